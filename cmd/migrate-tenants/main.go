@@ -0,0 +1,58 @@
+// Command migrate-tenants applies the tenant migrations to the schema of every organization
+// that has one in multi-schema mode. Run it after the public schema migrations whenever a
+// release adds a tenant migration. Schemas can also be named as arguments to migrate only
+// those.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lenon/portfolios/internal/app"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/migrations"
+)
+
+func main() {
+	// Load configuration from the runtime home directory and environment variables
+	cfg, _, err := app.LoadConfig()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	db, err := database.Connect(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	schemas := os.Args[1:]
+	if len(schemas) == 0 {
+		schemas, err = repository.NewOrganizationRepository(db).FindSchemaNames()
+		if err != nil {
+			log.Fatalf("Failed to list tenant schemas: %v", err)
+		}
+	}
+
+	// Keep going after a failure so that one broken tenant doesn't hold back the rest
+	failed := 0
+	for _, schema := range schemas {
+		applied, err := database.MigrateTenantSchema(context.Background(), db, schema, migrations.Tenant)
+		if err != nil {
+			log.Printf("Failed to migrate %s: %v", schema, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: applied %d migrations\n", schema, applied)
+	}
+
+	fmt.Printf("Migrated %d of %d tenant schemas\n", len(schemas)-failed, len(schemas))
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
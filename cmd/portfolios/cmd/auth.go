@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"syscall"
 
 	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/pkg/client"
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 )
@@ -43,6 +45,9 @@ var whoamiCmd = &cobra.Command{
 	RunE:  runWhoami,
 }
 
+// errLocalAuth is returned by the commands that manage a login, which local mode doesn't use
+var errLocalAuth = errors.New("local mode doesn't use accounts; drop --local to log in to an API server")
+
 func init() {
 	authCmd.AddCommand(loginCmd)
 	authCmd.AddCommand(logoutCmd)
@@ -51,9 +56,12 @@ func init() {
 }
 
 func runLogin(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
+	}
+	if config.Local {
+		return errLocalAuth
 	}
 
 	// Prompt for email
@@ -71,45 +79,31 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	}
 	password := string(passwordBytes)
 
-	// Create API client
-	client := cli.NewClientFromConfig(config)
-
-	// Login request
-	loginReq := map[string]string{
-		"email":    email,
-		"password": password,
-	}
-
-	var loginResp struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		User         struct {
-			ID    uint   `json:"id"`
-			Name  string `json:"name"`
-			Email string `json:"email"`
-		} `json:"user"`
-	}
-
-	if err := client.Request("POST", "/api/v1/auth/login", loginReq, &loginResp); err != nil {
+	loginResp, err := client.New(config.APIBaseURL).Login(cmd.Context(), client.LoginRequest{
+		Email:    email,
+		Password: password,
+	})
+	if err != nil {
 		return fmt.Errorf("login failed: %w", err)
 	}
 
 	// Save tokens
-	config.AccessToken = loginResp.AccessToken
-	config.RefreshToken = loginResp.RefreshToken
-	if err := cli.SaveConfig(config); err != nil {
+	if err := cli.SaveTokens(loginResp.AccessToken, loginResp.RefreshToken); err != nil {
 		return fmt.Errorf("failed to save tokens: %w", err)
 	}
 
 	cli.PrintSuccess("Successfully logged in!")
 	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("User", loginResp.User.Name))
 	fmt.Println(cli.RenderKeyValue("Email", loginResp.User.Email))
 
 	return nil
 }
 
 func runLogout(cmd *cobra.Command, args []string) error {
+	if localMode || localDB != "" {
+		return errLocalAuth
+	}
+
 	if err := cli.ClearTokens(); err != nil {
 		return fmt.Errorf("failed to logout: %w", err)
 	}
@@ -119,17 +113,15 @@ func runLogout(cmd *cobra.Command, args []string) error {
 }
 
 func runRegister(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
-
-	// Prompt for user details
-	name, err := cli.ReadInput("Full Name")
-	if err != nil {
-		return fmt.Errorf("failed to read name: %w", err)
+	if config.Local {
+		return errLocalAuth
 	}
 
+	// Prompt for user details
 	email, err := cli.ReadInput("Email")
 	if err != nil {
 		return fmt.Errorf("failed to read email: %w", err)
@@ -157,73 +149,57 @@ func runRegister(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("passwords do not match")
 	}
 
-	// Create API client
-	client := cli.NewClientFromConfig(config)
-
-	// Register request
-	registerReq := map[string]string{
-		"name":     name,
-		"email":    email,
-		"password": password,
-	}
-
-	var registerResp struct {
-		AccessToken  string `json:"access_token"`
-		RefreshToken string `json:"refresh_token"`
-		User         struct {
-			ID    uint   `json:"id"`
-			Name  string `json:"name"`
-			Email string `json:"email"`
-		} `json:"user"`
-	}
-
-	if err := client.Request("POST", "/api/v1/auth/register", registerReq, &registerResp); err != nil {
+	registerResp, err := client.New(config.APIBaseURL).Register(cmd.Context(), client.RegisterRequest{
+		Email:    email,
+		Password: password,
+	})
+	if err != nil {
 		return fmt.Errorf("registration failed: %w", err)
 	}
 
 	// Save tokens
-	config.AccessToken = registerResp.AccessToken
-	config.RefreshToken = registerResp.RefreshToken
-	if err := cli.SaveConfig(config); err != nil {
+	if err := cli.SaveTokens(registerResp.AccessToken, registerResp.RefreshToken); err != nil {
 		return fmt.Errorf("failed to save tokens: %w", err)
 	}
 
 	cli.PrintSuccess("Successfully registered and logged in!")
 	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("User", registerResp.User.Name))
 	fmt.Println(cli.RenderKeyValue("Email", registerResp.User.Email))
 
 	return nil
 }
 
 func runWhoami(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
+		return err
 	}
 
-	if config.AccessToken == "" {
+	if config.AccessToken == "" && config.APIKey == "" {
 		cli.PrintWarning("Not logged in. Use 'portfolios auth login' to authenticate.")
 		return nil
 	}
 
-	client := cli.NewClientFromConfig(config)
-
-	var userResp struct {
-		ID    uint   `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
+	var user *client.UserResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		user, err = c.GetCurrentUser(cmd.Context())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get user info: %w", err)
 	}
 
-	if err := client.Request("GET", "/api/v1/auth/me", nil, &userResp); err != nil {
-		return fmt.Errorf("failed to get user info: %w", err)
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
+		return cli.OutputJSON(user)
 	}
 
 	fmt.Println(cli.RenderSection("Current User"))
 	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("ID", fmt.Sprintf("%d", userResp.ID)))
-	fmt.Println(cli.RenderKeyValue("Name", userResp.Name))
-	fmt.Println(cli.RenderKeyValue("Email", userResp.Email))
+	fmt.Println(cli.RenderKeyValue("ID", user.ID.String()))
+	fmt.Println(cli.RenderKeyValue("Email", user.Email))
+	if config.APIKey != "" {
+		fmt.Println(cli.RenderKeyValue("Authenticated With", "API key"))
+	}
 
 	return nil
 }
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/lenon/portfolios/internal/app"
 	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -23,7 +27,7 @@ var configShowCmd = &cobra.Command{
 var configSetCmd = &cobra.Command{
 	Use:   "set <key> <value>",
 	Short: "Set a configuration value",
-	Long:  "Set a configuration value (api_base_url, output_format)",
+	Long:  "Set a configuration value (api_base_url, output_format, api_key, local_database, market_data_api_key)",
 	Args:  cobra.ExactArgs(2),
 	RunE:  runConfigSet,
 }
@@ -35,10 +39,21 @@ var configPathCmd = &cobra.Command{
 	RunE:  runConfigPath,
 }
 
+var configValidateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Validate a server configuration file",
+	Long: `Check a server configuration file (the config.yaml in the server's runtime home
+directory) for unknown fields and invalid values before deploying it. Environment variables
+are not applied, so settings such as DATABASE_URL may be left out of the file.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigValidate,
+}
+
 func init() {
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configSetCmd)
 	configCmd.AddCommand(configPathCmd)
+	configCmd.AddCommand(configValidateCmd)
 }
 
 func runConfigShow(cmd *cobra.Command, args []string) error {
@@ -58,6 +73,25 @@ func runConfigShow(cmd *cobra.Command, args []string) error {
 		fmt.Println(cli.RenderKeyValue("Logged In", "No"))
 	}
 
+	switch {
+	case os.Getenv(cli.APIKeyEnvVar) != "":
+		fmt.Println(cli.RenderKeyValue("API Key", "Set ("+cli.APIKeyEnvVar+")"))
+	case config.APIKey != "":
+		fmt.Println(cli.RenderKeyValue("API Key", "Set"))
+	default:
+		fmt.Println(cli.RenderKeyValue("API Key", "Not set"))
+	}
+
+	fmt.Println(cli.RenderKeyValue("Local Database", config.LocalDatabase))
+	switch {
+	case config.MarketDataAPIKey != "":
+		fmt.Println(cli.RenderKeyValue("Market Data API Key", "Set"))
+	case os.Getenv(cli.MarketDataAPIKeyEnvVar) != "":
+		fmt.Println(cli.RenderKeyValue("Market Data API Key", "Set ("+cli.MarketDataAPIKeyEnvVar+")"))
+	default:
+		fmt.Println(cli.RenderKeyValue("Market Data API Key", "Not set"))
+	}
+
 	return nil
 }
 
@@ -78,6 +112,14 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("invalid output format: %s (must be table, json, or csv)", value)
 		}
 		config.OutputFormat = value
+	case "api_key":
+		config.APIKey = value
+		value = "********"
+	case "local_database":
+		config.LocalDatabase = value
+	case "market_data_api_key":
+		config.MarketDataAPIKey = value
+		value = "********"
 	default:
 		return fmt.Errorf("unknown configuration key: %s", key)
 	}
@@ -99,3 +141,28 @@ func runConfigPath(cmd *cobra.Command, args []string) error {
 	fmt.Println(path)
 	return nil
 }
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	path := args[0]
+
+	cfg, err := config.LoadYAMLStrict(path)
+	if err == nil {
+		err = app.ValidateConfig(cfg)
+	}
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			cli.PrintError(line)
+		}
+		return fmt.Errorf("%s is not a valid configuration", path)
+	}
+
+	if cfg.Database.URL == "" {
+		cli.PrintInfo("database.url is not set, so DATABASE_URL must be set where the server runs")
+	}
+	if cfg.JWT.Secret == "" {
+		cli.PrintInfo("jwt.secret is not set, so JWT_SECRET must be set where the server runs")
+	}
+	cli.PrintInfo(fmt.Sprintf("Passwords are hashed with %s", app.PasswordHasher(cfg)))
+	cli.PrintSuccess(fmt.Sprintf("%s is valid", path))
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/pkg/client"
 	"github.com/spf13/cobra"
 )
 
@@ -32,38 +33,34 @@ func runInteractive(cmd *cobra.Command, args []string) error {
 }
 
 func runPortfolioSelect(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	client := cli.NewClientFromConfig(config)
-
 	// Fetch portfolios
-	var portfoliosResp []struct {
-		ID          uint    `json:"id"`
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		TotalValue  float64 `json:"total_value"`
-	}
-
-	if err := client.Request("GET", "/api/v1/portfolios", nil, &portfoliosResp); err != nil {
+	var portfoliosResp *client.PortfolioListResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		portfoliosResp, err = c.ListPortfolios(cmd.Context())
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
-	if len(portfoliosResp) == 0 {
+	if len(portfoliosResp.Portfolios) == 0 {
 		cli.PrintInfo("No portfolios found. Create one with 'portfolios portfolio create'")
 		return nil
 	}
 
 	// Convert to selector format
-	portfolios := make([]cli.Portfolio, len(portfoliosResp))
-	for i, p := range portfoliosResp {
+	portfolios := make([]cli.Portfolio, len(portfoliosResp.Portfolios))
+	for i, p := range portfoliosResp.Portfolios {
 		portfolios[i] = cli.Portfolio{
-			ID:          p.ID,
+			ID:          p.ID.String(),
 			Name:        p.Name,
 			Description: p.Description,
-			TotalValue:  p.TotalValue,
+			Currency:    p.BaseCurrency,
 		}
 	}
 
@@ -82,35 +79,13 @@ func runPortfolioSelect(cmd *cobra.Command, args []string) error {
 	cli.PrintSuccess(fmt.Sprintf("Selected: %s", selected.Name))
 	fmt.Println()
 
-	// Fetch full portfolio details
-	var portfolio struct {
-		ID          uint    `json:"id"`
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		TotalValue  float64 `json:"total_value"`
-		TotalGain   float64 `json:"total_gain"`
-		TotalReturn float64 `json:"total_return"`
-		CostBasis   float64 `json:"cost_basis"`
-	}
-
-	if err := client.Request("GET", fmt.Sprintf("/api/v1/portfolios/%d", selected.ID), nil, &portfolio); err != nil {
-		return err
-	}
-
-	fmt.Println(cli.RenderSection("Portfolio Details"))
-	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("ID", fmt.Sprintf("%d", portfolio.ID)))
-	fmt.Println(cli.RenderKeyValue("Name", portfolio.Name))
-	if portfolio.Description != "" {
-		fmt.Println(cli.RenderKeyValue("Description", portfolio.Description))
+	for _, p := range portfoliosResp.Portfolios {
+		if p.ID.String() == selected.ID {
+			fmt.Println(cli.RenderSection("Portfolio Details"))
+			fmt.Println()
+			printPortfolio(p)
+		}
 	}
-	fmt.Println()
-	fmt.Println(cli.RenderSection("Performance"))
-	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("Total Value", fmt.Sprintf("$%.2f", portfolio.TotalValue)))
-	fmt.Println(cli.RenderKeyValue("Cost Basis", fmt.Sprintf("$%.2f", portfolio.CostBasis)))
-	fmt.Println(cli.RenderKeyValue("Total Gain", formatGain(portfolio.TotalGain)))
-	fmt.Println(cli.RenderKeyValue("Total Return", fmt.Sprintf("%.2f%%", portfolio.TotalReturn)))
 
 	return nil
 }
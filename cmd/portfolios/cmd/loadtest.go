@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/loadtest"
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+var (
+	loadtestTransactions int
+	loadtestYears        int
+	loadtestSymbols      int
+	loadtestSeed         uint64
+	loadtestIterations   int
+	loadtestConcurrency  int
+	loadtestPortfolio    string
+	loadtestDatabaseURL  string
+)
+
+var loadtestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Measure analytics latency on a synthetic portfolio",
+	Long: `Seed a synthetic portfolio, 50,000 transactions and 10 years of daily snapshots by
+default, then measure how long the TWR, MWR, performance metrics and valuation requests
+take on it, reporting their latency percentiles.
+
+In local mode the portfolio is seeded into the local database and quoted from a synthetic
+market, so no market data provider is needed. Against a server the portfolio is seeded
+through --database-url for the logged-in user, and the server quotes it as usual.
+--portfolio measures an existing portfolio instead of seeding one.
+
+The command fails if any request fails.`,
+	Args: cobra.NoArgs,
+	RunE: runLoadtest,
+}
+
+var loadtestSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Seed a synthetic portfolio",
+	Long: `Seed a synthetic portfolio, 50,000 transactions and 10 years of daily snapshots by
+default, for trying the analytics on realistic data in a development environment.`,
+	Args: cobra.NoArgs,
+	RunE: runLoadtestSeed,
+}
+
+func init() {
+	loadtestCmd.AddCommand(loadtestSeedCmd)
+
+	defaults := loadtest.DefaultSeedOptions()
+	for _, cmd := range []*cobra.Command{loadtestCmd, loadtestSeedCmd} {
+		cmd.Flags().IntVar(&loadtestTransactions, "transactions", defaults.Transactions, "Transactions to seed")
+		cmd.Flags().IntVar(&loadtestYears, "years", defaults.Years, "Years of history to seed")
+		cmd.Flags().IntVar(&loadtestSymbols, "symbols", defaults.Symbols, "Symbols the seeded portfolio trades")
+		cmd.Flags().Uint64Var(&loadtestSeed, "seed", defaults.Seed, "Random seed; the same seed seeds the same portfolio")
+		cmd.Flags().StringVar(&loadtestDatabaseURL, "database-url", os.Getenv("DATABASE_URL"), "Server database to seed, when not in local mode (default $DATABASE_URL)")
+	}
+	loadtestCmd.Flags().IntVar(&loadtestIterations, "iterations", loadtest.DefaultIterations, "Requests of each kind to make")
+	loadtestCmd.Flags().IntVar(&loadtestConcurrency, "concurrency", loadtest.DefaultConcurrency, "Requests to keep in flight at once")
+	loadtestCmd.Flags().StringVar(&loadtestPortfolio, "portfolio", "", "Measure this existing portfolio instead of seeding one")
+}
+
+// loadtestSeedOptions returns the seed options given by the flags
+func loadtestSeedOptions() loadtest.SeedOptions {
+	opts := loadtest.DefaultSeedOptions()
+	opts.Name = fmt.Sprintf("Load Test %s", time.Now().Format("2006-01-02 15:04:05"))
+	opts.Transactions = loadtestTransactions
+	opts.Years = loadtestYears
+	opts.Symbols = loadtestSymbols
+	opts.Seed = loadtestSeed
+	return opts
+}
+
+func runLoadtestSeed(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	opts := loadtestSeedOptions()
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	var result *loadtest.SeedResult
+	err = withLoadtestBackend(cmd.Context(), config, opts, func(c *client.Client, seed seedFunc) (err error) {
+		result, err = seed()
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return outputSeedResult(config, result)
+}
+
+func runLoadtest(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	opts := loadtestSeedOptions()
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	runOpts := loadtest.RunOptions{Iterations: loadtestIterations, Concurrency: loadtestConcurrency}
+	format := cli.OutputFormat(config.OutputFormat)
+
+	var results []*loadtest.Result
+	err = withLoadtestBackend(cmd.Context(), config, opts, func(c *client.Client, seed seedFunc) error {
+		portfolioID := loadtestPortfolio
+		var period client.DateRange
+		if portfolioID == "" {
+			result, err := seed()
+			if err != nil {
+				return err
+			}
+			if format == cli.OutputFormatTable {
+				cli.PrintInfo(fmt.Sprintf("Seeded %d transactions and %d snapshots", result.Transactions, result.Snapshots))
+			}
+			portfolioID = result.Portfolio.ID.String()
+			period = client.DateRange{Start: result.StartDate, End: result.EndDate}
+		}
+
+		var err error
+		results, err = loadtest.Run(cmd.Context(), c, portfolioID, period, loadtest.Scenarios, runOpts)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	headers := []string{"Request", "Requests", "Errors", "Min", "Mean", "P50", "P95", "P99", "Max"}
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		rows[i] = []string{
+			r.Scenario,
+			fmt.Sprint(r.Requests),
+			fmt.Sprint(r.Errors),
+			formatLatency(r.Min),
+			formatLatency(r.Mean),
+			formatLatency(r.P50),
+			formatLatency(r.P95),
+			formatLatency(r.P99),
+			formatLatency(r.Max),
+		}
+	}
+	if err := cli.Output(format, headers, rows, results); err != nil {
+		return err
+	}
+
+	return loadtest.Failed(results)
+}
+
+// seedFunc seeds the synthetic portfolio for the user the client acts as
+type seedFunc func() (*loadtest.SeedResult, error)
+
+// withLoadtestBackend runs fn with a client and a way to seed the portfolio it measures. In
+// local mode the backend quotes the seeded market, so it needs no market data provider.
+func withLoadtestBackend(ctx context.Context, config *cli.Config, opts loadtest.SeedOptions, fn func(*client.Client, seedFunc) error) error {
+	if config.Local {
+		backend, err := cli.OpenLocalWithProvider(ctx, config.LocalDatabase, loadtest.NewProvider(opts.Market()))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = backend.Close()
+		}()
+
+		return fn(backend.Client(), func() (*loadtest.SeedResult, error) {
+			return loadtest.Seed(ctx, backend.DB(), backend.UserID(), opts)
+		})
+	}
+
+	return cli.Call(ctx, config, func(c *client.Client) error {
+		return fn(c, func() (*loadtest.SeedResult, error) {
+			if loadtestDatabaseURL == "" {
+				return nil, fmt.Errorf("seeding a server's portfolio needs its database; set --database-url or DATABASE_URL, or use --local")
+			}
+			user, err := c.GetCurrentUser(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return seedDatabase(ctx, loadtestDatabaseURL, user.ID, opts)
+		})
+	})
+}
+
+// seedDatabase seeds the portfolio for userID into the database at databaseURL
+func seedDatabase(ctx context.Context, databaseURL string, userID uuid.UUID, opts loadtest.SeedOptions) (*loadtest.SeedResult, error) {
+	db, err := database.ConnectWithLogger(databaseURL, gormlogger.Default.LogMode(gormlogger.Silent))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	return loadtest.Seed(ctx, db, userID, opts)
+}
+
+// outputSeedResult prints what was seeded
+func outputSeedResult(config *cli.Config, result *loadtest.SeedResult) error {
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
+		return cli.OutputJSON(result)
+	}
+
+	cli.PrintSuccess("Synthetic portfolio seeded!")
+	fmt.Println(cli.RenderKeyValue("Portfolio ID", result.Portfolio.ID.String()))
+	fmt.Println(cli.RenderKeyValue("Name", result.Portfolio.Name))
+	fmt.Println(cli.RenderKeyValue("Transactions", fmt.Sprint(result.Transactions)))
+	fmt.Println(cli.RenderKeyValue("Snapshots", fmt.Sprint(result.Snapshots)))
+	fmt.Println(cli.RenderKeyValue("Holdings", fmt.Sprint(result.Holdings)))
+	fmt.Println(cli.RenderKeyValue("History", result.StartDate.Format(dateLayout)+" to "+result.EndDate.Format(dateLayout)))
+	return nil
+}
+
+// formatLatency formats a request latency in milliseconds
+func formatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+}
@@ -1,15 +1,22 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/pkg/client"
 	"github.com/spf13/cobra"
 )
 
+// dateLayout is the date format the CLI accepts and prints
+const dateLayout = "2006-01-02"
+
 var (
-	startDate string
-	endDate   string
+	startDate     string
+	endDate       string
+	snapshotLimit int
 )
 
 var performanceCmd = &cobra.Command{
@@ -28,10 +35,10 @@ var performanceShowCmd = &cobra.Command{
 }
 
 var performanceCompareCmd = &cobra.Command{
-	Use:   "compare <portfolio-id-1> <portfolio-id-2>",
-	Short: "Compare two portfolios",
-	Long:  "Compare performance metrics between two portfolios",
-	Args:  cobra.ExactArgs(2),
+	Use:   "compare <portfolio-id-1> <portfolio-id-2> [portfolio-id...]",
+	Short: "Compare portfolios",
+	Long:  "Compare performance metrics between portfolios over the same period",
+	Args:  cobra.MinimumNArgs(2),
 	RunE:  runPerformanceCompare,
 }
 
@@ -58,314 +65,260 @@ func init() {
 	performanceCmd.AddCommand(performanceSnapshotsCmd)
 
 	// Add date range flags
-	performanceShowCmd.Flags().StringVar(&startDate, "start", "", "Start date (YYYY-MM-DD)")
-	performanceShowCmd.Flags().StringVar(&endDate, "end", "", "End date (YYYY-MM-DD)")
-	performanceCompareCmd.Flags().StringVar(&startDate, "start", "", "Start date (YYYY-MM-DD)")
-	performanceCompareCmd.Flags().StringVar(&endDate, "end", "", "End date (YYYY-MM-DD)")
-	performanceBenchmarkCmd.Flags().StringVar(&startDate, "start", "", "Start date (YYYY-MM-DD)")
-	performanceBenchmarkCmd.Flags().StringVar(&endDate, "end", "", "End date (YYYY-MM-DD)")
+	for _, cmd := range []*cobra.Command{performanceShowCmd, performanceCompareCmd, performanceBenchmarkCmd, performanceSnapshotsCmd} {
+		cmd.Flags().StringVar(&startDate, "start", "", "Start date (YYYY-MM-DD)")
+		cmd.Flags().StringVar(&endDate, "end", "", "End date (YYYY-MM-DD)")
+	}
+	performanceSnapshotsCmd.Flags().IntVar(&snapshotLimit, "limit", 30, "Number of most recent snapshots to show when no dates are given")
 }
 
 func runPerformanceShow(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-	client := cli.NewClientFromConfig(config)
-
-	// Build query params
-	path := "/api/v1/portfolios/" + portfolioID + "/performance"
-	if startDate != "" || endDate != "" {
-		path += "?"
-		if startDate != "" {
-			path += "start_date=" + startDate
-		}
-		if endDate != "" {
-			if startDate != "" {
-				path += "&"
-			}
-			path += "end_date=" + endDate
-		}
-	}
-
-	var metrics struct {
-		TimeWeightedReturn  float64 `json:"time_weighted_return"`
-		MoneyWeightedReturn float64 `json:"money_weighted_return"`
-		AnnualizedReturn    float64 `json:"annualized_return"`
-		TotalReturn         float64 `json:"total_return"`
-		TotalGain           float64 `json:"total_gain"`
-		DividendIncome      float64 `json:"dividend_income"`
-		RealizedGain        float64 `json:"realized_gain"`
-		UnrealizedGain      float64 `json:"unrealized_gain"`
-		BeginningValue      float64 `json:"beginning_value"`
-		EndingValue         float64 `json:"ending_value"`
-		NetContributions    float64 `json:"net_contributions"`
-		Period              string  `json:"period"`
-	}
-
-	if err := client.Request("GET", path, nil, &metrics); err != nil {
+	period, err := dateRange(startDate, endDate)
+	if err != nil {
 		return err
 	}
 
-	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
+	var metrics *client.PerformanceMetricsResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		metrics, err = c.GetPerformanceMetrics(cmd.Context(), args[0], period)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	if format == cli.OutputFormatJSON {
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
 		return cli.OutputJSON(metrics)
 	}
 
 	// Display formatted performance metrics
 	fmt.Println(cli.RenderSection("Performance Metrics"))
-	if metrics.Period != "" {
-		fmt.Printf("\nPeriod: %s\n", metrics.Period)
-	}
+	fmt.Printf("\nPeriod: %s to %s\n", metrics.StartDate.Format(dateLayout), metrics.EndDate.Format(dateLayout))
 	fmt.Println()
 
 	fmt.Println(cli.RenderSection("Returns"))
-	fmt.Println(cli.RenderKeyValue("Time-Weighted Return", fmt.Sprintf("%.2f%%", metrics.TimeWeightedReturn)))
-	fmt.Println(cli.RenderKeyValue("Money-Weighted Return", fmt.Sprintf("%.2f%%", metrics.MoneyWeightedReturn)))
-	fmt.Println(cli.RenderKeyValue("Annualized Return", fmt.Sprintf("%.2f%%", metrics.AnnualizedReturn)))
-	fmt.Println(cli.RenderKeyValue("Total Return", fmt.Sprintf("%.2f%%", metrics.TotalReturn)))
-
-	fmt.Println()
-	fmt.Println(cli.RenderSection("Gains & Income"))
-	fmt.Println(cli.RenderKeyValue("Total Gain", formatGain(metrics.TotalGain)))
-	fmt.Println(cli.RenderKeyValue("Realized Gain", formatGain(metrics.RealizedGain)))
-	fmt.Println(cli.RenderKeyValue("Unrealized Gain", formatGain(metrics.UnrealizedGain)))
-	fmt.Println(cli.RenderKeyValue("Dividend Income", fmt.Sprintf("$%.2f", metrics.DividendIncome)))
+	fmt.Println(cli.RenderKeyValue("Time-Weighted Return", formatPercent(metrics.TimeWeightedReturn)))
+	fmt.Println(cli.RenderKeyValue("Money-Weighted Return", formatPercent(metrics.MoneyWeightedReturn)))
+	fmt.Println(cli.RenderKeyValue("Annualized Return", formatPercent(metrics.AnnualizedReturn)))
+	fmt.Println(cli.RenderKeyValue("Total Return", formatPercent(metrics.TotalReturnPct)))
+	fmt.Println(cli.RenderKeyValue("Total Gain", formatGain(metrics.TotalReturn)))
 
 	fmt.Println()
 	fmt.Println(cli.RenderSection("Portfolio Value"))
-	fmt.Println(cli.RenderKeyValue("Beginning Value", fmt.Sprintf("$%.2f", metrics.BeginningValue)))
-	fmt.Println(cli.RenderKeyValue("Ending Value", fmt.Sprintf("$%.2f", metrics.EndingValue)))
-	fmt.Println(cli.RenderKeyValue("Net Contributions", formatGain(metrics.NetContributions)))
+	fmt.Println(cli.RenderKeyValue("Starting Value", formatMoney(metrics.StartingValue)))
+	fmt.Println(cli.RenderKeyValue("Ending Value", formatMoney(metrics.EndingValue)))
+	fmt.Println(cli.RenderKeyValue("Deposits", formatMoney(metrics.TotalDeposits)))
+	fmt.Println(cli.RenderKeyValue("Withdrawals", formatMoney(metrics.TotalWithdrawals)))
+	fmt.Println(cli.RenderKeyValue("Purchases", formatMoney(metrics.TotalPurchases)))
+	fmt.Println(cli.RenderKeyValue("Sales", formatMoney(metrics.TotalSales)))
+	fmt.Println(cli.RenderKeyValue("Net Cash Flow", formatGain(metrics.NetCashFlow)))
 
 	return nil
 }
 
 func runPerformanceCompare(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID1 := args[0]
-	portfolioID2 := args[1]
-	client := cli.NewClientFromConfig(config)
-
-	// Build query params
-	queryParams := fmt.Sprintf("?portfolio_ids=%s,%s", portfolioID1, portfolioID2)
-	if startDate != "" {
-		queryParams += "&start_date=" + startDate
-	}
-	if endDate != "" {
-		queryParams += "&end_date=" + endDate
-	}
-
-	var comparison struct {
-		Portfolios []struct {
-			ID                  uint    `json:"id"`
-			Name                string  `json:"name"`
-			TimeWeightedReturn  float64 `json:"time_weighted_return"`
-			MoneyWeightedReturn float64 `json:"money_weighted_return"`
-			AnnualizedReturn    float64 `json:"annualized_return"`
-			TotalReturn         float64 `json:"total_return"`
-			TotalGain           float64 `json:"total_gain"`
-			EndingValue         float64 `json:"ending_value"`
-		} `json:"portfolios"`
-	}
-
-	if err := client.Request("GET", "/api/v1/portfolios/compare"+queryParams, nil, &comparison); err != nil {
+	period, err := dateRange(startDate, endDate)
+	if err != nil {
 		return err
 	}
 
-	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
-	}
-
-	if format == cli.OutputFormatJSON {
-		return cli.OutputJSON(comparison)
-	}
-
-	if len(comparison.Portfolios) == 0 {
-		cli.PrintInfo("No data available for comparison")
-		return nil
-	}
-
-	// Display as table
-	headers := []string{"Portfolio", "TWR %", "MWR %", "Annualized %", "Total Return %", "Total Gain", "Value"}
-	rows := make([][]string, len(comparison.Portfolios))
-
-	for i, p := range comparison.Portfolios {
-		rows[i] = []string{
-			fmt.Sprintf("%s (#%d)", p.Name, p.ID),
-			fmt.Sprintf("%.2f%%", p.TimeWeightedReturn),
-			fmt.Sprintf("%.2f%%", p.MoneyWeightedReturn),
-			fmt.Sprintf("%.2f%%", p.AnnualizedReturn),
-			fmt.Sprintf("%.2f%%", p.TotalReturn),
-			formatGain(p.TotalGain),
-			fmt.Sprintf("$%.2f", p.EndingValue),
-		}
-	}
-
-	cli.OutputTable(headers, rows)
-	return nil
+	return outputPerformanceReport(cmd.Context(), config, args, period, "")
 }
 
 func runPerformanceBenchmark(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-	benchmarkSymbol := args[1]
-	client := cli.NewClientFromConfig(config)
-
-	// Build query params
-	queryParams := fmt.Sprintf("?benchmark=%s", benchmarkSymbol)
-	if startDate != "" {
-		queryParams += "&start_date=" + startDate
-	}
-	if endDate != "" {
-		queryParams += "&end_date=" + endDate
-	}
-
-	var comparison struct {
-		Portfolio struct {
-			TimeWeightedReturn float64 `json:"time_weighted_return"`
-			AnnualizedReturn   float64 `json:"annualized_return"`
-			TotalReturn        float64 `json:"total_return"`
-		} `json:"portfolio"`
-		Benchmark struct {
-			Symbol             string  `json:"symbol"`
-			TimeWeightedReturn float64 `json:"time_weighted_return"`
-			AnnualizedReturn   float64 `json:"annualized_return"`
-			TotalReturn        float64 `json:"total_return"`
-		} `json:"benchmark"`
-		Difference struct {
-			TimeWeightedReturn float64 `json:"time_weighted_return"`
-			AnnualizedReturn   float64 `json:"annualized_return"`
-			TotalReturn        float64 `json:"total_return"`
-		} `json:"difference"`
+	period, err := dateRange(startDate, endDate)
+	if err != nil {
+		return err
 	}
 
-	if err := client.Request("GET", "/api/v1/portfolios/"+portfolioID+"/performance"+queryParams, nil, &comparison); err != nil {
+	var comparison *client.BenchmarkComparisonResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		comparison, err = c.GetBenchmarkComparison(cmd.Context(), args[0], args[1], period)
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
 	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
-	}
-
 	if format == cli.OutputFormatJSON {
 		return cli.OutputJSON(comparison)
 	}
 
-	// Display comparison
-	fmt.Println(cli.RenderSection(fmt.Sprintf("Benchmark Comparison: %s", benchmarkSymbol)))
-	fmt.Println()
-
 	headers := []string{"Metric", "Portfolio", "Benchmark", "Difference"}
 	rows := [][]string{
 		{
-			"Time-Weighted Return",
-			fmt.Sprintf("%.2f%%", comparison.Portfolio.TimeWeightedReturn),
-			fmt.Sprintf("%.2f%%", comparison.Benchmark.TimeWeightedReturn),
-			formatDifference(comparison.Difference.TimeWeightedReturn),
+			"Total Return",
+			formatPercent(comparison.PortfolioReturn),
+			formatPercent(comparison.BenchmarkReturn),
+			formatDifference(comparison.Outperformance),
 		},
 		{
 			"Annualized Return",
-			fmt.Sprintf("%.2f%%", comparison.Portfolio.AnnualizedReturn),
-			fmt.Sprintf("%.2f%%", comparison.Benchmark.AnnualizedReturn),
-			formatDifference(comparison.Difference.AnnualizedReturn),
-		},
-		{
-			"Total Return",
-			fmt.Sprintf("%.2f%%", comparison.Portfolio.TotalReturn),
-			fmt.Sprintf("%.2f%%", comparison.Benchmark.TotalReturn),
-			formatDifference(comparison.Difference.TotalReturn),
+			formatPercent(comparison.PortfolioAnnualized),
+			formatPercent(comparison.BenchmarkAnnualized),
+			formatDifference(comparison.Alpha),
 		},
 	}
 
-	cli.OutputTable(headers, rows)
-	return nil
+	if format == cli.OutputFormatTable {
+		// Display comparison
+		fmt.Println(cli.RenderSection(fmt.Sprintf("Benchmark Comparison: %s", comparison.BenchmarkSymbol)))
+		fmt.Printf("\nPeriod: %s to %s\n\n", comparison.StartDate.Format(dateLayout), comparison.EndDate.Format(dateLayout))
+	}
+
+	return cli.Output(format, headers, rows, comparison)
 }
 
 func runPerformanceSnapshots(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-	client := cli.NewClientFromConfig(config)
+	period, err := dateRange(startDate, endDate)
+	if err != nil {
+		return err
+	}
 
-	// Build query params
-	path := "/api/v1/portfolios/" + portfolioID + "/snapshots"
-	if startDate != "" || endDate != "" {
-		path += "?"
-		if startDate != "" {
-			path += "start_date=" + startDate
+	var snapshots *client.PerformanceSnapshotListResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		if period.Start.IsZero() && period.End.IsZero() {
+			snapshots, err = c.ListSnapshots(cmd.Context(), args[0], snapshotLimit, 0)
+		} else {
+			snapshots, err = c.ListSnapshotsByDateRange(cmd.Context(), args[0], period)
 		}
-		if endDate != "" {
-			if startDate != "" {
-				path += "&"
-			}
-			path += "end_date=" + endDate
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	format := cli.OutputFormat(config.OutputFormat)
+	if len(snapshots.Snapshots) == 0 && format == cli.OutputFormatTable {
+		cli.PrintInfo("No snapshots found for this portfolio")
+		return nil
+	}
+
+	headers := []string{"Date", "Total Value", "Cost Basis", "Total Gain", "Return %", "Day Change", "Day Change %"}
+	rows := make([][]string, len(snapshots.Snapshots))
+
+	for i, s := range snapshots.Snapshots {
+		rows[i] = []string{
+			s.Date.Format(dateLayout),
+			formatMoney(s.TotalValue),
+			formatMoney(s.TotalCostBasis),
+			formatGain(s.TotalReturn),
+			formatPercent(s.TotalReturnPct),
+			formatOptional(s.DayChange, formatGain),
+			formatOptional(s.DayChangePct, formatDifference),
 		}
 	}
 
-	var snapshots []struct {
-		ID               uint    `json:"id"`
-		SnapshotDate     string  `json:"snapshot_date"`
-		TotalValue       float64 `json:"total_value"`
-		TotalGain        float64 `json:"total_gain"`
-		TotalReturn      float64 `json:"total_return"`
-		DayChange        float64 `json:"day_change"`
-		DayChangePercent float64 `json:"day_change_percent"`
+	return cli.Output(format, headers, rows, snapshots.Snapshots)
+}
+
+// parseDate parses a YYYY-MM-DD date given on the command line
+func parseDate(value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD", value)
 	}
+	return date, nil
+}
 
-	if err := client.Request("GET", path, nil, &snapshots); err != nil {
-		return err
+// dateRange parses the --start and --end flags. An empty flag leaves that bound to the server.
+func dateRange(start, end string) (client.DateRange, error) {
+	var period client.DateRange
+	var err error
+	if start != "" {
+		if period.Start, err = parseDate(start); err != nil {
+			return period, err
+		}
+	}
+	if end != "" {
+		if period.End, err = parseDate(end); err != nil {
+			return period, err
+		}
 	}
+	return period, nil
+}
 
-	if len(snapshots) == 0 {
-		cli.PrintInfo("No snapshots found for this portfolio")
+// outputPerformanceReport prints one row of performance metrics per portfolio over period,
+// with the return of benchmark over the same period when one is given
+func outputPerformanceReport(ctx context.Context, config *cli.Config, portfolioIDs []string, period client.DateRange, benchmark string) error {
+	type reportRow struct {
+		PortfolioID string                              `json:"portfolio_id"`
+		Name        string                              `json:"name"`
+		Metrics     *client.PerformanceMetricsResponse  `json:"metrics"`
+		Benchmark   *client.BenchmarkComparisonResponse `json:"benchmark,omitempty"`
+	}
+
+	report := make([]reportRow, 0, len(portfolioIDs))
+	err := cli.Call(ctx, config, func(c *client.Client) error {
+		report = report[:0]
+		for _, id := range portfolioIDs {
+			portfolio, err := c.GetPortfolio(ctx, id)
+			if err != nil {
+				return err
+			}
+			metrics, err := c.GetPerformanceMetrics(ctx, id, period)
+			if err != nil {
+				return fmt.Errorf("portfolio %s: %w", portfolio.Name, err)
+			}
+			row := reportRow{PortfolioID: id, Name: portfolio.Name, Metrics: metrics}
+			if benchmark != "" {
+				if row.Benchmark, err = c.GetBenchmarkComparison(ctx, id, benchmark, period); err != nil {
+					return fmt.Errorf("portfolio %s: %w", portfolio.Name, err)
+				}
+			}
+			report = append(report, row)
+		}
 		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
+	headers := []string{"Portfolio", "ID", "Start", "End", "Starting Value", "Ending Value", "Total Gain", "Total Return %", "TWR %", "MWR %", "Annualized %"}
+	if benchmark != "" {
+		headers = append(headers, benchmark+" %", "Outperformance")
 	}
+	rows := make([][]string, len(report))
 
-	headers := []string{"Date", "Total Value", "Total Gain", "Return %", "Day Change", "Day Change %"}
-	rows := make([][]string, len(snapshots))
-
-	for i, s := range snapshots {
+	for i, r := range report {
 		rows[i] = []string{
-			s.SnapshotDate,
-			fmt.Sprintf("$%.2f", s.TotalValue),
-			formatGain(s.TotalGain),
-			fmt.Sprintf("%.2f%%", s.TotalReturn),
-			formatGain(s.DayChange),
-			formatDifference(s.DayChangePercent),
+			r.Name,
+			r.PortfolioID,
+			r.Metrics.StartDate.Format(dateLayout),
+			r.Metrics.EndDate.Format(dateLayout),
+			formatMoney(r.Metrics.StartingValue),
+			formatMoney(r.Metrics.EndingValue),
+			formatGain(r.Metrics.TotalReturn),
+			formatPercent(r.Metrics.TotalReturnPct),
+			formatPercent(r.Metrics.TimeWeightedReturn),
+			formatPercent(r.Metrics.MoneyWeightedReturn),
+			formatPercent(r.Metrics.AnnualizedReturn),
+		}
+		if r.Benchmark != nil {
+			rows[i] = append(rows[i], formatPercent(r.Benchmark.BenchmarkReturn), formatDifference(r.Benchmark.Outperformance))
 		}
 	}
 
-	return cli.Output(format, headers, rows, snapshots)
-}
-
-func formatDifference(diff float64) string {
-	if diff >= 0 {
-		return fmt.Sprintf("+%.2f%%", diff)
-	}
-	return fmt.Sprintf("%.2f%%", diff)
+	return cli.Output(cli.OutputFormat(config.OutputFormat), headers, rows, report)
 }
@@ -2,11 +2,29 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/pkg/client"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
+var (
+	portfolioName        string
+	portfolioDescription string
+	portfolioCurrency    string
+	portfolioCostBasis   string
+	skipConfirmation     bool
+	importTracker        string
+	importCostBasis      string
+	createAdjustments    bool
+	holdingsAsOf         string
+)
+
 var portfolioCmd = &cobra.Command{
 	Use:     "portfolio",
 	Aliases: []string{"port", "p"},
@@ -18,15 +36,18 @@ var portfolioListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls", "l"},
 	Short:   "List all portfolios",
-	Long:    "Display all portfolios with their current values and performance metrics",
+	Long:    "Display all portfolios you have access to",
+	Args:    cobra.NoArgs,
 	RunE:    runPortfolioList,
 }
 
 var portfolioCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new portfolio",
-	Long:  "Create a new investment portfolio with a name and optional description",
-	RunE:  runPortfolioCreate,
+	Long: `Create a new investment portfolio. Values not given as flags are prompted for, so
+'portfolios portfolio create --name "Retirement"' runs without any prompts.`,
+	Args: cobra.NoArgs,
+	RunE: runPortfolioCreate,
 }
 
 var portfolioShowCmd = &cobra.Command{
@@ -50,185 +71,203 @@ var portfolioHoldingsCmd = &cobra.Command{
 	Use:     "holdings <id>",
 	Aliases: []string{"hold"},
 	Short:   "Show portfolio holdings",
-	Long:    "Display current holdings for a specific portfolio",
+	Long:    "Display current holdings for a specific portfolio, or with --as-of those it had at the end of a past day",
 	Args:    cobra.ExactArgs(1),
 	RunE:    runPortfolioHoldings,
 }
 
+var portfolioValueCmd = &cobra.Command{
+	Use:     "value <id>",
+	Aliases: []string{"val"},
+	Short:   "Value portfolio holdings",
+	Long:    "Value each holding of a portfolio at its current market price (requires market data)",
+	Args:    cobra.ExactArgs(1),
+	RunE:    runPortfolioValue,
+}
+
+var portfolioImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import portfolios from another tracker",
+	Long: `Import the full history exported from another portfolio tracker. Each account in the
+export becomes a new portfolio with its transactions and tax lots. Supports Ghostfolio JSON
+exports and Portfolio Performance files saved as XML. Use - to read the file from standard input.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPortfolioImport,
+}
+
+var portfolioReconcileCmd = &cobra.Command{
+	Use:   "reconcile <portfolio-id> <csv-file>",
+	Short: "Compare holdings with a position statement",
+	Long: `Compare a portfolio's holdings with a statement of the positions its account holds, from
+a CSV file with Symbol and Quantity columns and an optional Cost Basis column, and list the
+symbols whose quantity or cost basis differs. Use - to read the file from standard input.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runPortfolioReconcile,
+}
+
+// trackerFormats maps the --from values, with dashes and underscores removed, to import formats
+var trackerFormats = map[string]client.ImportFormat{
+	"ghostfolio":           client.ImportFormatGhostfolio,
+	"portfolioperformance": client.ImportFormatPortfolioPerformance,
+	"pp":                   client.ImportFormatPortfolioPerformance,
+}
+
 func init() {
 	portfolioCmd.AddCommand(portfolioListCmd)
 	portfolioCmd.AddCommand(portfolioCreateCmd)
 	portfolioCmd.AddCommand(portfolioShowCmd)
 	portfolioCmd.AddCommand(portfolioDeleteCmd)
 	portfolioCmd.AddCommand(portfolioHoldingsCmd)
+	portfolioCmd.AddCommand(portfolioValueCmd)
+	portfolioCmd.AddCommand(portfolioImportCmd)
+	portfolioCmd.AddCommand(portfolioReconcileCmd)
+
+	portfolioCreateCmd.Flags().StringVar(&portfolioName, "name", "", "Portfolio name")
+	portfolioCreateCmd.Flags().StringVar(&portfolioDescription, "description", "", "Portfolio description")
+	portfolioCreateCmd.Flags().StringVar(&portfolioCurrency, "currency", "USD", "Base currency (ISO 4217 code)")
+	portfolioCreateCmd.Flags().StringVar(&portfolioCostBasis, "cost-basis", "fifo", "Cost basis method (fifo|lifo|specific_lot)")
+	portfolioHoldingsCmd.Flags().StringVar(&holdingsAsOf, "as-of", "", "Show the holdings at the end of this day (YYYY-MM-DD)")
+	portfolioDeleteCmd.Flags().BoolVarP(&skipConfirmation, "yes", "y", false, "Delete without asking for confirmation")
+
+	portfolioImportCmd.Flags().StringVarP(&importTracker, "from", "f", "", "Tracker the file was exported from (ghostfolio|portfolio-performance)")
+	portfolioImportCmd.Flags().StringVar(&importCostBasis, "cost-basis", "fifo", "Cost basis method of the new portfolios (fifo|lifo|specific_lot)")
+	portfolioImportCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate without importing")
+	portfolioImportCmd.Flags().BoolVar(&skipInvalid, "skip-invalid", false, "Import the valid activities and skip the invalid ones")
+	portfolioImportCmd.Flags().StringVar(&importNotes, "notes", "", "Notes stored with the import batches")
+
+	portfolioReconcileCmd.Flags().BoolVar(&createAdjustments, "adjust", false, "Record transactions bringing the holdings' quantities in line with the statement")
+	_ = portfolioImportCmd.MarkFlagRequired("from")
 }
 
 func runPortfolioList(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	client := cli.NewClientFromConfig(config)
-
-	var portfolios []struct {
-		ID          uint    `json:"id"`
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		TotalValue  float64 `json:"total_value"`
-		TotalGain   float64 `json:"total_gain"`
-		TotalReturn float64 `json:"total_return"`
-		CreatedAt   string  `json:"created_at"`
-	}
-
-	if err := client.Request("GET", "/api/v1/portfolios", nil, &portfolios); err != nil {
+	var portfolios *client.PortfolioListResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		portfolios, err = c.ListPortfolios(cmd.Context())
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
-	if len(portfolios) == 0 {
+	format := cli.OutputFormat(config.OutputFormat)
+	if len(portfolios.Portfolios) == 0 && format == cli.OutputFormatTable {
 		cli.PrintInfo("No portfolios found. Create one with 'portfolios portfolio create'")
 		return nil
 	}
 
-	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
-	}
-
-	headers := []string{"ID", "Name", "Description", "Total Value", "Total Gain", "Total Return %"}
-	rows := make([][]string, len(portfolios))
+	headers := []string{"ID", "Name", "Description", "Currency", "Cost Basis", "Created"}
+	rows := make([][]string, len(portfolios.Portfolios))
 
-	for i, p := range portfolios {
+	for i, p := range portfolios.Portfolios {
 		rows[i] = []string{
-			fmt.Sprintf("%d", p.ID),
+			p.ID.String(),
 			p.Name,
 			truncate(p.Description, 40),
-			fmt.Sprintf("$%.2f", p.TotalValue),
-			formatGain(p.TotalGain),
-			fmt.Sprintf("%.2f%%", p.TotalReturn),
+			p.BaseCurrency,
+			string(p.CostBasisMethod),
+			p.CreatedAt.Format(dateLayout),
 		}
 	}
 
-	return cli.Output(format, headers, rows, portfolios)
+	return cli.Output(format, headers, rows, portfolios.Portfolios)
 }
 
 func runPortfolioCreate(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
-	if err != nil {
-		return err
-	}
-
-	// Prompt for portfolio details
-	name, err := cli.ReadInput("Portfolio Name")
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	description, err := cli.ReadInput("Description (optional)")
-	if err != nil {
-		return err
+	name := portfolioName
+	description := portfolioDescription
+	if name == "" {
+		// Prompt for portfolio details
+		if name, err = cli.ReadInput("Portfolio Name"); err != nil {
+			return err
+		}
+		if description == "" {
+			if description, err = cli.ReadInput("Description (optional)"); err != nil {
+				return err
+			}
+		}
 	}
 
-	client := cli.NewClientFromConfig(config)
-
-	createReq := map[string]string{
-		"name":        name,
-		"description": description,
+	createReq := client.CreatePortfolioRequest{
+		Name:            name,
+		Description:     description,
+		BaseCurrency:    strings.ToUpper(portfolioCurrency),
+		CostBasisMethod: client.CostBasisMethod(enumValue(portfolioCostBasis)),
 	}
 
-	var portfolio struct {
-		ID          uint   `json:"id"`
-		Name        string `json:"name"`
-		Description string `json:"description"`
+	var portfolio *client.PortfolioResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		portfolio, err = c.CreatePortfolio(cmd.Context(), createReq)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := client.Request("POST", "/api/v1/portfolios", createReq, &portfolio); err != nil {
-		return err
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
+		return cli.OutputJSON(portfolio)
 	}
 
 	cli.PrintSuccess(fmt.Sprintf("Portfolio '%s' created successfully!", portfolio.Name))
 	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("ID", fmt.Sprintf("%d", portfolio.ID)))
-	fmt.Println(cli.RenderKeyValue("Name", portfolio.Name))
-	if portfolio.Description != "" {
-		fmt.Println(cli.RenderKeyValue("Description", portfolio.Description))
-	}
+	printPortfolio(portfolio)
 
 	return nil
 }
 
 func runPortfolioShow(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-	client := cli.NewClientFromConfig(config)
-
-	var portfolio struct {
-		ID          uint    `json:"id"`
-		Name        string  `json:"name"`
-		Description string  `json:"description"`
-		TotalValue  float64 `json:"total_value"`
-		TotalGain   float64 `json:"total_gain"`
-		TotalReturn float64 `json:"total_return"`
-		CostBasis   float64 `json:"cost_basis"`
-		CreatedAt   string  `json:"created_at"`
-		UpdatedAt   string  `json:"updated_at"`
-	}
-
-	if err := client.Request("GET", "/api/v1/portfolios/"+portfolioID, nil, &portfolio); err != nil {
+	var portfolio *client.PortfolioResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		portfolio, err = c.GetPortfolio(cmd.Context(), args[0])
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
-	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
-	}
-
-	if format == cli.OutputFormatJSON {
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
 		return cli.OutputJSON(portfolio)
 	}
 
 	// Display as formatted text
 	fmt.Println(cli.RenderSection("Portfolio Details"))
 	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("ID", fmt.Sprintf("%d", portfolio.ID)))
-	fmt.Println(cli.RenderKeyValue("Name", portfolio.Name))
-	if portfolio.Description != "" {
-		fmt.Println(cli.RenderKeyValue("Description", portfolio.Description))
-	}
-	fmt.Println()
-	fmt.Println(cli.RenderSection("Performance"))
-	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("Total Value", fmt.Sprintf("$%.2f", portfolio.TotalValue)))
-	fmt.Println(cli.RenderKeyValue("Cost Basis", fmt.Sprintf("$%.2f", portfolio.CostBasis)))
-	fmt.Println(cli.RenderKeyValue("Total Gain", formatGain(portfolio.TotalGain)))
-	fmt.Println(cli.RenderKeyValue("Total Return", fmt.Sprintf("%.2f%%", portfolio.TotalReturn)))
-	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("Created", portfolio.CreatedAt))
-	fmt.Println(cli.RenderKeyValue("Last Updated", portfolio.UpdatedAt))
+	printPortfolio(portfolio)
 
 	return nil
 }
 
 func runPortfolioDelete(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
 	portfolioID := args[0]
 
-	if !cli.Confirm(fmt.Sprintf("Are you sure you want to delete portfolio %s?", portfolioID)) {
+	if !skipConfirmation && !cli.Confirm(fmt.Sprintf("Are you sure you want to delete portfolio %s?", portfolioID)) {
 		cli.PrintInfo("Deletion cancelled")
 		return nil
 	}
 
-	client := cli.NewClientFromConfig(config)
-
-	if err := client.Request("DELETE", "/api/v1/portfolios/"+portfolioID, nil, nil); err != nil {
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) error {
+		return c.DeletePortfolio(cmd.Context(), portfolioID)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -237,55 +276,357 @@ func runPortfolioDelete(cmd *cobra.Command, args []string) error {
 }
 
 func runPortfolioHoldings(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-	client := cli.NewClientFromConfig(config)
-
-	var holdings []struct {
-		ID                    uint    `json:"id"`
-		Symbol                string  `json:"symbol"`
-		Quantity              float64 `json:"quantity"`
-		AverageCost           float64 `json:"average_cost"`
-		TotalCost             float64 `json:"total_cost"`
-		CurrentValue          float64 `json:"current_value"`
-		UnrealizedGain        float64 `json:"unrealized_gain"`
-		UnrealizedGainPercent float64 `json:"unrealized_gain_percent"`
+	var asOf time.Time
+	if holdingsAsOf != "" {
+		if asOf, err = parseDate(holdingsAsOf); err != nil {
+			return err
+		}
 	}
 
-	if err := client.Request("GET", "/api/v1/portfolios/"+portfolioID+"/holdings", nil, &holdings); err != nil {
+	var holdings *client.HoldingListResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		if !asOf.IsZero() {
+			holdings, err = c.ListHoldingsAsOf(cmd.Context(), args[0], asOf)
+			return err
+		}
+		holdings, err = c.ListHoldings(cmd.Context(), args[0])
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
-	if len(holdings) == 0 {
+	format := cli.OutputFormat(config.OutputFormat)
+	if len(holdings.Holdings) == 0 && format == cli.OutputFormatTable {
 		cli.PrintInfo("No holdings found for this portfolio")
 		return nil
 	}
 
-	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
+	headers := []string{"Symbol", "Quantity", "Avg Cost", "Cost Basis", "Market Value", "Unrealized Gain", "Return %", "Status"}
+	rows := make([][]string, len(holdings.Holdings))
+
+	for i, h := range holdings.Holdings {
+		status := ""
+		if h.TradingRestriction != nil {
+			status = strings.ToUpper(string(h.TradingRestriction.Status))
+		}
+		rows[i] = []string{
+			h.Symbol,
+			h.Quantity.String(),
+			formatMoney(h.AvgCostPrice),
+			formatMoney(h.CostBasis),
+			formatOptional(h.MarketValue, formatMoney),
+			formatOptional(h.UnrealizedGain, formatGain),
+			formatOptional(h.UnrealizedGainPct, formatPercent),
+			status,
+		}
+	}
+
+	return cli.Output(format, headers, rows, holdings)
+}
+
+func runPortfolioReconcile(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	fileData, err := readImportFile(args[1])
+	if err != nil {
+		return err
+	}
+
+	var report *client.ReconciliationReport
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		report, err = c.Reconcile(cmd.Context(), args[0], client.ReconcileRequest{
+			CSVData:           string(fileData),
+			CreateAdjustments: createAdjustments,
+		})
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	headers := []string{"Symbol", "Quantity", "Avg Cost", "Total Cost", "Current Value", "Unrealized Gain", "Return %"}
-	rows := make([][]string, len(holdings))
+	format := cli.OutputFormat(config.OutputFormat)
+	if format == cli.OutputFormatTable {
+		if len(report.Discrepancies) == 0 {
+			cli.PrintSuccess(fmt.Sprintf("All %d positions match", report.Positions))
+			return nil
+		}
+		for _, importErr := range report.Errors {
+			cli.PrintWarning(importErr.Message)
+		}
+		if report.Adjusted > 0 {
+			cli.PrintSuccess(fmt.Sprintf("Recorded %d adjustments", report.Adjusted))
+		}
+	}
 
-	for i, h := range holdings {
+	headers := []string{"Symbol", "Statement Qty", "Holding Qty", "Difference", "Statement Cost", "Holding Cost", "Adjustment"}
+	rows := make([][]string, len(report.Discrepancies))
+	for i, d := range report.Discrepancies {
+		adjustment := ""
+		if d.Adjustment != nil {
+			adjustment = fmt.Sprintf("%s %s @ %s", d.Adjustment.Type, d.Adjustment.Quantity, formatMoney(d.Adjustment.Price))
+		}
 		rows[i] = []string{
+			d.Symbol,
+			d.StatementQuantity.String(),
+			d.HoldingQuantity.String(),
+			d.QuantityDifference.String(),
+			formatOptional(d.StatementCostBasis, formatMoney),
+			formatMoney(d.HoldingCostBasis),
+			adjustment,
+		}
+	}
+
+	return cli.Output(format, headers, rows, report)
+}
+
+// holdingValue is a holding valued at its current market price
+type holdingValue struct {
+	Symbol         string          `json:"symbol"`
+	Quantity       decimal.Decimal `json:"quantity"`
+	Price          decimal.Decimal `json:"price"`
+	MarketValue    decimal.Decimal `json:"market_value"`
+	CostBasis      decimal.Decimal `json:"cost_basis"`
+	UnrealizedGain decimal.Decimal `json:"unrealized_gain"`
+	ReturnPct      decimal.Decimal `json:"return_pct"`
+	WeightPct      decimal.Decimal `json:"weight_pct"`
+}
+
+// portfolioValuation is a portfolio's holdings valued at current market prices
+type portfolioValuation struct {
+	Holdings       []holdingValue  `json:"holdings"`
+	MarketValue    decimal.Decimal `json:"market_value"`
+	CostBasis      decimal.Decimal `json:"cost_basis"`
+	UnrealizedGain decimal.Decimal `json:"unrealized_gain"`
+	ReturnPct      decimal.Decimal `json:"return_pct"`
+}
+
+func runPortfolioValue(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var holdings *client.HoldingListResponse
+	var quotes *client.QuotesResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		if holdings, err = c.ListHoldings(cmd.Context(), args[0]); err != nil {
+			return err
+		}
+		symbols := make([]string, len(holdings.Holdings))
+		for i, h := range holdings.Holdings {
+			symbols[i] = h.Symbol
+		}
+		if len(symbols) == 0 {
+			quotes = &client.QuotesResponse{}
+			return nil
+		}
+		quotes, err = c.GetQuotes(cmd.Context(), symbols)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	valuation := valuePortfolio(holdings.Holdings, quotes.Quotes)
+
+	format := cli.OutputFormat(config.OutputFormat)
+	if format == cli.OutputFormatJSON {
+		return cli.OutputJSON(valuation)
+	}
+	if len(valuation.Holdings) == 0 && format == cli.OutputFormatTable {
+		cli.PrintInfo("No holdings found for this portfolio")
+		return nil
+	}
+
+	headers := []string{"Symbol", "Quantity", "Price", "Market Value", "Cost Basis", "Unrealized Gain", "Return %", "Weight %"}
+	rows := make([][]string, 0, len(valuation.Holdings)+1)
+
+	for _, h := range valuation.Holdings {
+		rows = append(rows, []string{
 			h.Symbol,
-			fmt.Sprintf("%.4f", h.Quantity),
-			fmt.Sprintf("$%.2f", h.AverageCost),
-			fmt.Sprintf("$%.2f", h.TotalCost),
-			fmt.Sprintf("$%.2f", h.CurrentValue),
+			h.Quantity.String(),
+			formatMoney(h.Price),
+			formatMoney(h.MarketValue),
+			formatMoney(h.CostBasis),
 			formatGain(h.UnrealizedGain),
-			fmt.Sprintf("%.2f%%", h.UnrealizedGainPercent),
+			formatPercent(h.ReturnPct),
+			formatPercent(h.WeightPct),
+		})
+	}
+	rows = append(rows, []string{
+		"Total", "", "",
+		formatMoney(valuation.MarketValue),
+		formatMoney(valuation.CostBasis),
+		formatGain(valuation.UnrealizedGain),
+		formatPercent(valuation.ReturnPct),
+		formatPercent(decimal.NewFromInt(100)),
+	})
+
+	return cli.Output(format, headers, rows, valuation)
+}
+
+// valuePortfolio values holdings at the prices in quotes. Holdings without a quote are left out.
+func valuePortfolio(holdings []*client.HoldingResponse, quotes map[string]*client.QuoteResponse) portfolioValuation {
+	hundred := decimal.NewFromInt(100)
+	var valuation portfolioValuation
+
+	for _, h := range holdings {
+		quote := quotes[h.Symbol]
+		if quote == nil {
+			continue
 		}
+		value := holdingValue{
+			Symbol:      h.Symbol,
+			Quantity:    h.Quantity,
+			Price:       quote.Price,
+			MarketValue: h.Quantity.Mul(quote.Price),
+			CostBasis:   h.CostBasis,
+		}
+		value.UnrealizedGain = value.MarketValue.Sub(value.CostBasis)
+		if value.CostBasis.IsPositive() {
+			value.ReturnPct = value.UnrealizedGain.Div(value.CostBasis).Mul(hundred)
+		}
+		valuation.Holdings = append(valuation.Holdings, value)
+		valuation.MarketValue = valuation.MarketValue.Add(value.MarketValue)
+		valuation.CostBasis = valuation.CostBasis.Add(value.CostBasis)
 	}
 
-	return cli.Output(format, headers, rows, holdings)
+	valuation.UnrealizedGain = valuation.MarketValue.Sub(valuation.CostBasis)
+	if valuation.CostBasis.IsPositive() {
+		valuation.ReturnPct = valuation.UnrealizedGain.Div(valuation.CostBasis).Mul(hundred)
+	}
+	if valuation.MarketValue.IsPositive() {
+		for i := range valuation.Holdings {
+			valuation.Holdings[i].WeightPct = valuation.Holdings[i].MarketValue.Div(valuation.MarketValue).Mul(hundred)
+		}
+	}
+
+	return valuation
+}
+
+// printPortfolio prints a portfolio's fields as key-value pairs
+func runPortfolioImport(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	format, ok := trackerFormats[strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(importTracker))]
+	if !ok {
+		return fmt.Errorf("unknown tracker: %s", importTracker)
+	}
+
+	// Read the export provided by user
+	var fileData []byte
+	if args[0] == "-" {
+		fileData, err = io.ReadAll(os.Stdin)
+	} else {
+		// #nosec G304 - File path is intentionally provided by user for import functionality
+		fileData, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read export file: %w", err)
+	}
+
+	importReq := client.TrackerImportRequest{
+		Format:          format,
+		Data:            string(fileData),
+		CostBasisMethod: client.CostBasisMethod(enumValue(importCostBasis)),
+		DryRun:          dryRun,
+		SkipInvalid:     skipInvalid,
+		Notes:           importNotes,
+	}
+
+	// The result lists the activity errors when activities are invalid
+	var result *client.TrackerImportResult
+	importErr := cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		result, err = c.ImportTracker(cmd.Context(), importReq)
+		return err
+	})
+	if result == nil {
+		return importErr
+	}
+	if importErr == nil && !result.Success {
+		importErr = fmt.Errorf("import failed: %d activities had errors", result.ErrorCount)
+	}
+
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
+		if err := cli.OutputJSON(result); err != nil {
+			return err
+		}
+		return importErr
+	}
+
+	switch {
+	case importErr != nil && len(result.Portfolios) == 0:
+		cli.PrintError("Import failed - no portfolios were created")
+	case importErr != nil:
+		cli.PrintError("Import failed for some accounts")
+	case result.ValidationOnly:
+		cli.PrintInfo("Dry run completed - no portfolios were created")
+	default:
+		cli.PrintSuccess("Import completed!")
+	}
+
+	if len(result.Portfolios) > 0 {
+		fmt.Println()
+		headers := []string{"Account", "Portfolio", "Transactions", "Skipped", "Tax Lots"}
+		rows := make([][]string, len(result.Portfolios))
+		for i, imported := range result.Portfolios {
+			portfolioID := "-"
+			if imported.Portfolio != nil {
+				portfolioID = imported.Portfolio.ID.String()
+			}
+			rows[i] = []string{
+				imported.Account,
+				portfolioID,
+				fmt.Sprintf("%d", imported.Result.SuccessCount),
+				fmt.Sprintf("%d", imported.Result.SkippedCount),
+				fmt.Sprintf("%d", imported.TaxLots),
+			}
+		}
+		cli.OutputTable(headers, rows)
+	}
+
+	if result.IgnoredCount > 0 {
+		fmt.Println()
+		cli.PrintInfo(fmt.Sprintf("%d cash-only activities (deposits, interest, fees) were not imported", result.IgnoredCount))
+	}
+
+	if len(result.Errors) > 0 {
+		fmt.Println()
+		cli.PrintWarning("Errors encountered:")
+		for _, importError := range result.Errors {
+			if importError.Line > 0 {
+				fmt.Printf("  - activity %d: %s\n", importError.Line, importError.Message)
+			} else {
+				fmt.Println("  - " + importError.Message)
+			}
+		}
+	}
+
+	return importErr
+}
+
+func printPortfolio(portfolio *client.PortfolioResponse) {
+	fmt.Println(cli.RenderKeyValue("ID", portfolio.ID.String()))
+	fmt.Println(cli.RenderKeyValue("Name", portfolio.Name))
+	if portfolio.Description != "" {
+		fmt.Println(cli.RenderKeyValue("Description", portfolio.Description))
+	}
+	fmt.Println(cli.RenderKeyValue("Base Currency", portfolio.BaseCurrency))
+	fmt.Println(cli.RenderKeyValue("Cost Basis Method", string(portfolio.CostBasisMethod)))
+	fmt.Println(cli.RenderKeyValue("Created", portfolio.CreatedAt.Format(dateLayout)))
+	fmt.Println(cli.RenderKeyValue("Last Updated", portfolio.UpdatedAt.Format(dateLayout)))
 }
 
 // Helper functions
@@ -297,9 +638,38 @@ func truncate(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-func formatGain(gain float64) string {
-	if gain >= 0 {
-		return fmt.Sprintf("+$%.2f", gain)
+// enumValue turns a flag value such as "dividend-reinvest" into the API's enum spelling,
+// "DIVIDEND_REINVEST"
+func enumValue(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), "-", "_"))
+}
+
+func formatMoney(amount decimal.Decimal) string {
+	return "$" + amount.StringFixed(2)
+}
+
+func formatGain(gain decimal.Decimal) string {
+	if gain.IsNegative() {
+		return "-$" + gain.Neg().StringFixed(2)
+	}
+	return "+$" + gain.StringFixed(2)
+}
+
+func formatPercent(pct decimal.Decimal) string {
+	return pct.StringFixed(2) + "%"
+}
+
+func formatDifference(diff decimal.Decimal) string {
+	if diff.IsNegative() {
+		return diff.StringFixed(2) + "%"
+	}
+	return "+" + diff.StringFixed(2) + "%"
+}
+
+// formatOptional formats a value the API may omit, showing "-" when it did
+func formatOptional(value *decimal.Decimal, format func(decimal.Decimal) string) string {
+	if value == nil {
+		return "-"
 	}
-	return fmt.Sprintf("-$%.2f", -gain)
+	return format(*value)
 }
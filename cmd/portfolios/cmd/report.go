@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportBenchmark       string
+	reportStatementPeriod string
+	reportStatementFormat string
+	reportStatementFile   string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate reports",
+	Long:  "Generate reports across portfolios, suitable for scripting with --output json or csv",
+}
+
+var reportPerformanceCmd = &cobra.Command{
+	Use:   "performance [portfolio-id...]",
+	Short: "Performance report",
+	Long: `Report performance metrics for the given portfolios, or for all of your portfolios
+when none are given, with one row per portfolio.
+
+  portfolios report performance --start 2024-01-01 --end 2024-12-31 --benchmark SPY -o csv`,
+	RunE: runReportPerformance,
+}
+
+var reportStatementCmd = &cobra.Command{
+	Use:   "statement <portfolio-id>",
+	Short: "Portfolio statement",
+	Long: `Download a portfolio's statement for a month or quarter, with its holdings at the
+end of the period, transactions, performance and dividend income. Statements are PDF
+documents; use --format html for a page to preview in a browser.
+
+  portfolios report statement <portfolio-id> --period 2024-Q4
+  portfolios report statement <portfolio-id> --period 2024-11 --format html --file november.html`,
+	Args: cobra.ExactArgs(1),
+	RunE: runReportStatement,
+}
+
+func init() {
+	reportCmd.AddCommand(reportPerformanceCmd)
+	reportCmd.AddCommand(reportStatementCmd)
+
+	reportPerformanceCmd.Flags().StringVar(&startDate, "start", "", "Start date (YYYY-MM-DD)")
+	reportPerformanceCmd.Flags().StringVar(&endDate, "end", "", "End date (YYYY-MM-DD)")
+	reportPerformanceCmd.Flags().StringVar(&reportBenchmark, "benchmark", "", "Also compare each portfolio against this symbol, e.g. SPY")
+
+	reportStatementCmd.Flags().StringVar(&reportStatementPeriod, "period", "", "Month (2024-11) or quarter (2024-Q4)")
+	reportStatementCmd.Flags().StringVar(&reportStatementFormat, "format", "pdf", "Document format (pdf|html)")
+	reportStatementCmd.Flags().StringVar(&reportStatementFile, "file", "", "File to write, statement-<period>.<format> by default; - for standard output")
+	_ = reportStatementCmd.MarkFlagRequired("period")
+}
+
+func runReportPerformance(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	period, err := dateRange(startDate, endDate)
+	if err != nil {
+		return err
+	}
+
+	portfolioIDs := args
+	if len(portfolioIDs) == 0 {
+		var portfolios *client.PortfolioListResponse
+		err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+			portfolios, err = c.ListPortfolios(cmd.Context())
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		for _, p := range portfolios.Portfolios {
+			portfolioIDs = append(portfolioIDs, p.ID.String())
+		}
+	}
+
+	if len(portfolioIDs) == 0 {
+		cli.PrintInfo("No portfolios found. Create one with 'portfolios portfolio create'")
+		return nil
+	}
+
+	return outputPerformanceReport(cmd.Context(), config, portfolioIDs, period, reportBenchmark)
+}
+
+func runReportStatement(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	var document []byte
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		document, err = c.GetStatement(cmd.Context(), args[0], reportStatementPeriod, client.StatementFormat(reportStatementFormat))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if reportStatementFile == "-" {
+		_, err = os.Stdout.Write(document)
+		return err
+	}
+
+	file := reportStatementFile
+	if file == "" {
+		file = fmt.Sprintf("statement-%s.%s", reportStatementPeriod, reportStatementFormat)
+	}
+	if err := os.WriteFile(file, document, 0600); err != nil {
+		return fmt.Errorf("failed to write statement: %w", err)
+	}
+
+	cli.PrintSuccess(fmt.Sprintf("Statement saved to %s", file))
+	return nil
+}
@@ -2,7 +2,8 @@ package cmd
 
 import (
 	"fmt"
-	"os"
+	"io"
+	"log"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/lenon/portfolios/internal/cli"
@@ -13,12 +14,16 @@ var (
 	cfgFile      string
 	outputFormat string
 	apiBaseURL   string
+	localMode    bool
+	localDB      string
 )
 
 // rootCmd represents the base command
 var rootCmd = &cobra.Command{
 	Use:   "portfolios",
 	Short: "Portfolio management CLI",
+	// Errors from the API are not usage mistakes, so don't follow them with the usage
+	SilenceUsage: true,
 	Long: renderBanner() + `
 
 A powerful command-line interface for managing your investment portfolios.
@@ -33,6 +38,12 @@ Features:
 Get started by logging in:
   portfolios auth login
 
+Or authenticate scripts with an API key:
+  export PORTFOLIOS_API_KEY=pfk_...
+
+Or skip the server and keep your data in a local SQLite database:
+  portfolios --local portfolio create --name "Personal"
+
 For more information, visit: https://github.com/lenon/portfolios`,
 }
 
@@ -48,22 +59,31 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.portfolios/config.yaml)")
 	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "output format (table|json|csv)")
 	rootCmd.PersistentFlags().StringVar(&apiBaseURL, "api-url", "", "API base URL (default is http://localhost:8080)")
+	rootCmd.PersistentFlags().BoolVar(&localMode, "local", false, "work on a local SQLite database instead of the API server")
+	rootCmd.PersistentFlags().StringVar(&localDB, "local-db", "", "local SQLite database, implies --local (default is $HOME/.portfolios/portfolios.db)")
 
 	// Add subcommands
 	rootCmd.AddCommand(authCmd)
 	rootCmd.AddCommand(portfolioCmd)
 	rootCmd.AddCommand(transactionCmd)
 	rootCmd.AddCommand(performanceCmd)
+	rootCmd.AddCommand(reportCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(loadtestCmd)
+	rootCmd.AddCommand(seedCmd)
 	rootCmd.AddCommand(versionCmd)
 }
 
-// initConfig reads in config file and ENV variables if set
+// initConfig points the CLI at the config file given with --config, if any
 func initConfig() {
+	cli.SetConfigPath(cfgFile)
+}
+
+// loadConfig loads the CLI configuration and applies the global flag overrides
+func loadConfig() (*cli.Config, error) {
 	config, err := cli.LoadConfig()
 	if err != nil {
-		cli.PrintError(fmt.Sprintf("Failed to load config: %v", err))
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
 
 	// Override config with flags if provided
@@ -73,6 +93,17 @@ func initConfig() {
 	if outputFormat != "" {
 		config.OutputFormat = outputFormat
 	}
+	if localDB != "" {
+		config.LocalDatabase = localDB
+		localMode = true
+	}
+	config.Local = localMode
+	if config.Local {
+		// The database package narrates connections and migrations, which is server chatter
+		log.SetOutput(io.Discard)
+	}
+
+	return config, nil
 }
 
 // renderBanner returns a styled banner for the CLI
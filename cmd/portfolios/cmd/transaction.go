@@ -2,18 +2,31 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/pkg/client"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
 var (
-	transactionType   string
-	transactionBroker string
-	dryRun            bool
+	transactionType       string
+	transactionSymbol     string
+	transactionQuantity   string
+	transactionPrice      string
+	transactionCommission string
+	transactionDate       string
+	transactionCurrency   string
+	transactionNotes      string
+	transactionCategory   string
+	transactionBroker     string
+	importNotes           string
+	dryRun                bool
+	skipInvalid           bool
 )
 
 var transactionCmd = &cobra.Command{
@@ -35,25 +48,38 @@ var transactionListCmd = &cobra.Command{
 var transactionAddCmd = &cobra.Command{
 	Use:   "add <portfolio-id>",
 	Short: "Add a transaction",
-	Long:  "Manually add a buy or sell transaction to a portfolio",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runTransactionAdd,
+	Long: `Add a transaction to a portfolio. Values not given as flags are prompted for, so
+'portfolios tx add <portfolio-id> --type buy --symbol AAPL --quantity 10 --price 150'
+runs without any prompts. The date defaults to today.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runTransactionAdd,
 }
 
 var transactionImportCmd = &cobra.Command{
 	Use:   "import <portfolio-id> <csv-file>",
 	Short: "Import transactions from CSV",
-	Long:  "Import transactions from a CSV file (supports multiple broker formats)",
+	Long:  "Import transactions from a CSV file (supports multiple broker formats). Use - to read the file from standard input.",
 	Args:  cobra.ExactArgs(2),
 	RunE:  runTransactionImport,
 }
 
+var transactionImportLotsCmd = &cobra.Command{
+	Use:   "import-lots <portfolio-id> <csv-file>",
+	Short: "Import opening lots from CSV",
+	Long: `Import the lots a portfolio held before its recorded history from a CSV file with
+Symbol, Quantity, Cost Basis and Purchase Date columns, and optional Currency and Notes
+columns. Each lot is recorded as an opening balance transaction. Use - to read the file
+from standard input.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runTransactionImportLots,
+}
+
 var transactionDeleteCmd = &cobra.Command{
-	Use:     "delete <portfolio-id> <transaction-id>",
+	Use:     "delete <transaction-id>",
 	Aliases: []string{"rm", "remove"},
 	Short:   "Delete a transaction",
-	Long:    "Delete a specific transaction from a portfolio",
-	Args:    cobra.ExactArgs(2),
+	Long:    "Delete a specific transaction",
+	Args:    cobra.ExactArgs(1),
 	RunE:    runTransactionDelete,
 }
 
@@ -74,174 +100,192 @@ var transactionBatchDeleteCmd = &cobra.Command{
 	RunE:  runTransactionBatchDelete,
 }
 
+// importFormats maps the --broker values, with dashes and underscores removed, to import formats
+var importFormats = map[string]client.ImportFormat{
+	"generic":            client.ImportFormatGeneric,
+	"fidelity":           client.ImportFormatFidelity,
+	"schwab":             client.ImportFormatSchwab,
+	"tdameritrade":       client.ImportFormatTDAmeritrade,
+	"etrade":             client.ImportFormatETrade,
+	"interactivebrokers": client.ImportFormatInteractiveBrokers,
+	"ibkr":               client.ImportFormatInteractiveBrokers,
+	"robinhood":          client.ImportFormatRobinhood,
+}
+
 func init() {
 	transactionCmd.AddCommand(transactionListCmd)
 	transactionCmd.AddCommand(transactionAddCmd)
 	transactionCmd.AddCommand(transactionImportCmd)
+	transactionCmd.AddCommand(transactionImportLotsCmd)
 	transactionCmd.AddCommand(transactionDeleteCmd)
 	transactionCmd.AddCommand(transactionBatchListCmd)
 	transactionCmd.AddCommand(transactionBatchDeleteCmd)
 
 	// Add flags
-	transactionAddCmd.Flags().StringVarP(&transactionType, "type", "t", "buy", "Transaction type (buy|sell)")
+	transactionListCmd.Flags().StringVarP(&transactionSymbol, "symbol", "s", "", "Only list transactions in this symbol")
+
+	transactionAddCmd.Flags().StringVarP(&transactionType, "type", "t", "buy", "Transaction type (buy|sell|dividend|dividend-reinvest|split|merger|spinoff|management-fee|adr-fee|fx-fee|lending-income|interest|rebate|deposit|withdrawal|opening-balance)")
+	transactionAddCmd.Flags().StringVarP(&transactionSymbol, "symbol", "s", "", "Symbol, e.g. AAPL, or the currency of a deposit or withdrawal")
+	transactionAddCmd.Flags().StringVarP(&transactionQuantity, "quantity", "q", "", "Number of shares")
+	transactionAddCmd.Flags().StringVarP(&transactionPrice, "price", "p", "", "Price per share")
+	transactionAddCmd.Flags().StringVar(&transactionCommission, "commission", "0", "Commission paid")
+	transactionAddCmd.Flags().StringVarP(&transactionDate, "date", "d", "", "Trade date (YYYY-MM-DD, default today)")
+	transactionAddCmd.Flags().StringVar(&transactionCurrency, "currency", "", "Currency (default is the portfolio's base currency)")
+	transactionAddCmd.Flags().StringVar(&transactionNotes, "notes", "", "Notes")
+	transactionAddCmd.Flags().StringVar(&transactionCategory, "category", "", "Income category of lending income, interest and rebates (default is the type)")
+
 	transactionImportCmd.Flags().StringVarP(&transactionBroker, "broker", "b", "generic", "Broker format (generic|fidelity|schwab|tdameritrade|etrade|interactivebrokers|robinhood)")
 	transactionImportCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate without importing")
+	transactionImportCmd.Flags().BoolVar(&skipInvalid, "skip-invalid", false, "Import the valid rows and skip the invalid ones")
+	transactionImportCmd.Flags().StringVar(&importNotes, "notes", "", "Notes stored with the import batch")
+
+	transactionImportLotsCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate without importing")
+	transactionImportLotsCmd.Flags().BoolVar(&skipInvalid, "skip-invalid", false, "Import the valid lots and skip the invalid ones")
+	transactionImportLotsCmd.Flags().StringVar(&importNotes, "notes", "", "Notes stored with the import batch")
+
+	transactionDeleteCmd.Flags().BoolVarP(&skipConfirmation, "yes", "y", false, "Delete without asking for confirmation")
+	transactionBatchDeleteCmd.Flags().BoolVarP(&skipConfirmation, "yes", "y", false, "Delete without asking for confirmation")
 }
 
 func runTransactionList(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-	client := cli.NewClientFromConfig(config)
-
-	var transactions []struct {
-		ID              uint    `json:"id"`
-		Type            string  `json:"type"`
-		Symbol          string  `json:"symbol"`
-		Quantity        float64 `json:"quantity"`
-		Price           float64 `json:"price"`
-		Commission      float64 `json:"commission"`
-		TransactionDate string  `json:"transaction_date"`
-		Notes           string  `json:"notes"`
-	}
-
-	if err := client.Request("GET", "/api/v1/portfolios/"+portfolioID+"/transactions", nil, &transactions); err != nil {
+	var transactions *client.TransactionListResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		transactions, err = c.ListTransactions(cmd.Context(), args[0], strings.ToUpper(transactionSymbol))
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
-	if len(transactions) == 0 {
+	format := cli.OutputFormat(config.OutputFormat)
+	if len(transactions.Transactions) == 0 && format == cli.OutputFormatTable {
 		cli.PrintInfo("No transactions found for this portfolio")
 		return nil
 	}
 
-	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
-	}
-
 	headers := []string{"ID", "Type", "Symbol", "Quantity", "Price", "Commission", "Date", "Notes"}
-	rows := make([][]string, len(transactions))
+	rows := make([][]string, len(transactions.Transactions))
 
-	for i, tx := range transactions {
+	for i, tx := range transactions.Transactions {
 		rows[i] = []string{
-			fmt.Sprintf("%d", tx.ID),
-			strings.ToUpper(tx.Type),
+			tx.ID.String(),
+			string(tx.Type),
 			tx.Symbol,
-			fmt.Sprintf("%.4f", tx.Quantity),
-			fmt.Sprintf("$%.2f", tx.Price),
-			fmt.Sprintf("$%.2f", tx.Commission),
-			tx.TransactionDate,
+			tx.Quantity.String(),
+			formatOptional(tx.Price, formatMoney),
+			formatMoney(tx.Commission),
+			tx.Date.Format(dateLayout),
 			truncate(tx.Notes, 30),
 		}
 	}
 
-	return cli.Output(format, headers, rows, transactions)
+	return cli.Output(format, headers, rows, transactions.Transactions)
 }
 
 func runTransactionAdd(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-
-	// Prompt for transaction details
-	txType, err := cli.ReadInput(fmt.Sprintf("Transaction Type [%s]", transactionType))
-	if err != nil {
-		return err
-	}
-	if txType == "" {
-		txType = transactionType
-	}
+	txType := client.TransactionType(enumValue(transactionType))
 
-	symbol, err := cli.ReadInput("Symbol (e.g., AAPL)")
-	if err != nil {
-		return err
+	// Prompt for the transaction details that weren't given as flags
+	symbol := transactionSymbol
+	if symbol == "" {
+		if symbol, err = cli.ReadInput("Symbol (e.g., AAPL)"); err != nil {
+			return err
+		}
 	}
 
-	quantityStr, err := cli.ReadInput("Quantity")
-	if err != nil {
-		return err
+	quantityStr := transactionQuantity
+	if quantityStr == "" {
+		if quantityStr, err = cli.ReadInput("Quantity"); err != nil {
+			return err
+		}
 	}
-	quantity, err := strconv.ParseFloat(quantityStr, 64)
+	quantity, err := decimal.NewFromString(quantityStr)
 	if err != nil {
 		return fmt.Errorf("invalid quantity: %w", err)
 	}
 
-	priceStr, err := cli.ReadInput("Price per share")
-	if err != nil {
-		return err
-	}
-	price, err := strconv.ParseFloat(priceStr, 64)
-	if err != nil {
-		return fmt.Errorf("invalid price: %w", err)
-	}
-
-	commissionStr, err := cli.ReadInput("Commission (0 if none)")
-	if err != nil {
-		return err
+	priceStr := transactionPrice
+	if priceStr == "" && (txType == client.TransactionTypeBuy || txType == client.TransactionTypeSell) {
+		if priceStr, err = cli.ReadInput("Price per share"); err != nil {
+			return err
+		}
 	}
-	commission := 0.0
-	if commissionStr != "" {
-		commission, err = strconv.ParseFloat(commissionStr, 64)
+	var price *decimal.Decimal
+	if priceStr != "" {
+		parsed, err := decimal.NewFromString(priceStr)
 		if err != nil {
-			return fmt.Errorf("invalid commission: %w", err)
+			return fmt.Errorf("invalid price: %w", err)
 		}
+		price = &parsed
 	}
 
-	date, err := cli.ReadInput("Date (YYYY-MM-DD)")
+	commission, err := decimal.NewFromString(transactionCommission)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid commission: %w", err)
 	}
 
-	notes, err := cli.ReadInput("Notes (optional)")
-	if err != nil {
-		return err
+	date := time.Now().UTC().Truncate(24 * time.Hour)
+	if transactionDate != "" {
+		if date, err = parseDate(transactionDate); err != nil {
+			return err
+		}
 	}
 
-	client := cli.NewClientFromConfig(config)
-
-	createReq := map[string]interface{}{
-		"type":             txType,
-		"symbol":           strings.ToUpper(symbol),
-		"quantity":         quantity,
-		"price":            price,
-		"commission":       commission,
-		"transaction_date": date,
-		"notes":            notes,
+	createReq := client.CreateTransactionRequest{
+		Type:       txType,
+		Symbol:     strings.ToUpper(symbol),
+		Date:       date,
+		Quantity:   quantity,
+		Price:      price,
+		Commission: commission,
+		Currency:   strings.ToUpper(transactionCurrency),
+		Notes:      transactionNotes,
+		Category:   transactionCategory,
 	}
 
-	var transaction struct {
-		ID              uint    `json:"id"`
-		Type            string  `json:"type"`
-		Symbol          string  `json:"symbol"`
-		Quantity        float64 `json:"quantity"`
-		Price           float64 `json:"price"`
-		TransactionDate string  `json:"transaction_date"`
+	var transaction *client.TransactionResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		transaction, err = c.CreateTransaction(cmd.Context(), args[0], createReq)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	if err := client.Request("POST", "/api/v1/portfolios/"+portfolioID+"/transactions", createReq, &transaction); err != nil {
-		return err
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
+		return cli.OutputJSON(transaction)
 	}
 
 	cli.PrintSuccess("Transaction added successfully!")
 	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("ID", fmt.Sprintf("%d", transaction.ID)))
-	fmt.Println(cli.RenderKeyValue("Type", strings.ToUpper(transaction.Type)))
+	fmt.Println(cli.RenderKeyValue("ID", transaction.ID.String()))
+	fmt.Println(cli.RenderKeyValue("Type", string(transaction.Type)))
 	fmt.Println(cli.RenderKeyValue("Symbol", transaction.Symbol))
-	fmt.Println(cli.RenderKeyValue("Quantity", fmt.Sprintf("%.4f", transaction.Quantity)))
-	fmt.Println(cli.RenderKeyValue("Price", fmt.Sprintf("$%.2f", transaction.Price)))
-	fmt.Println(cli.RenderKeyValue("Total", fmt.Sprintf("$%.2f", transaction.Quantity*transaction.Price)))
+	fmt.Println(cli.RenderKeyValue("Date", transaction.Date.Format(dateLayout)))
+	fmt.Println(cli.RenderKeyValue("Quantity", transaction.Quantity.String()))
+	if transaction.Price != nil {
+		fmt.Println(cli.RenderKeyValue("Price", formatMoney(*transaction.Price)))
+		fmt.Println(cli.RenderKeyValue("Total", formatMoney(transaction.Quantity.Mul(*transaction.Price))))
+	}
+	for _, warning := range transaction.Warnings {
+		cli.PrintWarning(warning)
+	}
 
 	return nil
 }
 
 func runTransactionImport(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
@@ -249,86 +293,184 @@ func runTransactionImport(cmd *cobra.Command, args []string) error {
 	portfolioID := args[0]
 	csvFile := args[1]
 
-	// Read CSV file provided by user
-	// #nosec G304 - File path is intentionally provided by user for import functionality
-	fileData, err := os.ReadFile(csvFile)
+	format, ok := importFormats[strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(transactionBroker))]
+	if !ok {
+		return fmt.Errorf("unknown broker format: %s", transactionBroker)
+	}
+
+	fileData, err := readImportFile(csvFile)
 	if err != nil {
-		return fmt.Errorf("failed to read CSV file: %w", err)
+		return err
 	}
 
-	client := cli.NewClientFromConfig(config)
+	importReq := client.CSVImportRequest{
+		Format:      format,
+		CSVData:     string(fileData),
+		DryRun:      dryRun,
+		SkipInvalid: skipInvalid,
+		Notes:       importNotes,
+	}
 
-	// Prepare additional fields
-	additionalFields := map[string]string{
-		"broker": transactionBroker,
+	// The server imports the rows in the background; follow its progress until it's done
+	showProgress := cli.OutputFormat(config.OutputFormat) != cli.OutputFormatJSON
+	var progress *client.ImportProgress
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) error {
+		job, err := c.ImportCSV(cmd.Context(), portfolioID, importReq)
+		if err != nil {
+			return err
+		}
+		progress, err = c.WatchImport(cmd.Context(), portfolioID, job.BatchID.String(), func(p *client.ImportProgress) {
+			if showProgress && p.TotalRows > 0 {
+				fmt.Fprintf(os.Stderr, "\rProcessed %d of %d rows", p.ProcessedRows, p.TotalRows)
+			}
+		})
+		return err
+	})
+	if showProgress && progress != nil && progress.TotalRows > 0 {
+		fmt.Fprintln(os.Stderr)
 	}
-	if dryRun {
-		additionalFields["dry_run"] = "true"
+	if err != nil {
+		return err
+	}
+	if progress.Status == client.ImportStatusFailed {
+		return fmt.Errorf("import failed: %s", progress.Error)
 	}
 
-	var importResp struct {
-		Success       int      `json:"success"`
-		Failed        int      `json:"failed"`
-		Total         int      `json:"total"`
-		Errors        []string `json:"errors"`
-		ImportBatchID string   `json:"import_batch_id"`
-		DryRun        bool     `json:"dry_run"`
+	result := progress.Result
+	var importErr error
+	if !result.Success || (result.SuccessCount == 0 && result.ErrorCount > 0) {
+		// Rows the server couldn't parse don't fail the import, but nothing was imported
+		importErr = fmt.Errorf("import failed: %d rows had errors", result.ErrorCount)
 	}
 
-	if err := client.UploadFile(
-		"/api/v1/portfolios/"+portfolioID+"/transactions/import/csv",
-		"file",
-		csvFile,
-		fileData,
-		additionalFields,
-		&importResp,
-	); err != nil {
-		return err
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
+		if err := cli.OutputJSON(result); err != nil {
+			return err
+		}
+		return importErr
 	}
 
-	if importResp.DryRun {
-		cli.PrintInfo("Dry run completed - no transactions were imported")
+	printImportResult(result, importErr)
+	return importErr
+}
+
+// readImportFile reads the CSV file provided by the user, or standard input for -
+func readImportFile(csvFile string) ([]byte, error) {
+	var fileData []byte
+	var err error
+	if csvFile == "-" {
+		fileData, err = io.ReadAll(os.Stdin)
 	} else {
+		// #nosec G304 - File path is intentionally provided by user for import functionality
+		fileData, err = os.ReadFile(csvFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV file: %w", err)
+	}
+	return fileData, nil
+}
+
+// printImportResult prints the outcome of an import, importErr being why it failed
+func printImportResult(result *client.ImportResult, importErr error) {
+	switch {
+	case importErr != nil:
+		cli.PrintError("Import failed - no transactions were imported")
+	case result.ValidationOnly:
+		cli.PrintInfo("Dry run completed - no transactions were imported")
+	default:
 		cli.PrintSuccess("Import completed!")
 	}
 
 	fmt.Println()
-	fmt.Println(cli.RenderKeyValue("Total", fmt.Sprintf("%d", importResp.Total)))
-	fmt.Println(cli.RenderKeyValue("Success", fmt.Sprintf("%d", importResp.Success)))
-	fmt.Println(cli.RenderKeyValue("Failed", fmt.Sprintf("%d", importResp.Failed)))
+	fmt.Println(cli.RenderKeyValue("Total Rows", fmt.Sprintf("%d", result.TotalRows)))
+	fmt.Println(cli.RenderKeyValue("Success", fmt.Sprintf("%d", result.SuccessCount)))
+	fmt.Println(cli.RenderKeyValue("Failed", fmt.Sprintf("%d", result.ErrorCount)))
+	if result.SkippedCount > 0 {
+		fmt.Println(cli.RenderKeyValue("Skipped", fmt.Sprintf("%d", result.SkippedCount)))
+	}
 
-	if !importResp.DryRun && importResp.ImportBatchID != "" {
-		fmt.Println(cli.RenderKeyValue("Batch ID", importResp.ImportBatchID))
+	if importErr == nil && !result.ValidationOnly && result.SuccessCount > 0 {
+		fmt.Println(cli.RenderKeyValue("Batch ID", result.BatchID.String()))
 	}
 
-	if len(importResp.Errors) > 0 {
+	if len(result.Errors) > 0 {
 		fmt.Println()
 		cli.PrintWarning("Errors encountered:")
-		for _, errMsg := range importResp.Errors {
-			fmt.Println("  - " + errMsg)
+		for _, importError := range result.Errors {
+			if importError.Line > 0 {
+				fmt.Printf("  - line %d: %s\n", importError.Line, importError.Message)
+			} else {
+				fmt.Println("  - " + importError.Message)
+			}
 		}
 	}
+}
 
-	return nil
+func runTransactionImportLots(cmd *cobra.Command, args []string) error {
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	portfolioID := args[0]
+	fileData, err := readImportFile(args[1])
+	if err != nil {
+		return err
+	}
+
+	importReq := client.OpeningLotImportRequest{
+		CSVData:     string(fileData),
+		DryRun:      dryRun,
+		SkipInvalid: skipInvalid,
+		Notes:       importNotes,
+	}
+
+	// A rejected import still comes with its result, which explains what was wrong
+	var result *client.OpeningLotImportResult
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) error {
+		result, err = c.ImportOpeningLots(cmd.Context(), portfolioID, importReq)
+		return err
+	})
+	if result == nil {
+		return err
+	}
+
+	var importErr error
+	if !result.Result.Success {
+		importErr = fmt.Errorf("import failed: %d lots had errors", result.Result.ErrorCount)
+	}
+
+	if cli.OutputFormat(config.OutputFormat) == cli.OutputFormatJSON {
+		if err := cli.OutputJSON(result); err != nil {
+			return err
+		}
+		return importErr
+	}
+
+	printImportResult(result.Result, importErr)
+	if importErr == nil && !result.Result.ValidationOnly {
+		fmt.Println(cli.RenderKeyValue("Tax Lots", fmt.Sprintf("%d", result.TaxLots)))
+	}
+	return importErr
 }
 
 func runTransactionDelete(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-	transactionID := args[1]
+	transactionID := args[0]
 
-	if !cli.Confirm(fmt.Sprintf("Are you sure you want to delete transaction %s?", transactionID)) {
+	if !skipConfirmation && !cli.Confirm(fmt.Sprintf("Are you sure you want to delete transaction %s?", transactionID)) {
 		cli.PrintInfo("Deletion cancelled")
 		return nil
 	}
 
-	client := cli.NewClientFromConfig(config)
-
-	if err := client.Request("DELETE", "/api/v1/portfolios/"+portfolioID+"/transactions/"+transactionID, nil, nil); err != nil {
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) error {
+		return c.DeleteTransaction(cmd.Context(), transactionID)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -337,50 +479,44 @@ func runTransactionDelete(cmd *cobra.Command, args []string) error {
 }
 
 func runTransactionBatchList(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
 
-	portfolioID := args[0]
-	client := cli.NewClientFromConfig(config)
-
-	var batches []struct {
-		ImportBatchID    string `json:"import_batch_id"`
-		TransactionCount int    `json:"transaction_count"`
-		FirstImportDate  string `json:"first_import_date"`
-	}
-
-	if err := client.Request("GET", "/api/v1/portfolios/"+portfolioID+"/imports/batches", nil, &batches); err != nil {
+	var batches *client.ImportBatchListResponse
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) (err error) {
+		batches, err = c.ListImportBatches(cmd.Context(), args[0])
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
-	if len(batches) == 0 {
+	format := cli.OutputFormat(config.OutputFormat)
+	if len(batches.Batches) == 0 && format == cli.OutputFormatTable {
 		cli.PrintInfo("No import batches found for this portfolio")
 		return nil
 	}
 
-	format := cli.OutputFormat(config.OutputFormat)
-	if outputFormat != "" {
-		format = cli.OutputFormat(outputFormat)
-	}
-
-	headers := []string{"Batch ID", "Transactions", "Import Date"}
-	rows := make([][]string, len(batches))
+	headers := []string{"Batch ID", "Format", "Transactions", "Imported", "Notes"}
+	rows := make([][]string, len(batches.Batches))
 
-	for i, b := range batches {
+	for i, b := range batches.Batches {
 		rows[i] = []string{
-			b.ImportBatchID,
+			b.BatchID.String(),
+			string(b.Format),
 			fmt.Sprintf("%d", b.TransactionCount),
-			b.FirstImportDate,
+			b.ImportedAt.Format(dateLayout),
+			truncate(b.Notes, 30),
 		}
 	}
 
-	return cli.Output(format, headers, rows, batches)
+	return cli.Output(format, headers, rows, batches.Batches)
 }
 
 func runTransactionBatchDelete(cmd *cobra.Command, args []string) error {
-	config, err := cli.LoadConfig()
+	config, err := loadConfig()
 	if err != nil {
 		return err
 	}
@@ -388,14 +524,15 @@ func runTransactionBatchDelete(cmd *cobra.Command, args []string) error {
 	portfolioID := args[0]
 	batchID := args[1]
 
-	if !cli.Confirm(fmt.Sprintf("Are you sure you want to delete all transactions from batch %s?", batchID)) {
+	if !skipConfirmation && !cli.Confirm(fmt.Sprintf("Are you sure you want to delete all transactions from batch %s?", batchID)) {
 		cli.PrintInfo("Deletion cancelled")
 		return nil
 	}
 
-	client := cli.NewClientFromConfig(config)
-
-	if err := client.Request("DELETE", "/api/v1/portfolios/"+portfolioID+"/imports/batches/"+batchID, nil, nil); err != nil {
+	err = cli.Call(cmd.Context(), config, func(c *client.Client) error {
+		return c.DeleteImportBatch(cmd.Context(), portfolioID, batchID)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -0,0 +1,54 @@
+// Command set-user-role sets the role of the user with the given email. Use it to promote the
+// first administrator, who can then manage other users through the admin API:
+//
+//	set-user-role ops@example.com admin
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/lenon/portfolios/internal/app"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "usage: set-user-role <email> <user|admin>")
+		os.Exit(2)
+	}
+	email, role := os.Args[1], models.UserRole(os.Args[2])
+	if !role.IsValid() {
+		log.Fatalf("Invalid role %q: %v", role, models.ErrInvalidRole)
+	}
+
+	// Load configuration from the runtime home directory and environment variables
+	cfg, _, err := app.LoadConfig()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	db, err := database.Connect(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer func() {
+		_ = database.Close()
+	}()
+
+	userRepo := repository.NewUserRepository(db)
+	user, err := userRepo.FindByEmail(email)
+	if err != nil {
+		log.Fatalf("Failed to find user: %v", err)
+	}
+
+	user.Role = role
+	if err := userRepo.Save(user); err != nil {
+		log.Fatalf("Failed to set role: %v", err)
+	}
+
+	fmt.Printf("%s is now %s\n", user.Email, user.Role)
+}
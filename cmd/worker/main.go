@@ -0,0 +1,117 @@
+// Command worker runs the background jobs and queued imports and reports without serving the
+// HTTP API, so that jobs can be scaled and deployed separately from the API servers. Run the
+// API servers with DISABLE_BACKGROUND_JOBS=true when a worker is deployed.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/lenon/portfolios/internal/app"
+	"github.com/lenon/portfolios/internal/jobs"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/server"
+)
+
+func main() {
+	// Load configuration from the runtime home directory and environment variables
+	cfg, homeDir, err := app.LoadConfig()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// Initialize server logger
+	serverLogger, err := logger.NewFileLoggerWithOutputs(
+		cfg.Logging.ServerLogPath,
+		app.ServerLog,
+		cfg.Logging.Level,
+		cfg.Logging.Format,
+		app.LogOutputs(cfg, app.ServerLog),
+	)
+	if err != nil {
+		log.Fatalf("Failed to initialize server logger: %v", err)
+	}
+	defer func() {
+		_ = serverLogger.Close()
+	}()
+
+	serverLogger.Info().
+		Str("home_dir", homeDir.Root).
+		Str("server_log", cfg.Logging.ServerLogPath).
+		Msg("Worker starting with runtime home directory")
+	fmt.Printf("Runtime home directory: %s\n", homeDir.Root)
+	fmt.Printf("Server log: %s\n", cfg.Logging.ServerLogPath)
+
+	// Initialize database connection
+	db, err := app.ConnectDatabase(cfg, serverLogger)
+	if err != nil {
+		serverLogger.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	serverLogger.Info().Msg("Connected to database")
+
+	// The worker serves no HTTP routes, so the admin API is never needed
+	container, err := app.BuildServices(cfg, db, app.WithLogger(serverLogger), app.WithoutAdmin())
+	if err != nil {
+		serverLogger.Fatal().Err(err).Msg("Failed to initialize services")
+	}
+	defer func() {
+		_ = container.Close()
+	}()
+
+	scheduler := container.BuildJobs()
+	serverLogger.Info().Strs("jobs", scheduler.JobNames()).Msg("Starting background job scheduler")
+	fmt.Println("Worker running background jobs")
+	scheduler.Start()
+
+	// Run queued imports, recalculations and reports
+	var workerPool *jobs.WorkerPool
+	if cfg.Server.JobWorkers > 0 {
+		workerPool = container.BuildWorkerPool()
+		workerPool.Start()
+	}
+
+	// Apply changes to the log level, rate limits and job schedules without a restart, when
+	// the config file changes or on SIGHUP
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	if err := container.WatchConfig(watchCtx, homeDir); err != nil {
+		serverLogger.Warn().Err(err).Msg("Configuration changes will need a restart")
+	}
+
+	// Wait for interrupt signal to stop the jobs
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	serverLogger.Info().Msg("Shutdown signal received, stopping background job scheduler")
+	fmt.Println("\nShutting down worker...")
+
+	// Scheduled and queued jobs finish their current work until the shutdown timeout
+	shutdownTimeout := cfg.Server.ShutdownTimeout
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = server.DefaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var stopped sync.WaitGroup
+	stopped.Add(1)
+	go func() {
+		defer stopped.Done()
+		if err := scheduler.Stop(ctx); err != nil {
+			serverLogger.Warn().Err(err).Msg("Scheduled jobs still running at shutdown were cancelled")
+		}
+	}()
+	if workerPool != nil {
+		if err := workerPool.Stop(ctx); err != nil {
+			serverLogger.Warn().Err(err).Msg("Queued jobs still running at shutdown were cancelled")
+		}
+	}
+	stopped.Wait()
+
+	fmt.Println("Worker exited")
+}
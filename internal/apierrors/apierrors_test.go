@@ -0,0 +1,105 @@
+package apierrors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestCatalog_CodesAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, e := range Catalog() {
+		assert.False(t, seen[e.Code], "duplicate code %s", e.Code)
+		seen[e.Code] = true
+		assert.NotEmpty(t, e.Message, e.Code)
+		assert.GreaterOrEqual(t, e.Status, http.StatusBadRequest, e.Code)
+	}
+}
+
+func TestCatalog_SortedByCode(t *testing.T) {
+	entries := Catalog()
+	for i := 1; i < len(entries); i++ {
+		assert.Less(t, entries[i-1].Code, entries[i].Code)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	t.Run("wrapped models error", func(t *testing.T) {
+		e, ok := Lookup(fmt.Errorf("loading portfolio: %w", models.ErrPortfolioNotFound))
+		require.True(t, ok)
+		assert.Equal(t, PortfolioNotFound, e)
+	})
+
+	t.Run("detailed error keeps its message", func(t *testing.T) {
+		err := fmt.Errorf("%w: must be at least 12 characters", models.ErrInvalidPassword)
+		e, ok := Lookup(err)
+		require.True(t, ok)
+		assert.Equal(t, InvalidPassword.Code, e.Code)
+		assert.Equal(t, InvalidPassword.Status, e.Status)
+		assert.Equal(t, err.Error(), e.Message)
+	})
+
+	t.Run("unknown error", func(t *testing.T) {
+		_, ok := Lookup(errors.New("connection refused"))
+		assert.False(t, ok)
+	})
+}
+
+func respondError(ctx context.Context, err error) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	RespondError(c, err, RetrievalFailed)
+	return w
+}
+
+func TestRespondError(t *testing.T) {
+	t.Run("models error", func(t *testing.T) {
+		w := respondError(context.Background(), models.ErrInsufficientShares)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, InsufficientShares.Code, response.Code)
+	})
+
+	t.Run("unknown error uses the fallback without leaking it", func(t *testing.T) {
+		w := respondError(context.Background(), errors.New("pq: relation does not exist"))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, RetrievalFailed.Code, response.Code)
+		assert.Equal(t, RetrievalFailed.Message, response.Error)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		w := respondError(context.Background(), fmt.Errorf("query: %w", context.DeadlineExceeded))
+
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, RequestTimeout.Code, response.Code)
+	})
+
+	t.Run("client disconnected", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		w := respondError(ctx, models.ErrPortfolioNotFound)
+
+		assert.Equal(t, StatusClientClosedRequest, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+}
@@ -0,0 +1,243 @@
+// Package apierrors is the catalog of the error codes the API responds with. Every error
+// response has the same envelope, dto.ErrorResponse, and carries one of the codes defined
+// here with the status that code is always sent with, so clients can match on codes without
+// them changing from one endpoint or release to the next. GET /api/errors lists the catalog.
+package apierrors
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Error is an entry in the catalog
+type Error struct {
+	// Code is the stable, machine-readable identifier clients match on
+	Code string
+	// Status is the HTTP status the code is sent with
+	Status int
+	// Message is sent to the user unless a response gives a more specific one
+	Message string
+}
+
+// WithMessage returns e with a message specific to one response. The code and status stay
+// the same.
+func (e Error) WithMessage(message string) Error {
+	e.Message = message
+	return e
+}
+
+// catalog holds every entry defined with define, in definition order
+var catalog []Error
+
+// define adds an entry to the catalog
+func define(code string, status int, message string) Error {
+	e := Error{Code: code, Status: status, Message: message}
+	catalog = append(catalog, e)
+	return e
+}
+
+// Catalog returns every entry, sorted by code
+func Catalog() []Error {
+	entries := make([]Error, len(catalog))
+	copy(entries, catalog)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+	return entries
+}
+
+// General errors
+var (
+	InvalidRequest        = define("INVALID_REQUEST", http.StatusBadRequest, "The request is malformed or missing a required value")
+	ValidationError       = define("VALIDATION_ERROR", http.StatusBadRequest, "The request failed validation")
+	InvalidDateRange      = define("INVALID_DATE_RANGE", http.StatusBadRequest, "End date must be after start date")
+	TooManySymbols        = define("TOO_MANY_SYMBOLS", http.StatusBadRequest, "Too many symbols in one request")
+	InternalError         = define("INTERNAL_ERROR", http.StatusInternalServerError, "Internal server error")
+	RequestTimeout        = define("REQUEST_TIMEOUT", http.StatusGatewayTimeout, "Request timed out")
+	RateLimitExceeded     = define("RATE_LIMIT_EXCEEDED", http.StatusTooManyRequests, "Rate limit exceeded. Please try again later.")
+	VersionRequired       = define("VERSION_REQUIRED", http.StatusPreconditionRequired, "Updates must send the version they were made against in If-Match or the request body")
+	VersionConflict       = define("VERSION_CONFLICT", http.StatusConflict, "The record was changed by another request; fetch it again and retry")
+	JobQueueUnavailable   = define("JOB_QUEUE_UNAVAILABLE", http.StatusServiceUnavailable, "Background jobs are not available")
+	MarketDataUnavailable = define("MARKET_DATA_UNAVAILABLE", http.StatusServiceUnavailable, "Market data is not available")
+	PushUnavailable       = define("PUSH_UNAVAILABLE", http.StatusServiceUnavailable, "Web Push notifications are not configured")
+	BrokerSyncUnavailable = define("BROKER_SYNC_UNAVAILABLE", http.StatusServiceUnavailable, "Broker syncing is not configured")
+	Maintenance           = define("MAINTENANCE", http.StatusServiceUnavailable, "The API is down for maintenance; try again later")
+	UnsupportedAPIVersion = define("UNSUPPORTED_API_VERSION", http.StatusNotAcceptable, "The requested API version is not supported")
+	FeatureDisabled       = define("FEATURE_DISABLED", http.StatusNotFound, "This feature is not enabled")
+	FeatureNotFound       = define("FEATURE_NOT_FOUND", http.StatusNotFound, "Feature flag not found")
+)
+
+// Failures of an operation for reasons the client can't fix. Handlers give them a message
+// naming the operation.
+var (
+	RetrievalFailed        = define("RETRIEVAL_FAILED", http.StatusInternalServerError, "Failed to retrieve the requested data")
+	CreationFailed         = define("CREATION_FAILED", http.StatusInternalServerError, "Failed to create the resource")
+	UpdateFailed           = define("UPDATE_FAILED", http.StatusInternalServerError, "Failed to update the resource")
+	DeleteFailed           = define("DELETE_FAILED", http.StatusInternalServerError, "Failed to delete the resource")
+	ImportFailed           = define("IMPORT_FAILED", http.StatusInternalServerError, "Failed to import transactions")
+	RecalculationFailed    = define("RECALCULATION_FAILED", http.StatusInternalServerError, "Failed to recalculate portfolio")
+	BackfillFailed         = define("BACKFILL_FAILED", http.StatusInternalServerError, "Failed to backfill snapshots")
+	StatementFailed        = define("STATEMENT_FAILED", http.StatusInternalServerError, "Failed to generate statement")
+	ExportFailed           = define("EXPORT_FAILED", http.StatusInternalServerError, "Failed to export data")
+	ReportGenerationFailed = define("REPORT_GENERATION_FAILED", http.StatusInternalServerError, "Failed to generate tax report")
+	AllocationFailed       = define("ALLOCATION_FAILED", http.StatusInternalServerError, "Failed to allocate sale")
+	IdentificationFailed   = define("IDENTIFICATION_FAILED", http.StatusInternalServerError, "Failed to identify tax loss opportunities")
+	ApprovalFailed         = define("APPROVAL_FAILED", http.StatusInternalServerError, "Failed to approve action")
+	RejectionFailed        = define("REJECTION_FAILED", http.StatusInternalServerError, "Failed to reject action")
+	TenantResolutionFailed = define("TENANT_RESOLUTION_FAILED", http.StatusInternalServerError, "Failed to resolve organization")
+)
+
+// Authentication errors
+var (
+	Unauthorized            = define("UNAUTHORIZED", http.StatusUnauthorized, "User not authenticated")
+	NotAuthenticated        = define("NOT_AUTHENTICATED", http.StatusUnauthorized, "Authentication required")
+	MissingAuthHeader       = define("MISSING_AUTH_HEADER", http.StatusUnauthorized, "Authorization header is required")
+	InvalidAuthHeaderFormat = define("INVALID_AUTH_HEADER_FORMAT", http.StatusUnauthorized, "Authorization header must start with 'Bearer '")
+	MissingToken            = define("MISSING_TOKEN", http.StatusUnauthorized, "Token is required")
+	InvalidToken            = define("INVALID_TOKEN", http.StatusUnauthorized, "Invalid or expired token")
+	InvalidTokenClaims      = define("INVALID_TOKEN_CLAIMS", http.StatusUnauthorized, "Failed to extract user information from token")
+	InvalidAPIKey           = define("INVALID_API_KEY", http.StatusUnauthorized, "Invalid or revoked API key")
+	MissingAdminToken       = define("MISSING_ADMIN_TOKEN", http.StatusUnauthorized, "Admin bearer token is required")
+	InvalidAdminToken       = define("INVALID_ADMIN_TOKEN", http.StatusUnauthorized, "Invalid admin token")
+	CSRFTokenInvalid        = define("CSRF_TOKEN_INVALID", http.StatusForbidden, "Missing or invalid CSRF token")
+	InvalidCredentials      = define("INVALID_CREDENTIALS", http.StatusUnauthorized, "Invalid email or password")
+	InvalidRefreshToken     = define("INVALID_REFRESH_TOKEN", http.StatusUnauthorized, "Invalid or expired refresh token")
+	MissingRefreshToken     = define("MISSING_REFRESH_TOKEN", http.StatusUnauthorized, "Refresh token cookie is required")
+	Forbidden               = define("FORBIDDEN", http.StatusForbidden, "You do not have permission to access this resource")
+	AccountDisabled         = define("ACCOUNT_DISABLED", http.StatusForbidden, "This account has been disabled")
+	PasswordResetRequired   = define("PASSWORD_RESET_REQUIRED", http.StatusForbidden, "A password reset is required; use the link sent by email or request a new one")
+	RegistrationFailed      = define("REGISTRATION_FAILED", http.StatusInternalServerError, "Failed to register user")
+	LogoutFailed            = define("LOGOUT_FAILED", http.StatusInternalServerError, "Failed to logout")
+)
+
+// Password errors
+var (
+	InvalidPassword        = define("INVALID_PASSWORD", http.StatusBadRequest, "Password does not meet the password policy")
+	InvalidCurrentPassword = define("INVALID_CURRENT_PASSWORD", http.StatusBadRequest, "Current password is incorrect")
+	PasswordReused         = define("PASSWORD_REUSED", http.StatusBadRequest, "Choose a password you haven't used recently")
+	InvalidResetToken      = define("INVALID_RESET_TOKEN", http.StatusBadRequest, "Invalid or expired reset token")
+	ResetPasswordFailed    = define("RESET_PASSWORD_FAILED", http.StatusInternalServerError, "Failed to reset password")
+	ChangePasswordFailed   = define("CHANGE_PASSWORD_FAILED", http.StatusInternalServerError, "Failed to change password")
+)
+
+// User and organization errors
+var (
+	UserNotFound            = define("USER_NOT_FOUND", http.StatusNotFound, "User not found")
+	EmailAlreadyExists      = define("EMAIL_ALREADY_EXISTS", http.StatusConflict, "A user with this email already exists")
+	AdminSelfChange         = define("ADMIN_SELF_CHANGE", http.StatusConflict, "Administrators cannot disable, delete or demote themselves")
+	OrganizationNotFound    = define("ORGANIZATION_NOT_FOUND", http.StatusNotFound, "Organization not found")
+	UserQuotaExceeded       = define("USER_QUOTA_EXCEEDED", http.StatusForbidden, "Organization user quota exceeded")
+	PortfolioQuotaExceeded  = define("PORTFOLIO_QUOTA_EXCEEDED", http.StatusForbidden, "Your organization's portfolio quota has been reached")
+	APIKeyQuotaExceeded     = define("API_KEY_QUOTA_EXCEEDED", http.StatusForbidden, "Organization API key quota exceeded")
+	APIKeyNotFound          = define("API_KEY_NOT_FOUND", http.StatusNotFound, "API key not found")
+	APIKeyOwnerChanged      = define("API_KEY_OWNER_CHANGED", http.StatusConflict, "An API key's user cannot be changed; issue a new key instead")
+	MemberNotFound          = define("MEMBER_NOT_FOUND", http.StatusNotFound, "Organization member not found")
+	NotOrganizationMember   = define("NOT_ORGANIZATION_MEMBER", http.StatusForbidden, "You are not a member of this organization")
+	AlreadyMember           = define("ALREADY_MEMBER", http.StatusConflict, "The user is already a member of this organization")
+	InsufficientOrgRole     = define("INSUFFICIENT_ORGANIZATION_ROLE", http.StatusForbidden, "Your role in this organization does not allow this")
+	LastOwner               = define("LAST_OWNER", http.StatusConflict, "An organization must keep at least one owner")
+	InvitationNotFound      = define("INVITATION_NOT_FOUND", http.StatusNotFound, "Invitation not found")
+	InvalidInvitation       = define("INVALID_INVITATION", http.StatusBadRequest, "Invalid or expired invitation")
+	InvitationEmailMismatch = define("INVITATION_EMAIL_MISMATCH", http.StatusForbidden, "The invitation was sent to a different email address")
+	InvalidOrganizationID   = define("INVALID_ORGANIZATION_ID", http.StatusBadRequest, "Invalid organization ID")
+)
+
+// Advisor delegation errors
+var (
+	DelegationNotFound           = define("DELEGATION_NOT_FOUND", http.StatusNotFound, "Delegation not found")
+	SelfDelegation               = define("SELF_DELEGATION", http.StatusBadRequest, "You cannot request access to your own portfolios")
+	DelegationExists             = define("DELEGATION_EXISTS", http.StatusConflict, "A delegation between you and this client is already pending or active")
+	InvalidConsentToken          = define("INVALID_CONSENT_TOKEN", http.StatusBadRequest, "Invalid or expired consent token")
+	ConsentEmailMismatch         = define("CONSENT_EMAIL_MISMATCH", http.StatusForbidden, "Access was requested from a different email address")
+	DelegationPortfoliosRequired = define("DELEGATION_PORTFOLIOS_REQUIRED", http.StatusBadRequest, "At least one portfolio must be delegated")
+	DelegationNotActive          = define("DELEGATION_NOT_ACTIVE", http.StatusConflict, "The delegation is not active")
+	NotDelegationClient          = define("NOT_DELEGATION_CLIENT", http.StatusForbidden, "Only the client may choose the delegated portfolios")
+	NotDelegated                 = define("NOT_DELEGATED", http.StatusForbidden, "The client has not delegated access to you")
+	DelegationReadOnly           = define("DELEGATION_READ_ONLY", http.StatusForbidden, "Your delegated access is read-only")
+	DelegationForbidden          = define("DELEGATION_FORBIDDEN", http.StatusForbidden, "This cannot be done on behalf of a client")
+	InvalidClientID              = define("INVALID_CLIENT_ID", http.StatusBadRequest, "Invalid client ID")
+)
+
+// Portfolio, portfolio group, transaction, holding, tax lot and tag errors
+var (
+	PortfolioNotFound      = define("PORTFOLIO_NOT_FOUND", http.StatusNotFound, "Portfolio not found")
+	DuplicatePortfolioName = define("DUPLICATE_PORTFOLIO_NAME", http.StatusConflict, "A portfolio with this name already exists")
+	GroupNotFound          = define("GROUP_NOT_FOUND", http.StatusNotFound, "Portfolio group not found")
+	DuplicateGroupName     = define("DUPLICATE_GROUP_NAME", http.StatusConflict, "A portfolio group with this name already exists")
+	TransactionNotFound    = define("TRANSACTION_NOT_FOUND", http.StatusNotFound, "Transaction not found")
+	InsufficientShares     = define("INSUFFICIENT_SHARES", http.StatusUnprocessableEntity, "Insufficient shares for sale")
+	HoldingNotFound        = define("HOLDING_NOT_FOUND", http.StatusNotFound, "Holding not found")
+	DuplicateHolding       = define("DUPLICATE_HOLDING", http.StatusConflict, "The portfolio already has a holding for this symbol")
+	TaxLotNotFound         = define("TAX_LOT_NOT_FOUND", http.StatusNotFound, "Tax lot not found")
+	UnsupportedTaxForm     = define("UNSUPPORTED_TAX_FORM", http.StatusUnprocessableEntity, "Tax forms can only be exported under the US tax regime")
+	TagNotFound            = define("TAG_NOT_FOUND", http.StatusNotFound, "Tag not found")
+	InvalidMethod          = define("INVALID_METHOD", http.StatusBadRequest, "Invalid cost basis method")
+	InvalidThreshold       = define("INVALID_THRESHOLD", http.StatusBadRequest, "Invalid threshold value")
+	ImportNotFound         = define("IMPORT_NOT_FOUND", http.StatusNotFound, "Import not found")
+	JobNotFound            = define("JOB_NOT_FOUND", http.StatusNotFound, "Job not found")
+	NotificationNotFound   = define("NOTIFICATION_NOT_FOUND", http.StatusNotFound, "Notification not found")
+	PushDeviceNotFound     = define("PUSH_DEVICE_NOT_FOUND", http.StatusNotFound, "Push device not found")
+	InvalidPushDevice      = define("INVALID_PUSH_DEVICE", http.StatusBadRequest, "Invalid push device")
+	PushDeviceLimit        = define("PUSH_DEVICE_LIMIT", http.StatusConflict, "Too many push devices registered; remove one first")
+	LedgerInconsistent     = define("LEDGER_INCONSISTENT", http.StatusUnprocessableEntity, "Transaction history cannot be replayed")
+)
+
+// Corporate action errors
+var (
+	ActionNotFound             = define("ACTION_NOT_FOUND", http.StatusNotFound, "Action not found")
+	ActionNotPending           = define("ACTION_NOT_PENDING", http.StatusBadRequest, "Action is not pending")
+	ApplyFailed                = define("APPLY_FAILED", http.StatusUnprocessableEntity, "Failed to apply corporate action")
+	FairMarketValueUnavailable = define("FAIR_MARKET_VALUE_UNAVAILABLE", http.StatusUnprocessableEntity, "Closing prices for a fair market value allocation are not available")
+)
+
+// Stock plan, blackout and rebalance plan errors
+var (
+	GrantNotFound           = define("GRANT_NOT_FOUND", http.StatusNotFound, "Stock plan grant not found")
+	InvalidOperation        = define("INVALID_OPERATION", http.StatusUnprocessableEntity, "The operation is not allowed in the resource's current state")
+	PolicyNotFound          = define("POLICY_NOT_FOUND", http.StatusNotFound, "Portfolio is not flagged as holding employer stock")
+	WindowNotFound          = define("WINDOW_NOT_FOUND", http.StatusNotFound, "Blackout window not found")
+	BlackoutPeriod          = define("BLACKOUT_PERIOD", http.StatusUnprocessableEntity, "Transaction falls within a trading blackout window")
+	PlanNotFound            = define("PLAN_NOT_FOUND", http.StatusNotFound, "Rebalance plan not found")
+	TradeNotFound           = define("TRADE_NOT_FOUND", http.StatusNotFound, "Rebalance plan trade not found")
+	SymbolTradingRestricted = define("SYMBOL_TRADING_RESTRICTED", http.StatusUnprocessableEntity, "Trading is halted or suspended for the symbol")
+)
+
+// Option errors
+var (
+	OptionContractNotFound = define("OPTION_CONTRACT_NOT_FOUND", http.StatusNotFound, "Option contract not found")
+	NoOptionPosition       = define("NO_OPTION_POSITION", http.StatusNotFound, "Portfolio holds no contracts of this option")
+	OptionNotExpired       = define("OPTION_NOT_EXPIRED", http.StatusUnprocessableEntity, "Option contract can't be settled before its expiry")
+)
+
+// Broker connection errors
+var (
+	BrokerConnectionNotFound = define("BROKER_CONNECTION_NOT_FOUND", http.StatusNotFound, "Broker connection not found")
+	BrokerSyncFailed         = define("BROKER_SYNC_FAILED", http.StatusBadGateway, "The broker's data provider couldn't be reached or refused the connection")
+)
+
+// Performance, reporting and comparison errors
+var (
+	SnapshotNotFound           = define("SNAPSHOT_NOT_FOUND", http.StatusNotFound, "Performance data not found for this period")
+	DuplicateSnapshot          = define("DUPLICATE_SNAPSHOT", http.StatusConflict, "The portfolio already has a performance snapshot for this date")
+	InsufficientData           = define("INSUFFICIENT_DATA", http.StatusUnprocessableEntity, "Not enough data to produce the result")
+	InvalidPeriod              = define("INVALID_PERIOD", http.StatusBadRequest, "Invalid period")
+	UnsupportedCertification   = define("UNSUPPORTED_CERTIFICATION", http.StatusBadRequest, "Unsupported performance certification format")
+	NotOptedIn                 = define("NOT_OPTED_IN", http.StatusForbidden, "Portfolio has not opted in to peer comparison")
+	BenchmarkUnavailable       = define("BENCHMARK_UNAVAILABLE", http.StatusNotFound, "Not enough peers to publish a benchmark for this allocation bucket")
+	InvalidFeeSchedule         = define("INVALID_FEE_SCHEDULE", http.StatusBadRequest, "Invalid fee schedule")
+	PresetNotFound             = define("PRESET_NOT_FOUND", http.StatusBadRequest, "Unknown fee schedule preset")
+	ReportSubscriptionNotFound = define("REPORT_SUBSCRIPTION_NOT_FOUND", http.StatusNotFound, "Report subscription not found")
+	InvalidFrequency           = define("INVALID_FREQUENCY", http.StatusBadRequest, "Report frequency must be WEEKLY or MONTHLY")
+	InvalidUnsubscribeToken    = define("INVALID_UNSUBSCRIBE_TOKEN", http.StatusBadRequest, "Unsubscribe link is invalid")
+	SimulationNotFound         = define("SIMULATION_NOT_FOUND", http.StatusNotFound, "Simulation not found")
+)
+
+// Market data errors
+var (
+	QuotaExceeded             = define("QUOTA_EXCEEDED", http.StatusTooManyRequests, "Market data provider quota exceeded, try again later")
+	QuoteFetchFailed          = define("QUOTE_FETCH_FAILED", http.StatusInternalServerError, "Failed to retrieve quote")
+	QuotesFetchFailed         = define("QUOTES_FETCH_FAILED", http.StatusInternalServerError, "Failed to retrieve quotes")
+	HistoricalDataFetchFailed = define("HISTORICAL_DATA_FETCH_FAILED", http.StatusInternalServerError, "Failed to retrieve historical prices")
+	ExchangeRateFetchFailed   = define("EXCHANGE_RATE_FETCH_FAILED", http.StatusInternalServerError, "Failed to retrieve exchange rate")
+	SymbolSearchFailed        = define("SYMBOL_SEARCH_FAILED", http.StatusInternalServerError, "Failed to search symbols")
+	SecurityNotFound          = define("SECURITY_NOT_FOUND", http.StatusNotFound, "Security not found")
+	InvalidSymbol             = define("INVALID_SYMBOL", http.StatusBadRequest, "Unknown symbol")
+)
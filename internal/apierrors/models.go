@@ -0,0 +1,182 @@
+package apierrors
+
+import (
+	"errors"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// modelError maps errors services return to the entry they are answered with. Errors that
+// explain what is wrong with the request are detailed: their own message is sent rather than
+// the entry's.
+type modelError struct {
+	errs     []error
+	entry    Error
+	detailed bool
+}
+
+// modelErrors is checked in order, so an error wrapping several models errors is answered
+// with the first that matches
+var modelErrors = []modelError{
+	// Users and authentication
+	{errs: []error{models.ErrUserNotFound}, entry: UserNotFound},
+	{errs: []error{models.ErrInvalidCredentials}, entry: InvalidCredentials},
+	{errs: []error{models.ErrEmailAlreadyExists}, entry: EmailAlreadyExists},
+	{errs: []error{models.ErrUserDisabled}, entry: AccountDisabled},
+	{errs: []error{models.ErrPasswordResetRequired}, entry: PasswordResetRequired},
+	{errs: []error{models.ErrAdminSelfChange}, entry: AdminSelfChange},
+	{errs: []error{models.ErrIncorrectPassword}, entry: InvalidCurrentPassword},
+	{errs: []error{models.ErrFeatureNotFound}, entry: FeatureNotFound},
+	{errs: []error{models.ErrPasswordReused}, entry: PasswordReused},
+	{errs: []error{models.ErrInvalidPassword}, entry: InvalidPassword, detailed: true},
+	{errs: []error{models.ErrInvalidResetToken}, entry: InvalidResetToken},
+	{errs: []error{models.ErrInvalidAPIKey}, entry: InvalidAPIKey},
+
+	// Organizations
+	{errs: []error{models.ErrOrganizationNotFound}, entry: OrganizationNotFound},
+	{errs: []error{models.ErrUserQuotaExceeded}, entry: UserQuotaExceeded},
+	{errs: []error{models.ErrPortfolioQuotaExceeded}, entry: PortfolioQuotaExceeded},
+	{errs: []error{models.ErrAPIKeyQuotaExceeded}, entry: APIKeyQuotaExceeded},
+	{errs: []error{models.ErrAPIKeyNotFound}, entry: APIKeyNotFound},
+	{errs: []error{models.ErrAPIKeyOwnerChanged}, entry: APIKeyOwnerChanged},
+	{errs: []error{models.ErrOrganizationMemberNotFound}, entry: MemberNotFound},
+	{errs: []error{models.ErrNotOrganizationMember}, entry: NotOrganizationMember},
+	{errs: []error{models.ErrAlreadyOrganizationMember}, entry: AlreadyMember},
+	{errs: []error{models.ErrInsufficientOrganizationRole}, entry: InsufficientOrgRole},
+	{errs: []error{models.ErrLastOrganizationOwner}, entry: LastOwner},
+	{errs: []error{models.ErrInvitationNotFound}, entry: InvitationNotFound},
+	{errs: []error{models.ErrInvalidInvitation}, entry: InvalidInvitation},
+	{errs: []error{models.ErrInvitationEmailMismatch}, entry: InvitationEmailMismatch},
+
+	// Advisor delegations
+	{errs: []error{models.ErrDelegationNotFound}, entry: DelegationNotFound},
+	{errs: []error{models.ErrSelfDelegation}, entry: SelfDelegation},
+	{errs: []error{models.ErrDelegationExists}, entry: DelegationExists},
+	{errs: []error{models.ErrInvalidConsentToken}, entry: InvalidConsentToken},
+	{errs: []error{models.ErrConsentEmailMismatch}, entry: ConsentEmailMismatch},
+	{errs: []error{models.ErrDelegationPortfoliosRequired}, entry: DelegationPortfoliosRequired},
+	{errs: []error{models.ErrDelegationNotActive}, entry: DelegationNotActive},
+	{errs: []error{models.ErrNotDelegationClient}, entry: NotDelegationClient},
+	{errs: []error{models.ErrDelegationReadOnly}, entry: DelegationReadOnly},
+	{errs: []error{models.ErrDelegationForbidden}, entry: DelegationForbidden},
+
+	// Portfolios and their records
+	{errs: []error{models.ErrPortfolioNotFound}, entry: PortfolioNotFound},
+	{errs: []error{models.ErrUnauthorizedAccess}, entry: Forbidden.WithMessage("Access denied to this portfolio")},
+	{errs: []error{models.ErrPortfolioDuplicateName}, entry: DuplicatePortfolioName},
+	{errs: []error{models.ErrPortfolioGroupNotFound}, entry: GroupNotFound},
+	{errs: []error{models.ErrPortfolioGroupDuplicateName}, entry: DuplicateGroupName},
+	{errs: []error{models.ErrTransactionNotFound}, entry: TransactionNotFound},
+	{errs: []error{models.ErrInsufficientShares}, entry: InsufficientShares},
+	{errs: []error{models.ErrHoldingNotFound}, entry: HoldingNotFound},
+	{errs: []error{models.ErrDuplicateHolding}, entry: DuplicateHolding},
+	{errs: []error{models.ErrTaxLotNotFound}, entry: TaxLotNotFound},
+	{errs: []error{models.ErrUnsupportedTaxForm}, entry: UnsupportedTaxForm},
+	{errs: []error{models.ErrTagNotFound}, entry: TagNotFound},
+	{errs: []error{models.ErrImportNotFound}, entry: ImportNotFound},
+	{errs: []error{models.ErrQueuedJobNotFound}, entry: JobNotFound},
+	{errs: []error{models.ErrJobQueueUnavailable}, entry: JobQueueUnavailable},
+	{errs: []error{models.ErrNotificationNotFound}, entry: NotificationNotFound},
+	{errs: []error{models.ErrPushDeviceNotFound}, entry: PushDeviceNotFound},
+	{errs: []error{models.ErrInvalidPushDevice}, entry: InvalidPushDevice, detailed: true},
+	{errs: []error{models.ErrPushDeviceLimit}, entry: PushDeviceLimit},
+	{errs: []error{models.ErrPushUnavailable}, entry: PushUnavailable},
+	{errs: []error{models.ErrLedgerReplayFailed}, entry: LedgerInconsistent, detailed: true},
+	{errs: []error{models.ErrVersionRequired}, entry: VersionRequired},
+
+	// Corporate actions
+	{errs: []error{models.ErrPortfolioActionNotFound}, entry: ActionNotFound},
+	{errs: []error{models.ErrPortfolioActionNotPending}, entry: ActionNotPending},
+	{errs: []error{models.ErrCorporateActionApplyFailed}, entry: ApplyFailed, detailed: true},
+	{errs: []error{models.ErrFairMarketValueUnavailable}, entry: FairMarketValueUnavailable, detailed: true},
+
+	// Stock plans, blackouts and rebalance plans
+	{errs: []error{models.ErrStockPlanGrantNotFound}, entry: GrantNotFound},
+	{errs: []error{models.ErrEmployerStockPolicyNotFound}, entry: PolicyNotFound},
+	{errs: []error{models.ErrBlackoutWindowNotFound}, entry: WindowNotFound},
+	{errs: []error{models.ErrBlackoutPeriod}, entry: BlackoutPeriod},
+	{errs: []error{models.ErrRebalancePlanNotFound}, entry: PlanNotFound},
+	{errs: []error{models.ErrRebalanceTradeNotFound}, entry: TradeNotFound},
+	{errs: []error{models.ErrRebalanceSymbolRestricted}, entry: SymbolTradingRestricted, detailed: true},
+	{errs: []error{
+		models.ErrStockPlanTypeMismatch, models.ErrInsufficientVestedShares, models.ErrStockPlanGrantExpired,
+		models.ErrRebalancePlanNotActive, models.ErrRebalanceTradeNotPending, models.ErrRebalanceFillMismatch,
+	}, entry: InvalidOperation, detailed: true},
+
+	// Options
+	{errs: []error{models.ErrOptionContractNotFound}, entry: OptionContractNotFound},
+	{errs: []error{models.ErrNoOptionPosition}, entry: NoOptionPosition, detailed: true},
+	{errs: []error{models.ErrOptionNotExpired}, entry: OptionNotExpired, detailed: true},
+
+	// Broker connections
+	{errs: []error{models.ErrBrokerConnectionNotFound}, entry: BrokerConnectionNotFound},
+	{errs: []error{models.ErrBrokerSyncUnavailable}, entry: BrokerSyncUnavailable},
+	{errs: []error{models.ErrBrokerSyncFailed}, entry: BrokerSyncFailed, detailed: true},
+
+	// Performance, reporting and comparisons
+	{errs: []error{models.ErrPerformanceSnapshotNotFound}, entry: SnapshotNotFound},
+	{errs: []error{models.ErrDuplicateSnapshot}, entry: DuplicateSnapshot},
+	{errs: []error{
+		models.ErrInsufficientCertificationData, models.ErrInsufficientPeerComparisonData, models.ErrInsufficientGroupData,
+		models.ErrInsufficientProjectionHistory, models.ErrProjectionStartingValue, models.ErrInsufficientSimulationHistory,
+		models.ErrInsufficientPerformanceHistory,
+	}, entry: InsufficientData, detailed: true},
+	{errs: []error{
+		models.ErrInvalidCertificationPeriod, models.ErrInvalidStatementPeriod, models.ErrInvalidPerformancePeriod, models.ErrInvalidFeeYear,
+		models.ErrInvalidContributionPeriod,
+	}, entry: InvalidPeriod, detailed: true},
+	{errs: []error{models.ErrUnsupportedCertification}, entry: UnsupportedCertification, detailed: true},
+	{errs: []error{models.ErrPeerComparisonNotOptedIn}, entry: NotOptedIn, detailed: true},
+	{errs: []error{models.ErrPeerBenchmarkNotFound}, entry: BenchmarkUnavailable, detailed: true},
+	{errs: []error{models.ErrInvalidFeeSchedule}, entry: InvalidFeeSchedule, detailed: true},
+	{errs: []error{models.ErrFeeSchedulePresetNotFound}, entry: PresetNotFound},
+	{errs: []error{models.ErrReportSubscriptionNotFound}, entry: ReportSubscriptionNotFound},
+	{errs: []error{models.ErrInvalidReportFrequency}, entry: InvalidFrequency, detailed: true},
+	{errs: []error{models.ErrInvalidUnsubscribeToken}, entry: InvalidUnsubscribeToken},
+	{errs: []error{models.ErrSimulationNotFound}, entry: SimulationNotFound},
+
+	// Market data
+	{errs: []error{models.ErrQuotaExceeded}, entry: QuotaExceeded},
+	{errs: []error{models.ErrMarketDataUnavailable}, entry: MarketDataUnavailable},
+	{errs: []error{models.ErrSecurityNotFound}, entry: SecurityNotFound},
+	{errs: []error{models.ErrUnknownSymbol}, entry: InvalidSymbol, detailed: true},
+
+	// Invalid values in a request
+	{errs: []error{
+		models.ErrInvalidRole, models.ErrInvalidTimezone, models.ErrInvalidLocale,
+		models.ErrPortfolioNameRequired, models.ErrInvalidCurrency, models.ErrInvalidCostBasisMethod,
+		models.ErrInvalidTaxRegime, models.ErrInvalidPortfolioID, models.ErrInvalidTag, models.ErrTooManyTags,
+		models.ErrPortfolioGroupNameRequired, models.ErrPortfolioGroupCycle, models.ErrPortfolioGroupTooDeep,
+		models.ErrInvalidTransactionType, models.ErrInvalidQuantity, models.ErrInvalidPrice, models.ErrInvalidSymbol,
+		models.ErrInvalidAssetType, models.ErrInvalidCryptoPair, models.ErrInvalidCryptoQuantity, models.ErrInvalidCryptoCurrency,
+		models.ErrInvalidOptionSymbol, models.ErrInvalidOptionQuantity, models.ErrInvalidOptionTrade, models.ErrInvalidIncomeCategory,
+		models.ErrInvalidCashFlow, models.ErrInvalidCapitalGainTerm, models.ErrInvalidOptionContract, models.ErrInvalidOptionSettlement,
+		models.ErrInvalidCorporateActionType, models.ErrInvalidCostBasisAllocation, models.ErrInvalidSpinoffAllocationMethod,
+		models.ErrInvalidStockPlanType, models.ErrInvalidVestingSchedule,
+		models.ErrInvalidBlackoutEnforcement, models.ErrInvalidBlackoutWindow,
+		models.ErrRebalancePlanNameRequired, models.ErrRebalancePlanNoTrades, models.ErrInvalidBrokerConnection,
+		models.ErrOrganizationNameRequired, models.ErrInvalidExternalID, models.ErrInvalidQuota, models.ErrInvalidOrganizationRole,
+		models.ErrInvalidDelegationAccess,
+		models.ErrInvalidProjectionMethod, models.ErrInvalidProjectionHorizon, models.ErrInvalidProjection, models.ErrExpectedReturnRequired,
+		models.ErrInvalidSimulation, models.ErrInvalidWhatIf, models.ErrInvalidSecurityQuery,
+		models.ErrInvalidPriceResolution, models.ErrIntradayRangeTooLong,
+		models.ErrInvalidDate, models.ErrInvalidValue, models.ErrInvalidImportFile,
+		models.ErrInvalidPositionStatement,
+	}, entry: ValidationError, detailed: true},
+}
+
+// Lookup returns the entry err is answered with if it is, or wraps, a models error in the
+// catalog
+func Lookup(err error) (Error, bool) {
+	for _, m := range modelErrors {
+		for _, target := range m.errs {
+			if errors.Is(err, target) {
+				if m.detailed {
+					return m.entry.WithMessage(err.Error()), true
+				}
+				return m.entry, true
+			}
+		}
+	}
+	return Error{}, false
+}
@@ -0,0 +1,56 @@
+package apierrors
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+// StatusClientClosedRequest is the non-standard status logged for requests whose client
+// disconnected before a response could be written
+const StatusClientClosedRequest = 499
+
+// Respond writes e as the response
+func Respond(c *gin.Context, e Error) {
+	c.JSON(e.Status, dto.ErrorResponse{Error: e.Message, Code: e.Code})
+}
+
+// Abort writes e as the response and stops the handlers after the calling middleware
+func Abort(c *gin.Context, e Error) {
+	c.AbortWithStatusJSON(e.Status, dto.ErrorResponse{Error: e.Message, Code: e.Code})
+}
+
+// RespondError writes the response to an error a service returned: a timeout if the
+// request's context ended, the catalog entry of a models error, or fallback for anything
+// else
+func RespondError(c *gin.Context, err error, fallback Error) {
+	if RespondContextDone(c, err) {
+		return
+	}
+	if e, ok := Lookup(err); ok {
+		Respond(c, e)
+		return
+	}
+	Respond(c, fallback)
+}
+
+// RespondContextDone writes a response if err came from the request's context ending,
+// either because its deadline passed or because the client disconnected, and reports
+// whether it did. The request context is checked as well as err, since services may
+// report a canceled lookup as a more specific error.
+func RespondContextDone(c *gin.Context, err error) bool {
+	ctxErr := c.Request.Context().Err()
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctxErr, context.DeadlineExceeded):
+		Respond(c, RequestTimeout)
+	case errors.Is(err, context.Canceled) || errors.Is(ctxErr, context.Canceled):
+		// Nobody is left to read a body
+		c.AbortWithStatus(StatusClientClosedRequest)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package apierrors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+func init() {
+	// Report fields by the names clients send rather than the Go field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(requestFieldName)
+	}
+}
+
+// requestFieldName returns the name a struct field is bound from: its json, form or uri key
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// RespondInvalid writes an INVALID_REQUEST response for a request body or query that could not
+// be bound. message says which part of the request was invalid, e.g. "Invalid request data";
+// when the failure was validation, every invalid field is listed as well.
+func RespondInvalid(c *gin.Context, message string, err error) {
+	fields := FieldErrors(err)
+	detail := err.Error()
+	if len(fields) > 0 {
+		problems := make([]string, len(fields))
+		for i, f := range fields {
+			problems[i] = f.Field + " " + f.Message
+		}
+		detail = strings.Join(problems, "; ")
+	}
+	c.JSON(InvalidRequest.Status, dto.ErrorResponse{
+		Error:  message + ": " + detail,
+		Code:   InvalidRequest.Code,
+		Errors: fields,
+	})
+}
+
+// FieldErrors translates a binding error into the fields it found invalid. It returns nil for
+// errors that are not about a particular field, such as malformed JSON.
+func FieldErrors(err error) []dto.FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]dto.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = dto.FieldError{
+				Field:   fieldPath(fe),
+				Rule:    fe.Tag(),
+				Message: ruleMessage(fe),
+			}
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []dto.FieldError{{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: "must be " + jsonType(typeErr.Type),
+		}}
+	}
+	return nil
+}
+
+// fieldPath returns the path to a field below the request struct, e.g. "trades[0].symbol"
+func fieldPath(fe validator.FieldError) string {
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+// ruleMessage describes the validation rule a field failed
+func ruleMessage(fe validator.FieldError) string {
+	param := fe.Param()
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "uuid":
+		return "must be a valid UUID"
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "min", "gte":
+		return sizeMessage(fe.Kind(), "at least", param)
+	case "max", "lte":
+		return sizeMessage(fe.Kind(), "at most", param)
+	case "len":
+		return sizeMessage(fe.Kind(), "exactly", param)
+	case "gt":
+		return sizeMessage(fe.Kind(), "more than", param)
+	case "lt":
+		return sizeMessage(fe.Kind(), "less than", param)
+	default:
+		return fmt.Sprintf("failed the %s rule", fe.Tag())
+	}
+}
+
+// sizeMessage describes a bound on a field, which limits the length of strings and lists and
+// the value of numbers
+func sizeMessage(kind reflect.Kind, bound, param string) string {
+	switch kind {
+	case reflect.String:
+		return fmt.Sprintf("must be %s %s %s long", bound, param, plural(param, "character"))
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("must contain %s %s %s", bound, param, plural(param, "item"))
+	default:
+		switch bound {
+		case "more than":
+			return "must be greater than " + param
+		case "less than":
+			return "must be less than " + param
+		}
+		return fmt.Sprintf("must be %s %s", bound, param)
+	}
+}
+
+// plural returns noun in the plural unless count is 1
+func plural(count, noun string) string {
+	if count == "1" {
+		return noun
+	}
+	return noun + "s"
+}
+
+// jsonType names the JSON type a Go type is decoded from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	default:
+		return "an object"
+	}
+}
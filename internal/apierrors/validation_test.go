@@ -0,0 +1,87 @@
+package apierrors
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+type validationTestTrade struct {
+	Symbol string `json:"symbol" binding:"required"`
+}
+
+type validationTestRequest struct {
+	Name     string                `json:"name" binding:"required,max=5"`
+	Type     string                `json:"type" binding:"oneof=BUY SELL"`
+	Email    string                `json:"email,omitempty" binding:"omitempty,email"`
+	Quantity int                   `json:"quantity" binding:"gt=0"`
+	Trades   []validationTestTrade `json:"trades" binding:"min=1,dive"`
+	Limit    int                   `form:"limit" binding:"omitempty,max=100"`
+}
+
+func bindInvalid(t *testing.T, body string) (*httptest.ResponseRecorder, dto.ErrorResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	var req validationTestRequest
+	err := c.ShouldBindJSON(&req)
+	require.Error(t, err)
+	RespondInvalid(c, "Invalid request data", err)
+
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w, response
+}
+
+func TestRespondInvalid_ValidationErrors(t *testing.T) {
+	w, response := bindInvalid(t, `{"name":"too long","type":"HOLD","email":"nope","quantity":0,"trades":[{"symbol":""}],"limit":500}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, InvalidRequest.Code, response.Code)
+	assert.Equal(t, []dto.FieldError{
+		{Field: "name", Rule: "max", Message: "must be at most 5 characters long"},
+		{Field: "type", Rule: "oneof", Message: "must be one of: BUY, SELL"},
+		{Field: "email", Rule: "email", Message: "must be a valid email address"},
+		{Field: "quantity", Rule: "gt", Message: "must be greater than 0"},
+		{Field: "trades[0].symbol", Rule: "required", Message: "is required"},
+		{Field: "limit", Rule: "max", Message: "must be at most 100"},
+	}, response.Errors)
+	assert.Contains(t, response.Error, "Invalid request data: name must be at most 5 characters long; type must be one of")
+}
+
+func TestRespondInvalid_RequiredAndLength(t *testing.T) {
+	_, response := bindInvalid(t, `{"type":"BUY","quantity":1,"trades":[]}`)
+
+	assert.Equal(t, []dto.FieldError{
+		{Field: "name", Rule: "required", Message: "is required"},
+		{Field: "trades", Rule: "min", Message: "must contain at least 1 item"},
+	}, response.Errors)
+}
+
+func TestRespondInvalid_WrongType(t *testing.T) {
+	_, response := bindInvalid(t, `{"name":"abc","quantity":"three"}`)
+
+	assert.Equal(t, []dto.FieldError{
+		{Field: "quantity", Rule: "type", Message: "must be an integer"},
+	}, response.Errors)
+}
+
+func TestRespondInvalid_MalformedBody(t *testing.T) {
+	_, response := bindInvalid(t, `{"name":`)
+
+	assert.Empty(t, response.Errors)
+	assert.Equal(t, InvalidRequest.Code, response.Code)
+	assert.True(t, strings.HasPrefix(response.Error, "Invalid request data: "))
+}
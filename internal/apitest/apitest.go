@@ -0,0 +1,290 @@
+// Package apitest runs the API end to end for tests: the server is built by server.New, as
+// cmd/api builds it, on a SQLite database, and served in-process over HTTP. Tests register
+// users and make authenticated requests through it, so they cover the production route
+// wiring rather than handlers wired by hand.
+//
+// With E2E_API_URL set, the same tests run against that server instead, such as the one
+// make e2e-up starts in Docker.
+package apitest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/app"
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/server"
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+// URLEnvVar names the environment variable pointing the tests at a running server
+const URLEnvVar = "E2E_API_URL"
+
+// Password is the password of the users RegisterUser creates
+const Password = "SecurePass123"
+
+// jobPollInterval is how often clients check on queued jobs
+const jobPollInterval = 20 * time.Millisecond
+
+// userCount makes the emails of registered users unique within a test binary
+var userCount atomic.Int64
+
+// Server is the API under test
+type Server struct {
+	// URL is the server's base URL
+	URL string
+	// Container and DB are the in-process server's services and database, nil when the
+	// tests run against a server at E2E_API_URL
+	Container *app.Container
+	DB        *gorm.DB
+}
+
+// Option configures an in-process server
+type Option func(*settings)
+
+type settings struct {
+	configure  []func(*config.Config)
+	appOptions []app.Option
+}
+
+// WithConfig changes the configuration the server is built with, after Config has filled it in
+func WithConfig(configure func(*config.Config)) Option {
+	return func(s *settings) {
+		s.configure = append(s.configure, configure)
+	}
+}
+
+// WithAppOptions builds the server's services with opts, such as a market data provider
+func WithAppOptions(opts ...app.Option) Option {
+	return func(s *settings) {
+		s.appOptions = append(s.appOptions, opts...)
+	}
+}
+
+// Config returns the configuration of an in-process server on the database at databaseURL.
+// It is the server's default configuration, except that rate limits are out of the way,
+// passwords are hashed cheaply and only errors are logged.
+func Config(databaseURL string) *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			Environment:    "test",
+			RequestTimeout: 10 * time.Second,
+			JobWorkers:     2,
+		},
+		Database: config.DatabaseConfig{
+			URL:                databaseURL,
+			MaxOpenConns:       1,
+			MaxIdleConns:       1,
+			SlowQueryThreshold: time.Second,
+		},
+		JWT: config.JWTConfig{
+			Secret:                    "apitest-secret-key-for-jwt-signing",
+			AccessTokenDuration:       30 * time.Minute,
+			RefreshTokenDuration:      7 * 24 * time.Hour,
+			RememberMeAccessDuration:  24 * time.Hour,
+			RememberMeRefreshDuration: 30 * 24 * time.Hour,
+		},
+		Security: config.SecurityConfig{
+			RateLimitRequests:    math.MaxInt32,
+			RateLimitDuration:    time.Minute,
+			APIRateLimitRequests: math.MaxInt32,
+			APIRateLimitDuration: time.Minute,
+		},
+		MarketData: config.MarketDataConfig{
+			Provider:           "alphavantage",
+			DailyRequestLimit:  25,
+			MinuteRequestLimit: 5,
+			QuoteConcurrency:   4,
+			BreakerFailures:    5,
+			BreakerCooldown:    30 * time.Second,
+		},
+		Admin: config.AdminConfig{
+			InviteValidity: 7 * 24 * time.Hour,
+		},
+		Snapshots: config.SnapshotConfig{
+			DailyRetentionMonths:  24,
+			WeeklyRetentionMonths: 60,
+		},
+		Rounding: config.RoundingConfig{
+			Mode:                 "HALF_UP",
+			EquityQuantityPlaces: 8,
+			CryptoQuantityPlaces: 8,
+			AmountPlaces:         8,
+		},
+		Password: config.PasswordConfig{
+			MinLength:        8,
+			RequireUppercase: true,
+			RequireLowercase: true,
+			RequireNumber:    true,
+			HashAlgorithm:    "bcrypt",
+			BcryptCost:       4,
+		},
+		Logging: config.LoggingConfig{
+			Level:  "error",
+			Format: "console",
+		},
+	}
+}
+
+// New starts a server for the test, stopped when the test finishes. Its queued job workers
+// run, but not its scheduled jobs, whose timing tests can't control. Against a server at
+// E2E_API_URL, tests that configure the server with opts are skipped.
+func New(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	if url := os.Getenv(URLEnvVar); url != "" {
+		if len(opts) > 0 {
+			t.Skipf("%s is set and the test configures its own server", URLEnvVar)
+		}
+		return &Server{URL: strings.TrimSuffix(url, "/")}
+	}
+
+	var s settings
+	for _, opt := range opts {
+		opt(&s)
+	}
+	cfg := Config("sqlite://" + filepath.Join(t.TempDir(), "portfolios.db"))
+	for _, configure := range s.configure {
+		configure(cfg)
+	}
+
+	// One logger for the server and its requests
+	log := logger.NewLogger(logger.Config{Level: cfg.Logging.Level, Format: cfg.Logging.Format, OutputPath: "stderr"})
+	db, err := app.ConnectDatabase(cfg, log)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	gin.SetMode(gin.TestMode)
+	srv, err := server.New(cfg, db, server.WithLogger(log), server.WithAppOptions(s.appOptions...))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = srv.Close() })
+
+	httpServer := httptest.NewServer(srv.Engine)
+	t.Cleanup(httpServer.Close)
+
+	if srv.WorkerPool != nil {
+		srv.WorkerPool.Start()
+		t.Cleanup(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), srv.ShutdownTimeout())
+			defer cancel()
+			_ = srv.WorkerPool.Stop(ctx)
+		})
+	}
+
+	return &Server{URL: httpServer.URL, Container: srv.Container, DB: db}
+}
+
+// InProcess reports whether the server runs in the test's process, with its database at hand
+func (s *Server) InProcess() bool {
+	return s.Container != nil
+}
+
+// Client returns an API client that isn't logged in
+func (s *Server) Client() *client.Client {
+	return client.New(s.URL, client.WithJobPollInterval(jobPollInterval))
+}
+
+// User is a registered user, with a client logged in as them
+type User struct {
+	ID           uuid.UUID
+	Email        string
+	Password     string
+	AccessToken  string
+	RefreshToken string
+	Client       *client.Client
+
+	server *Server
+}
+
+// RegisterUser registers a new user with a unique email and Password
+func (s *Server) RegisterUser(t testing.TB) *User {
+	t.Helper()
+
+	email := fmt.Sprintf("user%d-%d@apitest.example.com", userCount.Add(1), time.Now().UnixNano())
+	c := s.Client()
+	auth, err := c.Register(context.Background(), client.RegisterRequest{Email: email, Password: Password})
+	require.NoError(t, err)
+
+	return &User{
+		ID:           auth.User.ID,
+		Email:        email,
+		Password:     Password,
+		AccessToken:  auth.AccessToken,
+		RefreshToken: auth.RefreshToken,
+		Client:       c,
+		server:       s,
+	}
+}
+
+// Response is a response to a raw request
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// Decode decodes the response's JSON body into v
+func (r *Response) Decode(t testing.TB, v any) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(r.Body, v), "response body: %s", r.Body)
+}
+
+// Do makes a raw request to the server, with body encoded as JSON when it isn't nil and
+// authenticated with token when it isn't empty. It is for what the client doesn't show,
+// such as headers and malformed requests.
+func (s *Server) Do(t testing.TB, method, path string, body any, token string) *Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, s.URL+path, reader)
+	require.NoError(t, err)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return &Response{StatusCode: resp.StatusCode, Header: resp.Header, Body: data}
+}
+
+// Do makes a raw request as the user
+func (u *User) Do(t testing.TB, method, path string, body any) *Response {
+	t.Helper()
+	return u.server.Do(t, method, path, body, u.AccessToken)
+}
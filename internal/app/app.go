@@ -0,0 +1,997 @@
+// Package app wires the repositories, services, HTTP router and background jobs together so
+// that every entrypoint (the API server, the worker, tests) builds them the same way.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/cache"
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/emails"
+	"github.com/lenon/portfolios/internal/grpcapi"
+	"github.com/lenon/portfolios/internal/handlers"
+	"github.com/lenon/portfolios/internal/jobs"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/push"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/router"
+	"github.com/lenon/portfolios/internal/runtime"
+	"github.com/lenon/portfolios/internal/services"
+	"github.com/lenon/portfolios/internal/utils"
+	"github.com/lenon/portfolios/migrations"
+)
+
+// marketDataCacheTTL is how long quotes and historical prices are cached
+const marketDataCacheTTL = 15 * time.Minute
+
+// passwordResetValidity is how long a password reset token can be used
+const passwordResetValidity = 1 * time.Hour
+
+// Option configures which subsystems BuildServices builds and what they are built with
+type Option func(*options)
+
+type options struct {
+	disableMarketData bool
+	disableAdmin      bool
+	provider          services.MarketDataProvider
+	emailService      services.EmailService
+	cacheStore        cache.Store
+	logger            *logger.AppLogger
+}
+
+// WithoutMarketData disables market data even when an API key is configured, which also
+// disables performance analytics and the market data jobs
+func WithoutMarketData() Option {
+	return func(o *options) {
+		o.disableMarketData = true
+	}
+}
+
+// WithoutAdmin disables the admin provisioning API even when an admin token is configured
+func WithoutAdmin() Option {
+	return func(o *options) {
+		o.disableAdmin = true
+	}
+}
+
+// WithMarketDataProvider replaces the configured market data provider. Market data is
+// enabled without an API key, and corporate action feed ingestion is disabled.
+func WithMarketDataProvider(provider services.MarketDataProvider) Option {
+	return func(o *options) {
+		o.provider = provider
+	}
+}
+
+// WithEmailService replaces the SMTP email service
+func WithEmailService(emailService services.EmailService) Option {
+	return func(o *options) {
+		o.emailService = emailService
+	}
+}
+
+// WithCacheStore replaces the configured cache store. The container does not close it.
+func WithCacheStore(store cache.Store) Option {
+	return func(o *options) {
+		o.cacheStore = store
+	}
+}
+
+// WithLogger sets the logger that startup and request failures are written to. It defaults
+// to the global logger.
+func WithLogger(log *logger.AppLogger) Option {
+	return func(o *options) {
+		o.logger = log
+	}
+}
+
+// Repositories holds the data access layer
+type Repositories struct {
+	User                repository.UserRepository
+	RefreshToken        repository.RefreshTokenRepository
+	PasswordReset       repository.PasswordResetRepository
+	PasswordHistory     repository.PasswordHistoryRepository
+	Portfolio           repository.PortfolioRepository
+	Transaction         repository.TransactionRepository
+	Holding             repository.HoldingRepository
+	TaxLot              repository.TaxLotRepository
+	CorporateAction     repository.CorporateActionRepository
+	PortfolioAction     repository.PortfolioActionRepository
+	PerformanceSnapshot repository.PerformanceSnapshotRepository
+	PerformanceMetrics  repository.PerformanceMetricsCacheRepository
+	SnapshotBackfill    repository.SnapshotBackfillCheckpointRepository
+	StockPlan           repository.StockPlanRepository
+	Blackout            repository.BlackoutRepository
+	RebalancePlan       repository.RebalancePlanRepository
+	BrokerConnection    repository.BrokerConnectionRepository
+	ReportSubscription  repository.ReportSubscriptionRepository
+	Tag                 repository.TagRepository
+	PortfolioGroup      repository.PortfolioGroupRepository
+	Simulation          repository.SimulationRepository
+	Security            repository.SecurityRepository
+	PeerBenchmark       repository.PeerBenchmarkRepository
+	OptionContract      repository.OptionContractRepository
+	Organization        repository.OrganizationRepository
+	APIKey              repository.APIKeyRepository
+	QueuedJob           repository.QueuedJobRepository
+	Notification        repository.NotificationRepository
+	PushDevice          repository.PushDeviceRepository
+	UserSettings        repository.UserSettingsRepository
+	FeatureFlag         repository.FeatureFlagRepository
+}
+
+// Services holds the business logic layer. MarketData, PerformanceAnalytics and
+// CorporateActionIngester are nil when market data is disabled, and AdminProvisioning is
+// nil when the admin API is disabled.
+type Services struct {
+	Token                   *services.TokenService
+	Email                   services.EmailService
+	Auth                    services.AuthService
+	PasswordReset           services.PasswordResetService
+	Password                services.PasswordService
+	Portfolio               services.PortfolioService
+	APIKey                  services.APIKeyService
+	Transaction             services.TransactionService
+	TaxLot                  services.TaxLotService
+	Holding                 services.HoldingService
+	StockPlan               services.StockPlanService
+	Option                  services.OptionService
+	Blackout                services.BlackoutService
+	RebalancePlan           services.RebalancePlanService
+	PeerComparison          services.PeerComparisonService
+	FeeComparison           services.FeeComparisonService
+	FeeSummary              services.FeeSummaryService
+	Contribution            services.ContributionService
+	Projection              services.ProjectionService
+	Simulation              services.SimulationService
+	WhatIf                  services.WhatIfService
+	Security                services.SecurityService
+	PerformanceSnapshot     services.PerformanceSnapshotService
+	SnapshotBackfill        services.SnapshotBackfillService
+	Certification           services.PerformanceCertificationService
+	Statement               services.StatementService
+	Export                  services.ExportService
+	ReportSubscription      services.ReportSubscriptionService
+	Tag                     services.TagService
+	Aggregation             services.AggregationService
+	Dashboard               services.DashboardService
+	PortfolioGroup          services.PortfolioGroupService
+	PerformanceAnalytics    services.PerformanceAnalyticsService
+	MarketData              services.MarketDataService
+	CorporateActionIngester *services.CorporateActionIngester
+	CorporateActionMonitor  *services.CorporateActionMonitor
+	CorporateActionCalendar services.CorporateActionCalendarService
+	CSVImport               services.CSVImportService
+	Recalculation           services.PortfolioRecalculationService
+	TrackerImport           services.TrackerImportService
+	OpeningLotImport        services.OpeningLotImportService
+	BrokerConnection        services.BrokerConnectionService
+	Reconciliation          services.ReconciliationService
+	PortfolioAction         services.PortfolioActionService
+	AdminProvisioning       services.AdminProvisioningService
+	UserAdmin               services.UserAdminService
+	Organization            services.OrganizationService
+	Delegation              services.DelegationService
+	JobQueue                services.JobQueueService
+	Notification            services.NotificationService
+	Push                    services.PushService
+	UserSettings            services.UserSettingsService
+	FeatureFlag             services.FeatureFlagService
+}
+
+// Container holds everything built from a configuration and database connection
+type Container struct {
+	Config          *config.Config
+	DB              *gorm.DB
+	Logger          *logger.AppLogger
+	Cache           cache.Store
+	Repositories    Repositories
+	Services        Services
+	RetentionPolicy models.SnapshotRetentionPolicy
+	RoundingPolicy  models.RoundingPolicy
+	PasswordPolicy  models.PasswordPolicy
+	PasswordHasher  utils.PasswordHasher
+	CORSPolicy      middleware.CORSPolicy
+	VAPIDKeys       *push.VAPIDKeys  // Nil when Web Push isn't configured
+	BrokerBox       *utils.SecretBox // Nil when broker connections aren't configured
+	EmailTemplates  *emails.Templates
+
+	ownsCache bool
+
+	// Built by BuildRouter and BuildJobs, and updated by ReloadConfig
+	authRateLimiter *middleware.RateLimiter
+	apiRateLimiter  *middleware.RateLimiter
+	bodyLogger      *middleware.BodyLogger
+	maintenance     *middleware.Maintenance
+	scheduler       *jobs.Scheduler
+	reload          reloadState
+}
+
+// BuildServices builds the repositories and services for cfg on db. Call Close when done
+// to release the cache store.
+func BuildServices(cfg *config.Config, db *gorm.DB, opts ...Option) (*Container, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.logger == nil {
+		o.logger = logger.GetLogger()
+	}
+
+	p, err := buildPolicies(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Container{
+		Config:          cfg,
+		DB:              db,
+		Logger:          o.logger,
+		RetentionPolicy: p.retention,
+		RoundingPolicy:  p.rounding,
+		PasswordPolicy:  p.password,
+		PasswordHasher:  p.hasher,
+		CORSPolicy:      p.cors,
+		VAPIDKeys:       p.vapid,
+		BrokerBox:       p.brokerBox,
+		EmailTemplates:  p.emailTemplates,
+	}
+
+	// Initialize shared cache store (Redis if configured, in-memory otherwise)
+	switch {
+	case o.cacheStore != nil:
+		c.Cache = o.cacheStore
+	case cfg.Cache.RedisURL != "":
+		store, err := cache.NewRedisStore(cfg.Cache.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		}
+		c.Cache = store
+		c.ownsCache = true
+		c.Logger.Info().Msg("Using Redis for caching and rate limiting")
+	default:
+		c.Cache = cache.NewMemoryStore()
+		c.ownsCache = true
+		c.Logger.Info().Msg("Using in-memory caching and rate limiting")
+	}
+
+	// Route queries on portfolio data to the schema of the caller's organization
+	if cfg.Database.MultiSchema {
+		if err := database.UseTenantSchemas(db); err != nil {
+			return nil, err
+		}
+		c.Logger.Info().Msg("Multi-schema mode enabled")
+	}
+
+	c.buildRepositories()
+	c.buildServices(o)
+	return c, nil
+}
+
+// policies are the settings BuildServices derives from the configuration
+type policies struct {
+	retention models.SnapshotRetentionPolicy
+	rounding  models.RoundingPolicy
+	password  models.PasswordPolicy
+	hasher    utils.PasswordHasher
+	cors      middleware.CORSPolicy
+	vapid     *push.VAPIDKeys
+	brokerBox *utils.SecretBox
+	// emailTemplates are the built-in email templates with the overrides of the runtime home
+	emailTemplates *emails.Templates
+}
+
+// buildPolicies derives the policies from cfg, reporting every invalid one
+func buildPolicies(cfg *config.Config) (policies, error) {
+	var errs []error
+	var p policies
+
+	p.retention = models.SnapshotRetentionPolicy{
+		DailyMonths:  cfg.Snapshots.DailyRetentionMonths,
+		WeeklyMonths: cfg.Snapshots.WeeklyRetentionMonths,
+	}
+	if err := p.retention.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid snapshot retention configuration: %w", err))
+	}
+
+	p.rounding = models.RoundingPolicy{
+		Mode:                 models.RoundingMode(cfg.Rounding.Mode),
+		EquityQuantityPlaces: int32(cfg.Rounding.EquityQuantityPlaces),
+		CryptoQuantityPlaces: int32(cfg.Rounding.CryptoQuantityPlaces),
+		AmountPlaces:         int32(cfg.Rounding.AmountPlaces),
+	}
+	if err := p.rounding.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid rounding configuration: %w", err))
+	}
+
+	p.password = models.PasswordPolicy{
+		MinLength:        cfg.Password.MinLength,
+		RequireUppercase: cfg.Password.RequireUppercase,
+		RequireLowercase: cfg.Password.RequireLowercase,
+		RequireNumber:    cfg.Password.RequireNumber,
+		RequireSymbol:    cfg.Password.RequireSymbol,
+		BannedPasswords:  cfg.Password.BannedPasswords,
+		HistorySize:      cfg.Password.HistorySize,
+	}
+	if err := p.password.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid password policy configuration: %w", err))
+	}
+
+	p.hasher = PasswordHasher(cfg)
+	if err := p.hasher.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid password hashing configuration: %w", err))
+	}
+
+	cors, err := middleware.NewCORSPolicy(cfg.Security.CORSPreset, cfg.Server.Environment, cfg.Server.CORSOrigins)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid CORS configuration: %w", err))
+	}
+	p.cors = cors
+
+	if cfg.Push.VAPIDPublicKey != "" || cfg.Push.VAPIDPrivateKey != "" {
+		vapid, err := push.ParseVAPIDKeys(cfg.Push.VAPIDPublicKey, cfg.Push.VAPIDPrivateKey, cfg.Push.VAPIDSubject)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid push configuration: %w", err))
+		}
+		p.vapid = vapid
+	}
+
+	if cfg.Brokers.EncryptionKey != "" {
+		box, err := utils.NewSecretBox(cfg.Brokers.EncryptionKey)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid brokers configuration: %w", err))
+		}
+		p.brokerBox = box
+	}
+
+	templatesDir := ""
+	if cfg.Runtime.HomeDir != "" {
+		templatesDir = filepath.Join(cfg.Runtime.HomeDir, runtime.EmailTemplatesDirName)
+	}
+	emailTemplates, err := emails.LoadTemplates(templatesDir)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	p.emailTemplates = emailTemplates
+
+	// Schedule errors already name the job and schedule
+	errs = append(errs, jobs.ValidateSchedules(cfg.Jobs.Schedules))
+
+	if err := services.ValidateFeatures(cfg.Features); err != nil {
+		errs = append(errs, fmt.Errorf("invalid features configuration: %w", err))
+	}
+
+	return p, errors.Join(errs...)
+}
+
+// ValidateConfig checks cfg the way BuildServices does, along with the values Validate
+// checks, without connecting to the database or cache. It reports every problem found.
+func ValidateConfig(cfg *config.Config) error {
+	_, err := buildPolicies(cfg)
+	return errors.Join(cfg.Validate(), err, validateLogOutputs(cfg))
+}
+
+// PasswordHasher returns the password hasher cfg configures, which ValidateConfig checks
+func PasswordHasher(cfg *config.Config) utils.PasswordHasher {
+	// Out of range parameters are clamped to values Validate rejects rather than wrapped
+	return utils.PasswordHasher{
+		Algorithm:         utils.PasswordHashAlgorithm(cfg.Password.HashAlgorithm),
+		Argon2Memory:      uint32(min(max(cfg.Password.Argon2Memory, 0), math.MaxUint32)),
+		Argon2Iterations:  uint32(min(max(cfg.Password.Argon2Iterations, 0), math.MaxUint32)),
+		Argon2Parallelism: uint8(min(max(cfg.Password.Argon2Parallelism, 0), math.MaxUint8)),
+		BcryptCost:        cfg.Password.BcryptCost,
+	}
+}
+
+// buildRepositories initializes the repositories
+func (c *Container) buildRepositories() {
+	db := c.DB
+	c.Repositories = Repositories{
+		User:                repository.NewUserRepository(db),
+		RefreshToken:        repository.NewRefreshTokenRepository(db),
+		PasswordReset:       repository.NewPasswordResetRepository(db),
+		PasswordHistory:     repository.NewPasswordHistoryRepository(db),
+		Portfolio:           repository.NewPortfolioRepository(db),
+		Transaction:         repository.NewTransactionRepository(db),
+		Holding:             repository.NewHoldingRepository(db),
+		TaxLot:              repository.NewTaxLotRepository(db),
+		CorporateAction:     repository.NewCorporateActionRepository(db),
+		PortfolioAction:     repository.NewPortfolioActionRepository(db),
+		PerformanceSnapshot: repository.NewPerformanceSnapshotRepository(db),
+		PerformanceMetrics:  repository.NewPerformanceMetricsCacheRepository(db),
+		SnapshotBackfill:    repository.NewSnapshotBackfillCheckpointRepository(db),
+		StockPlan:           repository.NewStockPlanRepository(db),
+		Blackout:            repository.NewBlackoutRepository(db),
+		RebalancePlan:       repository.NewRebalancePlanRepository(db),
+		BrokerConnection:    repository.NewBrokerConnectionRepository(db),
+		ReportSubscription:  repository.NewReportSubscriptionRepository(db),
+		Tag:                 repository.NewTagRepository(db),
+		PortfolioGroup:      repository.NewPortfolioGroupRepository(db),
+		Simulation:          repository.NewSimulationRepository(db),
+		Security:            repository.NewSecurityRepository(db),
+		PeerBenchmark:       repository.NewPeerBenchmarkRepository(db),
+		OptionContract:      repository.NewOptionContractRepository(db),
+		Organization:        repository.NewOrganizationRepository(db),
+		APIKey:              repository.NewAPIKeyRepository(db),
+		QueuedJob:           repository.NewQueuedJobRepository(db),
+		Notification:        repository.NewNotificationRepository(db),
+		PushDevice:          repository.NewPushDeviceRepository(db),
+		UserSettings:        repository.NewUserSettingsRepository(db),
+		FeatureFlag:         repository.NewFeatureFlagRepository(db),
+	}
+}
+
+// emailTransport returns the transport emails are sent through. Without one configured,
+// emails go to the SMTP settings, which fail to send until a host is set.
+func (c *Container) emailTransport() emails.Transport {
+	cfg := c.Config
+	switch cfg.EmailTransport() {
+	case "ses":
+		return emails.NewSESTransport(cfg.Email.SES.Region, cfg.Email.SES.AccessKeyID, cfg.Email.SES.SecretAccessKey)
+	case "sendgrid":
+		return emails.NewSendGridTransport(cfg.Email.SendGridAPIKey)
+	case "log":
+		return emails.NewLogTransport(c.Logger)
+	default:
+		return emails.NewSMTPTransport(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password)
+	}
+}
+
+// buildServices initializes the services on top of the repositories
+func (c *Container) buildServices(o *options) {
+	cfg := c.Config
+	r := c.Repositories
+	s := &c.Services
+
+	s.Token = services.NewTokenService(cfg.JWT.Secret)
+	s.Email = o.emailService
+	if s.Email == nil {
+		s.Email = services.NewEmailServiceWithTransport(c.emailTransport(), cfg.EmailFrom(), c.EmailTemplates)
+	}
+	s.Auth = services.NewAuthServiceWithPasswords(
+		r.User,
+		r.RefreshToken,
+		s.Token,
+		cfg.JWT.AccessTokenDuration,
+		cfg.JWT.RefreshTokenDuration,
+		cfg.JWT.RememberMeAccessDuration,
+		cfg.JWT.RememberMeRefreshDuration,
+		c.PasswordPolicy,
+		c.PasswordHasher,
+		c.Logger,
+	)
+	c.Logger.Info().Str("algorithm", c.PasswordHasher.String()).Msg("Password hashing configured")
+	s.Password = services.NewPasswordService(r.User, r.PasswordHistory, c.PasswordPolicy, c.PasswordHasher)
+	s.PasswordReset = services.NewPasswordResetServiceWithPasswords(r.User, r.PasswordReset, s.Email, passwordResetValidity, s.Password)
+	if cfg.Database.MultiSchema {
+		s.UserAdmin = services.NewUserAdminServiceWithTenantSchemas(c.DB, s.Email, passwordResetValidity, r.Organization)
+	} else {
+		s.UserAdmin = services.NewUserAdminService(c.DB, s.Email, passwordResetValidity)
+	}
+	s.UserSettings = services.NewUserSettingsService(r.UserSettings)
+	s.FeatureFlag = services.NewFeatureFlagService(r.FeatureFlag, r.User, cfg.Features)
+	s.Portfolio = services.NewPortfolioServiceWithSettings(r.Portfolio, r.User, r.Organization, s.UserSettings)
+	s.APIKey = services.NewAPIKeyService(r.APIKey)
+	s.Organization = services.NewOrganizationService(c.DB, s.Email, cfg.Admin.InviteValidity)
+	s.Delegation = services.NewDelegationService(c.DB, s.Email, cfg.Admin.InviteValidity)
+	s.Holding = services.NewHoldingServiceWithLedger(r.Holding, r.Portfolio, r.Transaction, c.RoundingPolicy)
+	s.StockPlan = services.NewStockPlanService(c.DB)
+	s.Blackout = services.NewBlackoutService(r.Blackout, r.Portfolio)
+	s.PeerComparison = services.NewPeerComparisonService(r.Portfolio, r.Holding, r.PerformanceSnapshot, r.PeerBenchmark)
+	s.FeeComparison = services.NewFeeComparisonService(r.Portfolio, r.Transaction)
+	s.FeeSummary = services.NewFeeSummaryService(r.Portfolio, r.Transaction, r.PerformanceSnapshot)
+	s.Contribution = services.NewContributionService(r.Portfolio, r.Transaction, r.PerformanceSnapshot)
+	s.Projection = services.NewProjectionService(r.Portfolio, r.Transaction, r.PerformanceSnapshot)
+	s.PerformanceSnapshot = services.NewPerformanceSnapshotServiceWithLedger(r.PerformanceSnapshot, r.Portfolio, r.Holding, r.Transaction, c.RoundingPolicy)
+	s.Certification = services.NewPerformanceCertificationService(r.Portfolio, r.Transaction, r.PerformanceSnapshot, []byte(cfg.JWT.Secret))
+	s.Statement = services.NewStatementServiceWithSettings(r.Portfolio, r.Transaction, r.PerformanceSnapshot, c.RoundingPolicy, s.UserSettings)
+	s.Export = services.NewExportService(r.User, r.UserSettings, r.Portfolio, r.Holding, r.TaxLot, r.Transaction, r.PerformanceSnapshot)
+	s.Push = services.NewPushService(r.PushDevice, c.VAPIDKeys)
+	s.Notification = services.NewNotificationServiceWithSettings(r.Notification, s.Push, s.UserSettings)
+	s.CorporateActionMonitor = services.NewCorporateActionMonitorWithNotifications(
+		r.CorporateAction, r.Portfolio, r.Holding, r.PortfolioAction, s.Notification,
+	)
+	s.JobQueue = services.NewJobQueueService(r.QueuedJob, r.Portfolio)
+	s.CSVImport = services.NewCSVImportServiceWithQueue(r.Transaction, r.Portfolio, r.Holding, s.JobQueue)
+	s.Simulation = services.NewSimulationService(r.Simulation, r.Portfolio, r.Transaction, r.PerformanceSnapshot, s.JobQueue)
+	s.Recalculation = services.NewPortfolioRecalculationServiceWithRounding(c.DB, c.RoundingPolicy)
+	s.TrackerImport = services.NewTrackerImportService(s.Portfolio, s.CSVImport, s.Recalculation)
+	s.OpeningLotImport = services.NewOpeningLotImportService(r.Portfolio, s.CSVImport, s.Recalculation)
+
+	// Broker connections sync with the providers whose credentials are configured
+	var brokerProviders []services.BrokerDataProvider
+	if cfg.Brokers.Plaid.ClientID != "" && cfg.Brokers.Plaid.Secret != "" {
+		brokerProviders = append(brokerProviders, services.NewPlaidProvider(cfg.Brokers.Plaid.ClientID, cfg.Brokers.Plaid.Secret, cfg.Brokers.Plaid.Environment))
+	}
+	s.BrokerConnection = services.NewBrokerConnectionService(
+		r.BrokerConnection,
+		r.Portfolio,
+		r.Transaction,
+		r.Holding,
+		s.CSVImport,
+		s.Recalculation,
+		c.BrokerBox,
+		brokerProviders...,
+	)
+	if c.brokerSyncEnabled() {
+		c.Logger.Info().Msg("Broker connections enabled")
+	}
+	s.Reconciliation = services.NewReconciliationService(r.Portfolio, r.Holding, s.CSVImport, s.Recalculation)
+
+	c.buildMarketData(o)
+
+	// Symbol searches go to the provider when market data is available
+	s.Security = services.NewSecurityService(r.Security, s.MarketData)
+
+	// New transactions are refused for symbols the provider doesn't list when market data is
+	// available; without it only stored securities could be checked
+	var symbolValidator services.SecurityService
+	if s.MarketData != nil {
+		symbolValidator = s.Security
+	}
+	s.Transaction = services.NewTransactionServiceWithSymbolValidation(r.Transaction, r.Portfolio, r.Holding, symbolValidator)
+
+	// The corporate action calendar adds actions the provider announced when market data is
+	// available
+	var announcedActions services.AnnouncedCorporateActions
+	if s.CorporateActionIngester != nil {
+		announcedActions = s.CorporateActionIngester
+	}
+	s.CorporateActionCalendar = services.NewCorporateActionCalendarService(r.CorporateAction, r.Portfolio, r.Holding, announcedActions)
+
+	// Spinoff cost basis can be allocated by fair market value when market data is available
+	s.PortfolioAction = services.NewPortfolioActionServiceWithMarketData(c.DB, c.RoundingPolicy, s.MarketData)
+
+	// Rebalance plans refuse halted or suspended symbols when market data is available
+	s.RebalancePlan = services.NewRebalancePlanServiceWithTradingRestrictions(r.RebalancePlan, r.Portfolio, s.Transaction, s.MarketData)
+
+	// Tax-loss harvesting opportunities are priced with quotes when market data is available
+	s.TaxLot = services.NewTaxLotServiceWithMarketData(
+		r.TaxLot,
+		r.Portfolio,
+		r.Holding,
+		r.Transaction,
+		c.RoundingPolicy,
+		s.MarketData,
+		cfg.TaxLossHarvesting.Replacements,
+	)
+
+	// What-if trades value untraded positions at their quotes when market data is available
+	s.WhatIf = services.NewWhatIfService(r.Portfolio, r.Holding, r.TaxLot, s.MarketData, c.RoundingPolicy)
+
+	// Option positions are valued and settled at expiry when market data is available
+	s.Option = services.NewOptionService(r.OptionContract, r.Portfolio, r.Transaction, r.Holding, s.Transaction, s.MarketData)
+
+	// Performance digests list top movers when market data is available
+	s.ReportSubscription = services.NewReportSubscriptionServiceWithSettings(
+		r.ReportSubscription,
+		r.Portfolio,
+		r.Transaction,
+		r.PerformanceSnapshot,
+		r.Holding,
+		s.MarketData,
+		s.UserSettings,
+		[]byte(cfg.JWT.Secret),
+	)
+
+	// Snapshots are backfilled at historical closing prices, so backfills are refused without
+	// market data
+	s.SnapshotBackfill = services.NewSnapshotBackfillService(
+		r.Portfolio,
+		r.Transaction,
+		r.PerformanceSnapshot,
+		r.SnapshotBackfill,
+		s.MarketData,
+		s.JobQueue,
+		c.RoundingPolicy,
+	)
+
+	// Initialize performance analytics service (only if market data is available)
+	if s.MarketData != nil {
+		s.PerformanceAnalytics = services.NewPerformanceAnalyticsServiceWithCache(
+			r.Portfolio,
+			r.Transaction,
+			r.PerformanceSnapshot,
+			s.MarketData,
+			r.PerformanceMetrics,
+		)
+		c.Logger.Info().Msg("Performance analytics service initialized")
+	} else {
+		c.Logger.Warn().Msg("Performance analytics service not initialized (requires market data)")
+	}
+
+	// Performance across tagged portfolios is measured when performance analytics are available
+	s.Tag = services.NewTagService(r.Tag, r.Portfolio, r.Transaction, s.PerformanceAnalytics)
+
+	// The household overview prices holdings, converts currencies and measures performance
+	// when market data is available
+	s.Aggregation = services.NewAggregationServiceWithSettings(r.Portfolio, r.Holding, s.MarketData, s.PerformanceAnalytics, s.UserSettings)
+	s.PortfolioGroup = services.NewPortfolioGroupService(r.PortfolioGroup, r.Portfolio, s.Aggregation, s.PerformanceAnalytics)
+
+	// The dashboard only lists stored corporate actions, so that loading the home screen
+	// spends none of the provider's request budget
+	storedActions := services.NewCorporateActionCalendarService(r.CorporateAction, r.Portfolio, r.Holding, nil)
+	s.Dashboard = services.NewDashboardService(r.Portfolio, r.Holding, s.MarketData, storedActions, s.Notification, s.UserSettings)
+
+	// Initialize admin provisioning (only if an admin API token is configured)
+	if cfg.Admin.APIToken != "" && !o.disableAdmin {
+		if cfg.Database.MultiSchema {
+			s.AdminProvisioning = services.NewAdminProvisioningServiceWithTenantSchemas(
+				c.DB,
+				s.Email,
+				cfg.Admin.InviteValidity,
+				c.migrateTenantSchema,
+			)
+		} else {
+			s.AdminProvisioning = services.NewAdminProvisioningService(c.DB, s.Email, cfg.Admin.InviteValidity)
+		}
+		c.Logger.Info().Msg("Admin provisioning API enabled")
+	}
+}
+
+// migrateTenantSchema creates or migrates an organization's schema with the embedded tenant
+// migrations
+func (c *Container) migrateTenantSchema(ctx context.Context, schema string) error {
+	applied, err := database.MigrateTenantSchema(ctx, c.DB, schema, migrations.Tenant)
+	if err != nil {
+		return err
+	}
+	if applied > 0 {
+		c.Logger.Info().Str("schema", schema).Int("migrations", applied).Msg("Migrated tenant schema")
+	}
+	return nil
+}
+
+// buildMarketData initializes the market data service and corporate action feed ingestion
+func (c *Container) buildMarketData(o *options) {
+	cfg := c.Config
+	s := &c.Services
+
+	switch {
+	case o.disableMarketData:
+		c.Logger.Warn().Msg("Market data service disabled")
+	case o.provider != nil:
+		s.MarketData = services.NewMarketDataServiceWithCache(o.provider, c.Cache, marketDataCacheTTL)
+	case cfg.MarketData.APIKey != "":
+		alphaVantageProvider := services.NewAlphaVantageProvider(cfg.MarketData.APIKey)
+		quotaTracker := services.NewQuotaTrackerWithStore(
+			c.Cache,
+			cfg.MarketData.Provider,
+			cfg.MarketData.DailyRequestLimit,
+			cfg.MarketData.MinuteRequestLimit,
+		)
+		s.MarketData = services.NewMarketDataServiceWithBreakers(
+			alphaVantageProvider, c.cryptoProvider(), c.Cache, quotaTracker, marketDataCacheTTL, cfg.MarketData.QuoteConcurrency,
+			services.NewCircuitBreaker(cfg.MarketData.Provider, cfg.MarketData.BreakerFailures, cfg.MarketData.BreakerCooldown),
+			services.NewCircuitBreaker(cfg.MarketData.CryptoProvider, cfg.MarketData.BreakerFailures, cfg.MarketData.BreakerCooldown),
+		)
+		s.CorporateActionIngester = services.NewCorporateActionIngester(
+			alphaVantageProvider,
+			c.Repositories.CorporateAction,
+			c.Repositories.Holding,
+			quotaTracker,
+			services.DefaultCorporateActionLookbackDays,
+		)
+		c.Logger.Info().
+			Int("daily_limit", cfg.MarketData.DailyRequestLimit).
+			Int("minute_limit", cfg.MarketData.MinuteRequestLimit).
+			Int("quote_concurrency", cfg.MarketData.QuoteConcurrency).
+			Int("breaker_failures", cfg.MarketData.BreakerFailures).
+			Dur("breaker_cooldown", cfg.MarketData.BreakerCooldown).
+			Msg("Market data service initialized with Alpha Vantage provider")
+	default:
+		c.Logger.Warn().Msg("Market data service not initialized (no API key provided)")
+	}
+}
+
+// cryptoProvider returns the configured provider for coin pairs such as BTC-USD, or nil
+// when crypto pricing is turned off and coin pairs go to the equity provider
+func (c *Container) cryptoProvider() services.MarketDataProvider {
+	switch provider := c.Config.MarketData.CryptoProvider; provider {
+	case "coingecko":
+		c.Logger.Info().Msg("Crypto prices provided by CoinGecko")
+		return services.NewCoinGeckoProvider(c.Config.MarketData.CryptoAPIKey)
+	case "", "none":
+		return nil
+	default:
+		c.Logger.Warn().Str("provider", provider).Msg("Unknown crypto market data provider; crypto pricing disabled")
+		return nil
+	}
+}
+
+// BuildHandlers builds the HTTP handlers. Handlers whose service is disabled are left nil
+// so that their routes are not registered.
+func (c *Container) BuildHandlers() router.Handlers {
+	r := c.Repositories
+	s := c.Services
+
+	h := router.Handlers{
+		Auth: handlers.NewAuthHandler(
+			s.Auth,
+			s.PasswordReset,
+			r.User,
+			int(c.Config.JWT.AccessTokenDuration.Seconds()),
+		),
+		Portfolio:           handlers.NewPortfolioHandlerWithTags(s.Portfolio, s.Tag),
+		Transaction:         handlers.NewTransactionHandlerWithTags(s.Transaction, s.Blackout, s.Tag),
+		Tag:                 handlers.NewTagHandler(s.Tag),
+		Aggregation:         handlers.NewAggregationHandler(s.Aggregation),
+		Dashboard:           handlers.NewDashboardHandler(s.Dashboard),
+		PortfolioGroup:      handlers.NewPortfolioGroupHandler(s.PortfolioGroup),
+		Import:              handlers.NewImportHandler(s.CSVImport),
+		TrackerImport:       handlers.NewTrackerImportHandler(s.JobQueue),
+		OpeningLotImport:    handlers.NewOpeningLotImportHandler(s.OpeningLotImport),
+		BrokerConnection:    handlers.NewBrokerConnectionHandler(s.BrokerConnection),
+		Reconciliation:      handlers.NewReconciliationHandler(s.Reconciliation),
+		Job:                 handlers.NewJobHandler(s.JobQueue),
+		Notification:        handlers.NewNotificationHandler(s.Notification),
+		Push:                handlers.NewPushHandler(s.Push),
+		Settings:            handlers.NewUserSettingsHandler(s.UserSettings),
+		Holding:             handlers.NewHoldingHandlerWithTradingRestrictions(s.Holding, s.MarketData),
+		PerformanceSnapshot: handlers.NewPerformanceSnapshotHandler(s.PerformanceSnapshot),
+		Certification:       handlers.NewPerformanceCertificationHandler(s.Certification),
+		Statement:           handlers.NewStatementHandler(s.Statement),
+		Export:              handlers.NewExportHandler(s.Export),
+		ReportSubscription:  handlers.NewReportSubscriptionHandler(s.ReportSubscription),
+		StockPlan:           handlers.NewStockPlanHandler(s.StockPlan),
+		Option:              handlers.NewOptionHandler(s.Option),
+		Blackout:            handlers.NewBlackoutHandler(s.Blackout),
+		RebalancePlan:       handlers.NewRebalancePlanHandler(s.RebalancePlan),
+		PeerComparison:      handlers.NewPeerComparisonHandler(s.PeerComparison),
+		FeeComparison:       handlers.NewFeeComparisonHandler(s.FeeComparison),
+		FeeSummary:          handlers.NewFeeSummaryHandler(s.FeeSummary),
+		Contribution:        handlers.NewContributionHandler(s.Contribution),
+		Projection:          handlers.NewProjectionHandler(s.Projection),
+		Simulation:          handlers.NewSimulationHandler(s.Simulation),
+		WhatIf:              handlers.NewWhatIfHandler(s.WhatIf),
+		Security:            handlers.NewSecurityHandler(s.Security),
+		Recalculation:       handlers.NewRecalculationHandler(s.JobQueue),
+		SnapshotBackfill:    handlers.NewSnapshotBackfillHandler(s.SnapshotBackfill),
+		TaxLot:              handlers.NewTaxLotHandler(s.TaxLot, s.JobQueue),
+		PortfolioAction:     handlers.NewPortfolioActionHandlerWithCalendar(r.PortfolioAction, r.Portfolio, s.PortfolioAction, s.CorporateActionCalendar),
+		UserAdmin:           handlers.NewUserAdminHandler(s.UserAdmin),
+		Organization:        handlers.NewOrganizationHandler(s.Organization),
+		Delegation:          handlers.NewDelegationHandler(s.Delegation),
+		FeatureFlag:         handlers.NewFeatureFlagHandler(s.FeatureFlag),
+		Password:            handlers.NewPasswordHandler(s.Password),
+	}
+	if c.Config.Security.SessionCookies {
+		h.Auth = handlers.NewAuthHandlerWithSessionCookies(
+			s.Auth,
+			s.PasswordReset,
+			r.User,
+			int(c.Config.JWT.AccessTokenDuration.Seconds()),
+			handlers.SessionCookieConfig{
+				Secure:                    c.Config.Server.Environment != "development",
+				RefreshTokenDuration:      c.Config.JWT.RefreshTokenDuration,
+				RememberMeRefreshDuration: c.Config.JWT.RememberMeRefreshDuration,
+			},
+		)
+	}
+	if s.PerformanceAnalytics != nil {
+		h.PerformanceAnalytics = handlers.NewPerformanceAnalyticsHandler(s.PerformanceAnalytics)
+	}
+	if s.MarketData != nil {
+		h.MarketData = handlers.NewMarketDataHandler(s.MarketData)
+	}
+	if s.AdminProvisioning != nil {
+		h.Admin = handlers.NewAdminHandler(s.AdminProvisioning)
+		if c.maintenance != nil {
+			h.Maintenance = handlers.NewMaintenanceHandler(c.maintenance)
+		}
+	}
+	return h
+}
+
+// BuildRouter builds the Gin engine with the global middleware and every enabled route.
+// Requests are logged to requestLogger.
+func (c *Container) BuildRouter(requestLogger *logger.AppLogger) *gin.Engine {
+	cfg := c.Config
+	engine := gin.New()
+
+	// Reloading the configuration sets the request log level too
+	c.reload.mu.Lock()
+	c.reload.loggers = append(c.reload.loggers, requestLogger)
+	c.reload.mu.Unlock()
+
+	// Apply global middleware
+	engine.Use(middleware.RequestID())
+	engine.Use(middleware.LoggingMiddleware(requestLogger))
+	c.bodyLogger = middleware.NewBodyLogger(requestLogger, cfg.Logging.BodyRoutes, cfg.Logging.BodyMaxBytes)
+	engine.Use(c.bodyLogger.Middleware())
+	engine.Use(middleware.RecoveryLoggingMiddleware(c.Logger))
+	engine.Use(middleware.ErrorHandler())
+	engine.Use(middleware.CORSWithPolicy(c.CORSPolicy))
+	engine.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+		HSTSMaxAge:            cfg.Security.HSTSMaxAge,
+		ContentSecurityPolicy: contentSecurityPolicy(cfg.Security.ContentSecurityPolicy),
+	}))
+
+	// Rate limit counters and the maintenance mode set through the admin API are shared by
+	// every instance when Redis is configured
+	var store cache.Store
+	if cfg.Cache.RedisURL != "" {
+		store = c.Cache
+	}
+
+	// Maintenance mode leaves the health check and the admin API up, so it can be turned off
+	c.maintenance = middleware.NewMaintenance(store, cfg.Server.MaintenanceMode, cfg.Server.MaintenanceRetryAfter)
+	engine.Use(c.maintenance.Middleware("/health", "/api/admin/"))
+
+	if cfg.Security.CSRFProtection {
+		engine.Use(middleware.CSRFProtection(cfg.Server.Environment != "development"))
+	}
+	engine.Use(middleware.RequestTimeout(cfg.Server.RequestTimeout))
+
+	// Create rate limiters for the authentication endpoints and the rest of the API. The API
+	// limiter is created even when it is off, so that reloading the configuration can turn it
+	// on
+	c.authRateLimiter = middleware.NewScopedRateLimiter("auth", store, cfg.Security.RateLimitRequests, cfg.Security.RateLimitDuration)
+	c.apiRateLimiter = middleware.NewScopedRateLimiter("api", store, cfg.Security.APIRateLimitRequests, cfg.Security.APIRateLimitDuration)
+
+	auth := router.Auth{
+		TokenService:      c.Services.Token,
+		APIKeys:           c.Services.APIKey,
+		Users:             c.Repositories.User,
+		Organizations:     c.Services.Organization,
+		Delegations:       c.Services.Delegation,
+		UnsubscribeTokens: c.Services.ReportSubscription,
+		RateLimit:         c.authRateLimiter.Middleware(),
+		APIRateLimit:      c.apiRateLimiter.Middleware(),
+		Features:          c.Services.FeatureFlag,
+	}
+	if c.Services.AdminProvisioning != nil {
+		auth.AdminToken = cfg.Admin.APIToken
+	}
+	if cfg.Database.MultiSchema {
+		auth.TenantSchemas = c.Repositories.Organization
+	}
+
+	// Register health check and API routes
+	router.Register(engine, c.BuildHandlers(), auth)
+
+	return engine
+}
+
+// BuildGRPCServer builds the gRPC server for internal integrations, authenticating calls
+// the same way as the HTTP API. Market data and performance are only served when market
+// data is enabled.
+func (c *Container) BuildGRPCServer() *grpc.Server {
+	s := c.Services
+	auth := grpcapi.Auth{
+		TokenService: s.Token,
+		APIKeys:      s.APIKey,
+		Users:        c.Repositories.User,
+	}
+	if c.Config.Database.MultiSchema {
+		auth.TenantSchemas = c.Repositories.Organization
+	}
+
+	return grpcapi.NewServer(grpcapi.Services{
+		Portfolio:            s.Portfolio,
+		Transaction:          s.Transaction,
+		Holding:              s.Holding,
+		Blackout:             s.Blackout,
+		MarketData:           s.MarketData,
+		PerformanceAnalytics: s.PerformanceAnalytics,
+	}, auth, c.Logger)
+}
+
+// BuildJobs builds the background job scheduler. Jobs that depend on a disabled subsystem
+// are not added.
+func (c *Container) BuildJobs() *jobs.Scheduler {
+	r := c.Repositories
+	s := c.Services
+	scheduler := jobs.NewScheduler()
+
+	// Add corporate action detection job
+	scheduler.AddJob(c.tenantJob(jobs.NewCorporateActionDetectionJobWithIngester(s.CorporateActionMonitor, s.CorporateActionIngester)))
+
+	// Add stale rebalance plan reminders and performance digests (only if email delivery is configured)
+	if c.Config.EmailTransport() != "" {
+		scheduler.AddJob(c.tenantJob(jobs.NewRebalancePlanReminderJob(r.RebalancePlan, r.User, s.Email)))
+		scheduler.AddJob(c.tenantJob(jobs.NewReportDigestJobWithNotifications(
+			r.ReportSubscription, r.User, s.ReportSubscription, s.Email, s.Notification,
+		)))
+	}
+
+	// Add broker connection syncs (only if a broker provider is configured)
+	if c.brokerSyncEnabled() {
+		scheduler.AddJob(c.tenantJob(jobs.NewBrokerSyncJob(s.BrokerConnection)))
+	}
+
+	// Add anonymized peer benchmark aggregation
+	scheduler.AddJob(c.tenantJob(jobs.NewPeerBenchmarkJob(s.PeerComparison)))
+
+	// Add performance snapshot downsampling
+	scheduler.AddJob(c.tenantJob(jobs.NewSnapshotCompactionJob(r.Portfolio, r.PerformanceSnapshot, c.RetentionPolicy)))
+
+	// Add market data jobs (only if market data service is available)
+	if s.MarketData != nil {
+		// Price update job - refreshes market data cache and held symbols' trading statuses
+		scheduler.AddJob(c.tenantJob(jobs.NewPriceUpdateJob(s.MarketData, r.Holding)))
+
+		// Option expiration job - assigns or expires contracts the day after their expiry
+		scheduler.AddJob(c.tenantJob(jobs.NewOptionExpirationJob(s.Option)))
+
+		// Performance snapshot job - generates daily snapshots with prices prefetched once per symbol
+		scheduler.AddJob(c.tenantJob(jobs.NewSnapshotGenerationJob(r.Portfolio, r.Holding, s.PerformanceSnapshot, s.MarketData)))
+
+		// Performance metrics job - materializes the standard periods' metrics for the analytics endpoints
+		scheduler.AddJob(c.tenantJob(jobs.NewPerformanceMetricsJob(r.Portfolio, s.PerformanceAnalytics)))
+
+		// Cleanup job - cleans up stale data
+		scheduler.AddJob(jobs.NewCleanupJob(s.MarketData, 365))
+
+		c.Logger.Info().Msg("Market data background jobs initialized")
+	}
+
+	// The schedules were validated with the rest of the configuration
+	if err := scheduler.SetSchedules(c.Config.Jobs.Schedules); err != nil {
+		c.Logger.Warn().Err(err).Msg("Ignoring job schedule overrides")
+	}
+	c.scheduler = scheduler
+
+	return scheduler
+}
+
+// brokerSyncEnabled reports whether broker connections can be synced: access tokens can be
+// sealed and a provider has credentials
+func (c *Container) brokerSyncEnabled() bool {
+	plaid := c.Config.Brokers.Plaid
+	return c.BrokerBox != nil && plaid.ClientID != "" && plaid.Secret != ""
+}
+
+// BuildWorkerPool builds the pool of workers that run queued imports, recalculations, reports,
+// simulations and snapshot backfills, with as many workers as configured
+func (c *Container) BuildWorkerPool() *jobs.WorkerPool {
+	s := c.Services
+	pool := jobs.NewWorkerPool(c.Repositories.QueuedJob, c.Config.Server.JobWorkers, s.JobQueue.Enqueued())
+
+	// Imports tell their users when they finish
+	pool.Handle(models.QueuedJobTypeCSVImport, services.NotifyingImportJobHandler(services.CSVImportJobHandler(s.CSVImport), s.Notification))
+	pool.Handle(models.QueuedJobTypeTrackerImport, services.NotifyingImportJobHandler(services.TrackerImportJobHandler(s.TrackerImport), s.Notification))
+	pool.Handle(models.QueuedJobTypeRecalculation, services.RecalculationJobHandler(s.Recalculation))
+	pool.Handle(models.QueuedJobTypeTaxReport, services.TaxReportJobHandler(s.TaxLot))
+	pool.Handle(models.QueuedJobTypeSimulation, services.SimulationJobHandler(s.Simulation))
+	pool.Handle(models.QueuedJobTypeSnapshotBackfill, services.SnapshotBackfillJobHandler(s.SnapshotBackfill))
+
+	return pool
+}
+
+// contentSecurityPolicy returns the configured content security policy, or the default one
+// if none is configured
+func contentSecurityPolicy(configured string) string {
+	if configured == "" {
+		return middleware.DefaultContentSecurityPolicy
+	}
+	return configured
+}
+
+// tenantJob makes a job that works on portfolio data run for every tenant schema in
+// multi-schema mode
+func (c *Container) tenantJob(job jobs.Job) jobs.Job {
+	if !c.Config.Database.MultiSchema {
+		return job
+	}
+	return jobs.NewTenantJob(job, c.Repositories.Organization.FindSchemaNames)
+}
+
+// Close releases the cache store unless it was supplied with WithCacheStore
+func (c *Container) Close() error {
+	if c.ownsCache {
+		return c.Cache.Close()
+	}
+	return nil
+}
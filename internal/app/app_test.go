@@ -0,0 +1,279 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+	"github.com/lenon/portfolios/internal/utils"
+)
+
+// stubProvider satisfies services.MarketDataProvider without network access
+type stubProvider struct{}
+
+func (stubProvider) GetQuote(_ context.Context, symbol string) (*services.Quote, error) {
+	return &services.Quote{Symbol: symbol, Price: decimal.NewFromInt(100), LastUpdated: time.Now()}, nil
+}
+
+func (stubProvider) GetQuotes(_ context.Context, _ []string) (map[string]*services.Quote, error) {
+	return map[string]*services.Quote{}, nil
+}
+
+func (stubProvider) GetHistoricalPrices(_ context.Context, _ string, _, _ time.Time) ([]*services.HistoricalPrice, error) {
+	return nil, nil
+}
+
+func (stubProvider) GetExchangeRate(_ context.Context, _, _ string) (decimal.Decimal, error) {
+	return decimal.NewFromInt(1), nil
+}
+
+func (stubProvider) IsAvailable() bool { return true }
+
+func testConfig() *config.Config {
+	return &config.Config{
+		JWT: config.JWTConfig{
+			Secret:              "test-secret-key-for-jwt-signing-32-chars",
+			AccessTokenDuration: 30 * time.Minute,
+		},
+		Security: config.SecurityConfig{
+			RateLimitRequests: 5,
+			RateLimitDuration: time.Minute,
+		},
+		Admin: config.AdminConfig{
+			InviteValidity: 24 * time.Hour,
+		},
+		Snapshots: config.SnapshotConfig{
+			DailyRetentionMonths:  24,
+			WeeklyRetentionMonths: 60,
+		},
+		Rounding: config.RoundingConfig{
+			Mode:                 "HALF_UP",
+			EquityQuantityPlaces: 8,
+			CryptoQuantityPlaces: 8,
+			AmountPlaces:         8,
+		},
+		Password: config.PasswordConfig{
+			MinLength:         8,
+			RequireUppercase:  true,
+			RequireLowercase:  true,
+			RequireNumber:     true,
+			HashAlgorithm:     "argon2id",
+			Argon2Memory:      64 * 1024,
+			Argon2Iterations:  3,
+			Argon2Parallelism: 4,
+			BcryptCost:        12,
+		},
+	}
+}
+
+func setupContainer(t *testing.T, cfg *config.Config, opts ...Option) *Container {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	container, err := BuildServices(cfg, db, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = container.Close() })
+	return container
+}
+
+func routeSet(engine *gin.Engine) map[string]bool {
+	routes := make(map[string]bool)
+	for _, route := range engine.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	return routes
+}
+
+func TestBuildServices_InvalidRetentionPolicy(t *testing.T) {
+	cfg := testConfig()
+	cfg.Snapshots.WeeklyRetentionMonths = 12
+
+	_, err := BuildServices(cfg, nil)
+	assert.Error(t, err)
+}
+
+func TestBuildServices_InvalidRoundingPolicy(t *testing.T) {
+	cfg := testConfig()
+	cfg.Rounding.Mode = "UP"
+
+	_, err := BuildServices(cfg, nil)
+	assert.ErrorIs(t, err, models.ErrInvalidRoundingPolicy)
+}
+
+func TestBuildServices_InvalidPasswordPolicy(t *testing.T) {
+	cfg := testConfig()
+	cfg.Password.MinLength = 4
+
+	_, err := BuildServices(cfg, nil)
+	assert.ErrorIs(t, err, models.ErrInvalidPasswordPolicy)
+}
+
+func TestBuildServices_InvalidPasswordHasher(t *testing.T) {
+	cfg := testConfig()
+	cfg.Password.HashAlgorithm = "scrypt"
+
+	_, err := BuildServices(cfg, nil)
+	assert.ErrorIs(t, err, utils.ErrInvalidPasswordHasher)
+}
+
+func TestBuildServices_InvalidCORSPreset(t *testing.T) {
+	cfg := testConfig()
+	cfg.Security.CORSPreset = "staging"
+
+	_, err := BuildServices(cfg, nil)
+	assert.ErrorIs(t, err, middleware.ErrInvalidCORSPreset)
+}
+
+func TestContainer_BuildRouter_Defaults(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	container := setupContainer(t, testConfig())
+	engine := container.BuildRouter(logger.GetLogger())
+
+	routes := routeSet(engine)
+	assert.True(t, routes["GET /health"])
+	assert.True(t, routes["POST /api/auth/login"])
+	assert.True(t, routes["GET /api/v1/portfolios"])
+	assert.True(t, routes["GET /api/report-subscriptions/unsubscribe"])
+
+	// Market data and the admin API are not configured
+	assert.Nil(t, container.Services.MarketData)
+	assert.False(t, routes["GET /api/v1/market/quote/:symbol"])
+	assert.False(t, routes["GET /api/admin/v1/orgs/:org_id"])
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, middleware.DefaultContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+}
+
+func TestContainer_BuildRouter_AllSubsystems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := testConfig()
+	cfg.Admin.APIToken = "admin-token"
+	container := setupContainer(t, cfg, WithMarketDataProvider(stubProvider{}))
+
+	routes := routeSet(container.BuildRouter(logger.GetLogger()))
+	assert.True(t, routes["GET /api/v1/market/quote/:symbol"])
+	assert.True(t, routes["GET /api/admin/v1/orgs/:org_id"])
+	assert.NotNil(t, container.Services.PerformanceAnalytics)
+}
+
+func TestContainer_BuildRouter_DisabledSubsystems(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := testConfig()
+	cfg.Admin.APIToken = "admin-token"
+	cfg.MarketData.APIKey = "alpha-vantage-key"
+	container := setupContainer(t, cfg, WithoutMarketData(), WithoutAdmin())
+
+	routes := routeSet(container.BuildRouter(logger.GetLogger()))
+	assert.False(t, routes["GET /api/v1/market/quote/:symbol"])
+	assert.False(t, routes["GET /api/admin/v1/orgs/:org_id"])
+	assert.Nil(t, container.Services.CorporateActionIngester)
+	assert.Nil(t, container.Services.AdminProvisioning)
+}
+
+func TestContainer_BuildGRPCServer(t *testing.T) {
+	container := setupContainer(t, testConfig())
+	registered := container.BuildGRPCServer().GetServiceInfo()
+	assert.Contains(t, registered, "portfolios.v1.PortfolioService")
+	assert.Contains(t, registered, "portfolios.v1.TransactionService")
+	assert.NotContains(t, registered, "portfolios.v1.MarketDataService")
+	assert.NotContains(t, registered, "portfolios.v1.PerformanceService")
+
+	container = setupContainer(t, testConfig(), WithMarketDataProvider(stubProvider{}))
+	registered = container.BuildGRPCServer().GetServiceInfo()
+	assert.Contains(t, registered, "portfolios.v1.MarketDataService")
+	assert.Contains(t, registered, "portfolios.v1.PerformanceService")
+}
+
+func TestContainer_CryptoProvider(t *testing.T) {
+	cfg := testConfig()
+	container := setupContainer(t, cfg, WithoutMarketData())
+
+	cfg.MarketData.CryptoProvider = "coingecko"
+	assert.IsType(t, &services.CoinGeckoProvider{}, container.cryptoProvider())
+
+	cfg.MarketData.CryptoProvider = "none"
+	assert.Nil(t, container.cryptoProvider())
+
+	cfg.MarketData.CryptoProvider = "unknown"
+	assert.Nil(t, container.cryptoProvider())
+}
+
+func TestContainer_BuildJobs(t *testing.T) {
+	t.Run("without optional subsystems", func(t *testing.T) {
+		container := setupContainer(t, testConfig())
+
+		assert.Equal(t, []string{
+			"CorporateActionDetection",
+			"PeerBenchmark",
+			"SnapshotCompaction",
+		}, container.BuildJobs().JobNames())
+	})
+
+	t.Run("with broker connections", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.Brokers.EncryptionKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+		cfg.Brokers.Plaid.ClientID = "client-id"
+		cfg.Brokers.Plaid.Secret = "secret"
+		container := setupContainer(t, cfg)
+
+		assert.Contains(t, container.BuildJobs().JobNames(), "BrokerSync")
+	})
+
+	t.Run("with email and market data", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.SMTP.Host = "smtp.example.com"
+		container := setupContainer(t, cfg, WithMarketDataProvider(stubProvider{}))
+
+		assert.Equal(t, []string{
+			"CorporateActionDetection",
+			"RebalancePlanReminder",
+			"ReportDigest",
+			"PeerBenchmark",
+			"SnapshotCompaction",
+			"PriceUpdate",
+			"OptionExpiration",
+			"SnapshotGeneration",
+			"PerformanceMetrics",
+			"Cleanup",
+		}, container.BuildJobs().JobNames())
+	})
+}
+
+func TestContainer_MultiSchema(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := testConfig()
+	cfg.Admin.APIToken = "admin-token"
+	cfg.Database.MultiSchema = true
+	container := setupContainer(t, cfg)
+
+	routes := routeSet(container.BuildRouter(logger.GetLogger()))
+	assert.True(t, routes["GET /api/v1/portfolios"])
+	assert.NotNil(t, container.Services.AdminProvisioning)
+
+	// Jobs keep their names when they run for every tenant schema
+	assert.Equal(t, []string{
+		"CorporateActionDetection",
+		"PeerBenchmark",
+		"SnapshotCompaction",
+	}, container.BuildJobs().JobNames())
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/runtime"
+)
+
+// LoadConfig initializes the runtime home directory named by RUNTIME_HOME_DIR and loads the
+// configuration from its config file, if present, and environment variables. Log paths
+// default to the home directory's log files.
+func LoadConfig() (*config.Config, *runtime.HomeDir, error) {
+	homeDir, err := runtime.InitHomeDir(os.Getenv("RUNTIME_HOME_DIR"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize home directory: %w", err)
+	}
+
+	// Ensure log files exist
+	if err := homeDir.EnsureLogFiles(); err != nil {
+		return nil, nil, fmt.Errorf("failed to create log files: %w", err)
+	}
+
+	cfg, err := loadHomeConfig(homeDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	if homeDir.ConfigExists() {
+		log.Printf("Loaded configuration from %s", homeDir.ConfigPath)
+	} else {
+		log.Printf("Using environment variables for configuration (no config file found at %s)", homeDir.ConfigPath)
+	}
+
+	return cfg, homeDir, nil
+}
+
+// loadHomeConfig loads the configuration from homeDir's config file, if present, and
+// environment variables, defaulting the settings that depend on the home directory
+func loadHomeConfig(homeDir *runtime.HomeDir) (*config.Config, error) {
+	// Load configuration from YAML file (if exists) and environment variables
+	var cfg *config.Config
+	var err error
+	if homeDir.ConfigExists() {
+		cfg, err = config.LoadWithYAML(homeDir.ConfigPath)
+	} else {
+		cfg, err = config.Load()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Set home directory in config if not already set
+	if cfg.Runtime.HomeDir == "" {
+		cfg.Runtime.HomeDir = homeDir.Root
+	}
+
+	// Set log paths if not already set
+	if cfg.Logging.ServerLogPath == "" {
+		cfg.Logging.ServerLogPath = homeDir.ServerLog
+	}
+	if cfg.Logging.RequestLogPath == "" {
+		cfg.Logging.RequestLogPath = homeDir.RequestLog
+	}
+
+	// Enable file logging by default
+	if !cfg.Logging.EnableFile && !cfg.Logging.EnableConsole {
+		cfg.Logging.EnableFile = true
+		cfg.Logging.EnableConsole = true
+	}
+
+	return cfg, nil
+}
+
+// ConnectDatabase connects to cfg's database with its connection pool settings, logging slow
+// and failed queries to serverLogger. With a replica configured, reads marked with
+// database.ContextWithReadReplica are served by the replica.
+func ConnectDatabase(cfg *config.Config, serverLogger logger.Logger) (*gorm.DB, error) {
+	pool := database.PoolConfig{
+		MaxOpenConns:    cfg.Database.MaxOpenConns,
+		MaxIdleConns:    cfg.Database.MaxIdleConns,
+		ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+	}
+	db, err := database.ConnectWithPool(
+		cfg.Database.URL,
+		database.NewSlowQueryLogger(serverLogger, cfg.Database.SlowQueryThreshold),
+		pool,
+	)
+	if err != nil || cfg.Database.ReplicaURL == "" {
+		return db, err
+	}
+
+	replica, err := database.OpenReadReplica(cfg.Database.ReplicaURL, pool)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.UseReadReplica(db, replica); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
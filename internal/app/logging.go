@@ -0,0 +1,51 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+// Log names, which identify the server and request logs to the outputs they are shipped to
+const (
+	ServerLog  = "server"
+	RequestLog = "request"
+)
+
+// LogOutputs returns the outputs in cfg the log called name is shipped to
+func LogOutputs(cfg *config.Config, name string) []logger.Output {
+	var outputs []logger.Output
+	for _, o := range cfg.Logging.Outputs {
+		if len(o.Logs) > 0 && !slices.Contains(o.Logs, name) {
+			continue
+		}
+		outputs = append(outputs, logger.Output{
+			Type:    o.Type,
+			Address: o.Address,
+			URL:     o.URL,
+			Headers: o.Headers,
+			Tag:     o.Tag,
+		})
+	}
+	return outputs
+}
+
+// validateLogOutputs checks the log outputs of cfg
+func validateLogOutputs(cfg *config.Config) error {
+	var errs []error
+	for i, o := range cfg.Logging.Outputs {
+		output := logger.Output{Type: o.Type, Address: o.Address, URL: o.URL}
+		if err := output.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("logging.outputs[%d]: %w", i, err))
+		}
+		for _, name := range o.Logs {
+			if name != ServerLog && name != RequestLog {
+				errs = append(errs, fmt.Errorf("logging.outputs[%d].logs must list server or request, got %q", i, name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
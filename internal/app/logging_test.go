@@ -0,0 +1,43 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+func TestLogOutputs(t *testing.T) {
+	cfg := testConfig()
+	cfg.Logging.Outputs = []config.LogOutputConfig{
+		{Type: logger.OutputSyslog, Address: "udp://syslog:514"},
+		{Type: logger.OutputOTLP, URL: "http://collector:4318/v1/logs", Logs: []string{RequestLog}},
+	}
+
+	server := LogOutputs(cfg, ServerLog)
+	require.Len(t, server, 1)
+	assert.Equal(t, logger.OutputSyslog, server[0].Type)
+
+	request := LogOutputs(cfg, RequestLog)
+	require.Len(t, request, 2)
+	assert.Equal(t, "http://collector:4318/v1/logs", request[1].URL)
+}
+
+func TestValidateConfig_LogOutputs(t *testing.T) {
+	cfg := testConfig()
+	cfg.Logging.Outputs = []config.LogOutputConfig{
+		{Type: logger.OutputHTTP, URL: "http://logstash:8080"},
+		{Type: "kafka"},
+		{Type: logger.OutputOTLP, Logs: []string{"audit"}},
+	}
+
+	err := ValidateConfig(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "logging.outputs[1]")
+	assert.Contains(t, err.Error(), "logging.outputs[2]: otlp output needs an http or https URL")
+	assert.Contains(t, err.Error(), `logging.outputs[2].logs must list server or request, got "audit"`)
+	assert.NotContains(t, err.Error(), "logging.outputs[0]")
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/runtime"
+)
+
+// configReloadDelay is how long WatchConfig waits for writes to the config file to settle
+// before reloading it
+const configReloadDelay = 500 * time.Millisecond
+
+// reloadState is the configuration ReloadConfig last applied and the loggers whose level it
+// sets
+type reloadState struct {
+	mu      sync.Mutex
+	applied *config.Config
+	loggers []*logger.AppLogger
+}
+
+// ReloadConfig applies the log level, body logging routes, rate limits, maintenance mode,
+// feature flags and job schedules of cfg to the running process. Other settings take effect
+// after a restart, so changes to them are only logged. cfg is rejected as a whole if ValidateConfig finds a problem with it.
+func (c *Container) ReloadConfig(cfg *config.Config) error {
+	if err := ValidateConfig(cfg); err != nil {
+		return err
+	}
+
+	c.reload.mu.Lock()
+	defer c.reload.mu.Unlock()
+
+	previous := c.reload.applied
+	if previous == nil {
+		previous = c.Config
+	}
+
+	c.Logger.SetLevel(cfg.Logging.Level)
+	for _, l := range c.reload.loggers {
+		l.SetLevel(cfg.Logging.Level)
+	}
+	if c.authRateLimiter != nil {
+		c.authRateLimiter.SetLimit(cfg.Security.RateLimitRequests, cfg.Security.RateLimitDuration)
+	}
+	if c.apiRateLimiter != nil {
+		c.apiRateLimiter.SetLimit(cfg.Security.APIRateLimitRequests, cfg.Security.APIRateLimitDuration)
+	}
+	if c.bodyLogger != nil {
+		c.bodyLogger.SetRoutes(cfg.Logging.BodyRoutes, cfg.Logging.BodyMaxBytes)
+	}
+	if c.maintenance != nil {
+		c.maintenance.Configure(cfg.Server.MaintenanceMode, cfg.Server.MaintenanceRetryAfter)
+	}
+	if c.Services.FeatureFlag != nil {
+		c.Services.FeatureFlag.Configure(cfg.Features)
+	}
+	if c.scheduler != nil {
+		if err := c.scheduler.SetSchedules(cfg.Jobs.Schedules); err != nil {
+			return err
+		}
+	}
+	c.reload.applied = cfg
+
+	c.Logger.Info().
+		Str("log_level", cfg.Logging.Level).
+		Strs("body_log_routes", cfg.Logging.BodyRoutes).
+		Int("rate_limit_requests", cfg.Security.RateLimitRequests).
+		Int("api_rate_limit_requests", cfg.Security.APIRateLimitRequests).
+		Bool("maintenance_mode", cfg.Server.MaintenanceMode).
+		Interface("features", cfg.Features).
+		Interface("job_schedules", cfg.Jobs.Schedules).
+		Msg("Configuration reloaded")
+	if sections := restartRequiredSections(previous, cfg); len(sections) > 0 {
+		c.Logger.Warn().Strs("sections", sections).Msg("Configuration changes to these sections take effect after a restart")
+	}
+	return nil
+}
+
+// WatchConfig reloads the configuration of homeDir whenever its config file changes or the
+// process receives SIGHUP, until ctx is done. A configuration that fails to load or
+// validate is logged and leaves the running one in place.
+func (c *Container) WatchConfig(ctx context.Context, homeDir *runtime.HomeDir) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch configuration: %w", err)
+	}
+	// Watch the directory, since editors and deploy tools often replace the file rather than
+	// write to it
+	if err := watcher.Add(filepath.Dir(homeDir.ConfigPath)); err != nil {
+		_ = watcher.Close()
+		return fmt.Errorf("failed to watch configuration: %w", err)
+	}
+
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	go func() {
+		defer func() {
+			signal.Stop(hangup)
+			_ = watcher.Close()
+		}()
+
+		var settled <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				c.Logger.Info().Msg("SIGHUP received, reloading configuration")
+				c.reloadHomeConfig(homeDir)
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(event.Name) == filepath.Clean(homeDir.ConfigPath) &&
+					event.Has(fsnotify.Write|fsnotify.Create) {
+					settled = time.After(configReloadDelay)
+				}
+			case <-settled:
+				settled = nil
+				c.Logger.Info().Str("config_path", homeDir.ConfigPath).Msg("Configuration file changed, reloading")
+				c.reloadHomeConfig(homeDir)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				c.Logger.Warn().Err(err).Msg("Configuration watcher error")
+			}
+		}
+	}()
+	return nil
+}
+
+// reloadHomeConfig loads and applies the configuration of homeDir, logging failures
+func (c *Container) reloadHomeConfig(homeDir *runtime.HomeDir) {
+	cfg, err := loadHomeConfig(homeDir)
+	if err == nil {
+		err = c.ReloadConfig(cfg)
+	}
+	if err != nil {
+		c.Logger.Error().Err(err).Msg("Configuration not reloaded, keeping the running configuration")
+	}
+}
+
+// restartRequiredSections returns the YAML names of the sections that differ between
+// previous and next in settings ReloadConfig doesn't apply
+func restartRequiredSections(previous, next *config.Config) []string {
+	a, b := *previous, *next
+	for _, cfg := range []*config.Config{&a, &b} {
+		cfg.Logging.Level = ""
+		cfg.Logging.BodyRoutes = nil
+		cfg.Logging.BodyMaxBytes = 0
+		cfg.Security.RateLimitRequests = 0
+		cfg.Security.RateLimitDuration = 0
+		cfg.Security.APIRateLimitRequests = 0
+		cfg.Security.APIRateLimitDuration = 0
+		cfg.Jobs.Schedules = nil
+		cfg.Server.MaintenanceMode = false
+		cfg.Server.MaintenanceRetryAfter = 0
+		cfg.Features = nil
+	}
+
+	var sections []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			sections = append(sections, va.Type().Field(i).Tag.Get("yaml"))
+		}
+	}
+	return sections
+}
@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/jobs"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/runtime"
+)
+
+// loginStatuses returns the status codes of n login attempts
+func loginStatuses(engine *gin.Engine, n int) []int {
+	statuses := make([]int, n)
+	for i := range statuses {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/auth/login", nil))
+		statuses[i] = w.Code
+	}
+	return statuses
+}
+
+func TestValidateConfig(t *testing.T) {
+	assert.NoError(t, ValidateConfig(testConfig()))
+
+	cfg := testConfig()
+	cfg.Logging.Level = "verbose"
+	cfg.Password.MinLength = 4
+	cfg.Jobs.Schedules = map[string]string{"PriceUpdate": "sometimes"}
+	cfg.Push.VAPIDPrivateKey = "not-a-key"
+	cfg.Brokers.EncryptionKey = "too-short"
+	cfg.Features = map[string]bool{"graphql": true}
+
+	// Every problem is reported, not just the first
+	err := ValidateConfig(cfg)
+	assert.ErrorIs(t, err, models.ErrInvalidPasswordPolicy)
+	assert.ErrorIs(t, err, jobs.ErrInvalidSchedule)
+	assert.Contains(t, err.Error(), "logging.level")
+	assert.Contains(t, err.Error(), "invalid push configuration")
+	assert.Contains(t, err.Error(), "invalid brokers configuration")
+	assert.ErrorIs(t, err, models.ErrFeatureNotFound)
+}
+
+func TestPasswordHasher(t *testing.T) {
+	cfg := testConfig()
+	assert.Equal(t, "argon2id (m=65536, t=3, p=4)", PasswordHasher(cfg).String())
+
+	cfg.Password.HashAlgorithm = "bcrypt"
+	assert.Equal(t, "bcrypt (cost=12)", PasswordHasher(cfg).String())
+
+	// Out of range parameters stay invalid instead of wrapping around
+	cfg.Password.HashAlgorithm = "argon2id"
+	cfg.Password.Argon2Iterations = -1
+	assert.Error(t, PasswordHasher(cfg).Validate())
+}
+
+func TestContainer_ReloadConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	container := setupContainer(t, testConfig())
+	engine := container.BuildRouter(logger.GetLogger())
+	container.BuildJobs()
+
+	cfg := testConfig()
+	cfg.Security.RateLimitRequests = 1
+	cfg.Jobs.Schedules = map[string]string{"PeerBenchmark": "@every 6h"}
+	require.NoError(t, container.ReloadConfig(cfg))
+
+	assert.Equal(t, []int{http.StatusBadRequest, http.StatusTooManyRequests}, loginStatuses(engine, 2))
+
+	// An invalid configuration is rejected as a whole
+	invalid := testConfig()
+	invalid.Security.RateLimitRequests = 100
+	invalid.Logging.Level = "verbose"
+	assert.Error(t, container.ReloadConfig(invalid))
+	assert.Equal(t, []int{http.StatusTooManyRequests}, loginStatuses(engine, 1))
+}
+
+func TestContainer_ReloadConfig_MaintenanceMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	container := setupContainer(t, testConfig())
+	engine := container.BuildRouter(logger.GetLogger())
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	assert.Equal(t, http.StatusOK, get("/api/errors").Code)
+
+	cfg := testConfig()
+	cfg.Server.MaintenanceMode = true
+	cfg.Server.MaintenanceRetryAfter = 2 * time.Minute
+	require.NoError(t, container.ReloadConfig(cfg))
+
+	w := get("/api/errors")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "120", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"MAINTENANCE"`)
+	assert.Equal(t, http.StatusOK, get("/health").Code)
+
+	require.NoError(t, container.ReloadConfig(testConfig()))
+	assert.Equal(t, http.StatusOK, get("/api/errors").Code)
+}
+
+func TestContainer_ReloadConfig_Features(t *testing.T) {
+	container := setupContainer(t, testConfig())
+	features := container.Services.FeatureFlag
+	ctx := context.Background()
+	assert.True(t, features.IsEnabled(ctx, models.FeatureWhatIf, ""))
+
+	cfg := testConfig()
+	cfg.Features = map[string]bool{models.FeatureWhatIf: false}
+	require.NoError(t, container.ReloadConfig(cfg))
+	assert.False(t, features.IsEnabled(ctx, models.FeatureWhatIf, ""))
+
+	require.NoError(t, container.ReloadConfig(testConfig()))
+	assert.True(t, features.IsEnabled(ctx, models.FeatureWhatIf, ""))
+}
+
+func TestRestartRequiredSections(t *testing.T) {
+	previous := testConfig()
+	next := testConfig()
+	next.Logging.Level = "debug"
+	next.Security.APIRateLimitRequests = 10
+	next.Security.APIRateLimitDuration = time.Second
+	next.Jobs.Schedules = map[string]string{"Cleanup": "@weekly"}
+	next.Logging.BodyRoutes = []string{"/api/v1/imports/*"}
+	next.Logging.BodyMaxBytes = 512
+	next.Server.MaintenanceMode = true
+	next.Server.MaintenanceRetryAfter = time.Hour
+	next.Features = map[string]bool{models.FeatureSimulations: false}
+	assert.Empty(t, restartRequiredSections(previous, next))
+
+	next.Security.SessionCookies = true
+	next.Password.MinLength = 12
+	assert.Equal(t, []string{"security", "password"}, restartRequiredSections(previous, next))
+}
+
+func TestContainer_WatchConfig(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("DATABASE_URL", "sqlite://:memory:")
+	t.Setenv("JWT_SECRET", "test-secret-key-for-jwt-signing-32-chars")
+	homeDir, err := runtime.InitHomeDir(t.TempDir())
+	require.NoError(t, err)
+
+	container := setupContainer(t, testConfig())
+	engine := container.BuildRouter(logger.GetLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, container.WatchConfig(ctx, homeDir))
+
+	require.NoError(t, os.WriteFile(homeDir.ConfigPath, []byte(`
+security:
+  rate_limit_requests: 1
+  rate_limit_duration: 1m
+`), 0600))
+
+	assert.Eventually(t, func() bool {
+		container.reload.mu.Lock()
+		defer container.reload.mu.Unlock()
+		return container.reload.applied != nil
+	}, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, []int{http.StatusBadRequest, http.StatusTooManyRequests}, loginStatuses(engine, 2))
+
+	// The rate limit headers report the reloaded limit
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/auth/login", nil))
+	assert.Equal(t, "1", w.Header().Get(middleware.RateLimitLimitHeader))
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrCacheMiss is returned when a key is not present in the store
+var ErrCacheMiss = errors.New("cache miss")
+
+// Store defines a minimal key/value store with per-entry expiration.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the value stored under key or ErrCacheMiss if it is absent or expired
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores value under key for the given TTL (zero means no expiration)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes a single key
+	Delete(ctx context.Context, key string) error
+
+	// DeletePrefix removes all keys starting with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+
+	// Incr increments the counter stored under key and returns the new value.
+	// The window is applied as the key's expiration when the counter is created.
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+
+	// IncrBy adds n, which may be negative, to the counter stored under key and returns the
+	// new value. The window is applied as it is by Incr.
+	IncrBy(ctx context.Context, key string, n int64, window time.Duration) (int64, error)
+
+	// Close releases any resources held by the store
+	Close() error
+}
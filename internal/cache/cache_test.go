@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedisStore(t *testing.T) (Store, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	store, err := NewRedisStore("redis://" + server.Addr())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close() })
+	return store, server
+}
+
+func testStores(t *testing.T) map[string]Store {
+	redisStore, _ := newTestRedisStore(t)
+	return map[string]Store{
+		"memory": NewMemoryStore(),
+		"redis":  redisStore,
+	}
+}
+
+func TestStore_GetSet(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			_, err := store.Get(ctx, "missing")
+			assert.ErrorIs(t, err, ErrCacheMiss)
+
+			err = store.Set(ctx, "key", []byte("value"), time.Minute)
+			assert.NoError(t, err)
+
+			value, err := store.Get(ctx, "key")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("value"), value)
+
+			err = store.Delete(ctx, "key")
+			assert.NoError(t, err)
+
+			_, err = store.Get(ctx, "key")
+			assert.ErrorIs(t, err, ErrCacheMiss)
+		})
+	}
+}
+
+func TestStore_DeletePrefix(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			_ = store.Set(ctx, "quote:AAPL", []byte("1"), time.Minute)
+			_ = store.Set(ctx, "quote:MSFT", []byte("2"), time.Minute)
+			_ = store.Set(ctx, "other:AAPL", []byte("3"), time.Minute)
+
+			err := store.DeletePrefix(ctx, "quote:")
+			assert.NoError(t, err)
+
+			_, err = store.Get(ctx, "quote:AAPL")
+			assert.ErrorIs(t, err, ErrCacheMiss)
+			_, err = store.Get(ctx, "quote:MSFT")
+			assert.ErrorIs(t, err, ErrCacheMiss)
+
+			value, err := store.Get(ctx, "other:AAPL")
+			assert.NoError(t, err)
+			assert.Equal(t, []byte("3"), value)
+		})
+	}
+}
+
+func TestStore_Incr(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			for i := int64(1); i <= 3; i++ {
+				count, err := store.Incr(ctx, "counter", time.Minute)
+				assert.NoError(t, err)
+				assert.Equal(t, i, count)
+			}
+		})
+	}
+}
+
+func TestStore_IncrBy(t *testing.T) {
+	ctx := context.Background()
+
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			count, err := store.IncrBy(ctx, "counter", 5, time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(5), count)
+
+			count, err = store.IncrBy(ctx, "counter", -2, time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(3), count)
+
+			count, err = store.Incr(ctx, "counter", time.Minute)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(4), count)
+		})
+	}
+}
+
+func TestMemoryStore_Expiration(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	_ = store.Set(ctx, "key", []byte("value"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := store.Get(ctx, "key")
+	assert.ErrorIs(t, err, ErrCacheMiss)
+
+	count, _ := store.Incr(ctx, "counter", 10*time.Millisecond)
+	assert.Equal(t, int64(1), count)
+	time.Sleep(20 * time.Millisecond)
+
+	count, _ = store.Incr(ctx, "counter", 10*time.Millisecond)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMemoryStore_SweepsExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore().(*memoryStore)
+	store.sweepInterval = 10 * time.Millisecond
+
+	_ = store.Set(ctx, "expiring", []byte("value"), 10*time.Millisecond)
+	_, _ = store.Incr(ctx, "counter", 10*time.Millisecond)
+	_ = store.Set(ctx, "kept", []byte("value"), 0)
+	time.Sleep(20 * time.Millisecond)
+
+	// The next write removes the entries nobody read again
+	_ = store.Set(ctx, "new", []byte("value"), time.Minute)
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	assert.Len(t, store.entries, 2)
+	assert.Contains(t, store.entries, "kept")
+	assert.Contains(t, store.entries, "new")
+}
+
+func TestRedisStore_Expiration(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedisStore(t)
+
+	_, _ = store.Incr(ctx, "counter", time.Minute)
+	assert.Equal(t, time.Minute, server.TTL(keyPrefix+"counter"))
+	server.FastForward(2 * time.Minute)
+
+	count, err := store.Incr(ctx, "counter", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRedisStore_IncrExpiresCountersWithoutTTL(t *testing.T) {
+	ctx := context.Background()
+	store, server := newTestRedisStore(t)
+
+	// A counter left without an expiration gets one on its next increment
+	require.NoError(t, server.Set(keyPrefix+"counter", "5"))
+	count, err := store.Incr(ctx, "counter", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), count)
+	assert.Equal(t, time.Minute, server.TTL(keyPrefix+"counter"))
+
+	// Later increments keep the window the counter started with
+	server.FastForward(30 * time.Second)
+	_, err = store.Incr(ctx, "counter", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, server.TTL(keyPrefix+"counter"))
+}
+
+func TestNewRedisStore_InvalidURL(t *testing.T) {
+	store, err := NewRedisStore("not-a-url")
+	assert.Error(t, err)
+	assert.Nil(t, store)
+}
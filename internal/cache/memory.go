@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the least time between two sweeps of a memory store's expired entries
+const sweepInterval = time.Minute
+
+// memoryStore implements Store with an in-process map. Reads skip expired entries, and
+// writes sweep them out of the map every sweepInterval so that keys never read again, such
+// as rate limit counters of clients that went away, don't accumulate.
+type memoryStore struct {
+	mu            sync.RWMutex
+	entries       map[string]*memoryEntry
+	sweepInterval time.Duration
+	sweptAt       time.Time
+}
+
+// memoryEntry represents a stored value with optional expiration
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// expired reports whether the entry has passed its expiration time
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// NewMemoryStore creates a new in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{
+		entries:       make(map[string]*memoryEntry),
+		sweepInterval: sweepInterval,
+	}
+}
+
+// sweep removes the expired entries when the sweep interval has passed since the last sweep.
+// The caller must hold the write lock.
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < s.sweepInterval {
+		return
+	}
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+	s.sweptAt = now
+}
+
+// Get returns the value stored under key
+func (s *memoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, exists := s.entries[key]
+	if !exists || entry.expired(time.Now()) {
+		return nil, ErrCacheMiss
+	}
+
+	return entry.value, nil
+}
+
+// Set stores value under key for the given TTL
+func (s *memoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	entry := &memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	s.entries[key] = entry
+
+	return nil
+}
+
+// Delete removes a single key
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// DeletePrefix removes all keys starting with prefix
+func (s *memoryStore) DeletePrefix(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+
+	return nil
+}
+
+// Incr increments the counter stored under key
+func (s *memoryStore) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return s.IncrBy(ctx, key, 1, window)
+}
+
+// IncrBy adds n to the counter stored under key
+func (s *memoryStore) IncrBy(ctx context.Context, key string, n int64, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	entry, exists := s.entries[key]
+	if !exists || entry.expired(now) {
+		entry = &memoryEntry{value: []byte("0")}
+		if window > 0 {
+			entry.expiresAt = now.Add(window)
+		}
+		s.entries[key] = entry
+	}
+
+	count, err := strconv.ParseInt(string(entry.value), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	count += n
+	entry.value = []byte(strconv.FormatInt(count, 10))
+
+	return count, nil
+}
+
+// Close is a no-op for the in-memory store
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix namespaces all keys written by the application
+const keyPrefix = "portfolios:"
+
+// redisStore implements Store on top of a Redis server
+type redisStore struct {
+	client *redis.Client
+}
+
+// NewRedisStore connects to the Redis server at redisURL and returns a Store.
+// The URL uses the redis:// or rediss:// scheme understood by go-redis.
+func NewRedisStore(redisURL string) (Store, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return &redisStore{client: client}, nil
+}
+
+// Get returns the value stored under key
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, keyPrefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrCacheMiss
+		}
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	return value, nil
+}
+
+// Set stores value under key for the given TTL
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.client.Set(ctx, keyPrefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set key: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a single key
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, keyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	}
+	return nil
+}
+
+// DeletePrefix removes all keys starting with prefix using SCAN to avoid blocking the server
+func (s *redisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	iter := s.client.Scan(ctx, 0, keyPrefix+prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := s.client.Del(ctx, iter.Val()).Err(); err != nil {
+			return fmt.Errorf("failed to delete key: %w", err)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to scan keys: %w", err)
+	}
+	return nil
+}
+
+// incrScript adds ARGV[2] to the counter under KEYS[1] and, when it has no expiration,
+// expires it in ARGV[1] milliseconds, so that no counter outlives its window even if a client
+// dies between the two commands
+var incrScript = redis.NewScript(`
+local count = redis.call("INCRBY", KEYS[1], ARGV[2])
+local window = tonumber(ARGV[1])
+if window > 0 and redis.call("PTTL", KEYS[1]) == -1 then
+	redis.call("PEXPIRE", KEYS[1], window)
+end
+return count
+`)
+
+// Incr increments the counter stored under key, setting its expiration on creation
+func (s *redisStore) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return s.IncrBy(ctx, key, 1, window)
+}
+
+// IncrBy adds n to the counter stored under key, setting its expiration on creation. Both
+// happen atomically in a script.
+func (s *redisStore) IncrBy(ctx context.Context, key string, n int64, window time.Duration) (int64, error) {
+	count, err := incrScript.Run(ctx, s.client, []string{keyPrefix + key}, window.Milliseconds(), n).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment key: %w", err)
+	}
+	return count, nil
+}
+
+// Close closes the underlying Redis connection
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,179 @@
+// Package calendar knows which days exchanges trade. Valuations, snapshots and benchmark
+// comparisons use it to tell a quiet day from one without a session.
+package calendar
+
+import (
+	"time"
+	// Sessions close in the exchange's own time zone, which slim images don't ship
+	_ "time/tzdata"
+)
+
+// Calendar is an exchange's trading calendar: the weekdays it is closed for holidays and the
+// time its sessions close
+type Calendar struct {
+	name     string
+	location *time.Location
+	closeAt  time.Duration
+	holidays func(year int) map[time.Time]string
+}
+
+// NYSE is the New York Stock Exchange's calendar, which Nasdaq shares. Unscheduled closures,
+// such as national days of mourning, aren't known in advance and aren't included.
+var NYSE = &Calendar{
+	name:     "NYSE",
+	location: mustLoadLocation("America/New_York"),
+	closeAt:  16 * time.Hour,
+	holidays: nyseHolidays,
+}
+
+// mustLoadLocation loads a time zone from the embedded database
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return location
+}
+
+// Name returns the exchange's name
+func (c *Calendar) Name() string {
+	return c.name
+}
+
+// day returns date's calendar day, ignoring its time and time zone
+func day(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Holiday returns the name of the holiday the exchange is closed for on date's day, if any
+func (c *Calendar) Holiday(date time.Time) (string, bool) {
+	d := day(date)
+	name, ok := c.holidays(d.Year())[d]
+	return name, ok
+}
+
+// IsTradingDay returns true if the exchange has a session on date's day
+func (c *Calendar) IsTradingDay(date time.Time) bool {
+	d := day(date)
+	if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		return false
+	}
+	_, holiday := c.Holiday(d)
+	return !holiday
+}
+
+// TradingDayOnOrBefore returns the last trading day on or before date's day, as a UTC
+// midnight
+func (c *Calendar) TradingDayOnOrBefore(date time.Time) time.Time {
+	d := day(date)
+	for !c.IsTradingDay(d) {
+		d = d.AddDate(0, 0, -1)
+	}
+	return d
+}
+
+// TradingDaysBetween counts the trading days after start's day up to and including end's
+func (c *Calendar) TradingDaysBetween(start, end time.Time) int {
+	count := 0
+	last := day(end)
+	for d := day(start).AddDate(0, 0, 1); !d.After(last); d = d.AddDate(0, 0, 1) {
+		if c.IsTradingDay(d) {
+			count++
+		}
+	}
+	return count
+}
+
+// SessionClose returns when the session of a trading day closes
+func (c *Calendar) SessionClose(tradingDay time.Time) time.Time {
+	d := day(tradingDay)
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, c.location).Add(c.closeAt)
+}
+
+// LastClosedSession returns the latest trading day whose session had closed by now, as a UTC
+// midnight
+func (c *Calendar) LastClosedSession(now time.Time) time.Time {
+	session := c.TradingDayOnOrBefore(now.In(c.location))
+	if c.SessionClose(session).After(now) {
+		session = c.TradingDayOnOrBefore(session.AddDate(0, 0, -1))
+	}
+	return session
+}
+
+// nyseHolidays returns the NYSE's full-day holidays of a year, keyed by UTC midnight. A
+// holiday on a Sunday is observed the following Monday and one on a Saturday the previous
+// Friday, except New Year's Day, which then isn't observed at all.
+func nyseHolidays(year int) map[time.Time]string {
+	holidays := map[time.Time]string{}
+	add := func(date time.Time, name string) {
+		holidays[date] = name
+	}
+	observed := func(date time.Time, name string) {
+		switch date.Weekday() {
+		case time.Saturday:
+			add(date.AddDate(0, 0, -1), name)
+		case time.Sunday:
+			add(date.AddDate(0, 0, 1), name)
+		default:
+			add(date, name)
+		}
+	}
+
+	if newYear := date(year, time.January, 1); newYear.Weekday() != time.Saturday {
+		observed(newYear, "New Year's Day")
+	}
+	if year >= 1998 {
+		add(nthWeekday(year, time.January, time.Monday, 3), "Martin Luther King Jr. Day")
+	}
+	add(nthWeekday(year, time.February, time.Monday, 3), "Washington's Birthday")
+	add(easter(year).AddDate(0, 0, -2), "Good Friday")
+	add(lastWeekday(year, time.May, time.Monday), "Memorial Day")
+	if year >= 2022 {
+		observed(date(year, time.June, 19), "Juneteenth")
+	}
+	observed(date(year, time.July, 4), "Independence Day")
+	add(nthWeekday(year, time.September, time.Monday, 1), "Labor Day")
+	add(nthWeekday(year, time.November, time.Thursday, 4), "Thanksgiving Day")
+	observed(date(year, time.December, 25), "Christmas Day")
+
+	return holidays
+}
+
+// date returns a UTC midnight
+func date(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+// nthWeekday returns the nth given weekday of a month
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := date(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+// lastWeekday returns the last given weekday of a month
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := date(year, month+1, 0)
+	offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+// easter returns Easter Sunday of a year in the Gregorian calendar (anonymous Gregorian
+// algorithm)
+func easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	dayOfMonth := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), dayOfMonth)
+}
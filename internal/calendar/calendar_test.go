@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNYSE_Holiday(t *testing.T) {
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{date(2024, time.January, 1), "New Year's Day"},
+		{date(2024, time.January, 15), "Martin Luther King Jr. Day"},
+		{date(2024, time.February, 19), "Washington's Birthday"},
+		{date(2024, time.March, 29), "Good Friday"},
+		{date(2024, time.May, 27), "Memorial Day"},
+		{date(2024, time.June, 19), "Juneteenth"},
+		{date(2024, time.July, 4), "Independence Day"},
+		{date(2024, time.September, 2), "Labor Day"},
+		{date(2024, time.November, 28), "Thanksgiving Day"},
+		{date(2024, time.December, 25), "Christmas Day"},
+		// Saturday holidays are observed the Friday before, Sunday ones the Monday after
+		{date(2021, time.July, 5), "Independence Day"},
+		{date(2022, time.December, 26), "Christmas Day"},
+		{date(2026, time.July, 3), "Independence Day"},
+		{date(2025, time.April, 18), "Good Friday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date.Format("2006-01-02"), func(t *testing.T) {
+			name, ok := NYSE.Holiday(tt.date)
+			assert.True(t, ok)
+			assert.Equal(t, tt.want, name)
+			assert.False(t, NYSE.IsTradingDay(tt.date))
+		})
+	}
+}
+
+func TestNYSE_IsTradingDay(t *testing.T) {
+	// A Saturday New Year's Day isn't observed on the Friday before
+	assert.True(t, NYSE.IsTradingDay(date(2021, time.December, 31)))
+	assert.False(t, NYSE.IsTradingDay(date(2022, time.January, 1)))
+	// Juneteenth has been a holiday since 2022
+	assert.True(t, NYSE.IsTradingDay(date(2021, time.June, 18)))
+	assert.True(t, NYSE.IsTradingDay(date(2024, time.June, 14)))
+	assert.False(t, NYSE.IsTradingDay(date(2024, time.June, 15)))
+	assert.False(t, NYSE.IsTradingDay(date(2024, time.June, 16)))
+	// Only the day counts, not the time zone
+	assert.True(t, NYSE.IsTradingDay(time.Date(2024, time.June, 14, 23, 0, 0, 0, time.FixedZone("UTC-10", -10*3600))))
+}
+
+func TestNYSE_TradingDayOnOrBefore(t *testing.T) {
+	assert.Equal(t, date(2024, time.June, 14), NYSE.TradingDayOnOrBefore(date(2024, time.June, 16)))
+	assert.Equal(t, date(2024, time.June, 14), NYSE.TradingDayOnOrBefore(time.Date(2024, time.June, 14, 12, 0, 0, 0, time.UTC)))
+	// Good Friday follows a weekend back to Thursday
+	assert.Equal(t, date(2024, time.March, 28), NYSE.TradingDayOnOrBefore(date(2024, time.March, 31)))
+}
+
+func TestNYSE_TradingDaysBetween(t *testing.T) {
+	// The week of July 4th, 2024
+	assert.Equal(t, 4, NYSE.TradingDaysBetween(date(2024, time.June, 30), date(2024, time.July, 6)))
+	assert.Equal(t, 0, NYSE.TradingDaysBetween(date(2024, time.June, 15), date(2024, time.June, 16)))
+	assert.Equal(t, 252, NYSE.TradingDaysBetween(date(2023, time.December, 31), date(2024, time.December, 31)))
+	assert.Equal(t, 0, NYSE.TradingDaysBetween(date(2024, time.June, 14), date(2024, time.June, 10)))
+}
+
+func TestNYSE_LastClosedSession(t *testing.T) {
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"before the close", time.Date(2024, time.June, 14, 19, 59, 0, 0, time.UTC), date(2024, time.June, 13)},
+		{"after the close", time.Date(2024, time.June, 14, 20, 0, 0, 0, time.UTC), date(2024, time.June, 14)},
+		{"weekend", time.Date(2024, time.June, 16, 12, 0, 0, 0, time.UTC), date(2024, time.June, 14)},
+		{"UTC day already ahead", time.Date(2024, time.June, 18, 2, 0, 0, 0, time.UTC), date(2024, time.June, 17)},
+		{"holiday", time.Date(2024, time.June, 19, 22, 0, 0, 0, time.UTC), date(2024, time.June, 18)},
+		{"winter close", time.Date(2024, time.December, 2, 21, 0, 0, 0, time.UTC), date(2024, time.December, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NYSE.LastClosedSession(tt.now))
+		})
+	}
+}
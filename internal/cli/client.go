@@ -1,154 +0,0 @@
-package cli
-
-import (
-	"bytes"
-	"encoding/json"
-	"fmt"
-	"io"
-	"mime/multipart"
-	"net/http"
-	"time"
-)
-
-// Client is the API client for the portfolios backend
-type Client struct {
-	BaseURL      string
-	HTTPClient   *http.Client
-	AccessToken  string
-	RefreshToken string
-}
-
-// NewClient creates a new API client
-func NewClient(baseURL, accessToken, refreshToken string) *Client {
-	return &Client{
-		BaseURL: baseURL,
-		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	}
-}
-
-// NewClientFromConfig creates a client from config
-func NewClientFromConfig(config *Config) *Client {
-	return NewClient(config.APIBaseURL, config.AccessToken, config.RefreshToken)
-}
-
-// Request makes an authenticated HTTP request
-func (c *Client) Request(method, path string, body interface{}, result interface{}) error {
-	var bodyReader io.Reader
-	if body != nil {
-		jsonBody, err := json.Marshal(body)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-		bodyReader = bytes.NewReader(jsonBody)
-	}
-
-	req, err := http.NewRequest(method, c.BaseURL+path, bodyReader)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-
-	if c.AccessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
-	}
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	// Handle authentication errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("authentication failed: please login again using 'portfolios auth login'")
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	if result != nil && resp.StatusCode != http.StatusNoContent {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// UploadFile uploads a file via multipart form
-func (c *Client) UploadFile(path, fieldName, filename string, fileData []byte, additionalFields map[string]string, result interface{}) error {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// Add file
-	part, err := writer.CreateFormFile(fieldName, filename)
-	if err != nil {
-		return fmt.Errorf("failed to create form file: %w", err)
-	}
-	if _, err := part.Write(fileData); err != nil {
-		return fmt.Errorf("failed to write file data: %w", err)
-	}
-
-	// Add additional fields
-	for key, val := range additionalFields {
-		if err := writer.WriteField(key, val); err != nil {
-			return fmt.Errorf("failed to write field %s: %w", key, err)
-		}
-	}
-
-	if err := writer.Close(); err != nil {
-		return fmt.Errorf("failed to close multipart writer: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", c.BaseURL+path, body)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", writer.FormDataContentType())
-	if c.AccessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
-	}
-
-	resp, err := c.HTTPClient.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
-	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("authentication failed: please login again")
-	}
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	if result != nil {
-		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
-		}
-	}
-
-	return nil
-}
-
-// UpdateTokens updates the client's tokens
-func (c *Client) UpdateTokens(accessToken, refreshToken string) {
-	c.AccessToken = accessToken
-	c.RefreshToken = refreshToken
-}
@@ -8,32 +8,55 @@ import (
 	"github.com/spf13/viper"
 )
 
+// APIKeyEnvVar names the environment variable that supplies an API key without storing it
+// in the config file
+const APIKeyEnvVar = "PORTFOLIOS_API_KEY"
+
 // Config holds the CLI configuration
 type Config struct {
 	APIBaseURL   string `mapstructure:"api_base_url"`
 	AccessToken  string `mapstructure:"access_token"`
 	RefreshToken string `mapstructure:"refresh_token"`
+	APIKey       string `mapstructure:"api_key"`       // used instead of the tokens when set
 	OutputFormat string `mapstructure:"output_format"` // table, json, csv
+
+	// LocalDatabase is the SQLite database used in local mode
+	LocalDatabase string `mapstructure:"local_database"`
+	// MarketDataAPIKey enables quotes and performance analytics in local mode
+	MarketDataAPIKey string `mapstructure:"market_data_api_key"`
+	// Local serves commands from LocalDatabase instead of the API server. It is set per
+	// command and never saved.
+	Local bool `mapstructure:"-"`
+
+	// apiKeyFromEnv is set when APIKey came from APIKeyEnvVar, so SaveConfig doesn't persist it
+	apiKeyFromEnv bool
+}
+
+// configPath overrides the default config file location when set
+var configPath string
+
+// SetConfigPath makes LoadConfig and SaveConfig use path instead of the default config
+// file. An empty path restores the default.
+func SetConfigPath(path string) {
+	configPath = path
 }
 
 // LoadConfig loads configuration from file
 func LoadConfig() (*Config, error) {
-	home, err := os.UserHomeDir()
+	configFile, err := GetConfigPath()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	configDir := filepath.Join(home, ".portfolios")
-	configFile := filepath.Join(configDir, "config.yaml")
-
 	// Create config directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0750); err != nil {
+	if err := os.MkdirAll(filepath.Dir(configFile), 0750); err != nil {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
 
 	// Set defaults
 	viper.SetDefault("api_base_url", "http://localhost:8080")
 	viper.SetDefault("output_format", "table")
+	viper.SetDefault("local_database", filepath.Join(filepath.Dir(configFile), "portfolios.db"))
 
 	viper.SetConfigFile(configFile)
 	viper.SetConfigType("yaml")
@@ -54,15 +77,26 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if key := os.Getenv(APIKeyEnvVar); key != "" {
+		config.APIKey = key
+		config.apiKeyFromEnv = true
+	}
+
 	return &config, nil
 }
 
-// SaveConfig saves configuration to file
+// SaveConfig saves configuration to file. An API key taken from the environment is not
+// written.
 func SaveConfig(config *Config) error {
 	viper.Set("api_base_url", config.APIBaseURL)
 	viper.Set("access_token", config.AccessToken)
 	viper.Set("refresh_token", config.RefreshToken)
 	viper.Set("output_format", config.OutputFormat)
+	viper.Set("local_database", config.LocalDatabase)
+	viper.Set("market_data_api_key", config.MarketDataAPIKey)
+	if !config.apiKeyFromEnv {
+		viper.Set("api_key", config.APIKey)
+	}
 
 	if err := viper.WriteConfig(); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
@@ -71,21 +105,34 @@ func SaveConfig(config *Config) error {
 	return nil
 }
 
-// ClearTokens clears authentication tokens from config
-func ClearTokens() error {
-	config, err := LoadConfig()
-	if err != nil {
+// SaveTokens stores authentication tokens in the config file, leaving the other settings
+// as they are on disk
+func SaveTokens(accessToken, refreshToken string) error {
+	if _, err := LoadConfig(); err != nil {
 		return err
 	}
 
-	config.AccessToken = ""
-	config.RefreshToken = ""
+	viper.Set("access_token", accessToken)
+	viper.Set("refresh_token", refreshToken)
+
+	if err := viper.WriteConfig(); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	return nil
+}
 
-	return SaveConfig(config)
+// ClearTokens clears authentication tokens from config
+func ClearTokens() error {
+	return SaveTokens("", "")
 }
 
 // GetConfigPath returns the path to the config file
 func GetConfigPath() (string, error) {
+	if configPath != "" {
+		return configPath, nil
+	}
+
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
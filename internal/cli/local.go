@@ -0,0 +1,252 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/app"
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/jobs"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+// MarketDataAPIKeyEnvVar names the environment variable holding the market data provider
+// key used in local mode, the same one the API server reads
+const MarketDataAPIKeyEnvVar = "MARKET_DATA_API_KEY"
+
+// localUserEmail identifies the user that owns everything in a local database
+const localUserEmail = "local@localhost"
+
+// localSessionDuration bounds a single CLI command in local mode
+const localSessionDuration = 24 * time.Hour
+
+// localJobPollInterval is how often the local client checks on queued jobs, which cost
+// nothing to check on without a network
+const localJobPollInterval = 20 * time.Millisecond
+
+// LocalBackend serves the API in the CLI's own process from a SQLite database, so the CLI
+// can be used without running a server. Requests skip the network and are authenticated as
+// the database's local user, who never logs in.
+type LocalBackend struct {
+	db         *gorm.DB
+	container  *app.Container
+	handler    http.Handler
+	workerPool *jobs.WorkerPool
+	userID     uuid.UUID
+	token      string
+}
+
+// OpenLocal opens the SQLite database at path, creating and migrating it as needed.
+// Performance analytics and quotes are available when marketDataAPIKey is set.
+func OpenLocal(ctx context.Context, path, marketDataAPIKey string) (*LocalBackend, error) {
+	return openLocal(ctx, path, marketDataAPIKey)
+}
+
+// OpenLocalWithProvider is like OpenLocal but quotes from provider instead of the market
+// data provider, such as the synthetic market of a load test
+func OpenLocalWithProvider(ctx context.Context, path string, provider services.MarketDataProvider) (*LocalBackend, error) {
+	return openLocal(ctx, path, "", app.WithMarketDataProvider(provider))
+}
+
+func openLocal(ctx context.Context, path, marketDataAPIKey string, opts ...app.Option) (*LocalBackend, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	databaseURL := "sqlite://" + path
+	db, err := database.ConnectWithLogger(databaseURL, gormlogger.Default.LogMode(gormlogger.Silent))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open local database: %w", err)
+	}
+
+	backend, err := newLocalBackend(ctx, db, databaseURL, marketDataAPIKey, opts...)
+	if err != nil {
+		if sqlDB, dbErr := db.DB(); dbErr == nil {
+			_ = sqlDB.Close()
+		}
+		return nil, err
+	}
+	return backend, nil
+}
+
+func newLocalBackend(ctx context.Context, db *gorm.DB, databaseURL, marketDataAPIKey string, opts ...app.Option) (*LocalBackend, error) {
+	// Tokens only have to be valid inside this process
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &config.Config{
+		// Imports and reports are queued; one worker runs them while the command waits
+		Server:   config.ServerConfig{JobWorkers: 1},
+		Database: config.DatabaseConfig{URL: databaseURL},
+		JWT: config.JWTConfig{
+			Secret:              secret,
+			AccessTokenDuration: localSessionDuration,
+		},
+		// There is a single caller, so nothing to rate limit
+		Security: config.SecurityConfig{
+			RateLimitRequests: math.MaxInt32,
+			RateLimitDuration: time.Minute,
+		},
+		MarketData: config.MarketDataConfig{
+			Provider:           "alphavantage",
+			APIKey:             marketDataAPIKey,
+			DailyRequestLimit:  25,
+			MinuteRequestLimit: 5,
+			QuoteConcurrency:   4,
+			BreakerFailures:    5,
+			BreakerCooldown:    30 * time.Second,
+		},
+		Snapshots: config.SnapshotConfig{
+			DailyRetentionMonths:  24,
+			WeeklyRetentionMonths: 60,
+		},
+		Rounding: config.RoundingConfig{
+			Mode:                 "HALF_UP",
+			EquityQuantityPlaces: 8,
+			CryptoQuantityPlaces: 8,
+			AmountPlaces:         8,
+		},
+		Password: config.PasswordConfig{
+			MinLength:         8,
+			RequireUppercase:  true,
+			RequireLowercase:  true,
+			RequireNumber:     true,
+			HashAlgorithm:     "argon2id",
+			Argon2Memory:      64 * 1024,
+			Argon2Iterations:  3,
+			Argon2Parallelism: 4,
+			BcryptCost:        12,
+		},
+	}
+
+	// Only failures are worth showing on a terminal
+	log := logger.NewLogger(logger.Config{Level: "error", Format: "console", OutputPath: "stderr"})
+
+	container, err := app.BuildServices(cfg, db, append([]app.Option{app.WithoutAdmin(), app.WithLogger(log)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := localUser(ctx, db)
+	if err != nil {
+		_ = container.Close()
+		return nil, err
+	}
+	token, err := container.Services.Token.GenerateAccessToken(user.ID.String(), localSessionDuration)
+	if err != nil {
+		_ = container.Close()
+		return nil, err
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	workerPool := container.BuildWorkerPool()
+	workerPool.Start()
+	return &LocalBackend{
+		db:         db,
+		container:  container,
+		handler:    container.BuildRouter(log),
+		workerPool: workerPool,
+		userID:     user.ID,
+		token:      token,
+	}, nil
+}
+
+// localUser returns the user that owns the local database's data, creating it on first use
+func localUser(ctx context.Context, db *gorm.DB) (*models.User, error) {
+	var user models.User
+	err := db.WithContext(ctx).Where("email = ?", localUserEmail).First(&user).Error
+	if err == nil {
+		return &user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to find local user: %w", err)
+	}
+
+	// Nobody logs in as the local user, so its password is random and discarded
+	password, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	user = models.User{Email: localUserEmail}
+	if err := user.SetPassword(password); err != nil {
+		return nil, fmt.Errorf("failed to create local user: %w", err)
+	}
+	if err := db.WithContext(ctx).Create(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to create local user: %w", err)
+	}
+	return &user, nil
+}
+
+// Client returns an API client whose requests the backend serves in-process
+func (b *LocalBackend) Client() *client.Client {
+	return client.New("http://local",
+		client.WithAccessToken(b.token),
+		client.WithHTTPClient(&http.Client{Transport: handlerTransport{b.handler}}),
+		client.WithJobPollInterval(localJobPollInterval),
+	)
+}
+
+// DB returns the local database, for writing to it directly, as seeding does
+func (b *LocalBackend) DB() *gorm.DB {
+	return b.db
+}
+
+// UserID returns the ID of the local user, who owns everything in the local database
+func (b *LocalBackend) UserID() uuid.UUID {
+	return b.userID
+}
+
+// Close stops the backend's workers, cancelling jobs that are still running, and releases
+// its cache and database connection
+func (b *LocalBackend) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = b.workerPool.Stop(ctx)
+	_ = b.container.Close()
+	sqlDB, err := b.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// handlerTransport is an http.RoundTripper that serves requests with an http.Handler
+type handlerTransport struct {
+	handler http.Handler
+}
+
+// RoundTrip implements http.RoundTripper
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorder := httptest.NewRecorder()
+	t.handler.ServeHTTP(recorder, req)
+	return recorder.Result(), nil
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+func TestOpenLocal_PersistsAcrossSessions(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "data", "portfolios.db")
+
+	backend, err := OpenLocal(ctx, path, "")
+	require.NoError(t, err)
+
+	created, err := backend.Client().CreatePortfolio(ctx, client.CreatePortfolioRequest{
+		Name:            "Personal",
+		BaseCurrency:    "USD",
+		CostBasisMethod: client.CostBasisFIFO,
+	})
+	require.NoError(t, err)
+	require.NoError(t, backend.Close())
+
+	// A later command sees the same local user and data
+	backend, err = OpenLocal(ctx, path, "")
+	require.NoError(t, err)
+	defer backend.Close()
+
+	portfolios, err := backend.Client().ListPortfolios(ctx)
+	require.NoError(t, err)
+	require.Len(t, portfolios.Portfolios, 1)
+	assert.Equal(t, created.ID, portfolios.Portfolios[0].ID)
+	assert.Equal(t, "Personal", portfolios.Portfolios[0].Name)
+}
+
+func TestCall_LocalMode(t *testing.T) {
+	config := &Config{Local: true, LocalDatabase: filepath.Join(t.TempDir(), "portfolios.db")}
+
+	var user *client.UserResponse
+	err := Call(context.Background(), config, func(c *client.Client) (err error) {
+		user, err = c.GetCurrentUser(context.Background())
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, localUserEmail, user.Email)
+}
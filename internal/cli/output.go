@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bufio"
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
@@ -137,8 +138,8 @@ func Confirm(message string) bool {
 
 	fmt.Print(style.Render(message + " (y/N): "))
 
-	var response string
-	if _, err := fmt.Scanln(&response); err != nil {
+	response, err := readLine()
+	if err != nil {
 		// If there's an error (e.g., EOF), treat as "no"
 		return false
 	}
@@ -154,10 +155,22 @@ func ReadInput(prompt string) (string, error) {
 
 	fmt.Print(style.Render(prompt + ": "))
 
-	var input string
-	if _, err := fmt.Scanln(&input); err != nil && err != io.EOF {
+	input, err := readLine()
+	if err != nil && err != io.EOF {
 		return "", err
 	}
 
 	return strings.TrimSpace(input), nil
 }
+
+// stdin is shared by the prompts so buffered input isn't lost between them
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a whole line from stdin, spaces included, without its line ending
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
@@ -9,10 +9,10 @@ import (
 
 // Portfolio represents a portfolio in the selector
 type Portfolio struct {
-	ID          uint
+	ID          string
 	Name        string
 	Description string
-	TotalValue  float64
+	Currency    string
 }
 
 // PortfolioSelector is a Bubble Tea model for selecting portfolios
@@ -89,12 +89,14 @@ func (m *PortfolioSelector) View() string {
 			cursor = "❯"
 		}
 
-		line := fmt.Sprintf("%s [%d] %s - $%.2f",
+		line := fmt.Sprintf("%s %s (%s)",
 			cursor,
-			portfolio.ID,
 			portfolio.Name,
-			portfolio.TotalValue,
+			portfolio.Currency,
 		)
+		if portfolio.Description != "" {
+			line += " - " + portfolio.Description
+		}
 
 		if m.cursor == i {
 			s += selectedStyle.Render(line) + "\n"
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+// NewAPIClient creates an API client for the configured server. It authenticates with the
+// configured API key, or with the stored access token when there is no key.
+func NewAPIClient(config *Config) *client.Client {
+	if config.APIKey != "" {
+		return client.New(config.APIBaseURL, client.WithAPIKey(config.APIKey))
+	}
+	return client.New(config.APIBaseURL, client.WithAccessToken(config.AccessToken))
+}
+
+// Call runs fn with an API client for config. When a stored access token has expired, it
+// refreshes the token once with the stored refresh token, saves it, and runs fn again, so
+// scripts keep working across token lifetimes. In local mode fn's requests are served from
+// the local database instead.
+func Call(ctx context.Context, config *Config, fn func(*client.Client) error) error {
+	if config.Local {
+		return callLocal(ctx, config, fn)
+	}
+
+	c := NewAPIClient(config)
+
+	err := fn(c)
+	if !isUnauthorized(err) || config.APIKey != "" || config.RefreshToken == "" {
+		return explainAuthError(err)
+	}
+
+	refreshed, refreshErr := c.RefreshToken(ctx, config.RefreshToken)
+	if refreshErr != nil {
+		return explainAuthError(err)
+	}
+	config.AccessToken = refreshed.AccessToken
+	if err := SaveTokens(config.AccessToken, config.RefreshToken); err != nil {
+		return err
+	}
+
+	return explainAuthError(fn(c))
+}
+
+// callLocal runs fn with a client served by the local database
+func callLocal(ctx context.Context, config *Config, fn func(*client.Client) error) error {
+	marketDataAPIKey := config.MarketDataAPIKey
+	if marketDataAPIKey == "" {
+		marketDataAPIKey = os.Getenv(MarketDataAPIKeyEnvVar)
+	}
+
+	backend, err := OpenLocal(ctx, config.LocalDatabase, marketDataAPIKey)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = backend.Close()
+	}()
+
+	err = fn(backend.Client())
+	if marketDataAPIKey == "" && isUnroutedRequest(err) {
+		// Quotes and performance analytics are only routed when market data is configured
+		return fmt.Errorf("%w (market data is required; set %s or 'portfolios config set market_data_api_key')",
+			err, MarketDataAPIKeyEnvVar)
+	}
+	return err
+}
+
+// isUnroutedRequest returns true if err is the API's 404 for a path with no route, as opposed
+// to a missing resource, which carries an error code
+func isUnroutedRequest(err error) bool {
+	var apiErr *client.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound && apiErr.Code == ""
+}
+
+// isUnauthorized returns true if err is an API error with status 401
+func isUnauthorized(err error) bool {
+	var apiErr *client.APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized
+}
+
+// explainAuthError tells the user how to authenticate when err is a 401
+func explainAuthError(err error) error {
+	if isUnauthorized(err) {
+		return fmt.Errorf("%w (login with 'portfolios auth login' or set %s)", err, APIKeyEnvVar)
+	}
+	return err
+}
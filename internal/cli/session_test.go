@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+// newTestServer serves /api/auth/me, which only accepts the token "fresh" or the API key
+// "pfk_test", and /api/auth/refresh, which hands out "fresh"
+func newTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/auth/refresh":
+			_ = json.NewEncoder(w).Encode(client.RefreshResponse{AccessToken: "fresh", ExpiresIn: 900})
+		case "/api/auth/me":
+			if r.Header.Get("Authorization") != "Bearer fresh" && r.Header.Get("X-API-Key") != "pfk_test" {
+				w.WriteHeader(http.StatusUnauthorized)
+				_ = json.NewEncoder(w).Encode(client.ErrorResponse{Error: "invalid token", Code: "UNAUTHORIZED"})
+				return
+			}
+			_ = json.NewEncoder(w).Encode(client.UserResponse{Email: "test@example.com"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// useTempConfig points the config at a file in a temporary directory
+func useTempConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	SetConfigPath(path)
+	t.Cleanup(func() { SetConfigPath("") })
+	return path
+}
+
+func getCurrentUser(ctx context.Context, config *Config) (*client.UserResponse, error) {
+	var user *client.UserResponse
+	err := Call(ctx, config, func(c *client.Client) (err error) {
+		user, err = c.GetCurrentUser(ctx)
+		return err
+	})
+	return user, err
+}
+
+func TestCall_RefreshesExpiredToken(t *testing.T) {
+	server := newTestServer(t)
+	useTempConfig(t)
+	require.NoError(t, SaveTokens("expired", "refresh"))
+
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	config.APIBaseURL = server.URL
+
+	user, err := getCurrentUser(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, "test@example.com", user.Email)
+	assert.Equal(t, "fresh", config.AccessToken)
+
+	saved, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", saved.AccessToken)
+	assert.Equal(t, "refresh", saved.RefreshToken)
+}
+
+func TestCall_WithoutRefreshToken(t *testing.T) {
+	server := newTestServer(t)
+	useTempConfig(t)
+
+	config := &Config{APIBaseURL: server.URL, AccessToken: "expired"}
+	_, err := getCurrentUser(context.Background(), config)
+
+	var apiErr *client.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.Contains(t, err.Error(), "portfolios auth login")
+}
+
+func TestCall_APIKeyFromEnvironment(t *testing.T) {
+	server := newTestServer(t)
+	path := useTempConfig(t)
+	t.Setenv(APIKeyEnvVar, "pfk_test")
+
+	config, err := LoadConfig()
+	require.NoError(t, err)
+	config.APIBaseURL = server.URL
+
+	user, err := getCurrentUser(context.Background(), config)
+	require.NoError(t, err)
+	assert.Equal(t, "test@example.com", user.Email)
+
+	// The key from the environment is never written to the config file
+	require.NoError(t, SaveConfig(config))
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(contents), "pfk_test")
+}
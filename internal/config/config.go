@@ -1,9 +1,13 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -12,14 +16,27 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Server     ServerConfig     `yaml:"server"`
-	Database   DatabaseConfig   `yaml:"database"`
-	JWT        JWTConfig        `yaml:"jwt"`
-	SMTP       SMTPConfig       `yaml:"smtp"`
-	Security   SecurityConfig   `yaml:"security"`
-	MarketData MarketDataConfig `yaml:"market_data"`
-	Runtime    RuntimeConfig    `yaml:"runtime"`
-	Logging    LoggingConfig    `yaml:"logging"`
+	Server            ServerConfig            `yaml:"server"`
+	Database          DatabaseConfig          `yaml:"database"`
+	JWT               JWTConfig               `yaml:"jwt"`
+	SMTP              SMTPConfig              `yaml:"smtp"`
+	Email             EmailConfig             `yaml:"email"`
+	Push              PushConfig              `yaml:"push"`
+	Brokers           BrokersConfig           `yaml:"brokers"`
+	Security          SecurityConfig          `yaml:"security"`
+	MarketData        MarketDataConfig        `yaml:"market_data"`
+	Cache             CacheConfig             `yaml:"cache"`
+	Admin             AdminConfig             `yaml:"admin"`
+	Snapshots         SnapshotConfig          `yaml:"snapshots"`
+	Rounding          RoundingConfig          `yaml:"rounding"`
+	Password          PasswordConfig          `yaml:"password"`
+	Jobs              JobsConfig              `yaml:"jobs"`
+	TaxLossHarvesting TaxLossHarvestingConfig `yaml:"tax_loss_harvesting"`
+	Runtime           RuntimeConfig           `yaml:"runtime"`
+	Logging           LoggingConfig           `yaml:"logging"`
+	// Features turns feature flags on or off in this environment, by name; flags it doesn't
+	// name keep their default, and the admin API can override them for everyone or one user
+	Features map[string]bool `yaml:"features"`
 }
 
 // ServerConfig holds server-related configuration
@@ -27,11 +44,45 @@ type ServerConfig struct {
 	Port        string   `yaml:"port"`
 	Environment string   `yaml:"environment"`
 	CORSOrigins []string `yaml:"cors_origins"`
+	// RequestTimeout bounds how long a request may spend in database and provider calls;
+	// zero disables it
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+	// ShutdownTimeout bounds how long a stopping process waits for requests and jobs to
+	// finish their current unit of work before cancelling them; zero uses the default
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// DisableJobs keeps the API server from running background jobs, for deployments
+	// where a separate worker runs them
+	DisableJobs bool `yaml:"disable_jobs"`
+	// GRPCPort, if set, serves the gRPC API for internal integrations on this port
+	GRPCPort string `yaml:"grpc_port"`
+	// JobWorkers is how many queued jobs, such as imports and recalculations, a process
+	// runs at once; zero leaves them to other processes
+	JobWorkers int `yaml:"job_workers"`
+	// MaintenanceMode answers every request but the health check and the admin API with 503,
+	// for example while migrations run; the admin API can also turn it on and off
+	MaintenanceMode bool `yaml:"maintenance_mode"`
+	// MaintenanceRetryAfter is sent as Retry-After with maintenance mode responses
+	MaintenanceRetryAfter time.Duration `yaml:"maintenance_retry_after"`
 }
 
 // DatabaseConfig holds database connection configuration
 type DatabaseConfig struct {
 	URL string `yaml:"url"`
+	// ReplicaURL is an optional PostgreSQL read replica serving the heavy read-only endpoints
+	// (valuations, snapshots, analytics), which fall back to the primary while it's down
+	ReplicaURL string `yaml:"replica_url"`
+	// MultiSchema keeps the portfolio data of each organization created through the admin API
+	// in its own Postgres schema
+	MultiSchema bool `yaml:"multi_schema"`
+	// MaxOpenConns and MaxIdleConns size each process's connection pool (0 open = unlimited)
+	MaxOpenConns int `yaml:"max_open_conns"`
+	MaxIdleConns int `yaml:"max_idle_conns"`
+	// ConnMaxLifetime and ConnMaxIdleTime close connections once they are this old or have
+	// been idle this long (0 = never)
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	// SlowQueryThreshold logs queries taking at least this long to the server log (0 = none)
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"`
 }
 
 // JWTConfig holds JWT token configuration
@@ -52,16 +103,164 @@ type SMTPConfig struct {
 	From     string `yaml:"from"`
 }
 
+// EmailConfig selects how emails are delivered
+type EmailConfig struct {
+	// Transport is smtp, ses, sendgrid or log, which writes emails to the server log instead
+	// of sending them. Empty uses smtp when smtp.host is set and sends no emails otherwise.
+	Transport string `yaml:"transport"`
+	// From is the sender address of every transport (default: smtp.from)
+	From           string    `yaml:"from"`
+	SES            SESConfig `yaml:"ses"`
+	SendGridAPIKey string    `yaml:"sendgrid_api_key"`
+}
+
+// SESConfig holds the AWS region and credentials emails are sent through Amazon SES with
+type SESConfig struct {
+	Region          string `yaml:"region"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+}
+
+// EmailTransport returns the transport emails are delivered with, or "" when none is set up
+func (c *Config) EmailTransport() string {
+	if c.Email.Transport == "" && c.SMTP.Host != "" {
+		return "smtp"
+	}
+	return c.Email.Transport
+}
+
+// EmailFrom returns the sender address of emails
+func (c *Config) EmailFrom() string {
+	if c.Email.From != "" {
+		return c.Email.From
+	}
+	return c.SMTP.From
+}
+
+// PushConfig holds push notification delivery configuration
+type PushConfig struct {
+	// VAPIDPublicKey and VAPIDPrivateKey identify the server to Web Push services, as the
+	// base64url P-256 keys that web-push tools generate; Web Push is disabled when empty
+	VAPIDPublicKey  string `yaml:"vapid_public_key"`
+	VAPIDPrivateKey string `yaml:"vapid_private_key"`
+	// VAPIDSubject is a mailto: or https: URL push services can reach the operator at
+	VAPIDSubject string `yaml:"vapid_subject"`
+}
+
+// BrokersConfig holds configuration for syncing portfolios with brokerage accounts
+type BrokersConfig struct {
+	// EncryptionKey seals the access tokens of broker connections at rest, as 32 bytes in hex
+	// or base64; broker connections are disabled when empty
+	EncryptionKey string      `yaml:"encryption_key"`
+	Plaid         PlaidConfig `yaml:"plaid"`
+}
+
+// PlaidConfig holds Plaid Investments API credentials; Plaid connections are disabled
+// without a client ID and secret
+type PlaidConfig struct {
+	ClientID    string `yaml:"client_id"`
+	Secret      string `yaml:"secret"`
+	Environment string `yaml:"environment"` // "sandbox" or "production"
+}
+
 // SecurityConfig holds security-related configuration
 type SecurityConfig struct {
+	// RateLimitRequests and RateLimitDuration limit requests to the authentication endpoints
+	// per client IP
 	RateLimitRequests int           `yaml:"rate_limit_requests"`
 	RateLimitDuration time.Duration `yaml:"rate_limit_duration"`
+	// APIRateLimitRequests and APIRateLimitDuration limit requests to the rest of the API
+	// per authenticated user; zero requests turns the limit off
+	APIRateLimitRequests int           `yaml:"api_rate_limit_requests"`
+	APIRateLimitDuration time.Duration `yaml:"api_rate_limit_duration"`
+	// CORSPreset is "development", which also allows any localhost origin, or "production",
+	// which never sends credentials to origins only "*" allows. Empty picks the preset
+	// matching the server environment.
+	CORSPreset string `yaml:"cors_preset"`
+	// HSTSMaxAge is sent in the Strict-Transport-Security header; zero leaves it out
+	HSTSMaxAge time.Duration `yaml:"hsts_max_age"`
+	// ContentSecurityPolicy replaces the default Content-Security-Policy header
+	ContentSecurityPolicy string `yaml:"content_security_policy"`
+	// CSRFProtection requires a double-submit CSRF token on unsafe requests that don't carry
+	// an Authorization or API key header, for deployments where browsers authenticate with
+	// cookies
+	CSRFProtection bool `yaml:"csrf_protection"`
+	// SessionCookies keeps browser sessions in httpOnly cookies: login sets the access and
+	// refresh tokens as cookies instead of returning them, and refresh rotates them
+	SessionCookies bool `yaml:"session_cookies"`
 }
 
 // MarketDataConfig holds market data provider configuration
 type MarketDataConfig struct {
-	Provider string `yaml:"provider"`
-	APIKey   string `yaml:"api_key"`
+	Provider           string `yaml:"provider"`
+	APIKey             string `yaml:"api_key"`
+	DailyRequestLimit  int    `yaml:"daily_request_limit"`  // Provider requests allowed per day (0 = unlimited)
+	MinuteRequestLimit int    `yaml:"minute_request_limit"` // Provider requests allowed per minute (0 = unlimited)
+	CryptoProvider     string `yaml:"crypto_provider"`      // Prices coin pairs such as BTC-USD ("coingecko" or "none")
+	CryptoAPIKey       string `yaml:"crypto_api_key"`       // Optional; the free tier works without one
+	QuoteConcurrency   int    `yaml:"quote_concurrency"`    // Quotes fetched at once for multi-symbol requests (0 or 1 sends them as one batch)
+	// BreakerFailures is how many timeouts or network errors in a row stop calling a provider
+	// for BreakerCooldown, while quotes are served from the last ones fetched (0 = never)
+	BreakerFailures int           `yaml:"breaker_failures"`
+	BreakerCooldown time.Duration `yaml:"breaker_cooldown"`
+}
+
+// CacheConfig holds shared cache configuration
+type CacheConfig struct {
+	RedisURL string `yaml:"redis_url"` // Optional Redis URL; in-memory caching is used when empty
+}
+
+// AdminConfig holds admin provisioning API configuration
+type AdminConfig struct {
+	APIToken       string        `yaml:"api_token"`       // Bearer token for /api/admin/v1; the admin API is disabled when empty
+	InviteValidity time.Duration `yaml:"invite_validity"` // How long invites to provisioned users, organization invitations and delegation requests stay valid
+}
+
+// SnapshotConfig holds performance snapshot retention configuration
+type SnapshotConfig struct {
+	DailyRetentionMonths  int `yaml:"daily_retention_months"`  // Keep every daily snapshot this long
+	WeeklyRetentionMonths int `yaml:"weekly_retention_months"` // Then keep one per week this long, and one per month after that
+}
+
+// RoundingConfig holds the rounding policy for quantities and amounts derived by corporate
+// actions and tax lot allocations
+type RoundingConfig struct {
+	Mode                 string `yaml:"mode"`                   // HALF_UP or HALF_EVEN (banker's rounding)
+	EquityQuantityPlaces int    `yaml:"equity_quantity_places"` // Decimal places of stock and fund share quantities
+	CryptoQuantityPlaces int    `yaml:"crypto_quantity_places"` // Decimal places of coin quantities
+	AmountPlaces         int    `yaml:"amount_places"`          // Decimal places of cost bases and proceeds
+}
+
+// PasswordConfig holds the policy for the passwords users choose
+type PasswordConfig struct {
+	MinLength        int      `yaml:"min_length"`
+	RequireUppercase bool     `yaml:"require_uppercase"`
+	RequireLowercase bool     `yaml:"require_lowercase"`
+	RequireNumber    bool     `yaml:"require_number"`
+	RequireSymbol    bool     `yaml:"require_symbol"`
+	BannedPasswords  []string `yaml:"banned_passwords"` // Rejected case-insensitively
+	HistorySize      int      `yaml:"history_size"`     // Recent passwords that can't be reused, 0 to allow reuse
+	// HashAlgorithm is argon2id or bcrypt. Hashes created with the other algorithm or other
+	// parameters are replaced when their users next log in.
+	HashAlgorithm     string `yaml:"hash_algorithm"`
+	Argon2Memory      int    `yaml:"argon2_memory"`      // In KiB
+	Argon2Iterations  int    `yaml:"argon2_iterations"`  // Passes over the memory
+	Argon2Parallelism int    `yaml:"argon2_parallelism"` // Threads per hash
+	BcryptCost        int    `yaml:"bcrypt_cost"`
+}
+
+// JobsConfig holds background job configuration
+type JobsConfig struct {
+	// Schedules overrides the schedules of jobs by job name, for example
+	// PriceUpdate: "@every 6h"
+	Schedules map[string]string `yaml:"schedules"`
+}
+
+// TaxLossHarvestingConfig holds tax-loss harvesting configuration
+type TaxLossHarvestingConfig struct {
+	// Replacements adds to or overrides the suggested replacement securities by symbol, for
+	// example VTI: [ITOT, SCHB]
+	Replacements map[string][]string `yaml:"replacements"`
 }
 
 // RuntimeConfig holds runtime directory configuration
@@ -77,6 +276,25 @@ type LoggingConfig struct {
 	RequestLogPath string `yaml:"request_log"`    // Path to request log file
 	EnableConsole  bool   `yaml:"enable_console"` // Enable console output
 	EnableFile     bool   `yaml:"enable_file"`    // Enable file output
+	// BodyRoutes lists the routes whose request and response bodies are logged to the request
+	// log, as Gin route patterns optionally preceded by a method, such as
+	// "POST /api/v1/portfolios/:id/imports". A pattern ending in * matches every route it
+	// begins. Passwords, tokens and email addresses are redacted.
+	BodyRoutes []string `yaml:"body_routes"`
+	// BodyMaxBytes is how much of each logged body is kept
+	BodyMaxBytes int `yaml:"body_max_bytes"`
+	// Outputs ship the server and request logs to remote receivers as well as the log files
+	Outputs []LogOutputConfig `yaml:"outputs"`
+}
+
+// LogOutputConfig is a remote receiver logs are shipped to
+type LogOutputConfig struct {
+	Type    string            `yaml:"type"`    // syslog, http or otlp
+	Address string            `yaml:"address"` // syslog server as udp://host:port, tcp://host:port or unix:///path; empty for the local syslog
+	URL     string            `yaml:"url"`     // Endpoint of http and otlp outputs
+	Headers map[string]string `yaml:"headers"` // Sent with http and otlp requests, e.g. Authorization
+	Tag     string            `yaml:"tag"`     // Application name sent to syslog and OTLP service name (default: portfolios)
+	Logs    []string          `yaml:"logs"`    // server, request or both (default: both)
 }
 
 // Load reads configuration from environment variables
@@ -90,12 +308,194 @@ func LoadWithYAML(yamlPath string) (*Config, error) {
 	// Load .env file if it exists (for local development)
 	_ = godotenv.Load()
 
-	// Initialize with defaults
-	config := &Config{
+	config := defaults()
+
+	// Load from YAML file if provided
+	if yamlPath != "" {
+		if err := loadFromYAML(yamlPath, config); err != nil {
+			return nil, fmt.Errorf("failed to load YAML config: %w", err)
+		}
+	}
+
+	// Override with environment variables (env vars take precedence)
+	if err := applyEnvironmentOverrides(config); err != nil {
+		return nil, err
+	}
+
+	// Validate required fields
+	if config.Database.URL == "" {
+		return nil, fmt.Errorf("DATABASE_URL is required")
+	}
+	if config.JWT.Secret == "" {
+		return nil, fmt.Errorf("JWT_SECRET is required")
+	}
+	if config.Database.MultiSchema && strings.HasPrefix(config.Database.URL, "sqlite://") {
+		return nil, fmt.Errorf("DATABASE_MULTI_SCHEMA requires a PostgreSQL DATABASE_URL")
+	}
+	if config.Database.ReplicaURL != "" &&
+		(strings.HasPrefix(config.Database.URL, "sqlite://") || strings.HasPrefix(config.Database.ReplicaURL, "sqlite://")) {
+		return nil, fmt.Errorf("DATABASE_REPLICA_URL requires a PostgreSQL DATABASE_URL and replica")
+	}
+
+	return config, nil
+}
+
+// LoadYAMLStrict reads configuration from a YAML file alone, without environment variables,
+// rejecting fields that don't exist and values of the wrong type. Unlike LoadWithYAML it
+// doesn't require settings that usually come from the environment, such as the database URL,
+// and it checks ${env:VAR} and ${file:path} references without resolving them.
+func LoadYAMLStrict(yamlPath string) (*Config, error) {
+	data, err := os.ReadFile(yamlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if err := interpolateNode(&document, checkReferences); err != nil {
+		return nil, err
+	}
+
+	config := defaults()
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return config, nil
+}
+
+// Validate checks the values of settings that have a fixed set of valid values or ranges.
+// Policies built from the configuration, such as the password policy, are checked where
+// they are built.
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Logging.Level {
+	case "", "debug", "info", "warn", "error", "fatal":
+	default:
+		errs = append(errs, fmt.Errorf("logging.level must be debug, info, warn, error or fatal, got %q", c.Logging.Level))
+	}
+	switch c.Logging.Format {
+	case "", "json", "console":
+	default:
+		errs = append(errs, fmt.Errorf("logging.format must be json or console, got %q", c.Logging.Format))
+	}
+
+	if len(c.Logging.BodyRoutes) > 0 && c.Logging.BodyMaxBytes <= 0 {
+		errs = append(errs, fmt.Errorf("logging.body_max_bytes must be positive when logging.body_routes is set, got %d", c.Logging.BodyMaxBytes))
+	}
+
+	errs = append(errs,
+		validateRateLimit("security.rate_limit", c.Security.RateLimitRequests, c.Security.RateLimitDuration),
+		validateRateLimit("security.api_rate_limit", c.Security.APIRateLimitRequests, c.Security.APIRateLimitDuration),
+	)
+
+	if c.Server.JobWorkers < 0 {
+		errs = append(errs, fmt.Errorf("server.job_workers must not be negative, got %d", c.Server.JobWorkers))
+	}
+	if c.Server.RequestTimeout < 0 {
+		errs = append(errs, fmt.Errorf("server.request_timeout must not be negative, got %s", c.Server.RequestTimeout))
+	}
+	if c.Server.ShutdownTimeout < 0 {
+		errs = append(errs, fmt.Errorf("server.shutdown_timeout must not be negative, got %s", c.Server.ShutdownTimeout))
+	}
+	if c.Server.MaintenanceRetryAfter < 0 {
+		errs = append(errs, fmt.Errorf("server.maintenance_retry_after must not be negative, got %s", c.Server.MaintenanceRetryAfter))
+	}
+	if c.MarketData.QuoteConcurrency < 0 {
+		errs = append(errs, fmt.Errorf("market_data.quote_concurrency must not be negative, got %d", c.MarketData.QuoteConcurrency))
+	}
+	if c.MarketData.BreakerFailures < 0 {
+		errs = append(errs, fmt.Errorf("market_data.breaker_failures must not be negative, got %d", c.MarketData.BreakerFailures))
+	}
+	if c.MarketData.BreakerCooldown < 0 {
+		errs = append(errs, fmt.Errorf("market_data.breaker_cooldown must not be negative, got %s", c.MarketData.BreakerCooldown))
+	}
+	switch c.Brokers.Plaid.Environment {
+	case "", "sandbox", "production":
+	default:
+		errs = append(errs, fmt.Errorf("brokers.plaid.environment must be sandbox or production, got %q", c.Brokers.Plaid.Environment))
+	}
+
+	errs = append(errs, c.Database.validate(), c.validateEmail())
+
+	return errors.Join(errs...)
+}
+
+// validate checks the connection pool and slow query settings
+func (d DatabaseConfig) validate() error {
+	var errs []error
+	if d.MaxOpenConns < 0 {
+		errs = append(errs, fmt.Errorf("database.max_open_conns must not be negative, got %d", d.MaxOpenConns))
+	}
+	if d.MaxIdleConns < 0 {
+		errs = append(errs, fmt.Errorf("database.max_idle_conns must not be negative, got %d", d.MaxIdleConns))
+	}
+	if d.ConnMaxLifetime < 0 {
+		errs = append(errs, fmt.Errorf("database.conn_max_lifetime must not be negative, got %s", d.ConnMaxLifetime))
+	}
+	if d.ConnMaxIdleTime < 0 {
+		errs = append(errs, fmt.Errorf("database.conn_max_idle_time must not be negative, got %s", d.ConnMaxIdleTime))
+	}
+	if d.SlowQueryThreshold < 0 {
+		errs = append(errs, fmt.Errorf("database.slow_query_threshold must not be negative, got %s", d.SlowQueryThreshold))
+	}
+	return errors.Join(errs...)
+}
+
+// validateEmail checks that the selected email transport has what it needs to send
+func (c *Config) validateEmail() error {
+	switch c.EmailTransport() {
+	case "", "log":
+		return nil
+	case "smtp":
+		if c.SMTP.Host == "" {
+			return fmt.Errorf("smtp.host is required for the smtp email transport")
+		}
+	case "ses":
+		if c.Email.SES.Region == "" || c.Email.SES.AccessKeyID == "" || c.Email.SES.SecretAccessKey == "" {
+			return fmt.Errorf("email.ses.region, access_key_id and secret_access_key are required for the ses email transport")
+		}
+	case "sendgrid":
+		if c.Email.SendGridAPIKey == "" {
+			return fmt.Errorf("email.sendgrid_api_key is required for the sendgrid email transport")
+		}
+	default:
+		return fmt.Errorf("email.transport must be smtp, ses, sendgrid or log, got %q", c.Email.Transport)
+	}
+	if c.EmailFrom() == "" {
+		return fmt.Errorf("email.from is required to send emails")
+	}
+	return nil
+}
+
+// validateRateLimit checks a rate limit of requests per duration, where zero requests turns
+// the limit off
+func validateRateLimit(name string, requests int, duration time.Duration) error {
+	if requests < 0 {
+		return fmt.Errorf("%s_requests must not be negative, got %d", name, requests)
+	}
+	if requests > 0 && duration <= 0 {
+		return fmt.Errorf("%s_duration must be positive, got %s", name, duration)
+	}
+	return nil
+}
+
+// defaults returns the configuration used for settings neither the YAML file nor the
+// environment set
+func defaults() *Config {
+	return &Config{
 		Server: ServerConfig{
-			Port:        "8080",
-			Environment: "development",
-			CORSOrigins: []string{"http://localhost:5173"},
+			Port:                  "8080",
+			Environment:           "development",
+			CORSOrigins:           []string{"http://localhost:5173"},
+			RequestTimeout:        10 * time.Second,
+			ShutdownTimeout:       30 * time.Second,
+			JobWorkers:            4,
+			MaintenanceRetryAfter: time.Minute,
 		},
 		JWT: JWTConfig{
 			AccessTokenDuration:       30 * time.Minute,
@@ -103,43 +503,69 @@ func LoadWithYAML(yamlPath string) (*Config, error) {
 			RememberMeAccessDuration:  24 * time.Hour,
 			RememberMeRefreshDuration: 30 * 24 * time.Hour,
 		},
+		Database: DatabaseConfig{
+			MaxOpenConns:       25,
+			MaxIdleConns:       5,
+			ConnMaxLifetime:    5 * time.Minute,
+			ConnMaxIdleTime:    10 * time.Minute,
+			SlowQueryThreshold: 200 * time.Millisecond,
+		},
 		SMTP: SMTPConfig{
 			Port: 587,
 		},
 		Security: SecurityConfig{
-			RateLimitRequests: 5,
-			RateLimitDuration: 1 * time.Minute,
+			RateLimitRequests:    5,
+			RateLimitDuration:    1 * time.Minute,
+			APIRateLimitRequests: 300,
+			APIRateLimitDuration: 1 * time.Minute,
+			HSTSMaxAge:           365 * 24 * time.Hour,
 		},
 		MarketData: MarketDataConfig{
-			Provider: "alphavantage",
+			Provider:           "alphavantage",
+			DailyRequestLimit:  25, // Alpha Vantage free tier
+			MinuteRequestLimit: 5,
+			CryptoProvider:     "coingecko",
+			QuoteConcurrency:   4,
+			BreakerFailures:    5,
+			BreakerCooldown:    30 * time.Second,
+		},
+		Brokers: BrokersConfig{
+			Plaid: PlaidConfig{
+				Environment: "sandbox",
+			},
+		},
+		Admin: AdminConfig{
+			InviteValidity: 7 * 24 * time.Hour,
+		},
+		Snapshots: SnapshotConfig{
+			DailyRetentionMonths:  24,
+			WeeklyRetentionMonths: 60,
+		},
+		Rounding: RoundingConfig{
+			Mode:                 "HALF_UP",
+			EquityQuantityPlaces: 8,
+			CryptoQuantityPlaces: 8,
+			AmountPlaces:         8,
+		},
+		Password: PasswordConfig{
+			MinLength:         8,
+			RequireUppercase:  true,
+			RequireLowercase:  true,
+			RequireNumber:     true,
+			HashAlgorithm:     "argon2id",
+			Argon2Memory:      64 * 1024,
+			Argon2Iterations:  3,
+			Argon2Parallelism: 4,
+			BcryptCost:        12,
 		},
 		Logging: LoggingConfig{
 			Level:         "info",
 			Format:        "json",
 			EnableConsole: true,
 			EnableFile:    false,
+			BodyMaxBytes:  4096,
 		},
 	}
-
-	// Load from YAML file if provided
-	if yamlPath != "" {
-		if err := loadFromYAML(yamlPath, config); err != nil {
-			return nil, fmt.Errorf("failed to load YAML config: %w", err)
-		}
-	}
-
-	// Override with environment variables (env vars take precedence)
-	applyEnvironmentOverrides(config)
-
-	// Validate required fields
-	if config.Database.URL == "" {
-		return nil, fmt.Errorf("DATABASE_URL is required")
-	}
-	if config.JWT.Secret == "" {
-		return nil, fmt.Errorf("JWT_SECRET is required")
-	}
-
-	return config, nil
 }
 
 // loadFromYAML loads configuration from a YAML file
@@ -153,15 +579,36 @@ func loadFromYAML(path string, config *Config) error {
 		return err
 	}
 
-	if err := yaml.Unmarshal(data, config); err != nil {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if document.Kind == 0 {
+		// Empty file
+		return nil
+	}
+	if err := interpolateNode(&document, interpolate); err != nil {
+		return err
+	}
+	if err := document.Decode(config); err != nil {
 		return fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
 	return nil
 }
 
-// applyEnvironmentOverrides applies environment variable overrides to the config
-func applyEnvironmentOverrides(config *Config) {
+// applyEnvironmentOverrides applies environment variable overrides to the config. Secrets
+// may instead be read from the files named by their _FILE variables.
+func applyEnvironmentOverrides(config *Config) error {
+	var errs []error
+	secret := func(key string) string {
+		val, err := getSecretEnv(key)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return val
+	}
+
 	// Server config
 	if val := getEnv("SERVER_PORT", ""); val != "" {
 		config.Server.Port = val
@@ -172,14 +619,38 @@ func applyEnvironmentOverrides(config *Config) {
 	if val := getEnvAsSlice("CORS_ALLOWED_ORIGINS", nil); val != nil {
 		config.Server.CORSOrigins = val
 	}
+	config.Server.RequestTimeout = getEnvAsDuration("REQUEST_TIMEOUT", config.Server.RequestTimeout)
+	config.Server.ShutdownTimeout = getEnvAsDuration("SHUTDOWN_TIMEOUT", config.Server.ShutdownTimeout)
+	if val := getEnvAsBool("DISABLE_BACKGROUND_JOBS", false); val {
+		config.Server.DisableJobs = true
+	}
+	if val := getEnv("GRPC_PORT", ""); val != "" {
+		config.Server.GRPCPort = val
+	}
+	if val := getEnvAsInt("JOB_WORKERS", -1); val >= 0 {
+		config.Server.JobWorkers = val
+	}
+	config.Server.MaintenanceMode = getEnvAsBool("MAINTENANCE_MODE", config.Server.MaintenanceMode)
+	config.Server.MaintenanceRetryAfter = getEnvAsDuration("MAINTENANCE_RETRY_AFTER", config.Server.MaintenanceRetryAfter)
 
 	// Database config
-	if val := getEnv("DATABASE_URL", ""); val != "" {
+	if val := secret("DATABASE_URL"); val != "" {
 		config.Database.URL = val
 	}
+	if val := secret("DATABASE_REPLICA_URL"); val != "" {
+		config.Database.ReplicaURL = val
+	}
+	if val := getEnvAsBool("DATABASE_MULTI_SCHEMA", false); val {
+		config.Database.MultiSchema = true
+	}
+	config.Database.MaxOpenConns = getEnvAsInt("DATABASE_MAX_OPEN_CONNS", config.Database.MaxOpenConns)
+	config.Database.MaxIdleConns = getEnvAsInt("DATABASE_MAX_IDLE_CONNS", config.Database.MaxIdleConns)
+	config.Database.ConnMaxLifetime = getEnvAsDuration("DATABASE_CONN_MAX_LIFETIME", config.Database.ConnMaxLifetime)
+	config.Database.ConnMaxIdleTime = getEnvAsDuration("DATABASE_CONN_MAX_IDLE_TIME", config.Database.ConnMaxIdleTime)
+	config.Database.SlowQueryThreshold = getEnvAsDuration("DATABASE_SLOW_QUERY_THRESHOLD", config.Database.SlowQueryThreshold)
 
 	// JWT config
-	if val := getEnv("JWT_SECRET", ""); val != "" {
+	if val := secret("JWT_SECRET"); val != "" {
 		config.JWT.Secret = val
 	}
 	if val := getEnvAsDuration("JWT_ACCESS_TOKEN_DURATION", 0); val != 0 {
@@ -202,31 +673,172 @@ func applyEnvironmentOverrides(config *Config) {
 	if val := getEnvAsInt("SMTP_PORT", 0); val != 0 {
 		config.SMTP.Port = val
 	}
-	if val := getEnv("SMTP_USERNAME", ""); val != "" {
+	if val := secret("SMTP_USERNAME"); val != "" {
 		config.SMTP.Username = val
 	}
-	if val := getEnv("SMTP_PASSWORD", ""); val != "" {
+	if val := secret("SMTP_PASSWORD"); val != "" {
 		config.SMTP.Password = val
 	}
 	if val := getEnv("SMTP_FROM", ""); val != "" {
 		config.SMTP.From = val
 	}
 
+	// Email config
+	if val := getEnv("EMAIL_TRANSPORT", ""); val != "" {
+		config.Email.Transport = val
+	}
+	if val := getEnv("EMAIL_FROM", ""); val != "" {
+		config.Email.From = val
+	}
+	if val := getEnv("EMAIL_SES_REGION", ""); val != "" {
+		config.Email.SES.Region = val
+	}
+	if val := getEnv("EMAIL_SES_ACCESS_KEY_ID", ""); val != "" {
+		config.Email.SES.AccessKeyID = val
+	}
+	if val := secret("EMAIL_SES_SECRET_ACCESS_KEY"); val != "" {
+		config.Email.SES.SecretAccessKey = val
+	}
+	if val := secret("EMAIL_SENDGRID_API_KEY"); val != "" {
+		config.Email.SendGridAPIKey = val
+	}
+
+	// Push config
+	if val := getEnv("PUSH_VAPID_PUBLIC_KEY", ""); val != "" {
+		config.Push.VAPIDPublicKey = val
+	}
+	if val := secret("PUSH_VAPID_PRIVATE_KEY"); val != "" {
+		config.Push.VAPIDPrivateKey = val
+	}
+	if val := getEnv("PUSH_VAPID_SUBJECT", ""); val != "" {
+		config.Push.VAPIDSubject = val
+	}
+
+	// Brokers config
+	if val := secret("BROKERS_ENCRYPTION_KEY"); val != "" {
+		config.Brokers.EncryptionKey = val
+	}
+	if val := getEnv("PLAID_CLIENT_ID", ""); val != "" {
+		config.Brokers.Plaid.ClientID = val
+	}
+	if val := secret("PLAID_SECRET"); val != "" {
+		config.Brokers.Plaid.Secret = val
+	}
+	if val := getEnv("PLAID_ENVIRONMENT", ""); val != "" {
+		config.Brokers.Plaid.Environment = val
+	}
+
 	// Security config
-	if val := getEnvAsInt("RATE_LIMIT_REQUESTS", 0); val != 0 {
+	// Zero turns a rate limit off, so only an unset variable keeps the configured limit
+	if val := getEnvAsInt("RATE_LIMIT_REQUESTS", -1); val >= 0 {
 		config.Security.RateLimitRequests = val
 	}
 	if val := getEnvAsDuration("RATE_LIMIT_DURATION", 0); val != 0 {
 		config.Security.RateLimitDuration = val
 	}
+	if val := getEnvAsInt("API_RATE_LIMIT_REQUESTS", -1); val >= 0 {
+		config.Security.APIRateLimitRequests = val
+	}
+	if val := getEnvAsDuration("API_RATE_LIMIT_DURATION", 0); val != 0 {
+		config.Security.APIRateLimitDuration = val
+	}
+	if val := getEnv("CORS_PRESET", ""); val != "" {
+		config.Security.CORSPreset = val
+	}
+	config.Security.HSTSMaxAge = getEnvAsDuration("HSTS_MAX_AGE", config.Security.HSTSMaxAge)
+	if val := getEnv("CONTENT_SECURITY_POLICY", ""); val != "" {
+		config.Security.ContentSecurityPolicy = val
+	}
+	config.Security.CSRFProtection = getEnvAsBool("CSRF_PROTECTION", config.Security.CSRFProtection)
+	config.Security.SessionCookies = getEnvAsBool("SESSION_COOKIES", config.Security.SessionCookies)
 
 	// Market data config
 	if val := getEnv("MARKET_DATA_PROVIDER", ""); val != "" {
 		config.MarketData.Provider = val
 	}
-	if val := getEnv("MARKET_DATA_API_KEY", ""); val != "" {
+	if val := secret("MARKET_DATA_API_KEY"); val != "" {
 		config.MarketData.APIKey = val
 	}
+	if val := getEnvAsInt("MARKET_DATA_DAILY_LIMIT", -1); val >= 0 {
+		config.MarketData.DailyRequestLimit = val
+	}
+	if val := getEnvAsInt("MARKET_DATA_MINUTE_LIMIT", -1); val >= 0 {
+		config.MarketData.MinuteRequestLimit = val
+	}
+	if val := getEnv("MARKET_DATA_CRYPTO_PROVIDER", ""); val != "" {
+		config.MarketData.CryptoProvider = val
+	}
+	if val := secret("MARKET_DATA_CRYPTO_API_KEY"); val != "" {
+		config.MarketData.CryptoAPIKey = val
+	}
+	config.MarketData.QuoteConcurrency = getEnvAsInt("MARKET_DATA_QUOTE_CONCURRENCY", config.MarketData.QuoteConcurrency)
+	config.MarketData.BreakerFailures = getEnvAsInt("MARKET_DATA_BREAKER_FAILURES", config.MarketData.BreakerFailures)
+	config.MarketData.BreakerCooldown = getEnvAsDuration("MARKET_DATA_BREAKER_COOLDOWN", config.MarketData.BreakerCooldown)
+
+	// Cache config
+	if val := secret("CACHE_REDIS_URL"); val != "" {
+		config.Cache.RedisURL = val
+	}
+
+	// Admin config
+	if val := secret("ADMIN_API_TOKEN"); val != "" {
+		config.Admin.APIToken = val
+	}
+	if val := getEnvAsDuration("ADMIN_INVITE_VALIDITY", 0); val != 0 {
+		config.Admin.InviteValidity = val
+	}
+
+	// Snapshot retention config
+	if val := getEnvAsInt("SNAPSHOT_DAILY_RETENTION_MONTHS", 0); val != 0 {
+		config.Snapshots.DailyRetentionMonths = val
+	}
+	if val := getEnvAsInt("SNAPSHOT_WEEKLY_RETENTION_MONTHS", 0); val != 0 {
+		config.Snapshots.WeeklyRetentionMonths = val
+	}
+
+	// Rounding config; zero places is a valid setting, so unset is -1
+	if val := getEnv("ROUNDING_MODE", ""); val != "" {
+		config.Rounding.Mode = val
+	}
+	if val := getEnvAsInt("ROUNDING_EQUITY_QUANTITY_PLACES", -1); val >= 0 {
+		config.Rounding.EquityQuantityPlaces = val
+	}
+	if val := getEnvAsInt("ROUNDING_CRYPTO_QUANTITY_PLACES", -1); val >= 0 {
+		config.Rounding.CryptoQuantityPlaces = val
+	}
+	if val := getEnvAsInt("ROUNDING_AMOUNT_PLACES", -1); val >= 0 {
+		config.Rounding.AmountPlaces = val
+	}
+
+	// Password policy config; a history size of zero is a valid setting, so unset is -1
+	if val := getEnvAsInt("PASSWORD_MIN_LENGTH", 0); val != 0 {
+		config.Password.MinLength = val
+	}
+	config.Password.RequireUppercase = getEnvAsBool("PASSWORD_REQUIRE_UPPERCASE", config.Password.RequireUppercase)
+	config.Password.RequireLowercase = getEnvAsBool("PASSWORD_REQUIRE_LOWERCASE", config.Password.RequireLowercase)
+	config.Password.RequireNumber = getEnvAsBool("PASSWORD_REQUIRE_NUMBER", config.Password.RequireNumber)
+	config.Password.RequireSymbol = getEnvAsBool("PASSWORD_REQUIRE_SYMBOL", config.Password.RequireSymbol)
+	if val := getEnvAsSlice("PASSWORD_BANNED_LIST", nil); val != nil {
+		config.Password.BannedPasswords = val
+	}
+	if val := getEnvAsInt("PASSWORD_HISTORY_SIZE", -1); val >= 0 {
+		config.Password.HistorySize = val
+	}
+	if val := getEnv("PASSWORD_HASH_ALGORITHM", ""); val != "" {
+		config.Password.HashAlgorithm = val
+	}
+	if val := getEnvAsInt("PASSWORD_ARGON2_MEMORY", 0); val != 0 {
+		config.Password.Argon2Memory = val
+	}
+	if val := getEnvAsInt("PASSWORD_ARGON2_ITERATIONS", 0); val != 0 {
+		config.Password.Argon2Iterations = val
+	}
+	if val := getEnvAsInt("PASSWORD_ARGON2_PARALLELISM", 0); val != 0 {
+		config.Password.Argon2Parallelism = val
+	}
+	if val := getEnvAsInt("PASSWORD_BCRYPT_COST", 0); val != 0 {
+		config.Password.BcryptCost = val
+	}
 
 	// Runtime config
 	if val := getEnv("RUNTIME_HOME_DIR", ""); val != "" {
@@ -252,6 +864,28 @@ func applyEnvironmentOverrides(config *Config) {
 	if val := getEnvAsBool("LOG_ENABLE_FILE", false); val {
 		config.Logging.EnableFile = val
 	}
+	if val := getEnvAsSlice("LOG_BODY_ROUTES", nil); val != nil {
+		config.Logging.BodyRoutes = val
+	}
+	if val := getEnvAsInt("LOG_BODY_MAX_BYTES", 0); val != 0 {
+		config.Logging.BodyMaxBytes = val
+	}
+
+	// Feature flags, e.g. FEATURE_FLAGS=simulations=false,what_if=true
+	for _, entry := range getEnvAsSlice("FEATURE_FLAGS", nil) {
+		name, value, _ := strings.Cut(entry, "=")
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("FEATURE_FLAGS entries must be name=true or name=false, got %q", entry))
+			continue
+		}
+		if config.Features == nil {
+			config.Features = make(map[string]bool)
+		}
+		config.Features[strings.TrimSpace(name)] = enabled
+	}
+
+	return errors.Join(errs...)
 }
 
 // getEnv retrieves an environment variable or returns a default value
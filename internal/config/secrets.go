@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// secretFileSuffix turns the name of a secret's environment variable into the name of the
+// variable that holds the path of a file containing it, such as a Docker or Kubernetes
+// secret mount
+const secretFileSuffix = "_FILE"
+
+// referencePattern matches the ${env:VAR} and ${file:path} references in YAML values
+var referencePattern = regexp.MustCompile(`\$\{([a-z]+):([^}]*)\}`)
+
+// getSecretEnv retrieves a secret from the environment variable key or, if key_FILE is set
+// instead, from the file it names. Trailing newlines are dropped from files.
+func getSecretEnv(key string) (string, error) {
+	value := os.Getenv(key)
+	path := os.Getenv(key + secretFileSuffix)
+	if path == "" {
+		return value, nil
+	}
+	if value != "" {
+		return "", fmt.Errorf("set either %s or %s%s, not both", key, key, secretFileSuffix)
+	}
+	value, err := readSecretFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s%s: %w", key, secretFileSuffix, err)
+	}
+	return value, nil
+}
+
+// readSecretFile reads a secret from the file at path, dropping trailing newlines
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// interpolate replaces the ${env:VAR} and ${file:path} references in value with the value of
+// the environment variable or the contents of the file. Unset variables and unreadable
+// files are errors, so that a missing secret doesn't silently become an empty one.
+func interpolate(value string) (string, error) {
+	var errs []error
+	result := referencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		match := referencePattern.FindStringSubmatch(reference)
+		kind, name := match[1], match[2]
+		if err := checkReference(reference, kind, name); err != nil {
+			errs = append(errs, err)
+			return reference
+		}
+
+		switch kind {
+		case "env":
+			resolved, ok := os.LookupEnv(name)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: environment variable %s is not set", reference, name))
+			}
+			return resolved
+		default:
+			resolved, err := readSecretFile(name)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", reference, err))
+			}
+			return resolved
+		}
+	})
+	return result, errors.Join(errs...)
+}
+
+// checkReferences reports malformed references in value without resolving them
+func checkReferences(value string) (string, error) {
+	var errs []error
+	for _, match := range referencePattern.FindAllStringSubmatch(value, -1) {
+		errs = append(errs, checkReference(match[0], match[1], match[2]))
+	}
+	return value, errors.Join(errs...)
+}
+
+// checkReference checks that a reference is to an environment variable or a file and names one
+func checkReference(reference, kind, name string) error {
+	if kind != "env" && kind != "file" {
+		return fmt.Errorf("%s: unknown reference, expected ${env:VAR} or ${file:path}", reference)
+	}
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%s: reference names no %s", reference, kind)
+	}
+	return nil
+}
+
+// interpolateNode replaces every scalar value in the YAML document rooted at node with the
+// result of resolve. Mapping keys are left alone. Plain scalars are resolved again, so that
+// a reference can supply a number or a boolean.
+func interpolateNode(node *yaml.Node, resolve func(string) (string, error)) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if !referencePattern.MatchString(node.Value) {
+			return nil
+		}
+		value, err := resolve(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Value = value
+		if node.Style == 0 {
+			node.Tag = ""
+		}
+		return nil
+	case yaml.MappingNode:
+		var errs []error
+		for i := 1; i < len(node.Content); i += 2 {
+			errs = append(errs, interpolateNode(node.Content[i], resolve))
+		}
+		return errors.Join(errs...)
+	default:
+		var errs []error
+		for _, child := range node.Content {
+			errs = append(errs, interpolateNode(child, resolve))
+		}
+		return errors.Join(errs...)
+	}
+}
@@ -1,25 +1,64 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/migrations"
 )
 
 // DB holds the database connection
 var DB *gorm.DB
 
-// Connect establishes a connection to the PostgreSQL database
+// PoolConfig sizes the connection pool. Zero lifetimes keep connections open indefinitely.
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DefaultPoolConfig returns the pool used when none is configured
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		MaxOpenConns:    25,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 5 * time.Minute,
+		ConnMaxIdleTime: 10 * time.Minute,
+	}
+}
+
+// Connect establishes a connection to the PostgreSQL database at databaseURL, or to a SQLite
+// database for a sqlite:// URL. SQLite databases are created and migrated as needed.
 func Connect(databaseURL string) (*gorm.DB, error) {
-	// Configure GORM logger
-	gormLogger := logger.Default.LogMode(logger.Info)
+	return ConnectWithLogger(databaseURL, logger.Default.LogMode(logger.Info))
+}
+
+// ConnectWithLogger is like Connect but logs queries, migrations included, to gormLogger
+func ConnectWithLogger(databaseURL string, gormLogger logger.Interface) (*gorm.DB, error) {
+	return ConnectWithPool(databaseURL, gormLogger, DefaultPoolConfig())
+}
+
+// ConnectWithPool is like ConnectWithLogger but sizes the connection pool with pool
+func ConnectWithPool(databaseURL string, gormLogger logger.Interface, pool PoolConfig) (*gorm.DB, error) {
+	dialector := postgres.Open(databaseURL)
+	if IsSQLiteURL(databaseURL) {
+		dsn, err := sqliteDSN(databaseURL)
+		if err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(dsn)
+	}
 
 	// Open database connection
-	db, err := gorm.Open(postgres.Open(databaseURL), &gorm.Config{
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: gormLogger,
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
@@ -36,10 +75,16 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 	}
 
 	// Configure connection pool
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetMaxIdleConns(5)
-	sqlDB.SetConnMaxLifetime(5 * time.Minute)
-	sqlDB.SetConnMaxIdleTime(10 * time.Minute)
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	if isSQLiteMemory(databaseURL) {
+		// Every connection to :memory: would open a separate, empty database
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
 
 	// Test connection with retry logic
 	maxRetries := 5
@@ -49,6 +94,16 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 		err = sqlDB.Ping()
 		if err == nil {
 			log.Println("Successfully connected to database")
+			if IsSQLiteURL(databaseURL) {
+				applied, err := MigrateSQLite(context.Background(), db, migrations.SQLite)
+				if err != nil {
+					_ = sqlDB.Close()
+					return nil, err
+				}
+				if applied > 0 {
+					log.Printf("Applied %d SQLite migration(s)", applied)
+				}
+			}
 			DB = db
 			return db, nil
 		}
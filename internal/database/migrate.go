@@ -0,0 +1,105 @@
+package database
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// migrationPattern matches the golang-migrate file names of an up migration
+var migrationPattern = regexp.MustCompile(`^(\d+)_\w+\.up\.sql$`)
+
+type migrationFile struct {
+	version uint64
+	name    string
+}
+
+// listMigrations lists the up migrations in fsys ordered by version
+func listMigrations(fsys fs.FS) ([]migrationFile, error) {
+	var migrations []migrationFile
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		match := migrationPattern.FindStringSubmatch(d.Name())
+		if match == nil {
+			return nil
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid migration version in %s: %w", path, err)
+		}
+		migrations = append(migrations, migrationFile{version: version, name: path})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].version)
+		}
+	}
+	return migrations, nil
+}
+
+// applyMigrations runs the migrations newer than the version recorded in tx's
+// schema_migrations table and records the new version, returning how many were applied. The
+// table has the layout the migrate CLI uses. tx should be a transaction, so a failed
+// migration leaves the database unchanged.
+func applyMigrations(tx *gorm.DB, fsys fs.FS, migrations []migrationFile) (int, error) {
+	err := tx.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)").Error
+	if err != nil {
+		return 0, err
+	}
+
+	var current []uint64
+	if err := tx.Raw("SELECT version FROM schema_migrations").Scan(&current).Error; err != nil {
+		return 0, err
+	}
+	var version uint64
+	if len(current) > 0 {
+		version = current[0]
+	}
+
+	applied := 0
+	for _, migration := range migrations {
+		if migration.version <= version {
+			continue
+		}
+		script, err := fs.ReadFile(fsys, migration.name)
+		if err != nil {
+			return 0, err
+		}
+		if strings.TrimSpace(string(script)) != "" {
+			if err := tx.Exec(string(script)).Error; err != nil {
+				return 0, fmt.Errorf("migration %s failed: %w", migration.name, err)
+			}
+		}
+		applied++
+		version = migration.version
+	}
+
+	if applied == 0 {
+		return 0, nil
+	}
+	if err := tx.Exec("DELETE FROM schema_migrations").Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (?, false)", version).Error; err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/migrations"
+)
+
+func TestListMigrations(t *testing.T) {
+	t.Run("orders up migrations by version", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"tenant/000002_add_column.up.sql":      {Data: []byte("ALTER TABLE portfolios ADD COLUMN x INT;")},
+			"tenant/000002_add_column.down.sql":    {Data: []byte("ALTER TABLE portfolios DROP COLUMN x;")},
+			"tenant/000001_create_tables.up.sql":   {Data: []byte("CREATE TABLE portfolios (id INT);")},
+			"tenant/000001_create_tables.down.sql": {Data: []byte("DROP TABLE portfolios;")},
+			"tenant/README.md":                     {Data: []byte("notes")},
+		}
+
+		found, err := listMigrations(fsys)
+		require.NoError(t, err)
+		require.Len(t, found, 2)
+		assert.Equal(t, uint64(1), found[0].version)
+		assert.Equal(t, "tenant/000001_create_tables.up.sql", found[0].name)
+		assert.Equal(t, uint64(2), found[1].version)
+	})
+
+	t.Run("rejects duplicate versions", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"000001_a.up.sql": {Data: []byte("SELECT 1;")},
+			"000001_b.up.sql": {Data: []byte("SELECT 1;")},
+		}
+
+		_, err := listMigrations(fsys)
+		assert.Error(t, err)
+	})
+
+	t.Run("embedded tenant migrations", func(t *testing.T) {
+		found, err := listMigrations(migrations.Tenant)
+		require.NoError(t, err)
+		require.NotEmpty(t, found)
+		assert.Equal(t, uint64(1), found[0].version)
+	})
+
+	t.Run("embedded SQLite migrations", func(t *testing.T) {
+		found, err := listMigrations(migrations.SQLite)
+		require.NoError(t, err)
+		require.NotEmpty(t, found)
+		assert.Equal(t, uint64(1), found[0].version)
+	})
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type readReplicaContextKey struct{}
+
+// ContextWithReadReplica returns a copy of ctx whose queries may be served by the read
+// replica. It has no effect unless UseReadReplica was called on the connection.
+func ContextWithReadReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readReplicaContextKey{}, true)
+}
+
+// UsesReadReplica returns true if ctx was marked with ContextWithReadReplica
+func UsesReadReplica(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	replica, _ := ctx.Value(readReplicaContextKey{}).(bool)
+	return replica
+}
+
+const (
+	readReplicaCallback         = "portfolios:read_replica"
+	readReplicaFallbackCallback = "portfolios:read_replica_fallback"
+	readReplicaPrimaryKey       = "portfolios:read_replica_primary"
+
+	// replicaRetryInterval is how long reads go to the primary after the replica fails
+	replicaRetryInterval = 30 * time.Second
+	// replicaPingTimeout bounds the ping that tells a failed query from a failed replica
+	replicaPingTimeout = 2 * time.Second
+)
+
+// readReplica routes reads to a replica's connection pool until it stops answering
+type readReplica struct {
+	pool *sql.DB
+	// downUntil is when, in Unix nanoseconds, the replica may be tried again after failing
+	downUntil atomic.Int64
+	now       func() time.Time
+}
+
+// OpenReadReplica opens a connection pool to the replica at replicaURL, sized with pool. The
+// replica isn't contacted until it serves a query.
+func OpenReadReplica(replicaURL string, pool PoolConfig) (*sql.DB, error) {
+	dialector := postgres.Open(replicaURL)
+	if IsSQLiteURL(replicaURL) {
+		dsn, err := sqliteDSN(replicaURL)
+		if err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(dsn)
+	}
+
+	replica, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logger.Discard,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open read replica: %w", err)
+	}
+	sqlDB, err := replica.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get read replica instance: %w", err)
+	}
+
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	return sqlDB, nil
+}
+
+// UseReadReplica registers callbacks that send queries whose context was marked with
+// ContextWithReadReplica to replica. Writes, transactions and locking reads always use the
+// primary. When the replica stops answering, the failed query is retried on the primary,
+// which serves every read for a while before the replica is tried again.
+func UseReadReplica(db *gorm.DB, replica *sql.DB) error {
+	r := &readReplica{pool: replica, now: time.Now}
+	callbacks := db.Callback()
+	query := callbacks.Query().Get("gorm:query")
+	row := callbacks.Row().Get("gorm:row")
+	registrations := []error{
+		callbacks.Query().Before("gorm:query").Register(readReplicaCallback, r.route),
+		callbacks.Query().After("gorm:query").Register(readReplicaFallbackCallback, r.fallback(query)),
+		callbacks.Row().Before("gorm:row").Register(readReplicaCallback, r.route),
+		callbacks.Row().After("gorm:row").Register(readReplicaFallbackCallback, r.fallback(row)),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return fmt.Errorf("failed to register read replica callback: %w", err)
+		}
+	}
+	return nil
+}
+
+// route points the statement at the replica if its context allows it and the replica is
+// believed to be up, remembering the primary pool for the fallback
+func (r *readReplica) route(db *gorm.DB) {
+	stmt := db.Statement
+	if !UsesReadReplica(stmt.Context) || r.unavailable() {
+		return
+	}
+	if _, inTransaction := stmt.ConnPool.(gorm.TxCommitter); inTransaction {
+		return
+	}
+	if _, locking := stmt.Clauses["FOR"]; locking {
+		return
+	}
+
+	stmt.Settings.Store(readReplicaPrimaryKey, stmt.ConnPool)
+	stmt.ConnPool = r.pool
+}
+
+// fallback restores the primary pool after a replica query and, if the query failed because
+// the replica is down, marks it unavailable and runs the query again with run on the primary
+func (r *readReplica) fallback(run func(*gorm.DB)) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		stmt := db.Statement
+		primary, routed := stmt.Settings.LoadAndDelete(readReplicaPrimaryKey)
+		if !routed {
+			return
+		}
+		stmt.ConnPool = primary.(gorm.ConnPool)
+
+		if db.Error == nil || errors.Is(db.Error, gorm.ErrRecordNotFound) || stmt.Context.Err() != nil {
+			return
+		}
+		if r.reachable(stmt.Context) {
+			return
+		}
+
+		r.downUntil.Store(r.now().Add(replicaRetryInterval).UnixNano())
+		log.Printf("Read replica unavailable, reading from the primary for %s: %v", replicaRetryInterval, db.Error)
+
+		db.Error = nil
+		db.RowsAffected = 0
+		run(db)
+	}
+}
+
+// unavailable returns true while reads go to the primary after the replica failed
+func (r *readReplica) unavailable() bool {
+	return r.now().UnixNano() < r.downUntil.Load()
+}
+
+// reachable returns true if the replica answers a ping
+func (r *readReplica) reachable(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), replicaPingTimeout)
+	defer cancel()
+	return r.pool.PingContext(ctx) == nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// connectWithReplica connects to a SQLite primary and replica, each holding one user whose
+// email names the database it's in
+func connectWithReplica(t *testing.T) (*gorm.DB, *sql.DB) {
+	t.Helper()
+	t.Cleanup(func() { DB = nil })
+	dir := t.TempDir()
+
+	open := func(name string) *gorm.DB {
+		db, err := ConnectWithLogger("sqlite://"+filepath.Join(dir, name+".db"), logger.Discard)
+		require.NoError(t, err)
+		require.NoError(t, db.Exec(
+			"INSERT INTO users (id, email, password_hash) VALUES (?, ?, ?)",
+			"00000000-0000-0000-0000-000000000001", name+"@example.com", "hash",
+		).Error)
+		return db
+	}
+
+	primary := open("primary")
+	replicaDB := open("replica")
+	sqlDB, err := replicaDB.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	replica, err := OpenReadReplica("sqlite://"+filepath.Join(dir, "replica.db"), DefaultPoolConfig())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = replica.Close() })
+	require.NoError(t, UseReadReplica(primary, replica))
+
+	return primary, replica
+}
+
+func TestUseReadReplica(t *testing.T) {
+	db, _ := connectWithReplica(t)
+	replicaCtx := ContextWithReadReplica(context.Background())
+
+	email := func(ctx context.Context) string {
+		var user models.User
+		require.NoError(t, db.WithContext(ctx).First(&user).Error)
+		return user.Email
+	}
+	rawEmail := func(ctx context.Context) string {
+		var email string
+		require.NoError(t, db.WithContext(ctx).Raw("SELECT email FROM users").Scan(&email).Error)
+		return email
+	}
+
+	assert.Equal(t, "primary@example.com", email(context.Background()))
+	assert.Equal(t, "replica@example.com", email(replicaCtx))
+	assert.Equal(t, "primary@example.com", rawEmail(context.Background()))
+	assert.Equal(t, "replica@example.com", rawEmail(replicaCtx))
+
+	t.Run("transactions use the primary", func(t *testing.T) {
+		require.NoError(t, db.WithContext(replicaCtx).Transaction(func(tx *gorm.DB) error {
+			var user models.User
+			require.NoError(t, tx.First(&user).Error)
+			assert.Equal(t, "primary@example.com", user.Email)
+			return nil
+		}))
+	})
+
+	t.Run("missing records aren't retried on the primary", func(t *testing.T) {
+		var user models.User
+		err := db.WithContext(replicaCtx).Where("email = ?", "primary@example.com").First(&user).Error
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func TestUseReadReplica_FallsBackToPrimary(t *testing.T) {
+	db, replica := connectWithReplica(t)
+	replicaCtx := ContextWithReadReplica(context.Background())
+	require.NoError(t, replica.Close())
+
+	var user models.User
+	require.NoError(t, db.WithContext(replicaCtx).First(&user).Error)
+	assert.Equal(t, "primary@example.com", user.Email)
+
+	var email string
+	require.NoError(t, db.WithContext(replicaCtx).Raw("SELECT email FROM users").Scan(&email).Error)
+	assert.Equal(t, "primary@example.com", email)
+}
+
+func TestReadReplica_Unavailable(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	r := &readReplica{now: func() time.Time { return now }}
+	assert.False(t, r.unavailable())
+
+	r.downUntil.Store(now.Add(replicaRetryInterval).UnixNano())
+	assert.True(t, r.unavailable())
+
+	now = now.Add(replicaRetryInterval)
+	assert.False(t, r.unavailable())
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+// slowQueryLogger is a GORM logger that writes queries taking longer than a threshold to the
+// server log, tagged with the ID of the request that ran them, along with failed queries
+type slowQueryLogger struct {
+	log       logger.Logger
+	threshold time.Duration
+	level     gormlogger.LogLevel
+}
+
+// NewSlowQueryLogger creates a GORM logger that logs queries taking threshold or longer to
+// log as warnings. A zero threshold logs no slow queries, only failed ones.
+func NewSlowQueryLogger(log logger.Logger, threshold time.Duration) gormlogger.Interface {
+	return &slowQueryLogger{log: log, threshold: threshold, level: gormlogger.Warn}
+}
+
+// LogMode returns a copy of the logger logging at level
+func (l *slowQueryLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs GORM's informational messages
+func (l *slowQueryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		withRequestID(ctx, l.log.Info()).Msgf(msg, args...)
+	}
+}
+
+// Warn logs GORM's warnings
+func (l *slowQueryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		withRequestID(ctx, l.log.Warn()).Msgf(msg, args...)
+	}
+}
+
+// Error logs GORM's errors
+func (l *slowQueryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		withRequestID(ctx, l.log.Error()).Msgf(msg, args...)
+	}
+}
+
+// Trace logs a query that failed, other than by finding no record, or that took the
+// threshold or longer
+func (l *slowQueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		withRequestID(ctx, l.log.Error()).
+			Err(err).
+			Str("sql", sql).
+			Int64("rows", rows).
+			Dur("duration", elapsed).
+			Msg("Database query failed")
+	case l.threshold > 0 && elapsed >= l.threshold && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		withRequestID(ctx, l.log.Warn()).
+			Str("sql", sql).
+			Int64("rows", rows).
+			Dur("duration", elapsed).
+			Dur("threshold", l.threshold).
+			Msg("Slow database query")
+	}
+}
+
+// withRequestID adds the ID of the request ctx serves to event, if any
+func withRequestID(ctx context.Context, event *zerolog.Event) *zerolog.Event {
+	if requestID := logger.RequestIDFromContext(ctx); requestID != "" {
+		return event.Str("request_id", requestID)
+	}
+	return event
+}
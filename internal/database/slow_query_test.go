@@ -0,0 +1,90 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+func TestConnectWithPool(t *testing.T) {
+	t.Cleanup(func() { DB = nil })
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+
+	databaseURL := "sqlite://" + filepath.Join(t.TempDir(), "portfolios.db")
+	db, err := ConnectWithPool(databaseURL, NewSlowQueryLogger(&log, time.Nanosecond), PoolConfig{
+		MaxOpenConns:    3,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: time.Hour,
+	})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	assert.Equal(t, 3, sqlDB.Stats().MaxOpenConnections)
+
+	buf.Reset()
+	ctx := logger.ContextWithRequestID(context.Background(), "req-123")
+	var count int64
+	require.NoError(t, db.WithContext(ctx).Raw("SELECT COUNT(*) FROM users").Scan(&count).Error)
+
+	assert.Contains(t, buf.String(), `"level":"warn"`)
+	assert.Contains(t, buf.String(), `"request_id":"req-123"`)
+	assert.Contains(t, buf.String(), `"sql":"SELECT COUNT(*) FROM users"`)
+	assert.Contains(t, buf.String(), "Slow database query")
+}
+
+func TestSlowQueryLogger_Trace(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+	query := func() (string, int64) { return "SELECT * FROM portfolios", 2 }
+
+	t.Run("fast queries are not logged", func(t *testing.T) {
+		buf.Reset()
+		NewSlowQueryLogger(&log, time.Second).Trace(context.Background(), time.Now(), query, nil)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("slow queries are logged without a request outside one", func(t *testing.T) {
+		buf.Reset()
+		NewSlowQueryLogger(&log, time.Second).Trace(context.Background(), time.Now().Add(-2*time.Second), query, nil)
+		assert.Contains(t, buf.String(), "Slow database query")
+		assert.Contains(t, buf.String(), `"rows":2`)
+		assert.NotContains(t, buf.String(), "request_id")
+	})
+
+	t.Run("a zero threshold logs no slow queries", func(t *testing.T) {
+		buf.Reset()
+		NewSlowQueryLogger(&log, 0).Trace(context.Background(), time.Now().Add(-time.Hour), query, nil)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("failed queries are logged, missing records aren't", func(t *testing.T) {
+		buf.Reset()
+		slowQueries := NewSlowQueryLogger(&log, time.Second)
+		slowQueries.Trace(context.Background(), time.Now(), query, gorm.ErrRecordNotFound)
+		assert.Empty(t, buf.String())
+
+		slowQueries.Trace(context.Background(), time.Now(), query, assert.AnError)
+		assert.Contains(t, buf.String(), `"level":"error"`)
+		assert.Contains(t, buf.String(), "Database query failed")
+	})
+
+	t.Run("silent", func(t *testing.T) {
+		buf.Reset()
+		NewSlowQueryLogger(&log, time.Second).LogMode(gormlogger.Silent).
+			Trace(context.Background(), time.Now().Add(-2*time.Second), query, assert.AnError)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// sqliteScheme prefixes DATABASE_URL values that select SQLite, as in
+// sqlite:///var/lib/portfolios/portfolios.db or sqlite://:memory:
+const sqliteScheme = "sqlite://"
+
+// sqlitePragmas are applied to every SQLite connection unless the URL sets them. WAL lets
+// readers work alongside a writer, the busy timeout makes a connection wait for a lock
+// instead of failing, and immediate transactions take the write lock up front so two
+// transactions never deadlock upgrading their locks.
+var sqlitePragmas = map[string]string{
+	"_journal_mode": "WAL",
+	"_busy_timeout": "5000",
+	"_foreign_keys": "on",
+	"_synchronous":  "NORMAL",
+	"_txlock":       "immediate",
+}
+
+// IsSQLiteURL returns true if databaseURL selects SQLite rather than PostgreSQL
+func IsSQLiteURL(databaseURL string) bool {
+	return strings.HasPrefix(databaseURL, sqliteScheme)
+}
+
+// sqliteDSN converts a sqlite:// URL to a go-sqlite3 DSN carrying sqlitePragmas. Query
+// parameters in the URL are passed through and override the defaults.
+func sqliteDSN(databaseURL string) (string, error) {
+	path, rawQuery, _ := strings.Cut(strings.TrimPrefix(databaseURL, sqliteScheme), "?")
+	if path == "" {
+		return "", fmt.Errorf("SQLite database URL needs a file path, as in %s/var/lib/portfolios/portfolios.db", sqliteScheme)
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", fmt.Errorf("invalid SQLite database URL parameters: %w", err)
+	}
+	for name, value := range sqlitePragmas {
+		if !query.Has(name) {
+			query.Set(name, value)
+		}
+	}
+
+	return "file:" + path + "?" + query.Encode(), nil
+}
+
+// isSQLiteMemory returns true if databaseURL is an in-memory SQLite database, which only
+// exists for as long as its single connection stays open
+func isSQLiteMemory(databaseURL string) bool {
+	path, _, _ := strings.Cut(strings.TrimPrefix(databaseURL, sqliteScheme), "?")
+	return path == ":memory:"
+}
+
+// MigrateSQLite applies the migrations in fsys that a SQLite database hasn't run yet,
+// returning how many were applied. They run in one immediate transaction, so concurrent
+// callers wait for each other and a failed migration leaves the database unchanged.
+func MigrateSQLite(ctx context.Context, db *gorm.DB, fsys fs.FS) (int, error) {
+	migrations, err := listMigrations(fsys)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list SQLite migrations: %w", err)
+	}
+
+	applied := 0
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		applied, err = applyMigrations(tx, fsys, migrations)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to migrate SQLite database: %w", err)
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,292 @@
+package database
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/migrations"
+)
+
+func TestIsSQLiteURL(t *testing.T) {
+	assert.True(t, IsSQLiteURL("sqlite:///var/lib/portfolios/portfolios.db"))
+	assert.True(t, IsSQLiteURL("sqlite://:memory:"))
+	assert.False(t, IsSQLiteURL("postgres://localhost:5432/portfolios"))
+	assert.False(t, IsSQLiteURL("host=localhost dbname=portfolios"))
+}
+
+func TestSQLiteDSN(t *testing.T) {
+	t.Run("adds the default pragmas", func(t *testing.T) {
+		dsn, err := sqliteDSN("sqlite:///data/portfolios.db")
+		require.NoError(t, err)
+		assert.Equal(t, "file:/data/portfolios.db?_busy_timeout=5000&_foreign_keys=on&_journal_mode=WAL&_synchronous=NORMAL&_txlock=immediate", dsn)
+	})
+
+	t.Run("keeps parameters from the URL", func(t *testing.T) {
+		dsn, err := sqliteDSN("sqlite://portfolios.db?_busy_timeout=10000&cache=private")
+		require.NoError(t, err)
+		assert.Contains(t, dsn, "file:portfolios.db?")
+		assert.Contains(t, dsn, "_busy_timeout=10000")
+		assert.Contains(t, dsn, "cache=private")
+		assert.Contains(t, dsn, "_journal_mode=WAL")
+	})
+
+	t.Run("requires a path", func(t *testing.T) {
+		_, err := sqliteDSN("sqlite://")
+		assert.Error(t, err)
+	})
+}
+
+func TestConnect_SQLite(t *testing.T) {
+	t.Cleanup(func() { DB = nil })
+	databaseURL := "sqlite://" + filepath.Join(t.TempDir(), "portfolios.db")
+
+	db, err := Connect(databaseURL)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+
+	var journalMode string
+	require.NoError(t, db.Raw("PRAGMA journal_mode").Scan(&journalMode).Error)
+	assert.Equal(t, "wal", journalMode)
+
+	var version uint64
+	require.NoError(t, db.Raw("SELECT version FROM schema_migrations").Scan(&version).Error)
+	assert.Equal(t, uint64(31), version)
+
+	t.Run("stores and cascades like Postgres", func(t *testing.T) {
+		user := &models.User{Email: "self-hosted@example.com"}
+		require.NoError(t, user.SetPassword("password123"))
+		require.NoError(t, db.Create(user).Error)
+
+		portfolio := &models.Portfolio{UserID: user.ID, Name: "Home", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+		require.NoError(t, db.Create(portfolio).Error)
+		price := decimal.RequireFromString("123.45")
+		transaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeBuy,
+			Symbol:      "VTI",
+			Quantity:    decimal.RequireFromString("1.5"),
+			Price:       &price,
+		}
+		require.NoError(t, db.Create(transaction).Error)
+
+		var found models.Transaction
+		require.NoError(t, db.First(&found, "id = ?", transaction.ID).Error)
+		assert.True(t, price.Equal(*found.Price))
+
+		require.NoError(t, db.Delete(&models.Portfolio{}, "id = ?", portfolio.ID).Error)
+		var count int64
+		require.NoError(t, db.Model(&models.Transaction{}).Count(&count).Error)
+		assert.Zero(t, count)
+	})
+
+	t.Run("accepts simulation and snapshot backfill jobs", func(t *testing.T) {
+		user := &models.User{Email: "retiree@example.com"}
+		require.NoError(t, user.SetPassword("password123"))
+		require.NoError(t, db.Create(user).Error)
+
+		job := &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeSimulation}
+		require.NoError(t, db.Create(job).Error)
+		backfill := &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeSnapshotBackfill}
+		require.NoError(t, db.Create(backfill).Error)
+		assert.Error(t, db.Exec(`INSERT INTO queued_jobs (id, user_id, type) VALUES ('j1', ?, 'GUESS')`, user.ID).Error)
+	})
+
+	t.Run("keeps one feature flag override for everyone and one per user", func(t *testing.T) {
+		user := &models.User{Email: "beta@example.com"}
+		require.NoError(t, user.SetPassword("password123"))
+		require.NoError(t, db.Create(user).Error)
+
+		require.NoError(t, db.Create(&models.FeatureFlagOverride{Name: models.FeatureSimulations, Enabled: false}).Error)
+		require.NoError(t, db.Create(&models.FeatureFlagOverride{Name: models.FeatureSimulations, UserID: &user.ID, Enabled: true}).Error)
+		assert.Error(t, db.Create(&models.FeatureFlagOverride{Name: models.FeatureSimulations, Enabled: true}).Error)
+		assert.Error(t, db.Create(&models.FeatureFlagOverride{Name: models.FeatureSimulations, UserID: &user.ID, Enabled: false}).Error)
+	})
+
+	t.Run("enforces foreign keys", func(t *testing.T) {
+		orphan := &models.Portfolio{UserID: uuid.New(), Name: "Orphan", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+		assert.Error(t, db.Create(orphan).Error)
+	})
+
+	t.Run("reconnecting applies nothing twice", func(t *testing.T) {
+		again, err := Connect(databaseURL)
+		require.NoError(t, err)
+		sqlDB, _ := again.DB()
+		_ = sqlDB.Close()
+
+		applied, err := MigrateSQLite(context.Background(), db, migrations.SQLite)
+		require.NoError(t, err)
+		assert.Zero(t, applied)
+	})
+}
+
+func TestMigrateSQLite_WidensTransactionTypes(t *testing.T) {
+	dsn, err := sqliteDSN("sqlite://" + filepath.Join(t.TempDir(), "portfolios.db"))
+	require.NoError(t, err)
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+
+	// Migrate to the version before option transactions were allowed
+	migrateSQLiteBefore(t, db, "000005")
+
+	require.NoError(t, db.Exec(`INSERT INTO users (id, email, password_hash) VALUES ('u1', 'lots@example.com', 'x')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO portfolios (id, user_id, name) VALUES ('p1', 'u1', 'Lots')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity, price)
+		VALUES ('t1', 'p1', 'BUY', 'VTI', '2024-01-02', 10, 200)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO tax_lots (id, portfolio_id, symbol, purchase_date, quantity, cost_basis, transaction_id)
+		VALUES ('l1', 'p1', 'VTI', '2024-01-02', 10, 2000, 't1')`).Error)
+
+	_, err = MigrateSQLite(context.Background(), db, migrations.SQLite)
+	require.NoError(t, err)
+
+	var lots int64
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM tax_lots").Scan(&lots).Error)
+	assert.Equal(t, int64(1), lots)
+
+	var multiplier int
+	require.NoError(t, db.Raw("SELECT multiplier FROM transactions WHERE id = 't1'").Scan(&multiplier).Error)
+	assert.Equal(t, 1, multiplier)
+
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity, price, asset_type, multiplier)
+		VALUES ('t2', 'p1', 'BUY_TO_OPEN', 'VTI240621C00250000', '2024-01-02', 1, 3.5, 'OPTION', 100)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity)
+		VALUES ('t4', 'p1', 'RETURN_OF_CAPITAL', 'VTI', '2024-03-01', 12.5)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO corporate_actions (id, symbol, type, date, amount)
+		VALUES ('c1', 'VTI', 'RETURN_OF_CAPITAL', '2024-03-01', 1.25)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity)
+		VALUES ('t5', 'p1', 'STOCK_DIVIDEND', 'VTI', '2024-04-01', 0.5)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO corporate_actions (id, symbol, type, date, ratio)
+		VALUES ('c2', 'VTI', 'STOCK_DIVIDEND', '2024-04-01', 0.05)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity)
+		VALUES ('t6', 'p1', 'ADR_FEE', 'VTI', '2024-05-01', 0.75)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity, category)
+		VALUES ('t7', 'p1', 'LENDING_INCOME', 'VTI', '2024-06-01', 1.5, 'FULLY_PAID_LENDING')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity)
+		VALUES ('t8', 'p1', 'DEPOSIT', 'USD', '2024-07-01', 5000)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity, price)
+		VALUES ('t9', 'p1', 'OPENING_BALANCE', 'VTI', '2019-03-01', 100, 50)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity, category)
+		VALUES ('t10', 'p1', 'CAPITAL_GAIN_DISTRIBUTION', 'VTI', '2024-12-20', 42.5, 'LONG_TERM')`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO corporate_actions (id, symbol, type, date, amount)
+		VALUES ('c3', 'VTI', 'CAPITAL_GAIN_DISTRIBUTION', '2024-12-20', 0.425)`).Error)
+	assert.Error(t, db.Exec(`INSERT INTO transactions (id, portfolio_id, type, symbol, date, quantity, price)
+		VALUES ('t3', 'p1', 'WRITE', 'VTI', '2024-01-02', 1, 3.5)`).Error)
+
+	// Tax lots still reference transactions and cascade from it
+	require.NoError(t, db.Exec("DELETE FROM transactions WHERE id = 't1'").Error)
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM tax_lots").Scan(&lots).Error)
+	assert.Zero(t, lots)
+}
+
+func TestMigrateSQLite_DedupesDailySnapshots(t *testing.T) {
+	dsn, err := sqliteDSN("sqlite://" + filepath.Join(t.TempDir(), "portfolios.db"))
+	require.NoError(t, err)
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := db.DB()
+		_ = sqlDB.Close()
+	})
+
+	// Migrate to the version before snapshots were unique per day
+	migrateSQLiteBefore(t, db, "000019")
+
+	user := &models.User{Email: "daily@example.com"}
+	require.NoError(t, user.SetPassword("password123"))
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Daily", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	// Portfolios had no organization before 000020 and no tax regime before 000030
+	require.NoError(t, db.Omit("OrganizationID", "TaxRegime").Create(portfolio).Error)
+
+	snapshotAt := func(date time.Time, value int64) *models.PerformanceSnapshot {
+		snapshot := &models.PerformanceSnapshot{
+			PortfolioID: portfolio.ID, Date: date, TotalValue: decimal.NewFromInt(value),
+			TotalCostBasis: decimal.NewFromInt(100), TotalReturn: decimal.Zero, TotalReturnPct: decimal.Zero,
+		}
+		// Snapshots had no gain breakdown before 000024
+		require.NoError(t, db.Omit("RealizedGain", "UnrealizedGain", "DividendIncome", "Fees").Create(snapshot).Error)
+		return snapshot
+	}
+	day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+	snapshotAt(day.Add(1*time.Hour), 101)
+	evening := snapshotAt(day.Add(21*time.Hour), 102)
+	nextDay := snapshotAt(day.Add(25*time.Hour), 103)
+
+	_, err = MigrateSQLite(context.Background(), db, migrations.SQLite)
+	require.NoError(t, err)
+
+	var ids []string
+	require.NoError(t, db.Raw("SELECT id FROM performance_snapshots ORDER BY date").Scan(&ids).Error)
+	assert.Equal(t, []string{evening.ID.String(), nextDay.ID.String()}, ids)
+
+	// Another snapshot the same day is now rejected
+	duplicate := &models.PerformanceSnapshot{
+		PortfolioID: portfolio.ID, Date: day.Add(23 * time.Hour), TotalValue: decimal.NewFromInt(104),
+		TotalCostBasis: decimal.NewFromInt(100), TotalReturn: decimal.Zero, TotalReturnPct: decimal.Zero,
+	}
+	assert.Error(t, db.Create(duplicate).Error)
+}
+
+func TestMigrateSQLite_FailedMigrationRollsBack(t *testing.T) {
+	t.Cleanup(func() { DB = nil })
+	db, err := Connect("sqlite://:memory:")
+	require.NoError(t, err)
+
+	// Numbered after the embedded migrations, which Connect has already applied
+	fsys := fstest.MapFS{
+		"000098_add_table.up.sql": {Data: []byte("CREATE TABLE extras (id TEXT PRIMARY KEY);")},
+		"000099_broken.up.sql":    {Data: []byte("ALTER TABLE missing ADD COLUMN x INT;")},
+	}
+	_, err = MigrateSQLite(context.Background(), db, fsys)
+	require.Error(t, err)
+
+	var tables int64
+	require.NoError(t, db.Raw("SELECT COUNT(*) FROM sqlite_master WHERE name = 'extras'").Scan(&tables).Error)
+	assert.Zero(t, tables)
+}
+
+func TestMigrateTenantSchema_RequiresPostgres(t *testing.T) {
+	t.Cleanup(func() { DB = nil })
+	db, err := Connect("sqlite://:memory:")
+	require.NoError(t, err)
+
+	_, err = MigrateTenantSchema(context.Background(), db, "tenant_acme", migrations.Tenant)
+	assert.ErrorIs(t, err, ErrTenantSchemasUnsupported)
+}
+
+// migrateSQLiteBefore applies the embedded SQLite migrations numbered before version
+func migrateSQLiteBefore(t *testing.T, db *gorm.DB, version string) {
+	t.Helper()
+
+	before := fstest.MapFS{}
+	entries, err := fs.ReadDir(migrations.SQLite, "sqlite")
+	require.NoError(t, err)
+	for _, entry := range entries {
+		if entry.Name() < version && strings.HasSuffix(entry.Name(), ".up.sql") {
+			data, err := fs.ReadFile(migrations.SQLite, "sqlite/"+entry.Name())
+			require.NoError(t, err)
+			before[entry.Name()] = &fstest.MapFile{Data: data}
+		}
+	}
+	_, err = MigrateSQLite(context.Background(), db, before)
+	require.NoError(t, err)
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// tenantTables are the tables that hold an organization's portfolio data. In multi-schema
+// mode each tenant schema has its own copy of them; every other table (users, organizations,
+// API keys, corporate actions) is shared in the public schema.
+var tenantTables = map[string]bool{
+	"portfolios":                     true,
+	"transactions":                   true,
+	"holdings":                       true,
+	"tax_lots":                       true,
+	"performance_snapshots":          true,
+	"performance_metrics_cache":      true,
+	"portfolio_actions":              true,
+	"stock_plan_grants":              true,
+	"stock_plan_events":              true,
+	"employer_stock_policies":        true,
+	"blackout_windows":               true,
+	"blackout_overrides":             true,
+	"rebalance_plans":                true,
+	"rebalance_plan_trades":          true,
+	"option_contracts":               true,
+	"peer_benchmarks":                true,
+	"report_subscriptions":           true,
+	"report_subscription_portfolios": true,
+	"tags":                           true,
+	"portfolio_tags":                 true,
+	"transaction_tags":               true,
+	"portfolio_groups":               true,
+	"portfolio_group_portfolios":     true,
+	"simulations":                    true,
+	"snapshot_backfill_checkpoints":  true,
+	"broker_connections":             true,
+}
+
+// IsTenantTable returns true if table is kept in each tenant schema in multi-schema mode
+func IsTenantTable(table string) bool {
+	return tenantTables[table]
+}
+
+type schemaContextKey struct{}
+
+// WithSchema returns a copy of ctx whose queries on tenant tables use schema. It has no
+// effect unless UseTenantSchemas was called on the connection.
+func WithSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, schemaContextKey{}, schema)
+}
+
+// SchemaFromContext returns the tenant schema set with WithSchema, or "" for the public schema
+func SchemaFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	schema, _ := ctx.Value(schemaContextKey{}).(string)
+	return schema
+}
+
+// ErrTenantSchemasUnsupported is returned when tenant schemas are used on a database other
+// than PostgreSQL
+var ErrTenantSchemasUnsupported = errors.New("tenant schemas require PostgreSQL")
+
+const tenantSchemaCallback = "portfolios:tenant_schema"
+
+// UseTenantSchemas registers callbacks that qualify tenant tables with the schema carried by
+// each statement's context. Statements without a schema keep using the public schema.
+func UseTenantSchemas(db *gorm.DB) error {
+	callbacks := db.Callback()
+	registrations := []error{
+		callbacks.Create().Before("gorm:create").Register(tenantSchemaCallback, qualifyTenantTable),
+		callbacks.Query().Before("gorm:query").Register(tenantSchemaCallback, qualifyTenantTable),
+		callbacks.Update().Before("gorm:update").Register(tenantSchemaCallback, qualifyTenantTable),
+		callbacks.Delete().Before("gorm:delete").Register(tenantSchemaCallback, qualifyTenantTable),
+		callbacks.Row().Before("gorm:row").Register(tenantSchemaCallback, qualifyTenantTable),
+	}
+	for _, err := range registrations {
+		if err != nil {
+			return fmt.Errorf("failed to register tenant schema callback: %w", err)
+		}
+	}
+	return nil
+}
+
+// TableName returns table qualified with ctx's schema if it is a tenant table. The tenant
+// callbacks only qualify a statement's own table, so raw SQL naming other tables, such as a
+// join, must name them with TableName.
+func TableName(ctx context.Context, table string) string {
+	if !tenantTables[table] {
+		return table
+	}
+	if schema := SchemaFromContext(ctx); schema != "" {
+		return schema + "." + table
+	}
+	return table
+}
+
+// qualifyTenantTable prefixes the statement's table with the context's schema. A qualified
+// name is not a tenant table name, so running it twice on a statement is harmless.
+func qualifyTenantTable(db *gorm.DB) {
+	stmt := db.Statement
+	if stmt.TableExpr != nil {
+		return
+	}
+	stmt.Table = TableName(stmt.Context, stmt.Table)
+}
+
+// MigrateTenantSchema creates schema if needed and applies the up migrations in fsys that it
+// hasn't run yet, returning how many were applied. Applied versions are recorded in the
+// schema's own schema_migrations table, in the same layout the migrate CLI uses, so a schema
+// can also be inspected or repaired with it. Everything runs in one transaction holding an
+// advisory lock on the schema, so concurrent runs don't apply a migration twice and a
+// failed migration leaves the schema unchanged.
+func MigrateTenantSchema(ctx context.Context, db *gorm.DB, schema string, fsys fs.FS) (int, error) {
+	if !models.IsValidSchemaName(schema) {
+		return 0, fmt.Errorf("invalid tenant schema name %q", schema)
+	}
+	if db.Dialector.Name() != "postgres" {
+		return 0, ErrTenantSchemasUnsupported
+	}
+
+	migrations, err := listMigrations(fsys)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list tenant migrations: %w", err)
+	}
+
+	applied := 0
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		quoted := `"` + schema + `"`
+		statements := []string{
+			"SELECT pg_advisory_xact_lock(hashtext('" + schema + "'))",
+			"CREATE SCHEMA IF NOT EXISTS " + quoted,
+			"SET LOCAL search_path TO " + quoted + ", public",
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		applied, err = applyMigrations(tx, fsys, migrations)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to migrate schema %s: %w", schema, err)
+	}
+
+	return applied, nil
+}
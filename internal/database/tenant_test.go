@@ -0,0 +1,141 @@
+package database
+
+import (
+	"context"
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/migrations"
+)
+
+// setupTenantTestDB creates a SQLite database with an attached database standing in for a
+// tenant schema. A single connection keeps the attachment visible to every query.
+func setupTenantTestDB(t *testing.T) (*gorm.DB, *models.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}))
+	require.NoError(t, db.Exec("ATTACH DATABASE ':memory:' AS tenant_acme").Error)
+
+	// Copy the portfolios table definition so column types survive
+	var ddl string
+	require.NoError(t, db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = 'portfolios'").Scan(&ddl).Error)
+	ddl = strings.Replace(ddl, "CREATE TABLE `portfolios`", "CREATE TABLE `tenant_acme`.`portfolios`", 1)
+	require.NoError(t, db.Exec(ddl).Error)
+	require.NoError(t, UseTenantSchemas(db))
+
+	user := &models.User{Email: "test@example.com"}
+	require.NoError(t, user.SetPassword("password123"))
+	require.NoError(t, db.Create(user).Error)
+
+	return db, user
+}
+
+func TestSchemaFromContext(t *testing.T) {
+	assert.Equal(t, "", SchemaFromContext(context.Background()))
+	assert.Equal(t, "tenant_acme", SchemaFromContext(WithSchema(context.Background(), "tenant_acme")))
+}
+
+func TestTableName(t *testing.T) {
+	tenantCtx := WithSchema(context.Background(), "tenant_acme")
+	assert.Equal(t, "tenant_acme.portfolios", TableName(tenantCtx, "portfolios"))
+	assert.Equal(t, "users", TableName(tenantCtx, "users"))
+	assert.Equal(t, "portfolios", TableName(context.Background(), "portfolios"))
+}
+
+func TestIsTenantTable(t *testing.T) {
+	assert.True(t, IsTenantTable("portfolios"))
+	assert.True(t, IsTenantTable("peer_benchmarks"))
+	assert.True(t, IsTenantTable("report_subscriptions"))
+	assert.True(t, IsTenantTable("report_subscription_portfolios"))
+	assert.False(t, IsTenantTable("users"))
+	assert.False(t, IsTenantTable("corporate_actions"))
+}
+
+func TestIsTenantTable_TenantMigrations(t *testing.T) {
+	createTable := regexp.MustCompile(`(?i)CREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+
+	files, err := fs.Glob(migrations.Tenant, "tenant/*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	tables := 0
+	for _, file := range files {
+		content, err := fs.ReadFile(migrations.Tenant, file)
+		require.NoError(t, err)
+		for _, match := range createTable.FindAllStringSubmatch(string(content), -1) {
+			assert.True(t, IsTenantTable(match[1]), "%s creates %s, which is not a tenant table", file, match[1])
+			tables++
+		}
+	}
+	assert.Equal(t, len(tenantTables), tables)
+}
+
+func TestUseTenantSchemas(t *testing.T) {
+	db, user := setupTenantTestDB(t)
+	tenantCtx := WithSchema(context.Background(), "tenant_acme")
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Tenant Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.WithContext(tenantCtx).Create(portfolio).Error)
+
+	t.Run("queries with a schema use the tenant table", func(t *testing.T) {
+		var found models.Portfolio
+		err := db.WithContext(tenantCtx).Where("id = ?", portfolio.ID).First(&found).Error
+		require.NoError(t, err)
+		assert.Equal(t, "Tenant Portfolio", found.Name)
+	})
+
+	t.Run("queries without a schema use the public table", func(t *testing.T) {
+		var count int64
+		require.NoError(t, db.Model(&models.Portfolio{}).Count(&count).Error)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("shared tables ignore the schema", func(t *testing.T) {
+		var found models.User
+		err := db.WithContext(tenantCtx).Where("id = ?", user.ID).First(&found).Error
+		require.NoError(t, err)
+		assert.Equal(t, user.Email, found.Email)
+	})
+
+	t.Run("updates and deletes use the tenant table", func(t *testing.T) {
+		err := db.WithContext(tenantCtx).Model(&models.Portfolio{}).
+			Where("id = ?", portfolio.ID).Update("name", "Renamed").Error
+		require.NoError(t, err)
+
+		var name string
+		require.NoError(t, db.Raw("SELECT name FROM tenant_acme.portfolios WHERE id = ?", portfolio.ID).Scan(&name).Error)
+		assert.Equal(t, "Renamed", name)
+
+		require.NoError(t, db.WithContext(tenantCtx).Delete(&models.Portfolio{}, "id = ?", portfolio.ID).Error)
+
+		var count int64
+		require.NoError(t, db.WithContext(tenantCtx).Model(&models.Portfolio{}).Count(&count).Error)
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func TestMigrateTenantSchema_InvalidName(t *testing.T) {
+	for _, schema := range []string{"", "public; DROP TABLE users", "pg_catalog", "Tenant"} {
+		_, err := MigrateTenantSchema(context.Background(), nil, schema, migrations.Tenant)
+		assert.Error(t, err, schema)
+	}
+	assert.True(t, models.IsValidSchemaName(models.TenantSchemaName(uuid.New())))
+}
@@ -0,0 +1,188 @@
+// Package devdata seeds a database with realistic fake users and portfolios, for trying the
+// UI and API in development. The same options always seed the same users, portfolios and
+// trades.
+package devdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/loadtest"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+const (
+	// DefaultUsers is how many users are seeded
+	DefaultUsers = 5
+	// DefaultPortfolios is how many portfolios each user gets
+	DefaultPortfolios = 3
+	// DefaultTransactions is how many transactions each portfolio has
+	DefaultTransactions = 1000
+	// DefaultYears is how many years of history each portfolio has
+	DefaultYears = 5
+
+	// Password is every seeded user's password
+	Password = "Seed1234"
+
+	// symbolsPerPortfolio is how many of the tickers a portfolio trades
+	symbolsPerPortfolio = 8
+)
+
+var (
+	firstNames = []string{
+		"Ada", "Alan", "Barbara", "Claude", "Dennis", "Edsger", "Frances", "Grace", "Hedy", "Ivan",
+		"Joan", "Ken", "Katherine", "Linus", "Margaret", "Niklaus", "Radia", "Shafi", "Tim", "Whitfield",
+	}
+	lastNames = []string{
+		"Allen", "Backus", "Cerf", "Dijkstra", "Engelbart", "Floyd", "Goldwasser", "Hopper", "Hamilton", "Johnson",
+		"Knuth", "Lamarr", "Liskov", "Lovelace", "McCarthy", "Perlman", "Ritchie", "Shannon", "Turing", "Wirth",
+	}
+	portfolioNames = []string{
+		"Retirement", "Brokerage", "Roth IRA", "College Fund", "Dividend Income", "Growth", "Index Funds",
+		"Tech Picks", "Rainy Day", "House Down Payment",
+	}
+	tickers = []string{
+		"AAPL", "MSFT", "GOOGL", "AMZN", "NVDA", "META", "TSLA", "BRK.B", "JPM", "V",
+		"JNJ", "WMT", "PG", "XOM", "KO", "PEP", "COST", "DIS", "SPY", "VTI",
+		"QQQ", "VXUS", "BND", "SCHD",
+	}
+)
+
+// Options describes what Seed creates
+type Options struct {
+	Users        int
+	Portfolios   int
+	Transactions int
+	Years        int
+	// EndDate is the last day of history, today when zero
+	EndDate time.Time
+	// Seed picks the users' names, their portfolios' tickers and the trades
+	Seed uint64
+}
+
+// DefaultOptions returns the options of a small but realistic development database
+func DefaultOptions() Options {
+	return Options{
+		Users:        DefaultUsers,
+		Portfolios:   DefaultPortfolios,
+		Transactions: DefaultTransactions,
+		Years:        DefaultYears,
+		Seed:         1,
+	}
+}
+
+// Validate checks the options can be seeded
+func (o Options) Validate() error {
+	if o.Users < 1 || o.Users > len(firstNames)*len(lastNames) {
+		return fmt.Errorf("users must be between 1 and %d, got %d", len(firstNames)*len(lastNames), o.Users)
+	}
+	if o.Portfolios < 1 || o.Portfolios > len(portfolioNames) {
+		return fmt.Errorf("portfolios must be between 1 and %d, got %d", len(portfolioNames), o.Portfolios)
+	}
+	if o.Transactions < 1 {
+		return fmt.Errorf("transactions must be at least 1, got %d", o.Transactions)
+	}
+	if o.Years < 1 {
+		return fmt.Errorf("years must be at least 1, got %d", o.Years)
+	}
+	return nil
+}
+
+// SeededUser is a seeded user and their portfolios. A user who already existed is skipped
+// and has no portfolios.
+type SeededUser struct {
+	User       *models.User           `json:"user"`
+	Skipped    bool                   `json:"skipped"`
+	Portfolios []*loadtest.SeedResult `json:"portfolios,omitempty"`
+}
+
+// Result describes what Seed created
+type Result struct {
+	Users    []*SeededUser `json:"users"`
+	Password string        `json:"password"`
+}
+
+// Seed creates the options' users, all with Password, each with portfolios of transactions,
+// tax lots, holdings and daily snapshots. Users whose email is already taken are skipped, so
+// seeding again with the same options adds nothing. Everything is written in one database
+// transaction.
+func Seed(ctx context.Context, db *gorm.DB, opts Options) (*Result, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Hashing is slow on purpose, so every user shares one hash
+	var hashed models.User
+	if err := hashed.SetPassword(Password); err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x5851f42d4c957f2d))
+	result := &Result{Password: Password}
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, email := range userEmails(rng, opts.Users) {
+			seeded := &SeededUser{}
+			result.Users = append(result.Users, seeded)
+
+			var existing models.User
+			err := tx.Where("email = ?", email).First(&existing).Error
+			if err == nil {
+				seeded.User = &existing
+				seeded.Skipped = true
+				continue
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to find user %s: %w", email, err)
+			}
+
+			seeded.User = &models.User{Email: email, PasswordHash: hashed.PasswordHash}
+			if err := tx.Create(seeded.User).Error; err != nil {
+				return fmt.Errorf("failed to create user %s: %w", email, err)
+			}
+
+			// Each user's portfolios have their own seed, so they don't depend on who was skipped
+			userRng := rand.New(rand.NewPCG(opts.Seed, uint64(i)))
+			for _, k := range userRng.Perm(len(portfolioNames))[:opts.Portfolios] {
+				symbols := make([]string, symbolsPerPortfolio)
+				for j, t := range userRng.Perm(len(tickers))[:symbolsPerPortfolio] {
+					symbols[j] = tickers[t]
+				}
+				portfolio, err := loadtest.Seed(ctx, tx, seeded.User.ID, loadtest.SeedOptions{
+					Name:         portfolioNames[k],
+					Transactions: opts.Transactions,
+					Years:        opts.Years,
+					Symbols:      len(symbols),
+					SymbolNames:  symbols,
+					EndDate:      opts.EndDate,
+					Seed:         userRng.Uint64(),
+				})
+				if err != nil {
+					return fmt.Errorf("failed to seed portfolios of %s: %w", email, err)
+				}
+				seeded.Portfolios = append(seeded.Portfolios, portfolio)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// userEmails returns n distinct emails made of random first and last names
+func userEmails(rng *rand.Rand, n int) []string {
+	emails := make([]string, 0, n)
+	for _, k := range rng.Perm(len(firstNames) * len(lastNames))[:n] {
+		first := firstNames[k%len(firstNames)]
+		last := lastNames[k/len(firstNames)]
+		emails = append(emails, strings.ToLower(first+"."+last)+"@example.com")
+	}
+	return emails
+}
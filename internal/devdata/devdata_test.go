@@ -0,0 +1,78 @@
+package devdata
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestSeed(t *testing.T) {
+	db, err := database.ConnectWithLogger("sqlite://:memory:", gormlogger.Discard)
+	require.NoError(t, err)
+
+	opts := Options{
+		Users:        3,
+		Portfolios:   2,
+		Transactions: 100,
+		Years:        1,
+		EndDate:      time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		Seed:         3,
+	}
+	result, err := Seed(context.Background(), db, opts)
+	require.NoError(t, err)
+	require.Len(t, result.Users, 3)
+
+	for _, seeded := range result.Users {
+		assert.False(t, seeded.Skipped)
+		assert.True(t, seeded.User.CheckPassword(Password))
+		require.Len(t, seeded.Portfolios, 2)
+		assert.NotEqual(t, seeded.Portfolios[0].Portfolio.Name, seeded.Portfolios[1].Portfolio.Name)
+		for _, portfolio := range seeded.Portfolios {
+			assert.Equal(t, 100, portfolio.Transactions)
+			assert.Positive(t, portfolio.TaxLots)
+			assert.Positive(t, portfolio.Snapshots)
+		}
+	}
+
+	var portfolios, taxLots int64
+	require.NoError(t, db.Model(&models.Portfolio{}).Count(&portfolios).Error)
+	require.NoError(t, db.Model(&models.TaxLot{}).Count(&taxLots).Error)
+	assert.EqualValues(t, 6, portfolios)
+
+	// Seeding again finds the same users and adds nothing
+	again, err := Seed(context.Background(), db, opts)
+	require.NoError(t, err)
+	for i, seeded := range again.Users {
+		assert.True(t, seeded.Skipped)
+		assert.Equal(t, result.Users[i].User.ID, seeded.User.ID)
+		assert.Empty(t, seeded.Portfolios)
+	}
+	var after int64
+	require.NoError(t, db.Model(&models.TaxLot{}).Count(&after).Error)
+	assert.Equal(t, taxLots, after)
+
+	// Another seed picks other users
+	opts.Seed = 4
+	other, err := Seed(context.Background(), db, opts)
+	require.NoError(t, err)
+	assert.NotEqual(t, result.Users[0].User.Email, other.Users[0].User.Email)
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.NoError(t, DefaultOptions().Validate())
+
+	opts := DefaultOptions()
+	opts.Portfolios = len(portfolioNames) + 1
+	assert.ErrorContains(t, opts.Validate(), "portfolios")
+
+	opts = DefaultOptions()
+	opts.Users = 0
+	assert.ErrorContains(t, opts.Validate(), "users")
+}
@@ -0,0 +1,141 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// UpsertOrganizationRequest represents the desired state of an organization
+type UpsertOrganizationRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=255"`
+}
+
+// SetOrganizationQuotasRequest represents the full set of quotas for an organization.
+// Every quota must be given so that omitting one does not silently keep an old value; zero means unlimited.
+type SetOrganizationQuotasRequest struct {
+	MaxUsers             *int `json:"max_users" binding:"required,min=0"`
+	MaxPortfoliosPerUser *int `json:"max_portfolios_per_user" binding:"required,min=0"`
+	MaxAPIKeys           *int `json:"max_api_keys" binding:"required,min=0"`
+}
+
+// OrganizationResponse represents an organization in admin API responses
+type OrganizationResponse struct {
+	ID                   string    `json:"id"`
+	ExternalID           string    `json:"external_id"`
+	Name                 string    `json:"name"`
+	MaxUsers             int       `json:"max_users"`
+	MaxPortfoliosPerUser int       `json:"max_portfolios_per_user"`
+	MaxAPIKeys           int       `json:"max_api_keys"`
+	SchemaName           *string   `json:"schema_name,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// UpsertOrganizationUserRequest represents the desired state of an organization member
+type UpsertOrganizationUserRequest struct {
+	Email string `json:"email" binding:"required,email,max=255"`
+}
+
+// OrganizationUserResponse represents an organization member in admin API responses
+type OrganizationUserResponse struct {
+	ID              string     `json:"id"`
+	ExternalID      string     `json:"external_id"`
+	Email           string     `json:"email"`
+	InviteSent      bool       `json:"invite_sent,omitempty"`
+	InviteExpiresAt *time.Time `json:"invite_expires_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// UpsertAPIKeyRequest represents the desired state of an API key
+type UpsertAPIKeyRequest struct {
+	UserID string `json:"user_id" binding:"required,min=1,max=255"`
+	Name   string `json:"name,omitempty" binding:"max=255"`
+}
+
+// APIKeyResponse represents an API key in admin API responses. Key is only present in the
+// response that issued it.
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	ExternalID string     `json:"external_id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	Active     bool       `json:"active"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// ToOrganizationResponse converts an Organization model to a response DTO
+func ToOrganizationResponse(organization *models.Organization) *OrganizationResponse {
+	return &OrganizationResponse{
+		ID:                   organization.ID.String(),
+		ExternalID:           organization.ExternalID,
+		Name:                 organization.Name,
+		MaxUsers:             organization.MaxUsers,
+		MaxPortfoliosPerUser: organization.MaxPortfoliosPerUser,
+		MaxAPIKeys:           organization.MaxAPIKeys,
+		SchemaName:           organization.SchemaName,
+		CreatedAt:            organization.CreatedAt,
+		UpdatedAt:            organization.UpdatedAt,
+	}
+}
+
+// ToOrganizationUserResponse converts an organization member to a response DTO
+func ToOrganizationUserResponse(user *models.User) *OrganizationUserResponse {
+	response := &OrganizationUserResponse{
+		ID:        user.ID.String(),
+		Email:     user.Email,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	if user.ExternalID != nil {
+		response.ExternalID = *user.ExternalID
+	}
+	return response
+}
+
+// ToAPIKeyResponse converts an APIKey model to a response DTO. The key's User must be loaded
+// so that the member is reported by its external ID.
+func ToAPIKeyResponse(key *models.APIKey) *APIKeyResponse {
+	response := &APIKeyResponse{
+		ID:         key.ID.String(),
+		ExternalID: key.ExternalID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Active:     key.IsActive(),
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+		UpdatedAt:  key.UpdatedAt,
+	}
+	if key.User != nil && key.User.ExternalID != nil {
+		response.UserID = *key.User.ExternalID
+	}
+	return response
+}
+
+// SetMaintenanceRequest turns maintenance mode on or off, overriding the configuration until
+// it is cleared
+type SetMaintenanceRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Message string `json:"message" binding:"max=500"`
+	// RetryAfterSeconds is sent as Retry-After with rejected requests; omitted, the
+	// configured value is sent
+	RetryAfterSeconds *int `json:"retry_after_seconds" binding:"omitempty,min=0"`
+}
+
+// MaintenanceResponse represents the maintenance mode in effect
+type MaintenanceResponse struct {
+	Enabled           bool      `json:"enabled"`
+	Message           string    `json:"message,omitempty"`
+	RetryAfterSeconds int       `json:"retry_after_seconds"`
+	Since             time.Time `json:"since"`
+	// Source is "config" when the configuration sets the mode and "admin" when the admin
+	// API overrides it
+	Source string `json:"source"`
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // RegisterRequest represents the registration request payload
@@ -40,26 +42,33 @@ type ResetPasswordRequest struct {
 	NewPassword string `json:"new_password" binding:"required,min=8"`
 }
 
+// ChangePasswordRequest represents the change password request payload
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=8"`
+}
+
 // UserResponse represents user data in API responses
 type UserResponse struct {
-	ID          uuid.UUID  `json:"id"`
-	Email       string     `json:"email"`
-	CreatedAt   time.Time  `json:"created_at"`
-	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	ID          uuid.UUID       `json:"id"`
+	Email       string          `json:"email"`
+	Role        models.UserRole `json:"role"`
+	CreatedAt   time.Time       `json:"created_at"`
+	LastLoginAt *time.Time      `json:"last_login_at,omitempty"`
 }
 
 // AuthResponse represents the authentication response with user and tokens
 type AuthResponse struct {
 	User         UserResponse `json:"user"`
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	ExpiresIn    int          `json:"expires_in"` // in seconds
+	AccessToken  string       `json:"access_token,omitempty"`  // Omitted in cookie session mode
+	RefreshToken string       `json:"refresh_token,omitempty"` // Omitted in cookie session mode
+	ExpiresIn    int          `json:"expires_in"`              // in seconds
 }
 
 // RefreshResponse represents the token refresh response
 type RefreshResponse struct {
-	AccessToken string `json:"access_token"`
-	ExpiresIn   int    `json:"expires_in"` // in seconds
+	AccessToken string `json:"access_token,omitempty"` // Omitted in cookie session mode
+	ExpiresIn   int    `json:"expires_in"`             // in seconds
 }
 
 // MessageResponse represents a simple message response
@@ -71,4 +80,45 @@ type MessageResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error"`
 	Code  string `json:"code,omitempty"`
+	// Errors lists each invalid field when a request body or query fails validation
+	Errors []FieldError `json:"errors,omitempty"`
+	// RequestID is added to every error response by the request ID middleware
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// FieldError describes why one field of a request failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ErrorCodeResponse describes one of the codes error responses carry
+type ErrorCodeResponse struct {
+	Code    string `json:"code"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// ErrorCatalogResponse lists every error code the API responds with
+type ErrorCatalogResponse struct {
+	Errors []ErrorCodeResponse `json:"errors"`
+}
+
+// VersionConflictResponse represents a 409 response to an update made against a stale version
+type VersionConflictResponse struct {
+	Error          string `json:"error"`
+	Code           string `json:"code"`
+	CurrentVersion int    `json:"current_version"`
+	RequestID      string `json:"request_id,omitempty"`
+}
+
+// InvalidSymbolResponse represents a 400 response to a transaction recorded for a symbol the
+// market data provider doesn't list
+type InvalidSymbolResponse struct {
+	Error       string   `json:"error"`
+	Code        string   `json:"code"`
+	Symbol      string   `json:"symbol"`
+	Suggestions []string `json:"suggestions"`
+	RequestID   string   `json:"request_id,omitempty"`
 }
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// SetEmployerStockPolicyRequest represents the request to flag a portfolio as holding employer stock
+type SetEmployerStockPolicyRequest struct {
+	Symbol      string                     `json:"symbol" binding:"required,min=1,max=20"`
+	Enforcement models.BlackoutEnforcement `json:"enforcement" binding:"omitempty,oneof=WARN BLOCK"`
+}
+
+// EmployerStockPolicyResponse represents an employer stock policy in API responses
+type EmployerStockPolicyResponse struct {
+	ID          string                     `json:"id"`
+	PortfolioID string                     `json:"portfolio_id"`
+	Symbol      string                     `json:"symbol"`
+	Enforcement models.BlackoutEnforcement `json:"enforcement"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+}
+
+// CreateBlackoutWindowRequest represents the request to add a trading blackout window
+type CreateBlackoutWindowRequest struct {
+	StartDate time.Time `json:"start_date" binding:"required"`
+	EndDate   time.Time `json:"end_date" binding:"required"`
+	Reason    string    `json:"reason,omitempty" binding:"max=500"`
+}
+
+// BlackoutWindowResponse represents a blackout window in API responses
+type BlackoutWindowResponse struct {
+	ID          string    `json:"id"`
+	PortfolioID string    `json:"portfolio_id"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	Reason      string    `json:"reason,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BlackoutOverrideResponse represents an entry in the blackout override audit trail
+type BlackoutOverrideResponse struct {
+	ID              string                     `json:"id"`
+	PortfolioID     string                     `json:"portfolio_id"`
+	WindowID        string                     `json:"window_id"`
+	TransactionID   string                     `json:"transaction_id"`
+	UserID          string                     `json:"user_id"`
+	Symbol          string                     `json:"symbol"`
+	TransactionType models.TransactionType     `json:"transaction_type"`
+	TransactionDate time.Time                  `json:"transaction_date"`
+	Enforcement     models.BlackoutEnforcement `json:"enforcement"`
+	Reason          string                     `json:"reason,omitempty"`
+	CreatedAt       time.Time                  `json:"created_at"`
+}
+
+// ToEmployerStockPolicyResponse converts an EmployerStockPolicy model to a response DTO
+func ToEmployerStockPolicyResponse(policy *models.EmployerStockPolicy) *EmployerStockPolicyResponse {
+	return &EmployerStockPolicyResponse{
+		ID:          policy.ID.String(),
+		PortfolioID: policy.PortfolioID.String(),
+		Symbol:      policy.Symbol,
+		Enforcement: policy.Enforcement,
+		CreatedAt:   policy.CreatedAt,
+		UpdatedAt:   policy.UpdatedAt,
+	}
+}
+
+// ToBlackoutWindowResponse converts a BlackoutWindow model to a response DTO
+func ToBlackoutWindowResponse(window *models.BlackoutWindow) *BlackoutWindowResponse {
+	return &BlackoutWindowResponse{
+		ID:          window.ID.String(),
+		PortfolioID: window.PortfolioID.String(),
+		StartDate:   window.StartDate,
+		EndDate:     window.EndDate,
+		Reason:      window.Reason,
+		CreatedAt:   window.CreatedAt,
+	}
+}
+
+// ToBlackoutOverrideResponse converts a BlackoutOverride model to a response DTO
+func ToBlackoutOverrideResponse(override *models.BlackoutOverride) *BlackoutOverrideResponse {
+	return &BlackoutOverrideResponse{
+		ID:              override.ID.String(),
+		PortfolioID:     override.PortfolioID.String(),
+		WindowID:        override.WindowID.String(),
+		TransactionID:   override.TransactionID.String(),
+		UserID:          override.UserID.String(),
+		Symbol:          override.Symbol,
+		TransactionType: override.TransactionType,
+		TransactionDate: override.TransactionDate,
+		Enforcement:     override.Enforcement,
+		Reason:          override.Reason,
+		CreatedAt:       override.CreatedAt,
+	}
+}
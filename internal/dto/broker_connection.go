@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// CreateBrokerConnectionRequest represents a request to connect a portfolio to a brokerage
+// account. For Plaid, AccessToken is the access token of the Item the account was linked
+// with, exchanged from Plaid Link's public token, and AccountID the account's Plaid ID.
+type CreateBrokerConnectionRequest struct {
+	Provider    models.BrokerProvider `json:"provider" binding:"required,oneof=PLAID"`
+	Name        string                `json:"name,omitempty" binding:"max=100"`
+	AccessToken string                `json:"access_token" binding:"required,max=500"`
+	AccountID   string                `json:"account_id" binding:"required,max=255"`
+	// SyncFrom is the date from which the portfolio's transactions are kept in step with the
+	// broker's; earlier history is left alone (default: today)
+	SyncFrom *time.Time `json:"sync_from,omitempty"`
+}
+
+// SyncBrokerConnectionRequest represents a request to sync a broker connection now
+type SyncBrokerConnectionRequest struct {
+	DryRun bool `json:"dry_run"` // If true, report the differences without importing anything
+}
+
+// BrokerConnectionResponse represents a portfolio's connection to a brokerage account. Its
+// access token is never returned.
+type BrokerConnectionResponse struct {
+	ID            uuid.UUID                     `json:"id"`
+	PortfolioID   uuid.UUID                     `json:"portfolio_id"`
+	Provider      models.BrokerProvider         `json:"provider"`
+	Name          string                        `json:"name"`
+	AccountID     string                        `json:"account_id"`
+	SyncFrom      time.Time                     `json:"sync_from"`
+	Status        models.BrokerConnectionStatus `json:"status"`
+	LastSyncedAt  *time.Time                    `json:"last_synced_at,omitempty"`
+	LastSyncError string                        `json:"last_sync_error,omitempty"`
+	LastSync      *BrokerSyncReport             `json:"last_sync,omitempty"` // Report of the last successful sync
+	CreatedAt     time.Time                     `json:"created_at"`
+}
+
+// BrokerSyncReport reconciles the transactions and positions a broker reports with those
+// of the portfolio. Broker transactions the portfolio lacks are imported, unless the sync
+// is a dry run; differences that need a decision are only reported.
+type BrokerSyncReport struct {
+	ConnectionID uuid.UUID  `json:"connection_id"`
+	SyncedAt     time.Time  `json:"synced_at"`
+	From         time.Time  `json:"from"` // Transactions dated from here up to SyncedAt were reconciled
+	DryRun       bool       `json:"dry_run"`
+	BatchID      *uuid.UUID `json:"batch_id,omitempty"` // Import batch of the imported transactions
+	Matched      int        `json:"matched"`            // Broker transactions the portfolio already has
+	Imported     int        `json:"imported"`
+	Ignored      int        `json:"ignored"` // Broker activity that isn't tracked, such as transfers between accounts
+	// Missing are the broker transactions the portfolio lacked: imported, or in a dry run,
+	// to be imported
+	Missing []BrokerTransactionDiff `json:"missing"`
+	// Mismatched are the portfolio transactions recorded with another quantity or price than
+	// the broker's on the same day
+	Mismatched []BrokerTransactionDiff `json:"mismatched"`
+	// LocalOnly are the portfolio transactions of the kinds the broker reports that it
+	// doesn't have
+	LocalOnly []BrokerTransactionDiff `json:"local_only"`
+	// Positions are the symbols whose quantity held differs from the broker's after the sync
+	Positions []BrokerPositionDiff `json:"positions"`
+	Errors    []ImportError        `json:"errors,omitempty"` // Broker transactions that couldn't be imported
+}
+
+// BrokerTransactionDiff is a transaction found on one side of a broker sync, with its
+// counterpart on the other side if it has one
+type BrokerTransactionDiff struct {
+	Date           time.Time              `json:"date"`
+	Type           models.TransactionType `json:"type"`
+	Symbol         string                 `json:"symbol"`
+	ExternalID     string                 `json:"external_id,omitempty"`    // The broker's transaction ID
+	TransactionID  *uuid.UUID             `json:"transaction_id,omitempty"` // The portfolio's transaction
+	BrokerQuantity *decimal.Decimal       `json:"broker_quantity,omitempty"`
+	BrokerPrice    *decimal.Decimal       `json:"broker_price,omitempty"`
+	LocalQuantity  *decimal.Decimal       `json:"local_quantity,omitempty"`
+	LocalPrice     *decimal.Decimal       `json:"local_price,omitempty"`
+}
+
+// BrokerPositionDiff is a symbol whose quantity held differs between the broker and the
+// portfolio
+type BrokerPositionDiff struct {
+	Symbol         string          `json:"symbol"`
+	BrokerQuantity decimal.Decimal `json:"broker_quantity"`
+	LocalQuantity  decimal.Decimal `json:"local_quantity"`
+	Difference     decimal.Decimal `json:"difference"` // Broker quantity less the portfolio's
+}
+
+// ToBrokerConnectionResponse converts a BrokerConnection model to a response DTO, with the
+// report of its last sync
+func ToBrokerConnectionResponse(connection *models.BrokerConnection) *BrokerConnectionResponse {
+	response := &BrokerConnectionResponse{
+		ID:            connection.ID,
+		PortfolioID:   connection.PortfolioID,
+		Provider:      connection.Provider,
+		Name:          connection.Name,
+		AccountID:     connection.AccountID,
+		SyncFrom:      connection.SyncFrom,
+		Status:        connection.Status,
+		LastSyncedAt:  connection.LastSyncedAt,
+		LastSyncError: connection.LastSyncError,
+		CreatedAt:     connection.CreatedAt,
+	}
+
+	var report BrokerSyncReport
+	if found, err := connection.DecodeSyncReport(&report); err == nil && found {
+		response.LastSync = &report
+	}
+	return response
+}
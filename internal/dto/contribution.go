@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ContributionAnalysisRequest represents the query parameters for splitting a portfolio's
+// growth into contributions and investment returns. The period defaults to the last year.
+type ContributionAnalysisRequest struct {
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+}
+
+// Sources of the contributions a contribution analysis measures
+const (
+	// ContributionSourceCashFlows is the deposit and withdrawal transactions
+	ContributionSourceCashFlows = "CASH_FLOWS"
+	// ContributionSourceTrades is the purchases and sales, standing in for the cash flows of
+	// portfolios that don't record them
+	ContributionSourceTrades = "TRADES"
+)
+
+// ContributionAnalysis splits the change in a portfolio's value over a period into the new
+// money put into it and the growth of its investments. When the portfolio records deposits
+// and withdrawals, its values include the cash they left uninvested and shares acquired
+// through stock plans count as contributions at their cost; otherwise the values are those
+// of its holdings and its purchases and sales are taken as the contributions.
+type ContributionAnalysis struct {
+	PortfolioID   string          `json:"portfolio_id"`
+	StartDate     time.Time       `json:"start_date"`
+	EndDate       time.Time       `json:"end_date"`
+	Source        string          `json:"source"`
+	StartingValue decimal.Decimal `json:"starting_value"`
+	EndingValue   decimal.Decimal `json:"ending_value"`
+	Change        decimal.Decimal `json:"change"`
+	// Contributions flow in after the start date up to and including the end date
+	Contributions    decimal.Decimal `json:"contributions"`
+	Withdrawals      decimal.Decimal `json:"withdrawals"`
+	NetContributions decimal.Decimal `json:"net_contributions"`
+	// InvestmentGrowth is the rest of the change: gains, losses and income net of fees
+	InvestmentGrowth decimal.Decimal `json:"investment_growth"`
+	// The shares are the percentages of the change that came from net contributions and
+	// from investment growth, left out when the value didn't change
+	ContributionShare *decimal.Decimal `json:"contribution_share,omitempty"`
+	GrowthShare       *decimal.Decimal `json:"growth_share,omitempty"`
+}
@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// DashboardRequest represents the query parameters of the dashboard
+type DashboardRequest struct {
+	Currency string `form:"currency" binding:"omitempty,len=3"`
+}
+
+// Dashboard is everything the home screen shows, in one currency: the user's net worth and
+// how it moved today, a card for each portfolio, the held symbols that moved the most, the
+// corporate actions coming up and the latest unread notifications. Warnings say which parts
+// could not be loaded in full.
+type Dashboard struct {
+	Currency            string                      `json:"currency"`
+	NetWorth            decimal.Decimal             `json:"net_worth"`
+	TotalCostBasis      decimal.Decimal             `json:"total_cost_basis"`
+	TotalUnrealizedGain decimal.Decimal             `json:"total_unrealized_gain"`
+	DayChange           decimal.Decimal             `json:"day_change"`
+	DayChangePct        decimal.Decimal             `json:"day_change_pct"`
+	Portfolios          []*DashboardPortfolio       `json:"portfolios"`
+	TopMovers           []*DashboardMover           `json:"top_movers"`
+	UpcomingActions     []*DashboardCorporateAction `json:"upcoming_actions"`
+	Notifications       []*NotificationResponse     `json:"notifications"`
+	UnreadCount         int64                       `json:"unread_count"`
+	Warnings            []string                    `json:"warnings,omitempty"`
+	GeneratedAt         time.Time                   `json:"generated_at"`
+}
+
+// DashboardPortfolio is a portfolio's card on the dashboard. Error says why a portfolio was
+// left out of the totals.
+type DashboardPortfolio struct {
+	PortfolioID    uuid.UUID       `json:"portfolio_id"`
+	Name           string          `json:"name"`
+	BaseCurrency   string          `json:"base_currency"`
+	Value          decimal.Decimal `json:"value"`
+	CostBasis      decimal.Decimal `json:"cost_basis"`
+	UnrealizedGain decimal.Decimal `json:"unrealized_gain"`
+	DayChange      decimal.Decimal `json:"day_change"`
+	DayChangePct   decimal.Decimal `json:"day_change_pct"`
+	Positions      int             `json:"positions"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// DashboardMover is a held symbol's move since the previous close. DayChange is what the
+// move made or lost across all the portfolios holding the symbol.
+type DashboardMover struct {
+	Symbol        string           `json:"symbol"`
+	AssetType     models.AssetType `json:"asset_type"`
+	Price         decimal.Decimal  `json:"price"`
+	Change        decimal.Decimal  `json:"change"`
+	ChangePercent decimal.Decimal  `json:"change_percent"`
+	DayChange     decimal.Decimal  `json:"day_change"`
+}
+
+// DashboardCorporateAction is a corporate action coming up for a portfolio's holding
+type DashboardCorporateAction struct {
+	*CorporateActionCalendarEvent
+	PortfolioID   string `json:"portfolio_id"`
+	PortfolioName string `json:"portfolio_name"`
+}
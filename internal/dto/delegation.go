@@ -0,0 +1,104 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// CreateDelegationRequest represents an advisor's request for access to a client's portfolios
+type CreateDelegationRequest struct {
+	ClientEmail string                  `json:"client_email" binding:"required,email,max=255"`
+	Access      models.DelegationAccess `json:"access" binding:"required,oneof=read manage"`
+}
+
+// ConsentDelegationRequest represents a client's consent to a request for access
+type ConsentDelegationRequest struct {
+	Token        string   `json:"token" binding:"required"`
+	PortfolioIDs []string `json:"portfolio_ids"`
+}
+
+// SetDelegatedPortfoliosRequest represents the request to change which portfolios a client
+// delegates
+type SetDelegatedPortfoliosRequest struct {
+	PortfolioIDs []string `json:"portfolio_ids"`
+}
+
+// DelegationResponse represents a delegation, or a request for access waiting for consent.
+// Token is only present in the response that requested access.
+type DelegationResponse struct {
+	ID           string                  `json:"id"`
+	AdvisorID    string                  `json:"advisor_id"`
+	AdvisorEmail string                  `json:"advisor_email,omitempty"`
+	ClientID     string                  `json:"client_id,omitempty"`
+	ClientEmail  string                  `json:"client_email"`
+	Access       models.DelegationAccess `json:"access"`
+	Status       models.DelegationStatus `json:"status"`
+	PortfolioIDs []string                `json:"portfolio_ids"`
+	Token        string                  `json:"token,omitempty"`
+	EmailSent    *bool                   `json:"email_sent,omitempty"`
+	ExpiresAt    time.Time               `json:"expires_at"`
+	ApprovedAt   *time.Time              `json:"approved_at,omitempty"`
+	RevokedAt    *time.Time              `json:"revoked_at,omitempty"`
+	CreatedAt    time.Time               `json:"created_at"`
+}
+
+// DelegationAuditEventResponse represents a request an advisor made on behalf of a client
+type DelegationAuditEventResponse struct {
+	ID          string    `json:"id"`
+	AdvisorID   string    `json:"advisor_id"`
+	ClientID    string    `json:"client_id"`
+	PortfolioID string    `json:"portfolio_id,omitempty"`
+	Method      string    `json:"method"`
+	Route       string    `json:"route"`
+	Path        string    `json:"path"`
+	Status      int       `json:"status"`
+	RequestID   string    `json:"request_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ToDelegationResponse converts a Delegation model, with its advisor and portfolios, to a
+// response DTO
+func ToDelegationResponse(delegation *models.Delegation) *DelegationResponse {
+	response := &DelegationResponse{
+		ID:           delegation.ID.String(),
+		AdvisorID:    delegation.AdvisorID.String(),
+		ClientEmail:  delegation.ClientEmail,
+		Access:       delegation.Access,
+		Status:       delegation.Status,
+		PortfolioIDs: make([]string, len(delegation.Portfolios)),
+		ExpiresAt:    delegation.ExpiresAt,
+		ApprovedAt:   delegation.ApprovedAt,
+		RevokedAt:    delegation.RevokedAt,
+		CreatedAt:    delegation.CreatedAt,
+	}
+	if delegation.Advisor != nil {
+		response.AdvisorEmail = delegation.Advisor.Email
+	}
+	if delegation.ClientID != nil {
+		response.ClientID = delegation.ClientID.String()
+	}
+	for i, portfolio := range delegation.Portfolios {
+		response.PortfolioIDs[i] = portfolio.PortfolioID.String()
+	}
+	return response
+}
+
+// ToDelegationAuditEventResponse converts a DelegationAuditEvent model to a response DTO
+func ToDelegationAuditEventResponse(event *models.DelegationAuditEvent) *DelegationAuditEventResponse {
+	response := &DelegationAuditEventResponse{
+		ID:        event.ID.String(),
+		AdvisorID: event.AdvisorID.String(),
+		ClientID:  event.ClientID.String(),
+		Method:    event.Method,
+		Route:     event.Route,
+		Path:      event.Path,
+		Status:    event.Status,
+		RequestID: event.RequestID,
+		CreatedAt: event.CreatedAt,
+	}
+	if event.PortfolioID != nil {
+		response.PortfolioID = event.PortfolioID.String()
+	}
+	return response
+}
@@ -279,9 +279,11 @@ func TestToHistoricalPricesResponse(t *testing.T) {
 		},
 	}
 
-	response := ToHistoricalPricesResponse(prices)
+	response := ToHistoricalPricesResponse("AAPL", models.PriceResolution1Day, prices)
 
 	assert.NotNil(t, response)
+	assert.Equal(t, "AAPL", response.Symbol)
+	assert.Equal(t, models.PriceResolution1Day, response.Resolution)
 	assert.Len(t, response.Prices, 2)
 }
 
@@ -449,6 +451,15 @@ func TestToPerformanceSnapshotResponse(t *testing.T) {
 	assert.Equal(t, portfolioID, response.PortfolioID)
 	assert.Equal(t, decimal.NewFromInt(12000), response.TotalValue)
 	assert.Equal(t, decimal.NewFromInt(20), response.TotalReturnPct)
+	assert.Nil(t, response.Gains)
+
+	snapshot.SetGainBreakdown(decimal.NewFromInt(300), decimal.NewFromInt(40), decimal.NewFromInt(5))
+	response = ToPerformanceSnapshotResponse(snapshot)
+	assert.NotNil(t, response.Gains)
+	assert.True(t, decimal.NewFromInt(300).Equal(response.Gains.RealizedGain))
+	assert.True(t, decimal.NewFromInt(2000).Equal(response.Gains.UnrealizedGain))
+	assert.True(t, decimal.NewFromInt(40).Equal(response.Gains.DividendIncome))
+	assert.True(t, decimal.NewFromInt(5).Equal(response.Gains.Fees))
 }
 
 func TestToPerformanceSnapshotResponse_Nil(t *testing.T) {
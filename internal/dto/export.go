@@ -0,0 +1,50 @@
+package dto
+
+// ExportFormat identifies the file an export is streamed as
+type ExportFormat string
+
+const (
+	ExportFormatCSV       ExportFormat = "csv"
+	ExportFormatJSONLines ExportFormat = "jsonl"
+)
+
+// ExportTransactionsRequest represents the query parameters for a transactions export
+type ExportTransactionsRequest struct {
+	Format ExportFormat `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
+
+// TaxExportFormat identifies the file a tax year's realized gains are exported as
+type TaxExportFormat string
+
+const (
+	// TaxExportForm8949CSV is a CSV file with the columns of IRS Form 8949
+	TaxExportForm8949CSV TaxExportFormat = "8949-csv"
+	// TaxExportTXF is a Tax Exchange Format file, which TurboTax and other tax software import
+	TaxExportTXF TaxExportFormat = "txf"
+)
+
+// TaxExportRequest represents the query parameters for a tax form export
+type TaxExportRequest struct {
+	Year   int             `form:"year" binding:"required,min=2000,max=2100"`
+	Format TaxExportFormat `form:"format" binding:"omitempty,oneof=8949-csv txf"`
+}
+
+// AccountExportRecordType identifies what an account export record holds
+type AccountExportRecordType string
+
+const (
+	AccountExportUser        AccountExportRecordType = "user"
+	AccountExportSettings    AccountExportRecordType = "settings"
+	AccountExportPortfolio   AccountExportRecordType = "portfolio"
+	AccountExportHolding     AccountExportRecordType = "holding"
+	AccountExportTaxLot      AccountExportRecordType = "tax_lot"
+	AccountExportTransaction AccountExportRecordType = "transaction"
+	AccountExportSnapshot    AccountExportRecordType = "snapshot"
+)
+
+// AccountExportRecord is one line of a user's account export: the user, their settings, or
+// one of their portfolios or a portfolio's holdings, tax lots, transactions and snapshots
+type AccountExportRecord struct {
+	Type AccountExportRecordType `json:"type"`
+	Data interface{}             `json:"data"`
+}
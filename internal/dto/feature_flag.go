@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// FeaturesResponse lists whether each feature is enabled for the signed-in user
+type FeaturesResponse struct {
+	Features map[string]bool `json:"features"`
+}
+
+// SetFeatureFlagRequest turns a feature on or off for one user, or for everyone when UserID
+// is empty, overriding the configuration
+type SetFeatureFlagRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	UserID  string `json:"user_id" binding:"omitempty,uuid"`
+}
+
+// DeleteFeatureFlagRequest represents the query parameters for removing an override, which
+// is the override for everyone when UserID is empty
+type DeleteFeatureFlagRequest struct {
+	UserID string `form:"user_id" binding:"omitempty,uuid"`
+}
+
+// FeatureFlagOverrideResponse represents an override of a feature for one user, or for
+// everyone when UserID is empty
+type FeatureFlagOverrideResponse struct {
+	UserID    string    `json:"user_id,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FeatureFlagResponse represents a feature flag in admin API responses
+type FeatureFlagResponse struct {
+	Name string `json:"name"`
+	// Default is whether the feature is on when neither the configuration nor an override
+	// sets it
+	Default bool `json:"default"`
+	// Configured is the configuration's setting, if it sets one
+	Configured *bool `json:"configured,omitempty"`
+	// Enabled is whether the feature is on for users without an override of their own
+	Enabled   bool                          `json:"enabled"`
+	Overrides []FeatureFlagOverrideResponse `json:"overrides"`
+}
+
+// FeatureFlagListResponse represents every feature flag
+type FeatureFlagListResponse struct {
+	FeatureFlags []FeatureFlagResponse `json:"feature_flags"`
+	Total        int                   `json:"total"`
+}
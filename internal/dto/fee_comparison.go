@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// FeeSchedulePresetsResponse lists the built-in fee schedules
+type FeeSchedulePresetsResponse struct {
+	Presets []models.FeeSchedule `json:"presets"`
+}
+
+// FeeComparisonRequest selects the fee schedules to replay a portfolio's trades under.
+// Presets are referenced by key; when neither presets nor schedules are given, every preset
+// is compared. StartDate and EndDate optionally limit the trades considered.
+type FeeComparisonRequest struct {
+	Presets   []string             `json:"presets,omitempty"`
+	Schedules []models.FeeSchedule `json:"schedules,omitempty"`
+	StartDate *time.Time           `json:"start_date,omitempty"`
+	EndDate   *time.Time           `json:"end_date,omitempty"`
+}
+
+// FeeScheduleComparison is what a portfolio's trades would have cost under one fee schedule
+type FeeScheduleComparison struct {
+	Schedule  models.FeeSchedule `json:"schedule"`
+	TotalFees decimal.Decimal    `json:"total_fees"`
+	// Difference is TotalFees minus the fees actually paid; negative means the schedule
+	// would have been cheaper
+	Difference decimal.Decimal `json:"difference"`
+	// FeesPercentOfTraded is TotalFees as a percentage of the total traded value
+	FeesPercentOfTraded decimal.Decimal `json:"fees_percent_of_traded"`
+}
+
+// FeeComparisonResult compares the fees a portfolio actually paid on its buys and sells
+// with what alternative fee schedules would have charged for the same trades
+type FeeComparisonResult struct {
+	PortfolioID         string                  `json:"portfolio_id"`
+	TradeCount          int                     `json:"trade_count"`
+	TradedValue         decimal.Decimal         `json:"traded_value"`
+	ActualFees          decimal.Decimal         `json:"actual_fees"`
+	FeesPercentOfTraded decimal.Decimal         `json:"fees_percent_of_traded"`
+	FirstTradeDate      *time.Time              `json:"first_trade_date,omitempty"`
+	LastTradeDate       *time.Time              `json:"last_trade_date,omitempty"`
+	Comparisons         []FeeScheduleComparison `json:"comparisons"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// FeeSummaryRequest represents the query parameters for summarizing a portfolio's fees. Year
+// defaults to the current year.
+type FeeSummaryRequest struct {
+	Year int `form:"year" binding:"omitempty,min=1900,max=9999"`
+}
+
+// FeeCategorySummary totals the fees of one category: COMMISSION for the commissions paid on
+// trades, or the type of the fee transactions
+type FeeCategorySummary struct {
+	Category string          `json:"category"`
+	Amount   decimal.Decimal `json:"amount"`
+	Count    int             `json:"count"`
+}
+
+// FeeSummary totals the fees a portfolio paid in a year by category, and measures them
+// against its value. The valuation fields are left out when the portfolio has no snapshots
+// in the year.
+type FeeSummary struct {
+	PortfolioID string               `json:"portfolio_id"`
+	Year        int                  `json:"year"`
+	StartDate   time.Time            `json:"start_date"`
+	EndDate     time.Time            `json:"end_date"` // Today for the current year
+	TotalFees   decimal.Decimal      `json:"total_fees"`
+	Categories  []FeeCategorySummary `json:"categories"`
+	// AverageValue is the mean value of the year's snapshots, and ExpenseRatio the fees as a
+	// yearly percentage of it
+	AverageValue *decimal.Decimal `json:"average_value,omitempty"`
+	ExpenseRatio *decimal.Decimal `json:"expense_ratio,omitempty"`
+	// The annualized returns run from the first to the last snapshot of the year, before and
+	// after deducting the year's fees; FeeDrag is the difference in percentage points
+	GrossAnnualizedReturn *decimal.Decimal `json:"gross_annualized_return,omitempty"`
+	NetAnnualizedReturn   *decimal.Decimal `json:"net_annualized_return,omitempty"`
+	FeeDrag               *decimal.Decimal `json:"fee_drag,omitempty"`
+}
@@ -7,15 +7,23 @@ import (
 	"time"
 )
 
+// HoldingListRequest represents the query parameters for listing holdings. AsOf lists the
+// holdings at the end of a past day instead of the current ones.
+type HoldingListRequest struct {
+	AsOf time.Time `form:"as_of" time_format:"2006-01-02"`
+}
+
 // HoldingResponse represents a holding in API responses
 type HoldingResponse struct {
-	ID           uuid.UUID       `json:"id"`
-	PortfolioID  uuid.UUID       `json:"portfolio_id"`
-	Symbol       string          `json:"symbol"`
-	Quantity     decimal.Decimal `json:"quantity"`
-	CostBasis    decimal.Decimal `json:"cost_basis"`
-	AvgCostPrice decimal.Decimal `json:"avg_cost_price"`
-	UpdatedAt    time.Time       `json:"updated_at"`
+	ID           uuid.UUID        `json:"id"`
+	PortfolioID  uuid.UUID        `json:"portfolio_id"`
+	Symbol       string           `json:"symbol"`
+	AssetType    models.AssetType `json:"asset_type"`
+	Quantity     decimal.Decimal  `json:"quantity"`
+	CostBasis    decimal.Decimal  `json:"cost_basis"`
+	AvgCostPrice decimal.Decimal  `json:"avg_cost_price"`
+	Multiplier   int              `json:"multiplier,omitempty"`
+	UpdatedAt    time.Time        `json:"updated_at"`
 	// Optional fields for enriched responses
 	MarketPrice          *decimal.Decimal `json:"market_price,omitempty"`
 	MarketValue          *decimal.Decimal `json:"market_value,omitempty"`
@@ -24,6 +32,8 @@ type HoldingResponse struct {
 	DayChange            *decimal.Decimal `json:"day_change,omitempty"`
 	DayChangePct         *decimal.Decimal `json:"day_change_pct,omitempty"`
 	AllocationPercentage *decimal.Decimal `json:"allocation_percentage,omitempty"`
+	// Set when the symbol is halted or suspended
+	TradingRestriction *SymbolTradingStatus `json:"trading_restriction,omitempty"`
 }
 
 // HoldingListResponse represents a list of holdings with summary
@@ -31,6 +41,8 @@ type HoldingListResponse struct {
 	Holdings []*HoldingResponse `json:"holdings"`
 	Total    int                `json:"total"`
 	Summary  *HoldingSummary    `json:"summary,omitempty"`
+	// Set when the holdings are those at the end of a past day
+	AsOf *time.Time `json:"as_of,omitempty"`
 }
 
 // HoldingSummary provides aggregate statistics for holdings
@@ -52,9 +64,11 @@ func ToHoldingResponse(holding *models.Holding) *HoldingResponse {
 		ID:           holding.ID,
 		PortfolioID:  holding.PortfolioID,
 		Symbol:       holding.Symbol,
+		AssetType:    holding.AssetType,
 		Quantity:     holding.Quantity,
 		CostBasis:    holding.CostBasis,
 		AvgCostPrice: holding.AvgCostPrice,
+		Multiplier:   holding.Multiplier,
 		UpdatedAt:    holding.UpdatedAt,
 	}
 }
@@ -67,7 +81,7 @@ func ToHoldingResponseWithMarketData(holding *models.Holding, marketPrice decima
 	}
 
 	// Calculate market value
-	marketValue := holding.Quantity.Mul(marketPrice)
+	marketValue := holding.MarketValue(marketPrice)
 	response.MarketPrice = &marketPrice
 	response.MarketValue = &marketValue
 
@@ -20,6 +20,20 @@ const (
 	ImportFormatETrade             ImportFormat = "ETRADE"
 	ImportFormatInteractiveBrokers ImportFormat = "INTERACTIVE_BROKERS"
 	ImportFormatRobinhood          ImportFormat = "ROBINHOOD"
+
+	// Exports of other portfolio trackers, imported with TrackerImportRequest
+	ImportFormatGhostfolio           ImportFormat = "GHOSTFOLIO"
+	ImportFormatPortfolioPerformance ImportFormat = "PORTFOLIO_PERFORMANCE"
+
+	// Lists of the lots held when a portfolio's history starts, imported with
+	// OpeningLotImportRequest
+	ImportFormatOpeningLots ImportFormat = "OPENING_LOTS"
+
+	// Transactions synced from a broker connection's provider
+	ImportFormatPlaid ImportFormat = "PLAID"
+
+	// Adjustments bringing holdings in line with a position statement
+	ImportFormatReconciliation ImportFormat = "RECONCILIATION"
 )
 
 // ImportTransactionRequest represents a single transaction in the import
@@ -53,6 +67,27 @@ type CSVImportRequest struct {
 	Notes       string       `json:"notes"`                       // Optional notes about this import batch
 }
 
+// TrackerImportRequest represents the request to import the export of another portfolio
+// tracker. Every account in the export with securities transactions becomes a new portfolio.
+type TrackerImportRequest struct {
+	Format          ImportFormat           `json:"format" binding:"required,oneof=GHOSTFOLIO PORTFOLIO_PERFORMANCE"`
+	Data            string                 `json:"data" binding:"required"`                                                      // Base64 encoded or raw export file
+	CostBasisMethod models.CostBasisMethod `json:"cost_basis_method,omitempty" binding:"omitempty,oneof=FIFO LIFO SPECIFIC_LOT"` // Defaults to FIFO
+	DryRun          bool                   `json:"dry_run"`                                                                      // If true, validate but don't create anything
+	SkipInvalid     bool                   `json:"skip_invalid"`                                                                 // If true, import what can be mapped and skip the rest
+	Notes           string                 `json:"notes"`                                                                        // Optional notes about the import batches
+}
+
+// OpeningLotImportRequest represents the request to import the lots a portfolio held before
+// its recorded history, from a CSV file with symbol, quantity, cost basis and purchase date
+// columns and optional currency and notes columns
+type OpeningLotImportRequest struct {
+	CSVData     string `json:"csv_data" binding:"required"` // Base64 encoded CSV data or raw CSV text
+	DryRun      bool   `json:"dry_run"`                     // If true, validate but don't save
+	SkipInvalid bool   `json:"skip_invalid"`                // If true, skip invalid lots and continue
+	Notes       string `json:"notes"`                       // Optional notes about this import batch
+}
+
 // ImportError represents an error that occurred during import
 type ImportError struct {
 	Line    int    `json:"line"`               // Line number in the CSV (0 for general errors)
@@ -82,6 +117,68 @@ type ImportResult struct {
 	ValidationResults []ImportValidationResult `json:"validation_results,omitempty"` // Detailed validation results
 }
 
+// ImportStatus is the state of a CSV import running in the background
+type ImportStatus string
+
+const (
+	ImportStatusQueued    ImportStatus = "QUEUED"    // Waiting for a free import worker
+	ImportStatusRunning   ImportStatus = "RUNNING"   // Rows are being imported
+	ImportStatusCompleted ImportStatus = "COMPLETED" // Finished; Result says whether the rows were accepted
+	ImportStatusFailed    ImportStatus = "FAILED"    // Stopped by an error before finishing
+)
+
+// IsFinished reports whether the import has stopped, successfully or not
+func (s ImportStatus) IsFinished() bool {
+	return s == ImportStatusCompleted || s == ImportStatusFailed
+}
+
+// ImportJobResponse is returned when a CSV import has been accepted to run in the background
+type ImportJobResponse struct {
+	BatchID   uuid.UUID    `json:"batch_id"`
+	Status    ImportStatus `json:"status"`
+	EventsURL string       `json:"events_url"` // Server-sent event stream of the import's progress
+	StatusURL string       `json:"status_url"` // Status of the import's queued job
+}
+
+// ImportProgress is an event in the progress stream of a background CSV import
+type ImportProgress struct {
+	BatchID       uuid.UUID     `json:"batch_id"`
+	Status        ImportStatus  `json:"status"`
+	TotalRows     int           `json:"total_rows"` // Zero until the CSV data has been parsed
+	ProcessedRows int           `json:"processed_rows"`
+	SuccessCount  int           `json:"success_count"`
+	ErrorCount    int           `json:"error_count"`
+	SkippedCount  int           `json:"skipped_count"`
+	Errors        []ImportError `json:"errors,omitempty"` // Errors found since the previous event of the stream
+	Result        *ImportResult `json:"result,omitempty"` // Full result, once the import has completed
+	Error         string        `json:"error,omitempty"`  // Why the import failed
+}
+
+// TrackerPortfolioImport represents the import of one account of a tracker export
+type TrackerPortfolioImport struct {
+	Account   string             `json:"account"`             // Account name in the export
+	Portfolio *PortfolioResponse `json:"portfolio,omitempty"` // Portfolio created for the account (not set in a dry run)
+	Result    *ImportResult      `json:"result"`              // Import batch of the account's transactions
+	TaxLots   int                `json:"tax_lots"`            // Tax lots rebuilt from the imported history
+}
+
+// OpeningLotImportResult represents the result of importing opening lots
+type OpeningLotImportResult struct {
+	Result  *ImportResult `json:"result"`   // Import batch of the lots' opening balance transactions
+	TaxLots int           `json:"tax_lots"` // Tax lots rebuilt once the lots were imported
+}
+
+// TrackerImportResult represents the result of importing a tracker export
+type TrackerImportResult struct {
+	Success        bool                      `json:"success"`
+	Format         ImportFormat              `json:"format"`
+	Portfolios     []*TrackerPortfolioImport `json:"portfolios"`
+	IgnoredCount   int                       `json:"ignored_count"` // Cash-only activities such as deposits, interest and fees, which aren't tracked
+	ErrorCount     int                       `json:"error_count"`
+	Errors         []ImportError             `json:"errors,omitempty"` // Activities that could not be mapped
+	ValidationOnly bool                      `json:"validation_only"`  // True if this was a dry run
+}
+
 // ImportBatchInfo represents information about an import batch
 type ImportBatchInfo struct {
 	BatchID          uuid.UUID    `json:"batch_id"`
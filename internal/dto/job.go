@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// QueuedJobListRequest represents the query parameters for listing queued jobs
+type QueuedJobListRequest struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"` // Defaults to 20
+}
+
+// QueuedJobResponse represents an import, recalculation or report queued to run in the
+// background. Progress and Result are in the layout of the job type's synchronous response,
+// for example a RecalculationReport for RECALCULATION jobs.
+type QueuedJobResponse struct {
+	ID          string                 `json:"id"`
+	Type        models.QueuedJobType   `json:"type"`
+	Status      models.QueuedJobStatus `json:"status"`
+	PortfolioID string                 `json:"portfolio_id,omitempty"`
+	Progress    json.RawMessage        `json:"progress,omitempty"`
+	Result      json.RawMessage        `json:"result,omitempty"` // Set once the job has succeeded
+	Error       string                 `json:"error,omitempty"`  // Set if the job failed
+	StatusURL   string                 `json:"status_url"`       // Poll this until the job has finished
+	CreatedAt   time.Time              `json:"created_at"`
+	StartedAt   *time.Time             `json:"started_at,omitempty"`
+	FinishedAt  *time.Time             `json:"finished_at,omitempty"`
+}
+
+// QueuedJobListResponse represents a list of queued jobs, newest first
+type QueuedJobListResponse struct {
+	Jobs []*QueuedJobResponse `json:"jobs"`
+}
+
+// JobStatusURL returns the API path that reports the status of the job with the ID
+func JobStatusURL(id string) string {
+	return "/api/v1/jobs/" + id
+}
+
+// ToQueuedJobResponse converts a QueuedJob model to a response DTO
+func ToQueuedJobResponse(job *models.QueuedJob) *QueuedJobResponse {
+	response := &QueuedJobResponse{
+		ID:         job.ID.String(),
+		Type:       job.Type,
+		Status:     job.Status,
+		Error:      job.Error,
+		StatusURL:  JobStatusURL(job.ID.String()),
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+	if job.PortfolioID != nil {
+		response.PortfolioID = job.PortfolioID.String()
+	}
+	if job.Progress != "" {
+		response.Progress = json.RawMessage(job.Progress)
+	}
+	if job.Result != "" && job.Status == models.QueuedJobStatusSucceeded {
+		response.Result = json.RawMessage(job.Result)
+	}
+	return response
+}
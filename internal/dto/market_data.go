@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // GetQuotesRequest represents request for multiple quotes
@@ -11,10 +13,12 @@ type GetQuotesRequest struct {
 	Symbols []string `json:"symbols" binding:"required"`
 }
 
-// HistoricalPricesRequest represents request parameters for historical prices
+// HistoricalPricesRequest represents request parameters for historical prices. Resolution
+// defaults to 1d.
 type HistoricalPricesRequest struct {
-	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
-	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+	StartDate  time.Time              `form:"start_date" time_format:"2006-01-02"`
+	EndDate    time.Time              `form:"end_date" time_format:"2006-01-02"`
+	Resolution models.PriceResolution `form:"resolution" binding:"omitempty,oneof=1m 5m 1h 1d 1w"`
 }
 
 // ExchangeRateRequest represents request parameters for exchange rate
@@ -35,6 +39,9 @@ type QuoteResponse struct {
 	Change        decimal.Decimal `json:"change"`
 	ChangePercent decimal.Decimal `json:"change_percent"`
 	LastTradeTime time.Time       `json:"last_updated"`
+	TradingStatus TradingStatus   `json:"trading_status,omitempty"`
+	// Stale is set when the provider is failing and this is the last quote fetched
+	Stale bool `json:"stale,omitempty"`
 }
 
 // QuotesResponse represents multiple quotes response
@@ -42,7 +49,16 @@ type QuotesResponse struct {
 	Quotes map[string]*QuoteResponse `json:"quotes"`
 }
 
-// HistoricalPriceResponse represents a single historical price point
+// SymbolTradingStatus is the trading status last seen for a symbol
+type SymbolTradingStatus struct {
+	Symbol           string        `json:"symbol"`
+	Status           TradingStatus `json:"status"`
+	LatestTradingDay *time.Time    `json:"latest_trading_day,omitempty"`
+	CheckedAt        time.Time     `json:"checked_at"`
+}
+
+// HistoricalPriceResponse represents a single historical price point. Date is the start of
+// the bar: a time of day for intraday bars, and the first trading day of weekly bars.
 type HistoricalPriceResponse struct {
 	Date   time.Time       `json:"date"`
 	Open   decimal.Decimal `json:"open"`
@@ -54,8 +70,9 @@ type HistoricalPriceResponse struct {
 
 // HistoricalPricesResponse represents historical prices response
 type HistoricalPricesResponse struct {
-	Symbol string                     `json:"symbol"`
-	Prices []*HistoricalPriceResponse `json:"prices"`
+	Symbol     string                     `json:"symbol"`
+	Resolution models.PriceResolution     `json:"resolution"`
+	Prices     []*HistoricalPriceResponse `json:"prices"`
 }
 
 // ExchangeRateResponse represents exchange rate response
@@ -83,6 +100,8 @@ func ToQuoteResponse(quote *Quote) *QuoteResponse {
 		Change:        quote.Change,
 		ChangePercent: quote.ChangePercent,
 		LastTradeTime: quote.LastUpdated,
+		TradingStatus: quote.TradingStatus,
+		Stale:         quote.Stale,
 	}
 }
 
@@ -115,16 +134,13 @@ func ToHistoricalPriceResponse(price *HistoricalPrice) *HistoricalPriceResponse
 	}
 }
 
-// ToHistoricalPricesResponse converts historical prices to HistoricalPricesResponse
-func ToHistoricalPricesResponse(prices []*HistoricalPrice) *HistoricalPricesResponse {
+// ToHistoricalPricesResponse converts a symbol's bars of a resolution to
+// HistoricalPricesResponse
+func ToHistoricalPricesResponse(symbol string, resolution models.PriceResolution, prices []*HistoricalPrice) *HistoricalPricesResponse {
 	response := &HistoricalPricesResponse{
-		Prices: make([]*HistoricalPriceResponse, 0, len(prices)),
-	}
-
-	if len(prices) > 0 {
-		// Assume all prices are for the same symbol (get from first entry)
-		// In the service, you might want to add symbol to the response
-		response.Symbol = "" // Will be set from context
+		Symbol:     symbol,
+		Resolution: resolution,
+		Prices:     make([]*HistoricalPriceResponse, 0, len(prices)),
 	}
 
 	for i := range prices {
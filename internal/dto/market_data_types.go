@@ -23,6 +23,32 @@ type Quote struct {
 	Week52High      *decimal.Decimal
 	Week52Low       *decimal.Decimal
 	AverageDailyVol *int64
+	// LatestTradingDay is the session the quote comes from, when the provider reports it
+	LatestTradingDay time.Time
+	// TradingStatus is empty when the provider doesn't report whether the symbol trades
+	TradingStatus TradingStatus
+	// Stale is set on the last quote fetched for a symbol when it is served because the
+	// provider is failing
+	Stale bool
+	// FetchedAt is when the quote was fetched from the provider
+	FetchedAt time.Time
+}
+
+// TradingStatus is whether a symbol is currently trading on its exchange
+type TradingStatus string
+
+const (
+	// TradingStatusActive means the symbol traded in its latest session
+	TradingStatusActive TradingStatus = "active"
+	// TradingStatusHalted means the symbol had no trades in its latest session
+	TradingStatusHalted TradingStatus = "halted"
+	// TradingStatusSuspended means the symbol hasn't had a session for several trading days
+	TradingStatusSuspended TradingStatus = "suspended"
+)
+
+// IsRestricted returns true if the symbol can't currently be traded
+func (s TradingStatus) IsRestricted() bool {
+	return s == TradingStatusHalted || s == TradingStatusSuspended
 }
 
 // HistoricalPrice represents a historical price point
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// NotificationListRequest represents the query parameters for listing notifications
+type NotificationListRequest struct {
+	Unread bool `form:"unread"`                                  // Only list unread notifications
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"` // Defaults to 50
+}
+
+// NotificationResponse represents an in-app notification
+type NotificationResponse struct {
+	ID          string                  `json:"id"`
+	Type        models.NotificationType `json:"type"`
+	Title       string                  `json:"title"`
+	Message     string                  `json:"message"`
+	PortfolioID string                  `json:"portfolio_id,omitempty"`
+	Read        bool                    `json:"read"`
+	ReadAt      *time.Time              `json:"read_at,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+}
+
+// NotificationListResponse represents a list of notifications, newest first
+type NotificationListResponse struct {
+	Notifications []*NotificationResponse `json:"notifications"`
+	UnreadCount   int64                   `json:"unread_count"` // Across all the user's notifications, not just those listed
+}
+
+// ToNotificationResponse converts a Notification model to a response DTO
+func ToNotificationResponse(notification *models.Notification) *NotificationResponse {
+	response := &NotificationResponse{
+		ID:        notification.ID.String(),
+		Type:      notification.Type,
+		Title:     notification.Title,
+		Message:   notification.Message,
+		Read:      notification.IsRead(),
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+	if notification.PortfolioID != nil {
+		response.PortfolioID = notification.PortfolioID.String()
+	}
+	return response
+}
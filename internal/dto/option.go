@@ -0,0 +1,119 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// OptionSettlementAction is how an option position is closed without a trade
+type OptionSettlementAction string
+
+const (
+	// OptionSettlementExpire lets the contracts lapse worthless
+	OptionSettlementExpire OptionSettlementAction = "EXPIRE"
+	// OptionSettlementAssign exercises the contracts: calls buy the underlying shares at the
+	// strike and puts sell them
+	OptionSettlementAssign OptionSettlementAction = "ASSIGN"
+)
+
+// OptionTradeRequest represents the request to buy or sell option contracts. The contract
+// is given either by its OCC symbol or by its terms.
+type OptionTradeRequest struct {
+	Type       models.TransactionType `json:"type" binding:"required,oneof=BUY_TO_OPEN SELL_TO_CLOSE"`
+	Symbol     string                 `json:"symbol,omitempty" binding:"omitempty,max=20"`
+	Underlying string                 `json:"underlying,omitempty" binding:"omitempty,max=5"`
+	OptionType models.OptionType      `json:"option_type,omitempty" binding:"omitempty,oneof=CALL PUT"`
+	Strike     *decimal.Decimal       `json:"strike,omitempty"`
+	Expiry     *time.Time             `json:"expiry,omitempty"`
+	Multiplier int                    `json:"multiplier,omitempty" binding:"omitempty,min=1"`
+	Date       time.Time              `json:"date" binding:"required"`
+	Quantity   decimal.Decimal        `json:"quantity" binding:"required"`
+	Price      decimal.Decimal        `json:"price" binding:"required"`
+	Commission decimal.Decimal        `json:"commission"`
+	Notes      string                 `json:"notes,omitempty"`
+}
+
+// SettleOptionRequest represents the request to close an option position by expiration or
+// assignment. Date defaults to the contract's expiry.
+type SettleOptionRequest struct {
+	Action OptionSettlementAction `json:"action" binding:"required,oneof=EXPIRE ASSIGN"`
+	Date   *time.Time             `json:"date,omitempty"`
+}
+
+// OptionContractResponse represents an option contract in API responses
+type OptionContractResponse struct {
+	ID         uuid.UUID         `json:"id"`
+	Symbol     string            `json:"symbol"`
+	Underlying string            `json:"underlying"`
+	Type       models.OptionType `json:"type"`
+	Strike     decimal.Decimal   `json:"strike"`
+	Expiry     time.Time         `json:"expiry"`
+	Multiplier int               `json:"multiplier"`
+}
+
+// OptionPositionResponse represents the contracts of one option held in a portfolio. Prices
+// are per underlying share; values are for all the contracts.
+type OptionPositionResponse struct {
+	Contract       *OptionContractResponse `json:"contract"`
+	Quantity       decimal.Decimal         `json:"quantity"`
+	CostBasis      decimal.Decimal         `json:"cost_basis"`
+	AvgCostPrice   decimal.Decimal         `json:"avg_cost_price"`
+	MarketPrice    *decimal.Decimal        `json:"market_price,omitempty"`
+	MarketValue    *decimal.Decimal        `json:"market_value,omitempty"`
+	UnrealizedGain *decimal.Decimal        `json:"unrealized_gain,omitempty"`
+	Expired        bool                    `json:"expired"`
+}
+
+// OptionPositionListResponse represents a portfolio's option positions
+type OptionPositionListResponse struct {
+	Positions []*OptionPositionResponse `json:"positions"`
+	Total     int                       `json:"total"`
+}
+
+// OptionSettlementResponse represents the transactions that closed an option position: the
+// option's own, and for assignments the underlying trade
+type OptionSettlementResponse struct {
+	Action       OptionSettlementAction `json:"action"`
+	Transactions []*TransactionResponse `json:"transactions"`
+}
+
+// ToOptionContractResponse converts an OptionContract model to OptionContractResponse DTO
+func ToOptionContractResponse(contract *models.OptionContract) *OptionContractResponse {
+	if contract == nil {
+		return nil
+	}
+
+	return &OptionContractResponse{
+		ID:         contract.ID,
+		Symbol:     contract.Symbol,
+		Underlying: contract.Underlying,
+		Type:       contract.Type,
+		Strike:     contract.Strike,
+		Expiry:     contract.Expiry,
+		Multiplier: contract.Multiplier,
+	}
+}
+
+// ToOptionPositionResponse converts an option holding and its contract to
+// OptionPositionResponse DTO, valued at marketPrice when it is given
+func ToOptionPositionResponse(holding *models.Holding, contract *models.OptionContract, marketPrice *decimal.Decimal, asOf time.Time) *OptionPositionResponse {
+	response := &OptionPositionResponse{
+		Contract:     ToOptionContractResponse(contract),
+		Quantity:     holding.Quantity,
+		CostBasis:    holding.CostBasis,
+		AvgCostPrice: holding.AvgCostPrice,
+		Expired:      contract.IsExpired(asOf),
+	}
+	if marketPrice != nil {
+		marketValue := holding.MarketValue(*marketPrice)
+		unrealizedGain := holding.CalculateUnrealizedGain(*marketPrice)
+		response.MarketPrice = marketPrice
+		response.MarketValue = &marketValue
+		response.UnrealizedGain = &unrealizedGain
+	}
+	return response
+}
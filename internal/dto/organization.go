@@ -0,0 +1,128 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// CreateOrganizationRequest represents the request to create an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,min=1,max=255"`
+}
+
+// SetMemberRoleRequest represents the request to change a member's role
+type SetMemberRoleRequest struct {
+	Role models.OrganizationRole `json:"role" binding:"required,oneof=owner admin member viewer"`
+}
+
+// CreateInvitationRequest represents the request to invite someone to an organization
+type CreateInvitationRequest struct {
+	Email string                  `json:"email" binding:"required,email,max=255"`
+	Role  models.OrganizationRole `json:"role" binding:"required,oneof=owner admin member viewer"`
+}
+
+// AcceptInvitationRequest represents the request to accept an invitation
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// CreateOrganizationAPIKeyRequest represents the request to issue an organization API key
+type CreateOrganizationAPIKeyRequest struct {
+	Name string `json:"name,omitempty" binding:"max=255"`
+}
+
+// MembershipResponse represents one of the organizations the current user belongs to
+type MembershipResponse struct {
+	OrganizationID string                  `json:"organization_id"`
+	Name           string                  `json:"name"`
+	Role           models.OrganizationRole `json:"role"`
+	JoinedAt       time.Time               `json:"joined_at"`
+}
+
+// MemberResponse represents a member of an organization
+type MemberResponse struct {
+	UserID   string                  `json:"user_id"`
+	Email    string                  `json:"email,omitempty"`
+	Role     models.OrganizationRole `json:"role"`
+	JoinedAt time.Time               `json:"joined_at"`
+}
+
+// InvitationResponse represents an invitation to an organization. Token is only present in
+// the response that issued it.
+type InvitationResponse struct {
+	ID             string                  `json:"id"`
+	OrganizationID string                  `json:"organization_id"`
+	Email          string                  `json:"email"`
+	Role           models.OrganizationRole `json:"role"`
+	Token          string                  `json:"token,omitempty"`
+	EmailSent      *bool                   `json:"email_sent,omitempty"`
+	ExpiresAt      time.Time               `json:"expires_at"`
+	CreatedAt      time.Time               `json:"created_at"`
+}
+
+// OrganizationAPIKeyResponse represents an organization API key. Key is only present in the
+// response that issued it.
+type OrganizationAPIKeyResponse struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"`
+	Active     bool       `json:"active"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToMembershipResponse converts a membership, with its organization, to a response DTO
+func ToMembershipResponse(member *models.OrganizationMember) *MembershipResponse {
+	response := &MembershipResponse{
+		OrganizationID: member.OrganizationID.String(),
+		Role:           member.Role,
+		JoinedAt:       member.CreatedAt,
+	}
+	if member.Organization != nil {
+		response.Name = member.Organization.Name
+	}
+	return response
+}
+
+// ToMemberResponse converts a membership, with its user, to a response DTO
+func ToMemberResponse(member *models.OrganizationMember) *MemberResponse {
+	response := &MemberResponse{
+		UserID:   member.UserID.String(),
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt,
+	}
+	if member.User != nil {
+		response.Email = member.User.Email
+	}
+	return response
+}
+
+// ToInvitationResponse converts an OrganizationInvitation model to a response DTO
+func ToInvitationResponse(invitation *models.OrganizationInvitation) *InvitationResponse {
+	return &InvitationResponse{
+		ID:             invitation.ID.String(),
+		OrganizationID: invitation.OrganizationID.String(),
+		Email:          invitation.Email,
+		Role:           invitation.Role,
+		ExpiresAt:      invitation.ExpiresAt,
+		CreatedAt:      invitation.CreatedAt,
+	}
+}
+
+// ToOrganizationAPIKeyResponse converts an APIKey model to an organization API key response DTO
+func ToOrganizationAPIKeyResponse(key *models.APIKey) *OrganizationAPIKeyResponse {
+	return &OrganizationAPIKeyResponse{
+		ID:         key.ID.String(),
+		UserID:     key.UserID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Active:     key.IsActive(),
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,81 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// OverviewRequest represents the query parameters of the household overview
+type OverviewRequest struct {
+	Currency  string    `form:"currency" binding:"omitempty,len=3"`
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+}
+
+// Overview aggregates all of a user's portfolios in one currency. Portfolios in other base
+// currencies are converted at the current exchange rate.
+type Overview struct {
+	Currency            string                `json:"currency"`
+	TotalValue          decimal.Decimal       `json:"total_value"`
+	TotalCostBasis      decimal.Decimal       `json:"total_cost_basis"`
+	TotalUnrealizedGain decimal.Decimal       `json:"total_unrealized_gain"`
+	TotalGainPct        decimal.Decimal       `json:"total_gain_pct"`
+	Portfolios          []*OverviewPortfolio  `json:"portfolios"`
+	Allocation          []*OverviewAllocation `json:"allocation"`
+	Holdings            []*OverviewHolding    `json:"holdings"`
+	Performance         *OverviewPerformance  `json:"performance,omitempty"`
+	GeneratedAt         time.Time             `json:"generated_at"`
+}
+
+// OverviewPortfolio is one portfolio's share of the overview. Error says why a portfolio was
+// left out of the totals, and PerformanceError why it was left out of the performance.
+type OverviewPortfolio struct {
+	PortfolioID      uuid.UUID       `json:"portfolio_id"`
+	Name             string          `json:"name"`
+	BaseCurrency     string          `json:"base_currency"`
+	ExchangeRate     decimal.Decimal `json:"exchange_rate"`
+	Value            decimal.Decimal `json:"value"`
+	AllocationPct    decimal.Decimal `json:"allocation_pct"`
+	Error            string          `json:"error,omitempty"`
+	PerformanceError string          `json:"performance_error,omitempty"`
+}
+
+// OverviewAllocation is the combined value held in one asset type
+type OverviewAllocation struct {
+	AssetType     models.AssetType `json:"asset_type"`
+	Value         decimal.Decimal  `json:"value"`
+	AllocationPct decimal.Decimal  `json:"allocation_pct"`
+	Positions     int              `json:"positions"`
+}
+
+// OverviewHolding merges the holdings of one symbol across portfolios. Priced is false when no
+// quote was available and the holding is valued at its cost basis.
+type OverviewHolding struct {
+	Symbol         string           `json:"symbol"`
+	AssetType      models.AssetType `json:"asset_type"`
+	Quantity       decimal.Decimal  `json:"quantity"`
+	CostBasis      decimal.Decimal  `json:"cost_basis"`
+	MarketValue    decimal.Decimal  `json:"market_value"`
+	UnrealizedGain decimal.Decimal  `json:"unrealized_gain"`
+	AllocationPct  decimal.Decimal  `json:"allocation_pct"`
+	Priced         bool             `json:"priced"`
+	PortfolioIDs   []uuid.UUID      `json:"portfolio_ids"`
+}
+
+// OverviewPerformance adds up the performance of the measured portfolios over a period
+type OverviewPerformance struct {
+	StartDate        time.Time       `json:"start_date"`
+	EndDate          time.Time       `json:"end_date"`
+	Portfolios       int             `json:"portfolios"`
+	StartingValue    decimal.Decimal `json:"starting_value"`
+	EndingValue      decimal.Decimal `json:"ending_value"`
+	TotalReturn      decimal.Decimal `json:"total_return"`
+	TotalReturnPct   decimal.Decimal `json:"total_return_pct"`
+	TotalDeposits    decimal.Decimal `json:"total_deposits"`
+	TotalWithdrawals decimal.Decimal `json:"total_withdrawals"`
+	NetCashFlow      decimal.Decimal `json:"net_cash_flow"`
+}
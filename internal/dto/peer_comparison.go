@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// PeerComparisonOptInRequest represents the request to opt a portfolio in or out of peer comparison
+type PeerComparisonOptInRequest struct {
+	OptIn *bool `json:"opt_in" binding:"required"`
+}
+
+// PeerComparisonOptInResponse represents a portfolio's peer comparison consent
+type PeerComparisonOptInResponse struct {
+	PortfolioID string `json:"portfolio_id"`
+	OptIn       bool   `json:"opt_in"`
+}
+
+// PeerDistribution represents the published quantiles of a bucket's risk-adjusted returns
+type PeerDistribution struct {
+	P10 decimal.Decimal `json:"p10"`
+	P25 decimal.Decimal `json:"p25"`
+	P50 decimal.Decimal `json:"p50"`
+	P75 decimal.Decimal `json:"p75"`
+	P90 decimal.Decimal `json:"p90"`
+}
+
+// PeerComparisonResult represents where a portfolio's risk-adjusted return falls among
+// anonymized peers in the same allocation bucket
+type PeerComparisonResult struct {
+	PortfolioID        string                  `json:"portfolio_id"`
+	Bucket             models.AllocationBucket `json:"bucket"`
+	LookbackDays       int                     `json:"lookback_days"`
+	RiskAdjustedReturn decimal.Decimal         `json:"risk_adjusted_return"`
+	Percentile         int                     `json:"percentile"`
+	PeerCount          int                     `json:"peer_count"`
+	Distribution       PeerDistribution        `json:"distribution"`
+	ComputedAt         time.Time               `json:"computed_at"`
+}
@@ -4,12 +4,17 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // PerformanceMetricsRequest represents request parameters for performance metrics
 type PerformanceMetricsRequest struct {
 	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
 	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+	// Period selects a standard period ending today (1M, 3M, YTD, 1Y, 3Y or ALL) instead of
+	// the dates, served from the nightly materialized metrics
+	Period string `form:"period"`
 }
 
 // BenchmarkComparisonRequest represents request parameters for benchmark comparison
@@ -21,19 +26,30 @@ type BenchmarkComparisonRequest struct {
 
 // PerformanceMetricsResponse represents comprehensive performance metrics
 type PerformanceMetricsResponse struct {
-	StartDate           time.Time       `json:"start_date"`
-	EndDate             time.Time       `json:"end_date"`
-	StartingValue       decimal.Decimal `json:"starting_value"`
-	EndingValue         decimal.Decimal `json:"ending_value"`
-	TotalReturn         decimal.Decimal `json:"total_return"`
-	TotalReturnPct      decimal.Decimal `json:"total_return_pct"`
-	TimeWeightedReturn  decimal.Decimal `json:"time_weighted_return"`
-	MoneyWeightedReturn decimal.Decimal `json:"money_weighted_return"`
-	AnnualizedReturn    decimal.Decimal `json:"annualized_return"`
-	TotalDeposits       decimal.Decimal `json:"total_deposits"`
-	TotalWithdrawals    decimal.Decimal `json:"total_withdrawals"`
-	NetCashFlow         decimal.Decimal `json:"net_cash_flow"`
-	Years               float64         `json:"years"`
+	Period              string                    `json:"period,omitempty"`
+	StartDate           time.Time                 `json:"start_date"`
+	EndDate             time.Time                 `json:"end_date"`
+	StartingValue       decimal.Decimal           `json:"starting_value"`
+	EndingValue         decimal.Decimal           `json:"ending_value"`
+	TotalReturn         decimal.Decimal           `json:"total_return"`
+	TotalReturnPct      decimal.Decimal           `json:"total_return_pct"`
+	TimeWeightedReturn  decimal.Decimal           `json:"time_weighted_return"`
+	MoneyWeightedReturn decimal.Decimal           `json:"money_weighted_return"`
+	AnnualizedReturn    decimal.Decimal           `json:"annualized_return"`
+	TotalDeposits       decimal.Decimal           `json:"total_deposits"`
+	TotalWithdrawals    decimal.Decimal           `json:"total_withdrawals"`
+	TotalPurchases      decimal.Decimal           `json:"total_purchases"`
+	TotalSales          decimal.Decimal           `json:"total_sales"`
+	NetCashFlow         decimal.Decimal           `json:"net_cash_flow"`
+	Years               float64                   `json:"years"`
+	TradingDays         int                       `json:"trading_days"`
+	Gains               *GainBreakdown            `json:"gains,omitempty"`
+	TotalFees           decimal.Decimal           `json:"total_fees"`
+	NetReturn           decimal.Decimal           `json:"net_return"`
+	NetReturnPct        decimal.Decimal           `json:"net_return_pct"`
+	NetAnnualizedReturn decimal.Decimal           `json:"net_annualized_return"`
+	TotalIncome         decimal.Decimal           `json:"total_income"`
+	IncomeByCategory    []StatementIncomeCategory `json:"income_by_category"`
 }
 
 // TWRResponse represents Time-Weighted Return response
@@ -68,6 +84,7 @@ type AnnualizedReturnResponse struct {
 	TotalReturnPct   decimal.Decimal `json:"total_return_pct"`
 	AnnualizedReturn decimal.Decimal `json:"annualized_return"`
 	Years            float64         `json:"years"`
+	TradingDays      int             `json:"trading_days"`
 }
 
 // BenchmarkComparisonResponse represents benchmark comparison response
@@ -101,9 +118,41 @@ func ToPerformanceMetricsResponse(metrics *PerformanceMetrics) *PerformanceMetri
 		AnnualizedReturn:    metrics.AnnualizedReturn,
 		TotalDeposits:       metrics.TotalDeposits,
 		TotalWithdrawals:    metrics.TotalWithdrawals,
+		TotalPurchases:      metrics.TotalPurchases,
+		TotalSales:          metrics.TotalSales,
 		NetCashFlow:         metrics.NetCashFlow,
 		Years:               metrics.Years,
+		TradingDays:         metrics.TradingDays,
+		Gains:               metrics.Gains,
+		TotalFees:           metrics.TotalFees,
+		NetReturn:           metrics.NetReturn,
+		NetReturnPct:        metrics.NetReturnPct,
+		NetAnnualizedReturn: metrics.NetAnnualizedReturn,
+		TotalIncome:         metrics.TotalIncome,
+		IncomeByCategory:    metrics.IncomeByCategory,
+	}
+}
+
+// PeriodMetricsResponse lists a portfolio's metrics over the standard periods its snapshots
+// cover, shortest first
+type PeriodMetricsResponse struct {
+	Periods []*PerformanceMetricsResponse `json:"periods"`
+}
+
+// ToPeriodMetricsResponse converts the metrics of each standard period to response DTOs,
+// leaving out the periods without metrics
+func ToPeriodMetricsResponse(metrics map[models.PerformancePeriod]*PerformanceMetrics) *PeriodMetricsResponse {
+	response := &PeriodMetricsResponse{Periods: []*PerformanceMetricsResponse{}}
+	for _, period := range models.StandardPerformancePeriods {
+		periodMetrics, ok := metrics[period]
+		if !ok || periodMetrics == nil {
+			continue
+		}
+		periodResponse := ToPerformanceMetricsResponse(periodMetrics)
+		periodResponse.Period = string(period)
+		response.Periods = append(response.Periods, periodResponse)
 	}
+	return response
 }
 
 // ToTWRResponse converts TWRResult to response DTO
@@ -155,6 +204,7 @@ func ToAnnualizedReturnResponse(result *AnnualizedReturnResult) *AnnualizedRetur
 		TotalReturnPct:   result.TotalReturnPct,
 		AnnualizedReturn: result.AnnualizedReturn,
 		Years:            result.Years,
+		TradingDays:      result.TradingDays,
 	}
 }
 
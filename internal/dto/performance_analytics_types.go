@@ -18,6 +18,19 @@ type TWRResult struct {
 	EndingValue   decimal.Decimal `json:"ending_value"`
 }
 
+// TWRSubPeriod is one linked period of a Time-Weighted Return calculation, between two
+// consecutive valuations
+type TWRSubPeriod struct {
+	StartDate  time.Time       `json:"start_date"`
+	EndDate    time.Time       `json:"end_date"`
+	StartValue decimal.Decimal `json:"start_value"`
+	EndValue   decimal.Decimal `json:"end_value"`
+	CashFlow   decimal.Decimal `json:"cash_flow"`
+	Return     decimal.Decimal `json:"return"`
+	// Excluded is set when the period starts from a zero value and has no defined return
+	Excluded bool `json:"excluded,omitempty"`
+}
+
 // MWRResult represents the Money-Weighted Return (IRR) calculation result
 type MWRResult struct {
 	StartDate     time.Time       `json:"start_date"`
@@ -38,6 +51,8 @@ type AnnualizedReturnResult struct {
 	TotalReturnPct   decimal.Decimal `json:"total_return_pct"`
 	AnnualizedReturn decimal.Decimal `json:"annualized_return"`
 	Years            float64         `json:"years"`
+	// TradingDays counts the NYSE sessions after the start date up to the end date
+	TradingDays int `json:"trading_days"`
 }
 
 // BenchmarkComparisonResult represents portfolio vs benchmark comparison
@@ -64,8 +79,29 @@ type PerformanceMetrics struct {
 	TimeWeightedReturn  decimal.Decimal `json:"time_weighted_return"`
 	MoneyWeightedReturn decimal.Decimal `json:"money_weighted_return"`
 	AnnualizedReturn    decimal.Decimal `json:"annualized_return"`
-	TotalDeposits       decimal.Decimal `json:"total_deposits"`
-	TotalWithdrawals    decimal.Decimal `json:"total_withdrawals"`
-	NetCashFlow         decimal.Decimal `json:"net_cash_flow"`
-	Years               float64         `json:"years"`
+	// TotalDeposits and TotalWithdrawals add up the period's deposit and withdrawal
+	// transactions, the money moved into and out of the portfolio. TotalPurchases and
+	// TotalSales add up its trades, which NetCashFlow nets as the cash flows of the holdings
+	// the returns above are measured on.
+	TotalDeposits    decimal.Decimal `json:"total_deposits"`
+	TotalWithdrawals decimal.Decimal `json:"total_withdrawals"`
+	TotalPurchases   decimal.Decimal `json:"total_purchases"`
+	TotalSales       decimal.Decimal `json:"total_sales"`
+	NetCashFlow      decimal.Decimal `json:"net_cash_flow"`
+	Years            float64         `json:"years"`
+	// TradingDays counts the NYSE sessions after the start date up to the end date
+	TradingDays int `json:"trading_days"`
+	// Gains is how the gain breakdown changed over the period, when both of its snapshots
+	// record one
+	Gains *GainBreakdown `json:"gains,omitempty"`
+	// The returns above are gross of fees. TotalFees adds up the commissions and fee
+	// transactions of the period, and the net returns deduct them from the ending value.
+	TotalFees           decimal.Decimal `json:"total_fees"`
+	NetReturn           decimal.Decimal `json:"net_return"`
+	NetReturnPct        decimal.Decimal `json:"net_return_pct"`
+	NetAnnualizedReturn decimal.Decimal `json:"net_annualized_return"`
+	// TotalIncome adds up the period's dividends, cash and reinvested, and income
+	// transactions, and IncomeByCategory splits it by income category
+	TotalIncome      decimal.Decimal           `json:"total_income"`
+	IncomeByCategory []StatementIncomeCategory `json:"income_by_category"`
 }
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PerformanceCertificationFormat identifies the layout of a certification report so readers
+// can tell which methodology and signing rules it follows
+const PerformanceCertificationFormat = "portfolios.performance-certification/v1"
+
+// PerformanceCertificationRequest represents request parameters for a certification export
+type PerformanceCertificationRequest struct {
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+}
+
+// PerformanceCertification is a signed report of a portfolio's time-weighted performance
+// that carries the inputs and methodology needed to reproduce every figure in it
+type PerformanceCertification struct {
+	Format      string                              `json:"format"`
+	GeneratedAt time.Time                           `json:"generated_at"`
+	Portfolio   CertifiedPortfolio                  `json:"portfolio"`
+	StartDate   time.Time                           `json:"start_date"`
+	EndDate     time.Time                           `json:"end_date"`
+	Results     PerformanceCertificationResults     `json:"results"`
+	Methodology PerformanceCertificationMethodology `json:"methodology"`
+	Signature   CertificationSignature              `json:"signature"`
+}
+
+// CertifiedPortfolio identifies the portfolio a certification covers
+type CertifiedPortfolio struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	BaseCurrency string `json:"base_currency"`
+}
+
+// PerformanceCertificationResults are the headline figures of a certification
+type PerformanceCertificationResults struct {
+	TWR             decimal.Decimal `json:"twr"`
+	TWRPercent      decimal.Decimal `json:"twr_percent"`
+	AnnualizedTWR   decimal.Decimal `json:"annualized_twr"`
+	StartingValue   decimal.Decimal `json:"starting_value"`
+	EndingValue     decimal.Decimal `json:"ending_value"`
+	NetCashFlow     decimal.Decimal `json:"net_cash_flow"`
+	NumSubPeriods   int             `json:"num_sub_periods"`
+	ExcludedPeriods int             `json:"excluded_periods"`
+}
+
+// PerformanceCertificationMethodology describes how the results were computed, along with
+// the sub-periods, cash flows and valuations they were computed from
+type PerformanceCertificationMethodology struct {
+	ReturnMethod      string               `json:"return_method"`
+	Annualization     string               `json:"annualization"`
+	CashFlowTreatment string               `json:"cash_flow_treatment"`
+	ValuationPolicy   string               `json:"valuation_policy"`
+	SubPeriods        []TWRSubPeriod       `json:"sub_periods"`
+	CashFlows         []CertifiedCashFlow  `json:"cash_flows"`
+	Valuations        []CertifiedValuation `json:"valuations"`
+}
+
+// CertifiedCashFlow is an external cash flow used in a certification
+type CertifiedCashFlow struct {
+	TransactionID string          `json:"transaction_id"`
+	Date          time.Time       `json:"date"`
+	Type          string          `json:"type"`
+	Symbol        string          `json:"symbol"`
+	Amount        decimal.Decimal `json:"amount"`
+}
+
+// CertifiedValuation is a portfolio valuation used in a certification, identified by the
+// performance snapshot it was taken from
+type CertifiedValuation struct {
+	SnapshotID     string          `json:"snapshot_id"`
+	Date           time.Time       `json:"date"`
+	TotalValue     decimal.Decimal `json:"total_value"`
+	TotalCostBasis decimal.Decimal `json:"total_cost_basis"`
+	RecordedAt     time.Time       `json:"recorded_at"`
+}
+
+// CertificationSignature lets a report be checked for changes since it was generated
+type CertificationSignature struct {
+	Algorithm string `json:"algorithm"`
+	// ContentDigest is the hex SHA-256 of the report's JSON encoding without its signature
+	ContentDigest string `json:"content_digest"`
+	// Value is the hex HMAC of the same encoding under the server's certification key
+	Value string `json:"value"`
+}
+
+// CertificationVerificationResponse reports whether a certification is unchanged since
+// this server signed it
+type CertificationVerificationResponse struct {
+	Valid         bool   `json:"valid"`
+	ContentDigest string `json:"content_digest"`
+}
@@ -25,9 +25,20 @@ type PerformanceSnapshotResponse struct {
 	TotalReturnPct decimal.Decimal  `json:"total_return_pct"`
 	DayChange      *decimal.Decimal `json:"day_change,omitempty"`
 	DayChangePct   *decimal.Decimal `json:"day_change_pct,omitempty"`
+	Gains          *GainBreakdown   `json:"gains,omitempty"` // Cumulative through the snapshot's date
 	CreatedAt      time.Time        `json:"created_at"`
 }
 
+// GainBreakdown splits a portfolio's return into the gains realized by sales, the unrealized
+// gain of its holdings over their cost basis, the dividends received and the fees paid. The
+// gains are already net of commissions, which fees include along with fee transactions.
+type GainBreakdown struct {
+	RealizedGain   decimal.Decimal `json:"realized_gain"`
+	UnrealizedGain decimal.Decimal `json:"unrealized_gain"`
+	DividendIncome decimal.Decimal `json:"dividend_income"`
+	Fees           decimal.Decimal `json:"fees"`
+}
+
 // PerformanceSnapshotListResponse represents a list of performance snapshots
 type PerformanceSnapshotListResponse struct {
 	Snapshots []*PerformanceSnapshotResponse `json:"snapshots"`
@@ -50,10 +61,39 @@ func ToPerformanceSnapshotResponse(snapshot *models.PerformanceSnapshot) *Perfor
 		TotalReturnPct: snapshot.TotalReturnPct,
 		DayChange:      snapshot.DayChange,
 		DayChangePct:   snapshot.DayChangePct,
+		Gains:          ToGainBreakdown(snapshot),
 		CreatedAt:      snapshot.CreatedAt,
 	}
 }
 
+// ToGainBreakdown returns the gain breakdown a snapshot records, or nil when it has none
+func ToGainBreakdown(snapshot *models.PerformanceSnapshot) *GainBreakdown {
+	if snapshot == nil || !snapshot.HasGainBreakdown() {
+		return nil
+	}
+	return &GainBreakdown{
+		RealizedGain:   *snapshot.RealizedGain,
+		UnrealizedGain: *snapshot.UnrealizedGain,
+		DividendIncome: *snapshot.DividendIncome,
+		Fees:           *snapshot.Fees,
+	}
+}
+
+// GainBreakdownBetween returns how the gain breakdown changed from the start snapshot to the
+// end one, or nil unless both record it
+func GainBreakdownBetween(start, end *models.PerformanceSnapshot) *GainBreakdown {
+	startGains, endGains := ToGainBreakdown(start), ToGainBreakdown(end)
+	if startGains == nil || endGains == nil {
+		return nil
+	}
+	return &GainBreakdown{
+		RealizedGain:   endGains.RealizedGain.Sub(startGains.RealizedGain),
+		UnrealizedGain: endGains.UnrealizedGain.Sub(startGains.UnrealizedGain),
+		DividendIncome: endGains.DividendIncome.Sub(startGains.DividendIncome),
+		Fees:           endGains.Fees.Sub(startGains.Fees),
+	}
+}
+
 // ToPerformanceSnapshotListResponse converts list of models to DTO
 func ToPerformanceSnapshotListResponse(snapshots []*models.PerformanceSnapshot) *PerformanceSnapshotListResponse {
 	response := &PerformanceSnapshotListResponse{
@@ -67,3 +107,35 @@ func ToPerformanceSnapshotListResponse(snapshots []*models.PerformanceSnapshot)
 
 	return response
 }
+
+// SnapshotBackfillRequest represents the query parameters for backfilling a portfolio's daily
+// snapshots. The range defaults to the portfolio's first transaction through the last closed
+// NYSE session.
+type SnapshotBackfillRequest struct {
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02" json:"start_date"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02" json:"end_date"`
+	Restart   bool      `form:"restart" json:"restart"` // Ignore the checkpoint an earlier backfill left
+}
+
+// SnapshotBackfillProgress represents how far a running snapshot backfill has got
+type SnapshotBackfillProgress struct {
+	StartDate         time.Time  `json:"start_date"`
+	EndDate           time.Time  `json:"end_date"`
+	LastCompletedDate *time.Time `json:"last_completed_date,omitempty"`
+	TotalSessions     int        `json:"total_sessions"`
+	CompletedSessions int        `json:"completed_sessions"`
+}
+
+// SnapshotBackfillResult represents the outcome of a snapshot backfill. Sessions that already
+// had a snapshot are left as they were.
+type SnapshotBackfillResult struct {
+	PortfolioID          string     `json:"portfolio_id"`
+	StartDate            time.Time  `json:"start_date"`
+	EndDate              time.Time  `json:"end_date"`
+	ResumedFrom          *time.Time `json:"resumed_from,omitempty"` // Set when an earlier backfill's checkpoint was picked up
+	Sessions             int        `json:"sessions"`
+	Created              int        `json:"created"`
+	Existing             int        `json:"existing"`
+	TransactionsReplayed int        `json:"transactions_replayed"`
+	CompletedAt          time.Time  `json:"completed_at"`
+}
@@ -10,28 +10,39 @@ import (
 
 // CreatePortfolioRequest represents the request to create a new portfolio
 type CreatePortfolioRequest struct {
-	Name            string                 `json:"name" binding:"required,min=1,max=255"`
-	Description     string                 `json:"description,omitempty"`
-	BaseCurrency    string                 `json:"base_currency" binding:"required,len=3"`
-	CostBasisMethod models.CostBasisMethod `json:"cost_basis_method" binding:"required,oneof=FIFO LIFO SPECIFIC_LOT"`
+	Name        string `json:"name" binding:"required,min=1,max=255"`
+	Description string `json:"description,omitempty"`
+	// BaseCurrency and CostBasisMethod default to the display currency and default cost basis
+	// method of the user's settings
+	BaseCurrency    string                 `json:"base_currency,omitempty" binding:"omitempty,len=3"`
+	CostBasisMethod models.CostBasisMethod `json:"cost_basis_method,omitempty" binding:"omitempty,oneof=FIFO LIFO SPECIFIC_LOT"`
+	Tags            []string               `json:"tags,omitempty" binding:"max=20"`
 }
 
 // UpdatePortfolioRequest represents the request to update a portfolio
 type UpdatePortfolioRequest struct {
 	Name        string `json:"name,omitempty" binding:"omitempty,min=1,max=255"`
 	Description string `json:"description,omitempty"`
+	// Tags replaces the portfolio's tags when set; an empty list removes them all
+	Tags *[]string `json:"tags,omitempty" binding:"omitempty,max=20"`
+	// Version is the portfolio version the update was made against; the If-Match header takes precedence
+	Version *int `json:"version,omitempty" binding:"omitempty,min=1"`
 }
 
 // PortfolioResponse represents a portfolio in API responses
 type PortfolioResponse struct {
-	ID              uuid.UUID              `json:"id"`
-	UserID          uuid.UUID              `json:"user_id"`
-	Name            string                 `json:"name"`
-	Description     string                 `json:"description,omitempty"`
-	BaseCurrency    string                 `json:"base_currency"`
-	CostBasisMethod models.CostBasisMethod `json:"cost_basis_method"`
-	CreatedAt       time.Time              `json:"created_at"`
-	UpdatedAt       time.Time              `json:"updated_at"`
+	ID                  uuid.UUID              `json:"id"`
+	UserID              uuid.UUID              `json:"user_id"`
+	Name                string                 `json:"name"`
+	Description         string                 `json:"description,omitempty"`
+	BaseCurrency        string                 `json:"base_currency"`
+	CostBasisMethod     models.CostBasisMethod `json:"cost_basis_method"`
+	TaxRegime           models.TaxRegime       `json:"tax_regime"`
+	PeerComparisonOptIn bool                   `json:"peer_comparison_opt_in"`
+	Tags                []string               `json:"tags"`
+	Version             int                    `json:"version"`
+	CreatedAt           time.Time              `json:"created_at"`
+	UpdatedAt           time.Time              `json:"updated_at"`
 }
 
 // PortfolioListResponse represents a list of portfolios
@@ -47,14 +58,18 @@ func ToPortfolioResponse(portfolio *models.Portfolio) *PortfolioResponse {
 	}
 
 	return &PortfolioResponse{
-		ID:              portfolio.ID,
-		UserID:          portfolio.UserID,
-		Name:            portfolio.Name,
-		Description:     portfolio.Description,
-		BaseCurrency:    portfolio.BaseCurrency,
-		CostBasisMethod: portfolio.CostBasisMethod,
-		CreatedAt:       portfolio.CreatedAt,
-		UpdatedAt:       portfolio.UpdatedAt,
+		ID:                  portfolio.ID,
+		UserID:              portfolio.UserID,
+		Name:                portfolio.Name,
+		Description:         portfolio.Description,
+		BaseCurrency:        portfolio.BaseCurrency,
+		CostBasisMethod:     portfolio.CostBasisMethod,
+		TaxRegime:           portfolio.TaxRegime,
+		PeerComparisonOptIn: portfolio.PeerComparisonOptIn,
+		Tags:                []string{},
+		Version:             portfolio.Version,
+		CreatedAt:           portfolio.CreatedAt,
+		UpdatedAt:           portfolio.UpdatedAt,
 	}
 }
 
@@ -71,3 +86,25 @@ func ToPortfolioListResponse(portfolios []*models.Portfolio) *PortfolioListRespo
 
 	return response
 }
+
+// ToPortfolioResponseWithTags converts a Portfolio model to a PortfolioResponse DTO listing
+// the portfolio's tags
+func ToPortfolioResponseWithTags(portfolio *models.Portfolio, tags []string) *PortfolioResponse {
+	response := ToPortfolioResponse(portfolio)
+	if response != nil && tags != nil {
+		response.Tags = tags
+	}
+	return response
+}
+
+// ToPortfolioListResponseWithTags converts a list of Portfolio models to a
+// PortfolioListResponse DTO listing each portfolio's tags
+func ToPortfolioListResponseWithTags(portfolios []*models.Portfolio, tags map[uuid.UUID][]string) *PortfolioListResponse {
+	response := ToPortfolioListResponse(portfolios)
+	for i, portfolio := range portfolios {
+		if portfolioTags, ok := tags[portfolio.ID]; ok {
+			response.Portfolios[i].Tags = portfolioTags
+		}
+	}
+	return response
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // PortfolioActionResponse represents a pending corporate action for a portfolio
@@ -35,9 +37,15 @@ type CorporateActionResponse struct {
 	CreatedAt   time.Time        `json:"created_at"`
 }
 
-// ApproveActionRequest represents a request to approve a pending action
+// ApproveActionRequest represents a request to approve a pending action. For spinoffs,
+// SpinoffAllocationMethod decides the share of the parent's cost basis moved to the spinoff
+// shares: EXPLICIT takes SpinoffCostBasisAllocation, between 0 and 1 exclusive,
+// FAIR_MARKET_VALUE computes it from closing prices, and DEFAULT moves 0.10. Without a
+// method, a given allocation is explicit and otherwise the default is used.
 type ApproveActionRequest struct {
-	Notes string `json:"notes,omitempty"`
+	Notes                      string                         `json:"notes,omitempty"`
+	SpinoffAllocationMethod    models.SpinoffAllocationMethod `json:"spinoff_allocation_method,omitempty" binding:"omitempty,oneof=DEFAULT EXPLICIT FAIR_MARKET_VALUE"`
+	SpinoffCostBasisAllocation *decimal.Decimal               `json:"spinoff_cost_basis_allocation,omitempty"`
 }
 
 // RejectActionRequest represents a request to reject a pending action
@@ -53,3 +61,38 @@ type PortfolioActionSummary struct {
 	RejectedCount int    `json:"rejected_count"`
 	AppliedCount  int    `json:"applied_count"`
 }
+
+// CorporateActionCalendarRequest represents the period of a corporate action calendar,
+// which defaults to the next 90 days
+type CorporateActionCalendarRequest struct {
+	From time.Time `form:"from" time_format:"2006-01-02"`
+	To   time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+// CorporateActionCalendarEvent is a corporate action of a held symbol. Source is STORED for
+// actions already recorded and PROVIDER for those only the market data provider announced.
+type CorporateActionCalendarEvent struct {
+	Symbol      string           `json:"symbol"`
+	Type        string           `json:"type"`
+	Date        time.Time        `json:"date"`
+	Ratio       *decimal.Decimal `json:"ratio,omitempty"`
+	Amount      *decimal.Decimal `json:"amount,omitempty"`
+	NewSymbol   *string          `json:"new_symbol,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Source      string           `json:"source"`
+	Applied     bool             `json:"applied"`
+	// SharesHeld is the portfolio's current position in the symbol
+	SharesHeld decimal.Decimal `json:"shares_held"`
+	// EstimatedIncome is a cash dividend's amount on the shares held today
+	EstimatedIncome *decimal.Decimal `json:"estimated_income,omitempty"`
+}
+
+// CorporateActionCalendarResponse lists the corporate actions of a portfolio's held symbols
+// in a period, oldest first. Warnings name symbols the provider couldn't be asked about.
+type CorporateActionCalendarResponse struct {
+	PortfolioID string                          `json:"portfolio_id"`
+	From        time.Time                       `json:"from"`
+	To          time.Time                       `json:"to"`
+	Events      []*CorporateActionCalendarEvent `json:"events"`
+	Warnings    []string                        `json:"warnings,omitempty"`
+}
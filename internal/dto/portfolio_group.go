@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// CreatePortfolioGroupRequest represents the request to create a portfolio group
+type CreatePortfolioGroupRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description,omitempty" binding:"max=500"`
+	// ParentID nests the group under another of the user's groups
+	ParentID     *string  `json:"parent_id,omitempty" binding:"omitempty,uuid"`
+	PortfolioIDs []string `json:"portfolio_ids,omitempty" binding:"omitempty,dive,uuid"`
+}
+
+// UpdatePortfolioGroupRequest represents the request to change a portfolio group. Fields that
+// are not set are left unchanged; an empty parent_id moves the group to the top level.
+type UpdatePortfolioGroupRequest struct {
+	Name         *string   `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	Description  *string   `json:"description,omitempty" binding:"omitempty,max=500"`
+	ParentID     *string   `json:"parent_id,omitempty" binding:"omitempty,uuid"`
+	PortfolioIDs *[]string `json:"portfolio_ids,omitempty" binding:"omitempty,dive,uuid"`
+}
+
+// PortfolioGroupResponse represents a portfolio group in API responses
+type PortfolioGroupResponse struct {
+	ID           uuid.UUID   `json:"id"`
+	ParentID     *uuid.UUID  `json:"parent_id,omitempty"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description,omitempty"`
+	PortfolioIDs []uuid.UUID `json:"portfolio_ids"`
+	SubgroupIDs  []uuid.UUID `json:"subgroup_ids"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// PortfolioGroupListResponse represents a user's portfolio groups
+type PortfolioGroupListResponse struct {
+	Groups []*PortfolioGroupResponse `json:"groups"`
+	Total  int                       `json:"total"`
+}
+
+// PortfolioGroupOverview is the roll-up of a group's portfolios, including those of its
+// subgroups
+type PortfolioGroupOverview struct {
+	Group *PortfolioGroupResponse `json:"group"`
+	*Overview
+}
+
+// PortfolioGroupBenchmarkRequest represents the query parameters of a group's benchmark
+// comparison
+type PortfolioGroupBenchmarkRequest struct {
+	Currency        string    `form:"currency" binding:"omitempty,len=3"`
+	StartDate       time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate         time.Time `form:"end_date" time_format:"2006-01-02"`
+	BenchmarkSymbol string    `form:"benchmark_symbol"`
+}
+
+// ToPortfolioGroupResponse converts a PortfolioGroup model to a PortfolioGroupResponse DTO
+func ToPortfolioGroupResponse(group *models.PortfolioGroup) *PortfolioGroupResponse {
+	response := &PortfolioGroupResponse{
+		ID:           group.ID,
+		ParentID:     group.ParentID,
+		Name:         group.Name,
+		Description:  group.Description,
+		PortfolioIDs: group.PortfolioIDs(),
+		SubgroupIDs:  group.SubgroupIDs,
+		CreatedAt:    group.CreatedAt,
+		UpdatedAt:    group.UpdatedAt,
+	}
+	if response.SubgroupIDs == nil {
+		response.SubgroupIDs = []uuid.UUID{}
+	}
+	return response
+}
+
+// ToPortfolioGroupListResponse converts a user's portfolio groups to a
+// PortfolioGroupListResponse DTO
+func ToPortfolioGroupListResponse(groups []*models.PortfolioGroup) *PortfolioGroupListResponse {
+	response := &PortfolioGroupListResponse{
+		Groups: make([]*PortfolioGroupResponse, 0, len(groups)),
+		Total:  len(groups),
+	}
+	for _, group := range groups {
+		response.Groups = append(response.Groups, ToPortfolioGroupResponse(group))
+	}
+	return response
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// ProjectionRequest describes a simulation of a portfolio's future value. The horizon is given
+// either in months or as a target date. Returns and volatility are annual percentages. Method
+// defaults to EXPECTED_RETURN when an expected return is given and HISTORICAL otherwise, and
+// the starting value to the portfolio's latest snapshot. A seed makes the simulation repeatable.
+type ProjectionRequest struct {
+	Method              models.ProjectionMethod `json:"method,omitempty"`
+	MonthlyContribution decimal.Decimal         `json:"monthly_contribution"`
+	HorizonMonths       int                     `json:"horizon_months,omitempty" binding:"omitempty,min=1,max=600"`
+	TargetDate          *time.Time              `json:"target_date,omitempty"`
+	ExpectedReturn      *decimal.Decimal        `json:"expected_return,omitempty"`
+	Volatility          decimal.Decimal         `json:"volatility"`
+	StartingValue       *decimal.Decimal        `json:"starting_value,omitempty"`
+	TargetValue         *decimal.Decimal        `json:"target_value,omitempty"`
+	Simulations         int                     `json:"simulations,omitempty" binding:"omitempty,min=100,max=10000"`
+	Seed                *int64                  `json:"seed,omitempty"`
+}
+
+// ProjectionResult is the simulated distribution of a portfolio's value at the end of each
+// month of the horizon, for charting percentile bands. ExpectedReturn and Volatility are the
+// annual figures used, measured from the portfolio's history for HISTORICAL projections.
+// TargetProbability is the percentage of simulated paths ending at or above TargetValue.
+type ProjectionResult struct {
+	PortfolioID         string                  `json:"portfolio_id"`
+	Method              models.ProjectionMethod `json:"method"`
+	StartDate           time.Time               `json:"start_date"`
+	EndDate             time.Time               `json:"end_date"`
+	HorizonMonths       int                     `json:"horizon_months"`
+	StartingValue       decimal.Decimal         `json:"starting_value"`
+	MonthlyContribution decimal.Decimal         `json:"monthly_contribution"`
+	TotalContributed    decimal.Decimal         `json:"total_contributed"`
+	ExpectedReturn      decimal.Decimal         `json:"expected_return"`
+	Volatility          decimal.Decimal         `json:"volatility"`
+	HistoryMonths       int                     `json:"history_months,omitempty"`
+	Simulations         int                     `json:"simulations"`
+	Bands               []models.ProjectionBand `json:"bands"`
+	TargetValue         *decimal.Decimal        `json:"target_value,omitempty"`
+	TargetProbability   *decimal.Decimal        `json:"target_probability,omitempty"`
+}
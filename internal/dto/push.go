@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// RegisterPushDeviceRequest represents a request to register a device for push notifications.
+// A browser sends the JSON of its PushSubscription; an iOS app sends its hex device token as
+// the endpoint.
+type RegisterPushDeviceRequest struct {
+	Platform models.PushPlatform  `json:"platform" binding:"required"`
+	Endpoint string               `json:"endpoint" binding:"required,max=2048"`
+	Keys     PushSubscriptionKeys `json:"keys"`
+	Name     string               `json:"name,omitempty" binding:"max=100"` // e.g. "Firefox on laptop"
+}
+
+// PushSubscriptionKeys are the encryption keys of a browser's push subscription
+type PushSubscriptionKeys struct {
+	P256dh string `json:"p256dh" binding:"max=128"`
+	Auth   string `json:"auth" binding:"max=64"`
+}
+
+// PushDeviceResponse represents a device registered for push notifications
+type PushDeviceResponse struct {
+	ID         string              `json:"id"`
+	Platform   models.PushPlatform `json:"platform"`
+	Endpoint   string              `json:"endpoint"`
+	Name       string              `json:"name,omitempty"`
+	CreatedAt  time.Time           `json:"created_at"`
+	LastUsedAt *time.Time          `json:"last_used_at,omitempty"`
+}
+
+// VAPIDPublicKeyResponse carries the key browsers subscribe with, as the
+// applicationServerKey of PushManager.subscribe
+type VAPIDPublicKeyResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// ToPushDeviceResponse converts a PushDevice model to a response DTO
+func ToPushDeviceResponse(device *models.PushDevice) *PushDeviceResponse {
+	return &PushDeviceResponse{
+		ID:         device.ID.String(),
+		Platform:   device.Platform,
+		Endpoint:   device.Endpoint,
+		Name:       device.Name,
+		CreatedAt:  device.CreatedAt,
+		LastUsedAt: device.LastUsedAt,
+	}
+}
@@ -0,0 +1,127 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// RebalanceTradeRequest represents a planned trade in a rebalance plan request
+type RebalanceTradeRequest struct {
+	Symbol   string                 `json:"symbol" binding:"required,min=1,max=20"`
+	Side     models.TransactionType `json:"side" binding:"required,oneof=BUY SELL"`
+	Quantity decimal.Decimal        `json:"quantity" binding:"required"`
+	Price    decimal.Decimal        `json:"price" binding:"required"`
+}
+
+// CreateRebalancePlanRequest represents the request to save a rebalancing execution plan
+type CreateRebalancePlanRequest struct {
+	Name           string                  `json:"name" binding:"required,min=1,max=255"`
+	Notes          string                  `json:"notes,omitempty"`
+	StaleAfterDays int                     `json:"stale_after_days" binding:"min=0,max=365"`
+	Trades         []RebalanceTradeRequest `json:"trades" binding:"required,min=1,dive"`
+}
+
+// ExecuteRebalanceTradeRequest represents the actual fill of a planned trade.
+// Either transaction_id of an existing transaction, or the fill details, must be given.
+type ExecuteRebalanceTradeRequest struct {
+	TransactionID string          `json:"transaction_id,omitempty" binding:"omitempty,uuid"`
+	Date          time.Time       `json:"date"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Price         decimal.Decimal `json:"price"`
+	Commission    decimal.Decimal `json:"commission"`
+}
+
+// RebalanceTradeResponse represents a planned trade and its execution in API responses
+type RebalanceTradeResponse struct {
+	ID               string                      `json:"id"`
+	Symbol           string                      `json:"symbol"`
+	Side             models.TransactionType      `json:"side"`
+	Status           models.RebalanceTradeStatus `json:"status"`
+	PlannedQuantity  decimal.Decimal             `json:"planned_quantity"`
+	PlannedPrice     decimal.Decimal             `json:"planned_price"`
+	PlannedAmount    decimal.Decimal             `json:"planned_amount"`
+	ExecutedQuantity *decimal.Decimal            `json:"executed_quantity,omitempty"`
+	ExecutedPrice    *decimal.Decimal            `json:"executed_price,omitempty"`
+	ExecutedAmount   *decimal.Decimal            `json:"executed_amount,omitempty"`
+	ExecutedAt       *time.Time                  `json:"executed_at,omitempty"`
+	TransactionID    *string                     `json:"transaction_id,omitempty"`
+	QuantityDrift    *decimal.Decimal            `json:"quantity_drift,omitempty"`
+	PriceDrift       *decimal.Decimal            `json:"price_drift,omitempty"`
+	AmountDrift      *decimal.Decimal            `json:"amount_drift,omitempty"`
+}
+
+// RebalancePlanResponse represents a rebalance plan in API responses
+type RebalancePlanResponse struct {
+	ID             string                     `json:"id"`
+	PortfolioID    string                     `json:"portfolio_id"`
+	Name           string                     `json:"name"`
+	Notes          string                     `json:"notes,omitempty"`
+	Status         models.RebalancePlanStatus `json:"status"`
+	StaleAfterDays int                        `json:"stale_after_days"`
+	Stale          bool                       `json:"stale"`
+	LastActivityAt time.Time                  `json:"last_activity_at"`
+	LastReminderAt *time.Time                 `json:"last_reminder_at,omitempty"`
+	ClosedAt       *time.Time                 `json:"closed_at,omitempty"`
+	Drift          models.RebalancePlanDrift  `json:"drift"`
+	Trades         []*RebalanceTradeResponse  `json:"trades"`
+	CreatedAt      time.Time                  `json:"created_at"`
+	UpdatedAt      time.Time                  `json:"updated_at"`
+}
+
+// ToRebalancePlanResponse converts a RebalancePlan model to a RebalancePlanResponse DTO
+func ToRebalancePlanResponse(plan *models.RebalancePlan) *RebalancePlanResponse {
+	response := &RebalancePlanResponse{
+		ID:             plan.ID.String(),
+		PortfolioID:    plan.PortfolioID.String(),
+		Name:           plan.Name,
+		Notes:          plan.Notes,
+		Status:         plan.Status,
+		StaleAfterDays: plan.StaleAfterDays,
+		Stale:          plan.IsStale(time.Now().UTC()),
+		LastActivityAt: plan.LastActivityAt(),
+		LastReminderAt: plan.LastReminderAt,
+		ClosedAt:       plan.ClosedAt,
+		Drift:          plan.Drift(),
+		Trades:         make([]*RebalanceTradeResponse, len(plan.Trades)),
+		CreatedAt:      plan.CreatedAt,
+		UpdatedAt:      plan.UpdatedAt,
+	}
+	for i, trade := range plan.Trades {
+		response.Trades[i] = ToRebalanceTradeResponse(trade)
+	}
+	return response
+}
+
+// ToRebalanceTradeResponse converts a RebalancePlanTrade model to a RebalanceTradeResponse DTO
+func ToRebalanceTradeResponse(trade *models.RebalancePlanTrade) *RebalanceTradeResponse {
+	response := &RebalanceTradeResponse{
+		ID:               trade.ID.String(),
+		Symbol:           trade.Symbol,
+		Side:             trade.Side,
+		Status:           trade.Status,
+		PlannedQuantity:  trade.PlannedQuantity,
+		PlannedPrice:     trade.PlannedPrice,
+		PlannedAmount:    trade.PlannedAmount(),
+		ExecutedQuantity: trade.ExecutedQuantity,
+		ExecutedPrice:    trade.ExecutedPrice,
+		ExecutedAt:       trade.ExecutedAt,
+	}
+	if trade.TransactionID != nil {
+		transactionID := trade.TransactionID.String()
+		response.TransactionID = &transactionID
+	}
+	if trade.Status == models.RebalanceTradeStatusExecuted {
+		executedAmount := trade.ExecutedAmount()
+		quantityDrift := trade.QuantityDrift()
+		priceDrift := trade.PriceDrift()
+		amountDrift := trade.AmountDrift()
+		response.ExecutedAmount = &executedAmount
+		response.QuantityDrift = &quantityDrift
+		response.PriceDrift = &priceDrift
+		response.AmountDrift = &amountDrift
+	}
+	return response
+}
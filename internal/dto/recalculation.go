@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// RecalculationRequest represents the query parameters for recalculating a portfolio
+type RecalculationRequest struct {
+	DryRun bool `form:"dry_run" json:"dry_run"`
+}
+
+// RecalculationDiscrepancyType identifies how a stored position differs from the rebuilt one
+type RecalculationDiscrepancyType string
+
+const (
+	// DiscrepancyMissingHolding means the ledger produces a holding that isn't stored
+	DiscrepancyMissingHolding RecalculationDiscrepancyType = "MISSING_HOLDING"
+	// DiscrepancyUnexpectedHolding means a holding is stored that the ledger doesn't produce
+	DiscrepancyUnexpectedHolding RecalculationDiscrepancyType = "UNEXPECTED_HOLDING"
+	// DiscrepancyHoldingMismatch means the stored holding's quantity or cost basis differs
+	DiscrepancyHoldingMismatch RecalculationDiscrepancyType = "HOLDING_MISMATCH"
+	// DiscrepancyTaxLotMismatch means the stored tax lots differ in count, quantity, or cost basis
+	DiscrepancyTaxLotMismatch RecalculationDiscrepancyType = "TAX_LOT_MISMATCH"
+)
+
+// RecalculationDiscrepancy represents one difference between stored and rebuilt state.
+// For tax lot mismatches the quantities and cost bases are totals across the symbol's lots.
+type RecalculationDiscrepancy struct {
+	Symbol           string                       `json:"symbol"`
+	Type             RecalculationDiscrepancyType `json:"type"`
+	StoredQuantity   decimal.Decimal              `json:"stored_quantity"`
+	RebuiltQuantity  decimal.Decimal              `json:"rebuilt_quantity"`
+	StoredCostBasis  decimal.Decimal              `json:"stored_cost_basis"`
+	RebuiltCostBasis decimal.Decimal              `json:"rebuilt_cost_basis"`
+	StoredTaxLots    int                          `json:"stored_tax_lots,omitempty"`
+	RebuiltTaxLots   int                          `json:"rebuilt_tax_lots,omitempty"`
+}
+
+// RecalculationReport represents the outcome of rebuilding a portfolio from its transactions
+type RecalculationReport struct {
+	PortfolioID          string                     `json:"portfolio_id"`
+	DryRun               bool                       `json:"dry_run"`
+	TransactionsReplayed int                        `json:"transactions_replayed"`
+	Holdings             int                        `json:"holdings"`
+	TaxLots              int                        `json:"tax_lots"`
+	Discrepancies        []RecalculationDiscrepancy `json:"discrepancies"`
+	RecalculatedAt       time.Time                  `json:"recalculated_at"`
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// ReconcileRequest represents a statement of the positions a portfolio's account holds, to
+// compare with its holdings. The positions are given either as a list or as a CSV file with
+// symbol and quantity columns and an optional cost basis column.
+type ReconcileRequest struct {
+	Positions []StatementPosition `json:"positions,omitempty" binding:"required_without=CSVData,omitempty,max=1000,dive"`
+	CSVData   string              `json:"csv_data,omitempty" binding:"required_without=Positions"` // Base64 encoded CSV data or raw CSV text
+	// CreateAdjustments records a transaction for each quantity discrepancy that brings the
+	// holding in line with the statement
+	CreateAdjustments bool `json:"create_adjustments"`
+}
+
+// StatementPosition is one position of a statement. A symbol listed more than once is
+// counted once with the quantities and cost bases added up.
+type StatementPosition struct {
+	Symbol    string           `json:"symbol" binding:"required,max=20"`
+	Quantity  decimal.Decimal  `json:"quantity"`
+	CostBasis *decimal.Decimal `json:"cost_basis,omitempty"` // The position's total cost, if the statement has it
+}
+
+// ReconciliationReport lists the symbols whose quantity or cost basis differs between a
+// statement and the portfolio's holdings, as they stood before any adjustment was made
+type ReconciliationReport struct {
+	PortfolioID   uuid.UUID             `json:"portfolio_id"`
+	ReconciledAt  time.Time             `json:"reconciled_at"`
+	Positions     int                   `json:"positions"` // Symbols compared, from the statement or the holdings
+	Matched       int                   `json:"matched"`
+	Discrepancies []PositionDiscrepancy `json:"discrepancies"`
+	BatchID       *uuid.UUID            `json:"batch_id,omitempty"` // Import batch of the adjustments created
+	Adjusted      int                   `json:"adjusted"`
+	Errors        []ImportError         `json:"errors,omitempty"` // Adjustments that couldn't be created
+}
+
+// PositionDiscrepancy is a symbol whose quantity or cost basis differs between a statement
+// and the portfolio's holdings
+type PositionDiscrepancy struct {
+	Symbol             string          `json:"symbol"`
+	StatementQuantity  decimal.Decimal `json:"statement_quantity"`
+	HoldingQuantity    decimal.Decimal `json:"holding_quantity"`
+	QuantityDifference decimal.Decimal `json:"quantity_difference"` // Statement quantity less the holding's
+	// The cost bases are compared only when the statement has one for the symbol
+	StatementCostBasis  *decimal.Decimal `json:"statement_cost_basis,omitempty"`
+	HoldingCostBasis    decimal.Decimal  `json:"holding_cost_basis"`
+	CostBasisDifference *decimal.Decimal `json:"cost_basis_difference,omitempty"` // Statement cost basis less the holding's
+	// Adjustment is the transaction that brings the holding's quantity in line with the
+	// statement: created when the request asks for adjustments, otherwise proposed
+	Adjustment *ReconciliationAdjustment `json:"adjustment,omitempty"`
+}
+
+// ReconciliationAdjustment is a transaction correcting a holding's quantity
+type ReconciliationAdjustment struct {
+	Type     models.TransactionType `json:"type"`
+	Quantity decimal.Decimal        `json:"quantity"`
+	Price    decimal.Decimal        `json:"price"`
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// CreateReportSubscriptionRequest represents the request to subscribe to a performance digest
+type CreateReportSubscriptionRequest struct {
+	Frequency models.ReportFrequency `json:"frequency" binding:"required,oneof=WEEKLY MONTHLY"`
+	// PortfolioIDs limits the digest to these portfolios; empty covers all of the user's
+	PortfolioIDs []string `json:"portfolio_ids,omitempty" binding:"omitempty,dive,uuid"`
+}
+
+// UpdateReportSubscriptionRequest represents the request to change a report subscription.
+// Fields that are not set are left unchanged.
+type UpdateReportSubscriptionRequest struct {
+	Frequency    *models.ReportFrequency `json:"frequency,omitempty" binding:"omitempty,oneof=WEEKLY MONTHLY"`
+	PortfolioIDs *[]string               `json:"portfolio_ids,omitempty" binding:"omitempty,dive,uuid"`
+	Enabled      *bool                   `json:"enabled,omitempty"`
+}
+
+// ReportSubscriptionResponse represents a report subscription in API responses
+type ReportSubscriptionResponse struct {
+	ID           string                 `json:"id"`
+	Frequency    models.ReportFrequency `json:"frequency"`
+	Enabled      bool                   `json:"enabled"`
+	PortfolioIDs []string               `json:"portfolio_ids"` // Empty when all portfolios are covered
+	LastSentAt   *time.Time             `json:"last_sent_at,omitempty"`
+	NextDigestAt *time.Time             `json:"next_digest_at,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+}
+
+// ReportSubscriptionListResponse represents a user's report subscriptions
+type ReportSubscriptionListResponse struct {
+	Subscriptions []*ReportSubscriptionResponse `json:"subscriptions"`
+}
+
+// ToReportSubscriptionResponse converts a ReportSubscription model to a
+// ReportSubscriptionResponse DTO
+func ToReportSubscriptionResponse(subscription *models.ReportSubscription) *ReportSubscriptionResponse {
+	return &ReportSubscriptionResponse{
+		ID:           subscription.ID.String(),
+		Frequency:    subscription.Frequency,
+		Enabled:      subscription.Enabled,
+		PortfolioIDs: subscription.PortfolioIDs(),
+		LastSentAt:   subscription.LastSentAt,
+		NextDigestAt: subscription.NextDigestAt(time.Now().UTC()),
+		CreatedAt:    subscription.CreatedAt,
+		UpdatedAt:    subscription.UpdatedAt,
+	}
+}
+
+// PerformanceDigest summarizes a user's portfolios over a week or month for an emailed report
+type PerformanceDigest struct {
+	Frequency  models.ReportFrequency `json:"frequency"`
+	Locale     string                 `json:"locale"` // The user's locale, which the emailed amounts are written in
+	StartDate  time.Time              `json:"start_date"`
+	EndDate    time.Time              `json:"end_date"` // Last day covered
+	Portfolios []DigestPortfolio      `json:"portfolios"`
+	TopMovers  []DigestMover          `json:"top_movers"`
+}
+
+// DigestPortfolio is one portfolio's value change and income over the digest period, in its
+// base currency
+type DigestPortfolio struct {
+	ID           string               `json:"id"`
+	Name         string               `json:"name"`
+	BaseCurrency string               `json:"base_currency"`
+	Performance  StatementPerformance `json:"performance"`
+	ValueChange  *decimal.Decimal     `json:"value_change,omitempty"` // Ending less starting value
+	Income       decimal.Decimal      `json:"income"`
+}
+
+// DigestMover is a held symbol whose price moved the most over the digest period
+type DigestMover struct {
+	Symbol        string          `json:"symbol"`
+	ChangePercent decimal.Decimal `json:"change_percent"`
+	ValueChange   decimal.Decimal `json:"value_change"` // Price change times the quantity held now
+}
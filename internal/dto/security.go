@@ -0,0 +1,49 @@
+package dto
+
+import "github.com/lenon/portfolios/internal/models"
+
+// SecuritySearchRequest represents the query parameters of a symbol search
+type SecuritySearchRequest struct {
+	Query string `form:"q" binding:"required"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=25"`
+}
+
+// SecurityResponse represents a security in API responses
+type SecurityResponse struct {
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Exchange string `json:"exchange"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+}
+
+// SecuritySearchResponse represents the securities matching a symbol search
+type SecuritySearchResponse struct {
+	Query      string              `json:"query"`
+	Securities []*SecurityResponse `json:"securities"`
+	Total      int                 `json:"total"`
+}
+
+// ToSecurityResponse converts a security to SecurityResponse DTO
+func ToSecurityResponse(security *models.Security) *SecurityResponse {
+	return &SecurityResponse{
+		Symbol:   security.Symbol,
+		Name:     security.Name,
+		Exchange: security.Exchange,
+		Type:     security.Type,
+		Currency: security.Currency,
+	}
+}
+
+// ToSecuritySearchResponse converts the securities matching query to SecuritySearchResponse DTO
+func ToSecuritySearchResponse(query string, securities []*models.Security) *SecuritySearchResponse {
+	response := &SecuritySearchResponse{
+		Query:      query,
+		Securities: make([]*SecurityResponse, len(securities)),
+		Total:      len(securities),
+	}
+	for i, security := range securities {
+		response.Securities[i] = ToSecurityResponse(security)
+	}
+	return response
+}
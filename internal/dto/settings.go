@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// UpdateUserSettingsRequest represents the request to change the user's settings. Fields that
+// are not set are left unchanged.
+type UpdateUserSettingsRequest struct {
+	DisplayCurrency        *string                 `json:"display_currency,omitempty" binding:"omitempty,len=3"`
+	Timezone               *string                 `json:"timezone,omitempty" binding:"omitempty,max=64"` // IANA name, such as Europe/Berlin
+	Locale                 *string                 `json:"locale,omitempty" binding:"omitempty,max=35"`   // BCP 47 tag, such as de-DE
+	DefaultCostBasisMethod *models.CostBasisMethod `json:"default_cost_basis_method,omitempty" binding:"omitempty,oneof=FIFO LIFO SPECIFIC_LOT"`
+	NotifyCorporateActions *bool                   `json:"notify_corporate_actions,omitempty"`
+	NotifyImports          *bool                   `json:"notify_imports,omitempty"`
+	NotifyReports          *bool                   `json:"notify_reports,omitempty"`
+	PushNotifications      *bool                   `json:"push_notifications,omitempty"`
+}
+
+// UserSettingsResponse represents the user's settings in API responses
+type UserSettingsResponse struct {
+	DisplayCurrency        string                 `json:"display_currency"`
+	Timezone               string                 `json:"timezone"`
+	Locale                 string                 `json:"locale"`
+	DefaultCostBasisMethod models.CostBasisMethod `json:"default_cost_basis_method"`
+	NotifyCorporateActions bool                   `json:"notify_corporate_actions"`
+	NotifyImports          bool                   `json:"notify_imports"`
+	NotifyReports          bool                   `json:"notify_reports"`
+	PushNotifications      bool                   `json:"push_notifications"`
+	UpdatedAt              *time.Time             `json:"updated_at,omitempty"` // Not set until the settings are first saved
+}
+
+// ToUserSettingsResponse converts a UserSettings model to a response DTO
+func ToUserSettingsResponse(settings *models.UserSettings) *UserSettingsResponse {
+	response := &UserSettingsResponse{
+		DisplayCurrency:        settings.DisplayCurrency,
+		Timezone:               settings.Timezone,
+		Locale:                 settings.Locale,
+		DefaultCostBasisMethod: settings.DefaultCostBasisMethod,
+		NotifyCorporateActions: settings.NotifyCorporateActions,
+		NotifyImports:          settings.NotifyImports,
+		NotifyReports:          settings.NotifyReports,
+		PushNotifications:      settings.PushNotifications,
+	}
+	if !settings.UpdatedAt.IsZero() {
+		response.UpdatedAt = &settings.UpdatedAt
+	}
+	return response
+}
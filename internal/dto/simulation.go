@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// SimulationRequest describes a Monte Carlo simulation of withdrawing from a portfolio for a
+// number of years. The annual withdrawal is taken in monthly installments and grows with
+// inflation every year. Returns, volatility and inflation are annual percentages; the return
+// and volatility default to those measured from the portfolio's snapshots, and the starting
+// value to its latest snapshot. A seed makes the simulation repeatable.
+type SimulationRequest struct {
+	AnnualWithdrawal decimal.Decimal  `json:"annual_withdrawal"`
+	Years            int              `json:"years" binding:"required,min=1,max=60"`
+	InflationRate    decimal.Decimal  `json:"inflation_rate"`
+	StartingValue    *decimal.Decimal `json:"starting_value,omitempty"`
+	ExpectedReturn   *decimal.Decimal `json:"expected_return,omitempty"`
+	Volatility       *decimal.Decimal `json:"volatility,omitempty"`
+	Paths            int              `json:"paths,omitempty" binding:"omitempty,min=100,max=10000"`
+	Seed             *int64           `json:"seed,omitempty"`
+}
+
+// SimulationParameters are the inputs a simulation ran with, with the defaults filled in.
+// HistoryMonths is the number of monthly returns the return and volatility were measured
+// from, if they were.
+type SimulationParameters struct {
+	AnnualWithdrawal decimal.Decimal `json:"annual_withdrawal"`
+	Years            int             `json:"years"`
+	InflationRate    decimal.Decimal `json:"inflation_rate"`
+	StartingValue    decimal.Decimal `json:"starting_value"`
+	ExpectedReturn   decimal.Decimal `json:"expected_return"`
+	Volatility       decimal.Decimal `json:"volatility"`
+	HistoryMonths    int             `json:"history_months,omitempty"`
+	Paths            int             `json:"paths"`
+	Seed             int64           `json:"seed"`
+}
+
+// SimulationResult is the outcome of a simulation. SuccessProbability is the percentage of
+// paths that still had money after the last year, and MedianDepletionYears how long the others
+// lasted.
+type SimulationResult struct {
+	SuccessProbability   decimal.Decimal         `json:"success_probability"`
+	DepletedPaths        int                     `json:"depleted_paths"`
+	MedianDepletionYears *decimal.Decimal        `json:"median_depletion_years,omitempty"`
+	Years                []models.SimulationYear `json:"years"`
+}
+
+// SimulationJob is the payload of the queued job that runs a stored simulation
+type SimulationJob struct {
+	SimulationID uuid.UUID `json:"simulation_id"`
+}
+
+// SimulationResponse represents a stored simulation. Result is set once its job has succeeded.
+type SimulationResponse struct {
+	ID          uuid.UUID              `json:"id"`
+	PortfolioID uuid.UUID              `json:"portfolio_id"`
+	JobID       *uuid.UUID             `json:"job_id,omitempty"`
+	Status      models.QueuedJobStatus `json:"status"`
+	Parameters  *SimulationParameters  `json:"parameters"`
+	Result      *SimulationResult      `json:"result,omitempty"`
+	Error       string                 `json:"error,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+	FinishedAt  *time.Time             `json:"finished_at,omitempty"`
+}
+
+// SimulationListResponse represents a portfolio's simulations, newest first
+type SimulationListResponse struct {
+	Simulations []*SimulationResponse `json:"simulations"`
+	Total       int                   `json:"total"`
+}
+
+// ToSimulationResponse converts a simulation model to its response
+func ToSimulationResponse(simulation *models.Simulation) *SimulationResponse {
+	response := &SimulationResponse{
+		ID:          simulation.ID,
+		PortfolioID: simulation.PortfolioID,
+		JobID:       simulation.JobID,
+		Status:      simulation.Status,
+		Error:       simulation.Error,
+		CreatedAt:   simulation.CreatedAt,
+		FinishedAt:  simulation.FinishedAt,
+	}
+
+	var parameters SimulationParameters
+	if err := simulation.DecodeParameters(&parameters); err == nil {
+		response.Parameters = &parameters
+	}
+	var result SimulationResult
+	if ok, err := simulation.DecodeResult(&result); ok && err == nil {
+		response.Result = &result
+	}
+
+	return response
+}
+
+// ToSimulationListResponse converts simulation models to a list response
+func ToSimulationListResponse(simulations []*models.Simulation) *SimulationListResponse {
+	responses := make([]*SimulationResponse, len(simulations))
+	for i, simulation := range simulations {
+		responses[i] = ToSimulationResponse(simulation)
+	}
+	return &SimulationListResponse{
+		Simulations: responses,
+		Total:       len(responses),
+	}
+}
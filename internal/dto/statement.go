@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// StatementFormat identifies the document a statement is rendered as
+type StatementFormat string
+
+const (
+	StatementFormatPDF  StatementFormat = "pdf"
+	StatementFormatHTML StatementFormat = "html"
+)
+
+// StatementRequest represents the query parameters for a portfolio statement
+type StatementRequest struct {
+	// Period is a month (2024-11) or a quarter (2024-Q4)
+	Period string          `form:"period" binding:"required"`
+	Format StatementFormat `form:"format" binding:"omitempty,oneof=pdf html"`
+}
+
+// Statement is a portfolio's account statement for a month or quarter
+type Statement struct {
+	Portfolio    CertifiedPortfolio     `json:"portfolio"`
+	Period       string                 `json:"period"`
+	Title        string                 `json:"title"`
+	StartDate    time.Time              `json:"start_date"`
+	EndDate      time.Time              `json:"end_date"` // Last day covered, which is today for the current period
+	GeneratedAt  time.Time              `json:"generated_at"`
+	Locale       string                 `json:"locale"`   // The reader's locale, which rendered numbers and dates are written in
+	Timezone     string                 `json:"timezone"` // The reader's time zone, which GeneratedAt is shown in
+	Performance  StatementPerformance   `json:"performance"`
+	Holdings     []StatementHolding     `json:"holdings"`
+	Transactions []StatementTransaction `json:"transactions"`
+	Income       StatementIncome        `json:"income"`
+}
+
+// StatementPerformance summarizes how the portfolio's value changed over the period. Values
+// come from performance snapshots and are nil when no snapshot covers that date.
+type StatementPerformance struct {
+	StartingValue  *decimal.Decimal `json:"starting_value,omitempty"` // Last valuation before the period
+	EndingValue    *decimal.Decimal `json:"ending_value,omitempty"`   // Last valuation in the period
+	NetCashFlow    decimal.Decimal  `json:"net_cash_flow"`            // Buys at total cost less sell proceeds
+	InvestmentGain *decimal.Decimal `json:"investment_gain,omitempty"`
+	TWRPercent     *decimal.Decimal `json:"twr_percent,omitempty"` // Time-weighted return between the two valuations
+}
+
+// StatementHolding is a position held at the end of the period
+type StatementHolding struct {
+	Symbol      string          `json:"symbol"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	CostBasis   decimal.Decimal `json:"cost_basis"`
+	AverageCost decimal.Decimal `json:"average_cost"`
+}
+
+// StatementTransaction is a transaction made during the period. Amount is the total cost of
+// acquisitions, the proceeds of sales and the amount of dividends, other income and fees.
+type StatementTransaction struct {
+	Date       time.Time              `json:"date"`
+	Type       models.TransactionType `json:"type"`
+	Symbol     string                 `json:"symbol"`
+	Quantity   decimal.Decimal        `json:"quantity"`
+	Price      *decimal.Decimal       `json:"price,omitempty"`
+	Commission decimal.Decimal        `json:"commission"`
+	Amount     decimal.Decimal        `json:"amount"`
+	Notes      string                 `json:"notes,omitempty"`
+}
+
+// StatementIncome totals the income received during the period: dividends, reinvested or not,
+// and income transactions such as interest
+type StatementIncome struct {
+	Total      decimal.Decimal           `json:"total"`
+	BySymbol   []StatementIncomeLine     `json:"by_symbol"`
+	ByCategory []StatementIncomeCategory `json:"by_category"`
+}
+
+// StatementIncomeLine totals one symbol's income
+type StatementIncomeLine struct {
+	Symbol   string          `json:"symbol"`
+	Amount   decimal.Decimal `json:"amount"`
+	Payments int             `json:"payments"`
+}
+
+// StatementIncomeCategory totals one income category: DIVIDEND for dividends, or the category
+// of income transactions
+type StatementIncomeCategory struct {
+	Category string          `json:"category"`
+	Amount   decimal.Decimal `json:"amount"`
+	Payments int             `json:"payments"`
+}
@@ -0,0 +1,164 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// CreateStockPlanGrantRequest represents the request to create an employer stock plan grant
+type CreateStockPlanGrantRequest struct {
+	Symbol                string               `json:"symbol" binding:"required,min=1,max=20"`
+	Type                  models.StockPlanType `json:"type" binding:"required,oneof=RSU ESPP ISO NSO"`
+	GrantDate             time.Time            `json:"grant_date" binding:"required"`
+	TotalShares           decimal.Decimal      `json:"total_shares" binding:"required"`
+	VestingStartDate      *time.Time           `json:"vesting_start_date,omitempty"`
+	VestingMonths         int                  `json:"vesting_months" binding:"min=0"`
+	CliffMonths           int                  `json:"cliff_months" binding:"min=0"`
+	VestingIntervalMonths int                  `json:"vesting_interval_months" binding:"min=0"`
+	StrikePrice           *decimal.Decimal     `json:"strike_price,omitempty"`
+	ExpirationDate        *time.Time           `json:"expiration_date,omitempty"`
+	DiscountPercent       *decimal.Decimal     `json:"discount_percent,omitempty"`
+	Lookback              bool                 `json:"lookback"`
+	Notes                 string               `json:"notes,omitempty"`
+}
+
+// StockPlanGrantResponse represents a stock plan grant in API responses
+type StockPlanGrantResponse struct {
+	ID                    string                `json:"id"`
+	PortfolioID           string                `json:"portfolio_id"`
+	Symbol                string                `json:"symbol"`
+	Type                  models.StockPlanType  `json:"type"`
+	GrantDate             time.Time             `json:"grant_date"`
+	TotalShares           decimal.Decimal       `json:"total_shares"`
+	VestedShares          decimal.Decimal       `json:"vested_shares"`
+	VestingStartDate      time.Time             `json:"vesting_start_date"`
+	VestingMonths         int                   `json:"vesting_months"`
+	CliffMonths           int                   `json:"cliff_months"`
+	VestingIntervalMonths int                   `json:"vesting_interval_months"`
+	StrikePrice           *decimal.Decimal      `json:"strike_price,omitempty"`
+	ExpirationDate        *time.Time            `json:"expiration_date,omitempty"`
+	DiscountPercent       *decimal.Decimal      `json:"discount_percent,omitempty"`
+	Lookback              bool                  `json:"lookback"`
+	Notes                 string                `json:"notes,omitempty"`
+	VestingSchedule       []models.VestingEvent `json:"vesting_schedule,omitempty"`
+	CreatedAt             time.Time             `json:"created_at"`
+	UpdatedAt             time.Time             `json:"updated_at"`
+}
+
+// RecordRSUVestRequest represents the request to record an RSU vest
+type RecordRSUVestRequest struct {
+	Date            time.Time       `json:"date" binding:"required"`
+	Shares          decimal.Decimal `json:"shares" binding:"required"`
+	SharesWithheld  decimal.Decimal `json:"shares_withheld"`
+	FairMarketValue decimal.Decimal `json:"fair_market_value" binding:"required"`
+}
+
+// RecordESPPPurchaseRequest represents the request to record an ESPP purchase
+type RecordESPPPurchaseRequest struct {
+	Date            time.Time       `json:"date" binding:"required"`
+	Shares          decimal.Decimal `json:"shares" binding:"required"`
+	OfferingDateFMV decimal.Decimal `json:"offering_date_fmv"`
+	PurchaseDateFMV decimal.Decimal `json:"purchase_date_fmv" binding:"required"`
+}
+
+// RecordOptionExerciseRequest represents the request to record an option exercise
+type RecordOptionExerciseRequest struct {
+	Date            time.Time       `json:"date" binding:"required"`
+	Shares          decimal.Decimal `json:"shares" binding:"required"`
+	FairMarketValue decimal.Decimal `json:"fair_market_value" binding:"required"`
+}
+
+// StockPlanEventResponse represents a recorded stock plan event in API responses
+type StockPlanEventResponse struct {
+	ID              string                    `json:"id"`
+	GrantID         string                    `json:"grant_id"`
+	PortfolioID     string                    `json:"portfolio_id"`
+	TransactionID   string                    `json:"transaction_id"`
+	Type            models.StockPlanEventType `json:"type"`
+	Date            time.Time                 `json:"date"`
+	Shares          decimal.Decimal           `json:"shares"`
+	SharesWithheld  decimal.Decimal           `json:"shares_withheld"`
+	FairMarketValue decimal.Decimal           `json:"fair_market_value"`
+	PurchasePrice   decimal.Decimal           `json:"purchase_price"`
+	CostBasis       decimal.Decimal           `json:"cost_basis"`
+	OrdinaryIncome  decimal.Decimal           `json:"ordinary_income"`
+	BargainElement  decimal.Decimal           `json:"bargain_element"`
+	AMTAdjustment   decimal.Decimal           `json:"amt_adjustment"`
+	CreatedAt       time.Time                 `json:"created_at"`
+}
+
+// VestingCalendarRequest represents query parameters for the vesting calendar
+type VestingCalendarRequest struct {
+	StartDate time.Time `form:"start_date" time_format:"2006-01-02"`
+	EndDate   time.Time `form:"end_date" time_format:"2006-01-02"`
+}
+
+// VestingCalendarEntryResponse represents a scheduled vest in API responses
+type VestingCalendarEntryResponse struct {
+	GrantID          string               `json:"grant_id"`
+	Symbol           string               `json:"symbol"`
+	Type             models.StockPlanType `json:"type"`
+	Date             time.Time            `json:"date"`
+	Shares           decimal.Decimal      `json:"shares"`
+	CumulativeShares decimal.Decimal      `json:"cumulative_shares"`
+	TotalShares      decimal.Decimal      `json:"total_shares"`
+}
+
+// VestingCalendarResponse represents the vesting calendar for a portfolio
+type VestingCalendarResponse struct {
+	StartDate time.Time                       `json:"start_date"`
+	EndDate   time.Time                       `json:"end_date"`
+	Entries   []*VestingCalendarEntryResponse `json:"entries"`
+}
+
+// ToStockPlanGrantResponse converts a StockPlanGrant model to a StockPlanGrantResponse DTO
+func ToStockPlanGrantResponse(grant *models.StockPlanGrant, includeSchedule bool) *StockPlanGrantResponse {
+	response := &StockPlanGrantResponse{
+		ID:                    grant.ID.String(),
+		PortfolioID:           grant.PortfolioID.String(),
+		Symbol:                grant.Symbol,
+		Type:                  grant.Type,
+		GrantDate:             grant.GrantDate,
+		TotalShares:           grant.TotalShares,
+		VestedShares:          grant.VestedShares(time.Now().UTC()),
+		VestingStartDate:      grant.VestingStartDate,
+		VestingMonths:         grant.VestingMonths,
+		CliffMonths:           grant.CliffMonths,
+		VestingIntervalMonths: grant.VestingIntervalMonths,
+		StrikePrice:           grant.StrikePrice,
+		ExpirationDate:        grant.ExpirationDate,
+		DiscountPercent:       grant.DiscountPercent,
+		Lookback:              grant.Lookback,
+		Notes:                 grant.Notes,
+		CreatedAt:             grant.CreatedAt,
+		UpdatedAt:             grant.UpdatedAt,
+	}
+	if includeSchedule {
+		response.VestingSchedule = grant.VestingSchedule()
+	}
+	return response
+}
+
+// ToStockPlanEventResponse converts a StockPlanEvent model to a StockPlanEventResponse DTO
+func ToStockPlanEventResponse(event *models.StockPlanEvent) *StockPlanEventResponse {
+	return &StockPlanEventResponse{
+		ID:              event.ID.String(),
+		GrantID:         event.GrantID.String(),
+		PortfolioID:     event.PortfolioID.String(),
+		TransactionID:   event.TransactionID.String(),
+		Type:            event.Type,
+		Date:            event.Date,
+		Shares:          event.Shares,
+		SharesWithheld:  event.SharesWithheld,
+		FairMarketValue: event.FairMarketValue,
+		PurchasePrice:   event.PurchasePrice,
+		CostBasis:       event.CostBasis,
+		OrdinaryIncome:  event.OrdinaryIncome,
+		BargainElement:  event.BargainElement,
+		AMTAdjustment:   event.AMTAdjustment,
+		CreatedAt:       event.CreatedAt,
+	}
+}
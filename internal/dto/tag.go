@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// TagResponse represents a tag and how many portfolios and transactions carry it
+type TagResponse struct {
+	Name         string `json:"name"`
+	Portfolios   int    `json:"portfolios"`
+	Transactions int    `json:"transactions"`
+}
+
+// TagListResponse represents a user's tags
+type TagListResponse struct {
+	Tags  []*TagResponse `json:"tags"`
+	Total int            `json:"total"`
+}
+
+// TagPerformance is the performance of every portfolio carrying a tag over a period, with
+// totals for each base currency
+type TagPerformance struct {
+	Tag        string                     `json:"tag"`
+	StartDate  time.Time                  `json:"start_date"`
+	EndDate    time.Time                  `json:"end_date"`
+	Portfolios []*TagPortfolioPerformance `json:"portfolios"`
+	Totals     []*TagPerformanceTotal     `json:"totals"`
+}
+
+// TagPortfolioPerformance is the performance of one tagged portfolio. Metrics is nil and Error
+// says why when the portfolio lacks the data to measure the period.
+type TagPortfolioPerformance struct {
+	PortfolioID  uuid.UUID           `json:"portfolio_id"`
+	Name         string              `json:"name"`
+	BaseCurrency string              `json:"base_currency"`
+	Metrics      *PerformanceMetrics `json:"metrics"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// TagPerformanceTotal adds up the measured portfolios of one base currency. Portfolios in
+// different currencies are not converted, so each currency has its own total.
+type TagPerformanceTotal struct {
+	Currency         string          `json:"currency"`
+	Portfolios       int             `json:"portfolios"`
+	StartingValue    decimal.Decimal `json:"starting_value"`
+	EndingValue      decimal.Decimal `json:"ending_value"`
+	TotalReturn      decimal.Decimal `json:"total_return"`
+	TotalReturnPct   decimal.Decimal `json:"total_return_pct"`
+	TotalDeposits    decimal.Decimal `json:"total_deposits"`
+	TotalWithdrawals decimal.Decimal `json:"total_withdrawals"`
+	NetCashFlow      decimal.Decimal `json:"net_cash_flow"`
+}
+
+// ToTagListResponse converts tag usage counts to a TagListResponse DTO
+func ToTagListResponse(usages []*models.TagUsage) *TagListResponse {
+	response := &TagListResponse{
+		Tags:  make([]*TagResponse, 0, len(usages)),
+		Total: len(usages),
+	}
+
+	for _, usage := range usages {
+		response.Tags = append(response.Tags, &TagResponse{
+			Name:         usage.Name,
+			Portfolios:   usage.Portfolios,
+			Transactions: usage.Transactions,
+		})
+	}
+
+	return response
+}
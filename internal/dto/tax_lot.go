@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // TaxLotResponse represents a tax lot in API responses
@@ -20,14 +22,21 @@ type TaxLotResponse struct {
 	UpdatedAt     time.Time       `json:"updated_at"`
 }
 
-// TaxLossOpportunityResponse represents a tax-loss harvesting opportunity
+// TaxLossOpportunityResponse represents a tax lot that could be sold to harvest its loss
 type TaxLossOpportunityResponse struct {
-	Symbol          string          `json:"symbol"`
-	CurrentQuantity decimal.Decimal `json:"current_quantity"`
-	CostBasis       decimal.Decimal `json:"cost_basis"`
-	CurrentValue    decimal.Decimal `json:"current_value"`
-	UnrealizedLoss  decimal.Decimal `json:"unrealized_loss"`
-	LossPercent     decimal.Decimal `json:"loss_percent"`
+	TaxLotID         string          `json:"tax_lot_id"`
+	Symbol           string          `json:"symbol"`
+	PurchaseDate     time.Time       `json:"purchase_date"`
+	CurrentQuantity  decimal.Decimal `json:"current_quantity"`
+	CostBasis        decimal.Decimal `json:"cost_basis"`
+	CurrentPrice     decimal.Decimal `json:"current_price"`
+	CurrentValue     decimal.Decimal `json:"current_value"`
+	UnrealizedLoss   decimal.Decimal `json:"unrealized_loss"`
+	LossPercent      decimal.Decimal `json:"loss_percent"`
+	IsLongTerm       bool            `json:"is_long_term"`
+	WashSaleRisk     bool            `json:"wash_sale_risk"`
+	LastPurchaseDate *time.Time      `json:"last_purchase_date,omitempty"`
+	Replacements     []string        `json:"replacements"`
 }
 
 // TaxReportRequest represents a request to generate a tax report
@@ -35,32 +44,45 @@ type TaxReportRequest struct {
 	TaxYear int `json:"tax_year" binding:"required,min=2000,max=2100"`
 }
 
-// RealizedGainResponse represents a realized gain or loss in API responses
+// RealizedGainResponse represents a realized gain or loss in API responses. Rule is the
+// share matching rule that costed the sale, for regimes that match sales by rule.
 type RealizedGainResponse struct {
-	Symbol       string          `json:"symbol"`
-	PurchaseDate time.Time       `json:"purchase_date"`
-	SaleDate     time.Time       `json:"sale_date"`
-	Quantity     decimal.Decimal `json:"quantity"`
-	CostBasis    decimal.Decimal `json:"cost_basis"`
-	Proceeds     decimal.Decimal `json:"proceeds"`
-	Gain         decimal.Decimal `json:"gain"`
-	IsLongTerm   bool            `json:"is_long_term"`
+	Symbol         string           `json:"symbol"`
+	PurchaseDate   time.Time        `json:"purchase_date"`
+	SaleDate       time.Time        `json:"sale_date"`
+	Quantity       decimal.Decimal  `json:"quantity"`
+	CostBasis      decimal.Decimal  `json:"cost_basis"`
+	Proceeds       decimal.Decimal  `json:"proceeds"`
+	Gain           decimal.Decimal  `json:"gain"`
+	IsLongTerm     bool             `json:"is_long_term"`
+	Rule           string           `json:"rule,omitempty"`
+	DisallowedLoss *decimal.Decimal `json:"disallowed_loss,omitempty"`
 }
 
-// TaxReportResponse represents a tax report in API responses
+// TaxReportResponse represents a tax report in API responses. Regimes without holding
+// periods list every gain in Gains.
 type TaxReportResponse struct {
-	Year               int                     `json:"year"`
-	ShortTermGains     []*RealizedGainResponse `json:"short_term_gains"`
-	LongTermGains      []*RealizedGainResponse `json:"long_term_gains"`
-	TotalShortTermGain decimal.Decimal         `json:"total_short_term_gain"`
-	TotalLongTermGain  decimal.Decimal         `json:"total_long_term_gain"`
-	TotalGain          decimal.Decimal         `json:"total_gain"`
+	Year                int                     `json:"year"`
+	Regime              models.TaxRegime        `json:"regime"`
+	ShortTermGains      []*RealizedGainResponse `json:"short_term_gains"`
+	LongTermGains       []*RealizedGainResponse `json:"long_term_gains"`
+	Gains               []*RealizedGainResponse `json:"gains,omitempty"`
+	TotalShortTermGain  decimal.Decimal         `json:"total_short_term_gain"`
+	TotalLongTermGain   decimal.Decimal         `json:"total_long_term_gain"`
+	TotalGain           decimal.Decimal         `json:"total_gain"`
+	TotalDisallowedLoss decimal.Decimal         `json:"total_disallowed_loss"`
+	TaxableGain         decimal.Decimal         `json:"taxable_gain"`
+}
+
+// TaxRegimeRequest represents a request to change the tax regime of a portfolio
+type TaxRegimeRequest struct {
+	TaxRegime models.TaxRegime `json:"tax_regime" binding:"required,oneof=US UK CA"`
 }
 
 // TaxLotAllocationRequest represents a request to allocate a sale to tax lots
 type TaxLotAllocationRequest struct {
 	Symbol   string          `json:"symbol" binding:"required"`
-	Quantity decimal.Decimal `json:"quantity" binding:"required,gt=0"`
+	Quantity decimal.Decimal `json:"quantity" binding:"required"`
 	Method   string          `json:"method" binding:"required,oneof=FIFO LIFO SPECIFIC_LOT"`
 }
 
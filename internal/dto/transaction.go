@@ -11,19 +11,22 @@ import (
 
 // CreateTransactionRequest represents the request to create a new transaction
 type CreateTransactionRequest struct {
-	Type       models.TransactionType `json:"type" binding:"required,oneof=BUY SELL DIVIDEND SPLIT MERGER SPINOFF DIVIDEND_REINVEST"`
-	Symbol     string                 `json:"symbol" binding:"required,min=1,max=20"`
-	Date       time.Time              `json:"date" binding:"required"`
-	Quantity   decimal.Decimal        `json:"quantity" binding:"required"`
-	Price      *decimal.Decimal       `json:"price,omitempty"`
-	Commission decimal.Decimal        `json:"commission"`
-	Currency   string                 `json:"currency,omitempty" binding:"omitempty,len=3"`
-	Notes      string                 `json:"notes,omitempty"`
+	Type                   models.TransactionType `json:"type" binding:"required,oneof=BUY SELL DIVIDEND SPLIT MERGER SPINOFF DIVIDEND_REINVEST MANAGEMENT_FEE ADR_FEE FX_FEE LENDING_INCOME INTEREST REBATE DEPOSIT WITHDRAWAL OPENING_BALANCE CAPITAL_GAIN_DISTRIBUTION"`
+	Symbol                 string                 `json:"symbol" binding:"required,min=1,max=20"`
+	Date                   time.Time              `json:"date" binding:"required"`
+	Quantity               decimal.Decimal        `json:"quantity" binding:"required"`
+	Price                  *decimal.Decimal       `json:"price,omitempty"`
+	Commission             decimal.Decimal        `json:"commission"`
+	Currency               string                 `json:"currency,omitempty" binding:"omitempty,len=3"`
+	Notes                  string                 `json:"notes,omitempty"`
+	Category               string                 `json:"category,omitempty" binding:"max=50"` // Income transactions, defaulting to the type, and capital gain distributions, SHORT_TERM or LONG_TERM
+	BlackoutOverrideReason string                 `json:"blackout_override_reason,omitempty" binding:"max=500"`
+	Tags                   []string               `json:"tags,omitempty" binding:"max=20"`
 }
 
 // UpdateTransactionRequest represents the request to update a transaction
 type UpdateTransactionRequest struct {
-	Type       models.TransactionType `json:"type" binding:"required,oneof=BUY SELL DIVIDEND SPLIT MERGER SPINOFF DIVIDEND_REINVEST"`
+	Type       models.TransactionType `json:"type" binding:"required,oneof=BUY SELL DIVIDEND SPLIT MERGER SPINOFF DIVIDEND_REINVEST MANAGEMENT_FEE ADR_FEE FX_FEE LENDING_INCOME INTEREST REBATE DEPOSIT WITHDRAWAL OPENING_BALANCE CAPITAL_GAIN_DISTRIBUTION"`
 	Symbol     string                 `json:"symbol" binding:"required,min=1,max=20"`
 	Date       time.Time              `json:"date" binding:"required"`
 	Quantity   decimal.Decimal        `json:"quantity" binding:"required"`
@@ -31,6 +34,11 @@ type UpdateTransactionRequest struct {
 	Commission decimal.Decimal        `json:"commission"`
 	Currency   string                 `json:"currency,omitempty" binding:"omitempty,len=3"`
 	Notes      string                 `json:"notes,omitempty"`
+	Category   string                 `json:"category,omitempty" binding:"max=50"` // Income transactions, defaulting to the type, and capital gain distributions, SHORT_TERM or LONG_TERM
+	// Tags replaces the transaction's tags when set; an empty list removes them all
+	Tags *[]string `json:"tags,omitempty" binding:"omitempty,max=20"`
+	// Version is the transaction version the update was made against; the If-Match header takes precedence
+	Version *int `json:"version,omitempty" binding:"omitempty,min=1"`
 }
 
 // TransactionResponse represents a transaction in API responses
@@ -39,15 +47,31 @@ type TransactionResponse struct {
 	PortfolioID   uuid.UUID              `json:"portfolio_id"`
 	Type          models.TransactionType `json:"type"`
 	Symbol        string                 `json:"symbol"`
+	AssetType     models.AssetType       `json:"asset_type"`
 	Date          time.Time              `json:"date"`
 	Quantity      decimal.Decimal        `json:"quantity"`
 	Price         *decimal.Decimal       `json:"price,omitempty"`
+	Multiplier    int                    `json:"multiplier,omitempty"`
 	Commission    decimal.Decimal        `json:"commission"`
 	Currency      string                 `json:"currency"`
 	Notes         string                 `json:"notes,omitempty"`
+	Category      string                 `json:"category,omitempty"` // The income category, for dividends and income transactions, or a capital gain distribution's term
 	ImportBatchID *uuid.UUID             `json:"import_batch_id,omitempty"`
+	Warnings      []string               `json:"warnings,omitempty"`
+	Tags          []string               `json:"tags"`
+	Version       int                    `json:"version"`
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
+	// RunningQuantity and RunningCostBasis are the symbol's position right after the
+	// transaction, set when a symbol's ledger is requested with running_position=true
+	RunningQuantity  *decimal.Decimal `json:"running_quantity,omitempty"`
+	RunningCostBasis *decimal.Decimal `json:"running_cost_basis,omitempty"`
+}
+
+// RunningPosition is a symbol's share count and cost basis right after a transaction
+type RunningPosition struct {
+	Quantity  decimal.Decimal
+	CostBasis decimal.Decimal
 }
 
 // TransactionListResponse represents a list of transactions
@@ -62,18 +86,30 @@ func ToTransactionResponse(transaction *models.Transaction) *TransactionResponse
 		return nil
 	}
 
+	category := transaction.IncomeCategory()
+	if transaction.IsLongTermDistribution() {
+		category = models.CapitalGainTermLong
+	} else if transaction.Type == models.TransactionTypeCapitalGainDistribution {
+		category = models.CapitalGainTermShort
+	}
+
 	return &TransactionResponse{
 		ID:            transaction.ID,
 		PortfolioID:   transaction.PortfolioID,
 		Type:          transaction.Type,
 		Symbol:        transaction.Symbol,
+		AssetType:     transaction.AssetType,
 		Date:          transaction.Date,
 		Quantity:      transaction.Quantity,
 		Price:         transaction.Price,
+		Multiplier:    transaction.Multiplier,
 		Commission:    transaction.Commission,
 		Currency:      transaction.Currency,
 		Notes:         transaction.Notes,
+		Category:      category,
 		ImportBatchID: transaction.ImportBatchID,
+		Tags:          []string{},
+		Version:       transaction.Version,
 		CreatedAt:     transaction.CreatedAt,
 		UpdatedAt:     transaction.UpdatedAt,
 	}
@@ -92,3 +128,37 @@ func ToTransactionListResponse(transactions []*models.Transaction) *TransactionL
 
 	return response
 }
+
+// WithRunningPositions sets each listed transaction's running position, if known
+func (r *TransactionListResponse) WithRunningPositions(positions map[uuid.UUID]*RunningPosition) *TransactionListResponse {
+	for _, transaction := range r.Transactions {
+		if position, ok := positions[transaction.ID]; ok {
+			quantity, costBasis := position.Quantity, position.CostBasis
+			transaction.RunningQuantity = &quantity
+			transaction.RunningCostBasis = &costBasis
+		}
+	}
+	return r
+}
+
+// ToTransactionResponseWithTags converts a Transaction model to a TransactionResponse DTO
+// listing the transaction's tags
+func ToTransactionResponseWithTags(transaction *models.Transaction, tags []string) *TransactionResponse {
+	response := ToTransactionResponse(transaction)
+	if response != nil && tags != nil {
+		response.Tags = tags
+	}
+	return response
+}
+
+// ToTransactionListResponseWithTags converts a list of Transaction models to a
+// TransactionListResponse DTO listing each transaction's tags
+func ToTransactionListResponseWithTags(transactions []*models.Transaction, tags map[uuid.UUID][]string) *TransactionListResponse {
+	response := ToTransactionListResponse(transactions)
+	for i, transaction := range transactions {
+		if transactionTags, ok := tags[transaction.ID]; ok {
+			response.Transactions[i].Tags = transactionTags
+		}
+	}
+	return response
+}
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// ListUsersRequest represents the query parameters for listing users. Limit defaults to 50.
+type ListUsersRequest struct {
+	Email    string          `form:"email"`
+	Role     models.UserRole `form:"role" binding:"omitempty,oneof=user admin"`
+	Disabled *bool           `form:"disabled"`
+	Limit    int             `form:"limit" binding:"omitempty,min=1,max=500"`
+	Offset   int             `form:"offset" binding:"omitempty,min=0"`
+}
+
+// SetUserRoleRequest represents a request to change a user's role
+type SetUserRoleRequest struct {
+	Role models.UserRole `json:"role" binding:"required,oneof=user admin"`
+}
+
+// AdminUserResponse represents a user in admin API responses
+type AdminUserResponse struct {
+	ID                    string          `json:"id"`
+	Email                 string          `json:"email"`
+	Role                  models.UserRole `json:"role"`
+	OrganizationID        *string         `json:"organization_id,omitempty"`
+	Disabled              bool            `json:"disabled"`
+	DisabledAt            *time.Time      `json:"disabled_at,omitempty"`
+	PasswordResetRequired bool            `json:"password_reset_required"`
+	CreatedAt             time.Time       `json:"created_at"`
+	UpdatedAt             time.Time       `json:"updated_at"`
+}
+
+// AdminUserListResponse represents a page of users
+type AdminUserListResponse struct {
+	Users  []*AdminUserResponse `json:"users"`
+	Total  int64                `json:"total"`
+	Limit  int                  `json:"limit"`
+	Offset int                  `json:"offset"`
+}
+
+// ForcePasswordResetResponse represents the outcome of forcing a password reset. EmailSent is
+// false if the reset link could not be delivered; the user can still request one through
+// forgot-password.
+type ForcePasswordResetResponse struct {
+	User           *AdminUserResponse `json:"user"`
+	EmailSent      bool               `json:"email_sent"`
+	ResetExpiresAt time.Time          `json:"reset_expires_at"`
+}
+
+// UserUsageResponse represents the resources a user owns
+type UserUsageResponse struct {
+	UserID               string `json:"user_id"`
+	Portfolios           int64  `json:"portfolios"`
+	Transactions         int64  `json:"transactions"`
+	ImportBatches        int64  `json:"import_batches"`
+	Holdings             int64  `json:"holdings"`
+	PerformanceSnapshots int64  `json:"performance_snapshots"`
+	ActiveAPIKeys        int64  `json:"active_api_keys"`
+	ActiveSessions       int64  `json:"active_sessions"`
+}
+
+// ToAdminUserResponse converts a User model to an admin response DTO
+func ToAdminUserResponse(user *models.User) *AdminUserResponse {
+	response := &AdminUserResponse{
+		ID:                    user.ID.String(),
+		Email:                 user.Email,
+		Role:                  user.Role,
+		Disabled:              user.IsDisabled(),
+		DisabledAt:            user.DisabledAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+		CreatedAt:             user.CreatedAt,
+		UpdatedAt:             user.UpdatedAt,
+	}
+	if user.OrganizationID != nil {
+		organizationID := user.OrganizationID.String()
+		response.OrganizationID = &organizationID
+	}
+	return response
+}
+
+// ToAdminUserListResponse converts a page of User models to a response DTO
+func ToAdminUserListResponse(users []*models.User, total int64, limit, offset int) *AdminUserListResponse {
+	responses := make([]*AdminUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = ToAdminUserResponse(user)
+	}
+	return &AdminUserListResponse{
+		Users:  responses,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+}
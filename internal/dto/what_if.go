@@ -0,0 +1,77 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// WhatIfTrade is a hypothetical buy or sell. Date defaults to today and decides whether the
+// lots a sale closes are held long-term.
+type WhatIfTrade struct {
+	Type       models.TransactionType `json:"type" binding:"required,oneof=BUY SELL"`
+	Symbol     string                 `json:"symbol" binding:"required,min=1,max=20"`
+	Quantity   decimal.Decimal        `json:"quantity" binding:"required"`
+	Price      decimal.Decimal        `json:"price" binding:"required"`
+	Commission decimal.Decimal        `json:"commission"`
+	Date       *time.Time             `json:"date,omitempty"`
+}
+
+// WhatIfRequest previews the effect of hypothetical trades on a portfolio. The portfolio
+// doesn't track cash, so CashBalance is the cash held alongside it before the trades. Tax
+// rates and target weights are percentages; target weights are by symbol, with whatever they
+// leave over meant for cash.
+type WhatIfRequest struct {
+	Trades           []WhatIfTrade              `json:"trades" binding:"required,min=1,max=100,dive"`
+	CashBalance      decimal.Decimal            `json:"cash_balance"`
+	ShortTermTaxRate decimal.Decimal            `json:"short_term_tax_rate"`
+	LongTermTaxRate  decimal.Decimal            `json:"long_term_tax_rate"`
+	TargetWeights    map[string]decimal.Decimal `json:"target_weights,omitempty"`
+}
+
+// WhatIfPosition is a position before and after the trades. Positions are valued at the
+// price of the last trade in the symbol, else at its latest quote, else at average cost, in
+// which case PricedAtCost is set. Weights are percentages of the total value including cash,
+// and drifts are weights minus the target weight.
+type WhatIfPosition struct {
+	Symbol         string           `json:"symbol"`
+	QuantityBefore decimal.Decimal  `json:"quantity_before"`
+	QuantityAfter  decimal.Decimal  `json:"quantity_after"`
+	Price          decimal.Decimal  `json:"price"`
+	PricedAtCost   bool             `json:"priced_at_cost,omitempty"`
+	ValueBefore    decimal.Decimal  `json:"value_before"`
+	ValueAfter     decimal.Decimal  `json:"value_after"`
+	WeightBefore   decimal.Decimal  `json:"weight_before"`
+	WeightAfter    decimal.Decimal  `json:"weight_after"`
+	WeightChange   decimal.Decimal  `json:"weight_change"`
+	TargetWeight   *decimal.Decimal `json:"target_weight,omitempty"`
+	DriftBefore    *decimal.Decimal `json:"drift_before,omitempty"`
+	DriftAfter     *decimal.Decimal `json:"drift_after,omitempty"`
+}
+
+// WhatIfResult is the previewed effect of hypothetical trades. Sales close lots in the order
+// of the portfolio's cost basis method; specific-lot portfolios close their oldest lots first.
+// EstimatedTax applies the tax rates to the net short- and long-term gains after a net loss of
+// one kind offsets gains of the other. TotalDriftBefore and TotalDriftAfter add up the
+// absolute drifts of the positions with a target weight.
+type WhatIfResult struct {
+	PortfolioID       string                  `json:"portfolio_id"`
+	CostBasisMethod   models.CostBasisMethod  `json:"cost_basis_method"`
+	RealizedGains     []*RealizedGainResponse `json:"realized_gains"`
+	ShortTermGain     decimal.Decimal         `json:"short_term_gain"`
+	LongTermGain      decimal.Decimal         `json:"long_term_gain"`
+	TotalGain         decimal.Decimal         `json:"total_gain"`
+	EstimatedTax      decimal.Decimal         `json:"estimated_tax"`
+	CashBalanceBefore decimal.Decimal         `json:"cash_balance_before"`
+	CashChange        decimal.Decimal         `json:"cash_change"`
+	CashBalanceAfter  decimal.Decimal         `json:"cash_balance_after"`
+	CashWeightBefore  decimal.Decimal         `json:"cash_weight_before"`
+	CashWeightAfter   decimal.Decimal         `json:"cash_weight_after"`
+	TotalValueBefore  decimal.Decimal         `json:"total_value_before"`
+	TotalValueAfter   decimal.Decimal         `json:"total_value_after"`
+	Positions         []*WhatIfPosition       `json:"positions"`
+	TotalDriftBefore  *decimal.Decimal        `json:"total_drift_before,omitempty"`
+	TotalDriftAfter   *decimal.Decimal        `json:"total_drift_after,omitempty"`
+}
@@ -0,0 +1,70 @@
+package emails
+
+// The data each email's templates are rendered with. Amounts and dates are already written
+// in the recipient's locale, so templates only place them.
+
+// PasswordResetData is the data of the PasswordReset email
+type PasswordResetData struct {
+	ResetLink string
+}
+
+// InviteData is the data of the Invite email
+type InviteData struct {
+	OrganizationName string
+	InviteLink       string
+	ExpiresAt        string
+}
+
+// OrganizationInviteData is the data of the OrganizationInvite email
+type OrganizationInviteData struct {
+	OrganizationName string
+	InviterEmail     string
+	Role             string
+	InviteLink       string
+	ExpiresAt        string
+}
+
+// DelegationRequestData is the data of the DelegationRequest email. Access completes "asked
+// to ... your portfolios".
+type DelegationRequestData struct {
+	AdvisorEmail string
+	Access       string
+	ConsentLink  string
+	ExpiresAt    string
+}
+
+// RebalanceReminderData is the data of the RebalanceReminder email
+type RebalanceReminderData struct {
+	PlanName      string
+	PendingTrades int
+	LastActivity  string
+}
+
+// PerformanceDigestData is the data of the PerformanceDigest email
+type PerformanceDigestData struct {
+	Period          string // Weekly or Monthly
+	StartDate       string
+	EndDate         string
+	Portfolios      []DigestPortfolio
+	TopMovers       []DigestMover
+	UnsubscribeLink string
+}
+
+// DigestPortfolio is one portfolio of a performance digest. Value, ValueChange, NetDeposits
+// and Return are empty when no valuation covers them.
+type DigestPortfolio struct {
+	Name        string
+	Currency    string
+	Value       string
+	ValueChange string
+	NetDeposits string
+	Return      string
+	Income      string
+}
+
+// DigestMover is one of the top movers of a performance digest
+type DigestMover struct {
+	Symbol        string
+	ChangePercent string
+	ValueChange   string
+}
@@ -0,0 +1,116 @@
+// Package emails renders the emails the app sends from templates, as a subject with plain
+// text and HTML bodies. The built-in templates are embedded; files of the same name in an
+// override directory replace them.
+package emails
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+// The emails, each rendered from the templates <name>.subject.txt, <name>.txt and <name>.html
+const (
+	PasswordReset      = "password_reset"
+	Invite             = "invite"
+	OrganizationInvite = "organization_invite"
+	DelegationRequest  = "delegation_request"
+	RebalanceReminder  = "rebalance_reminder"
+	PerformanceDigest  = "performance_digest"
+)
+
+//go:embed templates/*.html templates/*.txt
+var templateFiles embed.FS
+
+// Message is a rendered email
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Templates are the parsed email templates. HTML templates can use the "header" and
+// "footer" templates of layout.html for the branded frame around their content.
+type Templates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// LoadTemplates parses the built-in templates, then the files in dir that replace them. An
+// empty or missing dir uses the built-in templates only. Files that don't replace a built-in
+// template are rejected, as they would never be used.
+func LoadTemplates(dir string) (*Templates, error) {
+	t := &Templates{
+		text: texttemplate.Must(texttemplate.New("").ParseFS(templateFiles, "templates/*.txt")),
+		html: htmltemplate.Must(htmltemplate.New("").ParseFS(templateFiles, "templates/*.html")),
+	}
+	if dir == "" {
+		return t, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read email templates: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if _, err := fs.Stat(templateFiles, "templates/"+name); err != nil {
+			return nil, fmt.Errorf("email template %s does not replace a built-in template", name)
+		}
+
+		path := filepath.Join(dir, name)
+		if filepath.Ext(name) == ".html" {
+			_, err = t.html.ParseFiles(path)
+		} else {
+			_, err = t.text.ParseFiles(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid email template %s: %w", name, err)
+		}
+	}
+
+	return t, nil
+}
+
+// MustLoadTemplates returns the built-in templates, which are known to parse
+func MustLoadTemplates() *Templates {
+	t, err := LoadTemplates("")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render renders the email name with data
+func (t *Templates) Render(name string, data any) (*Message, error) {
+	var subject, text, html bytes.Buffer
+	if err := t.text.ExecuteTemplate(&subject, name+".subject.txt", data); err != nil {
+		return nil, fmt.Errorf("failed to render email subject: %w", err)
+	}
+	if err := t.text.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return nil, fmt.Errorf("failed to render email text: %w", err)
+	}
+	if err := t.html.ExecuteTemplate(&html, name+".html", data); err != nil {
+		return nil, fmt.Errorf("failed to render email HTML: %w", err)
+	}
+
+	return &Message{
+		// Subjects are a single line, whatever the template ends with
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()),
+		HTML:    html.String(),
+	}, nil
+}
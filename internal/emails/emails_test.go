@@ -0,0 +1,109 @@
+package emails
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates_Render(t *testing.T) {
+	message, err := MustLoadTemplates().Render(Invite, InviteData{
+		OrganizationName: "Smith & Sons",
+		InviteLink:       "https://app.example.com/reset-password?token=abc&x=<y>",
+		ExpiresAt:        "January 2, 2025",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "You have been invited to Smith & Sons on Portfolios", message.Subject)
+	assert.Contains(t, message.Text, "An account has been created for you in Smith & Sons.")
+	assert.Contains(t, message.Text, "https://app.example.com/reset-password?token=abc&x=<y>")
+	assert.Contains(t, message.Text, "This link will expire on January 2, 2025.")
+
+	// HTML escapes the data and wraps it in the layout
+	assert.Contains(t, message.HTML, "<strong>Smith &amp; Sons</strong>")
+	assert.Contains(t, message.HTML, `href="https://app.example.com/reset-password?token=abc&amp;x=%3cy%3e"`)
+	assert.Contains(t, message.HTML, "<!DOCTYPE html>")
+	assert.Contains(t, message.HTML, "The Portfolios Team")
+
+	message, err = MustLoadTemplates().Render(OrganizationInvite, OrganizationInviteData{
+		OrganizationName: "Smith & Sons",
+		InviterEmail:     "advisor@example.com",
+		Role:             "viewer",
+		InviteLink:       "https://app.example.com/invitations/accept?token=abc",
+		ExpiresAt:        "January 2, 2025",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "advisor@example.com invited you to Smith & Sons on Portfolios", message.Subject)
+	assert.Contains(t, message.Text, "invited you to join Smith & Sons as viewer.")
+	assert.Contains(t, message.HTML, "<strong>Smith &amp; Sons</strong>")
+
+	message, err = MustLoadTemplates().Render(DelegationRequest, DelegationRequestData{
+		AdvisorEmail: "advisor@example.com",
+		Access:       "view",
+		ConsentLink:  "https://app.example.com/delegations/consent?token=abc",
+		ExpiresAt:    "January 2, 2025",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "advisor@example.com asked for access to your portfolios on Portfolios", message.Subject)
+	assert.Contains(t, message.Text, "advisor@example.com asked to view your portfolios on your behalf.")
+	assert.Contains(t, message.HTML, "<strong>advisor@example.com</strong>")
+
+	_, err = MustLoadTemplates().Render("welcome", nil)
+	assert.Error(t, err)
+}
+
+func TestLoadTemplates(t *testing.T) {
+	t.Run("missing directory uses the built-in templates", func(t *testing.T) {
+		templates, err := LoadTemplates(filepath.Join(t.TempDir(), "missing"))
+		require.NoError(t, err)
+
+		message, err := templates.Render(PasswordReset, PasswordResetData{ResetLink: "https://example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "Password Reset Request", message.Subject)
+	})
+
+	t.Run("files replace the built-in templates", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, dir, "password_reset.subject.txt", "Reset your Acme password\n")
+		write(t, dir, "layout.html", `{{define "header"}}<div class="acme">{{end}}{{define "footer"}}</div>{{end}}`)
+
+		templates, err := LoadTemplates(dir)
+		require.NoError(t, err)
+
+		message, err := templates.Render(PasswordReset, PasswordResetData{ResetLink: "https://example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "Reset your Acme password", message.Subject)
+		assert.Contains(t, message.Text, "https://example.com")
+		assert.Contains(t, message.HTML, `<div class="acme"><p>Hello,</p>`)
+		assert.NotContains(t, message.HTML, "<!DOCTYPE html>")
+
+		// Other loads keep the built-in templates
+		message, err = MustLoadTemplates().Render(PasswordReset, PasswordResetData{ResetLink: "https://example.com"})
+		require.NoError(t, err)
+		assert.Equal(t, "Password Reset Request", message.Subject)
+	})
+
+	t.Run("rejects files that replace nothing", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, dir, "pasword_reset.txt", "Hello")
+
+		_, err := LoadTemplates(dir)
+		assert.ErrorContains(t, err, "pasword_reset.txt does not replace a built-in template")
+	})
+
+	t.Run("rejects templates that don't parse", func(t *testing.T) {
+		dir := t.TempDir()
+		write(t, dir, "invite.html", "{{.InviteLink")
+
+		_, err := LoadTemplates(dir)
+		assert.ErrorContains(t, err, "invalid email template invite.html")
+	})
+}
+
+func write(t *testing.T, dir, name, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+}
@@ -0,0 +1,27 @@
+package emails
+
+import (
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+// logTransport writes emails to the server log instead of sending them, so that links in
+// password resets and invites can be followed in development without a mail service
+type logTransport struct {
+	log logger.Logger
+}
+
+// NewLogTransport creates a transport that logs emails rather than sending them
+func NewLogTransport(log logger.Logger) Transport {
+	return &logTransport{log: log}
+}
+
+// Send logs an email's recipient, subject and plain text body
+func (t *logTransport) Send(email *Email) error {
+	t.log.Info().
+		Str("from", email.From).
+		Str("to", email.To).
+		Str("subject", email.Subject).
+		Str("body", email.Text).
+		Msg("Email not sent, as the log email transport is selected")
+	return nil
+}
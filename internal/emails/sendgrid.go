@@ -0,0 +1,91 @@
+package emails
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// sendGridEndpoint is SendGrid's v3 mail send API
+const sendGridEndpoint = "https://api.sendgrid.com/v3/mail/send"
+
+// sendGridTransport sends emails through SendGrid's web API
+type sendGridTransport struct {
+	apiKey   string
+	endpoint string
+	client   *http.Client
+}
+
+// NewSendGridTransport creates a transport that sends emails with a SendGrid API key
+func NewSendGridTransport(apiKey string) Transport {
+	return &sendGridTransport{
+		apiKey:   apiKey,
+		endpoint: sendGridEndpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// sendGridAddress is an address in a SendGrid mail send request
+type sendGridAddress struct {
+	Email string `json:"email"`
+}
+
+// sendGridContent is one of the bodies of a SendGrid mail send request
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// sendGridPersonalization is a SendGrid mail send request's recipients
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+// sendGridRequest is the body of a SendGrid mail send request
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+// Send posts an email to SendGrid, which accepts it for delivery with 202 Accepted
+func (t *sendGridTransport) Send(email *Email) error {
+	request := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{{Email: email.To}}}},
+		From:             sendGridAddress{Email: email.From},
+		Subject:          email.Subject,
+		Content: []sendGridContent{
+			{Type: "text/plain", Value: email.Text},
+			{Type: "text/html", Value: email.HTML},
+		},
+		Headers: email.Headers,
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode email: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create SendGrid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to send email: SendGrid responded with status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package emails
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// sesTransport sends emails through the Amazon SES v2 API, signing requests with AWS
+// Signature Version 4
+type sesTransport struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	endpoint        string
+	client          *http.Client
+	now             func() time.Time
+}
+
+// NewSESTransport creates a transport that sends emails through Amazon SES in a region, with
+// the credentials of an IAM user allowed to call ses:SendEmail
+func NewSESTransport(region, accessKeyID, secretAccessKey string) Transport {
+	return &sesTransport{
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		endpoint:        fmt.Sprintf("https://email.%s.amazonaws.com", region),
+		client:          &http.Client{Timeout: 10 * time.Second},
+		now:             time.Now,
+	}
+}
+
+// sesRequest is the body of an SES v2 SendEmail request with a raw MIME message, which
+// keeps the extra headers and both alternatives
+type sesRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	Content struct {
+		Raw struct {
+			Data []byte `json:"Data"` // Encoded as base64
+		} `json:"Raw"`
+	} `json:"Content"`
+}
+
+// Send posts an email to SES as a raw MIME message
+func (t *sesTransport) Send(email *Email) error {
+	message, err := compose(email)
+	if err != nil {
+		return err
+	}
+
+	var request sesRequest
+	request.FromEmailAddress = email.From
+	request.Destination.ToAddresses = []string{email.To}
+	request.Content.Raw.Data = message
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode email: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, t.endpoint+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create SES request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	t.sign(req, body)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to send email: SES responded with status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
+
+// sign adds the AWS Signature Version 4 headers to a request for the ses service, signing
+// its content type, host, payload hash and date
+func (t *sesTransport) sign(req *http.Request, body []byte) {
+	now := t.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	const signedHeaders = "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := req.Method + "\n" +
+		req.URL.EscapedPath() + "\n" +
+		req.URL.RawQuery + "\n" +
+		"content-type:" + req.Header.Get("Content-Type") + "\n" +
+		"host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n" +
+		"\n" +
+		signedHeaders + "\n" +
+		payloadHash
+
+	scope := date + "/" + t.region + "/ses/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+t.secretAccessKey), date)
+	key = hmacSHA256(key, t.region)
+	key = hmacSHA256(key, "ses")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.accessKeyID, scope, signedHeaders, signature))
+}
+
+// sha256Hex returns the hex encoded SHA-256 digest of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data keyed by key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
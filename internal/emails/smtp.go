@@ -0,0 +1,108 @@
+package emails
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/smtp"
+)
+
+// smtpTransport sends emails through an SMTP server, preferring TLS
+type smtpTransport struct {
+	host     string
+	port     int
+	username string
+	password string
+}
+
+// NewSMTPTransport creates a transport that sends emails through an SMTP server
+func NewSMTPTransport(host string, port int, username, password string) Transport {
+	return &smtpTransport{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+	}
+}
+
+// Send delivers an email, falling back to a plain connection when TLS fails
+func (t *smtpTransport) Send(email *Email) error {
+	message, err := compose(email)
+	if err != nil {
+		return err
+	}
+
+	// Set up authentication
+	auth := smtp.PlainAuth("", t.username, t.password, t.host)
+
+	// Connect to SMTP server with TLS
+	addr := fmt.Sprintf("%s:%d", t.host, t.port)
+
+	// Try to send with TLS
+	err = t.sendWithTLS(addr, auth, message, email.From, email.To)
+	if err != nil {
+		// If TLS fails, try without TLS for development/testing
+		err = smtp.SendMail(addr, auth, email.From, []string{email.To}, message)
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// sendWithTLS sends email with TLS/SSL connection
+func (t *smtpTransport) sendWithTLS(addr string, auth smtp.Auth, message []byte, from, to string) error {
+	// Create TLS configuration with minimum TLS 1.2 for security
+	tlsConfig := &tls.Config{
+		ServerName: t.host,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	// Connect to the SMTP server
+	client, err := smtp.Dial(addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	defer func() {
+		_ = client.Close()
+	}()
+
+	// Start TLS
+	if err = client.StartTLS(tlsConfig); err != nil {
+		return fmt.Errorf("failed to start TLS: %w", err)
+	}
+
+	// Authenticate
+	if err = client.Auth(auth); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	// Set sender
+	if err = client.Mail(from); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+
+	// Set recipient
+	if err = client.Rcpt(to); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+
+	// Send message
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to get data writer: %w", err)
+	}
+
+	_, err = w.Write(message)
+	if err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close data writer: %w", err)
+	}
+
+	// Send QUIT command
+	return client.Quit()
+}
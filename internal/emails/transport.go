@@ -0,0 +1,74 @@
+package emails
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
+)
+
+// Email is a rendered message with its sender, recipient and extra headers
+type Email struct {
+	From    string
+	To      string
+	Headers map[string]string // Extra headers, such as List-Unsubscribe
+	Message
+}
+
+// Transport delivers emails through one mail service
+type Transport interface {
+	Send(email *Email) error
+}
+
+// compose writes an email as a MIME message with plain text and HTML alternatives, for
+// transports that send raw messages
+func compose(email *Email) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", email.Text},
+		{"text/html; charset=utf-8", email.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to compose email: %w", err)
+		}
+		encoded := quotedprintable.NewWriter(w)
+		if _, err := encoded.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to compose email: %w", err)
+		}
+		if err := encoded.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compose email: %w", err)
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compose email: %w", err)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", email.From)
+	fmt.Fprintf(&message, "To: %s\r\n", email.To)
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", email.Subject))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%s\r\n", parts.Boundary())
+
+	// Sorted so that the same email always composes to the same headers
+	names := make([]string, 0, len(email.Headers))
+	for name := range email.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&message, "%s: %s\r\n", name, email.Headers[name])
+	}
+
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package emails
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEmail() *Email {
+	return &Email{
+		From:    "noreply@example.com",
+		To:      "user@example.com",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe>"},
+		Message: Message{
+			Subject: "Your weekly digest – Growth",
+			Text:    "Hello,\n\nYour portfolios gained 1.2%.",
+			HTML:    "<p>Your portfolios gained <strong>1.2%</strong>.</p>",
+		},
+	}
+}
+
+func TestCompose(t *testing.T) {
+	message, err := compose(testEmail())
+	require.NoError(t, err)
+
+	raw := string(message)
+	assert.Contains(t, raw, "From: noreply@example.com\r\n")
+	assert.Contains(t, raw, "To: user@example.com\r\n")
+	assert.Contains(t, raw, "Subject: =?utf-8?q?Your_weekly_digest_=E2=80=93_Growth?=\r\n")
+	assert.Contains(t, raw, "List-Unsubscribe: <https://example.com/unsubscribe>\r\n")
+	assert.Contains(t, raw, "Content-Type: multipart/alternative; boundary=")
+	assert.Contains(t, raw, "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, raw, "Content-Type: text/html; charset=utf-8")
+	assert.Contains(t, raw, "Your portfolios gained <strong>1.2%</strong>.")
+}
+
+func TestSendGridTransport_Send(t *testing.T) {
+	var request sendGridRequest
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	transport := NewSendGridTransport("SG.key").(*sendGridTransport)
+	transport.endpoint = server.URL
+	require.NoError(t, transport.Send(testEmail()))
+
+	assert.Equal(t, "Bearer SG.key", authorization)
+	assert.Equal(t, "noreply@example.com", request.From.Email)
+	assert.Equal(t, "user@example.com", request.Personalizations[0].To[0].Email)
+	assert.Equal(t, "Your weekly digest – Growth", request.Subject)
+	require.Len(t, request.Content, 2)
+	assert.Equal(t, "text/plain", request.Content[0].Type)
+	assert.Equal(t, "text/html", request.Content[1].Type)
+	assert.Equal(t, "<https://example.com/unsubscribe>", request.Headers["List-Unsubscribe"])
+
+	t.Run("rejected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"errors":[{"message":"The provided authorization grant is invalid"}]}`, http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		transport := NewSendGridTransport("SG.wrong").(*sendGridTransport)
+		transport.endpoint = server.URL
+		err := transport.Send(testEmail())
+		assert.ErrorContains(t, err, "SendGrid responded with status 401")
+		assert.ErrorContains(t, err, "authorization grant is invalid")
+	})
+}
+
+func TestSESTransport_Send(t *testing.T) {
+	var request sesRequest
+	var path, authorization, amzDate string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		amzDate = r.Header.Get("X-Amz-Date")
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, sha256Hex(body), r.Header.Get("X-Amz-Content-Sha256"))
+		require.NoError(t, json.Unmarshal(body, &request))
+		_, _ = w.Write([]byte(`{"MessageId":"abc"}`))
+	}))
+	defer server.Close()
+
+	transport := NewSESTransport("eu-west-1", "AKIDEXAMPLE", "secret").(*sesTransport)
+	transport.endpoint = server.URL
+	transport.now = func() time.Time { return time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC) }
+	require.NoError(t, transport.Send(testEmail()))
+
+	assert.Equal(t, "/v2/email/outbound-emails", path)
+	assert.Equal(t, "20250304T050607Z", amzDate)
+	assert.True(t, strings.HasPrefix(authorization,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250304/eu-west-1/ses/aws4_request, "+
+			"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="), authorization)
+	assert.Equal(t, "noreply@example.com", request.FromEmailAddress)
+	assert.Equal(t, []string{"user@example.com"}, request.Destination.ToAddresses)
+
+	// The raw message is the composed MIME message, keeping the extra headers
+	assert.Contains(t, string(request.Content.Raw.Data), "Subject: =?utf-8?q?Your_weekly_digest_=E2=80=93_Growth?=")
+	assert.Contains(t, string(request.Content.Raw.Data), "List-Unsubscribe: <https://example.com/unsubscribe>")
+
+	t.Run("rejected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message":"Email address is not verified."}`, http.StatusBadRequest)
+		}))
+		defer server.Close()
+
+		transport := NewSESTransport("eu-west-1", "AKIDEXAMPLE", "secret").(*sesTransport)
+		transport.endpoint = server.URL
+		assert.ErrorContains(t, transport.Send(testEmail()), "SES responded with status 400")
+	})
+}
+
+func TestLogTransport_Send(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf)
+
+	require.NoError(t, NewLogTransport(&log).Send(testEmail()))
+
+	assert.Contains(t, buf.String(), `"to":"user@example.com"`)
+	assert.Contains(t, buf.String(), `"subject":"Your weekly digest – Growth"`)
+	assert.Contains(t, buf.String(), "Your portfolios gained 1.2%.")
+}
+
+func TestSMTPTransport_Send(t *testing.T) {
+	err := NewSMTPTransport("invalid-host-that-does-not-exist.local", 587, "user", "pass").Send(testEmail())
+	assert.ErrorContains(t, err, "failed to send email")
+}
@@ -0,0 +1,52 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// CSVContentType is the media type of CSV exports
+const CSVContentType = "text/csv; charset=utf-8"
+
+// CSVEncoder writes rows of a CSV file, starting with its header row
+type CSVEncoder struct {
+	stream
+	csv           *csv.Writer
+	header        []string
+	headerWritten bool
+}
+
+// NewCSVEncoder creates a CSVEncoder writing to w. The header is written before the first
+// row, or on Flush if there are no rows.
+func NewCSVEncoder(w io.Writer, header []string) *CSVEncoder {
+	s := newStream(w)
+	return &CSVEncoder{stream: s, csv: csv.NewWriter(s.buf), header: header}
+}
+
+// Encode writes one row
+func (e *CSVEncoder) Encode(row []string) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.csv.Write(row)
+}
+
+// Flush sends the rows encoded so far on to the client
+func (e *CSVEncoder) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.csv.Flush()
+	if err := e.csv.Error(); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+func (e *CSVEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.csv.Write(e.header)
+}
@@ -0,0 +1,33 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONLinesContentType is the media type of JSON Lines exports
+const JSONLinesContentType = "application/x-ndjson"
+
+// JSONLinesEncoder writes records as JSON Lines: one JSON document per line
+type JSONLinesEncoder struct {
+	stream
+	enc *json.Encoder
+}
+
+// NewJSONLinesEncoder creates a JSONLinesEncoder writing to w
+func NewJSONLinesEncoder(w io.Writer) *JSONLinesEncoder {
+	s := newStream(w)
+	enc := json.NewEncoder(s.buf)
+	enc.SetEscapeHTML(false)
+	return &JSONLinesEncoder{stream: s, enc: enc}
+}
+
+// Encode writes one record on its own line
+func (e *JSONLinesEncoder) Encode(record interface{}) error {
+	return e.enc.Encode(record)
+}
+
+// Flush sends the records encoded so far on to the client
+func (e *JSONLinesEncoder) Flush() error {
+	return e.flush()
+}
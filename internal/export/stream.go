@@ -0,0 +1,35 @@
+// Package export streams large datasets to clients as CSV or JSON Lines. Records are encoded
+// as they are read and flushed to the client after every page, so an export never holds
+// more than a page of rows in memory however large the account is.
+package export
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+)
+
+// bufferSize is how much encoded output is collected before it is written on
+const bufferSize = 32 * 1024
+
+// stream buffers encoded records on their way to w
+type stream struct {
+	w   io.Writer
+	buf *bufio.Writer
+}
+
+func newStream(w io.Writer) stream {
+	return stream{w: w, buf: bufio.NewWriterSize(w, bufferSize)}
+}
+
+// flush writes out the buffered records and, when w is an HTTP response, sends them on to
+// the client as a chunk instead of waiting for the response to end
+func (s stream) flush() error {
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
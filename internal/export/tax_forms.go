@@ -0,0 +1,157 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// TXFContentType is the media type of Tax Exchange Format exports
+const TXFContentType = "text/plain; charset=utf-8"
+
+// taxFormDate is how tax forms write dates
+const taxFormDate = "01/02/2006"
+
+// washSaleCode is the Form 8949 adjustment code of a loss disallowed by a wash sale
+const washSaleCode = "W"
+
+// TaxFormEncoder writes a tax year's realized gains as a file tax software can import
+type TaxFormEncoder interface {
+	// Encode writes one realized gain or loss
+	Encode(gain *services.RealizedGain) error
+
+	// Flush sends the gains encoded so far on to the client
+	Flush() error
+
+	// ContentType returns the media type of the files this encoder writes
+	ContentType() string
+
+	// FileExtension returns the file name extension of the files, without the dot
+	FileExtension() string
+}
+
+// NewTaxFormEncoder returns the encoder for a tax export format writing to w. generated is
+// the date TXF files are stamped with. An empty format exports Form 8949 CSV.
+func NewTaxFormEncoder(format dto.TaxExportFormat, w io.Writer, generated time.Time) (TaxFormEncoder, error) {
+	switch format {
+	case dto.TaxExportForm8949CSV, "":
+		return &form8949CSVEncoder{csv: NewCSVEncoder(w, form8949CSVHeader)}, nil
+	case dto.TaxExportTXF:
+		return &txfEncoder{stream: newStream(w), generated: generated}, nil
+	default:
+		return nil, fmt.Errorf("unsupported tax export format: %s", format)
+	}
+}
+
+// form8949CSVHeader follows the columns (a) through (h) of Form 8949, with the part of the
+// form each row belongs on last
+var form8949CSVHeader = []string{
+	"Description", "Date Acquired", "Date Sold", "Proceeds", "Cost Basis",
+	"Adjustment Code", "Adjustment Amount", "Gain or Loss", "Term",
+}
+
+// form8949CSVEncoder writes gains as Form 8949 CSV rows
+type form8949CSVEncoder struct {
+	csv *CSVEncoder
+}
+
+func (e *form8949CSVEncoder) Encode(gain *services.RealizedGain) error {
+	code, adjustment := "", ""
+	if gain.DisallowedLoss != nil {
+		code, adjustment = washSaleCode, gain.DisallowedLoss.StringFixed(2)
+	}
+	term := "SHORT"
+	if gain.IsLongTerm {
+		term = "LONG"
+	}
+	return e.csv.Encode([]string{
+		taxFormDescription(gain),
+		gain.PurchaseDate.Format(taxFormDate),
+		gain.SaleDate.Format(taxFormDate),
+		gain.Proceeds.StringFixed(2),
+		gain.CostBasis.StringFixed(2),
+		code,
+		adjustment,
+		gain.Gain.StringFixed(2),
+		term,
+	})
+}
+
+func (e *form8949CSVEncoder) Flush() error          { return e.csv.Flush() }
+func (e *form8949CSVEncoder) ContentType() string   { return CSVContentType }
+func (e *form8949CSVEncoder) FileExtension() string { return "csv" }
+
+// TXF reference numbers of sales reported on Form 8949 with their basis reported to the IRS
+const (
+	txfShortTermSale = "321"
+	txfLongTermSale  = "323"
+)
+
+// txfEncoder writes gains as Tax Exchange Format (version 042) records, one per gain, after
+// a header naming the program and date the file was generated
+type txfEncoder struct {
+	stream
+	generated     time.Time
+	headerWritten bool
+}
+
+func (e *txfEncoder) Encode(gain *services.RealizedGain) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	refNumber := txfShortTermSale
+	if gain.IsLongTerm {
+		refNumber = txfLongTermSale
+	}
+	lines := []string{
+		"TD",
+		"N" + refNumber,
+		"C1",
+		"L1",
+		"P" + taxFormDescription(gain),
+		"D" + gain.PurchaseDate.Format(taxFormDate),
+		"D" + gain.SaleDate.Format(taxFormDate),
+		"$" + gain.CostBasis.StringFixed(2),
+		"$" + gain.Proceeds.StringFixed(2),
+	}
+	// A wash sale's disallowed loss follows the proceeds
+	if gain.DisallowedLoss != nil {
+		lines = append(lines, "$"+gain.DisallowedLoss.StringFixed(2))
+	}
+	return e.writeLines(append(lines, "^"))
+}
+
+func (e *txfEncoder) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+func (e *txfEncoder) ContentType() string   { return TXFContentType }
+func (e *txfEncoder) FileExtension() string { return "txf" }
+
+func (e *txfEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.writeLines([]string{"V042", "Aportfolios", "D" + e.generated.Format(taxFormDate), "^"})
+}
+
+func (e *txfEncoder) writeLines(lines []string) error {
+	for _, line := range lines {
+		if _, err := e.buf.WriteString(line + "\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// taxFormDescription describes the property sold, such as "10 sh AAPL"
+func taxFormDescription(gain *services.RealizedGain) string {
+	return fmt.Sprintf("%s sh %s", gain.Quantity.String(), gain.Symbol)
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+func testRealizedGains() []*services.RealizedGain {
+	disallowed := decimal.NewFromInt(600)
+	return []*services.RealizedGain{
+		{
+			Symbol: "AAPL", PurchaseDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), SaleDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(100), CostBasis: decimal.NewFromInt(5000), Proceeds: decimal.NewFromInt(4000),
+			Gain: decimal.NewFromInt(-400), DisallowedLoss: &disallowed,
+		},
+		{
+			Symbol: "VTI", PurchaseDate: time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC), SaleDate: time.Date(2024, 9, 16, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.RequireFromString("2.5"), CostBasis: decimal.RequireFromString("501.1"), Proceeds: decimal.RequireFromString("650.75"),
+			Gain: decimal.RequireFromString("149.65"), IsLongTerm: true,
+		},
+	}
+}
+
+func TestTaxFormEncoder_Form8949CSV(t *testing.T) {
+	w := httptest.NewRecorder()
+	enc, err := NewTaxFormEncoder("", w, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, CSVContentType, enc.ContentType())
+	assert.Equal(t, "csv", enc.FileExtension())
+
+	for _, gain := range testRealizedGains() {
+		require.NoError(t, enc.Encode(gain))
+	}
+	require.NoError(t, enc.Flush())
+
+	assert.Equal(t, "Description,Date Acquired,Date Sold,Proceeds,Cost Basis,Adjustment Code,Adjustment Amount,Gain or Loss,Term\n"+
+		"100 sh AAPL,01/10/2024,06/01/2024,4000.00,5000.00,W,600.00,-400.00,SHORT\n"+
+		"2.5 sh VTI,03/02/2021,09/16/2024,650.75,501.10,,,149.65,LONG\n", w.Body.String())
+}
+
+func TestTaxFormEncoder_TXF(t *testing.T) {
+	w := httptest.NewRecorder()
+	enc, err := NewTaxFormEncoder(dto.TaxExportTXF, w, time.Date(2025, 2, 14, 9, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "txf", enc.FileExtension())
+
+	for _, gain := range testRealizedGains() {
+		require.NoError(t, enc.Encode(gain))
+	}
+	require.NoError(t, enc.Flush())
+
+	assert.Equal(t, "V042\r\nAportfolios\r\nD02/14/2025\r\n^\r\n"+
+		"TD\r\nN321\r\nC1\r\nL1\r\nP100 sh AAPL\r\nD01/10/2024\r\nD06/01/2024\r\n$5000.00\r\n$4000.00\r\n$600.00\r\n^\r\n"+
+		"TD\r\nN323\r\nC1\r\nL1\r\nP2.5 sh VTI\r\nD03/02/2021\r\nD09/16/2024\r\n$501.10\r\n$650.75\r\n^\r\n", w.Body.String())
+}
+
+func TestTaxFormEncoder_WritesTheHeaderWithoutGains(t *testing.T) {
+	w := httptest.NewRecorder()
+	enc, err := NewTaxFormEncoder(dto.TaxExportTXF, w, time.Date(2025, 2, 14, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.NoError(t, enc.Flush())
+	assert.Equal(t, "V042\r\nAportfolios\r\nD02/14/2025\r\n^\r\n", w.Body.String())
+}
+
+func TestTaxFormEncoder_UnsupportedFormat(t *testing.T) {
+	_, err := NewTaxFormEncoder("pdf", httptest.NewRecorder(), time.Now())
+	assert.Error(t, err)
+}
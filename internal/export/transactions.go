@@ -0,0 +1,80 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// TransactionEncoder writes a portfolio's transactions in one export format
+type TransactionEncoder interface {
+	// Encode writes one transaction
+	Encode(transaction *models.Transaction) error
+
+	// Flush sends the transactions encoded so far on to the client
+	Flush() error
+
+	// ContentType returns the media type of the files this encoder writes
+	ContentType() string
+
+	// FileExtension returns the file name extension of the files, without the dot
+	FileExtension() string
+}
+
+// NewTransactionEncoder returns the encoder for an export format writing to w. An empty
+// format exports CSV.
+func NewTransactionEncoder(format dto.ExportFormat, w io.Writer) (TransactionEncoder, error) {
+	switch format {
+	case dto.ExportFormatCSV, "":
+		return &transactionCSVEncoder{csv: NewCSVEncoder(w, transactionCSVHeader)}, nil
+	case dto.ExportFormatJSONLines:
+		return &transactionJSONLinesEncoder{jsonl: NewJSONLinesEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// transactionCSVHeader matches the generic CSV import format, so an exported file can be
+// imported into another portfolio
+var transactionCSVHeader = []string{"Date", "Type", "Symbol", "Quantity", "Price", "Commission", "Currency", "Notes"}
+
+// transactionCSVEncoder writes transactions as CSV rows
+type transactionCSVEncoder struct {
+	csv *CSVEncoder
+}
+
+func (e *transactionCSVEncoder) Encode(transaction *models.Transaction) error {
+	price := ""
+	if transaction.Price != nil {
+		price = transaction.Price.String()
+	}
+	return e.csv.Encode([]string{
+		transaction.Date.Format("2006-01-02"),
+		string(transaction.Type),
+		transaction.Symbol,
+		transaction.Quantity.String(),
+		price,
+		transaction.Commission.String(),
+		transaction.Currency,
+		transaction.Notes,
+	})
+}
+
+func (e *transactionCSVEncoder) Flush() error          { return e.csv.Flush() }
+func (e *transactionCSVEncoder) ContentType() string   { return CSVContentType }
+func (e *transactionCSVEncoder) FileExtension() string { return "csv" }
+
+// transactionJSONLinesEncoder writes transactions as JSON Lines, one transaction per line
+type transactionJSONLinesEncoder struct {
+	jsonl *JSONLinesEncoder
+}
+
+func (e *transactionJSONLinesEncoder) Encode(transaction *models.Transaction) error {
+	return e.jsonl.Encode(transaction)
+}
+
+func (e *transactionJSONLinesEncoder) Flush() error          { return e.jsonl.Flush() }
+func (e *transactionJSONLinesEncoder) ContentType() string   { return JSONLinesContentType }
+func (e *transactionJSONLinesEncoder) FileExtension() string { return "jsonl" }
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services/csv_parsers"
+)
+
+func testTransactions() []*models.Transaction {
+	price := decimal.RequireFromString("150.25")
+	return []*models.Transaction{
+		{
+			ID: uuid.New(), Type: models.TransactionTypeBuy, Symbol: "AAPL",
+			Date:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(10), Price: &price, Commission: decimal.RequireFromString("1.5"),
+			Currency: "USD", Notes: `Bought "on the dip", finally`,
+		},
+		{
+			ID: uuid.New(), Type: models.TransactionTypeDividend, Symbol: "AAPL",
+			Date:     time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.RequireFromString("2.4"), Commission: decimal.Zero, Currency: "USD",
+		},
+	}
+}
+
+func TestTransactionEncoder_CSVRoundTripsThroughImport(t *testing.T) {
+	w := httptest.NewRecorder()
+	enc, err := NewTransactionEncoder("", w)
+	require.NoError(t, err)
+	assert.Equal(t, CSVContentType, enc.ContentType())
+	assert.Equal(t, "csv", enc.FileExtension())
+
+	for _, tx := range testTransactions() {
+		require.NoError(t, enc.Encode(tx))
+	}
+	assert.False(t, w.Flushed, "nothing is sent before a flush")
+	require.NoError(t, enc.Flush())
+	assert.True(t, w.Flushed)
+
+	imported, importErrors, err := csv_parsers.NewGenericParser().Parse(strings.NewReader(w.Body.String()))
+	require.NoError(t, err)
+	assert.Empty(t, importErrors)
+	require.Len(t, imported, 2)
+	assert.Equal(t, models.TransactionTypeBuy, imported[0].Type)
+	assert.True(t, imported[0].Price.Equal(decimal.RequireFromString("150.25")))
+	assert.True(t, imported[0].Commission.Equal(decimal.RequireFromString("1.5")))
+	assert.Equal(t, `Bought "on the dip", finally`, imported[0].Notes)
+	assert.Equal(t, models.TransactionTypeDividend, imported[1].Type)
+	assert.Nil(t, imported[1].Price)
+}
+
+func TestTransactionEncoder_CSVWithoutTransactions(t *testing.T) {
+	w := httptest.NewRecorder()
+	enc, err := NewTransactionEncoder(dto.ExportFormatCSV, w)
+	require.NoError(t, err)
+	require.NoError(t, enc.Flush())
+
+	assert.Equal(t, "Date,Type,Symbol,Quantity,Price,Commission,Currency,Notes\n", w.Body.String())
+}
+
+func TestTransactionEncoder_JSONLines(t *testing.T) {
+	w := httptest.NewRecorder()
+	enc, err := NewTransactionEncoder(dto.ExportFormatJSONLines, w)
+	require.NoError(t, err)
+	assert.Equal(t, JSONLinesContentType, enc.ContentType())
+
+	transactions := testTransactions()
+	for _, tx := range transactions {
+		require.NoError(t, enc.Encode(tx))
+	}
+	require.NoError(t, enc.Flush())
+
+	scanner := bufio.NewScanner(strings.NewReader(w.Body.String()))
+	var lines int
+	for scanner.Scan() {
+		var decoded models.Transaction
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &decoded))
+		assert.Equal(t, transactions[lines].ID, decoded.ID)
+		lines++
+	}
+	assert.Equal(t, 2, lines)
+}
+
+func TestNewTransactionEncoder_UnsupportedFormat(t *testing.T) {
+	_, err := NewTransactionEncoder("xlsx", httptest.NewRecorder())
+	assert.Error(t, err)
+}
@@ -0,0 +1,113 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// Metadata keys are the lower-cased HTTP header names, so callers authenticate the same way
+// over both APIs
+var (
+	authorizationMetadataKey = strings.ToLower(middleware.AuthorizationHeader)
+	apiKeyMetadataKey        = strings.ToLower(middleware.APIKeyHeader)
+)
+
+// userIDKey is the context key for the authenticated user ID
+type userIDKey struct{}
+
+// Auth holds what the server needs to authenticate calls
+type Auth struct {
+	// TokenService validates user JWTs sent as "authorization: Bearer <token>"
+	TokenService *services.TokenService
+	// APIKeys, if set, also lets calls authenticate with an API key sent as "x-api-key"
+	APIKeys services.APIKeyService
+	// Users, if set, rejects calls from disabled accounts and accounts pending a forced
+	// password reset
+	Users middleware.UserLookup
+	// TenantSchemas, if set, routes calls to the schema of the user's organization
+	TenantSchemas middleware.TenantSchemaResolver
+}
+
+// userID returns the ID of the user the call was authenticated as
+func userID(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey{}).(string)
+	return id
+}
+
+// authenticate resolves the caller from the call's metadata and returns a context carrying
+// their user ID and, in multi-schema mode, their organization's schema
+func (a Auth) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var id string
+	if keys := md.Get(apiKeyMetadataKey); len(keys) > 0 && a.APIKeys != nil {
+		apiKey, err := a.APIKeys.Authenticate(keys[0])
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or revoked API key")
+		}
+		id = apiKey.UserID.String()
+	} else {
+		values := md.Get(authorizationMetadataKey)
+		if len(values) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+		}
+		tokenString, ok := strings.CutPrefix(values[0], middleware.BearerPrefix)
+		if !ok || tokenString == "" {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata must be a bearer token")
+		}
+		token, err := a.TokenService.ValidateToken(tokenString)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "invalid or expired token")
+		}
+		id, err = a.TokenService.ExtractUserID(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "failed to extract user information from token")
+		}
+	}
+
+	if a.Users != nil {
+		user, err := a.Users.FindByID(id)
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			return nil, status.Error(codes.Unauthenticated, "user no longer exists")
+		case err != nil:
+			return nil, status.Error(codes.Internal, "failed to load user")
+		case user.IsDisabled():
+			return nil, status.Error(codes.PermissionDenied, "this account has been disabled")
+		case user.PasswordResetRequired:
+			return nil, status.Error(codes.PermissionDenied, "a password reset is required")
+		}
+	}
+
+	if a.TenantSchemas != nil {
+		schema, err := a.TenantSchemas.FindSchemaNameByUserID(id)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to resolve organization")
+		}
+		if schema != "" {
+			ctx = database.WithSchema(ctx, schema)
+		}
+	}
+
+	return context.WithValue(ctx, userIDKey{}, id), nil
+}
+
+// unaryInterceptor authenticates every unary call before it reaches a service
+func (a Auth) unaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
@@ -0,0 +1,85 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// notFoundErrors are the service errors for records that don't exist
+var notFoundErrors = []error{
+	models.ErrPortfolioNotFound,
+	models.ErrTransactionNotFound,
+	models.ErrHoldingNotFound,
+	models.ErrPerformanceSnapshotNotFound,
+}
+
+// invalidArgumentErrors are the service errors for requests that fail validation
+var invalidArgumentErrors = []error{
+	models.ErrPortfolioNameRequired,
+	models.ErrInvalidCurrency,
+	models.ErrInvalidCostBasisMethod,
+	models.ErrInvalidTaxRegime,
+	models.ErrInvalidPortfolioID,
+	models.ErrInvalidTransactionType,
+	models.ErrInvalidQuantity,
+	models.ErrInvalidPrice,
+	models.ErrInvalidSymbol,
+	models.ErrUnknownSymbol,
+	models.ErrInvalidAssetType,
+	models.ErrInvalidCryptoPair,
+	models.ErrInvalidCryptoQuantity,
+	models.ErrInvalidCryptoCurrency,
+	models.ErrInvalidOptionSymbol,
+	models.ErrInvalidOptionQuantity,
+	models.ErrInvalidOptionTrade,
+}
+
+// toStatus maps a service error to the status returned to the caller. Unexpected errors
+// are reported as Internal without their details, as the HTTP API does.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Err()
+	}
+
+	var conflict *models.VersionConflictError
+	if errors.As(err, &conflict) {
+		return status.Error(codes.Aborted, fmt.Sprintf("%s; fetch it again and retry", conflict.Error()))
+	}
+
+	for _, target := range notFoundErrors {
+		if errors.Is(err, target) {
+			return status.Error(codes.NotFound, target.Error())
+		}
+	}
+	for _, target := range invalidArgumentErrors {
+		if errors.Is(err, target) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	switch {
+	case errors.Is(err, models.ErrUnauthorizedAccess):
+		return status.Error(codes.PermissionDenied, "access denied to this portfolio")
+	case errors.Is(err, models.ErrPortfolioDuplicateName):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, models.ErrVersionRequired), errors.Is(err, models.ErrInsufficientShares),
+		errors.Is(err, models.ErrBlackoutPeriod):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, models.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,99 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/lenon/portfolios/internal/services"
+	pb "github.com/lenon/portfolios/pkg/pb/portfolios/v1"
+)
+
+// maxQuoteSymbols is the most symbols GetQuotes accepts, as in the HTTP API
+const maxQuoteSymbols = 100
+
+// marketDataServer serves MarketDataService from the market data service
+type marketDataServer struct {
+	pb.UnimplementedMarketDataServiceServer
+	marketDataService services.MarketDataService
+}
+
+// GetQuote returns the latest quote for a symbol
+func (s *marketDataServer) GetQuote(ctx context.Context, req *pb.GetQuoteRequest) (*pb.Quote, error) {
+	if req.GetSymbol() == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+
+	quote, err := s.marketDataService.GetQuote(ctx, req.GetSymbol())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toQuote(quote), nil
+}
+
+// GetQuotes returns the latest quotes for several symbols
+func (s *marketDataServer) GetQuotes(ctx context.Context, req *pb.GetQuotesRequest) (*pb.GetQuotesResponse, error) {
+	if len(req.GetSymbols()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one symbol is required")
+	}
+	if len(req.GetSymbols()) > maxQuoteSymbols {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d symbols can be quoted at once", maxQuoteSymbols)
+	}
+
+	quotes, err := s.marketDataService.GetQuotes(ctx, req.GetSymbols())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.GetQuotesResponse{Quotes: make(map[string]*pb.Quote, len(quotes))}
+	for symbol, quote := range quotes {
+		response.Quotes[symbol] = toQuote(quote)
+	}
+	return response, nil
+}
+
+// GetHistoricalPrices returns the daily prices of a symbol between two dates
+func (s *marketDataServer) GetHistoricalPrices(ctx context.Context, req *pb.GetHistoricalPricesRequest) (*pb.GetHistoricalPricesResponse, error) {
+	if req.GetSymbol() == "" {
+		return nil, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+	if req.GetStartDate() == nil || req.GetEndDate() == nil {
+		return nil, status.Error(codes.InvalidArgument, "start_date and end_date are required")
+	}
+
+	prices, err := s.marketDataService.GetHistoricalPrices(ctx, req.GetSymbol(), req.GetStartDate().AsTime(), req.GetEndDate().AsTime())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.GetHistoricalPricesResponse{Prices: make([]*pb.HistoricalPrice, 0, len(prices))}
+	for _, price := range prices {
+		response.Prices = append(response.Prices, &pb.HistoricalPrice{
+			Date:   timestamppb.New(price.Date),
+			Open:   price.Open.String(),
+			High:   price.High.String(),
+			Low:    price.Low.String(),
+			Close:  price.Close.String(),
+			Volume: price.Volume,
+		})
+	}
+	return response, nil
+}
+
+// toQuote converts a market data quote to its protobuf message
+func toQuote(quote *services.Quote) *pb.Quote {
+	return &pb.Quote{
+		Symbol:        quote.Symbol,
+		Price:         quote.Price.String(),
+		Open:          quote.Open.String(),
+		High:          quote.High.String(),
+		Low:           quote.Low.String(),
+		Volume:        quote.Volume,
+		PreviousClose: quote.PreviousClose.String(),
+		Change:        quote.Change.String(),
+		ChangePercent: quote.ChangePercent.String(),
+		LastUpdated:   timestamppb.New(quote.LastUpdated),
+	}
+}
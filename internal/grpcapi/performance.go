@@ -0,0 +1,51 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/lenon/portfolios/internal/services"
+	pb "github.com/lenon/portfolios/pkg/pb/portfolios/v1"
+)
+
+// performanceServer serves PerformanceService from the performance analytics service
+type performanceServer struct {
+	pb.UnimplementedPerformanceServiceServer
+	analyticsService services.PerformanceAnalyticsService
+}
+
+// GetPerformanceMetrics returns the returns of one of the caller's portfolios over a
+// period, the last year by default
+func (s *performanceServer) GetPerformanceMetrics(ctx context.Context, req *pb.GetPerformanceMetricsRequest) (*pb.PerformanceMetrics, error) {
+	startDate := time.Now().AddDate(-1, 0, 0)
+	if req.GetStartDate() != nil {
+		startDate = req.GetStartDate().AsTime()
+	}
+	endDate := time.Now()
+	if req.GetEndDate() != nil {
+		endDate = req.GetEndDate().AsTime()
+	}
+
+	metrics, err := s.analyticsService.GetPerformanceMetrics(ctx, req.GetPortfolioId(), userID(ctx), startDate, endDate)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.PerformanceMetrics{
+		StartDate:           timestamppb.New(metrics.StartDate),
+		EndDate:             timestamppb.New(metrics.EndDate),
+		StartingValue:       metrics.StartingValue.String(),
+		EndingValue:         metrics.EndingValue.String(),
+		TotalReturn:         metrics.TotalReturn.String(),
+		TotalReturnPct:      metrics.TotalReturnPct.String(),
+		TimeWeightedReturn:  metrics.TimeWeightedReturn.String(),
+		MoneyWeightedReturn: metrics.MoneyWeightedReturn.String(),
+		AnnualizedReturn:    metrics.AnnualizedReturn.String(),
+		TotalDeposits:       metrics.TotalDeposits.String(),
+		TotalWithdrawals:    metrics.TotalWithdrawals.String(),
+		NetCashFlow:         metrics.NetCashFlow.String(),
+		Years:               metrics.Years,
+	}, nil
+}
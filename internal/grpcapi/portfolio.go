@@ -0,0 +1,115 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+	pb "github.com/lenon/portfolios/pkg/pb/portfolios/v1"
+)
+
+// portfolioServer serves PortfolioService from the portfolio and holding services
+type portfolioServer struct {
+	pb.UnimplementedPortfolioServiceServer
+	portfolioService services.PortfolioService
+	holdingService   services.HoldingService
+}
+
+// ListPortfolios lists the caller's portfolios
+func (s *portfolioServer) ListPortfolios(ctx context.Context, _ *pb.ListPortfoliosRequest) (*pb.ListPortfoliosResponse, error) {
+	portfolios, err := s.portfolioService.GetAllByUserID(ctx, userID(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListPortfoliosResponse{Portfolios: make([]*pb.Portfolio, 0, len(portfolios))}
+	for _, portfolio := range portfolios {
+		response.Portfolios = append(response.Portfolios, toPortfolio(portfolio))
+	}
+	return response, nil
+}
+
+// GetPortfolio returns one of the caller's portfolios
+func (s *portfolioServer) GetPortfolio(ctx context.Context, req *pb.GetPortfolioRequest) (*pb.Portfolio, error) {
+	portfolio, err := s.portfolioService.GetByID(ctx, req.GetId(), userID(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPortfolio(portfolio), nil
+}
+
+// CreatePortfolio creates a portfolio for the caller
+func (s *portfolioServer) CreatePortfolio(ctx context.Context, req *pb.CreatePortfolioRequest) (*pb.Portfolio, error) {
+	portfolio, err := s.portfolioService.Create(ctx,
+		userID(ctx),
+		req.GetName(),
+		req.GetDescription(),
+		req.GetBaseCurrency(),
+		models.CostBasisMethod(req.GetCostBasisMethod()),
+	)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPortfolio(portfolio), nil
+}
+
+// UpdatePortfolio renames or redescribes one of the caller's portfolios
+func (s *portfolioServer) UpdatePortfolio(ctx context.Context, req *pb.UpdatePortfolioRequest) (*pb.Portfolio, error) {
+	if req.GetVersion() < 1 {
+		return nil, toStatus(models.ErrVersionRequired)
+	}
+
+	portfolio, err := s.portfolioService.Update(ctx, req.GetId(), userID(ctx), int(req.GetVersion()), req.GetName(), req.GetDescription())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toPortfolio(portfolio), nil
+}
+
+// DeletePortfolio deletes one of the caller's portfolios with its transactions and holdings
+func (s *portfolioServer) DeletePortfolio(ctx context.Context, req *pb.DeletePortfolioRequest) (*pb.DeletePortfolioResponse, error) {
+	if err := s.portfolioService.Delete(ctx, req.GetId(), userID(ctx)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeletePortfolioResponse{}, nil
+}
+
+// ListHoldings lists the holdings of one of the caller's portfolios
+func (s *portfolioServer) ListHoldings(ctx context.Context, req *pb.ListHoldingsRequest) (*pb.ListHoldingsResponse, error) {
+	holdings, err := s.holdingService.GetByPortfolioID(ctx, req.GetPortfolioId(), userID(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListHoldingsResponse{Holdings: make([]*pb.Holding, 0, len(holdings))}
+	for _, holding := range holdings {
+		response.Holdings = append(response.Holdings, &pb.Holding{
+			Id:           holding.ID.String(),
+			PortfolioId:  holding.PortfolioID.String(),
+			Symbol:       holding.Symbol,
+			AssetType:    string(holding.AssetType),
+			Quantity:     holding.Quantity.String(),
+			CostBasis:    holding.CostBasis.String(),
+			AvgCostPrice: holding.AvgCostPrice.String(),
+			UpdatedAt:    timestamppb.New(holding.UpdatedAt),
+		})
+	}
+	return response, nil
+}
+
+// toPortfolio converts a Portfolio model to its protobuf message
+func toPortfolio(portfolio *models.Portfolio) *pb.Portfolio {
+	return &pb.Portfolio{
+		Id:              portfolio.ID.String(),
+		UserId:          portfolio.UserID.String(),
+		Name:            portfolio.Name,
+		Description:     portfolio.Description,
+		BaseCurrency:    portfolio.BaseCurrency,
+		CostBasisMethod: string(portfolio.CostBasisMethod),
+		Version:         int32(portfolio.Version),
+		CreatedAt:       timestamppb.New(portfolio.CreatedAt),
+		UpdatedAt:       timestamppb.New(portfolio.UpdatedAt),
+	}
+}
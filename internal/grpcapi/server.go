@@ -0,0 +1,72 @@
+// Package grpcapi serves the portfolio, transaction, market data and performance services
+// over gRPC for internal integrations. It calls the same service layer as the HTTP API, with
+// the same authentication and ownership checks; the protobuf definitions are in proto/ and
+// the generated code in pkg/pb.
+package grpcapi
+
+import (
+	"context"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/services"
+	pb "github.com/lenon/portfolios/pkg/pb/portfolios/v1"
+)
+
+// Services holds the services the gRPC API calls. MarketData and PerformanceAnalytics
+// depend on a market data provider and may be nil, in which case their gRPC services are
+// not registered. Blackout may be nil to skip blackout window checks.
+type Services struct {
+	Portfolio            services.PortfolioService
+	Transaction          services.TransactionService
+	Holding              services.HoldingService
+	Blackout             services.BlackoutService
+	MarketData           services.MarketDataService
+	PerformanceAnalytics services.PerformanceAnalyticsService
+}
+
+// NewServer builds a gRPC server with every enabled service registered. Calls are
+// authenticated with auth, and panics are logged to log and returned as Internal.
+func NewServer(s Services, auth Auth, log *logger.AppLogger, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts, grpc.ChainUnaryInterceptor(recoveryInterceptor(log), auth.unaryInterceptor))
+	server := grpc.NewServer(opts...)
+
+	pb.RegisterPortfolioServiceServer(server, &portfolioServer{
+		portfolioService: s.Portfolio,
+		holdingService:   s.Holding,
+	})
+	pb.RegisterTransactionServiceServer(server, &transactionServer{
+		transactionService: s.Transaction,
+		blackoutService:    s.Blackout,
+	})
+	if s.MarketData != nil {
+		pb.RegisterMarketDataServiceServer(server, &marketDataServer{marketDataService: s.MarketData})
+	}
+	if s.PerformanceAnalytics != nil {
+		pb.RegisterPerformanceServiceServer(server, &performanceServer{analyticsService: s.PerformanceAnalytics})
+	}
+
+	return server
+}
+
+// recoveryInterceptor turns a panic in a call into an Internal error instead of crashing
+// the server
+func recoveryInterceptor(log *logger.AppLogger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().
+					Interface("panic", r).
+					Str("method", info.FullMethod).
+					Str("stack", string(debug.Stack())).
+					Msg("Panic recovered in gRPC call")
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
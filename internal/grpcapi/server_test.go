@@ -0,0 +1,265 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+	pb "github.com/lenon/portfolios/pkg/pb/portfolios/v1"
+)
+
+// stubProvider serves a fixed quote so the market data service registers
+type stubProvider struct{}
+
+func (stubProvider) GetQuote(_ context.Context, symbol string) (*services.Quote, error) {
+	return &services.Quote{Symbol: symbol, Price: decimal.RequireFromString("120.5"), LastUpdated: time.Now()}, nil
+}
+
+func (p stubProvider) GetQuotes(ctx context.Context, symbols []string) (map[string]*services.Quote, error) {
+	quotes := make(map[string]*services.Quote, len(symbols))
+	for _, symbol := range symbols {
+		quotes[symbol], _ = p.GetQuote(ctx, symbol)
+	}
+	return quotes, nil
+}
+
+func (stubProvider) GetHistoricalPrices(_ context.Context, _ string, _, _ time.Time) ([]*services.HistoricalPrice, error) {
+	return nil, nil
+}
+
+func (stubProvider) GetExchangeRate(_ context.Context, _, _ string) (decimal.Decimal, error) {
+	return decimal.NewFromInt(1), nil
+}
+
+func (stubProvider) IsAvailable() bool { return true }
+
+// testServer is a gRPC server on an in-memory listener backed by an in-memory SQLite database
+type testServer struct {
+	conn   *grpc.ClientConn
+	tokens *services.TokenService
+	users  repository.UserRepository
+}
+
+func setupTestServer(t *testing.T) *testServer {
+	db, err := database.Connect("sqlite://:memory:")
+	require.NoError(t, err)
+	db.Logger = db.Logger.LogMode(gormlogger.Silent)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	userRepo := repository.NewUserRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	tokenService := services.NewTokenService("test-secret-key-for-jwt-signing-32-chars")
+
+	server := NewServer(Services{
+		Portfolio:   services.NewPortfolioService(portfolioRepo, userRepo),
+		Transaction: services.NewTransactionService(transactionRepo, portfolioRepo, holdingRepo),
+		Holding:     services.NewHoldingService(holdingRepo, portfolioRepo),
+		Blackout:    services.NewBlackoutService(repository.NewBlackoutRepository(db), portfolioRepo),
+		MarketData:  services.NewMarketDataService(stubProvider{}, time.Minute),
+	}, Auth{TokenService: tokenService, Users: userRepo}, logger.GetLogger())
+
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return &testServer{conn: conn, tokens: tokenService, users: userRepo}
+}
+
+// signIn creates a user and returns a context that authenticates calls as them
+func (s *testServer) signIn(t *testing.T) (context.Context, *models.User) {
+	user := &models.User{ID: uuid.New(), Email: uuid.NewString() + "@example.com"}
+	require.NoError(t, user.SetPassword("password123"))
+	require.NoError(t, s.users.Create(user))
+
+	token, err := s.tokens.GenerateAccessToken(user.ID.String(), time.Hour)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token), user
+}
+
+func requireCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	require.Error(t, err)
+	assert.Equal(t, code, status.Code(err), err.Error())
+}
+
+func TestServer_Authentication(t *testing.T) {
+	server := setupTestServer(t)
+	portfolios := pb.NewPortfolioServiceClient(server.conn)
+
+	_, err := portfolios.ListPortfolios(context.Background(), &pb.ListPortfoliosRequest{})
+	requireCode(t, err, codes.Unauthenticated)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer not-a-token")
+	_, err = portfolios.ListPortfolios(ctx, &pb.ListPortfoliosRequest{})
+	requireCode(t, err, codes.Unauthenticated)
+
+	ctx, user := server.signIn(t)
+	response, err := portfolios.ListPortfolios(ctx, &pb.ListPortfoliosRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, response.Portfolios)
+
+	// Disabling an account takes effect on the tokens already issued
+	disabledAt := time.Now()
+	user.DisabledAt = &disabledAt
+	require.NoError(t, server.users.Save(user))
+	_, err = portfolios.ListPortfolios(ctx, &pb.ListPortfoliosRequest{})
+	requireCode(t, err, codes.PermissionDenied)
+}
+
+func TestServer_PortfoliosAndTransactions(t *testing.T) {
+	server := setupTestServer(t)
+	portfolios := pb.NewPortfolioServiceClient(server.conn)
+	transactions := pb.NewTransactionServiceClient(server.conn)
+	ctx, _ := server.signIn(t)
+
+	portfolio, err := portfolios.CreatePortfolio(ctx, &pb.CreatePortfolioRequest{Name: "Brokerage", BaseCurrency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, "FIFO", portfolio.CostBasisMethod)
+	assert.Equal(t, int32(1), portfolio.Version)
+
+	_, err = portfolios.CreatePortfolio(ctx, &pb.CreatePortfolioRequest{Name: "Brokerage", BaseCurrency: "USD"})
+	requireCode(t, err, codes.AlreadyExists)
+
+	t.Run("updates need the current version", func(t *testing.T) {
+		updated, err := portfolios.UpdatePortfolio(ctx, &pb.UpdatePortfolioRequest{Id: portfolio.Id, Version: 1, Name: "Taxable"})
+		require.NoError(t, err)
+		assert.Equal(t, "Taxable", updated.Name)
+		assert.Equal(t, int32(2), updated.Version)
+
+		_, err = portfolios.UpdatePortfolio(ctx, &pb.UpdatePortfolioRequest{Id: portfolio.Id, Version: 1, Name: "Stale"})
+		requireCode(t, err, codes.Aborted)
+
+		_, err = portfolios.UpdatePortfolio(ctx, &pb.UpdatePortfolioRequest{Id: portfolio.Id, Name: "Unversioned"})
+		requireCode(t, err, codes.FailedPrecondition)
+	})
+
+	t.Run("transactions update holdings", func(t *testing.T) {
+		created, err := transactions.CreateTransaction(ctx, &pb.CreateTransactionRequest{
+			PortfolioId: portfolio.Id,
+			Type:        "BUY",
+			Symbol:      "AAPL",
+			Date:        timestamppb.New(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)),
+			Quantity:    "10",
+			Price:       "100.25",
+			Currency:    "USD",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "100.25", created.Price)
+
+		holdings, err := portfolios.ListHoldings(ctx, &pb.ListHoldingsRequest{PortfolioId: portfolio.Id})
+		require.NoError(t, err)
+		require.Len(t, holdings.Holdings, 1)
+		assert.Equal(t, "10", holdings.Holdings[0].Quantity)
+
+		updated, err := transactions.UpdateTransaction(ctx, &pb.UpdateTransactionRequest{
+			Id:       created.Id,
+			Version:  created.Version,
+			Type:     "BUY",
+			Symbol:   "AAPL",
+			Date:     created.Date,
+			Quantity: "12",
+			Price:    "100.25",
+			Currency: "USD",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, created.Version+1, updated.Version)
+
+		listed, err := transactions.ListTransactions(ctx, &pb.ListTransactionsRequest{PortfolioId: portfolio.Id, Symbol: "AAPL"})
+		require.NoError(t, err)
+		require.Len(t, listed.Transactions, 1)
+		assert.Equal(t, "12", listed.Transactions[0].Quantity)
+
+		_, err = transactions.DeleteTransaction(ctx, &pb.DeleteTransactionRequest{Id: created.Id})
+		require.NoError(t, err)
+		_, err = transactions.GetTransaction(ctx, &pb.GetTransactionRequest{Id: created.Id})
+		requireCode(t, err, codes.NotFound)
+	})
+
+	t.Run("invalid transactions", func(t *testing.T) {
+		_, err := transactions.CreateTransaction(ctx, &pb.CreateTransactionRequest{
+			PortfolioId: portfolio.Id,
+			Type:        "BUY",
+			Symbol:      "AAPL",
+			Date:        timestamppb.Now(),
+			Quantity:    "ten",
+		})
+		requireCode(t, err, codes.InvalidArgument)
+
+		_, err = transactions.CreateTransaction(ctx, &pb.CreateTransactionRequest{
+			PortfolioId: portfolio.Id,
+			Type:        "SELL",
+			Symbol:      "MSFT",
+			Date:        timestamppb.Now(),
+			Quantity:    "5",
+			Price:       "300",
+		})
+		requireCode(t, err, codes.FailedPrecondition)
+	})
+
+	t.Run("other users' portfolios are off limits", func(t *testing.T) {
+		otherCtx, _ := server.signIn(t)
+		_, err := portfolios.GetPortfolio(otherCtx, &pb.GetPortfolioRequest{Id: portfolio.Id})
+		requireCode(t, err, codes.PermissionDenied)
+
+		_, err = transactions.ListTransactions(otherCtx, &pb.ListTransactionsRequest{PortfolioId: portfolio.Id})
+		requireCode(t, err, codes.PermissionDenied)
+	})
+
+	_, err = portfolios.DeletePortfolio(ctx, &pb.DeletePortfolioRequest{Id: portfolio.Id})
+	require.NoError(t, err)
+	_, err = portfolios.GetPortfolio(ctx, &pb.GetPortfolioRequest{Id: portfolio.Id})
+	requireCode(t, err, codes.NotFound)
+}
+
+func TestServer_MarketData(t *testing.T) {
+	server := setupTestServer(t)
+	marketData := pb.NewMarketDataServiceClient(server.conn)
+	ctx, _ := server.signIn(t)
+
+	quote, err := marketData.GetQuote(ctx, &pb.GetQuoteRequest{Symbol: "AAPL"})
+	require.NoError(t, err)
+	assert.Equal(t, "120.5", quote.Price)
+
+	quotes, err := marketData.GetQuotes(ctx, &pb.GetQuotesRequest{Symbols: []string{"AAPL", "MSFT"}})
+	require.NoError(t, err)
+	assert.Len(t, quotes.Quotes, 2)
+
+	_, err = marketData.GetQuote(ctx, &pb.GetQuoteRequest{})
+	requireCode(t, err, codes.InvalidArgument)
+
+	// Performance is not registered without the analytics service
+	_, err = pb.NewPerformanceServiceClient(server.conn).GetPerformanceMetrics(ctx, &pb.GetPerformanceMetricsRequest{})
+	requireCode(t, err, codes.Unimplemented)
+}
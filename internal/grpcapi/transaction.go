@@ -0,0 +1,228 @@
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+	pb "github.com/lenon/portfolios/pkg/pb/portfolios/v1"
+)
+
+// transactionServer serves TransactionService from the transaction service, checking new
+// trades against employer stock blackout windows when a blackout service is set
+type transactionServer struct {
+	pb.UnimplementedTransactionServiceServer
+	transactionService services.TransactionService
+	blackoutService    services.BlackoutService
+}
+
+// ListTransactions lists the transactions of one of the caller's portfolios
+func (s *transactionServer) ListTransactions(ctx context.Context, req *pb.ListTransactionsRequest) (*pb.ListTransactionsResponse, error) {
+	var transactions []*models.Transaction
+	var err error
+	if req.GetSymbol() != "" {
+		transactions, err = s.transactionService.GetByPortfolioIDAndSymbol(ctx, req.GetPortfolioId(), req.GetSymbol(), userID(ctx))
+	} else {
+		transactions, err = s.transactionService.GetByPortfolioID(ctx, req.GetPortfolioId(), userID(ctx))
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &pb.ListTransactionsResponse{Transactions: make([]*pb.Transaction, 0, len(transactions))}
+	for _, transaction := range transactions {
+		response.Transactions = append(response.Transactions, toTransaction(transaction))
+	}
+	return response, nil
+}
+
+// GetTransaction returns one of the caller's transactions
+func (s *transactionServer) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.Transaction, error) {
+	transaction, err := s.transactionService.GetByID(ctx, req.GetId(), userID(ctx))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toTransaction(transaction), nil
+}
+
+// CreateTransaction records a transaction in one of the caller's portfolios
+func (s *transactionServer) CreateTransaction(ctx context.Context, req *pb.CreateTransactionRequest) (*pb.Transaction, error) {
+	fields, err := parseTransactionFields(req.GetDate(), req.GetQuantity(), req.GetPrice(), req.GetCommission())
+	if err != nil {
+		return nil, err
+	}
+	uid := userID(ctx)
+	transactionType := models.TransactionType(req.GetType())
+
+	// Check employer stock blackout windows before recording the trade
+	var blackout *services.BlackoutCheck
+	if s.blackoutService != nil {
+		blackout, err = s.blackoutService.CheckTransaction(ctx,
+			req.GetPortfolioId(),
+			uid,
+			transactionType,
+			req.GetSymbol(),
+			fields.date,
+			req.GetBlackoutOverrideReason(),
+		)
+		if err == models.ErrBlackoutPeriod {
+			return nil, status.Error(codes.FailedPrecondition, blackout.Warning()+"; provide blackout_override_reason to override")
+		}
+		if err != nil {
+			return nil, toStatus(err)
+		}
+	}
+
+	transaction, err := s.transactionService.Create(ctx,
+		req.GetPortfolioId(),
+		uid,
+		transactionType,
+		req.GetSymbol(),
+		fields.date,
+		fields.quantity,
+		fields.price,
+		fields.commission,
+		req.GetCurrency(),
+		req.GetNotes(),
+		"",
+	)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := toTransaction(transaction)
+
+	// Trades inside a blackout window are always added to the audit trail
+	if blackout != nil {
+		if err := s.blackoutService.RecordOverride(ctx, blackout, transaction, uid, req.GetBlackoutOverrideReason()); err != nil {
+			// Don't keep a restricted trade that isn't audited, even if the caller has gone
+			_ = s.transactionService.Delete(context.WithoutCancel(ctx), transaction.ID.String(), uid)
+			return nil, status.Error(codes.Internal, "failed to record blackout override")
+		}
+		response.Warnings = append(response.Warnings, blackout.Warning())
+	}
+
+	return response, nil
+}
+
+// UpdateTransaction replaces one of the caller's transactions
+func (s *transactionServer) UpdateTransaction(ctx context.Context, req *pb.UpdateTransactionRequest) (*pb.Transaction, error) {
+	if req.GetVersion() < 1 {
+		return nil, toStatus(models.ErrVersionRequired)
+	}
+	fields, err := parseTransactionFields(req.GetDate(), req.GetQuantity(), req.GetPrice(), req.GetCommission())
+	if err != nil {
+		return nil, err
+	}
+
+	// Messages don't carry income categories, so an income transaction keeps the one it has
+	transactionType := models.TransactionType(req.GetType())
+	var category string
+	if slices.Contains(models.IncomeTransactionTypes, transactionType) {
+		existing, err := s.transactionService.GetByID(ctx, req.GetId(), userID(ctx))
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		category = existing.Category
+	}
+
+	transaction, err := s.transactionService.Update(ctx,
+		req.GetId(),
+		userID(ctx),
+		int(req.GetVersion()),
+		transactionType,
+		req.GetSymbol(),
+		fields.date,
+		fields.quantity,
+		fields.price,
+		fields.commission,
+		req.GetCurrency(),
+		req.GetNotes(),
+		category,
+	)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return toTransaction(transaction), nil
+}
+
+// DeleteTransaction deletes one of the caller's transactions
+func (s *transactionServer) DeleteTransaction(ctx context.Context, req *pb.DeleteTransactionRequest) (*pb.DeleteTransactionResponse, error) {
+	if err := s.transactionService.Delete(ctx, req.GetId(), userID(ctx)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.DeleteTransactionResponse{}, nil
+}
+
+// transactionFields are the parsed non-string fields of a create or update request
+type transactionFields struct {
+	date                        time.Time
+	quantity, price, commission decimal.Decimal
+}
+
+// parseTransactionFields parses the date and decimal fields of a create or update request.
+// The price and commission are zero when empty; the date and quantity are required.
+func parseTransactionFields(date *timestamppb.Timestamp, quantity, price, commission string) (*transactionFields, error) {
+	if date == nil {
+		return nil, status.Error(codes.InvalidArgument, "date is required")
+	}
+	fields := &transactionFields{date: date.AsTime()}
+
+	var err error
+	if fields.quantity, err = parseDecimal("quantity", quantity, false); err != nil {
+		return nil, err
+	}
+	if fields.price, err = parseDecimal("price", price, true); err != nil {
+		return nil, err
+	}
+	if fields.commission, err = parseDecimal("commission", commission, true); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// parseDecimal parses a decimal string field, returning zero for an empty optional field
+func parseDecimal(field, value string, optional bool) (decimal.Decimal, error) {
+	if value == "" {
+		if optional {
+			return decimal.Zero, nil
+		}
+		return decimal.Zero, status.Error(codes.InvalidArgument, field+" is required")
+	}
+	d, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, status.Error(codes.InvalidArgument, fmt.Sprintf("%s must be a decimal number, got %q", field, value))
+	}
+	return d, nil
+}
+
+// toTransaction converts a Transaction model to its protobuf message
+func toTransaction(transaction *models.Transaction) *pb.Transaction {
+	response := &pb.Transaction{
+		Id:          transaction.ID.String(),
+		PortfolioId: transaction.PortfolioID.String(),
+		Type:        string(transaction.Type),
+		Symbol:      transaction.Symbol,
+		AssetType:   string(transaction.AssetType),
+		Date:        timestamppb.New(transaction.Date),
+		Quantity:    transaction.Quantity.String(),
+		Commission:  transaction.Commission.String(),
+		Currency:    transaction.Currency,
+		Notes:       transaction.Notes,
+		Version:     int32(transaction.Version),
+		CreatedAt:   timestamppb.New(transaction.CreatedAt),
+		UpdatedAt:   timestamppb.New(transaction.UpdatedAt),
+	}
+	if transaction.Price != nil {
+		response.Price = transaction.Price.String()
+	}
+	return response
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// AdminHandler handles admin provisioning HTTP requests. Every resource is addressed by the
+// external ID the provisioning tool assigned, and PUT requests are idempotent upserts that
+// answer 201 when they create the resource and 200 otherwise.
+type AdminHandler struct {
+	provisioningService services.AdminProvisioningService
+}
+
+// NewAdminHandler creates a new AdminHandler instance
+func NewAdminHandler(provisioningService services.AdminProvisioningService) *AdminHandler {
+	return &AdminHandler{
+		provisioningService: provisioningService,
+	}
+}
+
+// PutOrganization handles creating or updating an organization
+// PUT /api/admin/v1/orgs/:org_id
+func (h *AdminHandler) PutOrganization(c *gin.Context) {
+	var req dto.UpsertOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	organization, created, err := h.provisioningService.UpsertOrganization(c.Param("org_id"), req.Name)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(upsertStatus(created), dto.ToOrganizationResponse(organization))
+}
+
+// GetOrganization handles retrieving an organization
+// GET /api/admin/v1/orgs/:org_id
+func (h *AdminHandler) GetOrganization(c *gin.Context) {
+	organization, err := h.provisioningService.GetOrganization(c.Param("org_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToOrganizationResponse(organization))
+}
+
+// PutQuotas handles replacing an organization's quotas
+// PUT /api/admin/v1/orgs/:org_id/quotas
+func (h *AdminHandler) PutQuotas(c *gin.Context) {
+	var req dto.SetOrganizationQuotasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	organization, err := h.provisioningService.SetQuotas(c.Param("org_id"), services.OrganizationQuotas{
+		MaxUsers:             *req.MaxUsers,
+		MaxPortfoliosPerUser: *req.MaxPortfoliosPerUser,
+		MaxAPIKeys:           *req.MaxAPIKeys,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToOrganizationResponse(organization))
+}
+
+// PutUser handles creating or updating an organization member. New members are emailed an
+// invite to choose their password.
+// PUT /api/admin/v1/orgs/:org_id/users/:user_id
+func (h *AdminHandler) PutUser(c *gin.Context) {
+	var req dto.UpsertOrganizationUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	provisioned, err := h.provisioningService.UpsertUser(c.Param("org_id"), c.Param("user_id"), req.Email)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dto.ToOrganizationUserResponse(provisioned.User)
+	response.InviteSent = provisioned.InviteSent
+	response.InviteExpiresAt = provisioned.InviteExpiresAt
+
+	c.JSON(upsertStatus(provisioned.Created), response)
+}
+
+// GetUser handles retrieving an organization member
+// GET /api/admin/v1/orgs/:org_id/users/:user_id
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	user, err := h.provisioningService.GetUser(c.Param("org_id"), c.Param("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToOrganizationUserResponse(user))
+}
+
+// PutAPIKey handles issuing or updating an API key. The plaintext key is only returned by the
+// request that issues it.
+// PUT /api/admin/v1/orgs/:org_id/api-keys/:key_id
+func (h *AdminHandler) PutAPIKey(c *gin.Context) {
+	var req dto.UpsertAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	issued, err := h.provisioningService.UpsertAPIKey(c.Param("org_id"), c.Param("key_id"), req.UserID, req.Name)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dto.ToAPIKeyResponse(issued.Key)
+	response.Key = issued.Secret
+
+	c.JSON(upsertStatus(issued.Created), response)
+}
+
+// GetAPIKey handles retrieving an API key's metadata
+// GET /api/admin/v1/orgs/:org_id/api-keys/:key_id
+func (h *AdminHandler) GetAPIKey(c *gin.Context) {
+	key, err := h.provisioningService.GetAPIKey(c.Param("org_id"), c.Param("key_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToAPIKeyResponse(key))
+}
+
+// DeleteAPIKey handles revoking an API key. Revoking an unknown or already revoked key succeeds.
+// DELETE /api/admin/v1/orgs/:org_id/api-keys/:key_id
+func (h *AdminHandler) DeleteAPIKey(c *gin.Context) {
+	if err := h.provisioningService.RevokeAPIKey(c.Param("org_id"), c.Param("key_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// upsertStatus returns the status code for a PUT that did or did not create its resource
+func upsertStatus(created bool) int {
+	if created {
+		return http.StatusCreated
+	}
+	return http.StatusOK
+}
+
+// handleError maps provisioning errors to HTTP responses
+func (h *AdminHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process provisioning request"))
+}
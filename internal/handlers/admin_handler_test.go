@@ -0,0 +1,295 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockAdminProvisioningService is a mock implementation of AdminProvisioningService
+type MockAdminProvisioningService struct {
+	mock.Mock
+}
+
+func (m *MockAdminProvisioningService) UpsertOrganization(externalID, name string) (*models.Organization, bool, error) {
+	args := m.Called(externalID, name)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*models.Organization), args.Bool(1), args.Error(2)
+}
+
+func (m *MockAdminProvisioningService) GetOrganization(externalID string) (*models.Organization, error) {
+	args := m.Called(externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockAdminProvisioningService) SetQuotas(externalID string, quotas services.OrganizationQuotas) (*models.Organization, error) {
+	args := m.Called(externalID, quotas)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockAdminProvisioningService) UpsertUser(organizationExternalID, userExternalID, email string) (*services.ProvisionedUser, error) {
+	args := m.Called(organizationExternalID, userExternalID, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ProvisionedUser), args.Error(1)
+}
+
+func (m *MockAdminProvisioningService) GetUser(organizationExternalID, userExternalID string) (*models.User, error) {
+	args := m.Called(organizationExternalID, userExternalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockAdminProvisioningService) UpsertAPIKey(organizationExternalID, keyExternalID, userExternalID, name string) (*services.IssuedAPIKey, error) {
+	args := m.Called(organizationExternalID, keyExternalID, userExternalID, name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.IssuedAPIKey), args.Error(1)
+}
+
+func (m *MockAdminProvisioningService) GetAPIKey(organizationExternalID, keyExternalID string) (*models.APIKey, error) {
+	args := m.Called(organizationExternalID, keyExternalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAdminProvisioningService) RevokeAPIKey(organizationExternalID, keyExternalID string) error {
+	args := m.Called(organizationExternalID, keyExternalID)
+	return args.Error(0)
+}
+
+// serveAdmin runs an admin handler with the given path parameters and JSON body
+func serveAdmin(handler gin.HandlerFunc, method string, params gin.Params, body interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = params
+
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	c.Request = httptest.NewRequest(method, "/", bytes.NewReader(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestAdminHandler_PutOrganization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	params := gin.Params{{Key: "org_id", Value: "acme"}}
+
+	for _, created := range []bool{true, false} {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		mockService.On("UpsertOrganization", "acme", "Acme").
+			Return(&models.Organization{ID: uuid.New(), ExternalID: "acme", Name: "Acme"}, created, nil)
+
+		w := serveAdmin(handler.PutOrganization, "PUT", params, dto.UpsertOrganizationRequest{Name: "Acme"})
+
+		if created {
+			assert.Equal(t, http.StatusCreated, w.Code)
+		} else {
+			assert.Equal(t, http.StatusOK, w.Code)
+		}
+		var response dto.OrganizationResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "acme", response.ExternalID)
+		mockService.AssertExpectations(t)
+	}
+
+	t.Run("missing name", func(t *testing.T) {
+		handler := NewAdminHandler(new(MockAdminProvisioningService))
+		w := serveAdmin(handler.PutOrganization, "PUT", params, map[string]string{})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("invalid external ID", func(t *testing.T) {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		mockService.On("UpsertOrganization", "acme", "Acme").Return(nil, false, models.ErrInvalidExternalID)
+
+		w := serveAdmin(handler.PutOrganization, "PUT", params, dto.UpsertOrganizationRequest{Name: "Acme"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAdminHandler_PutQuotas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	params := gin.Params{{Key: "org_id", Value: "acme"}}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		quotas := services.OrganizationQuotas{MaxUsers: 10, MaxPortfoliosPerUser: 0, MaxAPIKeys: 2}
+		mockService.On("SetQuotas", "acme", quotas).
+			Return(&models.Organization{ExternalID: "acme", MaxUsers: 10, MaxAPIKeys: 2}, nil)
+
+		w := serveAdmin(handler.PutQuotas, "PUT", params, map[string]int{
+			"max_users": 10, "max_portfolios_per_user": 0, "max_api_keys": 2,
+		})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("every quota is required", func(t *testing.T) {
+		handler := NewAdminHandler(new(MockAdminProvisioningService))
+		w := serveAdmin(handler.PutQuotas, "PUT", params, map[string]int{"max_users": 10})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("organization not found", func(t *testing.T) {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		mockService.On("SetQuotas", "acme", services.OrganizationQuotas{}).Return(nil, models.ErrOrganizationNotFound)
+
+		w := serveAdmin(handler.PutQuotas, "PUT", params, map[string]int{
+			"max_users": 0, "max_portfolios_per_user": 0, "max_api_keys": 0,
+		})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestAdminHandler_PutUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	params := gin.Params{{Key: "org_id", Value: "acme"}, {Key: "user_id", Value: "jane"}}
+	externalID := "jane"
+
+	t.Run("created", func(t *testing.T) {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		expiresAt := time.Now().Add(24 * time.Hour)
+		mockService.On("UpsertUser", "acme", "jane", "jane@acme.example").Return(&services.ProvisionedUser{
+			User:            &models.User{ID: uuid.New(), Email: "jane@acme.example", ExternalID: &externalID},
+			Created:         true,
+			InviteSent:      true,
+			InviteExpiresAt: &expiresAt,
+		}, nil)
+
+		w := serveAdmin(handler.PutUser, "PUT", params, dto.UpsertOrganizationUserRequest{Email: "jane@acme.example"})
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response dto.OrganizationUserResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "jane", response.ExternalID)
+		assert.True(t, response.InviteSent)
+		assert.NotNil(t, response.InviteExpiresAt)
+	})
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"quota exceeded", models.ErrUserQuotaExceeded, http.StatusForbidden},
+		{"email taken", models.ErrEmailAlreadyExists, http.StatusConflict},
+		{"organization not found", models.ErrOrganizationNotFound, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdminProvisioningService)
+			handler := NewAdminHandler(mockService)
+			mockService.On("UpsertUser", "acme", "jane", "jane@acme.example").Return(nil, tt.err)
+
+			w := serveAdmin(handler.PutUser, "PUT", params, dto.UpsertOrganizationUserRequest{Email: "jane@acme.example"})
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+
+	t.Run("invalid email", func(t *testing.T) {
+		handler := NewAdminHandler(new(MockAdminProvisioningService))
+		w := serveAdmin(handler.PutUser, "PUT", params, dto.UpsertOrganizationUserRequest{Email: "not-an-email"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestAdminHandler_APIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	params := gin.Params{{Key: "org_id", Value: "acme"}, {Key: "key_id", Value: "ci"}}
+	externalID := "jane"
+	key := &models.APIKey{
+		ID:         uuid.New(),
+		ExternalID: "ci",
+		Name:       "CI",
+		Prefix:     "pfk_abcdefgh",
+		User:       &models.User{ExternalID: &externalID},
+	}
+
+	t.Run("issue returns the secret once", func(t *testing.T) {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		mockService.On("UpsertAPIKey", "acme", "ci", "jane", "CI").
+			Return(&services.IssuedAPIKey{Key: key, Created: true, Secret: "pfk_secret"}, nil)
+		mockService.On("GetAPIKey", "acme", "ci").Return(key, nil)
+
+		w := serveAdmin(handler.PutAPIKey, "PUT", params, dto.UpsertAPIKeyRequest{UserID: "jane", Name: "CI"})
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var issued dto.APIKeyResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &issued))
+		assert.Equal(t, "pfk_secret", issued.Key)
+		assert.Equal(t, "jane", issued.UserID)
+
+		w = serveAdmin(handler.GetAPIKey, "GET", params, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var fetched dto.APIKeyResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+		assert.Empty(t, fetched.Key)
+		assert.True(t, fetched.Active)
+	})
+
+	t.Run("owner change", func(t *testing.T) {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		mockService.On("UpsertAPIKey", "acme", "ci", "john", "").Return(nil, models.ErrAPIKeyOwnerChanged)
+
+		w := serveAdmin(handler.PutAPIKey, "PUT", params, dto.UpsertAPIKeyRequest{UserID: "john"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		mockService.On("RevokeAPIKey", "acme", "ci").Return(nil)
+
+		w := serveAdmin(handler.DeleteAPIKey, "DELETE", params, nil)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(MockAdminProvisioningService)
+		handler := NewAdminHandler(mockService)
+		mockService.On("GetAPIKey", "acme", "ci").Return(nil, models.ErrAPIKeyNotFound)
+
+		w := serveAdmin(handler.GetAPIKey, "GET", params, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
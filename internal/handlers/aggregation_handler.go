@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// AggregationHandler handles HTTP requests for views across all of a user's portfolios
+type AggregationHandler struct {
+	aggregationService services.AggregationService
+}
+
+// NewAggregationHandler creates a new AggregationHandler instance
+func NewAggregationHandler(aggregationService services.AggregationService) *AggregationHandler {
+	return &AggregationHandler{
+		aggregationService: aggregationService,
+	}
+}
+
+// GetOverview aggregates the value, asset allocation, holdings and performance of all of the
+// user's portfolios. The currency defaults to the user's display currency and the performance
+// period to the last year.
+// GET /api/v1/overview
+func (h *AggregationHandler) GetOverview(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.OverviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	startDate := req.StartDate
+	endDate := req.EndDate
+	if startDate.IsZero() {
+		startDate = time.Now().AddDate(-1, 0, 0)
+	}
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	if !endDate.After(startDate) {
+		apierrors.Respond(c, apierrors.InvalidDateRange)
+		return
+	}
+
+	overview, err := h.aggregationService.GetOverview(c.Request.Context(), userID.(string), req.Currency, startDate, endDate)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to build overview"))
+		return
+	}
+
+	c.JSON(http.StatusOK, overview)
+}
@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
 	"github.com/lenon/portfolios/internal/models"
@@ -18,6 +21,18 @@ type AuthHandler struct {
 	passwordResetService services.PasswordResetService
 	userRepo             repository.UserRepository
 	accessTokenDuration  int // in seconds
+	sessionCookies       *SessionCookieConfig
+}
+
+// SessionCookieConfig configures cookie session mode, in which the auth endpoints keep the
+// access and refresh tokens in httpOnly cookies instead of returning them to browser scripts
+type SessionCookieConfig struct {
+	// Secure restricts the cookies to HTTPS
+	Secure bool
+	// RefreshTokenDuration and RememberMeRefreshDuration are how long the cookies of a new
+	// session last, matching its refresh token
+	RefreshTokenDuration      time.Duration
+	RememberMeRefreshDuration time.Duration
 }
 
 // NewAuthHandler creates a new AuthHandler instance
@@ -35,6 +50,21 @@ func NewAuthHandler(
 	}
 }
 
+// NewAuthHandlerWithSessionCookies creates an AuthHandler in cookie session mode: register,
+// login and refresh set httpOnly cookies holding the tokens, refresh rotates the refresh
+// token, and logout clears the cookies
+func NewAuthHandlerWithSessionCookies(
+	authService services.AuthService,
+	passwordResetService services.PasswordResetService,
+	userRepo repository.UserRepository,
+	accessTokenDuration int,
+	sessionCookies SessionCookieConfig,
+) *AuthHandler {
+	h := NewAuthHandler(authService, passwordResetService, userRepo, accessTokenDuration)
+	h.sessionCookies = &sessionCookies
+	return h
+}
+
 // Register handles user registration
 // POST /api/auth/register
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -42,38 +72,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Call auth service to register user
 	user, accessToken, refreshToken, err := h.authService.Register(req.Email, req.Password)
 	if err != nil {
-		// Check for duplicate email error
-		if strings.Contains(err.Error(), "already exists") {
-			c.JSON(http.StatusConflict, dto.ErrorResponse{
-				Error: "A user with this email already exists",
-				Code:  "EMAIL_ALREADY_EXISTS",
-			})
-			return
-		}
-
-		// Check for password validation error
-		if strings.Contains(err.Error(), "invalid password") {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: err.Error(),
-				Code:  "INVALID_PASSWORD",
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to register user",
-			Code:  "REGISTRATION_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.RegistrationFailed)
 		return
 	}
 
@@ -84,6 +90,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		RefreshToken: refreshToken,
 		ExpiresIn:    h.accessTokenDuration,
 	}
+	if h.sessionCookies != nil {
+		h.setSessionCookies(c, accessToken, refreshToken, time.Now().Add(h.sessionCookies.RefreshTokenDuration))
+		response.AccessToken, response.RefreshToken = "", ""
+	}
 
 	c.JSON(http.StatusCreated, response)
 }
@@ -95,20 +105,17 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Call auth service to login user
 	user, accessToken, refreshToken, err := h.authService.Login(req.Email, req.Password, req.RememberMe)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "Invalid email or password",
-			Code:  "INVALID_CREDENTIALS",
-		})
+		if respondAccountLocked(c, err) {
+			return
+		}
+		apierrors.Respond(c, apierrors.InvalidCredentials)
 		return
 	}
 
@@ -119,6 +126,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		RefreshToken: refreshToken,
 		ExpiresIn:    h.accessTokenDuration,
 	}
+	if h.sessionCookies != nil {
+		sessionDuration := h.sessionCookies.RefreshTokenDuration
+		if req.RememberMe {
+			sessionDuration = h.sessionCookies.RememberMeRefreshDuration
+		}
+		h.setSessionCookies(c, accessToken, refreshToken, time.Now().Add(sessionDuration))
+		response.AccessToken, response.RefreshToken = "", ""
+	}
 
 	c.JSON(http.StatusOK, response)
 }
@@ -126,24 +141,26 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // RefreshToken handles token refresh
 // POST /api/auth/refresh
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	if h.sessionCookies != nil {
+		h.rotateSessionCookies(c)
+		return
+	}
+
 	var req dto.RefreshRequest
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Call auth service to refresh token
 	accessToken, err := h.authService.RefreshAccessToken(req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "Invalid or expired refresh token",
-			Code:  "INVALID_REFRESH_TOKEN",
-		})
+		if respondAccountLocked(c, err) {
+			return
+		}
+		apierrors.Respond(c, apierrors.InvalidRefreshToken)
 		return
 	}
 
@@ -156,26 +173,50 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// rotateSessionCookies exchanges the refresh token cookie for new access and refresh token
+// cookies
+func (h *AuthHandler) rotateSessionCookies(c *gin.Context) {
+	refreshToken, err := c.Cookie(middleware.RefreshTokenCookieName)
+	if err != nil || refreshToken == "" {
+		apierrors.Respond(c, apierrors.MissingRefreshToken)
+		return
+	}
+
+	accessToken, newRefreshToken, expiresAt, err := h.authService.RotateRefreshToken(refreshToken)
+	if err != nil {
+		if respondAccountLocked(c, err) {
+			return
+		}
+		h.clearSessionCookies(c)
+		apierrors.Respond(c, apierrors.InvalidRefreshToken)
+		return
+	}
+
+	h.setSessionCookies(c, accessToken, newRefreshToken, expiresAt)
+	c.JSON(http.StatusOK, dto.RefreshResponse{
+		ExpiresIn: h.accessTokenDuration,
+	})
+}
+
 // Logout handles user logout
 // POST /api/auth/logout
 func (h *AuthHandler) Logout(c *gin.Context) {
+	if h.sessionCookies != nil {
+		h.endCookieSession(c)
+		return
+	}
+
 	var req dto.LogoutRequest
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Call auth service to logout user
 	if err := h.authService.Logout(req.RefreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to logout",
-			Code:  "LOGOUT_FAILED",
-		})
+		apierrors.Respond(c, apierrors.LogoutFailed)
 		return
 	}
 
@@ -184,26 +225,38 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	})
 }
 
+// endCookieSession revokes the refresh token cookie, if there is one, and clears the session
+// cookies
+func (h *AuthHandler) endCookieSession(c *gin.Context) {
+	refreshToken, _ := c.Cookie(middleware.RefreshTokenCookieName)
+	h.clearSessionCookies(c)
+
+	if refreshToken != "" {
+		if err := h.authService.Logout(refreshToken); err != nil {
+			apierrors.Respond(c, apierrors.LogoutFailed)
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Message: "Logged out successfully",
+	})
+}
+
 // GetCurrentUser returns the current authenticated user's profile
 // GET /api/auth/me
 func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID := middleware.GetUserID(c)
 	if userID == "" {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "NOT_AUTHENTICATED",
-		})
+		apierrors.Respond(c, apierrors.NotAuthenticated)
 		return
 	}
 
 	// Fetch user from repository
 	user, err := h.userRepo.FindByID(userID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "User not found",
-			Code:  "USER_NOT_FOUND",
-		})
+		apierrors.Respond(c, apierrors.UserNotFound)
 		return
 	}
 
@@ -220,10 +273,7 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
@@ -243,36 +293,13 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Call password reset service
 	if err := h.passwordResetService.ResetPassword(req.Token, req.NewPassword); err != nil {
-		// Check for specific error types
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "expired") || strings.Contains(err.Error(), "already used") {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: "Invalid or expired reset token",
-				Code:  "INVALID_RESET_TOKEN",
-			})
-			return
-		}
-
-		if strings.Contains(err.Error(), "invalid password") {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: err.Error(),
-				Code:  "INVALID_PASSWORD",
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to reset password",
-			Code:  "RESET_PASSWORD_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.ResetPasswordFailed)
 		return
 	}
 
@@ -281,11 +308,53 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	})
 }
 
+// setSessionCookies stores the tokens of a session in httpOnly cookies that last until
+// expiresAt. The refresh token cookie is only sent to the auth endpoints.
+func (h *AuthHandler) setSessionCookies(c *gin.Context, accessToken, refreshToken string, expiresAt time.Time) {
+	maxAge := int(time.Until(expiresAt).Seconds())
+	h.setSessionCookie(c, middleware.AccessTokenCookieName, accessToken, "/api", maxAge)
+	h.setSessionCookie(c, middleware.RefreshTokenCookieName, refreshToken, "/api/auth", maxAge)
+}
+
+// clearSessionCookies tells the browser to delete the session cookies
+func (h *AuthHandler) clearSessionCookies(c *gin.Context) {
+	h.setSessionCookie(c, middleware.AccessTokenCookieName, "", "/api", -1)
+	h.setSessionCookie(c, middleware.RefreshTokenCookieName, "", "/api/auth", -1)
+}
+
+// setSessionCookie sets an httpOnly, SameSite=Strict session cookie
+func (h *AuthHandler) setSessionCookie(c *gin.Context, name, value, path string, maxAge int) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		Secure:   h.sessionCookies.Secure,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// respondAccountLocked writes a 403 response if err says the user's account is disabled or
+// awaiting a forced password reset, and reports whether it did
+func respondAccountLocked(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, models.ErrUserDisabled):
+		apierrors.Respond(c, apierrors.AccountDisabled)
+	case errors.Is(err, models.ErrPasswordResetRequired):
+		apierrors.Respond(c, apierrors.PasswordResetRequired)
+	default:
+		return false
+	}
+	return true
+}
+
 // userToResponse converts a User model to UserResponse DTO
 func userToResponse(user *models.User) dto.UserResponse {
 	return dto.UserResponse{
 		ID:          user.ID,
 		Email:       user.Email,
+		Role:        user.Role,
 		CreatedAt:   user.CreatedAt,
 		LastLoginAt: user.LastLoginAt,
 	}
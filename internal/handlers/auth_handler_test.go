@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,8 +12,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
 )
 
 // Mock services for testing
@@ -20,6 +23,7 @@ type mockAuthService struct {
 	registerFunc       func(email, password string) (*models.User, string, string, error)
 	loginFunc          func(email, password string, rememberMe bool) (*models.User, string, string, error)
 	refreshAccessToken func(refreshToken string) (string, error)
+	rotateRefreshToken func(refreshToken string) (string, string, time.Time, error)
 	logoutFunc         func(refreshToken string) error
 }
 
@@ -44,6 +48,13 @@ func (m *mockAuthService) RefreshAccessToken(refreshToken string) (string, error
 	return "", errors.New("not implemented")
 }
 
+func (m *mockAuthService) RotateRefreshToken(refreshToken string) (string, string, time.Time, error) {
+	if m.rotateRefreshToken != nil {
+		return m.rotateRefreshToken(refreshToken)
+	}
+	return "", "", time.Time{}, errors.New("not implemented")
+}
+
 func (m *mockAuthService) Logout(refreshToken string) error {
 	if m.logoutFunc != nil {
 		return m.logoutFunc(refreshToken)
@@ -101,6 +112,18 @@ func (m *mockUserRepository) UpdatePassword(id string, passwordHash string) erro
 	return nil
 }
 
+func (m *mockUserRepository) List(filter repository.UserFilter) ([]*models.User, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *mockUserRepository) Save(user *models.User) error {
+	return nil
+}
+
+func (m *mockUserRepository) Delete(id string) error {
+	return nil
+}
+
 // Test 1: Successful user registration
 func TestRegisterSuccess(t *testing.T) {
 	gin.SetMode(gin.TestMode)
@@ -164,7 +187,7 @@ func TestRegisterDuplicateEmail(t *testing.T) {
 	// Setup mock services
 	mockAuth := &mockAuthService{
 		registerFunc: func(email, password string) (*models.User, string, string, error) {
-			return nil, "", "", errors.New("user with email test@example.com already exists")
+			return nil, "", "", fmt.Errorf("%w: test@example.com", models.ErrEmailAlreadyExists)
 		},
 	}
 	mockPasswordReset := &mockPasswordResetService{}
@@ -686,7 +709,7 @@ func TestResetPasswordInvalidToken(t *testing.T) {
 	mockAuth := &mockAuthService{}
 	mockPasswordReset := &mockPasswordResetService{
 		resetPasswordFunc: func(token, newPassword string) error {
-			return errors.New("token not found")
+			return models.ErrInvalidResetToken
 		},
 	}
 	mockUserRepo := &mockUserRepository{}
@@ -732,8 +755,7 @@ func TestResetPasswordInvalidPassword(t *testing.T) {
 	mockAuth := &mockAuthService{}
 	mockPasswordReset := &mockPasswordResetService{
 		resetPasswordFunc: func(token, newPassword string) error {
-			// Service returns error with "invalid password" in message
-			return errors.New("invalid password: must contain uppercase and numbers")
+			return fmt.Errorf("%w: %w", models.ErrInvalidPassword, errors.New("must contain uppercase and numbers"))
 		},
 	}
 	mockUserRepo := &mockUserRepository{}
@@ -771,3 +793,156 @@ func TestResetPasswordInvalidPassword(t *testing.T) {
 		t.Errorf("Expected error code 'INVALID_PASSWORD', got '%s'", response.Code)
 	}
 }
+
+func newCookieSessionAuthHandler(mockAuth *mockAuthService) *AuthHandler {
+	return NewAuthHandlerWithSessionCookies(mockAuth, &mockPasswordResetService{}, &mockUserRepository{}, 3600, SessionCookieConfig{
+		Secure:                    true,
+		RefreshTokenDuration:      7 * 24 * time.Hour,
+		RememberMeRefreshDuration: 30 * 24 * time.Hour,
+	})
+}
+
+func responseCookies(w *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range w.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+// Test 15: Login in cookie session mode sets the tokens as cookies
+func TestLoginSessionCookies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockAuth := &mockAuthService{
+		loginFunc: func(email, password string, rememberMe bool) (*models.User, string, string, error) {
+			return &models.User{ID: uuid.New(), Email: email}, "access_token_456", "refresh_token_456", nil
+		},
+	}
+	handler := newCookieSessionAuthHandler(mockAuth)
+
+	body, _ := json.Marshal(dto.LoginRequest{Email: "test@example.com", Password: "SecurePass123", RememberMe: true})
+	req, _ := http.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.Login(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response dto.AuthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.AccessToken != "" || response.RefreshToken != "" {
+		t.Error("Expected no tokens in the response body")
+	}
+
+	cookies := responseCookies(w)
+	access, refresh := cookies["access_token"], cookies["refresh_token"]
+	if access == nil || refresh == nil {
+		t.Fatalf("Expected access and refresh token cookies, got %v", cookies)
+	}
+	if access.Value != "access_token_456" || refresh.Value != "refresh_token_456" {
+		t.Errorf("Unexpected cookie values %q and %q", access.Value, refresh.Value)
+	}
+	if !access.HttpOnly || !access.Secure || access.SameSite != http.SameSiteStrictMode {
+		t.Error("Expected an httpOnly, Secure, SameSite=Strict access token cookie")
+	}
+	if refresh.Path != "/api/auth" {
+		t.Errorf("Expected the refresh token cookie on /api/auth, got %q", refresh.Path)
+	}
+	// Remember me keeps the session for the longer refresh duration
+	if refresh.MaxAge < int((29 * 24 * time.Hour).Seconds()) {
+		t.Errorf("Expected a remember me cookie lifetime, got %d seconds", refresh.MaxAge)
+	}
+}
+
+// Test 16: Refresh in cookie session mode rotates the cookies
+func TestRefreshTokenSessionCookies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	expiresAt := time.Now().Add(time.Hour)
+	mockAuth := &mockAuthService{
+		rotateRefreshToken: func(refreshToken string) (string, string, time.Time, error) {
+			if refreshToken != "old_refresh_token" {
+				return "", "", time.Time{}, errors.New("refresh token not found")
+			}
+			return "new_access_token", "new_refresh_token", expiresAt, nil
+		},
+	}
+	handler := newCookieSessionAuthHandler(mockAuth)
+
+	req, _ := http.NewRequest("POST", "/api/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "old_refresh_token"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.RefreshToken(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	cookies := responseCookies(w)
+	if cookies["access_token"] == nil || cookies["access_token"].Value != "new_access_token" {
+		t.Error("Expected a new access token cookie")
+	}
+	if cookies["refresh_token"] == nil || cookies["refresh_token"].Value != "new_refresh_token" {
+		t.Error("Expected a new refresh token cookie")
+	}
+
+	// Without the cookie there is nothing to refresh
+	req, _ = http.NewRequest("POST", "/api/auth/refresh", nil)
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.RefreshToken(c)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+// Test 17: Logout in cookie session mode revokes the refresh token and clears the cookies
+func TestLogoutSessionCookies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	revoked := ""
+	mockAuth := &mockAuthService{
+		logoutFunc: func(refreshToken string) error {
+			revoked = refreshToken
+			return nil
+		},
+	}
+	handler := newCookieSessionAuthHandler(mockAuth)
+
+	req, _ := http.NewRequest("POST", "/api/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "refresh_token_456"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	handler.Logout(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if revoked != "refresh_token_456" {
+		t.Errorf("Expected the refresh token to be revoked, got %q", revoked)
+	}
+	for _, name := range []string{"access_token", "refresh_token"} {
+		cookie := responseCookies(w)[name]
+		if cookie == nil || cookie.MaxAge >= 0 {
+			t.Errorf("Expected the %s cookie to be cleared", name)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// BlackoutHandler handles employer stock policy and blackout window HTTP requests
+type BlackoutHandler struct {
+	blackoutService services.BlackoutService
+}
+
+// NewBlackoutHandler creates a new BlackoutHandler instance
+func NewBlackoutHandler(blackoutService services.BlackoutService) *BlackoutHandler {
+	return &BlackoutHandler{
+		blackoutService: blackoutService,
+	}
+}
+
+// GetPolicy handles retrieving the employer stock policy for a portfolio
+// GET /api/v1/portfolios/:id/employer-stock
+func (h *BlackoutHandler) GetPolicy(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	policy, err := h.blackoutService.GetPolicy(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToEmployerStockPolicyResponse(policy))
+}
+
+// SetPolicy handles flagging a portfolio as holding employer stock
+// PUT /api/v1/portfolios/:id/employer-stock
+func (h *BlackoutHandler) SetPolicy(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.SetEmployerStockPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	enforcement := req.Enforcement
+	if enforcement == "" {
+		enforcement = models.BlackoutEnforcementWarn
+	}
+
+	policy, err := h.blackoutService.SetPolicy(c.Request.Context(), portfolioID, userID.(string), req.Symbol, enforcement)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToEmployerStockPolicyResponse(policy))
+}
+
+// DeletePolicy handles removing the employer stock flag from a portfolio
+// DELETE /api/v1/portfolios/:id/employer-stock
+func (h *BlackoutHandler) DeletePolicy(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.blackoutService.DeletePolicy(c.Request.Context(), portfolioID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateWindow handles adding a trading blackout window
+// POST /api/v1/portfolios/:id/blackout-windows
+func (h *BlackoutHandler) CreateWindow(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.CreateBlackoutWindowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	window, err := h.blackoutService.CreateWindow(c.Request.Context(), portfolioID, userID.(string), &models.BlackoutWindow{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToBlackoutWindowResponse(window))
+}
+
+// GetWindows handles retrieving all blackout windows for a portfolio
+// GET /api/v1/portfolios/:id/blackout-windows
+func (h *BlackoutHandler) GetWindows(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	windows, err := h.blackoutService.GetWindows(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.BlackoutWindowResponse, len(windows))
+	for i, window := range windows {
+		response[i] = dto.ToBlackoutWindowResponse(window)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteWindow handles deleting a blackout window
+// DELETE /api/v1/portfolios/:id/blackout-windows/:window_id
+func (h *BlackoutHandler) DeleteWindow(c *gin.Context) {
+	portfolioID := c.Param("id")
+	windowID := c.Param("window_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.blackoutService.DeleteWindow(c.Request.Context(), windowID, portfolioID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetOverrides handles retrieving the blackout override audit trail
+// GET /api/v1/portfolios/:id/blackout-overrides
+func (h *BlackoutHandler) GetOverrides(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	overrides, err := h.blackoutService.GetOverrides(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.BlackoutOverrideResponse, len(overrides))
+	for i, override := range overrides {
+		response[i] = dto.ToBlackoutOverrideResponse(override)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *BlackoutHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process blackout request"))
+}
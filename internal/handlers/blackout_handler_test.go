@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockBlackoutService is a mock implementation of BlackoutService
+type MockBlackoutService struct {
+	mock.Mock
+}
+
+func (m *MockBlackoutService) GetPolicy(ctx context.Context, portfolioID, userID string) (*models.EmployerStockPolicy, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmployerStockPolicy), args.Error(1)
+}
+
+func (m *MockBlackoutService) SetPolicy(ctx context.Context, portfolioID, userID, symbol string, enforcement models.BlackoutEnforcement) (*models.EmployerStockPolicy, error) {
+	args := m.Called(portfolioID, userID, symbol, enforcement)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.EmployerStockPolicy), args.Error(1)
+}
+
+func (m *MockBlackoutService) DeletePolicy(ctx context.Context, portfolioID, userID string) error {
+	args := m.Called(portfolioID, userID)
+	return args.Error(0)
+}
+
+func (m *MockBlackoutService) CreateWindow(ctx context.Context, portfolioID, userID string, window *models.BlackoutWindow) (*models.BlackoutWindow, error) {
+	args := m.Called(portfolioID, userID, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BlackoutWindow), args.Error(1)
+}
+
+func (m *MockBlackoutService) GetWindows(ctx context.Context, portfolioID, userID string) ([]*models.BlackoutWindow, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BlackoutWindow), args.Error(1)
+}
+
+func (m *MockBlackoutService) DeleteWindow(ctx context.Context, id, portfolioID, userID string) error {
+	args := m.Called(id, portfolioID, userID)
+	return args.Error(0)
+}
+
+func (m *MockBlackoutService) CheckTransaction(ctx context.Context, portfolioID, userID string, transactionType models.TransactionType, symbol string, date time.Time, overrideReason string) (*services.BlackoutCheck, error) {
+	args := m.Called(portfolioID, userID, transactionType, symbol, date, overrideReason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.BlackoutCheck), args.Error(1)
+}
+
+func (m *MockBlackoutService) RecordOverride(ctx context.Context, check *services.BlackoutCheck, transaction *models.Transaction, userID, reason string) error {
+	args := m.Called(check, transaction, userID, reason)
+	return args.Error(0)
+}
+
+func (m *MockBlackoutService) GetOverrides(ctx context.Context, portfolioID, userID string) ([]*models.BlackoutOverride, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BlackoutOverride), args.Error(1)
+}
+
+func newTestBlackoutCheck(enforcement models.BlackoutEnforcement) *services.BlackoutCheck {
+	return &services.BlackoutCheck{
+		Policy: &models.EmployerStockPolicy{Symbol: "ACME", Enforcement: enforcement},
+		Window: &models.BlackoutWindow{
+			ID:        uuid.New(),
+			StartDate: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+func TestBlackoutHandler_SetPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockBlackoutService)
+	handler := NewBlackoutHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	// Enforcement defaults to WARN when omitted
+	mockService.On("SetPolicy", portfolioID, userID, "ACME", models.BlackoutEnforcementWarn).
+		Return(&models.EmployerStockPolicy{
+			ID:          uuid.New(),
+			PortfolioID: uuid.MustParse(portfolioID),
+			Symbol:      "ACME",
+			Enforcement: models.BlackoutEnforcementWarn,
+		}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"symbol": "ACME"})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("PUT", "/", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.SetPolicy(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBlackoutHandler_CreateWindow_Invalid(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockBlackoutService)
+	handler := NewBlackoutHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("CreateWindow", portfolioID, userID, mock.AnythingOfType("*models.BlackoutWindow")).
+		Return(nil, models.ErrInvalidBlackoutWindow)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"start_date": "2024-04-30T00:00:00Z",
+		"end_date":   "2024-03-15T00:00:00Z",
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.CreateWindow(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestBlackoutHandler_GetPolicy_NotFlagged(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockBlackoutService)
+	handler := NewBlackoutHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("GetPolicy", portfolioID, userID).Return(nil, models.ErrEmployerStockPolicyNotFound)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	handler.GetPolicy(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransactionHandler_Create_Blackout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	date := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	price := decimal.NewFromInt(50)
+
+	newRequest := func(overrideReason string) *http.Request {
+		body, _ := json.Marshal(map[string]interface{}{
+			"type":                     "SELL",
+			"symbol":                   "ACME",
+			"date":                     date,
+			"quantity":                 "10",
+			"price":                    price,
+			"currency":                 "USD",
+			"blackout_override_reason": overrideReason,
+		})
+		req := httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("blocked without override", func(t *testing.T) {
+		mockTransactions := new(MockTransactionService)
+		mockBlackout := new(MockBlackoutService)
+		handler := NewTransactionHandlerWithBlackout(mockTransactions, mockBlackout)
+
+		mockBlackout.On("CheckTransaction", portfolioID, userID, models.TransactionTypeSell, "ACME", mock.AnythingOfType("time.Time"), "").
+			Return(newTestBlackoutCheck(models.BlackoutEnforcementBlock), models.ErrBlackoutPeriod)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = newRequest("")
+
+		handler.Create(c)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "BLACKOUT_PERIOD")
+		mockTransactions.AssertNotCalled(t, "Create")
+		mockBlackout.AssertExpectations(t)
+	})
+
+	t.Run("override is recorded", func(t *testing.T) {
+		mockTransactions := new(MockTransactionService)
+		mockBlackout := new(MockBlackoutService)
+		handler := NewTransactionHandlerWithBlackout(mockTransactions, mockBlackout)
+
+		check := newTestBlackoutCheck(models.BlackoutEnforcementBlock)
+		transaction := &models.Transaction{
+			ID:          uuid.New(),
+			PortfolioID: uuid.MustParse(portfolioID),
+			Type:        models.TransactionTypeSell,
+			Symbol:      "ACME",
+			Date:        date,
+			Quantity:    decimal.NewFromInt(10),
+			Price:       &price,
+			Currency:    "USD",
+		}
+
+		mockBlackout.On("CheckTransaction", portfolioID, userID, models.TransactionTypeSell, "ACME", mock.AnythingOfType("time.Time"), "10b5-1 plan").
+			Return(check, nil)
+		mockTransactions.On("Create", portfolioID, userID, models.TransactionTypeSell, "ACME",
+			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything, mock.Anything, "USD", "", "").
+			Return(transaction, nil)
+		mockBlackout.On("RecordOverride", check, transaction, userID, "10b5-1 plan").Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = newRequest("10b5-1 plan")
+
+		handler.Create(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Len(t, response["warnings"], 1)
+		mockTransactions.AssertExpectations(t)
+		mockBlackout.AssertExpectations(t)
+	})
+}
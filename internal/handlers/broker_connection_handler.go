@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// BrokerConnectionHandler handles broker connection HTTP requests
+type BrokerConnectionHandler struct {
+	connectionService services.BrokerConnectionService
+}
+
+// NewBrokerConnectionHandler creates a new BrokerConnectionHandler instance
+func NewBrokerConnectionHandler(connectionService services.BrokerConnectionService) *BrokerConnectionHandler {
+	return &BrokerConnectionHandler{
+		connectionService: connectionService,
+	}
+}
+
+// Create handles connecting a portfolio to a brokerage account
+// POST /api/v1/portfolios/:id/connections
+func (h *BrokerConnectionHandler) Create(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.CreateBrokerConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	connection, err := h.connectionService.Connect(c.Request.Context(), portfolioID, userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToBrokerConnectionResponse(connection))
+}
+
+// List handles retrieving a portfolio's broker connections
+// GET /api/v1/portfolios/:id/connections
+func (h *BrokerConnectionHandler) List(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	connections, err := h.connectionService.List(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.BrokerConnectionResponse, len(connections))
+	for i, connection := range connections {
+		response[i] = dto.ToBrokerConnectionResponse(connection)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get handles retrieving a broker connection with the report of its last sync
+// GET /api/v1/portfolios/:id/connections/:connection_id
+func (h *BrokerConnectionHandler) Get(c *gin.Context) {
+	portfolioID := c.Param("id")
+	connectionID := c.Param("connection_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	connection, err := h.connectionService.Get(c.Request.Context(), connectionID, portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToBrokerConnectionResponse(connection))
+}
+
+// Delete handles disconnecting a brokerage account. Transactions it synced are kept.
+// DELETE /api/v1/portfolios/:id/connections/:connection_id
+func (h *BrokerConnectionHandler) Delete(c *gin.Context) {
+	portfolioID := c.Param("id")
+	connectionID := c.Param("connection_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.connectionService.Disconnect(c.Request.Context(), connectionID, portfolioID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Sync handles syncing a broker connection now. The body is optional.
+// POST /api/v1/portfolios/:id/connections/:connection_id/sync
+func (h *BrokerConnectionHandler) Sync(c *gin.Context) {
+	portfolioID := c.Param("id")
+	connectionID := c.Param("connection_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.SyncBrokerConnectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	report, err := h.connectionService.Sync(c.Request.Context(), connectionID, portfolioID, userID.(string), req.DryRun)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *BrokerConnectionHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process broker connection request"))
+}
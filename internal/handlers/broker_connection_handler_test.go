@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockBrokerConnectionService is a mock implementation of BrokerConnectionService
+type MockBrokerConnectionService struct {
+	mock.Mock
+}
+
+func (m *MockBrokerConnectionService) Connect(ctx context.Context, portfolioID, userID string, req dto.CreateBrokerConnectionRequest) (*models.BrokerConnection, error) {
+	args := m.Called(portfolioID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BrokerConnection), args.Error(1)
+}
+
+func (m *MockBrokerConnectionService) List(ctx context.Context, portfolioID, userID string) ([]*models.BrokerConnection, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.BrokerConnection), args.Error(1)
+}
+
+func (m *MockBrokerConnectionService) Get(ctx context.Context, id, portfolioID, userID string) (*models.BrokerConnection, error) {
+	args := m.Called(id, portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BrokerConnection), args.Error(1)
+}
+
+func (m *MockBrokerConnectionService) Disconnect(ctx context.Context, id, portfolioID, userID string) error {
+	args := m.Called(id, portfolioID, userID)
+	return args.Error(0)
+}
+
+func (m *MockBrokerConnectionService) Sync(ctx context.Context, id, portfolioID, userID string, dryRun bool) (*dto.BrokerSyncReport, error) {
+	args := m.Called(id, portfolioID, userID, dryRun)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.BrokerSyncReport), args.Error(1)
+}
+
+func (m *MockBrokerConnectionService) SyncAll(ctx context.Context) (int, int, error) {
+	args := m.Called()
+	return args.Int(0), args.Int(1), args.Error(2)
+}
+
+func TestBrokerConnectionHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		serviceErr error
+		wantStatus int
+	}{
+		{"connected", map[string]interface{}{"provider": "PLAID", "access_token": "access-sandbox-1", "account_id": "acc-1"}, nil, http.StatusCreated},
+		{"unknown provider", map[string]interface{}{"provider": "YODLEE", "access_token": "token", "account_id": "acc-1"}, nil, http.StatusBadRequest},
+		{"not configured", map[string]interface{}{"provider": "PLAID", "access_token": "token", "account_id": "acc-1"}, models.ErrBrokerSyncUnavailable, http.StatusServiceUnavailable},
+		{"rejected token", map[string]interface{}{"provider": "PLAID", "access_token": "token", "account_id": "acc-1"}, fmt.Errorf("%w: INVALID_ACCESS_TOKEN", models.ErrBrokerSyncFailed), http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBrokerConnectionService)
+			handler := NewBrokerConnectionHandler(mockService)
+
+			call := mockService.On("Connect", portfolioID, userID, mock.AnythingOfType("dto.CreateBrokerConnectionRequest"))
+			if tt.serviceErr != nil {
+				call.Return(nil, tt.serviceErr)
+			} else {
+				call.Return(&models.BrokerConnection{
+					ID:          uuid.New(),
+					PortfolioID: uuid.MustParse(portfolioID),
+					Provider:    models.BrokerProviderPlaid,
+					AccountID:   "acc-1",
+					Credentials: "sealed",
+					Status:      models.BrokerConnectionStatusActive,
+				}, nil)
+			}
+
+			body, _ := json.Marshal(tt.body)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+			c.Set(middleware.UserIDContextKey, userID)
+			c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Create(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			assert.NotContains(t, w.Body.String(), "sealed")
+		})
+	}
+}
+
+func TestBrokerConnectionHandler_Sync(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	connectionID := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		body       string
+		dryRun     bool
+		serviceErr error
+		wantStatus int
+	}{
+		{"without a body", "", false, nil, http.StatusOK},
+		{"dry run", `{"dry_run": true}`, true, nil, http.StatusOK},
+		{"unknown connection", "", false, models.ErrBrokerConnectionNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockBrokerConnectionService)
+			handler := NewBrokerConnectionHandler(mockService)
+
+			call := mockService.On("Sync", connectionID, portfolioID, userID, tt.dryRun)
+			if tt.serviceErr != nil {
+				call.Return(nil, tt.serviceErr)
+			} else {
+				call.Return(&dto.BrokerSyncReport{SyncedAt: time.Now().UTC(), DryRun: tt.dryRun, Matched: 4}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: portfolioID}, {Key: "connection_id", Value: connectionID}}
+			c.Set(middleware.UserIDContextKey, userID)
+			c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Sync(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var report dto.BrokerSyncReport
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+				assert.Equal(t, 4, report.Matched)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
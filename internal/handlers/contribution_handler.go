@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// ContributionHandler handles contribution analysis HTTP requests
+type ContributionHandler struct {
+	contributionService services.ContributionService
+}
+
+// NewContributionHandler creates a new ContributionHandler instance
+func NewContributionHandler(contributionService services.ContributionService) *ContributionHandler {
+	return &ContributionHandler{
+		contributionService: contributionService,
+	}
+}
+
+// Get handles splitting a portfolio's growth over a period into the money contributed to it
+// and the returns of its investments
+// GET /api/v1/portfolios/:id/performance/contributions
+func (h *ContributionHandler) Get(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.ContributionAnalysisRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Set default date range if not provided (last year)
+	startDate := req.StartDate
+	endDate := req.EndDate
+	if startDate.IsZero() {
+		startDate = time.Now().AddDate(-1, 0, 0)
+	}
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+
+	analysis, err := h.contributionService.Analyze(c.Request.Context(), portfolioID, userID.(string), startDate, endDate)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ContributionHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to analyze contributions"))
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockContributionService is a mock implementation of ContributionService
+type MockContributionService struct {
+	mock.Mock
+}
+
+func (m *MockContributionService) Analyze(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*services.ContributionAnalysis, error) {
+	args := m.Called(portfolioID, userID, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ContributionAnalysis), args.Error(1)
+}
+
+func newContributionContext(w *httptest.ResponseRecorder, portfolioID, userID, query string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	return c
+}
+
+func TestContributionHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockContributionService)
+	handler := NewContributionHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	sameDay := func(day time.Time) any {
+		return mock.MatchedBy(func(date time.Time) bool { return date.Format(time.DateOnly) == day.Format(time.DateOnly) })
+	}
+	mockService.On("Analyze", portfolioID, userID, sameDay(startDate), sameDay(endDate)).Return(&services.ContributionAnalysis{
+		PortfolioID:      portfolioID,
+		Source:           dto.ContributionSourceCashFlows,
+		NetContributions: decimal.NewFromInt(4000),
+		InvestmentGrowth: decimal.NewFromInt(1700),
+	}, nil)
+
+	w := httptest.NewRecorder()
+	handler.Get(newContributionContext(w, portfolioID, userID, "start_date=2024-01-01&end_date=2024-12-31"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.ContributionAnalysis
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.ContributionSourceCashFlows, response.Source)
+	assert.True(t, decimal.NewFromInt(4000).Equal(response.NetContributions))
+	assert.True(t, decimal.NewFromInt(1700).Equal(response.InvestmentGrowth))
+	mockService.AssertExpectations(t)
+}
+
+func TestContributionHandler_Get_InvalidDate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewContributionHandler(new(MockContributionService))
+
+	w := httptest.NewRecorder()
+	handler.Get(newContributionContext(w, uuid.New().String(), uuid.New().String(), "start_date=yesterday"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestContributionHandler_Get_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"reversed dates", models.ErrInvalidContributionPeriod, http.StatusBadRequest, "INVALID_PERIOD"},
+		{"no snapshots", models.ErrInsufficientPerformanceHistory, http.StatusUnprocessableEntity, "INSUFFICIENT_DATA"},
+		{"internal", assert.AnError, http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockContributionService)
+			handler := NewContributionHandler(mockService)
+			portfolioID := uuid.New().String()
+			userID := uuid.New().String()
+
+			mockService.On("Analyze", portfolioID, userID, mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			handler.Get(newContributionContext(w, portfolioID, userID, ""))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// DashboardHandler handles HTTP requests for the home screen summary
+type DashboardHandler struct {
+	dashboardService services.DashboardService
+}
+
+// NewDashboardHandler creates a new DashboardHandler instance
+func NewDashboardHandler(dashboardService services.DashboardService) *DashboardHandler {
+	return &DashboardHandler{
+		dashboardService: dashboardService,
+	}
+}
+
+// Get returns everything the home screen shows in one response: the net worth and today's
+// change, the portfolio cards, the top movers, the upcoming corporate actions and the unread
+// notifications. The currency defaults to the user's display currency.
+// GET /api/v1/dashboard
+func (h *DashboardHandler) Get(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.DashboardRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	dashboard, err := h.dashboardService.GetDashboard(c.Request.Context(), userID.(string), req.Currency)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to build dashboard"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
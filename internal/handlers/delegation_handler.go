@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// DelegationHandler handles requests for advisors' access to their clients' portfolios: the
+// requests advisors make, the consent clients give, and the audit trail of what advisors did
+type DelegationHandler struct {
+	delegationService services.DelegationService
+}
+
+// NewDelegationHandler creates a new DelegationHandler instance
+func NewDelegationHandler(delegationService services.DelegationService) *DelegationHandler {
+	return &DelegationHandler{
+		delegationService: delegationService,
+	}
+}
+
+// Request handles an advisor asking a client for access to their portfolios. The consent
+// token is returned so that it can be passed on if the email could not be delivered.
+// POST /api/v1/delegations
+func (h *DelegationHandler) Request(c *gin.Context) {
+	var req dto.CreateDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	issued, err := h.delegationService.RequestAccess(c.Request.Context(), middleware.GetUserID(c), req.ClientEmail, req.Access)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dto.ToDelegationResponse(issued.Delegation)
+	response.Token = issued.Token
+	response.EmailSent = &issued.EmailSent
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// List handles listing the current user's delegations, as advisor or client, and the
+// requests for access sent to them
+// GET /api/v1/delegations
+func (h *DelegationHandler) List(c *gin.Context) {
+	delegations, err := h.delegationService.List(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.DelegationResponse, len(delegations))
+	for i, delegation := range delegations {
+		response[i] = dto.ToDelegationResponse(delegation)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Consent handles a client consenting to a request for access with the chosen portfolios
+// POST /api/v1/delegations/consent
+func (h *DelegationHandler) Consent(c *gin.Context) {
+	var req dto.ConsentDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	delegation, err := h.delegationService.Consent(c.Request.Context(), middleware.GetUserID(c), req.Token, req.PortfolioIDs)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToDelegationResponse(delegation))
+}
+
+// SetPortfolios handles a client changing which portfolios they delegate
+// PUT /api/v1/delegations/:delegation_id/portfolios
+func (h *DelegationHandler) SetPortfolios(c *gin.Context) {
+	var req dto.SetDelegatedPortfoliosRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	delegation, err := h.delegationService.SetPortfolios(
+		c.Request.Context(), middleware.GetUserID(c), c.Param("delegation_id"), req.PortfolioIDs,
+	)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToDelegationResponse(delegation))
+}
+
+// Revoke handles ending a delegation or declining a request for access
+// DELETE /api/v1/delegations/:delegation_id
+func (h *DelegationHandler) Revoke(c *gin.Context) {
+	if err := h.delegationService.Revoke(c.Request.Context(), middleware.GetUserID(c), c.Param("delegation_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAuditEvents handles listing the latest requests made under a delegation
+// GET /api/v1/delegations/:delegation_id/audit
+func (h *DelegationHandler) ListAuditEvents(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultDelegationAuditLimit)))
+	if err != nil || limit < 0 {
+		limit = services.DefaultDelegationAuditLimit
+	}
+
+	events, err := h.delegationService.ListAuditEvents(
+		c.Request.Context(), middleware.GetUserID(c), c.Param("delegation_id"), limit,
+	)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.DelegationAuditEventResponse, len(events))
+	for i, event := range events {
+		response[i] = dto.ToDelegationAuditEventResponse(event)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleError maps delegation errors to HTTP responses
+func (h *DelegationHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process delegation request"))
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+// ListErrorCodes handles listing the codes error responses carry
+// GET /api/errors
+func ListErrorCodes(c *gin.Context) {
+	catalog := apierrors.Catalog()
+	response := dto.ErrorCatalogResponse{Errors: make([]dto.ErrorCodeResponse, 0, len(catalog))}
+	for _, e := range catalog {
+		response.Errors = append(response.Errors, dto.ErrorCodeResponse{
+			Code:    e.Code,
+			Status:  e.Status,
+			Message: e.Message,
+		})
+	}
+	c.JSON(http.StatusOK, response)
+}
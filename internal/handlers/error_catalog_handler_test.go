@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+func TestListErrorCodes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	ListErrorCodes(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.ErrorCatalogResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Errors, len(apierrors.Catalog()))
+	assert.Contains(t, response.Errors, dto.ErrorCodeResponse{
+		Code:    apierrors.PortfolioNotFound.Code,
+		Status:  apierrors.PortfolioNotFound.Status,
+		Message: apierrors.PortfolioNotFound.Message,
+	})
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/export"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// ExportHandler handles streaming exports of a user's data
+type ExportHandler struct {
+	exportService services.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler instance
+func NewExportHandler(exportService services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportTransactions handles downloading a portfolio's transactions as CSV, in the generic
+// import format, or as JSON Lines. The file is streamed a page of transactions at a time, for
+// as long as that takes: only the client disconnecting stops it, not the request timeout.
+// GET /api/v1/portfolios/:id/transactions/export
+func (h *ExportHandler) ExportTransactions(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.ExportTransactionsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	encoder, err := export.NewTransactionEncoder(req.Format, c.Writer)
+	if err != nil {
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage(err.Error()))
+		return
+	}
+
+	ctx, cancel := middleware.WithoutTimeout(c)
+	defer cancel()
+
+	stream := newExportStream(c, encoder.ContentType(),
+		fmt.Sprintf("transactions-%s.%s", portfolioID, encoder.FileExtension()))
+	err = h.exportService.ExportTransactions(ctx, portfolioID, userID.(string),
+		func(transactions []*models.Transaction) error {
+			stream.start()
+			for _, tx := range transactions {
+				if err := encoder.Encode(tx); err != nil {
+					return err
+				}
+			}
+			return encoder.Flush()
+		})
+	if err == nil {
+		stream.start()
+		err = encoder.Flush()
+	}
+	stream.finish(err)
+}
+
+// ExportAccount handles downloading everything stored about the user as JSON Lines, one
+// record per line, for data portability requests. Like transaction exports, it isn't bound by
+// the request timeout.
+// GET /api/v1/export
+func (h *ExportHandler) ExportAccount(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	ctx, cancel := middleware.WithoutTimeout(c)
+	defer cancel()
+
+	encoder := export.NewJSONLinesEncoder(c.Writer)
+	stream := newExportStream(c, export.JSONLinesContentType, "account-export.jsonl")
+	records := 0
+	err := h.exportService.ExportAccount(ctx, userID.(string),
+		func(record dto.AccountExportRecord) error {
+			stream.start()
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+			// Send the records on to the client every so often rather than buffering them all
+			records++
+			if records%exportFlushInterval == 0 {
+				return encoder.Flush()
+			}
+			return nil
+		})
+	if err == nil {
+		stream.start()
+		err = encoder.Flush()
+	}
+	stream.finish(err)
+}
+
+// exportFlushInterval is how many account export records are written between flushes
+const exportFlushInterval = 500
+
+// exportStream starts a streamed download once there is something to send, so an error
+// found before then, such as a missing portfolio, can still be reported as an error response
+type exportStream struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func newExportStream(c *gin.Context, contentType, filename string) *exportStream {
+	return &exportStream{c: c, contentType: contentType, filename: filename}
+}
+
+// start sends the response headers. Without a Content-Length the body is sent chunked.
+func (s *exportStream) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+
+	s.c.Header("Content-Type", s.contentType)
+	s.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, s.filename))
+	s.c.Header("Cache-Control", "no-store")
+	s.c.Header("X-Accel-Buffering", "no") // Stop nginx from buffering the download
+	// Large exports can take longer than the server's write timeout
+	_ = http.NewResponseController(s.c.Writer).SetWriteDeadline(time.Time{})
+	s.c.Status(http.StatusOK)
+}
+
+// finish reports an export's error. Once the download has started the status can no
+// longer change, so the error is only recorded and the download ends early.
+func (s *exportStream) finish(err error) {
+	if err == nil {
+		return
+	}
+	if !s.started {
+		apierrors.RespondError(s.c, err, apierrors.ExportFailed)
+		return
+	}
+	_ = s.c.Error(err)
+	s.c.Abort()
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// stubExportService passes its pages and records on, waiting delay before each page, then
+// fails with err if set
+type stubExportService struct {
+	pages   [][]*models.Transaction
+	records []dto.AccountExportRecord
+	delay   time.Duration
+	err     error
+}
+
+func (s *stubExportService) ExportTransactions(ctx context.Context, portfolioID, userID string, fn func([]*models.Transaction) error) error {
+	for _, page := range s.pages {
+		select {
+		case <-time.After(s.delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func (s *stubExportService) ExportAccount(ctx context.Context, userID string, fn func(dto.AccountExportRecord) error) error {
+	for _, record := range s.records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	return s.err
+}
+
+func exportTestContext(query string) (*httptest.ResponseRecorder, *gin.Context) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: "p1"}}
+	c.Set(middleware.UserIDContextKey, uuid.New().String())
+	c.Request = httptest.NewRequest(http.MethodGet, "/export?"+query, nil)
+	return w, c
+}
+
+func TestExportHandler_ExportTransactions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	price := decimal.NewFromInt(100)
+	transaction := func(day int) *models.Transaction {
+		return &models.Transaction{
+			ID: uuid.New(), Type: models.TransactionTypeBuy, Symbol: "VTI",
+			Date:     time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(1), Price: &price, Currency: "USD",
+		}
+	}
+	pages := [][]*models.Transaction{{transaction(1), transaction(2)}, {transaction(3)}}
+
+	t.Run("CSV", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{pages: pages})
+		w, c := exportTestContext("")
+
+		handler.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="transactions-p1.csv"`, w.Header().Get("Content-Disposition"))
+		assert.True(t, w.Flushed)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 4)
+		assert.Equal(t, "Date,Type,Symbol,Quantity,Price,Commission,Currency,Notes", lines[0])
+		assert.Equal(t, "2024-01-03,BUY,VTI,1,100,0,USD,", lines[3])
+	})
+
+	t.Run("JSON Lines", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{pages: pages})
+		w, c := exportTestContext("format=jsonl")
+
+		handler.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, 3, strings.Count(w.Body.String(), "\n"))
+	})
+
+	t.Run("no transactions", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{})
+		w, c := exportTestContext("format=csv")
+
+		handler.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "Date,Type,Symbol,Quantity,Price,Commission,Currency,Notes\n", w.Body.String())
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{pages: pages})
+		w, c := exportTestContext("format=xlsx")
+
+		handler.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("error before anything was sent", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{err: models.ErrUnauthorizedAccess})
+		w, c := exportTestContext("")
+
+		handler.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("outlasts the request timeout", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{pages: pages, delay: 30 * time.Millisecond})
+		router := gin.New()
+		router.Use(middleware.RequestTimeout(20 * time.Millisecond))
+		var errs []*gin.Error
+		router.GET("/portfolios/:id/transactions/export", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, uuid.New().String())
+			handler.ExportTransactions(c)
+			errs = c.Errors
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/portfolios/p1/transactions/export", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, errs)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Len(t, lines, 4)
+	})
+
+	t.Run("stops when the client disconnects", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{pages: pages, delay: time.Hour})
+		w, c := exportTestContext("")
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		cancel()
+
+		handler.ExportTransactions(c)
+
+		assert.Equal(t, apierrors.StatusClientClosedRequest, w.Code)
+	})
+
+	t.Run("error while streaming", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{pages: pages, err: errors.New("connection lost")})
+		w, c := exportTestContext("")
+
+		handler.ExportTransactions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, c.IsAborted())
+		require.Len(t, c.Errors, 1)
+		assert.Equal(t, "connection lost", c.Errors[0].Error())
+	})
+}
+
+func TestExportHandler_ExportAccount(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("one record per line", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{records: []dto.AccountExportRecord{
+			{Type: dto.AccountExportUser, Data: map[string]string{"email": "user@example.com"}},
+			{Type: dto.AccountExportPortfolio, Data: map[string]string{"name": "Retirement"}},
+		}})
+		w, c := exportTestContext("")
+
+		handler.ExportAccount(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="account-export.jsonl"`, w.Header().Get("Content-Disposition"))
+
+		var types []dto.AccountExportRecordType
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var record struct {
+				Type dto.AccountExportRecordType `json:"type"`
+				Data map[string]string           `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+			types = append(types, record.Type)
+		}
+		assert.Equal(t, []dto.AccountExportRecordType{dto.AccountExportUser, dto.AccountExportPortfolio}, types)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		handler := NewExportHandler(&stubExportService{err: models.ErrUserNotFound})
+		w, c := exportTestContext("")
+
+		handler.ExportAccount(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// FeatureFlagHandler handles requests for the features enabled for users, and the admin
+// requests that turn features on or off for everyone or for one user
+type FeatureFlagHandler struct {
+	featureFlagService services.FeatureFlagService
+}
+
+// NewFeatureFlagHandler creates a new FeatureFlagHandler instance
+func NewFeatureFlagHandler(featureFlagService services.FeatureFlagService) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		featureFlagService: featureFlagService,
+	}
+}
+
+// GetFeatures handles listing whether each feature is enabled for the authenticated user
+// GET /api/v1/features
+func (h *FeatureFlagHandler) GetFeatures(c *gin.Context) {
+	c.JSON(http.StatusOK, h.featureFlagService.ForUser(c.Request.Context(), middleware.GetUserID(c)))
+}
+
+// ListFeatureFlags handles listing every feature flag with its overrides
+// GET /api/v1/admin/feature-flags
+func (h *FeatureFlagHandler) ListFeatureFlags(c *gin.Context) {
+	flags, err := h.featureFlagService.List(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FeatureFlagListResponse{FeatureFlags: flags, Total: len(flags)})
+}
+
+// SetFeatureFlag handles turning a feature on or off for everyone or for one user
+// PUT /api/v1/admin/feature-flags/:name
+func (h *FeatureFlagHandler) SetFeatureFlag(c *gin.Context) {
+	var req dto.SetFeatureFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	flag, err := h.featureFlagService.SetOverride(c.Request.Context(), c.Param("name"), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// DeleteFeatureFlag handles removing the override of a feature for everyone, or for the
+// user named by the user_id query parameter
+// DELETE /api/v1/admin/feature-flags/:name
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *gin.Context) {
+	var req dto.DeleteFeatureFlagRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	flag, err := h.featureFlagService.DeleteOverride(c.Request.Context(), c.Param("name"), req.UserID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flag)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *FeatureFlagHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process feature flag request"))
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockFeatureFlagService is a mock implementation of FeatureFlagService
+type MockFeatureFlagService struct {
+	mock.Mock
+}
+
+func (m *MockFeatureFlagService) IsEnabled(ctx context.Context, name, userID string) bool {
+	return m.Called(ctx, name, userID).Bool(0)
+}
+
+func (m *MockFeatureFlagService) ForUser(ctx context.Context, userID string) *dto.FeaturesResponse {
+	return m.Called(ctx, userID).Get(0).(*dto.FeaturesResponse)
+}
+
+func (m *MockFeatureFlagService) List(ctx context.Context) ([]dto.FeatureFlagResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.FeatureFlagResponse), args.Error(1)
+}
+
+func (m *MockFeatureFlagService) SetOverride(ctx context.Context, name string, req dto.SetFeatureFlagRequest) (*dto.FeatureFlagResponse, error) {
+	args := m.Called(ctx, name, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.FeatureFlagResponse), args.Error(1)
+}
+
+func (m *MockFeatureFlagService) DeleteOverride(ctx context.Context, name, userID string) (*dto.FeatureFlagResponse, error) {
+	args := m.Called(ctx, name, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.FeatureFlagResponse), args.Error(1)
+}
+
+func (m *MockFeatureFlagService) Configure(features map[string]bool) {
+	m.Called(features)
+}
+
+func setupFeatureFlagRouter(service *MockFeatureFlagService, userID string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Next()
+	})
+	handler := NewFeatureFlagHandler(service)
+	router.GET("/api/v1/features", handler.GetFeatures)
+	router.GET("/api/v1/admin/feature-flags", handler.ListFeatureFlags)
+	router.PUT("/api/v1/admin/feature-flags/:name", handler.SetFeatureFlag)
+	router.DELETE("/api/v1/admin/feature-flags/:name", handler.DeleteFeatureFlag)
+	return router
+}
+
+func serveFeatureFlags(router *gin.Engine, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestFeatureFlagHandler_GetFeatures(t *testing.T) {
+	service := new(MockFeatureFlagService)
+	userID := uuid.New().String()
+	service.On("ForUser", mock.Anything, userID).
+		Return(&dto.FeaturesResponse{Features: map[string]bool{models.FeatureWhatIf: false}})
+
+	w := serveFeatureFlags(setupFeatureFlagRouter(service, userID), http.MethodGet, "/api/v1/features", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.FeaturesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	enabled, ok := response.Features[models.FeatureWhatIf]
+	assert.True(t, ok)
+	assert.False(t, enabled)
+	service.AssertExpectations(t)
+}
+
+func TestFeatureFlagHandler_ListFeatureFlags(t *testing.T) {
+	service := new(MockFeatureFlagService)
+	service.On("List", mock.Anything).Return([]dto.FeatureFlagResponse{{Name: models.FeatureSimulations, Enabled: true}}, nil)
+
+	w := serveFeatureFlags(setupFeatureFlagRouter(service, ""), http.MethodGet, "/api/v1/admin/feature-flags", "")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.FeatureFlagListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, models.FeatureSimulations, response.FeatureFlags[0].Name)
+}
+
+func TestFeatureFlagHandler_SetFeatureFlag(t *testing.T) {
+	userID := uuid.New().String()
+
+	t.Run("override for a user", func(t *testing.T) {
+		service := new(MockFeatureFlagService)
+		enabled := true
+		service.On("SetOverride", mock.Anything, models.FeatureWhatIf, dto.SetFeatureFlagRequest{Enabled: &enabled, UserID: userID}).
+			Return(&dto.FeatureFlagResponse{Name: models.FeatureWhatIf}, nil)
+
+		w := serveFeatureFlags(setupFeatureFlagRouter(service, ""), http.MethodPut,
+			"/api/v1/admin/feature-flags/what_if", `{"enabled": true, "user_id": "`+userID+`"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		service.AssertExpectations(t)
+	})
+
+	t.Run("unknown feature", func(t *testing.T) {
+		service := new(MockFeatureFlagService)
+		service.On("SetOverride", mock.Anything, "graphql", mock.Anything).Return(nil, models.ErrFeatureNotFound)
+
+		w := serveFeatureFlags(setupFeatureFlagRouter(service, ""), http.MethodPut,
+			"/api/v1/admin/feature-flags/graphql", `{"enabled": true}`)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Contains(t, w.Body.String(), "FEATURE_NOT_FOUND")
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		router := setupFeatureFlagRouter(new(MockFeatureFlagService), "")
+		for _, body := range []string{`{}`, `{"enabled": true, "user_id": "jane"}`} {
+			w := serveFeatureFlags(router, http.MethodPut, "/api/v1/admin/feature-flags/what_if", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+}
+
+func TestFeatureFlagHandler_DeleteFeatureFlag(t *testing.T) {
+	service := new(MockFeatureFlagService)
+	userID := uuid.New().String()
+	service.On("DeleteOverride", mock.Anything, models.FeatureWhatIf, userID).
+		Return(&dto.FeatureFlagResponse{Name: models.FeatureWhatIf}, nil)
+	router := setupFeatureFlagRouter(service, "")
+
+	w := serveFeatureFlags(router, http.MethodDelete, "/api/v1/admin/feature-flags/what_if?user_id="+userID, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	service.AssertExpectations(t)
+
+	w = serveFeatureFlags(router, http.MethodDelete, "/api/v1/admin/feature-flags/what_if?user_id=jane", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
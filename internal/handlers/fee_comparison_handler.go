@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// FeeComparisonHandler handles historical fee comparison HTTP requests
+type FeeComparisonHandler struct {
+	feeComparisonService services.FeeComparisonService
+}
+
+// NewFeeComparisonHandler creates a new FeeComparisonHandler instance
+func NewFeeComparisonHandler(feeComparisonService services.FeeComparisonService) *FeeComparisonHandler {
+	return &FeeComparisonHandler{
+		feeComparisonService: feeComparisonService,
+	}
+}
+
+// ListPresets handles listing the built-in broker fee schedules
+// GET /api/v1/fee-schedules
+func (h *FeeComparisonHandler) ListPresets(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.FeeSchedulePresetsResponse{
+		Presets: models.FeeSchedulePresets(),
+	})
+}
+
+// Compare handles recomputing a portfolio's historical trading fees under other fee schedules
+// POST /api/v1/portfolios/:id/fee-comparison
+func (h *FeeComparisonHandler) Compare(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.FeeComparisonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	if req.StartDate != nil && req.EndDate != nil && req.EndDate.Before(*req.StartDate) {
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("end_date must not be before start_date"))
+		return
+	}
+
+	schedules := make([]models.FeeSchedule, 0, len(req.Presets)+len(req.Schedules))
+	for _, key := range req.Presets {
+		preset, err := models.FindFeeSchedulePreset(key)
+		if err != nil {
+			apierrors.Respond(c, apierrors.PresetNotFound.WithMessage("Unknown fee schedule preset: "+key))
+			return
+		}
+		schedules = append(schedules, preset)
+	}
+	for _, schedule := range req.Schedules {
+		schedule.Key = ""
+		schedules = append(schedules, schedule)
+	}
+
+	result, err := h.feeComparisonService.Compare(c.Request.Context(), portfolioID, userID.(string), schedules, req.StartDate, req.EndDate)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *FeeComparisonHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to compare fees"))
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockFeeComparisonService is a mock implementation of FeeComparisonService
+type MockFeeComparisonService struct {
+	mock.Mock
+}
+
+func (m *MockFeeComparisonService) Compare(ctx context.Context, portfolioID, userID string, schedules []models.FeeSchedule, startDate, endDate *time.Time) (*services.FeeComparisonResult, error) {
+	args := m.Called(portfolioID, userID, schedules, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.FeeComparisonResult), args.Error(1)
+}
+
+func newFeeComparisonContext(w *httptest.ResponseRecorder, portfolioID, userID, body string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	return c
+}
+
+func TestFeeComparisonHandler_ListPresets(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewFeeComparisonHandler(new(MockFeeComparisonService))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	handler.ListPresets(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.FeeSchedulePresetsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Presets, len(models.FeeSchedulePresets()))
+}
+
+func TestFeeComparisonHandler_Compare(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockFeeComparisonService)
+	handler := NewFeeComparisonHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	zero, _ := models.FindFeeSchedulePreset("ZERO_COMMISSION")
+	custom := models.FeeSchedule{Name: "Custom", Type: models.FeeScheduleTypePerShare, Rate: decimal.RequireFromString("0.002")}
+
+	mockService.On("Compare", portfolioID, userID, mock.MatchedBy(func(schedules []models.FeeSchedule) bool {
+		return len(schedules) == 2 && schedules[0].Key == zero.Key && schedules[1].Name == custom.Name && schedules[1].Key == ""
+	}), mock.AnythingOfType("*time.Time"), (*time.Time)(nil)).
+		Return(&services.FeeComparisonResult{PortfolioID: portfolioID, TradeCount: 3}, nil)
+
+	w := httptest.NewRecorder()
+	c := newFeeComparisonContext(w, portfolioID, userID, `{
+		"presets": ["zero_commission"],
+		"schedules": [{"key": "IGNORED", "name": "Custom", "type": "PER_SHARE", "rate": "0.002"}],
+		"start_date": "2024-01-01T00:00:00Z"
+	}`)
+
+	handler.Compare(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.FeeComparisonResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.TradeCount)
+	mockService.AssertExpectations(t)
+}
+
+func TestFeeComparisonHandler_Compare_UnknownPreset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewFeeComparisonHandler(new(MockFeeComparisonService))
+
+	w := httptest.NewRecorder()
+	c := newFeeComparisonContext(w, uuid.New().String(), uuid.New().String(), `{"presets": ["FLAT_99"]}`)
+
+	handler.Compare(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "PRESET_NOT_FOUND")
+}
+
+func TestFeeComparisonHandler_Compare_InvalidDateRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewFeeComparisonHandler(new(MockFeeComparisonService))
+
+	w := httptest.NewRecorder()
+	c := newFeeComparisonContext(w, uuid.New().String(), uuid.New().String(),
+		`{"start_date": "2024-06-01T00:00:00Z", "end_date": "2024-01-01T00:00:00Z"}`)
+
+	handler.Compare(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFeeComparisonHandler_Compare_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"invalid schedule", models.ErrInvalidFeeSchedule, http.StatusBadRequest, "INVALID_FEE_SCHEDULE"},
+		{"internal", assert.AnError, http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockFeeComparisonService)
+			handler := NewFeeComparisonHandler(mockService)
+			portfolioID := uuid.New().String()
+			userID := uuid.New().String()
+
+			mockService.On("Compare", portfolioID, userID, mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			c := newFeeComparisonContext(w, portfolioID, userID, `{}`)
+
+			handler.Compare(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// FeeSummaryHandler handles yearly fee summary HTTP requests
+type FeeSummaryHandler struct {
+	feeSummaryService services.FeeSummaryService
+}
+
+// NewFeeSummaryHandler creates a new FeeSummaryHandler instance
+func NewFeeSummaryHandler(feeSummaryService services.FeeSummaryService) *FeeSummaryHandler {
+	return &FeeSummaryHandler{
+		feeSummaryService: feeSummaryService,
+	}
+}
+
+// Get handles summarizing the fees a portfolio paid in a year and their drag on its return
+// GET /api/v1/portfolios/:id/fees
+func (h *FeeSummaryHandler) Get(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.FeeSummaryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	summary, err := h.feeSummaryService.Summarize(c.Request.Context(), portfolioID, userID.(string), req.Year)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *FeeSummaryHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to summarize fees"))
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockFeeSummaryService is a mock implementation of FeeSummaryService
+type MockFeeSummaryService struct {
+	mock.Mock
+}
+
+func (m *MockFeeSummaryService) Summarize(ctx context.Context, portfolioID, userID string, year int) (*services.FeeSummary, error) {
+	args := m.Called(portfolioID, userID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.FeeSummary), args.Error(1)
+}
+
+func newFeeSummaryContext(w *httptest.ResponseRecorder, portfolioID, userID, query string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	return c
+}
+
+func TestFeeSummaryHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockFeeSummaryService)
+	handler := NewFeeSummaryHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("Summarize", portfolioID, userID, 2024).
+		Return(&services.FeeSummary{PortfolioID: portfolioID, Year: 2024, TotalFees: decimal.NewFromInt(42)}, nil)
+
+	w := httptest.NewRecorder()
+	c := newFeeSummaryContext(w, portfolioID, userID, "year=2024")
+
+	handler.Get(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.FeeSummary
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2024, response.Year)
+	assert.True(t, decimal.NewFromInt(42).Equal(response.TotalFees))
+	mockService.AssertExpectations(t)
+}
+
+func TestFeeSummaryHandler_Get_DefaultYear(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockFeeSummaryService)
+	handler := NewFeeSummaryHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("Summarize", portfolioID, userID, 0).Return(&services.FeeSummary{PortfolioID: portfolioID}, nil)
+
+	w := httptest.NewRecorder()
+	handler.Get(newFeeSummaryContext(w, portfolioID, userID, ""))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestFeeSummaryHandler_Get_InvalidYear(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewFeeSummaryHandler(new(MockFeeSummaryService))
+
+	w := httptest.NewRecorder()
+	handler.Get(newFeeSummaryContext(w, uuid.New().String(), uuid.New().String(), "year=abc"))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFeeSummaryHandler_Get_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"future year", models.ErrInvalidFeeYear, http.StatusBadRequest, "INVALID_PERIOD"},
+		{"internal", assert.AnError, http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockFeeSummaryService)
+			handler := NewFeeSummaryHandler(mockService)
+			portfolioID := uuid.New().String()
+			userID := uuid.New().String()
+
+			mockService.On("Summarize", portfolioID, userID, mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			handler.Get(newFeeSummaryContext(w, portfolioID, userID, ""))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -4,73 +4,81 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
-	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/services"
 )
 
 // HoldingHandler handles holding-related HTTP requests
 type HoldingHandler struct {
 	holdingService services.HoldingService
+	restrictions   services.TradingRestrictionLookup
 }
 
 // NewHoldingHandler creates a new HoldingHandler instance
 func NewHoldingHandler(holdingService services.HoldingService) *HoldingHandler {
+	return NewHoldingHandlerWithTradingRestrictions(holdingService, nil)
+}
+
+// NewHoldingHandlerWithTradingRestrictions creates a HoldingHandler that marks holdings in
+// symbols restrictions reports as halted or suspended. A nil lookup marks none.
+func NewHoldingHandlerWithTradingRestrictions(holdingService services.HoldingService, restrictions services.TradingRestrictionLookup) *HoldingHandler {
 	return &HoldingHandler{
 		holdingService: holdingService,
+		restrictions:   restrictions,
 	}
 }
 
-// GetAll retrieves all holdings for a portfolio
+// GetAll retrieves all holdings for a portfolio, or with as_of=YYYY-MM-DD those it had at the
+// end of that day
 // GET /api/v1/portfolios/:id/holdings
 func (h *HoldingHandler) GetAll(c *gin.Context) {
 	// Get portfolio ID from URL parameter
 	portfolioID := c.Param("id")
 	if portfolioID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Portfolio ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Portfolio ID is required"))
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
-	// Get all holdings for the portfolio
-	holdings, err := h.holdingService.GetByPortfolioID(portfolioID, userID.(string))
-	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "You don't have permission to access this portfolio",
-				Code:  "FORBIDDEN",
-			})
+	var req dto.HoldingListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Past holdings are rebuilt from the transactions up to the end of that day
+	if !req.AsOf.IsZero() {
+		holdings, err := h.holdingService.GetByPortfolioIDAsOf(c.Request.Context(), portfolioID, userID.(string), req.AsOf)
+		if err != nil {
+			apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve holdings"))
 			return
 		}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve holdings",
-			Code:  "RETRIEVAL_FAILED",
-		})
+		response := dto.ToHoldingListResponse(holdings)
+		response.AsOf = &req.AsOf
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	// Get all holdings for the portfolio
+	holdings, err := h.holdingService.GetByPortfolioID(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve holdings"))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToHoldingListResponse(holdings))
+	response := dto.ToHoldingListResponse(holdings)
+	h.markTradingRestrictions(c, response.Holdings...)
+	c.JSON(http.StatusOK, response)
 }
 
 // GetBySymbol retrieves a specific holding by symbol
@@ -81,54 +89,43 @@ func (h *HoldingHandler) GetBySymbol(c *gin.Context) {
 	symbol := c.Param("symbol")
 
 	if portfolioID == "" || symbol == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Portfolio ID and symbol are required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Portfolio ID and symbol are required"))
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Get the holding
-	holding, err := h.holdingService.GetByPortfolioIDAndSymbol(portfolioID, symbol, userID.(string))
+	holding, err := h.holdingService.GetByPortfolioIDAndSymbol(c.Request.Context(), portfolioID, symbol, userID.(string))
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "You don't have permission to access this portfolio",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
-		if err == models.ErrHoldingNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Holding not found",
-				Code:  "HOLDING_NOT_FOUND",
-			})
-			return
-		}
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve holding"))
+		return
+	}
+
+	response := dto.ToHoldingResponse(holding)
+	h.markTradingRestrictions(c, response)
+	c.JSON(http.StatusOK, response)
+}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve holding",
-			Code:  "RETRIEVAL_FAILED",
-		})
+// markTradingRestrictions sets the trading restriction of holdings whose symbol is halted
+// or suspended
+func (h *HoldingHandler) markTradingRestrictions(c *gin.Context, holdings ...*dto.HoldingResponse) {
+	if h.restrictions == nil || len(holdings) == 0 {
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToHoldingResponse(holding))
+	symbols := make([]string, 0, len(holdings))
+	for _, holding := range holdings {
+		symbols = append(symbols, holding.Symbol)
+	}
+
+	restrictions := h.restrictions.GetTradingRestrictions(c.Request.Context(), symbols)
+	for _, holding := range holdings {
+		holding.TradingRestriction = restrictions[holding.Symbol]
+	}
 }
@@ -1,19 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/lenon/portfolios/internal/dto"
-	"github.com/lenon/portfolios/internal/middleware"
-	"github.com/lenon/portfolios/internal/models"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // MockHoldingService for testing
@@ -21,7 +24,7 @@ type MockHoldingService struct {
 	mock.Mock
 }
 
-func (m *MockHoldingService) GetByPortfolioID(portfolioID, userID string) ([]*models.Holding, error) {
+func (m *MockHoldingService) GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.Holding, error) {
 	args := m.Called(portfolioID, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -29,7 +32,7 @@ func (m *MockHoldingService) GetByPortfolioID(portfolioID, userID string) ([]*mo
 	return args.Get(0).([]*models.Holding), args.Error(1)
 }
 
-func (m *MockHoldingService) GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) (*models.Holding, error) {
+func (m *MockHoldingService) GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) (*models.Holding, error) {
 	args := m.Called(portfolioID, symbol, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,7 +40,15 @@ func (m *MockHoldingService) GetByPortfolioIDAndSymbol(portfolioID, symbol, user
 	return args.Get(0).(*models.Holding), args.Error(1)
 }
 
-func (m *MockHoldingService) GetPortfolioValue(portfolioID, userID string, prices map[string]decimal.Decimal) (decimal.Decimal, error) {
+func (m *MockHoldingService) GetByPortfolioIDAsOf(ctx context.Context, portfolioID, userID string, asOf time.Time) ([]*models.Holding, error) {
+	args := m.Called(portfolioID, userID, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Holding), args.Error(1)
+}
+
+func (m *MockHoldingService) GetPortfolioValue(ctx context.Context, portfolioID, userID string, prices map[string]decimal.Decimal) (decimal.Decimal, error) {
 	args := m.Called(portfolioID, userID, prices)
 	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
@@ -99,6 +110,40 @@ func TestHoldingHandler_GetAll(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("marks halted holdings", func(t *testing.T) {
+		mockService := new(MockHoldingService)
+		mockMarketData := new(MockMarketDataService)
+		handler := NewHoldingHandlerWithTradingRestrictions(mockService, mockMarketData)
+
+		mockService.On("GetByPortfolioID", portfolioID.String(), userID.String()).Return(holdings, nil)
+		mockMarketData.On("GetTradingRestrictions", []string{"AAPL", "GOOGL"}).Return(map[string]*dto.SymbolTradingStatus{
+			"GOOGL": {Symbol: "GOOGL", Status: dto.TradingStatusHalted},
+		})
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/api/v1/portfolios/:id/holdings", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID.String())
+			handler.GetAll(c)
+		})
+
+		req, _ := http.NewRequest("GET", "/api/v1/portfolios/"+portfolioID.String()+"/holdings", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response dto.HoldingListResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Nil(t, response.Holdings[0].TradingRestriction)
+		if assert.NotNil(t, response.Holdings[1].TradingRestriction) {
+			assert.Equal(t, dto.TradingStatusHalted, response.Holdings[1].TradingRestriction.Status)
+		}
+
+		mockMarketData.AssertExpectations(t)
+	})
+
 	t.Run("portfolio not found", func(t *testing.T) {
 		mockService := new(MockHoldingService)
 		handler := NewHoldingHandler(mockService)
@@ -155,6 +200,60 @@ func TestHoldingHandler_GetAll(t *testing.T) {
 
 		assert.Equal(t, http.StatusUnauthorized, w.Code)
 	})
+
+	t.Run("holdings as of a past date", func(t *testing.T) {
+		mockService := new(MockHoldingService)
+		mockMarketData := new(MockMarketDataService)
+		handler := NewHoldingHandlerWithTradingRestrictions(mockService, mockMarketData)
+
+		mockService.On("GetByPortfolioIDAsOf", portfolioID.String(), userID.String(), mock.MatchedBy(func(date time.Time) bool {
+			return date.Format("2006-01-02") == "2023-06-30"
+		})).Return(holdings[:1], nil)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/api/v1/portfolios/:id/holdings", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID.String())
+			handler.GetAll(c)
+		})
+
+		req, _ := http.NewRequest("GET", "/api/v1/portfolios/"+portfolioID.String()+"/holdings?as_of=2023-06-30", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response dto.HoldingListResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response.Holdings, 1)
+		if assert.NotNil(t, response.AsOf) {
+			assert.Equal(t, "2023-06-30", response.AsOf.Format("2006-01-02"))
+		}
+
+		// Past holdings aren't marked with today's trading restrictions
+		mockService.AssertExpectations(t)
+		mockMarketData.AssertNotCalled(t, "GetTradingRestrictions", mock.Anything)
+	})
+
+	t.Run("invalid as_of date", func(t *testing.T) {
+		mockService := new(MockHoldingService)
+		handler := NewHoldingHandler(mockService)
+
+		gin.SetMode(gin.TestMode)
+		router := gin.New()
+		router.GET("/api/v1/portfolios/:id/holdings", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID.String())
+			handler.GetAll(c)
+		})
+
+		req, _ := http.NewRequest("GET", "/api/v1/portfolios/"+portfolioID.String()+"/holdings?as_of=30/06/2023", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetByPortfolioIDAsOf", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 func TestHoldingHandler_GetBySymbol(t *testing.T) {
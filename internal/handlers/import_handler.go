@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/services"
 )
@@ -24,13 +27,13 @@ func NewImportHandler(importService services.CSVImportService) *ImportHandler {
 
 // ImportCSV handles CSV file import
 // @Summary Import transactions from CSV
-// @Description Import transactions from a CSV file in various broker formats
+// @Description Import transactions from a CSV file in various broker formats. The import runs in the background; follow its progress on the events URL in the response.
 // @Tags imports
 // @Accept json
 // @Produce json
 // @Param id path string true "Portfolio ID"
 // @Param request body dto.CSVImportRequest true "CSV import request"
-// @Success 200 {object} dto.ImportResult
+// @Success 202 {object} dto.ImportJobResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
@@ -43,26 +46,96 @@ func (h *ImportHandler) ImportCSV(c *gin.Context) {
 
 	var req dto.CSVImportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		apierrors.RespondInvalid(c, "Invalid request body", err)
 		return
 	}
 
-	result, err := h.importService.ImportFromCSV(portfolioID, userID, req)
+	batchID, err := h.importService.StartImportFromCSV(c.Request.Context(), portfolioID, userID, req)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "portfolio not found" {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		apierrors.RespondError(c, err, apierrors.ImportFailed)
 		return
 	}
 
-	statusCode := http.StatusOK
-	if !result.Success {
-		statusCode = http.StatusBadRequest
+	c.JSON(http.StatusAccepted, dto.ImportJobResponse{
+		BatchID:   batchID,
+		Status:    dto.ImportStatusQueued,
+		EventsURL: fmt.Sprintf("/api/v1/portfolios/%s/imports/batches/%s/events", portfolioID, batchID),
+		StatusURL: dto.JobStatusURL(batchID.String()),
+	})
+}
+
+// ImportEvents streams the progress of a background CSV import as server-sent events
+// @Summary Stream import progress
+// @Description Stream the progress of a background CSV import as server-sent events: "progress" while it runs, then "completed" with the import result or "failed". The stream ends once the import has finished.
+// @Tags imports
+// @Produce text/event-stream
+// @Param id path string true "Portfolio ID"
+// @Param batch_id path string true "Import Batch ID"
+// @Success 200 {object} dto.ImportProgress
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security Bearer
+// @Router /api/v1/portfolios/{id}/imports/batches/{batch_id}/events [get]
+func (h *ImportHandler) ImportEvents(c *gin.Context) {
+	portfolioID := c.Param("id")
+	userID := c.GetString("user_id")
+
+	batchID, err := uuid.Parse(c.Param("batch_id"))
+	if err != nil {
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Invalid batch ID"))
+		return
 	}
 
-	c.JSON(statusCode, result)
+	ctx := c.Request.Context()
+	events, err := h.importService.WatchImport(ctx, portfolioID, userID, batchID)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve import"))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Stop nginx from buffering the stream
+	// Imports can take longer than the server's write timeout
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(importKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case progress, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent(importEventName(progress.Status), progress)
+			c.Writer.Flush()
+		case <-keepAlive.C:
+			// A comment line keeps proxies from closing a quiet stream
+			_, _ = c.Writer.WriteString(": keep-alive\n\n")
+			c.Writer.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// importKeepAliveInterval is how often a quiet import event stream is sent a comment
+const importKeepAliveInterval = 15 * time.Second
+
+// importEventName returns the server-sent event name for an import's progress
+func importEventName(status dto.ImportStatus) string {
+	switch status {
+	case dto.ImportStatusCompleted:
+		return "completed"
+	case dto.ImportStatusFailed:
+		return "failed"
+	default:
+		return "progress"
+	}
 }
 
 // ImportBulk handles bulk transaction import
@@ -86,17 +159,13 @@ func (h *ImportHandler) ImportBulk(c *gin.Context) {
 
 	var req dto.BulkImportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		apierrors.RespondInvalid(c, "Invalid request body", err)
 		return
 	}
 
-	result, err := h.importService.ImportBulk(portfolioID, userID, req)
+	result, err := h.importService.ImportBulk(c.Request.Context(), portfolioID, userID, req)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "portfolio not found" {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		apierrors.RespondError(c, err, apierrors.ImportFailed)
 		return
 	}
 
@@ -124,13 +193,9 @@ func (h *ImportHandler) GetImportBatches(c *gin.Context) {
 	portfolioID := c.Param("id")
 	userID := c.GetString("user_id")
 
-	result, err := h.importService.GetImportBatches(portfolioID, userID)
+	result, err := h.importService.GetImportBatches(c.Request.Context(), portfolioID, userID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "portfolio not found" {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve import batches"))
 		return
 	}
 
@@ -158,17 +223,13 @@ func (h *ImportHandler) DeleteImportBatch(c *gin.Context) {
 
 	batchID, err := uuid.Parse(batchIDStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Invalid batch ID"))
 		return
 	}
 
-	err = h.importService.DeleteImportBatch(portfolioID, userID, batchID)
+	err = h.importService.DeleteImportBatch(c.Request.Context(), portfolioID, userID, batchID)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "portfolio not found" {
-			status = http.StatusNotFound
-		}
-		c.JSON(status, gin.H{"error": err.Error()})
+		apierrors.RespondError(c, err, apierrors.DeleteFailed.WithMessage("Failed to delete import batch"))
 		return
 	}
 
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockCSVImportService is a mock implementation of CSVImportService
+type MockCSVImportService struct {
+	mock.Mock
+}
+
+func (m *MockCSVImportService) ImportFromCSV(ctx context.Context, portfolioID, userID string, req dto.CSVImportRequest) (*dto.ImportResult, error) {
+	args := m.Called(portfolioID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImportResult), args.Error(1)
+}
+
+func (m *MockCSVImportService) StartImportFromCSV(ctx context.Context, portfolioID, userID string, req dto.CSVImportRequest) (uuid.UUID, error) {
+	args := m.Called(portfolioID, userID, req)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockCSVImportService) WatchImport(ctx context.Context, portfolioID, userID string, batchID uuid.UUID) (<-chan dto.ImportProgress, error) {
+	args := m.Called(portfolioID, userID, batchID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(<-chan dto.ImportProgress), args.Error(1)
+}
+
+func (m *MockCSVImportService) RunImport(ctx context.Context, portfolioID string, batchID uuid.UUID, req dto.CSVImportRequest, checkpoint *dto.ImportProgress, onProgress func(dto.ImportProgress)) (*dto.ImportResult, error) {
+	args := m.Called(portfolioID, batchID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImportResult), args.Error(1)
+}
+
+func (m *MockCSVImportService) ImportBulk(ctx context.Context, portfolioID, userID string, req dto.BulkImportRequest) (*dto.ImportResult, error) {
+	args := m.Called(portfolioID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImportResult), args.Error(1)
+}
+
+func (m *MockCSVImportService) GetImportBatches(ctx context.Context, portfolioID, userID string) (*dto.ImportBatchListResponse, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImportBatchListResponse), args.Error(1)
+}
+
+func (m *MockCSVImportService) DeleteImportBatch(ctx context.Context, portfolioID, userID string, batchID uuid.UUID) error {
+	return m.Called(portfolioID, userID, batchID).Error(0)
+}
+
+func TestImportHandler_ImportCSV(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockCSVImportService)
+	handler := NewImportHandler(mockService)
+	userID := uuid.New().String()
+	portfolioID := uuid.New().String()
+	batchID := uuid.New()
+
+	req := dto.CSVImportRequest{Format: dto.ImportFormatGeneric, CSVData: "Date,Symbol,Type,Quantity\n"}
+	mockService.On("StartImportFromCSV", portfolioID, userID, req).Return(batchID, nil)
+
+	jsonBody, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Request = httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolioID+"/transactions/import/csv", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.ImportCSV(c)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var response dto.ImportJobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, batchID, response.BatchID)
+	assert.Equal(t, dto.ImportStatusQueued, response.Status)
+	assert.Equal(t, "/api/v1/portfolios/"+portfolioID+"/imports/batches/"+batchID.String()+"/events", response.EventsURL)
+	assert.Equal(t, "/api/v1/jobs/"+batchID.String(), response.StatusURL)
+	mockService.AssertExpectations(t)
+}
+
+func TestImportHandler_ImportEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New().String()
+	portfolioID := uuid.New().String()
+	batchID := uuid.New()
+
+	performImportEvents := func(service *MockCSVImportService, batch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Params = gin.Params{{Key: "id", Value: portfolioID}, {Key: "batch_id", Value: batch}}
+		c.Request = httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolioID+"/imports/batches/"+batch+"/events", nil)
+
+		NewImportHandler(service).ImportEvents(c)
+		return w
+	}
+
+	t.Run("streams progress until the import finishes", func(t *testing.T) {
+		events := make(chan dto.ImportProgress, 3)
+		events <- dto.ImportProgress{BatchID: batchID, Status: dto.ImportStatusRunning, TotalRows: 2, ProcessedRows: 1, SuccessCount: 1}
+		events <- dto.ImportProgress{
+			BatchID: batchID, Status: dto.ImportStatusCompleted, TotalRows: 2, ProcessedRows: 2, SuccessCount: 2,
+			Result: &dto.ImportResult{Success: true, BatchID: batchID, TotalRows: 2, SuccessCount: 2},
+		}
+		close(events)
+
+		mockService := new(MockCSVImportService)
+		mockService.On("WatchImport", portfolioID, userID, batchID).Return((<-chan dto.ImportProgress)(events), nil)
+
+		w := performImportEvents(mockService, batchID.String())
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream"))
+		body := w.Body.String()
+		assert.Contains(t, body, "event:progress\ndata:")
+		assert.Contains(t, body, "event:completed\ndata:")
+		assert.Less(t, strings.Index(body, "event:progress"), strings.Index(body, "event:completed"))
+		assert.Contains(t, body, `"processed_rows":1`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown import", func(t *testing.T) {
+		mockService := new(MockCSVImportService)
+		mockService.On("WatchImport", portfolioID, userID, batchID).Return(nil, models.ErrImportNotFound)
+
+		w := performImportEvents(mockService, batchID.String())
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("watch failure", func(t *testing.T) {
+		mockService := new(MockCSVImportService)
+		mockService.On("WatchImport", portfolioID, userID, batchID).Return(nil, errors.New("boom"))
+
+		w := performImportEvents(mockService, batchID.String())
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("invalid batch ID", func(t *testing.T) {
+		w := performImportEvents(new(MockCSVImportService), "not-a-uuid")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// JobHandler handles checking on imports, recalculations and reports queued to run in the
+// background
+type JobHandler struct {
+	jobQueueService services.JobQueueService
+}
+
+// NewJobHandler creates a new JobHandler instance
+func NewJobHandler(jobQueueService services.JobQueueService) *JobHandler {
+	return &JobHandler{
+		jobQueueService: jobQueueService,
+	}
+}
+
+// List handles retrieving the user's most recent jobs
+// GET /api/v1/jobs
+func (h *JobHandler) List(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.QueuedJobListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	jobs, err := h.jobQueueService.List(c.Request.Context(), userID.(string), req.Limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := &dto.QueuedJobListResponse{
+		Jobs: make([]*dto.QueuedJobResponse, len(jobs)),
+	}
+	for i, job := range jobs {
+		response.Jobs[i] = dto.ToQueuedJobResponse(job)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get handles retrieving the status, progress and result of a job
+// GET /api/v1/jobs/:id
+func (h *JobHandler) Get(c *gin.Context) {
+	jobID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	job, err := h.jobQueueService.Get(c.Request.Context(), jobID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToQueuedJobResponse(job))
+}
+
+// handleError maps service errors to HTTP responses
+func (h *JobHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to retrieve jobs"))
+}
+
+// respondJobQueued responds 202 Accepted with a job that was queued, pointing the client at
+// its status URL
+func respondJobQueued(c *gin.Context, job *models.QueuedJob) {
+	response := dto.ToQueuedJobResponse(job)
+	c.Header("Location", response.StatusURL)
+	c.JSON(http.StatusAccepted, response)
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockJobQueueService is a mock implementation of JobQueueService
+type MockJobQueueService struct {
+	mock.Mock
+}
+
+func (m *MockJobQueueService) Enqueue(ctx context.Context, userID, portfolioID string, jobType models.QueuedJobType, payload any) (*models.QueuedJob, error) {
+	args := m.Called(userID, portfolioID, jobType, payload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QueuedJob), args.Error(1)
+}
+
+func (m *MockJobQueueService) Get(ctx context.Context, jobID, userID string) (*models.QueuedJob, error) {
+	args := m.Called(jobID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QueuedJob), args.Error(1)
+}
+
+func (m *MockJobQueueService) List(ctx context.Context, userID string, limit int) ([]*models.QueuedJob, error) {
+	args := m.Called(userID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.QueuedJob), args.Error(1)
+}
+
+func (m *MockJobQueueService) Enqueued() <-chan struct{} {
+	return nil
+}
+
+// queuedJob returns a job as Enqueue would
+func queuedJob(jobType models.QueuedJobType, portfolioID string) *models.QueuedJob {
+	job := &models.QueuedJob{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Type:      jobType,
+		Status:    models.QueuedJobStatusQueued,
+		Payload:   "{}",
+		CreatedAt: time.Now().UTC(),
+	}
+	if portfolioID != "" {
+		id := uuid.MustParse(portfolioID)
+		job.PortfolioID = &id
+	}
+	return job
+}
+
+// assertJobQueued checks that a response accepted the job and points at its status
+func assertJobQueued(t *testing.T, w *httptest.ResponseRecorder, job *models.QueuedJob) {
+	t.Helper()
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/api/v1/jobs/"+job.ID.String(), w.Header().Get("Location"))
+
+	var response dto.QueuedJobResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, job.ID.String(), response.ID)
+	assert.Equal(t, job.Type, response.Type)
+	assert.Equal(t, models.QueuedJobStatusQueued, response.Status)
+	assert.Equal(t, "/api/v1/jobs/"+job.ID.String(), response.StatusURL)
+}
+
+func TestJobHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	t.Run("finished jobs include their result", func(t *testing.T) {
+		mockService := new(MockJobQueueService)
+		handler := NewJobHandler(mockService)
+
+		job := queuedJob(models.QueuedJobTypeRecalculation, uuid.New().String())
+		job.Status = models.QueuedJobStatusSucceeded
+		job.Result = `{"transactions_replayed":3}`
+		mockService.On("Get", job.ID.String(), userID).Return(job, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: job.ID.String()}}
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		handler.Get(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response dto.QueuedJobResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, models.QueuedJobStatusSucceeded, response.Status)
+		assert.Equal(t, job.PortfolioID.String(), response.PortfolioID)
+		assert.JSONEq(t, job.Result, string(response.Result))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(MockJobQueueService)
+		handler := NewJobHandler(mockService)
+		mockService.On("Get", "missing", userID).Return(nil, models.ErrQueuedJobNotFound)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "missing"}}
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		handler.Get(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "JOB_NOT_FOUND", response.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		handler := NewJobHandler(new(MockJobQueueService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+		c.Request = httptest.NewRequest("GET", "/", nil)
+
+		handler.Get(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestJobHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockJobQueueService)
+		handler := NewJobHandler(mockService)
+
+		failed := queuedJob(models.QueuedJobTypeTrackerImport, "")
+		failed.Status = models.QueuedJobStatusFailed
+		failed.Error = "invalid import file"
+		mockService.On("List", userID, 5).Return([]*models.QueuedJob{failed}, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("GET", "/?limit=5", nil)
+
+		handler.List(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response dto.QueuedJobListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Jobs, 1)
+		assert.Equal(t, models.QueuedJobStatusFailed, response.Jobs[0].Status)
+		assert.Equal(t, "invalid import file", response.Jobs[0].Error)
+		assert.Empty(t, response.Jobs[0].PortfolioID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		handler := NewJobHandler(new(MockJobQueueService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("GET", "/?limit=500", nil)
+
+		handler.List(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
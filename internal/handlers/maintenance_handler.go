@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+)
+
+// MaintenanceHandler handles the admin API requests that turn maintenance mode on and off,
+// for example around migrations, without editing the configuration of every instance
+type MaintenanceHandler struct {
+	maintenance *middleware.Maintenance
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler instance
+func NewMaintenanceHandler(maintenance *middleware.Maintenance) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenance: maintenance,
+	}
+}
+
+// GetMaintenance handles retrieving the maintenance mode in effect
+// GET /api/admin/v1/maintenance
+func (h *MaintenanceHandler) GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, toMaintenanceResponse(h.maintenance.State(c.Request.Context())))
+}
+
+// PutMaintenance handles turning maintenance mode on or off until it is cleared
+// PUT /api/admin/v1/maintenance
+func (h *MaintenanceHandler) PutMaintenance(c *gin.Context) {
+	var req dto.SetMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	state := middleware.MaintenanceState{
+		Enabled:    *req.Enabled,
+		Message:    req.Message,
+		RetryAfter: h.maintenance.Configured().RetryAfter,
+	}
+	if req.RetryAfterSeconds != nil {
+		state.RetryAfter = time.Duration(*req.RetryAfterSeconds) * time.Second
+	}
+	if err := h.maintenance.Set(c.Request.Context(), state); err != nil {
+		apierrors.Respond(c, apierrors.UpdateFailed.WithMessage("Failed to set maintenance mode"))
+		return
+	}
+
+	c.JSON(http.StatusOK, toMaintenanceResponse(h.maintenance.State(c.Request.Context())))
+}
+
+// DeleteMaintenance handles clearing the maintenance mode set through the admin API. The
+// response is the configured mode, which is in effect again.
+// DELETE /api/admin/v1/maintenance
+func (h *MaintenanceHandler) DeleteMaintenance(c *gin.Context) {
+	if err := h.maintenance.Clear(c.Request.Context()); err != nil {
+		apierrors.Respond(c, apierrors.DeleteFailed.WithMessage("Failed to clear maintenance mode"))
+		return
+	}
+
+	c.JSON(http.StatusOK, toMaintenanceResponse(h.maintenance.State(c.Request.Context())))
+}
+
+// toMaintenanceResponse converts a maintenance state to a response DTO
+func toMaintenanceResponse(state middleware.MaintenanceState) dto.MaintenanceResponse {
+	return dto.MaintenanceResponse{
+		Enabled:           state.Enabled,
+		Message:           state.Message,
+		RetryAfterSeconds: int(state.RetryAfter / time.Second),
+		Since:             state.Since,
+		Source:            state.Source,
+	}
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+)
+
+func setupMaintenanceRouter(maintenance *middleware.Maintenance) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewMaintenanceHandler(maintenance)
+	router.GET("/api/admin/v1/maintenance", handler.GetMaintenance)
+	router.PUT("/api/admin/v1/maintenance", handler.PutMaintenance)
+	router.DELETE("/api/admin/v1/maintenance", handler.DeleteMaintenance)
+	return router
+}
+
+func maintenanceResponse(t *testing.T, router *gin.Engine, method, body string) (int, dto.MaintenanceResponse) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/api/admin/v1/maintenance", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var response dto.MaintenanceResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response
+}
+
+func TestMaintenanceHandler(t *testing.T) {
+	router := setupMaintenanceRouter(middleware.NewMaintenance(nil, false, 2*time.Minute))
+
+	status, response := maintenanceResponse(t, router, http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.False(t, response.Enabled)
+	assert.Equal(t, "config", response.Source)
+	assert.Equal(t, 120, response.RetryAfterSeconds)
+
+	// The configured Retry-After is kept unless the request sets one
+	status, response = maintenanceResponse(t, router, http.MethodPut, `{"enabled": true, "message": "Migrating"}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.True(t, response.Enabled)
+	assert.Equal(t, "Migrating", response.Message)
+	assert.Equal(t, 120, response.RetryAfterSeconds)
+	assert.Equal(t, "admin", response.Source)
+	assert.False(t, response.Since.IsZero())
+
+	_, response = maintenanceResponse(t, router, http.MethodPut, `{"enabled": true, "retry_after_seconds": 0}`)
+	assert.Zero(t, response.RetryAfterSeconds)
+
+	status, response = maintenanceResponse(t, router, http.MethodDelete, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.False(t, response.Enabled)
+	assert.Equal(t, "config", response.Source)
+}
+
+func TestMaintenanceHandler_InvalidRequest(t *testing.T) {
+	router := setupMaintenanceRouter(middleware.NewMaintenance(nil, false, time.Minute))
+
+	for _, body := range []string{`{}`, `{"enabled": true, "retry_after_seconds": -1}`} {
+		status, _ := maintenanceResponse(t, router, http.MethodPut, body)
+		assert.Equal(t, http.StatusBadRequest, status, body)
+	}
+}
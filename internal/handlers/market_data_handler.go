@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/services"
 )
 
@@ -27,19 +32,24 @@ func (h *MarketDataHandler) GetQuote(c *gin.Context) {
 	symbol := c.Param("symbol")
 
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Symbol is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Symbol is required"))
 		return
 	}
 
-	quote, err := h.marketDataService.GetQuote(symbol)
+	quote, err := h.marketDataService.GetQuote(c.Request.Context(), symbol)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve quote: " + err.Error(),
-			Code:  "QUOTE_FETCH_FAILED",
-		})
+		if apierrors.RespondContextDone(c, err) {
+			return
+		}
+
+		if h.respondQuotaExceeded(c, err) {
+			return
+		}
+		if e, ok := apierrors.Lookup(err); ok {
+			apierrors.Respond(c, e)
+			return
+		}
+		apierrors.Respond(c, apierrors.QuoteFetchFailed.WithMessage("Failed to retrieve quote: "+err.Error()))
 		return
 	}
 
@@ -52,93 +62,99 @@ func (h *MarketDataHandler) GetQuotes(c *gin.Context) {
 	var req dto.GetQuotesRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	if len(req.Symbols) == 0 {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "At least one symbol is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("At least one symbol is required"))
 		return
 	}
 
 	// Limit to reasonable number of symbols
 	if len(req.Symbols) > 100 {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Maximum 100 symbols allowed per request",
-			Code:  "TOO_MANY_SYMBOLS",
-		})
+		apierrors.Respond(c, apierrors.TooManySymbols.WithMessage("Maximum 100 symbols allowed per request"))
 		return
 	}
 
-	quotes, err := h.marketDataService.GetQuotes(req.Symbols)
+	quotes, err := h.marketDataService.GetQuotes(c.Request.Context(), req.Symbols)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve quotes: " + err.Error(),
-			Code:  "QUOTES_FETCH_FAILED",
-		})
+		if apierrors.RespondContextDone(c, err) {
+			return
+		}
+
+		if h.respondQuotaExceeded(c, err) {
+			return
+		}
+		if e, ok := apierrors.Lookup(err); ok {
+			apierrors.Respond(c, e)
+			return
+		}
+		apierrors.Respond(c, apierrors.QuotesFetchFailed.WithMessage("Failed to retrieve quotes: "+err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.ToQuotesResponse(quotes))
 }
 
-// GetHistoricalPrices retrieves historical price data for a symbol
-// GET /api/v1/market/history/:symbol
+// GetHistoricalPrices retrieves a symbol's price bars at a resolution of 1m, 5m, 1h, 1d (the
+// default) or 1w
+// GET /api/v1/market/history/:symbol?resolution=5m
 func (h *MarketDataHandler) GetHistoricalPrices(c *gin.Context) {
 	symbol := c.Param("symbol")
 
 	if symbol == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Symbol is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Symbol is required"))
 		return
 	}
 
 	var req dto.HistoricalPricesRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid query parameters: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
 		return
 	}
+	resolution := req.Resolution
+	if resolution == "" {
+		resolution = models.PriceResolution1Day
+	}
 
-	// Set default date range if not provided (last 30 days)
+	// Set default date range if not provided, sized to the resolution
 	startDate := req.StartDate
 	endDate := req.EndDate
-	if startDate.IsZero() {
-		startDate = time.Now().AddDate(0, 0, -30)
-	}
 	if endDate.IsZero() {
 		endDate = time.Now()
+	} else if resolution.IsIntraday() {
+		// Intraday bars run through the whole end date
+		endDate = endDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	if startDate.IsZero() {
+		startDate = endDate.Add(-resolution.DefaultPeriod())
 	}
 
 	// Validate date range
 	if endDate.Before(startDate) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "End date must be after start date",
-			Code:  "INVALID_DATE_RANGE",
-		})
+		apierrors.Respond(c, apierrors.InvalidDateRange)
 		return
 	}
 
-	prices, err := h.marketDataService.GetHistoricalPrices(symbol, startDate, endDate)
+	prices, err := h.marketDataService.GetPriceHistory(c.Request.Context(), symbol, resolution, startDate, endDate)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve historical prices: " + err.Error(),
-			Code:  "HISTORICAL_DATA_FETCH_FAILED",
-		})
+		if apierrors.RespondContextDone(c, err) {
+			return
+		}
+
+		if h.respondQuotaExceeded(c, err) {
+			return
+		}
+		if e, ok := apierrors.Lookup(err); ok {
+			apierrors.Respond(c, e)
+			return
+		}
+		apierrors.Respond(c, apierrors.HistoricalDataFetchFailed.WithMessage("Failed to retrieve historical prices: "+err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToHistoricalPricesResponse(prices))
+	c.JSON(http.StatusOK, dto.ToHistoricalPricesResponse(symbol, resolution, prices))
 }
 
 // GetExchangeRate retrieves exchange rate between two currencies
@@ -146,27 +162,29 @@ func (h *MarketDataHandler) GetHistoricalPrices(c *gin.Context) {
 func (h *MarketDataHandler) GetExchangeRate(c *gin.Context) {
 	var req dto.ExchangeRateRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid query parameters: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
 		return
 	}
 
 	if req.From == "" || req.To == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Both 'from' and 'to' currency codes are required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Both 'from' and 'to' currency codes are required"))
 		return
 	}
 
-	rate, err := h.marketDataService.GetExchangeRate(req.From, req.To)
+	rate, err := h.marketDataService.GetExchangeRate(c.Request.Context(), req.From, req.To)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve exchange rate: " + err.Error(),
-			Code:  "EXCHANGE_RATE_FETCH_FAILED",
-		})
+		if apierrors.RespondContextDone(c, err) {
+			return
+		}
+
+		if h.respondQuotaExceeded(c, err) {
+			return
+		}
+		if e, ok := apierrors.Lookup(err); ok {
+			apierrors.Respond(c, e)
+			return
+		}
+		apierrors.Respond(c, apierrors.ExchangeRateFetchFailed.WithMessage("Failed to retrieve exchange rate: "+err.Error()))
 		return
 	}
 
@@ -176,8 +194,35 @@ func (h *MarketDataHandler) GetExchangeRate(c *gin.Context) {
 // ClearCache clears the market data cache
 // POST /api/v1/market/cache/clear
 func (h *MarketDataHandler) ClearCache(c *gin.Context) {
-	h.marketDataService.ClearCache()
+	h.marketDataService.ClearCache(c.Request.Context())
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Market data cache cleared successfully",
 	})
 }
+
+// GetQuota returns the remaining request budget for the market data provider
+// GET /api/v1/market/quota
+func (h *MarketDataHandler) GetQuota(c *gin.Context) {
+	c.JSON(http.StatusOK, h.marketDataService.GetQuotaStatus())
+}
+
+// respondQuotaExceeded writes a 429 response if err is a provider quota error
+func (h *MarketDataHandler) respondQuotaExceeded(c *gin.Context, err error) bool {
+	if !errors.Is(err, models.ErrQuotaExceeded) {
+		return false
+	}
+
+	status := h.marketDataService.GetQuotaStatus()
+	if status != nil {
+		retryAt := status.MinuteResetAt
+		if status.DailyLimit > 0 && status.DailyRemaining == 0 {
+			retryAt = status.DailyResetAt
+		}
+		if seconds := int(time.Until(retryAt).Seconds()) + 1; seconds > 0 {
+			c.Header("Retry-After", strconv.Itoa(seconds))
+		}
+	}
+
+	apierrors.Respond(c, apierrors.QuotaExceeded)
+	return true
+}
@@ -2,17 +2,23 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lenon/portfolios/internal/services"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
 )
 
 // MockMarketDataService is a mock implementation
@@ -20,7 +26,7 @@ type MockMarketDataService struct {
 	mock.Mock
 }
 
-func (m *MockMarketDataService) GetQuote(symbol string) (*services.Quote, error) {
+func (m *MockMarketDataService) GetQuote(ctx context.Context, symbol string) (*services.Quote, error) {
 	args := m.Called(symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -28,7 +34,7 @@ func (m *MockMarketDataService) GetQuote(symbol string) (*services.Quote, error)
 	return args.Get(0).(*services.Quote), args.Error(1)
 }
 
-func (m *MockMarketDataService) GetQuotes(symbols []string) (map[string]*services.Quote, error) {
+func (m *MockMarketDataService) GetQuotes(ctx context.Context, symbols []string) (map[string]*services.Quote, error) {
 	args := m.Called(symbols)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,7 +42,7 @@ func (m *MockMarketDataService) GetQuotes(symbols []string) (map[string]*service
 	return args.Get(0).(map[string]*services.Quote), args.Error(1)
 }
 
-func (m *MockMarketDataService) GetHistoricalPrices(symbol string, startDate, endDate time.Time) ([]*services.HistoricalPrice, error) {
+func (m *MockMarketDataService) GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*services.HistoricalPrice, error) {
 	args := m.Called(symbol, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,20 +50,68 @@ func (m *MockMarketDataService) GetHistoricalPrices(symbol string, startDate, en
 	return args.Get(0).([]*services.HistoricalPrice), args.Error(1)
 }
 
-func (m *MockMarketDataService) GetExchangeRate(fromCurrency, toCurrency string) (decimal.Decimal, error) {
+func (m *MockMarketDataService) GetPriceHistory(ctx context.Context, symbol string, resolution models.PriceResolution, startDate, endDate time.Time) ([]*services.HistoricalPrice, error) {
+	args := m.Called(symbol, resolution, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*services.HistoricalPrice), args.Error(1)
+}
+
+func (m *MockMarketDataService) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error) {
 	args := m.Called(fromCurrency, toCurrency)
 	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
 
-func (m *MockMarketDataService) RefreshCache(symbol string) error {
+func (m *MockMarketDataService) RefreshCache(ctx context.Context, symbol string) error {
 	args := m.Called(symbol)
 	return args.Error(0)
 }
 
-func (m *MockMarketDataService) ClearCache() {
+func (m *MockMarketDataService) ClearCache(ctx context.Context) {
 	m.Called()
 }
 
+func (m *MockMarketDataService) GetQuotaStatus() *services.QuotaStatus {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*services.QuotaStatus)
+}
+
+func (m *MockMarketDataService) SearchSymbols(ctx context.Context, keywords string) ([]*models.Security, error) {
+	args := m.Called(keywords)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Security), args.Error(1)
+}
+
+func (m *MockMarketDataService) RefreshTradingStatuses(ctx context.Context, symbols []string) (map[string]*dto.SymbolTradingStatus, error) {
+	args := m.Called(symbols)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*dto.SymbolTradingStatus), args.Error(1)
+}
+
+func (m *MockMarketDataService) GetQuoteFetchTimes(ctx context.Context, symbols []string) map[string]time.Time {
+	args := m.Called(symbols)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(map[string]time.Time)
+}
+
+func (m *MockMarketDataService) GetTradingRestrictions(ctx context.Context, symbols []string) map[string]*dto.SymbolTradingStatus {
+	args := m.Called(symbols)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(map[string]*dto.SymbolTradingStatus)
+}
+
 func TestNewMarketDataHandler(t *testing.T) {
 	mockService := new(MockMarketDataService)
 	handler := NewMarketDataHandler(mockService)
@@ -88,6 +142,7 @@ func TestGetQuote_Success(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = gin.Params{{Key: "symbol", Value: symbol}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/quote/"+symbol, nil)
 
 	handler.GetQuote(c)
 
@@ -103,6 +158,7 @@ func TestGetQuote_EmptySymbol(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
 	c.Params = gin.Params{{Key: "symbol", Value: ""}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/quote/", nil)
 
 	handler.GetQuote(c)
 
@@ -207,7 +263,7 @@ func TestGetHistoricalPrices_Success(t *testing.T) {
 		},
 	}
 
-	mockService.On("GetHistoricalPrices", symbol, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+	mockService.On("GetPriceHistory", symbol, models.PriceResolution1Day, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
 		Return(expectedPrices, nil)
 
 	w := httptest.NewRecorder()
@@ -221,6 +277,72 @@ func TestGetHistoricalPrices_Success(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestGetHistoricalPrices_Intraday(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMarketDataService)
+	handler := NewMarketDataHandler(mockService)
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2024, 5, 2, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
+	sameTime := func(want time.Time) interface{} {
+		return mock.MatchedBy(func(got time.Time) bool { return got.Equal(want) })
+	}
+	mockService.On("GetPriceHistory", "AAPL", models.PriceResolution5Min, sameTime(start), sameTime(end)).
+		Return([]*services.HistoricalPrice{{Date: start.Add(13*time.Hour + 30*time.Minute), Close: decimal.NewFromInt(170)}}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "symbol", Value: "AAPL"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/history/AAPL?resolution=5m&start_date=2024-05-01&end_date=2024-05-01", nil)
+
+	handler.GetHistoricalPrices(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.HistoricalPricesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "AAPL", response.Symbol)
+	assert.Equal(t, models.PriceResolution5Min, response.Resolution)
+	assert.Len(t, response.Prices, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetHistoricalPrices_InvalidResolution(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMarketDataService)
+	handler := NewMarketDataHandler(mockService)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "symbol", Value: "AAPL"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/history/AAPL?resolution=2h", nil)
+
+	handler.GetHistoricalPrices(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "GetPriceHistory")
+}
+
+func TestGetHistoricalPrices_IntradayRangeTooLong(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMarketDataService)
+	handler := NewMarketDataHandler(mockService)
+
+	mockService.On("GetPriceHistory", "AAPL", models.PriceResolution1Hour, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(nil, models.ErrIntradayRangeTooLong)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "symbol", Value: "AAPL"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/history/AAPL?resolution=1h&start_date=2024-01-01&end_date=2024-05-01", nil)
+
+	handler.GetHistoricalPrices(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "VALIDATION_ERROR", response.Code)
+}
+
 func TestGetHistoricalPrices_EmptySymbol(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockService := new(MockMarketDataService)
@@ -287,3 +409,99 @@ func TestClearCache_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	mockService.AssertExpectations(t)
 }
+
+func TestGetQuote_QuotaExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMarketDataService)
+	handler := NewMarketDataHandler(mockService)
+
+	mockService.On("GetQuote", "AAPL").Return(nil, fmt.Errorf("failed to fetch quote for AAPL: %w", models.ErrQuotaExceeded))
+	mockService.On("GetQuotaStatus").Return(&services.QuotaStatus{
+		Provider:        "alphavantage",
+		DailyLimit:      25,
+		DailyUsed:       10,
+		DailyRemaining:  15,
+		MinuteLimit:     5,
+		MinuteUsed:      5,
+		MinuteRemaining: 0,
+		MinuteResetAt:   time.Now().Add(30 * time.Second),
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "symbol", Value: "AAPL"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/quote/AAPL", nil)
+
+	handler.GetQuote(c)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "QUOTA_EXCEEDED")
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuote_ProviderUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMarketDataService)
+	handler := NewMarketDataHandler(mockService)
+
+	mockService.On("GetQuote", "MSFT").Return(nil, fmt.Errorf("failed to fetch quote for MSFT: %w", models.ErrMarketDataUnavailable))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "symbol", Value: "MSFT"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/quote/MSFT", nil)
+
+	handler.GetQuote(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "MARKET_DATA_UNAVAILABLE")
+	mockService.AssertExpectations(t)
+}
+
+func TestGetQuote_Stale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMarketDataService)
+	handler := NewMarketDataHandler(mockService)
+
+	mockService.On("GetQuote", "AAPL").Return(&services.Quote{Symbol: "AAPL", Price: decimal.NewFromInt(150), Stale: true}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "symbol", Value: "AAPL"}}
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/quote/AAPL", nil)
+
+	handler.GetQuote(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.QuoteResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Stale)
+}
+
+func TestGetQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockMarketDataService)
+	handler := NewMarketDataHandler(mockService)
+
+	mockService.On("GetQuotaStatus").Return(&services.QuotaStatus{
+		Provider:       "alphavantage",
+		DailyLimit:     25,
+		DailyUsed:      3,
+		DailyRemaining: 22,
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/api/v1/market/quota", nil)
+
+	handler.GetQuota(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "alphavantage", response["provider"])
+	assert.Equal(t, float64(22), response["daily_remaining"])
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// NotificationHandler handles the in-app notifications about corporate actions, finished
+// imports and scheduled reports
+type NotificationHandler struct {
+	notificationService services.NotificationService
+}
+
+// NewNotificationHandler creates a new NotificationHandler instance
+func NewNotificationHandler(notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// List handles retrieving the user's most recent notifications
+// GET /api/v1/notifications
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.NotificationListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	notifications, unread, err := h.notificationService.List(c.Request.Context(), userID.(string), req.Unread, req.Limit)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := &dto.NotificationListResponse{
+		Notifications: make([]*dto.NotificationResponse, len(notifications)),
+		UnreadCount:   unread,
+	}
+	for i, notification := range notifications {
+		response.Notifications[i] = dto.ToNotificationResponse(notification)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// MarkRead handles marking a notification read
+// POST /api/v1/notifications/:id/read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	notificationID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(c.Request.Context(), notificationID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToNotificationResponse(notification))
+}
+
+// handleError maps service errors to HTTP responses
+func (h *NotificationHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to retrieve notifications"))
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockNotificationService is a mock implementation of NotificationService
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) Notify(ctx context.Context, notification *models.Notification) error {
+	args := m.Called(notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) List(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*models.Notification, int64, error) {
+	args := m.Called(userID, unreadOnly, limit)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.Notification), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockNotificationService) MarkRead(ctx context.Context, notificationID, userID string) (*models.Notification, error) {
+	args := m.Called(notificationID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Notification), args.Error(1)
+}
+
+func TestNotificationHandler_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	mockService := new(MockNotificationService)
+	handler := NewNotificationHandler(mockService)
+
+	portfolioID := uuid.New()
+	notification := &models.Notification{
+		ID:          uuid.New(),
+		PortfolioID: &portfolioID,
+		Type:        models.NotificationTypeCorporateAction,
+		Title:       "AAPL SPLIT on 2024-06-10 awaits approval",
+		Message:     "Stock split 4 for AAPL",
+		CreatedAt:   time.Now().UTC(),
+	}
+	mockService.On("List", userID, true, 0).Return([]*models.Notification{notification}, int64(3), nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/?unread=true", nil)
+
+	handler.List(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.NotificationListResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, int64(3), response.UnreadCount)
+	require.Len(t, response.Notifications, 1)
+	assert.Equal(t, models.NotificationTypeCorporateAction, response.Notifications[0].Type)
+	assert.Equal(t, portfolioID.String(), response.Notifications[0].PortfolioID)
+	assert.False(t, response.Notifications[0].Read)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationHandler_MarkRead(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockNotificationService)
+		handler := NewNotificationHandler(mockService)
+
+		readAt := time.Now().UTC()
+		notification := &models.Notification{
+			ID:     uuid.New(),
+			Type:   models.NotificationTypeImportCompleted,
+			Title:  "Import finished",
+			ReadAt: &readAt,
+		}
+		mockService.On("MarkRead", notification.ID.String(), userID).Return(notification, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: notification.ID.String()}}
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("POST", "/", nil)
+
+		handler.MarkRead(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response dto.NotificationResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Read)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(MockNotificationService)
+		handler := NewNotificationHandler(mockService)
+		mockService.On("MarkRead", "missing", userID).Return(nil, models.ErrNotificationNotFound)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: "missing"}}
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("POST", "/", nil)
+
+		handler.MarkRead(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "NOTIFICATION_NOT_FOUND", response.Code)
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// OpeningLotImportHandler handles importing the lots a portfolio held before its recorded history
+type OpeningLotImportHandler struct {
+	openingLotImportService services.OpeningLotImportService
+}
+
+// NewOpeningLotImportHandler creates a new OpeningLotImportHandler instance
+func NewOpeningLotImportHandler(openingLotImportService services.OpeningLotImportService) *OpeningLotImportHandler {
+	return &OpeningLotImportHandler{
+		openingLotImportService: openingLotImportService,
+	}
+}
+
+// Import handles importing a CSV file of opening lots, each recorded as an opening balance
+// transaction, and rebuilding the portfolio's holdings and tax lots from them
+// POST /api/v1/portfolios/:id/transactions/import/lots
+func (h *OpeningLotImportHandler) Import(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.OpeningLotImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request body", err)
+		return
+	}
+
+	result, err := h.openingLotImportService.Import(c.Request.Context(), portfolioID, userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	statusCode := http.StatusOK
+	if !result.Result.Success {
+		statusCode = http.StatusBadRequest
+	}
+
+	c.JSON(statusCode, result)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *OpeningLotImportHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.ImportFailed.WithMessage("Failed to import opening lots"))
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockOpeningLotImportService is a mock implementation of OpeningLotImportService
+type MockOpeningLotImportService struct {
+	mock.Mock
+}
+
+func (m *MockOpeningLotImportService) Import(ctx context.Context, portfolioID, userID string, req dto.OpeningLotImportRequest) (*dto.OpeningLotImportResult, error) {
+	args := m.Called(portfolioID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.OpeningLotImportResult), args.Error(1)
+}
+
+func performOpeningLotImport(handler *OpeningLotImportHandler, portfolioID, userID string, body interface{}) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolioID+"/transactions/import/lots", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Import(c)
+	return w
+}
+
+func TestOpeningLotImportHandler_Import(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("imported", func(t *testing.T) {
+		mockService := new(MockOpeningLotImportService)
+		handler := NewOpeningLotImportHandler(mockService)
+		portfolioID := uuid.New().String()
+		userID := uuid.New().String()
+
+		req := dto.OpeningLotImportRequest{CSVData: "Symbol,Quantity,Cost Basis,Purchase Date\nAAPL,100,5000,2019-03-01\n"}
+		mockService.On("Import", portfolioID, userID, req).Return(&dto.OpeningLotImportResult{
+			Result:  &dto.ImportResult{Success: true, TotalRows: 1, SuccessCount: 1},
+			TaxLots: 1,
+		}, nil)
+
+		w := performOpeningLotImport(handler, portfolioID, userID, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response dto.OpeningLotImportResult
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 1, response.TaxLots)
+		assert.Equal(t, 1, response.Result.SuccessCount)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid lots", func(t *testing.T) {
+		mockService := new(MockOpeningLotImportService)
+		handler := NewOpeningLotImportHandler(mockService)
+		portfolioID := uuid.New().String()
+		userID := uuid.New().String()
+
+		req := dto.OpeningLotImportRequest{CSVData: "Symbol,Quantity,Cost Basis,Purchase Date\nAAPL,100,-1,2019-03-01\n"}
+		mockService.On("Import", portfolioID, userID, req).Return(&dto.OpeningLotImportResult{
+			Result: &dto.ImportResult{Success: false, TotalRows: 1, ErrorCount: 1},
+		}, nil)
+
+		w := performOpeningLotImport(handler, portfolioID, userID, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestOpeningLotImportHandler_Import_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"invalid file", fmt.Errorf("%w: missing required column: cost_basis", models.ErrInvalidImportFile), http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"unexpected", fmt.Errorf("database is down"), http.StatusInternalServerError, "IMPORT_FAILED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOpeningLotImportService)
+			handler := NewOpeningLotImportHandler(mockService)
+			portfolioID := uuid.New().String()
+			userID := uuid.New().String()
+
+			mockService.On("Import", portfolioID, userID, mock.Anything).Return(nil, tt.err)
+
+			w := performOpeningLotImport(handler, portfolioID, userID, dto.OpeningLotImportRequest{CSVData: "x"})
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+
+	t.Run("missing data", func(t *testing.T) {
+		handler := NewOpeningLotImportHandler(new(MockOpeningLotImportService))
+
+		w := performOpeningLotImport(handler, uuid.New().String(), uuid.New().String(), map[string]string{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response dto.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_REQUEST", response.Code)
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// OptionHandler handles option contract HTTP requests
+type OptionHandler struct {
+	optionService services.OptionService
+}
+
+// NewOptionHandler creates a new OptionHandler instance
+func NewOptionHandler(optionService services.OptionService) *OptionHandler {
+	return &OptionHandler{
+		optionService: optionService,
+	}
+}
+
+// Trade handles buying contracts to open a position or selling them to close it
+// POST /api/v1/portfolios/:id/options/trades
+func (h *OptionHandler) Trade(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.OptionTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	transaction, err := h.optionService.Trade(c.Request.Context(), portfolioID, userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToTransactionResponse(transaction))
+}
+
+// List handles listing the option positions in a portfolio
+// GET /api/v1/portfolios/:id/options
+func (h *OptionHandler) List(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	positions, err := h.optionService.ListPositions(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, positions)
+}
+
+// Settle handles closing an option position by expiration or assignment
+// POST /api/v1/portfolios/:id/options/:contract_id/settle
+func (h *OptionHandler) Settle(c *gin.Context) {
+	portfolioID := c.Param("id")
+	contractID := c.Param("contract_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.SettleOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	transactions, err := h.optionService.Settle(c.Request.Context(), portfolioID, contractID, userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := &dto.OptionSettlementResponse{
+		Action:       req.Action,
+		Transactions: make([]*dto.TransactionResponse, len(transactions)),
+	}
+	for i, transaction := range transactions {
+		response.Transactions[i] = dto.ToTransactionResponse(transaction)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *OptionHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process option request"))
+}
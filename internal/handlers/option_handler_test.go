@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockOptionService is a mock implementation of OptionService
+type MockOptionService struct {
+	mock.Mock
+}
+
+func (m *MockOptionService) Trade(ctx context.Context, portfolioID, userID string, req *dto.OptionTradeRequest) (*models.Transaction, error) {
+	args := m.Called(portfolioID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockOptionService) ListPositions(ctx context.Context, portfolioID, userID string) (*dto.OptionPositionListResponse, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.OptionPositionListResponse), args.Error(1)
+}
+
+func (m *MockOptionService) Settle(ctx context.Context, portfolioID, contractID, userID string, req *dto.SettleOptionRequest) ([]*models.Transaction, error) {
+	args := m.Called(portfolioID, contractID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockOptionService) SettleExpired(ctx context.Context, asOf time.Time) (int, error) {
+	args := m.Called(asOf)
+	return args.Int(0), args.Error(1)
+}
+
+func TestOptionHandler_Trade(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New().String()
+	portfolioID := uuid.New().String()
+	price := decimal.RequireFromString("5.5")
+	transaction := &models.Transaction{
+		ID:         uuid.New(),
+		Type:       models.TransactionTypeBuyToOpen,
+		Symbol:     "AAPL240621C00190000",
+		AssetType:  models.AssetTypeOption,
+		Quantity:   decimal.NewFromInt(2),
+		Price:      &price,
+		Multiplier: 100,
+	}
+
+	mockService := new(MockOptionService)
+	handler := NewOptionHandler(mockService)
+	mockService.On("Trade", portfolioID, userID, mock.MatchedBy(func(req *dto.OptionTradeRequest) bool {
+		return req.Symbol == "AAPL240621C00190000" && req.Quantity.Equal(decimal.NewFromInt(2))
+	})).Return(transaction, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(
+		`{"type":"BUY_TO_OPEN","symbol":"AAPL240621C00190000","date":"2024-05-01T00:00:00Z","quantity":"2","price":"5.5"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Trade(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response dto.TransactionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, transaction.ID, response.ID)
+	assert.Equal(t, 100, response.Multiplier)
+	mockService.AssertExpectations(t)
+
+	t.Run("rejects stock transaction types", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("POST", "/", strings.NewReader(
+			`{"type":"BUY","symbol":"AAPL240621C00190000","date":"2024-05-01T00:00:00Z","quantity":"2","price":"5.5"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.Trade(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestOptionHandler_Settle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New().String()
+	portfolioID := uuid.New().String()
+	contractID := uuid.New().String()
+	transactions := []*models.Transaction{
+		{ID: uuid.New(), Type: models.TransactionTypeOptionAssignment, Symbol: "AAPL240621C00190000"},
+		{ID: uuid.New(), Type: models.TransactionTypeBuy, Symbol: "AAPL"},
+	}
+
+	mockService := new(MockOptionService)
+	handler := NewOptionHandler(mockService)
+	mockService.On("Settle", portfolioID, contractID, userID, &dto.SettleOptionRequest{
+		Action: dto.OptionSettlementAssign,
+	}).Return(transactions, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}, {Key: "contract_id", Value: contractID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"action":"ASSIGN"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Settle(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.OptionSettlementResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.OptionSettlementAssign, response.Action)
+	require.Len(t, response.Transactions, 2)
+	assert.Equal(t, "AAPL", response.Transactions[1].Symbol)
+	mockService.AssertExpectations(t)
+}
+
+func TestOptionHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"contract not found", models.ErrOptionContractNotFound, http.StatusNotFound, "OPTION_CONTRACT_NOT_FOUND"},
+		{"no position", models.ErrNoOptionPosition, http.StatusNotFound, "NO_OPTION_POSITION"},
+		{"not expired", models.ErrOptionNotExpired, http.StatusUnprocessableEntity, "OPTION_NOT_EXPIRED"},
+		{"wrapped insufficient shares", fmt.Errorf("failed to update holdings: %w", models.ErrInsufficientShares), http.StatusUnprocessableEntity, "INSUFFICIENT_SHARES"},
+		{"invalid contract", models.ErrInvalidOptionContract, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"unexpected", fmt.Errorf("database is down"), http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOptionService)
+			handler := NewOptionHandler(mockService)
+			mockService.On("ListPositions", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+			c.Set(middleware.UserIDContextKey, uuid.New().String())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+
+			handler.List(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// OrganizationHandler handles requests to manage the organizations the current user belongs
+// to: their members, invitations and API keys
+type OrganizationHandler struct {
+	organizationService services.OrganizationService
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler instance
+func NewOrganizationHandler(organizationService services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+	}
+}
+
+// Create handles creating an organization owned by the current user
+// POST /api/v1/orgs
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	var req dto.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	organization, err := h.organizationService.Create(c.Request.Context(), middleware.GetUserID(c), req.Name)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToOrganizationResponse(organization))
+}
+
+// ListMemberships handles listing the organizations the current user belongs to
+// GET /api/v1/orgs
+func (h *OrganizationHandler) ListMemberships(c *gin.Context) {
+	memberships, err := h.organizationService.ListMemberships(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.MembershipResponse, len(memberships))
+	for i, membership := range memberships {
+		response[i] = dto.ToMembershipResponse(membership)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListMembers handles listing an organization's members
+// GET /api/v1/orgs/:org_id/members
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	members, err := h.organizationService.ListMembers(c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.MemberResponse, len(members))
+	for i, member := range members {
+		response[i] = dto.ToMemberResponse(member)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetMemberRole handles changing a member's role
+// PUT /api/v1/orgs/:org_id/members/:user_id
+func (h *OrganizationHandler) SetMemberRole(c *gin.Context) {
+	var req dto.SetMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	member, err := h.organizationService.SetMemberRole(
+		c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c), c.Param("user_id"), req.Role,
+	)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToMemberResponse(member))
+}
+
+// RemoveMember handles removing a member from an organization, or leaving it
+// DELETE /api/v1/orgs/:org_id/members/:user_id
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	err := h.organizationService.RemoveMember(c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c), c.Param("user_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Invite handles inviting someone to an organization. The invitation token is returned so
+// that it can be passed on if the email could not be delivered.
+// POST /api/v1/orgs/:org_id/invitations
+func (h *OrganizationHandler) Invite(c *gin.Context) {
+	var req dto.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	issued, err := h.organizationService.Invite(c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c), req.Email, req.Role)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dto.ToInvitationResponse(issued.Invitation)
+	response.Token = issued.Token
+	response.EmailSent = &issued.EmailSent
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListInvitations handles listing an organization's pending invitations
+// GET /api/v1/orgs/:org_id/invitations
+func (h *OrganizationHandler) ListInvitations(c *gin.Context) {
+	invitations, err := h.organizationService.ListInvitations(c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		response[i] = dto.ToInvitationResponse(invitation)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeInvitation handles revoking a pending invitation
+// DELETE /api/v1/orgs/:org_id/invitations/:invitation_id
+func (h *OrganizationHandler) RevokeInvitation(c *gin.Context) {
+	err := h.organizationService.RevokeInvitation(
+		c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c), c.Param("invitation_id"),
+	)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AcceptInvitation handles the current user accepting an invitation
+// POST /api/v1/orgs/invitations/accept
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	var req dto.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	member, err := h.organizationService.AcceptInvitation(c.Request.Context(), middleware.GetUserID(c), req.Token)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToMembershipResponse(member))
+}
+
+// ListAPIKeys handles listing an organization's API keys
+// GET /api/v1/orgs/:org_id/api-keys
+func (h *OrganizationHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.organizationService.ListAPIKeys(c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.OrganizationAPIKeyResponse, len(keys))
+	for i, key := range keys {
+		response[i] = dto.ToOrganizationAPIKeyResponse(key)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// CreateAPIKey handles issuing an API key acting as the current user in an organization. The
+// plaintext key is only returned by this request.
+// POST /api/v1/orgs/:org_id/api-keys
+func (h *OrganizationHandler) CreateAPIKey(c *gin.Context) {
+	var req dto.CreateOrganizationAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	issued, err := h.organizationService.CreateAPIKey(c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c), req.Name)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := dto.ToOrganizationAPIKeyResponse(issued.Key)
+	response.Key = issued.Secret
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RevokeAPIKey handles revoking an organization API key
+// DELETE /api/v1/orgs/:org_id/api-keys/:key_id
+func (h *OrganizationHandler) RevokeAPIKey(c *gin.Context) {
+	err := h.organizationService.RevokeAPIKey(c.Request.Context(), c.Param("org_id"), middleware.GetUserID(c), c.Param("key_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleError maps organization errors to HTTP responses
+func (h *OrganizationHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process organization request"))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// PasswordHandler handles signed-in users changing their password
+type PasswordHandler struct {
+	passwordService services.PasswordService
+}
+
+// NewPasswordHandler creates a new PasswordHandler instance
+func NewPasswordHandler(passwordService services.PasswordService) *PasswordHandler {
+	return &PasswordHandler{
+		passwordService: passwordService,
+	}
+}
+
+// ChangePassword replaces the current user's password, given their current one
+// POST /api/auth/change-password
+func (h *PasswordHandler) ChangePassword(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		apierrors.Respond(c, apierrors.NotAuthenticated)
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	if err := h.passwordService.ChangePassword(userID, req.CurrentPassword, req.NewPassword); err != nil {
+		apierrors.RespondError(c, err, apierrors.ChangePasswordFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.MessageResponse{
+		Message: "Password changed successfully",
+	})
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockPasswordService is a mock implementation of PasswordService
+type MockPasswordService struct {
+	mock.Mock
+}
+
+func (m *MockPasswordService) ChangePassword(userID, currentPassword, newPassword string) error {
+	args := m.Called(userID, currentPassword, newPassword)
+	return args.Error(0)
+}
+
+func (m *MockPasswordService) SetPassword(userID, newPassword string) error {
+	args := m.Called(userID, newPassword)
+	return args.Error(0)
+}
+
+func TestPasswordHandler_ChangePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+	body := `{"current_password":"Original123","new_password":"Changed123"}`
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"changed", nil, http.StatusOK, ""},
+		{"incorrect current password", models.ErrIncorrectPassword, http.StatusBadRequest, "INVALID_CURRENT_PASSWORD"},
+		{"policy violation", fmt.Errorf("%w: %w", models.ErrInvalidPassword, errors.New("password is too common")), http.StatusBadRequest, "INVALID_PASSWORD"},
+		{"reused", models.ErrPasswordReused, http.StatusBadRequest, "PASSWORD_REUSED"},
+		{"failure", errors.New("database unavailable"), http.StatusInternalServerError, "CHANGE_PASSWORD_FAILED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPasswordService)
+			handler := NewPasswordHandler(mockService)
+			mockService.On("ChangePassword", userID, "Original123", "Changed123").Return(tt.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set(middleware.UserIDContextKey, userID)
+			c.Request = httptest.NewRequest("POST", "/api/auth/change-password", strings.NewReader(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.ChangePassword(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var response dto.ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantCode, response.Code)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("requires the current password", func(t *testing.T) {
+		handler := NewPasswordHandler(new(MockPasswordService))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("POST", "/api/auth/change-password", strings.NewReader(`{"new_password":"Changed123"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.ChangePassword(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
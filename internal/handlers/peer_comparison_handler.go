@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// PeerComparisonHandler handles peer percentile comparison HTTP requests
+type PeerComparisonHandler struct {
+	peerComparisonService services.PeerComparisonService
+}
+
+// NewPeerComparisonHandler creates a new PeerComparisonHandler instance
+func NewPeerComparisonHandler(peerComparisonService services.PeerComparisonService) *PeerComparisonHandler {
+	return &PeerComparisonHandler{
+		peerComparisonService: peerComparisonService,
+	}
+}
+
+// SetOptIn handles opting a portfolio in or out of anonymized peer comparison
+// PUT /api/v1/portfolios/:id/peer-comparison/opt-in
+func (h *PeerComparisonHandler) SetOptIn(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.PeerComparisonOptInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	portfolio, err := h.peerComparisonService.SetOptIn(c.Request.Context(), portfolioID, userID.(string), *req.OptIn)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PeerComparisonOptInResponse{
+		PortfolioID: portfolio.ID.String(),
+		OptIn:       portfolio.PeerComparisonOptIn,
+	})
+}
+
+// Get handles retrieving where a portfolio's risk-adjusted return falls among its peers
+// GET /api/v1/portfolios/:id/peer-comparison
+func (h *PeerComparisonHandler) Get(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	result, err := h.peerComparisonService.GetComparison(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *PeerComparisonHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process peer comparison request"))
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockPeerComparisonService is a mock implementation of PeerComparisonService
+type MockPeerComparisonService struct {
+	mock.Mock
+}
+
+func (m *MockPeerComparisonService) SetOptIn(ctx context.Context, portfolioID, userID string, optIn bool) (*models.Portfolio, error) {
+	args := m.Called(portfolioID, userID, optIn)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Portfolio), args.Error(1)
+}
+
+func (m *MockPeerComparisonService) GetComparison(ctx context.Context, portfolioID, userID string) (*services.PeerComparisonResult, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PeerComparisonResult), args.Error(1)
+}
+
+func (m *MockPeerComparisonService) AggregateBenchmarks(ctx context.Context) (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
+func TestPeerComparisonHandler_SetOptIn(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPeerComparisonService)
+	handler := NewPeerComparisonHandler(mockService)
+
+	portfolioID := uuid.New()
+	userID := uuid.New().String()
+
+	mockService.On("SetOptIn", portfolioID.String(), userID, false).
+		Return(&models.Portfolio{ID: portfolioID, PeerComparisonOptIn: false}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID.String()}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(`{"opt_in": false}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.SetOptIn(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.PeerComparisonOptInResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, portfolioID.String(), response.PortfolioID)
+	assert.False(t, response.OptIn)
+	mockService.AssertExpectations(t)
+}
+
+func TestPeerComparisonHandler_SetOptIn_MissingField(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewPeerComparisonHandler(new(MockPeerComparisonService))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+	c.Set(middleware.UserIDContextKey, uuid.New().String())
+	c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.SetOptIn(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPeerComparisonHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPeerComparisonService)
+	handler := NewPeerComparisonHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("GetComparison", portfolioID, userID).Return(&services.PeerComparisonResult{
+		PortfolioID:        portfolioID,
+		Bucket:             models.AllocationBucketFocused,
+		LookbackDays:       models.PeerBenchmarkLookbackDays,
+		RiskAdjustedReturn: decimal.NewFromFloat(1.2),
+		Percentile:         72,
+		PeerCount:          14,
+		ComputedAt:         time.Now().UTC(),
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	handler.Get(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response dto.PeerComparisonResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 72, response.Percentile)
+	assert.Equal(t, models.AllocationBucketFocused, response.Bucket)
+	mockService.AssertExpectations(t)
+}
+
+func TestPeerComparisonHandler_Get_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"not opted in", models.ErrPeerComparisonNotOptedIn, http.StatusForbidden, "NOT_OPTED_IN"},
+		{"no benchmark", models.ErrPeerBenchmarkNotFound, http.StatusNotFound, "BENCHMARK_UNAVAILABLE"},
+		{"insufficient data", models.ErrInsufficientPeerComparisonData, http.StatusUnprocessableEntity, "INSUFFICIENT_DATA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPeerComparisonService)
+			handler := NewPeerComparisonHandler(mockService)
+			mockService.On("GetComparison", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+			c.Set(middleware.UserIDContextKey, uuid.New().String())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+
+			handler.Get(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestPeerComparisonHandler_Get_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewPeerComparisonHandler(new(MockPeerComparisonService))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	handler.Get(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
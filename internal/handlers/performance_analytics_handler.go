@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
 	"github.com/lenon/portfolios/internal/models"
@@ -31,20 +33,34 @@ func (h *PerformanceAnalyticsHandler) GetPerformanceMetrics(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Parse query parameters for date range
 	var req dto.PerformanceMetricsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid query parameters: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Standard periods are served from the materialized metrics
+	if req.Period != "" {
+		period, err := models.ParsePerformancePeriod(req.Period)
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+
+		metrics, err := h.analyticsService.GetPeriodMetrics(c.Request.Context(), portfolioID, userID.(string), period)
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+
+		response := dto.ToPerformanceMetricsResponse(metrics)
+		response.Period = string(period)
+		c.JSON(http.StatusOK, response)
 		return
 	}
 
@@ -59,7 +75,7 @@ func (h *PerformanceAnalyticsHandler) GetPerformanceMetrics(c *gin.Context) {
 	}
 
 	// Get performance metrics
-	metrics, err := h.analyticsService.GetPerformanceMetrics(
+	metrics, err := h.analyticsService.GetPerformanceMetrics(c.Request.Context(),
 		portfolioID,
 		userID.(string),
 		startDate,
@@ -73,6 +89,28 @@ func (h *PerformanceAnalyticsHandler) GetPerformanceMetrics(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.ToPerformanceMetricsResponse(metrics))
 }
 
+// GetPeriodMetrics retrieves the metrics of every standard period the portfolio's snapshots
+// cover
+// GET /api/v1/portfolios/:id/performance/periods
+func (h *PerformanceAnalyticsHandler) GetPeriodMetrics(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	// Get user ID from context
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	metrics, err := h.analyticsService.GetStandardPeriodMetrics(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPeriodMetricsResponse(metrics))
+}
+
 // GetTWR calculates Time-Weighted Return
 // GET /api/v1/portfolios/:id/performance/twr
 func (h *PerformanceAnalyticsHandler) GetTWR(c *gin.Context) {
@@ -81,20 +119,14 @@ func (h *PerformanceAnalyticsHandler) GetTWR(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Parse query parameters
 	var req dto.PerformanceMetricsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid query parameters: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
 		return
 	}
 
@@ -109,7 +141,7 @@ func (h *PerformanceAnalyticsHandler) GetTWR(c *gin.Context) {
 	}
 
 	// Calculate TWR
-	twr, err := h.analyticsService.CalculateTWR(
+	twr, err := h.analyticsService.CalculateTWR(c.Request.Context(),
 		portfolioID,
 		userID.(string),
 		startDate,
@@ -131,20 +163,14 @@ func (h *PerformanceAnalyticsHandler) GetMWR(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Parse query parameters
 	var req dto.PerformanceMetricsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid query parameters: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
 		return
 	}
 
@@ -159,7 +185,7 @@ func (h *PerformanceAnalyticsHandler) GetMWR(c *gin.Context) {
 	}
 
 	// Calculate MWR
-	mwr, err := h.analyticsService.CalculateMWR(
+	mwr, err := h.analyticsService.CalculateMWR(c.Request.Context(),
 		portfolioID,
 		userID.(string),
 		startDate,
@@ -181,20 +207,14 @@ func (h *PerformanceAnalyticsHandler) GetBenchmarkComparison(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Parse query parameters
 	var req dto.BenchmarkComparisonRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid query parameters: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
 		return
 	}
 
@@ -214,7 +234,7 @@ func (h *PerformanceAnalyticsHandler) GetBenchmarkComparison(c *gin.Context) {
 	}
 
 	// Compare to benchmark
-	comparison, err := h.analyticsService.CompareToBenchmark(
+	comparison, err := h.analyticsService.CompareToBenchmark(c.Request.Context(),
 		portfolioID,
 		userID.(string),
 		req.BenchmarkSymbol,
@@ -237,20 +257,14 @@ func (h *PerformanceAnalyticsHandler) GetAnnualizedReturn(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Parse query parameters
 	var req dto.PerformanceMetricsRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid query parameters: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
 		return
 	}
 
@@ -265,7 +279,7 @@ func (h *PerformanceAnalyticsHandler) GetAnnualizedReturn(c *gin.Context) {
 	}
 
 	// Calculate annualized return
-	annualizedReturn, err := h.analyticsService.CalculateAnnualizedReturn(
+	annualizedReturn, err := h.analyticsService.CalculateAnnualizedReturn(c.Request.Context(),
 		portfolioID,
 		userID.(string),
 		startDate,
@@ -281,26 +295,5 @@ func (h *PerformanceAnalyticsHandler) GetAnnualizedReturn(c *gin.Context) {
 
 // handleError handles errors and returns appropriate HTTP responses
 func (h *PerformanceAnalyticsHandler) handleError(c *gin.Context, err error) {
-	switch err {
-	case models.ErrPortfolioNotFound:
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Portfolio not found",
-			Code:  "PORTFOLIO_NOT_FOUND",
-		})
-	case models.ErrUnauthorizedAccess:
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "You don't have access to this portfolio",
-			Code:  "UNAUTHORIZED_ACCESS",
-		})
-	case models.ErrPerformanceSnapshotNotFound:
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Performance data not found for this period",
-			Code:  "SNAPSHOT_NOT_FOUND",
-		})
-	default:
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "INTERNAL_ERROR",
-		})
-	}
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to calculate performance"))
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -8,12 +9,14 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lenon/portfolios/internal/middleware"
-	"github.com/lenon/portfolios/internal/models"
-	"github.com/lenon/portfolios/internal/services"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
 )
 
 // MockPerformanceAnalyticsService is a mock implementation
@@ -21,7 +24,7 @@ type MockPerformanceAnalyticsService struct {
 	mock.Mock
 }
 
-func (m *MockPerformanceAnalyticsService) CalculateTWR(portfolioID, userID string, startDate, endDate time.Time) (*services.TWRResult, error) {
+func (m *MockPerformanceAnalyticsService) CalculateTWR(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*services.TWRResult, error) {
 	args := m.Called(portfolioID, userID, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -29,7 +32,7 @@ func (m *MockPerformanceAnalyticsService) CalculateTWR(portfolioID, userID strin
 	return args.Get(0).(*services.TWRResult), args.Error(1)
 }
 
-func (m *MockPerformanceAnalyticsService) CalculateMWR(portfolioID, userID string, startDate, endDate time.Time) (*services.MWRResult, error) {
+func (m *MockPerformanceAnalyticsService) CalculateMWR(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*services.MWRResult, error) {
 	args := m.Called(portfolioID, userID, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,7 +40,7 @@ func (m *MockPerformanceAnalyticsService) CalculateMWR(portfolioID, userID strin
 	return args.Get(0).(*services.MWRResult), args.Error(1)
 }
 
-func (m *MockPerformanceAnalyticsService) CalculateAnnualizedReturn(portfolioID, userID string, startDate, endDate time.Time) (*services.AnnualizedReturnResult, error) {
+func (m *MockPerformanceAnalyticsService) CalculateAnnualizedReturn(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*services.AnnualizedReturnResult, error) {
 	args := m.Called(portfolioID, userID, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -45,7 +48,7 @@ func (m *MockPerformanceAnalyticsService) CalculateAnnualizedReturn(portfolioID,
 	return args.Get(0).(*services.AnnualizedReturnResult), args.Error(1)
 }
 
-func (m *MockPerformanceAnalyticsService) CompareToBenchmark(portfolioID, userID, benchmarkSymbol string, startDate, endDate time.Time) (*services.BenchmarkComparisonResult, error) {
+func (m *MockPerformanceAnalyticsService) CompareToBenchmark(ctx context.Context, portfolioID, userID, benchmarkSymbol string, startDate, endDate time.Time) (*services.BenchmarkComparisonResult, error) {
 	args := m.Called(portfolioID, userID, benchmarkSymbol, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -53,7 +56,15 @@ func (m *MockPerformanceAnalyticsService) CompareToBenchmark(portfolioID, userID
 	return args.Get(0).(*services.BenchmarkComparisonResult), args.Error(1)
 }
 
-func (m *MockPerformanceAnalyticsService) GetPerformanceMetrics(portfolioID, userID string, startDate, endDate time.Time) (*services.PerformanceMetrics, error) {
+func (m *MockPerformanceAnalyticsService) CompareReturnToBenchmark(ctx context.Context, portfolioReturn *services.AnnualizedReturnResult, benchmarkSymbol string) (*services.BenchmarkComparisonResult, error) {
+	args := m.Called(portfolioReturn, benchmarkSymbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.BenchmarkComparisonResult), args.Error(1)
+}
+
+func (m *MockPerformanceAnalyticsService) GetPerformanceMetrics(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*services.PerformanceMetrics, error) {
 	args := m.Called(portfolioID, userID, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -61,6 +72,27 @@ func (m *MockPerformanceAnalyticsService) GetPerformanceMetrics(portfolioID, use
 	return args.Get(0).(*services.PerformanceMetrics), args.Error(1)
 }
 
+func (m *MockPerformanceAnalyticsService) GetPeriodMetrics(ctx context.Context, portfolioID, userID string, period models.PerformancePeriod) (*services.PerformanceMetrics, error) {
+	args := m.Called(portfolioID, userID, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PerformanceMetrics), args.Error(1)
+}
+
+func (m *MockPerformanceAnalyticsService) GetStandardPeriodMetrics(ctx context.Context, portfolioID, userID string) (map[models.PerformancePeriod]*services.PerformanceMetrics, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[models.PerformancePeriod]*services.PerformanceMetrics), args.Error(1)
+}
+
+func (m *MockPerformanceAnalyticsService) MaterializePeriodMetrics(ctx context.Context, portfolioID string) (int, error) {
+	args := m.Called(portfolioID)
+	return args.Int(0), args.Error(1)
+}
+
 func TestNewPerformanceAnalyticsHandler(t *testing.T) {
 	mockService := new(MockPerformanceAnalyticsService)
 	handler := NewPerformanceAnalyticsHandler(mockService)
@@ -146,6 +178,81 @@ func TestGetPerformanceMetrics_PortfolioNotFound(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestGetPerformanceMetrics_Period(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPerformanceAnalyticsService)
+	handler := NewPerformanceAnalyticsHandler(mockService)
+
+	portfolioID := "test-portfolio-id"
+	userID := "test-user-id"
+	mockService.On("GetPeriodMetrics", portfolioID, userID, models.PerformancePeriodYTD).
+		Return(&services.PerformanceMetrics{TotalReturnPct: decimal.NewFromInt(7)}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolioID+"/performance/metrics?period=ytd", nil)
+
+	handler.GetPerformanceMetrics(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "YTD", response["period"])
+	assert.Equal(t, "7", response["total_return_pct"])
+	mockService.AssertExpectations(t)
+
+	t.Run("unknown period", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolioID+"/performance/metrics?period=5Y", nil)
+
+		handler.GetPerformanceMetrics(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "1M, 3M, YTD, 1Y, 3Y or ALL")
+	})
+}
+
+func TestGetPeriodMetrics_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPerformanceAnalyticsService)
+	handler := NewPerformanceAnalyticsHandler(mockService)
+
+	portfolioID := "test-portfolio-id"
+	userID := "test-user-id"
+	mockService.On("GetStandardPeriodMetrics", portfolioID, userID).Return(map[models.PerformancePeriod]*services.PerformanceMetrics{
+		models.PerformancePeriodAll: {TotalReturnPct: decimal.NewFromInt(40)},
+		models.PerformancePeriod1M:  {TotalReturnPct: decimal.NewFromInt(2)},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolioID+"/performance/periods", nil)
+
+	handler.GetPeriodMetrics(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Periods []struct {
+			Period         string `json:"period"`
+			TotalReturnPct string `json:"total_return_pct"`
+		} `json:"periods"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(t, response.Periods, 2) {
+		assert.Equal(t, "1M", response.Periods[0].Period)
+		assert.Equal(t, "ALL", response.Periods[1].Period)
+		assert.Equal(t, "40", response.Periods[1].TotalReturnPct)
+	}
+	mockService.AssertExpectations(t)
+}
+
 func TestGetTWR_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	mockService := new(MockPerformanceAnalyticsService)
@@ -286,3 +393,54 @@ func TestGetAnnualizedReturn_Success(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestGetPerformanceMetrics_Timeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPerformanceAnalyticsService)
+	handler := NewPerformanceAnalyticsHandler(mockService)
+
+	portfolioID := "test-portfolio-id"
+	userID := "test-user-id"
+
+	mockService.On("GetPerformanceMetrics", portfolioID, userID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(nil, context.DeadlineExceeded)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolioID+"/performance/metrics", nil)
+
+	handler.GetPerformanceMetrics(c)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), "REQUEST_TIMEOUT")
+	mockService.AssertExpectations(t)
+}
+
+func TestGetPerformanceMetrics_ClientGone(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPerformanceAnalyticsService)
+	handler := NewPerformanceAnalyticsHandler(mockService)
+
+	portfolioID := "test-portfolio-id"
+	userID := "test-user-id"
+
+	mockService.On("GetPerformanceMetrics", portfolioID, userID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).
+		Return(nil, context.Canceled)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolioID+"/performance/metrics", nil).WithContext(ctx)
+
+	handler.GetPerformanceMetrics(c)
+
+	assert.Equal(t, apierrors.StatusClientClosedRequest, c.Writer.Status())
+	assert.Empty(t, w.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// PerformanceCertificationHandler handles performance certification HTTP requests
+type PerformanceCertificationHandler struct {
+	certificationService services.PerformanceCertificationService
+}
+
+// NewPerformanceCertificationHandler creates a new PerformanceCertificationHandler instance
+func NewPerformanceCertificationHandler(certificationService services.PerformanceCertificationService) *PerformanceCertificationHandler {
+	return &PerformanceCertificationHandler{
+		certificationService: certificationService,
+	}
+}
+
+// Export handles exporting a signed performance certification for a period
+// GET /api/v1/portfolios/:id/performance/certification
+func (h *PerformanceCertificationHandler) Export(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.PerformanceCertificationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Default to the last year, like the other performance endpoints
+	startDate := req.StartDate
+	endDate := req.EndDate
+	if startDate.IsZero() {
+		startDate = time.Now().AddDate(-1, 0, 0)
+	}
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+
+	certification, err := h.certificationService.Certify(c.Request.Context(), portfolioID, userID.(string), startDate, endDate)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, certification)
+}
+
+// Verify handles checking that a certification is unchanged since this server signed it
+// POST /api/v1/performance-certifications/verify
+func (h *PerformanceCertificationHandler) Verify(c *gin.Context) {
+	var certification dto.PerformanceCertification
+	if err := c.ShouldBindJSON(&certification); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	result, err := h.certificationService.Verify(&certification)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *PerformanceCertificationHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process performance certification request"))
+}
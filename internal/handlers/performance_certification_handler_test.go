@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockPerformanceCertificationService is a mock implementation of PerformanceCertificationService
+type MockPerformanceCertificationService struct {
+	mock.Mock
+}
+
+func (m *MockPerformanceCertificationService) Certify(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*services.PerformanceCertification, error) {
+	args := m.Called(portfolioID, userID, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.PerformanceCertification), args.Error(1)
+}
+
+func (m *MockPerformanceCertificationService) Verify(certification *services.PerformanceCertification) (*dto.CertificationVerificationResponse, error) {
+	args := m.Called(certification)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CertificationVerificationResponse), args.Error(1)
+}
+
+func TestPerformanceCertificationHandler_Export(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPerformanceCertificationService)
+	handler := NewPerformanceCertificationHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	mockService.On("Certify", portfolioID, userID, mock.MatchedBy(start.Equal), mock.MatchedBy(end.Equal)).
+		Return(&services.PerformanceCertification{Format: dto.PerformanceCertificationFormat}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/?start_date=2024-01-01&end_date=2024-12-31", nil)
+
+	handler.Export(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.PerformanceCertification
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, dto.PerformanceCertificationFormat, response.Format)
+	mockService.AssertExpectations(t)
+}
+
+func TestPerformanceCertificationHandler_Export_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"invalid period", models.ErrInvalidCertificationPeriod, http.StatusBadRequest, "INVALID_PERIOD"},
+		{"insufficient data", models.ErrInsufficientCertificationData, http.StatusUnprocessableEntity, "INSUFFICIENT_DATA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPerformanceCertificationService)
+			handler := NewPerformanceCertificationHandler(mockService)
+			mockService.On("Certify", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+			c.Set(middleware.UserIDContextKey, uuid.New().String())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+
+			handler.Export(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestPerformanceCertificationHandler_Verify(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPerformanceCertificationService)
+	handler := NewPerformanceCertificationHandler(mockService)
+
+	mockService.On("Verify", mock.MatchedBy(func(certification *services.PerformanceCertification) bool {
+		return certification.Signature.Value == "abc"
+	})).Return(&dto.CertificationVerificationResponse{Valid: true, ContentDigest: "digest"}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(
+		`{"format": "portfolios.performance-certification/v1", "signature": {"algorithm": "HMAC-SHA256", "value": "abc"}}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Verify(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.CertificationVerificationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Valid)
+	mockService.AssertExpectations(t)
+}
@@ -6,9 +6,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
-	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/services"
 )
 
@@ -32,10 +33,7 @@ func (h *PerformanceSnapshotHandler) GetSnapshots(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
@@ -56,7 +54,7 @@ func (h *PerformanceSnapshotHandler) GetSnapshots(c *gin.Context) {
 		offset = 0
 	}
 
-	snapshots, err := h.snapshotService.GetByPortfolioID(portfolioID, userID.(string), limit, offset)
+	snapshots, err := h.snapshotService.GetByPortfolioID(c.Request.Context(), portfolioID, userID.(string), limit, offset)
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -73,20 +71,14 @@ func (h *PerformanceSnapshotHandler) GetSnapshotsByDateRange(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Parse query parameters
 	var req dto.PerformanceSnapshotRangeRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid query parameters: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
 		return
 	}
 
@@ -102,14 +94,11 @@ func (h *PerformanceSnapshotHandler) GetSnapshotsByDateRange(c *gin.Context) {
 
 	// Validate date range
 	if endDate.Before(startDate) {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "End date must be after start date",
-			Code:  "INVALID_DATE_RANGE",
-		})
+		apierrors.Respond(c, apierrors.InvalidDateRange)
 		return
 	}
 
-	snapshots, err := h.snapshotService.GetByDateRange(portfolioID, userID.(string), startDate, endDate)
+	snapshots, err := h.snapshotService.GetByDateRange(c.Request.Context(), portfolioID, userID.(string), startDate, endDate)
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -126,14 +115,11 @@ func (h *PerformanceSnapshotHandler) GetLatestSnapshot(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
-	snapshot, err := h.snapshotService.GetLatest(portfolioID, userID.(string))
+	snapshot, err := h.snapshotService.GetLatest(c.Request.Context(), portfolioID, userID.(string))
 	if err != nil {
 		h.handleError(c, err)
 		return
@@ -144,26 +130,5 @@ func (h *PerformanceSnapshotHandler) GetLatestSnapshot(c *gin.Context) {
 
 // handleError handles errors and returns appropriate HTTP responses
 func (h *PerformanceSnapshotHandler) handleError(c *gin.Context, err error) {
-	switch err {
-	case models.ErrPortfolioNotFound:
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Portfolio not found",
-			Code:  "PORTFOLIO_NOT_FOUND",
-		})
-	case models.ErrUnauthorizedAccess:
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "You don't have access to this portfolio",
-			Code:  "UNAUTHORIZED_ACCESS",
-		})
-	case models.ErrPerformanceSnapshotNotFound:
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "No performance snapshots found",
-			Code:  "SNAPSHOT_NOT_FOUND",
-		})
-	default:
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: err.Error(),
-			Code:  "INTERNAL_ERROR",
-		})
-	}
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to retrieve performance snapshots"))
 }
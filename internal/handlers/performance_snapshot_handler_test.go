@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -8,11 +9,12 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/lenon/portfolios/internal/middleware"
-	"github.com/lenon/portfolios/internal/models"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // MockPerformanceSnapshotService is a mock implementation
@@ -20,7 +22,7 @@ type MockPerformanceSnapshotService struct {
 	mock.Mock
 }
 
-func (m *MockPerformanceSnapshotService) CreateSnapshot(portfolioID, userID string, prices map[string]decimal.Decimal) (*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotService) CreateSnapshot(ctx context.Context, portfolioID, userID string, prices map[string]decimal.Decimal) (*models.PerformanceSnapshot, error) {
 	args := m.Called(portfolioID, userID, prices)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -28,7 +30,7 @@ func (m *MockPerformanceSnapshotService) CreateSnapshot(portfolioID, userID stri
 	return args.Get(0).(*models.PerformanceSnapshot), args.Error(1)
 }
 
-func (m *MockPerformanceSnapshotService) GetByPortfolioID(portfolioID, userID string, limit, offset int) ([]*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotService) GetByPortfolioID(ctx context.Context, portfolioID, userID string, limit, offset int) ([]*models.PerformanceSnapshot, error) {
 	args := m.Called(portfolioID, userID, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -36,7 +38,7 @@ func (m *MockPerformanceSnapshotService) GetByPortfolioID(portfolioID, userID st
 	return args.Get(0).([]*models.PerformanceSnapshot), args.Error(1)
 }
 
-func (m *MockPerformanceSnapshotService) GetByDateRange(portfolioID, userID string, startDate, endDate time.Time) ([]*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotService) GetByDateRange(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) ([]*models.PerformanceSnapshot, error) {
 	args := m.Called(portfolioID, userID, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,7 +46,7 @@ func (m *MockPerformanceSnapshotService) GetByDateRange(portfolioID, userID stri
 	return args.Get(0).([]*models.PerformanceSnapshot), args.Error(1)
 }
 
-func (m *MockPerformanceSnapshotService) GetLatest(portfolioID, userID string) (*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotService) GetLatest(ctx context.Context, portfolioID, userID string) (*models.PerformanceSnapshot, error) {
 	args := m.Called(portfolioID, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -1,86 +1,134 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/shopspring/decimal"
 
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
 )
 
+// corporateActionCalendarDays is the period of a corporate action calendar when no end date
+// is given
+const corporateActionCalendarDays = 90
+
 // PortfolioActionHandler handles portfolio action-related HTTP requests
 type PortfolioActionHandler struct {
 	portfolioActionRepo    repository.PortfolioActionRepository
 	portfolioRepo          repository.PortfolioRepository
-	corporateActionService CorporateActionService
-}
-
-// CorporateActionService interface for applying actions
-type CorporateActionService interface {
-	ApplyStockSplit(portfolioID, symbol, userID string, ratio decimal.Decimal, date time.Time) error
-	ApplyDividend(portfolioID, symbol, userID string, amount decimal.Decimal, date time.Time) error
-	ApplyMerger(portfolioID, oldSymbol, newSymbol, userID string, ratio decimal.Decimal, date time.Time) error
+	portfolioActionService services.PortfolioActionService
+	calendarService        services.CorporateActionCalendarService
 }
 
 // NewPortfolioActionHandler creates a new PortfolioActionHandler instance
 func NewPortfolioActionHandler(
 	portfolioActionRepo repository.PortfolioActionRepository,
 	portfolioRepo repository.PortfolioRepository,
-	corporateActionService CorporateActionService,
+	portfolioActionService services.PortfolioActionService,
 ) *PortfolioActionHandler {
 	return &PortfolioActionHandler{
 		portfolioActionRepo:    portfolioActionRepo,
 		portfolioRepo:          portfolioRepo,
-		corporateActionService: corporateActionService,
+		portfolioActionService: portfolioActionService,
+	}
+}
+
+// NewPortfolioActionHandlerWithCalendar creates a new PortfolioActionHandler instance that
+// also serves the corporate action calendar of a portfolio's held symbols
+func NewPortfolioActionHandlerWithCalendar(
+	portfolioActionRepo repository.PortfolioActionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	portfolioActionService services.PortfolioActionService,
+	calendarService services.CorporateActionCalendarService,
+) *PortfolioActionHandler {
+	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, portfolioActionService)
+	handler.calendarService = calendarService
+	return handler
+}
+
+// GetCalendar lists the known corporate actions of every symbol the portfolio holds between
+// from and to, which default to today and 90 days later. Actions the market data provider
+// announced but that aren't stored yet are included.
+// GET /api/v1/portfolios/:id/actions/calendar?from=2024-06-01&to=2024-08-31
+func (h *PortfolioActionHandler) GetCalendar(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	// Get user ID from context
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
 	}
+
+	var req dto.CorporateActionCalendarRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	from := req.From
+	if from.IsZero() {
+		from = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	to := req.To
+	if to.IsZero() {
+		to = from.AddDate(0, 0, corporateActionCalendarDays)
+	}
+	if to.Before(from) {
+		apierrors.Respond(c, apierrors.InvalidDateRange)
+		return
+	}
+
+	calendar, err := h.calendarService.GetCalendar(c.Request.Context(), portfolioID, userID.(string), from, to)
+	if err != nil {
+		if apierrors.RespondContextDone(c, err) {
+			return
+		}
+
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve corporate action calendar"))
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
 }
 
 // GetPendingActions retrieves all pending corporate actions for a portfolio
-// GET /api/v1/portfolios/:portfolio_id/actions/pending
+// GET /api/v1/portfolios/:id/actions/pending
 func (h *PortfolioActionHandler) GetPendingActions(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Verify portfolio belongs to user
-	portfolio, err := h.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := h.portfolioRepo.FindByID(c.Request.Context(), portfolioID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Portfolio not found",
-			Code:  "PORTFOLIO_NOT_FOUND",
-		})
+		apierrors.Respond(c, apierrors.PortfolioNotFound)
 		return
 	}
 
-	if portfolio.UserID.String() != userID.(string) {
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "Access denied to this portfolio",
-			Code:  "FORBIDDEN",
-		})
+	if !portfolio.AccessibleBy(c.Request.Context(), userID.(string)) {
+		apierrors.Respond(c, apierrors.Forbidden.WithMessage("Access denied to this portfolio"))
 		return
 	}
 
 	// Get pending actions
-	actions, err := h.portfolioActionRepo.FindPendingByPortfolioID(portfolioID)
+	actions, err := h.portfolioActionRepo.FindPendingByPortfolioID(c.Request.Context(), portfolioID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve pending actions",
-			Code:  "RETRIEVAL_FAILED",
-		})
+		apierrors.Respond(c, apierrors.RetrievalFailed.WithMessage("Failed to retrieve pending actions"))
 		return
 	}
 
@@ -94,45 +142,33 @@ func (h *PortfolioActionHandler) GetPendingActions(c *gin.Context) {
 }
 
 // GetAllActions retrieves all corporate actions for a portfolio (all statuses)
-// GET /api/v1/portfolios/:portfolio_id/actions
+// GET /api/v1/portfolios/:id/actions
 func (h *PortfolioActionHandler) GetAllActions(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Verify portfolio belongs to user
-	portfolio, err := h.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := h.portfolioRepo.FindByID(c.Request.Context(), portfolioID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Portfolio not found",
-			Code:  "PORTFOLIO_NOT_FOUND",
-		})
+		apierrors.Respond(c, apierrors.PortfolioNotFound)
 		return
 	}
 
-	if portfolio.UserID.String() != userID.(string) {
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "Access denied to this portfolio",
-			Code:  "FORBIDDEN",
-		})
+	if !portfolio.AccessibleBy(c.Request.Context(), userID.(string)) {
+		apierrors.Respond(c, apierrors.Forbidden.WithMessage("Access denied to this portfolio"))
 		return
 	}
 
 	// Get all actions
-	actions, err := h.portfolioActionRepo.FindByPortfolioID(portfolioID)
+	actions, err := h.portfolioActionRepo.FindByPortfolioID(c.Request.Context(), portfolioID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve actions",
-			Code:  "RETRIEVAL_FAILED",
-		})
+		apierrors.Respond(c, apierrors.RetrievalFailed.WithMessage("Failed to retrieve actions"))
 		return
 	}
 
@@ -146,55 +182,40 @@ func (h *PortfolioActionHandler) GetAllActions(c *gin.Context) {
 }
 
 // GetActionByID retrieves a specific portfolio action
-// GET /api/v1/portfolios/:portfolio_id/actions/:action_id
+// GET /api/v1/portfolios/:id/actions/:action_id
 func (h *PortfolioActionHandler) GetActionByID(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 	actionID := c.Param("action_id")
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Verify portfolio belongs to user
-	portfolio, err := h.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := h.portfolioRepo.FindByID(c.Request.Context(), portfolioID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Portfolio not found",
-			Code:  "PORTFOLIO_NOT_FOUND",
-		})
+		apierrors.Respond(c, apierrors.PortfolioNotFound)
 		return
 	}
 
-	if portfolio.UserID.String() != userID.(string) {
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "Access denied to this portfolio",
-			Code:  "FORBIDDEN",
-		})
+	if !portfolio.AccessibleBy(c.Request.Context(), userID.(string)) {
+		apierrors.Respond(c, apierrors.Forbidden.WithMessage("Access denied to this portfolio"))
 		return
 	}
 
 	// Get the action
-	action, err := h.portfolioActionRepo.FindByID(actionID)
+	action, err := h.portfolioActionRepo.FindByID(c.Request.Context(), actionID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Action not found",
-			Code:  "ACTION_NOT_FOUND",
-		})
+		apierrors.Respond(c, apierrors.ActionNotFound)
 		return
 	}
 
 	// Verify action belongs to this portfolio
 	if action.PortfolioID.String() != portfolioID {
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "Action does not belong to this portfolio",
-			Code:  "FORBIDDEN",
-		})
+		apierrors.Respond(c, apierrors.Forbidden.WithMessage("Action does not belong to this portfolio"))
 		return
 	}
 
@@ -202,211 +223,85 @@ func (h *PortfolioActionHandler) GetActionByID(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// ApproveAction approves a pending corporate action
-// POST /api/v1/portfolios/:portfolio_id/actions/:action_id/approve
+// ApproveAction approves a pending corporate action and applies it to the portfolio.
+// Approval and application happen in one database transaction, so if the action
+// cannot be applied it stays pending.
+// POST /api/v1/portfolios/:id/actions/:action_id/approve
 func (h *PortfolioActionHandler) ApproveAction(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 	actionID := c.Param("action_id")
 
+	// The body is optional, but one that is sent must be valid
 	var req dto.ApproveActionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		// Notes are optional, so binding errors are not critical
-		req = dto.ApproveActionRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
-		return
-	}
-
-	// Verify portfolio belongs to user
-	portfolio, err := h.portfolioRepo.FindByID(portfolioID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Portfolio not found",
-			Code:  "PORTFOLIO_NOT_FOUND",
-		})
-		return
-	}
-
-	if portfolio.UserID.String() != userID.(string) {
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "Access denied to this portfolio",
-			Code:  "FORBIDDEN",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
-	// Get the action
-	action, err := h.portfolioActionRepo.FindByID(actionID)
+	action, err := h.portfolioActionService.ApproveAction(c.Request.Context(), actionID, portfolioID, userID.(string), &req)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Action not found",
-			Code:  "ACTION_NOT_FOUND",
-		})
-		return
-	}
-
-	// Verify action belongs to this portfolio
-	if action.PortfolioID.String() != portfolioID {
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "Action does not belong to this portfolio",
-			Code:  "FORBIDDEN",
-		})
+		apierrors.RespondError(c, err, apierrors.ApprovalFailed)
 		return
 	}
 
-	// Check if action is still pending
-	if !action.IsPending() {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Action is not pending",
-			Code:  "ACTION_NOT_PENDING",
-		})
-		return
-	}
-
-	// Approve the action
-	uid, _ := uuid.Parse(userID.(string))
-	action.Approve(uid)
-	if req.Notes != "" {
-		action.Notes = req.Notes
-	}
-
-	if err := h.portfolioActionRepo.Update(action); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to approve action",
-			Code:  "APPROVAL_FAILED",
-		})
-		return
-	}
-
-	// Auto-apply the action after approval
-	if action.CorporateAction != nil && h.corporateActionService != nil {
-		var applyErr error
-		switch action.CorporateAction.Type {
-		case models.CorporateActionTypeSplit:
-			if action.CorporateAction.Ratio != nil {
-				applyErr = h.corporateActionService.ApplyStockSplit(
-					portfolioID,
-					action.AffectedSymbol,
-					userID.(string),
-					*action.CorporateAction.Ratio,
-					action.CorporateAction.Date,
-				)
-			}
-		case models.CorporateActionTypeDividend:
-			if action.CorporateAction.Amount != nil {
-				applyErr = h.corporateActionService.ApplyDividend(
-					portfolioID,
-					action.AffectedSymbol,
-					userID.(string),
-					*action.CorporateAction.Amount,
-					action.CorporateAction.Date,
-				)
-			}
-		case models.CorporateActionTypeMerger:
-			if action.CorporateAction.Ratio != nil && action.CorporateAction.NewSymbol != nil {
-				applyErr = h.corporateActionService.ApplyMerger(
-					portfolioID,
-					action.AffectedSymbol,
-					*action.CorporateAction.NewSymbol,
-					userID.(string),
-					*action.CorporateAction.Ratio,
-					action.CorporateAction.Date,
-				)
-			}
-		}
-
-		// If application was successful, mark the action as applied
-		if applyErr == nil {
-			now := time.Now().UTC()
-			action.AppliedAt = &now
-			// Attempt to update the action as applied
-			// We intentionally ignore errors here - the action is already approved
-			// In production, this would be logged to an error tracking system
-			_ = h.portfolioActionRepo.Update(action)
-		}
-		// If there was an error applying, we don't fail the approval
-		// The action remains approved but not applied, allowing manual retry
-	}
-
 	response := h.toPortfolioActionResponse(action)
 	c.JSON(http.StatusOK, response)
 }
 
 // RejectAction rejects a pending corporate action
-// POST /api/v1/portfolios/:portfolio_id/actions/:action_id/reject
+// POST /api/v1/portfolios/:id/actions/:action_id/reject
 func (h *PortfolioActionHandler) RejectAction(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 	actionID := c.Param("action_id")
 
 	var req dto.RejectActionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Verify portfolio belongs to user
-	portfolio, err := h.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := h.portfolioRepo.FindByID(c.Request.Context(), portfolioID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Portfolio not found",
-			Code:  "PORTFOLIO_NOT_FOUND",
-		})
+		apierrors.Respond(c, apierrors.PortfolioNotFound)
 		return
 	}
 
-	if portfolio.UserID.String() != userID.(string) {
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "Access denied to this portfolio",
-			Code:  "FORBIDDEN",
-		})
+	if !portfolio.AccessibleBy(c.Request.Context(), userID.(string)) {
+		apierrors.Respond(c, apierrors.Forbidden.WithMessage("Access denied to this portfolio"))
 		return
 	}
 
 	// Get the action
-	action, err := h.portfolioActionRepo.FindByID(actionID)
+	action, err := h.portfolioActionRepo.FindByID(c.Request.Context(), actionID)
 	if err != nil {
-		c.JSON(http.StatusNotFound, dto.ErrorResponse{
-			Error: "Action not found",
-			Code:  "ACTION_NOT_FOUND",
-		})
+		apierrors.Respond(c, apierrors.ActionNotFound)
 		return
 	}
 
 	// Verify action belongs to this portfolio
 	if action.PortfolioID.String() != portfolioID {
-		c.JSON(http.StatusForbidden, dto.ErrorResponse{
-			Error: "Action does not belong to this portfolio",
-			Code:  "FORBIDDEN",
-		})
+		apierrors.Respond(c, apierrors.Forbidden.WithMessage("Action does not belong to this portfolio"))
 		return
 	}
 
 	// Check if action is still pending
 	if !action.IsPending() {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Action is not pending",
-			Code:  "ACTION_NOT_PENDING",
-		})
+		apierrors.Respond(c, apierrors.ActionNotPending)
 		return
 	}
 
@@ -414,11 +309,8 @@ func (h *PortfolioActionHandler) RejectAction(c *gin.Context) {
 	uid, _ := uuid.Parse(userID.(string))
 	action.Reject(uid, req.Reason)
 
-	if err := h.portfolioActionRepo.Update(action); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to reject action",
-			Code:  "REJECTION_FAILED",
-		})
+	if err := h.portfolioActionRepo.Update(c.Request.Context(), action); err != nil {
+		apierrors.Respond(c, apierrors.RejectionFailed)
 		return
 	}
 
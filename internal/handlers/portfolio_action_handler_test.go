@@ -2,22 +2,26 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lenon/portfolios/internal/dto"
-	"github.com/lenon/portfolios/internal/middleware"
-	"github.com/lenon/portfolios/internal/models"
-	"github.com/lenon/portfolios/internal/repository"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
 )
 
 func setupActionHandlerTestDB(t *testing.T) *gorm.DB {
@@ -29,6 +33,9 @@ func setupActionHandlerTestDB(t *testing.T) *gorm.DB {
 		&models.Portfolio{},
 		&models.CorporateAction{},
 		&models.PortfolioAction{},
+		&models.Holding{},
+		&models.TaxLot{},
+		&models.Transaction{},
 	)
 	require.NoError(t, err)
 
@@ -73,6 +80,18 @@ func createActionHandlerTestData(t *testing.T, db *gorm.DB) (*models.User, *mode
 	return user, portfolio, corpAction, portfolioAction
 }
 
+func createActionHandlerTestHolding(t *testing.T, db *gorm.DB, portfolio *models.Portfolio) *models.Holding {
+	holding := &models.Holding{
+		PortfolioID:  portfolio.ID,
+		Symbol:       "AAPL",
+		Quantity:     decimal.NewFromInt(100),
+		CostBasis:    decimal.NewFromInt(15000),
+		AvgCostPrice: decimal.NewFromInt(150),
+	}
+	require.NoError(t, db.Create(holding).Error)
+	return holding
+}
+
 func TestNewPortfolioActionHandler(t *testing.T) {
 	db := setupActionHandlerTestDB(t)
 	portfolioActionRepo := repository.NewPortfolioActionRepository(db)
@@ -95,7 +114,7 @@ func TestGetPendingActions_Success(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/actions/pending", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/actions/pending", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.GetPendingActions(c)
 	})
@@ -124,7 +143,7 @@ func TestGetPendingActions_Unauthorized(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/actions/pending", handler.GetPendingActions)
+	router.GET("/api/v1/portfolios/:id/actions/pending", handler.GetPendingActions)
 
 	req := httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolio.ID.String()+"/actions/pending", nil)
 	w := httptest.NewRecorder()
@@ -143,7 +162,7 @@ func TestGetPendingActions_PortfolioNotFound(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/actions/pending", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/actions/pending", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.GetPendingActions(c)
 	})
@@ -172,7 +191,7 @@ func TestGetPendingActions_Forbidden(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/actions/pending", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/actions/pending", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, otherUser.ID.String())
 		handler.GetPendingActions(c)
 	})
@@ -194,7 +213,7 @@ func TestGetAllActions_Success(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/actions", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/actions", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.GetAllActions(c)
 	})
@@ -221,7 +240,7 @@ func TestGetActionByID_Success(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/actions/:action_id", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/actions/:action_id", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.GetActionByID(c)
 	})
@@ -249,7 +268,7 @@ func TestGetActionByID_ActionNotFound(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/actions/:action_id", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/actions/:action_id", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.GetActionByID(c)
 	})
@@ -280,7 +299,7 @@ func TestGetActionByID_WrongPortfolio(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/actions/:action_id", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/actions/:action_id", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.GetActionByID(c)
 	})
@@ -295,14 +314,15 @@ func TestGetActionByID_WrongPortfolio(t *testing.T) {
 func TestApproveAction_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	db := setupActionHandlerTestDB(t)
-	user, portfolio, _, portfolioAction := createActionHandlerTestData(t, db)
+	user, portfolio, corpAction, portfolioAction := createActionHandlerTestData(t, db)
+	holding := createActionHandlerTestHolding(t, db, portfolio)
 
 	portfolioActionRepo := repository.NewPortfolioActionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
-	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
+	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, services.NewPortfolioActionService(db))
 
 	router := gin.New()
-	router.POST("/api/v1/portfolios/:portfolio_id/actions/:action_id/approve", func(c *gin.Context) {
+	router.POST("/api/v1/portfolios/:id/actions/:action_id/approve", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.ApproveAction(c)
 	})
@@ -319,9 +339,95 @@ func TestApproveAction_Success(t *testing.T) {
 	var response dto.PortfolioActionResponse
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "APPROVED", response.Status)
+	assert.Equal(t, "APPLIED", response.Status)
 	assert.NotNil(t, response.ReviewedAt)
+	assert.NotNil(t, response.AppliedAt)
 	assert.Equal(t, "Looks good", response.Notes)
+
+	// The split was applied and the corporate action marked applied
+	var updatedHolding models.Holding
+	require.NoError(t, db.First(&updatedHolding, "id = ?", holding.ID).Error)
+	assert.True(t, updatedHolding.Quantity.Equal(decimal.NewFromInt(200)))
+
+	var updatedCorpAction models.CorporateAction
+	require.NoError(t, db.First(&updatedCorpAction, "id = ?", corpAction.ID).Error)
+	assert.True(t, updatedCorpAction.Applied)
+}
+
+func TestApproveAction_ApplyFailedRollsBack(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupActionHandlerTestDB(t)
+	// No holding for the split to apply to
+	user, portfolio, corpAction, portfolioAction := createActionHandlerTestData(t, db)
+
+	portfolioActionRepo := repository.NewPortfolioActionRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, services.NewPortfolioActionService(db))
+
+	router := gin.New()
+	router.POST("/api/v1/portfolios/:id/actions/:action_id/approve", func(c *gin.Context) {
+		c.Set(middleware.UserIDContextKey, user.ID.String())
+		handler.ApproveAction(c)
+	})
+
+	req := httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolio.ID.String()+"/actions/"+portfolioAction.ID.String()+"/approve", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "APPLY_FAILED")
+
+	var unchanged models.PortfolioAction
+	require.NoError(t, db.First(&unchanged, "id = ?", portfolioAction.ID).Error)
+	assert.Equal(t, models.PortfolioActionStatusPending, unchanged.Status)
+
+	var unchangedCorpAction models.CorporateAction
+	require.NoError(t, db.First(&unchangedCorpAction, "id = ?", corpAction.ID).Error)
+	assert.False(t, unchangedCorpAction.Applied)
+}
+
+func TestApproveAction_InvalidRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupActionHandlerTestDB(t)
+	user, portfolio, _, portfolioAction := createActionHandlerTestData(t, db)
+	createActionHandlerTestHolding(t, db, portfolio)
+
+	portfolioActionRepo := repository.NewPortfolioActionRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, services.NewPortfolioActionService(db))
+
+	router := gin.New()
+	router.POST("/api/v1/portfolios/:id/actions/:action_id/approve", func(c *gin.Context) {
+		c.Set(middleware.UserIDContextKey, user.ID.String())
+		handler.ApproveAction(c)
+	})
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode string
+	}{
+		{"malformed body", `{"spinoff_cost_basis_allocation": "abc"}`, "INVALID_REQUEST"},
+		{"allocation out of range", `{"spinoff_cost_basis_allocation": "1.2"}`, "VALIDATION_ERROR"},
+		{"unknown allocation method", `{"spinoff_allocation_method": "HALF"}`, "INVALID_REQUEST"},
+		{"explicit method without an allocation", `{"spinoff_allocation_method": "EXPLICIT"}`, "VALIDATION_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolio.ID.String()+"/actions/"+portfolioAction.ID.String()+"/approve", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantCode)
+		})
+	}
+
+	var unchanged models.PortfolioAction
+	require.NoError(t, db.First(&unchanged, "id = ?", portfolioAction.ID).Error)
+	assert.Equal(t, models.PortfolioActionStatusPending, unchanged.Status)
 }
 
 func TestApproveAction_Unauthorized(t *testing.T) {
@@ -334,7 +440,7 @@ func TestApproveAction_Unauthorized(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.POST("/api/v1/portfolios/:portfolio_id/actions/:action_id/approve", handler.ApproveAction)
+	router.POST("/api/v1/portfolios/:id/actions/:action_id/approve", handler.ApproveAction)
 
 	req := httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolio.ID.String()+"/actions/"+portfolioAction.ID.String()+"/approve", nil)
 	w := httptest.NewRecorder()
@@ -354,10 +460,10 @@ func TestApproveAction_NotPending(t *testing.T) {
 
 	portfolioActionRepo := repository.NewPortfolioActionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
-	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
+	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, services.NewPortfolioActionService(db))
 
 	router := gin.New()
-	router.POST("/api/v1/portfolios/:portfolio_id/actions/:action_id/approve", func(c *gin.Context) {
+	router.POST("/api/v1/portfolios/:id/actions/:action_id/approve", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.ApproveAction(c)
 	})
@@ -379,7 +485,7 @@ func TestRejectAction_Success(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.POST("/api/v1/portfolios/:portfolio_id/actions/:action_id/reject", func(c *gin.Context) {
+	router.POST("/api/v1/portfolios/:id/actions/:action_id/reject", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.RejectAction(c)
 	})
@@ -411,7 +517,7 @@ func TestRejectAction_InvalidRequest(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.POST("/api/v1/portfolios/:portfolio_id/actions/:action_id/reject", func(c *gin.Context) {
+	router.POST("/api/v1/portfolios/:id/actions/:action_id/reject", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.RejectAction(c)
 	})
@@ -441,7 +547,7 @@ func TestRejectAction_NotPending(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	router := gin.New()
-	router.POST("/api/v1/portfolios/:portfolio_id/actions/:action_id/reject", func(c *gin.Context) {
+	router.POST("/api/v1/portfolios/:id/actions/:action_id/reject", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, user.ID.String())
 		handler.RejectAction(c)
 	})
@@ -457,6 +563,8 @@ func TestRejectAction_NotPending(t *testing.T) {
 }
 
 func TestToPortfolioActionResponse(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupActionHandlerTestDB(t)
 	_, _, corpAction, portfolioAction := createActionHandlerTestData(t, db)
 
@@ -465,7 +573,7 @@ func TestToPortfolioActionResponse(t *testing.T) {
 	handler := NewPortfolioActionHandler(portfolioActionRepo, portfolioRepo, nil)
 
 	// Load the action with corporate action
-	loadedAction, err := portfolioActionRepo.FindByID(portfolioAction.ID.String())
+	loadedAction, err := portfolioActionRepo.FindByID(ctx, portfolioAction.ID.String())
 	require.NoError(t, err)
 
 	response := handler.toPortfolioActionResponse(loadedAction)
@@ -513,3 +621,55 @@ func TestToPortfolioActionResponse_NoCorporateAction(t *testing.T) {
 	// Cleanup
 	_ = user
 }
+
+func TestGetCalendar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupActionHandlerTestDB(t)
+	user, portfolio, _, _ := createActionHandlerTestData(t, db)
+	createActionHandlerTestHolding(t, db, portfolio)
+
+	portfolioActionRepo := repository.NewPortfolioActionRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	calendarService := services.NewCorporateActionCalendarService(repository.NewCorporateActionRepository(db),
+		portfolioRepo, repository.NewHoldingRepository(db), nil)
+	handler := NewPortfolioActionHandlerWithCalendar(portfolioActionRepo, portfolioRepo, nil, calendarService)
+
+	router := gin.New()
+	router.GET("/api/v1/portfolios/:id/actions/calendar", func(c *gin.Context) {
+		c.Set(middleware.UserIDContextKey, user.ID.String())
+		handler.GetCalendar(c)
+	})
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolio.ID.String()+"/actions/calendar"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("defaults to the next 90 days", func(t *testing.T) {
+		w := get("")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response dto.CorporateActionCalendarResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 90*24*time.Hour, response.To.Sub(response.From))
+		require.Len(t, response.Events, 1)
+		assert.Equal(t, "AAPL", response.Events[0].Symbol)
+		assert.Equal(t, services.CorporateActionSourceStored, response.Events[0].Source)
+		assert.True(t, decimal.NewFromInt(100).Equal(response.Events[0].SharesHeld))
+	})
+
+	t.Run("period without actions", func(t *testing.T) {
+		w := get("?from=2020-01-01&to=2020-03-31")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response dto.CorporateActionCalendarResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Empty(t, response.Events)
+	})
+
+	t.Run("end before start", func(t *testing.T) {
+		w := get("?from=2024-06-01&to=2024-05-01")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
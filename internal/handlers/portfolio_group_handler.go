@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// PortfolioGroupHandler handles portfolio group HTTP requests
+type PortfolioGroupHandler struct {
+	groupService services.PortfolioGroupService
+}
+
+// NewPortfolioGroupHandler creates a new PortfolioGroupHandler instance
+func NewPortfolioGroupHandler(groupService services.PortfolioGroupService) *PortfolioGroupHandler {
+	return &PortfolioGroupHandler{
+		groupService: groupService,
+	}
+}
+
+// Create handles creating a portfolio group
+// POST /api/v1/groups
+func (h *PortfolioGroupHandler) Create(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.CreatePortfolioGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	group, err := h.groupService.Create(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToPortfolioGroupResponse(group))
+}
+
+// List handles retrieving the user's portfolio groups
+// GET /api/v1/groups
+func (h *PortfolioGroupHandler) List(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	groups, err := h.groupService.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPortfolioGroupListResponse(groups))
+}
+
+// Get handles retrieving a single portfolio group
+// GET /api/v1/groups/:id
+func (h *PortfolioGroupHandler) Get(c *gin.Context) {
+	groupID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	group, err := h.groupService.Get(c.Request.Context(), groupID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPortfolioGroupResponse(group))
+}
+
+// Update handles renaming, moving or changing the portfolios of a portfolio group
+// PUT /api/v1/groups/:id
+func (h *PortfolioGroupHandler) Update(c *gin.Context) {
+	groupID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.UpdatePortfolioGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	group, err := h.groupService.Update(c.Request.Context(), groupID, userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPortfolioGroupResponse(group))
+}
+
+// Delete handles deleting a portfolio group. Its portfolios are kept.
+// DELETE /api/v1/groups/:id
+func (h *PortfolioGroupHandler) Delete(c *gin.Context) {
+	groupID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.groupService.Delete(c.Request.Context(), groupID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetOverview handles rolling up the valuation and performance of a group's portfolios,
+// including those of its subgroups. The period defaults to the last year.
+// GET /api/v1/groups/:id/overview
+func (h *PortfolioGroupHandler) GetOverview(c *gin.Context) {
+	groupID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.OverviewRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	startDate, endDate, ok := groupPeriod(c, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
+
+	overview, err := h.groupService.GetOverview(c.Request.Context(), groupID, userID.(string), req.Currency, startDate, endDate)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, overview)
+}
+
+// GetBenchmarkComparison handles comparing a group's combined return to a benchmark index.
+// The benchmark defaults to SPY and the period to the last year.
+// GET /api/v1/groups/:id/benchmark
+func (h *PortfolioGroupHandler) GetBenchmarkComparison(c *gin.Context) {
+	groupID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.PortfolioGroupBenchmarkRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	startDate, endDate, ok := groupPeriod(c, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
+
+	comparison, err := h.groupService.CompareToBenchmark(c.Request.Context(),
+		groupID,
+		userID.(string),
+		req.Currency,
+		req.BenchmarkSymbol,
+		startDate,
+		endDate,
+	)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToBenchmarkComparisonResponse(comparison))
+}
+
+// groupPeriod fills in the last year for missing dates and responds with an error if the
+// period ends before it starts
+func groupPeriod(c *gin.Context, startDate, endDate time.Time) (time.Time, time.Time, bool) {
+	if startDate.IsZero() {
+		startDate = time.Now().AddDate(-1, 0, 0)
+	}
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	if !endDate.After(startDate) {
+		apierrors.Respond(c, apierrors.InvalidDateRange)
+		return time.Time{}, time.Time{}, false
+	}
+	return startDate, endDate, true
+}
+
+// handleError maps service errors to HTTP responses
+func (h *PortfolioGroupHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process portfolio group request"))
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
 	"github.com/lenon/portfolios/internal/models"
@@ -14,6 +16,7 @@ import (
 // PortfolioHandler handles portfolio-related HTTP requests
 type PortfolioHandler struct {
 	portfolioService services.PortfolioService
+	tagService       services.TagService
 }
 
 // NewPortfolioHandler creates a new PortfolioHandler instance
@@ -23,6 +26,15 @@ func NewPortfolioHandler(portfolioService services.PortfolioService) *PortfolioH
 	}
 }
 
+// NewPortfolioHandlerWithTags creates a new PortfolioHandler that tags portfolios and
+// filters the portfolio list by tag
+func NewPortfolioHandlerWithTags(portfolioService services.PortfolioService, tagService services.TagService) *PortfolioHandler {
+	return &PortfolioHandler{
+		portfolioService: portfolioService,
+		tagService:       tagService,
+	}
+}
+
 // Create handles portfolio creation
 // POST /api/v1/portfolios
 func (h *PortfolioHandler) Create(c *gin.Context) {
@@ -30,25 +42,26 @@ func (h *PortfolioHandler) Create(c *gin.Context) {
 
 	// Bind and validate request
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	// Reject invalid tags before anything is created
+	if _, err := models.NormalizeTags(req.Tags); err != nil {
+		apierrors.RespondError(c, err, apierrors.ValidationError)
 		return
 	}
 
 	// Create portfolio
 	portfolio, err := h.portfolioService.Create(
+		c.Request.Context(),
 		userID.(string),
 		req.Name,
 		req.Description,
@@ -56,58 +69,54 @@ func (h *PortfolioHandler) Create(c *gin.Context) {
 		req.CostBasisMethod,
 	)
 	if err != nil {
-		// Check for duplicate name error
-		if err == models.ErrPortfolioDuplicateName {
-			c.JSON(http.StatusConflict, dto.ErrorResponse{
-				Error: "A portfolio with this name already exists",
-				Code:  "DUPLICATE_PORTFOLIO_NAME",
-			})
-			return
-		}
+		apierrors.RespondError(c, err, apierrors.CreationFailed.WithMessage("Failed to create portfolio"))
+		return
+	}
 
-		// Check for validation errors
-		if strings.Contains(err.Error(), "required") || strings.Contains(err.Error(), "invalid") {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: err.Error(),
-				Code:  "VALIDATION_ERROR",
-			})
+	var tags []string
+	if h.tagService != nil && len(req.Tags) > 0 {
+		tags, err = h.tagService.SetPortfolioTags(c.Request.Context(), portfolio.ID.String(), userID.(string), req.Tags)
+		if err != nil {
+			// Don't keep a portfolio created without the tags asked for, even if the client has gone
+			_ = h.portfolioService.Delete(context.WithoutCancel(c.Request.Context()), portfolio.ID.String(), userID.(string))
+			apierrors.RespondError(c, err, apierrors.CreationFailed.WithMessage("Failed to tag portfolio"))
 			return
 		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to create portfolio",
-			Code:  "CREATION_FAILED",
-		})
-		return
 	}
 
-	c.JSON(http.StatusCreated, dto.ToPortfolioResponse(portfolio))
+	c.JSON(http.StatusCreated, dto.ToPortfolioResponseWithTags(portfolio, tags))
 }
 
-// GetAll retrieves all portfolios for the authenticated user
-// GET /api/v1/portfolios
+// GetAll retrieves all portfolios for the authenticated user, optionally only those carrying
+// every tag given in repeated tag query parameters
+// GET /api/v1/portfolios?tag=retirement
 func (h *PortfolioHandler) GetAll(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Get all portfolios
-	portfolios, err := h.portfolioService.GetAllByUserID(userID.(string))
+	portfolios, err := h.portfolioService.GetAllByUserID(c.Request.Context(), userID.(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve portfolios",
-			Code:  "RETRIEVAL_FAILED",
-		})
+		apierrors.Respond(c, apierrors.RetrievalFailed.WithMessage("Failed to retrieve portfolios"))
+		return
+	}
+
+	if h.tagService == nil {
+		c.JSON(http.StatusOK, dto.ToPortfolioListResponse(portfolios))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToPortfolioListResponse(portfolios))
+	portfolios, tags, err := h.tagService.TagPortfolios(c.Request.Context(), portfolios, c.QueryArray("tag"))
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve portfolio tags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToPortfolioListResponseWithTags(portfolios, tags))
 }
 
 // GetByID retrieves a specific portfolio
@@ -115,50 +124,44 @@ func (h *PortfolioHandler) GetAll(c *gin.Context) {
 func (h *PortfolioHandler) GetByID(c *gin.Context) {
 	portfolioID := c.Param("id")
 	if portfolioID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Portfolio ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Portfolio ID is required"))
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Get portfolio
-	portfolio, err := h.portfolioService.GetByID(portfolioID, userID.(string))
+	portfolio, err := h.portfolioService.GetByID(c.Request.Context(), portfolioID, userID.(string))
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
-
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve portfolio"))
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve portfolio",
-			Code:  "RETRIEVAL_FAILED",
-		})
+	tags, err := h.portfolioTags(c, portfolio)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve portfolio tags"))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToPortfolioResponse(portfolio))
+	setVersionETag(c, portfolio.Version)
+	c.JSON(http.StatusOK, dto.ToPortfolioResponseWithTags(portfolio, tags))
+}
+
+// portfolioTags returns the tags of a portfolio, or nil if tags are not enabled
+func (h *PortfolioHandler) portfolioTags(c *gin.Context, portfolio *models.Portfolio) ([]string, error) {
+	if h.tagService == nil {
+		return nil, nil
+	}
+	_, tags, err := h.tagService.TagPortfolios(c.Request.Context(), []*models.Portfolio{portfolio}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tags[portfolio.ID], nil
 }
 
 // Update updates a portfolio
@@ -166,67 +169,64 @@ func (h *PortfolioHandler) GetByID(c *gin.Context) {
 func (h *PortfolioHandler) Update(c *gin.Context) {
 	portfolioID := c.Param("id")
 	if portfolioID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Portfolio ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Portfolio ID is required"))
 		return
 	}
 
 	var req dto.UpdatePortfolioRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
-	// Update portfolio
-	portfolio, err := h.portfolioService.Update(portfolioID, userID.(string), req.Name, req.Description)
+	version, err := requestVersion(c, req.Version)
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
+		if !respondVersionError(c, err) {
+			apierrors.Respond(c, apierrors.InvalidRequest.WithMessage(err.Error()))
 		}
+		return
+	}
 
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied",
-				Code:  "FORBIDDEN",
-			})
+	// Reject invalid tags before anything is changed
+	if req.Tags != nil {
+		if _, err := models.NormalizeTags(*req.Tags); err != nil {
+			apierrors.RespondError(c, err, apierrors.ValidationError)
 			return
 		}
+	}
 
-		if err == models.ErrPortfolioDuplicateName {
-			c.JSON(http.StatusConflict, dto.ErrorResponse{
-				Error: "A portfolio with this name already exists",
-				Code:  "DUPLICATE_PORTFOLIO_NAME",
-			})
+	// Update portfolio
+	portfolio, err := h.portfolioService.Update(c.Request.Context(), portfolioID, userID.(string), version, req.Name, req.Description)
+	if err != nil {
+		if respondVersionError(c, err) {
 			return
 		}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to update portfolio",
-			Code:  "UPDATE_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.UpdateFailed.WithMessage("Failed to update portfolio"))
+		return
+	}
+
+	// Replace the tags if they were given, otherwise report the ones the portfolio has
+	var tags []string
+	if h.tagService != nil && req.Tags != nil {
+		tags, err = h.tagService.SetPortfolioTags(c.Request.Context(), portfolioID, userID.(string), *req.Tags)
+	} else {
+		tags, err = h.portfolioTags(c, portfolio)
+	}
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.UpdateFailed.WithMessage("Failed to update portfolio tags"))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToPortfolioResponse(portfolio))
+	setVersionETag(c, portfolio.Version)
+	c.JSON(http.StatusOK, dto.ToPortfolioResponseWithTags(portfolio, tags))
 }
 
 // Delete deletes a portfolio
@@ -234,46 +234,21 @@ func (h *PortfolioHandler) Update(c *gin.Context) {
 func (h *PortfolioHandler) Delete(c *gin.Context) {
 	portfolioID := c.Param("id")
 	if portfolioID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Portfolio ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Portfolio ID is required"))
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Delete portfolio
-	err := h.portfolioService.Delete(portfolioID, userID.(string))
+	err := h.portfolioService.Delete(c.Request.Context(), portfolioID, userID.(string))
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
-
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to delete portfolio",
-			Code:  "DELETE_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.DeleteFailed.WithMessage("Failed to delete portfolio"))
 		return
 	}
 
@@ -2,18 +2,22 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
 	"github.com/lenon/portfolios/internal/models"
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 )
 
 // MockPortfolioService is a mock implementation of PortfolioService
@@ -21,7 +25,7 @@ type MockPortfolioService struct {
 	mock.Mock
 }
 
-func (m *MockPortfolioService) Create(userID, name, description, baseCurrency string, costBasisMethod models.CostBasisMethod) (*models.Portfolio, error) {
+func (m *MockPortfolioService) Create(ctx context.Context, userID, name, description, baseCurrency string, costBasisMethod models.CostBasisMethod) (*models.Portfolio, error) {
 	args := m.Called(userID, name, description, baseCurrency, costBasisMethod)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -29,7 +33,7 @@ func (m *MockPortfolioService) Create(userID, name, description, baseCurrency st
 	return args.Get(0).(*models.Portfolio), args.Error(1)
 }
 
-func (m *MockPortfolioService) GetByID(id string, userID string) (*models.Portfolio, error) {
+func (m *MockPortfolioService) GetByID(ctx context.Context, id string, userID string) (*models.Portfolio, error) {
 	args := m.Called(id, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -37,7 +41,7 @@ func (m *MockPortfolioService) GetByID(id string, userID string) (*models.Portfo
 	return args.Get(0).(*models.Portfolio), args.Error(1)
 }
 
-func (m *MockPortfolioService) GetAllByUserID(userID string) ([]*models.Portfolio, error) {
+func (m *MockPortfolioService) GetAllByUserID(ctx context.Context, userID string) ([]*models.Portfolio, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -45,15 +49,15 @@ func (m *MockPortfolioService) GetAllByUserID(userID string) ([]*models.Portfoli
 	return args.Get(0).([]*models.Portfolio), args.Error(1)
 }
 
-func (m *MockPortfolioService) Update(id, userID, name, description string) (*models.Portfolio, error) {
-	args := m.Called(id, userID, name, description)
+func (m *MockPortfolioService) Update(ctx context.Context, id, userID string, version int, name, description string) (*models.Portfolio, error) {
+	args := m.Called(id, userID, version, name, description)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Portfolio), args.Error(1)
 }
 
-func (m *MockPortfolioService) Delete(id, userID string) error {
+func (m *MockPortfolioService) Delete(ctx context.Context, id, userID string) error {
 	args := m.Called(id, userID)
 	return args.Error(0)
 }
@@ -386,7 +390,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 			CostBasisMethod: models.CostBasisFIFO,
 		}
 
-		mockService.On("Update", portfolioID, userID, "Updated Portfolio", "Updated Description").
+		mockService.On("Update", portfolioID, userID, 1, "Updated Portfolio", "Updated Description").
 			Return(portfolio, nil)
 
 		router.PUT("/portfolios/:id", func(c *gin.Context) {
@@ -402,6 +406,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/portfolios/"+portfolioID, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -425,6 +430,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/portfolios/"+portfolioID, bytes.NewBufferString("invalid json"))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -444,7 +450,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 		userID := uuid.New().String()
 		portfolioID := uuid.New().String()
 
-		mockService.On("Update", portfolioID, userID, "Updated Portfolio", "").
+		mockService.On("Update", portfolioID, userID, 1, "Updated Portfolio", "").
 			Return(nil, models.ErrPortfolioNotFound)
 
 		router.PUT("/portfolios/:id", func(c *gin.Context) {
@@ -459,6 +465,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/portfolios/"+portfolioID, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -479,7 +486,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 		userID := uuid.New().String()
 		portfolioID := uuid.New().String()
 
-		mockService.On("Update", portfolioID, userID, "Updated Portfolio", "").
+		mockService.On("Update", portfolioID, userID, 1, "Updated Portfolio", "").
 			Return(nil, models.ErrUnauthorizedAccess)
 
 		router.PUT("/portfolios/:id", func(c *gin.Context) {
@@ -494,6 +501,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/portfolios/"+portfolioID, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -514,7 +522,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 		userID := uuid.New().String()
 		portfolioID := uuid.New().String()
 
-		mockService.On("Update", portfolioID, userID, "Duplicate Portfolio", "").
+		mockService.On("Update", portfolioID, userID, 1, "Duplicate Portfolio", "").
 			Return(nil, models.ErrPortfolioDuplicateName)
 
 		router.PUT("/portfolios/:id", func(c *gin.Context) {
@@ -529,6 +537,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/portfolios/"+portfolioID, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -557,6 +566,7 @@ func TestPortfolioHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/portfolios/"+portfolioID, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -569,6 +579,68 @@ func TestPortfolioHandler_Update(t *testing.T) {
 	})
 }
 
+func TestPortfolioHandler_Update_Versioning(t *testing.T) {
+	userID := uuid.New().String()
+	portfolioID := uuid.New().String()
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		body        string
+		serviceErr  error
+		wantStatus  int
+		wantCode    string
+		wantETag    string
+		wantVersion int
+	}{
+		{"If-Match header", `W/"3"`, `{"name":"Renamed"}`, nil, http.StatusOK, "", `"4"`, 0},
+		{"body version", "", `{"name":"Renamed","version":3}`, nil, http.StatusOK, "", `"4"`, 0},
+		{"stale version", `"3"`, `{"name":"Renamed"}`, &models.VersionConflictError{CurrentVersion: 5}, http.StatusConflict, "VERSION_CONFLICT", `"5"`, 5},
+		{"missing version", "", `{"name":"Renamed"}`, nil, http.StatusPreconditionRequired, "VERSION_REQUIRED", "", 0},
+		{"malformed If-Match", "*", `{"name":"Renamed"}`, nil, http.StatusBadRequest, "INVALID_REQUEST", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockPortfolioService)
+			handler := NewPortfolioHandler(mockService)
+			router := setupTestRouter()
+
+			if tt.wantStatus == http.StatusOK {
+				mockService.On("Update", portfolioID, userID, 3, "Renamed", "").
+					Return(&models.Portfolio{ID: uuid.MustParse(portfolioID), Name: "Renamed", Version: 4}, nil)
+			} else if tt.serviceErr != nil {
+				mockService.On("Update", portfolioID, userID, 3, "Renamed", "").
+					Return(nil, fmt.Errorf("failed to update portfolio: %w", tt.serviceErr))
+			}
+
+			router.PUT("/portfolios/:id", func(c *gin.Context) {
+				c.Set(middleware.UserIDContextKey, userID)
+				handler.Update(c)
+			})
+
+			req, _ := http.NewRequest(http.MethodPut, "/portfolios/"+portfolioID, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantETag, w.Header().Get("ETag"))
+			if tt.wantCode != "" {
+				var response dto.VersionConflictResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.wantCode, response.Code)
+				assert.Equal(t, tt.wantVersion, response.CurrentVersion)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestPortfolioHandler_Delete(t *testing.T) {
 	t.Run("successful deletion", func(t *testing.T) {
 		mockService := new(MockPortfolioService)
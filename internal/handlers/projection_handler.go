@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// ProjectionHandler handles portfolio projection HTTP requests
+type ProjectionHandler struct {
+	projectionService services.ProjectionService
+}
+
+// NewProjectionHandler creates a new ProjectionHandler instance
+func NewProjectionHandler(projectionService services.ProjectionService) *ProjectionHandler {
+	return &ProjectionHandler{
+		projectionService: projectionService,
+	}
+}
+
+// Project handles simulating a portfolio's future value under monthly contributions
+// POST /api/v1/portfolios/:id/projection
+func (h *ProjectionHandler) Project(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.ProjectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	result, err := h.projectionService.Project(c.Request.Context(), portfolioID, userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ProjectionHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to project portfolio value"))
+}
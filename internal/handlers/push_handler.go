@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// PushHandler handles the devices users register to receive their notifications as push
+// notifications
+type PushHandler struct {
+	pushService services.PushService
+}
+
+// NewPushHandler creates a new PushHandler instance
+func NewPushHandler(pushService services.PushService) *PushHandler {
+	return &PushHandler{
+		pushService: pushService,
+	}
+}
+
+// GetVAPIDPublicKey handles retrieving the key browsers subscribe to push notifications with
+// GET /api/v1/push/vapid-public-key
+func (h *PushHandler) GetVAPIDPublicKey(c *gin.Context) {
+	publicKey, err := h.pushService.VAPIDPublicKey()
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &dto.VAPIDPublicKeyResponse{PublicKey: publicKey})
+}
+
+// RegisterDevice handles registering a device for push notifications
+// POST /api/v1/push/devices
+func (h *PushHandler) RegisterDevice(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.RegisterPushDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	device, err := h.pushService.RegisterDevice(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToPushDeviceResponse(device))
+}
+
+// ListDevices handles retrieving the user's registered devices
+// GET /api/v1/push/devices
+func (h *PushHandler) ListDevices(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	devices, err := h.pushService.ListDevices(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.PushDeviceResponse, len(devices))
+	for i, device := range devices {
+		response[i] = dto.ToPushDeviceResponse(device)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteDevice handles unregistering a device
+// DELETE /api/v1/push/devices/:id
+func (h *PushHandler) DeleteDevice(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.pushService.DeleteDevice(c.Request.Context(), c.Param("id"), userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *PushHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to manage push devices"))
+}
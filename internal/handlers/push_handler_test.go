@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockPushService is a mock implementation of PushService
+type MockPushService struct {
+	mock.Mock
+}
+
+func (m *MockPushService) RegisterDevice(ctx context.Context, userID string, req *dto.RegisterPushDeviceRequest) (*models.PushDevice, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PushDevice), args.Error(1)
+}
+
+func (m *MockPushService) ListDevices(ctx context.Context, userID string) ([]*models.PushDevice, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PushDevice), args.Error(1)
+}
+
+func (m *MockPushService) DeleteDevice(ctx context.Context, deviceID, userID string) error {
+	args := m.Called(deviceID, userID)
+	return args.Error(0)
+}
+
+func (m *MockPushService) VAPIDPublicKey() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockPushService) Deliver(ctx context.Context, notification *models.Notification) {
+	m.Called(notification)
+}
+
+func TestPushHandler_RegisterDevice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	register := func(handler *PushHandler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.RegisterDevice(c)
+		return w
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockPushService)
+		handler := NewPushHandler(mockService)
+
+		device := &models.PushDevice{ID: uuid.New(), Platform: models.PushPlatformWeb, Endpoint: "https://push.example.com/1", P256dh: "key", Auth: "secret"}
+		mockService.On("RegisterDevice", userID, mock.MatchedBy(func(req *dto.RegisterPushDeviceRequest) bool {
+			return req.Keys.P256dh == "key" && req.Keys.Auth == "secret"
+		})).Return(device, nil)
+
+		w := register(handler, `{"platform":"WEB_PUSH","endpoint":"https://push.example.com/1","keys":{"p256dh":"key","auth":"secret"}}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response dto.PushDeviceResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, device.ID.String(), response.ID)
+		// The subscription's keys aren't sent back
+		assert.NotContains(t, w.Body.String(), "secret")
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid device", func(t *testing.T) {
+		mockService := new(MockPushService)
+		handler := NewPushHandler(mockService)
+		mockService.On("RegisterDevice", userID, mock.Anything).Return(nil, models.ErrInvalidPushDevice)
+
+		w := register(handler, `{"platform":"APNS","endpoint":"not hex"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_PUSH_DEVICE", response.Code)
+	})
+}
+
+func TestPushHandler_GetVAPIDPublicKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockPushService)
+	handler := NewPushHandler(mockService)
+	mockService.On("VAPIDPublicKey").Return("", models.ErrPushUnavailable)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	handler.GetVAPIDPublicKey(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var response dto.ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "PUSH_UNAVAILABLE", response.Code)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// RebalancePlanHandler handles rebalancing execution plan HTTP requests
+type RebalancePlanHandler struct {
+	planService services.RebalancePlanService
+}
+
+// NewRebalancePlanHandler creates a new RebalancePlanHandler instance
+func NewRebalancePlanHandler(planService services.RebalancePlanService) *RebalancePlanHandler {
+	return &RebalancePlanHandler{
+		planService: planService,
+	}
+}
+
+// Create handles saving a new rebalance plan
+// POST /api/v1/portfolios/:id/rebalance-plans
+func (h *RebalancePlanHandler) Create(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.CreateRebalancePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	plan := &models.RebalancePlan{
+		Name:           strings.TrimSpace(req.Name),
+		Notes:          req.Notes,
+		StaleAfterDays: req.StaleAfterDays,
+		Trades:         make([]*models.RebalancePlanTrade, len(req.Trades)),
+	}
+	for i, trade := range req.Trades {
+		plan.Trades[i] = &models.RebalancePlanTrade{
+			Symbol:          trade.Symbol,
+			Side:            trade.Side,
+			PlannedQuantity: trade.Quantity,
+			PlannedPrice:    trade.Price,
+		}
+	}
+
+	created, err := h.planService.CreatePlan(c.Request.Context(), portfolioID, userID.(string), plan)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToRebalancePlanResponse(created))
+}
+
+// List handles retrieving all rebalance plans for a portfolio
+// GET /api/v1/portfolios/:id/rebalance-plans
+func (h *RebalancePlanHandler) List(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	plans, err := h.planService.GetPlans(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.RebalancePlanResponse, len(plans))
+	for i, plan := range plans {
+		response[i] = dto.ToRebalancePlanResponse(plan)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get handles retrieving a single rebalance plan with its execution drift
+// GET /api/v1/portfolios/:id/rebalance-plans/:plan_id
+func (h *RebalancePlanHandler) Get(c *gin.Context) {
+	portfolioID := c.Param("id")
+	planID := c.Param("plan_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	plan, err := h.planService.GetPlan(c.Request.Context(), planID, portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToRebalancePlanResponse(plan))
+}
+
+// Cancel handles abandoning an active rebalance plan
+// POST /api/v1/portfolios/:id/rebalance-plans/:plan_id/cancel
+func (h *RebalancePlanHandler) Cancel(c *gin.Context) {
+	portfolioID := c.Param("id")
+	planID := c.Param("plan_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	plan, err := h.planService.CancelPlan(c.Request.Context(), planID, portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToRebalancePlanResponse(plan))
+}
+
+// Delete handles deleting a rebalance plan
+// DELETE /api/v1/portfolios/:id/rebalance-plans/:plan_id
+func (h *RebalancePlanHandler) Delete(c *gin.Context) {
+	portfolioID := c.Param("id")
+	planID := c.Param("plan_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.planService.DeletePlan(c.Request.Context(), planID, portfolioID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ExecuteTrade handles recording the actual fill of a planned trade
+// POST /api/v1/portfolios/:id/rebalance-plans/:plan_id/trades/:trade_id/execute
+func (h *RebalancePlanHandler) ExecuteTrade(c *gin.Context) {
+	portfolioID := c.Param("id")
+	planID := c.Param("plan_id")
+	tradeID := c.Param("trade_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.ExecuteRebalanceTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	if req.TransactionID == "" && req.Date.IsZero() {
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Either transaction_id or the fill date, quantity and price are required"))
+		return
+	}
+
+	plan, err := h.planService.ExecuteTrade(c.Request.Context(), planID, tradeID, portfolioID, userID.(string), services.TradeFill{
+		TransactionID: req.TransactionID,
+		Date:          req.Date,
+		Quantity:      req.Quantity,
+		Price:         req.Price,
+		Commission:    req.Commission,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToRebalancePlanResponse(plan))
+}
+
+// SkipTrade handles marking a planned trade as not executed
+// POST /api/v1/portfolios/:id/rebalance-plans/:plan_id/trades/:trade_id/skip
+func (h *RebalancePlanHandler) SkipTrade(c *gin.Context) {
+	portfolioID := c.Param("id")
+	planID := c.Param("plan_id")
+	tradeID := c.Param("trade_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	plan, err := h.planService.SkipTrade(c.Request.Context(), planID, tradeID, portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToRebalancePlanResponse(plan))
+}
+
+// handleError maps service errors to HTTP responses
+func (h *RebalancePlanHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process rebalance plan request"))
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockRebalancePlanService is a mock implementation of RebalancePlanService
+type MockRebalancePlanService struct {
+	mock.Mock
+}
+
+func (m *MockRebalancePlanService) CreatePlan(ctx context.Context, portfolioID, userID string, plan *models.RebalancePlan) (*models.RebalancePlan, error) {
+	args := m.Called(portfolioID, userID, plan)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePlan), args.Error(1)
+}
+
+func (m *MockRebalancePlanService) GetPlan(ctx context.Context, id, portfolioID, userID string) (*models.RebalancePlan, error) {
+	args := m.Called(id, portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePlan), args.Error(1)
+}
+
+func (m *MockRebalancePlanService) GetPlans(ctx context.Context, portfolioID, userID string) ([]*models.RebalancePlan, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RebalancePlan), args.Error(1)
+}
+
+func (m *MockRebalancePlanService) ExecuteTrade(ctx context.Context, planID, tradeID, portfolioID, userID string, fill services.TradeFill) (*models.RebalancePlan, error) {
+	args := m.Called(planID, tradeID, portfolioID, userID, fill)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePlan), args.Error(1)
+}
+
+func (m *MockRebalancePlanService) SkipTrade(ctx context.Context, planID, tradeID, portfolioID, userID string) (*models.RebalancePlan, error) {
+	args := m.Called(planID, tradeID, portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePlan), args.Error(1)
+}
+
+func (m *MockRebalancePlanService) CancelPlan(ctx context.Context, id, portfolioID, userID string) (*models.RebalancePlan, error) {
+	args := m.Called(id, portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePlan), args.Error(1)
+}
+
+func (m *MockRebalancePlanService) DeletePlan(ctx context.Context, id, portfolioID, userID string) error {
+	args := m.Called(id, portfolioID, userID)
+	return args.Error(0)
+}
+
+func newTestRebalancePlanModel(portfolioID string) *models.RebalancePlan {
+	return &models.RebalancePlan{
+		ID:             uuid.New(),
+		PortfolioID:    uuid.MustParse(portfolioID),
+		Name:           "Q3 rebalance",
+		Status:         models.RebalancePlanStatusActive,
+		StaleAfterDays: 7,
+		CreatedAt:      time.Now().UTC(),
+		Trades: []*models.RebalancePlanTrade{{
+			ID:              uuid.New(),
+			Symbol:          "VTI",
+			Side:            models.TransactionTypeBuy,
+			PlannedQuantity: decimal.NewFromInt(10),
+			PlannedPrice:    decimal.NewFromInt(250),
+			Status:          models.RebalanceTradeStatusPending,
+		}},
+	}
+}
+
+func TestRebalancePlanHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockRebalancePlanService)
+	handler := NewRebalancePlanHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("CreatePlan", portfolioID, userID, mock.MatchedBy(func(plan *models.RebalancePlan) bool {
+		return plan.Name == "Q3 rebalance" && len(plan.Trades) == 1 && plan.Trades[0].PlannedPrice.Equal(decimal.NewFromInt(250))
+	})).Return(newTestRebalancePlanModel(portfolioID), nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name": "Q3 rebalance",
+		"trades": []map[string]interface{}{
+			{"symbol": "VTI", "side": "BUY", "quantity": "10", "price": "250"},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Create(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response dto.RebalancePlanResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Drift.PendingTrades)
+	assert.False(t, response.Stale)
+	mockService.AssertExpectations(t)
+}
+
+func TestRebalancePlanHandler_Create_InvalidSide(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewRebalancePlanHandler(new(MockRebalancePlanService))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name": "Q3 rebalance",
+		"trades": []map[string]interface{}{
+			{"symbol": "VTI", "side": "DIVIDEND", "quantity": "10", "price": "250"},
+		},
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+	c.Set(middleware.UserIDContextKey, uuid.New().String())
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Create(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRebalancePlanHandler_ExecuteTrade(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	planID := uuid.New().String()
+	tradeID := uuid.New().String()
+	transactionID := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		serviceErr error
+		wantStatus int
+	}{
+		{"linked fill", nil, http.StatusOK},
+		{"mismatched fill", models.ErrRebalanceFillMismatch, http.StatusUnprocessableEntity},
+		{"wrapped insufficient shares", fmt.Errorf("failed to update holdings: %w", models.ErrInsufficientShares), http.StatusUnprocessableEntity},
+		{"unknown trade", models.ErrRebalanceTradeNotFound, http.StatusNotFound},
+		{"halted symbol", fmt.Errorf("%w: VTI", models.ErrRebalanceSymbolRestricted), http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockRebalancePlanService)
+			handler := NewRebalancePlanHandler(mockService)
+
+			call := mockService.On("ExecuteTrade", planID, tradeID, portfolioID, userID, services.TradeFill{TransactionID: transactionID})
+			if tt.serviceErr != nil {
+				call.Return(nil, tt.serviceErr)
+			} else {
+				call.Return(newTestRebalancePlanModel(portfolioID), nil)
+			}
+
+			body, _ := json.Marshal(map[string]interface{}{"transaction_id": transactionID})
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{
+				{Key: "id", Value: portfolioID},
+				{Key: "plan_id", Value: planID},
+				{Key: "trade_id", Value: tradeID},
+			}
+			c.Set(middleware.UserIDContextKey, userID)
+			c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.ExecuteTrade(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRebalancePlanHandler_ExecuteTrade_MissingFill(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewRebalancePlanHandler(new(MockRebalancePlanService))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+	c.Set(middleware.UserIDContextKey, uuid.New().String())
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBufferString(`{}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.ExecuteTrade(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
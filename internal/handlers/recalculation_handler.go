@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// RecalculationHandler handles rebuilding a portfolio's holdings from its transactions
+type RecalculationHandler struct {
+	jobQueueService services.JobQueueService
+}
+
+// NewRecalculationHandler creates a new RecalculationHandler instance
+func NewRecalculationHandler(jobQueueService services.JobQueueService) *RecalculationHandler {
+	return &RecalculationHandler{
+		jobQueueService: jobQueueService,
+	}
+}
+
+// Recalculate handles queueing a replay of a portfolio's transactions to regenerate its holdings
+// and tax lots. The job's result is the recalculation report.
+// POST /api/v1/portfolios/:id/recalculate
+func (h *RecalculationHandler) Recalculate(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.RecalculationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	job, err := h.jobQueueService.Enqueue(c.Request.Context(), userID.(string), portfolioID, models.QueuedJobTypeRecalculation, req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	respondJobQueued(c, job)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *RecalculationHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.RecalculationFailed)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestRecalculationHandler_Recalculate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, dryRun := range []bool{false, true} {
+		t.Run(fmt.Sprintf("dry_run=%t", dryRun), func(t *testing.T) {
+			mockService := new(MockJobQueueService)
+			handler := NewRecalculationHandler(mockService)
+
+			portfolioID := uuid.New().String()
+			userID := uuid.New().String()
+
+			job := queuedJob(models.QueuedJobTypeRecalculation, portfolioID)
+			mockService.On("Enqueue", userID, portfolioID, models.QueuedJobTypeRecalculation, dto.RecalculationRequest{DryRun: dryRun}).Return(job, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+			c.Set(middleware.UserIDContextKey, userID)
+			c.Request = httptest.NewRequest("POST", fmt.Sprintf("/?dry_run=%t", dryRun), nil)
+
+			handler.Recalculate(c)
+
+			assertJobQueued(t, w, job)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRecalculationHandler_Recalculate_InvalidDryRun(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewRecalculationHandler(new(MockJobQueueService))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+	c.Set(middleware.UserIDContextKey, uuid.New().String())
+	c.Request = httptest.NewRequest("POST", "/?dry_run=maybe", nil)
+
+	handler.Recalculate(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRecalculationHandler_Recalculate_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"unexpected", assert.AnError, http.StatusInternalServerError, "RECALCULATION_FAILED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockJobQueueService)
+			handler := NewRecalculationHandler(mockService)
+			mockService.On("Enqueue", mock.Anything, mock.Anything, models.QueuedJobTypeRecalculation, dto.RecalculationRequest{}).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+			c.Set(middleware.UserIDContextKey, uuid.New().String())
+			c.Request = httptest.NewRequest("POST", "/", nil)
+
+			handler.Recalculate(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestRecalculationHandler_Recalculate_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewRecalculationHandler(new(MockJobQueueService))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+	c.Request = httptest.NewRequest("POST", "/", nil)
+
+	handler.Recalculate(c)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// ReconciliationHandler handles reconciling portfolios with position statements
+type ReconciliationHandler struct {
+	reconciliationService services.ReconciliationService
+}
+
+// NewReconciliationHandler creates a new ReconciliationHandler instance
+func NewReconciliationHandler(reconciliationService services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// Reconcile handles comparing a statement of the positions a portfolio's account holds with
+// its holdings, optionally creating adjustment transactions for the discrepancies
+// POST /api/v1/portfolios/:id/reconcile
+func (h *ReconciliationHandler) Reconcile(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.ReconcileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request body", err)
+		return
+	}
+
+	report, err := h.reconciliationService.Reconcile(c.Request.Context(), portfolioID, userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ReconciliationHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to reconcile portfolio"))
+}
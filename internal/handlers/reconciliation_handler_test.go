@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockReconciliationService is a mock implementation of ReconciliationService
+type MockReconciliationService struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationService) Reconcile(ctx context.Context, portfolioID, userID string, req dto.ReconcileRequest) (*dto.ReconciliationReport, error) {
+	args := m.Called(portfolioID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReconciliationReport), args.Error(1)
+}
+
+func TestReconciliationHandler_Reconcile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		body       string
+		serviceErr error
+		wantStatus int
+	}{
+		{"csv statement", `{"csv_data": "Symbol,Quantity\nAAPL,100\n"}`, nil, http.StatusOK},
+		{"positions", `{"positions": [{"symbol": "AAPL", "quantity": "100", "cost_basis": "5000"}], "create_adjustments": true}`, nil, http.StatusOK},
+		{"no statement", `{"create_adjustments": true}`, nil, http.StatusBadRequest},
+		{"position without a symbol", `{"positions": [{"quantity": "100"}]}`, nil, http.StatusBadRequest},
+		{"unreadable statement", `{"csv_data": "Symbol\nAAPL\n"}`, fmt.Errorf("%w: missing required column: quantity", models.ErrInvalidPositionStatement), http.StatusBadRequest},
+		{"unknown portfolio", `{"csv_data": "Symbol,Quantity\nAAPL,100\n"}`, models.ErrPortfolioNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockReconciliationService)
+			handler := NewReconciliationHandler(mockService)
+
+			call := mockService.On("Reconcile", portfolioID, userID, mock.AnythingOfType("dto.ReconcileRequest"))
+			if tt.serviceErr != nil {
+				call.Return(nil, tt.serviceErr)
+			} else {
+				call.Return(&dto.ReconciliationReport{
+					PortfolioID: uuid.MustParse(portfolioID),
+					Positions:   1,
+					Discrepancies: []dto.PositionDiscrepancy{{
+						Symbol:             "AAPL",
+						StatementQuantity:  decimal.NewFromInt(100),
+						HoldingQuantity:    decimal.NewFromInt(90),
+						QuantityDifference: decimal.NewFromInt(10),
+					}},
+				}, nil)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+			c.Set(middleware.UserIDContextKey, userID)
+			c.Request = httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolioID+"/reconcile", bytes.NewBufferString(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			handler.Reconcile(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code, w.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				var report dto.ReconciliationReport
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+				assert.Len(t, report.Discrepancies, 1)
+			}
+		})
+	}
+}
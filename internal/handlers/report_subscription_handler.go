@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// ReportSubscriptionHandler handles performance digest subscription HTTP requests
+type ReportSubscriptionHandler struct {
+	subscriptionService services.ReportSubscriptionService
+}
+
+// NewReportSubscriptionHandler creates a new ReportSubscriptionHandler instance
+func NewReportSubscriptionHandler(subscriptionService services.ReportSubscriptionService) *ReportSubscriptionHandler {
+	return &ReportSubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// Create handles subscribing to a weekly or monthly performance digest
+// POST /api/v1/report-subscriptions
+func (h *ReportSubscriptionHandler) Create(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.CreateReportSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	subscription, err := h.subscriptionService.Create(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToReportSubscriptionResponse(subscription))
+}
+
+// List handles retrieving the user's report subscriptions
+// GET /api/v1/report-subscriptions
+func (h *ReportSubscriptionHandler) List(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	subscriptions, err := h.subscriptionService.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := &dto.ReportSubscriptionListResponse{
+		Subscriptions: make([]*dto.ReportSubscriptionResponse, len(subscriptions)),
+	}
+	for i, subscription := range subscriptions {
+		response.Subscriptions[i] = dto.ToReportSubscriptionResponse(subscription)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// Get handles retrieving a single report subscription
+// GET /api/v1/report-subscriptions/:id
+func (h *ReportSubscriptionHandler) Get(c *gin.Context) {
+	subscriptionID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	subscription, err := h.subscriptionService.Get(c.Request.Context(), subscriptionID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToReportSubscriptionResponse(subscription))
+}
+
+// Update handles changing a report subscription's frequency, portfolios or enabled state
+// PUT /api/v1/report-subscriptions/:id
+func (h *ReportSubscriptionHandler) Update(c *gin.Context) {
+	subscriptionID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.UpdateReportSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	subscription, err := h.subscriptionService.Update(c.Request.Context(), subscriptionID, userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToReportSubscriptionResponse(subscription))
+}
+
+// Delete handles deleting a report subscription
+// DELETE /api/v1/report-subscriptions/:id
+func (h *ReportSubscriptionHandler) Delete(c *gin.Context) {
+	subscriptionID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.subscriptionService.Delete(c.Request.Context(), subscriptionID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Preview handles building the digest a subscription would have sent for the last
+// complete period, without emailing it
+// GET /api/v1/report-subscriptions/:id/preview
+func (h *ReportSubscriptionHandler) Preview(c *gin.Context) {
+	subscriptionID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	digest, err := h.subscriptionService.Preview(c.Request.Context(), subscriptionID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, digest)
+}
+
+// Unsubscribe handles the unsubscribe link in a digest email. The signed token identifies
+// the subscription, so no sign-in is needed.
+// GET /api/report-subscriptions/unsubscribe?token=...
+func (h *ReportSubscriptionHandler) Unsubscribe(c *gin.Context) {
+	subscription, err := h.subscriptionService.Unsubscribe(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToReportSubscriptionResponse(subscription))
+}
+
+// handleError maps service errors to HTTP responses
+func (h *ReportSubscriptionHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process report subscription request"))
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockReportSubscriptionService is a mock implementation of ReportSubscriptionService
+type MockReportSubscriptionService struct {
+	mock.Mock
+}
+
+func (m *MockReportSubscriptionService) Create(ctx context.Context, userID string, req *dto.CreateReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReportSubscription), args.Error(1)
+}
+
+func (m *MockReportSubscriptionService) List(ctx context.Context, userID string) ([]*models.ReportSubscription, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ReportSubscription), args.Error(1)
+}
+
+func (m *MockReportSubscriptionService) Get(ctx context.Context, id, userID string) (*models.ReportSubscription, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReportSubscription), args.Error(1)
+}
+
+func (m *MockReportSubscriptionService) Update(ctx context.Context, id, userID string, req *dto.UpdateReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	args := m.Called(id, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReportSubscription), args.Error(1)
+}
+
+func (m *MockReportSubscriptionService) Delete(ctx context.Context, id, userID string) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *MockReportSubscriptionService) Preview(ctx context.Context, id, userID string) (*dto.PerformanceDigest, error) {
+	args := m.Called(id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PerformanceDigest), args.Error(1)
+}
+
+func (m *MockReportSubscriptionService) BuildDigest(ctx context.Context, subscription *models.ReportSubscription, start, end time.Time) (*dto.PerformanceDigest, error) {
+	args := m.Called(subscription, start, end)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.PerformanceDigest), args.Error(1)
+}
+
+func (m *MockReportSubscriptionService) UnsubscribeToken(subscription *models.ReportSubscription) string {
+	args := m.Called(subscription)
+	return args.String(0)
+}
+
+func (m *MockReportSubscriptionService) VerifyUnsubscribeToken(token string) (string, string, error) {
+	args := m.Called(token)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockReportSubscriptionService) Unsubscribe(ctx context.Context, token string) (*models.ReportSubscription, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReportSubscription), args.Error(1)
+}
+
+func TestReportSubscriptionHandler_Create(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New()
+	portfolioID := uuid.New()
+	subscription := &models.ReportSubscription{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Frequency:  models.ReportFrequencyWeekly,
+		Enabled:    true,
+		Portfolios: []*models.ReportSubscriptionPortfolio{{PortfolioID: portfolioID}},
+	}
+
+	mockService := new(MockReportSubscriptionService)
+	handler := NewReportSubscriptionHandler(mockService)
+	mockService.On("Create", userID.String(), &dto.CreateReportSubscriptionRequest{
+		Frequency:    models.ReportFrequencyWeekly,
+		PortfolioIDs: []string{portfolioID.String()},
+	}).Return(subscription, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(middleware.UserIDContextKey, userID.String())
+	c.Request = httptest.NewRequest("POST", "/", strings.NewReader(
+		fmt.Sprintf(`{"frequency":"WEEKLY","portfolio_ids":["%s"]}`, portfolioID)))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Create(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response dto.ReportSubscriptionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, subscription.ID.String(), response.ID)
+	assert.Equal(t, []string{portfolioID.String()}, response.PortfolioIDs)
+	assert.NotNil(t, response.NextDigestAt)
+	mockService.AssertExpectations(t)
+
+	t.Run("rejects an unknown frequency", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(middleware.UserIDContextKey, userID.String())
+		c.Request = httptest.NewRequest("POST", "/", strings.NewReader(`{"frequency":"DAILY"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		handler.Create(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+	})
+}
+
+func TestReportSubscriptionHandler_Delete(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	subscriptionID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService := new(MockReportSubscriptionService)
+	handler := NewReportSubscriptionHandler(mockService)
+	mockService.On("Delete", subscriptionID, userID).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: subscriptionID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("DELETE", "/", nil)
+
+	handler.Delete(c)
+	c.Writer.WriteHeaderNow()
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestReportSubscriptionHandler_Unsubscribe(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	subscription := &models.ReportSubscription{ID: uuid.New(), UserID: uuid.New(), Frequency: models.ReportFrequencyMonthly}
+
+	mockService := new(MockReportSubscriptionService)
+	handler := NewReportSubscriptionHandler(mockService)
+	mockService.On("Unsubscribe", "signed-token").Return(subscription, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/?token=signed-token", nil)
+
+	handler.Unsubscribe(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.ReportSubscriptionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Enabled)
+	assert.Nil(t, response.NextDigestAt)
+	mockService.AssertExpectations(t)
+}
+
+func TestReportSubscriptionHandler_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"subscription not found", models.ErrReportSubscriptionNotFound, http.StatusNotFound, "REPORT_SUBSCRIPTION_NOT_FOUND"},
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"invalid frequency", models.ErrInvalidReportFrequency, http.StatusBadRequest, "INVALID_FREQUENCY"},
+		{"invalid token", models.ErrInvalidUnsubscribeToken, http.StatusBadRequest, "INVALID_UNSUBSCRIBE_TOKEN"},
+		{"unexpected", fmt.Errorf("database is down"), http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockReportSubscriptionService)
+			handler := NewReportSubscriptionHandler(mockService)
+			mockService.On("Preview", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+			c.Set(middleware.UserIDContextKey, uuid.New().String())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+
+			handler.Preview(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// SecurityHandler handles symbol search and security lookup HTTP requests
+type SecurityHandler struct {
+	securityService services.SecurityService
+}
+
+// NewSecurityHandler creates a new SecurityHandler instance
+func NewSecurityHandler(securityService services.SecurityService) *SecurityHandler {
+	return &SecurityHandler{
+		securityService: securityService,
+	}
+}
+
+// Search finds securities whose symbol or name matches the q query parameter
+// GET /api/v1/market/search
+func (h *SecurityHandler) Search(c *gin.Context) {
+	var req dto.SecuritySearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	securities, err := h.securityService.Search(c.Request.Context(), req.Query, req.Limit)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.SymbolSearchFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSecuritySearchResponse(req.Query, securities))
+}
+
+// Get looks up a security by its symbol
+// GET /api/v1/market/securities/:symbol
+func (h *SecurityHandler) Get(c *gin.Context) {
+	security, err := h.securityService.Lookup(c.Request.Context(), c.Param("symbol"))
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.SymbolSearchFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSecurityResponse(security))
+}
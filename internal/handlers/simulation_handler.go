@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// SimulationHandler handles retirement withdrawal simulation HTTP requests
+type SimulationHandler struct {
+	simulationService services.SimulationService
+}
+
+// NewSimulationHandler creates a new SimulationHandler instance
+func NewSimulationHandler(simulationService services.SimulationService) *SimulationHandler {
+	return &SimulationHandler{
+		simulationService: simulationService,
+	}
+}
+
+// Create handles queueing a Monte Carlo simulation of withdrawals from a portfolio. The
+// simulation is stored, and the job's result is the finished simulation.
+// POST /api/v1/portfolios/:id/simulations
+func (h *SimulationHandler) Create(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.SimulationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	job, err := h.simulationService.Create(c.Request.Context(), portfolioID, userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	respondJobQueued(c, job)
+}
+
+// List handles retrieving a portfolio's stored simulations, newest first
+// GET /api/v1/portfolios/:id/simulations
+func (h *SimulationHandler) List(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	simulations, err := h.simulationService.List(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSimulationListResponse(simulations))
+}
+
+// Get handles retrieving a stored simulation with its result
+// GET /api/v1/portfolios/:id/simulations/:simulation_id
+func (h *SimulationHandler) Get(c *gin.Context) {
+	portfolioID := c.Param("id")
+	simulationID := c.Param("simulation_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	simulation, err := h.simulationService.Get(c.Request.Context(), portfolioID, simulationID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToSimulationResponse(simulation))
+}
+
+// Delete handles deleting a stored simulation
+// DELETE /api/v1/portfolios/:id/simulations/:simulation_id
+func (h *SimulationHandler) Delete(c *gin.Context) {
+	portfolioID := c.Param("id")
+	simulationID := c.Param("simulation_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.simulationService.Delete(c.Request.Context(), portfolioID, simulationID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *SimulationHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process simulation request"))
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// SnapshotBackfillHandler handles filling in a portfolio's missing performance snapshots
+type SnapshotBackfillHandler struct {
+	backfillService services.SnapshotBackfillService
+}
+
+// NewSnapshotBackfillHandler creates a new SnapshotBackfillHandler instance
+func NewSnapshotBackfillHandler(backfillService services.SnapshotBackfillService) *SnapshotBackfillHandler {
+	return &SnapshotBackfillHandler{
+		backfillService: backfillService,
+	}
+}
+
+// Backfill handles queueing a backfill of a portfolio's daily snapshots from its transaction
+// history. The job's result is the backfill result.
+// POST /api/v1/portfolios/:id/snapshots/backfill
+func (h *SnapshotBackfillHandler) Backfill(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.SnapshotBackfillRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+	if !req.StartDate.IsZero() && !req.EndDate.IsZero() && req.EndDate.Before(req.StartDate) {
+		apierrors.Respond(c, apierrors.InvalidDateRange)
+		return
+	}
+
+	job, err := h.backfillService.StartBackfill(c.Request.Context(), portfolioID, userID.(string), req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	respondJobQueued(c, job)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *SnapshotBackfillHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.BackfillFailed)
+}
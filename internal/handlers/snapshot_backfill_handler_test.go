@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockSnapshotBackfillService is a mock implementation of SnapshotBackfillService
+type MockSnapshotBackfillService struct {
+	mock.Mock
+}
+
+func (m *MockSnapshotBackfillService) StartBackfill(ctx context.Context, portfolioID, userID string, req dto.SnapshotBackfillRequest) (*models.QueuedJob, error) {
+	args := m.Called(portfolioID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.QueuedJob), args.Error(1)
+}
+
+func (m *MockSnapshotBackfillService) Backfill(
+	ctx context.Context,
+	portfolioID, userID string,
+	req dto.SnapshotBackfillRequest,
+	onProgress func(services.SnapshotBackfillProgress),
+) (*services.SnapshotBackfillResult, error) {
+	args := m.Called(portfolioID, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.SnapshotBackfillResult), args.Error(1)
+}
+
+func newSnapshotBackfillContext(w *httptest.ResponseRecorder, portfolioID, userID, query string) *gin.Context {
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	if userID != "" {
+		c.Set(middleware.UserIDContextKey, userID)
+	}
+	c.Request = httptest.NewRequest("POST", "/"+query, nil)
+	return c
+}
+
+func TestSnapshotBackfillHandler_Backfill(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockSnapshotBackfillService)
+	handler := NewSnapshotBackfillHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	job := queuedJob(models.QueuedJobTypeSnapshotBackfill, portfolioID)
+	mockService.On("StartBackfill", portfolioID, userID, mock.MatchedBy(func(req dto.SnapshotBackfillRequest) bool {
+		return req.StartDate.Equal(time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)) &&
+			req.EndDate.Equal(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)) &&
+			req.Restart
+	})).Return(job, nil)
+
+	w := httptest.NewRecorder()
+	handler.Backfill(newSnapshotBackfillContext(w, portfolioID, userID, "?start_date=2015-01-02&end_date=2024-12-31&restart=true"))
+
+	assertJobQueued(t, w, job)
+	mockService.AssertExpectations(t)
+}
+
+func TestSnapshotBackfillHandler_Backfill_InvalidRequests(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		query    string
+		wantCode string
+	}{
+		{"unparseable date", "?start_date=yesterday", "INVALID_REQUEST"},
+		{"end before start", "?start_date=2024-06-01&end_date=2024-01-01", "INVALID_DATE_RANGE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewSnapshotBackfillHandler(new(MockSnapshotBackfillService))
+
+			w := httptest.NewRecorder()
+			handler.Backfill(newSnapshotBackfillContext(w, uuid.New().String(), uuid.New().String(), tt.query))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestSnapshotBackfillHandler_Backfill_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"portfolio not found", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"no market data", models.ErrMarketDataUnavailable, http.StatusServiceUnavailable, "MARKET_DATA_UNAVAILABLE"},
+		{"unexpected", assert.AnError, http.StatusInternalServerError, "BACKFILL_FAILED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSnapshotBackfillService)
+			handler := NewSnapshotBackfillHandler(mockService)
+			mockService.On("StartBackfill", mock.Anything, mock.Anything, dto.SnapshotBackfillRequest{}).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			handler.Backfill(newSnapshotBackfillContext(w, uuid.New().String(), uuid.New().String(), ""))
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+}
+
+func TestSnapshotBackfillHandler_Backfill_Unauthorized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewSnapshotBackfillHandler(new(MockSnapshotBackfillService))
+
+	w := httptest.NewRecorder()
+	handler.Backfill(newSnapshotBackfillContext(w, uuid.New().String(), "", ""))
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/reports"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// StatementHandler handles portfolio statement HTTP requests
+type StatementHandler struct {
+	statementService services.StatementService
+}
+
+// NewStatementHandler creates a new StatementHandler instance
+func NewStatementHandler(statementService services.StatementService) *StatementHandler {
+	return &StatementHandler{
+		statementService: statementService,
+	}
+}
+
+// Generate handles rendering a portfolio's statement for a month or quarter, as a PDF
+// download or an HTML page for previewing it
+// GET /api/v1/portfolios/:id/statements
+func (h *StatementHandler) Generate(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.StatementRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	renderer, err := reports.NewRenderer(req.Format)
+	if err != nil {
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage(err.Error()))
+		return
+	}
+
+	statement, err := h.statementService.Generate(c.Request.Context(), portfolioID, userID.(string), req.Period)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// Render in full before responding so a failure can still be reported as an error
+	var document bytes.Buffer
+	if err := renderer.RenderStatement(&document, statement); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	disposition := "attachment"
+	if req.Format == dto.StatementFormatHTML {
+		disposition = "inline"
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`%s; filename="statement-%s.%s"`,
+		disposition, statement.Period, renderer.FileExtension()))
+	c.Data(http.StatusOK, renderer.ContentType(), document.Bytes())
+}
+
+// handleError maps service errors to HTTP responses
+func (h *StatementHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.StatementFailed)
+}
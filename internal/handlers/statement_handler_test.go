@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockStatementService is a mock implementation of StatementService
+type MockStatementService struct {
+	mock.Mock
+}
+
+func (m *MockStatementService) Generate(ctx context.Context, portfolioID, userID, period string) (*dto.Statement, error) {
+	args := m.Called(portfolioID, userID, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.Statement), args.Error(1)
+}
+
+func TestStatementHandler_Generate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	statement := &dto.Statement{
+		Portfolio:   dto.CertifiedPortfolio{ID: portfolioID, Name: "Retirement", BaseCurrency: "USD"},
+		Period:      "2024-Q4",
+		Title:       "Quarterly statement, Q4 2024",
+		StartDate:   time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		GeneratedAt: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		query           string
+		wantContentType string
+		wantDisposition string
+		wantPrefix      string
+	}{
+		{"period=2024-Q4", "application/pdf", `attachment; filename="statement-2024-Q4.pdf"`, "%PDF-"},
+		{"period=2024-Q4&format=pdf", "application/pdf", `attachment; filename="statement-2024-Q4.pdf"`, "%PDF-"},
+		{"period=2024-Q4&format=html", "text/html; charset=utf-8", `inline; filename="statement-2024-Q4.html"`, "<!DOCTYPE html>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			mockService := new(MockStatementService)
+			handler := NewStatementHandler(mockService)
+			mockService.On("Generate", portfolioID, userID, "2024-Q4").Return(statement, nil)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+			c.Set(middleware.UserIDContextKey, userID)
+			c.Request = httptest.NewRequest("GET", "/?"+tt.query, nil)
+
+			handler.Generate(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantDisposition, w.Header().Get("Content-Disposition"))
+			assert.True(t, strings.HasPrefix(w.Body.String(), tt.wantPrefix))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStatementHandler_Generate_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		query      string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing period", "", nil, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"unsupported format", "period=2024-Q4&format=docx", nil, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"invalid period", "period=2024-Q9", models.ErrInvalidStatementPeriod, http.StatusBadRequest, "INVALID_PERIOD"},
+		{"portfolio not found", "period=2024-Q4", models.ErrPortfolioNotFound, http.StatusNotFound, "PORTFOLIO_NOT_FOUND"},
+		{"forbidden", "period=2024-Q4", models.ErrUnauthorizedAccess, http.StatusForbidden, "FORBIDDEN"},
+		{"ledger inconsistent", "period=2024-Q4", fmt.Errorf("%w: oversold", models.ErrLedgerReplayFailed), http.StatusUnprocessableEntity, "LEDGER_INCONSISTENT"},
+		{"unexpected", "period=2024-Q4", fmt.Errorf("database is down"), http.StatusInternalServerError, "STATEMENT_FAILED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockStatementService)
+			handler := NewStatementHandler(mockService)
+			mockService.On("Generate", mock.Anything, mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+			c.Set(middleware.UserIDContextKey, uuid.New().String())
+			c.Request = httptest.NewRequest("GET", "/?"+tt.query, nil)
+
+			handler.Generate(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response dto.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantCode, response.Code)
+			if tt.err == nil {
+				mockService.AssertNotCalled(t, "Generate", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
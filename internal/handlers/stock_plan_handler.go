@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// StockPlanHandler handles employer stock plan HTTP requests
+type StockPlanHandler struct {
+	stockPlanService services.StockPlanService
+}
+
+// NewStockPlanHandler creates a new StockPlanHandler instance
+func NewStockPlanHandler(stockPlanService services.StockPlanService) *StockPlanHandler {
+	return &StockPlanHandler{
+		stockPlanService: stockPlanService,
+	}
+}
+
+// CreateGrant handles creating a new stock plan grant
+// POST /api/v1/portfolios/:id/stock-plans/grants
+func (h *StockPlanHandler) CreateGrant(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.CreateStockPlanGrantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	grant := &models.StockPlanGrant{
+		Symbol:                req.Symbol,
+		Type:                  req.Type,
+		GrantDate:             req.GrantDate,
+		TotalShares:           req.TotalShares,
+		VestingMonths:         req.VestingMonths,
+		CliffMonths:           req.CliffMonths,
+		VestingIntervalMonths: req.VestingIntervalMonths,
+		StrikePrice:           req.StrikePrice,
+		ExpirationDate:        req.ExpirationDate,
+		DiscountPercent:       req.DiscountPercent,
+		Lookback:              req.Lookback,
+		Notes:                 req.Notes,
+	}
+	if req.VestingStartDate != nil {
+		grant.VestingStartDate = *req.VestingStartDate
+	}
+
+	created, err := h.stockPlanService.CreateGrant(c.Request.Context(), portfolioID, userID.(string), grant)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToStockPlanGrantResponse(created, true))
+}
+
+// GetGrants handles retrieving all stock plan grants for a portfolio
+// GET /api/v1/portfolios/:id/stock-plans/grants
+func (h *StockPlanHandler) GetGrants(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	grants, err := h.stockPlanService.GetGrants(c.Request.Context(), portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.StockPlanGrantResponse, len(grants))
+	for i, grant := range grants {
+		response[i] = dto.ToStockPlanGrantResponse(grant, false)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetGrant handles retrieving a single stock plan grant with its vesting schedule
+// GET /api/v1/portfolios/:id/stock-plans/grants/:grant_id
+func (h *StockPlanHandler) GetGrant(c *gin.Context) {
+	portfolioID := c.Param("id")
+	grantID := c.Param("grant_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	grant, err := h.stockPlanService.GetGrant(c.Request.Context(), grantID, portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToStockPlanGrantResponse(grant, true))
+}
+
+// GetGrantEvents handles retrieving the recorded events for a stock plan grant
+// GET /api/v1/portfolios/:id/stock-plans/grants/:grant_id/events
+func (h *StockPlanHandler) GetGrantEvents(c *gin.Context) {
+	portfolioID := c.Param("id")
+	grantID := c.Param("grant_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	events, err := h.stockPlanService.GetGrantEvents(c.Request.Context(), grantID, portfolioID, userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := make([]*dto.StockPlanEventResponse, len(events))
+	for i, event := range events {
+		response[i] = dto.ToStockPlanEventResponse(event)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteGrant handles deleting a stock plan grant
+// DELETE /api/v1/portfolios/:id/stock-plans/grants/:grant_id
+func (h *StockPlanHandler) DeleteGrant(c *gin.Context) {
+	portfolioID := c.Param("id")
+	grantID := c.Param("grant_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	if err := h.stockPlanService.DeleteGrant(c.Request.Context(), grantID, portfolioID, userID.(string)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RecordVest handles recording an RSU vest
+// POST /api/v1/portfolios/:id/stock-plans/grants/:grant_id/vest
+func (h *StockPlanHandler) RecordVest(c *gin.Context) {
+	portfolioID := c.Param("id")
+	grantID := c.Param("grant_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.RecordRSUVestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	event, err := h.stockPlanService.RecordRSUVest(c.Request.Context(), grantID, portfolioID, userID.(string), services.RSUVestInput{
+		Date:            req.Date,
+		Shares:          req.Shares,
+		SharesWithheld:  req.SharesWithheld,
+		FairMarketValue: req.FairMarketValue,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToStockPlanEventResponse(event))
+}
+
+// RecordESPPPurchase handles recording an ESPP purchase
+// POST /api/v1/portfolios/:id/stock-plans/grants/:grant_id/espp-purchase
+func (h *StockPlanHandler) RecordESPPPurchase(c *gin.Context) {
+	portfolioID := c.Param("id")
+	grantID := c.Param("grant_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.RecordESPPPurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	event, err := h.stockPlanService.RecordESPPPurchase(c.Request.Context(), grantID, portfolioID, userID.(string), services.ESPPPurchaseInput{
+		Date:            req.Date,
+		Shares:          req.Shares,
+		OfferingDateFMV: req.OfferingDateFMV,
+		PurchaseDateFMV: req.PurchaseDateFMV,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToStockPlanEventResponse(event))
+}
+
+// RecordExercise handles recording an ISO or NSO exercise
+// POST /api/v1/portfolios/:id/stock-plans/grants/:grant_id/exercise
+func (h *StockPlanHandler) RecordExercise(c *gin.Context) {
+	portfolioID := c.Param("id")
+	grantID := c.Param("grant_id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.RecordOptionExerciseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	event, err := h.stockPlanService.RecordOptionExercise(c.Request.Context(), grantID, portfolioID, userID.(string), services.OptionExerciseInput{
+		Date:            req.Date,
+		Shares:          req.Shares,
+		FairMarketValue: req.FairMarketValue,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ToStockPlanEventResponse(event))
+}
+
+// GetVestingCalendar handles retrieving upcoming vests across all grants in a portfolio
+// GET /api/v1/portfolios/:id/stock-plans/vesting-calendar
+func (h *StockPlanHandler) GetVestingCalendar(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.VestingCalendarRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	// Default to the next 12 months
+	startDate := req.StartDate
+	endDate := req.EndDate
+	if startDate.IsZero() {
+		startDate = time.Now().UTC()
+	}
+	if endDate.IsZero() {
+		endDate = startDate.AddDate(1, 0, 0)
+	}
+
+	if endDate.Before(startDate) {
+		apierrors.Respond(c, apierrors.InvalidDateRange)
+		return
+	}
+
+	entries, err := h.stockPlanService.GetVestingCalendar(c.Request.Context(), portfolioID, userID.(string), startDate, endDate)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response := &dto.VestingCalendarResponse{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Entries:   make([]*dto.VestingCalendarEntryResponse, len(entries)),
+	}
+	for i, entry := range entries {
+		response.Entries[i] = &dto.VestingCalendarEntryResponse{
+			GrantID:          entry.GrantID.String(),
+			Symbol:           entry.Symbol,
+			Type:             entry.Type,
+			Date:             entry.Date,
+			Shares:           entry.Shares,
+			CumulativeShares: entry.CumulativeShares,
+			TotalShares:      entry.TotalShares,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *StockPlanHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process stock plan request"))
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockStockPlanService is a mock implementation of StockPlanService
+type MockStockPlanService struct {
+	mock.Mock
+}
+
+func (m *MockStockPlanService) CreateGrant(ctx context.Context, portfolioID, userID string, grant *models.StockPlanGrant) (*models.StockPlanGrant, error) {
+	args := m.Called(portfolioID, userID, grant)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockPlanGrant), args.Error(1)
+}
+
+func (m *MockStockPlanService) GetGrant(ctx context.Context, id, portfolioID, userID string) (*models.StockPlanGrant, error) {
+	args := m.Called(id, portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockPlanGrant), args.Error(1)
+}
+
+func (m *MockStockPlanService) GetGrants(ctx context.Context, portfolioID, userID string) ([]*models.StockPlanGrant, error) {
+	args := m.Called(portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StockPlanGrant), args.Error(1)
+}
+
+func (m *MockStockPlanService) GetGrantEvents(ctx context.Context, id, portfolioID, userID string) ([]*models.StockPlanEvent, error) {
+	args := m.Called(id, portfolioID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.StockPlanEvent), args.Error(1)
+}
+
+func (m *MockStockPlanService) DeleteGrant(ctx context.Context, id, portfolioID, userID string) error {
+	args := m.Called(id, portfolioID, userID)
+	return args.Error(0)
+}
+
+func (m *MockStockPlanService) RecordRSUVest(ctx context.Context, grantID, portfolioID, userID string, input services.RSUVestInput) (*models.StockPlanEvent, error) {
+	args := m.Called(grantID, portfolioID, userID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockPlanEvent), args.Error(1)
+}
+
+func (m *MockStockPlanService) RecordESPPPurchase(ctx context.Context, grantID, portfolioID, userID string, input services.ESPPPurchaseInput) (*models.StockPlanEvent, error) {
+	args := m.Called(grantID, portfolioID, userID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockPlanEvent), args.Error(1)
+}
+
+func (m *MockStockPlanService) RecordOptionExercise(ctx context.Context, grantID, portfolioID, userID string, input services.OptionExerciseInput) (*models.StockPlanEvent, error) {
+	args := m.Called(grantID, portfolioID, userID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StockPlanEvent), args.Error(1)
+}
+
+func (m *MockStockPlanService) GetVestingCalendar(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) ([]*services.VestingCalendarEntry, error) {
+	args := m.Called(portfolioID, userID, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*services.VestingCalendarEntry), args.Error(1)
+}
+
+func TestStockPlanHandler_CreateGrant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockStockPlanService)
+	handler := NewStockPlanHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("CreateGrant", portfolioID, userID, mock.MatchedBy(func(g *models.StockPlanGrant) bool {
+		return g.Symbol == "ACME" && g.Type == models.StockPlanTypeRSU && g.VestingMonths == 48
+	})).Return(&models.StockPlanGrant{
+		ID:            uuid.New(),
+		PortfolioID:   uuid.MustParse(portfolioID),
+		Symbol:        "ACME",
+		Type:          models.StockPlanTypeRSU,
+		GrantDate:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		TotalShares:   decimal.NewFromInt(480),
+		VestingMonths: 48,
+		CliffMonths:   12,
+	}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"symbol":         "ACME",
+		"type":           "RSU",
+		"grant_date":     "2024-01-01T00:00:00Z",
+		"total_shares":   "480",
+		"vesting_months": 48,
+		"cliff_months":   12,
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolioID+"/stock-plans/grants", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.CreateGrant(c)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response["vesting_schedule"], 37)
+	mockService.AssertExpectations(t)
+}
+
+func TestStockPlanHandler_CreateGrant_InvalidType(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewStockPlanHandler(new(MockStockPlanService))
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"symbol":       "ACME",
+		"type":         "PHANTOM",
+		"grant_date":   "2024-01-01T00:00:00Z",
+		"total_shares": "100",
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: uuid.New().String()}}
+	c.Set(middleware.UserIDContextKey, uuid.New().String())
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.CreateGrant(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestStockPlanHandler_RecordExercise_Unvested(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockStockPlanService)
+	handler := NewStockPlanHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	grantID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("RecordOptionExercise", grantID, portfolioID, userID, mock.AnythingOfType("services.OptionExerciseInput")).
+		Return(nil, models.ErrInsufficientVestedShares)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"date":              "2024-06-01T00:00:00Z",
+		"shares":            "100",
+		"fair_market_value": "30",
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}, {Key: "grant_id", Value: grantID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.RecordExercise(c)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestStockPlanHandler_GetGrant_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockStockPlanService)
+	handler := NewStockPlanHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	grantID := uuid.New().String()
+	userID := uuid.New().String()
+
+	mockService.On("GetGrant", grantID, portfolioID, userID).Return(nil, models.ErrStockPlanGrantNotFound)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}, {Key: "grant_id", Value: grantID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	handler.GetGrant(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestStockPlanHandler_GetVestingCalendar(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockStockPlanService)
+	handler := NewStockPlanHandler(mockService)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	mockService.On("GetVestingCalendar", portfolioID, userID,
+		mock.MatchedBy(func(d time.Time) bool { return d.Equal(start) }),
+		mock.MatchedBy(func(d time.Time) bool { return d.Equal(end) }),
+	).Return([]*services.VestingCalendarEntry{
+		{
+			GrantID:          uuid.New(),
+			Symbol:           "ACME",
+			Type:             models.StockPlanTypeRSU,
+			Date:             time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			Shares:           decimal.NewFromInt(25),
+			CumulativeShares: decimal.NewFromInt(125),
+			TotalShares:      decimal.NewFromInt(400),
+		},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: portfolioID}}
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("GET", "/?start_date=2025-01-01&end_date=2025-12-31", nil)
+
+	handler.GetVestingCalendar(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response["entries"], 1)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// TagHandler handles HTTP requests about the tags on a user's portfolios and transactions
+type TagHandler struct {
+	tagService services.TagService
+}
+
+// NewTagHandler creates a new TagHandler instance
+func NewTagHandler(tagService services.TagService) *TagHandler {
+	return &TagHandler{
+		tagService: tagService,
+	}
+}
+
+// List lists the user's tags with how many portfolios and transactions carry each
+// GET /api/v1/tags
+func (h *TagHandler) List(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	usages, err := h.tagService.List(c.Request.Context(), userID.(string))
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve tags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToTagListResponse(usages))
+}
+
+// GetPerformance reports the performance of every portfolio carrying a tag, with totals for
+// each base currency. The period defaults to the last year.
+// GET /api/v1/tags/:tag/performance
+func (h *TagHandler) GetPerformance(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.PerformanceMetricsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+
+	startDate := req.StartDate
+	endDate := req.EndDate
+	if startDate.IsZero() {
+		startDate = time.Now().AddDate(-1, 0, 0)
+	}
+	if endDate.IsZero() {
+		endDate = time.Now()
+	}
+	if !endDate.After(startDate) {
+		apierrors.Respond(c, apierrors.InvalidDateRange)
+		return
+	}
+
+	performance, err := h.tagService.GetPerformance(c.Request.Context(), userID.(string), c.Param("tag"), startDate, endDate)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to calculate performance"))
+		return
+	}
+
+	c.JSON(http.StatusOK, performance)
+}
@@ -1,42 +1,46 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/export"
 	"github.com/lenon/portfolios/internal/middleware"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/services"
-	"github.com/shopspring/decimal"
 )
 
 // TaxLotHandler handles tax lot-related HTTP requests
 type TaxLotHandler struct {
-	taxLotService services.TaxLotService
+	taxLotService   services.TaxLotService
+	jobQueueService services.JobQueueService
 }
 
 // NewTaxLotHandler creates a new TaxLotHandler instance
-func NewTaxLotHandler(taxLotService services.TaxLotService) *TaxLotHandler {
+func NewTaxLotHandler(taxLotService services.TaxLotService, jobQueueService services.JobQueueService) *TaxLotHandler {
 	return &TaxLotHandler{
-		taxLotService: taxLotService,
+		taxLotService:   taxLotService,
+		jobQueueService: jobQueueService,
 	}
 }
 
 // GetAll retrieves all tax lots for a portfolio
-// GET /api/v1/portfolios/:portfolio_id/tax-lots
+// GET /api/v1/portfolios/:id/tax-lots
 func (h *TaxLotHandler) GetAll(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 	symbol := c.Query("symbol")
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
@@ -45,31 +49,13 @@ func (h *TaxLotHandler) GetAll(c *gin.Context) {
 
 	// If symbol is provided, filter by symbol
 	if symbol != "" {
-		taxLots, err = h.taxLotService.GetByPortfolioIDAndSymbol(portfolioID, symbol, userID.(string))
+		taxLots, err = h.taxLotService.GetByPortfolioIDAndSymbol(c.Request.Context(), portfolioID, symbol, userID.(string))
 	} else {
-		taxLots, err = h.taxLotService.GetByPortfolioID(portfolioID, userID.(string))
+		taxLots, err = h.taxLotService.GetByPortfolioID(c.Request.Context(), portfolioID, userID.(string))
 	}
 
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied to this portfolio",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve tax lots",
-			Code:  "RETRIEVAL_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve tax lots"))
 		return
 	}
 
@@ -101,34 +87,13 @@ func (h *TaxLotHandler) GetByID(c *gin.Context) {
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
-	taxLot, err := h.taxLotService.GetByID(id, userID.(string))
+	taxLot, err := h.taxLotService.GetByID(c.Request.Context(), id, userID.(string))
 	if err != nil {
-		if err == models.ErrTaxLotNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Tax lot not found",
-				Code:  "TAX_LOT_NOT_FOUND",
-			})
-			return
-		}
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied to this tax lot",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve tax lot",
-			Code:  "RETRIEVAL_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve tax lot"))
 		return
 	}
 
@@ -149,26 +114,26 @@ func (h *TaxLotHandler) GetByID(c *gin.Context) {
 }
 
 // AllocateSale shows how a sale would be allocated to tax lots
-// POST /api/v1/portfolios/:portfolio_id/tax-lots/allocate
+// POST /api/v1/portfolios/:id/tax-lots/allocate
 func (h *TaxLotHandler) AllocateSale(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 
 	var req dto.TaxLotAllocationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	// Validator tags cannot compare decimals, so check the quantity here
+	if !req.Quantity.IsPositive() {
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Quantity must be greater than zero"))
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
@@ -182,14 +147,12 @@ func (h *TaxLotHandler) AllocateSale(c *gin.Context) {
 	case "SPECIFIC_LOT":
 		method = models.CostBasisSpecificLot
 	default:
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid cost basis method",
-			Code:  "INVALID_METHOD",
-		})
+		apierrors.Respond(c, apierrors.InvalidMethod)
 		return
 	}
 
 	allocations, err := h.taxLotService.AllocateSale(
+		c.Request.Context(),
 		portfolioID,
 		req.Symbol,
 		userID.(string),
@@ -198,32 +161,7 @@ func (h *TaxLotHandler) AllocateSale(c *gin.Context) {
 	)
 
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied to this portfolio",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
-		if err == models.ErrInsufficientShares {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: "Insufficient shares for sale",
-				Code:  "INSUFFICIENT_SHARES",
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to allocate sale",
-			Code:  "ALLOCATION_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.AllocationFailed)
 		return
 	}
 
@@ -243,58 +181,36 @@ func (h *TaxLotHandler) AllocateSale(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// IdentifyTaxLossOpportunities identifies potential tax-loss harvesting opportunities
-// GET /api/v1/portfolios/:portfolio_id/tax-lots/harvest
+// IdentifyTaxLossOpportunities identifies tax lots whose loss at current prices is at least
+// the threshold percentage of their cost basis
+// GET /api/v1/portfolios/:id/tax-lots/harvest
 func (h *TaxLotHandler) IdentifyTaxLossOpportunities(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 
 	// Get threshold from query params (default to -3%)
 	thresholdStr := c.DefaultQuery("threshold", "-3")
 	threshold, err := strconv.ParseFloat(thresholdStr, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid threshold value",
-			Code:  "INVALID_THRESHOLD",
-		})
+		apierrors.Respond(c, apierrors.InvalidThreshold)
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	opportunities, err := h.taxLotService.IdentifyTaxLossOpportunities(
+		c.Request.Context(),
 		portfolioID,
 		userID.(string),
 		decimal.NewFromFloat(threshold),
 	)
 
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied to this portfolio",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to identify tax loss opportunities",
-			Code:  "IDENTIFICATION_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.IdentificationFailed)
 		return
 	}
 
@@ -302,108 +218,131 @@ func (h *TaxLotHandler) IdentifyTaxLossOpportunities(c *gin.Context) {
 	response := make([]*dto.TaxLossOpportunityResponse, len(opportunities))
 	for i, opp := range opportunities {
 		response[i] = &dto.TaxLossOpportunityResponse{
-			Symbol:          opp.Symbol,
-			CurrentQuantity: opp.CurrentQuantity,
-			CostBasis:       opp.CostBasis,
-			CurrentValue:    opp.CurrentValue,
-			UnrealizedLoss:  opp.UnrealizedLoss,
-			LossPercent:     opp.LossPercent,
+			TaxLotID:         opp.TaxLotID,
+			Symbol:           opp.Symbol,
+			PurchaseDate:     opp.PurchaseDate,
+			CurrentQuantity:  opp.CurrentQuantity,
+			CostBasis:        opp.CostBasis,
+			CurrentPrice:     opp.CurrentPrice,
+			CurrentValue:     opp.CurrentValue,
+			UnrealizedLoss:   opp.UnrealizedLoss,
+			LossPercent:      opp.LossPercent,
+			IsLongTerm:       opp.IsLongTerm,
+			WashSaleRisk:     opp.WashSaleRisk,
+			LastPurchaseDate: opp.LastPurchaseDate,
+			Replacements:     opp.Replacements,
 		}
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// GenerateTaxReport generates a tax report for a given year
-// POST /api/v1/portfolios/:portfolio_id/tax-lots/report
+// GenerateTaxReport queues generating a tax report for a given year. The job's result is the
+// tax report.
+// POST /api/v1/portfolios/:id/tax-lots/report
 func (h *TaxLotHandler) GenerateTaxReport(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 
 	var req dto.TaxReportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
-	report, err := h.taxLotService.GenerateTaxReport(
-		portfolioID,
-		userID.(string),
-		req.TaxYear,
-	)
+	job, err := h.jobQueueService.Enqueue(c.Request.Context(), userID.(string), portfolioID, models.QueuedJobTypeTaxReport, req)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.ReportGenerationFailed)
+		return
+	}
+
+	respondJobQueued(c, job)
+}
+
+// SetTaxRegime changes the tax regime a portfolio's sales are allocated and reported under
+// PUT /api/v1/portfolios/:id/tax-regime
+func (h *TaxLotHandler) SetTaxRegime(c *gin.Context) {
+	portfolioID := c.Param("id")
 
+	var req dto.TaxRegimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	// Get user ID from context
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	portfolio, err := h.taxLotService.SetTaxRegime(c.Request.Context(), portfolioID, userID.(string), req.TaxRegime)
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied to this portfolio",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
+		apierrors.RespondError(c, err, apierrors.UpdateFailed)
+		return
+	}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to generate tax report",
-			Code:  "REPORT_GENERATION_FAILED",
-		})
+	c.JSON(http.StatusOK, dto.ToPortfolioResponse(portfolio))
+}
+
+// ExportTaxForm handles downloading a tax year's realized gains as Form 8949 CSV or as a TXF
+// file for tax software. The forms are American, so only portfolios under the US tax regime
+// can be exported.
+// GET /api/v1/portfolios/:id/tax/export
+func (h *TaxLotHandler) ExportTaxForm(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
-	// Convert to response DTO
-	shortTermGains := make([]*dto.RealizedGainResponse, len(report.ShortTermGains))
-	for i, gain := range report.ShortTermGains {
-		shortTermGains[i] = &dto.RealizedGainResponse{
-			Symbol:       gain.Symbol,
-			PurchaseDate: gain.PurchaseDate,
-			SaleDate:     gain.SaleDate,
-			Quantity:     gain.Quantity,
-			CostBasis:    gain.CostBasis,
-			Proceeds:     gain.Proceeds,
-			Gain:         gain.Gain,
-			IsLongTerm:   gain.IsLongTerm,
-		}
+	var req dto.TaxExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
 	}
 
-	longTermGains := make([]*dto.RealizedGainResponse, len(report.LongTermGains))
-	for i, gain := range report.LongTermGains {
-		longTermGains[i] = &dto.RealizedGainResponse{
-			Symbol:       gain.Symbol,
-			PurchaseDate: gain.PurchaseDate,
-			SaleDate:     gain.SaleDate,
-			Quantity:     gain.Quantity,
-			CostBasis:    gain.CostBasis,
-			Proceeds:     gain.Proceeds,
-			Gain:         gain.Gain,
-			IsLongTerm:   gain.IsLongTerm,
-		}
+	encoder, err := export.NewTaxFormEncoder(req.Format, c.Writer, time.Now().UTC())
+	if err != nil {
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage(err.Error()))
+		return
 	}
 
-	response := &dto.TaxReportResponse{
-		Year:               report.Year,
-		ShortTermGains:     shortTermGains,
-		LongTermGains:      longTermGains,
-		TotalShortTermGain: report.TotalShortTermGain,
-		TotalLongTermGain:  report.TotalLongTermGain,
-		TotalGain:          report.TotalGain,
+	report, err := h.taxLotService.GenerateTaxReport(c.Request.Context(), portfolioID, userID.(string), req.Year)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.ExportFailed)
+		return
+	}
+	if report.Regime != models.TaxRegimeUS {
+		apierrors.RespondError(c, models.ErrUnsupportedTaxForm, apierrors.ExportFailed)
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	// Short-term sales go on Part I of Form 8949 and long-term sales on Part II. Capital gain
+	// distributions aren't sales and are reported straight on Schedule D, so they are left out.
+	stream := newExportStream(c, encoder.ContentType(),
+		fmt.Sprintf("tax-%s-%d.%s", portfolioID, req.Year, encoder.FileExtension()))
+	stream.start()
+	gains := append(append([]*services.RealizedGain{}, report.ShortTermGains...), report.LongTermGains...)
+	for _, gain := range gains {
+		if gain.Rule == services.GainRuleCapitalGainDistribution {
+			continue
+		}
+		if err = encoder.Encode(gain); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = encoder.Flush()
+	}
+	stream.finish(err)
 }
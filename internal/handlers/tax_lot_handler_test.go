@@ -2,21 +2,25 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/services"
-	"github.com/shopspring/decimal"
-	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
 )
 
 // TaxLotServiceMock is a mock implementation of TaxLotService for testing
@@ -24,7 +28,7 @@ type TaxLotServiceMock struct {
 	mock.Mock
 }
 
-func (m *TaxLotServiceMock) GetByID(id, userID string) (*models.TaxLot, error) {
+func (m *TaxLotServiceMock) GetByID(ctx context.Context, id, userID string) (*models.TaxLot, error) {
 	args := m.Called(id, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -32,7 +36,7 @@ func (m *TaxLotServiceMock) GetByID(id, userID string) (*models.TaxLot, error) {
 	return args.Get(0).(*models.TaxLot), args.Error(1)
 }
 
-func (m *TaxLotServiceMock) GetByPortfolioID(portfolioID, userID string) ([]*models.TaxLot, error) {
+func (m *TaxLotServiceMock) GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.TaxLot, error) {
 	args := m.Called(portfolioID, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -40,7 +44,7 @@ func (m *TaxLotServiceMock) GetByPortfolioID(portfolioID, userID string) ([]*mod
 	return args.Get(0).([]*models.TaxLot), args.Error(1)
 }
 
-func (m *TaxLotServiceMock) GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) ([]*models.TaxLot, error) {
+func (m *TaxLotServiceMock) GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) ([]*models.TaxLot, error) {
 	args := m.Called(portfolioID, symbol, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -48,7 +52,7 @@ func (m *TaxLotServiceMock) GetByPortfolioIDAndSymbol(portfolioID, symbol, userI
 	return args.Get(0).([]*models.TaxLot), args.Error(1)
 }
 
-func (m *TaxLotServiceMock) AllocateSale(portfolioID, symbol, userID string, quantity decimal.Decimal, method models.CostBasisMethod) ([]*services.LotAllocation, error) {
+func (m *TaxLotServiceMock) AllocateSale(ctx context.Context, portfolioID, symbol, userID string, quantity decimal.Decimal, method models.CostBasisMethod) ([]*services.LotAllocation, error) {
 	args := m.Called(portfolioID, symbol, userID, quantity, method)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -56,7 +60,7 @@ func (m *TaxLotServiceMock) AllocateSale(portfolioID, symbol, userID string, qua
 	return args.Get(0).([]*services.LotAllocation), args.Error(1)
 }
 
-func (m *TaxLotServiceMock) IdentifyTaxLossOpportunities(portfolioID, userID string, minLossPercent decimal.Decimal) ([]*services.TaxLossOpportunity, error) {
+func (m *TaxLotServiceMock) IdentifyTaxLossOpportunities(ctx context.Context, portfolioID, userID string, minLossPercent decimal.Decimal) ([]*services.TaxLossOpportunity, error) {
 	args := m.Called(portfolioID, userID, minLossPercent)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -64,7 +68,7 @@ func (m *TaxLotServiceMock) IdentifyTaxLossOpportunities(portfolioID, userID str
 	return args.Get(0).([]*services.TaxLossOpportunity), args.Error(1)
 }
 
-func (m *TaxLotServiceMock) GenerateTaxReport(portfolioID, userID string, taxYear int) (*services.TaxReport, error) {
+func (m *TaxLotServiceMock) GenerateTaxReport(ctx context.Context, portfolioID, userID string, taxYear int) (*services.TaxReport, error) {
 	args := m.Called(portfolioID, userID, taxYear)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -72,9 +76,17 @@ func (m *TaxLotServiceMock) GenerateTaxReport(portfolioID, userID string, taxYea
 	return args.Get(0).(*services.TaxReport), args.Error(1)
 }
 
+func (m *TaxLotServiceMock) SetTaxRegime(ctx context.Context, portfolioID, userID string, regime models.TaxRegime) (*models.Portfolio, error) {
+	args := m.Called(portfolioID, userID, regime)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Portfolio), args.Error(1)
+}
+
 func TestNewTaxLotHandler(t *testing.T) {
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	assert.NotNil(t, handler)
 	assert.NotNil(t, handler.taxLotService)
@@ -83,7 +95,7 @@ func TestNewTaxLotHandler(t *testing.T) {
 func TestTaxLotHandler_GetAll_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	userID := uuid.New().String()
 	portfolioID := uuid.New()
@@ -104,7 +116,7 @@ func TestTaxLotHandler_GetAll_Success(t *testing.T) {
 	serviceMock.On("GetByPortfolioID", portfolioID.String(), userID).Return(taxLots, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/tax-lots", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/tax-lots", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, userID)
 		handler.GetAll(c)
 	})
@@ -124,7 +136,7 @@ func TestTaxLotHandler_GetAll_Success(t *testing.T) {
 func TestTaxLotHandler_GetAll_WithSymbol(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	userID := uuid.New().String()
 	portfolioID := uuid.New()
@@ -144,7 +156,7 @@ func TestTaxLotHandler_GetAll_WithSymbol(t *testing.T) {
 	serviceMock.On("GetByPortfolioIDAndSymbol", portfolioID.String(), symbol, userID).Return(taxLots, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/tax-lots", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/tax-lots", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, userID)
 		handler.GetAll(c)
 	})
@@ -160,10 +172,10 @@ func TestTaxLotHandler_GetAll_WithSymbol(t *testing.T) {
 func TestTaxLotHandler_GetAll_Unauthorized(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/tax-lots", handler.GetAll)
+	router.GET("/api/v1/portfolios/:id/tax-lots", handler.GetAll)
 
 	req := httptest.NewRequest("GET", "/api/v1/portfolios/"+uuid.New().String()+"/tax-lots", nil)
 	w := httptest.NewRecorder()
@@ -175,7 +187,7 @@ func TestTaxLotHandler_GetAll_Unauthorized(t *testing.T) {
 func TestTaxLotHandler_GetAll_PortfolioNotFound(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	userID := uuid.New().String()
 	portfolioID := uuid.New().String()
@@ -183,7 +195,7 @@ func TestTaxLotHandler_GetAll_PortfolioNotFound(t *testing.T) {
 	serviceMock.On("GetByPortfolioID", portfolioID, userID).Return(nil, models.ErrPortfolioNotFound)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/tax-lots", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/tax-lots", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, userID)
 		handler.GetAll(c)
 	})
@@ -199,7 +211,7 @@ func TestTaxLotHandler_GetAll_PortfolioNotFound(t *testing.T) {
 func TestTaxLotHandler_GetByID_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	userID := uuid.New().String()
 	taxLotID := uuid.New()
@@ -228,19 +240,97 @@ func TestTaxLotHandler_GetByID_Success(t *testing.T) {
 	serviceMock.AssertExpectations(t)
 }
 
-// Test commented out due to gin validation issue with decimal.Decimal type
-// func TestTaxLotHandler_AllocateSale_Success(t *testing.T) {
-// 	...
-// }
+// serveAllocateSale posts an allocation request to the handler as the given user
+func serveAllocateSale(handler *TaxLotHandler, userID, portfolioID, body string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.POST("/api/v1/portfolios/:id/tax-lots/allocate", func(c *gin.Context) {
+		c.Set(middleware.UserIDContextKey, userID)
+		handler.AllocateSale(c)
+	})
+
+	req := httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolioID+"/tax-lots/allocate", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTaxLotHandler_AllocateSale_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := new(TaxLotServiceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
+
+	userID := uuid.New().String()
+	portfolioID := uuid.New()
+	lot := &models.TaxLot{
+		ID:           uuid.New(),
+		PortfolioID:  portfolioID,
+		Symbol:       "AAPL",
+		PurchaseDate: time.Now().AddDate(-2, 0, 0),
+		Quantity:     decimal.NewFromInt(10),
+		CostBasis:    decimal.NewFromInt(1000),
+	}
+	allocations := []*services.LotAllocation{
+		{TaxLot: lot, Quantity: decimal.NewFromInt(4), CostBasis: decimal.NewFromInt(400), IsLongTerm: true},
+	}
+
+	serviceMock.On("AllocateSale", portfolioID.String(), "AAPL", userID, decimal.NewFromInt(4), models.CostBasisFIFO).
+		Return(allocations, nil)
+
+	w := serveAllocateSale(handler, userID, portfolioID.String(), `{"symbol":"AAPL","quantity":"4","method":"FIFO"}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []*dto.LotAllocationResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response, 1)
+	assert.Equal(t, lot.ID.String(), response[0].TaxLotID)
+	assert.True(t, response[0].IsLongTerm)
+	serviceMock.AssertExpectations(t)
+}
+
+func TestTaxLotHandler_AllocateSale_NonPositiveQuantity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := new(TaxLotServiceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
+
+	w := serveAllocateSale(handler, uuid.New().String(), uuid.New().String(), `{"symbol":"AAPL","quantity":"-1","method":"FIFO"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	serviceMock.AssertNotCalled(t, "AllocateSale")
+}
+
+func TestTaxLotHandler_AllocateSale_InvalidMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := new(TaxLotServiceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
-// Tests commented out due to gin validation issue with decimal.Decimal type
-// func TestTaxLotHandler_AllocateSale_InvalidMethod(t *testing.T) { ... }
-// func TestTaxLotHandler_AllocateSale_InsufficientShares(t *testing.T) { ... }
+	w := serveAllocateSale(handler, uuid.New().String(), uuid.New().String(), `{"symbol":"AAPL","quantity":"4","method":"HIFO"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	serviceMock.AssertNotCalled(t, "AllocateSale")
+}
+
+func TestTaxLotHandler_AllocateSale_InsufficientShares(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serviceMock := new(TaxLotServiceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
+
+	userID := uuid.New().String()
+	portfolioID := uuid.New().String()
+	serviceMock.On("AllocateSale", portfolioID, "AAPL", userID, decimal.NewFromInt(40), models.CostBasisLIFO).
+		Return(nil, models.ErrInsufficientShares)
+
+	w := serveAllocateSale(handler, userID, portfolioID, `{"symbol":"AAPL","quantity":"40","method":"LIFO"}`)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "INSUFFICIENT_SHARES")
+	serviceMock.AssertExpectations(t)
+}
 
 func TestTaxLotHandler_IdentifyTaxLossOpportunities_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	userID := uuid.New().String()
 	portfolioID := uuid.New()
@@ -259,7 +349,7 @@ func TestTaxLotHandler_IdentifyTaxLossOpportunities_Success(t *testing.T) {
 	serviceMock.On("IdentifyTaxLossOpportunities", portfolioID.String(), userID, decimal.NewFromFloat(-3)).Return(opportunities, nil)
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/tax-lots/harvest", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/tax-lots/harvest", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, userID)
 		handler.IdentifyTaxLossOpportunities(c)
 	})
@@ -275,13 +365,13 @@ func TestTaxLotHandler_IdentifyTaxLossOpportunities_Success(t *testing.T) {
 func TestTaxLotHandler_IdentifyTaxLossOpportunities_InvalidThreshold(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	userID := uuid.New().String()
 	portfolioID := uuid.New()
 
 	router := gin.New()
-	router.GET("/api/v1/portfolios/:portfolio_id/tax-lots/harvest", func(c *gin.Context) {
+	router.GET("/api/v1/portfolios/:id/tax-lots/harvest", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, userID)
 		handler.IdentifyTaxLossOpportunities(c)
 	})
@@ -295,25 +385,17 @@ func TestTaxLotHandler_IdentifyTaxLossOpportunities_InvalidThreshold(t *testing.
 
 func TestTaxLotHandler_GenerateTaxReport_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
-	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	jobQueueMock := new(MockJobQueueService)
+	handler := NewTaxLotHandler(new(TaxLotServiceMock), jobQueueMock)
 
 	userID := uuid.New().String()
 	portfolioID := uuid.New()
 
-	report := &services.TaxReport{
-		Year:               2024,
-		ShortTermGains:     []*services.RealizedGain{},
-		LongTermGains:      []*services.RealizedGain{},
-		TotalShortTermGain: decimal.Zero,
-		TotalLongTermGain:  decimal.Zero,
-		TotalGain:          decimal.Zero,
-	}
-
-	serviceMock.On("GenerateTaxReport", portfolioID.String(), userID, 2024).Return(report, nil)
+	job := queuedJob(models.QueuedJobTypeTaxReport, portfolioID.String())
+	jobQueueMock.On("Enqueue", userID, portfolioID.String(), models.QueuedJobTypeTaxReport, dto.TaxReportRequest{TaxYear: 2024}).Return(job, nil)
 
 	router := gin.New()
-	router.POST("/api/v1/portfolios/:portfolio_id/tax-lots/report", func(c *gin.Context) {
+	router.POST("/api/v1/portfolios/:id/tax-lots/report", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, userID)
 		handler.GenerateTaxReport(c)
 	})
@@ -325,20 +407,45 @@ func TestTaxLotHandler_GenerateTaxReport_Success(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
-	serviceMock.AssertExpectations(t)
+	assertJobQueued(t, w, job)
+	jobQueueMock.AssertExpectations(t)
+}
+
+func TestTaxLotHandler_GenerateTaxReport_PortfolioNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jobQueueMock := new(MockJobQueueService)
+	handler := NewTaxLotHandler(new(TaxLotServiceMock), jobQueueMock)
+
+	userID := uuid.New().String()
+	portfolioID := uuid.New()
+
+	jobQueueMock.On("Enqueue", userID, portfolioID.String(), models.QueuedJobTypeTaxReport, dto.TaxReportRequest{TaxYear: 2024}).Return(nil, models.ErrPortfolioNotFound)
+
+	router := gin.New()
+	router.POST("/api/v1/portfolios/:id/tax-lots/report", func(c *gin.Context) {
+		c.Set(middleware.UserIDContextKey, userID)
+		handler.GenerateTaxReport(c)
+	})
+
+	body, _ := json.Marshal(dto.TaxReportRequest{TaxYear: 2024})
+	req := httptest.NewRequest("POST", "/api/v1/portfolios/"+portfolioID.String()+"/tax-lots/report", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
 func TestTaxLotHandler_GenerateTaxReport_InvalidRequest(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	serviceMock := new(TaxLotServiceMock)
-	handler := NewTaxLotHandler(serviceMock)
+	handler := NewTaxLotHandler(serviceMock, nil)
 
 	userID := uuid.New().String()
 	portfolioID := uuid.New()
 
 	router := gin.New()
-	router.POST("/api/v1/portfolios/:portfolio_id/tax-lots/report", func(c *gin.Context) {
+	router.POST("/api/v1/portfolios/:id/tax-lots/report", func(c *gin.Context) {
 		c.Set(middleware.UserIDContextKey, userID)
 		handler.GenerateTaxReport(c)
 	})
@@ -350,3 +457,141 @@ func TestTaxLotHandler_GenerateTaxReport_InvalidRequest(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
+
+func TestTaxLotHandler_SetTaxRegime(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New().String()
+	portfolioID := uuid.New()
+
+	newRouter := func(serviceMock *TaxLotServiceMock) *gin.Engine {
+		handler := NewTaxLotHandler(serviceMock, nil)
+		router := gin.New()
+		router.PUT("/api/v1/portfolios/:id/tax-regime", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID)
+			handler.SetTaxRegime(c)
+		})
+		return router
+	}
+	put := func(router *gin.Engine, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/v1/portfolios/"+portfolioID.String()+"/tax-regime", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("changes the regime", func(t *testing.T) {
+		serviceMock := new(TaxLotServiceMock)
+		portfolio := &models.Portfolio{ID: portfolioID, Name: "ISA", TaxRegime: models.TaxRegimeUK}
+		serviceMock.On("SetTaxRegime", portfolioID.String(), userID, models.TaxRegimeUK).Return(portfolio, nil)
+
+		w := put(newRouter(serviceMock), `{"tax_regime":"UK"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response dto.PortfolioResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, models.TaxRegimeUK, response.TaxRegime)
+		serviceMock.AssertExpectations(t)
+	})
+
+	t.Run("unknown regime", func(t *testing.T) {
+		w := put(newRouter(new(TaxLotServiceMock)), `{"tax_regime":"FR"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("portfolio not found", func(t *testing.T) {
+		serviceMock := new(TaxLotServiceMock)
+		serviceMock.On("SetTaxRegime", portfolioID.String(), userID, models.TaxRegimeCA).Return(nil, models.ErrPortfolioNotFound)
+
+		w := put(newRouter(serviceMock), `{"tax_regime":"CA"}`)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestTaxLotHandler_ExportTaxForm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	userID := uuid.New().String()
+	portfolioID := uuid.New().String()
+
+	get := func(serviceMock *TaxLotServiceMock, query string) *httptest.ResponseRecorder {
+		handler := NewTaxLotHandler(serviceMock, nil)
+		router := gin.New()
+		router.GET("/api/v1/portfolios/:id/tax/export", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID)
+			handler.ExportTaxForm(c)
+		})
+		req := httptest.NewRequest("GET", "/api/v1/portfolios/"+portfolioID+"/tax/export?"+query, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("short-term gains come before long-term gains", func(t *testing.T) {
+		serviceMock := new(TaxLotServiceMock)
+		sold := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		gain := func(symbol string, longTerm bool) *services.RealizedGain {
+			return &services.RealizedGain{
+				Symbol: symbol, PurchaseDate: sold.AddDate(-2, 0, 0), SaleDate: sold, Quantity: decimal.NewFromInt(1),
+				CostBasis: decimal.NewFromInt(10), Proceeds: decimal.NewFromInt(12), Gain: decimal.NewFromInt(2), IsLongTerm: longTerm,
+			}
+		}
+		serviceMock.On("GenerateTaxReport", portfolioID, userID, 2024).Return(&services.TaxReport{
+			Year: 2024, Regime: models.TaxRegimeUS,
+			ShortTermGains: []*services.RealizedGain{gain("AAPL", false)},
+			LongTermGains:  []*services.RealizedGain{gain("VTI", true)},
+		}, nil)
+
+		w := get(serviceMock, "year=2024&format=8949-csv")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="tax-`+portfolioID+`-2024.csv"`, w.Header().Get("Content-Disposition"))
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.True(t, strings.HasPrefix(lines[1], "1 sh AAPL,"))
+		assert.True(t, strings.HasPrefix(lines[2], "1 sh VTI,"))
+	})
+
+	t.Run("capital gain distributions are left out", func(t *testing.T) {
+		serviceMock := new(TaxLotServiceMock)
+		paid := time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+		serviceMock.On("GenerateTaxReport", portfolioID, userID, 2024).Return(&services.TaxReport{
+			Year: 2024, Regime: models.TaxRegimeUS,
+			LongTermGains: []*services.RealizedGain{{
+				Symbol: "VFIAX", PurchaseDate: paid, SaleDate: paid, Quantity: decimal.Zero, CostBasis: decimal.Zero,
+				Proceeds: decimal.NewFromInt(40), Gain: decimal.NewFromInt(40), IsLongTerm: true, Rule: services.GainRuleCapitalGainDistribution,
+			}},
+		}, nil)
+
+		w := get(serviceMock, "year=2024&format=8949-csv")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		assert.Len(t, lines, 1)
+	})
+
+	t.Run("portfolios under another tax regime", func(t *testing.T) {
+		serviceMock := new(TaxLotServiceMock)
+		serviceMock.On("GenerateTaxReport", portfolioID, userID, 2024).Return(&services.TaxReport{Year: 2024, Regime: models.TaxRegimeUK}, nil)
+
+		w := get(serviceMock, "year=2024&format=txf")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Contains(t, w.Body.String(), "UNSUPPORTED_TAX_FORM")
+	})
+
+	t.Run("missing year", func(t *testing.T) {
+		w := get(new(TaxLotServiceMock), "format=txf")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		w := get(new(TaxLotServiceMock), "year=2024&format=pdf")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
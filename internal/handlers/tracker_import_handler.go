@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// TrackerImportHandler handles importing other portfolio trackers' exports
+type TrackerImportHandler struct {
+	jobQueueService services.JobQueueService
+}
+
+// NewTrackerImportHandler creates a new TrackerImportHandler instance
+func NewTrackerImportHandler(jobQueueService services.JobQueueService) *TrackerImportHandler {
+	return &TrackerImportHandler{
+		jobQueueService: jobQueueService,
+	}
+}
+
+// Import handles queueing the import of a Ghostfolio or Portfolio Performance export into new
+// portfolios. The job's result is the import result.
+// POST /api/v1/imports/tracker
+func (h *TrackerImportHandler) Import(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.TrackerImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request body", err)
+		return
+	}
+
+	job, err := h.jobQueueService.Enqueue(c.Request.Context(), userID.(string), "", models.QueuedJobTypeTrackerImport, req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	respondJobQueued(c, job)
+}
+
+// handleError maps service errors to HTTP responses. The export is checked when the job
+// runs, so problems with it fail the job rather than the request.
+func (h *TrackerImportHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.ImportFailed.WithMessage("Failed to queue import"))
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func performTrackerImport(handler *TrackerImportHandler, userID string, body interface{}) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(middleware.UserIDContextKey, userID)
+	c.Request = httptest.NewRequest("POST", "/api/v1/imports/tracker", bytes.NewBuffer(jsonBody))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Import(c)
+	return w
+}
+
+func TestTrackerImportHandler_Import(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := new(MockJobQueueService)
+	handler := NewTrackerImportHandler(mockService)
+	userID := uuid.New().String()
+
+	req := dto.TrackerImportRequest{Format: dto.ImportFormatGhostfolio, Data: `{"activities": []}`}
+	job := queuedJob(models.QueuedJobTypeTrackerImport, "")
+	mockService.On("Enqueue", userID, "", models.QueuedJobTypeTrackerImport, req).Return(job, nil)
+
+	w := performTrackerImport(handler, userID, req)
+
+	assertJobQueued(t, w, job)
+	mockService.AssertExpectations(t)
+}
+
+func TestTrackerImportHandler_Import_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("unexpected", func(t *testing.T) {
+		mockService := new(MockJobQueueService)
+		handler := NewTrackerImportHandler(mockService)
+		userID := uuid.New().String()
+
+		req := dto.TrackerImportRequest{Format: dto.ImportFormatGhostfolio, Data: "{}"}
+		mockService.On("Enqueue", userID, "", models.QueuedJobTypeTrackerImport, req).Return(nil, fmt.Errorf("database is down"))
+
+		w := performTrackerImport(handler, userID, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		var response dto.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "IMPORT_FAILED", response.Code)
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		handler := NewTrackerImportHandler(new(MockJobQueueService))
+
+		w := performTrackerImport(handler, uuid.New().String(), map[string]string{"format": "QUICKEN", "data": "x"})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response dto.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "INVALID_REQUEST", response.Code)
+	})
+}
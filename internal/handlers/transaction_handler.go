@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/middleware"
 	"github.com/lenon/portfolios/internal/models"
@@ -16,6 +19,8 @@ import (
 // TransactionHandler handles transaction-related HTTP requests
 type TransactionHandler struct {
 	transactionService services.TransactionService
+	blackoutService    services.BlackoutService
+	tagService         services.TagService
 }
 
 // NewTransactionHandler creates a new TransactionHandler instance
@@ -25,37 +30,83 @@ func NewTransactionHandler(transactionService services.TransactionService) *Tran
 	}
 }
 
+// NewTransactionHandlerWithBlackout creates a new TransactionHandler that enforces
+// employer stock blackout windows when creating transactions
+func NewTransactionHandlerWithBlackout(
+	transactionService services.TransactionService,
+	blackoutService services.BlackoutService,
+) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+		blackoutService:    blackoutService,
+	}
+}
+
+// NewTransactionHandlerWithTags creates a new TransactionHandler that enforces employer
+// stock blackout windows, tags transactions and filters transaction lists by tag
+func NewTransactionHandlerWithTags(
+	transactionService services.TransactionService,
+	blackoutService services.BlackoutService,
+	tagService services.TagService,
+) *TransactionHandler {
+	return &TransactionHandler{
+		transactionService: transactionService,
+		blackoutService:    blackoutService,
+		tagService:         tagService,
+	}
+}
+
 // Create handles transaction creation
-// POST /api/v1/portfolios/:portfolio_id/transactions
+// POST /api/v1/portfolios/:id/transactions
 func (h *TransactionHandler) Create(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 	if portfolioID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Portfolio ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Portfolio ID is required"))
 		return
 	}
 
 	var req dto.CreateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	// Reject invalid tags before anything is created
+	if _, err := models.NormalizeTags(req.Tags); err != nil {
+		apierrors.RespondError(c, err, apierrors.ValidationError)
 		return
 	}
 
+	// Check employer stock blackout windows before recording the trade
+	var blackout *services.BlackoutCheck
+	if h.blackoutService != nil {
+		var err error
+		blackout, err = h.blackoutService.CheckTransaction(
+			c.Request.Context(),
+			portfolioID,
+			userID.(string),
+			req.Type,
+			req.Symbol,
+			req.Date,
+			req.BlackoutOverrideReason,
+		)
+		if err != nil {
+			if err == models.ErrBlackoutPeriod {
+				apierrors.Respond(c, apierrors.BlackoutPeriod.WithMessage(blackout.Warning()+"; provide blackout_override_reason to override"))
+				return
+			}
+			h.respondCreateError(c, err)
+			return
+		}
+	}
+
 	// Extract price or use zero
 	var price decimal.Decimal
 	if req.Price != nil {
@@ -63,7 +114,7 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 	}
 
 	// Create transaction
-	transaction, err := h.transactionService.Create(
+	transaction, err := h.transactionService.Create(c.Request.Context(),
 		portfolioID,
 		userID.(string),
 		req.Type,
@@ -74,109 +125,123 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 		req.Commission,
 		req.Currency,
 		req.Notes,
+		req.Category,
 	)
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
-			return
-		}
+		h.respondCreateError(c, err)
+		return
+	}
 
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied",
-				Code:  "FORBIDDEN",
-			})
+	var tags []string
+	if h.tagService != nil && len(req.Tags) > 0 {
+		tags, err = h.tagService.SetTransactionTags(c.Request.Context(), transaction.ID.String(), userID.(string), req.Tags)
+		if err != nil {
+			// Don't keep a transaction created without the tags asked for, even if the client has gone
+			_ = h.transactionService.Delete(context.WithoutCancel(c.Request.Context()), transaction.ID.String(), userID.(string))
+			apierrors.RespondError(c, err, apierrors.CreationFailed.WithMessage("Failed to tag transaction"))
 			return
 		}
+	}
 
-		if err == models.ErrInsufficientShares {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: "Insufficient shares for sale",
-				Code:  "INSUFFICIENT_SHARES",
-			})
-			return
-		}
+	response := dto.ToTransactionResponseWithTags(transaction, tags)
 
-		if strings.Contains(err.Error(), "invalid") || strings.Contains(err.Error(), "required") {
-			c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-				Error: err.Error(),
-				Code:  "VALIDATION_ERROR",
-			})
+	// Trades inside a blackout window are always added to the audit trail
+	if blackout != nil {
+		if err := h.blackoutService.RecordOverride(c.Request.Context(), blackout, transaction, userID.(string), req.BlackoutOverrideReason); err != nil {
+			// Don't keep a restricted trade that isn't audited, even if the client has gone
+			_ = h.transactionService.Delete(context.WithoutCancel(c.Request.Context()), transaction.ID.String(), userID.(string))
+			apierrors.Respond(c, apierrors.CreationFailed.WithMessage("Failed to record blackout override: "+err.Error()))
 			return
 		}
+		response.Warnings = append(response.Warnings, blackout.Warning())
+	}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to create transaction: " + err.Error(),
-			Code:  "CREATION_FAILED",
+	c.JSON(http.StatusCreated, response)
+}
+
+// respondCreateError maps transaction creation errors to HTTP responses. Unknown symbols
+// are answered with the symbols the client may have meant.
+func (h *TransactionHandler) respondCreateError(c *gin.Context, err error) {
+	var invalid *models.InvalidSymbolError
+	if errors.As(err, &invalid) {
+		suggestions := invalid.Suggestions
+		if suggestions == nil {
+			suggestions = []string{}
+		}
+		c.JSON(apierrors.InvalidSymbol.Status, dto.InvalidSymbolResponse{
+			Error:       invalid.Error(),
+			Code:        apierrors.InvalidSymbol.Code,
+			Symbol:      invalid.Symbol,
+			Suggestions: suggestions,
 		})
 		return
 	}
-
-	c.JSON(http.StatusCreated, dto.ToTransactionResponse(transaction))
+	apierrors.RespondError(c, err, apierrors.CreationFailed.WithMessage("Failed to create transaction"))
 }
 
-// GetAll retrieves all transactions for a portfolio
-// GET /api/v1/portfolios/:portfolio_id/transactions
+// GetAll retrieves all transactions for a portfolio, optionally only those of a symbol and
+// those carrying every tag given in repeated tag query parameters. With a symbol,
+// running_position=true lists them oldest first with the position after each one.
+// GET /api/v1/portfolios/:id/transactions?symbol=AAPL&tag=speculative&running_position=true
 func (h *TransactionHandler) GetAll(c *gin.Context) {
-	portfolioID := c.Param("portfolio_id")
+	portfolioID := c.Param("id")
 	if portfolioID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Portfolio ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Portfolio ID is required"))
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Get optional symbol filter
 	symbol := c.Query("symbol")
+	runningPosition := c.Query("running_position") == "true"
+	if runningPosition && symbol == "" {
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("running_position requires a symbol"))
+		return
+	}
 
 	var transactions []*models.Transaction
+	var positions map[uuid.UUID]*services.RunningPosition
 	var err error
 
-	if symbol != "" {
-		transactions, err = h.transactionService.GetByPortfolioIDAndSymbol(portfolioID, symbol, userID.(string))
+	if runningPosition {
+		transactions, positions, err = h.transactionService.GetSymbolLedger(c.Request.Context(), portfolioID, symbol, userID.(string))
+	} else if symbol != "" {
+		transactions, err = h.transactionService.GetByPortfolioIDAndSymbol(c.Request.Context(), portfolioID, symbol, userID.(string))
 	} else {
-		transactions, err = h.transactionService.GetByPortfolioID(portfolioID, userID.(string))
+		transactions, err = h.transactionService.GetByPortfolioID(c.Request.Context(), portfolioID, userID.(string))
 	}
 
 	if err != nil {
-		if err == models.ErrPortfolioNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Portfolio not found",
-				Code:  "PORTFOLIO_NOT_FOUND",
-			})
+		if apierrors.RespondContextDone(c, err) {
 			return
 		}
 
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied",
-				Code:  "FORBIDDEN",
-			})
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve transactions"))
+		return
+	}
+
+	if h.tagService == nil {
+		c.JSON(http.StatusOK, dto.ToTransactionListResponse(transactions).WithRunningPositions(positions))
+		return
+	}
+
+	transactions, tags, err := h.tagService.TagTransactions(c.Request.Context(), transactions, c.QueryArray("tag"))
+	if err != nil {
+		if apierrors.RespondContextDone(c, err) {
 			return
 		}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve transactions",
-			Code:  "RETRIEVAL_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve transaction tags"))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToTransactionListResponse(transactions))
+	c.JSON(http.StatusOK, dto.ToTransactionListResponseWithTags(transactions, tags).WithRunningPositions(positions))
 }
 
 // GetByID retrieves a specific transaction
@@ -184,50 +249,52 @@ func (h *TransactionHandler) GetAll(c *gin.Context) {
 func (h *TransactionHandler) GetByID(c *gin.Context) {
 	transactionID := c.Param("id")
 	if transactionID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Transaction ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Transaction ID is required"))
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Get transaction
-	transaction, err := h.transactionService.GetByID(transactionID, userID.(string))
+	transaction, err := h.transactionService.GetByID(c.Request.Context(), transactionID, userID.(string))
 	if err != nil {
-		if err == models.ErrTransactionNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Transaction not found",
-				Code:  "TRANSACTION_NOT_FOUND",
-			})
+		if apierrors.RespondContextDone(c, err) {
 			return
 		}
 
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied",
-				Code:  "FORBIDDEN",
-			})
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve transaction"))
+		return
+	}
+
+	tags, err := h.transactionTags(c, transaction)
+	if err != nil {
+		if apierrors.RespondContextDone(c, err) {
 			return
 		}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to retrieve transaction",
-			Code:  "RETRIEVAL_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve transaction tags"))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToTransactionResponse(transaction))
+	setVersionETag(c, transaction.Version)
+	c.JSON(http.StatusOK, dto.ToTransactionResponseWithTags(transaction, tags))
+}
+
+// transactionTags returns the tags of a transaction, or nil if tags are not enabled
+func (h *TransactionHandler) transactionTags(c *gin.Context, transaction *models.Transaction) ([]string, error) {
+	if h.tagService == nil {
+		return nil, nil
+	}
+	_, tags, err := h.tagService.TagTransactions(c.Request.Context(), []*models.Transaction{transaction}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tags[transaction.ID], nil
 }
 
 // Update updates a transaction
@@ -235,32 +302,39 @@ func (h *TransactionHandler) GetByID(c *gin.Context) {
 func (h *TransactionHandler) Update(c *gin.Context) {
 	transactionID := c.Param("id")
 	if transactionID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Transaction ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Transaction ID is required"))
 		return
 	}
 
 	var req dto.UpdateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Invalid request data: " + err.Error(),
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.RespondInvalid(c, "Invalid request data", err)
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	version, err := requestVersion(c, req.Version)
+	if err != nil {
+		if !respondVersionError(c, err) {
+			apierrors.Respond(c, apierrors.InvalidRequest.WithMessage(err.Error()))
+		}
 		return
 	}
 
+	// Reject invalid tags before anything is changed
+	if req.Tags != nil {
+		if _, err := models.NormalizeTags(*req.Tags); err != nil {
+			apierrors.RespondError(c, err, apierrors.ValidationError)
+			return
+		}
+	}
+
 	// Extract price or use zero
 	var price decimal.Decimal
 	if req.Price != nil {
@@ -268,9 +342,10 @@ func (h *TransactionHandler) Update(c *gin.Context) {
 	}
 
 	// Update transaction
-	transaction, err := h.transactionService.Update(
+	transaction, err := h.transactionService.Update(c.Request.Context(),
 		transactionID,
 		userID.(string),
+		version,
 		req.Type,
 		req.Symbol,
 		req.Date,
@@ -279,32 +354,35 @@ func (h *TransactionHandler) Update(c *gin.Context) {
 		req.Commission,
 		req.Currency,
 		req.Notes,
+		req.Category,
 	)
 	if err != nil {
-		if err == models.ErrTransactionNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Transaction not found",
-				Code:  "TRANSACTION_NOT_FOUND",
-			})
+		if apierrors.RespondContextDone(c, err) || respondVersionError(c, err) {
 			return
 		}
 
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied",
-				Code:  "FORBIDDEN",
-			})
+		apierrors.RespondError(c, err, apierrors.UpdateFailed.WithMessage("Failed to update transaction"))
+		return
+	}
+
+	// Replace the tags if they were given, otherwise report the ones the transaction has
+	var tags []string
+	if h.tagService != nil && req.Tags != nil {
+		tags, err = h.tagService.SetTransactionTags(c.Request.Context(), transactionID, userID.(string), *req.Tags)
+	} else {
+		tags, err = h.transactionTags(c, transaction)
+	}
+	if err != nil {
+		if apierrors.RespondContextDone(c, err) {
 			return
 		}
 
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to update transaction",
-			Code:  "UPDATE_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.UpdateFailed.WithMessage("Failed to update transaction tags"))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.ToTransactionResponse(transaction))
+	setVersionETag(c, transaction.Version)
+	c.JSON(http.StatusOK, dto.ToTransactionResponseWithTags(transaction, tags))
 }
 
 // Delete deletes a transaction
@@ -312,46 +390,25 @@ func (h *TransactionHandler) Update(c *gin.Context) {
 func (h *TransactionHandler) Delete(c *gin.Context) {
 	transactionID := c.Param("id")
 	if transactionID == "" {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Error: "Transaction ID is required",
-			Code:  "INVALID_REQUEST",
-		})
+		apierrors.Respond(c, apierrors.InvalidRequest.WithMessage("Transaction ID is required"))
 		return
 	}
 
 	// Get user ID from context
 	userID, exists := c.Get(middleware.UserIDContextKey)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, dto.ErrorResponse{
-			Error: "User not authenticated",
-			Code:  "UNAUTHORIZED",
-		})
+		apierrors.Respond(c, apierrors.Unauthorized)
 		return
 	}
 
 	// Delete transaction
-	err := h.transactionService.Delete(transactionID, userID.(string))
+	err := h.transactionService.Delete(c.Request.Context(), transactionID, userID.(string))
 	if err != nil {
-		if err == models.ErrTransactionNotFound {
-			c.JSON(http.StatusNotFound, dto.ErrorResponse{
-				Error: "Transaction not found",
-				Code:  "TRANSACTION_NOT_FOUND",
-			})
+		if apierrors.RespondContextDone(c, err) {
 			return
 		}
 
-		if err == models.ErrUnauthorizedAccess {
-			c.JSON(http.StatusForbidden, dto.ErrorResponse{
-				Error: "Access denied",
-				Code:  "FORBIDDEN",
-			})
-			return
-		}
-
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Error: "Failed to delete transaction",
-			Code:  "DELETE_FAILED",
-		})
+		apierrors.RespondError(c, err, apierrors.DeleteFailed.WithMessage("Failed to delete transaction"))
 		return
 	}
 
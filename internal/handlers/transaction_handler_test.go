@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -10,12 +11,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/lenon/portfolios/internal/dto"
-	"github.com/lenon/portfolios/internal/middleware"
-	"github.com/lenon/portfolios/internal/models"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
 )
 
 // MockTransactionService is a mock implementation of TransactionService
@@ -23,15 +27,15 @@ type MockTransactionService struct {
 	mock.Mock
 }
 
-func (m *MockTransactionService) Create(portfolioID, userID string, transactionType models.TransactionType, symbol string, date time.Time, quantity, price decimal.Decimal, commission decimal.Decimal, currency, notes string) (*models.Transaction, error) {
-	args := m.Called(portfolioID, userID, transactionType, symbol, date, quantity, price, commission, currency, notes)
+func (m *MockTransactionService) Create(ctx context.Context, portfolioID, userID string, transactionType models.TransactionType, symbol string, date time.Time, quantity, price decimal.Decimal, commission decimal.Decimal, currency, notes, category string) (*models.Transaction, error) {
+	args := m.Called(portfolioID, userID, transactionType, symbol, date, quantity, price, commission, currency, notes, category)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionService) GetByID(id, userID string) (*models.Transaction, error) {
+func (m *MockTransactionService) GetByID(ctx context.Context, id, userID string) (*models.Transaction, error) {
 	args := m.Called(id, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -39,7 +43,7 @@ func (m *MockTransactionService) GetByID(id, userID string) (*models.Transaction
 	return args.Get(0).(*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionService) GetByPortfolioID(portfolioID, userID string) ([]*models.Transaction, error) {
+func (m *MockTransactionService) GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.Transaction, error) {
 	args := m.Called(portfolioID, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -47,7 +51,7 @@ func (m *MockTransactionService) GetByPortfolioID(portfolioID, userID string) ([
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionService) GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) ([]*models.Transaction, error) {
+func (m *MockTransactionService) GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) ([]*models.Transaction, error) {
 	args := m.Called(portfolioID, symbol, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -55,15 +59,23 @@ func (m *MockTransactionService) GetByPortfolioIDAndSymbol(portfolioID, symbol,
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionService) Update(id, userID string, transactionType models.TransactionType, symbol string, date time.Time, quantity, price decimal.Decimal, commission decimal.Decimal, currency, notes string) (*models.Transaction, error) {
-	args := m.Called(id, userID, transactionType, symbol, date, quantity, price, commission, currency, notes)
+func (m *MockTransactionService) GetSymbolLedger(ctx context.Context, portfolioID, symbol, userID string) ([]*models.Transaction, map[uuid.UUID]*services.RunningPosition, error) {
+	args := m.Called(portfolioID, symbol, userID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]*models.Transaction), args.Get(1).(map[uuid.UUID]*services.RunningPosition), args.Error(2)
+}
+
+func (m *MockTransactionService) Update(ctx context.Context, id, userID string, version int, transactionType models.TransactionType, symbol string, date time.Time, quantity, price decimal.Decimal, commission decimal.Decimal, currency, notes, category string) (*models.Transaction, error) {
+	args := m.Called(id, userID, version, transactionType, symbol, date, quantity, price, commission, currency, notes, category)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionService) Delete(id, userID string) error {
+func (m *MockTransactionService) Delete(ctx context.Context, id, userID string) error {
 	args := m.Called(id, userID)
 	return args.Error(0)
 }
@@ -93,10 +105,10 @@ func TestTransactionHandler_Create(t *testing.T) {
 
 		mockService.On("Create", portfolioID, userID, models.TransactionTypeBuy, "AAPL",
 			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything,
-			mock.Anything, "USD", "").
+			mock.Anything, "USD", "", "").
 			Return(transaction, nil)
 
-		router.POST("/portfolios/:portfolio_id/transactions", func(c *gin.Context) {
+		router.POST("/portfolios/:id/transactions", func(c *gin.Context) {
 			c.Set(middleware.UserIDContextKey, userID)
 			handler.Create(c)
 		})
@@ -122,6 +134,49 @@ func TestTransactionHandler_Create(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("income with a category", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		handler := NewTransactionHandler(mockService)
+		router := setupTestRouter()
+
+		userID := uuid.New().String()
+		portfolioID := uuid.New().String()
+
+		transaction := &models.Transaction{
+			ID:          uuid.New(),
+			PortfolioID: uuid.MustParse(portfolioID),
+			Type:        models.TransactionTypeInterest,
+			Symbol:      "SGOV",
+			Date:        time.Now(),
+			Quantity:    decimal.NewFromInt(4),
+			Currency:    "USD",
+			Category:    "CASH_SWEEP",
+		}
+
+		mockService.On("Create", portfolioID, userID, models.TransactionTypeInterest, "SGOV",
+			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything,
+			mock.Anything, "USD", "", "CASH_SWEEP").
+			Return(transaction, nil)
+
+		router.POST("/portfolios/:id/transactions", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID)
+			handler.Create(c)
+		})
+
+		body := `{"type": "INTEREST", "symbol": "SGOV", "date": "2024-06-03T00:00:00Z", "quantity": "4", "currency": "USD", "category": "CASH_SWEEP"}`
+		req, _ := http.NewRequest(http.MethodPost, "/portfolios/"+portfolioID+"/transactions", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response dto.TransactionResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "CASH_SWEEP", response.Category)
+		mockService.AssertExpectations(t)
+	})
+
 	t.Run("invalid JSON", func(t *testing.T) {
 		mockService := new(MockTransactionService)
 		handler := NewTransactionHandler(mockService)
@@ -130,7 +185,7 @@ func TestTransactionHandler_Create(t *testing.T) {
 		userID := uuid.New().String()
 		portfolioID := uuid.New().String()
 
-		router.POST("/portfolios/:portfolio_id/transactions", func(c *gin.Context) {
+		router.POST("/portfolios/:id/transactions", func(c *gin.Context) {
 			c.Set(middleware.UserIDContextKey, userID)
 			handler.Create(c)
 		})
@@ -148,6 +203,39 @@ func TestTransactionHandler_Create(t *testing.T) {
 		assert.Equal(t, "INVALID_REQUEST", response.Code)
 	})
 
+	t.Run("invalid fields", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		handler := NewTransactionHandler(mockService)
+		router := setupTestRouter()
+
+		userID := uuid.New().String()
+		portfolioID := uuid.New().String()
+
+		router.POST("/portfolios/:id/transactions", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID)
+			handler.Create(c)
+		})
+
+		body := `{"type":"HOLD","date":"2024-01-02T00:00:00Z","quantity":"10","currency":"US"}`
+		req, _ := http.NewRequest(http.MethodPost, "/portfolios/"+portfolioID+"/transactions", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response dto.ErrorResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "INVALID_REQUEST", response.Code)
+		assert.Equal(t, []dto.FieldError{
+			{Field: "type", Rule: "oneof", Message: "must be one of: BUY, SELL, DIVIDEND, SPLIT, MERGER, SPINOFF, DIVIDEND_REINVEST, MANAGEMENT_FEE, ADR_FEE, FX_FEE, LENDING_INCOME, INTEREST, REBATE, DEPOSIT, WITHDRAWAL, OPENING_BALANCE, CAPITAL_GAIN_DISTRIBUTION"},
+			{Field: "symbol", Rule: "required", Message: "is required"},
+			{Field: "currency", Rule: "len", Message: "must be exactly 3 characters long"},
+		}, response.Errors)
+		mockService.AssertNotCalled(t, "Create")
+	})
+
 	t.Run("missing authentication", func(t *testing.T) {
 		mockService := new(MockTransactionService)
 		handler := NewTransactionHandler(mockService)
@@ -155,7 +243,7 @@ func TestTransactionHandler_Create(t *testing.T) {
 
 		portfolioID := uuid.New().String()
 
-		router.POST("/portfolios/:portfolio_id/transactions", handler.Create)
+		router.POST("/portfolios/:id/transactions", handler.Create)
 
 		price := decimal.NewFromFloat(150.00)
 		reqBody := dto.CreateTransactionRequest{
@@ -191,10 +279,10 @@ func TestTransactionHandler_Create(t *testing.T) {
 
 		mockService.On("Create", portfolioID, userID, models.TransactionTypeBuy, "AAPL",
 			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything,
-			mock.Anything, "USD", "").
+			mock.Anything, "USD", "", "").
 			Return(nil, models.ErrPortfolioNotFound)
 
-		router.POST("/portfolios/:portfolio_id/transactions", func(c *gin.Context) {
+		router.POST("/portfolios/:id/transactions", func(c *gin.Context) {
 			c.Set(middleware.UserIDContextKey, userID)
 			handler.Create(c)
 		})
@@ -234,10 +322,10 @@ func TestTransactionHandler_Create(t *testing.T) {
 
 		mockService.On("Create", portfolioID, userID, models.TransactionTypeSell, "AAPL",
 			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything,
-			mock.Anything, "USD", "").
+			mock.Anything, "USD", "", "").
 			Return(nil, models.ErrInsufficientShares)
 
-		router.POST("/portfolios/:portfolio_id/transactions", func(c *gin.Context) {
+		router.POST("/portfolios/:id/transactions", func(c *gin.Context) {
 			c.Set(middleware.UserIDContextKey, userID)
 			handler.Create(c)
 		})
@@ -258,13 +346,59 @@ func TestTransactionHandler_Create(t *testing.T) {
 
 		router.ServeHTTP(w, req)
 
-		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
 
 		var response dto.ErrorResponse
 		_ = json.Unmarshal(w.Body.Bytes(), &response)
 		assert.Equal(t, "INSUFFICIENT_SHARES", response.Code)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("unknown symbol", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		handler := NewTransactionHandler(mockService)
+		router := setupTestRouter()
+
+		userID := uuid.New().String()
+		portfolioID := uuid.New().String()
+		price := decimal.NewFromFloat(150.00)
+
+		mockService.On("Create", portfolioID, userID, models.TransactionTypeBuy, "APPL",
+			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything,
+			mock.Anything, "USD", "", "").
+			Return(nil, &models.InvalidSymbolError{Symbol: "APPL", Suggestions: []string{"AAPL"}})
+
+		router.POST("/portfolios/:id/transactions", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID)
+			handler.Create(c)
+		})
+
+		reqBody := dto.CreateTransactionRequest{
+			Type:     models.TransactionTypeBuy,
+			Symbol:   "APPL",
+			Date:     time.Now(),
+			Quantity: decimal.NewFromInt(10),
+			Price:    &price,
+			Currency: "USD",
+		}
+		body, _ := json.Marshal(reqBody)
+
+		req, _ := http.NewRequest(http.MethodPost, "/portfolios/"+portfolioID+"/transactions", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response dto.InvalidSymbolResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "INVALID_SYMBOL", response.Code)
+		assert.Equal(t, "APPL", response.Symbol)
+		assert.Equal(t, []string{"AAPL"}, response.Suggestions)
+		assert.Equal(t, "unknown symbol APPL; did you mean AAPL?", response.Error)
+		mockService.AssertExpectations(t)
+	})
 }
 
 func TestTransactionHandler_GetAll(t *testing.T) {
@@ -291,7 +425,7 @@ func TestTransactionHandler_GetAll(t *testing.T) {
 
 		mockService.On("GetByPortfolioID", portfolioID, userID).Return(transactions, nil)
 
-		router.GET("/portfolios/:portfolio_id/transactions", func(c *gin.Context) {
+		router.GET("/portfolios/:id/transactions", func(c *gin.Context) {
 			c.Set(middleware.UserIDContextKey, userID)
 			handler.GetAll(c)
 		})
@@ -328,7 +462,7 @@ func TestTransactionHandler_GetAll(t *testing.T) {
 
 		mockService.On("GetByPortfolioIDAndSymbol", portfolioID, "AAPL", userID).Return(transactions, nil)
 
-		router.GET("/portfolios/:portfolio_id/transactions", func(c *gin.Context) {
+		router.GET("/portfolios/:id/transactions", func(c *gin.Context) {
 			c.Set(middleware.UserIDContextKey, userID)
 			handler.GetAll(c)
 		})
@@ -342,6 +476,62 @@ func TestTransactionHandler_GetAll(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 
+	t.Run("symbol ledger with running position", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		handler := NewTransactionHandler(mockService)
+		router := setupTestRouter()
+
+		userID := uuid.New().String()
+		portfolioID := uuid.New().String()
+		price := decimal.NewFromFloat(150.00)
+
+		buy := &models.Transaction{ID: uuid.New(), Type: models.TransactionTypeBuy, Symbol: "AAPL", Quantity: decimal.NewFromInt(10), Price: &price}
+		sell := &models.Transaction{ID: uuid.New(), Type: models.TransactionTypeSell, Symbol: "AAPL", Quantity: decimal.NewFromInt(4), Price: &price}
+		positions := map[uuid.UUID]*services.RunningPosition{
+			buy.ID:  {Quantity: decimal.NewFromInt(10), CostBasis: decimal.NewFromInt(1500)},
+			sell.ID: {Quantity: decimal.NewFromInt(6), CostBasis: decimal.NewFromInt(900)},
+		}
+
+		mockService.On("GetSymbolLedger", portfolioID, "AAPL", userID).Return([]*models.Transaction{buy, sell}, positions, nil)
+
+		router.GET("/portfolios/:id/transactions", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID)
+			handler.GetAll(c)
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, "/portfolios/"+portfolioID+"/transactions?symbol=AAPL&running_position=true", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response dto.TransactionListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Transactions, 2)
+		assert.Equal(t, "6", response.Transactions[1].RunningQuantity.String())
+		assert.Equal(t, "900", response.Transactions[1].RunningCostBasis.String())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("running position without symbol", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		handler := NewTransactionHandler(mockService)
+		router := setupTestRouter()
+
+		router.GET("/portfolios/:id/transactions", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, uuid.New().String())
+			handler.GetAll(c)
+		})
+
+		req, _ := http.NewRequest(http.MethodGet, "/portfolios/"+uuid.New().String()+"/transactions?running_position=true", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetByPortfolioID", mock.Anything, mock.Anything)
+	})
+
 	t.Run("portfolio not found", func(t *testing.T) {
 		mockService := new(MockTransactionService)
 		handler := NewTransactionHandler(mockService)
@@ -352,7 +542,7 @@ func TestTransactionHandler_GetAll(t *testing.T) {
 
 		mockService.On("GetByPortfolioID", portfolioID, userID).Return(nil, models.ErrPortfolioNotFound)
 
-		router.GET("/portfolios/:portfolio_id/transactions", func(c *gin.Context) {
+		router.GET("/portfolios/:id/transactions", func(c *gin.Context) {
 			c.Set(middleware.UserIDContextKey, userID)
 			handler.GetAll(c)
 		})
@@ -377,7 +567,7 @@ func TestTransactionHandler_GetAll(t *testing.T) {
 
 		portfolioID := uuid.New().String()
 
-		router.GET("/portfolios/:portfolio_id/transactions", handler.GetAll)
+		router.GET("/portfolios/:id/transactions", handler.GetAll)
 
 		req, _ := http.NewRequest(http.MethodGet, "/portfolios/"+portfolioID+"/transactions", nil)
 		w := httptest.NewRecorder()
@@ -529,9 +719,9 @@ func TestTransactionHandler_Update(t *testing.T) {
 			Currency:    "USD",
 		}
 
-		mockService.On("Update", transactionID, userID, models.TransactionTypeBuy, "AAPL",
+		mockService.On("Update", transactionID, userID, 1, models.TransactionTypeBuy, "AAPL",
 			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything,
-			mock.Anything, "USD", "Updated").
+			mock.Anything, "USD", "Updated", "").
 			Return(transaction, nil)
 
 		router.PUT("/transactions/:id", func(c *gin.Context) {
@@ -553,6 +743,7 @@ func TestTransactionHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/transactions/"+transactionID, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -576,6 +767,7 @@ func TestTransactionHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/transactions/"+transactionID, bytes.NewBufferString("invalid json"))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -596,9 +788,9 @@ func TestTransactionHandler_Update(t *testing.T) {
 		transactionID := uuid.New().String()
 		price := decimal.NewFromFloat(160.00)
 
-		mockService.On("Update", transactionID, userID, models.TransactionTypeBuy, "AAPL",
+		mockService.On("Update", transactionID, userID, 1, models.TransactionTypeBuy, "AAPL",
 			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything,
-			mock.Anything, "USD", "").
+			mock.Anything, "USD", "", "").
 			Return(nil, models.ErrTransactionNotFound)
 
 		router.PUT("/transactions/:id", func(c *gin.Context) {
@@ -618,6 +810,7 @@ func TestTransactionHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/transactions/"+transactionID, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -652,6 +845,7 @@ func TestTransactionHandler_Update(t *testing.T) {
 
 		req, _ := http.NewRequest(http.MethodPut, "/transactions/"+transactionID, bytes.NewBuffer(body))
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"1"`)
 		w := httptest.NewRecorder()
 
 		router.ServeHTTP(w, req)
@@ -662,6 +856,53 @@ func TestTransactionHandler_Update(t *testing.T) {
 		_ = json.Unmarshal(w.Body.Bytes(), &response)
 		assert.Equal(t, "UNAUTHORIZED", response.Code)
 	})
+
+	t.Run("stale version", func(t *testing.T) {
+		mockService := new(MockTransactionService)
+		handler := NewTransactionHandler(mockService)
+		router := setupTestRouter()
+
+		userID := uuid.New().String()
+		transactionID := uuid.New().String()
+		price := decimal.NewFromFloat(160.00)
+		version := 2
+
+		mockService.On("Update", transactionID, userID, 2, models.TransactionTypeBuy, "AAPL",
+			mock.AnythingOfType("time.Time"), mock.Anything, mock.Anything,
+			mock.Anything, "USD", "", "").
+			Return(nil, &models.VersionConflictError{CurrentVersion: 3})
+
+		router.PUT("/transactions/:id", func(c *gin.Context) {
+			c.Set(middleware.UserIDContextKey, userID)
+			handler.Update(c)
+		})
+
+		reqBody := dto.UpdateTransactionRequest{
+			Type:     models.TransactionTypeBuy,
+			Symbol:   "AAPL",
+			Date:     time.Now(),
+			Quantity: decimal.NewFromInt(15),
+			Price:    &price,
+			Currency: "USD",
+			Version:  &version,
+		}
+		body, _ := json.Marshal(reqBody)
+
+		req, _ := http.NewRequest(http.MethodPut, "/transactions/"+transactionID, bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		var response dto.VersionConflictResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "VERSION_CONFLICT", response.Code)
+		assert.Equal(t, 3, response.CurrentVersion)
+		mockService.AssertExpectations(t)
+	})
 }
 
 func TestTransactionHandler_Delete(t *testing.T) {
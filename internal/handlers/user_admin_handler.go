@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// defaultUserListLimit is the page size of user listings that don't specify one
+const defaultUserListLimit = 50
+
+// UserAdminHandler handles user management requests from administrators
+type UserAdminHandler struct {
+	userAdminService services.UserAdminService
+}
+
+// NewUserAdminHandler creates a new UserAdminHandler instance
+func NewUserAdminHandler(userAdminService services.UserAdminService) *UserAdminHandler {
+	return &UserAdminHandler{
+		userAdminService: userAdminService,
+	}
+}
+
+// ListUsers handles listing users, optionally filtered by email, role and disabled state
+// GET /api/v1/admin/users
+func (h *UserAdminHandler) ListUsers(c *gin.Context) {
+	var req dto.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid query parameters", err)
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultUserListLimit
+	}
+
+	users, total, err := h.userAdminService.ListUsers(repository.UserFilter{
+		Email:    req.Email,
+		Role:     req.Role,
+		Disabled: req.Disabled,
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToAdminUserListResponse(users, total, req.Limit, req.Offset))
+}
+
+// GetUser handles retrieving a user
+// GET /api/v1/admin/users/:id
+func (h *UserAdminHandler) GetUser(c *gin.Context) {
+	user, err := h.userAdminService.GetUser(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToAdminUserResponse(user))
+}
+
+// SetRole handles changing a user's role
+// PUT /api/v1/admin/users/:id/role
+func (h *UserAdminHandler) SetRole(c *gin.Context) {
+	var req dto.SetUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	user, err := h.userAdminService.SetRole(middleware.GetUserID(c), c.Param("id"), req.Role)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToAdminUserResponse(user))
+}
+
+// DisableUser handles disabling a user's account
+// POST /api/v1/admin/users/:id/disable
+func (h *UserAdminHandler) DisableUser(c *gin.Context) {
+	user, err := h.userAdminService.DisableUser(middleware.GetUserID(c), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToAdminUserResponse(user))
+}
+
+// EnableUser handles re-enabling a disabled account
+// POST /api/v1/admin/users/:id/enable
+func (h *UserAdminHandler) EnableUser(c *gin.Context) {
+	user, err := h.userAdminService.EnableUser(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToAdminUserResponse(user))
+}
+
+// ForcePasswordReset handles locking a user's account until they choose a new password
+// POST /api/v1/admin/users/:id/reset-password
+func (h *UserAdminHandler) ForcePasswordReset(c *gin.Context) {
+	reset, err := h.userAdminService.ForcePasswordReset(c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ForcePasswordResetResponse{
+		User:           dto.ToAdminUserResponse(reset.User),
+		EmailSent:      reset.EmailSent,
+		ResetExpiresAt: reset.ExpiresAt,
+	})
+}
+
+// DeleteUser handles deleting a user and everything they own
+// DELETE /api/v1/admin/users/:id
+func (h *UserAdminHandler) DeleteUser(c *gin.Context) {
+	if err := h.userAdminService.DeleteUser(middleware.GetUserID(c), c.Param("id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetUsage handles counting the resources a user owns
+// GET /api/v1/admin/users/:id/usage
+func (h *UserAdminHandler) GetUsage(c *gin.Context) {
+	userID := c.Param("id")
+	usage, err := h.userAdminService.GetUsage(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.UserUsageResponse{
+		UserID:               userID,
+		Portfolios:           usage.Portfolios,
+		Transactions:         usage.Transactions,
+		ImportBatches:        usage.ImportBatches,
+		Holdings:             usage.Holdings,
+		PerformanceSnapshots: usage.PerformanceSnapshots,
+		ActiveAPIKeys:        usage.ActiveAPIKeys,
+		ActiveSessions:       usage.ActiveSessions,
+	})
+}
+
+// handleError maps user management errors to HTTP responses
+func (h *UserAdminHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to process user management request"))
+}
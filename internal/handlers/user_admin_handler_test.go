@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// MockUserAdminService is a mock implementation of UserAdminService
+type MockUserAdminService struct {
+	mock.Mock
+}
+
+func (m *MockUserAdminService) ListUsers(filter repository.UserFilter) ([]*models.User, int64, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*models.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserAdminService) GetUser(userID string) (*models.User, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserAdminService) SetRole(adminID, userID string, role models.UserRole) (*models.User, error) {
+	args := m.Called(adminID, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserAdminService) DisableUser(adminID, userID string) (*models.User, error) {
+	args := m.Called(adminID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserAdminService) EnableUser(userID string) (*models.User, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserAdminService) ForcePasswordReset(userID string) (*services.ForcedPasswordReset, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.ForcedPasswordReset), args.Error(1)
+}
+
+func (m *MockUserAdminService) DeleteUser(adminID, userID string) error {
+	args := m.Called(adminID, userID)
+	return args.Error(0)
+}
+
+func (m *MockUserAdminService) GetUsage(ctx context.Context, userID string) (*services.UserUsage, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.UserUsage), args.Error(1)
+}
+
+// serveUserAdmin runs a user admin handler as adminID with the given target user and JSON body
+func serveUserAdmin(handler gin.HandlerFunc, method, target, adminID, userID string, body interface{}) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "id", Value: userID}}
+	c.Set(middleware.UserIDContextKey, adminID)
+
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	c.Request = httptest.NewRequest(method, target, bytes.NewReader(payload))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler(c)
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestUserAdminHandler_ListUsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adminID := uuid.New().String()
+
+	t.Run("filters and default limit", func(t *testing.T) {
+		mockService := new(MockUserAdminService)
+		handler := NewUserAdminHandler(mockService)
+		disabled := true
+		mockService.On("ListUsers", repository.UserFilter{
+			Email:    "acme",
+			Role:     models.RoleUser,
+			Disabled: &disabled,
+			Limit:    defaultUserListLimit,
+		}).Return([]*models.User{{ID: uuid.New(), Email: "jane@acme.example", Role: models.RoleUser}}, int64(1), nil)
+
+		w := serveUserAdmin(handler.ListUsers, "GET", "/?email=acme&role=user&disabled=true", adminID, "", nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response dto.AdminUserListResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, int64(1), response.Total)
+		assert.Equal(t, defaultUserListLimit, response.Limit)
+		assert.Len(t, response.Users, 1)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		handler := NewUserAdminHandler(new(MockUserAdminService))
+		w := serveUserAdmin(handler.ListUsers, "GET", "/?role=owner", adminID, "", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("limit too large", func(t *testing.T) {
+		handler := NewUserAdminHandler(new(MockUserAdminService))
+		w := serveUserAdmin(handler.ListUsers, "GET", "/?limit=1000", adminID, "", nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestUserAdminHandler_SetRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adminID := uuid.New().String()
+	userID := uuid.New().String()
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockUserAdminService)
+		handler := NewUserAdminHandler(mockService)
+		mockService.On("SetRole", adminID, userID, models.RoleAdmin).
+			Return(&models.User{ID: uuid.MustParse(userID), Role: models.RoleAdmin}, nil)
+
+		w := serveUserAdmin(handler.SetRole, "PUT", "/", adminID, userID, dto.SetUserRoleRequest{Role: models.RoleAdmin})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response dto.AdminUserResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, models.RoleAdmin, response.Role)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unknown role", func(t *testing.T) {
+		handler := NewUserAdminHandler(new(MockUserAdminService))
+		w := serveUserAdmin(handler.SetRole, "PUT", "/", adminID, userID, map[string]string{"role": "owner"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("demoting self", func(t *testing.T) {
+		mockService := new(MockUserAdminService)
+		handler := NewUserAdminHandler(mockService)
+		mockService.On("SetRole", adminID, adminID, models.RoleUser).Return(nil, models.ErrAdminSelfChange)
+
+		w := serveUserAdmin(handler.SetRole, "PUT", "/", adminID, adminID, dto.SetUserRoleRequest{Role: models.RoleUser})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestUserAdminHandler_DisableUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adminID := uuid.New().String()
+	userID := uuid.New().String()
+
+	t.Run("success", func(t *testing.T) {
+		mockService := new(MockUserAdminService)
+		handler := NewUserAdminHandler(mockService)
+		disabledAt := time.Now()
+		mockService.On("DisableUser", adminID, userID).
+			Return(&models.User{ID: uuid.MustParse(userID), DisabledAt: &disabledAt}, nil)
+
+		w := serveUserAdmin(handler.DisableUser, "POST", "/", adminID, userID, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response dto.AdminUserResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Disabled)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		mockService := new(MockUserAdminService)
+		handler := NewUserAdminHandler(mockService)
+		mockService.On("DisableUser", adminID, userID).Return(nil, models.ErrUserNotFound)
+
+		w := serveUserAdmin(handler.DisableUser, "POST", "/", adminID, userID, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestUserAdminHandler_ForcePasswordReset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	mockService := new(MockUserAdminService)
+	handler := NewUserAdminHandler(mockService)
+	expiresAt := time.Now().Add(time.Hour).UTC()
+	mockService.On("ForcePasswordReset", userID).Return(&services.ForcedPasswordReset{
+		User:      &models.User{ID: uuid.MustParse(userID), PasswordResetRequired: true},
+		ExpiresAt: expiresAt,
+		EmailSent: true,
+	}, nil)
+
+	w := serveUserAdmin(handler.ForcePasswordReset, "POST", "/", uuid.New().String(), userID, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.ForcePasswordResetResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.EmailSent)
+	assert.True(t, response.User.PasswordResetRequired)
+	assert.True(t, expiresAt.Equal(response.ResetExpiresAt))
+}
+
+func TestUserAdminHandler_DeleteUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	adminID := uuid.New().String()
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"success", nil, http.StatusNoContent},
+		{"user not found", models.ErrUserNotFound, http.StatusNotFound},
+		{"deleting self", models.ErrAdminSelfChange, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockUserAdminService)
+			handler := NewUserAdminHandler(mockService)
+			mockService.On("DeleteUser", adminID, userID).Return(tt.err)
+
+			w := serveUserAdmin(handler.DeleteUser, "DELETE", "/", adminID, userID, nil)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestUserAdminHandler_GetUsage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	mockService := new(MockUserAdminService)
+	handler := NewUserAdminHandler(mockService)
+	mockService.On("GetUsage", mock.Anything, userID).Return(&services.UserUsage{
+		Portfolios:    2,
+		Transactions:  14,
+		ImportBatches: 1,
+		ActiveAPIKeys: 1,
+	}, nil)
+
+	w := serveUserAdmin(handler.GetUsage, "GET", "/", uuid.New().String(), userID, nil)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.UserUsageResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, userID, response.UserID)
+	assert.Equal(t, int64(2), response.Portfolios)
+	assert.Equal(t, int64(14), response.Transactions)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// UserSettingsHandler handles the user's preferences
+type UserSettingsHandler struct {
+	settingsService services.UserSettingsService
+}
+
+// NewUserSettingsHandler creates a new UserSettingsHandler instance
+func NewUserSettingsHandler(settingsService services.UserSettingsService) *UserSettingsHandler {
+	return &UserSettingsHandler{
+		settingsService: settingsService,
+	}
+}
+
+// Get handles retrieving the user's settings, which are the defaults until first saved
+// GET /api/v1/settings
+func (h *UserSettingsHandler) Get(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	settings, err := h.settingsService.Get(c.Request.Context(), userID.(string))
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.RetrievalFailed.WithMessage("Failed to retrieve settings"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToUserSettingsResponse(settings))
+}
+
+// Update handles changing the user's settings
+// PUT /api/v1/settings
+func (h *UserSettingsHandler) Update(c *gin.Context) {
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.UpdateUserSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	settings, err := h.settingsService.Update(c.Request.Context(), userID.(string), &req)
+	if err != nil {
+		apierrors.RespondError(c, err, apierrors.UpdateFailed.WithMessage("Failed to update settings"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ToUserSettingsResponse(settings))
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// MockUserSettingsService is a mock implementation of UserSettingsService
+type MockUserSettingsService struct {
+	mock.Mock
+}
+
+func (m *MockUserSettingsService) Get(ctx context.Context, userID string) (*models.UserSettings, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserSettings), args.Error(1)
+}
+
+func (m *MockUserSettingsService) Update(ctx context.Context, userID string, req *dto.UpdateUserSettingsRequest) (*models.UserSettings, error) {
+	args := m.Called(userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserSettings), args.Error(1)
+}
+
+func TestUserSettingsHandler_Get(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New()
+
+	mockService := new(MockUserSettingsService)
+	handler := NewUserSettingsHandler(mockService)
+	mockService.On("Get", userID.String()).Return(models.DefaultUserSettings(userID), nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(middleware.UserIDContextKey, userID.String())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+
+	handler.Get(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response dto.UserSettingsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "USD", response.DisplayCurrency)
+	assert.True(t, response.PushNotifications)
+	assert.Nil(t, response.UpdatedAt)
+	mockService.AssertExpectations(t)
+}
+
+func TestUserSettingsHandler_Update(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	userID := uuid.New().String()
+
+	update := func(handler *UserSettingsHandler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(middleware.UserIDContextKey, userID)
+		c.Request = httptest.NewRequest("PUT", "/", bytes.NewBufferString(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.Update(c)
+		return w
+	}
+
+	t.Run("invalid time zone", func(t *testing.T) {
+		mockService := new(MockUserSettingsService)
+		handler := NewUserSettingsHandler(mockService)
+		mockService.On("Update", userID, mock.MatchedBy(func(req *dto.UpdateUserSettingsRequest) bool {
+			return req.Timezone != nil && *req.Timezone == "Europe/Atlantis" && req.Locale == nil
+		})).Return(nil, models.ErrInvalidTimezone)
+
+		w := update(handler, `{"timezone":"Europe/Atlantis"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var response dto.ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "VALIDATION_ERROR", response.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid cost basis method", func(t *testing.T) {
+		handler := NewUserSettingsHandler(new(MockUserSettingsService))
+
+		w := update(handler, `{"default_cost_basis_method":"AVERAGE"}`)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// requestVersion returns the version an update was made against, taken from the If-Match
+// header when present and from the request body otherwise
+func requestVersion(c *gin.Context, bodyVersion *int) (int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if bodyVersion == nil {
+			return 0, models.ErrVersionRequired
+		}
+		return *bodyVersion, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("the If-Match header must be the ETag of a previous response, got %s", header)
+	}
+	return version, nil
+}
+
+// setVersionETag sets the ETag clients send back in If-Match to update a record
+func setVersionETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// respondVersionError writes a response if err is about the version an update was made
+// against, and reports whether it did
+func respondVersionError(c *gin.Context, err error) bool {
+	var conflict *models.VersionConflictError
+	switch {
+	case errors.As(err, &conflict):
+		setVersionETag(c, conflict.CurrentVersion)
+		c.JSON(apierrors.VersionConflict.Status, dto.VersionConflictResponse{
+			Error:          apierrors.VersionConflict.Message,
+			Code:           apierrors.VersionConflict.Code,
+			CurrentVersion: conflict.CurrentVersion,
+		})
+	case errors.Is(err, models.ErrVersionRequired):
+		apierrors.Respond(c, apierrors.VersionRequired)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// WhatIfHandler handles what-if trade analysis HTTP requests
+type WhatIfHandler struct {
+	whatIfService services.WhatIfService
+}
+
+// NewWhatIfHandler creates a new WhatIfHandler instance
+func NewWhatIfHandler(whatIfService services.WhatIfService) *WhatIfHandler {
+	return &WhatIfHandler{
+		whatIfService: whatIfService,
+	}
+}
+
+// Analyze handles previewing the allocation, realized gains, taxes and cash after hypothetical
+// trades. Nothing is recorded.
+// POST /api/v1/portfolios/:id/what-if
+func (h *WhatIfHandler) Analyze(c *gin.Context) {
+	portfolioID := c.Param("id")
+
+	userID, exists := c.Get(middleware.UserIDContextKey)
+	if !exists {
+		apierrors.Respond(c, apierrors.Unauthorized)
+		return
+	}
+
+	var req dto.WhatIfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apierrors.RespondInvalid(c, "Invalid request data", err)
+		return
+	}
+
+	result, err := h.whatIfService.Analyze(c.Request.Context(), portfolioID, userID.(string), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// handleError maps service errors to HTTP responses
+func (h *WhatIfHandler) handleError(c *gin.Context, err error) {
+	apierrors.RespondError(c, err, apierrors.InternalError.WithMessage("Failed to analyze trades"))
+}
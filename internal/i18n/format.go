@@ -0,0 +1,196 @@
+// Package i18n formats numbers, amounts and dates the way a user's locale writes them, for
+// the statements and emails people read. API responses stay in locale-neutral JSON.
+package i18n
+
+import (
+	"strings"
+	"time"
+	// Times are shown in the user's time zone, which slim images don't ship
+	_ "time/tzdata"
+
+	"github.com/shopspring/decimal"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// DefaultLocale is the locale of users who never chose one
+const DefaultLocale = "en-US"
+
+// Formatter writes numbers and dates in one locale and time zone. The zero value is not
+// usable; create one with NewFormatter.
+type Formatter struct {
+	locale         string
+	group          string // Between groups of three digits of the integer part
+	decimal        string // Between the integer and fractional parts
+	dateLayout     string
+	longDateLayout string
+	location       *time.Location
+}
+
+// NewFormatter returns a formatter for a BCP 47 locale and IANA time zone. Unknown locales
+// are written like DefaultLocale and unknown time zones as UTC.
+func NewFormatter(locale, timezone string) Formatter {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		tag = language.MustParse(DefaultLocale)
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" || timezone == "Local" {
+		location = time.UTC
+	}
+
+	group, decimalSeparator := separators(tag)
+	dateLayout, longDateLayout := dateLayouts(tag)
+	return Formatter{
+		locale:         tag.String(),
+		group:          group,
+		decimal:        decimalSeparator,
+		dateLayout:     dateLayout,
+		longDateLayout: longDateLayout,
+		location:       location,
+	}
+}
+
+// separators returns the digit grouping and decimal separators of a locale, taken from how
+// it prints a sample number. Locales that don't write numbers in ASCII digits use English
+// separators.
+func separators(tag language.Tag) (group, decimalSeparator string) {
+	// "1,234,567.50" in English, "1.234.567,50" in German and "1 234 567,50" in French
+	sample := message.NewPrinter(tag).Sprintf("%.2f", 1234567.5)
+	rest, ok := strings.CutPrefix(sample, "1")
+	if !ok {
+		return ",", "."
+	}
+	group, rest, ok = strings.Cut(rest, "234")
+	if !ok {
+		return ",", "."
+	}
+	rest, ok = strings.CutPrefix(rest, group+"567")
+	if !ok {
+		return ",", "."
+	}
+	decimalSeparator, ok = strings.CutSuffix(rest, "50")
+	if !ok || decimalSeparator == "" {
+		return ",", "."
+	}
+
+	// PDF statements are written in Latin-1, which has the no-break space but neither the
+	// narrow one French groups with nor the apostrophe Swiss German does
+	group = strings.NewReplacer("\u202f", "\u00a0", "\u2019", "'").Replace(group)
+	return group, decimalSeparator
+}
+
+// numericDateLayouts are the short date layouts of languages whose month names aren't
+// English, keyed by base language
+var numericDateLayouts = map[string]string{
+	"de": "02.01.2006",
+	"da": "02.01.2006",
+	"fi": "2.1.2006",
+	"nb": "02.01.2006",
+	"pl": "02.01.2006",
+	"ru": "02.01.2006",
+	"tr": "02.01.2006",
+	"fr": "02/01/2006",
+	"es": "02/01/2006",
+	"it": "02/01/2006",
+	"pt": "02/01/2006",
+	"nl": "02-01-2006",
+	"sv": "2006-01-02",
+	"ja": "2006/01/02",
+	"zh": "2006/01/02",
+	"ko": "2006. 01. 02.",
+}
+
+// dateLayouts returns the short and long date layouts of a locale. English spells months
+// out, with the day first outside the United States; other languages write dates in
+// numbers, since Go only knows the English month names.
+func dateLayouts(tag language.Tag) (short, long string) {
+	base, _ := tag.Base()
+	if base.String() == "en" {
+		if region, _ := tag.Region(); region.String() == "US" || region.String() == "PH" {
+			return "Jan 2, 2006", "January 2, 2006"
+		}
+		return "2 Jan 2006", "2 January 2006"
+	}
+	if layout, ok := numericDateLayouts[base.String()]; ok {
+		return layout, layout
+	}
+	return "2006-01-02", "2006-01-02"
+}
+
+// Locale returns the canonical BCP 47 tag of the formatter's locale
+func (f Formatter) Locale() string {
+	return f.locale
+}
+
+// Amount writes an amount with two decimal places and grouped thousands
+func (f Formatter) Amount(amount decimal.Decimal) string {
+	return f.number(amount.StringFixed(2))
+}
+
+// SignedAmount writes an amount like Amount, with a plus sign when it is positive
+func (f Formatter) SignedAmount(amount decimal.Decimal) string {
+	if amount.IsPositive() {
+		return "+" + f.Amount(amount)
+	}
+	return f.Amount(amount)
+}
+
+// Money writes an amount like Amount, followed by its ISO 4217 currency code
+func (f Formatter) Money(amount decimal.Decimal, currency string) string {
+	return f.Amount(amount) + " " + strings.ToUpper(currency)
+}
+
+// Quantity writes a number with grouped thousands and without trailing zeros
+func (f Formatter) Quantity(quantity decimal.Decimal) string {
+	return f.number(quantity.String())
+}
+
+// Percent writes a percentage with two decimal places
+func (f Formatter) Percent(percent decimal.Decimal) string {
+	return f.Amount(percent) + "%"
+}
+
+// SignedPercent writes a percentage like Percent, with a plus sign when it is positive
+func (f Formatter) SignedPercent(percent decimal.Decimal) string {
+	return f.SignedAmount(percent) + "%"
+}
+
+// Date writes a calendar date in the locale's short form, such as Jan 2, 2006 or 02.01.2006.
+// Dates carry no time of day and are not moved to the formatter's time zone.
+func (f Formatter) Date(t time.Time) string {
+	return t.Format(f.dateLayout)
+}
+
+// LongDate writes a calendar date in the locale's long form, such as January 2, 2006
+func (f Formatter) LongDate(t time.Time) string {
+	return t.Format(f.longDateLayout)
+}
+
+// DateTime writes an instant as a short date and time of day in the formatter's time zone
+func (f Formatter) DateTime(t time.Time) string {
+	return t.In(f.location).Format(f.dateLayout + " 15:04 MST")
+}
+
+// number writes a decimal number in ASCII digits with the locale's separators
+func (f Formatter) number(number string) string {
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(number, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteString(f.decimal)
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatter_Numbers(t *testing.T) {
+	amount := decimal.RequireFromString("-1234567.891")
+	quantity := decimal.RequireFromString("12500.50")
+
+	tests := []struct {
+		locale   string
+		amount   string
+		quantity string
+	}{
+		{"en-US", "-1,234,567.89", "12,500.5"},
+		{"de-DE", "-1.234.567,89", "12.500,5"},
+		{"fr-FR", "-1\u00a0234\u00a0567,89", "12\u00a0500,5"},
+		{"de-CH", "-1'234'567.89", "12'500.5"},
+		{"ar-EG", "-1,234,567.89", "12,500.5"}, // Arabic digits aren't used
+		{"not a locale", "-1,234,567.89", "12,500.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			f := NewFormatter(tt.locale, "")
+			assert.Equal(t, tt.amount, f.Amount(amount))
+			assert.Equal(t, tt.quantity, f.Quantity(quantity))
+		})
+	}
+
+	f := NewFormatter("de-de", "")
+	assert.Equal(t, "de-DE", f.Locale())
+	assert.Equal(t, "+4,50%", f.SignedPercent(decimal.RequireFromString("4.5")))
+	assert.Equal(t, "-4,50%", f.SignedPercent(decimal.RequireFromString("-4.5")))
+	assert.Equal(t, "0,00", f.SignedAmount(decimal.Zero))
+	assert.Equal(t, "999,00 EUR", f.Money(decimal.NewFromInt(999), "eur"))
+}
+
+func TestFormatter_Dates(t *testing.T) {
+	date := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	instant := time.Date(2025, 7, 1, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		locale, timezone         string
+		date, longDate, dateTime string
+	}{
+		{"en-US", "", "Jan 6, 2025", "January 6, 2025", "Jul 1, 2025 23:30 UTC"},
+		{"en-GB", "Europe/London", "6 Jan 2025", "6 January 2025", "2 Jul 2025 00:30 BST"},
+		{"de-DE", "Europe/Berlin", "06.01.2025", "06.01.2025", "02.07.2025 01:30 CEST"},
+		{"ja-JP", "Asia/Tokyo", "2025/01/06", "2025/01/06", "2025/07/02 08:30 JST"},
+		{"sw-KE", "Mars/Olympus_Mons", "2025-01-06", "2025-01-06", "2025-07-01 23:30 UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			f := NewFormatter(tt.locale, tt.timezone)
+			assert.Equal(t, tt.date, f.Date(date))
+			assert.Equal(t, tt.longDate, f.LongDate(date))
+			assert.Equal(t, tt.dateTime, f.DateTime(instant))
+		})
+	}
+}
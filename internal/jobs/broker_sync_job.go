@@ -0,0 +1,51 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// BrokerSyncJob is a background job that syncs every broker connection, importing the
+// transactions portfolios lack and recording a reconciliation report on each connection
+type BrokerSyncJob struct {
+	connectionService services.BrokerConnectionService
+}
+
+// NewBrokerSyncJob creates a new broker sync job
+func NewBrokerSyncJob(connectionService services.BrokerConnectionService) *BrokerSyncJob {
+	return &BrokerSyncJob{
+		connectionService: connectionService,
+	}
+}
+
+// Name returns the job name
+func (j *BrokerSyncJob) Name() string {
+	return "BrokerSync"
+}
+
+// Schedule returns the job schedule
+// Runs daily; brokers report the previous day's activity overnight
+func (j *BrokerSyncJob) Schedule() string {
+	return "@daily"
+}
+
+// Run executes the job. Connections that fail to sync are marked failed on the connection
+// rather than failing the run.
+func (j *BrokerSyncJob) Run(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	startTime := time.Now()
+	synced, failed, err := j.connectionService.SyncAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sync broker connections: %w", err)
+	}
+
+	log.Printf("Broker sync synced %d connections (%d failed) in %v", synced, failed, time.Since(startTime))
+	return nil
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// stubBrokerConnectionService records sync runs
+type stubBrokerConnectionService struct {
+	runs int
+	err  error
+}
+
+func (s *stubBrokerConnectionService) Connect(ctx context.Context, portfolioID, userID string, req dto.CreateBrokerConnectionRequest) (*models.BrokerConnection, error) {
+	return nil, nil
+}
+
+func (s *stubBrokerConnectionService) List(ctx context.Context, portfolioID, userID string) ([]*models.BrokerConnection, error) {
+	return nil, nil
+}
+
+func (s *stubBrokerConnectionService) Get(ctx context.Context, id, portfolioID, userID string) (*models.BrokerConnection, error) {
+	return nil, nil
+}
+
+func (s *stubBrokerConnectionService) Disconnect(ctx context.Context, id, portfolioID, userID string) error {
+	return nil
+}
+
+func (s *stubBrokerConnectionService) Sync(ctx context.Context, id, portfolioID, userID string, dryRun bool) (*dto.BrokerSyncReport, error) {
+	return nil, nil
+}
+
+func (s *stubBrokerConnectionService) SyncAll(ctx context.Context) (int, int, error) {
+	s.runs++
+	return 2, 1, s.err
+}
+
+func TestBrokerSyncJob_NameAndSchedule(t *testing.T) {
+	job := NewBrokerSyncJob(&stubBrokerConnectionService{})
+	assert.Equal(t, "BrokerSync", job.Name())
+	assert.Equal(t, "@daily", job.Schedule())
+}
+
+func TestBrokerSyncJob_Run(t *testing.T) {
+	t.Run("syncs every connection", func(t *testing.T) {
+		service := &stubBrokerConnectionService{}
+		assert.NoError(t, NewBrokerSyncJob(service).Run(context.Background()))
+		assert.Equal(t, 1, service.runs)
+	})
+
+	t.Run("returns sync errors", func(t *testing.T) {
+		job := NewBrokerSyncJob(&stubBrokerConnectionService{err: errors.New("db down")})
+		assert.Error(t, job.Run(context.Background()))
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		service := &stubBrokerConnectionService{}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.Error(t, NewBrokerSyncJob(service).Run(ctx))
+		assert.Zero(t, service.runs)
+	})
+}
@@ -54,7 +54,7 @@ func (j *CleanupJob) Run(ctx context.Context) error {
 	// Clear market data cache
 	if j.marketDataSvc != nil {
 		log.Println("Clearing market data cache...")
-		j.marketDataSvc.ClearCache()
+		j.marketDataSvc.ClearCache(ctx)
 		log.Println("Market data cache cleared")
 	}
 
@@ -2,13 +2,18 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"log"
 
 	"github.com/lenon/portfolios/internal/services"
 )
 
-// CorporateActionDetectionJob is a background job that detects corporate actions
+// CorporateActionDetectionJob is a background job that detects corporate actions.
+// When an ingester is configured it first pulls newly announced actions from the
+// market data provider, then suggests all unapplied actions to affected portfolios.
 type CorporateActionDetectionJob struct {
-	monitor *services.CorporateActionMonitor
+	monitor  *services.CorporateActionMonitor
+	ingester *services.CorporateActionIngester
 }
 
 // NewCorporateActionDetectionJob creates a new corporate action detection job
@@ -18,6 +23,19 @@ func NewCorporateActionDetectionJob(monitor *services.CorporateActionMonitor) *C
 	}
 }
 
+// NewCorporateActionDetectionJobWithIngester creates a new corporate action detection job
+// that ingests announced actions from a provider feed before detection. A nil ingester
+// only runs detection.
+func NewCorporateActionDetectionJobWithIngester(
+	monitor *services.CorporateActionMonitor,
+	ingester *services.CorporateActionIngester,
+) *CorporateActionDetectionJob {
+	return &CorporateActionDetectionJob{
+		monitor:  monitor,
+		ingester: ingester,
+	}
+}
+
 // Name returns the job name
 func (j *CorporateActionDetectionJob) Name() string {
 	return "CorporateActionDetection"
@@ -31,5 +49,52 @@ func (j *CorporateActionDetectionJob) Schedule() string {
 
 // Run executes the job
 func (j *CorporateActionDetectionJob) Run(ctx context.Context) error {
-	return j.monitor.DetectAndSuggestActions(ctx)
+	return j.RunForSchemas(ctx, nil)
+}
+
+// RunForSchemas executes the job for the public schema and the given tenant schemas.
+// Corporate actions are shared, so the feed is queried once for the symbols held across
+// all of them before actions are suggested to each schema's portfolios.
+func (j *CorporateActionDetectionJob) RunForSchemas(ctx context.Context, schemas []string) error {
+	schemas = append([]string{""}, schemas...)
+
+	if j.ingester != nil {
+		if err := j.ingest(ctx, schemas); err != nil {
+			// Still suggest previously known actions if the feed is unavailable
+			log.Printf("Corporate action ingestion failed: %v", err)
+		}
+	}
+
+	if len(schemas) == 1 {
+		return j.monitor.DetectAndSuggestActions(ctx)
+	}
+
+	var errs []error
+	for _, schema := range schemas {
+		if err := j.monitor.DetectAndSuggestActions(schemaContext(ctx, schema)); err != nil {
+			errs = append(errs, schemaError(schema, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ingest pulls announced actions for every symbol held in any of the schemas
+func (j *CorporateActionDetectionJob) ingest(ctx context.Context, schemas []string) error {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, schema := range schemas {
+		held, err := j.ingester.HeldSymbols(schemaContext(ctx, schema))
+		if err != nil {
+			return schemaError(schema, err)
+		}
+		for _, symbol := range held {
+			if !seen[symbol] {
+				seen[symbol] = true
+				symbols = append(symbols, symbol)
+			}
+		}
+	}
+
+	_, err := j.ingester.IngestSymbols(ctx, symbols)
+	return err
 }
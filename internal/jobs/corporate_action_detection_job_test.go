@@ -26,6 +26,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&models.Holding{},
 		&models.CorporateAction{},
 		&models.PortfolioAction{},
+		&models.PerformanceSnapshot{},
 	)
 	require.NoError(t, err)
 
@@ -158,3 +159,82 @@ func TestCorporateActionDetectionJob_Run_WithContextCancellation(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, errors.Is(err, context.Canceled) || err != nil)
 }
+
+// stubCorporateActionFeed announces a fixed set of splits and no dividends
+type stubCorporateActionFeed struct {
+	splits []*models.CorporateAction
+}
+
+func (f *stubCorporateActionFeed) GetSplits(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	var actions []*models.CorporateAction
+	for _, split := range f.splits {
+		if split.Symbol == symbol {
+			actions = append(actions, split)
+		}
+	}
+	return actions, nil
+}
+
+func (f *stubCorporateActionFeed) GetDividends(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	return nil, nil
+}
+
+func TestCorporateActionDetectionJob_Run_WithIngester(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTestDB(t)
+
+	corporateActionRepo := repository.NewCorporateActionRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	portfolioActionRepo := repository.NewPortfolioActionRepository(db)
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hash",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Test Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	holding := &models.Holding{
+		PortfolioID:  portfolio.ID,
+		Symbol:       "AAPL",
+		Quantity:     decimal.NewFromInt(100),
+		CostBasis:    decimal.NewFromInt(10000),
+		AvgCostPrice: decimal.NewFromInt(100),
+	}
+	require.NoError(t, db.Create(holding).Error)
+
+	// No manually created actions; the split only exists in the feed
+	ratio := decimal.NewFromFloat(2.0)
+	feed := &stubCorporateActionFeed{
+		splits: []*models.CorporateAction{
+			{Symbol: "AAPL", Type: models.CorporateActionTypeSplit, Date: time.Now().UTC(), Ratio: &ratio},
+		},
+	}
+
+	monitor := services.NewCorporateActionMonitor(
+		corporateActionRepo,
+		portfolioRepo,
+		holdingRepo,
+		portfolioActionRepo,
+	)
+	ingester := services.NewCorporateActionIngester(feed, corporateActionRepo, holdingRepo, nil, 0)
+
+	job := NewCorporateActionDetectionJobWithIngester(monitor, ingester)
+
+	err := job.Run(context.Background())
+	require.NoError(t, err)
+
+	pending, err := portfolioActionRepo.FindPendingByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "AAPL", pending[0].CorporateAction.Symbol)
+}
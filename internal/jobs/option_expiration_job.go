@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// OptionExpirationJob is a background job that settles option positions whose contracts have
+// expired, assigning those that finished in the money and letting the rest expire worthless
+type OptionExpirationJob struct {
+	optionService services.OptionService
+	now           func() time.Time
+}
+
+// NewOptionExpirationJob creates a new option expiration job
+func NewOptionExpirationJob(optionService services.OptionService) *OptionExpirationJob {
+	return &OptionExpirationJob{
+		optionService: optionService,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Name returns the job name
+func (j *OptionExpirationJob) Name() string {
+	return "OptionExpiration"
+}
+
+// Schedule returns the job schedule
+// Runs daily; contracts are settled the day after they expire
+func (j *OptionExpirationJob) Schedule() string {
+	return "@daily"
+}
+
+// Run executes the job
+func (j *OptionExpirationJob) Run(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	startTime := time.Now()
+	settled, err := j.optionService.SettleExpired(ctx, j.now())
+	if err != nil {
+		return fmt.Errorf("failed to settle expired options: %w", err)
+	}
+
+	log.Printf("Option expiration settled %d positions in %v", settled, time.Since(startTime))
+	return nil
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// stubOptionService records settlement runs
+type stubOptionService struct {
+	settled int
+	err     error
+	asOf    []time.Time
+}
+
+func (s *stubOptionService) Trade(ctx context.Context, portfolioID, userID string, req *dto.OptionTradeRequest) (*models.Transaction, error) {
+	return nil, nil
+}
+
+func (s *stubOptionService) ListPositions(ctx context.Context, portfolioID, userID string) (*dto.OptionPositionListResponse, error) {
+	return nil, nil
+}
+
+func (s *stubOptionService) Settle(ctx context.Context, portfolioID, contractID, userID string, req *dto.SettleOptionRequest) ([]*models.Transaction, error) {
+	return nil, nil
+}
+
+func (s *stubOptionService) SettleExpired(ctx context.Context, asOf time.Time) (int, error) {
+	s.asOf = append(s.asOf, asOf)
+	return s.settled, s.err
+}
+
+func TestOptionExpirationJob_NameAndSchedule(t *testing.T) {
+	job := NewOptionExpirationJob(&stubOptionService{})
+	assert.Equal(t, "OptionExpiration", job.Name())
+	assert.Equal(t, "@daily", job.Schedule())
+}
+
+func TestOptionExpirationJob_Run(t *testing.T) {
+	t.Run("settles as of now", func(t *testing.T) {
+		service := &stubOptionService{settled: 2}
+		job := NewOptionExpirationJob(service)
+		now := time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC)
+		job.now = func() time.Time { return now }
+
+		assert.NoError(t, job.Run(context.Background()))
+		assert.Equal(t, []time.Time{now}, service.asOf)
+	})
+
+	t.Run("returns settlement errors", func(t *testing.T) {
+		job := NewOptionExpirationJob(&stubOptionService{err: errors.New("db down")})
+		assert.Error(t, job.Run(context.Background()))
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		service := &stubOptionService{}
+		job := NewOptionExpirationJob(service)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.Error(t, job.Run(ctx))
+		assert.Empty(t, service.asOf)
+	})
+}
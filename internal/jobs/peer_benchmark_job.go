@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// PeerBenchmarkJob is a background job that recomputes the anonymized peer benchmarks
+// from portfolios whose owners opted in to peer comparison
+type PeerBenchmarkJob struct {
+	peerComparisonService services.PeerComparisonService
+}
+
+// NewPeerBenchmarkJob creates a new peer benchmark job
+func NewPeerBenchmarkJob(peerComparisonService services.PeerComparisonService) *PeerBenchmarkJob {
+	return &PeerBenchmarkJob{
+		peerComparisonService: peerComparisonService,
+	}
+}
+
+// Name returns the job name
+func (j *PeerBenchmarkJob) Name() string {
+	return "PeerBenchmark"
+}
+
+// Schedule returns the job schedule
+// Runs daily, after the day's performance snapshots are available
+func (j *PeerBenchmarkJob) Schedule() string {
+	return "@daily"
+}
+
+// Run executes the job
+func (j *PeerBenchmarkJob) Run(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("context cancelled: %w", err)
+	}
+
+	startTime := time.Now()
+	published, err := j.peerComparisonService.AggregateBenchmarks(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate peer benchmarks: %w", err)
+	}
+
+	log.Printf("Peer benchmarks published for %d allocation buckets in %v", published, time.Since(startTime))
+	return nil
+}
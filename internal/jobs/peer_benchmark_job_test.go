@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// stubPeerComparisonService records aggregation runs
+type stubPeerComparisonService struct {
+	published int
+	err       error
+	runs      int
+}
+
+func (s *stubPeerComparisonService) SetOptIn(ctx context.Context, portfolioID, userID string, optIn bool) (*models.Portfolio, error) {
+	return nil, nil
+}
+
+func (s *stubPeerComparisonService) GetComparison(ctx context.Context, portfolioID, userID string) (*services.PeerComparisonResult, error) {
+	return nil, nil
+}
+
+func (s *stubPeerComparisonService) AggregateBenchmarks(ctx context.Context) (int, error) {
+	s.runs++
+	return s.published, s.err
+}
+
+func TestPeerBenchmarkJob_NameAndSchedule(t *testing.T) {
+	job := NewPeerBenchmarkJob(&stubPeerComparisonService{})
+	assert.Equal(t, "PeerBenchmark", job.Name())
+	assert.Equal(t, "@daily", job.Schedule())
+}
+
+func TestPeerBenchmarkJob_Run(t *testing.T) {
+	t.Run("aggregates benchmarks", func(t *testing.T) {
+		service := &stubPeerComparisonService{published: 2}
+		job := NewPeerBenchmarkJob(service)
+
+		assert.NoError(t, job.Run(context.Background()))
+		assert.Equal(t, 1, service.runs)
+	})
+
+	t.Run("returns aggregation errors", func(t *testing.T) {
+		job := NewPeerBenchmarkJob(&stubPeerComparisonService{err: errors.New("db down")})
+		assert.Error(t, job.Run(context.Background()))
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		service := &stubPeerComparisonService{}
+		job := NewPeerBenchmarkJob(service)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.Error(t, job.Run(ctx))
+		assert.Equal(t, 0, service.runs)
+	})
+}
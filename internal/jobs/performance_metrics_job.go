@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// PerformanceMetricsJob is a background job that materializes every portfolio's performance
+// metrics over the standard periods (1M, 3M, YTD, 1Y, 3Y and ALL), so the analytics endpoints
+// serve them without recomputing the returns on each request
+type PerformanceMetricsJob struct {
+	portfolioRepo    repository.PortfolioRepository
+	analyticsService services.PerformanceAnalyticsService
+}
+
+// NewPerformanceMetricsJob creates a new performance metrics job
+func NewPerformanceMetricsJob(
+	portfolioRepo repository.PortfolioRepository,
+	analyticsService services.PerformanceAnalyticsService,
+) *PerformanceMetricsJob {
+	return &PerformanceMetricsJob{
+		portfolioRepo:    portfolioRepo,
+		analyticsService: analyticsService,
+	}
+}
+
+// Name returns the job name
+func (j *PerformanceMetricsJob) Name() string {
+	return "PerformanceMetrics"
+}
+
+// Schedule returns the job schedule
+// Runs daily, after the day's performance snapshots are available
+func (j *PerformanceMetricsJob) Schedule() string {
+	return "@daily"
+}
+
+// Run executes the job
+func (j *PerformanceMetricsJob) Run(ctx context.Context) error {
+	portfolios, err := j.portfolioRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list portfolios: %w", err)
+	}
+
+	startTime := time.Now()
+	materialized, periods := 0, 0
+	for _, portfolio := range portfolios {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+
+		covered, err := j.analyticsService.MaterializePeriodMetrics(ctx, portfolio.ID.String())
+		if err != nil {
+			log.Printf("Error materializing performance metrics for portfolio %s: %v", portfolio.ID, err)
+			continue
+		}
+		materialized++
+		periods += covered
+	}
+
+	log.Printf("Performance metrics materialized for %d/%d portfolios (%d periods) in %v",
+		materialized, len(portfolios), periods, time.Since(startTime))
+	return nil
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+func newPerformanceMetricsJob(db *gorm.DB) *PerformanceMetricsJob {
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	return NewPerformanceMetricsJob(portfolioRepo, services.NewPerformanceAnalyticsServiceWithCache(
+		portfolioRepo,
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+		nil,
+		repository.NewPerformanceMetricsCacheRepository(db),
+	))
+}
+
+func TestPerformanceMetricsJob_NameAndSchedule(t *testing.T) {
+	job := newPerformanceMetricsJob(setupTestDB(t))
+	assert.Equal(t, "PerformanceMetrics", job.Name())
+	assert.Equal(t, "@daily", job.Schedule())
+}
+
+func TestPerformanceMetricsJob_Run(t *testing.T) {
+	ctx := context.Background()
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Transaction{}, &models.PerformanceMetricsCache{}))
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Growth", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	// Daily snapshots for the last 40 days cover the 1M and ALL periods
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for date, value := today.AddDate(0, 0, -40), int64(1000); !date.After(today); date, value = date.AddDate(0, 0, 1), value+5 {
+		require.NoError(t, db.Create(&models.PerformanceSnapshot{
+			PortfolioID:    portfolio.ID,
+			Date:           date,
+			TotalValue:     decimal.NewFromInt(value),
+			TotalCostBasis: decimal.NewFromInt(1000),
+		}).Error)
+	}
+
+	require.NoError(t, newPerformanceMetricsJob(db).Run(ctx))
+
+	var entries []*models.PerformanceMetricsCache
+	require.NoError(t, db.Where("portfolio_id = ?", portfolio.ID).Find(&entries).Error)
+	assert.Len(t, entries, len(models.StandardPerformancePeriods))
+
+	covered := map[models.PerformancePeriod]bool{}
+	for _, entry := range entries {
+		covered[entry.Period] = entry.Metrics != "null"
+	}
+	assert.True(t, covered[models.PerformancePeriod1M])
+	assert.True(t, covered[models.PerformancePeriodAll])
+	assert.False(t, covered[models.PerformancePeriod3Y])
+}
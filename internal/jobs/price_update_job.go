@@ -2,25 +2,48 @@ package jobs
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sort"
 	"time"
 
+	"github.com/lenon/portfolios/internal/repository"
 	"github.com/lenon/portfolios/internal/services"
 )
 
-// PriceUpdateJob is a background job that refreshes market data cache
-// In a full implementation, this would:
-// - Fetch end-of-day prices for all symbols in active portfolios
-// - Update holdings with current market values
-// - Refresh quotes in the market data cache
+const (
+	// priceUpdateQuotaShare is the share of the provider's remaining daily budget the job
+	// spends, leaving the rest for users' requests
+	priceUpdateQuotaShare = 0.5
+
+	// priceUpdateMinAge is how old a symbol's last quote must be for the job to refresh it
+	priceUpdateMinAge = 15 * time.Minute
+
+	// priceUpdateFirstInterval stands in for the time since the last run on the first run
+	priceUpdateFirstInterval = time.Hour
+)
+
+// PriceUpdateJob is a background job that keeps the quotes of held symbols fresh. Each run
+// refreshes as many symbols as its share of the provider's daily budget allows, so the
+// budget is spread across the day, starting with the symbols held by the most users whose
+// quotes are the most out of date. Fetching a quote also updates the symbol's trading
+// status (halted or suspended).
 type PriceUpdateJob struct {
 	marketDataSvc services.MarketDataService
+	holdingRepo   repository.HoldingRepository
+
+	// lastRun is when a run last spent its budget
+	lastRun time.Time
+	now     func() time.Time
 }
 
-// NewPriceUpdateJob creates a new price update job
-func NewPriceUpdateJob(marketDataSvc services.MarketDataService) *PriceUpdateJob {
+// NewPriceUpdateJob creates a new price update job. A nil holdingRepo leaves it nothing to
+// refresh.
+func NewPriceUpdateJob(marketDataSvc services.MarketDataService, holdingRepo repository.HoldingRepository) *PriceUpdateJob {
 	return &PriceUpdateJob{
 		marketDataSvc: marketDataSvc,
+		holdingRepo:   holdingRepo,
+		now:           func() time.Time { return time.Now().UTC() },
 	}
 }
 
@@ -30,34 +53,161 @@ func (j *PriceUpdateJob) Name() string {
 }
 
 // Schedule returns the job schedule
-// Runs every day after market close (6 PM ET / 18:00)
+// Runs every hour; each run's share of the provider budget grows with the time since the last
 func (j *PriceUpdateJob) Schedule() string {
-	return "@daily"
+	return "@hourly"
 }
 
 // Run executes the job
 func (j *PriceUpdateJob) Run(ctx context.Context) error {
+	return j.RunForSchemas(ctx, nil)
+}
+
+// RunForSchemas executes the job for the symbols held in the public schema and the given
+// tenant schemas. Quotes are shared, so each symbol is refreshed once.
+func (j *PriceUpdateJob) RunForSchemas(ctx context.Context, schemas []string) error {
 	log.Println("Starting price update job...")
 	startTime := time.Now()
 
-	// Clear the market data cache to force fresh fetches
-	// This ensures stale prices don't persist
-	if j.marketDataSvc != nil {
-		j.marketDataSvc.ClearCache()
-		log.Println("Market data cache cleared")
+	if j.marketDataSvc == nil || j.holdingRepo == nil {
+		return nil
 	}
 
-	// Note: In a full implementation, this job would:
-	// 1. Query all active portfolios
-	// 2. Collect unique symbols from all holdings
-	// 3. Fetch current prices for all symbols in batch
-	// 4. Update holdings or cache with fresh prices
-	//
-	// This requires adding a FindAll() method to PortfolioRepository
-	// and potentially storing current prices in the Holding model.
+	holders, err := j.countHolders(ctx, append([]string{""}, schemas...))
+	if err != nil {
+		return err
+	}
+	symbols := make([]string, 0, len(holders))
+	for symbol := range holders {
+		symbols = append(symbols, symbol)
+	}
+
+	now := j.now()
+	candidates := prioritizeSymbols(holders, j.marketDataSvc.GetQuoteFetchTimes(ctx, symbols), now)
+	if len(candidates) == 0 {
+		j.lastRun = now
+		log.Printf("Price update found no quotes of %d held symbols to refresh", len(symbols))
+		return nil
+	}
+
+	budget := j.budget(now)
+	if budget == 0 {
+		// Leave lastRun alone so the next run's share covers the time since this one too
+		log.Printf("Price update has no provider budget to refresh %d symbols", len(candidates))
+		return nil
+	}
+	if budget > 0 && budget < len(candidates) {
+		candidates = candidates[:budget]
+	}
+	j.lastRun = now
+
+	if err := j.refresh(ctx, candidates); err != nil {
+		return err
+	}
 
 	duration := time.Since(startTime)
 	log.Printf("Price update completed in %v", duration)
 
 	return nil
 }
+
+// countHolders counts the users holding each symbol across the schemas
+func (j *PriceUpdateJob) countHolders(ctx context.Context, schemas []string) (map[string]int, error) {
+	holders := make(map[string]int)
+	for _, schema := range schemas {
+		counts, err := j.holdingRepo.CountHoldersBySymbol(schemaContext(ctx, schema))
+		if err != nil {
+			return nil, schemaError(schema, fmt.Errorf("failed to count holders: %w", err))
+		}
+		for symbol, count := range counts {
+			holders[symbol] += count
+		}
+	}
+	return holders, nil
+}
+
+// budget returns how many quotes this run may fetch, or -1 for no limit
+func (j *PriceUpdateJob) budget(now time.Time) int {
+	elapsed := priceUpdateFirstInterval
+	if !j.lastRun.IsZero() {
+		elapsed = now.Sub(j.lastRun)
+	}
+	return priceUpdateBudget(j.marketDataSvc.GetQuotaStatus(), elapsed, now)
+}
+
+// priceUpdateBudget returns a run's share of the provider's remaining daily budget, in
+// proportion to the time elapsed since the last run out of the time left until the budget
+// resets, and no more than the minute budget allows. -1 is no limit.
+func priceUpdateBudget(status *services.QuotaStatus, elapsed time.Duration, now time.Time) int {
+	budget := -1
+	if status.DailyRemaining >= 0 {
+		share := priceUpdateQuotaShare
+		if untilReset := status.DailyResetAt.Sub(now); elapsed < untilReset {
+			share *= elapsed.Seconds() / untilReset.Seconds()
+		}
+		budget = int(float64(status.DailyRemaining) * share)
+	}
+	if status.MinuteRemaining >= 0 && (budget < 0 || status.MinuteRemaining < budget) {
+		budget = status.MinuteRemaining
+	}
+	return budget
+}
+
+// refresh fetches fresh quotes for symbols and logs the ones that are halted or suspended
+func (j *PriceUpdateJob) refresh(ctx context.Context, symbols []string) error {
+	restrictions, err := j.marketDataSvc.RefreshTradingStatuses(ctx, symbols)
+	if err != nil {
+		return err
+	}
+
+	restricted := make([]string, 0, len(restrictions))
+	for symbol, status := range restrictions {
+		restricted = append(restricted, fmt.Sprintf("%s (%s)", symbol, status.Status))
+	}
+	sort.Strings(restricted)
+	log.Printf("Refreshed quotes of %d symbols, %d restricted %v", len(symbols), len(restricted), restricted)
+
+	return nil
+}
+
+// prioritizeSymbols returns the held symbols whose last quote is at least priceUpdateMinAge
+// old, most urgent first. Symbols never quoted come first, by holders; the rest are ranked
+// by holders times hours since their last quote, so a symbol ten users hold whose quote is an
+// hour old ranks with one a single user holds whose quote is ten hours old.
+func prioritizeSymbols(holders map[string]int, fetched map[string]time.Time, now time.Time) []string {
+	type candidate struct {
+		symbol string
+		quoted bool
+		score  float64
+	}
+
+	candidates := make([]candidate, 0, len(holders))
+	for symbol, count := range holders {
+		fetchedAt, quoted := fetched[symbol]
+		if !quoted {
+			candidates = append(candidates, candidate{symbol: symbol, score: float64(count)})
+			continue
+		}
+		age := now.Sub(fetchedAt)
+		if age < priceUpdateMinAge {
+			continue
+		}
+		candidates = append(candidates, candidate{symbol: symbol, quoted: true, score: float64(count) * age.Hours()})
+	}
+
+	sort.Slice(candidates, func(a, b int) bool {
+		if candidates[a].quoted != candidates[b].quoted {
+			return !candidates[a].quoted
+		}
+		if candidates[a].score != candidates[b].score {
+			return candidates[a].score > candidates[b].score
+		}
+		return candidates[a].symbol < candidates[b].symbol
+	})
+
+	symbols := make([]string, len(candidates))
+	for i, c := range candidates {
+		symbols[i] = c.symbol
+	}
+	return symbols
+}
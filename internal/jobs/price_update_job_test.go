@@ -2,26 +2,196 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/cache"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
 )
 
+// haltingQuoteProvider quotes every symbol, reporting the ones in halted as halted
+type haltingQuoteProvider struct {
+	halted map[string]bool
+	quoted []string
+}
+
+func (p *haltingQuoteProvider) GetQuote(ctx context.Context, symbol string) (*services.Quote, error) {
+	p.quoted = append(p.quoted, symbol)
+	quote := &services.Quote{
+		Symbol:           symbol,
+		Price:            decimal.NewFromInt(100),
+		Volume:           1000,
+		LatestTradingDay: time.Now().UTC(),
+		TradingStatus:    dto.TradingStatusActive,
+	}
+	if p.halted[symbol] {
+		quote.Volume = 0
+		quote.TradingStatus = dto.TradingStatusHalted
+	}
+	return quote, nil
+}
+
+func (p *haltingQuoteProvider) GetQuotes(ctx context.Context, symbols []string) (map[string]*services.Quote, error) {
+	quotes := make(map[string]*services.Quote, len(symbols))
+	for _, symbol := range symbols {
+		quotes[symbol], _ = p.GetQuote(ctx, symbol)
+	}
+	return quotes, nil
+}
+
+func (p *haltingQuoteProvider) GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*services.HistoricalPrice, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *haltingQuoteProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error) {
+	return decimal.Zero, errors.New("not implemented")
+}
+
+func (p *haltingQuoteProvider) IsAvailable() bool {
+	return true
+}
+
 func TestPriceUpdateJob_Name(t *testing.T) {
-	job := NewPriceUpdateJob(nil)
+	job := NewPriceUpdateJob(nil, nil)
 	assert.Equal(t, "PriceUpdate", job.Name())
 }
 
 func TestPriceUpdateJob_Schedule(t *testing.T) {
-	job := NewPriceUpdateJob(nil)
-	assert.Equal(t, "@daily", job.Schedule())
+	job := NewPriceUpdateJob(nil, nil)
+	assert.Equal(t, "@hourly", job.Schedule())
 }
 
 func TestPriceUpdateJob_Run(t *testing.T) {
-	job := NewPriceUpdateJob(nil)
+	job := NewPriceUpdateJob(nil, nil)
 	ctx := context.Background()
 
 	// Should not error even with nil service
 	err := job.Run(ctx)
 	assert.NoError(t, err)
 }
+
+func TestPriceUpdateJob_Run_RefreshesTradingStatuses(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	for _, name := range []string{"Growth", "Income"} {
+		portfolio := &models.Portfolio{
+			UserID:          user.ID,
+			Name:            name,
+			BaseCurrency:    "USD",
+			CostBasisMethod: models.CostBasisFIFO,
+		}
+		require.NoError(t, db.Create(portfolio).Error)
+		for _, symbol := range []string{"AAPL", "HALT"} {
+			require.NoError(t, db.Create(&models.Holding{
+				PortfolioID:  portfolio.ID,
+				Symbol:       symbol,
+				Quantity:     decimal.NewFromInt(10),
+				CostBasis:    decimal.NewFromInt(1000),
+				AvgCostPrice: decimal.NewFromInt(100),
+			}).Error)
+		}
+	}
+
+	provider := &haltingQuoteProvider{halted: map[string]bool{"HALT": true}}
+	marketData := services.NewMarketDataService(provider, time.Hour)
+	job := NewPriceUpdateJob(marketData, repository.NewHoldingRepository(db))
+
+	require.NoError(t, job.Run(ctx))
+
+	// Each held symbol is quoted once however many portfolios hold it
+	assert.ElementsMatch(t, []string{"AAPL", "HALT"}, provider.quoted)
+
+	restrictions := marketData.GetTradingRestrictions(ctx, []string{"AAPL", "HALT"})
+	assert.Len(t, restrictions, 1)
+	if assert.Contains(t, restrictions, "HALT") {
+		assert.Equal(t, dto.TradingStatusHalted, restrictions["HALT"].Status)
+	}
+}
+
+func TestPriceUpdateJob_Run_SpendsItsBudgetOnTheMostHeldSymbols(t *testing.T) {
+	db := setupTestDB(t)
+	ctx := context.Background()
+
+	// MSFT is held by three users, AAPL by two and VTI by one
+	holdings := map[string][]string{
+		"a@example.com": {"MSFT", "AAPL", "VTI"},
+		"b@example.com": {"MSFT", "AAPL"},
+		"c@example.com": {"MSFT"},
+	}
+	for email, symbols := range holdings {
+		user := &models.User{Email: email, PasswordHash: "hash"}
+		require.NoError(t, db.Create(user).Error)
+		portfolio := &models.Portfolio{UserID: user.ID, Name: "Main", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+		require.NoError(t, db.Create(portfolio).Error)
+		for _, symbol := range symbols {
+			require.NoError(t, db.Create(&models.Holding{
+				PortfolioID:  portfolio.ID,
+				Symbol:       symbol,
+				Quantity:     decimal.NewFromInt(10),
+				CostBasis:    decimal.NewFromInt(1000),
+				AvgCostPrice: decimal.NewFromInt(100),
+			}).Error)
+		}
+	}
+
+	// Two requests a minute leave the job two quotes per run
+	provider := &haltingQuoteProvider{}
+	store := cache.NewMemoryStore()
+	marketData := services.NewMarketDataServiceWithQuota(provider, store, services.NewQuotaTracker("test", 0, 2), time.Hour)
+	require.NoError(t, NewPriceUpdateJob(marketData, repository.NewHoldingRepository(db)).Run(ctx))
+	assert.Equal(t, []string{"MSFT", "AAPL"}, provider.quoted)
+
+	// Quotes just fetched aren't refreshed again, so the next run gets to VTI
+	provider.quoted = nil
+	marketData = services.NewMarketDataServiceWithQuota(provider, store, services.NewQuotaTracker("test", 0, 2), time.Hour)
+	require.NoError(t, NewPriceUpdateJob(marketData, repository.NewHoldingRepository(db)).Run(ctx))
+	assert.Equal(t, []string{"VTI"}, provider.quoted)
+}
+
+func TestPrioritizeSymbols(t *testing.T) {
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	holders := map[string]int{"AAPL": 10, "MSFT": 1, "VTI": 2, "NEW": 1, "FRESH": 50}
+	fetched := map[string]time.Time{
+		"AAPL":  now.Add(-time.Hour),      // 10 holders x 1 hour
+		"MSFT":  now.Add(-20 * time.Hour), // 1 holder x 20 hours
+		"VTI":   now.Add(-2 * time.Hour),  // 2 holders x 2 hours
+		"FRESH": now.Add(-time.Minute),
+	}
+
+	assert.Equal(t, []string{"NEW", "MSFT", "AAPL", "VTI"}, prioritizeSymbols(holders, fetched, now))
+}
+
+func TestPriceUpdateBudget(t *testing.T) {
+	now := time.Date(2024, 6, 3, 12, 0, 0, 0, time.UTC)
+	reset := now.Add(12 * time.Hour)
+
+	tests := []struct {
+		name    string
+		status  services.QuotaStatus
+		elapsed time.Duration
+		want    int
+	}{
+		{"unlimited", services.QuotaStatus{DailyRemaining: -1, MinuteRemaining: -1}, time.Hour, -1},
+		{"an hour of the twelve left", services.QuotaStatus{DailyRemaining: 480, DailyResetAt: reset, MinuteRemaining: -1}, time.Hour, 20},
+		{"capped by the minute budget", services.QuotaStatus{DailyRemaining: 480, DailyResetAt: reset, MinuteRemaining: 5}, time.Hour, 5},
+		{"the last run before the reset", services.QuotaStatus{DailyRemaining: 40, DailyResetAt: now.Add(30 * time.Minute), MinuteRemaining: -1}, time.Hour, 20},
+		{"too little to spend yet", services.QuotaStatus{DailyRemaining: 10, DailyResetAt: reset, MinuteRemaining: -1}, time.Hour, 0},
+		{"only the minute budget", services.QuotaStatus{DailyRemaining: -1, MinuteRemaining: 3}, time.Hour, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, priceUpdateBudget(&tt.status, tt.elapsed, now))
+		})
+	}
+}
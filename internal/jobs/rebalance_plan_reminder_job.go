@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// RebalancePlanReminderJob is a background job that emails users about active rebalance
+// plans that have gone stale with trades still pending. Each plan is reminded at most
+// once per staleness period.
+type RebalancePlanReminderJob struct {
+	planRepo     repository.RebalancePlanRepository
+	userRepo     repository.UserRepository
+	emailService services.EmailService
+	now          func() time.Time
+}
+
+// NewRebalancePlanReminderJob creates a new rebalance plan reminder job
+func NewRebalancePlanReminderJob(
+	planRepo repository.RebalancePlanRepository,
+	userRepo repository.UserRepository,
+	emailService services.EmailService,
+) *RebalancePlanReminderJob {
+	return &RebalancePlanReminderJob{
+		planRepo:     planRepo,
+		userRepo:     userRepo,
+		emailService: emailService,
+		now:          func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Name returns the job name
+func (j *RebalancePlanReminderJob) Name() string {
+	return "RebalancePlanReminder"
+}
+
+// Schedule returns the job schedule
+func (j *RebalancePlanReminderJob) Schedule() string {
+	return "@daily"
+}
+
+// Run executes the job
+func (j *RebalancePlanReminderJob) Run(ctx context.Context) error {
+	plans, err := j.planRepo.FindActive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list active rebalance plans: %w", err)
+	}
+
+	now := j.now()
+	sent := 0
+	for _, plan := range plans {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+
+		if !plan.ReminderDue(now) || plan.Portfolio == nil {
+			continue
+		}
+
+		user, err := j.userRepo.FindByID(plan.Portfolio.UserID.String())
+		if err != nil {
+			log.Printf("Error loading owner of rebalance plan %s: %v", plan.ID, err)
+			continue
+		}
+
+		if err := j.emailService.SendRebalancePlanReminderEmail(user.Email, plan.Name, plan.PendingTrades(), plan.LastActivityAt()); err != nil {
+			log.Printf("Error sending reminder for rebalance plan %s: %v", plan.ID, err)
+			continue
+		}
+
+		plan.LastReminderAt = &now
+		if err := j.planRepo.Update(ctx, plan); err != nil {
+			log.Printf("Error recording reminder for rebalance plan %s: %v", plan.ID, err)
+			continue
+		}
+		sent++
+	}
+
+	log.Printf("Rebalance plan reminders sent: %d of %d active plans", sent, len(plans))
+	return nil
+}
@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// recordingEmailService records rebalance plan reminders and performance digests instead of
+// sending them
+type recordingEmailService struct {
+	reminders []string
+	digests   []*dto.PerformanceDigest
+}
+
+func (s *recordingEmailService) SendPasswordResetEmail(to, resetToken string) error {
+	return nil
+}
+
+func (s *recordingEmailService) SendRebalancePlanReminderEmail(to, planName string, pendingTrades int, lastActivity time.Time) error {
+	s.reminders = append(s.reminders, to+":"+planName)
+	return nil
+}
+
+func (s *recordingEmailService) SendInviteEmail(to, organizationName, inviteToken string, expiresAt time.Time) error {
+	return nil
+}
+
+func (s *recordingEmailService) SendOrganizationInviteEmail(to, organizationName, inviterEmail string, role models.OrganizationRole, inviteToken string, expiresAt time.Time) error {
+	return nil
+}
+
+func (s *recordingEmailService) SendDelegationRequestEmail(to, advisorEmail string, access models.DelegationAccess, consentToken string, expiresAt time.Time) error {
+	return nil
+}
+
+func (s *recordingEmailService) SendPerformanceDigestEmail(to string, digest *dto.PerformanceDigest, unsubscribeToken string) error {
+	s.digests = append(s.digests, digest)
+	return nil
+}
+
+func TestRebalancePlanReminderJob_Run(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.RebalancePlan{}, &models.RebalancePlanTrade{}))
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Test Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	now := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	planRepo := repository.NewRebalancePlanRepository(db)
+	newPlan := func(name string, createdAt time.Time) *models.RebalancePlan {
+		plan := &models.RebalancePlan{
+			PortfolioID: portfolio.ID,
+			Name:        name,
+			CreatedAt:   createdAt,
+			Trades: []*models.RebalancePlanTrade{
+				{Symbol: "VTI", Side: models.TransactionTypeBuy, PlannedQuantity: decimal.NewFromInt(10), PlannedPrice: decimal.NewFromInt(250)},
+			},
+		}
+		require.NoError(t, planRepo.Create(ctx, plan))
+		return plan
+	}
+	newPlan("Stale", now.AddDate(0, 0, -10))
+	newPlan("Fresh", now.AddDate(0, 0, -2))
+
+	emailService := &recordingEmailService{}
+	job := NewRebalancePlanReminderJob(planRepo, repository.NewUserRepository(db), emailService)
+	job.now = func() time.Time { return now }
+
+	assert.Equal(t, "RebalancePlanReminder", job.Name())
+	assert.Equal(t, "@daily", job.Schedule())
+
+	require.NoError(t, job.Run(context.Background()))
+	assert.Equal(t, []string{"investor@example.com:Stale"}, emailService.reminders)
+
+	// The next day's run doesn't remind again
+	job.now = func() time.Time { return now.AddDate(0, 0, 1) }
+	require.NoError(t, job.Run(context.Background()))
+	assert.Len(t, emailService.reminders, 1)
+}
@@ -0,0 +1,135 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// ReportDigestJob is a background job that emails weekly and monthly performance digests to
+// users with a report subscription. Each subscription is sent one digest per period, covering
+// the period that last ended, so a run missed on the day a period ends catches up on the next.
+type ReportDigestJob struct {
+	subscriptionRepo    repository.ReportSubscriptionRepository
+	userRepo            repository.UserRepository
+	subscriptionService services.ReportSubscriptionService
+	emailService        services.EmailService
+	notifications       services.NotificationService
+	now                 func() time.Time
+}
+
+// NewReportDigestJob creates a new report digest job
+func NewReportDigestJob(
+	subscriptionRepo repository.ReportSubscriptionRepository,
+	userRepo repository.UserRepository,
+	subscriptionService services.ReportSubscriptionService,
+	emailService services.EmailService,
+) *ReportDigestJob {
+	return &ReportDigestJob{
+		subscriptionRepo:    subscriptionRepo,
+		userRepo:            userRepo,
+		subscriptionService: subscriptionService,
+		emailService:        emailService,
+		now:                 func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// NewReportDigestJobWithNotifications creates a report digest job that also tells users in
+// the app that their digest was sent
+func NewReportDigestJobWithNotifications(
+	subscriptionRepo repository.ReportSubscriptionRepository,
+	userRepo repository.UserRepository,
+	subscriptionService services.ReportSubscriptionService,
+	emailService services.EmailService,
+	notifications services.NotificationService,
+) *ReportDigestJob {
+	job := NewReportDigestJob(subscriptionRepo, userRepo, subscriptionService, emailService)
+	job.notifications = notifications
+	return job
+}
+
+// Name returns the job name
+func (j *ReportDigestJob) Name() string {
+	return "ReportDigest"
+}
+
+// Schedule returns the job schedule
+func (j *ReportDigestJob) Schedule() string {
+	return "@daily"
+}
+
+// Run executes the job
+func (j *ReportDigestJob) Run(ctx context.Context) error {
+	subscriptions, err := j.subscriptionRepo.FindEnabled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list report subscriptions: %w", err)
+	}
+
+	now := j.now()
+	sent := 0
+	for _, subscription := range subscriptions {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+
+		if !subscription.DigestDue(now) {
+			continue
+		}
+
+		user, err := j.userRepo.FindByID(subscription.UserID.String())
+		if err != nil {
+			log.Printf("Error loading owner of report subscription %s: %v", subscription.ID, err)
+			continue
+		}
+
+		start, end := subscription.DigestPeriod(now)
+		digest, err := j.subscriptionService.BuildDigest(ctx, subscription, start, end)
+		if err != nil {
+			log.Printf("Error building digest for report subscription %s: %v", subscription.ID, err)
+			continue
+		}
+
+		token := j.subscriptionService.UnsubscribeToken(subscription)
+		if err := j.emailService.SendPerformanceDigestEmail(user.Email, digest, token); err != nil {
+			log.Printf("Error sending digest for report subscription %s: %v", subscription.ID, err)
+			continue
+		}
+
+		subscription.LastSentAt = &now
+		if err := j.subscriptionRepo.Update(ctx, subscription); err != nil {
+			log.Printf("Error recording digest for report subscription %s: %v", subscription.ID, err)
+			continue
+		}
+		sent++
+
+		j.notify(ctx, subscription, start, end)
+	}
+
+	log.Printf("Performance digests sent: %d of %d report subscriptions", sent, len(subscriptions))
+	return nil
+}
+
+// notify tells a subscription's owner that the digest of the half-open period from start to end
+// was sent
+func (j *ReportDigestJob) notify(ctx context.Context, subscription *models.ReportSubscription, start, end time.Time) {
+	if j.notifications == nil {
+		return
+	}
+
+	notification := &models.Notification{
+		UserID: subscription.UserID,
+		Type:   models.NotificationTypeReportGenerated,
+		Title:  fmt.Sprintf("Your %s performance digest is ready", strings.ToLower(string(subscription.Frequency))),
+		Message: fmt.Sprintf("The digest covering %s to %s was sent to your email address",
+			start.Format("2006-01-02"), end.AddDate(0, 0, -1).Format("2006-01-02")),
+	}
+	if err := j.notifications.Notify(ctx, notification); err != nil {
+		log.Printf("Error notifying owner of report subscription %s: %v", subscription.ID, err)
+	}
+}
@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+func TestReportDigestJob_Run(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(
+		&models.Transaction{}, &models.ReportSubscription{}, &models.ReportSubscriptionPortfolio{}, &models.Notification{},
+	))
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Test Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	subscriptionRepo := repository.NewReportSubscriptionRepository(db)
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, frequency := range []models.ReportFrequency{models.ReportFrequencyWeekly, models.ReportFrequencyMonthly} {
+		require.NoError(t, subscriptionRepo.Create(ctx, &models.ReportSubscription{
+			UserID:    user.ID,
+			Frequency: frequency,
+			Enabled:   true,
+			CreatedAt: created,
+		}))
+	}
+
+	subscriptionService := services.NewReportSubscriptionService(
+		subscriptionRepo,
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+		repository.NewHoldingRepository(db),
+		nil,
+		[]byte("test-secret"),
+	)
+
+	emailService := &recordingEmailService{}
+	notifications := services.NewNotificationService(repository.NewNotificationRepository(db))
+	job := NewReportDigestJobWithNotifications(
+		subscriptionRepo, repository.NewUserRepository(db), subscriptionService, emailService, notifications,
+	)
+
+	assert.Equal(t, "ReportDigest", job.Name())
+	assert.Equal(t, "@daily", job.Schedule())
+
+	// Monday the 13th: the first full week has ended but no full month has
+	now := time.Date(2025, 1, 13, 6, 0, 0, 0, time.UTC)
+	job.now = func() time.Time { return now }
+	require.NoError(t, job.Run(ctx))
+	require.Len(t, emailService.digests, 1)
+	assert.Equal(t, models.ReportFrequencyWeekly, emailService.digests[0].Frequency)
+	assert.Equal(t, time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), emailService.digests[0].StartDate)
+	require.Len(t, emailService.digests[0].Portfolios, 1)
+	assert.Equal(t, "Test Portfolio", emailService.digests[0].Portfolios[0].Name)
+	listed, _, err := notifications.List(ctx, user.ID.String(), false, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, models.NotificationTypeReportGenerated, listed[0].Type)
+	assert.Equal(t, "The digest covering 2025-01-06 to 2025-01-12 was sent to your email address", listed[0].Message)
+
+	// The next day's run doesn't send the week again
+	job.now = func() time.Time { return now.AddDate(0, 0, 1) }
+	require.NoError(t, job.Run(ctx))
+	assert.Len(t, emailService.digests, 1)
+
+	// February 1st sends January's monthly digest, and the weekly one catches up on the
+	// week of January 20th
+	job.now = func() time.Time { return time.Date(2025, 2, 1, 6, 0, 0, 0, time.UTC) }
+	require.NoError(t, job.Run(ctx))
+	require.Len(t, emailService.digests, 3)
+	starts := map[models.ReportFrequency]time.Time{}
+	for _, digest := range emailService.digests[1:] {
+		starts[digest.Frequency] = digest.StartDate
+	}
+	assert.Equal(t, created, starts[models.ReportFrequencyMonthly])
+	assert.Equal(t, time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), starts[models.ReportFrequencyWeekly])
+}
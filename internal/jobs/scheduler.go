@@ -2,11 +2,35 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// ErrInvalidSchedule is returned for schedules the scheduler doesn't understand and for
+// schedules of jobs that don't exist
+var ErrInvalidSchedule = errors.New("invalid job schedule")
+
+// scheduledJobNames are the names of the jobs the scheduler may run, which schedule
+// overrides refer to
+var scheduledJobNames = []string{
+	"BrokerSync",
+	"Cleanup",
+	"CorporateActionDetection",
+	"OptionExpiration",
+	"PeerBenchmark",
+	"PriceUpdate",
+	"RebalancePlanReminder",
+	"ReportDigest",
+	"SnapshotCompaction",
+	"SnapshotGeneration",
+}
+
 // Job represents a scheduled job
 type Job interface {
 	Name() string
@@ -16,21 +40,102 @@ type Job interface {
 
 // Scheduler manages and runs scheduled jobs
 type Scheduler struct {
-	jobs   []Job
-	ctx    context.Context
-	cancel context.CancelFunc
-	wg     sync.WaitGroup
-	mu     sync.RWMutex
+	jobs []Job
+	// schedules overrides the schedules of jobs by name
+	schedules map[string]string
+	// reschedule tells each running job its new interval
+	reschedule map[string]chan time.Duration
+	// stopping stops the jobs' schedules; cancelling ctx cancels the job runs in progress
+	stopping context.Context
+	stop     context.CancelFunc
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	mu       sync.RWMutex
+
+	// running are the start times of the job runs in progress, by job name
+	running   map[string]time.Time
+	runningMu sync.Mutex
 }
 
 // NewScheduler creates a new job scheduler
 func NewScheduler() *Scheduler {
+	stopping, stop := context.WithCancel(context.Background())
 	ctx, cancel := context.WithCancel(context.Background())
 	return &Scheduler{
-		jobs:   make([]Job, 0),
-		ctx:    ctx,
-		cancel: cancel,
+		jobs:       make([]Job, 0),
+		schedules:  make(map[string]string),
+		reschedule: make(map[string]chan time.Duration),
+		stopping:   stopping,
+		stop:       stop,
+		ctx:        ctx,
+		cancel:     cancel,
+		running:    make(map[string]time.Time),
+	}
+}
+
+// ValidateSchedules checks that schedule overrides name jobs the scheduler knows and that
+// ParseSchedule understands their schedules
+func ValidateSchedules(schedules map[string]string) error {
+	names := make([]string, 0, len(schedules))
+	for name := range schedules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if !slices.Contains(scheduledJobNames, name) {
+			errs = append(errs, fmt.Errorf("job %s: %w: no such job, expected one of %s",
+				name, ErrInvalidSchedule, strings.Join(scheduledJobNames, ", ")))
+			continue
+		}
+		if _, err := ParseSchedule(schedules[name]); err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetSchedules replaces the schedule overrides, so that each named job runs on its new
+// schedule instead of its own. Jobs left out go back to their own schedules. Jobs that are
+// already running start their new interval from now. Nothing changes if the overrides are
+// invalid.
+func (s *Scheduler) SetSchedules(schedules map[string]string) error {
+	if err := ValidateSchedules(schedules); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.schedules = make(map[string]string, len(schedules))
+	for name, schedule := range schedules {
+		s.schedules[name] = schedule
+	}
+
+	for _, job := range s.jobs {
+		reschedule, running := s.reschedule[job.Name()]
+		if !running {
+			continue
+		}
+		interval := s.parseSchedule(s.scheduleOf(job))
+		// Replace a new interval the job hasn't picked up yet
+		select {
+		case <-reschedule:
+		default:
+		}
+		reschedule <- interval
+	}
+	return nil
+}
+
+// scheduleOf returns the schedule job runs on. Callers must hold s.mu.
+func (s *Scheduler) scheduleOf(job Job) string {
+	if schedule, ok := s.schedules[job.Name()]; ok {
+		return schedule
 	}
+	return job.Schedule()
 }
 
 // AddJob adds a job to the scheduler
@@ -43,33 +148,78 @@ func (s *Scheduler) AddJob(job Job) {
 
 // Start begins running all scheduled jobs
 func (s *Scheduler) Start() {
-	s.mu.RLock()
+	s.mu.Lock()
 	jobs := make([]Job, len(s.jobs))
 	copy(jobs, s.jobs)
-	s.mu.RUnlock()
+	intervals := make([]time.Duration, len(jobs))
+	reschedules := make([]chan time.Duration, len(jobs))
+	for i, job := range jobs {
+		intervals[i] = s.parseSchedule(s.scheduleOf(job))
+		reschedules[i] = make(chan time.Duration, 1)
+		s.reschedule[job.Name()] = reschedules[i]
+	}
+	s.mu.Unlock()
 
-	for _, job := range jobs {
+	for i, job := range jobs {
 		s.wg.Add(1)
-		go s.runJob(job)
+		go s.runJob(job, intervals[i], reschedules[i])
 	}
 
 	log.Printf("Scheduler started with %d jobs", len(jobs))
 }
 
-// Stop gracefully stops the scheduler
-func (s *Scheduler) Stop() {
+// Stop stops running jobs on their schedules and waits for the runs in progress to finish.
+// If ctx is done first, the runs still in progress are logged and cancelled, which stops
+// them at their next cancellation check.
+func (s *Scheduler) Stop(ctx context.Context) error {
 	log.Println("Stopping scheduler...")
-	s.cancel()
-	s.wg.Wait()
-	log.Println("Scheduler stopped")
+	s.stop()
+	defer s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		for _, run := range s.runningJobs() {
+			log.Printf("Interrupting job %s after %v", run.name, time.Since(run.started).Round(time.Millisecond))
+		}
+		s.cancel()
+		<-done
+		log.Println("Scheduler stopped with running jobs cancelled")
+		return ctx.Err()
+	}
 }
 
-// runJob runs a single job on its schedule
-func (s *Scheduler) runJob(job Job) {
+// jobRun is a job run in progress
+type jobRun struct {
+	name    string
+	started time.Time
+}
+
+// runningJobs returns the job runs in progress, the longest running first
+func (s *Scheduler) runningJobs() []jobRun {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+
+	runs := make([]jobRun, 0, len(s.running))
+	for name, started := range s.running {
+		runs = append(runs, jobRun{name: name, started: started})
+	}
+	sort.Slice(runs, func(i, k int) bool { return runs[i].started.Before(runs[k].started) })
+	return runs
+}
+
+// runJob runs a single job every interval until the interval is changed through reschedule
+func (s *Scheduler) runJob(job Job, interval time.Duration, reschedule <-chan time.Duration) {
 	defer s.wg.Done()
 
-	// Parse schedule to determine interval
-	interval := s.parseSchedule(job.Schedule())
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -80,11 +230,17 @@ func (s *Scheduler) runJob(job Job) {
 
 	for {
 		select {
-		case <-s.ctx.Done():
+		case <-s.stopping.Done():
 			log.Printf("Job %s stopped", job.Name())
 			return
 		case <-ticker.C:
-			s.executeJob(job)
+			// The stop and the tick may have come together
+			if s.stopping.Err() == nil {
+				s.executeJob(job)
+			}
+		case interval = <-reschedule:
+			ticker.Reset(interval)
+			log.Printf("Job %s rescheduled to run every %v", job.Name(), interval)
 		}
 	}
 }
@@ -94,6 +250,15 @@ func (s *Scheduler) executeJob(job Job) {
 	log.Printf("Running job: %s", job.Name())
 	startTime := time.Now()
 
+	s.runningMu.Lock()
+	s.running[job.Name()] = startTime
+	s.runningMu.Unlock()
+	defer func() {
+		s.runningMu.Lock()
+		delete(s.running, job.Name())
+		s.runningMu.Unlock()
+	}()
+
 	if err := job.Run(s.ctx); err != nil {
 		log.Printf("Job %s failed: %v", job.Name(), err)
 	} else {
@@ -101,31 +266,39 @@ func (s *Scheduler) executeJob(job Job) {
 	}
 }
 
-// parseSchedule converts a cron-like schedule string to a duration
+// ParseSchedule converts a cron-like schedule string to a duration
 // For simplicity, we support a few common patterns:
+// "@weekly" or "0 0 * * 0" -> 7 days
 // "@daily" or "0 0 * * *" -> 24 hours
 // "@hourly" or "0 * * * *" -> 1 hour
-// "@every Xm" -> X minutes
-// "@every Xh" -> X hours
-// Default: 24 hours
-func (s *Scheduler) parseSchedule(schedule string) time.Duration {
+// "@every <duration>" -> the duration, for example "@every 30m" or "@every 6h"
+func ParseSchedule(schedule string) (time.Duration, error) {
 	switch schedule {
+	case "@weekly", "0 0 * * 0":
+		return 7 * 24 * time.Hour, nil
 	case "@daily", "0 0 * * *":
-		return 24 * time.Hour
+		return 24 * time.Hour, nil
 	case "@hourly", "0 * * * *":
-		return time.Hour
-	case "@every 30m":
-		return 30 * time.Minute
-	case "@every 1h":
-		return time.Hour
-	case "@every 6h":
-		return 6 * time.Hour
-	case "@every 12h":
-		return 12 * time.Hour
-	default:
-		// Default to daily
+		return time.Hour, nil
+	}
+
+	if every, ok := strings.CutPrefix(schedule, "@every "); ok {
+		interval, err := time.ParseDuration(every)
+		if err == nil && interval > 0 {
+			return interval, nil
+		}
+	}
+	return 0, fmt.Errorf("%w %q: expected @hourly, @daily, @weekly or @every <duration>", ErrInvalidSchedule, schedule)
+}
+
+// parseSchedule converts a schedule string to a duration, running jobs with schedules
+// ParseSchedule doesn't understand daily
+func (s *Scheduler) parseSchedule(schedule string) time.Duration {
+	interval, err := ParseSchedule(schedule)
+	if err != nil {
 		return 24 * time.Hour
 	}
+	return interval
 }
 
 // RunOnce runs a job immediately (useful for testing or manual triggers)
@@ -141,3 +314,15 @@ func (s *Scheduler) RunOnce(jobName string) error {
 
 	return nil
 }
+
+// JobNames returns the names of the scheduled jobs in the order they were added
+func (s *Scheduler) JobNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, len(s.jobs))
+	for i, job := range s.jobs {
+		names[i] = job.Name()
+	}
+	return names
+}
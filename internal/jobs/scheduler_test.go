@@ -9,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // Mock job for testing
@@ -84,6 +86,7 @@ func TestScheduler_AddMultipleJobs(t *testing.T) {
 	scheduler.AddJob(job2)
 
 	assert.Len(t, scheduler.jobs, 2)
+	assert.Equal(t, []string{"job1", "job2"}, scheduler.JobNames())
 }
 
 func TestScheduler_StartAndStop(t *testing.T) {
@@ -96,7 +99,7 @@ func TestScheduler_StartAndStop(t *testing.T) {
 	// Give scheduler time to start
 	time.Sleep(100 * time.Millisecond)
 
-	scheduler.Stop()
+	require.NoError(t, scheduler.Stop(context.Background()))
 
 	// Verify job was initialized (scheduler started successfully)
 	assert.GreaterOrEqual(t, job.getRunCount(), 0)
@@ -113,7 +116,7 @@ func TestScheduler_JobExecution(t *testing.T) {
 	// Wait a bit for any initial execution
 	time.Sleep(100 * time.Millisecond)
 
-	scheduler.Stop()
+	require.NoError(t, scheduler.Stop(context.Background()))
 
 	// Job should have been set up (even if not run yet due to ticker)
 	assert.GreaterOrEqual(t, job.getRunCount(), 0)
@@ -129,7 +132,7 @@ func TestScheduler_JobWithError(t *testing.T) {
 
 	time.Sleep(100 * time.Millisecond)
 
-	scheduler.Stop()
+	require.NoError(t, scheduler.Stop(context.Background()))
 
 	// Job should still be callable despite error
 	assert.GreaterOrEqual(t, job.getRunCount(), 0)
@@ -167,6 +170,8 @@ func TestScheduler_ParseSchedule(t *testing.T) {
 		schedule string
 		expected time.Duration
 	}{
+		{"weekly", "@weekly", 7 * 24 * time.Hour},
+		{"weekly cron", "0 0 * * 0", 7 * 24 * time.Hour},
 		{"daily", "@daily", 24 * time.Hour},
 		{"daily cron", "0 0 * * *", 24 * time.Hour},
 		{"hourly", "@hourly", time.Hour},
@@ -175,7 +180,9 @@ func TestScheduler_ParseSchedule(t *testing.T) {
 		{"every 1h", "@every 1h", time.Hour},
 		{"every 6h", "@every 6h", 6 * time.Hour},
 		{"every 12h", "@every 12h", 12 * time.Hour},
+		{"every 90m", "@every 90m", 90 * time.Minute},
 		{"unknown", "unknown", 24 * time.Hour}, // default
+		{"negative every", "@every -1h", 24 * time.Hour},
 	}
 
 	for _, tt := range tests {
@@ -199,7 +206,7 @@ func TestScheduler_ConcurrentJobs(t *testing.T) {
 
 	scheduler.Start()
 	time.Sleep(100 * time.Millisecond)
-	scheduler.Stop()
+	require.NoError(t, scheduler.Stop(context.Background()))
 
 	// All jobs should be set up
 	assert.GreaterOrEqual(t, job1.getRunCount(), 0)
@@ -218,7 +225,7 @@ func TestScheduler_ContextCancellation(t *testing.T) {
 	time.Sleep(50 * time.Millisecond)
 
 	// Stop should cancel context
-	scheduler.Stop()
+	require.NoError(t, scheduler.Stop(context.Background()))
 
 	// Context should be cancelled
 	select {
@@ -228,3 +235,141 @@ func TestScheduler_ContextCancellation(t *testing.T) {
 		t.Fatal("Context not cancelled after Stop")
 	}
 }
+
+// blockingJob runs until it is released or its context is cancelled
+type blockingJob struct {
+	started   chan struct{}
+	release   chan struct{}
+	cancelled chan struct{}
+	once      sync.Once
+}
+
+func newBlockingJob() *blockingJob {
+	return &blockingJob{started: make(chan struct{}), release: make(chan struct{}), cancelled: make(chan struct{})}
+}
+
+func (j *blockingJob) Name() string     { return "blocking-job" }
+func (j *blockingJob) Schedule() string { return "@every 10ms" }
+
+func (j *blockingJob) Run(ctx context.Context) error {
+	j.once.Do(func() { close(j.started) })
+	select {
+	case <-j.release:
+		return nil
+	case <-ctx.Done():
+		close(j.cancelled)
+		return ctx.Err()
+	}
+}
+
+func TestScheduler_StopWaitsForRunningJobs(t *testing.T) {
+	scheduler := NewScheduler()
+	job := newBlockingJob()
+	scheduler.AddJob(job)
+	scheduler.Start()
+	<-job.started
+
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- scheduler.Stop(context.Background())
+	}()
+
+	// The run in progress is left to finish
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned before the job finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(job.release)
+	require.NoError(t, <-stopped)
+	select {
+	case <-job.cancelled:
+		t.Fatal("job was cancelled")
+	default:
+	}
+}
+
+func TestScheduler_StopCancelsJobsAtDeadline(t *testing.T) {
+	scheduler := NewScheduler()
+	job := newBlockingJob()
+	scheduler.AddJob(job)
+	scheduler.Start()
+	<-job.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, scheduler.Stop(ctx), context.DeadlineExceeded)
+
+	select {
+	case <-job.cancelled:
+	default:
+		t.Fatal("job was not cancelled")
+	}
+	assert.Empty(t, scheduler.runningJobs())
+}
+
+func TestParseSchedule_Invalid(t *testing.T) {
+	for _, schedule := range []string{"", "unknown", "@every", "@every soon", "@every 0s", "*/5 * * * *"} {
+		_, err := ParseSchedule(schedule)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, schedule)
+	}
+}
+
+func TestValidateSchedules(t *testing.T) {
+	assert.NoError(t, ValidateSchedules(nil))
+	assert.NoError(t, ValidateSchedules(map[string]string{"PriceUpdate": "@every 6h", "Cleanup": "@weekly"}))
+
+	err := ValidateSchedules(map[string]string{"PriceUpdates": "@daily", "Cleanup": "sometimes"})
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+	assert.Contains(t, err.Error(), "job PriceUpdates: invalid job schedule: no such job")
+	assert.Contains(t, err.Error(), "job Cleanup")
+}
+
+func TestScheduledJobNames(t *testing.T) {
+	// Every job the scheduler may run can have its schedule overridden
+	for _, job := range []Job{
+		NewCleanupJob(nil, 0),
+		NewCorporateActionDetectionJob(nil),
+		NewOptionExpirationJob(nil),
+		NewPeerBenchmarkJob(nil),
+		NewPriceUpdateJob(nil, nil),
+		NewRebalancePlanReminderJob(nil, nil, nil),
+		NewReportDigestJob(nil, nil, nil, nil),
+		NewSnapshotCompactionJob(nil, nil, models.DefaultSnapshotRetentionPolicy()),
+		NewSnapshotGenerationJob(nil, nil, nil, nil),
+	} {
+		assert.Contains(t, scheduledJobNames, job.Name())
+	}
+}
+
+func TestScheduler_SetSchedules(t *testing.T) {
+	scheduler := NewScheduler()
+	job := newMockJob("PriceUpdate", "@daily")
+	scheduler.AddJob(job)
+	scheduler.Start()
+	defer func() {
+		_ = scheduler.Stop(context.Background())
+	}()
+
+	// A running job picks up its new schedule
+	require.NoError(t, scheduler.SetSchedules(map[string]string{"PriceUpdate": "@every 10ms"}))
+	select {
+	case <-job.runCalled:
+	case <-time.After(time.Second):
+		t.Fatal("job did not run on its new schedule")
+	}
+
+	// Invalid overrides are rejected without changing the schedules
+	err := scheduler.SetSchedules(map[string]string{"PriceUpdate": "whenever"})
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+	scheduler.mu.RLock()
+	assert.Equal(t, "@every 10ms", scheduler.scheduleOf(job))
+	scheduler.mu.RUnlock()
+
+	// Leaving a job out restores its own schedule
+	require.NoError(t, scheduler.SetSchedules(nil))
+	scheduler.mu.RLock()
+	assert.Equal(t, "@daily", scheduler.scheduleOf(job))
+	scheduler.mu.RUnlock()
+}
@@ -0,0 +1,118 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// SnapshotCompactionJob is a background job that downsamples aging performance snapshots
+// according to a retention policy. Only snapshots older than the policy's daily cutoff are
+// considered, so recent history is never touched.
+type SnapshotCompactionJob struct {
+	portfolioRepo repository.PortfolioRepository
+	snapshotRepo  repository.PerformanceSnapshotRepository
+	policy        models.SnapshotRetentionPolicy
+	now           func() time.Time
+}
+
+// NewSnapshotCompactionJob creates a new snapshot compaction job
+func NewSnapshotCompactionJob(
+	portfolioRepo repository.PortfolioRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	policy models.SnapshotRetentionPolicy,
+) *SnapshotCompactionJob {
+	return &SnapshotCompactionJob{
+		portfolioRepo: portfolioRepo,
+		snapshotRepo:  snapshotRepo,
+		policy:        policy,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Name returns the job name
+func (j *SnapshotCompactionJob) Name() string {
+	return "SnapshotCompaction"
+}
+
+// Schedule returns the job schedule
+func (j *SnapshotCompactionJob) Schedule() string {
+	return "@weekly"
+}
+
+// Run executes the job
+func (j *SnapshotCompactionJob) Run(ctx context.Context) error {
+	if err := j.policy.Validate(); err != nil {
+		return err
+	}
+
+	portfolios, err := j.portfolioRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list portfolios: %w", err)
+	}
+
+	startTime := time.Now()
+	now := j.now()
+	// Read a month past the cutoff so the last compacted period can see the snapshot after it
+	readUntil := j.policy.DailyCutoff(now).AddDate(0, 1, 0)
+
+	var removed int64
+	for _, portfolio := range portfolios {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+
+		deleted, err := j.compactPortfolio(ctx, portfolio.ID.String(), now, readUntil)
+		removed += deleted
+		if err != nil {
+			log.Printf("Error compacting snapshots for portfolio %s: %v", portfolio.ID, err)
+		}
+	}
+
+	log.Printf("Snapshot compaction removed %d snapshots across %d portfolios in %v", removed, len(portfolios), time.Since(startTime))
+	return nil
+}
+
+// compactPortfolio removes the snapshots of a portfolio up to readUntil that the policy no
+// longer keeps. Snapshots are read in batches; the last two of each batch are carried over
+// to the next, so every snapshot is compared with the one after it just as if the whole
+// history had been read at once.
+func (j *SnapshotCompactionJob) compactPortfolio(ctx context.Context, portfolioID string, now, readUntil time.Time) (int64, error) {
+	var removed int64
+	var carried []*models.PerformanceSnapshot
+	err := j.snapshotRepo.IterateByPortfolioID(ctx, portfolioID, func(batch []*models.PerformanceSnapshot) error {
+		done := false
+		for i, snapshot := range batch {
+			if snapshot.Date.After(readUntil) {
+				batch, done = batch[:i], true
+				break
+			}
+		}
+
+		window := append(carried, batch...)
+		if compacted := j.policy.Compact(window, now); len(compacted) > 0 {
+			ids := make([]uuid.UUID, len(compacted))
+			for i, snapshot := range compacted {
+				ids[i] = snapshot.ID
+			}
+			deleted, err := j.snapshotRepo.DeleteByIDs(ctx, ids)
+			if err != nil {
+				return err
+			}
+			removed += deleted
+		}
+		carried = append([]*models.PerformanceSnapshot(nil), window[max(0, len(window)-2):]...)
+
+		if done {
+			return repository.ErrStopIteration
+		}
+		return nil
+	})
+	return removed, err
+}
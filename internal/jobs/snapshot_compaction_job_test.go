@@ -0,0 +1,140 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func newSnapshotCompactionJob(db *gorm.DB, policy models.SnapshotRetentionPolicy) *SnapshotCompactionJob {
+	return NewSnapshotCompactionJob(
+		repository.NewPortfolioRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+		policy,
+	)
+}
+
+func TestSnapshotCompactionJob_NameAndSchedule(t *testing.T) {
+	job := newSnapshotCompactionJob(setupTestDB(t), models.DefaultSnapshotRetentionPolicy())
+	assert.Equal(t, "SnapshotCompaction", job.Name())
+	assert.Equal(t, "@weekly", job.Schedule())
+}
+
+func TestSnapshotCompactionJob_Run(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTestDB(t)
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	// Two portfolios with a daily snapshot for every day of 2024 so far
+	var portfolios []*models.Portfolio
+	for _, name := range []string{"Growth", "Income"} {
+		portfolio := &models.Portfolio{
+			UserID:          user.ID,
+			Name:            name,
+			BaseCurrency:    "USD",
+			CostBasisMethod: models.CostBasisFIFO,
+		}
+		require.NoError(t, db.Create(portfolio).Error)
+		portfolios = append(portfolios, portfolio)
+
+		for date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC); !date.After(now); date = date.AddDate(0, 0, 1) {
+			require.NoError(t, db.Create(&models.PerformanceSnapshot{
+				PortfolioID:    portfolio.ID,
+				Date:           date,
+				TotalValue:     decimal.NewFromInt(1000),
+				TotalCostBasis: decimal.NewFromInt(1000),
+			}).Error)
+		}
+	}
+
+	job := newSnapshotCompactionJob(db, models.SnapshotRetentionPolicy{DailyMonths: 1, WeeklyMonths: 3})
+	job.now = func() time.Time { return now }
+
+	require.NoError(t, job.Run(context.Background()))
+
+	snapshotRepo := repository.NewPerformanceSnapshotRepository(db)
+	for _, portfolio := range portfolios {
+		// Daily history since the cutoff is untouched
+		recent, err := snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolio.ID.String(), time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC), now)
+		require.NoError(t, err)
+		assert.Len(t, recent, 32)
+
+		// Older history is thinned but keeps its month ends
+		older, err := snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolio.ID.String(), time.Time{}, time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Less(t, len(older), 30)
+		_, err = snapshotRepo.FindByPortfolioIDAndDate(ctx, portfolio.ID.String(), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))
+		assert.NoError(t, err)
+		_, err = snapshotRepo.FindByPortfolioIDAndDate(ctx, portfolio.ID.String(), time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, models.ErrPerformanceSnapshotNotFound, err)
+	}
+
+	// A second run has nothing left to remove
+	var before int64
+	require.NoError(t, db.Model(&models.PerformanceSnapshot{}).Count(&before).Error)
+	require.NoError(t, job.Run(context.Background()))
+	var after int64
+	require.NoError(t, db.Model(&models.PerformanceSnapshot{}).Count(&after).Error)
+	assert.Equal(t, before, after)
+}
+
+func TestSnapshotCompactionJob_RunAcrossBatches(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTestDB(t)
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	policy := models.SnapshotRetentionPolicy{DailyMonths: 1, WeeklyMonths: 12}
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Growth", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	// Three years of daily snapshots span several batches of the snapshot iterator
+	var snapshots []*models.PerformanceSnapshot
+	for date := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC); !date.After(now); date = date.AddDate(0, 0, 1) {
+		snapshots = append(snapshots, &models.PerformanceSnapshot{
+			PortfolioID:    portfolio.ID,
+			Date:           date,
+			TotalValue:     decimal.NewFromInt(1000),
+			TotalCostBasis: decimal.NewFromInt(1000),
+		})
+	}
+	require.NoError(t, db.CreateInBatches(snapshots, 200).Error)
+
+	// Compacting batch by batch removes exactly what compacting the whole history would
+	readUntil := policy.DailyCutoff(now).AddDate(0, 1, 0)
+	var history []*models.PerformanceSnapshot
+	for _, snapshot := range snapshots {
+		if !snapshot.Date.After(readUntil) {
+			history = append(history, snapshot)
+		}
+	}
+	want := len(snapshots) - len(policy.Compact(history, now))
+
+	job := newSnapshotCompactionJob(db, policy)
+	job.now = func() time.Time { return now }
+	require.NoError(t, job.Run(ctx))
+
+	var remaining int64
+	require.NoError(t, db.Model(&models.PerformanceSnapshot{}).Count(&remaining).Error)
+	assert.Equal(t, int64(want), remaining)
+	assert.Less(t, want, len(snapshots)/2)
+}
+
+func TestSnapshotCompactionJob_InvalidPolicy(t *testing.T) {
+	job := newSnapshotCompactionJob(setupTestDB(t), models.SnapshotRetentionPolicy{})
+	assert.Equal(t, models.ErrInvalidRetentionPolicy, job.Run(context.Background()))
+}
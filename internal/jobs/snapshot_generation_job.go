@@ -2,20 +2,51 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/calendar"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
 )
 
+// snapshotPriceLookbackDays is how far back the job looks for a closing price before the
+// last closed NYSE session, covering symbols that didn't trade that day. Coin pairs trade every day and are only valued at a current price. Option contracts have
+// no price history and are valued at their latest quote.
+const snapshotPriceLookbackDays = 7
+
 // SnapshotGenerationJob is a background job that generates daily performance snapshots
-// In a full implementation, this would:
-// - Query all active portfolios
-// - Generate daily performance snapshots for each
-// - Calculate daily returns and metrics
-type SnapshotGenerationJob struct{}
+// for every portfolio, valued at the last closed NYSE session. Portfolios without coin pairs
+// already snapshotted since that session closed are skipped, so weekends and market holidays
+// don't repeat the previous session's values. Historical prices are prefetched once per symbol for the whole
+// run and shared across portfolios, so provider calls scale with distinct symbols.
+type SnapshotGenerationJob struct {
+	portfolioRepo   repository.PortfolioRepository
+	holdingRepo     repository.HoldingRepository
+	snapshotService services.PerformanceSnapshotService
+	marketDataSvc   services.MarketDataService
+	now             func() time.Time
+}
 
 // NewSnapshotGenerationJob creates a new snapshot generation job
-func NewSnapshotGenerationJob() *SnapshotGenerationJob {
-	return &SnapshotGenerationJob{}
+func NewSnapshotGenerationJob(
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	snapshotService services.PerformanceSnapshotService,
+	marketDataSvc services.MarketDataService,
+) *SnapshotGenerationJob {
+	return &SnapshotGenerationJob{
+		portfolioRepo:   portfolioRepo,
+		holdingRepo:     holdingRepo,
+		snapshotService: snapshotService,
+		marketDataSvc:   marketDataSvc,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
 }
 
 // Name returns the job name
@@ -34,22 +65,127 @@ func (j *SnapshotGenerationJob) Run(ctx context.Context) error {
 	log.Println("Starting snapshot generation job...")
 	startTime := time.Now()
 
-	// Note: In a full implementation, this job would:
-	// 1. Query all active portfolios via PortfolioRepository.FindAll()
-	// 2. For each portfolio:
-	//    a. Get current holdings
-	//    b. Fetch current market prices
-	//    c. Calculate total value, returns, etc.
-	//    d. Create a performance snapshot via PerformanceSnapshotService
-	// 3. Handle errors gracefully, logging failed portfolios
-	//
-	// This requires:
-	// - Adding FindAll() method to PortfolioRepository
-	// - Potentially simplifying CreateSnapshot to auto-fetch prices
-	// - Or passing a userID context (perhaps a system user for batch jobs)
+	portfolios, err := j.portfolioRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list portfolios: %w", err)
+	}
+
+	// Collect each portfolio's symbols up front so prices can be fetched in one pass
+	symbolsByPortfolio := make(map[string][]string, len(portfolios))
+	var allSymbols []string
+	for _, portfolio := range portfolios {
+		holdings, err := j.holdingRepo.FindByPortfolioID(ctx, portfolio.ID.String())
+		if err != nil {
+			log.Printf("Error loading holdings for portfolio %s: %v", portfolio.ID, err)
+			continue
+		}
+		symbols := make([]string, 0, len(holdings))
+		for _, holding := range holdings {
+			symbols = append(symbols, holding.Symbol)
+		}
+		symbolsByPortfolio[portfolio.ID.String()] = symbols
+		allSymbols = append(allSymbols, symbols...)
+	}
+
+	historySymbols, optionSymbols := splitOptionSymbols(allSymbols)
+
+	now := j.now()
+	session := calendar.NYSE.LastClosedSession(now)
+	var prefetcher *services.HistoricalPricePrefetcher
+	optionQuotes := map[string]*services.Quote{}
+	if j.marketDataSvc != nil {
+		prefetcher = services.NewHistoricalPricePrefetcher(j.marketDataSvc, session.AddDate(0, 0, -snapshotPriceLookbackDays), session)
+		if err := prefetcher.Prefetch(ctx, historySymbols); err != nil {
+			// Portfolios missing prices fall back to cost basis in CreateSnapshot
+			log.Printf("Price prefetch stopped early: %v", err)
+		}
+		if len(optionSymbols) > 0 {
+			quotes, err := j.marketDataSvc.GetQuotes(ctx, optionSymbols)
+			if err != nil {
+				log.Printf("Error fetching option quotes: %v", err)
+			} else {
+				optionQuotes = quotes
+			}
+		}
+	}
+
+	created, skipped := 0, 0
+	for _, portfolio := range portfolios {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("context cancelled: %w", err)
+		}
+
+		symbols, ok := symbolsByPortfolio[portfolio.ID.String()]
+		if !ok {
+			continue
+		}
+
+		if j.snapshotTakenSince(ctx, portfolio, symbols, calendar.NYSE.SessionClose(session)) {
+			skipped++
+			continue
+		}
+
+		historySymbols, optionSymbols := splitOptionSymbols(symbols)
+		prices := map[string]decimal.Decimal{}
+		if prefetcher != nil {
+			prices = prefetcher.PricesOn(ctx, historySymbols, session)
+		}
+		for _, symbol := range optionSymbols {
+			if quote, ok := optionQuotes[symbol]; ok {
+				prices[symbol] = quote.Price
+			}
+		}
+
+		if _, err := j.snapshotService.CreateSnapshot(ctx, portfolio.ID.String(), portfolio.UserID.String(), prices); err != nil {
+			if errors.Is(err, models.ErrDuplicateSnapshot) {
+				// Already snapshotted today, e.g. by an earlier run
+				skipped++
+				continue
+			}
+			log.Printf("Error creating snapshot for portfolio %s: %v", portfolio.ID, err)
+			continue
+		}
+		created++
+	}
+
+	providerCalls := 0
+	if prefetcher != nil {
+		providerCalls = prefetcher.ProviderCalls()
+	}
 
 	duration := time.Since(startTime)
-	log.Printf("Snapshot generation completed in %v", duration)
+	log.Printf("Snapshot generation completed in %v: %d/%d portfolios (%d unchanged since the %s session), %d price requests",
+		duration, created, len(portfolios), skipped, session.Format("2006-01-02"), providerCalls)
 
 	return nil
 }
+
+// snapshotTakenSince returns true if a portfolio holding no coin pairs was already
+// snapshotted after sessionClose. Its value can't have moved since, while coin pairs trade
+// every day.
+func (j *SnapshotGenerationJob) snapshotTakenSince(ctx context.Context, portfolio *models.Portfolio, symbols []string, sessionClose time.Time) bool {
+	for _, symbol := range symbols {
+		if _, _, ok := models.CryptoPair(symbol); ok {
+			return false
+		}
+	}
+
+	latest, err := j.snapshotService.GetLatest(ctx, portfolio.ID.String(), portfolio.UserID.String())
+	if err != nil || latest == nil {
+		return false
+	}
+	return !latest.Date.Before(sessionClose)
+}
+
+// splitOptionSymbols separates option contracts, which are valued at their latest quote,
+// from the symbols valued from price history
+func splitOptionSymbols(symbols []string) (historySymbols, optionSymbols []string) {
+	for _, symbol := range symbols {
+		if models.IsOptionSymbol(symbol) {
+			optionSymbols = append(optionSymbols, symbol)
+		} else {
+			historySymbols = append(historySymbols, symbol)
+		}
+	}
+	return historySymbols, optionSymbols
+}
@@ -2,26 +2,230 @@ package jobs
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
 )
 
+// countingHistoryProvider serves fixed daily closes and counts history requests per symbol
+type countingHistoryProvider struct {
+	mu     sync.Mutex
+	closes map[string]decimal.Decimal
+	calls  map[string]int
+}
+
+func (p *countingHistoryProvider) GetQuote(ctx context.Context, symbol string) (*services.Quote, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *countingHistoryProvider) GetQuotes(ctx context.Context, symbols []string) (map[string]*services.Quote, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (p *countingHistoryProvider) GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*services.HistoricalPrice, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[symbol]++
+
+	closePrice, ok := p.closes[symbol]
+	if !ok {
+		return nil, errors.New("unknown symbol")
+	}
+	return []*services.HistoricalPrice{{Date: startDate, Close: closePrice}}, nil
+}
+
+func (p *countingHistoryProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error) {
+	return decimal.Zero, errors.New("not implemented")
+}
+
+func (p *countingHistoryProvider) IsAvailable() bool {
+	return true
+}
+
+// optionQuoteHistoryProvider adds option quotes to countingHistoryProvider
+type optionQuoteHistoryProvider struct {
+	*countingHistoryProvider
+	premiums map[string]decimal.Decimal
+}
+
+func (p *optionQuoteHistoryProvider) GetOptionQuote(ctx context.Context, symbol string) (*services.Quote, error) {
+	premium, ok := p.premiums[symbol]
+	if !ok {
+		return nil, errors.New("unknown option")
+	}
+	return &services.Quote{Symbol: symbol, Price: premium}, nil
+}
+
+func newSnapshotGenerationJob(db *gorm.DB, marketDataSvc services.MarketDataService) *SnapshotGenerationJob {
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	snapshotService := services.NewPerformanceSnapshotService(
+		repository.NewPerformanceSnapshotRepository(db),
+		portfolioRepo,
+		holdingRepo,
+	)
+	return NewSnapshotGenerationJob(portfolioRepo, holdingRepo, snapshotService, marketDataSvc)
+}
+
 func TestSnapshotGenerationJob_Name(t *testing.T) {
-	job := NewSnapshotGenerationJob()
+	job := newSnapshotGenerationJob(setupTestDB(t), nil)
 	assert.Equal(t, "SnapshotGeneration", job.Name())
 }
 
 func TestSnapshotGenerationJob_Schedule(t *testing.T) {
-	job := NewSnapshotGenerationJob()
+	job := newSnapshotGenerationJob(setupTestDB(t), nil)
 	assert.Equal(t, "@daily", job.Schedule())
 }
 
 func TestSnapshotGenerationJob_Run(t *testing.T) {
-	job := NewSnapshotGenerationJob()
-	ctx := context.Background()
+	db := setupTestDB(t)
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	// Ten portfolios all holding the same two symbols
+	for i := 0; i < 10; i++ {
+		portfolio := &models.Portfolio{
+			UserID:          user.ID,
+			Name:            "Portfolio " + string(rune('A'+i)),
+			BaseCurrency:    "USD",
+			CostBasisMethod: models.CostBasisFIFO,
+		}
+		require.NoError(t, db.Create(portfolio).Error)
+
+		for _, symbol := range []string{"AAPL", "MSFT"} {
+			require.NoError(t, db.Create(&models.Holding{
+				PortfolioID:  portfolio.ID,
+				Symbol:       symbol,
+				Quantity:     decimal.NewFromInt(10),
+				CostBasis:    decimal.NewFromInt(1000),
+				AvgCostPrice: decimal.NewFromInt(100),
+			}).Error)
+		}
+	}
+
+	provider := &countingHistoryProvider{
+		closes: map[string]decimal.Decimal{
+			"AAPL": decimal.NewFromInt(150),
+			"MSFT": decimal.NewFromInt(200),
+		},
+		calls: make(map[string]int),
+	}
+	job := newSnapshotGenerationJob(db, services.NewMarketDataService(provider, time.Minute))
+	job.now = func() time.Time { return time.Date(2024, 6, 15, 20, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, job.Run(context.Background()))
+
+	// One history request per symbol regardless of portfolio count
+	assert.Equal(t, map[string]int{"AAPL": 1, "MSFT": 1}, provider.calls)
+
+	var snapshots []models.PerformanceSnapshot
+	require.NoError(t, db.Find(&snapshots).Error)
+	require.Len(t, snapshots, 10)
+	for _, snapshot := range snapshots {
+		// 10 * 150 + 10 * 200
+		assert.True(t, decimal.NewFromInt(3500).Equal(snapshot.TotalValue))
+	}
+}
+
+func TestSnapshotGenerationJob_Run_ValuesOptionsAtQuotes(t *testing.T) {
+	db := setupTestDB(t)
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Options", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID:  portfolio.ID,
+		Symbol:       "AAPL",
+		Quantity:     decimal.NewFromInt(10),
+		CostBasis:    decimal.NewFromInt(1000),
+		AvgCostPrice: decimal.NewFromInt(100),
+	}).Error)
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID:  portfolio.ID,
+		Symbol:       "AAPL240621C00190000",
+		Quantity:     decimal.NewFromInt(2),
+		CostBasis:    decimal.NewFromInt(500),
+		AvgCostPrice: decimal.NewFromInt(250),
+		Multiplier:   100,
+	}).Error)
+
+	provider := &optionQuoteHistoryProvider{
+		countingHistoryProvider: &countingHistoryProvider{
+			closes: map[string]decimal.Decimal{"AAPL": decimal.NewFromInt(150)},
+			calls:  make(map[string]int),
+		},
+		premiums: map[string]decimal.Decimal{"AAPL240621C00190000": decimal.RequireFromString("3.5")},
+	}
+	job := newSnapshotGenerationJob(db, services.NewMarketDataService(provider, time.Minute))
+	job.now = func() time.Time { return time.Date(2024, 6, 15, 20, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, job.Run(context.Background()))
+
+	// Options have no price history to request
+	assert.Equal(t, map[string]int{"AAPL": 1}, provider.calls)
+
+	var snapshot models.PerformanceSnapshot
+	require.NoError(t, db.First(&snapshot).Error)
+	// 10 * 150 + 2 contracts * 100 shares * 3.50
+	assert.True(t, decimal.NewFromInt(2200).Equal(snapshot.TotalValue), snapshot.TotalValue.String())
+}
+
+func TestSnapshotGenerationJob_Run_WithoutMarketData(t *testing.T) {
+	db := setupTestDB(t)
+	job := newSnapshotGenerationJob(db, nil)
+
+	// No portfolios and no market data service should not error
+	assert.NoError(t, job.Run(context.Background()))
+}
+
+func TestSnapshotGenerationJob_Run_SkipsUntilNextSession(t *testing.T) {
+	db := setupTestDB(t)
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	stocks := &models.Portfolio{UserID: user.ID, Name: "Stocks", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(stocks).Error)
+	coins := &models.Portfolio{UserID: user.ID, Name: "Coins", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(coins).Error)
+
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID:  stocks.ID,
+		Symbol:       "AAPL",
+		Quantity:     decimal.NewFromInt(10),
+		CostBasis:    decimal.NewFromInt(1000),
+		AvgCostPrice: decimal.NewFromInt(100),
+	}).Error)
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID:  coins.ID,
+		Symbol:       "BTC-USD",
+		Quantity:     decimal.NewFromInt(1),
+		CostBasis:    decimal.NewFromInt(30000),
+		AvgCostPrice: decimal.NewFromInt(30000),
+	}).Error)
+
+	job := newSnapshotGenerationJob(db, nil)
+	// Saturday: the Friday session closed before either run
+	job.now = func() time.Time { return time.Date(2024, 6, 15, 20, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, job.Run(context.Background()))
+	require.NoError(t, job.Run(context.Background()))
 
-	// Should not error (it's currently a placeholder)
-	err := job.Run(ctx)
-	assert.NoError(t, err)
+	var count int64
+	require.NoError(t, db.Model(&models.PerformanceSnapshot{}).Where("portfolio_id = ?", stocks.ID).Count(&count).Error)
+	assert.Equal(t, int64(1), count, "no session closed between runs")
+	require.NoError(t, db.Model(&models.PerformanceSnapshot{}).Where("portfolio_id = ?", coins.ID).Count(&count).Error)
+	assert.Equal(t, int64(2), count, "coin pairs trade every day")
 }
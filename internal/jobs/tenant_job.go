@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lenon/portfolios/internal/database"
+)
+
+// SchemaLister lists the tenant schemas that hold organizations' portfolio data
+type SchemaLister func() ([]string, error)
+
+// tenantAwareJob is implemented by jobs that need to see every schema in a single run,
+// for example to share work between them
+type tenantAwareJob interface {
+	RunForSchemas(ctx context.Context, schemas []string) error
+}
+
+// TenantJob runs a job that works on portfolio data once for the public schema and once for
+// each tenant schema in multi-schema mode. A failure in one schema does not stop the others.
+type TenantJob struct {
+	job     Job
+	schemas SchemaLister
+}
+
+// NewTenantJob wraps job so that it runs against every schema listed by schemas
+func NewTenantJob(job Job, schemas SchemaLister) *TenantJob {
+	return &TenantJob{
+		job:     job,
+		schemas: schemas,
+	}
+}
+
+// Name returns the wrapped job's name
+func (j *TenantJob) Name() string {
+	return j.job.Name()
+}
+
+// Schedule returns the wrapped job's schedule
+func (j *TenantJob) Schedule() string {
+	return j.job.Schedule()
+}
+
+// Run executes the wrapped job for every schema
+func (j *TenantJob) Run(ctx context.Context) error {
+	schemas, err := j.schemas()
+	if err != nil {
+		return fmt.Errorf("failed to list tenant schemas: %w", err)
+	}
+
+	if job, ok := j.job.(tenantAwareJob); ok {
+		return job.RunForSchemas(ctx, schemas)
+	}
+
+	var errs []error
+	for _, schema := range append([]string{""}, schemas...) {
+		if err := ctx.Err(); err != nil {
+			return errors.Join(append(errs, fmt.Errorf("context cancelled: %w", err))...)
+		}
+		if err := j.job.Run(schemaContext(ctx, schema)); err != nil {
+			errs = append(errs, schemaError(schema, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// schemaContext returns ctx reading from schema, where "" is the public schema
+func schemaContext(ctx context.Context, schema string) context.Context {
+	if schema == "" {
+		return ctx
+	}
+	return database.WithSchema(ctx, schema)
+}
+
+// schemaError labels err with the schema it happened in
+func schemaError(schema string, err error) error {
+	if schema == "" {
+		schema = "public"
+	}
+	return fmt.Errorf("schema %s: %w", schema, err)
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/database"
+)
+
+// recordingJob records the schema of every run and fails in the schemas listed in failIn
+type recordingJob struct {
+	schemas []string
+	failIn  map[string]bool
+}
+
+func (j *recordingJob) Name() string     { return "Recording" }
+func (j *recordingJob) Schedule() string { return "@hourly" }
+
+func (j *recordingJob) Run(ctx context.Context) error {
+	schema := database.SchemaFromContext(ctx)
+	j.schemas = append(j.schemas, schema)
+	if j.failIn[schema] {
+		return errors.New("run failed")
+	}
+	return nil
+}
+
+// recordingTenantAwareJob records the schemas it was given in a single run
+type recordingTenantAwareJob struct {
+	recordingJob
+	given []string
+}
+
+func (j *recordingTenantAwareJob) RunForSchemas(ctx context.Context, schemas []string) error {
+	j.given = schemas
+	return nil
+}
+
+func TestTenantJob(t *testing.T) {
+	listSchemas := func() ([]string, error) {
+		return []string{"tenant_a", "tenant_b"}, nil
+	}
+
+	t.Run("runs for the public schema and every tenant", func(t *testing.T) {
+		inner := &recordingJob{}
+		job := NewTenantJob(inner, listSchemas)
+
+		assert.Equal(t, "Recording", job.Name())
+		assert.Equal(t, "@hourly", job.Schedule())
+		require.NoError(t, job.Run(context.Background()))
+		assert.Equal(t, []string{"", "tenant_a", "tenant_b"}, inner.schemas)
+	})
+
+	t.Run("a failing schema does not stop the others", func(t *testing.T) {
+		inner := &recordingJob{failIn: map[string]bool{"tenant_a": true}}
+		job := NewTenantJob(inner, listSchemas)
+
+		err := job.Run(context.Background())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "schema tenant_a")
+		assert.Equal(t, []string{"", "tenant_a", "tenant_b"}, inner.schemas)
+	})
+
+	t.Run("tenant aware jobs get every schema at once", func(t *testing.T) {
+		inner := &recordingTenantAwareJob{}
+		job := NewTenantJob(inner, listSchemas)
+
+		require.NoError(t, job.Run(context.Background()))
+		assert.Equal(t, []string{"tenant_a", "tenant_b"}, inner.given)
+		assert.Empty(t, inner.schemas)
+	})
+
+	t.Run("fails when schemas cannot be listed", func(t *testing.T) {
+		inner := &recordingJob{}
+		job := NewTenantJob(inner, func() ([]string, error) {
+			return nil, errors.New("database unavailable")
+		})
+
+		assert.Error(t, job.Run(context.Background()))
+		assert.Empty(t, inner.schemas)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		inner := &recordingJob{}
+		err := NewTenantJob(inner, listSchemas).Run(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, inner.schemas)
+	})
+}
@@ -0,0 +1,396 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+const (
+	// DefaultWorkerPollInterval is how often idle workers check the queue for jobs queued
+	// by other processes
+	DefaultWorkerPollInterval = time.Second
+
+	// progressSaveInterval is the least time between two progress writes of a job
+	progressSaveInterval = 500 * time.Millisecond
+
+	// heartbeatInterval is how often a running job's worker records that it's alive
+	heartbeatInterval = 30 * time.Second
+
+	// staleJobTimeout is how long a running job can go without a heartbeat before it is
+	// failed, as its worker must have stopped
+	staleJobTimeout = 5 * time.Minute
+
+	// finishedJobRetention is how long the status and result of a finished job are kept
+	finishedJobRetention = 7 * 24 * time.Hour
+
+	// maintenanceInterval is how often stale jobs are failed and old jobs deleted
+	maintenanceInterval = time.Minute
+)
+
+// WorkerPool runs queued jobs on a fixed number of worker goroutines. Workers claim jobs
+// from the database, so pools in several processes can share one queue. A job is run by
+// the handler registered for its type, in the tenant schema it was queued from. A job
+// whose handler stops at a checkpoint goes back in the queue to resume from there.
+type WorkerPool struct {
+	queue        repository.QueuedJobRepository
+	workers      int
+	wake         <-chan struct{}
+	pollInterval time.Duration
+	handlers     map[models.QueuedJobType]services.QueuedJobHandler
+	mu           sync.RWMutex
+
+	// stopping stops workers from claiming jobs; cancelling running cancels running jobs
+	stopping   context.Context
+	stop       context.CancelFunc
+	running    context.Context
+	cancelJobs context.CancelFunc
+	wg         sync.WaitGroup
+
+	// active are the jobs the workers are running, by ID
+	active   map[uuid.UUID]*models.QueuedJob
+	activeMu sync.Mutex
+}
+
+// NewWorkerPool creates a pool of workers that run jobs from queue. Idle workers poll the
+// queue every DefaultWorkerPollInterval and when wake receives, which may be nil.
+func NewWorkerPool(queue repository.QueuedJobRepository, workers int, wake <-chan struct{}) *WorkerPool {
+	stopping, stop := context.WithCancel(context.Background())
+	running, cancelJobs := context.WithCancel(context.Background())
+	return &WorkerPool{
+		queue:        queue,
+		workers:      workers,
+		wake:         wake,
+		pollInterval: DefaultWorkerPollInterval,
+		handlers:     make(map[models.QueuedJobType]services.QueuedJobHandler),
+		stopping:     stopping,
+		stop:         stop,
+		running:      running,
+		cancelJobs:   cancelJobs,
+		active:       make(map[uuid.UUID]*models.QueuedJob),
+	}
+}
+
+// Handle registers the handler that runs jobs of jobType. Jobs of types without a handler
+// are left in the queue for other pools.
+func (p *WorkerPool) Handle(jobType models.QueuedJobType, handler services.QueuedJobHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[jobType] = handler
+}
+
+// JobTypes returns the job types the pool has handlers for
+func (p *WorkerPool) JobTypes() []models.QueuedJobType {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	types := make([]models.QueuedJobType, 0, len(p.handlers))
+	for jobType := range p.handlers {
+		types = append(types, jobType)
+	}
+	return types
+}
+
+// Start starts the workers and the maintenance loop that fails jobs of stopped workers and
+// deletes old jobs
+func (p *WorkerPool) Start() {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.work()
+	}
+
+	p.wg.Add(1)
+	go p.maintain()
+
+	log.Printf("Worker pool started with %d workers", p.workers)
+}
+
+// Stop stops claiming jobs and waits for the running ones to finish. If ctx is done first,
+// the running jobs are logged and cancelled: jobs that stop at a checkpoint go back in the
+// queue and the others fail.
+func (p *WorkerPool) Stop(ctx context.Context) error {
+	log.Println("Stopping worker pool...")
+	p.stop()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Println("Worker pool stopped")
+		return nil
+	case <-ctx.Done():
+		for _, job := range p.activeJobs() {
+			log.Printf("Interrupting queued job %s (%s) after %v", job.ID, job.Type, time.Since(*job.StartedAt).Round(time.Millisecond))
+		}
+		p.cancelJobs()
+		<-done
+		log.Println("Worker pool stopped with running jobs cancelled")
+		return ctx.Err()
+	}
+}
+
+// activeJobs returns the jobs being run, the longest running first
+func (p *WorkerPool) activeJobs() []*models.QueuedJob {
+	p.activeMu.Lock()
+	defer p.activeMu.Unlock()
+
+	jobs := make([]*models.QueuedJob, 0, len(p.active))
+	for _, job := range p.active {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].StartedAt.Before(*jobs[k].StartedAt) })
+	return jobs
+}
+
+// track records that job is running until the returned function is called
+func (p *WorkerPool) track(job *models.QueuedJob) func() {
+	if job.StartedAt == nil {
+		now := time.Now().UTC()
+		job.StartedAt = &now
+	}
+
+	p.activeMu.Lock()
+	p.active[job.ID] = job
+	p.activeMu.Unlock()
+
+	return func() {
+		p.activeMu.Lock()
+		delete(p.active, job.ID)
+		p.activeMu.Unlock()
+	}
+}
+
+// work runs jobs until the pool stops, waiting for new ones when the queue is empty
+func (p *WorkerPool) work() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for p.stopping.Err() == nil {
+			if !p.runNext() {
+				break
+			}
+		}
+
+		select {
+		case <-p.stopping.Done():
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs the oldest queued job, returning false if there was none
+func (p *WorkerPool) runNext() bool {
+	job, err := p.queue.ClaimNext(p.stopping, p.JobTypes())
+	if err != nil {
+		if p.stopping.Err() == nil {
+			log.Printf("Error claiming queued job: %v", err)
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	p.run(job)
+	return true
+}
+
+// run runs a claimed job and records its outcome
+func (p *WorkerPool) run(job *models.QueuedJob) {
+	startTime := time.Now()
+	log.Printf("Running queued job %s (%s)", job.ID, job.Type)
+	defer p.track(job)()
+
+	ctx := p.running
+	if job.TenantSchema != "" {
+		ctx = database.WithSchema(ctx, job.TenantSchema)
+	}
+	if job.OrganizationID != nil {
+		ctx = models.WithOrganizationScope(ctx, models.OrganizationScope{OrganizationID: *job.OrganizationID})
+	}
+	if job.DelegationID != nil && job.PortfolioID != nil {
+		// Access to the portfolio was checked when the advisor requested the job
+		ctx = models.WithDelegationScope(ctx, models.DelegationScope{
+			DelegationID: *job.DelegationID,
+			AdvisorID:    job.UserID,
+			PortfolioIDs: []uuid.UUID{*job.PortfolioID},
+		})
+	}
+	// Bookkeeping writes still go through after the job is cancelled
+	queueCtx := context.WithoutCancel(ctx)
+
+	progress := &progressWriter{ctx: queueCtx, queue: p.queue, job: job}
+	heartbeatDone := make(chan struct{})
+	defer close(heartbeatDone)
+	go p.heartbeat(queueCtx, job, heartbeatDone)
+
+	result, err := p.handle(ctx, job, progress.report)
+	progress.flush()
+
+	if errors.Is(err, models.ErrJobCheckpointed) {
+		if err := p.queue.Requeue(queueCtx, job.ID); err != nil {
+			log.Printf("Error requeueing queued job %s: %v", job.ID, err)
+			return
+		}
+		log.Printf("Queued job %s (%s) stopped at a checkpoint and was requeued: %v", job.ID, job.Type, err)
+		return
+	}
+
+	status, resultJSON, errMessage := models.QueuedJobStatusSucceeded, "", ""
+	if err == nil {
+		resultJSON, err = encodeJobResult(result)
+	}
+	if err != nil {
+		status, errMessage = models.QueuedJobStatusFailed, err.Error()
+	}
+
+	if err := p.queue.Finish(queueCtx, job.ID, status, resultJSON, errMessage); err != nil {
+		log.Printf("Error recording outcome of queued job %s: %v", job.ID, err)
+		return
+	}
+
+	if errMessage != "" {
+		log.Printf("Queued job %s (%s) failed: %s", job.ID, job.Type, errMessage)
+	} else {
+		log.Printf("Queued job %s (%s) completed successfully in %v", job.ID, job.Type, time.Since(startTime))
+	}
+}
+
+// handle calls the job's handler. A panic fails the job instead of stopping the worker.
+func (p *WorkerPool) handle(ctx context.Context, job *models.QueuedJob, report func(any)) (result any, err error) {
+	p.mu.RLock()
+	handler, ok := p.handlers[job.Type]
+	p.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no handler for %s jobs", job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Panic recovered in queued job %s: %v", job.ID, r)
+			result, err = nil, fmt.Errorf("job stopped by an internal error")
+		}
+	}()
+
+	return handler(ctx, job, report)
+}
+
+// heartbeat records that the job is alive until done is closed
+func (p *WorkerPool) heartbeat(ctx context.Context, job *models.QueuedJob, done <-chan struct{}) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := p.queue.Heartbeat(ctx, job.ID); err != nil {
+				log.Printf("Error recording heartbeat of queued job %s: %v", job.ID, err)
+			}
+		}
+	}
+}
+
+// maintain periodically fails the running jobs of workers that stopped sending heartbeats
+// and deletes jobs that finished more than finishedJobRetention ago
+func (p *WorkerPool) maintain() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(maintenanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopping.Done():
+			return
+		case <-ticker.C:
+			p.runMaintenance(p.stopping)
+		}
+	}
+}
+
+// runMaintenance fails stale jobs and deletes old ones
+func (p *WorkerPool) runMaintenance(ctx context.Context) {
+	now := time.Now().UTC()
+
+	failed, err := p.queue.FailStale(ctx, now.Add(-staleJobTimeout), "job stopped responding and was abandoned")
+	if err != nil {
+		log.Printf("Error failing stale queued jobs: %v", err)
+	} else if failed > 0 {
+		log.Printf("Failed %d stale queued jobs", failed)
+	}
+
+	if _, err := p.queue.DeleteFinishedBefore(ctx, now.Add(-finishedJobRetention)); err != nil {
+		log.Printf("Error deleting finished queued jobs: %v", err)
+	}
+}
+
+// progressWriter stores the progress a job reports, at most every progressSaveInterval.
+// Reports in between are kept and written by the next save or by flush.
+type progressWriter struct {
+	ctx     context.Context
+	queue   repository.QueuedJobRepository
+	job     *models.QueuedJob
+	pending any
+	dirty   bool
+	savedAt time.Time
+}
+
+// report records the job's latest progress, writing it if the last write was long enough ago
+func (w *progressWriter) report(progress any) {
+	w.pending, w.dirty = progress, true
+	if time.Since(w.savedAt) >= progressSaveInterval {
+		w.flush()
+	}
+}
+
+// flush writes the latest progress if it hasn't been written yet
+func (w *progressWriter) flush() {
+	if !w.dirty {
+		return
+	}
+	w.dirty, w.savedAt = false, time.Now()
+
+	data, err := json.Marshal(w.pending)
+	if err != nil {
+		log.Printf("Error encoding progress of queued job %s: %v", w.job.ID, err)
+		return
+	}
+	if err := w.queue.UpdateProgress(w.ctx, w.job.ID, string(data)); err != nil {
+		log.Printf("Error saving progress of queued job %s: %v", w.job.ID, err)
+	}
+}
+
+// encodeJobResult encodes a job's result as JSON
+func encodeJobResult(result any) (string, error) {
+	if result == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job result: %w", err)
+	}
+	return string(data), nil
+}
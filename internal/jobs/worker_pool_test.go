@@ -0,0 +1,238 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupWorkerPoolTest(t *testing.T) (repository.QueuedJobRepository, *models.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// The workers and the test share the in-memory database
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.QueuedJob{}))
+
+	user := &models.User{Email: "worker@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	return repository.NewQueuedJobRepository(db), user
+}
+
+func startWorkerPool(t *testing.T, pool *WorkerPool) {
+	pool.pollInterval = 10 * time.Millisecond
+	pool.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = pool.Stop(ctx)
+	})
+}
+
+// enqueue adds a job to the queue
+func enqueue(t *testing.T, queue repository.QueuedJobRepository, job *models.QueuedJob) *models.QueuedJob {
+	require.NoError(t, queue.Create(context.Background(), job))
+	return job
+}
+
+// waitForJob waits until a job has finished and returns it
+func waitForJob(t *testing.T, queue repository.QueuedJobRepository, job *models.QueuedJob) *models.QueuedJob {
+	var found *models.QueuedJob
+	require.Eventually(t, func() bool {
+		var err error
+		found, err = queue.FindByID(context.Background(), job.ID.String())
+		return err == nil && found.Status.IsFinished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return found
+}
+
+func TestWorkerPool_RunsJobs(t *testing.T) {
+	queue, user := setupWorkerPoolTest(t)
+
+	pool := NewWorkerPool(queue, 2, nil)
+	pool.Handle(models.QueuedJobTypeRecalculation, func(ctx context.Context, job *models.QueuedJob, report func(any)) (any, error) {
+		var payload struct{ Count int }
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, err
+		}
+		for i := 1; i <= payload.Count; i++ {
+			report(map[string]int{"done": i})
+		}
+		return map[string]any{"count": payload.Count, "schema": database.SchemaFromContext(ctx)}, nil
+	})
+	pool.Handle(models.QueuedJobTypeTaxReport, func(context.Context, *models.QueuedJob, func(any)) (any, error) {
+		return nil, errors.New("no lots to report")
+	})
+	pool.Handle(models.QueuedJobTypeTrackerImport, func(context.Context, *models.QueuedJob, func(any)) (any, error) {
+		panic("unexpected export")
+	})
+	startWorkerPool(t, pool)
+
+	t.Run("results and the last progress are stored", func(t *testing.T) {
+		job := &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeRecalculation}
+		require.NoError(t, job.SetPayload(map[string]int{"Count": 3}))
+		job = waitForJob(t, queue, enqueue(t, queue, job))
+
+		assert.Equal(t, models.QueuedJobStatusSucceeded, job.Status)
+		assert.JSONEq(t, `{"done":3}`, job.Progress)
+		assert.JSONEq(t, `{"count":3,"schema":""}`, job.Result)
+		assert.Empty(t, job.Error)
+		assert.NotNil(t, job.StartedAt)
+		assert.NotNil(t, job.FinishedAt)
+	})
+
+	t.Run("jobs run in the schema they were queued from", func(t *testing.T) {
+		job := &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeRecalculation, TenantSchema: "org_acme"}
+		job = waitForJob(t, queue, enqueue(t, queue, job))
+		assert.JSONEq(t, `{"count":0,"schema":"org_acme"}`, job.Result)
+	})
+
+	t.Run("errors and panics fail the job", func(t *testing.T) {
+		job := waitForJob(t, queue, enqueue(t, queue, &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeTaxReport}))
+		assert.Equal(t, models.QueuedJobStatusFailed, job.Status)
+		assert.Equal(t, "no lots to report", job.Error)
+		assert.Empty(t, job.Result)
+
+		job = waitForJob(t, queue, enqueue(t, queue, &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeTrackerImport}))
+		assert.Equal(t, models.QueuedJobStatusFailed, job.Status)
+		assert.Equal(t, "job stopped by an internal error", job.Error)
+	})
+
+	t.Run("jobs without a handler stay queued", func(t *testing.T) {
+		job := enqueue(t, queue, &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeCSVImport})
+		time.Sleep(50 * time.Millisecond)
+
+		found, err := queue.FindByID(context.Background(), job.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, models.QueuedJobStatusQueued, found.Status)
+	})
+}
+
+func TestWorkerPool_Wake(t *testing.T) {
+	queue, user := setupWorkerPoolTest(t)
+
+	wake := make(chan struct{}, 1)
+	pool := NewWorkerPool(queue, 1, wake)
+	pool.Handle(models.QueuedJobTypeRecalculation, func(context.Context, *models.QueuedJob, func(any)) (any, error) {
+		return "done", nil
+	})
+	pool.Start()
+	t.Cleanup(func() { _ = pool.Stop(context.Background()) })
+
+	// The poll interval is a second; waking the pool runs the job well before that
+	job := enqueue(t, queue, &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeRecalculation})
+	wake <- struct{}{}
+	require.Eventually(t, func() bool {
+		found, err := queue.FindByID(context.Background(), job.ID.String())
+		return err == nil && found.Status == models.QueuedJobStatusSucceeded
+	}, 500*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestWorkerPool_Stop(t *testing.T) {
+	queue, user := setupWorkerPoolTest(t)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	pool := NewWorkerPool(queue, 1, nil)
+	pool.Handle(models.QueuedJobTypeRecalculation, func(ctx context.Context, _ *models.QueuedJob, _ func(any)) (any, error) {
+		close(started)
+		select {
+		case <-release:
+			return "finished", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+	pool.pollInterval = 10 * time.Millisecond
+	pool.Start()
+
+	job := enqueue(t, queue, &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeRecalculation})
+	<-started
+
+	// A job that outlives the shutdown deadline is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Stop(ctx), context.DeadlineExceeded)
+
+	found, err := queue.FindByID(context.Background(), job.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobStatusFailed, found.Status)
+	assert.Equal(t, context.Canceled.Error(), found.Error)
+}
+
+func TestWorkerPool_StopRequeuesCheckpointedJobs(t *testing.T) {
+	queue, user := setupWorkerPoolTest(t)
+
+	started := make(chan struct{}, 1)
+	var resumedFrom string
+	handler := func(ctx context.Context, job *models.QueuedJob, report func(any)) (any, error) {
+		if job.Progress != "" {
+			resumedFrom = job.Progress
+			return "finished", nil
+		}
+		report(map[string]int{"done": 1})
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, fmt.Errorf("%w: %w", models.ErrJobCheckpointed, ctx.Err())
+	}
+
+	pool := NewWorkerPool(queue, 1, nil)
+	pool.Handle(models.QueuedJobTypeCSVImport, handler)
+	pool.pollInterval = 10 * time.Millisecond
+	pool.Start()
+
+	job := enqueue(t, queue, &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeCSVImport})
+	<-started
+
+	// The interrupted job goes back in the queue with its checkpoint
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, pool.Stop(ctx), context.DeadlineExceeded)
+	assert.Empty(t, pool.activeJobs())
+
+	found, err := queue.FindByID(context.Background(), job.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobStatusQueued, found.Status)
+	assert.Equal(t, `{"done":1}`, found.Progress)
+
+	// Another pool resumes it
+	resumed := NewWorkerPool(queue, 1, nil)
+	resumed.Handle(models.QueuedJobTypeCSVImport, handler)
+	startWorkerPool(t, resumed)
+
+	found = waitForJob(t, queue, job)
+	assert.Equal(t, models.QueuedJobStatusSucceeded, found.Status)
+	assert.Equal(t, `{"done":1}`, resumedFrom)
+}
+
+func TestWorkerPool_Maintenance(t *testing.T) {
+	queue, user := setupWorkerPoolTest(t)
+	ctx := context.Background()
+
+	job := enqueue(t, queue, &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeRecalculation})
+	claimed, err := queue.ClaimNext(ctx, []models.QueuedJobType{models.QueuedJobTypeRecalculation})
+	require.NoError(t, err)
+	require.Equal(t, job.ID, claimed.ID)
+
+	pool := NewWorkerPool(queue, 1, nil)
+
+	// A job whose worker is still sending heartbeats is left running, and unfinished jobs
+	// are never deleted
+	pool.runMaintenance(ctx)
+	found, err := queue.FindByID(ctx, job.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobStatusRunning, found.Status)
+}
@@ -0,0 +1,238 @@
+package loadtest
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/cli"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services"
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+func testSeedOptions() SeedOptions {
+	return SeedOptions{
+		Name:         "Load Test",
+		Transactions: 600,
+		Years:        1,
+		Symbols:      5,
+		EndDate:      time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+		Seed:         7,
+	}
+}
+
+func openTestDatabase(t testing.TB) (*gorm.DB, *models.User) {
+	db, err := database.ConnectWithLogger("sqlite://:memory:", gormlogger.Discard)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	return db, user
+}
+
+func TestSeed(t *testing.T) {
+	db, user := openTestDatabase(t)
+	opts := testSeedOptions()
+
+	result, err := Seed(context.Background(), db, user.ID, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 600, result.Transactions)
+	// One snapshot per weekday of the year up to the end date
+	assert.Equal(t, 261, result.Snapshots)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), result.StartDate)
+	assert.Equal(t, opts.EndDate, result.EndDate)
+
+	var transactions []*models.Transaction
+	require.NoError(t, db.Where("portfolio_id = ?", result.Portfolio.ID).Find(&transactions).Error)
+	require.Len(t, transactions, 600)
+
+	// The holdings are what the transactions leave
+	quantities := make(map[string]decimal.Decimal)
+	for _, transaction := range transactions {
+		require.NoError(t, transaction.Validate())
+		switch transaction.Type {
+		case models.TransactionTypeBuy:
+			quantities[transaction.Symbol] = quantities[transaction.Symbol].Add(transaction.Quantity)
+		case models.TransactionTypeSell:
+			quantities[transaction.Symbol] = quantities[transaction.Symbol].Sub(transaction.Quantity)
+		}
+	}
+	var holdings []*models.Holding
+	require.NoError(t, db.Where("portfolio_id = ?", result.Portfolio.ID).Find(&holdings).Error)
+	require.Len(t, holdings, result.Holdings)
+	for _, holding := range holdings {
+		assert.True(t, holding.Quantity.Equal(quantities[holding.Symbol]), holding.Symbol)
+		assert.True(t, holding.Quantity.IsPositive())
+	}
+
+	// The open lots add up to the holdings
+	var taxLots []*models.TaxLot
+	require.NoError(t, db.Where("portfolio_id = ?", result.Portfolio.ID).Find(&taxLots).Error)
+	require.Len(t, taxLots, result.TaxLots)
+	lotQuantities := make(map[string]decimal.Decimal)
+	for _, lot := range taxLots {
+		assert.True(t, lot.Quantity.IsPositive())
+		lotQuantities[lot.Symbol] = lotQuantities[lot.Symbol].Add(lot.Quantity)
+	}
+	for _, holding := range holdings {
+		assert.True(t, lotQuantities[holding.Symbol].Equal(holding.Quantity), holding.Symbol)
+	}
+
+	// The last snapshot values the holdings at the market's last close
+	market := opts.Market()
+	var latest models.PerformanceSnapshot
+	require.NoError(t, db.Where("portfolio_id = ?", result.Portfolio.ID).Order("date DESC").First(&latest).Error)
+	var value decimal.Decimal
+	for _, holding := range holdings {
+		value = value.Add(holding.Quantity.Mul(market.closes[market.symbolIndex(holding.Symbol)][len(market.Days)-1]))
+	}
+	assert.True(t, latest.TotalValue.Equal(value), latest.TotalValue.String())
+}
+
+func TestSeed_IsReproducible(t *testing.T) {
+	db, user := openTestDatabase(t)
+	opts := testSeedOptions()
+
+	first, err := Seed(context.Background(), db, user.ID, opts)
+	require.NoError(t, err)
+	opts.Name = "Load Test Again"
+	second, err := Seed(context.Background(), db, user.ID, opts)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Portfolio.ID, second.Portfolio.ID)
+
+	var firstSnapshot, secondSnapshot models.PerformanceSnapshot
+	require.NoError(t, db.Where("portfolio_id = ?", first.Portfolio.ID).Order("date DESC").First(&firstSnapshot).Error)
+	require.NoError(t, db.Where("portfolio_id = ?", second.Portfolio.ID).Order("date DESC").First(&secondSnapshot).Error)
+	assert.True(t, firstSnapshot.TotalValue.Equal(secondSnapshot.TotalValue))
+}
+
+func TestSeed_InvalidOptions(t *testing.T) {
+	db, user := openTestDatabase(t)
+	opts := testSeedOptions()
+	opts.Transactions = 0
+
+	_, err := Seed(context.Background(), db, user.ID, opts)
+	assert.ErrorContains(t, err, "transactions")
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	opts := testSeedOptions()
+
+	backend, err := cli.OpenLocalWithProvider(ctx, filepath.Join(t.TempDir(), "portfolios.db"), NewProvider(opts.Market()))
+	require.NoError(t, err)
+	defer backend.Close()
+
+	seeded, err := Seed(ctx, backend.DB(), backend.UserID(), opts)
+	require.NoError(t, err)
+
+	period := client.DateRange{Start: seeded.StartDate, End: seeded.EndDate}
+	results, err := Run(ctx, backend.Client(), seeded.Portfolio.ID.String(), period, Scenarios, RunOptions{Iterations: 3, Concurrency: 2})
+	require.NoError(t, err)
+	require.NoError(t, Failed(results))
+	require.Len(t, results, len(Scenarios))
+	for _, result := range results {
+		assert.Equal(t, 3, result.Requests, result.Scenario)
+		assert.Positive(t, result.P50, result.Scenario)
+		assert.LessOrEqual(t, result.Min, result.P95, result.Scenario)
+		assert.LessOrEqual(t, result.P95, result.Max, result.Scenario)
+	}
+
+	// Requests for a portfolio that doesn't exist fail
+	results, err = Run(ctx, backend.Client(), "00000000-0000-0000-0000-000000000000", period, Scenarios[:1], RunOptions{Iterations: 2, Concurrency: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, results[0].Errors)
+	assert.Error(t, Failed(results))
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration(i+1) * time.Millisecond
+	}
+
+	assert.Equal(t, 50*time.Millisecond, percentile(latencies, 50))
+	assert.Equal(t, 95*time.Millisecond, percentile(latencies, 95))
+	assert.Equal(t, 99*time.Millisecond, percentile(latencies, 99))
+	assert.Equal(t, time.Millisecond, percentile(latencies[:1], 99))
+}
+
+// benchmarkFixture is a portfolio of the default size, seeded once for every benchmark
+var benchmarkFixture struct {
+	once      sync.Once
+	db        *gorm.DB
+	user      *models.User
+	seeded    *SeedResult
+	analytics services.PerformanceAnalyticsService
+	dashboard services.DashboardService
+}
+
+func setupBenchmark(b *testing.B) {
+	f := &benchmarkFixture
+	f.once.Do(func() {
+		db, err := database.ConnectWithLogger("sqlite://:memory:", gormlogger.Discard)
+		require.NoError(b, err)
+		user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+		require.NoError(b, db.Create(user).Error)
+
+		opts := DefaultSeedOptions()
+		opts.EndDate = time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+		seeded, err := Seed(context.Background(), db, user.ID, opts)
+		require.NoError(b, err)
+
+		portfolioRepo := repository.NewPortfolioRepository(db)
+		marketData := services.NewMarketDataService(NewProvider(opts.Market()), time.Hour)
+		f.db, f.user, f.seeded = db, user, seeded
+		f.analytics = services.NewPerformanceAnalyticsService(
+			portfolioRepo, repository.NewTransactionRepository(db), repository.NewPerformanceSnapshotRepository(db), marketData,
+		)
+		f.dashboard = services.NewDashboardService(portfolioRepo, repository.NewHoldingRepository(db), marketData, nil, nil, nil)
+	})
+	b.ResetTimer()
+}
+
+func BenchmarkTWR(b *testing.B) {
+	setupBenchmark(b)
+	f := &benchmarkFixture
+	for i := 0; i < b.N; i++ {
+		if _, err := f.analytics.CalculateTWR(context.Background(), f.seeded.Portfolio.ID.String(), f.user.ID.String(), f.seeded.StartDate, f.seeded.EndDate); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMWR(b *testing.B) {
+	setupBenchmark(b)
+	f := &benchmarkFixture
+	for i := 0; i < b.N; i++ {
+		if _, err := f.analytics.CalculateMWR(context.Background(), f.seeded.Portfolio.ID.String(), f.user.ID.String(), f.seeded.StartDate, f.seeded.EndDate); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkValuation(b *testing.B) {
+	setupBenchmark(b)
+	f := &benchmarkFixture
+	for i := 0; i < b.N; i++ {
+		if _, err := f.dashboard.GetDashboard(context.Background(), f.user.ID.String(), "USD"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// Provider is a market data provider quoting a synthetic Market, so that valuations and
+// analytics can be measured without spending a real provider's requests. Its latest quote
+// is the market's last close.
+type Provider struct {
+	market *Market
+}
+
+// NewProvider creates a new Provider quoting market
+func NewProvider(market *Market) *Provider {
+	return &Provider{market: market}
+}
+
+// GetQuote returns the last close of symbol
+func (p *Provider) GetQuote(ctx context.Context, symbol string) (*services.Quote, error) {
+	k := p.market.symbolIndex(symbol)
+	if k < 0 {
+		return nil, fmt.Errorf("no synthetic prices for %s", symbol)
+	}
+
+	last := len(p.market.Days) - 1
+	quote := &services.Quote{
+		Symbol:           symbol,
+		Price:            p.market.closes[k][last],
+		Open:             p.market.closes[k][last],
+		High:             p.market.closes[k][last],
+		Low:              p.market.closes[k][last],
+		PreviousClose:    p.market.closes[k][last],
+		LastUpdated:      p.market.Days[last],
+		LatestTradingDay: p.market.Days[last],
+	}
+	if last > 0 {
+		quote.PreviousClose = p.market.closes[k][last-1]
+		quote.Change = quote.Price.Sub(quote.PreviousClose)
+		quote.ChangePercent = quote.Change.Div(quote.PreviousClose).Mul(decimal.NewFromInt(100))
+	}
+	return quote, nil
+}
+
+// GetQuotes returns the last closes of the symbols the market trades
+func (p *Provider) GetQuotes(ctx context.Context, symbols []string) (map[string]*services.Quote, error) {
+	quotes := make(map[string]*services.Quote, len(symbols))
+	for _, symbol := range symbols {
+		if quote, err := p.GetQuote(ctx, symbol); err == nil {
+			quotes[symbol] = quote
+		}
+	}
+	return quotes, nil
+}
+
+// GetHistoricalPrices returns the closes of symbol between startDate and endDate
+func (p *Provider) GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*services.HistoricalPrice, error) {
+	k := p.market.symbolIndex(symbol)
+	if k < 0 {
+		return nil, fmt.Errorf("no synthetic prices for %s", symbol)
+	}
+
+	var prices []*services.HistoricalPrice
+	for day, date := range p.market.Days {
+		if date.Before(startDate) || date.After(endDate) {
+			continue
+		}
+		price := p.market.closes[k][day]
+		prices = append(prices, &services.HistoricalPrice{
+			Date:     date,
+			Open:     price,
+			High:     price,
+			Low:      price,
+			Close:    price,
+			AdjClose: &price,
+		})
+	}
+	return prices, nil
+}
+
+// GetExchangeRate returns 1; the synthetic market trades in a single currency
+func (p *Provider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error) {
+	return decimal.NewFromInt(1), nil
+}
+
+// IsAvailable returns true
+func (p *Provider) IsAvailable() bool {
+	return true
+}
@@ -0,0 +1,190 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+const (
+	// DefaultIterations is how many requests of each scenario a run makes
+	DefaultIterations = 20
+	// DefaultConcurrency is how many requests a run keeps in flight at once
+	DefaultConcurrency = 4
+)
+
+// Scenario is an analytics request a run measures
+type Scenario struct {
+	Name string
+	Call func(ctx context.Context, c *client.Client, portfolioID string, period client.DateRange) error
+}
+
+// Scenarios are the requests a run measures: the time- and money-weighted returns and the
+// metrics combining them over the whole history, and the valuation of the user's holdings
+// on the dashboard
+var Scenarios = []Scenario{
+	{Name: "twr", Call: func(ctx context.Context, c *client.Client, portfolioID string, period client.DateRange) error {
+		_, err := c.GetTWR(ctx, portfolioID, period)
+		return err
+	}},
+	{Name: "mwr", Call: func(ctx context.Context, c *client.Client, portfolioID string, period client.DateRange) error {
+		_, err := c.GetMWR(ctx, portfolioID, period)
+		return err
+	}},
+	{Name: "metrics", Call: func(ctx context.Context, c *client.Client, portfolioID string, period client.DateRange) error {
+		_, err := c.GetPerformanceMetrics(ctx, portfolioID, period)
+		return err
+	}},
+	{Name: "valuation", Call: func(ctx context.Context, c *client.Client, portfolioID string, period client.DateRange) error {
+		_, err := c.GetDashboard(ctx, "")
+		return err
+	}},
+}
+
+// RunOptions controls how hard a run loads the API
+type RunOptions struct {
+	// Iterations is how many requests of each scenario are made
+	Iterations int
+	// Concurrency is how many requests of a scenario are in flight at once
+	Concurrency int
+}
+
+// Result is the latency of a scenario's requests. Failed requests count towards Errors
+// but not the latencies.
+type Result struct {
+	Scenario   string        `json:"scenario"`
+	Requests   int           `json:"requests"`
+	Errors     int           `json:"errors"`
+	Min        time.Duration `json:"min_ns"`
+	Mean       time.Duration `json:"mean_ns"`
+	P50        time.Duration `json:"p50_ns"`
+	P95        time.Duration `json:"p95_ns"`
+	P99        time.Duration `json:"p99_ns"`
+	Max        time.Duration `json:"max_ns"`
+	FirstError string        `json:"first_error,omitempty"`
+}
+
+// Run measures each of scenarios on the portfolio over period, one scenario after another so
+// their latencies don't mix. It stops early only when ctx is done.
+func Run(
+	ctx context.Context,
+	c *client.Client,
+	portfolioID string,
+	period client.DateRange,
+	scenarios []Scenario,
+	opts RunOptions,
+) ([]*Result, error) {
+	if opts.Iterations < 1 {
+		return nil, fmt.Errorf("iterations must be at least 1, got %d", opts.Iterations)
+	}
+	if opts.Concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1, got %d", opts.Concurrency)
+	}
+
+	results := make([]*Result, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		result := measure(ctx, c, portfolioID, period, scenario, opts)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// measure makes a scenario's requests, opts.Concurrency at a time
+func measure(
+	ctx context.Context,
+	c *client.Client,
+	portfolioID string,
+	period client.DateRange,
+	scenario Scenario,
+	opts RunOptions,
+) *Result {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var latencies []time.Duration
+	var errs []error
+
+	requests := make(chan struct{})
+	for range min(opts.Concurrency, opts.Iterations) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range requests {
+				start := time.Now()
+				err := scenario.Call(ctx, c, portfolioID, period)
+				elapsed := time.Since(start)
+
+				mu.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					latencies = append(latencies, elapsed)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for range opts.Iterations {
+		if ctx.Err() != nil {
+			break
+		}
+		requests <- struct{}{}
+	}
+	close(requests)
+	wg.Wait()
+
+	result := summarize(scenario.Name, latencies)
+	result.Requests += len(errs)
+	result.Errors = len(errs)
+	if len(errs) > 0 {
+		result.FirstError = errs[0].Error()
+	}
+	return result
+}
+
+// summarize returns the latency percentiles of a scenario's successful requests
+func summarize(name string, latencies []time.Duration) *Result {
+	result := &Result{Scenario: name, Requests: len(latencies)}
+	if len(latencies) == 0 {
+		return result
+	}
+
+	slices.Sort(latencies)
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	result.Min = latencies[0]
+	result.Max = latencies[len(latencies)-1]
+	result.Mean = total / time.Duration(len(latencies))
+	result.P50 = percentile(latencies, 50)
+	result.P95 = percentile(latencies, 95)
+	result.P99 = percentile(latencies, 99)
+	return result
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// Failed returns an error naming the scenarios with failed requests, or nil if every
+// request succeeded
+func Failed(results []*Result) error {
+	var errs []error
+	for _, result := range results {
+		if result.Errors > 0 {
+			errs = append(errs, fmt.Errorf("%s: %d of %d requests failed: %s",
+				result.Scenario, result.Errors, result.Requests, result.FirstError))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,359 @@
+// Package loadtest seeds synthetic portfolios and measures how long the analytics endpoints
+// take on them, so that performance regressions are caught before they reach users.
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+const (
+	// DefaultTransactions is how many transactions a seeded portfolio has
+	DefaultTransactions = 50000
+	// DefaultYears is how many years of history a seeded portfolio has
+	DefaultYears = 10
+	// DefaultSymbols is how many symbols a seeded portfolio trades
+	DefaultSymbols = 20
+
+	// seedBatchSize is how many rows each insert writes
+	seedBatchSize = 500
+)
+
+// SeedOptions describes the synthetic portfolio Seed creates. The same options always create
+// the same transactions and snapshots, priced by the same Market.
+type SeedOptions struct {
+	Name         string
+	Transactions int
+	Years        int
+	Symbols      int
+	// SymbolNames names the symbols, which are LOAD00, LOAD01 and so on when empty. It
+	// needs at least Symbols names.
+	SymbolNames []string
+	// EndDate is the last day of history, today when zero
+	EndDate time.Time
+	// Seed picks the random prices and trades
+	Seed uint64
+}
+
+// DefaultSeedOptions returns the options of a portfolio large enough to show how the
+// analytics scale: DefaultTransactions transactions over DefaultYears years
+func DefaultSeedOptions() SeedOptions {
+	return SeedOptions{
+		Name:         "Load Test",
+		Transactions: DefaultTransactions,
+		Years:        DefaultYears,
+		Symbols:      DefaultSymbols,
+		Seed:         1,
+	}
+}
+
+// Validate checks the options describe a portfolio that can be seeded
+func (o SeedOptions) Validate() error {
+	if o.Transactions < 1 {
+		return fmt.Errorf("transactions must be at least 1, got %d", o.Transactions)
+	}
+	if o.Years < 1 {
+		return fmt.Errorf("years must be at least 1, got %d", o.Years)
+	}
+	if o.Symbols < 1 {
+		return fmt.Errorf("symbols must be at least 1, got %d", o.Symbols)
+	}
+	if len(o.SymbolNames) > 0 && len(o.SymbolNames) < o.Symbols {
+		return fmt.Errorf("%d symbols need as many names, got %d", o.Symbols, len(o.SymbolNames))
+	}
+	return nil
+}
+
+// Market returns the synthetic market the options' portfolio trades in
+func (o SeedOptions) Market() *Market {
+	endDate := o.EndDate
+	if endDate.IsZero() {
+		endDate = time.Now().UTC()
+	}
+	market := NewMarket(o.Symbols, o.Years, endDate, o.Seed)
+	if len(o.SymbolNames) > 0 {
+		copy(market.Symbols, o.SymbolNames)
+	}
+	return market
+}
+
+// SeedResult describes a seeded portfolio
+type SeedResult struct {
+	Portfolio    *models.Portfolio `json:"portfolio"`
+	Transactions int               `json:"transactions"`
+	Snapshots    int               `json:"snapshots"`
+	Holdings     int               `json:"holdings"`
+	TaxLots      int               `json:"tax_lots"`
+	StartDate    time.Time         `json:"start_date"`
+	EndDate      time.Time         `json:"end_date"`
+}
+
+// Seed creates a portfolio for userID with the options' transactions spread over their
+// trading days, a performance snapshot for every trading day, and the holdings and tax lots
+// the transactions leave. The symbols are bought, sold and pay dividends at the Market's
+// prices; sales close the oldest lots first. Everything is written in one database
+// transaction, so a failed seed leaves nothing behind.
+func Seed(ctx context.Context, db *gorm.DB, userID uuid.UUID, opts SeedOptions) (*SeedResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	market := opts.Market()
+	portfolio := &models.Portfolio{
+		UserID:          userID,
+		Name:            opts.Name,
+		Description:     fmt.Sprintf("Synthetic portfolio of %d transactions over %d years", opts.Transactions, opts.Years),
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	portfolio.ID = uuid.New()
+
+	transactions, snapshots, holdings, taxLots := generate(portfolio.ID, market, opts)
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(portfolio).Error; err != nil {
+			return fmt.Errorf("failed to create portfolio: %w", err)
+		}
+		if err := tx.CreateInBatches(transactions, seedBatchSize).Error; err != nil {
+			return fmt.Errorf("failed to create transactions: %w", err)
+		}
+		if err := tx.CreateInBatches(snapshots, seedBatchSize).Error; err != nil {
+			return fmt.Errorf("failed to create snapshots: %w", err)
+		}
+		if len(holdings) > 0 {
+			if err := tx.Create(holdings).Error; err != nil {
+				return fmt.Errorf("failed to create holdings: %w", err)
+			}
+		}
+		if len(taxLots) > 0 {
+			if err := tx.CreateInBatches(taxLots, seedBatchSize).Error; err != nil {
+				return fmt.Errorf("failed to create tax lots: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SeedResult{
+		Portfolio:    portfolio,
+		Transactions: len(transactions),
+		Snapshots:    len(snapshots),
+		Holdings:     len(holdings),
+		TaxLots:      len(taxLots),
+		StartDate:    market.Days[0],
+		EndDate:      market.Days[len(market.Days)-1],
+	}, nil
+}
+
+// position is a symbol's holding while transactions are generated, with its open lots
+// oldest first
+type position struct {
+	quantity  int64
+	costBasis decimal.Decimal
+	lots      []*models.TaxLot
+}
+
+// sell removes quantity shares from the position at average cost, the way holdings are
+// kept, and closes its oldest lots
+func (p *position) sell(quantity int64) {
+	sold := p.costBasis.Mul(decimal.NewFromInt(quantity)).Div(decimal.NewFromInt(p.quantity)).Round(8)
+	p.costBasis = p.costBasis.Sub(sold)
+	p.quantity -= quantity
+
+	remaining := decimal.NewFromInt(quantity)
+	for len(p.lots) > 0 && remaining.IsPositive() {
+		lot := p.lots[0]
+		if lot.Quantity.LessThanOrEqual(remaining) {
+			remaining = remaining.Sub(lot.Quantity)
+			p.lots = p.lots[1:]
+			continue
+		}
+		lot.CostBasis = lot.CostBasis.Sub(lot.GetCostPerShare().Mul(remaining)).Round(8)
+		lot.Quantity = lot.Quantity.Sub(remaining)
+		remaining = decimal.Zero
+	}
+}
+
+// generate builds the portfolio's transactions, one snapshot per trading day and its final
+// holdings and open tax lots. Transactions are spread evenly over the trading days; each
+// buys a few shares, sells part of a position or records a dividend on one.
+func generate(
+	portfolioID uuid.UUID,
+	market *Market,
+	opts SeedOptions,
+) ([]*models.Transaction, []*models.PerformanceSnapshot, []*models.Holding, []*models.TaxLot) {
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+	commission := decimal.NewFromInt(1)
+	dividendYield := decimal.NewFromFloat(0.004)
+
+	positions := make([]position, len(market.Symbols))
+	transactions := make([]*models.Transaction, 0, opts.Transactions)
+	snapshots := make([]*models.PerformanceSnapshot, 0, len(market.Days))
+	var previousValue decimal.Decimal
+
+	next := 0
+	for day, date := range market.Days {
+		// The transactions whose even share of the history falls on this day
+		for ; next < opts.Transactions && next*len(market.Days)/opts.Transactions <= day; next++ {
+			k := rng.IntN(len(market.Symbols))
+			pos := &positions[k]
+			price := market.closes[k][day]
+			transaction := &models.Transaction{
+				ID:          uuid.New(),
+				PortfolioID: portfolioID,
+				Symbol:      market.Symbols[k],
+				AssetType:   models.AssetTypeEquity,
+				Date:        date,
+				Currency:    "USD",
+				Multiplier:  1,
+			}
+
+			switch r := rng.Float64(); {
+			case r < 0.08 && pos.quantity > 0:
+				transaction.Type = models.TransactionTypeDividend
+				transaction.Quantity = decimal.NewFromInt(pos.quantity).Mul(price).Mul(dividendYield).Round(2)
+				if !transaction.Quantity.IsPositive() {
+					transaction.Quantity = decimal.NewFromFloat(0.01)
+				}
+			case r < 0.3 && pos.quantity > 0:
+				quantity := min(pos.quantity, 1+rng.Int64N(max(1, pos.quantity/2)))
+				pos.sell(quantity)
+				transaction.Type = models.TransactionTypeSell
+				transaction.Quantity = decimal.NewFromInt(quantity)
+				transaction.Price = &price
+				transaction.Commission = commission
+			default:
+				quantity := 1 + rng.Int64N(20)
+				cost := price.Mul(decimal.NewFromInt(quantity)).Add(commission)
+				pos.quantity += quantity
+				pos.costBasis = pos.costBasis.Add(cost)
+				pos.lots = append(pos.lots, &models.TaxLot{
+					ID:            uuid.New(),
+					PortfolioID:   portfolioID,
+					Symbol:        market.Symbols[k],
+					PurchaseDate:  date,
+					Quantity:      decimal.NewFromInt(quantity),
+					CostBasis:     cost,
+					TransactionID: transaction.ID,
+				})
+				transaction.Type = models.TransactionTypeBuy
+				transaction.Quantity = decimal.NewFromInt(quantity)
+				transaction.Price = &price
+				transaction.Commission = commission
+			}
+			transactions = append(transactions, transaction)
+		}
+
+		var value, costBasis decimal.Decimal
+		for k, pos := range positions {
+			value = value.Add(market.closes[k][day].Mul(decimal.NewFromInt(pos.quantity)))
+			costBasis = costBasis.Add(pos.costBasis)
+		}
+		snapshot := &models.PerformanceSnapshot{
+			ID:             uuid.New(),
+			PortfolioID:    portfolioID,
+			Date:           date,
+			TotalValue:     value,
+			TotalCostBasis: costBasis,
+			TotalReturn:    value.Sub(costBasis),
+			TotalReturnPct: percent(value.Sub(costBasis), costBasis),
+		}
+		if day > 0 {
+			change := value.Sub(previousValue)
+			changePct := percent(change, previousValue)
+			snapshot.DayChange = &change
+			snapshot.DayChangePct = &changePct
+		}
+		snapshots = append(snapshots, snapshot)
+		previousValue = value
+	}
+
+	var holdings []*models.Holding
+	var taxLots []*models.TaxLot
+	for k, pos := range positions {
+		if pos.quantity == 0 {
+			continue
+		}
+		taxLots = append(taxLots, pos.lots...)
+		quantity := decimal.NewFromInt(pos.quantity)
+		holdings = append(holdings, &models.Holding{
+			ID:           uuid.New(),
+			PortfolioID:  portfolioID,
+			Symbol:       market.Symbols[k],
+			AssetType:    models.AssetTypeEquity,
+			Quantity:     quantity,
+			CostBasis:    pos.costBasis,
+			AvgCostPrice: pos.costBasis.Div(quantity).Round(8),
+			Multiplier:   1,
+		})
+	}
+
+	return transactions, snapshots, holdings, taxLots
+}
+
+// percent returns part as a percentage of whole, rounded to the snapshots' 4 places, or zero
+// when whole is zero
+func percent(part, whole decimal.Decimal) decimal.Decimal {
+	if whole.IsZero() {
+		return decimal.Zero
+	}
+	return part.Div(whole).Mul(decimal.NewFromInt(100)).Round(4)
+}
+
+// Market is a synthetic market of symbols whose daily closes follow a random walk over the
+// trading days, Monday to Friday, of a number of years
+type Market struct {
+	Symbols []string
+	// Days are the trading days, oldest first, at midnight UTC
+	Days   []time.Time
+	closes [][]decimal.Decimal
+}
+
+// NewMarket creates a market of symbols trading over the years up to endDate. The same
+// arguments always give the same prices.
+func NewMarket(symbols, years int, endDate time.Time, seed uint64) *Market {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	end := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	market := &Market{}
+	for date := end.AddDate(-years, 0, 0); !date.After(end); date = date.AddDate(0, 0, 1) {
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			market.Days = append(market.Days, date)
+		}
+	}
+
+	for k := range symbols {
+		market.Symbols = append(market.Symbols, fmt.Sprintf("LOAD%02d", k))
+		closes := make([]decimal.Decimal, len(market.Days))
+		price := 20 + rng.Float64()*180
+		for day := range market.Days {
+			// About 7% a year on average, with 24% volatility
+			price *= math.Exp(0.0003 + 0.015*rng.NormFloat64())
+			closes[day] = decimal.NewFromFloat(math.Max(price, 0.01)).Round(2)
+		}
+		market.closes = append(market.closes, closes)
+	}
+
+	return market
+}
+
+// symbolIndex returns the index of symbol in the market, or -1 if it doesn't trade there
+func (m *Market) symbolIndex(symbol string) int {
+	for k, s := range m.Symbols {
+		if s == symbol {
+			return k
+		}
+	}
+	return -1
+}
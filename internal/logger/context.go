@@ -0,0 +1,34 @@
+package logger
+
+import "context"
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the ID of the request it serves
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or "" outside a request
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+// FromContext returns the global logger, tagging its entries with the request ID carried by
+// ctx so that they can be correlated with the request log
+func FromContext(ctx context.Context) *AppLogger {
+	log := GetLogger()
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return log.WithRequestID(requestID)
+	}
+	return log
+}
+
+// WithRequestID returns a logger that adds requestID to every entry
+func (l *AppLogger) WithRequestID(requestID string) *AppLogger {
+	return &AppLogger{logger: l.logger.With().Str("request_id", requestID).Logger(), level: l.level}
+}
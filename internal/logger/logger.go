@@ -1,9 +1,11 @@
 package logger
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -34,6 +36,18 @@ type Config struct {
 // AppLogger wraps zerolog.Logger
 type AppLogger struct {
 	logger zerolog.Logger
+	// level is the minimum level logged, shared with the loggers derived from this one so
+	// that SetLevel changes them all; nil logs every level the zerolog logger allows
+	level *atomic.Int32
+	// outputs ship entries to remote receivers, and are flushed by Close
+	outputs []*outputWriter
+}
+
+// newAppLogger wraps logger, logging events at level and above
+func newAppLogger(logger zerolog.Logger, level zerolog.Level) *AppLogger {
+	l := &AppLogger{logger: logger, level: new(atomic.Int32)}
+	l.level.Store(int32(level))
+	return l
 }
 
 // NewLogger creates a new structured logger
@@ -72,7 +86,7 @@ func NewLogger(config Config) *AppLogger {
 		logger = zerolog.New(output).With().Timestamp().Caller().Logger()
 	}
 
-	return &AppLogger{logger: logger}
+	return newAppLogger(logger, level)
 }
 
 // NewLoggerWithMultipleOutputs creates a logger with multiple output destinations
@@ -120,11 +134,25 @@ func NewLoggerWithMultipleOutputs(config Config) *AppLogger {
 	// Create logger
 	logger := zerolog.New(output).With().Timestamp().Caller().Logger()
 
-	return &AppLogger{logger: logger}
+	return newAppLogger(logger, level)
 }
 
 // NewFileLogger creates a logger that writes to a specific file
 func NewFileLogger(logPath string, level string, format string) (*AppLogger, error) {
+	return NewFileLoggerWithOutputs(logPath, "", level, format, nil)
+}
+
+// NewFileLoggerWithOutputs creates a logger that writes to a specific file and ships its
+// entries to outputs as JSON, whatever the file's format. name identifies the log to the
+// outputs, e.g. "server" or "request". Call Close before exiting to send the entries still
+// queued.
+func NewFileLoggerWithOutputs(logPath, name, level, format string, outputs []Output) (*AppLogger, error) {
+	for _, o := range outputs {
+		if err := o.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Open log file with secure permissions (read/write for owner only)
 	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
 	if err != nil {
@@ -143,34 +171,90 @@ func NewFileLogger(logPath string, level string, format string) (*AppLogger, err
 		}
 	}
 
-	// Create logger
+	var shipped []*outputWriter
+	if len(outputs) > 0 {
+		writers := []io.Writer{output}
+		for _, o := range outputs {
+			w, err := newOutputWriter(o, name)
+			if err != nil {
+				return nil, err
+			}
+			shipped = append(shipped, w)
+			writers = append(writers, w)
+		}
+		output = zerolog.MultiLevelWriter(writers...)
+	}
+
+	// Create logger; the level is applied by AppLogger so that SetLevel can change it
 	logger := zerolog.New(output).
-		Level(logLevel).
 		With().
 		Timestamp().
 		Caller().
 		Logger()
 
-	return &AppLogger{logger: logger}, nil
+	l := newAppLogger(logger, logLevel)
+	l.outputs = shipped
+	return l, nil
 }
 
-// Debug returns a debug level event
+// Close sends the entries the logger's outputs still have queued and closes their
+// connections. Entries logged afterwards are only written to the file.
+func (l *AppLogger) Close() error {
+	var errs []error
+	for _, o := range l.outputs {
+		errs = append(errs, o.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// SetLevel changes the minimum level logged by l and the loggers derived from it while they
+// are in use
+func (l *AppLogger) SetLevel(level string) {
+	parsed := parseLevel(level)
+	if l.level != nil {
+		l.level.Store(int32(parsed))
+	}
+
+	// The global level is a floor for every logger, so lower it for more verbose levels
+	if parsed < zerolog.GlobalLevel() {
+		zerolog.SetGlobalLevel(parsed)
+	}
+}
+
+// enabled reports whether l logs events at level
+func (l *AppLogger) enabled(level zerolog.Level) bool {
+	return l.level == nil || level >= zerolog.Level(l.level.Load())
+}
+
+// Debug returns a debug level event, or nil if debug events are not logged
 func (l *AppLogger) Debug() *zerolog.Event {
+	if !l.enabled(zerolog.DebugLevel) {
+		return nil
+	}
 	return l.logger.Debug()
 }
 
-// Info returns an info level event
+// Info returns an info level event, or nil if info events are not logged
 func (l *AppLogger) Info() *zerolog.Event {
+	if !l.enabled(zerolog.InfoLevel) {
+		return nil
+	}
 	return l.logger.Info()
 }
 
-// Warn returns a warn level event
+// Warn returns a warn level event, or nil if warnings are not logged
 func (l *AppLogger) Warn() *zerolog.Event {
+	if !l.enabled(zerolog.WarnLevel) {
+		return nil
+	}
 	return l.logger.Warn()
 }
 
-// Error returns an error level event
+// Error returns an error level event, or nil if errors are not logged
 func (l *AppLogger) Error() *zerolog.Event {
+	if !l.enabled(zerolog.ErrorLevel) {
+		return nil
+	}
 	return l.logger.Error()
 }
 
@@ -2,11 +2,15 @@ package logger
 
 import (
 	"bytes"
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -168,3 +172,37 @@ func TestGetLogger_Fallback(t *testing.T) {
 	logger := GetLogger()
 	assert.NotNil(t, logger)
 }
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	globalLogger = &AppLogger{logger: zerolog.New(&buf)}
+	t.Cleanup(func() { globalLogger = nil })
+
+	ctx := ContextWithRequestID(context.Background(), "req-123")
+	assert.Equal(t, "req-123", RequestIDFromContext(ctx))
+
+	FromContext(ctx).Info().Msg("tagged")
+	assert.Contains(t, buf.String(), `"request_id":"req-123"`)
+
+	buf.Reset()
+	FromContext(context.Background()).Info().Msg("untagged")
+	assert.NotContains(t, buf.String(), "request_id")
+}
+
+func TestAppLogger_SetLevel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+	logger, err := NewFileLogger(path, "warn", "json")
+	require.NoError(t, err)
+	requestLogger := logger.WithRequestID("req-1")
+
+	logger.Info().Msg("hidden before reload")
+	logger.SetLevel("debug")
+	logger.Debug().Msg("shown after reload")
+	requestLogger.Info().Msg("shown for requests")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "hidden before reload")
+	assert.Contains(t, string(data), "shown after reload")
+	assert.Contains(t, string(data), "shown for requests")
+}
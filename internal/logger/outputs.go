@@ -0,0 +1,537 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Output types
+const (
+	// OutputSyslog sends entries to a syslog server in the RFC 5424 format
+	OutputSyslog = "syslog"
+	// OutputHTTP posts entries as newline-delimited JSON, as accepted by Logstash, Vector
+	// and Fluent Bit HTTP inputs
+	OutputHTTP = "http"
+	// OutputOTLP posts entries as OTLP/HTTP JSON log records, as accepted by OpenTelemetry
+	// collectors and Loki
+	OutputOTLP = "otlp"
+)
+
+const (
+	// defaultOutputTag names the application to syslog and OTLP receivers
+	defaultOutputTag = "portfolios"
+	// defaultSyslogSocket is the local syslog socket used when no address is set
+	defaultSyslogSocket = "/dev/log"
+
+	// outputQueueSize is how many entries an output buffers before it drops new ones
+	outputQueueSize = 4096
+	// outputBatchSize is the most entries an output sends at once
+	outputBatchSize = 100
+	// outputFlushInterval is the longest an entry waits to be sent
+	outputFlushInterval = time.Second
+	// outputTimeout limits each delivery to a receiver
+	outputTimeout = 10 * time.Second
+)
+
+// Output is a destination entries are shipped to besides the log file
+type Output struct {
+	// Type is OutputSyslog, OutputHTTP or OutputOTLP
+	Type string
+	// Address is the syslog server as udp://host:port, tcp://host:port or unix:///path;
+	// empty uses the local syslog socket
+	Address string
+	// URL is the endpoint of HTTP and OTLP outputs
+	URL string
+	// Headers are sent with every HTTP and OTLP request, e.g. for authentication
+	Headers map[string]string
+	// Tag is the application name sent to syslog and the OTLP service name
+	Tag string
+}
+
+// Validate checks that o has the settings its type needs
+func (o Output) Validate() error {
+	switch o.Type {
+	case OutputSyslog:
+		if o.Address != "" {
+			if _, _, err := syslogAddress(o.Address); err != nil {
+				return err
+			}
+		}
+	case OutputHTTP, OutputOTLP:
+		u, err := url.Parse(o.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%s output needs an http or https URL, got %q", o.Type, o.URL)
+		}
+	default:
+		return fmt.Errorf("unknown log output type %q, expected syslog, http or otlp", o.Type)
+	}
+	return nil
+}
+
+// sender delivers batches of JSON log entries to a receiver
+type sender interface {
+	send(ctx context.Context, entries [][]byte) error
+	close() error
+}
+
+// newOutputWriter returns a writer shipping the entries of the log called name to o
+func newOutputWriter(o Output, name string) (*outputWriter, error) {
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	tag := o.Tag
+	if tag == "" {
+		tag = defaultOutputTag
+	}
+
+	var s sender
+	switch o.Type {
+	case OutputSyslog:
+		network, address := "unixgram", defaultSyslogSocket
+		if o.Address != "" {
+			network, address, _ = syslogAddress(o.Address)
+		}
+		s = newSyslogSender(network, address, tag, name)
+	case OutputHTTP:
+		s = &httpSender{url: o.URL, headers: o.Headers, name: name, client: &http.Client{Timeout: outputTimeout}}
+	case OutputOTLP:
+		s = &otlpSender{url: o.URL, headers: o.Headers, service: tag, name: name, client: &http.Client{Timeout: outputTimeout}}
+	}
+	return newQueuedWriter(o.Type, s), nil
+}
+
+// outputWriter queues entries and delivers them in batches from a goroutine, so that a slow
+// or unreachable receiver never holds up the code that logs. Entries are dropped while the
+// queue is full.
+type outputWriter struct {
+	kind    string
+	sender  sender
+	mu      sync.RWMutex
+	closed  bool
+	entries chan []byte
+	done    chan struct{}
+	dropped atomic.Int64
+}
+
+func newQueuedWriter(kind string, s sender) *outputWriter {
+	w := &outputWriter{
+		kind:    kind,
+		sender:  s,
+		entries: make(chan []byte, outputQueueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write implements io.Writer. zerolog writes one entry per call.
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return len(p), nil
+	}
+	entry := bytes.TrimRight(append([]byte(nil), p...), "\n")
+	select {
+	case w.entries <- entry:
+	default:
+		w.dropped.Add(1)
+	}
+	return len(p), nil
+}
+
+// Close sends the entries still queued and releases the output's connection
+func (w *outputWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	close(w.entries)
+	w.mu.Unlock()
+
+	<-w.done
+	return w.sender.close()
+}
+
+func (w *outputWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(outputFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, outputBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), outputTimeout)
+		err := w.sender.send(ctx, batch)
+		cancel()
+		if err != nil {
+			// The logger can't log its own failures, so report them where the process's
+			// supervisor will see them
+			fmt.Fprintf(os.Stderr, "%s log output: %d entries not delivered: %v\n", w.kind, len(batch), err)
+		}
+		if dropped := w.dropped.Swap(0); dropped > 0 {
+			fmt.Fprintf(os.Stderr, "%s log output: %d entries dropped while the queue was full\n", w.kind, dropped)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case entry, ok := <-w.entries:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, entry)
+			if len(batch) == outputBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// entryFields holds the fields of a JSON entry that outputs interpret
+type entryFields struct {
+	Level string `json:"level"`
+	Time  string `json:"time"`
+}
+
+// syslogAddress splits a syslog address URL into a network and address for net.Dial
+func syslogAddress(address string) (string, string, error) {
+	u, err := url.Parse(address)
+	if err == nil {
+		switch u.Scheme {
+		case "udp", "tcp":
+			if u.Host != "" {
+				return u.Scheme, u.Host, nil
+			}
+		case "unix":
+			if u.Path != "" {
+				return "unixgram", u.Path, nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("syslog address must be udp://host:port, tcp://host:port or unix:///path, got %q", address)
+}
+
+// syslogSender sends entries to a syslog server in the RFC 5424 format, with the log's name
+// as the message ID. Messages over TCP are framed by octet counting (RFC 6587).
+type syslogSender struct {
+	network  string
+	address  string
+	hostname string
+	tag      string
+	msgID    string
+	conn     net.Conn
+}
+
+func newSyslogSender(network, address, tag, name string) *syslogSender {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	msgID := name
+	if msgID == "" {
+		msgID = "-"
+	}
+	return &syslogSender{network: network, address: address, hostname: hostname, tag: tag, msgID: msgID}
+}
+
+func (s *syslogSender) send(ctx context.Context, entries [][]byte) error {
+	for _, entry := range entries {
+		message := s.format(entry)
+		if err := s.write(ctx, message); err != nil {
+			// Reconnect once, in case the server restarted
+			if err = s.write(ctx, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// format builds the syslog message for an entry, with the entry's JSON as the message
+func (s *syslogSender) format(entry []byte) []byte {
+	var fields entryFields
+	_ = json.Unmarshal(entry, &fields)
+	timestamp := time.Now().UTC().Format(time.RFC3339Nano)
+	if t, err := time.Parse(time.RFC3339, fields.Time); err == nil {
+		timestamp = t.UTC().Format(time.RFC3339Nano)
+	}
+
+	// Facility 1 is user-level messages
+	priority := 1*8 + syslogSeverity(fields.Level)
+	message := fmt.Sprintf("<%d>1 %s %s %s %d %s - %s", priority, timestamp, s.hostname, s.tag, os.Getpid(), s.msgID, entry)
+	if s.network == "tcp" {
+		message = strconv.Itoa(len(message)) + " " + message
+	}
+	return []byte(message)
+}
+
+func (s *syslogSender) write(ctx context.Context, message []byte) error {
+	if s.conn == nil {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+	if _, err := s.conn.Write(message); err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (s *syslogSender) close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// syslogSeverity maps a zerolog level to a syslog severity
+func syslogSeverity(level string) int {
+	switch level {
+	case "panic":
+		return 0 // Emergency
+	case "fatal":
+		return 2 // Critical
+	case "error":
+		return 3 // Error
+	case "warn":
+		return 4 // Warning
+	case "debug", "trace":
+		return 7 // Debug
+	default:
+		return 6 // Informational
+	}
+}
+
+// httpSender posts entries as newline-delimited JSON, adding a "log" field with the log's
+// name so receivers can tell the server and request logs apart
+type httpSender struct {
+	url     string
+	headers map[string]string
+	name    string
+	client  *http.Client
+}
+
+func (s *httpSender) send(ctx context.Context, entries [][]byte) error {
+	var body bytes.Buffer
+	prefix := []byte(`{"log":` + strconv.Quote(s.name))
+	for _, entry := range entries {
+		if s.name != "" && len(entry) > 2 && entry[0] == '{' {
+			body.Write(prefix)
+			body.WriteByte(',')
+			body.Write(entry[1:])
+		} else {
+			body.Write(entry)
+		}
+		body.WriteByte('\n')
+	}
+	return post(ctx, s.client, s.url, "application/x-ndjson", s.headers, &body)
+}
+
+func (s *httpSender) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// otlpSender posts entries as OTLP/HTTP JSON log records. The entry's message becomes the
+// record body and its other fields the record attributes.
+type otlpSender struct {
+	url     string
+	headers map[string]string
+	service string
+	name    string
+	client  *http.Client
+}
+
+// OTLP JSON encoding of the logs data model
+type (
+	otlpLogsRequest struct {
+		ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+	}
+	otlpResourceLogs struct {
+		Resource  otlpResource    `json:"resource"`
+		ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeLogs struct {
+		Scope      otlpScope       `json:"scope"`
+		LogRecords []otlpLogRecord `json:"logRecords"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpLogRecord struct {
+		TimeUnixNano   string         `json:"timeUnixNano"`
+		SeverityNumber int            `json:"severityNumber"`
+		SeverityText   string         `json:"severityText"`
+		Body           otlpAnyValue   `json:"body"`
+		Attributes     []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string       `json:"key"`
+		Value otlpAnyValue `json:"value"`
+	}
+	otlpAnyValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+func (s *otlpSender) send(ctx context.Context, entries [][]byte) error {
+	records := make([]otlpLogRecord, 0, len(entries))
+	for _, entry := range entries {
+		records = append(records, otlpRecord(entry))
+	}
+	request := otlpLogsRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource: otlpResource{Attributes: []otlpKeyValue{
+			{Key: "service.name", Value: otlpString(s.service)},
+			{Key: "log.name", Value: otlpString(s.name)},
+		}},
+		ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: s.service}, LogRecords: records}},
+	}}}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.client, s.url, "application/json", s.headers, bytes.NewReader(body))
+}
+
+func (s *otlpSender) close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// otlpRecord converts a JSON entry to an OTLP log record
+func otlpRecord(entry []byte) otlpLogRecord {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(entry, &fields); err != nil {
+		return otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(time.Now().UnixNano(), 10),
+			SeverityNumber: otlpSeverity(""),
+			Body:           otlpString(string(entry)),
+		}
+	}
+
+	level, _ := fields["level"].(string)
+	message, _ := fields["message"].(string)
+	timestamp := time.Now()
+	if value, ok := fields["time"].(string); ok {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			timestamp = t
+		}
+	}
+
+	record := otlpLogRecord{
+		TimeUnixNano:   strconv.FormatInt(timestamp.UnixNano(), 10),
+		SeverityNumber: otlpSeverity(level),
+		SeverityText:   strings.ToUpper(level),
+		Body:           otlpString(message),
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		switch key {
+		case "level", "message", "time":
+		default:
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		record.Attributes = append(record.Attributes, otlpKeyValue{Key: key, Value: otlpValue(fields[key])})
+	}
+	return record
+}
+
+func otlpString(value string) otlpAnyValue {
+	return otlpAnyValue{StringValue: &value}
+}
+
+// otlpValue converts a decoded JSON value to an OTLP value, encoding objects and arrays as
+// JSON strings
+func otlpValue(value interface{}) otlpAnyValue {
+	switch v := value.(type) {
+	case string:
+		return otlpString(v)
+	case bool:
+		return otlpAnyValue{BoolValue: &v}
+	case float64:
+		return otlpAnyValue{DoubleValue: &v}
+	default:
+		encoded, _ := json.Marshal(v)
+		return otlpString(string(encoded))
+	}
+}
+
+// otlpSeverity maps a zerolog level to an OTLP severity number
+func otlpSeverity(level string) int {
+	switch level {
+	case "trace":
+		return 1
+	case "debug":
+		return 5
+	case "warn":
+		return 13
+	case "error":
+		return 17
+	case "fatal", "panic":
+		return 21
+	default:
+		return 9 // Info
+	}
+}
+
+// post sends body to endpoint and fails unless the receiver accepts it
+func post(ctx context.Context, client *http.Client, endpoint, contentType string, headers map[string]string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("receiver answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver records the requests an HTTP output makes
+type receiver struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	response int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.bodies = append(r.bodies, string(body))
+	r.headers = append(r.headers, req.Header.Clone())
+	r.mu.Unlock()
+	if r.response != 0 {
+		w.WriteHeader(r.response)
+	}
+}
+
+func newOutputLogger(t *testing.T, output Output) *AppLogger {
+	t.Helper()
+	l, err := NewFileLoggerWithOutputs(filepath.Join(t.TempDir(), "server.log"), "server", "info", "console", []Output{output})
+	require.NoError(t, err)
+	return l
+}
+
+func TestOutput_Validate(t *testing.T) {
+	assert.NoError(t, Output{Type: OutputSyslog}.Validate())
+	assert.NoError(t, Output{Type: OutputSyslog, Address: "udp://localhost:514"}.Validate())
+	assert.NoError(t, Output{Type: OutputSyslog, Address: "unix:///dev/log"}.Validate())
+	assert.NoError(t, Output{Type: OutputOTLP, URL: "https://collector:4318/v1/logs"}.Validate())
+
+	assert.Error(t, Output{Type: OutputSyslog, Address: "localhost:514"}.Validate())
+	assert.Error(t, Output{Type: OutputHTTP}.Validate())
+	assert.Error(t, Output{Type: OutputHTTP, URL: "ftp://logs"}.Validate())
+	assert.Error(t, Output{Type: "kafka"}.Validate())
+
+	_, err := NewFileLoggerWithOutputs(filepath.Join(t.TempDir(), "server.log"), "server", "info", "json", []Output{{Type: "kafka"}})
+	assert.Error(t, err)
+}
+
+func TestHTTPOutput(t *testing.T) {
+	rec := &receiver{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	l := newOutputLogger(t, Output{Type: OutputHTTP, URL: server.URL, Headers: map[string]string{"Authorization": "Bearer abc"}})
+	l.Info().Str("path", "/health").Msg("first")
+	l.Debug().Msg("below the level")
+	l.Warn().Msg("second")
+	require.NoError(t, l.Close())
+
+	require.Len(t, rec.bodies, 1)
+	assert.Equal(t, "application/x-ndjson", rec.headers[0].Get("Content-Type"))
+	assert.Equal(t, "Bearer abc", rec.headers[0].Get("Authorization"))
+
+	// Entries are shipped as JSON even though the file is written in the console format
+	scanner := bufio.NewScanner(strings.NewReader(rec.bodies[0]))
+	var entries []map[string]interface{}
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 2)
+	assert.Equal(t, "server", entries[0]["log"])
+	assert.Equal(t, "first", entries[0]["message"])
+	assert.Equal(t, "/health", entries[0]["path"])
+	assert.Equal(t, "warn", entries[1]["level"])
+}
+
+func TestOTLPOutput(t *testing.T) {
+	rec := &receiver{}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	l := newOutputLogger(t, Output{Type: OutputOTLP, URL: server.URL + "/v1/logs", Tag: "portfolios-api"})
+	l.Error().Int("status_code", 500).Bool("retried", true).Msg("request failed")
+	require.NoError(t, l.Close())
+
+	require.Len(t, rec.bodies, 1)
+	var request otlpLogsRequest
+	require.NoError(t, json.Unmarshal([]byte(rec.bodies[0]), &request))
+	require.Len(t, request.ResourceLogs, 1)
+	resource := request.ResourceLogs[0]
+	assert.Equal(t, "service.name", resource.Resource.Attributes[0].Key)
+	assert.Equal(t, "portfolios-api", *resource.Resource.Attributes[0].Value.StringValue)
+	assert.Equal(t, "server", *resource.Resource.Attributes[1].Value.StringValue)
+
+	require.Len(t, resource.ScopeLogs[0].LogRecords, 1)
+	record := resource.ScopeLogs[0].LogRecords[0]
+	assert.Equal(t, "request failed", *record.Body.StringValue)
+	assert.Equal(t, 17, record.SeverityNumber)
+	assert.Equal(t, "ERROR", record.SeverityText)
+	assert.NotEmpty(t, record.TimeUnixNano)
+
+	attributes := map[string]otlpAnyValue{}
+	for _, kv := range record.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	assert.Equal(t, 500.0, *attributes["status_code"].DoubleValue)
+	assert.True(t, *attributes["retried"].BoolValue)
+	assert.NotContains(t, attributes, "message")
+}
+
+func TestHTTPOutput_ReceiverFailureDoesNotFailLogging(t *testing.T) {
+	rec := &receiver{response: http.StatusServiceUnavailable}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	l := newOutputLogger(t, Output{Type: OutputHTTP, URL: server.URL})
+	l.Info().Msg("lost")
+	assert.NoError(t, l.Close())
+	assert.Len(t, rec.bodies, 1)
+}
+
+func TestSyslogOutput(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	l := newOutputLogger(t, Output{Type: OutputSyslog, Address: "udp://" + conn.LocalAddr().String(), Tag: "portfolios"})
+	l.Warn().Msg("disk almost full")
+	require.NoError(t, l.Close())
+
+	buf := make([]byte, 4096)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	message := string(buf[:n])
+
+	// Facility user (1) and severity warning (4)
+	assert.True(t, strings.HasPrefix(message, "<12>1 "), message)
+	assert.Contains(t, message, " portfolios ")
+	assert.Contains(t, message, " server - {")
+	assert.Contains(t, message, `"message":"disk almost full"`)
+}
+
+func TestSyslogSender_TCPFraming(t *testing.T) {
+	s := newSyslogSender("tcp", "localhost:514", "portfolios", "request")
+	message := string(s.format([]byte(`{"level":"info","message":"hi"}`)))
+
+	length, rest, ok := strings.Cut(message, " ")
+	require.True(t, ok)
+	n, err := strconv.Atoi(length)
+	require.NoError(t, err)
+	assert.Equal(t, len(rest), n)
+	assert.True(t, strings.HasPrefix(rest, "<14>1 "))
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+)
+
+// AdminTokenRequired is a middleware that only admits requests bearing the configured admin
+// API token. It guards the provisioning API used by infrastructure tooling.
+func AdminTokenRequired(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader(AuthorizationHeader)
+		if !strings.HasPrefix(authHeader, BearerPrefix) {
+			apierrors.Abort(c, apierrors.MissingAdminToken)
+			return
+		}
+
+		// Compare in constant time so the token cannot be guessed from response timing
+		token := strings.TrimPrefix(authHeader, BearerPrefix)
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			apierrors.Abort(c, apierrors.InvalidAdminToken)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+)
+
+const (
+	// APIVersionHeader names the API version a response was produced for
+	APIVersionHeader = "API-Version"
+	// APIVersionContextKey is the context key holding the API version of a request
+	APIVersionContextKey = "api_version"
+)
+
+// SupportedAPIVersions are the API versions the server serves, oldest first. Each version
+// has its routes under its own /api/vN prefix.
+var SupportedAPIVersions = []int{1}
+
+// apiMediaType matches the vendor media types clients name API versions with in the Accept
+// header, such as application/vnd.portfolios.v1+json
+var apiMediaType = regexp.MustCompile(`^application/vnd\.portfolios(?:\.v(\d+))?\+json$`)
+
+// APIVersion returns a middleware that negotiates the API version of requests to the routes
+// of pathVersion. A request gets the highest supported version its Accept header names with
+// the vendor media type, or pathVersion if it names none, and is rejected with 406
+// UNSUPPORTED_API_VERSION if it only names versions the server doesn't support. Handlers
+// whose responses differ between versions read the negotiated one with GetAPIVersion, and
+// responses name it in the API-Version header.
+func APIVersion(pathVersion int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := pathVersion
+		requested, named := acceptedAPIVersions(c.GetHeader("Accept"))
+		if named {
+			version = 0
+			for _, v := range requested {
+				if v > version && slices.Contains(SupportedAPIVersions, v) {
+					version = v
+				}
+			}
+			if version == 0 {
+				apierrors.Abort(c, apierrors.UnsupportedAPIVersion.WithMessage(fmt.Sprintf(
+					"None of the requested API versions is supported; supported versions: %s", supportedAPIVersionList())))
+				return
+			}
+		}
+
+		c.Set(APIVersionContextKey, version)
+		c.Header(APIVersionHeader, strconv.Itoa(version))
+		c.Header("Vary", "Accept")
+		c.Next()
+	}
+}
+
+// GetAPIVersion returns the API version negotiated for the request, or the oldest supported
+// version outside the versioned routes
+func GetAPIVersion(c *gin.Context) int {
+	if version, ok := c.Get(APIVersionContextKey); ok {
+		if v, ok := version.(int); ok {
+			return v
+		}
+	}
+	return SupportedAPIVersions[0]
+}
+
+// acceptedAPIVersions returns the versions an Accept header names with the vendor media
+// type, and whether it names any. A vendor media type without a version names none of its
+// own, leaving the path's version.
+func acceptedAPIVersions(accept string) ([]int, bool) {
+	var versions []int
+	named := false
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(mediaRange, ";")
+		match := apiMediaType.FindStringSubmatch(strings.ToLower(strings.TrimSpace(mediaType)))
+		if match == nil || match[1] == "" {
+			continue
+		}
+		named = true
+		if v, err := strconv.Atoi(match[1]); err == nil {
+			versions = append(versions, v)
+		}
+	}
+	return versions, named
+}
+
+// supportedAPIVersionList returns SupportedAPIVersions for error messages, e.g. "1, 2"
+func supportedAPIVersionList() string {
+	versions := make([]string, len(SupportedAPIVersions))
+	for i, v := range SupportedAPIVersions {
+		versions[i] = strconv.Itoa(v)
+	}
+	return strings.Join(versions, ", ")
+}
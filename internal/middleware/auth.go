@@ -1,10 +1,11 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/services"
 )
 
@@ -13,64 +14,60 @@ const (
 	AuthorizationHeader = "Authorization"
 	// BearerPrefix is the prefix for bearer tokens
 	BearerPrefix = "Bearer "
+	// APIKeyHeader is the header name for API keys issued through the admin API
+	APIKeyHeader = "X-API-Key"
 	// UserIDContextKey is the context key for user ID
 	UserIDContextKey = "user_id"
+	// APIKeyOrganizationContextKey is the context key for the organization a request's API key
+	// was issued in
+	APIKeyOrganizationContextKey = "api_key_organization_id"
+	// AccessTokenCookieName is the cookie holding the access token in cookie session mode
+	AccessTokenCookieName = "access_token"
+	// RefreshTokenCookieName is the cookie holding the refresh token in cookie session mode
+	RefreshTokenCookieName = "refresh_token"
 )
 
-// AuthRequired is a middleware that validates JWT tokens and attaches user ID to context
+// AuthRequired is a middleware that validates JWT tokens and attaches user ID to context.
+// The token is read from the Authorization header, or from the access token cookie set in
+// cookie session mode if the header is absent.
 func AuthRequired(tokenService *services.TokenService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract token from Authorization header
 		authHeader := c.GetHeader(AuthorizationHeader)
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header is required",
-				"code":  "MISSING_AUTH_HEADER",
-			})
-			c.Abort()
+			if cookie, err := c.Cookie(AccessTokenCookieName); err == nil && cookie != "" {
+				authHeader = BearerPrefix + cookie
+			}
+		}
+		if authHeader == "" {
+			apierrors.Abort(c, apierrors.MissingAuthHeader)
 			return
 		}
 
 		// Check Bearer prefix
 		if !strings.HasPrefix(authHeader, BearerPrefix) {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header must start with 'Bearer '",
-				"code":  "INVALID_AUTH_HEADER_FORMAT",
-			})
-			c.Abort()
+			apierrors.Abort(c, apierrors.InvalidAuthHeaderFormat)
 			return
 		}
 
 		// Extract token
 		tokenString := strings.TrimPrefix(authHeader, BearerPrefix)
 		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token is required",
-				"code":  "MISSING_TOKEN",
-			})
-			c.Abort()
+			apierrors.Abort(c, apierrors.MissingToken)
 			return
 		}
 
 		// Validate token
 		token, err := tokenService.ValidateToken(tokenString)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid or expired token",
-				"code":  "INVALID_TOKEN",
-			})
-			c.Abort()
+			apierrors.Abort(c, apierrors.InvalidToken)
 			return
 		}
 
 		// Extract user ID
 		userID, err := tokenService.ExtractUserID(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Failed to extract user information from token",
-				"code":  "INVALID_TOKEN_CLAIMS",
-			})
-			c.Abort()
+			apierrors.Abort(c, apierrors.InvalidTokenClaims)
 			return
 		}
 
@@ -82,6 +79,34 @@ func AuthRequired(tokenService *services.TokenService) gin.HandlerFunc {
 	}
 }
 
+// AuthRequiredWithAPIKeys is like AuthRequired but also accepts an API key in the X-API-Key
+// header. Requests authenticated with a key act as the user the key was issued to, and may
+// only act in the organization it was issued in.
+func AuthRequiredWithAPIKeys(tokenService *services.TokenService, apiKeyService services.APIKeyService) gin.HandlerFunc {
+	jwtAuth := AuthRequired(tokenService)
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			jwtAuth(c)
+			return
+		}
+
+		apiKey, err := apiKeyService.Authenticate(key)
+		if err != nil {
+			apierrors.Abort(c, apierrors.InvalidAPIKey)
+			return
+		}
+
+		// Attach user ID to context
+		c.Set(UserIDContextKey, apiKey.UserID.String())
+		c.Set(APIKeyOrganizationContextKey, apiKey.OrganizationID.String())
+
+		// Continue to next handler
+		c.Next()
+	}
+}
+
 // GetUserID extracts the authenticated user ID from the Gin context
 func GetUserID(c *gin.Context) string {
 	userID, exists := c.Get(UserIDContextKey)
@@ -1,9 +1,9 @@
 package middleware
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 )
 
 // RequireOwnership is a middleware that ensures the authenticated user owns the resource
@@ -13,11 +13,7 @@ func RequireOwnership(resourceUserIDParam string) gin.HandlerFunc {
 		// Get authenticated user ID from context
 		authenticatedUserID := GetUserID(c)
 		if authenticatedUserID == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authentication required",
-				"code":  "NOT_AUTHENTICATED",
-			})
-			c.Abort()
+			apierrors.Abort(c, apierrors.NotAuthenticated)
 			return
 		}
 
@@ -55,11 +51,7 @@ func RequireOwnership(resourceUserIDParam string) gin.HandlerFunc {
 
 		// Compare user IDs
 		if authenticatedUserID != resourceUserID {
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "You do not have permission to access this resource",
-				"code":  "FORBIDDEN",
-			})
-			c.Abort()
+			apierrors.Abort(c, apierrors.Forbidden)
 			return
 		}
 
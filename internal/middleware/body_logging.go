@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+// redacted replaces sensitive values in logged bodies
+const redacted = "[REDACTED]"
+
+var (
+	// sensitiveJSONField matches a JSON string value whose key names a password, token,
+	// secret, API key or email address. The closing quote is optional, since a truncated
+	// body may end inside it.
+	sensitiveJSONField = regexp.MustCompile(`(?i)("[a-z0-9_\-]*(?:password|token|secret|api_?key|authorization|email)[a-z0-9_\-]*"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+	// sensitiveFormField matches the same keys in URL-encoded form bodies
+	sensitiveFormField = regexp.MustCompile(`(?i)((?:^|[&?])[a-z0-9_\-]*(?:password|token|secret|api_?key|authorization|email)[a-z0-9_\-]*=)[^&\s]*`)
+	// emailAddress matches email addresses anywhere in a body, including imported CSV files
+	emailAddress = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// BodyLogger logs the request and response bodies of selected routes, truncated to a size
+// limit and with passwords, tokens and email addresses redacted. The routes can be changed
+// while it is in use.
+type BodyLogger struct {
+	log      *logger.AppLogger
+	mu       sync.RWMutex
+	routes   []bodyRoute
+	maxBytes int
+}
+
+// bodyRoute is a route whose bodies are logged: a Gin route pattern, or a prefix of one if
+// it ended in *, and the method it applies to, or every method if empty
+type bodyRoute struct {
+	method string
+	path   string
+	prefix bool
+}
+
+// NewBodyLogger creates a body logger writing to log. See SetRoutes for routes and maxBytes.
+func NewBodyLogger(log *logger.AppLogger, routes []string, maxBytes int) *BodyLogger {
+	b := &BodyLogger{log: log}
+	b.SetRoutes(routes, maxBytes)
+	return b
+}
+
+// SetRoutes changes the routes whose bodies are logged and how many bytes of each body are
+// kept. A route is a Gin route pattern optionally preceded by a method, such as
+// "POST /api/v1/portfolios/:id/imports"; a pattern ending in * matches every route it
+// begins. No routes turns body logging off.
+func (b *BodyLogger) SetRoutes(routes []string, maxBytes int) {
+	parsed := make([]bodyRoute, 0, len(routes))
+	for _, route := range routes {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+		var r bodyRoute
+		if method, path, ok := strings.Cut(route, " "); ok {
+			r.method = strings.ToUpper(method)
+			route = strings.TrimSpace(path)
+		}
+		r.path, r.prefix = strings.CutSuffix(route, "*")
+		parsed = append(parsed, r)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.routes = parsed
+	b.maxBytes = maxBytes
+}
+
+// limitFor returns how many bytes of the bodies of a request to log, or zero if they aren't
+// logged
+func (b *BodyLogger) limitFor(method, fullPath string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.maxBytes <= 0 || fullPath == "" {
+		return 0
+	}
+	for _, r := range b.routes {
+		if r.method != "" && r.method != method {
+			continue
+		}
+		if fullPath == r.path || (r.prefix && strings.HasPrefix(fullPath, r.path)) {
+			return b.maxBytes
+		}
+	}
+	return 0
+}
+
+// Middleware returns the Gin middleware that logs the bodies of the selected routes. It
+// must run after RequestID so the entries can be matched with the request log.
+func (b *BodyLogger) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := b.limitFor(c.Request.Method, c.FullPath())
+		if limit == 0 {
+			c.Next()
+			return
+		}
+
+		// Read only what is logged, and hand the handler the rest of the body untouched
+		var requestBody []byte
+		requestTruncated := false
+		if c.Request.Body != nil && c.Request.Body != http.NoBody {
+			body := c.Request.Body
+			head, _ := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+			c.Request.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(head), body), Closer: body}
+			requestTruncated = len(head) > limit
+			requestBody = head[:min(len(head), limit)]
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+
+		c.Next()
+
+		b.log.Info().
+			Str("method", c.Request.Method).
+			Str("route", c.FullPath()).
+			Str("request_id", GetRequestID(c)).
+			Str("request_body", loggedBody(requestBody, c.ContentType())).
+			Bool("request_body_truncated", requestTruncated).
+			Str("response_body", loggedBody(writer.body.Bytes(), writer.Header().Get("Content-Type"))).
+			Bool("response_body_truncated", writer.truncated).
+			Msg("HTTP request body")
+	}
+}
+
+// readCloser pairs the reader of a request body with the closer of the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyCaptureWriter keeps the first limit bytes written to a response
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (w *bodyCaptureWriter) capture(data []byte) {
+	if room := w.limit - w.body.Len(); room < len(data) {
+		w.truncated = true
+		data = data[:max(room, 0)]
+	}
+	w.body.Write(data)
+}
+
+// Write implements io.Writer
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString implements io.StringWriter
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// loggedBody returns the redacted text of a body, or a placeholder for bodies that aren't
+// text, such as PDF statements
+func loggedBody(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !textualContentType(contentType) {
+		return "[" + contentType + " body omitted]"
+	}
+	return RedactBody(string(bytes.ToValidUTF8(body, nil)))
+}
+
+// textualContentType reports whether bodies of a content type can be logged as text
+func textualContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType == ""
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"),
+		strings.HasSuffix(mediaType, "xml"),
+		mediaType == "application/x-www-form-urlencoded",
+		mediaType == "multipart/form-data":
+		return true
+	}
+	return false
+}
+
+// RedactBody replaces passwords, tokens, secrets, API keys and email addresses in a JSON,
+// form or text body, including URL-encoded addresses in email fields
+func RedactBody(body string) string {
+	body = sensitiveJSONField.ReplaceAllString(body, `$1"`+redacted+`"`)
+	body = sensitiveFormField.ReplaceAllString(body, "${1}"+redacted)
+	return emailAddress.ReplaceAllString(body, redacted)
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+// bodyLogRouter returns a router whose routes echo their request body, logging bodies with
+// bodyLogger, and the path of its log file
+func bodyLogRouter(t *testing.T, routes []string, maxBytes int) (*gin.Engine, *BodyLogger, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	logPath := filepath.Join(t.TempDir(), "requests.log")
+	log, err := logger.NewFileLogger(logPath, "info", "json")
+	require.NoError(t, err)
+
+	bodyLogger := NewBodyLogger(log, routes, maxBytes)
+	router := gin.New()
+	router.Use(RequestID(), bodyLogger.Middleware())
+	echo := func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, "application/json", body)
+	}
+	router.POST("/api/auth/login", echo)
+	router.POST("/api/v1/portfolios/:id/imports", echo)
+	router.GET("/api/v1/portfolios/:id/statement", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/pdf", []byte("%PDF-1.7"))
+	})
+	return router, bodyLogger, logPath
+}
+
+// bodyLogEntries returns the body log entries written to logPath
+func bodyLogEntries(t *testing.T, logPath string) []map[string]interface{} {
+	t.Helper()
+	file, err := os.Open(logPath)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	var entries []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		if entry["message"] == "HTTP request body" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func TestBodyLogger_RedactsSensitiveValues(t *testing.T) {
+	router, _, logPath := bodyLogRouter(t, []string{"POST /api/auth/*"}, 4096)
+
+	body := `{"email":"investor@example.com","password":"SecurePass123","refresh_token":"abc.def"}`
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/auth/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	// The handler still sees the whole body
+	assert.Equal(t, body, w.Body.String())
+
+	entries := bodyLogEntries(t, logPath)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "/api/auth/login", entries[0]["route"])
+	assert.Equal(t, w.Header().Get(RequestIDHeader), entries[0]["request_id"])
+	for _, field := range []string{"request_body", "response_body"} {
+		logged := entries[0][field].(string)
+		assert.Equal(t, `{"email":"[REDACTED]","password":"[REDACTED]","refresh_token":"[REDACTED]"}`, logged)
+	}
+}
+
+func TestBodyLogger_TruncatesBodies(t *testing.T) {
+	router, _, logPath := bodyLogRouter(t, []string{"/api/v1/portfolios/:id/imports"}, 16)
+
+	body := "symbol,quantity\nAAPL,10\nMSFT,5\n"
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/portfolios/1/imports", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, body, w.Body.String())
+
+	entries := bodyLogEntries(t, logPath)
+	require.Len(t, entries, 1)
+	assert.Equal(t, body[:16], entries[0]["request_body"])
+	assert.Equal(t, true, entries[0]["request_body_truncated"])
+	assert.Equal(t, true, entries[0]["response_body_truncated"])
+}
+
+func TestBodyLogger_SelectsRoutes(t *testing.T) {
+	router, bodyLogger, logPath := bodyLogRouter(t, []string{"GET /api/v1/portfolios/:id/statement"}, 4096)
+
+	serve := func(method, path string) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader("{}")))
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	serve("POST", "/api/auth/login")
+	serve("GET", "/api/v1/portfolios/1/statement")
+	entries := bodyLogEntries(t, logPath)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "[application/pdf body omitted]", entries[0]["response_body"])
+
+	// Turning body logging off takes effect on the next request
+	bodyLogger.SetRoutes(nil, 4096)
+	serve("GET", "/api/v1/portfolios/1/statement")
+	assert.Len(t, bodyLogEntries(t, logPath), 1)
+}
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"json keys", `{"new_password": "x\"y", "api_key":"k", "symbol":"AAPL"}`, `{"new_password": "[REDACTED]", "api_key":"[REDACTED]", "symbol":"AAPL"}`},
+		{"truncated value", `{"access_token":"eyJhbGciOi`, `{"access_token":"[REDACTED]"`},
+		{"form", `email=a%40b.com&password=secret&remember=true`, `email=[REDACTED]&password=[REDACTED]&remember=true`},
+		{"email in csv", "owner,symbol\njane.doe@example.com,AAPL", "owner,symbol\n[REDACTED],AAPL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RedactBody(tt.body))
+		})
+	}
+}
@@ -1,31 +1,125 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+
 	"github.com/gin-gonic/gin"
 )
 
+// CORS presets
+const (
+	// CORSPresetDevelopment allows the listed origins and any localhost origin, with
+	// credentials
+	CORSPresetDevelopment = "development"
+	// CORSPresetProduction allows the listed origins with credentials. A "*" origin allows
+	// any other origin, but never with credentials.
+	CORSPresetProduction = "production"
+)
+
+// ErrInvalidCORSPreset is returned for CORS presets other than development and production
+var ErrInvalidCORSPreset = errors.New("CORS preset must be development or production")
+
+// corsAllowedHeaders are the request headers browsers may send cross-origin
+const corsAllowedHeaders = "Content-Type, Authorization, Accept, If-Match, " +
+	RequestIDHeader + ", " + APIKeyHeader + ", " + CSRFHeaderName
+
+// corsExposedHeaders are the response headers scripts on other origins may read
+const corsExposedHeaders = "ETag, Location, Retry-After, " + RequestIDHeader + ", " +
+	RateLimitLimitHeader + ", " + RateLimitRemainingHeader + ", " + RateLimitResetHeader + ", " +
+	APIVersionHeader
+
+// CORSPolicy decides which origins may call the API from a browser
+type CORSPolicy struct {
+	// AllowedOrigins may call the API with credentials; "*" allows every origin
+	AllowedOrigins []string
+	// AllowLocalhost also allows http://localhost and http://127.0.0.1 on any port
+	AllowLocalhost bool
+	// CredentialedWildcard sends credentials to origins only "*" allows
+	CredentialedWildcard bool
+}
+
+// NewCORSPolicy returns the policy of a preset for the listed origins. An empty preset
+// picks the development preset in the development environment and production otherwise.
+func NewCORSPolicy(preset, environment string, allowedOrigins []string) (CORSPolicy, error) {
+	if preset == "" {
+		preset = CORSPresetProduction
+		if environment == "development" {
+			preset = CORSPresetDevelopment
+		}
+	}
+
+	switch preset {
+	case CORSPresetDevelopment:
+		return CORSPolicy{AllowedOrigins: allowedOrigins, AllowLocalhost: true, CredentialedWildcard: true}, nil
+	case CORSPresetProduction:
+		return CORSPolicy{AllowedOrigins: allowedOrigins}, nil
+	default:
+		return CORSPolicy{}, fmt.Errorf("%w, got %q", ErrInvalidCORSPreset, preset)
+	}
+}
+
+// allows reports whether origin may call the API and whether it may send credentials
+func (p CORSPolicy) allows(origin string) (allowed, credentials bool) {
+	if origin == "" {
+		return false, false
+	}
+
+	wildcard := false
+	for _, allowedOrigin := range p.AllowedOrigins {
+		if origin == allowedOrigin {
+			return true, true
+		}
+		if allowedOrigin == "*" {
+			wildcard = true
+		}
+	}
+	if p.AllowLocalhost && isLocalhostOrigin(origin) {
+		return true, true
+	}
+	if wildcard {
+		return true, p.CredentialedWildcard
+	}
+	return false, false
+}
+
+// isLocalhostOrigin reports whether origin is http://localhost or http://127.0.0.1 on any port
+func isLocalhostOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme != "http" {
+		return false
+	}
+	host := u.Hostname()
+	return host == "localhost" || host == "127.0.0.1"
+}
+
 // CORS creates a CORS middleware with the specified allowed origins
 func CORS(allowedOrigins []string) gin.HandlerFunc {
+	return CORSWithPolicy(CORSPolicy{AllowedOrigins: allowedOrigins, CredentialedWildcard: true})
+}
+
+// CORSWithPolicy creates a CORS middleware that allows the origins of policy
+func CORSWithPolicy(policy CORSPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
 
 		// Check if origin is allowed
-		allowed := false
-		for _, allowedOrigin := range allowedOrigins {
-			if origin == allowedOrigin || allowedOrigin == "*" {
-				allowed = true
-				break
-			}
-		}
+		allowed, credentials := policy.allows(origin)
 
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
 		if allowed {
-			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Origin", origin)
+			if credentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
 		}
 
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Accept")
-		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
+		header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		header.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+		header.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+		header.Set("Access-Control-Max-Age", "86400")
 
 		// Handle preflight requests
 		if c.Request.Method == "OPTIONS" {
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+)
+
+const (
+	// CSRFCookieName is the cookie holding the CSRF token
+	CSRFCookieName = "csrf_token"
+	// CSRFHeaderName is the header that must repeat the CSRF cookie on unsafe requests
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// csrfTokenBytes is the number of random bytes in a CSRF token
+const csrfTokenBytes = 32
+
+// CSRFProtection creates a double-submit CSRF middleware for deployments where browsers
+// authenticate with cookies, for example behind a proxy that keeps the session. Responses
+// set a random token in a cookie scripts on the site can read, and POST, PUT, PATCH and
+// DELETE requests must send it back in the X-CSRF-Token header, which other sites can't do.
+// Requests with an Authorization or X-API-Key header are exempt, since browsers never add
+// those on their own. secureCookie marks the cookie HTTPS-only.
+func CSRFProtection(secureCookie bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader(AuthorizationHeader) != "" || c.GetHeader(APIKeyHeader) != "" {
+			c.Next()
+			return
+		}
+
+		token, err := c.Cookie(CSRFCookieName)
+		if err != nil || token == "" {
+			token, err = newCSRFToken()
+			if err != nil {
+				apierrors.Abort(c, apierrors.InternalError.WithMessage("Failed to generate CSRF token"))
+				return
+			}
+			// Issue the token; a request that arrived without one can't pass the check below
+			http.SetCookie(c.Writer, &http.Cookie{
+				Name:     CSRFCookieName,
+				Value:    token,
+				Path:     "/",
+				Secure:   secureCookie,
+				SameSite: http.SameSiteStrictMode,
+			})
+		}
+
+		if !isSafeMethod(c.Request.Method) {
+			sent := c.GetHeader(CSRFHeaderName)
+			if sent == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				apierrors.Abort(c, apierrors.CSRFTokenInvalid)
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// isSafeMethod reports whether an HTTP method is one that must not change state
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// newCSRFToken returns a random CSRF token
+func newCSRFToken() (string, error) {
+	b := make([]byte, csrfTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// OnBehalfOfHeader is the header naming the client an advisor's request acts for
+const OnBehalfOfHeader = "X-On-Behalf-Of"
+
+// DelegationResolver looks up the portfolios a client delegated to an advisor and records
+// what the advisor does with them
+type DelegationResolver interface {
+	// ResolveDelegation returns the active delegation, with its portfolios, and the schema
+	// holding the client's portfolios, or ErrDelegationNotFound if the client hasn't
+	// delegated access to the advisor
+	ResolveDelegation(ctx context.Context, advisorID, clientID string) (*models.Delegation, string, error)
+	// RecordAccess adds a request made under a delegation to its audit trail
+	RecordAccess(ctx context.Context, event *models.DelegationAuditEvent) error
+}
+
+// DelegationScope acts on behalf of the client named by the X-On-Behalf-Of header for the
+// rest of the request, giving the advisor access to the portfolios the client delegated and
+// selecting the schema they are kept in. Requests without the header act as the user alone.
+// Advisors with read access may only make read requests, and every request made on behalf
+// of a client is added to the delegation's audit trail once it has been handled. It must run
+// after authentication and OrganizationScope, and cannot be combined with an organization or
+// an API key.
+func DelegationScope(resolver DelegationResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(OnBehalfOfHeader)
+		advisorID := GetUserID(c)
+		if header == "" || advisorID == "" {
+			c.Next()
+			return
+		}
+
+		clientID, err := uuid.Parse(header)
+		if err != nil {
+			apierrors.Abort(c, apierrors.InvalidClientID)
+			return
+		}
+		ctx := c.Request.Context()
+		if _, ok := models.OrganizationScopeFromContext(ctx); ok {
+			apierrors.Abort(c, apierrors.DelegationForbidden.WithMessage("Requests on behalf of a client cannot act in an organization"))
+			return
+		}
+		if c.GetString(APIKeyOrganizationContextKey) != "" {
+			apierrors.Abort(c, apierrors.DelegationForbidden.WithMessage("Requests with an API key cannot act on behalf of a client"))
+			return
+		}
+
+		delegation, schema, err := resolver.ResolveDelegation(ctx, advisorID, clientID.String())
+		if err != nil {
+			if errors.Is(err, models.ErrDelegationNotFound) {
+				apierrors.Abort(c, apierrors.NotDelegated)
+				return
+			}
+			apierrors.Abort(c, apierrors.TenantResolutionFailed)
+			return
+		}
+		if !delegation.Access.CanWrite() && !isSafeMethod(c.Request.Method) {
+			apierrors.Abort(c, apierrors.DelegationReadOnly)
+			return
+		}
+
+		// The client's portfolios are in their schema, which may not be the advisor's own
+		ctx = database.WithSchema(ctx, schema)
+		ctx = models.WithDelegationScope(ctx, models.DelegationScope{
+			DelegationID: delegation.ID,
+			AdvisorID:    delegation.AdvisorID,
+			ClientID:     clientID,
+			Access:       delegation.Access,
+			PortfolioIDs: delegation.PortfolioIDs(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		event := &models.DelegationAuditEvent{
+			DelegationID: delegation.ID,
+			AdvisorID:    delegation.AdvisorID,
+			ClientID:     clientID,
+			Method:       c.Request.Method,
+			Route:        c.FullPath(),
+			Path:         c.Request.URL.Path,
+			Status:       c.Writer.Status(),
+			RequestID:    c.GetString(RequestIDContextKey),
+		}
+		if strings.HasPrefix(event.Route, "/api/v1/portfolios/:id") {
+			if portfolioID, err := uuid.Parse(c.Param("id")); err == nil {
+				event.PortfolioID = &portfolioID
+			}
+		}
+		// The request has been handled, so a failure to audit it can only be logged
+		if err := resolver.RecordAccess(context.WithoutCancel(ctx), event); err != nil {
+			logger.FromContext(ctx).Error().Err(err).
+				Str("delegation_id", delegation.ID.String()).
+				Msg("Failed to record delegated request")
+		}
+	}
+}
@@ -2,9 +2,10 @@ package middleware
 
 import (
 	"fmt"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 )
 
 // ErrorHandler is a middleware that catches panics and converts them to 500 errors
@@ -15,14 +16,8 @@ func ErrorHandler() gin.HandlerFunc {
 				// Log the error
 				fmt.Printf("Panic recovered: %v\n", err)
 
-				// Return standardized error response
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": "Internal server error",
-					"code":  "INTERNAL_SERVER_ERROR",
-				})
-
-				// Abort the request
-				c.Abort()
+				// Return standardized error response and abort the request
+				apierrors.Abort(c, apierrors.InternalError)
 			}
 		}()
 
@@ -38,10 +33,7 @@ func ErrorHandler() gin.HandlerFunc {
 
 			// If response wasn't already sent, send error response
 			if !c.Writer.Written() {
-				c.JSON(http.StatusInternalServerError, gin.H{
-					"error": err.Error(),
-					"code":  "REQUEST_ERROR",
-				})
+				apierrors.Respond(c, apierrors.InternalError)
 			}
 		}
 	}
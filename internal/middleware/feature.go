@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+)
+
+// FeatureChecker decides whether a feature is enabled for a user
+type FeatureChecker interface {
+	IsEnabled(ctx context.Context, name, userID string) bool
+}
+
+// FeatureRequired is a middleware that answers 404 FEATURE_DISABLED unless feature name is
+// enabled for the authenticated user, so that experimental routes can be turned on per
+// environment or user. It must run after authentication.
+func FeatureRequired(features FeatureChecker, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !features.IsEnabled(c.Request.Context(), name, GetUserID(c)) {
+			apierrors.Abort(c, apierrors.FeatureDisabled)
+			return
+		}
+
+		c.Next()
+	}
+}
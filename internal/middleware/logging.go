@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
 	"github.com/lenon/portfolios/internal/logger"
 )
 
@@ -50,6 +52,9 @@ func LoggingMiddleware(log *logger.AppLogger) gin.HandlerFunc {
 		if userIDStr != "" {
 			event = event.Str("user_id", userIDStr)
 		}
+		if requestID := GetRequestID(c); requestID != "" {
+			event = event.Str("request_id", requestID)
+		}
 
 		// Add error details if request failed
 		if statusCode >= 400 {
@@ -74,7 +79,8 @@ func ErrorLoggingMiddleware(log *logger.AppLogger) gin.HandlerFunc {
 				Err(err.Err).
 				Str("type", fmt.Sprintf("%v", err.Type)).
 				Str("path", c.Request.URL.Path).
-				Str("method", c.Request.Method)
+				Str("method", c.Request.Method).
+				Str("request_id", GetRequestID(c))
 
 			if err.Meta != nil {
 				event = event.Interface("meta", err.Meta)
@@ -94,13 +100,10 @@ func RecoveryLoggingMiddleware(log *logger.AppLogger) gin.HandlerFunc {
 					Interface("error", err).
 					Str("path", c.Request.URL.Path).
 					Str("method", c.Request.Method).
+					Str("request_id", GetRequestID(c)).
 					Msg("Panic recovered")
 
-				c.JSON(500, gin.H{
-					"error": "Internal server error",
-					"code":  "INTERNAL_ERROR",
-				})
-				c.Abort()
+				apierrors.Abort(c, apierrors.InternalError)
 			}
 		}()
 
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/cache"
+)
+
+// maintenanceKey is the cache key of the maintenance mode set through the admin API
+const maintenanceKey = "maintenance"
+
+// maintenanceRefreshInterval is how often the maintenance mode set through the admin API is
+// read from a shared store, which bounds how long other instances take to follow a change
+const maintenanceRefreshInterval = 5 * time.Second
+
+// Where the maintenance mode in effect comes from
+const (
+	MaintenanceSourceConfig = "config"
+	MaintenanceSourceAdmin  = "admin"
+)
+
+// MaintenanceState is whether maintenance mode is on and what rejected requests are told
+type MaintenanceState struct {
+	Enabled bool `json:"enabled"`
+	// Message replaces the default error message of rejected requests when set
+	Message string `json:"message,omitempty"`
+	// RetryAfter is sent as the Retry-After header of rejected requests; zero sends none
+	RetryAfter time.Duration `json:"retry_after"`
+	// Since is when maintenance mode was last turned on or off
+	Since time.Time `json:"since"`
+	// Source is MaintenanceSourceConfig or MaintenanceSourceAdmin
+	Source string `json:"-"`
+}
+
+// Maintenance rejects requests with 503 MAINTENANCE while maintenance mode is on. The
+// configuration turns it on and off, unless a mode set through the admin API overrides it.
+// With a store, the override is shared by every instance using the store, each following a
+// change within a few seconds.
+type Maintenance struct {
+	store cache.Store
+	now   func() time.Time
+
+	mu         sync.Mutex
+	configured MaintenanceState
+	override   *MaintenanceState
+	refreshed  time.Time
+}
+
+// NewMaintenance creates maintenance mode as configured. store may be nil, in which case a
+// mode set through the admin API only applies to this instance.
+func NewMaintenance(store cache.Store, enabled bool, retryAfter time.Duration) *Maintenance {
+	m := &Maintenance{store: store, now: time.Now}
+	m.Configure(enabled, retryAfter)
+	return m
+}
+
+// Configure sets the maintenance mode the configuration asks for. It is in effect while
+// no mode is set through the admin API.
+func (m *Maintenance) Configure(enabled bool, retryAfter time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if enabled != m.configured.Enabled || m.configured.Since.IsZero() {
+		m.configured.Since = m.now().UTC()
+	}
+	m.configured.Enabled = enabled
+	m.configured.RetryAfter = retryAfter
+	m.configured.Source = MaintenanceSourceConfig
+}
+
+// Configured returns the maintenance mode the configuration asks for
+func (m *Maintenance) Configured() MaintenanceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.configured
+}
+
+// Set overrides the configured maintenance mode until Clear is called
+func (m *Maintenance) Set(ctx context.Context, state MaintenanceState) error {
+	state.Since = m.now().UTC()
+	state.Source = MaintenanceSourceAdmin
+	if m.store != nil {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		if err := m.store.Set(ctx, maintenanceKey, data, 0); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.override = &state
+	m.refreshed = m.now()
+	return nil
+}
+
+// Clear removes the mode set through the admin API, returning to the configured one
+func (m *Maintenance) Clear(ctx context.Context) error {
+	if m.store != nil {
+		if err := m.store.Delete(ctx, maintenanceKey); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.override = nil
+	m.refreshed = m.now()
+	return nil
+}
+
+// State returns the maintenance mode in effect. While the store can't be read, the mode
+// last read from it stays in effect.
+func (m *Maintenance) State(ctx context.Context) MaintenanceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.store != nil && m.now().Sub(m.refreshed) >= maintenanceRefreshInterval {
+		data, err := m.store.Get(ctx, maintenanceKey)
+		switch {
+		case errors.Is(err, cache.ErrCacheMiss):
+			m.override = nil
+		case err == nil:
+			var state MaintenanceState
+			if json.Unmarshal(data, &state) == nil {
+				state.Source = MaintenanceSourceAdmin
+				m.override = &state
+			}
+		}
+		m.refreshed = m.now()
+	}
+
+	if m.override != nil {
+		return *m.override
+	}
+	return m.configured
+}
+
+// Middleware returns a middleware that rejects requests while maintenance mode is on, except
+// those whose path begins with one of exempt
+func (m *Maintenance) Middleware(exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		state := m.State(c.Request.Context())
+		if !state.Enabled {
+			c.Next()
+			return
+		}
+
+		if state.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(state.RetryAfter.Seconds()))))
+		}
+		e := apierrors.Maintenance
+		if state.Message != "" {
+			e = e.WithMessage(state.Message)
+		}
+		apierrors.Abort(c, e)
+	}
+}
@@ -1,16 +1,23 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/cache"
+	"github.com/lenon/portfolios/internal/database"
 	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/services"
-	"github.com/stretchr/testify/assert"
 )
 
 func TestAuthRequired_ValidToken(t *testing.T) {
@@ -98,6 +105,179 @@ func TestAuthRequired_InvalidToken(t *testing.T) {
 	assert.True(t, c.IsAborted())
 }
 
+// stubAPIKeyService authenticates a single known key
+type stubAPIKeyService struct {
+	key    string
+	userID uuid.UUID
+}
+
+func (s *stubAPIKeyService) Authenticate(key string) (*models.APIKey, error) {
+	if key != s.key {
+		return nil, models.ErrInvalidAPIKey
+	}
+	return &models.APIKey{UserID: s.userID}, nil
+}
+
+func TestAuthRequiredWithAPIKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokenService := services.NewTokenService("test-secret-key-for-testing")
+	apiKeys := &stubAPIKeyService{key: "pfk_valid", userID: uuid.New()}
+	jwtUserID := uuid.New().String()
+	token, err := tokenService.GenerateAccessToken(jwtUserID, 15*time.Minute)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		wantStatus int
+		wantUserID string
+	}{
+		{"valid API key", map[string]string{"X-API-Key": "pfk_valid"}, http.StatusOK, apiKeys.userID.String()},
+		{"invalid API key", map[string]string{"X-API-Key": "pfk_revoked"}, http.StatusUnauthorized, ""},
+		{"falls back to JWT", map[string]string{"Authorization": "Bearer " + token}, http.StatusOK, jwtUserID},
+		{"no credentials", map[string]string{}, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/test", nil)
+			for k, v := range tt.headers {
+				c.Request.Header.Set(k, v)
+			}
+
+			AuthRequiredWithAPIKeys(tokenService, apiKeys)(c)
+			if !c.IsAborted() {
+				c.Status(http.StatusOK)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantUserID, GetUserID(c))
+		})
+	}
+}
+
+func TestAdminTokenRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		configured string
+		header     string
+		wantStatus int
+	}{
+		{"valid token", "admin-secret", "Bearer admin-secret", http.StatusOK},
+		{"wrong token", "admin-secret", "Bearer other", http.StatusUnauthorized},
+		{"missing header", "admin-secret", "", http.StatusUnauthorized},
+		{"not configured", "", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("Authorization", tt.header)
+			}
+
+			AdminTokenRequired(tt.configured)(c)
+			if !c.IsAborted() {
+				c.Status(http.StatusOK)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+type stubUserLookup map[string]*models.User
+
+func (s stubUserLookup) FindByID(id string) (*models.User, error) {
+	if user, ok := s[id]; ok {
+		return user, nil
+	}
+	return nil, models.ErrUserNotFound
+}
+
+func TestActiveUserRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	disabledAt := time.Now()
+	users := stubUserLookup{
+		"active":   {Email: "active@example.com", Role: models.RoleUser},
+		"disabled": {Email: "disabled@example.com", Role: models.RoleUser, DisabledAt: &disabledAt},
+		"reset":    {Email: "reset@example.com", Role: models.RoleUser, PasswordResetRequired: true},
+	}
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantStatus int
+	}{
+		{"active user", "active", http.StatusOK},
+		{"disabled user", "disabled", http.StatusForbidden},
+		{"password reset required", "reset", http.StatusForbidden},
+		{"deleted user", "deleted", http.StatusUnauthorized},
+		{"not authenticated", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/test", nil)
+			if tt.userID != "" {
+				c.Set(UserIDContextKey, tt.userID)
+			}
+
+			ActiveUserRequired(users)(c)
+			if !c.IsAborted() {
+				assert.Equal(t, users[tt.userID], GetUser(c))
+				c.Status(http.StatusOK)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	users := stubUserLookup{
+		"admin":  {Email: "admin@example.com", Role: models.RoleAdmin},
+		"member": {Email: "member@example.com", Role: models.RoleUser},
+	}
+
+	tests := []struct {
+		name       string
+		userID     string
+		wantStatus int
+	}{
+		{"admin", "admin", http.StatusOK},
+		{"regular user", "member", http.StatusForbidden},
+		{"deleted user", "deleted", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/test", nil)
+			c.Set(UserIDContextKey, tt.userID)
+
+			RequireRole(users, models.RoleAdmin)(c)
+			if !c.IsAborted() {
+				c.Status(http.StatusOK)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
 func TestGetUserID_Success(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -186,6 +366,518 @@ func TestErrorHandler_NormalRequest(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("sets a deadline on the request context", func(t *testing.T) {
+		router := gin.New()
+		router.Use(RequestTimeout(time.Second))
+		router.GET("/test", func(c *gin.Context) {
+			deadline, ok := c.Request.Context().Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("expires slow requests", func(t *testing.T) {
+		router := gin.New()
+		router.Use(RequestTimeout(10 * time.Millisecond))
+		router.GET("/test", func(c *gin.Context) {
+			<-c.Request.Context().Done()
+			assert.ErrorIs(t, c.Request.Context().Err(), context.DeadlineExceeded)
+			c.Status(http.StatusGatewayTimeout)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	})
+
+	t.Run("WithoutTimeout lifts the deadline until the client disconnects", func(t *testing.T) {
+		type key struct{}
+		router := gin.New()
+		router.Use(RequestTimeout(10 * time.Millisecond))
+		router.GET("/test", func(c *gin.Context) {
+			c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), key{}, "kept"))
+			ctx, cancel := WithoutTimeout(c)
+			defer cancel()
+
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+			assert.Equal(t, "kept", ctx.Value(key{}))
+			<-c.Request.Context().Done()
+			assert.NoError(t, ctx.Err())
+			c.Status(http.StatusOK)
+		})
+
+		clientCtx, disconnect := context.WithCancel(context.Background())
+		defer disconnect()
+		var ctx context.Context
+		router.GET("/disconnect", func(c *gin.Context) {
+			var cancel context.CancelFunc
+			ctx, cancel = WithoutTimeout(c)
+			defer cancel()
+			disconnect()
+			<-ctx.Done()
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/disconnect", nil).WithContext(clientCtx))
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+
+	t.Run("zero disables the deadline", func(t *testing.T) {
+		router := gin.New()
+		router.Use(RequestTimeout(0))
+		router.GET("/test", func(c *gin.Context) {
+			_, ok := c.Request.Context().Deadline()
+			assert.False(t, ok)
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/ok", func(c *gin.Context) {
+		assert.Equal(t, GetRequestID(c), logger.RequestIDFromContext(c.Request.Context()))
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	router.GET("/fail", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Portfolio not found", "code": "PORTFOLIO_NOT_FOUND"})
+	})
+	router.GET("/empty", func(c *gin.Context) {
+		c.JSON(http.StatusBadRequest, gin.H{})
+	})
+
+	t.Run("assigns an ID to requests without one", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		_, err := uuid.Parse(w.Header().Get(RequestIDHeader))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+	})
+
+	t.Run("keeps an incoming ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ok", nil)
+		req.Header.Set(RequestIDHeader, "edge-42.a")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, "edge-42.a", w.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("replaces an unsafe incoming ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/ok", nil)
+		req.Header.Set(RequestIDHeader, "bad id\"}")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.NotEqual(t, "bad id\"}", w.Header().Get(RequestIDHeader))
+		assert.NotEmpty(t, w.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("adds the ID to error bodies", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/fail", nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"request_id":"req-1","error":"Portfolio not found","code":"PORTFOLIO_NOT_FOUND"}`, w.Body.String())
+
+		req = httptest.NewRequest("GET", "/empty", nil)
+		req.Header.Set(RequestIDHeader, "req-2")
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.JSONEq(t, `{"request_id":"req-2"}`, w.Body.String())
+	})
+}
+
+// tenantSchemaResolverStub maps user IDs to schemas
+type tenantSchemaResolverStub struct {
+	schemas map[string]string
+	err     error
+}
+
+func (r *tenantSchemaResolverStub) FindSchemaNameByUserID(userID string) (string, error) {
+	return r.schemas[userID], r.err
+}
+
+func TestTenantSchema(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(resolver TenantSchemaResolver, userID string, check func(c *gin.Context)) *gin.Engine {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if userID != "" {
+				c.Set(UserIDContextKey, userID)
+			}
+			c.Next()
+		})
+		router.Use(TenantSchema(resolver))
+		router.GET("/test", func(c *gin.Context) {
+			check(c)
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	resolver := &tenantSchemaResolverStub{schemas: map[string]string{"tenant-user": "tenant_acme"}}
+
+	t.Run("selects the organization schema", func(t *testing.T) {
+		router := newRouter(resolver, "tenant-user", func(c *gin.Context) {
+			assert.Equal(t, "tenant_acme", database.SchemaFromContext(c.Request.Context()))
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("keeps other users on the public schema", func(t *testing.T) {
+		router := newRouter(resolver, "solo-user", func(c *gin.Context) {
+			assert.Empty(t, database.SchemaFromContext(c.Request.Context()))
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("fails when the organization cannot be resolved", func(t *testing.T) {
+		failing := &tenantSchemaResolverStub{err: assert.AnError}
+		router := newRouter(failing, "tenant-user", func(c *gin.Context) {
+			t.Error("handler should not run")
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "TENANT_RESOLUTION_FAILED")
+	})
+}
+
+// organizationScopeResolverStub knows one organization and the roles of its members
+type organizationScopeResolverStub struct {
+	organization *models.Organization
+	roles        map[string]models.OrganizationRole
+	err          error
+}
+
+func (r *organizationScopeResolverStub) ResolveScope(ctx context.Context, organizationID, userID string) (*models.OrganizationMember, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	role, ok := r.roles[userID]
+	if !ok || organizationID != r.organization.ID.String() {
+		return nil, models.ErrNotOrganizationMember
+	}
+	return &models.OrganizationMember{OrganizationID: r.organization.ID, Role: role, Organization: r.organization}, nil
+}
+
+func TestOrganizationScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	schema := "tenant_acme"
+	organization := &models.Organization{ID: uuid.New(), SchemaName: &schema}
+	resolver := &organizationScopeResolverStub{
+		organization: organization,
+		roles:        map[string]models.OrganizationRole{"member": models.OrgRoleMember, "viewer": models.OrgRoleViewer},
+	}
+
+	serve := func(resolver OrganizationScopeResolver, method, userID, header, keyOrganization string) (*httptest.ResponseRecorder, context.Context) {
+		var ctx context.Context
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(UserIDContextKey, userID)
+			if keyOrganization != "" {
+				c.Set(APIKeyOrganizationContextKey, keyOrganization)
+			}
+			c.Request = c.Request.WithContext(database.WithSchema(c.Request.Context(), "tenant_own"))
+			c.Next()
+		})
+		router.Use(OrganizationScope(resolver))
+		router.Handle(method, "/test", func(c *gin.Context) {
+			ctx = c.Request.Context()
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(method, "/test", nil)
+		if header != "" {
+			req.Header.Set(OrganizationHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w, ctx
+	}
+
+	t.Run("acts in the organization and its schema", func(t *testing.T) {
+		w, ctx := serve(resolver, http.MethodPost, "member", organization.ID.String(), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		scope, ok := models.OrganizationScopeFromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, organization.ID, scope.OrganizationID)
+		assert.Equal(t, models.OrgRoleMember, scope.Role)
+		assert.Equal(t, "tenant_acme", database.SchemaFromContext(ctx))
+	})
+
+	t.Run("acts as the user alone without the header", func(t *testing.T) {
+		w, ctx := serve(resolver, http.MethodGet, "member", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		_, ok := models.OrganizationScopeFromContext(ctx)
+		assert.False(t, ok)
+		assert.Equal(t, "tenant_own", database.SchemaFromContext(ctx))
+	})
+
+	t.Run("viewers may only read", func(t *testing.T) {
+		w, _ := serve(resolver, http.MethodGet, "viewer", organization.ID.String(), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w, _ = serve(resolver, http.MethodPut, "viewer", organization.ID.String(), "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "INSUFFICIENT_ORGANIZATION_ROLE")
+	})
+
+	t.Run("rejects non-members", func(t *testing.T) {
+		w, _ := serve(resolver, http.MethodGet, "stranger", organization.ID.String(), "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "NOT_ORGANIZATION_MEMBER")
+	})
+
+	t.Run("rejects API keys from another organization", func(t *testing.T) {
+		w, _ := serve(resolver, http.MethodGet, "member", organization.ID.String(), uuid.New().String())
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w, _ = serve(resolver, http.MethodGet, "member", organization.ID.String(), organization.ID.String())
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("rejects API keys without the header", func(t *testing.T) {
+		w, ctx := serve(resolver, http.MethodGet, "member", "", organization.ID.String())
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "NOT_ORGANIZATION_MEMBER")
+		assert.Nil(t, ctx)
+	})
+
+	t.Run("rejects malformed organization IDs", func(t *testing.T) {
+		w, _ := serve(resolver, http.MethodGet, "member", "acme", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_ORGANIZATION_ID")
+	})
+
+	t.Run("fails when the membership cannot be resolved", func(t *testing.T) {
+		failing := &organizationScopeResolverStub{organization: organization, err: assert.AnError}
+		w, _ := serve(failing, http.MethodGet, "member", organization.ID.String(), "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+// delegationResolverStub resolves a single delegation and records the audit events
+type delegationResolverStub struct {
+	delegation *models.Delegation
+	schema     string
+	err        error
+	events     []*models.DelegationAuditEvent
+}
+
+func (r *delegationResolverStub) ResolveDelegation(ctx context.Context, advisorID, clientID string) (*models.Delegation, string, error) {
+	if r.err != nil {
+		return nil, "", r.err
+	}
+	if advisorID != r.delegation.AdvisorID.String() || clientID != r.delegation.ClientID.String() {
+		return nil, "", models.ErrDelegationNotFound
+	}
+	return r.delegation, r.schema, nil
+}
+
+func (r *delegationResolverStub) RecordAccess(ctx context.Context, event *models.DelegationAuditEvent) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestDelegationScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	advisorID, clientID, portfolioID := uuid.New(), uuid.New(), uuid.New()
+	newResolver := func(access models.DelegationAccess) *delegationResolverStub {
+		return &delegationResolverStub{
+			delegation: &models.Delegation{
+				ID:         uuid.New(),
+				AdvisorID:  advisorID,
+				ClientID:   &clientID,
+				Access:     access,
+				Status:     models.DelegationStatusActive,
+				Portfolios: []*models.DelegationPortfolio{{PortfolioID: portfolioID}},
+			},
+			schema: "tenant_client",
+		}
+	}
+
+	serve := func(resolver DelegationResolver, method, userID, header string, inOrganization bool, keyOrganization string) (*httptest.ResponseRecorder, context.Context) {
+		var ctx context.Context
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set(UserIDContextKey, userID)
+			c.Set(RequestIDContextKey, "req-1")
+			if keyOrganization != "" {
+				c.Set(APIKeyOrganizationContextKey, keyOrganization)
+			}
+			reqCtx := database.WithSchema(c.Request.Context(), "tenant_own")
+			if inOrganization {
+				reqCtx = models.WithOrganizationScope(reqCtx, models.OrganizationScope{OrganizationID: uuid.New()})
+			}
+			c.Request = c.Request.WithContext(reqCtx)
+			c.Next()
+		})
+		router.Use(DelegationScope(resolver))
+		router.Handle(method, "/api/v1/portfolios/:id", func(c *gin.Context) {
+			ctx = c.Request.Context()
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(method, "/api/v1/portfolios/"+portfolioID.String(), nil)
+		if header != "" {
+			req.Header.Set(OnBehalfOfHeader, header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w, ctx
+	}
+
+	t.Run("acts on behalf of the client and audits the request", func(t *testing.T) {
+		resolver := newResolver(models.DelegationAccessManage)
+		w, ctx := serve(resolver, http.MethodPut, advisorID.String(), clientID.String(), false, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		scope, ok := models.DelegationScopeFromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(t, clientID, scope.ClientID)
+		assert.True(t, scope.Grants(portfolioID))
+		assert.Equal(t, "tenant_client", database.SchemaFromContext(ctx))
+
+		require.Len(t, resolver.events, 1)
+		event := resolver.events[0]
+		assert.Equal(t, advisorID, event.AdvisorID)
+		assert.Equal(t, clientID, event.ClientID)
+		assert.Equal(t, http.MethodPut, event.Method)
+		assert.Equal(t, "/api/v1/portfolios/:id", event.Route)
+		assert.Equal(t, http.StatusOK, event.Status)
+		assert.Equal(t, "req-1", event.RequestID)
+		require.NotNil(t, event.PortfolioID)
+		assert.Equal(t, portfolioID, *event.PortfolioID)
+	})
+
+	t.Run("acts as the user alone without the header", func(t *testing.T) {
+		resolver := newResolver(models.DelegationAccessManage)
+		w, ctx := serve(resolver, http.MethodGet, advisorID.String(), "", false, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		_, ok := models.DelegationScopeFromContext(ctx)
+		assert.False(t, ok)
+		assert.Equal(t, "tenant_own", database.SchemaFromContext(ctx))
+		assert.Empty(t, resolver.events)
+	})
+
+	t.Run("read access may only read", func(t *testing.T) {
+		resolver := newResolver(models.DelegationAccessRead)
+		w, _ := serve(resolver, http.MethodGet, advisorID.String(), clientID.String(), false, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w, _ = serve(resolver, http.MethodDelete, advisorID.String(), clientID.String(), false, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "DELEGATION_READ_ONLY")
+		assert.Len(t, resolver.events, 1)
+	})
+
+	t.Run("rejects users the client hasn't delegated to", func(t *testing.T) {
+		w, _ := serve(newResolver(models.DelegationAccessManage), http.MethodGet, uuid.New().String(), clientID.String(), false, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "NOT_DELEGATED")
+	})
+
+	t.Run("rejects malformed client IDs", func(t *testing.T) {
+		w, _ := serve(newResolver(models.DelegationAccessManage), http.MethodGet, advisorID.String(), "client", false, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_CLIENT_ID")
+	})
+
+	t.Run("cannot be combined with an organization", func(t *testing.T) {
+		w, _ := serve(newResolver(models.DelegationAccessManage), http.MethodGet, advisorID.String(), clientID.String(), true, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "DELEGATION_FORBIDDEN")
+	})
+
+	t.Run("rejects API keys", func(t *testing.T) {
+		resolver := newResolver(models.DelegationAccessManage)
+		w, _ := serve(resolver, http.MethodGet, advisorID.String(), clientID.String(), false, uuid.New().String())
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "DELEGATION_FORBIDDEN")
+		assert.Empty(t, resolver.events)
+
+		w, _ = serve(resolver, http.MethodGet, advisorID.String(), "", false, uuid.New().String())
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("fails when the delegation cannot be resolved", func(t *testing.T) {
+		failing := newResolver(models.DelegationAccessManage)
+		failing.err = assert.AnError
+		w, _ := serve(failing, http.MethodGet, advisorID.String(), clientID.String(), false, "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+// unsubscribeTokenVerifierStub accepts a single token
+type unsubscribeTokenVerifierStub struct {
+	token  string
+	userID string
+}
+
+func (v *unsubscribeTokenVerifierStub) VerifyUnsubscribeToken(token string) (string, string, error) {
+	if token != v.token {
+		return "", "", models.ErrInvalidUnsubscribeToken
+	}
+	return "subscription-id", v.userID, nil
+}
+
+func TestUnsubscribeTokenRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(UnsubscribeTokenRequired(&unsubscribeTokenVerifierStub{token: "valid", userID: "token-user"}))
+	router.GET("/unsubscribe", func(c *gin.Context) {
+		c.String(http.StatusOK, GetUserID(c))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/unsubscribe?token=valid", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "token-user", w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/unsubscribe?token=forged", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_UNSUBSCRIBE_TOKEN")
+}
+
 func TestRateLimit_AllowedRequests(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -249,6 +941,145 @@ func TestRateLimit_DifferentIPs(t *testing.T) {
 	assert.False(t, c2.IsAborted())
 }
 
+func TestRateLimit_SharedStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Two limiters sharing one store behave like a single limiter across replicas
+	store := cache.NewMemoryStore()
+	replicaA := NewRateLimiterWithStore(store, 1, 1*time.Second).Middleware()
+	replicaB := NewRateLimiterWithStore(store, 1, 1*time.Second).Middleware()
+
+	w1 := httptest.NewRecorder()
+	c1, _ := gin.CreateTestContext(w1)
+	c1.Request = httptest.NewRequest("GET", "/test", nil)
+	c1.Request.RemoteAddr = "192.168.1.1:1234"
+	replicaA(c1)
+	assert.False(t, c1.IsAborted())
+
+	w2 := httptest.NewRecorder()
+	c2, _ := gin.CreateTestContext(w2)
+	c2.Request = httptest.NewRequest("GET", "/test", nil)
+	c2.Request.RemoteAddr = "192.168.1.1:1234"
+	replicaB(c2)
+	assert.True(t, c2.IsAborted())
+	assert.Equal(t, http.StatusTooManyRequests, w2.Code)
+}
+
+func TestRateLimit_PerUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Allow only 1 request per second
+	handler := RateLimit(1, 1*time.Second)
+
+	request := func(userID string) *gin.Context {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/test", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		if userID != "" {
+			c.Set(UserIDContextKey, userID)
+		}
+		handler(c)
+		return c
+	}
+
+	// Users behind the same address each get their own limit, apart from anonymous requests
+	assert.False(t, request("user-1").IsAborted())
+	assert.False(t, request("user-2").IsAborted())
+	assert.False(t, request("").IsAborted())
+	assert.True(t, request("user-1").IsAborted())
+	assert.True(t, request("").IsAborted())
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for name, limiter := range map[string]*RateLimiter{
+		"in memory":    NewRateLimiter(2, time.Minute),
+		"shared store": NewRateLimiterWithStore(cache.NewMemoryStore(), 2, time.Minute),
+	} {
+		t.Run(name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(limiter.Middleware())
+			router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var responses []*httptest.ResponseRecorder
+			for i := 0; i < 3; i++ {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+				responses = append(responses, w)
+			}
+
+			for i, want := range []string{"1", "0", "0"} {
+				assert.Equal(t, "2", responses[i].Header().Get(RateLimitLimitHeader))
+				assert.Equal(t, want, responses[i].Header().Get(RateLimitRemainingHeader))
+
+				reset, err := strconv.ParseInt(responses[i].Header().Get(RateLimitResetHeader), 10, 64)
+				require.NoError(t, err)
+				assert.InDelta(t, time.Now().Add(time.Minute).Unix(), reset, 61)
+			}
+
+			assert.Equal(t, http.StatusOK, responses[1].Code)
+			assert.Equal(t, http.StatusTooManyRequests, responses[2].Code)
+			retryAfter, err := strconv.Atoi(responses[2].Header().Get("Retry-After"))
+			require.NoError(t, err)
+			assert.Positive(t, retryAfter)
+			assert.LessOrEqual(t, retryAfter, 60)
+		})
+	}
+}
+
+func TestRateLimit_Scopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Limiters with different scopes keep separate counts on a shared store
+	store := cache.NewMemoryStore()
+	authLimiter := NewScopedRateLimiter("auth", store, 1, time.Minute).Middleware()
+	apiLimiter := NewScopedRateLimiter("api", store, 1, time.Minute).Middleware()
+
+	for _, handler := range []gin.HandlerFunc{authLimiter, apiLimiter} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/test", nil)
+		c.Set(UserIDContextKey, "user-1")
+		handler(c)
+		assert.False(t, c.IsAborted())
+	}
+}
+
+func TestRateLimit_SetLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter := NewRateLimiter(1, time.Minute)
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request().Code)
+	assert.Equal(t, http.StatusTooManyRequests, request().Code)
+
+	// A higher limit applies to the current window
+	limiter.SetLimit(3, time.Minute)
+	w := request()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "3", w.Header().Get(RateLimitLimitHeader))
+	assert.Equal(t, "1", w.Header().Get(RateLimitRemainingHeader))
+
+	// A zero limit turns the limiter off
+	limiter.SetLimit(0, time.Minute)
+	for i := 0; i < 5; i++ {
+		w := request()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get(RateLimitLimitHeader))
+	}
+}
+
 func TestLoggingMiddleware(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -309,3 +1140,188 @@ func TestRecoveryLoggingMiddleware(t *testing.T) {
 	// Should complete without crashing
 	assert.NotNil(t, handler)
 }
+
+func TestAuthRequired_AccessTokenCookie(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tokenService := services.NewTokenService("test-secret-key-for-testing")
+	userID := uuid.New().String()
+	token, err := tokenService.GenerateAccessToken(userID, 15*time.Minute)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/test", nil)
+	c.Request.AddCookie(&http.Cookie{Name: AccessTokenCookieName, Value: token})
+
+	AuthRequired(tokenService)(c)
+
+	assert.False(t, c.IsAborted())
+	assert.Equal(t, userID, GetUserID(c))
+}
+
+// maintenanceRequest runs a request to path through middleware and returns its response
+func maintenanceRequest(middleware gin.HandlerFunc, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", path, nil)
+	middleware(c)
+	if !c.IsAborted() {
+		c.Status(http.StatusOK)
+	}
+	return w
+}
+
+func TestMaintenance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := NewMaintenance(nil, true, 90*time.Second)
+	handler := m.Middleware("/health", "/api/admin/")
+
+	w := maintenanceRequest(handler, "/api/v1/portfolios")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"code":"MAINTENANCE"`)
+
+	// The health check and the admin API stay up
+	assert.Equal(t, http.StatusOK, maintenanceRequest(handler, "/health").Code)
+	assert.Equal(t, http.StatusOK, maintenanceRequest(handler, "/api/admin/v1/maintenance").Code)
+
+	// The admin API overrides the configuration until cleared
+	ctx := context.Background()
+	require.NoError(t, m.Set(ctx, MaintenanceState{Enabled: true, Message: "Upgrading the database"}))
+	w = maintenanceRequest(handler, "/api/v1/portfolios")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "Upgrading the database")
+	assert.Equal(t, MaintenanceSourceAdmin, m.State(ctx).Source)
+
+	require.NoError(t, m.Set(ctx, MaintenanceState{Enabled: false}))
+	assert.Equal(t, http.StatusOK, maintenanceRequest(handler, "/api/v1/portfolios").Code)
+
+	require.NoError(t, m.Clear(ctx))
+	assert.Equal(t, MaintenanceSourceConfig, m.State(ctx).Source)
+	assert.Equal(t, http.StatusServiceUnavailable, maintenanceRequest(handler, "/api/v1/portfolios").Code)
+
+	// Reloading the configuration turns it off
+	m.Configure(false, time.Minute)
+	assert.Equal(t, http.StatusOK, maintenanceRequest(handler, "/api/v1/portfolios").Code)
+}
+
+func TestMaintenance_SharedStore(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	// Two instances sharing one store follow each other's admin overrides once they refresh
+	now := time.Now()
+	clock := func() time.Time { return now }
+	store := cache.NewMemoryStore()
+	replicaA := NewMaintenance(store, false, time.Minute)
+	replicaB := NewMaintenance(store, false, time.Minute)
+	replicaA.now, replicaB.now = clock, clock
+	assert.False(t, replicaB.State(ctx).Enabled)
+
+	require.NoError(t, replicaA.Set(ctx, MaintenanceState{Enabled: true, RetryAfter: time.Minute}))
+	assert.True(t, replicaA.State(ctx).Enabled)
+	assert.False(t, replicaB.State(ctx).Enabled, "instances read the store every few seconds")
+
+	now = now.Add(maintenanceRefreshInterval)
+	state := replicaB.State(ctx)
+	assert.True(t, state.Enabled)
+	assert.Equal(t, time.Minute, state.RetryAfter)
+	assert.Equal(t, MaintenanceSourceAdmin, state.Source)
+
+	require.NoError(t, replicaB.Clear(ctx))
+	now = now.Add(maintenanceRefreshInterval)
+	assert.False(t, replicaA.State(ctx).Enabled)
+	assert.Equal(t, MaintenanceSourceConfig, replicaA.State(ctx).Source)
+}
+
+func TestAPIVersion(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		accept      string
+		wantStatus  int
+		wantVersion string
+	}{
+		{"no accept header", "", http.StatusOK, "1"},
+		{"plain json", "application/json", http.StatusOK, "1"},
+		{"vendor type", "application/vnd.portfolios.v1+json", http.StatusOK, "1"},
+		{"vendor type without version", "application/vnd.portfolios+json", http.StatusOK, "1"},
+		{"highest supported version", "application/vnd.portfolios.v9+json, application/vnd.portfolios.v1+json; q=0.5", http.StatusOK, "1"},
+		{"unsupported version", "application/vnd.portfolios.v9+json", http.StatusNotAcceptable, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/portfolios", nil)
+			if tt.accept != "" {
+				c.Request.Header.Set("Accept", tt.accept)
+			}
+
+			APIVersion(1)(c)
+			if !c.IsAborted() {
+				assert.Equal(t, 1, GetAPIVersion(c))
+				c.Status(http.StatusOK)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantVersion, w.Header().Get(APIVersionHeader))
+			if tt.wantStatus == http.StatusNotAcceptable {
+				assert.Contains(t, w.Body.String(), "UNSUPPORTED_API_VERSION")
+			}
+		})
+	}
+}
+
+// stubFeatureChecker enables the features listed for each user
+type stubFeatureChecker map[string][]string
+
+func (s stubFeatureChecker) IsEnabled(_ context.Context, name, userID string) bool {
+	for _, enabled := range s[userID] {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestFeatureRequired(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	features := stubFeatureChecker{"tester": {models.FeatureSimulations}}
+
+	tests := []struct {
+		name       string
+		userID     string
+		feature    string
+		wantStatus int
+	}{
+		{"enabled for user", "tester", models.FeatureSimulations, http.StatusOK},
+		{"disabled for user", "tester", models.FeatureWhatIf, http.StatusNotFound},
+		{"disabled for other users", "other", models.FeatureSimulations, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/test", nil)
+			c.Set(UserIDContextKey, tt.userID)
+
+			FeatureRequired(features, tt.feature)(c)
+			if !c.IsAborted() {
+				c.Status(http.StatusOK)
+			}
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusNotFound {
+				assert.Contains(t, w.Body.String(), "FEATURE_DISABLED")
+			}
+		})
+	}
+}
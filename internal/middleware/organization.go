@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// OrganizationHeader is the header selecting the organization a request acts in
+const OrganizationHeader = "X-Organization-ID"
+
+// OrganizationScopeResolver looks up a user's membership of an organization
+type OrganizationScopeResolver interface {
+	// ResolveScope returns the membership, with the organization, or
+	// ErrNotOrganizationMember if the user doesn't belong to the organization
+	ResolveScope(ctx context.Context, organizationID, userID string) (*models.OrganizationMember, error)
+}
+
+// OrganizationScope acts in the organization named by the X-Organization-ID header for the
+// rest of the request, giving access to the organization's portfolios and selecting the
+// schema they are kept in. Requests without the header act as the user alone. Viewers may
+// only make read requests in an organization, and a request authenticated with an API key
+// must send the header of the organization the key was issued in. It must run after
+// authentication and TenantSchema.
+func OrganizationScope(resolver OrganizationScopeResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader(OrganizationHeader)
+		userID := GetUserID(c)
+		keyOrganization := c.GetString(APIKeyOrganizationContextKey)
+		if header == "" && keyOrganization != "" {
+			apierrors.Abort(c, apierrors.NotOrganizationMember.WithMessage("Requests with this API key must act in the organization it was issued in"))
+			return
+		}
+		if header == "" || userID == "" {
+			c.Next()
+			return
+		}
+
+		organizationID, err := uuid.Parse(header)
+		if err != nil {
+			apierrors.Abort(c, apierrors.InvalidOrganizationID)
+			return
+		}
+		if keyOrganization != "" && keyOrganization != organizationID.String() {
+			apierrors.Abort(c, apierrors.NotOrganizationMember.WithMessage("This API key was issued in a different organization"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		member, err := resolver.ResolveScope(ctx, organizationID.String(), userID)
+		if err != nil {
+			if errors.Is(err, models.ErrNotOrganizationMember) {
+				apierrors.Abort(c, apierrors.NotOrganizationMember)
+				return
+			}
+			apierrors.Abort(c, apierrors.TenantResolutionFailed)
+			return
+		}
+		if !member.Role.CanWrite() && !isSafeMethod(c.Request.Method) {
+			apierrors.Abort(c, apierrors.InsufficientOrgRole)
+			return
+		}
+
+		// The organization's portfolios are in its schema, which may not be the user's own
+		schema := ""
+		if member.Organization != nil && member.Organization.SchemaName != nil {
+			schema = *member.Organization.SchemaName
+		}
+		ctx = database.WithSchema(ctx, schema)
+		ctx = models.WithOrganizationScope(ctx, models.OrganizationScope{OrganizationID: organizationID, Role: member.Role})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
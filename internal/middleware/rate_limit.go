@@ -1,33 +1,80 @@
 package middleware
 
 import (
-	"net/http"
+	"context"
+	"fmt"
+	"math"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/cache"
+)
+
+// rateLimitKeyPrefix is the cache key prefix for rate limit counters
+const rateLimitKeyPrefix = "ratelimit:"
+
+// Rate limit response headers
+const (
+	// RateLimitLimitHeader is the number of requests allowed per window
+	RateLimitLimitHeader = "X-RateLimit-Limit"
+	// RateLimitRemainingHeader is the number of requests left in the current window
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	// RateLimitResetHeader is when the current window ends, in Unix seconds
+	RateLimitResetHeader = "X-RateLimit-Reset"
 )
 
-// RateLimiter manages rate limiting for requests
+// RateLimiter manages rate limiting for requests. Requests are counted per user once the
+// request is authenticated and per client IP before, so users behind a shared address don't
+// exhaust each other's limit.
 type RateLimiter struct {
 	requests map[string]*clientRequests
 	mu       sync.RWMutex
+	scope    string
 	limit    int
 	window   time.Duration
+	store    cache.Store
 }
 
-// clientRequests tracks requests for a specific client IP
+// clientRequests tracks requests for a specific user or client IP
 type clientRequests struct {
 	count     int
 	firstSeen time.Time
 }
 
+// rateLimitDecision is the outcome of counting a request
+type rateLimitDecision struct {
+	allowed   bool
+	limit     int // Zero when the limiter is off
+	remaining int
+	reset     time.Time
+}
+
 // NewRateLimiter creates a new rate limiter
 func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return NewScopedRateLimiter("", nil, limit, window)
+}
+
+// NewRateLimiterWithStore creates a rate limiter whose counters live in a shared cache store,
+// so limits are enforced across multiple API replicas. If the store becomes unavailable,
+// the limiter falls back to its in-memory counters.
+func NewRateLimiterWithStore(store cache.Store, limit int, window time.Duration) *RateLimiter {
+	return NewScopedRateLimiter("", store, limit, window)
+}
+
+// NewScopedRateLimiter creates a rate limiter whose counters are kept apart from those of
+// limiters with other scopes, so that, for example, the authentication endpoints and the
+// rest of the API can have separate limits on one store. store may be nil.
+func NewScopedRateLimiter(scope string, store cache.Store, limit int, window time.Duration) *RateLimiter {
 	rl := &RateLimiter{
 		requests: make(map[string]*clientRequests),
+		scope:    scope,
 		limit:    limit,
 		window:   window,
+		store:    store,
 	}
 
 	// Start cleanup goroutine
@@ -36,6 +83,15 @@ func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
 	return rl
 }
 
+// SetLimit changes the number of requests allowed per window while the limiter is in use;
+// a limit of zero turns it off. Counts already made carry over to the new limit.
+func (rl *RateLimiter) SetLimit(limit int, window time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.limit = limit
+	rl.window = window
+}
+
 // cleanupExpiredEntries removes expired entries from the rate limiter
 func (rl *RateLimiter) cleanupExpiredEntries() {
 	ticker := time.NewTicker(1 * time.Minute)
@@ -44,28 +100,37 @@ func (rl *RateLimiter) cleanupExpiredEntries() {
 	for range ticker.C {
 		rl.mu.Lock()
 		now := time.Now()
-		for ip, req := range rl.requests {
+		for key, req := range rl.requests {
 			if now.Sub(req.firstSeen) > rl.window {
-				delete(rl.requests, ip)
+				delete(rl.requests, key)
 			}
 		}
 		rl.mu.Unlock()
 	}
 }
 
-// Middleware returns a Gin middleware handler for rate limiting
+// Middleware returns a Gin middleware handler for rate limiting. Every response carries the
+// X-RateLimit-* headers, and rejected requests also carry Retry-After. Requests pass through
+// untouched while the limit is zero.
 func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get client IP
-		clientIP := c.ClientIP()
-
-		// Check rate limit
-		if !rl.allowRequest(clientIP) {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded. Please try again later.",
-				"code":  "RATE_LIMIT_EXCEEDED",
-			})
-			c.Abort()
+		decision := rl.allowRequest(rateLimitClientKey(c))
+		if decision.limit <= 0 {
+			c.Next()
+			return
+		}
+
+		c.Header(RateLimitLimitHeader, strconv.Itoa(decision.limit))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(decision.remaining))
+		c.Header(RateLimitResetHeader, strconv.FormatInt(decision.reset.Unix(), 10))
+
+		if !decision.allowed {
+			retryAfter := int(math.Ceil(time.Until(decision.reset).Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			apierrors.Abort(c, apierrors.RateLimitExceeded)
 			return
 		}
 
@@ -73,39 +138,78 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 	}
 }
 
-// allowRequest checks if a request from the given IP should be allowed
-func (rl *RateLimiter) allowRequest(clientIP string) bool {
+// rateLimitClientKey identifies who a request counts against: the authenticated user if
+// there is one, otherwise the client IP
+func rateLimitClientKey(c *gin.Context) string {
+	if userID := c.GetString(UserIDContextKey); userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// allowRequest counts a request against the client's limit
+func (rl *RateLimiter) allowRequest(clientKey string) rateLimitDecision {
+	if rl.store != nil {
+		rl.mu.RLock()
+		limit, window := rl.limit, rl.window
+		rl.mu.RUnlock()
+
+		// Shared counters use fixed windows, so every replica agrees on when one ends
+		if limit > 0 {
+			now := time.Now()
+			windowStart := now.Truncate(window)
+			key := fmt.Sprintf("%s%s:%s:%d", rateLimitKeyPrefix, rl.scope, clientKey, windowStart.Unix())
+			count, err := rl.store.Incr(context.Background(), key, window)
+			if err == nil {
+				return rateLimitDecision{
+					allowed:   count <= int64(limit),
+					limit:     limit,
+					remaining: max(limit-int(count), 0),
+					reset:     windowStart.Add(window),
+				}
+			}
+		}
+		// Store unavailable, fall back to in-memory counters
+	}
+
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
+	limit, window := rl.limit, rl.window
+	if limit <= 0 {
+		return rateLimitDecision{allowed: true}
+	}
 	now := time.Now()
 
 	// Get or create client request record
-	req, exists := rl.requests[clientIP]
+	req, exists := rl.requests[clientKey]
 	if !exists {
-		rl.requests[clientIP] = &clientRequests{
+		req = &clientRequests{
 			count:     1,
 			firstSeen: now,
 		}
-		return true
+		rl.requests[clientKey] = req
+		return rateLimitDecision{allowed: true, limit: limit, remaining: max(limit-1, 0), reset: now.Add(window)}
 	}
 
 	// Check if the time window has passed
-	if now.Sub(req.firstSeen) > rl.window {
+	if now.Sub(req.firstSeen) > window {
 		// Reset the counter for a new window
 		req.count = 1
 		req.firstSeen = now
-		return true
+		return rateLimitDecision{allowed: true, limit: limit, remaining: max(limit-1, 0), reset: now.Add(window)}
 	}
 
+	reset := req.firstSeen.Add(window)
+
 	// Check if limit is exceeded
-	if req.count >= rl.limit {
-		return false
+	if req.count >= limit {
+		return rateLimitDecision{allowed: false, limit: limit, remaining: 0, reset: reset}
 	}
 
 	// Increment counter and allow request
 	req.count++
-	return true
+	return rateLimitDecision{allowed: true, limit: limit, remaining: limit - req.count, reset: reset}
 }
 
 // RateLimit creates a rate limiting middleware with the specified limits
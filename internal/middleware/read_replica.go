@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/database"
+)
+
+// ReadReplica lets the rest of the request read from the database's read replica, if one
+// is configured. It is meant for read-only endpoints that can tolerate replication lag.
+func ReadReplica() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(database.ContextWithReadReplica(c.Request.Context()))
+		c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+const (
+	// RequestIDHeader carries the request ID on requests and responses
+	RequestIDHeader = "X-Request-ID"
+	// RequestIDContextKey is the Gin context key holding the request ID
+	RequestIDContextKey = "request_id"
+)
+
+// requestIDPattern matches incoming request IDs that are safe to log and echo back
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID assigns each request an ID, or keeps the one a proxy or client sent in the
+// X-Request-ID header. The ID is returned in the response header, added to JSON error
+// bodies as request_id so users can quote it in bug reports, and carried by the request
+// context so that logger.FromContext tags service log entries with it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(RequestIDContextKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), requestID))
+		c.Writer = &requestIDWriter{ResponseWriter: c.Writer, requestID: requestID}
+
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID RequestID assigned to the request, or "" if it didn't run
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDContextKey)
+}
+
+// requestIDWriter adds the request ID to JSON error responses, so that every handler's
+// error body carries it without each one having to set it
+type requestIDWriter struct {
+	gin.ResponseWriter
+	requestID string
+}
+
+func (w *requestIDWriter) Write(data []byte) (int, error) {
+	if w.Status() < 400 ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") ||
+		!bytes.HasPrefix(data, []byte("{")) ||
+		bytes.Contains(data, []byte(`"request_id":`)) {
+		return w.ResponseWriter.Write(data)
+	}
+
+	field, err := json.Marshal(w.requestID)
+	if err != nil {
+		return w.ResponseWriter.Write(data)
+	}
+
+	body := make([]byte, 0, len(data)+len(field)+16)
+	body = append(body, `{"request_id":`...)
+	body = append(body, field...)
+	if rest := bytes.TrimSpace(data[1:]); !bytes.HasPrefix(rest, []byte("}")) {
+		body = append(body, ',')
+	}
+	body = append(body, data[1:]...)
+
+	if _, err := w.ResponseWriter.Write(body); err != nil {
+		return 0, err
+	}
+	// Callers only know about the body they passed in
+	return len(data), nil
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// UserContextKey is the context key for the authenticated user loaded by ActiveUserRequired
+const UserContextKey = "user"
+
+// UserLookup finds the account behind an authenticated request
+type UserLookup interface {
+	FindByID(id string) (*models.User, error)
+}
+
+// ActiveUserRequired is a middleware that rejects requests from users whose account is
+// disabled or locked pending a forced password reset, so that locking an account takes effect
+// on the access tokens and API keys already issued. It must run after authentication.
+func ActiveUserRequired(users UserLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadUser(c, users)
+		if !ok {
+			return
+		}
+
+		switch {
+		case user.IsDisabled():
+			apierrors.Abort(c, apierrors.AccountDisabled)
+			return
+		case user.PasswordResetRequired:
+			apierrors.Abort(c, apierrors.PasswordResetRequired)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole is a middleware that only admits users with the given role. It must run after
+// authentication, and reuses the user loaded by ActiveUserRequired when that ran first.
+func RequireRole(users UserLookup, role models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := loadUser(c, users)
+		if !ok {
+			return
+		}
+
+		if user.Role != role {
+			apierrors.Abort(c, apierrors.Forbidden)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetUser returns the authenticated user loaded by ActiveUserRequired or RequireRole, or nil
+func GetUser(c *gin.Context) *models.User {
+	if user, exists := c.Get(UserContextKey); exists {
+		if u, ok := user.(*models.User); ok {
+			return u
+		}
+	}
+	return nil
+}
+
+// loadUser returns the authenticated user, caching it on the context. It aborts the request
+// and returns false when there is no such user.
+func loadUser(c *gin.Context, users UserLookup) (*models.User, bool) {
+	if user := GetUser(c); user != nil {
+		return user, true
+	}
+
+	userID := GetUserID(c)
+	if userID == "" {
+		apierrors.Abort(c, apierrors.NotAuthenticated)
+		return nil, false
+	}
+
+	user, err := users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			// The account was deleted after the token was issued
+			apierrors.Abort(c, apierrors.InvalidToken.WithMessage("User no longer exists"))
+		} else {
+			apierrors.Abort(c, apierrors.InternalError.WithMessage("Failed to load user"))
+		}
+		return nil, false
+	}
+
+	c.Set(UserContextKey, user)
+	return user, true
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultContentSecurityPolicy lets responses load nothing but their own inline styles,
+// which is all the HTML statements need, and keeps them out of frames
+const DefaultContentSecurityPolicy = "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+
+// SecurityHeadersConfig configures the headers SecurityHeaders sends
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge is how long browsers should only use HTTPS for the host; zero leaves
+	// Strict-Transport-Security out
+	HSTSMaxAge time.Duration
+	// ContentSecurityPolicy is sent as Content-Security-Policy; empty leaves it out
+	ContentSecurityPolicy string
+}
+
+// SecurityHeaders creates a middleware that sets headers hardening browsers' handling of
+// responses: no content sniffing, no framing and no referrers, plus HSTS and a content
+// security policy when configured
+func SecurityHeaders(cfg SecurityHeadersConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(cfg.HSTSMaxAge.Seconds()), 10) + "; includeSubDomains"
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", "DENY")
+		header.Set("Referrer-Policy", "no-referrer")
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if cfg.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+
+		c.Next()
+	}
+}
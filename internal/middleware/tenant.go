@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+	"github.com/lenon/portfolios/internal/database"
+)
+
+// TenantSchemaResolver looks up the Postgres schema holding a user's portfolio data
+type TenantSchemaResolver interface {
+	// FindSchemaNameByUserID returns the schema of the user's organization, or "" for the
+	// public schema
+	FindSchemaNameByUserID(userID string) (string, error)
+}
+
+// TenantSchema selects the schema of the authenticated user's organization for the rest of
+// the request, so that repositories called with the request context read and write that
+// organization's tables. It must run after authentication. Users outside an organization
+// with its own schema keep using the public schema.
+func TenantSchema(resolver TenantSchemaResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := GetUserID(c)
+		if userID == "" {
+			c.Next()
+			return
+		}
+
+		schema, err := resolver.FindSchemaNameByUserID(userID)
+		if err != nil {
+			apierrors.Abort(c, apierrors.TenantResolutionFailed)
+			return
+		}
+
+		if schema != "" {
+			c.Request = c.Request.WithContext(database.WithSchema(c.Request.Context(), schema))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// untimedContextKey holds a request's context from before RequestTimeout gave it a deadline
+const untimedContextKey = "untimed_request_context"
+
+// RequestTimeout gives each request's context a deadline, so that services called with it
+// abandon slow database and provider calls instead of holding a connection after the
+// client has given up. A timeout of zero or less leaves requests unbounded. Server-sent
+// event streams stay open for as long as what they report on, so requests that accept
+// text/event-stream are not given a deadline.
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+			c.Next()
+			return
+		}
+
+		c.Set(untimedContextKey, c.Request.Context())
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// WithoutTimeout returns a context for work that takes as long as it takes, such as a streamed
+// download whose headers have already been sent. It carries the request context's values but
+// not the deadline RequestTimeout gave it, and is cancelled only once the client disconnects.
+// The cancel function must be called when the work is done.
+func WithoutTimeout(c *gin.Context) (context.Context, context.CancelFunc) {
+	untimed := c.Request.Context()
+	if value, ok := c.Get(untimedContextKey); ok {
+		untimed = value.(context.Context)
+	}
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(c.Request.Context()))
+	stop := context.AfterFunc(untimed, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
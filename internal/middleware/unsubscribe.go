@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/apierrors"
+)
+
+// UnsubscribeTokenVerifier checks the signed tokens in report digest unsubscribe links
+type UnsubscribeTokenVerifier interface {
+	VerifyUnsubscribeToken(token string) (subscriptionID, userID string, err error)
+}
+
+// UnsubscribeTokenRequired is a middleware that admits requests carrying a valid unsubscribe
+// token in the token query parameter and attaches the user it names to the context, so that
+// unsubscribe links work from an email without signing in. Chain TenantSchema after it to
+// reach the user's organization schema.
+func UnsubscribeTokenRequired(verifier UnsubscribeTokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_, userID, err := verifier.VerifyUnsubscribeToken(c.Query("token"))
+		if err != nil {
+			apierrors.Abort(c, apierrors.InvalidUnsubscribeToken)
+			return
+		}
+
+		c.Set(UserIDContextKey, userID)
+		c.Next()
+	}
+}
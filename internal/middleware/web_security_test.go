@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func corsResponse(t *testing.T, policy CORSPolicy, origin string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/test", nil)
+	c.Request.Header.Set("Origin", origin)
+
+	CORSWithPolicy(policy)(c)
+	return w
+}
+
+func TestNewCORSPolicy_DefaultsToEnvironment(t *testing.T) {
+	policy, err := NewCORSPolicy("", "development", nil)
+	require.NoError(t, err)
+	assert.True(t, policy.AllowLocalhost)
+
+	policy, err = NewCORSPolicy("", "production", nil)
+	require.NoError(t, err)
+	assert.False(t, policy.AllowLocalhost)
+	assert.False(t, policy.CredentialedWildcard)
+
+	_, err = NewCORSPolicy("staging", "production", nil)
+	assert.ErrorIs(t, err, ErrInvalidCORSPreset)
+}
+
+func TestCORSWithPolicy_DevelopmentAllowsLocalhost(t *testing.T) {
+	policy, err := NewCORSPolicy(CORSPresetDevelopment, "", nil)
+	require.NoError(t, err)
+
+	w := corsResponse(t, policy, "http://localhost:8080")
+	assert.Equal(t, "http://localhost:8080", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = corsResponse(t, policy, "https://evil.com")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSWithPolicy_ProductionWildcardWithoutCredentials(t *testing.T) {
+	policy, err := NewCORSPolicy(CORSPresetProduction, "", []string{"https://app.example.com", "*"})
+	require.NoError(t, err)
+
+	w := corsResponse(t, policy, "https://app.example.com")
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = corsResponse(t, policy, "https://other.example.com")
+	assert.Equal(t, "https://other.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	// Localhost is only allowed by the development preset
+	policy, err = NewCORSPolicy(CORSPresetProduction, "", []string{"https://app.example.com"})
+	require.NoError(t, err)
+	w = corsResponse(t, policy, "http://localhost:5173")
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/test", nil)
+
+	SecurityHeaders(SecurityHeadersConfig{
+		HSTSMaxAge:            24 * time.Hour,
+		ContentSecurityPolicy: DefaultContentSecurityPolicy,
+	})(c)
+
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	assert.Equal(t, "max-age=86400; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, DefaultContentSecurityPolicy, w.Header().Get("Content-Security-Policy"))
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/test", nil)
+
+	SecurityHeaders(SecurityHeadersConfig{})(c)
+
+	assert.Empty(t, w.Header().Get("Strict-Transport-Security"))
+	assert.Empty(t, w.Header().Get("Content-Security-Policy"))
+}
+
+func setupCSRFRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CSRFProtection(true))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestCSRFProtection_IssuesToken(t *testing.T) {
+	router := setupCSRFRouter()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	cookies := w.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, CSRFCookieName, cookies[0].Name)
+	assert.NotEmpty(t, cookies[0].Value)
+	assert.True(t, cookies[0].Secure)
+	assert.Equal(t, http.SameSiteStrictMode, cookies[0].SameSite)
+}
+
+func TestCSRFProtection_RequiresMatchingHeader(t *testing.T) {
+	router := setupCSRFRouter()
+	cookie := &http.Cookie{Name: CSRFCookieName, Value: "token"}
+
+	// Missing header
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/test", nil)
+	req.AddCookie(cookie)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "CSRF_TOKEN_INVALID")
+
+	// Mismatched header
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/test", nil)
+	req.AddCookie(cookie)
+	req.Header.Set(CSRFHeaderName, "other")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Matching header
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/test", nil)
+	req.AddCookie(cookie)
+	req.Header.Set(CSRFHeaderName, "token")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestCSRFProtection_SkipsHeaderAuthentication(t *testing.T) {
+	router := setupCSRFRouter()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set(AuthorizationHeader, BearerPrefix+"token")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set(APIKeyHeader, "key")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
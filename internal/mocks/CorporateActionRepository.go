@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/lenon/portfolios/internal/models"
 	mock "github.com/stretchr/testify/mock"
 
@@ -22,17 +24,17 @@ func (_m *CorporateActionRepository) EXPECT() *CorporateActionRepository_Expecte
 	return &CorporateActionRepository_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: action
-func (_m *CorporateActionRepository) Create(action *models.CorporateAction) error {
-	ret := _m.Called(action)
+// Create provides a mock function with given fields: ctx, action
+func (_m *CorporateActionRepository) Create(ctx context.Context, action *models.CorporateAction) error {
+	ret := _m.Called(ctx, action)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.CorporateAction) error); ok {
-		r0 = rf(action)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.CorporateAction) error); ok {
+		r0 = rf(ctx, action)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -46,14 +48,15 @@ type CorporateActionRepository_Create_Call struct {
 }
 
 // Create is a helper method to define mock.On call
+//   - ctx context.Context
 //   - action *models.CorporateAction
-func (_e *CorporateActionRepository_Expecter) Create(action interface{}) *CorporateActionRepository_Create_Call {
-	return &CorporateActionRepository_Create_Call{Call: _e.mock.On("Create", action)}
+func (_e *CorporateActionRepository_Expecter) Create(ctx interface{}, action interface{}) *CorporateActionRepository_Create_Call {
+	return &CorporateActionRepository_Create_Call{Call: _e.mock.On("Create", ctx, action)}
 }
 
-func (_c *CorporateActionRepository_Create_Call) Run(run func(action *models.CorporateAction)) *CorporateActionRepository_Create_Call {
+func (_c *CorporateActionRepository_Create_Call) Run(run func(ctx context.Context, action *models.CorporateAction)) *CorporateActionRepository_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.CorporateAction))
+		run(args[0].(context.Context), args[1].(*models.CorporateAction))
 	})
 	return _c
 }
@@ -63,22 +66,22 @@ func (_c *CorporateActionRepository_Create_Call) Return(_a0 error) *CorporateAct
 	return _c
 }
 
-func (_c *CorporateActionRepository_Create_Call) RunAndReturn(run func(*models.CorporateAction) error) *CorporateActionRepository_Create_Call {
+func (_c *CorporateActionRepository_Create_Call) RunAndReturn(run func(context.Context, *models.CorporateAction) error) *CorporateActionRepository_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *CorporateActionRepository) Delete(id string) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, id
+func (_m *CorporateActionRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -92,14 +95,15 @@ type CorporateActionRepository_Delete_Call struct {
 }
 
 // Delete is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *CorporateActionRepository_Expecter) Delete(id interface{}) *CorporateActionRepository_Delete_Call {
-	return &CorporateActionRepository_Delete_Call{Call: _e.mock.On("Delete", id)}
+func (_e *CorporateActionRepository_Expecter) Delete(ctx interface{}, id interface{}) *CorporateActionRepository_Delete_Call {
+	return &CorporateActionRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
 }
 
-func (_c *CorporateActionRepository_Delete_Call) Run(run func(id string)) *CorporateActionRepository_Delete_Call {
+func (_c *CorporateActionRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *CorporateActionRepository_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -109,14 +113,14 @@ func (_c *CorporateActionRepository_Delete_Call) Return(_a0 error) *CorporateAct
 	return _c
 }
 
-func (_c *CorporateActionRepository_Delete_Call) RunAndReturn(run func(string) error) *CorporateActionRepository_Delete_Call {
+func (_c *CorporateActionRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *CorporateActionRepository_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByID provides a mock function with given fields: id
-func (_m *CorporateActionRepository) FindByID(id string) (*models.CorporateAction, error) {
-	ret := _m.Called(id)
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *CorporateActionRepository) FindByID(ctx context.Context, id string) (*models.CorporateAction, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByID")
@@ -124,19 +128,19 @@ func (_m *CorporateActionRepository) FindByID(id string) (*models.CorporateActio
 
 	var r0 *models.CorporateAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*models.CorporateAction, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.CorporateAction, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *models.CorporateAction); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.CorporateAction); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.CorporateAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -150,14 +154,15 @@ type CorporateActionRepository_FindByID_Call struct {
 }
 
 // FindByID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *CorporateActionRepository_Expecter) FindByID(id interface{}) *CorporateActionRepository_FindByID_Call {
-	return &CorporateActionRepository_FindByID_Call{Call: _e.mock.On("FindByID", id)}
+func (_e *CorporateActionRepository_Expecter) FindByID(ctx interface{}, id interface{}) *CorporateActionRepository_FindByID_Call {
+	return &CorporateActionRepository_FindByID_Call{Call: _e.mock.On("FindByID", ctx, id)}
 }
 
-func (_c *CorporateActionRepository_FindByID_Call) Run(run func(id string)) *CorporateActionRepository_FindByID_Call {
+func (_c *CorporateActionRepository_FindByID_Call) Run(run func(ctx context.Context, id string)) *CorporateActionRepository_FindByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -167,14 +172,14 @@ func (_c *CorporateActionRepository_FindByID_Call) Return(_a0 *models.CorporateA
 	return _c
 }
 
-func (_c *CorporateActionRepository_FindByID_Call) RunAndReturn(run func(string) (*models.CorporateAction, error)) *CorporateActionRepository_FindByID_Call {
+func (_c *CorporateActionRepository_FindByID_Call) RunAndReturn(run func(context.Context, string) (*models.CorporateAction, error)) *CorporateActionRepository_FindByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindBySymbol provides a mock function with given fields: symbol
-func (_m *CorporateActionRepository) FindBySymbol(symbol string) ([]*models.CorporateAction, error) {
-	ret := _m.Called(symbol)
+// FindBySymbol provides a mock function with given fields: ctx, symbol
+func (_m *CorporateActionRepository) FindBySymbol(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	ret := _m.Called(ctx, symbol)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindBySymbol")
@@ -182,19 +187,19 @@ func (_m *CorporateActionRepository) FindBySymbol(symbol string) ([]*models.Corp
 
 	var r0 []*models.CorporateAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.CorporateAction, error)); ok {
-		return rf(symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.CorporateAction, error)); ok {
+		return rf(ctx, symbol)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.CorporateAction); ok {
-		r0 = rf(symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.CorporateAction); ok {
+		r0 = rf(ctx, symbol)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.CorporateAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(symbol)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, symbol)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -208,14 +213,15 @@ type CorporateActionRepository_FindBySymbol_Call struct {
 }
 
 // FindBySymbol is a helper method to define mock.On call
+//   - ctx context.Context
 //   - symbol string
-func (_e *CorporateActionRepository_Expecter) FindBySymbol(symbol interface{}) *CorporateActionRepository_FindBySymbol_Call {
-	return &CorporateActionRepository_FindBySymbol_Call{Call: _e.mock.On("FindBySymbol", symbol)}
+func (_e *CorporateActionRepository_Expecter) FindBySymbol(ctx interface{}, symbol interface{}) *CorporateActionRepository_FindBySymbol_Call {
+	return &CorporateActionRepository_FindBySymbol_Call{Call: _e.mock.On("FindBySymbol", ctx, symbol)}
 }
 
-func (_c *CorporateActionRepository_FindBySymbol_Call) Run(run func(symbol string)) *CorporateActionRepository_FindBySymbol_Call {
+func (_c *CorporateActionRepository_FindBySymbol_Call) Run(run func(ctx context.Context, symbol string)) *CorporateActionRepository_FindBySymbol_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -225,14 +231,14 @@ func (_c *CorporateActionRepository_FindBySymbol_Call) Return(_a0 []*models.Corp
 	return _c
 }
 
-func (_c *CorporateActionRepository_FindBySymbol_Call) RunAndReturn(run func(string) ([]*models.CorporateAction, error)) *CorporateActionRepository_FindBySymbol_Call {
+func (_c *CorporateActionRepository_FindBySymbol_Call) RunAndReturn(run func(context.Context, string) ([]*models.CorporateAction, error)) *CorporateActionRepository_FindBySymbol_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindBySymbolAndDateRange provides a mock function with given fields: symbol, startDate, endDate
-func (_m *CorporateActionRepository) FindBySymbolAndDateRange(symbol string, startDate time.Time, endDate time.Time) ([]*models.CorporateAction, error) {
-	ret := _m.Called(symbol, startDate, endDate)
+// FindBySymbolAndDateRange provides a mock function with given fields: ctx, symbol, startDate, endDate
+func (_m *CorporateActionRepository) FindBySymbolAndDateRange(ctx context.Context, symbol string, startDate time.Time, endDate time.Time) ([]*models.CorporateAction, error) {
+	ret := _m.Called(ctx, symbol, startDate, endDate)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindBySymbolAndDateRange")
@@ -240,19 +246,19 @@ func (_m *CorporateActionRepository) FindBySymbolAndDateRange(symbol string, sta
 
 	var r0 []*models.CorporateAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, time.Time, time.Time) ([]*models.CorporateAction, error)); ok {
-		return rf(symbol, startDate, endDate)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) ([]*models.CorporateAction, error)); ok {
+		return rf(ctx, symbol, startDate, endDate)
 	}
-	if rf, ok := ret.Get(0).(func(string, time.Time, time.Time) []*models.CorporateAction); ok {
-		r0 = rf(symbol, startDate, endDate)
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Time) []*models.CorporateAction); ok {
+		r0 = rf(ctx, symbol, startDate, endDate)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.CorporateAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, time.Time, time.Time) error); ok {
-		r1 = rf(symbol, startDate, endDate)
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, symbol, startDate, endDate)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -266,16 +272,17 @@ type CorporateActionRepository_FindBySymbolAndDateRange_Call struct {
 }
 
 // FindBySymbolAndDateRange is a helper method to define mock.On call
+//   - ctx context.Context
 //   - symbol string
 //   - startDate time.Time
 //   - endDate time.Time
-func (_e *CorporateActionRepository_Expecter) FindBySymbolAndDateRange(symbol interface{}, startDate interface{}, endDate interface{}) *CorporateActionRepository_FindBySymbolAndDateRange_Call {
-	return &CorporateActionRepository_FindBySymbolAndDateRange_Call{Call: _e.mock.On("FindBySymbolAndDateRange", symbol, startDate, endDate)}
+func (_e *CorporateActionRepository_Expecter) FindBySymbolAndDateRange(ctx interface{}, symbol interface{}, startDate interface{}, endDate interface{}) *CorporateActionRepository_FindBySymbolAndDateRange_Call {
+	return &CorporateActionRepository_FindBySymbolAndDateRange_Call{Call: _e.mock.On("FindBySymbolAndDateRange", ctx, symbol, startDate, endDate)}
 }
 
-func (_c *CorporateActionRepository_FindBySymbolAndDateRange_Call) Run(run func(symbol string, startDate time.Time, endDate time.Time)) *CorporateActionRepository_FindBySymbolAndDateRange_Call {
+func (_c *CorporateActionRepository_FindBySymbolAndDateRange_Call) Run(run func(ctx context.Context, symbol string, startDate time.Time, endDate time.Time)) *CorporateActionRepository_FindBySymbolAndDateRange_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(time.Time), args[2].(time.Time))
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(time.Time))
 	})
 	return _c
 }
@@ -285,14 +292,14 @@ func (_c *CorporateActionRepository_FindBySymbolAndDateRange_Call) Return(_a0 []
 	return _c
 }
 
-func (_c *CorporateActionRepository_FindBySymbolAndDateRange_Call) RunAndReturn(run func(string, time.Time, time.Time) ([]*models.CorporateAction, error)) *CorporateActionRepository_FindBySymbolAndDateRange_Call {
+func (_c *CorporateActionRepository_FindBySymbolAndDateRange_Call) RunAndReturn(run func(context.Context, string, time.Time, time.Time) ([]*models.CorporateAction, error)) *CorporateActionRepository_FindBySymbolAndDateRange_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindUnapplied provides a mock function with no fields
-func (_m *CorporateActionRepository) FindUnapplied() ([]*models.CorporateAction, error) {
-	ret := _m.Called()
+// FindUnapplied provides a mock function with given fields: ctx
+func (_m *CorporateActionRepository) FindUnapplied(ctx context.Context) ([]*models.CorporateAction, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindUnapplied")
@@ -300,19 +307,19 @@ func (_m *CorporateActionRepository) FindUnapplied() ([]*models.CorporateAction,
 
 	var r0 []*models.CorporateAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*models.CorporateAction, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.CorporateAction, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func() []*models.CorporateAction); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.CorporateAction); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.CorporateAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -326,13 +333,14 @@ type CorporateActionRepository_FindUnapplied_Call struct {
 }
 
 // FindUnapplied is a helper method to define mock.On call
-func (_e *CorporateActionRepository_Expecter) FindUnapplied() *CorporateActionRepository_FindUnapplied_Call {
-	return &CorporateActionRepository_FindUnapplied_Call{Call: _e.mock.On("FindUnapplied")}
+//   - ctx context.Context
+func (_e *CorporateActionRepository_Expecter) FindUnapplied(ctx interface{}) *CorporateActionRepository_FindUnapplied_Call {
+	return &CorporateActionRepository_FindUnapplied_Call{Call: _e.mock.On("FindUnapplied", ctx)}
 }
 
-func (_c *CorporateActionRepository_FindUnapplied_Call) Run(run func()) *CorporateActionRepository_FindUnapplied_Call {
+func (_c *CorporateActionRepository_FindUnapplied_Call) Run(run func(ctx context.Context)) *CorporateActionRepository_FindUnapplied_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(context.Context))
 	})
 	return _c
 }
@@ -342,22 +350,22 @@ func (_c *CorporateActionRepository_FindUnapplied_Call) Return(_a0 []*models.Cor
 	return _c
 }
 
-func (_c *CorporateActionRepository_FindUnapplied_Call) RunAndReturn(run func() ([]*models.CorporateAction, error)) *CorporateActionRepository_FindUnapplied_Call {
+func (_c *CorporateActionRepository_FindUnapplied_Call) RunAndReturn(run func(context.Context) ([]*models.CorporateAction, error)) *CorporateActionRepository_FindUnapplied_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: action
-func (_m *CorporateActionRepository) Update(action *models.CorporateAction) error {
-	ret := _m.Called(action)
+// Update provides a mock function with given fields: ctx, action
+func (_m *CorporateActionRepository) Update(ctx context.Context, action *models.CorporateAction) error {
+	ret := _m.Called(ctx, action)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.CorporateAction) error); ok {
-		r0 = rf(action)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.CorporateAction) error); ok {
+		r0 = rf(ctx, action)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -371,14 +379,15 @@ type CorporateActionRepository_Update_Call struct {
 }
 
 // Update is a helper method to define mock.On call
+//   - ctx context.Context
 //   - action *models.CorporateAction
-func (_e *CorporateActionRepository_Expecter) Update(action interface{}) *CorporateActionRepository_Update_Call {
-	return &CorporateActionRepository_Update_Call{Call: _e.mock.On("Update", action)}
+func (_e *CorporateActionRepository_Expecter) Update(ctx interface{}, action interface{}) *CorporateActionRepository_Update_Call {
+	return &CorporateActionRepository_Update_Call{Call: _e.mock.On("Update", ctx, action)}
 }
 
-func (_c *CorporateActionRepository_Update_Call) Run(run func(action *models.CorporateAction)) *CorporateActionRepository_Update_Call {
+func (_c *CorporateActionRepository_Update_Call) Run(run func(ctx context.Context, action *models.CorporateAction)) *CorporateActionRepository_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.CorporateAction))
+		run(args[0].(context.Context), args[1].(*models.CorporateAction))
 	})
 	return _c
 }
@@ -388,7 +397,7 @@ func (_c *CorporateActionRepository_Update_Call) Return(_a0 error) *CorporateAct
 	return _c
 }
 
-func (_c *CorporateActionRepository_Update_Call) RunAndReturn(run func(*models.CorporateAction) error) *CorporateActionRepository_Update_Call {
+func (_c *CorporateActionRepository_Update_Call) RunAndReturn(run func(context.Context, *models.CorporateAction) error) *CorporateActionRepository_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -3,8 +3,12 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/lenon/portfolios/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
 )
 
 // HoldingRepository is an autogenerated mock type for the HoldingRepository type
@@ -20,17 +24,75 @@ func (_m *HoldingRepository) EXPECT() *HoldingRepository_Expecter {
 	return &HoldingRepository_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: holding
-func (_m *HoldingRepository) Create(holding *models.Holding) error {
-	ret := _m.Called(holding)
+// CountHoldersBySymbol provides a mock function with given fields: ctx
+func (_m *HoldingRepository) CountHoldersBySymbol(ctx context.Context) (map[string]int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountHoldersBySymbol")
+	}
+
+	var r0 map[string]int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (map[string]int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) map[string]int); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HoldingRepository_CountHoldersBySymbol_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountHoldersBySymbol'
+type HoldingRepository_CountHoldersBySymbol_Call struct {
+	*mock.Call
+}
+
+// CountHoldersBySymbol is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *HoldingRepository_Expecter) CountHoldersBySymbol(ctx interface{}) *HoldingRepository_CountHoldersBySymbol_Call {
+	return &HoldingRepository_CountHoldersBySymbol_Call{Call: _e.mock.On("CountHoldersBySymbol", ctx)}
+}
+
+func (_c *HoldingRepository_CountHoldersBySymbol_Call) Run(run func(ctx context.Context)) *HoldingRepository_CountHoldersBySymbol_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *HoldingRepository_CountHoldersBySymbol_Call) Return(_a0 map[string]int, _a1 error) *HoldingRepository_CountHoldersBySymbol_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HoldingRepository_CountHoldersBySymbol_Call) RunAndReturn(run func(context.Context) (map[string]int, error)) *HoldingRepository_CountHoldersBySymbol_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function with given fields: ctx, holding
+func (_m *HoldingRepository) Create(ctx context.Context, holding *models.Holding) error {
+	ret := _m.Called(ctx, holding)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Holding) error); ok {
-		r0 = rf(holding)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Holding) error); ok {
+		r0 = rf(ctx, holding)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -44,14 +106,15 @@ type HoldingRepository_Create_Call struct {
 }
 
 // Create is a helper method to define mock.On call
+//   - ctx context.Context
 //   - holding *models.Holding
-func (_e *HoldingRepository_Expecter) Create(holding interface{}) *HoldingRepository_Create_Call {
-	return &HoldingRepository_Create_Call{Call: _e.mock.On("Create", holding)}
+func (_e *HoldingRepository_Expecter) Create(ctx interface{}, holding interface{}) *HoldingRepository_Create_Call {
+	return &HoldingRepository_Create_Call{Call: _e.mock.On("Create", ctx, holding)}
 }
 
-func (_c *HoldingRepository_Create_Call) Run(run func(holding *models.Holding)) *HoldingRepository_Create_Call {
+func (_c *HoldingRepository_Create_Call) Run(run func(ctx context.Context, holding *models.Holding)) *HoldingRepository_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Holding))
+		run(args[0].(context.Context), args[1].(*models.Holding))
 	})
 	return _c
 }
@@ -61,22 +124,22 @@ func (_c *HoldingRepository_Create_Call) Return(_a0 error) *HoldingRepository_Cr
 	return _c
 }
 
-func (_c *HoldingRepository_Create_Call) RunAndReturn(run func(*models.Holding) error) *HoldingRepository_Create_Call {
+func (_c *HoldingRepository_Create_Call) RunAndReturn(run func(context.Context, *models.Holding) error) *HoldingRepository_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *HoldingRepository) Delete(id string) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, id
+func (_m *HoldingRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,14 +153,15 @@ type HoldingRepository_Delete_Call struct {
 }
 
 // Delete is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *HoldingRepository_Expecter) Delete(id interface{}) *HoldingRepository_Delete_Call {
-	return &HoldingRepository_Delete_Call{Call: _e.mock.On("Delete", id)}
+func (_e *HoldingRepository_Expecter) Delete(ctx interface{}, id interface{}) *HoldingRepository_Delete_Call {
+	return &HoldingRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
 }
 
-func (_c *HoldingRepository_Delete_Call) Run(run func(id string)) *HoldingRepository_Delete_Call {
+func (_c *HoldingRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *HoldingRepository_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -107,22 +171,22 @@ func (_c *HoldingRepository_Delete_Call) Return(_a0 error) *HoldingRepository_De
 	return _c
 }
 
-func (_c *HoldingRepository_Delete_Call) RunAndReturn(run func(string) error) *HoldingRepository_Delete_Call {
+func (_c *HoldingRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *HoldingRepository_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteByPortfolioIDAndSymbol provides a mock function with given fields: portfolioID, symbol
-func (_m *HoldingRepository) DeleteByPortfolioIDAndSymbol(portfolioID string, symbol string) error {
-	ret := _m.Called(portfolioID, symbol)
+// DeleteByPortfolioIDAndSymbol provides a mock function with given fields: ctx, portfolioID, symbol
+func (_m *HoldingRepository) DeleteByPortfolioIDAndSymbol(ctx context.Context, portfolioID string, symbol string) error {
+	ret := _m.Called(ctx, portfolioID, symbol)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteByPortfolioIDAndSymbol")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(portfolioID, symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, portfolioID, symbol)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -136,15 +200,16 @@ type HoldingRepository_DeleteByPortfolioIDAndSymbol_Call struct {
 }
 
 // DeleteByPortfolioIDAndSymbol is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
 //   - symbol string
-func (_e *HoldingRepository_Expecter) DeleteByPortfolioIDAndSymbol(portfolioID interface{}, symbol interface{}) *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call {
-	return &HoldingRepository_DeleteByPortfolioIDAndSymbol_Call{Call: _e.mock.On("DeleteByPortfolioIDAndSymbol", portfolioID, symbol)}
+func (_e *HoldingRepository_Expecter) DeleteByPortfolioIDAndSymbol(ctx interface{}, portfolioID interface{}, symbol interface{}) *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call {
+	return &HoldingRepository_DeleteByPortfolioIDAndSymbol_Call{Call: _e.mock.On("DeleteByPortfolioIDAndSymbol", ctx, portfolioID, symbol)}
 }
 
-func (_c *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call) Run(run func(portfolioID string, symbol string)) *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call {
+func (_c *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call) Run(run func(ctx context.Context, portfolioID string, symbol string)) *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -154,14 +219,14 @@ func (_c *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call) Return(_a0 error)
 	return _c
 }
 
-func (_c *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call) RunAndReturn(run func(string, string) error) *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call {
+func (_c *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call) RunAndReturn(run func(context.Context, string, string) error) *HoldingRepository_DeleteByPortfolioIDAndSymbol_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByID provides a mock function with given fields: id
-func (_m *HoldingRepository) FindByID(id string) (*models.Holding, error) {
-	ret := _m.Called(id)
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *HoldingRepository) FindByID(ctx context.Context, id string) (*models.Holding, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByID")
@@ -169,19 +234,19 @@ func (_m *HoldingRepository) FindByID(id string) (*models.Holding, error) {
 
 	var r0 *models.Holding
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*models.Holding, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Holding, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *models.Holding); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Holding); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Holding)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -195,14 +260,15 @@ type HoldingRepository_FindByID_Call struct {
 }
 
 // FindByID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *HoldingRepository_Expecter) FindByID(id interface{}) *HoldingRepository_FindByID_Call {
-	return &HoldingRepository_FindByID_Call{Call: _e.mock.On("FindByID", id)}
+func (_e *HoldingRepository_Expecter) FindByID(ctx interface{}, id interface{}) *HoldingRepository_FindByID_Call {
+	return &HoldingRepository_FindByID_Call{Call: _e.mock.On("FindByID", ctx, id)}
 }
 
-func (_c *HoldingRepository_FindByID_Call) Run(run func(id string)) *HoldingRepository_FindByID_Call {
+func (_c *HoldingRepository_FindByID_Call) Run(run func(ctx context.Context, id string)) *HoldingRepository_FindByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -212,14 +278,14 @@ func (_c *HoldingRepository_FindByID_Call) Return(_a0 *models.Holding, _a1 error
 	return _c
 }
 
-func (_c *HoldingRepository_FindByID_Call) RunAndReturn(run func(string) (*models.Holding, error)) *HoldingRepository_FindByID_Call {
+func (_c *HoldingRepository_FindByID_Call) RunAndReturn(run func(context.Context, string) (*models.Holding, error)) *HoldingRepository_FindByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioID provides a mock function with given fields: portfolioID
-func (_m *HoldingRepository) FindByPortfolioID(portfolioID string) ([]*models.Holding, error) {
-	ret := _m.Called(portfolioID)
+// FindByPortfolioID provides a mock function with given fields: ctx, portfolioID
+func (_m *HoldingRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Holding, error) {
+	ret := _m.Called(ctx, portfolioID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioID")
@@ -227,19 +293,19 @@ func (_m *HoldingRepository) FindByPortfolioID(portfolioID string) ([]*models.Ho
 
 	var r0 []*models.Holding
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.Holding, error)); ok {
-		return rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.Holding, error)); ok {
+		return rf(ctx, portfolioID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.Holding); ok {
-		r0 = rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.Holding); ok {
+		r0 = rf(ctx, portfolioID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Holding)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(portfolioID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, portfolioID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -253,14 +319,15 @@ type HoldingRepository_FindByPortfolioID_Call struct {
 }
 
 // FindByPortfolioID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
-func (_e *HoldingRepository_Expecter) FindByPortfolioID(portfolioID interface{}) *HoldingRepository_FindByPortfolioID_Call {
-	return &HoldingRepository_FindByPortfolioID_Call{Call: _e.mock.On("FindByPortfolioID", portfolioID)}
+func (_e *HoldingRepository_Expecter) FindByPortfolioID(ctx interface{}, portfolioID interface{}) *HoldingRepository_FindByPortfolioID_Call {
+	return &HoldingRepository_FindByPortfolioID_Call{Call: _e.mock.On("FindByPortfolioID", ctx, portfolioID)}
 }
 
-func (_c *HoldingRepository_FindByPortfolioID_Call) Run(run func(portfolioID string)) *HoldingRepository_FindByPortfolioID_Call {
+func (_c *HoldingRepository_FindByPortfolioID_Call) Run(run func(ctx context.Context, portfolioID string)) *HoldingRepository_FindByPortfolioID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -270,14 +337,73 @@ func (_c *HoldingRepository_FindByPortfolioID_Call) Return(_a0 []*models.Holding
 	return _c
 }
 
-func (_c *HoldingRepository_FindByPortfolioID_Call) RunAndReturn(run func(string) ([]*models.Holding, error)) *HoldingRepository_FindByPortfolioID_Call {
+func (_c *HoldingRepository_FindByPortfolioID_Call) RunAndReturn(run func(context.Context, string) ([]*models.Holding, error)) *HoldingRepository_FindByPortfolioID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindByPortfolioIDs provides a mock function with given fields: ctx, portfolioIDs
+func (_m *HoldingRepository) FindByPortfolioIDs(ctx context.Context, portfolioIDs []uuid.UUID) ([]*models.Holding, error) {
+	ret := _m.Called(ctx, portfolioIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByPortfolioIDs")
+	}
+
+	var r0 []*models.Holding
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) ([]*models.Holding, error)); ok {
+		return rf(ctx, portfolioIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []uuid.UUID) []*models.Holding); ok {
+		r0 = rf(ctx, portfolioIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Holding)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []uuid.UUID) error); ok {
+		r1 = rf(ctx, portfolioIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HoldingRepository_FindByPortfolioIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByPortfolioIDs'
+type HoldingRepository_FindByPortfolioIDs_Call struct {
+	*mock.Call
+}
+
+// FindByPortfolioIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - portfolioIDs []uuid.UUID
+func (_e *HoldingRepository_Expecter) FindByPortfolioIDs(ctx interface{}, portfolioIDs interface{}) *HoldingRepository_FindByPortfolioIDs_Call {
+	return &HoldingRepository_FindByPortfolioIDs_Call{Call: _e.mock.On("FindByPortfolioIDs", ctx, portfolioIDs)}
+}
+
+func (_c *HoldingRepository_FindByPortfolioIDs_Call) Run(run func(ctx context.Context, portfolioIDs []uuid.UUID)) *HoldingRepository_FindByPortfolioIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *HoldingRepository_FindByPortfolioIDs_Call) Return(_a0 []*models.Holding, _a1 error) *HoldingRepository_FindByPortfolioIDs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HoldingRepository_FindByPortfolioIDs_Call) RunAndReturn(run func(context.Context, []uuid.UUID) ([]*models.Holding, error)) *HoldingRepository_FindByPortfolioIDs_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioIDAndSymbol provides a mock function with given fields: portfolioID, symbol
-func (_m *HoldingRepository) FindByPortfolioIDAndSymbol(portfolioID string, symbol string) (*models.Holding, error) {
-	ret := _m.Called(portfolioID, symbol)
+// FindByPortfolioIDAndSymbol provides a mock function with given fields: ctx, portfolioID, symbol
+func (_m *HoldingRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID string, symbol string) (*models.Holding, error) {
+	ret := _m.Called(ctx, portfolioID, symbol)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioIDAndSymbol")
@@ -285,19 +411,19 @@ func (_m *HoldingRepository) FindByPortfolioIDAndSymbol(portfolioID string, symb
 
 	var r0 *models.Holding
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (*models.Holding, error)); ok {
-		return rf(portfolioID, symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Holding, error)); ok {
+		return rf(ctx, portfolioID, symbol)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) *models.Holding); ok {
-		r0 = rf(portfolioID, symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Holding); ok {
+		r0 = rf(ctx, portfolioID, symbol)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Holding)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(portfolioID, symbol)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, portfolioID, symbol)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -311,15 +437,16 @@ type HoldingRepository_FindByPortfolioIDAndSymbol_Call struct {
 }
 
 // FindByPortfolioIDAndSymbol is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
 //   - symbol string
-func (_e *HoldingRepository_Expecter) FindByPortfolioIDAndSymbol(portfolioID interface{}, symbol interface{}) *HoldingRepository_FindByPortfolioIDAndSymbol_Call {
-	return &HoldingRepository_FindByPortfolioIDAndSymbol_Call{Call: _e.mock.On("FindByPortfolioIDAndSymbol", portfolioID, symbol)}
+func (_e *HoldingRepository_Expecter) FindByPortfolioIDAndSymbol(ctx interface{}, portfolioID interface{}, symbol interface{}) *HoldingRepository_FindByPortfolioIDAndSymbol_Call {
+	return &HoldingRepository_FindByPortfolioIDAndSymbol_Call{Call: _e.mock.On("FindByPortfolioIDAndSymbol", ctx, portfolioID, symbol)}
 }
 
-func (_c *HoldingRepository_FindByPortfolioIDAndSymbol_Call) Run(run func(portfolioID string, symbol string)) *HoldingRepository_FindByPortfolioIDAndSymbol_Call {
+func (_c *HoldingRepository_FindByPortfolioIDAndSymbol_Call) Run(run func(ctx context.Context, portfolioID string, symbol string)) *HoldingRepository_FindByPortfolioIDAndSymbol_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -329,14 +456,14 @@ func (_c *HoldingRepository_FindByPortfolioIDAndSymbol_Call) Return(_a0 *models.
 	return _c
 }
 
-func (_c *HoldingRepository_FindByPortfolioIDAndSymbol_Call) RunAndReturn(run func(string, string) (*models.Holding, error)) *HoldingRepository_FindByPortfolioIDAndSymbol_Call {
+func (_c *HoldingRepository_FindByPortfolioIDAndSymbol_Call) RunAndReturn(run func(context.Context, string, string) (*models.Holding, error)) *HoldingRepository_FindByPortfolioIDAndSymbol_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindBySymbol provides a mock function with given fields: symbol
-func (_m *HoldingRepository) FindBySymbol(symbol string) ([]*models.Holding, error) {
-	ret := _m.Called(symbol)
+// FindBySymbol provides a mock function with given fields: ctx, symbol
+func (_m *HoldingRepository) FindBySymbol(ctx context.Context, symbol string) ([]*models.Holding, error) {
+	ret := _m.Called(ctx, symbol)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindBySymbol")
@@ -344,19 +471,19 @@ func (_m *HoldingRepository) FindBySymbol(symbol string) ([]*models.Holding, err
 
 	var r0 []*models.Holding
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.Holding, error)); ok {
-		return rf(symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.Holding, error)); ok {
+		return rf(ctx, symbol)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.Holding); ok {
-		r0 = rf(symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.Holding); ok {
+		r0 = rf(ctx, symbol)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Holding)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(symbol)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, symbol)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -370,14 +497,15 @@ type HoldingRepository_FindBySymbol_Call struct {
 }
 
 // FindBySymbol is a helper method to define mock.On call
+//   - ctx context.Context
 //   - symbol string
-func (_e *HoldingRepository_Expecter) FindBySymbol(symbol interface{}) *HoldingRepository_FindBySymbol_Call {
-	return &HoldingRepository_FindBySymbol_Call{Call: _e.mock.On("FindBySymbol", symbol)}
+func (_e *HoldingRepository_Expecter) FindBySymbol(ctx interface{}, symbol interface{}) *HoldingRepository_FindBySymbol_Call {
+	return &HoldingRepository_FindBySymbol_Call{Call: _e.mock.On("FindBySymbol", ctx, symbol)}
 }
 
-func (_c *HoldingRepository_FindBySymbol_Call) Run(run func(symbol string)) *HoldingRepository_FindBySymbol_Call {
+func (_c *HoldingRepository_FindBySymbol_Call) Run(run func(ctx context.Context, symbol string)) *HoldingRepository_FindBySymbol_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -387,22 +515,80 @@ func (_c *HoldingRepository_FindBySymbol_Call) Return(_a0 []*models.Holding, _a1
 	return _c
 }
 
-func (_c *HoldingRepository_FindBySymbol_Call) RunAndReturn(run func(string) ([]*models.Holding, error)) *HoldingRepository_FindBySymbol_Call {
+func (_c *HoldingRepository_FindBySymbol_Call) RunAndReturn(run func(context.Context, string) ([]*models.Holding, error)) *HoldingRepository_FindBySymbol_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindDistinctSymbols provides a mock function with given fields: ctx
+func (_m *HoldingRepository) FindDistinctSymbols(ctx context.Context) ([]string, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindDistinctSymbols")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]string, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []string); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HoldingRepository_FindDistinctSymbols_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindDistinctSymbols'
+type HoldingRepository_FindDistinctSymbols_Call struct {
+	*mock.Call
+}
+
+// FindDistinctSymbols is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *HoldingRepository_Expecter) FindDistinctSymbols(ctx interface{}) *HoldingRepository_FindDistinctSymbols_Call {
+	return &HoldingRepository_FindDistinctSymbols_Call{Call: _e.mock.On("FindDistinctSymbols", ctx)}
+}
+
+func (_c *HoldingRepository_FindDistinctSymbols_Call) Run(run func(ctx context.Context)) *HoldingRepository_FindDistinctSymbols_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *HoldingRepository_FindDistinctSymbols_Call) Return(_a0 []string, _a1 error) *HoldingRepository_FindDistinctSymbols_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *HoldingRepository_FindDistinctSymbols_Call) RunAndReturn(run func(context.Context) ([]string, error)) *HoldingRepository_FindDistinctSymbols_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: holding
-func (_m *HoldingRepository) Update(holding *models.Holding) error {
-	ret := _m.Called(holding)
+// Update provides a mock function with given fields: ctx, holding
+func (_m *HoldingRepository) Update(ctx context.Context, holding *models.Holding) error {
+	ret := _m.Called(ctx, holding)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Holding) error); ok {
-		r0 = rf(holding)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Holding) error); ok {
+		r0 = rf(ctx, holding)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -416,14 +602,15 @@ type HoldingRepository_Update_Call struct {
 }
 
 // Update is a helper method to define mock.On call
+//   - ctx context.Context
 //   - holding *models.Holding
-func (_e *HoldingRepository_Expecter) Update(holding interface{}) *HoldingRepository_Update_Call {
-	return &HoldingRepository_Update_Call{Call: _e.mock.On("Update", holding)}
+func (_e *HoldingRepository_Expecter) Update(ctx interface{}, holding interface{}) *HoldingRepository_Update_Call {
+	return &HoldingRepository_Update_Call{Call: _e.mock.On("Update", ctx, holding)}
 }
 
-func (_c *HoldingRepository_Update_Call) Run(run func(holding *models.Holding)) *HoldingRepository_Update_Call {
+func (_c *HoldingRepository_Update_Call) Run(run func(ctx context.Context, holding *models.Holding)) *HoldingRepository_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Holding))
+		run(args[0].(context.Context), args[1].(*models.Holding))
 	})
 	return _c
 }
@@ -433,22 +620,22 @@ func (_c *HoldingRepository_Update_Call) Return(_a0 error) *HoldingRepository_Up
 	return _c
 }
 
-func (_c *HoldingRepository_Update_Call) RunAndReturn(run func(*models.Holding) error) *HoldingRepository_Update_Call {
+func (_c *HoldingRepository_Update_Call) RunAndReturn(run func(context.Context, *models.Holding) error) *HoldingRepository_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Upsert provides a mock function with given fields: holding
-func (_m *HoldingRepository) Upsert(holding *models.Holding) error {
-	ret := _m.Called(holding)
+// Upsert provides a mock function with given fields: ctx, holding
+func (_m *HoldingRepository) Upsert(ctx context.Context, holding *models.Holding) error {
+	ret := _m.Called(ctx, holding)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Upsert")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Holding) error); ok {
-		r0 = rf(holding)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Holding) error); ok {
+		r0 = rf(ctx, holding)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -462,14 +649,15 @@ type HoldingRepository_Upsert_Call struct {
 }
 
 // Upsert is a helper method to define mock.On call
+//   - ctx context.Context
 //   - holding *models.Holding
-func (_e *HoldingRepository_Expecter) Upsert(holding interface{}) *HoldingRepository_Upsert_Call {
-	return &HoldingRepository_Upsert_Call{Call: _e.mock.On("Upsert", holding)}
+func (_e *HoldingRepository_Expecter) Upsert(ctx interface{}, holding interface{}) *HoldingRepository_Upsert_Call {
+	return &HoldingRepository_Upsert_Call{Call: _e.mock.On("Upsert", ctx, holding)}
 }
 
-func (_c *HoldingRepository_Upsert_Call) Run(run func(holding *models.Holding)) *HoldingRepository_Upsert_Call {
+func (_c *HoldingRepository_Upsert_Call) Run(run func(ctx context.Context, holding *models.Holding)) *HoldingRepository_Upsert_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Holding))
+		run(args[0].(context.Context), args[1].(*models.Holding))
 	})
 	return _c
 }
@@ -479,7 +667,7 @@ func (_c *HoldingRepository_Upsert_Call) Return(_a0 error) *HoldingRepository_Up
 	return _c
 }
 
-func (_c *HoldingRepository_Upsert_Call) RunAndReturn(run func(*models.Holding) error) *HoldingRepository_Upsert_Call {
+func (_c *HoldingRepository_Upsert_Call) RunAndReturn(run func(context.Context, *models.Holding) error) *HoldingRepository_Upsert_Call {
 	_c.Call.Return(run)
 	return _c
 }
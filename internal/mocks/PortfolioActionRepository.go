@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/lenon/portfolios/internal/models"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -20,17 +22,17 @@ func (_m *PortfolioActionRepository) EXPECT() *PortfolioActionRepository_Expecte
 	return &PortfolioActionRepository_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: action
-func (_m *PortfolioActionRepository) Create(action *models.PortfolioAction) error {
-	ret := _m.Called(action)
+// Create provides a mock function with given fields: ctx, action
+func (_m *PortfolioActionRepository) Create(ctx context.Context, action *models.PortfolioAction) error {
+	ret := _m.Called(ctx, action)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.PortfolioAction) error); ok {
-		r0 = rf(action)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PortfolioAction) error); ok {
+		r0 = rf(ctx, action)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -44,14 +46,15 @@ type PortfolioActionRepository_Create_Call struct {
 }
 
 // Create is a helper method to define mock.On call
+//   - ctx context.Context
 //   - action *models.PortfolioAction
-func (_e *PortfolioActionRepository_Expecter) Create(action interface{}) *PortfolioActionRepository_Create_Call {
-	return &PortfolioActionRepository_Create_Call{Call: _e.mock.On("Create", action)}
+func (_e *PortfolioActionRepository_Expecter) Create(ctx interface{}, action interface{}) *PortfolioActionRepository_Create_Call {
+	return &PortfolioActionRepository_Create_Call{Call: _e.mock.On("Create", ctx, action)}
 }
 
-func (_c *PortfolioActionRepository_Create_Call) Run(run func(action *models.PortfolioAction)) *PortfolioActionRepository_Create_Call {
+func (_c *PortfolioActionRepository_Create_Call) Run(run func(ctx context.Context, action *models.PortfolioAction)) *PortfolioActionRepository_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.PortfolioAction))
+		run(args[0].(context.Context), args[1].(*models.PortfolioAction))
 	})
 	return _c
 }
@@ -61,22 +64,22 @@ func (_c *PortfolioActionRepository_Create_Call) Return(_a0 error) *PortfolioAct
 	return _c
 }
 
-func (_c *PortfolioActionRepository_Create_Call) RunAndReturn(run func(*models.PortfolioAction) error) *PortfolioActionRepository_Create_Call {
+func (_c *PortfolioActionRepository_Create_Call) RunAndReturn(run func(context.Context, *models.PortfolioAction) error) *PortfolioActionRepository_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *PortfolioActionRepository) Delete(id string) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, id
+func (_m *PortfolioActionRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,14 +93,15 @@ type PortfolioActionRepository_Delete_Call struct {
 }
 
 // Delete is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *PortfolioActionRepository_Expecter) Delete(id interface{}) *PortfolioActionRepository_Delete_Call {
-	return &PortfolioActionRepository_Delete_Call{Call: _e.mock.On("Delete", id)}
+func (_e *PortfolioActionRepository_Expecter) Delete(ctx interface{}, id interface{}) *PortfolioActionRepository_Delete_Call {
+	return &PortfolioActionRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
 }
 
-func (_c *PortfolioActionRepository_Delete_Call) Run(run func(id string)) *PortfolioActionRepository_Delete_Call {
+func (_c *PortfolioActionRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *PortfolioActionRepository_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -107,14 +111,14 @@ func (_c *PortfolioActionRepository_Delete_Call) Return(_a0 error) *PortfolioAct
 	return _c
 }
 
-func (_c *PortfolioActionRepository_Delete_Call) RunAndReturn(run func(string) error) *PortfolioActionRepository_Delete_Call {
+func (_c *PortfolioActionRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *PortfolioActionRepository_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ExistsPendingForPortfolioAndAction provides a mock function with given fields: portfolioID, corporateActionID
-func (_m *PortfolioActionRepository) ExistsPendingForPortfolioAndAction(portfolioID string, corporateActionID string) (bool, error) {
-	ret := _m.Called(portfolioID, corporateActionID)
+// ExistsPendingForPortfolioAndAction provides a mock function with given fields: ctx, portfolioID, corporateActionID
+func (_m *PortfolioActionRepository) ExistsPendingForPortfolioAndAction(ctx context.Context, portfolioID string, corporateActionID string) (bool, error) {
+	ret := _m.Called(ctx, portfolioID, corporateActionID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ExistsPendingForPortfolioAndAction")
@@ -122,17 +126,17 @@ func (_m *PortfolioActionRepository) ExistsPendingForPortfolioAndAction(portfoli
 
 	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (bool, error)); ok {
-		return rf(portfolioID, corporateActionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, portfolioID, corporateActionID)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
-		r0 = rf(portfolioID, corporateActionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, portfolioID, corporateActionID)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(portfolioID, corporateActionID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, portfolioID, corporateActionID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -146,15 +150,16 @@ type PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call struct {
 }
 
 // ExistsPendingForPortfolioAndAction is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
 //   - corporateActionID string
-func (_e *PortfolioActionRepository_Expecter) ExistsPendingForPortfolioAndAction(portfolioID interface{}, corporateActionID interface{}) *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call {
-	return &PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call{Call: _e.mock.On("ExistsPendingForPortfolioAndAction", portfolioID, corporateActionID)}
+func (_e *PortfolioActionRepository_Expecter) ExistsPendingForPortfolioAndAction(ctx interface{}, portfolioID interface{}, corporateActionID interface{}) *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call {
+	return &PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call{Call: _e.mock.On("ExistsPendingForPortfolioAndAction", ctx, portfolioID, corporateActionID)}
 }
 
-func (_c *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call) Run(run func(portfolioID string, corporateActionID string)) *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call {
+func (_c *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call) Run(run func(ctx context.Context, portfolioID string, corporateActionID string)) *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -164,14 +169,14 @@ func (_c *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call) Ret
 	return _c
 }
 
-func (_c *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call) RunAndReturn(run func(string, string) (bool, error)) *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call {
+func (_c *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *PortfolioActionRepository_ExistsPendingForPortfolioAndAction_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByID provides a mock function with given fields: id
-func (_m *PortfolioActionRepository) FindByID(id string) (*models.PortfolioAction, error) {
-	ret := _m.Called(id)
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *PortfolioActionRepository) FindByID(ctx context.Context, id string) (*models.PortfolioAction, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByID")
@@ -179,19 +184,19 @@ func (_m *PortfolioActionRepository) FindByID(id string) (*models.PortfolioActio
 
 	var r0 *models.PortfolioAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*models.PortfolioAction, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.PortfolioAction, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *models.PortfolioAction); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.PortfolioAction); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.PortfolioAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -205,14 +210,15 @@ type PortfolioActionRepository_FindByID_Call struct {
 }
 
 // FindByID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *PortfolioActionRepository_Expecter) FindByID(id interface{}) *PortfolioActionRepository_FindByID_Call {
-	return &PortfolioActionRepository_FindByID_Call{Call: _e.mock.On("FindByID", id)}
+func (_e *PortfolioActionRepository_Expecter) FindByID(ctx interface{}, id interface{}) *PortfolioActionRepository_FindByID_Call {
+	return &PortfolioActionRepository_FindByID_Call{Call: _e.mock.On("FindByID", ctx, id)}
 }
 
-func (_c *PortfolioActionRepository_FindByID_Call) Run(run func(id string)) *PortfolioActionRepository_FindByID_Call {
+func (_c *PortfolioActionRepository_FindByID_Call) Run(run func(ctx context.Context, id string)) *PortfolioActionRepository_FindByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -222,14 +228,14 @@ func (_c *PortfolioActionRepository_FindByID_Call) Return(_a0 *models.PortfolioA
 	return _c
 }
 
-func (_c *PortfolioActionRepository_FindByID_Call) RunAndReturn(run func(string) (*models.PortfolioAction, error)) *PortfolioActionRepository_FindByID_Call {
+func (_c *PortfolioActionRepository_FindByID_Call) RunAndReturn(run func(context.Context, string) (*models.PortfolioAction, error)) *PortfolioActionRepository_FindByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioID provides a mock function with given fields: portfolioID
-func (_m *PortfolioActionRepository) FindByPortfolioID(portfolioID string) ([]*models.PortfolioAction, error) {
-	ret := _m.Called(portfolioID)
+// FindByPortfolioID provides a mock function with given fields: ctx, portfolioID
+func (_m *PortfolioActionRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.PortfolioAction, error) {
+	ret := _m.Called(ctx, portfolioID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioID")
@@ -237,19 +243,19 @@ func (_m *PortfolioActionRepository) FindByPortfolioID(portfolioID string) ([]*m
 
 	var r0 []*models.PortfolioAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.PortfolioAction, error)); ok {
-		return rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.PortfolioAction, error)); ok {
+		return rf(ctx, portfolioID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.PortfolioAction); ok {
-		r0 = rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.PortfolioAction); ok {
+		r0 = rf(ctx, portfolioID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.PortfolioAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(portfolioID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, portfolioID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -263,14 +269,15 @@ type PortfolioActionRepository_FindByPortfolioID_Call struct {
 }
 
 // FindByPortfolioID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
-func (_e *PortfolioActionRepository_Expecter) FindByPortfolioID(portfolioID interface{}) *PortfolioActionRepository_FindByPortfolioID_Call {
-	return &PortfolioActionRepository_FindByPortfolioID_Call{Call: _e.mock.On("FindByPortfolioID", portfolioID)}
+func (_e *PortfolioActionRepository_Expecter) FindByPortfolioID(ctx interface{}, portfolioID interface{}) *PortfolioActionRepository_FindByPortfolioID_Call {
+	return &PortfolioActionRepository_FindByPortfolioID_Call{Call: _e.mock.On("FindByPortfolioID", ctx, portfolioID)}
 }
 
-func (_c *PortfolioActionRepository_FindByPortfolioID_Call) Run(run func(portfolioID string)) *PortfolioActionRepository_FindByPortfolioID_Call {
+func (_c *PortfolioActionRepository_FindByPortfolioID_Call) Run(run func(ctx context.Context, portfolioID string)) *PortfolioActionRepository_FindByPortfolioID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -280,14 +287,14 @@ func (_c *PortfolioActionRepository_FindByPortfolioID_Call) Return(_a0 []*models
 	return _c
 }
 
-func (_c *PortfolioActionRepository_FindByPortfolioID_Call) RunAndReturn(run func(string) ([]*models.PortfolioAction, error)) *PortfolioActionRepository_FindByPortfolioID_Call {
+func (_c *PortfolioActionRepository_FindByPortfolioID_Call) RunAndReturn(run func(context.Context, string) ([]*models.PortfolioAction, error)) *PortfolioActionRepository_FindByPortfolioID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioIDAndStatus provides a mock function with given fields: portfolioID, status
-func (_m *PortfolioActionRepository) FindByPortfolioIDAndStatus(portfolioID string, status models.PortfolioActionStatus) ([]*models.PortfolioAction, error) {
-	ret := _m.Called(portfolioID, status)
+// FindByPortfolioIDAndStatus provides a mock function with given fields: ctx, portfolioID, status
+func (_m *PortfolioActionRepository) FindByPortfolioIDAndStatus(ctx context.Context, portfolioID string, status models.PortfolioActionStatus) ([]*models.PortfolioAction, error) {
+	ret := _m.Called(ctx, portfolioID, status)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioIDAndStatus")
@@ -295,19 +302,19 @@ func (_m *PortfolioActionRepository) FindByPortfolioIDAndStatus(portfolioID stri
 
 	var r0 []*models.PortfolioAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, models.PortfolioActionStatus) ([]*models.PortfolioAction, error)); ok {
-		return rf(portfolioID, status)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.PortfolioActionStatus) ([]*models.PortfolioAction, error)); ok {
+		return rf(ctx, portfolioID, status)
 	}
-	if rf, ok := ret.Get(0).(func(string, models.PortfolioActionStatus) []*models.PortfolioAction); ok {
-		r0 = rf(portfolioID, status)
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.PortfolioActionStatus) []*models.PortfolioAction); ok {
+		r0 = rf(ctx, portfolioID, status)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.PortfolioAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, models.PortfolioActionStatus) error); ok {
-		r1 = rf(portfolioID, status)
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.PortfolioActionStatus) error); ok {
+		r1 = rf(ctx, portfolioID, status)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -321,15 +328,16 @@ type PortfolioActionRepository_FindByPortfolioIDAndStatus_Call struct {
 }
 
 // FindByPortfolioIDAndStatus is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
 //   - status models.PortfolioActionStatus
-func (_e *PortfolioActionRepository_Expecter) FindByPortfolioIDAndStatus(portfolioID interface{}, status interface{}) *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call {
-	return &PortfolioActionRepository_FindByPortfolioIDAndStatus_Call{Call: _e.mock.On("FindByPortfolioIDAndStatus", portfolioID, status)}
+func (_e *PortfolioActionRepository_Expecter) FindByPortfolioIDAndStatus(ctx interface{}, portfolioID interface{}, status interface{}) *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call {
+	return &PortfolioActionRepository_FindByPortfolioIDAndStatus_Call{Call: _e.mock.On("FindByPortfolioIDAndStatus", ctx, portfolioID, status)}
 }
 
-func (_c *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call) Run(run func(portfolioID string, status models.PortfolioActionStatus)) *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call {
+func (_c *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call) Run(run func(ctx context.Context, portfolioID string, status models.PortfolioActionStatus)) *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(models.PortfolioActionStatus))
+		run(args[0].(context.Context), args[1].(string), args[2].(models.PortfolioActionStatus))
 	})
 	return _c
 }
@@ -339,14 +347,14 @@ func (_c *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call) Return(_a0
 	return _c
 }
 
-func (_c *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call) RunAndReturn(run func(string, models.PortfolioActionStatus) ([]*models.PortfolioAction, error)) *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call {
+func (_c *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call) RunAndReturn(run func(context.Context, string, models.PortfolioActionStatus) ([]*models.PortfolioAction, error)) *PortfolioActionRepository_FindByPortfolioIDAndStatus_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindPendingByCorporateActionID provides a mock function with given fields: corporateActionID
-func (_m *PortfolioActionRepository) FindPendingByCorporateActionID(corporateActionID string) ([]*models.PortfolioAction, error) {
-	ret := _m.Called(corporateActionID)
+// FindPendingByCorporateActionID provides a mock function with given fields: ctx, corporateActionID
+func (_m *PortfolioActionRepository) FindPendingByCorporateActionID(ctx context.Context, corporateActionID string) ([]*models.PortfolioAction, error) {
+	ret := _m.Called(ctx, corporateActionID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindPendingByCorporateActionID")
@@ -354,19 +362,19 @@ func (_m *PortfolioActionRepository) FindPendingByCorporateActionID(corporateAct
 
 	var r0 []*models.PortfolioAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.PortfolioAction, error)); ok {
-		return rf(corporateActionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.PortfolioAction, error)); ok {
+		return rf(ctx, corporateActionID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.PortfolioAction); ok {
-		r0 = rf(corporateActionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.PortfolioAction); ok {
+		r0 = rf(ctx, corporateActionID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.PortfolioAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(corporateActionID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, corporateActionID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -380,14 +388,15 @@ type PortfolioActionRepository_FindPendingByCorporateActionID_Call struct {
 }
 
 // FindPendingByCorporateActionID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - corporateActionID string
-func (_e *PortfolioActionRepository_Expecter) FindPendingByCorporateActionID(corporateActionID interface{}) *PortfolioActionRepository_FindPendingByCorporateActionID_Call {
-	return &PortfolioActionRepository_FindPendingByCorporateActionID_Call{Call: _e.mock.On("FindPendingByCorporateActionID", corporateActionID)}
+func (_e *PortfolioActionRepository_Expecter) FindPendingByCorporateActionID(ctx interface{}, corporateActionID interface{}) *PortfolioActionRepository_FindPendingByCorporateActionID_Call {
+	return &PortfolioActionRepository_FindPendingByCorporateActionID_Call{Call: _e.mock.On("FindPendingByCorporateActionID", ctx, corporateActionID)}
 }
 
-func (_c *PortfolioActionRepository_FindPendingByCorporateActionID_Call) Run(run func(corporateActionID string)) *PortfolioActionRepository_FindPendingByCorporateActionID_Call {
+func (_c *PortfolioActionRepository_FindPendingByCorporateActionID_Call) Run(run func(ctx context.Context, corporateActionID string)) *PortfolioActionRepository_FindPendingByCorporateActionID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -397,14 +406,14 @@ func (_c *PortfolioActionRepository_FindPendingByCorporateActionID_Call) Return(
 	return _c
 }
 
-func (_c *PortfolioActionRepository_FindPendingByCorporateActionID_Call) RunAndReturn(run func(string) ([]*models.PortfolioAction, error)) *PortfolioActionRepository_FindPendingByCorporateActionID_Call {
+func (_c *PortfolioActionRepository_FindPendingByCorporateActionID_Call) RunAndReturn(run func(context.Context, string) ([]*models.PortfolioAction, error)) *PortfolioActionRepository_FindPendingByCorporateActionID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindPendingByPortfolioID provides a mock function with given fields: portfolioID
-func (_m *PortfolioActionRepository) FindPendingByPortfolioID(portfolioID string) ([]*models.PortfolioAction, error) {
-	ret := _m.Called(portfolioID)
+// FindPendingByPortfolioID provides a mock function with given fields: ctx, portfolioID
+func (_m *PortfolioActionRepository) FindPendingByPortfolioID(ctx context.Context, portfolioID string) ([]*models.PortfolioAction, error) {
+	ret := _m.Called(ctx, portfolioID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindPendingByPortfolioID")
@@ -412,19 +421,19 @@ func (_m *PortfolioActionRepository) FindPendingByPortfolioID(portfolioID string
 
 	var r0 []*models.PortfolioAction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.PortfolioAction, error)); ok {
-		return rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.PortfolioAction, error)); ok {
+		return rf(ctx, portfolioID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.PortfolioAction); ok {
-		r0 = rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.PortfolioAction); ok {
+		r0 = rf(ctx, portfolioID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.PortfolioAction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(portfolioID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, portfolioID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -438,14 +447,15 @@ type PortfolioActionRepository_FindPendingByPortfolioID_Call struct {
 }
 
 // FindPendingByPortfolioID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
-func (_e *PortfolioActionRepository_Expecter) FindPendingByPortfolioID(portfolioID interface{}) *PortfolioActionRepository_FindPendingByPortfolioID_Call {
-	return &PortfolioActionRepository_FindPendingByPortfolioID_Call{Call: _e.mock.On("FindPendingByPortfolioID", portfolioID)}
+func (_e *PortfolioActionRepository_Expecter) FindPendingByPortfolioID(ctx interface{}, portfolioID interface{}) *PortfolioActionRepository_FindPendingByPortfolioID_Call {
+	return &PortfolioActionRepository_FindPendingByPortfolioID_Call{Call: _e.mock.On("FindPendingByPortfolioID", ctx, portfolioID)}
 }
 
-func (_c *PortfolioActionRepository_FindPendingByPortfolioID_Call) Run(run func(portfolioID string)) *PortfolioActionRepository_FindPendingByPortfolioID_Call {
+func (_c *PortfolioActionRepository_FindPendingByPortfolioID_Call) Run(run func(ctx context.Context, portfolioID string)) *PortfolioActionRepository_FindPendingByPortfolioID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -455,22 +465,22 @@ func (_c *PortfolioActionRepository_FindPendingByPortfolioID_Call) Return(_a0 []
 	return _c
 }
 
-func (_c *PortfolioActionRepository_FindPendingByPortfolioID_Call) RunAndReturn(run func(string) ([]*models.PortfolioAction, error)) *PortfolioActionRepository_FindPendingByPortfolioID_Call {
+func (_c *PortfolioActionRepository_FindPendingByPortfolioID_Call) RunAndReturn(run func(context.Context, string) ([]*models.PortfolioAction, error)) *PortfolioActionRepository_FindPendingByPortfolioID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: action
-func (_m *PortfolioActionRepository) Update(action *models.PortfolioAction) error {
-	ret := _m.Called(action)
+// Update provides a mock function with given fields: ctx, action
+func (_m *PortfolioActionRepository) Update(ctx context.Context, action *models.PortfolioAction) error {
+	ret := _m.Called(ctx, action)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.PortfolioAction) error); ok {
-		r0 = rf(action)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PortfolioAction) error); ok {
+		r0 = rf(ctx, action)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -484,14 +494,15 @@ type PortfolioActionRepository_Update_Call struct {
 }
 
 // Update is a helper method to define mock.On call
+//   - ctx context.Context
 //   - action *models.PortfolioAction
-func (_e *PortfolioActionRepository_Expecter) Update(action interface{}) *PortfolioActionRepository_Update_Call {
-	return &PortfolioActionRepository_Update_Call{Call: _e.mock.On("Update", action)}
+func (_e *PortfolioActionRepository_Expecter) Update(ctx interface{}, action interface{}) *PortfolioActionRepository_Update_Call {
+	return &PortfolioActionRepository_Update_Call{Call: _e.mock.On("Update", ctx, action)}
 }
 
-func (_c *PortfolioActionRepository_Update_Call) Run(run func(action *models.PortfolioAction)) *PortfolioActionRepository_Update_Call {
+func (_c *PortfolioActionRepository_Update_Call) Run(run func(ctx context.Context, action *models.PortfolioAction)) *PortfolioActionRepository_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.PortfolioAction))
+		run(args[0].(context.Context), args[1].(*models.PortfolioAction))
 	})
 	return _c
 }
@@ -501,7 +512,7 @@ func (_c *PortfolioActionRepository_Update_Call) Return(_a0 error) *PortfolioAct
 	return _c
 }
 
-func (_c *PortfolioActionRepository_Update_Call) RunAndReturn(run func(*models.PortfolioAction) error) *PortfolioActionRepository_Update_Call {
+func (_c *PortfolioActionRepository_Update_Call) RunAndReturn(run func(context.Context, *models.PortfolioAction) error) *PortfolioActionRepository_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -3,8 +3,12 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/lenon/portfolios/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
 )
 
 // PortfolioRepository is an autogenerated mock type for the PortfolioRepository type
@@ -20,17 +24,17 @@ func (_m *PortfolioRepository) EXPECT() *PortfolioRepository_Expecter {
 	return &PortfolioRepository_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: portfolio
-func (_m *PortfolioRepository) Create(portfolio *models.Portfolio) error {
-	ret := _m.Called(portfolio)
+// Create provides a mock function with given fields: ctx, portfolio
+func (_m *PortfolioRepository) Create(ctx context.Context, portfolio *models.Portfolio) error {
+	ret := _m.Called(ctx, portfolio)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Portfolio) error); ok {
-		r0 = rf(portfolio)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Portfolio) error); ok {
+		r0 = rf(ctx, portfolio)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -44,14 +48,15 @@ type PortfolioRepository_Create_Call struct {
 }
 
 // Create is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolio *models.Portfolio
-func (_e *PortfolioRepository_Expecter) Create(portfolio interface{}) *PortfolioRepository_Create_Call {
-	return &PortfolioRepository_Create_Call{Call: _e.mock.On("Create", portfolio)}
+func (_e *PortfolioRepository_Expecter) Create(ctx interface{}, portfolio interface{}) *PortfolioRepository_Create_Call {
+	return &PortfolioRepository_Create_Call{Call: _e.mock.On("Create", ctx, portfolio)}
 }
 
-func (_c *PortfolioRepository_Create_Call) Run(run func(portfolio *models.Portfolio)) *PortfolioRepository_Create_Call {
+func (_c *PortfolioRepository_Create_Call) Run(run func(ctx context.Context, portfolio *models.Portfolio)) *PortfolioRepository_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Portfolio))
+		run(args[0].(context.Context), args[1].(*models.Portfolio))
 	})
 	return _c
 }
@@ -61,22 +66,22 @@ func (_c *PortfolioRepository_Create_Call) Return(_a0 error) *PortfolioRepositor
 	return _c
 }
 
-func (_c *PortfolioRepository_Create_Call) RunAndReturn(run func(*models.Portfolio) error) *PortfolioRepository_Create_Call {
+func (_c *PortfolioRepository_Create_Call) RunAndReturn(run func(context.Context, *models.Portfolio) error) *PortfolioRepository_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *PortfolioRepository) Delete(id string) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, id
+func (_m *PortfolioRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,14 +95,15 @@ type PortfolioRepository_Delete_Call struct {
 }
 
 // Delete is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *PortfolioRepository_Expecter) Delete(id interface{}) *PortfolioRepository_Delete_Call {
-	return &PortfolioRepository_Delete_Call{Call: _e.mock.On("Delete", id)}
+func (_e *PortfolioRepository_Expecter) Delete(ctx interface{}, id interface{}) *PortfolioRepository_Delete_Call {
+	return &PortfolioRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
 }
 
-func (_c *PortfolioRepository_Delete_Call) Run(run func(id string)) *PortfolioRepository_Delete_Call {
+func (_c *PortfolioRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *PortfolioRepository_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -107,14 +113,14 @@ func (_c *PortfolioRepository_Delete_Call) Return(_a0 error) *PortfolioRepositor
 	return _c
 }
 
-func (_c *PortfolioRepository_Delete_Call) RunAndReturn(run func(string) error) *PortfolioRepository_Delete_Call {
+func (_c *PortfolioRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *PortfolioRepository_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ExistsByUserIDAndName provides a mock function with given fields: userID, name
-func (_m *PortfolioRepository) ExistsByUserIDAndName(userID string, name string) (bool, error) {
-	ret := _m.Called(userID, name)
+// ExistsByUserIDAndName provides a mock function with given fields: ctx, userID, name
+func (_m *PortfolioRepository) ExistsByUserIDAndName(ctx context.Context, userID string, name string) (bool, error) {
+	ret := _m.Called(ctx, userID, name)
 
 	if len(ret) == 0 {
 		panic("no return value specified for ExistsByUserIDAndName")
@@ -122,17 +128,17 @@ func (_m *PortfolioRepository) ExistsByUserIDAndName(userID string, name string)
 
 	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (bool, error)); ok {
-		return rf(userID, name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (bool, error)); ok {
+		return rf(ctx, userID, name)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) bool); ok {
-		r0 = rf(userID, name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) bool); ok {
+		r0 = rf(ctx, userID, name)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(userID, name)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -146,15 +152,16 @@ type PortfolioRepository_ExistsByUserIDAndName_Call struct {
 }
 
 // ExistsByUserIDAndName is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID string
 //   - name string
-func (_e *PortfolioRepository_Expecter) ExistsByUserIDAndName(userID interface{}, name interface{}) *PortfolioRepository_ExistsByUserIDAndName_Call {
-	return &PortfolioRepository_ExistsByUserIDAndName_Call{Call: _e.mock.On("ExistsByUserIDAndName", userID, name)}
+func (_e *PortfolioRepository_Expecter) ExistsByUserIDAndName(ctx interface{}, userID interface{}, name interface{}) *PortfolioRepository_ExistsByUserIDAndName_Call {
+	return &PortfolioRepository_ExistsByUserIDAndName_Call{Call: _e.mock.On("ExistsByUserIDAndName", ctx, userID, name)}
 }
 
-func (_c *PortfolioRepository_ExistsByUserIDAndName_Call) Run(run func(userID string, name string)) *PortfolioRepository_ExistsByUserIDAndName_Call {
+func (_c *PortfolioRepository_ExistsByUserIDAndName_Call) Run(run func(ctx context.Context, userID string, name string)) *PortfolioRepository_ExistsByUserIDAndName_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -164,14 +171,72 @@ func (_c *PortfolioRepository_ExistsByUserIDAndName_Call) Return(_a0 bool, _a1 e
 	return _c
 }
 
-func (_c *PortfolioRepository_ExistsByUserIDAndName_Call) RunAndReturn(run func(string, string) (bool, error)) *PortfolioRepository_ExistsByUserIDAndName_Call {
+func (_c *PortfolioRepository_ExistsByUserIDAndName_Call) RunAndReturn(run func(context.Context, string, string) (bool, error)) *PortfolioRepository_ExistsByUserIDAndName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindAll provides a mock function with given fields: ctx
+func (_m *PortfolioRepository) FindAll(ctx context.Context) ([]*models.Portfolio, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAll")
+	}
+
+	var r0 []*models.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.Portfolio, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.Portfolio); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PortfolioRepository_FindAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAll'
+type PortfolioRepository_FindAll_Call struct {
+	*mock.Call
+}
+
+// FindAll is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *PortfolioRepository_Expecter) FindAll(ctx interface{}) *PortfolioRepository_FindAll_Call {
+	return &PortfolioRepository_FindAll_Call{Call: _e.mock.On("FindAll", ctx)}
+}
+
+func (_c *PortfolioRepository_FindAll_Call) Run(run func(ctx context.Context)) *PortfolioRepository_FindAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *PortfolioRepository_FindAll_Call) Return(_a0 []*models.Portfolio, _a1 error) *PortfolioRepository_FindAll_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PortfolioRepository_FindAll_Call) RunAndReturn(run func(context.Context) ([]*models.Portfolio, error)) *PortfolioRepository_FindAll_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByID provides a mock function with given fields: id
-func (_m *PortfolioRepository) FindByID(id string) (*models.Portfolio, error) {
-	ret := _m.Called(id)
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *PortfolioRepository) FindByID(ctx context.Context, id string) (*models.Portfolio, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByID")
@@ -179,19 +244,19 @@ func (_m *PortfolioRepository) FindByID(id string) (*models.Portfolio, error) {
 
 	var r0 *models.Portfolio
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*models.Portfolio, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Portfolio, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *models.Portfolio); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Portfolio); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Portfolio)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -205,14 +270,15 @@ type PortfolioRepository_FindByID_Call struct {
 }
 
 // FindByID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *PortfolioRepository_Expecter) FindByID(id interface{}) *PortfolioRepository_FindByID_Call {
-	return &PortfolioRepository_FindByID_Call{Call: _e.mock.On("FindByID", id)}
+func (_e *PortfolioRepository_Expecter) FindByID(ctx interface{}, id interface{}) *PortfolioRepository_FindByID_Call {
+	return &PortfolioRepository_FindByID_Call{Call: _e.mock.On("FindByID", ctx, id)}
 }
 
-func (_c *PortfolioRepository_FindByID_Call) Run(run func(id string)) *PortfolioRepository_FindByID_Call {
+func (_c *PortfolioRepository_FindByID_Call) Run(run func(ctx context.Context, id string)) *PortfolioRepository_FindByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -222,14 +288,73 @@ func (_c *PortfolioRepository_FindByID_Call) Return(_a0 *models.Portfolio, _a1 e
 	return _c
 }
 
-func (_c *PortfolioRepository_FindByID_Call) RunAndReturn(run func(string) (*models.Portfolio, error)) *PortfolioRepository_FindByID_Call {
+func (_c *PortfolioRepository_FindByID_Call) RunAndReturn(run func(context.Context, string) (*models.Portfolio, error)) *PortfolioRepository_FindByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByUserID provides a mock function with given fields: userID
-func (_m *PortfolioRepository) FindByUserID(userID string) ([]*models.Portfolio, error) {
-	ret := _m.Called(userID)
+// FindByOrganizationID provides a mock function with given fields: ctx, organizationID
+func (_m *PortfolioRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*models.Portfolio, error) {
+	ret := _m.Called(ctx, organizationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByOrganizationID")
+	}
+
+	var r0 []*models.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) ([]*models.Portfolio, error)); ok {
+		return rf(ctx, organizationID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID) []*models.Portfolio); ok {
+		r0 = rf(ctx, organizationID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID) error); ok {
+		r1 = rf(ctx, organizationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PortfolioRepository_FindByOrganizationID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByOrganizationID'
+type PortfolioRepository_FindByOrganizationID_Call struct {
+	*mock.Call
+}
+
+// FindByOrganizationID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organizationID uuid.UUID
+func (_e *PortfolioRepository_Expecter) FindByOrganizationID(ctx interface{}, organizationID interface{}) *PortfolioRepository_FindByOrganizationID_Call {
+	return &PortfolioRepository_FindByOrganizationID_Call{Call: _e.mock.On("FindByOrganizationID", ctx, organizationID)}
+}
+
+func (_c *PortfolioRepository_FindByOrganizationID_Call) Run(run func(ctx context.Context, organizationID uuid.UUID)) *PortfolioRepository_FindByOrganizationID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *PortfolioRepository_FindByOrganizationID_Call) Return(_a0 []*models.Portfolio, _a1 error) *PortfolioRepository_FindByOrganizationID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PortfolioRepository_FindByOrganizationID_Call) RunAndReturn(run func(context.Context, uuid.UUID) ([]*models.Portfolio, error)) *PortfolioRepository_FindByOrganizationID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindByUserID provides a mock function with given fields: ctx, userID
+func (_m *PortfolioRepository) FindByUserID(ctx context.Context, userID string) ([]*models.Portfolio, error) {
+	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByUserID")
@@ -237,19 +362,19 @@ func (_m *PortfolioRepository) FindByUserID(userID string) ([]*models.Portfolio,
 
 	var r0 []*models.Portfolio
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.Portfolio, error)); ok {
-		return rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.Portfolio, error)); ok {
+		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.Portfolio); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.Portfolio); ok {
+		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Portfolio)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -263,14 +388,15 @@ type PortfolioRepository_FindByUserID_Call struct {
 }
 
 // FindByUserID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID string
-func (_e *PortfolioRepository_Expecter) FindByUserID(userID interface{}) *PortfolioRepository_FindByUserID_Call {
-	return &PortfolioRepository_FindByUserID_Call{Call: _e.mock.On("FindByUserID", userID)}
+func (_e *PortfolioRepository_Expecter) FindByUserID(ctx interface{}, userID interface{}) *PortfolioRepository_FindByUserID_Call {
+	return &PortfolioRepository_FindByUserID_Call{Call: _e.mock.On("FindByUserID", ctx, userID)}
 }
 
-func (_c *PortfolioRepository_FindByUserID_Call) Run(run func(userID string)) *PortfolioRepository_FindByUserID_Call {
+func (_c *PortfolioRepository_FindByUserID_Call) Run(run func(ctx context.Context, userID string)) *PortfolioRepository_FindByUserID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -280,14 +406,14 @@ func (_c *PortfolioRepository_FindByUserID_Call) Return(_a0 []*models.Portfolio,
 	return _c
 }
 
-func (_c *PortfolioRepository_FindByUserID_Call) RunAndReturn(run func(string) ([]*models.Portfolio, error)) *PortfolioRepository_FindByUserID_Call {
+func (_c *PortfolioRepository_FindByUserID_Call) RunAndReturn(run func(context.Context, string) ([]*models.Portfolio, error)) *PortfolioRepository_FindByUserID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByUserIDAndName provides a mock function with given fields: userID, name
-func (_m *PortfolioRepository) FindByUserIDAndName(userID string, name string) (*models.Portfolio, error) {
-	ret := _m.Called(userID, name)
+// FindByUserIDAndName provides a mock function with given fields: ctx, userID, name
+func (_m *PortfolioRepository) FindByUserIDAndName(ctx context.Context, userID string, name string) (*models.Portfolio, error) {
+	ret := _m.Called(ctx, userID, name)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByUserIDAndName")
@@ -295,19 +421,19 @@ func (_m *PortfolioRepository) FindByUserIDAndName(userID string, name string) (
 
 	var r0 *models.Portfolio
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (*models.Portfolio, error)); ok {
-		return rf(userID, name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*models.Portfolio, error)); ok {
+		return rf(ctx, userID, name)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) *models.Portfolio); ok {
-		r0 = rf(userID, name)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *models.Portfolio); ok {
+		r0 = rf(ctx, userID, name)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Portfolio)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(userID, name)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, userID, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -321,15 +447,16 @@ type PortfolioRepository_FindByUserIDAndName_Call struct {
 }
 
 // FindByUserIDAndName is a helper method to define mock.On call
+//   - ctx context.Context
 //   - userID string
 //   - name string
-func (_e *PortfolioRepository_Expecter) FindByUserIDAndName(userID interface{}, name interface{}) *PortfolioRepository_FindByUserIDAndName_Call {
-	return &PortfolioRepository_FindByUserIDAndName_Call{Call: _e.mock.On("FindByUserIDAndName", userID, name)}
+func (_e *PortfolioRepository_Expecter) FindByUserIDAndName(ctx interface{}, userID interface{}, name interface{}) *PortfolioRepository_FindByUserIDAndName_Call {
+	return &PortfolioRepository_FindByUserIDAndName_Call{Call: _e.mock.On("FindByUserIDAndName", ctx, userID, name)}
 }
 
-func (_c *PortfolioRepository_FindByUserIDAndName_Call) Run(run func(userID string, name string)) *PortfolioRepository_FindByUserIDAndName_Call {
+func (_c *PortfolioRepository_FindByUserIDAndName_Call) Run(run func(ctx context.Context, userID string, name string)) *PortfolioRepository_FindByUserIDAndName_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -339,22 +466,187 @@ func (_c *PortfolioRepository_FindByUserIDAndName_Call) Return(_a0 *models.Portf
 	return _c
 }
 
-func (_c *PortfolioRepository_FindByUserIDAndName_Call) RunAndReturn(run func(string, string) (*models.Portfolio, error)) *PortfolioRepository_FindByUserIDAndName_Call {
+func (_c *PortfolioRepository_FindByUserIDAndName_Call) RunAndReturn(run func(context.Context, string, string) (*models.Portfolio, error)) *PortfolioRepository_FindByUserIDAndName_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindPeerComparisonOptedIn provides a mock function with given fields: ctx
+func (_m *PortfolioRepository) FindPeerComparisonOptedIn(ctx context.Context) ([]*models.Portfolio, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindPeerComparisonOptedIn")
+	}
+
+	var r0 []*models.Portfolio
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.Portfolio, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.Portfolio); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Portfolio)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PortfolioRepository_FindPeerComparisonOptedIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindPeerComparisonOptedIn'
+type PortfolioRepository_FindPeerComparisonOptedIn_Call struct {
+	*mock.Call
+}
+
+// FindPeerComparisonOptedIn is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *PortfolioRepository_Expecter) FindPeerComparisonOptedIn(ctx interface{}) *PortfolioRepository_FindPeerComparisonOptedIn_Call {
+	return &PortfolioRepository_FindPeerComparisonOptedIn_Call{Call: _e.mock.On("FindPeerComparisonOptedIn", ctx)}
+}
+
+func (_c *PortfolioRepository_FindPeerComparisonOptedIn_Call) Run(run func(ctx context.Context)) *PortfolioRepository_FindPeerComparisonOptedIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *PortfolioRepository_FindPeerComparisonOptedIn_Call) Return(_a0 []*models.Portfolio, _a1 error) *PortfolioRepository_FindPeerComparisonOptedIn_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PortfolioRepository_FindPeerComparisonOptedIn_Call) RunAndReturn(run func(context.Context) ([]*models.Portfolio, error)) *PortfolioRepository_FindPeerComparisonOptedIn_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReassignOrganizationPortfolios provides a mock function with given fields: ctx, organizationID, fromUserID, toUserID
+func (_m *PortfolioRepository) ReassignOrganizationPortfolios(ctx context.Context, organizationID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID) (int64, error) {
+	ret := _m.Called(ctx, organizationID, fromUserID, toUserID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReassignOrganizationPortfolios")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (int64, error)); ok {
+		return rf(ctx, organizationID, fromUserID, toUserID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) int64); ok {
+		r0 = rf(ctx, organizationID, fromUserID, toUserID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) error); ok {
+		r1 = rf(ctx, organizationID, fromUserID, toUserID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PortfolioRepository_ReassignOrganizationPortfolios_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReassignOrganizationPortfolios'
+type PortfolioRepository_ReassignOrganizationPortfolios_Call struct {
+	*mock.Call
+}
+
+// ReassignOrganizationPortfolios is a helper method to define mock.On call
+//   - ctx context.Context
+//   - organizationID uuid.UUID
+//   - fromUserID uuid.UUID
+//   - toUserID uuid.UUID
+func (_e *PortfolioRepository_Expecter) ReassignOrganizationPortfolios(ctx interface{}, organizationID interface{}, fromUserID interface{}, toUserID interface{}) *PortfolioRepository_ReassignOrganizationPortfolios_Call {
+	return &PortfolioRepository_ReassignOrganizationPortfolios_Call{Call: _e.mock.On("ReassignOrganizationPortfolios", ctx, organizationID, fromUserID, toUserID)}
+}
+
+func (_c *PortfolioRepository_ReassignOrganizationPortfolios_Call) Run(run func(ctx context.Context, organizationID uuid.UUID, fromUserID uuid.UUID, toUserID uuid.UUID)) *PortfolioRepository_ReassignOrganizationPortfolios_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(uuid.UUID), args[3].(uuid.UUID))
+	})
+	return _c
+}
+
+func (_c *PortfolioRepository_ReassignOrganizationPortfolios_Call) Return(_a0 int64, _a1 error) *PortfolioRepository_ReassignOrganizationPortfolios_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PortfolioRepository_ReassignOrganizationPortfolios_Call) RunAndReturn(run func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (int64, error)) *PortfolioRepository_ReassignOrganizationPortfolios_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetPeerComparisonOptIn provides a mock function with given fields: ctx, id, optIn
+func (_m *PortfolioRepository) SetPeerComparisonOptIn(ctx context.Context, id string, optIn bool) error {
+	ret := _m.Called(ctx, id, optIn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPeerComparisonOptIn")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bool) error); ok {
+		r0 = rf(ctx, id, optIn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PortfolioRepository_SetPeerComparisonOptIn_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetPeerComparisonOptIn'
+type PortfolioRepository_SetPeerComparisonOptIn_Call struct {
+	*mock.Call
+}
+
+// SetPeerComparisonOptIn is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - optIn bool
+func (_e *PortfolioRepository_Expecter) SetPeerComparisonOptIn(ctx interface{}, id interface{}, optIn interface{}) *PortfolioRepository_SetPeerComparisonOptIn_Call {
+	return &PortfolioRepository_SetPeerComparisonOptIn_Call{Call: _e.mock.On("SetPeerComparisonOptIn", ctx, id, optIn)}
+}
+
+func (_c *PortfolioRepository_SetPeerComparisonOptIn_Call) Run(run func(ctx context.Context, id string, optIn bool)) *PortfolioRepository_SetPeerComparisonOptIn_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *PortfolioRepository_SetPeerComparisonOptIn_Call) Return(_a0 error) *PortfolioRepository_SetPeerComparisonOptIn_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PortfolioRepository_SetPeerComparisonOptIn_Call) RunAndReturn(run func(context.Context, string, bool) error) *PortfolioRepository_SetPeerComparisonOptIn_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: portfolio
-func (_m *PortfolioRepository) Update(portfolio *models.Portfolio) error {
-	ret := _m.Called(portfolio)
+// Update provides a mock function with given fields: ctx, portfolio
+func (_m *PortfolioRepository) Update(ctx context.Context, portfolio *models.Portfolio) error {
+	ret := _m.Called(ctx, portfolio)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Portfolio) error); ok {
-		r0 = rf(portfolio)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Portfolio) error); ok {
+		r0 = rf(ctx, portfolio)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -368,14 +660,15 @@ type PortfolioRepository_Update_Call struct {
 }
 
 // Update is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolio *models.Portfolio
-func (_e *PortfolioRepository_Expecter) Update(portfolio interface{}) *PortfolioRepository_Update_Call {
-	return &PortfolioRepository_Update_Call{Call: _e.mock.On("Update", portfolio)}
+func (_e *PortfolioRepository_Expecter) Update(ctx interface{}, portfolio interface{}) *PortfolioRepository_Update_Call {
+	return &PortfolioRepository_Update_Call{Call: _e.mock.On("Update", ctx, portfolio)}
 }
 
-func (_c *PortfolioRepository_Update_Call) Run(run func(portfolio *models.Portfolio)) *PortfolioRepository_Update_Call {
+func (_c *PortfolioRepository_Update_Call) Run(run func(ctx context.Context, portfolio *models.Portfolio)) *PortfolioRepository_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Portfolio))
+		run(args[0].(context.Context), args[1].(*models.Portfolio))
 	})
 	return _c
 }
@@ -385,7 +678,7 @@ func (_c *PortfolioRepository_Update_Call) Return(_a0 error) *PortfolioRepositor
 	return _c
 }
 
-func (_c *PortfolioRepository_Update_Call) RunAndReturn(run func(*models.Portfolio) error) *PortfolioRepository_Update_Call {
+func (_c *PortfolioRepository_Update_Call) RunAndReturn(run func(context.Context, *models.Portfolio) error) *PortfolioRepository_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/lenon/portfolios/internal/models"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -20,17 +22,17 @@ func (_m *TaxLotRepository) EXPECT() *TaxLotRepository_Expecter {
 	return &TaxLotRepository_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: taxLot
-func (_m *TaxLotRepository) Create(taxLot *models.TaxLot) error {
-	ret := _m.Called(taxLot)
+// Create provides a mock function with given fields: ctx, taxLot
+func (_m *TaxLotRepository) Create(ctx context.Context, taxLot *models.TaxLot) error {
+	ret := _m.Called(ctx, taxLot)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.TaxLot) error); ok {
-		r0 = rf(taxLot)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.TaxLot) error); ok {
+		r0 = rf(ctx, taxLot)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -44,14 +46,15 @@ type TaxLotRepository_Create_Call struct {
 }
 
 // Create is a helper method to define mock.On call
+//   - ctx context.Context
 //   - taxLot *models.TaxLot
-func (_e *TaxLotRepository_Expecter) Create(taxLot interface{}) *TaxLotRepository_Create_Call {
-	return &TaxLotRepository_Create_Call{Call: _e.mock.On("Create", taxLot)}
+func (_e *TaxLotRepository_Expecter) Create(ctx interface{}, taxLot interface{}) *TaxLotRepository_Create_Call {
+	return &TaxLotRepository_Create_Call{Call: _e.mock.On("Create", ctx, taxLot)}
 }
 
-func (_c *TaxLotRepository_Create_Call) Run(run func(taxLot *models.TaxLot)) *TaxLotRepository_Create_Call {
+func (_c *TaxLotRepository_Create_Call) Run(run func(ctx context.Context, taxLot *models.TaxLot)) *TaxLotRepository_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.TaxLot))
+		run(args[0].(context.Context), args[1].(*models.TaxLot))
 	})
 	return _c
 }
@@ -61,22 +64,22 @@ func (_c *TaxLotRepository_Create_Call) Return(_a0 error) *TaxLotRepository_Crea
 	return _c
 }
 
-func (_c *TaxLotRepository_Create_Call) RunAndReturn(run func(*models.TaxLot) error) *TaxLotRepository_Create_Call {
+func (_c *TaxLotRepository_Create_Call) RunAndReturn(run func(context.Context, *models.TaxLot) error) *TaxLotRepository_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *TaxLotRepository) Delete(id string) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, id
+func (_m *TaxLotRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -90,14 +93,15 @@ type TaxLotRepository_Delete_Call struct {
 }
 
 // Delete is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *TaxLotRepository_Expecter) Delete(id interface{}) *TaxLotRepository_Delete_Call {
-	return &TaxLotRepository_Delete_Call{Call: _e.mock.On("Delete", id)}
+func (_e *TaxLotRepository_Expecter) Delete(ctx interface{}, id interface{}) *TaxLotRepository_Delete_Call {
+	return &TaxLotRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
 }
 
-func (_c *TaxLotRepository_Delete_Call) Run(run func(id string)) *TaxLotRepository_Delete_Call {
+func (_c *TaxLotRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *TaxLotRepository_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -107,22 +111,22 @@ func (_c *TaxLotRepository_Delete_Call) Return(_a0 error) *TaxLotRepository_Dele
 	return _c
 }
 
-func (_c *TaxLotRepository_Delete_Call) RunAndReturn(run func(string) error) *TaxLotRepository_Delete_Call {
+func (_c *TaxLotRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *TaxLotRepository_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteByPortfolioIDAndSymbol provides a mock function with given fields: portfolioID, symbol
-func (_m *TaxLotRepository) DeleteByPortfolioIDAndSymbol(portfolioID string, symbol string) error {
-	ret := _m.Called(portfolioID, symbol)
+// DeleteByPortfolioIDAndSymbol provides a mock function with given fields: ctx, portfolioID, symbol
+func (_m *TaxLotRepository) DeleteByPortfolioIDAndSymbol(ctx context.Context, portfolioID string, symbol string) error {
+	ret := _m.Called(ctx, portfolioID, symbol)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteByPortfolioIDAndSymbol")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(portfolioID, symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, portfolioID, symbol)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -136,15 +140,16 @@ type TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call struct {
 }
 
 // DeleteByPortfolioIDAndSymbol is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
 //   - symbol string
-func (_e *TaxLotRepository_Expecter) DeleteByPortfolioIDAndSymbol(portfolioID interface{}, symbol interface{}) *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call {
-	return &TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call{Call: _e.mock.On("DeleteByPortfolioIDAndSymbol", portfolioID, symbol)}
+func (_e *TaxLotRepository_Expecter) DeleteByPortfolioIDAndSymbol(ctx interface{}, portfolioID interface{}, symbol interface{}) *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call {
+	return &TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call{Call: _e.mock.On("DeleteByPortfolioIDAndSymbol", ctx, portfolioID, symbol)}
 }
 
-func (_c *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call) Run(run func(portfolioID string, symbol string)) *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call {
+func (_c *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call) Run(run func(ctx context.Context, portfolioID string, symbol string)) *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -154,14 +159,14 @@ func (_c *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call) Return(_a0 error)
 	return _c
 }
 
-func (_c *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call) RunAndReturn(run func(string, string) error) *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call {
+func (_c *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call) RunAndReturn(run func(context.Context, string, string) error) *TaxLotRepository_DeleteByPortfolioIDAndSymbol_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByID provides a mock function with given fields: id
-func (_m *TaxLotRepository) FindByID(id string) (*models.TaxLot, error) {
-	ret := _m.Called(id)
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *TaxLotRepository) FindByID(ctx context.Context, id string) (*models.TaxLot, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByID")
@@ -169,19 +174,19 @@ func (_m *TaxLotRepository) FindByID(id string) (*models.TaxLot, error) {
 
 	var r0 *models.TaxLot
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*models.TaxLot, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.TaxLot, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *models.TaxLot); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.TaxLot); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.TaxLot)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -195,14 +200,15 @@ type TaxLotRepository_FindByID_Call struct {
 }
 
 // FindByID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *TaxLotRepository_Expecter) FindByID(id interface{}) *TaxLotRepository_FindByID_Call {
-	return &TaxLotRepository_FindByID_Call{Call: _e.mock.On("FindByID", id)}
+func (_e *TaxLotRepository_Expecter) FindByID(ctx interface{}, id interface{}) *TaxLotRepository_FindByID_Call {
+	return &TaxLotRepository_FindByID_Call{Call: _e.mock.On("FindByID", ctx, id)}
 }
 
-func (_c *TaxLotRepository_FindByID_Call) Run(run func(id string)) *TaxLotRepository_FindByID_Call {
+func (_c *TaxLotRepository_FindByID_Call) Run(run func(ctx context.Context, id string)) *TaxLotRepository_FindByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -212,14 +218,14 @@ func (_c *TaxLotRepository_FindByID_Call) Return(_a0 *models.TaxLot, _a1 error)
 	return _c
 }
 
-func (_c *TaxLotRepository_FindByID_Call) RunAndReturn(run func(string) (*models.TaxLot, error)) *TaxLotRepository_FindByID_Call {
+func (_c *TaxLotRepository_FindByID_Call) RunAndReturn(run func(context.Context, string) (*models.TaxLot, error)) *TaxLotRepository_FindByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioID provides a mock function with given fields: portfolioID
-func (_m *TaxLotRepository) FindByPortfolioID(portfolioID string) ([]*models.TaxLot, error) {
-	ret := _m.Called(portfolioID)
+// FindByPortfolioID provides a mock function with given fields: ctx, portfolioID
+func (_m *TaxLotRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.TaxLot, error) {
+	ret := _m.Called(ctx, portfolioID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioID")
@@ -227,19 +233,19 @@ func (_m *TaxLotRepository) FindByPortfolioID(portfolioID string) ([]*models.Tax
 
 	var r0 []*models.TaxLot
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.TaxLot, error)); ok {
-		return rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.TaxLot, error)); ok {
+		return rf(ctx, portfolioID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.TaxLot); ok {
-		r0 = rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.TaxLot); ok {
+		r0 = rf(ctx, portfolioID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.TaxLot)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(portfolioID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, portfolioID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -253,14 +259,15 @@ type TaxLotRepository_FindByPortfolioID_Call struct {
 }
 
 // FindByPortfolioID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
-func (_e *TaxLotRepository_Expecter) FindByPortfolioID(portfolioID interface{}) *TaxLotRepository_FindByPortfolioID_Call {
-	return &TaxLotRepository_FindByPortfolioID_Call{Call: _e.mock.On("FindByPortfolioID", portfolioID)}
+func (_e *TaxLotRepository_Expecter) FindByPortfolioID(ctx interface{}, portfolioID interface{}) *TaxLotRepository_FindByPortfolioID_Call {
+	return &TaxLotRepository_FindByPortfolioID_Call{Call: _e.mock.On("FindByPortfolioID", ctx, portfolioID)}
 }
 
-func (_c *TaxLotRepository_FindByPortfolioID_Call) Run(run func(portfolioID string)) *TaxLotRepository_FindByPortfolioID_Call {
+func (_c *TaxLotRepository_FindByPortfolioID_Call) Run(run func(ctx context.Context, portfolioID string)) *TaxLotRepository_FindByPortfolioID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -270,14 +277,14 @@ func (_c *TaxLotRepository_FindByPortfolioID_Call) Return(_a0 []*models.TaxLot,
 	return _c
 }
 
-func (_c *TaxLotRepository_FindByPortfolioID_Call) RunAndReturn(run func(string) ([]*models.TaxLot, error)) *TaxLotRepository_FindByPortfolioID_Call {
+func (_c *TaxLotRepository_FindByPortfolioID_Call) RunAndReturn(run func(context.Context, string) ([]*models.TaxLot, error)) *TaxLotRepository_FindByPortfolioID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioIDAndSymbol provides a mock function with given fields: portfolioID, symbol
-func (_m *TaxLotRepository) FindByPortfolioIDAndSymbol(portfolioID string, symbol string) ([]*models.TaxLot, error) {
-	ret := _m.Called(portfolioID, symbol)
+// FindByPortfolioIDAndSymbol provides a mock function with given fields: ctx, portfolioID, symbol
+func (_m *TaxLotRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID string, symbol string) ([]*models.TaxLot, error) {
+	ret := _m.Called(ctx, portfolioID, symbol)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioIDAndSymbol")
@@ -285,19 +292,19 @@ func (_m *TaxLotRepository) FindByPortfolioIDAndSymbol(portfolioID string, symbo
 
 	var r0 []*models.TaxLot
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) ([]*models.TaxLot, error)); ok {
-		return rf(portfolioID, symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]*models.TaxLot, error)); ok {
+		return rf(ctx, portfolioID, symbol)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) []*models.TaxLot); ok {
-		r0 = rf(portfolioID, symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*models.TaxLot); ok {
+		r0 = rf(ctx, portfolioID, symbol)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.TaxLot)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(portfolioID, symbol)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, portfolioID, symbol)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -311,15 +318,16 @@ type TaxLotRepository_FindByPortfolioIDAndSymbol_Call struct {
 }
 
 // FindByPortfolioIDAndSymbol is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
 //   - symbol string
-func (_e *TaxLotRepository_Expecter) FindByPortfolioIDAndSymbol(portfolioID interface{}, symbol interface{}) *TaxLotRepository_FindByPortfolioIDAndSymbol_Call {
-	return &TaxLotRepository_FindByPortfolioIDAndSymbol_Call{Call: _e.mock.On("FindByPortfolioIDAndSymbol", portfolioID, symbol)}
+func (_e *TaxLotRepository_Expecter) FindByPortfolioIDAndSymbol(ctx interface{}, portfolioID interface{}, symbol interface{}) *TaxLotRepository_FindByPortfolioIDAndSymbol_Call {
+	return &TaxLotRepository_FindByPortfolioIDAndSymbol_Call{Call: _e.mock.On("FindByPortfolioIDAndSymbol", ctx, portfolioID, symbol)}
 }
 
-func (_c *TaxLotRepository_FindByPortfolioIDAndSymbol_Call) Run(run func(portfolioID string, symbol string)) *TaxLotRepository_FindByPortfolioIDAndSymbol_Call {
+func (_c *TaxLotRepository_FindByPortfolioIDAndSymbol_Call) Run(run func(ctx context.Context, portfolioID string, symbol string)) *TaxLotRepository_FindByPortfolioIDAndSymbol_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -329,14 +337,74 @@ func (_c *TaxLotRepository_FindByPortfolioIDAndSymbol_Call) Return(_a0 []*models
 	return _c
 }
 
-func (_c *TaxLotRepository_FindByPortfolioIDAndSymbol_Call) RunAndReturn(run func(string, string) ([]*models.TaxLot, error)) *TaxLotRepository_FindByPortfolioIDAndSymbol_Call {
+func (_c *TaxLotRepository_FindByPortfolioIDAndSymbol_Call) RunAndReturn(run func(context.Context, string, string) ([]*models.TaxLot, error)) *TaxLotRepository_FindByPortfolioIDAndSymbol_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindByPortfolioIDAndSymbols provides a mock function with given fields: ctx, portfolioID, symbols
+func (_m *TaxLotRepository) FindByPortfolioIDAndSymbols(ctx context.Context, portfolioID string, symbols []string) ([]*models.TaxLot, error) {
+	ret := _m.Called(ctx, portfolioID, symbols)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByPortfolioIDAndSymbols")
+	}
+
+	var r0 []*models.TaxLot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) ([]*models.TaxLot, error)); ok {
+		return rf(ctx, portfolioID, symbols)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string) []*models.TaxLot); ok {
+		r0 = rf(ctx, portfolioID, symbols)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.TaxLot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string) error); ok {
+		r1 = rf(ctx, portfolioID, symbols)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TaxLotRepository_FindByPortfolioIDAndSymbols_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindByPortfolioIDAndSymbols'
+type TaxLotRepository_FindByPortfolioIDAndSymbols_Call struct {
+	*mock.Call
+}
+
+// FindByPortfolioIDAndSymbols is a helper method to define mock.On call
+//   - ctx context.Context
+//   - portfolioID string
+//   - symbols []string
+func (_e *TaxLotRepository_Expecter) FindByPortfolioIDAndSymbols(ctx interface{}, portfolioID interface{}, symbols interface{}) *TaxLotRepository_FindByPortfolioIDAndSymbols_Call {
+	return &TaxLotRepository_FindByPortfolioIDAndSymbols_Call{Call: _e.mock.On("FindByPortfolioIDAndSymbols", ctx, portfolioID, symbols)}
+}
+
+func (_c *TaxLotRepository_FindByPortfolioIDAndSymbols_Call) Run(run func(ctx context.Context, portfolioID string, symbols []string)) *TaxLotRepository_FindByPortfolioIDAndSymbols_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string))
+	})
+	return _c
+}
+
+func (_c *TaxLotRepository_FindByPortfolioIDAndSymbols_Call) Return(_a0 []*models.TaxLot, _a1 error) *TaxLotRepository_FindByPortfolioIDAndSymbols_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *TaxLotRepository_FindByPortfolioIDAndSymbols_Call) RunAndReturn(run func(context.Context, string, []string) ([]*models.TaxLot, error)) *TaxLotRepository_FindByPortfolioIDAndSymbols_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByTransactionID provides a mock function with given fields: transactionID
-func (_m *TaxLotRepository) FindByTransactionID(transactionID string) ([]*models.TaxLot, error) {
-	ret := _m.Called(transactionID)
+// FindByTransactionID provides a mock function with given fields: ctx, transactionID
+func (_m *TaxLotRepository) FindByTransactionID(ctx context.Context, transactionID string) ([]*models.TaxLot, error) {
+	ret := _m.Called(ctx, transactionID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByTransactionID")
@@ -344,19 +412,19 @@ func (_m *TaxLotRepository) FindByTransactionID(transactionID string) ([]*models
 
 	var r0 []*models.TaxLot
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.TaxLot, error)); ok {
-		return rf(transactionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.TaxLot, error)); ok {
+		return rf(ctx, transactionID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.TaxLot); ok {
-		r0 = rf(transactionID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.TaxLot); ok {
+		r0 = rf(ctx, transactionID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.TaxLot)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(transactionID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, transactionID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -370,14 +438,15 @@ type TaxLotRepository_FindByTransactionID_Call struct {
 }
 
 // FindByTransactionID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - transactionID string
-func (_e *TaxLotRepository_Expecter) FindByTransactionID(transactionID interface{}) *TaxLotRepository_FindByTransactionID_Call {
-	return &TaxLotRepository_FindByTransactionID_Call{Call: _e.mock.On("FindByTransactionID", transactionID)}
+func (_e *TaxLotRepository_Expecter) FindByTransactionID(ctx interface{}, transactionID interface{}) *TaxLotRepository_FindByTransactionID_Call {
+	return &TaxLotRepository_FindByTransactionID_Call{Call: _e.mock.On("FindByTransactionID", ctx, transactionID)}
 }
 
-func (_c *TaxLotRepository_FindByTransactionID_Call) Run(run func(transactionID string)) *TaxLotRepository_FindByTransactionID_Call {
+func (_c *TaxLotRepository_FindByTransactionID_Call) Run(run func(ctx context.Context, transactionID string)) *TaxLotRepository_FindByTransactionID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -387,22 +456,22 @@ func (_c *TaxLotRepository_FindByTransactionID_Call) Return(_a0 []*models.TaxLot
 	return _c
 }
 
-func (_c *TaxLotRepository_FindByTransactionID_Call) RunAndReturn(run func(string) ([]*models.TaxLot, error)) *TaxLotRepository_FindByTransactionID_Call {
+func (_c *TaxLotRepository_FindByTransactionID_Call) RunAndReturn(run func(context.Context, string) ([]*models.TaxLot, error)) *TaxLotRepository_FindByTransactionID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: taxLot
-func (_m *TaxLotRepository) Update(taxLot *models.TaxLot) error {
-	ret := _m.Called(taxLot)
+// Update provides a mock function with given fields: ctx, taxLot
+func (_m *TaxLotRepository) Update(ctx context.Context, taxLot *models.TaxLot) error {
+	ret := _m.Called(ctx, taxLot)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.TaxLot) error); ok {
-		r0 = rf(taxLot)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.TaxLot) error); ok {
+		r0 = rf(ctx, taxLot)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -416,14 +485,15 @@ type TaxLotRepository_Update_Call struct {
 }
 
 // Update is a helper method to define mock.On call
+//   - ctx context.Context
 //   - taxLot *models.TaxLot
-func (_e *TaxLotRepository_Expecter) Update(taxLot interface{}) *TaxLotRepository_Update_Call {
-	return &TaxLotRepository_Update_Call{Call: _e.mock.On("Update", taxLot)}
+func (_e *TaxLotRepository_Expecter) Update(ctx interface{}, taxLot interface{}) *TaxLotRepository_Update_Call {
+	return &TaxLotRepository_Update_Call{Call: _e.mock.On("Update", ctx, taxLot)}
 }
 
-func (_c *TaxLotRepository_Update_Call) Run(run func(taxLot *models.TaxLot)) *TaxLotRepository_Update_Call {
+func (_c *TaxLotRepository_Update_Call) Run(run func(ctx context.Context, taxLot *models.TaxLot)) *TaxLotRepository_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.TaxLot))
+		run(args[0].(context.Context), args[1].(*models.TaxLot))
 	})
 	return _c
 }
@@ -433,7 +503,7 @@ func (_c *TaxLotRepository_Update_Call) Return(_a0 error) *TaxLotRepository_Upda
 	return _c
 }
 
-func (_c *TaxLotRepository_Update_Call) RunAndReturn(run func(*models.TaxLot) error) *TaxLotRepository_Update_Call {
+func (_c *TaxLotRepository_Update_Call) RunAndReturn(run func(context.Context, *models.TaxLot) error) *TaxLotRepository_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
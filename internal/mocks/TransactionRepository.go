@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	models "github.com/lenon/portfolios/internal/models"
 	mock "github.com/stretchr/testify/mock"
 
@@ -22,17 +24,17 @@ func (_m *TransactionRepository) EXPECT() *TransactionRepository_Expecter {
 	return &TransactionRepository_Expecter{mock: &_m.Mock}
 }
 
-// Create provides a mock function with given fields: transaction
-func (_m *TransactionRepository) Create(transaction *models.Transaction) error {
-	ret := _m.Called(transaction)
+// Create provides a mock function with given fields: ctx, transaction
+func (_m *TransactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	ret := _m.Called(ctx, transaction)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Create")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Transaction) error); ok {
-		r0 = rf(transaction)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Transaction) error); ok {
+		r0 = rf(ctx, transaction)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -46,14 +48,15 @@ type TransactionRepository_Create_Call struct {
 }
 
 // Create is a helper method to define mock.On call
+//   - ctx context.Context
 //   - transaction *models.Transaction
-func (_e *TransactionRepository_Expecter) Create(transaction interface{}) *TransactionRepository_Create_Call {
-	return &TransactionRepository_Create_Call{Call: _e.mock.On("Create", transaction)}
+func (_e *TransactionRepository_Expecter) Create(ctx interface{}, transaction interface{}) *TransactionRepository_Create_Call {
+	return &TransactionRepository_Create_Call{Call: _e.mock.On("Create", ctx, transaction)}
 }
 
-func (_c *TransactionRepository_Create_Call) Run(run func(transaction *models.Transaction)) *TransactionRepository_Create_Call {
+func (_c *TransactionRepository_Create_Call) Run(run func(ctx context.Context, transaction *models.Transaction)) *TransactionRepository_Create_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Transaction))
+		run(args[0].(context.Context), args[1].(*models.Transaction))
 	})
 	return _c
 }
@@ -63,22 +66,22 @@ func (_c *TransactionRepository_Create_Call) Return(_a0 error) *TransactionRepos
 	return _c
 }
 
-func (_c *TransactionRepository_Create_Call) RunAndReturn(run func(*models.Transaction) error) *TransactionRepository_Create_Call {
+func (_c *TransactionRepository_Create_Call) RunAndReturn(run func(context.Context, *models.Transaction) error) *TransactionRepository_Create_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Delete provides a mock function with given fields: id
-func (_m *TransactionRepository) Delete(id string) error {
-	ret := _m.Called(id)
+// Delete provides a mock function with given fields: ctx, id
+func (_m *TransactionRepository) Delete(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Delete")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -92,14 +95,15 @@ type TransactionRepository_Delete_Call struct {
 }
 
 // Delete is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *TransactionRepository_Expecter) Delete(id interface{}) *TransactionRepository_Delete_Call {
-	return &TransactionRepository_Delete_Call{Call: _e.mock.On("Delete", id)}
+func (_e *TransactionRepository_Expecter) Delete(ctx interface{}, id interface{}) *TransactionRepository_Delete_Call {
+	return &TransactionRepository_Delete_Call{Call: _e.mock.On("Delete", ctx, id)}
 }
 
-func (_c *TransactionRepository_Delete_Call) Run(run func(id string)) *TransactionRepository_Delete_Call {
+func (_c *TransactionRepository_Delete_Call) Run(run func(ctx context.Context, id string)) *TransactionRepository_Delete_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -109,22 +113,22 @@ func (_c *TransactionRepository_Delete_Call) Return(_a0 error) *TransactionRepos
 	return _c
 }
 
-func (_c *TransactionRepository_Delete_Call) RunAndReturn(run func(string) error) *TransactionRepository_Delete_Call {
+func (_c *TransactionRepository_Delete_Call) RunAndReturn(run func(context.Context, string) error) *TransactionRepository_Delete_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// DeleteByImportBatchID provides a mock function with given fields: batchID
-func (_m *TransactionRepository) DeleteByImportBatchID(batchID string) error {
-	ret := _m.Called(batchID)
+// DeleteByImportBatchID provides a mock function with given fields: ctx, batchID
+func (_m *TransactionRepository) DeleteByImportBatchID(ctx context.Context, batchID string) error {
+	ret := _m.Called(ctx, batchID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteByImportBatchID")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string) error); ok {
-		r0 = rf(batchID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, batchID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -138,14 +142,15 @@ type TransactionRepository_DeleteByImportBatchID_Call struct {
 }
 
 // DeleteByImportBatchID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - batchID string
-func (_e *TransactionRepository_Expecter) DeleteByImportBatchID(batchID interface{}) *TransactionRepository_DeleteByImportBatchID_Call {
-	return &TransactionRepository_DeleteByImportBatchID_Call{Call: _e.mock.On("DeleteByImportBatchID", batchID)}
+func (_e *TransactionRepository_Expecter) DeleteByImportBatchID(ctx interface{}, batchID interface{}) *TransactionRepository_DeleteByImportBatchID_Call {
+	return &TransactionRepository_DeleteByImportBatchID_Call{Call: _e.mock.On("DeleteByImportBatchID", ctx, batchID)}
 }
 
-func (_c *TransactionRepository_DeleteByImportBatchID_Call) Run(run func(batchID string)) *TransactionRepository_DeleteByImportBatchID_Call {
+func (_c *TransactionRepository_DeleteByImportBatchID_Call) Run(run func(ctx context.Context, batchID string)) *TransactionRepository_DeleteByImportBatchID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -155,14 +160,14 @@ func (_c *TransactionRepository_DeleteByImportBatchID_Call) Return(_a0 error) *T
 	return _c
 }
 
-func (_c *TransactionRepository_DeleteByImportBatchID_Call) RunAndReturn(run func(string) error) *TransactionRepository_DeleteByImportBatchID_Call {
+func (_c *TransactionRepository_DeleteByImportBatchID_Call) RunAndReturn(run func(context.Context, string) error) *TransactionRepository_DeleteByImportBatchID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByID provides a mock function with given fields: id
-func (_m *TransactionRepository) FindByID(id string) (*models.Transaction, error) {
-	ret := _m.Called(id)
+// FindByID provides a mock function with given fields: ctx, id
+func (_m *TransactionRepository) FindByID(ctx context.Context, id string) (*models.Transaction, error) {
+	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByID")
@@ -170,19 +175,19 @@ func (_m *TransactionRepository) FindByID(id string) (*models.Transaction, error
 
 	var r0 *models.Transaction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*models.Transaction, error)); ok {
-		return rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.Transaction, error)); ok {
+		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(string) *models.Transaction); ok {
-		r0 = rf(id)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.Transaction); ok {
+		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*models.Transaction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(id)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -196,14 +201,15 @@ type TransactionRepository_FindByID_Call struct {
 }
 
 // FindByID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - id string
-func (_e *TransactionRepository_Expecter) FindByID(id interface{}) *TransactionRepository_FindByID_Call {
-	return &TransactionRepository_FindByID_Call{Call: _e.mock.On("FindByID", id)}
+func (_e *TransactionRepository_Expecter) FindByID(ctx interface{}, id interface{}) *TransactionRepository_FindByID_Call {
+	return &TransactionRepository_FindByID_Call{Call: _e.mock.On("FindByID", ctx, id)}
 }
 
-func (_c *TransactionRepository_FindByID_Call) Run(run func(id string)) *TransactionRepository_FindByID_Call {
+func (_c *TransactionRepository_FindByID_Call) Run(run func(ctx context.Context, id string)) *TransactionRepository_FindByID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -213,14 +219,14 @@ func (_c *TransactionRepository_FindByID_Call) Return(_a0 *models.Transaction, _
 	return _c
 }
 
-func (_c *TransactionRepository_FindByID_Call) RunAndReturn(run func(string) (*models.Transaction, error)) *TransactionRepository_FindByID_Call {
+func (_c *TransactionRepository_FindByID_Call) RunAndReturn(run func(context.Context, string) (*models.Transaction, error)) *TransactionRepository_FindByID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioID provides a mock function with given fields: portfolioID
-func (_m *TransactionRepository) FindByPortfolioID(portfolioID string) ([]*models.Transaction, error) {
-	ret := _m.Called(portfolioID)
+// FindByPortfolioID provides a mock function with given fields: ctx, portfolioID
+func (_m *TransactionRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Transaction, error) {
+	ret := _m.Called(ctx, portfolioID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioID")
@@ -228,19 +234,19 @@ func (_m *TransactionRepository) FindByPortfolioID(portfolioID string) ([]*model
 
 	var r0 []*models.Transaction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.Transaction, error)); ok {
-		return rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.Transaction, error)); ok {
+		return rf(ctx, portfolioID)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.Transaction); ok {
-		r0 = rf(portfolioID)
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.Transaction); ok {
+		r0 = rf(ctx, portfolioID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Transaction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(portfolioID)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, portfolioID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -254,14 +260,15 @@ type TransactionRepository_FindByPortfolioID_Call struct {
 }
 
 // FindByPortfolioID is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
-func (_e *TransactionRepository_Expecter) FindByPortfolioID(portfolioID interface{}) *TransactionRepository_FindByPortfolioID_Call {
-	return &TransactionRepository_FindByPortfolioID_Call{Call: _e.mock.On("FindByPortfolioID", portfolioID)}
+func (_e *TransactionRepository_Expecter) FindByPortfolioID(ctx interface{}, portfolioID interface{}) *TransactionRepository_FindByPortfolioID_Call {
+	return &TransactionRepository_FindByPortfolioID_Call{Call: _e.mock.On("FindByPortfolioID", ctx, portfolioID)}
 }
 
-func (_c *TransactionRepository_FindByPortfolioID_Call) Run(run func(portfolioID string)) *TransactionRepository_FindByPortfolioID_Call {
+func (_c *TransactionRepository_FindByPortfolioID_Call) Run(run func(ctx context.Context, portfolioID string)) *TransactionRepository_FindByPortfolioID_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
@@ -271,14 +278,14 @@ func (_c *TransactionRepository_FindByPortfolioID_Call) Return(_a0 []*models.Tra
 	return _c
 }
 
-func (_c *TransactionRepository_FindByPortfolioID_Call) RunAndReturn(run func(string) ([]*models.Transaction, error)) *TransactionRepository_FindByPortfolioID_Call {
+func (_c *TransactionRepository_FindByPortfolioID_Call) RunAndReturn(run func(context.Context, string) ([]*models.Transaction, error)) *TransactionRepository_FindByPortfolioID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioIDAndSymbol provides a mock function with given fields: portfolioID, symbol
-func (_m *TransactionRepository) FindByPortfolioIDAndSymbol(portfolioID string, symbol string) ([]*models.Transaction, error) {
-	ret := _m.Called(portfolioID, symbol)
+// FindByPortfolioIDAndSymbol provides a mock function with given fields: ctx, portfolioID, symbol
+func (_m *TransactionRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID string, symbol string) ([]*models.Transaction, error) {
+	ret := _m.Called(ctx, portfolioID, symbol)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioIDAndSymbol")
@@ -286,19 +293,19 @@ func (_m *TransactionRepository) FindByPortfolioIDAndSymbol(portfolioID string,
 
 	var r0 []*models.Transaction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) ([]*models.Transaction, error)); ok {
-		return rf(portfolioID, symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]*models.Transaction, error)); ok {
+		return rf(ctx, portfolioID, symbol)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) []*models.Transaction); ok {
-		r0 = rf(portfolioID, symbol)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) []*models.Transaction); ok {
+		r0 = rf(ctx, portfolioID, symbol)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Transaction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(portfolioID, symbol)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, portfolioID, symbol)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -312,15 +319,16 @@ type TransactionRepository_FindByPortfolioIDAndSymbol_Call struct {
 }
 
 // FindByPortfolioIDAndSymbol is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
 //   - symbol string
-func (_e *TransactionRepository_Expecter) FindByPortfolioIDAndSymbol(portfolioID interface{}, symbol interface{}) *TransactionRepository_FindByPortfolioIDAndSymbol_Call {
-	return &TransactionRepository_FindByPortfolioIDAndSymbol_Call{Call: _e.mock.On("FindByPortfolioIDAndSymbol", portfolioID, symbol)}
+func (_e *TransactionRepository_Expecter) FindByPortfolioIDAndSymbol(ctx interface{}, portfolioID interface{}, symbol interface{}) *TransactionRepository_FindByPortfolioIDAndSymbol_Call {
+	return &TransactionRepository_FindByPortfolioIDAndSymbol_Call{Call: _e.mock.On("FindByPortfolioIDAndSymbol", ctx, portfolioID, symbol)}
 }
 
-func (_c *TransactionRepository_FindByPortfolioIDAndSymbol_Call) Run(run func(portfolioID string, symbol string)) *TransactionRepository_FindByPortfolioIDAndSymbol_Call {
+func (_c *TransactionRepository_FindByPortfolioIDAndSymbol_Call) Run(run func(ctx context.Context, portfolioID string, symbol string)) *TransactionRepository_FindByPortfolioIDAndSymbol_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
@@ -330,14 +338,14 @@ func (_c *TransactionRepository_FindByPortfolioIDAndSymbol_Call) Return(_a0 []*m
 	return _c
 }
 
-func (_c *TransactionRepository_FindByPortfolioIDAndSymbol_Call) RunAndReturn(run func(string, string) ([]*models.Transaction, error)) *TransactionRepository_FindByPortfolioIDAndSymbol_Call {
+func (_c *TransactionRepository_FindByPortfolioIDAndSymbol_Call) RunAndReturn(run func(context.Context, string, string) ([]*models.Transaction, error)) *TransactionRepository_FindByPortfolioIDAndSymbol_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// FindByPortfolioIDWithFilters provides a mock function with given fields: portfolioID, symbol, startDate, endDate
-func (_m *TransactionRepository) FindByPortfolioIDWithFilters(portfolioID string, symbol *string, startDate *time.Time, endDate *time.Time) ([]*models.Transaction, error) {
-	ret := _m.Called(portfolioID, symbol, startDate, endDate)
+// FindByPortfolioIDWithFilters provides a mock function with given fields: ctx, portfolioID, symbol, startDate, endDate
+func (_m *TransactionRepository) FindByPortfolioIDWithFilters(ctx context.Context, portfolioID string, symbol *string, startDate *time.Time, endDate *time.Time) ([]*models.Transaction, error) {
+	ret := _m.Called(ctx, portfolioID, symbol, startDate, endDate)
 
 	if len(ret) == 0 {
 		panic("no return value specified for FindByPortfolioIDWithFilters")
@@ -345,19 +353,19 @@ func (_m *TransactionRepository) FindByPortfolioIDWithFilters(portfolioID string
 
 	var r0 []*models.Transaction
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, *string, *time.Time, *time.Time) ([]*models.Transaction, error)); ok {
-		return rf(portfolioID, symbol, startDate, endDate)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *string, *time.Time, *time.Time) ([]*models.Transaction, error)); ok {
+		return rf(ctx, portfolioID, symbol, startDate, endDate)
 	}
-	if rf, ok := ret.Get(0).(func(string, *string, *time.Time, *time.Time) []*models.Transaction); ok {
-		r0 = rf(portfolioID, symbol, startDate, endDate)
+	if rf, ok := ret.Get(0).(func(context.Context, string, *string, *time.Time, *time.Time) []*models.Transaction); ok {
+		r0 = rf(ctx, portfolioID, symbol, startDate, endDate)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*models.Transaction)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, *string, *time.Time, *time.Time) error); ok {
-		r1 = rf(portfolioID, symbol, startDate, endDate)
+	if rf, ok := ret.Get(1).(func(context.Context, string, *string, *time.Time, *time.Time) error); ok {
+		r1 = rf(ctx, portfolioID, symbol, startDate, endDate)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -371,17 +379,18 @@ type TransactionRepository_FindByPortfolioIDWithFilters_Call struct {
 }
 
 // FindByPortfolioIDWithFilters is a helper method to define mock.On call
+//   - ctx context.Context
 //   - portfolioID string
 //   - symbol *string
 //   - startDate *time.Time
 //   - endDate *time.Time
-func (_e *TransactionRepository_Expecter) FindByPortfolioIDWithFilters(portfolioID interface{}, symbol interface{}, startDate interface{}, endDate interface{}) *TransactionRepository_FindByPortfolioIDWithFilters_Call {
-	return &TransactionRepository_FindByPortfolioIDWithFilters_Call{Call: _e.mock.On("FindByPortfolioIDWithFilters", portfolioID, symbol, startDate, endDate)}
+func (_e *TransactionRepository_Expecter) FindByPortfolioIDWithFilters(ctx interface{}, portfolioID interface{}, symbol interface{}, startDate interface{}, endDate interface{}) *TransactionRepository_FindByPortfolioIDWithFilters_Call {
+	return &TransactionRepository_FindByPortfolioIDWithFilters_Call{Call: _e.mock.On("FindByPortfolioIDWithFilters", ctx, portfolioID, symbol, startDate, endDate)}
 }
 
-func (_c *TransactionRepository_FindByPortfolioIDWithFilters_Call) Run(run func(portfolioID string, symbol *string, startDate *time.Time, endDate *time.Time)) *TransactionRepository_FindByPortfolioIDWithFilters_Call {
+func (_c *TransactionRepository_FindByPortfolioIDWithFilters_Call) Run(run func(ctx context.Context, portfolioID string, symbol *string, startDate *time.Time, endDate *time.Time)) *TransactionRepository_FindByPortfolioIDWithFilters_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(*string), args[2].(*time.Time), args[3].(*time.Time))
+		run(args[0].(context.Context), args[1].(string), args[2].(*string), args[3].(*time.Time), args[4].(*time.Time))
 	})
 	return _c
 }
@@ -391,22 +400,131 @@ func (_c *TransactionRepository_FindByPortfolioIDWithFilters_Call) Return(_a0 []
 	return _c
 }
 
-func (_c *TransactionRepository_FindByPortfolioIDWithFilters_Call) RunAndReturn(run func(string, *string, *time.Time, *time.Time) ([]*models.Transaction, error)) *TransactionRepository_FindByPortfolioIDWithFilters_Call {
+func (_c *TransactionRepository_FindByPortfolioIDWithFilters_Call) RunAndReturn(run func(context.Context, string, *string, *time.Time, *time.Time) ([]*models.Transaction, error)) *TransactionRepository_FindByPortfolioIDWithFilters_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindPageByPortfolioID provides a mock function with given fields: ctx, portfolioID, after, limit
+func (_m *TransactionRepository) FindPageByPortfolioID(ctx context.Context, portfolioID string, after *models.Transaction, limit int) ([]*models.Transaction, error) {
+	ret := _m.Called(ctx, portfolioID, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindPageByPortfolioID")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.Transaction, int) ([]*models.Transaction, error)); ok {
+		return rf(ctx, portfolioID, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, *models.Transaction, int) []*models.Transaction); ok {
+		r0 = rf(ctx, portfolioID, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, *models.Transaction, int) error); ok {
+		r1 = rf(ctx, portfolioID, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TransactionRepository_FindPageByPortfolioID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindPageByPortfolioID'
+type TransactionRepository_FindPageByPortfolioID_Call struct {
+	*mock.Call
+}
+
+// FindPageByPortfolioID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - portfolioID string
+//   - after *models.Transaction
+//   - limit int
+func (_e *TransactionRepository_Expecter) FindPageByPortfolioID(ctx interface{}, portfolioID interface{}, after interface{}, limit interface{}) *TransactionRepository_FindPageByPortfolioID_Call {
+	return &TransactionRepository_FindPageByPortfolioID_Call{Call: _e.mock.On("FindPageByPortfolioID", ctx, portfolioID, after, limit)}
+}
+
+func (_c *TransactionRepository_FindPageByPortfolioID_Call) Run(run func(ctx context.Context, portfolioID string, after *models.Transaction, limit int)) *TransactionRepository_FindPageByPortfolioID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(*models.Transaction), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *TransactionRepository_FindPageByPortfolioID_Call) Return(_a0 []*models.Transaction, _a1 error) *TransactionRepository_FindPageByPortfolioID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *TransactionRepository_FindPageByPortfolioID_Call) RunAndReturn(run func(context.Context, string, *models.Transaction, int) ([]*models.Transaction, error)) *TransactionRepository_FindPageByPortfolioID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IterateByPortfolioID provides a mock function with given fields: ctx, portfolioID, fn
+func (_m *TransactionRepository) IterateByPortfolioID(ctx context.Context, portfolioID string, fn func([]*models.Transaction) error) error {
+	ret := _m.Called(ctx, portfolioID, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IterateByPortfolioID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, func([]*models.Transaction) error) error); ok {
+		r0 = rf(ctx, portfolioID, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TransactionRepository_IterateByPortfolioID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IterateByPortfolioID'
+type TransactionRepository_IterateByPortfolioID_Call struct {
+	*mock.Call
+}
+
+// IterateByPortfolioID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - portfolioID string
+//   - fn func([]*models.Transaction) error
+func (_e *TransactionRepository_Expecter) IterateByPortfolioID(ctx interface{}, portfolioID interface{}, fn interface{}) *TransactionRepository_IterateByPortfolioID_Call {
+	return &TransactionRepository_IterateByPortfolioID_Call{Call: _e.mock.On("IterateByPortfolioID", ctx, portfolioID, fn)}
+}
+
+func (_c *TransactionRepository_IterateByPortfolioID_Call) Run(run func(ctx context.Context, portfolioID string, fn func([]*models.Transaction) error)) *TransactionRepository_IterateByPortfolioID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(func([]*models.Transaction) error))
+	})
+	return _c
+}
+
+func (_c *TransactionRepository_IterateByPortfolioID_Call) Return(_a0 error) *TransactionRepository_IterateByPortfolioID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *TransactionRepository_IterateByPortfolioID_Call) RunAndReturn(run func(context.Context, string, func([]*models.Transaction) error) error) *TransactionRepository_IterateByPortfolioID_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Update provides a mock function with given fields: transaction
-func (_m *TransactionRepository) Update(transaction *models.Transaction) error {
-	ret := _m.Called(transaction)
+// Update provides a mock function with given fields: ctx, transaction
+func (_m *TransactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
+	ret := _m.Called(ctx, transaction)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Update")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Transaction) error); ok {
-		r0 = rf(transaction)
+	if rf, ok := ret.Get(0).(func(context.Context, *models.Transaction) error); ok {
+		r0 = rf(ctx, transaction)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -420,14 +538,15 @@ type TransactionRepository_Update_Call struct {
 }
 
 // Update is a helper method to define mock.On call
+//   - ctx context.Context
 //   - transaction *models.Transaction
-func (_e *TransactionRepository_Expecter) Update(transaction interface{}) *TransactionRepository_Update_Call {
-	return &TransactionRepository_Update_Call{Call: _e.mock.On("Update", transaction)}
+func (_e *TransactionRepository_Expecter) Update(ctx interface{}, transaction interface{}) *TransactionRepository_Update_Call {
+	return &TransactionRepository_Update_Call{Call: _e.mock.On("Update", ctx, transaction)}
 }
 
-func (_c *TransactionRepository_Update_Call) Run(run func(transaction *models.Transaction)) *TransactionRepository_Update_Call {
+func (_c *TransactionRepository_Update_Call) Run(run func(ctx context.Context, transaction *models.Transaction)) *TransactionRepository_Update_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Transaction))
+		run(args[0].(context.Context), args[1].(*models.Transaction))
 	})
 	return _c
 }
@@ -437,7 +556,7 @@ func (_c *TransactionRepository_Update_Call) Return(_a0 error) *TransactionRepos
 	return _c
 }
 
-func (_c *TransactionRepository_Update_Call) RunAndReturn(run func(*models.Transaction) error) *TransactionRepository_Update_Call {
+func (_c *TransactionRepository_Update_Call) RunAndReturn(run func(context.Context, *models.Transaction) error) *TransactionRepository_Update_Call {
 	_c.Call.Return(run)
 	return _c
 }
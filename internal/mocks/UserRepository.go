@@ -5,6 +5,8 @@ package mocks
 import (
 	models "github.com/lenon/portfolios/internal/models"
 	mock "github.com/stretchr/testify/mock"
+
+	repository "github.com/lenon/portfolios/internal/repository"
 )
 
 // UserRepository is an autogenerated mock type for the UserRepository type
@@ -66,6 +68,52 @@ func (_c *UserRepository_Create_Call) RunAndReturn(run func(*models.User) error)
 	return _c
 }
 
+// Delete provides a mock function with given fields: id
+func (_m *UserRepository) Delete(id string) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_Delete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Delete'
+type UserRepository_Delete_Call struct {
+	*mock.Call
+}
+
+// Delete is a helper method to define mock.On call
+//   - id string
+func (_e *UserRepository_Expecter) Delete(id interface{}) *UserRepository_Delete_Call {
+	return &UserRepository_Delete_Call{Call: _e.mock.On("Delete", id)}
+}
+
+func (_c *UserRepository_Delete_Call) Run(run func(id string)) *UserRepository_Delete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Delete_Call) Return(_a0 error) *UserRepository_Delete_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_Delete_Call) RunAndReturn(run func(string) error) *UserRepository_Delete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // FindByEmail provides a mock function with given fields: email
 func (_m *UserRepository) FindByEmail(email string) (*models.User, error) {
 	ret := _m.Called(email)
@@ -182,6 +230,117 @@ func (_c *UserRepository_FindByID_Call) RunAndReturn(run func(string) (*models.U
 	return _c
 }
 
+// List provides a mock function with given fields: filter
+func (_m *UserRepository) List(filter repository.UserFilter) ([]*models.User, int64, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []*models.User
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(repository.UserFilter) ([]*models.User, int64, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(repository.UserFilter) []*models.User); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(repository.UserFilter) int64); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(repository.UserFilter) error); ok {
+		r2 = rf(filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UserRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type UserRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - filter repository.UserFilter
+func (_e *UserRepository_Expecter) List(filter interface{}) *UserRepository_List_Call {
+	return &UserRepository_List_Call{Call: _e.mock.On("List", filter)}
+}
+
+func (_c *UserRepository_List_Call) Run(run func(filter repository.UserFilter)) *UserRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(repository.UserFilter))
+	})
+	return _c
+}
+
+func (_c *UserRepository_List_Call) Return(_a0 []*models.User, _a1 int64, _a2 error) *UserRepository_List_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *UserRepository_List_Call) RunAndReturn(run func(repository.UserFilter) ([]*models.User, int64, error)) *UserRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: user
+func (_m *UserRepository) Save(user *models.User) error {
+	ret := _m.Called(user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.User) error); ok {
+		r0 = rf(user)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserRepository_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type UserRepository_Save_Call struct {
+	*mock.Call
+}
+
+// Save is a helper method to define mock.On call
+//   - user *models.User
+func (_e *UserRepository_Expecter) Save(user interface{}) *UserRepository_Save_Call {
+	return &UserRepository_Save_Call{Call: _e.mock.On("Save", user)}
+}
+
+func (_c *UserRepository_Save_Call) Run(run func(user *models.User)) *UserRepository_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*models.User))
+	})
+	return _c
+}
+
+func (_c *UserRepository_Save_Call) Return(_a0 error) *UserRepository_Save_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserRepository_Save_Call) RunAndReturn(run func(*models.User) error) *UserRepository_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // UpdateLastLogin provides a mock function with given fields: id
 func (_m *UserRepository) UpdateLastLogin(id string) error {
 	ret := _m.Called(id)
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix starts every issued API key so that leaked keys are easy to recognize
+const APIKeyPrefix = "pfk_"
+
+// APIKeyDisplayLength is how many leading characters of a key are stored in the clear so
+// that administrators can tell keys apart
+const APIKeyDisplayLength = 12
+
+// APIKey is a long-lived credential issued to an organization member through the admin API.
+// Requests authenticated with the key act as that member. Only a hash of the key is stored.
+type APIKey struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_api_keys_organization_external_id" json:"organization_id" validate:"required"`
+	ExternalID     string     `gorm:"type:varchar(255);not null;uniqueIndex:idx_api_keys_organization_external_id" json:"external_id" validate:"required"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
+	Name           string     `gorm:"type:varchar(255);not null" json:"name"`
+	Prefix         string     `gorm:"type:varchar(20);not null" json:"prefix"`
+	KeyHash        string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	User           *User      `gorm:"foreignKey:UserID" json:"-"`
+}
+
+// TableName specifies the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// BeforeCreate hook to generate UUID before creating a new API key
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	if k.CreatedAt.IsZero() {
+		k.CreatedAt = time.Now().UTC()
+	}
+	if k.UpdatedAt.IsZero() {
+		k.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (k *APIKey) BeforeUpdate(tx *gorm.DB) error {
+	k.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the API key has valid data
+func (k *APIKey) Validate() error {
+	if k.OrganizationID == uuid.Nil || k.UserID == uuid.Nil {
+		return ErrInvalidValue
+	}
+	if !IsValidExternalID(k.ExternalID) {
+		return ErrInvalidExternalID
+	}
+	if !strings.HasPrefix(k.Prefix, APIKeyPrefix) || k.KeyHash == "" {
+		return ErrInvalidAPIKey
+	}
+	return nil
+}
+
+// IsActive returns true if the key has not been revoked
+func (k *APIKey) IsActive() bool {
+	return k.RevokedAt == nil
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"regexp"
+
+	"github.com/shopspring/decimal"
+)
+
+// AssetType is the kind of asset a transaction or holding is in
+type AssetType string
+
+const (
+	// AssetTypeEquity is a stock or fund, priced by the market data provider on trading days
+	AssetTypeEquity AssetType = "EQUITY"
+	// AssetTypeCrypto is a coin pair such as BTC-USD, priced around the clock by the crypto
+	// market data provider in the pair's quote currency
+	AssetTypeCrypto AssetType = "CRYPTO"
+	// AssetTypeOption is a listed option contract identified by its OCC symbol, such as
+	// AAPL240621C00190000. Quantities are contracts, each covering the contract's multiplier
+	// of underlying shares.
+	AssetTypeOption AssetType = "OPTION"
+)
+
+// CryptoQuantityDecimals is the most decimal places a crypto quantity may have, matching
+// the precision of the quantity columns
+const CryptoQuantityDecimals = 8
+
+// cryptoPairPattern matches coin pairs: a coin symbol, a dash and a three-letter quote
+// currency. Share class suffixes such as BRK-B are too short to match.
+var cryptoPairPattern = regexp.MustCompile(`^([A-Z0-9]{2,10})-([A-Z]{3})$`)
+
+// IsValid returns true if the asset type is recognized
+func (a AssetType) IsValid() bool {
+	return a == AssetTypeEquity || a == AssetTypeCrypto || a == AssetTypeOption
+}
+
+// CryptoPair splits a coin pair symbol such as BTC-USD into its coin and the currency it
+// is quoted in. ok is false for symbols that aren't coin pairs.
+func CryptoPair(symbol string) (coin, quoteCurrency string, ok bool) {
+	match := cryptoPairPattern.FindStringSubmatch(symbol)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// AssetTypeForSymbol returns the asset type a symbol is traded as: option for OCC option
+// symbols, crypto for coin pairs and equity for everything else
+func AssetTypeForSymbol(symbol string) AssetType {
+	if IsOptionSymbol(symbol) {
+		return AssetTypeOption
+	}
+	if _, _, ok := CryptoPair(symbol); ok {
+		return AssetTypeCrypto
+	}
+	return AssetTypeEquity
+}
+
+// hasAtMostDecimals returns true if value has no more than places decimal places
+func hasAtMostDecimals(value decimal.Decimal, places int32) bool {
+	return value.Equal(value.Truncate(places))
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCryptoPair(t *testing.T) {
+	tests := []struct {
+		symbol    string
+		wantCoin  string
+		wantQuote string
+		wantOK    bool
+	}{
+		{"BTC-USD", "BTC", "USD", true},
+		{"ETH-EUR", "ETH", "EUR", true},
+		{"SHIB-GBP", "SHIB", "GBP", true},
+		{"1INCH-USD", "1INCH", "USD", true},
+		{"ETH-BTC", "ETH", "BTC", true},
+		{"AAPL", "", "", false},
+		{"BRK-B", "", "", false},
+		{"btc-usd", "", "", false},
+		{"BTC-USDT", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			coin, quote, ok := CryptoPair(tt.symbol)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantCoin, coin)
+			assert.Equal(t, tt.wantQuote, quote)
+		})
+	}
+}
+
+func TestAssetTypeForSymbol(t *testing.T) {
+	assert.Equal(t, AssetTypeCrypto, AssetTypeForSymbol("BTC-USD"))
+	assert.Equal(t, AssetTypeEquity, AssetTypeForSymbol("AAPL"))
+	assert.Equal(t, AssetTypeEquity, AssetTypeForSymbol("BRK-B"))
+	assert.Equal(t, AssetTypeOption, AssetTypeForSymbol("AAPL240621C00190000"))
+}
+
+func TestAssetType_IsValid(t *testing.T) {
+	assert.True(t, AssetTypeEquity.IsValid())
+	assert.True(t, AssetTypeCrypto.IsValid())
+	assert.True(t, AssetTypeOption.IsValid())
+	assert.False(t, AssetType("BOND").IsValid())
+	assert.False(t, AssetType("").IsValid())
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BlackoutEnforcement controls what happens when a trade falls inside a blackout window
+type BlackoutEnforcement string
+
+const (
+	// BlackoutEnforcementWarn allows the trade but flags it and records it in the audit trail
+	BlackoutEnforcementWarn BlackoutEnforcement = "WARN"
+	// BlackoutEnforcementBlock rejects the trade unless an override reason is given
+	BlackoutEnforcementBlock BlackoutEnforcement = "BLOCK"
+)
+
+// IsValid returns true if the enforcement mode is recognized
+func (e BlackoutEnforcement) IsValid() bool {
+	return e == BlackoutEnforcementWarn || e == BlackoutEnforcementBlock
+}
+
+// EmployerStockPolicy flags a portfolio as holding restricted employer stock
+type EmployerStockPolicy struct {
+	ID          uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex" json:"portfolio_id" validate:"required"`
+	Symbol      string              `gorm:"type:varchar(20);not null" json:"symbol" validate:"required"`
+	Enforcement BlackoutEnforcement `gorm:"type:varchar(10);not null;default:'WARN'" json:"enforcement"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+	Portfolio   *Portfolio          `gorm:"foreignKey:PortfolioID" json:"portfolio,omitempty"`
+}
+
+// TableName specifies the table name for the EmployerStockPolicy model
+func (EmployerStockPolicy) TableName() string {
+	return "employer_stock_policies"
+}
+
+// BeforeCreate hook to generate UUID before creating a new policy
+func (p *EmployerStockPolicy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now().UTC()
+	}
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = time.Now().UTC()
+	}
+	if p.Enforcement == "" {
+		p.Enforcement = BlackoutEnforcementWarn
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (p *EmployerStockPolicy) BeforeUpdate(tx *gorm.DB) error {
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the policy has valid data
+func (p *EmployerStockPolicy) Validate() error {
+	if p.PortfolioID == uuid.Nil {
+		return ErrInvalidPortfolioID
+	}
+	if p.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if p.Enforcement != "" && !p.Enforcement.IsValid() {
+		return ErrInvalidBlackoutEnforcement
+	}
+	return nil
+}
+
+// AppliesTo returns true if a transaction is a discretionary trade of the restricted symbol.
+// Dividends, reinvestments, corporate actions and stock plan events happen on a schedule
+// the employee doesn't control, so only open-market buys and sells are restricted.
+func (p *EmployerStockPolicy) AppliesTo(symbol string, transactionType TransactionType) bool {
+	if !strings.EqualFold(symbol, p.Symbol) {
+		return false
+	}
+	return transactionType == TransactionTypeBuy || transactionType == TransactionTypeSell
+}
+
+// BlackoutWindow is a date range during which trading the restricted symbol is not allowed
+type BlackoutWindow struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID uuid.UUID  `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
+	StartDate   time.Time  `gorm:"not null" json:"start_date" validate:"required"`
+	EndDate     time.Time  `gorm:"not null" json:"end_date" validate:"required"`
+	Reason      string     `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Portfolio   *Portfolio `gorm:"foreignKey:PortfolioID" json:"portfolio,omitempty"`
+}
+
+// TableName specifies the table name for the BlackoutWindow model
+func (BlackoutWindow) TableName() string {
+	return "blackout_windows"
+}
+
+// BeforeCreate hook to generate UUID before creating a new blackout window
+func (w *BlackoutWindow) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// Validate checks if the blackout window has valid data
+func (w *BlackoutWindow) Validate() error {
+	if w.PortfolioID == uuid.Nil {
+		return ErrInvalidPortfolioID
+	}
+	if w.StartDate.IsZero() || w.EndDate.IsZero() {
+		return ErrInvalidDate
+	}
+	if w.EndDate.Before(w.StartDate) {
+		return ErrInvalidBlackoutWindow
+	}
+	return nil
+}
+
+// Contains returns true if date falls on or between the window's start and end days
+func (w *BlackoutWindow) Contains(date time.Time) bool {
+	day := date.UTC().Truncate(24 * time.Hour)
+	return !day.Before(w.StartDate.UTC().Truncate(24*time.Hour)) &&
+		!day.After(w.EndDate.UTC().Truncate(24*time.Hour))
+}
+
+// BlackoutOverride is an audit record of a trade made inside a blackout window
+type BlackoutOverride struct {
+	ID              uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID     uuid.UUID           `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
+	WindowID        uuid.UUID           `gorm:"type:uuid;not null;index" json:"window_id" validate:"required"`
+	TransactionID   uuid.UUID           `gorm:"type:uuid;not null" json:"transaction_id" validate:"required"`
+	UserID          uuid.UUID           `gorm:"type:uuid;not null" json:"user_id" validate:"required"`
+	Symbol          string              `gorm:"type:varchar(20);not null" json:"symbol"`
+	TransactionType TransactionType     `gorm:"type:varchar(20);not null" json:"transaction_type"`
+	TransactionDate time.Time           `gorm:"not null" json:"transaction_date"`
+	Enforcement     BlackoutEnforcement `gorm:"type:varchar(10);not null" json:"enforcement"`
+	Reason          string              `gorm:"type:text" json:"reason,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+// TableName specifies the table name for the BlackoutOverride model
+func (BlackoutOverride) TableName() string {
+	return "blackout_overrides"
+}
+
+// BeforeCreate hook to generate UUID before creating a new override record
+func (o *BlackoutOverride) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
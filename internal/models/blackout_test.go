@@ -0,0 +1,55 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlackout_TableNames(t *testing.T) {
+	assert.Equal(t, "employer_stock_policies", EmployerStockPolicy{}.TableName())
+	assert.Equal(t, "blackout_windows", BlackoutWindow{}.TableName())
+	assert.Equal(t, "blackout_overrides", BlackoutOverride{}.TableName())
+}
+
+func TestEmployerStockPolicy_Validate(t *testing.T) {
+	portfolioID := uuid.New()
+
+	assert.NoError(t, (&EmployerStockPolicy{PortfolioID: portfolioID, Symbol: "ACME"}).Validate())
+	assert.Equal(t, ErrInvalidSymbol, (&EmployerStockPolicy{PortfolioID: portfolioID}).Validate())
+	assert.Equal(t, ErrInvalidBlackoutEnforcement,
+		(&EmployerStockPolicy{PortfolioID: portfolioID, Symbol: "ACME", Enforcement: "IGNORE"}).Validate())
+}
+
+func TestEmployerStockPolicy_AppliesTo(t *testing.T) {
+	policy := &EmployerStockPolicy{Symbol: "ACME"}
+
+	assert.True(t, policy.AppliesTo("ACME", TransactionTypeBuy))
+	assert.True(t, policy.AppliesTo("ACME", TransactionTypeSell))
+	assert.False(t, policy.AppliesTo("ACME", TransactionTypeDividend))
+	assert.False(t, policy.AppliesTo("ACME", TransactionTypeRSUVest))
+	assert.False(t, policy.AppliesTo("AAPL", TransactionTypeSell))
+}
+
+func TestBlackoutWindow_Validate(t *testing.T) {
+	start := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, (&BlackoutWindow{PortfolioID: uuid.New(), StartDate: start, EndDate: start}).Validate())
+	assert.Equal(t, ErrInvalidBlackoutWindow,
+		(&BlackoutWindow{PortfolioID: uuid.New(), StartDate: start, EndDate: start.AddDate(0, 0, -1)}).Validate())
+	assert.Equal(t, ErrInvalidDate, (&BlackoutWindow{PortfolioID: uuid.New(), StartDate: start}).Validate())
+}
+
+func TestBlackoutWindow_Contains(t *testing.T) {
+	window := &BlackoutWindow{
+		StartDate: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	assert.True(t, window.Contains(time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC)))
+	assert.True(t, window.Contains(time.Date(2024, 4, 30, 23, 59, 0, 0, time.UTC)))
+	assert.False(t, window.Contains(time.Date(2024, 3, 14, 23, 59, 0, 0, time.UTC)))
+	assert.False(t, window.Contains(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxBrokerConnectionNameLength is the longest name a broker connection may have
+const MaxBrokerConnectionNameLength = 100
+
+// BrokerProvider is the brokerage aggregation API a connection pulls an account from
+type BrokerProvider string
+
+const (
+	// BrokerProviderPlaid syncs an investment account linked through Plaid
+	BrokerProviderPlaid BrokerProvider = "PLAID"
+)
+
+// IsValid returns true if the broker provider is recognized
+func (p BrokerProvider) IsValid() bool {
+	return p == BrokerProviderPlaid
+}
+
+// BrokerConnectionStatus is the state of a broker connection's syncs
+type BrokerConnectionStatus string
+
+const (
+	// BrokerConnectionStatusActive connections synced successfully last time, or haven't
+	// synced yet
+	BrokerConnectionStatusActive BrokerConnectionStatus = "ACTIVE"
+	// BrokerConnectionStatusFailed connections failed their last sync, for example because
+	// the provider revoked their access token; LastSyncError says why
+	BrokerConnectionStatusFailed BrokerConnectionStatus = "FAILED"
+)
+
+// BrokerConnection links a portfolio to a brokerage account at a provider such as Plaid,
+// whose positions and transactions are synced into the portfolio. Credentials holds the
+// account's access token sealed with the server's broker encryption key and is never sent to
+// clients. Syncs reconcile the transactions dated from SyncFrom; the report of the last one is
+// stored as JSON in LastSyncReport.
+type BrokerConnection struct {
+	ID             uuid.UUID              `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID    uuid.UUID              `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
+	UserID         uuid.UUID              `gorm:"type:uuid;not null" json:"user_id" validate:"required"`
+	Provider       BrokerProvider         `gorm:"type:varchar(20);not null" json:"provider" validate:"required"`
+	Name           string                 `gorm:"type:varchar(100);not null" json:"name"`
+	AccountID      string                 `gorm:"type:varchar(255);not null" json:"account_id" validate:"required"`
+	Credentials    string                 `gorm:"type:text;not null" json:"-"`
+	SyncFrom       time.Time              `gorm:"not null" json:"sync_from"`
+	Status         BrokerConnectionStatus `gorm:"type:varchar(20);not null;default:'ACTIVE'" json:"status"`
+	LastSyncedAt   *time.Time             `json:"last_synced_at,omitempty"`
+	LastSyncError  string                 `gorm:"type:text" json:"last_sync_error,omitempty"`
+	LastSyncReport string                 `gorm:"type:text" json:"-"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+// TableName specifies the table name for the BrokerConnection model
+func (BrokerConnection) TableName() string {
+	return "broker_connections"
+}
+
+// BeforeCreate hook to generate UUID before creating a new connection
+func (c *BrokerConnection) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	if c.Status == "" {
+		c.Status = BrokerConnectionStatusActive
+	}
+	now := time.Now().UTC()
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = now
+	}
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (c *BrokerConnection) BeforeUpdate(tx *gorm.DB) error {
+	c.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the connection has valid data
+func (c *BrokerConnection) Validate() error {
+	if c.PortfolioID == uuid.Nil || c.UserID == uuid.Nil {
+		return ErrInvalidBrokerConnection
+	}
+	if !c.Provider.IsValid() {
+		return fmt.Errorf("%w: unknown provider %q", ErrInvalidBrokerConnection, c.Provider)
+	}
+	if strings.TrimSpace(c.AccountID) == "" {
+		return fmt.Errorf("%w: account ID is required", ErrInvalidBrokerConnection)
+	}
+	if len(c.Name) > MaxBrokerConnectionNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidBrokerConnection, MaxBrokerConnectionNameLength)
+	}
+	if c.Credentials == "" || c.SyncFrom.IsZero() {
+		return ErrInvalidBrokerConnection
+	}
+	return nil
+}
+
+// SetSyncReport stores the report of the connection's last sync as JSON
+func (c *BrokerConnection) SetSyncReport(report any) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode sync report: %w", err)
+	}
+	c.LastSyncReport = string(data)
+	return nil
+}
+
+// DecodeSyncReport reads the report of the connection's last sync into v. It returns false if
+// the connection hasn't synced successfully yet.
+func (c *BrokerConnection) DecodeSyncReport(v any) (bool, error) {
+	if c.LastSyncReport == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(c.LastSyncReport), v); err != nil {
+		return false, fmt.Errorf("failed to decode sync report: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerConnection_Validate(t *testing.T) {
+	valid := func() *BrokerConnection {
+		return &BrokerConnection{
+			PortfolioID: uuid.New(),
+			UserID:      uuid.New(),
+			Provider:    BrokerProviderPlaid,
+			Name:        "Brokerage",
+			AccountID:   "acc-1",
+			Credentials: "sealed",
+			SyncFrom:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	assert.NoError(t, valid().Validate())
+
+	tests := map[string]func(c *BrokerConnection){
+		"no portfolio":      func(c *BrokerConnection) { c.PortfolioID = uuid.Nil },
+		"unknown provider":  func(c *BrokerConnection) { c.Provider = "YODLEE" },
+		"no account":        func(c *BrokerConnection) { c.AccountID = " " },
+		"long name":         func(c *BrokerConnection) { c.Name = strings.Repeat("a", MaxBrokerConnectionNameLength+1) },
+		"no credentials":    func(c *BrokerConnection) { c.Credentials = "" },
+		"no sync from date": func(c *BrokerConnection) { c.SyncFrom = time.Time{} },
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			connection := valid()
+			change(connection)
+			assert.ErrorIs(t, connection.Validate(), ErrInvalidBrokerConnection)
+		})
+	}
+}
+
+func TestBrokerConnection_SyncReport(t *testing.T) {
+	connection := &BrokerConnection{}
+	var report map[string]int
+	found, err := connection.DecodeSyncReport(&report)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, connection.SetSyncReport(map[string]int{"imported": 3}))
+	found, err = connection.DecodeSyncReport(&report)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, map[string]int{"imported": 3}, report)
+}
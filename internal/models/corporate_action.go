@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,13 +18,106 @@ const (
 	CorporateActionTypeMerger       CorporateActionType = "MERGER"
 	CorporateActionTypeSpinoff      CorporateActionType = "SPINOFF"
 	CorporateActionTypeTickerChange CorporateActionType = "TICKER_CHANGE"
+	// CorporateActionTypeReturnOfCapital is a non-dividend distribution: it is paid back out
+	// of the shareholders' investment, so it lowers cost basis instead of being income
+	CorporateActionTypeReturnOfCapital CorporateActionType = "RETURN_OF_CAPITAL"
+	// CorporateActionTypeStockDividend pays a dividend in shares; its ratio is the new shares
+	// received per share held
+	CorporateActionTypeStockDividend CorporateActionType = "STOCK_DIVIDEND"
+	// CorporateActionTypeCapitalGainDistribution is a fund paying out the capital gains it
+	// realized; its amount is per share, and it is taxed as a long-term gain
+	CorporateActionTypeCapitalGainDistribution CorporateActionType = "CAPITAL_GAIN_DISTRIBUTION"
 )
 
+// DefaultSpinoffCostBasisAllocation is the share of a parent's cost basis moved to spinoff
+// shares when none is given. The split should follow the relative fair market values of the
+// parent and spinoff on the distribution date, as published by the issuer.
+var DefaultSpinoffCostBasisAllocation = decimal.RequireFromString("0.10")
+
+// spinoffAllocationPlaces is the precision of allocations computed from market values
+const spinoffAllocationPlaces = 8
+
+// SpinoffAllocationMethod is how the share of cost basis moved to spinoff shares was decided
+type SpinoffAllocationMethod string
+
+const (
+	// SpinoffAllocationDefault moves DefaultSpinoffCostBasisAllocation
+	SpinoffAllocationDefault SpinoffAllocationMethod = "DEFAULT"
+	// SpinoffAllocationExplicit moves a share given by the user, usually the issuer's
+	SpinoffAllocationExplicit SpinoffAllocationMethod = "EXPLICIT"
+	// SpinoffAllocationFairMarketValue moves the spinoff's share of the combined market value
+	// of the parent and spinoff at their closing prices on the distribution date
+	SpinoffAllocationFairMarketValue SpinoffAllocationMethod = "FAIR_MARKET_VALUE"
+)
+
+// SpinoffAllocation is the share of a parent's cost basis moved to spinoff shares and how it
+// was decided. Fair market value allocations keep the closing prices they were computed from.
+type SpinoffAllocation struct {
+	Method       SpinoffAllocationMethod
+	Fraction     decimal.Decimal
+	ParentPrice  decimal.Decimal
+	SpinoffPrice decimal.Decimal
+}
+
+// DefaultSpinoffAllocation returns the allocation used when none is given
+func DefaultSpinoffAllocation() SpinoffAllocation {
+	return SpinoffAllocation{Method: SpinoffAllocationDefault, Fraction: DefaultSpinoffCostBasisAllocation}
+}
+
+// ExplicitSpinoffAllocation returns an allocation of the given fraction
+func ExplicitSpinoffAllocation(fraction decimal.Decimal) SpinoffAllocation {
+	return SpinoffAllocation{Method: SpinoffAllocationExplicit, Fraction: fraction}
+}
+
+// FairMarketValueSpinoffAllocation allocates cost basis by the market value of what a parent
+// share became: the parent share itself at parentPrice, and ratio spinoff shares at spinoffPrice
+func FairMarketValueSpinoffAllocation(ratio, parentPrice, spinoffPrice decimal.Decimal) (SpinoffAllocation, error) {
+	if !ratio.IsPositive() || !parentPrice.IsPositive() || !spinoffPrice.IsPositive() {
+		return SpinoffAllocation{}, ErrInvalidCostBasisAllocation
+	}
+	spinoffValue := spinoffPrice.Mul(ratio)
+	allocation := SpinoffAllocation{
+		Method:       SpinoffAllocationFairMarketValue,
+		Fraction:     spinoffValue.Div(spinoffValue.Add(parentPrice)).Round(spinoffAllocationPlaces),
+		ParentPrice:  parentPrice,
+		SpinoffPrice: spinoffPrice,
+	}
+	return allocation, allocation.Validate()
+}
+
+// Validate checks that the allocation leaves some cost basis with both the parent and the
+// spinoff
+func (a SpinoffAllocation) Validate() error {
+	return ValidateCostBasisAllocation(a.Fraction)
+}
+
+// Describe returns how the allocation was decided, for audit notes
+func (a SpinoffAllocation) Describe(parentSymbol, spinoffSymbol string) string {
+	switch a.Method {
+	case SpinoffAllocationExplicit:
+		return "explicit"
+	case SpinoffAllocationFairMarketValue:
+		return fmt.Sprintf("fair market value, %s closed at %s and %s at %s",
+			parentSymbol, a.ParentPrice.String(), spinoffSymbol, a.SpinoffPrice.String())
+	default:
+		return "default"
+	}
+}
+
+// ValidateCostBasisAllocation checks that a spinoff cost basis allocation leaves some cost
+// basis with both the parent and the spinoff
+func ValidateCostBasisAllocation(allocation decimal.Decimal) error {
+	if !allocation.IsPositive() || allocation.GreaterThanOrEqual(decimal.NewFromInt(1)) {
+		return ErrInvalidCostBasisAllocation
+	}
+	return nil
+}
+
 // CorporateAction represents a corporate action that affects securities
 type CorporateAction struct {
 	ID          uuid.UUID           `gorm:"type:uuid;primaryKey" json:"id"`
 	Symbol      string              `gorm:"type:varchar(20);not null;index" json:"symbol" validate:"required"`
-	Type        CorporateActionType `gorm:"type:varchar(20);not null;index" json:"type" validate:"required"`
+	Type        CorporateActionType `gorm:"type:varchar(30);not null;index" json:"type" validate:"required"`
 	Date        time.Time           `gorm:"not null;index" json:"date" validate:"required"`
 	Ratio       *decimal.Decimal    `gorm:"type:numeric(20,8)" json:"ratio,omitempty"`
 	Amount      *decimal.Decimal    `gorm:"type:numeric(20,8)" json:"amount,omitempty"`
@@ -61,11 +155,11 @@ func (ca *CorporateAction) Validate() error {
 
 	// Type-specific validation
 	switch ca.Type {
-	case CorporateActionTypeSplit:
+	case CorporateActionTypeSplit, CorporateActionTypeStockDividend:
 		if ca.Ratio == nil || ca.Ratio.IsZero() || ca.Ratio.IsNegative() {
 			return ErrInvalidCorporateActionType
 		}
-	case CorporateActionTypeDividend:
+	case CorporateActionTypeDividend, CorporateActionTypeReturnOfCapital, CorporateActionTypeCapitalGainDistribution:
 		if ca.Amount == nil || ca.Amount.IsZero() || ca.Amount.IsNegative() {
 			return ErrInvalidCorporateActionType
 		}
@@ -87,7 +181,8 @@ func (ca *CorporateAction) isValidType() bool {
 	switch ca.Type {
 	case CorporateActionTypeSplit, CorporateActionTypeDividend,
 		CorporateActionTypeMerger, CorporateActionTypeSpinoff,
-		CorporateActionTypeTickerChange:
+		CorporateActionTypeTickerChange, CorporateActionTypeReturnOfCapital,
+		CorporateActionTypeStockDividend, CorporateActionTypeCapitalGainDistribution:
 		return true
 	default:
 		return false
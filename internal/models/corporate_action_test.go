@@ -101,6 +101,48 @@ func TestCorporateAction_Validate_DividendMissingAmount(t *testing.T) {
 	assert.Equal(t, ErrInvalidCorporateActionType, err)
 }
 
+func TestCorporateAction_Validate_ReturnOfCapital(t *testing.T) {
+	amount := decimal.NewFromFloat(0.40)
+	action := &CorporateAction{
+		Symbol: "T",
+		Type:   CorporateActionTypeReturnOfCapital,
+		Date:   time.Now().UTC(),
+		Amount: &amount,
+	}
+	assert.NoError(t, action.Validate())
+
+	action.Amount = nil
+	assert.Equal(t, ErrInvalidCorporateActionType, action.Validate())
+}
+
+func TestCorporateAction_Validate_StockDividend(t *testing.T) {
+	rate := decimal.NewFromFloat(0.05)
+	action := &CorporateAction{
+		Symbol: "AAPL",
+		Type:   CorporateActionTypeStockDividend,
+		Date:   time.Now().UTC(),
+		Ratio:  &rate,
+	}
+	assert.NoError(t, action.Validate())
+
+	action.Ratio = nil
+	assert.Equal(t, ErrInvalidCorporateActionType, action.Validate())
+}
+
+func TestCorporateAction_Validate_CapitalGainDistribution(t *testing.T) {
+	amount := decimal.NewFromFloat(1.85)
+	action := &CorporateAction{
+		Symbol: "VFIAX",
+		Type:   CorporateActionTypeCapitalGainDistribution,
+		Date:   time.Now().UTC(),
+		Amount: &amount,
+	}
+	assert.NoError(t, action.Validate())
+
+	action.Amount = nil
+	assert.Equal(t, ErrInvalidCorporateActionType, action.Validate())
+}
+
 func TestCorporateAction_Validate_Merger(t *testing.T) {
 	ratio := decimal.NewFromFloat(1.5)
 	newSymbol := "ABC"
@@ -207,3 +249,27 @@ func TestCorporateAction_Validate_InvalidType(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidCorporateActionType, err)
 }
+
+func TestFairMarketValueSpinoffAllocation(t *testing.T) {
+	// A parent share worth 90 and half a spinoff share worth 20 each: 10 of 100
+	allocation, err := FairMarketValueSpinoffAllocation(decimal.RequireFromString("0.5"), decimal.NewFromInt(90), decimal.NewFromInt(20))
+	assert.NoError(t, err)
+	assert.Equal(t, SpinoffAllocationFairMarketValue, allocation.Method)
+	assert.Equal(t, "0.1", allocation.Fraction.String())
+	assert.Equal(t, "fair market value, OLD closed at 90 and SPIN at 20", allocation.Describe("OLD", "SPIN"))
+
+	// Fractions are kept to 8 places
+	allocation, err = FairMarketValueSpinoffAllocation(decimal.NewFromInt(1), decimal.NewFromInt(2), decimal.NewFromInt(1))
+	assert.NoError(t, err)
+	assert.Equal(t, "0.33333333", allocation.Fraction.String())
+
+	_, err = FairMarketValueSpinoffAllocation(decimal.NewFromInt(1), decimal.Zero, decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, ErrInvalidCostBasisAllocation)
+}
+
+func TestSpinoffAllocation_Describe(t *testing.T) {
+	assert.Equal(t, "default", DefaultSpinoffAllocation().Describe("OLD", "SPIN"))
+	assert.Equal(t, "explicit", ExplicitSpinoffAllocation(decimal.RequireFromString("0.3")).Describe("OLD", "SPIN"))
+	assert.NoError(t, DefaultSpinoffAllocation().Validate())
+	assert.ErrorIs(t, ExplicitSpinoffAllocation(decimal.NewFromInt(1)).Validate(), ErrInvalidCostBasisAllocation)
+}
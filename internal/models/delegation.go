@@ -0,0 +1,201 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DelegationAccess is what an advisor may do with the portfolios a client delegated to them
+type DelegationAccess string
+
+const (
+	// DelegationAccessRead lets the advisor read the delegated portfolios
+	DelegationAccessRead DelegationAccess = "read"
+	// DelegationAccessManage also lets the advisor change the delegated portfolios' records
+	DelegationAccessManage DelegationAccess = "manage"
+)
+
+// IsValid returns true if the access level is known
+func (a DelegationAccess) IsValid() bool {
+	return a == DelegationAccessRead || a == DelegationAccessManage
+}
+
+// CanWrite returns true if the access level may change the delegated portfolios
+func (a DelegationAccess) CanWrite() bool {
+	return a == DelegationAccessManage
+}
+
+// DelegationStatus is where a delegation is in the consent flow
+type DelegationStatus string
+
+const (
+	// DelegationStatusPending is waiting for the client to consent
+	DelegationStatusPending DelegationStatus = "pending"
+	// DelegationStatusActive has the client's consent
+	DelegationStatusActive DelegationStatus = "active"
+	// DelegationStatusRevoked was declined or revoked by the client, or withdrawn by the advisor
+	DelegationStatusRevoked DelegationStatus = "revoked"
+)
+
+// Delegation grants an advisor access to some of a client's portfolios. The advisor asks for
+// access by the client's email; the client consents with the token emailed to them and
+// chooses which of their portfolios to delegate. Only a hash of the consent token is stored.
+type Delegation struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	AdvisorID   uuid.UUID        `gorm:"type:uuid;not null;index" json:"advisor_id"`
+	ClientEmail string           `gorm:"type:varchar(255);not null;index" json:"client_email"`
+	ClientID    *uuid.UUID       `gorm:"type:uuid;index" json:"client_id,omitempty"`
+	Access      DelegationAccess `gorm:"type:varchar(10);not null" json:"access"`
+	Status      DelegationStatus `gorm:"type:varchar(10);not null;default:'pending'" json:"status"`
+	TokenHash   string           `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	ExpiresAt   time.Time        `gorm:"not null" json:"expires_at"`
+	ApprovedAt  *time.Time       `json:"approved_at,omitempty"`
+	RevokedAt   *time.Time       `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+
+	Advisor    *User                  `gorm:"foreignKey:AdvisorID" json:"advisor,omitempty"`
+	Client     *User                  `gorm:"foreignKey:ClientID" json:"client,omitempty"`
+	Portfolios []*DelegationPortfolio `gorm:"foreignKey:DelegationID" json:"portfolios,omitempty"`
+}
+
+// TableName specifies the table name for the Delegation model
+func (Delegation) TableName() string {
+	return "delegations"
+}
+
+// BeforeCreate hook to generate UUID before creating a new delegation
+func (d *Delegation) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+	if d.UpdatedAt.IsZero() {
+		d.UpdatedAt = time.Now().UTC()
+	}
+	if d.Status == "" {
+		d.Status = DelegationStatusPending
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (d *Delegation) BeforeUpdate(tx *gorm.DB) error {
+	d.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// IsPending returns true if the client can still consent to the delegation at now
+func (d *Delegation) IsPending(now time.Time) bool {
+	return d.Status == DelegationStatusPending && now.Before(d.ExpiresAt)
+}
+
+// IsParty returns true if userID is the delegation's advisor or client
+func (d *Delegation) IsParty(userID uuid.UUID) bool {
+	return d.AdvisorID == userID || (d.ClientID != nil && *d.ClientID == userID)
+}
+
+// PortfolioIDs returns the IDs of the delegated portfolios
+func (d *Delegation) PortfolioIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(d.Portfolios))
+	for i, portfolio := range d.Portfolios {
+		ids[i] = portfolio.PortfolioID
+	}
+	return ids
+}
+
+// DelegationPortfolio is one of the client's portfolios delegated to the advisor. Portfolios
+// may be kept in an organization's schema, so there is no foreign key to them.
+type DelegationPortfolio struct {
+	DelegationID uuid.UUID `gorm:"type:uuid;primaryKey" json:"delegation_id"`
+	PortfolioID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"portfolio_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the DelegationPortfolio model
+func (DelegationPortfolio) TableName() string {
+	return "delegation_portfolios"
+}
+
+// BeforeCreate hook to set the timestamp before delegating a portfolio
+func (p *DelegationPortfolio) BeforeCreate(tx *gorm.DB) error {
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// DelegationAuditEvent records a request an advisor made on behalf of a client, so that both
+// can see what was done and that the advisor did it
+type DelegationAuditEvent struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	DelegationID uuid.UUID  `gorm:"type:uuid;not null;index" json:"delegation_id"`
+	AdvisorID    uuid.UUID  `gorm:"type:uuid;not null" json:"advisor_id"`
+	ClientID     uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	PortfolioID  *uuid.UUID `gorm:"type:uuid" json:"portfolio_id,omitempty"`
+	Method       string     `gorm:"type:varchar(10);not null" json:"method"`
+	Route        string     `gorm:"type:varchar(255);not null" json:"route"`
+	Path         string     `gorm:"type:varchar(2048);not null" json:"path"`
+	Status       int        `gorm:"not null" json:"status"`
+	RequestID    string     `gorm:"type:varchar(128)" json:"request_id,omitempty"`
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+}
+
+// TableName specifies the table name for the DelegationAuditEvent model
+func (DelegationAuditEvent) TableName() string {
+	return "delegation_audit_events"
+}
+
+// BeforeCreate hook to generate UUID before recording an audit event
+func (e *DelegationAuditEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// DelegationScope is the delegation a request acts under: an advisor working with the
+// portfolios a client delegated to them. Access is empty for queued jobs, whose access was
+// checked when they were requested.
+type DelegationScope struct {
+	DelegationID uuid.UUID
+	AdvisorID    uuid.UUID
+	ClientID     uuid.UUID
+	Access       DelegationAccess
+	PortfolioIDs []uuid.UUID
+}
+
+// Grants returns true if the portfolio is one of the delegated portfolios
+func (s DelegationScope) Grants(portfolioID uuid.UUID) bool {
+	for _, id := range s.PortfolioIDs {
+		if id == portfolioID {
+			return true
+		}
+	}
+	return false
+}
+
+type delegationScopeContextKey struct{}
+
+// WithDelegationScope returns a copy of ctx acting under scope's delegation, which gives
+// access to the delegated portfolios
+func WithDelegationScope(ctx context.Context, scope DelegationScope) context.Context {
+	return context.WithValue(ctx, delegationScopeContextKey{}, scope)
+}
+
+// DelegationScopeFromContext returns the delegation set with WithDelegationScope, if any
+func DelegationScopeFromContext(ctx context.Context) (DelegationScope, bool) {
+	if ctx == nil {
+		return DelegationScope{}, false
+	}
+	scope, ok := ctx.Value(delegationScopeContextKey{}).(DelegationScope)
+	return scope, ok
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelegation_IsPending(t *testing.T) {
+	now := time.Now().UTC()
+	delegation := &Delegation{Status: DelegationStatusPending, ExpiresAt: now.Add(time.Hour)}
+	assert.True(t, delegation.IsPending(now))
+	assert.False(t, delegation.IsPending(now.Add(2*time.Hour)))
+
+	delegation.Status = DelegationStatusActive
+	assert.False(t, delegation.IsPending(now))
+}
+
+func TestDelegation_IsParty(t *testing.T) {
+	advisorID, clientID := uuid.New(), uuid.New()
+	delegation := &Delegation{AdvisorID: advisorID}
+	assert.True(t, delegation.IsParty(advisorID))
+	assert.False(t, delegation.IsParty(clientID))
+
+	delegation.ClientID = &clientID
+	assert.True(t, delegation.IsParty(clientID))
+	assert.False(t, delegation.IsParty(uuid.New()))
+}
+
+func TestPortfolio_AccessibleBy_Delegation(t *testing.T) {
+	advisorID, clientID := uuid.New(), uuid.New()
+	delegated := &Portfolio{ID: uuid.New(), UserID: clientID}
+	other := &Portfolio{ID: uuid.New(), UserID: clientID}
+
+	ctx := context.Background()
+	assert.False(t, delegated.AccessibleBy(ctx, advisorID.String()))
+
+	ctx = WithDelegationScope(ctx, DelegationScope{
+		AdvisorID:    advisorID,
+		ClientID:     clientID,
+		Access:       DelegationAccessRead,
+		PortfolioIDs: []uuid.UUID{delegated.ID},
+	})
+	assert.True(t, delegated.AccessibleBy(ctx, advisorID.String()))
+	assert.False(t, other.AccessibleBy(ctx, advisorID.String()))
+	// The delegation only extends to its advisor
+	assert.False(t, delegated.AccessibleBy(ctx, uuid.New().String()))
+	assert.True(t, delegated.AccessibleBy(ctx, clientID.String()))
+}
+
+func TestDelegationAccess(t *testing.T) {
+	assert.True(t, DelegationAccessRead.IsValid())
+	assert.True(t, DelegationAccessManage.IsValid())
+	assert.False(t, DelegationAccess("admin").IsValid())
+	assert.False(t, DelegationAccessRead.CanWrite())
+	assert.True(t, DelegationAccessManage.CanWrite())
+}
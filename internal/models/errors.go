@@ -4,9 +4,20 @@ import "errors"
 
 // User-related errors
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrInvalidCredentials    = errors.New("invalid credentials")
+	ErrEmailAlreadyExists    = errors.New("email already exists")
+	ErrUserDisabled          = errors.New("user account is disabled")
+	ErrPasswordResetRequired = errors.New("password reset required")
+	ErrInvalidRole           = errors.New("role must be user or admin")
+	ErrAdminSelfChange       = errors.New("administrators cannot disable, delete or demote themselves")
+	ErrIncorrectPassword     = errors.New("current password is incorrect")
+	ErrPasswordReused        = errors.New("password was used recently")
+	ErrInvalidPassword       = errors.New("invalid password")
+	ErrInvalidResetToken     = errors.New("invalid or expired reset token")
+	ErrInvalidPasswordPolicy = errors.New("password policy must require 8 to 72 characters and remember at most 24 passwords")
+	ErrInvalidTimezone       = errors.New("invalid time zone: must be an IANA name such as Europe/Berlin")
+	ErrInvalidLocale         = errors.New("invalid locale: must be a BCP 47 language tag such as en-US")
 )
 
 // Portfolio-related errors
@@ -15,6 +26,7 @@ var (
 	ErrPortfolioNameRequired  = errors.New("portfolio name is required")
 	ErrInvalidCurrency        = errors.New("invalid currency code")
 	ErrInvalidCostBasisMethod = errors.New("invalid cost basis method")
+	ErrInvalidTaxRegime       = errors.New("invalid tax regime: must be US, UK or CA")
 	ErrPortfolioDuplicateName = errors.New("portfolio with this name already exists")
 	ErrUnauthorizedAccess     = errors.New("unauthorized access to portfolio")
 	ErrInvalidPortfolioID     = errors.New("invalid portfolio ID")
@@ -28,27 +40,280 @@ var (
 	ErrInvalidPrice           = errors.New("invalid price")
 	ErrInvalidSymbol          = errors.New("invalid symbol")
 	ErrInsufficientShares     = errors.New("insufficient shares for sale")
+	ErrInvalidAssetType       = errors.New("invalid asset type: must be EQUITY, CRYPTO or OPTION")
+	ErrInvalidCryptoPair      = errors.New("invalid crypto symbol: must be a coin pair such as BTC-USD")
+	ErrInvalidCryptoQuantity  = errors.New("invalid crypto quantity: at most 8 decimal places")
+	ErrInvalidCryptoCurrency  = errors.New("invalid crypto currency: must be the coin pair's quote currency")
+	ErrInvalidOptionSymbol    = errors.New("invalid option symbol: must be an OCC symbol such as AAPL240621C00190000")
+	ErrInvalidOptionQuantity  = errors.New("invalid option quantity: must be whole contracts")
+	ErrInvalidOptionTrade     = errors.New("invalid transaction type for the asset: options trade with BUY_TO_OPEN and SELL_TO_CLOSE")
+	ErrInvalidIncomeCategory  = errors.New("invalid category: only income transactions have one, of at most 50 characters")
+	ErrInvalidCashFlow        = errors.New("invalid cash flow: deposits and withdrawals are recorded under the code of the currency moved, such as USD")
+	ErrInvalidCapitalGainTerm = errors.New("invalid category: a capital gain distribution's term is SHORT_TERM or LONG_TERM")
+)
+
+// Tag-related errors
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrInvalidTag  = errors.New("invalid tag: must be 1-50 characters without commas")
+	ErrTooManyTags = errors.New("too many tags: at most 20 per portfolio or transaction")
+)
+
+// Portfolio group-related errors
+var (
+	ErrPortfolioGroupNotFound      = errors.New("portfolio group not found")
+	ErrPortfolioGroupNameRequired  = errors.New("portfolio group name is required")
+	ErrPortfolioGroupDuplicateName = errors.New("portfolio group with this name already exists")
+	ErrPortfolioGroupCycle         = errors.New("a portfolio group can't be nested under itself or one of its subgroups")
+	ErrPortfolioGroupTooDeep       = errors.New("portfolio groups can be nested at most 5 levels deep")
+	ErrInsufficientGroupData       = errors.New("no portfolio in the group has performance snapshots for the period")
+)
+
+// Option-related errors
+var (
+	ErrOptionContractNotFound  = errors.New("option contract not found")
+	ErrInvalidOptionContract   = errors.New("invalid option contract: needs an underlying symbol, CALL or PUT, a positive strike, an expiry and a positive multiplier")
+	ErrInvalidOptionSettlement = errors.New("invalid option settlement: must be EXPIRE or ASSIGN")
+	ErrOptionNotExpired        = errors.New("option contract can't be settled before its expiry")
+	ErrNoOptionPosition        = errors.New("portfolio holds no contracts of this option")
+)
+
+// Import-related errors
+var (
+	ErrInvalidImportFile        = errors.New("invalid import file")
+	ErrImportNotFound           = errors.New("import not found")
+	ErrInvalidPositionStatement = errors.New("invalid position statement")
 )
 
 // Holding-related errors
 var (
-	ErrHoldingNotFound = errors.New("holding not found")
+	ErrHoldingNotFound  = errors.New("holding not found")
+	ErrDuplicateHolding = errors.New("the portfolio already has a holding for this symbol")
 )
 
 // Tax lot-related errors
 var (
-	ErrTaxLotNotFound = errors.New("tax lot not found")
+	ErrTaxLotNotFound     = errors.New("tax lot not found")
+	ErrUnsupportedTaxForm = errors.New("tax forms can only be exported for portfolios under the US tax regime")
 )
 
 // Corporate action-related errors
 var (
-	ErrCorporateActionNotFound    = errors.New("corporate action not found")
-	ErrInvalidCorporateActionType = errors.New("invalid corporate action type")
+	ErrCorporateActionNotFound        = errors.New("corporate action not found")
+	ErrInvalidCorporateActionType     = errors.New("invalid corporate action type")
+	ErrCorporateActionApplyFailed     = errors.New("failed to apply corporate action")
+	ErrPortfolioActionNotFound        = errors.New("portfolio action not found")
+	ErrPortfolioActionNotPending      = errors.New("portfolio action is not pending")
+	ErrInvalidCostBasisAllocation     = errors.New("spinoff cost basis allocation must be greater than 0 and less than 1")
+	ErrFairMarketValueUnavailable     = errors.New("closing prices for a fair market value allocation are not available")
+	ErrInvalidSpinoffAllocationMethod = errors.New("a spinoff cost basis allocation must be given with the EXPLICIT allocation method and only with it")
+)
+
+// Stock plan-related errors
+var (
+	ErrStockPlanGrantNotFound   = errors.New("stock plan grant not found")
+	ErrInvalidStockPlanType     = errors.New("invalid stock plan type")
+	ErrInvalidVestingSchedule   = errors.New("invalid vesting schedule")
+	ErrStockPlanTypeMismatch    = errors.New("operation not supported for this stock plan type")
+	ErrInsufficientVestedShares = errors.New("insufficient vested shares")
+	ErrStockPlanGrantExpired    = errors.New("stock plan grant has expired")
+)
+
+// Blackout-related errors
+var (
+	ErrEmployerStockPolicyNotFound = errors.New("employer stock policy not found")
+	ErrInvalidBlackoutEnforcement  = errors.New("invalid blackout enforcement")
+	ErrBlackoutWindowNotFound      = errors.New("blackout window not found")
+	ErrInvalidBlackoutWindow       = errors.New("invalid blackout window: end date must not be before start date")
+	ErrBlackoutPeriod              = errors.New("transaction falls within a trading blackout window")
+)
+
+// Rebalance plan-related errors
+var (
+	ErrRebalancePlanNotFound     = errors.New("rebalance plan not found")
+	ErrRebalancePlanNameRequired = errors.New("rebalance plan name is required")
+	ErrRebalancePlanNoTrades     = errors.New("rebalance plan must contain at least one trade")
+	ErrRebalancePlanNotActive    = errors.New("rebalance plan is not active")
+	ErrRebalanceTradeNotFound    = errors.New("rebalance plan trade not found")
+	ErrRebalanceTradeNotPending  = errors.New("rebalance plan trade has already been executed or skipped")
+	ErrRebalanceFillMismatch     = errors.New("transaction does not match the planned trade")
+	ErrRebalanceSymbolRestricted = errors.New("trading is halted or suspended for the symbol")
+)
+
+// Market data-related errors
+var (
+	ErrQuotaExceeded          = errors.New("market data provider quota exceeded")
+	ErrMarketDataUnavailable  = errors.New("market data is not available")
+	ErrSecurityNotFound       = errors.New("security not found")
+	ErrInvalidSecurityQuery   = errors.New("search query must be between 1 and 50 characters")
+	ErrUnknownSymbol          = errors.New("unknown symbol")
+	ErrInvalidPriceResolution = errors.New("invalid resolution: must be 1m, 5m, 1h, 1d or 1w")
+	ErrIntradayRangeTooLong   = errors.New("intraday prices are only available for periods of up to 31 days")
 )
 
 // Performance snapshot-related errors
 var (
-	ErrPerformanceSnapshotNotFound = errors.New("performance snapshot not found")
+	ErrPerformanceSnapshotNotFound    = errors.New("performance snapshot not found")
+	ErrInvalidRetentionPolicy         = errors.New("snapshot retention must keep daily snapshots for at least one month and weekly snapshots at least as long")
+	ErrInvalidPerformancePeriod       = errors.New("invalid period: must be 1M, 3M, YTD, 1Y, 3Y or ALL")
+	ErrInsufficientPerformanceHistory = errors.New("the portfolio's performance snapshots don't cover the period")
+	ErrDuplicateSnapshot              = errors.New("the portfolio already has a performance snapshot for this date")
+)
+
+// Rounding-related errors
+var (
+	ErrInvalidRoundingPolicy = errors.New("rounding policy must use HALF_UP or HALF_EVEN and between 0 and 8 decimal places")
+)
+
+// Peer comparison-related errors
+var (
+	ErrPeerComparisonNotOptedIn       = errors.New("portfolio has not opted in to peer comparison")
+	ErrPeerBenchmarkNotFound          = errors.New("not enough peers to publish a benchmark for this allocation bucket")
+	ErrInsufficientPeerComparisonData = errors.New("not enough holdings or performance history for peer comparison")
+)
+
+// Performance certification-related errors
+var (
+	ErrInsufficientCertificationData = errors.New("at least two performance snapshots in the period are needed to certify performance")
+	ErrInvalidCertificationPeriod    = errors.New("certification end date must be after its start date")
+	ErrUnsupportedCertification      = errors.New("unsupported performance certification format")
+)
+
+// Projection-related errors
+var (
+	ErrInvalidProjectionMethod       = errors.New("projection method must be EXPECTED_RETURN or HISTORICAL")
+	ErrInvalidProjectionHorizon      = errors.New("projection horizon must be between 1 and 600 months, or a target date at least a month away")
+	ErrInvalidProjection             = errors.New("contributions, starting value, target value and volatility can't be negative, and expected return must be above -100%")
+	ErrExpectedReturnRequired        = errors.New("expected_return is required for an EXPECTED_RETURN projection")
+	ErrInsufficientProjectionHistory = errors.New("at least 12 months of performance snapshots are needed for a HISTORICAL projection")
+	ErrProjectionStartingValue       = errors.New("the portfolio has no performance snapshot to start from: starting_value is required")
+)
+
+// Simulation-related errors
+var (
+	ErrSimulationNotFound            = errors.New("simulation not found")
+	ErrInvalidSimulation             = errors.New("withdrawals, inflation, starting value and volatility can't be negative, and expected return must be above -100%")
+	ErrInsufficientSimulationHistory = errors.New("at least 12 months of performance snapshots are needed to measure the portfolio's return and volatility: pass expected_return and volatility instead")
+)
+
+// What-if-related errors
+var (
+	ErrInvalidWhatIf = errors.New("what-if trades need a positive quantity and price, tax rates and target weights must be between 0 and 100, and target weights can't add up to more than 100")
+)
+
+// Statement-related errors
+var (
+	ErrInvalidStatementPeriod = errors.New("statement period must be a month (2024-11) or a quarter (2024-Q4) that has started")
+)
+
+// Fee-related errors
+var (
+	ErrInvalidFeeYear = errors.New("fee summary year must have started")
+)
+
+// Contribution analysis-related errors
+var (
+	ErrInvalidContributionPeriod = errors.New("contribution analysis end date must be after its start date")
+)
+
+// Report subscription-related errors
+var (
+	ErrReportSubscriptionNotFound = errors.New("report subscription not found")
+	ErrInvalidReportFrequency     = errors.New("report frequency must be WEEKLY or MONTHLY")
+	ErrInvalidUnsubscribeToken    = errors.New("unsubscribe link is invalid")
+)
+
+// Fee comparison-related errors
+var (
+	ErrInvalidFeeSchedule        = errors.New("fee schedule needs a name, a type of ZERO_COMMISSION, PER_SHARE or PERCENTAGE, a positive rate and a minimum no greater than its maximum")
+	ErrFeeSchedulePresetNotFound = errors.New("fee schedule preset not found")
+)
+
+// Recalculation-related errors
+var (
+	ErrLedgerReplayFailed = errors.New("transaction history cannot be replayed")
+)
+
+// Organization provisioning-related errors
+var (
+	ErrOrganizationNotFound     = errors.New("organization not found")
+	ErrOrganizationNameRequired = errors.New("organization name is required")
+	ErrInvalidExternalID        = errors.New("external ID must be 1-255 letters, digits, '.', '_' or '-'")
+	ErrInvalidQuota             = errors.New("quota must not be negative")
+	ErrUserQuotaExceeded        = errors.New("organization user quota exceeded")
+	ErrPortfolioQuotaExceeded   = errors.New("organization portfolio quota exceeded")
+	ErrAPIKeyQuotaExceeded      = errors.New("organization API key quota exceeded")
+	ErrAPIKeyNotFound           = errors.New("API key not found")
+	ErrAPIKeyOwnerChanged       = errors.New("API key owner cannot be changed")
+	ErrInvalidAPIKey            = errors.New("invalid or revoked API key")
+)
+
+// Organization membership-related errors
+var (
+	ErrOrganizationMemberNotFound   = errors.New("organization member not found")
+	ErrNotOrganizationMember        = errors.New("not a member of the organization")
+	ErrAlreadyOrganizationMember    = errors.New("user is already a member of the organization")
+	ErrInvalidOrganizationRole      = errors.New("role must be owner, admin, member or viewer")
+	ErrInsufficientOrganizationRole = errors.New("your role in the organization does not allow this")
+	ErrLastOrganizationOwner        = errors.New("an organization must keep at least one owner")
+	ErrInvitationNotFound           = errors.New("invitation not found")
+	ErrInvalidInvitation            = errors.New("invalid or expired invitation")
+	ErrInvitationEmailMismatch      = errors.New("invitation was sent to a different email address")
+)
+
+// Advisor delegation-related errors
+var (
+	ErrDelegationNotFound           = errors.New("delegation not found")
+	ErrInvalidDelegationAccess      = errors.New("access must be read or manage")
+	ErrSelfDelegation               = errors.New("you cannot request access to your own portfolios")
+	ErrDelegationExists             = errors.New("a delegation between you and this client is already pending or active")
+	ErrInvalidConsentToken          = errors.New("invalid or expired consent token")
+	ErrConsentEmailMismatch         = errors.New("access was requested from a different email address")
+	ErrDelegationPortfoliosRequired = errors.New("at least one portfolio must be delegated")
+	ErrDelegationNotActive          = errors.New("delegation is not active")
+	ErrNotDelegationClient          = errors.New("only the client may choose the delegated portfolios")
+	ErrDelegationReadOnly           = errors.New("delegated access is read-only")
+	ErrDelegationForbidden          = errors.New("this cannot be done on behalf of a client")
+)
+
+// Job queue-related errors
+var (
+	ErrQueuedJobNotFound   = errors.New("job not found")
+	ErrJobQueueUnavailable = errors.New("background jobs are not available")
+	// ErrJobCheckpointed is returned by queued job handlers that stopped early, when their
+	// context was cancelled, at a point the job can resume from. The progress the job
+	// reported last records where that is.
+	ErrJobCheckpointed = errors.New("job stopped at a checkpoint")
+)
+
+// Notification-related errors
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrPushDeviceNotFound   = errors.New("push device not found")
+	ErrInvalidPushDevice    = errors.New("invalid push device: Web Push needs an https endpoint with its p256dh and auth keys, APNs a hex device token")
+	ErrPushDeviceLimit      = errors.New("too many push devices registered")
+	ErrPushUnavailable      = errors.New("web push is not configured")
+)
+
+// Broker connection errors
+var (
+	ErrBrokerConnectionNotFound = errors.New("broker connection not found")
+	ErrInvalidBrokerConnection  = errors.New("invalid broker connection")
+	ErrBrokerSyncUnavailable    = errors.New("broker syncing is not configured")
+	// ErrBrokerSyncFailed is returned when the provider couldn't be reached or refused the
+	// connection's credentials
+	ErrBrokerSyncFailed = errors.New("broker sync failed")
+)
+
+// Feature flag errors
+var (
+	ErrFeatureNotFound = errors.New("feature flag not found")
+)
+
+// Concurrency-related errors
+var (
+	ErrVersionRequired = errors.New("updates must give the version they were made against")
+	ErrVersionConflict = errors.New("the record was changed by another request")
 )
 
 // General validation errors
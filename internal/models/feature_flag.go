@@ -0,0 +1,73 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Feature flags turn experimental routes on and off without a release: per environment with
+// the configuration, and for everyone or single users with overrides stored in the database
+const (
+	// FeatureSimulations gates the Monte Carlo retirement simulations
+	FeatureSimulations = "simulations"
+	// FeatureProjections gates the projections of a portfolio's future value
+	FeatureProjections = "projections"
+	// FeatureWhatIf gates the previews of hypothetical trades
+	FeatureWhatIf = "what_if"
+	// FeaturePeerComparison gates the anonymized peer percentile comparison
+	FeaturePeerComparison = "peer_comparison"
+)
+
+// featureDefaults holds every feature flag with whether it is on when neither the
+// configuration nor an override sets it
+var featureDefaults = map[string]bool{
+	FeatureSimulations:    true,
+	FeatureProjections:    true,
+	FeatureWhatIf:         true,
+	FeaturePeerComparison: true,
+}
+
+// Features returns the name of every feature flag, sorted
+func Features() []string {
+	names := make([]string, 0, len(featureDefaults))
+	for name := range featureDefaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FeatureDefault returns whether a feature is on when nothing sets it, and whether the
+// feature exists
+func FeatureDefault(name string) (enabled, ok bool) {
+	enabled, ok = featureDefaults[name]
+	return enabled, ok
+}
+
+// FeatureFlagOverride turns a feature on or off for one user, or for everyone when UserID is
+// nil, taking precedence over the configuration. A user's override takes precedence over one
+// for everyone. Overrides are kept in the public schema, next to the users.
+type FeatureFlagOverride struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	Name      string     `gorm:"type:varchar(50);not null" json:"name"`
+	UserID    *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"`
+	Enabled   bool       `gorm:"not null" json:"enabled"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the FeatureFlagOverride model
+func (FeatureFlagOverride) TableName() string {
+	return "feature_flag_overrides"
+}
+
+// BeforeCreate hook to generate UUID before creating a new override
+func (o *FeatureFlagOverride) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// FeeScheduleType is how a broker charges for a trade
+type FeeScheduleType string
+
+const (
+	// FeeScheduleTypeZeroCommission charges nothing per trade
+	FeeScheduleTypeZeroCommission FeeScheduleType = "ZERO_COMMISSION"
+	// FeeScheduleTypePerShare charges Rate for every share traded
+	FeeScheduleTypePerShare FeeScheduleType = "PER_SHARE"
+	// FeeScheduleTypePercentage charges Rate percent of the trade value
+	FeeScheduleTypePercentage FeeScheduleType = "PERCENTAGE"
+)
+
+var oneHundred = decimal.NewFromInt(100)
+
+// FeeSchedule describes what a broker would charge per trade. Minimum and Maximum bound the
+// fee of a single order; zero means no bound.
+type FeeSchedule struct {
+	// Key identifies a preset; it is empty for schedules supplied by the user
+	Key     string          `json:"key,omitempty"`
+	Name    string          `json:"name"`
+	Type    FeeScheduleType `json:"type"`
+	Rate    decimal.Decimal `json:"rate"`
+	Minimum decimal.Decimal `json:"minimum"`
+	Maximum decimal.Decimal `json:"maximum"`
+}
+
+// feeSchedulePresets are common broker pricing models, in display order
+var feeSchedulePresets = []FeeSchedule{
+	{
+		Key:  "ZERO_COMMISSION",
+		Name: "Zero commission",
+		Type: FeeScheduleTypeZeroCommission,
+	},
+	{
+		Key:     "PER_SHARE_0_005",
+		Name:    "$0.005 per share, $1.00 minimum",
+		Type:    FeeScheduleTypePerShare,
+		Rate:    decimal.RequireFromString("0.005"),
+		Minimum: decimal.NewFromInt(1),
+	},
+	{
+		Key:     "PER_SHARE_0_01",
+		Name:    "$0.01 per share, $1.00 minimum, $10.00 maximum",
+		Type:    FeeScheduleTypePerShare,
+		Rate:    decimal.RequireFromString("0.01"),
+		Minimum: decimal.NewFromInt(1),
+		Maximum: decimal.NewFromInt(10),
+	},
+	{
+		Key:     "PERCENTAGE_0_10",
+		Name:    "0.10% of trade value, $1.00 minimum",
+		Type:    FeeScheduleTypePercentage,
+		Rate:    decimal.RequireFromString("0.10"),
+		Minimum: decimal.NewFromInt(1),
+	},
+	{
+		Key:     "PERCENTAGE_0_25",
+		Name:    "0.25% of trade value, $5.00 minimum",
+		Type:    FeeScheduleTypePercentage,
+		Rate:    decimal.RequireFromString("0.25"),
+		Minimum: decimal.NewFromInt(5),
+	},
+}
+
+// FeeSchedulePresets returns the built-in fee schedules
+func FeeSchedulePresets() []FeeSchedule {
+	presets := make([]FeeSchedule, len(feeSchedulePresets))
+	copy(presets, feeSchedulePresets)
+	return presets
+}
+
+// FindFeeSchedulePreset returns the built-in fee schedule with the given key
+func FindFeeSchedulePreset(key string) (FeeSchedule, error) {
+	for _, preset := range feeSchedulePresets {
+		if strings.EqualFold(preset.Key, key) {
+			return preset, nil
+		}
+	}
+	return FeeSchedule{}, ErrFeeSchedulePresetNotFound
+}
+
+// Validate checks if the fee schedule has valid data
+func (f *FeeSchedule) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return ErrInvalidFeeSchedule
+	}
+	switch f.Type {
+	case FeeScheduleTypeZeroCommission:
+		return nil
+	case FeeScheduleTypePerShare, FeeScheduleTypePercentage:
+	default:
+		return ErrInvalidFeeSchedule
+	}
+
+	if !f.Rate.IsPositive() || f.Minimum.IsNegative() || f.Maximum.IsNegative() {
+		return ErrInvalidFeeSchedule
+	}
+	if f.Maximum.IsPositive() && f.Maximum.LessThan(f.Minimum) {
+		return ErrInvalidFeeSchedule
+	}
+	return nil
+}
+
+// Fee returns what the schedule charges for an order of quantity shares at price
+func (f *FeeSchedule) Fee(quantity, price decimal.Decimal) decimal.Decimal {
+	var fee decimal.Decimal
+	switch f.Type {
+	case FeeScheduleTypePerShare:
+		fee = quantity.Abs().Mul(f.Rate)
+	case FeeScheduleTypePercentage:
+		fee = quantity.Abs().Mul(price).Mul(f.Rate).Div(oneHundred)
+	default:
+		return decimal.Zero
+	}
+
+	if fee.LessThan(f.Minimum) {
+		fee = f.Minimum
+	}
+	if f.Maximum.IsPositive() && fee.GreaterThan(f.Maximum) {
+		fee = f.Maximum
+	}
+	return fee.Round(2)
+}
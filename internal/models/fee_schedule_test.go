@@ -0,0 +1,90 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeSchedulePresets(t *testing.T) {
+	presets := FeeSchedulePresets()
+	require.NotEmpty(t, presets)
+	for _, preset := range presets {
+		assert.NoError(t, preset.Validate(), preset.Key)
+	}
+
+	found, err := FindFeeSchedulePreset("per_share_0_005")
+	require.NoError(t, err)
+	assert.Equal(t, FeeScheduleTypePerShare, found.Type)
+
+	_, err = FindFeeSchedulePreset("missing")
+	assert.Equal(t, ErrFeeSchedulePresetNotFound, err)
+
+	// Callers can't modify the presets
+	presets[0].Name = "Changed"
+	assert.NotEqual(t, "Changed", FeeSchedulePresets()[0].Name)
+}
+
+func TestFeeSchedule_Validate(t *testing.T) {
+	valid := FeeSchedule{Name: "Custom", Type: FeeScheduleTypePerShare, Rate: decimal.RequireFromString("0.01")}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(f *FeeSchedule)
+	}{
+		{"missing name", func(f *FeeSchedule) { f.Name = " " }},
+		{"unknown type", func(f *FeeSchedule) { f.Type = "FLAT" }},
+		{"zero rate", func(f *FeeSchedule) { f.Rate = decimal.Zero }},
+		{"negative minimum", func(f *FeeSchedule) { f.Minimum = decimal.NewFromInt(-1) }},
+		{"maximum below minimum", func(f *FeeSchedule) {
+			f.Minimum = decimal.NewFromInt(5)
+			f.Maximum = decimal.NewFromInt(1)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := valid
+			tt.modify(&schedule)
+			assert.Equal(t, ErrInvalidFeeSchedule, schedule.Validate())
+		})
+	}
+}
+
+func TestFeeSchedule_Fee(t *testing.T) {
+	price := decimal.NewFromInt(50)
+	perShare := FeeSchedule{
+		Type:    FeeScheduleTypePerShare,
+		Rate:    decimal.RequireFromString("0.01"),
+		Minimum: decimal.NewFromInt(1),
+		Maximum: decimal.NewFromInt(10),
+	}
+	percentage := FeeSchedule{
+		Type:    FeeScheduleTypePercentage,
+		Rate:    decimal.RequireFromString("0.25"),
+		Minimum: decimal.NewFromInt(5),
+	}
+	zero := FeeSchedule{Type: FeeScheduleTypeZeroCommission}
+
+	tests := []struct {
+		name     string
+		schedule FeeSchedule
+		quantity int64
+		expected string
+	}{
+		{"per share", perShare, 500, "5"},
+		{"per share minimum", perShare, 10, "1"},
+		{"per share maximum", perShare, 5000, "10"},
+		{"percentage", percentage, 100, "12.5"},
+		{"percentage minimum", percentage, 10, "5"},
+		{"zero commission", zero, 100, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fee := tt.schedule.Fee(decimal.NewFromInt(tt.quantity), price)
+			assert.True(t, decimal.RequireFromString(tt.expected).Equal(fee), "got %s", fee)
+		})
+	}
+}
@@ -11,11 +11,13 @@ import (
 // Holding represents the current position of a symbol in a portfolio
 type Holding struct {
 	ID           uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
-	PortfolioID  uuid.UUID       `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
-	Symbol       string          `gorm:"type:varchar(20);not null;index" json:"symbol" validate:"required"`
+	PortfolioID  uuid.UUID       `gorm:"type:uuid;not null;index;uniqueIndex:idx_holdings_portfolio_symbol" json:"portfolio_id" validate:"required"`
+	Symbol       string          `gorm:"type:varchar(20);not null;index;uniqueIndex:idx_holdings_portfolio_symbol" json:"symbol" validate:"required"`
+	AssetType    AssetType       `gorm:"type:varchar(10);not null;default:'EQUITY'" json:"asset_type"`
 	Quantity     decimal.Decimal `gorm:"type:numeric(20,8);not null" json:"quantity" validate:"required"`
 	CostBasis    decimal.Decimal `gorm:"type:numeric(20,8);not null" json:"cost_basis" validate:"required"`
 	AvgCostPrice decimal.Decimal `gorm:"type:numeric(20,8);not null" json:"avg_cost_price" validate:"required"`
+	Multiplier   int             `gorm:"not null;default:1" json:"multiplier"`
 	UpdatedAt    time.Time       `json:"updated_at"`
 	Portfolio    *Portfolio      `gorm:"foreignKey:PortfolioID" json:"portfolio,omitempty"`
 }
@@ -30,6 +32,12 @@ func (h *Holding) BeforeCreate(tx *gorm.DB) error {
 	if h.ID == uuid.Nil {
 		h.ID = uuid.New()
 	}
+	if h.AssetType == "" {
+		h.AssetType = AssetTypeForSymbol(h.Symbol)
+	}
+	if h.Multiplier == 0 {
+		h.Multiplier = 1
+	}
 	if h.UpdatedAt.IsZero() {
 		h.UpdatedAt = time.Now().UTC()
 	}
@@ -69,10 +77,19 @@ func (h *Holding) RemoveShares(quantity, costBasis decimal.Decimal) error {
 	return nil
 }
 
+// MarketValue returns the value of the holding at marketPrice. Option prices are per share,
+// so option holdings are worth price x contracts x multiplier.
+func (h *Holding) MarketValue(marketPrice decimal.Decimal) decimal.Decimal {
+	value := marketPrice.Mul(h.Quantity)
+	if h.Multiplier > 1 {
+		value = value.Mul(decimal.NewFromInt(int64(h.Multiplier)))
+	}
+	return value
+}
+
 // CalculateUnrealizedGain calculates the unrealized gain/loss given current market price
 func (h *Holding) CalculateUnrealizedGain(marketPrice decimal.Decimal) decimal.Decimal {
-	currentValue := marketPrice.Mul(h.Quantity)
-	return currentValue.Sub(h.CostBasis)
+	return h.MarketValue(marketPrice).Sub(h.CostBasis)
 }
 
 // CalculateUnrealizedGainPercent calculates the unrealized gain/loss percentage
@@ -26,6 +26,14 @@ func TestHolding_BeforeCreate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, holding.ID)
 	assert.False(t, holding.UpdatedAt.IsZero())
+	assert.Equal(t, AssetTypeEquity, holding.AssetType)
+
+	t.Run("takes the asset type from a coin pair", func(t *testing.T) {
+		holding := &Holding{PortfolioID: uuid.New(), Symbol: "ETH-EUR"}
+
+		assert.NoError(t, holding.BeforeCreate(db))
+		assert.Equal(t, AssetTypeCrypto, holding.AssetType)
+	})
 }
 
 func TestHolding_BeforeUpdate(t *testing.T) {
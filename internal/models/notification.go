@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationType is the kind of event a notification tells a user about
+type NotificationType string
+
+const (
+	// NotificationTypeCorporateAction tells that a corporate action awaits approval on a
+	// portfolio
+	NotificationTypeCorporateAction NotificationType = "CORPORATE_ACTION"
+	// NotificationTypeImportCompleted tells that a queued import finished
+	NotificationTypeImportCompleted NotificationType = "IMPORT_COMPLETED"
+	// NotificationTypeImportFailed tells that a queued import stopped with an error
+	NotificationTypeImportFailed NotificationType = "IMPORT_FAILED"
+	// NotificationTypeReportGenerated tells that a scheduled performance digest was sent
+	NotificationTypeReportGenerated NotificationType = "REPORT_GENERATED"
+)
+
+// IsValid returns true if the notification type is recognized
+func (t NotificationType) IsValid() bool {
+	switch t {
+	case NotificationTypeCorporateAction, NotificationTypeImportCompleted, NotificationTypeImportFailed,
+		NotificationTypeReportGenerated:
+		return true
+	}
+	return false
+}
+
+// Notification is an in-app message about something that happened to a user's portfolios
+// while they weren't looking. Notifications are kept in the public schema, next to their
+// users; PortfolioID is set when the event concerns one portfolio.
+type Notification struct {
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID        `gorm:"type:uuid;not null;index" json:"user_id"`
+	PortfolioID *uuid.UUID       `gorm:"type:uuid" json:"portfolio_id,omitempty"`
+	Type        NotificationType `gorm:"type:varchar(30);not null" json:"type"`
+	Title       string           `gorm:"type:varchar(255);not null" json:"title"`
+	Message     string           `gorm:"type:text;not null" json:"message"`
+	ReadAt      *time.Time       `json:"read_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// TableName specifies the table name for the Notification model
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// BeforeCreate hook to generate UUID before creating a new notification
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// IsRead returns true once the user has marked the notification read
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// OptionType is whether an option contract is a call or a put
+type OptionType string
+
+const (
+	OptionTypeCall OptionType = "CALL"
+	OptionTypePut  OptionType = "PUT"
+)
+
+// DefaultOptionMultiplier is the number of underlying shares a standard listed equity option
+// covers
+const DefaultOptionMultiplier = 100
+
+// optionSymbolPattern matches OCC option symbols without padding: the underlying, the
+// expiry as YYMMDD, C or P, and the strike in thousandths as eight digits
+var optionSymbolPattern = regexp.MustCompile(`^([A-Z]{1,5})(\d{6})([CP])(\d{8})$`)
+
+// optionStrikeScale is the number of decimal places of the strike in an OCC symbol
+const optionStrikeScale = 3
+
+// OptionContract is a listed option on an equity. Transactions and holdings in the contract
+// use its OCC symbol and count contracts, each covering Multiplier shares of Underlying.
+type OptionContract struct {
+	ID         uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	Symbol     string          `gorm:"type:varchar(20);not null;uniqueIndex" json:"symbol"`
+	Underlying string          `gorm:"type:varchar(10);not null;index" json:"underlying" validate:"required"`
+	Type       OptionType      `gorm:"column:option_type;type:varchar(4);not null" json:"type" validate:"required"`
+	Strike     decimal.Decimal `gorm:"type:numeric(20,8);not null" json:"strike" validate:"required"`
+	Expiry     time.Time       `gorm:"type:date;not null;index" json:"expiry" validate:"required"`
+	Multiplier int             `gorm:"not null;default:100" json:"multiplier"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// TableName specifies the table name for the OptionContract model
+func (OptionContract) TableName() string {
+	return "option_contracts"
+}
+
+// BeforeCreate hook to generate UUID and the OCC symbol before creating a new contract
+func (c *OptionContract) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	if c.Multiplier == 0 {
+		c.Multiplier = DefaultOptionMultiplier
+	}
+	if c.Symbol == "" {
+		c.Symbol = OptionSymbol(c.Underlying, c.Expiry, c.Type, c.Strike)
+	}
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// Validate checks the contract terms can be written as an OCC symbol
+func (c *OptionContract) Validate() error {
+	if c.Underlying == "" || len(c.Underlying) > 5 || c.Expiry.IsZero() {
+		return ErrInvalidOptionContract
+	}
+	if c.Type != OptionTypeCall && c.Type != OptionTypePut {
+		return ErrInvalidOptionContract
+	}
+	if !c.Strike.IsPositive() || !hasAtMostDecimals(c.Strike, optionStrikeScale) || c.Strike.Shift(optionStrikeScale).GreaterThanOrEqual(decimal.New(1, 8)) {
+		return ErrInvalidOptionContract
+	}
+	if c.Multiplier < 0 {
+		return ErrInvalidOptionContract
+	}
+	if c.Symbol != "" && c.Symbol != OptionSymbol(c.Underlying, c.Expiry, c.Type, c.Strike) {
+		return ErrInvalidOptionContract
+	}
+	return nil
+}
+
+// IsExpired returns true once the contract's expiry day has passed at asOf
+func (c *OptionContract) IsExpired(asOf time.Time) bool {
+	return asOf.UTC().Truncate(24 * time.Hour).After(c.Expiry.UTC().Truncate(24 * time.Hour))
+}
+
+// IsInTheMoney returns true if exercising the contract with the underlying at
+// underlyingPrice is worth more than letting it lapse
+func (c *OptionContract) IsInTheMoney(underlyingPrice decimal.Decimal) bool {
+	return c.IntrinsicValue(underlyingPrice).IsPositive()
+}
+
+// IntrinsicValue returns what exercising the contract is worth per underlying share with the
+// underlying at underlyingPrice: how far a call's strike is below the price, or a put's above
+func (c *OptionContract) IntrinsicValue(underlyingPrice decimal.Decimal) decimal.Decimal {
+	value := underlyingPrice.Sub(c.Strike)
+	if c.Type == OptionTypePut {
+		value = value.Neg()
+	}
+	if value.IsNegative() {
+		return decimal.Zero
+	}
+	return value
+}
+
+// OptionSymbol returns the OCC symbol of a contract, such as AAPL240621C00190000 for the
+// AAPL 21 June 2024 190 call
+func OptionSymbol(underlying string, expiry time.Time, optionType OptionType, strike decimal.Decimal) string {
+	side := "C"
+	if optionType == OptionTypePut {
+		side = "P"
+	}
+	return fmt.Sprintf("%s%s%s%08d", underlying, expiry.UTC().Format("060102"), side,
+		strike.Shift(optionStrikeScale).IntPart())
+}
+
+// IsOptionSymbol returns true if symbol is an OCC option symbol
+func IsOptionSymbol(symbol string) bool {
+	_, err := ParseOptionSymbol(symbol)
+	return err == nil
+}
+
+// ParseOptionSymbol reads the contract terms from an OCC option symbol. The multiplier is
+// not part of the symbol and is set to DefaultOptionMultiplier.
+func ParseOptionSymbol(symbol string) (*OptionContract, error) {
+	match := optionSymbolPattern.FindStringSubmatch(symbol)
+	if match == nil {
+		return nil, ErrInvalidOptionSymbol
+	}
+	expiry, err := time.Parse("060102", match[2])
+	if err != nil {
+		return nil, ErrInvalidOptionSymbol
+	}
+	strike, err := strconv.ParseInt(match[4], 10, 64)
+	if err != nil || strike == 0 {
+		return nil, ErrInvalidOptionSymbol
+	}
+
+	optionType := OptionTypeCall
+	if match[3] == "P" {
+		optionType = OptionTypePut
+	}
+	return &OptionContract{
+		Symbol:     symbol,
+		Underlying: match[1],
+		Type:       optionType,
+		Strike:     decimal.New(strike, -optionStrikeScale),
+		Expiry:     expiry,
+		Multiplier: DefaultOptionMultiplier,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// externalIDPattern matches the identifiers provisioning tools assign to organizations,
+// users and API keys. They appear in URL paths, so they are restricted to URL-safe characters.
+var externalIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,254}$`)
+
+// IsValidExternalID returns true if id can be used as an external ID
+func IsValidExternalID(id string) bool {
+	return externalIDPattern.MatchString(id)
+}
+
+// schemaNamePattern matches Postgres identifiers that never need quoting or case folding
+var schemaNamePattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// IsValidSchemaName returns true if name can be used as a tenant schema name
+func IsValidSchemaName(name string) bool {
+	return schemaNamePattern.MatchString(name) && !strings.HasPrefix(name, "pg_")
+}
+
+// TenantSchemaName returns the schema name assigned to an organization's data in
+// multi-schema mode. It is derived from the organization ID rather than the external ID,
+// which may not be a valid identifier and could be reused.
+func TenantSchemaName(organizationID uuid.UUID) string {
+	return "tenant_" + strings.ReplaceAll(organizationID.String(), "-", "")
+}
+
+// Organization groups users provisioned through the admin API and holds the quotas that
+// apply to them. A quota of zero means unlimited.
+type Organization struct {
+	ID                   uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	ExternalID           string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"external_id" validate:"required"`
+	Name                 string    `gorm:"type:varchar(255);not null" json:"name" validate:"required"`
+	MaxUsers             int       `gorm:"not null;default:0" json:"max_users"`
+	MaxPortfoliosPerUser int       `gorm:"not null;default:0" json:"max_portfolios_per_user"`
+	MaxAPIKeys           int       `gorm:"not null;default:0" json:"max_api_keys"`
+	// SchemaName is the Postgres schema holding the organization's portfolio data, or nil if
+	// it is kept in the shared tables
+	SchemaName *string   `gorm:"type:varchar(63);uniqueIndex" json:"schema_name,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Organization model
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// BeforeCreate hook to generate UUID before creating a new organization
+func (o *Organization) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	if o.CreatedAt.IsZero() {
+		o.CreatedAt = time.Now().UTC()
+	}
+	if o.UpdatedAt.IsZero() {
+		o.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (o *Organization) BeforeUpdate(tx *gorm.DB) error {
+	o.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the organization has valid data
+func (o *Organization) Validate() error {
+	if !IsValidExternalID(o.ExternalID) {
+		return ErrInvalidExternalID
+	}
+	if strings.TrimSpace(o.Name) == "" {
+		return ErrOrganizationNameRequired
+	}
+	if o.MaxUsers < 0 || o.MaxPortfoliosPerUser < 0 || o.MaxAPIKeys < 0 {
+		return ErrInvalidQuota
+	}
+	return nil
+}
+
+// UserQuotaReached returns true if an organization with count users may not add another
+func (o *Organization) UserQuotaReached(count int64) bool {
+	return quotaReached(o.MaxUsers, count)
+}
+
+// PortfolioQuotaReached returns true if a member owning count portfolios may not create another
+func (o *Organization) PortfolioQuotaReached(count int64) bool {
+	return quotaReached(o.MaxPortfoliosPerUser, count)
+}
+
+// APIKeyQuotaReached returns true if an organization with count active API keys may not issue another
+func (o *Organization) APIKeyQuotaReached(count int64) bool {
+	return quotaReached(o.MaxAPIKeys, count)
+}
+
+// quotaReached applies a quota where zero means unlimited
+func quotaReached(limit int, count int64) bool {
+	return limit > 0 && count >= int64(limit)
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrganizationRole is a member's level of access to an organization
+type OrganizationRole string
+
+const (
+	// OrgRoleOwner can do everything an admin can, and manage other owners
+	OrgRoleOwner OrganizationRole = "owner"
+	// OrgRoleAdmin can also manage the organization's members, invitations and API keys
+	OrgRoleAdmin OrganizationRole = "admin"
+	// OrgRoleMember can create and change the organization's portfolios
+	OrgRoleMember OrganizationRole = "member"
+	// OrgRoleViewer can only read the organization's portfolios
+	OrgRoleViewer OrganizationRole = "viewer"
+)
+
+// IsValid returns true if the role is known
+func (r OrganizationRole) IsValid() bool {
+	switch r {
+	case OrgRoleOwner, OrgRoleAdmin, OrgRoleMember, OrgRoleViewer:
+		return true
+	}
+	return false
+}
+
+// CanManageMembers returns true if the role may invite, change and remove members and
+// issue API keys
+func (r OrganizationRole) CanManageMembers() bool {
+	return r == OrgRoleOwner || r == OrgRoleAdmin
+}
+
+// CanWrite returns true if the role may change the organization's portfolios
+func (r OrganizationRole) CanWrite() bool {
+	return r != OrgRoleViewer
+}
+
+// OrganizationMember is a user's membership of an organization
+type OrganizationMember struct {
+	OrganizationID uuid.UUID        `gorm:"type:uuid;primaryKey" json:"organization_id"`
+	UserID         uuid.UUID        `gorm:"type:uuid;primaryKey;index" json:"user_id"`
+	Role           OrganizationRole `gorm:"type:varchar(10);not null" json:"role"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+	Organization   *Organization    `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	User           *User            `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+// TableName specifies the table name for the OrganizationMember model
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// BeforeCreate hook to set timestamps before creating a new membership
+func (m *OrganizationMember) BeforeCreate(tx *gorm.DB) error {
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = time.Now().UTC()
+	}
+	if m.UpdatedAt.IsZero() {
+		m.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (m *OrganizationMember) BeforeUpdate(tx *gorm.DB) error {
+	m.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// OrganizationInvitation invites whoever signs in with Email to join an organization with
+// Role. Only a hash of the invitation token is stored.
+type OrganizationInvitation struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	OrganizationID uuid.UUID        `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Email          string           `gorm:"type:varchar(255);not null" json:"email"`
+	Role           OrganizationRole `gorm:"type:varchar(10);not null" json:"role"`
+	TokenHash      string           `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+	InvitedByID    *uuid.UUID       `gorm:"type:uuid" json:"invited_by_id,omitempty"`
+	ExpiresAt      time.Time        `gorm:"not null" json:"expires_at"`
+	AcceptedAt     *time.Time       `json:"accepted_at,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	Organization   *Organization    `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+}
+
+// TableName specifies the table name for the OrganizationInvitation model
+func (OrganizationInvitation) TableName() string {
+	return "organization_invitations"
+}
+
+// BeforeCreate hook to generate UUID before creating a new invitation
+func (i *OrganizationInvitation) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	if i.CreatedAt.IsZero() {
+		i.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// IsPending returns true if the invitation can still be accepted at now
+func (i *OrganizationInvitation) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
+
+// OrganizationScope is the organization a request acts in and the requesting user's role in
+// it. Role is empty for queued jobs, whose role was checked when they were requested.
+type OrganizationScope struct {
+	OrganizationID uuid.UUID
+	Role           OrganizationRole
+}
+
+type organizationScopeContextKey struct{}
+
+// WithOrganizationScope returns a copy of ctx acting in scope's organization, which gives
+// access to the organization's portfolios
+func WithOrganizationScope(ctx context.Context, scope OrganizationScope) context.Context {
+	return context.WithValue(ctx, organizationScopeContextKey{}, scope)
+}
+
+// OrganizationScopeFromContext returns the organization set with WithOrganizationScope, if any
+func OrganizationScopeFromContext(ctx context.Context) (OrganizationScope, bool) {
+	if ctx == nil {
+		return OrganizationScope{}, false
+	}
+	scope, ok := ctx.Value(organizationScopeContextKey{}).(OrganizationScope)
+	return scope, ok
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidExternalID(t *testing.T) {
+	valid := []string{"acme", "acme-corp", "team_1", "user.42", "A", strings.Repeat("a", 255)}
+	for _, id := range valid {
+		assert.True(t, IsValidExternalID(id), id)
+	}
+
+	invalid := []string{"", "-leading-dash", ".hidden", "has space", "slash/es", "percent%20", strings.Repeat("a", 256)}
+	for _, id := range invalid {
+		assert.False(t, IsValidExternalID(id), id)
+	}
+}
+
+func TestIsValidSchemaName(t *testing.T) {
+	valid := []string{"tenant_acme", "_private", "t1", strings.Repeat("a", 63)}
+	for _, name := range valid {
+		assert.True(t, IsValidSchemaName(name), name)
+	}
+
+	invalid := []string{"", "1tenant", "Tenant", "has-dash", "a.b", `x"; drop`, "pg_catalog", strings.Repeat("a", 64)}
+	for _, name := range invalid {
+		assert.False(t, IsValidSchemaName(name), name)
+	}
+}
+
+func TestTenantSchemaName(t *testing.T) {
+	id := uuid.MustParse("8f14e45f-ceea-467f-a0e6-7c2d0b1a5a3e")
+
+	name := TenantSchemaName(id)
+	assert.Equal(t, "tenant_8f14e45fceea467fa0e67c2d0b1a5a3e", name)
+	assert.True(t, IsValidSchemaName(name))
+}
+
+func TestOrganization_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		organization Organization
+		wantErr      error
+	}{
+		{"valid", Organization{ExternalID: "acme", Name: "Acme", MaxUsers: 10}, nil},
+		{"invalid external ID", Organization{ExternalID: "a b", Name: "Acme"}, ErrInvalidExternalID},
+		{"blank name", Organization{ExternalID: "acme", Name: "  "}, ErrOrganizationNameRequired},
+		{"negative quota", Organization{ExternalID: "acme", Name: "Acme", MaxAPIKeys: -1}, ErrInvalidQuota},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantErr, tt.organization.Validate())
+		})
+	}
+}
+
+func TestOrganization_QuotaReached(t *testing.T) {
+	organization := Organization{MaxUsers: 2, MaxPortfoliosPerUser: 0, MaxAPIKeys: 1}
+
+	assert.False(t, organization.UserQuotaReached(1))
+	assert.True(t, organization.UserQuotaReached(2))
+	// Zero means unlimited
+	assert.False(t, organization.PortfolioQuotaReached(1000))
+	assert.False(t, organization.APIKeyQuotaReached(0))
+	assert.True(t, organization.APIKeyQuotaReached(1))
+}
+
+func TestAPIKey_ValidateAndIsActive(t *testing.T) {
+	key := APIKey{
+		OrganizationID: uuid.New(),
+		ExternalID:     "ci",
+		UserID:         uuid.New(),
+		Prefix:         APIKeyPrefix + "abcdefgh",
+		KeyHash:        "hash",
+	}
+	assert.NoError(t, key.Validate())
+	assert.True(t, key.IsActive())
+
+	revokedAt := time.Now()
+	key.RevokedAt = &revokedAt
+	assert.False(t, key.IsActive())
+
+	key.Prefix = "sk_abcdefgh"
+	assert.Equal(t, ErrInvalidAPIKey, key.Validate())
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory is a password a user has replaced, kept so the password policy can stop
+// them from choosing it again
+type PasswordHistory struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the PasswordHistory model
+func (PasswordHistory) TableName() string {
+	return "password_history"
+}
+
+// BeforeCreate hook to generate UUID before creating a new password history entry
+func (ph *PasswordHistory) BeforeCreate(tx *gorm.DB) error {
+	if ph.ID == uuid.Nil {
+		ph.ID = uuid.New()
+	}
+	if ph.CreatedAt.IsZero() {
+		ph.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxPasswordBytes is the longest password bcrypt hashes in full
+const maxPasswordBytes = 72
+
+// maxPasswordHistory bounds how many previous passwords are checked, since each check is a
+// bcrypt comparison
+const maxPasswordHistory = 24
+
+// PasswordPolicy decides which passwords users may choose when they register, reset or change
+// their password
+type PasswordPolicy struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireNumber    bool
+	RequireSymbol    bool
+	// BannedPasswords can't be used, compared case-insensitively
+	BannedPasswords []string
+	// HistorySize is how many of a user's most recent passwords, counting the current one,
+	// a new password must differ from. Zero allows reusing any password.
+	HistorySize int
+}
+
+// DefaultPasswordPolicy requires 8 characters with an uppercase letter, a lowercase letter
+// and a number, and allows reusing passwords
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireNumber:    true,
+	}
+}
+
+// Validate checks if the policy is usable
+func (p PasswordPolicy) Validate() error {
+	if p.MinLength < 8 || p.MinLength > maxPasswordBytes {
+		return ErrInvalidPasswordPolicy
+	}
+	if p.HistorySize < 0 || p.HistorySize > maxPasswordHistory {
+		return ErrInvalidPasswordPolicy
+	}
+	return nil
+}
+
+// Check returns an error describing the first rule password breaks, or nil if it follows the
+// policy. Reuse of previous passwords is checked separately, against their hashes.
+func (p PasswordPolicy) Check(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("password must be at most %d bytes long", maxPasswordBytes)
+	}
+
+	var hasUpper, hasLower, hasNumber, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsNumber(char):
+			hasNumber = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSymbol = true
+		}
+	}
+
+	if p.RequireUppercase && !hasUpper {
+		return fmt.Errorf("password must contain at least one uppercase letter")
+	}
+	if p.RequireLowercase && !hasLower {
+		return fmt.Errorf("password must contain at least one lowercase letter")
+	}
+	if p.RequireNumber && !hasNumber {
+		return fmt.Errorf("password must contain at least one number")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		return fmt.Errorf("password must contain at least one symbol")
+	}
+
+	for _, banned := range p.BannedPasswords {
+		if strings.EqualFold(password, banned) {
+			return fmt.Errorf("password is too common")
+		}
+	}
+
+	return nil
+}
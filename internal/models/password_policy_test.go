@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	assert.NoError(t, DefaultPasswordPolicy().Validate())
+	assert.NoError(t, PasswordPolicy{MinLength: 12, HistorySize: 5}.Validate())
+	assert.Equal(t, ErrInvalidPasswordPolicy, PasswordPolicy{MinLength: 6}.Validate())
+	assert.Equal(t, ErrInvalidPasswordPolicy, PasswordPolicy{MinLength: 100}.Validate())
+	assert.Equal(t, ErrInvalidPasswordPolicy, PasswordPolicy{MinLength: 8, HistorySize: -1}.Validate())
+	assert.Equal(t, ErrInvalidPasswordPolicy, PasswordPolicy{MinLength: 8, HistorySize: 25}.Validate())
+}
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	policy.RequireSymbol = true
+	policy.BannedPasswords = []string{"Password123!"}
+
+	tests := []struct {
+		password string
+		wantErr  string
+	}{
+		{"Secure123!", ""},
+		{"Sec12!", "at least 8 characters"},
+		{"secure123!", "uppercase"},
+		{"SECURE123!", "lowercase"},
+		{"SecurePass!", "number"},
+		{"Secure1234", "symbol"},
+		{"pASSWORD123!", "too common"},
+		{"Secure123!" + strings.Repeat("a", 70), "at most 72 bytes"},
+	}
+
+	for _, tt := range tests {
+		err := policy.Check(tt.password)
+		if tt.wantErr == "" {
+			assert.NoError(t, err, tt.password)
+		} else {
+			assert.ErrorContains(t, err, tt.wantErr, tt.password)
+		}
+	}
+
+	// Rules that aren't required aren't checked
+	assert.NoError(t, PasswordPolicy{MinLength: 8}.Check("lowercase only"))
+}
@@ -0,0 +1,214 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// AllocationBucket groups portfolios with a similar allocation profile for peer comparison
+type AllocationBucket string
+
+const (
+	// AllocationBucketConcentrated holds portfolios whose largest position is at least 40%
+	AllocationBucketConcentrated AllocationBucket = "CONCENTRATED"
+	// AllocationBucketFocused holds portfolios whose largest position is at least 20%
+	AllocationBucketFocused AllocationBucket = "FOCUSED"
+	// AllocationBucketDiversified holds portfolios whose largest position is below 20%
+	AllocationBucketDiversified AllocationBucket = "DIVERSIFIED"
+)
+
+const (
+	// PeerBenchmarkMinSampleSize is the fewest contributing portfolios a bucket needs before
+	// its distribution is published, so that no single portfolio can be singled out
+	PeerBenchmarkMinSampleSize = 10
+
+	// PeerBenchmarkLookbackDays is the trailing window risk-adjusted returns are measured over
+	PeerBenchmarkLookbackDays = 365
+
+	// PeerBenchmarkMinObservations is the fewest snapshot-to-snapshot returns needed to
+	// measure a portfolio's risk-adjusted return
+	PeerBenchmarkMinObservations = 20
+)
+
+var (
+	concentratedWeight = decimal.NewFromFloat(0.40)
+	focusedWeight      = decimal.NewFromFloat(0.20)
+)
+
+// PeerBenchmark is the published, anonymized distribution of risk-adjusted returns for one
+// allocation bucket. Only quantiles are stored; individual returns, minimums and maximums
+// are never persisted.
+type PeerBenchmark struct {
+	ID         uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	Bucket     AllocationBucket `gorm:"type:varchar(20);not null;uniqueIndex" json:"bucket"`
+	SampleSize int              `gorm:"not null" json:"sample_size"`
+	P10        decimal.Decimal  `gorm:"type:numeric(12,6);not null" json:"p10"`
+	P25        decimal.Decimal  `gorm:"type:numeric(12,6);not null" json:"p25"`
+	P50        decimal.Decimal  `gorm:"type:numeric(12,6);not null" json:"p50"`
+	P75        decimal.Decimal  `gorm:"type:numeric(12,6);not null" json:"p75"`
+	P90        decimal.Decimal  `gorm:"type:numeric(12,6);not null" json:"p90"`
+	ComputedAt time.Time        `gorm:"not null" json:"computed_at"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// TableName specifies the table name for the PeerBenchmark model
+func (PeerBenchmark) TableName() string {
+	return "peer_benchmarks"
+}
+
+// BeforeCreate hook to generate UUID before creating a new peer benchmark
+func (pb *PeerBenchmark) BeforeCreate(tx *gorm.DB) error {
+	if pb.ID == uuid.Nil {
+		pb.ID = uuid.New()
+	}
+	if pb.CreatedAt.IsZero() {
+		pb.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// NewPeerBenchmark builds the published distribution for a bucket from its contributors'
+// risk-adjusted returns. It returns nil when there are too few contributors to publish.
+func NewPeerBenchmark(bucket AllocationBucket, values []float64, computedAt time.Time) *PeerBenchmark {
+	if len(values) < PeerBenchmarkMinSampleSize {
+		return nil
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	return &PeerBenchmark{
+		Bucket:     bucket,
+		SampleSize: len(sorted),
+		P10:        decimal.NewFromFloat(quantile(sorted, 0.10)).Round(6),
+		P25:        decimal.NewFromFloat(quantile(sorted, 0.25)).Round(6),
+		P50:        decimal.NewFromFloat(quantile(sorted, 0.50)).Round(6),
+		P75:        decimal.NewFromFloat(quantile(sorted, 0.75)).Round(6),
+		P90:        decimal.NewFromFloat(quantile(sorted, 0.90)).Round(6),
+		ComputedAt: computedAt,
+	}
+}
+
+// Percentile estimates which percentile a risk-adjusted return falls into by interpolating
+// between the published quantiles. Returns below P10 or above P90 are reported as 5 and 95,
+// since the extremes of the distribution are not published.
+func (pb *PeerBenchmark) Percentile(value decimal.Decimal) int {
+	points := []struct {
+		pct   float64
+		value decimal.Decimal
+	}{
+		{10, pb.P10}, {25, pb.P25}, {50, pb.P50}, {75, pb.P75}, {90, pb.P90},
+	}
+
+	if value.LessThan(pb.P10) {
+		return 5
+	}
+	if value.GreaterThan(pb.P90) {
+		return 95
+	}
+
+	for i := 1; i < len(points); i++ {
+		lower, upper := points[i-1], points[i]
+		if value.GreaterThan(upper.value) {
+			continue
+		}
+		span := upper.value.Sub(lower.value)
+		if span.IsZero() {
+			return int(lower.pct)
+		}
+		fraction, _ := value.Sub(lower.value).Div(span).Float64()
+		return int(math.Round(lower.pct + fraction*(upper.pct-lower.pct)))
+	}
+
+	return 90
+}
+
+// ClassifyAllocationBucket assigns a portfolio to an allocation bucket by the cost-basis
+// weight of its largest position. It returns false when the portfolio holds nothing.
+func ClassifyAllocationBucket(holdings []*Holding) (AllocationBucket, bool) {
+	total := decimal.Zero
+	largest := decimal.Zero
+	for _, holding := range holdings {
+		if !holding.Quantity.IsPositive() || !holding.CostBasis.IsPositive() {
+			continue
+		}
+		total = total.Add(holding.CostBasis)
+		if holding.CostBasis.GreaterThan(largest) {
+			largest = holding.CostBasis
+		}
+	}
+	if total.IsZero() {
+		return "", false
+	}
+
+	weight := largest.Div(total)
+	switch {
+	case weight.GreaterThanOrEqual(concentratedWeight):
+		return AllocationBucketConcentrated, true
+	case weight.GreaterThanOrEqual(focusedWeight):
+		return AllocationBucketFocused, true
+	default:
+		return AllocationBucketDiversified, true
+	}
+}
+
+// RiskAdjustedReturn computes an annualized Sharpe-style ratio (mean return over its
+// standard deviation, with a zero risk-free rate) from a date-ordered series of snapshots.
+// Each period's return is the change in unrealized gain over the previous total value, so
+// buying more shares does not count as performance. It returns false when there are too
+// few observations or the returns do not vary.
+func RiskAdjustedReturn(snapshots []*PerformanceSnapshot) (decimal.Decimal, bool) {
+	returns := make([]float64, 0, len(snapshots))
+	for i := 1; i < len(snapshots); i++ {
+		previous, current := snapshots[i-1], snapshots[i]
+		if !previous.TotalValue.IsPositive() {
+			continue
+		}
+		r, _ := current.TotalReturn.Sub(previous.TotalReturn).Div(previous.TotalValue).Float64()
+		returns = append(returns, r)
+	}
+	if len(returns) < PeerBenchmarkMinObservations {
+		return decimal.Zero, false
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	stdDev := math.Sqrt(variance / float64(len(returns)-1))
+	if stdDev < 1e-12 {
+		return decimal.Zero, false
+	}
+
+	// Annualize using the observed snapshot frequency
+	span := snapshots[len(snapshots)-1].Date.Sub(snapshots[0].Date).Hours() / 24 / 365.25
+	if span <= 0 {
+		return decimal.Zero, false
+	}
+	periodsPerYear := float64(len(returns)) / span
+
+	return decimal.NewFromFloat(mean / stdDev * math.Sqrt(periodsPerYear)).Round(6), true
+}
+
+// quantile returns the q-th quantile of sorted values using linear interpolation
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (position-float64(lower))*(sorted[upper]-sorted[lower])
+}
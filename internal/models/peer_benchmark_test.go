@@ -0,0 +1,166 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPeerBenchmark(t *testing.T) {
+	computedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("too few contributors", func(t *testing.T) {
+		values := make([]float64, PeerBenchmarkMinSampleSize-1)
+		assert.Nil(t, NewPeerBenchmark(AllocationBucketFocused, values, computedAt))
+	})
+
+	t.Run("quantiles", func(t *testing.T) {
+		// 0, 1, ..., 10 in shuffled order
+		values := []float64{5, 3, 9, 0, 10, 1, 7, 2, 8, 4, 6}
+		benchmark := NewPeerBenchmark(AllocationBucketFocused, values, computedAt)
+		require.NotNil(t, benchmark)
+
+		assert.Equal(t, AllocationBucketFocused, benchmark.Bucket)
+		assert.Equal(t, 11, benchmark.SampleSize)
+		assert.True(t, benchmark.P10.Equal(decimal.NewFromInt(1)), "p10 %s", benchmark.P10)
+		assert.True(t, benchmark.P25.Equal(decimal.NewFromFloat(2.5)), "p25 %s", benchmark.P25)
+		assert.True(t, benchmark.P50.Equal(decimal.NewFromInt(5)), "p50 %s", benchmark.P50)
+		assert.True(t, benchmark.P75.Equal(decimal.NewFromFloat(7.5)), "p75 %s", benchmark.P75)
+		assert.True(t, benchmark.P90.Equal(decimal.NewFromInt(9)), "p90 %s", benchmark.P90)
+		assert.Equal(t, computedAt, benchmark.ComputedAt)
+		// The input is not reordered
+		assert.Equal(t, 5.0, values[0])
+	})
+}
+
+func TestPeerBenchmark_Percentile(t *testing.T) {
+	benchmark := &PeerBenchmark{
+		P10: decimal.NewFromInt(1),
+		P25: decimal.NewFromInt(2),
+		P50: decimal.NewFromInt(4),
+		P75: decimal.NewFromInt(6),
+		P90: decimal.NewFromInt(8),
+	}
+
+	tests := []struct {
+		name  string
+		value decimal.Decimal
+		want  int
+	}{
+		{"below published range", decimal.NewFromFloat(0.5), 5},
+		{"at p10", decimal.NewFromInt(1), 10},
+		{"between p25 and p50", decimal.NewFromInt(3), 38},
+		{"at median", decimal.NewFromInt(4), 50},
+		{"between p75 and p90", decimal.NewFromInt(7), 83},
+		{"at p90", decimal.NewFromInt(8), 90},
+		{"above published range", decimal.NewFromInt(9), 95},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, benchmark.Percentile(tt.value))
+		})
+	}
+
+	t.Run("flat distribution", func(t *testing.T) {
+		flat := &PeerBenchmark{P10: decimal.NewFromInt(1), P25: decimal.NewFromInt(1), P50: decimal.NewFromInt(1),
+			P75: decimal.NewFromInt(1), P90: decimal.NewFromInt(1)}
+		assert.Equal(t, 10, flat.Percentile(decimal.NewFromInt(1)))
+	})
+}
+
+func TestClassifyAllocationBucket(t *testing.T) {
+	holding := func(symbol string, costBasis int64) *Holding {
+		return &Holding{Symbol: symbol, Quantity: decimal.NewFromInt(1), CostBasis: decimal.NewFromInt(costBasis)}
+	}
+
+	tests := []struct {
+		name     string
+		holdings []*Holding
+		want     AllocationBucket
+		ok       bool
+	}{
+		{"no holdings", nil, "", false},
+		{"closed positions only", []*Holding{{Symbol: "AAPL", Quantity: decimal.Zero, CostBasis: decimal.Zero}}, "", false},
+		{"single position", []*Holding{holding("AAPL", 1000)}, AllocationBucketConcentrated, true},
+		{"largest at 40%", []*Holding{holding("AAPL", 40), holding("MSFT", 30), holding("GOOG", 30)}, AllocationBucketConcentrated, true},
+		{"largest at 25%", []*Holding{holding("A", 25), holding("B", 25), holding("C", 25), holding("D", 25)}, AllocationBucketFocused, true},
+		{"largest at 10%", []*Holding{
+			holding("A", 10), holding("B", 10), holding("C", 10), holding("D", 10), holding("E", 10),
+			holding("F", 10), holding("G", 10), holding("H", 10), holding("I", 10), holding("J", 10),
+		}, AllocationBucketDiversified, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, ok := ClassifyAllocationBucket(tt.holdings)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, bucket)
+		})
+	}
+}
+
+// snapshotSeries builds daily snapshots whose unrealized gain alternates between rising by
+// up and by down on a constant total value
+func snapshotSeries(days int, up, down float64) []*PerformanceSnapshot {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	value := decimal.NewFromInt(10000)
+	gain := decimal.Zero
+	snapshots := make([]*PerformanceSnapshot, 0, days)
+	for i := 0; i < days; i++ {
+		if i > 0 {
+			step := up
+			if i%2 == 0 {
+				step = down
+			}
+			gain = gain.Add(decimal.NewFromFloat(step))
+		}
+		snapshots = append(snapshots, &PerformanceSnapshot{
+			Date:        start.AddDate(0, 0, i),
+			TotalValue:  value,
+			TotalReturn: gain,
+		})
+	}
+	return snapshots
+}
+
+func TestRiskAdjustedReturn(t *testing.T) {
+	t.Run("too few observations", func(t *testing.T) {
+		_, ok := RiskAdjustedReturn(snapshotSeries(PeerBenchmarkMinObservations, 20, -10))
+		assert.False(t, ok)
+	})
+
+	t.Run("no variation", func(t *testing.T) {
+		_, ok := RiskAdjustedReturn(snapshotSeries(60, 10, 10))
+		assert.False(t, ok)
+	})
+
+	t.Run("positive drift ranks above negative drift", func(t *testing.T) {
+		good, ok := RiskAdjustedReturn(snapshotSeries(60, 30, -10))
+		require.True(t, ok)
+		bad, ok := RiskAdjustedReturn(snapshotSeries(60, 10, -30))
+		require.True(t, ok)
+
+		assert.True(t, good.IsPositive())
+		assert.True(t, bad.IsNegative())
+	})
+
+	t.Run("new contributions are not counted as returns", func(t *testing.T) {
+		snapshots := snapshotSeries(60, 30, -10)
+		base, ok := RiskAdjustedReturn(snapshots)
+		require.True(t, ok)
+
+		// Doubling total value from day 30 onward without changing the gain only scales
+		// later returns, it does not create a jump
+		for _, snapshot := range snapshots[30:] {
+			snapshot.TotalValue = snapshot.TotalValue.Mul(decimal.NewFromInt(2))
+		}
+		withDeposit, ok := RiskAdjustedReturn(snapshots)
+		require.True(t, ok)
+		assert.True(t, withDeposit.IsPositive())
+		assert.True(t, withDeposit.Sub(base).Abs().LessThan(base), "base %s, with deposit %s", base, withDeposit)
+	})
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PerformancePeriod is a standard period performance metrics are materialized for, ending
+// on the day they are computed
+type PerformancePeriod string
+
+const (
+	PerformancePeriod1M  PerformancePeriod = "1M"
+	PerformancePeriod3M  PerformancePeriod = "3M"
+	PerformancePeriodYTD PerformancePeriod = "YTD"
+	PerformancePeriod1Y  PerformancePeriod = "1Y"
+	PerformancePeriod3Y  PerformancePeriod = "3Y"
+	// PerformancePeriodAll starts at the portfolio's first snapshot
+	PerformancePeriodAll PerformancePeriod = "ALL"
+)
+
+// StandardPerformancePeriods are the periods materialized for every portfolio, shortest first
+var StandardPerformancePeriods = []PerformancePeriod{
+	PerformancePeriod1M,
+	PerformancePeriod3M,
+	PerformancePeriodYTD,
+	PerformancePeriod1Y,
+	PerformancePeriod3Y,
+	PerformancePeriodAll,
+}
+
+// ParsePerformancePeriod parses a standard period, ignoring case
+func ParsePerformancePeriod(value string) (PerformancePeriod, error) {
+	period := PerformancePeriod(strings.ToUpper(strings.TrimSpace(value)))
+	for _, standard := range StandardPerformancePeriods {
+		if period == standard {
+			return period, nil
+		}
+	}
+	return "", ErrInvalidPerformancePeriod
+}
+
+// StartDate returns when the period ending at end starts. inception, the date of the
+// portfolio's first snapshot, starts the ALL period.
+func (p PerformancePeriod) StartDate(end, inception time.Time) time.Time {
+	switch p {
+	case PerformancePeriod1M:
+		return end.AddDate(0, -1, 0)
+	case PerformancePeriod3M:
+		return end.AddDate(0, -3, 0)
+	case PerformancePeriodYTD:
+		return time.Date(end.Year(), time.January, 1, 0, 0, 0, 0, end.Location())
+	case PerformancePeriod1Y:
+		return end.AddDate(-1, 0, 0)
+	case PerformancePeriod3Y:
+		return end.AddDate(-3, 0, 0)
+	default:
+		return inception
+	}
+}
+
+// PerformanceMetricsCache holds a portfolio's performance metrics over a standard period,
+// stored as JSON. SourceStamp identifies the transactions and snapshots they were computed
+// from: metrics whose stamp no longer matches the portfolio's are stale.
+type PerformanceMetricsCache struct {
+	ID          uuid.UUID         `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_performance_metrics_cache_portfolio_period" json:"portfolio_id"`
+	Period      PerformancePeriod `gorm:"type:varchar(3);not null;uniqueIndex:idx_performance_metrics_cache_portfolio_period" json:"period"`
+	StartDate   time.Time         `gorm:"not null" json:"start_date"`
+	EndDate     time.Time         `gorm:"not null" json:"end_date"`
+	SourceStamp string            `gorm:"type:varchar(100);not null" json:"-"`
+	Metrics     string            `gorm:"type:text;not null" json:"-"`
+	ComputedAt  time.Time         `gorm:"not null" json:"computed_at"`
+}
+
+// TableName specifies the table name for the PerformanceMetricsCache model
+func (PerformanceMetricsCache) TableName() string {
+	return "performance_metrics_cache"
+}
+
+// BeforeCreate hook to generate UUID before creating a new cache entry
+func (c *PerformanceMetricsCache) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	if c.ComputedAt.IsZero() {
+		c.ComputedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// SetMetrics stores the metrics as JSON
+func (c *PerformanceMetricsCache) SetMetrics(metrics any) error {
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return fmt.Errorf("failed to encode performance metrics: %w", err)
+	}
+	c.Metrics = string(data)
+	return nil
+}
+
+// DecodeMetrics reads the stored metrics into v
+func (c *PerformanceMetricsCache) DecodeMetrics(v any) error {
+	if err := json.Unmarshal([]byte(c.Metrics), v); err != nil {
+		return fmt.Errorf("failed to decode performance metrics: %w", err)
+	}
+	return nil
+}
+
+// IsFresh returns true if the metrics were computed from the data sourceStamp identifies on
+// the same UTC day as now, so the period they cover still ends today
+func (c *PerformanceMetricsCache) IsFresh(sourceStamp string, now time.Time) bool {
+	computed := c.ComputedAt.UTC()
+	now = now.UTC()
+	return c.SourceStamp == sourceStamp &&
+		computed.Year() == now.Year() && computed.YearDay() == now.YearDay()
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePerformancePeriod(t *testing.T) {
+	period, err := ParsePerformancePeriod("ytd")
+	require.NoError(t, err)
+	assert.Equal(t, PerformancePeriodYTD, period)
+
+	period, err = ParsePerformancePeriod(" 3Y ")
+	require.NoError(t, err)
+	assert.Equal(t, PerformancePeriod3Y, period)
+
+	_, err = ParsePerformancePeriod("5Y")
+	assert.ErrorIs(t, err, ErrInvalidPerformancePeriod)
+}
+
+func TestPerformancePeriod_StartDate(t *testing.T) {
+	end := time.Date(2025, 5, 15, 18, 0, 0, 0, time.UTC)
+	inception := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := map[PerformancePeriod]time.Time{
+		PerformancePeriod1M:  time.Date(2025, 4, 15, 18, 0, 0, 0, time.UTC),
+		PerformancePeriod3M:  time.Date(2025, 2, 15, 18, 0, 0, 0, time.UTC),
+		PerformancePeriodYTD: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		PerformancePeriod1Y:  time.Date(2024, 5, 15, 18, 0, 0, 0, time.UTC),
+		PerformancePeriod3Y:  time.Date(2022, 5, 15, 18, 0, 0, 0, time.UTC),
+		PerformancePeriodAll: inception,
+	}
+	for period, want := range tests {
+		assert.Equal(t, want, period.StartDate(end, inception), period)
+	}
+}
+
+func TestPerformanceMetricsCache_IsFresh(t *testing.T) {
+	computed := time.Date(2025, 5, 15, 2, 0, 0, 0, time.UTC)
+	entry := &PerformanceMetricsCache{SourceStamp: "3|a|b", ComputedAt: computed}
+
+	assert.True(t, entry.IsFresh("3|a|b", computed.Add(20*time.Hour)))
+	assert.False(t, entry.IsFresh("4|a|b", computed.Add(time.Hour)), "the portfolio's data changed")
+	assert.False(t, entry.IsFresh("3|a|b", computed.Add(22*time.Hour)), "the period now ends a day later")
+}
+
+func TestPerformanceMetricsCache_Metrics(t *testing.T) {
+	var entry PerformanceMetricsCache
+	require.NoError(t, entry.SetMetrics(map[string]string{"total_return": "12.5"}))
+
+	var metrics map[string]string
+	require.NoError(t, entry.DecodeMetrics(&metrics))
+	assert.Equal(t, "12.5", metrics["total_return"])
+}
@@ -19,6 +19,15 @@ type PerformanceSnapshot struct {
 	TotalReturnPct decimal.Decimal  `gorm:"type:numeric(10,4);not null" json:"total_return_pct" validate:"required"`
 	DayChange      *decimal.Decimal `gorm:"type:numeric(20,8)" json:"day_change,omitempty"`
 	DayChangePct   *decimal.Decimal `gorm:"type:numeric(10,4)" json:"day_change_pct,omitempty"`
+	// The gain breakdown is cumulative from the portfolio's first transaction through Date.
+	// Realized gains are measured against average cost, like holdings' cost basis, and fees
+	// are the commissions, which cost basis and proceeds already include, and the fee
+	// transactions, which they don't. It is nil on snapshots taken before it was recorded or
+	// whose ledger couldn't be replayed.
+	RealizedGain   *decimal.Decimal `gorm:"type:numeric(20,8)" json:"realized_gain,omitempty"`
+	UnrealizedGain *decimal.Decimal `gorm:"type:numeric(20,8)" json:"unrealized_gain,omitempty"`
+	DividendIncome *decimal.Decimal `gorm:"type:numeric(20,8)" json:"dividend_income,omitempty"`
+	Fees           *decimal.Decimal `gorm:"type:numeric(20,8)" json:"fees,omitempty"`
 	CreatedAt      time.Time        `json:"created_at"`
 	Portfolio      *Portfolio       `gorm:"foreignKey:PortfolioID" json:"portfolio,omitempty"`
 }
@@ -82,3 +91,18 @@ func (ps *PerformanceSnapshot) CalculateDayChange(previousValue decimal.Decimal)
 		ps.DayChangePct = &zero
 	}
 }
+
+// SetGainBreakdown records the gains realized, the dividends received and the fees paid
+// through the snapshot's date, along with the unrealized gain of its value over cost basis
+func (ps *PerformanceSnapshot) SetGainBreakdown(realized, dividends, fees decimal.Decimal) {
+	unrealized := ps.TotalValue.Sub(ps.TotalCostBasis)
+	ps.RealizedGain = &realized
+	ps.UnrealizedGain = &unrealized
+	ps.DividendIncome = &dividends
+	ps.Fees = &fees
+}
+
+// HasGainBreakdown reports whether the snapshot records its gain breakdown
+func (ps *PerformanceSnapshot) HasGainBreakdown() bool {
+	return ps.RealizedGain != nil && ps.UnrealizedGain != nil && ps.DividendIncome != nil && ps.Fees != nil
+}
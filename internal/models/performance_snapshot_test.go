@@ -206,3 +206,19 @@ func TestPerformanceSnapshot_CalculateDayChange(t *testing.T) {
 		assert.True(t, snapshot.DayChangePct.Equal(decimal.Zero))
 	})
 }
+
+func TestPerformanceSnapshot_SetGainBreakdown(t *testing.T) {
+	snapshot := &PerformanceSnapshot{
+		TotalValue:     decimal.NewFromInt(12000),
+		TotalCostBasis: decimal.NewFromInt(10000),
+	}
+	assert.False(t, snapshot.HasGainBreakdown())
+
+	snapshot.SetGainBreakdown(decimal.NewFromInt(500), decimal.NewFromInt(120), decimal.NewFromInt(15))
+
+	assert.True(t, snapshot.HasGainBreakdown())
+	assert.True(t, snapshot.RealizedGain.Equal(decimal.NewFromInt(500)))
+	assert.True(t, snapshot.UnrealizedGain.Equal(decimal.NewFromInt(2000)))
+	assert.True(t, snapshot.DividendIncome.Equal(decimal.NewFromInt(120)))
+	assert.True(t, snapshot.Fees.Equal(decimal.NewFromInt(15)))
+}
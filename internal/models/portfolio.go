@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,17 +17,48 @@ const (
 	CostBasisSpecificLot CostBasisMethod = "SPECIFIC_LOT"
 )
 
+// TaxRegime is the country whose capital gains rules a portfolio's tax reports follow
+type TaxRegime string
+
+const (
+	// TaxRegimeUS matches sales to lots by the cost basis method and splits gains into short
+	// and long term
+	TaxRegimeUS TaxRegime = "US"
+	// TaxRegimeUK matches sales to same-day and next-30-day purchases, then to the Section 104
+	// pool of the other shares at their average cost
+	TaxRegimeUK TaxRegime = "UK"
+	// TaxRegimeCA costs sales at the adjusted cost base, the average cost of the shares held,
+	// and denies superficial losses
+	TaxRegimeCA TaxRegime = "CA"
+)
+
+// IsValid returns true if the tax regime is supported
+func (r TaxRegime) IsValid() bool {
+	switch r {
+	case TaxRegimeUS, TaxRegimeUK, TaxRegimeCA:
+		return true
+	default:
+		return false
+	}
+}
+
 // Portfolio represents a user's investment portfolio
 type Portfolio struct {
-	ID              uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
-	UserID          uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
-	Name            string          `gorm:"type:varchar(255);not null" json:"name" validate:"required,min=1,max=255"`
-	Description     string          `gorm:"type:text" json:"description,omitempty"`
-	BaseCurrency    string          `gorm:"type:varchar(3);not null;default:'USD'" json:"base_currency" validate:"required,len=3"`
-	CostBasisMethod CostBasisMethod `gorm:"type:varchar(20);not null;default:'FIFO'" json:"cost_basis_method" validate:"required,oneof=FIFO LIFO SPECIFIC_LOT"`
-	CreatedAt       time.Time       `json:"created_at"`
-	UpdatedAt       time.Time       `json:"updated_at"`
-	User            *User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
+	// OrganizationID is set for portfolios owned by an organization, which every member of
+	// the organization can reach. UserID is then the member who created it.
+	OrganizationID      *uuid.UUID      `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	Name                string          `gorm:"type:varchar(255);not null" json:"name" validate:"required,min=1,max=255"`
+	Description         string          `gorm:"type:text" json:"description,omitempty"`
+	BaseCurrency        string          `gorm:"type:varchar(3);not null;default:'USD'" json:"base_currency" validate:"required,len=3"`
+	CostBasisMethod     CostBasisMethod `gorm:"type:varchar(20);not null;default:'FIFO'" json:"cost_basis_method" validate:"required,oneof=FIFO LIFO SPECIFIC_LOT"`
+	TaxRegime           TaxRegime       `gorm:"type:varchar(2);not null;default:'US'" json:"tax_regime" validate:"required,oneof=US UK CA"`
+	PeerComparisonOptIn bool            `gorm:"not null;default:false" json:"peer_comparison_opt_in"`
+	Version             int             `gorm:"not null;default:1" json:"version"`
+	CreatedAt           time.Time       `json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+	User                *User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
 // TableName specifies the table name for the Portfolio model
@@ -51,6 +83,12 @@ func (p *Portfolio) BeforeCreate(tx *gorm.DB) error {
 	if p.CostBasisMethod == "" {
 		p.CostBasisMethod = CostBasisFIFO
 	}
+	if p.TaxRegime == "" {
+		p.TaxRegime = TaxRegimeUS
+	}
+	if p.Version == 0 {
+		p.Version = 1
+	}
 	return nil
 }
 
@@ -71,6 +109,9 @@ func (p *Portfolio) Validate() error {
 	if !p.isValidCostBasisMethod() {
 		return ErrInvalidCostBasisMethod
 	}
+	if p.TaxRegime != "" && !p.TaxRegime.IsValid() {
+		return ErrInvalidTaxRegime
+	}
 	return nil
 }
 
@@ -83,3 +124,20 @@ func (p *Portfolio) isValidCostBasisMethod() bool {
 		return false
 	}
 }
+
+// AccessibleBy returns true if userID may work with the portfolio: they own it, it belongs
+// to the organization ctx acts in, or it was delegated to them under the delegation ctx
+// acts under
+func (p *Portfolio) AccessibleBy(ctx context.Context, userID string) bool {
+	if p.UserID.String() == userID {
+		return true
+	}
+	if delegation, ok := DelegationScopeFromContext(ctx); ok && delegation.AdvisorID.String() == userID && delegation.Grants(p.ID) {
+		return true
+	}
+	if p.OrganizationID == nil {
+		return false
+	}
+	scope, ok := OrganizationScopeFromContext(ctx)
+	return ok && scope.OrganizationID == *p.OrganizationID
+}
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxPortfolioGroupDepth is how deeply groups can be nested, counting the top-level group
+const MaxPortfolioGroupDepth = 5
+
+// PortfolioGroup is a named set of a user's portfolios, such as "Retirement" or "Kids", that is
+// valued and measured as one. Groups can be nested under a parent group, whose roll-up then
+// includes the portfolios of its subgroups.
+type PortfolioGroup struct {
+	ID          uuid.UUID                  `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID      uuid.UUID                  `gorm:"type:uuid;not null;uniqueIndex:idx_portfolio_groups_user_id_name" json:"user_id"`
+	ParentID    *uuid.UUID                 `gorm:"type:uuid;index" json:"parent_id,omitempty"`
+	Name        string                     `gorm:"type:varchar(100);not null;uniqueIndex:idx_portfolio_groups_user_id_name" json:"name" validate:"required"`
+	Description string                     `gorm:"type:text" json:"description,omitempty"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	UpdatedAt   time.Time                  `json:"updated_at"`
+	Portfolios  []*PortfolioGroupPortfolio `gorm:"foreignKey:GroupID" json:"portfolios,omitempty"`
+	// SubgroupIDs lists the groups directly under this one; it is filled in from the hierarchy
+	// and not stored
+	SubgroupIDs []uuid.UUID `gorm:"-" json:"subgroup_ids,omitempty"`
+}
+
+// TableName specifies the table name for the PortfolioGroup model
+func (PortfolioGroup) TableName() string {
+	return "portfolio_groups"
+}
+
+// BeforeCreate hook to generate UUID before creating a new group
+func (g *PortfolioGroup) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = time.Now().UTC()
+	}
+	if g.UpdatedAt.IsZero() {
+		g.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (g *PortfolioGroup) BeforeUpdate(tx *gorm.DB) error {
+	g.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the group has valid data
+func (g *PortfolioGroup) Validate() error {
+	if g.UserID == uuid.Nil {
+		return ErrInvalidValue
+	}
+	if strings.TrimSpace(g.Name) == "" {
+		return ErrPortfolioGroupNameRequired
+	}
+	if g.ParentID != nil && *g.ParentID == g.ID {
+		return ErrPortfolioGroupCycle
+	}
+	return nil
+}
+
+// PortfolioIDs returns the IDs of the portfolios directly in the group
+func (g *PortfolioGroup) PortfolioIDs() []uuid.UUID {
+	ids := make([]uuid.UUID, len(g.Portfolios))
+	for i, portfolio := range g.Portfolios {
+		ids[i] = portfolio.PortfolioID
+	}
+	return ids
+}
+
+// PortfolioGroupPortfolio links a portfolio group to a portfolio in it. A portfolio can be in
+// more than one group.
+type PortfolioGroupPortfolio struct {
+	GroupID     uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	PortfolioID uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"portfolio_id"`
+}
+
+// TableName specifies the table name for the PortfolioGroupPortfolio model
+func (PortfolioGroupPortfolio) TableName() string {
+	return "portfolio_group_portfolios"
+}
+
+// PortfolioGroupTree indexes a user's groups to walk their hierarchy
+type PortfolioGroupTree struct {
+	groups   map[uuid.UUID]*PortfolioGroup
+	children map[uuid.UUID][]*PortfolioGroup
+}
+
+// NewPortfolioGroupTree builds the hierarchy of a user's groups
+func NewPortfolioGroupTree(groups []*PortfolioGroup) *PortfolioGroupTree {
+	tree := &PortfolioGroupTree{
+		groups:   make(map[uuid.UUID]*PortfolioGroup, len(groups)),
+		children: make(map[uuid.UUID][]*PortfolioGroup),
+	}
+	for _, group := range groups {
+		tree.groups[group.ID] = group
+		if group.ParentID != nil {
+			tree.children[*group.ParentID] = append(tree.children[*group.ParentID], group)
+		}
+	}
+	return tree
+}
+
+// Contains returns true if the tree has a group with the given ID
+func (t *PortfolioGroupTree) Contains(id uuid.UUID) bool {
+	_, ok := t.groups[id]
+	return ok
+}
+
+// Subgroups returns the groups directly under a group
+func (t *PortfolioGroupTree) Subgroups(id uuid.UUID) []*PortfolioGroup {
+	return t.children[id]
+}
+
+// RollUpPortfolioIDs returns the portfolios of a group and of all the groups under it, each once
+func (t *PortfolioGroupTree) RollUpPortfolioIDs(id uuid.UUID) []uuid.UUID {
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	visited := make(map[uuid.UUID]bool)
+
+	pending := []uuid.UUID{id}
+	for len(pending) > 0 {
+		groupID := pending[0]
+		pending = pending[1:]
+		if visited[groupID] {
+			continue
+		}
+		visited[groupID] = true
+
+		if group, ok := t.groups[groupID]; ok {
+			for _, portfolioID := range group.PortfolioIDs() {
+				if !seen[portfolioID] {
+					seen[portfolioID] = true
+					ids = append(ids, portfolioID)
+				}
+			}
+		}
+		for _, child := range t.children[groupID] {
+			pending = append(pending, child.ID)
+		}
+	}
+	return ids
+}
+
+// CheckParent returns an error if moving a group under parentID would nest it under itself or
+// one of its subgroups, or nest groups more than MaxPortfolioGroupDepth deep
+func (t *PortfolioGroupTree) CheckParent(id, parentID uuid.UUID) error {
+	// Depth of the parent, counting it, and whether the group is one of its ancestors
+	depth := 0
+	for current := &parentID; current != nil; {
+		if *current == id {
+			return ErrPortfolioGroupCycle
+		}
+		depth++
+		if depth > MaxPortfolioGroupDepth {
+			return ErrPortfolioGroupTooDeep
+		}
+		group, ok := t.groups[*current]
+		if !ok {
+			break
+		}
+		current = group.ParentID
+	}
+
+	if depth+t.height(id, make(map[uuid.UUID]bool)) > MaxPortfolioGroupDepth {
+		return ErrPortfolioGroupTooDeep
+	}
+	return nil
+}
+
+// height returns how many levels a group and the groups under it span
+func (t *PortfolioGroupTree) height(id uuid.UUID, visited map[uuid.UUID]bool) int {
+	if visited[id] {
+		return 0
+	}
+	visited[id] = true
+
+	tallest := 0
+	for _, child := range t.children[id] {
+		tallest = max(tallest, t.height(child.ID, visited))
+	}
+	return tallest + 1
+}
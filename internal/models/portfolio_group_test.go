@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// groupChain returns n groups, each nested under the one before it
+func groupChain(n int) []*PortfolioGroup {
+	groups := make([]*PortfolioGroup, n)
+	for i := range groups {
+		groups[i] = &PortfolioGroup{ID: uuid.New(), Name: "Group"}
+		if i > 0 {
+			groups[i].ParentID = &groups[i-1].ID
+		}
+	}
+	return groups
+}
+
+func TestPortfolioGroup_Validate(t *testing.T) {
+	group := &PortfolioGroup{ID: uuid.New(), UserID: uuid.New(), Name: "  "}
+	assert.Equal(t, ErrPortfolioGroupNameRequired, group.Validate())
+
+	group.Name = "Family"
+	group.ParentID = &group.ID
+	assert.Equal(t, ErrPortfolioGroupCycle, group.Validate())
+
+	group.ParentID = nil
+	assert.NoError(t, group.Validate())
+}
+
+func TestPortfolioGroupTree_RollUpPortfolioIDs(t *testing.T) {
+	groups := groupChain(3)
+	shared := uuid.New()
+	own := uuid.New()
+	groups[0].Portfolios = []*PortfolioGroupPortfolio{{PortfolioID: own}}
+	groups[1].Portfolios = []*PortfolioGroupPortfolio{{PortfolioID: shared}}
+	groups[2].Portfolios = []*PortfolioGroupPortfolio{{PortfolioID: shared}}
+
+	tree := NewPortfolioGroupTree(groups)
+
+	assert.Equal(t, []uuid.UUID{own, shared}, tree.RollUpPortfolioIDs(groups[0].ID))
+	assert.Equal(t, []uuid.UUID{shared}, tree.RollUpPortfolioIDs(groups[2].ID))
+	assert.Equal(t, []*PortfolioGroup{groups[1]}, tree.Subgroups(groups[0].ID))
+}
+
+func TestPortfolioGroupTree_CheckParent(t *testing.T) {
+	groups := groupChain(3)
+	tree := NewPortfolioGroupTree(groups)
+
+	// A group can't move under itself or one of its subgroups
+	assert.Equal(t, ErrPortfolioGroupCycle, tree.CheckParent(groups[0].ID, groups[0].ID))
+	assert.Equal(t, ErrPortfolioGroupCycle, tree.CheckParent(groups[0].ID, groups[2].ID))
+
+	other := groupChain(MaxPortfolioGroupDepth)
+	tree = NewPortfolioGroupTree(append(groups, other...))
+
+	// The chain of three fits under the second of five groups but not under the third
+	assert.NoError(t, tree.CheckParent(groups[0].ID, other[1].ID))
+	assert.Equal(t, ErrPortfolioGroupTooDeep, tree.CheckParent(groups[0].ID, other[2].ID))
+	assert.Equal(t, ErrPortfolioGroupTooDeep, tree.CheckParent(uuid.New(), other[MaxPortfolioGroupDepth-1].ID))
+	assert.NoError(t, tree.CheckParent(uuid.New(), other[MaxPortfolioGroupDepth-2].ID))
+}
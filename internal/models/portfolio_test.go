@@ -26,6 +26,7 @@ func TestPortfolio_BeforeCreate(t *testing.T) {
 		assert.False(t, portfolio.UpdatedAt.IsZero())
 		assert.Equal(t, "USD", portfolio.BaseCurrency)
 		assert.Equal(t, CostBasisFIFO, portfolio.CostBasisMethod)
+		assert.Equal(t, TaxRegimeUS, portfolio.TaxRegime)
 	})
 
 	t.Run("does not override existing values", func(t *testing.T) {
@@ -112,6 +113,27 @@ func TestPortfolio_Validate(t *testing.T) {
 		assert.Error(t, err)
 		assert.Equal(t, ErrInvalidCostBasisMethod, err)
 	})
+
+	t.Run("invalid tax regime error", func(t *testing.T) {
+		portfolio := &Portfolio{
+			Name:            "Test",
+			BaseCurrency:    "USD",
+			CostBasisMethod: CostBasisFIFO,
+			TaxRegime:       "FR",
+		}
+
+		err := portfolio.Validate()
+
+		assert.Equal(t, ErrInvalidTaxRegime, err)
+	})
+}
+
+func TestTaxRegime_IsValid(t *testing.T) {
+	for _, regime := range []TaxRegime{TaxRegimeUS, TaxRegimeUK, TaxRegimeCA} {
+		assert.True(t, regime.IsValid(), regime)
+	}
+	assert.False(t, TaxRegime("").IsValid())
+	assert.False(t, TaxRegime("us").IsValid())
 }
 
 func TestPortfolio_isValidCostBasisMethod(t *testing.T) {
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// PriceResolution is the length of each bar of a price history
+type PriceResolution string
+
+const (
+	// PriceResolution1Min is one bar per minute
+	PriceResolution1Min PriceResolution = "1m"
+	// PriceResolution5Min is one bar per five minutes
+	PriceResolution5Min PriceResolution = "5m"
+	// PriceResolution1Hour is one bar per hour
+	PriceResolution1Hour PriceResolution = "1h"
+	// PriceResolution1Day is one bar per trading day, the resolution when none is requested
+	PriceResolution1Day PriceResolution = "1d"
+	// PriceResolution1Week is one bar per week, built from its trading days
+	PriceResolution1Week PriceResolution = "1w"
+)
+
+// MaxIntradayRangeDays bounds the period of an intraday price history. Providers only keep
+// intraday bars for the last month or so.
+const MaxIntradayRangeDays = 31
+
+// IsValid returns true if the resolution is recognized
+func (r PriceResolution) IsValid() bool {
+	switch r {
+	case PriceResolution1Min, PriceResolution5Min, PriceResolution1Hour, PriceResolution1Day, PriceResolution1Week:
+		return true
+	}
+	return false
+}
+
+// IsIntraday returns true if bars are shorter than a trading day
+func (r PriceResolution) IsIntraday() bool {
+	return r == PriceResolution1Min || r == PriceResolution5Min || r == PriceResolution1Hour
+}
+
+// DefaultPeriod is the period a price history covers when no start date is requested: a day
+// of minute bars, a week of hourly bars, a month of daily bars or a year of weekly bars
+func (r PriceResolution) DefaultPeriod() time.Duration {
+	switch r {
+	case PriceResolution1Min, PriceResolution5Min:
+		return 24 * time.Hour
+	case PriceResolution1Hour:
+		return 7 * 24 * time.Hour
+	case PriceResolution1Week:
+		return 365 * 24 * time.Hour
+	default:
+		return 30 * 24 * time.Hour
+	}
+}
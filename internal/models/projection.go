@@ -0,0 +1,67 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ProjectionMethod is how a projection draws the portfolio's future monthly returns
+type ProjectionMethod string
+
+const (
+	// ProjectionMethodExpectedReturn compounds an expected annual return, with normally
+	// distributed monthly returns when a volatility is given
+	ProjectionMethodExpectedReturn ProjectionMethod = "EXPECTED_RETURN"
+	// ProjectionMethodHistorical resamples the portfolio's own monthly returns (Monte Carlo
+	// bootstrap)
+	ProjectionMethodHistorical ProjectionMethod = "HISTORICAL"
+)
+
+// IsValid returns true if the method is recognized
+func (m ProjectionMethod) IsValid() bool {
+	return m == ProjectionMethodExpectedReturn || m == ProjectionMethodHistorical
+}
+
+const (
+	// MaxProjectionMonths is the longest horizon a projection simulates (50 years)
+	MaxProjectionMonths = 600
+	// DefaultProjectionSimulations is the number of simulated paths when none is requested
+	DefaultProjectionSimulations = 1000
+	// MaxProjectionSimulations bounds the work a single projection does
+	MaxProjectionSimulations = 10000
+	// MinProjectionHistoryMonths is the number of monthly returns a historical projection
+	// resamples at least
+	MinProjectionHistoryMonths = 12
+)
+
+// ProjectionBand is the distribution of the simulated portfolio values at the end of one month
+// of a projection. Contributed is the starting value plus the contributions made so far.
+type ProjectionBand struct {
+	Month       int             `json:"month"`
+	Date        time.Time       `json:"date"`
+	Contributed decimal.Decimal `json:"contributed"`
+	P10         decimal.Decimal `json:"p10"`
+	P25         decimal.Decimal `json:"p25"`
+	P50         decimal.Decimal `json:"p50"`
+	P75         decimal.Decimal `json:"p75"`
+	P90         decimal.Decimal `json:"p90"`
+}
+
+// NewProjectionBand summarizes the values of the simulated paths at the end of a month.
+// values is sorted in place.
+func NewProjectionBand(month int, date time.Time, contributed decimal.Decimal, values []float64) ProjectionBand {
+	sort.Float64s(values)
+
+	return ProjectionBand{
+		Month:       month,
+		Date:        date,
+		Contributed: contributed.Round(2),
+		P10:         decimal.NewFromFloat(quantile(values, 0.10)).Round(2),
+		P25:         decimal.NewFromFloat(quantile(values, 0.25)).Round(2),
+		P50:         decimal.NewFromFloat(quantile(values, 0.50)).Round(2),
+		P75:         decimal.NewFromFloat(quantile(values, 0.75)).Round(2),
+		P90:         decimal.NewFromFloat(quantile(values, 0.90)).Round(2),
+	}
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxPushDevicesPerUser is how many devices a user can register for push notifications
+const MaxPushDevicesPerUser = 20
+
+// PushPlatform is the service a device receives push notifications through
+type PushPlatform string
+
+const (
+	// PushPlatformWeb is a browser subscribed through the Push API, which is sent Web Push
+	// messages signed with the server's VAPID key
+	PushPlatformWeb PushPlatform = "WEB_PUSH"
+	// PushPlatformAPNs is an iOS device registered with Apple Push Notification service
+	PushPlatformAPNs PushPlatform = "APNS"
+)
+
+// IsValid returns true if the push platform is recognized
+func (p PushPlatform) IsValid() bool {
+	return p == PushPlatformWeb || p == PushPlatformAPNs
+}
+
+// PushDevice is a browser or phone a user registered to receive their notifications. For
+// Web Push, Endpoint is the push service URL of the browser's subscription and P256dh and
+// Auth are its encryption keys; for APNs, Endpoint is the hex device token. Devices are kept
+// in the public schema, next to their users.
+type PushDevice struct {
+	ID         uuid.UUID    `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID    `gorm:"type:uuid;not null;index" json:"user_id"`
+	Platform   PushPlatform `gorm:"type:varchar(20);not null" json:"platform"`
+	Endpoint   string       `gorm:"type:text;not null;uniqueIndex:idx_push_devices_endpoint" json:"endpoint"`
+	P256dh     string       `gorm:"type:varchar(128)" json:"-"`
+	Auth       string       `gorm:"type:varchar(64)" json:"-"`
+	Name       string       `gorm:"type:varchar(100)" json:"name,omitempty"`
+	CreatedAt  time.Time    `json:"created_at"`
+	LastUsedAt *time.Time   `json:"last_used_at,omitempty"`
+}
+
+// TableName specifies the table name for the PushDevice model
+func (PushDevice) TableName() string {
+	return "push_devices"
+}
+
+// BeforeCreate hook to generate UUID before creating a new device
+func (d *PushDevice) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// Validate checks that the device can be sent notifications on its platform
+func (d *PushDevice) Validate() error {
+	switch d.Platform {
+	case PushPlatformWeb:
+		endpoint, err := url.Parse(d.Endpoint)
+		if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+			return ErrInvalidPushDevice
+		}
+		// The browser's P-256 public key and 16-byte authentication secret
+		if key, err := base64.RawURLEncoding.DecodeString(d.P256dh); err != nil || len(key) != 65 {
+			return ErrInvalidPushDevice
+		}
+		if secret, err := base64.RawURLEncoding.DecodeString(d.Auth); err != nil || len(secret) != 16 {
+			return ErrInvalidPushDevice
+		}
+	case PushPlatformAPNs:
+		if token, err := hex.DecodeString(d.Endpoint); err != nil || len(token) == 0 || len(token) > 100 {
+			return ErrInvalidPushDevice
+		}
+	default:
+		return ErrInvalidPushDevice
+	}
+	return nil
+}
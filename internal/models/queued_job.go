@@ -0,0 +1,168 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QueuedJobType is the kind of operation a queued job runs
+type QueuedJobType string
+
+const (
+	// QueuedJobTypeCSVImport imports a CSV file of transactions into a portfolio
+	QueuedJobTypeCSVImport QueuedJobType = "CSV_IMPORT"
+	// QueuedJobTypeTrackerImport imports another portfolio tracker's export into new portfolios
+	QueuedJobTypeTrackerImport QueuedJobType = "TRACKER_IMPORT"
+	// QueuedJobTypeRecalculation rebuilds a portfolio's holdings and tax lots from its ledger
+	QueuedJobTypeRecalculation QueuedJobType = "RECALCULATION"
+	// QueuedJobTypeTaxReport generates a portfolio's realized gains report for a tax year
+	QueuedJobTypeTaxReport QueuedJobType = "TAX_REPORT"
+	// QueuedJobTypeSimulation runs a stored Monte Carlo withdrawal simulation
+	QueuedJobTypeSimulation QueuedJobType = "SIMULATION"
+	// QueuedJobTypeSnapshotBackfill fills in a portfolio's missing daily performance snapshots
+	QueuedJobTypeSnapshotBackfill QueuedJobType = "SNAPSHOT_BACKFILL"
+)
+
+// IsValid returns true if the job type is recognized
+func (t QueuedJobType) IsValid() bool {
+	switch t {
+	case QueuedJobTypeCSVImport, QueuedJobTypeTrackerImport, QueuedJobTypeRecalculation, QueuedJobTypeTaxReport,
+		QueuedJobTypeSimulation, QueuedJobTypeSnapshotBackfill:
+		return true
+	}
+	return false
+}
+
+// QueuedJobStatus is where a queued job is in its lifecycle
+type QueuedJobStatus string
+
+const (
+	// QueuedJobStatusQueued means the job is waiting for a worker
+	QueuedJobStatusQueued QueuedJobStatus = "QUEUED"
+	// QueuedJobStatusRunning means a worker is running the job
+	QueuedJobStatusRunning QueuedJobStatus = "RUNNING"
+	// QueuedJobStatusSucceeded means the job ran to completion and its result is stored
+	QueuedJobStatusSucceeded QueuedJobStatus = "SUCCEEDED"
+	// QueuedJobStatusFailed means the job stopped with an error
+	QueuedJobStatusFailed QueuedJobStatus = "FAILED"
+)
+
+// IsFinished returns true if the job will not change any more
+func (s QueuedJobStatus) IsFinished() bool {
+	return s == QueuedJobStatusSucceeded || s == QueuedJobStatusFailed
+}
+
+// QueuedJob is a heavy operation requested over the API and run in the background by the
+// worker pool. The request is stored as JSON in Payload; the worker stores the latest
+// progress and, once finished, the result or error. Jobs are kept in the public schema and
+// TenantSchema records the schema of the organization whose data the job works on,
+// OrganizationID the organization the request acted in, if any, and DelegationID the
+// delegation an advisor requested it under, if any.
+type QueuedJob struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID         uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
+	PortfolioID    *uuid.UUID      `gorm:"type:uuid" json:"portfolio_id,omitempty"`
+	TenantSchema   string          `gorm:"type:varchar(63);not null;default:''" json:"-"`
+	OrganizationID *uuid.UUID      `gorm:"type:uuid" json:"-"`
+	DelegationID   *uuid.UUID      `gorm:"type:uuid" json:"-"`
+	Type           QueuedJobType   `gorm:"type:varchar(30);not null" json:"type" validate:"required"`
+	Status         QueuedJobStatus `gorm:"type:varchar(20);not null;default:'QUEUED'" json:"status"`
+	Payload        string          `gorm:"type:text;not null" json:"-"`
+	Progress       string          `gorm:"type:text" json:"-"`
+	Result         string          `gorm:"type:text" json:"-"`
+	Error          string          `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	StartedAt      *time.Time      `json:"started_at,omitempty"`
+	HeartbeatAt    *time.Time      `json:"heartbeat_at,omitempty"`
+	FinishedAt     *time.Time      `json:"finished_at,omitempty"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// TableName specifies the table name for the QueuedJob model
+func (QueuedJob) TableName() string {
+	return "queued_jobs"
+}
+
+// BeforeCreate hook to generate UUID before creating a new job
+func (j *QueuedJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	if j.Status == "" {
+		j.Status = QueuedJobStatusQueued
+	}
+	if j.Payload == "" {
+		j.Payload = "{}"
+	}
+	now := time.Now().UTC()
+	if j.CreatedAt.IsZero() {
+		j.CreatedAt = now
+	}
+	if j.UpdatedAt.IsZero() {
+		j.UpdatedAt = now
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (j *QueuedJob) BeforeUpdate(tx *gorm.DB) error {
+	j.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the job has valid data
+func (j *QueuedJob) Validate() error {
+	if j.UserID == uuid.Nil {
+		return ErrInvalidValue
+	}
+	if !j.Type.IsValid() {
+		return fmt.Errorf("%w: unknown job type %q", ErrInvalidValue, j.Type)
+	}
+	return nil
+}
+
+// SetPayload stores the job's request as JSON
+func (j *QueuedJob) SetPayload(payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	j.Payload = string(data)
+	return nil
+}
+
+// DecodePayload reads the job's request into v
+func (j *QueuedJob) DecodePayload(v any) error {
+	if err := json.Unmarshal([]byte(j.Payload), v); err != nil {
+		return fmt.Errorf("failed to decode job payload: %w", err)
+	}
+	return nil
+}
+
+// DecodeProgress reads the job's latest progress into v. It returns false if the job hasn't
+// reported any progress.
+func (j *QueuedJob) DecodeProgress(v any) (bool, error) {
+	if j.Progress == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(j.Progress), v); err != nil {
+		return false, fmt.Errorf("failed to decode job progress: %w", err)
+	}
+	return true, nil
+}
+
+// DecodeResult reads the result of a succeeded job into v. It returns false if the job has
+// no result.
+func (j *QueuedJob) DecodeResult(v any) (bool, error) {
+	if j.Result == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(j.Result), v); err != nil {
+		return false, fmt.Errorf("failed to decode job result: %w", err)
+	}
+	return true, nil
+}
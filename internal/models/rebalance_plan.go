@@ -0,0 +1,303 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// DefaultRebalancePlanStaleAfterDays is how long an active plan can go without an
+// executed trade before it is considered stale
+const DefaultRebalancePlanStaleAfterDays = 7
+
+// RebalancePlanStatus represents the lifecycle state of a rebalancing execution plan
+type RebalancePlanStatus string
+
+const (
+	RebalancePlanStatusActive    RebalancePlanStatus = "ACTIVE"
+	RebalancePlanStatusCompleted RebalancePlanStatus = "COMPLETED"
+	RebalancePlanStatusCancelled RebalancePlanStatus = "CANCELLED"
+)
+
+// RebalanceTradeStatus represents the execution state of a planned trade
+type RebalanceTradeStatus string
+
+const (
+	RebalanceTradeStatusPending  RebalanceTradeStatus = "PENDING"
+	RebalanceTradeStatusExecuted RebalanceTradeStatus = "EXECUTED"
+	RebalanceTradeStatusSkipped  RebalanceTradeStatus = "SKIPPED"
+)
+
+// RebalancePlan is a saved set of rebalancing trades that the user executes over time
+type RebalancePlan struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID    uuid.UUID             `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
+	Name           string                `gorm:"type:varchar(255);not null" json:"name" validate:"required"`
+	Notes          string                `gorm:"type:text" json:"notes,omitempty"`
+	Status         RebalancePlanStatus   `gorm:"type:varchar(20);not null;default:'ACTIVE';index" json:"status"`
+	StaleAfterDays int                   `gorm:"not null;default:7" json:"stale_after_days"`
+	LastReminderAt *time.Time            `json:"last_reminder_at,omitempty"`
+	ClosedAt       *time.Time            `json:"closed_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+	Trades         []*RebalancePlanTrade `gorm:"foreignKey:PlanID" json:"trades,omitempty"`
+	Portfolio      *Portfolio            `gorm:"foreignKey:PortfolioID" json:"portfolio,omitempty"`
+}
+
+// TableName specifies the table name for the RebalancePlan model
+func (RebalancePlan) TableName() string {
+	return "rebalance_plans"
+}
+
+// BeforeCreate hook to generate UUID before creating a new plan
+func (p *RebalancePlan) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	if p.CreatedAt.IsZero() {
+		p.CreatedAt = time.Now().UTC()
+	}
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = time.Now().UTC()
+	}
+	if p.Status == "" {
+		p.Status = RebalancePlanStatusActive
+	}
+	if p.StaleAfterDays == 0 {
+		p.StaleAfterDays = DefaultRebalancePlanStaleAfterDays
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (p *RebalancePlan) BeforeUpdate(tx *gorm.DB) error {
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the plan and its trades have valid data
+func (p *RebalancePlan) Validate() error {
+	if p.Name == "" {
+		return ErrRebalancePlanNameRequired
+	}
+	if p.StaleAfterDays < 0 {
+		return ErrInvalidValue
+	}
+	if len(p.Trades) == 0 {
+		return ErrRebalancePlanNoTrades
+	}
+	for _, trade := range p.Trades {
+		if err := trade.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsActive returns true if the plan still has trades to work through
+func (p *RebalancePlan) IsActive() bool {
+	return p.Status == RebalancePlanStatusActive
+}
+
+// PendingTrades returns the number of trades that are neither executed nor skipped
+func (p *RebalancePlan) PendingTrades() int {
+	pending := 0
+	for _, trade := range p.Trades {
+		if trade.Status == RebalanceTradeStatusPending {
+			pending++
+		}
+	}
+	return pending
+}
+
+// LastActivityAt returns when the plan was created or a trade was last executed,
+// whichever is later
+func (p *RebalancePlan) LastActivityAt() time.Time {
+	last := p.CreatedAt
+	for _, trade := range p.Trades {
+		if trade.ExecutedAt != nil && trade.ExecutedAt.After(last) {
+			last = *trade.ExecutedAt
+		}
+	}
+	return last
+}
+
+// IsStale returns true if an active plan has pending trades and no activity for
+// StaleAfterDays. A plan with StaleAfterDays of 0 never goes stale.
+func (p *RebalancePlan) IsStale(now time.Time) bool {
+	if !p.IsActive() || p.StaleAfterDays <= 0 || p.PendingTrades() == 0 {
+		return false
+	}
+	return now.Sub(p.LastActivityAt()) >= time.Duration(p.StaleAfterDays)*24*time.Hour
+}
+
+// ReminderDue returns true if the plan is stale and the user hasn't been reminded
+// within the last StaleAfterDays
+func (p *RebalancePlan) ReminderDue(now time.Time) bool {
+	if !p.IsStale(now) {
+		return false
+	}
+	if p.LastReminderAt == nil {
+		return true
+	}
+	return now.Sub(*p.LastReminderAt) >= time.Duration(p.StaleAfterDays)*24*time.Hour
+}
+
+// RebalancePlanDrift summarizes how a plan's execution differs from what was planned
+type RebalancePlanDrift struct {
+	ExecutedTrades int             `json:"executed_trades"`
+	SkippedTrades  int             `json:"skipped_trades"`
+	PendingTrades  int             `json:"pending_trades"`
+	PlannedBuys    decimal.Decimal `json:"planned_buys"`
+	PlannedSells   decimal.Decimal `json:"planned_sells"`
+	ExecutedBuys   decimal.Decimal `json:"executed_buys"`
+	ExecutedSells  decimal.Decimal `json:"executed_sells"`
+	// AmountDrift is the total absolute difference between executed and planned amounts
+	// over executed trades, plus the planned amount of skipped trades
+	AmountDrift decimal.Decimal `json:"amount_drift"`
+}
+
+// Drift calculates the plan's execution drift. Amounts exclude commissions.
+func (p *RebalancePlan) Drift() RebalancePlanDrift {
+	drift := RebalancePlanDrift{
+		PlannedBuys:   decimal.Zero,
+		PlannedSells:  decimal.Zero,
+		ExecutedBuys:  decimal.Zero,
+		ExecutedSells: decimal.Zero,
+		AmountDrift:   decimal.Zero,
+	}
+
+	for _, trade := range p.Trades {
+		planned := trade.PlannedAmount()
+		if trade.Side == TransactionTypeBuy {
+			drift.PlannedBuys = drift.PlannedBuys.Add(planned)
+		} else {
+			drift.PlannedSells = drift.PlannedSells.Add(planned)
+		}
+
+		switch trade.Status {
+		case RebalanceTradeStatusExecuted:
+			drift.ExecutedTrades++
+			executed := trade.ExecutedAmount()
+			if trade.Side == TransactionTypeBuy {
+				drift.ExecutedBuys = drift.ExecutedBuys.Add(executed)
+			} else {
+				drift.ExecutedSells = drift.ExecutedSells.Add(executed)
+			}
+			drift.AmountDrift = drift.AmountDrift.Add(trade.AmountDrift().Abs())
+		case RebalanceTradeStatusSkipped:
+			drift.SkippedTrades++
+			drift.AmountDrift = drift.AmountDrift.Add(planned)
+		default:
+			drift.PendingTrades++
+		}
+	}
+
+	return drift
+}
+
+// RebalancePlanTrade is a single buy or sell in a rebalancing execution plan
+type RebalancePlanTrade struct {
+	ID               uuid.UUID            `gorm:"type:uuid;primaryKey" json:"id"`
+	PlanID           uuid.UUID            `gorm:"type:uuid;not null;index" json:"plan_id"`
+	Symbol           string               `gorm:"type:varchar(20);not null" json:"symbol" validate:"required"`
+	Side             TransactionType      `gorm:"type:varchar(10);not null" json:"side" validate:"required"`
+	PlannedQuantity  decimal.Decimal      `gorm:"type:numeric(20,8);not null" json:"planned_quantity" validate:"required"`
+	PlannedPrice     decimal.Decimal      `gorm:"type:numeric(20,8);not null" json:"planned_price" validate:"required"`
+	Status           RebalanceTradeStatus `gorm:"type:varchar(20);not null;default:'PENDING'" json:"status"`
+	ExecutedQuantity *decimal.Decimal     `gorm:"type:numeric(20,8)" json:"executed_quantity,omitempty"`
+	ExecutedPrice    *decimal.Decimal     `gorm:"type:numeric(20,8)" json:"executed_price,omitempty"`
+	ExecutedAt       *time.Time           `json:"executed_at,omitempty"`
+	TransactionID    *uuid.UUID           `gorm:"type:uuid" json:"transaction_id,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
+// TableName specifies the table name for the RebalancePlanTrade model
+func (RebalancePlanTrade) TableName() string {
+	return "rebalance_plan_trades"
+}
+
+// BeforeCreate hook to generate UUID before creating a new trade
+func (t *RebalancePlanTrade) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	if t.UpdatedAt.IsZero() {
+		t.UpdatedAt = time.Now().UTC()
+	}
+	if t.Status == "" {
+		t.Status = RebalanceTradeStatusPending
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (t *RebalancePlanTrade) BeforeUpdate(tx *gorm.DB) error {
+	t.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the trade has valid data
+func (t *RebalancePlanTrade) Validate() error {
+	if t.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if t.Side != TransactionTypeBuy && t.Side != TransactionTypeSell {
+		return ErrInvalidTransactionType
+	}
+	if t.PlannedQuantity.LessThanOrEqual(decimal.Zero) {
+		return ErrInvalidQuantity
+	}
+	if t.PlannedPrice.LessThanOrEqual(decimal.Zero) {
+		return ErrInvalidPrice
+	}
+	return nil
+}
+
+// IsPending returns true if the trade hasn't been executed or skipped
+func (t *RebalancePlanTrade) IsPending() bool {
+	return t.Status == RebalanceTradeStatusPending
+}
+
+// PlannedAmount returns the planned trade value
+func (t *RebalancePlanTrade) PlannedAmount() decimal.Decimal {
+	return t.PlannedQuantity.Mul(t.PlannedPrice)
+}
+
+// ExecutedAmount returns the value of the actual fill, or zero if not executed
+func (t *RebalancePlanTrade) ExecutedAmount() decimal.Decimal {
+	if t.ExecutedQuantity == nil || t.ExecutedPrice == nil {
+		return decimal.Zero
+	}
+	return t.ExecutedQuantity.Mul(*t.ExecutedPrice)
+}
+
+// QuantityDrift returns executed minus planned quantity, or zero if not executed
+func (t *RebalancePlanTrade) QuantityDrift() decimal.Decimal {
+	if t.ExecutedQuantity == nil {
+		return decimal.Zero
+	}
+	return t.ExecutedQuantity.Sub(t.PlannedQuantity)
+}
+
+// PriceDrift returns executed minus planned price, or zero if not executed
+func (t *RebalancePlanTrade) PriceDrift() decimal.Decimal {
+	if t.ExecutedPrice == nil {
+		return decimal.Zero
+	}
+	return t.ExecutedPrice.Sub(t.PlannedPrice)
+}
+
+// AmountDrift returns executed minus planned trade value, or zero if not executed
+func (t *RebalancePlanTrade) AmountDrift() decimal.Decimal {
+	if t.Status != RebalanceTradeStatusExecuted {
+		return decimal.Zero
+	}
+	return t.ExecutedAmount().Sub(t.PlannedAmount())
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRebalanceTrade(symbol string, side TransactionType, quantity, price int64) *RebalancePlanTrade {
+	return &RebalancePlanTrade{
+		Symbol:          symbol,
+		Side:            side,
+		PlannedQuantity: decimal.NewFromInt(quantity),
+		PlannedPrice:    decimal.NewFromInt(price),
+		Status:          RebalanceTradeStatusPending,
+	}
+}
+
+func executeTestRebalanceTrade(trade *RebalancePlanTrade, quantity, price int64, at time.Time) {
+	q := decimal.NewFromInt(quantity)
+	p := decimal.NewFromInt(price)
+	trade.Status = RebalanceTradeStatusExecuted
+	trade.ExecutedQuantity = &q
+	trade.ExecutedPrice = &p
+	trade.ExecutedAt = &at
+}
+
+func TestRebalancePlan_TableNames(t *testing.T) {
+	assert.Equal(t, "rebalance_plans", RebalancePlan{}.TableName())
+	assert.Equal(t, "rebalance_plan_trades", RebalancePlanTrade{}.TableName())
+}
+
+func TestRebalancePlan_Validate(t *testing.T) {
+	valid := func() *RebalancePlan {
+		return &RebalancePlan{
+			Name:   "Q3 rebalance",
+			Trades: []*RebalancePlanTrade{newTestRebalanceTrade("VTI", TransactionTypeBuy, 10, 250)},
+		}
+	}
+
+	assert.NoError(t, valid().Validate())
+
+	plan := valid()
+	plan.Name = ""
+	assert.Equal(t, ErrRebalancePlanNameRequired, plan.Validate())
+
+	plan = valid()
+	plan.Trades = nil
+	assert.Equal(t, ErrRebalancePlanNoTrades, plan.Validate())
+
+	plan = valid()
+	plan.Trades[0].Side = TransactionTypeDividend
+	assert.Equal(t, ErrInvalidTransactionType, plan.Validate())
+
+	plan = valid()
+	plan.Trades[0].PlannedQuantity = decimal.Zero
+	assert.Equal(t, ErrInvalidQuantity, plan.Validate())
+
+	plan = valid()
+	plan.Trades[0].PlannedPrice = decimal.NewFromInt(-1)
+	assert.Equal(t, ErrInvalidPrice, plan.Validate())
+}
+
+func TestRebalancePlan_IsStale(t *testing.T) {
+	created := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	plan := &RebalancePlan{
+		Status:         RebalancePlanStatusActive,
+		StaleAfterDays: 7,
+		CreatedAt:      created,
+		Trades: []*RebalancePlanTrade{
+			newTestRebalanceTrade("VTI", TransactionTypeBuy, 10, 250),
+			newTestRebalanceTrade("BND", TransactionTypeSell, 20, 75),
+		},
+	}
+
+	assert.False(t, plan.IsStale(created.AddDate(0, 0, 6)))
+	assert.True(t, plan.IsStale(created.AddDate(0, 0, 7)))
+
+	// Executing a trade resets the clock
+	executeTestRebalanceTrade(plan.Trades[0], 10, 251, created.AddDate(0, 0, 5))
+	assert.Equal(t, created.AddDate(0, 0, 5), plan.LastActivityAt())
+	assert.False(t, plan.IsStale(created.AddDate(0, 0, 11)))
+	assert.True(t, plan.IsStale(created.AddDate(0, 0, 12)))
+
+	// No pending trades means nothing to remind about
+	executeTestRebalanceTrade(plan.Trades[1], 20, 74, created.AddDate(0, 0, 5))
+	assert.False(t, plan.IsStale(created.AddDate(0, 0, 30)))
+
+	cancelled := &RebalancePlan{
+		Status:         RebalancePlanStatusCancelled,
+		StaleAfterDays: 7,
+		CreatedAt:      created,
+		Trades:         []*RebalancePlanTrade{newTestRebalanceTrade("VTI", TransactionTypeBuy, 10, 250)},
+	}
+	assert.False(t, cancelled.IsStale(created.AddDate(0, 0, 30)))
+}
+
+func TestRebalancePlan_ReminderDue(t *testing.T) {
+	created := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	plan := &RebalancePlan{
+		Status:         RebalancePlanStatusActive,
+		StaleAfterDays: 7,
+		CreatedAt:      created,
+		Trades:         []*RebalancePlanTrade{newTestRebalanceTrade("VTI", TransactionTypeBuy, 10, 250)},
+	}
+
+	now := created.AddDate(0, 0, 8)
+	assert.True(t, plan.ReminderDue(now))
+
+	plan.LastReminderAt = &now
+	assert.False(t, plan.ReminderDue(now.AddDate(0, 0, 6)))
+	assert.True(t, plan.ReminderDue(now.AddDate(0, 0, 7)))
+}
+
+func TestRebalancePlan_Drift(t *testing.T) {
+	now := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	buy := newTestRebalanceTrade("VTI", TransactionTypeBuy, 10, 250)
+	sell := newTestRebalanceTrade("BND", TransactionTypeSell, 20, 75)
+	skipped := newTestRebalanceTrade("VXUS", TransactionTypeBuy, 5, 60)
+	pending := newTestRebalanceTrade("GLD", TransactionTypeSell, 2, 200)
+
+	executeTestRebalanceTrade(buy, 10, 252, now)
+	executeTestRebalanceTrade(sell, 18, 75, now)
+	skipped.Status = RebalanceTradeStatusSkipped
+
+	plan := &RebalancePlan{Trades: []*RebalancePlanTrade{buy, sell, skipped, pending}}
+	drift := plan.Drift()
+
+	assert.Equal(t, 2, drift.ExecutedTrades)
+	assert.Equal(t, 1, drift.SkippedTrades)
+	assert.Equal(t, 1, drift.PendingTrades)
+	assert.True(t, decimal.NewFromInt(2800).Equal(drift.PlannedBuys))
+	assert.True(t, decimal.NewFromInt(1900).Equal(drift.PlannedSells))
+	assert.True(t, decimal.NewFromInt(2520).Equal(drift.ExecutedBuys))
+	assert.True(t, decimal.NewFromInt(1350).Equal(drift.ExecutedSells))
+	// |2520-2500| + |1350-1500| + 300 skipped
+	assert.True(t, decimal.NewFromInt(470).Equal(drift.AmountDrift))
+
+	assert.True(t, decimal.NewFromInt(-2).Equal(sell.QuantityDrift()))
+	assert.True(t, decimal.NewFromInt(2).Equal(buy.PriceDrift()))
+	assert.True(t, decimal.Zero.Equal(pending.AmountDrift()))
+}
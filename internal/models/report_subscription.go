@@ -0,0 +1,144 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReportFrequency is how often a report subscription's digest is sent
+type ReportFrequency string
+
+const (
+	// ReportFrequencyWeekly sends a digest every Monday covering the previous week
+	ReportFrequencyWeekly ReportFrequency = "WEEKLY"
+	// ReportFrequencyMonthly sends a digest on the first of the month covering the previous month
+	ReportFrequencyMonthly ReportFrequency = "MONTHLY"
+)
+
+// IsValid returns true if the frequency is recognized
+func (f ReportFrequency) IsValid() bool {
+	return f == ReportFrequencyWeekly || f == ReportFrequencyMonthly
+}
+
+// ReportSubscription is a user's sign-up for a performance digest emailed on a schedule
+type ReportSubscription struct {
+	ID         uuid.UUID                      `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID     uuid.UUID                      `gorm:"type:uuid;not null;index" json:"user_id" validate:"required"`
+	Frequency  ReportFrequency                `gorm:"type:varchar(10);not null" json:"frequency" validate:"required"`
+	Enabled    bool                           `gorm:"not null;default:true" json:"enabled"`
+	LastSentAt *time.Time                     `json:"last_sent_at,omitempty"`
+	CreatedAt  time.Time                      `json:"created_at"`
+	UpdatedAt  time.Time                      `json:"updated_at"`
+	Portfolios []*ReportSubscriptionPortfolio `gorm:"foreignKey:SubscriptionID" json:"portfolios,omitempty"`
+}
+
+// TableName specifies the table name for the ReportSubscription model
+func (ReportSubscription) TableName() string {
+	return "report_subscriptions"
+}
+
+// BeforeCreate hook to generate UUID before creating a new subscription
+func (s *ReportSubscription) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now().UTC()
+	}
+	if s.UpdatedAt.IsZero() {
+		s.UpdatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (s *ReportSubscription) BeforeUpdate(tx *gorm.DB) error {
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the subscription has valid data
+func (s *ReportSubscription) Validate() error {
+	if s.UserID == uuid.Nil {
+		return ErrInvalidValue
+	}
+	if !s.Frequency.IsValid() {
+		return ErrInvalidReportFrequency
+	}
+	return nil
+}
+
+// PortfolioIDs returns the IDs of the portfolios the digest covers. None means all of the
+// user's portfolios.
+func (s *ReportSubscription) PortfolioIDs() []string {
+	ids := make([]string, len(s.Portfolios))
+	for i, portfolio := range s.Portfolios {
+		ids[i] = portfolio.PortfolioID.String()
+	}
+	return ids
+}
+
+// PeriodStart returns the start of the period that now falls in: Monday of this week for
+// weekly digests, the first of this month for monthly ones
+func (s *ReportSubscription) PeriodStart(now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if s.Frequency == ReportFrequencyMonthly {
+		return today.AddDate(0, 0, 1-today.Day())
+	}
+	daysSinceMonday := (int(today.Weekday()) + 6) % 7
+	return today.AddDate(0, 0, -daysSinceMonday)
+}
+
+// DigestPeriod returns the last complete period before now, as a half-open range
+func (s *ReportSubscription) DigestPeriod(now time.Time) (start, end time.Time) {
+	end = s.PeriodStart(now)
+	return s.addPeriods(end, -1), end
+}
+
+// addPeriods moves a period start forward by n weeks or months
+func (s *ReportSubscription) addPeriods(start time.Time, n int) time.Time {
+	if s.Frequency == ReportFrequencyMonthly {
+		return start.AddDate(0, n, 0)
+	}
+	return start.AddDate(0, 0, 7*n)
+}
+
+// DigestDue returns true if the subscription is enabled and no digest has been sent since
+// the last period ended. The first digest covers the first complete period after the
+// subscription was created.
+func (s *ReportSubscription) DigestDue(now time.Time) bool {
+	if !s.Enabled {
+		return false
+	}
+	start, end := s.DigestPeriod(now)
+	if s.CreatedAt.After(start) {
+		return false
+	}
+	return s.LastSentAt == nil || s.LastSentAt.Before(end)
+}
+
+// NextDigestAt returns when the next digest becomes due, or nil if the subscription is
+// disabled. A digest that is already due is reported at the end of the period it covers.
+func (s *ReportSubscription) NextDigestAt(now time.Time) *time.Time {
+	if !s.Enabled {
+		return nil
+	}
+	at := s.PeriodStart(now)
+	for !s.DigestDue(at) {
+		at = s.addPeriods(at, 1)
+	}
+	return &at
+}
+
+// ReportSubscriptionPortfolio links a report subscription to a portfolio its digest covers
+type ReportSubscriptionPortfolio struct {
+	SubscriptionID uuid.UUID `gorm:"type:uuid;primaryKey" json:"-"`
+	PortfolioID    uuid.UUID `gorm:"type:uuid;primaryKey;index" json:"portfolio_id"`
+}
+
+// TableName specifies the table name for the ReportSubscriptionPortfolio model
+func (ReportSubscriptionPortfolio) TableName() string {
+	return "report_subscription_portfolios"
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportSubscription_TableNames(t *testing.T) {
+	assert.Equal(t, "report_subscriptions", ReportSubscription{}.TableName())
+	assert.Equal(t, "report_subscription_portfolios", ReportSubscriptionPortfolio{}.TableName())
+}
+
+func TestReportSubscription_Validate(t *testing.T) {
+	subscription := &ReportSubscription{UserID: uuid.New(), Frequency: ReportFrequencyWeekly}
+	assert.NoError(t, subscription.Validate())
+
+	subscription.Frequency = "DAILY"
+	assert.Equal(t, ErrInvalidReportFrequency, subscription.Validate())
+
+	subscription = &ReportSubscription{Frequency: ReportFrequencyMonthly}
+	assert.Equal(t, ErrInvalidValue, subscription.Validate())
+}
+
+func TestReportSubscription_DigestPeriod(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	weekly := &ReportSubscription{Frequency: ReportFrequencyWeekly}
+	start, end := weekly.DigestPeriod(now)
+	assert.Equal(t, time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), end)
+
+	// On a Monday the week that just ended is covered
+	start, _ = weekly.DigestPeriod(time.Date(2025, 1, 13, 0, 5, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), start)
+
+	monthly := &ReportSubscription{Frequency: ReportFrequencyMonthly}
+	start, end = monthly.DigestPeriod(now)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestReportSubscription_DigestDue(t *testing.T) {
+	monday := time.Date(2025, 1, 13, 6, 0, 0, 0, time.UTC)
+
+	subscription := &ReportSubscription{
+		Frequency: ReportFrequencyWeekly,
+		Enabled:   true,
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	assert.True(t, subscription.DigestDue(monday))
+
+	sent := monday
+	subscription.LastSentAt = &sent
+	assert.False(t, subscription.DigestDue(monday.Add(time.Hour)))
+	assert.True(t, subscription.DigestDue(monday.AddDate(0, 0, 7)))
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := *subscription
+		disabled.Enabled = false
+		assert.False(t, disabled.DigestDue(monday.AddDate(0, 0, 7)))
+		assert.Nil(t, disabled.NextDigestAt(monday))
+	})
+
+	t.Run("first digest covers a full period", func(t *testing.T) {
+		// Created on a Wednesday: the following week is the first complete one
+		created := &ReportSubscription{
+			Frequency: ReportFrequencyWeekly,
+			Enabled:   true,
+			CreatedAt: time.Date(2025, 1, 8, 12, 0, 0, 0, time.UTC),
+		}
+		assert.False(t, created.DigestDue(monday))
+		assert.Equal(t, time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), *created.NextDigestAt(monday))
+	})
+
+	t.Run("next digest after one was sent", func(t *testing.T) {
+		assert.Equal(t, time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC), *subscription.NextDigestAt(monday.Add(time.Hour)))
+	})
+}
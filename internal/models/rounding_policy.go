@@ -0,0 +1,118 @@
+package models
+
+import "github.com/shopspring/decimal"
+
+// RoundingMode is how values halfway between two representable values are rounded
+type RoundingMode string
+
+const (
+	// RoundingModeHalfUp rounds halves away from zero
+	RoundingModeHalfUp RoundingMode = "HALF_UP"
+	// RoundingModeHalfEven rounds halves to the nearest even digit (banker's rounding), so
+	// rounding errors don't accumulate in one direction over many operations
+	RoundingModeHalfEven RoundingMode = "HALF_EVEN"
+)
+
+// maxRoundingPlaces is the scale quantities and amounts are stored with
+const maxRoundingPlaces = 8
+
+// optionQuantityPlaces is the precision of option quantities, which are whole contracts
+const optionQuantityPlaces = 0
+
+// RoundingPolicy decides the precision of the quantities and amounts that corporate actions
+// and tax lot allocations derive from existing positions, such as shares produced by a split
+// or the cost basis moved to a spinoff. Quantities are rounded to the places of their asset
+// type, and amounts to AmountPlaces.
+type RoundingPolicy struct {
+	Mode                 RoundingMode
+	EquityQuantityPlaces int32
+	CryptoQuantityPlaces int32
+	AmountPlaces         int32
+}
+
+// DefaultRoundingPolicy rounds half up to the full stored precision of 8 places
+func DefaultRoundingPolicy() RoundingPolicy {
+	return RoundingPolicy{
+		Mode:                 RoundingModeHalfUp,
+		EquityQuantityPlaces: maxRoundingPlaces,
+		CryptoQuantityPlaces: maxRoundingPlaces,
+		AmountPlaces:         maxRoundingPlaces,
+	}
+}
+
+// Validate checks if the policy is usable
+func (p RoundingPolicy) Validate() error {
+	if p.Mode != RoundingModeHalfUp && p.Mode != RoundingModeHalfEven {
+		return ErrInvalidRoundingPolicy
+	}
+	for _, places := range []int32{p.EquityQuantityPlaces, p.CryptoQuantityPlaces, p.AmountPlaces} {
+		if places < 0 || places > maxRoundingPlaces {
+			return ErrInvalidRoundingPolicy
+		}
+	}
+	return nil
+}
+
+// QuantityPlaces returns the decimal places quantities of an asset type are rounded to
+func (p RoundingPolicy) QuantityPlaces(assetType AssetType) int32 {
+	switch assetType {
+	case AssetTypeCrypto:
+		return p.CryptoQuantityPlaces
+	case AssetTypeOption:
+		return optionQuantityPlaces
+	default:
+		return p.EquityQuantityPlaces
+	}
+}
+
+// RoundQuantity rounds a quantity of an asset type
+func (p RoundingPolicy) RoundQuantity(assetType AssetType, value decimal.Decimal) decimal.Decimal {
+	return p.round(value, p.QuantityPlaces(assetType))
+}
+
+// RoundAmount rounds a monetary amount
+func (p RoundingPolicy) RoundAmount(value decimal.Decimal) decimal.Decimal {
+	return p.round(value, p.AmountPlaces)
+}
+
+// AllocateQuantity splits a quantity of an asset type across parts in proportion to weights
+func (p RoundingPolicy) AllocateQuantity(assetType AssetType, total decimal.Decimal, weights []decimal.Decimal) []decimal.Decimal {
+	return p.allocate(total, weights, p.QuantityPlaces(assetType))
+}
+
+// AllocateAmount splits an amount across parts in proportion to weights
+func (p RoundingPolicy) AllocateAmount(total decimal.Decimal, weights []decimal.Decimal) []decimal.Decimal {
+	return p.allocate(total, weights, p.AmountPlaces)
+}
+
+// allocate splits total in proportion to weights, rounding each part to places. The last
+// part takes whatever rounding left over, so the parts always add up to total exactly.
+func (p RoundingPolicy) allocate(total decimal.Decimal, weights []decimal.Decimal, places int32) []decimal.Decimal {
+	parts := make([]decimal.Decimal, len(weights))
+	if len(weights) == 0 {
+		return parts
+	}
+
+	totalWeight := decimal.Zero
+	for _, weight := range weights {
+		totalWeight = totalWeight.Add(weight)
+	}
+
+	allocated := decimal.Zero
+	for i, weight := range weights[:len(weights)-1] {
+		if !totalWeight.IsZero() {
+			parts[i] = p.round(total.Mul(weight).Div(totalWeight), places)
+		}
+		allocated = allocated.Add(parts[i])
+	}
+	parts[len(parts)-1] = total.Sub(allocated)
+	return parts
+}
+
+// round rounds value to places using the policy's mode
+func (p RoundingPolicy) round(value decimal.Decimal, places int32) decimal.Decimal {
+	if p.Mode == RoundingModeHalfEven {
+		return value.RoundBank(places)
+	}
+	return value.Round(places)
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundingPolicy_Validate(t *testing.T) {
+	assert.NoError(t, DefaultRoundingPolicy().Validate())
+	assert.NoError(t, RoundingPolicy{Mode: RoundingModeHalfEven, EquityQuantityPlaces: 0, CryptoQuantityPlaces: 8, AmountPlaces: 2}.Validate())
+	assert.Equal(t, ErrInvalidRoundingPolicy, RoundingPolicy{Mode: "UP", AmountPlaces: 2}.Validate())
+	assert.Equal(t, ErrInvalidRoundingPolicy, RoundingPolicy{Mode: RoundingModeHalfUp, AmountPlaces: 9}.Validate())
+	assert.Equal(t, ErrInvalidRoundingPolicy, RoundingPolicy{Mode: RoundingModeHalfUp, EquityQuantityPlaces: -1}.Validate())
+}
+
+func TestRoundingPolicy_Round(t *testing.T) {
+	halfUp := RoundingPolicy{Mode: RoundingModeHalfUp, EquityQuantityPlaces: 2, CryptoQuantityPlaces: 4, AmountPlaces: 2}
+	halfEven := halfUp
+	halfEven.Mode = RoundingModeHalfEven
+
+	tests := []struct {
+		value        string
+		wantHalfUp   string
+		wantHalfEven string
+	}{
+		{"2.345", "2.35", "2.34"},
+		{"2.355", "2.36", "2.36"},
+		{"-2.345", "-2.35", "-2.34"},
+		{"2.3449", "2.34", "2.34"},
+	}
+
+	for _, tt := range tests {
+		value := decimal.RequireFromString(tt.value)
+		assert.Equal(t, tt.wantHalfUp, halfUp.RoundAmount(value).StringFixed(2), tt.value)
+		assert.Equal(t, tt.wantHalfEven, halfEven.RoundAmount(value).StringFixed(2), tt.value)
+	}
+
+	quantity := decimal.RequireFromString("1.23456")
+	assert.Equal(t, "1.23", halfUp.RoundQuantity(AssetTypeEquity, quantity).String())
+	assert.Equal(t, "1.2346", halfUp.RoundQuantity(AssetTypeCrypto, quantity).String())
+	assert.Equal(t, "1", halfUp.RoundQuantity(AssetTypeOption, quantity).String())
+}
+
+func TestRoundingPolicy_Allocate(t *testing.T) {
+	policy := RoundingPolicy{Mode: RoundingModeHalfUp, EquityQuantityPlaces: 0, CryptoQuantityPlaces: 8, AmountPlaces: 2}
+	thirds := []decimal.Decimal{decimal.NewFromInt(1), decimal.NewFromInt(1), decimal.NewFromInt(1)}
+
+	amounts := policy.AllocateAmount(decimal.NewFromInt(100), thirds)
+	assert.Equal(t, []string{"33.33", "33.33", "33.34"}, []string{amounts[0].String(), amounts[1].String(), amounts[2].String()})
+
+	quantities := policy.AllocateQuantity(AssetTypeEquity, decimal.NewFromInt(5), []decimal.Decimal{decimal.NewFromInt(3), decimal.NewFromInt(1)})
+	assert.Equal(t, "4", quantities[0].String())
+	assert.Equal(t, "1", quantities[1].String())
+
+	assert.Empty(t, policy.AllocateAmount(decimal.NewFromInt(100), nil))
+	zero := policy.AllocateAmount(decimal.NewFromInt(10), []decimal.Decimal{decimal.Zero, decimal.Zero})
+	assert.True(t, zero[0].IsZero())
+	assert.True(t, zero[1].Equal(decimal.NewFromInt(10)))
+}
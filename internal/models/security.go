@@ -0,0 +1,98 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSecuritySearchResults is how many matches a symbol search returns when no limit
+	// is requested
+	DefaultSecuritySearchResults = 10
+	// MaxSecuritySearchResults bounds the matches a symbol search returns
+	MaxSecuritySearchResults = 25
+	// MaxSecurityQueryLength bounds the length of a symbol search query
+	MaxSecurityQueryLength = 50
+	// MaxSymbolSuggestions bounds the symbols suggested in place of an unknown one
+	MaxSymbolSuggestions = 5
+)
+
+// usExchangeCodes are the exchange qualifiers stripped from ticker symbols, as in AAPL.US,
+// AAPL:US or NASDAQ:AAPL. Qualifiers of other exchanges are kept, since the provider lists
+// those listings under their own symbols.
+var usExchangeCodes = map[string]bool{
+	"US":       true,
+	"NYSE":     true,
+	"NASDAQ":   true,
+	"ARCA":     true,
+	"NYSEARCA": true,
+	"AMEX":     true,
+	"BATS":     true,
+}
+
+// Security is a tradable instrument found through the market data provider's symbol search.
+// Securities are reference data shared by every user, stored so they can be searched and
+// looked up again without spending provider requests.
+type Security struct {
+	Symbol    string    `gorm:"type:varchar(40);primaryKey" json:"symbol"`
+	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
+	Exchange  string    `gorm:"type:varchar(100)" json:"exchange"`
+	Type      string    `gorm:"type:varchar(50)" json:"type"`
+	Currency  string    `gorm:"type:varchar(10)" json:"currency"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the Security model
+func (Security) TableName() string {
+	return "securities"
+}
+
+// NormalizeSymbol trims and upper-cases a ticker symbol
+func NormalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// NormalizeTicker trims and upper-cases a ticker symbol and strips a US exchange qualifier
+// from it, so AAPL, aapl.us and NASDAQ:AAPL are all recorded as AAPL
+func NormalizeTicker(symbol string) string {
+	symbol = NormalizeSymbol(symbol)
+	if exchange, ticker, ok := strings.Cut(symbol, ":"); ok && usExchangeCodes[exchange] {
+		return ticker
+	}
+	if i := strings.LastIndexAny(symbol, ".:"); i > 0 && usExchangeCodes[symbol[i+1:]] {
+		return symbol[:i]
+	}
+	return symbol
+}
+
+// InvalidSymbolError is returned when a transaction is recorded for a symbol the provider
+// doesn't list, with the listed symbols closest to it
+type InvalidSymbolError struct {
+	Symbol      string
+	Suggestions []string
+}
+
+// Error implements the error interface
+func (e *InvalidSymbolError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("%s %s", ErrUnknownSymbol, e.Symbol)
+	}
+	return fmt.Sprintf("%s %s; did you mean %s?", ErrUnknownSymbol, e.Symbol, strings.Join(e.Suggestions, ", "))
+}
+
+// Unwrap lets errors.Is match ErrUnknownSymbol
+func (e *InvalidSymbolError) Unwrap() error {
+	return ErrUnknownSymbol
+}
+
+// NormalizeSecurityQuery trims a symbol search query, returning an error if it is empty or
+// too long
+func NormalizeSecurityQuery(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || len(query) > MaxSecurityQueryLength {
+		return "", ErrInvalidSecurityQuery
+	}
+	return query, nil
+}
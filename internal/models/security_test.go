@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTicker(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   string
+	}{
+		{"AAPL", "AAPL"},
+		{" aapl ", "AAPL"},
+		{"aapl.us", "AAPL"},
+		{"AAPL:US", "AAPL"},
+		{"NASDAQ:AAPL", "AAPL"},
+		{"spy.arca", "SPY"},
+		{"BRK.B", "BRK.B"},
+		{"BRK.B.US", "BRK.B"},
+		{"TSCO.LON", "TSCO.LON"},
+		{"btc-usd", "BTC-USD"},
+		{"AAPL240621C00190000", "AAPL240621C00190000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeTicker(tt.symbol))
+		})
+	}
+}
+
+func TestInvalidSymbolError(t *testing.T) {
+	err := &InvalidSymbolError{Symbol: "APPL", Suggestions: []string{"AAPL", "APP"}}
+	assert.Equal(t, "unknown symbol APPL; did you mean AAPL, APP?", err.Error())
+	assert.True(t, errors.Is(err, ErrUnknownSymbol))
+
+	err = &InvalidSymbolError{Symbol: "ZZZZ"}
+	assert.Equal(t, "unknown symbol ZZZZ", err.Error())
+}
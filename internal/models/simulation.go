@@ -0,0 +1,127 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	// DefaultSimulationPaths is the number of randomized paths a simulation runs when none is
+	// requested
+	DefaultSimulationPaths = 1000
+	// MaxSimulationPaths bounds the work a single simulation does
+	MaxSimulationPaths = 10000
+	// MaxSimulationYears is the longest retirement a simulation covers
+	MaxSimulationYears = 60
+)
+
+// Simulation is a stored Monte Carlo simulation of withdrawals from a portfolio. It is run in
+// the background by a queued job, and Status follows that job. The resolved parameters and,
+// once the job has succeeded, the result are stored as JSON.
+type Simulation struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID uuid.UUID       `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
+	JobID       *uuid.UUID      `gorm:"type:uuid" json:"job_id,omitempty"`
+	Status      QueuedJobStatus `gorm:"type:varchar(20);not null;default:'QUEUED'" json:"status"`
+	Parameters  string          `gorm:"type:text;not null" json:"-"`
+	Result      string          `gorm:"type:text" json:"-"`
+	Error       string          `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+}
+
+// TableName specifies the table name for the Simulation model
+func (Simulation) TableName() string {
+	return "simulations"
+}
+
+// BeforeCreate hook to generate UUID before creating a new simulation
+func (s *Simulation) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.Status == "" {
+		s.Status = QueuedJobStatusQueued
+	}
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// SetParameters stores the simulation's parameters as JSON
+func (s *Simulation) SetParameters(parameters any) error {
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return fmt.Errorf("failed to encode simulation parameters: %w", err)
+	}
+	s.Parameters = string(data)
+	return nil
+}
+
+// DecodeParameters reads the simulation's parameters into v
+func (s *Simulation) DecodeParameters(v any) error {
+	if err := json.Unmarshal([]byte(s.Parameters), v); err != nil {
+		return fmt.Errorf("failed to decode simulation parameters: %w", err)
+	}
+	return nil
+}
+
+// SetResult stores the simulation's result as JSON
+func (s *Simulation) SetResult(result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode simulation result: %w", err)
+	}
+	s.Result = string(data)
+	return nil
+}
+
+// DecodeResult reads the result of a succeeded simulation into v. It returns false if the
+// simulation has no result yet.
+func (s *Simulation) DecodeResult(v any) (bool, error) {
+	if s.Result == "" {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(s.Result), v); err != nil {
+		return false, fmt.Errorf("failed to decode simulation result: %w", err)
+	}
+	return true, nil
+}
+
+// SimulationYear is the state of a simulation's paths at the end of one year of withdrawals.
+// SurvivingPct is the percentage of paths that haven't run out of money; the percentiles are
+// of the values of all paths, depleted ones counting as zero.
+type SimulationYear struct {
+	Year         int             `json:"year"`
+	Withdrawal   decimal.Decimal `json:"withdrawal"`
+	SurvivingPct decimal.Decimal `json:"surviving_pct"`
+	P10          decimal.Decimal `json:"p10"`
+	P25          decimal.Decimal `json:"p25"`
+	P50          decimal.Decimal `json:"p50"`
+	P75          decimal.Decimal `json:"p75"`
+	P90          decimal.Decimal `json:"p90"`
+}
+
+// NewSimulationYear summarizes the values of the paths at the end of a year. values is sorted
+// in place.
+func NewSimulationYear(year int, withdrawal decimal.Decimal, surviving int, values []float64) SimulationYear {
+	sort.Float64s(values)
+
+	return SimulationYear{
+		Year:         year,
+		Withdrawal:   withdrawal.Round(2),
+		SurvivingPct: decimal.NewFromInt(int64(surviving)).Div(decimal.NewFromInt(int64(len(values)))).Mul(decimal.NewFromInt(100)).Round(2),
+		P10:          decimal.NewFromFloat(quantile(values, 0.10)).Round(2),
+		P25:          decimal.NewFromFloat(quantile(values, 0.25)).Round(2),
+		P50:          decimal.NewFromFloat(quantile(values, 0.50)).Round(2),
+		P75:          decimal.NewFromFloat(quantile(values, 0.75)).Round(2),
+		P90:          decimal.NewFromFloat(quantile(values, 0.90)).Round(2),
+	}
+}
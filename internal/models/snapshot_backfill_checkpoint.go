@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SnapshotBackfillCheckpoint records how far a portfolio's snapshot backfill has got. The
+// backfill started at StartDate and has stored a snapshot for every trading day up to and
+// including LastCompletedDate, so an interrupted backfill resumes on the next trading day.
+type SnapshotBackfillCheckpoint struct {
+	PortfolioID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"portfolio_id"`
+	StartDate         time.Time `gorm:"not null" json:"start_date"`
+	LastCompletedDate time.Time `gorm:"not null" json:"last_completed_date"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the SnapshotBackfillCheckpoint model
+func (SnapshotBackfillCheckpoint) TableName() string {
+	return "snapshot_backfill_checkpoints"
+}
+
+// Covers returns true if the backfill the checkpoint records started on or before start and
+// has got at least as far as it
+func (c *SnapshotBackfillCheckpoint) Covers(start time.Time) bool {
+	return !c.StartDate.After(start) && !c.LastCompletedDate.Before(start)
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotBackfillCheckpoint_Covers(t *testing.T) {
+	checkpoint := &SnapshotBackfillCheckpoint{
+		StartDate:         time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+		LastCompletedDate: time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	assert.True(t, checkpoint.Covers(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, checkpoint.Covers(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, checkpoint.Covers(time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC)))
+	// An earlier start needs snapshots the backfill never stored
+	assert.False(t, checkpoint.Covers(time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)))
+	// A later start leaves a gap after the checkpoint
+	assert.False(t, checkpoint.Covers(time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)))
+}
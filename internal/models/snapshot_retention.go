@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// SnapshotResolution is how finely performance snapshots of a given age are kept
+type SnapshotResolution string
+
+const (
+	SnapshotResolutionDaily   SnapshotResolution = "DAILY"
+	SnapshotResolutionWeekly  SnapshotResolution = "WEEKLY"
+	SnapshotResolutionMonthly SnapshotResolution = "MONTHLY"
+)
+
+// SnapshotRetentionPolicy decides which performance snapshots to keep as they age. Snapshots
+// younger than DailyMonths are all kept, snapshots younger than WeeklyMonths are thinned to
+// one per week, and older snapshots to one per month.
+//
+// The snapshot kept for a period is its last one, so the value at every period boundary
+// survives: time-weighted returns chained across the remaining snapshots still start and
+// end on the same values. In the weekly tier the last snapshot of each month is kept too,
+// so monthly, quarterly and yearly returns are unaffected by compaction.
+type SnapshotRetentionPolicy struct {
+	DailyMonths  int
+	WeeklyMonths int
+}
+
+// DefaultSnapshotRetentionPolicy keeps daily snapshots for two years, weekly snapshots for
+// five, and monthly snapshots thereafter
+func DefaultSnapshotRetentionPolicy() SnapshotRetentionPolicy {
+	return SnapshotRetentionPolicy{
+		DailyMonths:  24,
+		WeeklyMonths: 60,
+	}
+}
+
+// Validate checks if the policy is usable
+func (p SnapshotRetentionPolicy) Validate() error {
+	if p.DailyMonths < 1 || p.WeeklyMonths < p.DailyMonths {
+		return ErrInvalidRetentionPolicy
+	}
+	return nil
+}
+
+// DailyCutoff returns the date before which snapshots are no longer all kept
+func (p SnapshotRetentionPolicy) DailyCutoff(now time.Time) time.Time {
+	return startOfDay(now).AddDate(0, -p.DailyMonths, 0)
+}
+
+// Resolution returns how finely snapshots taken on date are kept as of now
+func (p SnapshotRetentionPolicy) Resolution(date, now time.Time) SnapshotResolution {
+	switch {
+	case !date.Before(p.DailyCutoff(now)):
+		return SnapshotResolutionDaily
+	case !date.Before(startOfDay(now).AddDate(0, -p.WeeklyMonths, 0)):
+		return SnapshotResolutionWeekly
+	default:
+		return SnapshotResolutionMonthly
+	}
+}
+
+// Compact returns the snapshots the policy no longer keeps. snapshots must belong to one
+// portfolio and be sorted by date ascending. The first snapshot is always kept as the
+// portfolio's starting value, and the last snapshot given is always kept since the period
+// it ends cannot be known to be complete.
+func (p SnapshotRetentionPolicy) Compact(snapshots []*PerformanceSnapshot, now time.Time) []*PerformanceSnapshot {
+	var remove []*PerformanceSnapshot
+	for i := 1; i < len(snapshots)-1; i++ {
+		snapshot, next := snapshots[i], snapshots[i+1]
+
+		var keep bool
+		switch p.Resolution(snapshot.Date, now) {
+		case SnapshotResolutionDaily:
+			keep = true
+		case SnapshotResolutionWeekly:
+			keep = !sameWeek(snapshot.Date, next.Date) || !sameMonth(snapshot.Date, next.Date)
+		default:
+			keep = !sameMonth(snapshot.Date, next.Date)
+		}
+
+		if !keep {
+			remove = append(remove, snapshot)
+		}
+	}
+	return remove
+}
+
+// sameWeek returns true if a and b fall in the same ISO week
+func sameWeek(a, b time.Time) bool {
+	aYear, aWeek := a.ISOWeek()
+	bYear, bWeek := b.ISOWeek()
+	return aYear == bYear && aWeek == bWeek
+}
+
+// sameMonth returns true if a and b fall in the same calendar month
+func sameMonth(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month()
+}
+
+// startOfDay truncates t to midnight in its location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// dailySnapshots returns one snapshot per day from start through end, valued at the day index
+func dailySnapshots(start, end time.Time) []*PerformanceSnapshot {
+	var snapshots []*PerformanceSnapshot
+	for date, i := start, int64(0); !date.After(end); date, i = date.AddDate(0, 0, 1), i+1 {
+		snapshots = append(snapshots, &PerformanceSnapshot{Date: date, TotalValue: decimal.NewFromInt(i)})
+	}
+	return snapshots
+}
+
+// keptDates returns the dates of snapshots not in removed
+func keptDates(snapshots, removed []*PerformanceSnapshot) map[string]bool {
+	removedSet := make(map[*PerformanceSnapshot]bool, len(removed))
+	for _, snapshot := range removed {
+		removedSet[snapshot] = true
+	}
+	kept := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		if !removedSet[snapshot] {
+			kept[snapshot.Date.Format("2006-01-02")] = true
+		}
+	}
+	return kept
+}
+
+func TestSnapshotRetentionPolicy_Validate(t *testing.T) {
+	assert.NoError(t, DefaultSnapshotRetentionPolicy().Validate())
+	assert.NoError(t, SnapshotRetentionPolicy{DailyMonths: 12, WeeklyMonths: 12}.Validate())
+	assert.Equal(t, ErrInvalidRetentionPolicy, SnapshotRetentionPolicy{DailyMonths: 0, WeeklyMonths: 12}.Validate())
+	assert.Equal(t, ErrInvalidRetentionPolicy, SnapshotRetentionPolicy{DailyMonths: 24, WeeklyMonths: 12}.Validate())
+}
+
+func TestSnapshotRetentionPolicy_Resolution(t *testing.T) {
+	policy := DefaultSnapshotRetentionPolicy()
+	now := time.Date(2025, 6, 15, 20, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, SnapshotResolutionDaily, policy.Resolution(time.Date(2023, 6, 15, 0, 0, 0, 0, time.UTC), now))
+	assert.Equal(t, SnapshotResolutionWeekly, policy.Resolution(time.Date(2023, 6, 14, 0, 0, 0, 0, time.UTC), now))
+	assert.Equal(t, SnapshotResolutionWeekly, policy.Resolution(time.Date(2020, 6, 15, 0, 0, 0, 0, time.UTC), now))
+	assert.Equal(t, SnapshotResolutionMonthly, policy.Resolution(time.Date(2020, 6, 14, 0, 0, 0, 0, time.UTC), now))
+}
+
+func TestSnapshotRetentionPolicy_Compact(t *testing.T) {
+	policy := SnapshotRetentionPolicy{DailyMonths: 1, WeeklyMonths: 3}
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	// Daily from 2024-05-15, weekly from 2024-03-15, monthly before that
+	snapshots := dailySnapshots(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), now)
+
+	removed := policy.Compact(snapshots, now)
+	kept := keptDates(snapshots, removed)
+
+	// The first snapshot is the starting value
+	assert.True(t, kept["2024-01-10"])
+
+	// Monthly tier keeps month ends only
+	assert.True(t, kept["2024-01-31"])
+	assert.True(t, kept["2024-02-29"])
+	assert.False(t, kept["2024-01-20"])
+	assert.False(t, kept["2024-02-01"])
+
+	// Weekly tier keeps week ends (Sundays) and month ends
+	assert.True(t, kept["2024-03-17"])
+	assert.True(t, kept["2024-03-31"])
+	assert.True(t, kept["2024-04-07"])
+	assert.True(t, kept["2024-04-30"], "month end that is not a week end")
+	assert.False(t, kept["2024-04-29"])
+	assert.False(t, kept["2024-04-10"])
+
+	// Daily tier is untouched
+	for date := time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC); !date.After(now); date = date.AddDate(0, 0, 1) {
+		assert.True(t, kept[date.Format("2006-01-02")], date)
+	}
+
+	// Compaction is idempotent
+	var remaining []*PerformanceSnapshot
+	for _, snapshot := range snapshots {
+		if kept[snapshot.Date.Format("2006-01-02")] {
+			remaining = append(remaining, snapshot)
+		}
+	}
+	assert.Empty(t, policy.Compact(remaining, now))
+}
+
+func TestSnapshotRetentionPolicy_Compact_PreservesPeriodReturns(t *testing.T) {
+	policy := SnapshotRetentionPolicy{DailyMonths: 1, WeeklyMonths: 2}
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	snapshots := dailySnapshots(time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), now)
+
+	kept := keptDates(snapshots, policy.Compact(snapshots, now))
+
+	// Every month end stays, so monthly, quarterly and year-to-date returns can still be
+	// chained from period-boundary values
+	for month := time.January; month <= time.May; month++ {
+		monthEnd := time.Date(2024, month+1, 0, 0, 0, 0, 0, time.UTC)
+		assert.True(t, kept[monthEnd.Format("2006-01-02")], monthEnd)
+	}
+	assert.True(t, kept["2023-12-31"])
+}
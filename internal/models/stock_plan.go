@@ -0,0 +1,230 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// StockPlanType represents the type of employer equity compensation
+type StockPlanType string
+
+const (
+	StockPlanTypeRSU  StockPlanType = "RSU"
+	StockPlanTypeESPP StockPlanType = "ESPP"
+	StockPlanTypeISO  StockPlanType = "ISO"
+	StockPlanTypeNSO  StockPlanType = "NSO"
+)
+
+// StockPlanEventType represents the type of event recorded against a grant
+type StockPlanEventType string
+
+const (
+	StockPlanEventVest         StockPlanEventType = "VEST"
+	StockPlanEventESPPPurchase StockPlanEventType = "ESPP_PURCHASE"
+	StockPlanEventExercise     StockPlanEventType = "EXERCISE"
+)
+
+// StockPlanGrant represents an employer equity grant held in a portfolio
+type StockPlanGrant struct {
+	ID                    uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID           uuid.UUID        `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
+	Symbol                string           `gorm:"type:varchar(20);not null;index" json:"symbol" validate:"required"`
+	Type                  StockPlanType    `gorm:"type:varchar(10);not null" json:"type" validate:"required"`
+	GrantDate             time.Time        `gorm:"not null" json:"grant_date" validate:"required"`
+	TotalShares           decimal.Decimal  `gorm:"type:numeric(20,8);not null" json:"total_shares" validate:"required"`
+	VestingStartDate      time.Time        `gorm:"not null" json:"vesting_start_date"`
+	VestingMonths         int              `gorm:"not null;default:0" json:"vesting_months"`
+	CliffMonths           int              `gorm:"not null;default:0" json:"cliff_months"`
+	VestingIntervalMonths int              `gorm:"not null;default:1" json:"vesting_interval_months"`
+	StrikePrice           *decimal.Decimal `gorm:"type:numeric(20,8)" json:"strike_price,omitempty"`
+	ExpirationDate        *time.Time       `json:"expiration_date,omitempty"`
+	DiscountPercent       *decimal.Decimal `gorm:"type:numeric(10,4)" json:"discount_percent,omitempty"`
+	Lookback              bool             `gorm:"not null;default:false" json:"lookback"`
+	Notes                 string           `gorm:"type:text" json:"notes,omitempty"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+	Portfolio             *Portfolio       `gorm:"foreignKey:PortfolioID" json:"portfolio,omitempty"`
+}
+
+// TableName specifies the table name for the StockPlanGrant model
+func (StockPlanGrant) TableName() string {
+	return "stock_plan_grants"
+}
+
+// BeforeCreate hook to generate UUID before creating a new grant
+func (g *StockPlanGrant) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	if g.CreatedAt.IsZero() {
+		g.CreatedAt = time.Now().UTC()
+	}
+	if g.UpdatedAt.IsZero() {
+		g.UpdatedAt = time.Now().UTC()
+	}
+	if g.VestingStartDate.IsZero() {
+		g.VestingStartDate = g.GrantDate
+	}
+	if g.VestingIntervalMonths == 0 {
+		g.VestingIntervalMonths = 1
+	}
+	return nil
+}
+
+// BeforeUpdate hook to update the UpdatedAt timestamp
+func (g *StockPlanGrant) BeforeUpdate(tx *gorm.DB) error {
+	g.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// Validate checks if the grant has valid data
+func (g *StockPlanGrant) Validate() error {
+	if g.Symbol == "" {
+		return ErrInvalidSymbol
+	}
+	if !g.isValidType() {
+		return ErrInvalidStockPlanType
+	}
+	if g.GrantDate.IsZero() {
+		return ErrInvalidDate
+	}
+	if g.TotalShares.LessThanOrEqual(decimal.Zero) {
+		return ErrInvalidQuantity
+	}
+	if g.VestingMonths < 0 || g.CliffMonths < 0 || g.VestingIntervalMonths < 0 {
+		return ErrInvalidVestingSchedule
+	}
+	if g.VestingMonths > 0 && g.CliffMonths > g.VestingMonths {
+		return ErrInvalidVestingSchedule
+	}
+	if g.IsOption() && (g.StrikePrice == nil || g.StrikePrice.IsNegative()) {
+		return ErrInvalidPrice
+	}
+	if g.DiscountPercent != nil && (g.DiscountPercent.IsNegative() || g.DiscountPercent.GreaterThanOrEqual(decimal.NewFromInt(100))) {
+		return ErrInvalidValue
+	}
+	return nil
+}
+
+// isValidType checks if the grant type is valid
+func (g *StockPlanGrant) isValidType() bool {
+	switch g.Type {
+	case StockPlanTypeRSU, StockPlanTypeESPP, StockPlanTypeISO, StockPlanTypeNSO:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsOption returns true if the grant is a stock option (ISO or NSO)
+func (g *StockPlanGrant) IsOption() bool {
+	return g.Type == StockPlanTypeISO || g.Type == StockPlanTypeNSO
+}
+
+// VestingEvent represents a single scheduled vest of a grant
+type VestingEvent struct {
+	Date             time.Time       `json:"date"`
+	Shares           decimal.Decimal `json:"shares"`
+	CumulativeShares decimal.Decimal `json:"cumulative_shares"`
+}
+
+// VestingSchedule computes the vesting events for the grant.
+// Shares vest pro-rata every VestingIntervalMonths after the cliff; fractional
+// shares are truncated and the remainder vests with the final event.
+// A grant with no vesting period vests fully on the vesting start date.
+func (g *StockPlanGrant) VestingSchedule() []VestingEvent {
+	start := g.VestingStartDate
+	if start.IsZero() {
+		start = g.GrantDate
+	}
+
+	if g.VestingMonths <= 0 {
+		return []VestingEvent{{Date: start, Shares: g.TotalShares, CumulativeShares: g.TotalShares}}
+	}
+
+	interval := g.VestingIntervalMonths
+	if interval <= 0 {
+		interval = 1
+	}
+
+	total := decimal.NewFromInt(int64(g.VestingMonths))
+	var events []VestingEvent
+	vested := decimal.Zero
+
+	for month := interval; ; month += interval {
+		if month > g.VestingMonths {
+			month = g.VestingMonths
+		}
+		if month >= g.CliffMonths {
+			cumulative := g.TotalShares.Mul(decimal.NewFromInt(int64(month))).Div(total).Truncate(0)
+			if month == g.VestingMonths {
+				cumulative = g.TotalShares
+			}
+			if cumulative.GreaterThan(vested) {
+				events = append(events, VestingEvent{
+					Date:             start.AddDate(0, month, 0),
+					Shares:           cumulative.Sub(vested),
+					CumulativeShares: cumulative,
+				})
+				vested = cumulative
+			}
+		}
+		if month == g.VestingMonths {
+			break
+		}
+	}
+
+	return events
+}
+
+// VestedShares returns the number of shares vested as of the given date
+func (g *StockPlanGrant) VestedShares(asOf time.Time) decimal.Decimal {
+	vested := decimal.Zero
+	for _, event := range g.VestingSchedule() {
+		if event.Date.After(asOf) {
+			break
+		}
+		vested = event.CumulativeShares
+	}
+	return vested
+}
+
+// StockPlanEvent records a vest, ESPP purchase, or option exercise against a grant
+// along with the tax-relevant values at the time of the event
+type StockPlanEvent struct {
+	ID              uuid.UUID          `gorm:"type:uuid;primaryKey" json:"id"`
+	GrantID         uuid.UUID          `gorm:"type:uuid;not null;index" json:"grant_id" validate:"required"`
+	PortfolioID     uuid.UUID          `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
+	TransactionID   uuid.UUID          `gorm:"type:uuid;not null" json:"transaction_id"`
+	Type            StockPlanEventType `gorm:"type:varchar(20);not null" json:"type"`
+	Date            time.Time          `gorm:"not null" json:"date"`
+	Shares          decimal.Decimal    `gorm:"type:numeric(20,8);not null" json:"shares"`
+	SharesWithheld  decimal.Decimal    `gorm:"type:numeric(20,8);not null;default:0" json:"shares_withheld"`
+	FairMarketValue decimal.Decimal    `gorm:"type:numeric(20,8);not null" json:"fair_market_value"`
+	PurchasePrice   decimal.Decimal    `gorm:"type:numeric(20,8);not null;default:0" json:"purchase_price"`
+	CostBasis       decimal.Decimal    `gorm:"type:numeric(20,8);not null" json:"cost_basis"`
+	OrdinaryIncome  decimal.Decimal    `gorm:"type:numeric(20,8);not null;default:0" json:"ordinary_income"`
+	BargainElement  decimal.Decimal    `gorm:"type:numeric(20,8);not null;default:0" json:"bargain_element"`
+	AMTAdjustment   decimal.Decimal    `gorm:"type:numeric(20,8);not null;default:0" json:"amt_adjustment"`
+	CreatedAt       time.Time          `json:"created_at"`
+	Grant           *StockPlanGrant    `gorm:"foreignKey:GrantID" json:"grant,omitempty"`
+}
+
+// TableName specifies the table name for the StockPlanEvent model
+func (StockPlanEvent) TableName() string {
+	return "stock_plan_events"
+}
+
+// BeforeCreate hook to generate UUID before creating a new event
+func (e *StockPlanEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStockPlanGrant_TableName(t *testing.T) {
+	assert.Equal(t, "stock_plan_grants", StockPlanGrant{}.TableName())
+	assert.Equal(t, "stock_plan_events", StockPlanEvent{}.TableName())
+}
+
+func TestStockPlanGrant_Validate(t *testing.T) {
+	strike := decimal.NewFromInt(10)
+	discount := decimal.NewFromInt(15)
+	badDiscount := decimal.NewFromInt(100)
+	grantDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	valid := func() *StockPlanGrant {
+		return &StockPlanGrant{
+			Symbol:        "ACME",
+			Type:          StockPlanTypeRSU,
+			GrantDate:     grantDate,
+			TotalShares:   decimal.NewFromInt(100),
+			VestingMonths: 48,
+			CliffMonths:   12,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(g *StockPlanGrant)
+		wantErr error
+	}{
+		{"valid RSU", func(g *StockPlanGrant) {}, nil},
+		{"missing symbol", func(g *StockPlanGrant) { g.Symbol = "" }, ErrInvalidSymbol},
+		{"invalid type", func(g *StockPlanGrant) { g.Type = "PHANTOM" }, ErrInvalidStockPlanType},
+		{"missing grant date", func(g *StockPlanGrant) { g.GrantDate = time.Time{} }, ErrInvalidDate},
+		{"zero shares", func(g *StockPlanGrant) { g.TotalShares = decimal.Zero }, ErrInvalidQuantity},
+		{"cliff longer than vesting", func(g *StockPlanGrant) { g.CliffMonths = 60 }, ErrInvalidVestingSchedule},
+		{"negative vesting", func(g *StockPlanGrant) { g.VestingMonths = -1 }, ErrInvalidVestingSchedule},
+		{"option without strike", func(g *StockPlanGrant) { g.Type = StockPlanTypeISO }, ErrInvalidPrice},
+		{"option with strike", func(g *StockPlanGrant) { g.Type = StockPlanTypeNSO; g.StrikePrice = &strike }, nil},
+		{"ESPP with discount", func(g *StockPlanGrant) { g.Type = StockPlanTypeESPP; g.DiscountPercent = &discount }, nil},
+		{"discount out of range", func(g *StockPlanGrant) { g.DiscountPercent = &badDiscount }, ErrInvalidValue},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := valid()
+			tt.modify(g)
+			assert.Equal(t, tt.wantErr, g.Validate())
+		})
+	}
+}
+
+func TestStockPlanGrant_VestingSchedule_CliffThenMonthly(t *testing.T) {
+	grant := &StockPlanGrant{
+		GrantDate:             time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		TotalShares:           decimal.NewFromInt(1000),
+		VestingMonths:         48,
+		CliffMonths:           12,
+		VestingIntervalMonths: 1,
+	}
+
+	schedule := grant.VestingSchedule()
+	require.Len(t, schedule, 37)
+
+	// Cliff vests a full year's worth
+	assert.Equal(t, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), schedule[0].Date)
+	assert.True(t, decimal.NewFromInt(250).Equal(schedule[0].Shares))
+
+	// Every share vests by the end, with no fractional shares along the way
+	last := schedule[len(schedule)-1]
+	assert.Equal(t, time.Date(2028, 1, 15, 0, 0, 0, 0, time.UTC), last.Date)
+	assert.True(t, decimal.NewFromInt(1000).Equal(last.CumulativeShares))
+
+	total := decimal.Zero
+	for _, event := range schedule {
+		assert.True(t, event.Shares.Equal(event.Shares.Truncate(0)))
+		total = total.Add(event.Shares)
+	}
+	assert.True(t, decimal.NewFromInt(1000).Equal(total))
+}
+
+func TestStockPlanGrant_VestingSchedule_Quarterly(t *testing.T) {
+	grant := &StockPlanGrant{
+		GrantDate:             time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		TotalShares:           decimal.NewFromInt(400),
+		VestingMonths:         12,
+		VestingIntervalMonths: 3,
+	}
+
+	schedule := grant.VestingSchedule()
+	require.Len(t, schedule, 4)
+	for _, event := range schedule {
+		assert.True(t, decimal.NewFromInt(100).Equal(event.Shares))
+	}
+}
+
+func TestStockPlanGrant_VestingSchedule_Immediate(t *testing.T) {
+	grantDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	grant := &StockPlanGrant{
+		GrantDate:   grantDate,
+		TotalShares: decimal.NewFromInt(50),
+	}
+
+	schedule := grant.VestingSchedule()
+	require.Len(t, schedule, 1)
+	assert.Equal(t, grantDate, schedule[0].Date)
+	assert.True(t, decimal.NewFromInt(50).Equal(schedule[0].Shares))
+}
+
+func TestStockPlanGrant_VestedShares(t *testing.T) {
+	grant := &StockPlanGrant{
+		GrantDate:             time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		TotalShares:           decimal.NewFromInt(480),
+		VestingMonths:         48,
+		CliffMonths:           12,
+		VestingIntervalMonths: 1,
+	}
+
+	assert.True(t, decimal.Zero.Equal(grant.VestedShares(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))))
+	assert.True(t, decimal.NewFromInt(120).Equal(grant.VestedShares(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))))
+	assert.True(t, decimal.NewFromInt(130).Equal(grant.VestedShares(time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC))))
+	assert.True(t, decimal.NewFromInt(480).Equal(grant.VestedShares(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))))
+}
+
+func TestTransaction_IsStockPlanAcquisition(t *testing.T) {
+	for _, txType := range []TransactionType{TransactionTypeRSUVest, TransactionTypeESPPPurchase, TransactionTypeOptionExercise} {
+		tx := &Transaction{Type: txType}
+		assert.True(t, tx.IsStockPlanAcquisition())
+		assert.True(t, tx.IsBuy())
+	}
+	assert.False(t, (&Transaction{Type: TransactionTypeBuy}).IsStockPlanAcquisition())
+}
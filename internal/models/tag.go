@@ -0,0 +1,104 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxTagLength is the longest tag name allowed, in characters
+	MaxTagLength = 50
+	// MaxTagsPerItem is how many tags a portfolio or transaction can carry
+	MaxTagsPerItem = 20
+)
+
+// Tag is a free-form label, such as "retirement" or "speculative", that a user puts on their
+// portfolios and transactions. Names are unique per user and stored normalized.
+type Tag struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_tags_user_id_name" json:"user_id"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_user_id_name" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for the Tag model
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// BeforeCreate hook to generate UUID before creating a new tag
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// PortfolioTag links a tag to a portfolio that carries it
+type PortfolioTag struct {
+	PortfolioID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TagID       uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+}
+
+// TableName specifies the table name for the PortfolioTag model
+func (PortfolioTag) TableName() string {
+	return "portfolio_tags"
+}
+
+// TransactionTag links a tag to a transaction that carries it
+type TransactionTag struct {
+	TransactionID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	TagID         uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+}
+
+// TableName specifies the table name for the TransactionTag model
+func (TransactionTag) TableName() string {
+	return "transaction_tags"
+}
+
+// TagUsage is a tag with how many of the user's portfolios and transactions carry it
+type TagUsage struct {
+	Name         string
+	Portfolios   int
+	Transactions int
+}
+
+// NormalizeTag returns the stored form of a tag name: trimmed and lowercased, so that
+// "Retirement" and "retirement " are the same tag. Names must be 1 to MaxTagLength characters
+// and can't contain commas, which separate tags in CSV exports.
+func NormalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || utf8.RuneCountInString(name) > MaxTagLength || strings.Contains(name, ",") {
+		return "", ErrInvalidTag
+	}
+	return name, nil
+}
+
+// NormalizeTags normalizes a list of tag names, dropping duplicates and sorting the result
+func NormalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag, err := NormalizeTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > MaxTagsPerItem {
+		return nil, ErrTooManyTags
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	name, err := NormalizeTag("  Long-Term ")
+	require.NoError(t, err)
+	assert.Equal(t, "long-term", name)
+
+	name, err = NormalizeTag(strings.Repeat("é", MaxTagLength))
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("é", MaxTagLength), name)
+
+	for _, invalid := range []string{"", "   ", "a,b", strings.Repeat("a", MaxTagLength+1)} {
+		_, err := NormalizeTag(invalid)
+		assert.Equal(t, ErrInvalidTag, err, invalid)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{"Speculative", "retirement", "speculative "})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"retirement", "speculative"}, tags)
+
+	tags, err = NormalizeTags(nil)
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	_, err = NormalizeTags([]string{"ok", ""})
+	assert.Equal(t, ErrInvalidTag, err)
+
+	many := make([]string, MaxTagsPerItem+1)
+	for i := range many {
+		many[i] = strings.Repeat("t", i+1)
+	}
+	_, err = NormalizeTags(many)
+	assert.Equal(t, ErrTooManyTags, err)
+
+	// Duplicates only count once
+	_, err = NormalizeTags(append(many[:MaxTagsPerItem], many[0]))
+	assert.NoError(t, err)
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -81,3 +82,35 @@ func (tl *TaxLot) ReduceQuantity(quantitySold decimal.Decimal) error {
 
 	return nil
 }
+
+// WashSaleWindowDays is how many days before or after a loss sale a purchase of the same
+// security disallows the loss
+const WashSaleWindowDays = 30
+
+// DefaultHarvestReplacements maps common funds to similar, but not substantially identical,
+// funds that keep the market exposure after harvesting a loss
+var DefaultHarvestReplacements = map[string][]string{
+	"SPY":  {"VTI", "SCHX"},
+	"VOO":  {"VTI", "SCHX"},
+	"IVV":  {"VTI", "SCHX"},
+	"VTI":  {"ITOT", "SCHB"},
+	"QQQ":  {"VGT", "XLK"},
+	"VEA":  {"IEFA", "SCHF"},
+	"VWO":  {"IEMG", "SCHE"},
+	"VXUS": {"IXUS"},
+	"BND":  {"AGG", "SCHZ"},
+	"AGG":  {"BND", "SCHZ"},
+}
+
+// HarvestReplacements returns the default replacements with the configured ones, by symbol,
+// taking precedence
+func HarvestReplacements(configured map[string][]string) map[string][]string {
+	replacements := make(map[string][]string, len(DefaultHarvestReplacements)+len(configured))
+	for symbol, candidates := range DefaultHarvestReplacements {
+		replacements[symbol] = candidates
+	}
+	for symbol, candidates := range configured {
+		replacements[strings.ToUpper(symbol)] = candidates
+	}
+	return replacements
+}
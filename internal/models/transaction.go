@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,24 +21,88 @@ const (
 	TransactionTypeSpinoff          TransactionType = "SPINOFF"
 	TransactionTypeDividendReinvest TransactionType = "DIVIDEND_REINVEST"
 	TransactionTypeTickerChange     TransactionType = "TICKER_CHANGE"
+	TransactionTypeRSUVest          TransactionType = "RSU_VEST"
+	TransactionTypeESPPPurchase     TransactionType = "ESPP_PURCHASE"
+	TransactionTypeOptionExercise   TransactionType = "OPTION_EXERCISE"
+	TransactionTypeBuyToOpen        TransactionType = "BUY_TO_OPEN"
+	TransactionTypeSellToClose      TransactionType = "SELL_TO_CLOSE"
+	TransactionTypeOptionExpiration TransactionType = "OPTION_EXPIRATION"
+	TransactionTypeOptionAssignment TransactionType = "OPTION_ASSIGNMENT"
+	// TransactionTypeReturnOfCapital records a non-dividend distribution; like a dividend its
+	// quantity is the total amount received
+	TransactionTypeReturnOfCapital TransactionType = "RETURN_OF_CAPITAL"
+	// TransactionTypeStockDividend records the shares a stock dividend added to a position
+	TransactionTypeStockDividend TransactionType = "STOCK_DIVIDEND"
+	// Fee transactions record a fee charged outside of a trade's commission, such as an
+	// advisory fee, a depositary receipt's custody fee or a currency conversion fee. Like a
+	// dividend their quantity is the total amount, and they leave positions unchanged.
+	TransactionTypeManagementFee TransactionType = "MANAGEMENT_FEE"
+	TransactionTypeADRFee        TransactionType = "ADR_FEE"
+	TransactionTypeFXFee         TransactionType = "FX_FEE"
+	// Income transactions record income other than dividends: fees from lending out shares,
+	// interest and rebates. Like a dividend their quantity is the total amount received, and
+	// they leave positions unchanged.
+	TransactionTypeLendingIncome TransactionType = "LENDING_INCOME"
+	TransactionTypeInterest      TransactionType = "INTEREST"
+	TransactionTypeRebate        TransactionType = "REBATE"
+	// Cash flow transactions record money deposited into or withdrawn from the portfolio,
+	// the external cash flows its contributions are measured by. Their symbol is the
+	// currency moved and their quantity the amount, and they leave positions unchanged.
+	TransactionTypeDeposit    TransactionType = "DEPOSIT"
+	TransactionTypeWithdrawal TransactionType = "WITHDRAWAL"
+	// TransactionTypeOpeningBalance records a lot held before the portfolio's recorded history
+	// starts. Its date is the lot's purchase date and its price the cost basis per share; it
+	// opens the lot like a purchase, without being a trade.
+	TransactionTypeOpeningBalance TransactionType = "OPENING_BALANCE"
+	// TransactionTypeCapitalGainDistribution records capital gains a fund paid out without
+	// any shares being sold. Like a dividend its quantity is the total amount received; its
+	// category is the term the fund designated, long term unless it is SHORT_TERM.
+	TransactionTypeCapitalGainDistribution TransactionType = "CAPITAL_GAIN_DISTRIBUTION"
 )
 
+// Terms a fund designates its capital gain distributions as, held in their category
+const (
+	CapitalGainTermShort = "SHORT_TERM"
+	CapitalGainTermLong  = "LONG_TERM"
+)
+
+// IncomeCategoryDividend is the income category of dividends, cash and reinvested
+const IncomeCategoryDividend = "DIVIDEND"
+
+// MaxIncomeCategoryLength is the longest income category a transaction may have
+const MaxIncomeCategoryLength = 50
+
+// FeeTransactionTypes lists the fee transaction types, in the order fee summaries report them
+var FeeTransactionTypes = []TransactionType{TransactionTypeManagementFee, TransactionTypeADRFee, TransactionTypeFXFee}
+
+// IncomeTransactionTypes lists the transaction types of income other than dividends
+var IncomeTransactionTypes = []TransactionType{TransactionTypeLendingIncome, TransactionTypeInterest, TransactionTypeRebate}
+
+// CashFlowTransactionTypes lists the transaction types of external cash flows
+var CashFlowTransactionTypes = []TransactionType{TransactionTypeDeposit, TransactionTypeWithdrawal}
+
 // Transaction represents a portfolio transaction
 type Transaction struct {
-	ID            uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
-	PortfolioID   uuid.UUID        `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
-	Type          TransactionType  `gorm:"type:varchar(20);not null" json:"type" validate:"required"`
-	Symbol        string           `gorm:"type:varchar(20);not null;index" json:"symbol" validate:"required"`
-	Date          time.Time        `gorm:"not null;index" json:"date" validate:"required"`
-	Quantity      decimal.Decimal  `gorm:"type:numeric(20,8);not null" json:"quantity" validate:"required"`
-	Price         *decimal.Decimal `gorm:"type:numeric(20,8)" json:"price,omitempty"`
-	Commission    decimal.Decimal  `gorm:"type:numeric(20,8);not null;default:0" json:"commission"`
-	Currency      string           `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
-	Notes         string           `gorm:"type:text" json:"notes,omitempty"`
-	ImportBatchID *uuid.UUID       `gorm:"type:uuid" json:"import_batch_id,omitempty"`
-	CreatedAt     time.Time        `json:"created_at"`
-	UpdatedAt     time.Time        `json:"updated_at"`
-	Portfolio     *Portfolio       `gorm:"foreignKey:PortfolioID" json:"portfolio,omitempty"`
+	ID          uuid.UUID        `gorm:"type:uuid;primaryKey" json:"id"`
+	PortfolioID uuid.UUID        `gorm:"type:uuid;not null;index" json:"portfolio_id" validate:"required"`
+	Type        TransactionType  `gorm:"type:varchar(30);not null" json:"type" validate:"required"`
+	Symbol      string           `gorm:"type:varchar(20);not null;index" json:"symbol" validate:"required"`
+	AssetType   AssetType        `gorm:"type:varchar(10);not null;default:'EQUITY'" json:"asset_type"`
+	Date        time.Time        `gorm:"not null;index" json:"date" validate:"required"`
+	Quantity    decimal.Decimal  `gorm:"type:numeric(20,8);not null" json:"quantity" validate:"required"`
+	Price       *decimal.Decimal `gorm:"type:numeric(20,8)" json:"price,omitempty"`
+	Multiplier  int              `gorm:"not null;default:1" json:"multiplier"`
+	Commission  decimal.Decimal  `gorm:"type:numeric(20,8);not null;default:0" json:"commission"`
+	Currency    string           `gorm:"type:varchar(3);not null;default:'USD'" json:"currency"`
+	Notes       string           `gorm:"type:text" json:"notes,omitempty"`
+	// Category is the category an income transaction is reported under, its type when empty,
+	// or the term of a capital gain distribution. Other transactions have none.
+	Category      string     `gorm:"type:varchar(50)" json:"category,omitempty"`
+	ImportBatchID *uuid.UUID `gorm:"type:uuid" json:"import_batch_id,omitempty"`
+	Version       int        `gorm:"not null;default:1" json:"version"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Portfolio     *Portfolio `gorm:"foreignKey:PortfolioID" json:"portfolio,omitempty"`
 }
 
 // TableName specifies the table name for the Transaction model
@@ -56,8 +121,23 @@ func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
 	if t.UpdatedAt.IsZero() {
 		t.UpdatedAt = time.Now().UTC()
 	}
+	if t.AssetType == "" {
+		t.AssetType = AssetTypeForSymbol(t.Symbol)
+	}
 	if t.Currency == "" {
-		t.Currency = "USD"
+		if _, quoteCurrency, ok := CryptoPair(t.Symbol); ok && t.AssetType == AssetTypeCrypto {
+			t.Currency = quoteCurrency
+		} else if t.IsCashFlow() {
+			t.Currency = t.Symbol
+		} else {
+			t.Currency = "USD"
+		}
+	}
+	if t.Multiplier == 0 {
+		t.Multiplier = 1
+	}
+	if t.Version == 0 {
+		t.Version = 1
 	}
 	if t.Commission.IsZero() {
 		t.Commission = decimal.Zero
@@ -82,22 +162,105 @@ func (t *Transaction) Validate() error {
 	if !t.isValidTransactionType() {
 		return ErrInvalidTransactionType
 	}
-	// Price is required for BUY and SELL transactions
-	if (t.Type == TransactionTypeBuy || t.Type == TransactionTypeSell) && (t.Price == nil || t.Price.LessThanOrEqual(decimal.Zero)) {
+	// Price is required for buys and sells, options included
+	if t.requiresPrice() && (t.Price == nil || t.Price.LessThanOrEqual(decimal.Zero)) {
 		return ErrInvalidPrice
 	}
 	if t.Commission.IsNegative() {
 		return ErrInvalidPrice
 	}
+	if t.Type == TransactionTypeCapitalGainDistribution {
+		if t.Category != "" && t.Category != CapitalGainTermShort && t.Category != CapitalGainTermLong {
+			return ErrInvalidCapitalGainTerm
+		}
+	} else if t.Category != "" && (!t.IsIncome() || len(t.Category) > MaxIncomeCategoryLength) {
+		return ErrInvalidIncomeCategory
+	}
+	if t.IsCashFlow() {
+		return t.validateCashFlow()
+	}
+	return t.validateAssetType()
+}
+
+// validateAssetType checks the symbol, quantity and currency against the asset type. An
+// unset asset type is taken from the symbol, as BeforeCreate does. Crypto transactions must
+// be in a coin pair, quoted in the pair's quote currency, with at most 8 decimal places.
+func (t *Transaction) validateAssetType() error {
+	assetType := t.AssetType
+	if assetType == "" {
+		assetType = AssetTypeForSymbol(t.Symbol)
+	}
+	if !assetType.IsValid() {
+		return ErrInvalidAssetType
+	}
+	if assetType == AssetTypeOption || t.IsOptionTrade() {
+		return t.validateOption(assetType)
+	}
+	if assetType != AssetTypeCrypto {
+		return nil
+	}
+
+	_, quoteCurrency, ok := CryptoPair(t.Symbol)
+	if !ok {
+		return ErrInvalidCryptoPair
+	}
+	if !hasAtMostDecimals(t.Quantity, CryptoQuantityDecimals) {
+		return ErrInvalidCryptoQuantity
+	}
+	if t.Currency != "" && t.Currency != quoteCurrency {
+		return ErrInvalidCryptoCurrency
+	}
+	return nil
+}
+
+// validateCashFlow checks a cash flow's symbol is a currency code and, when the currency is
+// set, the same one
+func (t *Transaction) validateCashFlow() error {
+	if len(t.Symbol) != 3 || strings.ToUpper(t.Symbol) != t.Symbol || t.Currency != "" && t.Currency != t.Symbol {
+		return ErrInvalidCashFlow
+	}
+	return nil
+}
+
+// validateOption checks option transactions are in whole contracts of an OCC symbol and
+// use the option transaction types, which nothing else may use
+func (t *Transaction) validateOption(assetType AssetType) error {
+	if assetType != AssetTypeOption || !t.IsOptionTrade() {
+		return ErrInvalidOptionTrade
+	}
+	if !IsOptionSymbol(t.Symbol) {
+		return ErrInvalidOptionSymbol
+	}
+	if !t.Quantity.Equal(t.Quantity.Truncate(0)) {
+		return ErrInvalidOptionQuantity
+	}
 	return nil
 }
 
+// requiresPrice returns true for the transaction types that must have a positive price
+func (t *Transaction) requiresPrice() bool {
+	switch t.Type {
+	case TransactionTypeBuy, TransactionTypeSell, TransactionTypeBuyToOpen, TransactionTypeSellToClose:
+		return true
+	default:
+		return false
+	}
+}
+
 // isValidTransactionType checks if the transaction type is valid
 func (t *Transaction) isValidTransactionType() bool {
 	switch t.Type {
 	case TransactionTypeBuy, TransactionTypeSell, TransactionTypeDividend,
 		TransactionTypeSplit, TransactionTypeMerger, TransactionTypeSpinoff,
-		TransactionTypeDividendReinvest, TransactionTypeTickerChange:
+		TransactionTypeDividendReinvest, TransactionTypeTickerChange,
+		TransactionTypeRSUVest, TransactionTypeESPPPurchase, TransactionTypeOptionExercise,
+		TransactionTypeBuyToOpen, TransactionTypeSellToClose,
+		TransactionTypeOptionExpiration, TransactionTypeOptionAssignment,
+		TransactionTypeReturnOfCapital, TransactionTypeStockDividend,
+		TransactionTypeManagementFee, TransactionTypeADRFee, TransactionTypeFXFee,
+		TransactionTypeLendingIncome, TransactionTypeInterest, TransactionTypeRebate,
+		TransactionTypeDeposit, TransactionTypeWithdrawal, TransactionTypeOpeningBalance,
+		TransactionTypeCapitalGainDistribution:
 		return true
 	default:
 		return false
@@ -106,20 +269,109 @@ func (t *Transaction) isValidTransactionType() bool {
 
 // IsBuy returns true if the transaction is a buy
 func (t *Transaction) IsBuy() bool {
-	return t.Type == TransactionTypeBuy || t.Type == TransactionTypeDividendReinvest
+	return t.Type == TransactionTypeBuy || t.Type == TransactionTypeDividendReinvest || t.IsStockPlanAcquisition() ||
+		t.Type == TransactionTypeBuyToOpen || t.Type == TransactionTypeOpeningBalance
+}
+
+// IsStockPlanAcquisition returns true if the transaction acquires shares through an employer stock plan
+func (t *Transaction) IsStockPlanAcquisition() bool {
+	return t.Type == TransactionTypeRSUVest || t.Type == TransactionTypeESPPPurchase || t.Type == TransactionTypeOptionExercise
 }
 
-// IsSell returns true if the transaction is a sell
+// IsSell returns true if the transaction is a sell. Option expirations and assignments
+// close the option position, at no price and at its premium respectively.
 func (t *Transaction) IsSell() bool {
-	return t.Type == TransactionTypeSell
+	return t.Type == TransactionTypeSell || t.Type == TransactionTypeSellToClose ||
+		t.Type == TransactionTypeOptionExpiration || t.Type == TransactionTypeOptionAssignment
+}
+
+// IsFee returns true if the transaction records a fee charged outside of a trade
+func (t *Transaction) IsFee() bool {
+	return t.Type == TransactionTypeManagementFee || t.Type == TransactionTypeADRFee || t.Type == TransactionTypeFXFee
+}
+
+// GetFees returns what the transaction cost in fees: its commission, plus the amount charged
+// by a fee transaction
+func (t *Transaction) GetFees() decimal.Decimal {
+	if t.IsFee() {
+		return t.Quantity.Add(t.Commission)
+	}
+	return t.Commission
+}
+
+// IsIncome returns true if the transaction records income other than a dividend
+func (t *Transaction) IsIncome() bool {
+	return t.Type == TransactionTypeLendingIncome || t.Type == TransactionTypeInterest || t.Type == TransactionTypeRebate
+}
+
+// IncomeCategory returns the category the transaction's income is reported under: its own
+// category or type for income transactions, DIVIDEND for dividends, and "" for transactions
+// that aren't income
+func (t *Transaction) IncomeCategory() string {
+	switch {
+	case t.IsIncome() && t.Category != "":
+		return t.Category
+	case t.IsIncome():
+		return string(t.Type)
+	case t.Type == TransactionTypeDividend || t.Type == TransactionTypeDividendReinvest:
+		return IncomeCategoryDividend
+	default:
+		return ""
+	}
+}
+
+// IsLongTermDistribution returns true if the transaction is a capital gain distribution the
+// fund didn't designate short term
+func (t *Transaction) IsLongTermDistribution() bool {
+	return t.Type == TransactionTypeCapitalGainDistribution && t.Category != CapitalGainTermShort
+}
+
+// IsCashFlow returns true if the transaction records money deposited into or withdrawn from
+// the portfolio
+func (t *Transaction) IsCashFlow() bool {
+	return t.Type == TransactionTypeDeposit || t.Type == TransactionTypeWithdrawal
+}
+
+// CashFlowAmount returns the money a cash flow transaction moved into the portfolio, negative
+// for withdrawals, and zero for other transactions
+func (t *Transaction) CashFlowAmount() decimal.Decimal {
+	switch t.Type {
+	case TransactionTypeDeposit:
+		return t.Quantity
+	case TransactionTypeWithdrawal:
+		return t.Quantity.Neg()
+	default:
+		return decimal.Zero
+	}
+}
+
+// IsOptionTrade returns true if the transaction opens, closes or settles an option position
+func (t *Transaction) IsOptionTrade() bool {
+	switch t.Type {
+	case TransactionTypeBuyToOpen, TransactionTypeSellToClose,
+		TransactionTypeOptionExpiration, TransactionTypeOptionAssignment:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetMultiplier returns how many units each unit of quantity covers: the contract
+// multiplier for options and 1 for everything else
+func (t *Transaction) GetMultiplier() decimal.Decimal {
+	if t.Multiplier <= 1 {
+		return decimal.NewFromInt(1)
+	}
+	return decimal.NewFromInt(int64(t.Multiplier))
 }
 
-// GetTotalCost returns the total cost of the transaction including commission
+// GetTotalCost returns the total cost of the transaction including commission. Option
+// prices are per share, so the cost is price x contracts x multiplier.
 func (t *Transaction) GetTotalCost() decimal.Decimal {
 	if t.Price == nil {
 		return t.Commission
 	}
-	return t.Price.Mul(t.Quantity).Add(t.Commission)
+	return t.Price.Mul(t.Quantity).Mul(t.GetMultiplier()).Add(t.Commission)
 }
 
 // GetProceeds returns the proceeds from a sell transaction minus commission
@@ -127,5 +379,5 @@ func (t *Transaction) GetProceeds() decimal.Decimal {
 	if t.Price == nil {
 		return decimal.Zero
 	}
-	return t.Price.Mul(t.Quantity).Sub(t.Commission)
+	return t.Price.Mul(t.Quantity).Mul(t.GetMultiplier()).Sub(t.Commission)
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -31,6 +32,21 @@ func TestTransaction_BeforeCreate(t *testing.T) {
 		assert.False(t, transaction.UpdatedAt.IsZero())
 		assert.Equal(t, "USD", transaction.Currency)
 		assert.True(t, transaction.Commission.IsZero())
+		assert.Equal(t, AssetTypeEquity, transaction.AssetType)
+	})
+
+	t.Run("prices a coin pair in its quote currency", func(t *testing.T) {
+		transaction := &Transaction{
+			PortfolioID: uuid.New(),
+			Type:        TransactionTypeBuy,
+			Symbol:      "BTC-EUR",
+			Date:        time.Now(),
+			Quantity:    decimal.RequireFromString("0.00250000"),
+		}
+
+		assert.NoError(t, transaction.BeforeCreate(db))
+		assert.Equal(t, AssetTypeCrypto, transaction.AssetType)
+		assert.Equal(t, "EUR", transaction.Currency)
 	})
 }
 
@@ -128,6 +144,41 @@ func TestTransaction_Validate(t *testing.T) {
 
 		assert.Error(t, err)
 	})
+
+	t.Run("crypto transactions", func(t *testing.T) {
+		btcPrice := decimal.NewFromInt(42000)
+		tests := []struct {
+			name      string
+			assetType AssetType
+			symbol    string
+			quantity  string
+			currency  string
+			wantErr   error
+		}{
+			{"coin pair with 8 decimals", "", "BTC-USD", "0.12345678", "USD", nil},
+			{"explicit crypto asset type", AssetTypeCrypto, "ETH-EUR", "1.5", "", nil},
+			{"more than 8 decimals", AssetTypeCrypto, "BTC-USD", "0.123456789", "USD", ErrInvalidCryptoQuantity},
+			{"currency other than the quote currency", AssetTypeCrypto, "BTC-USD", "1", "EUR", ErrInvalidCryptoCurrency},
+			{"crypto without a coin pair", AssetTypeCrypto, "BTC", "1", "USD", ErrInvalidCryptoPair},
+			{"equity keeps its precision", AssetTypeEquity, "BRK-B", "0.123456789", "USD", nil},
+			{"unknown asset type", AssetType("BOND"), "AAPL", "1", "USD", ErrInvalidAssetType},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				transaction := &Transaction{
+					Type:      TransactionTypeBuy,
+					Symbol:    tt.symbol,
+					AssetType: tt.assetType,
+					Quantity:  decimal.RequireFromString(tt.quantity),
+					Price:     &btcPrice,
+					Currency:  tt.currency,
+				}
+
+				assert.ErrorIs(t, transaction.Validate(), tt.wantErr)
+			})
+		}
+	})
 }
 
 func TestTransaction_isValidTransactionType(t *testing.T) {
@@ -140,6 +191,15 @@ func TestTransaction_isValidTransactionType(t *testing.T) {
 		TransactionTypeSpinoff,
 		TransactionTypeDividendReinvest,
 		TransactionTypeTickerChange,
+		TransactionTypeManagementFee,
+		TransactionTypeADRFee,
+		TransactionTypeFXFee,
+		TransactionTypeLendingIncome,
+		TransactionTypeInterest,
+		TransactionTypeRebate,
+		TransactionTypeDeposit,
+		TransactionTypeWithdrawal,
+		TransactionTypeOpeningBalance,
 	}
 
 	for _, tt := range validTypes {
@@ -162,6 +222,11 @@ func TestTransaction_IsBuy(t *testing.T) {
 		assert.True(t, transaction.IsBuy())
 	})
 
+	t.Run("opening balance is true", func(t *testing.T) {
+		transaction := &Transaction{Type: TransactionTypeOpeningBalance}
+		assert.True(t, transaction.IsBuy())
+	})
+
 	t.Run("sell is false", func(t *testing.T) {
 		transaction := &Transaction{Type: TransactionTypeSell}
 		assert.False(t, transaction.IsBuy())
@@ -234,6 +299,96 @@ func TestTransaction_GetProceeds(t *testing.T) {
 	})
 }
 
+func TestTransaction_GetFees(t *testing.T) {
+	t.Run("fee transaction", func(t *testing.T) {
+		transaction := &Transaction{Type: TransactionTypeADRFee, Quantity: decimal.NewFromFloat(2.5)}
+		assert.True(t, transaction.IsFee())
+		assert.True(t, decimal.NewFromFloat(2.5).Equal(transaction.GetFees()))
+	})
+
+	t.Run("trade commission", func(t *testing.T) {
+		price := decimal.NewFromInt(100)
+		transaction := &Transaction{
+			Type:       TransactionTypeBuy,
+			Quantity:   decimal.NewFromInt(10),
+			Price:      &price,
+			Commission: decimal.NewFromFloat(4.95),
+		}
+		assert.False(t, transaction.IsFee())
+		assert.True(t, decimal.NewFromFloat(4.95).Equal(transaction.GetFees()))
+	})
+}
+
+func TestTransaction_IncomeCategory(t *testing.T) {
+	tests := []struct {
+		name        string
+		transaction Transaction
+		want        string
+	}{
+		{"income defaults to its type", Transaction{Type: TransactionTypeInterest}, "INTEREST"},
+		{"income with a category", Transaction{Type: TransactionTypeLendingIncome, Category: "FULLY_PAID_LENDING"}, "FULLY_PAID_LENDING"},
+		{"cash dividend", Transaction{Type: TransactionTypeDividend}, IncomeCategoryDividend},
+		{"reinvested dividend", Transaction{Type: TransactionTypeDividendReinvest}, IncomeCategoryDividend},
+		{"buy", Transaction{Type: TransactionTypeBuy}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.transaction.IncomeCategory())
+		})
+	}
+}
+
+func TestTransaction_Validate_Category(t *testing.T) {
+	price := decimal.NewFromInt(100)
+
+	income := &Transaction{Type: TransactionTypeRebate, Symbol: "VTI", Quantity: decimal.NewFromInt(2), Category: "PROMOTION"}
+	assert.NoError(t, income.Validate())
+
+	income.Category = strings.Repeat("x", MaxIncomeCategoryLength+1)
+	assert.ErrorIs(t, income.Validate(), ErrInvalidIncomeCategory)
+
+	buy := &Transaction{Type: TransactionTypeBuy, Symbol: "VTI", Quantity: decimal.NewFromInt(2), Price: &price, Category: "PROMOTION"}
+	assert.ErrorIs(t, buy.Validate(), ErrInvalidIncomeCategory)
+}
+
+func TestTransaction_CapitalGainDistribution(t *testing.T) {
+	distribution := &Transaction{Type: TransactionTypeCapitalGainDistribution, Symbol: "VFIAX", Quantity: decimal.NewFromInt(40)}
+	assert.NoError(t, distribution.Validate())
+	assert.True(t, distribution.IsLongTermDistribution())
+
+	distribution.Category = CapitalGainTermShort
+	assert.NoError(t, distribution.Validate())
+	assert.False(t, distribution.IsLongTermDistribution())
+
+	distribution.Category = "PROMOTION"
+	assert.ErrorIs(t, distribution.Validate(), ErrInvalidCapitalGainTerm)
+
+	dividend := &Transaction{Type: TransactionTypeDividend, Symbol: "VFIAX", Quantity: decimal.NewFromInt(40)}
+	assert.False(t, dividend.IsLongTermDistribution())
+}
+
+func TestTransaction_CashFlow(t *testing.T) {
+	deposit := &Transaction{Type: TransactionTypeDeposit, Symbol: "EUR", Quantity: decimal.NewFromInt(500), Currency: "EUR"}
+	assert.NoError(t, deposit.Validate())
+	assert.True(t, deposit.IsCashFlow())
+	assert.True(t, decimal.NewFromInt(500).Equal(deposit.CashFlowAmount()))
+
+	withdrawal := &Transaction{Type: TransactionTypeWithdrawal, Symbol: "USD", Quantity: decimal.NewFromInt(200)}
+	assert.NoError(t, withdrawal.Validate())
+	assert.True(t, decimal.NewFromInt(-200).Equal(withdrawal.CashFlowAmount()))
+
+	withdrawal.Currency = "EUR"
+	assert.ErrorIs(t, withdrawal.Validate(), ErrInvalidCashFlow)
+
+	withdrawal = &Transaction{Type: TransactionTypeWithdrawal, Symbol: "AAPL", Quantity: decimal.NewFromInt(200)}
+	assert.ErrorIs(t, withdrawal.Validate(), ErrInvalidCashFlow)
+
+	buy := &Transaction{Type: TransactionTypeBuy, Quantity: decimal.NewFromInt(10)}
+	assert.False(t, buy.IsCashFlow())
+	assert.True(t, buy.CashFlowAmount().IsZero())
+}
+
 func TestTransaction_TableName(t *testing.T) {
 	transaction := Transaction{}
 	assert.Equal(t, "transactions", transaction.TableName())
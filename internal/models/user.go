@@ -4,10 +4,26 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/utils"
 )
 
+// UserRole is a user's level of access
+type UserRole string
+
+const (
+	// RoleUser can only manage their own portfolios
+	RoleUser UserRole = "user"
+	// RoleAdmin can also manage other users through the admin user API
+	RoleAdmin UserRole = "admin"
+)
+
+// IsValid returns true if r is a known role
+func (r UserRole) IsValid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
 // User represents a user in the system
 type User struct {
 	ID           uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
@@ -16,6 +32,19 @@ type User struct {
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
 	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
+	Role         UserRole   `gorm:"type:varchar(20);not null;default:'user'" json:"role"`
+
+	// DisabledAt is set while an administrator has disabled the account. Disabled users
+	// cannot log in and their tokens and API keys are rejected.
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	// PasswordResetRequired is set when an administrator forces a password reset. The
+	// account is locked until the user chooses a new password through a reset link.
+	PasswordResetRequired bool `gorm:"not null;default:false" json:"password_reset_required"`
+
+	// OrganizationID and ExternalID are set for users provisioned through the admin API;
+	// ExternalID is the provisioning tool's stable identifier within the organization
+	OrganizationID *uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_users_organization_external_id" json:"organization_id,omitempty"`
+	ExternalID     *string    `gorm:"type:varchar(255);uniqueIndex:idx_users_organization_external_id" json:"external_id,omitempty"`
 }
 
 // TableName specifies the table name for the User model
@@ -34,23 +63,47 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.UpdatedAt.IsZero() {
 		u.UpdatedAt = time.Now().UTC()
 	}
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
+	return nil
+}
+
+// IsAdmin returns true if the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsDisabled returns true if an administrator has disabled the account
+func (u *User) IsDisabled() bool {
+	return u.DisabledAt != nil
+}
+
+// CanAuthenticate returns nil if the user may sign in or use the API, or the reason the
+// account is locked
+func (u *User) CanAuthenticate() error {
+	if u.IsDisabled() {
+		return ErrUserDisabled
+	}
+	if u.PasswordResetRequired {
+		return ErrPasswordResetRequired
+	}
 	return nil
 }
 
 // SetPassword hashes and sets the user's password
 func (u *User) SetPassword(password string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
 		return err
 	}
-	u.PasswordHash = string(hashedPassword)
+	u.PasswordHash = hashedPassword
 	return nil
 }
 
 // CheckPassword verifies if the provided password matches the stored hash
 func (u *User) CheckPassword(password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password))
-	return err == nil
+	return utils.CheckPassword(password, u.PasswordHash) == nil
 }
 
 // UpdateLastLogin updates the last login timestamp
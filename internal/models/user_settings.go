@@ -0,0 +1,85 @@
+package models
+
+import (
+	"strings"
+	"time"
+	// Time zones are validated against the embedded database, as slim images don't ship one
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+)
+
+// UserSettings are a user's preferences: the currency overviews are shown in and new
+// portfolios default to, their time zone and locale, the cost basis method new portfolios
+// default to, and which notifications they receive. Users who never saved settings get
+// DefaultUserSettings. Settings are kept in the public schema, next to their users.
+type UserSettings struct {
+	UserID                 uuid.UUID       `gorm:"type:uuid;primaryKey" json:"user_id"`
+	DisplayCurrency        string          `gorm:"type:varchar(3);not null" json:"display_currency"`
+	Timezone               string          `gorm:"type:varchar(64);not null" json:"timezone"`
+	Locale                 string          `gorm:"type:varchar(35);not null" json:"locale"`
+	DefaultCostBasisMethod CostBasisMethod `gorm:"type:varchar(20);not null" json:"default_cost_basis_method"`
+	// Notification preferences: whether each kind of notification is sent at all, and
+	// whether notifications are pushed to the user's devices besides being shown in the app
+	NotifyCorporateActions bool      `gorm:"not null" json:"notify_corporate_actions"`
+	NotifyImports          bool      `gorm:"not null" json:"notify_imports"`
+	NotifyReports          bool      `gorm:"not null" json:"notify_reports"`
+	PushNotifications      bool      `gorm:"not null" json:"push_notifications"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for the UserSettings model
+func (UserSettings) TableName() string {
+	return "user_settings"
+}
+
+// DefaultUserSettings returns the settings of a user who never saved any
+func DefaultUserSettings(userID uuid.UUID) *UserSettings {
+	return &UserSettings{
+		UserID:                 userID,
+		DisplayCurrency:        "USD",
+		Timezone:               "UTC",
+		Locale:                 "en-US",
+		DefaultCostBasisMethod: CostBasisFIFO,
+		NotifyCorporateActions: true,
+		NotifyImports:          true,
+		NotifyReports:          true,
+		PushNotifications:      true,
+	}
+}
+
+// Validate checks the settings, normalizing the currency to upper case and the locale to its
+// canonical BCP 47 form
+func (s *UserSettings) Validate() error {
+	s.DisplayCurrency = strings.ToUpper(s.DisplayCurrency)
+	if len(s.DisplayCurrency) != 3 {
+		return ErrInvalidCurrency
+	}
+	if _, err := time.LoadLocation(s.Timezone); err != nil || s.Timezone == "" || s.Timezone == "Local" {
+		return ErrInvalidTimezone
+	}
+	tag, err := language.Parse(s.Locale)
+	if err != nil {
+		return ErrInvalidLocale
+	}
+	s.Locale = tag.String()
+	if !(&Portfolio{CostBasisMethod: s.DefaultCostBasisMethod}).isValidCostBasisMethod() {
+		return ErrInvalidCostBasisMethod
+	}
+	return nil
+}
+
+// Notifies reports whether the user wants notifications of type t
+func (s *UserSettings) Notifies(t NotificationType) bool {
+	switch t {
+	case NotificationTypeCorporateAction:
+		return s.NotifyCorporateActions
+	case NotificationTypeImportCompleted, NotificationTypeImportFailed:
+		return s.NotifyImports
+	case NotificationTypeReportGenerated:
+		return s.NotifyReports
+	default:
+		return true
+	}
+}
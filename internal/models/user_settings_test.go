@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSettings_Validate(t *testing.T) {
+	settings := DefaultUserSettings(uuid.New())
+	require.NoError(t, settings.Validate())
+
+	settings.DisplayCurrency = "eur"
+	settings.Timezone = "Europe/Berlin"
+	settings.Locale = "de-de"
+	require.NoError(t, settings.Validate())
+	assert.Equal(t, "EUR", settings.DisplayCurrency)
+	assert.Equal(t, "de-DE", settings.Locale)
+
+	tests := []struct {
+		name   string
+		modify func(*UserSettings)
+		err    error
+	}{
+		{"currency", func(s *UserSettings) { s.DisplayCurrency = "EURO" }, ErrInvalidCurrency},
+		{"unknown time zone", func(s *UserSettings) { s.Timezone = "Europe/Atlantis" }, ErrInvalidTimezone},
+		{"local time zone", func(s *UserSettings) { s.Timezone = "Local" }, ErrInvalidTimezone},
+		{"locale", func(s *UserSettings) { s.Locale = "not a locale" }, ErrInvalidLocale},
+		{"cost basis method", func(s *UserSettings) { s.DefaultCostBasisMethod = "AVERAGE" }, ErrInvalidCostBasisMethod},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := DefaultUserSettings(uuid.New())
+			tt.modify(settings)
+			assert.Equal(t, tt.err, settings.Validate())
+		})
+	}
+}
+
+func TestUserSettings_Notifies(t *testing.T) {
+	settings := DefaultUserSettings(uuid.New())
+	settings.NotifyImports = false
+
+	assert.True(t, settings.Notifies(NotificationTypeCorporateAction))
+	assert.False(t, settings.Notifies(NotificationTypeImportCompleted))
+	assert.False(t, settings.Notifies(NotificationTypeImportFailed))
+	assert.True(t, settings.Notifies(NotificationTypeReportGenerated))
+}
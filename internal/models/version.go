@@ -0,0 +1,20 @@
+package models
+
+import "fmt"
+
+// VersionConflictError is returned when an update was made against a version of a record
+// that has since been changed. Portfolios and transactions carry a version that each update
+// increments, so two clients editing the same record can't silently overwrite each other.
+type VersionConflictError struct {
+	CurrentVersion int
+}
+
+// Error implements the error interface
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("%s: the current version is %d", ErrVersionConflict, e.CurrentVersion)
+}
+
+// Unwrap lets errors.Is match ErrVersionConflict
+func (e *VersionConflictError) Unwrap() error {
+	return ErrVersionConflict
+}
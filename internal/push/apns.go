@@ -0,0 +1,19 @@
+package push
+
+import (
+	"context"
+	"log"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// APNsStubSender stands in for Apple Push Notification service delivery, which needs a
+// signing key from an Apple developer account. It logs the messages it would send, so iOS
+// apps can register devices before delivery is set up.
+type APNsStubSender struct{}
+
+// Send logs the message and reports the platform unsupported, leaving the device registered
+func (APNsStubSender) Send(ctx context.Context, device *models.PushDevice, message Message) error {
+	log.Printf("APNs delivery is not configured; not sending %q to device %s", message.Title, device.ID)
+	return ErrPlatformUnsupported
+}
@@ -0,0 +1,34 @@
+// Package push delivers notifications to the browsers and phones users registered. Web Push
+// messages are encrypted for the browser and signed with the server's VAPID key; APNs is a
+// stub until the server holds Apple credentials.
+package push
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+var (
+	// ErrSubscriptionGone means the push service no longer accepts messages for a device,
+	// which should be forgotten
+	ErrSubscriptionGone = errors.New("push subscription has expired or was removed")
+	// ErrPlatformUnsupported means no sender delivers to the device's platform
+	ErrPlatformUnsupported = errors.New("push platform is not supported")
+)
+
+// Message is the payload a device receives, which a service worker or app shows as a
+// notification
+type Message struct {
+	NotificationID string `json:"notification_id"`
+	Type           string `json:"type"`
+	Title          string `json:"title"`
+	Body           string `json:"body"`
+	PortfolioID    string `json:"portfolio_id,omitempty"`
+}
+
+// Sender delivers messages to the devices of one platform
+type Sender interface {
+	Send(ctx context.Context, device *models.PushDevice, message Message) error
+}
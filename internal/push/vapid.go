@@ -0,0 +1,100 @@
+package push
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidTokenValidity is how long a VAPID token signed for a push service is valid; push
+// services refuse tokens valid for more than a day
+const vapidTokenValidity = 12 * time.Hour
+
+// VAPIDKeys is the key pair the server identifies itself to Web Push services with
+// (RFC 8292), and the contact URL sent along
+type VAPIDKeys struct {
+	privateKey *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+}
+
+// ParseVAPIDKeys parses a base64url P-256 key pair, as generated by web-push tools. The
+// public key must match the private key, and subject must be a mailto: or https: URL.
+func ParseVAPIDKeys(publicKey, privateKey, subject string) (*VAPIDKeys, error) {
+	raw, err := decodeKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+
+	point := key.PublicKey().Bytes()
+	if publicKey != "" {
+		configured, err := decodeKey(publicKey)
+		if err != nil || string(configured) != string(point) {
+			return nil, errors.New("VAPID public key doesn't match the private key")
+		}
+	}
+
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, fmt.Errorf("VAPID subject must be a mailto: or https: URL, got %q", subject)
+	}
+
+	// The uncompressed point is 0x04 followed by the X and Y coordinates
+	signingKey := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(point[1:33]),
+			Y:     new(big.Int).SetBytes(point[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+
+	return &VAPIDKeys{
+		privateKey: signingKey,
+		publicKey:  base64.RawURLEncoding.EncodeToString(point),
+		subject:    subject,
+	}, nil
+}
+
+// PublicKey returns the base64url public key browsers subscribe with, as their
+// applicationServerKey
+func (k *VAPIDKeys) PublicKey() string {
+	return k.publicKey
+}
+
+// authorization returns the Authorization header of a request to a push endpoint
+func (k *VAPIDKeys) authorization(endpoint string, now time.Time) (string, error) {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": target.Scheme + "://" + target.Host,
+		"exp": now.Add(vapidTokenValidity).Unix(),
+		"sub": k.subject,
+	})
+	signed, err := token.SignedString(k.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	return "vapid t=" + signed + ", k=" + k.publicKey, nil
+}
+
+// decodeKey decodes a base64url key, with or without padding
+func decodeKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
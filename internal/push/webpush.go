@@ -0,0 +1,165 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+const (
+	// webPushRecordSize is the record size of encrypted payloads, which fit in one record
+	webPushRecordSize = 4096
+	// maxWebPushPayload is the largest payload push services are required to accept once
+	// encrypted: 4096 bytes, less the header, the padding delimiter and the GCM tag
+	maxWebPushPayload = 4096 - 86 - 1 - 16
+	// webPushTTL is how long a push service keeps a message for a device that is offline
+	webPushTTL = 24 * time.Hour
+)
+
+// WebPushSender sends Web Push messages (RFC 8030), encrypted for the browser (RFC 8291) and
+// signed with the server's VAPID key
+type WebPushSender struct {
+	keys   *VAPIDKeys
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebPushSender creates a Web Push sender signing with keys
+func NewWebPushSender(keys *VAPIDKeys) *WebPushSender {
+	return &WebPushSender{
+		keys:   keys,
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+	}
+}
+
+// Send posts a message to the device's push endpoint. A push service that has dropped the
+// subscription answers ErrSubscriptionGone.
+func (s *WebPushSender) Send(ctx context.Context, device *models.PushDevice, message Message) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode push message: %w", err)
+	}
+	if len(payload) > maxWebPushPayload {
+		return fmt.Errorf("push message is %d bytes, more than the %d push services accept", len(payload), maxWebPushPayload)
+	}
+
+	body, err := encrypt(payload, device.P256dh, device.Auth)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.keys.authorization(device.Endpoint, s.now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, device.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(webPushTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach push service: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("push service responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// encrypt encrypts a payload for a browser's subscription keys with a fresh key pair and salt
+func encrypt(payload []byte, p256dh, auth string) ([]byte, error) {
+	rawKey, err := decodeKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	userAgentKey, err := ecdh.P256().NewPublicKey(rawKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription key: %w", err)
+	}
+	secret, err := decodeKey(auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription secret: %w", err)
+	}
+
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate push key: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate push salt: %w", err)
+	}
+
+	return encryptWith(payload, userAgentKey, secret, serverKey, salt)
+}
+
+// encryptWith encrypts a payload as a single aes128gcm record (RFC 8188), keyed by the
+// ECDH secret of the server's and the browser's keys and the browser's authentication secret
+func encryptWith(payload []byte, userAgentKey *ecdh.PublicKey, auth []byte, serverKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	shared, err := serverKey.ECDH(userAgentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive push secret: %w", err)
+	}
+	serverPublic := serverKey.PublicKey().Bytes()
+
+	keyInfo := "WebPush: info\x00" + string(userAgentKey.Bytes()) + string(serverPublic)
+	ikm, err := hkdf.Key(sha256.New, shared, auth, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// The header carries the salt, record size and the server's public key
+	header := make([]byte, 0, 21+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+
+	// A 0x02 delimiter marks the last (and only) record
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
@@ -0,0 +1,177 @@
+package push
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func mustDecode(t *testing.T, value string) []byte {
+	t.Helper()
+	decoded, err := decodeKey(value)
+	require.NoError(t, err)
+	return decoded
+}
+
+// TestEncryptWith checks encryption against the example of RFC 8291, Appendix A
+func TestEncryptWith(t *testing.T) {
+	serverKey, err := ecdh.P256().NewPrivateKey(mustDecode(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+	userAgentKey, err := ecdh.P256().NewPublicKey(mustDecode(t,
+		"BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"))
+	require.NoError(t, err)
+
+	body, err := encryptWith(
+		[]byte("When I grow up, I want to be a watermelon"),
+		userAgentKey,
+		mustDecode(t, "BTBZMqHH6r4Tts7J_aSIgg"),
+		serverKey,
+		mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlw"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		base64.RawURLEncoding.EncodeToString(body))
+}
+
+// decrypt decrypts a single-record aes128gcm body the way a browser does
+func decrypt(t *testing.T, body []byte, userAgentKey *ecdh.PrivateKey, auth []byte) []byte {
+	t.Helper()
+	require.Greater(t, len(body), 86)
+	salt := body[:16]
+	assert.Equal(t, uint32(webPushRecordSize), binary.BigEndian.Uint32(body[16:20]))
+	serverPublic := body[21 : 21+int(body[20])]
+
+	serverKey, err := ecdh.P256().NewPublicKey(serverPublic)
+	require.NoError(t, err)
+	shared, err := userAgentKey.ECDH(serverKey)
+	require.NoError(t, err)
+
+	keyInfo := "WebPush: info\x00" + string(userAgentKey.PublicKey().Bytes()) + string(serverPublic)
+	ikm, err := hkdf.Key(sha256.New, shared, auth, keyInfo, 32)
+	require.NoError(t, err)
+	contentKey, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	require.NoError(t, err)
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(contentKey)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, body[21+len(serverPublic):], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+// testVAPIDKeys generates a VAPID key pair
+func testVAPIDKeys(t *testing.T) *VAPIDKeys {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys, err := ParseVAPIDKeys(
+		base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()),
+		"mailto:admin@example.com",
+	)
+	require.NoError(t, err)
+	return keys
+}
+
+func TestWebPushSender_Send(t *testing.T) {
+	keys := testVAPIDKeys(t)
+	userAgentKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, _ = rand.Read(auth)
+
+	var received Message
+	status := http.StatusCreated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "aes128gcm", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "86400", r.Header.Get("TTL"))
+
+		// The VAPID token is signed for the push service's origin
+		authorization := r.Header.Get("Authorization")
+		require.True(t, strings.HasPrefix(authorization, "vapid t="))
+		token, publicKey, _ := strings.Cut(strings.TrimPrefix(authorization, "vapid t="), ", k=")
+		assert.Equal(t, keys.PublicKey(), publicKey)
+		claims := jwt.MapClaims{}
+		_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) {
+			return &keys.privateKey.PublicKey, nil
+		}, jwt.WithValidMethods([]string{"ES256"}))
+		require.NoError(t, err)
+		assert.Equal(t, "http://"+r.Host, claims["aud"])
+		assert.Equal(t, "mailto:admin@example.com", claims["sub"])
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(decrypt(t, body, userAgentKey, auth), &received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sender := NewWebPushSender(keys)
+	device := &models.PushDevice{
+		ID:       uuid.New(),
+		Platform: models.PushPlatformWeb,
+		Endpoint: server.URL + "/push/abc",
+		P256dh:   base64.RawURLEncoding.EncodeToString(userAgentKey.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}
+	message := Message{NotificationID: uuid.NewString(), Type: "CORPORATE_ACTION", Title: "AAPL SPLIT", Body: "Stock split 4 for AAPL"}
+
+	require.NoError(t, sender.Send(context.Background(), device, message))
+	assert.Equal(t, message, received)
+
+	status = http.StatusGone
+	assert.ErrorIs(t, sender.Send(context.Background(), device, message), ErrSubscriptionGone)
+
+	status = http.StatusTooManyRequests
+	err = sender.Send(context.Background(), device, message)
+	assert.ErrorContains(t, err, "status 429")
+}
+
+func TestParseVAPIDKeys(t *testing.T) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	other, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	publicKey := base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes())
+	privateKey := base64.RawURLEncoding.EncodeToString(key.Bytes())
+
+	keys, err := ParseVAPIDKeys("", privateKey, "https://example.com/contact")
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, keys.PublicKey())
+
+	// Padded keys are accepted too
+	_, err = ParseVAPIDKeys(publicKey+"=", base64.URLEncoding.EncodeToString(key.Bytes()), "mailto:admin@example.com")
+	assert.NoError(t, err)
+
+	_, err = ParseVAPIDKeys(base64.RawURLEncoding.EncodeToString(other.PublicKey().Bytes()), privateKey, "mailto:admin@example.com")
+	assert.ErrorContains(t, err, "doesn't match")
+	_, err = ParseVAPIDKeys(publicKey, "not a key", "mailto:admin@example.com")
+	assert.ErrorContains(t, err, "invalid VAPID private key")
+	_, err = ParseVAPIDKeys(publicKey, privateKey, "admin@example.com")
+	assert.ErrorContains(t, err, "mailto:")
+}
@@ -0,0 +1,76 @@
+package reports
+
+import (
+	"html/template"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/i18n"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// formatter writes the numbers and dates of a report in its reader's locale and time zone
+type formatter struct {
+	i18n.Formatter
+}
+
+// newFormatter returns the formatter of a statement's locale and time zone
+func newFormatter(statement *dto.Statement) formatter {
+	return formatter{i18n.NewFormatter(statement.Locale, statement.Timezone)}
+}
+
+// funcs returns the formatting functions of the HTML templates
+func (f formatter) funcs() template.FuncMap {
+	return template.FuncMap{
+		"date":           f.Date,
+		"dateTime":       f.DateTime,
+		"money":          f.Amount,
+		"optionalMoney":  f.optionalMoney,
+		"quantity":       f.Quantity,
+		"percent":        f.optionalPercent,
+		"type":           formatType,
+		"incomeCategory": formatIncomeCategory,
+	}
+}
+
+// optionalMoney writes an amount, or a dash when it is unknown
+func (f formatter) optionalMoney(amount *decimal.Decimal) string {
+	if amount == nil {
+		return "-"
+	}
+	return f.Amount(*amount)
+}
+
+// optionalPercent writes a percentage with two decimal places, or a dash when it is unknown
+func (f formatter) optionalPercent(percent *decimal.Decimal) string {
+	if percent == nil {
+		return "-"
+	}
+	return f.Percent(*percent)
+}
+
+// formatType writes a transaction type in words, e.g. DIVIDEND_REINVEST as Dividend reinvest
+func formatType(txType models.TransactionType) string {
+	words := strings.ToLower(strings.ReplaceAll(string(txType), "_", " "))
+	if words == "" {
+		return ""
+	}
+	words = strings.Replace(words, "rsu", "RSU", 1)
+	words = strings.Replace(words, "espp", "ESPP", 1)
+	words = strings.Replace(words, "adr fee", "ADR fee", 1)
+	words = strings.Replace(words, "fx fee", "FX fee", 1)
+	return strings.ToUpper(words[:1]) + words[1:]
+}
+
+// formatIncomeCategory writes an income category, spelling out the dividend and income
+// transaction types that income is reported under unless given a category of its own
+func formatIncomeCategory(category string) string {
+	txType := models.TransactionType(category)
+	if category == models.IncomeCategoryDividend || slices.Contains(models.IncomeTransactionTypes, txType) {
+		return formatType(txType)
+	}
+	return category
+}
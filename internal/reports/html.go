@@ -0,0 +1,45 @@
+package reports
+
+import (
+	"embed"
+	"html/template"
+	"io"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+//go:embed templates/*.html
+var templateFiles embed.FS
+
+// templates are the HTML report templates. They are parsed with the formatting functions of
+// the default locale, which each rendering replaces with those of its reader's.
+var templates = template.Must(template.New("").
+	Funcs(newFormatter(&dto.Statement{}).funcs()).
+	ParseFS(templateFiles, "templates/*.html"))
+
+// htmlRenderer renders reports as standalone HTML pages, for previewing them in a browser
+type htmlRenderer struct{}
+
+// NewHTMLRenderer creates a renderer of HTML documents
+func NewHTMLRenderer() ReportRenderer {
+	return htmlRenderer{}
+}
+
+// RenderStatement writes a portfolio statement as an HTML page
+func (htmlRenderer) RenderStatement(w io.Writer, statement *dto.Statement) error {
+	localized, err := templates.Clone()
+	if err != nil {
+		return err
+	}
+	return localized.Funcs(newFormatter(statement).funcs()).ExecuteTemplate(w, "statement.html", statement)
+}
+
+// ContentType returns the media type of HTML documents
+func (htmlRenderer) ContentType() string {
+	return "text/html; charset=utf-8"
+}
+
+// FileExtension returns the file name extension of HTML documents
+func (htmlRenderer) FileExtension() string {
+	return "html"
+}
@@ -0,0 +1,209 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// US Letter pages, in points
+const (
+	pdfPageWidth  = 612.0
+	pdfPageHeight = 792.0
+)
+
+// pdfFont is one of the standard fonts every PDF reader provides, so none are embedded
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota
+	fontBold
+)
+
+// pdfFontNames are the resource names and base fonts of the standard fonts used
+var pdfFontNames = [...]struct{ resource, base string }{
+	fontRegular: {"F1", "Helvetica"},
+	fontBold:    {"F2", "Helvetica-Bold"},
+}
+
+// pdfDocument builds a PDF document of text and lines on US Letter pages. Text is written
+// in WinAnsiEncoding; characters outside Latin-1 are replaced with '?'.
+type pdfDocument struct {
+	title   string
+	created time.Time
+	pages   []*bytes.Buffer
+}
+
+// newPDFDocument creates a document without pages
+func newPDFDocument(title string, created time.Time) *pdfDocument {
+	return &pdfDocument{title: title, created: created}
+}
+
+// addPage starts a new page and returns its number, counting from 1
+func (d *pdfDocument) addPage() int {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	return len(d.pages)
+}
+
+// text writes s on page with its baseline starting at x, y from the bottom left corner
+func (d *pdfDocument) text(page int, x, y float64, font pdfFont, size float64, s string) {
+	fmt.Fprintf(d.pages[page-1], "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		pdfFontNames[font].resource, size, x, y, pdfEscape(s))
+}
+
+// line draws a line of width points from x1, y1 to x2, y2 on page
+func (d *pdfDocument) line(page int, x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.pages[page-1], "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+// fill paints a rectangle in a shade of gray, from 0 (black) to 1 (white), on page
+func (d *pdfDocument) fill(page int, x, y, width, height, gray float64) {
+	fmt.Fprintf(d.pages[page-1], "q %.2f g %.2f %.2f %.2f %.2f re f Q\n", gray, x, y, width, height)
+}
+
+// writeTo writes the document as a PDF file
+func (d *pdfDocument) writeTo(w io.Writer) error {
+	var out bytes.Buffer
+	var offsets []int
+
+	// Objects are numbered from 1 in the order they are written
+	beginObject := func() int {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n", len(offsets))
+		return len(offsets)
+	}
+	endObject := func() {
+		out.WriteString("endobj\n")
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: catalog, 2: page tree, 3+: fonts, then each page followed by its contents
+	const catalogObject, pagesObject, firstFontObject = 1, 2, 3
+	firstPageObject := firstFontObject + len(pdfFontNames)
+
+	beginObject()
+	fmt.Fprintf(&out, "<< /Type /Catalog /Pages %d 0 R >>\n", pagesObject)
+	endObject()
+
+	beginObject()
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageObject+2*i)
+	}
+	fmt.Fprintf(&out, "<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %.0f %.0f] >>\n",
+		strings.Join(kids, " "), len(d.pages), pdfPageWidth, pdfPageHeight)
+	endObject()
+
+	fonts := make([]string, len(pdfFontNames))
+	for i, font := range pdfFontNames {
+		beginObject()
+		fmt.Fprintf(&out, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\n", font.base)
+		endObject()
+		fonts[i] = fmt.Sprintf("/%s %d 0 R", font.resource, firstFontObject+i)
+	}
+
+	for i, content := range d.pages {
+		beginObject()
+		fmt.Fprintf(&out, "<< /Type /Page /Parent %d 0 R /Resources << /Font << %s >> >> /Contents %d 0 R >>\n",
+			pagesObject, strings.Join(fonts, " "), firstPageObject+2*i+1)
+		endObject()
+
+		beginObject()
+		fmt.Fprintf(&out, "<< /Length %d >>\nstream\n", content.Len())
+		out.Write(content.Bytes())
+		out.WriteString("endstream\n")
+		endObject()
+	}
+
+	infoObject := beginObject()
+	fmt.Fprintf(&out, "<< /Title (%s) /Producer (portfolios) /CreationDate (D:%s) >>\n",
+		pdfEscape(d.title), d.created.UTC().Format("20060102150405Z"))
+	endObject()
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, catalogObject, infoObject, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pdfEscape encodes s as the contents of a PDF string in WinAnsiEncoding
+func pdfEscape(s string) string {
+	var escaped strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			// WinAnsiEncoding matches Latin-1 in these ranges
+			escaped.WriteByte(byte(r))
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+	return escaped.String()
+}
+
+// Advance widths of the printable ASCII characters, from space to tilde, in thousandths of
+// the font size, from the fonts' Adobe font metrics
+var (
+	helveticaWidths = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	helveticaBoldWidths = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// textWidth returns the width of s in points when written in font at size. Characters
+// outside printable ASCII are assumed to be as wide as a digit.
+func textWidth(s string, font pdfFont, size float64) float64 {
+	widths := &helveticaWidths
+	if font == fontBold {
+		widths = &helveticaBoldWidths
+	}
+
+	total := 0
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7f {
+			total += widths[r-0x20]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// fitText shortens s with an ellipsis of dots until it is at most width points wide
+func fitText(s string, font pdfFont, size, width float64) string {
+	if textWidth(s, font, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if shortened := string(runes) + "..."; textWidth(shortened, font, size) <= width {
+			return shortened
+		}
+	}
+	return ""
+}
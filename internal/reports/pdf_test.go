@@ -0,0 +1,31 @@
+package reports
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+func TestPDFEscape(t *testing.T) {
+	assert.Equal(t, `a \(b\) \\ c`, pdfEscape(`a (b) \ c`))
+	assert.Equal(t, "caf\xe9 ?", pdfEscape("café €"))
+}
+
+func TestFitText(t *testing.T) {
+	// "Dividend" is 35 points wide at 9 points, "Divid..." 27.5
+	assert.Equal(t, "Dividend", fitText("Dividend", fontRegular, 9, 40))
+	assert.Equal(t, "Divid...", fitText("Dividend", fontRegular, 9, 30))
+	assert.Equal(t, "", fitText("Dividend", fontRegular, 9, 5))
+	assert.Greater(t, textWidth("Dividend", fontBold, 9), textWidth("Dividend", fontRegular, 9))
+}
+
+func TestGroupThousands(t *testing.T) {
+	f := newFormatter(&dto.Statement{})
+	assert.Equal(t, "1,234,567.89", f.Amount(decimal.RequireFromString("1234567.891")))
+	assert.Equal(t, "-1,000.00", f.Amount(decimal.NewFromInt(-1000)))
+	assert.Equal(t, "999", f.Quantity(decimal.NewFromInt(999)))
+	assert.Equal(t, "0.5", f.Quantity(decimal.RequireFromString("0.50")))
+}
@@ -0,0 +1,33 @@
+// Package reports renders reports built by the services as documents for people to read.
+package reports
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+// ReportRenderer renders reports in one document format
+type ReportRenderer interface {
+	// RenderStatement writes a portfolio statement to w
+	RenderStatement(w io.Writer, statement *dto.Statement) error
+
+	// ContentType returns the media type of the documents this renderer writes
+	ContentType() string
+
+	// FileExtension returns the file name extension of the documents, without the dot
+	FileExtension() string
+}
+
+// NewRenderer returns the renderer for a statement format. An empty format renders PDF.
+func NewRenderer(format dto.StatementFormat) (ReportRenderer, error) {
+	switch format {
+	case dto.StatementFormatPDF, "":
+		return NewPDFRenderer(), nil
+	case dto.StatementFormatHTML:
+		return NewHTMLRenderer(), nil
+	default:
+		return nil, fmt.Errorf("unsupported report format: %s", format)
+	}
+}
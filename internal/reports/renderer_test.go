@@ -0,0 +1,169 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func testStatement(transactions int) *dto.Statement {
+	twr := decimal.RequireFromString("4.5678")
+	start := decimal.NewFromInt(10000)
+	price := decimal.RequireFromString("101.5")
+
+	statement := &dto.Statement{
+		Portfolio:   dto.CertifiedPortfolio{ID: "p1", Name: "Smith & Sons (Retirement)", BaseCurrency: "USD"},
+		Period:      "2024-Q4",
+		Title:       "Quarterly statement, Q4 2024",
+		StartDate:   time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+		GeneratedAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Performance: dto.StatementPerformance{
+			StartingValue: &start,
+			NetCashFlow:   decimal.NewFromInt(1234567),
+			TWRPercent:    &twr,
+		},
+		Holdings: []dto.StatementHolding{{
+			Symbol:      "VTI",
+			Quantity:    decimal.RequireFromString("12.5"),
+			CostBasis:   decimal.NewFromInt(1250),
+			AverageCost: decimal.NewFromInt(100),
+		}},
+		Income: dto.StatementIncome{
+			Total:    decimal.RequireFromString("12.3"),
+			BySymbol: []dto.StatementIncomeLine{{Symbol: "VTI", Amount: decimal.RequireFromString("12.3"), Payments: 1}},
+			ByCategory: []dto.StatementIncomeCategory{
+				{Category: "DIVIDEND", Amount: decimal.RequireFromString("10"), Payments: 1},
+				{Category: "LENDING_INCOME", Amount: decimal.RequireFromString("2"), Payments: 1},
+				{Category: "Cash sweep", Amount: decimal.RequireFromString("0.3"), Payments: 1},
+			},
+		},
+	}
+	for i := 0; i < transactions; i++ {
+		statement.Transactions = append(statement.Transactions, dto.StatementTransaction{
+			Date:     time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i%90),
+			Type:     models.TransactionTypeDividendReinvest,
+			Symbol:   fmt.Sprintf("SYM%d", i),
+			Quantity: decimal.NewFromInt(2),
+			Price:    &price,
+			Amount:   decimal.NewFromInt(203),
+		})
+	}
+	return statement
+}
+
+func TestNewRenderer(t *testing.T) {
+	for format, contentType := range map[dto.StatementFormat]string{
+		"":                      "application/pdf",
+		dto.StatementFormatPDF:  "application/pdf",
+		dto.StatementFormatHTML: "text/html; charset=utf-8",
+	} {
+		renderer, err := NewRenderer(format)
+		require.NoError(t, err)
+		assert.Equal(t, contentType, renderer.ContentType())
+	}
+
+	_, err := NewRenderer("docx")
+	assert.Error(t, err)
+}
+
+func TestHTMLRenderer_RenderStatement(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, NewHTMLRenderer().RenderStatement(&out, testStatement(1)))
+	html := out.String()
+
+	assert.Contains(t, html, "<title>Smith &amp; Sons (Retirement) - Quarterly statement, Q4 2024</title>")
+	assert.Contains(t, html, "Oct 1, 2024 to Dec 31, 2024")
+	assert.Contains(t, html, "1,234,567.00")
+	assert.Contains(t, html, "4.57%")
+	assert.Contains(t, html, "Dividend reinvest")
+	assert.Contains(t, html, "<td>Lending income</td>")
+	assert.Contains(t, html, "<td>Cash sweep</td>")
+	assert.NotContains(t, html, "No transactions in this period.")
+	assert.Contains(t, html, "Generated Jan 2, 2025 03:04 UTC.")
+
+	t.Run("in the reader's locale and time zone", func(t *testing.T) {
+		statement := testStatement(1)
+		statement.Locale, statement.Timezone = "de-DE", "Europe/Berlin"
+
+		var out bytes.Buffer
+		require.NoError(t, NewHTMLRenderer().RenderStatement(&out, statement))
+		html := out.String()
+
+		assert.Contains(t, html, "01.10.2024 to 31.12.2024")
+		assert.Contains(t, html, "1.234.567,00")
+		assert.Contains(t, html, "4,57%")
+		assert.Contains(t, html, "Generated 02.01.2025 04:04 CET.")
+
+		// Rendering in one locale leaves the others as they were
+		out.Reset()
+		require.NoError(t, NewHTMLRenderer().RenderStatement(&out, testStatement(1)))
+		assert.Contains(t, out.String(), "1,234,567.00")
+	})
+}
+
+func TestPDFRenderer_RenderStatement(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, NewPDFRenderer().RenderStatement(&out, testStatement(1)))
+	pdf := out.String()
+
+	assert.True(t, strings.HasPrefix(pdf, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(pdf, "%%EOF\n"))
+	assert.Contains(t, pdf, "/Count 1")
+	assert.Contains(t, pdf, `(Smith & Sons \(Retirement\)) Tj`)
+	assert.Contains(t, pdf, "(1,234,567.00) Tj")
+	assert.Contains(t, pdf, "(Page 1 of 1) Tj")
+	assertValidXref(t, pdf)
+
+	t.Run("in the reader's locale", func(t *testing.T) {
+		statement := testStatement(1)
+		statement.Locale = "fr-FR"
+
+		var out bytes.Buffer
+		require.NoError(t, NewPDFRenderer().RenderStatement(&out, statement))
+		// French groups digits with a narrow no-break space, which Latin-1 writes as a wide one
+		assert.Contains(t, out.String(), "(1\xa0234\xa0567,00) Tj")
+	})
+
+	t.Run("breaks long tables across pages", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, NewPDFRenderer().RenderStatement(&out, testStatement(120)))
+		pdf := out.String()
+
+		assert.Contains(t, pdf, "/Count 4")
+		assert.Contains(t, pdf, "(Page 4 of 4) Tj")
+		assert.Contains(t, pdf, "(SYM119) Tj")
+		assertValidXref(t, pdf)
+	})
+}
+
+// assertValidXref checks that every entry of the cross-reference table points at the object
+// it numbers
+func assertValidXref(t *testing.T, pdf string) {
+	t.Helper()
+
+	var xref int
+	_, err := fmt.Sscanf(pdf[strings.LastIndex(pdf, "startxref\n"):], "startxref\n%d", &xref)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(pdf[xref:], "xref\n"))
+
+	lines := strings.Split(pdf[xref:], "\n")
+	var count int
+	_, err = fmt.Sscanf(lines[1], "0 %d", &count)
+	require.NoError(t, err)
+	for object := 1; object < count; object++ {
+		var offset int
+		_, err := fmt.Sscanf(lines[2+object], "%d", &offset)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj\n", object)), "object %d", object)
+	}
+}
@@ -0,0 +1,234 @@
+package reports
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+// Layout of PDF pages, in points
+const (
+	pdfMargin       = 50.0
+	pdfBodySize     = 9.0
+	pdfRowHeight    = 15.0
+	pdfHeadingSize  = 12.0
+	pdfTitleSize    = 18.0
+	pdfFooterHeight = 30.0
+)
+
+// pdfRenderer renders reports as PDF documents
+type pdfRenderer struct{}
+
+// NewPDFRenderer creates a renderer of PDF documents
+func NewPDFRenderer() ReportRenderer {
+	return pdfRenderer{}
+}
+
+// ContentType returns the media type of PDF documents
+func (pdfRenderer) ContentType() string {
+	return "application/pdf"
+}
+
+// FileExtension returns the file name extension of PDF documents
+func (pdfRenderer) FileExtension() string {
+	return "pdf"
+}
+
+// pdfColumn is a column of a table in a PDF report
+type pdfColumn struct {
+	title  string
+	width  float64
+	number bool // Numbers are aligned right
+}
+
+// pdfLayout places report content down the pages of a document, starting a new page when
+// the current one is full
+type pdfLayout struct {
+	doc  *pdfDocument
+	page int
+	y    float64 // Baseline of the next line
+}
+
+// newPDFLayout starts a document on its first page
+func newPDFLayout(doc *pdfDocument) *pdfLayout {
+	l := &pdfLayout{doc: doc}
+	l.newPage()
+	return l
+}
+
+// newPage moves to the top of a new page
+func (l *pdfLayout) newPage() {
+	l.page = l.doc.addPage()
+	l.y = pdfPageHeight - pdfMargin
+}
+
+// ensure starts a new page unless height points fit above the footer
+func (l *pdfLayout) ensure(height float64) {
+	if l.y-height < pdfMargin+pdfFooterHeight {
+		l.newPage()
+	}
+}
+
+// paragraph writes a line of text across the page
+func (l *pdfLayout) paragraph(font pdfFont, size float64, s string) {
+	l.ensure(size * 1.5)
+	l.y -= size
+	l.doc.text(l.page, pdfMargin, l.y, font, size, fitText(s, font, size, pdfPageWidth-2*pdfMargin))
+	l.y -= size * 0.5
+}
+
+// heading starts a section. It moves to a new page unless the heading and the first rows
+// of the section fit on this one.
+func (l *pdfLayout) heading(title string) {
+	l.y -= pdfHeadingSize
+	l.ensure(pdfHeadingSize*2 + 3*pdfRowHeight)
+	l.y -= pdfHeadingSize
+	l.doc.text(l.page, pdfMargin, l.y, fontBold, pdfHeadingSize, title)
+	l.y -= 4
+	l.doc.line(l.page, pdfMargin, l.y, pdfPageWidth-pdfMargin, l.y, 0.75)
+	l.y -= 4
+}
+
+// table writes rows under a bold header row, repeating the header on each new page
+func (l *pdfLayout) table(columns []pdfColumn, rows [][]string) {
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.title
+	}
+
+	l.ensure(2 * pdfRowHeight)
+	l.row(columns, header, fontBold, true)
+	for _, cells := range rows {
+		if l.y-pdfRowHeight < pdfMargin+pdfFooterHeight {
+			l.newPage()
+			l.row(columns, header, fontBold, true)
+		}
+		l.row(columns, cells, fontRegular, false)
+	}
+}
+
+// row writes one row of a table, shortening cells that don't fit in their column
+func (l *pdfLayout) row(columns []pdfColumn, cells []string, font pdfFont, shaded bool) {
+	l.y -= pdfRowHeight
+	if shaded {
+		l.doc.fill(l.page, pdfMargin, l.y, pdfPageWidth-2*pdfMargin, pdfRowHeight, 0.93)
+	}
+
+	baseline := l.y + (pdfRowHeight-pdfBodySize)/2 + 1
+	x := pdfMargin
+	for i, column := range columns {
+		const padding = 4.0
+		text := fitText(cells[i], font, pdfBodySize, column.width-2*padding)
+		textX := x + padding
+		if column.number {
+			textX = x + column.width - padding - textWidth(text, font, pdfBodySize)
+		}
+		l.doc.text(l.page, textX, baseline, font, pdfBodySize, text)
+		x += column.width
+	}
+
+	if !shaded {
+		l.doc.line(l.page, pdfMargin, l.y, pdfPageWidth-pdfMargin, l.y, 0.25)
+	}
+}
+
+// note writes a line of body text, used for sections without rows
+func (l *pdfLayout) note(s string) {
+	l.paragraph(fontRegular, pdfBodySize, s)
+}
+
+// RenderStatement writes a portfolio statement as a PDF document
+func (pdfRenderer) RenderStatement(w io.Writer, statement *dto.Statement) error {
+	f := newFormatter(statement)
+	doc := newPDFDocument(statement.Portfolio.Name+" - "+statement.Title, statement.GeneratedAt)
+	l := newPDFLayout(doc)
+
+	l.paragraph(fontBold, pdfTitleSize, statement.Portfolio.Name)
+	l.paragraph(fontRegular, 11, fmt.Sprintf("%s, %s to %s. Amounts in %s.", statement.Title,
+		f.Date(statement.StartDate), f.Date(statement.EndDate), statement.Portfolio.BaseCurrency))
+
+	performance := statement.Performance
+	l.heading("Performance")
+	l.table([]pdfColumn{{title: "Measure", width: 312}, {title: "Value", width: 200, number: true}}, [][]string{
+		{"Starting value", f.optionalMoney(performance.StartingValue)},
+		{"Net cash flow", f.Amount(performance.NetCashFlow)},
+		{"Investment gain", f.optionalMoney(performance.InvestmentGain)},
+		{"Ending value", f.optionalMoney(performance.EndingValue)},
+		{"Time-weighted return", f.optionalPercent(performance.TWRPercent)},
+	})
+
+	l.heading("Holdings at " + f.Date(statement.EndDate))
+	if len(statement.Holdings) == 0 {
+		l.note("No holdings.")
+	} else {
+		rows := make([][]string, len(statement.Holdings))
+		for i, holding := range statement.Holdings {
+			rows[i] = []string{holding.Symbol, f.Quantity(holding.Quantity),
+				f.Amount(holding.AverageCost), f.Amount(holding.CostBasis)}
+		}
+		l.table([]pdfColumn{
+			{title: "Symbol", width: 152},
+			{title: "Quantity", width: 120, number: true},
+			{title: "Average cost", width: 120, number: true},
+			{title: "Cost basis", width: 120, number: true},
+		}, rows)
+	}
+
+	l.heading("Transactions")
+	if len(statement.Transactions) == 0 {
+		l.note("No transactions in this period.")
+	} else {
+		rows := make([][]string, len(statement.Transactions))
+		for i, tx := range statement.Transactions {
+			rows[i] = []string{f.Date(tx.Date), formatType(tx.Type), tx.Symbol, f.Quantity(tx.Quantity),
+				f.optionalMoney(tx.Price), f.Amount(tx.Commission), f.Amount(tx.Amount)}
+		}
+		l.table([]pdfColumn{
+			{title: "Date", width: 66},
+			{title: "Type", width: 84},
+			{title: "Symbol", width: 62},
+			{title: "Quantity", width: 70, number: true},
+			{title: "Price", width: 70, number: true},
+			{title: "Commission", width: 70, number: true},
+			{title: "Amount", width: 90, number: true},
+		}, rows)
+	}
+
+	l.heading("Income")
+	if len(statement.Income.BySymbol) == 0 {
+		l.note("No income in this period.")
+	} else {
+		rows := make([][]string, 0, len(statement.Income.BySymbol)+1)
+		for _, line := range statement.Income.BySymbol {
+			rows = append(rows, []string{line.Symbol, strconv.Itoa(line.Payments), f.Amount(line.Amount)})
+		}
+		rows = append(rows, []string{"Total", "", f.Amount(statement.Income.Total)})
+		l.table([]pdfColumn{
+			{title: "Symbol", width: 272},
+			{title: "Payments", width: 120, number: true},
+			{title: "Income", width: 120, number: true},
+		}, rows)
+
+		rows = make([][]string, 0, len(statement.Income.ByCategory))
+		for _, line := range statement.Income.ByCategory {
+			rows = append(rows, []string{formatIncomeCategory(line.Category), strconv.Itoa(line.Payments), f.Amount(line.Amount)})
+		}
+		l.table([]pdfColumn{
+			{title: "Category", width: 272},
+			{title: "Payments", width: 120, number: true},
+			{title: "Income", width: 120, number: true},
+		}, rows)
+	}
+
+	// Footers go on once the number of pages is known
+	generated := "Generated " + f.DateTime(statement.GeneratedAt)
+	for page := 1; page <= len(doc.pages); page++ {
+		doc.text(page, pdfMargin, pdfMargin, fontRegular, 8, generated)
+		number := fmt.Sprintf("Page %d of %d", page, len(doc.pages))
+		doc.text(page, pdfPageWidth-pdfMargin-textWidth(number, fontRegular, 8), pdfMargin, fontRegular, 8, number)
+	}
+
+	return doc.writeTo(w)
+}
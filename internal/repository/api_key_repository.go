@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// APIKeyRepository defines the interface for API key data operations
+type APIKeyRepository interface {
+	FindByExternalID(organizationID uuid.UUID, externalID string) (*models.APIKey, error)
+	FindByID(organizationID, id uuid.UUID) (*models.APIKey, error)
+	FindByOrganizationID(organizationID uuid.UUID) ([]*models.APIKey, error)
+	FindByKeyHash(keyHash string) (*models.APIKey, error)
+	CountActive(organizationID uuid.UUID) (int64, error)
+	Save(key *models.APIKey) error
+	TouchLastUsed(id uuid.UUID, usedAt time.Time) error
+	RevokeByUserID(organizationID, userID uuid.UUID, revokedAt time.Time) (int64, error)
+}
+
+// apiKeyRepository implements APIKeyRepository interface
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new APIKeyRepository instance
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// FindByExternalID finds an organization's API key by the identifier its provisioning tool
+// assigned, with the member it acts as
+func (r *apiKeyRepository) FindByExternalID(organizationID uuid.UUID, externalID string) (*models.APIKey, error) {
+	if externalID == "" {
+		return nil, fmt.Errorf("external ID cannot be empty")
+	}
+
+	var key models.APIKey
+	err := r.db.Preload("User").Where("organization_id = ? AND external_id = ?", organizationID, externalID).First(&key).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// FindByKeyHash finds an API key by the hash of its secret
+func (r *apiKeyRepository) FindByKeyHash(keyHash string) (*models.APIKey, error) {
+	if keyHash == "" {
+		return nil, fmt.Errorf("key hash cannot be empty")
+	}
+
+	var key models.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// FindByID finds one of an organization's API keys by ID
+func (r *apiKeyRepository) FindByID(organizationID, id uuid.UUID) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("organization_id = ? AND id = ?", organizationID, id).First(&key).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+
+	return &key, nil
+}
+
+// FindByOrganizationID finds an organization's API keys, revoked ones included, newest first
+func (r *apiKeyRepository) FindByOrganizationID(organizationID uuid.UUID) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	if err := r.db.Where("organization_id = ?", organizationID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to find API keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// CountActive counts an organization's API keys that have not been revoked
+func (r *apiKeyRepository) CountActive(organizationID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.APIKey{}).
+		Where("organization_id = ? AND revoked_at IS NULL", organizationID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count API keys: %w", err)
+	}
+	return count, nil
+}
+
+// Save creates the API key, or updates it if it already exists
+func (r *apiKeyRepository) Save(key *models.APIKey) error {
+	if key == nil {
+		return fmt.Errorf("API key cannot be nil")
+	}
+
+	if key.ID != uuid.Nil {
+		key.UpdatedAt = time.Now().UTC()
+	}
+
+	if err := r.db.Omit("User").Save(key).Error; err != nil {
+		return fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	return nil
+}
+
+// TouchLastUsed records when an API key last authenticated a request
+func (r *apiKeyRepository) TouchLastUsed(id uuid.UUID, usedAt time.Time) error {
+	err := r.db.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", usedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to update API key last use: %w", err)
+	}
+	return nil
+}
+
+// RevokeByUserID revokes the active API keys an organization issued to one of its members,
+// returning how many were revoked
+func (r *apiKeyRepository) RevokeByUserID(organizationID, userID uuid.UUID, revokedAt time.Time) (int64, error) {
+	result := r.db.Model(&models.APIKey{}).
+		Where("organization_id = ? AND user_id = ? AND revoked_at IS NULL", organizationID, userID).
+		Updates(map[string]interface{}{"revoked_at": revokedAt, "updated_at": revokedAt})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke API keys: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// BlackoutRepository defines the interface for employer stock policy, blackout window
+// and override audit data operations
+type BlackoutRepository interface {
+	FindPolicyByPortfolioID(ctx context.Context, portfolioID string) (*models.EmployerStockPolicy, error)
+	SavePolicy(ctx context.Context, policy *models.EmployerStockPolicy) error
+	DeletePolicy(ctx context.Context, portfolioID string) error
+	CreateWindow(ctx context.Context, window *models.BlackoutWindow) error
+	FindWindowByID(ctx context.Context, id string) (*models.BlackoutWindow, error)
+	FindWindowsByPortfolioID(ctx context.Context, portfolioID string) ([]*models.BlackoutWindow, error)
+	DeleteWindow(ctx context.Context, id string) error
+	CreateOverride(ctx context.Context, override *models.BlackoutOverride) error
+	FindOverridesByPortfolioID(ctx context.Context, portfolioID string) ([]*models.BlackoutOverride, error)
+}
+
+// blackoutRepository implements BlackoutRepository interface
+type blackoutRepository struct {
+	db *gorm.DB
+}
+
+// NewBlackoutRepository creates a new BlackoutRepository instance
+func NewBlackoutRepository(db *gorm.DB) BlackoutRepository {
+	return &blackoutRepository{db: db}
+}
+
+// FindPolicyByPortfolioID finds the employer stock policy for a portfolio
+func (r *blackoutRepository) FindPolicyByPortfolioID(ctx context.Context, portfolioID string) (*models.EmployerStockPolicy, error) {
+	if portfolioID == "" {
+		return nil, fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var policy models.EmployerStockPolicy
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ?", pid).First(&policy).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrEmployerStockPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to find employer stock policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// SavePolicy creates the employer stock policy, or updates it if it already exists
+func (r *blackoutRepository) SavePolicy(ctx context.Context, policy *models.EmployerStockPolicy) error {
+	if policy == nil {
+		return fmt.Errorf("employer stock policy cannot be nil")
+	}
+
+	if policy.ID != uuid.Nil {
+		policy.UpdatedAt = time.Now().UTC()
+	}
+
+	if err := r.db.WithContext(ctx).Save(policy).Error; err != nil {
+		return fmt.Errorf("failed to save employer stock policy: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePolicy deletes the employer stock policy for a portfolio
+func (r *blackoutRepository) DeletePolicy(ctx context.Context, portfolioID string) error {
+	if portfolioID == "" {
+		return fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Where("portfolio_id = ?", pid).Delete(&models.EmployerStockPolicy{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete employer stock policy: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ErrEmployerStockPolicyNotFound
+	}
+
+	return nil
+}
+
+// CreateWindow creates a new blackout window
+func (r *blackoutRepository) CreateWindow(ctx context.Context, window *models.BlackoutWindow) error {
+	if window == nil {
+		return fmt.Errorf("blackout window cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(window).Error; err != nil {
+		return fmt.Errorf("failed to create blackout window: %w", err)
+	}
+
+	return nil
+}
+
+// FindWindowByID finds a blackout window by ID
+func (r *blackoutRepository) FindWindowByID(ctx context.Context, id string) (*models.BlackoutWindow, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	windowID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blackout window ID format: %w", err)
+	}
+
+	var window models.BlackoutWindow
+	if err := r.db.WithContext(ctx).Where("id = ?", windowID).First(&window).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrBlackoutWindowNotFound
+		}
+		return nil, fmt.Errorf("failed to find blackout window: %w", err)
+	}
+
+	return &window, nil
+}
+
+// FindWindowsByPortfolioID finds all blackout windows for a portfolio, ordered by start date
+func (r *blackoutRepository) FindWindowsByPortfolioID(ctx context.Context, portfolioID string) ([]*models.BlackoutWindow, error) {
+	if portfolioID == "" {
+		return nil, fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var windows []*models.BlackoutWindow
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ?", pid).
+		Order("start_date ASC").
+		Find(&windows).Error; err != nil {
+		return nil, fmt.Errorf("failed to find blackout windows: %w", err)
+	}
+
+	return windows, nil
+}
+
+// DeleteWindow deletes a blackout window by ID
+func (r *blackoutRepository) DeleteWindow(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	windowID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid blackout window ID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", windowID).Delete(&models.BlackoutWindow{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete blackout window: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ErrBlackoutWindowNotFound
+	}
+
+	return nil
+}
+
+// CreateOverride records a trade made inside a blackout window
+func (r *blackoutRepository) CreateOverride(ctx context.Context, override *models.BlackoutOverride) error {
+	if override == nil {
+		return fmt.Errorf("blackout override cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(override).Error; err != nil {
+		return fmt.Errorf("failed to create blackout override: %w", err)
+	}
+
+	return nil
+}
+
+// FindOverridesByPortfolioID finds the override audit trail for a portfolio, newest first
+func (r *blackoutRepository) FindOverridesByPortfolioID(ctx context.Context, portfolioID string) ([]*models.BlackoutOverride, error) {
+	if portfolioID == "" {
+		return nil, fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var overrides []*models.BlackoutOverride
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ?", pid).
+		Order("created_at DESC").
+		Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to find blackout overrides: %w", err)
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupBlackoutTestDB(t *testing.T) (*gorm.DB, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{},
+		&models.EmployerStockPolicy{}, &models.BlackoutWindow{}, &models.BlackoutOverride{})
+	require.NoError(t, err)
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Test Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	return db, portfolio
+}
+
+func TestBlackoutRepository_Policy(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupBlackoutTestDB(t)
+	repo := NewBlackoutRepository(db)
+
+	_, err := repo.FindPolicyByPortfolioID(ctx, portfolio.ID.String())
+	assert.Equal(t, models.ErrEmployerStockPolicyNotFound, err)
+
+	policy := &models.EmployerStockPolicy{PortfolioID: portfolio.ID, Symbol: "ACME"}
+	require.NoError(t, repo.SavePolicy(ctx, policy))
+	assert.Equal(t, models.BlackoutEnforcementWarn, policy.Enforcement)
+
+	policy.Enforcement = models.BlackoutEnforcementBlock
+	require.NoError(t, repo.SavePolicy(ctx, policy))
+
+	found, err := repo.FindPolicyByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, policy.ID, found.ID)
+	assert.Equal(t, models.BlackoutEnforcementBlock, found.Enforcement)
+
+	require.NoError(t, repo.DeletePolicy(ctx, portfolio.ID.String()))
+	assert.Equal(t, models.ErrEmployerStockPolicyNotFound, repo.DeletePolicy(ctx, portfolio.ID.String()))
+}
+
+func TestBlackoutRepository_Windows(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupBlackoutTestDB(t)
+	repo := NewBlackoutRepository(db)
+
+	q2 := &models.BlackoutWindow{
+		PortfolioID: portfolio.ID,
+		StartDate:   time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2024, 7, 31, 0, 0, 0, 0, time.UTC),
+	}
+	q1 := &models.BlackoutWindow{
+		PortfolioID: portfolio.ID,
+		StartDate:   time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+		Reason:      "Q1 earnings",
+	}
+	require.NoError(t, repo.CreateWindow(ctx, q2))
+	require.NoError(t, repo.CreateWindow(ctx, q1))
+
+	windows, err := repo.FindWindowsByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	require.Len(t, windows, 2)
+	assert.Equal(t, q1.ID, windows[0].ID)
+
+	found, err := repo.FindWindowByID(ctx, q1.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "Q1 earnings", found.Reason)
+
+	require.NoError(t, repo.DeleteWindow(ctx, q1.ID.String()))
+	_, err = repo.FindWindowByID(ctx, q1.ID.String())
+	assert.Equal(t, models.ErrBlackoutWindowNotFound, err)
+	assert.Equal(t, models.ErrBlackoutWindowNotFound, repo.DeleteWindow(ctx, uuid.New().String()))
+}
+
+func TestBlackoutRepository_Overrides(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupBlackoutTestDB(t)
+	repo := NewBlackoutRepository(db)
+
+	override := &models.BlackoutOverride{
+		PortfolioID:     portfolio.ID,
+		WindowID:        uuid.New(),
+		TransactionID:   uuid.New(),
+		UserID:          portfolio.UserID,
+		Symbol:          "ACME",
+		TransactionType: models.TransactionTypeSell,
+		TransactionDate: time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+		Enforcement:     models.BlackoutEnforcementBlock,
+		Reason:          "Pre-cleared 10b5-1 plan sale",
+	}
+	require.NoError(t, repo.CreateOverride(ctx, override))
+
+	overrides, err := repo.FindOverridesByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	assert.Equal(t, "Pre-cleared 10b5-1 plan sale", overrides[0].Reason)
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// BrokerConnectionRepository defines the interface for broker connection data operations
+type BrokerConnectionRepository interface {
+	Create(ctx context.Context, connection *models.BrokerConnection) error
+	FindByID(ctx context.Context, id string) (*models.BrokerConnection, error)
+	FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.BrokerConnection, error)
+	FindAll(ctx context.Context) ([]*models.BrokerConnection, error)
+	Update(ctx context.Context, connection *models.BrokerConnection) error
+	Delete(ctx context.Context, id string) error
+}
+
+// brokerConnectionRepository implements BrokerConnectionRepository interface
+type brokerConnectionRepository struct {
+	db *gorm.DB
+}
+
+// NewBrokerConnectionRepository creates a new BrokerConnectionRepository instance
+func NewBrokerConnectionRepository(db *gorm.DB) BrokerConnectionRepository {
+	return &brokerConnectionRepository{db: db}
+}
+
+// Create creates a new broker connection
+func (r *brokerConnectionRepository) Create(ctx context.Context, connection *models.BrokerConnection) error {
+	if connection == nil {
+		return fmt.Errorf("broker connection cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(connection).Error; err != nil {
+		return fmt.Errorf("failed to create broker connection: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a broker connection by ID. IDs that aren't UUIDs find nothing.
+func (r *brokerConnectionRepository) FindByID(ctx context.Context, id string) (*models.BrokerConnection, error) {
+	connectionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, models.ErrBrokerConnectionNotFound
+	}
+
+	var connection models.BrokerConnection
+	if err := r.db.WithContext(ctx).Where("id = ?", connectionID).First(&connection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrBrokerConnectionNotFound
+		}
+		return nil, fmt.Errorf("failed to find broker connection: %w", err)
+	}
+
+	return &connection, nil
+}
+
+// FindByPortfolioID finds a portfolio's broker connections, oldest first
+func (r *brokerConnectionRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.BrokerConnection, error) {
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var connections []*models.BrokerConnection
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ?", pid).Order("created_at ASC").Find(&connections).Error; err != nil {
+		return nil, fmt.Errorf("failed to find broker connections: %w", err)
+	}
+
+	return connections, nil
+}
+
+// FindAll finds every broker connection, oldest first, for the scheduled sync
+func (r *brokerConnectionRepository) FindAll(ctx context.Context) ([]*models.BrokerConnection, error) {
+	var connections []*models.BrokerConnection
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&connections).Error; err != nil {
+		return nil, fmt.Errorf("failed to find broker connections: %w", err)
+	}
+
+	return connections, nil
+}
+
+// Update updates a broker connection
+func (r *brokerConnectionRepository) Update(ctx context.Context, connection *models.BrokerConnection) error {
+	if connection == nil {
+		return fmt.Errorf("broker connection cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Save(connection).Error; err != nil {
+		return fmt.Errorf("failed to update broker connection: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a broker connection
+func (r *brokerConnectionRepository) Delete(ctx context.Context, id string) error {
+	connectionID, err := uuid.Parse(id)
+	if err != nil {
+		return models.ErrBrokerConnectionNotFound
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", connectionID).Delete(&models.BrokerConnection{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete broker connection: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrBrokerConnectionNotFound
+	}
+
+	return nil
+}
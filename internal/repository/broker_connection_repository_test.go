@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestBrokerConnectionRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.BrokerConnection{}))
+	repo := NewBrokerConnectionRepository(db)
+	ctx := context.Background()
+	portfolioID := uuid.New()
+
+	newConnection := func(portfolioID uuid.UUID, accountID string) *models.BrokerConnection {
+		return &models.BrokerConnection{
+			PortfolioID: portfolioID,
+			UserID:      uuid.New(),
+			Provider:    models.BrokerProviderPlaid,
+			AccountID:   accountID,
+			Credentials: "sealed",
+			SyncFrom:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	first := newConnection(portfolioID, "acc-1")
+	require.NoError(t, repo.Create(ctx, first))
+	assert.Equal(t, models.BrokerConnectionStatusActive, first.Status)
+	second := newConnection(portfolioID, "acc-2")
+	second.CreatedAt = first.CreatedAt.Add(time.Second)
+	require.NoError(t, repo.Create(ctx, second))
+	require.NoError(t, repo.Create(ctx, newConnection(uuid.New(), "acc-3")))
+
+	connections, err := repo.FindByPortfolioID(ctx, portfolioID.String())
+	require.NoError(t, err)
+	require.Len(t, connections, 2)
+	assert.Equal(t, "acc-1", connections[0].AccountID)
+
+	all, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	syncedAt := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	first.Status = models.BrokerConnectionStatusFailed
+	first.LastSyncedAt = &syncedAt
+	first.LastSyncError = "ITEM_LOGIN_REQUIRED"
+	require.NoError(t, repo.Update(ctx, first))
+
+	found, err := repo.FindByID(ctx, first.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.BrokerConnectionStatusFailed, found.Status)
+	assert.Equal(t, "ITEM_LOGIN_REQUIRED", found.LastSyncError)
+	assert.Equal(t, "sealed", found.Credentials)
+
+	require.NoError(t, repo.Delete(ctx, first.ID.String()))
+	_, err = repo.FindByID(ctx, first.ID.String())
+	assert.ErrorIs(t, err, models.ErrBrokerConnectionNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, first.ID.String()), models.ErrBrokerConnectionNotFound)
+
+	_, err = repo.FindByID(ctx, "not-a-uuid")
+	assert.ErrorIs(t, err, models.ErrBrokerConnectionNotFound)
+}
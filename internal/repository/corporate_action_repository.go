@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,13 +13,13 @@ import (
 
 // CorporateActionRepository defines the interface for corporate action data operations
 type CorporateActionRepository interface {
-	Create(action *models.CorporateAction) error
-	FindByID(id string) (*models.CorporateAction, error)
-	FindBySymbol(symbol string) ([]*models.CorporateAction, error)
-	FindBySymbolAndDateRange(symbol string, startDate, endDate time.Time) ([]*models.CorporateAction, error)
-	FindUnapplied() ([]*models.CorporateAction, error)
-	Update(action *models.CorporateAction) error
-	Delete(id string) error
+	Create(ctx context.Context, action *models.CorporateAction) error
+	FindByID(ctx context.Context, id string) (*models.CorporateAction, error)
+	FindBySymbol(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
+	FindBySymbolAndDateRange(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.CorporateAction, error)
+	FindUnapplied(ctx context.Context) ([]*models.CorporateAction, error)
+	Update(ctx context.Context, action *models.CorporateAction) error
+	Delete(ctx context.Context, id string) error
 }
 
 // corporateActionRepository implements CorporateActionRepository interface
@@ -32,12 +33,12 @@ func NewCorporateActionRepository(db *gorm.DB) CorporateActionRepository {
 }
 
 // Create creates a new corporate action in the database
-func (r *corporateActionRepository) Create(action *models.CorporateAction) error {
+func (r *corporateActionRepository) Create(ctx context.Context, action *models.CorporateAction) error {
 	if action == nil {
 		return fmt.Errorf("corporate action cannot be nil")
 	}
 
-	if err := r.db.Create(action).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(action).Error; err != nil {
 		return fmt.Errorf("failed to create corporate action: %w", err)
 	}
 
@@ -45,7 +46,7 @@ func (r *corporateActionRepository) Create(action *models.CorporateAction) error
 }
 
 // FindByID finds a corporate action by ID
-func (r *corporateActionRepository) FindByID(id string) (*models.CorporateAction, error) {
+func (r *corporateActionRepository) FindByID(ctx context.Context, id string) (*models.CorporateAction, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id cannot be empty")
 	}
@@ -56,7 +57,7 @@ func (r *corporateActionRepository) FindByID(id string) (*models.CorporateAction
 	}
 
 	var action models.CorporateAction
-	if err := r.db.Where("id = ?", actionID).First(&action).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", actionID).First(&action).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrCorporateActionNotFound
 		}
@@ -67,13 +68,13 @@ func (r *corporateActionRepository) FindByID(id string) (*models.CorporateAction
 }
 
 // FindBySymbol finds all corporate actions for a symbol, ordered by date descending
-func (r *corporateActionRepository) FindBySymbol(symbol string) ([]*models.CorporateAction, error) {
+func (r *corporateActionRepository) FindBySymbol(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
 	if symbol == "" {
 		return nil, fmt.Errorf("symbol cannot be empty")
 	}
 
 	var actions []*models.CorporateAction
-	if err := r.db.Where("symbol = ?", symbol).
+	if err := r.db.WithContext(ctx).Where("symbol = ?", symbol).
 		Order("date DESC").
 		Find(&actions).Error; err != nil {
 		return nil, fmt.Errorf("failed to find corporate actions: %w", err)
@@ -84,6 +85,7 @@ func (r *corporateActionRepository) FindBySymbol(symbol string) ([]*models.Corpo
 
 // FindBySymbolAndDateRange finds corporate actions for a symbol within a date range
 func (r *corporateActionRepository) FindBySymbolAndDateRange(
+	ctx context.Context,
 	symbol string,
 	startDate, endDate time.Time,
 ) ([]*models.CorporateAction, error) {
@@ -92,7 +94,7 @@ func (r *corporateActionRepository) FindBySymbolAndDateRange(
 	}
 
 	var actions []*models.CorporateAction
-	if err := r.db.Where("symbol = ? AND date >= ? AND date <= ?", symbol, startDate, endDate).
+	if err := r.db.WithContext(ctx).Where("symbol = ? AND date >= ? AND date <= ?", symbol, startDate, endDate).
 		Order("date ASC").
 		Find(&actions).Error; err != nil {
 		return nil, fmt.Errorf("failed to find corporate actions: %w", err)
@@ -102,9 +104,9 @@ func (r *corporateActionRepository) FindBySymbolAndDateRange(
 }
 
 // FindUnapplied finds all corporate actions that haven't been applied yet
-func (r *corporateActionRepository) FindUnapplied() ([]*models.CorporateAction, error) {
+func (r *corporateActionRepository) FindUnapplied(ctx context.Context) ([]*models.CorporateAction, error) {
 	var actions []*models.CorporateAction
-	if err := r.db.Where("applied = ?", false).
+	if err := r.db.WithContext(ctx).Where("applied = ?", false).
 		Order("date ASC").
 		Find(&actions).Error; err != nil {
 		return nil, fmt.Errorf("failed to find unapplied corporate actions: %w", err)
@@ -114,12 +116,12 @@ func (r *corporateActionRepository) FindUnapplied() ([]*models.CorporateAction,
 }
 
 // Update updates an existing corporate action
-func (r *corporateActionRepository) Update(action *models.CorporateAction) error {
+func (r *corporateActionRepository) Update(ctx context.Context, action *models.CorporateAction) error {
 	if action == nil {
 		return fmt.Errorf("corporate action cannot be nil")
 	}
 
-	if err := r.db.Save(action).Error; err != nil {
+	if err := r.db.WithContext(ctx).Save(action).Error; err != nil {
 		return fmt.Errorf("failed to update corporate action: %w", err)
 	}
 
@@ -127,7 +129,7 @@ func (r *corporateActionRepository) Update(action *models.CorporateAction) error
 }
 
 // Delete deletes a corporate action by ID
-func (r *corporateActionRepository) Delete(id string) error {
+func (r *corporateActionRepository) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("id cannot be empty")
 	}
@@ -137,7 +139,7 @@ func (r *corporateActionRepository) Delete(id string) error {
 		return fmt.Errorf("invalid corporate action ID format: %w", err)
 	}
 
-	result := r.db.Where("id = ?", actionID).Delete(&models.CorporateAction{})
+	result := r.db.WithContext(ctx).Where("id = ?", actionID).Delete(&models.CorporateAction{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete corporate action: %w", result.Error)
 	}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -24,6 +25,8 @@ func setupCorporateActionTestDB(t *testing.T) *gorm.DB {
 }
 
 func TestCorporateActionRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
@@ -38,21 +41,25 @@ func TestCorporateActionRepository_Create(t *testing.T) {
 		Applied:     false,
 	}
 
-	err := repo.Create(action)
+	err := repo.Create(ctx, action)
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, action.ID)
 }
 
 func TestCorporateActionRepository_Create_Nil(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
-	err := repo.Create(nil)
+	err := repo.Create(ctx, nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot be nil")
 }
 
 func TestCorporateActionRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
@@ -65,10 +72,10 @@ func TestCorporateActionRepository_FindByID(t *testing.T) {
 		Description: "Split",
 		Applied:     false,
 	}
-	err := repo.Create(action)
+	err := repo.Create(ctx, action)
 	require.NoError(t, err)
 
-	found, err := repo.FindByID(action.ID.String())
+	found, err := repo.FindByID(ctx, action.ID.String())
 	assert.NoError(t, err)
 	assert.Equal(t, action.ID, found.ID)
 	assert.Equal(t, action.Symbol, found.Symbol)
@@ -76,24 +83,30 @@ func TestCorporateActionRepository_FindByID(t *testing.T) {
 }
 
 func TestCorporateActionRepository_FindByID_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
-	_, err := repo.FindByID(uuid.New().String())
+	_, err := repo.FindByID(ctx, uuid.New().String())
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrCorporateActionNotFound, err)
 }
 
 func TestCorporateActionRepository_FindByID_InvalidID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
-	_, err := repo.FindByID("invalid-uuid")
+	_, err := repo.FindByID(ctx, "invalid-uuid")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid")
 }
 
 func TestCorporateActionRepository_FindBySymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
@@ -121,11 +134,11 @@ func TestCorporateActionRepository_FindBySymbol(t *testing.T) {
 		Applied: false,
 	}
 
-	require.NoError(t, repo.Create(action1))
-	require.NoError(t, repo.Create(action2))
-	require.NoError(t, repo.Create(action3))
+	require.NoError(t, repo.Create(ctx, action1))
+	require.NoError(t, repo.Create(ctx, action2))
+	require.NoError(t, repo.Create(ctx, action3))
 
-	actions, err := repo.FindBySymbol("AAPL")
+	actions, err := repo.FindBySymbol(ctx, "AAPL")
 	assert.NoError(t, err)
 	assert.Len(t, actions, 2)
 	// Should be ordered by date DESC
@@ -133,6 +146,8 @@ func TestCorporateActionRepository_FindBySymbol(t *testing.T) {
 }
 
 func TestCorporateActionRepository_FindBySymbolAndDateRange(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
@@ -161,19 +176,21 @@ func TestCorporateActionRepository_FindBySymbolAndDateRange(t *testing.T) {
 		Applied: false,
 	}
 
-	require.NoError(t, repo.Create(action1))
-	require.NoError(t, repo.Create(action2))
-	require.NoError(t, repo.Create(action3))
+	require.NoError(t, repo.Create(ctx, action1))
+	require.NoError(t, repo.Create(ctx, action2))
+	require.NoError(t, repo.Create(ctx, action3))
 
 	startDate := now.AddDate(0, 0, -7)
 	endDate := now.AddDate(0, 0, 1)
 
-	actions, err := repo.FindBySymbolAndDateRange("AAPL", startDate, endDate)
+	actions, err := repo.FindBySymbolAndDateRange(ctx, "AAPL", startDate, endDate)
 	assert.NoError(t, err)
 	assert.Len(t, actions, 2) // Only action2 and action3 should be in range
 }
 
 func TestCorporateActionRepository_FindUnapplied(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
@@ -200,11 +217,11 @@ func TestCorporateActionRepository_FindUnapplied(t *testing.T) {
 		Applied: false,
 	}
 
-	require.NoError(t, repo.Create(action1))
-	require.NoError(t, repo.Create(action2))
-	require.NoError(t, repo.Create(action3))
+	require.NoError(t, repo.Create(ctx, action1))
+	require.NoError(t, repo.Create(ctx, action2))
+	require.NoError(t, repo.Create(ctx, action3))
 
-	actions, err := repo.FindUnapplied()
+	actions, err := repo.FindUnapplied(ctx)
 	assert.NoError(t, err)
 	assert.Len(t, actions, 2) // Only action1 and action3
 	for _, action := range actions {
@@ -213,6 +230,8 @@ func TestCorporateActionRepository_FindUnapplied(t *testing.T) {
 }
 
 func TestCorporateActionRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
@@ -224,7 +243,7 @@ func TestCorporateActionRepository_Update(t *testing.T) {
 		Ratio:   &ratio,
 		Applied: false,
 	}
-	err := repo.Create(action)
+	err := repo.Create(ctx, action)
 	require.NoError(t, err)
 
 	// Update
@@ -232,26 +251,30 @@ func TestCorporateActionRepository_Update(t *testing.T) {
 	newDesc := "Updated description"
 	action.Description = newDesc
 
-	err = repo.Update(action)
+	err = repo.Update(ctx, action)
 	assert.NoError(t, err)
 
 	// Verify update
-	found, err := repo.FindByID(action.ID.String())
+	found, err := repo.FindByID(ctx, action.ID.String())
 	assert.NoError(t, err)
 	assert.True(t, found.Applied)
 	assert.Equal(t, newDesc, found.Description)
 }
 
 func TestCorporateActionRepository_Update_Nil(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
-	err := repo.Update(nil)
+	err := repo.Update(ctx, nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot be nil")
 }
 
 func TestCorporateActionRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
@@ -263,32 +286,36 @@ func TestCorporateActionRepository_Delete(t *testing.T) {
 		Ratio:   &ratio,
 		Applied: false,
 	}
-	err := repo.Create(action)
+	err := repo.Create(ctx, action)
 	require.NoError(t, err)
 
-	err = repo.Delete(action.ID.String())
+	err = repo.Delete(ctx, action.ID.String())
 	assert.NoError(t, err)
 
 	// Verify deletion
-	_, err = repo.FindByID(action.ID.String())
+	_, err = repo.FindByID(ctx, action.ID.String())
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrCorporateActionNotFound, err)
 }
 
 func TestCorporateActionRepository_Delete_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
-	err := repo.Delete(uuid.New().String())
+	err := repo.Delete(ctx, uuid.New().String())
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrCorporateActionNotFound, err)
 }
 
 func TestCorporateActionRepository_Delete_InvalidID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupCorporateActionTestDB(t)
 	repo := NewCorporateActionRepository(db)
 
-	err := repo.Delete("invalid-uuid")
+	err := repo.Delete(ctx, "invalid-uuid")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid")
 }
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// DelegationRepository defines the interface for advisor delegation, delegated portfolio and
+// delegation audit data operations
+type DelegationRepository interface {
+	Create(ctx context.Context, delegation *models.Delegation) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Delegation, error)
+	FindByTokenHash(ctx context.Context, tokenHash string) (*models.Delegation, error)
+	FindForUser(ctx context.Context, userID uuid.UUID, email string) ([]*models.Delegation, error)
+	FindActive(ctx context.Context, advisorID, clientID uuid.UUID) (*models.Delegation, error)
+	ExistsOpen(ctx context.Context, advisorID uuid.UUID, clientEmail string, now time.Time) (bool, error)
+	Save(ctx context.Context, delegation *models.Delegation) error
+	SetPortfolios(ctx context.Context, delegationID uuid.UUID, portfolioIDs []uuid.UUID) error
+	CreateAuditEvent(ctx context.Context, event *models.DelegationAuditEvent) error
+	FindAuditEvents(ctx context.Context, delegationID uuid.UUID, limit int) ([]*models.DelegationAuditEvent, error)
+}
+
+// delegationRepository implements DelegationRepository interface
+type delegationRepository struct {
+	db *gorm.DB
+}
+
+// NewDelegationRepository creates a new DelegationRepository instance
+func NewDelegationRepository(db *gorm.DB) DelegationRepository {
+	return &delegationRepository{db: db}
+}
+
+// Create creates a delegation
+func (r *delegationRepository) Create(ctx context.Context, delegation *models.Delegation) error {
+	if delegation == nil {
+		return fmt.Errorf("delegation cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(delegation).Error; err != nil {
+		return fmt.Errorf("failed to create delegation: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a delegation by ID, with its advisor, client and portfolios
+func (r *delegationRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Delegation, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+// FindByTokenHash finds a delegation by the hash of its consent token, with its advisor,
+// client and portfolios
+func (r *delegationRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*models.Delegation, error) {
+	return r.findOne(ctx, "token_hash = ?", tokenHash)
+}
+
+// FindActive finds the active delegation from a client to an advisor, with its portfolios
+func (r *delegationRepository) FindActive(ctx context.Context, advisorID, clientID uuid.UUID) (*models.Delegation, error) {
+	return r.findOne(ctx, "advisor_id = ? AND client_id = ? AND status = ?", advisorID, clientID, models.DelegationStatusActive)
+}
+
+// findOne finds the first delegation matching the query, with its advisor, client and
+// portfolios
+func (r *delegationRepository) findOne(ctx context.Context, query string, args ...interface{}) (*models.Delegation, error) {
+	var delegation models.Delegation
+	err := r.db.WithContext(ctx).
+		Preload("Advisor").Preload("Client").Preload("Portfolios").
+		Where(query, args...).
+		First(&delegation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrDelegationNotFound
+		}
+		return nil, fmt.Errorf("failed to find delegation: %w", err)
+	}
+
+	return &delegation, nil
+}
+
+// FindForUser finds the delegations a user is the advisor or client of, and the pending
+// requests sent to their email, with their advisors, clients and portfolios, newest first
+func (r *delegationRepository) FindForUser(ctx context.Context, userID uuid.UUID, email string) ([]*models.Delegation, error) {
+	var delegations []*models.Delegation
+	err := r.db.WithContext(ctx).
+		Preload("Advisor").Preload("Client").Preload("Portfolios").
+		Where("advisor_id = ? OR client_id = ? OR (client_email = ? AND status = ?)",
+			userID, userID, email, models.DelegationStatusPending).
+		Order("created_at DESC").
+		Find(&delegations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find delegations: %w", err)
+	}
+
+	return delegations, nil
+}
+
+// ExistsOpen returns true if an advisor has an active delegation from the client with the
+// email, or a request to them the client can still consent to at now
+func (r *delegationRepository) ExistsOpen(ctx context.Context, advisorID uuid.UUID, clientEmail string, now time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Delegation{}).
+		Where("advisor_id = ? AND client_email = ?", advisorID, clientEmail).
+		Where("status = ? OR (status = ? AND expires_at > ?)",
+			models.DelegationStatusActive, models.DelegationStatusPending, now).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check delegations: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Save updates a delegation
+func (r *delegationRepository) Save(ctx context.Context, delegation *models.Delegation) error {
+	if delegation == nil {
+		return fmt.Errorf("delegation cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Save(delegation).Error; err != nil {
+		return fmt.Errorf("failed to save delegation: %w", err)
+	}
+
+	return nil
+}
+
+// SetPortfolios replaces the portfolios delegated under a delegation
+func (r *delegationRepository) SetPortfolios(ctx context.Context, delegationID uuid.UUID, portfolioIDs []uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("delegation_id = ?", delegationID).Delete(&models.DelegationPortfolio{}).Error; err != nil {
+			return fmt.Errorf("failed to clear delegated portfolios: %w", err)
+		}
+		if len(portfolioIDs) == 0 {
+			return nil
+		}
+
+		portfolios := make([]*models.DelegationPortfolio, len(portfolioIDs))
+		for i, id := range portfolioIDs {
+			portfolios[i] = &models.DelegationPortfolio{DelegationID: delegationID, PortfolioID: id}
+		}
+		if err := tx.Create(&portfolios).Error; err != nil {
+			return fmt.Errorf("failed to delegate portfolios: %w", err)
+		}
+		return nil
+	})
+}
+
+// CreateAuditEvent records a request made under a delegation
+func (r *delegationRepository) CreateAuditEvent(ctx context.Context, event *models.DelegationAuditEvent) error {
+	if event == nil {
+		return fmt.Errorf("audit event cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record delegation audit event: %w", err)
+	}
+
+	return nil
+}
+
+// FindAuditEvents finds the latest limit requests made under a delegation, newest first
+func (r *delegationRepository) FindAuditEvents(ctx context.Context, delegationID uuid.UUID, limit int) ([]*models.DelegationAuditEvent, error) {
+	var events []*models.DelegationAuditEvent
+	err := r.db.WithContext(ctx).
+		Where("delegation_id = ?", delegationID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find delegation audit events: %w", err)
+	}
+
+	return events, nil
+}
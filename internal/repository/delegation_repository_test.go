@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupDelegationTestDB(t *testing.T) *gorm.DB {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Delegation{}, &models.DelegationPortfolio{}, &models.DelegationAuditEvent{}))
+	return db
+}
+
+func TestDelegationRepository_Delegations(t *testing.T) {
+	ctx := context.Background()
+	db := setupDelegationTestDB(t)
+	repo := NewDelegationRepository(db)
+	now := time.Now().UTC()
+
+	advisor := createMemberUser(t, db, "advisor@example.com")
+	client := createMemberUser(t, db, "client@example.com")
+
+	delegation := &models.Delegation{
+		AdvisorID:   advisor.ID,
+		ClientEmail: client.Email,
+		Access:      models.DelegationAccessRead,
+		TokenHash:   "hash",
+		ExpiresAt:   now.Add(time.Hour),
+	}
+	require.NoError(t, repo.Create(ctx, delegation))
+	assert.Equal(t, models.DelegationStatusPending, delegation.Status)
+
+	open, err := repo.ExistsOpen(ctx, advisor.ID, client.Email, now)
+	require.NoError(t, err)
+	assert.True(t, open)
+	open, err = repo.ExistsOpen(ctx, advisor.ID, client.Email, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, open, "an expired request is no longer open")
+
+	// The client sees the pending request by email before consenting
+	found, err := repo.FindForUser(ctx, client.ID, client.Email)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.NotNil(t, found[0].Advisor)
+	assert.Equal(t, advisor.Email, found[0].Advisor.Email)
+
+	_, err = repo.FindActive(ctx, advisor.ID, client.ID)
+	assert.Equal(t, models.ErrDelegationNotFound, err)
+
+	delegation.ClientID = &client.ID
+	delegation.Status = models.DelegationStatusActive
+	require.NoError(t, repo.Save(ctx, delegation))
+	first, second := uuid.New(), uuid.New()
+	require.NoError(t, repo.SetPortfolios(ctx, delegation.ID, []uuid.UUID{first, second}))
+
+	active, err := repo.FindActive(ctx, advisor.ID, client.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{first, second}, active.PortfolioIDs())
+
+	// Setting the portfolios replaces them
+	require.NoError(t, repo.SetPortfolios(ctx, delegation.ID, []uuid.UUID{second}))
+	byToken, err := repo.FindByTokenHash(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{second}, byToken.PortfolioIDs())
+	require.NotNil(t, byToken.Client)
+	assert.Equal(t, client.Email, byToken.Client.Email)
+
+	found, err = repo.FindForUser(ctx, advisor.ID, advisor.Email)
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+	stranger := createMemberUser(t, db, "stranger@example.com")
+	found, err = repo.FindForUser(ctx, stranger.ID, stranger.Email)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	_, err = repo.FindByID(ctx, uuid.New())
+	assert.Equal(t, models.ErrDelegationNotFound, err)
+}
+
+func TestDelegationRepository_AuditEvents(t *testing.T) {
+	ctx := context.Background()
+	db := setupDelegationTestDB(t)
+	repo := NewDelegationRepository(db)
+
+	advisor := createMemberUser(t, db, "advisor@example.com")
+	client := createMemberUser(t, db, "client@example.com")
+	delegation := &models.Delegation{
+		AdvisorID: advisor.ID, ClientEmail: client.Email, ClientID: &client.ID,
+		Access: models.DelegationAccessManage, TokenHash: "hash", ExpiresAt: time.Now().UTC(),
+	}
+	require.NoError(t, repo.Create(ctx, delegation))
+
+	start := time.Now().UTC().Add(-time.Hour)
+	for i, method := range []string{"GET", "POST", "DELETE"} {
+		require.NoError(t, repo.CreateAuditEvent(ctx, &models.DelegationAuditEvent{
+			DelegationID: delegation.ID,
+			AdvisorID:    advisor.ID,
+			ClientID:     client.ID,
+			Method:       method,
+			Route:        "/api/v1/portfolios/:id",
+			Path:         "/api/v1/portfolios/1",
+			Status:       200,
+			CreatedAt:    start.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+
+	events, err := repo.FindAuditEvents(ctx, delegation.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "DELETE", events[0].Method)
+	assert.Equal(t, "POST", events[1].Method)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// isUniqueViolation reports whether err is the database rejecting a row that breaks a unique
+// index. The dialect translates its own error codes, so this works on PostgreSQL and SQLite
+// without turning on error translation for every query.
+func isUniqueViolation(db *gorm.DB, err error) bool {
+	if err == nil {
+		return false
+	}
+	if translator, ok := db.Dialector.(gorm.ErrorTranslator); ok {
+		err = translator.Translate(err)
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
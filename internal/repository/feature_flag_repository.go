@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// FeatureFlagRepository defines the interface for feature flag override data operations
+type FeatureFlagRepository interface {
+	FindAll(ctx context.Context) ([]*models.FeatureFlagOverride, error)
+	Save(ctx context.Context, override *models.FeatureFlagOverride) error
+	Delete(ctx context.Context, name string, userID *uuid.UUID) error
+}
+
+// featureFlagRepository implements FeatureFlagRepository interface
+type featureFlagRepository struct {
+	db *gorm.DB
+}
+
+// NewFeatureFlagRepository creates a new FeatureFlagRepository instance
+func NewFeatureFlagRepository(db *gorm.DB) FeatureFlagRepository {
+	return &featureFlagRepository{db: db}
+}
+
+// FindAll returns every override, ordered by feature with the overrides for everyone first
+func (r *featureFlagRepository) FindAll(ctx context.Context) ([]*models.FeatureFlagOverride, error) {
+	var overrides []*models.FeatureFlagOverride
+	err := r.db.WithContext(ctx).
+		Order("name ASC").
+		Order("user_id IS NOT NULL").
+		Order("created_at ASC").
+		Find(&overrides).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find feature flag overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// Save stores an override, replacing the one already set for its feature and user
+func (r *featureFlagRepository) Save(ctx context.Context, override *models.FeatureFlagOverride) error {
+	if override == nil {
+		return fmt.Errorf("override cannot be nil")
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.FeatureFlagOverride
+		err := whereOverride(tx, override.Name, override.UserID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(override).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		override.ID = existing.ID
+		override.CreatedAt = existing.CreatedAt
+		return tx.Model(&existing).Update("enabled", override.Enabled).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save feature flag override: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the override of a feature for a user, or for everyone when userID is nil.
+// Removing an override that isn't set succeeds.
+func (r *featureFlagRepository) Delete(ctx context.Context, name string, userID *uuid.UUID) error {
+	if err := whereOverride(r.db.WithContext(ctx), name, userID).Delete(&models.FeatureFlagOverride{}).Error; err != nil {
+		return fmt.Errorf("failed to delete feature flag override: %w", err)
+	}
+	return nil
+}
+
+// whereOverride scopes a query to the override of a feature for a user, or for everyone when
+// userID is nil
+func whereOverride(db *gorm.DB, name string, userID *uuid.UUID) *gorm.DB {
+	if userID == nil {
+		return db.Where("name = ? AND user_id IS NULL", name)
+	}
+	return db.Where("name = ? AND user_id = ?", name, *userID)
+}
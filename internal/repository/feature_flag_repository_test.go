@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestFeatureFlagRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.FeatureFlagOverride{}))
+	repo := NewFeatureFlagRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	require.NoError(t, repo.Save(ctx, &models.FeatureFlagOverride{Name: models.FeatureWhatIf, UserID: &userID, Enabled: true}))
+	everyone := &models.FeatureFlagOverride{Name: models.FeatureWhatIf, Enabled: false}
+	require.NoError(t, repo.Save(ctx, everyone))
+	require.NoError(t, repo.Save(ctx, &models.FeatureFlagOverride{Name: models.FeatureSimulations, Enabled: true}))
+
+	// Saving again replaces the override of the same feature and user
+	again := &models.FeatureFlagOverride{Name: models.FeatureWhatIf, Enabled: true}
+	require.NoError(t, repo.Save(ctx, again))
+	assert.Equal(t, everyone.ID, again.ID)
+
+	overrides, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, overrides, 3)
+	assert.Equal(t, models.FeatureSimulations, overrides[0].Name)
+	assert.Equal(t, models.FeatureWhatIf, overrides[1].Name)
+	assert.Nil(t, overrides[1].UserID, "overrides for everyone come first")
+	assert.True(t, overrides[1].Enabled)
+	require.NotNil(t, overrides[2].UserID)
+	assert.Equal(t, userID, *overrides[2].UserID)
+
+	require.NoError(t, repo.Delete(ctx, models.FeatureWhatIf, &userID))
+	require.NoError(t, repo.Delete(ctx, models.FeatureWhatIf, &userID))
+	require.NoError(t, repo.Delete(ctx, models.FeatureSimulations, nil))
+	overrides, err = repo.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	assert.Equal(t, everyone.ID, overrides[0].ID)
+}
@@ -1,25 +1,30 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/lenon/portfolios/internal/database"
 	"github.com/lenon/portfolios/internal/models"
 )
 
 // HoldingRepository defines the interface for holding data operations
 type HoldingRepository interface {
-	Create(holding *models.Holding) error
-	FindByID(id string) (*models.Holding, error)
-	FindByPortfolioID(portfolioID string) ([]*models.Holding, error)
-	FindByPortfolioIDAndSymbol(portfolioID, symbol string) (*models.Holding, error)
-	FindBySymbol(symbol string) ([]*models.Holding, error)
-	Update(holding *models.Holding) error
-	Upsert(holding *models.Holding) error
-	Delete(id string) error
-	DeleteByPortfolioIDAndSymbol(portfolioID, symbol string) error
+	Create(ctx context.Context, holding *models.Holding) error
+	FindByID(ctx context.Context, id string) (*models.Holding, error)
+	FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Holding, error)
+	FindByPortfolioIDs(ctx context.Context, portfolioIDs []uuid.UUID) ([]*models.Holding, error)
+	FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) (*models.Holding, error)
+	FindBySymbol(ctx context.Context, symbol string) ([]*models.Holding, error)
+	FindDistinctSymbols(ctx context.Context) ([]string, error)
+	CountHoldersBySymbol(ctx context.Context) (map[string]int, error)
+	Update(ctx context.Context, holding *models.Holding) error
+	Upsert(ctx context.Context, holding *models.Holding) error
+	Delete(ctx context.Context, id string) error
+	DeleteByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) error
 }
 
 // holdingRepository implements HoldingRepository interface
@@ -33,12 +38,15 @@ func NewHoldingRepository(db *gorm.DB) HoldingRepository {
 }
 
 // Create creates a new holding in the database
-func (r *holdingRepository) Create(holding *models.Holding) error {
+func (r *holdingRepository) Create(ctx context.Context, holding *models.Holding) error {
 	if holding == nil {
 		return fmt.Errorf("holding cannot be nil")
 	}
 
-	if err := r.db.Create(holding).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(holding).Error; err != nil {
+		if isUniqueViolation(r.db, err) {
+			return models.ErrDuplicateHolding
+		}
 		return fmt.Errorf("failed to create holding: %w", err)
 	}
 
@@ -46,7 +54,7 @@ func (r *holdingRepository) Create(holding *models.Holding) error {
 }
 
 // FindByID finds a holding by ID
-func (r *holdingRepository) FindByID(id string) (*models.Holding, error) {
+func (r *holdingRepository) FindByID(ctx context.Context, id string) (*models.Holding, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id cannot be empty")
 	}
@@ -58,7 +66,7 @@ func (r *holdingRepository) FindByID(id string) (*models.Holding, error) {
 	}
 
 	var holding models.Holding
-	err = r.db.Where("id = ?", holdingID).First(&holding).Error
+	err = r.db.WithContext(ctx).Where("id = ?", holdingID).First(&holding).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrHoldingNotFound
@@ -70,7 +78,7 @@ func (r *holdingRepository) FindByID(id string) (*models.Holding, error) {
 }
 
 // FindByPortfolioID finds all holdings for a specific portfolio
-func (r *holdingRepository) FindByPortfolioID(portfolioID string) ([]*models.Holding, error) {
+func (r *holdingRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Holding, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -82,7 +90,7 @@ func (r *holdingRepository) FindByPortfolioID(portfolioID string) ([]*models.Hol
 	}
 
 	var holdings []*models.Holding
-	err = r.db.Where("portfolio_id = ? AND quantity > 0", pid).
+	err = r.db.WithContext(ctx).Where("portfolio_id = ? AND quantity > 0", pid).
 		Order("symbol ASC").
 		Find(&holdings).Error
 	if err != nil {
@@ -92,8 +100,26 @@ func (r *holdingRepository) FindByPortfolioID(portfolioID string) ([]*models.Hol
 	return holdings, nil
 }
 
+// FindByPortfolioIDs finds the open holdings of several portfolios in one query, ordered by
+// portfolio and symbol
+func (r *holdingRepository) FindByPortfolioIDs(ctx context.Context, portfolioIDs []uuid.UUID) ([]*models.Holding, error) {
+	if len(portfolioIDs) == 0 {
+		return []*models.Holding{}, nil
+	}
+
+	var holdings []*models.Holding
+	err := r.db.WithContext(ctx).Where("portfolio_id IN ? AND quantity > 0", portfolioIDs).
+		Order("portfolio_id ASC, symbol ASC").
+		Find(&holdings).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find holdings: %w", err)
+	}
+
+	return holdings, nil
+}
+
 // FindByPortfolioIDAndSymbol finds a holding by portfolio ID and symbol
-func (r *holdingRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol string) (*models.Holding, error) {
+func (r *holdingRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) (*models.Holding, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -108,7 +134,7 @@ func (r *holdingRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol strin
 	}
 
 	var holding models.Holding
-	err = r.db.Where("portfolio_id = ? AND symbol = ?", pid, symbol).First(&holding).Error
+	err = r.db.WithContext(ctx).Where("portfolio_id = ? AND symbol = ?", pid, symbol).First(&holding).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrHoldingNotFound
@@ -120,13 +146,13 @@ func (r *holdingRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol strin
 }
 
 // FindBySymbol finds all holdings for a given symbol across all portfolios
-func (r *holdingRepository) FindBySymbol(symbol string) ([]*models.Holding, error) {
+func (r *holdingRepository) FindBySymbol(ctx context.Context, symbol string) ([]*models.Holding, error) {
 	if symbol == "" {
 		return nil, fmt.Errorf("symbol cannot be empty")
 	}
 
 	var holdings []*models.Holding
-	if err := r.db.Preload("Portfolio").
+	if err := r.db.WithContext(ctx).Preload("Portfolio").
 		Where("symbol = ?", symbol).
 		Find(&holdings).Error; err != nil {
 		return nil, fmt.Errorf("failed to find holdings: %w", err)
@@ -135,14 +161,54 @@ func (r *holdingRepository) FindBySymbol(symbol string) ([]*models.Holding, erro
 	return holdings, nil
 }
 
+// FindDistinctSymbols returns every symbol with an open position in any portfolio
+func (r *holdingRepository) FindDistinctSymbols(ctx context.Context) ([]string, error) {
+	var symbols []string
+	if err := r.db.WithContext(ctx).Model(&models.Holding{}).
+		Where("quantity > 0").
+		Distinct("symbol").
+		Order("symbol ASC").
+		Pluck("symbol", &symbols).Error; err != nil {
+		return nil, fmt.Errorf("failed to find held symbols: %w", err)
+	}
+
+	return symbols, nil
+}
+
+// CountHoldersBySymbol counts the users holding each symbol with a positive quantity in
+// any of their portfolios
+func (r *holdingRepository) CountHoldersBySymbol(ctx context.Context) (map[string]int, error) {
+	var rows []struct {
+		Symbol  string
+		Holders int
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Holding{}).
+		Select("holdings.symbol AS symbol, COUNT(DISTINCT portfolios.user_id) AS holders").
+		Joins("JOIN " + database.TableName(ctx, "portfolios") + " AS portfolios ON portfolios.id = holdings.portfolio_id").
+		Where("holdings.quantity > 0").
+		Group("holdings.symbol").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count holders by symbol: %w", err)
+	}
+
+	holders := make(map[string]int, len(rows))
+	for _, row := range rows {
+		holders[row.Symbol] = row.Holders
+	}
+	return holders, nil
+}
+
 // Update updates an existing holding
-func (r *holdingRepository) Update(holding *models.Holding) error {
+func (r *holdingRepository) Update(ctx context.Context, holding *models.Holding) error {
 	if holding == nil {
 		return fmt.Errorf("holding cannot be nil")
 	}
 
-	result := r.db.Model(holding).Where("id = ?", holding.ID).Updates(holding)
+	result := r.db.WithContext(ctx).Model(holding).Where("id = ?", holding.ID).Updates(holding)
 	if result.Error != nil {
+		if isUniqueViolation(r.db, result.Error) {
+			return models.ErrDuplicateHolding
+		}
 		return fmt.Errorf("failed to update holding: %w", result.Error)
 	}
 
@@ -154,13 +220,13 @@ func (r *holdingRepository) Update(holding *models.Holding) error {
 }
 
 // Upsert creates or updates a holding based on portfolio_id and symbol
-func (r *holdingRepository) Upsert(holding *models.Holding) error {
+func (r *holdingRepository) Upsert(ctx context.Context, holding *models.Holding) error {
 	if holding == nil {
 		return fmt.Errorf("holding cannot be nil")
 	}
 
 	// Try to find existing holding
-	existing, err := r.FindByPortfolioIDAndSymbol(holding.PortfolioID.String(), holding.Symbol)
+	existing, err := r.FindByPortfolioIDAndSymbol(ctx, holding.PortfolioID.String(), holding.Symbol)
 	if err != nil && err != models.ErrHoldingNotFound {
 		return fmt.Errorf("failed to check existing holding: %w", err)
 	}
@@ -168,15 +234,24 @@ func (r *holdingRepository) Upsert(holding *models.Holding) error {
 	if existing != nil {
 		// Update existing holding
 		holding.ID = existing.ID
-		return r.Update(holding)
+		return r.Update(ctx, holding)
 	}
 
-	// Create new holding
-	return r.Create(holding)
+	// Create new holding. If another request created it since we looked, update that one.
+	err = r.Create(ctx, holding)
+	if err == models.ErrDuplicateHolding {
+		existing, err = r.FindByPortfolioIDAndSymbol(ctx, holding.PortfolioID.String(), holding.Symbol)
+		if err != nil {
+			return fmt.Errorf("failed to check existing holding: %w", err)
+		}
+		holding.ID = existing.ID
+		return r.Update(ctx, holding)
+	}
+	return err
 }
 
 // Delete deletes a holding by ID
-func (r *holdingRepository) Delete(id string) error {
+func (r *holdingRepository) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("id cannot be empty")
 	}
@@ -187,7 +262,7 @@ func (r *holdingRepository) Delete(id string) error {
 		return fmt.Errorf("invalid holding ID format: %w", err)
 	}
 
-	result := r.db.Where("id = ?", holdingID).Delete(&models.Holding{})
+	result := r.db.WithContext(ctx).Where("id = ?", holdingID).Delete(&models.Holding{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete holding: %w", result.Error)
 	}
@@ -200,7 +275,7 @@ func (r *holdingRepository) Delete(id string) error {
 }
 
 // DeleteByPortfolioIDAndSymbol deletes a holding by portfolio ID and symbol
-func (r *holdingRepository) DeleteByPortfolioIDAndSymbol(portfolioID, symbol string) error {
+func (r *holdingRepository) DeleteByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) error {
 	if portfolioID == "" {
 		return fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -214,7 +289,7 @@ func (r *holdingRepository) DeleteByPortfolioIDAndSymbol(portfolioID, symbol str
 		return fmt.Errorf("invalid portfolio ID format: %w", err)
 	}
 
-	result := r.db.Where("portfolio_id = ? AND symbol = ?", pid, symbol).Delete(&models.Holding{})
+	result := r.db.WithContext(ctx).Where("portfolio_id = ? AND symbol = ?", pid, symbol).Delete(&models.Holding{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete holding: %w", result.Error)
 	}
@@ -1,14 +1,18 @@
 package repository
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/lenon/portfolios/internal/database"
 	"github.com/lenon/portfolios/internal/models"
 )
 
@@ -41,6 +45,8 @@ func setupHoldingRepoTestDB(t *testing.T) (*gorm.DB, *models.User, *models.Portf
 }
 
 func TestHoldingRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupHoldingRepoTestDB(t)
 	repo := NewHoldingRepository(db)
 
@@ -53,20 +59,36 @@ func TestHoldingRepository_Create(t *testing.T) {
 			AvgCostPrice: decimal.NewFromFloat(150.00),
 		}
 
-		err := repo.Create(holding)
+		err := repo.Create(ctx, holding)
 
 		assert.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, holding.ID)
 	})
 
+	t.Run("duplicate symbol error", func(t *testing.T) {
+		holding := &models.Holding{
+			PortfolioID:  portfolio.ID,
+			Symbol:       "AAPL",
+			Quantity:     decimal.NewFromInt(5),
+			CostBasis:    decimal.NewFromFloat(750.00),
+			AvgCostPrice: decimal.NewFromFloat(150.00),
+		}
+
+		err := repo.Create(ctx, holding)
+
+		assert.ErrorIs(t, err, models.ErrDuplicateHolding)
+	})
+
 	t.Run("nil holding error", func(t *testing.T) {
-		err := repo.Create(nil)
+		err := repo.Create(ctx, nil)
 
 		assert.Error(t, err)
 	})
 }
 
 func TestHoldingRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupHoldingRepoTestDB(t)
 	repo := NewHoldingRepository(db)
 
@@ -77,11 +99,11 @@ func TestHoldingRepository_FindByID(t *testing.T) {
 		CostBasis:    decimal.NewFromFloat(1500.00),
 		AvgCostPrice: decimal.NewFromFloat(150.00),
 	}
-	err := repo.Create(holding)
+	err := repo.Create(ctx, holding)
 	assert.NoError(t, err)
 
 	t.Run("successful find", func(t *testing.T) {
-		found, err := repo.FindByID(holding.ID.String())
+		found, err := repo.FindByID(ctx, holding.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
@@ -90,25 +112,27 @@ func TestHoldingRepository_FindByID(t *testing.T) {
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		_, err := repo.FindByID(uuid.New().String())
+		_, err := repo.FindByID(ctx, uuid.New().String())
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrHoldingNotFound, err)
 	})
 
 	t.Run("empty id error", func(t *testing.T) {
-		_, err := repo.FindByID("")
+		_, err := repo.FindByID(ctx, "")
 
 		assert.Error(t, err)
 	})
 }
 
 func TestHoldingRepository_FindByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupHoldingRepoTestDB(t)
 	repo := NewHoldingRepository(db)
 
 	t.Run("empty list", func(t *testing.T) {
-		holdings, err := repo.FindByPortfolioID(portfolio.ID.String())
+		holdings, err := repo.FindByPortfolioID(ctx, portfolio.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, holdings)
@@ -134,25 +158,68 @@ func TestHoldingRepository_FindByPortfolioID(t *testing.T) {
 	}
 
 	for _, h := range holdings {
-		err := repo.Create(h)
+		err := repo.Create(ctx, h)
 		assert.NoError(t, err)
 	}
 
 	t.Run("find multiple holdings", func(t *testing.T) {
-		found, err := repo.FindByPortfolioID(portfolio.ID.String())
+		found, err := repo.FindByPortfolioID(ctx, portfolio.ID.String())
 
 		assert.NoError(t, err)
 		assert.Len(t, found, 2)
 	})
 
 	t.Run("empty portfolio id error", func(t *testing.T) {
-		_, err := repo.FindByPortfolioID("")
+		_, err := repo.FindByPortfolioID(ctx, "")
 
 		assert.Error(t, err)
 	})
 }
 
+func TestHoldingRepository_FindByPortfolioIDs(t *testing.T) {
+	ctx := context.Background()
+
+	db, user, portfolio := setupHoldingRepoTestDB(t)
+	repo := NewHoldingRepository(db)
+
+	other := &models.Portfolio{UserID: user.ID, Name: "Other", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	assert.NoError(t, db.Create(other).Error)
+	unrelated := &models.Portfolio{UserID: user.ID, Name: "Unrelated", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	assert.NoError(t, db.Create(unrelated).Error)
+
+	for _, h := range []*models.Holding{
+		{PortfolioID: portfolio.ID, Symbol: "MSFT", Quantity: decimal.NewFromInt(5)},
+		{PortfolioID: portfolio.ID, Symbol: "AAPL", Quantity: decimal.NewFromInt(10)},
+		{PortfolioID: portfolio.ID, Symbol: "SOLD", Quantity: decimal.Zero},
+		{PortfolioID: other.ID, Symbol: "AAPL", Quantity: decimal.NewFromInt(2)},
+		{PortfolioID: unrelated.ID, Symbol: "TSLA", Quantity: decimal.NewFromInt(1)},
+	} {
+		assert.NoError(t, repo.Create(ctx, h))
+	}
+
+	t.Run("open holdings of every portfolio", func(t *testing.T) {
+		found, err := repo.FindByPortfolioIDs(ctx, []uuid.UUID{portfolio.ID, other.ID})
+
+		assert.NoError(t, err)
+		assert.Len(t, found, 3)
+		for _, holding := range found {
+			assert.NotEqual(t, unrelated.ID, holding.PortfolioID)
+			assert.NotEqual(t, "SOLD", holding.Symbol)
+		}
+	})
+
+	t.Run("no portfolios", func(t *testing.T) {
+		found, err := repo.FindByPortfolioIDs(ctx, nil)
+
+		assert.NoError(t, err)
+		assert.NotNil(t, found)
+		assert.Empty(t, found)
+	})
+}
+
 func TestHoldingRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupHoldingRepoTestDB(t)
 	repo := NewHoldingRepository(db)
 
@@ -163,11 +230,11 @@ func TestHoldingRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
 		CostBasis:    decimal.NewFromFloat(1500.00),
 		AvgCostPrice: decimal.NewFromFloat(150.00),
 	}
-	err := repo.Create(holding)
+	err := repo.Create(ctx, holding)
 	assert.NoError(t, err)
 
 	t.Run("successful find", func(t *testing.T) {
-		found, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		found, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
@@ -175,22 +242,154 @@ func TestHoldingRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		_, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "MSFT")
+		_, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "MSFT")
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrHoldingNotFound, err)
 	})
 
 	t.Run("empty parameters error", func(t *testing.T) {
-		_, err := repo.FindByPortfolioIDAndSymbol("", "AAPL")
+		_, err := repo.FindByPortfolioIDAndSymbol(ctx, "", "AAPL")
 		assert.Error(t, err)
 
-		_, err = repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "")
+		_, err = repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "")
 		assert.Error(t, err)
 	})
 }
 
+func TestHoldingRepository_FindDistinctSymbols(t *testing.T) {
+	ctx := context.Background()
+
+	db, user, portfolio := setupHoldingRepoTestDB(t)
+	repo := NewHoldingRepository(db)
+
+	other := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Other Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	assert.NoError(t, db.Create(other).Error)
+
+	for _, h := range []struct {
+		portfolio *models.Portfolio
+		symbol    string
+		quantity  int64
+	}{
+		{portfolio, "MSFT", 5},
+		{portfolio, "AAPL", 10},
+		{other, "AAPL", 3},
+		{other, "TSLA", 0}, // closed position
+	} {
+		assert.NoError(t, repo.Create(ctx, &models.Holding{
+			PortfolioID:  h.portfolio.ID,
+			Symbol:       h.symbol,
+			Quantity:     decimal.NewFromInt(h.quantity),
+			CostBasis:    decimal.NewFromInt(100),
+			AvgCostPrice: decimal.NewFromInt(10),
+		}))
+	}
+
+	symbols, err := repo.FindDistinctSymbols(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"AAPL", "MSFT"}, symbols)
+}
+
+func TestHoldingRepository_CountHoldersBySymbol(t *testing.T) {
+	ctx := context.Background()
+
+	db, user, portfolio := setupHoldingRepoTestDB(t)
+	repo := NewHoldingRepository(db)
+
+	other := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Other Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	assert.NoError(t, db.Create(other).Error)
+	otherUser := &models.User{Email: "other-holder@example.com", PasswordHash: "hash"}
+	assert.NoError(t, db.Create(otherUser).Error)
+	otherUsers := &models.Portfolio{
+		UserID:          otherUser.ID,
+		Name:            "Their Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	assert.NoError(t, db.Create(otherUsers).Error)
+
+	for _, h := range []struct {
+		portfolio *models.Portfolio
+		symbol    string
+		quantity  int64
+	}{
+		{portfolio, "AAPL", 10},
+		{other, "AAPL", 3}, // the same user again
+		{otherUsers, "AAPL", 1},
+		{otherUsers, "MSFT", 5},
+		{otherUsers, "TSLA", 0}, // closed position
+	} {
+		assert.NoError(t, repo.Create(ctx, &models.Holding{
+			PortfolioID:  h.portfolio.ID,
+			Symbol:       h.symbol,
+			Quantity:     decimal.NewFromInt(h.quantity),
+			CostBasis:    decimal.NewFromInt(100),
+			AvgCostPrice: decimal.NewFromInt(10),
+		}))
+	}
+
+	holders, err := repo.CountHoldersBySymbol(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"AAPL": 2, "MSFT": 1}, holders)
+}
+
+func TestHoldingRepository_CountHoldersBySymbol_TenantSchema(t *testing.T) {
+	db, user, portfolio := setupHoldingRepoTestDB(t)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	// An attached database stands in for the tenant schema
+	require.NoError(t, db.Exec("ATTACH DATABASE ':memory:' AS tenant_acme").Error)
+	for _, table := range []string{"portfolios", "holdings"} {
+		var ddl string
+		require.NoError(t, db.Raw("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&ddl).Error)
+		ddl = strings.Replace(ddl, "CREATE TABLE `"+table+"`", "CREATE TABLE `tenant_acme`.`"+table+"`", 1)
+		require.NoError(t, db.Exec(ddl).Error)
+	}
+	require.NoError(t, database.UseTenantSchemas(db))
+
+	repo := NewHoldingRepository(db)
+	tenantCtx := database.WithSchema(context.Background(), "tenant_acme")
+	tenantPortfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Tenant Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.WithContext(tenantCtx).Create(tenantPortfolio).Error)
+	for ctx, holding := range map[context.Context]*models.Holding{
+		tenantCtx:            {PortfolioID: tenantPortfolio.ID, Symbol: "AAPL", Quantity: decimal.NewFromInt(10)},
+		context.Background(): {PortfolioID: portfolio.ID, Symbol: "MSFT", Quantity: decimal.NewFromInt(5)},
+	} {
+		require.NoError(t, repo.Create(ctx, holding))
+	}
+
+	// Each schema's holdings are joined to the same schema's portfolios
+	holders, err := repo.CountHoldersBySymbol(tenantCtx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"AAPL": 1}, holders)
+
+	holders, err = repo.CountHoldersBySymbol(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"MSFT": 1}, holders)
+}
+
 func TestHoldingRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupHoldingRepoTestDB(t)
 	repo := NewHoldingRepository(db)
 
@@ -201,7 +400,7 @@ func TestHoldingRepository_Update(t *testing.T) {
 		CostBasis:    decimal.NewFromFloat(1500.00),
 		AvgCostPrice: decimal.NewFromFloat(150.00),
 	}
-	err := repo.Create(holding)
+	err := repo.Create(ctx, holding)
 	assert.NoError(t, err)
 
 	t.Run("successful update", func(t *testing.T) {
@@ -211,19 +410,19 @@ func TestHoldingRepository_Update(t *testing.T) {
 		holding.CostBasis = newCostBasis
 		holding.CalculateAvgCostPrice()
 
-		err := repo.Update(holding)
+		err := repo.Update(ctx, holding)
 
 		assert.NoError(t, err)
 
 		// Verify update
-		found, err := repo.FindByID(holding.ID.String())
+		found, err := repo.FindByID(ctx, holding.ID.String())
 		assert.NoError(t, err)
 		assert.True(t, newQuantity.Equal(found.Quantity))
 		assert.True(t, newCostBasis.Equal(found.CostBasis))
 	})
 
 	t.Run("nil holding error", func(t *testing.T) {
-		err := repo.Update(nil)
+		err := repo.Update(ctx, nil)
 
 		assert.Error(t, err)
 	})
@@ -238,7 +437,7 @@ func TestHoldingRepository_Update(t *testing.T) {
 			AvgCostPrice: decimal.NewFromFloat(100.00),
 		}
 
-		err := repo.Update(nonExistent)
+		err := repo.Update(ctx, nonExistent)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrHoldingNotFound, err)
@@ -246,6 +445,8 @@ func TestHoldingRepository_Update(t *testing.T) {
 }
 
 func TestHoldingRepository_Upsert(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupHoldingRepoTestDB(t)
 	repo := NewHoldingRepository(db)
 
@@ -258,20 +459,20 @@ func TestHoldingRepository_Upsert(t *testing.T) {
 			AvgCostPrice: decimal.NewFromFloat(150.00),
 		}
 
-		err := repo.Upsert(holding)
+		err := repo.Upsert(ctx, holding)
 
 		assert.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, holding.ID)
 
 		// Verify it was created
-		found, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		found, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
 	})
 
 	t.Run("update existing holding", func(t *testing.T) {
 		// First, get the existing holding
-		existing, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		existing, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 		assert.NoError(t, err)
 
 		// Create a holding with same portfolio and symbol but different values
@@ -283,19 +484,21 @@ func TestHoldingRepository_Upsert(t *testing.T) {
 			AvgCostPrice: decimal.NewFromFloat(150.00),
 		}
 
-		err = repo.Upsert(holding)
+		err = repo.Upsert(ctx, holding)
 
 		assert.NoError(t, err)
 		assert.Equal(t, existing.ID, holding.ID) // Should have same ID
 
 		// Verify it was updated
-		found, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		found, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 		assert.NoError(t, err)
 		assert.True(t, decimal.NewFromInt(20).Equal(found.Quantity))
 	})
 }
 
 func TestHoldingRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupHoldingRepoTestDB(t)
 	repo := NewHoldingRepository(db)
 
@@ -306,34 +509,36 @@ func TestHoldingRepository_Delete(t *testing.T) {
 		CostBasis:    decimal.NewFromFloat(1500.00),
 		AvgCostPrice: decimal.NewFromFloat(150.00),
 	}
-	err := repo.Create(holding)
+	err := repo.Create(ctx, holding)
 	assert.NoError(t, err)
 
 	t.Run("successful deletion", func(t *testing.T) {
-		err := repo.Delete(holding.ID.String())
+		err := repo.Delete(ctx, holding.ID.String())
 
 		assert.NoError(t, err)
 
 		// Verify deletion
-		_, err = repo.FindByID(holding.ID.String())
+		_, err = repo.FindByID(ctx, holding.ID.String())
 		assert.Error(t, err)
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		err := repo.Delete(uuid.New().String())
+		err := repo.Delete(ctx, uuid.New().String())
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrHoldingNotFound, err)
 	})
 
 	t.Run("empty id error", func(t *testing.T) {
-		err := repo.Delete("")
+		err := repo.Delete(ctx, "")
 
 		assert.Error(t, err)
 	})
 }
 
 func TestHoldingRepository_DeleteByPortfolioIDAndSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupHoldingRepoTestDB(t)
 	repo := NewHoldingRepository(db)
 
@@ -344,31 +549,31 @@ func TestHoldingRepository_DeleteByPortfolioIDAndSymbol(t *testing.T) {
 		CostBasis:    decimal.NewFromFloat(1500.00),
 		AvgCostPrice: decimal.NewFromFloat(150.00),
 	}
-	err := repo.Create(holding)
+	err := repo.Create(ctx, holding)
 	assert.NoError(t, err)
 
 	t.Run("successful deletion", func(t *testing.T) {
-		err := repo.DeleteByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		err := repo.DeleteByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 
 		assert.NoError(t, err)
 
 		// Verify deletion
-		_, err = repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		_, err = repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 		assert.Error(t, err)
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		err := repo.DeleteByPortfolioIDAndSymbol(portfolio.ID.String(), "MSFT")
+		err := repo.DeleteByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "MSFT")
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrHoldingNotFound, err)
 	})
 
 	t.Run("empty parameters error", func(t *testing.T) {
-		err := repo.DeleteByPortfolioIDAndSymbol("", "AAPL")
+		err := repo.DeleteByPortfolioIDAndSymbol(ctx, "", "AAPL")
 		assert.Error(t, err)
 
-		err = repo.DeleteByPortfolioIDAndSymbol(portfolio.ID.String(), "")
+		err = repo.DeleteByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "")
 		assert.Error(t, err)
 	})
 }
@@ -0,0 +1,10 @@
+package repository
+
+import "errors"
+
+// iterateBatchSize is how many rows IterateByPortfolioID methods read at a time
+const iterateBatchSize = 500
+
+// ErrStopIteration may be returned by an iterator's callback to stop iterating early. The
+// iterator then returns nil.
+var ErrStopIteration = errors.New("stop iteration")
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// NotificationRepository defines the interface for notification data operations
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *models.Notification) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Notification, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkRead(ctx context.Context, id uuid.UUID, readAt time.Time) error
+}
+
+// notificationRepository implements NotificationRepository interface
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository creates a new NotificationRepository instance
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create stores a new notification
+func (r *notificationRepository) Create(ctx context.Context, notification *models.Notification) error {
+	if notification == nil {
+		return fmt.Errorf("notification cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a notification by ID
+func (r *notificationRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Notification, error) {
+	var notification models.Notification
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&notification).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrNotificationNotFound
+		}
+		return nil, fmt.Errorf("failed to find notification: %w", err)
+	}
+
+	return &notification, nil
+}
+
+// FindByUserID finds a user's most recent notifications, newest first, optionally only the
+// unread ones
+func (r *notificationRepository) FindByUserID(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]*models.Notification, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var notifications []*models.Notification
+	if err := query.Order("created_at DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to find notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// CountUnread counts the notifications a user hasn't read
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkRead records when a notification was read. A notification already read keeps the time
+// it was first read.
+func (r *notificationRepository) MarkRead(ctx context.Context, id uuid.UUID, readAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", readAt).Error; err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	return nil
+}
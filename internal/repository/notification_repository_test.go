@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupNotificationTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}))
+	return db
+}
+
+func TestNotificationRepository(t *testing.T) {
+	repo := NewNotificationRepository(setupNotificationTestDB(t))
+	ctx := context.Background()
+	userID := uuid.New()
+	base := time.Date(2024, time.June, 14, 12, 0, 0, 0, time.UTC)
+
+	older := &models.Notification{UserID: userID, Type: models.NotificationTypeImportCompleted, Title: "Import finished", Message: "10 rows", CreatedAt: base}
+	newer := &models.Notification{UserID: userID, Type: models.NotificationTypeCorporateAction, Title: "Split", Message: "AAPL", CreatedAt: base.Add(time.Hour)}
+	other := &models.Notification{UserID: uuid.New(), Type: models.NotificationTypeCorporateAction, Title: "Split", Message: "MSFT", CreatedAt: base}
+	for _, notification := range []*models.Notification{older, newer, other} {
+		require.NoError(t, repo.Create(ctx, notification))
+	}
+
+	notifications, err := repo.FindByUserID(ctx, userID, false, 10)
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, newer.ID, notifications[0].ID)
+	assert.Equal(t, older.ID, notifications[1].ID)
+
+	readAt := base.Add(2 * time.Hour)
+	require.NoError(t, repo.MarkRead(ctx, newer.ID, readAt))
+	// Marking it again keeps the first read time
+	require.NoError(t, repo.MarkRead(ctx, newer.ID, readAt.Add(time.Hour)))
+
+	found, err := repo.FindByID(ctx, newer.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.ReadAt)
+	assert.True(t, readAt.Equal(*found.ReadAt))
+
+	unread, err := repo.FindByUserID(ctx, userID, true, 10)
+	require.NoError(t, err)
+	require.Len(t, unread, 1)
+	assert.Equal(t, older.ID, unread[0].ID)
+
+	count, err := repo.CountUnread(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	_, err = repo.FindByID(ctx, uuid.New())
+	assert.Equal(t, models.ErrNotificationNotFound, err)
+}
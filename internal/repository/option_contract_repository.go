@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// OptionContractRepository defines the interface for option contract data operations.
+// Contracts are shared by every portfolio that trades them.
+type OptionContractRepository interface {
+	FindOrCreate(ctx context.Context, contract *models.OptionContract) (*models.OptionContract, error)
+	FindByID(ctx context.Context, id string) (*models.OptionContract, error)
+	FindBySymbol(ctx context.Context, symbol string) (*models.OptionContract, error)
+	FindBySymbols(ctx context.Context, symbols []string) ([]*models.OptionContract, error)
+}
+
+// optionContractRepository implements OptionContractRepository interface
+type optionContractRepository struct {
+	db *gorm.DB
+}
+
+// NewOptionContractRepository creates a new OptionContractRepository instance
+func NewOptionContractRepository(db *gorm.DB) OptionContractRepository {
+	return &optionContractRepository{db: db}
+}
+
+// FindOrCreate returns the stored contract with the same OCC symbol, saving contract first
+// if there is none. A contract saved concurrently by another request is returned as is.
+func (r *optionContractRepository) FindOrCreate(ctx context.Context, contract *models.OptionContract) (*models.OptionContract, error) {
+	if contract.Symbol == "" {
+		contract.Symbol = models.OptionSymbol(contract.Underlying, contract.Expiry, contract.Type, contract.Strike)
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "symbol"}},
+		DoNothing: true,
+	}).Create(contract).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create option contract: %w", err)
+	}
+
+	return r.FindBySymbol(ctx, contract.Symbol)
+}
+
+// FindByID finds an option contract by ID
+func (r *optionContractRepository) FindByID(ctx context.Context, id string) (*models.OptionContract, error) {
+	var contract models.OptionContract
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&contract).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrOptionContractNotFound
+		}
+		return nil, fmt.Errorf("failed to find option contract: %w", err)
+	}
+
+	return &contract, nil
+}
+
+// FindBySymbol finds an option contract by its OCC symbol
+func (r *optionContractRepository) FindBySymbol(ctx context.Context, symbol string) (*models.OptionContract, error) {
+	var contract models.OptionContract
+	if err := r.db.WithContext(ctx).Where("symbol = ?", symbol).First(&contract).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrOptionContractNotFound
+		}
+		return nil, fmt.Errorf("failed to find option contract: %w", err)
+	}
+
+	return &contract, nil
+}
+
+// FindBySymbols finds the contracts with any of the OCC symbols, ordered by expiry. Symbols
+// without a stored contract are skipped.
+func (r *optionContractRepository) FindBySymbols(ctx context.Context, symbols []string) ([]*models.OptionContract, error) {
+	var contracts []*models.OptionContract
+	if len(symbols) == 0 {
+		return contracts, nil
+	}
+
+	if err := r.db.WithContext(ctx).Where("symbol IN ?", symbols).
+		Order("expiry ASC, symbol ASC").Find(&contracts).Error; err != nil {
+		return nil, fmt.Errorf("failed to find option contracts: %w", err)
+	}
+
+	return contracts, nil
+}
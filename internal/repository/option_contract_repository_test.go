@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupOptionContractTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.AutoMigrate(&models.OptionContract{}))
+
+	return db
+}
+
+func newTestOptionContract(underlying string, optionType models.OptionType, strike string, expiry time.Time) *models.OptionContract {
+	return &models.OptionContract{
+		Underlying: underlying,
+		Type:       optionType,
+		Strike:     decimal.RequireFromString(strike),
+		Expiry:     expiry,
+	}
+}
+
+func TestOptionContractRepository_FindOrCreate(t *testing.T) {
+	ctx := context.Background()
+	repo := NewOptionContractRepository(setupOptionContractTestDB(t))
+	expiry := time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+
+	created, err := repo.FindOrCreate(ctx, newTestOptionContract("AAPL", models.OptionTypeCall, "190", expiry))
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL240621C00190000", created.Symbol)
+	assert.Equal(t, models.DefaultOptionMultiplier, created.Multiplier)
+
+	// The same terms return the stored contract
+	again, err := repo.FindOrCreate(ctx, newTestOptionContract("AAPL", models.OptionTypeCall, "190", expiry))
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, again.ID)
+
+	found, err := repo.FindByID(ctx, created.ID.String())
+	require.NoError(t, err)
+	assert.True(t, found.Strike.Equal(decimal.NewFromInt(190)))
+
+	_, err = repo.FindBySymbol(ctx, "AAPL240621P00190000")
+	assert.ErrorIs(t, err, models.ErrOptionContractNotFound)
+}
+
+func TestOptionContractRepository_FindBySymbols(t *testing.T) {
+	ctx := context.Background()
+	repo := NewOptionContractRepository(setupOptionContractTestDB(t))
+
+	july, err := repo.FindOrCreate(ctx, newTestOptionContract("MSFT", models.OptionTypePut, "400", time.Date(2024, 7, 19, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+	june, err := repo.FindOrCreate(ctx, newTestOptionContract("MSFT", models.OptionTypePut, "410.5", time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, err)
+
+	contracts, err := repo.FindBySymbols(ctx, []string{july.Symbol, june.Symbol, "MSFT", "AAPL240621C00190000"})
+	require.NoError(t, err)
+	require.Len(t, contracts, 2)
+	assert.Equal(t, "MSFT240621P00410500", contracts[0].Symbol)
+	assert.Equal(t, july.ID, contracts[1].ID)
+
+	contracts, err = repo.FindBySymbols(ctx, nil)
+	require.NoError(t, err)
+	assert.Empty(t, contracts)
+}
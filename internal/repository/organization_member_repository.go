@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// OrganizationMemberRepository defines the interface for organization membership and
+// invitation data operations
+type OrganizationMemberRepository interface {
+	FindMember(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.OrganizationMember, error)
+	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error)
+	CountMembers(ctx context.Context, organizationID uuid.UUID) (int64, error)
+	FindOwners(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error)
+	Save(ctx context.Context, member *models.OrganizationMember) error
+	Delete(ctx context.Context, organizationID, userID uuid.UUID) error
+	CreateInvitation(ctx context.Context, invitation *models.OrganizationInvitation) error
+	FindInvitation(ctx context.Context, organizationID, id uuid.UUID) (*models.OrganizationInvitation, error)
+	FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error)
+	FindPendingInvitations(ctx context.Context, organizationID uuid.UUID, now time.Time) ([]*models.OrganizationInvitation, error)
+	MarkInvitationAccepted(ctx context.Context, id uuid.UUID, acceptedAt time.Time) error
+	DeleteInvitation(ctx context.Context, organizationID, id uuid.UUID) error
+}
+
+// organizationMemberRepository implements OrganizationMemberRepository interface
+type organizationMemberRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationMemberRepository creates a new OrganizationMemberRepository instance
+func NewOrganizationMemberRepository(db *gorm.DB) OrganizationMemberRepository {
+	return &organizationMemberRepository{db: db}
+}
+
+// FindMember finds a user's membership of an organization, with the organization
+func (r *organizationMemberRepository) FindMember(ctx context.Context, organizationID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := r.db.WithContext(ctx).Preload("Organization").
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		First(&member).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrOrganizationMemberNotFound
+		}
+		return nil, fmt.Errorf("failed to find organization member: %w", err)
+	}
+
+	return &member, nil
+}
+
+// FindByUserID finds every organization a user belongs to, with the organizations, in the
+// order they joined
+func (r *organizationMemberRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.OrganizationMember, error) {
+	var members []*models.OrganizationMember
+	err := r.db.WithContext(ctx).Preload("Organization").
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find organization memberships: %w", err)
+	}
+
+	return members, nil
+}
+
+// FindByOrganizationID finds an organization's members, with their users, in the order they
+// joined
+func (r *organizationMemberRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
+	var members []*models.OrganizationMember
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("organization_id = ?", organizationID).
+		Order("created_at ASC").
+		Find(&members).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find organization members: %w", err)
+	}
+
+	return members, nil
+}
+
+// CountMembers counts an organization's members
+func (r *organizationMemberRepository) CountMembers(ctx context.Context, organizationID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.OrganizationMember{}).
+		Where("organization_id = ?", organizationID).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count organization members: %w", err)
+	}
+
+	return count, nil
+}
+
+// FindOwners finds an organization's owners, longest-standing first
+func (r *organizationMemberRepository) FindOwners(ctx context.Context, organizationID uuid.UUID) ([]*models.OrganizationMember, error) {
+	var owners []*models.OrganizationMember
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND role = ?", organizationID, models.OrgRoleOwner).
+		Order("created_at ASC").
+		Find(&owners).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find organization owners: %w", err)
+	}
+
+	return owners, nil
+}
+
+// Save creates a membership, or replaces the role of an existing one
+func (r *organizationMemberRepository) Save(ctx context.Context, member *models.OrganizationMember) error {
+	if member == nil {
+		return fmt.Errorf("member cannot be nil")
+	}
+
+	err := r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"role": member.Role, "updated_at": time.Now().UTC()}),
+	}).Create(member).Error
+	if err != nil {
+		return fmt.Errorf("failed to save organization member: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user from an organization
+func (r *organizationMemberRepository) Delete(ctx context.Context, organizationID, userID uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&models.OrganizationMember{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete organization member: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ErrOrganizationMemberNotFound
+	}
+
+	return nil
+}
+
+// CreateInvitation creates an invitation
+func (r *organizationMemberRepository) CreateInvitation(ctx context.Context, invitation *models.OrganizationInvitation) error {
+	if invitation == nil {
+		return fmt.Errorf("invitation cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Omit(clause.Associations).Create(invitation).Error; err != nil {
+		return fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	return nil
+}
+
+// FindInvitation finds one of an organization's invitations by ID
+func (r *organizationMemberRepository) FindInvitation(ctx context.Context, organizationID, id uuid.UUID) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND id = ?", organizationID, id).
+		First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to find invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+// FindInvitationByTokenHash finds an invitation by the hash of its token, with its organization
+func (r *organizationMemberRepository) FindInvitationByTokenHash(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	err := r.db.WithContext(ctx).Preload("Organization").
+		Where("token_hash = ?", tokenHash).
+		First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("failed to find invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+// FindPendingInvitations finds an organization's invitations that can still be accepted at
+// now, newest first
+func (r *organizationMemberRepository) FindPendingInvitations(
+	ctx context.Context,
+	organizationID uuid.UUID,
+	now time.Time,
+) ([]*models.OrganizationInvitation, error) {
+	var invitations []*models.OrganizationInvitation
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND accepted_at IS NULL AND expires_at > ?", organizationID, now).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find invitations: %w", err)
+	}
+
+	return invitations, nil
+}
+
+// MarkInvitationAccepted records that an invitation was accepted. It fails with
+// ErrInvalidInvitation if the invitation was already accepted.
+func (r *organizationMemberRepository) MarkInvitationAccepted(ctx context.Context, id uuid.UUID, acceptedAt time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.OrganizationInvitation{}).
+		Where("id = ? AND accepted_at IS NULL", id).
+		Update("accepted_at", acceptedAt)
+	if result.Error != nil {
+		return fmt.Errorf("failed to accept invitation: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ErrInvalidInvitation
+	}
+
+	return nil
+}
+
+// DeleteInvitation deletes one of an organization's invitations
+func (r *organizationMemberRepository) DeleteInvitation(ctx context.Context, organizationID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Where("organization_id = ? AND id = ?", organizationID, id).
+		Delete(&models.OrganizationInvitation{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete invitation: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ErrInvitationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupOrganizationMemberTestDB(t *testing.T) (*gorm.DB, *models.Organization) {
+	db, organization := setupOrganizationTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.OrganizationMember{}, &models.OrganizationInvitation{}))
+	return db, organization
+}
+
+func createMemberUser(t *testing.T, db *gorm.DB, email string) *models.User {
+	user := &models.User{Email: email, PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+func TestOrganizationMemberRepository_Members(t *testing.T) {
+	ctx := context.Background()
+	db, organization := setupOrganizationMemberTestDB(t)
+	repo := NewOrganizationMemberRepository(db)
+
+	jane := createMemberUser(t, db, "jane@example.com")
+	john := createMemberUser(t, db, "john@example.com")
+	joined := time.Now().UTC().Add(-time.Hour)
+	require.NoError(t, repo.Save(ctx, &models.OrganizationMember{
+		OrganizationID: organization.ID, UserID: jane.ID, Role: models.OrgRoleOwner, CreatedAt: joined,
+	}))
+	require.NoError(t, repo.Save(ctx, &models.OrganizationMember{
+		OrganizationID: organization.ID, UserID: john.ID, Role: models.OrgRoleViewer,
+	}))
+
+	member, err := repo.FindMember(ctx, organization.ID, john.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleViewer, member.Role)
+	require.NotNil(t, member.Organization)
+	assert.Equal(t, "Acme", member.Organization.Name)
+
+	// Saving an existing membership replaces its role
+	member.Role = models.OrgRoleAdmin
+	require.NoError(t, repo.Save(ctx, member))
+	member, err = repo.FindMember(ctx, organization.ID, john.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleAdmin, member.Role)
+
+	count, err := repo.CountMembers(ctx, organization.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	members, err := repo.FindByOrganizationID(ctx, organization.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, jane.ID, members[0].UserID)
+	require.NotNil(t, members[0].User)
+	assert.Equal(t, "jane@example.com", members[0].User.Email)
+
+	owners, err := repo.FindOwners(ctx, organization.ID)
+	require.NoError(t, err)
+	require.Len(t, owners, 1)
+	assert.Equal(t, jane.ID, owners[0].UserID)
+
+	memberships, err := repo.FindByUserID(ctx, john.ID)
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, organization.ID, memberships[0].Organization.ID)
+
+	require.NoError(t, repo.Delete(ctx, organization.ID, john.ID))
+	_, err = repo.FindMember(ctx, organization.ID, john.ID)
+	assert.Equal(t, models.ErrOrganizationMemberNotFound, err)
+	assert.Equal(t, models.ErrOrganizationMemberNotFound, repo.Delete(ctx, organization.ID, john.ID))
+}
+
+func TestOrganizationMemberRepository_Invitations(t *testing.T) {
+	ctx := context.Background()
+	db, organization := setupOrganizationMemberTestDB(t)
+	repo := NewOrganizationMemberRepository(db)
+	now := time.Now().UTC()
+
+	pending := &models.OrganizationInvitation{
+		OrganizationID: organization.ID,
+		Email:          "jane@example.com",
+		Role:           models.OrgRoleMember,
+		TokenHash:      "pending-hash",
+		ExpiresAt:      now.Add(time.Hour),
+	}
+	expired := &models.OrganizationInvitation{
+		OrganizationID: organization.ID,
+		Email:          "john@example.com",
+		Role:           models.OrgRoleViewer,
+		TokenHash:      "expired-hash",
+		ExpiresAt:      now.Add(-time.Hour),
+	}
+	require.NoError(t, repo.CreateInvitation(ctx, pending))
+	require.NoError(t, repo.CreateInvitation(ctx, expired))
+
+	found, err := repo.FindInvitationByTokenHash(ctx, "pending-hash")
+	require.NoError(t, err)
+	assert.Equal(t, pending.ID, found.ID)
+	require.NotNil(t, found.Organization)
+	assert.Equal(t, organization.ID, found.Organization.ID)
+
+	_, err = repo.FindInvitationByTokenHash(ctx, "missing")
+	assert.Equal(t, models.ErrInvitationNotFound, err)
+
+	invitations, err := repo.FindPendingInvitations(ctx, organization.ID, now)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	assert.Equal(t, pending.ID, invitations[0].ID)
+
+	// An invitation can only be accepted once
+	require.NoError(t, repo.MarkInvitationAccepted(ctx, pending.ID, now))
+	assert.Equal(t, models.ErrInvalidInvitation, repo.MarkInvitationAccepted(ctx, pending.ID, now))
+	invitations, err = repo.FindPendingInvitations(ctx, organization.ID, now)
+	require.NoError(t, err)
+	assert.Empty(t, invitations)
+
+	// Invitations are only found within their organization
+	_, err = repo.FindInvitation(ctx, uuid.New(), expired.ID)
+	assert.Equal(t, models.ErrInvitationNotFound, err)
+	assert.Equal(t, models.ErrInvitationNotFound, repo.DeleteInvitation(ctx, uuid.New(), expired.ID))
+	require.NoError(t, repo.DeleteInvitation(ctx, organization.ID, expired.ID))
+	_, err = repo.FindInvitation(ctx, organization.ID, expired.ID)
+	assert.Equal(t, models.ErrInvitationNotFound, err)
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// OrganizationRepository defines the interface for organization and organization member
+// data operations
+type OrganizationRepository interface {
+	FindByID(id string) (*models.Organization, error)
+	FindByExternalID(externalID string) (*models.Organization, error)
+	Save(organization *models.Organization) error
+	FindMember(organizationID uuid.UUID, externalID string) (*models.User, error)
+	CountMembers(organizationID uuid.UUID) (int64, error)
+	SaveMember(user *models.User) error
+	FindSchemaNames() ([]string, error)
+	FindSchemaNameByUserID(userID string) (string, error)
+}
+
+// organizationRepository implements OrganizationRepository interface
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRepository creates a new OrganizationRepository instance
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db: db}
+}
+
+// FindByID finds an organization by ID
+func (r *organizationRepository) FindByID(id string) (*models.Organization, error) {
+	if id == "" {
+		return nil, fmt.Errorf("organization ID cannot be empty")
+	}
+
+	organizationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid organization ID format: %w", err)
+	}
+
+	var organization models.Organization
+	if err := r.db.Where("id = ?", organizationID).First(&organization).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to find organization: %w", err)
+	}
+
+	return &organization, nil
+}
+
+// FindByExternalID finds an organization by the identifier its provisioning tool assigned
+func (r *organizationRepository) FindByExternalID(externalID string) (*models.Organization, error) {
+	if externalID == "" {
+		return nil, fmt.Errorf("external ID cannot be empty")
+	}
+
+	var organization models.Organization
+	if err := r.db.Where("external_id = ?", externalID).First(&organization).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("failed to find organization: %w", err)
+	}
+
+	return &organization, nil
+}
+
+// Save creates the organization, or updates it if it already exists
+func (r *organizationRepository) Save(organization *models.Organization) error {
+	if organization == nil {
+		return fmt.Errorf("organization cannot be nil")
+	}
+
+	if organization.ID != uuid.Nil {
+		organization.UpdatedAt = time.Now().UTC()
+	}
+
+	if err := r.db.Save(organization).Error; err != nil {
+		return fmt.Errorf("failed to save organization: %w", err)
+	}
+
+	return nil
+}
+
+// FindMember finds an organization member by the identifier its provisioning tool assigned
+func (r *organizationRepository) FindMember(organizationID uuid.UUID, externalID string) (*models.User, error) {
+	if externalID == "" {
+		return nil, fmt.Errorf("external ID cannot be empty")
+	}
+
+	var user models.User
+	err := r.db.Where("organization_id = ? AND external_id = ?", organizationID, externalID).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to find organization member: %w", err)
+	}
+
+	return &user, nil
+}
+
+// CountMembers counts the users provisioned into an organization
+func (r *organizationRepository) CountMembers(organizationID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.User{}).Where("organization_id = ?", organizationID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count organization members: %w", err)
+	}
+	return count, nil
+}
+
+// SaveMember creates an organization member, or updates it if it already exists
+func (r *organizationRepository) SaveMember(user *models.User) error {
+	if user == nil {
+		return fmt.Errorf("user cannot be nil")
+	}
+
+	if user.ID != uuid.Nil {
+		user.UpdatedAt = time.Now().UTC()
+	}
+
+	if err := r.db.Save(user).Error; err != nil {
+		return fmt.Errorf("failed to save organization member: %w", err)
+	}
+
+	return nil
+}
+
+// FindSchemaNames returns the Postgres schema of every organization that has its own
+func (r *organizationRepository) FindSchemaNames() ([]string, error) {
+	var schemas []string
+	if err := r.db.Model(&models.Organization{}).
+		Where("schema_name IS NOT NULL").
+		Order("schema_name").
+		Pluck("schema_name", &schemas).Error; err != nil {
+		return nil, fmt.Errorf("failed to list organization schemas: %w", err)
+	}
+	return schemas, nil
+}
+
+// FindSchemaNameByUserID returns the Postgres schema of the user's organization, or "" if the
+// user belongs to no organization or the organization uses the public schema
+func (r *organizationRepository) FindSchemaNameByUserID(userID string) (string, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return "", fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	var schemas []string
+	if err := r.db.Model(&models.Organization{}).
+		Joins("JOIN users ON users.organization_id = organizations.id").
+		Where("users.id = ? AND organizations.schema_name IS NOT NULL", uid).
+		Pluck("organizations.schema_name", &schemas).Error; err != nil {
+		return "", fmt.Errorf("failed to find organization schema: %w", err)
+	}
+	if len(schemas) == 0 {
+		return "", nil
+	}
+	return schemas[0], nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupOrganizationTestDB(t *testing.T) (*gorm.DB, *models.Organization) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Organization{}, &models.APIKey{}))
+
+	organization := &models.Organization{ExternalID: "acme", Name: "Acme"}
+	require.NoError(t, NewOrganizationRepository(db).Save(organization))
+
+	return db, organization
+}
+
+func newTestMember(organization *models.Organization, externalID, email string) *models.User {
+	organizationID := organization.ID
+	return &models.User{
+		Email:          email,
+		PasswordHash:   "hash",
+		OrganizationID: &organizationID,
+		ExternalID:     &externalID,
+	}
+}
+
+func TestOrganizationRepository_SaveAndFind(t *testing.T) {
+	db, organization := setupOrganizationTestDB(t)
+	repo := NewOrganizationRepository(db)
+
+	found, err := repo.FindByExternalID("acme")
+	require.NoError(t, err)
+	assert.Equal(t, organization.ID, found.ID)
+
+	found.Name = "Acme Corp"
+	found.MaxUsers = 5
+	require.NoError(t, repo.Save(found))
+
+	byID, err := repo.FindByID(organization.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Corp", byID.Name)
+	assert.Equal(t, 5, byID.MaxUsers)
+
+	_, err = repo.FindByExternalID("missing")
+	assert.Equal(t, models.ErrOrganizationNotFound, err)
+
+	// External IDs are unique
+	assert.Error(t, repo.Save(&models.Organization{ExternalID: "acme", Name: "Duplicate"}))
+}
+
+func TestOrganizationRepository_Members(t *testing.T) {
+	db, organization := setupOrganizationTestDB(t)
+	repo := NewOrganizationRepository(db)
+
+	require.NoError(t, repo.SaveMember(newTestMember(organization, "jane", "jane@acme.example")))
+	require.NoError(t, repo.SaveMember(newTestMember(organization, "john", "john@acme.example")))
+
+	// Users who signed up on their own do not count towards an organization
+	require.NoError(t, db.Create(&models.User{Email: "solo@example.com", PasswordHash: "hash"}).Error)
+
+	count, err := repo.CountMembers(organization.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	member, err := repo.FindMember(organization.ID, "jane")
+	require.NoError(t, err)
+	assert.Equal(t, "jane@acme.example", member.Email)
+
+	_, err = repo.FindMember(uuid.New(), "jane")
+	assert.Equal(t, models.ErrUserNotFound, err)
+
+	// External IDs are unique within an organization
+	assert.Error(t, repo.SaveMember(newTestMember(organization, "jane", "other@acme.example")))
+}
+
+func TestOrganizationRepository_SchemaNames(t *testing.T) {
+	db, organization := setupOrganizationTestDB(t)
+	repo := NewOrganizationRepository(db)
+
+	schema := models.TenantSchemaName(uuid.New())
+	tenant := &models.Organization{ExternalID: "globex", Name: "Globex", SchemaName: &schema}
+	require.NoError(t, repo.Save(tenant))
+
+	schemas, err := repo.FindSchemaNames()
+	require.NoError(t, err)
+	assert.Equal(t, []string{schema}, schemas)
+
+	member := newTestMember(tenant, "jane", "jane@globex.example")
+	require.NoError(t, repo.SaveMember(member))
+	found, err := repo.FindSchemaNameByUserID(member.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, schema, found)
+
+	// Members of organizations in the public schema and users without an organization
+	publicMember := newTestMember(organization, "john", "john@acme.example")
+	require.NoError(t, repo.SaveMember(publicMember))
+	found, err = repo.FindSchemaNameByUserID(publicMember.ID.String())
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	found, err = repo.FindSchemaNameByUserID(uuid.New().String())
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestAPIKeyRepository(t *testing.T) {
+	db, organization := setupOrganizationTestDB(t)
+	member := newTestMember(organization, "jane", "jane@acme.example")
+	require.NoError(t, NewOrganizationRepository(db).SaveMember(member))
+	repo := NewAPIKeyRepository(db)
+
+	key := &models.APIKey{
+		OrganizationID: organization.ID,
+		ExternalID:     "ci",
+		UserID:         member.ID,
+		Name:           "CI",
+		Prefix:         "pfk_abcdefgh",
+		KeyHash:        "hash-1",
+	}
+	require.NoError(t, repo.Save(key))
+
+	found, err := repo.FindByExternalID(organization.ID, "ci")
+	require.NoError(t, err)
+	require.NotNil(t, found.User)
+	assert.Equal(t, "jane", *found.User.ExternalID)
+
+	byHash, err := repo.FindByKeyHash("hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, byHash.ID)
+
+	_, err = repo.FindByKeyHash("hash-2")
+	assert.Equal(t, models.ErrAPIKeyNotFound, err)
+
+	count, err := repo.CountActive(organization.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	usedAt := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, repo.TouchLastUsed(key.ID, usedAt))
+
+	found, err = repo.FindByExternalID(organization.ID, "ci")
+	require.NoError(t, err)
+	require.NotNil(t, found.LastUsedAt)
+	assert.True(t, usedAt.Equal(*found.LastUsedAt))
+
+	revokedAt := time.Now().UTC()
+	found.RevokedAt = &revokedAt
+	require.NoError(t, repo.Save(found))
+
+	count, err = repo.CountActive(organization.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	reloaded, err := repo.FindByExternalID(organization.ID, "ci")
+	require.NoError(t, err)
+	assert.False(t, reloaded.IsActive())
+
+	byID, err := repo.FindByID(organization.ID, key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ci", byID.ExternalID)
+	_, err = repo.FindByID(uuid.New(), key.ID)
+	assert.Equal(t, models.ErrAPIKeyNotFound, err)
+
+	second := &models.APIKey{
+		OrganizationID: organization.ID,
+		ExternalID:     "deploy",
+		UserID:         member.ID,
+		Name:           "Deploy",
+		Prefix:         "pfk_ijklmnop",
+		KeyHash:        "hash-2",
+	}
+	require.NoError(t, repo.Save(second))
+	keys, err := repo.FindByOrganizationID(organization.ID)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+
+	// Revoking a user's keys leaves already revoked keys alone
+	revoked, err := repo.RevokeByUserID(organization.ID, member.ID, time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), revoked)
+	count, err = repo.CountActive(organization.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// PasswordHistoryRepository defines the interface for password history data operations
+type PasswordHistoryRepository interface {
+	Create(entry *models.PasswordHistory) error
+	FindRecentByUserID(userID string, limit int) ([]*models.PasswordHistory, error)
+	DeleteAllButRecent(userID string, keep int) error
+}
+
+// passwordHistoryRepository implements PasswordHistoryRepository interface
+type passwordHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordHistoryRepository creates a new PasswordHistoryRepository instance
+func NewPasswordHistoryRepository(db *gorm.DB) PasswordHistoryRepository {
+	return &passwordHistoryRepository{db: db}
+}
+
+// Create records a password a user has replaced
+func (r *passwordHistoryRepository) Create(entry *models.PasswordHistory) error {
+	if entry == nil {
+		return fmt.Errorf("entry cannot be nil")
+	}
+
+	if err := r.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to create password history entry: %w", err)
+	}
+
+	return nil
+}
+
+// FindRecentByUserID returns up to limit of a user's replaced passwords, newest first
+func (r *passwordHistoryRepository) FindRecentByUserID(userID string, limit int) ([]*models.PasswordHistory, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	var entries []*models.PasswordHistory
+	err := r.db.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find password history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// DeleteAllButRecent deletes a user's replaced passwords except the keep most recent ones
+func (r *passwordHistoryRepository) DeleteAllButRecent(userID string, keep int) error {
+	if userID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	recent := r.db.Model(&models.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Limit(keep)
+
+	err := r.db.Where("user_id = ? AND id NOT IN (?)", userID, recent).
+		Delete(&models.PasswordHistory{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete password history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestPasswordHistoryRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.PasswordHistory{}))
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hashedpassword"}
+	require.NoError(t, db.Create(user).Error)
+	other := &models.User{Email: "other@example.com", PasswordHash: "hashedpassword"}
+	require.NoError(t, db.Create(other).Error)
+
+	repo := NewPasswordHistoryRepository(db)
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, hash := range []string{"first", "second", "third"} {
+		require.NoError(t, repo.Create(&models.PasswordHistory{
+			UserID:       user.ID,
+			PasswordHash: hash,
+			CreatedAt:    created.Add(time.Duration(i) * time.Hour),
+		}))
+	}
+	require.NoError(t, repo.Create(&models.PasswordHistory{UserID: other.ID, PasswordHash: "other"}))
+
+	entries, err := repo.FindRecentByUserID(user.ID.String(), 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "third", entries[0].PasswordHash, "newest first")
+	assert.Equal(t, "second", entries[1].PasswordHash)
+
+	require.NoError(t, repo.DeleteAllButRecent(user.ID.String(), 1))
+
+	entries, err = repo.FindRecentByUserID(user.ID.String(), 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "third", entries[0].PasswordHash)
+
+	// Other users' history is untouched
+	entries, err = repo.FindRecentByUserID(other.ID.String(), 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
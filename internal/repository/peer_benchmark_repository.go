@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// PeerBenchmarkRepository defines the interface for anonymized peer benchmark data operations
+type PeerBenchmarkRepository interface {
+	ReplaceAll(ctx context.Context, benchmarks []*models.PeerBenchmark) error
+	FindByBucket(ctx context.Context, bucket models.AllocationBucket) (*models.PeerBenchmark, error)
+	FindAll(ctx context.Context) ([]*models.PeerBenchmark, error)
+}
+
+// peerBenchmarkRepository implements PeerBenchmarkRepository interface
+type peerBenchmarkRepository struct {
+	db *gorm.DB
+}
+
+// NewPeerBenchmarkRepository creates a new PeerBenchmarkRepository instance
+func NewPeerBenchmarkRepository(db *gorm.DB) PeerBenchmarkRepository {
+	return &peerBenchmarkRepository{db: db}
+}
+
+// ReplaceAll replaces the published benchmarks with a freshly computed set. Buckets missing
+// from the new set, for example because they fell below the minimum sample size, are
+// withdrawn rather than left stale.
+func (r *peerBenchmarkRepository) ReplaceAll(ctx context.Context, benchmarks []*models.PeerBenchmark) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.PeerBenchmark{}).Error; err != nil {
+			return fmt.Errorf("failed to delete peer benchmarks: %w", err)
+		}
+
+		if len(benchmarks) == 0 {
+			return nil
+		}
+
+		if err := tx.Create(benchmarks).Error; err != nil {
+			return fmt.Errorf("failed to create peer benchmarks: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// FindByBucket finds the published benchmark for an allocation bucket
+func (r *peerBenchmarkRepository) FindByBucket(ctx context.Context, bucket models.AllocationBucket) (*models.PeerBenchmark, error) {
+	var benchmark models.PeerBenchmark
+	if err := r.db.WithContext(ctx).Where("bucket = ?", bucket).First(&benchmark).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrPeerBenchmarkNotFound
+		}
+		return nil, fmt.Errorf("failed to find peer benchmark: %w", err)
+	}
+
+	return &benchmark, nil
+}
+
+// FindAll finds all published benchmarks
+func (r *peerBenchmarkRepository) FindAll(ctx context.Context) ([]*models.PeerBenchmark, error) {
+	var benchmarks []*models.PeerBenchmark
+	if err := r.db.WithContext(ctx).Order("bucket ASC").Find(&benchmarks).Error; err != nil {
+		return nil, fmt.Errorf("failed to find peer benchmarks: %w", err)
+	}
+
+	return benchmarks, nil
+}
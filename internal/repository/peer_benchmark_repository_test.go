@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupPeerBenchmarkTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.AutoMigrate(&models.PeerBenchmark{}))
+
+	return db
+}
+
+func newTestPeerBenchmark(bucket models.AllocationBucket, sampleSize int) *models.PeerBenchmark {
+	return &models.PeerBenchmark{
+		Bucket:     bucket,
+		SampleSize: sampleSize,
+		P10:        decimal.NewFromFloat(-0.5),
+		P25:        decimal.NewFromFloat(0.1),
+		P50:        decimal.NewFromFloat(0.6),
+		P75:        decimal.NewFromFloat(1.1),
+		P90:        decimal.NewFromFloat(1.6),
+		ComputedAt: time.Now().UTC(),
+	}
+}
+
+func TestPeerBenchmarkRepository_ReplaceAll(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPeerBenchmarkTestDB(t)
+	repo := NewPeerBenchmarkRepository(db)
+
+	require.NoError(t, repo.ReplaceAll(ctx, []*models.PeerBenchmark{
+		newTestPeerBenchmark(models.AllocationBucketDiversified, 12),
+		newTestPeerBenchmark(models.AllocationBucketFocused, 15),
+	}))
+
+	benchmarks, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, benchmarks, 2)
+
+	// A bucket missing from the new set is withdrawn
+	require.NoError(t, repo.ReplaceAll(ctx, []*models.PeerBenchmark{
+		newTestPeerBenchmark(models.AllocationBucketDiversified, 20),
+	}))
+
+	benchmarks, err = repo.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, benchmarks, 1)
+	assert.Equal(t, 20, benchmarks[0].SampleSize)
+
+	_, err = repo.FindByBucket(ctx, models.AllocationBucketFocused)
+	assert.ErrorIs(t, err, models.ErrPeerBenchmarkNotFound)
+
+	require.NoError(t, repo.ReplaceAll(ctx, nil))
+	benchmarks, err = repo.FindAll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, benchmarks)
+}
+
+func TestPeerBenchmarkRepository_FindByBucket(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPeerBenchmarkTestDB(t)
+	repo := NewPeerBenchmarkRepository(db)
+
+	require.NoError(t, repo.ReplaceAll(ctx, []*models.PeerBenchmark{
+		newTestPeerBenchmark(models.AllocationBucketConcentrated, 11),
+	}))
+
+	benchmark, err := repo.FindByBucket(ctx, models.AllocationBucketConcentrated)
+	require.NoError(t, err)
+	assert.Equal(t, 11, benchmark.SampleSize)
+	assert.True(t, benchmark.P50.Equal(decimal.NewFromFloat(0.6)))
+}
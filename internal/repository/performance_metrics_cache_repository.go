@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// PerformanceMetricsCacheRepository defines the interface for materialized performance
+// metrics data operations
+type PerformanceMetricsCacheRepository interface {
+	FindByPortfolioIDAndPeriod(ctx context.Context, portfolioID string, period models.PerformancePeriod) (*models.PerformanceMetricsCache, error)
+	Save(ctx context.Context, entry *models.PerformanceMetricsCache) error
+	SourceStamp(ctx context.Context, portfolioID string) (string, error)
+}
+
+// performanceMetricsCacheRepository implements PerformanceMetricsCacheRepository interface
+type performanceMetricsCacheRepository struct {
+	db *gorm.DB
+}
+
+// NewPerformanceMetricsCacheRepository creates a new PerformanceMetricsCacheRepository instance
+func NewPerformanceMetricsCacheRepository(db *gorm.DB) PerformanceMetricsCacheRepository {
+	return &performanceMetricsCacheRepository{db: db}
+}
+
+// FindByPortfolioIDAndPeriod finds a portfolio's materialized metrics over a period, returning
+// nil if none were materialized
+func (r *performanceMetricsCacheRepository) FindByPortfolioIDAndPeriod(
+	ctx context.Context,
+	portfolioID string,
+	period models.PerformancePeriod,
+) (*models.PerformanceMetricsCache, error) {
+	id, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var entry models.PerformanceMetricsCache
+	err = r.db.WithContext(ctx).Where("portfolio_id = ? AND period = ?", id, period).First(&entry).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find performance metrics: %w", err)
+	}
+
+	return &entry, nil
+}
+
+// Save stores a portfolio's metrics over a period, replacing those materialized before
+func (r *performanceMetricsCacheRepository) Save(ctx context.Context, entry *models.PerformanceMetricsCache) error {
+	if entry == nil {
+		return fmt.Errorf("entry cannot be nil")
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "portfolio_id"}, {Name: "period"}},
+		DoUpdates: clause.AssignmentColumns([]string{"start_date", "end_date", "source_stamp", "metrics", "computed_at"}),
+	}).Create(entry).Error
+	if err != nil {
+		return fmt.Errorf("failed to save performance metrics: %w", err)
+	}
+
+	return nil
+}
+
+// sourceState counts a table's rows for a portfolio and when they last changed
+type sourceState struct {
+	Count   int64
+	Changed string
+}
+
+// SourceStamp identifies the transactions and snapshots a portfolio's metrics are computed
+// from. It changes whenever one is added, updated or deleted.
+func (r *performanceMetricsCacheRepository) SourceStamp(ctx context.Context, portfolioID string) (string, error) {
+	id, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return "", fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var transactions, snapshots sourceState
+	err = r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select("COUNT(*) AS count, COALESCE(CAST(MAX(updated_at) AS TEXT), '') AS changed").
+		Where("portfolio_id = ?", id).
+		Scan(&transactions).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to stamp transactions: %w", err)
+	}
+	err = r.db.WithContext(ctx).Model(&models.PerformanceSnapshot{}).
+		Select("COUNT(*) AS count, COALESCE(CAST(MAX(created_at) AS TEXT), '') AS changed").
+		Where("portfolio_id = ?", id).
+		Scan(&snapshots).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to stamp snapshots: %w", err)
+	}
+
+	return fmt.Sprintf("%d|%s|%d|%s", transactions.Count, transactions.Changed, snapshots.Count, snapshots.Changed), nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestPerformanceMetricsCacheRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.PerformanceSnapshot{}, &models.PerformanceMetricsCache{},
+	))
+	repo := NewPerformanceMetricsCacheRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Growth", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+	portfolioID := portfolio.ID.String()
+
+	t.Run("nothing materialized", func(t *testing.T) {
+		entry, err := repo.FindByPortfolioIDAndPeriod(ctx, portfolioID, models.PerformancePeriod1Y)
+		require.NoError(t, err)
+		assert.Nil(t, entry)
+	})
+
+	t.Run("saving again replaces the entry", func(t *testing.T) {
+		computed := time.Date(2025, 5, 15, 2, 0, 0, 0, time.UTC)
+		entry := &models.PerformanceMetricsCache{
+			PortfolioID: portfolio.ID, Period: models.PerformancePeriod1Y,
+			StartDate: computed.AddDate(-1, 0, 0), EndDate: computed,
+			SourceStamp: "first", Metrics: `{"total_return":"1"}`, ComputedAt: computed,
+		}
+		require.NoError(t, repo.Save(ctx, entry))
+
+		replacement := *entry
+		replacement.ID = uuid.Nil
+		replacement.SourceStamp = "second"
+		replacement.Metrics = `{"total_return":"2"}`
+		replacement.ComputedAt = computed.Add(24 * time.Hour)
+		require.NoError(t, repo.Save(ctx, &replacement))
+
+		saved, err := repo.FindByPortfolioIDAndPeriod(ctx, portfolioID, models.PerformancePeriod1Y)
+		require.NoError(t, err)
+		require.NotNil(t, saved)
+		assert.Equal(t, entry.ID, saved.ID)
+		assert.Equal(t, "second", saved.SourceStamp)
+		assert.Equal(t, `{"total_return":"2"}`, saved.Metrics)
+
+		var count int64
+		require.NoError(t, db.Model(&models.PerformanceMetricsCache{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("the source stamp follows transactions and snapshots", func(t *testing.T) {
+		empty, err := repo.SourceStamp(ctx, portfolioID)
+		require.NoError(t, err)
+
+		price := decimal.NewFromInt(150)
+		transaction := &models.Transaction{
+			PortfolioID: portfolio.ID, Type: models.TransactionTypeBuy, Symbol: "AAPL", Date: time.Now(),
+			Quantity: decimal.NewFromInt(10), Price: &price, Currency: "USD",
+		}
+		require.NoError(t, db.Create(transaction).Error)
+		withTransaction, err := repo.SourceStamp(ctx, portfolioID)
+		require.NoError(t, err)
+		assert.NotEqual(t, empty, withTransaction)
+
+		require.NoError(t, db.Create(&models.PerformanceSnapshot{
+			PortfolioID: portfolio.ID, Date: time.Now(), TotalValue: decimal.NewFromInt(1500),
+			TotalCostBasis: decimal.NewFromInt(1500), TotalReturn: decimal.Zero, TotalReturnPct: decimal.Zero,
+		}).Error)
+		withSnapshot, err := repo.SourceStamp(ctx, portfolioID)
+		require.NoError(t, err)
+		assert.NotEqual(t, withTransaction, withSnapshot)
+
+		require.NoError(t, db.Delete(transaction).Error)
+		withoutTransaction, err := repo.SourceStamp(ctx, portfolioID)
+		require.NoError(t, err)
+		assert.NotEqual(t, withSnapshot, withoutTransaction)
+
+		again, err := repo.SourceStamp(ctx, portfolioID)
+		require.NoError(t, err)
+		assert.Equal(t, withoutTransaction, again)
+	})
+}
@@ -1,37 +1,43 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lenon/portfolios/internal/models"
 	"gorm.io/gorm"
 )
 
 // PerformanceSnapshotRepository defines the interface for performance snapshot data operations
 type PerformanceSnapshotRepository interface {
-	Create(snapshot *models.PerformanceSnapshot) error
-	FindByID(id string) (*models.PerformanceSnapshot, error)
-	FindByPortfolioID(portfolioID string, limit, offset int) ([]*models.PerformanceSnapshot, error)
-	FindByPortfolioIDAndDateRange(portfolioID string, startDate, endDate time.Time) ([]*models.PerformanceSnapshot, error)
-	FindLatestByPortfolioID(portfolioID string) (*models.PerformanceSnapshot, error)
-	FindByPortfolioIDAndDate(portfolioID string, date time.Time) (*models.PerformanceSnapshot, error)
-	Delete(id string) error
-	DeleteByPortfolioID(portfolioID string) error
+	Create(ctx context.Context, snapshot *models.PerformanceSnapshot) error
+	FindByID(ctx context.Context, id string) (*models.PerformanceSnapshot, error)
+	FindByPortfolioID(ctx context.Context, portfolioID string, limit, offset int) ([]*models.PerformanceSnapshot, error)
+	FindByPortfolioIDAndDateRange(ctx context.Context, portfolioID string, startDate, endDate time.Time) ([]*models.PerformanceSnapshot, error)
+	FindLatestByPortfolioID(ctx context.Context, portfolioID string) (*models.PerformanceSnapshot, error)
+	FindByPortfolioIDAndDate(ctx context.Context, portfolioID string, date time.Time) (*models.PerformanceSnapshot, error)
+	IterateByPortfolioID(ctx context.Context, portfolioID string, fn func([]*models.PerformanceSnapshot) error) error
+	Delete(ctx context.Context, id string) error
+	DeleteByPortfolioID(ctx context.Context, portfolioID string) error
+	DeleteByIDs(ctx context.Context, ids []uuid.UUID) (int64, error)
 }
 
 // performanceSnapshotRepository implements PerformanceSnapshotRepository interface
 type performanceSnapshotRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	batchSize int
 }
 
 // NewPerformanceSnapshotRepository creates a new PerformanceSnapshotRepository instance
 func NewPerformanceSnapshotRepository(db *gorm.DB) PerformanceSnapshotRepository {
-	return &performanceSnapshotRepository{db: db}
+	return &performanceSnapshotRepository{db: db, batchSize: iterateBatchSize}
 }
 
 // Create creates a new performance snapshot
-func (r *performanceSnapshotRepository) Create(snapshot *models.PerformanceSnapshot) error {
+func (r *performanceSnapshotRepository) Create(ctx context.Context, snapshot *models.PerformanceSnapshot) error {
 	if snapshot == nil {
 		return fmt.Errorf("snapshot cannot be nil")
 	}
@@ -40,17 +46,24 @@ func (r *performanceSnapshotRepository) Create(snapshot *models.PerformanceSnaps
 		return err
 	}
 
-	return r.db.Create(snapshot).Error
+	if err := r.db.WithContext(ctx).Create(snapshot).Error; err != nil {
+		if isUniqueViolation(r.db, err) {
+			return models.ErrDuplicateSnapshot
+		}
+		return err
+	}
+
+	return nil
 }
 
 // FindByID finds a performance snapshot by ID
-func (r *performanceSnapshotRepository) FindByID(id string) (*models.PerformanceSnapshot, error) {
+func (r *performanceSnapshotRepository) FindByID(ctx context.Context, id string) (*models.PerformanceSnapshot, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id cannot be empty")
 	}
 
 	var snapshot models.PerformanceSnapshot
-	if err := r.db.Where("id = ?", id).First(&snapshot).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&snapshot).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrPerformanceSnapshotNotFound
 		}
@@ -61,13 +74,13 @@ func (r *performanceSnapshotRepository) FindByID(id string) (*models.Performance
 }
 
 // FindByPortfolioID finds all performance snapshots for a portfolio, ordered by date descending
-func (r *performanceSnapshotRepository) FindByPortfolioID(portfolioID string, limit, offset int) ([]*models.PerformanceSnapshot, error) {
+func (r *performanceSnapshotRepository) FindByPortfolioID(ctx context.Context, portfolioID string, limit, offset int) ([]*models.PerformanceSnapshot, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
 
 	var snapshots []*models.PerformanceSnapshot
-	query := r.db.Where("portfolio_id = ?", portfolioID).
+	query := r.db.WithContext(ctx).Where("portfolio_id = ?", portfolioID).
 		Order("date DESC")
 
 	if limit > 0 {
@@ -86,6 +99,7 @@ func (r *performanceSnapshotRepository) FindByPortfolioID(portfolioID string, li
 
 // FindByPortfolioIDAndDateRange finds performance snapshots for a portfolio within a date range
 func (r *performanceSnapshotRepository) FindByPortfolioIDAndDateRange(
+	ctx context.Context,
 	portfolioID string,
 	startDate, endDate time.Time,
 ) ([]*models.PerformanceSnapshot, error) {
@@ -94,7 +108,7 @@ func (r *performanceSnapshotRepository) FindByPortfolioIDAndDateRange(
 	}
 
 	var snapshots []*models.PerformanceSnapshot
-	if err := r.db.Where("portfolio_id = ? AND date >= ? AND date <= ?", portfolioID, startDate, endDate).
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ? AND date >= ? AND date <= ?", portfolioID, startDate, endDate).
 		Order("date ASC").
 		Find(&snapshots).Error; err != nil {
 		return nil, err
@@ -104,13 +118,13 @@ func (r *performanceSnapshotRepository) FindByPortfolioIDAndDateRange(
 }
 
 // FindLatestByPortfolioID finds the most recent performance snapshot for a portfolio
-func (r *performanceSnapshotRepository) FindLatestByPortfolioID(portfolioID string) (*models.PerformanceSnapshot, error) {
+func (r *performanceSnapshotRepository) FindLatestByPortfolioID(ctx context.Context, portfolioID string) (*models.PerformanceSnapshot, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
 
 	var snapshot models.PerformanceSnapshot
-	if err := r.db.Where("portfolio_id = ?", portfolioID).
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ?", portfolioID).
 		Order("date DESC").
 		First(&snapshot).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -123,7 +137,7 @@ func (r *performanceSnapshotRepository) FindLatestByPortfolioID(portfolioID stri
 }
 
 // FindByPortfolioIDAndDate finds a performance snapshot for a specific portfolio and date
-func (r *performanceSnapshotRepository) FindByPortfolioIDAndDate(portfolioID string, date time.Time) (*models.PerformanceSnapshot, error) {
+func (r *performanceSnapshotRepository) FindByPortfolioIDAndDate(ctx context.Context, portfolioID string, date time.Time) (*models.PerformanceSnapshot, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -132,7 +146,7 @@ func (r *performanceSnapshotRepository) FindByPortfolioIDAndDate(portfolioID str
 	// Normalize date to start of day for comparison
 	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
 
-	if err := r.db.Where("portfolio_id = ? AND DATE(date) = DATE(?)", portfolioID, normalizedDate).
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ? AND DATE(date) = DATE(?)", portfolioID, normalizedDate).
 		First(&snapshot).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrPerformanceSnapshotNotFound
@@ -143,13 +157,53 @@ func (r *performanceSnapshotRepository) FindByPortfolioIDAndDate(portfolioID str
 	return &snapshot, nil
 }
 
+// IterateByPortfolioID passes a portfolio's performance snapshots to fn in batches, oldest
+// first, so its whole history never has to be held in memory. Each batch seeks past the last
+// snapshot of the one before. It stops at the first error fn returns, returning nil if that
+// is ErrStopIteration.
+func (r *performanceSnapshotRepository) IterateByPortfolioID(
+	ctx context.Context,
+	portfolioID string,
+	fn func([]*models.PerformanceSnapshot) error,
+) error {
+	if portfolioID == "" {
+		return fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	var last *models.PerformanceSnapshot
+	for {
+		query := r.db.WithContext(ctx).Where("portfolio_id = ?", portfolioID)
+		if last != nil {
+			query = query.Where("date > ? OR (date = ? AND id > ?)", last.Date, last.Date, last.ID)
+		}
+
+		var batch []*models.PerformanceSnapshot
+		if err := query.Order("date ASC, id ASC").Limit(r.batchSize).Find(&batch).Error; err != nil {
+			return fmt.Errorf("failed to find performance snapshots: %w", err)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+		if len(batch) < r.batchSize {
+			return nil
+		}
+		last = batch[len(batch)-1]
+	}
+}
+
 // Delete deletes a performance snapshot by ID
-func (r *performanceSnapshotRepository) Delete(id string) error {
+func (r *performanceSnapshotRepository) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("id cannot be empty")
 	}
 
-	result := r.db.Where("id = ?", id).Delete(&models.PerformanceSnapshot{})
+	result := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.PerformanceSnapshot{})
 	if result.Error != nil {
 		return result.Error
 	}
@@ -162,10 +216,24 @@ func (r *performanceSnapshotRepository) Delete(id string) error {
 }
 
 // DeleteByPortfolioID deletes all performance snapshots for a portfolio
-func (r *performanceSnapshotRepository) DeleteByPortfolioID(portfolioID string) error {
+func (r *performanceSnapshotRepository) DeleteByPortfolioID(ctx context.Context, portfolioID string) error {
 	if portfolioID == "" {
 		return fmt.Errorf("portfolio ID cannot be empty")
 	}
 
-	return r.db.Where("portfolio_id = ?", portfolioID).Delete(&models.PerformanceSnapshot{}).Error
+	return r.db.WithContext(ctx).Where("portfolio_id = ?", portfolioID).Delete(&models.PerformanceSnapshot{}).Error
+}
+
+// DeleteByIDs deletes the given performance snapshots and returns how many were deleted
+func (r *performanceSnapshotRepository) DeleteByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&models.PerformanceSnapshot{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete performance snapshots: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
 }
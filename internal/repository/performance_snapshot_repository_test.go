@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -23,6 +24,8 @@ func setupPerformanceSnapshotTestDB(t *testing.T) *gorm.DB {
 }
 
 func TestPerformanceSnapshotRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPerformanceSnapshotTestDB(t)
 	repo := NewPerformanceSnapshotRepository(db)
 
@@ -50,20 +53,50 @@ func TestPerformanceSnapshotRepository_Create(t *testing.T) {
 		TotalReturnPct: decimal.NewFromFloat(25.0),
 	}
 
-	err := repo.Create(snapshot)
+	err := repo.Create(ctx, snapshot)
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, snapshot.ID)
 }
 
+func TestPerformanceSnapshotRepository_Create_SameDay(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPerformanceSnapshotTestDB(t)
+	// The per-day unique index from the migrations, which AutoMigrate can't express
+	assert.NoError(t, db.Exec("CREATE UNIQUE INDEX idx_performance_snapshots_portfolio_day ON performance_snapshots(portfolio_id, date(date))").Error)
+	repo := NewPerformanceSnapshotRepository(db)
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	assert.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Test Portfolio", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	assert.NoError(t, db.Create(portfolio).Error)
+
+	snapshotAt := func(date time.Time) *models.PerformanceSnapshot {
+		return &models.PerformanceSnapshot{
+			PortfolioID: portfolio.ID, Date: date, TotalValue: decimal.NewFromInt(10000),
+			TotalCostBasis: decimal.NewFromInt(8000), TotalReturn: decimal.NewFromInt(2000), TotalReturnPct: decimal.NewFromFloat(25.0),
+		}
+	}
+	morning := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, repo.Create(ctx, snapshotAt(morning)))
+	assert.ErrorIs(t, repo.Create(ctx, snapshotAt(morning.Add(12*time.Hour))), models.ErrDuplicateSnapshot)
+	assert.NoError(t, repo.Create(ctx, snapshotAt(morning.Add(24*time.Hour))))
+}
+
 func TestPerformanceSnapshotRepository_Create_Nil(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPerformanceSnapshotTestDB(t)
 	repo := NewPerformanceSnapshotRepository(db)
 
-	err := repo.Create(nil)
+	err := repo.Create(ctx, nil)
 	assert.Error(t, err)
 }
 
 func TestPerformanceSnapshotRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPerformanceSnapshotTestDB(t)
 	repo := NewPerformanceSnapshotRepository(db)
 
@@ -90,27 +123,29 @@ func TestPerformanceSnapshotRepository_FindByID(t *testing.T) {
 		TotalReturn:    decimal.NewFromInt(2000),
 		TotalReturnPct: decimal.NewFromFloat(25.0),
 	}
-	assert.NoError(t, repo.Create(snapshot))
+	assert.NoError(t, repo.Create(ctx, snapshot))
 
 	t.Run("found", func(t *testing.T) {
-		found, err := repo.FindByID(snapshot.ID.String())
+		found, err := repo.FindByID(ctx, snapshot.ID.String())
 		assert.NoError(t, err)
 		assert.Equal(t, snapshot.ID, found.ID)
 		assert.True(t, snapshot.TotalValue.Equal(found.TotalValue))
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		_, err := repo.FindByID(uuid.New().String())
+		_, err := repo.FindByID(ctx, uuid.New().String())
 		assert.Equal(t, models.ErrPerformanceSnapshotNotFound, err)
 	})
 
 	t.Run("empty ID", func(t *testing.T) {
-		_, err := repo.FindByID("")
+		_, err := repo.FindByID(ctx, "")
 		assert.Error(t, err)
 	})
 }
 
 func TestPerformanceSnapshotRepository_FindByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPerformanceSnapshotTestDB(t)
 	repo := NewPerformanceSnapshotRepository(db)
 
@@ -140,11 +175,11 @@ func TestPerformanceSnapshotRepository_FindByPortfolioID(t *testing.T) {
 			TotalReturn:    decimal.NewFromInt(2000 + int64(i*100)),
 			TotalReturnPct: decimal.NewFromFloat(25.0),
 		}
-		assert.NoError(t, repo.Create(snapshot))
+		assert.NoError(t, repo.Create(ctx, snapshot))
 	}
 
 	t.Run("find all", func(t *testing.T) {
-		snapshots, err := repo.FindByPortfolioID(portfolio.ID.String(), 0, 0)
+		snapshots, err := repo.FindByPortfolioID(ctx, portfolio.ID.String(), 0, 0)
 		assert.NoError(t, err)
 		assert.Len(t, snapshots, 5)
 		// Should be ordered by date DESC
@@ -152,24 +187,26 @@ func TestPerformanceSnapshotRepository_FindByPortfolioID(t *testing.T) {
 	})
 
 	t.Run("with limit", func(t *testing.T) {
-		snapshots, err := repo.FindByPortfolioID(portfolio.ID.String(), 2, 0)
+		snapshots, err := repo.FindByPortfolioID(ctx, portfolio.ID.String(), 2, 0)
 		assert.NoError(t, err)
 		assert.Len(t, snapshots, 2)
 	})
 
 	t.Run("with offset", func(t *testing.T) {
-		snapshots, err := repo.FindByPortfolioID(portfolio.ID.String(), 2, 2)
+		snapshots, err := repo.FindByPortfolioID(ctx, portfolio.ID.String(), 2, 2)
 		assert.NoError(t, err)
 		assert.Len(t, snapshots, 2)
 	})
 
 	t.Run("empty portfolio ID", func(t *testing.T) {
-		_, err := repo.FindByPortfolioID("", 0, 0)
+		_, err := repo.FindByPortfolioID(ctx, "", 0, 0)
 		assert.Error(t, err)
 	})
 }
 
 func TestPerformanceSnapshotRepository_FindByPortfolioIDAndDateRange(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPerformanceSnapshotTestDB(t)
 	repo := NewPerformanceSnapshotRepository(db)
 
@@ -199,13 +236,13 @@ func TestPerformanceSnapshotRepository_FindByPortfolioIDAndDateRange(t *testing.
 			TotalReturn:    decimal.NewFromInt(2000 + int64(i*100)),
 			TotalReturnPct: decimal.NewFromFloat(25.0),
 		}
-		assert.NoError(t, repo.Create(snapshot))
+		assert.NoError(t, repo.Create(ctx, snapshot))
 	}
 
 	startDate := now.Add(-5 * 24 * time.Hour)
 	endDate := now
 
-	snapshots, err := repo.FindByPortfolioIDAndDateRange(portfolio.ID.String(), startDate, endDate)
+	snapshots, err := repo.FindByPortfolioIDAndDateRange(ctx, portfolio.ID.String(), startDate, endDate)
 	assert.NoError(t, err)
 	assert.LessOrEqual(t, len(snapshots), 6) // Should have at most 6 snapshots (days 0-5)
 	// Should be ordered by date ASC
@@ -214,7 +251,68 @@ func TestPerformanceSnapshotRepository_FindByPortfolioIDAndDateRange(t *testing.
 	}
 }
 
+func TestPerformanceSnapshotRepository_IterateByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPerformanceSnapshotTestDB(t)
+	repo := &performanceSnapshotRepository{db: db, batchSize: 3}
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	assert.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Test Portfolio", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	assert.NoError(t, db.Create(portfolio).Error)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 6; i >= 0; i-- {
+		assert.NoError(t, repo.Create(ctx, &models.PerformanceSnapshot{
+			PortfolioID:    portfolio.ID,
+			Date:           start.AddDate(0, 0, i),
+			TotalValue:     decimal.NewFromInt(10000),
+			TotalCostBasis: decimal.NewFromInt(8000),
+		}))
+	}
+
+	t.Run("every snapshot oldest first in batches", func(t *testing.T) {
+		var batches int
+		var dates []time.Time
+		err := repo.IterateByPortfolioID(ctx, portfolio.ID.String(), func(batch []*models.PerformanceSnapshot) error {
+			batches++
+			assert.LessOrEqual(t, len(batch), 3)
+			for _, snapshot := range batch {
+				dates = append(dates, snapshot.Date)
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, batches)
+		if assert.Len(t, dates, 7) {
+			for i, date := range dates {
+				assert.True(t, start.AddDate(0, 0, i).Equal(date))
+			}
+		}
+	})
+
+	t.Run("stopping early", func(t *testing.T) {
+		var batches int
+		err := repo.IterateByPortfolioID(ctx, portfolio.ID.String(), func([]*models.PerformanceSnapshot) error {
+			batches++
+			return ErrStopIteration
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, batches)
+	})
+
+	t.Run("callback errors are returned", func(t *testing.T) {
+		err := repo.IterateByPortfolioID(ctx, portfolio.ID.String(), func([]*models.PerformanceSnapshot) error {
+			return context.Canceled
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
 func TestPerformanceSnapshotRepository_FindLatestByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPerformanceSnapshotTestDB(t)
 	repo := NewPerformanceSnapshotRepository(db)
 
@@ -245,30 +343,32 @@ func TestPerformanceSnapshotRepository_FindLatestByPortfolioID(t *testing.T) {
 			TotalReturn:    decimal.NewFromInt(2000 + int64(i*100)),
 			TotalReturnPct: decimal.NewFromFloat(25.0),
 		}
-		assert.NoError(t, repo.Create(snapshot))
+		assert.NoError(t, repo.Create(ctx, snapshot))
 		if i == 0 {
 			latestSnapshot = snapshot
 		}
 	}
 
 	t.Run("found", func(t *testing.T) {
-		found, err := repo.FindLatestByPortfolioID(portfolio.ID.String())
+		found, err := repo.FindLatestByPortfolioID(ctx, portfolio.ID.String())
 		assert.NoError(t, err)
 		assert.Equal(t, latestSnapshot.ID, found.ID)
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		_, err := repo.FindLatestByPortfolioID(uuid.New().String())
+		_, err := repo.FindLatestByPortfolioID(ctx, uuid.New().String())
 		assert.Equal(t, models.ErrPerformanceSnapshotNotFound, err)
 	})
 
 	t.Run("empty portfolio ID", func(t *testing.T) {
-		_, err := repo.FindLatestByPortfolioID("")
+		_, err := repo.FindLatestByPortfolioID(ctx, "")
 		assert.Error(t, err)
 	})
 }
 
 func TestPerformanceSnapshotRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPerformanceSnapshotTestDB(t)
 	repo := NewPerformanceSnapshotRepository(db)
 
@@ -295,29 +395,31 @@ func TestPerformanceSnapshotRepository_Delete(t *testing.T) {
 		TotalReturn:    decimal.NewFromInt(2000),
 		TotalReturnPct: decimal.NewFromFloat(25.0),
 	}
-	assert.NoError(t, repo.Create(snapshot))
+	assert.NoError(t, repo.Create(ctx, snapshot))
 
 	t.Run("successful delete", func(t *testing.T) {
-		err := repo.Delete(snapshot.ID.String())
+		err := repo.Delete(ctx, snapshot.ID.String())
 		assert.NoError(t, err)
 
 		// Verify it's deleted
-		_, err = repo.FindByID(snapshot.ID.String())
+		_, err = repo.FindByID(ctx, snapshot.ID.String())
 		assert.Equal(t, models.ErrPerformanceSnapshotNotFound, err)
 	})
 
 	t.Run("not found", func(t *testing.T) {
-		err := repo.Delete(uuid.New().String())
+		err := repo.Delete(ctx, uuid.New().String())
 		assert.Equal(t, models.ErrPerformanceSnapshotNotFound, err)
 	})
 
 	t.Run("empty ID", func(t *testing.T) {
-		err := repo.Delete("")
+		err := repo.Delete(ctx, "")
 		assert.Error(t, err)
 	})
 }
 
 func TestPerformanceSnapshotRepository_DeleteByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPerformanceSnapshotTestDB(t)
 	repo := NewPerformanceSnapshotRepository(db)
 
@@ -346,14 +448,56 @@ func TestPerformanceSnapshotRepository_DeleteByPortfolioID(t *testing.T) {
 			TotalReturn:    decimal.NewFromInt(2000 + int64(i*100)),
 			TotalReturnPct: decimal.NewFromFloat(25.0),
 		}
-		assert.NoError(t, repo.Create(snapshot))
+		assert.NoError(t, repo.Create(ctx, snapshot))
 	}
 
-	err := repo.DeleteByPortfolioID(portfolio.ID.String())
+	err := repo.DeleteByPortfolioID(ctx, portfolio.ID.String())
 	assert.NoError(t, err)
 
 	// Verify all deleted
-	snapshots, err := repo.FindByPortfolioID(portfolio.ID.String(), 0, 0)
+	snapshots, err := repo.FindByPortfolioID(ctx, portfolio.ID.String(), 0, 0)
 	assert.NoError(t, err)
 	assert.Len(t, snapshots, 0)
 }
+
+func TestPerformanceSnapshotRepository_DeleteByIDs(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPerformanceSnapshotTestDB(t)
+	repo := NewPerformanceSnapshotRepository(db)
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	assert.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Test Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	assert.NoError(t, db.Create(portfolio).Error)
+
+	var ids []uuid.UUID
+	for i := 0; i < 3; i++ {
+		snapshot := &models.PerformanceSnapshot{
+			PortfolioID:    portfolio.ID,
+			Date:           time.Now().UTC().AddDate(0, 0, -i),
+			TotalValue:     decimal.NewFromInt(10000),
+			TotalCostBasis: decimal.NewFromInt(8000),
+		}
+		assert.NoError(t, repo.Create(ctx, snapshot))
+		ids = append(ids, snapshot.ID)
+	}
+
+	deleted, err := repo.DeleteByIDs(ctx, ids[:2])
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	deleted, err = repo.DeleteByIDs(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), deleted)
+
+	snapshots, err := repo.FindByPortfolioID(ctx, portfolio.ID.String(), 0, 0)
+	assert.NoError(t, err)
+	assert.Len(t, snapshots, 1)
+	assert.Equal(t, ids[2], snapshots[0].ID)
+}
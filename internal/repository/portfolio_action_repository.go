@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,15 +13,15 @@ import (
 
 // PortfolioActionRepository defines the interface for portfolio action data operations
 type PortfolioActionRepository interface {
-	Create(action *models.PortfolioAction) error
-	FindByID(id string) (*models.PortfolioAction, error)
-	FindByPortfolioID(portfolioID string) ([]*models.PortfolioAction, error)
-	FindPendingByPortfolioID(portfolioID string) ([]*models.PortfolioAction, error)
-	FindByPortfolioIDAndStatus(portfolioID string, status models.PortfolioActionStatus) ([]*models.PortfolioAction, error)
-	FindPendingByCorporateActionID(corporateActionID string) ([]*models.PortfolioAction, error)
-	Update(action *models.PortfolioAction) error
-	Delete(id string) error
-	ExistsPendingForPortfolioAndAction(portfolioID, corporateActionID string) (bool, error)
+	Create(ctx context.Context, action *models.PortfolioAction) error
+	FindByID(ctx context.Context, id string) (*models.PortfolioAction, error)
+	FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.PortfolioAction, error)
+	FindPendingByPortfolioID(ctx context.Context, portfolioID string) ([]*models.PortfolioAction, error)
+	FindByPortfolioIDAndStatus(ctx context.Context, portfolioID string, status models.PortfolioActionStatus) ([]*models.PortfolioAction, error)
+	FindPendingByCorporateActionID(ctx context.Context, corporateActionID string) ([]*models.PortfolioAction, error)
+	Update(ctx context.Context, action *models.PortfolioAction) error
+	Delete(ctx context.Context, id string) error
+	ExistsPendingForPortfolioAndAction(ctx context.Context, portfolioID, corporateActionID string) (bool, error)
 }
 
 // portfolioActionRepository implements PortfolioActionRepository interface
@@ -34,12 +35,12 @@ func NewPortfolioActionRepository(db *gorm.DB) PortfolioActionRepository {
 }
 
 // Create creates a new portfolio action in the database
-func (r *portfolioActionRepository) Create(action *models.PortfolioAction) error {
+func (r *portfolioActionRepository) Create(ctx context.Context, action *models.PortfolioAction) error {
 	if action == nil {
 		return fmt.Errorf("portfolio action cannot be nil")
 	}
 
-	if err := r.db.Create(action).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(action).Error; err != nil {
 		return fmt.Errorf("failed to create portfolio action: %w", err)
 	}
 
@@ -47,7 +48,7 @@ func (r *portfolioActionRepository) Create(action *models.PortfolioAction) error
 }
 
 // FindByID finds a portfolio action by ID with preloaded relationships
-func (r *portfolioActionRepository) FindByID(id string) (*models.PortfolioAction, error) {
+func (r *portfolioActionRepository) FindByID(ctx context.Context, id string) (*models.PortfolioAction, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id cannot be empty")
 	}
@@ -58,7 +59,7 @@ func (r *portfolioActionRepository) FindByID(id string) (*models.PortfolioAction
 	}
 
 	var action models.PortfolioAction
-	if err := r.db.Preload("Portfolio").
+	if err := r.db.WithContext(ctx).Preload("Portfolio").
 		Preload("CorporateAction").
 		Where("id = ?", actionID).
 		First(&action).Error; err != nil {
@@ -72,7 +73,7 @@ func (r *portfolioActionRepository) FindByID(id string) (*models.PortfolioAction
 }
 
 // FindByPortfolioID finds all portfolio actions for a portfolio
-func (r *portfolioActionRepository) FindByPortfolioID(portfolioID string) ([]*models.PortfolioAction, error) {
+func (r *portfolioActionRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.PortfolioAction, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -83,7 +84,7 @@ func (r *portfolioActionRepository) FindByPortfolioID(portfolioID string) ([]*mo
 	}
 
 	var actions []*models.PortfolioAction
-	if err := r.db.Preload("CorporateAction").
+	if err := r.db.WithContext(ctx).Preload("CorporateAction").
 		Where("portfolio_id = ?", pid).
 		Order("detected_at DESC").
 		Find(&actions).Error; err != nil {
@@ -94,12 +95,13 @@ func (r *portfolioActionRepository) FindByPortfolioID(portfolioID string) ([]*mo
 }
 
 // FindPendingByPortfolioID finds all pending portfolio actions for a portfolio
-func (r *portfolioActionRepository) FindPendingByPortfolioID(portfolioID string) ([]*models.PortfolioAction, error) {
-	return r.FindByPortfolioIDAndStatus(portfolioID, models.PortfolioActionStatusPending)
+func (r *portfolioActionRepository) FindPendingByPortfolioID(ctx context.Context, portfolioID string) ([]*models.PortfolioAction, error) {
+	return r.FindByPortfolioIDAndStatus(ctx, portfolioID, models.PortfolioActionStatusPending)
 }
 
 // FindByPortfolioIDAndStatus finds portfolio actions by portfolio ID and status
 func (r *portfolioActionRepository) FindByPortfolioIDAndStatus(
+	ctx context.Context,
 	portfolioID string,
 	status models.PortfolioActionStatus,
 ) ([]*models.PortfolioAction, error) {
@@ -113,7 +115,7 @@ func (r *portfolioActionRepository) FindByPortfolioIDAndStatus(
 	}
 
 	var actions []*models.PortfolioAction
-	if err := r.db.Preload("CorporateAction").
+	if err := r.db.WithContext(ctx).Preload("CorporateAction").
 		Where("portfolio_id = ? AND status = ?", pid, status).
 		Order("detected_at DESC").
 		Find(&actions).Error; err != nil {
@@ -124,7 +126,7 @@ func (r *portfolioActionRepository) FindByPortfolioIDAndStatus(
 }
 
 // FindPendingByCorporateActionID finds all pending portfolio actions for a corporate action
-func (r *portfolioActionRepository) FindPendingByCorporateActionID(corporateActionID string) ([]*models.PortfolioAction, error) {
+func (r *portfolioActionRepository) FindPendingByCorporateActionID(ctx context.Context, corporateActionID string) ([]*models.PortfolioAction, error) {
 	if corporateActionID == "" {
 		return nil, fmt.Errorf("corporate action ID cannot be empty")
 	}
@@ -135,7 +137,7 @@ func (r *portfolioActionRepository) FindPendingByCorporateActionID(corporateActi
 	}
 
 	var actions []*models.PortfolioAction
-	if err := r.db.Preload("Portfolio").
+	if err := r.db.WithContext(ctx).Preload("Portfolio").
 		Where("corporate_action_id = ? AND status = ?", caid, models.PortfolioActionStatusPending).
 		Find(&actions).Error; err != nil {
 		return nil, fmt.Errorf("failed to find portfolio actions: %w", err)
@@ -145,14 +147,14 @@ func (r *portfolioActionRepository) FindPendingByCorporateActionID(corporateActi
 }
 
 // Update updates an existing portfolio action
-func (r *portfolioActionRepository) Update(action *models.PortfolioAction) error {
+func (r *portfolioActionRepository) Update(ctx context.Context, action *models.PortfolioAction) error {
 	if action == nil {
 		return fmt.Errorf("portfolio action cannot be nil")
 	}
 
 	action.UpdatedAt = time.Now().UTC()
 
-	if err := r.db.Save(action).Error; err != nil {
+	if err := r.db.WithContext(ctx).Save(action).Error; err != nil {
 		return fmt.Errorf("failed to update portfolio action: %w", err)
 	}
 
@@ -160,7 +162,7 @@ func (r *portfolioActionRepository) Update(action *models.PortfolioAction) error
 }
 
 // Delete deletes a portfolio action by ID
-func (r *portfolioActionRepository) Delete(id string) error {
+func (r *portfolioActionRepository) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("id cannot be empty")
 	}
@@ -170,7 +172,7 @@ func (r *portfolioActionRepository) Delete(id string) error {
 		return fmt.Errorf("invalid portfolio action ID format: %w", err)
 	}
 
-	result := r.db.Where("id = ?", actionID).Delete(&models.PortfolioAction{})
+	result := r.db.WithContext(ctx).Where("id = ?", actionID).Delete(&models.PortfolioAction{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete portfolio action: %w", result.Error)
 	}
@@ -184,6 +186,7 @@ func (r *portfolioActionRepository) Delete(id string) error {
 
 // ExistsPendingForPortfolioAndAction checks if a pending action already exists
 func (r *portfolioActionRepository) ExistsPendingForPortfolioAndAction(
+	ctx context.Context,
 	portfolioID, corporateActionID string,
 ) (bool, error) {
 	if portfolioID == "" || corporateActionID == "" {
@@ -201,7 +204,7 @@ func (r *portfolioActionRepository) ExistsPendingForPortfolioAndAction(
 	}
 
 	var count int64
-	if err := r.db.Model(&models.PortfolioAction{}).
+	if err := r.db.WithContext(ctx).Model(&models.PortfolioAction{}).
 		Where("portfolio_id = ? AND corporate_action_id = ? AND status = ?",
 			pid, caid, models.PortfolioActionStatusPending).
 		Count(&count).Error; err != nil {
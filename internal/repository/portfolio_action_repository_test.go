@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -57,6 +58,8 @@ func createTestDataForPortfolioAction(t *testing.T, db *gorm.DB) (*models.Portfo
 }
 
 func TestPortfolioActionRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
@@ -71,21 +74,25 @@ func TestPortfolioActionRepository_Create(t *testing.T) {
 		DetectedAt:        time.Now().UTC(),
 	}
 
-	err := repo.Create(portfolioAction)
+	err := repo.Create(ctx, portfolioAction)
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, portfolioAction.ID)
 }
 
 func TestPortfolioActionRepository_Create_Nil(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
-	err := repo.Create(nil)
+	err := repo.Create(ctx, nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot be nil")
 }
 
 func TestPortfolioActionRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
@@ -99,9 +106,9 @@ func TestPortfolioActionRepository_FindByID(t *testing.T) {
 		SharesAffected:    100,
 		DetectedAt:        time.Now().UTC(),
 	}
-	require.NoError(t, repo.Create(portfolioAction))
+	require.NoError(t, repo.Create(ctx, portfolioAction))
 
-	found, err := repo.FindByID(portfolioAction.ID.String())
+	found, err := repo.FindByID(ctx, portfolioAction.ID.String())
 	assert.NoError(t, err)
 	assert.Equal(t, portfolioAction.ID, found.ID)
 	assert.Equal(t, portfolioAction.Status, found.Status)
@@ -110,15 +117,19 @@ func TestPortfolioActionRepository_FindByID(t *testing.T) {
 }
 
 func TestPortfolioActionRepository_FindByID_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
-	_, err := repo.FindByID(uuid.New().String())
+	_, err := repo.FindByID(ctx, uuid.New().String())
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
 
 func TestPortfolioActionRepository_FindByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
@@ -142,10 +153,10 @@ func TestPortfolioActionRepository_FindByPortfolioID(t *testing.T) {
 		DetectedAt:        time.Now().UTC().AddDate(0, 0, -1),
 	}
 
-	require.NoError(t, repo.Create(action1))
-	require.NoError(t, repo.Create(action2))
+	require.NoError(t, repo.Create(ctx, action1))
+	require.NoError(t, repo.Create(ctx, action2))
 
-	actions, err := repo.FindByPortfolioID(portfolio.ID.String())
+	actions, err := repo.FindByPortfolioID(ctx, portfolio.ID.String())
 	assert.NoError(t, err)
 	assert.Len(t, actions, 2)
 	// Should be ordered by detected_at DESC
@@ -153,6 +164,8 @@ func TestPortfolioActionRepository_FindByPortfolioID(t *testing.T) {
 }
 
 func TestPortfolioActionRepository_FindPendingByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
@@ -176,16 +189,18 @@ func TestPortfolioActionRepository_FindPendingByPortfolioID(t *testing.T) {
 		DetectedAt:        time.Now().UTC(),
 	}
 
-	require.NoError(t, repo.Create(pending))
-	require.NoError(t, repo.Create(approved))
+	require.NoError(t, repo.Create(ctx, pending))
+	require.NoError(t, repo.Create(ctx, approved))
 
-	actions, err := repo.FindPendingByPortfolioID(portfolio.ID.String())
+	actions, err := repo.FindPendingByPortfolioID(ctx, portfolio.ID.String())
 	assert.NoError(t, err)
 	assert.Len(t, actions, 1)
 	assert.Equal(t, models.PortfolioActionStatusPending, actions[0].Status)
 }
 
 func TestPortfolioActionRepository_FindByPortfolioIDAndStatus(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
@@ -208,16 +223,18 @@ func TestPortfolioActionRepository_FindByPortfolioIDAndStatus(t *testing.T) {
 		DetectedAt:        time.Now().UTC(),
 	}
 
-	require.NoError(t, repo.Create(pending))
-	require.NoError(t, repo.Create(approved))
+	require.NoError(t, repo.Create(ctx, pending))
+	require.NoError(t, repo.Create(ctx, approved))
 
-	actions, err := repo.FindByPortfolioIDAndStatus(portfolio.ID.String(), models.PortfolioActionStatusApproved)
+	actions, err := repo.FindByPortfolioIDAndStatus(ctx, portfolio.ID.String(), models.PortfolioActionStatusApproved)
 	assert.NoError(t, err)
 	assert.Len(t, actions, 1)
 	assert.Equal(t, models.PortfolioActionStatusApproved, actions[0].Status)
 }
 
 func TestPortfolioActionRepository_FindPendingByCorporateActionID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
@@ -240,10 +257,10 @@ func TestPortfolioActionRepository_FindPendingByCorporateActionID(t *testing.T)
 		DetectedAt:        time.Now().UTC(),
 	}
 
-	require.NoError(t, repo.Create(pending))
-	require.NoError(t, repo.Create(approved))
+	require.NoError(t, repo.Create(ctx, pending))
+	require.NoError(t, repo.Create(ctx, approved))
 
-	actions, err := repo.FindPendingByCorporateActionID(corpAction.ID.String())
+	actions, err := repo.FindPendingByCorporateActionID(ctx, corpAction.ID.String())
 	assert.NoError(t, err)
 	assert.Len(t, actions, 1)
 	assert.Equal(t, models.PortfolioActionStatusPending, actions[0].Status)
@@ -251,6 +268,8 @@ func TestPortfolioActionRepository_FindPendingByCorporateActionID(t *testing.T)
 }
 
 func TestPortfolioActionRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
@@ -264,24 +283,26 @@ func TestPortfolioActionRepository_Update(t *testing.T) {
 		SharesAffected:    100,
 		DetectedAt:        time.Now().UTC(),
 	}
-	require.NoError(t, repo.Create(portfolioAction))
+	require.NoError(t, repo.Create(ctx, portfolioAction))
 
 	// Update status
 	portfolioAction.Status = models.PortfolioActionStatusApproved
 	now := time.Now().UTC()
 	portfolioAction.ReviewedAt = &now
 
-	err := repo.Update(portfolioAction)
+	err := repo.Update(ctx, portfolioAction)
 	assert.NoError(t, err)
 
 	// Verify update
-	found, err := repo.FindByID(portfolioAction.ID.String())
+	found, err := repo.FindByID(ctx, portfolioAction.ID.String())
 	assert.NoError(t, err)
 	assert.Equal(t, models.PortfolioActionStatusApproved, found.Status)
 	assert.NotNil(t, found.ReviewedAt)
 }
 
 func TestPortfolioActionRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
@@ -295,32 +316,36 @@ func TestPortfolioActionRepository_Delete(t *testing.T) {
 		SharesAffected:    100,
 		DetectedAt:        time.Now().UTC(),
 	}
-	require.NoError(t, repo.Create(portfolioAction))
+	require.NoError(t, repo.Create(ctx, portfolioAction))
 
-	err := repo.Delete(portfolioAction.ID.String())
+	err := repo.Delete(ctx, portfolioAction.ID.String())
 	assert.NoError(t, err)
 
 	// Verify deletion
-	_, err = repo.FindByID(portfolioAction.ID.String())
+	_, err = repo.FindByID(ctx, portfolioAction.ID.String())
 	assert.Error(t, err)
 }
 
 func TestPortfolioActionRepository_Delete_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
-	err := repo.Delete(uuid.New().String())
+	err := repo.Delete(ctx, uuid.New().String())
 	assert.Error(t, err)
 }
 
 func TestPortfolioActionRepository_ExistsPendingForPortfolioAndAction(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
 	portfolio, corpAction := createTestDataForPortfolioAction(t, db)
 
 	// No action exists yet
-	exists, err := repo.ExistsPendingForPortfolioAndAction(portfolio.ID.String(), corpAction.ID.String())
+	exists, err := repo.ExistsPendingForPortfolioAndAction(ctx, portfolio.ID.String(), corpAction.ID.String())
 	assert.NoError(t, err)
 	assert.False(t, exists)
 
@@ -333,30 +358,32 @@ func TestPortfolioActionRepository_ExistsPendingForPortfolioAndAction(t *testing
 		SharesAffected:    100,
 		DetectedAt:        time.Now().UTC(),
 	}
-	require.NoError(t, repo.Create(portfolioAction))
+	require.NoError(t, repo.Create(ctx, portfolioAction))
 
 	// Now it should exist
-	exists, err = repo.ExistsPendingForPortfolioAndAction(portfolio.ID.String(), corpAction.ID.String())
+	exists, err = repo.ExistsPendingForPortfolioAndAction(ctx, portfolio.ID.String(), corpAction.ID.String())
 	assert.NoError(t, err)
 	assert.True(t, exists)
 
 	// Change status to approved
 	portfolioAction.Status = models.PortfolioActionStatusApproved
-	require.NoError(t, repo.Update(portfolioAction))
+	require.NoError(t, repo.Update(ctx, portfolioAction))
 
 	// Should not exist as pending anymore
-	exists, err = repo.ExistsPendingForPortfolioAndAction(portfolio.ID.String(), corpAction.ID.String())
+	exists, err = repo.ExistsPendingForPortfolioAndAction(ctx, portfolio.ID.String(), corpAction.ID.String())
 	assert.NoError(t, err)
 	assert.False(t, exists)
 }
 
 func TestPortfolioActionRepository_ExistsPendingForPortfolioAndAction_InvalidID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioActionTestDB(t)
 	repo := NewPortfolioActionRepository(db)
 
-	_, err := repo.ExistsPendingForPortfolioAndAction("invalid", uuid.New().String())
+	_, err := repo.ExistsPendingForPortfolioAndAction(ctx, "invalid", uuid.New().String())
 	assert.Error(t, err)
 
-	_, err = repo.ExistsPendingForPortfolioAndAction(uuid.New().String(), "invalid")
+	_, err = repo.ExistsPendingForPortfolioAndAction(ctx, uuid.New().String(), "invalid")
 	assert.Error(t, err)
 }
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// PortfolioGroupRepository defines the interface for portfolio group data operations
+type PortfolioGroupRepository interface {
+	Create(ctx context.Context, group *models.PortfolioGroup) error
+	FindByID(ctx context.Context, id string) (*models.PortfolioGroup, error)
+	FindByUserID(ctx context.Context, userID string) ([]*models.PortfolioGroup, error)
+	ExistsByUserIDAndName(ctx context.Context, userID uuid.UUID, name string, excludeID uuid.UUID) (bool, error)
+	Update(ctx context.Context, group *models.PortfolioGroup) error
+	Delete(ctx context.Context, id string) error
+}
+
+// portfolioGroupRepository implements PortfolioGroupRepository interface
+type portfolioGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewPortfolioGroupRepository creates a new PortfolioGroupRepository instance
+func NewPortfolioGroupRepository(db *gorm.DB) PortfolioGroupRepository {
+	return &portfolioGroupRepository{db: db}
+}
+
+// Create creates a new portfolio group together with its portfolios
+func (r *portfolioGroupRepository) Create(ctx context.Context, group *models.PortfolioGroup) error {
+	if group == nil {
+		return fmt.Errorf("portfolio group cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(group).Error; err != nil {
+		return fmt.Errorf("failed to create portfolio group: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a portfolio group by ID, including its portfolios
+func (r *portfolioGroupRepository) FindByID(ctx context.Context, id string) (*models.PortfolioGroup, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	groupID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio group ID format: %w", err)
+	}
+
+	var group models.PortfolioGroup
+	if err := r.db.WithContext(ctx).Preload("Portfolios").Where("id = ?", groupID).First(&group).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrPortfolioGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to find portfolio group: %w", err)
+	}
+
+	return &group, nil
+}
+
+// FindByUserID finds all of a user's portfolio groups, including their portfolios, by name
+func (r *portfolioGroupRepository) FindByUserID(ctx context.Context, userID string) ([]*models.PortfolioGroup, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	var groups []*models.PortfolioGroup
+	if err := r.db.WithContext(ctx).Preload("Portfolios").
+		Where("user_id = ?", uid).
+		Order("name ASC").
+		Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to find portfolio groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// ExistsByUserIDAndName checks if a user has a group with the given name other than excludeID
+func (r *portfolioGroupRepository) ExistsByUserIDAndName(ctx context.Context, userID uuid.UUID, name string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.PortfolioGroup{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check portfolio group name: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Update updates a portfolio group and replaces its portfolios with the ones it lists
+func (r *portfolioGroupRepository) Update(ctx context.Context, group *models.PortfolioGroup) error {
+	if group == nil {
+		return fmt.Errorf("portfolio group cannot be nil")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Portfolios").Save(group).Error; err != nil {
+			return fmt.Errorf("failed to update portfolio group: %w", err)
+		}
+
+		if err := tx.Where("group_id = ?", group.ID).Delete(&models.PortfolioGroupPortfolio{}).Error; err != nil {
+			return fmt.Errorf("failed to update portfolio group portfolios: %w", err)
+		}
+		for _, portfolio := range group.Portfolios {
+			portfolio.GroupID = group.ID
+		}
+		if len(group.Portfolios) > 0 {
+			if err := tx.Create(group.Portfolios).Error; err != nil {
+				return fmt.Errorf("failed to update portfolio group portfolios: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete deletes a portfolio group, unlinking its portfolios. Its subgroups move up to its parent.
+func (r *portfolioGroupRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	groupID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid portfolio group ID format: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group models.PortfolioGroup
+		if err := tx.Where("id = ?", groupID).First(&group).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return models.ErrPortfolioGroupNotFound
+			}
+			return fmt.Errorf("failed to find portfolio group: %w", err)
+		}
+
+		if err := tx.Model(&models.PortfolioGroup{}).
+			Where("parent_id = ?", groupID).
+			Update("parent_id", group.ParentID).Error; err != nil {
+			return fmt.Errorf("failed to move portfolio subgroups: %w", err)
+		}
+
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.PortfolioGroupPortfolio{}).Error; err != nil {
+			return fmt.Errorf("failed to delete portfolio group portfolios: %w", err)
+		}
+
+		if err := tx.Where("id = ?", groupID).Delete(&models.PortfolioGroup{}).Error; err != nil {
+			return fmt.Errorf("failed to delete portfolio group: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupPortfolioGroupTestDB(t *testing.T) (*gorm.DB, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.PortfolioGroup{}, &models.PortfolioGroupPortfolio{})
+	require.NoError(t, err)
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Retirement",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	return db, user, portfolio
+}
+
+func TestPortfolioGroupRepository_CreateAndFind(t *testing.T) {
+	db, user, portfolio := setupPortfolioGroupTestDB(t)
+	repo := NewPortfolioGroupRepository(db)
+	ctx := context.Background()
+
+	group := &models.PortfolioGroup{
+		UserID:     user.ID,
+		Name:       "Family",
+		Portfolios: []*models.PortfolioGroupPortfolio{{PortfolioID: portfolio.ID}},
+	}
+	require.NoError(t, repo.Create(ctx, group))
+	require.NoError(t, repo.Create(ctx, &models.PortfolioGroup{UserID: user.ID, Name: "Brokerage", ParentID: &group.ID}))
+
+	found, err := repo.FindByID(ctx, group.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{portfolio.ID}, found.PortfolioIDs())
+
+	groups, err := repo.FindByUserID(ctx, user.ID.String())
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	assert.Equal(t, "Brokerage", groups[0].Name)
+
+	exists, err := repo.ExistsByUserIDAndName(ctx, user.ID, "Family", uuid.Nil)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	exists, err = repo.ExistsByUserIDAndName(ctx, user.ID, "Family", group.ID)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = repo.FindByID(ctx, uuid.New().String())
+	assert.Equal(t, models.ErrPortfolioGroupNotFound, err)
+}
+
+func TestPortfolioGroupRepository_UpdateReplacesPortfolios(t *testing.T) {
+	db, user, portfolio := setupPortfolioGroupTestDB(t)
+	repo := NewPortfolioGroupRepository(db)
+	ctx := context.Background()
+
+	other := &models.Portfolio{UserID: user.ID, Name: "Brokerage", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(other).Error)
+
+	group := &models.PortfolioGroup{
+		UserID:     user.ID,
+		Name:       "Family",
+		Portfolios: []*models.PortfolioGroupPortfolio{{PortfolioID: portfolio.ID}},
+	}
+	require.NoError(t, repo.Create(ctx, group))
+
+	group.Name = "Household"
+	group.Portfolios = []*models.PortfolioGroupPortfolio{{PortfolioID: other.ID}}
+	require.NoError(t, repo.Update(ctx, group))
+
+	found, err := repo.FindByID(ctx, group.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "Household", found.Name)
+	assert.Equal(t, []uuid.UUID{other.ID}, found.PortfolioIDs())
+}
+
+func TestPortfolioGroupRepository_DeleteMovesSubgroupsUp(t *testing.T) {
+	db, user, portfolio := setupPortfolioGroupTestDB(t)
+	repo := NewPortfolioGroupRepository(db)
+	ctx := context.Background()
+
+	top := &models.PortfolioGroup{UserID: user.ID, Name: "Family"}
+	require.NoError(t, repo.Create(ctx, top))
+	middle := &models.PortfolioGroup{
+		UserID:     user.ID,
+		Name:       "Retirement",
+		ParentID:   &top.ID,
+		Portfolios: []*models.PortfolioGroupPortfolio{{PortfolioID: portfolio.ID}},
+	}
+	require.NoError(t, repo.Create(ctx, middle))
+	bottom := &models.PortfolioGroup{UserID: user.ID, Name: "IRA", ParentID: &middle.ID}
+	require.NoError(t, repo.Create(ctx, bottom))
+
+	require.NoError(t, repo.Delete(ctx, middle.ID.String()))
+
+	found, err := repo.FindByID(ctx, bottom.ID.String())
+	require.NoError(t, err)
+	require.NotNil(t, found.ParentID)
+	assert.Equal(t, top.ID, *found.ParentID)
+
+	var links int64
+	require.NoError(t, db.Model(&models.PortfolioGroupPortfolio{}).Where("group_id = ?", middle.ID).Count(&links).Error)
+	assert.Zero(t, links)
+
+	var portfolios int64
+	require.NoError(t, db.Model(&models.Portfolio{}).Count(&portfolios).Error)
+	assert.Equal(t, int64(1), portfolios)
+
+	assert.Equal(t, models.ErrPortfolioGroupNotFound, repo.Delete(ctx, middle.ID.String()))
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -11,13 +12,18 @@ import (
 
 // PortfolioRepository defines the interface for portfolio data operations
 type PortfolioRepository interface {
-	Create(portfolio *models.Portfolio) error
-	FindByID(id string) (*models.Portfolio, error)
-	FindByUserID(userID string) ([]*models.Portfolio, error)
-	FindByUserIDAndName(userID, name string) (*models.Portfolio, error)
-	Update(portfolio *models.Portfolio) error
-	Delete(id string) error
-	ExistsByUserIDAndName(userID, name string) (bool, error)
+	Create(ctx context.Context, portfolio *models.Portfolio) error
+	FindByID(ctx context.Context, id string) (*models.Portfolio, error)
+	FindByUserID(ctx context.Context, userID string) ([]*models.Portfolio, error)
+	FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*models.Portfolio, error)
+	FindAll(ctx context.Context) ([]*models.Portfolio, error)
+	FindByUserIDAndName(ctx context.Context, userID, name string) (*models.Portfolio, error)
+	Update(ctx context.Context, portfolio *models.Portfolio) error
+	Delete(ctx context.Context, id string) error
+	ExistsByUserIDAndName(ctx context.Context, userID, name string) (bool, error)
+	FindPeerComparisonOptedIn(ctx context.Context) ([]*models.Portfolio, error)
+	SetPeerComparisonOptIn(ctx context.Context, id string, optIn bool) error
+	ReassignOrganizationPortfolios(ctx context.Context, organizationID, fromUserID, toUserID uuid.UUID) (int64, error)
 }
 
 // portfolioRepository implements PortfolioRepository interface
@@ -31,12 +37,12 @@ func NewPortfolioRepository(db *gorm.DB) PortfolioRepository {
 }
 
 // Create creates a new portfolio in the database
-func (r *portfolioRepository) Create(portfolio *models.Portfolio) error {
+func (r *portfolioRepository) Create(ctx context.Context, portfolio *models.Portfolio) error {
 	if portfolio == nil {
 		return fmt.Errorf("portfolio cannot be nil")
 	}
 
-	if err := r.db.Create(portfolio).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(portfolio).Error; err != nil {
 		return fmt.Errorf("failed to create portfolio: %w", err)
 	}
 
@@ -44,7 +50,7 @@ func (r *portfolioRepository) Create(portfolio *models.Portfolio) error {
 }
 
 // FindByID finds a portfolio by ID
-func (r *portfolioRepository) FindByID(id string) (*models.Portfolio, error) {
+func (r *portfolioRepository) FindByID(ctx context.Context, id string) (*models.Portfolio, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id cannot be empty")
 	}
@@ -56,7 +62,7 @@ func (r *portfolioRepository) FindByID(id string) (*models.Portfolio, error) {
 	}
 
 	var portfolio models.Portfolio
-	err = r.db.Where("id = ?", portfolioID).First(&portfolio).Error
+	err = r.db.WithContext(ctx).Where("id = ?", portfolioID).First(&portfolio).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrPortfolioNotFound
@@ -68,7 +74,7 @@ func (r *portfolioRepository) FindByID(id string) (*models.Portfolio, error) {
 }
 
 // FindByUserID finds all portfolios for a specific user
-func (r *portfolioRepository) FindByUserID(userID string) ([]*models.Portfolio, error) {
+func (r *portfolioRepository) FindByUserID(ctx context.Context, userID string) ([]*models.Portfolio, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
 	}
@@ -80,7 +86,7 @@ func (r *portfolioRepository) FindByUserID(userID string) ([]*models.Portfolio,
 	}
 
 	var portfolios []*models.Portfolio
-	err = r.db.Where("user_id = ?", uid).Order("created_at DESC").Find(&portfolios).Error
+	err = r.db.WithContext(ctx).Where("user_id = ?", uid).Order("created_at DESC").Find(&portfolios).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to find portfolios for user: %w", err)
 	}
@@ -88,8 +94,29 @@ func (r *portfolioRepository) FindByUserID(userID string) ([]*models.Portfolio,
 	return portfolios, nil
 }
 
+// FindByOrganizationID finds all portfolios owned by an organization
+func (r *portfolioRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*models.Portfolio, error) {
+	var portfolios []*models.Portfolio
+	err := r.db.WithContext(ctx).Where("organization_id = ?", organizationID).Order("created_at DESC").Find(&portfolios).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find portfolios for organization: %w", err)
+	}
+
+	return portfolios, nil
+}
+
+// FindAll finds all portfolios across all users, for use by background jobs
+func (r *portfolioRepository) FindAll(ctx context.Context) ([]*models.Portfolio, error) {
+	var portfolios []*models.Portfolio
+	if err := r.db.WithContext(ctx).Order("created_at ASC").Find(&portfolios).Error; err != nil {
+		return nil, fmt.Errorf("failed to find portfolios: %w", err)
+	}
+
+	return portfolios, nil
+}
+
 // FindByUserIDAndName finds a portfolio by user ID and name
-func (r *portfolioRepository) FindByUserIDAndName(userID, name string) (*models.Portfolio, error) {
+func (r *portfolioRepository) FindByUserIDAndName(ctx context.Context, userID, name string) (*models.Portfolio, error) {
 	if userID == "" {
 		return nil, fmt.Errorf("user ID cannot be empty")
 	}
@@ -104,7 +131,7 @@ func (r *portfolioRepository) FindByUserIDAndName(userID, name string) (*models.
 	}
 
 	var portfolio models.Portfolio
-	err = r.db.Where("user_id = ? AND name = ?", uid, name).First(&portfolio).Error
+	err = r.db.WithContext(ctx).Where("user_id = ? AND name = ?", uid, name).First(&portfolio).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrPortfolioNotFound
@@ -115,26 +142,39 @@ func (r *portfolioRepository) FindByUserIDAndName(userID, name string) (*models.
 	return &portfolio, nil
 }
 
-// Update updates an existing portfolio
-func (r *portfolioRepository) Update(portfolio *models.Portfolio) error {
+// Update updates an existing portfolio if it is still at the version it was read at, and
+// increments the version. A portfolio changed since then returns a VersionConflictError.
+func (r *portfolioRepository) Update(ctx context.Context, portfolio *models.Portfolio) error {
 	if portfolio == nil {
 		return fmt.Errorf("portfolio cannot be nil")
 	}
 
-	result := r.db.Model(portfolio).Where("id = ?", portfolio.ID).Updates(portfolio)
+	version := portfolio.Version
+	portfolio.Version = version + 1
+	result := r.db.WithContext(ctx).Model(portfolio).Where("id = ? AND version = ?", portfolio.ID, version).Updates(portfolio)
 	if result.Error != nil {
+		portfolio.Version = version
 		return fmt.Errorf("failed to update portfolio: %w", result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		return models.ErrPortfolioNotFound
+		portfolio.Version = version
+		var current models.Portfolio
+		err := r.db.WithContext(ctx).Select("version").Where("id = ?", portfolio.ID).First(&current).Error
+		if err == gorm.ErrRecordNotFound {
+			return models.ErrPortfolioNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check portfolio version: %w", err)
+		}
+		return &models.VersionConflictError{CurrentVersion: current.Version}
 	}
 
 	return nil
 }
 
 // Delete deletes a portfolio by ID
-func (r *portfolioRepository) Delete(id string) error {
+func (r *portfolioRepository) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("id cannot be empty")
 	}
@@ -145,7 +185,7 @@ func (r *portfolioRepository) Delete(id string) error {
 		return fmt.Errorf("invalid portfolio ID format: %w", err)
 	}
 
-	result := r.db.Where("id = ?", portfolioID).Delete(&models.Portfolio{})
+	result := r.db.WithContext(ctx).Where("id = ?", portfolioID).Delete(&models.Portfolio{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete portfolio: %w", result.Error)
 	}
@@ -158,7 +198,7 @@ func (r *portfolioRepository) Delete(id string) error {
 }
 
 // ExistsByUserIDAndName checks if a portfolio with the given name exists for a user
-func (r *portfolioRepository) ExistsByUserIDAndName(userID, name string) (bool, error) {
+func (r *portfolioRepository) ExistsByUserIDAndName(ctx context.Context, userID, name string) (bool, error) {
 	if userID == "" {
 		return false, fmt.Errorf("user ID cannot be empty")
 	}
@@ -173,10 +213,61 @@ func (r *portfolioRepository) ExistsByUserIDAndName(userID, name string) (bool,
 	}
 
 	var count int64
-	err = r.db.Model(&models.Portfolio{}).Where("user_id = ? AND name = ?", uid, name).Count(&count).Error
+	err = r.db.WithContext(ctx).Model(&models.Portfolio{}).Where("user_id = ? AND name = ?", uid, name).Count(&count).Error
 	if err != nil {
 		return false, fmt.Errorf("failed to check portfolio existence: %w", err)
 	}
 
 	return count > 0, nil
 }
+
+// FindPeerComparisonOptedIn finds all portfolios whose owners consented to peer comparison
+func (r *portfolioRepository) FindPeerComparisonOptedIn(ctx context.Context) ([]*models.Portfolio, error) {
+	var portfolios []*models.Portfolio
+	if err := r.db.WithContext(ctx).Where("peer_comparison_opt_in = ?", true).Order("created_at ASC").Find(&portfolios).Error; err != nil {
+		return nil, fmt.Errorf("failed to find opted-in portfolios: %w", err)
+	}
+
+	return portfolios, nil
+}
+
+// SetPeerComparisonOptIn records whether a portfolio takes part in peer comparison.
+// It is separate from Update because Update skips false values.
+func (r *portfolioRepository) SetPeerComparisonOptIn(ctx context.Context, id string, optIn bool) error {
+	if id == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	portfolioID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Model(&models.Portfolio{}).Where("id = ?", portfolioID).Update("peer_comparison_opt_in", optIn)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update peer comparison opt-in: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ErrPortfolioNotFound
+	}
+
+	return nil
+}
+
+// ReassignOrganizationPortfolios hands the organization portfolios created by one member to
+// another, returning how many were reassigned. Portfolios outside the organization are left
+// alone.
+func (r *portfolioRepository) ReassignOrganizationPortfolios(ctx context.Context, organizationID, fromUserID, toUserID uuid.UUID) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.Portfolio{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, fromUserID).
+		Update("user_id", toUserID)
+	if result.Error != nil {
+		if isUniqueViolation(r.db, result.Error) {
+			return 0, models.ErrPortfolioDuplicateName
+		}
+		return 0, fmt.Errorf("failed to reassign portfolios: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -1,10 +1,12 @@
 package repository
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
@@ -34,6 +36,8 @@ func createTestUser(t *testing.T, db *gorm.DB) *models.User {
 }
 
 func TestPortfolioRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioRepoTestDB(t)
 	repo := NewPortfolioRepository(db)
 	user := createTestUser(t, db)
@@ -47,20 +51,22 @@ func TestPortfolioRepository_Create(t *testing.T) {
 			CostBasisMethod: models.CostBasisFIFO,
 		}
 
-		err := repo.Create(portfolio)
+		err := repo.Create(ctx, portfolio)
 
 		assert.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, portfolio.ID)
 	})
 
 	t.Run("nil portfolio error", func(t *testing.T) {
-		err := repo.Create(nil)
+		err := repo.Create(ctx, nil)
 
 		assert.Error(t, err)
 	})
 }
 
 func TestPortfolioRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioRepoTestDB(t)
 	repo := NewPortfolioRepository(db)
 	user := createTestUser(t, db)
@@ -71,11 +77,11 @@ func TestPortfolioRepository_FindByID(t *testing.T) {
 		BaseCurrency:    "USD",
 		CostBasisMethod: models.CostBasisFIFO,
 	}
-	err := repo.Create(portfolio)
+	err := repo.Create(ctx, portfolio)
 	assert.NoError(t, err)
 
 	t.Run("successful find", func(t *testing.T) {
-		found, err := repo.FindByID(portfolio.ID.String())
+		found, err := repo.FindByID(ctx, portfolio.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
@@ -84,32 +90,34 @@ func TestPortfolioRepository_FindByID(t *testing.T) {
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		_, err := repo.FindByID(uuid.New().String())
+		_, err := repo.FindByID(ctx, uuid.New().String())
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrPortfolioNotFound, err)
 	})
 
 	t.Run("empty id error", func(t *testing.T) {
-		_, err := repo.FindByID("")
+		_, err := repo.FindByID(ctx, "")
 
 		assert.Error(t, err)
 	})
 
 	t.Run("invalid uuid error", func(t *testing.T) {
-		_, err := repo.FindByID("invalid-uuid")
+		_, err := repo.FindByID(ctx, "invalid-uuid")
 
 		assert.Error(t, err)
 	})
 }
 
 func TestPortfolioRepository_FindByUserID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioRepoTestDB(t)
 	repo := NewPortfolioRepository(db)
 	user := createTestUser(t, db)
 
 	t.Run("empty list", func(t *testing.T) {
-		portfolios, err := repo.FindByUserID(user.ID.String())
+		portfolios, err := repo.FindByUserID(ctx, user.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, portfolios)
@@ -124,25 +132,54 @@ func TestPortfolioRepository_FindByUserID(t *testing.T) {
 			BaseCurrency:    "USD",
 			CostBasisMethod: models.CostBasisFIFO,
 		}
-		err := repo.Create(portfolio)
+		err := repo.Create(ctx, portfolio)
 		assert.NoError(t, err)
 	}
 
 	t.Run("find multiple portfolios", func(t *testing.T) {
-		portfolios, err := repo.FindByUserID(user.ID.String())
+		portfolios, err := repo.FindByUserID(ctx, user.ID.String())
 
 		assert.NoError(t, err)
 		assert.Len(t, portfolios, 3)
 	})
 
 	t.Run("empty user id error", func(t *testing.T) {
-		_, err := repo.FindByUserID("")
+		_, err := repo.FindByUserID(ctx, "")
 
 		assert.Error(t, err)
 	})
 }
 
+func TestPortfolioRepository_FindAll(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPortfolioRepoTestDB(t)
+	repo := NewPortfolioRepository(db)
+	user := createTestUser(t, db)
+
+	otherUser := &models.User{ID: uuid.New(), Email: "other@example.com"}
+	assert.NoError(t, otherUser.SetPassword("password123"))
+	assert.NoError(t, db.Create(otherUser).Error)
+
+	for _, owner := range []*models.User{user, otherUser} {
+		err := repo.Create(ctx, &models.Portfolio{
+			UserID:          owner.ID,
+			Name:            "Main",
+			BaseCurrency:    "USD",
+			CostBasisMethod: models.CostBasisFIFO,
+		})
+		assert.NoError(t, err)
+	}
+
+	portfolios, err := repo.FindAll(ctx)
+
+	assert.NoError(t, err)
+	assert.Len(t, portfolios, 2)
+}
+
 func TestPortfolioRepository_FindByUserIDAndName(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioRepoTestDB(t)
 	repo := NewPortfolioRepository(db)
 	user := createTestUser(t, db)
@@ -153,11 +190,11 @@ func TestPortfolioRepository_FindByUserIDAndName(t *testing.T) {
 		BaseCurrency:    "USD",
 		CostBasisMethod: models.CostBasisFIFO,
 	}
-	err := repo.Create(portfolio)
+	err := repo.Create(ctx, portfolio)
 	assert.NoError(t, err)
 
 	t.Run("successful find", func(t *testing.T) {
-		found, err := repo.FindByUserIDAndName(user.ID.String(), "Unique Portfolio")
+		found, err := repo.FindByUserIDAndName(ctx, user.ID.String(), "Unique Portfolio")
 
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
@@ -165,22 +202,24 @@ func TestPortfolioRepository_FindByUserIDAndName(t *testing.T) {
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		_, err := repo.FindByUserIDAndName(user.ID.String(), "Non-existent")
+		_, err := repo.FindByUserIDAndName(ctx, user.ID.String(), "Non-existent")
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrPortfolioNotFound, err)
 	})
 
 	t.Run("empty parameters error", func(t *testing.T) {
-		_, err := repo.FindByUserIDAndName("", "Portfolio")
+		_, err := repo.FindByUserIDAndName(ctx, "", "Portfolio")
 		assert.Error(t, err)
 
-		_, err = repo.FindByUserIDAndName(user.ID.String(), "")
+		_, err = repo.FindByUserIDAndName(ctx, user.ID.String(), "")
 		assert.Error(t, err)
 	})
 }
 
 func TestPortfolioRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioRepoTestDB(t)
 	repo := NewPortfolioRepository(db)
 	user := createTestUser(t, db)
@@ -192,26 +231,49 @@ func TestPortfolioRepository_Update(t *testing.T) {
 		BaseCurrency:    "USD",
 		CostBasisMethod: models.CostBasisFIFO,
 	}
-	err := repo.Create(portfolio)
+	err := repo.Create(ctx, portfolio)
 	assert.NoError(t, err)
 
 	t.Run("successful update", func(t *testing.T) {
 		portfolio.Name = "Updated Name"
 		portfolio.Description = "Updated Description"
 
-		err := repo.Update(portfolio)
+		err := repo.Update(ctx, portfolio)
 
 		assert.NoError(t, err)
 
 		// Verify update
-		found, err := repo.FindByID(portfolio.ID.String())
+		found, err := repo.FindByID(ctx, portfolio.ID.String())
 		assert.NoError(t, err)
 		assert.Equal(t, "Updated Name", found.Name)
 		assert.Equal(t, "Updated Description", found.Description)
+		assert.Equal(t, 2, found.Version)
+		assert.Equal(t, 2, portfolio.Version)
+	})
+
+	t.Run("stale version conflict", func(t *testing.T) {
+		stale, err := repo.FindByID(ctx, portfolio.ID.String())
+		require.NoError(t, err)
+
+		portfolio.Name = "First Writer"
+		require.NoError(t, repo.Update(ctx, portfolio))
+
+		stale.Name = "Second Writer"
+		err = repo.Update(ctx, stale)
+
+		var conflict *models.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.ErrorIs(t, err, models.ErrVersionConflict)
+		assert.Equal(t, portfolio.Version, conflict.CurrentVersion)
+		assert.Equal(t, conflict.CurrentVersion-1, stale.Version)
+
+		found, err := repo.FindByID(ctx, portfolio.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "First Writer", found.Name)
 	})
 
 	t.Run("nil portfolio error", func(t *testing.T) {
-		err := repo.Update(nil)
+		err := repo.Update(ctx, nil)
 
 		assert.Error(t, err)
 	})
@@ -225,7 +287,7 @@ func TestPortfolioRepository_Update(t *testing.T) {
 			CostBasisMethod: models.CostBasisFIFO,
 		}
 
-		err := repo.Update(nonExistent)
+		err := repo.Update(ctx, nonExistent)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrPortfolioNotFound, err)
@@ -233,6 +295,8 @@ func TestPortfolioRepository_Update(t *testing.T) {
 }
 
 func TestPortfolioRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioRepoTestDB(t)
 	repo := NewPortfolioRepository(db)
 	user := createTestUser(t, db)
@@ -243,34 +307,36 @@ func TestPortfolioRepository_Delete(t *testing.T) {
 		BaseCurrency:    "USD",
 		CostBasisMethod: models.CostBasisFIFO,
 	}
-	err := repo.Create(portfolio)
+	err := repo.Create(ctx, portfolio)
 	assert.NoError(t, err)
 
 	t.Run("successful deletion", func(t *testing.T) {
-		err := repo.Delete(portfolio.ID.String())
+		err := repo.Delete(ctx, portfolio.ID.String())
 
 		assert.NoError(t, err)
 
 		// Verify deletion
-		_, err = repo.FindByID(portfolio.ID.String())
+		_, err = repo.FindByID(ctx, portfolio.ID.String())
 		assert.Error(t, err)
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		err := repo.Delete(uuid.New().String())
+		err := repo.Delete(ctx, uuid.New().String())
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrPortfolioNotFound, err)
 	})
 
 	t.Run("empty id error", func(t *testing.T) {
-		err := repo.Delete("")
+		err := repo.Delete(ctx, "")
 
 		assert.Error(t, err)
 	})
 }
 
 func TestPortfolioRepository_ExistsByUserIDAndName(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioRepoTestDB(t)
 	repo := NewPortfolioRepository(db)
 	user := createTestUser(t, db)
@@ -281,28 +347,97 @@ func TestPortfolioRepository_ExistsByUserIDAndName(t *testing.T) {
 		BaseCurrency:    "USD",
 		CostBasisMethod: models.CostBasisFIFO,
 	}
-	err := repo.Create(portfolio)
+	err := repo.Create(ctx, portfolio)
 	assert.NoError(t, err)
 
 	t.Run("exists returns true", func(t *testing.T) {
-		exists, err := repo.ExistsByUserIDAndName(user.ID.String(), "Existing Portfolio")
+		exists, err := repo.ExistsByUserIDAndName(ctx, user.ID.String(), "Existing Portfolio")
 
 		assert.NoError(t, err)
 		assert.True(t, exists)
 	})
 
 	t.Run("does not exist returns false", func(t *testing.T) {
-		exists, err := repo.ExistsByUserIDAndName(user.ID.String(), "Non-existent")
+		exists, err := repo.ExistsByUserIDAndName(ctx, user.ID.String(), "Non-existent")
 
 		assert.NoError(t, err)
 		assert.False(t, exists)
 	})
 
 	t.Run("empty parameters error", func(t *testing.T) {
-		_, err := repo.ExistsByUserIDAndName("", "Portfolio")
+		_, err := repo.ExistsByUserIDAndName(ctx, "", "Portfolio")
 		assert.Error(t, err)
 
-		_, err = repo.ExistsByUserIDAndName(user.ID.String(), "")
+		_, err = repo.ExistsByUserIDAndName(ctx, user.ID.String(), "")
 		assert.Error(t, err)
 	})
 }
+
+func TestPortfolioRepository_PeerComparisonOptIn(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPortfolioRepoTestDB(t)
+	repo := NewPortfolioRepository(db)
+	user := createTestUser(t, db)
+
+	optedIn := &models.Portfolio{UserID: user.ID, Name: "Opted In", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	other := &models.Portfolio{UserID: user.ID, Name: "Other", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	assert.NoError(t, repo.Create(ctx, optedIn))
+	assert.NoError(t, repo.Create(ctx, other))
+
+	t.Run("opt in", func(t *testing.T) {
+		assert.NoError(t, repo.SetPeerComparisonOptIn(ctx, optedIn.ID.String(), true))
+
+		portfolios, err := repo.FindPeerComparisonOptedIn(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, portfolios, 1)
+		assert.Equal(t, optedIn.ID, portfolios[0].ID)
+	})
+
+	t.Run("opt out persists false", func(t *testing.T) {
+		assert.NoError(t, repo.SetPeerComparisonOptIn(ctx, optedIn.ID.String(), false))
+
+		portfolios, err := repo.FindPeerComparisonOptedIn(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, portfolios)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		err := repo.SetPeerComparisonOptIn(ctx, uuid.New().String(), true)
+		assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+	})
+}
+
+func TestPortfolioRepository_OrganizationPortfolios(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPortfolioRepoTestDB(t)
+	repo := NewPortfolioRepository(db)
+	user := createTestUser(t, db)
+	heir := &models.User{ID: uuid.New(), Email: "heir@example.com", PasswordHash: "hash"}
+	assert.NoError(t, db.Create(heir).Error)
+
+	organizationID := uuid.New()
+	shared := &models.Portfolio{UserID: user.ID, OrganizationID: &organizationID, Name: "Client", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	personal := &models.Portfolio{UserID: user.ID, Name: "Personal", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	assert.NoError(t, repo.Create(ctx, shared))
+	assert.NoError(t, repo.Create(ctx, personal))
+
+	portfolios, err := repo.FindByOrganizationID(ctx, organizationID)
+	assert.NoError(t, err)
+	assert.Len(t, portfolios, 1)
+	assert.Equal(t, shared.ID, portfolios[0].ID)
+
+	t.Run("reassign only moves organization portfolios", func(t *testing.T) {
+		moved, err := repo.ReassignOrganizationPortfolios(ctx, organizationID, user.ID, heir.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), moved)
+
+		found, err := repo.FindByID(ctx, shared.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, heir.ID, found.UserID)
+		found, err = repo.FindByID(ctx, personal.ID.String())
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, found.UserID)
+	})
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// PushDeviceRepository defines the interface for push device data operations
+type PushDeviceRepository interface {
+	Register(ctx context.Context, device *models.PushDevice) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.PushDevice, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PushDevice, error)
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error)
+	MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// pushDeviceRepository implements PushDeviceRepository interface
+type pushDeviceRepository struct {
+	db *gorm.DB
+}
+
+// NewPushDeviceRepository creates a new PushDeviceRepository instance
+func NewPushDeviceRepository(db *gorm.DB) PushDeviceRepository {
+	return &pushDeviceRepository{db: db}
+}
+
+// Register stores a device, or updates the device already registered with its endpoint,
+// which then keeps its ID and moves to the device's user. A browser that signs in as another
+// user thereby stops receiving the previous user's notifications.
+func (r *pushDeviceRepository) Register(ctx context.Context, device *models.PushDevice) error {
+	if device == nil {
+		return fmt.Errorf("device cannot be nil")
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.PushDevice
+		err := tx.Where("endpoint = ?", device.Endpoint).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(device).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		device.ID = existing.ID
+		device.CreatedAt = existing.CreatedAt
+		device.LastUsedAt = existing.LastUsedAt
+		return tx.Model(&existing).Updates(map[string]any{
+			"user_id":  device.UserID,
+			"platform": device.Platform,
+			"p256dh":   device.P256dh,
+			"auth":     device.Auth,
+			"name":     device.Name,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register push device: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a device by ID
+func (r *pushDeviceRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.PushDevice, error) {
+	var device models.PushDevice
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, models.ErrPushDeviceNotFound
+		}
+		return nil, fmt.Errorf("failed to find push device: %w", err)
+	}
+
+	return &device, nil
+}
+
+// FindByUserID finds a user's devices, oldest first
+func (r *pushDeviceRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]*models.PushDevice, error) {
+	var devices []*models.PushDevice
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to find push devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// CountByUserID counts a user's devices
+func (r *pushDeviceRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.PushDevice{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count push devices: %w", err)
+	}
+
+	return count, nil
+}
+
+// MarkUsed records when a notification was last delivered to a device
+func (r *pushDeviceRepository) MarkUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	if err := r.db.WithContext(ctx).Model(&models.PushDevice{}).Where("id = ?", id).Update("last_used_at", usedAt).Error; err != nil {
+		return fmt.Errorf("failed to update push device: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a device
+func (r *pushDeviceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Where("id = ?", id).Delete(&models.PushDevice{}).Error; err != nil {
+		return fmt.Errorf("failed to delete push device: %w", err)
+	}
+
+	return nil
+}
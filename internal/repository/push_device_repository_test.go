@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestPushDeviceRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.PushDevice{}))
+	repo := NewPushDeviceRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	device := &models.PushDevice{UserID: userID, Platform: models.PushPlatformAPNs, Endpoint: "a1b2c3", Name: "iPhone"}
+	require.NoError(t, repo.Register(ctx, device))
+	require.NoError(t, repo.Register(ctx, &models.PushDevice{UserID: userID, Platform: models.PushPlatformAPNs, Endpoint: "d4e5f6"}))
+
+	usedAt := time.Date(2024, time.June, 14, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.MarkUsed(ctx, device.ID, usedAt))
+
+	// Registering the endpoint again moves it to the new user, keeping its ID
+	otherUserID := uuid.New()
+	again := &models.PushDevice{UserID: otherUserID, Platform: models.PushPlatformAPNs, Endpoint: "a1b2c3", Name: "Shared iPad"}
+	require.NoError(t, repo.Register(ctx, again))
+	assert.Equal(t, device.ID, again.ID)
+
+	count, err := repo.CountByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	found, err := repo.FindByID(ctx, device.ID)
+	require.NoError(t, err)
+	assert.Equal(t, otherUserID, found.UserID)
+	assert.Equal(t, "Shared iPad", found.Name)
+	require.NotNil(t, found.LastUsedAt)
+	assert.True(t, usedAt.Equal(*found.LastUsedAt))
+
+	devices, err := repo.FindByUserID(ctx, otherUserID)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+
+	require.NoError(t, repo.Delete(ctx, device.ID))
+	_, err = repo.FindByID(ctx, device.ID)
+	assert.Equal(t, models.ErrPushDeviceNotFound, err)
+}
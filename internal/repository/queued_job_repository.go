@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// QueuedJobRepository defines the interface for job queue data operations
+type QueuedJobRepository interface {
+	Create(ctx context.Context, job *models.QueuedJob) error
+	FindByID(ctx context.Context, id string) (*models.QueuedJob, error)
+	FindByUserID(ctx context.Context, userID string, limit int) ([]*models.QueuedJob, error)
+	ClaimNext(ctx context.Context, types []models.QueuedJobType) (*models.QueuedJob, error)
+	UpdateProgress(ctx context.Context, id uuid.UUID, progress string) error
+	Heartbeat(ctx context.Context, id uuid.UUID) error
+	Finish(ctx context.Context, id uuid.UUID, status models.QueuedJobStatus, result, errMessage string) error
+	Requeue(ctx context.Context, id uuid.UUID) error
+	FailStale(ctx context.Context, heartbeatBefore time.Time, errMessage string) (int64, error)
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// queuedJobRepository implements QueuedJobRepository interface
+type queuedJobRepository struct {
+	db *gorm.DB
+}
+
+// NewQueuedJobRepository creates a new QueuedJobRepository instance
+func NewQueuedJobRepository(db *gorm.DB) QueuedJobRepository {
+	return &queuedJobRepository{db: db}
+}
+
+// Create adds a job to the queue
+func (r *queuedJobRepository) Create(ctx context.Context, job *models.QueuedJob) error {
+	if job == nil {
+		return fmt.Errorf("job cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a job by ID
+func (r *queuedJobRepository) FindByID(ctx context.Context, id string) (*models.QueuedJob, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	jobID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job ID format: %w", err)
+	}
+
+	var job models.QueuedJob
+	if err := r.db.WithContext(ctx).Where("id = ?", jobID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrQueuedJobNotFound
+		}
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+
+	return &job, nil
+}
+
+// FindByUserID finds a user's most recent jobs, newest first
+func (r *queuedJobRepository) FindByUserID(ctx context.Context, userID string, limit int) ([]*models.QueuedJob, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	var jobs []*models.QueuedJob
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", uid).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&jobs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// ClaimNext marks the oldest queued job of one of types as running and returns it, or
+// returns nil if there is none. A job is only claimed by one worker, even across processes:
+// Postgres skips rows another worker has locked, and the status check on the update makes a
+// lost race claim nothing.
+func (r *queuedJobRepository) ClaimNext(ctx context.Context, types []models.QueuedJobType) (*models.QueuedJob, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	var claimed *models.QueuedJob
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("status = ? AND type IN ?", models.QueuedJobStatusQueued, types).
+			Order("created_at ASC").
+			Limit(1)
+		if tx.Dialector.Name() == "postgres" {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+		}
+
+		var job models.QueuedJob
+		if err := query.Find(&job).Error; err != nil {
+			return err
+		}
+		if job.ID == uuid.Nil {
+			return nil
+		}
+
+		now := time.Now().UTC()
+		result := tx.Model(&models.QueuedJob{}).
+			Where("id = ? AND status = ?", job.ID, models.QueuedJobStatusQueued).
+			Updates(map[string]interface{}{
+				"status":       models.QueuedJobStatusRunning,
+				"started_at":   now,
+				"heartbeat_at": now,
+				"updated_at":   now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		job.Status = models.QueuedJobStatusRunning
+		job.StartedAt = &now
+		job.HeartbeatAt = &now
+		job.UpdatedAt = now
+		claimed = &job
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return claimed, nil
+}
+
+// UpdateProgress stores a running job's latest progress, which also counts as a heartbeat
+func (r *queuedJobRepository) UpdateProgress(ctx context.Context, id uuid.UUID, progress string) error {
+	now := time.Now().UTC()
+	return r.updateRunning(ctx, id, map[string]interface{}{
+		"progress":     progress,
+		"heartbeat_at": now,
+		"updated_at":   now,
+	})
+}
+
+// Heartbeat records that a running job's worker is still alive
+func (r *queuedJobRepository) Heartbeat(ctx context.Context, id uuid.UUID) error {
+	return r.updateRunning(ctx, id, map[string]interface{}{
+		"heartbeat_at": time.Now().UTC(),
+	})
+}
+
+// Finish records the outcome of a running job
+func (r *queuedJobRepository) Finish(ctx context.Context, id uuid.UUID, status models.QueuedJobStatus, result, errMessage string) error {
+	if !status.IsFinished() {
+		return fmt.Errorf("invalid final job status %q", status)
+	}
+
+	now := time.Now().UTC()
+	return r.updateRunning(ctx, id, map[string]interface{}{
+		"status":      status,
+		"result":      result,
+		"error":       errMessage,
+		"finished_at": now,
+		"updated_at":  now,
+	})
+}
+
+// Requeue puts a running job back in the queue, keeping its progress, for a job that
+// stopped at a checkpoint it can resume from
+func (r *queuedJobRepository) Requeue(ctx context.Context, id uuid.UUID) error {
+	return r.updateRunning(ctx, id, map[string]interface{}{
+		"status":       models.QueuedJobStatusQueued,
+		"started_at":   nil,
+		"heartbeat_at": nil,
+		"updated_at":   time.Now().UTC(),
+	})
+}
+
+// updateRunning updates a job that is still running. A job that was failed as stale in
+// the meantime is left alone.
+func (r *queuedJobRepository) updateRunning(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error {
+	result := r.db.WithContext(ctx).Model(&models.QueuedJob{}).
+		Where("id = ? AND status = ?", id, models.QueuedJobStatusRunning).
+		Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update job: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ErrQueuedJobNotFound
+	}
+
+	return nil
+}
+
+// FailStale fails running jobs whose worker hasn't sent a heartbeat since heartbeatBefore,
+// such as jobs of a worker that crashed. They are not retried, since an operation stopped
+// partway may not be safe to run twice.
+func (r *queuedJobRepository) FailStale(ctx context.Context, heartbeatBefore time.Time, errMessage string) (int64, error) {
+	now := time.Now().UTC()
+	result := r.db.WithContext(ctx).Model(&models.QueuedJob{}).
+		Where("status = ? AND heartbeat_at < ?", models.QueuedJobStatusRunning, heartbeatBefore).
+		Updates(map[string]interface{}{
+			"status":      models.QueuedJobStatusFailed,
+			"error":       errMessage,
+			"finished_at": now,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to fail stale jobs: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteFinishedBefore deletes jobs that finished before the given time
+func (r *queuedJobRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status IN ? AND finished_at < ?",
+			[]models.QueuedJobStatus{models.QueuedJobStatusSucceeded, models.QueuedJobStatusFailed}, before).
+		Delete(&models.QueuedJob{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete finished jobs: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
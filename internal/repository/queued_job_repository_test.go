@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupQueuedJobTestDB(t *testing.T) (*gorm.DB, *models.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.QueuedJob{}))
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	return db, user
+}
+
+func TestQueuedJobRepository_CreateAndFind(t *testing.T) {
+	ctx := context.Background()
+
+	db, user := setupQueuedJobTestDB(t)
+	repo := NewQueuedJobRepository(db)
+
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, jobType := range []models.QueuedJobType{models.QueuedJobTypeRecalculation, models.QueuedJobTypeTaxReport} {
+		job := &models.QueuedJob{UserID: user.ID, Type: jobType, CreatedAt: created.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, repo.Create(ctx, job))
+		assert.Equal(t, models.QueuedJobStatusQueued, job.Status)
+		assert.Equal(t, "{}", job.Payload)
+	}
+
+	jobs, err := repo.FindByUserID(ctx, user.ID.String(), 10)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, models.QueuedJobTypeTaxReport, jobs[0].Type, "newest first")
+
+	jobs, err = repo.FindByUserID(ctx, user.ID.String(), 1)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+
+	found, err := repo.FindByID(ctx, jobs[0].ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, jobs[0].ID, found.ID)
+
+	_, err = repo.FindByID(ctx, uuid.NewString())
+	assert.ErrorIs(t, err, models.ErrQueuedJobNotFound)
+}
+
+func TestQueuedJobRepository_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	db, user := setupQueuedJobTestDB(t)
+	repo := NewQueuedJobRepository(db)
+
+	first := &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeRecalculation, CreatedAt: time.Now().UTC().Add(-time.Minute)}
+	second := &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeTaxReport}
+	require.NoError(t, repo.Create(ctx, first))
+	require.NoError(t, repo.Create(ctx, second))
+
+	// Only the requested types are claimed
+	claimed, err := repo.ClaimNext(ctx, []models.QueuedJobType{models.QueuedJobTypeCSVImport})
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	// Oldest first
+	claimed, err = repo.ClaimNext(ctx, []models.QueuedJobType{models.QueuedJobTypeRecalculation, models.QueuedJobTypeTaxReport})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, first.ID, claimed.ID)
+	assert.Equal(t, models.QueuedJobStatusRunning, claimed.Status)
+	assert.NotNil(t, claimed.StartedAt)
+
+	require.NoError(t, repo.UpdateProgress(ctx, first.ID, `{"done":1}`))
+	require.NoError(t, repo.Finish(ctx, first.ID, models.QueuedJobStatusSucceeded, `{"ok":true}`, ""))
+	assert.Error(t, repo.Finish(ctx, first.ID, models.QueuedJobStatusRunning, "", ""))
+
+	found, err := repo.FindByID(ctx, first.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobStatusSucceeded, found.Status)
+	assert.Equal(t, `{"done":1}`, found.Progress)
+	assert.Equal(t, `{"ok":true}`, found.Result)
+	assert.NotNil(t, found.FinishedAt)
+
+	// A finished job can't be updated again
+	assert.ErrorIs(t, repo.Heartbeat(ctx, first.ID), models.ErrQueuedJobNotFound)
+
+	claimed, err = repo.ClaimNext(ctx, []models.QueuedJobType{models.QueuedJobTypeTaxReport})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, second.ID, claimed.ID)
+
+	claimed, err = repo.ClaimNext(ctx, []models.QueuedJobType{models.QueuedJobTypeTaxReport})
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "a running job is not claimed twice")
+
+	t.Run("stale jobs are failed", func(t *testing.T) {
+		failed, err := repo.FailStale(ctx, time.Now().UTC().Add(-time.Minute), "worker stopped")
+		require.NoError(t, err)
+		assert.Zero(t, failed)
+
+		failed, err = repo.FailStale(ctx, time.Now().UTC().Add(time.Minute), "worker stopped")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), failed)
+
+		found, err := repo.FindByID(ctx, second.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, models.QueuedJobStatusFailed, found.Status)
+		assert.Equal(t, "worker stopped", found.Error)
+
+		// The worker's late result doesn't overwrite the failure
+		assert.ErrorIs(t, repo.Finish(ctx, second.ID, models.QueuedJobStatusSucceeded, "{}", ""), models.ErrQueuedJobNotFound)
+	})
+
+	t.Run("finished jobs are pruned", func(t *testing.T) {
+		queued := &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeTaxReport}
+		require.NoError(t, repo.Create(ctx, queued))
+
+		deleted, err := repo.DeleteFinishedBefore(ctx, time.Now().UTC().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		jobs, err := repo.FindByUserID(ctx, user.ID.String(), 10)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		assert.Equal(t, queued.ID, jobs[0].ID)
+	})
+}
+
+func TestQueuedJobRepository_Requeue(t *testing.T) {
+	ctx := context.Background()
+
+	db, user := setupQueuedJobTestDB(t)
+	repo := NewQueuedJobRepository(db)
+
+	job := &models.QueuedJob{UserID: user.ID, Type: models.QueuedJobTypeCSVImport}
+	require.NoError(t, repo.Create(ctx, job))
+	types := []models.QueuedJobType{models.QueuedJobTypeCSVImport}
+
+	claimed, err := repo.ClaimNext(ctx, types)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.NoError(t, repo.UpdateProgress(ctx, job.ID, `{"processed_rows":3}`))
+	require.NoError(t, repo.Requeue(ctx, job.ID))
+
+	found, err := repo.FindByID(ctx, job.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobStatusQueued, found.Status)
+	assert.Nil(t, found.StartedAt)
+	assert.Nil(t, found.HeartbeatAt)
+	assert.Equal(t, `{"processed_rows":3}`, found.Progress, "the checkpoint is kept")
+
+	// Only running jobs go back in the queue
+	assert.ErrorIs(t, repo.Requeue(ctx, job.ID), models.ErrQueuedJobNotFound)
+
+	claimed, err = repo.ClaimNext(ctx, types)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, job.ID, claimed.ID)
+	assert.Equal(t, `{"processed_rows":3}`, claimed.Progress)
+}
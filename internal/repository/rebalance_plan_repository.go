@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// RebalancePlanRepository defines the interface for rebalancing execution plan data operations
+type RebalancePlanRepository interface {
+	Create(ctx context.Context, plan *models.RebalancePlan) error
+	FindByID(ctx context.Context, id string) (*models.RebalancePlan, error)
+	FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.RebalancePlan, error)
+	FindActive(ctx context.Context) ([]*models.RebalancePlan, error)
+	Update(ctx context.Context, plan *models.RebalancePlan) error
+	UpdateTrade(ctx context.Context, trade *models.RebalancePlanTrade) error
+	Delete(ctx context.Context, id string) error
+}
+
+// rebalancePlanRepository implements RebalancePlanRepository interface
+type rebalancePlanRepository struct {
+	db *gorm.DB
+}
+
+// NewRebalancePlanRepository creates a new RebalancePlanRepository instance
+func NewRebalancePlanRepository(db *gorm.DB) RebalancePlanRepository {
+	return &rebalancePlanRepository{db: db}
+}
+
+// orderRebalanceTrades loads a plan's trades in the order they were planned
+func orderRebalanceTrades(db *gorm.DB) *gorm.DB {
+	return db.Order("created_at ASC")
+}
+
+// Create creates a new rebalance plan together with its trades
+func (r *rebalancePlanRepository) Create(ctx context.Context, plan *models.RebalancePlan) error {
+	if plan == nil {
+		return fmt.Errorf("rebalance plan cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(plan).Error; err != nil {
+		return fmt.Errorf("failed to create rebalance plan: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a rebalance plan by ID, including its trades
+func (r *rebalancePlanRepository) FindByID(ctx context.Context, id string) (*models.RebalancePlan, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	planID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rebalance plan ID format: %w", err)
+	}
+
+	var plan models.RebalancePlan
+	if err := r.db.WithContext(ctx).Preload("Trades", orderRebalanceTrades).Where("id = ?", planID).First(&plan).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrRebalancePlanNotFound
+		}
+		return nil, fmt.Errorf("failed to find rebalance plan: %w", err)
+	}
+
+	return &plan, nil
+}
+
+// FindByPortfolioID finds all rebalance plans for a portfolio, newest first
+func (r *rebalancePlanRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.RebalancePlan, error) {
+	if portfolioID == "" {
+		return nil, fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var plans []*models.RebalancePlan
+	if err := r.db.WithContext(ctx).Preload("Trades", orderRebalanceTrades).
+		Where("portfolio_id = ?", pid).
+		Order("created_at DESC").
+		Find(&plans).Error; err != nil {
+		return nil, fmt.Errorf("failed to find rebalance plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+// FindActive finds all active rebalance plans across portfolios, including their
+// trades and owning portfolio
+func (r *rebalancePlanRepository) FindActive(ctx context.Context) ([]*models.RebalancePlan, error) {
+	var plans []*models.RebalancePlan
+	if err := r.db.WithContext(ctx).Preload("Trades", orderRebalanceTrades).
+		Preload("Portfolio").
+		Where("status = ?", models.RebalancePlanStatusActive).
+		Order("created_at ASC").
+		Find(&plans).Error; err != nil {
+		return nil, fmt.Errorf("failed to find active rebalance plans: %w", err)
+	}
+
+	return plans, nil
+}
+
+// Update updates a rebalance plan's own fields. Trades are updated with UpdateTrade.
+func (r *rebalancePlanRepository) Update(ctx context.Context, plan *models.RebalancePlan) error {
+	if plan == nil {
+		return fmt.Errorf("rebalance plan cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Omit("Trades", "Portfolio").Save(plan).Error; err != nil {
+		return fmt.Errorf("failed to update rebalance plan: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateTrade updates a single planned trade
+func (r *rebalancePlanRepository) UpdateTrade(ctx context.Context, trade *models.RebalancePlanTrade) error {
+	if trade == nil {
+		return fmt.Errorf("rebalance plan trade cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Save(trade).Error; err != nil {
+		return fmt.Errorf("failed to update rebalance plan trade: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a rebalance plan and its trades
+func (r *rebalancePlanRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	planID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid rebalance plan ID format: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("plan_id = ?", planID).Delete(&models.RebalancePlanTrade{}).Error; err != nil {
+			return fmt.Errorf("failed to delete rebalance plan trades: %w", err)
+		}
+
+		result := tx.Where("id = ?", planID).Delete(&models.RebalancePlan{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete rebalance plan: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrRebalancePlanNotFound
+		}
+
+		return nil
+	})
+}
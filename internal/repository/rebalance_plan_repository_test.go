@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupRebalancePlanTestDB(t *testing.T) (*gorm.DB, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{},
+		&models.RebalancePlan{}, &models.RebalancePlanTrade{})
+	require.NoError(t, err)
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Test Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	return db, portfolio
+}
+
+func newTestRebalancePlan(portfolio *models.Portfolio, name string) *models.RebalancePlan {
+	return &models.RebalancePlan{
+		PortfolioID: portfolio.ID,
+		Name:        name,
+		Trades: []*models.RebalancePlanTrade{
+			{Symbol: "VTI", Side: models.TransactionTypeBuy, PlannedQuantity: decimal.NewFromInt(10), PlannedPrice: decimal.NewFromInt(250)},
+			{Symbol: "BND", Side: models.TransactionTypeSell, PlannedQuantity: decimal.NewFromInt(20), PlannedPrice: decimal.NewFromInt(75)},
+		},
+	}
+}
+
+func TestRebalancePlanRepository_CreateAndFind(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupRebalancePlanTestDB(t)
+	repo := NewRebalancePlanRepository(db)
+
+	plan := newTestRebalancePlan(portfolio, "Q3 rebalance")
+	require.NoError(t, repo.Create(ctx, plan))
+	assert.Equal(t, models.RebalancePlanStatusActive, plan.Status)
+	assert.Equal(t, models.DefaultRebalancePlanStaleAfterDays, plan.StaleAfterDays)
+
+	found, err := repo.FindByID(ctx, plan.ID.String())
+	require.NoError(t, err)
+	require.Len(t, found.Trades, 2)
+	assert.Equal(t, models.RebalanceTradeStatusPending, found.Trades[0].Status)
+	assert.Equal(t, plan.ID, found.Trades[0].PlanID)
+
+	plans, err := repo.FindByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	require.Len(t, plans, 1)
+	assert.Len(t, plans[0].Trades, 2)
+
+	_, err = repo.FindByID(ctx, "00000000-0000-0000-0000-000000000001")
+	assert.Equal(t, models.ErrRebalancePlanNotFound, err)
+}
+
+func TestRebalancePlanRepository_UpdateAndFindActive(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupRebalancePlanTestDB(t)
+	repo := NewRebalancePlanRepository(db)
+
+	active := newTestRebalancePlan(portfolio, "Active")
+	require.NoError(t, repo.Create(ctx, active))
+	cancelled := newTestRebalancePlan(portfolio, "Cancelled")
+	require.NoError(t, repo.Create(ctx, cancelled))
+
+	now := time.Now().UTC()
+	cancelled.Status = models.RebalancePlanStatusCancelled
+	cancelled.ClosedAt = &now
+	require.NoError(t, repo.Update(ctx, cancelled))
+
+	quantity := decimal.NewFromInt(10)
+	price := decimal.NewFromInt(251)
+	trade := active.Trades[0]
+	trade.Status = models.RebalanceTradeStatusExecuted
+	trade.ExecutedQuantity = &quantity
+	trade.ExecutedPrice = &price
+	trade.ExecutedAt = &now
+	require.NoError(t, repo.UpdateTrade(ctx, trade))
+
+	plans, err := repo.FindActive(ctx)
+	require.NoError(t, err)
+	require.Len(t, plans, 1)
+	assert.Equal(t, active.ID, plans[0].ID)
+	require.NotNil(t, plans[0].Portfolio)
+	assert.Equal(t, portfolio.UserID, plans[0].Portfolio.UserID)
+	assert.Equal(t, models.RebalanceTradeStatusExecuted, plans[0].Trades[0].Status)
+	assert.True(t, price.Equal(*plans[0].Trades[0].ExecutedPrice))
+}
+
+func TestRebalancePlanRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupRebalancePlanTestDB(t)
+	repo := NewRebalancePlanRepository(db)
+
+	plan := newTestRebalancePlan(portfolio, "Q3 rebalance")
+	require.NoError(t, repo.Create(ctx, plan))
+
+	require.NoError(t, repo.Delete(ctx, plan.ID.String()))
+	assert.Equal(t, models.ErrRebalancePlanNotFound, repo.Delete(ctx, plan.ID.String()))
+
+	var trades int64
+	require.NoError(t, db.Model(&models.RebalancePlanTrade{}).Count(&trades).Error)
+	assert.Zero(t, trades)
+}
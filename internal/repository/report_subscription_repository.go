@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// ReportSubscriptionRepository defines the interface for report subscription data operations
+type ReportSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *models.ReportSubscription) error
+	FindByID(ctx context.Context, id string) (*models.ReportSubscription, error)
+	FindByUserID(ctx context.Context, userID string) ([]*models.ReportSubscription, error)
+	FindEnabled(ctx context.Context) ([]*models.ReportSubscription, error)
+	Update(ctx context.Context, subscription *models.ReportSubscription) error
+	Delete(ctx context.Context, id string) error
+}
+
+// reportSubscriptionRepository implements ReportSubscriptionRepository interface
+type reportSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewReportSubscriptionRepository creates a new ReportSubscriptionRepository instance
+func NewReportSubscriptionRepository(db *gorm.DB) ReportSubscriptionRepository {
+	return &reportSubscriptionRepository{db: db}
+}
+
+// Create creates a new report subscription together with its portfolios
+func (r *reportSubscriptionRepository) Create(ctx context.Context, subscription *models.ReportSubscription) error {
+	if subscription == nil {
+		return fmt.Errorf("report subscription cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(subscription).Error; err != nil {
+		return fmt.Errorf("failed to create report subscription: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a report subscription by ID, including its portfolios
+func (r *reportSubscriptionRepository) FindByID(ctx context.Context, id string) (*models.ReportSubscription, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report subscription ID format: %w", err)
+	}
+
+	var subscription models.ReportSubscription
+	if err := r.db.WithContext(ctx).Preload("Portfolios").Where("id = ?", subscriptionID).First(&subscription).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrReportSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("failed to find report subscription: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+// FindByUserID finds all of a user's report subscriptions, oldest first
+func (r *reportSubscriptionRepository) FindByUserID(ctx context.Context, userID string) ([]*models.ReportSubscription, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	var subscriptions []*models.ReportSubscription
+	if err := r.db.WithContext(ctx).Preload("Portfolios").
+		Where("user_id = ?", uid).
+		Order("created_at ASC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to find report subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// FindEnabled finds all enabled report subscriptions across users, including their portfolios
+func (r *reportSubscriptionRepository) FindEnabled(ctx context.Context) ([]*models.ReportSubscription, error) {
+	var subscriptions []*models.ReportSubscription
+	if err := r.db.WithContext(ctx).Preload("Portfolios").
+		Where("enabled = ?", true).
+		Order("created_at ASC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to find enabled report subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// Update updates a report subscription and replaces its portfolios with the ones it lists
+func (r *reportSubscriptionRepository) Update(ctx context.Context, subscription *models.ReportSubscription) error {
+	if subscription == nil {
+		return fmt.Errorf("report subscription cannot be nil")
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Portfolios").Save(subscription).Error; err != nil {
+			return fmt.Errorf("failed to update report subscription: %w", err)
+		}
+
+		if err := tx.Where("subscription_id = ?", subscription.ID).Delete(&models.ReportSubscriptionPortfolio{}).Error; err != nil {
+			return fmt.Errorf("failed to update report subscription portfolios: %w", err)
+		}
+		for _, portfolio := range subscription.Portfolios {
+			portfolio.SubscriptionID = subscription.ID
+		}
+		if len(subscription.Portfolios) > 0 {
+			if err := tx.Create(subscription.Portfolios).Error; err != nil {
+				return fmt.Errorf("failed to update report subscription portfolios: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete deletes a report subscription and its portfolios
+func (r *reportSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	subscriptionID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid report subscription ID format: %w", err)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", subscriptionID).Delete(&models.ReportSubscriptionPortfolio{}).Error; err != nil {
+			return fmt.Errorf("failed to delete report subscription portfolios: %w", err)
+		}
+
+		result := tx.Where("id = ?", subscriptionID).Delete(&models.ReportSubscription{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete report subscription: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrReportSubscriptionNotFound
+		}
+
+		return nil
+	})
+}
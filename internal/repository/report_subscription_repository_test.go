@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupReportSubscriptionTestDB(t *testing.T) (*gorm.DB, *models.User, []*models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{},
+		&models.ReportSubscription{}, &models.ReportSubscriptionPortfolio{})
+	require.NoError(t, err)
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	var portfolios []*models.Portfolio
+	for _, name := range []string{"Retirement", "Brokerage"} {
+		portfolio := &models.Portfolio{
+			UserID:          user.ID,
+			Name:            name,
+			BaseCurrency:    "USD",
+			CostBasisMethod: models.CostBasisFIFO,
+		}
+		require.NoError(t, db.Create(portfolio).Error)
+		portfolios = append(portfolios, portfolio)
+	}
+
+	return db, user, portfolios
+}
+
+func TestReportSubscriptionRepository_CreateAndFind(t *testing.T) {
+	ctx := context.Background()
+
+	db, user, portfolios := setupReportSubscriptionTestDB(t)
+	repo := NewReportSubscriptionRepository(db)
+
+	subscription := &models.ReportSubscription{
+		UserID:     user.ID,
+		Frequency:  models.ReportFrequencyWeekly,
+		Enabled:    true,
+		Portfolios: []*models.ReportSubscriptionPortfolio{{PortfolioID: portfolios[0].ID}},
+	}
+	require.NoError(t, repo.Create(ctx, subscription))
+	assert.NotEqual(t, uuid.Nil, subscription.ID)
+
+	found, err := repo.FindByID(ctx, subscription.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportFrequencyWeekly, found.Frequency)
+	assert.Equal(t, []string{portfolios[0].ID.String()}, found.PortfolioIDs())
+
+	byUser, err := repo.FindByUserID(ctx, user.ID.String())
+	require.NoError(t, err)
+	assert.Len(t, byUser, 1)
+
+	_, err = repo.FindByID(ctx, uuid.New().String())
+	assert.ErrorIs(t, err, models.ErrReportSubscriptionNotFound)
+}
+
+func TestReportSubscriptionRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
+	db, user, portfolios := setupReportSubscriptionTestDB(t)
+	repo := NewReportSubscriptionRepository(db)
+
+	subscription := &models.ReportSubscription{
+		UserID:     user.ID,
+		Frequency:  models.ReportFrequencyWeekly,
+		Enabled:    true,
+		Portfolios: []*models.ReportSubscriptionPortfolio{{PortfolioID: portfolios[0].ID}},
+	}
+	require.NoError(t, repo.Create(ctx, subscription))
+
+	sentAt := time.Date(2025, 1, 6, 6, 0, 0, 0, time.UTC)
+	subscription.Frequency = models.ReportFrequencyMonthly
+	subscription.LastSentAt = &sentAt
+	subscription.Portfolios = []*models.ReportSubscriptionPortfolio{{PortfolioID: portfolios[1].ID}}
+	require.NoError(t, repo.Update(ctx, subscription))
+
+	found, err := repo.FindByID(ctx, subscription.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportFrequencyMonthly, found.Frequency)
+	assert.True(t, sentAt.Equal(*found.LastSentAt))
+	assert.Equal(t, []string{portfolios[1].ID.String()}, found.PortfolioIDs())
+
+	t.Run("disabled subscriptions are not enabled", func(t *testing.T) {
+		found.Enabled = false
+		require.NoError(t, repo.Update(ctx, found))
+
+		enabled, err := repo.FindEnabled(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, enabled)
+	})
+}
+
+func TestReportSubscriptionRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	db, user, portfolios := setupReportSubscriptionTestDB(t)
+	repo := NewReportSubscriptionRepository(db)
+
+	subscription := &models.ReportSubscription{
+		UserID:     user.ID,
+		Frequency:  models.ReportFrequencyMonthly,
+		Enabled:    true,
+		Portfolios: []*models.ReportSubscriptionPortfolio{{PortfolioID: portfolios[0].ID}},
+	}
+	require.NoError(t, repo.Create(ctx, subscription))
+
+	require.NoError(t, repo.Delete(ctx, subscription.ID.String()))
+
+	var links int64
+	require.NoError(t, db.Model(&models.ReportSubscriptionPortfolio{}).Count(&links).Error)
+	assert.Zero(t, links)
+
+	err := repo.Delete(ctx, subscription.ID.String())
+	assert.ErrorIs(t, err, models.ErrReportSubscriptionNotFound)
+}
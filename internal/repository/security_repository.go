@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// SecurityRepository defines the interface for security data operations. Securities are
+// shared by every user.
+type SecurityRepository interface {
+	Upsert(ctx context.Context, securities []*models.Security) error
+	FindBySymbol(ctx context.Context, symbol string) (*models.Security, error)
+	Search(ctx context.Context, query string, limit int) ([]*models.Security, error)
+}
+
+// securityRepository implements SecurityRepository interface
+type securityRepository struct {
+	db *gorm.DB
+}
+
+// NewSecurityRepository creates a new SecurityRepository instance
+func NewSecurityRepository(db *gorm.DB) SecurityRepository {
+	return &securityRepository{db: db}
+}
+
+// Upsert saves securities, replacing the name, exchange, type and currency of those already
+// stored with the same symbol
+func (r *securityRepository) Upsert(ctx context.Context, securities []*models.Security) error {
+	if len(securities) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "symbol"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "exchange", "type", "currency", "updated_at"}),
+	}).Create(securities).Error
+	if err != nil {
+		return fmt.Errorf("failed to save securities: %w", err)
+	}
+
+	return nil
+}
+
+// FindBySymbol finds a security by its symbol
+func (r *securityRepository) FindBySymbol(ctx context.Context, symbol string) (*models.Security, error) {
+	var security models.Security
+	if err := r.db.WithContext(ctx).Where("symbol = ?", symbol).First(&security).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrSecurityNotFound
+		}
+		return nil, fmt.Errorf("failed to find security: %w", err)
+	}
+
+	return &security, nil
+}
+
+// Search finds securities whose symbol starts with query or whose name contains it, ignoring
+// case. An exact symbol match comes first, then symbol matches, then name matches.
+func (r *securityRepository) Search(ctx context.Context, query string, limit int) ([]*models.Security, error) {
+	pattern := strings.ToUpper(query)
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(pattern)
+
+	var securities []*models.Security
+	err := r.db.WithContext(ctx).
+		Where(`UPPER(symbol) LIKE ? ESCAPE '\' OR UPPER(name) LIKE ? ESCAPE '\'`, escaped+"%", "%"+escaped+"%").
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  `CASE WHEN UPPER(symbol) = ? THEN 0 WHEN UPPER(symbol) LIKE ? ESCAPE '\' THEN 1 ELSE 2 END, symbol`,
+			Vars: []interface{}{pattern, escaped + "%"},
+		}}).
+		Limit(limit).
+		Find(&securities).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search securities: %w", err)
+	}
+
+	return securities, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupSecurityTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Security{}))
+	return db
+}
+
+func TestSecurityRepository_UpsertAndFind(t *testing.T) {
+	repo := NewSecurityRepository(setupSecurityTestDB(t))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Upsert(ctx, []*models.Security{
+		{Symbol: "AAPL", Name: "Apple Inc", Exchange: "United States", Type: "Equity", Currency: "USD"},
+	}))
+	require.NoError(t, repo.Upsert(ctx, []*models.Security{
+		{Symbol: "AAPL", Name: "Apple Inc.", Exchange: "United States", Type: "Equity", Currency: "USD"},
+	}))
+
+	security, err := repo.FindBySymbol(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Equal(t, "Apple Inc.", security.Name)
+
+	_, err = repo.FindBySymbol(ctx, "MSFT")
+	assert.Equal(t, models.ErrSecurityNotFound, err)
+}
+
+func TestSecurityRepository_Search(t *testing.T) {
+	repo := NewSecurityRepository(setupSecurityTestDB(t))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Upsert(ctx, []*models.Security{
+		{Symbol: "APLE", Name: "Apple Hospitality REIT Inc"},
+		{Symbol: "AAPL", Name: "Apple Inc"},
+		{Symbol: "APP", Name: "AppLovin Corp"},
+		{Symbol: "MSFT", Name: "Microsoft Corporation"},
+		{Symbol: "A_B", Name: "Underscore Holdings"},
+	}))
+
+	securities, err := repo.Search(ctx, "app", 10)
+	require.NoError(t, err)
+	symbols := make([]string, len(securities))
+	for i, security := range securities {
+		symbols[i] = security.Symbol
+	}
+	// The exact symbol first, then symbols starting with the query, then names containing it
+	assert.Equal(t, []string{"APP", "AAPL", "APLE"}, symbols)
+
+	securities, err = repo.Search(ctx, "app", 1)
+	require.NoError(t, err)
+	assert.Len(t, securities, 1)
+
+	// Wildcards in the query match literally
+	securities, err = repo.Search(ctx, "a_", 10)
+	require.NoError(t, err)
+	require.Len(t, securities, 1)
+	assert.Equal(t, "A_B", securities[0].Symbol)
+}
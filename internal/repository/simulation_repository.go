@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// SimulationRepository defines the interface for stored simulation data operations
+type SimulationRepository interface {
+	Create(ctx context.Context, simulation *models.Simulation) error
+	FindByID(ctx context.Context, id string) (*models.Simulation, error)
+	FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Simulation, error)
+	Update(ctx context.Context, simulation *models.Simulation) error
+	SetJobID(ctx context.Context, id, jobID uuid.UUID) error
+	Delete(ctx context.Context, id string) error
+}
+
+// simulationRepository implements SimulationRepository interface
+type simulationRepository struct {
+	db *gorm.DB
+}
+
+// NewSimulationRepository creates a new SimulationRepository instance
+func NewSimulationRepository(db *gorm.DB) SimulationRepository {
+	return &simulationRepository{db: db}
+}
+
+// Create creates a new simulation
+func (r *simulationRepository) Create(ctx context.Context, simulation *models.Simulation) error {
+	if simulation == nil {
+		return fmt.Errorf("simulation cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(simulation).Error; err != nil {
+		return fmt.Errorf("failed to create simulation: %w", err)
+	}
+
+	return nil
+}
+
+// FindByID finds a simulation by ID
+func (r *simulationRepository) FindByID(ctx context.Context, id string) (*models.Simulation, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	simulationID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid simulation ID format: %w", err)
+	}
+
+	var simulation models.Simulation
+	if err := r.db.WithContext(ctx).Where("id = ?", simulationID).First(&simulation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrSimulationNotFound
+		}
+		return nil, fmt.Errorf("failed to find simulation: %w", err)
+	}
+
+	return &simulation, nil
+}
+
+// FindByPortfolioID finds a portfolio's simulations, newest first
+func (r *simulationRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Simulation, error) {
+	if portfolioID == "" {
+		return nil, fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var simulations []*models.Simulation
+	if err := r.db.WithContext(ctx).
+		Where("portfolio_id = ?", pid).
+		Order("created_at DESC").
+		Find(&simulations).Error; err != nil {
+		return nil, fmt.Errorf("failed to find simulations: %w", err)
+	}
+
+	return simulations, nil
+}
+
+// Update updates a simulation's status, result and error
+func (r *simulationRepository) Update(ctx context.Context, simulation *models.Simulation) error {
+	if simulation == nil {
+		return fmt.Errorf("simulation cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Model(simulation).
+		Select("status", "result", "error", "finished_at").
+		Updates(simulation).Error; err != nil {
+		return fmt.Errorf("failed to update simulation: %w", err)
+	}
+
+	return nil
+}
+
+// SetJobID records the job that runs a simulation. Only the job ID is written, so it can't
+// overwrite a status the job has already stored.
+func (r *simulationRepository) SetJobID(ctx context.Context, id, jobID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&models.Simulation{}).
+		Where("id = ?", id).
+		UpdateColumn("job_id", jobID).Error; err != nil {
+		return fmt.Errorf("failed to update simulation: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a simulation
+func (r *simulationRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	simulationID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid simulation ID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", simulationID).Delete(&models.Simulation{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete simulation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return models.ErrSimulationNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupSimulationTestDB(t *testing.T) (*gorm.DB, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Simulation{})
+	require.NoError(t, err)
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Retirement",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	return db, portfolio
+}
+
+func TestSimulationRepository_CreateAndFind(t *testing.T) {
+	db, portfolio := setupSimulationTestDB(t)
+	repo := NewSimulationRepository(db)
+	ctx := context.Background()
+
+	older := &models.Simulation{PortfolioID: portfolio.ID, Parameters: `{"years":30}`, CreatedAt: time.Now().Add(-time.Hour)}
+	require.NoError(t, repo.Create(ctx, older))
+	newer := &models.Simulation{PortfolioID: portfolio.ID, Parameters: `{"years":20}`}
+	require.NoError(t, repo.Create(ctx, newer))
+
+	found, err := repo.FindByID(ctx, older.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobStatusQueued, found.Status)
+	assert.Equal(t, `{"years":30}`, found.Parameters)
+
+	simulations, err := repo.FindByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	require.Len(t, simulations, 2)
+	assert.Equal(t, newer.ID, simulations[0].ID)
+
+	_, err = repo.FindByID(ctx, uuid.New().String())
+	assert.Equal(t, models.ErrSimulationNotFound, err)
+}
+
+func TestSimulationRepository_UpdateAndSetJobID(t *testing.T) {
+	db, portfolio := setupSimulationTestDB(t)
+	repo := NewSimulationRepository(db)
+	ctx := context.Background()
+
+	simulation := &models.Simulation{PortfolioID: portfolio.ID, Parameters: "{}"}
+	require.NoError(t, repo.Create(ctx, simulation))
+
+	finishedAt := time.Now().UTC()
+	simulation.Status = models.QueuedJobStatusSucceeded
+	simulation.Result = `{"depleted_paths":0}`
+	simulation.FinishedAt = &finishedAt
+	require.NoError(t, repo.Update(ctx, simulation))
+
+	// Recording the job afterwards keeps the stored status
+	jobID := uuid.New()
+	require.NoError(t, repo.SetJobID(ctx, simulation.ID, jobID))
+
+	found, err := repo.FindByID(ctx, simulation.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobStatusSucceeded, found.Status)
+	assert.Equal(t, `{"depleted_paths":0}`, found.Result)
+	require.NotNil(t, found.JobID)
+	assert.Equal(t, jobID, *found.JobID)
+	assert.NotNil(t, found.FinishedAt)
+}
+
+func TestSimulationRepository_Delete(t *testing.T) {
+	db, portfolio := setupSimulationTestDB(t)
+	repo := NewSimulationRepository(db)
+	ctx := context.Background()
+
+	simulation := &models.Simulation{PortfolioID: portfolio.ID, Parameters: "{}"}
+	require.NoError(t, repo.Create(ctx, simulation))
+
+	require.NoError(t, repo.Delete(ctx, simulation.ID.String()))
+	assert.Equal(t, models.ErrSimulationNotFound, repo.Delete(ctx, simulation.ID.String()))
+}
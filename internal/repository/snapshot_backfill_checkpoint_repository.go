@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// SnapshotBackfillCheckpointRepository defines the interface for snapshot backfill checkpoint
+// data operations
+type SnapshotBackfillCheckpointRepository interface {
+	FindByPortfolioID(ctx context.Context, portfolioID string) (*models.SnapshotBackfillCheckpoint, error)
+	Save(ctx context.Context, checkpoint *models.SnapshotBackfillCheckpoint) error
+}
+
+// snapshotBackfillCheckpointRepository implements SnapshotBackfillCheckpointRepository interface
+type snapshotBackfillCheckpointRepository struct {
+	db *gorm.DB
+}
+
+// NewSnapshotBackfillCheckpointRepository creates a new SnapshotBackfillCheckpointRepository instance
+func NewSnapshotBackfillCheckpointRepository(db *gorm.DB) SnapshotBackfillCheckpointRepository {
+	return &snapshotBackfillCheckpointRepository{db: db}
+}
+
+// FindByPortfolioID finds a portfolio's backfill checkpoint, returning nil if its snapshots
+// were never backfilled
+func (r *snapshotBackfillCheckpointRepository) FindByPortfolioID(ctx context.Context, portfolioID string) (*models.SnapshotBackfillCheckpoint, error) {
+	id, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var checkpoint models.SnapshotBackfillCheckpoint
+	err = r.db.WithContext(ctx).Where("portfolio_id = ?", id).First(&checkpoint).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find snapshot backfill checkpoint: %w", err)
+	}
+
+	return &checkpoint, nil
+}
+
+// Save stores a portfolio's backfill checkpoint, replacing the one saved before
+func (r *snapshotBackfillCheckpointRepository) Save(ctx context.Context, checkpoint *models.SnapshotBackfillCheckpoint) error {
+	if checkpoint == nil {
+		return fmt.Errorf("checkpoint cannot be nil")
+	}
+	checkpoint.UpdatedAt = time.Now().UTC()
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "portfolio_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"start_date", "last_completed_date", "updated_at"}),
+	}).Create(checkpoint).Error
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot backfill checkpoint: %w", err)
+	}
+
+	return nil
+}
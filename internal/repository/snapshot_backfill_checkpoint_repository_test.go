@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestSnapshotBackfillCheckpointRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.SnapshotBackfillCheckpoint{}))
+	repo := NewSnapshotBackfillCheckpointRepository(db)
+	ctx := context.Background()
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Growth", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+	portfolioID := portfolio.ID.String()
+
+	t.Run("never backfilled", func(t *testing.T) {
+		checkpoint, err := repo.FindByPortfolioID(ctx, portfolioID)
+		require.NoError(t, err)
+		assert.Nil(t, checkpoint)
+
+		_, err = repo.FindByPortfolioID(ctx, "not-a-uuid")
+		assert.Error(t, err)
+	})
+
+	t.Run("saving again moves the checkpoint", func(t *testing.T) {
+		start := time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, repo.Save(ctx, &models.SnapshotBackfillCheckpoint{
+			PortfolioID: portfolio.ID, StartDate: start, LastCompletedDate: start,
+		}))
+		require.NoError(t, repo.Save(ctx, &models.SnapshotBackfillCheckpoint{
+			PortfolioID: portfolio.ID, StartDate: start, LastCompletedDate: time.Date(2019, 6, 28, 0, 0, 0, 0, time.UTC),
+		}))
+
+		checkpoint, err := repo.FindByPortfolioID(ctx, portfolioID)
+		require.NoError(t, err)
+		require.NotNil(t, checkpoint)
+		assert.True(t, start.Equal(checkpoint.StartDate))
+		assert.True(t, time.Date(2019, 6, 28, 0, 0, 0, 0, time.UTC).Equal(checkpoint.LastCompletedDate))
+		assert.False(t, checkpoint.UpdatedAt.IsZero())
+
+		var count int64
+		require.NoError(t, db.Model(&models.SnapshotBackfillCheckpoint{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("nil checkpoints are rejected", func(t *testing.T) {
+		assert.Error(t, repo.Save(ctx, nil))
+	})
+}
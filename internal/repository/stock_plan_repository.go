@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// StockPlanRepository defines the interface for stock plan grant and event data operations
+type StockPlanRepository interface {
+	CreateGrant(ctx context.Context, grant *models.StockPlanGrant) error
+	FindGrantByID(ctx context.Context, id string) (*models.StockPlanGrant, error)
+	FindGrantsByPortfolioID(ctx context.Context, portfolioID string) ([]*models.StockPlanGrant, error)
+	UpdateGrant(ctx context.Context, grant *models.StockPlanGrant) error
+	DeleteGrant(ctx context.Context, id string) error
+	CreateEvent(ctx context.Context, event *models.StockPlanEvent) error
+	FindEventsByGrantID(ctx context.Context, grantID string) ([]*models.StockPlanEvent, error)
+}
+
+// stockPlanRepository implements StockPlanRepository interface
+type stockPlanRepository struct {
+	db *gorm.DB
+}
+
+// NewStockPlanRepository creates a new StockPlanRepository instance
+func NewStockPlanRepository(db *gorm.DB) StockPlanRepository {
+	return &stockPlanRepository{db: db}
+}
+
+// CreateGrant creates a new stock plan grant in the database
+func (r *stockPlanRepository) CreateGrant(ctx context.Context, grant *models.StockPlanGrant) error {
+	if grant == nil {
+		return fmt.Errorf("stock plan grant cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(grant).Error; err != nil {
+		return fmt.Errorf("failed to create stock plan grant: %w", err)
+	}
+
+	return nil
+}
+
+// FindGrantByID finds a stock plan grant by ID
+func (r *stockPlanRepository) FindGrantByID(ctx context.Context, id string) (*models.StockPlanGrant, error) {
+	if id == "" {
+		return nil, fmt.Errorf("id cannot be empty")
+	}
+
+	grantID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stock plan grant ID format: %w", err)
+	}
+
+	var grant models.StockPlanGrant
+	if err := r.db.WithContext(ctx).Where("id = ?", grantID).First(&grant).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, models.ErrStockPlanGrantNotFound
+		}
+		return nil, fmt.Errorf("failed to find stock plan grant: %w", err)
+	}
+
+	return &grant, nil
+}
+
+// FindGrantsByPortfolioID finds all stock plan grants for a portfolio, ordered by grant date
+func (r *stockPlanRepository) FindGrantsByPortfolioID(ctx context.Context, portfolioID string) ([]*models.StockPlanGrant, error) {
+	if portfolioID == "" {
+		return nil, fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	var grants []*models.StockPlanGrant
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ?", pid).
+		Order("grant_date ASC").
+		Find(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to find stock plan grants: %w", err)
+	}
+
+	return grants, nil
+}
+
+// UpdateGrant updates an existing stock plan grant
+func (r *stockPlanRepository) UpdateGrant(ctx context.Context, grant *models.StockPlanGrant) error {
+	if grant == nil {
+		return fmt.Errorf("stock plan grant cannot be nil")
+	}
+
+	grant.UpdatedAt = time.Now().UTC()
+
+	if err := r.db.WithContext(ctx).Save(grant).Error; err != nil {
+		return fmt.Errorf("failed to update stock plan grant: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteGrant deletes a stock plan grant by ID
+func (r *stockPlanRepository) DeleteGrant(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("id cannot be empty")
+	}
+
+	grantID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid stock plan grant ID format: %w", err)
+	}
+
+	result := r.db.WithContext(ctx).Where("id = ?", grantID).Delete(&models.StockPlanGrant{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete stock plan grant: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ErrStockPlanGrantNotFound
+	}
+
+	return nil
+}
+
+// CreateEvent records a vest, ESPP purchase, or exercise event
+func (r *stockPlanRepository) CreateEvent(ctx context.Context, event *models.StockPlanEvent) error {
+	if event == nil {
+		return fmt.Errorf("stock plan event cannot be nil")
+	}
+
+	if err := r.db.WithContext(ctx).Create(event).Error; err != nil {
+		return fmt.Errorf("failed to create stock plan event: %w", err)
+	}
+
+	return nil
+}
+
+// FindEventsByGrantID finds all events for a grant, ordered by date
+func (r *stockPlanRepository) FindEventsByGrantID(ctx context.Context, grantID string) ([]*models.StockPlanEvent, error) {
+	if grantID == "" {
+		return nil, fmt.Errorf("grant ID cannot be empty")
+	}
+
+	gid, err := uuid.Parse(grantID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stock plan grant ID format: %w", err)
+	}
+
+	var events []*models.StockPlanEvent
+	if err := r.db.WithContext(ctx).Where("grant_id = ?", gid).
+		Order("date ASC").
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to find stock plan events: %w", err)
+	}
+
+	return events, nil
+}
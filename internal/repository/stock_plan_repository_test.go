@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupStockPlanTestDB(t *testing.T) (*gorm.DB, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{},
+		&models.StockPlanGrant{}, &models.StockPlanEvent{})
+	require.NoError(t, err)
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Test Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	return db, portfolio
+}
+
+func newTestGrant(portfolioID uuid.UUID, grantDate time.Time) *models.StockPlanGrant {
+	return &models.StockPlanGrant{
+		PortfolioID:   portfolioID,
+		Symbol:        "ACME",
+		Type:          models.StockPlanTypeRSU,
+		GrantDate:     grantDate,
+		TotalShares:   decimal.NewFromInt(480),
+		VestingMonths: 48,
+		CliffMonths:   12,
+	}
+}
+
+func TestStockPlanRepository_CreateAndFindGrant(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupStockPlanTestDB(t)
+	repo := NewStockPlanRepository(db)
+
+	grantDate := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	grant := newTestGrant(portfolio.ID, grantDate)
+
+	err := repo.CreateGrant(ctx, grant)
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, grant.ID)
+	assert.Equal(t, grantDate, grant.VestingStartDate)
+	assert.Equal(t, 1, grant.VestingIntervalMonths)
+
+	found, err := repo.FindGrantByID(ctx, grant.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", found.Symbol)
+	assert.Equal(t, models.StockPlanTypeRSU, found.Type)
+	assert.True(t, decimal.NewFromInt(480).Equal(found.TotalShares))
+}
+
+func TestStockPlanRepository_FindGrantByID_NotFound(t *testing.T) {
+	ctx := context.Background()
+
+	db, _ := setupStockPlanTestDB(t)
+	repo := NewStockPlanRepository(db)
+
+	_, err := repo.FindGrantByID(ctx, uuid.New().String())
+	assert.Equal(t, models.ErrStockPlanGrantNotFound, err)
+
+	_, err = repo.FindGrantByID(ctx, "invalid")
+	assert.Error(t, err)
+}
+
+func TestStockPlanRepository_FindGrantsByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupStockPlanTestDB(t)
+	repo := NewStockPlanRepository(db)
+
+	later := newTestGrant(portfolio.ID, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	earlier := newTestGrant(portfolio.ID, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, repo.CreateGrant(ctx, later))
+	require.NoError(t, repo.CreateGrant(ctx, earlier))
+
+	grants, err := repo.FindGrantsByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	require.Len(t, grants, 2)
+	assert.Equal(t, earlier.ID, grants[0].ID)
+	assert.Equal(t, later.ID, grants[1].ID)
+}
+
+func TestStockPlanRepository_UpdateAndDeleteGrant(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupStockPlanTestDB(t)
+	repo := NewStockPlanRepository(db)
+
+	grant := newTestGrant(portfolio.ID, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, repo.CreateGrant(ctx, grant))
+
+	grant.Notes = "Refresh grant"
+	require.NoError(t, repo.UpdateGrant(ctx, grant))
+
+	found, err := repo.FindGrantByID(ctx, grant.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "Refresh grant", found.Notes)
+
+	require.NoError(t, repo.DeleteGrant(ctx, grant.ID.String()))
+	assert.Equal(t, models.ErrStockPlanGrantNotFound, repo.DeleteGrant(ctx, grant.ID.String()))
+}
+
+func TestStockPlanRepository_Events(t *testing.T) {
+	ctx := context.Background()
+
+	db, portfolio := setupStockPlanTestDB(t)
+	repo := NewStockPlanRepository(db)
+
+	grant := newTestGrant(portfolio.ID, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, repo.CreateGrant(ctx, grant))
+
+	for _, month := range []int{13, 12} {
+		event := &models.StockPlanEvent{
+			GrantID:         grant.ID,
+			PortfolioID:     portfolio.ID,
+			TransactionID:   uuid.New(),
+			Type:            models.StockPlanEventVest,
+			Date:            grant.GrantDate.AddDate(0, month, 0),
+			Shares:          decimal.NewFromInt(10),
+			FairMarketValue: decimal.NewFromInt(50),
+			CostBasis:       decimal.NewFromInt(500),
+			OrdinaryIncome:  decimal.NewFromInt(500),
+		}
+		require.NoError(t, repo.CreateEvent(ctx, event))
+	}
+
+	events, err := repo.FindEventsByGrantID(ctx, grant.ID.String())
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.True(t, events[0].Date.Before(events[1].Date))
+
+	assert.Error(t, repo.CreateEvent(ctx, nil))
+}
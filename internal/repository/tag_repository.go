@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// tagLookupBatchSize caps the IDs looked up in one query, below the bind parameter limits of
+// PostgreSQL and SQLite
+const tagLookupBatchSize = 1000
+
+// TagRepository defines the interface for tag data operations. Tags are found by item so that
+// lookups only touch one table at a time, which keeps them working with tenant schemas.
+type TagRepository interface {
+	FindUsageByUserID(ctx context.Context, userID uuid.UUID) ([]*models.TagUsage, error)
+	FindByPortfolioIDs(ctx context.Context, portfolioIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+	FindByTransactionIDs(ctx context.Context, transactionIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+	SetPortfolioTags(ctx context.Context, userID, portfolioID uuid.UUID, names []string) error
+	SetTransactionTags(ctx context.Context, userID, transactionID uuid.UUID, names []string) error
+}
+
+// tagRepository implements TagRepository interface
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository creates a new TagRepository instance
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// tagCount is a row of a count of links grouped by tag
+type tagCount struct {
+	TagID uuid.UUID
+	Count int
+}
+
+// FindUsageByUserID finds the tags a user has on at least one portfolio or transaction, with
+// how many of each carry them, sorted by name
+func (r *tagRepository) FindUsageByUserID(ctx context.Context, userID uuid.UUID) ([]*models.TagUsage, error) {
+	var tags []*models.Tag
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to find tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return []*models.TagUsage{}, nil
+	}
+
+	tagIDs := make([]uuid.UUID, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+
+	portfolioCounts, err := r.countLinks(ctx, &models.PortfolioTag{}, tagIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tagged portfolios: %w", err)
+	}
+	transactionCounts, err := r.countLinks(ctx, &models.TransactionTag{}, tagIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tagged transactions: %w", err)
+	}
+
+	usages := make([]*models.TagUsage, 0, len(tags))
+	for _, tag := range tags {
+		usage := &models.TagUsage{
+			Name:         tag.Name,
+			Portfolios:   portfolioCounts[tag.ID],
+			Transactions: transactionCounts[tag.ID],
+		}
+		if usage.Portfolios > 0 || usage.Transactions > 0 {
+			usages = append(usages, usage)
+		}
+	}
+
+	return usages, nil
+}
+
+// countLinks counts the rows of a link table for each tag
+func (r *tagRepository) countLinks(ctx context.Context, model any, tagIDs []uuid.UUID) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(tagIDs))
+	for start := 0; start < len(tagIDs); start += tagLookupBatchSize {
+		batch := tagIDs[start:min(start+tagLookupBatchSize, len(tagIDs))]
+
+		var rows []tagCount
+		err := r.db.WithContext(ctx).Model(model).
+			Select("tag_id, COUNT(*) AS count").
+			Where("tag_id IN ?", batch).
+			Group("tag_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			counts[row.TagID] = row.Count
+		}
+	}
+	return counts, nil
+}
+
+// FindByPortfolioIDs finds the tag names of each portfolio, sorted. Portfolios without tags
+// are left out of the map.
+func (r *tagRepository) FindByPortfolioIDs(ctx context.Context, portfolioIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	var links []*models.PortfolioTag
+	for start := 0; start < len(portfolioIDs); start += tagLookupBatchSize {
+		batch := portfolioIDs[start:min(start+tagLookupBatchSize, len(portfolioIDs))]
+
+		var rows []*models.PortfolioTag
+		if err := r.db.WithContext(ctx).Where("portfolio_id IN ?", batch).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to find portfolio tags: %w", err)
+		}
+		links = append(links, rows...)
+	}
+
+	tagIDs := make([]uuid.UUID, len(links))
+	for i, link := range links {
+		tagIDs[i] = link.TagID
+	}
+	names, err := r.findNames(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[uuid.UUID][]string)
+	for _, link := range links {
+		tags[link.PortfolioID] = append(tags[link.PortfolioID], names[link.TagID])
+	}
+	for _, itemTags := range tags {
+		sort.Strings(itemTags)
+	}
+
+	return tags, nil
+}
+
+// FindByTransactionIDs finds the tag names of each transaction, sorted. Transactions without
+// tags are left out of the map.
+func (r *tagRepository) FindByTransactionIDs(ctx context.Context, transactionIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	var links []*models.TransactionTag
+	for start := 0; start < len(transactionIDs); start += tagLookupBatchSize {
+		batch := transactionIDs[start:min(start+tagLookupBatchSize, len(transactionIDs))]
+
+		var rows []*models.TransactionTag
+		if err := r.db.WithContext(ctx).Where("transaction_id IN ?", batch).Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to find transaction tags: %w", err)
+		}
+		links = append(links, rows...)
+	}
+
+	tagIDs := make([]uuid.UUID, len(links))
+	for i, link := range links {
+		tagIDs[i] = link.TagID
+	}
+	names, err := r.findNames(ctx, tagIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[uuid.UUID][]string)
+	for _, link := range links {
+		tags[link.TransactionID] = append(tags[link.TransactionID], names[link.TagID])
+	}
+	for _, itemTags := range tags {
+		sort.Strings(itemTags)
+	}
+
+	return tags, nil
+}
+
+// findNames finds the names of tags by ID
+func (r *tagRepository) findNames(ctx context.Context, tagIDs []uuid.UUID) (map[uuid.UUID]string, error) {
+	unique := make([]uuid.UUID, 0, len(tagIDs))
+	seen := make(map[uuid.UUID]bool, len(tagIDs))
+	for _, id := range tagIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	names := make(map[uuid.UUID]string, len(unique))
+	for start := 0; start < len(unique); start += tagLookupBatchSize {
+		batch := unique[start:min(start+tagLookupBatchSize, len(unique))]
+
+		var tags []*models.Tag
+		if err := r.db.WithContext(ctx).Where("id IN ?", batch).Find(&tags).Error; err != nil {
+			return nil, fmt.Errorf("failed to find tags: %w", err)
+		}
+		for _, tag := range tags {
+			names[tag.ID] = tag.Name
+		}
+	}
+	return names, nil
+}
+
+// SetPortfolioTags replaces the tags of a portfolio with names, which must be normalized.
+// Tags the user doesn't have yet are created.
+func (r *tagRepository) SetPortfolioTags(ctx context.Context, userID, portfolioID uuid.UUID, names []string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagIDs, err := findOrCreateTags(tx, userID, names)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("portfolio_id = ?", portfolioID).Delete(&models.PortfolioTag{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		links := make([]*models.PortfolioTag, len(tagIDs))
+		for i, tagID := range tagIDs {
+			links[i] = &models.PortfolioTag{PortfolioID: portfolioID, TagID: tagID}
+		}
+		return tx.Create(links).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set portfolio tags: %w", err)
+	}
+
+	return nil
+}
+
+// SetTransactionTags replaces the tags of a transaction with names, which must be normalized.
+// Tags the user doesn't have yet are created.
+func (r *tagRepository) SetTransactionTags(ctx context.Context, userID, transactionID uuid.UUID, names []string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		tagIDs, err := findOrCreateTags(tx, userID, names)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("transaction_id = ?", transactionID).Delete(&models.TransactionTag{}).Error; err != nil {
+			return err
+		}
+		if len(tagIDs) == 0 {
+			return nil
+		}
+
+		links := make([]*models.TransactionTag, len(tagIDs))
+		for i, tagID := range tagIDs {
+			links[i] = &models.TransactionTag{TransactionID: transactionID, TagID: tagID}
+		}
+		return tx.Create(links).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set transaction tags: %w", err)
+	}
+
+	return nil
+}
+
+// findOrCreateTags returns the IDs of a user's tags with the given names, creating the ones
+// that don't exist. A tag created concurrently by another request is reused.
+func findOrCreateTags(tx *gorm.DB, userID uuid.UUID, names []string) ([]uuid.UUID, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	missing := make([]*models.Tag, 0, len(names))
+	existing, err := findTagsByName(tx, userID, names)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if _, ok := existing[name]; !ok {
+			missing = append(missing, &models.Tag{UserID: userID, Name: name})
+		}
+	}
+
+	if len(missing) > 0 {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(missing).Error; err != nil {
+			return nil, err
+		}
+		if existing, err = findTagsByName(tx, userID, names); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(names))
+	for _, name := range names {
+		id, ok := existing[name]
+		if !ok {
+			return nil, fmt.Errorf("tag %q was not created", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// findTagsByName returns the IDs of a user's tags with the given names, by name
+func findTagsByName(tx *gorm.DB, userID uuid.UUID, names []string) (map[string]uuid.UUID, error) {
+	var tags []*models.Tag
+	if err := tx.Where("user_id = ? AND name IN ?", userID, names).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]uuid.UUID, len(tags))
+	for _, tag := range tags {
+		ids[tag.Name] = tag.ID
+	}
+	return ids, nil
+}
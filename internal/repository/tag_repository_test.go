@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupTagTestDB(t *testing.T) (*gorm.DB, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{},
+		&models.Tag{}, &models.PortfolioTag{}, &models.TransactionTag{})
+	require.NoError(t, err)
+
+	user := &models.User{
+		Email:        "test@example.com",
+		PasswordHash: "hashedpassword",
+	}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Retirement",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	return db, user, portfolio
+}
+
+func TestTagRepository_SetAndFind(t *testing.T) {
+	db, user, portfolio := setupTagTestDB(t)
+	repo := NewTagRepository(db)
+	ctx := context.Background()
+
+	other := &models.Portfolio{UserID: user.ID, Name: "Brokerage", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(other).Error)
+	price := decimal.NewFromInt(150)
+	transaction := &models.Transaction{
+		PortfolioID: portfolio.ID,
+		Type:        models.TransactionTypeBuy,
+		Symbol:      "AAPL",
+		Quantity:    decimal.NewFromInt(10),
+		Price:       &price,
+		Currency:    "USD",
+		Date:        time.Now(),
+	}
+	require.NoError(t, db.Create(transaction).Error)
+
+	require.NoError(t, repo.SetPortfolioTags(ctx, user.ID, portfolio.ID, []string{"long-term", "retirement"}))
+	require.NoError(t, repo.SetPortfolioTags(ctx, user.ID, other.ID, []string{"retirement"}))
+	require.NoError(t, repo.SetTransactionTags(ctx, user.ID, transaction.ID, []string{"long-term"}))
+
+	// Tags are shared across the user's items rather than duplicated
+	var count int64
+	require.NoError(t, db.Model(&models.Tag{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	tags, err := repo.FindByPortfolioIDs(ctx, []uuid.UUID{portfolio.ID, other.ID, uuid.New()})
+	require.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID][]string{
+		portfolio.ID: {"long-term", "retirement"},
+		other.ID:     {"retirement"},
+	}, tags)
+
+	tags, err = repo.FindByTransactionIDs(ctx, []uuid.UUID{transaction.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"long-term"}, tags[transaction.ID])
+
+	usages, err := repo.FindUsageByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.TagUsage{
+		{Name: "long-term", Portfolios: 1, Transactions: 1},
+		{Name: "retirement", Portfolios: 2},
+	}, usages)
+
+	// Replacing the tags drops the old links, and unused tags are left out of the usage
+	require.NoError(t, repo.SetPortfolioTags(ctx, user.ID, portfolio.ID, []string{"speculative"}))
+	require.NoError(t, repo.SetTransactionTags(ctx, user.ID, transaction.ID, nil))
+
+	tags, err = repo.FindByPortfolioIDs(ctx, []uuid.UUID{portfolio.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"speculative"}, tags[portfolio.ID])
+
+	usages, err = repo.FindUsageByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.TagUsage{
+		{Name: "retirement", Portfolios: 1},
+		{Name: "speculative", Portfolios: 1},
+	}, usages)
+}
+
+func TestTagRepository_FindUsageByUserID_OtherUser(t *testing.T) {
+	db, user, portfolio := setupTagTestDB(t)
+	repo := NewTagRepository(db)
+	ctx := context.Background()
+
+	require.NoError(t, repo.SetPortfolioTags(ctx, user.ID, portfolio.ID, []string{"retirement"}))
+
+	usages, err := repo.FindUsageByUserID(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, usages)
+}
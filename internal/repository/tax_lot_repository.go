@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -12,14 +13,15 @@ import (
 
 // TaxLotRepository defines the interface for tax lot data operations
 type TaxLotRepository interface {
-	Create(taxLot *models.TaxLot) error
-	FindByID(id string) (*models.TaxLot, error)
-	FindByPortfolioID(portfolioID string) ([]*models.TaxLot, error)
-	FindByPortfolioIDAndSymbol(portfolioID, symbol string) ([]*models.TaxLot, error)
-	FindByTransactionID(transactionID string) ([]*models.TaxLot, error)
-	Update(taxLot *models.TaxLot) error
-	Delete(id string) error
-	DeleteByPortfolioIDAndSymbol(portfolioID, symbol string) error
+	Create(ctx context.Context, taxLot *models.TaxLot) error
+	FindByID(ctx context.Context, id string) (*models.TaxLot, error)
+	FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.TaxLot, error)
+	FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) ([]*models.TaxLot, error)
+	FindByPortfolioIDAndSymbols(ctx context.Context, portfolioID string, symbols []string) ([]*models.TaxLot, error)
+	FindByTransactionID(ctx context.Context, transactionID string) ([]*models.TaxLot, error)
+	Update(ctx context.Context, taxLot *models.TaxLot) error
+	Delete(ctx context.Context, id string) error
+	DeleteByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) error
 }
 
 // taxLotRepository implements TaxLotRepository interface
@@ -33,12 +35,12 @@ func NewTaxLotRepository(db *gorm.DB) TaxLotRepository {
 }
 
 // Create creates a new tax lot in the database
-func (r *taxLotRepository) Create(taxLot *models.TaxLot) error {
+func (r *taxLotRepository) Create(ctx context.Context, taxLot *models.TaxLot) error {
 	if taxLot == nil {
 		return fmt.Errorf("tax lot cannot be nil")
 	}
 
-	if err := r.db.Create(taxLot).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(taxLot).Error; err != nil {
 		return fmt.Errorf("failed to create tax lot: %w", err)
 	}
 
@@ -46,7 +48,7 @@ func (r *taxLotRepository) Create(taxLot *models.TaxLot) error {
 }
 
 // FindByID finds a tax lot by ID
-func (r *taxLotRepository) FindByID(id string) (*models.TaxLot, error) {
+func (r *taxLotRepository) FindByID(ctx context.Context, id string) (*models.TaxLot, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id cannot be empty")
 	}
@@ -57,7 +59,7 @@ func (r *taxLotRepository) FindByID(id string) (*models.TaxLot, error) {
 	}
 
 	var taxLot models.TaxLot
-	if err := r.db.Where("id = ?", taxLotID).First(&taxLot).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("id = ?", taxLotID).First(&taxLot).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrTaxLotNotFound
 		}
@@ -68,7 +70,7 @@ func (r *taxLotRepository) FindByID(id string) (*models.TaxLot, error) {
 }
 
 // FindByPortfolioID finds all tax lots for a portfolio, ordered by purchase date
-func (r *taxLotRepository) FindByPortfolioID(portfolioID string) ([]*models.TaxLot, error) {
+func (r *taxLotRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.TaxLot, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -79,7 +81,7 @@ func (r *taxLotRepository) FindByPortfolioID(portfolioID string) ([]*models.TaxL
 	}
 
 	var taxLots []*models.TaxLot
-	if err := r.db.Where("portfolio_id = ?", pid).
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ?", pid).
 		Order("purchase_date ASC, created_at ASC").
 		Find(&taxLots).Error; err != nil {
 		return nil, fmt.Errorf("failed to find tax lots: %w", err)
@@ -89,7 +91,7 @@ func (r *taxLotRepository) FindByPortfolioID(portfolioID string) ([]*models.TaxL
 }
 
 // FindByPortfolioIDAndSymbol finds all tax lots for a portfolio and symbol, ordered by purchase date (FIFO)
-func (r *taxLotRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol string) ([]*models.TaxLot, error) {
+func (r *taxLotRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) ([]*models.TaxLot, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -103,7 +105,7 @@ func (r *taxLotRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol string
 	}
 
 	var taxLots []*models.TaxLot
-	if err := r.db.Where("portfolio_id = ? AND symbol = ?", pid, symbol).
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ? AND symbol = ?", pid, symbol).
 		Order("purchase_date ASC, created_at ASC").
 		Find(&taxLots).Error; err != nil {
 		return nil, fmt.Errorf("failed to find tax lots: %w", err)
@@ -112,8 +114,33 @@ func (r *taxLotRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol string
 	return taxLots, nil
 }
 
+// FindByPortfolioIDAndSymbols finds the tax lots of several of a portfolio's symbols in one
+// query, ordered by symbol and then by purchase date (FIFO)
+func (r *taxLotRepository) FindByPortfolioIDAndSymbols(ctx context.Context, portfolioID string, symbols []string) ([]*models.TaxLot, error) {
+	if portfolioID == "" {
+		return nil, fmt.Errorf("portfolio ID cannot be empty")
+	}
+
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+	if len(symbols) == 0 {
+		return []*models.TaxLot{}, nil
+	}
+
+	var taxLots []*models.TaxLot
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ? AND symbol IN ?", pid, symbols).
+		Order("symbol ASC, purchase_date ASC, created_at ASC").
+		Find(&taxLots).Error; err != nil {
+		return nil, fmt.Errorf("failed to find tax lots: %w", err)
+	}
+
+	return taxLots, nil
+}
+
 // FindByTransactionID finds all tax lots associated with a transaction
-func (r *taxLotRepository) FindByTransactionID(transactionID string) ([]*models.TaxLot, error) {
+func (r *taxLotRepository) FindByTransactionID(ctx context.Context, transactionID string) ([]*models.TaxLot, error) {
 	if transactionID == "" {
 		return nil, fmt.Errorf("transaction ID cannot be empty")
 	}
@@ -124,7 +151,7 @@ func (r *taxLotRepository) FindByTransactionID(transactionID string) ([]*models.
 	}
 
 	var taxLots []*models.TaxLot
-	if err := r.db.Where("transaction_id = ?", tid).
+	if err := r.db.WithContext(ctx).Where("transaction_id = ?", tid).
 		Find(&taxLots).Error; err != nil {
 		return nil, fmt.Errorf("failed to find tax lots: %w", err)
 	}
@@ -133,14 +160,14 @@ func (r *taxLotRepository) FindByTransactionID(transactionID string) ([]*models.
 }
 
 // Update updates an existing tax lot
-func (r *taxLotRepository) Update(taxLot *models.TaxLot) error {
+func (r *taxLotRepository) Update(ctx context.Context, taxLot *models.TaxLot) error {
 	if taxLot == nil {
 		return fmt.Errorf("tax lot cannot be nil")
 	}
 
 	taxLot.UpdatedAt = time.Now().UTC()
 
-	if err := r.db.Save(taxLot).Error; err != nil {
+	if err := r.db.WithContext(ctx).Save(taxLot).Error; err != nil {
 		return fmt.Errorf("failed to update tax lot: %w", err)
 	}
 
@@ -148,7 +175,7 @@ func (r *taxLotRepository) Update(taxLot *models.TaxLot) error {
 }
 
 // Delete deletes a tax lot by ID
-func (r *taxLotRepository) Delete(id string) error {
+func (r *taxLotRepository) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("id cannot be empty")
 	}
@@ -158,7 +185,7 @@ func (r *taxLotRepository) Delete(id string) error {
 		return fmt.Errorf("invalid tax lot ID format: %w", err)
 	}
 
-	result := r.db.Where("id = ?", taxLotID).Delete(&models.TaxLot{})
+	result := r.db.WithContext(ctx).Where("id = ?", taxLotID).Delete(&models.TaxLot{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete tax lot: %w", result.Error)
 	}
@@ -171,7 +198,7 @@ func (r *taxLotRepository) Delete(id string) error {
 }
 
 // DeleteByPortfolioIDAndSymbol deletes all tax lots for a portfolio and symbol
-func (r *taxLotRepository) DeleteByPortfolioIDAndSymbol(portfolioID, symbol string) error {
+func (r *taxLotRepository) DeleteByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) error {
 	if portfolioID == "" {
 		return fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -184,7 +211,7 @@ func (r *taxLotRepository) DeleteByPortfolioIDAndSymbol(portfolioID, symbol stri
 		return fmt.Errorf("invalid portfolio ID format: %w", err)
 	}
 
-	if err := r.db.Where("portfolio_id = ? AND symbol = ?", pid, symbol).
+	if err := r.db.WithContext(ctx).Where("portfolio_id = ? AND symbol = ?", pid, symbol).
 		Delete(&models.TaxLot{}).Error; err != nil {
 		return fmt.Errorf("failed to delete tax lots: %w", err)
 	}
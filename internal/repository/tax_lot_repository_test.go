@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -64,6 +65,8 @@ func createTestTransactionForTaxLot(t *testing.T, db *gorm.DB, portfolioID uuid.
 }
 
 func TestTaxLotRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
@@ -80,21 +83,25 @@ func TestTaxLotRepository_Create(t *testing.T) {
 		TransactionID: transaction.ID,
 	}
 
-	err := repo.Create(taxLot)
+	err := repo.Create(ctx, taxLot)
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, taxLot.ID)
 }
 
 func TestTaxLotRepository_Create_NilTaxLot(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
-	err := repo.Create(nil)
+	err := repo.Create(ctx, nil)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot be nil")
 }
 
 func TestTaxLotRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
@@ -110,10 +117,10 @@ func TestTaxLotRepository_FindByID(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(1005.00),
 		TransactionID: transaction.ID,
 	}
-	err := repo.Create(taxLot)
+	err := repo.Create(ctx, taxLot)
 	require.NoError(t, err)
 
-	found, err := repo.FindByID(taxLot.ID.String())
+	found, err := repo.FindByID(ctx, taxLot.ID.String())
 	assert.NoError(t, err)
 	assert.Equal(t, taxLot.ID, found.ID)
 	assert.Equal(t, taxLot.Symbol, found.Symbol)
@@ -121,24 +128,30 @@ func TestTaxLotRepository_FindByID(t *testing.T) {
 }
 
 func TestTaxLotRepository_FindByID_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
-	_, err := repo.FindByID(uuid.New().String())
+	_, err := repo.FindByID(ctx, uuid.New().String())
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrTaxLotNotFound, err)
 }
 
 func TestTaxLotRepository_FindByID_InvalidID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
-	_, err := repo.FindByID("invalid-uuid")
+	_, err := repo.FindByID(ctx, "invalid-uuid")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid")
 }
 
 func TestTaxLotRepository_FindByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
@@ -155,7 +168,7 @@ func TestTaxLotRepository_FindByPortfolioID(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(1005.00),
 		TransactionID: transaction.ID,
 	}
-	err := repo.Create(taxLot1)
+	err := repo.Create(ctx, taxLot1)
 	require.NoError(t, err)
 
 	taxLot2 := &models.TaxLot{
@@ -166,10 +179,10 @@ func TestTaxLotRepository_FindByPortfolioID(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(500.00),
 		TransactionID: transaction.ID,
 	}
-	err = repo.Create(taxLot2)
+	err = repo.Create(ctx, taxLot2)
 	require.NoError(t, err)
 
-	taxLots, err := repo.FindByPortfolioID(portfolio.ID.String())
+	taxLots, err := repo.FindByPortfolioID(ctx, portfolio.ID.String())
 	assert.NoError(t, err)
 	assert.Len(t, taxLots, 2)
 	// Verify they are ordered by purchase date
@@ -178,6 +191,8 @@ func TestTaxLotRepository_FindByPortfolioID(t *testing.T) {
 }
 
 func TestTaxLotRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
@@ -194,7 +209,7 @@ func TestTaxLotRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(1005.00),
 		TransactionID: transaction.ID,
 	}
-	err := repo.Create(taxLot1)
+	err := repo.Create(ctx, taxLot1)
 	require.NoError(t, err)
 
 	taxLot2 := &models.TaxLot{
@@ -205,7 +220,7 @@ func TestTaxLotRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(502.50),
 		TransactionID: transaction.ID,
 	}
-	err = repo.Create(taxLot2)
+	err = repo.Create(ctx, taxLot2)
 	require.NoError(t, err)
 
 	taxLot3 := &models.TaxLot{
@@ -216,17 +231,57 @@ func TestTaxLotRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(300.00),
 		TransactionID: transaction.ID,
 	}
-	err = repo.Create(taxLot3)
+	err = repo.Create(ctx, taxLot3)
 	require.NoError(t, err)
 
-	taxLots, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+	taxLots, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 	assert.NoError(t, err)
 	assert.Len(t, taxLots, 2)
 	// Verify they are FIFO ordered (oldest first)
 	assert.True(t, taxLots[0].PurchaseDate.Before(taxLots[1].PurchaseDate))
 }
 
+func TestTaxLotRepository_FindByPortfolioIDAndSymbols(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTaxLotTestDB(t)
+	repo := NewTaxLotRepository(db)
+
+	user := createTestUserForTaxLot(t, db)
+	portfolio := createTestPortfolioForTaxLot(t, db, user.ID)
+	transaction := createTestTransactionForTaxLot(t, db, portfolio.ID)
+
+	for i, symbol := range []string{"MSFT", "AAPL", "TSLA", "AAPL"} {
+		require.NoError(t, repo.Create(ctx, &models.TaxLot{
+			PortfolioID:   portfolio.ID,
+			Symbol:        symbol,
+			PurchaseDate:  time.Now().UTC().AddDate(0, 0, -10+i),
+			Quantity:      decimal.NewFromInt(int64(i + 1)),
+			CostBasis:     decimal.NewFromInt(100),
+			TransactionID: transaction.ID,
+		}))
+	}
+
+	taxLots, err := repo.FindByPortfolioIDAndSymbols(ctx, portfolio.ID.String(), []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	require.Len(t, taxLots, 3)
+	// Ordered by symbol, then FIFO
+	assert.Equal(t, "AAPL", taxLots[0].Symbol)
+	assert.Equal(t, "AAPL", taxLots[1].Symbol)
+	assert.True(t, taxLots[0].PurchaseDate.Before(taxLots[1].PurchaseDate))
+	assert.Equal(t, "MSFT", taxLots[2].Symbol)
+
+	taxLots, err = repo.FindByPortfolioIDAndSymbols(ctx, portfolio.ID.String(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, taxLots)
+
+	_, err = repo.FindByPortfolioIDAndSymbols(ctx, "not-a-uuid", []string{"AAPL"})
+	assert.Error(t, err)
+}
+
 func TestTaxLotRepository_FindByTransactionID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
@@ -242,16 +297,18 @@ func TestTaxLotRepository_FindByTransactionID(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(1005.00),
 		TransactionID: transaction.ID,
 	}
-	err := repo.Create(taxLot)
+	err := repo.Create(ctx, taxLot)
 	require.NoError(t, err)
 
-	taxLots, err := repo.FindByTransactionID(transaction.ID.String())
+	taxLots, err := repo.FindByTransactionID(ctx, transaction.ID.String())
 	assert.NoError(t, err)
 	assert.Len(t, taxLots, 1)
 	assert.Equal(t, taxLot.ID, taxLots[0].ID)
 }
 
 func TestTaxLotRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
@@ -267,24 +324,26 @@ func TestTaxLotRepository_Update(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(1005.00),
 		TransactionID: transaction.ID,
 	}
-	err := repo.Create(taxLot)
+	err := repo.Create(ctx, taxLot)
 	require.NoError(t, err)
 
 	// Update quantity
 	taxLot.Quantity = decimal.NewFromInt(5)
 	taxLot.CostBasis = decimal.NewFromFloat(502.50)
 
-	err = repo.Update(taxLot)
+	err = repo.Update(ctx, taxLot)
 	assert.NoError(t, err)
 
 	// Verify update
-	found, err := repo.FindByID(taxLot.ID.String())
+	found, err := repo.FindByID(ctx, taxLot.ID.String())
 	assert.NoError(t, err)
 	assert.Equal(t, "5", found.Quantity.String())
 	assert.Equal(t, "502.5", found.CostBasis.String())
 }
 
 func TestTaxLotRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
@@ -300,28 +359,32 @@ func TestTaxLotRepository_Delete(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(1005.00),
 		TransactionID: transaction.ID,
 	}
-	err := repo.Create(taxLot)
+	err := repo.Create(ctx, taxLot)
 	require.NoError(t, err)
 
-	err = repo.Delete(taxLot.ID.String())
+	err = repo.Delete(ctx, taxLot.ID.String())
 	assert.NoError(t, err)
 
 	// Verify deletion
-	_, err = repo.FindByID(taxLot.ID.String())
+	_, err = repo.FindByID(ctx, taxLot.ID.String())
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrTaxLotNotFound, err)
 }
 
 func TestTaxLotRepository_Delete_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
-	err := repo.Delete(uuid.New().String())
+	err := repo.Delete(ctx, uuid.New().String())
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrTaxLotNotFound, err)
 }
 
 func TestTaxLotRepository_DeleteByPortfolioIDAndSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTaxLotTestDB(t)
 	repo := NewTaxLotRepository(db)
 
@@ -339,7 +402,7 @@ func TestTaxLotRepository_DeleteByPortfolioIDAndSymbol(t *testing.T) {
 			CostBasis:     decimal.NewFromFloat(1005.00),
 			TransactionID: transaction.ID,
 		}
-		err := repo.Create(taxLot)
+		err := repo.Create(ctx, taxLot)
 		require.NoError(t, err)
 	}
 
@@ -352,20 +415,20 @@ func TestTaxLotRepository_DeleteByPortfolioIDAndSymbol(t *testing.T) {
 		CostBasis:     decimal.NewFromFloat(500.00),
 		TransactionID: transaction.ID,
 	}
-	err := repo.Create(taxLot)
+	err := repo.Create(ctx, taxLot)
 	require.NoError(t, err)
 
 	// Delete AAPL tax lots
-	err = repo.DeleteByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+	err = repo.DeleteByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 	assert.NoError(t, err)
 
 	// Verify AAPL lots are deleted
-	aaplLots, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+	aaplLots, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 	assert.NoError(t, err)
 	assert.Len(t, aaplLots, 0)
 
 	// Verify MSFT lot still exists
-	msftLots, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "MSFT")
+	msftLots, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "MSFT")
 	assert.NoError(t, err)
 	assert.Len(t, msftLots, 1)
 }
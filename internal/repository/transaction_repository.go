@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -12,33 +14,36 @@ import (
 
 // TransactionRepository defines the interface for transaction data operations
 type TransactionRepository interface {
-	Create(transaction *models.Transaction) error
-	FindByID(id string) (*models.Transaction, error)
-	FindByPortfolioID(portfolioID string) ([]*models.Transaction, error)
-	FindByPortfolioIDAndSymbol(portfolioID, symbol string) ([]*models.Transaction, error)
-	FindByPortfolioIDWithFilters(portfolioID string, symbol *string, startDate, endDate *time.Time) ([]*models.Transaction, error)
-	Update(transaction *models.Transaction) error
-	Delete(id string) error
-	DeleteByImportBatchID(batchID string) error
+	Create(ctx context.Context, transaction *models.Transaction) error
+	FindByID(ctx context.Context, id string) (*models.Transaction, error)
+	FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Transaction, error)
+	FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) ([]*models.Transaction, error)
+	FindByPortfolioIDWithFilters(ctx context.Context, portfolioID string, symbol *string, startDate, endDate *time.Time) ([]*models.Transaction, error)
+	FindPageByPortfolioID(ctx context.Context, portfolioID string, after *models.Transaction, limit int) ([]*models.Transaction, error)
+	IterateByPortfolioID(ctx context.Context, portfolioID string, fn func([]*models.Transaction) error) error
+	Update(ctx context.Context, transaction *models.Transaction) error
+	Delete(ctx context.Context, id string) error
+	DeleteByImportBatchID(ctx context.Context, batchID string) error
 }
 
 // transactionRepository implements TransactionRepository interface
 type transactionRepository struct {
-	db *gorm.DB
+	db        *gorm.DB
+	batchSize int
 }
 
 // NewTransactionRepository creates a new TransactionRepository instance
 func NewTransactionRepository(db *gorm.DB) TransactionRepository {
-	return &transactionRepository{db: db}
+	return &transactionRepository{db: db, batchSize: iterateBatchSize}
 }
 
 // Create creates a new transaction in the database
-func (r *transactionRepository) Create(transaction *models.Transaction) error {
+func (r *transactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
 	if transaction == nil {
 		return fmt.Errorf("transaction cannot be nil")
 	}
 
-	if err := r.db.Create(transaction).Error; err != nil {
+	if err := r.db.WithContext(ctx).Create(transaction).Error; err != nil {
 		return fmt.Errorf("failed to create transaction: %w", err)
 	}
 
@@ -46,7 +51,7 @@ func (r *transactionRepository) Create(transaction *models.Transaction) error {
 }
 
 // FindByID finds a transaction by ID
-func (r *transactionRepository) FindByID(id string) (*models.Transaction, error) {
+func (r *transactionRepository) FindByID(ctx context.Context, id string) (*models.Transaction, error) {
 	if id == "" {
 		return nil, fmt.Errorf("id cannot be empty")
 	}
@@ -58,7 +63,7 @@ func (r *transactionRepository) FindByID(id string) (*models.Transaction, error)
 	}
 
 	var transaction models.Transaction
-	err = r.db.Where("id = ?", transactionID).First(&transaction).Error
+	err = r.db.WithContext(ctx).Where("id = ?", transactionID).First(&transaction).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, models.ErrTransactionNotFound
@@ -70,7 +75,7 @@ func (r *transactionRepository) FindByID(id string) (*models.Transaction, error)
 }
 
 // FindByPortfolioID finds all transactions for a specific portfolio
-func (r *transactionRepository) FindByPortfolioID(portfolioID string) ([]*models.Transaction, error) {
+func (r *transactionRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Transaction, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -82,7 +87,7 @@ func (r *transactionRepository) FindByPortfolioID(portfolioID string) ([]*models
 	}
 
 	var transactions []*models.Transaction
-	err = r.db.Where("portfolio_id = ?", pid).Order("date DESC, created_at DESC").Find(&transactions).Error
+	err = r.db.WithContext(ctx).Where("portfolio_id = ?", pid).Order("date DESC, created_at DESC").Find(&transactions).Error
 	if err != nil {
 		return nil, fmt.Errorf("failed to find transactions: %w", err)
 	}
@@ -91,7 +96,7 @@ func (r *transactionRepository) FindByPortfolioID(portfolioID string) ([]*models
 }
 
 // FindByPortfolioIDAndSymbol finds all transactions for a specific portfolio and symbol
-func (r *transactionRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol string) ([]*models.Transaction, error) {
+func (r *transactionRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) ([]*models.Transaction, error) {
 	if portfolioID == "" {
 		return nil, fmt.Errorf("portfolio ID cannot be empty")
 	}
@@ -106,7 +111,7 @@ func (r *transactionRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol s
 	}
 
 	var transactions []*models.Transaction
-	err = r.db.Where("portfolio_id = ? AND symbol = ?", pid, symbol).
+	err = r.db.WithContext(ctx).Where("portfolio_id = ? AND symbol = ?", pid, symbol).
 		Order("date DESC, created_at DESC").
 		Find(&transactions).Error
 	if err != nil {
@@ -118,6 +123,7 @@ func (r *transactionRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol s
 
 // FindByPortfolioIDWithFilters finds transactions with optional filters
 func (r *transactionRepository) FindByPortfolioIDWithFilters(
+	ctx context.Context,
 	portfolioID string,
 	symbol *string,
 	startDate, endDate *time.Time,
@@ -132,7 +138,7 @@ func (r *transactionRepository) FindByPortfolioIDWithFilters(
 		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
 	}
 
-	query := r.db.Where("portfolio_id = ?", pid)
+	query := r.db.WithContext(ctx).Where("portfolio_id = ?", pid)
 
 	if symbol != nil && *symbol != "" {
 		query = query.Where("symbol = ?", *symbol)
@@ -155,26 +161,106 @@ func (r *transactionRepository) FindByPortfolioIDWithFilters(
 	return transactions, nil
 }
 
+// FindPageByPortfolioID finds up to limit of a portfolio's transactions in the order they are
+// replayed (by date, then by when they were recorded), starting after the given transaction or
+// from the first when after is nil. Seeking past the last row of the previous page keeps
+// reading deep pages as cheap as reading the first.
+func (r *transactionRepository) FindPageByPortfolioID(
+	ctx context.Context,
+	portfolioID string,
+	after *models.Transaction,
+	limit int,
+) ([]*models.Transaction, error) {
+	if portfolioID == "" {
+		return nil, fmt.Errorf("portfolio ID cannot be empty")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit must be positive")
+	}
+
+	// Validate UUID format
+	pid, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	query := r.db.WithContext(ctx).Where("portfolio_id = ?", pid)
+	if after != nil {
+		query = query.Where("date > ? OR (date = ? AND (created_at > ? OR (created_at = ? AND id > ?)))",
+			after.Date, after.Date, after.CreatedAt, after.CreatedAt, after.ID)
+	}
+
+	var transactions []*models.Transaction
+	err = query.Order("date ASC, created_at ASC, id ASC").Limit(limit).Find(&transactions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// IterateByPortfolioID passes a portfolio's transactions to fn in batches, in the order they
+// are replayed, so the whole ledger never has to be held in memory. It stops at the first
+// error fn returns, returning nil if that is ErrStopIteration.
+func (r *transactionRepository) IterateByPortfolioID(
+	ctx context.Context,
+	portfolioID string,
+	fn func([]*models.Transaction) error,
+) error {
+	var after *models.Transaction
+	for {
+		batch, err := r.FindPageByPortfolioID(ctx, portfolioID, after, r.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := fn(batch); err != nil {
+			if errors.Is(err, ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+		if len(batch) < r.batchSize {
+			return nil
+		}
+		after = batch[len(batch)-1]
+	}
+}
+
 // Update updates an existing transaction
-func (r *transactionRepository) Update(transaction *models.Transaction) error {
+func (r *transactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
 	if transaction == nil {
 		return fmt.Errorf("transaction cannot be nil")
 	}
 
-	result := r.db.Model(transaction).Where("id = ?", transaction.ID).Updates(transaction)
+	version := transaction.Version
+	transaction.Version = version + 1
+	result := r.db.WithContext(ctx).Model(transaction).Where("id = ? AND version = ?", transaction.ID, version).Updates(transaction)
 	if result.Error != nil {
+		transaction.Version = version
 		return fmt.Errorf("failed to update transaction: %w", result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		return models.ErrTransactionNotFound
+		transaction.Version = version
+		var current models.Transaction
+		err := r.db.WithContext(ctx).Select("version").Where("id = ?", transaction.ID).First(&current).Error
+		if err == gorm.ErrRecordNotFound {
+			return models.ErrTransactionNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check transaction version: %w", err)
+		}
+		return &models.VersionConflictError{CurrentVersion: current.Version}
 	}
 
 	return nil
 }
 
 // Delete deletes a transaction by ID
-func (r *transactionRepository) Delete(id string) error {
+func (r *transactionRepository) Delete(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("id cannot be empty")
 	}
@@ -185,7 +271,7 @@ func (r *transactionRepository) Delete(id string) error {
 		return fmt.Errorf("invalid transaction ID format: %w", err)
 	}
 
-	result := r.db.Where("id = ?", transactionID).Delete(&models.Transaction{})
+	result := r.db.WithContext(ctx).Where("id = ?", transactionID).Delete(&models.Transaction{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete transaction: %w", result.Error)
 	}
@@ -198,7 +284,7 @@ func (r *transactionRepository) Delete(id string) error {
 }
 
 // DeleteByImportBatchID deletes all transactions with a specific import batch ID
-func (r *transactionRepository) DeleteByImportBatchID(batchID string) error {
+func (r *transactionRepository) DeleteByImportBatchID(ctx context.Context, batchID string) error {
 	if batchID == "" {
 		return fmt.Errorf("batch ID cannot be empty")
 	}
@@ -209,7 +295,7 @@ func (r *transactionRepository) DeleteByImportBatchID(batchID string) error {
 		return fmt.Errorf("invalid batch ID format: %w", err)
 	}
 
-	result := r.db.Where("import_batch_id = ?", bid).Delete(&models.Transaction{})
+	result := r.db.WithContext(ctx).Where("import_batch_id = ?", bid).Delete(&models.Transaction{})
 	if result.Error != nil {
 		return fmt.Errorf("failed to delete transactions: %w", result.Error)
 	}
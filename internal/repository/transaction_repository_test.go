@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
@@ -42,6 +44,8 @@ func setupTransactionRepoTestDB(t *testing.T) (*gorm.DB, *models.User, *models.P
 }
 
 func TestTransactionRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupTransactionRepoTestDB(t)
 	repo := NewTransactionRepository(db)
 
@@ -59,20 +63,22 @@ func TestTransactionRepository_Create(t *testing.T) {
 			Notes:       "Test transaction",
 		}
 
-		err := repo.Create(transaction)
+		err := repo.Create(ctx, transaction)
 
 		assert.NoError(t, err)
 		assert.NotEqual(t, uuid.Nil, transaction.ID)
 	})
 
 	t.Run("nil transaction error", func(t *testing.T) {
-		err := repo.Create(nil)
+		err := repo.Create(ctx, nil)
 
 		assert.Error(t, err)
 	})
 }
 
 func TestTransactionRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupTransactionRepoTestDB(t)
 	repo := NewTransactionRepository(db)
 
@@ -87,11 +93,11 @@ func TestTransactionRepository_FindByID(t *testing.T) {
 		Commission:  decimal.Zero,
 		Currency:    "USD",
 	}
-	err := repo.Create(transaction)
+	err := repo.Create(ctx, transaction)
 	assert.NoError(t, err)
 
 	t.Run("successful find", func(t *testing.T) {
-		found, err := repo.FindByID(transaction.ID.String())
+		found, err := repo.FindByID(ctx, transaction.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, found)
@@ -100,25 +106,27 @@ func TestTransactionRepository_FindByID(t *testing.T) {
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		_, err := repo.FindByID(uuid.New().String())
+		_, err := repo.FindByID(ctx, uuid.New().String())
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrTransactionNotFound, err)
 	})
 
 	t.Run("empty id error", func(t *testing.T) {
-		_, err := repo.FindByID("")
+		_, err := repo.FindByID(ctx, "")
 
 		assert.Error(t, err)
 	})
 }
 
 func TestTransactionRepository_FindByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupTransactionRepoTestDB(t)
 	repo := NewTransactionRepository(db)
 
 	t.Run("empty list", func(t *testing.T) {
-		transactions, err := repo.FindByPortfolioID(portfolio.ID.String())
+		transactions, err := repo.FindByPortfolioID(ctx, portfolio.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, transactions)
@@ -150,25 +158,27 @@ func TestTransactionRepository_FindByPortfolioID(t *testing.T) {
 	}
 
 	for _, tx := range transactions {
-		err := repo.Create(tx)
+		err := repo.Create(ctx, tx)
 		assert.NoError(t, err)
 	}
 
 	t.Run("find multiple transactions", func(t *testing.T) {
-		found, err := repo.FindByPortfolioID(portfolio.ID.String())
+		found, err := repo.FindByPortfolioID(ctx, portfolio.ID.String())
 
 		assert.NoError(t, err)
 		assert.Len(t, found, 2)
 	})
 
 	t.Run("empty portfolio id error", func(t *testing.T) {
-		_, err := repo.FindByPortfolioID("")
+		_, err := repo.FindByPortfolioID(ctx, "")
 
 		assert.Error(t, err)
 	})
 }
 
 func TestTransactionRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupTransactionRepoTestDB(t)
 	repo := NewTransactionRepository(db)
 
@@ -208,12 +218,12 @@ func TestTransactionRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
 	}
 
 	for _, tx := range transactions {
-		err := repo.Create(tx)
+		err := repo.Create(ctx, tx)
 		assert.NoError(t, err)
 	}
 
 	t.Run("filter by symbol", func(t *testing.T) {
-		found, err := repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		found, err := repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 
 		assert.NoError(t, err)
 		assert.Len(t, found, 2)
@@ -223,15 +233,120 @@ func TestTransactionRepository_FindByPortfolioIDAndSymbol(t *testing.T) {
 	})
 
 	t.Run("empty parameters error", func(t *testing.T) {
-		_, err := repo.FindByPortfolioIDAndSymbol("", "AAPL")
+		_, err := repo.FindByPortfolioIDAndSymbol(ctx, "", "AAPL")
 		assert.Error(t, err)
 
-		_, err = repo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "")
+		_, err = repo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "")
+		assert.Error(t, err)
+	})
+}
+
+func TestTransactionRepository_FindPageByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
+	db, _, portfolio := setupTransactionRepoTestDB(t)
+	repo := NewTransactionRepository(db)
+
+	// Two transactions share each day so pages must break ties by ID
+	price := decimal.NewFromInt(100)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 7; i++ {
+		require.NoError(t, db.Create(&models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeBuy,
+			Symbol:      "AAPL",
+			Date:        start.AddDate(0, 0, i/2),
+			Quantity:    decimal.NewFromInt(1),
+			Price:       &price,
+			Currency:    "USD",
+		}).Error)
+	}
+
+	var seen []*models.Transaction
+	var after *models.Transaction
+	pages := 0
+	for {
+		page, err := repo.FindPageByPortfolioID(ctx, portfolio.ID.String(), after, 3)
+		require.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		assert.LessOrEqual(t, len(page), 3)
+		seen = append(seen, page...)
+		after = page[len(page)-1]
+		pages++
+	}
+
+	assert.Equal(t, 3, pages)
+	require.Len(t, seen, 7)
+	ids := make(map[uuid.UUID]bool)
+	for i, tx := range seen {
+		ids[tx.ID] = true
+		if i > 0 {
+			assert.False(t, tx.Date.Before(seen[i-1].Date), "pages are ordered by date")
+		}
+	}
+	assert.Len(t, ids, 7, "no transaction is read twice")
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := repo.FindPageByPortfolioID(ctx, portfolio.ID.String(), nil, 0)
 		assert.Error(t, err)
 	})
 }
 
+func TestTransactionRepository_IterateByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
+	db, _, portfolio := setupTransactionRepoTestDB(t)
+	repo := &transactionRepository{db: db, batchSize: 2}
+
+	// Transactions on the same day are replayed in the order they were recorded
+	price := decimal.NewFromInt(100)
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recorded := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	var want []uuid.UUID
+	for i := 0; i < 5; i++ {
+		transaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeBuy,
+			Symbol:      "AAPL",
+			Date:        date,
+			Quantity:    decimal.NewFromInt(1),
+			Price:       &price,
+			Currency:    "USD",
+			CreatedAt:   recorded.Add(time.Duration(5-i) * time.Minute),
+		}
+		require.NoError(t, db.Create(transaction).Error)
+		want = append([]uuid.UUID{transaction.ID}, want...)
+	}
+
+	var got []uuid.UUID
+	batches := 0
+	err := repo.IterateByPortfolioID(ctx, portfolio.ID.String(), func(batch []*models.Transaction) error {
+		batches++
+		for _, tx := range batch {
+			got = append(got, tx.ID)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, batches)
+	assert.Equal(t, want, got)
+
+	t.Run("stopping early", func(t *testing.T) {
+		batches := 0
+		err := repo.IterateByPortfolioID(ctx, portfolio.ID.String(), func([]*models.Transaction) error {
+			batches++
+			return ErrStopIteration
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, batches)
+	})
+}
+
 func TestTransactionRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupTransactionRepoTestDB(t)
 	repo := NewTransactionRepository(db)
 
@@ -246,7 +361,7 @@ func TestTransactionRepository_Update(t *testing.T) {
 		Currency:    "USD",
 		Notes:       "Original notes",
 	}
-	err := repo.Create(transaction)
+	err := repo.Create(ctx, transaction)
 	assert.NoError(t, err)
 
 	t.Run("successful update", func(t *testing.T) {
@@ -254,19 +369,39 @@ func TestTransactionRepository_Update(t *testing.T) {
 		newQuantity := decimal.NewFromInt(15)
 		transaction.Quantity = newQuantity
 
-		err := repo.Update(transaction)
+		err := repo.Update(ctx, transaction)
 
 		assert.NoError(t, err)
 
 		// Verify update
-		found, err := repo.FindByID(transaction.ID.String())
+		found, err := repo.FindByID(ctx, transaction.ID.String())
 		assert.NoError(t, err)
 		assert.Equal(t, "Updated notes", found.Notes)
 		assert.True(t, newQuantity.Equal(found.Quantity))
+		assert.Equal(t, 2, found.Version)
+	})
+
+	t.Run("stale version conflict", func(t *testing.T) {
+		stale, err := repo.FindByID(ctx, transaction.ID.String())
+		require.NoError(t, err)
+
+		transaction.Notes = "First writer"
+		require.NoError(t, repo.Update(ctx, transaction))
+
+		stale.Notes = "Second writer"
+		err = repo.Update(ctx, stale)
+
+		var conflict *models.VersionConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, 3, conflict.CurrentVersion)
+
+		found, err := repo.FindByID(ctx, transaction.ID.String())
+		require.NoError(t, err)
+		assert.Equal(t, "First writer", found.Notes)
 	})
 
 	t.Run("nil transaction error", func(t *testing.T) {
-		err := repo.Update(nil)
+		err := repo.Update(ctx, nil)
 
 		assert.Error(t, err)
 	})
@@ -282,7 +417,7 @@ func TestTransactionRepository_Update(t *testing.T) {
 			Currency:    "USD",
 		}
 
-		err := repo.Update(nonExistent)
+		err := repo.Update(ctx, nonExistent)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrTransactionNotFound, err)
@@ -290,6 +425,8 @@ func TestTransactionRepository_Update(t *testing.T) {
 }
 
 func TestTransactionRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db, _, portfolio := setupTransactionRepoTestDB(t)
 	repo := NewTransactionRepository(db)
 
@@ -303,28 +440,28 @@ func TestTransactionRepository_Delete(t *testing.T) {
 		Price:       &price,
 		Currency:    "USD",
 	}
-	err := repo.Create(transaction)
+	err := repo.Create(ctx, transaction)
 	assert.NoError(t, err)
 
 	t.Run("successful deletion", func(t *testing.T) {
-		err := repo.Delete(transaction.ID.String())
+		err := repo.Delete(ctx, transaction.ID.String())
 
 		assert.NoError(t, err)
 
 		// Verify deletion
-		_, err = repo.FindByID(transaction.ID.String())
+		_, err = repo.FindByID(ctx, transaction.ID.String())
 		assert.Error(t, err)
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		err := repo.Delete(uuid.New().String())
+		err := repo.Delete(ctx, uuid.New().String())
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrTransactionNotFound, err)
 	})
 
 	t.Run("empty id error", func(t *testing.T) {
-		err := repo.Delete("")
+		err := repo.Delete(ctx, "")
 
 		assert.Error(t, err)
 	})
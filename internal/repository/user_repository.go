@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +18,18 @@ type UserRepository interface {
 	FindByID(id string) (*models.User, error)
 	UpdateLastLogin(id string) error
 	UpdatePassword(id string, passwordHash string) error
+	List(filter UserFilter) ([]*models.User, int64, error)
+	Save(user *models.User) error
+	Delete(id string) error
+}
+
+// UserFilter narrows a user listing. Zero-valued fields don't filter.
+type UserFilter struct {
+	Email    string          // Case-insensitive substring of the email address
+	Role     models.UserRole // Only users with this role
+	Disabled *bool           // Only disabled or only enabled users
+	Limit    int
+	Offset   int
 }
 
 // userRepository implements UserRepository interface
@@ -52,7 +65,7 @@ func (r *userRepository) FindByEmail(email string) (*models.User, error) {
 	err := r.db.Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found with email: %s", email)
+			return nil, fmt.Errorf("%w with email: %s", models.ErrUserNotFound, email)
 		}
 		return nil, fmt.Errorf("failed to find user by email: %w", err)
 	}
@@ -76,7 +89,7 @@ func (r *userRepository) FindByID(id string) (*models.User, error) {
 	err = r.db.Where("id = ?", userID).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found with id: %s", id)
+			return nil, fmt.Errorf("%w with id: %s", models.ErrUserNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to find user by id: %w", err)
 	}
@@ -109,7 +122,8 @@ func (r *userRepository) UpdateLastLogin(id string) error {
 	return nil
 }
 
-// UpdatePassword updates the password hash for a user
+// UpdatePassword updates the password hash for a user. A new password also satisfies a
+// forced password reset.
 func (r *userRepository) UpdatePassword(id string, passwordHash string) error {
 	if id == "" {
 		return fmt.Errorf("id cannot be empty")
@@ -128,8 +142,9 @@ func (r *userRepository) UpdatePassword(id string, passwordHash string) error {
 	result := r.db.Model(&models.User{}).
 		Where("id = ?", userID).
 		Updates(map[string]interface{}{
-			"password_hash": passwordHash,
-			"updated_at":    now,
+			"password_hash":           passwordHash,
+			"password_reset_required": false,
+			"updated_at":              now,
 		})
 
 	if result.Error != nil {
@@ -142,3 +157,75 @@ func (r *userRepository) UpdatePassword(id string, passwordHash string) error {
 
 	return nil
 }
+
+// List returns the users matching filter ordered by creation date, oldest first, along with
+// the number of matching users before Limit and Offset are applied
+func (r *userRepository) List(filter UserFilter) ([]*models.User, int64, error) {
+	query := r.db.Model(&models.User{})
+	if filter.Email != "" {
+		query = query.Where("LOWER(email) LIKE ?", "%"+strings.ToLower(filter.Email)+"%")
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Disabled != nil {
+		if *filter.Disabled {
+			query = query.Where("disabled_at IS NOT NULL")
+		} else {
+			query = query.Where("disabled_at IS NULL")
+		}
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query = query.Order("created_at ASC").Order("id ASC")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var users []*models.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// Save updates an existing user
+func (r *userRepository) Save(user *models.User) error {
+	if user == nil {
+		return fmt.Errorf("user cannot be nil")
+	}
+
+	user.UpdatedAt = time.Now().UTC()
+	if err := r.db.Save(user).Error; err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a user. Their portfolios, tokens and API keys are deleted with them.
+func (r *userRepository) Delete(id string) error {
+	userID, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	result := r.db.Delete(&models.User{}, "id = ?", userID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete user: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w with id: %s", models.ErrUserNotFound, id)
+	}
+
+	return nil
+}
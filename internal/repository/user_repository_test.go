@@ -275,3 +275,87 @@ func TestUserRepository_UpdatePassword_UserNotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "user not found with id")
 }
+
+func TestUserRepository_List(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+
+	disabledAt := time.Now().UTC()
+	base := time.Now().UTC().Add(-time.Hour)
+	users := []*models.User{
+		{Email: "ops@example.com", Role: models.RoleAdmin},
+		{Email: "Jane@Acme.example", Role: models.RoleUser},
+		{Email: "john@acme.example", Role: models.RoleUser, DisabledAt: &disabledAt},
+	}
+	for i, user := range users {
+		user.PasswordHash = "hashed"
+		user.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		assert.NoError(t, repo.Create(user))
+	}
+
+	found, total, err := repo.List(UserFilter{Email: "acme"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	if assert.Len(t, found, 2) {
+		assert.Equal(t, users[1].ID, found[0].ID)
+	}
+
+	found, total, err = repo.List(UserFilter{Role: models.RoleAdmin})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "ops@example.com", found[0].Email)
+
+	disabled := true
+	found, total, err = repo.List(UserFilter{Disabled: &disabled})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, "john@acme.example", found[0].Email)
+
+	// The total counts every match, not just the page
+	found, total, err = repo.List(UserFilter{Limit: 1, Offset: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+	if assert.Len(t, found, 1) {
+		assert.Equal(t, users[1].ID, found[0].ID)
+	}
+}
+
+func TestUserRepository_Save(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hashed"}
+	assert.NoError(t, repo.Create(user))
+	assert.Equal(t, models.RoleUser, user.Role)
+
+	user.Role = models.RoleAdmin
+	user.PasswordResetRequired = true
+	assert.NoError(t, repo.Save(user))
+
+	saved, err := repo.FindByID(user.ID.String())
+	assert.NoError(t, err)
+	assert.True(t, saved.IsAdmin())
+	assert.True(t, saved.PasswordResetRequired)
+
+	// Setting a new password clears a forced reset
+	assert.NoError(t, repo.UpdatePassword(user.ID.String(), "new-hashed"))
+	saved, err = repo.FindByID(user.ID.String())
+	assert.NoError(t, err)
+	assert.False(t, saved.PasswordResetRequired)
+}
+
+func TestUserRepository_Delete(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewUserRepository(db)
+
+	user := &models.User{Email: "test@example.com", PasswordHash: "hashed"}
+	assert.NoError(t, repo.Create(user))
+
+	assert.NoError(t, repo.Delete(user.ID.String()))
+
+	_, err := repo.FindByID(user.ID.String())
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+
+	assert.ErrorIs(t, repo.Delete(user.ID.String()), models.ErrUserNotFound)
+	assert.Error(t, repo.Delete("invalid-uuid"))
+}
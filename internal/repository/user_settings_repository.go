@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// UserSettingsRepository defines the interface for user settings data operations
+type UserSettingsRepository interface {
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*models.UserSettings, error)
+	Save(ctx context.Context, settings *models.UserSettings) error
+}
+
+// userSettingsRepository implements UserSettingsRepository interface
+type userSettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewUserSettingsRepository creates a new UserSettingsRepository instance
+func NewUserSettingsRepository(db *gorm.DB) UserSettingsRepository {
+	return &userSettingsRepository{db: db}
+}
+
+// FindByUserID finds a user's settings, returning the defaults for a user who never saved any
+func (r *userSettingsRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*models.UserSettings, error) {
+	var settings models.UserSettings
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&settings).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.DefaultUserSettings(userID), nil
+		}
+		return nil, fmt.Errorf("failed to find user settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+// Save stores a user's settings, replacing those saved before
+func (r *userSettingsRepository) Save(ctx context.Context, settings *models.UserSettings) error {
+	if settings == nil {
+		return fmt.Errorf("settings cannot be nil")
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		UpdateAll: true,
+	}).Create(settings).Error
+	if err != nil {
+		return fmt.Errorf("failed to save user settings: %w", err)
+	}
+
+	return nil
+}
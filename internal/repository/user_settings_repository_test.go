@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestUserSettingsRepository(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.UserSettings{}))
+	repo := NewUserSettingsRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// Users who never saved settings get the defaults
+	settings, err := repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultUserSettings(userID), settings)
+
+	settings.DisplayCurrency = "EUR"
+	settings.NotifyImports = false
+	require.NoError(t, repo.Save(ctx, settings))
+
+	// Saving again replaces the settings, including fields set to false
+	settings.Locale = "de-DE"
+	settings.PushNotifications = false
+	require.NoError(t, repo.Save(ctx, settings))
+
+	saved, err := repo.FindByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", saved.DisplayCurrency)
+	assert.Equal(t, "de-DE", saved.Locale)
+	assert.False(t, saved.NotifyImports)
+	assert.False(t, saved.PushNotifications)
+	assert.True(t, saved.NotifyReports)
+}
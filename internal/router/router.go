@@ -0,0 +1,527 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/lenon/portfolios/internal/handlers"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// Handlers holds the HTTP handlers the API routes dispatch to. PerformanceAnalytics and
+// MarketData depend on a market data provider and may be nil, in which case their routes
+// are not registered. Admin is nil unless an admin API token is configured, and Maintenance
+// routes are only registered with it. UserAdmin routes are only registered when Auth.Users is
+// set, Organization routes when Organization is set, Delegation routes when Delegation is
+// set, and FeatureFlag routes when FeatureFlag is set, its admin routes also needing Auth.Users.
+type Handlers struct {
+	Auth                 *handlers.AuthHandler
+	Password             *handlers.PasswordHandler
+	Portfolio            *handlers.PortfolioHandler
+	Transaction          *handlers.TransactionHandler
+	Tag                  *handlers.TagHandler
+	Aggregation          *handlers.AggregationHandler
+	Dashboard            *handlers.DashboardHandler
+	PortfolioGroup       *handlers.PortfolioGroupHandler
+	Import               *handlers.ImportHandler
+	TrackerImport        *handlers.TrackerImportHandler
+	OpeningLotImport     *handlers.OpeningLotImportHandler
+	BrokerConnection     *handlers.BrokerConnectionHandler
+	Reconciliation       *handlers.ReconciliationHandler
+	Job                  *handlers.JobHandler
+	Notification         *handlers.NotificationHandler
+	Push                 *handlers.PushHandler
+	Settings             *handlers.UserSettingsHandler
+	Holding              *handlers.HoldingHandler
+	PerformanceAnalytics *handlers.PerformanceAnalyticsHandler
+	PerformanceSnapshot  *handlers.PerformanceSnapshotHandler
+	Certification        *handlers.PerformanceCertificationHandler
+	Statement            *handlers.StatementHandler
+	Export               *handlers.ExportHandler
+	ReportSubscription   *handlers.ReportSubscriptionHandler
+	StockPlan            *handlers.StockPlanHandler
+	Option               *handlers.OptionHandler
+	Blackout             *handlers.BlackoutHandler
+	RebalancePlan        *handlers.RebalancePlanHandler
+	PeerComparison       *handlers.PeerComparisonHandler
+	FeeComparison        *handlers.FeeComparisonHandler
+	FeeSummary           *handlers.FeeSummaryHandler
+	Contribution         *handlers.ContributionHandler
+	Projection           *handlers.ProjectionHandler
+	Simulation           *handlers.SimulationHandler
+	WhatIf               *handlers.WhatIfHandler
+	Recalculation        *handlers.RecalculationHandler
+	SnapshotBackfill     *handlers.SnapshotBackfillHandler
+	TaxLot               *handlers.TaxLotHandler
+	PortfolioAction      *handlers.PortfolioActionHandler
+	MarketData           *handlers.MarketDataHandler
+	Security             *handlers.SecurityHandler
+	Admin                *handlers.AdminHandler
+	Maintenance          *handlers.MaintenanceHandler
+	UserAdmin            *handlers.UserAdminHandler
+	Organization         *handlers.OrganizationHandler
+	Delegation           *handlers.DelegationHandler
+	FeatureFlag          *handlers.FeatureFlagHandler
+}
+
+// Auth holds what the routes need to authenticate requests
+type Auth struct {
+	// TokenService validates user JWTs
+	TokenService *services.TokenService
+	// APIKeys, if set, also lets API v1 requests authenticate with an API key
+	APIKeys services.APIKeyService
+	// AdminToken is the bearer token required by the admin API
+	AdminToken string
+	// Users, if set, rejects requests from disabled accounts and accounts pending a forced
+	// password reset, and looks up roles for the admin user management routes
+	Users middleware.UserLookup
+	// TenantSchemas, if set, routes API v1 requests to the schema of the user's organization
+	TenantSchemas middleware.TenantSchemaResolver
+	// Organizations, if set, lets API v1 requests act in an organization named by the
+	// X-Organization-ID header
+	Organizations middleware.OrganizationScopeResolver
+	// Delegations, if set, lets advisors' API v1 requests act on behalf of a client named by
+	// the X-On-Behalf-Of header, and records what they do
+	Delegations middleware.DelegationResolver
+	// UnsubscribeTokens verifies the links in report digest emails, which work without signing in
+	UnsubscribeTokens middleware.UnsubscribeTokenVerifier
+	// RateLimit is applied to the authentication endpoints
+	RateLimit gin.HandlerFunc
+	// APIRateLimit, if set, is applied to API v1 requests once they are authenticated
+	APIRateLimit gin.HandlerFunc
+	// Features, if set, hides experimental routes from users their feature is disabled for
+	Features middleware.FeatureChecker
+}
+
+// Register registers the health check and all API routes on the router
+func Register(router *gin.Engine, h Handlers, auth Auth) {
+	// Health check endpoint (no authentication required)
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"status":    "healthy",
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	})
+
+	// API routes
+	api := router.Group("/api")
+	{
+		// Error code catalog (no authentication required)
+		api.GET("/errors", handlers.ListErrorCodes)
+
+		// Auth routes with rate limiting
+		authRoutes := api.Group("/auth")
+		authRoutes.Use(auth.RateLimit)
+		{
+			authRoutes.POST("/register", h.Auth.Register)
+			authRoutes.POST("/login", h.Auth.Login)
+			authRoutes.POST("/refresh", h.Auth.RefreshToken)
+			authRoutes.POST("/forgot-password", h.Auth.ForgotPassword)
+			authRoutes.POST("/reset-password", h.Auth.ResetPassword)
+
+			// Protected routes
+			authenticated := authRoutes.Group("")
+			authenticated.Use(middleware.AuthRequired(auth.TokenService))
+			if auth.Users != nil {
+				authenticated.Use(middleware.ActiveUserRequired(auth.Users))
+			}
+			{
+				authenticated.POST("/logout", h.Auth.Logout)
+				authenticated.GET("/me", h.Auth.GetCurrentUser)
+				if h.Password != nil {
+					authenticated.POST("/change-password", h.Password.ChangePassword)
+				}
+			}
+		}
+
+		// Unsubscribe links in report digest emails, authenticated by their signed token
+		unsubscribe := api.Group("/report-subscriptions")
+		unsubscribe.Use(auth.RateLimit, middleware.UnsubscribeTokenRequired(auth.UnsubscribeTokens))
+		if auth.TenantSchemas != nil {
+			unsubscribe.Use(middleware.TenantSchema(auth.TenantSchemas))
+		}
+		{
+			unsubscribe.GET("/unsubscribe", h.ReportSubscription.Unsubscribe)
+		}
+
+		// Versioned API routes (protected). Every supported version serves the same routes under
+		// its own prefix, and handlers whose responses change between versions check
+		// middleware.GetAPIVersion, so a breaking change ships as a new version while clients
+		// of the old one keep getting the old responses.
+		for _, version := range middleware.SupportedAPIVersions {
+			registerVersion(api.Group(fmt.Sprintf("/v%d", version)), version, h, auth)
+		}
+
+		// Admin provisioning routes for infrastructure tooling (if enabled)
+		if h.Admin != nil {
+			admin := api.Group("/admin/v1")
+			admin.Use(middleware.AdminTokenRequired(auth.AdminToken))
+			{
+				admin.PUT("/orgs/:org_id", h.Admin.PutOrganization)
+				admin.GET("/orgs/:org_id", h.Admin.GetOrganization)
+				admin.PUT("/orgs/:org_id/quotas", h.Admin.PutQuotas)
+				admin.PUT("/orgs/:org_id/users/:user_id", h.Admin.PutUser)
+				admin.GET("/orgs/:org_id/users/:user_id", h.Admin.GetUser)
+				admin.PUT("/orgs/:org_id/api-keys/:key_id", h.Admin.PutAPIKey)
+				admin.GET("/orgs/:org_id/api-keys/:key_id", h.Admin.GetAPIKey)
+				admin.DELETE("/orgs/:org_id/api-keys/:key_id", h.Admin.DeleteAPIKey)
+				if h.Maintenance != nil {
+					admin.GET("/maintenance", h.Maintenance.GetMaintenance)
+					admin.PUT("/maintenance", h.Maintenance.PutMaintenance)
+					admin.DELETE("/maintenance", h.Maintenance.DeleteMaintenance)
+				}
+			}
+		}
+	}
+}
+
+// registerVersion registers the authenticated routes of an API version on v, its /api/vN
+// group
+func registerVersion(v *gin.RouterGroup, version int, h Handlers, auth Auth) {
+	v.Use(middleware.APIVersion(version))
+	if auth.APIKeys != nil {
+		v.Use(middleware.AuthRequiredWithAPIKeys(auth.TokenService, auth.APIKeys))
+	} else {
+		v.Use(middleware.AuthRequired(auth.TokenService))
+	}
+	if auth.Users != nil {
+		v.Use(middleware.ActiveUserRequired(auth.Users))
+	}
+	if auth.APIRateLimit != nil {
+		v.Use(auth.APIRateLimit)
+	}
+	if auth.TenantSchemas != nil {
+		v.Use(middleware.TenantSchema(auth.TenantSchemas))
+	}
+	if auth.Organizations != nil {
+		v.Use(middleware.OrganizationScope(auth.Organizations))
+	}
+	if auth.Delegations != nil {
+		v.Use(middleware.DelegationScope(auth.Delegations))
+	}
+	// feature gates an experimental route behind its feature flag
+	feature := func(name string, handler gin.HandlerFunc) []gin.HandlerFunc {
+		if auth.Features == nil {
+			return []gin.HandlerFunc{handler}
+		}
+		return []gin.HandlerFunc{middleware.FeatureRequired(auth.Features, name), handler}
+	}
+	{
+		// Heavy read-only routes may be served by the read replica
+		readReplica := middleware.ReadReplica()
+
+		// Portfolio routes
+		portfolios := v.Group("/portfolios")
+		{
+			portfolios.POST("", h.Portfolio.Create)
+			portfolios.GET("", h.Portfolio.GetAll)
+			portfolios.GET("/:id", h.Portfolio.GetByID)
+			portfolios.PUT("/:id", h.Portfolio.Update)
+			portfolios.DELETE("/:id", h.Portfolio.Delete)
+
+			// Transaction routes under portfolio
+			portfolios.POST("/:id/transactions", h.Transaction.Create)
+			portfolios.GET("/:id/transactions", h.Transaction.GetAll)
+			portfolios.GET("/:id/transactions/export", readReplica, h.Export.ExportTransactions)
+
+			// CSV import routes
+			portfolios.POST("/:id/transactions/import/csv", h.Import.ImportCSV)
+			portfolios.POST("/:id/transactions/import/bulk", h.Import.ImportBulk)
+			portfolios.POST("/:id/transactions/import/lots", h.OpeningLotImport.Import)
+			portfolios.GET("/:id/imports/batches", h.Import.GetImportBatches)
+			portfolios.DELETE("/:id/imports/batches/:batch_id", h.Import.DeleteImportBatch)
+			portfolios.GET("/:id/imports/batches/:batch_id/events", h.Import.ImportEvents)
+
+			// Holding routes under portfolio
+			portfolios.GET("/:id/holdings", h.Holding.GetAll)
+			portfolios.GET("/:id/holdings/:symbol", h.Holding.GetBySymbol)
+
+			// Performance analytics routes (if available)
+			if h.PerformanceAnalytics != nil {
+				portfolios.GET("/:id/performance/metrics", readReplica, h.PerformanceAnalytics.GetPerformanceMetrics)
+				portfolios.GET("/:id/performance/periods", readReplica, h.PerformanceAnalytics.GetPeriodMetrics)
+				portfolios.GET("/:id/performance/twr", readReplica, h.PerformanceAnalytics.GetTWR)
+				portfolios.GET("/:id/performance/mwr", readReplica, h.PerformanceAnalytics.GetMWR)
+				portfolios.GET("/:id/performance/annualized", readReplica, h.PerformanceAnalytics.GetAnnualizedReturn)
+				portfolios.GET("/:id/performance/benchmark", readReplica, h.PerformanceAnalytics.GetBenchmarkComparison)
+			}
+
+			// Growth split into net contributions and investment returns
+			portfolios.GET("/:id/performance/contributions", readReplica, h.Contribution.Get)
+
+			// Performance snapshot routes
+			portfolios.GET("/:id/snapshots", readReplica, h.PerformanceSnapshot.GetSnapshots)
+			portfolios.GET("/:id/snapshots/range", readReplica, h.PerformanceSnapshot.GetSnapshotsByDateRange)
+			portfolios.GET("/:id/snapshots/latest", readReplica, h.PerformanceSnapshot.GetLatestSnapshot)
+			portfolios.POST("/:id/snapshots/backfill", h.SnapshotBackfill.Backfill)
+
+			// Signed performance certification with its methodology and inputs
+			portfolios.GET("/:id/performance/certification", readReplica, h.Certification.Export)
+
+			// Monthly and quarterly statements as PDF documents or HTML previews
+			portfolios.GET("/:id/statements", readReplica, h.Statement.Generate)
+
+			// Employer stock plan routes
+			portfolios.POST("/:id/stock-plans/grants", h.StockPlan.CreateGrant)
+			portfolios.GET("/:id/stock-plans/grants", h.StockPlan.GetGrants)
+			portfolios.GET("/:id/stock-plans/grants/:grant_id", h.StockPlan.GetGrant)
+			portfolios.DELETE("/:id/stock-plans/grants/:grant_id", h.StockPlan.DeleteGrant)
+			portfolios.GET("/:id/stock-plans/grants/:grant_id/events", h.StockPlan.GetGrantEvents)
+			portfolios.POST("/:id/stock-plans/grants/:grant_id/vest", h.StockPlan.RecordVest)
+			portfolios.POST("/:id/stock-plans/grants/:grant_id/espp-purchase", h.StockPlan.RecordESPPPurchase)
+			portfolios.POST("/:id/stock-plans/grants/:grant_id/exercise", h.StockPlan.RecordExercise)
+			portfolios.GET("/:id/stock-plans/vesting-calendar", h.StockPlan.GetVestingCalendar)
+
+			// Option contract positions
+			portfolios.GET("/:id/options", h.Option.List)
+			portfolios.POST("/:id/options/trades", h.Option.Trade)
+			portfolios.POST("/:id/options/:contract_id/settle", h.Option.Settle)
+
+			// Employer stock blackout windows
+			portfolios.GET("/:id/employer-stock", h.Blackout.GetPolicy)
+			portfolios.PUT("/:id/employer-stock", h.Blackout.SetPolicy)
+			portfolios.DELETE("/:id/employer-stock", h.Blackout.DeletePolicy)
+			portfolios.POST("/:id/blackout-windows", h.Blackout.CreateWindow)
+			portfolios.GET("/:id/blackout-windows", h.Blackout.GetWindows)
+			portfolios.DELETE("/:id/blackout-windows/:window_id", h.Blackout.DeleteWindow)
+			portfolios.GET("/:id/blackout-overrides", h.Blackout.GetOverrides)
+
+			// Rebalancing execution plans
+			portfolios.POST("/:id/rebalance-plans", h.RebalancePlan.Create)
+			portfolios.GET("/:id/rebalance-plans", h.RebalancePlan.List)
+			portfolios.GET("/:id/rebalance-plans/:plan_id", h.RebalancePlan.Get)
+			portfolios.DELETE("/:id/rebalance-plans/:plan_id", h.RebalancePlan.Delete)
+			portfolios.POST("/:id/rebalance-plans/:plan_id/cancel", h.RebalancePlan.Cancel)
+			portfolios.POST("/:id/rebalance-plans/:plan_id/trades/:trade_id/execute", h.RebalancePlan.ExecuteTrade)
+			portfolios.POST("/:id/rebalance-plans/:plan_id/trades/:trade_id/skip", h.RebalancePlan.SkipTrade)
+
+			// Broker connections
+			portfolios.POST("/:id/connections", h.BrokerConnection.Create)
+			portfolios.GET("/:id/connections", h.BrokerConnection.List)
+			portfolios.GET("/:id/connections/:connection_id", h.BrokerConnection.Get)
+			portfolios.DELETE("/:id/connections/:connection_id", h.BrokerConnection.Delete)
+			portfolios.POST("/:id/connections/:connection_id/sync", h.BrokerConnection.Sync)
+			portfolios.POST("/:id/reconcile", h.Reconciliation.Reconcile)
+
+			// Anonymized peer percentile comparison (opt-in)
+			portfolios.PUT("/:id/peer-comparison/opt-in", feature(models.FeaturePeerComparison, h.PeerComparison.SetOptIn)...)
+			portfolios.GET("/:id/peer-comparison", feature(models.FeaturePeerComparison, h.PeerComparison.Get)...)
+
+			// Historical fees replayed under other brokers' fee schedules
+			portfolios.POST("/:id/fee-comparison", h.FeeComparison.Compare)
+
+			// Yearly fees by category and their drag on the annualized return
+			portfolios.GET("/:id/fees", readReplica, h.FeeSummary.Get)
+
+			// Simulated future value under monthly contributions
+			portfolios.POST("/:id/projection", feature(models.FeatureProjections, h.Projection.Project)...)
+
+			// Stored Monte Carlo simulations of retirement withdrawals, run in the background
+			portfolios.POST("/:id/simulations", feature(models.FeatureSimulations, h.Simulation.Create)...)
+			portfolios.GET("/:id/simulations", feature(models.FeatureSimulations, h.Simulation.List)...)
+			portfolios.GET("/:id/simulations/:simulation_id", feature(models.FeatureSimulations, h.Simulation.Get)...)
+			portfolios.DELETE("/:id/simulations/:simulation_id", feature(models.FeatureSimulations, h.Simulation.Delete)...)
+
+			// Preview of hypothetical trades, never recorded
+			portfolios.POST("/:id/what-if", feature(models.FeatureWhatIf, h.WhatIf.Analyze)...)
+
+			// Full rebuild of holdings and tax lots from the transaction ledger
+			portfolios.POST("/:id/recalculate", h.Recalculation.Recalculate)
+		}
+
+		// Import another portfolio tracker's export into new portfolios
+		v.POST("/imports/tracker", h.TrackerImport.Import)
+
+		// Status of queued imports, recalculations and reports
+		jobs := v.Group("/jobs")
+		{
+			jobs.GET("", h.Job.List)
+			jobs.GET("/:id", h.Job.Get)
+		}
+
+		// In-app notifications of corporate actions, finished imports and scheduled reports
+		notifications := v.Group("/notifications")
+		{
+			notifications.GET("", h.Notification.List)
+			notifications.POST("/:id/read", h.Notification.MarkRead)
+		}
+
+		// The user's display currency, time zone, locale, default cost basis method and
+		// notification preferences
+		v.GET("/settings", h.Settings.Get)
+		v.PUT("/settings", h.Settings.Update)
+
+		// Everything stored about the user, streamed as JSON Lines for data portability requests
+		v.GET("/export", readReplica, h.Export.ExportAccount)
+
+		// Browsers and phones that receive notifications as push notifications
+		push := v.Group("/push")
+		{
+			push.GET("/vapid-public-key", h.Push.GetVAPIDPublicKey)
+			push.GET("/devices", h.Push.ListDevices)
+			push.POST("/devices", h.Push.RegisterDevice)
+			push.DELETE("/devices/:id", h.Push.DeleteDevice)
+		}
+
+		// Check a performance certification against its signature
+		v.POST("/performance-certifications/verify", h.Certification.Verify)
+
+		// Weekly and monthly performance digests sent by email
+		reportSubscriptions := v.Group("/report-subscriptions")
+		{
+			reportSubscriptions.GET("", h.ReportSubscription.List)
+			reportSubscriptions.POST("", h.ReportSubscription.Create)
+			reportSubscriptions.GET("/:id", h.ReportSubscription.Get)
+			reportSubscriptions.PUT("/:id", h.ReportSubscription.Update)
+			reportSubscriptions.DELETE("/:id", h.ReportSubscription.Delete)
+			reportSubscriptions.GET("/:id/preview", h.ReportSubscription.Preview)
+		}
+
+		// Tags on portfolios and transactions, and performance across tagged portfolios
+		tags := v.Group("/tags")
+		{
+			tags.GET("", h.Tag.List)
+			if h.PerformanceAnalytics != nil {
+				tags.GET("/:tag/performance", readReplica, h.Tag.GetPerformance)
+			}
+		}
+
+		// Household overview across all of the user's portfolios
+		v.GET("/overview", readReplica, h.Aggregation.GetOverview)
+
+		// Everything the home screen shows, in one request
+		v.GET("/dashboard", readReplica, h.Dashboard.Get)
+
+		// Portfolio groups with roll-up valuation and performance
+		groups := v.Group("/groups")
+		{
+			groups.GET("", h.PortfolioGroup.List)
+			groups.POST("", h.PortfolioGroup.Create)
+			groups.GET("/:id", h.PortfolioGroup.Get)
+			groups.PUT("/:id", h.PortfolioGroup.Update)
+			groups.DELETE("/:id", h.PortfolioGroup.Delete)
+			groups.GET("/:id/overview", readReplica, h.PortfolioGroup.GetOverview)
+			if h.PerformanceAnalytics != nil {
+				groups.GET("/:id/benchmark", readReplica, h.PortfolioGroup.GetBenchmarkComparison)
+			}
+		}
+
+		// Built-in broker fee schedules for fee comparison
+		v.GET("/fee-schedules", h.FeeComparison.ListPresets)
+
+		// Transaction routes
+		transactions := v.Group("/transactions")
+		{
+			transactions.GET("/:id", h.Transaction.GetByID)
+			transactions.PUT("/:id", h.Transaction.Update)
+			transactions.DELETE("/:id", h.Transaction.Delete)
+		}
+
+		// Tax lot routes
+		taxLots := v.Group("/tax-lots")
+		{
+			taxLots.GET("/:id", h.TaxLot.GetByID)
+		}
+
+		// Portfolio-specific tax lot routes
+		v.GET("/portfolios/:id/tax-lots", h.TaxLot.GetAll)
+		v.POST("/portfolios/:id/tax-lots/allocate", h.TaxLot.AllocateSale)
+		v.GET("/portfolios/:id/tax-lots/harvest", h.TaxLot.IdentifyTaxLossOpportunities)
+		v.POST("/portfolios/:id/tax-lots/report", h.TaxLot.GenerateTaxReport)
+		v.PUT("/portfolios/:id/tax-regime", h.TaxLot.SetTaxRegime)
+		v.GET("/portfolios/:id/tax/export", readReplica, h.TaxLot.ExportTaxForm)
+
+		// Portfolio action routes (pending corporate actions)
+		v.GET("/portfolios/:id/actions", h.PortfolioAction.GetAllActions)
+		v.GET("/portfolios/:id/actions/pending", h.PortfolioAction.GetPendingActions)
+		v.GET("/portfolios/:id/actions/calendar", h.PortfolioAction.GetCalendar)
+		v.GET("/portfolios/:id/actions/:action_id", h.PortfolioAction.GetActionByID)
+		v.POST("/portfolios/:id/actions/:action_id/approve", h.PortfolioAction.ApproveAction)
+		v.POST("/portfolios/:id/actions/:action_id/reject", h.PortfolioAction.RejectAction)
+
+		// Symbol search and security lookup routes. Without market data only securities
+		// found by earlier searches are found.
+		market := v.Group("/market")
+		{
+			market.GET("/search", h.Security.Search)
+			market.GET("/securities/:symbol", h.Security.Get)
+		}
+
+		// Market data routes (if available)
+		if h.MarketData != nil {
+			market.GET("/quote/:symbol", h.MarketData.GetQuote)
+			market.POST("/quotes", h.MarketData.GetQuotes)
+			market.GET("/history/:symbol", h.MarketData.GetHistoricalPrices)
+			market.GET("/exchange", h.MarketData.GetExchangeRate)
+			market.POST("/cache/clear", h.MarketData.ClearCache)
+			market.GET("/quota", h.MarketData.GetQuota)
+		}
+
+		// Organization routes
+		if h.Organization != nil {
+			orgs := v.Group("/orgs")
+			{
+				orgs.GET("", h.Organization.ListMemberships)
+				orgs.POST("", h.Organization.Create)
+				orgs.POST("/invitations/accept", h.Organization.AcceptInvitation)
+				orgs.GET("/:org_id/members", h.Organization.ListMembers)
+				orgs.PUT("/:org_id/members/:user_id", h.Organization.SetMemberRole)
+				orgs.DELETE("/:org_id/members/:user_id", h.Organization.RemoveMember)
+				orgs.GET("/:org_id/invitations", h.Organization.ListInvitations)
+				orgs.POST("/:org_id/invitations", h.Organization.Invite)
+				orgs.DELETE("/:org_id/invitations/:invitation_id", h.Organization.RevokeInvitation)
+				orgs.GET("/:org_id/api-keys", h.Organization.ListAPIKeys)
+				orgs.POST("/:org_id/api-keys", h.Organization.CreateAPIKey)
+				orgs.DELETE("/:org_id/api-keys/:key_id", h.Organization.RevokeAPIKey)
+			}
+		}
+
+		// Advisor delegation routes
+		if h.Delegation != nil {
+			delegations := v.Group("/delegations")
+			{
+				delegations.GET("", h.Delegation.List)
+				delegations.POST("", h.Delegation.Request)
+				delegations.POST("/consent", h.Delegation.Consent)
+				delegations.PUT("/:delegation_id/portfolios", h.Delegation.SetPortfolios)
+				delegations.DELETE("/:delegation_id", h.Delegation.Revoke)
+				delegations.GET("/:delegation_id/audit", h.Delegation.ListAuditEvents)
+			}
+		}
+
+		// User management routes for administrators
+		if h.UserAdmin != nil && auth.Users != nil {
+			admin := v.Group("/admin")
+			admin.Use(middleware.RequireRole(auth.Users, models.RoleAdmin))
+			{
+				admin.GET("/users", h.UserAdmin.ListUsers)
+				admin.GET("/users/:id", h.UserAdmin.GetUser)
+				admin.PUT("/users/:id/role", h.UserAdmin.SetRole)
+				admin.POST("/users/:id/disable", h.UserAdmin.DisableUser)
+				admin.POST("/users/:id/enable", h.UserAdmin.EnableUser)
+				admin.POST("/users/:id/reset-password", h.UserAdmin.ForcePasswordReset)
+				admin.DELETE("/users/:id", h.UserAdmin.DeleteUser)
+				admin.GET("/users/:id/usage", h.UserAdmin.GetUsage)
+			}
+		}
+
+		// Feature flags enabled for the user, and the admin routes that override them
+		if h.FeatureFlag != nil {
+			v.GET("/features", h.FeatureFlag.GetFeatures)
+			if auth.Users != nil {
+				flags := v.Group("/admin/feature-flags")
+				flags.Use(middleware.RequireRole(auth.Users, models.RoleAdmin))
+				{
+					flags.GET("", h.FeatureFlag.ListFeatureFlags)
+					flags.PUT("/:name", h.FeatureFlag.SetFeatureFlag)
+					flags.DELETE("/:name", h.FeatureFlag.DeleteFeatureFlag)
+				}
+			}
+		}
+	}
+}
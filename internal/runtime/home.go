@@ -21,6 +21,10 @@ const (
 
 	// RequestLogFileName is the name of the request log file
 	RequestLogFileName = "requests.log"
+
+	// EmailTemplatesDirName is the name of the directory of email templates that replace
+	// the built-in ones
+	EmailTemplatesDirName = "email-templates"
 )
 
 // HomeDir represents the runtime home directory structure
@@ -30,6 +34,9 @@ type HomeDir struct {
 	LogsDir    string // Full path to the logs directory
 	ServerLog  string // Full path to the server log file
 	RequestLog string // Full path to the request log file
+	// Full path to the directory of email templates replacing the built-in ones, which is
+	// optional and not created
+	EmailTemplatesDir string
 }
 
 // InitHomeDir initializes the runtime home directory structure
@@ -46,11 +53,12 @@ func InitHomeDir(homePath string) (*HomeDir, error) {
 
 	// Create home directory structure
 	home := &HomeDir{
-		Root:       homePath,
-		ConfigPath: filepath.Join(homePath, ConfigFileName),
-		LogsDir:    filepath.Join(homePath, LogsDirName),
-		ServerLog:  filepath.Join(homePath, LogsDirName, ServerLogFileName),
-		RequestLog: filepath.Join(homePath, LogsDirName, RequestLogFileName),
+		Root:              homePath,
+		ConfigPath:        filepath.Join(homePath, ConfigFileName),
+		LogsDir:           filepath.Join(homePath, LogsDirName),
+		ServerLog:         filepath.Join(homePath, LogsDirName, ServerLogFileName),
+		RequestLog:        filepath.Join(homePath, LogsDirName, RequestLogFileName),
+		EmailTemplatesDir: filepath.Join(homePath, EmailTemplatesDirName),
 	}
 
 	// Create directories with appropriate permissions
@@ -0,0 +1,250 @@
+// Package server assembles the API server cmd/api runs: the HTTP router, the optional gRPC
+// server, the background job scheduler and the workers running queued jobs. Other programs
+// can embed it and tests can start it without going through main.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/app"
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/jobs"
+	"github.com/lenon/portfolios/internal/logger"
+)
+
+// DefaultShutdownTimeout bounds how long Run waits for requests and jobs to finish once its
+// context is done, unless the configuration sets server.shutdown_timeout
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Server is the API server built from a configuration and a database
+type Server struct {
+	Container *app.Container
+	// Engine routes the HTTP API
+	Engine *gin.Engine
+	// HTTPServer serves Engine on the configured port
+	HTTPServer *http.Server
+	// GRPCServer serves the gRPC API, nil when no gRPC port is configured
+	GRPCServer *grpc.Server
+	// Scheduler runs the background jobs and WorkerPool the queued jobs. Both are nil when
+	// the configuration disables jobs for a separate worker to run them, and WorkerPool is
+	// nil without job workers.
+	Scheduler  *jobs.Scheduler
+	WorkerPool *jobs.WorkerPool
+
+	cfg    *config.Config
+	logger *logger.AppLogger
+}
+
+// Option configures a Server
+type Option func(*options)
+
+type options struct {
+	logger        *logger.AppLogger
+	requestLogger *logger.AppLogger
+	appOptions    []app.Option
+}
+
+// WithLogger logs the server's events and errors to log instead of the default logger
+func WithLogger(log *logger.AppLogger) Option {
+	return func(o *options) {
+		o.logger = log
+	}
+}
+
+// WithRequestLogger logs requests to log instead of the server's logger
+func WithRequestLogger(log *logger.AppLogger) Option {
+	return func(o *options) {
+		o.requestLogger = log
+	}
+}
+
+// WithAppOptions builds the server's services with opts, such as a market data provider
+func WithAppOptions(opts ...app.Option) Option {
+	return func(o *options) {
+		o.appOptions = append(o.appOptions, opts...)
+	}
+}
+
+// New builds the server's services, routes and jobs on db. Nothing is started until Start or
+// Run; Close releases the services when the server is no longer needed.
+func New(cfg *config.Config, db *gorm.DB, opts ...Option) (*Server, error) {
+	o := options{logger: logger.GetLogger()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.requestLogger == nil {
+		o.requestLogger = o.logger
+	}
+
+	container, err := app.BuildServices(cfg, db, append([]app.Option{app.WithLogger(o.logger)}, o.appOptions...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize services: %w", err)
+	}
+
+	s := &Server{
+		Container: container,
+		Engine:    container.BuildRouter(o.requestLogger),
+		cfg:       cfg,
+		logger:    o.logger,
+	}
+
+	// Timeouts keep slow clients from holding connections open
+	s.HTTPServer = &http.Server{
+		Addr:              ":" + cfg.Server.Port,
+		Handler:           s.Engine,
+		ReadHeaderTimeout: 15 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+	if cfg.Server.GRPCPort != "" {
+		s.GRPCServer = container.BuildGRPCServer()
+	}
+
+	if !cfg.Server.DisableJobs {
+		s.Scheduler = container.BuildJobs()
+		if cfg.Server.JobWorkers > 0 {
+			s.WorkerPool = container.BuildWorkerPool()
+		}
+	}
+
+	return s, nil
+}
+
+// Start starts the background job scheduler and the queued job workers, unless jobs are
+// disabled. It doesn't serve requests; Run does both.
+func (s *Server) Start() {
+	if s.Scheduler == nil {
+		s.logger.Info().Msg("Background jobs disabled, expecting a separate worker to run them")
+		return
+	}
+	s.logger.Info().Msg("Starting background job scheduler")
+	s.Scheduler.Start()
+	if s.WorkerPool != nil {
+		s.WorkerPool.Start()
+	}
+}
+
+// Run starts the jobs and serves the HTTP API, and the gRPC API if configured, until ctx is
+// done, then shuts down within the configured shutdown timeout. It returns early if a
+// listener fails.
+func (s *Server) Run(ctx context.Context) error {
+	var grpcListener net.Listener
+	if s.GRPCServer != nil {
+		var err error
+		grpcListener, err = net.Listen("tcp", ":"+s.cfg.Server.GRPCPort)
+		if err != nil {
+			return fmt.Errorf("failed to listen for gRPC: %w", err)
+		}
+	}
+
+	s.Start()
+
+	errs := make(chan error, 2)
+	go func() {
+		s.logger.Info().
+			Str("port", s.cfg.Server.Port).
+			Str("environment", s.cfg.Server.Environment).
+			Msg("Server starting")
+		if err := s.HTTPServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("failed to start server: %w", err)
+		}
+	}()
+	if grpcListener != nil {
+		go func() {
+			s.logger.Info().Str("port", s.cfg.Server.GRPCPort).Msg("gRPC server starting")
+			if err := s.GRPCServer.Serve(grpcListener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				errs <- fmt.Errorf("failed to start gRPC server: %w", err)
+			}
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		s.logger.Info().Msg("Shutdown signal received, shutting down server")
+	case runErr = <-errs:
+		s.logger.Error().Err(runErr).Msg("Server stopped")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout())
+	defer cancel()
+	return errors.Join(runErr, s.Shutdown(shutdownCtx))
+}
+
+// ShutdownTimeout returns how long the server waits for requests and jobs to finish when it
+// shuts down
+func (s *Server) ShutdownTimeout() time.Duration {
+	if s.cfg.Server.ShutdownTimeout > 0 {
+		return s.cfg.Server.ShutdownTimeout
+	}
+	return DefaultShutdownTimeout
+}
+
+// Shutdown stops running scheduled jobs, claiming queued jobs and accepting requests, and
+// waits for the jobs and requests in progress to finish, until ctx is done. Jobs and
+// requests still running then are cancelled; the jobs are logged by name or ID, and queued
+// jobs that stop at a checkpoint go back in the queue.
+func (s *Server) Shutdown(ctx context.Context) error {
+	// Jobs finish their current work while the listeners drain
+	var jobs sync.WaitGroup
+	if s.Scheduler != nil {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			s.logger.Info().Msg("Stopping background job scheduler")
+			if err := s.Scheduler.Stop(ctx); err != nil {
+				s.logger.Warn().Err(err).Msg("Scheduled jobs still running at shutdown were cancelled")
+			}
+		}()
+	}
+	if s.WorkerPool != nil {
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			if err := s.WorkerPool.Stop(ctx); err != nil {
+				s.logger.Warn().Err(err).Msg("Queued jobs still running at shutdown were cancelled")
+			}
+		}()
+	}
+
+	if s.GRPCServer != nil {
+		stopped := make(chan struct{})
+		defer close(stopped)
+		go func() {
+			select {
+			case <-ctx.Done():
+				s.GRPCServer.Stop()
+			case <-stopped:
+			}
+		}()
+	}
+
+	err := s.HTTPServer.Shutdown(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Server forced to shutdown")
+	} else {
+		s.logger.Info().Msg("Server shutdown gracefully")
+	}
+	if s.GRPCServer != nil {
+		s.GRPCServer.GracefulStop()
+	}
+
+	jobs.Wait()
+	return err
+}
+
+// Close releases the server's services, such as their cache connections
+func (s *Server) Close() error {
+	return s.Container.Close()
+}
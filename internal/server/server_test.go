@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/database"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			Port:       "0",
+			JobWorkers: 1,
+		},
+		JWT: config.JWTConfig{
+			Secret:              "test-secret-key-for-jwt-signing-32-chars",
+			AccessTokenDuration: 30 * time.Minute,
+		},
+		Security: config.SecurityConfig{
+			RateLimitRequests: 5,
+			RateLimitDuration: time.Minute,
+		},
+		Snapshots: config.SnapshotConfig{
+			DailyRetentionMonths:  24,
+			WeeklyRetentionMonths: 60,
+		},
+		Rounding: config.RoundingConfig{
+			Mode:                 "HALF_UP",
+			EquityQuantityPlaces: 8,
+			CryptoQuantityPlaces: 8,
+			AmountPlaces:         8,
+		},
+		Password: config.PasswordConfig{
+			MinLength:     8,
+			HashAlgorithm: "bcrypt",
+			BcryptCost:    4,
+		},
+	}
+}
+
+func newTestServer(t *testing.T, cfg *config.Config) *Server {
+	gin.SetMode(gin.TestMode)
+	db, err := database.ConnectWithLogger("sqlite://:memory:", gormlogger.Discard)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	s, err := New(cfg, db)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func TestNew(t *testing.T) {
+	s := newTestServer(t, testConfig())
+
+	assert.NotNil(t, s.Scheduler)
+	assert.NotNil(t, s.WorkerPool)
+	assert.Nil(t, s.GRPCServer)
+	assert.Equal(t, ":0", s.HTTPServer.Addr)
+
+	w := httptest.NewRecorder()
+	s.Engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNew_JobsDisabled(t *testing.T) {
+	cfg := testConfig()
+	cfg.Server.DisableJobs = true
+	cfg.Server.GRPCPort = "0"
+	s := newTestServer(t, cfg)
+
+	assert.Nil(t, s.Scheduler)
+	assert.Nil(t, s.WorkerPool)
+	assert.NotNil(t, s.GRPCServer)
+
+	// Starting without jobs does nothing
+	s.Start()
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	cfg := testConfig()
+	cfg.Rounding.Mode = "SIDEWAYS"
+
+	_, err := New(cfg, nil)
+	assert.ErrorContains(t, err, "failed to initialize services")
+}
+
+func TestServer_Run(t *testing.T) {
+	s := newTestServer(t, testConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Run(ctx) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(2 * s.ShutdownTimeout()):
+		t.Fatal("Run didn't return after its context was done")
+	}
+}
+
+func TestServer_ShutdownTimeout(t *testing.T) {
+	cfg := testConfig()
+	s := newTestServer(t, cfg)
+	assert.Equal(t, DefaultShutdownTimeout, s.ShutdownTimeout())
+
+	cfg.Server.ShutdownTimeout = 2 * time.Minute
+	assert.Equal(t, 2*time.Minute, s.ShutdownTimeout())
+}
+
+func TestServer_Run_ListenFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	cfg := testConfig()
+	cfg.Server.DisableJobs = true
+	s := newTestServer(t, cfg)
+	s.HTTPServer.Addr = listener.Addr().String()
+
+	err = s.Run(context.Background())
+	assert.ErrorContains(t, err, "failed to start server")
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/utils"
+)
+
+// apiKeySecretLength is the number of random bytes in an API key, hex encoded after the prefix
+const apiKeySecretLength = 32
+
+// OrganizationQuotas are the limits an administrator sets on an organization. Zero means unlimited.
+type OrganizationQuotas struct {
+	MaxUsers             int
+	MaxPortfoliosPerUser int
+	MaxAPIKeys           int
+}
+
+// ProvisionedUser is the outcome of provisioning an organization member
+type ProvisionedUser struct {
+	User *models.User
+	// Created is true if the user did not exist before this request
+	Created bool
+	// InviteExpiresAt is set when an invite to choose a password was issued
+	InviteExpiresAt *time.Time
+	// InviteSent is false if the invite email could not be delivered
+	InviteSent bool
+}
+
+// IssuedAPIKey is the outcome of provisioning an API key
+type IssuedAPIKey struct {
+	Key *models.APIKey
+	// Created is true if a new secret was issued by this request
+	Created bool
+	// Secret is the plaintext key. It is only set when Created is true and cannot be retrieved later.
+	Secret string
+}
+
+// AdminProvisioningService defines the interface for declarative organization, user and API
+// key provisioning. Every operation is keyed by the caller's external IDs and can be repeated
+// safely: applying the same request twice leaves the same state.
+type AdminProvisioningService interface {
+	UpsertOrganization(externalID, name string) (*models.Organization, bool, error)
+	GetOrganization(externalID string) (*models.Organization, error)
+	SetQuotas(externalID string, quotas OrganizationQuotas) (*models.Organization, error)
+	UpsertUser(organizationExternalID, userExternalID, email string) (*ProvisionedUser, error)
+	GetUser(organizationExternalID, userExternalID string) (*models.User, error)
+	UpsertAPIKey(organizationExternalID, keyExternalID, userExternalID, name string) (*IssuedAPIKey, error)
+	GetAPIKey(organizationExternalID, keyExternalID string) (*models.APIKey, error)
+	RevokeAPIKey(organizationExternalID, keyExternalID string) error
+}
+
+// TenantSchemaProvisioner creates or migrates the Postgres schema holding an organization's
+// portfolio data. It must be safe to call again for a schema that is already up to date.
+type TenantSchemaProvisioner func(ctx context.Context, schema string) error
+
+// adminProvisioningService implements AdminProvisioningService interface
+type adminProvisioningService struct {
+	db              *gorm.DB
+	emailService    EmailService
+	inviteValidity  time.Duration
+	provisionSchema TenantSchemaProvisioner
+	now             func() time.Time
+}
+
+// NewAdminProvisioningService creates a new AdminProvisioningService instance
+func NewAdminProvisioningService(db *gorm.DB, emailService EmailService, inviteValidity time.Duration) AdminProvisioningService {
+	return &adminProvisioningService{
+		db:             db,
+		emailService:   emailService,
+		inviteValidity: inviteValidity,
+		now:            func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// NewAdminProvisioningServiceWithTenantSchemas creates an AdminProvisioningService that gives
+// each new organization its own Postgres schema, created with provisionSchema. Organizations
+// created before multi-schema mode was enabled keep using the shared tables.
+func NewAdminProvisioningServiceWithTenantSchemas(
+	db *gorm.DB,
+	emailService EmailService,
+	inviteValidity time.Duration,
+	provisionSchema TenantSchemaProvisioner,
+) AdminProvisioningService {
+	return &adminProvisioningService{
+		db:              db,
+		emailService:    emailService,
+		inviteValidity:  inviteValidity,
+		provisionSchema: provisionSchema,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// UpsertOrganization creates an organization or renames an existing one. It reports whether
+// the organization was created.
+func (s *adminProvisioningService) UpsertOrganization(externalID, name string) (*models.Organization, bool, error) {
+	if !models.IsValidExternalID(externalID) {
+		return nil, false, models.ErrInvalidExternalID
+	}
+
+	var organization *models.Organization
+	created := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		orgRepo := repository.NewOrganizationRepository(tx)
+
+		var err error
+		organization, err = orgRepo.FindByExternalID(externalID)
+		if err != nil {
+			if !errors.Is(err, models.ErrOrganizationNotFound) {
+				return err
+			}
+			organization = &models.Organization{ExternalID: externalID}
+			if s.provisionSchema != nil {
+				organization.ID = uuid.New()
+				schema := models.TenantSchemaName(organization.ID)
+				organization.SchemaName = &schema
+			}
+			created = true
+		}
+
+		organization.Name = strings.TrimSpace(name)
+		if err := organization.Validate(); err != nil {
+			return err
+		}
+
+		return orgRepo.Save(organization)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// The schema is created after the organization is committed, and on every later upsert,
+	// so that repeating the request finishes a schema whose creation failed
+	if s.provisionSchema != nil && organization.SchemaName != nil {
+		if err := s.provisionSchema(context.Background(), *organization.SchemaName); err != nil {
+			return nil, false, fmt.Errorf("failed to provision organization schema: %w", err)
+		}
+	}
+
+	return organization, created, nil
+}
+
+// GetOrganization retrieves an organization by external ID
+func (s *adminProvisioningService) GetOrganization(externalID string) (*models.Organization, error) {
+	return repository.NewOrganizationRepository(s.db).FindByExternalID(externalID)
+}
+
+// SetQuotas replaces an organization's quotas. Lowering a quota below current usage is
+// allowed; it only prevents further growth.
+func (s *adminProvisioningService) SetQuotas(externalID string, quotas OrganizationQuotas) (*models.Organization, error) {
+	orgRepo := repository.NewOrganizationRepository(s.db)
+
+	organization, err := orgRepo.FindByExternalID(externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	organization.MaxUsers = quotas.MaxUsers
+	organization.MaxPortfoliosPerUser = quotas.MaxPortfoliosPerUser
+	organization.MaxAPIKeys = quotas.MaxAPIKeys
+	if err := organization.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := orgRepo.Save(organization); err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+// UpsertUser creates an organization member and invites them to choose a password, or updates
+// an existing member's email. New members join with the member role. Existing members are
+// not re-invited.
+func (s *adminProvisioningService) UpsertUser(organizationExternalID, userExternalID, email string) (*ProvisionedUser, error) {
+	if !models.IsValidExternalID(userExternalID) {
+		return nil, models.ErrInvalidExternalID
+	}
+	email = strings.TrimSpace(email)
+
+	var organization *models.Organization
+	var inviteToken string
+	result := &ProvisionedUser{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		orgRepo := repository.NewOrganizationRepository(tx)
+		userRepo := repository.NewUserRepository(tx)
+
+		var err error
+		organization, err = orgRepo.FindByExternalID(organizationExternalID)
+		if err != nil {
+			return err
+		}
+
+		user, err := orgRepo.FindMember(organization.ID, userExternalID)
+		if err != nil && !errors.Is(err, models.ErrUserNotFound) {
+			return err
+		}
+
+		// The email must not belong to anyone else
+		if existing, err := userRepo.FindByEmail(email); err == nil && (user == nil || existing.ID != user.ID) {
+			return models.ErrEmailAlreadyExists
+		}
+
+		if user != nil {
+			user.Email = email
+			result.User = user
+			return orgRepo.SaveMember(user)
+		}
+
+		count, err := orgRepo.CountMembers(organization.ID)
+		if err != nil {
+			return err
+		}
+		if organization.UserQuotaReached(count) {
+			return models.ErrUserQuotaExceeded
+		}
+
+		user, err = s.newInvitedUser(organization, userExternalID, email)
+		if err != nil {
+			return err
+		}
+		if err := orgRepo.SaveMember(user); err != nil {
+			return err
+		}
+		membership := &models.OrganizationMember{OrganizationID: organization.ID, UserID: user.ID, Role: models.OrgRoleMember}
+		if err := repository.NewOrganizationMemberRepository(tx).Save(context.Background(), membership); err != nil {
+			return err
+		}
+
+		inviteToken, err = generateSecret(TokenLength)
+		if err != nil {
+			return fmt.Errorf("failed to generate invite token: %w", err)
+		}
+		expiresAt := s.now().Add(s.inviteValidity)
+		invite := &models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hashResetToken(inviteToken),
+			ExpiresAt: expiresAt,
+		}
+		if err := repository.NewPasswordResetRepository(tx).Create(invite); err != nil {
+			return err
+		}
+
+		result.User = user
+		result.Created = true
+		result.InviteExpiresAt = &expiresAt
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Deliver the invite only once the user is committed. A delivery failure is reported
+	// rather than returned so that retrying the request does not create a second user.
+	if result.Created {
+		err := s.emailService.SendInviteEmail(result.User.Email, organization.Name, inviteToken, *result.InviteExpiresAt)
+		result.InviteSent = err == nil
+	}
+
+	return result, nil
+}
+
+// newInvitedUser builds an organization member whose password is unusable until they accept
+// their invite
+func (s *adminProvisioningService) newInvitedUser(organization *models.Organization, externalID, email string) (*models.User, error) {
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+
+	placeholder, err := generateSecret(TokenLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate placeholder password: %w", err)
+	}
+	passwordHash, err := utils.HashPassword(placeholder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash placeholder password: %w", err)
+	}
+
+	organizationID := organization.ID
+	return &models.User{
+		Email:          email,
+		PasswordHash:   passwordHash,
+		OrganizationID: &organizationID,
+		ExternalID:     &externalID,
+	}, nil
+}
+
+// GetUser retrieves an organization member by external IDs
+func (s *adminProvisioningService) GetUser(organizationExternalID, userExternalID string) (*models.User, error) {
+	orgRepo := repository.NewOrganizationRepository(s.db)
+
+	organization, err := orgRepo.FindByExternalID(organizationExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return orgRepo.FindMember(organization.ID, userExternalID)
+}
+
+// UpsertAPIKey issues an API key acting as an organization member, or renames an existing
+// key. A revoked key is reissued with a new secret. The key's owner cannot be changed.
+func (s *adminProvisioningService) UpsertAPIKey(organizationExternalID, keyExternalID, userExternalID, name string) (*IssuedAPIKey, error) {
+	if !models.IsValidExternalID(keyExternalID) {
+		return nil, models.ErrInvalidExternalID
+	}
+
+	result := &IssuedAPIKey{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		orgRepo := repository.NewOrganizationRepository(tx)
+		keyRepo := repository.NewAPIKeyRepository(tx)
+
+		organization, err := orgRepo.FindByExternalID(organizationExternalID)
+		if err != nil {
+			return err
+		}
+
+		owner, err := orgRepo.FindMember(organization.ID, userExternalID)
+		if err != nil {
+			return err
+		}
+
+		key, err := keyRepo.FindByExternalID(organization.ID, keyExternalID)
+		if err != nil && !errors.Is(err, models.ErrAPIKeyNotFound) {
+			return err
+		}
+		if key != nil && key.UserID != owner.ID {
+			return models.ErrAPIKeyOwnerChanged
+		}
+
+		if key == nil || !key.IsActive() {
+			count, err := keyRepo.CountActive(organization.ID)
+			if err != nil {
+				return err
+			}
+			if organization.APIKeyQuotaReached(count) {
+				return models.ErrAPIKeyQuotaExceeded
+			}
+
+			if key == nil {
+				key = &models.APIKey{OrganizationID: organization.ID, ExternalID: keyExternalID, UserID: owner.ID, User: owner}
+			}
+			secret, err := generateSecret(apiKeySecretLength)
+			if err != nil {
+				return fmt.Errorf("failed to generate API key: %w", err)
+			}
+			result.Secret = models.APIKeyPrefix + secret
+			result.Created = true
+			key.Prefix = result.Secret[:models.APIKeyDisplayLength]
+			key.KeyHash = hashResetToken(result.Secret)
+			key.RevokedAt = nil
+			key.LastUsedAt = nil
+		}
+
+		key.Name = strings.TrimSpace(name)
+		if key.Name == "" {
+			key.Name = keyExternalID
+		}
+		if err := key.Validate(); err != nil {
+			return err
+		}
+
+		result.Key = key
+		return keyRepo.Save(key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetAPIKey retrieves an organization's API key by external IDs
+func (s *adminProvisioningService) GetAPIKey(organizationExternalID, keyExternalID string) (*models.APIKey, error) {
+	organization, err := repository.NewOrganizationRepository(s.db).FindByExternalID(organizationExternalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return repository.NewAPIKeyRepository(s.db).FindByExternalID(organization.ID, keyExternalID)
+}
+
+// RevokeAPIKey revokes an API key. Revoking a key that is already revoked or does not exist
+// succeeds, so that removing a key from declarative configuration converges.
+func (s *adminProvisioningService) RevokeAPIKey(organizationExternalID, keyExternalID string) error {
+	organization, err := repository.NewOrganizationRepository(s.db).FindByExternalID(organizationExternalID)
+	if err != nil {
+		return err
+	}
+
+	keyRepo := repository.NewAPIKeyRepository(s.db)
+	key, err := keyRepo.FindByExternalID(organization.ID, keyExternalID)
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !key.IsActive() {
+		return nil
+	}
+
+	revokedAt := s.now()
+	key.RevokedAt = &revokedAt
+	return keyRepo.Save(key)
+}
+
+// generateSecret returns length cryptographically random bytes, hex encoded
+func generateSecret(length int) (string, error) {
+	secret := make([]byte, length)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/utils"
+)
+
+func setupAdminProvisioningTest(t *testing.T) (*gorm.DB, AdminProvisioningService, *mockEmailService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.PasswordResetToken{}, &models.Organization{}, &models.APIKey{},
+		&models.OrganizationMember{},
+	))
+
+	emailService := newMockEmailService()
+	return db, NewAdminProvisioningService(db, emailService, 24*time.Hour), emailService
+}
+
+func TestAdminProvisioningService_UpsertOrganization(t *testing.T) {
+	_, service, _ := setupAdminProvisioningTest(t)
+
+	created, wasCreated, err := service.UpsertOrganization("acme", "Acme")
+	require.NoError(t, err)
+	assert.True(t, wasCreated)
+
+	// Repeating the request updates the same organization
+	updated, wasCreated, err := service.UpsertOrganization("acme", "Acme Corp")
+	require.NoError(t, err)
+	assert.False(t, wasCreated)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, "Acme Corp", updated.Name)
+
+	_, _, err = service.UpsertOrganization("not valid", "Acme")
+	assert.Equal(t, models.ErrInvalidExternalID, err)
+
+	_, _, err = service.UpsertOrganization("other", " ")
+	assert.Equal(t, models.ErrOrganizationNameRequired, err)
+
+	_, err = service.SetQuotas("acme", OrganizationQuotas{MaxUsers: -1})
+	assert.Equal(t, models.ErrInvalidQuota, err)
+
+	_, err = service.SetQuotas("missing", OrganizationQuotas{})
+	assert.Equal(t, models.ErrOrganizationNotFound, err)
+}
+
+func TestAdminProvisioningService_UpsertOrganization_TenantSchemas(t *testing.T) {
+	db, _, emailService := setupAdminProvisioningTest(t)
+
+	var provisioned []string
+	failing := true
+	service := NewAdminProvisioningServiceWithTenantSchemas(db, emailService, 24*time.Hour,
+		func(ctx context.Context, schema string) error {
+			provisioned = append(provisioned, schema)
+			if failing {
+				return assert.AnError
+			}
+			return nil
+		})
+
+	// A failed schema is retried by repeating the request
+	_, _, err := service.UpsertOrganization("acme", "Acme")
+	assert.ErrorIs(t, err, assert.AnError)
+
+	failing = false
+	organization, wasCreated, err := service.UpsertOrganization("acme", "Acme")
+	require.NoError(t, err)
+	assert.False(t, wasCreated)
+	require.NotNil(t, organization.SchemaName)
+	assert.Equal(t, models.TenantSchemaName(organization.ID), *organization.SchemaName)
+	assert.Equal(t, []string{*organization.SchemaName, *organization.SchemaName}, provisioned)
+
+	// Organizations created before multi-schema mode keep the shared tables
+	_, _, err = NewAdminProvisioningService(db, emailService, 24*time.Hour).UpsertOrganization("globex", "Globex")
+	require.NoError(t, err)
+	legacy, _, err := service.UpsertOrganization("globex", "Globex Corp")
+	require.NoError(t, err)
+	assert.Nil(t, legacy.SchemaName)
+	assert.Len(t, provisioned, 2)
+}
+
+func TestAdminProvisioningService_UpsertUser(t *testing.T) {
+	db, service, emailService := setupAdminProvisioningTest(t)
+	_, _, err := service.UpsertOrganization("acme", "Acme")
+	require.NoError(t, err)
+
+	provisioned, err := service.UpsertUser("acme", "jane", "jane@acme.example")
+	require.NoError(t, err)
+	assert.True(t, provisioned.Created)
+	assert.True(t, provisioned.InviteSent)
+	require.NotNil(t, provisioned.InviteExpiresAt)
+	require.Len(t, emailService.sentEmails, 1)
+	assert.Equal(t, "jane@acme.example", emailService.sentEmails[0].to)
+
+	// New users join the organization as members
+	membership, err := repository.NewOrganizationMemberRepository(db).
+		FindMember(context.Background(), *provisioned.User.OrganizationID, provisioned.User.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleMember, membership.Role)
+
+	// The invite token sets the user's password through the password reset flow
+	userRepo := repository.NewUserRepository(db)
+	resetService := NewPasswordResetService(userRepo, repository.NewPasswordResetRepository(db), emailService, time.Hour)
+	require.NoError(t, resetService.ResetPassword(emailService.sentEmails[0].token, "ChosenPass123"))
+	user, err := userRepo.FindByID(provisioned.User.ID.String())
+	require.NoError(t, err)
+	assert.NoError(t, utils.CheckPassword("ChosenPass123", user.PasswordHash))
+
+	// Repeating the request with a new email updates the user without inviting them again
+	updated, err := service.UpsertUser("acme", "jane", "jane.doe@acme.example")
+	require.NoError(t, err)
+	assert.False(t, updated.Created)
+	assert.Equal(t, provisioned.User.ID, updated.User.ID)
+	assert.Equal(t, "jane.doe@acme.example", updated.User.Email)
+	assert.Len(t, emailService.sentEmails, 1)
+
+	fetched, err := service.GetUser("acme", "jane")
+	require.NoError(t, err)
+	assert.Equal(t, "jane.doe@acme.example", fetched.Email)
+
+	// An email can only belong to one user
+	_, err = service.UpsertUser("acme", "john", "jane.doe@acme.example")
+	assert.Equal(t, models.ErrEmailAlreadyExists, err)
+
+	_, err = service.UpsertUser("missing", "john", "john@acme.example")
+	assert.Equal(t, models.ErrOrganizationNotFound, err)
+}
+
+func TestAdminProvisioningService_UpsertUser_InviteFailureStillProvisions(t *testing.T) {
+	_, service, emailService := setupAdminProvisioningTest(t)
+	_, _, err := service.UpsertOrganization("acme", "Acme")
+	require.NoError(t, err)
+	emailService.shouldFail = true
+
+	provisioned, err := service.UpsertUser("acme", "jane", "jane@acme.example")
+	require.NoError(t, err)
+	assert.True(t, provisioned.Created)
+	assert.False(t, provisioned.InviteSent)
+}
+
+func TestAdminProvisioningService_UserQuota(t *testing.T) {
+	_, service, _ := setupAdminProvisioningTest(t)
+	_, _, err := service.UpsertOrganization("acme", "Acme")
+	require.NoError(t, err)
+	_, err = service.SetQuotas("acme", OrganizationQuotas{MaxUsers: 1})
+	require.NoError(t, err)
+
+	_, err = service.UpsertUser("acme", "jane", "jane@acme.example")
+	require.NoError(t, err)
+
+	_, err = service.UpsertUser("acme", "john", "john@acme.example")
+	assert.Equal(t, models.ErrUserQuotaExceeded, err)
+
+	// Updating an existing member is not limited by the quota
+	_, err = service.UpsertUser("acme", "jane", "jane.doe@acme.example")
+	assert.NoError(t, err)
+}
+
+func TestAdminProvisioningService_APIKeys(t *testing.T) {
+	db, service, _ := setupAdminProvisioningTest(t)
+	_, _, err := service.UpsertOrganization("acme", "Acme")
+	require.NoError(t, err)
+	_, err = service.SetQuotas("acme", OrganizationQuotas{MaxAPIKeys: 1})
+	require.NoError(t, err)
+	jane, err := service.UpsertUser("acme", "jane", "jane@acme.example")
+	require.NoError(t, err)
+	_, err = service.UpsertUser("acme", "john", "john@acme.example")
+	require.NoError(t, err)
+
+	issued, err := service.UpsertAPIKey("acme", "ci", "jane", "CI")
+	require.NoError(t, err)
+	assert.True(t, issued.Created)
+	assert.True(t, strings.HasPrefix(issued.Secret, models.APIKeyPrefix))
+	assert.Equal(t, issued.Secret[:models.APIKeyDisplayLength], issued.Key.Prefix)
+	assert.NotContains(t, issued.Key.KeyHash, issued.Secret)
+
+	authenticator := NewAPIKeyService(repository.NewAPIKeyRepository(db))
+	authenticated, err := authenticator.Authenticate(issued.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, jane.User.ID, authenticated.UserID)
+	assert.NotNil(t, authenticated.LastUsedAt)
+
+	// Repeating the request renames the key without issuing a new secret
+	renamed, err := service.UpsertAPIKey("acme", "ci", "jane", "CI runner")
+	require.NoError(t, err)
+	assert.False(t, renamed.Created)
+	assert.Empty(t, renamed.Secret)
+	assert.Equal(t, "CI runner", renamed.Key.Name)
+	_, err = authenticator.Authenticate(issued.Secret)
+	assert.NoError(t, err)
+
+	_, err = service.UpsertAPIKey("acme", "ci", "john", "CI")
+	assert.Equal(t, models.ErrAPIKeyOwnerChanged, err)
+
+	_, err = service.UpsertAPIKey("acme", "deploy", "jane", "")
+	assert.Equal(t, models.ErrAPIKeyQuotaExceeded, err)
+
+	// Revoking is idempotent and frees the quota
+	require.NoError(t, service.RevokeAPIKey("acme", "ci"))
+	require.NoError(t, service.RevokeAPIKey("acme", "ci"))
+	require.NoError(t, service.RevokeAPIKey("acme", "never-issued"))
+	_, err = authenticator.Authenticate(issued.Secret)
+	assert.Equal(t, models.ErrInvalidAPIKey, err)
+
+	deploy, err := service.UpsertAPIKey("acme", "deploy", "jane", "")
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", deploy.Key.Name)
+
+	// A revoked key is reissued with a new secret once there is room
+	require.NoError(t, service.RevokeAPIKey("acme", "deploy"))
+	reissued, err := service.UpsertAPIKey("acme", "ci", "jane", "CI")
+	require.NoError(t, err)
+	assert.True(t, reissued.Created)
+	assert.NotEqual(t, issued.Secret, reissued.Secret)
+	assert.True(t, reissued.Key.IsActive())
+
+	fetched, err := service.GetAPIKey("acme", "ci")
+	require.NoError(t, err)
+	assert.Equal(t, "jane", *fetched.User.ExternalID)
+
+	_, err = authenticator.Authenticate("pfk_unknown")
+	assert.Equal(t, models.ErrInvalidAPIKey, err)
+	_, err = authenticator.Authenticate("not-an-api-key")
+	assert.Equal(t, models.ErrInvalidAPIKey, err)
+}
@@ -0,0 +1,377 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type aliases for dto types for backward compatibility
+type Overview = dto.Overview
+type OverviewPortfolio = dto.OverviewPortfolio
+type OverviewAllocation = dto.OverviewAllocation
+type OverviewHolding = dto.OverviewHolding
+type OverviewPerformance = dto.OverviewPerformance
+
+// DefaultOverviewCurrency is the currency of the overview when none is requested
+const DefaultOverviewCurrency = "USD"
+
+// AggregationService defines the interface for views across several of a user's portfolios
+type AggregationService interface {
+	GetOverview(ctx context.Context, userID, currency string, startDate, endDate time.Time) (*Overview, error)
+	GetPortfoliosOverview(ctx context.Context, userID, currency string, portfolioIDs []uuid.UUID, startDate, endDate time.Time) (*Overview, error)
+}
+
+// aggregationService implements AggregationService interface
+type aggregationService struct {
+	portfolioRepo    repository.PortfolioRepository
+	holdingRepo      repository.HoldingRepository
+	marketData       MarketDataService
+	analyticsService PerformanceAnalyticsService
+	settings         UserSettingsService
+	now              func() time.Time
+}
+
+// NewAggregationService creates a new AggregationService instance. marketData prices holdings
+// and converts currencies, and analyticsService measures performance; either may be nil, in
+// which case holdings are valued at cost basis or the performance is left out.
+func NewAggregationService(
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	marketData MarketDataService,
+	analyticsService PerformanceAnalyticsService,
+) AggregationService {
+	return NewAggregationServiceWithSettings(portfolioRepo, holdingRepo, marketData, analyticsService, nil)
+}
+
+// NewAggregationServiceWithSettings creates an AggregationService whose overviews are in the
+// display currency of the user's settings when no currency is requested
+func NewAggregationServiceWithSettings(
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	marketData MarketDataService,
+	analyticsService PerformanceAnalyticsService,
+	settings UserSettingsService,
+) AggregationService {
+	return &aggregationService{
+		portfolioRepo:    portfolioRepo,
+		holdingRepo:      holdingRepo,
+		marketData:       marketData,
+		analyticsService: analyticsService,
+		settings:         settings,
+		now:              func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// GetOverview aggregates the value, asset allocation, holdings and performance over a period of
+// all of a user's portfolios, in currency. Holdings of the same symbol in different portfolios
+// are merged. A portfolio whose base currency can't be converted is listed but left out of the
+// totals rather than failing the whole overview.
+func (s *aggregationService) GetOverview(
+	ctx context.Context,
+	userID, currency string,
+	startDate, endDate time.Time,
+) (*Overview, error) {
+	return s.aggregate(ctx, userID, currency, nil, startDate, endDate)
+}
+
+// GetPortfoliosOverview aggregates some of a user's portfolios like GetOverview. IDs of
+// portfolios that don't belong to the user are ignored.
+func (s *aggregationService) GetPortfoliosOverview(
+	ctx context.Context,
+	userID, currency string,
+	portfolioIDs []uuid.UUID,
+	startDate, endDate time.Time,
+) (*Overview, error) {
+	if portfolioIDs == nil {
+		portfolioIDs = []uuid.UUID{}
+	}
+	return s.aggregate(ctx, userID, currency, portfolioIDs, startDate, endDate)
+}
+
+// aggregate builds the overview of the user's portfolios in portfolioIDs, or of all of them
+// when portfolioIDs is nil
+func (s *aggregationService) aggregate(
+	ctx context.Context,
+	userID, currency string,
+	portfolioIDs []uuid.UUID,
+	startDate, endDate time.Time,
+) (*Overview, error) {
+	currency, err := displayCurrency(ctx, s.settings, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolios, err := s.portfolioRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve portfolios: %w", err)
+	}
+	if portfolioIDs != nil {
+		portfolios = slices.DeleteFunc(portfolios, func(portfolio *models.Portfolio) bool {
+			return !slices.Contains(portfolioIDs, portfolio.ID)
+		})
+	}
+	sort.Slice(portfolios, func(i, j int) bool {
+		return portfolios[i].Name < portfolios[j].Name
+	})
+
+	overview := &Overview{
+		Currency:    currency,
+		Portfolios:  make([]*OverviewPortfolio, 0, len(portfolios)),
+		Allocation:  []*OverviewAllocation{},
+		Holdings:    []*OverviewHolding{},
+		GeneratedAt: s.now(),
+	}
+
+	var convertedIDs []uuid.UUID
+	rates := make(map[string]decimal.Decimal)
+	for _, portfolio := range portfolios {
+		entry := &OverviewPortfolio{
+			PortfolioID:  portfolio.ID,
+			Name:         portfolio.Name,
+			BaseCurrency: portfolio.BaseCurrency,
+		}
+		overview.Portfolios = append(overview.Portfolios, entry)
+
+		rate, err := s.exchangeRate(ctx, rates, portfolio.BaseCurrency, currency)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			entry.Error = err.Error()
+			continue
+		}
+		entry.ExchangeRate = rate
+		convertedIDs = append(convertedIDs, portfolio.ID)
+	}
+
+	// The holdings of every portfolio are loaded at once, and priced with one quote request
+	holdings, err := s.holdingRepo.FindByPortfolioIDs(ctx, convertedIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve holdings: %w", err)
+	}
+	holdingsByPortfolio := make(map[uuid.UUID][]*models.Holding, len(convertedIDs))
+	symbols := make([]string, 0, len(holdings))
+	for _, holding := range holdings {
+		holdingsByPortfolio[holding.PortfolioID] = append(holdingsByPortfolio[holding.PortfolioID], holding)
+		if !slices.Contains(symbols, holding.Symbol) {
+			symbols = append(symbols, holding.Symbol)
+		}
+	}
+
+	prices := s.prices(ctx, symbols)
+
+	merged := make(map[string]*OverviewHolding)
+	allocation := make(map[models.AssetType]*OverviewAllocation)
+	for _, entry := range overview.Portfolios {
+		for _, holding := range holdingsByPortfolio[entry.PortfolioID] {
+			if holding.Quantity.IsZero() {
+				continue
+			}
+
+			costBasis := holding.CostBasis.Mul(entry.ExchangeRate)
+			marketValue := costBasis
+			price, priced := prices[holding.Symbol]
+			if priced {
+				marketValue = holding.MarketValue(price).Mul(entry.ExchangeRate)
+			}
+			entry.Value = entry.Value.Add(marketValue)
+
+			combined, ok := merged[holding.Symbol]
+			if !ok {
+				combined = &OverviewHolding{
+					Symbol:    holding.Symbol,
+					AssetType: holding.AssetType,
+					Priced:    priced,
+				}
+				merged[holding.Symbol] = combined
+				overview.Holdings = append(overview.Holdings, combined)
+			}
+			combined.Quantity = combined.Quantity.Add(holding.Quantity)
+			combined.CostBasis = combined.CostBasis.Add(costBasis)
+			combined.MarketValue = combined.MarketValue.Add(marketValue)
+			combined.UnrealizedGain = combined.MarketValue.Sub(combined.CostBasis)
+			combined.PortfolioIDs = append(combined.PortfolioIDs, entry.PortfolioID)
+
+			overview.TotalCostBasis = overview.TotalCostBasis.Add(costBasis)
+		}
+		overview.TotalValue = overview.TotalValue.Add(entry.Value)
+	}
+
+	for _, holding := range overview.Holdings {
+		holding.AllocationPct = percentOf(holding.MarketValue, overview.TotalValue)
+
+		assetType := holding.AssetType
+		if assetType == "" {
+			assetType = models.AssetTypeEquity
+		}
+		slice, ok := allocation[assetType]
+		if !ok {
+			slice = &OverviewAllocation{AssetType: assetType}
+			allocation[assetType] = slice
+			overview.Allocation = append(overview.Allocation, slice)
+		}
+		slice.Value = slice.Value.Add(holding.MarketValue)
+		slice.Positions++
+	}
+	for _, slice := range overview.Allocation {
+		slice.AllocationPct = percentOf(slice.Value, overview.TotalValue)
+	}
+	for _, entry := range overview.Portfolios {
+		entry.AllocationPct = percentOf(entry.Value, overview.TotalValue)
+	}
+	overview.TotalUnrealizedGain = overview.TotalValue.Sub(overview.TotalCostBasis)
+	overview.TotalGainPct = percentOf(overview.TotalUnrealizedGain, overview.TotalCostBasis)
+
+	// Largest positions first
+	sort.SliceStable(overview.Holdings, func(i, j int) bool {
+		if !overview.Holdings[i].MarketValue.Equal(overview.Holdings[j].MarketValue) {
+			return overview.Holdings[i].MarketValue.GreaterThan(overview.Holdings[j].MarketValue)
+		}
+		return overview.Holdings[i].Symbol < overview.Holdings[j].Symbol
+	})
+	sort.Slice(overview.Allocation, func(i, j int) bool {
+		return overview.Allocation[i].AssetType < overview.Allocation[j].AssetType
+	})
+
+	if s.analyticsService != nil {
+		performance, err := s.performance(ctx, userID, overview.Portfolios, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		overview.Performance = performance
+	}
+
+	return overview, nil
+}
+
+// displayCurrency returns the requested currency, or the display currency of the user's
+// settings when none is requested. settings may be nil, in which case DefaultOverviewCurrency
+// is used.
+func displayCurrency(ctx context.Context, settings UserSettingsService, userID, currency string) (string, error) {
+	if currency == "" && settings != nil {
+		userSettings, err := settings.Get(ctx, userID)
+		if err != nil {
+			return "", fmt.Errorf("failed to load user settings: %w", err)
+		}
+		currency = userSettings.DisplayCurrency
+	}
+	currency = strings.ToUpper(currency)
+	if currency == "" {
+		currency = DefaultOverviewCurrency
+	}
+	if len(currency) != 3 {
+		return "", models.ErrInvalidCurrency
+	}
+	return currency, nil
+}
+
+// exchangeRate returns the rate converting from into to, remembering the rates already fetched
+func (s *aggregationService) exchangeRate(ctx context.Context, rates map[string]decimal.Decimal, from, to string) (decimal.Decimal, error) {
+	return cachedExchangeRate(ctx, s.marketData, rates, from, to)
+}
+
+// cachedExchangeRate returns the rate converting from into to with marketData, remembering
+// the rates already fetched in rates. marketData may be nil, in which case only same-currency
+// conversions succeed.
+func cachedExchangeRate(
+	ctx context.Context,
+	marketData MarketDataService,
+	rates map[string]decimal.Decimal,
+	from, to string,
+) (decimal.Decimal, error) {
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	if rate, ok := rates[from]; ok {
+		return rate, nil
+	}
+	if marketData == nil {
+		return decimal.Zero, fmt.Errorf("no exchange rate from %s to %s: market data is not available", from, to)
+	}
+
+	rate, err := marketData.GetExchangeRate(ctx, from, to)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("no exchange rate from %s to %s: %w", from, to, err)
+	}
+	rates[from] = rate
+	return rate, nil
+}
+
+// prices returns the latest prices of the symbols that have a quote. Symbols without one are
+// valued at cost basis.
+func (s *aggregationService) prices(ctx context.Context, symbols []string) map[string]decimal.Decimal {
+	prices := make(map[string]decimal.Decimal)
+	if s.marketData == nil || len(symbols) == 0 {
+		return prices
+	}
+
+	quotes, err := s.marketData.GetQuotes(ctx, symbols)
+	if err != nil {
+		return prices
+	}
+	for symbol, quote := range quotes {
+		prices[symbol] = quote.Price
+	}
+	return prices
+}
+
+// performance adds up the performance of the portfolios in the overview's currency. A portfolio
+// without enough performance history for the period is left out and noted on its entry.
+func (s *aggregationService) performance(
+	ctx context.Context,
+	userID string,
+	portfolios []*OverviewPortfolio,
+	startDate, endDate time.Time,
+) (*OverviewPerformance, error) {
+	result := &OverviewPerformance{
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	for _, entry := range portfolios {
+		if entry.Error != "" {
+			continue
+		}
+
+		metrics, err := s.analyticsService.GetPerformanceMetrics(ctx, entry.PortfolioID.String(), userID, startDate, endDate)
+		if err != nil {
+			// The request ending is not a problem with the portfolio
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			entry.PerformanceError = err.Error()
+			continue
+		}
+
+		rate := entry.ExchangeRate
+		result.Portfolios++
+		result.StartingValue = result.StartingValue.Add(metrics.StartingValue.Mul(rate))
+		result.EndingValue = result.EndingValue.Add(metrics.EndingValue.Mul(rate))
+		result.TotalReturn = result.TotalReturn.Add(metrics.TotalReturn.Mul(rate))
+		result.TotalDeposits = result.TotalDeposits.Add(metrics.TotalDeposits.Mul(rate))
+		result.TotalWithdrawals = result.TotalWithdrawals.Add(metrics.TotalWithdrawals.Mul(rate))
+		result.NetCashFlow = result.NetCashFlow.Add(metrics.NetCashFlow.Mul(rate))
+	}
+	result.TotalReturnPct = percentOf(result.TotalReturn, result.StartingValue)
+
+	return result, nil
+}
+
+// percentOf returns part as a percentage of whole, or zero when whole is zero
+func percentOf(part, whole decimal.Decimal) decimal.Decimal {
+	if whole.IsZero() {
+		return decimal.Zero
+	}
+	return part.Div(whole).Mul(decimal.NewFromInt(100))
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupAggregationTest(t *testing.T, marketData MarketDataService) (*gorm.DB, *models.User, AggregationService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Holding{}))
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	service := NewAggregationService(repository.NewPortfolioRepository(db), repository.NewHoldingRepository(db), marketData, nil)
+	return db, user, service
+}
+
+func createAggregationPortfolio(t *testing.T, db *gorm.DB, userID uuid.UUID, name, currency string, holdings map[string]int64) *models.Portfolio {
+	portfolio := &models.Portfolio{UserID: userID, Name: name, BaseCurrency: currency, CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	for symbol, quantity := range holdings {
+		assetType := models.AssetTypeEquity
+		if symbol == "BTC-USD" {
+			assetType = models.AssetTypeCrypto
+		}
+		require.NoError(t, db.Create(&models.Holding{
+			PortfolioID:  portfolio.ID,
+			Symbol:       symbol,
+			AssetType:    assetType,
+			Quantity:     decimal.NewFromInt(quantity),
+			CostBasis:    decimal.NewFromInt(quantity * 100),
+			AvgCostPrice: decimal.NewFromInt(100),
+		}).Error)
+	}
+	return portfolio
+}
+
+func TestAggregationService_GetOverview(t *testing.T) {
+	marketData := new(MockMarketDataService)
+	db, user, service := setupAggregationTest(t, marketData)
+	ctx := context.Background()
+
+	createAggregationPortfolio(t, db, user.ID, "Brokerage", "USD", map[string]int64{"AAPL": 10, "BTC-USD": 1})
+	createAggregationPortfolio(t, db, user.ID, "Retirement", "USD", map[string]int64{"AAPL": 5, "MSFT": 2})
+	euro := createAggregationPortfolio(t, db, user.ID, "Euro", "EUR", map[string]int64{"SAP": 10})
+
+	marketData.On("GetExchangeRate", "EUR", "USD").Return(decimal.NewFromInt(2), nil)
+	marketData.On("GetQuotes", mock.Anything).Return(map[string]*Quote{
+		"AAPL":    {Symbol: "AAPL", Price: decimal.NewFromInt(200)},
+		"BTC-USD": {Symbol: "BTC-USD", Price: decimal.NewFromInt(1000)},
+		"SAP":     {Symbol: "SAP", Price: decimal.NewFromInt(150)},
+	}, nil)
+
+	overview, err := service.GetOverview(ctx, user.ID.String(), "usd", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "USD", overview.Currency)
+	assert.Nil(t, overview.Performance)
+
+	// AAPL 15 x 200 + BTC 1000 + MSFT at cost 200 + SAP 10 x 150 x 2
+	assert.True(t, overview.TotalValue.Equal(decimal.NewFromInt(7200)), overview.TotalValue.String())
+	assert.True(t, overview.TotalCostBasis.Equal(decimal.NewFromInt(3800)), overview.TotalCostBasis.String())
+
+	require.Len(t, overview.Holdings, 4)
+	apple := overview.Holdings[0]
+	assert.Equal(t, "AAPL", apple.Symbol)
+	assert.True(t, apple.Quantity.Equal(decimal.NewFromInt(15)))
+	assert.True(t, apple.MarketValue.Equal(decimal.NewFromInt(3000)))
+	assert.Len(t, apple.PortfolioIDs, 2)
+	assert.Equal(t, "MSFT", overview.Holdings[3].Symbol)
+	assert.False(t, overview.Holdings[3].Priced)
+
+	require.Len(t, overview.Allocation, 2)
+	assert.Equal(t, models.AssetTypeCrypto, overview.Allocation[0].AssetType)
+	assert.True(t, overview.Allocation[0].Value.Equal(decimal.NewFromInt(1000)))
+	assert.Equal(t, 3, overview.Allocation[1].Positions)
+
+	require.Len(t, overview.Portfolios, 3)
+	assert.Equal(t, euro.ID, overview.Portfolios[1].PortfolioID)
+	assert.True(t, overview.Portfolios[1].Value.Equal(decimal.NewFromInt(3000)))
+}
+
+func TestAggregationService_GetOverview_MissingExchangeRate(t *testing.T) {
+	marketData := new(MockMarketDataService)
+	db, user, service := setupAggregationTest(t, marketData)
+
+	createAggregationPortfolio(t, db, user.ID, "Brokerage", "USD", map[string]int64{"AAPL": 10})
+	createAggregationPortfolio(t, db, user.ID, "Euro", "EUR", map[string]int64{"SAP": 10})
+
+	marketData.On("GetExchangeRate", "EUR", "USD").Return(decimal.Zero, errors.New("rate unavailable"))
+	marketData.On("GetQuotes", []string{"AAPL"}).Return(nil, errors.New("quota exceeded"))
+
+	overview, err := service.GetOverview(context.Background(), user.ID.String(), "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	// The euro portfolio is left out, and AAPL falls back to its cost basis
+	assert.True(t, overview.TotalValue.Equal(decimal.NewFromInt(1000)), overview.TotalValue.String())
+	assert.NotEmpty(t, overview.Portfolios[1].Error)
+	require.Len(t, overview.Holdings, 1)
+	assert.False(t, overview.Holdings[0].Priced)
+}
+
+func TestAggregationService_GetOverview_InvalidCurrency(t *testing.T) {
+	_, user, service := setupAggregationTest(t, nil)
+
+	_, err := service.GetOverview(context.Background(), user.ID.String(), "DOLLARS", time.Time{}, time.Time{})
+	assert.Equal(t, models.ErrInvalidCurrency, err)
+}
+
+// BenchmarkAggregationService_GetOverview measures the overview of a user with 20 portfolios
+// of 10 holdings and a year of weekly snapshots, reporting the database queries each takes
+func BenchmarkAggregationService_GetOverview(b *testing.B) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	require.NoError(b, err)
+	require.NoError(b, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Holding{}, &models.Transaction{}, &models.PerformanceSnapshot{}))
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(b, db.Create(user).Error)
+
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(1, 0, 0)
+	for i := 0; i < 20; i++ {
+		portfolio := &models.Portfolio{UserID: user.ID, Name: fmt.Sprintf("Portfolio %02d", i), BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+		require.NoError(b, db.Create(portfolio).Error)
+		for j := 0; j < 10; j++ {
+			require.NoError(b, db.Create(&models.Holding{
+				PortfolioID: portfolio.ID,
+				Symbol:      fmt.Sprintf("SYM%d", j),
+				Quantity:    decimal.NewFromInt(10),
+				CostBasis:   decimal.NewFromInt(1000),
+			}).Error)
+		}
+		for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 7) {
+			require.NoError(b, db.Create(&models.PerformanceSnapshot{
+				PortfolioID: portfolio.ID,
+				Date:        date,
+				TotalValue:  decimal.NewFromInt(int64(10000 + date.YearDay())),
+			}).Error)
+		}
+	}
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	analytics := NewPerformanceAnalyticsService(portfolioRepo, repository.NewTransactionRepository(db), repository.NewPerformanceSnapshotRepository(db), nil)
+	service := NewAggregationService(portfolioRepo, repository.NewHoldingRepository(db), nil, analytics)
+
+	var queries int
+	require.NoError(b, db.Callback().Query().After("gorm:query").Register("count_queries", func(*gorm.DB) { queries++ }))
+
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.GetOverview(ctx, user.ID.String(), "USD", startDate, endDate); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(queries)/float64(b.N), "queries/op")
+}
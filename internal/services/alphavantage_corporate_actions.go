@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// GetSplits retrieves the split history for a symbol from Alpha Vantage.
+// The returned actions are not persisted.
+func (p *AlphaVantageProvider) GetSplits(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	var result struct {
+		Data []struct {
+			EffectiveDate string `json:"effective_date"`
+			SplitFactor   string `json:"split_factor"`
+		} `json:"data"`
+	}
+	if err := p.fetchCorporateActionData(ctx, "SPLITS", symbol, &result); err != nil {
+		return nil, err
+	}
+
+	actions := make([]*models.CorporateAction, 0, len(result.Data))
+	for _, data := range result.Data {
+		date, err := time.Parse("2006-01-02", data.EffectiveDate)
+		if err != nil {
+			continue
+		}
+		ratio, err := decimal.NewFromString(data.SplitFactor)
+		if err != nil || !ratio.IsPositive() {
+			continue
+		}
+
+		actions = append(actions, &models.CorporateAction{
+			Symbol:      symbol,
+			Type:        models.CorporateActionTypeSplit,
+			Date:        date,
+			Ratio:       &ratio,
+			Description: fmt.Sprintf("%s split %s (Alpha Vantage)", symbol, ratio.String()),
+		})
+	}
+
+	return actions, nil
+}
+
+// GetDividends retrieves declared and historical cash dividends for a symbol from
+// Alpha Vantage, dated by ex-dividend date. The returned actions are not persisted.
+func (p *AlphaVantageProvider) GetDividends(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	var result struct {
+		Data []struct {
+			ExDividendDate string `json:"ex_dividend_date"`
+			PaymentDate    string `json:"payment_date"`
+			Amount         string `json:"amount"`
+		} `json:"data"`
+	}
+	if err := p.fetchCorporateActionData(ctx, "DIVIDENDS", symbol, &result); err != nil {
+		return nil, err
+	}
+
+	actions := make([]*models.CorporateAction, 0, len(result.Data))
+	for _, data := range result.Data {
+		date, err := time.Parse("2006-01-02", data.ExDividendDate)
+		if err != nil {
+			continue
+		}
+		amount, err := decimal.NewFromString(data.Amount)
+		if err != nil || !amount.IsPositive() {
+			continue
+		}
+
+		description := fmt.Sprintf("%s dividend of %s per share (Alpha Vantage)", symbol, amount.String())
+		if data.PaymentDate != "" && data.PaymentDate != "None" {
+			description = fmt.Sprintf("%s dividend of %s per share, payable %s (Alpha Vantage)",
+				symbol, amount.String(), data.PaymentDate)
+		}
+
+		actions = append(actions, &models.CorporateAction{
+			Symbol:      symbol,
+			Type:        models.CorporateActionTypeDividend,
+			Date:        date,
+			Amount:      &amount,
+			Description: description,
+		})
+	}
+
+	return actions, nil
+}
+
+// fetchCorporateActionData calls a corporate action endpoint and decodes the response into out
+func (p *AlphaVantageProvider) fetchCorporateActionData(ctx context.Context, function, symbol string, out interface{}) error {
+	return p.fetchFunction(ctx, function, url.Values{"symbol": {symbol}}, out)
+}
+
+// fetchFunction calls an Alpha Vantage function with params and decodes its JSON response
+// into out
+func (p *AlphaVantageProvider) fetchFunction(ctx context.Context, function string, params url.Values, out interface{}) error {
+	if !p.IsAvailable() {
+		return fmt.Errorf("alpha Vantage API key not configured")
+	}
+
+	params.Set("function", function)
+	params.Set("apikey", p.apiKey)
+
+	reqURL := fmt.Sprintf("%s?%s", alphaVantageBaseURL, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", strings.ToLower(function), err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Errors and rate limit notices come back as 200 responses with a message field
+	var status struct {
+		ErrorMessage string `json:"Error Message"`
+		Note         string `json:"Note"`
+		Information  string `json:"Information"`
+	}
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if status.ErrorMessage != "" {
+		return fmt.Errorf("API error: %s", status.ErrorMessage)
+	}
+	if status.Note != "" || status.Information != "" {
+		return fmt.Errorf("API rate limit exceeded: %s%s: %w", status.Note, status.Information, models.ErrQuotaExceeded)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+	// Alpha Vantage reports intraday bars in US/Eastern time, which slim images don't ship
+	_ "time/tzdata"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// alphaVantageIntervals are the TIME_SERIES_INTRADAY intervals of each intraday resolution
+var alphaVantageIntervals = map[models.PriceResolution]string{
+	models.PriceResolution1Min:  "1min",
+	models.PriceResolution5Min:  "5min",
+	models.PriceResolution1Hour: "60min",
+}
+
+// GetIntradayPrices retrieves intraday bars for a symbol from Alpha Vantage. The full series
+// covers the last 30 days or so; bars outside startDate and endDate are dropped.
+func (p *AlphaVantageProvider) GetIntradayPrices(ctx context.Context, symbol string, resolution models.PriceResolution, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+	interval, ok := alphaVantageIntervals[resolution]
+	if !ok {
+		return nil, models.ErrInvalidPriceResolution
+	}
+
+	// The series sits under a key naming its interval, e.g. "Time Series (5min)"
+	var result map[string]json.RawMessage
+	params := url.Values{
+		"symbol":     {symbol},
+		"interval":   {interval},
+		"outputsize": {"full"},
+	}
+	if err := p.fetchFunction(ctx, "TIME_SERIES_INTRADAY", params, &result); err != nil {
+		return nil, err
+	}
+
+	var meta struct {
+		TimeZone string `json:"6. Time Zone"`
+	}
+	if data, ok := result["Meta Data"]; ok {
+		_ = json.Unmarshal(data, &meta)
+	}
+	location := time.UTC
+	if meta.TimeZone != "" {
+		if loc, err := time.LoadLocation(meta.TimeZone); err == nil {
+			location = loc
+		}
+	}
+
+	var series map[string]struct {
+		Open   string `json:"1. open"`
+		High   string `json:"2. high"`
+		Low    string `json:"3. low"`
+		Close  string `json:"4. close"`
+		Volume string `json:"5. volume"`
+	}
+	data, ok := result["Time Series ("+interval+")"]
+	if !ok {
+		return nil, fmt.Errorf("no intraday data found for symbol %s", symbol)
+	}
+	if err := json.Unmarshal(data, &series); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	prices := make([]*HistoricalPrice, 0, len(series))
+	for timestamp, bar := range series {
+		at, err := time.ParseInLocation("2006-01-02 15:04:05", timestamp, location)
+		if err != nil {
+			continue
+		}
+		at = at.UTC()
+		if at.Before(startDate) || at.After(endDate) {
+			continue
+		}
+
+		price := &HistoricalPrice{Date: at}
+		price.Open, _ = decimal.NewFromString(bar.Open)
+		price.High, _ = decimal.NewFromString(bar.High)
+		price.Low, _ = decimal.NewFromString(bar.Low)
+		price.Close, _ = decimal.NewFromString(bar.Close)
+		price.Volume, _ = strconv.ParseInt(bar.Volume, 10, 64)
+		prices = append(prices, price)
+	}
+
+	return prices, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// GetOptionQuote retrieves the latest end-of-day price of an option contract, given by its
+// OCC symbol, from Alpha Vantage's option chain for the underlying. The price is the mark
+// (the bid/ask midpoint), or the last trade when no mark is quoted.
+func (p *AlphaVantageProvider) GetOptionQuote(ctx context.Context, symbol string) (*Quote, error) {
+	contract, err := models.ParseOptionSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data []struct {
+			ContractID string `json:"contractID"`
+			Date       string `json:"date"`
+			Last       string `json:"last"`
+			Mark       string `json:"mark"`
+			Volume     string `json:"volume"`
+		} `json:"data"`
+	}
+	if err := p.fetchCorporateActionData(ctx, "HISTORICAL_OPTIONS", contract.Underlying, &result); err != nil {
+		return nil, err
+	}
+
+	for _, data := range result.Data {
+		if data.ContractID != symbol {
+			continue
+		}
+
+		price, err := decimal.NewFromString(data.Mark)
+		if err != nil || !price.IsPositive() {
+			price, err = decimal.NewFromString(data.Last)
+			if err != nil {
+				return nil, fmt.Errorf("no price quoted for option %s", symbol)
+			}
+		}
+
+		quote := &Quote{
+			Symbol:      symbol,
+			Price:       price,
+			LastUpdated: time.Now(),
+		}
+		if volume, err := strconv.ParseInt(data.Volume, 10, 64); err == nil {
+			quote.Volume = volume
+		}
+		if date, err := time.Parse("2006-01-02", data.Date); err == nil {
+			quote.LatestTradingDay = date
+		}
+		return quote, nil
+	}
+
+	return nil, fmt.Errorf("no data found for option %s", symbol)
+}
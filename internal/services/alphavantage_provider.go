@@ -144,6 +144,10 @@ func (p *AlphaVantageProvider) GetQuote(ctx context.Context, symbol string) (*Qu
 	if volume, err := strconv.ParseInt(result.GlobalQuote.Volume, 10, 64); err == nil {
 		quote.Volume = volume
 	}
+	if latestTradingDay, err := time.Parse("2006-01-02", result.GlobalQuote.LatestTradingDay); err == nil {
+		quote.LatestTradingDay = latestTradingDay
+		quote.TradingStatus = DetectTradingStatus(latestTradingDay, quote.Volume, quote.LastUpdated)
+	}
 
 	return quote, nil
 }
@@ -10,6 +10,10 @@ import (
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
 )
 
 func TestNewAlphaVantageProvider(t *testing.T) {
@@ -78,6 +82,9 @@ func TestAlphaVantageProvider_GetQuote(t *testing.T) {
 		assert.True(t, quote.Change.Equal(decimal.NewFromFloat(2.50)))
 		assert.True(t, quote.ChangePercent.Equal(decimal.NewFromFloat(1.67)))
 		assert.Equal(t, int64(1000000), quote.Volume)
+		assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), quote.LatestTradingDay)
+		// The fixture's session is long past, so the symbol looks suspended
+		assert.Equal(t, dto.TradingStatusSuspended, quote.TradingStatus)
 	})
 
 	t.Run("API key not configured", func(t *testing.T) {
@@ -595,3 +602,192 @@ func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req.URL.Host = t.server.URL[7:] // Remove "http://" prefix
 	return t.server.Client().Do(req)
 }
+
+func TestAlphaVantageProvider_GetSplits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "SPLITS", r.URL.Query().Get("function"))
+		assert.Equal(t, "AAPL", r.URL.Query().Get("symbol"))
+
+		response := `{
+			"symbol": "AAPL",
+			"data": [
+				{"effective_date": "2020-08-31", "split_factor": "4.0000"},
+				{"effective_date": "2014-06-09", "split_factor": "7.0000"},
+				{"effective_date": "None", "split_factor": "2.0000"}
+			]
+		}`
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	provider := &AlphaVantageProvider{
+		apiKey:     "test-api-key",
+		httpClient: &http.Client{Transport: &mockTransport{server: server}},
+	}
+
+	actions, err := provider.GetSplits(context.Background(), "AAPL")
+
+	assert.NoError(t, err)
+	assert.Len(t, actions, 2)
+	assert.Equal(t, models.CorporateActionTypeSplit, actions[0].Type)
+	assert.Equal(t, time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC), actions[0].Date)
+	assert.True(t, actions[0].Ratio.Equal(decimal.NewFromInt(4)))
+}
+
+func TestAlphaVantageProvider_GetDividends(t *testing.T) {
+	t.Run("successful retrieval", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "DIVIDENDS", r.URL.Query().Get("function"))
+
+			response := `{
+				"symbol": "IBM",
+				"data": [
+					{"ex_dividend_date": "2024-05-09", "declaration_date": "2024-04-30", "record_date": "2024-05-10", "payment_date": "2024-06-10", "amount": "1.67"},
+					{"ex_dividend_date": "2024-02-08", "declaration_date": "None", "record_date": "None", "payment_date": "None", "amount": "1.66"}
+				]
+			}`
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(response))
+		}))
+		defer server.Close()
+
+		provider := &AlphaVantageProvider{
+			apiKey:     "test-api-key",
+			httpClient: &http.Client{Transport: &mockTransport{server: server}},
+		}
+
+		actions, err := provider.GetDividends(context.Background(), "IBM")
+
+		assert.NoError(t, err)
+		assert.Len(t, actions, 2)
+		assert.Equal(t, models.CorporateActionTypeDividend, actions[0].Type)
+		assert.True(t, actions[0].Amount.Equal(decimal.NewFromFloat(1.67)))
+		assert.Contains(t, actions[0].Description, "payable 2024-06-10")
+		assert.NoError(t, actions[1].Validate())
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"Information": "Our standard API rate limit is 25 requests per day."}`))
+		}))
+		defer server.Close()
+
+		provider := &AlphaVantageProvider{
+			apiKey:     "test-api-key",
+			httpClient: &http.Client{Transport: &mockTransport{server: server}},
+		}
+
+		_, err := provider.GetDividends(context.Background(), "IBM")
+
+		assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+	})
+}
+
+func TestAlphaVantageProvider_GetOptionQuote(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "HISTORICAL_OPTIONS", r.URL.Query().Get("function"))
+		assert.Equal(t, "AAPL", r.URL.Query().Get("symbol"))
+
+		response := `{
+			"endpoint": "Historical Options",
+			"message": "success",
+			"data": [
+				{"contractID": "AAPL240621C00190000", "date": "2024-06-07", "last": "3.50", "mark": "3.55", "volume": "1234"},
+				{"contractID": "AAPL240621P00150000", "date": "2024-06-07", "last": "0.12", "mark": "0.00", "volume": "10"}
+			]
+		}`
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	provider := &AlphaVantageProvider{
+		apiKey:     "test-api-key",
+		httpClient: &http.Client{Transport: &mockTransport{server: server}},
+	}
+	ctx := context.Background()
+
+	quote, err := provider.GetOptionQuote(ctx, "AAPL240621C00190000")
+	require.NoError(t, err)
+	assert.True(t, quote.Price.Equal(decimal.RequireFromString("3.55")))
+	assert.Equal(t, int64(1234), quote.Volume)
+	assert.Equal(t, time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC), quote.LatestTradingDay)
+
+	// Without a mark the last trade is used
+	quote, err = provider.GetOptionQuote(ctx, "AAPL240621P00150000")
+	require.NoError(t, err)
+	assert.True(t, quote.Price.Equal(decimal.RequireFromString("0.12")))
+
+	_, err = provider.GetOptionQuote(ctx, "AAPL240621C00200000")
+	assert.ErrorContains(t, err, "no data found")
+
+	_, err = provider.GetOptionQuote(ctx, "AAPL")
+	assert.ErrorIs(t, err, models.ErrInvalidOptionSymbol)
+}
+
+func TestAlphaVantageProvider_SearchSymbols(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "SYMBOL_SEARCH", r.URL.Query().Get("function"))
+		assert.Equal(t, "apple", r.URL.Query().Get("keywords"))
+
+		response := `{
+			"bestMatches": [
+				{"1. symbol": "AAPL", "2. name": "Apple Inc", "3. type": "Equity", "4. region": "United States",
+				 "5. marketOpen": "09:30", "6. marketClose": "16:00", "7. timezone": "UTC-04", "8. currency": "USD", "9. matchScore": "0.8889"},
+				{"1. symbol": "APC.DEX", "2. name": "Apple Inc ", "3. type": "Equity", "4. region": "XETRA",
+				 "5. marketOpen": "08:00", "6. marketClose": "20:00", "7. timezone": "UTC+02", "8. currency": "EUR", "9. matchScore": "0.7143"}
+			]
+		}`
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	provider := &AlphaVantageProvider{
+		apiKey:     "test-api-key",
+		httpClient: &http.Client{Transport: &mockTransport{server: server}},
+	}
+
+	securities, err := provider.SearchSymbols(context.Background(), "apple")
+	require.NoError(t, err)
+	require.Len(t, securities, 2)
+	assert.Equal(t, &models.Security{Symbol: "AAPL", Name: "Apple Inc", Exchange: "United States", Type: "Equity", Currency: "USD"}, securities[0])
+	assert.Equal(t, "APC.DEX", securities[1].Symbol)
+	assert.Equal(t, "Apple Inc", securities[1].Name)
+	assert.Equal(t, "EUR", securities[1].Currency)
+}
+
+func TestAlphaVantageProvider_GetIntradayPrices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "TIME_SERIES_INTRADAY", r.URL.Query().Get("function"))
+		assert.Equal(t, "5min", r.URL.Query().Get("interval"))
+
+		_, _ = w.Write([]byte(`{
+			"Meta Data": {"1. Information": "Intraday (5min)", "6. Time Zone": "US/Eastern"},
+			"Time Series (5min)": {
+				"2024-05-01 09:35:00": {"1. open": "170.00", "2. high": "171.00", "3. low": "169.50", "4. close": "170.50", "5. volume": "12000"},
+				"2024-04-30 15:55:00": {"1. open": "168.00", "2. high": "168.50", "3. low": "167.50", "4. close": "168.25", "5. volume": "9000"}
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	provider := &AlphaVantageProvider{
+		apiKey:     "test-api-key",
+		httpClient: &http.Client{Transport: &mockTransport{server: server}},
+	}
+
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	prices, err := provider.GetIntradayPrices(context.Background(), "AAPL", models.PriceResolution5Min, start, start.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, prices, 1)
+	// 09:35 in New York is 13:35 UTC during daylight saving time
+	assert.Equal(t, time.Date(2024, 5, 1, 13, 35, 0, 0, time.UTC), prices[0].Date)
+	assert.True(t, prices[0].Close.Equal(decimal.NewFromFloat(170.50)))
+	assert.Equal(t, int64(12000), prices[0].Volume)
+
+	_, err = provider.GetIntradayPrices(context.Background(), "AAPL", models.PriceResolution1Day, start, start)
+	assert.Equal(t, models.ErrInvalidPriceResolution, err)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// SearchSymbols finds securities whose symbol or name matches keywords with Alpha Vantage's
+// symbol search, best match first. Alpha Vantage reports the region a security trades in
+// rather than its exchange, so the region is stored as the exchange.
+func (p *AlphaVantageProvider) SearchSymbols(ctx context.Context, keywords string) ([]*models.Security, error) {
+	var result struct {
+		BestMatches []struct {
+			Symbol   string `json:"1. symbol"`
+			Name     string `json:"2. name"`
+			Type     string `json:"3. type"`
+			Region   string `json:"4. region"`
+			Currency string `json:"8. currency"`
+		} `json:"bestMatches"`
+	}
+	if err := p.fetchFunction(ctx, "SYMBOL_SEARCH", url.Values{"keywords": {keywords}}, &result); err != nil {
+		return nil, err
+	}
+
+	securities := make([]*models.Security, 0, len(result.BestMatches))
+	for _, match := range result.BestMatches {
+		symbol := models.NormalizeSymbol(match.Symbol)
+		if symbol == "" {
+			continue
+		}
+		securities = append(securities, &models.Security{
+			Symbol:   symbol,
+			Name:     strings.TrimSpace(match.Name),
+			Exchange: match.Region,
+			Type:     match.Type,
+			Currency: strings.ToUpper(match.Currency),
+		})
+	}
+	return securities, nil
+}
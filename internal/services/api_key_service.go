@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// APIKeyService defines the interface for authenticating requests made with API keys
+type APIKeyService interface {
+	Authenticate(key string) (*models.APIKey, error)
+}
+
+// apiKeyService implements APIKeyService interface
+type apiKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+	now        func() time.Time
+}
+
+// NewAPIKeyService creates a new APIKeyService instance
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Authenticate resolves an API key to the stored key record and records its use. Unknown and
+// revoked keys both return ErrInvalidAPIKey.
+func (s *apiKeyService) Authenticate(key string) (*models.APIKey, error) {
+	if !strings.HasPrefix(key, models.APIKeyPrefix) {
+		return nil, models.ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.FindByKeyHash(hashResetToken(key))
+	if err != nil {
+		if errors.Is(err, models.ErrAPIKeyNotFound) {
+			return nil, models.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if !apiKey.IsActive() {
+		return nil, models.ErrInvalidAPIKey
+	}
+
+	usedAt := s.now()
+	if err := s.apiKeyRepo.TouchLastUsed(apiKey.ID, usedAt); err != nil {
+		return nil, err
+	}
+	apiKey.LastUsedAt = &usedAt
+
+	return apiKey, nil
+}
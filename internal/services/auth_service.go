@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/lenon/portfolios/internal/logger"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/repository"
 	"github.com/lenon/portfolios/internal/utils"
@@ -18,6 +19,9 @@ type AuthService interface {
 	Register(email, password string) (*models.User, string, string, error)
 	Login(email, password string, rememberMe bool) (*models.User, string, string, error)
 	RefreshAccessToken(refreshToken string) (string, error)
+	// RotateRefreshToken revokes a refresh token and returns a new access token and a
+	// replacement refresh token that expires when the revoked one would have
+	RotateRefreshToken(refreshToken string) (string, string, time.Time, error)
 	Logout(refreshToken string) error
 }
 
@@ -30,6 +34,9 @@ type authService struct {
 	refreshDuration           time.Duration
 	rememberMeAccessDuration  time.Duration
 	rememberMeRefreshDuration time.Duration
+	passwordPolicy            models.PasswordPolicy
+	hasher                    utils.PasswordHasher
+	log                       *logger.AppLogger
 }
 
 // NewAuthService creates a new AuthService instance
@@ -41,6 +48,36 @@ func NewAuthService(
 	refreshDuration time.Duration,
 	rememberMeAccessDuration time.Duration,
 	rememberMeRefreshDuration time.Duration,
+) AuthService {
+	return NewAuthServiceWithPasswords(
+		userRepo,
+		refreshTokenRepo,
+		tokenService,
+		accessDuration,
+		refreshDuration,
+		rememberMeAccessDuration,
+		rememberMeRefreshDuration,
+		models.DefaultPasswordPolicy(),
+		utils.DefaultPasswordHasher(),
+		logger.GetLogger(),
+	)
+}
+
+// NewAuthServiceWithPasswords creates a new AuthService instance that checks the passwords of
+// new users against the given policy and hashes passwords with hasher. Users whose password
+// hash was created with another algorithm or other parameters are rehashed when they log in,
+// and failures to rehash are logged to log.
+func NewAuthServiceWithPasswords(
+	userRepo repository.UserRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	tokenService *TokenService,
+	accessDuration time.Duration,
+	refreshDuration time.Duration,
+	rememberMeAccessDuration time.Duration,
+	rememberMeRefreshDuration time.Duration,
+	passwordPolicy models.PasswordPolicy,
+	hasher utils.PasswordHasher,
+	log *logger.AppLogger,
 ) AuthService {
 	return &authService{
 		userRepo:                  userRepo,
@@ -50,6 +87,9 @@ func NewAuthService(
 		refreshDuration:           refreshDuration,
 		rememberMeAccessDuration:  rememberMeAccessDuration,
 		rememberMeRefreshDuration: rememberMeRefreshDuration,
+		passwordPolicy:            passwordPolicy,
+		hasher:                    hasher,
+		log:                       log,
 	}
 }
 
@@ -61,15 +101,18 @@ func (s *authService) Register(email, password string) (*models.User, string, st
 	}
 
 	// Validate and hash password
-	hashedPassword, err := utils.ValidateAndHashPassword(password)
+	if err := s.passwordPolicy.Check(password); err != nil {
+		return nil, "", "", fmt.Errorf("%w: %w", models.ErrInvalidPassword, err)
+	}
+	hashedPassword, err := s.hasher.Hash(password)
 	if err != nil {
-		return nil, "", "", fmt.Errorf("invalid password: %w", err)
+		return nil, "", "", fmt.Errorf("failed to hash password: %w", err)
 	}
 
 	// Check if user already exists
 	existingUser, err := s.userRepo.FindByEmail(email)
 	if err == nil && existingUser != nil {
-		return nil, "", "", fmt.Errorf("user with email %s already exists", email)
+		return nil, "", "", fmt.Errorf("%w: %s", models.ErrEmailAlreadyExists, email)
 	}
 
 	// Create user
@@ -126,10 +169,23 @@ func (s *authService) Login(email, password string, rememberMe bool) (*models.Us
 	}
 
 	// Verify password
-	if err := utils.CheckPassword(password, user.PasswordHash); err != nil {
+	if err := s.hasher.Check(password, user.PasswordHash); err != nil {
 		return nil, "", "", fmt.Errorf("invalid email or password")
 	}
 
+	// Disabled and locked accounts can't log in, even with the right password
+	if err := user.CanAuthenticate(); err != nil {
+		return nil, "", "", err
+	}
+
+	// Upgrade hashes from another algorithm or older parameters while the password is known
+	if s.hasher.NeedsRehash(user.PasswordHash) {
+		if err := s.rehashPassword(user, password); err != nil {
+			// Log error but don't fail login
+			s.log.Warn().Err(err).Str("user_id", user.ID.String()).Msg("Failed to rehash password")
+		}
+	}
+
 	// Determine token durations based on remember me flag
 	accessDuration := s.accessDuration
 	refreshDuration := s.refreshDuration
@@ -176,43 +232,111 @@ func (s *authService) Login(email, password string, rememberMe bool) (*models.Us
 	return user, accessToken, refreshToken, nil
 }
 
+// rehashPassword replaces a user's password hash with one created by the service's hasher
+func (s *authService) rehashPassword(user *models.User, password string) error {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.UpdatePassword(user.ID.String(), hashedPassword)
+}
+
 // RefreshAccessToken generates a new access token using a valid refresh token
 func (s *authService) RefreshAccessToken(refreshToken string) (string, error) {
+	_, userID, err := s.validRefreshToken(refreshToken)
+	if err != nil {
+		return "", err
+	}
+
+	// Generate new access token (use default duration)
+	accessToken, err := s.tokenService.GenerateAccessToken(userID, s.accessDuration)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	return accessToken, nil
+}
+
+// RotateRefreshToken revokes a valid refresh token and issues a new access token and
+// refresh token in its place, so a stolen refresh token stops working once it has been used
+func (s *authService) RotateRefreshToken(refreshToken string) (string, string, time.Time, error) {
+	storedToken, userID, err := s.validRefreshToken(refreshToken)
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+
+	accessToken, err := s.tokenService.GenerateAccessToken(userID, s.accessDuration)
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	// The replacement keeps the original expiry, so rotating doesn't extend the session
+	newRefreshToken, err := s.tokenService.GenerateRefreshToken(userID, time.Until(storedToken.ExpiresAt))
+	if err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	// Revoking fails if the token was revoked since it was checked, so of two concurrent
+	// rotations only one gets a replacement
+	if err := s.refreshTokenRepo.RevokeByTokenHash(storedToken.TokenHash); err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+
+	refreshTokenModel := &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    storedToken.UserID,
+		TokenHash: hashToken(newRefreshToken),
+		ExpiresAt: storedToken.ExpiresAt,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.refreshTokenRepo.Create(refreshTokenModel); err != nil {
+		return "", "", time.Time{}, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return accessToken, newRefreshToken, storedToken.ExpiresAt, nil
+}
+
+// validRefreshToken returns the stored record and user ID of a refresh token that is signed,
+// not expired or revoked, and belongs to a user who may still sign in
+func (s *authService) validRefreshToken(refreshToken string) (*models.RefreshToken, string, error) {
 	if refreshToken == "" {
-		return "", fmt.Errorf("refresh token cannot be empty")
+		return nil, "", fmt.Errorf("refresh token cannot be empty")
 	}
 
 	// Validate refresh token
 	token, err := s.tokenService.ValidateToken(refreshToken)
 	if err != nil {
-		return "", fmt.Errorf("invalid refresh token: %w", err)
+		return nil, "", fmt.Errorf("invalid refresh token: %w", err)
 	}
 
 	// Extract user ID
 	userID, err := s.tokenService.ExtractUserID(token)
 	if err != nil {
-		return "", fmt.Errorf("failed to extract user ID: %w", err)
+		return nil, "", fmt.Errorf("failed to extract user ID: %w", err)
 	}
 
 	// Verify refresh token exists in database and is not revoked
 	tokenHash := hashToken(refreshToken)
 	storedToken, err := s.refreshTokenRepo.FindByTokenHash(tokenHash)
 	if err != nil {
-		return "", fmt.Errorf("refresh token not found: %w", err)
+		return nil, "", fmt.Errorf("refresh token not found: %w", err)
 	}
 
 	// Check if token is valid (not expired or revoked)
 	if !storedToken.IsValid() {
-		return "", fmt.Errorf("refresh token is expired or revoked")
+		return nil, "", fmt.Errorf("refresh token is expired or revoked")
 	}
 
-	// Generate new access token (use default duration)
-	accessToken, err := s.tokenService.GenerateAccessToken(userID, s.accessDuration)
+	// Sessions of disabled and locked accounts are revoked, but check in case one slipped through
+	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate access token: %w", err)
+		return nil, "", fmt.Errorf("failed to find user: %w", err)
+	}
+	if err := user.CanAuthenticate(); err != nil {
+		return nil, "", err
 	}
 
-	return accessToken, nil
+	return storedToken, userID, nil
 }
 
 // Logout revokes the refresh token
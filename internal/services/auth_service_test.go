@@ -1,12 +1,19 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/lenon/portfolios/internal/logger"
 	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/utils"
 )
 
 // Mock repositories for testing
@@ -60,10 +67,33 @@ func (m *mockUserRepository) UpdatePassword(id string, passwordHash string) erro
 		return err
 	}
 	user.PasswordHash = passwordHash
+	user.PasswordResetRequired = false
 	user.UpdatedAt = time.Now().UTC()
 	return nil
 }
 
+func (m *mockUserRepository) List(filter repository.UserFilter) ([]*models.User, int64, error) {
+	users := make([]*models.User, 0, len(m.users))
+	for _, user := range m.users {
+		users = append(users, user)
+	}
+	return users, int64(len(users)), nil
+}
+
+func (m *mockUserRepository) Save(user *models.User) error {
+	m.users[user.Email] = user
+	return nil
+}
+
+func (m *mockUserRepository) Delete(id string) error {
+	user, err := m.FindByID(id)
+	if err != nil {
+		return err
+	}
+	delete(m.users, user.Email)
+	return nil
+}
+
 type mockRefreshTokenRepository struct {
 	tokens map[string]*models.RefreshToken
 }
@@ -203,6 +233,54 @@ func TestAuthService_Login_CorrectPassword(t *testing.T) {
 	}
 }
 
+func TestAuthService_Login_LockedAccount(t *testing.T) {
+	userRepo := newMockUserRepository()
+	tokenRepo := newMockRefreshTokenRepository()
+	tokenService := NewTokenService("test-secret-key-for-jwt-signing")
+
+	authService := NewAuthService(
+		userRepo,
+		tokenRepo,
+		tokenService,
+		30*time.Minute,
+		7*24*time.Hour,
+		24*time.Hour,
+		30*24*time.Hour,
+	)
+
+	email := "test@example.com"
+	password := "SecurePass123"
+
+	user, _, refreshToken, err := authService.Register(email, password)
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+
+	disabledAt := time.Now().UTC()
+	user.DisabledAt = &disabledAt
+
+	if _, _, _, err := authService.Login(email, password, false); !errors.Is(err, models.ErrUserDisabled) {
+		t.Errorf("Expected ErrUserDisabled, got: %v", err)
+	}
+
+	// Tokens issued before the account was disabled cannot be refreshed either
+	if _, err := authService.RefreshAccessToken(refreshToken); !errors.Is(err, models.ErrUserDisabled) {
+		t.Errorf("Expected ErrUserDisabled on refresh, got: %v", err)
+	}
+
+	user.DisabledAt = nil
+	user.PasswordResetRequired = true
+
+	if _, _, _, err := authService.Login(email, password, false); !errors.Is(err, models.ErrPasswordResetRequired) {
+		t.Errorf("Expected ErrPasswordResetRequired, got: %v", err)
+	}
+
+	// A wrong password is reported as such rather than revealing the account state
+	if _, _, _, err := authService.Login(email, "WrongPass123", false); errors.Is(err, models.ErrPasswordResetRequired) {
+		t.Error("Expected wrong password error before the account state is checked")
+	}
+}
+
 // Test 3: Login with wrong password fails
 func TestAuthService_Login_WrongPassword(t *testing.T) {
 	userRepo := newMockUserRepository()
@@ -568,3 +646,104 @@ func TestAuthService_Logout_InvalidToken(t *testing.T) {
 		t.Fatal("Expected error on logout with invalid token, got nil")
 	}
 }
+
+// Test 13: Rotating a refresh token revokes it and issues a replacement with the same expiry
+func TestAuthService_RotateRefreshToken(t *testing.T) {
+	userRepo := newMockUserRepository()
+	tokenRepo := newMockRefreshTokenRepository()
+	tokenService := NewTokenService("test-secret-key-for-jwt-signing")
+
+	authService := NewAuthService(
+		userRepo,
+		tokenRepo,
+		tokenService,
+		30*time.Minute,
+		7*24*time.Hour,
+		24*time.Hour,
+		30*24*time.Hour,
+	)
+
+	_, _, refreshToken, err := authService.Register("test@example.com", "SecurePass123")
+	if err != nil {
+		t.Fatalf("Failed to register: %v", err)
+	}
+	original, err := tokenRepo.FindByTokenHash(hashToken(refreshToken))
+	if err != nil {
+		t.Fatalf("Failed to find refresh token: %v", err)
+	}
+
+	accessToken, newRefreshToken, expiresAt, err := authService.RotateRefreshToken(refreshToken)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if accessToken == "" || newRefreshToken == "" || newRefreshToken == refreshToken {
+		t.Fatal("Expected a new access token and a different refresh token")
+	}
+	if !expiresAt.Equal(original.ExpiresAt) {
+		t.Errorf("Expected the replacement to expire at %v, got %v", original.ExpiresAt, expiresAt)
+	}
+
+	// The old token is revoked and the new one works
+	if _, err := authService.RefreshAccessToken(refreshToken); err == nil {
+		t.Error("Expected error when using the rotated token, got nil")
+	}
+	if _, err := authService.RefreshAccessToken(newRefreshToken); err != nil {
+		t.Errorf("Expected the replacement token to work, got: %v", err)
+	}
+}
+
+// Test 14: Login rehashes passwords hashed with older settings
+func TestAuthService_Login_RehashesBcryptPassword(t *testing.T) {
+	userRepo := newMockUserRepository()
+	tokenRepo := newMockRefreshTokenRepository()
+	tokenService := NewTokenService("test-secret-key-for-jwt-signing")
+
+	bcryptHasher := utils.PasswordHasher{Algorithm: utils.PasswordHashBcrypt, BcryptCost: bcrypt.MinCost}
+	legacyService := NewAuthServiceWithPasswords(
+		userRepo,
+		tokenRepo,
+		tokenService,
+		30*time.Minute,
+		7*24*time.Hour,
+		24*time.Hour,
+		30*24*time.Hour,
+		models.DefaultPasswordPolicy(),
+		bcryptHasher,
+		logger.GetLogger(),
+	)
+
+	email := "legacy@example.com"
+	password := "SecurePass123"
+
+	user, _, _, err := legacyService.Register(email, password)
+	if err != nil {
+		t.Fatalf("Failed to register user: %v", err)
+	}
+	if !strings.HasPrefix(user.PasswordHash, "$2a$") {
+		t.Fatalf("Expected a bcrypt hash, got: %s", user.PasswordHash)
+	}
+
+	authService := NewAuthService(
+		userRepo,
+		tokenRepo,
+		tokenService,
+		30*time.Minute,
+		7*24*time.Hour,
+		24*time.Hour,
+		30*24*time.Hour,
+	)
+
+	if _, _, _, err := authService.Login(email, password, false); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	stored, _ := userRepo.FindByEmail(email)
+	if !strings.HasPrefix(stored.PasswordHash, "$argon2id$") {
+		t.Errorf("Expected password to be rehashed with argon2id, got: %s", stored.PasswordHash)
+	}
+
+	// The new hash still verifies
+	if _, _, _, err := authService.Login(email, password, false); err != nil {
+		t.Errorf("Expected login with the rehashed password to succeed, got: %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// BlackoutService defines the interface for employer stock blackout window operations
+type BlackoutService interface {
+	// Employer stock policy
+	GetPolicy(ctx context.Context, portfolioID, userID string) (*models.EmployerStockPolicy, error)
+	SetPolicy(ctx context.Context, portfolioID, userID, symbol string, enforcement models.BlackoutEnforcement) (*models.EmployerStockPolicy, error)
+	DeletePolicy(ctx context.Context, portfolioID, userID string) error
+
+	// Blackout windows
+	CreateWindow(ctx context.Context, portfolioID, userID string, window *models.BlackoutWindow) (*models.BlackoutWindow, error)
+	GetWindows(ctx context.Context, portfolioID, userID string) ([]*models.BlackoutWindow, error)
+	DeleteWindow(ctx context.Context, id, portfolioID, userID string) error
+
+	// Transaction enforcement and audit trail
+	CheckTransaction(ctx context.Context, portfolioID, userID string, transactionType models.TransactionType, symbol string, date time.Time, overrideReason string) (*BlackoutCheck, error)
+	RecordOverride(ctx context.Context, check *BlackoutCheck, transaction *models.Transaction, userID, reason string) error
+	GetOverrides(ctx context.Context, portfolioID, userID string) ([]*models.BlackoutOverride, error)
+}
+
+// BlackoutCheck describes a transaction that falls inside a blackout window
+type BlackoutCheck struct {
+	Policy *models.EmployerStockPolicy
+	Window *models.BlackoutWindow
+}
+
+// Warning returns a human-readable description of the blackout
+func (c *BlackoutCheck) Warning() string {
+	return fmt.Sprintf("%s is in a trading blackout from %s to %s",
+		c.Policy.Symbol,
+		c.Window.StartDate.Format("2006-01-02"),
+		c.Window.EndDate.Format("2006-01-02"))
+}
+
+// blackoutService implements BlackoutService interface
+type blackoutService struct {
+	blackoutRepo  repository.BlackoutRepository
+	portfolioRepo repository.PortfolioRepository
+}
+
+// NewBlackoutService creates a new BlackoutService instance
+func NewBlackoutService(
+	blackoutRepo repository.BlackoutRepository,
+	portfolioRepo repository.PortfolioRepository,
+) BlackoutService {
+	return &blackoutService{
+		blackoutRepo:  blackoutRepo,
+		portfolioRepo: portfolioRepo,
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *blackoutService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// GetPolicy retrieves the employer stock policy for a portfolio
+func (s *blackoutService) GetPolicy(ctx context.Context, portfolioID, userID string) (*models.EmployerStockPolicy, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.blackoutRepo.FindPolicyByPortfolioID(ctx, portfolioID)
+}
+
+// SetPolicy flags a portfolio as holding employer stock, replacing any existing policy
+func (s *blackoutService) SetPolicy(ctx context.Context, portfolioID, userID, symbol string, enforcement models.BlackoutEnforcement) (*models.EmployerStockPolicy, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.blackoutRepo.FindPolicyByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		if err != models.ErrEmployerStockPolicyNotFound {
+			return nil, err
+		}
+		policy = &models.EmployerStockPolicy{PortfolioID: portfolio.ID}
+	}
+
+	policy.Symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	policy.Enforcement = enforcement
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.blackoutRepo.SavePolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save employer stock policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// DeletePolicy removes the employer stock flag from a portfolio
+func (s *blackoutService) DeletePolicy(ctx context.Context, portfolioID, userID string) error {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return err
+	}
+
+	return s.blackoutRepo.DeletePolicy(ctx, portfolioID)
+}
+
+// CreateWindow adds a blackout window to a portfolio
+func (s *blackoutService) CreateWindow(ctx context.Context, portfolioID, userID string, window *models.BlackoutWindow) (*models.BlackoutWindow, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	window.PortfolioID = portfolio.ID
+	if err := window.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.blackoutRepo.CreateWindow(ctx, window); err != nil {
+		return nil, fmt.Errorf("failed to create blackout window: %w", err)
+	}
+
+	return window, nil
+}
+
+// GetWindows retrieves all blackout windows for a portfolio
+func (s *blackoutService) GetWindows(ctx context.Context, portfolioID, userID string) ([]*models.BlackoutWindow, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.blackoutRepo.FindWindowsByPortfolioID(ctx, portfolioID)
+}
+
+// DeleteWindow deletes a blackout window, ensuring it belongs to the user's portfolio
+func (s *blackoutService) DeleteWindow(ctx context.Context, id, portfolioID, userID string) error {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return err
+	}
+
+	window, err := s.blackoutRepo.FindWindowByID(ctx, id)
+	if err != nil {
+		return models.ErrBlackoutWindowNotFound
+	}
+	if window.PortfolioID.String() != portfolioID {
+		return models.ErrBlackoutWindowNotFound
+	}
+
+	return s.blackoutRepo.DeleteWindow(ctx, id)
+}
+
+// CheckTransaction reports whether a trade falls inside one of the portfolio's blackout
+// windows. It returns nil when the trade is unrestricted. Under BLOCK enforcement the
+// trade is rejected with models.ErrBlackoutPeriod unless an override reason is given.
+func (s *blackoutService) CheckTransaction(
+	ctx context.Context,
+	portfolioID, userID string,
+	transactionType models.TransactionType,
+	symbol string,
+	date time.Time,
+	overrideReason string,
+) (*BlackoutCheck, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.blackoutRepo.FindPolicyByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		if err == models.ErrEmployerStockPolicyNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load employer stock policy: %w", err)
+	}
+	if !policy.AppliesTo(symbol, transactionType) {
+		return nil, nil
+	}
+
+	windows, err := s.blackoutRepo.FindWindowsByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blackout windows: %w", err)
+	}
+
+	for _, window := range windows {
+		if !window.Contains(date) {
+			continue
+		}
+
+		check := &BlackoutCheck{Policy: policy, Window: window}
+		if policy.Enforcement == models.BlackoutEnforcementBlock && strings.TrimSpace(overrideReason) == "" {
+			return check, models.ErrBlackoutPeriod
+		}
+		return check, nil
+	}
+
+	return nil, nil
+}
+
+// RecordOverride adds a trade made inside a blackout window to the audit trail
+func (s *blackoutService) RecordOverride(ctx context.Context, check *BlackoutCheck, transaction *models.Transaction, userID, reason string) error {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	override := &models.BlackoutOverride{
+		PortfolioID:     transaction.PortfolioID,
+		WindowID:        check.Window.ID,
+		TransactionID:   transaction.ID,
+		UserID:          uid,
+		Symbol:          transaction.Symbol,
+		TransactionType: transaction.Type,
+		TransactionDate: transaction.Date,
+		Enforcement:     check.Policy.Enforcement,
+		Reason:          strings.TrimSpace(reason),
+	}
+
+	if err := s.blackoutRepo.CreateOverride(ctx, override); err != nil {
+		return fmt.Errorf("failed to record blackout override: %w", err)
+	}
+
+	return nil
+}
+
+// GetOverrides retrieves the blackout override audit trail for a portfolio
+func (s *blackoutService) GetOverrides(ctx context.Context, portfolioID, userID string) ([]*models.BlackoutOverride, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.blackoutRepo.FindOverridesByPortfolioID(ctx, portfolioID)
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupBlackoutServiceTest(t *testing.T) (BlackoutService, *gorm.DB, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{},
+		&models.EmployerStockPolicy{}, &models.BlackoutWindow{}, &models.BlackoutOverride{})
+	require.NoError(t, err)
+
+	service := NewBlackoutService(
+		repository.NewBlackoutRepository(db),
+		repository.NewPortfolioRepository(db),
+	)
+
+	user, portfolio := createTestUserAndPortfolio(t, db)
+	return service, db, user, portfolio
+}
+
+func TestBlackoutService_SetPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, user, portfolio := setupBlackoutServiceTest(t)
+
+	policy, err := service.SetPolicy(ctx, portfolio.ID.String(), user.ID.String(), " acme ", models.BlackoutEnforcementWarn)
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", policy.Symbol)
+
+	// Setting again updates the existing policy
+	updated, err := service.SetPolicy(ctx, portfolio.ID.String(), user.ID.String(), "ACME", models.BlackoutEnforcementBlock)
+	require.NoError(t, err)
+	assert.Equal(t, policy.ID, updated.ID)
+	assert.Equal(t, models.BlackoutEnforcementBlock, updated.Enforcement)
+
+	_, err = service.SetPolicy(ctx, portfolio.ID.String(), user.ID.String(), "ACME", "IGNORE")
+	assert.Equal(t, models.ErrInvalidBlackoutEnforcement, err)
+
+	_, err = service.SetPolicy(ctx, portfolio.ID.String(), uuid.New().String(), "ACME", models.BlackoutEnforcementWarn)
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+}
+
+func TestBlackoutService_CheckTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, user, portfolio := setupBlackoutServiceTest(t)
+	portfolioID, userID := portfolio.ID.String(), user.ID.String()
+
+	inWindow := time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC)
+	outsideWindow := time.Date(2024, 5, 20, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no policy", func(t *testing.T) {
+		check, err := service.CheckTransaction(ctx, portfolioID, userID, models.TransactionTypeSell, "ACME", inWindow, "")
+		assert.NoError(t, err)
+		assert.Nil(t, check)
+	})
+
+	_, err := service.SetPolicy(ctx, portfolioID, userID, "ACME", models.BlackoutEnforcementWarn)
+	require.NoError(t, err)
+	_, err = service.CreateWindow(ctx, portfolioID, userID, &models.BlackoutWindow{
+		StartDate: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	t.Run("warn allows trade in window", func(t *testing.T) {
+		check, err := service.CheckTransaction(ctx, portfolioID, userID, models.TransactionTypeSell, "ACME", inWindow, "")
+		require.NoError(t, err)
+		require.NotNil(t, check)
+		assert.Equal(t, "ACME is in a trading blackout from 2024-03-15 to 2024-04-30", check.Warning())
+	})
+
+	t.Run("outside window", func(t *testing.T) {
+		check, err := service.CheckTransaction(ctx, portfolioID, userID, models.TransactionTypeSell, "ACME", outsideWindow, "")
+		assert.NoError(t, err)
+		assert.Nil(t, check)
+	})
+
+	t.Run("other symbols are unrestricted", func(t *testing.T) {
+		check, err := service.CheckTransaction(ctx, portfolioID, userID, models.TransactionTypeBuy, "AAPL", inWindow, "")
+		assert.NoError(t, err)
+		assert.Nil(t, check)
+	})
+
+	_, err = service.SetPolicy(ctx, portfolioID, userID, "ACME", models.BlackoutEnforcementBlock)
+	require.NoError(t, err)
+
+	t.Run("block rejects trade without override", func(t *testing.T) {
+		check, err := service.CheckTransaction(ctx, portfolioID, userID, models.TransactionTypeSell, "ACME", inWindow, "  ")
+		assert.Equal(t, models.ErrBlackoutPeriod, err)
+		assert.NotNil(t, check)
+	})
+
+	t.Run("block allows trade with override reason", func(t *testing.T) {
+		check, err := service.CheckTransaction(ctx, portfolioID, userID, models.TransactionTypeSell, "ACME", inWindow, "Pre-cleared by compliance")
+		assert.NoError(t, err)
+		assert.NotNil(t, check)
+	})
+}
+
+func TestBlackoutService_RecordOverride(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, user, portfolio := setupBlackoutServiceTest(t)
+	portfolioID, userID := portfolio.ID.String(), user.ID.String()
+
+	_, err := service.SetPolicy(ctx, portfolioID, userID, "ACME", models.BlackoutEnforcementBlock)
+	require.NoError(t, err)
+	window, err := service.CreateWindow(ctx, portfolioID, userID, &models.BlackoutWindow{
+		StartDate: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	check, err := service.CheckTransaction(ctx, portfolioID, userID, models.TransactionTypeSell, "ACME",
+		time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC), "Pre-cleared by compliance")
+	require.NoError(t, err)
+
+	transaction := &models.Transaction{
+		ID:          uuid.New(),
+		PortfolioID: portfolio.ID,
+		Type:        models.TransactionTypeSell,
+		Symbol:      "ACME",
+		Date:        time.Date(2024, 3, 20, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, service.RecordOverride(ctx, check, transaction, userID, "Pre-cleared by compliance"))
+
+	overrides, err := service.GetOverrides(ctx, portfolioID, userID)
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	assert.Equal(t, window.ID, overrides[0].WindowID)
+	assert.Equal(t, transaction.ID, overrides[0].TransactionID)
+	assert.Equal(t, models.BlackoutEnforcementBlock, overrides[0].Enforcement)
+	assert.Equal(t, "Pre-cleared by compliance", overrides[0].Reason)
+}
+
+func TestBlackoutService_DeleteWindow_OtherPortfolio(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, user, portfolio := setupBlackoutServiceTest(t)
+
+	window, err := service.CreateWindow(ctx, portfolio.ID.String(), user.ID.String(), &models.BlackoutWindow{
+		StartDate: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	err = service.DeleteWindow(ctx, window.ID.String(), uuid.New().String(), user.ID.String())
+	assert.Equal(t, models.ErrPortfolioNotFound, err)
+
+	assert.NoError(t, service.DeleteWindow(ctx, window.ID.String(), portfolio.ID.String(), user.ID.String()))
+}
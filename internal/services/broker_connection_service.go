@@ -0,0 +1,510 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/logger"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/utils"
+)
+
+// brokerSyncedTypes are the transaction types broker syncs reconcile. Portfolio
+// transactions of other types are never reported as missing at the broker.
+var brokerSyncedTypes = map[models.TransactionType]bool{
+	models.TransactionTypeBuy:           true,
+	models.TransactionTypeSell:          true,
+	models.TransactionTypeDividend:      true,
+	models.TransactionTypeInterest:      true,
+	models.TransactionTypeManagementFee: true,
+	models.TransactionTypeDeposit:       true,
+	models.TransactionTypeWithdrawal:    true,
+}
+
+// BrokerConnectionService defines the interface for connecting portfolios to brokerage
+// accounts and keeping their transactions in step
+type BrokerConnectionService interface {
+	Connect(ctx context.Context, portfolioID, userID string, req dto.CreateBrokerConnectionRequest) (*models.BrokerConnection, error)
+	List(ctx context.Context, portfolioID, userID string) ([]*models.BrokerConnection, error)
+	Get(ctx context.Context, id, portfolioID, userID string) (*models.BrokerConnection, error)
+	Disconnect(ctx context.Context, id, portfolioID, userID string) error
+
+	// Sync reconciles a connection's account with its portfolio, importing the broker's
+	// transactions the portfolio lacks unless dryRun is set
+	Sync(ctx context.Context, id, portfolioID, userID string, dryRun bool) (*dto.BrokerSyncReport, error)
+
+	// SyncAll syncs every connection, returning how many synced and how many failed
+	SyncAll(ctx context.Context) (synced, failed int, err error)
+}
+
+// brokerConnectionService implements BrokerConnectionService interface
+type brokerConnectionService struct {
+	connectionRepo       repository.BrokerConnectionRepository
+	portfolioRepo        repository.PortfolioRepository
+	transactionRepo      repository.TransactionRepository
+	holdingRepo          repository.HoldingRepository
+	importService        CSVImportService
+	recalculationService PortfolioRecalculationService
+	box                  *utils.SecretBox
+	providers            map[models.BrokerProvider]BrokerDataProvider
+	now                  func() time.Time
+}
+
+// NewBrokerConnectionService creates a new BrokerConnectionService instance. Access tokens
+// are sealed with box before they are stored; without a box, or without a provider for a
+// connection, nothing can be connected or synced.
+func NewBrokerConnectionService(
+	connectionRepo repository.BrokerConnectionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	holdingRepo repository.HoldingRepository,
+	importService CSVImportService,
+	recalculationService PortfolioRecalculationService,
+	box *utils.SecretBox,
+	providers ...BrokerDataProvider,
+) BrokerConnectionService {
+	byName := make(map[models.BrokerProvider]BrokerDataProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &brokerConnectionService{
+		connectionRepo:       connectionRepo,
+		portfolioRepo:        portfolioRepo,
+		transactionRepo:      transactionRepo,
+		holdingRepo:          holdingRepo,
+		importService:        importService,
+		recalculationService: recalculationService,
+		box:                  box,
+		providers:            byName,
+		now:                  func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *brokerConnectionService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// provider returns the provider of a connection, failing if syncing with it isn't configured
+func (s *brokerConnectionService) provider(name models.BrokerProvider) (BrokerDataProvider, error) {
+	provider, ok := s.providers[name]
+	if !ok || s.box == nil {
+		return nil, models.ErrBrokerSyncUnavailable
+	}
+	return provider, nil
+}
+
+// Connect connects a portfolio to a brokerage account. The access token is checked by
+// fetching the account's positions before the connection is saved.
+func (s *brokerConnectionService) Connect(ctx context.Context, portfolioID, userID string, req dto.CreateBrokerConnectionRequest) (*models.BrokerConnection, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := s.provider(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	syncFrom := s.now()
+	if req.SyncFrom != nil {
+		syncFrom = req.SyncFrom.UTC()
+	}
+	connection := &models.BrokerConnection{
+		PortfolioID: portfolio.ID,
+		UserID:      uid,
+		Provider:    req.Provider,
+		Name:        strings.TrimSpace(req.Name),
+		AccountID:   strings.TrimSpace(req.AccountID),
+		SyncFrom:    time.Date(syncFrom.Year(), syncFrom.Month(), syncFrom.Day(), 0, 0, 0, 0, time.UTC),
+	}
+	if connection.Credentials, err = s.box.Seal(req.AccessToken); err != nil {
+		return nil, fmt.Errorf("failed to seal access token: %w", err)
+	}
+	if err := connection.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err := provider.GetPositions(ctx, req.AccessToken, connection.AccountID); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrBrokerSyncFailed, err)
+	}
+
+	if err := s.connectionRepo.Create(ctx, connection); err != nil {
+		return nil, err
+	}
+	return connection, nil
+}
+
+// List retrieves a portfolio's broker connections
+func (s *brokerConnectionService) List(ctx context.Context, portfolioID, userID string) ([]*models.BrokerConnection, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+	return s.connectionRepo.FindByPortfolioID(ctx, portfolioID)
+}
+
+// Get retrieves a broker connection, ensuring it belongs to the user's portfolio
+func (s *brokerConnectionService) Get(ctx context.Context, id, portfolioID, userID string) (*models.BrokerConnection, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := s.connectionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if connection.PortfolioID != portfolio.ID {
+		return nil, models.ErrBrokerConnectionNotFound
+	}
+	return connection, nil
+}
+
+// Disconnect deletes a broker connection. Transactions it imported are kept.
+func (s *brokerConnectionService) Disconnect(ctx context.Context, id, portfolioID, userID string) error {
+	connection, err := s.Get(ctx, id, portfolioID, userID)
+	if err != nil {
+		return err
+	}
+	return s.connectionRepo.Delete(ctx, connection.ID.String())
+}
+
+// Sync syncs a connection of the user's portfolio
+func (s *brokerConnectionService) Sync(ctx context.Context, id, portfolioID, userID string, dryRun bool) (*dto.BrokerSyncReport, error) {
+	connection, err := s.Get(ctx, id, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sync(ctx, connection, userID, dryRun)
+}
+
+// SyncAll syncs every connection as the user who made it. A connection that fails to sync
+// is marked failed and doesn't stop the others.
+func (s *brokerConnectionService) SyncAll(ctx context.Context) (int, int, error) {
+	connections, err := s.connectionRepo.FindAll(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	synced, failed := 0, 0
+	for _, connection := range connections {
+		if err := ctx.Err(); err != nil {
+			return synced, failed, err
+		}
+		if _, ok := s.providers[connection.Provider]; !ok {
+			continue
+		}
+		if _, err := s.sync(ctx, connection, connection.UserID.String(), false); err != nil {
+			failed++
+			logger.FromContext(ctx).Warn().Err(err).
+				Str("connection_id", connection.ID.String()).
+				Msg("Failed to sync broker connection")
+			continue
+		}
+		synced++
+	}
+	return synced, failed, nil
+}
+
+// sync pulls a connection's transactions since its sync date and its positions, imports
+// the transactions the portfolio lacks and records the outcome on the connection
+func (s *brokerConnectionService) sync(ctx context.Context, connection *models.BrokerConnection, userID string, dryRun bool) (*dto.BrokerSyncReport, error) {
+	provider, err := s.provider(connection.Provider)
+	if err != nil {
+		return nil, err
+	}
+	accessToken, err := s.box.Open(connection.Credentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open access token: %w", err)
+	}
+
+	now := s.now()
+	report := &dto.BrokerSyncReport{
+		ConnectionID: connection.ID,
+		SyncedAt:     now,
+		From:         connection.SyncFrom,
+		DryRun:       dryRun,
+		Missing:      []dto.BrokerTransactionDiff{},
+		Mismatched:   []dto.BrokerTransactionDiff{},
+		LocalOnly:    []dto.BrokerTransactionDiff{},
+		Positions:    []dto.BrokerPositionDiff{},
+	}
+
+	brokerTransactions, err := provider.GetTransactions(ctx, accessToken, connection.AccountID, connection.SyncFrom, now)
+	if err != nil {
+		return nil, s.failSync(ctx, connection, err)
+	}
+
+	local, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, connection.PortfolioID.String(), nil, &connection.SyncFrom, &now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	missing := reconcileBrokerTransactions(report, brokerTransactions, local)
+
+	if len(missing) > 0 {
+		if err := s.importMissing(ctx, connection, userID, missing, report); err != nil {
+			return nil, err
+		}
+	}
+
+	positions, err := provider.GetPositions(ctx, accessToken, connection.AccountID)
+	if err != nil {
+		return nil, s.failSync(ctx, connection, err)
+	}
+	holdings, err := s.holdingRepo.FindByPortfolioID(ctx, connection.PortfolioID.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holdings: %w", err)
+	}
+	report.Positions = diffBrokerPositions(positions, holdings)
+
+	if dryRun {
+		return report, nil
+	}
+
+	connection.Status = models.BrokerConnectionStatusActive
+	connection.LastSyncedAt = &now
+	connection.LastSyncError = ""
+	if err := connection.SetSyncReport(report); err != nil {
+		return nil, err
+	}
+	if err := s.connectionRepo.Update(ctx, connection); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// importMissing imports the broker transactions a portfolio lacks as one import batch and
+// rebuilds the portfolio's holdings and tax lots from its ledger
+func (s *brokerConnectionService) importMissing(ctx context.Context, connection *models.BrokerConnection, userID string, missing []BrokerTransaction, report *dto.BrokerSyncReport) error {
+	transactions := make([]dto.ImportTransactionRequest, 0, len(missing))
+	for _, tx := range missing {
+		transactions = append(transactions, dto.ImportTransactionRequest{
+			Type:       tx.Type,
+			Symbol:     tx.Symbol,
+			Date:       tx.Date,
+			Quantity:   tx.Quantity,
+			Price:      tx.Price,
+			Commission: tx.Commission,
+			Currency:   tx.Currency,
+			Notes:      tx.Description,
+			RawData:    tx.ExternalID,
+		})
+	}
+
+	name := connection.Name
+	if name == "" {
+		name = string(connection.Provider) + " " + connection.AccountID
+	}
+	result, _, err := importAndReplay(ctx, s.importService, s.recalculationService, connection.PortfolioID.String(), userID, dto.BulkImportRequest{
+		Format:       dto.ImportFormatPlaid,
+		Transactions: transactions,
+		DryRun:       report.DryRun,
+		SkipInvalid:  true,
+		Notes:        "Synced from " + name,
+	})
+	if err != nil {
+		return err
+	}
+	report.Errors = result.Errors
+	if report.DryRun || result.SuccessCount == 0 {
+		return nil
+	}
+
+	batchID := result.BatchID
+	report.BatchID = &batchID
+	report.Imported = result.SuccessCount
+	return nil
+}
+
+// failSync records a provider failure on a connection and returns the error to report
+func (s *brokerConnectionService) failSync(ctx context.Context, connection *models.BrokerConnection, cause error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+
+	connection.Status = models.BrokerConnectionStatusFailed
+	connection.LastSyncError = cause.Error()
+	if err := s.connectionRepo.Update(ctx, connection); err != nil {
+		return errors.Join(fmt.Errorf("%w: %v", models.ErrBrokerSyncFailed, cause), err)
+	}
+	return fmt.Errorf("%w: %v", models.ErrBrokerSyncFailed, cause)
+}
+
+// brokerSyncKey identifies the transactions of one kind in one symbol on one day, which
+// broker and portfolio transactions are paired within
+type brokerSyncKey struct {
+	date   string
+	txType models.TransactionType
+	symbol string
+}
+
+// reconcileBrokerTransactions pairs broker transactions with the portfolio's, filling in
+// the report, and returns the broker transactions the portfolio lacks. A pair with the
+// same quantity is a match unless its prices differ by a cent or more; transactions left
+// over on both sides under one key are paired as mismatches.
+func reconcileBrokerTransactions(report *dto.BrokerSyncReport, broker []BrokerTransaction, local []*models.Transaction) []BrokerTransaction {
+	candidates := make(map[brokerSyncKey][]*models.Transaction)
+	for _, tx := range local {
+		if !brokerSyncedTypes[tx.Type] {
+			continue
+		}
+		key := brokerSyncKey{tx.Date.Format("2006-01-02"), tx.Type, strings.ToUpper(tx.Symbol)}
+		candidates[key] = append(candidates[key], tx)
+	}
+	take := func(key brokerSyncKey, match func(*models.Transaction) bool) *models.Transaction {
+		for i, tx := range candidates[key] {
+			if match(tx) {
+				candidates[key] = append(candidates[key][:i:i], candidates[key][i+1:]...)
+				return tx
+			}
+		}
+		return nil
+	}
+
+	var unpaired []BrokerTransaction
+	for _, tx := range broker {
+		if tx.Type == "" {
+			report.Ignored++
+			continue
+		}
+		key := brokerSyncKey{tx.Date.Format("2006-01-02"), tx.Type, strings.ToUpper(tx.Symbol)}
+		quantity := tx.Quantity.Round(8)
+		pair := take(key, func(local *models.Transaction) bool { return local.Quantity.Round(8).Equal(quantity) })
+		if pair == nil {
+			unpaired = append(unpaired, tx)
+			continue
+		}
+		if pricesDiffer(tx.Price, pair.Price) {
+			report.Mismatched = append(report.Mismatched, brokerTransactionDiff(tx, pair))
+			continue
+		}
+		report.Matched++
+	}
+
+	var missing []BrokerTransaction
+	for _, tx := range unpaired {
+		key := brokerSyncKey{tx.Date.Format("2006-01-02"), tx.Type, strings.ToUpper(tx.Symbol)}
+		if pair := take(key, func(*models.Transaction) bool { return true }); pair != nil {
+			report.Mismatched = append(report.Mismatched, brokerTransactionDiff(tx, pair))
+			continue
+		}
+		missing = append(missing, tx)
+		report.Missing = append(report.Missing, brokerTransactionDiff(tx, nil))
+	}
+
+	// Whatever wasn't paired is only in the portfolio; report it in ledger order
+	unused := make(map[*models.Transaction]bool)
+	for _, remaining := range candidates {
+		for _, tx := range remaining {
+			unused[tx] = true
+		}
+	}
+	for _, tx := range local {
+		if unused[tx] {
+			report.LocalOnly = append(report.LocalOnly, localTransactionDiff(tx))
+		}
+	}
+	return missing
+}
+
+// pricesDiffer reports whether two prices differ once rounded to the cent. A missing
+// price differs only from a present one.
+func pricesDiffer(broker, local *decimal.Decimal) bool {
+	if broker == nil || local == nil {
+		return (broker == nil) != (local == nil)
+	}
+	return !broker.Round(2).Equal(local.Round(2))
+}
+
+// brokerTransactionDiff describes a broker transaction and, if set, its portfolio counterpart
+func brokerTransactionDiff(tx BrokerTransaction, local *models.Transaction) dto.BrokerTransactionDiff {
+	quantity := tx.Quantity
+	diff := dto.BrokerTransactionDiff{
+		Date:           tx.Date,
+		Type:           tx.Type,
+		Symbol:         tx.Symbol,
+		ExternalID:     tx.ExternalID,
+		BrokerQuantity: &quantity,
+		BrokerPrice:    tx.Price,
+	}
+	if local != nil {
+		diff.TransactionID = &local.ID
+		diff.LocalQuantity = &local.Quantity
+		diff.LocalPrice = local.Price
+	}
+	return diff
+}
+
+// localTransactionDiff describes a portfolio transaction the broker doesn't have
+func localTransactionDiff(tx *models.Transaction) dto.BrokerTransactionDiff {
+	return dto.BrokerTransactionDiff{
+		Date:          tx.Date,
+		Type:          tx.Type,
+		Symbol:        tx.Symbol,
+		TransactionID: &tx.ID,
+		LocalQuantity: &tx.Quantity,
+		LocalPrice:    tx.Price,
+	}
+}
+
+// diffBrokerPositions returns the symbols whose quantity held differs between the broker's
+// positions and the portfolio's holdings, in symbol order
+func diffBrokerPositions(positions []BrokerPosition, holdings []*models.Holding) []dto.BrokerPositionDiff {
+	broker := make(map[string]decimal.Decimal, len(positions))
+	for _, position := range positions {
+		symbol := strings.ToUpper(position.Symbol)
+		broker[symbol] = broker[symbol].Add(position.Quantity)
+	}
+	local := make(map[string]decimal.Decimal, len(holdings))
+	for _, holding := range holdings {
+		local[strings.ToUpper(holding.Symbol)] = holding.Quantity
+	}
+
+	symbols := make(map[string]bool, len(broker)+len(local))
+	for symbol := range broker {
+		symbols[symbol] = true
+	}
+	for symbol, quantity := range local {
+		if !quantity.IsZero() {
+			symbols[symbol] = true
+		}
+	}
+
+	diffs := []dto.BrokerPositionDiff{}
+	for symbol := range symbols {
+		difference := broker[symbol].Sub(local[symbol])
+		if difference.Round(8).IsZero() {
+			continue
+		}
+		diffs = append(diffs, dto.BrokerPositionDiff{
+			Symbol:         symbol,
+			BrokerQuantity: broker[symbol],
+			LocalQuantity:  local[symbol],
+			Difference:     difference,
+		})
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Symbol < diffs[j].Symbol })
+	return diffs
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/utils"
+)
+
+// fakeBrokerProvider serves fixed positions and transactions for any account
+type fakeBrokerProvider struct {
+	positions    []BrokerPosition
+	transactions []BrokerTransaction
+	err          error
+	tokens       []string
+}
+
+func (p *fakeBrokerProvider) Name() models.BrokerProvider {
+	return models.BrokerProviderPlaid
+}
+
+func (p *fakeBrokerProvider) GetPositions(_ context.Context, accessToken, _ string) ([]BrokerPosition, error) {
+	p.tokens = append(p.tokens, accessToken)
+	return p.positions, p.err
+}
+
+func (p *fakeBrokerProvider) GetTransactions(_ context.Context, accessToken, _ string, _, _ time.Time) ([]BrokerTransaction, error) {
+	p.tokens = append(p.tokens, accessToken)
+	return p.transactions, p.err
+}
+
+func setupBrokerConnectionTest(t *testing.T, provider *fakeBrokerProvider) (*gorm.DB, BrokerConnectionService, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.Holding{}, &models.TaxLot{}, &models.BrokerConnection{},
+	))
+
+	user := &models.User{Email: "broker@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Brokerage",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	box, err := utils.NewSecretBox("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	require.NoError(t, err)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	service := NewBrokerConnectionService(
+		repository.NewBrokerConnectionRepository(db),
+		portfolioRepo,
+		transactionRepo,
+		holdingRepo,
+		NewCSVImportService(transactionRepo, portfolioRepo, holdingRepo),
+		NewPortfolioRecalculationService(db),
+		box,
+		provider,
+	)
+	service.(*brokerConnectionService).now = func() time.Time { return time.Date(2024, 3, 31, 18, 0, 0, 0, time.UTC) }
+	return db, service, user, portfolio
+}
+
+func brokerPrice(value string) *decimal.Decimal {
+	price := decimal.RequireFromString(value)
+	return &price
+}
+
+func TestBrokerConnectionService_Connect(t *testing.T) {
+	provider := &fakeBrokerProvider{}
+	db, service, user, portfolio := setupBrokerConnectionTest(t, provider)
+	ctx := context.Background()
+
+	connection, err := service.Connect(ctx, portfolio.ID.String(), user.ID.String(), dto.CreateBrokerConnectionRequest{
+		Provider:    models.BrokerProviderPlaid,
+		Name:        "Brokerage",
+		AccessToken: "access-sandbox-123",
+		AccountID:   "acc-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"access-sandbox-123"}, provider.tokens)
+	assert.Equal(t, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), connection.SyncFrom)
+
+	// The access token is only stored sealed
+	var stored models.BrokerConnection
+	require.NoError(t, db.First(&stored, "id = ?", connection.ID).Error)
+	assert.NotContains(t, stored.Credentials, "access-sandbox-123")
+
+	connections, err := service.List(ctx, portfolio.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	assert.Len(t, connections, 1)
+
+	_, err = service.List(ctx, portfolio.ID.String(), uuid.New().String())
+	assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+
+	// A token the provider rejects isn't saved
+	provider.err = errors.New("ITEM_LOGIN_REQUIRED")
+	_, err = service.Connect(ctx, portfolio.ID.String(), user.ID.String(), dto.CreateBrokerConnectionRequest{
+		Provider: models.BrokerProviderPlaid, AccessToken: "bad", AccountID: "acc-2",
+	})
+	assert.ErrorIs(t, err, models.ErrBrokerSyncFailed)
+
+	require.NoError(t, service.Disconnect(ctx, connection.ID.String(), portfolio.ID.String(), user.ID.String()))
+	_, err = service.Get(ctx, connection.ID.String(), portfolio.ID.String(), user.ID.String())
+	assert.ErrorIs(t, err, models.ErrBrokerConnectionNotFound)
+}
+
+func TestBrokerConnectionService_Unavailable(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}))
+	user := &models.User{Email: "broker@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Brokerage", BaseCurrency: "USD"}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	service := NewBrokerConnectionService(nil, repository.NewPortfolioRepository(db), nil, nil, nil, nil, nil, &fakeBrokerProvider{})
+	_, err = service.Connect(context.Background(), portfolio.ID.String(), user.ID.String(), dto.CreateBrokerConnectionRequest{
+		Provider: models.BrokerProviderPlaid, AccessToken: "token", AccountID: "acc-1",
+	})
+	assert.ErrorIs(t, err, models.ErrBrokerSyncUnavailable)
+}
+
+func TestBrokerConnectionService_Sync(t *testing.T) {
+	provider := &fakeBrokerProvider{}
+	db, service, user, portfolio := setupBrokerConnectionTest(t, provider)
+	ctx := context.Background()
+
+	syncFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	connection, err := service.Connect(ctx, portfolio.ID.String(), user.ID.String(), dto.CreateBrokerConnectionRequest{
+		Provider: models.BrokerProviderPlaid, AccessToken: "access-sandbox-123", AccountID: "acc-1", SyncFrom: &syncFrom,
+	})
+	require.NoError(t, err)
+
+	// The portfolio already has the January buy, records the February buy at another price,
+	// and has a March sell the broker doesn't know about
+	transactionRepo := repository.NewTransactionRepository(db)
+	transactionService := NewTransactionService(transactionRepo, repository.NewPortfolioRepository(db), repository.NewHoldingRepository(db))
+	record := func(txType models.TransactionType, symbol string, date time.Time, quantity int64, price string) *models.Transaction {
+		tx, err := transactionService.Create(ctx, portfolio.ID.String(), user.ID.String(), txType, symbol, date,
+			decimal.NewFromInt(quantity), decimal.RequireFromString(price), decimal.Zero, "USD", "", "")
+		require.NoError(t, err)
+		return tx
+	}
+	record(models.TransactionTypeBuy, "AAPL", time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), 10, "150.001")
+	februaryBuy := record(models.TransactionTypeBuy, "MSFT", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), 5, "400")
+	marchSell := record(models.TransactionTypeSell, "MSFT", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), 1, "410")
+	// History before the sync date is left alone
+	record(models.TransactionTypeBuy, "VTI", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), 2, "200")
+
+	provider.transactions = []BrokerTransaction{
+		{ExternalID: "t1", Type: models.TransactionTypeBuy, Symbol: "AAPL", Date: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(10), Price: brokerPrice("150"), Currency: "USD"},
+		{ExternalID: "t2", Type: models.TransactionTypeBuy, Symbol: "MSFT", Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(5), Price: brokerPrice("401.50"), Currency: "USD"},
+		{ExternalID: "t3", Type: models.TransactionTypeDividend, Symbol: "AAPL", Date: time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), Quantity: decimal.RequireFromString("2.40"), Currency: "USD"},
+		{ExternalID: "t4", Type: models.TransactionTypeBuy, Symbol: "NVDA", Date: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(3), Price: brokerPrice("850"), Currency: "USD"},
+		{ExternalID: "t5", Date: time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC), Description: "Transfer"},
+	}
+	provider.positions = []BrokerPosition{
+		{Symbol: "AAPL", Quantity: decimal.NewFromInt(10)},
+		{Symbol: "MSFT", Quantity: decimal.NewFromInt(5)},
+		{Symbol: "NVDA", Quantity: decimal.NewFromInt(3)},
+		{Symbol: "VTI", Quantity: decimal.NewFromInt(2)},
+	}
+
+	// A dry run reports without importing or touching the connection
+	report, err := service.Sync(ctx, connection.ID.String(), portfolio.ID.String(), user.ID.String(), true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Missing, 2)
+	assert.Zero(t, report.Imported)
+	assert.Nil(t, report.BatchID)
+	stored, err := service.Get(ctx, connection.ID.String(), portfolio.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	assert.Nil(t, stored.LastSyncedAt)
+
+	report, err = service.Sync(ctx, connection.ID.String(), portfolio.ID.String(), user.ID.String(), false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 1, report.Matched)
+	assert.Equal(t, 1, report.Ignored)
+	assert.Equal(t, 2, report.Imported)
+	require.NotNil(t, report.BatchID)
+
+	require.Len(t, report.Missing, 2)
+	assert.Equal(t, "t3", report.Missing[0].ExternalID)
+	assert.Equal(t, "t4", report.Missing[1].ExternalID)
+
+	require.Len(t, report.Mismatched, 1)
+	assert.Equal(t, "t2", report.Mismatched[0].ExternalID)
+	assert.Equal(t, februaryBuy.ID, *report.Mismatched[0].TransactionID)
+	assert.True(t, report.Mismatched[0].LocalPrice.Equal(decimal.NewFromInt(400)))
+
+	require.Len(t, report.LocalOnly, 1)
+	assert.Equal(t, marchSell.ID, *report.LocalOnly[0].TransactionID)
+
+	// The unrecorded sell leaves the portfolio a share of MSFT short of the broker
+	require.Len(t, report.Positions, 1)
+	assert.Equal(t, "MSFT", report.Positions[0].Symbol)
+	assert.True(t, report.Positions[0].Difference.Equal(decimal.NewFromInt(1)))
+
+	// The imported buy has its tax lot
+	lots, err := repository.NewTaxLotRepository(db).FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "NVDA")
+	require.NoError(t, err)
+	assert.Len(t, lots, 1)
+
+	stored, err = service.Get(ctx, connection.ID.String(), portfolio.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastSyncedAt)
+	lastSync := dto.ToBrokerConnectionResponse(stored).LastSync
+	require.NotNil(t, lastSync)
+	assert.Equal(t, 2, lastSync.Imported)
+
+	// Syncing again finds nothing new
+	report, err = service.Sync(ctx, connection.ID.String(), portfolio.ID.String(), user.ID.String(), false)
+	require.NoError(t, err)
+	assert.Empty(t, report.Missing)
+	assert.Equal(t, 3, report.Matched)
+}
+
+func TestBrokerConnectionService_SyncAll(t *testing.T) {
+	provider := &fakeBrokerProvider{}
+	_, service, user, portfolio := setupBrokerConnectionTest(t, provider)
+	ctx := context.Background()
+
+	connection, err := service.Connect(ctx, portfolio.ID.String(), user.ID.String(), dto.CreateBrokerConnectionRequest{
+		Provider: models.BrokerProviderPlaid, AccessToken: "access-sandbox-123", AccountID: "acc-1",
+	})
+	require.NoError(t, err)
+
+	synced, failed, err := service.SyncAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, synced)
+	assert.Zero(t, failed)
+
+	// A failing provider marks the connection failed
+	provider.err = errors.New("ITEM_LOGIN_REQUIRED")
+	synced, failed, err = service.SyncAll(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, synced)
+	assert.Equal(t, 1, failed)
+
+	stored, err := service.Get(ctx, connection.ID.String(), portfolio.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.BrokerConnectionStatusFailed, stored.Status)
+	assert.Equal(t, "ITEM_LOGIN_REQUIRED", stored.LastSyncError)
+}
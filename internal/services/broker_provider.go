@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// BrokerDataProvider defines the interface for brokerage data aggregators that broker
+// connections sync from
+type BrokerDataProvider interface {
+	// Name returns the provider broker connections are made with
+	Name() models.BrokerProvider
+
+	// GetPositions retrieves an account's current positions
+	GetPositions(ctx context.Context, accessToken, accountID string) ([]BrokerPosition, error)
+
+	// GetTransactions retrieves an account's transactions dated from start to end, oldest first
+	GetTransactions(ctx context.Context, accessToken, accountID string, start, end time.Time) ([]BrokerTransaction, error)
+}
+
+// BrokerPosition is a position a broker reports for an account
+type BrokerPosition struct {
+	Symbol    string
+	Quantity  decimal.Decimal
+	CostBasis *decimal.Decimal // Total cost basis, when the broker reports it
+	Currency  string
+}
+
+// BrokerTransaction is a transaction a broker reports for an account, mapped to the
+// transaction it records in a portfolio. Activity that isn't tracked has no Type.
+type BrokerTransaction struct {
+	ExternalID  string
+	Type        models.TransactionType
+	Symbol      string
+	Date        time.Time
+	Quantity    decimal.Decimal  // Shares traded, or the amount of cash transactions
+	Price       *decimal.Decimal // Set for trades
+	Commission  decimal.Decimal
+	Currency    string
+	Description string
+}
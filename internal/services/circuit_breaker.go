@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// CircuitState is whether a circuit breaker lets provider requests through
+type CircuitState string
+
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen fails every request without calling the provider
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen lets a single probe through to see whether the provider has recovered
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// errCircuitOpen is returned for requests a circuit breaker doesn't let through
+var errCircuitOpen = fmt.Errorf("circuit breaker is open: %w", models.ErrMarketDataUnavailable)
+
+// CircuitBreaker stops calling a provider that keeps failing, so that requests needing it
+// fail fast instead of each waiting for a timeout. It opens after failureThreshold failures
+// in a row, and once cooldown has passed lets one probe through: the breaker closes if the
+// probe succeeds and opens again if it fails. Only timeouts and network errors are failures;
+// errors the provider answered with show it is reachable. A nil breaker never opens.
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	provider         string
+	failureThreshold int
+	cooldown         time.Duration
+	state            CircuitState
+	failures         int
+	openedAt         time.Time
+	probing          bool
+	now              func() time.Time
+}
+
+// NewCircuitBreaker creates a new CircuitBreaker for the given provider. A failureThreshold of
+// zero returns nil, which never opens.
+func NewCircuitBreaker(provider string, failureThreshold int, cooldown time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		return nil
+	}
+	return &CircuitBreaker{
+		provider:         provider,
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		state:            CircuitClosed,
+		now:              func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Allow reports whether a request may be made to the provider. It returns an error wrapping
+// models.ErrMarketDataUnavailable while the breaker is open, or while another request is
+// probing a half-open breaker. Every allowed request must be followed by Record.
+func (b *CircuitBreaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		b.state = CircuitHalfOpen
+	}
+	switch b.state {
+	case CircuitOpen:
+		return fmt.Errorf("%s is failing, retrying after %s: %w",
+			b.name(), b.openedAt.Add(b.cooldown).Format(time.RFC3339), errCircuitOpen)
+	case CircuitHalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s is failing, waiting on a probe: %w", b.name(), errCircuitOpen)
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports how an allowed request went. Timeouts and network errors count towards
+// opening the breaker, and any answer from the provider closes it. Requests that never
+// reached the provider, because they were canceled or out of quota, change nothing.
+func (b *CircuitBreaker) Record(err error) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	probe := b.probing
+	b.probing = false
+	switch {
+	case err != nil && !reachedProvider(err):
+		// A probe that never reached the provider leaves the breaker half-open for the next one
+	case err != nil && isProviderFailure(err):
+		b.failures++
+		if probe || b.failures >= b.failureThreshold {
+			b.state = CircuitOpen
+			b.openedAt = b.now()
+		}
+	default:
+		b.state = CircuitClosed
+		b.failures = 0
+	}
+}
+
+// State returns whether the breaker lets requests through
+func (b *CircuitBreaker) State() CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && !b.now().Before(b.openedAt.Add(b.cooldown)) {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// name describes the provider in errors
+func (b *CircuitBreaker) name() string {
+	if b.provider == "" {
+		return "market data provider"
+	}
+	return "market data provider " + b.provider
+}
+
+// reachedProvider returns false for errors of requests that were never sent
+func reachedProvider(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, models.ErrQuotaExceeded)
+}
+
+// isProviderFailure returns true for errors showing the provider couldn't be reached or
+// didn't answer in time
+func isProviderFailure(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// providerAttempts collects the outcomes of several requests made to a provider under one
+// Allow, so that Record can be called once for all of them. The provider is reachable if it
+// answered any of them.
+type providerAttempts struct {
+	mu       sync.Mutex
+	answered bool
+	err      error
+}
+
+// add records the outcome of a request
+func (a *providerAttempts) add(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err == nil || (reachedProvider(err) && !isProviderFailure(err)) {
+		a.answered = true
+	} else if a.err == nil {
+		a.err = err
+	}
+}
+
+// result returns the error to record for the requests
+func (a *providerAttempts) result() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.answered {
+		return nil
+	}
+	return a.err
+}
+
+// callThrough calls the provider through breaker, failing fast while it is open
+func callThrough[T any](breaker *CircuitBreaker, call func() (T, error)) (T, error) {
+	if err := breaker.Allow(); err != nil {
+		var zero T
+		return zero, err
+	}
+	result, err := call()
+	breaker.Record(err)
+	return result, err
+}
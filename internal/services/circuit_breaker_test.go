@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func newTestCircuitBreaker(failureThreshold int, now *time.Time) *CircuitBreaker {
+	b := NewCircuitBreaker("alphavantage", failureThreshold, 30*time.Second)
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	now := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	b := newTestCircuitBreaker(3, &now)
+	timeout := &net.OpError{Op: "dial", Err: errors.New("i/o timeout")}
+
+	for range 2 {
+		require.NoError(t, b.Allow())
+		b.Record(timeout)
+	}
+	// A success starts the count over
+	require.NoError(t, b.Allow())
+	b.Record(nil)
+	for range 2 {
+		require.NoError(t, b.Allow())
+		b.Record(context.DeadlineExceeded)
+	}
+	assert.Equal(t, CircuitClosed, b.State())
+
+	require.NoError(t, b.Allow())
+	b.Record(timeout)
+	assert.Equal(t, CircuitOpen, b.State())
+
+	err := b.Allow()
+	assert.ErrorIs(t, err, models.ErrMarketDataUnavailable)
+	assert.ErrorIs(t, err, errCircuitOpen)
+}
+
+func TestCircuitBreaker_IgnoresErrorsFromTheProvider(t *testing.T) {
+	now := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	b := newTestCircuitBreaker(1, &now)
+
+	// The provider answered, or was never asked
+	for _, err := range []error{errors.New("invalid symbol"), models.ErrQuotaExceeded, context.Canceled} {
+		require.NoError(t, b.Allow())
+		b.Record(err)
+	}
+	assert.Equal(t, CircuitClosed, b.State())
+}
+
+func TestCircuitBreaker_ProbesAfterCooldown(t *testing.T) {
+	now := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	b := newTestCircuitBreaker(1, &now)
+
+	require.NoError(t, b.Allow())
+	b.Record(context.DeadlineExceeded)
+	assert.Error(t, b.Allow())
+
+	// A single probe is let through after the cooldown, and reopens the breaker if it fails
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+	require.NoError(t, b.Allow())
+	assert.Error(t, b.Allow())
+	b.Record(context.DeadlineExceeded)
+	assert.Equal(t, CircuitOpen, b.State())
+	assert.Error(t, b.Allow())
+
+	// A probe that never reached the provider leaves the next request to probe
+	now = now.Add(30 * time.Second)
+	require.NoError(t, b.Allow())
+	b.Record(models.ErrQuotaExceeded)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+
+	require.NoError(t, b.Allow())
+	b.Record(nil)
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	b := NewCircuitBreaker("alphavantage", 0, time.Minute)
+	assert.Nil(t, b)
+
+	for range 10 {
+		require.NoError(t, b.Allow())
+		b.Record(context.DeadlineExceeded)
+	}
+	assert.Equal(t, CircuitClosed, b.State())
+}
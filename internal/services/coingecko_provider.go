@@ -0,0 +1,367 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+const (
+	coinGeckoBaseURL = "https://api.coingecko.com/api/v3"
+)
+
+// coinGeckoCoinIDs maps the most traded coin symbols to their CoinGecko IDs. Many symbols
+// are shared by several coins on CoinGecko, so the well-known ones are pinned here and only
+// others are looked up in the coin list.
+var coinGeckoCoinIDs = map[string]string{
+	"BTC":   "bitcoin",
+	"ETH":   "ethereum",
+	"USDT":  "tether",
+	"USDC":  "usd-coin",
+	"BNB":   "binancecoin",
+	"SOL":   "solana",
+	"XRP":   "ripple",
+	"ADA":   "cardano",
+	"DOGE":  "dogecoin",
+	"TRX":   "tron",
+	"DOT":   "polkadot",
+	"MATIC": "matic-network",
+	"LTC":   "litecoin",
+	"BCH":   "bitcoin-cash",
+	"AVAX":  "avalanche-2",
+	"LINK":  "chainlink",
+	"XLM":   "stellar",
+	"ATOM":  "cosmos",
+	"XMR":   "monero",
+	"ETC":   "ethereum-classic",
+	"UNI":   "uniswap",
+	"SHIB":  "shiba-inu",
+	"DAI":   "dai",
+	"ALGO":  "algorand",
+}
+
+// CoinGeckoProvider implements MarketDataProvider for coin pairs such as BTC-USD using the
+// CoinGecko API. Coins trade around the clock, so quotes and history cover every calendar
+// day, and each pair is priced in its quote currency.
+type CoinGeckoProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu      sync.Mutex
+	coinIDs map[string]string // symbols resolved from the coin list, loaded on first use
+}
+
+// NewCoinGeckoProvider creates a new CoinGecko crypto market data provider. The API key is
+// optional: without one requests use the public rate limits.
+func NewCoinGeckoProvider(apiKey string) *CoinGeckoProvider {
+	return &CoinGeckoProvider{
+		apiKey:  apiKey,
+		baseURL: coinGeckoBaseURL,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		now: func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// IsAvailable reports that the provider is usable; CoinGecko's public API needs no key
+func (p *CoinGeckoProvider) IsAvailable() bool {
+	return true
+}
+
+// GetQuote retrieves the current price of a coin pair
+func (p *CoinGeckoProvider) GetQuote(ctx context.Context, symbol string) (*Quote, error) {
+	quotes, err := p.GetQuotes(ctx, []string{symbol})
+	if err != nil {
+		return nil, err
+	}
+
+	quote, ok := quotes[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no data found for symbol %s", symbol)
+	}
+	return quote, nil
+}
+
+// GetQuotes retrieves the current prices of coin pairs in a single request. Symbols that
+// aren't coin pairs or whose coin is unknown are left out of the result.
+func (p *CoinGeckoProvider) GetQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	result := make(map[string]*Quote)
+
+	type pair struct {
+		symbol, coinID, vsCurrency string
+	}
+	var pairs []pair
+	ids := map[string]bool{}
+	vsCurrencies := map[string]bool{}
+	for _, symbol := range symbols {
+		coin, quoteCurrency, ok := models.CryptoPair(symbol)
+		if !ok {
+			continue
+		}
+		coinID, err := p.coinID(ctx, coin)
+		if err != nil {
+			continue
+		}
+		vsCurrency := strings.ToLower(quoteCurrency)
+		pairs = append(pairs, pair{symbol: symbol, coinID: coinID, vsCurrency: vsCurrency})
+		ids[coinID] = true
+		vsCurrencies[vsCurrency] = true
+	}
+	if len(pairs) == 0 {
+		return result, nil
+	}
+
+	params := url.Values{}
+	params.Set("ids", strings.Join(sortedKeys(ids), ","))
+	params.Set("vs_currencies", strings.Join(sortedKeys(vsCurrencies), ","))
+	params.Set("include_24hr_vol", "true")
+	params.Set("include_24hr_change", "true")
+	params.Set("include_last_updated_at", "true")
+
+	var prices map[string]map[string]decimal.Decimal
+	if err := p.get(ctx, "/simple/price", params, &prices); err != nil {
+		return nil, fmt.Errorf("failed to fetch quotes: %w", err)
+	}
+
+	now := p.now()
+	for _, pair := range pairs {
+		fields, ok := prices[pair.coinID]
+		if !ok {
+			continue
+		}
+		price, ok := fields[pair.vsCurrency]
+		if !ok {
+			continue
+		}
+
+		quote := &Quote{
+			Symbol:      pair.symbol,
+			Price:       price,
+			LastUpdated: now,
+		}
+		if updatedAt, ok := fields["last_updated_at"]; ok {
+			quote.LastUpdated = time.Unix(updatedAt.IntPart(), 0).UTC()
+		}
+		if volume, ok := fields[pair.vsCurrency+"_24h_vol"]; ok {
+			quote.Volume = volume.IntPart()
+		}
+		if changePct, ok := fields[pair.vsCurrency+"_24h_change"]; ok {
+			// CoinGecko reports the change over the last 24 hours; that price stands in for
+			// the previous close of markets that never close
+			previous := price.Div(decimal.NewFromInt(1).Add(changePct.Div(decimal.NewFromInt(100))))
+			quote.PreviousClose = previous.Round(8)
+			quote.Change = price.Sub(quote.PreviousClose)
+			quote.ChangePercent = changePct.Round(4)
+		}
+		quote.LatestTradingDay = time.Date(quote.LastUpdated.Year(), quote.LastUpdated.Month(), quote.LastUpdated.Day(), 0, 0, 0, 0, time.UTC)
+		quote.TradingStatus = DetectContinuousTradingStatus(quote.LastUpdated, now)
+
+		result[pair.symbol] = quote
+	}
+
+	return result, nil
+}
+
+// GetHistoricalPrices retrieves daily prices for a coin pair. Every calendar day has a
+// price; the close of a day is the last price recorded on it in UTC.
+func (p *CoinGeckoProvider) GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+	coin, quoteCurrency, ok := models.CryptoPair(symbol)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a coin pair", symbol)
+	}
+	coinID, err := p.coinID(ctx, coin)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("vs_currency", strings.ToLower(quoteCurrency))
+	params.Set("from", strconv.FormatInt(startDate.Unix(), 10))
+	params.Set("to", strconv.FormatInt(endDate.AddDate(0, 0, 1).Unix(), 10))
+
+	var result struct {
+		Prices       [][2]decimal.Decimal `json:"prices"`
+		TotalVolumes [][2]decimal.Decimal `json:"total_volumes"`
+	}
+	if err := p.get(ctx, "/coins/"+url.PathEscape(coinID)+"/market_chart/range", params, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch historical data: %w", err)
+	}
+
+	startDay := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	endDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
+
+	byDay := make(map[time.Time]*HistoricalPrice)
+	for _, point := range result.Prices {
+		at := time.UnixMilli(point[0].IntPart()).UTC()
+		day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		if day.Before(startDay) || day.After(endDay) {
+			continue
+		}
+
+		price := point[1]
+		daily, ok := byDay[day]
+		if !ok {
+			byDay[day] = &HistoricalPrice{Date: day, Open: price, High: price, Low: price, Close: price}
+			continue
+		}
+		if price.GreaterThan(daily.High) {
+			daily.High = price
+		}
+		if price.LessThan(daily.Low) {
+			daily.Low = price
+		}
+		daily.Close = price
+	}
+	for _, point := range result.TotalVolumes {
+		at := time.UnixMilli(point[0].IntPart()).UTC()
+		day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		if daily, ok := byDay[day]; ok {
+			daily.Volume = point[1].IntPart()
+		}
+	}
+
+	prices := make([]*HistoricalPrice, 0, len(byDay))
+	for _, daily := range byDay {
+		prices = append(prices, daily)
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Date.Before(prices[j].Date)
+	})
+
+	return prices, nil
+}
+
+// GetExchangeRate retrieves the exchange rate between two currencies or coins from
+// CoinGecko's Bitcoin-denominated rate table
+func (p *CoinGeckoProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error) {
+	if fromCurrency == toCurrency {
+		return decimal.NewFromInt(1), nil
+	}
+
+	var result struct {
+		Rates map[string]struct {
+			Value decimal.Decimal `json:"value"`
+		} `json:"rates"`
+	}
+	if err := p.get(ctx, "/exchange_rates", nil, &result); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to fetch exchange rate: %w", err)
+	}
+
+	from, ok := result.Rates[strings.ToLower(fromCurrency)]
+	if !ok || from.Value.IsZero() {
+		return decimal.Zero, fmt.Errorf("no exchange rate for %s", fromCurrency)
+	}
+	to, ok := result.Rates[strings.ToLower(toCurrency)]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("no exchange rate for %s", toCurrency)
+	}
+
+	return to.Value.Div(from.Value), nil
+}
+
+// coinID returns the CoinGecko ID of a coin symbol, looking it up in the coin list when
+// it isn't one of the pinned coins. Symbols shared by several coins are rejected rather
+// than priced as the wrong coin.
+func (p *CoinGeckoProvider) coinID(ctx context.Context, coin string) (string, error) {
+	if id, ok := coinGeckoCoinIDs[coin]; ok {
+		return id, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.coinIDs == nil {
+		var coins []struct {
+			ID     string `json:"id"`
+			Symbol string `json:"symbol"`
+		}
+		if err := p.get(ctx, "/coins/list", nil, &coins); err != nil {
+			return "", fmt.Errorf("failed to fetch coin list: %w", err)
+		}
+
+		p.coinIDs = make(map[string]string, len(coins))
+		for _, c := range coins {
+			symbol := strings.ToUpper(c.Symbol)
+			if _, seen := p.coinIDs[symbol]; seen {
+				p.coinIDs[symbol] = "" // ambiguous
+				continue
+			}
+			p.coinIDs[symbol] = c.ID
+		}
+	}
+
+	id, ok := p.coinIDs[coin]
+	if !ok {
+		return "", fmt.Errorf("unknown coin %s", coin)
+	}
+	if id == "" {
+		return "", fmt.Errorf("coin symbol %s is shared by several coins", coin)
+	}
+	return id, nil
+}
+
+// get sends a GET request to a CoinGecko endpoint and decodes the JSON response into out
+func (p *CoinGeckoProvider) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	reqURL := p.baseURL + path
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("x-cg-demo-api-key", p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return fmt.Errorf("API rate limit exceeded")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of set in sorted order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
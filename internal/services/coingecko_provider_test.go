@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+// newTestCoinGeckoProvider returns a provider whose requests go to server
+func newTestCoinGeckoProvider(server *httptest.Server, apiKey string) *CoinGeckoProvider {
+	provider := NewCoinGeckoProvider(apiKey)
+	provider.httpClient = &http.Client{Transport: &mockTransport{server: server}}
+	return provider
+}
+
+func TestNewCoinGeckoProvider(t *testing.T) {
+	provider := NewCoinGeckoProvider("")
+	assert.NotNil(t, provider.httpClient)
+	assert.Equal(t, coinGeckoBaseURL, provider.baseURL)
+	assert.True(t, provider.IsAvailable())
+}
+
+func TestCoinGeckoProvider_GetQuotes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/simple/price", r.URL.Path)
+		assert.Equal(t, "bitcoin,ethereum", r.URL.Query().Get("ids"))
+		assert.Equal(t, "eur,usd", r.URL.Query().Get("vs_currencies"))
+		assert.Equal(t, "demo-key", r.Header.Get("x-cg-demo-api-key"))
+
+		_, _ = w.Write([]byte(`{
+			"bitcoin": {"usd": 67000.5, "usd_24h_vol": 25000000000.9, "usd_24h_change": 2.5, "last_updated_at": 1717945200},
+			"ethereum": {"eur": 3200.12345678, "eur_24h_change": -1.25, "last_updated_at": 1717945200}
+		}`))
+	}))
+	defer server.Close()
+
+	provider := newTestCoinGeckoProvider(server, "demo-key")
+	// Sunday 9 June 2024, an hour after the prices were last updated
+	provider.now = func() time.Time { return time.Date(2024, 6, 9, 16, 0, 0, 0, time.UTC) }
+
+	quotes, err := provider.GetQuotes(context.Background(), []string{"BTC-USD", "ETH-EUR", "AAPL"})
+	require.NoError(t, err)
+	require.Len(t, quotes, 2)
+
+	btc := quotes["BTC-USD"]
+	assert.True(t, btc.Price.Equal(decimal.RequireFromString("67000.5")))
+	assert.Equal(t, int64(25000000000), btc.Volume)
+	assert.True(t, btc.ChangePercent.Equal(decimal.RequireFromString("2.5")))
+	assert.True(t, btc.PreviousClose.Add(btc.Change).Equal(btc.Price))
+	assert.Equal(t, time.Date(2024, 6, 9, 15, 0, 0, 0, time.UTC), btc.LastUpdated)
+	// Weekend prices are current for coins
+	assert.Equal(t, time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC), btc.LatestTradingDay)
+	assert.Equal(t, dto.TradingStatusActive, btc.TradingStatus)
+
+	eth := quotes["ETH-EUR"]
+	assert.True(t, eth.Price.Equal(decimal.RequireFromString("3200.12345678")))
+}
+
+func TestCoinGeckoProvider_GetQuote_UnknownCoin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/coins/list", r.URL.Path)
+		_, _ = w.Write([]byte(`[
+			{"id": "pepe", "symbol": "pepe", "name": "Pepe"},
+			{"id": "gemini-dollar", "symbol": "gusd", "name": "Gemini Dollar"},
+			{"id": "gusd-token", "symbol": "gusd", "name": "GUSD Token"}
+		]`))
+	}))
+	defer server.Close()
+
+	provider := newTestCoinGeckoProvider(server, "")
+
+	_, err := provider.GetQuote(context.Background(), "NOPE-USD")
+	assert.ErrorContains(t, err, "no data found")
+
+	id, err := provider.coinID(context.Background(), "PEPE")
+	require.NoError(t, err)
+	assert.Equal(t, "pepe", id)
+
+	_, err = provider.coinID(context.Background(), "GUSD")
+	assert.ErrorContains(t, err, "shared by several coins")
+}
+
+func TestCoinGeckoProvider_GetHistoricalPrices(t *testing.T) {
+	day := func(d, hour int) int64 {
+		return time.Date(2024, 6, d, hour, 0, 0, 0, time.UTC).UnixMilli()
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/coins/bitcoin/market_chart/range", r.URL.Path)
+		assert.Equal(t, "usd", r.URL.Query().Get("vs_currency"))
+
+		_, _ = w.Write([]byte(`{
+			"prices": [
+				[` + decimal.NewFromInt(day(7, 0)).String() + `, 100],
+				[` + decimal.NewFromInt(day(8, 0)).String() + `, 110],
+				[` + decimal.NewFromInt(day(8, 12)).String() + `, 120],
+				[` + decimal.NewFromInt(day(8, 23)).String() + `, 105],
+				[` + decimal.NewFromInt(day(9, 0)).String() + `, 108]
+			],
+			"total_volumes": [
+				[` + decimal.NewFromInt(day(8, 23)).String() + `, 5000.7]
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	provider := newTestCoinGeckoProvider(server, "")
+
+	prices, err := provider.GetHistoricalPrices(context.Background(), "BTC-USD",
+		time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	// Saturday and Sunday both have prices, and points outside the range are dropped
+	require.Len(t, prices, 2)
+	saturday := prices[0]
+	assert.Equal(t, time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC), saturday.Date)
+	assert.True(t, saturday.Open.Equal(decimal.NewFromInt(110)))
+	assert.True(t, saturday.High.Equal(decimal.NewFromInt(120)))
+	assert.True(t, saturday.Low.Equal(decimal.NewFromInt(105)))
+	assert.True(t, saturday.Close.Equal(decimal.NewFromInt(105)))
+	assert.Equal(t, int64(5000), saturday.Volume)
+	assert.True(t, prices[1].Close.Equal(decimal.NewFromInt(108)))
+
+	_, err = provider.GetHistoricalPrices(context.Background(), "AAPL", time.Now(), time.Now())
+	assert.Error(t, err)
+}
+
+func TestCoinGeckoProvider_GetExchangeRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/exchange_rates", r.URL.Path)
+		_, _ = w.Write([]byte(`{"rates": {
+			"btc": {"name": "Bitcoin", "unit": "BTC", "value": 1, "type": "crypto"},
+			"usd": {"name": "US Dollar", "unit": "$", "value": 60000, "type": "fiat"},
+			"eur": {"name": "Euro", "unit": "€", "value": 54000, "type": "fiat"}
+		}}`))
+	}))
+	defer server.Close()
+
+	provider := newTestCoinGeckoProvider(server, "")
+	ctx := context.Background()
+
+	rate, err := provider.GetExchangeRate(ctx, "BTC", "USD")
+	require.NoError(t, err)
+	assert.True(t, rate.Equal(decimal.NewFromInt(60000)))
+
+	rate, err = provider.GetExchangeRate(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	assert.True(t, rate.Equal(decimal.RequireFromString("0.9")))
+
+	_, err = provider.GetExchangeRate(ctx, "XYZ", "USD")
+	assert.Error(t, err)
+}
+
+func TestCoinGeckoProvider_RateLimited(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := newTestCoinGeckoProvider(server, "")
+
+	_, err := provider.GetQuotes(context.Background(), []string{"BTC-USD"})
+	assert.ErrorContains(t, err, "rate limit")
+}
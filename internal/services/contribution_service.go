@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type alias for dto type for consistency with the other analytics services
+type ContributionAnalysis = dto.ContributionAnalysis
+
+// ContributionService defines the interface for splitting a portfolio's growth into the
+// money contributed to it and the returns of its investments
+type ContributionService interface {
+	Analyze(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*ContributionAnalysis, error)
+}
+
+// contributionService implements ContributionService interface
+type contributionService struct {
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.PerformanceSnapshotRepository
+}
+
+// NewContributionService creates a new ContributionService instance
+func NewContributionService(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+) ContributionService {
+	return &contributionService{
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+	}
+}
+
+// Analyze splits the change in a portfolio's value between startDate and endDate into its
+// net contributions and investment growth. Portfolios that record deposits and withdrawals
+// are measured on them; the others on their purchases and sales.
+func (s *contributionService) Analyze(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*ContributionAnalysis, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	if !endDate.After(startDate) {
+		return nil, models.ErrInvalidContributionPeriod
+	}
+
+	// The values come from the snapshots nearest the period's ends, and the cash balance
+	// at each end from every transaction before it
+	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, startDate.AddDate(0, 0, -snapshotSearchDays), endDate.AddDate(0, 0, snapshotSearchDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve snapshots: %w", err)
+	}
+	startSnapshot := snapshotNearDate(snapshots, startDate)
+	endSnapshot := snapshotNearDate(snapshots, endDate)
+	if startSnapshot == nil || endSnapshot == nil {
+		return nil, models.ErrInsufficientPerformanceHistory
+	}
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, nil, &endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+
+	analysis := analyzeContributions(transactions, startDate, endDate, startSnapshot.TotalValue, endSnapshot.TotalValue)
+	analysis.PortfolioID = portfolioID
+	return analysis, nil
+}
+
+// analyzeContributions splits the change between the holdings values at startDate and
+// endDate, given the portfolio's transactions up to endDate
+func analyzeContributions(
+	transactions []*models.Transaction,
+	startDate, endDate time.Time,
+	startingHoldings, endingHoldings decimal.Decimal,
+) *ContributionAnalysis {
+	analysis := &ContributionAnalysis{
+		StartDate:     startDate,
+		EndDate:       endDate,
+		Source:        dto.ContributionSourceTrades,
+		StartingValue: startingHoldings,
+		EndingValue:   endingHoldings,
+		Contributions: decimal.Zero,
+		Withdrawals:   decimal.Zero,
+	}
+	if slices.ContainsFunc(transactions, (*models.Transaction).IsCashFlow) {
+		analysis.Source = dto.ContributionSourceCashFlows
+	}
+
+	for _, tx := range transactions {
+		if analysis.Source == dto.ContributionSourceCashFlows {
+			// The money left uninvested is part of the portfolio's value
+			if !tx.Date.After(startDate) {
+				analysis.StartingValue = analysis.StartingValue.Add(cashEffect(tx))
+			}
+			analysis.EndingValue = analysis.EndingValue.Add(cashEffect(tx))
+		}
+		if !tx.Date.After(startDate) {
+			continue
+		}
+
+		contribution := contributionAmount(tx, analysis.Source)
+		if contribution.IsPositive() {
+			analysis.Contributions = analysis.Contributions.Add(contribution)
+		} else {
+			analysis.Withdrawals = analysis.Withdrawals.Sub(contribution)
+		}
+	}
+
+	analysis.Change = analysis.EndingValue.Sub(analysis.StartingValue)
+	analysis.NetContributions = analysis.Contributions.Sub(analysis.Withdrawals)
+	analysis.InvestmentGrowth = analysis.Change.Sub(analysis.NetContributions)
+	if !analysis.Change.IsZero() {
+		hundred := decimal.NewFromInt(100)
+		contributionShare := analysis.NetContributions.Div(analysis.Change).Mul(hundred)
+		growthShare := analysis.InvestmentGrowth.Div(analysis.Change).Mul(hundred)
+		analysis.ContributionShare = &contributionShare
+		analysis.GrowthShare = &growthShare
+	}
+	return analysis
+}
+
+// contributionAmount is the money a transaction put into the portfolio from outside it,
+// negative for money taken out. With cash flows recorded, that is deposits, withdrawals,
+// opening balances and stock plan acquisitions, which are paid for outside the portfolio;
+// otherwise it is purchases and sales.
+func contributionAmount(tx *models.Transaction, source string) decimal.Decimal {
+	switch {
+	case source == dto.ContributionSourceCashFlows && tx.IsCashFlow():
+		return tx.CashFlowAmount()
+	case source == dto.ContributionSourceCashFlows && (tx.IsStockPlanAcquisition() || tx.Type == models.TransactionTypeOpeningBalance):
+		// The commission is paid from the portfolio's cash
+		return tx.GetTotalCost().Sub(tx.Commission)
+	case source == dto.ContributionSourceCashFlows:
+		return decimal.Zero
+	case tx.IsBuy():
+		return tx.GetTotalCost()
+	case tx.IsSell():
+		return tx.GetProceeds().Neg()
+	default:
+		return decimal.Zero
+	}
+}
+
+// cashEffect is how a transaction changed the portfolio's cash: deposits, sales, dividends,
+// distributions and other income add to it, while withdrawals, purchases and fees draw on it.
+// Reinvested dividends, stock plan acquisitions and opening balances leave it unchanged, as do
+// corporate actions.
+func cashEffect(tx *models.Transaction) decimal.Decimal {
+	switch {
+	case tx.IsCashFlow():
+		return tx.CashFlowAmount().Sub(tx.Commission)
+	case tx.Type == models.TransactionTypeDividendReinvest || tx.IsStockPlanAcquisition() ||
+		tx.Type == models.TransactionTypeOpeningBalance:
+		return tx.Commission.Neg()
+	case tx.IsBuy():
+		return tx.GetTotalCost().Neg()
+	case tx.IsSell():
+		return tx.GetProceeds()
+	case tx.Type == models.TransactionTypeDividend || tx.IsIncome() || tx.Type == models.TransactionTypeReturnOfCapital ||
+		tx.Type == models.TransactionTypeCapitalGainDistribution:
+		return tx.Quantity.Sub(tx.Commission)
+	default:
+		return tx.GetFees().Neg()
+	}
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupContributionTest(t *testing.T) (*gorm.DB, ContributionService, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.PerformanceSnapshot{}))
+
+	user := &models.User{Email: "contributions@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Contributions",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	service := NewContributionService(
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+	)
+	return db, service, user, portfolio
+}
+
+func createContributionTransaction(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, txType models.TransactionType, symbol string, date time.Time, quantity, price string) {
+	tx := &models.Transaction{
+		PortfolioID: portfolio.ID,
+		Type:        txType,
+		Symbol:      symbol,
+		Date:        date,
+		Quantity:    decimal.RequireFromString(quantity),
+	}
+	if price != "" {
+		p := decimal.RequireFromString(price)
+		tx.Price = &p
+	}
+	require.NoError(t, db.Create(tx).Error)
+}
+
+func createContributionSnapshot(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, date time.Time, value string) {
+	snapshot := &models.PerformanceSnapshot{
+		PortfolioID:    portfolio.ID,
+		Date:           date,
+		TotalValue:     decimal.RequireFromString(value),
+		TotalCostBasis: decimal.RequireFromString(value),
+	}
+	snapshot.CalculateMetrics()
+	require.NoError(t, db.Create(snapshot).Error)
+}
+
+func TestContributionService_Analyze_CashFlows(t *testing.T) {
+	db, service, user, portfolio := setupContributionTest(t)
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	// 10,000 deposited and invested before the period, then 5,000 more deposited during it,
+	// of which 4,000 was invested, and 1,000 withdrawn
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeDeposit, "USD", time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), "10000", "")
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeBuy, "VTI", time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC), "50", "200")
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeDeposit, "USD", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "5000", "")
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeBuy, "VTI", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), "16", "250")
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeDividend, "VTI", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "200", "")
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeWithdrawal, "USD", time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), "1000", "")
+	// Deposits after the period are left out
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeDeposit, "USD", time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), "9999", "")
+
+	createContributionSnapshot(t, db, portfolio, start, "11000")
+	createContributionSnapshot(t, db, portfolio, end, "16500")
+
+	analysis, err := service.Analyze(ctx, portfolio.ID.String(), user.ID.String(), start, end)
+	require.NoError(t, err)
+
+	assert.Equal(t, dto.ContributionSourceCashFlows, analysis.Source)
+	// Holdings plus the cash left uninvested: 11,000 + 0 at the start, 16,500 + 200 at the end
+	assert.True(t, decimal.NewFromInt(11000).Equal(analysis.StartingValue), analysis.StartingValue.String())
+	assert.True(t, decimal.NewFromInt(16700).Equal(analysis.EndingValue), analysis.EndingValue.String())
+	assert.True(t, decimal.NewFromInt(5700).Equal(analysis.Change), analysis.Change.String())
+	assert.True(t, decimal.NewFromInt(5000).Equal(analysis.Contributions), analysis.Contributions.String())
+	assert.True(t, decimal.NewFromInt(1000).Equal(analysis.Withdrawals), analysis.Withdrawals.String())
+	assert.True(t, decimal.NewFromInt(4000).Equal(analysis.NetContributions), analysis.NetContributions.String())
+	assert.True(t, decimal.NewFromInt(1700).Equal(analysis.InvestmentGrowth), analysis.InvestmentGrowth.String())
+	require.NotNil(t, analysis.ContributionShare)
+	require.NotNil(t, analysis.GrowthShare)
+	assert.True(t, analysis.ContributionShare.Add(*analysis.GrowthShare).Equal(decimal.NewFromInt(100)))
+	assert.Equal(t, "70.18", analysis.ContributionShare.StringFixed(2))
+}
+
+func TestContributionService_Analyze_Trades(t *testing.T) {
+	db, service, user, portfolio := setupContributionTest(t)
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	// Without deposits, purchases and sales stand in for the money contributed
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeBuy, "VTI", time.Date(2023, 6, 2, 0, 0, 0, 0, time.UTC), "50", "200")
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeBuy, "VTI", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), "10", "250")
+	createContributionTransaction(t, db, portfolio, models.TransactionTypeSell, "VTI", time.Date(2024, 9, 2, 0, 0, 0, 0, time.UTC), "4", "250")
+
+	createContributionSnapshot(t, db, portfolio, start, "11000")
+	createContributionSnapshot(t, db, portfolio, end, "14000")
+
+	analysis, err := service.Analyze(ctx, portfolio.ID.String(), user.ID.String(), start, end)
+	require.NoError(t, err)
+
+	assert.Equal(t, dto.ContributionSourceTrades, analysis.Source)
+	assert.True(t, decimal.NewFromInt(3000).Equal(analysis.Change), analysis.Change.String())
+	assert.True(t, decimal.NewFromInt(2500).Equal(analysis.Contributions), analysis.Contributions.String())
+	assert.True(t, decimal.NewFromInt(1000).Equal(analysis.Withdrawals), analysis.Withdrawals.String())
+	assert.True(t, decimal.NewFromInt(1500).Equal(analysis.InvestmentGrowth), analysis.InvestmentGrowth.String())
+	assert.Equal(t, "50", analysis.GrowthShare.String())
+}
+
+func TestContributionService_Analyze_Errors(t *testing.T) {
+	db, service, user, portfolio := setupContributionTest(t)
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.Analyze(ctx, portfolio.ID.String(), user.ID.String(), end, start)
+	assert.ErrorIs(t, err, models.ErrInvalidContributionPeriod)
+
+	// Both ends need a snapshot within a week of them
+	createContributionSnapshot(t, db, portfolio, start, "11000")
+	_, err = service.Analyze(ctx, portfolio.ID.String(), user.ID.String(), start, end)
+	assert.ErrorIs(t, err, models.ErrInsufficientPerformanceHistory)
+
+	_, err = service.Analyze(ctx, portfolio.ID.String(), "00000000-0000-0000-0000-000000000001", start, end)
+	assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+}
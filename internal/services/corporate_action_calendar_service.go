@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Corporate action calendar event sources
+const (
+	CorporateActionSourceStored   = "STORED"
+	CorporateActionSourceProvider = "PROVIDER"
+)
+
+// AnnouncedCorporateActions looks up the corporate actions a provider has announced for a
+// symbol, without storing them
+type AnnouncedCorporateActions interface {
+	Announced(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
+}
+
+// CorporateActionCalendarService defines the interface for corporate action calendars
+type CorporateActionCalendarService interface {
+	GetCalendar(ctx context.Context, portfolioID, userID string, from, to time.Time) (*dto.CorporateActionCalendarResponse, error)
+}
+
+// corporateActionCalendarService implements CorporateActionCalendarService interface
+type corporateActionCalendarService struct {
+	corporateActionRepo repository.CorporateActionRepository
+	portfolioRepo       repository.PortfolioRepository
+	holdingRepo         repository.HoldingRepository
+	announced           AnnouncedCorporateActions
+}
+
+// NewCorporateActionCalendarService creates a new CorporateActionCalendarService instance.
+// Stored corporate actions are merged with those announced reports; announced may be nil to
+// only list stored ones.
+func NewCorporateActionCalendarService(
+	corporateActionRepo repository.CorporateActionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	announced AnnouncedCorporateActions,
+) CorporateActionCalendarService {
+	return &corporateActionCalendarService{
+		corporateActionRepo: corporateActionRepo,
+		portfolioRepo:       portfolioRepo,
+		holdingRepo:         holdingRepo,
+		announced:           announced,
+	}
+}
+
+// GetCalendar lists the corporate actions dated between from and to of every equity the
+// portfolio holds. Announced actions not yet stored are included; a symbol the provider
+// can't be asked about only lists its stored actions and is named in the warnings.
+func (s *corporateActionCalendarService) GetCalendar(ctx context.Context, portfolioID, userID string, from, to time.Time) (*dto.CorporateActionCalendarResponse, error) {
+	// Verify portfolio exists and belongs to user
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	holdings, err := s.holdingRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve holdings: %w", err)
+	}
+
+	response := &dto.CorporateActionCalendarResponse{
+		PortfolioID: portfolioID,
+		From:        from,
+		To:          to,
+		Events:      []*dto.CorporateActionCalendarEvent{},
+	}
+	quotaExceeded := false
+	for _, holding := range holdings {
+		// Coin pairs and option contracts have no corporate actions
+		if models.AssetTypeForSymbol(holding.Symbol) != models.AssetTypeEquity {
+			continue
+		}
+
+		stored, err := s.corporateActionRepo.FindBySymbolAndDateRange(ctx, holding.Symbol, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve corporate actions: %w", err)
+		}
+		for _, action := range stored {
+			response.Events = append(response.Events, calendarEvent(action, holding, CorporateActionSourceStored))
+		}
+
+		if s.announced == nil {
+			continue
+		}
+		if quotaExceeded {
+			response.Warnings = append(response.Warnings, fmt.Sprintf("%s: provider request budget exhausted", holding.Symbol))
+			continue
+		}
+
+		announced, err := s.announced.Announced(ctx, holding.Symbol)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			quotaExceeded = errors.Is(err, models.ErrQuotaExceeded)
+			response.Warnings = append(response.Warnings, fmt.Sprintf("%s: %v", holding.Symbol, err))
+		}
+		for _, action := range announced {
+			if action.Date.Before(from) || action.Date.After(to) || isKnownCorporateAction(stored, action) {
+				continue
+			}
+			response.Events = append(response.Events, calendarEvent(action, holding, CorporateActionSourceProvider))
+		}
+	}
+
+	sort.SliceStable(response.Events, func(i, j int) bool {
+		if !response.Events[i].Date.Equal(response.Events[j].Date) {
+			return response.Events[i].Date.Before(response.Events[j].Date)
+		}
+		return response.Events[i].Symbol < response.Events[j].Symbol
+	})
+
+	return response, nil
+}
+
+// calendarEvent describes a corporate action of a held symbol
+func calendarEvent(action *models.CorporateAction, holding *models.Holding, source string) *dto.CorporateActionCalendarEvent {
+	event := &dto.CorporateActionCalendarEvent{
+		Symbol:      holding.Symbol,
+		Type:        string(action.Type),
+		Date:        action.Date,
+		Ratio:       action.Ratio,
+		Amount:      action.Amount,
+		NewSymbol:   action.NewSymbol,
+		Description: action.Description,
+		Source:      source,
+		Applied:     action.Applied,
+		SharesHeld:  holding.Quantity,
+	}
+	if action.Type == models.CorporateActionTypeDividend && action.Amount != nil {
+		income := action.Amount.Mul(holding.Quantity).Round(2)
+		event.EstimatedIncome = &income
+	}
+	return event
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func TestCorporateActionCalendarService_GetCalendar(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupMonitorTestDB(t)
+	portfolio, _, _ := createMonitorTestData(t, db)
+	for _, symbol := range []string{"MSFT", "BTC-USD"} {
+		require.NoError(t, db.Create(&models.Holding{
+			PortfolioID:  portfolio.ID,
+			Symbol:       symbol,
+			Quantity:     decimal.NewFromInt(10),
+			CostBasis:    decimal.NewFromInt(1000),
+			AvgCostPrice: decimal.NewFromInt(100),
+		}).Error)
+	}
+
+	from := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	to := from.AddDate(0, 0, 30)
+	ratio := decimal.NewFromInt(2)
+	amount := decimal.RequireFromString("0.24")
+	feed := &fakeCorporateActionFeed{
+		splits: map[string][]*models.CorporateAction{
+			// Already stored by createMonitorTestData
+			"AAPL": {{Type: models.CorporateActionTypeSplit, Date: time.Now().UTC(), Ratio: &ratio}},
+		},
+		dividends: map[string][]*models.CorporateAction{
+			"AAPL": {
+				{Type: models.CorporateActionTypeDividend, Date: from.AddDate(0, 0, 10), Amount: &amount},
+				{Type: models.CorporateActionTypeDividend, Date: from.AddDate(0, 0, -80), Amount: &amount},
+			},
+		},
+		errs: map[string]error{"MSFT": errors.New("provider unavailable")},
+	}
+	corporateActionRepo := repository.NewCorporateActionRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	ingester := NewCorporateActionIngester(feed, corporateActionRepo, holdingRepo, nil, 0)
+	service := NewCorporateActionCalendarService(corporateActionRepo, repository.NewPortfolioRepository(db), holdingRepo, ingester)
+
+	calendar, err := service.GetCalendar(ctx, portfolio.ID.String(), portfolio.UserID.String(), from, to)
+	require.NoError(t, err)
+
+	require.Len(t, calendar.Events, 2)
+	split, dividend := calendar.Events[0], calendar.Events[1]
+	assert.Equal(t, "SPLIT", split.Type)
+	assert.Equal(t, CorporateActionSourceStored, split.Source)
+	assert.Equal(t, "DIVIDEND", dividend.Type)
+	assert.Equal(t, CorporateActionSourceProvider, dividend.Source)
+	// 100 shares at 0.24
+	require.NotNil(t, dividend.EstimatedIncome)
+	assert.Equal(t, "24", dividend.EstimatedIncome.String())
+
+	// Coin pairs aren't asked about, and MSFT's failed split request ends its lookup
+	assert.Equal(t, 3, feed.calls)
+	require.Len(t, calendar.Warnings, 1)
+	assert.Contains(t, calendar.Warnings[0], "MSFT")
+
+	// Provider data is never stored
+	stored, err := corporateActionRepo.FindBySymbol(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+
+	_, err = service.GetCalendar(ctx, portfolio.ID.String(), uuid.New().String(), from, to)
+	assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+}
+
+func TestCorporateActionCalendarService_GetCalendar_WithoutProvider(t *testing.T) {
+	db := setupMonitorTestDB(t)
+	portfolio, _, _ := createMonitorTestData(t, db)
+
+	service := NewCorporateActionCalendarService(repository.NewCorporateActionRepository(db),
+		repository.NewPortfolioRepository(db), repository.NewHoldingRepository(db), nil)
+
+	from := time.Now().UTC().AddDate(0, 0, -1)
+	calendar, err := service.GetCalendar(context.Background(), portfolio.ID.String(), portfolio.UserID.String(), from, from.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	require.Len(t, calendar.Events, 1)
+	assert.Empty(t, calendar.Warnings)
+	assert.Nil(t, calendar.Events[0].EstimatedIncome)
+}
+
+func TestCorporateActionCalendarService_GetCalendar_QuotaExhausted(t *testing.T) {
+	db, corporateActionRepo, holdingRepo := setupIngesterTest(t, "MSFT", "IBM")
+	var portfolio models.Portfolio
+	require.NoError(t, db.First(&portfolio).Error)
+
+	feed := &fakeCorporateActionFeed{}
+	// Room for AAPL's two requests only
+	quota := NewQuotaTracker("test", 2, 0)
+	ingester := NewCorporateActionIngester(feed, corporateActionRepo, holdingRepo, quota, 0)
+	service := NewCorporateActionCalendarService(corporateActionRepo, repository.NewPortfolioRepository(db), holdingRepo, ingester)
+
+	from := time.Now().UTC()
+	calendar, err := service.GetCalendar(context.Background(), portfolio.ID.String(), portfolio.UserID.String(), from, from.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	assert.Equal(t, 2, feed.calls)
+	assert.Len(t, calendar.Warnings, 2)
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// DefaultCorporateActionLookbackDays is how far back ingestion looks for announced actions.
+// Older history is ignored so a newly held symbol doesn't flood users with decades of events.
+const DefaultCorporateActionLookbackDays = 30
+
+// CorporateActionFeed is an external source of announced splits and dividends
+type CorporateActionFeed interface {
+	// GetSplits retrieves splits for a symbol
+	GetSplits(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
+
+	// GetDividends retrieves cash dividends for a symbol
+	GetDividends(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
+}
+
+// CorporateActionIngester pulls announced corporate actions for every held symbol from a
+// feed and stores the ones not already known. Stored actions start unapplied, so the
+// CorporateActionMonitor picks them up and suggests them to affected portfolios.
+type CorporateActionIngester struct {
+	feed                CorporateActionFeed
+	corporateActionRepo repository.CorporateActionRepository
+	holdingRepo         repository.HoldingRepository
+	quota               *QuotaTracker
+	lookbackDays        int
+	now                 func() time.Time
+}
+
+// NewCorporateActionIngester creates a new corporate action ingester. Feed requests are
+// spent from quota, which should be shared with the market data service when both use
+// the same provider account.
+func NewCorporateActionIngester(
+	feed CorporateActionFeed,
+	corporateActionRepo repository.CorporateActionRepository,
+	holdingRepo repository.HoldingRepository,
+	quota *QuotaTracker,
+	lookbackDays int,
+) *CorporateActionIngester {
+	if quota == nil {
+		quota = NewQuotaTracker("", 0, 0)
+	}
+	if lookbackDays <= 0 {
+		lookbackDays = DefaultCorporateActionLookbackDays
+	}
+	return &CorporateActionIngester{
+		feed:                feed,
+		corporateActionRepo: corporateActionRepo,
+		holdingRepo:         holdingRepo,
+		quota:               quota,
+		lookbackDays:        lookbackDays,
+		now:                 func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Ingest fetches splits and dividends for all held symbols and returns how many new
+// corporate actions were created. Per-symbol failures are logged and skipped; running
+// out of provider quota ends the run early without an error so the next run can continue.
+func (i *CorporateActionIngester) Ingest(ctx context.Context) (int, error) {
+	symbols, err := i.HeldSymbols(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return i.IngestSymbols(ctx, symbols)
+}
+
+// HeldSymbols returns every symbol with an open position in the holdings ctx reads from
+func (i *CorporateActionIngester) HeldSymbols(ctx context.Context) ([]string, error) {
+	symbols, err := i.holdingRepo.FindDistinctSymbols(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list held symbols: %w", err)
+	}
+	return symbols, nil
+}
+
+// IngestSymbols is like Ingest for the given symbols, which lets callers fetch each symbol
+// once when holdings are spread across several tenant schemas
+func (i *CorporateActionIngester) IngestSymbols(ctx context.Context, symbols []string) (int, error) {
+	since := i.now().AddDate(0, 0, -i.lookbackDays).Truncate(24 * time.Hour)
+	fetchers := []struct {
+		name  string
+		fetch func(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
+	}{
+		{"splits", i.feed.GetSplits},
+		{"dividends", i.feed.GetDividends},
+	}
+
+	created := 0
+	for _, symbol := range symbols {
+		for _, fetcher := range fetchers {
+			if err := ctx.Err(); err != nil {
+				return created, fmt.Errorf("context cancelled: %w", err)
+			}
+
+			if err := i.quota.Acquire(1); err != nil {
+				log.Printf("Corporate action ingestion stopped: %v", err)
+				return created, nil
+			}
+
+			actions, err := fetcher.fetch(ctx, symbol)
+			if err != nil {
+				if errors.Is(err, models.ErrQuotaExceeded) {
+					log.Printf("Corporate action ingestion stopped: %v", err)
+					return created, nil
+				}
+				log.Printf("Error fetching %s for %s: %v", fetcher.name, symbol, err)
+				continue
+			}
+
+			n, err := i.store(ctx, symbol, actions, since)
+			if err != nil {
+				log.Printf("Error storing %s for %s: %v", fetcher.name, symbol, err)
+			}
+			created += n
+		}
+	}
+
+	log.Printf("Corporate action ingestion created %d new actions for %d symbols", created, len(symbols))
+	return created, nil
+}
+
+// Announced fetches a symbol's splits and dividends from the feed without storing them.
+// Requests are spent from the quota; running out returns ErrQuotaExceeded along with what
+// was fetched before.
+func (i *CorporateActionIngester) Announced(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	var announced []*models.CorporateAction
+	for _, fetch := range []func(ctx context.Context, symbol string) ([]*models.CorporateAction, error){
+		i.feed.GetSplits,
+		i.feed.GetDividends,
+	} {
+		if err := i.quota.Acquire(1); err != nil {
+			return announced, err
+		}
+		actions, err := fetch(ctx, symbol)
+		if err != nil {
+			return announced, err
+		}
+		for _, action := range actions {
+			action.Symbol = symbol
+		}
+		announced = append(announced, actions...)
+	}
+	return announced, nil
+}
+
+// store saves actions dated on or after since that aren't already recorded
+func (i *CorporateActionIngester) store(ctx context.Context, symbol string, actions []*models.CorporateAction, since time.Time) (int, error) {
+	existing, err := i.corporateActionRepo.FindBySymbol(ctx, symbol)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check existing actions: %w", err)
+	}
+
+	created := 0
+	for _, action := range actions {
+		if action.Date.Before(since) || isKnownCorporateAction(existing, action) {
+			continue
+		}
+
+		action.Symbol = symbol
+		if err := action.Validate(); err != nil {
+			continue
+		}
+		if err := i.corporateActionRepo.Create(ctx, action); err != nil {
+			return created, err
+		}
+
+		existing = append(existing, action)
+		created++
+	}
+
+	return created, nil
+}
+
+// isKnownCorporateAction returns true if an action of the same type and day is already recorded
+func isKnownCorporateAction(existing []*models.CorporateAction, action *models.CorporateAction) bool {
+	day := action.Date.UTC().Truncate(24 * time.Hour)
+	for _, known := range existing {
+		if known.Type == action.Type && known.Date.UTC().Truncate(24*time.Hour).Equal(day) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// fakeCorporateActionFeed returns canned actions per symbol and counts calls
+type fakeCorporateActionFeed struct {
+	splits    map[string][]*models.CorporateAction
+	dividends map[string][]*models.CorporateAction
+	errs      map[string]error
+	calls     int
+}
+
+func (f *fakeCorporateActionFeed) GetSplits(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	f.calls++
+	if err := f.errs[symbol]; err != nil {
+		return nil, err
+	}
+	return copyCorporateActions(f.splits[symbol]), nil
+}
+
+func (f *fakeCorporateActionFeed) GetDividends(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	f.calls++
+	if err := f.errs[symbol]; err != nil {
+		return nil, err
+	}
+	return copyCorporateActions(f.dividends[symbol]), nil
+}
+
+// copyCorporateActions mimics a provider returning fresh records on every call
+func copyCorporateActions(actions []*models.CorporateAction) []*models.CorporateAction {
+	copies := make([]*models.CorporateAction, len(actions))
+	for i, action := range actions {
+		c := *action
+		copies[i] = &c
+	}
+	return copies
+}
+
+func setupIngesterTest(t *testing.T, symbols ...string) (*gorm.DB, repository.CorporateActionRepository, repository.HoldingRepository) {
+	db := setupMonitorTestDB(t)
+	portfolio, _, action := createMonitorTestData(t, db)
+	require.NoError(t, db.Delete(action).Error)
+
+	for _, symbol := range symbols {
+		require.NoError(t, db.Create(&models.Holding{
+			PortfolioID:  portfolio.ID,
+			Symbol:       symbol,
+			Quantity:     decimal.NewFromInt(10),
+			CostBasis:    decimal.NewFromInt(1000),
+			AvgCostPrice: decimal.NewFromInt(100),
+		}).Error)
+	}
+
+	return db, repository.NewCorporateActionRepository(db), repository.NewHoldingRepository(db)
+}
+
+func TestCorporateActionIngester_Ingest(t *testing.T) {
+	ctx := context.Background()
+
+	_, corporateActionRepo, holdingRepo := setupIngesterTest(t, "MSFT")
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	ratio := decimal.NewFromInt(4)
+	amount := decimal.NewFromFloat(0.25)
+	feed := &fakeCorporateActionFeed{
+		splits: map[string][]*models.CorporateAction{
+			"AAPL": {
+				{Symbol: "AAPL", Type: models.CorporateActionTypeSplit, Date: now.AddDate(0, 0, 10), Ratio: &ratio},
+				{Symbol: "AAPL", Type: models.CorporateActionTypeSplit, Date: now.AddDate(-4, 0, 0), Ratio: &ratio},
+			},
+		},
+		dividends: map[string][]*models.CorporateAction{
+			"MSFT": {
+				{Symbol: "MSFT", Type: models.CorporateActionTypeDividend, Date: now.AddDate(0, 0, -5), Amount: &amount},
+			},
+		},
+	}
+
+	ingester := NewCorporateActionIngester(feed, corporateActionRepo, holdingRepo, nil, 0)
+	ingester.now = func() time.Time { return now }
+
+	created, err := ingester.Ingest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, created)
+	assert.Equal(t, 4, feed.calls)
+
+	// The split older than the lookback window is ignored
+	aapl, err := corporateActionRepo.FindBySymbol(ctx, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, aapl, 1)
+	assert.False(t, aapl[0].Applied)
+	assert.True(t, aapl[0].Ratio.Equal(ratio))
+
+	// A second run finds nothing new
+	created, err = ingester.Ingest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, created)
+
+	unapplied, err := corporateActionRepo.FindUnapplied(ctx)
+	require.NoError(t, err)
+	assert.Len(t, unapplied, 2)
+}
+
+func TestCorporateActionIngester_SkipsFailingSymbol(t *testing.T) {
+	_, corporateActionRepo, holdingRepo := setupIngesterTest(t, "MSFT")
+	now := time.Now().UTC()
+
+	amount := decimal.NewFromFloat(0.75)
+	feed := &fakeCorporateActionFeed{
+		dividends: map[string][]*models.CorporateAction{
+			"MSFT": {{Symbol: "MSFT", Type: models.CorporateActionTypeDividend, Date: now, Amount: &amount}},
+		},
+		errs: map[string]error{"AAPL": errors.New("unknown symbol")},
+	}
+
+	ingester := NewCorporateActionIngester(feed, corporateActionRepo, holdingRepo, nil, 0)
+
+	created, err := ingester.Ingest(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+}
+
+func TestCorporateActionIngester_StopsWhenQuotaExhausted(t *testing.T) {
+	_, corporateActionRepo, holdingRepo := setupIngesterTest(t, "MSFT", "GOOG")
+
+	t.Run("local quota", func(t *testing.T) {
+		feed := &fakeCorporateActionFeed{}
+		quota := NewQuotaTracker("alphavantage", 3, 0)
+		ingester := NewCorporateActionIngester(feed, corporateActionRepo, holdingRepo, quota, 0)
+
+		_, err := ingester.Ingest(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, feed.calls)
+	})
+
+	t.Run("provider rate limit", func(t *testing.T) {
+		feed := &fakeCorporateActionFeed{
+			errs: map[string]error{"AAPL": models.ErrQuotaExceeded},
+		}
+		ingester := NewCorporateActionIngester(feed, corporateActionRepo, holdingRepo, nil, 0)
+
+		_, err := ingester.Ingest(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, feed.calls)
+	})
+}
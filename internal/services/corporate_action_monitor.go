@@ -18,6 +18,7 @@ type CorporateActionMonitor struct {
 	portfolioRepo       repository.PortfolioRepository
 	holdingRepo         repository.HoldingRepository
 	portfolioActionRepo repository.PortfolioActionRepository
+	notifications       NotificationService
 }
 
 // NewCorporateActionMonitor creates a new corporate action monitor
@@ -35,6 +36,20 @@ func NewCorporateActionMonitor(
 	}
 }
 
+// NewCorporateActionMonitorWithNotifications creates a corporate action monitor that notifies
+// the owner of each portfolio it suggests an action to
+func NewCorporateActionMonitorWithNotifications(
+	corporateActionRepo repository.CorporateActionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	portfolioActionRepo repository.PortfolioActionRepository,
+	notifications NotificationService,
+) *CorporateActionMonitor {
+	monitor := NewCorporateActionMonitor(corporateActionRepo, portfolioRepo, holdingRepo, portfolioActionRepo)
+	monitor.notifications = notifications
+	return monitor
+}
+
 // DetectAndSuggestActions detects new corporate actions and creates pending actions for affected portfolios
 func (m *CorporateActionMonitor) DetectAndSuggestActions(ctx context.Context) error {
 	log.Println("Starting corporate action detection...")
@@ -45,7 +60,7 @@ func (m *CorporateActionMonitor) DetectAndSuggestActions(ctx context.Context) er
 	}
 
 	// Step 1: Fetch new/unapplied corporate actions
-	actions, err := m.corporateActionRepo.FindUnapplied()
+	actions, err := m.corporateActionRepo.FindUnapplied(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to fetch unapplied corporate actions: %w", err)
 	}
@@ -63,7 +78,7 @@ func (m *CorporateActionMonitor) DetectAndSuggestActions(ctx context.Context) er
 			return fmt.Errorf("context cancelled: %w", err)
 		}
 
-		if err := m.processAction(action); err != nil {
+		if err := m.processAction(ctx, action); err != nil {
 			log.Printf("Error processing corporate action %s for symbol %s: %v",
 				action.ID, action.Symbol, err)
 			continue
@@ -75,12 +90,12 @@ func (m *CorporateActionMonitor) DetectAndSuggestActions(ctx context.Context) er
 }
 
 // processAction processes a single corporate action and creates portfolio actions
-func (m *CorporateActionMonitor) processAction(action *models.CorporateAction) error {
+func (m *CorporateActionMonitor) processAction(ctx context.Context, action *models.CorporateAction) error {
 	log.Printf("Processing %s for symbol %s on %s",
 		action.Type, action.Symbol, action.Date.Format("2006-01-02"))
 
 	// Find all portfolios with holdings in this symbol
-	portfolios, err := m.findPortfoliosWithSymbol(action.Symbol)
+	portfolios, err := m.findPortfoliosWithSymbol(ctx, action.Symbol)
 	if err != nil {
 		return fmt.Errorf("failed to find portfolios with symbol %s: %w", action.Symbol, err)
 	}
@@ -97,6 +112,7 @@ func (m *CorporateActionMonitor) processAction(action *models.CorporateAction) e
 	for _, portfolio := range portfolios {
 		// Check if action already exists
 		exists, err := m.portfolioActionRepo.ExistsPendingForPortfolioAndAction(
+			ctx,
 			portfolio.ID.String(),
 			action.ID.String(),
 		)
@@ -111,7 +127,7 @@ func (m *CorporateActionMonitor) processAction(action *models.CorporateAction) e
 		}
 
 		// Get holding to determine shares affected
-		holding, err := m.holdingRepo.FindByPortfolioIDAndSymbol(
+		holding, err := m.holdingRepo.FindByPortfolioIDAndSymbol(ctx,
 			portfolio.ID.String(),
 			action.Symbol,
 		)
@@ -131,7 +147,7 @@ func (m *CorporateActionMonitor) processAction(action *models.CorporateAction) e
 			Notes:             m.generateActionDescription(action, holding),
 		}
 
-		if err := m.portfolioActionRepo.Create(portfolioAction); err != nil {
+		if err := m.portfolioActionRepo.Create(ctx, portfolioAction); err != nil {
 			log.Printf("Failed to create portfolio action for portfolio %s: %v", portfolio.ID, err)
 			continue
 		}
@@ -139,14 +155,40 @@ func (m *CorporateActionMonitor) processAction(action *models.CorporateAction) e
 		createdCount++
 		log.Printf("Created pending action for portfolio %s (%d shares affected)",
 			portfolio.ID, portfolioAction.SharesAffected)
+
+		m.notify(ctx, portfolio, action, portfolioAction)
 	}
 
 	log.Printf("Created %d pending portfolio actions for symbol %s", createdCount, action.Symbol)
 	return nil
 }
 
+// notify tells a portfolio's owner that a corporate action awaits their approval
+func (m *CorporateActionMonitor) notify(
+	ctx context.Context,
+	portfolio *models.Portfolio,
+	action *models.CorporateAction,
+	portfolioAction *models.PortfolioAction,
+) {
+	if m.notifications == nil {
+		return
+	}
+
+	portfolioID := portfolio.ID
+	notification := &models.Notification{
+		UserID:      portfolio.UserID,
+		PortfolioID: &portfolioID,
+		Type:        models.NotificationTypeCorporateAction,
+		Title:       fmt.Sprintf("%s %s on %s awaits approval", action.Symbol, action.Type, action.Date.Format("2006-01-02")),
+		Message:     portfolioAction.Notes,
+	}
+	if err := m.notifications.Notify(ctx, notification); err != nil {
+		log.Printf("Error notifying owner of portfolio %s: %v", portfolio.ID, err)
+	}
+}
+
 // findPortfoliosWithSymbol finds all portfolios that have holdings in the given symbol
-func (m *CorporateActionMonitor) findPortfoliosWithSymbol(symbol string) ([]*models.Portfolio, error) {
+func (m *CorporateActionMonitor) findPortfoliosWithSymbol(ctx context.Context, symbol string) ([]*models.Portfolio, error) {
 	// This is a simplified implementation
 	// In a production system, you'd want a more efficient query
 
@@ -157,13 +199,13 @@ func (m *CorporateActionMonitor) findPortfoliosWithSymbol(symbol string) ([]*mod
 	// This is a placeholder - in production, add a method to HoldingRepository:
 	// FindPortfoliosBySymbol(symbol string) ([]*models.Portfolio, error)
 
-	return m.findPortfoliosBySymbolWorkaround(symbol)
+	return m.findPortfoliosBySymbolWorkaround(ctx, symbol)
 }
 
 // findPortfoliosBySymbolWorkaround uses holdings to find portfolios with a specific symbol
-func (m *CorporateActionMonitor) findPortfoliosBySymbolWorkaround(symbol string) ([]*models.Portfolio, error) {
+func (m *CorporateActionMonitor) findPortfoliosBySymbolWorkaround(ctx context.Context, symbol string) ([]*models.Portfolio, error) {
 	// Find all holdings for this symbol
-	holdings, err := m.holdingRepo.FindBySymbol(symbol)
+	holdings, err := m.holdingRepo.FindBySymbol(ctx, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find holdings: %w", err)
 	}
@@ -206,6 +248,30 @@ func (m *CorporateActionMonitor) generateActionDescription(
 		}
 		return fmt.Sprintf("Dividend for %s", action.Symbol)
 
+	case models.CorporateActionTypeStockDividend:
+		if action.Ratio != nil {
+			received := action.Ratio.Mul(holding.Quantity)
+			return fmt.Sprintf("Stock dividend of %s shares per share for %s. You will receive %s shares at no additional cost basis.",
+				action.Ratio.String(), action.Symbol, received.String())
+		}
+		return fmt.Sprintf("Stock dividend for %s", action.Symbol)
+
+	case models.CorporateActionTypeReturnOfCapital:
+		if action.Amount != nil {
+			total := action.Amount.Mul(holding.Quantity)
+			return fmt.Sprintf("Return of capital of %s per share (%s total) for %s. It reduces your cost basis rather than counting as income.",
+				action.Amount.String(), total.String(), action.Symbol)
+		}
+		return fmt.Sprintf("Return of capital for %s", action.Symbol)
+
+	case models.CorporateActionTypeCapitalGainDistribution:
+		if action.Amount != nil {
+			total := action.Amount.Mul(holding.Quantity)
+			return fmt.Sprintf("Capital gain distribution of %s per share (%s total) for %s. It is taxed as a long-term capital gain.",
+				action.Amount.String(), total.String(), action.Symbol)
+		}
+		return fmt.Sprintf("Capital gain distribution for %s", action.Symbol)
+
 	case models.CorporateActionTypeMerger:
 		if action.NewSymbol != nil {
 			return fmt.Sprintf("Merger: %s is being acquired. Shares will be converted to %s",
@@ -234,6 +300,7 @@ func (m *CorporateActionMonitor) generateActionDescription(
 // SimulateDetection creates a simulated corporate action for testing
 // In production, this would fetch from an external API
 func (m *CorporateActionMonitor) SimulateDetection(
+	ctx context.Context,
 	symbol string,
 	actionType models.CorporateActionType,
 	date time.Time,
@@ -242,7 +309,7 @@ func (m *CorporateActionMonitor) SimulateDetection(
 	description string,
 ) (*models.CorporateAction, error) {
 	// Check if action already exists
-	existing, err := m.corporateActionRepo.FindBySymbol(symbol)
+	existing, err := m.corporateActionRepo.FindBySymbol(ctx, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing actions: %w", err)
 	}
@@ -271,7 +338,7 @@ func (m *CorporateActionMonitor) SimulateDetection(
 		return nil, fmt.Errorf("invalid corporate action: %w", err)
 	}
 
-	if err := m.corporateActionRepo.Create(action); err != nil {
+	if err := m.corporateActionRepo.Create(ctx, action); err != nil {
 		return nil, fmt.Errorf("failed to create corporate action: %w", err)
 	}
 
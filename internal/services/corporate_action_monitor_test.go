@@ -131,13 +131,41 @@ func TestDetectAndSuggestActions_WithAction(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify portfolio action was created
-	actions, err := portfolioActionRepo.FindPendingByCorporateActionID(action.ID.String())
+	actions, err := portfolioActionRepo.FindPendingByCorporateActionID(ctx, action.ID.String())
 	assert.NoError(t, err)
 	assert.Len(t, actions, 1)
 	assert.Equal(t, "AAPL", actions[0].AffectedSymbol)
 	assert.Equal(t, int64(100), actions[0].SharesAffected)
 }
 
+func TestDetectAndSuggestActions_NotifiesOwners(t *testing.T) {
+	db := setupMonitorTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}))
+	portfolio, _, _ := createMonitorTestData(t, db)
+
+	notifications := NewNotificationService(repository.NewNotificationRepository(db))
+	monitor := NewCorporateActionMonitorWithNotifications(
+		repository.NewCorporateActionRepository(db),
+		repository.NewPortfolioRepository(db),
+		repository.NewHoldingRepository(db),
+		repository.NewPortfolioActionRepository(db),
+		notifications,
+	)
+
+	ctx := context.Background()
+	require.NoError(t, monitor.DetectAndSuggestActions(ctx))
+	// A second run finds the action already pending and doesn't notify again
+	require.NoError(t, monitor.DetectAndSuggestActions(ctx))
+
+	listed, unread, err := notifications.List(ctx, portfolio.UserID.String(), false, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), unread)
+	require.Len(t, listed, 1)
+	assert.Equal(t, models.NotificationTypeCorporateAction, listed[0].Type)
+	assert.Equal(t, portfolio.ID, *listed[0].PortfolioID)
+	assert.Contains(t, listed[0].Message, "Stock split 2 for AAPL")
+}
+
 func TestDetectAndSuggestActions_ContextCancellation(t *testing.T) {
 	db := setupMonitorTestDB(t)
 	createMonitorTestData(t, db)
@@ -163,6 +191,8 @@ func TestDetectAndSuggestActions_ContextCancellation(t *testing.T) {
 }
 
 func TestDetectAndSuggestActions_NoDuplicates(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupMonitorTestDB(t)
 	portfolio, _, action := createMonitorTestData(t, db)
 
@@ -187,20 +217,21 @@ func TestDetectAndSuggestActions_NoDuplicates(t *testing.T) {
 		SharesAffected:    100,
 		DetectedAt:        time.Now().UTC(),
 	}
-	require.NoError(t, portfolioActionRepo.Create(existingAction))
+	require.NoError(t, portfolioActionRepo.Create(ctx, existingAction))
 
 	// Run detection
-	ctx := context.Background()
 	err := monitor.DetectAndSuggestActions(ctx)
 	assert.NoError(t, err)
 
 	// Should still only have one action (no duplicate created)
-	actions, err := portfolioActionRepo.FindPendingByCorporateActionID(action.ID.String())
+	actions, err := portfolioActionRepo.FindPendingByCorporateActionID(ctx, action.ID.String())
 	assert.NoError(t, err)
 	assert.Len(t, actions, 1)
 }
 
 func TestProcessAction_NoHoldings(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupMonitorTestDB(t)
 
 	corporateActionRepo := repository.NewCorporateActionRepository(db)
@@ -226,16 +257,18 @@ func TestProcessAction_NoHoldings(t *testing.T) {
 	}
 	require.NoError(t, db.Create(action).Error)
 
-	err := monitor.processAction(action)
+	err := monitor.processAction(ctx, action)
 	assert.NoError(t, err)
 
 	// No portfolio actions should be created
-	actions, err := portfolioActionRepo.FindPendingByCorporateActionID(action.ID.String())
+	actions, err := portfolioActionRepo.FindPendingByCorporateActionID(ctx, action.ID.String())
 	assert.NoError(t, err)
 	assert.Len(t, actions, 0)
 }
 
 func TestFindPortfoliosWithSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupMonitorTestDB(t)
 	createMonitorTestData(t, db)
 
@@ -251,13 +284,15 @@ func TestFindPortfoliosWithSymbol(t *testing.T) {
 		portfolioActionRepo,
 	)
 
-	portfolios, err := monitor.findPortfoliosWithSymbol("AAPL")
+	portfolios, err := monitor.findPortfoliosWithSymbol(ctx, "AAPL")
 	assert.NoError(t, err)
 	assert.Len(t, portfolios, 1)
 	assert.Equal(t, "Test Portfolio", portfolios[0].Name)
 }
 
 func TestFindPortfoliosWithSymbol_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupMonitorTestDB(t)
 
 	corporateActionRepo := repository.NewCorporateActionRepository(db)
@@ -272,7 +307,7 @@ func TestFindPortfoliosWithSymbol_NotFound(t *testing.T) {
 		portfolioActionRepo,
 	)
 
-	portfolios, err := monitor.findPortfoliosWithSymbol("TSLA")
+	portfolios, err := monitor.findPortfoliosWithSymbol(ctx, "TSLA")
 	assert.NoError(t, err)
 	assert.Len(t, portfolios, 0)
 }
@@ -432,6 +467,8 @@ func TestGenerateActionDescription_TickerChange(t *testing.T) {
 }
 
 func TestSimulateDetection_CreateNew(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupMonitorTestDB(t)
 
 	corporateActionRepo := repository.NewCorporateActionRepository(db)
@@ -448,6 +485,7 @@ func TestSimulateDetection_CreateNew(t *testing.T) {
 
 	ratio := decimal.NewFromFloat(2.0)
 	action, err := monitor.SimulateDetection(
+		ctx,
 		"AAPL",
 		models.CorporateActionTypeSplit,
 		time.Now().UTC(),
@@ -465,6 +503,8 @@ func TestSimulateDetection_CreateNew(t *testing.T) {
 }
 
 func TestSimulateDetection_Duplicate(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupMonitorTestDB(t)
 
 	corporateActionRepo := repository.NewCorporateActionRepository(db)
@@ -484,6 +524,7 @@ func TestSimulateDetection_Duplicate(t *testing.T) {
 
 	// Create first action
 	action1, err := monitor.SimulateDetection(
+		ctx,
 		"AAPL",
 		models.CorporateActionTypeSplit,
 		date,
@@ -496,6 +537,7 @@ func TestSimulateDetection_Duplicate(t *testing.T) {
 
 	// Try to create duplicate
 	action2, err := monitor.SimulateDetection(
+		ctx,
 		"AAPL",
 		models.CorporateActionTypeSplit,
 		date,
@@ -509,6 +551,8 @@ func TestSimulateDetection_Duplicate(t *testing.T) {
 }
 
 func TestSimulateDetection_InvalidAction(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupMonitorTestDB(t)
 
 	corporateActionRepo := repository.NewCorporateActionRepository(db)
@@ -525,6 +569,7 @@ func TestSimulateDetection_InvalidAction(t *testing.T) {
 
 	// Split without ratio should fail validation
 	_, err := monitor.SimulateDetection(
+		ctx,
 		"AAPL",
 		models.CorporateActionTypeSplit,
 		time.Now().UTC(),
@@ -1,10 +1,12 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/repository"
@@ -13,38 +15,57 @@ import (
 // CorporateActionService defines the interface for corporate action operations
 type CorporateActionService interface {
 	// Core operations
-	Create(symbol string, actionType models.CorporateActionType, date time.Time, ratio, amount *decimal.Decimal, newSymbol, currency, description *string) (*models.CorporateAction, error)
-	GetByID(id string) (*models.CorporateAction, error)
-	GetBySymbol(symbol string) ([]*models.CorporateAction, error)
-	GetBySymbolAndDateRange(symbol string, startDate, endDate time.Time) ([]*models.CorporateAction, error)
-	GetUnapplied() ([]*models.CorporateAction, error)
-	MarkAsApplied(id string) error
-	Delete(id string) error
+	Create(ctx context.Context, symbol string, actionType models.CorporateActionType, date time.Time, ratio, amount *decimal.Decimal, newSymbol, currency, description *string) (*models.CorporateAction, error)
+	GetByID(ctx context.Context, id string) (*models.CorporateAction, error)
+	GetBySymbol(ctx context.Context, symbol string) ([]*models.CorporateAction, error)
+	GetBySymbolAndDateRange(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.CorporateAction, error)
+	GetUnapplied(ctx context.Context) ([]*models.CorporateAction, error)
+	MarkAsApplied(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
 
 	// Application methods
-	ApplyStockSplit(portfolioID, symbol, userID string, ratio decimal.Decimal, date time.Time) error
-	ApplyDividend(portfolioID, symbol, userID string, amount decimal.Decimal, date time.Time) error
-	ApplyMerger(portfolioID, oldSymbol, newSymbol, userID string, ratio decimal.Decimal, date time.Time) error
-	ApplySpinoff(portfolioID, oldSymbol, newSymbol, userID string, ratio decimal.Decimal, date time.Time) error
-	ApplyTickerChange(portfolioID, oldSymbol, newSymbol, userID string, date time.Time) error
+	ApplyStockSplit(ctx context.Context, portfolioID, symbol, userID string, ratio decimal.Decimal, date time.Time) error
+	ApplyDividend(ctx context.Context, portfolioID, symbol, userID string, amount decimal.Decimal, date time.Time) error
+	ApplyMerger(ctx context.Context, portfolioID, oldSymbol, newSymbol, userID string, ratio decimal.Decimal, date time.Time) error
+	ApplySpinoff(ctx context.Context, portfolioID, oldSymbol, newSymbol, userID string, ratio decimal.Decimal, allocation models.SpinoffAllocation, date time.Time) error
+	ApplyTickerChange(ctx context.Context, portfolioID, oldSymbol, newSymbol, userID string, date time.Time) error
+	ApplyReturnOfCapital(ctx context.Context, portfolioID, symbol, userID string, amount decimal.Decimal, date time.Time) error
+	ApplyStockDividend(ctx context.Context, portfolioID, symbol, userID string, rate decimal.Decimal, date time.Time) error
+	ApplyCapitalGainDistribution(ctx context.Context, portfolioID, symbol, userID string, amount decimal.Decimal, date time.Time) error
 }
 
 // corporateActionService implements CorporateActionService interface
 type corporateActionService struct {
+	db                  *gorm.DB
 	corporateActionRepo repository.CorporateActionRepository
 	portfolioRepo       repository.PortfolioRepository
 	transactionRepo     repository.TransactionRepository
 	holdingRepo         repository.HoldingRepository
 	taxLotRepo          repository.TaxLotRepository
+	rounding            models.RoundingPolicy
 }
 
-// NewCorporateActionService creates a new CorporateActionService instance
+// NewCorporateActionService creates a new CorporateActionService instance that rounds with
+// the default rounding policy
 func NewCorporateActionService(
 	corporateActionRepo repository.CorporateActionRepository,
 	portfolioRepo repository.PortfolioRepository,
 	transactionRepo repository.TransactionRepository,
 	holdingRepo repository.HoldingRepository,
 	taxLotRepo repository.TaxLotRepository,
+) CorporateActionService {
+	return NewCorporateActionServiceWithRounding(corporateActionRepo, portfolioRepo, transactionRepo, holdingRepo, taxLotRepo, models.DefaultRoundingPolicy())
+}
+
+// NewCorporateActionServiceWithRounding creates a new CorporateActionService instance that
+// rounds the quantities and cost bases it derives with the given policy
+func NewCorporateActionServiceWithRounding(
+	corporateActionRepo repository.CorporateActionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	holdingRepo repository.HoldingRepository,
+	taxLotRepo repository.TaxLotRepository,
+	rounding models.RoundingPolicy,
 ) CorporateActionService {
 	return &corporateActionService{
 		corporateActionRepo: corporateActionRepo,
@@ -52,11 +73,44 @@ func NewCorporateActionService(
 		transactionRepo:     transactionRepo,
 		holdingRepo:         holdingRepo,
 		taxLotRepo:          taxLotRepo,
+		rounding:            rounding,
+	}
+}
+
+// NewCorporateActionServiceWithDB creates a new CorporateActionService instance that works on
+// the database directly, so each corporate action is applied in one database transaction
+func NewCorporateActionServiceWithDB(db *gorm.DB, rounding models.RoundingPolicy) CorporateActionService {
+	return newCorporateActionServiceOn(db, rounding)
+}
+
+// newCorporateActionServiceOn creates a corporateActionService whose repositories use db
+func newCorporateActionServiceOn(db *gorm.DB, rounding models.RoundingPolicy) *corporateActionService {
+	return &corporateActionService{
+		db:                  db,
+		corporateActionRepo: repository.NewCorporateActionRepository(db),
+		portfolioRepo:       repository.NewPortfolioRepository(db),
+		transactionRepo:     repository.NewTransactionRepository(db),
+		holdingRepo:         repository.NewHoldingRepository(db),
+		taxLotRepo:          repository.NewTaxLotRepository(db),
+		rounding:            rounding,
 	}
 }
 
+// transaction runs fn with a service whose holding, tax lot and transaction writes share one
+// database transaction. A service created from repositories has no database of its own, so
+// fn writes through them and the caller decides whether they share a transaction.
+func (s *corporateActionService) transaction(ctx context.Context, fn func(s *corporateActionService) error) error {
+	if s.db == nil {
+		return fn(s)
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(newCorporateActionServiceOn(tx, s.rounding))
+	})
+}
+
 // Create creates a new corporate action
 func (s *corporateActionService) Create(
+	ctx context.Context,
 	symbol string,
 	actionType models.CorporateActionType,
 	date time.Time,
@@ -79,7 +133,7 @@ func (s *corporateActionService) Create(
 		return nil, err
 	}
 
-	if err := s.corporateActionRepo.Create(action); err != nil {
+	if err := s.corporateActionRepo.Create(ctx, action); err != nil {
 		return nil, fmt.Errorf("failed to create corporate action: %w", err)
 	}
 
@@ -87,534 +141,824 @@ func (s *corporateActionService) Create(
 }
 
 // GetByID retrieves a corporate action by ID
-func (s *corporateActionService) GetByID(id string) (*models.CorporateAction, error) {
-	return s.corporateActionRepo.FindByID(id)
+func (s *corporateActionService) GetByID(ctx context.Context, id string) (*models.CorporateAction, error) {
+	return s.corporateActionRepo.FindByID(ctx, id)
 }
 
 // GetBySymbol retrieves all corporate actions for a symbol
-func (s *corporateActionService) GetBySymbol(symbol string) ([]*models.CorporateAction, error) {
-	return s.corporateActionRepo.FindBySymbol(symbol)
+func (s *corporateActionService) GetBySymbol(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
+	return s.corporateActionRepo.FindBySymbol(ctx, symbol)
 }
 
 // GetBySymbolAndDateRange retrieves corporate actions for a symbol within a date range
 func (s *corporateActionService) GetBySymbolAndDateRange(
+	ctx context.Context,
 	symbol string,
 	startDate, endDate time.Time,
 ) ([]*models.CorporateAction, error) {
-	return s.corporateActionRepo.FindBySymbolAndDateRange(symbol, startDate, endDate)
+	return s.corporateActionRepo.FindBySymbolAndDateRange(ctx, symbol, startDate, endDate)
 }
 
 // GetUnapplied retrieves all unapplied corporate actions
-func (s *corporateActionService) GetUnapplied() ([]*models.CorporateAction, error) {
-	return s.corporateActionRepo.FindUnapplied()
+func (s *corporateActionService) GetUnapplied(ctx context.Context) ([]*models.CorporateAction, error) {
+	return s.corporateActionRepo.FindUnapplied(ctx)
 }
 
 // MarkAsApplied marks a corporate action as applied
-func (s *corporateActionService) MarkAsApplied(id string) error {
-	action, err := s.corporateActionRepo.FindByID(id)
+func (s *corporateActionService) MarkAsApplied(ctx context.Context, id string) error {
+	action, err := s.corporateActionRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	action.Applied = true
-	return s.corporateActionRepo.Update(action)
+	return s.corporateActionRepo.Update(ctx, action)
 }
 
 // Delete deletes a corporate action
-func (s *corporateActionService) Delete(id string) error {
-	return s.corporateActionRepo.Delete(id)
+func (s *corporateActionService) Delete(ctx context.Context, id string) error {
+	return s.corporateActionRepo.Delete(ctx, id)
 }
 
 // ApplyStockSplit applies a stock split to a portfolio
 func (s *corporateActionService) ApplyStockSplit(
+	ctx context.Context,
 	portfolioID, symbol, userID string,
 	ratio decimal.Decimal,
 	date time.Time,
 ) error {
-	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
-	if err != nil {
-		return models.ErrPortfolioNotFound
-	}
-	if portfolio.UserID.String() != userID {
-		return models.ErrUnauthorizedAccess
-	}
+	return s.transaction(ctx, func(s *corporateActionService) error {
+		// Verify portfolio exists and belongs to user
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
 
-	// Validate split ratio
-	if ratio.LessThanOrEqual(decimal.Zero) {
-		return fmt.Errorf("invalid split ratio: must be greater than 0")
-	}
+		// Validate split ratio
+		if ratio.LessThanOrEqual(decimal.Zero) {
+			return fmt.Errorf("invalid split ratio: must be greater than 0")
+		}
 
-	// 1. Get holding for the symbol
-	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
-	if err != nil {
-		return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
-	}
+		// 1. Get holding for the symbol
+		holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
+		}
 
-	// 2. Multiply quantity by split ratio, keep total cost basis the same
-	// Example: 4:1 split of 100 shares @ $180/share
-	// Before: 100 shares, $18,000 cost basis, $180 avg cost
-	// After: 400 shares, $18,000 cost basis, $45 avg cost
-	oldQuantity := holding.Quantity
-	newQuantity := holding.Quantity.Mul(ratio)
+		// 2. Multiply quantity by split ratio, keep total cost basis the same
+		// Example: 4:1 split of 100 shares @ $180/share
+		// Before: 100 shares, $18,000 cost basis, $180 avg cost
+		// After: 400 shares, $18,000 cost basis, $45 avg cost
+		oldQuantity := holding.Quantity
+		newQuantity := s.rounding.RoundQuantity(holding.AssetType, holding.Quantity.Mul(ratio))
 
-	holding.Quantity = newQuantity
-	// Cost basis stays the same (total investment doesn't change)
-	// But avg cost per share decreases
-	holding.CalculateAvgCostPrice()
+		holding.Quantity = newQuantity
+		// Cost basis stays the same (total investment doesn't change)
+		// But avg cost per share decreases
+		holding.CalculateAvgCostPrice()
 
-	if err := s.holdingRepo.Update(holding); err != nil {
-		return fmt.Errorf("failed to update holding: %w", err)
-	}
+		if err := s.holdingRepo.Update(ctx, holding); err != nil {
+			return fmt.Errorf("failed to update holding: %w", err)
+		}
 
-	// 3. Update all tax lots for this symbol
-	taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve tax lots: %w", err)
-	}
+		// 3. Update all tax lots for this symbol
+		taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tax lots: %w", err)
+		}
 
-	for _, lot := range taxLots {
-		// Multiply quantity by split ratio
-		lot.Quantity = lot.Quantity.Mul(ratio)
-		// Cost basis stays the same for each lot
-		// This automatically adjusts the per-share cost
+		lotQuantities := s.allocateLotQuantities(taxLots, symbol, ratio)
+		for i, lot := range taxLots {
+			// Cost basis stays the same for each lot
+			// This automatically adjusts the per-share cost
+			lot.Quantity = lotQuantities[i]
 
-		if err := s.taxLotRepo.Update(lot); err != nil {
-			return fmt.Errorf("failed to update tax lot: %w", err)
+			if err := s.taxLotRepo.Update(ctx, lot); err != nil {
+				return fmt.Errorf("failed to update tax lot: %w", err)
+			}
 		}
-	}
 
-	// 4. Create a SPLIT transaction for audit trail
-	splitTransaction := &models.Transaction{
-		PortfolioID: portfolio.ID,
-		Type:        models.TransactionTypeSplit,
-		Symbol:      symbol,
-		Date:        date,
-		Quantity:    newQuantity.Sub(oldQuantity), // Additional shares received
-		Price:       nil,                          // No price for splits
-		Commission:  decimal.Zero,
-		Currency:    portfolio.BaseCurrency,
-		Notes:       fmt.Sprintf("Stock split: %s ratio applied", ratio.String()),
-	}
+		// 4. Create a SPLIT transaction for audit trail
+		splitTransaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeSplit,
+			Symbol:      symbol,
+			Date:        date,
+			Quantity:    newQuantity.Sub(oldQuantity), // Additional shares received
+			Price:       nil,                          // No price for splits
+			Commission:  decimal.Zero,
+			Currency:    portfolio.BaseCurrency,
+			Notes:       fmt.Sprintf("Stock split: %s ratio applied", ratio.String()),
+		}
 
-	if err := s.transactionRepo.Create(splitTransaction); err != nil {
-		return fmt.Errorf("failed to create split transaction: %w", err)
-	}
+		if err := s.transactionRepo.Create(ctx, splitTransaction); err != nil {
+			return fmt.Errorf("failed to create split transaction: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // ApplyDividend applies a cash dividend to a portfolio
 // Note: For dividend reinvestment (DRIP), use the transaction service to create a buy transaction
 func (s *corporateActionService) ApplyDividend(
+	ctx context.Context,
 	portfolioID, symbol, userID string,
 	amount decimal.Decimal,
 	date time.Time,
 ) error {
-	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
-	if err != nil {
-		return models.ErrPortfolioNotFound
-	}
-	if portfolio.UserID.String() != userID {
-		return models.ErrUnauthorizedAccess
-	}
+	return s.transaction(ctx, func(s *corporateActionService) error {
+		// Verify portfolio exists and belongs to user
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
 
-	// Validate dividend amount
-	if amount.LessThanOrEqual(decimal.Zero) {
-		return fmt.Errorf("invalid dividend amount: must be greater than 0")
-	}
+		// Validate dividend amount
+		if amount.LessThanOrEqual(decimal.Zero) {
+			return fmt.Errorf("invalid dividend amount: must be greater than 0")
+		}
 
-	// 1. Get holding for the symbol to verify it exists
-	_, err = s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
-	if err != nil {
-		return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
-	}
+		// 1. Get holding for the symbol to verify it exists
+		_, err = s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
+		}
 
-	// 2. Create a DIVIDEND transaction for record keeping
-	// Note: Cash dividends don't affect share count or cost basis,
-	// they just represent income received
-	dividendTransaction := &models.Transaction{
-		PortfolioID: portfolio.ID,
-		Type:        models.TransactionTypeDividend,
-		Symbol:      symbol,
-		Date:        date,
-		Quantity:    amount, // For dividends, quantity represents the total amount received
-		Price:       nil,    // No price for dividend transactions
-		Commission:  decimal.Zero,
-		Currency:    portfolio.BaseCurrency,
-		Notes:       fmt.Sprintf("Cash dividend: %s", amount.String()),
-	}
+		// 2. Create a DIVIDEND transaction for record keeping
+		// Note: Cash dividends don't affect share count or cost basis,
+		// they just represent income received
+		dividendTransaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeDividend,
+			Symbol:      symbol,
+			Date:        date,
+			Quantity:    amount, // For dividends, quantity represents the total amount received
+			Price:       nil,    // No price for dividend transactions
+			Commission:  decimal.Zero,
+			Currency:    portfolio.BaseCurrency,
+			Notes:       fmt.Sprintf("Cash dividend: %s", amount.String()),
+		}
 
-	if err := s.transactionRepo.Create(dividendTransaction); err != nil {
-		return fmt.Errorf("failed to create dividend transaction: %w", err)
-	}
+		if err := s.transactionRepo.Create(ctx, dividendTransaction); err != nil {
+			return fmt.Errorf("failed to create dividend transaction: %w", err)
+		}
 
-	// Note: For dividend reinvestment (DRIP), the calling code should:
-	// 1. Calculate shares purchased: dividend amount / current share price
-	// 2. Call transaction service to create a DIVIDEND_REINVEST buy transaction
-	// 3. That will automatically create tax lots and update holdings
+		// Note: For dividend reinvestment (DRIP), the calling code should:
+		// 1. Calculate shares purchased: dividend amount / current share price
+		// 2. Call transaction service to create a DIVIDEND_REINVEST buy transaction
+		// 3. That will automatically create tax lots and update holdings
 
-	return nil
+		return nil
+	})
 }
 
 // ApplyMerger applies a merger/acquisition to a portfolio
 // This handles stock-for-stock mergers where oldSymbol is converted to newSymbol
 func (s *corporateActionService) ApplyMerger(
+	ctx context.Context,
 	portfolioID, oldSymbol, newSymbol, userID string,
 	ratio decimal.Decimal,
 	date time.Time,
 ) error {
-	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
-	if err != nil {
-		return models.ErrPortfolioNotFound
-	}
-	if portfolio.UserID.String() != userID {
-		return models.ErrUnauthorizedAccess
-	}
-
-	// Validate merger ratio
-	if ratio.LessThanOrEqual(decimal.Zero) {
-		return fmt.Errorf("invalid merger ratio: must be greater than 0")
-	}
-
-	// 1. Get holding for old symbol
-	oldHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, oldSymbol)
-	if err != nil {
-		return fmt.Errorf("no holding found for symbol %s: %w", oldSymbol, err)
-	}
-
-	// 2. Calculate new position
-	// Example: 1.5:1 ratio means 100 shares of A become 150 shares of B
-	oldQuantity := oldHolding.Quantity
-	newQuantity := oldQuantity.Mul(ratio)
-	totalCostBasis := oldHolding.CostBasis // Cost basis transfers to new symbol
-
-	// 3. Get or create holding for new symbol
-	newHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, newSymbol)
-	if err != nil {
-		// Create new holding if it doesn't exist
-		newHolding = &models.Holding{
-			PortfolioID:  portfolio.ID,
-			Symbol:       newSymbol,
-			Quantity:     decimal.Zero,
-			CostBasis:    decimal.Zero,
-			AvgCostPrice: decimal.Zero,
+	return s.transaction(ctx, func(s *corporateActionService) error {
+		// Verify portfolio exists and belongs to user
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
 		}
-	}
-
-	// Add the converted shares to the new holding
-	newHolding.AddShares(newQuantity, totalCostBasis)
 
-	if newHolding.ID.String() == "00000000-0000-0000-0000-000000000000" || newHolding.ID == [16]byte{} {
-		if err := s.holdingRepo.Create(newHolding); err != nil {
-			return fmt.Errorf("failed to create new holding: %w", err)
+		// Validate merger ratio
+		if ratio.LessThanOrEqual(decimal.Zero) {
+			return fmt.Errorf("invalid merger ratio: must be greater than 0")
 		}
-	} else {
-		if err := s.holdingRepo.Update(newHolding); err != nil {
-			return fmt.Errorf("failed to update new holding: %w", err)
+
+		// 1. Get holding for old symbol
+		oldHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", oldSymbol, err)
 		}
-	}
 
-	// 4. Update tax lots - convert old symbol lots to new symbol
-	oldTaxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(portfolioID, oldSymbol)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve tax lots: %w", err)
-	}
+		// 2. Calculate new position
+		// Example: 1.5:1 ratio means 100 shares of A become 150 shares of B
+		oldQuantity := oldHolding.Quantity
+		newQuantity := s.rounding.RoundQuantity(models.AssetTypeForSymbol(newSymbol), oldQuantity.Mul(ratio))
+		totalCostBasis := oldHolding.CostBasis // Cost basis transfers to new symbol
+
+		// 3. Get or create holding for new symbol
+		newHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, newSymbol)
+		if err != nil {
+			// Create new holding if it doesn't exist
+			newHolding = &models.Holding{
+				PortfolioID:  portfolio.ID,
+				Symbol:       newSymbol,
+				Quantity:     decimal.Zero,
+				CostBasis:    decimal.Zero,
+				AvgCostPrice: decimal.Zero,
+			}
+		}
 
-	for _, lot := range oldTaxLots {
-		// Calculate new quantity based on merger ratio
-		newLotQuantity := lot.Quantity.Mul(ratio)
+		// Add the converted shares to the new holding
+		newHolding.AddShares(newQuantity, totalCostBasis)
+
+		if newHolding.ID.String() == "00000000-0000-0000-0000-000000000000" || newHolding.ID == [16]byte{} {
+			if err := s.holdingRepo.Create(ctx, newHolding); err != nil {
+				return fmt.Errorf("failed to create new holding: %w", err)
+			}
+		} else {
+			if err := s.holdingRepo.Update(ctx, newHolding); err != nil {
+				return fmt.Errorf("failed to update new holding: %w", err)
+			}
+		}
 
-		// Create new tax lot for new symbol with preserved purchase date
-		newLot := &models.TaxLot{
-			PortfolioID:   portfolio.ID,
-			Symbol:        newSymbol,
-			PurchaseDate:  lot.PurchaseDate, // Preserve original purchase date for tax purposes
-			Quantity:      newLotQuantity,
-			CostBasis:     lot.CostBasis, // Cost basis transfers
-			TransactionID: lot.TransactionID,
+		// 4. Update tax lots - convert old symbol lots to new symbol
+		oldTaxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tax lots: %w", err)
 		}
 
-		if err := s.taxLotRepo.Create(newLot); err != nil {
-			return fmt.Errorf("failed to create new tax lot: %w", err)
+		newLotQuantities := s.allocateLotQuantities(oldTaxLots, newSymbol, ratio)
+		for i, lot := range oldTaxLots {
+			// Create new tax lot for new symbol with preserved purchase date
+			newLot := &models.TaxLot{
+				PortfolioID:   portfolio.ID,
+				Symbol:        newSymbol,
+				PurchaseDate:  lot.PurchaseDate, // Preserve original purchase date for tax purposes
+				Quantity:      newLotQuantities[i],
+				CostBasis:     lot.CostBasis, // Cost basis transfers
+				TransactionID: lot.TransactionID,
+			}
+
+			if err := s.taxLotRepo.Create(ctx, newLot); err != nil {
+				return fmt.Errorf("failed to create new tax lot: %w", err)
+			}
 		}
-	}
 
-	// 5. Delete old holding and tax lots
-	if err := s.holdingRepo.DeleteByPortfolioIDAndSymbol(portfolioID, oldSymbol); err != nil {
-		return fmt.Errorf("failed to delete old holding: %w", err)
-	}
+		// 5. Delete old holding and tax lots
+		if err := s.holdingRepo.DeleteByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol); err != nil {
+			return fmt.Errorf("failed to delete old holding: %w", err)
+		}
 
-	if err := s.taxLotRepo.DeleteByPortfolioIDAndSymbol(portfolioID, oldSymbol); err != nil {
-		return fmt.Errorf("failed to delete old tax lots: %w", err)
-	}
+		if err := s.taxLotRepo.DeleteByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol); err != nil {
+			return fmt.Errorf("failed to delete old tax lots: %w", err)
+		}
 
-	// 6. Create MERGER transaction for audit trail
-	mergerTransaction := &models.Transaction{
-		PortfolioID: portfolio.ID,
-		Type:        models.TransactionTypeMerger,
-		Symbol:      newSymbol,
-		Date:        date,
-		Quantity:    newQuantity,
-		Price:       nil, // No price for merger transactions
-		Commission:  decimal.Zero,
-		Currency:    portfolio.BaseCurrency,
-		Notes:       fmt.Sprintf("Merger: %s converted to %s at %s ratio (%s shares became %s shares)", oldSymbol, newSymbol, ratio.String(), oldQuantity.String(), newQuantity.String()),
-	}
+		// 6. Create MERGER transaction for audit trail
+		mergerTransaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeMerger,
+			Symbol:      newSymbol,
+			Date:        date,
+			Quantity:    newQuantity,
+			Price:       nil, // No price for merger transactions
+			Commission:  decimal.Zero,
+			Currency:    portfolio.BaseCurrency,
+			Notes:       fmt.Sprintf("Merger: %s converted to %s at %s ratio (%s shares became %s shares)", oldSymbol, newSymbol, ratio.String(), oldQuantity.String(), newQuantity.String()),
+		}
 
-	if err := s.transactionRepo.Create(mergerTransaction); err != nil {
-		return fmt.Errorf("failed to create merger transaction: %w", err)
-	}
+		if err := s.transactionRepo.Create(ctx, mergerTransaction); err != nil {
+			return fmt.Errorf("failed to create merger transaction: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // ApplySpinoff applies a spinoff to a portfolio
 // A spinoff is when a company distributes shares of a subsidiary to existing shareholders
 // Example: You own 100 shares of Company A. Company A spins off Company B at 0.5:1 ratio.
 // You still have 100 shares of Company A, plus you receive 50 shares of Company B.
+// The allocation is the share of Company A's cost basis that moves to the Company B shares;
+// it and how it was decided are recorded in the SPINOFF transaction's notes.
 func (s *corporateActionService) ApplySpinoff(
+	ctx context.Context,
 	portfolioID, oldSymbol, newSymbol, userID string,
 	ratio decimal.Decimal,
+	allocation models.SpinoffAllocation,
 	date time.Time,
 ) error {
-	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
-	if err != nil {
-		return models.ErrPortfolioNotFound
-	}
-	if portfolio.UserID.String() != userID {
-		return models.ErrUnauthorizedAccess
-	}
+	return s.transaction(ctx, func(s *corporateActionService) error {
+		// Verify portfolio exists and belongs to user
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
 
-	// Validate spinoff ratio
-	if ratio.LessThanOrEqual(decimal.Zero) {
-		return fmt.Errorf("invalid spinoff ratio: must be greater than 0")
-	}
+		// Validate spinoff ratio
+		if ratio.LessThanOrEqual(decimal.Zero) {
+			return fmt.Errorf("invalid spinoff ratio: must be greater than 0")
+		}
+		if err := allocation.Validate(); err != nil {
+			return err
+		}
 
-	// 1. Get holding for parent symbol
-	parentHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, oldSymbol)
-	if err != nil {
-		return fmt.Errorf("no holding found for symbol %s: %w", oldSymbol, err)
-	}
+		// 1. Get holding for parent symbol
+		parentHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", oldSymbol, err)
+		}
 
-	// 2. Calculate spinoff shares
-	// Example: 100 shares of parent at 0.5:1 ratio = 50 shares of spinoff
-	spinoffQuantity := parentHolding.Quantity.Mul(ratio)
+		// 2. Calculate spinoff shares
+		// Example: 100 shares of parent at 0.5:1 ratio = 50 shares of spinoff
+		spinoffQuantity := s.rounding.RoundQuantity(models.AssetTypeForSymbol(newSymbol), parentHolding.Quantity.Mul(ratio))
+
+		// 3. Get or create holding for spinoff symbol
+		spinoffCostBasis := s.rounding.RoundAmount(parentHolding.CostBasis.Mul(allocation.Fraction))
+
+		spinoffHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, newSymbol)
+		if err != nil {
+			// Create new holding if it doesn't exist
+			spinoffHolding = &models.Holding{
+				PortfolioID:  portfolio.ID,
+				Symbol:       newSymbol,
+				Quantity:     decimal.Zero,
+				CostBasis:    decimal.Zero,
+				AvgCostPrice: decimal.Zero,
+			}
+		}
 
-	// 3. Get or create holding for spinoff symbol
-	// For spinoffs, the cost basis of the parent is typically allocated between
-	// parent and spinoff based on relative fair market values on distribution date.
-	// For simplicity, we'll allocate a small percentage (10%) to the spinoff,
-	// but in production this should be configurable or based on actual market values.
-	costBasisAllocationRatio := decimal.NewFromFloat(0.10) // 10% to spinoff, 90% stays with parent
-	spinoffCostBasis := parentHolding.CostBasis.Mul(costBasisAllocationRatio)
+		// Add the spinoff shares
+		spinoffHolding.AddShares(spinoffQuantity, spinoffCostBasis)
+
+		if spinoffHolding.ID.String() == "00000000-0000-0000-0000-000000000000" || spinoffHolding.ID == [16]byte{} {
+			if err := s.holdingRepo.Create(ctx, spinoffHolding); err != nil {
+				return fmt.Errorf("failed to create spinoff holding: %w", err)
+			}
+		} else {
+			if err := s.holdingRepo.Update(ctx, spinoffHolding); err != nil {
+				return fmt.Errorf("failed to update spinoff holding: %w", err)
+			}
+		}
 
-	spinoffHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, newSymbol)
-	if err != nil {
-		// Create new holding if it doesn't exist
-		spinoffHolding = &models.Holding{
-			PortfolioID:  portfolio.ID,
-			Symbol:       newSymbol,
-			Quantity:     decimal.Zero,
-			CostBasis:    decimal.Zero,
-			AvgCostPrice: decimal.Zero,
+		// 4. Reduce parent holding cost basis by the amount moved to the spinoff
+		parentHolding.CostBasis = parentHolding.CostBasis.Sub(spinoffCostBasis)
+		parentHolding.CalculateAvgCostPrice()
+
+		if err := s.holdingRepo.Update(ctx, parentHolding); err != nil {
+			return fmt.Errorf("failed to update parent holding: %w", err)
 		}
-	}
 
-	// Add the spinoff shares
-	spinoffHolding.AddShares(spinoffQuantity, spinoffCostBasis)
+		// 5. Create tax lots for spinoff shares
+		// Tax lots inherit the purchase dates from the parent company
+		parentTaxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve parent tax lots: %w", err)
+		}
 
-	if spinoffHolding.ID.String() == "00000000-0000-0000-0000-000000000000" || spinoffHolding.ID == [16]byte{} {
-		if err := s.holdingRepo.Create(spinoffHolding); err != nil {
-			return fmt.Errorf("failed to create spinoff holding: %w", err)
+		// Allocate spinoff shares and cost basis proportionally to each lot's share of the
+		// parent position, so the lots add up to the spinoff holding exactly
+		weights := make([]decimal.Decimal, len(parentTaxLots))
+		for i, lot := range parentTaxLots {
+			weights[i] = lot.Quantity
 		}
-	} else {
-		if err := s.holdingRepo.Update(spinoffHolding); err != nil {
-			return fmt.Errorf("failed to update spinoff holding: %w", err)
+		spinoffLotQuantities := s.rounding.AllocateQuantity(models.AssetTypeForSymbol(newSymbol), spinoffQuantity, weights)
+		spinoffLotCostBases := s.rounding.AllocateAmount(spinoffCostBasis, weights)
+
+		for i, parentLot := range parentTaxLots {
+			spinoffLotCostBasis := spinoffLotCostBases[i]
+
+			// Create new tax lot for spinoff with same purchase date as parent
+			spinoffLot := &models.TaxLot{
+				PortfolioID:   portfolio.ID,
+				Symbol:        newSymbol,
+				PurchaseDate:  parentLot.PurchaseDate, // Inherit purchase date for tax purposes
+				Quantity:      spinoffLotQuantities[i],
+				CostBasis:     spinoffLotCostBasis,
+				TransactionID: parentLot.TransactionID,
+			}
+
+			if err := s.taxLotRepo.Create(ctx, spinoffLot); err != nil {
+				return fmt.Errorf("failed to create spinoff tax lot: %w", err)
+			}
+
+			// Reduce parent lot cost basis
+			parentLot.CostBasis = parentLot.CostBasis.Sub(spinoffLotCostBasis)
+			if err := s.taxLotRepo.Update(ctx, parentLot); err != nil {
+				return fmt.Errorf("failed to update parent tax lot: %w", err)
+			}
+		}
+
+		// 6. Create SPINOFF transaction for audit trail
+		spinoffTransaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeSpinoff,
+			Symbol:      newSymbol,
+			Date:        date,
+			Quantity:    spinoffQuantity,
+			Price:       nil, // No price for spinoff transactions
+			Commission:  decimal.Zero,
+			Currency:    portfolio.BaseCurrency,
+			Notes: fmt.Sprintf("Spinoff: received %s shares of %s from %s at %s ratio, allocating %s of cost basis (%s)",
+				spinoffQuantity.String(), newSymbol, oldSymbol, ratio.String(), allocation.Fraction.String(), allocation.Describe(oldSymbol, newSymbol)),
 		}
-	}
 
-	// 4. Reduce parent holding cost basis (parent retains 90% of cost basis)
-	parentHolding.CostBasis = parentHolding.CostBasis.Sub(spinoffCostBasis)
-	parentHolding.CalculateAvgCostPrice()
+		if err := s.transactionRepo.Create(ctx, spinoffTransaction); err != nil {
+			return fmt.Errorf("failed to create spinoff transaction: %w", err)
+		}
 
-	if err := s.holdingRepo.Update(parentHolding); err != nil {
-		return fmt.Errorf("failed to update parent holding: %w", err)
-	}
+		return nil
+	})
+}
 
-	// 5. Create tax lots for spinoff shares
-	// Tax lots inherit the purchase dates from the parent company
-	parentTaxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(portfolioID, oldSymbol)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve parent tax lots: %w", err)
-	}
+// ApplyTickerChange applies a ticker symbol change to a portfolio
+// This is when a company changes its ticker symbol but remains the same company
+// Example: Facebook (FB) changed to Meta (META)
+func (s *corporateActionService) ApplyTickerChange(
+	ctx context.Context,
+	portfolioID, oldSymbol, newSymbol, userID string,
+	date time.Time,
+) error {
+	return s.transaction(ctx, func(s *corporateActionService) error {
+		// Verify portfolio exists and belongs to user
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
 
-	totalParentQuantity := decimal.Zero
-	for _, lot := range parentTaxLots {
-		totalParentQuantity = totalParentQuantity.Add(lot.Quantity)
-	}
+		// 1. Get holding for old symbol
+		oldHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", oldSymbol, err)
+		}
 
-	for _, parentLot := range parentTaxLots {
-		// Allocate spinoff shares proportionally based on each lot's percentage of total
-		lotPercentage := parentLot.Quantity.Div(totalParentQuantity)
-		spinoffLotQuantity := spinoffQuantity.Mul(lotPercentage)
-		spinoffLotCostBasis := spinoffCostBasis.Mul(lotPercentage)
+		// 2. For ticker changes, everything stays the same except the symbol
+		// Quantity, cost basis, and average cost price all remain unchanged
+		quantity := oldHolding.Quantity
+		costBasis := oldHolding.CostBasis
+		avgCostPrice := oldHolding.AvgCostPrice
+
+		// 3. Get or create holding for new symbol
+		newHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, newSymbol)
+		if err != nil {
+			// Create new holding with same values
+			newHolding = &models.Holding{
+				PortfolioID:  portfolio.ID,
+				Symbol:       newSymbol,
+				Quantity:     quantity,
+				CostBasis:    costBasis,
+				AvgCostPrice: avgCostPrice,
+			}
+			if err := s.holdingRepo.Create(ctx, newHolding); err != nil {
+				return fmt.Errorf("failed to create new holding: %w", err)
+			}
+		} else {
+			// If holding already exists, add to it
+			newHolding.AddShares(quantity, costBasis)
+			if err := s.holdingRepo.Update(ctx, newHolding); err != nil {
+				return fmt.Errorf("failed to update new holding: %w", err)
+			}
+		}
 
-		// Create new tax lot for spinoff with same purchase date as parent
-		spinoffLot := &models.TaxLot{
-			PortfolioID:   portfolio.ID,
-			Symbol:        newSymbol,
-			PurchaseDate:  parentLot.PurchaseDate, // Inherit purchase date for tax purposes
-			Quantity:      spinoffLotQuantity,
-			CostBasis:     spinoffLotCostBasis,
-			TransactionID: parentLot.TransactionID,
+		// 4. Update all tax lots - change symbol but keep everything else
+		oldTaxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tax lots: %w", err)
 		}
 
-		if err := s.taxLotRepo.Create(spinoffLot); err != nil {
-			return fmt.Errorf("failed to create spinoff tax lot: %w", err)
+		for _, lot := range oldTaxLots {
+			// Create new tax lot with new symbol but all other data preserved
+			newLot := &models.TaxLot{
+				PortfolioID:   portfolio.ID,
+				Symbol:        newSymbol,
+				PurchaseDate:  lot.PurchaseDate, // Preserve original purchase date
+				Quantity:      lot.Quantity,     // Same quantity
+				CostBasis:     lot.CostBasis,    // Same cost basis
+				TransactionID: lot.TransactionID,
+			}
+
+			if err := s.taxLotRepo.Create(ctx, newLot); err != nil {
+				return fmt.Errorf("failed to create new tax lot: %w", err)
+			}
 		}
 
-		// Reduce parent lot cost basis
-		parentLot.CostBasis = parentLot.CostBasis.Sub(spinoffLotCostBasis)
-		if err := s.taxLotRepo.Update(parentLot); err != nil {
-			return fmt.Errorf("failed to update parent tax lot: %w", err)
+		// 5. Update all old transactions with the old symbol to use the new symbol
+		// This maintains historical accuracy
+		oldTransactions, err := s.transactionRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve transactions: %w", err)
 		}
-	}
 
-	// 6. Create SPINOFF transaction for audit trail
-	spinoffTransaction := &models.Transaction{
-		PortfolioID: portfolio.ID,
-		Type:        models.TransactionTypeSpinoff,
-		Symbol:      newSymbol,
-		Date:        date,
-		Quantity:    spinoffQuantity,
-		Price:       nil, // No price for spinoff transactions
-		Commission:  decimal.Zero,
-		Currency:    portfolio.BaseCurrency,
-		Notes:       fmt.Sprintf("Spinoff: received %s shares of %s from %s at %s ratio", spinoffQuantity.String(), newSymbol, oldSymbol, ratio.String()),
-	}
+		for _, txn := range oldTransactions {
+			txn.Symbol = newSymbol
+			if err := s.transactionRepo.Update(ctx, txn); err != nil {
+				return fmt.Errorf("failed to update transaction: %w", err)
+			}
+		}
 
-	if err := s.transactionRepo.Create(spinoffTransaction); err != nil {
-		return fmt.Errorf("failed to create spinoff transaction: %w", err)
-	}
+		// 6. Delete old holding and tax lots
+		if err := s.holdingRepo.DeleteByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol); err != nil {
+			return fmt.Errorf("failed to delete old holding: %w", err)
+		}
 
-	return nil
+		if err := s.taxLotRepo.DeleteByPortfolioIDAndSymbol(ctx, portfolioID, oldSymbol); err != nil {
+			return fmt.Errorf("failed to delete old tax lots: %w", err)
+		}
+
+		// 7. Create TICKER_CHANGE transaction for audit trail
+		tickerChangeTransaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeTickerChange,
+			Symbol:      newSymbol,
+			Date:        date,
+			Quantity:    quantity,
+			Price:       nil, // No price for ticker change
+			Commission:  decimal.Zero,
+			Currency:    portfolio.BaseCurrency,
+			Notes:       fmt.Sprintf("Ticker change: %s changed to %s", oldSymbol, newSymbol),
+		}
+
+		if err := s.transactionRepo.Create(ctx, tickerChangeTransaction); err != nil {
+			return fmt.Errorf("failed to create ticker change transaction: %w", err)
+		}
+
+		return nil
+	})
 }
 
-// ApplyTickerChange applies a ticker symbol change to a portfolio
-// This is when a company changes its ticker symbol but remains the same company
-// Example: Facebook (FB) changed to Meta (META)
-func (s *corporateActionService) ApplyTickerChange(
-	portfolioID, oldSymbol, newSymbol, userID string,
+// ApplyReturnOfCapital applies a non-dividend distribution to a portfolio
+// A return of capital pays shareholders back part of their investment, so rather than being
+// income it lowers the cost basis of every lot in proportion to its shares. Once a lot's cost
+// basis reaches zero, the rest of its share of the distribution is a capital gain.
+// Example: You own 100 shares with a $500 cost basis and receive $600. The cost basis drops
+// to $0 and the remaining $100 is realized as a gain.
+func (s *corporateActionService) ApplyReturnOfCapital(
+	ctx context.Context,
+	portfolioID, symbol, userID string,
+	amount decimal.Decimal,
 	date time.Time,
 ) error {
-	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
-	if err != nil {
-		return models.ErrPortfolioNotFound
-	}
-	if portfolio.UserID.String() != userID {
-		return models.ErrUnauthorizedAccess
-	}
+	return s.transaction(ctx, func(s *corporateActionService) error {
+		// Verify portfolio exists and belongs to user
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
 
-	// 1. Get holding for old symbol
-	oldHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, oldSymbol)
-	if err != nil {
-		return fmt.Errorf("no holding found for symbol %s: %w", oldSymbol, err)
-	}
+		// Validate distribution amount
+		amount = s.rounding.RoundAmount(amount)
+		if amount.LessThanOrEqual(decimal.Zero) {
+			return fmt.Errorf("invalid return of capital amount: must be greater than 0")
+		}
 
-	// 2. For ticker changes, everything stays the same except the symbol
-	// Quantity, cost basis, and average cost price all remain unchanged
-	quantity := oldHolding.Quantity
-	costBasis := oldHolding.CostBasis
-	avgCostPrice := oldHolding.AvgCostPrice
+		// 1. Get holding and tax lots for the symbol
+		holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
+		}
 
-	// 3. Get or create holding for new symbol
-	newHolding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, newSymbol)
-	if err != nil {
-		// Create new holding with same values
-		newHolding = &models.Holding{
-			PortfolioID:  portfolio.ID,
-			Symbol:       newSymbol,
-			Quantity:     quantity,
-			CostBasis:    costBasis,
-			AvgCostPrice: avgCostPrice,
-		}
-		if err := s.holdingRepo.Create(newHolding); err != nil {
-			return fmt.Errorf("failed to create new holding: %w", err)
-		}
-	} else {
-		// If holding already exists, add to it
-		newHolding.AddShares(quantity, costBasis)
-		if err := s.holdingRepo.Update(newHolding); err != nil {
-			return fmt.Errorf("failed to update new holding: %w", err)
+		taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tax lots: %w", err)
 		}
-	}
 
-	// 4. Update all tax lots - change symbol but keep everything else
-	oldTaxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(portfolioID, oldSymbol)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve tax lots: %w", err)
-	}
+		// 2. Reduce the cost basis of the holding and its lots
+		reduction, _ := returnCapital(s.rounding, holding, taxLots, amount, date)
 
-	for _, lot := range oldTaxLots {
-		// Create new tax lot with new symbol but all other data preserved
-		newLot := &models.TaxLot{
-			PortfolioID:   portfolio.ID,
-			Symbol:        newSymbol,
-			PurchaseDate:  lot.PurchaseDate, // Preserve original purchase date
-			Quantity:      lot.Quantity,     // Same quantity
-			CostBasis:     lot.CostBasis,    // Same cost basis
-			TransactionID: lot.TransactionID,
+		if err := s.holdingRepo.Update(ctx, holding); err != nil {
+			return fmt.Errorf("failed to update holding: %w", err)
 		}
 
-		if err := s.taxLotRepo.Create(newLot); err != nil {
-			return fmt.Errorf("failed to create new tax lot: %w", err)
+		for _, lot := range taxLots {
+			if err := s.taxLotRepo.Update(ctx, lot); err != nil {
+				return fmt.Errorf("failed to update tax lot: %w", err)
+			}
 		}
-	}
 
-	// 5. Update all old transactions with the old symbol to use the new symbol
-	// This maintains historical accuracy
-	oldTransactions, err := s.transactionRepo.FindByPortfolioIDAndSymbol(portfolioID, oldSymbol)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve transactions: %w", err)
-	}
+		// 3. Create RETURN_OF_CAPITAL transaction for audit trail
+		returnOfCapitalTransaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeReturnOfCapital,
+			Symbol:      symbol,
+			Date:        date,
+			Quantity:    amount, // Like dividends, quantity represents the total amount received
+			Price:       nil,    // No price for return of capital transactions
+			Commission:  decimal.Zero,
+			Currency:    portfolio.BaseCurrency,
+			Notes: fmt.Sprintf("Return of capital: %s reduced cost basis by %s, realizing %s as capital gain",
+				amount.String(), reduction.String(), amount.Sub(reduction).String()),
+		}
 
-	for _, txn := range oldTransactions {
-		txn.Symbol = newSymbol
-		if err := s.transactionRepo.Update(txn); err != nil {
-			return fmt.Errorf("failed to update transaction: %w", err)
+		if err := s.transactionRepo.Create(ctx, returnOfCapitalTransaction); err != nil {
+			return fmt.Errorf("failed to create return of capital transaction: %w", err)
 		}
-	}
 
-	// 6. Delete old holding and tax lots
-	if err := s.holdingRepo.DeleteByPortfolioIDAndSymbol(portfolioID, oldSymbol); err != nil {
-		return fmt.Errorf("failed to delete old holding: %w", err)
-	}
+		return nil
+	})
+}
 
-	if err := s.taxLotRepo.DeleteByPortfolioIDAndSymbol(portfolioID, oldSymbol); err != nil {
-		return fmt.Errorf("failed to delete old tax lots: %w", err)
-	}
+// ApplyStockDividend applies a dividend paid in shares to a portfolio
+// The rate is the new shares received per share held. A nontaxable stock dividend carries no
+// cost basis of its own: the position's cost basis is spread over the old and new shares, so
+// each lot keeps its basis and purchase date and only its quantity grows.
+// Example: A 5% stock dividend on 100 shares with a $1,000 cost basis gives 105 shares with
+// the same $1,000 cost basis.
+func (s *corporateActionService) ApplyStockDividend(
+	ctx context.Context,
+	portfolioID, symbol, userID string,
+	rate decimal.Decimal,
+	date time.Time,
+) error {
+	return s.transaction(ctx, func(s *corporateActionService) error {
+		// Verify portfolio exists and belongs to user
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
 
-	// 7. Create TICKER_CHANGE transaction for audit trail
-	tickerChangeTransaction := &models.Transaction{
-		PortfolioID: portfolio.ID,
-		Type:        models.TransactionTypeTickerChange,
-		Symbol:      newSymbol,
-		Date:        date,
-		Quantity:    quantity,
-		Price:       nil, // No price for ticker change
-		Commission:  decimal.Zero,
-		Currency:    portfolio.BaseCurrency,
-		Notes:       fmt.Sprintf("Ticker change: %s changed to %s", oldSymbol, newSymbol),
-	}
+		// Validate dividend rate
+		if rate.LessThanOrEqual(decimal.Zero) {
+			return fmt.Errorf("invalid stock dividend rate: must be greater than 0")
+		}
+
+		// 1. Get holding for the symbol
+		holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
+		}
+
+		// 2. Add the dividend shares, keeping the cost basis unchanged
+		ratio := decimal.NewFromInt(1).Add(rate)
+		oldQuantity := holding.Quantity
+		newQuantity := s.rounding.RoundQuantity(holding.AssetType, holding.Quantity.Mul(ratio))
+		received := newQuantity.Sub(oldQuantity)
+		if !received.IsPositive() {
+			return fmt.Errorf("invalid stock dividend rate: %s shares at %s rounds to no new shares", oldQuantity.String(), rate.String())
+		}
+
+		holding.Quantity = newQuantity
+		holding.CalculateAvgCostPrice()
+
+		if err := s.holdingRepo.Update(ctx, holding); err != nil {
+			return fmt.Errorf("failed to update holding: %w", err)
+		}
+
+		// 3. Grow each tax lot by the same rate; its cost basis and purchase date carry over to
+		// the new shares
+		taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve tax lots: %w", err)
+		}
+
+		lotQuantities := s.allocateLotQuantities(taxLots, symbol, ratio)
+		for i, lot := range taxLots {
+			lot.Quantity = lotQuantities[i]
+
+			if err := s.taxLotRepo.Update(ctx, lot); err != nil {
+				return fmt.Errorf("failed to update tax lot: %w", err)
+			}
+		}
+
+		// 4. Create STOCK_DIVIDEND transaction for audit trail
+		stockDividendTransaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeStockDividend,
+			Symbol:      symbol,
+			Date:        date,
+			Quantity:    received, // Shares received
+			Price:       nil,      // No price for stock dividends
+			Commission:  decimal.Zero,
+			Currency:    portfolio.BaseCurrency,
+			Notes:       fmt.Sprintf("Stock dividend: %s shares per share held, received %s shares", rate.String(), received.String()),
+		}
+
+		if err := s.transactionRepo.Create(ctx, stockDividendTransaction); err != nil {
+			return fmt.Errorf("failed to create stock dividend transaction: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ApplyCapitalGainDistribution applies a fund's capital gain distribution to a portfolio
+// A fund passes the gains it realized on to its shareholders, who are taxed on them although
+// they sold nothing. Shares and cost basis are unchanged; the distribution is recorded as a
+// long-term CAPITAL_GAIN_DISTRIBUTION, the way funds designate them unless told otherwise.
+func (s *corporateActionService) ApplyCapitalGainDistribution(
+	ctx context.Context,
+	portfolioID, symbol, userID string,
+	amount decimal.Decimal,
+	date time.Time,
+) error {
+	return s.transaction(ctx, func(s *corporateActionService) error {
+		// Verify portfolio exists and belongs to user
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
+
+		// Validate distribution amount
+		amount = s.rounding.RoundAmount(amount)
+		if amount.LessThanOrEqual(decimal.Zero) {
+			return fmt.Errorf("invalid capital gain distribution amount: must be greater than 0")
+		}
+
+		// 1. Get holding for the symbol to verify it exists
+		_, err = s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
+		}
+
+		// 2. Create a CAPITAL_GAIN_DISTRIBUTION transaction, which the tax report counts as a gain
+		distributionTransaction := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        models.TransactionTypeCapitalGainDistribution,
+			Symbol:      symbol,
+			Date:        date,
+			Quantity:    amount, // Like dividends, quantity represents the total amount received
+			Price:       nil,    // No price for distributions
+			Commission:  decimal.Zero,
+			Currency:    portfolio.BaseCurrency,
+			Category:    models.CapitalGainTermLong,
+			Notes:       fmt.Sprintf("Capital gain distribution: %s", amount.String()),
+		}
+
+		if err := s.transactionRepo.Create(ctx, distributionTransaction); err != nil {
+			return fmt.Errorf("failed to create capital gain distribution transaction: %w", err)
+		}
 
-	if err := s.transactionRepo.Create(tickerChangeTransaction); err != nil {
-		return fmt.Errorf("failed to create ticker change transaction: %w", err)
+		return nil
+	})
+}
+
+// returnCapital applies a return-of-capital distribution to a holding and its lots. The
+// amount is split across the lots by quantity, and each lot's cost basis falls by its share
+// down to zero; anything beyond that is realized as a gain, short- or long-term by the lot's
+// holding period. It returns the total cost basis reduction and the gains. A holding without
+// lots is reduced directly.
+func returnCapital(rounding models.RoundingPolicy, holding *models.Holding, lots []*models.TaxLot, amount decimal.Decimal, date time.Time) (decimal.Decimal, []*RealizedGain) {
+	if len(lots) == 0 {
+		reduction := decimal.Min(amount, holding.CostBasis)
+		holding.CostBasis = holding.CostBasis.Sub(reduction)
+		holding.CalculateAvgCostPrice()
+		return reduction, nil
+	}
+
+	weights := make([]decimal.Decimal, len(lots))
+	for i, lot := range lots {
+		weights[i] = lot.Quantity
+	}
+	shares := rounding.AllocateAmount(amount, weights)
+
+	reduction := decimal.Zero
+	var gains []*RealizedGain
+	for i, lot := range lots {
+		lotReduction := decimal.Min(shares[i], lot.CostBasis)
+		lot.CostBasis = lot.CostBasis.Sub(lotReduction)
+		reduction = reduction.Add(lotReduction)
+
+		if excess := shares[i].Sub(lotReduction); excess.IsPositive() {
+			gains = append(gains, &RealizedGain{
+				Symbol:       lot.Symbol,
+				PurchaseDate: lot.PurchaseDate,
+				SaleDate:     date,
+				Quantity:     lot.Quantity,
+				CostBasis:    decimal.Zero,
+				Proceeds:     excess,
+				Gain:         excess,
+				IsLongTerm:   lot.IsLongTerm(date),
+			})
+		}
 	}
 
-	return nil
+	holding.CostBasis = decimal.Max(holding.CostBasis.Sub(reduction), decimal.Zero)
+	holding.CalculateAvgCostPrice()
+	return reduction, gains
+}
+
+// allocateLotQuantities converts the quantities of lots at ratio into shares of symbol. The
+// converted total is rounded once and split across the lots in proportion to their old
+// quantities, so rounding never makes the lots disagree with the holding.
+func (s *corporateActionService) allocateLotQuantities(lots []*models.TaxLot, symbol string, ratio decimal.Decimal) []decimal.Decimal {
+	assetType := models.AssetTypeForSymbol(symbol)
+	weights := make([]decimal.Decimal, len(lots))
+	total := decimal.Zero
+	for i, lot := range lots {
+		weights[i] = lot.Quantity
+		total = total.Add(lot.Quantity)
+	}
+	return s.rounding.AllocateQuantity(assetType, s.rounding.RoundQuantity(assetType, total.Mul(ratio)), weights)
 }
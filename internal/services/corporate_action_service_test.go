@@ -1,7 +1,10 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +13,8 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
 )
 
 // Mock repositories for testing
@@ -17,12 +22,12 @@ type MockTaxLotRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaxLotRepository) Create(lot *models.TaxLot) error {
+func (m *MockTaxLotRepository) Create(ctx context.Context, lot *models.TaxLot) error {
 	args := m.Called(lot)
 	return args.Error(0)
 }
 
-func (m *MockTaxLotRepository) FindByID(id string) (*models.TaxLot, error) {
+func (m *MockTaxLotRepository) FindByID(ctx context.Context, id string) (*models.TaxLot, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -30,7 +35,7 @@ func (m *MockTaxLotRepository) FindByID(id string) (*models.TaxLot, error) {
 	return args.Get(0).(*models.TaxLot), args.Error(1)
 }
 
-func (m *MockTaxLotRepository) FindByPortfolioID(portfolioID string) ([]*models.TaxLot, error) {
+func (m *MockTaxLotRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.TaxLot, error) {
 	args := m.Called(portfolioID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -38,7 +43,7 @@ func (m *MockTaxLotRepository) FindByPortfolioID(portfolioID string) ([]*models.
 	return args.Get(0).([]*models.TaxLot), args.Error(1)
 }
 
-func (m *MockTaxLotRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol string) ([]*models.TaxLot, error) {
+func (m *MockTaxLotRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) ([]*models.TaxLot, error) {
 	args := m.Called(portfolioID, symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -46,27 +51,29 @@ func (m *MockTaxLotRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol st
 	return args.Get(0).([]*models.TaxLot), args.Error(1)
 }
 
-func (m *MockTaxLotRepository) Update(lot *models.TaxLot) error {
+func (m *MockTaxLotRepository) Update(ctx context.Context, lot *models.TaxLot) error {
 	args := m.Called(lot)
 	return args.Error(0)
 }
 
-func (m *MockTaxLotRepository) Delete(id string) error {
+func (m *MockTaxLotRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockTaxLotRepository) DeleteByPortfolioID(portfolioID string) error {
+func (m *MockTaxLotRepository) DeleteByPortfolioID(ctx context.Context, portfolioID string) error {
 	args := m.Called(portfolioID)
 	return args.Error(0)
 }
 
-func (m *MockTaxLotRepository) DeleteByPortfolioIDAndSymbol(portfolioID, symbol string) error {
+func (m *MockTaxLotRepository) DeleteByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) error {
 	args := m.Called(portfolioID, symbol)
 	return args.Error(0)
 }
 
 func TestApplyStockSplit_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -112,7 +119,7 @@ func TestApplyStockSplit_Success(t *testing.T) {
 	taxLotRepo.On("Update", mock.AnythingOfType("*models.TaxLot")).Return(nil)
 	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Return(nil)
 
-	err := service.ApplyStockSplit(portfolioID.String(), symbol, userID.String(), ratio, date)
+	err := service.ApplyStockSplit(ctx, portfolioID.String(), symbol, userID.String(), ratio, date)
 
 	assert.NoError(t, err)
 	portfolioRepo.AssertExpectations(t)
@@ -122,6 +129,8 @@ func TestApplyStockSplit_Success(t *testing.T) {
 }
 
 func TestApplyStockSplit_PortfolioNotFound(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -136,7 +145,7 @@ func TestApplyStockSplit_PortfolioNotFound(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(nil, fmt.Errorf("not found"))
 
-	err := service.ApplyStockSplit(portfolioID, "AAPL", userID, ratio, time.Now())
+	err := service.ApplyStockSplit(ctx, portfolioID, "AAPL", userID, ratio, time.Now())
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrPortfolioNotFound, err)
@@ -144,6 +153,8 @@ func TestApplyStockSplit_PortfolioNotFound(t *testing.T) {
 }
 
 func TestApplyStockSplit_UnauthorizedAccess(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -164,7 +175,7 @@ func TestApplyStockSplit_UnauthorizedAccess(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
 
-	err := service.ApplyStockSplit(portfolioID.String(), "AAPL", differentUserID.String(), ratio, time.Now())
+	err := service.ApplyStockSplit(ctx, portfolioID.String(), "AAPL", differentUserID.String(), ratio, time.Now())
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrUnauthorizedAccess, err)
@@ -172,6 +183,8 @@ func TestApplyStockSplit_UnauthorizedAccess(t *testing.T) {
 }
 
 func TestApplyStockSplit_InvalidRatio(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -191,7 +204,7 @@ func TestApplyStockSplit_InvalidRatio(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
 
-	err := service.ApplyStockSplit(portfolioID.String(), "AAPL", userID.String(), invalidRatio, time.Now())
+	err := service.ApplyStockSplit(ctx, portfolioID.String(), "AAPL", userID.String(), invalidRatio, time.Now())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid split ratio")
@@ -199,6 +212,8 @@ func TestApplyStockSplit_InvalidRatio(t *testing.T) {
 }
 
 func TestApplyDividend_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -231,7 +246,7 @@ func TestApplyDividend_Success(t *testing.T) {
 	holdingRepo.On("FindByPortfolioIDAndSymbol", portfolioID.String(), symbol).Return(holding, nil)
 	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Return(nil)
 
-	err := service.ApplyDividend(portfolioID.String(), symbol, userID.String(), amount, date)
+	err := service.ApplyDividend(ctx, portfolioID.String(), symbol, userID.String(), amount, date)
 
 	assert.NoError(t, err)
 	portfolioRepo.AssertExpectations(t)
@@ -240,6 +255,8 @@ func TestApplyDividend_Success(t *testing.T) {
 }
 
 func TestApplyDividend_InvalidAmount(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -259,7 +276,7 @@ func TestApplyDividend_InvalidAmount(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
 
-	err := service.ApplyDividend(portfolioID.String(), "AAPL", userID.String(), invalidAmount, time.Now())
+	err := service.ApplyDividend(ctx, portfolioID.String(), "AAPL", userID.String(), invalidAmount, time.Now())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid dividend amount")
@@ -267,6 +284,8 @@ func TestApplyDividend_InvalidAmount(t *testing.T) {
 }
 
 func Skip_TestApplyMerger_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -307,7 +326,7 @@ func Skip_TestApplyMerger_Success(t *testing.T) {
 	taxLotRepo.On("DeleteByPortfolioIDAndSymbol", portfolioID.String(), oldSymbol).Return(nil)
 	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Return(nil).Times(2) // SELL and BUY
 
-	err := service.ApplyMerger(portfolioID.String(), oldSymbol, newSymbol, userID.String(), ratio, date)
+	err := service.ApplyMerger(ctx, portfolioID.String(), oldSymbol, newSymbol, userID.String(), ratio, date)
 
 	assert.NoError(t, err)
 	portfolioRepo.AssertExpectations(t)
@@ -317,6 +336,8 @@ func Skip_TestApplyMerger_Success(t *testing.T) {
 }
 
 func TestApplyMerger_InvalidRatio(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -336,7 +357,7 @@ func TestApplyMerger_InvalidRatio(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
 
-	err := service.ApplyMerger(portfolioID.String(), "FB", "META", userID.String(), invalidRatio, time.Now())
+	err := service.ApplyMerger(ctx, portfolioID.String(), "FB", "META", userID.String(), invalidRatio, time.Now())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid merger ratio")
@@ -348,12 +369,12 @@ type MockCorporateActionRepository struct {
 	mock.Mock
 }
 
-func (m *MockCorporateActionRepository) Create(action *models.CorporateAction) error {
+func (m *MockCorporateActionRepository) Create(ctx context.Context, action *models.CorporateAction) error {
 	args := m.Called(action)
 	return args.Error(0)
 }
 
-func (m *MockCorporateActionRepository) FindByID(id string) (*models.CorporateAction, error) {
+func (m *MockCorporateActionRepository) FindByID(ctx context.Context, id string) (*models.CorporateAction, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -361,7 +382,7 @@ func (m *MockCorporateActionRepository) FindByID(id string) (*models.CorporateAc
 	return args.Get(0).(*models.CorporateAction), args.Error(1)
 }
 
-func (m *MockCorporateActionRepository) FindBySymbol(symbol string) ([]*models.CorporateAction, error) {
+func (m *MockCorporateActionRepository) FindBySymbol(ctx context.Context, symbol string) ([]*models.CorporateAction, error) {
 	args := m.Called(symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -369,7 +390,7 @@ func (m *MockCorporateActionRepository) FindBySymbol(symbol string) ([]*models.C
 	return args.Get(0).([]*models.CorporateAction), args.Error(1)
 }
 
-func (m *MockCorporateActionRepository) FindBySymbolAndDate(symbol string, date time.Time) (*models.CorporateAction, error) {
+func (m *MockCorporateActionRepository) FindBySymbolAndDate(ctx context.Context, symbol string, date time.Time) (*models.CorporateAction, error) {
 	args := m.Called(symbol, date)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -377,7 +398,7 @@ func (m *MockCorporateActionRepository) FindBySymbolAndDate(symbol string, date
 	return args.Get(0).(*models.CorporateAction), args.Error(1)
 }
 
-func (m *MockCorporateActionRepository) FindBySymbolAndDateRange(symbol string, startDate, endDate time.Time) ([]*models.CorporateAction, error) {
+func (m *MockCorporateActionRepository) FindBySymbolAndDateRange(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*models.CorporateAction, error) {
 	args := m.Called(symbol, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -385,7 +406,7 @@ func (m *MockCorporateActionRepository) FindBySymbolAndDateRange(symbol string,
 	return args.Get(0).([]*models.CorporateAction), args.Error(1)
 }
 
-func (m *MockCorporateActionRepository) FindUnapplied() ([]*models.CorporateAction, error) {
+func (m *MockCorporateActionRepository) FindUnapplied(ctx context.Context) ([]*models.CorporateAction, error) {
 	args := m.Called()
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -393,17 +414,25 @@ func (m *MockCorporateActionRepository) FindUnapplied() ([]*models.CorporateActi
 	return args.Get(0).([]*models.CorporateAction), args.Error(1)
 }
 
-func (m *MockCorporateActionRepository) Update(action *models.CorporateAction) error {
+func (m *MockCorporateActionRepository) Update(ctx context.Context, action *models.CorporateAction) error {
 	args := m.Called(action)
 	return args.Error(0)
 }
 
-func (m *MockCorporateActionRepository) Delete(id string) error {
+func (m *MockCorporateActionRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockTaxLotRepository) FindByTransactionID(transactionID string) ([]*models.TaxLot, error) {
+func (m *MockTaxLotRepository) FindByPortfolioIDAndSymbols(ctx context.Context, portfolioID string, symbols []string) ([]*models.TaxLot, error) {
+	args := m.Called(portfolioID, symbols)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.TaxLot), args.Error(1)
+}
+
+func (m *MockTaxLotRepository) FindByTransactionID(ctx context.Context, transactionID string) ([]*models.TaxLot, error) {
 	args := m.Called(transactionID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -414,6 +443,8 @@ func (m *MockTaxLotRepository) FindByTransactionID(transactionID string) ([]*mod
 // CRUD Tests
 
 func TestCorporateActionService_Create(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -436,7 +467,7 @@ func TestCorporateActionService_Create(t *testing.T) {
 			action.Description == description
 	})).Return(nil)
 
-	result, err := service.Create(symbol, actionType, date, &ratio, nil, nil, nil, &description)
+	result, err := service.Create(ctx, symbol, actionType, date, &ratio, nil, nil, nil, &description)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -446,6 +477,8 @@ func TestCorporateActionService_Create(t *testing.T) {
 }
 
 func TestCorporateActionService_Create_ValidationError(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -460,7 +493,7 @@ func TestCorporateActionService_Create_ValidationError(t *testing.T) {
 	date := time.Now()
 	description := "test"
 
-	result, err := service.Create(symbol, actionType, date, nil, nil, nil, nil, &description)
+	result, err := service.Create(ctx, symbol, actionType, date, nil, nil, nil, nil, &description)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -468,6 +501,8 @@ func TestCorporateActionService_Create_ValidationError(t *testing.T) {
 }
 
 func TestCorporateActionService_GetByID(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -485,7 +520,7 @@ func TestCorporateActionService_GetByID(t *testing.T) {
 
 	caRepo.On("FindByID", actionID).Return(action, nil)
 
-	result, err := service.GetByID(actionID)
+	result, err := service.GetByID(ctx, actionID)
 
 	assert.NoError(t, err)
 	assert.Equal(t, action, result)
@@ -493,6 +528,8 @@ func TestCorporateActionService_GetByID(t *testing.T) {
 }
 
 func TestCorporateActionService_GetByID_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -505,7 +542,7 @@ func TestCorporateActionService_GetByID_NotFound(t *testing.T) {
 	expectedErr := fmt.Errorf("not found")
 	caRepo.On("FindByID", actionID).Return(nil, expectedErr)
 
-	result, err := service.GetByID(actionID)
+	result, err := service.GetByID(ctx, actionID)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -513,6 +550,8 @@ func TestCorporateActionService_GetByID_NotFound(t *testing.T) {
 }
 
 func TestCorporateActionService_GetBySymbol(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -537,7 +576,7 @@ func TestCorporateActionService_GetBySymbol(t *testing.T) {
 
 	caRepo.On("FindBySymbol", symbol).Return(actions, nil)
 
-	result, err := service.GetBySymbol(symbol)
+	result, err := service.GetBySymbol(ctx, symbol)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -546,6 +585,8 @@ func TestCorporateActionService_GetBySymbol(t *testing.T) {
 }
 
 func TestCorporateActionService_GetBySymbolAndDateRange(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -569,7 +610,7 @@ func TestCorporateActionService_GetBySymbolAndDateRange(t *testing.T) {
 
 	caRepo.On("FindBySymbolAndDateRange", symbol, startDate, endDate).Return(actions, nil)
 
-	result, err := service.GetBySymbolAndDateRange(symbol, startDate, endDate)
+	result, err := service.GetBySymbolAndDateRange(ctx, symbol, startDate, endDate)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
@@ -578,6 +619,8 @@ func TestCorporateActionService_GetBySymbolAndDateRange(t *testing.T) {
 }
 
 func TestCorporateActionService_GetUnapplied(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -603,7 +646,7 @@ func TestCorporateActionService_GetUnapplied(t *testing.T) {
 
 	caRepo.On("FindUnapplied").Return(actions, nil)
 
-	result, err := service.GetUnapplied()
+	result, err := service.GetUnapplied(ctx)
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -612,6 +655,8 @@ func TestCorporateActionService_GetUnapplied(t *testing.T) {
 }
 
 func TestCorporateActionService_MarkAsApplied(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -633,13 +678,15 @@ func TestCorporateActionService_MarkAsApplied(t *testing.T) {
 		return a.ID.String() == actionID && a.Applied == true
 	})).Return(nil)
 
-	err := service.MarkAsApplied(actionID)
+	err := service.MarkAsApplied(ctx, actionID)
 
 	assert.NoError(t, err)
 	caRepo.AssertExpectations(t)
 }
 
 func TestCorporateActionService_MarkAsApplied_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -652,13 +699,15 @@ func TestCorporateActionService_MarkAsApplied_NotFound(t *testing.T) {
 	expectedErr := fmt.Errorf("not found")
 	caRepo.On("FindByID", actionID).Return(nil, expectedErr)
 
-	err := service.MarkAsApplied(actionID)
+	err := service.MarkAsApplied(ctx, actionID)
 
 	assert.Error(t, err)
 	caRepo.AssertExpectations(t)
 }
 
 func TestCorporateActionService_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -670,13 +719,15 @@ func TestCorporateActionService_Delete(t *testing.T) {
 	actionID := uuid.New().String()
 	caRepo.On("Delete", actionID).Return(nil)
 
-	err := service.Delete(actionID)
+	err := service.Delete(ctx, actionID)
 
 	assert.NoError(t, err)
 	caRepo.AssertExpectations(t)
 }
 
 func TestCorporateActionService_Delete_Error(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -689,7 +740,7 @@ func TestCorporateActionService_Delete_Error(t *testing.T) {
 	expectedErr := fmt.Errorf("delete error")
 	caRepo.On("Delete", actionID).Return(expectedErr)
 
-	err := service.Delete(actionID)
+	err := service.Delete(ctx, actionID)
 
 	assert.Error(t, err)
 	assert.Equal(t, expectedErr, err)
@@ -697,6 +748,8 @@ func TestCorporateActionService_Delete_Error(t *testing.T) {
 }
 
 func TestApplySpinoff_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -747,16 +800,311 @@ func TestApplySpinoff_Success(t *testing.T) {
 	taxLotRepo.On("Update", mock.AnythingOfType("*models.TaxLot")).Return(nil)
 	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Return(nil)
 
-	err := service.ApplySpinoff(portfolioID, oldSymbol, newSymbol, userID.String(), ratio, date)
+	err := service.ApplySpinoff(ctx, portfolioID, oldSymbol, newSymbol, userID.String(), ratio, models.ExplicitSpinoffAllocation(decimal.RequireFromString("0.25")), date)
 
 	assert.NoError(t, err)
+	assert.True(t, parentHolding.CostBasis.Equal(decimal.NewFromInt(7500)))
+	assert.True(t, taxLot.CostBasis.Equal(decimal.NewFromInt(7500)))
+	holdingRepo.AssertCalled(t, "Create", mock.MatchedBy(func(h *models.Holding) bool {
+		return h.Symbol == newSymbol && h.Quantity.Equal(decimal.NewFromInt(50)) && h.CostBasis.Equal(decimal.NewFromInt(2500))
+	}))
+	transactionRepo.AssertCalled(t, "Create", mock.MatchedBy(func(tx *models.Transaction) bool {
+		return strings.HasSuffix(tx.Notes, "allocating 0.25 of cost basis (explicit)")
+	}))
 	portfolioRepo.AssertExpectations(t)
 	holdingRepo.AssertExpectations(t)
 	taxLotRepo.AssertExpectations(t)
 	transactionRepo.AssertExpectations(t)
 }
 
+func TestApplySpinoff_RoundsAndAllocatesAcrossLots(t *testing.T) {
+	ctx := context.Background()
+
+	portfolioRepo := new(MockPortfolioRepository)
+	holdingRepo := new(MockHoldingRepository)
+	taxLotRepo := new(MockTaxLotRepository)
+	transactionRepo := new(MockTransactionRepository)
+	caRepo := new(MockCorporateActionRepository)
+
+	rounding := models.RoundingPolicy{
+		Mode:                 models.RoundingModeHalfEven,
+		EquityQuantityPlaces: 0,
+		CryptoQuantityPlaces: 8,
+		AmountPlaces:         2,
+	}
+	service := NewCorporateActionServiceWithRounding(caRepo, portfolioRepo, transactionRepo, holdingRepo, taxLotRepo, rounding)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New()
+	portfolio := &models.Portfolio{ID: uuid.MustParse(portfolioID), UserID: userID, BaseCurrency: "USD"}
+	parentHolding := &models.Holding{
+		ID:          uuid.New(),
+		PortfolioID: portfolio.ID,
+		Symbol:      "AAPL",
+		AssetType:   models.AssetTypeEquity,
+		Quantity:    decimal.NewFromInt(3),
+		CostBasis:   decimal.NewFromInt(100),
+	}
+	lots := []*models.TaxLot{
+		{ID: uuid.New(), PortfolioID: portfolio.ID, Symbol: "AAPL", Quantity: decimal.NewFromInt(1), CostBasis: decimal.RequireFromString("33.33")},
+		{ID: uuid.New(), PortfolioID: portfolio.ID, Symbol: "AAPL", Quantity: decimal.NewFromInt(1), CostBasis: decimal.RequireFromString("33.33")},
+		{ID: uuid.New(), PortfolioID: portfolio.ID, Symbol: "AAPL", Quantity: decimal.NewFromInt(1), CostBasis: decimal.RequireFromString("33.34")},
+	}
+
+	var created []*models.TaxLot
+	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
+	holdingRepo.On("FindByPortfolioIDAndSymbol", portfolioID, "AAPL").Return(parentHolding, nil)
+	holdingRepo.On("FindByPortfolioIDAndSymbol", portfolioID, "SPIN").Return(nil, fmt.Errorf("not found"))
+	holdingRepo.On("Create", mock.AnythingOfType("*models.Holding")).Return(nil)
+	holdingRepo.On("Update", mock.AnythingOfType("*models.Holding")).Return(nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", portfolioID, "AAPL").Return(lots, nil)
+	taxLotRepo.On("Create", mock.AnythingOfType("*models.TaxLot")).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(0).(*models.TaxLot))
+	}).Return(nil)
+	taxLotRepo.On("Update", mock.AnythingOfType("*models.TaxLot")).Return(nil)
+	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Return(nil)
+
+	// 3 shares at 0.5 is 1.5 spinoff shares, rounded half to even to 2; a third of the
+	// parent's $100 is 33.333..., rounded to cents
+	err := service.ApplySpinoff(ctx, portfolioID, "AAPL", "SPIN", userID.String(),
+		decimal.RequireFromString("0.5"), models.ExplicitSpinoffAllocation(decimal.RequireFromString("0.3333333")), time.Now())
+	require.NoError(t, err)
+
+	assert.True(t, parentHolding.CostBasis.Equal(decimal.RequireFromString("66.67")))
+	require.Len(t, created, 3)
+	quantity, costBasis := decimal.Zero, decimal.Zero
+	for _, lot := range created {
+		assert.True(t, lot.Quantity.Equal(lot.Quantity.Round(0)), "lot quantity %s", lot.Quantity)
+		quantity = quantity.Add(lot.Quantity)
+		costBasis = costBasis.Add(lot.CostBasis)
+	}
+	assert.True(t, quantity.Equal(decimal.NewFromInt(2)))
+	assert.True(t, costBasis.Equal(decimal.RequireFromString("33.33")))
+}
+
+func TestApplySpinoff_InvalidAllocation(t *testing.T) {
+	ctx := context.Background()
+
+	portfolioRepo := new(MockPortfolioRepository)
+	service := NewCorporateActionService(new(MockCorporateActionRepository), portfolioRepo, new(MockTransactionRepository), new(MockHoldingRepository), new(MockTaxLotRepository))
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New()
+	portfolioRepo.On("FindByID", portfolioID).Return(&models.Portfolio{ID: uuid.MustParse(portfolioID), UserID: userID}, nil)
+
+	for _, allocation := range []string{"0", "1", "-0.1", "1.5"} {
+		err := service.ApplySpinoff(ctx, portfolioID, "AAPL", "SPIN", userID.String(),
+			decimal.RequireFromString("0.5"), models.ExplicitSpinoffAllocation(decimal.RequireFromString(allocation)), time.Now())
+		assert.ErrorIs(t, err, models.ErrInvalidCostBasisAllocation, allocation)
+	}
+}
+
+func TestApplyReturnOfCapital_ReducesCostBasisAndRealizesExcess(t *testing.T) {
+	ctx := context.Background()
+
+	portfolioRepo := new(MockPortfolioRepository)
+	holdingRepo := new(MockHoldingRepository)
+	taxLotRepo := new(MockTaxLotRepository)
+	transactionRepo := new(MockTransactionRepository)
+	service := NewCorporateActionService(new(MockCorporateActionRepository), portfolioRepo, transactionRepo, holdingRepo, taxLotRepo)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New()
+	portfolio := &models.Portfolio{ID: uuid.MustParse(portfolioID), UserID: userID, BaseCurrency: "USD"}
+	holding := &models.Holding{
+		ID:          uuid.New(),
+		PortfolioID: portfolio.ID,
+		Symbol:      "MLP",
+		Quantity:    decimal.NewFromInt(100),
+		CostBasis:   decimal.NewFromInt(1030),
+	}
+	lots := []*models.TaxLot{
+		{ID: uuid.New(), PortfolioID: portfolio.ID, Symbol: "MLP", PurchaseDate: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(50), CostBasis: decimal.NewFromInt(30)},
+		{ID: uuid.New(), PortfolioID: portfolio.ID, Symbol: "MLP", PurchaseDate: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(50), CostBasis: decimal.NewFromInt(1000)},
+	}
+
+	var transaction *models.Transaction
+	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
+	holdingRepo.On("FindByPortfolioIDAndSymbol", portfolioID, "MLP").Return(holding, nil)
+	holdingRepo.On("Update", holding).Return(nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", portfolioID, "MLP").Return(lots, nil)
+	taxLotRepo.On("Update", mock.AnythingOfType("*models.TaxLot")).Return(nil)
+	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Run(func(args mock.Arguments) {
+		transaction = args.Get(0).(*models.Transaction)
+	}).Return(nil)
+
+	// $1 a share: the old lot's $30 basis absorbs $30 of its $50, and the other $20 is a gain
+	date := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	err := service.ApplyReturnOfCapital(ctx, portfolioID, "MLP", userID.String(), decimal.NewFromInt(100), date)
+	require.NoError(t, err)
+
+	assert.True(t, lots[0].CostBasis.IsZero(), lots[0].CostBasis.String())
+	assert.True(t, lots[1].CostBasis.Equal(decimal.NewFromInt(950)), lots[1].CostBasis.String())
+	assert.True(t, holding.CostBasis.Equal(decimal.NewFromInt(950)), holding.CostBasis.String())
+	assert.True(t, holding.AvgCostPrice.Equal(decimal.RequireFromString("9.5")), holding.AvgCostPrice.String())
+	assert.True(t, holding.Quantity.Equal(decimal.NewFromInt(100)))
+
+	require.NotNil(t, transaction)
+	assert.Equal(t, models.TransactionTypeReturnOfCapital, transaction.Type)
+	assert.True(t, transaction.Quantity.Equal(decimal.NewFromInt(100)))
+	assert.Equal(t, "Return of capital: 100 reduced cost basis by 80, realizing 20 as capital gain", transaction.Notes)
+	taxLotRepo.AssertNumberOfCalls(t, "Update", 2)
+}
+
+func TestApplyReturnOfCapital_InvalidAmount(t *testing.T) {
+	ctx := context.Background()
+
+	portfolioRepo := new(MockPortfolioRepository)
+	service := NewCorporateActionService(new(MockCorporateActionRepository), portfolioRepo, new(MockTransactionRepository), new(MockHoldingRepository), new(MockTaxLotRepository))
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New()
+	portfolioRepo.On("FindByID", portfolioID).Return(&models.Portfolio{ID: uuid.MustParse(portfolioID), UserID: userID}, nil)
+
+	err := service.ApplyReturnOfCapital(ctx, portfolioID, "MLP", userID.String(), decimal.Zero, time.Now())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid return of capital amount")
+}
+
+func TestApplyStockDividend_Success(t *testing.T) {
+	ctx := context.Background()
+
+	portfolioRepo := new(MockPortfolioRepository)
+	holdingRepo := new(MockHoldingRepository)
+	taxLotRepo := new(MockTaxLotRepository)
+	transactionRepo := new(MockTransactionRepository)
+	service := NewCorporateActionService(new(MockCorporateActionRepository), portfolioRepo, transactionRepo, holdingRepo, taxLotRepo)
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New()
+	portfolio := &models.Portfolio{ID: uuid.MustParse(portfolioID), UserID: userID, BaseCurrency: "USD"}
+	holding := &models.Holding{
+		ID:          uuid.New(),
+		PortfolioID: portfolio.ID,
+		Symbol:      "AAPL",
+		Quantity:    decimal.NewFromInt(100),
+		CostBasis:   decimal.NewFromInt(1000),
+	}
+	purchaseDate := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	lots := []*models.TaxLot{
+		{ID: uuid.New(), PortfolioID: portfolio.ID, Symbol: "AAPL", PurchaseDate: purchaseDate, Quantity: decimal.NewFromInt(60), CostBasis: decimal.NewFromInt(600)},
+		{ID: uuid.New(), PortfolioID: portfolio.ID, Symbol: "AAPL", PurchaseDate: purchaseDate.AddDate(1, 0, 0), Quantity: decimal.NewFromInt(40), CostBasis: decimal.NewFromInt(400)},
+	}
+
+	var transaction *models.Transaction
+	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
+	holdingRepo.On("FindByPortfolioIDAndSymbol", portfolioID, "AAPL").Return(holding, nil)
+	holdingRepo.On("Update", holding).Return(nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", portfolioID, "AAPL").Return(lots, nil)
+	taxLotRepo.On("Update", mock.AnythingOfType("*models.TaxLot")).Return(nil)
+	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Run(func(args mock.Arguments) {
+		transaction = args.Get(0).(*models.Transaction)
+	}).Return(nil)
+
+	err := service.ApplyStockDividend(ctx, portfolioID, "AAPL", userID.String(), decimal.RequireFromString("0.05"), time.Now())
+	require.NoError(t, err)
+
+	assert.True(t, holding.Quantity.Equal(decimal.NewFromInt(105)))
+	assert.True(t, holding.CostBasis.Equal(decimal.NewFromInt(1000)))
+	assert.True(t, lots[0].Quantity.Equal(decimal.NewFromInt(63)), lots[0].Quantity.String())
+	assert.True(t, lots[0].CostBasis.Equal(decimal.NewFromInt(600)))
+	assert.Equal(t, purchaseDate, lots[0].PurchaseDate)
+	assert.True(t, lots[1].Quantity.Equal(decimal.NewFromInt(42)), lots[1].Quantity.String())
+
+	require.NotNil(t, transaction)
+	assert.Equal(t, models.TransactionTypeStockDividend, transaction.Type)
+	assert.True(t, transaction.Quantity.Equal(decimal.NewFromInt(5)))
+	assert.Nil(t, transaction.Price)
+}
+
+func TestApplyStockDividend_InvalidRate(t *testing.T) {
+	ctx := context.Background()
+
+	portfolioRepo := new(MockPortfolioRepository)
+	service := NewCorporateActionService(new(MockCorporateActionRepository), portfolioRepo, new(MockTransactionRepository), new(MockHoldingRepository), new(MockTaxLotRepository))
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New()
+	portfolioRepo.On("FindByID", portfolioID).Return(&models.Portfolio{ID: uuid.MustParse(portfolioID), UserID: userID}, nil)
+
+	err := service.ApplyStockDividend(ctx, portfolioID, "AAPL", userID.String(), decimal.NewFromInt(-1), time.Now())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stock dividend rate")
+}
+
+func TestApplyCapitalGainDistribution_RecordsLongTermDistribution(t *testing.T) {
+	ctx := context.Background()
+
+	portfolioRepo := new(MockPortfolioRepository)
+	holdingRepo := new(MockHoldingRepository)
+	transactionRepo := new(MockTransactionRepository)
+	service := NewCorporateActionService(new(MockCorporateActionRepository), portfolioRepo, transactionRepo, holdingRepo, new(MockTaxLotRepository))
+
+	portfolioID := uuid.New()
+	userID := uuid.New()
+	portfolio := &models.Portfolio{ID: portfolioID, UserID: userID, BaseCurrency: "USD"}
+	holding := &models.Holding{ID: uuid.New(), PortfolioID: portfolioID, Symbol: "VFIAX", Quantity: decimal.NewFromInt(100)}
+
+	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
+	holdingRepo.On("FindByPortfolioIDAndSymbol", portfolioID.String(), "VFIAX").Return(holding, nil)
+	var created *models.Transaction
+	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Run(func(args mock.Arguments) {
+		created = args.Get(0).(*models.Transaction)
+	}).Return(nil)
+
+	err := service.ApplyCapitalGainDistribution(ctx, portfolioID.String(), "VFIAX", userID.String(), decimal.RequireFromString("42.50"), time.Now())
+	require.NoError(t, err)
+
+	// Shares and cost basis are untouched; only the distribution is recorded
+	holdingRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	require.NotNil(t, created)
+	assert.Equal(t, models.TransactionTypeCapitalGainDistribution, created.Type)
+	assert.True(t, decimal.RequireFromString("42.50").Equal(created.Quantity))
+	assert.True(t, created.IsLongTermDistribution())
+	assert.NoError(t, created.Validate())
+}
+
+func TestApplyCapitalGainDistribution_InvalidAmount(t *testing.T) {
+	ctx := context.Background()
+
+	portfolioRepo := new(MockPortfolioRepository)
+	service := NewCorporateActionService(new(MockCorporateActionRepository), portfolioRepo, new(MockTransactionRepository), new(MockHoldingRepository), new(MockTaxLotRepository))
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New()
+	portfolioRepo.On("FindByID", portfolioID).Return(&models.Portfolio{ID: uuid.MustParse(portfolioID), UserID: userID}, nil)
+
+	err := service.ApplyCapitalGainDistribution(ctx, portfolioID, "VFIAX", userID.String(), decimal.Zero, time.Now())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid capital gain distribution amount")
+}
+
+func TestReturnCapital_Gains(t *testing.T) {
+	holding := &models.Holding{Symbol: "MLP", Quantity: decimal.NewFromInt(20), CostBasis: decimal.NewFromInt(15)}
+	lots := []*models.TaxLot{
+		{Symbol: "MLP", PurchaseDate: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(10), CostBasis: decimal.NewFromInt(5)},
+		{Symbol: "MLP", PurchaseDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(10), CostBasis: decimal.NewFromInt(10)},
+	}
+	date := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	reduction, gains := returnCapital(models.DefaultRoundingPolicy(), holding, lots, decimal.NewFromInt(30), date)
+
+	assert.True(t, reduction.Equal(decimal.NewFromInt(15)), reduction.String())
+	assert.True(t, holding.CostBasis.IsZero())
+	require.Len(t, gains, 2)
+	assert.True(t, gains[0].IsLongTerm)
+	assert.True(t, gains[0].Gain.Equal(decimal.NewFromInt(10)), gains[0].Gain.String())
+	assert.Equal(t, lots[0].PurchaseDate, gains[0].PurchaseDate)
+	assert.Equal(t, date, gains[0].SaleDate)
+	assert.False(t, gains[1].IsLongTerm)
+	assert.True(t, gains[1].Gain.Equal(decimal.NewFromInt(5)), gains[1].Gain.String())
+}
+
 func TestApplySpinoff_UnauthorizedAccess(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -777,7 +1125,7 @@ func TestApplySpinoff_UnauthorizedAccess(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 
-	err := service.ApplySpinoff(portfolioID, "AAPL", "AAPL-SPIN", userID.String(), decimal.NewFromFloat(0.5), time.Now())
+	err := service.ApplySpinoff(ctx, portfolioID, "AAPL", "AAPL-SPIN", userID.String(), decimal.NewFromFloat(0.5), models.DefaultSpinoffAllocation(), time.Now())
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrUnauthorizedAccess, err)
@@ -785,6 +1133,8 @@ func TestApplySpinoff_UnauthorizedAccess(t *testing.T) {
 }
 
 func TestApplySpinoff_InvalidRatio(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -805,7 +1155,7 @@ func TestApplySpinoff_InvalidRatio(t *testing.T) {
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 
 	// Test with zero ratio
-	err := service.ApplySpinoff(portfolioID, "AAPL", "AAPL-SPIN", userID.String(), decimal.Zero, time.Now())
+	err := service.ApplySpinoff(ctx, portfolioID, "AAPL", "AAPL-SPIN", userID.String(), decimal.Zero, models.DefaultSpinoffAllocation(), time.Now())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid spinoff ratio")
@@ -813,6 +1163,8 @@ func TestApplySpinoff_InvalidRatio(t *testing.T) {
 }
 
 func TestApplyTickerChange_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -872,7 +1224,7 @@ func TestApplyTickerChange_Success(t *testing.T) {
 	transactionRepo.On("Update", mock.AnythingOfType("*models.Transaction")).Return(nil)
 	transactionRepo.On("Create", mock.AnythingOfType("*models.Transaction")).Return(nil)
 
-	err := service.ApplyTickerChange(portfolioID, oldSymbol, newSymbol, userID.String(), date)
+	err := service.ApplyTickerChange(ctx, portfolioID, oldSymbol, newSymbol, userID.String(), date)
 
 	assert.NoError(t, err)
 	portfolioRepo.AssertExpectations(t)
@@ -882,6 +1234,8 @@ func TestApplyTickerChange_Success(t *testing.T) {
 }
 
 func TestApplyTickerChange_UnauthorizedAccess(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -902,7 +1256,7 @@ func TestApplyTickerChange_UnauthorizedAccess(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 
-	err := service.ApplyTickerChange(portfolioID, "FB", "META", userID.String(), time.Now())
+	err := service.ApplyTickerChange(ctx, portfolioID, "FB", "META", userID.String(), time.Now())
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrUnauthorizedAccess, err)
@@ -910,6 +1264,8 @@ func TestApplyTickerChange_UnauthorizedAccess(t *testing.T) {
 }
 
 func TestApplyTickerChange_NoHolding(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	holdingRepo := new(MockHoldingRepository)
 	taxLotRepo := new(MockTaxLotRepository)
@@ -930,10 +1286,45 @@ func TestApplyTickerChange_NoHolding(t *testing.T) {
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 	holdingRepo.On("FindByPortfolioIDAndSymbol", portfolioID, "FB").Return(nil, fmt.Errorf("not found"))
 
-	err := service.ApplyTickerChange(portfolioID, "FB", "META", userID.String(), time.Now())
+	err := service.ApplyTickerChange(ctx, portfolioID, "FB", "META", userID.String(), time.Now())
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "no holding found")
 	portfolioRepo.AssertExpectations(t)
 	holdingRepo.AssertExpectations(t)
 }
+
+func TestApplyMerger_FailedWriteRecordsNothing(t *testing.T) {
+	ctx := context.Background()
+
+	db, _, portfolio := setupRecalculationTest(t)
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	buy := createLedgerTransaction(t, db, portfolio, models.TransactionTypeBuy, "OLD", day, 100, 50)
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID: portfolio.ID, Symbol: "OLD",
+		Quantity: decimal.NewFromInt(100), CostBasis: decimal.NewFromInt(5000), AvgCostPrice: decimal.NewFromInt(50),
+	}).Error)
+	require.NoError(t, db.Create(&models.TaxLot{
+		PortfolioID: portfolio.ID, Symbol: "OLD", PurchaseDate: day,
+		Quantity: decimal.NewFromInt(100), CostBasis: decimal.NewFromInt(5000), TransactionID: buy.ID,
+	}).Error)
+
+	// Saving the MERGER transaction, the last write, fails
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("fail_merger_transactions", func(tx *gorm.DB) {
+		if txn, ok := tx.Statement.Dest.(*models.Transaction); ok && txn.Type == models.TransactionTypeMerger {
+			_ = tx.AddError(errors.New("disk full"))
+		}
+	}))
+
+	service := NewCorporateActionServiceWithDB(db, models.DefaultRoundingPolicy())
+	err := service.ApplyMerger(ctx, portfolio.ID.String(), "OLD", "NEW", portfolio.UserID.String(), decimal.NewFromFloat(1.5), day.AddDate(0, 1, 0))
+	require.Error(t, err)
+
+	// The holdings and lots written before it are rolled back with it
+	holdings, lots := loadLedgerState(t, db, portfolio)
+	require.Len(t, holdings, 1)
+	assert.Equal(t, "OLD", holdings[0].Symbol)
+	assert.True(t, holdings[0].Quantity.Equal(decimal.NewFromInt(100)))
+	require.Len(t, lots, 1)
+	assert.Equal(t, "OLD", lots[0].Symbol)
+}
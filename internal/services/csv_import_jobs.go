@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// importPollInterval is how often WatchImport checks the job queue for progress
+const importPollInterval = 500 * time.Millisecond
+
+// StartImportFromCSV checks that the portfolio and format are valid and queues the import,
+// returning its batch ID straight away. The import runs on the worker pool.
+func (s *csvImportService) StartImportFromCSV(ctx context.Context, portfolioID, userID string, req dto.CSVImportRequest) (uuid.UUID, error) {
+	if s.jobQueue == nil {
+		return uuid.Nil, models.ErrJobQueueUnavailable
+	}
+
+	// Verify portfolio exists and user has access
+	if err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return uuid.Nil, err
+	}
+
+	if _, ok := s.parsers[req.Format]; !ok {
+		return uuid.Nil, fmt.Errorf("unsupported import format: %s", req.Format)
+	}
+
+	job, err := s.jobQueue.Enqueue(ctx, userID, portfolioID, models.QueuedJobTypeCSVImport, req)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return job.ID, nil
+}
+
+// RunImport parses and imports the CSV data of a queued import as batch batchID, calling
+// onProgress with the progress so far after parsing and after every row. The progress
+// lists every error found so far. If ctx is cancelled, the import stops between rows and
+// returns models.ErrJobCheckpointed; run again with the last progress as checkpoint, it
+// picks up from the next row.
+func (s *csvImportService) RunImport(
+	ctx context.Context,
+	portfolioID string,
+	batchID uuid.UUID,
+	req dto.CSVImportRequest,
+	checkpoint *dto.ImportProgress,
+	onProgress func(dto.ImportProgress),
+) (*dto.ImportResult, error) {
+	portfolioUUID, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, models.ErrInvalidPortfolioID
+	}
+
+	transactions, parseErrors, err := s.parseCSV(req)
+	if err != nil {
+		return nil, err
+	}
+
+	bulk := csvBulkRequest(req, transactions)
+	result, from := newImportResult(batchID, bulk), 0
+	if checkpoint != nil && checkpoint.ProcessedRows > 0 && checkpoint.TotalRows == len(transactions) {
+		if err := s.resumeImport(ctx, portfolioID, batchID, result, checkpoint, len(parseErrors)); err != nil {
+			return nil, err
+		}
+		from = checkpoint.ProcessedRows
+	}
+
+	progress := dto.ImportProgress{
+		BatchID:       batchID,
+		Status:        dto.ImportStatusRunning,
+		TotalRows:     len(transactions),
+		ProcessedRows: from,
+	}
+	reportRows := func(processed int, result *dto.ImportResult) {
+		progress.ProcessedRows = processed
+		progress.SuccessCount = result.SuccessCount
+		progress.ErrorCount = len(parseErrors) + result.ErrorCount
+		progress.SkippedCount = result.SkippedCount
+		progress.Errors = append(parseErrors[:len(parseErrors):len(parseErrors)], result.Errors...)
+		onProgress(progress)
+	}
+	reportRows(from, result)
+
+	result, err = s.importTransactionsFrom(ctx, portfolioUUID, batchID, bulk, result, from, reportRows)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%w: import stopped after %d of %d rows: %w",
+				models.ErrJobCheckpointed, progress.ProcessedRows, progress.TotalRows, err)
+		}
+		return nil, err
+	}
+
+	addParseErrors(result, parseErrors)
+	return result, nil
+}
+
+// resumeImport restores into result the outcome of the rows an earlier run of the import
+// got through before it stopped at checkpoint. Its counts and errors come from the
+// checkpoint, which includes the parseErrors CSV parsing errors, and its transactions from
+// the batch; the validation results only cover the rows imported after resuming.
+func (s *csvImportService) resumeImport(
+	ctx context.Context,
+	portfolioID string,
+	batchID uuid.UUID,
+	result *dto.ImportResult,
+	checkpoint *dto.ImportProgress,
+	parseErrors int,
+) error {
+	result.SuccessCount = checkpoint.SuccessCount
+	result.SkippedCount = checkpoint.SkippedCount
+	result.ErrorCount = max(checkpoint.ErrorCount-parseErrors, 0)
+	if len(checkpoint.Errors) > parseErrors {
+		result.Errors = append(result.Errors, checkpoint.Errors[parseErrors:]...)
+	}
+
+	if result.ValidationOnly {
+		return nil
+	}
+	transactions, err := s.transactionRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return fmt.Errorf("failed to get transactions: %w", err)
+	}
+	for _, transaction := range transactions {
+		if transaction.ImportBatchID != nil && *transaction.ImportBatchID == batchID {
+			result.Transactions = append(result.Transactions, dto.ToTransactionResponse(transaction))
+		}
+	}
+	return nil
+}
+
+// WatchImport streams the progress of a queued import. The channel gets the current
+// progress straight away, then an event whenever it changes, and is closed after the
+// import finishes or when ctx is done. Each event lists the errors found since the one
+// before it.
+func (s *csvImportService) WatchImport(ctx context.Context, portfolioID, userID string, batchID uuid.UUID) (<-chan dto.ImportProgress, error) {
+	if s.jobQueue == nil {
+		return nil, models.ErrJobQueueUnavailable
+	}
+
+	job, err := s.findImportJob(ctx, portfolioID, userID, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan dto.ImportProgress)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(importPollInterval)
+		defer ticker.Stop()
+
+		sent := 0
+		for {
+			progress, err := importProgress(job, &sent)
+			if err != nil {
+				progress = dto.ImportProgress{BatchID: batchID, Status: dto.ImportStatusFailed, Error: err.Error()}
+			}
+			select {
+			case events <- progress:
+			case <-ctx.Done():
+				return
+			}
+			if progress.Status.IsFinished() {
+				return
+			}
+
+			// Wait for the job to change; heartbeats don't count
+			for previous := job; job.Status == previous.Status && job.UpdatedAt.Equal(previous.UpdatedAt); {
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+				if job, err = s.findImportJob(ctx, portfolioID, userID, batchID); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// findImportJob returns the queued CSV import with the batch ID if it is the user's import
+// into the portfolio
+func (s *csvImportService) findImportJob(ctx context.Context, portfolioID, userID string, batchID uuid.UUID) (*models.QueuedJob, error) {
+	job, err := s.jobQueue.Get(ctx, batchID.String(), userID)
+	if err != nil {
+		if errors.Is(err, models.ErrQueuedJobNotFound) {
+			return nil, models.ErrImportNotFound
+		}
+		return nil, err
+	}
+
+	if job.Type != models.QueuedJobTypeCSVImport || job.PortfolioID == nil || job.PortfolioID.String() != portfolioID {
+		return nil, models.ErrImportNotFound
+	}
+
+	return job, nil
+}
+
+// importProgress returns the progress of a queued import with the errors after the first
+// sent. sent is advanced past the returned errors. The stored progress lists the errors in
+// the order they were found, which the result doesn't, so they are always read from it.
+func importProgress(job *models.QueuedJob, sent *int) (dto.ImportProgress, error) {
+	var progress dto.ImportProgress
+	if _, err := job.DecodeProgress(&progress); err != nil {
+		return progress, err
+	}
+	progress.BatchID = job.ID
+
+	switch job.Status {
+	case models.QueuedJobStatusQueued:
+		progress.Status = dto.ImportStatusQueued
+	case models.QueuedJobStatusRunning:
+		progress.Status = dto.ImportStatusRunning
+	case models.QueuedJobStatusFailed:
+		progress.Status = dto.ImportStatusFailed
+		progress.Error = job.Error
+	case models.QueuedJobStatusSucceeded:
+		var result dto.ImportResult
+		if _, err := job.DecodeResult(&result); err != nil {
+			return progress, err
+		}
+		progress.Status = dto.ImportStatusCompleted
+		progress.SuccessCount = result.SuccessCount
+		progress.ErrorCount = result.ErrorCount
+		progress.SkippedCount = result.SkippedCount
+		progress.Result = &result
+	}
+
+	if *sent < len(progress.Errors) {
+		progress.Errors = progress.Errors[*sent:]
+		*sent += len(progress.Errors)
+	} else {
+		progress.Errors = nil
+	}
+	return progress, nil
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+const backgroundImportCSV = `Date,Symbol,Type,Quantity,Price,Fees
+2024-01-15,AAPL,buy,10,150.50,5.00
+2024-02-20,GOOGL,buy,5,120.00,2.50
+2024-03-10,MSFT,buy,0,300.00,4.00
+2024-04-05,AAPL,sell,3,160.00,2.00
+`
+
+func setupBackgroundImportTest(t *testing.T) (CSVImportService, repository.QueuedJobRepository, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.Holding{}, &models.QueuedJob{}))
+
+	user := &models.User{Email: "importer@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Brokerage", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	jobRepo := repository.NewQueuedJobRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	service := NewCSVImportServiceWithQueue(
+		repository.NewTransactionRepository(db),
+		portfolioRepo,
+		repository.NewHoldingRepository(db),
+		NewJobQueueService(jobRepo, portfolioRepo),
+	)
+	return service, jobRepo, portfolio
+}
+
+// runQueuedImport runs the next queued CSV import the way a worker would, storing every
+// progress report, and returns the reports
+func runQueuedImport(t *testing.T, service CSVImportService, jobRepo repository.QueuedJobRepository) []dto.ImportProgress {
+	ctx := context.Background()
+
+	job, err := jobRepo.ClaimNext(ctx, []models.QueuedJobType{models.QueuedJobTypeCSVImport})
+	require.NoError(t, err)
+	require.NotNil(t, job)
+
+	var reports []dto.ImportProgress
+	result, err := CSVImportJobHandler(service)(ctx, job, func(progress any) {
+		reports = append(reports, progress.(dto.ImportProgress))
+		data, err := json.Marshal(progress)
+		require.NoError(t, err)
+		require.NoError(t, jobRepo.UpdateProgress(ctx, job.ID, string(data)))
+	})
+
+	if err != nil {
+		require.NoError(t, jobRepo.Finish(ctx, job.ID, models.QueuedJobStatusFailed, "", err.Error()))
+		return reports
+	}
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	require.NoError(t, jobRepo.Finish(ctx, job.ID, models.QueuedJobStatusSucceeded, string(data), ""))
+	return reports
+}
+
+// watchUntilFinished collects every progress event of an import
+func watchUntilFinished(t *testing.T, service CSVImportService, portfolio *models.Portfolio, batchID uuid.UUID) []dto.ImportProgress {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := service.WatchImport(ctx, portfolio.ID.String(), portfolio.UserID.String(), batchID)
+	require.NoError(t, err)
+
+	var progress []dto.ImportProgress
+	for event := range events {
+		progress = append(progress, event)
+	}
+	require.NoError(t, ctx.Err(), "import did not finish")
+	return progress
+}
+
+func TestCSVImportService_StartImportFromCSV(t *testing.T) {
+	service, jobRepo, portfolio := setupBackgroundImportTest(t)
+	ctx := context.Background()
+
+	batchID, err := service.StartImportFromCSV(ctx, portfolio.ID.String(), portfolio.UserID.String(), dto.CSVImportRequest{
+		Format:      dto.ImportFormatGeneric,
+		CSVData:     backgroundImportCSV,
+		SkipInvalid: true,
+	})
+	require.NoError(t, err)
+
+	// The import waits in the queue until a worker picks it up
+	job, err := jobRepo.FindByID(ctx, batchID.String())
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobTypeCSVImport, job.Type)
+	assert.Equal(t, models.QueuedJobStatusQueued, job.Status)
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	events, err := service.WatchImport(watchCtx, portfolio.ID.String(), portfolio.UserID.String(), batchID)
+	require.NoError(t, err)
+	first := <-events
+	cancel()
+	assert.Equal(t, dto.ImportStatusQueued, first.Status)
+	assert.Equal(t, batchID, first.BatchID)
+
+	reports := runQueuedImport(t, service, jobRepo)
+	require.NotEmpty(t, reports)
+
+	// Rows processed only go up, and errors found earlier stay in later reports
+	for i, report := range reports {
+		assert.Equal(t, batchID, report.BatchID)
+		if i > 0 {
+			assert.GreaterOrEqual(t, report.ProcessedRows, reports[i-1].ProcessedRows)
+			assert.GreaterOrEqual(t, len(report.Errors), len(reports[i-1].Errors))
+		}
+	}
+	last := reports[len(reports)-1]
+	// The row with no quantity is rejected by the parser, so it isn't one of the rows to import
+	assert.Equal(t, 3, last.TotalRows)
+	assert.Equal(t, 3, last.ProcessedRows)
+
+	// A watcher of the finished import gets the final progress with every error straight away
+	progress := watchUntilFinished(t, service, portfolio, batchID)
+	require.Len(t, progress, 1)
+	final := progress[0]
+	assert.Equal(t, dto.ImportStatusCompleted, final.Status)
+	assert.Equal(t, batchID, final.BatchID)
+	assert.Equal(t, 3, final.SuccessCount)
+	assert.Equal(t, 1, final.ErrorCount)
+	require.Len(t, final.Errors, 1)
+	assert.Equal(t, 4, final.Errors[0].Line)
+	require.NotNil(t, final.Result)
+	assert.True(t, final.Result.Success)
+	assert.Equal(t, batchID, final.Result.BatchID)
+	assert.Len(t, final.Result.Transactions, 3)
+
+	batches, err := service.GetImportBatches(ctx, portfolio.ID.String(), portfolio.UserID.String())
+	require.NoError(t, err)
+	require.Len(t, batches.Batches, 1)
+	assert.Equal(t, batchID, batches.Batches[0].BatchID)
+}
+
+func TestCSVImportService_StartImportFromCSV_Failures(t *testing.T) {
+	service, jobRepo, portfolio := setupBackgroundImportTest(t)
+	ctx := context.Background()
+	portfolioID, userID := portfolio.ID.String(), portfolio.UserID.String()
+
+	t.Run("unreadable files fail in the background", func(t *testing.T) {
+		batchID, err := service.StartImportFromCSV(ctx, portfolioID, userID, dto.CSVImportRequest{
+			Format:  dto.ImportFormatGeneric,
+			CSVData: "only,a,header",
+		})
+		require.NoError(t, err)
+		runQueuedImport(t, service, jobRepo)
+
+		progress := watchUntilFinished(t, service, portfolio, batchID)
+		final := progress[len(progress)-1]
+		assert.Equal(t, dto.ImportStatusFailed, final.Status)
+		assert.Contains(t, final.Error, "failed to parse CSV")
+		assert.Nil(t, final.Result)
+	})
+
+	t.Run("requests are checked before queueing", func(t *testing.T) {
+		_, err := service.StartImportFromCSV(ctx, portfolioID, uuid.NewString(), dto.CSVImportRequest{
+			Format: dto.ImportFormatGeneric, CSVData: backgroundImportCSV,
+		})
+		assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+
+		_, err = service.StartImportFromCSV(ctx, portfolioID, userID, dto.CSVImportRequest{
+			Format: "UNKNOWN", CSVData: backgroundImportCSV,
+		})
+		assert.ErrorContains(t, err, "unsupported import format")
+	})
+
+	t.Run("only the importer can watch an import", func(t *testing.T) {
+		batchID, err := service.StartImportFromCSV(ctx, portfolioID, userID, dto.CSVImportRequest{
+			Format: dto.ImportFormatGeneric, CSVData: backgroundImportCSV, DryRun: true,
+		})
+		require.NoError(t, err)
+
+		_, err = service.WatchImport(ctx, portfolioID, uuid.NewString(), batchID)
+		assert.ErrorIs(t, err, models.ErrImportNotFound)
+		_, err = service.WatchImport(ctx, portfolioID, userID, uuid.New())
+		assert.ErrorIs(t, err, models.ErrImportNotFound)
+
+		runQueuedImport(t, service, jobRepo)
+		progress := watchUntilFinished(t, service, portfolio, batchID)
+		assert.True(t, progress[len(progress)-1].Result.ValidationOnly)
+	})
+
+	t.Run("imports need a job queue", func(t *testing.T) {
+		_, err := NewCSVImportService(nil, nil, nil).StartImportFromCSV(ctx, portfolioID, userID, dto.CSVImportRequest{
+			Format: dto.ImportFormatGeneric, CSVData: backgroundImportCSV,
+		})
+		assert.ErrorIs(t, err, models.ErrJobQueueUnavailable)
+	})
+}
+
+func TestCSVImportService_RunImport_ResumesFromCheckpoint(t *testing.T) {
+	service, jobRepo, portfolio := setupBackgroundImportTest(t)
+	ctx := context.Background()
+
+	batchID, err := service.StartImportFromCSV(ctx, portfolio.ID.String(), portfolio.UserID.String(), dto.CSVImportRequest{
+		Format:      dto.ImportFormatGeneric,
+		CSVData:     backgroundImportCSV,
+		SkipInvalid: true,
+	})
+	require.NoError(t, err)
+
+	// The worker is stopped once the first row is imported
+	job, err := jobRepo.ClaimNext(ctx, []models.QueuedJobType{models.QueuedJobTypeCSVImport})
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	_, err = CSVImportJobHandler(service)(runCtx, job, func(progress any) {
+		data, err := json.Marshal(progress)
+		require.NoError(t, err)
+		require.NoError(t, jobRepo.UpdateProgress(ctx, job.ID, string(data)))
+		if progress.(dto.ImportProgress).ProcessedRows == 1 {
+			stop()
+		}
+	})
+	require.ErrorIs(t, err, models.ErrJobCheckpointed)
+	assert.ErrorContains(t, err, "after 1 of 3 rows")
+	require.NoError(t, jobRepo.Requeue(ctx, job.ID))
+
+	batches, err := service.GetImportBatches(ctx, portfolio.ID.String(), portfolio.UserID.String())
+	require.NoError(t, err)
+	require.Len(t, batches.Batches, 1)
+	assert.Equal(t, 1, batches.Batches[0].TransactionCount)
+
+	// The next worker picks up from the second row
+	reports := runQueuedImport(t, service, jobRepo)
+	require.NotEmpty(t, reports)
+	assert.Equal(t, 1, reports[0].ProcessedRows)
+	assert.Equal(t, 1, reports[0].SuccessCount)
+
+	progress := watchUntilFinished(t, service, portfolio, batchID)
+	final := progress[len(progress)-1]
+	assert.Equal(t, dto.ImportStatusCompleted, final.Status)
+	assert.Equal(t, 3, final.SuccessCount)
+	assert.Equal(t, 1, final.ErrorCount)
+	require.NotNil(t, final.Result)
+	assert.Equal(t, 3, final.Result.SuccessCount)
+	assert.Len(t, final.Result.Errors, 1)
+	assert.Len(t, final.Result.Transactions, 3)
+
+	batches, err = service.GetImportBatches(ctx, portfolio.ID.String(), portfolio.UserID.String())
+	require.NoError(t, err)
+	assert.Equal(t, 3, batches.Batches[0].TransactionCount, "no row is imported twice")
+}
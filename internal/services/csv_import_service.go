@@ -2,15 +2,16 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
 	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/logger"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/repository"
 	"github.com/lenon/portfolios/internal/services/csv_parsers"
@@ -19,16 +20,27 @@ import (
 // CSVImportService defines the interface for CSV import operations
 type CSVImportService interface {
 	// ImportFromCSV imports transactions from CSV data
-	ImportFromCSV(portfolioID, userID string, req dto.CSVImportRequest) (*dto.ImportResult, error)
+	ImportFromCSV(ctx context.Context, portfolioID, userID string, req dto.CSVImportRequest) (*dto.ImportResult, error)
+
+	// StartImportFromCSV queues an import of transactions from CSV data and returns the
+	// batch ID of the import, whose progress can be followed with WatchImport
+	StartImportFromCSV(ctx context.Context, portfolioID, userID string, req dto.CSVImportRequest) (uuid.UUID, error)
+
+	// RunImport runs a queued import, reporting its progress as it goes. An import stopped
+	// by ctx resumes from checkpoint, the last progress it reported, when run again.
+	RunImport(ctx context.Context, portfolioID string, batchID uuid.UUID, req dto.CSVImportRequest, checkpoint *dto.ImportProgress, onProgress func(dto.ImportProgress)) (*dto.ImportResult, error)
+
+	// WatchImport streams the progress of a queued import until it finishes
+	WatchImport(ctx context.Context, portfolioID, userID string, batchID uuid.UUID) (<-chan dto.ImportProgress, error)
 
 	// ImportBulk imports a list of pre-parsed transactions
-	ImportBulk(portfolioID, userID string, req dto.BulkImportRequest) (*dto.ImportResult, error)
+	ImportBulk(ctx context.Context, portfolioID, userID string, req dto.BulkImportRequest) (*dto.ImportResult, error)
 
 	// GetImportBatches retrieves all import batches for a portfolio
-	GetImportBatches(portfolioID, userID string) (*dto.ImportBatchListResponse, error)
+	GetImportBatches(ctx context.Context, portfolioID, userID string) (*dto.ImportBatchListResponse, error)
 
 	// DeleteImportBatch deletes all transactions from a specific import batch
-	DeleteImportBatch(portfolioID, userID string, batchID uuid.UUID) error
+	DeleteImportBatch(ctx context.Context, portfolioID, userID string, batchID uuid.UUID) error
 }
 
 // csvImportService implements CSVImportService interface
@@ -37,13 +49,26 @@ type csvImportService struct {
 	portfolioRepo   repository.PortfolioRepository
 	holdingRepo     repository.HoldingRepository
 	parsers         map[dto.ImportFormat]csv_parsers.CSVParser
+	jobQueue        JobQueueService
 }
 
-// NewCSVImportService creates a new CSVImportService instance
+// NewCSVImportService creates a new CSVImportService instance. Imports can only be run
+// in the foreground; use NewCSVImportServiceWithQueue for background imports.
 func NewCSVImportService(
 	transactionRepo repository.TransactionRepository,
 	portfolioRepo repository.PortfolioRepository,
 	holdingRepo repository.HoldingRepository,
+) CSVImportService {
+	return NewCSVImportServiceWithQueue(transactionRepo, portfolioRepo, holdingRepo, nil)
+}
+
+// NewCSVImportServiceWithQueue creates a new CSVImportService instance that queues
+// background imports on jobQueue
+func NewCSVImportServiceWithQueue(
+	transactionRepo repository.TransactionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	jobQueue JobQueueService,
 ) CSVImportService {
 	// Initialize all parsers
 	parsers := map[dto.ImportFormat]csv_parsers.CSVParser{
@@ -61,20 +86,56 @@ func NewCSVImportService(
 		portfolioRepo:   portfolioRepo,
 		holdingRepo:     holdingRepo,
 		parsers:         parsers,
+		jobQueue:        jobQueue,
 	}
 }
 
 // ImportFromCSV imports transactions from CSV data
-func (s *csvImportService) ImportFromCSV(portfolioID, userID string, req dto.CSVImportRequest) (*dto.ImportResult, error) {
+func (s *csvImportService) ImportFromCSV(ctx context.Context, portfolioID, userID string, req dto.CSVImportRequest) (*dto.ImportResult, error) {
+	// Verify portfolio exists and user has access
+	if err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	transactions, parseErrors, err := s.parseCSV(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Import transactions
+	result, err := s.ImportBulk(ctx, portfolioID, userID, csvBulkRequest(req, transactions))
+	if err != nil {
+		return nil, err
+	}
+
+	addParseErrors(result, parseErrors)
+	return result, nil
+}
+
+// ImportBulk imports a list of pre-parsed transactions
+func (s *csvImportService) ImportBulk(ctx context.Context, portfolioID, userID string, req dto.BulkImportRequest) (*dto.ImportResult, error) {
 	// Verify portfolio exists and user has access
-	if err := s.verifyPortfolioAccess(portfolioID, userID); err != nil {
+	if err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
 		return nil, err
 	}
 
+	// Parse portfolio ID
+	portfolioUUID, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return nil, models.ErrInvalidPortfolioID
+	}
+
+	// Generate batch ID for this import
+	return s.importTransactions(ctx, portfolioUUID, uuid.New(), req, nil)
+}
+
+// parseCSV decodes and parses the CSV data of an import request. Rows the parser can't
+// read are returned as import errors rather than failing the whole file.
+func (s *csvImportService) parseCSV(req dto.CSVImportRequest) ([]dto.ImportTransactionRequest, []dto.ImportError, error) {
 	// Get appropriate parser for the format
 	parser, ok := s.parsers[req.Format]
 	if !ok {
-		return nil, fmt.Errorf("unsupported import format: %s", req.Format)
+		return nil, nil, fmt.Errorf("unsupported import format: %s", req.Format)
 	}
 
 	// Decode CSV data (support both raw text and base64)
@@ -95,51 +156,78 @@ func (s *csvImportService) ImportFromCSV(portfolioID, userID string, req dto.CSV
 	reader := bytes.NewReader(csvData)
 	transactions, parseErrors, err := parser.Parse(reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse CSV: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse CSV: %w", err)
 	}
+	return transactions, parseErrors, nil
+}
 
-	// Create bulk import request from parsed transactions
-	bulkReq := dto.BulkImportRequest{
+// csvBulkRequest creates the bulk import request for the parsed rows of a CSV import
+func csvBulkRequest(req dto.CSVImportRequest, transactions []dto.ImportTransactionRequest) dto.BulkImportRequest {
+	return dto.BulkImportRequest{
 		Format:       req.Format,
 		Transactions: transactions,
 		DryRun:       req.DryRun,
 		SkipInvalid:  req.SkipInvalid,
 		Notes:        req.Notes,
 	}
+}
 
-	// Import transactions
-	result, err := s.ImportBulk(portfolioID, userID, bulkReq)
-	if err != nil {
-		return nil, err
-	}
-
-	// Add parse errors to result
+// addParseErrors adds the rows the CSV parser couldn't read to an import result
+func addParseErrors(result *dto.ImportResult, parseErrors []dto.ImportError) {
 	if len(parseErrors) > 0 {
 		result.Errors = append(result.Errors, parseErrors...)
 		result.ErrorCount += len(parseErrors)
 	}
-
-	return result, nil
 }
 
-// ImportBulk imports a list of pre-parsed transactions
-func (s *csvImportService) ImportBulk(portfolioID, userID string, req dto.BulkImportRequest) (*dto.ImportResult, error) {
-	// Verify portfolio exists and user has access
-	if err := s.verifyPortfolioAccess(portfolioID, userID); err != nil {
-		return nil, err
+// importAndReplay imports transactions into a portfolio and, unless it was a dry run or none
+// were imported, rebuilds the portfolio's holdings and tax lots by replaying its ledger. Tax
+// lots are only created by the replay, so importers add transactions and leave the lots to it.
+// A failed replay keeps the imported transactions and is added to the result's errors; the
+// replay's report is nil when it didn't run or failed.
+func importAndReplay(
+	ctx context.Context,
+	importService CSVImportService,
+	recalculationService PortfolioRecalculationService,
+	portfolioID, userID string,
+	req dto.BulkImportRequest,
+) (*dto.ImportResult, *RecalculationReport, error) {
+	result, err := importService.ImportBulk(ctx, portfolioID, userID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req.DryRun || result.SuccessCount == 0 {
+		return result, nil, nil
 	}
 
-	// Parse portfolio ID
-	portfolioUUID, err := uuid.Parse(portfolioID)
+	report, err := recalculationService.Recalculate(ctx, portfolioID, userID, false)
 	if err != nil {
-		return nil, models.ErrInvalidPortfolioID
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+		result.Errors = append(result.Errors, dto.ImportError{
+			Message: fmt.Sprintf("failed to rebuild holdings and tax lots: %v", err),
+		})
+		result.ErrorCount++
+		return result, nil, nil
 	}
+	return result, report, nil
+}
 
-	// Generate batch ID for this import
-	batchID := uuid.New()
+// importTransactions imports transactions into a portfolio as batch batchID. onRow, if
+// set, is called with the result so far after each row.
+func (s *csvImportService) importTransactions(
+	ctx context.Context,
+	portfolioID, batchID uuid.UUID,
+	req dto.BulkImportRequest,
+	onRow func(processed int, result *dto.ImportResult),
+) (*dto.ImportResult, error) {
+	return s.importTransactionsFrom(ctx, portfolioID, batchID, req, newImportResult(batchID, req), 0, onRow)
+}
 
-	// Initialize result
-	result := &dto.ImportResult{
+// newImportResult returns the result of an import of req before any row is imported
+func newImportResult(batchID uuid.UUID, req dto.BulkImportRequest) *dto.ImportResult {
+	return &dto.ImportResult{
 		Success:           true,
 		BatchID:           batchID,
 		TotalRows:         len(req.Transactions),
@@ -151,108 +239,133 @@ func (s *csvImportService) ImportBulk(portfolioID, userID string, req dto.BulkIm
 		ValidationOnly:    req.DryRun,
 		ValidationResults: []dto.ImportValidationResult{},
 	}
+}
 
+// importTransactionsFrom imports the transactions of req from row from on, adding them to
+// result. A row that was started is finished even if ctx is cancelled, so an import stopped
+// early has imported exactly the rows before the one it stopped at.
+func (s *csvImportService) importTransactionsFrom(
+	ctx context.Context,
+	portfolioID, batchID uuid.UUID,
+	req dto.BulkImportRequest,
+	result *dto.ImportResult,
+	from int,
+	onRow func(processed int, result *dto.ImportResult),
+) (*dto.ImportResult, error) {
 	// Process each transaction
-	for i, txReq := range req.Transactions {
-		validationResult := dto.ImportValidationResult{
-			Index:  i,
-			Valid:  true,
-			Errors: []dto.ImportError{},
+	for i := from; i < len(req.Transactions); i++ {
+		// Stop importing once the caller has given up; rows already saved stay imported
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		// Validate transaction
-		if err := s.validateImportTransaction(&txReq); err != nil {
-			validationResult.Valid = false
-			validationResult.Errors = append(validationResult.Errors, dto.ImportError{
-				Line:    i + 1,
-				Message: err.Error(),
-				RawData: txReq.RawData,
-			})
-
-			result.ValidationResults = append(result.ValidationResults, validationResult)
-			result.Errors = append(result.Errors, validationResult.Errors...)
-			result.ErrorCount++
-
+		if !s.importRow(context.WithoutCancel(ctx), portfolioID, batchID, i, req.Transactions[i], req.DryRun, result) {
 			if !req.SkipInvalid {
 				result.Success = false
-				return result, nil
+			} else {
+				result.SkippedCount++
 			}
-			result.SkippedCount++
-			continue
 		}
-
-		// If dry run, just validate
-		if req.DryRun {
-			result.ValidationResults = append(result.ValidationResults, validationResult)
-			result.SuccessCount++
-			continue
+		if onRow != nil {
+			onRow(i+1, result)
 		}
-
-		// Create transaction model
-		transaction := &models.Transaction{
-			PortfolioID:   portfolioUUID,
-			Type:          txReq.Type,
-			Symbol:        txReq.Symbol,
-			Date:          txReq.Date,
-			Quantity:      txReq.Quantity,
-			Price:         txReq.Price,
-			Commission:    txReq.Commission,
-			Currency:      txReq.Currency,
-			Notes:         txReq.Notes,
-			ImportBatchID: &batchID,
+		if !result.Success {
+			return result, nil
 		}
+	}
 
-		// Save transaction
-		err = s.transactionRepo.Create(transaction)
-		if err != nil {
-			validationResult.Valid = false
-			validationResult.Errors = append(validationResult.Errors, dto.ImportError{
-				Line:    i + 1,
-				Message: fmt.Sprintf("failed to create transaction: %v", err),
-				RawData: txReq.RawData,
-			})
+	// If no transactions were successfully imported, mark as failed
+	if result.SuccessCount == 0 && result.TotalRows > 0 {
+		result.Success = false
+	}
 
-			result.ValidationResults = append(result.ValidationResults, validationResult)
-			result.Errors = append(result.Errors, validationResult.Errors...)
-			result.ErrorCount++
+	return result, nil
+}
 
-			if !req.SkipInvalid {
-				result.Success = false
-				return result, nil
-			}
-			result.SkippedCount++
-			continue
-		}
+// importRow validates and saves row i of an import, recording the outcome in result.
+// It returns false if the row is invalid or could not be saved.
+func (s *csvImportService) importRow(
+	ctx context.Context,
+	portfolioID, batchID uuid.UUID,
+	i int,
+	txReq dto.ImportTransactionRequest,
+	dryRun bool,
+	result *dto.ImportResult,
+) bool {
+	validationResult := dto.ImportValidationResult{
+		Index:  i,
+		Valid:  true,
+		Errors: []dto.ImportError{},
+	}
+	rejectRow := func(message string) bool {
+		validationResult.Valid = false
+		validationResult.Errors = append(validationResult.Errors, dto.ImportError{
+			Line:    i + 1,
+			Message: message,
+			RawData: txReq.RawData,
+		})
 
-		// Update holdings based on transaction
-		if err := s.updateHoldingsForTransaction(transaction); err != nil {
-			// Log error but don't fail the import
-			// Holdings can be recalculated later if needed
-			log.Printf("Warning: Failed to update holdings for transaction %s: %v", transaction.ID, err)
-		}
+		result.ValidationResults = append(result.ValidationResults, validationResult)
+		result.Errors = append(result.Errors, validationResult.Errors...)
+		result.ErrorCount++
+		return false
+	}
+
+	// Validate transaction
+	if err := s.validateImportTransaction(&txReq); err != nil {
+		return rejectRow(err.Error())
+	}
 
+	// If dry run, just validate
+	if dryRun {
 		result.ValidationResults = append(result.ValidationResults, validationResult)
-		result.Transactions = append(result.Transactions, dto.ToTransactionResponse(transaction))
 		result.SuccessCount++
+		return true
 	}
 
-	// If no transactions were successfully imported, mark as failed
-	if result.SuccessCount == 0 && result.TotalRows > 0 {
-		result.Success = false
+	// Create transaction model
+	transaction := &models.Transaction{
+		PortfolioID:   portfolioID,
+		Type:          txReq.Type,
+		Symbol:        txReq.Symbol,
+		Date:          txReq.Date,
+		Quantity:      txReq.Quantity,
+		Price:         txReq.Price,
+		Commission:    txReq.Commission,
+		Currency:      txReq.Currency,
+		Notes:         txReq.Notes,
+		ImportBatchID: &batchID,
 	}
 
-	return result, nil
+	// Save transaction
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+		return rejectRow(fmt.Sprintf("failed to create transaction: %v", err))
+	}
+
+	// Update holdings based on transaction, even if the caller has given up since it was saved
+	if err := s.updateHoldingsForTransaction(context.WithoutCancel(ctx), transaction); err != nil {
+		// Log error but don't fail the import
+		// Holdings can be recalculated later if needed
+		logger.FromContext(ctx).Warn().Err(err).
+			Str("transaction_id", transaction.ID.String()).
+			Msg("Failed to update holdings for imported transaction")
+	}
+
+	result.ValidationResults = append(result.ValidationResults, validationResult)
+	result.Transactions = append(result.Transactions, dto.ToTransactionResponse(transaction))
+	result.SuccessCount++
+	return true
 }
 
 // GetImportBatches retrieves all import batches for a portfolio
-func (s *csvImportService) GetImportBatches(portfolioID, userID string) (*dto.ImportBatchListResponse, error) {
+func (s *csvImportService) GetImportBatches(ctx context.Context, portfolioID, userID string) (*dto.ImportBatchListResponse, error) {
 	// Verify portfolio exists and user has access
-	if err := s.verifyPortfolioAccess(portfolioID, userID); err != nil {
+	if err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
 		return nil, err
 	}
 
 	// Get all transactions for this portfolio
-	transactions, err := s.transactionRepo.FindByPortfolioID(portfolioID)
+	transactions, err := s.transactionRepo.FindByPortfolioID(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -306,23 +419,26 @@ func (s *csvImportService) GetImportBatches(portfolioID, userID string) (*dto.Im
 }
 
 // DeleteImportBatch deletes all transactions from a specific import batch
-func (s *csvImportService) DeleteImportBatch(portfolioID, userID string, batchID uuid.UUID) error {
+func (s *csvImportService) DeleteImportBatch(ctx context.Context, portfolioID, userID string, batchID uuid.UUID) error {
 	// Verify portfolio exists and user has access
-	if err := s.verifyPortfolioAccess(portfolioID, userID); err != nil {
+	if err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
 		return err
 	}
 
 	// Get all transactions in this batch
-	transactions, err := s.transactionRepo.FindByPortfolioID(portfolioID)
+	transactions, err := s.transactionRepo.FindByPortfolioID(ctx, portfolioID)
 	if err != nil {
 		return fmt.Errorf("failed to get transactions: %w", err)
 	}
 
+	// Holdings must be recalculated once any transaction is deleted, even if the caller gives up
+	ctx = context.WithoutCancel(ctx)
+
 	// Delete transactions that belong to this batch
 	affectedSymbols := make(map[string]bool)
 	for _, tx := range transactions {
 		if tx.ImportBatchID != nil && *tx.ImportBatchID == batchID {
-			if err := s.transactionRepo.Delete(tx.ID.String()); err != nil {
+			if err := s.transactionRepo.Delete(ctx, tx.ID.String()); err != nil {
 				return fmt.Errorf("failed to delete transaction: %w", err)
 			}
 			affectedSymbols[tx.Symbol] = true
@@ -331,9 +447,12 @@ func (s *csvImportService) DeleteImportBatch(portfolioID, userID string, batchID
 
 	// Recalculate holdings for affected symbols
 	for symbol := range affectedSymbols {
-		if err := s.recalculateHoldingsForSymbol(portfolioID, symbol); err != nil {
+		if err := s.recalculateHoldingsForSymbol(ctx, portfolioID, symbol); err != nil {
 			// Log error but don't fail the deletion
-			log.Printf("Warning: Failed to recalculate holdings for symbol %s in portfolio %s: %v", symbol, portfolioID, err)
+			logger.FromContext(ctx).Warn().Err(err).
+				Str("portfolio_id", portfolioID).
+				Str("symbol", symbol).
+				Msg("Failed to recalculate holdings after deleting import batch")
 		}
 	}
 
@@ -341,13 +460,13 @@ func (s *csvImportService) DeleteImportBatch(portfolioID, userID string, batchID
 }
 
 // verifyPortfolioAccess verifies that the portfolio exists and the user has access
-func (s *csvImportService) verifyPortfolioAccess(portfolioID, userID string) error {
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+func (s *csvImportService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) error {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return err
 	}
 
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return models.ErrPortfolioNotFound // Don't leak existence of other users' portfolios
 	}
 
@@ -387,30 +506,34 @@ func (s *csvImportService) validateImportTransaction(tx *dto.ImportTransactionRe
 }
 
 // updateHoldingsForTransaction updates holdings based on a transaction
-func (s *csvImportService) updateHoldingsForTransaction(tx *models.Transaction) error {
+func (s *csvImportService) updateHoldingsForTransaction(ctx context.Context, tx *models.Transaction) error {
 	// Get current holding for this symbol
-	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(tx.PortfolioID.String(), tx.Symbol)
+	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, tx.PortfolioID.String(), tx.Symbol)
 	if err != nil && err != models.ErrHoldingNotFound {
 		return err
 	}
 
 	switch tx.Type {
-	case models.TransactionTypeBuy, models.TransactionTypeDividendReinvest:
+	case models.TransactionTypeBuy, models.TransactionTypeDividendReinvest, models.TransactionTypeOpeningBalance:
 		if holding == nil {
-			// Create new holding
+			// Create new holding. Opening balances of lots received at no cost have no price.
+			avgCostPrice := decimal.Zero
+			if tx.Price != nil {
+				avgCostPrice = *tx.Price
+			}
 			newHolding := &models.Holding{
 				PortfolioID:  tx.PortfolioID,
 				Symbol:       tx.Symbol,
 				Quantity:     tx.Quantity,
 				CostBasis:    tx.GetTotalCost(),
-				AvgCostPrice: *tx.Price,
+				AvgCostPrice: avgCostPrice,
 			}
-			err = s.holdingRepo.Create(newHolding)
+			err = s.holdingRepo.Create(ctx, newHolding)
 			return err
 		} else {
 			// Update existing holding using AddShares method
 			holding.AddShares(tx.Quantity, tx.GetTotalCost())
-			return s.holdingRepo.Update(holding)
+			return s.holdingRepo.Update(ctx, holding)
 		}
 
 	case models.TransactionTypeSell:
@@ -424,19 +547,19 @@ func (s *csvImportService) updateHoldingsForTransaction(tx *models.Transaction)
 		}
 
 		if holding.Quantity.IsZero() {
-			return s.holdingRepo.Delete(holding.ID.String())
+			return s.holdingRepo.Delete(ctx, holding.ID.String())
 		}
 
-		return s.holdingRepo.Update(holding)
+		return s.holdingRepo.Update(ctx, holding)
 	}
 
 	return nil
 }
 
 // recalculateHoldingsForSymbol recalculates holdings for a specific symbol
-func (s *csvImportService) recalculateHoldingsForSymbol(portfolioID, symbol string) error {
+func (s *csvImportService) recalculateHoldingsForSymbol(ctx context.Context, portfolioID, symbol string) error {
 	// Get all transactions for this symbol, ordered by date
-	transactions, err := s.transactionRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+	transactions, err := s.transactionRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
 	if err != nil {
 		return fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -447,7 +570,8 @@ func (s *csvImportService) recalculateHoldingsForSymbol(portfolioID, symbol stri
 
 	for _, tx := range transactions {
 		switch tx.Type {
-		case models.TransactionTypeBuy, models.TransactionTypeDividendReinvest:
+		case models.TransactionTypeBuy, models.TransactionTypeDividendReinvest, models.TransactionTypeRSUVest,
+			models.TransactionTypeESPPPurchase, models.TransactionTypeOptionExercise, models.TransactionTypeOpeningBalance:
 			quantity = quantity.Add(tx.Quantity)
 			costBasis = costBasis.Add(tx.GetTotalCost())
 		case models.TransactionTypeSell:
@@ -462,14 +586,14 @@ func (s *csvImportService) recalculateHoldingsForSymbol(portfolioID, symbol stri
 	}
 
 	// Update or delete holding
-	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
 	if err != nil && err != models.ErrHoldingNotFound {
 		return err
 	}
 
 	if quantity.IsZero() {
 		if holding != nil {
-			return s.holdingRepo.Delete(holding.ID.String())
+			return s.holdingRepo.Delete(ctx, holding.ID.String())
 		}
 		return nil
 	}
@@ -485,14 +609,14 @@ func (s *csvImportService) recalculateHoldingsForSymbol(portfolioID, symbol stri
 			CostBasis:    costBasis,
 			AvgCostPrice: avgCost,
 		}
-		err = s.holdingRepo.Create(newHolding)
+		err = s.holdingRepo.Create(ctx, newHolding)
 		return err
 	}
 
 	holding.Quantity = quantity
 	holding.CostBasis = costBasis
 	holding.AvgCostPrice = avgCost
-	return s.holdingRepo.Update(holding)
+	return s.holdingRepo.Update(ctx, holding)
 }
 
 // isBase64 checks if a string is base64 encoded
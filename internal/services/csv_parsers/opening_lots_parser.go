@@ -0,0 +1,148 @@
+package csv_parsers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// openingLotPriceDecimals is the precision of the per-share cost an opening lot is recorded
+// at, that of the transaction price column
+const openingLotPriceDecimals = 8
+
+// openingLotColumns lists the accepted names of each opening lots column
+var openingLotColumns = map[string][]string{
+	"symbol":        {"symbol", "ticker"},
+	"quantity":      {"quantity", "shares"},
+	"cost_basis":    {"cost_basis", "cost basis", "total cost", "cost"},
+	"purchase_date": {"purchase_date", "purchase date", "date acquired", "acquired", "date"},
+}
+
+// OpeningLotsParser handles lists of the lots held when a portfolio's recorded history starts.
+// Each row becomes an OPENING_BALANCE transaction priced at the lot's cost per share.
+// Expected format:
+// Symbol,Quantity,Cost Basis,Purchase Date,Currency,Notes
+type OpeningLotsParser struct {
+	BaseParser
+}
+
+// NewOpeningLotsParser creates a new opening lots CSV parser
+func NewOpeningLotsParser() CSVParser {
+	return &OpeningLotsParser{}
+}
+
+// GetFormat returns the format this parser handles
+func (p *OpeningLotsParser) GetFormat() dto.ImportFormat {
+	return dto.ImportFormatOpeningLots
+}
+
+// ValidateHeaders validates that the CSV has the expected headers
+func (p *OpeningLotsParser) ValidateHeaders(headers []string) error {
+	for _, required := range []string{"symbol", "quantity", "cost_basis", "purchase_date"} {
+		if p.GetColumnIndex(headers, openingLotColumns[required]...) == -1 {
+			return fmt.Errorf("missing required column: %s", required)
+		}
+	}
+
+	return nil
+}
+
+// Parse parses CSV data and returns an opening balance transaction for each lot
+func (p *OpeningLotsParser) Parse(data io.Reader) ([]dto.ImportTransactionRequest, []dto.ImportError, error) {
+	rows, err := p.ParseCSV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(rows) < 2 {
+		return nil, nil, fmt.Errorf("CSV must contain header row and at least one data row")
+	}
+
+	headers := rows[0]
+	if err := p.ValidateHeaders(headers); err != nil {
+		return nil, nil, err
+	}
+
+	// Get column indices
+	symbolIdx := p.GetColumnIndex(headers, openingLotColumns["symbol"]...)
+	quantityIdx := p.GetColumnIndex(headers, openingLotColumns["quantity"]...)
+	costBasisIdx := p.GetColumnIndex(headers, openingLotColumns["cost_basis"]...)
+	dateIdx := p.GetColumnIndex(headers, openingLotColumns["purchase_date"]...)
+	currencyIdx := p.GetColumnIndex(headers, "currency")
+	notesIdx := p.GetColumnIndex(headers, "notes", "description", "memo")
+
+	var transactions []dto.ImportTransactionRequest
+	var errors []dto.ImportError
+
+	// Parse each data row
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+		lineNum := i + 1
+
+		// Skip empty rows
+		if p.IsEmptyRow(row) {
+			continue
+		}
+
+		rawData := p.JoinRow(row)
+
+		symbol := p.NormalizeSymbol(p.GetColumnValue(row, symbolIdx))
+		if symbol == "" {
+			errors = append(errors, p.CreateImportError(lineNum, "symbol", "symbol is required", rawData))
+			continue
+		}
+
+		quantity, err := p.ParseDecimal(p.GetColumnValue(row, quantityIdx))
+		if err != nil {
+			errors = append(errors, p.CreateImportError(lineNum, "quantity", err.Error(), rawData))
+			continue
+		}
+
+		costBasis, err := p.ParseDecimal(p.GetColumnValue(row, costBasisIdx))
+		if err != nil {
+			errors = append(errors, p.CreateImportError(lineNum, "cost_basis", err.Error(), rawData))
+			continue
+		}
+		if costBasis.IsNegative() {
+			errors = append(errors, p.CreateImportError(lineNum, "cost_basis", "cost basis cannot be negative", rawData))
+			continue
+		}
+
+		date, err := p.ParseDate(p.GetColumnValue(row, dateIdx))
+		if err != nil {
+			errors = append(errors, p.CreateImportError(lineNum, "purchase_date", err.Error(), rawData))
+			continue
+		}
+
+		// Currency and notes are optional
+		currency := p.GetColumnValue(row, currencyIdx)
+		notes := p.GetColumnValue(row, notesIdx)
+
+		tx := dto.ImportTransactionRequest{
+			Type:     models.TransactionTypeOpeningBalance,
+			Symbol:   symbol,
+			Date:     date,
+			Quantity: quantity,
+			Currency: currency,
+			Notes:    notes,
+			RawData:  rawData,
+		}
+		if quantity.IsPositive() {
+			price := costBasis.DivRound(quantity, openingLotPriceDecimals)
+			tx.Price = &price
+		}
+
+		// Validate transaction
+		validationErrors := p.ValidateTransaction(&tx, lineNum)
+		if len(validationErrors) > 0 {
+			errors = append(errors, validationErrors...)
+			continue
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, errors, nil
+}
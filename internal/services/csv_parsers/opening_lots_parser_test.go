@@ -0,0 +1,65 @@
+package csv_parsers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestOpeningLotsParser_GetFormat(t *testing.T) {
+	parser := NewOpeningLotsParser()
+	assert.Equal(t, dto.ImportFormatOpeningLots, parser.GetFormat())
+}
+
+func TestOpeningLotsParser_ValidateHeaders(t *testing.T) {
+	parser := &OpeningLotsParser{}
+
+	assert.NoError(t, parser.ValidateHeaders([]string{"Symbol", "Quantity", "Cost Basis", "Purchase Date"}))
+	assert.NoError(t, parser.ValidateHeaders([]string{"ticker", "shares", "total cost", "date acquired", "currency"}))
+	assert.EqualError(t, parser.ValidateHeaders([]string{"Symbol", "Quantity", "Purchase Date"}), "missing required column: cost_basis")
+	assert.EqualError(t, parser.ValidateHeaders([]string{"Symbol", "Quantity", "Cost Basis"}), "missing required column: purchase_date")
+}
+
+func TestOpeningLotsParser_Parse(t *testing.T) {
+	parser := NewOpeningLotsParser()
+
+	csvData := `Symbol,Quantity,Cost Basis,Purchase Date,Currency,Notes
+aapl,100,"$5,000.00",03/01/2019,,Inherited
+VTI,3,500,2020-07-15,USD,
+GIFT,10,0,2021-01-04,,Gifted at no cost
+MSFT,0,100,2021-02-01,,
+IBM,5,-10,2021-02-01,,
+SPY,5,100,someday,,`
+
+	transactions, errors, err := parser.Parse(strings.NewReader(csvData))
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+
+	lot := transactions[0]
+	assert.Equal(t, models.TransactionTypeOpeningBalance, lot.Type)
+	assert.Equal(t, "AAPL", lot.Symbol)
+	assert.Equal(t, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), lot.Date)
+	assert.True(t, decimal.NewFromInt(100).Equal(lot.Quantity))
+	require.NotNil(t, lot.Price)
+	assert.True(t, decimal.NewFromInt(50).Equal(*lot.Price), lot.Price.String())
+	assert.Empty(t, lot.Currency)
+	assert.Equal(t, "Inherited", lot.Notes)
+
+	// Cost per share is rounded to the precision prices are stored at
+	assert.Equal(t, "166.66666667", transactions[1].Price.String())
+	assert.Equal(t, "USD", transactions[1].Currency)
+	assert.True(t, transactions[2].Price.IsZero())
+
+	require.Len(t, errors, 3)
+	assert.Equal(t, "quantity", errors[0].Field)
+	assert.Equal(t, 5, errors[0].Line)
+	assert.Equal(t, "cost_basis", errors[1].Field)
+	assert.Equal(t, "purchase_date", errors[2].Field)
+}
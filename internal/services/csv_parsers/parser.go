@@ -109,25 +109,50 @@ func (p *BaseParser) ParseTransactionType(typeStr string) (models.TransactionTyp
 
 	// Map common variations to our transaction types
 	typeMap := map[string]models.TransactionType{
-		"BUY":               models.TransactionTypeBuy,
-		"BOUGHT":            models.TransactionTypeBuy,
-		"PURCHASE":          models.TransactionTypeBuy,
-		"SELL":              models.TransactionTypeSell,
-		"SOLD":              models.TransactionTypeSell,
-		"SALE":              models.TransactionTypeSell,
-		"DIVIDEND":          models.TransactionTypeDividend,
-		"DIV":               models.TransactionTypeDividend,
-		"CASH DIVIDEND":     models.TransactionTypeDividend,
-		"SPLIT":             models.TransactionTypeSplit,
-		"STOCK SPLIT":       models.TransactionTypeSplit,
-		"MERGER":            models.TransactionTypeMerger,
-		"SPINOFF":           models.TransactionTypeSpinoff,
-		"SPIN-OFF":          models.TransactionTypeSpinoff,
-		"DIVIDEND REINVEST": models.TransactionTypeDividendReinvest,
-		"DRIP":              models.TransactionTypeDividendReinvest,
-		"REINVEST":          models.TransactionTypeDividendReinvest,
-		"TICKER CHANGE":     models.TransactionTypeTickerChange,
-		"SYMBOL CHANGE":     models.TransactionTypeTickerChange,
+		"BUY":                models.TransactionTypeBuy,
+		"BOUGHT":             models.TransactionTypeBuy,
+		"PURCHASE":           models.TransactionTypeBuy,
+		"SELL":               models.TransactionTypeSell,
+		"SOLD":               models.TransactionTypeSell,
+		"SALE":               models.TransactionTypeSell,
+		"DIVIDEND":           models.TransactionTypeDividend,
+		"DIV":                models.TransactionTypeDividend,
+		"CASH DIVIDEND":      models.TransactionTypeDividend,
+		"SPLIT":              models.TransactionTypeSplit,
+		"STOCK SPLIT":        models.TransactionTypeSplit,
+		"STOCK DIVIDEND":     models.TransactionTypeStockDividend,
+		"MERGER":             models.TransactionTypeMerger,
+		"SPINOFF":            models.TransactionTypeSpinoff,
+		"SPIN-OFF":           models.TransactionTypeSpinoff,
+		"DIVIDEND REINVEST":  models.TransactionTypeDividendReinvest,
+		"DRIP":               models.TransactionTypeDividendReinvest,
+		"REINVEST":           models.TransactionTypeDividendReinvest,
+		"TICKER CHANGE":      models.TransactionTypeTickerChange,
+		"SYMBOL CHANGE":      models.TransactionTypeTickerChange,
+		"RETURN OF CAPITAL":  models.TransactionTypeReturnOfCapital,
+		"ROC":                models.TransactionTypeReturnOfCapital,
+		"MANAGEMENT FEE":     models.TransactionTypeManagementFee,
+		"ADVISORY FEE":       models.TransactionTypeManagementFee,
+		"ADR FEE":            models.TransactionTypeADRFee,
+		"DEPOSITARY FEE":     models.TransactionTypeADRFee,
+		"FX FEE":             models.TransactionTypeFXFee,
+		"CURRENCY FEE":       models.TransactionTypeFXFee,
+		"LENDING INCOME":     models.TransactionTypeLendingIncome,
+		"SECURITIES LENDING": models.TransactionTypeLendingIncome,
+		"STOCK LOAN INCOME":  models.TransactionTypeLendingIncome,
+		"INTEREST":           models.TransactionTypeInterest,
+		"CREDIT INTEREST":    models.TransactionTypeInterest,
+		"BOND INTEREST":      models.TransactionTypeInterest,
+		"REBATE":             models.TransactionTypeRebate,
+		"FEE REBATE":         models.TransactionTypeRebate,
+		"DEPOSIT":            models.TransactionTypeDeposit,
+		"CONTRIBUTION":       models.TransactionTypeDeposit,
+		"WITHDRAWAL":         models.TransactionTypeWithdrawal,
+
+		// Funds paying out realized gains
+		"CAPITAL GAIN DISTRIBUTION":  models.TransactionTypeCapitalGainDistribution,
+		"CAPITAL GAINS DISTRIBUTION": models.TransactionTypeCapitalGainDistribution,
+		"CAP GAIN DIST":              models.TransactionTypeCapitalGainDistribution,
 	}
 
 	if txType, ok := typeMap[typeStr]; ok {
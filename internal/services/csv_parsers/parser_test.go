@@ -95,6 +95,17 @@ func TestBaseParser_ParseTransactionType(t *testing.T) {
 		{"buy lowercase", "buy", "BUY", false},
 		{"purchase", "PURCHASE", "BUY", false},
 		{"sale", "SALE", "SELL", false},
+		{"return of capital", "Return of Capital", "RETURN_OF_CAPITAL", false},
+		{"stock dividend", "STOCK DIVIDEND", "STOCK_DIVIDEND", false},
+		{"advisory fee", "Advisory Fee", "MANAGEMENT_FEE", false},
+		{"adr fee", "ADR FEE", "ADR_FEE", false},
+		{"fx fee", "FX Fee", "FX_FEE", false},
+		{"securities lending", "Securities Lending", "LENDING_INCOME", false},
+		{"credit interest", "Credit Interest", "INTEREST", false},
+		{"rebate", "REBATE", "REBATE", false},
+		{"contribution", "Contribution", "DEPOSIT", false},
+		{"withdrawal", "WITHDRAWAL", "WITHDRAWAL", false},
+		{"capital gain distribution", "Capital Gain Distribution", "CAPITAL_GAIN_DISTRIBUTION", false},
 		{"invalid", "INVALID_TYPE", "", true},
 	}
 
@@ -0,0 +1,112 @@
+package csv_parsers
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+// positionStatementColumns lists the accepted names of each position statement column
+var positionStatementColumns = map[string][]string{
+	"symbol":     {"symbol", "ticker"},
+	"quantity":   {"quantity", "shares"},
+	"cost_basis": {"cost_basis", "cost basis", "total cost", "cost"},
+}
+
+// PositionStatementParser handles statements of the positions an account holds, which are
+// reconciled with a portfolio's holdings rather than imported as transactions.
+// Expected format:
+// Symbol,Quantity,Cost Basis
+type PositionStatementParser struct {
+	BaseParser
+}
+
+// NewPositionStatementParser creates a new position statement CSV parser
+func NewPositionStatementParser() *PositionStatementParser {
+	return &PositionStatementParser{}
+}
+
+// ValidateHeaders validates that the CSV has the expected headers
+func (p *PositionStatementParser) ValidateHeaders(headers []string) error {
+	for _, required := range []string{"symbol", "quantity"} {
+		if p.GetColumnIndex(headers, positionStatementColumns[required]...) == -1 {
+			return fmt.Errorf("missing required column: %s", required)
+		}
+	}
+
+	return nil
+}
+
+// Parse parses CSV data and returns its positions. The cost basis column is optional, and
+// a row with an empty cost basis has none.
+func (p *PositionStatementParser) Parse(data io.Reader) ([]dto.StatementPosition, []dto.ImportError, error) {
+	rows, err := p.ParseCSV(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(rows) < 2 {
+		return nil, nil, fmt.Errorf("CSV must contain header row and at least one data row")
+	}
+
+	headers := rows[0]
+	if err := p.ValidateHeaders(headers); err != nil {
+		return nil, nil, err
+	}
+
+	// Get column indices
+	symbolIdx := p.GetColumnIndex(headers, positionStatementColumns["symbol"]...)
+	quantityIdx := p.GetColumnIndex(headers, positionStatementColumns["quantity"]...)
+	costBasisIdx := p.GetColumnIndex(headers, positionStatementColumns["cost_basis"]...)
+
+	var positions []dto.StatementPosition
+	var errors []dto.ImportError
+
+	// Parse each data row
+	for i := 1; i < len(rows); i++ {
+		row := rows[i]
+		lineNum := i + 1
+
+		// Skip empty rows
+		if p.IsEmptyRow(row) {
+			continue
+		}
+
+		rawData := p.JoinRow(row)
+
+		symbol := p.NormalizeSymbol(p.GetColumnValue(row, symbolIdx))
+		if symbol == "" {
+			errors = append(errors, p.CreateImportError(lineNum, "symbol", "symbol is required", rawData))
+			continue
+		}
+
+		quantity, err := p.ParseDecimal(p.GetColumnValue(row, quantityIdx))
+		if err != nil {
+			errors = append(errors, p.CreateImportError(lineNum, "quantity", err.Error(), rawData))
+			continue
+		}
+		if quantity.IsNegative() {
+			errors = append(errors, p.CreateImportError(lineNum, "quantity", "quantity cannot be negative", rawData))
+			continue
+		}
+
+		position := dto.StatementPosition{Symbol: symbol, Quantity: quantity}
+		if value := p.GetColumnValue(row, costBasisIdx); value != "" {
+			costBasis, err := p.ParseDecimal(value)
+			if err != nil {
+				errors = append(errors, p.CreateImportError(lineNum, "cost_basis", err.Error(), rawData))
+				continue
+			}
+			if costBasis.IsNegative() {
+				errors = append(errors, p.CreateImportError(lineNum, "cost_basis", "cost basis cannot be negative", rawData))
+				continue
+			}
+			position.CostBasis = &costBasis
+		}
+
+		positions = append(positions, position)
+	}
+
+	return positions, errors, nil
+}
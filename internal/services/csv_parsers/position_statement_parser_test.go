@@ -0,0 +1,51 @@
+package csv_parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPositionStatementParser_ValidateHeaders(t *testing.T) {
+	parser := NewPositionStatementParser()
+
+	assert.NoError(t, parser.ValidateHeaders([]string{"Symbol", "Quantity", "Cost Basis"}))
+	assert.NoError(t, parser.ValidateHeaders([]string{"ticker", "shares"}))
+	assert.EqualError(t, parser.ValidateHeaders([]string{"Symbol", "Cost Basis"}), "missing required column: quantity")
+}
+
+func TestPositionStatementParser_Parse(t *testing.T) {
+	parser := NewPositionStatementParser()
+
+	csvData := `Symbol,Quantity,Cost Basis
+aapl,100,"$5,000.00"
+VTI,12.5,
+,3,100
+MSFT,-1,100
+IBM,5,-10`
+
+	positions, errors, err := parser.Parse(strings.NewReader(csvData))
+	require.NoError(t, err)
+	require.Len(t, positions, 2)
+
+	assert.Equal(t, "AAPL", positions[0].Symbol)
+	assert.True(t, decimal.NewFromInt(100).Equal(positions[0].Quantity))
+	require.NotNil(t, positions[0].CostBasis)
+	assert.True(t, decimal.NewFromInt(5000).Equal(*positions[0].CostBasis))
+
+	// An empty cost basis is unknown rather than zero
+	assert.Equal(t, "12.5", positions[1].Quantity.String())
+	assert.Nil(t, positions[1].CostBasis)
+
+	require.Len(t, errors, 3)
+	assert.Equal(t, 4, errors[0].Line)
+	assert.Equal(t, "symbol", errors[0].Field)
+	assert.Equal(t, "quantity", errors[1].Field)
+	assert.Equal(t, "cost_basis", errors[2].Field)
+
+	_, _, err = parser.Parse(strings.NewReader("Symbol,Quantity\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+const (
+	// DashboardTopMovers is how many of the held symbols that moved the most are shown
+	DashboardTopMovers = 5
+	// DashboardActionWindow is how far ahead upcoming corporate actions are shown
+	DashboardActionWindow = 30 * 24 * time.Hour
+	// DashboardActionLimit is how many upcoming corporate actions are shown
+	DashboardActionLimit = 10
+	// DashboardNotificationLimit is how many unread notifications are shown
+	DashboardNotificationLimit = 5
+)
+
+// DashboardService defines the interface for the home screen summary
+type DashboardService interface {
+	GetDashboard(ctx context.Context, userID, currency string) (*dto.Dashboard, error)
+}
+
+// dashboardService implements DashboardService interface
+type dashboardService struct {
+	portfolioRepo       repository.PortfolioRepository
+	holdingRepo         repository.HoldingRepository
+	marketData          MarketDataService
+	calendarService     CorporateActionCalendarService
+	notificationService NotificationService
+	settings            UserSettingsService
+	now                 func() time.Time
+}
+
+// NewDashboardService creates a new DashboardService instance. marketData prices holdings and
+// converts currencies, and may be nil, in which case holdings are valued at cost basis and
+// nothing moves. calendarService and notificationService may be nil to leave the upcoming
+// corporate actions or the notifications out, and settings to default to USD.
+func NewDashboardService(
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	marketData MarketDataService,
+	calendarService CorporateActionCalendarService,
+	notificationService NotificationService,
+	settings UserSettingsService,
+) DashboardService {
+	return &dashboardService{
+		portfolioRepo:       portfolioRepo,
+		holdingRepo:         holdingRepo,
+		marketData:          marketData,
+		calendarService:     calendarService,
+		notificationService: notificationService,
+		settings:            settings,
+		now:                 func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// GetDashboard assembles the user's dashboard in currency, or in their display currency when
+// none is requested. The valuation, the corporate actions and the notifications are loaded
+// concurrently; a portfolio's corporate actions that can't be loaded are noted in the
+// warnings rather than failing the dashboard.
+func (s *dashboardService) GetDashboard(ctx context.Context, userID, currency string) (*dto.Dashboard, error) {
+	currency, err := displayCurrency(ctx, s.settings, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolios, err := s.portfolioRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve portfolios: %w", err)
+	}
+	sort.Slice(portfolios, func(i, j int) bool {
+		return portfolios[i].Name < portfolios[j].Name
+	})
+
+	dashboard := &dto.Dashboard{
+		Currency:        currency,
+		Portfolios:      make([]*dto.DashboardPortfolio, 0, len(portfolios)),
+		TopMovers:       []*dto.DashboardMover{},
+		UpcomingActions: []*dto.DashboardCorporateAction{},
+		Notifications:   []*dto.NotificationResponse{},
+		GeneratedAt:     s.now(),
+	}
+
+	// Each part fills in its own fields, so they don't need to be guarded
+	var actionWarnings []string
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return s.value(gctx, dashboard, portfolios)
+	})
+	g.Go(func() error {
+		var err error
+		dashboard.UpcomingActions, actionWarnings, err = s.upcomingActions(gctx, userID, portfolios)
+		return err
+	})
+	g.Go(func() error {
+		return s.notifications(gctx, dashboard, userID)
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	dashboard.Warnings = append(dashboard.Warnings, actionWarnings...)
+
+	return dashboard, nil
+}
+
+// value fills in the net worth, the portfolio cards and the top movers. Holdings without a
+// quote are valued at cost basis and don't move, and a portfolio whose base currency can't be
+// converted is listed but left out of the totals.
+func (s *dashboardService) value(ctx context.Context, dashboard *dto.Dashboard, portfolios []*models.Portfolio) error {
+	var convertedIDs []uuid.UUID
+	rates := make(map[string]decimal.Decimal)
+	portfolioRates := make(map[uuid.UUID]decimal.Decimal, len(portfolios))
+	for _, portfolio := range portfolios {
+		card := &dto.DashboardPortfolio{
+			PortfolioID:  portfolio.ID,
+			Name:         portfolio.Name,
+			BaseCurrency: portfolio.BaseCurrency,
+		}
+		dashboard.Portfolios = append(dashboard.Portfolios, card)
+
+		rate, err := cachedExchangeRate(ctx, s.marketData, rates, portfolio.BaseCurrency, dashboard.Currency)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			card.Error = err.Error()
+			continue
+		}
+		portfolioRates[portfolio.ID] = rate
+		convertedIDs = append(convertedIDs, portfolio.ID)
+	}
+
+	holdings, err := s.holdingRepo.FindByPortfolioIDs(ctx, convertedIDs)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve holdings: %w", err)
+	}
+	holdingsByPortfolio := make(map[uuid.UUID][]*models.Holding, len(convertedIDs))
+	symbols := make([]string, 0, len(holdings))
+	for _, holding := range holdings {
+		holdingsByPortfolio[holding.PortfolioID] = append(holdingsByPortfolio[holding.PortfolioID], holding)
+		if !slices.Contains(symbols, holding.Symbol) {
+			symbols = append(symbols, holding.Symbol)
+		}
+	}
+
+	quotes := s.quotes(ctx, symbols)
+
+	movers := make(map[string]*dto.DashboardMover)
+	var previousValue decimal.Decimal
+	for _, card := range dashboard.Portfolios {
+		if card.Error != "" {
+			continue
+		}
+		rate := portfolioRates[card.PortfolioID]
+
+		var cardPrevious decimal.Decimal
+		for _, holding := range holdingsByPortfolio[card.PortfolioID] {
+			if holding.Quantity.IsZero() {
+				continue
+			}
+			card.Positions++
+
+			costBasis := holding.CostBasis.Mul(rate)
+			card.CostBasis = card.CostBasis.Add(costBasis)
+
+			quote, ok := quotes[holding.Symbol]
+			if !ok || !quote.Price.IsPositive() {
+				card.Value = card.Value.Add(costBasis)
+				cardPrevious = cardPrevious.Add(costBasis)
+				continue
+			}
+			value := holding.MarketValue(quote.Price).Mul(rate)
+			previous := value
+			if quote.PreviousClose.IsPositive() {
+				previous = holding.MarketValue(quote.PreviousClose).Mul(rate)
+			}
+			card.Value = card.Value.Add(value)
+			cardPrevious = cardPrevious.Add(previous)
+
+			if previous.Equal(value) {
+				continue
+			}
+			mover, ok := movers[holding.Symbol]
+			if !ok {
+				mover = &dto.DashboardMover{
+					Symbol:        holding.Symbol,
+					AssetType:     holding.AssetType,
+					Price:         quote.Price,
+					Change:        quote.Price.Sub(quote.PreviousClose),
+					ChangePercent: percentOf(quote.Price.Sub(quote.PreviousClose), quote.PreviousClose),
+				}
+				movers[holding.Symbol] = mover
+			}
+			mover.DayChange = mover.DayChange.Add(value.Sub(previous))
+		}
+
+		card.UnrealizedGain = card.Value.Sub(card.CostBasis)
+		card.DayChange = card.Value.Sub(cardPrevious)
+		card.DayChangePct = percentOf(card.DayChange, cardPrevious)
+
+		dashboard.NetWorth = dashboard.NetWorth.Add(card.Value)
+		dashboard.TotalCostBasis = dashboard.TotalCostBasis.Add(card.CostBasis)
+		previousValue = previousValue.Add(cardPrevious)
+	}
+	dashboard.TotalUnrealizedGain = dashboard.NetWorth.Sub(dashboard.TotalCostBasis)
+	dashboard.DayChange = dashboard.NetWorth.Sub(previousValue)
+	dashboard.DayChangePct = percentOf(dashboard.DayChange, previousValue)
+
+	// The biggest moves either way first
+	for _, mover := range movers {
+		dashboard.TopMovers = append(dashboard.TopMovers, mover)
+	}
+	sort.Slice(dashboard.TopMovers, func(i, j int) bool {
+		a, b := dashboard.TopMovers[i].ChangePercent.Abs(), dashboard.TopMovers[j].ChangePercent.Abs()
+		if !a.Equal(b) {
+			return a.GreaterThan(b)
+		}
+		return dashboard.TopMovers[i].Symbol < dashboard.TopMovers[j].Symbol
+	})
+	if len(dashboard.TopMovers) > DashboardTopMovers {
+		dashboard.TopMovers = dashboard.TopMovers[:DashboardTopMovers]
+	}
+
+	return nil
+}
+
+// quotes returns the latest quotes of the symbols that have one
+func (s *dashboardService) quotes(ctx context.Context, symbols []string) map[string]*Quote {
+	if s.marketData == nil || len(symbols) == 0 {
+		return map[string]*Quote{}
+	}
+
+	quotes, err := s.marketData.GetQuotes(ctx, symbols)
+	if err != nil || quotes == nil {
+		return map[string]*Quote{}
+	}
+	return quotes
+}
+
+// upcomingActions lists the soonest corporate actions of the portfolios' holdings within
+// DashboardActionWindow, with warnings for the portfolios whose calendar couldn't be loaded
+func (s *dashboardService) upcomingActions(
+	ctx context.Context,
+	userID string,
+	portfolios []*models.Portfolio,
+) ([]*dto.DashboardCorporateAction, []string, error) {
+	actions := []*dto.DashboardCorporateAction{}
+	if s.calendarService == nil {
+		return actions, nil, nil
+	}
+
+	var warnings []string
+	from := s.now().Truncate(24 * time.Hour)
+	to := from.Add(DashboardActionWindow)
+	for _, portfolio := range portfolios {
+		calendar, err := s.calendarService.GetCalendar(ctx, portfolio.ID.String(), userID, from, to)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			warnings = append(warnings, fmt.Sprintf("%s: corporate actions unavailable: %v", portfolio.Name, err))
+			continue
+		}
+		for _, event := range calendar.Events {
+			if event.Applied {
+				continue
+			}
+			actions = append(actions, &dto.DashboardCorporateAction{
+				CorporateActionCalendarEvent: event,
+				PortfolioID:                  portfolio.ID.String(),
+				PortfolioName:                portfolio.Name,
+			})
+		}
+		for _, warning := range calendar.Warnings {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", portfolio.Name, warning))
+		}
+	}
+
+	sort.SliceStable(actions, func(i, j int) bool {
+		if !actions[i].Date.Equal(actions[j].Date) {
+			return actions[i].Date.Before(actions[j].Date)
+		}
+		return actions[i].Symbol < actions[j].Symbol
+	})
+	if len(actions) > DashboardActionLimit {
+		actions = actions[:DashboardActionLimit]
+	}
+
+	return actions, warnings, nil
+}
+
+// notifications fills in the latest unread notifications and how many there are
+func (s *dashboardService) notifications(ctx context.Context, dashboard *dto.Dashboard, userID string) error {
+	if s.notificationService == nil {
+		return nil
+	}
+
+	notifications, unread, err := s.notificationService.List(ctx, userID, true, DashboardNotificationLimit)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve notifications: %w", err)
+	}
+	for _, notification := range notifications {
+		dashboard.Notifications = append(dashboard.Notifications, dto.ToNotificationResponse(notification))
+	}
+	dashboard.UnreadCount = unread
+
+	return nil
+}
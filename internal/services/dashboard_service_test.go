@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupDashboardTest(t *testing.T, marketData MarketDataService) (*gorm.DB, *models.User, *dashboardService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// Every connection to an in-memory database opens a new one, and the dashboard's parts
+	// query concurrently
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.Holding{}, &models.CorporateAction{}, &models.Notification{},
+	))
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	calendar := NewCorporateActionCalendarService(repository.NewCorporateActionRepository(db), portfolioRepo, holdingRepo, nil)
+	notifications := NewNotificationService(repository.NewNotificationRepository(db))
+
+	service := NewDashboardService(portfolioRepo, holdingRepo, marketData, calendar, notifications, nil).(*dashboardService)
+	service.now = func() time.Time { return time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC) }
+	return db, user, service
+}
+
+func TestDashboardService_GetDashboard(t *testing.T) {
+	marketData := new(MockMarketDataService)
+	db, user, service := setupDashboardTest(t, marketData)
+	ctx := context.Background()
+
+	brokerage := createAggregationPortfolio(t, db, user.ID, "Brokerage", "USD", map[string]int64{"AAPL": 10, "MSFT": 2})
+	createAggregationPortfolio(t, db, user.ID, "Euro", "EUR", map[string]int64{"SAP": 10})
+
+	marketData.On("GetExchangeRate", "EUR", "USD").Return(decimal.NewFromInt(2), nil)
+	marketData.On("GetQuotes", mock.Anything).Return(map[string]*Quote{
+		"AAPL": {Symbol: "AAPL", Price: decimal.NewFromInt(200), PreviousClose: decimal.NewFromInt(190)},
+		"SAP":  {Symbol: "SAP", Price: decimal.NewFromInt(150), PreviousClose: decimal.NewFromInt(160)},
+	}, nil)
+
+	amount := decimal.NewFromFloat(0.25)
+	for _, action := range []*models.CorporateAction{
+		{Symbol: "AAPL", Type: models.CorporateActionTypeDividend, Date: time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC), Amount: &amount},
+		{Symbol: "MSFT", Type: models.CorporateActionTypeDividend, Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Amount: &amount},
+		// Outside the window
+		{Symbol: "AAPL", Type: models.CorporateActionTypeDividend, Date: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), Amount: &amount},
+	} {
+		require.NoError(t, db.Create(action).Error)
+	}
+
+	readAt := time.Now()
+	require.NoError(t, db.Create(&models.Notification{
+		UserID: user.ID, Type: models.NotificationTypeCorporateAction, Title: "Split", Message: "AAPL split",
+	}).Error)
+	require.NoError(t, db.Create(&models.Notification{
+		UserID: user.ID, Type: models.NotificationTypeCorporateAction, Title: "Old", Message: "Seen", ReadAt: &readAt,
+	}).Error)
+
+	dashboard, err := service.GetDashboard(ctx, user.ID.String(), "usd")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", dashboard.Currency)
+
+	// AAPL 10 x 200 + MSFT at cost 200 + SAP 10 x 150 x 2
+	assert.True(t, dashboard.NetWorth.Equal(decimal.NewFromInt(5200)), dashboard.NetWorth.String())
+	assert.True(t, dashboard.TotalCostBasis.Equal(decimal.NewFromInt(3200)), dashboard.TotalCostBasis.String())
+	// AAPL made 100 and SAP lost 200 since the previous close
+	assert.True(t, dashboard.DayChange.Equal(decimal.NewFromInt(-100)), dashboard.DayChange.String())
+	assert.True(t, dashboard.DayChangePct.Round(4).Equal(decimal.RequireFromString("-1.8868")), dashboard.DayChangePct.String())
+
+	require.Len(t, dashboard.Portfolios, 2)
+	assert.Equal(t, brokerage.ID, dashboard.Portfolios[0].PortfolioID)
+	assert.Equal(t, 2, dashboard.Portfolios[0].Positions)
+	assert.True(t, dashboard.Portfolios[0].DayChange.Equal(decimal.NewFromInt(100)))
+	assert.True(t, dashboard.Portfolios[1].Value.Equal(decimal.NewFromInt(3000)))
+
+	require.Len(t, dashboard.TopMovers, 2)
+	assert.Equal(t, "SAP", dashboard.TopMovers[0].Symbol)
+	assert.True(t, dashboard.TopMovers[0].DayChange.Equal(decimal.NewFromInt(-200)))
+	assert.Equal(t, "AAPL", dashboard.TopMovers[1].Symbol)
+
+	require.Len(t, dashboard.UpcomingActions, 2)
+	assert.Equal(t, "MSFT", dashboard.UpcomingActions[0].Symbol)
+	assert.Equal(t, "Brokerage", dashboard.UpcomingActions[0].PortfolioName)
+
+	require.Len(t, dashboard.Notifications, 1)
+	assert.Equal(t, "Split", dashboard.Notifications[0].Title)
+	assert.Equal(t, int64(1), dashboard.UnreadCount)
+	assert.Empty(t, dashboard.Warnings)
+}
+
+func TestDashboardService_GetDashboard_WithoutMarketData(t *testing.T) {
+	marketData := new(MockMarketDataService)
+	db, user, service := setupDashboardTest(t, marketData)
+
+	createAggregationPortfolio(t, db, user.ID, "Brokerage", "USD", map[string]int64{"AAPL": 10})
+	createAggregationPortfolio(t, db, user.ID, "Euro", "EUR", map[string]int64{"SAP": 10})
+
+	marketData.On("GetExchangeRate", "EUR", "USD").Return(decimal.Zero, errors.New("rate unavailable"))
+	marketData.On("GetQuotes", []string{"AAPL"}).Return(nil, errors.New("quota exceeded"))
+
+	dashboard, err := service.GetDashboard(context.Background(), user.ID.String(), "")
+	require.NoError(t, err)
+
+	// The euro portfolio is left out, and AAPL is valued at cost and doesn't move
+	assert.True(t, dashboard.NetWorth.Equal(decimal.NewFromInt(1000)), dashboard.NetWorth.String())
+	assert.True(t, dashboard.DayChange.IsZero())
+	assert.NotEmpty(t, dashboard.Portfolios[1].Error)
+	assert.Empty(t, dashboard.TopMovers)
+	assert.Empty(t, dashboard.UpcomingActions)
+	assert.Empty(t, dashboard.Notifications)
+}
+
+func TestDashboardService_GetDashboard_InvalidCurrency(t *testing.T) {
+	_, user, service := setupDashboardTest(t, nil)
+
+	_, err := service.GetDashboard(context.Background(), user.ID.String(), "DOLLARS")
+	assert.Equal(t, models.ErrInvalidCurrency, err)
+}
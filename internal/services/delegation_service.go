@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+const (
+	// DefaultDelegationAuditLimit is how many audit events are listed when no limit is given
+	DefaultDelegationAuditLimit = 100
+	// MaxDelegationAuditLimit is the most audit events listed at once
+	MaxDelegationAuditLimit = 1000
+)
+
+// IssuedDelegationRequest is the outcome of an advisor asking a client for access
+type IssuedDelegationRequest struct {
+	Delegation *models.Delegation
+	// Token consents to the request. It cannot be retrieved later.
+	Token string
+	// EmailSent is false if the consent email could not be delivered
+	EmailSent bool
+}
+
+// DelegationService defines the interface for advisor delegations: an advisor asks a client
+// for access to their portfolios, the client consents by choosing which portfolios to
+// delegate, and every request the advisor makes on the client's behalf is recorded
+type DelegationService interface {
+	RequestAccess(ctx context.Context, advisorID, clientEmail string, access models.DelegationAccess) (*IssuedDelegationRequest, error)
+	List(ctx context.Context, userID string) ([]*models.Delegation, error)
+	Consent(ctx context.Context, clientID, token string, portfolioIDs []string) (*models.Delegation, error)
+	SetPortfolios(ctx context.Context, clientID, delegationID string, portfolioIDs []string) (*models.Delegation, error)
+	Revoke(ctx context.Context, userID, delegationID string) error
+	ListAuditEvents(ctx context.Context, userID, delegationID string, limit int) ([]*models.DelegationAuditEvent, error)
+	ResolveDelegation(ctx context.Context, advisorID, clientID string) (*models.Delegation, string, error)
+	RecordAccess(ctx context.Context, event *models.DelegationAuditEvent) error
+}
+
+// delegationService implements DelegationService interface
+type delegationService struct {
+	db              *gorm.DB
+	emailService    EmailService
+	consentValidity time.Duration
+	now             func() time.Time
+}
+
+// NewDelegationService creates a new DelegationService instance. Clients can consent to a
+// request for consentValidity after it is made.
+func NewDelegationService(db *gorm.DB, emailService EmailService, consentValidity time.Duration) DelegationService {
+	return &delegationService{
+		db:              db,
+		emailService:    emailService,
+		consentValidity: consentValidity,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// RequestAccess asks whoever signs in with clientEmail for access to their portfolios. The
+// consent email is sent once the request is stored; a delivery failure is reported rather
+// than returned, and the token is returned so that it can be passed on some other way. An
+// advisor can only have one open request or delegation per client.
+func (s *delegationService) RequestAccess(
+	ctx context.Context,
+	advisorID, clientEmail string,
+	access models.DelegationAccess,
+) (*IssuedDelegationRequest, error) {
+	if !access.IsValid() {
+		return nil, models.ErrInvalidDelegationAccess
+	}
+	clientEmail = strings.ToLower(strings.TrimSpace(clientEmail))
+	if clientEmail == "" {
+		return nil, fmt.Errorf("client email is required")
+	}
+	advisor, err := repository.NewUserRepository(s.db).FindByID(advisorID)
+	if err != nil {
+		return nil, models.ErrUserNotFound
+	}
+	if strings.EqualFold(advisor.Email, clientEmail) {
+		return nil, models.ErrSelfDelegation
+	}
+
+	delegationRepo := repository.NewDelegationRepository(s.db)
+	now := s.now()
+	open, err := delegationRepo.ExistsOpen(ctx, advisor.ID, clientEmail, now)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, models.ErrDelegationExists
+	}
+
+	token, err := generateSecret(TokenLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate consent token: %w", err)
+	}
+	delegation := &models.Delegation{
+		AdvisorID:   advisor.ID,
+		ClientEmail: clientEmail,
+		Access:      access,
+		TokenHash:   hashResetToken(token),
+		ExpiresAt:   now.Add(s.consentValidity),
+	}
+	if err := delegationRepo.Create(ctx, delegation); err != nil {
+		return nil, err
+	}
+	delegation.Advisor = advisor
+
+	err = s.emailService.SendDelegationRequestEmail(clientEmail, advisor.Email, access, token, delegation.ExpiresAt)
+
+	return &IssuedDelegationRequest{Delegation: delegation, Token: token, EmailSent: err == nil}, nil
+}
+
+// List lists the delegations a user is the advisor or client of, and the requests for access
+// sent to their email, newest first
+func (s *delegationService) List(ctx context.Context, userID string) ([]*models.Delegation, error) {
+	user, err := repository.NewUserRepository(s.db).FindByID(userID)
+	if err != nil {
+		return nil, models.ErrUserNotFound
+	}
+
+	return repository.NewDelegationRepository(s.db).FindForUser(ctx, user.ID, strings.ToLower(user.Email))
+}
+
+// Consent approves a request for access with the token emailed to the client, delegating
+// the chosen portfolios. The request must be pending and addressed to the client's email,
+// and the portfolios must be the client's own rather than an organization's.
+func (s *delegationService) Consent(ctx context.Context, clientID, token string, portfolioIDs []string) (*models.Delegation, error) {
+	client, err := repository.NewUserRepository(s.db).FindByID(clientID)
+	if err != nil {
+		return nil, models.ErrUserNotFound
+	}
+
+	delegationRepo := repository.NewDelegationRepository(s.db)
+	delegation, err := delegationRepo.FindByTokenHash(ctx, hashResetToken(token))
+	if err != nil {
+		if errors.Is(err, models.ErrDelegationNotFound) {
+			return nil, models.ErrInvalidConsentToken
+		}
+		return nil, err
+	}
+	now := s.now()
+	if !delegation.IsPending(now) {
+		return nil, models.ErrInvalidConsentToken
+	}
+	if !strings.EqualFold(delegation.ClientEmail, client.Email) {
+		return nil, models.ErrConsentEmailMismatch
+	}
+	if delegation.AdvisorID == client.ID {
+		return nil, models.ErrSelfDelegation
+	}
+
+	ids, err := s.checkClientPortfolios(ctx, client.ID, portfolioIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txRepo := repository.NewDelegationRepository(tx)
+		delegation.ClientID = &client.ID
+		delegation.Status = models.DelegationStatusActive
+		delegation.ApprovedAt = &now
+		if err := txRepo.Save(ctx, delegation); err != nil {
+			return err
+		}
+		return txRepo.SetPortfolios(ctx, delegation.ID, ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return delegationRepo.FindByID(ctx, delegation.ID)
+}
+
+// SetPortfolios changes which portfolios a client delegates under an active delegation. Only
+// the client may change them.
+func (s *delegationService) SetPortfolios(ctx context.Context, clientID, delegationID string, portfolioIDs []string) (*models.Delegation, error) {
+	delegationRepo := repository.NewDelegationRepository(s.db)
+	delegation, uid, err := s.findForParty(ctx, delegationRepo, clientID, delegationID)
+	if err != nil {
+		return nil, err
+	}
+	if delegation.ClientID == nil || *delegation.ClientID != uid {
+		return nil, models.ErrNotDelegationClient
+	}
+	if delegation.Status != models.DelegationStatusActive {
+		return nil, models.ErrDelegationNotActive
+	}
+
+	ids, err := s.checkClientPortfolios(ctx, uid, portfolioIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := delegationRepo.SetPortfolios(ctx, delegation.ID, ids); err != nil {
+		return nil, err
+	}
+
+	return delegationRepo.FindByID(ctx, delegation.ID)
+}
+
+// Revoke ends a delegation, or declines a request for access. The client may revoke or
+// decline, and the advisor may withdraw. Revoking a delegation that has already ended
+// succeeds.
+func (s *delegationService) Revoke(ctx context.Context, userID, delegationID string) error {
+	delegationRepo := repository.NewDelegationRepository(s.db)
+	delegation, uid, err := s.findForParty(ctx, delegationRepo, userID, delegationID)
+	if err != nil {
+		return err
+	}
+	if delegation.Status == models.DelegationStatusRevoked {
+		return nil
+	}
+
+	now := s.now()
+	if delegation.ClientID == nil && delegation.AdvisorID != uid {
+		// The client declined before consenting
+		delegation.ClientID = &uid
+	}
+	delegation.Status = models.DelegationStatusRevoked
+	delegation.RevokedAt = &now
+	return delegationRepo.Save(ctx, delegation)
+}
+
+// ListAuditEvents lists the latest requests made under a delegation, newest first. The
+// advisor and client may list them.
+func (s *delegationService) ListAuditEvents(ctx context.Context, userID, delegationID string, limit int) ([]*models.DelegationAuditEvent, error) {
+	delegationRepo := repository.NewDelegationRepository(s.db)
+	delegation, _, err := s.findForParty(ctx, delegationRepo, userID, delegationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultDelegationAuditLimit
+	}
+	if limit > MaxDelegationAuditLimit {
+		limit = MaxDelegationAuditLimit
+	}
+	return delegationRepo.FindAuditEvents(ctx, delegation.ID, limit)
+}
+
+// ResolveDelegation returns the active delegation from a client to an advisor, with the
+// delegated portfolios, and the schema holding the client's portfolios. It returns
+// ErrDelegationNotFound if the client hasn't delegated access to the advisor.
+func (s *delegationService) ResolveDelegation(ctx context.Context, advisorID, clientID string) (*models.Delegation, string, error) {
+	advisor, err := uuid.Parse(advisorID)
+	if err != nil {
+		return nil, "", models.ErrDelegationNotFound
+	}
+	client, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, "", models.ErrDelegationNotFound
+	}
+
+	delegation, err := repository.NewDelegationRepository(s.db).FindActive(ctx, advisor, client)
+	if err != nil {
+		return nil, "", err
+	}
+	schema, err := repository.NewOrganizationRepository(s.db).FindSchemaNameByUserID(clientID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return delegation, schema, nil
+}
+
+// RecordAccess adds a request made under a delegation to its audit trail
+func (s *delegationService) RecordAccess(ctx context.Context, event *models.DelegationAuditEvent) error {
+	return repository.NewDelegationRepository(s.db).CreateAuditEvent(ctx, event)
+}
+
+// findForParty finds a delegation userID is the advisor or client of, or a pending request
+// sent to their email. Anyone else gets ErrDelegationNotFound.
+func (s *delegationService) findForParty(
+	ctx context.Context,
+	delegationRepo repository.DelegationRepository,
+	userID, delegationID string,
+) (*models.Delegation, uuid.UUID, error) {
+	user, err := repository.NewUserRepository(s.db).FindByID(userID)
+	if err != nil {
+		return nil, uuid.Nil, models.ErrUserNotFound
+	}
+	id, err := uuid.Parse(delegationID)
+	if err != nil {
+		return nil, uuid.Nil, models.ErrDelegationNotFound
+	}
+
+	delegation, err := delegationRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	if delegation.IsParty(user.ID) {
+		return delegation, user.ID, nil
+	}
+	if delegation.ClientID == nil && strings.EqualFold(delegation.ClientEmail, user.Email) {
+		return delegation, user.ID, nil
+	}
+
+	return nil, uuid.Nil, models.ErrDelegationNotFound
+}
+
+// checkClientPortfolios parses the portfolios a client delegates and checks that each is
+// one of the client's own. They are looked up in the client's schema, whichever
+// organization the request acts in.
+func (s *delegationService) checkClientPortfolios(ctx context.Context, clientID uuid.UUID, portfolioIDs []string) ([]uuid.UUID, error) {
+	if len(portfolioIDs) == 0 {
+		return nil, models.ErrDelegationPortfoliosRequired
+	}
+
+	schema, err := repository.NewOrganizationRepository(s.db).FindSchemaNameByUserID(clientID.String())
+	if err != nil {
+		return nil, err
+	}
+	ctx = database.WithSchema(ctx, schema)
+
+	portfolioRepo := repository.NewPortfolioRepository(s.db)
+	seen := make(map[uuid.UUID]bool, len(portfolioIDs))
+	ids := make([]uuid.UUID, 0, len(portfolioIDs))
+	for _, portfolioID := range portfolioIDs {
+		if _, err := uuid.Parse(portfolioID); err != nil {
+			return nil, models.ErrPortfolioNotFound
+		}
+		portfolio, err := portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return nil, err
+		}
+		if portfolio.UserID != clientID || portfolio.OrganizationID != nil {
+			return nil, models.ErrUnauthorizedAccess
+		}
+		if !seen[portfolio.ID] {
+			seen[portfolio.ID] = true
+			ids = append(ids, portfolio.ID)
+		}
+	}
+
+	return ids, nil
+}
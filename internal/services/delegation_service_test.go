@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupDelegationServiceTest(t *testing.T) (*gorm.DB, DelegationService, *mockEmailService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Organization{}, &models.Portfolio{},
+		&models.Delegation{}, &models.DelegationPortfolio{}, &models.DelegationAuditEvent{},
+	))
+
+	emailService := newMockEmailService()
+	return db, NewDelegationService(db, emailService, 24*time.Hour), emailService
+}
+
+func createDelegationTestPortfolio(t *testing.T, db *gorm.DB, userID string, organizationID *uuid.UUID) string {
+	portfolio := &models.Portfolio{
+		UserID:          uuid.MustParse(userID),
+		OrganizationID:  organizationID,
+		Name:            "Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+	return portfolio.ID.String()
+}
+
+func TestDelegationService_RequestAndConsent(t *testing.T) {
+	ctx := context.Background()
+	db, service, emailService := setupDelegationServiceTest(t)
+	advisorID := createOrganizationTestUser(t, db, "advisor@example.com")
+	clientID := createOrganizationTestUser(t, db, "client@example.com")
+	otherID := createOrganizationTestUser(t, db, "other@example.com")
+	portfolioID := createDelegationTestPortfolio(t, db, clientID, nil)
+	otherPortfolioID := createDelegationTestPortfolio(t, db, otherID, nil)
+
+	_, err := service.RequestAccess(ctx, advisorID, "advisor@example.com", models.DelegationAccessRead)
+	assert.Equal(t, models.ErrSelfDelegation, err)
+	_, err = service.RequestAccess(ctx, advisorID, "client@example.com", "admin")
+	assert.Equal(t, models.ErrInvalidDelegationAccess, err)
+
+	issued, err := service.RequestAccess(ctx, advisorID, " Client@Example.com", models.DelegationAccessRead)
+	require.NoError(t, err)
+	assert.True(t, issued.EmailSent)
+	assert.Equal(t, "client@example.com", issued.Delegation.ClientEmail)
+	assert.Equal(t, models.DelegationStatusPending, issued.Delegation.Status)
+	require.Len(t, emailService.sentEmails, 1)
+	assert.Equal(t, issued.Token, emailService.sentEmails[0].token)
+
+	// One open request per client
+	_, err = service.RequestAccess(ctx, advisorID, "client@example.com", models.DelegationAccessManage)
+	assert.Equal(t, models.ErrDelegationExists, err)
+
+	// The request is listed for the client by email before they consent
+	delegations, err := service.List(ctx, clientID)
+	require.NoError(t, err)
+	require.Len(t, delegations, 1)
+
+	// Only the addressed client may consent, and only with their own portfolios
+	_, err = service.Consent(ctx, otherID, issued.Token, []string{otherPortfolioID})
+	assert.Equal(t, models.ErrConsentEmailMismatch, err)
+	_, err = service.Consent(ctx, clientID, issued.Token, nil)
+	assert.Equal(t, models.ErrDelegationPortfoliosRequired, err)
+	_, err = service.Consent(ctx, clientID, issued.Token, []string{otherPortfolioID})
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+	_, err = service.Consent(ctx, clientID, "unknown", []string{portfolioID})
+	assert.Equal(t, models.ErrInvalidConsentToken, err)
+
+	delegation, err := service.Consent(ctx, clientID, issued.Token, []string{portfolioID, portfolioID})
+	require.NoError(t, err)
+	assert.Equal(t, models.DelegationStatusActive, delegation.Status)
+	require.NotNil(t, delegation.ClientID)
+	assert.Equal(t, clientID, delegation.ClientID.String())
+	assert.NotNil(t, delegation.ApprovedAt)
+	assert.Equal(t, []uuid.UUID{uuid.MustParse(portfolioID)}, delegation.PortfolioIDs())
+
+	// A token works once
+	_, err = service.Consent(ctx, clientID, issued.Token, []string{portfolioID})
+	assert.Equal(t, models.ErrInvalidConsentToken, err)
+
+	resolved, schema, err := service.ResolveDelegation(ctx, advisorID, clientID)
+	require.NoError(t, err)
+	assert.Equal(t, delegation.ID, resolved.ID)
+	assert.Empty(t, schema)
+	_, _, err = service.ResolveDelegation(ctx, otherID, clientID)
+	assert.Equal(t, models.ErrDelegationNotFound, err)
+}
+
+func TestDelegationService_Consent_Expired(t *testing.T) {
+	ctx := context.Background()
+	db, service, _ := setupDelegationServiceTest(t)
+	advisorID := createOrganizationTestUser(t, db, "advisor@example.com")
+	clientID := createOrganizationTestUser(t, db, "client@example.com")
+	portfolioID := createDelegationTestPortfolio(t, db, clientID, nil)
+
+	issued, err := service.RequestAccess(ctx, advisorID, "client@example.com", models.DelegationAccessManage)
+	require.NoError(t, err)
+
+	service.(*delegationService).now = func() time.Time { return time.Now().UTC().Add(48 * time.Hour) }
+	_, err = service.Consent(ctx, clientID, issued.Token, []string{portfolioID})
+	assert.Equal(t, models.ErrInvalidConsentToken, err)
+
+	// An expired request no longer blocks a new one
+	_, err = service.RequestAccess(ctx, advisorID, "client@example.com", models.DelegationAccessManage)
+	require.NoError(t, err)
+}
+
+func TestDelegationService_SetPortfoliosAndRevoke(t *testing.T) {
+	ctx := context.Background()
+	db, service, _ := setupDelegationServiceTest(t)
+	advisorID := createOrganizationTestUser(t, db, "advisor@example.com")
+	clientID := createOrganizationTestUser(t, db, "client@example.com")
+	outsiderID := createOrganizationTestUser(t, db, "outsider@example.com")
+	first := createDelegationTestPortfolio(t, db, clientID, nil)
+	second := createDelegationTestPortfolio(t, db, clientID, nil)
+	organizationID := uuid.New()
+	shared := createDelegationTestPortfolio(t, db, clientID, &organizationID)
+
+	issued, err := service.RequestAccess(ctx, advisorID, "client@example.com", models.DelegationAccessManage)
+	require.NoError(t, err)
+	delegationID := issued.Delegation.ID.String()
+
+	// Only active delegations can change portfolios
+	_, err = service.SetPortfolios(ctx, clientID, delegationID, []string{first})
+	assert.Equal(t, models.ErrNotDelegationClient, err)
+
+	_, err = service.Consent(ctx, clientID, issued.Token, []string{first})
+	require.NoError(t, err)
+
+	_, err = service.SetPortfolios(ctx, advisorID, delegationID, []string{first, second})
+	assert.Equal(t, models.ErrNotDelegationClient, err)
+	_, err = service.SetPortfolios(ctx, outsiderID, delegationID, []string{first})
+	assert.Equal(t, models.ErrDelegationNotFound, err)
+	// Organization portfolios belong to the organization, not the client
+	_, err = service.SetPortfolios(ctx, clientID, delegationID, []string{shared})
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+	_, err = service.SetPortfolios(ctx, clientID, delegationID, []string{"not-a-uuid"})
+	assert.Equal(t, models.ErrPortfolioNotFound, err)
+
+	delegation, err := service.SetPortfolios(ctx, clientID, delegationID, []string{second})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{uuid.MustParse(second)}, delegation.PortfolioIDs())
+
+	require.NoError(t, service.RecordAccess(ctx, &models.DelegationAuditEvent{
+		DelegationID: delegation.ID,
+		AdvisorID:    delegation.AdvisorID,
+		ClientID:     *delegation.ClientID,
+		Method:       "GET",
+		Route:        "/api/v1/portfolios",
+		Path:         "/api/v1/portfolios",
+		Status:       200,
+	}))
+	events, err := service.ListAuditEvents(ctx, advisorID, delegationID, 0)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+	_, err = service.ListAuditEvents(ctx, outsiderID, delegationID, 0)
+	assert.Equal(t, models.ErrDelegationNotFound, err)
+
+	require.NoError(t, service.Revoke(ctx, clientID, delegationID))
+	require.NoError(t, service.Revoke(ctx, advisorID, delegationID))
+	_, _, err = service.ResolveDelegation(ctx, advisorID, clientID)
+	assert.Equal(t, models.ErrDelegationNotFound, err)
+	_, err = service.SetPortfolios(ctx, clientID, delegationID, []string{first})
+	assert.Equal(t, models.ErrDelegationNotActive, err)
+}
+
+func TestDelegationService_Revoke_DeclinesPendingRequest(t *testing.T) {
+	ctx := context.Background()
+	db, service, _ := setupDelegationServiceTest(t)
+	advisorID := createOrganizationTestUser(t, db, "advisor@example.com")
+	clientID := createOrganizationTestUser(t, db, "client@example.com")
+	portfolioID := createDelegationTestPortfolio(t, db, clientID, nil)
+
+	issued, err := service.RequestAccess(ctx, advisorID, "client@example.com", models.DelegationAccessRead)
+	require.NoError(t, err)
+
+	require.NoError(t, service.Revoke(ctx, clientID, issued.Delegation.ID.String()))
+	_, err = service.Consent(ctx, clientID, issued.Token, []string{portfolioID})
+	assert.Equal(t, models.ErrInvalidConsentToken, err)
+
+	delegations, err := service.List(ctx, clientID)
+	require.NoError(t, err)
+	require.Len(t, delegations, 1)
+	assert.Equal(t, models.DelegationStatusRevoked, delegations[0].Status)
+	require.NotNil(t, delegations[0].ClientID)
+	assert.Equal(t, clientID, delegations[0].ClientID.String())
+}
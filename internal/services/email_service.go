@@ -1,33 +1,52 @@
 package services
 
 import (
-	"crypto/tls"
 	"fmt"
-	"net/smtp"
+	"time"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/emails"
+	"github.com/lenon/portfolios/internal/i18n"
+	"github.com/lenon/portfolios/internal/models"
 )
 
 // EmailService defines the interface for email operations
 type EmailService interface {
 	SendPasswordResetEmail(to, resetToken string) error
+	SendRebalancePlanReminderEmail(to, planName string, pendingTrades int, lastActivity time.Time) error
+	SendInviteEmail(to, organizationName, inviteToken string, expiresAt time.Time) error
+	SendOrganizationInviteEmail(to, organizationName, inviterEmail string, role models.OrganizationRole, inviteToken string, expiresAt time.Time) error
+	SendDelegationRequestEmail(to, advisorEmail string, access models.DelegationAccess, consentToken string, expiresAt time.Time) error
+	SendPerformanceDigestEmail(to string, digest *dto.PerformanceDigest, unsubscribeToken string) error
 }
 
 // emailService implements EmailService interface
 type emailService struct {
-	host     string
-	port     int
-	username string
-	password string
-	from     string
+	transport emails.Transport
+	from      string
+	templates *emails.Templates
 }
 
-// NewEmailService creates a new EmailService instance
+// NewEmailService creates a new EmailService instance that sends the built-in email
+// templates through an SMTP server
 func NewEmailService(host string, port int, username, password, from string) EmailService {
+	return NewEmailServiceWithTemplates(host, port, username, password, from, emails.MustLoadTemplates())
+}
+
+// NewEmailServiceWithTemplates creates a new EmailService instance that sends emails rendered
+// from templates, such as those loaded with overrides from the runtime home directory,
+// through an SMTP server
+func NewEmailServiceWithTemplates(host string, port int, username, password, from string, templates *emails.Templates) EmailService {
+	return NewEmailServiceWithTransport(emails.NewSMTPTransport(host, port, username, password), from, templates)
+}
+
+// NewEmailServiceWithTransport creates a new EmailService instance that sends emails rendered
+// from templates through any transport, such as SES or the log
+func NewEmailServiceWithTransport(transport emails.Transport, from string, templates *emails.Templates) EmailService {
 	return &emailService{
-		host:     host,
-		port:     port,
-		username: username,
-		password: password,
-		from:     from,
+		transport: transport,
+		from:      from,
+		templates: templates,
 	}
 }
 
@@ -40,103 +59,163 @@ func (s *emailService) SendPasswordResetEmail(to, resetToken string) error {
 		return fmt.Errorf("reset token cannot be empty")
 	}
 
-	// Create reset link
-	resetLink := fmt.Sprintf("https://app.example.com/reset-password?token=%s", resetToken)
-
-	// Create email subject and body
-	subject := "Password Reset Request"
-	body := fmt.Sprintf(`Hello,
-
-You have requested to reset your password. Please click the link below to reset your password:
-
-%s
-
-This link will expire in 1 hour.
-
-If you did not request a password reset, please ignore this email.
+	return s.sendTemplate(to, emails.PasswordReset, emails.PasswordResetData{
+		ResetLink: fmt.Sprintf("https://app.example.com/reset-password?token=%s", resetToken),
+	}, nil)
+}
 
-Best regards,
-The Portfolios Team`, resetLink)
+// SendRebalancePlanReminderEmail reminds a user about a rebalance plan with trades still pending
+func (s *emailService) SendRebalancePlanReminderEmail(to, planName string, pendingTrades int, lastActivity time.Time) error {
+	if to == "" {
+		return fmt.Errorf("recipient email cannot be empty")
+	}
 
-	// Compose email message
-	message := fmt.Sprintf("From: %s\r\n"+
-		"To: %s\r\n"+
-		"Subject: %s\r\n"+
-		"\r\n"+
-		"%s\r\n", s.from, to, subject, body)
+	return s.sendTemplate(to, emails.RebalanceReminder, emails.RebalanceReminderData{
+		PlanName:      planName,
+		PendingTrades: pendingTrades,
+		LastActivity:  lastActivity.Format("January 2, 2006"),
+	}, nil)
+}
 
-	// Set up authentication
-	auth := smtp.PlainAuth("", s.username, s.password, s.host)
+// SendInviteEmail invites a provisioned user to choose a password for their new account
+func (s *emailService) SendInviteEmail(to, organizationName, inviteToken string, expiresAt time.Time) error {
+	if to == "" {
+		return fmt.Errorf("recipient email cannot be empty")
+	}
+	if inviteToken == "" {
+		return fmt.Errorf("invite token cannot be empty")
+	}
 
-	// Connect to SMTP server with TLS
-	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+	return s.sendTemplate(to, emails.Invite, emails.InviteData{
+		OrganizationName: organizationName,
+		InviteLink:       fmt.Sprintf("https://app.example.com/reset-password?token=%s", inviteToken),
+		ExpiresAt:        expiresAt.Format("January 2, 2006"),
+	}, nil)
+}
 
-	// Try to send with TLS
-	err := s.sendWithTLS(addr, auth, []byte(message), to)
-	if err != nil {
-		// If TLS fails, try without TLS for development/testing
-		err = smtp.SendMail(addr, auth, s.from, []string{to}, []byte(message))
-		if err != nil {
-			return fmt.Errorf("failed to send email: %w", err)
-		}
+// SendOrganizationInviteEmail invites an existing or future user to join an organization
+func (s *emailService) SendOrganizationInviteEmail(
+	to, organizationName, inviterEmail string,
+	role models.OrganizationRole,
+	inviteToken string,
+	expiresAt time.Time,
+) error {
+	if to == "" {
+		return fmt.Errorf("recipient email cannot be empty")
+	}
+	if inviteToken == "" {
+		return fmt.Errorf("invite token cannot be empty")
 	}
 
-	return nil
+	return s.sendTemplate(to, emails.OrganizationInvite, emails.OrganizationInviteData{
+		OrganizationName: organizationName,
+		InviterEmail:     inviterEmail,
+		Role:             string(role),
+		InviteLink:       fmt.Sprintf("https://app.example.com/invitations/accept?token=%s", inviteToken),
+		ExpiresAt:        expiresAt.Format("January 2, 2006"),
+	}, nil)
 }
 
-// sendWithTLS sends email with TLS/SSL connection
-func (s *emailService) sendWithTLS(addr string, auth smtp.Auth, message []byte, to string) error {
-	// Create TLS configuration with minimum TLS 1.2 for security
-	tlsConfig := &tls.Config{
-		ServerName: s.host,
-		MinVersion: tls.VersionTLS12,
+// SendDelegationRequestEmail asks a client to consent to an advisor's access to their
+// portfolios
+func (s *emailService) SendDelegationRequestEmail(
+	to, advisorEmail string,
+	access models.DelegationAccess,
+	consentToken string,
+	expiresAt time.Time,
+) error {
+	if to == "" {
+		return fmt.Errorf("recipient email cannot be empty")
 	}
-
-	// Connect to the SMTP server
-	client, err := smtp.Dial(addr)
-	if err != nil {
-		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	if consentToken == "" {
+		return fmt.Errorf("consent token cannot be empty")
 	}
-	defer func() {
-		_ = client.Close()
-	}()
 
-	// Start TLS
-	if err = client.StartTLS(tlsConfig); err != nil {
-		return fmt.Errorf("failed to start TLS: %w", err)
+	verb := "view"
+	if access.CanWrite() {
+		verb = "manage"
 	}
+	return s.sendTemplate(to, emails.DelegationRequest, emails.DelegationRequestData{
+		AdvisorEmail: advisorEmail,
+		Access:       verb,
+		ConsentLink:  fmt.Sprintf("https://app.example.com/delegations/consent?token=%s", consentToken),
+		ExpiresAt:    expiresAt.Format("January 2, 2006"),
+	}, nil)
+}
 
-	// Authenticate
-	if err = client.Auth(auth); err != nil {
-		return fmt.Errorf("authentication failed: %w", err)
+// SendPerformanceDigestEmail sends a report subscription's weekly or monthly performance
+// digest, with a link that turns the subscription off
+func (s *emailService) SendPerformanceDigestEmail(to string, digest *dto.PerformanceDigest, unsubscribeToken string) error {
+	if to == "" {
+		return fmt.Errorf("recipient email cannot be empty")
 	}
-
-	// Set sender
-	if err = client.Mail(s.from); err != nil {
-		return fmt.Errorf("failed to set sender: %w", err)
+	if unsubscribeToken == "" {
+		return fmt.Errorf("unsubscribe token cannot be empty")
 	}
 
-	// Set recipient
-	if err = client.Rcpt(to); err != nil {
-		return fmt.Errorf("failed to set recipient: %w", err)
+	unsubscribeLink := fmt.Sprintf("https://app.example.com/api/report-subscriptions/unsubscribe?token=%s", unsubscribeToken)
+	return s.sendTemplate(to, emails.PerformanceDigest, performanceDigestData(digest, unsubscribeLink),
+		map[string]string{"List-Unsubscribe": fmt.Sprintf("<%s>", unsubscribeLink)})
+}
+
+// performanceDigestData prepares a performance digest for its email, with amounts and dates
+// in the digest's locale
+func performanceDigestData(digest *dto.PerformanceDigest, unsubscribeLink string) emails.PerformanceDigestData {
+	f := i18n.NewFormatter(digest.Locale, "")
+
+	data := emails.PerformanceDigestData{
+		Period:          "Weekly",
+		StartDate:       f.LongDate(digest.StartDate),
+		EndDate:         f.LongDate(digest.EndDate),
+		Portfolios:      make([]emails.DigestPortfolio, 0, len(digest.Portfolios)),
+		TopMovers:       make([]emails.DigestMover, 0, len(digest.TopMovers)),
+		UnsubscribeLink: unsubscribeLink,
+	}
+	if digest.Frequency == models.ReportFrequencyMonthly {
+		data.Period = "Monthly"
 	}
 
-	// Send message
-	w, err := client.Data()
-	if err != nil {
-		return fmt.Errorf("failed to get data writer: %w", err)
+	for _, portfolio := range digest.Portfolios {
+		performance := portfolio.Performance
+		line := emails.DigestPortfolio{
+			Name:     portfolio.Name,
+			Currency: portfolio.BaseCurrency,
+			Income:   f.Money(portfolio.Income, portfolio.BaseCurrency),
+		}
+		if performance.EndingValue != nil {
+			line.Value = f.Money(*performance.EndingValue, portfolio.BaseCurrency)
+		}
+		if portfolio.ValueChange != nil {
+			line.ValueChange = f.SignedAmount(*portfolio.ValueChange)
+			line.NetDeposits = f.SignedAmount(performance.NetCashFlow)
+		}
+		if performance.TWRPercent != nil {
+			line.Return = f.SignedPercent(*performance.TWRPercent)
+		}
+		data.Portfolios = append(data.Portfolios, line)
 	}
 
-	_, err = w.Write(message)
-	if err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
+	for _, mover := range digest.TopMovers {
+		data.TopMovers = append(data.TopMovers, emails.DigestMover{
+			Symbol:        mover.Symbol,
+			ChangePercent: f.SignedPercent(mover.ChangePercent),
+			ValueChange:   f.SignedAmount(mover.ValueChange),
+		})
 	}
 
-	err = w.Close()
+	return data
+}
+
+// sendTemplate renders the email name with data and sends it through the transport
+func (s *emailService) sendTemplate(to, name string, data any, headers map[string]string) error {
+	message, err := s.templates.Render(name, data)
 	if err != nil {
-		return fmt.Errorf("failed to close data writer: %w", err)
+		return err
 	}
-
-	// Send QUIT command
-	return client.Quit()
+	return s.transport.Send(&emails.Email{
+		From:    s.from,
+		To:      to,
+		Headers: headers,
+		Message: *message,
+	})
 }
@@ -2,8 +2,15 @@ package services
 
 import (
 	"testing"
+	"time"
 
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/emails"
+	"github.com/lenon/portfolios/internal/models"
 )
 
 func TestNewEmailService(t *testing.T) {
@@ -41,6 +48,91 @@ func TestEmailService_SendPasswordResetEmail_InvalidSMTP(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to send email")
 }
 
+func TestEmailService_SendRebalancePlanReminderEmail_EmptyRecipient(t *testing.T) {
+	service := NewEmailService("smtp.example.com", 587, "user@example.com", "password", "noreply@example.com")
+
+	err := service.SendRebalancePlanReminderEmail("", "Q3 rebalance", 2, time.Now())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "recipient email cannot be empty")
+}
+
 // Note: Testing actual email sending would require a real SMTP server or mock server
 // For comprehensive testing, you would typically use a mock SMTP server
 // The tests above cover the validation logic and error handling
+
+func TestEmailService_SendInviteEmail_EmptyToken(t *testing.T) {
+	service := NewEmailService("smtp.example.com", 587, "user@example.com", "password", "noreply@example.com")
+
+	err := service.SendInviteEmail("jane@example.com", "Acme", "", time.Now().Add(24*time.Hour))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invite token cannot be empty")
+}
+
+func TestEmailService_SendPerformanceDigestEmail_EmptyToken(t *testing.T) {
+	service := NewEmailService("smtp.example.com", 587, "user@example.com", "password", "noreply@example.com")
+
+	err := service.SendPerformanceDigestEmail("jane@example.com", &dto.PerformanceDigest{}, "")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsubscribe token cannot be empty")
+}
+
+func TestPerformanceDigestBody(t *testing.T) {
+	endingValue := decimal.NewFromInt(1100)
+	change := decimal.NewFromInt(100)
+	twr := decimal.RequireFromString("4.5")
+	digest := &dto.PerformanceDigest{
+		Frequency: models.ReportFrequencyWeekly,
+		StartDate: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC),
+		Portfolios: []dto.DigestPortfolio{{
+			Name:         "Retirement",
+			BaseCurrency: "USD",
+			Performance: dto.StatementPerformance{
+				EndingValue: &endingValue,
+				NetCashFlow: decimal.NewFromInt(50),
+				TWRPercent:  &twr,
+			},
+			ValueChange: &change,
+			Income:      decimal.NewFromInt(5),
+		}},
+		TopMovers: []dto.DigestMover{{Symbol: "AAPL", ChangePercent: decimal.NewFromInt(-5), ValueChange: decimal.NewFromInt(-20)}},
+	}
+
+	render := func() *emails.Message {
+		message, err := emails.MustLoadTemplates().Render(emails.PerformanceDigest,
+			performanceDigestData(digest, "https://app.example.com/unsubscribe"))
+		require.NoError(t, err)
+		return message
+	}
+	message := render()
+	body := message.Text
+
+	assert.Equal(t, "Weekly portfolio digest: January 6, 2025 to January 12, 2025", message.Subject)
+
+	assert.Contains(t, body, "from January 6, 2025 to January 12, 2025")
+	assert.Contains(t, body, "Retirement (USD)")
+	assert.Contains(t, body, "Change in value: +100.00")
+	assert.Contains(t, body, "Return: +4.50%")
+	assert.Contains(t, body, "Value: 1,100.00 USD")
+	assert.Contains(t, body, "Income received: 5.00 USD")
+	assert.Contains(t, body, "AAPL     -5.00% (-20.00 on your position)")
+	assert.Contains(t, body, "https://app.example.com/unsubscribe")
+
+	// Amounts and dates are written in the user's locale
+	endingValue = decimal.RequireFromString("1234567.891")
+	digest.Locale = "de-DE"
+	body = render().Text
+	assert.Contains(t, body, "from 06.01.2025 to 12.01.2025")
+	assert.Contains(t, body, "Value: 1.234.567,89 USD")
+	assert.Contains(t, body, "Return: +4,50%")
+	assert.Contains(t, body, "AAPL     -5,00% (-20,00 on your position)")
+
+	// The HTML alternative shows the same figures in the branded layout
+	html := render().HTML
+	assert.Contains(t, html, "<td>Value</td><td align=\"right\">1.234.567,89 USD</td>")
+	assert.Contains(t, html, `href="https://app.example.com/unsubscribe"`)
+	assert.Contains(t, html, "The Portfolios Team")
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// ExportService defines the interface for exporting a user's data. Exports are passed on a
+// page or record at a time as they are read so they can be streamed to the client.
+type ExportService interface {
+	ExportTransactions(ctx context.Context, portfolioID, userID string, fn func([]*models.Transaction) error) error
+	ExportAccount(ctx context.Context, userID string, fn func(dto.AccountExportRecord) error) error
+}
+
+// exportService implements ExportService interface
+type exportService struct {
+	userRepo        repository.UserRepository
+	settingsRepo    repository.UserSettingsRepository
+	portfolioRepo   repository.PortfolioRepository
+	holdingRepo     repository.HoldingRepository
+	taxLotRepo      repository.TaxLotRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.PerformanceSnapshotRepository
+}
+
+// NewExportService creates a new ExportService instance
+func NewExportService(
+	userRepo repository.UserRepository,
+	settingsRepo repository.UserSettingsRepository,
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	taxLotRepo repository.TaxLotRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+) ExportService {
+	return &exportService{
+		userRepo:        userRepo,
+		settingsRepo:    settingsRepo,
+		portfolioRepo:   portfolioRepo,
+		holdingRepo:     holdingRepo,
+		taxLotRepo:      taxLotRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+	}
+}
+
+// ExportTransactions passes a portfolio's transactions to fn a page at a time, oldest first.
+// Access is checked before fn is first called, so an error returned before then means
+// nothing was exported.
+func (s *exportService) ExportTransactions(
+	ctx context.Context,
+	portfolioID, userID string,
+	fn func([]*models.Transaction) error,
+) error {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return models.ErrUnauthorizedAccess
+	}
+
+	return s.transactionRepo.IterateByPortfolioID(ctx, portfolioID, fn)
+}
+
+// ExportAccount passes everything stored about a user to fn a record at a time: the user,
+// their settings, then each portfolio followed by its holdings, tax lots, transactions and
+// snapshots. An error returned before fn is first called means nothing was exported.
+func (s *exportService) ExportAccount(ctx context.Context, userID string, fn func(dto.AccountExportRecord) error) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return models.ErrUserNotFound
+	}
+	settings, err := s.settingsRepo.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve settings: %w", err)
+	}
+	portfolios, err := s.portfolioRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve portfolios: %w", err)
+	}
+
+	if err := fn(dto.AccountExportRecord{Type: dto.AccountExportUser, Data: user}); err != nil {
+		return err
+	}
+	if err := fn(dto.AccountExportRecord{Type: dto.AccountExportSettings, Data: settings}); err != nil {
+		return err
+	}
+	for _, portfolio := range portfolios {
+		if err := s.exportPortfolio(ctx, portfolio, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportPortfolio passes a portfolio and everything recorded in it to fn
+func (s *exportService) exportPortfolio(
+	ctx context.Context,
+	portfolio *models.Portfolio,
+	fn func(dto.AccountExportRecord) error,
+) error {
+	portfolioID := portfolio.ID.String()
+	if err := fn(dto.AccountExportRecord{Type: dto.AccountExportPortfolio, Data: portfolio}); err != nil {
+		return err
+	}
+
+	holdings, err := s.holdingRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve holdings: %w", err)
+	}
+	for _, holding := range holdings {
+		if err := fn(dto.AccountExportRecord{Type: dto.AccountExportHolding, Data: holding}); err != nil {
+			return err
+		}
+	}
+
+	taxLots, err := s.taxLotRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve tax lots: %w", err)
+	}
+	for _, lot := range taxLots {
+		if err := fn(dto.AccountExportRecord{Type: dto.AccountExportTaxLot, Data: lot}); err != nil {
+			return err
+		}
+	}
+
+	err = s.transactionRepo.IterateByPortfolioID(ctx, portfolioID, func(transactions []*models.Transaction) error {
+		for _, tx := range transactions {
+			if err := fn(dto.AccountExportRecord{Type: dto.AccountExportTransaction, Data: tx}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.snapshotRepo.IterateByPortfolioID(ctx, portfolioID, func(snapshots []*models.PerformanceSnapshot) error {
+		for _, snapshot := range snapshots {
+			if err := fn(dto.AccountExportRecord{Type: dto.AccountExportSnapshot, Data: snapshot}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupExportServiceTest(t *testing.T) (*gorm.DB, ExportService, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.UserSettings{}, &models.Portfolio{}, &models.Holding{},
+		&models.TaxLot{}, &models.Transaction{}, &models.PerformanceSnapshot{},
+	))
+
+	user := &models.User{Email: "export@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Export", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	service := NewExportService(
+		repository.NewUserRepository(db),
+		repository.NewUserSettingsRepository(db),
+		repository.NewPortfolioRepository(db),
+		repository.NewHoldingRepository(db),
+		repository.NewTaxLotRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+	)
+	return db, service, user, portfolio
+}
+
+func createExportTransactions(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, count int) {
+	price := decimal.NewFromInt(100)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < count; i++ {
+		require.NoError(t, db.Create(&models.Transaction{
+			PortfolioID: portfolio.ID, Type: models.TransactionTypeBuy, Symbol: "VTI",
+			Date: start.AddDate(0, 0, i), Quantity: decimal.NewFromInt(1), Price: &price, Currency: "USD",
+		}).Error)
+	}
+}
+
+func TestExportService_ExportTransactions(t *testing.T) {
+	db, service, user, portfolio := setupExportServiceTest(t)
+	ctx := context.Background()
+	createExportTransactions(t, db, portfolio, 5)
+
+	t.Run("every transaction oldest first", func(t *testing.T) {
+		var exported []*models.Transaction
+		err := service.ExportTransactions(ctx, portfolio.ID.String(), user.ID.String(), func(page []*models.Transaction) error {
+			exported = append(exported, page...)
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Len(t, exported, 5)
+		for i := 1; i < len(exported); i++ {
+			assert.True(t, exported[i].Date.After(exported[i-1].Date))
+		}
+	})
+
+	t.Run("another user's portfolio", func(t *testing.T) {
+		called := false
+		err := service.ExportTransactions(ctx, portfolio.ID.String(), uuid.New().String(), func([]*models.Transaction) error {
+			called = true
+			return nil
+		})
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+		assert.False(t, called)
+	})
+
+	t.Run("unknown portfolio", func(t *testing.T) {
+		err := service.ExportTransactions(ctx, uuid.New().String(), user.ID.String(), func([]*models.Transaction) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+	})
+
+	t.Run("stops when the client goes away", func(t *testing.T) {
+		pages := 0
+		err := service.ExportTransactions(ctx, portfolio.ID.String(), user.ID.String(), func([]*models.Transaction) error {
+			pages++
+			return context.Canceled
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, pages)
+	})
+}
+
+func TestExportService_ExportAccount(t *testing.T) {
+	db, service, user, portfolio := setupExportServiceTest(t)
+	ctx := context.Background()
+	createExportTransactions(t, db, portfolio, 3)
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID: portfolio.ID, Symbol: "VTI", Quantity: decimal.NewFromInt(3),
+		CostBasis: decimal.NewFromInt(300), AvgCostPrice: decimal.NewFromInt(100),
+	}).Error)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, db.Create(&models.PerformanceSnapshot{
+			PortfolioID: portfolio.ID, Date: time.Date(2024, 2, 1+i, 0, 0, 0, 0, time.UTC),
+			TotalValue: decimal.NewFromInt(300), TotalCostBasis: decimal.NewFromInt(300),
+			TotalReturn: decimal.Zero, TotalReturnPct: decimal.Zero,
+		}).Error)
+	}
+	other := &models.User{Email: "other@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(other).Error)
+	require.NoError(t, db.Create(&models.Portfolio{UserID: other.ID, Name: "Not mine", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}).Error)
+
+	counts := make(map[dto.AccountExportRecordType]int)
+	var order []dto.AccountExportRecordType
+	err := service.ExportAccount(ctx, user.ID.String(), func(record dto.AccountExportRecord) error {
+		if counts[record.Type] == 0 {
+			order = append(order, record.Type)
+		}
+		counts[record.Type]++
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[dto.AccountExportRecordType]int{
+		dto.AccountExportUser:        1,
+		dto.AccountExportSettings:    1,
+		dto.AccountExportPortfolio:   1,
+		dto.AccountExportHolding:     1,
+		dto.AccountExportTransaction: 3,
+		dto.AccountExportSnapshot:    3,
+	}, counts)
+	assert.Equal(t, []dto.AccountExportRecordType{
+		dto.AccountExportUser, dto.AccountExportSettings, dto.AccountExportPortfolio,
+		dto.AccountExportHolding, dto.AccountExportTransaction, dto.AccountExportSnapshot,
+	}, order)
+
+	t.Run("unknown user", func(t *testing.T) {
+		err := service.ExportAccount(ctx, uuid.New().String(), func(dto.AccountExportRecord) error { return nil })
+		assert.ErrorIs(t, err, models.ErrUserNotFound)
+	})
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// featureFlagCacheTTL is how long overrides are kept in memory, which bounds how long other
+// instances take to follow a change made through the admin API
+const featureFlagCacheTTL = 30 * time.Second
+
+// FeatureFlagService defines the interface for deciding which features are enabled. A
+// feature is on or off for a user by their own override, else by the override for everyone,
+// else by the configuration, else by its default.
+type FeatureFlagService interface {
+	IsEnabled(ctx context.Context, name, userID string) bool
+	ForUser(ctx context.Context, userID string) *dto.FeaturesResponse
+	List(ctx context.Context) ([]dto.FeatureFlagResponse, error)
+	SetOverride(ctx context.Context, name string, req dto.SetFeatureFlagRequest) (*dto.FeatureFlagResponse, error)
+	DeleteOverride(ctx context.Context, name, userID string) (*dto.FeatureFlagResponse, error)
+	Configure(features map[string]bool)
+}
+
+// featureFlagService implements FeatureFlagService interface
+type featureFlagService struct {
+	repo  repository.FeatureFlagRepository
+	users repository.UserRepository
+	now   func() time.Time
+
+	mu         sync.Mutex
+	configured map[string]bool
+	overrides  []*models.FeatureFlagOverride
+	loadedAt   time.Time
+}
+
+// NewFeatureFlagService creates a new FeatureFlagService instance with the features the
+// configuration turns on or off
+func NewFeatureFlagService(
+	repo repository.FeatureFlagRepository,
+	users repository.UserRepository,
+	configured map[string]bool,
+) FeatureFlagService {
+	return &featureFlagService{
+		repo:       repo,
+		users:      users,
+		now:        time.Now,
+		configured: configured,
+	}
+}
+
+// ValidateFeatures checks that features only names feature flags that exist
+func ValidateFeatures(features map[string]bool) error {
+	for name := range features {
+		if _, ok := models.FeatureDefault(name); !ok {
+			return fmt.Errorf("%w: %q, expected one of %v", models.ErrFeatureNotFound, name, models.Features())
+		}
+	}
+	return nil
+}
+
+// Configure replaces the features the configuration turns on or off, when it is reloaded
+func (s *featureFlagService) Configure(features map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configured = features
+}
+
+// IsEnabled reports whether a feature is on for a user. Unknown features are off. While the
+// overrides can't be loaded, the ones loaded last stay in effect.
+func (s *featureFlagService) IsEnabled(ctx context.Context, name, userID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh(ctx, false)
+	return s.enabled(name, userID)
+}
+
+// ForUser returns whether each feature is on for a user
+func (s *featureFlagService) ForUser(ctx context.Context, userID string) *dto.FeaturesResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refresh(ctx, false)
+
+	response := &dto.FeaturesResponse{Features: make(map[string]bool)}
+	for _, name := range models.Features() {
+		response.Features[name] = s.enabled(name, userID)
+	}
+	return response
+}
+
+// List returns every feature flag with its overrides, as stored now
+func (s *featureFlagService) List(ctx context.Context) ([]dto.FeatureFlagResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(ctx, true); err != nil {
+		return nil, err
+	}
+
+	flags := make([]dto.FeatureFlagResponse, 0, len(models.Features()))
+	for _, name := range models.Features() {
+		flags = append(flags, s.flag(name))
+	}
+	return flags, nil
+}
+
+// SetOverride turns a feature on or off for a user, or for everyone when req names no user
+func (s *featureFlagService) SetOverride(ctx context.Context, name string, req dto.SetFeatureFlagRequest) (*dto.FeatureFlagResponse, error) {
+	if _, ok := models.FeatureDefault(name); !ok {
+		return nil, models.ErrFeatureNotFound
+	}
+	userID, err := s.overrideUser(req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	override := &models.FeatureFlagOverride{Name: name, UserID: userID, Enabled: *req.Enabled}
+	if err := s.repo.Save(ctx, override); err != nil {
+		return nil, err
+	}
+	return s.reloaded(ctx, name)
+}
+
+// DeleteOverride removes the override of a feature for a user, or for everyone when userID
+// is empty
+func (s *featureFlagService) DeleteOverride(ctx context.Context, name, userID string) (*dto.FeatureFlagResponse, error) {
+	if _, ok := models.FeatureDefault(name); !ok {
+		return nil, models.ErrFeatureNotFound
+	}
+	var id *uuid.UUID
+	if userID != "" {
+		parsed, err := uuid.Parse(userID)
+		if err != nil {
+			return nil, models.ErrUserNotFound
+		}
+		id = &parsed
+	}
+
+	if err := s.repo.Delete(ctx, name, id); err != nil {
+		return nil, err
+	}
+	return s.reloaded(ctx, name)
+}
+
+// overrideUser returns the ID of the existing user an override is for, or nil for everyone
+func (s *featureFlagService) overrideUser(userID string) (*uuid.UUID, error) {
+	if userID == "" {
+		return nil, nil
+	}
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &user.ID, nil
+}
+
+// reloaded loads the overrides just changed and returns the flag of feature name
+func (s *featureFlagService) reloaded(ctx context.Context, name string) (*dto.FeatureFlagResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.refresh(ctx, true); err != nil {
+		return nil, err
+	}
+	flag := s.flag(name)
+	return &flag, nil
+}
+
+// refresh loads the overrides if they are older than featureFlagCacheTTL, or always when
+// force is set. The caller holds s.mu.
+func (s *featureFlagService) refresh(ctx context.Context, force bool) error {
+	if !force && !s.loadedAt.IsZero() && s.now().Sub(s.loadedAt) < featureFlagCacheTTL {
+		return nil
+	}
+	overrides, err := s.repo.FindAll(ctx)
+	// A failed load is retried after the TTL too, rather than by every request
+	s.loadedAt = s.now()
+	if err != nil {
+		return err
+	}
+	s.overrides = overrides
+	return nil
+}
+
+// enabled decides whether a feature is on for a user, or for users without an override
+// of their own when userID is empty. The caller holds s.mu.
+func (s *featureFlagService) enabled(name, userID string) bool {
+	enabled, ok := models.FeatureDefault(name)
+	if !ok {
+		return false
+	}
+	if configured, ok := s.configured[name]; ok {
+		enabled = configured
+	}
+
+	userOverridden := false
+	for _, override := range s.overrides {
+		if override.Name != name {
+			continue
+		}
+		switch {
+		case override.UserID == nil && !userOverridden:
+			enabled = override.Enabled
+		case override.UserID != nil && userID != "" && override.UserID.String() == userID:
+			enabled = override.Enabled
+			userOverridden = true
+		}
+	}
+	return enabled
+}
+
+// flag returns the admin view of feature name. The caller holds s.mu.
+func (s *featureFlagService) flag(name string) dto.FeatureFlagResponse {
+	flag := dto.FeatureFlagResponse{
+		Name:      name,
+		Enabled:   s.enabled(name, ""),
+		Overrides: []dto.FeatureFlagOverrideResponse{},
+	}
+	flag.Default, _ = models.FeatureDefault(name)
+	if configured, ok := s.configured[name]; ok {
+		flag.Configured = &configured
+	}
+	for _, override := range s.overrides {
+		if override.Name != name {
+			continue
+		}
+		response := dto.FeatureFlagOverrideResponse{Enabled: override.Enabled, UpdatedAt: override.UpdatedAt}
+		if override.UserID != nil {
+			response.UserID = override.UserID.String()
+		}
+		flag.Overrides = append(flag.Overrides, response)
+	}
+	return flag
+}
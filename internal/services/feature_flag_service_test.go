@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupFeatureFlagTest(t *testing.T, configured map[string]bool) (*gorm.DB, *featureFlagService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.FeatureFlagOverride{}))
+
+	service := NewFeatureFlagService(
+		repository.NewFeatureFlagRepository(db), repository.NewUserRepository(db), configured,
+	).(*featureFlagService)
+	return db, service
+}
+
+func TestFeatureFlagService_Precedence(t *testing.T) {
+	db, service := setupFeatureFlagTest(t, map[string]bool{models.FeatureWhatIf: false})
+	ctx := context.Background()
+	user := &models.User{Email: "flags@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	userID := user.ID.String()
+	enabled, disabled := true, false
+
+	// Defaults apply until the configuration or an override says otherwise
+	assert.True(t, service.IsEnabled(ctx, models.FeatureSimulations, userID))
+	assert.False(t, service.IsEnabled(ctx, models.FeatureWhatIf, userID))
+	assert.False(t, service.IsEnabled(ctx, "unknown", userID))
+
+	_, err := service.SetOverride(ctx, models.FeatureWhatIf, dto.SetFeatureFlagRequest{Enabled: &enabled, UserID: userID})
+	require.NoError(t, err)
+	assert.True(t, service.IsEnabled(ctx, models.FeatureWhatIf, userID))
+	assert.False(t, service.IsEnabled(ctx, models.FeatureWhatIf, ""))
+
+	// A user's own override wins over the override for everyone
+	flag, err := service.SetOverride(ctx, models.FeatureWhatIf, dto.SetFeatureFlagRequest{Enabled: &enabled})
+	require.NoError(t, err)
+	assert.True(t, flag.Enabled)
+	require.NotNil(t, flag.Configured)
+	assert.False(t, *flag.Configured)
+	assert.Len(t, flag.Overrides, 2)
+
+	_, err = service.SetOverride(ctx, models.FeatureWhatIf, dto.SetFeatureFlagRequest{Enabled: &disabled, UserID: userID})
+	require.NoError(t, err)
+	assert.False(t, service.IsEnabled(ctx, models.FeatureWhatIf, userID))
+	assert.True(t, service.ForUser(ctx, "").Features[models.FeatureWhatIf])
+	assert.False(t, service.ForUser(ctx, userID).Features[models.FeatureWhatIf])
+
+	flag, err = service.DeleteOverride(ctx, models.FeatureWhatIf, "")
+	require.NoError(t, err)
+	assert.False(t, flag.Enabled)
+	assert.Len(t, flag.Overrides, 1)
+
+	// Reloaded configuration applies to users without an override
+	service.Configure(nil)
+	assert.True(t, service.IsEnabled(ctx, models.FeatureWhatIf, ""))
+	assert.False(t, service.IsEnabled(ctx, models.FeatureWhatIf, userID))
+
+	flags, err := service.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, flags, len(models.Features()))
+}
+
+func TestFeatureFlagService_Errors(t *testing.T) {
+	_, service := setupFeatureFlagTest(t, nil)
+	ctx := context.Background()
+	enabled := true
+
+	_, err := service.SetOverride(ctx, "unknown", dto.SetFeatureFlagRequest{Enabled: &enabled})
+	assert.Equal(t, models.ErrFeatureNotFound, err)
+
+	_, err = service.DeleteOverride(ctx, "unknown", "")
+	assert.Equal(t, models.ErrFeatureNotFound, err)
+
+	_, err = service.SetOverride(ctx, models.FeatureSimulations,
+		dto.SetFeatureFlagRequest{Enabled: &enabled, UserID: "00000000-0000-0000-0000-000000000001"})
+	assert.True(t, errors.Is(err, models.ErrUserNotFound))
+
+	assert.NoError(t, ValidateFeatures(map[string]bool{models.FeatureProjections: false}))
+	assert.ErrorIs(t, ValidateFeatures(map[string]bool{"graphql": true}), models.ErrFeatureNotFound)
+}
+
+func TestFeatureFlagService_CachesOverrides(t *testing.T) {
+	db, service := setupFeatureFlagTest(t, nil)
+	ctx := context.Background()
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	assert.True(t, service.IsEnabled(ctx, models.FeatureProjections, ""))
+
+	// Overrides written by another instance apply once the cache expires
+	require.NoError(t, db.Create(&models.FeatureFlagOverride{Name: models.FeatureProjections, Enabled: false}).Error)
+	assert.True(t, service.IsEnabled(ctx, models.FeatureProjections, ""))
+	now = now.Add(featureFlagCacheTTL)
+	assert.False(t, service.IsEnabled(ctx, models.FeatureProjections, ""))
+
+	// While the overrides can't be loaded, the ones loaded last stay in effect
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	now = now.Add(featureFlagCacheTTL)
+	assert.False(t, service.IsEnabled(ctx, models.FeatureProjections, ""))
+}
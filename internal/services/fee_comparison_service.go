@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type alias for dto type for consistency with the other analytics services
+type FeeComparisonResult = dto.FeeComparisonResult
+
+// FeeComparisonService defines the interface for replaying a portfolio's trades under
+// alternative broker fee schedules
+type FeeComparisonService interface {
+	Compare(ctx context.Context, portfolioID, userID string, schedules []models.FeeSchedule, startDate, endDate *time.Time) (*FeeComparisonResult, error)
+}
+
+// feeComparisonService implements FeeComparisonService interface
+type feeComparisonService struct {
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+}
+
+// NewFeeComparisonService creates a new FeeComparisonService instance
+func NewFeeComparisonService(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+) FeeComparisonService {
+	return &feeComparisonService{
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *feeComparisonService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// Compare recomputes the fees of every buy and sell in the portfolio under each schedule and
+// compares them with the commissions actually paid. Schedules default to the built-in presets.
+func (s *feeComparisonService) Compare(ctx context.Context, portfolioID, userID string, schedules []models.FeeSchedule, startDate, endDate *time.Time) (*FeeComparisonResult, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	if len(schedules) == 0 {
+		schedules = models.FeeSchedulePresets()
+	}
+	for i := range schedules {
+		if err := schedules[i].Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	result := &FeeComparisonResult{
+		PortfolioID: portfolioID,
+		TradedValue: decimal.Zero,
+		ActualFees:  decimal.Zero,
+		Comparisons: make([]dto.FeeScheduleComparison, len(schedules)),
+	}
+	for i, schedule := range schedules {
+		result.Comparisons[i] = dto.FeeScheduleComparison{Schedule: schedule, TotalFees: decimal.Zero}
+	}
+
+	for _, tx := range transactions {
+		if !isFeeComparisonTrade(tx) {
+			continue
+		}
+
+		result.TradeCount++
+		result.TradedValue = result.TradedValue.Add(tx.Quantity.Abs().Mul(*tx.Price))
+		result.ActualFees = result.ActualFees.Add(tx.Commission)
+		for i := range result.Comparisons {
+			comparison := &result.Comparisons[i]
+			comparison.TotalFees = comparison.TotalFees.Add(comparison.Schedule.Fee(tx.Quantity, *tx.Price))
+		}
+
+		date := tx.Date
+		if result.FirstTradeDate == nil || date.Before(*result.FirstTradeDate) {
+			result.FirstTradeDate = &date
+		}
+		if result.LastTradeDate == nil || date.After(*result.LastTradeDate) {
+			result.LastTradeDate = &date
+		}
+	}
+
+	result.TradedValue = result.TradedValue.Round(2)
+	result.ActualFees = result.ActualFees.Round(2)
+	result.FeesPercentOfTraded = feesPercentOfTraded(result.ActualFees, result.TradedValue)
+	for i := range result.Comparisons {
+		comparison := &result.Comparisons[i]
+		comparison.Difference = comparison.TotalFees.Sub(result.ActualFees)
+		comparison.FeesPercentOfTraded = feesPercentOfTraded(comparison.TotalFees, result.TradedValue)
+	}
+
+	return result, nil
+}
+
+// isFeeComparisonTrade returns true for the buys and sells a broker would have charged for
+func isFeeComparisonTrade(tx *models.Transaction) bool {
+	if tx.Type != models.TransactionTypeBuy && tx.Type != models.TransactionTypeSell {
+		return false
+	}
+	return tx.Price != nil && !tx.Quantity.IsZero()
+}
+
+// feesPercentOfTraded returns fees as a percentage of the traded value
+func feesPercentOfTraded(fees, tradedValue decimal.Decimal) decimal.Decimal {
+	if tradedValue.IsZero() {
+		return decimal.Zero
+	}
+	return fees.Div(tradedValue).Mul(decimal.NewFromInt(100)).Round(4)
+}
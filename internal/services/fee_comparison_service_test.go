@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupFeeComparisonTest(t *testing.T) (*gorm.DB, FeeComparisonService, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}))
+
+	user := &models.User{Email: "fees@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Fees",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	service := NewFeeComparisonService(
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+	)
+	return db, service, user, portfolio
+}
+
+func createFeeComparisonTransaction(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, txType models.TransactionType, date time.Time, quantity, price, commission string) {
+	tx := &models.Transaction{
+		PortfolioID: portfolio.ID,
+		Type:        txType,
+		Symbol:      "AAPL",
+		Date:        date,
+		Quantity:    decimal.RequireFromString(quantity),
+		Commission:  decimal.RequireFromString(commission),
+	}
+	if price != "" {
+		p := decimal.RequireFromString(price)
+		tx.Price = &p
+	}
+	require.NoError(t, db.Create(tx).Error)
+}
+
+func TestFeeComparisonService_Compare(t *testing.T) {
+	db, service, user, portfolio := setupFeeComparisonTest(t)
+	ctx := context.Background()
+
+	jan := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	createFeeComparisonTransaction(t, db, portfolio, models.TransactionTypeBuy, jan, "100", "50", "4.95")
+	createFeeComparisonTransaction(t, db, portfolio, models.TransactionTypeSell, mar, "2000", "10", "4.95")
+	createFeeComparisonTransaction(t, db, portfolio, models.TransactionTypeDividend, mar, "0", "12.50", "0")
+
+	t.Run("compares against every preset by default", func(t *testing.T) {
+		result, err := service.Compare(ctx, portfolio.ID.String(), user.ID.String(), nil, nil, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, result.TradeCount)
+		assert.True(t, decimal.NewFromInt(25000).Equal(result.TradedValue), result.TradedValue.String())
+		assert.True(t, decimal.RequireFromString("9.90").Equal(result.ActualFees), result.ActualFees.String())
+		assert.True(t, decimal.RequireFromString("0.0396").Equal(result.FeesPercentOfTraded), result.FeesPercentOfTraded.String())
+		require.NotNil(t, result.FirstTradeDate)
+		assert.True(t, jan.Equal(*result.FirstTradeDate))
+		assert.True(t, mar.Equal(*result.LastTradeDate))
+		require.Len(t, result.Comparisons, len(models.FeeSchedulePresets()))
+
+		byKey := map[string]int{}
+		for i, comparison := range result.Comparisons {
+			byKey[comparison.Schedule.Key] = i
+		}
+
+		zero := result.Comparisons[byKey["ZERO_COMMISSION"]]
+		assert.True(t, zero.TotalFees.IsZero())
+		assert.True(t, decimal.RequireFromString("-9.90").Equal(zero.Difference), zero.Difference.String())
+
+		// $0.50 raised to the $1.00 minimum, then $20.00 capped at $10.00
+		perShare := result.Comparisons[byKey["PER_SHARE_0_01"]]
+		assert.True(t, decimal.NewFromInt(11).Equal(perShare.TotalFees), perShare.TotalFees.String())
+		assert.True(t, decimal.RequireFromString("1.10").Equal(perShare.Difference), perShare.Difference.String())
+
+		// 0.25% of $5,000 and of $20,000
+		percentage := result.Comparisons[byKey["PERCENTAGE_0_25"]]
+		assert.True(t, decimal.RequireFromString("62.50").Equal(percentage.TotalFees), percentage.TotalFees.String())
+		assert.True(t, decimal.RequireFromString("0.25").Equal(percentage.FeesPercentOfTraded), percentage.FeesPercentOfTraded.String())
+	})
+
+	t.Run("limits trades to the date range", func(t *testing.T) {
+		start := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		schedules := []models.FeeSchedule{{
+			Name: "Flat per share",
+			Type: models.FeeScheduleTypePerShare,
+			Rate: decimal.RequireFromString("0.001"),
+		}}
+
+		result, err := service.Compare(ctx, portfolio.ID.String(), user.ID.String(), schedules, &start, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.TradeCount)
+		require.Len(t, result.Comparisons, 1)
+		assert.True(t, decimal.NewFromInt(2).Equal(result.Comparisons[0].TotalFees), result.Comparisons[0].TotalFees.String())
+	})
+
+	t.Run("rejects an invalid schedule", func(t *testing.T) {
+		schedules := []models.FeeSchedule{{Name: "Broken", Type: models.FeeScheduleTypePercentage}}
+
+		_, err := service.Compare(ctx, portfolio.ID.String(), user.ID.String(), schedules, nil, nil)
+		assert.ErrorIs(t, err, models.ErrInvalidFeeSchedule)
+	})
+
+	t.Run("portfolio not found", func(t *testing.T) {
+		_, err := service.Compare(ctx, uuid.New().String(), user.ID.String(), nil, nil, nil)
+		assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+	})
+
+	t.Run("other user's portfolio", func(t *testing.T) {
+		_, err := service.Compare(ctx, portfolio.ID.String(), uuid.New().String(), nil, nil, nil)
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+	})
+}
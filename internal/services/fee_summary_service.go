@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type alias for dto type for consistency with the other analytics services
+type FeeSummary = dto.FeeSummary
+
+// feeCategoryCommission is the fee summary category of the commissions paid on trades
+const feeCategoryCommission = "COMMISSION"
+
+// FeeSummaryService defines the interface for summarizing the fees a portfolio paid
+type FeeSummaryService interface {
+	Summarize(ctx context.Context, portfolioID, userID string, year int) (*FeeSummary, error)
+}
+
+// feeSummaryService implements FeeSummaryService interface
+type feeSummaryService struct {
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.PerformanceSnapshotRepository
+	now             func() time.Time
+}
+
+// NewFeeSummaryService creates a new FeeSummaryService instance
+func NewFeeSummaryService(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+) FeeSummaryService {
+	return &feeSummaryService{
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Summarize totals a portfolio's commissions and fee transactions in a year, the current one
+// when year is 0, and measures their drag on its annualized return
+func (s *feeSummaryService) Summarize(ctx context.Context, portfolioID, userID string, year int) (*FeeSummary, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	now := s.now()
+	if year == 0 {
+		year = now.Year()
+	}
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0).Add(-time.Nanosecond)
+	if start.After(now) {
+		return nil, models.ErrInvalidFeeYear
+	}
+	if end.After(now) {
+		end = now
+	}
+
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, &start, &end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+
+	summary := &FeeSummary{
+		PortfolioID: portfolioID,
+		Year:        year,
+		StartDate:   start,
+		EndDate:     end,
+		TotalFees:   decimal.Zero,
+		Categories:  make([]dto.FeeCategorySummary, 0, len(models.FeeTransactionTypes)+1),
+	}
+	categories := make(map[string]int)
+	addCategory := func(category string) {
+		categories[category] = len(summary.Categories)
+		summary.Categories = append(summary.Categories, dto.FeeCategorySummary{Category: category, Amount: decimal.Zero})
+	}
+	addCategory(feeCategoryCommission)
+	for _, txType := range models.FeeTransactionTypes {
+		addCategory(string(txType))
+	}
+	add := func(category string, amount decimal.Decimal) {
+		if amount.IsZero() {
+			return
+		}
+		i := categories[category]
+		summary.Categories[i].Amount = summary.Categories[i].Amount.Add(amount)
+		summary.Categories[i].Count++
+		summary.TotalFees = summary.TotalFees.Add(amount)
+	}
+	for _, tx := range transactions {
+		add(feeCategoryCommission, tx.Commission)
+		if tx.IsFee() {
+			add(string(tx.Type), tx.Quantity)
+		}
+	}
+
+	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve snapshots: %w", err)
+	}
+	measureFeeDrag(summary, snapshots)
+	return summary, nil
+}
+
+// measureFeeDrag sets the fee summary's valuation fields from the year's snapshots, oldest first
+func measureFeeDrag(summary *FeeSummary, snapshots []*models.PerformanceSnapshot) {
+	if len(snapshots) == 0 {
+		return
+	}
+
+	total := decimal.Zero
+	for _, snapshot := range snapshots {
+		total = total.Add(snapshot.TotalValue)
+	}
+	averageValue := total.Div(decimal.NewFromInt(int64(len(snapshots))))
+	summary.AverageValue = &averageValue
+	if years := yearsBetween(summary.StartDate, summary.EndDate); years > 0 && !averageValue.IsZero() {
+		expenseRatio := summary.TotalFees.Div(averageValue).Div(decimal.NewFromFloat(years)).Mul(decimal.NewFromInt(100))
+		summary.ExpenseRatio = &expenseRatio
+	}
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	years := yearsBetween(first.Date, last.Date)
+	if years <= 0 || first.TotalValue.IsZero() {
+		return
+	}
+	gross := annualizedPercent(first.TotalValue, last.TotalValue, years)
+	net := annualizedPercent(first.TotalValue, last.TotalValue.Sub(summary.TotalFees), years)
+	drag := gross.Sub(net)
+	summary.GrossAnnualizedReturn = &gross
+	summary.NetAnnualizedReturn = &net
+	summary.FeeDrag = &drag
+}
+
+// yearsBetween returns the time from start to end in years
+func yearsBetween(start, end time.Time) float64 {
+	return end.Sub(start).Hours() / 24 / 365.25
+}
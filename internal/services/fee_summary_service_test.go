@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupFeeSummaryTest(t *testing.T) (*gorm.DB, *feeSummaryService, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.PerformanceSnapshot{}))
+
+	user := &models.User{Email: "fee-summary@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Fee Summary",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	service := NewFeeSummaryService(
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+	).(*feeSummaryService)
+	service.now = func() time.Time { return time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC) }
+	return db, service, user, portfolio
+}
+
+func createFeeSummaryTransaction(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, txType models.TransactionType, date time.Time, quantity, commission string) {
+	price := decimal.NewFromInt(100)
+	tx := &models.Transaction{
+		PortfolioID: portfolio.ID,
+		Type:        txType,
+		Symbol:      "VXUS",
+		Date:        date,
+		Quantity:    decimal.RequireFromString(quantity),
+		Price:       &price,
+		Commission:  decimal.RequireFromString(commission),
+	}
+	require.NoError(t, db.Create(tx).Error)
+}
+
+func createFeeSummarySnapshot(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, date time.Time, value string) {
+	snapshot := &models.PerformanceSnapshot{
+		PortfolioID:    portfolio.ID,
+		Date:           date,
+		TotalValue:     decimal.RequireFromString(value),
+		TotalCostBasis: decimal.NewFromInt(10000),
+	}
+	snapshot.CalculateMetrics()
+	require.NoError(t, db.Create(snapshot).Error)
+}
+
+func TestFeeSummaryService_Summarize(t *testing.T) {
+	db, service, user, portfolio := setupFeeSummaryTest(t)
+	ctx := context.Background()
+
+	jan := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC)
+	dec := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	createFeeSummaryTransaction(t, db, portfolio, models.TransactionTypeBuy, jan, "100", "5")
+	createFeeSummaryTransaction(t, db, portfolio, models.TransactionTypeManagementFee, jun, "60", "0")
+	createFeeSummaryTransaction(t, db, portfolio, models.TransactionTypeManagementFee, dec, "40", "0")
+	createFeeSummaryTransaction(t, db, portfolio, models.TransactionTypeADRFee, jun, "3", "0")
+	createFeeSummaryTransaction(t, db, portfolio, models.TransactionTypeSell, time.Date(2023, 12, 29, 0, 0, 0, 0, time.UTC), "10", "9.99")
+	createFeeSummarySnapshot(t, db, portfolio, jan, "10000")
+	createFeeSummarySnapshot(t, db, portfolio, dec, "11000")
+
+	t.Run("totals the year's fees by category", func(t *testing.T) {
+		summary, err := service.Summarize(ctx, portfolio.ID.String(), user.ID.String(), 2024)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2024, summary.Year)
+		assert.True(t, decimal.NewFromInt(108).Equal(summary.TotalFees), summary.TotalFees.String())
+
+		require.Len(t, summary.Categories, 4)
+		amounts := make(map[string]string)
+		counts := make(map[string]int)
+		for _, category := range summary.Categories {
+			amounts[category.Category] = category.Amount.String()
+			counts[category.Category] = category.Count
+		}
+		assert.Equal(t, "5", amounts[feeCategoryCommission])
+		assert.Equal(t, "100", amounts[string(models.TransactionTypeManagementFee)])
+		assert.Equal(t, 2, counts[string(models.TransactionTypeManagementFee)])
+		assert.Equal(t, "3", amounts[string(models.TransactionTypeADRFee)])
+		assert.Equal(t, "0", amounts[string(models.TransactionTypeFXFee)])
+		assert.Zero(t, counts[string(models.TransactionTypeFXFee)])
+	})
+
+	t.Run("measures the fees against the portfolio's value", func(t *testing.T) {
+		summary, err := service.Summarize(ctx, portfolio.ID.String(), user.ID.String(), 2024)
+		require.NoError(t, err)
+
+		require.NotNil(t, summary.AverageValue)
+		assert.True(t, decimal.NewFromInt(10500).Equal(*summary.AverageValue), summary.AverageValue.String())
+		require.NotNil(t, summary.ExpenseRatio)
+		expenseRatio, _ := summary.ExpenseRatio.Float64()
+		assert.InDelta(t, 1.0259, expenseRatio, 0.001)
+
+		require.NotNil(t, summary.GrossAnnualizedReturn)
+		require.NotNil(t, summary.NetAnnualizedReturn)
+		require.NotNil(t, summary.FeeDrag)
+		gross, _ := summary.GrossAnnualizedReturn.Float64()
+		net, _ := summary.NetAnnualizedReturn.Float64()
+		assert.Greater(t, gross, 10.0)
+		assert.Less(t, net, gross)
+		assert.True(t, summary.GrossAnnualizedReturn.Sub(*summary.NetAnnualizedReturn).Equal(*summary.FeeDrag))
+	})
+
+	t.Run("defaults to the current year through today", func(t *testing.T) {
+		summary, err := service.Summarize(ctx, portfolio.ID.String(), user.ID.String(), 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2025, summary.Year)
+		assert.True(t, service.now().Equal(summary.EndDate))
+		assert.True(t, summary.TotalFees.IsZero())
+		assert.Nil(t, summary.AverageValue)
+		assert.Nil(t, summary.FeeDrag)
+	})
+
+	t.Run("rejects a year that hasn't started", func(t *testing.T) {
+		_, err := service.Summarize(ctx, portfolio.ID.String(), user.ID.String(), 2026)
+		assert.ErrorIs(t, err, models.ErrInvalidFeeYear)
+	})
+}
+
+func TestFeeSummaryService_Summarize_Access(t *testing.T) {
+	db, service, _, portfolio := setupFeeSummaryTest(t)
+	ctx := context.Background()
+
+	other := &models.User{Email: "other-fees@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(other).Error)
+
+	_, err := service.Summarize(ctx, portfolio.ID.String(), other.ID.String(), 2024)
+	assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+
+	_, err = service.Summarize(ctx, "00000000-0000-0000-0000-000000000000", other.ID.String(), 2024)
+	assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+}
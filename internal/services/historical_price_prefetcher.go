@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// HistoricalPricePrefetcher fetches each symbol's price history at most once for a fixed
+// date window and serves every later lookup from memory. Batch jobs create one per run
+// and share it across portfolios, so provider calls scale with distinct symbols rather
+// than with symbols × portfolios.
+type HistoricalPricePrefetcher struct {
+	marketData MarketDataService
+	startDate  time.Time
+	endDate    time.Time
+
+	mu            sync.Mutex
+	prices        map[string][]*HistoricalPrice
+	errs          map[string]error
+	providerCalls int
+}
+
+// NewHistoricalPricePrefetcher creates a prefetcher covering startDate through endDate
+func NewHistoricalPricePrefetcher(marketData MarketDataService, startDate, endDate time.Time) *HistoricalPricePrefetcher {
+	return &HistoricalPricePrefetcher{
+		marketData: marketData,
+		startDate:  startDate,
+		endDate:    endDate,
+		prices:     make(map[string][]*HistoricalPrice),
+		errs:       make(map[string]error),
+	}
+}
+
+// Prefetch loads history for all symbols not already fetched. Failures for individual
+// symbols are remembered and don't stop the batch, except for models.ErrQuotaExceeded:
+// once the provider budget is gone the remaining symbols are skipped and the error is returned.
+func (p *HistoricalPricePrefetcher) Prefetch(ctx context.Context, symbols []string) error {
+	for _, symbol := range uniqueSymbols(symbols) {
+		if _, err := p.fetch(ctx, symbol); errors.Is(err, models.ErrQuotaExceeded) {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetHistoricalPrices returns the prices for symbol between startDate and endDate.
+// Ranges inside the prefetch window are served from memory; anything outside it
+// goes straight to the market data service.
+func (p *HistoricalPricePrefetcher) GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+	if startDate.Before(p.startDate) || endDate.After(p.endDate) {
+		return p.marketData.GetHistoricalPrices(ctx, symbol, startDate, endDate)
+	}
+
+	prices, err := p.fetch(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*HistoricalPrice, 0, len(prices))
+	for _, price := range prices {
+		if price.Date.Before(startDate) || price.Date.After(endDate) {
+			continue
+		}
+		result = append(result, price)
+	}
+	return result, nil
+}
+
+// PriceOn returns the closing price for symbol on date, or on the closest earlier
+// trading day in the window when the market was closed on date. Coin pairs trade every
+// day, so for them a price more than a day older than date is stale and not used.
+func (p *HistoricalPricePrefetcher) PriceOn(ctx context.Context, symbol string, date time.Time) (decimal.Decimal, bool) {
+	prices, err := p.fetch(ctx, symbol)
+	if err != nil {
+		return decimal.Zero, false
+	}
+
+	// Prices are sorted by date, so the last one not after date is the closest
+	idx := sort.Search(len(prices), func(i int) bool {
+		return prices[i].Date.After(date)
+	})
+	if idx == 0 {
+		return decimal.Zero, false
+	}
+	closest := prices[idx-1]
+	if models.AssetTypeForSymbol(normalizeSymbol(symbol)) == models.AssetTypeCrypto {
+		// Allow the previous day's close for runs just after midnight UTC, before the
+		// provider has recorded a price for the new day
+		day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if closest.Date.Before(day.AddDate(0, 0, -1)) {
+			return decimal.Zero, false
+		}
+	}
+	return closest.Close, true
+}
+
+// PricesOn returns the closing prices on date for the given symbols. Symbols without
+// a price in the window are omitted from the result.
+func (p *HistoricalPricePrefetcher) PricesOn(ctx context.Context, symbols []string, date time.Time) map[string]decimal.Decimal {
+	result := make(map[string]decimal.Decimal, len(symbols))
+	for _, symbol := range symbols {
+		if price, ok := p.PriceOn(ctx, symbol, date); ok {
+			result[symbol] = price
+		}
+	}
+	return result
+}
+
+// Err returns the error recorded while fetching symbol, if any
+func (p *HistoricalPricePrefetcher) Err(symbol string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.errs[normalizeSymbol(symbol)]
+}
+
+// ProviderCalls returns how many times the market data service was asked for history
+func (p *HistoricalPricePrefetcher) ProviderCalls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.providerCalls
+}
+
+// fetch returns the prefetched history for symbol, loading it on first use.
+// The lock is held across the provider call so concurrent callers never fetch twice.
+func (p *HistoricalPricePrefetcher) fetch(ctx context.Context, symbol string) ([]*HistoricalPrice, error) {
+	symbol = normalizeSymbol(symbol)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if prices, ok := p.prices[symbol]; ok {
+		return prices, nil
+	}
+	if err, ok := p.errs[symbol]; ok {
+		return nil, err
+	}
+
+	p.providerCalls++
+	prices, err := p.marketData.GetHistoricalPrices(ctx, symbol, p.startDate, p.endDate)
+	if err != nil {
+		// A quota failure says nothing about the symbol, so don't remember it
+		if !errors.Is(err, models.ErrQuotaExceeded) {
+			p.errs[symbol] = err
+		}
+		return nil, fmt.Errorf("failed to prefetch historical prices for %s: %w", symbol, err)
+	}
+
+	sorted := make([]*HistoricalPrice, 0, len(prices))
+	for _, price := range prices {
+		if price != nil {
+			sorted = append(sorted, price)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+
+	p.prices[symbol] = sorted
+	return sorted, nil
+}
+
+// normalizeSymbol upper-cases and trims a ticker symbol
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// uniqueSymbols returns the distinct, normalized, non-empty symbols in input order
+func uniqueSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	result := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = normalizeSymbol(symbol)
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		result = append(result, symbol)
+	}
+	return result
+}
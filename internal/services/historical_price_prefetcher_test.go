@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func dailyCloses(start time.Time, closes ...int64) []*HistoricalPrice {
+	prices := make([]*HistoricalPrice, len(closes))
+	for i, c := range closes {
+		prices[i] = &HistoricalPrice{Date: start.AddDate(0, 0, i), Close: decimal.NewFromInt(c)}
+	}
+	return prices
+}
+
+func TestHistoricalPricePrefetcher_FetchesEachSymbolOnce(t *testing.T) {
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 4)
+
+	mockMarketData := new(MockMarketDataService)
+	mockMarketData.On("GetHistoricalPrices", "AAPL", start, end).Return(dailyCloses(start, 100, 101, 102), nil).Once()
+	mockMarketData.On("GetHistoricalPrices", "MSFT", start, end).Return(dailyCloses(start, 300, 301, 302), nil).Once()
+
+	prefetcher := NewHistoricalPricePrefetcher(mockMarketData, start, end)
+
+	// Three portfolios holding overlapping symbols
+	require.NoError(t, prefetcher.Prefetch(ctx, []string{"AAPL", "msft"}))
+	require.NoError(t, prefetcher.Prefetch(ctx, []string{"AAPL"}))
+	require.NoError(t, prefetcher.Prefetch(ctx, []string{" MSFT ", "AAPL"}))
+
+	prices, err := prefetcher.GetHistoricalPrices(ctx, "AAPL", start.AddDate(0, 0, 1), end)
+	require.NoError(t, err)
+	assert.Len(t, prices, 2)
+
+	assert.Equal(t, 2, prefetcher.ProviderCalls())
+	mockMarketData.AssertExpectations(t)
+}
+
+func TestHistoricalPricePrefetcher_PriceOn(t *testing.T) {
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 6)
+
+	mockMarketData := new(MockMarketDataService)
+	mockMarketData.On("GetHistoricalPrices", "AAPL", start, end).Return(dailyCloses(start.AddDate(0, 0, 1), 101, 102), nil)
+
+	prefetcher := NewHistoricalPricePrefetcher(mockMarketData, start, end)
+
+	t.Run("exact date", func(t *testing.T) {
+		price, ok := prefetcher.PriceOn(ctx, "AAPL", start.AddDate(0, 0, 1))
+		assert.True(t, ok)
+		assert.True(t, decimal.NewFromInt(101).Equal(price))
+	})
+
+	t.Run("market closed uses previous close", func(t *testing.T) {
+		price, ok := prefetcher.PriceOn(ctx, "AAPL", start.AddDate(0, 0, 5))
+		assert.True(t, ok)
+		assert.True(t, decimal.NewFromInt(102).Equal(price))
+	})
+
+	t.Run("no earlier close", func(t *testing.T) {
+		_, ok := prefetcher.PriceOn(ctx, "AAPL", start)
+		assert.False(t, ok)
+	})
+}
+
+func TestHistoricalPricePrefetcher_PriceOn_CoinPair(t *testing.T) {
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 6)
+
+	// Prices stop after the 3rd, as if the provider stopped reporting the coin
+	mockMarketData := new(MockMarketDataService)
+	mockMarketData.On("GetHistoricalPrices", "BTC-USD", start, end).Return(dailyCloses(start, 100, 101, 102), nil)
+
+	prefetcher := NewHistoricalPricePrefetcher(mockMarketData, start, end)
+
+	price, ok := prefetcher.PriceOn(ctx, "BTC-USD", start.AddDate(0, 0, 2))
+	assert.True(t, ok)
+	assert.True(t, decimal.NewFromInt(102).Equal(price))
+
+	// Just after midnight the previous day's close is still current
+	price, ok = prefetcher.PriceOn(ctx, "BTC-USD", start.AddDate(0, 0, 3))
+	assert.True(t, ok)
+	assert.True(t, decimal.NewFromInt(102).Equal(price))
+
+	// Coins trade every day, so an older close is stale rather than a weekend's
+	_, ok = prefetcher.PriceOn(ctx, "BTC-USD", start.AddDate(0, 0, 5))
+	assert.False(t, ok)
+}
+
+func TestHistoricalPricePrefetcher_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 4)
+	providerErr := errors.New("unknown symbol")
+
+	mockMarketData := new(MockMarketDataService)
+	mockMarketData.On("GetHistoricalPrices", "BAD", start, end).Return(nil, providerErr).Once()
+	mockMarketData.On("GetHistoricalPrices", "AAPL", start, end).Return(nil, models.ErrQuotaExceeded).Once()
+
+	prefetcher := NewHistoricalPricePrefetcher(mockMarketData, start, end)
+
+	// A failing symbol is remembered; the quota error stops the batch before MSFT
+	err := prefetcher.Prefetch(ctx, []string{"BAD", "AAPL", "MSFT"})
+	assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+	assert.ErrorIs(t, prefetcher.Err("BAD"), providerErr)
+	assert.NoError(t, prefetcher.Err("AAPL"))
+
+	_, err = prefetcher.GetHistoricalPrices(ctx, "BAD", start, end)
+	assert.ErrorIs(t, err, providerErr)
+
+	assert.Equal(t, 2, prefetcher.ProviderCalls())
+	mockMarketData.AssertExpectations(t)
+}
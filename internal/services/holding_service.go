@@ -1,6 +1,10 @@
 package services
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/repository"
 	"github.com/shopspring/decimal"
@@ -8,60 +12,124 @@ import (
 
 // HoldingService defines the interface for holding operations
 type HoldingService interface {
-	GetByPortfolioID(portfolioID, userID string) ([]*models.Holding, error)
-	GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) (*models.Holding, error)
-	GetPortfolioValue(portfolioID, userID string, prices map[string]decimal.Decimal) (decimal.Decimal, error)
+	GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.Holding, error)
+	GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) (*models.Holding, error)
+	GetByPortfolioIDAsOf(ctx context.Context, portfolioID, userID string, asOf time.Time) ([]*models.Holding, error)
+	GetPortfolioValue(ctx context.Context, portfolioID, userID string, prices map[string]decimal.Decimal) (decimal.Decimal, error)
 }
 
 // holdingService implements HoldingService interface
 type holdingService struct {
-	holdingRepo   repository.HoldingRepository
-	portfolioRepo repository.PortfolioRepository
+	holdingRepo     repository.HoldingRepository
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+	rounding        models.RoundingPolicy
 }
 
 // NewHoldingService creates a new HoldingService instance
 func NewHoldingService(
 	holdingRepo repository.HoldingRepository,
 	portfolioRepo repository.PortfolioRepository,
+) HoldingService {
+	return NewHoldingServiceWithLedger(holdingRepo, portfolioRepo, nil, models.DefaultRoundingPolicy())
+}
+
+// NewHoldingServiceWithLedger creates a HoldingService that also rebuilds the holdings of
+// past dates by replaying the portfolio's transactions, rounding corporate actions with
+// rounding
+func NewHoldingServiceWithLedger(
+	holdingRepo repository.HoldingRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	rounding models.RoundingPolicy,
 ) HoldingService {
 	return &holdingService{
-		holdingRepo:   holdingRepo,
-		portfolioRepo: portfolioRepo,
+		holdingRepo:     holdingRepo,
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		rounding:        rounding,
 	}
 }
 
 // GetByPortfolioID retrieves all holdings for a portfolio
-func (s *holdingService) GetByPortfolioID(portfolioID, userID string) ([]*models.Holding, error) {
+func (s *holdingService) GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.Holding, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	return s.holdingRepo.FindByPortfolioID(portfolioID)
+	return s.holdingRepo.FindByPortfolioID(ctx, portfolioID)
 }
 
 // GetByPortfolioIDAndSymbol retrieves a specific holding by portfolio ID and symbol
-func (s *holdingService) GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) (*models.Holding, error) {
+func (s *holdingService) GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) (*models.Holding, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	return s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+}
+
+// GetByPortfolioIDAsOf rebuilds the holdings a portfolio had at the end of a day by
+// replaying its transactions up to that day, leaving out positions that were closed. Each
+// holding's UpdatedAt is the date of the last transaction in its symbol.
+func (s *holdingService) GetByPortfolioIDAsOf(ctx context.Context, portfolioID, userID string, asOf time.Time) ([]*models.Holding, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
+	if s.transactionRepo == nil {
+		return nil, fmt.Errorf("holdings history requires the transaction ledger")
+	}
+
+	year, month, day := asOf.Date()
+	end := time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, nil, &end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+	sortTransactionsForReplay(transactions)
 
-	return s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+	replay := newLedgerReplay(portfolio, s.rounding)
+	lastTraded := make(map[string]time.Time)
+	for _, tx := range transactions {
+		if !tx.Date.Before(end) {
+			break
+		}
+		if err := replay.apply(tx); err != nil {
+			return nil, err
+		}
+		lastTraded[tx.Symbol] = tx.Date
+	}
+	rebuilt, _ := replay.results()
+
+	holdings := make([]*models.Holding, 0, len(rebuilt))
+	for _, holding := range rebuilt {
+		if holding.Quantity.IsZero() {
+			continue
+		}
+		holding.UpdatedAt = lastTraded[holding.Symbol]
+		holdings = append(holdings, holding)
+	}
+	return holdings, nil
 }
 
 // GetPortfolioValue calculates the total value of all holdings in a portfolio
 // prices is a map of symbol to current market price
-func (s *holdingService) GetPortfolioValue(portfolioID, userID string, prices map[string]decimal.Decimal) (decimal.Decimal, error) {
-	holdings, err := s.GetByPortfolioID(portfolioID, userID)
+func (s *holdingService) GetPortfolioValue(ctx context.Context, portfolioID, userID string, prices map[string]decimal.Decimal) (decimal.Decimal, error) {
+	holdings, err := s.GetByPortfolioID(ctx, portfolioID, userID)
 	if err != nil {
 		return decimal.Zero, err
 	}
@@ -73,7 +141,7 @@ func (s *holdingService) GetPortfolioValue(portfolioID, userID string, prices ma
 			// If price not provided, use cost basis (conservative estimate)
 			totalValue = totalValue.Add(holding.CostBasis)
 		} else {
-			marketValue := holding.Quantity.Mul(price)
+			marketValue := holding.MarketValue(price)
 			totalValue = totalValue.Add(marketValue)
 		}
 	}
@@ -1,7 +1,9 @@
 package services
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lenon/portfolios/internal/models"
@@ -18,6 +20,8 @@ func TestNewHoldingService(t *testing.T) {
 }
 
 func TestHoldingService_GetByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	mockHoldingRepo := new(MockHoldingRepository)
 	mockPortfolioRepo := new(MockPortfolioRepository)
 	service := NewHoldingService(mockHoldingRepo, mockPortfolioRepo)
@@ -48,7 +52,7 @@ func TestHoldingService_GetByPortfolioID(t *testing.T) {
 		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil).Once()
 		mockHoldingRepo.On("FindByPortfolioID", portfolioID.String()).Return(holdings, nil).Once()
 
-		result, err := service.GetByPortfolioID(portfolioID.String(), userID.String())
+		result, err := service.GetByPortfolioID(ctx, portfolioID.String(), userID.String())
 		assert.NoError(t, err)
 		assert.Len(t, result, 1)
 
@@ -59,7 +63,7 @@ func TestHoldingService_GetByPortfolioID(t *testing.T) {
 	t.Run("portfolio not found", func(t *testing.T) {
 		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(nil, models.ErrPortfolioNotFound).Once()
 
-		_, err := service.GetByPortfolioID(portfolioID.String(), userID.String())
+		_, err := service.GetByPortfolioID(ctx, portfolioID.String(), userID.String())
 		assert.Equal(t, models.ErrPortfolioNotFound, err)
 
 		mockPortfolioRepo.AssertExpectations(t)
@@ -69,7 +73,7 @@ func TestHoldingService_GetByPortfolioID(t *testing.T) {
 		differentUserID := uuid.New()
 		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil).Once()
 
-		_, err := service.GetByPortfolioID(portfolioID.String(), differentUserID.String())
+		_, err := service.GetByPortfolioID(ctx, portfolioID.String(), differentUserID.String())
 		assert.Equal(t, models.ErrUnauthorizedAccess, err)
 
 		mockPortfolioRepo.AssertExpectations(t)
@@ -77,6 +81,8 @@ func TestHoldingService_GetByPortfolioID(t *testing.T) {
 }
 
 func TestHoldingService_GetByPortfolioIDAndSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	mockHoldingRepo := new(MockHoldingRepository)
 	mockPortfolioRepo := new(MockPortfolioRepository)
 	service := NewHoldingService(mockHoldingRepo, mockPortfolioRepo)
@@ -105,7 +111,7 @@ func TestHoldingService_GetByPortfolioIDAndSymbol(t *testing.T) {
 		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil).Once()
 		mockHoldingRepo.On("FindByPortfolioIDAndSymbol", portfolioID.String(), "AAPL").Return(holding, nil).Once()
 
-		result, err := service.GetByPortfolioIDAndSymbol(portfolioID.String(), "AAPL", userID.String())
+		result, err := service.GetByPortfolioIDAndSymbol(ctx, portfolioID.String(), "AAPL", userID.String())
 		assert.NoError(t, err)
 		assert.Equal(t, "AAPL", result.Symbol)
 
@@ -114,7 +120,90 @@ func TestHoldingService_GetByPortfolioIDAndSymbol(t *testing.T) {
 	})
 }
 
+func TestHoldingService_GetByPortfolioIDAsOf(t *testing.T) {
+	ctx := context.Background()
+
+	mockHoldingRepo := new(MockHoldingRepository)
+	mockPortfolioRepo := new(MockPortfolioRepository)
+	mockTransactionRepo := new(MockTransactionRepository)
+	service := NewHoldingServiceWithLedger(mockHoldingRepo, mockPortfolioRepo, mockTransactionRepo, models.DefaultRoundingPolicy())
+
+	portfolioID := uuid.New()
+	userID := uuid.New()
+
+	portfolio := &models.Portfolio{
+		ID:              portfolioID,
+		UserID:          userID,
+		Name:            "Test Portfolio",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+
+	date := func(day int) time.Time { return time.Date(2023, 6, day, 0, 0, 0, 0, time.UTC) }
+	trade := func(txType models.TransactionType, symbol string, day int, quantity, price int64) *models.Transaction {
+		p := decimal.NewFromInt(price)
+		return &models.Transaction{
+			ID:          uuid.New(),
+			PortfolioID: portfolioID,
+			Type:        txType,
+			Symbol:      symbol,
+			Date:        date(day),
+			Quantity:    decimal.NewFromInt(quantity),
+			Price:       &p,
+		}
+	}
+	// Returned newest first, as the repository does
+	transactions := []*models.Transaction{
+		trade(models.TransactionTypeSell, "AAPL", 30, 10, 120),
+		trade(models.TransactionTypeBuy, "MSFT", 15, 5, 300),
+		trade(models.TransactionTypeBuy, "AAPL", 10, 4, 110),
+		trade(models.TransactionTypeBuy, "AAPL", 1, 6, 100),
+	}
+
+	t.Run("rebuilds the holdings at the end of the day", func(t *testing.T) {
+		end := date(16)
+		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil).Once()
+		mockTransactionRepo.On("FindByPortfolioIDWithFilters", portfolioID.String(), (*string)(nil), (*time.Time)(nil), &end).
+			Return(transactions[1:], nil).Once()
+
+		result, err := service.GetByPortfolioIDAsOf(ctx, portfolioID.String(), userID.String(), date(15))
+		assert.NoError(t, err)
+		if assert.Len(t, result, 2) {
+			assert.Equal(t, "AAPL", result[0].Symbol)
+			assert.True(t, result[0].Quantity.Equal(decimal.NewFromInt(10)))
+			assert.True(t, result[0].CostBasis.Equal(decimal.NewFromInt(1040)))
+			assert.Equal(t, date(10), result[0].UpdatedAt)
+			assert.Equal(t, "MSFT", result[1].Symbol)
+		}
+
+		mockPortfolioRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("leaves out closed positions", func(t *testing.T) {
+		end := date(31)
+		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil).Once()
+		mockTransactionRepo.On("FindByPortfolioIDWithFilters", portfolioID.String(), (*string)(nil), (*time.Time)(nil), &end).
+			Return(transactions, nil).Once()
+
+		result, err := service.GetByPortfolioIDAsOf(ctx, portfolioID.String(), userID.String(), date(30))
+		assert.NoError(t, err)
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, "MSFT", result[0].Symbol)
+		}
+	})
+
+	t.Run("unauthorized access", func(t *testing.T) {
+		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil).Once()
+
+		_, err := service.GetByPortfolioIDAsOf(ctx, portfolioID.String(), uuid.New().String(), date(30))
+		assert.Equal(t, models.ErrUnauthorizedAccess, err)
+	})
+}
+
 func TestHoldingService_GetPortfolioValue(t *testing.T) {
+	ctx := context.Background()
+
 	mockHoldingRepo := new(MockHoldingRepository)
 	mockPortfolioRepo := new(MockPortfolioRepository)
 	service := NewHoldingService(mockHoldingRepo, mockPortfolioRepo)
@@ -158,7 +247,7 @@ func TestHoldingService_GetPortfolioValue(t *testing.T) {
 			"GOOGL": decimal.NewFromInt(220),
 		}
 
-		totalValue, err := service.GetPortfolioValue(portfolioID.String(), userID.String(), prices)
+		totalValue, err := service.GetPortfolioValue(ctx, portfolioID.String(), userID.String(), prices)
 		assert.NoError(t, err)
 		assert.True(t, totalValue.Equal(decimal.NewFromInt(29000)))
 
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+const (
+	// DefaultJobListLimit is how many jobs List returns when no limit is given
+	DefaultJobListLimit = 20
+
+	// MaxJobListLimit is the most jobs List returns
+	MaxJobListLimit = 100
+)
+
+// QueuedJobHandler runs a queued job and returns its result, which is stored as JSON.
+// report stores the job's latest progress, also as JSON, for status polling; it may be
+// called as often as needed since the worker pool throttles the writes.
+type QueuedJobHandler func(ctx context.Context, job *models.QueuedJob, report func(progress any)) (any, error)
+
+// JobQueueService defines the interface for queueing heavy operations to run in the background
+type JobQueueService interface {
+	// Enqueue queues a job of jobType for userID with payload as its request. A job on a
+	// portfolio is only queued if the user owns it. The job runs in the tenant schema of ctx.
+	Enqueue(ctx context.Context, userID, portfolioID string, jobType models.QueuedJobType, payload any) (*models.QueuedJob, error)
+
+	// Get returns one of the user's jobs
+	Get(ctx context.Context, jobID, userID string) (*models.QueuedJob, error)
+
+	// List returns the user's most recent jobs, newest first
+	List(ctx context.Context, userID string, limit int) ([]*models.QueuedJob, error)
+
+	// Enqueued receives a value after jobs are queued, so that workers in this process can
+	// pick them up without waiting for their next poll
+	Enqueued() <-chan struct{}
+}
+
+// jobQueueService implements JobQueueService interface
+type jobQueueService struct {
+	jobRepo       repository.QueuedJobRepository
+	portfolioRepo repository.PortfolioRepository
+	enqueued      chan struct{}
+}
+
+// NewJobQueueService creates a new JobQueueService instance
+func NewJobQueueService(jobRepo repository.QueuedJobRepository, portfolioRepo repository.PortfolioRepository) JobQueueService {
+	return &jobQueueService{
+		jobRepo:       jobRepo,
+		portfolioRepo: portfolioRepo,
+		enqueued:      make(chan struct{}, 1),
+	}
+}
+
+// Enqueue queues a job after checking the user owns its portfolio
+func (s *jobQueueService) Enqueue(ctx context.Context, userID, portfolioID string, jobType models.QueuedJobType, payload any) (*models.QueuedJob, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	job := &models.QueuedJob{
+		UserID:       uid,
+		Type:         jobType,
+		TenantSchema: database.SchemaFromContext(ctx),
+	}
+	if scope, ok := models.OrganizationScopeFromContext(ctx); ok {
+		job.OrganizationID = &scope.OrganizationID
+	}
+	if scope, ok := models.DelegationScopeFromContext(ctx); ok {
+		job.DelegationID = &scope.DelegationID
+	}
+
+	if portfolioID != "" {
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			if errors.Is(err, models.ErrPortfolioNotFound) {
+				return nil, models.ErrPortfolioNotFound
+			}
+			return nil, fmt.Errorf("failed to find portfolio: %w", err)
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return nil, models.ErrUnauthorizedAccess
+		}
+		job.PortfolioID = &portfolio.ID
+	}
+
+	if err := job.SetPayload(payload); err != nil {
+		return nil, err
+	}
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// Wake an idle worker; one that's busy picks the job up when it's done
+	select {
+	case s.enqueued <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Get returns a job if it belongs to the user
+func (s *jobQueueService) Get(ctx context.Context, jobID, userID string) (*models.QueuedJob, error) {
+	if _, err := uuid.Parse(jobID); err != nil {
+		return nil, models.ErrQueuedJobNotFound
+	}
+
+	job, err := s.jobRepo.FindByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.UserID.String() != userID {
+		return nil, models.ErrQueuedJobNotFound // Don't leak other users' jobs
+	}
+
+	return job, nil
+}
+
+// List returns the user's most recent jobs, up to MaxJobListLimit
+func (s *jobQueueService) List(ctx context.Context, userID string, limit int) ([]*models.QueuedJob, error) {
+	if limit <= 0 {
+		limit = DefaultJobListLimit
+	}
+	if limit > MaxJobListLimit {
+		limit = MaxJobListLimit
+	}
+
+	return s.jobRepo.FindByUserID(ctx, userID, limit)
+}
+
+// Enqueued returns the channel that is signalled when a job is queued
+func (s *jobQueueService) Enqueued() <-chan struct{} {
+	return s.enqueued
+}
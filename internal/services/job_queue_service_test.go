@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupJobQueueTest(t *testing.T) (JobQueueService, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.QueuedJob{}))
+
+	user := &models.User{Email: "queue@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Brokerage", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	return NewJobQueueService(repository.NewQueuedJobRepository(db), repository.NewPortfolioRepository(db)), portfolio
+}
+
+func TestJobQueueService_Enqueue(t *testing.T) {
+	service, portfolio := setupJobQueueTest(t)
+	ctx := context.Background()
+	userID := portfolio.UserID.String()
+
+	t.Run("jobs are queued with their request", func(t *testing.T) {
+		job, err := service.Enqueue(database.WithSchema(ctx, "org_acme"), userID, portfolio.ID.String(),
+			models.QueuedJobTypeRecalculation, dto.RecalculationRequest{DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, models.QueuedJobStatusQueued, job.Status)
+		assert.Equal(t, portfolio.ID, *job.PortfolioID)
+		assert.Equal(t, "org_acme", job.TenantSchema)
+
+		var req dto.RecalculationRequest
+		require.NoError(t, job.DecodePayload(&req))
+		assert.True(t, req.DryRun)
+
+		// Idle workers are woken up
+		select {
+		case <-service.Enqueued():
+		default:
+			t.Fatal("expected a wake-up after queueing a job")
+		}
+	})
+
+	t.Run("jobs without a portfolio", func(t *testing.T) {
+		job, err := service.Enqueue(ctx, userID, "", models.QueuedJobTypeTrackerImport, dto.TrackerImportRequest{})
+		require.NoError(t, err)
+		assert.Nil(t, job.PortfolioID)
+	})
+
+	t.Run("only the owner can queue jobs on a portfolio", func(t *testing.T) {
+		_, err := service.Enqueue(ctx, uuid.NewString(), portfolio.ID.String(), models.QueuedJobTypeTaxReport, dto.TaxReportRequest{})
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+
+		_, err = service.Enqueue(ctx, userID, uuid.NewString(), models.QueuedJobTypeTaxReport, dto.TaxReportRequest{})
+		assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+	})
+}
+
+func TestJobQueueService_GetAndList(t *testing.T) {
+	service, portfolio := setupJobQueueTest(t)
+	ctx := context.Background()
+	userID := portfolio.UserID.String()
+
+	first, err := service.Enqueue(ctx, userID, portfolio.ID.String(), models.QueuedJobTypeRecalculation, dto.RecalculationRequest{})
+	require.NoError(t, err)
+	second, err := service.Enqueue(ctx, userID, portfolio.ID.String(), models.QueuedJobTypeTaxReport, dto.TaxReportRequest{TaxYear: 2024})
+	require.NoError(t, err)
+
+	job, err := service.Get(ctx, first.ID.String(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobTypeRecalculation, job.Type)
+
+	// Other users' jobs and malformed IDs look the same as jobs that don't exist
+	_, err = service.Get(ctx, first.ID.String(), uuid.NewString())
+	assert.ErrorIs(t, err, models.ErrQueuedJobNotFound)
+	_, err = service.Get(ctx, "not-a-uuid", userID)
+	assert.ErrorIs(t, err, models.ErrQueuedJobNotFound)
+
+	jobs, err := service.List(ctx, userID, 0)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, second.ID, jobs[0].ID)
+
+	jobs, err = service.List(ctx, userID, 1)
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+
+	jobs, err = service.List(ctx, uuid.NewString(), 0)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
@@ -0,0 +1,401 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// Corporate action transactions only record the symbol they produced; the source symbol,
+// ratio and spinoff cost basis allocation are recovered from the audit note written by the
+// corporate action service. Spinoffs recorded before the allocation was noted used the default.
+var (
+	mergerNotePattern  = regexp.MustCompile(`^Merger: (\S+) converted to \S+ at (\S+) ratio`)
+	spinoffNotePattern = regexp.MustCompile(`^Spinoff: received \S+ shares of \S+ from (\S+) at (\S+) ratio(?:, allocating (\S+) of cost basis)?`)
+)
+
+// ledgerReplay rebuilds a portfolio's holdings and tax lots by applying its transactions in order
+type ledgerReplay struct {
+	portfolioID uuid.UUID
+	method      models.CostBasisMethod
+	rounding    models.RoundingPolicy
+	holdings    map[string]*models.Holding
+	taxLots     map[string][]*models.TaxLot
+	// gains are realized by return-of-capital distributions that exceed a lot's cost basis
+	gains []*RealizedGain
+	// totals accumulate the income and costs of the transactions replayed so far
+	totals ledgerTotals
+}
+
+// ledgerTotals are the gains realized at average cost, the dividends received and the
+// commissions and fees paid by the transactions a ledger replay has applied
+type ledgerTotals struct {
+	Realized  decimal.Decimal
+	Dividends decimal.Decimal
+	Fees      decimal.Decimal
+}
+
+// newLedgerReplay creates an empty replay for the portfolio that rounds corporate actions
+// the way the corporate action service does
+func newLedgerReplay(portfolio *models.Portfolio, rounding models.RoundingPolicy) *ledgerReplay {
+	return &ledgerReplay{
+		portfolioID: portfolio.ID,
+		method:      portfolio.CostBasisMethod,
+		rounding:    rounding,
+		holdings:    make(map[string]*models.Holding),
+		taxLots:     make(map[string][]*models.TaxLot),
+	}
+}
+
+// sortTransactionsForReplay orders transactions oldest first, breaking ties on same-day
+// transactions by the order in which they were recorded
+func sortTransactionsForReplay(transactions []*models.Transaction) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if !transactions[i].Date.Equal(transactions[j].Date) {
+			return transactions[i].Date.Before(transactions[j].Date)
+		}
+		return transactions[i].CreatedAt.Before(transactions[j].CreatedAt)
+	})
+}
+
+// apply replays a single transaction
+func (r *ledgerReplay) apply(tx *models.Transaction) error {
+	r.totals.Fees = r.totals.Fees.Add(tx.GetFees())
+	if tx.Type == models.TransactionTypeDividend || tx.Type == models.TransactionTypeDividendReinvest {
+		r.totals.Dividends = r.totals.Dividends.Add(statementAmount(tx))
+	}
+
+	switch {
+	case tx.IsBuy():
+		r.acquire(tx)
+		return nil
+	case tx.IsSell():
+		return r.sell(tx)
+	}
+
+	switch tx.Type {
+	case models.TransactionTypeSplit, models.TransactionTypeStockDividend:
+		return r.split(tx)
+	case models.TransactionTypeMerger:
+		return r.merger(tx)
+	case models.TransactionTypeSpinoff:
+		return r.spinoff(tx)
+	case models.TransactionTypeReturnOfCapital:
+		return r.returnOfCapital(tx)
+	default:
+		// Cash dividends, capital gain distributions, fees and cash flows don't change positions,
+		// and ticker changes rename the earlier transactions, so all are already reflected in the
+		// ledger
+		return nil
+	}
+}
+
+// acquire adds the purchased shares to the holding at their total cost and opens a lot
+func (r *ledgerReplay) acquire(tx *models.Transaction) {
+	cost := tx.GetTotalCost()
+	holding := r.holding(tx.Symbol)
+	holding.Multiplier = tx.Multiplier
+	holding.AddShares(tx.Quantity, cost)
+	r.taxLots[tx.Symbol] = append(r.taxLots[tx.Symbol], &models.TaxLot{
+		PortfolioID:   r.portfolioID,
+		Symbol:        tx.Symbol,
+		PurchaseDate:  tx.Date,
+		Quantity:      tx.Quantity,
+		CostBasis:     cost,
+		TransactionID: tx.ID,
+	})
+}
+
+// sell removes shares from the holding at average cost and closes lots in the order of
+// the portfolio's cost basis method. Specific-lot portfolios don't record which lots were
+// sold, so their lots are closed oldest first.
+func (r *ledgerReplay) sell(tx *models.Transaction) error {
+	holding, err := r.requireHolding(tx, tx.Symbol)
+	if err != nil {
+		return err
+	}
+	soldCost := holding.AvgCostPrice.Mul(tx.Quantity)
+	if err := holding.RemoveShares(tx.Quantity, soldCost); err != nil {
+		return fmt.Errorf("%w: transaction %s sells %s shares of %s but only %s are held",
+			models.ErrLedgerReplayFailed, tx.ID, tx.Quantity, tx.Symbol, holding.Quantity)
+	}
+	r.totals.Realized = r.totals.Realized.Add(tx.GetProceeds().Sub(soldCost))
+
+	lots := r.taxLots[tx.Symbol]
+	sortTaxLots(lots, r.method)
+	remaining := tx.Quantity
+	open := lots[:0]
+	for _, lot := range lots {
+		switch {
+		case remaining.IsZero():
+			open = append(open, lot)
+		case lot.Quantity.LessThanOrEqual(remaining):
+			remaining = remaining.Sub(lot.Quantity)
+		default:
+			lot.CostBasis = lot.CostBasis.Sub(lot.GetCostPerShare().Mul(remaining))
+			lot.Quantity = lot.Quantity.Sub(remaining)
+			remaining = decimal.Zero
+			open = append(open, lot)
+		}
+	}
+	r.taxLots[tx.Symbol] = open
+
+	if holding.Quantity.IsZero() {
+		r.remove(tx.Symbol)
+	}
+	return nil
+}
+
+// closingLots returns the lots sell would close for a sale, in the order it closes them, and
+// the quantity it takes from each
+func (r *ledgerReplay) closingLots(tx *models.Transaction) ([]*models.TaxLot, []decimal.Decimal) {
+	lots := r.taxLots[tx.Symbol]
+	sortTaxLots(lots, r.method)
+
+	var sold []*models.TaxLot
+	var quantities []decimal.Decimal
+	remaining := tx.Quantity
+	for _, lot := range lots {
+		if remaining.IsZero() {
+			break
+		}
+		quantity := decimal.Min(lot.Quantity, remaining)
+		sold = append(sold, lot)
+		quantities = append(quantities, quantity)
+		remaining = remaining.Sub(quantity)
+	}
+	return sold, quantities
+}
+
+// saleGains returns the gains a sale realizes on the lots sell would close, in the order of
+// closingLots, splitting its proceeds across them by quantity. The lots themselves are left open.
+func (r *ledgerReplay) saleGains(tx *models.Transaction) []*RealizedGain {
+	sold, quantities := r.closingLots(tx)
+
+	proceeds := r.rounding.AllocateAmount(tx.GetProceeds(), quantities)
+	gains := make([]*RealizedGain, len(sold))
+	for i, lot := range sold {
+		costBasis := lot.CostBasis
+		if !quantities[i].Equal(lot.Quantity) {
+			costBasis = r.rounding.RoundAmount(lot.GetCostPerShare().Mul(quantities[i]))
+		}
+		gains[i] = &RealizedGain{
+			Symbol:       tx.Symbol,
+			PurchaseDate: lot.PurchaseDate,
+			SaleDate:     tx.Date,
+			Quantity:     quantities[i],
+			CostBasis:    costBasis,
+			Proceeds:     proceeds[i],
+			Gain:         proceeds[i].Sub(costBasis),
+			IsLongTerm:   lot.IsLongTerm(tx.Date),
+		}
+	}
+	return gains
+}
+
+// split adds the shares a split or stock dividend produced while keeping the cost basis
+// unchanged
+func (r *ledgerReplay) split(tx *models.Transaction) error {
+	holding, err := r.requireHolding(tx, tx.Symbol)
+	if err != nil {
+		return err
+	}
+
+	oldQuantity := holding.Quantity
+	newQuantity := holding.Quantity.Add(tx.Quantity)
+	holding.Quantity = newQuantity
+	holding.CalculateAvgCostPrice()
+
+	lots := r.taxLots[tx.Symbol]
+	quantities := r.allocateLotQuantities(lots, tx.Symbol, func(total decimal.Decimal) decimal.Decimal {
+		return total.Mul(newQuantity).Div(oldQuantity)
+	})
+	for i, lot := range lots {
+		lot.Quantity = quantities[i]
+	}
+	return nil
+}
+
+// merger converts the whole source position into the acquiring symbol, carrying cost
+// basis and purchase dates over
+func (r *ledgerReplay) merger(tx *models.Transaction) error {
+	oldSymbol, ratio, err := parseCorporateActionNote(tx, mergerNotePattern)
+	if err != nil {
+		return err
+	}
+	oldHolding, err := r.requireHolding(tx, oldSymbol)
+	if err != nil {
+		return err
+	}
+
+	assetType := models.AssetTypeForSymbol(tx.Symbol)
+	r.holding(tx.Symbol).AddShares(r.rounding.RoundQuantity(assetType, oldHolding.Quantity.Mul(ratio)), oldHolding.CostBasis)
+	lots := r.taxLots[oldSymbol]
+	quantities := r.allocateLotQuantities(lots, tx.Symbol, func(total decimal.Decimal) decimal.Decimal {
+		return total.Mul(ratio)
+	})
+	for i, lot := range lots {
+		lot.Symbol = tx.Symbol
+		lot.Quantity = quantities[i]
+		r.taxLots[tx.Symbol] = append(r.taxLots[tx.Symbol], lot)
+	}
+	delete(r.taxLots, oldSymbol)
+	r.remove(oldSymbol)
+	return nil
+}
+
+// spinoff distributes new shares against the parent position and moves part of the
+// parent's cost basis to them, lot by lot
+func (r *ledgerReplay) spinoff(tx *models.Transaction) error {
+	parentSymbol, ratio, err := parseCorporateActionNote(tx, spinoffNotePattern)
+	if err != nil {
+		return err
+	}
+	allocation, err := parseSpinoffAllocation(tx)
+	if err != nil {
+		return err
+	}
+	parent, err := r.requireHolding(tx, parentSymbol)
+	if err != nil {
+		return err
+	}
+
+	spinoffQuantity := r.rounding.RoundQuantity(models.AssetTypeForSymbol(tx.Symbol), parent.Quantity.Mul(ratio))
+	spinoffCostBasis := r.rounding.RoundAmount(parent.CostBasis.Mul(allocation))
+	r.holding(tx.Symbol).AddShares(spinoffQuantity, spinoffCostBasis)
+	parent.CostBasis = parent.CostBasis.Sub(spinoffCostBasis)
+	parent.CalculateAvgCostPrice()
+
+	parentLots := r.taxLots[parentSymbol]
+	weights := make([]decimal.Decimal, len(parentLots))
+	for i, lot := range parentLots {
+		weights[i] = lot.Quantity
+	}
+	quantities := r.rounding.AllocateQuantity(models.AssetTypeForSymbol(tx.Symbol), spinoffQuantity, weights)
+	costBases := r.rounding.AllocateAmount(spinoffCostBasis, weights)
+	for i, parentLot := range parentLots {
+		r.taxLots[tx.Symbol] = append(r.taxLots[tx.Symbol], &models.TaxLot{
+			PortfolioID:   r.portfolioID,
+			Symbol:        tx.Symbol,
+			PurchaseDate:  parentLot.PurchaseDate,
+			Quantity:      quantities[i],
+			CostBasis:     costBases[i],
+			TransactionID: parentLot.TransactionID,
+		})
+		parentLot.CostBasis = parentLot.CostBasis.Sub(costBases[i])
+	}
+	return nil
+}
+
+// returnOfCapital lowers the cost basis of a position's lots by a distribution, realizing
+// whatever exceeds a lot's basis as a gain
+func (r *ledgerReplay) returnOfCapital(tx *models.Transaction) error {
+	holding, err := r.requireHolding(tx, tx.Symbol)
+	if err != nil {
+		return err
+	}
+	_, gains := returnCapital(r.rounding, holding, r.taxLots[tx.Symbol], tx.Quantity, tx.Date)
+	r.gains = append(r.gains, gains...)
+	for _, gain := range gains {
+		r.totals.Realized = r.totals.Realized.Add(gain.Gain)
+	}
+	return nil
+}
+
+// allocateLotQuantities converts lots into shares of symbol, rounding the converted total
+// once and splitting it across the lots in proportion to their quantities
+func (r *ledgerReplay) allocateLotQuantities(lots []*models.TaxLot, symbol string, convert func(decimal.Decimal) decimal.Decimal) []decimal.Decimal {
+	assetType := models.AssetTypeForSymbol(symbol)
+	weights := make([]decimal.Decimal, len(lots))
+	total := decimal.Zero
+	for i, lot := range lots {
+		weights[i] = lot.Quantity
+		total = total.Add(lot.Quantity)
+	}
+	return r.rounding.AllocateQuantity(assetType, r.rounding.RoundQuantity(assetType, convert(total)), weights)
+}
+
+// holding returns the replayed holding for a symbol, opening an empty one if needed
+func (r *ledgerReplay) holding(symbol string) *models.Holding {
+	holding, ok := r.holdings[symbol]
+	if !ok {
+		holding = &models.Holding{
+			PortfolioID:  r.portfolioID,
+			Symbol:       symbol,
+			Quantity:     decimal.Zero,
+			CostBasis:    decimal.Zero,
+			AvgCostPrice: decimal.Zero,
+		}
+		r.holdings[symbol] = holding
+	}
+	return holding
+}
+
+// requireHolding returns the replayed holding a transaction depends on
+func (r *ledgerReplay) requireHolding(tx *models.Transaction, symbol string) (*models.Holding, error) {
+	holding, ok := r.holdings[symbol]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s transaction %s on %s requires a position in %s, but none is held",
+			models.ErrLedgerReplayFailed, tx.Type, tx.ID, tx.Date.Format("2006-01-02"), symbol)
+	}
+	return holding, nil
+}
+
+// remove closes a position
+func (r *ledgerReplay) remove(symbol string) {
+	delete(r.holdings, symbol)
+	delete(r.taxLots, symbol)
+}
+
+// results returns the rebuilt holdings and tax lots, ordered by symbol
+func (r *ledgerReplay) results() ([]*models.Holding, []*models.TaxLot) {
+	symbols := make([]string, 0, len(r.holdings))
+	for symbol := range r.holdings {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	holdings := make([]*models.Holding, 0, len(symbols))
+	var taxLots []*models.TaxLot
+	for _, symbol := range symbols {
+		holdings = append(holdings, r.holdings[symbol])
+		lots := r.taxLots[symbol]
+		sortTaxLots(lots, models.CostBasisFIFO)
+		taxLots = append(taxLots, lots...)
+	}
+	return holdings, taxLots
+}
+
+// parseCorporateActionNote recovers the source symbol and ratio of a merger or spinoff
+func parseCorporateActionNote(tx *models.Transaction, pattern *regexp.Regexp) (string, decimal.Decimal, error) {
+	matches := pattern.FindStringSubmatch(tx.Notes)
+	if matches == nil {
+		return "", decimal.Zero, fmt.Errorf("%w: %s transaction %s does not record its source symbol and ratio",
+			models.ErrLedgerReplayFailed, tx.Type, tx.ID)
+	}
+	ratio, err := decimal.NewFromString(matches[2])
+	if err != nil || !ratio.IsPositive() {
+		return "", decimal.Zero, fmt.Errorf("%w: %s transaction %s has an invalid ratio %q",
+			models.ErrLedgerReplayFailed, tx.Type, tx.ID, matches[2])
+	}
+	return matches[1], ratio, nil
+}
+
+// parseSpinoffAllocation recovers the share of cost basis a spinoff moved from its parent
+func parseSpinoffAllocation(tx *models.Transaction) (decimal.Decimal, error) {
+	matches := spinoffNotePattern.FindStringSubmatch(tx.Notes)
+	if matches == nil || matches[3] == "" {
+		return models.DefaultSpinoffCostBasisAllocation, nil
+	}
+	allocation, err := decimal.NewFromString(matches[3])
+	if err != nil || models.ValidateCostBasisAllocation(allocation) != nil {
+		return decimal.Zero, fmt.Errorf("%w: %s transaction %s has an invalid cost basis allocation %q",
+			models.ErrLedgerReplayFailed, tx.Type, tx.ID, matches[3])
+	}
+	return allocation, nil
+}
@@ -2,14 +2,49 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/shopspring/decimal"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 
+	"github.com/lenon/portfolios/internal/cache"
 	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
 )
 
+// quoteCachePrefix is the cache key prefix for quotes
+const quoteCachePrefix = "quote:"
+
+// staleQuoteCachePrefix is the cache key prefix for the last quote fetched for each symbol,
+// which is served while the provider's circuit breaker is open
+const staleQuoteCachePrefix = "stale_quote:"
+
+// staleQuoteTTL is how long the last quote fetched for a symbol is kept. It outlives any
+// outage the breaker is expected to ride out, and is kept when quotes are cleared.
+const staleQuoteTTL = 7 * 24 * time.Hour
+
+// symbolSearchCachePrefix is the cache key prefix for symbol search results
+const symbolSearchCachePrefix = "symbol_search:"
+
+// symbolSearchTTL is how long symbol search results are kept. Listings change rarely, and
+// searches are typed a few characters at a time, so they are kept much longer than quotes.
+const symbolSearchTTL = 24 * time.Hour
+
+// tradingStatusCachePrefix is the cache key prefix for symbol trading statuses
+const tradingStatusCachePrefix = "trading_status:"
+
+// tradingStatusTTL is how long a trading status is kept. It outlives the daily price update
+// job that refreshes it, and is kept when quotes are cleared, so restrictions stay known
+// between refreshes.
+const tradingStatusTTL = 36 * time.Hour
+
 // Quote is an alias for dto.Quote for backward compatibility
 type Quote = dto.Quote
 
@@ -34,145 +69,705 @@ type MarketDataProvider interface {
 	IsAvailable() bool
 }
 
+// SymbolSearchProvider is implemented by market data providers that can look up securities
+// by symbol or name
+type SymbolSearchProvider interface {
+	// SearchSymbols finds securities whose symbol or name matches keywords, best match first
+	SearchSymbols(ctx context.Context, keywords string) ([]*models.Security, error)
+}
+
+// IntradayPriceProvider is implemented by market data providers that can return bars
+// shorter than a trading day
+type IntradayPriceProvider interface {
+	// GetIntradayPrices retrieves bars of an intraday resolution for a symbol
+	GetIntradayPrices(ctx context.Context, symbol string, resolution models.PriceResolution, startDate, endDate time.Time) ([]*HistoricalPrice, error)
+}
+
+// OptionQuoteProvider is implemented by market data providers that can price listed option
+// contracts, given by their OCC symbols
+type OptionQuoteProvider interface {
+	// GetOptionQuote retrieves the latest price per share of an option contract
+	GetOptionQuote(ctx context.Context, symbol string) (*Quote, error)
+}
+
 // MarketDataService provides market data operations with caching
 type MarketDataService interface {
 	// GetQuote retrieves a quote, using cache if available
-	GetQuote(symbol string) (*Quote, error)
+	GetQuote(ctx context.Context, symbol string) (*Quote, error)
 
 	// GetQuotes retrieves multiple quotes
-	GetQuotes(symbols []string) (map[string]*Quote, error)
+	GetQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error)
 
 	// GetHistoricalPrices retrieves historical prices
-	GetHistoricalPrices(symbol string, startDate, endDate time.Time) ([]*HistoricalPrice, error)
+	GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*HistoricalPrice, error)
+
+	// GetPriceHistory retrieves a symbol's bars of the given resolution, oldest first
+	GetPriceHistory(ctx context.Context, symbol string, resolution models.PriceResolution, startDate, endDate time.Time) ([]*HistoricalPrice, error)
 
 	// GetExchangeRate retrieves an exchange rate
-	GetExchangeRate(fromCurrency, toCurrency string) (decimal.Decimal, error)
+	GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error)
 
 	// RefreshCache forces a refresh of cached data
-	RefreshCache(symbol string) error
+	RefreshCache(ctx context.Context, symbol string) error
+
+	// ClearCache clears all cached quotes
+	ClearCache(ctx context.Context)
+
+	// SearchSymbols finds securities whose symbol or name matches keywords, using cache if
+	// available
+	SearchSymbols(ctx context.Context, keywords string) ([]*models.Security, error)
 
-	// ClearCache clears all cached data
-	ClearCache()
+	// GetQuotaStatus returns the provider's current request budget
+	GetQuotaStatus() *QuotaStatus
+
+	// RefreshTradingStatuses fetches fresh quotes for symbols, updating their trading
+	// statuses, and returns the statuses of those that are halted or suspended
+	RefreshTradingStatuses(ctx context.Context, symbols []string) (map[string]*dto.SymbolTradingStatus, error)
+
+	// GetQuoteFetchTimes returns when the last quote of each of symbols was fetched from the
+	// provider, leaving out symbols without one. It never calls the provider.
+	GetQuoteFetchTimes(ctx context.Context, symbols []string) map[string]time.Time
+
+	TradingRestrictionLookup
 }
 
 // marketDataService implements MarketDataService with caching
 type marketDataService struct {
-	provider   MarketDataProvider
-	cache      map[string]*cachedQuote
-	cacheTTL   time.Duration
-	defaultCtx context.Context
-}
-
-// cachedQuote represents a cached quote with expiration
-type cachedQuote struct {
-	quote     *Quote
-	fetchedAt time.Time
+	provider MarketDataProvider
+	crypto   MarketDataProvider // prices coin pairs when set; nil sends them to provider
+	cache    cache.Store
+	cacheTTL time.Duration
+	quota    *QuotaTracker
+	inflight singleflight.Group
+	// concurrency is how many quotes a multi-symbol request fetches at once; at most one
+	// sends the symbols to the provider as a batch
+	concurrency int
+	// breaker and cryptoBreaker stop calling provider and crypto while they keep failing;
+	// nil breakers never open
+	breaker       *CircuitBreaker
+	cryptoBreaker *CircuitBreaker
 }
 
 // NewMarketDataService creates a new MarketDataService with the specified provider
+// and an in-memory quote cache
 func NewMarketDataService(provider MarketDataProvider, cacheTTL time.Duration) MarketDataService {
+	return NewMarketDataServiceWithCache(provider, cache.NewMemoryStore(), cacheTTL)
+}
+
+// NewMarketDataServiceWithCache creates a new MarketDataService backed by the given cache store.
+// Use a shared store (e.g. Redis) when running multiple API replicas.
+func NewMarketDataServiceWithCache(provider MarketDataProvider, store cache.Store, cacheTTL time.Duration) MarketDataService {
+	return NewMarketDataServiceWithQuota(provider, store, NewQuotaTracker("", 0, 0), cacheTTL)
+}
+
+// NewMarketDataServiceWithQuota creates a new MarketDataService that spends provider
+// requests from the given quota tracker and fails fast once the budget is exhausted
+func NewMarketDataServiceWithQuota(provider MarketDataProvider, store cache.Store, quota *QuotaTracker, cacheTTL time.Duration) MarketDataService {
+	return NewMarketDataServiceWithCrypto(provider, nil, store, quota, cacheTTL)
+}
+
+// NewMarketDataServiceWithCrypto creates a new MarketDataService that prices coin pairs
+// such as BTC-USD with the crypto provider and everything else with provider. Only requests
+// to provider are spent from the quota; the crypto provider has rate limits of its own.
+func NewMarketDataServiceWithCrypto(provider, crypto MarketDataProvider, store cache.Store, quota *QuotaTracker, cacheTTL time.Duration) MarketDataService {
+	return NewMarketDataServiceWithConcurrency(provider, crypto, store, quota, cacheTTL, 1)
+}
+
+// NewMarketDataServiceWithConcurrency creates a MarketDataService that fetches up to
+// concurrency quotes at once for multi-symbol requests, rather than sending the symbols to
+// the provider as one batch. The requests are still spent from the quota before any is made,
+// so the provider's rate limits hold however many run at once.
+func NewMarketDataServiceWithConcurrency(
+	provider, crypto MarketDataProvider,
+	store cache.Store,
+	quota *QuotaTracker,
+	cacheTTL time.Duration,
+	concurrency int,
+) MarketDataService {
+	return NewMarketDataServiceWithBreakers(provider, crypto, store, quota, cacheTTL, concurrency, nil, nil)
+}
+
+// NewMarketDataServiceWithBreakers creates a MarketDataService that calls provider and crypto
+// through circuit breakers. While a provider's breaker is open, requests needing it fail fast
+// with models.ErrMarketDataUnavailable instead of waiting for a timeout, and quotes are served
+// from the last ones fetched, flagged as stale. Either breaker may be nil to never open.
+func NewMarketDataServiceWithBreakers(
+	provider, crypto MarketDataProvider,
+	store cache.Store,
+	quota *QuotaTracker,
+	cacheTTL time.Duration,
+	concurrency int,
+	breaker, cryptoBreaker *CircuitBreaker,
+) MarketDataService {
 	return &marketDataService{
-		provider:   provider,
-		cache:      make(map[string]*cachedQuote),
-		cacheTTL:   cacheTTL,
-		defaultCtx: context.Background(),
+		provider:      provider,
+		crypto:        crypto,
+		cache:         store,
+		cacheTTL:      cacheTTL,
+		quota:         quota,
+		concurrency:   concurrency,
+		breaker:       breaker,
+		cryptoBreaker: cryptoBreaker,
 	}
 }
 
-// GetQuote retrieves a quote with caching
-func (s *marketDataService) GetQuote(symbol string) (*Quote, error) {
-	// Check cache first
-	if cached, exists := s.cache[symbol]; exists {
-		if time.Since(cached.fetchedAt) < s.cacheTTL {
-			return cached.quote, nil
+// isCrypto returns true if symbol is priced by the crypto provider
+func (s *marketDataService) isCrypto(symbol string) bool {
+	return s.crypto != nil && models.AssetTypeForSymbol(symbol) == models.AssetTypeCrypto
+}
+
+// providerFor returns the provider that prices symbol
+func (s *marketDataService) providerFor(symbol string) MarketDataProvider {
+	if s.isCrypto(symbol) {
+		return s.crypto
+	}
+	return s.provider
+}
+
+// breakerFor returns the circuit breaker of the provider that prices symbol
+func (s *marketDataService) breakerFor(symbol string) *CircuitBreaker {
+	if s.isCrypto(symbol) {
+		return s.cryptoBreaker
+	}
+	return s.breaker
+}
+
+// fetchQuote fetches a quote for symbol from the provider that prices it. Option contracts
+// are priced by the provider's option quotes, when it has them.
+func (s *marketDataService) fetchQuote(ctx context.Context, symbol string) (*Quote, error) {
+	if !models.IsOptionSymbol(symbol) {
+		return s.providerFor(symbol).GetQuote(ctx, symbol)
+	}
+
+	options, ok := s.provider.(OptionQuoteProvider)
+	if !ok {
+		return nil, fmt.Errorf("market data provider has no option quotes")
+	}
+	return options.GetOptionQuote(ctx, symbol)
+}
+
+// acquire spends requests for symbol from the quota, unless the crypto provider prices it
+func (s *marketDataService) acquire(symbol string) error {
+	if s.isCrypto(symbol) {
+		return nil
+	}
+	return s.quota.Acquire(1)
+}
+
+// getCachedQuote returns a cached quote for the symbol, or nil if none is cached
+func (s *marketDataService) getCachedQuote(ctx context.Context, symbol string) *Quote {
+	return s.getQuoteFromCache(ctx, quoteCachePrefix+symbol)
+}
+
+// getStaleQuote returns the last quote fetched for the symbol, flagged as stale, or nil if
+// there is none
+func (s *marketDataService) getStaleQuote(ctx context.Context, symbol string) *Quote {
+	quote := s.getQuoteFromCache(ctx, staleQuoteCachePrefix+symbol)
+	if quote != nil {
+		quote.Stale = true
+	}
+	return quote
+}
+
+// addStaleQuotes adds the last quotes fetched for symbols to result. Symbols without one are
+// left out, like symbols a provider can't price.
+func (s *marketDataService) addStaleQuotes(ctx context.Context, symbols []string, result map[string]*Quote) {
+	for _, symbol := range symbols {
+		if quote := s.getStaleQuote(ctx, symbol); quote != nil {
+			result[symbol] = quote
 		}
 	}
+}
 
-	// Fetch from provider
-	ctx, cancel := context.WithTimeout(s.defaultCtx, 10*time.Second)
-	defer cancel()
+// getQuoteFromCache returns the quote cached under key, or nil if there is none
+func (s *marketDataService) getQuoteFromCache(ctx context.Context, key string) *Quote {
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return nil
+	}
+
+	var quote Quote
+	if err := json.Unmarshal(data, &quote); err != nil {
+		return nil
+	}
+
+	return &quote
+}
 
-	quote, err := s.provider.GetQuote(ctx, symbol)
+// cacheQuote stores a quote in the cache; failures are ignored since the cache is best-effort
+func (s *marketDataService) cacheQuote(ctx context.Context, symbol string, quote *Quote) {
+	if quote.FetchedAt.IsZero() {
+		quote.FetchedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(quote)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch quote for %s: %w", symbol, err)
+		return
 	}
+	_ = s.cache.Set(ctx, quoteCachePrefix+symbol, data, s.cacheTTL)
+	_ = s.cache.Set(ctx, staleQuoteCachePrefix+symbol, data, staleQuoteTTL)
+	s.cacheTradingStatus(ctx, symbol, quote)
+}
 
-	// Cache the result
-	s.cache[symbol] = &cachedQuote{
-		quote:     quote,
-		fetchedAt: time.Now(),
+// cacheTradingStatus records the trading status reported with a quote. Quotes from
+// providers that don't report one leave the last known status in place.
+func (s *marketDataService) cacheTradingStatus(ctx context.Context, symbol string, quote *Quote) {
+	if quote.TradingStatus == "" {
+		return
 	}
 
-	return quote, nil
+	status := &dto.SymbolTradingStatus{
+		Symbol:    symbol,
+		Status:    quote.TradingStatus,
+		CheckedAt: time.Now().UTC(),
+	}
+	if !quote.LatestTradingDay.IsZero() {
+		latestTradingDay := quote.LatestTradingDay
+		status.LatestTradingDay = &latestTradingDay
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	_ = s.cache.Set(ctx, tradingStatusCachePrefix+symbol, data, tradingStatusTTL)
 }
 
-// GetQuotes retrieves multiple quotes
-func (s *marketDataService) GetQuotes(symbols []string) (map[string]*Quote, error) {
+// GetQuote retrieves a quote with caching.
+// Concurrent requests for the same uncached symbol share a single provider request. The
+// shared request is not canceled with ctx, since other callers may still be waiting on it,
+// but GetQuote stops waiting for it as soon as ctx is done. While the provider's circuit
+// breaker is open, the last quote fetched for the symbol is returned, flagged as stale.
+func (s *marketDataService) GetQuote(ctx context.Context, symbol string) (*Quote, error) {
+	// Check cache first
+	if cached := s.getCachedQuote(ctx, symbol); cached != nil {
+		return cached, nil
+	}
+
+	fetchCtx := context.WithoutCancel(ctx)
+	inflight := s.inflight.DoChan(quoteCachePrefix+symbol, func() (interface{}, error) {
+		// Fetch from provider
+		ctx, cancel := context.WithTimeout(fetchCtx, 10*time.Second)
+		defer cancel()
+
+		quote, err := callThrough(s.breakerFor(symbol), func() (*Quote, error) {
+			if err := s.acquire(symbol); err != nil {
+				return nil, err
+			}
+			return s.fetchQuote(ctx, symbol)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Cache the result
+		s.cacheQuote(ctx, symbol, quote)
+
+		return quote, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to fetch quote for %s: %w", symbol, ctx.Err())
+	case result := <-inflight:
+		if errors.Is(result.Err, errCircuitOpen) {
+			if stale := s.getStaleQuote(ctx, symbol); stale != nil {
+				return stale, nil
+			}
+		}
+		if result.Err != nil {
+			return nil, fmt.Errorf("failed to fetch quote for %s: %w", symbol, result.Err)
+		}
+		return result.Val.(*Quote), nil
+	}
+}
+
+// GetQuotes retrieves multiple quotes. While a provider's circuit breaker is open, the
+// symbols it prices are served from the last quotes fetched for them, flagged as stale.
+func (s *marketDataService) GetQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
 	result := make(map[string]*Quote)
 	uncachedSymbols := []string{}
+	seen := make(map[string]bool, len(symbols))
 
-	// Check cache for each symbol
+	// Check cache for each symbol, skipping duplicates so each is only fetched once
 	for _, symbol := range symbols {
-		if cached, exists := s.cache[symbol]; exists {
-			if time.Since(cached.fetchedAt) < s.cacheTTL {
-				result[symbol] = cached.quote
-				continue
-			}
+		if seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+
+		if cached := s.getCachedQuote(ctx, symbol); cached != nil {
+			result[symbol] = cached
+			continue
 		}
 		uncachedSymbols = append(uncachedSymbols, symbol)
 	}
 
-	// Fetch uncached symbols
-	if len(uncachedSymbols) > 0 {
-		ctx, cancel := context.WithTimeout(s.defaultCtx, 30*time.Second)
-		defer cancel()
+	// Coin pairs go to the crypto provider in a batch of their own, and option contracts
+	// are fetched one at a time
+	var equitySymbols, cryptoSymbols, optionSymbols []string
+	for _, symbol := range uncachedSymbols {
+		switch {
+		case s.isCrypto(symbol):
+			cryptoSymbols = append(cryptoSymbols, symbol)
+		case models.IsOptionSymbol(symbol):
+			optionSymbols = append(optionSymbols, symbol)
+		default:
+			equitySymbols = append(equitySymbols, symbol)
+		}
+	}
 
-		quotes, err := s.provider.GetQuotes(ctx, uncachedSymbols)
-		if err != nil {
+	// Fetch uncached symbols. Each breaker that lets its provider's symbols through must hear
+	// how their requests went.
+	if len(equitySymbols)+len(optionSymbols) > 0 {
+		if err := s.breaker.Allow(); err != nil {
+			s.addStaleQuotes(ctx, append(equitySymbols, optionSymbols...), result)
+			equitySymbols, optionSymbols = nil, nil
+		} else if err := s.quota.Acquire(len(equitySymbols) + len(optionSymbols)); err != nil {
+			s.breaker.Record(err)
 			return nil, fmt.Errorf("failed to fetch quotes: %w", err)
 		}
+	}
+	if len(cryptoSymbols) > 0 {
+		if err := s.cryptoBreaker.Allow(); err != nil {
+			s.addStaleQuotes(ctx, cryptoSymbols, result)
+			cryptoSymbols = nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if s.concurrency > 1 {
+		if err := s.fetchQuotesConcurrently(ctx, append(equitySymbols, optionSymbols...), cryptoSymbols, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	if len(equitySymbols)+len(optionSymbols) > 0 {
+		var attempts providerAttempts
+		err := s.fetchQuotes(ctx, s.provider, equitySymbols, result)
+		if len(equitySymbols) > 0 {
+			attempts.add(err)
+		}
 
-		// Cache and add to result
-		for symbol, quote := range quotes {
-			s.cache[symbol] = &cachedQuote{
-				quote:     quote,
-				fetchedAt: time.Now(),
+		// Like batch quotes, options that can't be priced are left out of the result
+		for _, symbol := range optionSymbols {
+			if err != nil {
+				break
 			}
+			quote, err := s.fetchQuote(ctx, symbol)
+			attempts.add(err)
+			if err != nil {
+				continue
+			}
+			s.cacheQuote(ctx, symbol, quote)
 			result[symbol] = quote
 		}
+
+		s.breaker.Record(attempts.result())
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(cryptoSymbols) > 0 {
+		err := s.fetchQuotes(ctx, s.crypto, cryptoSymbols, result)
+		s.cryptoBreaker.Record(err)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return result, nil
 }
 
+// fetchQuotesConcurrently fetches quotes for symbols one at a time, up to s.concurrency at
+// once, alongside the batch of coin pairs for the crypto provider, caching them and adding
+// them to result. Like batch quotes, symbols that can't be priced are left out of the result.
+func (s *marketDataService) fetchQuotesConcurrently(ctx context.Context, symbols, cryptoSymbols []string, result map[string]*Quote) error {
+	var mu sync.Mutex
+	var attempts providerAttempts
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(s.concurrency)
+
+	if len(cryptoSymbols) > 0 {
+		g.Go(func() error {
+			quotes, err := s.crypto.GetQuotes(gctx, cryptoSymbols)
+			s.cryptoBreaker.Record(err)
+			if err != nil {
+				return fmt.Errorf("failed to fetch quotes: %w", err)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for symbol, quote := range quotes {
+				s.cacheQuote(gctx, symbol, quote)
+				result[symbol] = quote
+			}
+			return nil
+		})
+	}
+	for _, symbol := range symbols {
+		g.Go(func() error {
+			quote, err := s.fetchQuote(gctx, symbol)
+			attempts.add(err)
+			if err != nil {
+				return nil
+			}
+			s.cacheQuote(gctx, symbol, quote)
+			mu.Lock()
+			defer mu.Unlock()
+			result[symbol] = quote
+			return nil
+		})
+	}
+
+	err := g.Wait()
+	if len(symbols) > 0 {
+		s.breaker.Record(attempts.result())
+	}
+	if err != nil {
+		return err
+	}
+	// A request that ran out of time fails rather than returning the quotes it got
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to fetch quotes: %w", err)
+	}
+	return nil
+}
+
+// fetchQuotes fetches quotes for symbols from provider, caching them and adding them to result
+func (s *marketDataService) fetchQuotes(ctx context.Context, provider MarketDataProvider, symbols []string, result map[string]*Quote) error {
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	quotes, err := provider.GetQuotes(ctx, symbols)
+	if err != nil {
+		return fmt.Errorf("failed to fetch quotes: %w", err)
+	}
+
+	// Cache and add to result
+	for symbol, quote := range quotes {
+		s.cacheQuote(ctx, symbol, quote)
+		result[symbol] = quote
+	}
+	return nil
+}
+
 // GetHistoricalPrices retrieves historical prices (no caching for historical data)
-func (s *marketDataService) GetHistoricalPrices(symbol string, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
-	ctx, cancel := context.WithTimeout(s.defaultCtx, 30*time.Second)
+func (s *marketDataService) GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+	if models.IsOptionSymbol(symbol) {
+		return nil, fmt.Errorf("historical prices are not available for option %s", symbol)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return callThrough(s.breakerFor(symbol), func() ([]*HistoricalPrice, error) {
+		if err := s.acquire(symbol); err != nil {
+			return nil, fmt.Errorf("failed to fetch historical prices for %s: %w", symbol, err)
+		}
+		return s.providerFor(symbol).GetHistoricalPrices(ctx, symbol, startDate, endDate)
+	})
+}
+
+// GetPriceHistory retrieves a symbol's bars of the given resolution, oldest first. Daily and
+// weekly bars are built from historical prices; intraday bars come from the provider's
+// intraday prices, when it has them, over at most MaxIntradayRangeDays.
+func (s *marketDataService) GetPriceHistory(ctx context.Context, symbol string, resolution models.PriceResolution, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+	if resolution == "" {
+		resolution = models.PriceResolution1Day
+	}
+	if !resolution.IsValid() {
+		return nil, models.ErrInvalidPriceResolution
+	}
+
+	var prices []*HistoricalPrice
+	var err error
+	if resolution.IsIntraday() {
+		prices, err = s.getIntradayPrices(ctx, symbol, resolution, startDate, endDate)
+	} else {
+		prices, err = s.GetHistoricalPrices(ctx, symbol, startDate, endDate)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Date.Before(prices[j].Date)
+	})
+	if resolution == models.PriceResolution1Week {
+		return weeklyBars(prices), nil
+	}
+	return prices, nil
+}
+
+// getIntradayPrices retrieves intraday bars from the provider. Coin pairs and option
+// contracts have no intraday prices.
+func (s *marketDataService) getIntradayPrices(ctx context.Context, symbol string, resolution models.PriceResolution, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+	if endDate.Sub(startDate) > models.MaxIntradayRangeDays*24*time.Hour {
+		return nil, models.ErrIntradayRangeTooLong
+	}
+
+	intraday, ok := s.provider.(IntradayPriceProvider)
+	if !ok || s.isCrypto(symbol) || models.IsOptionSymbol(symbol) {
+		return nil, fmt.Errorf("intraday prices are not available for %s: %w", symbol, models.ErrMarketDataUnavailable)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	return s.provider.GetHistoricalPrices(ctx, symbol, startDate, endDate)
+	return callThrough(s.breaker, func() ([]*HistoricalPrice, error) {
+		if err := s.quota.Acquire(1); err != nil {
+			return nil, fmt.Errorf("failed to fetch intraday prices for %s: %w", symbol, err)
+		}
+		return intraday.GetIntradayPrices(ctx, symbol, resolution, startDate, endDate)
+	})
+}
+
+// weeklyBars combines daily bars, oldest first, into one bar per week starting on Monday.
+// Each weekly bar is dated on the week's first trading day.
+func weeklyBars(daily []*HistoricalPrice) []*HistoricalPrice {
+	var weeks []*HistoricalPrice
+	var weekStart time.Time
+	for _, day := range daily {
+		start := day.Date.AddDate(0, 0, -(int(day.Date.Weekday())+6)%7)
+		start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+		last := len(weeks) - 1
+		if last < 0 || !start.Equal(weekStart) {
+			week := *day
+			weeks = append(weeks, &week)
+			weekStart = start
+			continue
+		}
+
+		week := weeks[last]
+		week.High = decimal.Max(week.High, day.High)
+		week.Low = decimal.Min(week.Low, day.Low)
+		week.Close = day.Close
+		week.AdjClose = day.AdjClose
+		week.Volume += day.Volume
+	}
+	return weeks
 }
 
 // GetExchangeRate retrieves an exchange rate
-func (s *marketDataService) GetExchangeRate(fromCurrency, toCurrency string) (decimal.Decimal, error) {
+func (s *marketDataService) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error) {
 	if fromCurrency == toCurrency {
 		return decimal.NewFromInt(1), nil
 	}
 
-	ctx, cancel := context.WithTimeout(s.defaultCtx, 10*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	return s.provider.GetExchangeRate(ctx, fromCurrency, toCurrency)
+	return callThrough(s.breaker, func() (decimal.Decimal, error) {
+		if err := s.quota.Acquire(1); err != nil {
+			return decimal.Zero, fmt.Errorf("failed to fetch exchange rate: %w", err)
+		}
+		return s.provider.GetExchangeRate(ctx, fromCurrency, toCurrency)
+	})
+}
+
+// SearchSymbols finds securities whose symbol or name matches keywords with the provider's
+// symbol search. Results are cached by the upper-cased keywords.
+func (s *marketDataService) SearchSymbols(ctx context.Context, keywords string) ([]*models.Security, error) {
+	search, ok := s.provider.(SymbolSearchProvider)
+	if !ok {
+		return nil, fmt.Errorf("market data provider has no symbol search: %w", models.ErrMarketDataUnavailable)
+	}
+
+	key := symbolSearchCachePrefix + strings.ToUpper(keywords)
+	if data, err := s.cache.Get(ctx, key); err == nil {
+		var securities []*models.Security
+		if err := json.Unmarshal(data, &securities); err == nil {
+			return securities, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	securities, err := callThrough(s.breaker, func() ([]*models.Security, error) {
+		if err := s.quota.Acquire(1); err != nil {
+			return nil, err
+		}
+		return search.SearchSymbols(ctx, keywords)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search symbols: %w", err)
+	}
+
+	if data, err := json.Marshal(securities); err == nil {
+		_ = s.cache.Set(ctx, key, data, symbolSearchTTL)
+	}
+	return securities, nil
 }
 
 // RefreshCache forces a refresh of cached data for a symbol
-func (s *marketDataService) RefreshCache(symbol string) error {
-	delete(s.cache, symbol)
-	_, err := s.GetQuote(symbol)
+func (s *marketDataService) RefreshCache(ctx context.Context, symbol string) error {
+	_ = s.cache.Delete(ctx, quoteCachePrefix+symbol)
+	_, err := s.GetQuote(ctx, symbol)
 	return err
 }
 
-// ClearCache clears all cached data
-func (s *marketDataService) ClearCache() {
-	s.cache = make(map[string]*cachedQuote)
+// RefreshTradingStatuses fetches fresh quotes for symbols, updating their trading
+// statuses, and returns the statuses of those that are halted or suspended
+func (s *marketDataService) RefreshTradingStatuses(ctx context.Context, symbols []string) (map[string]*dto.SymbolTradingStatus, error) {
+	for _, symbol := range symbols {
+		_ = s.cache.Delete(ctx, quoteCachePrefix+symbol)
+	}
+	if _, err := s.GetQuotes(ctx, symbols); err != nil {
+		return nil, fmt.Errorf("failed to refresh trading statuses: %w", err)
+	}
+
+	return s.GetTradingRestrictions(ctx, symbols), nil
+}
+
+// GetTradingRestrictions returns the cached status of each of symbols that is halted or
+// suspended. Statuses are recorded whenever a quote is fetched, so this never calls the
+// provider.
+func (s *marketDataService) GetTradingRestrictions(ctx context.Context, symbols []string) map[string]*dto.SymbolTradingStatus {
+	restrictions := make(map[string]*dto.SymbolTradingStatus)
+	for _, symbol := range symbols {
+		data, err := s.cache.Get(ctx, tradingStatusCachePrefix+symbol)
+		if err != nil {
+			continue
+		}
+
+		var status dto.SymbolTradingStatus
+		if err := json.Unmarshal(data, &status); err != nil {
+			continue
+		}
+		if status.Status.IsRestricted() {
+			restrictions[symbol] = &status
+		}
+	}
+
+	return restrictions
+}
+
+// GetQuoteFetchTimes returns when the last quote of each of symbols was fetched, from the
+// quotes kept for when the provider fails, which outlive the quote cache
+func (s *marketDataService) GetQuoteFetchTimes(ctx context.Context, symbols []string) map[string]time.Time {
+	fetched := make(map[string]time.Time, len(symbols))
+	for _, symbol := range symbols {
+		if quote := s.getQuoteFromCache(ctx, staleQuoteCachePrefix+symbol); quote != nil && !quote.FetchedAt.IsZero() {
+			fetched[symbol] = quote.FetchedAt
+		}
+	}
+	return fetched
+}
+
+// ClearCache clears all cached quotes. Trading statuses and the quotes served while a
+// breaker is open are kept until they expire.
+func (s *marketDataService) ClearCache(ctx context.Context) {
+	_ = s.cache.DeletePrefix(ctx, quoteCachePrefix)
+}
+
+// GetQuotaStatus returns the provider's current request budget and circuit breaker state
+func (s *marketDataService) GetQuotaStatus() *QuotaStatus {
+	status := s.quota.Status()
+	status.CircuitState = s.breaker.State()
+	return status
 }
@@ -2,12 +2,19 @@ package services
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/cache"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
 )
 
 func TestNewMarketDataService(t *testing.T) {
@@ -17,6 +24,8 @@ func TestNewMarketDataService(t *testing.T) {
 }
 
 func TestMarketDataService_GetQuote(t *testing.T) {
+	ctx := context.Background()
+
 	mockProvider := new(MockMarketDataProvider)
 	service := NewMarketDataService(mockProvider, 5*time.Minute)
 
@@ -36,7 +45,7 @@ func TestMarketDataService_GetQuote(t *testing.T) {
 	t.Run("fetch and cache quote", func(t *testing.T) {
 		mockProvider.On("GetQuote", mock.Anything, "AAPL").Return(quote, nil).Once()
 
-		result, err := service.GetQuote("AAPL")
+		result, err := service.GetQuote(ctx, "AAPL")
 		assert.NoError(t, err)
 		assert.Equal(t, "AAPL", result.Symbol)
 		assert.True(t, result.Price.Equal(decimal.NewFromInt(150)))
@@ -46,7 +55,7 @@ func TestMarketDataService_GetQuote(t *testing.T) {
 
 	t.Run("use cached quote", func(t *testing.T) {
 		// Should not call provider again, use cache
-		result, err := service.GetQuote("AAPL")
+		result, err := service.GetQuote(ctx, "AAPL")
 		assert.NoError(t, err)
 		assert.Equal(t, "AAPL", result.Symbol)
 		assert.True(t, result.Price.Equal(decimal.NewFromInt(150)))
@@ -56,6 +65,8 @@ func TestMarketDataService_GetQuote(t *testing.T) {
 }
 
 func TestMarketDataService_GetQuotes(t *testing.T) {
+	ctx := context.Background()
+
 	mockProvider := new(MockMarketDataProvider)
 	service := NewMarketDataService(mockProvider, 5*time.Minute)
 
@@ -73,7 +84,7 @@ func TestMarketDataService_GetQuotes(t *testing.T) {
 	t.Run("fetch multiple quotes", func(t *testing.T) {
 		mockProvider.On("GetQuotes", mock.Anything, []string{"AAPL", "GOOGL"}).Return(quotes, nil).Once()
 
-		result, err := service.GetQuotes([]string{"AAPL", "GOOGL"})
+		result, err := service.GetQuotes(ctx, []string{"AAPL", "GOOGL"})
 		assert.NoError(t, err)
 		assert.Len(t, result, 2)
 		assert.Equal(t, "AAPL", result["AAPL"].Symbol)
@@ -92,7 +103,7 @@ func TestMarketDataService_GetQuotes(t *testing.T) {
 		}
 		mockProvider.On("GetQuotes", mock.Anything, []string{"MSFT"}).Return(newQuotes, nil).Once()
 
-		result, err := service.GetQuotes([]string{"AAPL", "GOOGL", "MSFT"})
+		result, err := service.GetQuotes(ctx, []string{"AAPL", "GOOGL", "MSFT"})
 		assert.NoError(t, err)
 		assert.Len(t, result, 3)
 		assert.Equal(t, "AAPL", result["AAPL"].Symbol)
@@ -104,6 +115,8 @@ func TestMarketDataService_GetQuotes(t *testing.T) {
 }
 
 func TestMarketDataService_GetHistoricalPrices(t *testing.T) {
+	ctx := context.Background()
+
 	mockProvider := new(MockMarketDataProvider)
 	service := NewMarketDataService(mockProvider, 5*time.Minute)
 
@@ -131,7 +144,7 @@ func TestMarketDataService_GetHistoricalPrices(t *testing.T) {
 
 	mockProvider.On("GetHistoricalPrices", mock.Anything, "AAPL", startDate, endDate).Return(prices, nil).Once()
 
-	result, err := service.GetHistoricalPrices("AAPL", startDate, endDate)
+	result, err := service.GetHistoricalPrices(ctx, "AAPL", startDate, endDate)
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
 	assert.True(t, result[0].Close.Equal(decimal.NewFromInt(147)))
@@ -140,12 +153,91 @@ func TestMarketDataService_GetHistoricalPrices(t *testing.T) {
 	mockProvider.AssertExpectations(t)
 }
 
+func TestMarketDataService_GetPriceHistory(t *testing.T) {
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2024, 5, d, 0, 0, 0, 0, time.UTC) }
+	bar := func(date time.Time, open, high, low, closePrice int64, volume int64) *HistoricalPrice {
+		return &HistoricalPrice{
+			Date:   date,
+			Open:   decimal.NewFromInt(open),
+			High:   decimal.NewFromInt(high),
+			Low:    decimal.NewFromInt(low),
+			Close:  decimal.NewFromInt(closePrice),
+			Volume: volume,
+		}
+	}
+
+	t.Run("weekly bars combine a week's trading days", func(t *testing.T) {
+		provider := new(MockMarketDataProvider)
+		service := NewMarketDataService(provider, 5*time.Minute)
+
+		// Wednesday May 1st to Tuesday May 7th 2024, out of order like the provider's
+		provider.On("GetHistoricalPrices", mock.Anything, "AAPL", day(1), day(7)).Return([]*HistoricalPrice{
+			bar(day(6), 105, 108, 104, 107, 50),
+			bar(day(2), 101, 106, 100, 104, 20),
+			bar(day(1), 100, 102, 99, 101, 10),
+			bar(day(3), 104, 105, 98, 103, 30),
+			bar(day(7), 107, 110, 106, 109, 60),
+		}, nil).Once()
+
+		prices, err := service.GetPriceHistory(ctx, "AAPL", models.PriceResolution1Week, day(1), day(7))
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+
+		assert.Equal(t, day(1), prices[0].Date)
+		assert.True(t, prices[0].Open.Equal(decimal.NewFromInt(100)))
+		assert.True(t, prices[0].High.Equal(decimal.NewFromInt(106)))
+		assert.True(t, prices[0].Low.Equal(decimal.NewFromInt(98)))
+		assert.True(t, prices[0].Close.Equal(decimal.NewFromInt(103)))
+		assert.Equal(t, int64(60), prices[0].Volume)
+
+		assert.Equal(t, day(6), prices[1].Date)
+		assert.True(t, prices[1].Close.Equal(decimal.NewFromInt(109)))
+		assert.Equal(t, int64(110), prices[1].Volume)
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("intraday bars come from the provider's intraday prices", func(t *testing.T) {
+		provider := new(MockIntradayPriceProvider)
+		quota := NewQuotaTracker("alphavantage", 10, 0)
+		service := NewMarketDataServiceWithQuota(provider, cache.NewMemoryStore(), quota, 5*time.Minute)
+
+		start := day(1)
+		end := day(2)
+		provider.On("GetIntradayPrices", mock.Anything, "AAPL", models.PriceResolution5Min, start, end).Return([]*HistoricalPrice{
+			bar(start.Add(14*time.Hour), 101, 101, 100, 100, 5),
+			bar(start.Add(13*time.Hour+30*time.Minute), 100, 101, 100, 101, 5),
+		}, nil).Once()
+
+		prices, err := service.GetPriceHistory(ctx, "AAPL", models.PriceResolution5Min, start, end)
+		require.NoError(t, err)
+		require.Len(t, prices, 2)
+		assert.True(t, prices[0].Date.Before(prices[1].Date))
+		assert.Equal(t, 1, service.GetQuotaStatus().DailyUsed)
+
+		_, err = service.GetPriceHistory(ctx, "AAPL", models.PriceResolution1Hour, day(1), day(1).AddDate(0, 2, 0))
+		assert.Equal(t, models.ErrIntradayRangeTooLong, err)
+
+		_, err = service.GetPriceHistory(ctx, "AAPL", "2h", start, end)
+		assert.Equal(t, models.ErrInvalidPriceResolution, err)
+		provider.AssertExpectations(t)
+	})
+
+	t.Run("provider without intraday prices", func(t *testing.T) {
+		service := NewMarketDataService(new(MockMarketDataProvider), 5*time.Minute)
+		_, err := service.GetPriceHistory(ctx, "AAPL", models.PriceResolution1Min, day(1), day(2))
+		assert.ErrorIs(t, err, models.ErrMarketDataUnavailable)
+	})
+}
+
 func TestMarketDataService_GetExchangeRate(t *testing.T) {
+	ctx := context.Background()
+
 	mockProvider := new(MockMarketDataProvider)
 	service := NewMarketDataService(mockProvider, 5*time.Minute)
 
 	t.Run("same currency", func(t *testing.T) {
-		rate, err := service.GetExchangeRate("USD", "USD")
+		rate, err := service.GetExchangeRate(ctx, "USD", "USD")
 		assert.NoError(t, err)
 		assert.True(t, rate.Equal(decimal.NewFromInt(1)))
 	})
@@ -153,7 +245,7 @@ func TestMarketDataService_GetExchangeRate(t *testing.T) {
 	t.Run("different currencies", func(t *testing.T) {
 		mockProvider.On("GetExchangeRate", mock.Anything, "EUR", "USD").Return(decimal.NewFromFloat(1.18), nil).Once()
 
-		rate, err := service.GetExchangeRate("EUR", "USD")
+		rate, err := service.GetExchangeRate(ctx, "EUR", "USD")
 		assert.NoError(t, err)
 		assert.True(t, rate.Equal(decimal.NewFromFloat(1.18)))
 
@@ -162,6 +254,8 @@ func TestMarketDataService_GetExchangeRate(t *testing.T) {
 }
 
 func TestMarketDataService_RefreshCache(t *testing.T) {
+	ctx := context.Background()
+
 	mockProvider := new(MockMarketDataProvider)
 	service := NewMarketDataService(mockProvider, 5*time.Minute)
 
@@ -172,7 +266,7 @@ func TestMarketDataService_RefreshCache(t *testing.T) {
 
 	// First call to cache
 	mockProvider.On("GetQuote", mock.Anything, "AAPL").Return(quote, nil).Once()
-	_, err := service.GetQuote("AAPL")
+	_, err := service.GetQuote(ctx, "AAPL")
 	assert.NoError(t, err)
 
 	// Refresh cache - should fetch again
@@ -182,8 +276,327 @@ func TestMarketDataService_RefreshCache(t *testing.T) {
 	}
 	mockProvider.On("GetQuote", mock.Anything, "AAPL").Return(updatedQuote, nil).Once()
 
-	err = service.RefreshCache("AAPL")
+	err = service.RefreshCache(ctx, "AAPL")
+	assert.NoError(t, err)
+
+	mockProvider.AssertExpectations(t)
+}
+
+func TestMarketDataService_QuotaExceeded(t *testing.T) {
+	ctx := context.Background()
+
+	mockProvider := new(MockMarketDataProvider)
+	quota := NewQuotaTracker("alphavantage", 1, 0)
+	service := NewMarketDataServiceWithQuota(mockProvider, cache.NewMemoryStore(), quota, 5*time.Minute)
+
+	mockProvider.On("GetQuote", mock.Anything, "AAPL").Return(&Quote{Symbol: "AAPL", Price: decimal.NewFromInt(150)}, nil).Once()
+
+	_, err := service.GetQuote(ctx, "AAPL")
+	assert.NoError(t, err)
+
+	// Cached quotes don't spend budget
+	_, err = service.GetQuote(ctx, "AAPL")
+	assert.NoError(t, err)
+
+	_, err = service.GetQuote(ctx, "MSFT")
+	assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+
+	_, err = service.GetQuotes(ctx, []string{"MSFT", "GOOGL"})
+	assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+
+	status := service.GetQuotaStatus()
+	assert.Equal(t, "alphavantage", status.Provider)
+	assert.Equal(t, 1, status.DailyUsed)
+	assert.Equal(t, 0, status.DailyRemaining)
+
+	mockProvider.AssertExpectations(t)
+}
+
+func TestMarketDataService_GetQuotes_DeduplicatesSymbols(t *testing.T) {
+	ctx := context.Background()
+
+	mockProvider := new(MockMarketDataProvider)
+	quota := NewQuotaTracker("alphavantage", 0, 0)
+	service := NewMarketDataServiceWithQuota(mockProvider, cache.NewMemoryStore(), quota, 5*time.Minute)
+
+	mockProvider.On("GetQuotes", mock.Anything, []string{"AAPL", "MSFT"}).Return(map[string]*Quote{
+		"AAPL": {Symbol: "AAPL", Price: decimal.NewFromInt(150)},
+		"MSFT": {Symbol: "MSFT", Price: decimal.NewFromInt(400)},
+	}, nil).Once()
+
+	result, err := service.GetQuotes(ctx, []string{"AAPL", "MSFT", "AAPL"})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, 2, service.GetQuotaStatus().DailyUsed)
+
+	mockProvider.AssertExpectations(t)
+}
+
+func TestMarketDataService_RoutesCoinPairsToCryptoProvider(t *testing.T) {
+	ctx := context.Background()
+
+	equities := new(MockMarketDataProvider)
+	crypto := new(MockMarketDataProvider)
+	quota := NewQuotaTracker("alphavantage", 1, 0)
+	service := NewMarketDataServiceWithCrypto(equities, crypto, cache.NewMemoryStore(), quota, 5*time.Minute)
+
+	equities.On("GetQuotes", mock.Anything, []string{"AAPL"}).Return(map[string]*Quote{
+		"AAPL": {Symbol: "AAPL", Price: decimal.NewFromInt(150)},
+	}, nil).Once()
+	crypto.On("GetQuotes", mock.Anything, []string{"BTC-USD", "ETH-EUR"}).Return(map[string]*Quote{
+		"BTC-USD": {Symbol: "BTC-USD", Price: decimal.NewFromInt(67000)},
+		"ETH-EUR": {Symbol: "ETH-EUR", Price: decimal.NewFromInt(3000)},
+	}, nil).Once()
+	crypto.On("GetHistoricalPrices", mock.Anything, "BTC-USD", mock.Anything, mock.Anything).Return([]*HistoricalPrice{
+		{Date: time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC), Close: decimal.NewFromInt(66000)},
+	}, nil).Once()
+
+	result, err := service.GetQuotes(ctx, []string{"AAPL", "BTC-USD", "ETH-EUR"})
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+
+	// Crypto requests don't spend the equity provider's budget
+	prices, err := service.GetHistoricalPrices(ctx, "BTC-USD", time.Date(2024, 6, 8, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, prices, 1)
+	assert.Equal(t, 1, service.GetQuotaStatus().DailyUsed)
+
+	equities.AssertExpectations(t)
+	crypto.AssertExpectations(t)
+}
+
+func TestMarketDataService_GetQuotes_FetchesConcurrently(t *testing.T) {
+	ctx := context.Background()
+
+	// Each equity quote waits until all three are in flight at once
+	equities := &barrierQuoteProvider{MockMarketDataProvider: new(MockMarketDataProvider), release: make(chan struct{})}
+	equities.started.Add(3)
+	go func() {
+		equities.started.Wait()
+		close(equities.release)
+	}()
+	crypto := new(MockMarketDataProvider)
+	quota := NewQuotaTracker("alphavantage", 0, 3)
+	service := NewMarketDataServiceWithConcurrency(equities, crypto, cache.NewMemoryStore(), quota, 5*time.Minute, 3)
+
+	crypto.On("GetQuotes", mock.Anything, []string{"BTC-USD"}).Return(map[string]*Quote{
+		"BTC-USD": {Symbol: "BTC-USD", Price: decimal.NewFromInt(67000)},
+	}, nil).Once()
+
+	result, err := service.GetQuotes(ctx, []string{"AAPL", "MSFT", "BTC-USD", "GOOGL"})
+	require.NoError(t, err)
+	assert.Len(t, result, 4)
+	assert.Equal(t, 3, service.GetQuotaStatus().MinuteUsed)
+
+	// Cached quotes are not fetched again, and the minute budget can't cover a fourth symbol
+	result, err = service.GetQuotes(ctx, []string{"AAPL", "MSFT", "GOOGL"})
+	require.NoError(t, err)
+	assert.Len(t, result, 3)
+	_, err = service.GetQuotes(ctx, []string{"AAPL", "TSLA"})
+	assert.ErrorIs(t, err, models.ErrQuotaExceeded)
+
+	crypto.AssertExpectations(t)
+}
+
+func TestMarketDataService_GetQuotes_ConcurrentlySkipsUnpricedSymbols(t *testing.T) {
+	mockProvider := new(MockMarketDataProvider)
+	service := NewMarketDataServiceWithConcurrency(mockProvider, nil, cache.NewMemoryStore(), NewQuotaTracker("", 0, 0), 5*time.Minute, 2)
+
+	mockProvider.On("GetQuote", mock.Anything, "AAPL").Return(&Quote{Symbol: "AAPL", Price: decimal.NewFromInt(150)}, nil).Once()
+	mockProvider.On("GetQuote", mock.Anything, "DELISTED").Return(nil, errors.New("no data found")).Once()
+
+	result, err := service.GetQuotes(context.Background(), []string{"AAPL", "DELISTED"})
+	require.NoError(t, err)
+	assert.Len(t, result, 1)
+	assert.Contains(t, result, "AAPL")
+}
+
+func TestMarketDataService_ServesStaleQuotesWhileBreakerIsOpen(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	breaker := newTestCircuitBreaker(1, &now)
+	mockProvider := new(MockMarketDataProvider)
+	quota := NewQuotaTracker("alphavantage", 0, 0)
+	service := NewMarketDataServiceWithBreakers(mockProvider, nil, cache.NewMemoryStore(), quota, 5*time.Minute, 1, breaker, nil)
+
+	mockProvider.On("GetQuote", mock.Anything, "AAPL").Return(&Quote{Symbol: "AAPL", Price: decimal.NewFromInt(150)}, nil).Once()
+	mockProvider.On("GetQuote", mock.Anything, "MSFT").Return(nil, context.DeadlineExceeded).Once()
+
+	_, err := service.GetQuote(ctx, "AAPL")
+	require.NoError(t, err)
+	service.ClearCache(ctx)
+	_, err = service.GetQuote(ctx, "MSFT")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, CircuitOpen, service.GetQuotaStatus().CircuitState)
+
+	// The provider isn't called while the breaker is open, and the last quotes fetched are
+	// served instead
+	quote, err := service.GetQuote(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.True(t, quote.Stale)
+	assert.True(t, quote.Price.Equal(decimal.NewFromInt(150)))
+
+	_, err = service.GetQuote(ctx, "MSFT")
+	assert.ErrorIs(t, err, models.ErrMarketDataUnavailable)
+	result, err := service.GetQuotes(ctx, []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.True(t, result["AAPL"].Stale)
+
+	_, err = service.GetHistoricalPrices(ctx, "AAPL", now.AddDate(0, 0, -7), now)
+	assert.ErrorIs(t, err, models.ErrMarketDataUnavailable)
+	assert.Equal(t, 2, service.GetQuotaStatus().DailyUsed)
+
+	// After the cooldown a request probes the provider, closing the breaker
+	now = now.Add(30 * time.Second)
+	mockProvider.On("GetQuotes", mock.Anything, []string{"AAPL", "MSFT"}).Return(map[string]*Quote{
+		"AAPL": {Symbol: "AAPL", Price: decimal.NewFromInt(155)},
+		"MSFT": {Symbol: "MSFT", Price: decimal.NewFromInt(400)},
+	}, nil).Once()
+
+	result, err = service.GetQuotes(ctx, []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.False(t, result["AAPL"].Stale)
+	assert.Equal(t, CircuitClosed, service.GetQuotaStatus().CircuitState)
+
+	mockProvider.AssertExpectations(t)
+}
+
+func TestMarketDataService_PricesOptionsWithOptionQuotes(t *testing.T) {
+	ctx := context.Background()
+
+	provider := new(MockOptionQuoteProvider)
+	quota := NewQuotaTracker("alphavantage", 10, 0)
+	service := NewMarketDataServiceWithQuota(provider, cache.NewMemoryStore(), quota, 5*time.Minute)
+
+	provider.On("GetQuotes", mock.Anything, []string{"AAPL"}).Return(map[string]*Quote{
+		"AAPL": {Symbol: "AAPL", Price: decimal.NewFromInt(150)},
+	}, nil).Once()
+	provider.On("GetOptionQuote", mock.Anything, "AAPL240621C00190000").
+		Return(&Quote{Symbol: "AAPL240621C00190000", Price: decimal.RequireFromString("3.55")}, nil).Once()
+	provider.On("GetOptionQuote", mock.Anything, "AAPL240621P00150000").
+		Return(nil, errors.New("no data found")).Once()
+
+	result, err := service.GetQuotes(ctx, []string{"AAPL", "AAPL240621C00190000", "AAPL240621P00150000"})
+	require.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.True(t, result["AAPL240621C00190000"].Price.Equal(decimal.RequireFromString("3.55")))
+	assert.Equal(t, 3, service.GetQuotaStatus().DailyUsed)
+
+	// Served from cache
+	quote, err := service.GetQuote(ctx, "AAPL240621C00190000")
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL240621C00190000", quote.Symbol)
+
+	_, err = service.GetHistoricalPrices(ctx, "AAPL240621C00190000", time.Now(), time.Now())
+	assert.Error(t, err)
+	assert.Equal(t, 3, service.GetQuotaStatus().DailyUsed)
+
+	provider.AssertExpectations(t)
+
+	t.Run("provider without option quotes", func(t *testing.T) {
+		service := NewMarketDataService(new(MockMarketDataProvider), 5*time.Minute)
+		_, err := service.GetQuote(ctx, "AAPL240621C00190000")
+		assert.ErrorContains(t, err, "no option quotes")
+	})
+}
+
+func TestMarketDataService_SearchSymbols(t *testing.T) {
+	ctx := context.Background()
+
+	provider := new(MockSymbolSearchProvider)
+	quota := NewQuotaTracker("alphavantage", 10, 0)
+	service := NewMarketDataServiceWithQuota(provider, cache.NewMemoryStore(), quota, 5*time.Minute)
+
+	provider.On("SearchSymbols", mock.Anything, "apple").Return([]*models.Security{
+		{Symbol: "AAPL", Name: "Apple Inc"},
+	}, nil).Once()
+
+	securities, err := service.SearchSymbols(ctx, "apple")
+	require.NoError(t, err)
+	require.Len(t, securities, 1)
+	assert.Equal(t, "AAPL", securities[0].Symbol)
+
+	// Served from cache whatever the keywords' case
+	securities, err = service.SearchSymbols(ctx, "APPLE")
+	require.NoError(t, err)
+	assert.Len(t, securities, 1)
+	assert.Equal(t, 1, service.GetQuotaStatus().DailyUsed)
+
+	provider.AssertExpectations(t)
+
+	t.Run("provider without symbol search", func(t *testing.T) {
+		service := NewMarketDataService(new(MockMarketDataProvider), 5*time.Minute)
+		_, err := service.SearchSymbols(ctx, "apple")
+		assert.ErrorIs(t, err, models.ErrMarketDataUnavailable)
+	})
+}
+
+func TestMarketDataService_GetQuote_CoalescesConcurrentRequests(t *testing.T) {
+	ctx := context.Background()
+
+	mockProvider := new(MockMarketDataProvider)
+	service := NewMarketDataService(mockProvider, 5*time.Minute)
+
+	release := make(chan time.Time)
+	mockProvider.On("GetQuote", mock.Anything, "AAPL").
+		WaitUntil(release).
+		Return(&Quote{Symbol: "AAPL", Price: decimal.NewFromInt(150)}, nil).
+		Once()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			quote, err := service.GetQuote(ctx, "AAPL")
+			assert.NoError(t, err)
+			assert.Equal(t, "AAPL", quote.Symbol)
+		}()
+	}
+
+	// Give the goroutines time to join the in-flight request before releasing it
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	mockProvider.AssertNumberOfCalls(t, "GetQuote", 1)
+}
+
+func TestMarketDataService_TradingRestrictions(t *testing.T) {
+	ctx := context.Background()
+
+	mockProvider := new(MockMarketDataProvider)
+	service := NewMarketDataService(mockProvider, 5*time.Minute)
+	session := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	mockProvider.On("GetQuotes", mock.Anything, []string{"HALT", "AAPL", "OTHER"}).Return(map[string]*Quote{
+		"HALT":  {Symbol: "HALT", LatestTradingDay: session, TradingStatus: dto.TradingStatusHalted},
+		"AAPL":  {Symbol: "AAPL", Volume: 1000, LatestTradingDay: session, TradingStatus: dto.TradingStatusActive},
+		"OTHER": {Symbol: "OTHER"},
+	}, nil).Once()
+
+	restrictions, err := service.RefreshTradingStatuses(ctx, []string{"HALT", "AAPL", "OTHER"})
 	assert.NoError(t, err)
+	assert.Len(t, restrictions, 1)
+	if assert.Contains(t, restrictions, "HALT") {
+		assert.Equal(t, dto.TradingStatusHalted, restrictions["HALT"].Status)
+		assert.Equal(t, session, *restrictions["HALT"].LatestTradingDay)
+	}
+
+	// Statuses outlive the quote cache and are read without calling the provider
+	service.ClearCache(ctx)
+	assert.Contains(t, service.GetTradingRestrictions(ctx, []string{"HALT", "AAPL"}), "HALT")
+
+	// A quote showing trading has resumed lifts the restriction
+	mockProvider.On("GetQuote", mock.Anything, "HALT").
+		Return(&Quote{Symbol: "HALT", Volume: 500, LatestTradingDay: session, TradingStatus: dto.TradingStatusActive}, nil).
+		Once()
+	_, err = service.GetQuote(ctx, "HALT")
+	assert.NoError(t, err)
+	assert.Empty(t, service.GetTradingRestrictions(ctx, []string{"HALT"}))
 
 	mockProvider.AssertExpectations(t)
 }
@@ -226,3 +639,60 @@ func (m *MockMarketDataProvider) IsAvailable() bool {
 	args := m.Called()
 	return args.Bool(0)
 }
+
+// MockOptionQuoteProvider is a MockMarketDataProvider that also prices options
+type MockOptionQuoteProvider struct {
+	MockMarketDataProvider
+}
+
+func (m *MockOptionQuoteProvider) GetOptionQuote(ctx context.Context, symbol string) (*Quote, error) {
+	args := m.Called(ctx, symbol)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Quote), args.Error(1)
+}
+
+// MockSymbolSearchProvider is a MockMarketDataProvider that also searches symbols
+type MockSymbolSearchProvider struct {
+	MockMarketDataProvider
+}
+
+func (m *MockSymbolSearchProvider) SearchSymbols(ctx context.Context, keywords string) ([]*models.Security, error) {
+	args := m.Called(ctx, keywords)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Security), args.Error(1)
+}
+
+// MockIntradayPriceProvider is a MockMarketDataProvider that also returns intraday bars
+type MockIntradayPriceProvider struct {
+	MockMarketDataProvider
+}
+
+func (m *MockIntradayPriceProvider) GetIntradayPrices(ctx context.Context, symbol string, resolution models.PriceResolution, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+	args := m.Called(ctx, symbol, resolution, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*HistoricalPrice), args.Error(1)
+}
+
+// barrierQuoteProvider answers every quote once release is closed, marking started as each
+// request arrives, and fails requests that wait longer than a second
+type barrierQuoteProvider struct {
+	*MockMarketDataProvider
+	started sync.WaitGroup
+	release chan struct{}
+}
+
+func (p *barrierQuoteProvider) GetQuote(ctx context.Context, symbol string) (*Quote, error) {
+	p.started.Done()
+	select {
+	case <-p.release:
+		return &Quote{Symbol: symbol, Price: decimal.NewFromInt(100)}, nil
+	case <-time.After(time.Second):
+		return nil, errors.New("quotes were not fetched concurrently")
+	}
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
@@ -12,12 +15,12 @@ type MockHoldingRepository struct {
 	mock.Mock
 }
 
-func (m *MockHoldingRepository) Create(holding *models.Holding) error {
+func (m *MockHoldingRepository) Create(ctx context.Context, holding *models.Holding) error {
 	args := m.Called(holding)
 	return args.Error(0)
 }
 
-func (m *MockHoldingRepository) FindByID(id string) (*models.Holding, error) {
+func (m *MockHoldingRepository) FindByID(ctx context.Context, id string) (*models.Holding, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -25,7 +28,7 @@ func (m *MockHoldingRepository) FindByID(id string) (*models.Holding, error) {
 	return args.Get(0).(*models.Holding), args.Error(1)
 }
 
-func (m *MockHoldingRepository) FindByPortfolioID(portfolioID string) ([]*models.Holding, error) {
+func (m *MockHoldingRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Holding, error) {
 	args := m.Called(portfolioID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -33,7 +36,15 @@ func (m *MockHoldingRepository) FindByPortfolioID(portfolioID string) ([]*models
 	return args.Get(0).([]*models.Holding), args.Error(1)
 }
 
-func (m *MockHoldingRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol string) (*models.Holding, error) {
+func (m *MockHoldingRepository) FindByPortfolioIDs(ctx context.Context, portfolioIDs []uuid.UUID) ([]*models.Holding, error) {
+	args := m.Called(portfolioIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Holding), args.Error(1)
+}
+
+func (m *MockHoldingRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) (*models.Holding, error) {
 	args := m.Called(portfolioID, symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -41,7 +52,7 @@ func (m *MockHoldingRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol s
 	return args.Get(0).(*models.Holding), args.Error(1)
 }
 
-func (m *MockHoldingRepository) FindBySymbol(symbol string) ([]*models.Holding, error) {
+func (m *MockHoldingRepository) FindBySymbol(ctx context.Context, symbol string) ([]*models.Holding, error) {
 	args := m.Called(symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -49,27 +60,43 @@ func (m *MockHoldingRepository) FindBySymbol(symbol string) ([]*models.Holding,
 	return args.Get(0).([]*models.Holding), args.Error(1)
 }
 
-func (m *MockHoldingRepository) Update(holding *models.Holding) error {
+func (m *MockHoldingRepository) FindDistinctSymbols(ctx context.Context) ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockHoldingRepository) CountHoldersBySymbol(ctx context.Context) (map[string]int, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockHoldingRepository) Update(ctx context.Context, holding *models.Holding) error {
 	args := m.Called(holding)
 	return args.Error(0)
 }
 
-func (m *MockHoldingRepository) Delete(id string) error {
+func (m *MockHoldingRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockHoldingRepository) DeleteByPortfolioID(portfolioID string) error {
+func (m *MockHoldingRepository) DeleteByPortfolioID(ctx context.Context, portfolioID string) error {
 	args := m.Called(portfolioID)
 	return args.Error(0)
 }
 
-func (m *MockHoldingRepository) DeleteByPortfolioIDAndSymbol(portfolioID, symbol string) error {
+func (m *MockHoldingRepository) DeleteByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) error {
 	args := m.Called(portfolioID, symbol)
 	return args.Error(0)
 }
 
-func (m *MockHoldingRepository) Upsert(holding *models.Holding) error {
+func (m *MockHoldingRepository) Upsert(ctx context.Context, holding *models.Holding) error {
 	args := m.Called(holding)
 	return args.Error(0)
 }
@@ -79,12 +106,12 @@ type MockPortfolioRepository struct {
 	mock.Mock
 }
 
-func (m *MockPortfolioRepository) Create(portfolio *models.Portfolio) error {
+func (m *MockPortfolioRepository) Create(ctx context.Context, portfolio *models.Portfolio) error {
 	args := m.Called(portfolio)
 	return args.Error(0)
 }
 
-func (m *MockPortfolioRepository) FindByID(id string) (*models.Portfolio, error) {
+func (m *MockPortfolioRepository) FindByID(ctx context.Context, id string) (*models.Portfolio, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -92,7 +119,7 @@ func (m *MockPortfolioRepository) FindByID(id string) (*models.Portfolio, error)
 	return args.Get(0).(*models.Portfolio), args.Error(1)
 }
 
-func (m *MockPortfolioRepository) FindByUserID(userID string) ([]*models.Portfolio, error) {
+func (m *MockPortfolioRepository) FindByUserID(ctx context.Context, userID string) ([]*models.Portfolio, error) {
 	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -100,7 +127,23 @@ func (m *MockPortfolioRepository) FindByUserID(userID string) ([]*models.Portfol
 	return args.Get(0).([]*models.Portfolio), args.Error(1)
 }
 
-func (m *MockPortfolioRepository) FindByUserIDAndName(userID, name string) (*models.Portfolio, error) {
+func (m *MockPortfolioRepository) FindByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]*models.Portfolio, error) {
+	args := m.Called(organizationID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Portfolio), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) FindAll(ctx context.Context) ([]*models.Portfolio, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Portfolio), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) FindByUserIDAndName(ctx context.Context, userID, name string) (*models.Portfolio, error) {
 	args := m.Called(userID, name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -108,32 +151,50 @@ func (m *MockPortfolioRepository) FindByUserIDAndName(userID, name string) (*mod
 	return args.Get(0).(*models.Portfolio), args.Error(1)
 }
 
-func (m *MockPortfolioRepository) Update(portfolio *models.Portfolio) error {
+func (m *MockPortfolioRepository) Update(ctx context.Context, portfolio *models.Portfolio) error {
 	args := m.Called(portfolio)
 	return args.Error(0)
 }
 
-func (m *MockPortfolioRepository) Delete(id string) error {
+func (m *MockPortfolioRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockPortfolioRepository) ExistsByUserIDAndName(userID, name string) (bool, error) {
+func (m *MockPortfolioRepository) ExistsByUserIDAndName(ctx context.Context, userID, name string) (bool, error) {
 	args := m.Called(userID, name)
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockPortfolioRepository) FindPeerComparisonOptedIn(ctx context.Context) ([]*models.Portfolio, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Portfolio), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) SetPeerComparisonOptIn(ctx context.Context, id string, optIn bool) error {
+	args := m.Called(id, optIn)
+	return args.Error(0)
+}
+
+func (m *MockPortfolioRepository) ReassignOrganizationPortfolios(ctx context.Context, organizationID, fromUserID, toUserID uuid.UUID) (int64, error) {
+	args := m.Called(organizationID, fromUserID, toUserID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // MockPerformanceSnapshotRepository for testing
 type MockPerformanceSnapshotRepository struct {
 	mock.Mock
 }
 
-func (m *MockPerformanceSnapshotRepository) Create(snapshot *models.PerformanceSnapshot) error {
+func (m *MockPerformanceSnapshotRepository) Create(ctx context.Context, snapshot *models.PerformanceSnapshot) error {
 	args := m.Called(snapshot)
 	return args.Error(0)
 }
 
-func (m *MockPerformanceSnapshotRepository) FindByID(id string) (*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotRepository) FindByID(ctx context.Context, id string) (*models.PerformanceSnapshot, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -141,7 +202,7 @@ func (m *MockPerformanceSnapshotRepository) FindByID(id string) (*models.Perform
 	return args.Get(0).(*models.PerformanceSnapshot), args.Error(1)
 }
 
-func (m *MockPerformanceSnapshotRepository) FindByPortfolioID(portfolioID string, limit, offset int) ([]*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotRepository) FindByPortfolioID(ctx context.Context, portfolioID string, limit, offset int) ([]*models.PerformanceSnapshot, error) {
 	args := m.Called(portfolioID, limit, offset)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -149,7 +210,7 @@ func (m *MockPerformanceSnapshotRepository) FindByPortfolioID(portfolioID string
 	return args.Get(0).([]*models.PerformanceSnapshot), args.Error(1)
 }
 
-func (m *MockPerformanceSnapshotRepository) FindByPortfolioIDAndDateRange(portfolioID string, startDate, endDate time.Time) ([]*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotRepository) FindByPortfolioIDAndDateRange(ctx context.Context, portfolioID string, startDate, endDate time.Time) ([]*models.PerformanceSnapshot, error) {
 	args := m.Called(portfolioID, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -157,7 +218,7 @@ func (m *MockPerformanceSnapshotRepository) FindByPortfolioIDAndDateRange(portfo
 	return args.Get(0).([]*models.PerformanceSnapshot), args.Error(1)
 }
 
-func (m *MockPerformanceSnapshotRepository) FindLatestByPortfolioID(portfolioID string) (*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotRepository) FindLatestByPortfolioID(ctx context.Context, portfolioID string) (*models.PerformanceSnapshot, error) {
 	args := m.Called(portfolioID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -165,7 +226,7 @@ func (m *MockPerformanceSnapshotRepository) FindLatestByPortfolioID(portfolioID
 	return args.Get(0).(*models.PerformanceSnapshot), args.Error(1)
 }
 
-func (m *MockPerformanceSnapshotRepository) FindByPortfolioIDAndDate(portfolioID string, date time.Time) (*models.PerformanceSnapshot, error) {
+func (m *MockPerformanceSnapshotRepository) FindByPortfolioIDAndDate(ctx context.Context, portfolioID string, date time.Time) (*models.PerformanceSnapshot, error) {
 	args := m.Called(portfolioID, date)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -173,22 +234,32 @@ func (m *MockPerformanceSnapshotRepository) FindByPortfolioIDAndDate(portfolioID
 	return args.Get(0).(*models.PerformanceSnapshot), args.Error(1)
 }
 
-func (m *MockPerformanceSnapshotRepository) Delete(id string) error {
+func (m *MockPerformanceSnapshotRepository) IterateByPortfolioID(ctx context.Context, portfolioID string, fn func([]*models.PerformanceSnapshot) error) error {
+	args := m.Called(portfolioID, fn)
+	return args.Error(0)
+}
+
+func (m *MockPerformanceSnapshotRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockPerformanceSnapshotRepository) DeleteByPortfolioID(portfolioID string) error {
+func (m *MockPerformanceSnapshotRepository) DeleteByPortfolioID(ctx context.Context, portfolioID string) error {
 	args := m.Called(portfolioID)
 	return args.Error(0)
 }
 
+func (m *MockPerformanceSnapshotRepository) DeleteByIDs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	args := m.Called(ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // MockHoldingService for testing
 type MockHoldingService struct {
 	mock.Mock
 }
 
-func (m *MockHoldingService) GetByPortfolioID(portfolioID, userID string) ([]*models.Holding, error) {
+func (m *MockHoldingService) GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.Holding, error) {
 	args := m.Called(portfolioID, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -196,7 +267,7 @@ func (m *MockHoldingService) GetByPortfolioID(portfolioID, userID string) ([]*mo
 	return args.Get(0).([]*models.Holding), args.Error(1)
 }
 
-func (m *MockHoldingService) GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) (*models.Holding, error) {
+func (m *MockHoldingService) GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) (*models.Holding, error) {
 	args := m.Called(portfolioID, symbol, userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -204,7 +275,15 @@ func (m *MockHoldingService) GetByPortfolioIDAndSymbol(portfolioID, symbol, user
 	return args.Get(0).(*models.Holding), args.Error(1)
 }
 
-func (m *MockHoldingService) GetPortfolioValue(portfolioID, userID string, prices map[string]decimal.Decimal) (decimal.Decimal, error) {
+func (m *MockHoldingService) GetByPortfolioIDAsOf(ctx context.Context, portfolioID, userID string, asOf time.Time) ([]*models.Holding, error) {
+	args := m.Called(portfolioID, userID, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Holding), args.Error(1)
+}
+
+func (m *MockHoldingService) GetPortfolioValue(ctx context.Context, portfolioID, userID string, prices map[string]decimal.Decimal) (decimal.Decimal, error) {
 	args := m.Called(portfolioID, userID, prices)
 	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
@@ -214,12 +293,12 @@ type MockTransactionRepository struct {
 	mock.Mock
 }
 
-func (m *MockTransactionRepository) Create(transaction *models.Transaction) error {
+func (m *MockTransactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
 	args := m.Called(transaction)
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepository) FindByID(id string) (*models.Transaction, error) {
+func (m *MockTransactionRepository) FindByID(ctx context.Context, id string) (*models.Transaction, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -227,7 +306,7 @@ func (m *MockTransactionRepository) FindByID(id string) (*models.Transaction, er
 	return args.Get(0).(*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) FindByPortfolioID(portfolioID string) ([]*models.Transaction, error) {
+func (m *MockTransactionRepository) FindByPortfolioID(ctx context.Context, portfolioID string) ([]*models.Transaction, error) {
 	args := m.Called(portfolioID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -235,7 +314,7 @@ func (m *MockTransactionRepository) FindByPortfolioID(portfolioID string) ([]*mo
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) FindByPortfolioIDAndSymbol(portfolioID, symbol string) ([]*models.Transaction, error) {
+func (m *MockTransactionRepository) FindByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol string) ([]*models.Transaction, error) {
 	args := m.Called(portfolioID, symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -243,7 +322,7 @@ func (m *MockTransactionRepository) FindByPortfolioIDAndSymbol(portfolioID, symb
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) FindByPortfolioIDWithFilters(portfolioID string, symbol *string, startDate, endDate *time.Time) ([]*models.Transaction, error) {
+func (m *MockTransactionRepository) FindByPortfolioIDWithFilters(ctx context.Context, portfolioID string, symbol *string, startDate, endDate *time.Time) ([]*models.Transaction, error) {
 	args := m.Called(portfolioID, symbol, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -251,17 +330,30 @@ func (m *MockTransactionRepository) FindByPortfolioIDWithFilters(portfolioID str
 	return args.Get(0).([]*models.Transaction), args.Error(1)
 }
 
-func (m *MockTransactionRepository) Update(transaction *models.Transaction) error {
+func (m *MockTransactionRepository) FindPageByPortfolioID(ctx context.Context, portfolioID string, after *models.Transaction, limit int) ([]*models.Transaction, error) {
+	args := m.Called(portfolioID, after, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockTransactionRepository) IterateByPortfolioID(ctx context.Context, portfolioID string, fn func([]*models.Transaction) error) error {
+	args := m.Called(portfolioID, fn)
+	return args.Error(0)
+}
+
+func (m *MockTransactionRepository) Update(ctx context.Context, transaction *models.Transaction) error {
 	args := m.Called(transaction)
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepository) Delete(id string) error {
+func (m *MockTransactionRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
-func (m *MockTransactionRepository) DeleteByImportBatchID(batchID string) error {
+func (m *MockTransactionRepository) DeleteByImportBatchID(ctx context.Context, batchID string) error {
 	args := m.Called(batchID)
 	return args.Error(0)
 }
@@ -271,7 +363,7 @@ type MockMarketDataService struct {
 	mock.Mock
 }
 
-func (m *MockMarketDataService) GetQuote(symbol string) (*Quote, error) {
+func (m *MockMarketDataService) GetQuote(ctx context.Context, symbol string) (*Quote, error) {
 	args := m.Called(symbol)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -279,7 +371,7 @@ func (m *MockMarketDataService) GetQuote(symbol string) (*Quote, error) {
 	return args.Get(0).(*Quote), args.Error(1)
 }
 
-func (m *MockMarketDataService) GetQuotes(symbols []string) (map[string]*Quote, error) {
+func (m *MockMarketDataService) GetQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
 	args := m.Called(symbols)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -287,7 +379,7 @@ func (m *MockMarketDataService) GetQuotes(symbols []string) (map[string]*Quote,
 	return args.Get(0).(map[string]*Quote), args.Error(1)
 }
 
-func (m *MockMarketDataService) GetHistoricalPrices(symbol string, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+func (m *MockMarketDataService) GetHistoricalPrices(ctx context.Context, symbol string, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
 	args := m.Called(symbol, startDate, endDate)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -295,16 +387,64 @@ func (m *MockMarketDataService) GetHistoricalPrices(symbol string, startDate, en
 	return args.Get(0).([]*HistoricalPrice), args.Error(1)
 }
 
-func (m *MockMarketDataService) GetExchangeRate(fromCurrency, toCurrency string) (decimal.Decimal, error) {
+func (m *MockMarketDataService) GetPriceHistory(ctx context.Context, symbol string, resolution models.PriceResolution, startDate, endDate time.Time) ([]*HistoricalPrice, error) {
+	args := m.Called(symbol, resolution, startDate, endDate)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*HistoricalPrice), args.Error(1)
+}
+
+func (m *MockMarketDataService) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (decimal.Decimal, error) {
 	args := m.Called(fromCurrency, toCurrency)
 	return args.Get(0).(decimal.Decimal), args.Error(1)
 }
 
-func (m *MockMarketDataService) RefreshCache(symbol string) error {
+func (m *MockMarketDataService) RefreshCache(ctx context.Context, symbol string) error {
 	args := m.Called(symbol)
 	return args.Error(0)
 }
 
-func (m *MockMarketDataService) ClearCache() {
+func (m *MockMarketDataService) ClearCache(ctx context.Context) {
 	m.Called()
 }
+
+func (m *MockMarketDataService) GetQuotaStatus() *QuotaStatus {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*QuotaStatus)
+}
+
+func (m *MockMarketDataService) SearchSymbols(ctx context.Context, keywords string) ([]*models.Security, error) {
+	args := m.Called(keywords)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Security), args.Error(1)
+}
+
+func (m *MockMarketDataService) RefreshTradingStatuses(ctx context.Context, symbols []string) (map[string]*dto.SymbolTradingStatus, error) {
+	args := m.Called(symbols)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*dto.SymbolTradingStatus), args.Error(1)
+}
+
+func (m *MockMarketDataService) GetQuoteFetchTimes(ctx context.Context, symbols []string) map[string]time.Time {
+	args := m.Called(symbols)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(map[string]time.Time)
+}
+
+func (m *MockMarketDataService) GetTradingRestrictions(ctx context.Context, symbols []string) map[string]*dto.SymbolTradingStatus {
+	args := m.Called(symbols)
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(map[string]*dto.SymbolTradingStatus)
+}
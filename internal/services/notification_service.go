@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+const (
+	// DefaultNotificationListLimit is how many notifications List returns when no limit is given
+	DefaultNotificationListLimit = 50
+
+	// MaxNotificationListLimit is the most notifications List returns
+	MaxNotificationListLimit = 100
+)
+
+// NotificationService defines the interface for in-app notifications
+type NotificationService interface {
+	Notify(ctx context.Context, notification *models.Notification) error
+	List(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*models.Notification, int64, error)
+	MarkRead(ctx context.Context, notificationID, userID string) (*models.Notification, error)
+}
+
+// notificationService implements NotificationService interface
+type notificationService struct {
+	notificationRepo repository.NotificationRepository
+	pushService      PushService
+	settings         UserSettingsService
+	now              func() time.Time
+}
+
+// NewNotificationService creates a new NotificationService instance
+func NewNotificationService(notificationRepo repository.NotificationRepository) NotificationService {
+	return NewNotificationServiceWithPush(notificationRepo, nil)
+}
+
+// NewNotificationServiceWithPush creates a NotificationService that also pushes each
+// notification to its user's registered devices
+func NewNotificationServiceWithPush(notificationRepo repository.NotificationRepository, pushService PushService) NotificationService {
+	return NewNotificationServiceWithSettings(notificationRepo, pushService, nil)
+}
+
+// NewNotificationServiceWithSettings creates a NotificationService that pushes notifications
+// like NewNotificationServiceWithPush, following the notification preferences of each user's
+// settings
+func NewNotificationServiceWithSettings(
+	notificationRepo repository.NotificationRepository,
+	pushService PushService,
+	settings UserSettingsService,
+) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		pushService:      pushService,
+		settings:         settings,
+		now:              func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Notify stores a notification for its user to read, then pushes it to their devices. Kinds
+// of notifications the user turned off in their settings are dropped.
+func (s *notificationService) Notify(ctx context.Context, notification *models.Notification) error {
+	if !notification.Type.IsValid() {
+		return fmt.Errorf("invalid notification type: %s", notification.Type)
+	}
+
+	push := s.pushService != nil
+	if s.settings != nil {
+		settings, err := s.settings.Get(ctx, notification.UserID.String())
+		if err != nil {
+			return fmt.Errorf("failed to load user settings: %w", err)
+		}
+		if !settings.Notifies(notification.Type) {
+			return nil
+		}
+		push = push && settings.PushNotifications
+	}
+
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return err
+	}
+	if push {
+		s.pushService.Deliver(ctx, notification)
+	}
+	return nil
+}
+
+// List returns the user's most recent notifications, up to MaxNotificationListLimit, along
+// with how many of all their notifications are unread
+func (s *notificationService) List(ctx context.Context, userID string, unreadOnly bool, limit int) ([]*models.Notification, int64, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	if limit <= 0 {
+		limit = DefaultNotificationListLimit
+	}
+	if limit > MaxNotificationListLimit {
+		limit = MaxNotificationListLimit
+	}
+
+	notifications, err := s.notificationRepo.FindByUserID(ctx, uid, unreadOnly, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, uid)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, unread, nil
+}
+
+// MarkRead marks one of the user's notifications read and returns it
+func (s *notificationService) MarkRead(ctx context.Context, notificationID, userID string) (*models.Notification, error) {
+	id, err := uuid.Parse(notificationID)
+	if err != nil {
+		return nil, models.ErrNotificationNotFound
+	}
+
+	notification, err := s.notificationRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if notification.UserID.String() != userID {
+		return nil, models.ErrNotificationNotFound // Don't leak other users' notifications
+	}
+	if notification.IsRead() {
+		return notification, nil
+	}
+
+	readAt := s.now()
+	if err := s.notificationRepo.MarkRead(ctx, id, readAt); err != nil {
+		return nil, err
+	}
+	notification.ReadAt = &readAt
+
+	return notification, nil
+}
+
+// NotifyingImportJobHandler tells the user who queued an import whether it finished, with a
+// notification, after running it with handler. A notification that can't be stored is
+// logged and doesn't change the job's outcome.
+func NotifyingImportJobHandler(handler QueuedJobHandler, notifications NotificationService) QueuedJobHandler {
+	return func(ctx context.Context, job *models.QueuedJob, report func(progress any)) (any, error) {
+		result, err := handler(ctx, job, report)
+
+		notification := &models.Notification{
+			UserID:      job.UserID,
+			PortfolioID: job.PortfolioID,
+			Type:        models.NotificationTypeImportFailed,
+			Title:       "Import failed",
+		}
+		if err != nil {
+			notification.Message = err.Error()
+		} else {
+			notification.Type = models.NotificationTypeImportCompleted
+			notification.Title = "Import finished"
+			notification.Message = importSummary(result)
+		}
+		if notifyErr := notifications.Notify(ctx, notification); notifyErr != nil {
+			log.Printf("Error notifying user %s of import %s: %v", job.UserID, job.ID, notifyErr)
+		}
+
+		return result, err
+	}
+}
+
+// importSummary describes the outcome of an import from its result
+func importSummary(result any) string {
+	switch result := result.(type) {
+	case *dto.ImportResult:
+		return fmt.Sprintf("%d of %d rows imported, %d skipped, %d with errors",
+			result.SuccessCount, result.TotalRows, result.SkippedCount, result.ErrorCount)
+	case *dto.TrackerImportResult:
+		return fmt.Sprintf("%d portfolios imported, %d activities with errors",
+			len(result.Portfolios), result.ErrorCount)
+	default:
+		return "Your import has finished"
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupNotificationService(t *testing.T) NotificationService {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Notification{}))
+	return NewNotificationService(repository.NewNotificationRepository(db))
+}
+
+func TestNotificationService_MarkRead(t *testing.T) {
+	service := setupNotificationService(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	notification := &models.Notification{UserID: userID, Type: models.NotificationTypeReportGenerated, Title: "Digest", Message: "Sent"}
+	require.NoError(t, service.Notify(ctx, notification))
+	assert.Error(t, service.Notify(ctx, &models.Notification{UserID: userID, Type: "PRICE_TARGET"}))
+
+	// Other users' notifications aren't found
+	_, err := service.MarkRead(ctx, notification.ID.String(), uuid.New().String())
+	assert.Equal(t, models.ErrNotificationNotFound, err)
+	_, err = service.MarkRead(ctx, "not-a-uuid", userID.String())
+	assert.Equal(t, models.ErrNotificationNotFound, err)
+
+	read, err := service.MarkRead(ctx, notification.ID.String(), userID.String())
+	require.NoError(t, err)
+	assert.True(t, read.IsRead())
+
+	notifications, unread, err := service.List(ctx, userID.String(), false, 0)
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.True(t, notifications[0].IsRead())
+	assert.Equal(t, int64(0), unread)
+}
+
+func TestNotifyingImportJobHandler(t *testing.T) {
+	service := setupNotificationService(t)
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	job := &models.QueuedJob{ID: uuid.New(), UserID: uuid.New(), PortfolioID: &portfolioID, Type: models.QueuedJobTypeCSVImport}
+
+	succeeded := NotifyingImportJobHandler(func(context.Context, *models.QueuedJob, func(any)) (any, error) {
+		return &dto.ImportResult{TotalRows: 3, SuccessCount: 2, ErrorCount: 1}, nil
+	}, service)
+	_, err := succeeded(ctx, job, func(any) {})
+	require.NoError(t, err)
+
+	failed := NotifyingImportJobHandler(func(context.Context, *models.QueuedJob, func(any)) (any, error) {
+		return (*dto.ImportResult)(nil), errors.New("unsupported import format: XLS")
+	}, service)
+	_, err = failed(ctx, job, func(any) {})
+	require.Error(t, err)
+
+	notifications, unread, err := service.List(ctx, job.UserID.String(), true, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), unread)
+	require.Len(t, notifications, 2)
+	types := map[models.NotificationType]string{}
+	for _, notification := range notifications {
+		types[notification.Type] = notification.Message
+		assert.Equal(t, portfolioID, *notification.PortfolioID)
+	}
+	assert.Equal(t, "2 of 3 rows imported, 0 skipped, 1 with errors", types[models.NotificationTypeImportCompleted])
+	assert.Equal(t, "unsupported import format: XLS", types[models.NotificationTypeImportFailed])
+}
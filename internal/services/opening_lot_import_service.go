@@ -0,0 +1,102 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services/csv_parsers"
+)
+
+// OpeningLotImportService defines the interface for importing the lots a portfolio held
+// before its recorded history
+type OpeningLotImportService interface {
+	// Import records each lot of a CSV file as an opening balance transaction and rebuilds
+	// the portfolio's holdings and tax lots
+	Import(ctx context.Context, portfolioID, userID string, req dto.OpeningLotImportRequest) (*dto.OpeningLotImportResult, error)
+}
+
+// openingLotImportService implements OpeningLotImportService interface
+type openingLotImportService struct {
+	portfolioRepo        repository.PortfolioRepository
+	importService        CSVImportService
+	recalculationService PortfolioRecalculationService
+	parser               csv_parsers.CSVParser
+}
+
+// NewOpeningLotImportService creates a new OpeningLotImportService instance. The lots are
+// imported as one import batch, then the portfolio is rebuilt from its ledger to create
+// their tax lots.
+func NewOpeningLotImportService(
+	portfolioRepo repository.PortfolioRepository,
+	importService CSVImportService,
+	recalculationService PortfolioRecalculationService,
+) OpeningLotImportService {
+	return &openingLotImportService{
+		portfolioRepo:        portfolioRepo,
+		importService:        importService,
+		recalculationService: recalculationService,
+		parser:               csv_parsers.NewOpeningLotsParser(),
+	}
+}
+
+// Import imports opening lots. Nothing is imported if any row can't be read, unless
+// SkipInvalid is set. Lots without a currency are in the portfolio's base currency.
+func (s *openingLotImportService) Import(ctx context.Context, portfolioID, userID string, req dto.OpeningLotImportRequest) (*dto.OpeningLotImportResult, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrPortfolioNotFound // Don't leak existence of other users' portfolios
+	}
+
+	// Decode the CSV data (support both raw text and base64)
+	data := []byte(req.CSVData)
+	if isBase64(req.CSVData) {
+		if decoded, err := base64.StdEncoding.DecodeString(req.CSVData); err == nil {
+			data = decoded
+		}
+	}
+
+	transactions, parseErrors, err := s.parser.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidImportFile, err)
+	}
+	if len(parseErrors) > 0 && !req.SkipInvalid || len(transactions) == 0 {
+		return &dto.OpeningLotImportResult{Result: &dto.ImportResult{
+			Success:        false,
+			TotalRows:      len(transactions) + len(parseErrors),
+			ErrorCount:     len(parseErrors),
+			Errors:         parseErrors,
+			ValidationOnly: req.DryRun,
+		}}, nil
+	}
+
+	for i := range transactions {
+		if transactions[i].Currency == "" {
+			transactions[i].Currency = portfolio.BaseCurrency
+		}
+	}
+
+	result, report, err := importAndReplay(ctx, s.importService, s.recalculationService, portfolioID, userID, dto.BulkImportRequest{
+		Format:       dto.ImportFormatOpeningLots,
+		Transactions: transactions,
+		DryRun:       req.DryRun,
+		SkipInvalid:  req.SkipInvalid,
+		Notes:        req.Notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	addParseErrors(result, parseErrors)
+	imported := &dto.OpeningLotImportResult{Result: result}
+	if report != nil {
+		imported.TaxLots = report.TaxLots
+	}
+	return imported, nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+const openingLotsCSV = `Symbol,Quantity,Cost Basis,Purchase Date,Currency
+AAPL,100,5000,2019-03-01,
+AAPL,3,500,2020-07-15,
+ASML,10,2500,2021-01-04,EUR
+`
+
+func setupOpeningLotImportTest(t *testing.T) (*gorm.DB, OpeningLotImportService, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.Holding{}, &models.TaxLot{},
+	))
+
+	user := &models.User{Email: "opening-lots@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Brokerage",
+		BaseCurrency:    "CAD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	service := NewOpeningLotImportService(
+		portfolioRepo,
+		NewCSVImportService(repository.NewTransactionRepository(db), portfolioRepo, repository.NewHoldingRepository(db)),
+		NewPortfolioRecalculationService(db),
+	)
+	return db, service, user, portfolio
+}
+
+func TestOpeningLotImportService_Import(t *testing.T) {
+	db, service, user, portfolio := setupOpeningLotImportTest(t)
+	ctx := context.Background()
+
+	result, err := service.Import(ctx, portfolio.ID.String(), user.ID.String(), dto.OpeningLotImportRequest{CSVData: openingLotsCSV})
+	require.NoError(t, err)
+	require.True(t, result.Result.Success, result.Result.Errors)
+	assert.Equal(t, 3, result.Result.SuccessCount)
+	assert.Equal(t, 3, result.TaxLots)
+
+	// Each lot is an opening balance priced at its cost per share, in the base currency
+	// unless the file says otherwise
+	transactions, err := repository.NewTransactionRepository(db).FindByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	currencies := map[string]string{}
+	for _, tx := range transactions {
+		assert.Equal(t, models.TransactionTypeOpeningBalance, tx.Type)
+		assert.Equal(t, result.Result.BatchID, *tx.ImportBatchID)
+		currencies[tx.Symbol] = tx.Currency
+	}
+	assert.Equal(t, map[string]string{"AAPL": "CAD", "ASML": "EUR"}, currencies)
+
+	// The lots keep their purchase dates and cost bases
+	lots, err := repository.NewTaxLotRepository(db).FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
+	require.NoError(t, err)
+	require.Len(t, lots, 2)
+	assert.Equal(t, "2019-03-01", lots[0].PurchaseDate.Format("2006-01-02"))
+	assert.True(t, decimal.NewFromInt(5000).Equal(lots[0].CostBasis), lots[0].CostBasis.String())
+	assert.Equal(t, "2020-07-15", lots[1].PurchaseDate.Format("2006-01-02"))
+	assert.True(t, decimal.NewFromInt(500).Round(2).Equal(lots[1].CostBasis.Round(2)), lots[1].CostBasis.String())
+
+	holding, err := repository.NewHoldingRepository(db).FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(103).Equal(holding.Quantity), holding.Quantity.String())
+}
+
+func TestOpeningLotImportService_Import_RejectsInvalidLots(t *testing.T) {
+	db, service, user, portfolio := setupOpeningLotImportTest(t)
+	ctx := context.Background()
+	csv := openingLotsCSV + "MSFT,5,-10,2021-02-01,\n"
+
+	result, err := service.Import(ctx, portfolio.ID.String(), user.ID.String(), dto.OpeningLotImportRequest{CSVData: csv})
+	require.NoError(t, err)
+	assert.False(t, result.Result.Success)
+	require.Len(t, result.Result.Errors, 1)
+	assert.Equal(t, 5, result.Result.Errors[0].Line)
+	assert.Equal(t, "cost_basis", result.Result.Errors[0].Field)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Transaction{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// Skipping the invalid lot imports the others
+	result, err = service.Import(ctx, portfolio.ID.String(), user.ID.String(), dto.OpeningLotImportRequest{CSVData: csv, SkipInvalid: true})
+	require.NoError(t, err)
+	assert.True(t, result.Result.Success)
+	assert.Equal(t, 3, result.Result.SuccessCount)
+	assert.Equal(t, 1, result.Result.ErrorCount)
+	assert.Equal(t, 3, result.TaxLots)
+}
+
+func TestOpeningLotImportService_Import_DryRun(t *testing.T) {
+	db, service, user, portfolio := setupOpeningLotImportTest(t)
+
+	result, err := service.Import(context.Background(), portfolio.ID.String(), user.ID.String(), dto.OpeningLotImportRequest{
+		CSVData: openingLotsCSV,
+		DryRun:  true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Result.Success)
+	assert.True(t, result.Result.ValidationOnly)
+	assert.Zero(t, result.TaxLots)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Transaction{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestOpeningLotImportService_Import_Errors(t *testing.T) {
+	_, service, user, portfolio := setupOpeningLotImportTest(t)
+	ctx := context.Background()
+
+	_, err := service.Import(ctx, portfolio.ID.String(), user.ID.String(), dto.OpeningLotImportRequest{
+		CSVData: "Date,Type,Symbol,Quantity\n2024-01-02,BUY,AAPL,1\n",
+	})
+	assert.True(t, errors.Is(err, models.ErrInvalidImportFile))
+
+	other := &models.User{Email: "someone-else@example.com"}
+	_, err = service.Import(ctx, portfolio.ID.String(), other.ID.String(), dto.OpeningLotImportRequest{CSVData: openingLotsCSV})
+	assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+}
+
+// failingRecalculationService fails every ledger replay
+type failingRecalculationService struct{}
+
+func (failingRecalculationService) Recalculate(ctx context.Context, portfolioID, userID string, dryRun bool) (*RecalculationReport, error) {
+	return nil, errors.New("replay failed")
+}
+
+func TestOpeningLotImportService_Import_FailedReplay(t *testing.T) {
+	db, _, user, portfolio := setupOpeningLotImportTest(t)
+	ctx := context.Background()
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	service := NewOpeningLotImportService(
+		portfolioRepo,
+		NewCSVImportService(repository.NewTransactionRepository(db), portfolioRepo, repository.NewHoldingRepository(db)),
+		failingRecalculationService{},
+	)
+
+	result, err := service.Import(ctx, portfolio.ID.String(), user.ID.String(), dto.OpeningLotImportRequest{CSVData: openingLotsCSV})
+	require.NoError(t, err)
+
+	// The transactions stay imported and the failed replay is reported with them
+	assert.Equal(t, 3, result.Result.SuccessCount)
+	assert.Equal(t, 1, result.Result.ErrorCount)
+	require.Len(t, result.Result.Errors, 1)
+	assert.Contains(t, result.Result.Errors[0].Message, "failed to rebuild holdings and tax lots")
+	assert.Zero(t, result.TaxLots)
+
+	var transactions int64
+	require.NoError(t, db.Model(&models.Transaction{}).Where("portfolio_id = ?", portfolio.ID).Count(&transactions).Error)
+	assert.Equal(t, int64(3), transactions)
+}
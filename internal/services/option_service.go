@@ -0,0 +1,532 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// underlyingCloseLookback is how far before an expiry to look for the underlying's last close
+// when deciding whether an expired contract finished in the money
+const underlyingCloseLookback = 7 * 24 * time.Hour
+
+// OptionService defines the interface for option trading business logic
+type OptionService interface {
+	Trade(ctx context.Context, portfolioID, userID string, req *dto.OptionTradeRequest) (*models.Transaction, error)
+	ListPositions(ctx context.Context, portfolioID, userID string) (*dto.OptionPositionListResponse, error)
+	Settle(ctx context.Context, portfolioID, contractID, userID string, req *dto.SettleOptionRequest) ([]*models.Transaction, error)
+	SettleExpired(ctx context.Context, asOf time.Time) (int, error)
+}
+
+// optionService implements OptionService interface
+type optionService struct {
+	contractRepo       repository.OptionContractRepository
+	portfolioRepo      repository.PortfolioRepository
+	transactionRepo    repository.TransactionRepository
+	holdingRepo        repository.HoldingRepository
+	transactionService TransactionService
+	marketData         MarketDataService
+	now                func() time.Time
+}
+
+// NewOptionService creates a new OptionService instance. Without market data, positions are
+// listed without market values and expired contracts are not settled automatically.
+func NewOptionService(
+	contractRepo repository.OptionContractRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	holdingRepo repository.HoldingRepository,
+	transactionService TransactionService,
+	marketData MarketDataService,
+) OptionService {
+	return &optionService{
+		contractRepo:       contractRepo,
+		portfolioRepo:      portfolioRepo,
+		transactionRepo:    transactionRepo,
+		holdingRepo:        holdingRepo,
+		transactionService: transactionService,
+		marketData:         marketData,
+		now:                func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *optionService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// Trade buys contracts to open a position or sells them to close it. The contract is
+// recorded the first time any portfolio trades it.
+func (s *optionService) Trade(ctx context.Context, portfolioID, userID string, req *dto.OptionTradeRequest) (*models.Transaction, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := s.resolveContract(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	price := req.Price
+	transaction := &models.Transaction{
+		PortfolioID: portfolio.ID,
+		Type:        req.Type,
+		Symbol:      contract.Symbol,
+		AssetType:   models.AssetTypeOption,
+		Date:        req.Date,
+		Quantity:    req.Quantity,
+		Price:       &price,
+		Multiplier:  contract.Multiplier,
+		Commission:  req.Commission,
+		Currency:    portfolio.BaseCurrency,
+		Notes:       req.Notes,
+	}
+	if transaction.Type != models.TransactionTypeBuyToOpen && transaction.Type != models.TransactionTypeSellToClose {
+		return nil, models.ErrInvalidOptionTrade
+	}
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+
+	var holding *models.Holding
+	if transaction.Type == models.TransactionTypeSellToClose {
+		holding, err = s.findPosition(ctx, portfolio.ID.String(), contract.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if holding.Quantity.LessThan(transaction.Quantity) {
+			return nil, models.ErrInsufficientShares
+		}
+	}
+
+	// The writes below (and the cleanup on failure) must all run even if the caller gives up
+	ctx = context.WithoutCancel(ctx)
+
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if transaction.Type == models.TransactionTypeBuyToOpen {
+		err = s.openPosition(ctx, transaction)
+	} else {
+		err = s.closePosition(ctx, holding, transaction.Quantity)
+	}
+	if err != nil {
+		return nil, s.rollbackTransaction(ctx, transaction, fmt.Errorf("failed to update holdings: %w", err))
+	}
+
+	return transaction, nil
+}
+
+// resolveContract returns the stored contract a trade request names, by OCC symbol or by its
+// terms, recording it if this is its first trade
+func (s *optionService) resolveContract(ctx context.Context, req *dto.OptionTradeRequest) (*models.OptionContract, error) {
+	var contract *models.OptionContract
+	if req.Symbol != "" {
+		parsed, err := models.ParseOptionSymbol(req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		contract = parsed
+	} else {
+		if req.Strike == nil || req.Expiry == nil {
+			return nil, models.ErrInvalidOptionContract
+		}
+		contract = &models.OptionContract{
+			Underlying: req.Underlying,
+			Type:       req.OptionType,
+			Strike:     *req.Strike,
+			Expiry:     *req.Expiry,
+			Multiplier: models.DefaultOptionMultiplier,
+		}
+	}
+	if req.Multiplier > 0 {
+		contract.Multiplier = req.Multiplier
+	}
+	if err := contract.Validate(); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.contractRepo.FindOrCreate(ctx, contract)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save option contract: %w", err)
+	}
+	return stored, nil
+}
+
+// findPosition returns the portfolio's holding of an option contract
+func (s *optionService) findPosition(ctx context.Context, portfolioID, symbol string) (*models.Holding, error) {
+	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+	if errors.Is(err, models.ErrHoldingNotFound) {
+		return nil, models.ErrNoOptionPosition
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holding: %w", err)
+	}
+	return holding, nil
+}
+
+// openPosition adds the transaction's contracts to the portfolio holding, creating it if needed
+func (s *optionService) openPosition(ctx context.Context, transaction *models.Transaction) error {
+	totalCost := transaction.GetTotalCost()
+
+	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, transaction.PortfolioID.String(), transaction.Symbol)
+	if errors.Is(err, models.ErrHoldingNotFound) {
+		return s.holdingRepo.Create(ctx, &models.Holding{
+			PortfolioID:  transaction.PortfolioID,
+			Symbol:       transaction.Symbol,
+			AssetType:    models.AssetTypeOption,
+			Quantity:     transaction.Quantity,
+			CostBasis:    totalCost,
+			AvgCostPrice: totalCost.Div(transaction.Quantity),
+			Multiplier:   transaction.Multiplier,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get holding: %w", err)
+	}
+
+	holding.AddShares(transaction.Quantity, totalCost)
+	return s.holdingRepo.Update(ctx, holding)
+}
+
+// closePosition removes contracts from the holding at their average cost, deleting it once
+// no contracts are left
+func (s *optionService) closePosition(ctx context.Context, holding *models.Holding, quantity decimal.Decimal) error {
+	if err := holding.RemoveShares(quantity, holding.AvgCostPrice.Mul(quantity)); err != nil {
+		return err
+	}
+	if holding.Quantity.IsZero() {
+		return s.holdingRepo.Delete(ctx, holding.ID.String())
+	}
+	return s.holdingRepo.Update(ctx, holding)
+}
+
+// rollbackTransaction deletes a transaction after a failed follow-up step
+func (s *optionService) rollbackTransaction(ctx context.Context, transaction *models.Transaction, cause error) error {
+	if deleteErr := s.transactionRepo.Delete(ctx, transaction.ID.String()); deleteErr != nil {
+		return fmt.Errorf("%w (rollback failed: %v)", cause, deleteErr)
+	}
+	return cause
+}
+
+// ListPositions lists the option contracts held in a portfolio, soonest expiry first, valued
+// at the provider's option quotes where there are any
+func (s *optionService) ListPositions(ctx context.Context, portfolioID, userID string) (*dto.OptionPositionListResponse, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	holdings, err := s.holdingRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holdings: %w", err)
+	}
+
+	holdingsBySymbol := make(map[string]*models.Holding)
+	var symbols []string
+	for _, holding := range holdings {
+		if holding.AssetType != models.AssetTypeOption && !models.IsOptionSymbol(holding.Symbol) {
+			continue
+		}
+		holdingsBySymbol[holding.Symbol] = holding
+		symbols = append(symbols, holding.Symbol)
+	}
+
+	response := &dto.OptionPositionListResponse{Positions: []*dto.OptionPositionResponse{}}
+	if len(symbols) == 0 {
+		return response, nil
+	}
+
+	contracts, err := s.contractRepo.FindBySymbols(ctx, symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get option contracts: %w", err)
+	}
+
+	// Contracts only ever held through imports or replays have no stored terms; read them
+	// from the symbol so the position is still listed
+	found := make(map[string]bool, len(contracts))
+	for _, contract := range contracts {
+		found[contract.Symbol] = true
+	}
+	for _, symbol := range symbols {
+		if found[symbol] {
+			continue
+		}
+		if contract, err := models.ParseOptionSymbol(symbol); err == nil {
+			contracts = append(contracts, contract)
+		}
+	}
+
+	// Positions are listed without market values when quotes aren't available
+	var quotes map[string]*Quote
+	if s.marketData != nil {
+		quotes, _ = s.marketData.GetQuotes(ctx, symbols)
+	}
+
+	asOf := s.now()
+	for _, contract := range contracts {
+		var marketPrice *decimal.Decimal
+		if quote, ok := quotes[contract.Symbol]; ok && quote != nil {
+			price := quote.Price
+			marketPrice = &price
+		}
+		response.Positions = append(response.Positions,
+			dto.ToOptionPositionResponse(holdingsBySymbol[contract.Symbol], contract, marketPrice, asOf))
+	}
+	response.Total = len(response.Positions)
+
+	return response, nil
+}
+
+// Settle closes a portfolio's whole position in a contract by expiration or assignment.
+// Contracts expire only once their expiry has passed; assignment may come early.
+func (s *optionService) Settle(ctx context.Context, portfolioID, contractID, userID string, req *dto.SettleOptionRequest) ([]*models.Transaction, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := uuid.Parse(contractID); err != nil {
+		return nil, models.ErrOptionContractNotFound
+	}
+	contract, err := s.contractRepo.FindByID(ctx, contractID)
+	if err != nil {
+		return nil, err
+	}
+
+	holding, err := s.findPosition(ctx, portfolio.ID.String(), contract.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	date := contract.Expiry
+	if req.Date != nil {
+		date = *req.Date
+	}
+
+	switch req.Action {
+	case dto.OptionSettlementExpire:
+		if date.UTC().Truncate(24 * time.Hour).Before(contract.Expiry.UTC().Truncate(24 * time.Hour)) {
+			return nil, models.ErrOptionNotExpired
+		}
+		transaction, err := s.expire(ctx, contract, holding, date)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Transaction{transaction}, nil
+	case dto.OptionSettlementAssign:
+		return s.assign(ctx, portfolio, contract, holding, date)
+	default:
+		return nil, models.ErrInvalidOptionSettlement
+	}
+}
+
+// expire records the contracts lapsing worthless. The premium paid becomes a realized loss.
+func (s *optionService) expire(ctx context.Context, contract *models.OptionContract, holding *models.Holding, date time.Time) (*models.Transaction, error) {
+	transaction := &models.Transaction{
+		PortfolioID: holding.PortfolioID,
+		Type:        models.TransactionTypeOptionExpiration,
+		Symbol:      contract.Symbol,
+		AssetType:   models.AssetTypeOption,
+		Date:        date,
+		Quantity:    holding.Quantity,
+		Multiplier:  contract.Multiplier,
+		Commission:  decimal.Zero,
+		Notes:       fmt.Sprintf("Expired worthless on %s", contract.Expiry.Format("2006-01-02")),
+	}
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if err := s.closePosition(ctx, holding, holding.Quantity); err != nil {
+		return nil, s.rollbackTransaction(ctx, transaction, fmt.Errorf("failed to update holdings: %w", err))
+	}
+	return transaction, nil
+}
+
+// assign exercises the contracts. A call buys the underlying shares at the strike and a put
+// sells them, with the premium paid added to the shares' cost or taken off the sale's
+// proceeds; the option position is then closed at its cost, so it realizes nothing itself.
+func (s *optionService) assign(ctx context.Context, portfolio *models.Portfolio, contract *models.OptionContract, holding *models.Holding, date time.Time) ([]*models.Transaction, error) {
+	multiplier := holding.Multiplier
+	if multiplier <= 1 {
+		multiplier = contract.Multiplier
+	}
+	shares := holding.Quantity.Mul(decimal.NewFromInt(int64(multiplier)))
+	premiumPerShare := holding.CostBasis.Div(shares)
+
+	underlyingType := models.TransactionTypeBuy
+	if contract.Type == models.OptionTypePut {
+		underlyingType = models.TransactionTypeSell
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	// The premium is carried as the underlying trade's commission, which a buy adds to its cost
+	// and a sell takes off its proceeds
+	underlying, err := s.transactionService.Create(ctx, portfolio.ID.String(), portfolio.UserID.String(),
+		underlyingType, contract.Underlying, date, shares, contract.Strike, holding.CostBasis, "",
+		fmt.Sprintf("Assignment of %s", contract.Symbol), "")
+	if err != nil {
+		return nil, err
+	}
+
+	transaction := &models.Transaction{
+		PortfolioID: holding.PortfolioID,
+		Type:        models.TransactionTypeOptionAssignment,
+		Symbol:      contract.Symbol,
+		AssetType:   models.AssetTypeOption,
+		Date:        date,
+		Quantity:    holding.Quantity,
+		Price:       &premiumPerShare,
+		Multiplier:  multiplier,
+		Commission:  decimal.Zero,
+		Notes:       fmt.Sprintf("Assigned into %s %s", underlyingType, contract.Underlying),
+	}
+
+	undo := func(cause error) error {
+		if deleteErr := s.transactionService.Delete(ctx, underlying.ID.String(), portfolio.UserID.String()); deleteErr != nil {
+			return fmt.Errorf("%w (rollback of %s failed: %v)", cause, contract.Underlying, deleteErr)
+		}
+		return cause
+	}
+
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
+		return nil, undo(fmt.Errorf("failed to create transaction: %w", err))
+	}
+	if err := s.closePosition(ctx, holding, holding.Quantity); err != nil {
+		return nil, undo(s.rollbackTransaction(ctx, transaction, fmt.Errorf("failed to update holdings: %w", err)))
+	}
+
+	return []*models.Transaction{transaction, underlying}, nil
+}
+
+// SettleExpired settles every position in a contract that expired before asOf: contracts that
+// finished in the money at the underlying's last close are assigned and the rest expire.
+// Positions that can't be settled are logged and left for the next run. It returns the number
+// of positions settled.
+func (s *optionService) SettleExpired(ctx context.Context, asOf time.Time) (int, error) {
+	if s.marketData == nil {
+		return 0, nil
+	}
+
+	symbols, err := s.holdingRepo.FindDistinctSymbols(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get held symbols: %w", err)
+	}
+
+	var optionSymbols []string
+	for _, symbol := range symbols {
+		if models.IsOptionSymbol(symbol) {
+			optionSymbols = append(optionSymbols, symbol)
+		}
+	}
+	if len(optionSymbols) == 0 {
+		return 0, nil
+	}
+
+	contracts, err := s.contractRepo.FindBySymbols(ctx, optionSymbols)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get option contracts: %w", err)
+	}
+	found := make(map[string]bool, len(contracts))
+	for _, contract := range contracts {
+		found[contract.Symbol] = true
+	}
+	for _, symbol := range optionSymbols {
+		if found[symbol] {
+			continue
+		}
+		if contract, err := models.ParseOptionSymbol(symbol); err == nil {
+			contracts = append(contracts, contract)
+		}
+	}
+
+	settled := 0
+	for _, contract := range contracts {
+		if err := ctx.Err(); err != nil {
+			return settled, err
+		}
+		if !contract.IsExpired(asOf) {
+			continue
+		}
+
+		closePrice, err := s.underlyingClose(ctx, contract)
+		if err != nil {
+			log.Printf("Skipping expired option %s: %v", contract.Symbol, err)
+			continue
+		}
+
+		holdings, err := s.holdingRepo.FindBySymbol(ctx, contract.Symbol)
+		if err != nil {
+			log.Printf("Skipping expired option %s: failed to get holdings: %v", contract.Symbol, err)
+			continue
+		}
+
+		for _, holding := range holdings {
+			portfolio, err := s.portfolioRepo.FindByID(ctx, holding.PortfolioID.String())
+			if err != nil {
+				log.Printf("Skipping expired option %s in portfolio %s: %v", contract.Symbol, holding.PortfolioID, err)
+				continue
+			}
+
+			if contract.IsInTheMoney(closePrice) {
+				_, err = s.assign(ctx, portfolio, contract, holding, contract.Expiry)
+			} else {
+				_, err = s.expire(ctx, contract, holding, contract.Expiry)
+			}
+			if err != nil {
+				log.Printf("Failed to settle expired option %s in portfolio %s: %v", contract.Symbol, holding.PortfolioID, err)
+				continue
+			}
+			settled++
+		}
+	}
+
+	return settled, nil
+}
+
+// underlyingClose returns the underlying's last close on or before the contract's expiry
+func (s *optionService) underlyingClose(ctx context.Context, contract *models.OptionContract) (decimal.Decimal, error) {
+	prices, err := s.marketData.GetHistoricalPrices(ctx, contract.Underlying,
+		contract.Expiry.Add(-underlyingCloseLookback), contract.Expiry)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get %s prices: %w", contract.Underlying, err)
+	}
+
+	var last *HistoricalPrice
+	for _, price := range prices {
+		if price.Date.After(contract.Expiry) {
+			continue
+		}
+		if last == nil || price.Date.After(last.Date) {
+			last = price
+		}
+	}
+	if last == nil {
+		return decimal.Zero, fmt.Errorf("no %s close on or before %s", contract.Underlying, contract.Expiry.Format("2006-01-02"))
+	}
+	return last.Close, nil
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+const testCallSymbol = "AAPL240621C00190000"
+
+func setupOptionServiceTest(t *testing.T, marketData MarketDataService) (OptionService, *gorm.DB, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.Holding{},
+		&models.OptionContract{})
+	require.NoError(t, err)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+
+	service := NewOptionService(
+		repository.NewOptionContractRepository(db),
+		portfolioRepo,
+		transactionRepo,
+		holdingRepo,
+		NewTransactionService(transactionRepo, portfolioRepo, holdingRepo),
+		marketData,
+	)
+
+	user, portfolio := createTestUserAndPortfolio(t, db)
+	return service, db, user, portfolio
+}
+
+func buyTestCalls(t *testing.T, service OptionService, user *models.User, portfolio *models.Portfolio, contracts int64, premium string) *models.Transaction {
+	transaction, err := service.Trade(context.Background(), portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+		Type:     models.TransactionTypeBuyToOpen,
+		Symbol:   testCallSymbol,
+		Date:     time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Quantity: decimal.NewFromInt(contracts),
+		Price:    decimal.RequireFromString(premium),
+	})
+	require.NoError(t, err)
+	return transaction
+}
+
+func TestOptionService_Trade(t *testing.T) {
+	ctx := context.Background()
+	service, db, user, portfolio := setupOptionServiceTest(t, nil)
+
+	t.Run("buy to open by contract terms", func(t *testing.T) {
+		strike := decimal.NewFromInt(190)
+		expiry := time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)
+		transaction, err := service.Trade(ctx, portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+			Type:       models.TransactionTypeBuyToOpen,
+			Underlying: "AAPL",
+			OptionType: models.OptionTypeCall,
+			Strike:     &strike,
+			Expiry:     &expiry,
+			Date:       time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			Quantity:   decimal.NewFromInt(2),
+			Price:      decimal.RequireFromString("5.50"),
+			Commission: decimal.NewFromInt(1),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, testCallSymbol, transaction.Symbol)
+		assert.Equal(t, models.AssetTypeOption, transaction.AssetType)
+		assert.Equal(t, 100, transaction.Multiplier)
+
+		var holding models.Holding
+		require.NoError(t, db.Where("symbol = ?", testCallSymbol).First(&holding).Error)
+		assert.True(t, decimal.NewFromInt(2).Equal(holding.Quantity))
+		assert.True(t, decimal.NewFromInt(1101).Equal(holding.CostBasis), holding.CostBasis.String())
+		assert.Equal(t, 100, holding.Multiplier)
+	})
+
+	t.Run("sell to close part of the position", func(t *testing.T) {
+		_, err := service.Trade(ctx, portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+			Type:     models.TransactionTypeSellToClose,
+			Symbol:   testCallSymbol,
+			Date:     time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(1),
+			Price:    decimal.NewFromInt(7),
+		})
+		require.NoError(t, err)
+
+		var holding models.Holding
+		require.NoError(t, db.Where("symbol = ?", testCallSymbol).First(&holding).Error)
+		assert.True(t, decimal.NewFromInt(1).Equal(holding.Quantity))
+		assert.True(t, decimal.RequireFromString("550.5").Equal(holding.CostBasis), holding.CostBasis.String())
+	})
+
+	t.Run("sell more contracts than held", func(t *testing.T) {
+		_, err := service.Trade(ctx, portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+			Type:     models.TransactionTypeSellToClose,
+			Symbol:   testCallSymbol,
+			Date:     time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(5),
+			Price:    decimal.NewFromInt(7),
+		})
+		assert.Equal(t, models.ErrInsufficientShares, err)
+	})
+
+	t.Run("sell a contract that isn't held", func(t *testing.T) {
+		_, err := service.Trade(ctx, portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+			Type:     models.TransactionTypeSellToClose,
+			Symbol:   "AAPL240621P00150000",
+			Date:     time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(1),
+			Price:    decimal.NewFromInt(1),
+		})
+		assert.Equal(t, models.ErrNoOptionPosition, err)
+	})
+
+	t.Run("fractional contracts", func(t *testing.T) {
+		_, err := service.Trade(ctx, portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+			Type:     models.TransactionTypeBuyToOpen,
+			Symbol:   testCallSymbol,
+			Date:     time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.RequireFromString("0.5"),
+			Price:    decimal.NewFromInt(1),
+		})
+		assert.Equal(t, models.ErrInvalidOptionQuantity, err)
+	})
+
+	t.Run("invalid symbol", func(t *testing.T) {
+		_, err := service.Trade(ctx, portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+			Type:     models.TransactionTypeBuyToOpen,
+			Symbol:   "AAPL",
+			Date:     time.Now(),
+			Quantity: decimal.NewFromInt(1),
+			Price:    decimal.NewFromInt(1),
+		})
+		assert.Equal(t, models.ErrInvalidOptionSymbol, err)
+	})
+
+	t.Run("unauthorized user", func(t *testing.T) {
+		_, err := service.Trade(ctx, portfolio.ID.String(), uuid.New().String(), &dto.OptionTradeRequest{})
+		assert.Equal(t, models.ErrUnauthorizedAccess, err)
+	})
+}
+
+func TestOptionService_ListPositions(t *testing.T) {
+	ctx := context.Background()
+	marketData := new(MockMarketDataService)
+	service, _, user, portfolio := setupOptionServiceTest(t, marketData)
+
+	buyTestCalls(t, service, user, portfolio, 2, "5")
+	marketData.On("GetQuotes", []string{testCallSymbol}).Return(map[string]*Quote{
+		testCallSymbol: {Symbol: testCallSymbol, Price: decimal.NewFromInt(8)},
+	}, nil)
+
+	response, err := service.ListPositions(ctx, portfolio.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	require.Equal(t, 1, response.Total)
+
+	position := response.Positions[0]
+	assert.Equal(t, "AAPL", position.Contract.Underlying)
+	assert.True(t, decimal.NewFromInt(190).Equal(position.Contract.Strike))
+	require.NotNil(t, position.MarketValue)
+	assert.True(t, decimal.NewFromInt(1600).Equal(*position.MarketValue), position.MarketValue.String())
+	assert.True(t, decimal.NewFromInt(600).Equal(*position.UnrealizedGain), position.UnrealizedGain.String())
+}
+
+func TestOptionService_Settle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("expire before expiry", func(t *testing.T) {
+		service, _, user, portfolio := setupOptionServiceTest(t, nil)
+		transaction := buyTestCalls(t, service, user, portfolio, 1, "5")
+		contract, err := models.ParseOptionSymbol(transaction.Symbol)
+		require.NoError(t, err)
+
+		listed, err := service.ListPositions(ctx, portfolio.ID.String(), user.ID.String())
+		require.NoError(t, err)
+		date := contract.Expiry.AddDate(0, 0, -1)
+
+		_, err = service.Settle(ctx, portfolio.ID.String(), listed.Positions[0].Contract.ID.String(), user.ID.String(),
+			&dto.SettleOptionRequest{Action: dto.OptionSettlementExpire, Date: &date})
+		assert.Equal(t, models.ErrOptionNotExpired, err)
+	})
+
+	t.Run("expire worthless", func(t *testing.T) {
+		service, db, user, portfolio := setupOptionServiceTest(t, nil)
+		buyTestCalls(t, service, user, portfolio, 1, "5")
+		listed, err := service.ListPositions(ctx, portfolio.ID.String(), user.ID.String())
+		require.NoError(t, err)
+
+		transactions, err := service.Settle(ctx, portfolio.ID.String(), listed.Positions[0].Contract.ID.String(), user.ID.String(),
+			&dto.SettleOptionRequest{Action: dto.OptionSettlementExpire})
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+		assert.Equal(t, models.TransactionTypeOptionExpiration, transactions[0].Type)
+
+		var count int64
+		db.Model(&models.Holding{}).Where("portfolio_id = ?", portfolio.ID).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("assign a call into the underlying", func(t *testing.T) {
+		service, db, user, portfolio := setupOptionServiceTest(t, nil)
+		buyTestCalls(t, service, user, portfolio, 2, "5")
+		listed, err := service.ListPositions(ctx, portfolio.ID.String(), user.ID.String())
+		require.NoError(t, err)
+
+		transactions, err := service.Settle(ctx, portfolio.ID.String(), listed.Positions[0].Contract.ID.String(), user.ID.String(),
+			&dto.SettleOptionRequest{Action: dto.OptionSettlementAssign})
+		require.NoError(t, err)
+		require.Len(t, transactions, 2)
+		assert.Equal(t, models.TransactionTypeOptionAssignment, transactions[0].Type)
+		assert.True(t, decimal.NewFromInt(1000).Equal(transactions[0].GetProceeds()))
+		assert.Equal(t, models.TransactionTypeBuy, transactions[1].Type)
+
+		var holdings []models.Holding
+		require.NoError(t, db.Where("portfolio_id = ?", portfolio.ID).Find(&holdings).Error)
+		require.Len(t, holdings, 1)
+		assert.Equal(t, "AAPL", holdings[0].Symbol)
+		assert.True(t, decimal.NewFromInt(200).Equal(holdings[0].Quantity))
+		// 200 shares at the 190 strike plus the 1000 premium paid
+		assert.True(t, decimal.NewFromInt(39000).Equal(holdings[0].CostBasis), holdings[0].CostBasis.String())
+	})
+
+	t.Run("assign a put without the underlying", func(t *testing.T) {
+		service, db, user, portfolio := setupOptionServiceTest(t, nil)
+		_, err := service.Trade(ctx, portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+			Type:     models.TransactionTypeBuyToOpen,
+			Symbol:   "AAPL240621P00150000",
+			Date:     time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			Quantity: decimal.NewFromInt(1),
+			Price:    decimal.NewFromInt(2),
+		})
+		require.NoError(t, err)
+		listed, err := service.ListPositions(ctx, portfolio.ID.String(), user.ID.String())
+		require.NoError(t, err)
+
+		_, err = service.Settle(ctx, portfolio.ID.String(), listed.Positions[0].Contract.ID.String(), user.ID.String(),
+			&dto.SettleOptionRequest{Action: dto.OptionSettlementAssign})
+		assert.ErrorIs(t, err, models.ErrInsufficientShares)
+
+		var holding models.Holding
+		require.NoError(t, db.Where("symbol = ?", "AAPL240621P00150000").First(&holding).Error)
+		assert.True(t, decimal.NewFromInt(1).Equal(holding.Quantity))
+	})
+
+	t.Run("unknown contract", func(t *testing.T) {
+		service, _, user, portfolio := setupOptionServiceTest(t, nil)
+		_, err := service.Settle(ctx, portfolio.ID.String(), uuid.New().String(), user.ID.String(),
+			&dto.SettleOptionRequest{Action: dto.OptionSettlementExpire})
+		assert.Equal(t, models.ErrOptionContractNotFound, err)
+	})
+}
+
+func TestOptionService_SettleExpired(t *testing.T) {
+	ctx := context.Background()
+	marketData := new(MockMarketDataService)
+	service, db, user, portfolio := setupOptionServiceTest(t, marketData)
+
+	buyTestCalls(t, service, user, portfolio, 1, "5")
+	_, err := service.Trade(ctx, portfolio.ID.String(), user.ID.String(), &dto.OptionTradeRequest{
+		Type:     models.TransactionTypeBuyToOpen,
+		Symbol:   "AAPL240621C00250000",
+		Date:     time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Quantity: decimal.NewFromInt(1),
+		Price:    decimal.RequireFromString("0.10"),
+	})
+	require.NoError(t, err)
+
+	marketData.On("GetHistoricalPrices", "AAPL", mock.Anything, mock.Anything).Return([]*HistoricalPrice{
+		{Date: time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC), Close: decimal.NewFromInt(180)},
+		{Date: time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC), Close: decimal.NewFromInt(200)},
+	}, nil)
+
+	settled, err := service.SettleExpired(ctx, time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 0, settled)
+
+	settled, err = service.SettleExpired(ctx, time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 2, settled)
+
+	var transactions []models.Transaction
+	require.NoError(t, db.Where("portfolio_id = ?", portfolio.ID).Order("symbol").Find(&transactions).Error)
+	types := make(map[string][]models.TransactionType)
+	for _, transaction := range transactions {
+		types[transaction.Symbol] = append(types[transaction.Symbol], transaction.Type)
+	}
+	assert.ElementsMatch(t, []models.TransactionType{models.TransactionTypeBuyToOpen, models.TransactionTypeOptionAssignment}, types[testCallSymbol])
+	assert.ElementsMatch(t, []models.TransactionType{models.TransactionTypeBuyToOpen, models.TransactionTypeOptionExpiration}, types["AAPL240621C00250000"])
+	assert.Equal(t, []models.TransactionType{models.TransactionTypeBuy}, types["AAPL"])
+}
@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupOrganizationServiceTest(t *testing.T) (*gorm.DB, OrganizationService, *mockEmailService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Organization{}, &models.APIKey{}, &models.Portfolio{},
+		&models.OrganizationMember{}, &models.OrganizationInvitation{},
+	))
+
+	emailService := newMockEmailService()
+	return db, NewOrganizationService(db, emailService, 24*time.Hour), emailService
+}
+
+func createOrganizationTestUser(t *testing.T, db *gorm.DB, email string) string {
+	user := &models.User{Email: email, PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	return user.ID.String()
+}
+
+// addOrganizationMember invites email to the organization with role and accepts the invitation
+func addOrganizationMember(
+	t *testing.T,
+	service OrganizationService,
+	organizationID, ownerID, userID, email string,
+	role models.OrganizationRole,
+) {
+	issued, err := service.Invite(context.Background(), organizationID, ownerID, email, role)
+	require.NoError(t, err)
+	_, err = service.AcceptInvitation(context.Background(), userID, issued.Token)
+	require.NoError(t, err)
+}
+
+func TestOrganizationService_CreateAndInvite(t *testing.T) {
+	ctx := context.Background()
+	db, service, emailService := setupOrganizationServiceTest(t)
+	ownerID := createOrganizationTestUser(t, db, "owner@example.com")
+	inviteeID := createOrganizationTestUser(t, db, "invitee@example.com")
+
+	organization, err := service.Create(ctx, ownerID, "  Acme Advisors ")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme Advisors", organization.Name)
+	assert.Equal(t, organization.ID.String(), organization.ExternalID)
+	orgID := organization.ID.String()
+
+	memberships, err := service.ListMemberships(ctx, ownerID)
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, models.OrgRoleOwner, memberships[0].Role)
+
+	_, err = service.Create(ctx, ownerID, " ")
+	assert.Equal(t, models.ErrOrganizationNameRequired, err)
+
+	issued, err := service.Invite(ctx, orgID, ownerID, " Invitee@Example.com", models.OrgRoleMember)
+	require.NoError(t, err)
+	assert.True(t, issued.EmailSent)
+	assert.NotEmpty(t, issued.Token)
+	assert.Equal(t, "invitee@example.com", issued.Invitation.Email)
+	require.Len(t, emailService.sentEmails, 1)
+	assert.Equal(t, issued.Token, emailService.sentEmails[0].token)
+
+	invitations, err := service.ListInvitations(ctx, orgID, ownerID)
+	require.NoError(t, err)
+	assert.Len(t, invitations, 1)
+
+	// Only the invited email can accept
+	otherID := createOrganizationTestUser(t, db, "other@example.com")
+	_, err = service.AcceptInvitation(ctx, otherID, issued.Token)
+	assert.Equal(t, models.ErrInvitationEmailMismatch, err)
+
+	member, err := service.AcceptInvitation(ctx, inviteeID, issued.Token)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleMember, member.Role)
+
+	// An invitation works once, and members cannot be invited again
+	_, err = service.AcceptInvitation(ctx, inviteeID, issued.Token)
+	assert.Equal(t, models.ErrInvalidInvitation, err)
+	_, err = service.Invite(ctx, orgID, ownerID, "invitee@example.com", models.OrgRoleAdmin)
+	assert.Equal(t, models.ErrAlreadyOrganizationMember, err)
+
+	// Members cannot invite, and nobody outside the organization can see it
+	_, err = service.Invite(ctx, orgID, inviteeID, "new@example.com", models.OrgRoleViewer)
+	assert.Equal(t, models.ErrInsufficientOrganizationRole, err)
+	_, err = service.ListMembers(ctx, orgID, otherID)
+	assert.Equal(t, models.ErrNotOrganizationMember, err)
+
+	members, err := service.ListMembers(ctx, orgID, inviteeID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	_, err = service.Invite(ctx, orgID, ownerID, "new@example.com", "superuser")
+	assert.Equal(t, models.ErrInvalidOrganizationRole, err)
+}
+
+func TestOrganizationService_AcceptInvitation_ExpiredAndQuota(t *testing.T) {
+	ctx := context.Background()
+	db, service, _ := setupOrganizationServiceTest(t)
+	ownerID := createOrganizationTestUser(t, db, "owner@example.com")
+	inviteeID := createOrganizationTestUser(t, db, "invitee@example.com")
+
+	organization, err := service.Create(ctx, ownerID, "Acme")
+	require.NoError(t, err)
+	orgID := organization.ID.String()
+
+	issued, err := service.Invite(ctx, orgID, ownerID, "invitee@example.com", models.OrgRoleMember)
+	require.NoError(t, err)
+
+	// The organization is already at its user quota
+	organization.MaxUsers = 1
+	require.NoError(t, repository.NewOrganizationRepository(db).Save(organization))
+	_, err = service.AcceptInvitation(ctx, inviteeID, issued.Token)
+	assert.Equal(t, models.ErrUserQuotaExceeded, err)
+
+	service.(*organizationService).now = func() time.Time { return time.Now().UTC().Add(48 * time.Hour) }
+	_, err = service.AcceptInvitation(ctx, inviteeID, issued.Token)
+	assert.Equal(t, models.ErrInvalidInvitation, err)
+
+	_, err = service.AcceptInvitation(ctx, inviteeID, "unknown")
+	assert.Equal(t, models.ErrInvalidInvitation, err)
+}
+
+func TestOrganizationService_Roles(t *testing.T) {
+	ctx := context.Background()
+	db, service, _ := setupOrganizationServiceTest(t)
+	ownerID := createOrganizationTestUser(t, db, "owner@example.com")
+	adminID := createOrganizationTestUser(t, db, "admin@example.com")
+	memberID := createOrganizationTestUser(t, db, "member@example.com")
+
+	organization, err := service.Create(ctx, ownerID, "Acme")
+	require.NoError(t, err)
+	orgID := organization.ID.String()
+	addOrganizationMember(t, service, orgID, ownerID, adminID, "admin@example.com", models.OrgRoleAdmin)
+	addOrganizationMember(t, service, orgID, ownerID, memberID, "member@example.com", models.OrgRoleMember)
+
+	// Admins manage members but cannot grant or touch the owner role
+	member, err := service.SetMemberRole(ctx, orgID, adminID, memberID, models.OrgRoleViewer)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleViewer, member.Role)
+	_, err = service.SetMemberRole(ctx, orgID, adminID, memberID, models.OrgRoleOwner)
+	assert.Equal(t, models.ErrInsufficientOrganizationRole, err)
+	_, err = service.SetMemberRole(ctx, orgID, adminID, ownerID, models.OrgRoleMember)
+	assert.Equal(t, models.ErrInsufficientOrganizationRole, err)
+	_, err = service.SetMemberRole(ctx, orgID, memberID, adminID, models.OrgRoleViewer)
+	assert.Equal(t, models.ErrInsufficientOrganizationRole, err)
+
+	// The last owner can neither be demoted nor removed
+	_, err = service.SetMemberRole(ctx, orgID, ownerID, ownerID, models.OrgRoleAdmin)
+	assert.Equal(t, models.ErrLastOrganizationOwner, err)
+	assert.Equal(t, models.ErrLastOrganizationOwner, service.RemoveMember(ctx, orgID, ownerID, ownerID))
+
+	_, err = service.SetMemberRole(ctx, orgID, ownerID, adminID, models.OrgRoleOwner)
+	require.NoError(t, err)
+	_, err = service.SetMemberRole(ctx, orgID, ownerID, ownerID, models.OrgRoleAdmin)
+	require.NoError(t, err)
+
+	// Anyone may leave
+	require.NoError(t, service.RemoveMember(ctx, orgID, memberID, memberID))
+	_, err = service.ResolveScope(ctx, orgID, memberID)
+	assert.Equal(t, models.ErrNotOrganizationMember, err)
+}
+
+func TestOrganizationService_RemoveMember_HandsOverPortfoliosAndRevokesKeys(t *testing.T) {
+	ctx := context.Background()
+	db, service, _ := setupOrganizationServiceTest(t)
+	ownerID := createOrganizationTestUser(t, db, "owner@example.com")
+	adminID := createOrganizationTestUser(t, db, "admin@example.com")
+
+	organization, err := service.Create(ctx, ownerID, "Acme")
+	require.NoError(t, err)
+	orgID := organization.ID.String()
+	addOrganizationMember(t, service, orgID, ownerID, adminID, "admin@example.com", models.OrgRoleAdmin)
+
+	admin, err := repository.NewUserRepository(db).FindByID(adminID)
+	require.NoError(t, err)
+	portfolio := &models.Portfolio{
+		UserID:          admin.ID,
+		OrganizationID:  &organization.ID,
+		Name:            "Client",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	issued, err := service.CreateAPIKey(ctx, orgID, adminID, "Reporting")
+	require.NoError(t, err)
+	assert.True(t, issued.Created)
+	assert.Contains(t, issued.Secret, models.APIKeyPrefix)
+	assert.Equal(t, "Reporting", issued.Key.Name)
+
+	require.NoError(t, service.RemoveMember(ctx, orgID, ownerID, adminID))
+
+	reloaded, err := repository.NewPortfolioRepository(db).FindByID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, ownerID, reloaded.UserID.String())
+
+	keys, err := service.ListAPIKeys(ctx, orgID, ownerID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.False(t, keys[0].IsActive())
+}
+
+func TestOrganizationService_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	db, service, _ := setupOrganizationServiceTest(t)
+	ownerID := createOrganizationTestUser(t, db, "owner@example.com")
+	viewerID := createOrganizationTestUser(t, db, "viewer@example.com")
+
+	organization, err := service.Create(ctx, ownerID, "Acme")
+	require.NoError(t, err)
+	orgID := organization.ID.String()
+	addOrganizationMember(t, service, orgID, ownerID, viewerID, "viewer@example.com", models.OrgRoleViewer)
+
+	_, err = service.CreateAPIKey(ctx, orgID, viewerID, "Mine")
+	assert.Equal(t, models.ErrInsufficientOrganizationRole, err)
+
+	organization.MaxAPIKeys = 1
+	require.NoError(t, repository.NewOrganizationRepository(db).Save(organization))
+	issued, err := service.CreateAPIKey(ctx, orgID, ownerID, "")
+	require.NoError(t, err)
+	assert.Equal(t, issued.Key.ExternalID, issued.Key.Name)
+	_, err = service.CreateAPIKey(ctx, orgID, ownerID, "Second")
+	assert.Equal(t, models.ErrAPIKeyQuotaExceeded, err)
+
+	// The key authenticates as the user who issued it
+	authenticated, err := NewAPIKeyService(repository.NewAPIKeyRepository(db)).Authenticate(issued.Secret)
+	require.NoError(t, err)
+	assert.Equal(t, ownerID, authenticated.UserID.String())
+
+	// Revoking frees the quota, and revoking again succeeds
+	require.NoError(t, service.RevokeAPIKey(ctx, orgID, ownerID, issued.Key.ID.String()))
+	require.NoError(t, service.RevokeAPIKey(ctx, orgID, ownerID, issued.Key.ID.String()))
+	_, err = service.CreateAPIKey(ctx, orgID, ownerID, "Second")
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ErrAPIKeyNotFound, service.RevokeAPIKey(ctx, orgID, ownerID, "not-a-key"))
+}
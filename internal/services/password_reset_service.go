@@ -34,6 +34,7 @@ type passwordResetService struct {
 	tokenRepo             repository.PasswordResetRepository
 	emailService          EmailService
 	tokenValidityDuration time.Duration
+	passwords             PasswordService
 }
 
 // NewPasswordResetService creates a new PasswordResetService instance
@@ -42,12 +43,31 @@ func NewPasswordResetService(
 	tokenRepo repository.PasswordResetRepository,
 	emailService EmailService,
 	tokenValidityDuration time.Duration,
+) PasswordResetService {
+	return NewPasswordResetServiceWithPasswords(
+		userRepo,
+		tokenRepo,
+		emailService,
+		tokenValidityDuration,
+		NewPasswordService(userRepo, nil, models.DefaultPasswordPolicy(), utils.DefaultPasswordHasher()),
+	)
+}
+
+// NewPasswordResetServiceWithPasswords creates a new PasswordResetService instance that sets
+// new passwords through passwords, so resets follow its password policy and history
+func NewPasswordResetServiceWithPasswords(
+	userRepo repository.UserRepository,
+	tokenRepo repository.PasswordResetRepository,
+	emailService EmailService,
+	tokenValidityDuration time.Duration,
+	passwords PasswordService,
 ) PasswordResetService {
 	return &passwordResetService{
 		userRepo:              userRepo,
 		tokenRepo:             tokenRepo,
 		emailService:          emailService,
 		tokenValidityDuration: tokenValidityDuration,
+		passwords:             passwords,
 	}
 }
 
@@ -109,12 +129,12 @@ func (s *passwordResetService) ValidateResetToken(token string) (*models.Passwor
 	// Find token in database
 	resetToken, err := s.tokenRepo.FindByTokenHash(tokenHash)
 	if err != nil {
-		return nil, fmt.Errorf("invalid or expired reset token")
+		return nil, models.ErrInvalidResetToken
 	}
 
 	// Check if token is valid (not expired and not used)
 	if !resetToken.IsValid() {
-		return nil, fmt.Errorf("invalid or expired reset token")
+		return nil, models.ErrInvalidResetToken
 	}
 
 	return resetToken, nil
@@ -137,23 +157,17 @@ func (s *passwordResetService) ResetPassword(token, newPassword string) error {
 
 	// Check if token is expired
 	if time.Now().UTC().After(resetToken.ExpiresAt) {
-		return fmt.Errorf("reset token has expired")
+		return fmt.Errorf("%w: the token has expired", models.ErrInvalidResetToken)
 	}
 
 	// Check if token was already used
 	if resetToken.UsedAt != nil {
-		return fmt.Errorf("reset token has already been used")
+		return fmt.Errorf("%w: the token has already been used", models.ErrInvalidResetToken)
 	}
 
-	// Validate and hash new password
-	hashedPassword, err := utils.ValidateAndHashPassword(newPassword)
-	if err != nil {
-		return fmt.Errorf("invalid password: %w", err)
-	}
-
-	// Update user's password
-	if err := s.userRepo.UpdatePassword(resetToken.UserID.String(), hashedPassword); err != nil {
-		return fmt.Errorf("failed to update password: %w", err)
+	// Validate and set the new password
+	if err := s.passwords.SetPassword(resetToken.UserID.String(), newPassword); err != nil {
+		return err
 	}
 
 	// Mark token as used
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/stretchr/testify/assert"
 )
@@ -80,6 +81,46 @@ func (m *mockEmailService) SendPasswordResetEmail(to, resetToken string) error {
 	return nil
 }
 
+func (m *mockEmailService) SendRebalancePlanReminderEmail(to, planName string, pendingTrades int, lastActivity time.Time) error {
+	if m.shouldFail {
+		return fmt.Errorf("failed to send email")
+	}
+	m.sentEmails = append(m.sentEmails, sentEmail{to: to})
+	return nil
+}
+
+func (m *mockEmailService) SendInviteEmail(to, organizationName, inviteToken string, expiresAt time.Time) error {
+	if m.shouldFail {
+		return fmt.Errorf("failed to send email")
+	}
+	m.sentEmails = append(m.sentEmails, sentEmail{to: to, token: inviteToken})
+	return nil
+}
+
+func (m *mockEmailService) SendOrganizationInviteEmail(to, organizationName, inviterEmail string, role models.OrganizationRole, inviteToken string, expiresAt time.Time) error {
+	if m.shouldFail {
+		return fmt.Errorf("failed to send email")
+	}
+	m.sentEmails = append(m.sentEmails, sentEmail{to: to, token: inviteToken})
+	return nil
+}
+
+func (m *mockEmailService) SendDelegationRequestEmail(to, advisorEmail string, access models.DelegationAccess, consentToken string, expiresAt time.Time) error {
+	if m.shouldFail {
+		return fmt.Errorf("failed to send email")
+	}
+	m.sentEmails = append(m.sentEmails, sentEmail{to: to, token: consentToken})
+	return nil
+}
+
+func (m *mockEmailService) SendPerformanceDigestEmail(to string, digest *dto.PerformanceDigest, unsubscribeToken string) error {
+	if m.shouldFail {
+		return fmt.Errorf("failed to send email")
+	}
+	m.sentEmails = append(m.sentEmails, sentEmail{to: to, token: unsubscribeToken})
+	return nil
+}
+
 func TestNewPasswordResetService(t *testing.T) {
 	userRepo := newMockUserRepository()
 	tokenRepo := newMockPasswordResetRepository()
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/utils"
+)
+
+// PasswordService defines the interface for replacing users' passwords under the password
+// policy
+type PasswordService interface {
+	// ChangePassword replaces the password of a user who knows their current one
+	ChangePassword(userID, currentPassword, newPassword string) error
+	// SetPassword replaces a user's password, for callers that have verified the user
+	// another way, such as with a reset token
+	SetPassword(userID, newPassword string) error
+}
+
+// passwordService implements PasswordService interface
+type passwordService struct {
+	userRepo    repository.UserRepository
+	historyRepo repository.PasswordHistoryRepository
+	policy      models.PasswordPolicy
+	hasher      utils.PasswordHasher
+}
+
+// NewPasswordService creates a new PasswordService instance that hashes new passwords with
+// hasher. historyRepo may be nil, in which case only the current password is checked for
+// reuse.
+func NewPasswordService(
+	userRepo repository.UserRepository,
+	historyRepo repository.PasswordHistoryRepository,
+	policy models.PasswordPolicy,
+	hasher utils.PasswordHasher,
+) PasswordService {
+	return &passwordService{
+		userRepo:    userRepo,
+		historyRepo: historyRepo,
+		policy:      policy,
+		hasher:      hasher,
+	}
+}
+
+// ChangePassword replaces the password of a user who knows their current one
+func (s *passwordService) ChangePassword(userID, currentPassword, newPassword string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := s.hasher.Check(currentPassword, user.PasswordHash); err != nil {
+		return models.ErrIncorrectPassword
+	}
+
+	return s.replacePassword(user, newPassword)
+}
+
+// SetPassword replaces a user's password
+func (s *passwordService) SetPassword(userID, newPassword string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	return s.replacePassword(user, newPassword)
+}
+
+// replacePassword checks a new password against the policy and the user's recent passwords,
+// then stores it and remembers the one it replaces
+func (s *passwordService) replacePassword(user *models.User, newPassword string) error {
+	if err := s.policy.Check(newPassword); err != nil {
+		return fmt.Errorf("%w: %w", models.ErrInvalidPassword, err)
+	}
+
+	if err := s.checkReuse(user, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	// The replaced password is recorded first, so a failure can't leave it forgotten
+	remember := s.historyRepo != nil && s.policy.HistorySize > 1
+	if remember {
+		if err := s.historyRepo.Create(&models.PasswordHistory{
+			ID:           uuid.New(),
+			UserID:       user.ID,
+			PasswordHash: user.PasswordHash,
+		}); err != nil {
+			return fmt.Errorf("failed to record password history: %w", err)
+		}
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID.String(), hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if remember {
+		// The current password counts towards the history size
+		if err := s.historyRepo.DeleteAllButRecent(user.ID.String(), s.policy.HistorySize-1); err != nil {
+			return fmt.Errorf("failed to prune password history: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// checkReuse returns ErrPasswordReused if the password is one of the user's HistorySize most
+// recent passwords
+func (s *passwordService) checkReuse(user *models.User, newPassword string) error {
+	if s.policy.HistorySize == 0 {
+		return nil
+	}
+
+	if s.hasher.Check(newPassword, user.PasswordHash) == nil {
+		return models.ErrPasswordReused
+	}
+
+	if s.historyRepo == nil || s.policy.HistorySize == 1 {
+		return nil
+	}
+
+	entries, err := s.historyRepo.FindRecentByUserID(user.ID.String(), s.policy.HistorySize-1)
+	if err != nil {
+		return fmt.Errorf("failed to check password history: %w", err)
+	}
+	for _, entry := range entries {
+		if s.hasher.Check(newPassword, entry.PasswordHash) == nil {
+			return models.ErrPasswordReused
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/utils"
+)
+
+func setupPasswordService(t *testing.T, policy models.PasswordPolicy) (PasswordService, repository.UserRepository, *models.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.PasswordHistory{}))
+
+	hash, err := utils.HashPassword("Original123")
+	require.NoError(t, err)
+	user := &models.User{Email: "test@example.com", PasswordHash: hash}
+	require.NoError(t, db.Create(user).Error)
+
+	userRepo := repository.NewUserRepository(db)
+	return NewPasswordService(userRepo, repository.NewPasswordHistoryRepository(db), policy, utils.DefaultPasswordHasher()), userRepo, user
+}
+
+func TestPasswordService_ChangePassword(t *testing.T) {
+	service, userRepo, user := setupPasswordService(t, models.DefaultPasswordPolicy())
+
+	err := service.ChangePassword(user.ID.String(), "Wrong12345", "Changed123")
+	assert.ErrorIs(t, err, models.ErrIncorrectPassword)
+
+	err = service.ChangePassword(user.ID.String(), "Original123", "weak")
+	assert.ErrorContains(t, err, "invalid password")
+
+	require.NoError(t, service.ChangePassword(user.ID.String(), "Original123", "Changed123"))
+
+	updated, err := userRepo.FindByID(user.ID.String())
+	require.NoError(t, err)
+	assert.NoError(t, utils.CheckPassword("Changed123", updated.PasswordHash))
+}
+
+func TestPasswordService_History(t *testing.T) {
+	policy := models.DefaultPasswordPolicy()
+	policy.HistorySize = 3
+	service, _, user := setupPasswordService(t, policy)
+	userID := user.ID.String()
+
+	// The current password counts towards the history
+	assert.ErrorIs(t, service.SetPassword(userID, "Original123"), models.ErrPasswordReused)
+
+	require.NoError(t, service.SetPassword(userID, "Second1234"))
+	require.NoError(t, service.SetPassword(userID, "Third12345"))
+	assert.ErrorIs(t, service.SetPassword(userID, "Original123"), models.ErrPasswordReused)
+	assert.ErrorIs(t, service.SetPassword(userID, "Second1234"), models.ErrPasswordReused)
+
+	// Once three newer passwords have been used, the first may be used again
+	require.NoError(t, service.SetPassword(userID, "Fourth1234"))
+	assert.NoError(t, service.SetPassword(userID, "Original123"))
+}
+
+func TestPasswordService_NoHistory(t *testing.T) {
+	service, _, user := setupPasswordService(t, models.DefaultPasswordPolicy())
+
+	assert.NoError(t, service.SetPassword(user.ID.String(), "Original123"))
+}
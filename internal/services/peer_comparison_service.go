@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type alias for dto type for consistency with the other analytics services
+type PeerComparisonResult = dto.PeerComparisonResult
+
+// allocationBuckets lists the buckets benchmarks are published for, in a stable order
+var allocationBuckets = []models.AllocationBucket{
+	models.AllocationBucketConcentrated,
+	models.AllocationBucketFocused,
+	models.AllocationBucketDiversified,
+}
+
+// PeerComparisonService defines the interface for opt-in peer percentile comparison
+type PeerComparisonService interface {
+	SetOptIn(ctx context.Context, portfolioID, userID string, optIn bool) (*models.Portfolio, error)
+	GetComparison(ctx context.Context, portfolioID, userID string) (*PeerComparisonResult, error)
+	AggregateBenchmarks(ctx context.Context) (int, error)
+}
+
+// peerComparisonService implements PeerComparisonService interface
+type peerComparisonService struct {
+	portfolioRepo repository.PortfolioRepository
+	holdingRepo   repository.HoldingRepository
+	snapshotRepo  repository.PerformanceSnapshotRepository
+	benchmarkRepo repository.PeerBenchmarkRepository
+	now           func() time.Time
+}
+
+// NewPeerComparisonService creates a new PeerComparisonService instance
+func NewPeerComparisonService(
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	benchmarkRepo repository.PeerBenchmarkRepository,
+) PeerComparisonService {
+	return &peerComparisonService{
+		portfolioRepo: portfolioRepo,
+		holdingRepo:   holdingRepo,
+		snapshotRepo:  snapshotRepo,
+		benchmarkRepo: benchmarkRepo,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *peerComparisonService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// SetOptIn records the owner's consent for a portfolio to contribute to, and be compared
+// against, peer benchmarks. Opting out takes effect at the next aggregation run.
+func (s *peerComparisonService) SetOptIn(ctx context.Context, portfolioID, userID string, optIn bool) (*models.Portfolio, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.portfolioRepo.SetPeerComparisonOptIn(ctx, portfolioID, optIn); err != nil {
+		return nil, fmt.Errorf("failed to update peer comparison opt-in: %w", err)
+	}
+
+	portfolio.PeerComparisonOptIn = optIn
+	return portfolio, nil
+}
+
+// GetComparison reports which percentile of its allocation bucket a portfolio's
+// risk-adjusted return falls into. Only portfolios that contribute may compare.
+func (s *peerComparisonService) GetComparison(ctx context.Context, portfolioID, userID string) (*PeerComparisonResult, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !portfolio.PeerComparisonOptIn {
+		return nil, models.ErrPeerComparisonNotOptedIn
+	}
+
+	bucket, value, err := s.measure(ctx, portfolio)
+	if err != nil {
+		return nil, err
+	}
+
+	benchmark, err := s.benchmarkRepo.FindByBucket(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PeerComparisonResult{
+		PortfolioID:        portfolio.ID.String(),
+		Bucket:             bucket,
+		LookbackDays:       models.PeerBenchmarkLookbackDays,
+		RiskAdjustedReturn: value,
+		Percentile:         benchmark.Percentile(value),
+		PeerCount:          benchmark.SampleSize,
+		Distribution: dto.PeerDistribution{
+			P10: benchmark.P10,
+			P25: benchmark.P25,
+			P50: benchmark.P50,
+			P75: benchmark.P75,
+			P90: benchmark.P90,
+		},
+		ComputedAt: benchmark.ComputedAt,
+	}, nil
+}
+
+// AggregateBenchmarks recomputes the published distribution for every allocation bucket
+// from opted-in portfolios and returns how many buckets were published. Each user counts
+// once per bucket, with the average of their portfolios in it, so a single user cannot
+// make up a bucket's sample alone. Buckets with too few users are not published.
+func (s *peerComparisonService) AggregateBenchmarks(ctx context.Context) (int, error) {
+	portfolios, err := s.portfolioRepo.FindPeerComparisonOptedIn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list opted-in portfolios: %w", err)
+	}
+
+	type userValues struct {
+		sum   float64
+		count int
+	}
+	byBucket := make(map[models.AllocationBucket]map[string]*userValues)
+	for _, portfolio := range portfolios {
+		bucket, value, err := s.measure(ctx, portfolio)
+		if err != nil {
+			if !errors.Is(err, models.ErrInsufficientPeerComparisonData) {
+				log.Printf("Error measuring portfolio %s for peer comparison: %v", portfolio.ID, err)
+			}
+			continue
+		}
+
+		users, ok := byBucket[bucket]
+		if !ok {
+			users = make(map[string]*userValues)
+			byBucket[bucket] = users
+		}
+		userID := portfolio.UserID.String()
+		if users[userID] == nil {
+			users[userID] = &userValues{}
+		}
+		f, _ := value.Float64()
+		users[userID].sum += f
+		users[userID].count++
+	}
+
+	computedAt := s.now()
+	benchmarks := make([]*models.PeerBenchmark, 0, len(allocationBuckets))
+	for _, bucket := range allocationBuckets {
+		values := make([]float64, 0, len(byBucket[bucket]))
+		for _, user := range byBucket[bucket] {
+			values = append(values, user.sum/float64(user.count))
+		}
+		if benchmark := models.NewPeerBenchmark(bucket, values, computedAt); benchmark != nil {
+			benchmarks = append(benchmarks, benchmark)
+		}
+	}
+
+	if err := s.benchmarkRepo.ReplaceAll(ctx, benchmarks); err != nil {
+		return 0, fmt.Errorf("failed to publish peer benchmarks: %w", err)
+	}
+
+	return len(benchmarks), nil
+}
+
+// measure classifies a portfolio and computes its trailing risk-adjusted return
+func (s *peerComparisonService) measure(ctx context.Context, portfolio *models.Portfolio) (models.AllocationBucket, decimal.Decimal, error) {
+	holdings, err := s.holdingRepo.FindByPortfolioID(ctx, portfolio.ID.String())
+	if err != nil {
+		return "", decimal.Zero, fmt.Errorf("failed to load holdings: %w", err)
+	}
+
+	bucket, ok := models.ClassifyAllocationBucket(holdings)
+	if !ok {
+		return "", decimal.Zero, models.ErrInsufficientPeerComparisonData
+	}
+
+	end := s.now()
+	start := end.AddDate(0, 0, -models.PeerBenchmarkLookbackDays)
+	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolio.ID.String(), start, end)
+	if err != nil {
+		return "", decimal.Zero, fmt.Errorf("failed to load performance snapshots: %w", err)
+	}
+
+	value, ok := models.RiskAdjustedReturn(snapshots)
+	if !ok {
+		return "", decimal.Zero, models.ErrInsufficientPeerComparisonData
+	}
+
+	return bucket, value, nil
+}
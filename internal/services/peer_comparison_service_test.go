@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+var peerTestNow = time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+func setupPeerComparisonTest(t *testing.T) (*gorm.DB, *peerComparisonService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.AutoMigrate(
+		&models.User{},
+		&models.Portfolio{},
+		&models.Holding{},
+		&models.PerformanceSnapshot{},
+		&models.PeerBenchmark{},
+	))
+
+	service := NewPeerComparisonService(
+		repository.NewPortfolioRepository(db),
+		repository.NewHoldingRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+		repository.NewPeerBenchmarkRepository(db),
+	).(*peerComparisonService)
+	service.now = func() time.Time { return peerTestNow }
+
+	return db, service
+}
+
+// createPeerPortfolio creates an opted-in, single-position portfolio with 60 days of
+// snapshots whose daily gain alternates between up and down
+func createPeerPortfolio(t *testing.T, db *gorm.DB, user *models.User, up, down float64) *models.Portfolio {
+	if user == nil {
+		user = &models.User{Email: fmt.Sprintf("%s@example.com", uuid.New()), PasswordHash: "hash"}
+		require.NoError(t, db.Create(user).Error)
+	}
+
+	portfolio := &models.Portfolio{
+		UserID:              user.ID,
+		Name:                "Peer " + uuid.New().String(),
+		BaseCurrency:        "USD",
+		CostBasisMethod:     models.CostBasisFIFO,
+		PeerComparisonOptIn: true,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID:  portfolio.ID,
+		Symbol:       "AAPL",
+		Quantity:     decimal.NewFromInt(100),
+		CostBasis:    decimal.NewFromInt(10000),
+		AvgCostPrice: decimal.NewFromInt(100),
+	}).Error)
+
+	gain := decimal.Zero
+	for i := 60; i >= 0; i-- {
+		if i < 60 {
+			step := up
+			if i%2 == 0 {
+				step = down
+			}
+			gain = gain.Add(decimal.NewFromFloat(step))
+		}
+		require.NoError(t, db.Create(&models.PerformanceSnapshot{
+			PortfolioID:    portfolio.ID,
+			Date:           peerTestNow.AddDate(0, 0, -i),
+			TotalValue:     decimal.NewFromInt(10000).Add(gain),
+			TotalCostBasis: decimal.NewFromInt(10000),
+			TotalReturn:    gain,
+			TotalReturnPct: decimal.Zero,
+		}).Error)
+	}
+
+	return portfolio
+}
+
+func TestPeerComparisonService_AggregateBenchmarks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("publishes buckets with enough users", func(t *testing.T) {
+		db, service := setupPeerComparisonTest(t)
+		for i := 0; i < models.PeerBenchmarkMinSampleSize; i++ {
+			createPeerPortfolio(t, db, nil, float64(10+i*5), -10)
+		}
+
+		published, err := service.AggregateBenchmarks(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, published)
+
+		var benchmark models.PeerBenchmark
+		require.NoError(t, db.First(&benchmark).Error)
+		assert.Equal(t, models.AllocationBucketConcentrated, benchmark.Bucket)
+		assert.Equal(t, models.PeerBenchmarkMinSampleSize, benchmark.SampleSize)
+		assert.True(t, benchmark.P10.LessThan(benchmark.P90))
+	})
+
+	t.Run("a single user cannot fill a bucket", func(t *testing.T) {
+		db, service := setupPeerComparisonTest(t)
+		user := &models.User{Email: "many@example.com", PasswordHash: "hash"}
+		require.NoError(t, db.Create(user).Error)
+		for i := 0; i < models.PeerBenchmarkMinSampleSize; i++ {
+			createPeerPortfolio(t, db, user, float64(10+i*5), -10)
+		}
+
+		published, err := service.AggregateBenchmarks(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, published)
+	})
+
+	t.Run("opted-out portfolios are excluded", func(t *testing.T) {
+		db, service := setupPeerComparisonTest(t)
+		var last *models.Portfolio
+		for i := 0; i < models.PeerBenchmarkMinSampleSize; i++ {
+			last = createPeerPortfolio(t, db, nil, float64(10+i*5), -10)
+		}
+		require.NoError(t, repository.NewPortfolioRepository(db).SetPeerComparisonOptIn(ctx, last.ID.String(), false))
+
+		published, err := service.AggregateBenchmarks(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, published)
+	})
+}
+
+func TestPeerComparisonService_GetComparison(t *testing.T) {
+	ctx := context.Background()
+
+	db, service := setupPeerComparisonTest(t)
+	var portfolios []*models.Portfolio
+	for i := 0; i < models.PeerBenchmarkMinSampleSize+1; i++ {
+		portfolios = append(portfolios, createPeerPortfolio(t, db, nil, float64(10+i*5), -10))
+	}
+	_, err := service.AggregateBenchmarks(ctx)
+	require.NoError(t, err)
+
+	t.Run("best performer ranks near the top", func(t *testing.T) {
+		best := portfolios[len(portfolios)-1]
+		result, err := service.GetComparison(ctx, best.ID.String(), best.UserID.String())
+		require.NoError(t, err)
+
+		assert.Equal(t, models.AllocationBucketConcentrated, result.Bucket)
+		assert.Equal(t, models.PeerBenchmarkMinSampleSize+1, result.PeerCount)
+		assert.GreaterOrEqual(t, result.Percentile, 90)
+		assert.Equal(t, models.PeerBenchmarkLookbackDays, result.LookbackDays)
+	})
+
+	t.Run("worst performer ranks near the bottom", func(t *testing.T) {
+		worst := portfolios[0]
+		result, err := service.GetComparison(ctx, worst.ID.String(), worst.UserID.String())
+		require.NoError(t, err)
+		assert.LessOrEqual(t, result.Percentile, 10)
+	})
+
+	t.Run("not opted in", func(t *testing.T) {
+		portfolio := portfolios[1]
+		_, err := service.SetOptIn(ctx, portfolio.ID.String(), portfolio.UserID.String(), false)
+		require.NoError(t, err)
+
+		_, err = service.GetComparison(ctx, portfolio.ID.String(), portfolio.UserID.String())
+		assert.ErrorIs(t, err, models.ErrPeerComparisonNotOptedIn)
+	})
+
+	t.Run("other user's portfolio", func(t *testing.T) {
+		_, err := service.GetComparison(ctx, portfolios[2].ID.String(), uuid.New().String())
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+	})
+
+	t.Run("bucket without a published benchmark", func(t *testing.T) {
+		// Two more equal positions move the portfolio into the unpublished FOCUSED bucket
+		portfolio := portfolios[3]
+		for _, symbol := range []string{"MSFT", "GOOG"} {
+			require.NoError(t, db.Create(&models.Holding{
+				PortfolioID:  portfolio.ID,
+				Symbol:       symbol,
+				Quantity:     decimal.NewFromInt(100),
+				CostBasis:    decimal.NewFromInt(10000),
+				AvgCostPrice: decimal.NewFromInt(100),
+			}).Error)
+		}
+
+		_, err := service.GetComparison(ctx, portfolio.ID.String(), portfolio.UserID.String())
+		assert.ErrorIs(t, err, models.ErrPeerBenchmarkNotFound)
+	})
+
+	t.Run("not enough history", func(t *testing.T) {
+		user := &models.User{Email: "new@example.com", PasswordHash: "hash"}
+		require.NoError(t, db.Create(user).Error)
+		portfolio := &models.Portfolio{UserID: user.ID, Name: "New", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+		require.NoError(t, db.Create(portfolio).Error)
+		_, err := service.SetOptIn(ctx, portfolio.ID.String(), user.ID.String(), true)
+		require.NoError(t, err)
+
+		_, err = service.GetComparison(ctx, portfolio.ID.String(), user.ID.String())
+		assert.ErrorIs(t, err, models.ErrInsufficientPeerComparisonData)
+	})
+}
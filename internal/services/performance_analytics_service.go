@@ -1,13 +1,16 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
+	"github.com/lenon/portfolios/internal/calendar"
 	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/repository"
@@ -22,11 +25,15 @@ type PerformanceMetrics = dto.PerformanceMetrics
 
 // PerformanceAnalyticsService defines the interface for performance analytics operations
 type PerformanceAnalyticsService interface {
-	CalculateTWR(portfolioID, userID string, startDate, endDate time.Time) (*TWRResult, error)
-	CalculateMWR(portfolioID, userID string, startDate, endDate time.Time) (*MWRResult, error)
-	CalculateAnnualizedReturn(portfolioID, userID string, startDate, endDate time.Time) (*AnnualizedReturnResult, error)
-	CompareToBenchmark(portfolioID, userID, benchmarkSymbol string, startDate, endDate time.Time) (*BenchmarkComparisonResult, error)
-	GetPerformanceMetrics(portfolioID, userID string, startDate, endDate time.Time) (*PerformanceMetrics, error)
+	CalculateTWR(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*TWRResult, error)
+	CalculateMWR(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*MWRResult, error)
+	CalculateAnnualizedReturn(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*AnnualizedReturnResult, error)
+	CompareToBenchmark(ctx context.Context, portfolioID, userID, benchmarkSymbol string, startDate, endDate time.Time) (*BenchmarkComparisonResult, error)
+	CompareReturnToBenchmark(ctx context.Context, portfolioReturn *AnnualizedReturnResult, benchmarkSymbol string) (*BenchmarkComparisonResult, error)
+	GetPerformanceMetrics(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*PerformanceMetrics, error)
+	GetPeriodMetrics(ctx context.Context, portfolioID, userID string, period models.PerformancePeriod) (*PerformanceMetrics, error)
+	GetStandardPeriodMetrics(ctx context.Context, portfolioID, userID string) (map[models.PerformancePeriod]*PerformanceMetrics, error)
+	MaterializePeriodMetrics(ctx context.Context, portfolioID string) (int, error)
 }
 
 // performanceAnalyticsService implements PerformanceAnalyticsService interface
@@ -35,6 +42,8 @@ type performanceAnalyticsService struct {
 	transactionRepo repository.TransactionRepository
 	snapshotRepo    repository.PerformanceSnapshotRepository
 	marketDataSvc   MarketDataService
+	metricsCache    repository.PerformanceMetricsCacheRepository
+	now             func() time.Time
 }
 
 // NewPerformanceAnalyticsService creates a new PerformanceAnalyticsService instance
@@ -43,28 +52,44 @@ func NewPerformanceAnalyticsService(
 	transactionRepo repository.TransactionRepository,
 	snapshotRepo repository.PerformanceSnapshotRepository,
 	marketDataSvc MarketDataService,
+) PerformanceAnalyticsService {
+	return NewPerformanceAnalyticsServiceWithCache(portfolioRepo, transactionRepo, snapshotRepo, marketDataSvc, nil)
+}
+
+// NewPerformanceAnalyticsServiceWithCache creates a PerformanceAnalyticsService that serves
+// the metrics of the standard periods from metricsCache while they are fresh, storing them
+// there when they are computed. Without a cache they are computed on every request.
+func NewPerformanceAnalyticsServiceWithCache(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	marketDataSvc MarketDataService,
+	metricsCache repository.PerformanceMetricsCacheRepository,
 ) PerformanceAnalyticsService {
 	return &performanceAnalyticsService{
 		portfolioRepo:   portfolioRepo,
 		transactionRepo: transactionRepo,
 		snapshotRepo:    snapshotRepo,
 		marketDataSvc:   marketDataSvc,
+		metricsCache:    metricsCache,
+		now:             func() time.Time { return time.Now().UTC() },
 	}
 }
 
 // CalculateTWR calculates the Time-Weighted Return for a portfolio
 // TWR eliminates the effect of cash flows and measures the compound rate of growth
 func (s *performanceAnalyticsService) CalculateTWR(
+	ctx context.Context,
 	portfolioID, userID string,
 	startDate, endDate time.Time,
 ) (*TWRResult, error) {
 	// Verify portfolio ownership
-	if err := s.verifyPortfolioOwnership(portfolioID, userID); err != nil {
+	if err := s.verifyPortfolioOwnership(ctx, portfolioID, userID); err != nil {
 		return nil, err
 	}
 
 	// Get performance snapshots for the period
-	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(portfolioID, startDate, endDate)
+	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve snapshots: %w", err)
 	}
@@ -74,11 +99,21 @@ func (s *performanceAnalyticsService) CalculateTWR(
 	}
 
 	// Get all transactions in the period to identify cash flow dates
-	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(portfolioID, nil, &startDate, &endDate)
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, &startDate, &endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
 	}
 
+	return twrResult(snapshots, transactions, startDate, endDate)
+}
+
+// twrResult links the returns between a period's snapshots, adjusted for the cash flows of
+// its transactions
+func twrResult(snapshots []*models.PerformanceSnapshot, transactions []*models.Transaction, startDate, endDate time.Time) (*TWRResult, error) {
+	if len(snapshots) < 2 {
+		return nil, fmt.Errorf("insufficient data: need at least 2 snapshots for TWR calculation")
+	}
+
 	// Sort snapshots by date
 	sort.Slice(snapshots, func(i, j int) bool {
 		return snapshots[i].Date.Before(snapshots[j].Date)
@@ -88,28 +123,7 @@ func (s *performanceAnalyticsService) CalculateTWR(
 	endingValue := snapshots[len(snapshots)-1].TotalValue
 
 	// Calculate TWR using sub-period returns
-	// TWR = [(1 + R1) × (1 + R2) × ... × (1 + Rn)] - 1
-	twrProduct := decimal.NewFromInt(1)
-
-	for i := 1; i < len(snapshots); i++ {
-		prevSnapshot := snapshots[i-1]
-		currSnapshot := snapshots[i]
-
-		// Calculate cash flows between periods
-		cashFlow := s.calculateCashFlowBetweenDates(transactions, prevSnapshot.Date, currSnapshot.Date)
-
-		// Adjust for cash flows: Return = (EndValue - CashFlow) / StartValue - 1
-		adjustedEndValue := currSnapshot.TotalValue.Sub(cashFlow)
-
-		if prevSnapshot.TotalValue.IsZero() {
-			continue
-		}
-
-		periodReturn := adjustedEndValue.Div(prevSnapshot.TotalValue).Sub(decimal.NewFromInt(1))
-		twrProduct = twrProduct.Mul(periodReturn.Add(decimal.NewFromInt(1)))
-	}
-
-	twr := twrProduct.Sub(decimal.NewFromInt(1))
+	_, twr := twrSubPeriods(snapshots, transactions)
 	twrPercent := twr.Mul(decimal.NewFromInt(100))
 
 	// Calculate annualized TWR
@@ -137,34 +151,42 @@ func (s *performanceAnalyticsService) CalculateTWR(
 // CalculateMWR calculates the Money-Weighted Return (Internal Rate of Return)
 // MWR considers the timing and size of cash flows
 func (s *performanceAnalyticsService) CalculateMWR(
+	ctx context.Context,
 	portfolioID, userID string,
 	startDate, endDate time.Time,
 ) (*MWRResult, error) {
 	// Verify portfolio ownership
-	if err := s.verifyPortfolioOwnership(portfolioID, userID); err != nil {
+	if err := s.verifyPortfolioOwnership(ctx, portfolioID, userID); err != nil {
 		return nil, err
 	}
 
 	// Get transactions for cash flow analysis
-	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(portfolioID, nil, &startDate, &endDate)
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, &startDate, &endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
 	}
 
 	// Get starting and ending values
-	startSnapshot, err := s.getSnapshotNearDate(portfolioID, startDate)
+	startSnapshot, err := s.getSnapshotNearDate(ctx, portfolioID, startDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get starting snapshot: %w", err)
 	}
 
-	endSnapshot, err := s.getSnapshotNearDate(portfolioID, endDate)
+	endSnapshot, err := s.getSnapshotNearDate(ctx, portfolioID, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ending snapshot: %w", err)
 	}
 
-	startingValue := startSnapshot.TotalValue
-	endingValue := endSnapshot.TotalValue
+	return s.mwrResult(transactions, startSnapshot.TotalValue, endSnapshot.TotalValue, startDate, endDate)
+}
 
+// mwrResult finds the internal rate of return of a period's transactions between its
+// starting and ending values
+func (s *performanceAnalyticsService) mwrResult(
+	transactions []*models.Transaction,
+	startingValue, endingValue decimal.Decimal,
+	startDate, endDate time.Time,
+) (*MWRResult, error) {
 	// Build cash flow series
 	cashFlows := s.buildCashFlowSeries(transactions, startDate, endDate, startingValue, endingValue)
 
@@ -205,27 +227,30 @@ func (s *performanceAnalyticsService) CalculateMWR(
 
 // CalculateAnnualizedReturn calculates the annualized return for a portfolio
 func (s *performanceAnalyticsService) CalculateAnnualizedReturn(
+	ctx context.Context,
 	portfolioID, userID string,
 	startDate, endDate time.Time,
 ) (*AnnualizedReturnResult, error) {
 	// Verify portfolio ownership
-	if err := s.verifyPortfolioOwnership(portfolioID, userID); err != nil {
+	if err := s.verifyPortfolioOwnership(ctx, portfolioID, userID); err != nil {
 		return nil, err
 	}
 
-	startSnapshot, err := s.getSnapshotNearDate(portfolioID, startDate)
+	startSnapshot, err := s.getSnapshotNearDate(ctx, portfolioID, startDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get starting snapshot: %w", err)
 	}
 
-	endSnapshot, err := s.getSnapshotNearDate(portfolioID, endDate)
+	endSnapshot, err := s.getSnapshotNearDate(ctx, portfolioID, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get ending snapshot: %w", err)
 	}
 
-	startingValue := startSnapshot.TotalValue
-	endingValue := endSnapshot.TotalValue
+	return annualizedReturn(startSnapshot.TotalValue, endSnapshot.TotalValue, startDate, endDate), nil
+}
 
+// annualizedReturn computes the total and annualized return between two values over a period
+func annualizedReturn(startingValue, endingValue decimal.Decimal, startDate, endDate time.Time) *AnnualizedReturnResult {
 	totalReturn := endingValue.Sub(startingValue)
 	totalReturnPct := decimal.Zero
 	if !startingValue.IsZero() {
@@ -234,55 +259,62 @@ func (s *performanceAnalyticsService) CalculateAnnualizedReturn(
 
 	// Calculate annualized return
 	years := endDate.Sub(startDate).Hours() / 24 / 365.25
-	annualizedReturn := decimal.Zero
-
-	if years > 0 && !startingValue.IsZero() {
-		// Annualized Return = (EndValue / StartValue)^(1/years) - 1
-		ratio, _ := endingValue.Div(startingValue).Float64()
-		annualizedFloat := (math.Pow(ratio, 1/years) - 1) * 100
-		annualizedReturn = decimal.NewFromFloat(annualizedFloat)
-	}
+	annualized := annualizedPercent(startingValue, endingValue, years)
 
 	return &AnnualizedReturnResult{
 		StartDate:        startDate,
 		EndDate:          endDate,
 		TotalReturn:      totalReturn,
 		TotalReturnPct:   totalReturnPct,
-		AnnualizedReturn: annualizedReturn,
+		AnnualizedReturn: annualized,
 		Years:            years,
-	}, nil
+		TradingDays:      calendar.NYSE.TradingDaysBetween(startDate, endDate),
+	}
 }
 
 // CompareToBenchmark compares portfolio performance to a benchmark index
 func (s *performanceAnalyticsService) CompareToBenchmark(
+	ctx context.Context,
 	portfolioID, userID, benchmarkSymbol string,
 	startDate, endDate time.Time,
 ) (*BenchmarkComparisonResult, error) {
 	// Verify portfolio ownership
-	if err := s.verifyPortfolioOwnership(portfolioID, userID); err != nil {
+	if err := s.verifyPortfolioOwnership(ctx, portfolioID, userID); err != nil {
 		return nil, err
 	}
 
 	// Calculate portfolio annualized return
-	portfolioReturn, err := s.CalculateAnnualizedReturn(portfolioID, userID, startDate, endDate)
+	portfolioReturn, err := s.CalculateAnnualizedReturn(ctx, portfolioID, userID, startDate, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate portfolio return: %w", err)
 	}
 
-	// Get benchmark historical data
-	benchmarkPrices, err := s.marketDataSvc.GetHistoricalPrices(benchmarkSymbol, startDate, endDate)
+	return s.CompareReturnToBenchmark(ctx, portfolioReturn, benchmarkSymbol)
+}
+
+// CompareReturnToBenchmark compares a return over a period, such as a portfolio group's, to a
+// benchmark index's over the same period
+func (s *performanceAnalyticsService) CompareReturnToBenchmark(
+	ctx context.Context,
+	portfolioReturn *AnnualizedReturnResult,
+	benchmarkSymbol string,
+) (*BenchmarkComparisonResult, error) {
+	startDate, endDate := portfolioReturn.StartDate, portfolioReturn.EndDate
+
+	// Get benchmark historical data, starting from the last session on or before the start
+	// date so a period starting on a weekend or holiday uses the close it was valued at
+	sessionStart := calendar.NYSE.TradingDayOnOrBefore(startDate)
+	benchmarkPrices, err := s.marketDataSvc.GetHistoricalPrices(ctx, benchmarkSymbol, sessionStart, endDate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get benchmark prices: %w", err)
 	}
 
-	if len(benchmarkPrices) < 2 {
+	// Get starting and ending prices
+	benchmarkStart, benchmarkEnd, ok := benchmarkCloses(benchmarkPrices, startDate, endDate)
+	if !ok {
 		return nil, fmt.Errorf("insufficient benchmark data")
 	}
 
-	// Get starting and ending prices
-	benchmarkStart := benchmarkPrices[0].Close
-	benchmarkEnd := benchmarkPrices[len(benchmarkPrices)-1].Close
-
 	// Calculate benchmark return
 	benchmarkReturn := benchmarkEnd.Sub(benchmarkStart).Div(benchmarkStart)
 	benchmarkReturnPct := benchmarkReturn.Mul(decimal.NewFromInt(100))
@@ -315,23 +347,52 @@ func (s *performanceAnalyticsService) CompareToBenchmark(
 
 // GetPerformanceMetrics returns comprehensive performance metrics
 func (s *performanceAnalyticsService) GetPerformanceMetrics(
+	ctx context.Context,
 	portfolioID, userID string,
 	startDate, endDate time.Time,
 ) (*PerformanceMetrics, error) {
 	// Verify portfolio ownership
-	if err := s.verifyPortfolioOwnership(portfolioID, userID); err != nil {
+	if err := s.verifyPortfolioOwnership(ctx, portfolioID, userID); err != nil {
 		return nil, err
 	}
 
-	// Get snapshots
-	startSnapshot, err := s.getSnapshotNearDate(portfolioID, startDate)
+	// Load the snapshots and transactions once for every metric: the snapshots around the
+	// period's ends as well as within it, to value the ends on days without a snapshot
+	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, startDate.AddDate(0, 0, -snapshotSearchDays), endDate.AddDate(0, 0, snapshotSearchDays))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get starting snapshot: %w", err)
+		return nil, fmt.Errorf("failed to retrieve snapshots: %w", err)
 	}
-
-	endSnapshot, err := s.getSnapshotNearDate(portfolioID, endDate)
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, &startDate, &endDate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get ending snapshot: %w", err)
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+
+	return s.performanceMetrics(snapshots, transactions, startDate, endDate)
+}
+
+// performanceMetrics computes the metrics between startDate and endDate from snapshots
+// covering the days around both ends and transactions covering the period. Either may
+// extend further, as when the metrics of several periods are computed from one load.
+func (s *performanceAnalyticsService) performanceMetrics(
+	snapshots []*models.PerformanceSnapshot,
+	transactions []*models.Transaction,
+	startDate, endDate time.Time,
+) (*PerformanceMetrics, error) {
+	periodTransactions := make([]*models.Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if !tx.Date.Before(startDate) && !tx.Date.After(endDate) {
+			periodTransactions = append(periodTransactions, tx)
+		}
+	}
+	transactions = periodTransactions
+
+	startSnapshot := snapshotNearDate(snapshots, startDate)
+	if startSnapshot == nil {
+		return nil, fmt.Errorf("failed to get starting snapshot: no snapshot found near date %s", startDate.Format("2006-01-02"))
+	}
+	endSnapshot := snapshotNearDate(snapshots, endDate)
+	if endSnapshot == nil {
+		return nil, fmt.Errorf("failed to get ending snapshot: no snapshot found near date %s", endDate.Format("2006-01-02"))
 	}
 
 	// Calculate basic metrics
@@ -346,43 +407,60 @@ func (s *performanceAnalyticsService) GetPerformanceMetrics(
 	// Calculate years
 	years := endDate.Sub(startDate).Hours() / 24 / 365.25
 
-	// Calculate TWR
-	twrResult, err := s.CalculateTWR(portfolioID, userID, startDate, endDate)
+	// Calculate TWR from the snapshots within the period
+	periodSnapshots := make([]*models.PerformanceSnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		if !snapshot.Date.Before(startDate) && !snapshot.Date.After(endDate) {
+			periodSnapshots = append(periodSnapshots, snapshot)
+		}
+	}
+	twr, err := twrResult(periodSnapshots, transactions, startDate, endDate)
 	timeWeightedReturn := decimal.Zero
 	if err == nil {
-		timeWeightedReturn = twrResult.TWRPercent
+		timeWeightedReturn = twr.TWRPercent
 	}
 
 	// Calculate MWR
-	mwrResult, err := s.CalculateMWR(portfolioID, userID, startDate, endDate)
+	mwr, err := s.mwrResult(transactions, startingValue, endingValue, startDate, endDate)
 	moneyWeightedReturn := decimal.Zero
 	netCashFlow := decimal.Zero
 	if err == nil {
-		moneyWeightedReturn = mwrResult.MWRPercent
-		netCashFlow = mwrResult.TotalCashFlow
+		moneyWeightedReturn = mwr.MWRPercent
+		netCashFlow = mwr.TotalCashFlow
 	}
 
 	// Calculate annualized return
-	annualizedReturn := decimal.Zero
-	if years > 0 && !startingValue.IsZero() {
-		ratio, _ := endingValue.Div(startingValue).Float64()
-		annualizedFloat := (math.Pow(ratio, 1/years) - 1) * 100
-		annualizedReturn = decimal.NewFromFloat(annualizedFloat)
-	}
+	annualizedReturn := annualizedPercent(startingValue, endingValue, years)
 
-	// Get transaction totals
-	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(portfolioID, nil, &startDate, &endDate)
+	// Get transaction totals. Deposits and withdrawals are the external cash flows, kept apart
+	// from the purchases and sales that only move money between cash and holdings.
 	totalDeposits := decimal.Zero
 	totalWithdrawals := decimal.Zero
-
-	if err == nil {
-		for _, tx := range transactions {
-			if tx.IsBuy() {
-				totalDeposits = totalDeposits.Add(tx.GetTotalCost())
-			} else if tx.IsSell() {
-				totalWithdrawals = totalWithdrawals.Add(tx.GetProceeds())
-			}
+	totalPurchases := decimal.Zero
+	totalSales := decimal.Zero
+	totalFees := decimal.Zero
+	for _, tx := range transactions {
+		switch {
+		case tx.Type == models.TransactionTypeDeposit:
+			totalDeposits = totalDeposits.Add(tx.Quantity)
+		case tx.Type == models.TransactionTypeWithdrawal:
+			totalWithdrawals = totalWithdrawals.Add(tx.Quantity)
+		case tx.IsBuy():
+			totalPurchases = totalPurchases.Add(tx.GetTotalCost())
+		case tx.IsSell():
+			totalSales = totalSales.Add(tx.GetProceeds())
 		}
+		totalFees = totalFees.Add(tx.GetFees())
+	}
+
+	// Income is totaled by category the way statements do
+	income := statementIncome(transactions)
+
+	// Net of fees, the return is what is left once the fees paid over the period are deducted
+	netReturn := totalReturn.Sub(totalFees)
+	netReturnPct := decimal.Zero
+	if !startingValue.IsZero() {
+		netReturnPct = netReturn.Div(startingValue).Mul(decimal.NewFromInt(100))
 	}
 
 	return &PerformanceMetrics{
@@ -397,58 +475,408 @@ func (s *performanceAnalyticsService) GetPerformanceMetrics(
 		AnnualizedReturn:    annualizedReturn,
 		TotalDeposits:       totalDeposits,
 		TotalWithdrawals:    totalWithdrawals,
+		TotalPurchases:      totalPurchases,
+		TotalSales:          totalSales,
 		NetCashFlow:         netCashFlow,
 		Years:               years,
+		TradingDays:         calendar.NYSE.TradingDaysBetween(startDate, endDate),
+		Gains:               dto.GainBreakdownBetween(startSnapshot, endSnapshot),
+		TotalFees:           totalFees,
+		NetReturn:           netReturn,
+		NetReturnPct:        netReturnPct,
+		NetAnnualizedReturn: annualizedPercent(startingValue, endingValue.Sub(totalFees), years),
+		TotalIncome:         income.Total,
+		IncomeByCategory:    income.ByCategory,
 	}, nil
 }
 
+// GetPeriodMetrics returns a portfolio's metrics over a standard period ending today. Metrics
+// materialized since the portfolio's transactions and snapshots last changed are served as
+// they are; otherwise they are computed and materialized for the next request.
+func (s *performanceAnalyticsService) GetPeriodMetrics(
+	ctx context.Context,
+	portfolioID, userID string,
+	period models.PerformancePeriod,
+) (*PerformanceMetrics, error) {
+	if err := s.verifyPortfolioOwnership(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.cachedPeriodMetrics(ctx, portfolioID, []models.PerformancePeriod{period})
+	if err != nil {
+		return nil, err
+	}
+	if metrics[period] == nil {
+		return nil, models.ErrInsufficientPerformanceHistory
+	}
+	return metrics[period], nil
+}
+
+// GetStandardPeriodMetrics returns a portfolio's metrics over every standard period its
+// snapshots cover, served and materialized like GetPeriodMetrics
+func (s *performanceAnalyticsService) GetStandardPeriodMetrics(
+	ctx context.Context,
+	portfolioID, userID string,
+) (map[models.PerformancePeriod]*PerformanceMetrics, error) {
+	if err := s.verifyPortfolioOwnership(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.cachedPeriodMetrics(ctx, portfolioID, models.StandardPerformancePeriods)
+	if err != nil {
+		return nil, err
+	}
+	for period, periodMetrics := range metrics {
+		if periodMetrics == nil {
+			delete(metrics, period)
+		}
+	}
+	return metrics, nil
+}
+
+// MaterializePeriodMetrics computes a portfolio's metrics over every standard period and
+// stores them, returning how many periods its snapshots cover
+func (s *performanceAnalyticsService) MaterializePeriodMetrics(ctx context.Context, portfolioID string) (int, error) {
+	if s.metricsCache == nil {
+		return 0, nil
+	}
+
+	// Stamp before loading, so that changes made while computing leave the metrics stale
+	stamp, err := s.metricsCache.SourceStamp(ctx, portfolioID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	computed, err := s.periodMetrics(ctx, portfolioID, models.StandardPerformancePeriods, now)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, period := range models.StandardPerformancePeriods {
+		if err := s.saveMetrics(ctx, portfolioID, period, computed[period], stamp, now); err != nil {
+			return 0, err
+		}
+	}
+	return len(computed), nil
+}
+
+// cachedPeriodMetrics returns a portfolio's metrics over periods, nil for the periods its
+// snapshots don't cover. Fresh materialized metrics are served as they are; the others are
+// computed together and materialized, uncovered periods included, so that they aren't
+// computed again until the portfolio changes or the day ends.
+func (s *performanceAnalyticsService) cachedPeriodMetrics(
+	ctx context.Context,
+	portfolioID string,
+	periods []models.PerformancePeriod,
+) (map[models.PerformancePeriod]*PerformanceMetrics, error) {
+	now := s.now()
+	if s.metricsCache == nil {
+		return s.periodMetrics(ctx, portfolioID, periods, now)
+	}
+
+	stamp, err := s.metricsCache.SourceStamp(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[models.PerformancePeriod]*PerformanceMetrics, len(periods))
+	var stale []models.PerformancePeriod
+	for _, period := range periods {
+		cached, err := s.metricsCache.FindByPortfolioIDAndPeriod(ctx, portfolioID, period)
+		if err != nil {
+			return nil, err
+		}
+		var periodMetrics *PerformanceMetrics
+		if cached == nil || !cached.IsFresh(stamp, now) || cached.DecodeMetrics(&periodMetrics) != nil {
+			stale = append(stale, period)
+			continue
+		}
+		metrics[period] = periodMetrics
+	}
+	if len(stale) == 0 {
+		return metrics, nil
+	}
+
+	computed, err := s.periodMetrics(ctx, portfolioID, stale, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, period := range stale {
+		metrics[period] = computed[period]
+		// A failed save only means the next request computes the metrics again
+		_ = s.saveMetrics(ctx, portfolioID, period, computed[period], stamp, now)
+	}
+	return metrics, nil
+}
+
+// periodMetrics computes a portfolio's metrics over periods ending at now, loading the
+// snapshots and transactions once for all of them. Periods the snapshots don't cover are
+// left out.
+func (s *performanceAnalyticsService) periodMetrics(
+	ctx context.Context,
+	portfolioID string,
+	periods []models.PerformancePeriod,
+	now time.Time,
+) (map[models.PerformancePeriod]*PerformanceMetrics, error) {
+	// The ALL period needs every snapshot, the others the days around their start
+	var earliest time.Time
+	for _, period := range periods {
+		if period == models.PerformancePeriodAll {
+			earliest = time.Time{}
+			break
+		}
+		if start := period.StartDate(now, now); earliest.IsZero() || start.Before(earliest) {
+			earliest = start
+		}
+	}
+
+	snapshotsFrom := earliest
+	var transactionsFrom *time.Time
+	if !earliest.IsZero() {
+		snapshotsFrom = earliest.AddDate(0, 0, -snapshotSearchDays)
+		transactionsFrom = &earliest
+	}
+	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, snapshotsFrom, now.AddDate(0, 0, snapshotSearchDays))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve snapshots: %w", err)
+	}
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, transactionsFrom, &now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+
+	computed := make(map[models.PerformancePeriod]*PerformanceMetrics, len(periods))
+	if len(snapshots) == 0 {
+		return computed, nil
+	}
+	inception := snapshots[0].Date
+	for _, snapshot := range snapshots {
+		if snapshot.Date.Before(inception) {
+			inception = snapshot.Date
+		}
+	}
+
+	for _, period := range periods {
+		// Periods starting long before the first snapshot have no starting value
+		metrics, err := s.performanceMetrics(snapshots, transactions, period.StartDate(now, inception), now)
+		if err != nil {
+			continue
+		}
+		computed[period] = metrics
+	}
+	return computed, nil
+}
+
+// saveMetrics materializes a portfolio's metrics over a period, computed at now from the
+// data stamp identifies. Nil metrics record that the snapshots don't cover the period.
+func (s *performanceAnalyticsService) saveMetrics(
+	ctx context.Context,
+	portfolioID string,
+	period models.PerformancePeriod,
+	metrics *PerformanceMetrics,
+	stamp string,
+	now time.Time,
+) error {
+	id, err := uuid.Parse(portfolioID)
+	if err != nil {
+		return fmt.Errorf("invalid portfolio ID format: %w", err)
+	}
+
+	entry := &models.PerformanceMetricsCache{
+		PortfolioID: id,
+		Period:      period,
+		StartDate:   period.StartDate(now, now),
+		EndDate:     now,
+		SourceStamp: stamp,
+		ComputedAt:  now,
+	}
+	if metrics != nil {
+		entry.StartDate = metrics.StartDate
+	}
+	if err := entry.SetMetrics(metrics); err != nil {
+		return err
+	}
+	return s.metricsCache.Save(ctx, entry)
+}
+
 // Helper functions
 
-func (s *performanceAnalyticsService) verifyPortfolioOwnership(portfolioID, userID string) error {
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+func (s *performanceAnalyticsService) verifyPortfolioOwnership(ctx context.Context, portfolioID, userID string) error {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return models.ErrUnauthorizedAccess
 	}
 	return nil
 }
 
-func (s *performanceAnalyticsService) getSnapshotNearDate(portfolioID string, date time.Time) (*models.PerformanceSnapshot, error) {
+// annualizedPercent compounds the growth from startingValue to endingValue over years into
+// a yearly percentage: (endingValue / startingValue)^(1/years) - 1. Losing everything is
+// -100%, and periods too short to annualize are 0%.
+func annualizedPercent(startingValue, endingValue decimal.Decimal, years float64) decimal.Decimal {
+	if years <= 0 || startingValue.IsZero() {
+		return decimal.Zero
+	}
+	ratio, _ := endingValue.Div(startingValue).Float64()
+	if ratio <= 0 {
+		return decimal.NewFromInt(-100)
+	}
+	annualized := (math.Pow(ratio, 1/years) - 1) * 100
+	if math.IsInf(annualized, 0) || math.IsNaN(annualized) {
+		return decimal.Zero
+	}
+	return decimal.NewFromFloat(annualized)
+}
+
+// snapshotSearchDays is how far either side of a date a snapshot valuing it is looked for
+const snapshotSearchDays = 7
+
+// getSnapshotNearDate finds the snapshot valuing a portfolio on a date, as snapshotNearDate
+// does, loading only the snapshots it needs
+func (s *performanceAnalyticsService) getSnapshotNearDate(ctx context.Context, portfolioID string, date time.Time) (*models.PerformanceSnapshot, error) {
 	// Try to get exact date first
-	snapshot, err := s.snapshotRepo.FindByPortfolioIDAndDate(portfolioID, date)
+	snapshot, err := s.snapshotRepo.FindByPortfolioIDAndDate(ctx, portfolioID, date)
 	if err == nil {
 		return snapshot, nil
 	}
 
 	// If not found, get snapshots in a range around the date
-	startRange := date.AddDate(0, 0, -7) // 7 days before
-	endRange := date.AddDate(0, 0, 7)    // 7 days after
+	startRange := date.AddDate(0, 0, -snapshotSearchDays)
+	endRange := date.AddDate(0, 0, snapshotSearchDays)
 
-	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(portfolioID, startRange, endRange)
-	if err != nil || len(snapshots) == 0 {
+	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, startRange, endRange)
+	if err != nil {
+		return nil, fmt.Errorf("no snapshot found near date %s", date.Format("2006-01-02"))
+	}
+	snapshot = snapshotNearDate(snapshots, date)
+	if snapshot == nil {
 		return nil, fmt.Errorf("no snapshot found near date %s", date.Format("2006-01-02"))
 	}
+	return snapshot, nil
+}
 
-	// Return the closest snapshot
-	closest := snapshots[0]
-	minDiff := math.Abs(date.Sub(snapshots[0].Date).Hours())
+// snapshotNearDate picks the snapshot valuing a portfolio on a date from snapshots, or nil if
+// none is near it. The snapshot taken that day is preferred; without one, the latest snapshot
+// taken since the last NYSE session on or before the date is used, since the market was
+// closed in between; otherwise the closest within a week either side.
+func snapshotNearDate(snapshots []*models.PerformanceSnapshot, date time.Time) *models.PerformanceSnapshot {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	dayEnd := day.AddDate(0, 0, 1)
+	for _, snap := range snapshots {
+		if !snap.Date.Before(day) && snap.Date.Before(dayEnd) {
+			return snap
+		}
+	}
 
+	// Prefer the latest snapshot since the last session, up to the end of the date
+	session := calendar.NYSE.TradingDayOnOrBefore(date)
+	var latest *models.PerformanceSnapshot
+	for _, snap := range snapshots {
+		if snap.Date.Before(session) || !snap.Date.Before(dayEnd) {
+			continue
+		}
+		if latest == nil || snap.Date.After(latest.Date) {
+			latest = snap
+		}
+	}
+	if latest != nil {
+		return latest
+	}
+
+	// Return the closest snapshot within the search window
+	var closest *models.PerformanceSnapshot
+	minDiff := float64(snapshotSearchDays * 24)
 	for _, snap := range snapshots {
 		diff := math.Abs(date.Sub(snap.Date).Hours())
-		if diff < minDiff {
+		if diff <= minDiff && (closest == nil || diff < minDiff) {
 			minDiff = diff
 			closest = snap
 		}
 	}
+	return closest
+}
 
-	return closest, nil
+// benchmarkCloses returns a benchmark's last closes on or before the start and end dates,
+// which fall on the last sessions before a weekend or holiday. Bars are sorted by date
+// first, since providers don't agree on an order.
+func benchmarkCloses(prices []*HistoricalPrice, startDate, endDate time.Time) (start, end decimal.Decimal, ok bool) {
+	sorted := make([]*HistoricalPrice, len(prices))
+	copy(sorted, prices)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	startDay := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	endDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
+	startIdx, endIdx := 0, -1
+	for i, price := range sorted {
+		day := time.Date(price.Date.Year(), price.Date.Month(), price.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if !day.After(startDay) {
+			startIdx = i
+		}
+		if !day.After(endDay) {
+			endIdx = i
+		}
+	}
+	if endIdx <= startIdx {
+		return decimal.Zero, decimal.Zero, false
+	}
+	return sorted[startIdx].Close, sorted[endIdx].Close, true
 }
 
 func (s *performanceAnalyticsService) calculateCashFlowBetweenDates(
 	transactions []*models.Transaction,
 	startDate, endDate time.Time,
+) decimal.Decimal {
+	return cashFlowBetweenDates(transactions, startDate, endDate)
+}
+
+// twrSubPeriods splits the span between date-ordered snapshots into sub-periods and links
+// their cash-flow-adjusted returns:
+// TWR = [(1 + R1) × (1 + R2) × ... × (1 + Rn)] - 1
+// Sub-periods starting from a zero value have no defined return and are excluded.
+func twrSubPeriods(snapshots []*models.PerformanceSnapshot, transactions []*models.Transaction) ([]dto.TWRSubPeriod, decimal.Decimal) {
+	one := decimal.NewFromInt(1)
+	twrProduct := one
+	var periods []dto.TWRSubPeriod
+
+	for i := 1; i < len(snapshots); i++ {
+		prevSnapshot := snapshots[i-1]
+		currSnapshot := snapshots[i]
+
+		// Calculate cash flows between periods
+		cashFlow := cashFlowBetweenDates(transactions, prevSnapshot.Date, currSnapshot.Date)
+		period := dto.TWRSubPeriod{
+			StartDate:  prevSnapshot.Date,
+			EndDate:    currSnapshot.Date,
+			StartValue: prevSnapshot.TotalValue,
+			EndValue:   currSnapshot.TotalValue,
+			CashFlow:   cashFlow,
+			Return:     decimal.Zero,
+		}
+
+		if prevSnapshot.TotalValue.IsZero() {
+			period.Excluded = true
+			periods = append(periods, period)
+			continue
+		}
+
+		// Adjust for cash flows: Return = (EndValue - CashFlow) / StartValue - 1
+		adjustedEndValue := currSnapshot.TotalValue.Sub(cashFlow)
+		period.Return = adjustedEndValue.Div(prevSnapshot.TotalValue).Sub(one)
+		twrProduct = twrProduct.Mul(period.Return.Add(one))
+		periods = append(periods, period)
+	}
+
+	return periods, twrProduct.Sub(one)
+}
+
+// cashFlowBetweenDates sums the external cash flows after startDate up to and including
+// endDate: buys at their total cost flow in, sells at their proceeds flow out
+func cashFlowBetweenDates(
+	transactions []*models.Transaction,
+	startDate, endDate time.Time,
 ) decimal.Decimal {
 	cashFlow := decimal.Zero
 
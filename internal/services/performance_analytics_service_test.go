@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"testing"
 	"time"
@@ -29,6 +30,8 @@ func TestNewPerformanceAnalyticsService(t *testing.T) {
 }
 
 func TestCalculateTWR_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -85,7 +88,7 @@ func TestCalculateTWR_Success(t *testing.T) {
 	snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, startDate, endDate).Return(snapshots, nil)
 	transactionRepo.On("FindByPortfolioIDWithFilters", portfolioID, mock.Anything, &startDate, &endDate).Return(transactions, nil)
 
-	result, err := svc.CalculateTWR(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateTWR(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -101,6 +104,8 @@ func TestCalculateTWR_Success(t *testing.T) {
 }
 
 func TestCalculateTWR_UnauthorizedAccess(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -127,7 +132,7 @@ func TestCalculateTWR_UnauthorizedAccess(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 
-	result, err := svc.CalculateTWR(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateTWR(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -137,6 +142,8 @@ func TestCalculateTWR_UnauthorizedAccess(t *testing.T) {
 }
 
 func TestCalculateTWR_PortfolioNotFound(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -156,7 +163,7 @@ func TestCalculateTWR_PortfolioNotFound(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(nil, models.ErrPortfolioNotFound)
 
-	result, err := svc.CalculateTWR(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateTWR(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -166,6 +173,8 @@ func TestCalculateTWR_PortfolioNotFound(t *testing.T) {
 }
 
 func TestCalculateTWR_InsufficientSnapshots(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -200,7 +209,7 @@ func TestCalculateTWR_InsufficientSnapshots(t *testing.T) {
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 	snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, startDate, endDate).Return(snapshots, nil)
 
-	result, err := svc.CalculateTWR(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateTWR(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -211,6 +220,8 @@ func TestCalculateTWR_InsufficientSnapshots(t *testing.T) {
 }
 
 func TestCalculateMWR_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -263,7 +274,7 @@ func TestCalculateMWR_Success(t *testing.T) {
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, endDate).Return(endSnapshot, nil)
 	transactionRepo.On("FindByPortfolioIDWithFilters", portfolioID, mock.Anything, &startDate, &endDate).Return(transactions, nil)
 
-	result, err := svc.CalculateMWR(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateMWR(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -278,6 +289,8 @@ func TestCalculateMWR_Success(t *testing.T) {
 }
 
 func TestCalculateMWR_UnauthorizedAccess(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -304,7 +317,7 @@ func TestCalculateMWR_UnauthorizedAccess(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 
-	result, err := svc.CalculateMWR(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateMWR(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -314,6 +327,8 @@ func TestCalculateMWR_UnauthorizedAccess(t *testing.T) {
 }
 
 func TestCalculateMWR_NoStartSnapshot(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -342,7 +357,7 @@ func TestCalculateMWR_NoStartSnapshot(t *testing.T) {
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, startDate).Return(nil, errors.New("snapshot not found"))
 	snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, mock.Anything, mock.Anything).Return([]*models.PerformanceSnapshot{}, nil)
 
-	result, err := svc.CalculateMWR(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateMWR(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -353,6 +368,8 @@ func TestCalculateMWR_NoStartSnapshot(t *testing.T) {
 }
 
 func TestCalculateAnnualizedReturn_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -392,7 +409,7 @@ func TestCalculateAnnualizedReturn_Success(t *testing.T) {
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, startDate).Return(startSnapshot, nil)
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, endDate).Return(endSnapshot, nil)
 
-	result, err := svc.CalculateAnnualizedReturn(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateAnnualizedReturn(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -400,12 +417,16 @@ func TestCalculateAnnualizedReturn_Success(t *testing.T) {
 	assert.Equal(t, endDate, result.EndDate)
 	assert.True(t, result.TotalReturn.Equal(decimal.NewFromInt(2000)))
 	assert.True(t, result.TotalReturnPct.GreaterThan(decimal.Zero))
+	// NYSE sessions in 2023
+	assert.Equal(t, 250, result.TradingDays)
 
 	portfolioRepo.AssertExpectations(t)
 	snapshotRepo.AssertExpectations(t)
 }
 
 func TestCalculateAnnualizedReturn_ZeroStartingValue(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -445,7 +466,7 @@ func TestCalculateAnnualizedReturn_ZeroStartingValue(t *testing.T) {
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, startDate).Return(startSnapshot, nil)
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, endDate).Return(endSnapshot, nil)
 
-	result, err := svc.CalculateAnnualizedReturn(portfolioID, userID, startDate, endDate)
+	result, err := svc.CalculateAnnualizedReturn(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -457,6 +478,8 @@ func TestCalculateAnnualizedReturn_ZeroStartingValue(t *testing.T) {
 }
 
 func TestCompareToBenchmark_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -507,9 +530,10 @@ func TestCompareToBenchmark_Success(t *testing.T) {
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil).Times(2)
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, startDate).Return(startSnapshot, nil)
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, endDate).Return(endSnapshot, nil)
-	marketDataSvc.On("GetHistoricalPrices", benchmarkSymbol, startDate, endDate).Return(benchmarkPrices, nil)
+	// New Year's Day 2023 fell on a Sunday, so the period starts from the 2022 close
+	marketDataSvc.On("GetHistoricalPrices", benchmarkSymbol, time.Date(2022, 12, 30, 0, 0, 0, 0, time.UTC), endDate).Return(benchmarkPrices, nil)
 
-	result, err := svc.CompareToBenchmark(portfolioID, userID, benchmarkSymbol, startDate, endDate)
+	result, err := svc.CompareToBenchmark(ctx, portfolioID, userID, benchmarkSymbol, startDate, endDate)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -523,6 +547,8 @@ func TestCompareToBenchmark_Success(t *testing.T) {
 }
 
 func TestCompareToBenchmark_InsufficientBenchmarkData(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -569,9 +595,10 @@ func TestCompareToBenchmark_InsufficientBenchmarkData(t *testing.T) {
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil).Times(2)
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, startDate).Return(startSnapshot, nil)
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, endDate).Return(endSnapshot, nil)
-	marketDataSvc.On("GetHistoricalPrices", benchmarkSymbol, startDate, endDate).Return(benchmarkPrices, nil)
+	// New Year's Day 2023 fell on a Sunday, so the period starts from the 2022 close
+	marketDataSvc.On("GetHistoricalPrices", benchmarkSymbol, time.Date(2022, 12, 30, 0, 0, 0, 0, time.UTC), endDate).Return(benchmarkPrices, nil)
 
-	result, err := svc.CompareToBenchmark(portfolioID, userID, benchmarkSymbol, startDate, endDate)
+	result, err := svc.CompareToBenchmark(ctx, portfolioID, userID, benchmarkSymbol, startDate, endDate)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -583,6 +610,8 @@ func TestCompareToBenchmark_InsufficientBenchmarkData(t *testing.T) {
 }
 
 func TestGetPerformanceMetrics_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -607,16 +636,20 @@ func TestGetPerformanceMetrics_Success(t *testing.T) {
 	}
 
 	startSnapshot := &models.PerformanceSnapshot{
-		PortfolioID: uuid.MustParse(portfolioID),
-		Date:        startDate,
-		TotalValue:  decimal.NewFromInt(10000),
+		PortfolioID:    uuid.MustParse(portfolioID),
+		Date:           startDate,
+		TotalValue:     decimal.NewFromInt(10000),
+		TotalCostBasis: decimal.NewFromInt(9000),
 	}
+	startSnapshot.SetGainBreakdown(decimal.NewFromInt(100), decimal.NewFromInt(50), decimal.NewFromInt(10))
 
 	endSnapshot := &models.PerformanceSnapshot{
-		PortfolioID: uuid.MustParse(portfolioID),
-		Date:        endDate,
-		TotalValue:  decimal.NewFromInt(12000),
+		PortfolioID:    uuid.MustParse(portfolioID),
+		Date:           endDate,
+		TotalValue:     decimal.NewFromInt(12000),
+		TotalCostBasis: decimal.NewFromInt(10000),
 	}
+	endSnapshot.SetGainBreakdown(decimal.NewFromInt(300), decimal.NewFromInt(80), decimal.NewFromInt(12))
 
 	snapshots := []*models.PerformanceSnapshot{
 		startSnapshot,
@@ -633,30 +666,46 @@ func TestGetPerformanceMetrics_Success(t *testing.T) {
 
 	price := decimal.NewFromInt(100)
 	transactions := []*models.Transaction{
+		{
+			PortfolioID: uuid.MustParse(portfolioID),
+			Symbol:      "USD",
+			Type:        models.TransactionTypeDeposit,
+			Quantity:    decimal.NewFromInt(1500),
+			Date:        time.Date(2024, 5, 30, 0, 0, 0, 0, time.UTC),
+		},
 		{
 			PortfolioID: uuid.MustParse(portfolioID),
 			Symbol:      "AAPL",
 			Type:        models.TransactionTypeBuy,
 			Quantity:    decimal.NewFromInt(10),
 			Price:       &price,
+			Commission:  decimal.NewFromInt(5),
 			Date:        time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
 		},
+		{
+			PortfolioID: uuid.MustParse(portfolioID),
+			Symbol:      "AAPL",
+			Type:        models.TransactionTypeFXFee,
+			Quantity:    decimal.NewFromInt(15),
+			Date:        time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			PortfolioID: uuid.MustParse(portfolioID),
+			Symbol:      "SGOV",
+			Type:        models.TransactionTypeInterest,
+			Quantity:    decimal.NewFromInt(7),
+			Category:    "CASH_SWEEP",
+			Date:        time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		},
 	}
 
-	// GetPerformanceMetrics calls:
-	// - verifyPortfolioOwnership: 1 time
-	// - getSnapshotNearDate(start): 1 time
-	// - getSnapshotNearDate(end): 1 time
-	// - CalculateTWR: verifyPortfolioOwnership + FindByPortfolioIDAndDateRange + FindByPortfolioIDWithFilters
-	// - CalculateMWR: verifyPortfolioOwnership + 2x getSnapshotNearDate + FindByPortfolioIDWithFilters
-	// - FindByPortfolioIDWithFilters: 1 time
-	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil).Times(3)
-	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, startDate).Return(startSnapshot, nil).Times(2)
-	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, endDate).Return(endSnapshot, nil).Times(2)
-	snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, startDate, endDate).Return(snapshots, nil).Times(1)
-	transactionRepo.On("FindByPortfolioIDWithFilters", portfolioID, mock.Anything, &startDate, &endDate).Return(transactions, nil).Times(3)
+	// Every metric is computed from one load of the portfolio, of the snapshots from a week
+	// before to a week after the period and of the period's transactions
+	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil).Once()
+	snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, startDate.AddDate(0, 0, -7), endDate.AddDate(0, 0, 7)).Return(snapshots, nil).Once()
+	transactionRepo.On("FindByPortfolioIDWithFilters", portfolioID, mock.Anything, &startDate, &endDate).Return(transactions, nil).Once()
 
-	result, err := svc.GetPerformanceMetrics(portfolioID, userID, startDate, endDate)
+	result, err := svc.GetPerformanceMetrics(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -664,7 +713,31 @@ func TestGetPerformanceMetrics_Success(t *testing.T) {
 	assert.Equal(t, endDate, result.EndDate)
 	assert.Equal(t, decimal.NewFromInt(10000), result.StartingValue)
 	assert.Equal(t, decimal.NewFromInt(12000), result.EndingValue)
-	assert.True(t, result.TotalDeposits.GreaterThan(decimal.Zero))
+
+	// Deposits are the external cash flows, apart from the trades they funded
+	assert.True(t, decimal.NewFromInt(1500).Equal(result.TotalDeposits), result.TotalDeposits.String())
+	assert.True(t, result.TotalWithdrawals.IsZero())
+	assert.True(t, decimal.NewFromInt(1005).Equal(result.TotalPurchases), result.TotalPurchases.String())
+	assert.True(t, result.TotalSales.IsZero())
+
+	// The gain breakdown is what changed between the period's snapshots
+	assert.NotNil(t, result.Gains)
+	assert.True(t, decimal.NewFromInt(200).Equal(result.Gains.RealizedGain))
+	assert.True(t, decimal.NewFromInt(1000).Equal(result.Gains.UnrealizedGain))
+	assert.True(t, decimal.NewFromInt(30).Equal(result.Gains.DividendIncome))
+	assert.True(t, decimal.NewFromInt(2).Equal(result.Gains.Fees))
+
+	// The net return deducts the commission and the fee transaction from the gross one
+	assert.True(t, decimal.NewFromInt(20).Equal(result.TotalFees), result.TotalFees.String())
+	assert.True(t, decimal.NewFromInt(1980).Equal(result.NetReturn), result.NetReturn.String())
+	assert.True(t, decimal.RequireFromString("19.8").Equal(result.NetReturnPct), result.NetReturnPct.String())
+	assert.True(t, result.NetAnnualizedReturn.LessThan(result.AnnualizedReturn))
+
+	// Income is totaled by category
+	assert.True(t, decimal.NewFromInt(7).Equal(result.TotalIncome), result.TotalIncome.String())
+	if assert.Len(t, result.IncomeByCategory, 1) {
+		assert.Equal(t, "CASH_SWEEP", result.IncomeByCategory[0].Category)
+	}
 
 	portfolioRepo.AssertExpectations(t)
 	snapshotRepo.AssertExpectations(t)
@@ -672,6 +745,8 @@ func TestGetPerformanceMetrics_Success(t *testing.T) {
 }
 
 func TestGetPerformanceMetrics_UnauthorizedAccess(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -698,7 +773,7 @@ func TestGetPerformanceMetrics_UnauthorizedAccess(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 
-	result, err := svc.GetPerformanceMetrics(portfolioID, userID, startDate, endDate)
+	result, err := svc.GetPerformanceMetrics(ctx, portfolioID, userID, startDate, endDate)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -708,6 +783,8 @@ func TestGetPerformanceMetrics_UnauthorizedAccess(t *testing.T) {
 }
 
 func TestGetSnapshotNearDate_ExactMatch(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -731,7 +808,7 @@ func TestGetSnapshotNearDate_ExactMatch(t *testing.T) {
 
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, date).Return(snapshot, nil)
 
-	result, err := svc.getSnapshotNearDate(portfolioID, date)
+	result, err := svc.getSnapshotNearDate(ctx, portfolioID, date)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -741,6 +818,8 @@ func TestGetSnapshotNearDate_ExactMatch(t *testing.T) {
 }
 
 func TestGetSnapshotNearDate_ClosestMatch(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -772,7 +851,7 @@ func TestGetSnapshotNearDate_ClosestMatch(t *testing.T) {
 	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, targetDate).Return(nil, errors.New("not found"))
 	snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, mock.Anything, mock.Anything).Return(snapshots, nil)
 
-	result, err := svc.getSnapshotNearDate(portfolioID, targetDate)
+	result, err := svc.getSnapshotNearDate(ctx, portfolioID, targetDate)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -781,6 +860,56 @@ func TestGetSnapshotNearDate_ClosestMatch(t *testing.T) {
 	snapshotRepo.AssertExpectations(t)
 }
 
+func TestGetSnapshotNearDate_PrefersLastSession(t *testing.T) {
+	ctx := context.Background()
+
+	snapshotRepo := new(MockPerformanceSnapshotRepository)
+	svc := NewPerformanceAnalyticsService(
+		new(MockPortfolioRepository),
+		new(MockTransactionRepository),
+		snapshotRepo,
+		new(MockMarketDataService),
+	).(*performanceAnalyticsService)
+
+	portfolioID := uuid.New().String()
+	// Sunday after the Juneteenth holiday week: the last session was Friday the 21st
+	targetDate := time.Date(2024, 6, 23, 0, 0, 0, 0, time.UTC)
+
+	snapshots := []*models.PerformanceSnapshot{
+		{Date: time.Date(2024, 6, 20, 21, 0, 0, 0, time.UTC), TotalValue: decimal.NewFromInt(10000)},
+		{Date: time.Date(2024, 6, 21, 21, 0, 0, 0, time.UTC), TotalValue: decimal.NewFromInt(10100)},
+		// Closer to the target, but valued after Monday's session
+		{Date: time.Date(2024, 6, 24, 21, 0, 0, 0, time.UTC), TotalValue: decimal.NewFromInt(10200)},
+	}
+
+	snapshotRepo.On("FindByPortfolioIDAndDate", portfolioID, targetDate).Return(nil, errors.New("not found"))
+	snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, mock.Anything, mock.Anything).Return(snapshots, nil)
+
+	result, err := svc.getSnapshotNearDate(ctx, portfolioID, targetDate)
+
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10100).Equal(result.TotalValue))
+}
+
+func TestBenchmarkCloses(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 6, d, 0, 0, 0, 0, time.UTC) }
+	// Unsorted, with no bars on the weekend of the 15th
+	prices := []*HistoricalPrice{
+		{Date: day(18), Close: decimal.NewFromInt(104)},
+		{Date: day(13), Close: decimal.NewFromInt(100)},
+		{Date: day(17), Close: decimal.NewFromInt(103)},
+		{Date: day(14), Close: decimal.NewFromInt(101)},
+	}
+
+	start, end, ok := benchmarkCloses(prices, day(16), day(17))
+	assert.True(t, ok)
+	assert.True(t, decimal.NewFromInt(101).Equal(start), start.String())
+	assert.True(t, decimal.NewFromInt(103).Equal(end), end.String())
+
+	_, _, ok = benchmarkCloses(prices, day(15), day(16))
+	assert.False(t, ok, "no session between the dates")
+}
+
 func TestCalculateCashFlowBetweenDates(t *testing.T) {
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
@@ -927,6 +1056,8 @@ func TestCalculateIRR_InsufficientCashFlows(t *testing.T) {
 }
 
 func TestVerifyPortfolioOwnership_Success(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -950,13 +1081,15 @@ func TestVerifyPortfolioOwnership_Success(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 
-	err := svc.verifyPortfolioOwnership(portfolioID, userID)
+	err := svc.verifyPortfolioOwnership(ctx, portfolioID, userID)
 
 	assert.NoError(t, err)
 	portfolioRepo.AssertExpectations(t)
 }
 
 func TestVerifyPortfolioOwnership_NotFound(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -974,7 +1107,7 @@ func TestVerifyPortfolioOwnership_NotFound(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(nil, models.ErrPortfolioNotFound)
 
-	err := svc.verifyPortfolioOwnership(portfolioID, userID)
+	err := svc.verifyPortfolioOwnership(ctx, portfolioID, userID)
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrPortfolioNotFound, err)
@@ -982,6 +1115,8 @@ func TestVerifyPortfolioOwnership_NotFound(t *testing.T) {
 }
 
 func TestVerifyPortfolioOwnership_Unauthorized(t *testing.T) {
+	ctx := context.Background()
+
 	portfolioRepo := new(MockPortfolioRepository)
 	transactionRepo := new(MockTransactionRepository)
 	snapshotRepo := new(MockPerformanceSnapshotRepository)
@@ -1006,9 +1141,104 @@ func TestVerifyPortfolioOwnership_Unauthorized(t *testing.T) {
 
 	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
 
-	err := svc.verifyPortfolioOwnership(portfolioID, userID)
+	err := svc.verifyPortfolioOwnership(ctx, portfolioID, userID)
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrUnauthorizedAccess, err)
 	portfolioRepo.AssertExpectations(t)
 }
+
+// memoryMetricsCache is a PerformanceMetricsCacheRepository keeping entries in memory, with a
+// source stamp tests change to make them stale
+type memoryMetricsCache struct {
+	stamp   string
+	entries map[models.PerformancePeriod]*models.PerformanceMetricsCache
+}
+
+func (c *memoryMetricsCache) FindByPortfolioIDAndPeriod(ctx context.Context, portfolioID string, period models.PerformancePeriod) (*models.PerformanceMetricsCache, error) {
+	return c.entries[period], nil
+}
+
+func (c *memoryMetricsCache) Save(ctx context.Context, entry *models.PerformanceMetricsCache) error {
+	c.entries[entry.Period] = entry
+	return nil
+}
+
+func (c *memoryMetricsCache) SourceStamp(ctx context.Context, portfolioID string) (string, error) {
+	return c.stamp, nil
+}
+
+func TestPerformanceAnalyticsService_PeriodMetrics(t *testing.T) {
+	ctx := context.Background()
+	portfolioRepo := new(MockPortfolioRepository)
+	transactionRepo := new(MockTransactionRepository)
+	snapshotRepo := new(MockPerformanceSnapshotRepository)
+	cache := &memoryMetricsCache{stamp: "v1", entries: map[models.PerformancePeriod]*models.PerformanceMetricsCache{}}
+
+	svc := NewPerformanceAnalyticsServiceWithCache(portfolioRepo, transactionRepo, snapshotRepo, new(MockMarketDataService), cache).(*performanceAnalyticsService)
+	now := time.Date(2025, 5, 15, 2, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	portfolioID := uuid.New().String()
+	userID := uuid.New().String()
+	portfolio := &models.Portfolio{ID: uuid.MustParse(portfolioID), UserID: uuid.MustParse(userID)}
+	portfolioRepo.On("FindByID", portfolioID).Return(portfolio, nil)
+
+	// Snapshots every three days since March 2024, growing by 10 each time
+	var snapshots []*models.PerformanceSnapshot
+	inception := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for date, value := inception, int64(10000); !date.After(now); date, value = date.AddDate(0, 0, 3), value+10 {
+		snapshots = append(snapshots, &models.PerformanceSnapshot{Date: date, TotalValue: decimal.NewFromInt(value)})
+	}
+
+	// Every period is materialized from one load of all snapshots and transactions
+	snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, time.Time{}, now.AddDate(0, 0, 7)).Return(snapshots, nil).Once()
+	transactionRepo.On("FindByPortfolioIDWithFilters", portfolioID, mock.Anything, (*time.Time)(nil), &now).Return([]*models.Transaction{}, nil).Once()
+
+	stored, err := svc.MaterializePeriodMetrics(ctx, portfolioID)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, stored, "the portfolio is younger than three years")
+	assert.Len(t, cache.entries, 6)
+	assert.Equal(t, "null", cache.entries[models.PerformancePeriod3Y].Metrics)
+	assert.Equal(t, inception, cache.entries[models.PerformancePeriodAll].StartDate)
+
+	t.Run("every period is served from the cache", func(t *testing.T) {
+		metrics, err := svc.GetStandardPeriodMetrics(ctx, portfolioID, userID)
+		assert.NoError(t, err)
+		assert.Len(t, metrics, 5)
+		assert.NotContains(t, metrics, models.PerformancePeriod3Y)
+		assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), metrics[models.PerformancePeriodYTD].StartDate.UTC())
+		snapshotRepo.AssertExpectations(t)
+	})
+
+	t.Run("fresh metrics are served from the cache", func(t *testing.T) {
+		metrics, err := svc.GetPeriodMetrics(ctx, portfolioID, userID, models.PerformancePeriod1Y)
+		assert.NoError(t, err)
+		assert.Equal(t, now.AddDate(-1, 0, 0), metrics.StartDate.UTC())
+		assert.True(t, metrics.EndingValue.GreaterThan(metrics.StartingValue))
+		snapshotRepo.AssertExpectations(t)
+		transactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("stale metrics are computed again and stored", func(t *testing.T) {
+		cache.stamp = "v2"
+		start := now.AddDate(0, -1, 0)
+		snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, start.AddDate(0, 0, -7), now.AddDate(0, 0, 7)).Return(snapshots, nil).Once()
+		transactionRepo.On("FindByPortfolioIDWithFilters", portfolioID, mock.Anything, &start, &now).Return([]*models.Transaction{}, nil).Once()
+
+		metrics, err := svc.GetPeriodMetrics(ctx, portfolioID, userID, models.PerformancePeriod1M)
+		assert.NoError(t, err)
+		assert.Equal(t, start, metrics.StartDate)
+		assert.Equal(t, "v2", cache.entries[models.PerformancePeriod1M].SourceStamp)
+		snapshotRepo.AssertExpectations(t)
+	})
+
+	t.Run("periods before the first snapshot", func(t *testing.T) {
+		start := now.AddDate(-3, 0, 0)
+		snapshotRepo.On("FindByPortfolioIDAndDateRange", portfolioID, start.AddDate(0, 0, -7), now.AddDate(0, 0, 7)).Return(snapshots, nil).Once()
+		transactionRepo.On("FindByPortfolioIDWithFilters", portfolioID, mock.Anything, &start, &now).Return([]*models.Transaction{}, nil).Once()
+
+		_, err := svc.GetPeriodMetrics(ctx, portfolioID, userID, models.PerformancePeriod3Y)
+		assert.ErrorIs(t, err, models.ErrInsufficientPerformanceHistory)
+	})
+}
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type alias for dto type for consistency with the other analytics services
+type PerformanceCertification = dto.PerformanceCertification
+
+// certificationSignatureAlgorithm is the only signature scheme certifications use
+const certificationSignatureAlgorithm = "HMAC-SHA256"
+
+// Methodology statements included in every certification. They describe the calculations in
+// this file and twrSubPeriods, so change them together.
+const (
+	certificationReturnMethod = "Time-weighted return. The period is split into sub-periods at every " +
+		"valuation; each sub-period return is (end value - net cash flow) / start value - 1, and " +
+		"sub-period returns are geometrically linked: TWR = (1 + R1) x (1 + R2) x ... x (1 + Rn) - 1. " +
+		"Sub-periods starting from a zero value have no defined return and are excluded from the link."
+	certificationAnnualization = "Annualized TWR = (1 + TWR)^(1 / years) - 1, with years = days in the " +
+		"requested period / 365.25. Reported for any period length."
+	certificationCashFlowTreatment = "Buys are external inflows at total cost including commission; sells " +
+		"are external outflows at proceeds net of commission. Other transactions are not cash flows. " +
+		"Cash flows are assumed to occur at the end of their trade date and are attributed to the " +
+		"sub-period that ends on or after that date."
+	certificationValuationPolicy = "Valuations are the portfolio's recorded performance snapshots: the " +
+		"market value of every holding at the prices supplied when the snapshot was taken, or its cost " +
+		"basis when no price was available. Snapshots are not revalued after they are recorded."
+)
+
+// PerformanceCertificationService defines the interface for exporting signed performance
+// certifications and checking them later
+type PerformanceCertificationService interface {
+	Certify(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*PerformanceCertification, error)
+	Verify(certification *PerformanceCertification) (*dto.CertificationVerificationResponse, error)
+}
+
+// performanceCertificationService implements PerformanceCertificationService interface
+type performanceCertificationService struct {
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.PerformanceSnapshotRepository
+	signingKey      []byte
+	now             func() time.Time
+}
+
+// NewPerformanceCertificationService creates a new PerformanceCertificationService instance.
+// Certifications are signed with a key derived from secret, so they can only be verified by
+// servers sharing it and stop verifying if it is rotated.
+func NewPerformanceCertificationService(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	secret []byte,
+) PerformanceCertificationService {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("portfolios performance certification"))
+
+	return &performanceCertificationService{
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+		signingKey:      mac.Sum(nil),
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Certify computes a portfolio's time-weighted return over the period from its recorded
+// snapshots and returns it signed, together with every input and the methodology used
+func (s *performanceCertificationService) Certify(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) (*PerformanceCertification, error) {
+	if !endDate.After(startDate) {
+		return nil, models.ErrInvalidCertificationPeriod
+	}
+
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	snapshots, err := s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve snapshots: %w", err)
+	}
+	if len(snapshots) < 2 {
+		return nil, models.ErrInsufficientCertificationData
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+
+	transactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, &startDate, &endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+
+	subPeriods, twr := twrSubPeriods(snapshots, transactions)
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+
+	results := dto.PerformanceCertificationResults{
+		TWR:           twr,
+		TWRPercent:    twr.Mul(decimal.NewFromInt(100)),
+		AnnualizedTWR: decimal.Zero,
+		StartingValue: first.TotalValue,
+		EndingValue:   last.TotalValue,
+		NetCashFlow:   decimal.Zero,
+		NumSubPeriods: len(subPeriods),
+	}
+	for _, period := range subPeriods {
+		results.NetCashFlow = results.NetCashFlow.Add(period.CashFlow)
+		if period.Excluded {
+			results.ExcludedPeriods++
+		}
+	}
+	if years := endDate.Sub(startDate).Hours() / 24 / 365.25; years > 0 {
+		growth, _ := twr.Add(decimal.NewFromInt(1)).Float64()
+		results.AnnualizedTWR = decimal.NewFromFloat((math.Pow(growth, 1/years) - 1) * 100)
+	}
+
+	certification := &PerformanceCertification{
+		Format:      dto.PerformanceCertificationFormat,
+		GeneratedAt: s.now().Truncate(time.Second),
+		Portfolio: dto.CertifiedPortfolio{
+			ID:           portfolio.ID.String(),
+			Name:         portfolio.Name,
+			BaseCurrency: portfolio.BaseCurrency,
+		},
+		StartDate: startDate,
+		EndDate:   endDate,
+		Results:   results,
+		Methodology: dto.PerformanceCertificationMethodology{
+			ReturnMethod:      certificationReturnMethod,
+			Annualization:     certificationAnnualization,
+			CashFlowTreatment: certificationCashFlowTreatment,
+			ValuationPolicy:   certificationValuationPolicy,
+			SubPeriods:        subPeriods,
+			CashFlows:         certifiedCashFlows(transactions, first.Date, last.Date),
+			Valuations:        certifiedValuations(snapshots),
+		},
+	}
+
+	if err := s.sign(certification); err != nil {
+		return nil, err
+	}
+	return certification, nil
+}
+
+// Verify reports whether a certification is exactly as this server signed it
+func (s *performanceCertificationService) Verify(certification *PerformanceCertification) (*dto.CertificationVerificationResponse, error) {
+	if certification.Format != dto.PerformanceCertificationFormat ||
+		certification.Signature.Algorithm != certificationSignatureAlgorithm {
+		return nil, models.ErrUnsupportedCertification
+	}
+
+	digest, signature, err := s.signatureOf(certification)
+	if err != nil {
+		return nil, err
+	}
+
+	given, err := hex.DecodeString(certification.Signature.Value)
+	valid := err == nil && hmac.Equal(given, signature) && certification.Signature.ContentDigest == digest
+
+	return &dto.CertificationVerificationResponse{
+		Valid:         valid,
+		ContentDigest: digest,
+	}, nil
+}
+
+// sign sets the certification's signature
+func (s *performanceCertificationService) sign(certification *PerformanceCertification) error {
+	digest, signature, err := s.signatureOf(certification)
+	if err != nil {
+		return err
+	}
+
+	certification.Signature = dto.CertificationSignature{
+		Algorithm:     certificationSignatureAlgorithm,
+		ContentDigest: digest,
+		Value:         hex.EncodeToString(signature),
+	}
+	return nil
+}
+
+// signatureOf returns the content digest and signature of the certification's JSON encoding
+// with its signature left empty. Decoding and re-encoding a certification reproduces the same
+// encoding, which is what lets a returned report be verified.
+func (s *performanceCertificationService) signatureOf(certification *PerformanceCertification) (string, []byte, error) {
+	unsigned := *certification
+	unsigned.Signature = dto.CertificationSignature{}
+
+	content, err := json.Marshal(unsigned)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode certification: %w", err)
+	}
+
+	digest := sha256.Sum256(content)
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write(content)
+
+	return hex.EncodeToString(digest[:]), mac.Sum(nil), nil
+}
+
+// certifiedCashFlows lists the external cash flows that fall in a sub-period, using the same
+// rules as cashFlowBetweenDates
+func certifiedCashFlows(transactions []*models.Transaction, firstValuation, lastValuation time.Time) []dto.CertifiedCashFlow {
+	flows := []dto.CertifiedCashFlow{}
+	for _, tx := range transactions {
+		if !tx.Date.After(firstValuation) || tx.Date.After(lastValuation) {
+			continue
+		}
+
+		var amount decimal.Decimal
+		switch {
+		case tx.IsBuy():
+			amount = tx.GetTotalCost()
+		case tx.IsSell():
+			amount = tx.GetProceeds().Neg()
+		default:
+			continue
+		}
+
+		flows = append(flows, dto.CertifiedCashFlow{
+			TransactionID: tx.ID.String(),
+			Date:          tx.Date,
+			Type:          string(tx.Type),
+			Symbol:        tx.Symbol,
+			Amount:        amount,
+		})
+	}
+	return flows
+}
+
+// certifiedValuations lists the snapshots the sub-periods were built from
+func certifiedValuations(snapshots []*models.PerformanceSnapshot) []dto.CertifiedValuation {
+	valuations := make([]dto.CertifiedValuation, len(snapshots))
+	for i, snapshot := range snapshots {
+		valuations[i] = dto.CertifiedValuation{
+			SnapshotID:     snapshot.ID.String(),
+			Date:           snapshot.Date,
+			TotalValue:     snapshot.TotalValue,
+			TotalCostBasis: snapshot.TotalCostBasis,
+			RecordedAt:     snapshot.CreatedAt,
+		}
+	}
+	return valuations
+}
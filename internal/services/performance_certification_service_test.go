@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupPerformanceCertificationTest(t *testing.T, secret string) (*gorm.DB, *performanceCertificationService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.PerformanceSnapshot{}))
+
+	service := NewPerformanceCertificationService(
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+		[]byte(secret),
+	).(*performanceCertificationService)
+	service.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	return db, service
+}
+
+func TestPerformanceCertificationService_Certify(t *testing.T) {
+	db, service := setupPerformanceCertificationTest(t, "secret")
+	ctx := context.Background()
+
+	user := &models.User{Email: "advisor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Client", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	dec := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	for date, value := range map[time.Time]int64{jan: 10000, jul: 12000, dec: 13200} {
+		require.NoError(t, db.Create(&models.PerformanceSnapshot{
+			PortfolioID:    portfolio.ID,
+			Date:           date,
+			TotalValue:     decimal.NewFromInt(value),
+			TotalCostBasis: decimal.NewFromInt(10000),
+		}).Error)
+	}
+
+	// A $1,000 buy in the second half: (13200 - 1000) / 12000 - 1 = 1.6667%
+	price := decimal.NewFromInt(100)
+	buy := &models.Transaction{
+		PortfolioID: portfolio.ID,
+		Type:        models.TransactionTypeBuy,
+		Symbol:      "VTI",
+		Date:        time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC),
+		Quantity:    decimal.NewFromInt(10),
+		Price:       &price,
+	}
+	require.NoError(t, db.Create(buy).Error)
+
+	certification, err := service.Certify(ctx, portfolio.ID.String(), user.ID.String(), jan, dec)
+	require.NoError(t, err)
+
+	assert.Equal(t, "portfolios.performance-certification/v1", certification.Format)
+	assert.Equal(t, "Client", certification.Portfolio.Name)
+	assert.Equal(t, 2, certification.Results.NumSubPeriods)
+	assert.True(t, decimal.NewFromInt(1000).Equal(certification.Results.NetCashFlow))
+	assert.Equal(t, "22.0", certification.Results.TWRPercent.StringFixed(1))
+
+	require.Len(t, certification.Methodology.SubPeriods, 2)
+	assert.Equal(t, "20.00", certification.Methodology.SubPeriods[0].Return.Mul(decimal.NewFromInt(100)).StringFixed(2))
+	assert.True(t, decimal.NewFromInt(1000).Equal(certification.Methodology.SubPeriods[1].CashFlow))
+	require.Len(t, certification.Methodology.CashFlows, 1)
+	assert.Equal(t, buy.ID.String(), certification.Methodology.CashFlows[0].TransactionID)
+	assert.Len(t, certification.Methodology.Valuations, 3)
+	assert.NotEmpty(t, certification.Methodology.CashFlowTreatment)
+
+	assert.Equal(t, "HMAC-SHA256", certification.Signature.Algorithm)
+	assert.Len(t, certification.Signature.ContentDigest, 64)
+
+	t.Run("verifies after a JSON round trip", func(t *testing.T) {
+		encoded, err := json.Marshal(certification)
+		require.NoError(t, err)
+		var decoded PerformanceCertification
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+
+		result, err := service.Verify(&decoded)
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.Equal(t, certification.Signature.ContentDigest, result.ContentDigest)
+	})
+
+	t.Run("detects changed results", func(t *testing.T) {
+		tampered := *certification
+		tampered.Results.TWRPercent = decimal.NewFromInt(30)
+
+		result, err := service.Verify(&tampered)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("rejects signatures from another key", func(t *testing.T) {
+		_, other := setupPerformanceCertificationTest(t, "another secret")
+
+		result, err := other.Verify(certification)
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("rejects unknown formats", func(t *testing.T) {
+		unknown := *certification
+		unknown.Format = "something-else"
+
+		_, err := service.Verify(&unknown)
+		assert.ErrorIs(t, err, models.ErrUnsupportedCertification)
+	})
+
+	t.Run("needs two snapshots", func(t *testing.T) {
+		_, err := service.Certify(ctx, portfolio.ID.String(), user.ID.String(), jan, jan.AddDate(0, 1, 0))
+		assert.ErrorIs(t, err, models.ErrInsufficientCertificationData)
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		_, err := service.Certify(ctx, portfolio.ID.String(), user.ID.String(), dec, jan)
+		assert.ErrorIs(t, err, models.ErrInvalidCertificationPeriod)
+	})
+
+	t.Run("other user's portfolio", func(t *testing.T) {
+		_, err := service.Certify(ctx, portfolio.ID.String(), uuid.New().String(), jan, dec)
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+	})
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -11,10 +12,10 @@ import (
 
 // PerformanceSnapshotService defines the interface for performance snapshot operations
 type PerformanceSnapshotService interface {
-	CreateSnapshot(portfolioID, userID string, prices map[string]decimal.Decimal) (*models.PerformanceSnapshot, error)
-	GetByPortfolioID(portfolioID, userID string, limit, offset int) ([]*models.PerformanceSnapshot, error)
-	GetByDateRange(portfolioID, userID string, startDate, endDate time.Time) ([]*models.PerformanceSnapshot, error)
-	GetLatest(portfolioID, userID string) (*models.PerformanceSnapshot, error)
+	CreateSnapshot(ctx context.Context, portfolioID, userID string, prices map[string]decimal.Decimal) (*models.PerformanceSnapshot, error)
+	GetByPortfolioID(ctx context.Context, portfolioID, userID string, limit, offset int) ([]*models.PerformanceSnapshot, error)
+	GetByDateRange(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) ([]*models.PerformanceSnapshot, error)
+	GetLatest(ctx context.Context, portfolioID, userID string) (*models.PerformanceSnapshot, error)
 }
 
 // performanceSnapshotService implements PerformanceSnapshotService interface
@@ -22,6 +23,9 @@ type performanceSnapshotService struct {
 	snapshotRepo  repository.PerformanceSnapshotRepository
 	portfolioRepo repository.PortfolioRepository
 	holdingRepo   repository.HoldingRepository
+	// transactionRepo is replayed for the gain breakdown; without it snapshots have none
+	transactionRepo repository.TransactionRepository
+	rounding        models.RoundingPolicy
 }
 
 // NewPerformanceSnapshotService creates a new PerformanceSnapshotService instance
@@ -29,30 +33,46 @@ func NewPerformanceSnapshotService(
 	snapshotRepo repository.PerformanceSnapshotRepository,
 	portfolioRepo repository.PortfolioRepository,
 	holdingRepo repository.HoldingRepository,
+) PerformanceSnapshotService {
+	return NewPerformanceSnapshotServiceWithLedger(snapshotRepo, portfolioRepo, holdingRepo, nil, models.DefaultRoundingPolicy())
+}
+
+// NewPerformanceSnapshotServiceWithLedger creates a PerformanceSnapshotService that records
+// the realized gains, dividend income and fees of each snapshot by replaying the portfolio's
+// transactions, rounding corporate actions with rounding
+func NewPerformanceSnapshotServiceWithLedger(
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	transactionRepo repository.TransactionRepository,
+	rounding models.RoundingPolicy,
 ) PerformanceSnapshotService {
 	return &performanceSnapshotService{
-		snapshotRepo:  snapshotRepo,
-		portfolioRepo: portfolioRepo,
-		holdingRepo:   holdingRepo,
+		snapshotRepo:    snapshotRepo,
+		portfolioRepo:   portfolioRepo,
+		holdingRepo:     holdingRepo,
+		transactionRepo: transactionRepo,
+		rounding:        rounding,
 	}
 }
 
 // CreateSnapshot creates a performance snapshot for a portfolio at the current time
 func (s *performanceSnapshotService) CreateSnapshot(
+	ctx context.Context,
 	portfolioID, userID string,
 	prices map[string]decimal.Decimal,
 ) (*models.PerformanceSnapshot, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
 	// Get all holdings for the portfolio
-	holdings, err := s.holdingRepo.FindByPortfolioID(portfolioID)
+	holdings, err := s.holdingRepo.FindByPortfolioID(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve holdings: %w", err)
 	}
@@ -71,7 +91,7 @@ func (s *performanceSnapshotService) CreateSnapshot(
 			// If no price provided, use cost basis (conservative estimate)
 			totalValue = totalValue.Add(holding.CostBasis)
 		} else {
-			marketValue := holding.Quantity.Mul(price)
+			marketValue := holding.MarketValue(price)
 			totalValue = totalValue.Add(marketValue)
 		}
 	}
@@ -86,65 +106,101 @@ func (s *performanceSnapshotService) CreateSnapshot(
 
 	// Calculate return metrics
 	snapshot.CalculateMetrics()
+	if totals, ok := s.ledgerTotals(ctx, portfolio, snapshot.Date); ok {
+		snapshot.SetGainBreakdown(totals.Realized, totals.Dividends, totals.Fees)
+	}
 
 	// Try to get previous day's snapshot for day change calculation
-	previousSnapshot, err := s.snapshotRepo.FindLatestByPortfolioID(portfolioID)
+	previousSnapshot, err := s.snapshotRepo.FindLatestByPortfolioID(ctx, portfolioID)
 	if err == nil && previousSnapshot != nil {
 		snapshot.CalculateDayChange(previousSnapshot.TotalValue)
 	}
 
 	// Save snapshot
-	if err := s.snapshotRepo.Create(snapshot); err != nil {
+	if err := s.snapshotRepo.Create(ctx, snapshot); err != nil {
 		return nil, fmt.Errorf("failed to create snapshot: %w", err)
 	}
 
 	return snapshot, nil
 }
 
+// ledgerTotals replays the portfolio's transactions dated up to date for their realized
+// gains, dividends and fees. A snapshot is still taken without them when the transactions
+// can't be loaded or replayed, so ok is false then.
+func (s *performanceSnapshotService) ledgerTotals(ctx context.Context, portfolio *models.Portfolio, date time.Time) (ledgerTotals, bool) {
+	if s.transactionRepo == nil {
+		return ledgerTotals{}, false
+	}
+
+	var transactions []*models.Transaction
+	err := s.transactionRepo.IterateByPortfolioID(ctx, portfolio.ID.String(), func(batch []*models.Transaction) error {
+		for _, tx := range batch {
+			if !tx.Date.After(date) {
+				transactions = append(transactions, tx)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ledgerTotals{}, false
+	}
+	sortTransactionsForReplay(transactions)
+
+	replay := newLedgerReplay(portfolio, s.rounding)
+	for _, tx := range transactions {
+		if err := replay.apply(tx); err != nil {
+			return ledgerTotals{}, false
+		}
+	}
+	return replay.totals, true
+}
+
 // GetByPortfolioID retrieves performance snapshots for a portfolio
 func (s *performanceSnapshotService) GetByPortfolioID(
+	ctx context.Context,
 	portfolioID, userID string,
 	limit, offset int,
 ) ([]*models.PerformanceSnapshot, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	return s.snapshotRepo.FindByPortfolioID(portfolioID, limit, offset)
+	return s.snapshotRepo.FindByPortfolioID(ctx, portfolioID, limit, offset)
 }
 
 // GetByDateRange retrieves performance snapshots for a portfolio within a date range
 func (s *performanceSnapshotService) GetByDateRange(
+	ctx context.Context,
 	portfolioID, userID string,
 	startDate, endDate time.Time,
 ) ([]*models.PerformanceSnapshot, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	return s.snapshotRepo.FindByPortfolioIDAndDateRange(portfolioID, startDate, endDate)
+	return s.snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, startDate, endDate)
 }
 
 // GetLatest retrieves the most recent performance snapshot for a portfolio
-func (s *performanceSnapshotService) GetLatest(portfolioID, userID string) (*models.PerformanceSnapshot, error) {
+func (s *performanceSnapshotService) GetLatest(ctx context.Context, portfolioID, userID string) (*models.PerformanceSnapshot, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	return s.snapshotRepo.FindLatestByPortfolioID(portfolioID)
+	return s.snapshotRepo.FindLatestByPortfolioID(ctx, portfolioID)
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -21,6 +22,8 @@ func TestNewPerformanceSnapshotService(t *testing.T) {
 }
 
 func TestPerformanceSnapshotService_CreateSnapshot(t *testing.T) {
+	ctx := context.Background()
+
 	mockSnapshotRepo := new(MockPerformanceSnapshotRepository)
 	mockPortfolioRepo := new(MockPortfolioRepository)
 	mockHoldingRepo := new(MockHoldingRepository)
@@ -66,7 +69,7 @@ func TestPerformanceSnapshotService_CreateSnapshot(t *testing.T) {
 		mockSnapshotRepo.On("FindLatestByPortfolioID", portfolioID.String()).Return(previousSnapshot, nil).Once()
 		mockSnapshotRepo.On("Create", mock.AnythingOfType("*models.PerformanceSnapshot")).Return(nil).Once()
 
-		snapshot, err := service.CreateSnapshot(portfolioID.String(), userID.String(), prices)
+		snapshot, err := service.CreateSnapshot(ctx, portfolioID.String(), userID.String(), prices)
 		assert.NoError(t, err)
 		assert.NotNil(t, snapshot)
 		assert.True(t, snapshot.TotalValue.Equal(decimal.NewFromInt(18000)))
@@ -79,14 +82,82 @@ func TestPerformanceSnapshotService_CreateSnapshot(t *testing.T) {
 	t.Run("portfolio not found", func(t *testing.T) {
 		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(nil, models.ErrPortfolioNotFound).Once()
 
-		_, err := service.CreateSnapshot(portfolioID.String(), userID.String(), prices)
+		_, err := service.CreateSnapshot(ctx, portfolioID.String(), userID.String(), prices)
 		assert.Equal(t, models.ErrPortfolioNotFound, err)
 
 		mockPortfolioRepo.AssertExpectations(t)
 	})
 }
 
+func TestPerformanceSnapshotService_CreateSnapshot_GainBreakdown(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	userID := uuid.New()
+	portfolio := &models.Portfolio{ID: portfolioID, UserID: userID, CostBasisMethod: models.CostBasisFIFO}
+	holdings := []*models.Holding{{
+		PortfolioID:  portfolioID,
+		Symbol:       "AAPL",
+		Quantity:     decimal.NewFromInt(5),
+		CostBasis:    decimal.NewFromInt(500),
+		AvgCostPrice: decimal.NewFromInt(100),
+	}}
+	prices := map[string]decimal.Decimal{"AAPL": decimal.NewFromInt(130)}
+	buyPrice, sellPrice := decimal.NewFromInt(100), decimal.NewFromInt(120)
+	transactions := []*models.Transaction{
+		{ID: uuid.New(), Type: models.TransactionTypeSell, Symbol: "AAPL", Date: time.Now().AddDate(0, 0, -1),
+			Quantity: decimal.NewFromInt(5), Price: &sellPrice, Commission: decimal.NewFromInt(2)},
+		{ID: uuid.New(), Type: models.TransactionTypeDividend, Symbol: "AAPL", Date: time.Now().AddDate(0, 0, -2),
+			Quantity: decimal.NewFromInt(8)},
+		{ID: uuid.New(), Type: models.TransactionTypeBuy, Symbol: "AAPL", Date: time.Now().AddDate(0, 0, -3),
+			Quantity: decimal.NewFromInt(10), Price: &buyPrice},
+		// Transactions dated after the snapshot are left out
+		{ID: uuid.New(), Type: models.TransactionTypeDividend, Symbol: "AAPL", Date: time.Now().AddDate(0, 0, 7),
+			Quantity: decimal.NewFromInt(50)},
+	}
+
+	setup := func(iterateErr error) PerformanceSnapshotService {
+		mockSnapshotRepo := new(MockPerformanceSnapshotRepository)
+		mockPortfolioRepo := new(MockPortfolioRepository)
+		mockHoldingRepo := new(MockHoldingRepository)
+		mockTransactionRepo := new(MockTransactionRepository)
+		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
+		mockHoldingRepo.On("FindByPortfolioID", portfolioID.String()).Return(holdings, nil)
+		mockTransactionRepo.On("IterateByPortfolioID", portfolioID.String(), mock.Anything).
+			Run(func(args mock.Arguments) {
+				if iterateErr == nil {
+					_ = args.Get(1).(func([]*models.Transaction) error)(transactions)
+				}
+			}).Return(iterateErr)
+		mockSnapshotRepo.On("FindLatestByPortfolioID", portfolioID.String()).Return(nil, models.ErrPerformanceSnapshotNotFound)
+		mockSnapshotRepo.On("Create", mock.AnythingOfType("*models.PerformanceSnapshot")).Return(nil)
+		return NewPerformanceSnapshotServiceWithLedger(
+			mockSnapshotRepo, mockPortfolioRepo, mockHoldingRepo, mockTransactionRepo, models.DefaultRoundingPolicy(),
+		)
+	}
+
+	t.Run("records the breakdown of the replayed ledger", func(t *testing.T) {
+		service := setup(nil)
+		snapshot, err := service.CreateSnapshot(ctx, portfolioID.String(), userID.String(), prices)
+		assert.NoError(t, err)
+		assert.True(t, snapshot.HasGainBreakdown())
+		assert.True(t, decimal.NewFromInt(98).Equal(*snapshot.RealizedGain), snapshot.RealizedGain.String())
+		assert.True(t, decimal.NewFromInt(150).Equal(*snapshot.UnrealizedGain), snapshot.UnrealizedGain.String())
+		assert.True(t, decimal.NewFromInt(8).Equal(*snapshot.DividendIncome), snapshot.DividendIncome.String())
+		assert.True(t, decimal.NewFromInt(2).Equal(*snapshot.Fees))
+	})
+
+	t.Run("leaves the breakdown out when the ledger can't be loaded", func(t *testing.T) {
+		service := setup(assert.AnError)
+		snapshot, err := service.CreateSnapshot(ctx, portfolioID.String(), userID.String(), prices)
+		assert.NoError(t, err)
+		assert.False(t, snapshot.HasGainBreakdown())
+		assert.True(t, decimal.NewFromInt(650).Equal(snapshot.TotalValue))
+	})
+}
+
 func TestPerformanceSnapshotService_GetByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	mockSnapshotRepo := new(MockPerformanceSnapshotRepository)
 	mockPortfolioRepo := new(MockPortfolioRepository)
 	mockHoldingRepo := new(MockHoldingRepository)
@@ -117,7 +188,7 @@ func TestPerformanceSnapshotService_GetByPortfolioID(t *testing.T) {
 		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil).Once()
 		mockSnapshotRepo.On("FindByPortfolioID", portfolioID.String(), 10, 0).Return(snapshots, nil).Once()
 
-		result, err := service.GetByPortfolioID(portfolioID.String(), userID.String(), 10, 0)
+		result, err := service.GetByPortfolioID(ctx, portfolioID.String(), userID.String(), 10, 0)
 		assert.NoError(t, err)
 		assert.Len(t, result, 1)
 
@@ -127,6 +198,8 @@ func TestPerformanceSnapshotService_GetByPortfolioID(t *testing.T) {
 }
 
 func TestPerformanceSnapshotService_GetLatest(t *testing.T) {
+	ctx := context.Background()
+
 	mockSnapshotRepo := new(MockPerformanceSnapshotRepository)
 	mockPortfolioRepo := new(MockPortfolioRepository)
 	mockHoldingRepo := new(MockHoldingRepository)
@@ -155,7 +228,7 @@ func TestPerformanceSnapshotService_GetLatest(t *testing.T) {
 		mockPortfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil).Once()
 		mockSnapshotRepo.On("FindLatestByPortfolioID", portfolioID.String()).Return(snapshot, nil).Once()
 
-		result, err := service.GetLatest(portfolioID.String(), userID.String())
+		result, err := service.GetLatest(ctx, portfolioID.String(), userID.String())
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
 
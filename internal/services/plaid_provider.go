@@ -0,0 +1,311 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+const (
+	plaidSandboxURL    = "https://sandbox.plaid.com"
+	plaidProductionURL = "https://production.plaid.com"
+
+	// plaidPageSize is how many investment transactions are requested at once, Plaid's maximum
+	plaidPageSize = 500
+)
+
+// plaidDividendSubtypes are the subtypes of Plaid cash transactions that are dividends
+var plaidDividendSubtypes = map[string]bool{
+	"dividend":               true,
+	"qualified dividend":     true,
+	"non-qualified dividend": true,
+}
+
+// PlaidProvider implements BrokerDataProvider with Plaid's Investments product. Holdings
+// and investment transactions are pulled for one account of the Item an access token was
+// issued for.
+type PlaidProvider struct {
+	clientID   string
+	secret     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewPlaidProvider creates a Plaid provider for the sandbox or production environment
+func NewPlaidProvider(clientID, secret, environment string) *PlaidProvider {
+	baseURL := plaidSandboxURL
+	if environment == "production" {
+		baseURL = plaidProductionURL
+	}
+
+	return &PlaidProvider{
+		clientID: clientID,
+		secret:   secret,
+		baseURL:  baseURL,
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// Name returns the provider broker connections are made with
+func (p *PlaidProvider) Name() models.BrokerProvider {
+	return models.BrokerProviderPlaid
+}
+
+// plaidSecurity is a security of a Plaid holdings or transactions response
+type plaidSecurity struct {
+	ID     string  `json:"security_id"`
+	Ticker *string `json:"ticker_symbol"`
+	Type   string  `json:"type"`
+}
+
+// plaidHolding is a position of a Plaid holdings response
+type plaidHolding struct {
+	AccountID  string           `json:"account_id"`
+	SecurityID string           `json:"security_id"`
+	Quantity   decimal.Decimal  `json:"quantity"`
+	CostBasis  *decimal.Decimal `json:"cost_basis"`
+	Currency   *string          `json:"iso_currency_code"`
+}
+
+// plaidInvestmentTransaction is a transaction of a Plaid investment transactions response.
+// Amounts are positive for money leaving the account, and sold quantities are negative.
+type plaidInvestmentTransaction struct {
+	ID         string           `json:"investment_transaction_id"`
+	AccountID  string           `json:"account_id"`
+	SecurityID *string          `json:"security_id"`
+	Date       string           `json:"date"`
+	Name       string           `json:"name"`
+	Quantity   decimal.Decimal  `json:"quantity"`
+	Amount     decimal.Decimal  `json:"amount"`
+	Price      decimal.Decimal  `json:"price"`
+	Fees       *decimal.Decimal `json:"fees"`
+	Type       string           `json:"type"`
+	Subtype    string           `json:"subtype"`
+	Currency   *string          `json:"iso_currency_code"`
+}
+
+// GetPositions retrieves the account's current positions. Cash and securities without a
+// ticker are left out.
+func (p *PlaidProvider) GetPositions(ctx context.Context, accessToken, accountID string) ([]BrokerPosition, error) {
+	var response struct {
+		Holdings   []plaidHolding  `json:"holdings"`
+		Securities []plaidSecurity `json:"securities"`
+	}
+	request := map[string]any{
+		"access_token": accessToken,
+		"options":      map[string]any{"account_ids": []string{accountID}},
+	}
+	if err := p.post(ctx, "/investments/holdings/get", request, &response); err != nil {
+		return nil, fmt.Errorf("failed to fetch holdings: %w", err)
+	}
+
+	tickers := plaidTickers(response.Securities)
+	var positions []BrokerPosition
+	for _, holding := range response.Holdings {
+		symbol, ok := tickers[holding.SecurityID]
+		if holding.AccountID != accountID || !ok {
+			continue
+		}
+		positions = append(positions, BrokerPosition{
+			Symbol:    symbol,
+			Quantity:  holding.Quantity,
+			CostBasis: holding.CostBasis,
+			Currency:  plaidCurrency(holding.Currency),
+		})
+	}
+	return positions, nil
+}
+
+// GetTransactions retrieves the account's investment transactions dated from start to end,
+// oldest first, following Plaid's pages
+func (p *PlaidProvider) GetTransactions(ctx context.Context, accessToken, accountID string, start, end time.Time) ([]BrokerTransaction, error) {
+	var transactions []BrokerTransaction
+	for offset := 0; ; {
+		var response struct {
+			Transactions []plaidInvestmentTransaction `json:"investment_transactions"`
+			Securities   []plaidSecurity              `json:"securities"`
+			Total        int                          `json:"total_investment_transactions"`
+		}
+		request := map[string]any{
+			"access_token": accessToken,
+			"start_date":   start.Format("2006-01-02"),
+			"end_date":     end.Format("2006-01-02"),
+			"options": map[string]any{
+				"account_ids": []string{accountID},
+				"count":       plaidPageSize,
+				"offset":      offset,
+			},
+		}
+		if err := p.post(ctx, "/investments/transactions/get", request, &response); err != nil {
+			return nil, fmt.Errorf("failed to fetch investment transactions: %w", err)
+		}
+
+		tickers := plaidTickers(response.Securities)
+		for _, tx := range response.Transactions {
+			if tx.AccountID != accountID {
+				continue
+			}
+			mapped, err := mapPlaidTransaction(tx, tickers)
+			if err != nil {
+				return nil, err
+			}
+			transactions = append(transactions, mapped)
+		}
+
+		offset += len(response.Transactions)
+		if len(response.Transactions) == 0 || offset >= response.Total {
+			break
+		}
+	}
+
+	// Plaid lists the newest transactions first
+	for i, j := 0, len(transactions)-1; i < j; i, j = i+1, j-1 {
+		transactions[i], transactions[j] = transactions[j], transactions[i]
+	}
+	return transactions, nil
+}
+
+// mapPlaidTransaction maps a Plaid investment transaction to the transaction it records.
+// Activity that isn't tracked, such as transfers, cancellations and dividends of unknown
+// securities, is returned without a type.
+func mapPlaidTransaction(tx plaidInvestmentTransaction, tickers map[string]string) (BrokerTransaction, error) {
+	date, err := time.Parse("2006-01-02", tx.Date)
+	if err != nil {
+		return BrokerTransaction{}, fmt.Errorf("invalid date %q of transaction %s", tx.Date, tx.ID)
+	}
+
+	currency := plaidCurrency(tx.Currency)
+	symbol := ""
+	if tx.SecurityID != nil {
+		symbol = tickers[*tx.SecurityID]
+	}
+	mapped := BrokerTransaction{
+		ExternalID:  tx.ID,
+		Date:        date,
+		Symbol:      symbol,
+		Currency:    currency,
+		Description: tx.Name,
+		Commission:  decimal.Zero,
+	}
+	if tx.Fees != nil {
+		mapped.Commission = tx.Fees.Abs()
+	}
+
+	amount := tx.Amount.Abs()
+	switch {
+	case (tx.Type == "buy" || tx.Type == "sell") && symbol != "" && !tx.Quantity.IsZero() && tx.Price.IsPositive():
+		mapped.Type = models.TransactionTypeBuy
+		if tx.Type == "sell" {
+			mapped.Type = models.TransactionTypeSell
+		}
+		price := tx.Price
+		mapped.Quantity = tx.Quantity.Abs()
+		mapped.Price = &price
+	case tx.Type == "cash" && plaidDividendSubtypes[tx.Subtype] && symbol != "":
+		mapped.Type = models.TransactionTypeDividend
+		mapped.Quantity = amount
+	case tx.Type == "cash" && tx.Subtype == "interest":
+		mapped.Type = models.TransactionTypeInterest
+		mapped.Quantity = amount
+	case tx.Type == "cash" && (tx.Subtype == "deposit" || tx.Subtype == "contribution"):
+		mapped.Type = models.TransactionTypeDeposit
+		mapped.Symbol = currency
+		mapped.Quantity = amount
+	case tx.Type == "cash" && (tx.Subtype == "withdrawal" || tx.Subtype == "distribution"):
+		mapped.Type = models.TransactionTypeWithdrawal
+		mapped.Symbol = currency
+		mapped.Quantity = amount
+	case tx.Type == "fee":
+		mapped.Type = models.TransactionTypeManagementFee
+		mapped.Quantity = amount
+		mapped.Commission = decimal.Zero
+	}
+
+	// Interest and fees not tied to a security are recorded against the account's currency
+	if mapped.Type != "" && mapped.Symbol == "" {
+		mapped.Symbol = currency
+	}
+	if mapped.Type != "" && !mapped.Quantity.IsPositive() {
+		mapped.Type = ""
+	}
+	return mapped, nil
+}
+
+// plaidTickers maps the IDs of securities with a ticker to their symbols. Cash positions
+// are left out.
+func plaidTickers(securities []plaidSecurity) map[string]string {
+	tickers := make(map[string]string, len(securities))
+	for _, security := range securities {
+		if security.Ticker == nil || security.Type == "cash" {
+			continue
+		}
+		if ticker := strings.ToUpper(strings.TrimSpace(*security.Ticker)); ticker != "" {
+			tickers[security.ID] = ticker
+		}
+	}
+	return tickers
+}
+
+// plaidCurrency returns the ISO currency of an amount, USD when Plaid reports none
+func plaidCurrency(currency *string) string {
+	if currency == nil || *currency == "" {
+		return "USD"
+	}
+	return strings.ToUpper(*currency)
+}
+
+// post sends a request to the Plaid API, authenticated with the client ID and secret, and
+// decodes the response into out
+func (p *PlaidProvider) post(ctx context.Context, path string, request map[string]any, out any) error {
+	request["client_id"] = p.clientID
+	request["secret"] = p.secret
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code    string `json:"error_code"`
+			Message string `json:"error_message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Code != "" {
+			return fmt.Errorf("Plaid returned %s: %s", apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
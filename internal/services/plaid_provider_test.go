@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// newTestPlaidProvider returns a provider whose requests go to server
+func newTestPlaidProvider(server *httptest.Server) *PlaidProvider {
+	provider := NewPlaidProvider("client-id", "secret", "sandbox")
+	provider.httpClient = &http.Client{Transport: &mockTransport{server: server}}
+	return provider
+}
+
+func TestNewPlaidProvider(t *testing.T) {
+	assert.Equal(t, plaidSandboxURL, NewPlaidProvider("id", "secret", "sandbox").baseURL)
+	assert.Equal(t, plaidProductionURL, NewPlaidProvider("id", "secret", "production").baseURL)
+	assert.Equal(t, models.BrokerProviderPlaid, NewPlaidProvider("id", "secret", "").Name())
+}
+
+func TestPlaidProvider_GetPositions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/investments/holdings/get", r.URL.Path)
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "client-id", body["client_id"])
+		assert.Equal(t, "secret", body["secret"])
+		assert.Equal(t, "access-token", body["access_token"])
+
+		_, _ = w.Write([]byte(`{
+			"holdings": [
+				{"account_id": "acc-1", "security_id": "sec-aapl", "quantity": 10, "cost_basis": 1500, "iso_currency_code": "USD"},
+				{"account_id": "acc-1", "security_id": "sec-cash", "quantity": 250, "iso_currency_code": "USD"},
+				{"account_id": "acc-2", "security_id": "sec-aapl", "quantity": 5}
+			],
+			"securities": [
+				{"security_id": "sec-aapl", "ticker_symbol": "aapl", "type": "equity"},
+				{"security_id": "sec-cash", "ticker_symbol": "CUR:USD", "type": "cash"}
+			]
+		}`))
+	}))
+	defer server.Close()
+
+	positions, err := newTestPlaidProvider(server).GetPositions(context.Background(), "access-token", "acc-1")
+	require.NoError(t, err)
+	require.Len(t, positions, 1)
+	assert.Equal(t, "AAPL", positions[0].Symbol)
+	assert.True(t, positions[0].Quantity.Equal(decimal.NewFromInt(10)))
+	require.NotNil(t, positions[0].CostBasis)
+	assert.True(t, positions[0].CostBasis.Equal(decimal.NewFromInt(1500)))
+	assert.Equal(t, "USD", positions[0].Currency)
+}
+
+func TestPlaidProvider_GetTransactions(t *testing.T) {
+	pages := []string{
+		`{"total_investment_transactions": 3,
+		  "securities": [{"security_id": "sec-aapl", "ticker_symbol": "AAPL", "type": "equity"}],
+		  "investment_transactions": [
+			{"investment_transaction_id": "t3", "account_id": "acc-1", "security_id": "sec-aapl", "date": "2024-03-15", "type": "cash", "subtype": "qualified dividend", "amount": -12.5, "quantity": 0, "price": 0},
+			{"investment_transaction_id": "t2", "account_id": "acc-1", "security_id": "sec-aapl", "date": "2024-02-10", "type": "sell", "subtype": "sell", "amount": -800, "quantity": -4, "price": 200, "fees": 1}
+		  ]}`,
+		`{"total_investment_transactions": 3,
+		  "securities": [{"security_id": "sec-aapl", "ticker_symbol": "AAPL", "type": "equity"}],
+		  "investment_transactions": [
+			{"investment_transaction_id": "t1", "account_id": "acc-1", "security_id": "sec-aapl", "date": "2024-01-05", "type": "buy", "subtype": "buy", "amount": 1500, "quantity": 10, "price": 150, "fees": 0}
+		  ]}`,
+	}
+	var offsets []float64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/investments/transactions/get", r.URL.Path)
+		var body struct {
+			StartDate string         `json:"start_date"`
+			EndDate   string         `json:"end_date"`
+			Options   map[string]any `json:"options"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "2024-01-01", body.StartDate)
+		assert.Equal(t, "2024-03-31", body.EndDate)
+		offset := body.Options["offset"].(float64)
+		offsets = append(offsets, offset)
+		_, _ = w.Write([]byte(pages[len(offsets)-1]))
+	}))
+	defer server.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	transactions, err := newTestPlaidProvider(server).GetTransactions(context.Background(), "access-token", "acc-1", start, end)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0, 2}, offsets)
+	require.Len(t, transactions, 3)
+
+	buy := transactions[0]
+	assert.Equal(t, "t1", buy.ExternalID)
+	assert.Equal(t, models.TransactionTypeBuy, buy.Type)
+	assert.True(t, buy.Quantity.Equal(decimal.NewFromInt(10)))
+	require.NotNil(t, buy.Price)
+	assert.True(t, buy.Price.Equal(decimal.NewFromInt(150)))
+
+	sell := transactions[1]
+	assert.Equal(t, models.TransactionTypeSell, sell.Type)
+	assert.True(t, sell.Quantity.Equal(decimal.NewFromInt(4)))
+	assert.True(t, sell.Commission.Equal(decimal.NewFromInt(1)))
+
+	dividend := transactions[2]
+	assert.Equal(t, models.TransactionTypeDividend, dividend.Type)
+	assert.Equal(t, "AAPL", dividend.Symbol)
+	assert.True(t, dividend.Quantity.Equal(decimal.RequireFromString("12.5")))
+	assert.Nil(t, dividend.Price)
+}
+
+func TestMapPlaidTransaction(t *testing.T) {
+	tickers := map[string]string{"sec-aapl": "AAPL"}
+	usd := "USD"
+
+	tests := []struct {
+		name       string
+		tx         plaidInvestmentTransaction
+		wantType   models.TransactionType
+		wantSymbol string
+	}{
+		{"interest", plaidInvestmentTransaction{Type: "cash", Subtype: "interest", Amount: decimal.NewFromInt(-3)}, models.TransactionTypeInterest, "USD"},
+		{"deposit", plaidInvestmentTransaction{Type: "cash", Subtype: "deposit", Amount: decimal.NewFromInt(-1000), Currency: &usd}, models.TransactionTypeDeposit, "USD"},
+		{"withdrawal", plaidInvestmentTransaction{Type: "cash", Subtype: "withdrawal", Amount: decimal.NewFromInt(500)}, models.TransactionTypeWithdrawal, "USD"},
+		{"fee", plaidInvestmentTransaction{Type: "fee", Subtype: "account fee", Amount: decimal.NewFromInt(25)}, models.TransactionTypeManagementFee, "USD"},
+		{"transfer", plaidInvestmentTransaction{Type: "transfer", Subtype: "transfer", Amount: decimal.NewFromInt(100)}, "", ""},
+		{"dividend of an unknown security", plaidInvestmentTransaction{Type: "cash", Subtype: "dividend", Amount: decimal.NewFromInt(-5)}, "", ""},
+		{"buy without a price", plaidInvestmentTransaction{Type: "buy", Subtype: "buy", Quantity: decimal.NewFromInt(1)}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.tx.Date = "2024-01-02"
+			tx, err := mapPlaidTransaction(tt.tx, tickers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, tx.Type)
+			if tt.wantType != "" {
+				assert.Equal(t, tt.wantSymbol, tx.Symbol)
+				assert.True(t, tx.Quantity.IsPositive())
+			}
+		})
+	}
+
+	_, err := mapPlaidTransaction(plaidInvestmentTransaction{ID: "t1", Date: "02/01/2024"}, tickers)
+	assert.Error(t, err)
+}
+
+func TestPlaidProvider_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error_type": "ITEM_ERROR", "error_code": "ITEM_LOGIN_REQUIRED", "error_message": "the login details of this item have changed"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestPlaidProvider(server).GetPositions(context.Background(), "access-token", "acc-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ITEM_LOGIN_REQUIRED")
+}
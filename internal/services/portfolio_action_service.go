@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// spinoffPriceWindow is how long after a spinoff's distribution date closing prices are
+// looked for to allocate its cost basis by fair market value
+const spinoffPriceWindow = 7 * 24 * time.Hour
+
+// PortfolioActionService defines the interface for reviewing corporate actions suggested to a portfolio
+type PortfolioActionService interface {
+	ApproveAction(ctx context.Context, actionID, portfolioID, userID string, req *dto.ApproveActionRequest) (*models.PortfolioAction, error)
+}
+
+// portfolioActionService implements PortfolioActionService interface
+type portfolioActionService struct {
+	db         *gorm.DB
+	rounding   models.RoundingPolicy
+	marketData MarketDataService
+}
+
+// NewPortfolioActionService creates a new PortfolioActionService instance. It works on the
+// database directly so approval and application can share one database transaction.
+func NewPortfolioActionService(db *gorm.DB) PortfolioActionService {
+	return NewPortfolioActionServiceWithRounding(db, models.DefaultRoundingPolicy())
+}
+
+// NewPortfolioActionServiceWithRounding creates a new PortfolioActionService instance that
+// applies corporate actions with the given rounding policy
+func NewPortfolioActionServiceWithRounding(db *gorm.DB, rounding models.RoundingPolicy) PortfolioActionService {
+	return NewPortfolioActionServiceWithMarketData(db, rounding, nil)
+}
+
+// NewPortfolioActionServiceWithMarketData creates a new PortfolioActionService instance that
+// can also allocate spinoff cost basis by fair market value, using closing prices from
+// marketData. A nil marketData leaves fair market value allocations unavailable.
+func NewPortfolioActionServiceWithMarketData(db *gorm.DB, rounding models.RoundingPolicy, marketData MarketDataService) PortfolioActionService {
+	return &portfolioActionService{db: db, rounding: rounding, marketData: marketData}
+}
+
+// ApproveAction approves a pending portfolio action and applies its corporate action to the
+// portfolio's holdings, tax lots, and transactions. Approval, application, and marking the
+// corporate action applied happen in one database transaction, so a failure leaves the
+// action pending and the portfolio untouched.
+func (s *portfolioActionService) ApproveAction(ctx context.Context, actionID, portfolioID, userID string, req *dto.ApproveActionRequest) (*models.PortfolioAction, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	method, err := spinoffAllocationMethod(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var approved *models.PortfolioAction
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		portfolioRepo := repository.NewPortfolioRepository(tx)
+		portfolioActionRepo := repository.NewPortfolioActionRepository(tx)
+		holdingRepo := repository.NewHoldingRepository(tx)
+		corporateActionService := NewCorporateActionServiceWithDB(tx, s.rounding)
+
+		portfolio, err := portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
+
+		action, err := portfolioActionRepo.FindByID(ctx, actionID)
+		if err != nil {
+			return models.ErrPortfolioActionNotFound
+		}
+		if action.PortfolioID != portfolio.ID {
+			return models.ErrUnauthorizedAccess
+		}
+		if !action.IsPending() {
+			return models.ErrPortfolioActionNotPending
+		}
+		if action.CorporateAction == nil {
+			return models.ErrCorporateActionNotFound
+		}
+
+		allocation := models.DefaultSpinoffAllocation()
+		if action.CorporateAction.Type == models.CorporateActionTypeSpinoff {
+			if allocation, err = s.spinoffAllocation(ctx, method, req, action.CorporateAction); err != nil {
+				return err
+			}
+		}
+
+		if err := applyCorporateAction(ctx, corporateActionService, holdingRepo, action, portfolioID, userID, allocation); err != nil {
+			return fmt.Errorf("%w: %v", models.ErrCorporateActionApplyFailed, err)
+		}
+
+		action.Approve(uid)
+		action.MarkApplied()
+		if req.Notes != "" {
+			action.Notes = req.Notes
+		}
+		if err := portfolioActionRepo.Update(ctx, action); err != nil {
+			return fmt.Errorf("failed to update portfolio action: %w", err)
+		}
+
+		// Stop the monitor from suggesting the action again. Actions already suggested to
+		// other portfolios stay pending and can still be approved.
+		if !action.CorporateAction.Applied {
+			if err := corporateActionService.MarkAsApplied(ctx, action.CorporateActionID.String()); err != nil {
+				return fmt.Errorf("failed to mark corporate action applied: %w", err)
+			}
+			action.CorporateAction.Applied = true
+		}
+
+		approved = action
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return approved, nil
+}
+
+// spinoffAllocationMethod returns the method a request asks spinoff cost basis to be allocated
+// by. Without one, a given allocation is explicit and otherwise the default is used. Only the
+// explicit method takes an allocation, and it requires one.
+func spinoffAllocationMethod(req *dto.ApproveActionRequest) (models.SpinoffAllocationMethod, error) {
+	method := req.SpinoffAllocationMethod
+	if method == "" {
+		method = models.SpinoffAllocationDefault
+		if req.SpinoffCostBasisAllocation != nil {
+			method = models.SpinoffAllocationExplicit
+		}
+	}
+
+	if (method == models.SpinoffAllocationExplicit) != (req.SpinoffCostBasisAllocation != nil) {
+		return "", models.ErrInvalidSpinoffAllocationMethod
+	}
+	if method == models.SpinoffAllocationExplicit {
+		if err := models.ValidateCostBasisAllocation(*req.SpinoffCostBasisAllocation); err != nil {
+			return "", err
+		}
+	}
+	return method, nil
+}
+
+// spinoffAllocation resolves the allocation of a spinoff's cost basis by method
+func (s *portfolioActionService) spinoffAllocation(
+	ctx context.Context,
+	method models.SpinoffAllocationMethod,
+	req *dto.ApproveActionRequest,
+	corporateAction *models.CorporateAction,
+) (models.SpinoffAllocation, error) {
+	switch method {
+	case models.SpinoffAllocationExplicit:
+		return models.ExplicitSpinoffAllocation(*req.SpinoffCostBasisAllocation), nil
+	case models.SpinoffAllocationFairMarketValue:
+		if corporateAction.Ratio == nil || corporateAction.NewSymbol == nil {
+			return models.SpinoffAllocation{}, fmt.Errorf("%w: %v", models.ErrCorporateActionApplyFailed, models.ErrInvalidValue)
+		}
+		return s.fairMarketValueAllocation(ctx, corporateAction.Symbol, *corporateAction.NewSymbol, *corporateAction.Ratio, corporateAction.Date)
+	default:
+		return models.DefaultSpinoffAllocation(), nil
+	}
+}
+
+// fairMarketValueAllocation allocates a spinoff's cost basis by the closing prices of the
+// parent and spinoff on the distribution date. A spinoff often only starts regular-way trading
+// after it is distributed, so when either has no close that day the first later day both closed
+// within spinoffPriceWindow is used.
+func (s *portfolioActionService) fairMarketValueAllocation(
+	ctx context.Context,
+	parentSymbol, spinoffSymbol string,
+	ratio decimal.Decimal,
+	date time.Time,
+) (models.SpinoffAllocation, error) {
+	if s.marketData == nil {
+		return models.SpinoffAllocation{}, fmt.Errorf("%w: market data is not configured", models.ErrFairMarketValueUnavailable)
+	}
+
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.Add(spinoffPriceWindow)
+	parentCloses, err := s.closesByDay(ctx, parentSymbol, start, end)
+	if err != nil {
+		return models.SpinoffAllocation{}, err
+	}
+	spinoffCloses, err := s.closesByDay(ctx, spinoffSymbol, start, end)
+	if err != nil {
+		return models.SpinoffAllocation{}, err
+	}
+
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		parentPrice, parentOK := parentCloses[key]
+		spinoffPrice, spinoffOK := spinoffCloses[key]
+		if parentOK && spinoffOK {
+			return models.FairMarketValueSpinoffAllocation(ratio, parentPrice, spinoffPrice)
+		}
+	}
+	return models.SpinoffAllocation{}, fmt.Errorf("%w: %s and %s have no closes on the same day within %d days of %s",
+		models.ErrFairMarketValueUnavailable, parentSymbol, spinoffSymbol, int(spinoffPriceWindow.Hours()/24), start.Format("2006-01-02"))
+}
+
+// closesByDay returns a symbol's closing prices between start and end, keyed by day
+func (s *portfolioActionService) closesByDay(ctx context.Context, symbol string, start, end time.Time) (map[string]decimal.Decimal, error) {
+	prices, err := s.marketData.GetHistoricalPrices(ctx, symbol, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to get %s prices: %v", models.ErrFairMarketValueUnavailable, symbol, err)
+	}
+	closes := make(map[string]decimal.Decimal, len(prices))
+	for _, price := range prices {
+		if price.Close.IsPositive() {
+			closes[price.Date.UTC().Format("2006-01-02")] = price.Close
+		}
+	}
+	return closes, nil
+}
+
+// applyCorporateAction calls the CorporateActionService method matching the action's type.
+// The allocation is the share of cost basis a spinoff moves from the parent.
+func applyCorporateAction(
+	ctx context.Context,
+	corporateActionService CorporateActionService,
+	holdingRepo repository.HoldingRepository,
+	action *models.PortfolioAction,
+	portfolioID, userID string,
+	allocation models.SpinoffAllocation,
+) error {
+	corporateAction := action.CorporateAction
+	symbol := action.AffectedSymbol
+	date := corporateAction.Date
+
+	switch corporateAction.Type {
+	case models.CorporateActionTypeSplit:
+		if corporateAction.Ratio == nil {
+			return models.ErrInvalidValue
+		}
+		return corporateActionService.ApplyStockSplit(ctx, portfolioID, symbol, userID, *corporateAction.Ratio, date)
+
+	case models.CorporateActionTypeDividend:
+		if corporateAction.Amount == nil {
+			return models.ErrInvalidValue
+		}
+		// Corporate action amounts are per share; ApplyDividend records the total received
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
+		}
+		total := corporateAction.Amount.Mul(holding.Quantity).Round(2)
+		if total.LessThanOrEqual(decimal.Zero) {
+			return models.ErrInvalidValue
+		}
+		return corporateActionService.ApplyDividend(ctx, portfolioID, symbol, userID, total, date)
+
+	case models.CorporateActionTypeStockDividend:
+		if corporateAction.Ratio == nil {
+			return models.ErrInvalidValue
+		}
+		return corporateActionService.ApplyStockDividend(ctx, portfolioID, symbol, userID, *corporateAction.Ratio, date)
+
+	case models.CorporateActionTypeReturnOfCapital:
+		if corporateAction.Amount == nil {
+			return models.ErrInvalidValue
+		}
+		// Like dividends, return of capital amounts are per share
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
+		}
+		total := corporateAction.Amount.Mul(holding.Quantity).Round(2)
+		if total.LessThanOrEqual(decimal.Zero) {
+			return models.ErrInvalidValue
+		}
+		return corporateActionService.ApplyReturnOfCapital(ctx, portfolioID, symbol, userID, total, date)
+
+	case models.CorporateActionTypeCapitalGainDistribution:
+		if corporateAction.Amount == nil {
+			return models.ErrInvalidValue
+		}
+		// Like dividends, distribution amounts are per share
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
+		if err != nil {
+			return fmt.Errorf("no holding found for symbol %s: %w", symbol, err)
+		}
+		total := corporateAction.Amount.Mul(holding.Quantity).Round(2)
+		if total.LessThanOrEqual(decimal.Zero) {
+			return models.ErrInvalidValue
+		}
+		return corporateActionService.ApplyCapitalGainDistribution(ctx, portfolioID, symbol, userID, total, date)
+
+	case models.CorporateActionTypeMerger:
+		if corporateAction.Ratio == nil || corporateAction.NewSymbol == nil {
+			return models.ErrInvalidValue
+		}
+		return corporateActionService.ApplyMerger(ctx, portfolioID, symbol, *corporateAction.NewSymbol, userID, *corporateAction.Ratio, date)
+
+	case models.CorporateActionTypeSpinoff:
+		if corporateAction.Ratio == nil || corporateAction.NewSymbol == nil {
+			return models.ErrInvalidValue
+		}
+		return corporateActionService.ApplySpinoff(ctx, portfolioID, symbol, *corporateAction.NewSymbol, userID, *corporateAction.Ratio, allocation, date)
+
+	case models.CorporateActionTypeTickerChange:
+		if corporateAction.NewSymbol == nil {
+			return models.ErrInvalidValue
+		}
+		return corporateActionService.ApplyTickerChange(ctx, portfolioID, symbol, *corporateAction.NewSymbol, userID, date)
+
+	default:
+		return models.ErrInvalidCorporateActionType
+	}
+}
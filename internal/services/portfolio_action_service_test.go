@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupPortfolioActionServiceTest(t *testing.T) (*gorm.DB, PortfolioActionService, *models.Portfolio, *models.Holding) {
+	db := setupMonitorTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.TaxLot{}, &models.Transaction{}))
+
+	portfolio, holding, _ := createMonitorTestData(t, db)
+	return db, NewPortfolioActionService(db), portfolio, holding
+}
+
+func createPendingPortfolioAction(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, corporateAction *models.CorporateAction) *models.PortfolioAction {
+	action := &models.PortfolioAction{
+		PortfolioID:       portfolio.ID,
+		CorporateActionID: corporateAction.ID,
+		AffectedSymbol:    corporateAction.Symbol,
+		SharesAffected:    100,
+	}
+	require.NoError(t, db.Create(action).Error)
+	return action
+}
+
+func TestPortfolioActionService_ApproveAction_Split(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio, holding := setupPortfolioActionServiceTest(t)
+
+	var split models.CorporateAction
+	require.NoError(t, db.Where("symbol = ? AND type = ?", "AAPL", models.CorporateActionTypeSplit).First(&split).Error)
+	action := createPendingPortfolioAction(t, db, portfolio, &split)
+
+	approved, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(), &dto.ApproveActionRequest{Notes: "ok"})
+	require.NoError(t, err)
+	assert.Equal(t, models.PortfolioActionStatusApplied, approved.Status)
+	assert.NotNil(t, approved.ReviewedAt)
+	assert.NotNil(t, approved.AppliedAt)
+	assert.Equal(t, "ok", approved.Notes)
+
+	var updatedHolding models.Holding
+	require.NoError(t, db.First(&updatedHolding, "id = ?", holding.ID).Error)
+	assert.True(t, updatedHolding.Quantity.Equal(decimal.NewFromInt(200)))
+	assert.True(t, updatedHolding.CostBasis.Equal(decimal.NewFromInt(10000)))
+
+	var updatedSplit models.CorporateAction
+	require.NoError(t, db.First(&updatedSplit, "id = ?", split.ID).Error)
+	assert.True(t, updatedSplit.Applied)
+}
+
+func TestPortfolioActionService_ApproveAction_DividendUsesHoldingQuantity(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio, _ := setupPortfolioActionServiceTest(t)
+
+	amount := decimal.NewFromFloat(0.25)
+	dividend := &models.CorporateAction{
+		Symbol: "AAPL",
+		Type:   models.CorporateActionTypeDividend,
+		Date:   time.Now().UTC(),
+		Amount: &amount,
+	}
+	require.NoError(t, db.Create(dividend).Error)
+	action := createPendingPortfolioAction(t, db, portfolio, dividend)
+
+	_, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(), &dto.ApproveActionRequest{})
+	require.NoError(t, err)
+
+	var transaction models.Transaction
+	require.NoError(t, db.Where("portfolio_id = ? AND type = ?", portfolio.ID, models.TransactionTypeDividend).First(&transaction).Error)
+	// 100 shares at 0.25 per share
+	assert.True(t, transaction.Quantity.Equal(decimal.NewFromInt(25)), "got %s", transaction.Quantity)
+}
+
+func TestPortfolioActionService_ApproveAction_StockDividend(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio, holding := setupPortfolioActionServiceTest(t)
+
+	rate := decimal.RequireFromString("0.05")
+	stockDividend := &models.CorporateAction{
+		Symbol: "AAPL",
+		Type:   models.CorporateActionTypeStockDividend,
+		Date:   time.Now().UTC(),
+		Ratio:  &rate,
+	}
+	require.NoError(t, db.Create(stockDividend).Error)
+	action := createPendingPortfolioAction(t, db, portfolio, stockDividend)
+
+	_, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(), &dto.ApproveActionRequest{})
+	require.NoError(t, err)
+
+	// 5 new shares per 100, with the cost basis spread over all 105
+	var updatedHolding models.Holding
+	require.NoError(t, db.First(&updatedHolding, "id = ?", holding.ID).Error)
+	assert.True(t, updatedHolding.Quantity.Equal(decimal.NewFromInt(105)), "got %s", updatedHolding.Quantity)
+	assert.True(t, updatedHolding.CostBasis.Equal(decimal.NewFromInt(10000)))
+
+	var transaction models.Transaction
+	require.NoError(t, db.Where("portfolio_id = ? AND type = ?", portfolio.ID, models.TransactionTypeStockDividend).First(&transaction).Error)
+	assert.True(t, transaction.Quantity.Equal(decimal.NewFromInt(5)), "got %s", transaction.Quantity)
+}
+
+func TestPortfolioActionService_ApproveAction_SpinoffAllocation(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio, holding := setupPortfolioActionServiceTest(t)
+
+	ratio := decimal.NewFromFloat(0.5)
+	newSymbol := "SPIN"
+	spinoff := &models.CorporateAction{
+		Symbol:    "AAPL",
+		Type:      models.CorporateActionTypeSpinoff,
+		Date:      time.Now().UTC(),
+		Ratio:     &ratio,
+		NewSymbol: &newSymbol,
+	}
+	require.NoError(t, db.Create(spinoff).Error)
+	action := createPendingPortfolioAction(t, db, portfolio, spinoff)
+
+	invalid := decimal.NewFromInt(1)
+	_, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(),
+		&dto.ApproveActionRequest{SpinoffCostBasisAllocation: &invalid})
+	assert.ErrorIs(t, err, models.ErrInvalidCostBasisAllocation)
+
+	allocation := decimal.RequireFromString("0.4")
+	_, err = service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(),
+		&dto.ApproveActionRequest{SpinoffCostBasisAllocation: &allocation})
+	require.NoError(t, err)
+
+	var parent, spun models.Holding
+	require.NoError(t, db.First(&parent, "id = ?", holding.ID).Error)
+	require.NoError(t, db.First(&spun, "portfolio_id = ? AND symbol = ?", portfolio.ID, newSymbol).Error)
+	assert.True(t, spun.CostBasis.Equal(holding.CostBasis.Mul(allocation)), "got %s", spun.CostBasis)
+	assert.True(t, parent.CostBasis.Add(spun.CostBasis).Equal(holding.CostBasis))
+}
+
+func TestPortfolioActionService_ApproveAction_SpinoffFairMarketValue(t *testing.T) {
+	ctx := context.Background()
+
+	db, _, portfolio, holding := setupPortfolioActionServiceTest(t)
+
+	// Distributed on a Friday, with the spinoff first closing on Monday
+	distribution := time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC)
+	ratio := decimal.NewFromFloat(0.5)
+	newSymbol := "SPIN"
+	spinoff := &models.CorporateAction{
+		Symbol:    "AAPL",
+		Type:      models.CorporateActionTypeSpinoff,
+		Date:      distribution,
+		Ratio:     &ratio,
+		NewSymbol: &newSymbol,
+	}
+	require.NoError(t, db.Create(spinoff).Error)
+	action := createPendingPortfolioAction(t, db, portfolio, spinoff)
+	fairMarketValue := &dto.ApproveActionRequest{SpinoffAllocationMethod: models.SpinoffAllocationFairMarketValue}
+
+	t.Run("without market data", func(t *testing.T) {
+		service := NewPortfolioActionService(db)
+		_, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(), fairMarketValue)
+		assert.ErrorIs(t, err, models.ErrFairMarketValueUnavailable)
+	})
+
+	t.Run("without a common close", func(t *testing.T) {
+		marketData := new(MockMarketDataService)
+		marketData.On("GetHistoricalPrices", "AAPL", mock.Anything, mock.Anything).Return([]*HistoricalPrice{
+			{Date: distribution, Close: decimal.NewFromInt(90)},
+		}, nil)
+		marketData.On("GetHistoricalPrices", "SPIN", mock.Anything, mock.Anything).Return([]*HistoricalPrice{}, nil)
+
+		service := NewPortfolioActionServiceWithMarketData(db, models.DefaultRoundingPolicy(), marketData)
+		_, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(), fairMarketValue)
+		assert.ErrorIs(t, err, models.ErrFairMarketValueUnavailable)
+
+		var unchanged models.PortfolioAction
+		require.NoError(t, db.First(&unchanged, "id = ?", action.ID).Error)
+		assert.Equal(t, models.PortfolioActionStatusPending, unchanged.Status)
+	})
+
+	t.Run("first common close", func(t *testing.T) {
+		monday := distribution.AddDate(0, 0, 3)
+		marketData := new(MockMarketDataService)
+		marketData.On("GetHistoricalPrices", "AAPL", mock.Anything, mock.Anything).Return([]*HistoricalPrice{
+			{Date: distribution, Close: decimal.NewFromInt(95)},
+			{Date: monday, Close: decimal.NewFromInt(90)},
+		}, nil)
+		marketData.On("GetHistoricalPrices", "SPIN", mock.Anything, mock.Anything).Return([]*HistoricalPrice{
+			{Date: monday, Close: decimal.NewFromInt(20)},
+		}, nil)
+
+		service := NewPortfolioActionServiceWithMarketData(db, models.DefaultRoundingPolicy(), marketData)
+		_, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(), fairMarketValue)
+		require.NoError(t, err)
+
+		// Each parent share became a $90 share and half a $20 spinoff share, so the spinoff
+		// is worth 10 of 100
+		var spun models.Holding
+		require.NoError(t, db.First(&spun, "portfolio_id = ? AND symbol = ?", portfolio.ID, newSymbol).Error)
+		assert.True(t, spun.CostBasis.Equal(holding.CostBasis.Mul(decimal.RequireFromString("0.1"))), "got %s", spun.CostBasis)
+
+		var transaction models.Transaction
+		require.NoError(t, db.Where("portfolio_id = ? AND type = ?", portfolio.ID, models.TransactionTypeSpinoff).First(&transaction).Error)
+		assert.Contains(t, transaction.Notes, "allocating 0.1 of cost basis (fair market value, AAPL closed at 90 and SPIN at 20)")
+		marketData.AssertExpectations(t)
+	})
+}
+
+func TestPortfolioActionService_ApproveAction_SpinoffAllocationMethod(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio, _ := setupPortfolioActionServiceTest(t)
+
+	var split models.CorporateAction
+	require.NoError(t, db.Where("symbol = ?", "AAPL").First(&split).Error)
+	action := createPendingPortfolioAction(t, db, portfolio, &split)
+	allocation := decimal.RequireFromString("0.2")
+
+	tests := []struct {
+		name string
+		req  *dto.ApproveActionRequest
+	}{
+		{"explicit without an allocation", &dto.ApproveActionRequest{SpinoffAllocationMethod: models.SpinoffAllocationExplicit}},
+		{"fair market value with an allocation", &dto.ApproveActionRequest{SpinoffAllocationMethod: models.SpinoffAllocationFairMarketValue, SpinoffCostBasisAllocation: &allocation}},
+		{"default with an allocation", &dto.ApproveActionRequest{SpinoffAllocationMethod: models.SpinoffAllocationDefault, SpinoffCostBasisAllocation: &allocation}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(), tt.req)
+			assert.ErrorIs(t, err, models.ErrInvalidSpinoffAllocationMethod)
+		})
+	}
+}
+
+func TestPortfolioActionService_ApproveAction_ApplyFailureRollsBack(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio, _ := setupPortfolioActionServiceTest(t)
+
+	ratio := decimal.NewFromInt(3)
+	split := &models.CorporateAction{
+		Symbol: "MSFT",
+		Type:   models.CorporateActionTypeSplit,
+		Date:   time.Now().UTC(),
+		Ratio:  &ratio,
+	}
+	require.NoError(t, db.Create(split).Error)
+	action := createPendingPortfolioAction(t, db, portfolio, split)
+
+	_, err := service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), portfolio.UserID.String(), &dto.ApproveActionRequest{})
+	assert.ErrorIs(t, err, models.ErrCorporateActionApplyFailed)
+
+	var unchanged models.PortfolioAction
+	require.NoError(t, db.First(&unchanged, "id = ?", action.ID).Error)
+	assert.Equal(t, models.PortfolioActionStatusPending, unchanged.Status)
+	assert.Nil(t, unchanged.ReviewedAt)
+
+	var unchangedSplit models.CorporateAction
+	require.NoError(t, db.First(&unchangedSplit, "id = ?", split.ID).Error)
+	assert.False(t, unchangedSplit.Applied)
+}
+
+func TestPortfolioActionService_ApproveAction_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio, _ := setupPortfolioActionServiceTest(t)
+
+	var split models.CorporateAction
+	require.NoError(t, db.Where("symbol = ?", "AAPL").First(&split).Error)
+	action := createPendingPortfolioAction(t, db, portfolio, &split)
+	userID := portfolio.UserID.String()
+
+	_, err := service.ApproveAction(ctx, action.ID.String(), uuid.New().String(), userID, &dto.ApproveActionRequest{})
+	assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+
+	_, err = service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), uuid.New().String(), &dto.ApproveActionRequest{})
+	assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+
+	_, err = service.ApproveAction(ctx, uuid.New().String(), portfolio.ID.String(), userID, &dto.ApproveActionRequest{})
+	assert.ErrorIs(t, err, models.ErrPortfolioActionNotFound)
+
+	_, err = service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), userID, &dto.ApproveActionRequest{})
+	require.NoError(t, err)
+
+	_, err = service.ApproveAction(ctx, action.ID.String(), portfolio.ID.String(), userID, &dto.ApproveActionRequest{})
+	assert.ErrorIs(t, err, models.ErrPortfolioActionNotPending)
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type aliases for dto types for backward compatibility
+type PortfolioGroupOverview = dto.PortfolioGroupOverview
+
+// DefaultBenchmarkSymbol is the benchmark index returns are compared to when none is requested
+const DefaultBenchmarkSymbol = "SPY"
+
+// PortfolioGroupService defines the interface for organizing portfolios into groups and
+// rolling up their valuation and performance
+type PortfolioGroupService interface {
+	Create(ctx context.Context, userID string, req *dto.CreatePortfolioGroupRequest) (*models.PortfolioGroup, error)
+	List(ctx context.Context, userID string) ([]*models.PortfolioGroup, error)
+	Get(ctx context.Context, id, userID string) (*models.PortfolioGroup, error)
+	Update(ctx context.Context, id, userID string, req *dto.UpdatePortfolioGroupRequest) (*models.PortfolioGroup, error)
+	Delete(ctx context.Context, id, userID string) error
+	GetOverview(ctx context.Context, id, userID, currency string, startDate, endDate time.Time) (*PortfolioGroupOverview, error)
+	CompareToBenchmark(ctx context.Context, id, userID, currency, benchmarkSymbol string, startDate, endDate time.Time) (*BenchmarkComparisonResult, error)
+}
+
+// portfolioGroupService implements PortfolioGroupService interface
+type portfolioGroupService struct {
+	groupRepo          repository.PortfolioGroupRepository
+	portfolioRepo      repository.PortfolioRepository
+	aggregationService AggregationService
+	analyticsService   PerformanceAnalyticsService
+}
+
+// NewPortfolioGroupService creates a new PortfolioGroupService instance. analyticsService
+// compares groups to benchmarks and may be nil when market data is disabled.
+func NewPortfolioGroupService(
+	groupRepo repository.PortfolioGroupRepository,
+	portfolioRepo repository.PortfolioRepository,
+	aggregationService AggregationService,
+	analyticsService PerformanceAnalyticsService,
+) PortfolioGroupService {
+	return &portfolioGroupService{
+		groupRepo:          groupRepo,
+		portfolioRepo:      portfolioRepo,
+		aggregationService: aggregationService,
+		analyticsService:   analyticsService,
+	}
+}
+
+// Create creates a group of the user's portfolios, optionally nested under another group
+func (s *portfolioGroupService) Create(ctx context.Context, userID string, req *dto.CreatePortfolioGroupRequest) (*models.PortfolioGroup, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, models.ErrInvalidValue
+	}
+
+	portfolios, err := s.groupPortfolios(ctx, userID, req.PortfolioIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &models.PortfolioGroup{
+		ID:          uuid.New(),
+		UserID:      uid,
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Portfolios:  portfolios,
+	}
+	if err := group.Validate(); err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil && *req.ParentID != "" {
+		groups, err := s.groupRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if err := setParent(group, *req.ParentID, models.NewPortfolioGroupTree(groups)); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkName(ctx, group); err != nil {
+		return nil, err
+	}
+
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to create portfolio group: %w", err)
+	}
+	group.SubgroupIDs = []uuid.UUID{}
+
+	return group, nil
+}
+
+// List retrieves a user's portfolio groups by name, each with its subgroups
+func (s *portfolioGroupService) List(ctx context.Context, userID string) ([]*models.PortfolioGroup, error) {
+	groups, err := s.groupRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := models.NewPortfolioGroupTree(groups)
+	for _, group := range groups {
+		group.SubgroupIDs = subgroupIDs(tree, group.ID)
+	}
+	return groups, nil
+}
+
+// Get retrieves one of a user's portfolio groups with its subgroups
+func (s *portfolioGroupService) Get(ctx context.Context, id, userID string) (*models.PortfolioGroup, error) {
+	group, _, err := s.find(ctx, id, userID)
+	return group, err
+}
+
+// find retrieves one of a user's portfolio groups together with the hierarchy of all their
+// groups
+func (s *portfolioGroupService) find(ctx context.Context, id, userID string) (*models.PortfolioGroup, *models.PortfolioGroupTree, error) {
+	group, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, models.ErrPortfolioGroupNotFound
+	}
+	if group.UserID.String() != userID {
+		return nil, nil, models.ErrPortfolioGroupNotFound
+	}
+
+	groups, err := s.groupRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	tree := models.NewPortfolioGroupTree(groups)
+	group.SubgroupIDs = subgroupIDs(tree, group.ID)
+
+	return group, tree, nil
+}
+
+// Update renames, describes, moves or changes the portfolios of a group
+func (s *portfolioGroupService) Update(ctx context.Context, id, userID string, req *dto.UpdatePortfolioGroupRequest) (*models.PortfolioGroup, error) {
+	group, tree, err := s.find(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		group.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.PortfolioIDs != nil {
+		portfolios, err := s.groupPortfolios(ctx, userID, *req.PortfolioIDs)
+		if err != nil {
+			return nil, err
+		}
+		group.Portfolios = portfolios
+	}
+	if req.ParentID != nil {
+		if *req.ParentID == "" {
+			group.ParentID = nil
+		} else if err := setParent(group, *req.ParentID, tree); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := group.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkName(ctx, group); err != nil {
+		return nil, err
+	}
+
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, fmt.Errorf("failed to update portfolio group: %w", err)
+	}
+
+	return group, nil
+}
+
+// Delete removes one of a user's portfolio groups. Its portfolios are kept, and its subgroups
+// move up to its parent.
+func (s *portfolioGroupService) Delete(ctx context.Context, id, userID string) error {
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return err
+	}
+	return s.groupRepo.Delete(ctx, id)
+}
+
+// GetOverview rolls up the valuation and performance of a group's portfolios, including those
+// of its subgroups
+func (s *portfolioGroupService) GetOverview(
+	ctx context.Context,
+	id, userID, currency string,
+	startDate, endDate time.Time,
+) (*PortfolioGroupOverview, error) {
+	group, tree, err := s.find(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	overview, err := s.aggregationService.GetPortfoliosOverview(ctx, userID, currency, tree.RollUpPortfolioIDs(group.ID), startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PortfolioGroupOverview{
+		Group:    dto.ToPortfolioGroupResponse(group),
+		Overview: overview,
+	}, nil
+}
+
+// CompareToBenchmark compares the combined return of a group's portfolios, including those of
+// its subgroups, to a benchmark index over a period
+func (s *portfolioGroupService) CompareToBenchmark(
+	ctx context.Context,
+	id, userID, currency, benchmarkSymbol string,
+	startDate, endDate time.Time,
+) (*BenchmarkComparisonResult, error) {
+	if s.analyticsService == nil {
+		return nil, fmt.Errorf("performance analytics are not available")
+	}
+	if benchmarkSymbol == "" {
+		benchmarkSymbol = DefaultBenchmarkSymbol
+	}
+
+	overview, err := s.GetOverview(ctx, id, userID, currency, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	if overview.Performance == nil || overview.Performance.Portfolios == 0 {
+		return nil, models.ErrInsufficientGroupData
+	}
+
+	groupReturn := annualizedReturn(overview.Performance.StartingValue, overview.Performance.EndingValue, startDate, endDate)
+	return s.analyticsService.CompareReturnToBenchmark(ctx, groupReturn, benchmarkSymbol)
+}
+
+// setParent nests a group under another of the user's groups, found in tree
+func setParent(group *models.PortfolioGroup, parentID string, tree *models.PortfolioGroupTree) error {
+	pid, err := uuid.Parse(parentID)
+	if err != nil || !tree.Contains(pid) {
+		return models.ErrPortfolioGroupNotFound
+	}
+	if err := tree.CheckParent(group.ID, pid); err != nil {
+		return err
+	}
+
+	group.ParentID = &pid
+	return nil
+}
+
+// checkName returns an error if the user has another group with the group's name
+func (s *portfolioGroupService) checkName(ctx context.Context, group *models.PortfolioGroup) error {
+	exists, err := s.groupRepo.ExistsByUserIDAndName(ctx, group.UserID, group.Name, group.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return models.ErrPortfolioGroupDuplicateName
+	}
+	return nil
+}
+
+// groupPortfolios checks that the portfolios belong to the user and links them to a group
+func (s *portfolioGroupService) groupPortfolios(ctx context.Context, userID string, portfolioIDs []string) ([]*models.PortfolioGroupPortfolio, error) {
+	links := make([]*models.PortfolioGroupPortfolio, 0, len(portfolioIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, portfolioID := range portfolioIDs {
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return nil, models.ErrPortfolioNotFound
+		}
+		if portfolio.UserID.String() != userID {
+			return nil, models.ErrUnauthorizedAccess
+		}
+		if seen[portfolio.ID] {
+			continue
+		}
+		seen[portfolio.ID] = true
+		links = append(links, &models.PortfolioGroupPortfolio{PortfolioID: portfolio.ID})
+	}
+	return links, nil
+}
+
+// subgroupIDs returns the IDs of the groups directly under a group
+func subgroupIDs(tree *models.PortfolioGroupTree, id uuid.UUID) []uuid.UUID {
+	ids := []uuid.UUID{}
+	for _, subgroup := range tree.Subgroups(id) {
+		ids = append(ids, subgroup.ID)
+	}
+	return ids
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupPortfolioGroupTest(t *testing.T) (*gorm.DB, *models.User, PortfolioGroupService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Holding{},
+		&models.PortfolioGroup{}, &models.PortfolioGroupPortfolio{}))
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	aggregation := NewAggregationService(portfolioRepo, repository.NewHoldingRepository(db), nil, nil)
+	service := NewPortfolioGroupService(repository.NewPortfolioGroupRepository(db), portfolioRepo, aggregation, nil)
+	return db, user, service
+}
+
+func TestPortfolioGroupService_GetOverviewRollsUpSubgroups(t *testing.T) {
+	db, user, service := setupPortfolioGroupTest(t)
+	ctx := context.Background()
+	userID := user.ID.String()
+
+	brokerage := createAggregationPortfolio(t, db, user.ID, "Brokerage", "USD", map[string]int64{"AAPL": 10})
+	retirement := createAggregationPortfolio(t, db, user.ID, "Retirement", "USD", map[string]int64{"AAPL": 5, "MSFT": 2})
+	createAggregationPortfolio(t, db, user.ID, "Other", "USD", map[string]int64{"TSLA": 1})
+
+	family, err := service.Create(ctx, userID, &dto.CreatePortfolioGroupRequest{
+		Name:         "Family",
+		PortfolioIDs: []string{brokerage.ID.String()},
+	})
+	require.NoError(t, err)
+	parentID := family.ID.String()
+	_, err = service.Create(ctx, userID, &dto.CreatePortfolioGroupRequest{
+		Name:         "Retirement",
+		ParentID:     &parentID,
+		PortfolioIDs: []string{retirement.ID.String(), brokerage.ID.String()},
+	})
+	require.NoError(t, err)
+
+	overview, err := service.GetOverview(ctx, parentID, userID, "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, overview.Group.SubgroupIDs, 1)
+	assert.Len(t, overview.Portfolios, 2)
+
+	// Without market data holdings are valued at cost: AAPL 15 x 100 + MSFT 2 x 100
+	assert.True(t, overview.TotalValue.Equal(decimal.NewFromInt(1700)), overview.TotalValue.String())
+}
+
+func TestPortfolioGroupService_RejectsInvalidGroups(t *testing.T) {
+	db, user, service := setupPortfolioGroupTest(t)
+	ctx := context.Background()
+	userID := user.ID.String()
+
+	stranger := &models.User{Email: "stranger@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(stranger).Error)
+	theirs := createAggregationPortfolio(t, db, stranger.ID, "Theirs", "USD", nil)
+
+	top, err := service.Create(ctx, userID, &dto.CreatePortfolioGroupRequest{Name: "Family"})
+	require.NoError(t, err)
+	topID := top.ID.String()
+	child, err := service.Create(ctx, userID, &dto.CreatePortfolioGroupRequest{Name: "Kids", ParentID: &topID})
+	require.NoError(t, err)
+
+	_, err = service.Create(ctx, userID, &dto.CreatePortfolioGroupRequest{Name: "Family"})
+	assert.Equal(t, models.ErrPortfolioGroupDuplicateName, err)
+
+	_, err = service.Create(ctx, userID, &dto.CreatePortfolioGroupRequest{Name: "Theirs", PortfolioIDs: []string{theirs.ID.String()}})
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+
+	childID := child.ID.String()
+	_, err = service.Update(ctx, topID, userID, &dto.UpdatePortfolioGroupRequest{ParentID: &childID})
+	assert.Equal(t, models.ErrPortfolioGroupCycle, err)
+
+	_, err = service.Get(ctx, topID, stranger.ID.String())
+	assert.Equal(t, models.ErrPortfolioGroupNotFound, err)
+
+	// Deleting the parent moves its subgroups to the top level
+	require.NoError(t, service.Delete(ctx, topID, userID))
+	child, err = service.Get(ctx, childID, userID)
+	require.NoError(t, err)
+	assert.Nil(t, child.ParentID)
+}
@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type aliases for dto types for consistency with the other services
+type (
+	RecalculationReport      = dto.RecalculationReport
+	RecalculationDiscrepancy = dto.RecalculationDiscrepancy
+)
+
+// recalculationPrecision is the number of decimal places stored state is compared at,
+// matching the numeric(20,8) columns holdings and tax lots are persisted in
+const recalculationPrecision = 8
+
+// PortfolioRecalculationService defines the interface for rebuilding a portfolio from its ledger
+type PortfolioRecalculationService interface {
+	Recalculate(ctx context.Context, portfolioID, userID string, dryRun bool) (*RecalculationReport, error)
+}
+
+// portfolioRecalculationService implements PortfolioRecalculationService interface
+type portfolioRecalculationService struct {
+	db       *gorm.DB
+	rounding models.RoundingPolicy
+	now      func() time.Time
+}
+
+// NewPortfolioRecalculationService creates a new PortfolioRecalculationService instance. It
+// works on the database directly so the comparison and rewrite share one database transaction.
+func NewPortfolioRecalculationService(db *gorm.DB) PortfolioRecalculationService {
+	return NewPortfolioRecalculationServiceWithRounding(db, models.DefaultRoundingPolicy())
+}
+
+// NewPortfolioRecalculationServiceWithRounding creates a new PortfolioRecalculationService
+// instance that replays corporate actions with the given rounding policy, which should be
+// the one they were applied with
+func NewPortfolioRecalculationServiceWithRounding(db *gorm.DB, rounding models.RoundingPolicy) PortfolioRecalculationService {
+	return &portfolioRecalculationService{
+		db:       db,
+		rounding: rounding,
+		now:      func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Recalculate replays every transaction in the portfolio in order to rebuild its holdings
+// and tax lots from scratch, and reports where the stored state differs. Unless dryRun is
+// set, symbols with discrepancies are replaced with the rebuilt state; symbols that already
+// match are left untouched. A ledger that cannot be replayed leaves the portfolio unchanged.
+func (s *portfolioRecalculationService) Recalculate(ctx context.Context, portfolioID, userID string, dryRun bool) (*RecalculationReport, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	var report *RecalculationReport
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		portfolioRepo := repository.NewPortfolioRepository(tx)
+		transactionRepo := repository.NewTransactionRepository(tx)
+		holdingRepo := repository.NewHoldingRepository(tx)
+		taxLotRepo := repository.NewTaxLotRepository(tx)
+
+		portfolio, err := portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return models.ErrPortfolioNotFound
+		}
+		if !portfolio.AccessibleBy(ctx, userID) {
+			return models.ErrUnauthorizedAccess
+		}
+
+		// Replay the ledger a batch at a time so long histories aren't loaded all at once
+		replay := newLedgerReplay(portfolio, s.rounding)
+		replayed := 0
+		err = transactionRepo.IterateByPortfolioID(ctx, portfolioID, func(transactions []*models.Transaction) error {
+			for _, transaction := range transactions {
+				if err := replay.apply(transaction); err != nil {
+					return err
+				}
+			}
+			replayed += len(transactions)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		rebuiltHoldings, rebuiltLots := replay.results()
+
+		storedHoldings, err := holdingRepo.FindByPortfolioID(ctx, portfolioID)
+		if err != nil {
+			return fmt.Errorf("failed to get holdings: %w", err)
+		}
+		storedLots, err := taxLotRepo.FindByPortfolioID(ctx, portfolioID)
+		if err != nil {
+			return fmt.Errorf("failed to get tax lots: %w", err)
+		}
+
+		discrepancies := compareLedgerState(storedHoldings, storedLots, rebuiltHoldings, rebuiltLots)
+		report = &RecalculationReport{
+			PortfolioID:          portfolioID,
+			DryRun:               dryRun,
+			TransactionsReplayed: replayed,
+			Holdings:             len(rebuiltHoldings),
+			TaxLots:              len(rebuiltLots),
+			Discrepancies:        discrepancies,
+			RecalculatedAt:       s.now(),
+		}
+
+		if dryRun {
+			return nil
+		}
+		return rewriteDiscrepantSymbols(ctx, holdingRepo, taxLotRepo, portfolioID, discrepancies, rebuiltHoldings, rebuiltLots)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// rewriteDiscrepantSymbols replaces the stored holding and tax lots of every symbol with a
+// discrepancy by the rebuilt ones
+func rewriteDiscrepantSymbols(
+	ctx context.Context,
+	holdingRepo repository.HoldingRepository,
+	taxLotRepo repository.TaxLotRepository,
+	portfolioID string,
+	discrepancies []RecalculationDiscrepancy,
+	rebuiltHoldings []*models.Holding,
+	rebuiltLots []*models.TaxLot,
+) error {
+	symbols := make(map[string]bool)
+	for _, discrepancy := range discrepancies {
+		symbols[discrepancy.Symbol] = true
+	}
+
+	for symbol := range symbols {
+		// A position closed since its tax lots were last rebuilt has lots but no holding
+		if err := holdingRepo.DeleteByPortfolioIDAndSymbol(ctx, portfolioID, symbol); err != nil && !errors.Is(err, models.ErrHoldingNotFound) {
+			return fmt.Errorf("failed to delete holding for %s: %w", symbol, err)
+		}
+		if err := taxLotRepo.DeleteByPortfolioIDAndSymbol(ctx, portfolioID, symbol); err != nil {
+			return fmt.Errorf("failed to delete tax lots for %s: %w", symbol, err)
+		}
+	}
+
+	for _, holding := range rebuiltHoldings {
+		if !symbols[holding.Symbol] {
+			continue
+		}
+		if err := holdingRepo.Create(ctx, holding); err != nil {
+			return fmt.Errorf("failed to create holding for %s: %w", holding.Symbol, err)
+		}
+	}
+	for _, lot := range rebuiltLots {
+		if !symbols[lot.Symbol] {
+			continue
+		}
+		if err := taxLotRepo.Create(ctx, lot); err != nil {
+			return fmt.Errorf("failed to create tax lot for %s: %w", lot.Symbol, err)
+		}
+	}
+
+	return nil
+}
+
+// lotTotals summarizes a symbol's tax lots for comparison
+type lotTotals struct {
+	count     int
+	quantity  decimal.Decimal
+	costBasis decimal.Decimal
+}
+
+// compareLedgerState lists the differences between stored and rebuilt holdings and tax
+// lots, ordered by symbol
+func compareLedgerState(
+	storedHoldings []*models.Holding,
+	storedLots []*models.TaxLot,
+	rebuiltHoldings []*models.Holding,
+	rebuiltLots []*models.TaxLot,
+) []RecalculationDiscrepancy {
+	symbolSet := make(map[string]bool)
+	stored := make(map[string]*models.Holding)
+	for _, holding := range storedHoldings {
+		stored[holding.Symbol] = holding
+		symbolSet[holding.Symbol] = true
+	}
+	rebuilt := make(map[string]*models.Holding)
+	for _, holding := range rebuiltHoldings {
+		rebuilt[holding.Symbol] = holding
+		symbolSet[holding.Symbol] = true
+	}
+	storedTotals := totalLotsBySymbol(storedLots)
+	rebuiltTotals := totalLotsBySymbol(rebuiltLots)
+	for symbol := range storedTotals {
+		symbolSet[symbol] = true
+	}
+
+	symbols := make([]string, 0, len(symbolSet))
+	for symbol := range symbolSet {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	discrepancies := make([]RecalculationDiscrepancy, 0)
+	for _, symbol := range symbols {
+		storedHolding, rebuiltHolding := stored[symbol], rebuilt[symbol]
+		discrepancy := RecalculationDiscrepancy{Symbol: symbol}
+		if storedHolding != nil {
+			discrepancy.StoredQuantity = storedHolding.Quantity
+			discrepancy.StoredCostBasis = storedHolding.CostBasis
+		}
+		if rebuiltHolding != nil {
+			discrepancy.RebuiltQuantity = rebuiltHolding.Quantity
+			discrepancy.RebuiltCostBasis = rebuiltHolding.CostBasis
+		}
+
+		switch {
+		case storedHolding == nil && rebuiltHolding != nil:
+			discrepancy.Type = dto.DiscrepancyMissingHolding
+		case storedHolding != nil && rebuiltHolding == nil:
+			discrepancy.Type = dto.DiscrepancyUnexpectedHolding
+		case storedHolding != nil && (!amountsMatch(storedHolding.Quantity, rebuiltHolding.Quantity) ||
+			!amountsMatch(storedHolding.CostBasis, rebuiltHolding.CostBasis)):
+			discrepancy.Type = dto.DiscrepancyHoldingMismatch
+		}
+		if discrepancy.Type != "" {
+			discrepancies = append(discrepancies, discrepancy)
+		}
+
+		storedLot, rebuiltLot := storedTotals[symbol], rebuiltTotals[symbol]
+		if storedLot.count != rebuiltLot.count ||
+			!amountsMatch(storedLot.quantity, rebuiltLot.quantity) ||
+			!amountsMatch(storedLot.costBasis, rebuiltLot.costBasis) {
+			discrepancies = append(discrepancies, RecalculationDiscrepancy{
+				Symbol:           symbol,
+				Type:             dto.DiscrepancyTaxLotMismatch,
+				StoredQuantity:   storedLot.quantity,
+				RebuiltQuantity:  rebuiltLot.quantity,
+				StoredCostBasis:  storedLot.costBasis,
+				RebuiltCostBasis: rebuiltLot.costBasis,
+				StoredTaxLots:    storedLot.count,
+				RebuiltTaxLots:   rebuiltLot.count,
+			})
+		}
+	}
+
+	return discrepancies
+}
+
+// totalLotsBySymbol sums tax lots per symbol
+func totalLotsBySymbol(lots []*models.TaxLot) map[string]lotTotals {
+	totals := make(map[string]lotTotals)
+	for _, lot := range lots {
+		total := totals[lot.Symbol]
+		total.count++
+		total.quantity = total.quantity.Add(lot.Quantity)
+		total.costBasis = total.costBasis.Add(lot.CostBasis)
+		totals[lot.Symbol] = total
+	}
+	return totals
+}
+
+// amountsMatch compares two amounts at the precision they are stored with
+func amountsMatch(a, b decimal.Decimal) bool {
+	return a.Round(recalculationPrecision).Equal(b.Round(recalculationPrecision))
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupRecalculationTest(t *testing.T) (*gorm.DB, PortfolioRecalculationService, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.AutoMigrate(
+		&models.User{},
+		&models.Portfolio{},
+		&models.Holding{},
+		&models.TaxLot{},
+		&models.Transaction{},
+	))
+
+	user := &models.User{Email: "ledger@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Ledger", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	return db, NewPortfolioRecalculationService(db), portfolio
+}
+
+func createLedgerTransaction(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, txType models.TransactionType, symbol string, date time.Time, quantity, price int64) *models.Transaction {
+	p := decimal.NewFromInt(price)
+	transaction := &models.Transaction{
+		PortfolioID: portfolio.ID,
+		Type:        txType,
+		Symbol:      symbol,
+		Date:        date,
+		Quantity:    decimal.NewFromInt(quantity),
+		Price:       &p,
+	}
+	require.NoError(t, db.Create(transaction).Error)
+	return transaction
+}
+
+func loadLedgerState(t *testing.T, db *gorm.DB, portfolio *models.Portfolio) ([]*models.Holding, []*models.TaxLot) {
+	ctx := context.Background()
+
+	holdings, err := repository.NewHoldingRepository(db).FindByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	lots, err := repository.NewTaxLotRepository(db).FindByPortfolioID(ctx, portfolio.ID.String())
+	require.NoError(t, err)
+	return holdings, lots
+}
+
+func TestPortfolioRecalculationService_RebuildsFromLedger(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio := setupRecalculationTest(t)
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	// Recorded newest first to check the replay orders by date
+	createLedgerTransaction(t, db, portfolio, models.TransactionTypeSell, "AAPL", day.AddDate(0, 2, 0), 15, 150)
+	second := createLedgerTransaction(t, db, portfolio, models.TransactionTypeBuy, "AAPL", day.AddDate(0, 1, 0), 10, 120)
+	createLedgerTransaction(t, db, portfolio, models.TransactionTypeBuy, "AAPL", day, 10, 100)
+
+	// Stored state drifted: wrong quantity, no lots, and a position the ledger never opened
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID: portfolio.ID, Symbol: "AAPL",
+		Quantity: decimal.NewFromInt(7), CostBasis: decimal.NewFromInt(700), AvgCostPrice: decimal.NewFromInt(100),
+	}).Error)
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID: portfolio.ID, Symbol: "MSFT",
+		Quantity: decimal.NewFromInt(1), CostBasis: decimal.NewFromInt(300), AvgCostPrice: decimal.NewFromInt(300),
+	}).Error)
+
+	t.Run("dry run reports without writing", func(t *testing.T) {
+		report, err := service.Recalculate(ctx, portfolio.ID.String(), portfolio.UserID.String(), true)
+		require.NoError(t, err)
+
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 3, report.TransactionsReplayed)
+		assert.Equal(t, 1, report.Holdings)
+		assert.Equal(t, 1, report.TaxLots)
+		require.Len(t, report.Discrepancies, 3)
+		assert.Equal(t, dto.DiscrepancyHoldingMismatch, report.Discrepancies[0].Type)
+		assert.True(t, report.Discrepancies[0].RebuiltQuantity.Equal(decimal.NewFromInt(5)))
+		assert.Equal(t, dto.DiscrepancyTaxLotMismatch, report.Discrepancies[1].Type)
+		assert.Equal(t, 0, report.Discrepancies[1].StoredTaxLots)
+		assert.Equal(t, 1, report.Discrepancies[1].RebuiltTaxLots)
+		assert.Equal(t, "MSFT", report.Discrepancies[2].Symbol)
+		assert.Equal(t, dto.DiscrepancyUnexpectedHolding, report.Discrepancies[2].Type)
+
+		holdings, lots := loadLedgerState(t, db, portfolio)
+		assert.Len(t, holdings, 2)
+		assert.Empty(t, lots)
+	})
+
+	t.Run("recalculation replaces the stored state", func(t *testing.T) {
+		report, err := service.Recalculate(ctx, portfolio.ID.String(), portfolio.UserID.String(), false)
+		require.NoError(t, err)
+		assert.Len(t, report.Discrepancies, 3)
+
+		holdings, lots := loadLedgerState(t, db, portfolio)
+		require.Len(t, holdings, 1)
+		// Average cost: 20 shares for 2200, then 15 sold at 110
+		assert.Equal(t, "AAPL", holdings[0].Symbol)
+		assert.True(t, holdings[0].Quantity.Equal(decimal.NewFromInt(5)))
+		assert.True(t, holdings[0].CostBasis.Equal(decimal.NewFromInt(550)))
+
+		// FIFO closes the first lot and half of the second
+		require.Len(t, lots, 1)
+		assert.Equal(t, second.ID, lots[0].TransactionID)
+		assert.True(t, lots[0].Quantity.Equal(decimal.NewFromInt(5)))
+		assert.True(t, lots[0].CostBasis.Equal(decimal.NewFromInt(600)))
+	})
+
+	t.Run("a second run finds nothing to fix", func(t *testing.T) {
+		report, err := service.Recalculate(ctx, portfolio.ID.String(), portfolio.UserID.String(), false)
+		require.NoError(t, err)
+		assert.Empty(t, report.Discrepancies)
+	})
+}
+
+func TestPortfolioRecalculationService_ClosedPositionWithLots(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio := setupRecalculationTest(t)
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	createLedgerTransaction(t, db, portfolio, models.TransactionTypeBuy, "VTI", day, 5, 200)
+	_, err := service.Recalculate(ctx, portfolio.ID.String(), portfolio.UserID.String(), false)
+	require.NoError(t, err)
+
+	// A sale recorded without rebuilding the lots closes the holding but leaves its lot
+	createLedgerTransaction(t, db, portfolio, models.TransactionTypeSell, "VTI", day.AddDate(0, 1, 0), 5, 210)
+	require.NoError(t, repository.NewHoldingRepository(db).DeleteByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "VTI"))
+
+	report, err := service.Recalculate(ctx, portfolio.ID.String(), portfolio.UserID.String(), false)
+	require.NoError(t, err)
+	require.Len(t, report.Discrepancies, 1)
+
+	holdings, lots := loadLedgerState(t, db, portfolio)
+	assert.Empty(t, holdings)
+	assert.Empty(t, lots)
+}
+
+func TestPortfolioRecalculationService_MatchesCorporateActions(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio := setupRecalculationTest(t)
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	buy := createLedgerTransaction(t, db, portfolio, models.TransactionTypeBuy, "OLD", day, 100, 50)
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID: portfolio.ID, Symbol: "OLD",
+		Quantity: decimal.NewFromInt(100), CostBasis: decimal.NewFromInt(5000), AvgCostPrice: decimal.NewFromInt(50),
+	}).Error)
+	require.NoError(t, db.Create(&models.TaxLot{
+		PortfolioID: portfolio.ID, Symbol: "OLD", PurchaseDate: day,
+		Quantity: decimal.NewFromInt(100), CostBasis: decimal.NewFromInt(5000), TransactionID: buy.ID,
+	}).Error)
+
+	corporateActionService := NewCorporateActionServiceWithDB(db, models.DefaultRoundingPolicy())
+	userID := portfolio.UserID.String()
+	require.NoError(t, corporateActionService.ApplyStockSplit(ctx, portfolio.ID.String(), "OLD", userID, decimal.NewFromInt(2), day.AddDate(0, 1, 0)))
+	require.NoError(t, corporateActionService.ApplySpinoff(ctx, portfolio.ID.String(), "OLD", "SPIN", userID, decimal.NewFromFloat(0.5), models.ExplicitSpinoffAllocation(decimal.RequireFromString("0.3")), day.AddDate(0, 2, 0)))
+	require.NoError(t, corporateActionService.ApplyMerger(ctx, portfolio.ID.String(), "OLD", "NEW", userID, decimal.NewFromFloat(1.5), day.AddDate(0, 3, 0)))
+	require.NoError(t, corporateActionService.ApplyStockDividend(ctx, portfolio.ID.String(), "NEW", userID, decimal.RequireFromString("0.02"), day.AddDate(0, 3, 15)))
+	require.NoError(t, corporateActionService.ApplyReturnOfCapital(ctx, portfolio.ID.String(), "SPIN", userID, decimal.NewFromInt(2000), day.AddDate(0, 4, 0)))
+
+	report, err := service.Recalculate(ctx, portfolio.ID.String(), userID, true)
+	require.NoError(t, err)
+	assert.Empty(t, report.Discrepancies)
+	assert.Equal(t, 2, report.Holdings)
+}
+
+func TestLedgerReplay_SpinoffAllocationFromNote(t *testing.T) {
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO}
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	price := decimal.NewFromInt(50)
+
+	tests := []struct {
+		name     string
+		notes    string
+		wantCost int64
+	}{
+		{"recorded allocation", "Spinoff: received 50 shares of SPIN from OLD at 0.5 ratio, allocating 0.3 of cost basis (explicit)", 1500},
+		{"fair market value", "Spinoff: received 50 shares of SPIN from OLD at 0.5 ratio, allocating 0.1 of cost basis (fair market value, OLD closed at 90 and SPIN at 20)", 500},
+		{"legacy note uses the default", "Spinoff: received 50 shares of SPIN from OLD at 0.5 ratio", 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replay := newLedgerReplay(portfolio, models.DefaultRoundingPolicy())
+			require.NoError(t, replay.apply(&models.Transaction{
+				ID: uuid.New(), Type: models.TransactionTypeBuy, Symbol: "OLD", Date: day,
+				Quantity: decimal.NewFromInt(100), Price: &price,
+			}))
+			require.NoError(t, replay.apply(&models.Transaction{
+				ID: uuid.New(), Type: models.TransactionTypeSpinoff, Symbol: "SPIN", Date: day.AddDate(0, 1, 0),
+				Quantity: decimal.NewFromInt(50), Notes: tt.notes,
+			}))
+
+			holdings, lots := replay.results()
+			require.Len(t, holdings, 2)
+			assert.Equal(t, "SPIN", holdings[1].Symbol)
+			assert.True(t, holdings[1].CostBasis.Equal(decimal.NewFromInt(tt.wantCost)), "got %s", holdings[1].CostBasis)
+			assert.True(t, holdings[0].CostBasis.Equal(decimal.NewFromInt(5000-tt.wantCost)))
+			require.Len(t, lots, 2)
+			assert.True(t, lots[1].Quantity.Equal(decimal.NewFromInt(50)))
+		})
+	}
+}
+
+func TestLedgerReplay_Totals(t *testing.T) {
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO}
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	transaction := func(txType models.TransactionType, days int, quantity, price, commission int64) *models.Transaction {
+		tx := &models.Transaction{
+			ID: uuid.New(), Type: txType, Symbol: "AAPL", Date: day.AddDate(0, 0, days),
+			Quantity: decimal.NewFromInt(quantity), Commission: decimal.NewFromInt(commission),
+		}
+		if price > 0 {
+			p := decimal.NewFromInt(price)
+			tx.Price = &p
+		}
+		return tx
+	}
+
+	replay := newLedgerReplay(portfolio, models.DefaultRoundingPolicy())
+	for _, tx := range []*models.Transaction{
+		transaction(models.TransactionTypeBuy, 0, 10, 100, 5),
+		transaction(models.TransactionTypeBuy, 1, 10, 120, 5),
+		transaction(models.TransactionTypeDividend, 2, 20, 0, 0),
+		transaction(models.TransactionTypeDividendReinvest, 3, 1, 130, 0),
+		transaction(models.TransactionTypeSell, 4, 5, 150, 5),
+	} {
+		require.NoError(t, replay.apply(tx))
+	}
+
+	// The sale's 745 of proceeds are measured against the average cost of 2340 / 21 shares
+	averageCost := decimal.NewFromInt(2340).Div(decimal.NewFromInt(21))
+	assert.True(t, decimal.NewFromInt(745).Sub(averageCost.Mul(decimal.NewFromInt(5))).Equal(replay.totals.Realized),
+		"got %s", replay.totals.Realized)
+	assert.True(t, decimal.NewFromInt(150).Equal(replay.totals.Dividends), "got %s", replay.totals.Dividends)
+	assert.True(t, decimal.NewFromInt(15).Equal(replay.totals.Fees), "got %s", replay.totals.Fees)
+}
+
+func TestPortfolioRecalculationService_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	db, service, portfolio := setupRecalculationTest(t)
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	t.Run("other user's portfolio", func(t *testing.T) {
+		_, err := service.Recalculate(ctx, portfolio.ID.String(), uuid.New().String(), true)
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+	})
+
+	t.Run("unknown portfolio", func(t *testing.T) {
+		_, err := service.Recalculate(ctx, uuid.New().String(), portfolio.UserID.String(), true)
+		assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+	})
+
+	t.Run("selling more than the ledger holds", func(t *testing.T) {
+		createLedgerTransaction(t, db, portfolio, models.TransactionTypeBuy, "AAPL", day, 10, 100)
+		createLedgerTransaction(t, db, portfolio, models.TransactionTypeSell, "AAPL", day.AddDate(0, 0, 1), 20, 100)
+
+		_, err := service.Recalculate(ctx, portfolio.ID.String(), portfolio.UserID.String(), false)
+		assert.ErrorIs(t, err, models.ErrLedgerReplayFailed)
+	})
+}
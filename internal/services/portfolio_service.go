@@ -1,6 +1,8 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -11,17 +13,19 @@ import (
 
 // PortfolioService defines the interface for portfolio operations
 type PortfolioService interface {
-	Create(userID, name, description, baseCurrency string, costBasisMethod models.CostBasisMethod) (*models.Portfolio, error)
-	GetByID(id string, userID string) (*models.Portfolio, error)
-	GetAllByUserID(userID string) ([]*models.Portfolio, error)
-	Update(id, userID, name, description string) (*models.Portfolio, error)
-	Delete(id, userID string) error
+	Create(ctx context.Context, userID, name, description, baseCurrency string, costBasisMethod models.CostBasisMethod) (*models.Portfolio, error)
+	GetByID(ctx context.Context, id string, userID string) (*models.Portfolio, error)
+	GetAllByUserID(ctx context.Context, userID string) ([]*models.Portfolio, error)
+	Update(ctx context.Context, id, userID string, version int, name, description string) (*models.Portfolio, error)
+	Delete(ctx context.Context, id, userID string) error
 }
 
 // portfolioService implements PortfolioService interface
 type portfolioService struct {
 	portfolioRepo repository.PortfolioRepository
 	userRepo      repository.UserRepository
+	orgRepo       repository.OrganizationRepository
+	settings      UserSettingsService
 }
 
 // NewPortfolioService creates a new PortfolioService instance
@@ -35,24 +39,62 @@ func NewPortfolioService(
 	}
 }
 
-// Create creates a new portfolio for a user
+// NewPortfolioServiceWithQuotas creates a PortfolioService that enforces the portfolio quota
+// of the organization a user was provisioned into
+func NewPortfolioServiceWithQuotas(
+	portfolioRepo repository.PortfolioRepository,
+	userRepo repository.UserRepository,
+	orgRepo repository.OrganizationRepository,
+) PortfolioService {
+	return NewPortfolioServiceWithSettings(portfolioRepo, userRepo, orgRepo, nil)
+}
+
+// NewPortfolioServiceWithSettings creates a PortfolioService that enforces organization
+// quotas like NewPortfolioServiceWithQuotas and gives new portfolios the currency and cost
+// basis method of their user's settings when none is given
+func NewPortfolioServiceWithSettings(
+	portfolioRepo repository.PortfolioRepository,
+	userRepo repository.UserRepository,
+	orgRepo repository.OrganizationRepository,
+	settings UserSettingsService,
+) PortfolioService {
+	return &portfolioService{
+		portfolioRepo: portfolioRepo,
+		userRepo:      userRepo,
+		orgRepo:       orgRepo,
+		settings:      settings,
+	}
+}
+
+// Create creates a new portfolio for a user. A portfolio created while acting in an
+// organization belongs to that organization and is shared with its members. Advisors cannot
+// create portfolios on behalf of a client.
 func (s *portfolioService) Create(
+	ctx context.Context,
 	userID, name, description, baseCurrency string,
 	costBasisMethod models.CostBasisMethod,
 ) (*models.Portfolio, error) {
+	if _, ok := models.DelegationScopeFromContext(ctx); ok {
+		return nil, models.ErrDelegationForbidden
+	}
+
 	// Validate user exists
-	_, err := s.userRepo.FindByID(userID)
+	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
+	if err := s.checkPortfolioQuota(ctx, user); err != nil {
+		return nil, err
+	}
+
 	// Validate name is not empty
 	if name == "" {
 		return nil, models.ErrPortfolioNameRequired
 	}
 
 	// Check if portfolio with same name already exists for user
-	exists, err := s.portfolioRepo.ExistsByUserIDAndName(userID, name)
+	exists, err := s.portfolioRepo.ExistsByUserIDAndName(ctx, userID, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check portfolio existence: %w", err)
 	}
@@ -60,7 +102,19 @@ func (s *portfolioService) Create(
 		return nil, models.ErrPortfolioDuplicateName
 	}
 
-	// Set defaults
+	// Set defaults, preferring the user's settings
+	if (baseCurrency == "" || costBasisMethod == "") && s.settings != nil {
+		settings, err := s.settings.Get(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user settings: %w", err)
+		}
+		if baseCurrency == "" {
+			baseCurrency = settings.DisplayCurrency
+		}
+		if costBasisMethod == "" {
+			costBasisMethod = settings.DefaultCostBasisMethod
+		}
+	}
 	if baseCurrency == "" {
 		baseCurrency = "USD"
 	}
@@ -82,6 +136,13 @@ func (s *portfolioService) Create(
 		BaseCurrency:    baseCurrency,
 		CostBasisMethod: costBasisMethod,
 	}
+	if scope, ok := models.OrganizationScopeFromContext(ctx); ok {
+		if scope.Role != "" && !scope.Role.CanWrite() {
+			return nil, models.ErrInsufficientOrganizationRole
+		}
+		organizationID := scope.OrganizationID
+		portfolio.OrganizationID = &organizationID
+	}
 
 	// Validate portfolio
 	if err := portfolio.Validate(); err != nil {
@@ -89,37 +150,71 @@ func (s *portfolioService) Create(
 	}
 
 	// Save to database
-	if err := s.portfolioRepo.Create(portfolio); err != nil {
+	if err := s.portfolioRepo.Create(ctx, portfolio); err != nil {
 		return nil, fmt.Errorf("failed to create portfolio: %w", err)
 	}
 
 	return portfolio, nil
 }
 
+// checkPortfolioQuota returns ErrPortfolioQuotaExceeded if the user belongs to an organization
+// whose per-user portfolio quota is already used up
+func (s *portfolioService) checkPortfolioQuota(ctx context.Context, user *models.User) error {
+	if s.orgRepo == nil || user.OrganizationID == nil {
+		return nil
+	}
+
+	organization, err := s.orgRepo.FindByID(user.OrganizationID.String())
+	if err != nil {
+		return fmt.Errorf("failed to load organization: %w", err)
+	}
+	if organization.MaxPortfoliosPerUser == 0 {
+		return nil
+	}
+
+	portfolios, err := s.portfolioRepo.FindByUserID(ctx, user.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to count portfolios: %w", err)
+	}
+	if organization.PortfolioQuotaReached(int64(len(portfolios))) {
+		return models.ErrPortfolioQuotaExceeded
+	}
+
+	return nil
+}
+
 // GetByID retrieves a portfolio by ID, ensuring it belongs to the user
-func (s *portfolioService) GetByID(id string, userID string) (*models.Portfolio, error) {
-	portfolio, err := s.portfolioRepo.FindByID(id)
+func (s *portfolioService) GetByID(ctx context.Context, id string, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Verify the portfolio belongs to the user
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
 	return portfolio, nil
 }
 
-// GetAllByUserID retrieves all portfolios for a user
-func (s *portfolioService) GetAllByUserID(userID string) ([]*models.Portfolio, error) {
+// GetAllByUserID retrieves all portfolios for a user, all of an organization's portfolios
+// when acting in one, or the portfolios a client delegated when acting on their behalf
+func (s *portfolioService) GetAllByUserID(ctx context.Context, userID string) ([]*models.Portfolio, error) {
 	// Validate user exists
 	_, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
-	portfolios, err := s.portfolioRepo.FindByUserID(userID)
+	var portfolios []*models.Portfolio
+	if delegation, ok := models.DelegationScopeFromContext(ctx); ok {
+		portfolios, err = s.findDelegated(ctx, delegation)
+	} else if scope, ok := models.OrganizationScopeFromContext(ctx); ok {
+		portfolios, err = s.portfolioRepo.FindByOrganizationID(ctx, scope.OrganizationID)
+	} else {
+		portfolios, err = s.portfolioRepo.FindByUserID(ctx, userID)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve portfolios: %w", err)
 	}
@@ -127,17 +222,37 @@ func (s *portfolioService) GetAllByUserID(userID string) ([]*models.Portfolio, e
 	return portfolios, nil
 }
 
-// Update updates a portfolio's details
-func (s *portfolioService) Update(id, userID, name, description string) (*models.Portfolio, error) {
+// findDelegated finds the delegated portfolios that still exist
+func (s *portfolioService) findDelegated(ctx context.Context, delegation models.DelegationScope) ([]*models.Portfolio, error) {
+	portfolios := make([]*models.Portfolio, 0, len(delegation.PortfolioIDs))
+	for _, id := range delegation.PortfolioIDs {
+		portfolio, err := s.portfolioRepo.FindByID(ctx, id.String())
+		if err != nil {
+			if errors.Is(err, models.ErrPortfolioNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		portfolios = append(portfolios, portfolio)
+	}
+	return portfolios, nil
+}
+
+// Update updates a portfolio's details. The version is the one the caller read the portfolio
+// at; if the portfolio has changed since, a VersionConflictError is returned.
+func (s *portfolioService) Update(ctx context.Context, id, userID string, version int, name, description string) (*models.Portfolio, error) {
 	// Get existing portfolio and verify ownership
-	portfolio, err := s.GetByID(id, userID)
+	portfolio, err := s.GetByID(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
+	if portfolio.Version != version {
+		return nil, &models.VersionConflictError{CurrentVersion: portfolio.Version}
+	}
 
 	// Check if new name conflicts with another portfolio
 	if name != "" && name != portfolio.Name {
-		exists, err := s.portfolioRepo.ExistsByUserIDAndName(userID, name)
+		exists, err := s.portfolioRepo.ExistsByUserIDAndName(ctx, userID, name)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check portfolio name: %w", err)
 		}
@@ -158,7 +273,7 @@ func (s *portfolioService) Update(id, userID, name, description string) (*models
 	}
 
 	// Save changes
-	if err := s.portfolioRepo.Update(portfolio); err != nil {
+	if err := s.portfolioRepo.Update(ctx, portfolio); err != nil {
 		return nil, fmt.Errorf("failed to update portfolio: %w", err)
 	}
 
@@ -166,15 +281,20 @@ func (s *portfolioService) Update(id, userID, name, description string) (*models
 }
 
 // Delete deletes a portfolio, ensuring it belongs to the user
-func (s *portfolioService) Delete(id, userID string) error {
+func (s *portfolioService) Delete(ctx context.Context, id, userID string) error {
+	// Only the client may delete a portfolio they delegated
+	if _, ok := models.DelegationScopeFromContext(ctx); ok {
+		return models.ErrDelegationForbidden
+	}
+
 	// Get portfolio and verify ownership
-	_, err := s.GetByID(id, userID)
+	_, err := s.GetByID(ctx, id, userID)
 	if err != nil {
 		return err
 	}
 
 	// Delete the portfolio (cascade will delete related records)
-	if err := s.portfolioRepo.Delete(id); err != nil {
+	if err := s.portfolioRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete portfolio: %w", err)
 	}
 
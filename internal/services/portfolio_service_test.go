@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
@@ -25,6 +26,8 @@ func setupPortfolioTestDB(t *testing.T) *gorm.DB {
 }
 
 func TestPortfolioService_Create(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioTestDB(t)
 	portfolioRepo := repository.NewPortfolioRepository(db)
 	userRepo := repository.NewUserRepository(db)
@@ -42,6 +45,7 @@ func TestPortfolioService_Create(t *testing.T) {
 
 	t.Run("successful creation", func(t *testing.T) {
 		portfolio, err := service.Create(
+			ctx,
 			user.ID.String(),
 			"Test Portfolio",
 			"A test portfolio",
@@ -60,6 +64,7 @@ func TestPortfolioService_Create(t *testing.T) {
 
 	t.Run("duplicate name error", func(t *testing.T) {
 		_, err := service.Create(
+			ctx,
 			user.ID.String(),
 			"Test Portfolio",
 			"Another portfolio",
@@ -73,6 +78,7 @@ func TestPortfolioService_Create(t *testing.T) {
 
 	t.Run("empty name error", func(t *testing.T) {
 		_, err := service.Create(
+			ctx,
 			user.ID.String(),
 			"",
 			"Description",
@@ -86,6 +92,7 @@ func TestPortfolioService_Create(t *testing.T) {
 
 	t.Run("invalid user error", func(t *testing.T) {
 		_, err := service.Create(
+			ctx,
 			uuid.New().String(),
 			"New Portfolio",
 			"Description",
@@ -98,6 +105,7 @@ func TestPortfolioService_Create(t *testing.T) {
 
 	t.Run("default values", func(t *testing.T) {
 		portfolio, err := service.Create(
+			ctx,
 			user.ID.String(),
 			"Default Portfolio",
 			"",
@@ -111,7 +119,50 @@ func TestPortfolioService_Create(t *testing.T) {
 	})
 }
 
+func TestPortfolioService_Create_OrganizationQuota(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupPortfolioTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&models.Organization{}))
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	service := NewPortfolioServiceWithQuotas(portfolioRepo, userRepo, orgRepo)
+
+	organization := &models.Organization{ExternalID: "acme", Name: "Acme", MaxPortfoliosPerUser: 1}
+	assert.NoError(t, orgRepo.Save(organization))
+
+	externalID := "jane"
+	member := &models.User{Email: "jane@acme.example", OrganizationID: &organization.ID, ExternalID: &externalID}
+	assert.NoError(t, member.SetPassword("password123"))
+	assert.NoError(t, orgRepo.SaveMember(member))
+
+	solo := &models.User{Email: "solo@example.com"}
+	assert.NoError(t, solo.SetPassword("password123"))
+	assert.NoError(t, userRepo.Create(solo))
+
+	_, err := service.Create(ctx, member.ID.String(), "First", "", "", "")
+	assert.NoError(t, err)
+
+	_, err = service.Create(ctx, member.ID.String(), "Second", "", "", "")
+	assert.Equal(t, models.ErrPortfolioQuotaExceeded, err)
+
+	// Users outside an organization are not limited
+	for _, name := range []string{"First", "Second"} {
+		_, err = service.Create(ctx, solo.ID.String(), name, "", "", "")
+		assert.NoError(t, err)
+	}
+
+	// Raising the quota allows more portfolios
+	organization.MaxPortfoliosPerUser = 0
+	assert.NoError(t, orgRepo.Save(organization))
+	_, err = service.Create(ctx, member.ID.String(), "Second", "", "", "")
+	assert.NoError(t, err)
+}
+
 func TestPortfolioService_GetByID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioTestDB(t)
 	portfolioRepo := repository.NewPortfolioRepository(db)
 	userRepo := repository.NewUserRepository(db)
@@ -129,6 +180,7 @@ func TestPortfolioService_GetByID(t *testing.T) {
 
 	// Create test portfolio
 	portfolio, err := service.Create(
+		ctx,
 		user.ID.String(),
 		"Test Portfolio",
 		"Description",
@@ -138,7 +190,7 @@ func TestPortfolioService_GetByID(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("successful retrieval", func(t *testing.T) {
-		retrieved, err := service.GetByID(portfolio.ID.String(), user.ID.String())
+		retrieved, err := service.GetByID(ctx, portfolio.ID.String(), user.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, retrieved)
@@ -148,20 +200,22 @@ func TestPortfolioService_GetByID(t *testing.T) {
 
 	t.Run("unauthorized access", func(t *testing.T) {
 		otherUserID := uuid.New().String()
-		_, err := service.GetByID(portfolio.ID.String(), otherUserID)
+		_, err := service.GetByID(ctx, portfolio.ID.String(), otherUserID)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrUnauthorizedAccess, err)
 	})
 
 	t.Run("not found error", func(t *testing.T) {
-		_, err := service.GetByID(uuid.New().String(), user.ID.String())
+		_, err := service.GetByID(ctx, uuid.New().String(), user.ID.String())
 
 		assert.Error(t, err)
 	})
 }
 
 func TestPortfolioService_GetAllByUserID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioTestDB(t)
 	portfolioRepo := repository.NewPortfolioRepository(db)
 	userRepo := repository.NewUserRepository(db)
@@ -178,7 +232,7 @@ func TestPortfolioService_GetAllByUserID(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("empty list", func(t *testing.T) {
-		portfolios, err := service.GetAllByUserID(user.ID.String())
+		portfolios, err := service.GetAllByUserID(ctx, user.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, portfolios)
@@ -186,13 +240,13 @@ func TestPortfolioService_GetAllByUserID(t *testing.T) {
 	})
 
 	// Create test portfolios
-	_, err = service.Create(user.ID.String(), "Portfolio 1", "Desc 1", "USD", models.CostBasisFIFO)
+	_, err = service.Create(ctx, user.ID.String(), "Portfolio 1", "Desc 1", "USD", models.CostBasisFIFO)
 	assert.NoError(t, err)
-	_, err = service.Create(user.ID.String(), "Portfolio 2", "Desc 2", "USD", models.CostBasisLIFO)
+	_, err = service.Create(ctx, user.ID.String(), "Portfolio 2", "Desc 2", "USD", models.CostBasisLIFO)
 	assert.NoError(t, err)
 
 	t.Run("multiple portfolios", func(t *testing.T) {
-		portfolios, err := service.GetAllByUserID(user.ID.String())
+		portfolios, err := service.GetAllByUserID(ctx, user.ID.String())
 
 		assert.NoError(t, err)
 		assert.Len(t, portfolios, 2)
@@ -200,6 +254,8 @@ func TestPortfolioService_GetAllByUserID(t *testing.T) {
 }
 
 func TestPortfolioService_Update(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioTestDB(t)
 	portfolioRepo := repository.NewPortfolioRepository(db)
 	userRepo := repository.NewUserRepository(db)
@@ -217,6 +273,7 @@ func TestPortfolioService_Update(t *testing.T) {
 
 	// Create test portfolio
 	portfolio, err := service.Create(
+		ctx,
 		user.ID.String(),
 		"Original Name",
 		"Original Description",
@@ -227,8 +284,10 @@ func TestPortfolioService_Update(t *testing.T) {
 
 	t.Run("successful update", func(t *testing.T) {
 		updated, err := service.Update(
+			ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
+			1,
 			"Updated Name",
 			"Updated Description",
 		)
@@ -236,13 +295,31 @@ func TestPortfolioService_Update(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "Updated Name", updated.Name)
 		assert.Equal(t, "Updated Description", updated.Description)
+		assert.Equal(t, 2, updated.Version)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		_, err := service.Update(
+			ctx,
+			portfolio.ID.String(),
+			user.ID.String(),
+			1,
+			"Lost Update",
+			"",
+		)
+
+		var conflict *models.VersionConflictError
+		assert.ErrorAs(t, err, &conflict)
+		assert.Equal(t, 2, conflict.CurrentVersion)
 	})
 
 	t.Run("unauthorized update", func(t *testing.T) {
 		otherUserID := uuid.New().String()
 		_, err := service.Update(
+			ctx,
 			portfolio.ID.String(),
 			otherUserID,
+			2,
 			"Hacked Name",
 			"Hacked Description",
 		)
@@ -254,6 +331,7 @@ func TestPortfolioService_Update(t *testing.T) {
 	t.Run("duplicate name error", func(t *testing.T) {
 		// Create another portfolio
 		_, err := service.Create(
+			ctx,
 			user.ID.String(),
 			"Another Portfolio",
 			"Description",
@@ -264,8 +342,10 @@ func TestPortfolioService_Update(t *testing.T) {
 
 		// Try to update first portfolio with the second portfolio's name
 		_, err = service.Update(
+			ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
+			2,
 			"Another Portfolio",
 			"Description",
 		)
@@ -276,6 +356,8 @@ func TestPortfolioService_Update(t *testing.T) {
 }
 
 func TestPortfolioService_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupPortfolioTestDB(t)
 	portfolioRepo := repository.NewPortfolioRepository(db)
 	userRepo := repository.NewUserRepository(db)
@@ -293,6 +375,7 @@ func TestPortfolioService_Delete(t *testing.T) {
 
 	// Create test portfolio
 	portfolio, err := service.Create(
+		ctx,
 		user.ID.String(),
 		"To Delete",
 		"Description",
@@ -302,18 +385,19 @@ func TestPortfolioService_Delete(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Run("successful deletion", func(t *testing.T) {
-		err := service.Delete(portfolio.ID.String(), user.ID.String())
+		err := service.Delete(ctx, portfolio.ID.String(), user.ID.String())
 
 		assert.NoError(t, err)
 
 		// Verify it's deleted
-		_, err = service.GetByID(portfolio.ID.String(), user.ID.String())
+		_, err = service.GetByID(ctx, portfolio.ID.String(), user.ID.String())
 		assert.Error(t, err)
 	})
 
 	t.Run("unauthorized deletion", func(t *testing.T) {
 		// Create another portfolio
 		portfolio2, err := service.Create(
+			ctx,
 			user.ID.String(),
 			"Another Portfolio",
 			"Description",
@@ -323,7 +407,7 @@ func TestPortfolioService_Delete(t *testing.T) {
 		assert.NoError(t, err)
 
 		otherUserID := uuid.New().String()
-		err = service.Delete(portfolio2.ID.String(), otherUserID)
+		err = service.Delete(ctx, portfolio2.ID.String(), otherUserID)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrUnauthorizedAccess, err)
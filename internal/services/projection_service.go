@@ -0,0 +1,335 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type alias for dto type for consistency with the other analytics services
+type ProjectionResult = dto.ProjectionResult
+
+// ProjectionService defines the interface for simulating a portfolio's future value under
+// periodic contributions
+type ProjectionService interface {
+	Project(ctx context.Context, portfolioID, userID string, req *dto.ProjectionRequest) (*ProjectionResult, error)
+}
+
+// projectionService implements ProjectionService interface
+type projectionService struct {
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.PerformanceSnapshotRepository
+	now             func() time.Time
+}
+
+// NewProjectionService creates a new ProjectionService instance
+func NewProjectionService(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+) ProjectionService {
+	return &projectionService{
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *projectionService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// Project simulates the portfolio's value month by month over the horizon, adding the monthly
+// contribution at the end of each month, and summarizes the simulated paths as percentile
+// bands. Without volatility an EXPECTED_RETURN projection has a single, deterministic path.
+func (s *projectionService) Project(ctx context.Context, portfolioID, userID string, req *dto.ProjectionRequest) (*ProjectionResult, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	method := req.Method
+	if method == "" {
+		method = models.ProjectionMethodHistorical
+		if req.ExpectedReturn != nil {
+			method = models.ProjectionMethodExpectedReturn
+		}
+	}
+	if !method.IsValid() {
+		return nil, models.ErrInvalidProjectionMethod
+	}
+	if method == models.ProjectionMethodExpectedReturn && req.ExpectedReturn == nil {
+		return nil, models.ErrExpectedReturnRequired
+	}
+	if err := validateProjection(req); err != nil {
+		return nil, err
+	}
+
+	today := s.now()
+	startDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	months, err := projectionMonths(startDate, req.HorizonMonths, req.TargetDate)
+	if err != nil {
+		return nil, err
+	}
+
+	startingValue, err := latestSnapshotValue(ctx, s.snapshotRepo, portfolioID, req.StartingValue)
+	if err != nil {
+		return nil, err
+	}
+
+	simulations := req.Simulations
+	if simulations == 0 {
+		simulations = models.DefaultProjectionSimulations
+	}
+
+	result := &ProjectionResult{
+		PortfolioID:         portfolioID,
+		Method:              method,
+		StartDate:           startDate,
+		EndDate:             startDate.AddDate(0, months, 0),
+		HorizonMonths:       months,
+		StartingValue:       startingValue,
+		MonthlyContribution: req.MonthlyContribution,
+		TotalContributed:    startingValue.Add(req.MonthlyContribution.Mul(decimal.NewFromInt(int64(months)))),
+		TargetValue:         req.TargetValue,
+	}
+
+	// draw returns one simulated monthly return
+	var draw func(rng *rand.Rand) float64
+	switch method {
+	case models.ProjectionMethodExpectedReturn:
+		monthlyReturn, monthlyVolatility := monthlyReturnDistribution(*req.ExpectedReturn, req.Volatility)
+		if monthlyVolatility == 0 {
+			simulations = 1
+		}
+		draw = func(rng *rand.Rand) float64 {
+			return monthlyReturn + monthlyVolatility*rng.NormFloat64()
+		}
+		result.ExpectedReturn = *req.ExpectedReturn
+		result.Volatility = req.Volatility
+
+	case models.ProjectionMethodHistorical:
+		returns, err := snapshotMonthlyReturns(ctx, s.snapshotRepo, s.transactionRepo, portfolioID, startDate)
+		if err != nil {
+			return nil, err
+		}
+		if len(returns) < models.MinProjectionHistoryMonths {
+			return nil, models.ErrInsufficientProjectionHistory
+		}
+		draw = func(rng *rand.Rand) float64 {
+			return returns[rng.IntN(len(returns))]
+		}
+		result.ExpectedReturn, result.Volatility = annualizeMonthlyReturns(returns)
+		result.HistoryMonths = len(returns)
+	}
+	result.Simulations = simulations
+
+	var seed uint64
+	if req.Seed != nil {
+		seed = uint64(*req.Seed)
+	} else {
+		seed = rand.Uint64()
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	start, _ := startingValue.Float64()
+	contribution, _ := req.MonthlyContribution.Float64()
+	values := make([]float64, simulations)
+	for i := range values {
+		values[i] = start
+	}
+
+	sorted := make([]float64, simulations)
+	contributed := startingValue
+	result.Bands = make([]models.ProjectionBand, 0, months)
+	for month := 1; month <= months; month++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		for i := range values {
+			// A month can at worst wipe out the portfolio
+			growth := math.Max(0, 1+draw(rng))
+			values[i] = values[i]*growth + contribution
+		}
+		contributed = contributed.Add(req.MonthlyContribution)
+
+		copy(sorted, values)
+		result.Bands = append(result.Bands, models.NewProjectionBand(month, startDate.AddDate(0, month, 0), contributed, sorted))
+	}
+
+	if req.TargetValue != nil {
+		target, _ := req.TargetValue.Float64()
+		reached := 0
+		for _, value := range values {
+			if value >= target {
+				reached++
+			}
+		}
+		probability := decimal.NewFromInt(int64(reached)).Div(decimal.NewFromInt(int64(simulations))).Mul(decimal.NewFromInt(100)).Round(2)
+		result.TargetProbability = &probability
+	}
+
+	return result, nil
+}
+
+// latestSnapshotValue returns the requested starting value, or the portfolio's latest snapshot
+// value
+func latestSnapshotValue(
+	ctx context.Context,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	portfolioID string,
+	requested *decimal.Decimal,
+) (decimal.Decimal, error) {
+	if requested != nil {
+		return *requested, nil
+	}
+
+	snapshot, err := snapshotRepo.FindLatestByPortfolioID(ctx, portfolioID)
+	if err != nil || snapshot == nil {
+		return decimal.Zero, models.ErrProjectionStartingValue
+	}
+	return snapshot.TotalValue, nil
+}
+
+// snapshotMonthlyReturns returns the portfolio's cash-flow-adjusted returns between its
+// month-end snapshots before endDate
+func snapshotMonthlyReturns(
+	ctx context.Context,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	transactionRepo repository.TransactionRepository,
+	portfolioID string,
+	endDate time.Time,
+) ([]float64, error) {
+	snapshots, err := snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, time.Time{}, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve snapshots: %w", err)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+
+	// Keep the last snapshot of each month
+	var monthEnds []*models.PerformanceSnapshot
+	for _, snapshot := range snapshots {
+		last := len(monthEnds) - 1
+		if last >= 0 && sameMonth(monthEnds[last].Date, snapshot.Date) {
+			monthEnds[last] = snapshot
+			continue
+		}
+		monthEnds = append(monthEnds, snapshot)
+	}
+	if len(monthEnds) < 2 {
+		return nil, nil
+	}
+
+	transactions, err := transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, nil, &endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+
+	periods, _ := twrSubPeriods(monthEnds, transactions)
+	returns := make([]float64, 0, len(periods))
+	for _, period := range periods {
+		if period.Excluded {
+			continue
+		}
+		r, _ := period.Return.Float64()
+		returns = append(returns, r)
+	}
+	return returns, nil
+}
+
+// validateProjection checks the amounts and rates of a projection request
+func validateProjection(req *dto.ProjectionRequest) error {
+	if req.MonthlyContribution.IsNegative() || req.Volatility.IsNegative() {
+		return models.ErrInvalidProjection
+	}
+	if req.StartingValue != nil && req.StartingValue.IsNegative() {
+		return models.ErrInvalidProjection
+	}
+	if req.TargetValue != nil && req.TargetValue.IsNegative() {
+		return models.ErrInvalidProjection
+	}
+	if req.ExpectedReturn != nil && req.ExpectedReturn.LessThanOrEqual(decimal.NewFromInt(-100)) {
+		return models.ErrInvalidProjection
+	}
+	if req.Simulations < 0 || req.Simulations > models.MaxProjectionSimulations {
+		return models.ErrInvalidProjection
+	}
+	return nil
+}
+
+// projectionMonths returns the number of months to simulate: the requested horizon, or the
+// whole months from startDate up to the target date
+func projectionMonths(startDate time.Time, horizonMonths int, targetDate *time.Time) (int, error) {
+	if (horizonMonths == 0) == (targetDate == nil) {
+		return 0, models.ErrInvalidProjectionHorizon
+	}
+
+	months := horizonMonths
+	if targetDate != nil {
+		months = (targetDate.Year()-startDate.Year())*12 + int(targetDate.Month()-startDate.Month())
+		if startDate.AddDate(0, months, 0).After(*targetDate) {
+			months--
+		}
+	}
+
+	if months < 1 || months > models.MaxProjectionMonths {
+		return 0, models.ErrInvalidProjectionHorizon
+	}
+	return months, nil
+}
+
+// monthlyReturnDistribution converts an annual return and volatility, as percentages, into the
+// mean and standard deviation of normally distributed monthly returns
+func monthlyReturnDistribution(annualReturn, annualVolatility decimal.Decimal) (float64, float64) {
+	annual, _ := annualReturn.Div(decimal.NewFromInt(100)).Float64()
+	volatility, _ := annualVolatility.Div(decimal.NewFromInt(100)).Float64()
+	return math.Pow(1+annual, 1.0/12) - 1, volatility / math.Sqrt(12)
+}
+
+// annualizeMonthlyReturns returns the annualized geometric mean return and volatility, as
+// percentages, of monthly returns
+func annualizeMonthlyReturns(returns []float64) (decimal.Decimal, decimal.Decimal) {
+	growth := 1.0
+	mean := 0.0
+	for _, r := range returns {
+		growth *= 1 + r
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	annualReturn := (math.Pow(growth, 12/float64(len(returns))) - 1) * 100
+	annualVolatility := math.Sqrt(variance) * math.Sqrt(12) * 100
+	return decimal.NewFromFloat(annualReturn).Round(2), decimal.NewFromFloat(annualVolatility).Round(2)
+}
+
+// sameMonth returns true if both times fall in the same calendar month
+func sameMonth(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month()
+}
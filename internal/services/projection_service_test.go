@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+var projectionTestNow = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+func setupProjectionTest(t *testing.T) (*gorm.DB, *models.Portfolio, *projectionService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.PerformanceSnapshot{}))
+
+	user := &models.User{Email: "saver@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Retirement", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	service := NewProjectionService(
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+	).(*projectionService)
+	service.now = func() time.Time { return projectionTestNow }
+
+	return db, portfolio, service
+}
+
+// createMonthlySnapshots creates month-end snapshots ending last month, growing by 1% a month
+func createMonthlySnapshots(t *testing.T, db *gorm.DB, portfolio *models.Portfolio, months int) {
+	value := decimal.NewFromInt(10000)
+	for i := months; i > 0; i-- {
+		date := time.Date(2024, time.Month(6-i+1), 0, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, db.Create(&models.PerformanceSnapshot{
+			PortfolioID:    portfolio.ID,
+			Date:           date,
+			TotalValue:     value,
+			TotalCostBasis: decimal.NewFromInt(10000),
+		}).Error)
+		value = value.Mul(decimal.NewFromFloat(1.01))
+	}
+}
+
+func TestProjectionService_ExpectedReturnWithoutVolatility(t *testing.T) {
+	_, portfolio, service := setupProjectionTest(t)
+	ctx := context.Background()
+	userID := portfolio.UserID.String()
+
+	startingValue := decimal.NewFromInt(1000)
+	expectedReturn := decimal.NewFromInt(12)
+	targetValue := decimal.NewFromInt(2400)
+	result, err := service.Project(ctx, portfolio.ID.String(), userID, &dto.ProjectionRequest{
+		StartingValue:       &startingValue,
+		MonthlyContribution: decimal.NewFromInt(100),
+		HorizonMonths:       12,
+		ExpectedReturn:      &expectedReturn,
+		TargetValue:         &targetValue,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, models.ProjectionMethodExpectedReturn, result.Method)
+	assert.Equal(t, 1, result.Simulations)
+	assert.Equal(t, time.Date(2025, 6, 15, 0, 0, 0, 0, time.UTC), result.EndDate)
+	assert.True(t, result.TotalContributed.Equal(decimal.NewFromInt(2200)))
+	require.Len(t, result.Bands, 12)
+
+	// 12% a year compounds the starting value to 1120 and the contributions to about 1264.65
+	last := result.Bands[11]
+	assert.True(t, last.P10.Equal(last.P90))
+	assert.True(t, last.P50.Equal(decimal.NewFromFloat(2384.65)), last.P50.String())
+	require.NotNil(t, result.TargetProbability)
+	assert.True(t, result.TargetProbability.IsZero())
+}
+
+func TestProjectionService_MonteCarloIsRepeatableWithSeed(t *testing.T) {
+	_, portfolio, service := setupProjectionTest(t)
+	ctx := context.Background()
+
+	startingValue := decimal.NewFromInt(50000)
+	expectedReturn := decimal.NewFromInt(7)
+	seed := int64(42)
+	targetDate := time.Date(2034, 7, 1, 0, 0, 0, 0, time.UTC)
+	req := &dto.ProjectionRequest{
+		StartingValue:       &startingValue,
+		MonthlyContribution: decimal.NewFromInt(500),
+		TargetDate:          &targetDate,
+		ExpectedReturn:      &expectedReturn,
+		Volatility:          decimal.NewFromInt(15),
+		Simulations:         500,
+		Seed:                &seed,
+	}
+
+	result, err := service.Project(ctx, portfolio.ID.String(), portfolio.UserID.String(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 500, result.Simulations)
+	assert.Equal(t, 120, result.HorizonMonths)
+
+	last := result.Bands[len(result.Bands)-1]
+	assert.True(t, last.P10.LessThan(last.P25))
+	assert.True(t, last.P25.LessThan(last.P50))
+	assert.True(t, last.P50.LessThan(last.P75))
+	assert.True(t, last.P75.LessThan(last.P90))
+
+	again, err := service.Project(ctx, portfolio.ID.String(), portfolio.UserID.String(), req)
+	require.NoError(t, err)
+	assert.Equal(t, result.Bands, again.Bands)
+}
+
+func TestProjectionService_Historical(t *testing.T) {
+	db, portfolio, service := setupProjectionTest(t)
+	ctx := context.Background()
+	userID := portfolio.UserID.String()
+
+	createMonthlySnapshots(t, db, portfolio, 12)
+	_, err := service.Project(ctx, portfolio.ID.String(), userID, &dto.ProjectionRequest{HorizonMonths: 12})
+	assert.Equal(t, models.ErrInsufficientProjectionHistory, err)
+
+	require.NoError(t, db.Where("portfolio_id = ?", portfolio.ID).Delete(&models.PerformanceSnapshot{}).Error)
+	createMonthlySnapshots(t, db, portfolio, 13)
+
+	result, err := service.Project(ctx, portfolio.ID.String(), userID, &dto.ProjectionRequest{HorizonMonths: 12, Simulations: 100})
+	require.NoError(t, err)
+	assert.Equal(t, models.ProjectionMethodHistorical, result.Method)
+	assert.Equal(t, 12, result.HistoryMonths)
+	assert.True(t, result.ExpectedReturn.Equal(decimal.NewFromFloat(12.68)), result.ExpectedReturn.String())
+	assert.True(t, result.Volatility.IsZero(), result.Volatility.String())
+
+	// Every month resamples the same 1% gain from the latest snapshot
+	expected := result.StartingValue.Mul(decimal.NewFromFloat(1.01).Pow(decimal.NewFromInt(12))).Round(2)
+	last := result.Bands[11]
+	assert.True(t, last.P10.Equal(expected), last.P10.String())
+	assert.True(t, last.P90.Equal(expected), last.P90.String())
+}
+
+func TestProjectionService_InvalidRequests(t *testing.T) {
+	db, portfolio, service := setupProjectionTest(t)
+	ctx := context.Background()
+	portfolioID := portfolio.ID.String()
+	userID := portfolio.UserID.String()
+
+	expectedReturn := decimal.NewFromInt(5)
+	tooSoon := projectionTestNow.AddDate(0, 0, 20)
+
+	tests := []struct {
+		name string
+		req  dto.ProjectionRequest
+		err  error
+	}{
+		{"no horizon", dto.ProjectionRequest{ExpectedReturn: &expectedReturn}, models.ErrInvalidProjectionHorizon},
+		{"target date within a month", dto.ProjectionRequest{ExpectedReturn: &expectedReturn, TargetDate: &tooSoon}, models.ErrInvalidProjectionHorizon},
+		{"unknown method", dto.ProjectionRequest{Method: "GUESS", HorizonMonths: 12}, models.ErrInvalidProjectionMethod},
+		{"missing expected return", dto.ProjectionRequest{Method: models.ProjectionMethodExpectedReturn, HorizonMonths: 12}, models.ErrExpectedReturnRequired},
+		{"negative contribution", dto.ProjectionRequest{ExpectedReturn: &expectedReturn, HorizonMonths: 12, MonthlyContribution: decimal.NewFromInt(-1)}, models.ErrInvalidProjection},
+		{"no snapshot", dto.ProjectionRequest{ExpectedReturn: &expectedReturn, HorizonMonths: 12}, models.ErrProjectionStartingValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Project(ctx, portfolioID, userID, &tt.req)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	stranger := &models.User{Email: "stranger@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(stranger).Error)
+	_, err := service.Project(ctx, portfolioID, stranger.ID.String(), &dto.ProjectionRequest{ExpectedReturn: &expectedReturn, HorizonMonths: 12})
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+}
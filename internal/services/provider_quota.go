@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/lenon/portfolios/internal/cache"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// quotaKeyPrefix namespaces the request counters of provider quotas in the cache
+const quotaKeyPrefix = "quota:"
+
+// QuotaStatus describes the current request budget for a market data provider
+type QuotaStatus struct {
+	Provider        string    `json:"provider"`
+	DailyLimit      int       `json:"daily_limit"`
+	DailyUsed       int       `json:"daily_used"`
+	DailyRemaining  int       `json:"daily_remaining"`
+	DailyResetAt    time.Time `json:"daily_reset_at"`
+	MinuteLimit     int       `json:"minute_limit"`
+	MinuteUsed      int       `json:"minute_used"`
+	MinuteRemaining int       `json:"minute_remaining"`
+	MinuteResetAt   time.Time `json:"minute_reset_at"`
+	// CircuitState is whether the provider's circuit breaker lets requests through
+	CircuitState CircuitState `json:"circuit_state,omitempty"`
+}
+
+// QuotaTracker tracks provider requests against daily and per-minute budgets.
+// The daily window resets at midnight UTC and the minute window at the start of each
+// minute. A limit of zero means unlimited. The counters are kept in a cache store, so
+// that every server sharing the store spends from the same budget.
+type QuotaTracker struct {
+	store       cache.Store
+	provider    string
+	dailyLimit  int
+	minuteLimit int
+	now         func() time.Time
+}
+
+// NewQuotaTracker creates a new QuotaTracker for the given provider and budgets that
+// counts requests in process memory
+func NewQuotaTracker(provider string, dailyLimit, minuteLimit int) *QuotaTracker {
+	return NewQuotaTrackerWithStore(cache.NewMemoryStore(), provider, dailyLimit, minuteLimit)
+}
+
+// NewQuotaTrackerWithStore creates a new QuotaTracker for the given provider and budgets
+// that counts requests in store
+func NewQuotaTrackerWithStore(store cache.Store, provider string, dailyLimit, minuteLimit int) *QuotaTracker {
+	return &QuotaTracker{
+		store:       store,
+		provider:    provider,
+		dailyLimit:  dailyLimit,
+		minuteLimit: minuteLimit,
+		now:         func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// quotaWindow is one of a quota's budgets during its current window
+type quotaWindow struct {
+	key   string
+	limit int
+	start time.Time
+	size  time.Duration
+}
+
+// windows returns the daily and minute windows that contain now
+func (q *QuotaTracker) windows(now time.Time) [2]quotaWindow {
+	day, minute := now.Truncate(24*time.Hour), now.Truncate(time.Minute)
+	return [2]quotaWindow{
+		{key: fmt.Sprintf("%s%s:day:%d", quotaKeyPrefix, q.provider, day.Unix()), limit: q.dailyLimit, start: day, size: 24 * time.Hour},
+		{key: fmt.Sprintf("%s%s:minute:%d", quotaKeyPrefix, q.provider, minute.Unix()), limit: q.minuteLimit, start: minute, size: time.Minute},
+	}
+}
+
+// Acquire reserves n requests from the budget. It reserves nothing and returns
+// models.ErrQuotaExceeded if either the daily or minute budget can't cover all n requests.
+// A reservation that overshoots a budget is given back, so concurrent callers may both be
+// refused, but never both allowed past it.
+func (q *QuotaTracker) Acquire(n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	var reserved []quotaWindow
+	release := func() {
+		for _, window := range reserved {
+			_, _ = q.store.IncrBy(ctx, window.key, -int64(n), window.size)
+		}
+	}
+	for _, window := range q.windows(q.now()) {
+		count, err := q.store.IncrBy(ctx, window.key, int64(n), window.size)
+		if err != nil {
+			release()
+			return fmt.Errorf("failed to count provider requests: %w", err)
+		}
+		reserved = append(reserved, window)
+		if window.limit > 0 && count > int64(window.limit) {
+			release()
+			return models.ErrQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// Status returns the current usage and remaining budget. It only reads the counters.
+func (q *QuotaTracker) Status() *QuotaStatus {
+	windows := q.windows(q.now())
+	day, minute := windows[0], windows[1]
+	dayCount, minuteCount := q.used(day), q.used(minute)
+
+	return &QuotaStatus{
+		Provider:        q.provider,
+		DailyLimit:      q.dailyLimit,
+		DailyUsed:       dayCount,
+		DailyRemaining:  remaining(q.dailyLimit, dayCount),
+		DailyResetAt:    day.start.Add(day.size),
+		MinuteLimit:     q.minuteLimit,
+		MinuteUsed:      minuteCount,
+		MinuteRemaining: remaining(q.minuteLimit, minuteCount),
+		MinuteResetAt:   minute.start.Add(minute.size),
+	}
+}
+
+// used returns the requests counted in a window, or zero when none are or the store can't
+// be read
+func (q *QuotaTracker) used(window quotaWindow) int {
+	value, err := q.store.Get(context.Background(), window.key)
+	if err != nil {
+		return 0
+	}
+	count, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// remaining returns the remaining budget, or -1 when the limit is unlimited
+func remaining(limit, used int) int {
+	if limit <= 0 {
+		return -1
+	}
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
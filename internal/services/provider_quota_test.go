@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lenon/portfolios/internal/cache"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func newTestQuotaTracker(dailyLimit, minuteLimit int, now *time.Time) *QuotaTracker {
+	q := NewQuotaTracker("alphavantage", dailyLimit, minuteLimit)
+	q.now = func() time.Time { return *now }
+	return q
+}
+
+func TestQuotaTracker_MinuteLimit(t *testing.T) {
+	now := time.Date(2025, 3, 10, 14, 0, 0, 0, time.UTC)
+	q := newTestQuotaTracker(0, 5, &now)
+
+	assert.NoError(t, q.Acquire(3))
+	assert.NoError(t, q.Acquire(2))
+	assert.ErrorIs(t, q.Acquire(1), models.ErrQuotaExceeded)
+
+	status := q.Status()
+	assert.Equal(t, 5, status.MinuteUsed)
+	assert.Equal(t, 0, status.MinuteRemaining)
+	assert.Equal(t, -1, status.DailyRemaining)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, q.Acquire(5))
+}
+
+func TestQuotaTracker_DailyLimit(t *testing.T) {
+	now := time.Date(2025, 3, 10, 23, 0, 0, 0, time.UTC)
+	q := newTestQuotaTracker(25, 0, &now)
+
+	assert.NoError(t, q.Acquire(20))
+
+	// All-or-nothing: a batch that doesn't fit reserves nothing
+	assert.ErrorIs(t, q.Acquire(10), models.ErrQuotaExceeded)
+	assert.Equal(t, 5, q.Status().DailyRemaining)
+
+	assert.NoError(t, q.Acquire(5))
+	assert.ErrorIs(t, q.Acquire(1), models.ErrQuotaExceeded)
+	assert.Equal(t, time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), q.Status().DailyResetAt)
+
+	// Budget resets at midnight UTC
+	now = time.Date(2025, 3, 11, 0, 0, 1, 0, time.UTC)
+	assert.NoError(t, q.Acquire(1))
+	assert.Equal(t, 24, q.Status().DailyRemaining)
+}
+
+func TestQuotaTracker_Unlimited(t *testing.T) {
+	q := NewQuotaTracker("", 0, 0)
+
+	assert.NoError(t, q.Acquire(1000))
+	assert.NoError(t, q.Acquire(0))
+
+	status := q.Status()
+	assert.Equal(t, 1000, status.DailyUsed)
+	assert.Equal(t, -1, status.DailyRemaining)
+	assert.Equal(t, -1, status.MinuteRemaining)
+}
+
+func TestQuotaTracker_SharedStore(t *testing.T) {
+	now := time.Date(2025, 3, 10, 14, 0, 30, 0, time.UTC)
+	store := cache.NewMemoryStore()
+	newTracker := func() *QuotaTracker {
+		q := NewQuotaTrackerWithStore(store, "alphavantage", 10, 0)
+		q.now = func() time.Time { return now }
+		return q
+	}
+	first, second := newTracker(), newTracker()
+
+	// Servers sharing a store spend from the same budget
+	assert.NoError(t, first.Acquire(6))
+	assert.ErrorIs(t, second.Acquire(5), models.ErrQuotaExceeded)
+	assert.NoError(t, second.Acquire(4))
+	assert.Equal(t, 10, first.Status().DailyUsed)
+	assert.Equal(t, 0, first.Status().DailyRemaining)
+}
+
+func TestQuotaTracker_StatusOnlyReads(t *testing.T) {
+	now := time.Date(2025, 3, 10, 14, 0, 30, 0, time.UTC)
+	q := newTestQuotaTracker(0, 5, &now)
+
+	status := q.Status()
+	assert.Equal(t, 0, status.MinuteUsed)
+	assert.Equal(t, time.Date(2025, 3, 10, 14, 1, 0, 0, time.UTC), status.MinuteResetAt)
+	for _, window := range q.windows(now) {
+		_, err := q.store.Get(context.Background(), window.key)
+		assert.ErrorIs(t, err, cache.ErrCacheMiss)
+	}
+
+	assert.NoError(t, q.Acquire(2))
+	now = now.Add(15 * time.Second)
+	status = q.Status()
+	assert.Equal(t, 2, status.MinuteUsed)
+	assert.Equal(t, time.Date(2025, 3, 10, 14, 1, 0, 0, time.UTC), status.MinuteResetAt)
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/push"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// PushService defines the interface for registering devices and pushing notifications to them
+type PushService interface {
+	RegisterDevice(ctx context.Context, userID string, req *dto.RegisterPushDeviceRequest) (*models.PushDevice, error)
+	ListDevices(ctx context.Context, userID string) ([]*models.PushDevice, error)
+	DeleteDevice(ctx context.Context, deviceID, userID string) error
+	VAPIDPublicKey() (string, error)
+	Deliver(ctx context.Context, notification *models.Notification)
+}
+
+// pushService implements PushService interface
+type pushService struct {
+	deviceRepo repository.PushDeviceRepository
+	keys       *push.VAPIDKeys
+	senders    map[models.PushPlatform]push.Sender
+	now        func() time.Time
+}
+
+// NewPushService creates a new PushService instance. Without VAPID keys browsers can't
+// subscribe, so only APNs devices can be registered.
+func NewPushService(deviceRepo repository.PushDeviceRepository, keys *push.VAPIDKeys) PushService {
+	senders := map[models.PushPlatform]push.Sender{
+		models.PushPlatformAPNs: push.APNsStubSender{},
+	}
+	if keys != nil {
+		senders[models.PushPlatformWeb] = push.NewWebPushSender(keys)
+	}
+	return &pushService{
+		deviceRepo: deviceRepo,
+		keys:       keys,
+		senders:    senders,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// RegisterDevice registers a device for the user's notifications. Registering an endpoint
+// again updates its keys.
+func (s *pushService) RegisterDevice(ctx context.Context, userID string, req *dto.RegisterPushDeviceRequest) (*models.PushDevice, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	device := &models.PushDevice{
+		UserID:   uid,
+		Platform: req.Platform,
+		Endpoint: strings.TrimSpace(req.Endpoint),
+		// Browsers encode the keys without padding, but some libraries add it
+		P256dh: strings.TrimRight(req.Keys.P256dh, "="),
+		Auth:   strings.TrimRight(req.Keys.Auth, "="),
+		Name:   strings.TrimSpace(req.Name),
+	}
+	if err := device.Validate(); err != nil {
+		return nil, err
+	}
+	if device.Platform == models.PushPlatformWeb && s.keys == nil {
+		return nil, models.ErrPushUnavailable
+	}
+
+	count, err := s.deviceRepo.CountByUserID(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if count >= models.MaxPushDevicesPerUser && !s.hasEndpoint(ctx, uid, device.Endpoint) {
+		return nil, models.ErrPushDeviceLimit
+	}
+
+	if err := s.deviceRepo.Register(ctx, device); err != nil {
+		return nil, err
+	}
+
+	return device, nil
+}
+
+// hasEndpoint reports whether the user already registered a device with the endpoint
+func (s *pushService) hasEndpoint(ctx context.Context, userID uuid.UUID, endpoint string) bool {
+	devices, err := s.deviceRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return false
+	}
+	for _, device := range devices {
+		if device.Endpoint == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// ListDevices returns the user's registered devices
+func (s *pushService) ListDevices(ctx context.Context, userID string) ([]*models.PushDevice, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	return s.deviceRepo.FindByUserID(ctx, uid)
+}
+
+// DeleteDevice removes one of the user's devices
+func (s *pushService) DeleteDevice(ctx context.Context, deviceID, userID string) error {
+	id, err := uuid.Parse(deviceID)
+	if err != nil {
+		return models.ErrPushDeviceNotFound
+	}
+
+	device, err := s.deviceRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if device.UserID.String() != userID {
+		return models.ErrPushDeviceNotFound // Don't leak other users' devices
+	}
+
+	return s.deviceRepo.Delete(ctx, id)
+}
+
+// VAPIDPublicKey returns the key browsers subscribe with
+func (s *pushService) VAPIDPublicKey() (string, error) {
+	if s.keys == nil {
+		return "", models.ErrPushUnavailable
+	}
+	return s.keys.PublicKey(), nil
+}
+
+// Deliver pushes a notification to each of its user's devices. Devices whose subscription is
+// gone are removed; other failures are logged, as the notification is still stored in-app.
+func (s *pushService) Deliver(ctx context.Context, notification *models.Notification) {
+	devices, err := s.deviceRepo.FindByUserID(ctx, notification.UserID)
+	if err != nil {
+		log.Printf("Error finding push devices of user %s: %v", notification.UserID, err)
+		return
+	}
+
+	message := push.Message{
+		NotificationID: notification.ID.String(),
+		Type:           string(notification.Type),
+		Title:          notification.Title,
+		Body:           notification.Message,
+	}
+	if notification.PortfolioID != nil {
+		message.PortfolioID = notification.PortfolioID.String()
+	}
+
+	for _, device := range devices {
+		sender, ok := s.senders[device.Platform]
+		if !ok {
+			continue
+		}
+
+		err := sender.Send(ctx, device, message)
+		switch {
+		case err == nil:
+			if err := s.deviceRepo.MarkUsed(ctx, device.ID, s.now()); err != nil {
+				log.Printf("Error updating push device %s: %v", device.ID, err)
+			}
+		case errors.Is(err, push.ErrSubscriptionGone):
+			if err := s.deviceRepo.Delete(ctx, device.ID); err != nil {
+				log.Printf("Error removing expired push device %s: %v", device.ID, err)
+			}
+		case errors.Is(err, push.ErrPlatformUnsupported):
+		default:
+			log.Printf("Error pushing notification %s to device %s: %v", notification.ID, device.ID, err)
+		}
+	}
+}
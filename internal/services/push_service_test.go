@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/push"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// recordingSender records the messages it sends, failing for the endpoints in errs
+type recordingSender struct {
+	sent []push.Message
+	errs map[string]error
+}
+
+func (s *recordingSender) Send(ctx context.Context, device *models.PushDevice, message push.Message) error {
+	if err := s.errs[device.Endpoint]; err != nil {
+		return err
+	}
+	s.sent = append(s.sent, message)
+	return nil
+}
+
+func setupPushService(t *testing.T, keys *push.VAPIDKeys) (*pushService, repository.NotificationRepository) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.PushDevice{}, &models.Notification{}))
+	return NewPushService(repository.NewPushDeviceRepository(db), keys).(*pushService), repository.NewNotificationRepository(db)
+}
+
+func webPushRequest(t *testing.T, endpoint string) *dto.RegisterPushDeviceRequest {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &dto.RegisterPushDeviceRequest{
+		Platform: models.PushPlatformWeb,
+		Endpoint: endpoint,
+		Keys: dto.PushSubscriptionKeys{
+			// Padded, as some libraries send them
+			P256dh: base64.URLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			Auth:   base64.URLEncoding.EncodeToString(make([]byte, 16)),
+		},
+	}
+}
+
+func TestPushService_RegisterDevice(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	// Browsers can't subscribe without VAPID keys
+	service, _ := setupPushService(t, nil)
+	_, err := service.VAPIDPublicKey()
+	assert.Equal(t, models.ErrPushUnavailable, err)
+	_, err = service.RegisterDevice(ctx, userID, webPushRequest(t, "https://push.example.com/1"))
+	assert.Equal(t, models.ErrPushUnavailable, err)
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keys, err := push.ParseVAPIDKeys("", base64.RawURLEncoding.EncodeToString(key.Bytes()), "mailto:admin@example.com")
+	require.NoError(t, err)
+	service, _ = setupPushService(t, keys)
+
+	device, err := service.RegisterDevice(ctx, userID, webPushRequest(t, "https://push.example.com/1"))
+	require.NoError(t, err)
+	assert.False(t, strings.HasSuffix(device.P256dh, "="))
+
+	_, err = service.RegisterDevice(ctx, userID, webPushRequest(t, "http://push.example.com/1"))
+	assert.ErrorIs(t, err, models.ErrInvalidPushDevice)
+	_, err = service.RegisterDevice(ctx, userID, &dto.RegisterPushDeviceRequest{Platform: models.PushPlatformAPNs, Endpoint: "not hex"})
+	assert.ErrorIs(t, err, models.ErrInvalidPushDevice)
+
+	// Past the limit only endpoints already registered can be registered again
+	for i := 1; i < models.MaxPushDevicesPerUser; i++ {
+		_, err := service.RegisterDevice(ctx, userID, &dto.RegisterPushDeviceRequest{
+			Platform: models.PushPlatformAPNs, Endpoint: strings.Repeat("ab", i),
+		})
+		require.NoError(t, err)
+	}
+	_, err = service.RegisterDevice(ctx, userID, webPushRequest(t, "https://push.example.com/2"))
+	assert.Equal(t, models.ErrPushDeviceLimit, err)
+	again, err := service.RegisterDevice(ctx, userID, webPushRequest(t, "https://push.example.com/1"))
+	require.NoError(t, err)
+	assert.Equal(t, device.ID, again.ID)
+
+	// Other users' devices aren't found
+	assert.Equal(t, models.ErrPushDeviceNotFound, service.DeleteDevice(ctx, device.ID.String(), uuid.New().String()))
+	require.NoError(t, service.DeleteDevice(ctx, device.ID.String(), userID))
+	devices, err := service.ListDevices(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, devices, models.MaxPushDevicesPerUser-1)
+}
+
+func TestNotificationService_PushesToDevices(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	pushes, notificationRepo := setupPushService(t, nil)
+
+	// The second device's subscription was dropped by its push service
+	sender := &recordingSender{errs: map[string]error{"dead": push.ErrSubscriptionGone}}
+	pushes.senders[models.PushPlatformAPNs] = sender
+	for _, endpoint := range []string{"abcd", "dead"} {
+		_, err := pushes.RegisterDevice(ctx, userID.String(), &dto.RegisterPushDeviceRequest{
+			Platform: models.PushPlatformAPNs, Endpoint: endpoint,
+		})
+		require.NoError(t, err)
+	}
+
+	service := NewNotificationServiceWithPush(notificationRepo, pushes)
+	portfolioID := uuid.New()
+	notification := &models.Notification{
+		UserID: userID, PortfolioID: &portfolioID, Type: models.NotificationTypeCorporateAction,
+		Title: "AAPL SPLIT on 2024-06-10 awaits approval", Message: "Stock split 4 for AAPL",
+	}
+	require.NoError(t, service.Notify(ctx, notification))
+
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, push.Message{
+		NotificationID: notification.ID.String(),
+		Type:           "CORPORATE_ACTION",
+		Title:          notification.Title,
+		Body:           notification.Message,
+		PortfolioID:    portfolioID.String(),
+	}, sender.sent[0])
+
+	devices, err := pushes.ListDevices(ctx, userID.String())
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "abcd", devices[0].Endpoint)
+	assert.NotNil(t, devices[0].LastUsedAt)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// CSVImportJobHandler runs queued CSV imports, reporting progress after every row. The job
+// ID is the import's batch ID. An import that was stopped at a checkpoint and requeued
+// resumes from the progress it saved.
+func CSVImportJobHandler(importService CSVImportService) QueuedJobHandler {
+	return func(ctx context.Context, job *models.QueuedJob, report func(progress any)) (any, error) {
+		portfolioID, err := queuedJobPortfolioID(job)
+		if err != nil {
+			return nil, err
+		}
+
+		var req dto.CSVImportRequest
+		if err := job.DecodePayload(&req); err != nil {
+			return nil, err
+		}
+
+		var checkpoint *dto.ImportProgress
+		var saved dto.ImportProgress
+		if ok, err := job.DecodeProgress(&saved); err != nil {
+			return nil, err
+		} else if ok {
+			checkpoint = &saved
+		}
+
+		return importService.RunImport(ctx, portfolioID, job.ID, req, checkpoint, func(progress dto.ImportProgress) {
+			report(progress)
+		})
+	}
+}
+
+// TrackerImportJobHandler runs queued imports of other portfolio trackers' exports. An
+// export that is imported with errors succeeds as a job; its result says what failed.
+func TrackerImportJobHandler(trackerImportService TrackerImportService) QueuedJobHandler {
+	return func(ctx context.Context, job *models.QueuedJob, _ func(progress any)) (any, error) {
+		var req dto.TrackerImportRequest
+		if err := job.DecodePayload(&req); err != nil {
+			return nil, err
+		}
+
+		return trackerImportService.Import(ctx, job.UserID.String(), req)
+	}
+}
+
+// RecalculationJobHandler runs queued portfolio recalculations
+func RecalculationJobHandler(recalculationService PortfolioRecalculationService) QueuedJobHandler {
+	return func(ctx context.Context, job *models.QueuedJob, _ func(progress any)) (any, error) {
+		portfolioID, err := queuedJobPortfolioID(job)
+		if err != nil {
+			return nil, err
+		}
+
+		var req dto.RecalculationRequest
+		if err := job.DecodePayload(&req); err != nil {
+			return nil, err
+		}
+
+		return recalculationService.Recalculate(ctx, portfolioID, job.UserID.String(), req.DryRun)
+	}
+}
+
+// TaxReportJobHandler runs queued tax report generation. The stored result has the layout
+// of dto.TaxReportResponse.
+func TaxReportJobHandler(taxLotService TaxLotService) QueuedJobHandler {
+	return func(ctx context.Context, job *models.QueuedJob, _ func(progress any)) (any, error) {
+		portfolioID, err := queuedJobPortfolioID(job)
+		if err != nil {
+			return nil, err
+		}
+
+		var req dto.TaxReportRequest
+		if err := job.DecodePayload(&req); err != nil {
+			return nil, err
+		}
+
+		return taxLotService.GenerateTaxReport(ctx, portfolioID, job.UserID.String(), req.TaxYear)
+	}
+}
+
+// SimulationJobHandler runs queued withdrawal simulations. The simulation stores its own result,
+// which the job's result repeats in the layout of dto.SimulationResponse.
+func SimulationJobHandler(simulationService SimulationService) QueuedJobHandler {
+	return func(ctx context.Context, job *models.QueuedJob, _ func(progress any)) (any, error) {
+		var payload dto.SimulationJob
+		if err := job.DecodePayload(&payload); err != nil {
+			return nil, err
+		}
+
+		simulation, err := simulationService.Run(ctx, payload.SimulationID.String())
+		if err != nil {
+			return nil, err
+		}
+		return dto.ToSimulationResponse(simulation), nil
+	}
+}
+
+// SnapshotBackfillJobHandler runs queued snapshot backfills, reporting progress at every
+// checkpoint. A backfill that was stopped and requeued resumes from its stored checkpoint.
+func SnapshotBackfillJobHandler(backfillService SnapshotBackfillService) QueuedJobHandler {
+	return func(ctx context.Context, job *models.QueuedJob, report func(progress any)) (any, error) {
+		portfolioID, err := queuedJobPortfolioID(job)
+		if err != nil {
+			return nil, err
+		}
+
+		var req dto.SnapshotBackfillRequest
+		if err := job.DecodePayload(&req); err != nil {
+			return nil, err
+		}
+
+		return backfillService.Backfill(ctx, portfolioID, job.UserID.String(), req, func(progress dto.SnapshotBackfillProgress) {
+			report(progress)
+		})
+	}
+}
+
+// queuedJobPortfolioID returns the ID of the portfolio a job works on
+func queuedJobPortfolioID(job *models.QueuedJob) (string, error) {
+	if job.PortfolioID == nil {
+		return "", fmt.Errorf("%s job has no portfolio", job.Type)
+	}
+	return job.PortfolioID.String(), nil
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// RebalancePlanService defines the interface for rebalancing execution plan operations
+type RebalancePlanService interface {
+	CreatePlan(ctx context.Context, portfolioID, userID string, plan *models.RebalancePlan) (*models.RebalancePlan, error)
+	GetPlan(ctx context.Context, id, portfolioID, userID string) (*models.RebalancePlan, error)
+	GetPlans(ctx context.Context, portfolioID, userID string) ([]*models.RebalancePlan, error)
+	ExecuteTrade(ctx context.Context, planID, tradeID, portfolioID, userID string, fill TradeFill) (*models.RebalancePlan, error)
+	SkipTrade(ctx context.Context, planID, tradeID, portfolioID, userID string) (*models.RebalancePlan, error)
+	CancelPlan(ctx context.Context, id, portfolioID, userID string) (*models.RebalancePlan, error)
+	DeletePlan(ctx context.Context, id, portfolioID, userID string) error
+}
+
+// TradeFill holds the actual execution of a planned trade. When TransactionID is set the
+// existing transaction (for example one imported from a broker statement) is linked as the
+// fill and the other fields are ignored; otherwise a new transaction is recorded.
+type TradeFill struct {
+	TransactionID string
+	Date          time.Time
+	Quantity      decimal.Decimal
+	Price         decimal.Decimal
+	Commission    decimal.Decimal
+}
+
+// rebalancePlanService implements RebalancePlanService interface
+type rebalancePlanService struct {
+	planRepo           repository.RebalancePlanRepository
+	portfolioRepo      repository.PortfolioRepository
+	transactionService TransactionService
+	restrictions       TradingRestrictionLookup
+}
+
+// NewRebalancePlanService creates a new RebalancePlanService instance
+func NewRebalancePlanService(
+	planRepo repository.RebalancePlanRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionService TransactionService,
+) RebalancePlanService {
+	return NewRebalancePlanServiceWithTradingRestrictions(planRepo, portfolioRepo, transactionService, nil)
+}
+
+// NewRebalancePlanServiceWithTradingRestrictions creates a RebalancePlanService that refuses
+// to plan or record new fills for symbols restrictions reports as halted or suspended. A nil
+// lookup allows every symbol.
+func NewRebalancePlanServiceWithTradingRestrictions(
+	planRepo repository.RebalancePlanRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionService TransactionService,
+	restrictions TradingRestrictionLookup,
+) RebalancePlanService {
+	return &rebalancePlanService{
+		planRepo:           planRepo,
+		portfolioRepo:      portfolioRepo,
+		transactionService: transactionService,
+		restrictions:       restrictions,
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *rebalancePlanService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// CreatePlan saves a new execution plan for a set of rebalancing trades
+func (s *rebalancePlanService) CreatePlan(ctx context.Context, portfolioID, userID string, plan *models.RebalancePlan) (*models.RebalancePlan, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.PortfolioID = portfolio.ID
+	plan.Status = models.RebalancePlanStatusActive
+	for _, trade := range plan.Trades {
+		trade.Symbol = strings.ToUpper(strings.TrimSpace(trade.Symbol))
+		trade.Status = models.RebalanceTradeStatusPending
+	}
+
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, len(plan.Trades))
+	for _, trade := range plan.Trades {
+		symbols = append(symbols, trade.Symbol)
+	}
+	if restricted := restrictedSymbols(ctx, s.restrictions, symbols); len(restricted) > 0 {
+		return nil, fmt.Errorf("%w: %s", models.ErrRebalanceSymbolRestricted, strings.Join(restricted, ", "))
+	}
+
+	if err := s.planRepo.Create(ctx, plan); err != nil {
+		return nil, fmt.Errorf("failed to create rebalance plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// GetPlan retrieves a plan with its trades, ensuring it belongs to the user's portfolio
+func (s *rebalancePlanService) GetPlan(ctx context.Context, id, portfolioID, userID string) (*models.RebalancePlan, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	plan, err := s.planRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, models.ErrRebalancePlanNotFound
+	}
+	if plan.PortfolioID.String() != portfolioID {
+		return nil, models.ErrRebalancePlanNotFound
+	}
+
+	return plan, nil
+}
+
+// GetPlans retrieves all plans for a portfolio, newest first
+func (s *rebalancePlanService) GetPlans(ctx context.Context, portfolioID, userID string) ([]*models.RebalancePlan, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.planRepo.FindByPortfolioID(ctx, portfolioID)
+}
+
+// ExecuteTrade marks a planned trade as executed with its actual fill. The plan is
+// completed once no trades remain pending. A new fill can't be recorded while the symbol is
+// halted or suspended, but an existing transaction can still be linked.
+func (s *rebalancePlanService) ExecuteTrade(ctx context.Context, planID, tradeID, portfolioID, userID string, fill TradeFill) (*models.RebalancePlan, error) {
+	plan, trade, err := s.findPendingTrade(ctx, planID, tradeID, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transaction *models.Transaction
+	created := false
+	if fill.TransactionID != "" {
+		transaction, err = s.transactionService.GetByID(ctx, fill.TransactionID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if transaction.PortfolioID != plan.PortfolioID ||
+			transaction.Type != trade.Side ||
+			!strings.EqualFold(transaction.Symbol, trade.Symbol) {
+			return nil, models.ErrRebalanceFillMismatch
+		}
+	} else {
+		if restrictedSymbols(ctx, s.restrictions, []string{trade.Symbol}) != nil {
+			return nil, fmt.Errorf("%w: %s", models.ErrRebalanceSymbolRestricted, trade.Symbol)
+		}
+
+		// The trade update (or the rollback below) must follow the new transaction even if
+		// the caller gives up
+		ctx = context.WithoutCancel(ctx)
+
+		notes := fmt.Sprintf("Rebalance plan: %s", plan.Name)
+		transaction, err = s.transactionService.Create(ctx, portfolioID, userID, trade.Side, trade.Symbol,
+			fill.Date, fill.Quantity, fill.Price, fill.Commission, "", notes, "")
+		if err != nil {
+			return nil, err
+		}
+		created = true
+	}
+
+	executedAt := transaction.Date
+	quantity := transaction.Quantity
+	price := decimal.Zero
+	if transaction.Price != nil {
+		price = *transaction.Price
+	}
+
+	trade.Status = models.RebalanceTradeStatusExecuted
+	trade.ExecutedQuantity = &quantity
+	trade.ExecutedPrice = &price
+	trade.ExecutedAt = &executedAt
+	trade.TransactionID = &transaction.ID
+
+	if err := s.planRepo.UpdateTrade(ctx, trade); err != nil {
+		err = fmt.Errorf("failed to update rebalance plan trade: %w", err)
+		if created {
+			// Don't leave behind a transaction the plan doesn't know about
+			if deleteErr := s.transactionService.Delete(ctx, transaction.ID.String(), userID); deleteErr != nil {
+				return nil, fmt.Errorf("%w (rollback failed: %v)", err, deleteErr)
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.completeIfDone(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// SkipTrade marks a planned trade as intentionally not executed
+func (s *rebalancePlanService) SkipTrade(ctx context.Context, planID, tradeID, portfolioID, userID string) (*models.RebalancePlan, error) {
+	plan, trade, err := s.findPendingTrade(ctx, planID, tradeID, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	trade.Status = models.RebalanceTradeStatusSkipped
+	if err := s.planRepo.UpdateTrade(ctx, trade); err != nil {
+		return nil, fmt.Errorf("failed to update rebalance plan trade: %w", err)
+	}
+
+	if err := s.completeIfDone(ctx, plan); err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// CancelPlan abandons an active plan. Trades already executed are kept.
+func (s *rebalancePlanService) CancelPlan(ctx context.Context, id, portfolioID, userID string) (*models.RebalancePlan, error) {
+	plan, err := s.GetPlan(ctx, id, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsActive() {
+		return nil, models.ErrRebalancePlanNotActive
+	}
+
+	now := time.Now().UTC()
+	plan.Status = models.RebalancePlanStatusCancelled
+	plan.ClosedAt = &now
+	if err := s.planRepo.Update(ctx, plan); err != nil {
+		return nil, fmt.Errorf("failed to cancel rebalance plan: %w", err)
+	}
+
+	return plan, nil
+}
+
+// DeletePlan deletes a plan and its trades. Transactions recorded as fills are kept.
+func (s *rebalancePlanService) DeletePlan(ctx context.Context, id, portfolioID, userID string) error {
+	plan, err := s.GetPlan(ctx, id, portfolioID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.planRepo.Delete(ctx, plan.ID.String())
+}
+
+// findPendingTrade loads an active plan and one of its trades that is still pending
+func (s *rebalancePlanService) findPendingTrade(ctx context.Context, planID, tradeID, portfolioID, userID string) (*models.RebalancePlan, *models.RebalancePlanTrade, error) {
+	plan, err := s.GetPlan(ctx, planID, portfolioID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !plan.IsActive() {
+		return nil, nil, models.ErrRebalancePlanNotActive
+	}
+
+	for _, trade := range plan.Trades {
+		if trade.ID.String() != tradeID {
+			continue
+		}
+		if !trade.IsPending() {
+			return nil, nil, models.ErrRebalanceTradeNotPending
+		}
+		return plan, trade, nil
+	}
+
+	return nil, nil, models.ErrRebalanceTradeNotFound
+}
+
+// completeIfDone marks the plan completed once every trade is executed or skipped
+func (s *rebalancePlanService) completeIfDone(ctx context.Context, plan *models.RebalancePlan) error {
+	if plan.PendingTrades() > 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	plan.Status = models.RebalancePlanStatusCompleted
+	plan.ClosedAt = &now
+	if err := s.planRepo.Update(ctx, plan); err != nil {
+		return fmt.Errorf("failed to complete rebalance plan: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupRebalancePlanServiceTest(t *testing.T) (RebalancePlanService, TransactionService, *gorm.DB, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.Holding{},
+		&models.RebalancePlan{}, &models.RebalancePlanTrade{})
+	require.NoError(t, err)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionService := NewTransactionService(
+		repository.NewTransactionRepository(db),
+		portfolioRepo,
+		repository.NewHoldingRepository(db),
+	)
+	service := NewRebalancePlanService(
+		repository.NewRebalancePlanRepository(db),
+		portfolioRepo,
+		transactionService,
+	)
+
+	user, portfolio := createTestUserAndPortfolio(t, db)
+	return service, transactionService, db, user, portfolio
+}
+
+func newTestPlan() *models.RebalancePlan {
+	return &models.RebalancePlan{
+		Name: "Q3 rebalance",
+		Trades: []*models.RebalancePlanTrade{
+			{Symbol: " vti ", Side: models.TransactionTypeBuy, PlannedQuantity: decimal.NewFromInt(10), PlannedPrice: decimal.NewFromInt(250)},
+			{Symbol: "BND", Side: models.TransactionTypeBuy, PlannedQuantity: decimal.NewFromInt(20), PlannedPrice: decimal.NewFromInt(75)},
+		},
+	}
+}
+
+func TestRebalancePlanService_CreatePlan(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, _, user, portfolio := setupRebalancePlanServiceTest(t)
+
+	plan, err := service.CreatePlan(ctx, portfolio.ID.String(), user.ID.String(), newTestPlan())
+	require.NoError(t, err)
+	assert.Equal(t, portfolio.ID, plan.PortfolioID)
+	assert.Equal(t, models.RebalancePlanStatusActive, plan.Status)
+	assert.Equal(t, "VTI", plan.Trades[0].Symbol)
+
+	plans, err := service.GetPlans(ctx, portfolio.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	assert.Len(t, plans, 1)
+
+	_, err = service.CreatePlan(ctx, portfolio.ID.String(), user.ID.String(), &models.RebalancePlan{Name: "Empty"})
+	assert.Equal(t, models.ErrRebalancePlanNoTrades, err)
+
+	_, err = service.CreatePlan(ctx, portfolio.ID.String(), uuid.New().String(), newTestPlan())
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+}
+
+func TestRebalancePlanService_ExecuteTrade(t *testing.T) {
+	ctx := context.Background()
+
+	service, transactionService, _, user, portfolio := setupRebalancePlanServiceTest(t)
+	portfolioID, userID := portfolio.ID.String(), user.ID.String()
+
+	plan, err := service.CreatePlan(ctx, portfolioID, userID, newTestPlan())
+	require.NoError(t, err)
+	planID := plan.ID.String()
+	date := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+
+	// Record a new fill
+	plan, err = service.ExecuteTrade(ctx, planID, plan.Trades[0].ID.String(), portfolioID, userID, TradeFill{
+		Date:       date,
+		Quantity:   decimal.NewFromInt(10),
+		Price:      decimal.NewFromFloat(251.5),
+		Commission: decimal.NewFromInt(1),
+	})
+	require.NoError(t, err)
+	trade := plan.Trades[0]
+	assert.Equal(t, models.RebalanceTradeStatusExecuted, trade.Status)
+	require.NotNil(t, trade.TransactionID)
+	assert.True(t, decimal.NewFromInt(15).Equal(trade.AmountDrift()))
+	assert.Equal(t, models.RebalancePlanStatusActive, plan.Status)
+
+	transactions, err := transactionService.GetByPortfolioIDAndSymbol(ctx, portfolioID, "VTI", userID)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Contains(t, transactions[0].Notes, "Q3 rebalance")
+
+	_, err = service.ExecuteTrade(ctx, planID, trade.ID.String(), portfolioID, userID, TradeFill{
+		Date: date, Quantity: decimal.NewFromInt(1), Price: decimal.NewFromInt(1),
+	})
+	assert.Equal(t, models.ErrRebalanceTradeNotPending, err)
+
+	// Link an imported fill; a mismatched transaction is rejected
+	wrongSymbol, err := transactionService.Create(ctx, portfolioID, userID, models.TransactionTypeBuy, "AGG",
+		date, decimal.NewFromInt(18), decimal.NewFromInt(74), decimal.Zero, "", "imported", "")
+	require.NoError(t, err)
+	_, err = service.ExecuteTrade(ctx, planID, plan.Trades[1].ID.String(), portfolioID, userID, TradeFill{
+		TransactionID: wrongSymbol.ID.String(),
+	})
+	assert.Equal(t, models.ErrRebalanceFillMismatch, err)
+
+	imported, err := transactionService.Create(ctx, portfolioID, userID, models.TransactionTypeBuy, "BND",
+		date, decimal.NewFromInt(18), decimal.NewFromInt(74), decimal.Zero, "", "imported", "")
+	require.NoError(t, err)
+	plan, err = service.ExecuteTrade(ctx, planID, plan.Trades[1].ID.String(), portfolioID, userID, TradeFill{
+		TransactionID: imported.ID.String(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, imported.ID, *plan.Trades[1].TransactionID)
+	assert.True(t, decimal.NewFromInt(-2).Equal(plan.Trades[1].QuantityDrift()))
+
+	// All trades done completes the plan
+	assert.Equal(t, models.RebalancePlanStatusCompleted, plan.Status)
+	assert.NotNil(t, plan.ClosedAt)
+
+	_, err = service.SkipTrade(ctx, planID, plan.Trades[1].ID.String(), portfolioID, userID)
+	assert.Equal(t, models.ErrRebalancePlanNotActive, err)
+}
+
+func TestRebalancePlanService_ExecuteTrade_InvalidFill(t *testing.T) {
+	ctx := context.Background()
+
+	service, transactionService, _, user, portfolio := setupRebalancePlanServiceTest(t)
+	portfolioID, userID := portfolio.ID.String(), user.ID.String()
+
+	plan, err := service.CreatePlan(ctx, portfolioID, userID, newTestPlan())
+	require.NoError(t, err)
+
+	_, err = service.ExecuteTrade(ctx, plan.ID.String(), plan.Trades[0].ID.String(), portfolioID, userID, TradeFill{
+		Date: time.Now().UTC(), Quantity: decimal.Zero, Price: decimal.NewFromInt(250),
+	})
+	assert.Equal(t, models.ErrInvalidQuantity, err)
+
+	_, err = service.ExecuteTrade(ctx, plan.ID.String(), uuid.New().String(), portfolioID, userID, TradeFill{})
+	assert.Equal(t, models.ErrRebalanceTradeNotFound, err)
+
+	transactions, err := transactionService.GetByPortfolioID(ctx, portfolioID, userID)
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func TestRebalancePlanService_SkipAndCancel(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, _, user, portfolio := setupRebalancePlanServiceTest(t)
+	portfolioID, userID := portfolio.ID.String(), user.ID.String()
+
+	plan, err := service.CreatePlan(ctx, portfolioID, userID, newTestPlan())
+	require.NoError(t, err)
+
+	plan, err = service.SkipTrade(ctx, plan.ID.String(), plan.Trades[0].ID.String(), portfolioID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RebalanceTradeStatusSkipped, plan.Trades[0].Status)
+	assert.Equal(t, models.RebalancePlanStatusActive, plan.Status)
+
+	plan, err = service.CancelPlan(ctx, plan.ID.String(), portfolioID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RebalancePlanStatusCancelled, plan.Status)
+
+	_, err = service.CancelPlan(ctx, plan.ID.String(), portfolioID, userID)
+	assert.Equal(t, models.ErrRebalancePlanNotActive, err)
+
+	require.NoError(t, service.DeletePlan(ctx, plan.ID.String(), portfolioID, userID))
+	_, err = service.GetPlan(ctx, plan.ID.String(), portfolioID, userID)
+	assert.Equal(t, models.ErrRebalancePlanNotFound, err)
+}
+
+// staticTradingRestrictions reports the symbols it holds as restricted
+type staticTradingRestrictions map[string]*dto.SymbolTradingStatus
+
+func (r staticTradingRestrictions) GetTradingRestrictions(_ context.Context, symbols []string) map[string]*dto.SymbolTradingStatus {
+	found := make(map[string]*dto.SymbolTradingStatus)
+	for _, symbol := range symbols {
+		if status, ok := r[symbol]; ok {
+			found[symbol] = status
+		}
+	}
+	return found
+}
+
+func TestRebalancePlanService_TradingRestrictions(t *testing.T) {
+	ctx := context.Background()
+
+	_, transactionService, db, user, portfolio := setupRebalancePlanServiceTest(t)
+	portfolioID, userID := portfolio.ID.String(), user.ID.String()
+	restrictions := staticTradingRestrictions{}
+	service := NewRebalancePlanServiceWithTradingRestrictions(
+		repository.NewRebalancePlanRepository(db),
+		repository.NewPortfolioRepository(db),
+		transactionService,
+		restrictions,
+	)
+
+	restrictions["BND"] = &dto.SymbolTradingStatus{Symbol: "BND", Status: dto.TradingStatusHalted}
+	_, err := service.CreatePlan(ctx, portfolioID, userID, newTestPlan())
+	assert.ErrorIs(t, err, models.ErrRebalanceSymbolRestricted)
+	assert.Contains(t, err.Error(), "BND")
+
+	delete(restrictions, "BND")
+	plan, err := service.CreatePlan(ctx, portfolioID, userID, newTestPlan())
+	require.NoError(t, err)
+
+	// A halt after planning blocks recording a new fill but not linking an existing one
+	date := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	restrictions["BND"] = &dto.SymbolTradingStatus{Symbol: "BND", Status: dto.TradingStatusSuspended}
+	_, err = service.ExecuteTrade(ctx, plan.ID.String(), plan.Trades[1].ID.String(), portfolioID, userID, TradeFill{
+		Date: date, Quantity: decimal.NewFromInt(20), Price: decimal.NewFromInt(75),
+	})
+	assert.ErrorIs(t, err, models.ErrRebalanceSymbolRestricted)
+
+	imported, err := transactionService.Create(ctx, portfolioID, userID, models.TransactionTypeBuy, "BND",
+		date, decimal.NewFromInt(20), decimal.NewFromInt(75), decimal.Zero, "", "imported", "")
+	require.NoError(t, err)
+	plan, err = service.ExecuteTrade(ctx, plan.ID.String(), plan.Trades[1].ID.String(), portfolioID, userID, TradeFill{
+		TransactionID: imported.ID.String(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.RebalanceTradeStatusExecuted, plan.Trades[1].Status)
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/services/csv_parsers"
+)
+
+// reconciliationPriceDecimals is the precision adjustments are priced at, that of the
+// transaction price column
+const reconciliationPriceDecimals = 8
+
+// ReconciliationService defines the interface for reconciling portfolios with statements of
+// the positions their accounts hold
+type ReconciliationService interface {
+	// Reconcile compares a position statement with the portfolio's holdings, creating
+	// adjustment transactions for the quantity discrepancies if asked to
+	Reconcile(ctx context.Context, portfolioID, userID string, req dto.ReconcileRequest) (*dto.ReconciliationReport, error)
+}
+
+// reconciliationService implements ReconciliationService interface
+type reconciliationService struct {
+	portfolioRepo        repository.PortfolioRepository
+	holdingRepo          repository.HoldingRepository
+	importService        CSVImportService
+	recalculationService PortfolioRecalculationService
+	parser               *csv_parsers.PositionStatementParser
+	now                  func() time.Time
+}
+
+// NewReconciliationService creates a new ReconciliationService instance. Adjustments are
+// imported as one import batch, then the portfolio is rebuilt from its ledger.
+func NewReconciliationService(
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	importService CSVImportService,
+	recalculationService PortfolioRecalculationService,
+) ReconciliationService {
+	return &reconciliationService{
+		portfolioRepo:        portfolioRepo,
+		holdingRepo:          holdingRepo,
+		importService:        importService,
+		recalculationService: recalculationService,
+		parser:               csv_parsers.NewPositionStatementParser(),
+		now:                  time.Now,
+	}
+}
+
+// Reconcile reconciles a portfolio with a position statement. The statement is taken to
+// list every position, so a holding it leaves out is a discrepancy. A statement with rows
+// that can't be read is rejected as a whole.
+func (s *reconciliationService) Reconcile(ctx context.Context, portfolioID, userID string, req dto.ReconcileRequest) (*dto.ReconciliationReport, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrPortfolioNotFound // Don't leak existence of other users' portfolios
+	}
+
+	positions := req.Positions
+	if req.CSVData != "" {
+		if positions, err = s.parseStatement(req.CSVData); err != nil {
+			return nil, err
+		}
+	}
+	statement, err := statementBySymbol(positions)
+	if err != nil {
+		return nil, err
+	}
+
+	holdings, err := s.holdingRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holdings: %w", err)
+	}
+
+	report := &dto.ReconciliationReport{
+		PortfolioID:   portfolio.ID,
+		ReconciledAt:  s.now().UTC(),
+		Discrepancies: []dto.PositionDiscrepancy{},
+	}
+	bySymbol := make(map[string]*models.Holding, len(holdings))
+	for _, holding := range holdings {
+		bySymbol[holding.Symbol] = holding
+	}
+
+	symbols := make([]string, 0, len(statement)+len(holdings))
+	for symbol := range statement {
+		symbols = append(symbols, symbol)
+	}
+	for _, holding := range holdings {
+		if _, listed := statement[holding.Symbol]; !listed && !holding.Quantity.IsZero() {
+			symbols = append(symbols, holding.Symbol)
+		}
+	}
+	sort.Strings(symbols)
+	report.Positions = len(symbols)
+
+	for _, symbol := range symbols {
+		discrepancy, found := comparePosition(symbol, statement[symbol], bySymbol[symbol])
+		if !found {
+			report.Matched++
+			continue
+		}
+		report.Discrepancies = append(report.Discrepancies, discrepancy)
+	}
+
+	if req.CreateAdjustments {
+		if err := s.createAdjustments(ctx, portfolio, userID, report); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// parseStatement reads a CSV position statement, raw or base64 encoded
+func (s *reconciliationService) parseStatement(csvData string) ([]dto.StatementPosition, error) {
+	data := []byte(csvData)
+	if isBase64(csvData) {
+		if decoded, err := base64.StdEncoding.DecodeString(csvData); err == nil {
+			data = decoded
+		}
+	}
+
+	positions, parseErrors, err := s.parser.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidPositionStatement, err)
+	}
+	if len(parseErrors) > 0 {
+		first := parseErrors[0]
+		return nil, fmt.Errorf("%w: line %d: %s: %s", models.ErrInvalidPositionStatement, first.Line, first.Field, first.Message)
+	}
+	return positions, nil
+}
+
+// statementBySymbol adds up a statement's positions by symbol. A symbol's cost basis is
+// known only if every position listing it has one.
+func statementBySymbol(positions []dto.StatementPosition) (map[string]dto.StatementPosition, error) {
+	statement := make(map[string]dto.StatementPosition, len(positions))
+	seen := make(map[string]bool, len(positions))
+	for _, position := range positions {
+		symbol := strings.ToUpper(strings.TrimSpace(position.Symbol))
+		if symbol == "" {
+			return nil, fmt.Errorf("%w: symbol is required", models.ErrInvalidPositionStatement)
+		}
+		if position.Quantity.IsNegative() || position.CostBasis != nil && position.CostBasis.IsNegative() {
+			return nil, fmt.Errorf("%w: %s: quantity and cost basis cannot be negative", models.ErrInvalidPositionStatement, symbol)
+		}
+
+		total, ok := statement[symbol]
+		if !ok {
+			total = dto.StatementPosition{Symbol: symbol, Quantity: decimal.Zero}
+		}
+		total.Quantity = total.Quantity.Add(position.Quantity)
+		switch {
+		case position.CostBasis == nil:
+			total.CostBasis = nil
+		case !seen[symbol]:
+			costBasis := *position.CostBasis
+			total.CostBasis = &costBasis
+		case total.CostBasis != nil:
+			costBasis := total.CostBasis.Add(*position.CostBasis)
+			total.CostBasis = &costBasis
+		}
+		seen[symbol] = true
+		statement[symbol] = total
+	}
+	return statement, nil
+}
+
+// comparePosition compares a symbol's statement position with its holding, either of which
+// may be missing, and reports whether they differ. Cost bases differ when they do once
+// rounded to the cent.
+func comparePosition(symbol string, position dto.StatementPosition, holding *models.Holding) (dto.PositionDiscrepancy, bool) {
+	discrepancy := dto.PositionDiscrepancy{
+		Symbol:            symbol,
+		StatementQuantity: decimal.Zero,
+		HoldingQuantity:   decimal.Zero,
+		HoldingCostBasis:  decimal.Zero,
+	}
+	if position.Symbol != "" {
+		discrepancy.StatementQuantity = position.Quantity
+		discrepancy.StatementCostBasis = position.CostBasis
+	}
+	if holding != nil {
+		discrepancy.HoldingQuantity = holding.Quantity
+		discrepancy.HoldingCostBasis = holding.CostBasis
+	}
+	discrepancy.QuantityDifference = discrepancy.StatementQuantity.Sub(discrepancy.HoldingQuantity)
+
+	differs := !discrepancy.QuantityDifference.IsZero()
+	if discrepancy.StatementCostBasis != nil {
+		difference := discrepancy.StatementCostBasis.Sub(discrepancy.HoldingCostBasis)
+		discrepancy.CostBasisDifference = &difference
+		if !difference.Round(2).IsZero() {
+			differs = true
+		}
+	}
+	if !differs {
+		return dto.PositionDiscrepancy{}, false
+	}
+
+	discrepancy.Adjustment = reconciliationAdjustment(discrepancy, holding)
+	return discrepancy, true
+}
+
+// reconciliationAdjustment returns the transaction that brings a holding's quantity in line
+// with the statement, or nil if there is none to make or nothing to price it at. Missing
+// shares are added as an opening balance priced at the cost basis the statement has beyond
+// the holding's, or at the holding's average cost; extra shares are sold at the holding's
+// average cost.
+func reconciliationAdjustment(discrepancy dto.PositionDiscrepancy, holding *models.Holding) *dto.ReconciliationAdjustment {
+	difference := discrepancy.QuantityDifference
+	switch {
+	case difference.IsPositive():
+		if discrepancy.CostBasisDifference != nil && !discrepancy.CostBasisDifference.IsNegative() {
+			return &dto.ReconciliationAdjustment{
+				Type:     models.TransactionTypeOpeningBalance,
+				Quantity: difference,
+				Price:    discrepancy.CostBasisDifference.DivRound(difference, reconciliationPriceDecimals),
+			}
+		}
+		if holding == nil || !holding.Quantity.IsPositive() {
+			return nil
+		}
+		return &dto.ReconciliationAdjustment{
+			Type:     models.TransactionTypeOpeningBalance,
+			Quantity: difference,
+			Price:    holding.AvgCostPrice.Round(reconciliationPriceDecimals),
+		}
+	case difference.IsNegative():
+		return &dto.ReconciliationAdjustment{
+			Type:     models.TransactionTypeSell,
+			Quantity: difference.Neg(),
+			Price:    holding.AvgCostPrice.Round(reconciliationPriceDecimals),
+		}
+	}
+	return nil
+}
+
+// createAdjustments imports the report's adjustments as one import batch dated today and
+// rebuilds the portfolio's holdings and tax lots from its ledger. Adjustments that can't be
+// imported are reported and leave their discrepancy unadjusted.
+func (s *reconciliationService) createAdjustments(ctx context.Context, portfolio *models.Portfolio, userID string, report *dto.ReconciliationReport) error {
+	year, month, day := report.ReconciledAt.Date()
+	date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	var transactions []dto.ImportTransactionRequest
+	for _, discrepancy := range report.Discrepancies {
+		adjustment := discrepancy.Adjustment
+		if adjustment == nil {
+			continue
+		}
+		currency := portfolio.BaseCurrency
+		if _, _, ok := models.CryptoPair(discrepancy.Symbol); ok {
+			currency = "" // Coin pairs are recorded in their quote currency
+		}
+		price := adjustment.Price
+		transactions = append(transactions, dto.ImportTransactionRequest{
+			Type:     adjustment.Type,
+			Symbol:   discrepancy.Symbol,
+			Date:     date,
+			Quantity: adjustment.Quantity,
+			Price:    &price,
+			Currency: currency,
+			Notes:    "Reconciliation adjustment",
+		})
+	}
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	result, _, err := importAndReplay(ctx, s.importService, s.recalculationService, portfolio.ID.String(), userID, dto.BulkImportRequest{
+		Format:       dto.ImportFormatReconciliation,
+		Transactions: transactions,
+		SkipInvalid:  true,
+		Notes:        "Reconciliation adjustments",
+	})
+	if err != nil {
+		return err
+	}
+	report.Errors = result.Errors
+	if result.SuccessCount == 0 {
+		return nil
+	}
+
+	batchID := result.BatchID
+	report.BatchID = &batchID
+	report.Adjusted = result.SuccessCount
+	return nil
+}
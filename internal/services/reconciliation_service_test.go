@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupReconciliationTest(t *testing.T) (*gorm.DB, ReconciliationService, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.Holding{}, &models.TaxLot{},
+	))
+
+	user := &models.User{Email: "reconcile@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	portfolio := &models.Portfolio{
+		UserID:          user.ID,
+		Name:            "Brokerage",
+		BaseCurrency:    "USD",
+		CostBasisMethod: models.CostBasisFIFO,
+	}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	importService := NewCSVImportService(repository.NewTransactionRepository(db), portfolioRepo, holdingRepo)
+	recalculationService := NewPortfolioRecalculationService(db)
+
+	// AAPL 100 at 50, MSFT 10 at 300 and VTI 5 at 200
+	opening := NewOpeningLotImportService(portfolioRepo, importService, recalculationService)
+	result, err := opening.Import(context.Background(), portfolio.ID.String(), user.ID.String(), dto.OpeningLotImportRequest{
+		CSVData: "Symbol,Quantity,Cost Basis,Purchase Date\nAAPL,100,5000,2020-01-02\nMSFT,10,3000,2020-01-02\nVTI,5,1000,2020-01-02\n",
+	})
+	require.NoError(t, err)
+	require.True(t, result.Result.Success, result.Result.Errors)
+
+	service := NewReconciliationService(portfolioRepo, holdingRepo, importService, recalculationService)
+	service.(*reconciliationService).now = func() time.Time { return time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC) }
+	return db, service, user, portfolio
+}
+
+func TestReconciliationService_Reconcile(t *testing.T) {
+	db, service, user, portfolio := setupReconciliationTest(t)
+	ctx := context.Background()
+
+	// AAPL matches, MSFT has two more shares, NVDA isn't held and VTI was sold off
+	csvData := "Symbol,Quantity,Cost Basis\nAAPL,60,3000\naapl,40,2000\nMSFT,12,3700\nNVDA,4,\n"
+	report, err := service.Reconcile(ctx, portfolio.ID.String(), user.ID.String(), dto.ReconcileRequest{CSVData: csvData})
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Positions)
+	assert.Equal(t, 1, report.Matched)
+	require.Len(t, report.Discrepancies, 3)
+	assert.Nil(t, report.BatchID)
+
+	msft := report.Discrepancies[0]
+	assert.Equal(t, "MSFT", msft.Symbol)
+	assert.True(t, msft.QuantityDifference.Equal(decimal.NewFromInt(2)))
+	require.NotNil(t, msft.CostBasisDifference)
+	assert.True(t, msft.CostBasisDifference.Equal(decimal.NewFromInt(700)))
+	require.NotNil(t, msft.Adjustment)
+	assert.Equal(t, models.TransactionTypeOpeningBalance, msft.Adjustment.Type)
+	assert.True(t, msft.Adjustment.Price.Equal(decimal.NewFromInt(350)), msft.Adjustment.Price.String())
+
+	// Nothing prices shares the statement has no cost basis for and the portfolio never held
+	nvda := report.Discrepancies[1]
+	assert.Equal(t, "NVDA", nvda.Symbol)
+	assert.Nil(t, nvda.CostBasisDifference)
+	assert.Nil(t, nvda.Adjustment)
+
+	vti := report.Discrepancies[2]
+	assert.Equal(t, "VTI", vti.Symbol)
+	assert.True(t, vti.QuantityDifference.Equal(decimal.NewFromInt(-5)))
+	require.NotNil(t, vti.Adjustment)
+	assert.Equal(t, models.TransactionTypeSell, vti.Adjustment.Type)
+	assert.True(t, vti.Adjustment.Price.Equal(decimal.NewFromInt(200)))
+
+	// Without adjustments nothing changes
+	var count int64
+	require.NoError(t, db.Model(&models.Transaction{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+
+	report, err = service.Reconcile(ctx, portfolio.ID.String(), user.ID.String(), dto.ReconcileRequest{CSVData: csvData, CreateAdjustments: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Adjusted)
+	require.NotNil(t, report.BatchID)
+	assert.Empty(t, report.Errors)
+
+	holdings := repository.NewHoldingRepository(db)
+	msftHolding, err := holdings.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "MSFT")
+	require.NoError(t, err)
+	assert.True(t, msftHolding.Quantity.Equal(decimal.NewFromInt(12)))
+	assert.True(t, msftHolding.CostBasis.Equal(decimal.NewFromInt(3700)), msftHolding.CostBasis.String())
+
+	// Only NVDA is left to explain
+	report, err = service.Reconcile(ctx, portfolio.ID.String(), user.ID.String(), dto.ReconcileRequest{CSVData: csvData})
+	require.NoError(t, err)
+	require.Len(t, report.Discrepancies, 1)
+	assert.Equal(t, "NVDA", report.Discrepancies[0].Symbol)
+
+	var adjustment models.Transaction
+	require.NoError(t, db.Where("symbol = ?", "VTI").Where("type = ?", models.TransactionTypeSell).First(&adjustment).Error)
+	assert.Equal(t, "2024-06-03", adjustment.Date.Format("2006-01-02"))
+	assert.Equal(t, "Reconciliation adjustment", adjustment.Notes)
+}
+
+func TestReconciliationService_Reconcile_Positions(t *testing.T) {
+	_, service, user, portfolio := setupReconciliationTest(t)
+	ctx := context.Background()
+
+	costBasis := decimal.NewFromInt(4990)
+	report, err := service.Reconcile(ctx, portfolio.ID.String(), user.ID.String(), dto.ReconcileRequest{Positions: []dto.StatementPosition{
+		{Symbol: "aapl", Quantity: decimal.NewFromInt(100), CostBasis: &costBasis},
+		{Symbol: "MSFT", Quantity: decimal.NewFromInt(10)},
+		{Symbol: "VTI", Quantity: decimal.NewFromInt(5)},
+	}})
+	require.NoError(t, err)
+
+	// A cost basis discrepancy alone has no adjustment
+	require.Len(t, report.Discrepancies, 1)
+	assert.Equal(t, "AAPL", report.Discrepancies[0].Symbol)
+	assert.True(t, report.Discrepancies[0].QuantityDifference.IsZero())
+	assert.True(t, report.Discrepancies[0].CostBasisDifference.Equal(decimal.NewFromInt(-10)))
+	assert.Nil(t, report.Discrepancies[0].Adjustment)
+	assert.Equal(t, 2, report.Matched)
+}
+
+func TestReconciliationService_Reconcile_Errors(t *testing.T) {
+	_, service, user, portfolio := setupReconciliationTest(t)
+	ctx := context.Background()
+
+	_, err := service.Reconcile(ctx, portfolio.ID.String(), user.ID.String(), dto.ReconcileRequest{CSVData: "Symbol,Quantity\nAAPL,lots\n"})
+	assert.ErrorIs(t, err, models.ErrInvalidPositionStatement)
+	assert.Contains(t, err.Error(), "line 2")
+
+	_, err = service.Reconcile(ctx, portfolio.ID.String(), user.ID.String(), dto.ReconcileRequest{CSVData: "Ticker,Cost\nAAPL,10\n"})
+	assert.ErrorIs(t, err, models.ErrInvalidPositionStatement)
+
+	_, err = service.Reconcile(ctx, portfolio.ID.String(), user.ID.String(), dto.ReconcileRequest{Positions: []dto.StatementPosition{
+		{Symbol: "AAPL", Quantity: decimal.NewFromInt(-1)},
+	}})
+	assert.ErrorIs(t, err, models.ErrInvalidPositionStatement)
+
+	_, err = service.Reconcile(ctx, portfolio.ID.String(), uuid.New().String(), dto.ReconcileRequest{CSVData: "Symbol,Quantity\nAAPL,100\n"})
+	assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+}
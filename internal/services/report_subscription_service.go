@@ -0,0 +1,415 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// digestTopMovers is how many symbols a digest lists as top movers
+const digestTopMovers = 5
+
+// ReportSubscriptionService defines the interface for managing performance digest
+// subscriptions and building the digests they send
+type ReportSubscriptionService interface {
+	Create(ctx context.Context, userID string, req *dto.CreateReportSubscriptionRequest) (*models.ReportSubscription, error)
+	List(ctx context.Context, userID string) ([]*models.ReportSubscription, error)
+	Get(ctx context.Context, id, userID string) (*models.ReportSubscription, error)
+	Update(ctx context.Context, id, userID string, req *dto.UpdateReportSubscriptionRequest) (*models.ReportSubscription, error)
+	Delete(ctx context.Context, id, userID string) error
+	Preview(ctx context.Context, id, userID string) (*dto.PerformanceDigest, error)
+	BuildDigest(ctx context.Context, subscription *models.ReportSubscription, start, end time.Time) (*dto.PerformanceDigest, error)
+	UnsubscribeToken(subscription *models.ReportSubscription) string
+	VerifyUnsubscribeToken(token string) (subscriptionID, userID string, err error)
+	Unsubscribe(ctx context.Context, token string) (*models.ReportSubscription, error)
+}
+
+// reportSubscriptionService implements ReportSubscriptionService interface
+type reportSubscriptionService struct {
+	subscriptionRepo repository.ReportSubscriptionRepository
+	portfolioRepo    repository.PortfolioRepository
+	transactionRepo  repository.TransactionRepository
+	snapshotRepo     repository.PerformanceSnapshotRepository
+	holdingRepo      repository.HoldingRepository
+	marketData       MarketDataService
+	settings         UserSettingsService
+	signingKey       []byte
+	now              func() time.Time
+}
+
+// NewReportSubscriptionService creates a new ReportSubscriptionService instance. The
+// unsubscribe links in digests are signed with a key derived from secret. Without market
+// data, digests list no top movers.
+func NewReportSubscriptionService(
+	subscriptionRepo repository.ReportSubscriptionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	holdingRepo repository.HoldingRepository,
+	marketData MarketDataService,
+	secret []byte,
+) ReportSubscriptionService {
+	return NewReportSubscriptionServiceWithSettings(
+		subscriptionRepo, portfolioRepo, transactionRepo, snapshotRepo, holdingRepo, marketData, nil, secret,
+	)
+}
+
+// NewReportSubscriptionServiceWithSettings creates a ReportSubscriptionService whose digests
+// are written in the locale of each user's settings, rather than in English
+func NewReportSubscriptionServiceWithSettings(
+	subscriptionRepo repository.ReportSubscriptionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	holdingRepo repository.HoldingRepository,
+	marketData MarketDataService,
+	settings UserSettingsService,
+	secret []byte,
+) ReportSubscriptionService {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("portfolios report unsubscribe"))
+
+	return &reportSubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		portfolioRepo:    portfolioRepo,
+		transactionRepo:  transactionRepo,
+		snapshotRepo:     snapshotRepo,
+		holdingRepo:      holdingRepo,
+		marketData:       marketData,
+		settings:         settings,
+		signingKey:       mac.Sum(nil),
+		now:              func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Create subscribes a user to a weekly or monthly digest of some or all of their portfolios
+func (s *reportSubscriptionService) Create(ctx context.Context, userID string, req *dto.CreateReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, models.ErrInvalidValue
+	}
+
+	portfolios, err := s.subscriptionPortfolios(ctx, userID, req.PortfolioIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription := &models.ReportSubscription{
+		UserID:     uid,
+		Frequency:  req.Frequency,
+		Enabled:    true,
+		Portfolios: portfolios,
+	}
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to create report subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// List retrieves a user's report subscriptions, oldest first
+func (s *reportSubscriptionService) List(ctx context.Context, userID string) ([]*models.ReportSubscription, error) {
+	return s.subscriptionRepo.FindByUserID(ctx, userID)
+}
+
+// Get retrieves one of a user's report subscriptions
+func (s *reportSubscriptionService) Get(ctx context.Context, id, userID string) (*models.ReportSubscription, error) {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, models.ErrReportSubscriptionNotFound
+	}
+	if subscription.UserID.String() != userID {
+		return nil, models.ErrReportSubscriptionNotFound
+	}
+
+	return subscription, nil
+}
+
+// Update changes a subscription's frequency, portfolios or whether it is enabled
+func (s *reportSubscriptionService) Update(ctx context.Context, id, userID string, req *dto.UpdateReportSubscriptionRequest) (*models.ReportSubscription, error) {
+	subscription, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Frequency != nil {
+		subscription.Frequency = *req.Frequency
+	}
+	if req.PortfolioIDs != nil {
+		portfolios, err := s.subscriptionPortfolios(ctx, userID, *req.PortfolioIDs)
+		if err != nil {
+			return nil, err
+		}
+		subscription.Portfolios = portfolios
+	}
+	if req.Enabled != nil {
+		subscription.Enabled = *req.Enabled
+	}
+	if err := subscription.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to update report subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// Delete removes one of a user's report subscriptions
+func (s *reportSubscriptionService) Delete(ctx context.Context, id, userID string) error {
+	if _, err := s.Get(ctx, id, userID); err != nil {
+		return err
+	}
+
+	return s.subscriptionRepo.Delete(ctx, id)
+}
+
+// Preview builds the digest the subscription would have sent for the last complete period
+func (s *reportSubscriptionService) Preview(ctx context.Context, id, userID string) (*dto.PerformanceDigest, error) {
+	subscription, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := subscription.DigestPeriod(s.now())
+	return s.BuildDigest(ctx, subscription, start, end)
+}
+
+// BuildDigest summarizes the subscription's portfolios over the half-open range [start, end):
+// each portfolio's value change from its performance snapshots and the income it received,
+// and the held symbols whose prices moved the most. Portfolios deleted since the
+// subscription was made are left out.
+func (s *reportSubscriptionService) BuildDigest(ctx context.Context, subscription *models.ReportSubscription, start, end time.Time) (*dto.PerformanceDigest, error) {
+	portfolios, err := s.digestPortfolios(ctx, subscription)
+	if err != nil {
+		return nil, err
+	}
+
+	locale := models.DefaultUserSettings(subscription.UserID).Locale
+	if s.settings != nil {
+		settings, err := s.settings.Get(ctx, subscription.UserID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load user settings: %w", err)
+		}
+		locale = settings.Locale
+	}
+
+	digest := &dto.PerformanceDigest{
+		Frequency:  subscription.Frequency,
+		Locale:     locale,
+		StartDate:  start,
+		EndDate:    end.AddDate(0, 0, -1),
+		Portfolios: make([]dto.DigestPortfolio, 0, len(portfolios)),
+		TopMovers:  []dto.DigestMover{},
+	}
+
+	quantities := make(map[string]decimal.Decimal)
+	for _, portfolio := range portfolios {
+		portfolioID := portfolio.ID.String()
+
+		transactions, err := s.transactionRepo.FindByPortfolioID(ctx, portfolioID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+		}
+		var inPeriod []*models.Transaction
+		heldBefore := false
+		for _, tx := range transactions {
+			switch {
+			case tx.Date.Before(start):
+				heldBefore = true
+			case tx.Date.Before(end):
+				inPeriod = append(inPeriod, tx)
+			}
+		}
+
+		performance, err := periodPerformance(ctx, s.snapshotRepo, portfolioID, start, end, heldBefore, inPeriod)
+		if err != nil {
+			return nil, err
+		}
+		entry := dto.DigestPortfolio{
+			ID:           portfolioID,
+			Name:         portfolio.Name,
+			BaseCurrency: portfolio.BaseCurrency,
+			Performance:  performance,
+			Income:       statementIncome(inPeriod).Total,
+		}
+		if performance.StartingValue != nil && performance.EndingValue != nil {
+			change := performance.EndingValue.Sub(*performance.StartingValue)
+			entry.ValueChange = &change
+		}
+		digest.Portfolios = append(digest.Portfolios, entry)
+
+		holdings, err := s.holdingRepo.FindByPortfolioID(ctx, portfolioID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve holdings: %w", err)
+		}
+		for _, holding := range holdings {
+			if holding.Quantity.IsPositive() {
+				quantities[holding.Symbol] = quantities[holding.Symbol].Add(holding.Quantity)
+			}
+		}
+	}
+
+	digest.TopMovers = s.topMovers(ctx, quantities, start, end)
+	return digest, nil
+}
+
+// digestPortfolios returns the portfolios a subscription covers that still exist and belong
+// to its user. A subscription without portfolios covers all of the user's.
+func (s *reportSubscriptionService) digestPortfolios(ctx context.Context, subscription *models.ReportSubscription) ([]*models.Portfolio, error) {
+	if len(subscription.Portfolios) == 0 {
+		portfolios, err := s.portfolioRepo.FindByUserID(ctx, subscription.UserID.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve portfolios: %w", err)
+		}
+		return portfolios, nil
+	}
+
+	portfolios := make([]*models.Portfolio, 0, len(subscription.Portfolios))
+	for _, link := range subscription.Portfolios {
+		portfolio, err := s.portfolioRepo.FindByID(ctx, link.PortfolioID.String())
+		if err != nil || portfolio.UserID != subscription.UserID {
+			continue
+		}
+		portfolios = append(portfolios, portfolio)
+	}
+	return portfolios, nil
+}
+
+// topMovers prices the held symbols at the close before the period and its last close, and
+// returns those whose price changed the most in either direction. Symbols without a price
+// at both ends are skipped.
+func (s *reportSubscriptionService) topMovers(ctx context.Context, quantities map[string]decimal.Decimal, start, end time.Time) []dto.DigestMover {
+	movers := []dto.DigestMover{}
+	if s.marketData == nil || len(quantities) == 0 {
+		return movers
+	}
+
+	symbols := make([]string, 0, len(quantities))
+	for symbol := range quantities {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	// Look back a week so the opening price is found across weekends and holidays
+	prices := NewHistoricalPricePrefetcher(s.marketData, start.AddDate(0, 0, -7), end)
+	if err := prices.Prefetch(ctx, symbols); err != nil {
+		return movers
+	}
+
+	for _, symbol := range symbols {
+		opening, ok := prices.PriceOn(ctx, symbol, start.Add(-time.Nanosecond))
+		if !ok || !opening.IsPositive() {
+			continue
+		}
+		closing, ok := prices.PriceOn(ctx, symbol, end.Add(-time.Nanosecond))
+		if !ok {
+			continue
+		}
+		change := closing.Sub(opening)
+		movers = append(movers, dto.DigestMover{
+			Symbol:        symbol,
+			ChangePercent: change.Div(opening).Mul(decimal.NewFromInt(100)).Round(2),
+			ValueChange:   change.Mul(quantities[symbol]).Round(2),
+		})
+	}
+
+	sort.SliceStable(movers, func(i, j int) bool {
+		return movers[i].ChangePercent.Abs().GreaterThan(movers[j].ChangePercent.Abs())
+	})
+	if len(movers) > digestTopMovers {
+		movers = movers[:digestTopMovers]
+	}
+	return movers
+}
+
+// subscriptionPortfolios checks that every portfolio belongs to the user and links them
+func (s *reportSubscriptionService) subscriptionPortfolios(ctx context.Context, userID string, portfolioIDs []string) ([]*models.ReportSubscriptionPortfolio, error) {
+	links := make([]*models.ReportSubscriptionPortfolio, 0, len(portfolioIDs))
+	seen := make(map[uuid.UUID]bool)
+	for _, portfolioID := range portfolioIDs {
+		portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+		if err != nil {
+			return nil, models.ErrPortfolioNotFound
+		}
+		if portfolio.UserID.String() != userID {
+			return nil, models.ErrUnauthorizedAccess
+		}
+		if seen[portfolio.ID] {
+			continue
+		}
+		seen[portfolio.ID] = true
+		links = append(links, &models.ReportSubscriptionPortfolio{PortfolioID: portfolio.ID})
+	}
+	return links, nil
+}
+
+// UnsubscribeToken returns the token for a digest's unsubscribe link. It names the
+// subscription and its user and is signed, so the link works without signing in.
+func (s *reportSubscriptionService) UnsubscribeToken(subscription *models.ReportSubscription) string {
+	payload := subscription.ID.String() + "." + subscription.UserID.String()
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.sign(payload))
+}
+
+// VerifyUnsubscribeToken checks an unsubscribe token's signature and returns the
+// subscription and user it names
+func (s *reportSubscriptionService) VerifyUnsubscribeToken(token string) (subscriptionID, userID string, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", "", models.ErrInvalidUnsubscribeToken
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, s.sign(parts[0]+"."+parts[1])) {
+		return "", "", models.ErrInvalidUnsubscribeToken
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// Unsubscribe disables the subscription an unsubscribe token names. The subscription is
+// kept so the user can enable it again.
+func (s *reportSubscriptionService) Unsubscribe(ctx context.Context, token string) (*models.ReportSubscription, error) {
+	subscriptionID, userID, err := s.VerifyUnsubscribeToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := s.Get(ctx, subscriptionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !subscription.Enabled {
+		return subscription, nil
+	}
+
+	subscription.Enabled = false
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to update report subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// sign computes the signature of an unsubscribe token's payload
+func (s *reportSubscriptionService) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupReportSubscriptionTest(t *testing.T, marketData MarketDataService) (*gorm.DB, *reportSubscriptionService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{},
+		&models.Holding{}, &models.PerformanceSnapshot{},
+		&models.ReportSubscription{}, &models.ReportSubscriptionPortfolio{}))
+
+	service := NewReportSubscriptionService(
+		repository.NewReportSubscriptionRepository(db),
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+		repository.NewHoldingRepository(db),
+		marketData,
+		[]byte("test-secret"),
+	).(*reportSubscriptionService)
+	// Wednesday, so the last complete week is January 6-12
+	service.now = func() time.Time { return time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC) }
+
+	return db, service
+}
+
+func createReportSubscriptionPortfolio(t *testing.T, db *gorm.DB, email, name string) (*models.User, *models.Portfolio) {
+	user := &models.User{Email: email, PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: name, BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+	return user, portfolio
+}
+
+func TestReportSubscriptionService_Manage(t *testing.T) {
+	db, service := setupReportSubscriptionTest(t, nil)
+	ctx := context.Background()
+
+	user, portfolio := createReportSubscriptionPortfolio(t, db, "investor@example.com", "Retirement")
+	other, otherPortfolio := createReportSubscriptionPortfolio(t, db, "other@example.com", "Other")
+
+	subscription, err := service.Create(ctx, user.ID.String(), &dto.CreateReportSubscriptionRequest{
+		Frequency:    models.ReportFrequencyWeekly,
+		PortfolioIDs: []string{portfolio.ID.String(), portfolio.ID.String()},
+	})
+	require.NoError(t, err)
+	assert.True(t, subscription.Enabled)
+	assert.Equal(t, []string{portfolio.ID.String()}, subscription.PortfolioIDs())
+
+	t.Run("another user's portfolio", func(t *testing.T) {
+		_, err := service.Create(ctx, user.ID.String(), &dto.CreateReportSubscriptionRequest{
+			Frequency:    models.ReportFrequencyWeekly,
+			PortfolioIDs: []string{otherPortfolio.ID.String()},
+		})
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+
+		_, err = service.Create(ctx, user.ID.String(), &dto.CreateReportSubscriptionRequest{
+			Frequency:    models.ReportFrequencyWeekly,
+			PortfolioIDs: []string{uuid.New().String()},
+		})
+		assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+	})
+
+	t.Run("another user's subscription", func(t *testing.T) {
+		_, err := service.Get(ctx, subscription.ID.String(), other.ID.String())
+		assert.ErrorIs(t, err, models.ErrReportSubscriptionNotFound)
+
+		err = service.Delete(ctx, subscription.ID.String(), other.ID.String())
+		assert.ErrorIs(t, err, models.ErrReportSubscriptionNotFound)
+	})
+
+	monthly := models.ReportFrequencyMonthly
+	all := []string{}
+	updated, err := service.Update(ctx, subscription.ID.String(), user.ID.String(), &dto.UpdateReportSubscriptionRequest{
+		Frequency:    &monthly,
+		PortfolioIDs: &all,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.ReportFrequencyMonthly, updated.Frequency)
+	assert.Empty(t, updated.PortfolioIDs())
+
+	list, err := service.List(ctx, user.ID.String())
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, models.ReportFrequencyMonthly, list[0].Frequency)
+
+	require.NoError(t, service.Delete(ctx, subscription.ID.String(), user.ID.String()))
+	_, err = service.Get(ctx, subscription.ID.String(), user.ID.String())
+	assert.ErrorIs(t, err, models.ErrReportSubscriptionNotFound)
+}
+
+func TestReportSubscriptionService_BuildDigest(t *testing.T) {
+	marketData := new(MockMarketDataService)
+	marketData.On("GetHistoricalPrices", "AAPL", mock.Anything, mock.Anything).Return([]*HistoricalPrice{
+		{Date: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Close: decimal.NewFromInt(200)},
+		{Date: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), Close: decimal.NewFromInt(190)},
+	}, nil)
+	marketData.On("GetHistoricalPrices", "VTI", mock.Anything, mock.Anything).Return([]*HistoricalPrice{
+		{Date: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Close: decimal.NewFromInt(100)},
+		{Date: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC), Close: decimal.NewFromInt(110)},
+	}, nil)
+
+	db, service := setupReportSubscriptionTest(t, marketData)
+	ctx := context.Background()
+
+	user, portfolio := createReportSubscriptionPortfolio(t, db, "investor@example.com", "Retirement")
+
+	for date, value := range map[time.Time]int64{
+		time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC):  1000,
+		time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC): 1100,
+	} {
+		require.NoError(t, db.Create(&models.PerformanceSnapshot{
+			PortfolioID: portfolio.ID,
+			Date:        date,
+			TotalValue:  decimal.NewFromInt(value),
+		}).Error)
+	}
+
+	price := decimal.NewFromInt(50)
+	for _, tx := range []*models.Transaction{
+		{Type: models.TransactionTypeBuy, Symbol: "VTI", Date: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(10), Price: &price},
+		{Type: models.TransactionTypeBuy, Symbol: "VTI", Date: time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(1), Price: &price},
+		{Type: models.TransactionTypeDividend, Symbol: "VTI", Date: time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(5)},
+	} {
+		tx.PortfolioID = portfolio.ID
+		require.NoError(t, db.Create(tx).Error)
+	}
+	for symbol, quantity := range map[string]int64{"VTI": 11, "AAPL": 2} {
+		require.NoError(t, db.Create(&models.Holding{
+			PortfolioID:  portfolio.ID,
+			Symbol:       symbol,
+			Quantity:     decimal.NewFromInt(quantity),
+			CostBasis:    decimal.Zero,
+			AvgCostPrice: decimal.Zero,
+		}).Error)
+	}
+
+	subscription, err := service.Create(ctx, user.ID.String(), &dto.CreateReportSubscriptionRequest{Frequency: models.ReportFrequencyWeekly})
+	require.NoError(t, err)
+
+	digest, err := service.Preview(ctx, subscription.ID.String(), user.ID.String())
+	require.NoError(t, err)
+
+	assert.Equal(t, time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), digest.StartDate)
+	assert.Equal(t, time.Date(2025, 1, 12, 0, 0, 0, 0, time.UTC), digest.EndDate)
+
+	require.Len(t, digest.Portfolios, 1)
+	entry := digest.Portfolios[0]
+	assert.Equal(t, "Retirement", entry.Name)
+	require.NotNil(t, entry.ValueChange)
+	assert.True(t, decimal.NewFromInt(100).Equal(*entry.ValueChange))
+	assert.True(t, decimal.NewFromInt(50).Equal(entry.Performance.NetCashFlow))
+	require.NotNil(t, entry.Performance.InvestmentGain)
+	assert.True(t, decimal.NewFromInt(50).Equal(*entry.Performance.InvestmentGain))
+	assert.True(t, decimal.NewFromInt(5).Equal(entry.Income))
+
+	// VTI moved 10% and AAPL 5%, so VTI is listed first
+	require.Len(t, digest.TopMovers, 2)
+	assert.Equal(t, "VTI", digest.TopMovers[0].Symbol)
+	assert.True(t, decimal.NewFromInt(10).Equal(digest.TopMovers[0].ChangePercent))
+	assert.True(t, decimal.NewFromInt(110).Equal(digest.TopMovers[0].ValueChange))
+	assert.Equal(t, "AAPL", digest.TopMovers[1].Symbol)
+	assert.True(t, decimal.NewFromInt(-5).Equal(digest.TopMovers[1].ChangePercent))
+	assert.True(t, decimal.NewFromInt(-20).Equal(digest.TopMovers[1].ValueChange))
+}
+
+func TestReportSubscriptionService_Unsubscribe(t *testing.T) {
+	db, service := setupReportSubscriptionTest(t, nil)
+	ctx := context.Background()
+
+	user, _ := createReportSubscriptionPortfolio(t, db, "investor@example.com", "Retirement")
+	subscription, err := service.Create(ctx, user.ID.String(), &dto.CreateReportSubscriptionRequest{Frequency: models.ReportFrequencyMonthly})
+	require.NoError(t, err)
+
+	token := service.UnsubscribeToken(subscription)
+
+	subscriptionID, userID, err := service.VerifyUnsubscribeToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, subscription.ID.String(), subscriptionID)
+	assert.Equal(t, user.ID.String(), userID)
+
+	t.Run("tampered tokens are rejected", func(t *testing.T) {
+		for _, tampered := range []string{
+			"",
+			"not-a-token",
+			uuid.New().String() + "." + user.ID.String() + token[len(token)-44:],
+			token + "x",
+		} {
+			_, _, err := service.VerifyUnsubscribeToken(tampered)
+			assert.ErrorIs(t, err, models.ErrInvalidUnsubscribeToken, tampered)
+		}
+	})
+
+	unsubscribed, err := service.Unsubscribe(ctx, token)
+	require.NoError(t, err)
+	assert.False(t, unsubscribed.Enabled)
+
+	// The link keeps working after the subscription is disabled
+	_, err = service.Unsubscribe(ctx, token)
+	require.NoError(t, err)
+
+	found, err := service.Get(ctx, subscription.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	assert.False(t, found.Enabled)
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// SecurityService defines the interface for finding securities by symbol or name
+type SecurityService interface {
+	Search(ctx context.Context, query string, limit int) ([]*models.Security, error)
+	Lookup(ctx context.Context, symbol string) (*models.Security, error)
+}
+
+// securityService implements SecurityService interface
+type securityService struct {
+	securityRepo repository.SecurityRepository
+	marketData   MarketDataService
+}
+
+// NewSecurityService creates a new SecurityService instance. Searches go to the market data
+// provider and their results are stored, so the stored securities can still be searched when
+// the provider is unavailable. marketData may be nil when market data is disabled, in which
+// case only stored securities are found.
+func NewSecurityService(securityRepo repository.SecurityRepository, marketData MarketDataService) SecurityService {
+	return &securityService{
+		securityRepo: securityRepo,
+		marketData:   marketData,
+	}
+}
+
+// Search finds securities whose symbol or name matches query. When the provider's search
+// fails, stored securities are searched instead, and the provider's error is only returned
+// if none of them match.
+func (s *securityService) Search(ctx context.Context, query string, limit int) ([]*models.Security, error) {
+	query, err := models.NormalizeSecurityQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = models.DefaultSecuritySearchResults
+	}
+	limit = min(limit, models.MaxSecuritySearchResults)
+
+	var searchErr error
+	if s.marketData != nil {
+		securities, err := s.search(ctx, query)
+		if err == nil {
+			return securities[:min(limit, len(securities))], nil
+		}
+		searchErr = err
+	}
+
+	securities, err := s.securityRepo.Search(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(securities) == 0 && searchErr != nil {
+		return nil, searchErr
+	}
+	return securities, nil
+}
+
+// Lookup finds a security by its symbol, searching the provider for symbols that aren't
+// stored yet
+func (s *securityService) Lookup(ctx context.Context, symbol string) (*models.Security, error) {
+	symbol = models.NormalizeTicker(symbol)
+	if symbol == "" {
+		return nil, models.ErrInvalidSymbol
+	}
+
+	security, err := s.securityRepo.FindBySymbol(ctx, symbol)
+	if err == nil || !errors.Is(err, models.ErrSecurityNotFound) || s.marketData == nil {
+		return security, err
+	}
+
+	securities, err := s.search(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	for _, security := range securities {
+		if security.Symbol == symbol {
+			return security, nil
+		}
+	}
+	return nil, models.ErrSecurityNotFound
+}
+
+// search searches the provider and stores the securities it finds
+func (s *securityService) search(ctx context.Context, query string) ([]*models.Security, error) {
+	securities, err := s.marketData.SearchSymbols(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.securityRepo.Upsert(ctx, dedupeSecurities(securities)); err != nil {
+		return nil, fmt.Errorf("failed to store securities: %w", err)
+	}
+	return securities, nil
+}
+
+// dedupeSecurities drops repeated symbols, which a single upsert can't write twice
+func dedupeSecurities(securities []*models.Security) []*models.Security {
+	seen := make(map[string]bool, len(securities))
+	unique := make([]*models.Security, 0, len(securities))
+	for _, security := range securities {
+		if seen[security.Symbol] {
+			continue
+		}
+		seen[security.Symbol] = true
+		unique = append(unique, security)
+	}
+	return unique
+}
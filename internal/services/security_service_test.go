@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupSecurityTest(t *testing.T, marketData MarketDataService) (repository.SecurityRepository, SecurityService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.Security{}))
+
+	securityRepo := repository.NewSecurityRepository(db)
+	return securityRepo, NewSecurityService(securityRepo, marketData)
+}
+
+func TestSecurityService_SearchStoresResults(t *testing.T) {
+	marketData := new(MockMarketDataService)
+	securityRepo, service := setupSecurityTest(t, marketData)
+	ctx := context.Background()
+
+	marketData.On("SearchSymbols", "apple").Return([]*models.Security{
+		{Symbol: "AAPL", Name: "Apple Inc", Type: "Equity", Currency: "USD"},
+		{Symbol: "APLE", Name: "Apple Hospitality REIT Inc", Type: "Equity", Currency: "USD"},
+		{Symbol: "AAPL", Name: "Apple Inc", Type: "Equity", Currency: "USD"},
+	}, nil).Once()
+
+	securities, err := service.Search(ctx, "  apple ", 2)
+	require.NoError(t, err)
+	require.Len(t, securities, 2)
+	assert.Equal(t, "AAPL", securities[0].Symbol)
+
+	stored, err := securityRepo.FindBySymbol(ctx, "APLE")
+	require.NoError(t, err)
+	assert.Equal(t, "Apple Hospitality REIT Inc", stored.Name)
+
+	// When the provider fails, stored securities are searched instead
+	marketData.On("SearchSymbols", "AAP").Return(nil, models.ErrQuotaExceeded).Once()
+	securities, err = service.Search(ctx, "AAP", 0)
+	require.NoError(t, err)
+	require.Len(t, securities, 1)
+	assert.Equal(t, "AAPL", securities[0].Symbol)
+
+	// ...and its error is returned if none match
+	marketData.On("SearchSymbols", "MSFT").Return(nil, models.ErrQuotaExceeded).Once()
+	_, err = service.Search(ctx, "MSFT", 0)
+	assert.True(t, errors.Is(err, models.ErrQuotaExceeded))
+
+	_, err = service.Search(ctx, " ", 0)
+	assert.Equal(t, models.ErrInvalidSecurityQuery, err)
+}
+
+func TestSecurityService_Lookup(t *testing.T) {
+	marketData := new(MockMarketDataService)
+	_, service := setupSecurityTest(t, marketData)
+	ctx := context.Background()
+
+	marketData.On("SearchSymbols", "VTI").Return([]*models.Security{
+		{Symbol: "VTI", Name: "Vanguard Total Stock Market ETF", Type: "ETF", Currency: "USD"},
+		{Symbol: "VTIP", Name: "Vanguard Short-Term Inflation-Protected Securities ETF", Type: "ETF", Currency: "USD"},
+	}, nil).Once()
+	marketData.On("SearchSymbols", "NOPE").Return([]*models.Security{}, nil).Once()
+
+	security, err := service.Lookup(ctx, "vti")
+	require.NoError(t, err)
+	assert.Equal(t, "Vanguard Total Stock Market ETF", security.Name)
+
+	// Found securities are stored, so looking them up again doesn't search the provider
+	security, err = service.Lookup(ctx, "VTIP")
+	require.NoError(t, err)
+	assert.Equal(t, "ETF", security.Type)
+
+	_, err = service.Lookup(ctx, "NOPE")
+	assert.Equal(t, models.ErrSecurityNotFound, err)
+	marketData.AssertExpectations(t)
+
+	// Without market data only stored securities are found
+	_, service = setupSecurityTest(t, nil)
+	_, err = service.Lookup(ctx, "VTI")
+	assert.Equal(t, models.ErrSecurityNotFound, err)
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// SimulationService defines the interface for Monte Carlo simulations of withdrawing from a
+// portfolio in retirement. Simulations run in the background and their results are stored.
+type SimulationService interface {
+	// Create stores a simulation with its parameters resolved and queues the job that runs it
+	Create(ctx context.Context, portfolioID, userID string, req *dto.SimulationRequest) (*models.QueuedJob, error)
+
+	// Run runs a stored simulation and stores its result
+	Run(ctx context.Context, simulationID string) (*models.Simulation, error)
+
+	List(ctx context.Context, portfolioID, userID string) ([]*models.Simulation, error)
+	Get(ctx context.Context, portfolioID, simulationID, userID string) (*models.Simulation, error)
+	Delete(ctx context.Context, portfolioID, simulationID, userID string) error
+}
+
+// simulationService implements SimulationService interface
+type simulationService struct {
+	simulationRepo  repository.SimulationRepository
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.PerformanceSnapshotRepository
+	jobQueue        JobQueueService
+	now             func() time.Time
+}
+
+// NewSimulationService creates a new SimulationService instance
+func NewSimulationService(
+	simulationRepo repository.SimulationRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	jobQueue JobQueueService,
+) SimulationService {
+	return &simulationService{
+		simulationRepo:  simulationRepo,
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+		jobQueue:        jobQueue,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *simulationService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// Create resolves the simulation's parameters, measuring the return and volatility from the
+// portfolio's snapshots unless both are given, so the stored simulation can be run and
+// repeated without looking at the portfolio again
+func (s *simulationService) Create(ctx context.Context, portfolioID, userID string, req *dto.SimulationRequest) (*models.QueuedJob, error) {
+	if s.jobQueue == nil {
+		return nil, models.ErrJobQueueUnavailable
+	}
+
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateSimulation(req); err != nil {
+		return nil, err
+	}
+
+	startingValue, err := latestSnapshotValue(ctx, s.snapshotRepo, portfolioID, req.StartingValue)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := dto.SimulationParameters{
+		AnnualWithdrawal: req.AnnualWithdrawal,
+		Years:            req.Years,
+		InflationRate:    req.InflationRate,
+		StartingValue:    startingValue,
+		Paths:            req.Paths,
+	}
+	if parameters.Paths == 0 {
+		parameters.Paths = models.DefaultSimulationPaths
+	}
+	if req.Seed != nil {
+		parameters.Seed = *req.Seed
+	} else {
+		parameters.Seed = rand.Int64()
+	}
+
+	if req.ExpectedReturn == nil || req.Volatility == nil {
+		returns, err := snapshotMonthlyReturns(ctx, s.snapshotRepo, s.transactionRepo, portfolioID, s.now())
+		if err != nil {
+			return nil, err
+		}
+		if len(returns) < models.MinProjectionHistoryMonths {
+			return nil, models.ErrInsufficientSimulationHistory
+		}
+		parameters.ExpectedReturn, parameters.Volatility = annualizeMonthlyReturns(returns)
+		parameters.HistoryMonths = len(returns)
+	}
+	if req.ExpectedReturn != nil {
+		parameters.ExpectedReturn = *req.ExpectedReturn
+	}
+	if req.Volatility != nil {
+		parameters.Volatility = *req.Volatility
+	}
+
+	simulation := &models.Simulation{PortfolioID: portfolio.ID}
+	if err := simulation.SetParameters(parameters); err != nil {
+		return nil, err
+	}
+	if err := s.simulationRepo.Create(ctx, simulation); err != nil {
+		return nil, err
+	}
+
+	job, err := s.jobQueue.Enqueue(ctx, userID, portfolioID, models.QueuedJobTypeSimulation, dto.SimulationJob{SimulationID: simulation.ID})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.simulationRepo.SetJobID(ctx, simulation.ID, job.ID); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Run simulates the stored simulation's paths and stores the result, or the error if it
+// couldn't be run
+func (s *simulationService) Run(ctx context.Context, simulationID string) (*models.Simulation, error) {
+	simulation, err := s.simulationRepo.FindByID(ctx, simulationID)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation.Status = models.QueuedJobStatusRunning
+	if err := s.simulationRepo.Update(ctx, simulation); err != nil {
+		return nil, err
+	}
+
+	var parameters dto.SimulationParameters
+	result, err := func() (*dto.SimulationResult, error) {
+		if err := simulation.DecodeParameters(&parameters); err != nil {
+			return nil, err
+		}
+		return simulate(ctx, parameters)
+	}()
+
+	finishedAt := s.now()
+	simulation.FinishedAt = &finishedAt
+	if err == nil {
+		err = simulation.SetResult(result)
+	}
+	if err != nil {
+		simulation.Status = models.QueuedJobStatusFailed
+		simulation.Error = err.Error()
+		// The job fails with the original error even if storing it fails
+		_ = s.simulationRepo.Update(context.WithoutCancel(ctx), simulation)
+		return nil, err
+	}
+
+	simulation.Status = models.QueuedJobStatusSucceeded
+	if err := s.simulationRepo.Update(ctx, simulation); err != nil {
+		return nil, err
+	}
+	return simulation, nil
+}
+
+// List retrieves a portfolio's simulations, newest first
+func (s *simulationService) List(ctx context.Context, portfolioID, userID string) ([]*models.Simulation, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+	return s.simulationRepo.FindByPortfolioID(ctx, portfolioID)
+}
+
+// Get retrieves one of a portfolio's simulations
+func (s *simulationService) Get(ctx context.Context, portfolioID, simulationID, userID string) (*models.Simulation, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	simulation, err := s.simulationRepo.FindByID(ctx, simulationID)
+	if err != nil {
+		return nil, models.ErrSimulationNotFound
+	}
+	if simulation.PortfolioID.String() != portfolioID {
+		return nil, models.ErrSimulationNotFound
+	}
+	return simulation, nil
+}
+
+// Delete removes one of a portfolio's simulations. A queued job for it fails when it runs.
+func (s *simulationService) Delete(ctx context.Context, portfolioID, simulationID, userID string) error {
+	simulation, err := s.Get(ctx, portfolioID, simulationID, userID)
+	if err != nil {
+		return err
+	}
+	return s.simulationRepo.Delete(ctx, simulation.ID.String())
+}
+
+// simulate runs the paths of a simulation month by month. Each month's withdrawal, a twelfth
+// of the year's, is taken at the start of the month and the rest of the portfolio then earns a
+// normally distributed return. The withdrawal grows with inflation at the start of every year
+// after the first. A path is depleted once a withdrawal leaves less than a cent.
+func simulate(ctx context.Context, parameters dto.SimulationParameters) (*dto.SimulationResult, error) {
+	seed := uint64(parameters.Seed)
+	rng := rand.New(rand.NewPCG(seed, seed))
+
+	monthlyReturn, monthlyVolatility := monthlyReturnDistribution(parameters.ExpectedReturn, parameters.Volatility)
+	inflation, _ := parameters.InflationRate.Div(decimal.NewFromInt(100)).Float64()
+	start, _ := parameters.StartingValue.Float64()
+
+	values := make([]float64, parameters.Paths)
+	for i := range values {
+		values[i] = start
+	}
+	// depletedIn is the fractional number of years each path lasted, or zero if it survives
+	depletedIn := make([]float64, parameters.Paths)
+
+	sorted := make([]float64, parameters.Paths)
+	withdrawal := parameters.AnnualWithdrawal
+	result := &dto.SimulationResult{Years: make([]models.SimulationYear, 0, parameters.Years)}
+	for year := 1; year <= parameters.Years; year++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if year > 1 {
+			withdrawal = withdrawal.Mul(decimal.NewFromFloat(1 + inflation))
+		}
+		monthly, _ := withdrawal.Div(decimal.NewFromInt(12)).Float64()
+
+		for month := 1; month <= 12; month++ {
+			for i := range values {
+				if depletedIn[i] > 0 {
+					continue
+				}
+				values[i] -= monthly
+				if values[i] < 0.005 {
+					values[i] = 0
+					depletedIn[i] = float64(year-1) + float64(month)/12
+					continue
+				}
+				// A month can at worst wipe out the portfolio
+				values[i] *= math.Max(0, 1+monthlyReturn+monthlyVolatility*rng.NormFloat64())
+			}
+		}
+
+		surviving := 0
+		for _, years := range depletedIn {
+			if years == 0 {
+				surviving++
+			}
+		}
+
+		copy(sorted, values)
+		result.Years = append(result.Years, models.NewSimulationYear(year, withdrawal, surviving, sorted))
+	}
+
+	var depleted []float64
+	for _, years := range depletedIn {
+		if years > 0 {
+			depleted = append(depleted, years)
+		}
+	}
+	result.DepletedPaths = len(depleted)
+	result.SuccessProbability = decimal.NewFromInt(int64(parameters.Paths - len(depleted))).
+		Div(decimal.NewFromInt(int64(parameters.Paths))).
+		Mul(decimal.NewFromInt(100)).
+		Round(2)
+	if len(depleted) > 0 {
+		sort.Float64s(depleted)
+		mid := len(depleted) / 2
+		median := depleted[mid]
+		if len(depleted)%2 == 0 {
+			median = (depleted[mid-1] + depleted[mid]) / 2
+		}
+		years := decimal.NewFromFloat(median).Round(2)
+		result.MedianDepletionYears = &years
+	}
+
+	return result, nil
+}
+
+// validateSimulation checks the amounts and rates of a simulation request
+func validateSimulation(req *dto.SimulationRequest) error {
+	if !req.AnnualWithdrawal.IsPositive() || req.InflationRate.IsNegative() {
+		return models.ErrInvalidSimulation
+	}
+	if req.Years < 1 || req.Years > models.MaxSimulationYears {
+		return models.ErrInvalidSimulation
+	}
+	if req.StartingValue != nil && !req.StartingValue.IsPositive() {
+		return models.ErrInvalidSimulation
+	}
+	if req.ExpectedReturn != nil && req.ExpectedReturn.LessThanOrEqual(decimal.NewFromInt(-100)) {
+		return models.ErrInvalidSimulation
+	}
+	if req.Volatility != nil && req.Volatility.IsNegative() {
+		return models.ErrInvalidSimulation
+	}
+	if req.Paths < 0 || req.Paths > models.MaxSimulationPaths {
+		return models.ErrInvalidSimulation
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupSimulationTest(t *testing.T) (*gorm.DB, *models.Portfolio, *simulationService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.PerformanceSnapshot{},
+		&models.QueuedJob{}, &models.Simulation{},
+	))
+
+	user := &models.User{Email: "retiree@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Retirement", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	service := NewSimulationService(
+		repository.NewSimulationRepository(db),
+		portfolioRepo,
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+		NewJobQueueService(repository.NewQueuedJobRepository(db), portfolioRepo),
+	).(*simulationService)
+	service.now = func() time.Time { return projectionTestNow }
+
+	return db, portfolio, service
+}
+
+// runQueuedSimulation runs the simulation a queued job refers to, the way the worker pool does
+func runQueuedSimulation(t *testing.T, service SimulationService, job *models.QueuedJob) *dto.SimulationResponse {
+	result, err := SimulationJobHandler(service)(context.Background(), job, func(any) {})
+	require.NoError(t, err)
+	return result.(*dto.SimulationResponse)
+}
+
+func TestSimulationService_DeterministicDepletion(t *testing.T) {
+	_, portfolio, service := setupSimulationTest(t)
+	ctx := context.Background()
+	portfolioID := portfolio.ID.String()
+	userID := portfolio.UserID.String()
+
+	startingValue := decimal.NewFromInt(5000)
+	flat := decimal.Zero
+	job, err := service.Create(ctx, portfolioID, userID, &dto.SimulationRequest{
+		AnnualWithdrawal: decimal.NewFromInt(1000),
+		Years:            8,
+		StartingValue:    &startingValue,
+		ExpectedReturn:   &flat,
+		Volatility:       &flat,
+		Paths:            100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobTypeSimulation, job.Type)
+
+	simulations, err := service.List(ctx, portfolioID, userID)
+	require.NoError(t, err)
+	require.Len(t, simulations, 1)
+	assert.Equal(t, models.QueuedJobStatusQueued, simulations[0].Status)
+	assert.Equal(t, job.ID, *simulations[0].JobID)
+
+	response := runQueuedSimulation(t, service, job)
+	assert.Equal(t, models.QueuedJobStatusSucceeded, response.Status)
+	assert.Equal(t, 100, response.Parameters.Paths)
+	require.NotNil(t, response.Result)
+
+	// Without returns 1000 a year lasts exactly five years on every path
+	result := response.Result
+	assert.True(t, result.SuccessProbability.IsZero())
+	assert.Equal(t, 100, result.DepletedPaths)
+	require.NotNil(t, result.MedianDepletionYears)
+	assert.True(t, result.MedianDepletionYears.Equal(decimal.NewFromInt(5)), result.MedianDepletionYears.String())
+	require.Len(t, result.Years, 8)
+	assert.True(t, result.Years[3].SurvivingPct.Equal(decimal.NewFromInt(100)))
+	assert.True(t, result.Years[3].P50.Equal(decimal.NewFromInt(1000)), result.Years[3].P50.String())
+	assert.True(t, result.Years[4].SurvivingPct.IsZero())
+
+	stored, err := service.Get(ctx, portfolioID, response.ID.String(), userID)
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobStatusSucceeded, stored.Status)
+	assert.NotNil(t, stored.FinishedAt)
+}
+
+func TestSimulationService_InflationAndSeed(t *testing.T) {
+	_, portfolio, service := setupSimulationTest(t)
+	ctx := context.Background()
+
+	startingValue := decimal.NewFromInt(1000000)
+	expectedReturn, volatility := decimal.NewFromInt(6), decimal.NewFromInt(15)
+	seed := int64(7)
+	req := &dto.SimulationRequest{
+		AnnualWithdrawal: decimal.NewFromInt(40000),
+		Years:            30,
+		InflationRate:    decimal.NewFromInt(3),
+		StartingValue:    &startingValue,
+		ExpectedReturn:   &expectedReturn,
+		Volatility:       &volatility,
+		Paths:            500,
+		Seed:             &seed,
+	}
+
+	first, err := service.Create(ctx, portfolio.ID.String(), portfolio.UserID.String(), req)
+	require.NoError(t, err)
+	second, err := service.Create(ctx, portfolio.ID.String(), portfolio.UserID.String(), req)
+	require.NoError(t, err)
+
+	a := runQueuedSimulation(t, service, first).Result
+	b := runQueuedSimulation(t, service, second).Result
+	assert.Equal(t, a, b)
+
+	assert.True(t, a.Years[1].Withdrawal.Equal(decimal.NewFromInt(41200)), a.Years[1].Withdrawal.String())
+	last := a.Years[29]
+	assert.True(t, last.P10.LessThanOrEqual(last.P50))
+	assert.True(t, last.P50.LessThanOrEqual(last.P90))
+	assert.True(t, a.SuccessProbability.GreaterThan(decimal.Zero))
+	assert.True(t, a.SuccessProbability.LessThan(decimal.NewFromInt(100)))
+}
+
+func TestSimulationService_Historical(t *testing.T) {
+	db, portfolio, service := setupSimulationTest(t)
+	ctx := context.Background()
+	portfolioID := portfolio.ID.String()
+	userID := portfolio.UserID.String()
+	req := &dto.SimulationRequest{AnnualWithdrawal: decimal.NewFromInt(500), Years: 10, Paths: 100}
+
+	createMonthlySnapshots(t, db, portfolio, 12)
+	_, err := service.Create(ctx, portfolioID, userID, req)
+	assert.Equal(t, models.ErrInsufficientSimulationHistory, err)
+
+	require.NoError(t, db.Where("portfolio_id = ?", portfolio.ID).Delete(&models.PerformanceSnapshot{}).Error)
+	createMonthlySnapshots(t, db, portfolio, 13)
+
+	job, err := service.Create(ctx, portfolioID, userID, req)
+	require.NoError(t, err)
+
+	response := runQueuedSimulation(t, service, job)
+	parameters := response.Parameters
+	assert.Equal(t, 12, parameters.HistoryMonths)
+	assert.True(t, parameters.ExpectedReturn.Equal(decimal.NewFromFloat(12.68)), parameters.ExpectedReturn.String())
+	assert.True(t, parameters.StartingValue.GreaterThan(decimal.NewFromInt(10000)))
+	assert.True(t, response.Result.SuccessProbability.Equal(decimal.NewFromInt(100)))
+}
+
+func TestSimulationService_Access(t *testing.T) {
+	db, portfolio, service := setupSimulationTest(t)
+	ctx := context.Background()
+	portfolioID := portfolio.ID.String()
+	userID := portfolio.UserID.String()
+
+	startingValue, expectedReturn := decimal.NewFromInt(1000), decimal.NewFromInt(5)
+	valid := dto.SimulationRequest{
+		AnnualWithdrawal: decimal.NewFromInt(100),
+		Years:            5,
+		StartingValue:    &startingValue,
+		ExpectedReturn:   &expectedReturn,
+		Volatility:       &expectedReturn,
+	}
+
+	invalid := valid
+	invalid.AnnualWithdrawal = decimal.Zero
+	_, err := service.Create(ctx, portfolioID, userID, &invalid)
+	assert.Equal(t, models.ErrInvalidSimulation, err)
+
+	stranger := &models.User{Email: "stranger@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(stranger).Error)
+	_, err = service.Create(ctx, portfolioID, stranger.ID.String(), &valid)
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+
+	_, err = service.Create(ctx, portfolioID, userID, &valid)
+	require.NoError(t, err)
+	simulations, err := service.List(ctx, portfolioID, userID)
+	require.NoError(t, err)
+	simulationID := simulations[0].ID.String()
+
+	other := &models.Portfolio{UserID: portfolio.UserID, Name: "Other", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(other).Error)
+	_, err = service.Get(ctx, other.ID.String(), simulationID, userID)
+	assert.Equal(t, models.ErrSimulationNotFound, err)
+
+	require.NoError(t, service.Delete(ctx, portfolioID, simulationID, userID))
+	_, err = service.Get(ctx, portfolioID, simulationID, userID)
+	assert.Equal(t, models.ErrSimulationNotFound, err)
+}
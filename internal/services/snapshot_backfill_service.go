@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/calendar"
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type aliases for dto types for consistency with the other services
+type (
+	SnapshotBackfillProgress = dto.SnapshotBackfillProgress
+	SnapshotBackfillResult   = dto.SnapshotBackfillResult
+)
+
+const (
+	// snapshotBackfillCheckpointInterval is how many sessions a backfill stores between
+	// checkpoints, bounding the work a crash throws away
+	snapshotBackfillCheckpointInterval = 20
+	// snapshotBackfillPriceLookbackDays is how far before the first session prices are
+	// fetched, covering symbols that didn't trade that day
+	snapshotBackfillPriceLookbackDays = 7
+)
+
+// SnapshotBackfillService defines the interface for filling in a portfolio's missing daily
+// performance snapshots from its transaction history
+type SnapshotBackfillService interface {
+	StartBackfill(ctx context.Context, portfolioID, userID string, req dto.SnapshotBackfillRequest) (*models.QueuedJob, error)
+	Backfill(
+		ctx context.Context,
+		portfolioID, userID string,
+		req dto.SnapshotBackfillRequest,
+		onProgress func(SnapshotBackfillProgress),
+	) (*SnapshotBackfillResult, error)
+}
+
+// snapshotBackfillService implements SnapshotBackfillService interface
+type snapshotBackfillService struct {
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.PerformanceSnapshotRepository
+	checkpointRepo  repository.SnapshotBackfillCheckpointRepository
+	marketData      MarketDataService
+	jobQueue        JobQueueService
+	rounding        models.RoundingPolicy
+	now             func() time.Time
+}
+
+// NewSnapshotBackfillService creates a new SnapshotBackfillService instance. Backfills run as
+// queued jobs and value holdings at historical closing prices, so they need both a job queue
+// and market data.
+func NewSnapshotBackfillService(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	checkpointRepo repository.SnapshotBackfillCheckpointRepository,
+	marketData MarketDataService,
+	jobQueue JobQueueService,
+	rounding models.RoundingPolicy,
+) SnapshotBackfillService {
+	return &snapshotBackfillService{
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+		checkpointRepo:  checkpointRepo,
+		marketData:      marketData,
+		jobQueue:        jobQueue,
+		rounding:        rounding,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// StartBackfill queues a backfill of the portfolio's snapshots. The job's result is the
+// backfill's SnapshotBackfillResult.
+func (s *snapshotBackfillService) StartBackfill(
+	ctx context.Context,
+	portfolioID, userID string,
+	req dto.SnapshotBackfillRequest,
+) (*models.QueuedJob, error) {
+	if s.jobQueue == nil {
+		return nil, models.ErrJobQueueUnavailable
+	}
+	if s.marketData == nil {
+		return nil, models.ErrMarketDataUnavailable
+	}
+	return s.jobQueue.Enqueue(ctx, userID, portfolioID, models.QueuedJobTypeSnapshotBackfill, req)
+}
+
+// Backfill replays the portfolio's transactions in order and stores a snapshot for every NYSE
+// session in the requested range that has none, valuing the holdings of the day at its
+// closing prices. Holdings without a price are valued at cost basis, as CreateSnapshot does.
+//
+// Progress is checkpointed every few sessions. A backfill whose context is cancelled stores
+// its checkpoint and returns models.ErrJobCheckpointed; the next backfill of a range the
+// checkpoint covers resumes after its last completed session, unless req.Restart is set.
+func (s *snapshotBackfillService) Backfill(
+	ctx context.Context,
+	portfolioID, userID string,
+	req dto.SnapshotBackfillRequest,
+	onProgress func(SnapshotBackfillProgress),
+) (*SnapshotBackfillResult, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	var transactions []*models.Transaction
+	err = s.transactionRepo.IterateByPortfolioID(ctx, portfolioID, func(batch []*models.Transaction) error {
+		transactions = append(transactions, batch...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	sortTransactionsForReplay(transactions)
+
+	end := calendar.NYSE.LastClosedSession(s.now())
+	if !req.EndDate.IsZero() && req.EndDate.Before(end) {
+		end = calendar.NYSE.TradingDayOnOrBefore(req.EndDate)
+	}
+	result := &SnapshotBackfillResult{PortfolioID: portfolioID, EndDate: end}
+	if len(transactions) == 0 {
+		// Nothing was ever held, so there is nothing to value
+		result.CompletedAt = s.now()
+		return result, nil
+	}
+
+	// Sessions before the first transaction held nothing
+	start := req.StartDate
+	if first := transactions[0].Date; start.Before(first) {
+		start = first
+	}
+	start = tradingDayOnOrAfter(start)
+	result.StartDate = start
+
+	checkpoint := &models.SnapshotBackfillCheckpoint{PortfolioID: portfolio.ID, StartDate: start}
+	resume := start
+	if !req.Restart {
+		saved, err := s.checkpointRepo.FindByPortfolioID(ctx, portfolioID)
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil && saved.Covers(start) {
+			checkpoint = saved
+			resume = tradingDayOnOrAfter(saved.LastCompletedDate.AddDate(0, 0, 1))
+			result.ResumedFrom = &resume
+		}
+	}
+	if resume.After(end) {
+		result.CompletedAt = s.now()
+		return result, nil
+	}
+	result.Sessions = calendar.NYSE.TradingDaysBetween(resume.AddDate(0, 0, -1), end)
+
+	prices := NewHistoricalPricePrefetcher(s.marketData, resume.AddDate(0, 0, -snapshotBackfillPriceLookbackDays), end)
+	if s.marketData != nil {
+		if err := prices.Prefetch(ctx, historySymbols(transactions)); err != nil {
+			return nil, fmt.Errorf("failed to fetch price history: %w", err)
+		}
+	}
+
+	// Writes aren't cancelled with the run, so a session is stored completely or not at all
+	store := context.WithoutCancel(ctx)
+	var previous *decimal.Decimal
+	if result.ResumedFrom != nil {
+		if snapshot, err := s.snapshotRepo.FindByPortfolioIDAndDate(ctx, portfolioID, calendar.NYSE.SessionClose(checkpoint.LastCompletedDate)); err == nil {
+			previous = &snapshot.TotalValue
+		}
+	}
+
+	replay := newLedgerReplay(portfolio, s.rounding)
+	next := 0
+	completed := 0
+	report := func() {
+		if onProgress == nil {
+			return
+		}
+		progress := SnapshotBackfillProgress{
+			StartDate:         start,
+			EndDate:           end,
+			TotalSessions:     result.Sessions,
+			CompletedSessions: completed,
+		}
+		if !checkpoint.LastCompletedDate.IsZero() {
+			last := checkpoint.LastCompletedDate
+			progress.LastCompletedDate = &last
+		}
+		onProgress(progress)
+	}
+	report()
+
+	for day := resume; !day.After(end); day = tradingDayOnOrAfter(day.AddDate(0, 0, 1)) {
+		if err := ctx.Err(); err != nil {
+			if completed > 0 {
+				if err := s.checkpointRepo.Save(store, checkpoint); err != nil {
+					return nil, err
+				}
+			}
+			return nil, fmt.Errorf("%w: snapshot backfill stopped after %d of %d sessions: %w",
+				models.ErrJobCheckpointed, completed, result.Sessions, err)
+		}
+
+		for next < len(transactions) && !transactionDay(transactions[next]).After(day) {
+			if err := replay.apply(transactions[next]); err != nil {
+				return nil, err
+			}
+			next++
+		}
+
+		snapshot := s.valueSession(ctx, portfolio.ID, replay, prices, day)
+		if previous != nil {
+			snapshot.CalculateDayChange(*previous)
+		}
+		err := s.snapshotRepo.Create(store, snapshot)
+		switch {
+		case errors.Is(err, models.ErrDuplicateSnapshot):
+			// Taken on the day or by an earlier backfill; later day changes follow it
+			result.Existing++
+			if existing, err := s.snapshotRepo.FindByPortfolioIDAndDate(store, portfolioID, snapshot.Date); err == nil {
+				snapshot = existing
+			}
+		case err != nil:
+			return nil, fmt.Errorf("failed to create snapshot for %s: %w", day.Format("2006-01-02"), err)
+		default:
+			result.Created++
+		}
+		previous = &snapshot.TotalValue
+
+		completed++
+		checkpoint.LastCompletedDate = day
+		if completed%snapshotBackfillCheckpointInterval == 0 {
+			if err := s.checkpointRepo.Save(store, checkpoint); err != nil {
+				return nil, err
+			}
+			report()
+		}
+	}
+
+	if err := s.checkpointRepo.Save(store, checkpoint); err != nil {
+		return nil, err
+	}
+	report()
+
+	result.TransactionsReplayed = next
+	result.CompletedAt = s.now()
+	return result, nil
+}
+
+// valueSession returns the snapshot of the replayed holdings at the close of day, with the
+// gains, dividends and fees of the transactions replayed so far
+func (s *snapshotBackfillService) valueSession(
+	ctx context.Context,
+	portfolioID uuid.UUID,
+	replay *ledgerReplay,
+	prices *HistoricalPricePrefetcher,
+	day time.Time,
+) *models.PerformanceSnapshot {
+	totalValue := decimal.Zero
+	totalCostBasis := decimal.Zero
+	for symbol, holding := range replay.holdings {
+		totalCostBasis = totalCostBasis.Add(holding.CostBasis)
+		price, ok := decimal.Zero, false
+		if s.marketData != nil && !models.IsOptionSymbol(symbol) {
+			price, ok = prices.PriceOn(ctx, symbol, day)
+		}
+		if ok {
+			totalValue = totalValue.Add(holding.MarketValue(price))
+		} else {
+			totalValue = totalValue.Add(holding.CostBasis)
+		}
+	}
+
+	snapshot := &models.PerformanceSnapshot{
+		PortfolioID:    portfolioID,
+		Date:           calendar.NYSE.SessionClose(day).UTC(),
+		TotalValue:     totalValue,
+		TotalCostBasis: totalCostBasis,
+	}
+	snapshot.CalculateMetrics()
+	snapshot.SetGainBreakdown(replay.totals.Realized, replay.totals.Dividends, replay.totals.Fees)
+	return snapshot
+}
+
+// tradingDayOnOrAfter returns the first NYSE trading day on or after date's day, as a UTC
+// midnight
+func tradingDayOnOrAfter(date time.Time) time.Time {
+	d := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	for !calendar.NYSE.IsTradingDay(d) {
+		d = d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// transactionDay returns the UTC day a transaction was made on
+func transactionDay(tx *models.Transaction) time.Time {
+	date := tx.Date.UTC()
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// historySymbols returns the symbols traded in transactions that have price history, leaving
+// out option contracts and the currencies of cash flows
+func historySymbols(transactions []*models.Transaction) []string {
+	var symbols []string
+	for _, tx := range transactions {
+		if !models.IsOptionSymbol(tx.Symbol) && !tx.IsCashFlow() {
+			symbols = append(symbols, tx.Symbol)
+		}
+	}
+	return symbols
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// backfillPriceStart is the first day of the price history the backfill tests serve. The
+// close on each day after it is 100 plus the number of days since.
+var backfillPriceStart = time.Date(2023, 12, 20, 0, 0, 0, 0, time.UTC)
+
+type snapshotBackfillTest struct {
+	service     *snapshotBackfillService
+	db          *gorm.DB
+	jobRepo     repository.QueuedJobRepository
+	snapshots   repository.PerformanceSnapshotRepository
+	checkpoints repository.SnapshotBackfillCheckpointRepository
+	portfolio   *models.Portfolio
+}
+
+func setupSnapshotBackfillTest(t *testing.T) *snapshotBackfillTest {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.PerformanceSnapshot{},
+		&models.SnapshotBackfillCheckpoint{}, &models.QueuedJob{},
+	))
+	// One snapshot per portfolio per day, as the migrations enforce
+	require.NoError(t, db.Exec("CREATE UNIQUE INDEX idx_performance_snapshots_portfolio_day ON performance_snapshots(portfolio_id, date(date))").Error)
+
+	user := &models.User{Email: "backfill@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Brokerage", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	for _, tx := range []struct {
+		txType   models.TransactionType
+		day      int
+		quantity int64
+	}{
+		{models.TransactionTypeBuy, 2, 10},
+		{models.TransactionTypeBuy, 10, 5},
+		{models.TransactionTypeSell, 16, 5},
+	} {
+		price := decimal.NewFromInt(100)
+		require.NoError(t, db.Create(&models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        tx.txType,
+			Symbol:      "AAPL",
+			Date:        time.Date(2024, 1, tx.day, 0, 0, 0, 0, time.UTC),
+			Quantity:    decimal.NewFromInt(tx.quantity),
+			Price:       &price,
+			Currency:    "USD",
+		}).Error)
+	}
+
+	var prices []*HistoricalPrice
+	for date := backfillPriceStart; date.Before(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)); date = date.AddDate(0, 0, 1) {
+		days := int64(date.Sub(backfillPriceStart).Hours() / 24)
+		prices = append(prices, &HistoricalPrice{Date: date, Close: decimal.NewFromInt(100 + days)})
+	}
+	marketData := new(MockMarketDataService)
+	marketData.On("GetHistoricalPrices", "AAPL", mock.Anything, mock.Anything).Return(prices, nil)
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	jobRepo := repository.NewQueuedJobRepository(db)
+	snapshots := repository.NewPerformanceSnapshotRepository(db)
+	checkpoints := repository.NewSnapshotBackfillCheckpointRepository(db)
+	service := NewSnapshotBackfillService(
+		portfolioRepo,
+		repository.NewTransactionRepository(db),
+		snapshots,
+		checkpoints,
+		marketData,
+		NewJobQueueService(jobRepo, portfolioRepo),
+		models.DefaultRoundingPolicy(),
+	).(*snapshotBackfillService)
+	// The session of January 31st, 2024 is the last one closed
+	service.now = func() time.Time { return time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC) }
+
+	return &snapshotBackfillTest{
+		service:     service,
+		db:          db,
+		jobRepo:     jobRepo,
+		snapshots:   snapshots,
+		checkpoints: checkpoints,
+		portfolio:   portfolio,
+	}
+}
+
+// snapshotOn returns the portfolio's snapshot of a January 2024 session
+func (bt *snapshotBackfillTest) snapshotOn(t *testing.T, day int) *models.PerformanceSnapshot {
+	snapshot, err := bt.snapshots.FindByPortfolioIDAndDate(context.Background(), bt.portfolio.ID.String(), time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	return snapshot
+}
+
+func (bt *snapshotBackfillTest) snapshotCount(t *testing.T) int64 {
+	var count int64
+	require.NoError(t, bt.db.Model(&models.PerformanceSnapshot{}).Count(&count).Error)
+	return count
+}
+
+func TestSnapshotBackfillService_Backfill(t *testing.T) {
+	bt := setupSnapshotBackfillTest(t)
+	ctx := context.Background()
+	portfolioID, userID := bt.portfolio.ID.String(), bt.portfolio.UserID.String()
+
+	var reports []dto.SnapshotBackfillProgress
+	result, err := bt.service.Backfill(ctx, portfolioID, userID, dto.SnapshotBackfillRequest{}, func(progress dto.SnapshotBackfillProgress) {
+		reports = append(reports, progress)
+	})
+	require.NoError(t, err)
+
+	// January 2024 had 21 sessions from the first transaction on, closed for New Year's Day
+	// and Martin Luther King Jr. Day
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), result.StartDate)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), result.EndDate)
+	assert.Nil(t, result.ResumedFrom)
+	assert.Equal(t, 21, result.Sessions)
+	assert.Equal(t, 21, result.Created)
+	assert.Zero(t, result.Existing)
+	assert.Equal(t, 3, result.TransactionsReplayed)
+	assert.Equal(t, int64(21), bt.snapshotCount(t))
+
+	// Progress is reported at the start, at the checkpoint after 20 sessions and at the end
+	require.Len(t, reports, 3)
+	assert.Zero(t, reports[0].CompletedSessions)
+	assert.Equal(t, 20, reports[1].CompletedSessions)
+	assert.Equal(t, 21, reports[2].CompletedSessions)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), *reports[2].LastCompletedDate)
+
+	// Holdings are valued at each day's close: 10 shares on the 2nd at 113, 15 on the 10th at
+	// 121 and 10 again on the 16th at 127
+	first := bt.snapshotOn(t, 2)
+	assert.True(t, decimal.NewFromInt(1130).Equal(first.TotalValue), first.TotalValue.String())
+	assert.True(t, decimal.NewFromInt(1000).Equal(first.TotalCostBasis))
+	assert.Nil(t, first.DayChange)
+	assert.True(t, decimal.NewFromInt(15*121).Equal(bt.snapshotOn(t, 10).TotalValue))
+	assert.True(t, decimal.NewFromInt(10*127).Equal(bt.snapshotOn(t, 16).TotalValue))
+
+	// The sale on the 16th realizes 5 x (100 - 100) at average cost, and the rest is unrealized
+	require.True(t, first.HasGainBreakdown())
+	assert.True(t, decimal.NewFromInt(130).Equal(*first.UnrealizedGain))
+	sold := bt.snapshotOn(t, 16)
+	require.True(t, sold.HasGainBreakdown())
+	assert.True(t, sold.RealizedGain.IsZero())
+	assert.True(t, decimal.NewFromInt(270).Equal(*sold.UnrealizedGain), sold.UnrealizedGain.String())
+	assert.True(t, sold.DividendIncome.IsZero())
+	assert.True(t, sold.Fees.IsZero())
+
+	// Day changes follow the previous session, across the holiday weekend
+	after := bt.snapshotOn(t, 16)
+	require.NotNil(t, after.DayChange)
+	assert.True(t, after.TotalValue.Sub(bt.snapshotOn(t, 12).TotalValue).Equal(*after.DayChange))
+
+	checkpoint, err := bt.checkpoints.FindByPortfolioID(ctx, portfolioID)
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.True(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC).Equal(checkpoint.LastCompletedDate))
+
+	// Running again finds nothing left to do
+	again, err := bt.service.Backfill(ctx, portfolioID, userID, dto.SnapshotBackfillRequest{}, nil)
+	require.NoError(t, err)
+	assert.Zero(t, again.Sessions)
+	assert.Zero(t, again.Created)
+}
+
+func TestSnapshotBackfillService_Backfill_ResumesFromCheckpoint(t *testing.T) {
+	bt := setupSnapshotBackfillTest(t)
+	ctx := context.Background()
+	portfolioID, userID := bt.portfolio.ID.String(), bt.portfolio.UserID.String()
+
+	// The worker is stopped at the first checkpoint
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	_, err := bt.service.Backfill(runCtx, portfolioID, userID, dto.SnapshotBackfillRequest{}, func(progress dto.SnapshotBackfillProgress) {
+		if progress.CompletedSessions == 20 {
+			stop()
+		}
+	})
+	require.ErrorIs(t, err, models.ErrJobCheckpointed)
+	assert.ErrorContains(t, err, "after 20 of 21 sessions")
+	assert.Equal(t, int64(20), bt.snapshotCount(t))
+
+	checkpoint, err := bt.checkpoints.FindByPortfolioID(ctx, portfolioID)
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	assert.True(t, time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC).Equal(checkpoint.LastCompletedDate))
+
+	// The next run picks up after the last completed session
+	result, err := bt.service.Backfill(ctx, portfolioID, userID, dto.SnapshotBackfillRequest{}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, result.ResumedFrom)
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), *result.ResumedFrom)
+	assert.Equal(t, 1, result.Sessions)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, int64(21), bt.snapshotCount(t))
+
+	last := bt.snapshotOn(t, 31)
+	require.NotNil(t, last.DayChange, "the day change follows the session before the checkpoint")
+	assert.True(t, last.TotalValue.Sub(bt.snapshotOn(t, 30).TotalValue).Equal(*last.DayChange))
+
+	// Restarting ignores the checkpoint and keeps the snapshots already taken
+	result, err = bt.service.Backfill(ctx, portfolioID, userID, dto.SnapshotBackfillRequest{Restart: true}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result.ResumedFrom)
+	assert.Equal(t, 21, result.Sessions)
+	assert.Zero(t, result.Created)
+	assert.Equal(t, 21, result.Existing)
+}
+
+func TestSnapshotBackfillService_Backfill_Ranges(t *testing.T) {
+	bt := setupSnapshotBackfillTest(t)
+	ctx := context.Background()
+	portfolioID, userID := bt.portfolio.ID.String(), bt.portfolio.UserID.String()
+
+	// A range starting on a weekend starts on the next session
+	result, err := bt.service.Backfill(ctx, portfolioID, userID, dto.SnapshotBackfillRequest{
+		StartDate: time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC),
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), result.StartDate)
+	assert.Equal(t, 4, result.Created)
+	assert.True(t, decimal.NewFromInt(10*127).Equal(bt.snapshotOn(t, 16).TotalValue), "earlier transactions are replayed")
+
+	// An earlier range isn't covered by the checkpoint, so it starts over
+	result, err = bt.service.Backfill(ctx, portfolioID, userID, dto.SnapshotBackfillRequest{
+		EndDate: time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC),
+	}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result.ResumedFrom)
+	assert.Equal(t, 13, result.Sessions)
+	assert.Equal(t, 9, result.Created)
+	assert.Equal(t, 4, result.Existing)
+}
+
+func TestSnapshotBackfillService_Backfill_Access(t *testing.T) {
+	bt := setupSnapshotBackfillTest(t)
+	ctx := context.Background()
+
+	_, err := bt.service.Backfill(ctx, bt.portfolio.ID.String(), "00000000-0000-0000-0000-000000000001", dto.SnapshotBackfillRequest{}, nil)
+	assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+	_, err = bt.service.Backfill(ctx, "00000000-0000-0000-0000-000000000002", bt.portfolio.UserID.String(), dto.SnapshotBackfillRequest{}, nil)
+	assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+}
+
+func TestSnapshotBackfillService_StartBackfill(t *testing.T) {
+	bt := setupSnapshotBackfillTest(t)
+	ctx := context.Background()
+	portfolioID, userID := bt.portfolio.ID.String(), bt.portfolio.UserID.String()
+
+	job, err := bt.service.StartBackfill(ctx, portfolioID, userID, dto.SnapshotBackfillRequest{Restart: true})
+	require.NoError(t, err)
+	assert.Equal(t, models.QueuedJobTypeSnapshotBackfill, job.Type)
+	assert.Equal(t, models.QueuedJobStatusQueued, job.Status)
+
+	// The queued job runs the backfill it was given
+	claimed, err := bt.jobRepo.ClaimNext(ctx, []models.QueuedJobType{models.QueuedJobTypeSnapshotBackfill})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	var progress []any
+	result, err := SnapshotBackfillJobHandler(bt.service)(ctx, claimed, func(p any) { progress = append(progress, p) })
+	require.NoError(t, err)
+	assert.Equal(t, 21, result.(*SnapshotBackfillResult).Created)
+	assert.NotEmpty(t, progress)
+
+	withoutMarketData := NewSnapshotBackfillService(nil, nil, nil, nil, nil, NewJobQueueService(bt.jobRepo, nil), models.DefaultRoundingPolicy())
+	_, err = withoutMarketData.StartBackfill(ctx, portfolioID, userID, dto.SnapshotBackfillRequest{})
+	assert.ErrorIs(t, err, models.ErrMarketDataUnavailable)
+}
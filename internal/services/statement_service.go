@@ -0,0 +1,339 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// statementValuationLookback is how far before a period the opening valuation is looked for.
+// Snapshots are thinned to weekly after the daily retention window, so there is always one
+// within a few weeks if the portfolio was being valued.
+const statementValuationLookback = 45 * 24 * time.Hour
+
+var (
+	statementMonthPattern   = regexp.MustCompile(`^(\d{4})-(0[1-9]|1[0-2])$`)
+	statementQuarterPattern = regexp.MustCompile(`^(\d{4})-[Qq]([1-4])$`)
+)
+
+// StatementService defines the interface for generating portfolio statements
+type StatementService interface {
+	Generate(ctx context.Context, portfolioID, userID, period string) (*dto.Statement, error)
+}
+
+// statementService implements StatementService interface
+type statementService struct {
+	portfolioRepo   repository.PortfolioRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.PerformanceSnapshotRepository
+	rounding        models.RoundingPolicy
+	settings        UserSettingsService
+	now             func() time.Time
+}
+
+// NewStatementService creates a new StatementService instance
+func NewStatementService(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+) StatementService {
+	return NewStatementServiceWithRounding(portfolioRepo, transactionRepo, snapshotRepo, models.DefaultRoundingPolicy())
+}
+
+// NewStatementServiceWithRounding creates a new StatementService instance that rebuilds
+// holdings with the given rounding policy
+func NewStatementServiceWithRounding(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	rounding models.RoundingPolicy,
+) StatementService {
+	return NewStatementServiceWithSettings(portfolioRepo, transactionRepo, snapshotRepo, rounding, nil)
+}
+
+// NewStatementServiceWithSettings creates a StatementService like
+// NewStatementServiceWithRounding whose statements are written in the locale and time zone
+// of the user's settings
+func NewStatementServiceWithSettings(
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	rounding models.RoundingPolicy,
+	settings UserSettingsService,
+) StatementService {
+	return &statementService{
+		portfolioRepo:   portfolioRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+		rounding:        rounding,
+		settings:        settings,
+		now:             func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Generate builds a portfolio's statement for a month (2024-11) or quarter (2024-Q4). The
+// holdings are those at the end of the period, rebuilt from the ledger; a period that hasn't
+// ended yet is covered up to today.
+func (s *statementService) Generate(ctx context.Context, portfolioID, userID, period string) (*dto.Statement, error) {
+	now := s.now()
+	title, start, end, err := parseStatementPeriod(period, now)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	transactions, err := s.transactionRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+	sortTransactionsForReplay(transactions)
+
+	// Replay the ledger up to the end of the period, collecting the period's transactions
+	replay := newLedgerReplay(portfolio, s.rounding)
+	var inPeriod []*models.Transaction
+	heldBefore := false
+	for _, tx := range transactions {
+		if !tx.Date.Before(end) {
+			break
+		}
+		if err := replay.apply(tx); err != nil {
+			return nil, err
+		}
+		if tx.Date.Before(start) {
+			heldBefore = true
+		} else {
+			inPeriod = append(inPeriod, tx)
+		}
+	}
+	holdings, _ := replay.results()
+
+	performance, err := periodPerformance(ctx, s.snapshotRepo, portfolioID, start, end, heldBefore, inPeriod)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := models.DefaultUserSettings(portfolio.UserID)
+	if s.settings != nil {
+		if preferences, err = s.settings.Get(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to load user settings: %w", err)
+		}
+	}
+
+	statement := &dto.Statement{
+		Portfolio: dto.CertifiedPortfolio{
+			ID:           portfolio.ID.String(),
+			Name:         portfolio.Name,
+			BaseCurrency: portfolio.BaseCurrency,
+		},
+		Period:       strings.ToUpper(period),
+		Title:        title,
+		StartDate:    start,
+		EndDate:      end.AddDate(0, 0, -1),
+		GeneratedAt:  now.Truncate(time.Second),
+		Locale:       preferences.Locale,
+		Timezone:     preferences.Timezone,
+		Performance:  performance,
+		Holdings:     make([]dto.StatementHolding, 0, len(holdings)),
+		Transactions: make([]dto.StatementTransaction, 0, len(inPeriod)),
+		Income:       statementIncome(inPeriod),
+	}
+	for _, holding := range holdings {
+		if holding.Quantity.IsZero() {
+			continue
+		}
+		statement.Holdings = append(statement.Holdings, dto.StatementHolding{
+			Symbol:      holding.Symbol,
+			Quantity:    holding.Quantity,
+			CostBasis:   holding.CostBasis,
+			AverageCost: holding.AvgCostPrice,
+		})
+	}
+	for _, tx := range inPeriod {
+		statement.Transactions = append(statement.Transactions, dto.StatementTransaction{
+			Date:       tx.Date,
+			Type:       tx.Type,
+			Symbol:     tx.Symbol,
+			Quantity:   tx.Quantity,
+			Price:      tx.Price,
+			Commission: tx.Commission,
+			Amount:     statementAmount(tx),
+			Notes:      tx.Notes,
+		})
+	}
+
+	return statement, nil
+}
+
+// periodPerformance values a portfolio at the last snapshot before the period and the last
+// one in it, and links the returns of the snapshots in between. A portfolio without
+// transactions before the period opens at zero.
+func periodPerformance(
+	ctx context.Context,
+	snapshotRepo repository.PerformanceSnapshotRepository,
+	portfolioID string,
+	start, end time.Time,
+	heldBefore bool,
+	inPeriod []*models.Transaction,
+) (dto.StatementPerformance, error) {
+	performance := dto.StatementPerformance{
+		NetCashFlow: cashFlowBetweenDates(inPeriod, start.Add(-time.Nanosecond), end.Add(-time.Nanosecond)),
+	}
+
+	snapshots, err := snapshotRepo.FindByPortfolioIDAndDateRange(ctx, portfolioID, start.Add(-statementValuationLookback), end.Add(-time.Nanosecond))
+	if err != nil {
+		return performance, fmt.Errorf("failed to retrieve snapshots: %w", err)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+
+	var opening *models.PerformanceSnapshot
+	valuations := []*models.PerformanceSnapshot{}
+	for _, snapshot := range snapshots {
+		if snapshot.Date.Before(start) {
+			opening = snapshot
+			continue
+		}
+		valuations = append(valuations, snapshot)
+	}
+	if opening == nil && !heldBefore {
+		opening = &models.PerformanceSnapshot{Date: start.Add(-time.Nanosecond), TotalValue: decimal.Zero}
+	}
+
+	if opening != nil {
+		startingValue := opening.TotalValue
+		performance.StartingValue = &startingValue
+	}
+	if len(valuations) == 0 {
+		return performance, nil
+	}
+	endingValue := valuations[len(valuations)-1].TotalValue
+	performance.EndingValue = &endingValue
+	if opening == nil {
+		return performance, nil
+	}
+
+	gain := endingValue.Sub(*performance.StartingValue).Sub(performance.NetCashFlow)
+	performance.InvestmentGain = &gain
+
+	subPeriods, twr := twrSubPeriods(append([]*models.PerformanceSnapshot{opening}, valuations...), inPeriod)
+	for _, period := range subPeriods {
+		if !period.Excluded {
+			twrPercent := twr.Mul(decimal.NewFromInt(100))
+			performance.TWRPercent = &twrPercent
+			break
+		}
+	}
+
+	return performance, nil
+}
+
+// statementIncome totals the income among transactions, cash and reinvested dividends along
+// with income transactions, by symbol and by category
+func statementIncome(transactions []*models.Transaction) dto.StatementIncome {
+	income := dto.StatementIncome{
+		Total:      decimal.Zero,
+		BySymbol:   []dto.StatementIncomeLine{},
+		ByCategory: []dto.StatementIncomeCategory{},
+	}
+	bySymbol := make(map[string]int)
+	byCategory := make(map[string]int)
+	for _, tx := range transactions {
+		category := tx.IncomeCategory()
+		if category == "" {
+			continue
+		}
+		amount := statementAmount(tx)
+
+		i, ok := bySymbol[tx.Symbol]
+		if !ok {
+			i = len(income.BySymbol)
+			bySymbol[tx.Symbol] = i
+			income.BySymbol = append(income.BySymbol, dto.StatementIncomeLine{Symbol: tx.Symbol, Amount: decimal.Zero})
+		}
+		income.BySymbol[i].Amount = income.BySymbol[i].Amount.Add(amount)
+		income.BySymbol[i].Payments++
+
+		i, ok = byCategory[category]
+		if !ok {
+			i = len(income.ByCategory)
+			byCategory[category] = i
+			income.ByCategory = append(income.ByCategory, dto.StatementIncomeCategory{Category: category, Amount: decimal.Zero})
+		}
+		income.ByCategory[i].Amount = income.ByCategory[i].Amount.Add(amount)
+		income.ByCategory[i].Payments++
+
+		income.Total = income.Total.Add(amount)
+	}
+
+	sort.Slice(income.BySymbol, func(i, j int) bool {
+		return income.BySymbol[i].Symbol < income.BySymbol[j].Symbol
+	})
+	sort.Slice(income.ByCategory, func(i, j int) bool {
+		return income.ByCategory[i].Category < income.ByCategory[j].Category
+	})
+	return income
+}
+
+// statementAmount is the money a transaction moved: the total cost of acquisitions, the
+// proceeds of sales and the amount of dividends, other income, fees, deposits and
+// withdrawals. Corporate actions move none.
+func statementAmount(tx *models.Transaction) decimal.Decimal {
+	switch {
+	case tx.Type == models.TransactionTypeDividend || tx.IsIncome() || tx.IsFee() || tx.IsCashFlow():
+		// Dividend, income, fee and cash flow transactions record the total amount as their quantity
+		return tx.Quantity
+	case tx.IsBuy():
+		return tx.GetTotalCost()
+	case tx.IsSell():
+		return tx.GetProceeds()
+	default:
+		return decimal.Zero
+	}
+}
+
+// parseStatementPeriod parses a month (2024-11) or quarter (2024-Q4) into its title and the
+// half-open range of days it covers, cut off after today
+func parseStatementPeriod(period string, now time.Time) (title string, start, end time.Time, err error) {
+	if match := statementMonthPattern.FindStringSubmatch(period); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		month, _ := strconv.Atoi(match[2])
+		start = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 1, 0)
+		title = "Monthly statement, " + start.Format("January 2006")
+	} else if match := statementQuarterPattern.FindStringSubmatch(period); match != nil {
+		year, _ := strconv.Atoi(match[1])
+		quarter, _ := strconv.Atoi(match[2])
+		start = time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, time.UTC)
+		end = start.AddDate(0, 3, 0)
+		title = fmt.Sprintf("Quarterly statement, Q%d %d", quarter, year)
+	} else {
+		return "", time.Time{}, time.Time{}, models.ErrInvalidStatementPeriod
+	}
+
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+	if !start.Before(tomorrow) {
+		return "", time.Time{}, time.Time{}, models.ErrInvalidStatementPeriod
+	}
+	if end.After(tomorrow) {
+		end = tomorrow
+	}
+	return title, start, end, nil
+}
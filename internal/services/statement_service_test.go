@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupStatementTest(t *testing.T) (*gorm.DB, *statementService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.PerformanceSnapshot{}))
+
+	service := NewStatementService(
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+	).(*statementService)
+	service.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	return db, service
+}
+
+func TestStatementService_Generate(t *testing.T) {
+	db, service := setupStatementTest(t)
+	ctx := context.Background()
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Retirement", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	for date, value := range map[time.Time]int64{
+		time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC):  1050,
+		time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC): 1500,
+	} {
+		require.NoError(t, db.Create(&models.PerformanceSnapshot{
+			PortfolioID: portfolio.ID,
+			Date:        date,
+			TotalValue:  decimal.NewFromInt(value),
+		}).Error)
+	}
+
+	transaction := func(txType models.TransactionType, date time.Time, quantity, price int64) {
+		tx := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        txType,
+			Symbol:      "VTI",
+			Date:        date,
+			Quantity:    decimal.NewFromInt(quantity),
+		}
+		if price > 0 {
+			p := decimal.NewFromInt(price)
+			tx.Price = &p
+		}
+		require.NoError(t, db.Create(tx).Error)
+	}
+	transaction(models.TransactionTypeBuy, time.Date(2024, 9, 15, 0, 0, 0, 0, time.UTC), 10, 100)
+	transaction(models.TransactionTypeBuy, time.Date(2024, 10, 10, 0, 0, 0, 0, time.UTC), 5, 110)
+	transaction(models.TransactionTypeDividend, time.Date(2024, 11, 5, 0, 0, 0, 0, time.UTC), 12, 0)
+	transaction(models.TransactionTypeSell, time.Date(2024, 12, 2, 0, 0, 0, 0, time.UTC), 3, 120)
+	transaction(models.TransactionTypeBuy, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), 1, 130)
+
+	statement, err := service.Generate(ctx, portfolio.ID.String(), user.ID.String(), "2024-q4")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Retirement", statement.Portfolio.Name)
+	assert.Equal(t, "2024-Q4", statement.Period)
+	assert.Equal(t, "Quarterly statement, Q4 2024", statement.Title)
+	assert.Equal(t, time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), statement.StartDate)
+	assert.Equal(t, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), statement.EndDate)
+
+	// The January buy is after the period and the September one before it
+	require.Len(t, statement.Transactions, 3)
+	assert.Equal(t, models.TransactionTypeBuy, statement.Transactions[0].Type)
+	assert.True(t, decimal.NewFromInt(550).Equal(statement.Transactions[0].Amount))
+	assert.True(t, decimal.NewFromInt(12).Equal(statement.Transactions[1].Amount))
+	assert.True(t, decimal.NewFromInt(360).Equal(statement.Transactions[2].Amount))
+
+	require.Len(t, statement.Holdings, 1)
+	assert.Equal(t, "VTI", statement.Holdings[0].Symbol)
+	assert.True(t, decimal.NewFromInt(12).Equal(statement.Holdings[0].Quantity))
+	// Holdings carry the average cost of the 15 shares bought: 1550 / 15 * 12
+	assert.Equal(t, "1240.00", statement.Holdings[0].CostBasis.StringFixed(2))
+
+	// Bought 550, sold 360: 1500 - 1050 - 190 = 260
+	performance := statement.Performance
+	require.NotNil(t, performance.StartingValue)
+	require.NotNil(t, performance.EndingValue)
+	require.NotNil(t, performance.InvestmentGain)
+	require.NotNil(t, performance.TWRPercent)
+	assert.True(t, decimal.NewFromInt(190).Equal(performance.NetCashFlow))
+	assert.True(t, decimal.NewFromInt(260).Equal(*performance.InvestmentGain))
+	assert.Equal(t, "24.76", performance.TWRPercent.StringFixed(2))
+
+	assert.True(t, decimal.NewFromInt(12).Equal(statement.Income.Total))
+	require.Len(t, statement.Income.BySymbol, 1)
+	assert.Equal(t, 1, statement.Income.BySymbol[0].Payments)
+
+	t.Run("without valuations", func(t *testing.T) {
+		statement, err := service.Generate(ctx, portfolio.ID.String(), user.ID.String(), "2024-08")
+		require.NoError(t, err)
+		assert.Empty(t, statement.Holdings)
+		assert.Empty(t, statement.Transactions)
+		assert.Nil(t, statement.Performance.EndingValue)
+		assert.Nil(t, statement.Performance.TWRPercent)
+	})
+
+	t.Run("current month ends today", func(t *testing.T) {
+		statement, err := service.Generate(ctx, portfolio.ID.String(), user.ID.String(), "2025-01")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), statement.EndDate)
+		require.Len(t, statement.Transactions, 1)
+		assert.True(t, decimal.NewFromInt(13).Equal(statement.Holdings[0].Quantity))
+	})
+
+	t.Run("written in the user's locale", func(t *testing.T) {
+		assert.Equal(t, "en-US", statement.Locale)
+		assert.Equal(t, "UTC", statement.Timezone)
+
+		require.NoError(t, db.AutoMigrate(&models.UserSettings{}))
+		settings := NewUserSettingsService(repository.NewUserSettingsRepository(db))
+		locale, timezone := "fr-CA", "America/Montreal"
+		_, err := settings.Update(ctx, user.ID.String(), &dto.UpdateUserSettingsRequest{Locale: &locale, Timezone: &timezone})
+		require.NoError(t, err)
+		service.settings = settings
+		defer func() { service.settings = nil }()
+
+		statement, err := service.Generate(ctx, portfolio.ID.String(), user.ID.String(), "2024-Q4")
+		require.NoError(t, err)
+		assert.Equal(t, "fr-CA", statement.Locale)
+		assert.Equal(t, "America/Montreal", statement.Timezone)
+	})
+
+	t.Run("other user's portfolio", func(t *testing.T) {
+		_, err := service.Generate(ctx, portfolio.ID.String(), uuid.New().String(), "2024-Q4")
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+	})
+
+	t.Run("unknown portfolio", func(t *testing.T) {
+		_, err := service.Generate(ctx, uuid.New().String(), user.ID.String(), "2024-Q4")
+		assert.ErrorIs(t, err, models.ErrPortfolioNotFound)
+	})
+}
+
+func TestParseStatementPeriod(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	title, start, end, err := parseStatementPeriod("2024-11", now)
+	require.NoError(t, err)
+	assert.Equal(t, "Monthly statement, November 2024", title)
+	assert.Equal(t, time.Date(2024, 11, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), end)
+
+	title, start, end, err = parseStatementPeriod("2025-Q1", now)
+	require.NoError(t, err)
+	assert.Equal(t, "Quarterly statement, Q1 2025", title)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), end)
+
+	for _, period := range []string{"", "2024-13", "2024-1", "24-Q1", "2024-Q5", "2025-02", "2025-Q2"} {
+		_, _, _, err := parseStatementPeriod(period, now)
+		assert.ErrorIs(t, err, models.ErrInvalidStatementPeriod, period)
+	}
+}
+
+func TestStatementIncome(t *testing.T) {
+	price := decimal.NewFromInt(50)
+	income := statementIncome([]*models.Transaction{
+		{Type: models.TransactionTypeDividend, Symbol: "VTI", Quantity: decimal.NewFromInt(12)},
+		{Type: models.TransactionTypeDividendReinvest, Symbol: "VTI", Quantity: decimal.NewFromInt(1), Price: &price},
+		{Type: models.TransactionTypeLendingIncome, Symbol: "GME", Quantity: decimal.NewFromInt(3), Category: "FULLY_PAID_LENDING"},
+		{Type: models.TransactionTypeInterest, Symbol: "SGOV", Quantity: decimal.NewFromInt(4)},
+		{Type: models.TransactionTypeBuy, Symbol: "VTI", Quantity: decimal.NewFromInt(2), Price: &price},
+		{Type: models.TransactionTypeFXFee, Symbol: "VTI", Quantity: decimal.NewFromInt(1)},
+	})
+
+	assert.True(t, decimal.NewFromInt(69).Equal(income.Total), income.Total.String())
+	require.Len(t, income.BySymbol, 3)
+	assert.Equal(t, "VTI", income.BySymbol[2].Symbol)
+	assert.Equal(t, 2, income.BySymbol[2].Payments)
+
+	require.Len(t, income.ByCategory, 3)
+	assert.Equal(t, models.IncomeCategoryDividend, income.ByCategory[0].Category)
+	assert.True(t, decimal.NewFromInt(62).Equal(income.ByCategory[0].Amount))
+	assert.Equal(t, "FULLY_PAID_LENDING", income.ByCategory[1].Category)
+	assert.Equal(t, string(models.TransactionTypeInterest), income.ByCategory[2].Category)
+}
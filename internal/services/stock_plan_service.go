@@ -0,0 +1,468 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// StockPlanService defines the interface for employer stock plan operations
+type StockPlanService interface {
+	// Grant management
+	CreateGrant(ctx context.Context, portfolioID, userID string, grant *models.StockPlanGrant) (*models.StockPlanGrant, error)
+	GetGrant(ctx context.Context, id, portfolioID, userID string) (*models.StockPlanGrant, error)
+	GetGrants(ctx context.Context, portfolioID, userID string) ([]*models.StockPlanGrant, error)
+	GetGrantEvents(ctx context.Context, id, portfolioID, userID string) ([]*models.StockPlanEvent, error)
+	DeleteGrant(ctx context.Context, id, portfolioID, userID string) error
+
+	// Share acquisition events
+	RecordRSUVest(ctx context.Context, grantID, portfolioID, userID string, input RSUVestInput) (*models.StockPlanEvent, error)
+	RecordESPPPurchase(ctx context.Context, grantID, portfolioID, userID string, input ESPPPurchaseInput) (*models.StockPlanEvent, error)
+	RecordOptionExercise(ctx context.Context, grantID, portfolioID, userID string, input OptionExerciseInput) (*models.StockPlanEvent, error)
+
+	// Vesting calendar
+	GetVestingCalendar(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) ([]*VestingCalendarEntry, error)
+}
+
+// RSUVestInput holds the details of an RSU vest
+type RSUVestInput struct {
+	Date            time.Time
+	Shares          decimal.Decimal // Gross shares vested
+	SharesWithheld  decimal.Decimal // Shares withheld to cover taxes
+	FairMarketValue decimal.Decimal // FMV per share on the vest date
+}
+
+// ESPPPurchaseInput holds the details of an ESPP purchase
+type ESPPPurchaseInput struct {
+	Date            time.Time
+	Shares          decimal.Decimal
+	OfferingDateFMV decimal.Decimal // FMV per share at the start of the offering period (used for lookback)
+	PurchaseDateFMV decimal.Decimal // FMV per share on the purchase date
+}
+
+// OptionExerciseInput holds the details of an option exercise
+type OptionExerciseInput struct {
+	Date            time.Time
+	Shares          decimal.Decimal
+	FairMarketValue decimal.Decimal // FMV per share on the exercise date
+}
+
+// VestingCalendarEntry represents a scheduled vest for a grant within a date range
+type VestingCalendarEntry struct {
+	GrantID          uuid.UUID            `json:"grant_id"`
+	Symbol           string               `json:"symbol"`
+	Type             models.StockPlanType `json:"type"`
+	Date             time.Time            `json:"date"`
+	Shares           decimal.Decimal      `json:"shares"`
+	CumulativeShares decimal.Decimal      `json:"cumulative_shares"`
+	TotalShares      decimal.Decimal      `json:"total_shares"`
+}
+
+// stockPlanService implements StockPlanService interface
+type stockPlanService struct {
+	db            *gorm.DB
+	stockPlanRepo repository.StockPlanRepository
+	portfolioRepo repository.PortfolioRepository
+}
+
+// NewStockPlanService creates a new StockPlanService instance. The shares a stock plan event
+// acquires are recorded in a single database transaction.
+func NewStockPlanService(db *gorm.DB) StockPlanService {
+	return &stockPlanService{
+		db:            db,
+		stockPlanRepo: repository.NewStockPlanRepository(db),
+		portfolioRepo: repository.NewPortfolioRepository(db),
+	}
+}
+
+// verifyPortfolioAccess verifies that the portfolio exists and belongs to the user
+func (s *stockPlanService) verifyPortfolioAccess(ctx context.Context, portfolioID, userID string) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	return portfolio, nil
+}
+
+// CreateGrant creates a new stock plan grant in a portfolio
+func (s *stockPlanService) CreateGrant(ctx context.Context, portfolioID, userID string, grant *models.StockPlanGrant) (*models.StockPlanGrant, error) {
+	portfolio, err := s.verifyPortfolioAccess(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	grant.PortfolioID = portfolio.ID
+	if err := grant.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.stockPlanRepo.CreateGrant(ctx, grant); err != nil {
+		return nil, fmt.Errorf("failed to create stock plan grant: %w", err)
+	}
+
+	return grant, nil
+}
+
+// GetGrant retrieves a grant, ensuring it belongs to the user's portfolio
+func (s *stockPlanService) GetGrant(ctx context.Context, id, portfolioID, userID string) (*models.StockPlanGrant, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	grant, err := s.stockPlanRepo.FindGrantByID(ctx, id)
+	if err != nil {
+		return nil, models.ErrStockPlanGrantNotFound
+	}
+	if grant.PortfolioID.String() != portfolioID {
+		return nil, models.ErrStockPlanGrantNotFound
+	}
+
+	return grant, nil
+}
+
+// GetGrants retrieves all grants for a portfolio
+func (s *stockPlanService) GetGrants(ctx context.Context, portfolioID, userID string) ([]*models.StockPlanGrant, error) {
+	if _, err := s.verifyPortfolioAccess(ctx, portfolioID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.stockPlanRepo.FindGrantsByPortfolioID(ctx, portfolioID)
+}
+
+// GetGrantEvents retrieves the recorded vest, purchase, and exercise events for a grant
+func (s *stockPlanService) GetGrantEvents(ctx context.Context, id, portfolioID, userID string) ([]*models.StockPlanEvent, error) {
+	grant, err := s.GetGrant(ctx, id, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.stockPlanRepo.FindEventsByGrantID(ctx, grant.ID.String())
+}
+
+// DeleteGrant deletes a grant. Transactions and tax lots created by its events are kept.
+func (s *stockPlanService) DeleteGrant(ctx context.Context, id, portfolioID, userID string) error {
+	grant, err := s.GetGrant(ctx, id, portfolioID, userID)
+	if err != nil {
+		return err
+	}
+
+	return s.stockPlanRepo.DeleteGrant(ctx, grant.ID.String())
+}
+
+// RecordRSUVest records an RSU vest. The gross shares are taxed as ordinary income at FMV,
+// and the net shares (after tax withholding) are added to the portfolio with a cost basis at FMV.
+func (s *stockPlanService) RecordRSUVest(ctx context.Context, grantID, portfolioID, userID string, input RSUVestInput) (*models.StockPlanEvent, error) {
+	grant, err := s.GetGrant(ctx, grantID, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Type != models.StockPlanTypeRSU {
+		return nil, models.ErrStockPlanTypeMismatch
+	}
+	if input.Date.IsZero() {
+		return nil, models.ErrInvalidDate
+	}
+	if input.Shares.LessThanOrEqual(decimal.Zero) {
+		return nil, models.ErrInvalidQuantity
+	}
+	if input.SharesWithheld.IsNegative() || input.SharesWithheld.GreaterThanOrEqual(input.Shares) {
+		return nil, models.ErrInvalidQuantity
+	}
+	if input.FairMarketValue.LessThanOrEqual(decimal.Zero) {
+		return nil, models.ErrInvalidPrice
+	}
+
+	if err := s.checkRemainingShares(ctx, grant, input.Shares, grant.TotalShares); err != nil {
+		return nil, err
+	}
+
+	netShares := input.Shares.Sub(input.SharesWithheld)
+	costBasis := netShares.Mul(input.FairMarketValue)
+
+	event := &models.StockPlanEvent{
+		Type:            models.StockPlanEventVest,
+		Date:            input.Date,
+		Shares:          input.Shares,
+		SharesWithheld:  input.SharesWithheld,
+		FairMarketValue: input.FairMarketValue,
+		CostBasis:       costBasis,
+		OrdinaryIncome:  input.Shares.Mul(input.FairMarketValue),
+	}
+
+	notes := fmt.Sprintf("RSU vest: %s shares, %s withheld for taxes", input.Shares.String(), input.SharesWithheld.String())
+	if err := s.recordAcquisition(ctx, grant, event, models.TransactionTypeRSUVest, netShares, input.FairMarketValue, notes); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// RecordESPPPurchase records an ESPP purchase. The purchase price is the discounted FMV on the
+// purchase date, or the discounted lower of the offering and purchase date FMVs when the plan
+// has a lookback provision. The discount is recorded as the bargain element; ordinary income is
+// recognized when the shares are sold, so none is recorded here.
+func (s *stockPlanService) RecordESPPPurchase(ctx context.Context, grantID, portfolioID, userID string, input ESPPPurchaseInput) (*models.StockPlanEvent, error) {
+	grant, err := s.GetGrant(ctx, grantID, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if grant.Type != models.StockPlanTypeESPP {
+		return nil, models.ErrStockPlanTypeMismatch
+	}
+	if input.Date.IsZero() {
+		return nil, models.ErrInvalidDate
+	}
+	if input.Shares.LessThanOrEqual(decimal.Zero) {
+		return nil, models.ErrInvalidQuantity
+	}
+	if input.PurchaseDateFMV.LessThanOrEqual(decimal.Zero) || input.OfferingDateFMV.IsNegative() {
+		return nil, models.ErrInvalidPrice
+	}
+
+	if err := s.checkRemainingShares(ctx, grant, input.Shares, grant.TotalShares); err != nil {
+		return nil, err
+	}
+
+	purchasePrice := CalculateESPPPurchasePrice(grant, input.OfferingDateFMV, input.PurchaseDateFMV)
+
+	event := &models.StockPlanEvent{
+		Type:            models.StockPlanEventESPPPurchase,
+		Date:            input.Date,
+		Shares:          input.Shares,
+		FairMarketValue: input.PurchaseDateFMV,
+		PurchasePrice:   purchasePrice,
+		CostBasis:       input.Shares.Mul(purchasePrice),
+		BargainElement:  input.PurchaseDateFMV.Sub(purchasePrice).Mul(input.Shares),
+	}
+
+	notes := fmt.Sprintf("ESPP purchase at %s (FMV %s)", purchasePrice.String(), input.PurchaseDateFMV.String())
+	if err := s.recordAcquisition(ctx, grant, event, models.TransactionTypeESPPPurchase, input.Shares, purchasePrice, notes); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// CalculateESPPPurchasePrice calculates the per-share ESPP purchase price for a grant
+func CalculateESPPPurchasePrice(grant *models.StockPlanGrant, offeringDateFMV, purchaseDateFMV decimal.Decimal) decimal.Decimal {
+	base := purchaseDateFMV
+	if grant.Lookback && offeringDateFMV.IsPositive() && offeringDateFMV.LessThan(purchaseDateFMV) {
+		base = offeringDateFMV
+	}
+
+	if grant.DiscountPercent == nil || grant.DiscountPercent.IsZero() {
+		return base
+	}
+
+	hundred := decimal.NewFromInt(100)
+	return base.Mul(hundred.Sub(*grant.DiscountPercent)).Div(hundred).Round(4)
+}
+
+// RecordOptionExercise records an ISO or NSO exercise. For NSOs the spread between FMV and strike
+// is ordinary income and the cost basis is FMV; for ISOs the spread is an AMT adjustment and the
+// regular tax cost basis is the strike price.
+func (s *stockPlanService) RecordOptionExercise(ctx context.Context, grantID, portfolioID, userID string, input OptionExerciseInput) (*models.StockPlanEvent, error) {
+	grant, err := s.GetGrant(ctx, grantID, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !grant.IsOption() || grant.StrikePrice == nil {
+		return nil, models.ErrStockPlanTypeMismatch
+	}
+	if input.Date.IsZero() {
+		return nil, models.ErrInvalidDate
+	}
+	if grant.ExpirationDate != nil && input.Date.After(*grant.ExpirationDate) {
+		return nil, models.ErrStockPlanGrantExpired
+	}
+	if input.Shares.LessThanOrEqual(decimal.Zero) {
+		return nil, models.ErrInvalidQuantity
+	}
+	if input.FairMarketValue.LessThanOrEqual(decimal.Zero) {
+		return nil, models.ErrInvalidPrice
+	}
+
+	// Only vested options can be exercised
+	if err := s.checkRemainingShares(ctx, grant, input.Shares, grant.VestedShares(input.Date)); err != nil {
+		return nil, err
+	}
+
+	strike := *grant.StrikePrice
+	spread := decimal.Max(input.FairMarketValue.Sub(strike), decimal.Zero).Mul(input.Shares)
+
+	event := &models.StockPlanEvent{
+		Type:            models.StockPlanEventExercise,
+		Date:            input.Date,
+		Shares:          input.Shares,
+		FairMarketValue: input.FairMarketValue,
+		PurchasePrice:   strike,
+		BargainElement:  spread,
+	}
+
+	basisPerShare := strike
+	if grant.Type == models.StockPlanTypeNSO {
+		basisPerShare = input.FairMarketValue
+		event.OrdinaryIncome = spread
+	} else {
+		event.AMTAdjustment = spread
+	}
+	event.CostBasis = basisPerShare.Mul(input.Shares)
+
+	notes := fmt.Sprintf("%s exercise at strike %s (FMV %s)", grant.Type, strike.String(), input.FairMarketValue.String())
+	if err := s.recordAcquisition(ctx, grant, event, models.TransactionTypeOptionExercise, input.Shares, basisPerShare, notes); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// GetVestingCalendar returns scheduled RSU and option vests for a portfolio within a date range
+func (s *stockPlanService) GetVestingCalendar(ctx context.Context, portfolioID, userID string, startDate, endDate time.Time) ([]*VestingCalendarEntry, error) {
+	grants, err := s.GetGrants(ctx, portfolioID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []*VestingCalendarEntry{}
+	for _, grant := range grants {
+		// ESPP shares are purchased, not vested
+		if grant.Type == models.StockPlanTypeESPP {
+			continue
+		}
+
+		for _, event := range grant.VestingSchedule() {
+			if event.Date.Before(startDate) || event.Date.After(endDate) {
+				continue
+			}
+			entries = append(entries, &VestingCalendarEntry{
+				GrantID:          grant.ID,
+				Symbol:           grant.Symbol,
+				Type:             grant.Type,
+				Date:             event.Date,
+				Shares:           event.Shares,
+				CumulativeShares: event.CumulativeShares,
+				TotalShares:      grant.TotalShares,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	return entries, nil
+}
+
+// checkRemainingShares verifies that recording the given shares would not exceed the limit
+// once shares from previously recorded events are counted
+func (s *stockPlanService) checkRemainingShares(ctx context.Context, grant *models.StockPlanGrant, shares, limit decimal.Decimal) error {
+	events, err := s.stockPlanRepo.FindEventsByGrantID(ctx, grant.ID.String())
+	if err != nil {
+		return fmt.Errorf("failed to get stock plan events: %w", err)
+	}
+
+	recorded := decimal.Zero
+	for _, event := range events {
+		recorded = recorded.Add(event.Shares)
+	}
+
+	if recorded.Add(shares).GreaterThan(limit) {
+		return models.ErrInsufficientVestedShares
+	}
+
+	return nil
+}
+
+// recordAcquisition creates the transaction, holding update, and tax lot for shares acquired
+// through a stock plan event, then saves the event itself, all in one database transaction
+func (s *stockPlanService) recordAcquisition(
+	ctx context.Context,
+	grant *models.StockPlanGrant,
+	event *models.StockPlanEvent,
+	transactionType models.TransactionType,
+	quantity, pricePerShare decimal.Decimal,
+	notes string,
+) error {
+	var pricePtr *decimal.Decimal
+	if !pricePerShare.IsZero() {
+		pricePtr = &pricePerShare
+	}
+
+	transaction := &models.Transaction{
+		PortfolioID: grant.PortfolioID,
+		Type:        transactionType,
+		Symbol:      grant.Symbol,
+		Date:        event.Date,
+		Quantity:    quantity,
+		Price:       pricePtr,
+		Commission:  decimal.Zero,
+		Notes:       notes,
+	}
+
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewTransactionRepository(tx).Create(ctx, transaction); err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+
+		if err := addToHolding(ctx, repository.NewHoldingRepository(tx), transaction); err != nil {
+			return fmt.Errorf("failed to update holdings: %w", err)
+		}
+
+		taxLot := &models.TaxLot{
+			PortfolioID:   grant.PortfolioID,
+			Symbol:        grant.Symbol,
+			PurchaseDate:  event.Date,
+			Quantity:      quantity,
+			CostBasis:     transaction.GetTotalCost(),
+			TransactionID: transaction.ID,
+		}
+		if err := repository.NewTaxLotRepository(tx).Create(ctx, taxLot); err != nil {
+			return fmt.Errorf("failed to create tax lot: %w", err)
+		}
+
+		event.GrantID = grant.ID
+		event.PortfolioID = grant.PortfolioID
+		event.TransactionID = transaction.ID
+		if err := repository.NewStockPlanRepository(tx).CreateEvent(ctx, event); err != nil {
+			return fmt.Errorf("failed to create stock plan event: %w", err)
+		}
+		return nil
+	})
+}
+
+// addToHolding adds the transaction's shares to the portfolio holding, creating it if needed
+func addToHolding(ctx context.Context, holdingRepo repository.HoldingRepository, transaction *models.Transaction) error {
+	totalCost := transaction.GetTotalCost()
+
+	holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, transaction.PortfolioID.String(), transaction.Symbol)
+	if err == models.ErrHoldingNotFound {
+		return holdingRepo.Create(ctx, &models.Holding{
+			PortfolioID:  transaction.PortfolioID,
+			Symbol:       transaction.Symbol,
+			Quantity:     transaction.Quantity,
+			CostBasis:    totalCost,
+			AvgCostPrice: totalCost.Div(transaction.Quantity),
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get holding: %w", err)
+	}
+
+	holding.AddShares(transaction.Quantity, totalCost)
+	return holdingRepo.Update(ctx, holding)
+}
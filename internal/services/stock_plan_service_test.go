@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func setupStockPlanServiceTest(t *testing.T) (StockPlanService, *gorm.DB, *models.User, *models.Portfolio) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.Holding{},
+		&models.TaxLot{}, &models.StockPlanGrant{}, &models.StockPlanEvent{})
+	require.NoError(t, err)
+
+	service := NewStockPlanService(db)
+
+	user, portfolio := createTestUserAndPortfolio(t, db)
+	return service, db, user, portfolio
+}
+
+func createTestGrant(t *testing.T, service StockPlanService, user *models.User, portfolio *models.Portfolio, grant *models.StockPlanGrant) *models.StockPlanGrant {
+	ctx := context.Background()
+
+	created, err := service.CreateGrant(ctx, portfolio.ID.String(), user.ID.String(), grant)
+	require.NoError(t, err)
+	return created
+}
+
+func TestStockPlanService_CreateGrant(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, user, portfolio := setupStockPlanServiceTest(t)
+
+	t.Run("valid grant", func(t *testing.T) {
+		grant, err := service.CreateGrant(ctx, portfolio.ID.String(), user.ID.String(), &models.StockPlanGrant{
+			Symbol:        "ACME",
+			Type:          models.StockPlanTypeRSU,
+			GrantDate:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			TotalShares:   decimal.NewFromInt(400),
+			VestingMonths: 48,
+			CliffMonths:   12,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, portfolio.ID, grant.PortfolioID)
+	})
+
+	t.Run("invalid grant", func(t *testing.T) {
+		_, err := service.CreateGrant(ctx, portfolio.ID.String(), user.ID.String(), &models.StockPlanGrant{
+			Symbol:      "ACME",
+			Type:        models.StockPlanTypeISO,
+			GrantDate:   time.Now(),
+			TotalShares: decimal.NewFromInt(100),
+		})
+		assert.Equal(t, models.ErrInvalidPrice, err)
+	})
+
+	t.Run("unauthorized user", func(t *testing.T) {
+		_, err := service.CreateGrant(ctx, portfolio.ID.String(), uuid.New().String(), &models.StockPlanGrant{})
+		assert.Equal(t, models.ErrUnauthorizedAccess, err)
+	})
+}
+
+func TestStockPlanService_RecordRSUVest(t *testing.T) {
+	ctx := context.Background()
+
+	service, db, user, portfolio := setupStockPlanServiceTest(t)
+
+	grant := createTestGrant(t, service, user, portfolio, &models.StockPlanGrant{
+		Symbol:      "ACME",
+		Type:        models.StockPlanTypeRSU,
+		GrantDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		TotalShares: decimal.NewFromInt(100),
+	})
+
+	event, err := service.RecordRSUVest(ctx, grant.ID.String(), portfolio.ID.String(), user.ID.String(), RSUVestInput{
+		Date:            time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Shares:          decimal.NewFromInt(100),
+		SharesWithheld:  decimal.NewFromInt(35),
+		FairMarketValue: decimal.NewFromInt(50),
+	})
+	require.NoError(t, err)
+
+	// Income is recognized on all vested shares; basis only on shares kept
+	assert.True(t, decimal.NewFromInt(5000).Equal(event.OrdinaryIncome))
+	assert.True(t, decimal.NewFromInt(3250).Equal(event.CostBasis))
+
+	var holding models.Holding
+	require.NoError(t, db.Where("portfolio_id = ? AND symbol = ?", portfolio.ID, "ACME").First(&holding).Error)
+	assert.True(t, decimal.NewFromInt(65).Equal(holding.Quantity))
+	assert.True(t, decimal.NewFromInt(50).Equal(holding.AvgCostPrice))
+
+	var lots []models.TaxLot
+	require.NoError(t, db.Where("transaction_id = ?", event.TransactionID).Find(&lots).Error)
+	require.Len(t, lots, 1)
+	assert.True(t, decimal.NewFromInt(3250).Equal(lots[0].CostBasis))
+
+	t.Run("cannot vest more than granted", func(t *testing.T) {
+		_, err := service.RecordRSUVest(ctx, grant.ID.String(), portfolio.ID.String(), user.ID.String(), RSUVestInput{
+			Date:            time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			Shares:          decimal.NewFromInt(1),
+			FairMarketValue: decimal.NewFromInt(50),
+		})
+		assert.Equal(t, models.ErrInsufficientVestedShares, err)
+	})
+}
+
+func TestStockPlanService_RecordRSUVest_FailedWriteRecordsNothing(t *testing.T) {
+	ctx := context.Background()
+
+	service, db, user, portfolio := setupStockPlanServiceTest(t)
+	grant := createTestGrant(t, service, user, portfolio, &models.StockPlanGrant{
+		Symbol:      "ACME",
+		Type:        models.StockPlanTypeRSU,
+		GrantDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		TotalShares: decimal.NewFromInt(100),
+	})
+
+	// Saving the event, the last write, fails
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("fail_stock_plan_events", func(tx *gorm.DB) {
+		if tx.Statement.Table == "stock_plan_events" {
+			_ = tx.AddError(errors.New("disk full"))
+		}
+	}))
+
+	_, err := service.RecordRSUVest(ctx, grant.ID.String(), portfolio.ID.String(), user.ID.String(), RSUVestInput{
+		Date:            time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Shares:          decimal.NewFromInt(100),
+		FairMarketValue: decimal.NewFromInt(50),
+	})
+	require.Error(t, err)
+
+	// The transaction, holding and tax lot written before it are rolled back with it
+	for _, model := range []interface{}{&models.Transaction{}, &models.Holding{}, &models.TaxLot{}} {
+		var count int64
+		require.NoError(t, db.Model(model).Where("portfolio_id = ?", portfolio.ID).Count(&count).Error)
+		assert.Zero(t, count, "%T", model)
+	}
+}
+
+func TestStockPlanService_RecordESPPPurchase(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, user, portfolio := setupStockPlanServiceTest(t)
+
+	discount := decimal.NewFromInt(15)
+	grant := createTestGrant(t, service, user, portfolio, &models.StockPlanGrant{
+		Symbol:          "ACME",
+		Type:            models.StockPlanTypeESPP,
+		GrantDate:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		TotalShares:     decimal.NewFromInt(1000),
+		DiscountPercent: &discount,
+		Lookback:        true,
+	})
+
+	// Lookback uses the lower offering date price: 40 * 0.85 = 34
+	event, err := service.RecordESPPPurchase(ctx, grant.ID.String(), portfolio.ID.String(), user.ID.String(), ESPPPurchaseInput{
+		Date:            time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC),
+		Shares:          decimal.NewFromInt(100),
+		OfferingDateFMV: decimal.NewFromInt(40),
+		PurchaseDateFMV: decimal.NewFromInt(50),
+	})
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(34).Equal(event.PurchasePrice))
+	assert.True(t, decimal.NewFromInt(3400).Equal(event.CostBasis))
+	assert.True(t, decimal.NewFromInt(1600).Equal(event.BargainElement))
+	assert.True(t, event.OrdinaryIncome.IsZero())
+}
+
+func TestCalculateESPPPurchasePrice(t *testing.T) {
+	discount := decimal.NewFromInt(15)
+
+	noLookback := &models.StockPlanGrant{DiscountPercent: &discount}
+	assert.True(t, decimal.NewFromFloat(42.5).Equal(CalculateESPPPurchasePrice(noLookback, decimal.NewFromInt(40), decimal.NewFromInt(50))))
+
+	// Lookback only helps when the offering price is lower
+	lookback := &models.StockPlanGrant{DiscountPercent: &discount, Lookback: true}
+	assert.True(t, decimal.NewFromFloat(42.5).Equal(CalculateESPPPurchasePrice(lookback, decimal.NewFromInt(60), decimal.NewFromInt(50))))
+
+	noDiscount := &models.StockPlanGrant{}
+	assert.True(t, decimal.NewFromInt(50).Equal(CalculateESPPPurchasePrice(noDiscount, decimal.Zero, decimal.NewFromInt(50))))
+}
+
+func TestStockPlanService_RecordOptionExercise(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, user, portfolio := setupStockPlanServiceTest(t)
+
+	strike := decimal.NewFromInt(10)
+	grantDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newOptionGrant := func(planType models.StockPlanType) *models.StockPlanGrant {
+		return createTestGrant(t, service, user, portfolio, &models.StockPlanGrant{
+			Symbol:        "ACME",
+			Type:          planType,
+			GrantDate:     grantDate,
+			TotalShares:   decimal.NewFromInt(480),
+			VestingMonths: 48,
+			CliffMonths:   12,
+			StrikePrice:   &strike,
+		})
+	}
+
+	t.Run("NSO spread is ordinary income", func(t *testing.T) {
+		grant := newOptionGrant(models.StockPlanTypeNSO)
+		event, err := service.RecordOptionExercise(ctx, grant.ID.String(), portfolio.ID.String(), user.ID.String(), OptionExerciseInput{
+			Date:            grantDate.AddDate(1, 0, 0),
+			Shares:          decimal.NewFromInt(100),
+			FairMarketValue: decimal.NewFromInt(30),
+		})
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(2000).Equal(event.OrdinaryIncome))
+		assert.True(t, event.AMTAdjustment.IsZero())
+		assert.True(t, decimal.NewFromInt(3000).Equal(event.CostBasis))
+	})
+
+	t.Run("ISO spread is an AMT adjustment", func(t *testing.T) {
+		grant := newOptionGrant(models.StockPlanTypeISO)
+		event, err := service.RecordOptionExercise(ctx, grant.ID.String(), portfolio.ID.String(), user.ID.String(), OptionExerciseInput{
+			Date:            grantDate.AddDate(1, 0, 0),
+			Shares:          decimal.NewFromInt(100),
+			FairMarketValue: decimal.NewFromInt(30),
+		})
+		require.NoError(t, err)
+		assert.True(t, event.OrdinaryIncome.IsZero())
+		assert.True(t, decimal.NewFromInt(2000).Equal(event.AMTAdjustment))
+		assert.True(t, decimal.NewFromInt(1000).Equal(event.CostBasis))
+	})
+
+	t.Run("unvested options cannot be exercised", func(t *testing.T) {
+		grant := newOptionGrant(models.StockPlanTypeISO)
+		_, err := service.RecordOptionExercise(ctx, grant.ID.String(), portfolio.ID.String(), user.ID.String(), OptionExerciseInput{
+			Date:            grantDate.AddDate(0, 6, 0),
+			Shares:          decimal.NewFromInt(1),
+			FairMarketValue: decimal.NewFromInt(30),
+		})
+		assert.Equal(t, models.ErrInsufficientVestedShares, err)
+	})
+
+	t.Run("RSU grant cannot be exercised", func(t *testing.T) {
+		grant := createTestGrant(t, service, user, portfolio, &models.StockPlanGrant{
+			Symbol:      "ACME",
+			Type:        models.StockPlanTypeRSU,
+			GrantDate:   grantDate,
+			TotalShares: decimal.NewFromInt(10),
+		})
+		_, err := service.RecordOptionExercise(ctx, grant.ID.String(), portfolio.ID.String(), user.ID.String(), OptionExerciseInput{
+			Date:            grantDate,
+			Shares:          decimal.NewFromInt(1),
+			FairMarketValue: decimal.NewFromInt(30),
+		})
+		assert.Equal(t, models.ErrStockPlanTypeMismatch, err)
+	})
+}
+
+func TestStockPlanService_GetVestingCalendar(t *testing.T) {
+	ctx := context.Background()
+
+	service, _, user, portfolio := setupStockPlanServiceTest(t)
+
+	grantDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	createTestGrant(t, service, user, portfolio, &models.StockPlanGrant{
+		Symbol:                "ACME",
+		Type:                  models.StockPlanTypeRSU,
+		GrantDate:             grantDate,
+		TotalShares:           decimal.NewFromInt(400),
+		VestingMonths:         12,
+		VestingIntervalMonths: 3,
+	})
+	createTestGrant(t, service, user, portfolio, &models.StockPlanGrant{
+		Symbol:      "ACME",
+		Type:        models.StockPlanTypeESPP,
+		GrantDate:   grantDate,
+		TotalShares: decimal.NewFromInt(100),
+	})
+
+	entries, err := service.GetVestingCalendar(ctx, portfolio.ID.String(), user.ID.String(),
+		grantDate, grantDate.AddDate(0, 7, 0))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, grantDate.AddDate(0, 3, 0), entries[0].Date)
+	assert.Equal(t, grantDate.AddDate(0, 6, 0), entries[1].Date)
+	assert.True(t, decimal.NewFromInt(200).Equal(entries[1].CumulativeShares))
+}
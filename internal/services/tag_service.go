@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type aliases for dto types for backward compatibility
+type TagPerformance = dto.TagPerformance
+type TagPortfolioPerformance = dto.TagPortfolioPerformance
+type TagPerformanceTotal = dto.TagPerformanceTotal
+
+// TagService defines the interface for tagging portfolios and transactions
+type TagService interface {
+	List(ctx context.Context, userID string) ([]*models.TagUsage, error)
+	TagPortfolios(ctx context.Context, portfolios []*models.Portfolio, filter []string) ([]*models.Portfolio, map[uuid.UUID][]string, error)
+	TagTransactions(ctx context.Context, transactions []*models.Transaction, filter []string) ([]*models.Transaction, map[uuid.UUID][]string, error)
+	SetPortfolioTags(ctx context.Context, portfolioID, userID string, tags []string) ([]string, error)
+	SetTransactionTags(ctx context.Context, transactionID, userID string, tags []string) ([]string, error)
+	GetPerformance(ctx context.Context, userID, tag string, startDate, endDate time.Time) (*TagPerformance, error)
+}
+
+// tagService implements TagService interface
+type tagService struct {
+	tagRepo          repository.TagRepository
+	portfolioRepo    repository.PortfolioRepository
+	transactionRepo  repository.TransactionRepository
+	analyticsService PerformanceAnalyticsService
+}
+
+// NewTagService creates a new TagService instance. analyticsService measures tagged
+// portfolios for GetPerformance and may be nil when market data is disabled.
+func NewTagService(
+	tagRepo repository.TagRepository,
+	portfolioRepo repository.PortfolioRepository,
+	transactionRepo repository.TransactionRepository,
+	analyticsService PerformanceAnalyticsService,
+) TagService {
+	return &tagService{
+		tagRepo:          tagRepo,
+		portfolioRepo:    portfolioRepo,
+		transactionRepo:  transactionRepo,
+		analyticsService: analyticsService,
+	}
+}
+
+// List returns the tags a user has put on portfolios or transactions, with their counts
+func (s *tagService) List(ctx context.Context, userID string) ([]*models.TagUsage, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	usages, err := s.tagRepo.FindUsageByUserID(ctx, uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tags: %w", err)
+	}
+
+	return usages, nil
+}
+
+// TagPortfolios looks up the tags of portfolios the caller has already checked access to, and
+// keeps the ones carrying every tag in filter. No filter keeps them all.
+func (s *tagService) TagPortfolios(
+	ctx context.Context,
+	portfolios []*models.Portfolio,
+	filter []string,
+) ([]*models.Portfolio, map[uuid.UUID][]string, error) {
+	filter, err := models.NormalizeTags(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, len(portfolios))
+	for i, portfolio := range portfolios {
+		ids[i] = portfolio.ID
+	}
+	tags, err := s.tagRepo.FindByPortfolioIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve portfolio tags: %w", err)
+	}
+
+	matching := make([]*models.Portfolio, 0, len(portfolios))
+	for _, portfolio := range portfolios {
+		if hasAllTags(tags[portfolio.ID], filter) {
+			matching = append(matching, portfolio)
+		}
+	}
+
+	return matching, tags, nil
+}
+
+// TagTransactions looks up the tags of transactions the caller has already checked access to,
+// and keeps the ones carrying every tag in filter. No filter keeps them all.
+func (s *tagService) TagTransactions(
+	ctx context.Context,
+	transactions []*models.Transaction,
+	filter []string,
+) ([]*models.Transaction, map[uuid.UUID][]string, error) {
+	filter, err := models.NormalizeTags(filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.ID
+	}
+	tags, err := s.tagRepo.FindByTransactionIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve transaction tags: %w", err)
+	}
+
+	matching := make([]*models.Transaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		if hasAllTags(tags[transaction.ID], filter) {
+			matching = append(matching, transaction)
+		}
+	}
+
+	return matching, tags, nil
+}
+
+// hasAllTags returns true if tags, which are sorted, include every tag in wanted
+func hasAllTags(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		if _, found := slices.BinarySearch(tags, tag); !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SetPortfolioTags replaces the tags of a portfolio, ensuring it belongs to the user, and
+// returns the normalized tags
+func (s *tagService) SetPortfolioTags(ctx context.Context, portfolioID, userID string, tags []string) ([]string, error) {
+	tags, err := models.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, err
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	if err := s.tagRepo.SetPortfolioTags(ctx, portfolio.UserID, portfolio.ID, tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// SetTransactionTags replaces the tags of a transaction, ensuring its portfolio belongs to the
+// user, and returns the normalized tags
+func (s *tagService) SetTransactionTags(ctx context.Context, transactionID, userID string, tags []string) ([]string, error) {
+	tags, err := models.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := s.transactionRepo.FindByID(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	portfolio, err := s.portfolioRepo.FindByID(ctx, transaction.PortfolioID.String())
+	if err != nil {
+		return nil, err
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+
+	if err := s.tagRepo.SetTransactionTags(ctx, portfolio.UserID, transaction.ID, tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// GetPerformance measures every portfolio of the user carrying a tag over a period and adds
+// up the results by base currency. A portfolio without enough performance history for the
+// period is listed without metrics rather than failing the whole request.
+func (s *tagService) GetPerformance(
+	ctx context.Context,
+	userID, tag string,
+	startDate, endDate time.Time,
+) (*TagPerformance, error) {
+	if s.analyticsService == nil {
+		return nil, fmt.Errorf("performance analytics are not available")
+	}
+
+	tag, err := models.NormalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	portfolios, err := s.portfolioRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve portfolios: %w", err)
+	}
+	tagged, _, err := s.TagPortfolios(ctx, portfolios, []string{tag})
+	if err != nil {
+		return nil, err
+	}
+	if len(tagged) == 0 {
+		return nil, models.ErrTagNotFound
+	}
+
+	// Report portfolios in a stable order
+	sort.Slice(tagged, func(i, j int) bool {
+		return tagged[i].Name < tagged[j].Name
+	})
+
+	result := &TagPerformance{
+		Tag:        tag,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Portfolios: make([]*TagPortfolioPerformance, 0, len(tagged)),
+		Totals:     []*TagPerformanceTotal{},
+	}
+	totals := make(map[string]*TagPerformanceTotal)
+
+	for _, portfolio := range tagged {
+		entry := &TagPortfolioPerformance{
+			PortfolioID:  portfolio.ID,
+			Name:         portfolio.Name,
+			BaseCurrency: portfolio.BaseCurrency,
+		}
+		result.Portfolios = append(result.Portfolios, entry)
+
+		metrics, err := s.analyticsService.GetPerformanceMetrics(ctx, portfolio.ID.String(), userID, startDate, endDate)
+		if err != nil {
+			// The request ending is not a problem with the portfolio
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			entry.Error = err.Error()
+			continue
+		}
+		entry.Metrics = metrics
+
+		total, ok := totals[portfolio.BaseCurrency]
+		if !ok {
+			total = &TagPerformanceTotal{Currency: portfolio.BaseCurrency}
+			totals[portfolio.BaseCurrency] = total
+			result.Totals = append(result.Totals, total)
+		}
+		total.Portfolios++
+		total.StartingValue = total.StartingValue.Add(metrics.StartingValue)
+		total.EndingValue = total.EndingValue.Add(metrics.EndingValue)
+		total.TotalReturn = total.TotalReturn.Add(metrics.TotalReturn)
+		total.TotalDeposits = total.TotalDeposits.Add(metrics.TotalDeposits)
+		total.TotalWithdrawals = total.TotalWithdrawals.Add(metrics.TotalWithdrawals)
+		total.NetCashFlow = total.NetCashFlow.Add(metrics.NetCashFlow)
+	}
+
+	for _, total := range result.Totals {
+		if !total.StartingValue.IsZero() {
+			total.TotalReturnPct = total.TotalReturn.Div(total.StartingValue).Mul(decimal.NewFromInt(100))
+		}
+	}
+	sort.Slice(result.Totals, func(i, j int) bool {
+		return result.Totals[i].Currency < result.Totals[j].Currency
+	})
+
+	return result, nil
+}
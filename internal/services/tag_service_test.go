@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupTagServiceTest(t *testing.T) (*gorm.DB, TagService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Transaction{},
+		&models.PerformanceSnapshot{}, &models.Tag{}, &models.PortfolioTag{}, &models.TransactionTag{}))
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	analytics := NewPerformanceAnalyticsService(portfolioRepo, transactionRepo,
+		repository.NewPerformanceSnapshotRepository(db), nil)
+	service := NewTagService(repository.NewTagRepository(db), portfolioRepo, transactionRepo, analytics)
+
+	return db, service
+}
+
+func createTagServicePortfolio(t *testing.T, db *gorm.DB, userID uuid.UUID, name, currency string) *models.Portfolio {
+	portfolio := &models.Portfolio{UserID: userID, Name: name, BaseCurrency: currency, CostBasisMethod: models.CostBasisFIFO}
+	require.NoError(t, db.Create(portfolio).Error)
+	return portfolio
+}
+
+func TestTagService_SetAndFilter(t *testing.T) {
+	db, service := setupTagServiceTest(t)
+	ctx := context.Background()
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	other := &models.User{Email: "other@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(other).Error)
+
+	retirement := createTagServicePortfolio(t, db, user.ID, "Retirement", "USD")
+	brokerage := createTagServicePortfolio(t, db, user.ID, "Brokerage", "USD")
+
+	tags, err := service.SetPortfolioTags(ctx, retirement.ID.String(), user.ID.String(), []string{"Retirement", "long-term "})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"long-term", "retirement"}, tags)
+	_, err = service.SetPortfolioTags(ctx, brokerage.ID.String(), user.ID.String(), []string{"long-term"})
+	require.NoError(t, err)
+
+	// Only the owner can tag a portfolio, and invalid tags are rejected
+	_, err = service.SetPortfolioTags(ctx, retirement.ID.String(), other.ID.String(), []string{"mine"})
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+	_, err = service.SetPortfolioTags(ctx, retirement.ID.String(), user.ID.String(), []string{"a,b"})
+	assert.Equal(t, models.ErrInvalidTag, err)
+
+	portfolios := []*models.Portfolio{retirement, brokerage}
+	matching, byID, err := service.TagPortfolios(ctx, portfolios, nil)
+	require.NoError(t, err)
+	assert.Len(t, matching, 2)
+	assert.Equal(t, []string{"long-term"}, byID[brokerage.ID])
+
+	matching, _, err = service.TagPortfolios(ctx, portfolios, []string{"LONG-TERM", "retirement"})
+	require.NoError(t, err)
+	require.Len(t, matching, 1)
+	assert.Equal(t, retirement.ID, matching[0].ID)
+
+	usages, err := service.List(ctx, user.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.TagUsage{
+		{Name: "long-term", Portfolios: 2},
+		{Name: "retirement", Portfolios: 1},
+	}, usages)
+}
+
+func TestTagService_GetPerformance(t *testing.T) {
+	db, service := setupTagServiceTest(t)
+	ctx := context.Background()
+
+	user := &models.User{Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	userID := user.ID.String()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	measured := []*models.Portfolio{
+		createTagServicePortfolio(t, db, user.ID, "Retirement", "USD"),
+		createTagServicePortfolio(t, db, user.ID, "IRA", "USD"),
+		createTagServicePortfolio(t, db, user.ID, "Pension", "EUR"),
+	}
+	for i, portfolio := range measured {
+		startValue := int64(1000 * (i + 1))
+		for date, value := range map[time.Time]int64{start: startValue, end: startValue + 100} {
+			require.NoError(t, db.Create(&models.PerformanceSnapshot{
+				PortfolioID: portfolio.ID,
+				Date:        date,
+				TotalValue:  decimal.NewFromInt(value),
+			}).Error)
+		}
+	}
+	// No history to measure
+	unmeasured := createTagServicePortfolio(t, db, user.ID, "New", "USD")
+
+	for _, portfolio := range append(measured, unmeasured) {
+		_, err := service.SetPortfolioTags(ctx, portfolio.ID.String(), userID, []string{"retirement"})
+		require.NoError(t, err)
+	}
+
+	performance, err := service.GetPerformance(ctx, userID, "Retirement", start, end)
+	require.NoError(t, err)
+	assert.Equal(t, "retirement", performance.Tag)
+
+	names := make([]string, len(performance.Portfolios))
+	for i, portfolio := range performance.Portfolios {
+		names[i] = portfolio.Name
+	}
+	assert.Equal(t, []string{"IRA", "New", "Pension", "Retirement"}, names)
+	assert.Nil(t, performance.Portfolios[1].Metrics)
+	assert.NotEmpty(t, performance.Portfolios[1].Error)
+
+	require.Len(t, performance.Totals, 2)
+	eur, usd := performance.Totals[0], performance.Totals[1]
+	assert.Equal(t, "EUR", eur.Currency)
+	assert.Equal(t, 1, eur.Portfolios)
+	assert.Equal(t, "USD", usd.Currency)
+	assert.Equal(t, 2, usd.Portfolios)
+	assert.True(t, usd.StartingValue.Equal(decimal.NewFromInt(3000)), usd.StartingValue.String())
+	assert.True(t, usd.TotalReturn.Equal(decimal.NewFromInt(200)), usd.TotalReturn.String())
+	assert.True(t, usd.TotalReturnPct.Round(2).Equal(decimal.RequireFromString("6.67")), usd.TotalReturnPct.String())
+
+	_, err = service.GetPerformance(ctx, userID, "unused", start, end)
+	assert.Equal(t, models.ErrTagNotFound, err)
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -14,18 +16,19 @@ import (
 // TaxLotService defines the interface for tax lot operations
 type TaxLotService interface {
 	// Core operations
-	GetByID(id, userID string) (*models.TaxLot, error)
-	GetByPortfolioID(portfolioID, userID string) ([]*models.TaxLot, error)
-	GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) ([]*models.TaxLot, error)
+	GetByID(ctx context.Context, id, userID string) (*models.TaxLot, error)
+	GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.TaxLot, error)
+	GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) ([]*models.TaxLot, error)
 
 	// Tax lot allocation for sales
-	AllocateSale(portfolioID, symbol, userID string, quantity decimal.Decimal, method models.CostBasisMethod) ([]*LotAllocation, error)
+	AllocateSale(ctx context.Context, portfolioID, symbol, userID string, quantity decimal.Decimal, method models.CostBasisMethod) ([]*LotAllocation, error)
 
 	// Tax optimization
-	IdentifyTaxLossOpportunities(portfolioID, userID string, minLossPercent decimal.Decimal) ([]*TaxLossOpportunity, error)
+	IdentifyTaxLossOpportunities(ctx context.Context, portfolioID, userID string, minLossPercent decimal.Decimal) ([]*TaxLossOpportunity, error)
 
 	// Tax reporting
-	GenerateTaxReport(portfolioID, userID string, taxYear int) (*TaxReport, error)
+	GenerateTaxReport(ctx context.Context, portfolioID, userID string, taxYear int) (*TaxReport, error)
+	SetTaxRegime(ctx context.Context, portfolioID, userID string, regime models.TaxRegime) (*models.Portfolio, error)
 }
 
 // LotAllocation represents how a sale is allocated to tax lots
@@ -38,36 +41,52 @@ type LotAllocation struct {
 	IsLongTerm   bool            `json:"is_long_term"`
 }
 
-// TaxLossOpportunity represents a potential tax-loss harvesting opportunity
+// TaxLossOpportunity represents a tax lot that could be sold to harvest its unrealized loss
 type TaxLossOpportunity struct {
-	Symbol          string          `json:"symbol"`
-	CurrentQuantity decimal.Decimal `json:"current_quantity"`
-	CostBasis       decimal.Decimal `json:"cost_basis"`
-	CurrentValue    decimal.Decimal `json:"current_value"`
-	UnrealizedLoss  decimal.Decimal `json:"unrealized_loss"`
-	LossPercent     decimal.Decimal `json:"loss_percent"`
+	TaxLotID         string          `json:"tax_lot_id"`
+	Symbol           string          `json:"symbol"`
+	PurchaseDate     time.Time       `json:"purchase_date"`
+	CurrentQuantity  decimal.Decimal `json:"current_quantity"`
+	CostBasis        decimal.Decimal `json:"cost_basis"`
+	CurrentPrice     decimal.Decimal `json:"current_price"`
+	CurrentValue     decimal.Decimal `json:"current_value"`
+	UnrealizedLoss   decimal.Decimal `json:"unrealized_loss"`
+	LossPercent      decimal.Decimal `json:"loss_percent"`
+	IsLongTerm       bool            `json:"is_long_term"`
+	WashSaleRisk     bool            `json:"wash_sale_risk"`
+	LastPurchaseDate *time.Time      `json:"last_purchase_date,omitempty"`
+	Replacements     []string        `json:"replacements"`
 }
 
-// TaxReport represents a tax report for a given year
+// TaxReport represents a tax report for a given year under the portfolio's tax regime.
+// Regimes without holding periods report every gain in Gains instead of by term.
 type TaxReport struct {
-	Year               int             `json:"year"`
-	ShortTermGains     []*RealizedGain `json:"short_term_gains"`
-	LongTermGains      []*RealizedGain `json:"long_term_gains"`
-	TotalShortTermGain decimal.Decimal `json:"total_short_term_gain"`
-	TotalLongTermGain  decimal.Decimal `json:"total_long_term_gain"`
-	TotalGain          decimal.Decimal `json:"total_gain"`
+	Year                int              `json:"year"`
+	Regime              models.TaxRegime `json:"regime"`
+	ShortTermGains      []*RealizedGain  `json:"short_term_gains"`
+	LongTermGains       []*RealizedGain  `json:"long_term_gains"`
+	Gains               []*RealizedGain  `json:"gains,omitempty"`
+	TotalShortTermGain  decimal.Decimal  `json:"total_short_term_gain"`
+	TotalLongTermGain   decimal.Decimal  `json:"total_long_term_gain"`
+	TotalGain           decimal.Decimal  `json:"total_gain"`
+	TotalDisallowedLoss decimal.Decimal  `json:"total_disallowed_loss"`
+	TaxableGain         decimal.Decimal  `json:"taxable_gain"`
 }
 
-// RealizedGain represents a realized gain or loss
+// RealizedGain represents a realized gain or loss. Rule is set by regimes that match sales to
+// shares by rule rather than to tax lots, and on gains funds distributed, and DisallowedLoss is
+// the part of a loss that was denied and already added back to Gain.
 type RealizedGain struct {
-	Symbol       string          `json:"symbol"`
-	PurchaseDate time.Time       `json:"purchase_date"`
-	SaleDate     time.Time       `json:"sale_date"`
-	Quantity     decimal.Decimal `json:"quantity"`
-	CostBasis    decimal.Decimal `json:"cost_basis"`
-	Proceeds     decimal.Decimal `json:"proceeds"`
-	Gain         decimal.Decimal `json:"gain"`
-	IsLongTerm   bool            `json:"is_long_term"`
+	Symbol         string           `json:"symbol"`
+	PurchaseDate   time.Time        `json:"purchase_date"`
+	SaleDate       time.Time        `json:"sale_date"`
+	Quantity       decimal.Decimal  `json:"quantity"`
+	CostBasis      decimal.Decimal  `json:"cost_basis"`
+	Proceeds       decimal.Decimal  `json:"proceeds"`
+	Gain           decimal.Decimal  `json:"gain"`
+	IsLongTerm     bool             `json:"is_long_term"`
+	Rule           string           `json:"rule,omitempty"`
+	DisallowedLoss *decimal.Decimal `json:"disallowed_loss,omitempty"`
 }
 
 // taxLotService implements TaxLotService interface
@@ -76,36 +95,72 @@ type taxLotService struct {
 	portfolioRepo   repository.PortfolioRepository
 	holdingRepo     repository.HoldingRepository
 	transactionRepo repository.TransactionRepository
+	rounding        models.RoundingPolicy
+	marketData      MarketDataService
+	replacements    map[string][]string
+	now             func() time.Time
 }
 
-// NewTaxLotService creates a new TaxLotService instance
+// NewTaxLotService creates a new TaxLotService instance that rounds with the default
+// rounding policy
 func NewTaxLotService(
 	taxLotRepo repository.TaxLotRepository,
 	portfolioRepo repository.PortfolioRepository,
 	holdingRepo repository.HoldingRepository,
 	transactionRepo repository.TransactionRepository,
+) TaxLotService {
+	return NewTaxLotServiceWithRounding(taxLotRepo, portfolioRepo, holdingRepo, transactionRepo, models.DefaultRoundingPolicy())
+}
+
+// NewTaxLotServiceWithRounding creates a new TaxLotService instance that rounds allocated
+// cost bases, proceeds and gains with the given policy
+func NewTaxLotServiceWithRounding(
+	taxLotRepo repository.TaxLotRepository,
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	transactionRepo repository.TransactionRepository,
+	rounding models.RoundingPolicy,
+) TaxLotService {
+	return NewTaxLotServiceWithMarketData(taxLotRepo, portfolioRepo, holdingRepo, transactionRepo, rounding, nil, nil)
+}
+
+// NewTaxLotServiceWithMarketData creates a new TaxLotService instance that prices tax lots with
+// live quotes to find tax-loss harvesting opportunities. replacements adds to or overrides the
+// default replacement securities by symbol. marketData may be nil when market data is disabled.
+func NewTaxLotServiceWithMarketData(
+	taxLotRepo repository.TaxLotRepository,
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	transactionRepo repository.TransactionRepository,
+	rounding models.RoundingPolicy,
+	marketData MarketDataService,
+	replacements map[string][]string,
 ) TaxLotService {
 	return &taxLotService{
 		taxLotRepo:      taxLotRepo,
 		portfolioRepo:   portfolioRepo,
 		holdingRepo:     holdingRepo,
 		transactionRepo: transactionRepo,
+		rounding:        rounding,
+		marketData:      marketData,
+		replacements:    models.HarvestReplacements(replacements),
+		now:             func() time.Time { return time.Now().UTC() },
 	}
 }
 
 // GetByID retrieves a tax lot by ID, ensuring it belongs to the user
-func (s *taxLotService) GetByID(id, userID string) (*models.TaxLot, error) {
-	taxLot, err := s.taxLotRepo.FindByID(id)
+func (s *taxLotService) GetByID(ctx context.Context, id, userID string) (*models.TaxLot, error) {
+	taxLot, err := s.taxLotRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Verify the portfolio belongs to the user
-	portfolio, err := s.portfolioRepo.FindByID(taxLot.PortfolioID.String())
+	portfolio, err := s.portfolioRepo.FindByID(ctx, taxLot.PortfolioID.String())
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
@@ -113,17 +168,17 @@ func (s *taxLotService) GetByID(id, userID string) (*models.TaxLot, error) {
 }
 
 // GetByPortfolioID retrieves all tax lots for a portfolio
-func (s *taxLotService) GetByPortfolioID(portfolioID, userID string) ([]*models.TaxLot, error) {
+func (s *taxLotService) GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.TaxLot, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	taxLots, err := s.taxLotRepo.FindByPortfolioID(portfolioID)
+	taxLots, err := s.taxLotRepo.FindByPortfolioID(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve tax lots: %w", err)
 	}
@@ -132,17 +187,17 @@ func (s *taxLotService) GetByPortfolioID(portfolioID, userID string) ([]*models.
 }
 
 // GetByPortfolioIDAndSymbol retrieves all tax lots for a portfolio and symbol
-func (s *taxLotService) GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) ([]*models.TaxLot, error) {
+func (s *taxLotService) GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) ([]*models.TaxLot, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+	taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve tax lots: %w", err)
 	}
@@ -150,23 +205,25 @@ func (s *taxLotService) GetByPortfolioIDAndSymbol(portfolioID, symbol, userID st
 	return taxLots, nil
 }
 
-// AllocateSale allocates a sale to tax lots based on the specified cost basis method
+// AllocateSale allocates a sale to tax lots under the portfolio's tax regime, in the order of
+// the specified cost basis method where the regime matches sales to lots
 func (s *taxLotService) AllocateSale(
+	ctx context.Context,
 	portfolioID, symbol, userID string,
 	quantity decimal.Decimal,
 	method models.CostBasisMethod,
 ) ([]*LotAllocation, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
 	// Get all tax lots for this symbol
-	taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+	taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve tax lots: %w", err)
 	}
@@ -175,45 +232,11 @@ func (s *taxLotService) AllocateSale(
 		return nil, models.ErrInsufficientShares
 	}
 
-	// Sort tax lots based on cost basis method
-	sortTaxLots(taxLots, method)
-
-	// Allocate the sale across tax lots
-	allocations := make([]*LotAllocation, 0)
-	remainingQuantity := quantity
-
-	for _, lot := range taxLots {
-		if remainingQuantity.IsZero() {
-			break
-		}
-
-		// Determine how much to take from this lot
-		quantityFromLot := lot.Quantity
-		if quantityFromLot.GreaterThan(remainingQuantity) {
-			quantityFromLot = remainingQuantity
-		}
-
-		// Calculate cost basis for this allocation
-		costBasisPerShare := lot.GetCostPerShare()
-		costBasis := costBasisPerShare.Mul(quantityFromLot)
-
-		allocation := &LotAllocation{
-			TaxLot:     lot,
-			Quantity:   quantityFromLot,
-			CostBasis:  costBasis,
-			IsLongTerm: lot.IsLongTerm(time.Now().UTC()),
-		}
-
-		allocations = append(allocations, allocation)
-		remainingQuantity = remainingQuantity.Sub(quantityFromLot)
-	}
-
-	// Check if we have enough shares
-	if remainingQuantity.GreaterThan(decimal.Zero) {
-		return nil, models.ErrInsufficientShares
+	regime, err := NewTaxRegime(portfolio.TaxRegime, s.rounding)
+	if err != nil {
+		return nil, err
 	}
-
-	return allocations, nil
+	return regime.AllocateSale(taxLots, quantity, s.now(), method)
 }
 
 // sortTaxLots sorts tax lots based on the cost basis method
@@ -235,175 +258,218 @@ func sortTaxLots(taxLots []*models.TaxLot, method models.CostBasisMethod) {
 	}
 }
 
-// IdentifyTaxLossOpportunities identifies holdings with unrealized losses
+// IdentifyTaxLossOpportunities prices each tax lot with live quotes and returns the lots whose
+// unrealized loss is at least minLossPercent of their cost basis, largest loss first. The
+// threshold may be given with either sign. A lot has wash sale risk when the symbol was bought
+// within the wash sale window by another transaction, since selling it at a loss would have the
+// loss disallowed.
 func (s *taxLotService) IdentifyTaxLossOpportunities(
+	ctx context.Context,
 	portfolioID, userID string,
 	minLossPercent decimal.Decimal,
 ) ([]*TaxLossOpportunity, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	// Get all holdings for the portfolio
-	holdings, err := s.holdingRepo.FindByPortfolioID(portfolioID)
+	if s.marketData == nil {
+		return nil, models.ErrMarketDataUnavailable
+	}
+
+	taxLots, err := s.taxLotRepo.FindByPortfolioID(ctx, portfolioID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve holdings: %w", err)
+		return nil, fmt.Errorf("failed to retrieve tax lots: %w", err)
 	}
 
 	opportunities := make([]*TaxLossOpportunity, 0)
+	if len(taxLots) == 0 {
+		return opportunities, nil
+	}
 
-	// For each holding, calculate unrealized loss
-	// Note: In a real implementation, we would fetch current market prices
-	// For now, we'll use a placeholder approach
-	for _, holding := range holdings {
-		// Skip holdings without losses
-		// This is a placeholder - in production we'd compare with current market price
-		// For now, we'll just create opportunities for demonstration
-		if holding.CostBasis.IsPositive() {
-			// Placeholder: assume current value is 10% less than cost basis for demonstration
-			currentValue := holding.CostBasis.Mul(decimal.NewFromFloat(0.9))
-			unrealizedLoss := currentValue.Sub(holding.CostBasis)
-			lossPercent := unrealizedLoss.Div(holding.CostBasis).Mul(decimal.NewFromInt(100))
-
-			if lossPercent.LessThan(minLossPercent.Neg()) {
-				opportunity := &TaxLossOpportunity{
-					Symbol:          holding.Symbol,
-					CurrentQuantity: holding.Quantity,
-					CostBasis:       holding.CostBasis,
-					CurrentValue:    currentValue,
-					UnrealizedLoss:  unrealizedLoss,
-					LossPercent:     lossPercent,
-				}
-				opportunities = append(opportunities, opportunity)
-			}
+	symbols := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, lot := range taxLots {
+		if !seen[lot.Symbol] {
+			seen[lot.Symbol] = true
+			symbols = append(symbols, lot.Symbol)
 		}
 	}
 
+	quotes, err := s.marketData.GetQuotes(ctx, symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve quotes: %w", err)
+	}
+
+	now := s.now()
+	windowStart := now.AddDate(0, 0, -models.WashSaleWindowDays)
+	recentPurchases, err := s.transactionRepo.FindByPortfolioIDWithFilters(ctx, portfolioID, nil, &windowStart, &now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+
+	threshold := minLossPercent.Abs().Neg()
+	for _, lot := range taxLots {
+		quote, ok := quotes[lot.Symbol]
+		if !ok || quote == nil || !lot.CostBasis.IsPositive() || !lot.Quantity.IsPositive() {
+			continue
+		}
+
+		currentValue := s.rounding.RoundAmount(lot.Quantity.Mul(quote.Price))
+		unrealizedLoss := currentValue.Sub(lot.CostBasis)
+		lossPercent := unrealizedLoss.Div(lot.CostBasis).Mul(decimal.NewFromInt(100)).Round(2)
+		if !unrealizedLoss.IsNegative() || lossPercent.GreaterThan(threshold) {
+			continue
+		}
+
+		opportunity := &TaxLossOpportunity{
+			TaxLotID:        lot.ID.String(),
+			Symbol:          lot.Symbol,
+			PurchaseDate:    lot.PurchaseDate,
+			CurrentQuantity: lot.Quantity,
+			CostBasis:       lot.CostBasis,
+			CurrentPrice:    quote.Price,
+			CurrentValue:    currentValue,
+			UnrealizedLoss:  unrealizedLoss,
+			LossPercent:     lossPercent,
+			IsLongTerm:      lot.IsLongTerm(now),
+			Replacements:    s.harvestReplacements(lot.Symbol),
+		}
+		if purchase := lastPurchase(recentPurchases, lot); purchase != nil {
+			opportunity.WashSaleRisk = true
+			opportunity.LastPurchaseDate = &purchase.Date
+		}
+		opportunities = append(opportunities, opportunity)
+	}
+
+	sort.SliceStable(opportunities, func(i, j int) bool {
+		return opportunities[i].UnrealizedLoss.LessThan(opportunities[j].UnrealizedLoss)
+	})
+
 	return opportunities, nil
 }
 
-// GenerateTaxReport generates a tax report for a given year
-func (s *taxLotService) GenerateTaxReport(portfolioID, userID string, taxYear int) (*TaxReport, error) {
+// harvestReplacements returns the securities suggested in place of a harvested symbol
+func (s *taxLotService) harvestReplacements(symbol string) []string {
+	if replacements, ok := s.replacements[strings.ToUpper(symbol)]; ok {
+		return replacements
+	}
+	return []string{}
+}
+
+// lastPurchase returns the latest purchase of a lot's symbol among transactions, other than the
+// one that opened the lot
+func lastPurchase(transactions []*models.Transaction, lot *models.TaxLot) *models.Transaction {
+	var last *models.Transaction
+	for _, tx := range transactions {
+		if tx.Symbol != lot.Symbol || !tx.IsBuy() || tx.ID == lot.TransactionID {
+			continue
+		}
+		if last == nil || tx.Date.After(last.Date) {
+			last = tx
+		}
+	}
+	return last
+}
+
+// GenerateTaxReport generates a tax report for a given year under the portfolio's tax regime
+func (s *taxLotService) GenerateTaxReport(ctx context.Context, portfolioID, userID string, taxYear int) (*TaxReport, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
+	regime, err := NewTaxRegime(portfolio.TaxRegime, s.rounding)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize report
 	report := &TaxReport{
-		Year:               taxYear,
-		ShortTermGains:     make([]*RealizedGain, 0),
-		LongTermGains:      make([]*RealizedGain, 0),
-		TotalShortTermGain: decimal.Zero,
-		TotalLongTermGain:  decimal.Zero,
-		TotalGain:          decimal.Zero,
+		Year:                taxYear,
+		Regime:              regime.Code(),
+		ShortTermGains:      make([]*RealizedGain, 0),
+		LongTermGains:       make([]*RealizedGain, 0),
+		TotalShortTermGain:  decimal.Zero,
+		TotalLongTermGain:   decimal.Zero,
+		TotalGain:           decimal.Zero,
+		TotalDisallowedLoss: decimal.Zero,
 	}
 
 	// Get the start and end dates for the tax year
 	startDate := time.Date(taxYear, 1, 1, 0, 0, 0, 0, time.UTC)
 	endDate := time.Date(taxYear, 12, 31, 23, 59, 59, 999999999, time.UTC)
 
-	// Get all transactions for this portfolio in the tax year
-	allTransactions, err := s.transactionRepo.FindByPortfolioIDWithFilters(portfolioID, nil, &startDate, &endDate)
+	records := &portfolioTaxRecords{
+		portfolioID:     portfolioID,
+		transactionRepo: s.transactionRepo,
+	}
+	gains, err := regime.RealizedGains(ctx, records, portfolio, startDate, endDate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query transactions: %w", err)
+		return nil, err
 	}
 
-	// Filter for sell transactions only
-	var sellTransactions []*models.Transaction
-	for _, tx := range allTransactions {
-		if tx.Type == models.TransactionTypeSell {
-			sellTransactions = append(sellTransactions, tx)
+	for _, gain := range gains {
+		switch {
+		case !regime.HoldingPeriods():
+			report.Gains = append(report.Gains, gain)
+		case gain.IsLongTerm:
+			report.LongTermGains = append(report.LongTermGains, gain)
+			report.TotalLongTermGain = report.TotalLongTermGain.Add(gain.Gain)
+		default:
+			report.ShortTermGains = append(report.ShortTermGains, gain)
+			report.TotalShortTermGain = report.TotalShortTermGain.Add(gain.Gain)
 		}
-	}
-
-	// For each sell transaction, allocate to tax lots and calculate gain/loss
-	for _, sellTx := range sellTransactions {
-		if sellTx.Price == nil {
-			continue // Skip sells without a price
+		report.TotalGain = report.TotalGain.Add(gain.Gain)
+		if gain.DisallowedLoss != nil {
+			report.TotalDisallowedLoss = report.TotalDisallowedLoss.Add(*gain.DisallowedLoss)
 		}
+	}
+	report.TaxableGain = s.rounding.RoundAmount(report.TotalGain.Mul(regime.InclusionRate()))
 
-		salePrice := *sellTx.Price
+	return report, nil
+}
 
-		// Get tax lots at the time of the sale to allocate
-		taxLots, err := s.taxLotRepo.FindByPortfolioIDAndSymbol(portfolioID, sellTx.Symbol)
-		if err != nil {
-			continue // Skip if we can't find tax lots
-		}
+// SetTaxRegime changes the tax regime a portfolio's sales are allocated and reported under
+func (s *taxLotService) SetTaxRegime(ctx context.Context, portfolioID, userID string, regime models.TaxRegime) (*models.Portfolio, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	if !regime.IsValid() {
+		return nil, models.ErrInvalidTaxRegime
+	}
 
-		// Sort lots by purchase date (FIFO)
-		sortTaxLots(taxLots, portfolio.CostBasisMethod)
-
-		// Allocate the sale to lots
-		remainingQuantity := sellTx.Quantity
-		totalCostBasis := decimal.Zero
-
-		for _, lot := range taxLots {
-			if remainingQuantity.IsZero() {
-				break
-			}
-
-			// Skip lots purchased after this sale
-			if lot.PurchaseDate.After(sellTx.Date) {
-				continue
-			}
-
-			// Determine how much to take from this lot
-			quantityFromLot := lot.Quantity
-			if quantityFromLot.GreaterThan(remainingQuantity) {
-				quantityFromLot = remainingQuantity
-			}
-
-			// Calculate cost basis for this portion
-			costBasisPerShare := lot.GetCostPerShare()
-			costBasisForPortion := costBasisPerShare.Mul(quantityFromLot)
-			totalCostBasis = totalCostBasis.Add(costBasisForPortion)
-
-			// Calculate proceeds for this portion
-			proceedsForPortion := salePrice.Mul(quantityFromLot)
-
-			// Calculate gain/loss
-			gain := proceedsForPortion.Sub(costBasisForPortion)
-
-			// Determine if long-term or short-term
-			isLongTerm := lot.IsLongTerm(sellTx.Date)
-
-			// Create realized gain entry
-			realizedGain := &RealizedGain{
-				Symbol:       sellTx.Symbol,
-				PurchaseDate: lot.PurchaseDate,
-				SaleDate:     sellTx.Date,
-				Quantity:     quantityFromLot,
-				CostBasis:    costBasisForPortion,
-				Proceeds:     proceedsForPortion,
-				Gain:         gain,
-				IsLongTerm:   isLongTerm,
-			}
-
-			// Add to appropriate category
-			if isLongTerm {
-				report.LongTermGains = append(report.LongTermGains, realizedGain)
-				report.TotalLongTermGain = report.TotalLongTermGain.Add(gain)
-			} else {
-				report.ShortTermGains = append(report.ShortTermGains, realizedGain)
-				report.TotalShortTermGain = report.TotalShortTermGain.Add(gain)
-			}
-
-			remainingQuantity = remainingQuantity.Sub(quantityFromLot)
-		}
+	portfolio.TaxRegime = regime
+	if err := s.portfolioRepo.Update(ctx, portfolio); err != nil {
+		return nil, fmt.Errorf("failed to update portfolio: %w", err)
 	}
+	return portfolio, nil
+}
 
-	// Calculate total gain
-	report.TotalGain = report.TotalShortTermGain.Add(report.TotalLongTermGain)
+// portfolioTaxRecords loads a portfolio's transactions from the repository
+type portfolioTaxRecords struct {
+	portfolioID     string
+	transactionRepo repository.TransactionRepository
+}
 
-	return report, nil
+// Transactions returns the portfolio's transactions dated through end, and from start on
+// when it is set
+func (r *portfolioTaxRecords) Transactions(ctx context.Context, start *time.Time, end time.Time) ([]*models.Transaction, error) {
+	transactions, err := r.transactionRepo.FindByPortfolioIDWithFilters(ctx, r.portfolioID, nil, start, &end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	return transactions, nil
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTaxLotService(t *testing.T) {
@@ -25,6 +27,8 @@ func TestNewTaxLotService(t *testing.T) {
 }
 
 func TestTaxLotService_GetByID_Success(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -50,10 +54,10 @@ func TestTaxLotService_GetByID_Success(t *testing.T) {
 		Name:   "Test Portfolio",
 	}
 
-	taxLotRepo.On("FindByID", taxLotID.String()).Return(taxLot, nil)
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByID", mock.Anything, taxLotID.String()).Return(taxLot, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
 
-	result, err := service.GetByID(taxLotID.String(), userID.String())
+	result, err := service.GetByID(ctx, taxLotID.String(), userID.String())
 
 	assert.NoError(t, err)
 	assert.Equal(t, taxLot, result)
@@ -62,6 +66,8 @@ func TestTaxLotService_GetByID_Success(t *testing.T) {
 }
 
 func TestTaxLotService_GetByID_TaxLotNotFound(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -72,15 +78,17 @@ func TestTaxLotService_GetByID_TaxLotNotFound(t *testing.T) {
 	taxLotID := uuid.New().String()
 	userID := uuid.New().String()
 
-	taxLotRepo.On("FindByID", taxLotID).Return(nil, fmt.Errorf("not found"))
+	taxLotRepo.On("FindByID", mock.Anything, taxLotID).Return(nil, fmt.Errorf("not found"))
 
-	_, err := service.GetByID(taxLotID, userID)
+	_, err := service.GetByID(ctx, taxLotID, userID)
 
 	assert.Error(t, err)
 	taxLotRepo.AssertExpectations(t)
 }
 
 func TestTaxLotService_GetByID_PortfolioNotFound(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -98,10 +106,10 @@ func TestTaxLotService_GetByID_PortfolioNotFound(t *testing.T) {
 		Symbol:      "AAPL",
 	}
 
-	taxLotRepo.On("FindByID", taxLotID.String()).Return(taxLot, nil)
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(nil, fmt.Errorf("not found"))
+	taxLotRepo.On("FindByID", mock.Anything, taxLotID.String()).Return(taxLot, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(nil, fmt.Errorf("not found"))
 
-	_, err := service.GetByID(taxLotID.String(), userID.String())
+	_, err := service.GetByID(ctx, taxLotID.String(), userID.String())
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrPortfolioNotFound, err)
@@ -110,6 +118,8 @@ func TestTaxLotService_GetByID_PortfolioNotFound(t *testing.T) {
 }
 
 func TestTaxLotService_GetByID_Unauthorized(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -134,10 +144,10 @@ func TestTaxLotService_GetByID_Unauthorized(t *testing.T) {
 		Name:   "Test Portfolio",
 	}
 
-	taxLotRepo.On("FindByID", taxLotID.String()).Return(taxLot, nil)
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByID", mock.Anything, taxLotID.String()).Return(taxLot, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
 
-	_, err := service.GetByID(taxLotID.String(), userID.String())
+	_, err := service.GetByID(ctx, taxLotID.String(), userID.String())
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrUnauthorizedAccess, err)
@@ -146,6 +156,8 @@ func TestTaxLotService_GetByID_Unauthorized(t *testing.T) {
 }
 
 func TestTaxLotService_GetByPortfolioID_Success(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -177,10 +189,10 @@ func TestTaxLotService_GetByPortfolioID_Success(t *testing.T) {
 		},
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
-	taxLotRepo.On("FindByPortfolioID", portfolioID.String()).Return(taxLots, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByPortfolioID", mock.Anything, portfolioID.String()).Return(taxLots, nil)
 
-	result, err := service.GetByPortfolioID(portfolioID.String(), userID.String())
+	result, err := service.GetByPortfolioID(ctx, portfolioID.String(), userID.String())
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 2)
@@ -189,6 +201,8 @@ func TestTaxLotService_GetByPortfolioID_Success(t *testing.T) {
 }
 
 func TestTaxLotService_GetByPortfolioID_Unauthorized(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -206,9 +220,9 @@ func TestTaxLotService_GetByPortfolioID_Unauthorized(t *testing.T) {
 		Name:   "Test Portfolio",
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
 
-	_, err := service.GetByPortfolioID(portfolioID.String(), userID.String())
+	_, err := service.GetByPortfolioID(ctx, portfolioID.String(), userID.String())
 
 	assert.Error(t, err)
 	assert.Equal(t, models.ErrUnauthorizedAccess, err)
@@ -216,6 +230,8 @@ func TestTaxLotService_GetByPortfolioID_Unauthorized(t *testing.T) {
 }
 
 func TestTaxLotService_GetByPortfolioIDAndSymbol_Success(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -242,10 +258,10 @@ func TestTaxLotService_GetByPortfolioIDAndSymbol_Success(t *testing.T) {
 		},
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
-	taxLotRepo.On("FindByPortfolioIDAndSymbol", portfolioID.String(), symbol).Return(taxLots, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", mock.Anything, portfolioID.String(), symbol).Return(taxLots, nil)
 
-	result, err := service.GetByPortfolioIDAndSymbol(portfolioID.String(), symbol, userID.String())
+	result, err := service.GetByPortfolioIDAndSymbol(ctx, portfolioID.String(), symbol, userID.String())
 
 	assert.NoError(t, err)
 	assert.Len(t, result, 1)
@@ -255,6 +271,8 @@ func TestTaxLotService_GetByPortfolioIDAndSymbol_Success(t *testing.T) {
 }
 
 func TestTaxLotService_AllocateSale_FIFO(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -296,11 +314,12 @@ func TestTaxLotService_AllocateSale_FIFO(t *testing.T) {
 		},
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
-	taxLotRepo.On("FindByPortfolioIDAndSymbol", portfolioID.String(), symbol).Return(taxLots, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", mock.Anything, portfolioID.String(), symbol).Return(taxLots, nil)
 
 	// Sell 8 shares - should use FIFO (oldest first)
 	allocations, err := service.AllocateSale(
+		ctx,
 		portfolioID.String(),
 		symbol,
 		userID.String(),
@@ -322,6 +341,8 @@ func TestTaxLotService_AllocateSale_FIFO(t *testing.T) {
 }
 
 func TestTaxLotService_AllocateSale_LIFO(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -361,11 +382,12 @@ func TestTaxLotService_AllocateSale_LIFO(t *testing.T) {
 		},
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
-	taxLotRepo.On("FindByPortfolioIDAndSymbol", portfolioID.String(), symbol).Return(taxLots, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", mock.Anything, portfolioID.String(), symbol).Return(taxLots, nil)
 
 	// Sell 8 shares - should use LIFO (newest first)
 	allocations, err := service.AllocateSale(
+		ctx,
 		portfolioID.String(),
 		symbol,
 		userID.String(),
@@ -383,7 +405,52 @@ func TestTaxLotService_AllocateSale_LIFO(t *testing.T) {
 	taxLotRepo.AssertExpectations(t)
 }
 
+func TestTaxLotService_AllocateSale_Rounding(t *testing.T) {
+	ctx := context.Background()
+
+	taxLotRepo := mocks.NewTaxLotRepository(t)
+	portfolioRepo := mocks.NewPortfolioRepository(t)
+	holdingRepo := mocks.NewHoldingRepository(t)
+	transactionRepo := mocks.NewTransactionRepository(t)
+
+	rounding := models.DefaultRoundingPolicy()
+	rounding.Mode = models.RoundingModeHalfEven
+	rounding.AmountPlaces = 2
+	service := NewTaxLotServiceWithRounding(taxLotRepo, portfolioRepo, holdingRepo, transactionRepo, rounding)
+
+	userID := uuid.New()
+	portfolioID := uuid.New()
+	portfolio := &models.Portfolio{ID: portfolioID, UserID: userID}
+
+	// 8 shares bought for $100.20, so each costs 12.525
+	taxLots := []*models.TaxLot{
+		{ID: uuid.New(), PortfolioID: portfolioID, Symbol: "AAPL", PurchaseDate: time.Now().AddDate(0, -2, 0), Quantity: decimal.NewFromInt(8), CostBasis: decimal.RequireFromString("100.20")},
+		{ID: uuid.New(), PortfolioID: portfolioID, Symbol: "AAPL", PurchaseDate: time.Now().AddDate(0, -1, 0), Quantity: decimal.NewFromInt(3), CostBasis: decimal.RequireFromString("10.01")},
+	}
+
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", mock.Anything, portfolioID.String(), "AAPL").Return(taxLots, nil)
+
+	t.Run("partial lot rounds half to even", func(t *testing.T) {
+		allocations, err := service.AllocateSale(ctx, portfolioID.String(), "AAPL", userID.String(), decimal.NewFromInt(1), models.CostBasisFIFO)
+		require.NoError(t, err)
+		require.Len(t, allocations, 1)
+		assert.Equal(t, "12.52", allocations[0].CostBasis.StringFixed(2))
+	})
+
+	t.Run("whole lot keeps its cost basis", func(t *testing.T) {
+		allocations, err := service.AllocateSale(ctx, portfolioID.String(), "AAPL", userID.String(), decimal.NewFromInt(9), models.CostBasisFIFO)
+		require.NoError(t, err)
+		require.Len(t, allocations, 2)
+		assert.True(t, allocations[0].CostBasis.Equal(decimal.RequireFromString("100.20")))
+		// 10.01 / 3 is 3.3366..., rounded to cents
+		assert.Equal(t, "3.34", allocations[1].CostBasis.StringFixed(2))
+	})
+}
+
 func TestTaxLotService_AllocateSale_InsufficientShares(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -411,11 +478,12 @@ func TestTaxLotService_AllocateSale_InsufficientShares(t *testing.T) {
 		},
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
-	taxLotRepo.On("FindByPortfolioIDAndSymbol", portfolioID.String(), symbol).Return(taxLots, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", mock.Anything, portfolioID.String(), symbol).Return(taxLots, nil)
 
 	// Try to sell 10 shares when only 5 available
 	_, err := service.AllocateSale(
+		ctx,
 		portfolioID.String(),
 		symbol,
 		userID.String(),
@@ -431,6 +499,8 @@ func TestTaxLotService_AllocateSale_InsufficientShares(t *testing.T) {
 }
 
 func TestTaxLotService_AllocateSale_NoTaxLots(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -448,10 +518,11 @@ func TestTaxLotService_AllocateSale_NoTaxLots(t *testing.T) {
 		Name:   "Test Portfolio",
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
-	taxLotRepo.On("FindByPortfolioIDAndSymbol", portfolioID.String(), symbol).Return([]*models.TaxLot{}, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByPortfolioIDAndSymbol", mock.Anything, portfolioID.String(), symbol).Return([]*models.TaxLot{}, nil)
 
 	_, err := service.AllocateSale(
+		ctx,
 		portfolioID.String(),
 		symbol,
 		userID.String(),
@@ -467,12 +538,20 @@ func TestTaxLotService_AllocateSale_NoTaxLots(t *testing.T) {
 }
 
 func TestTaxLotService_IdentifyTaxLossOpportunities_Success(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
 	transactionRepo := mocks.NewTransactionRepository(t)
+	marketData := new(MockMarketDataService)
 
-	service := NewTaxLotService(taxLotRepo, portfolioRepo, holdingRepo, transactionRepo)
+	service := NewTaxLotServiceWithMarketData(
+		taxLotRepo, portfolioRepo, holdingRepo, transactionRepo, models.DefaultRoundingPolicy(), marketData,
+		map[string][]string{"aapl": {"MSFT"}},
+	).(*taxLotService)
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
 
 	userID := uuid.New()
 	portfolioID := uuid.New()
@@ -483,35 +562,124 @@ func TestTaxLotService_IdentifyTaxLossOpportunities_Success(t *testing.T) {
 		Name:   "Test Portfolio",
 	}
 
-	holdings := []*models.Holding{
-		{
-			ID:          uuid.New(),
-			PortfolioID: portfolioID,
-			Symbol:      "AAPL",
-			Quantity:    decimal.NewFromInt(10),
-			CostBasis:   decimal.NewFromInt(1000),
-		},
+	oldVTI := &models.TaxLot{
+		ID:            uuid.New(),
+		PortfolioID:   portfolioID,
+		Symbol:        "VTI",
+		PurchaseDate:  now.AddDate(-2, 0, 0),
+		Quantity:      decimal.NewFromInt(10),
+		CostBasis:     decimal.NewFromInt(2500), // 250 a share
+		TransactionID: uuid.New(),
+	}
+	newVTI := &models.TaxLot{
+		ID:            uuid.New(),
+		PortfolioID:   portfolioID,
+		Symbol:        "VTI",
+		PurchaseDate:  now.AddDate(0, 0, -10),
+		Quantity:      decimal.NewFromInt(1),
+		CostBasis:     decimal.NewFromInt(230),
+		TransactionID: uuid.New(),
+	}
+	aapl := &models.TaxLot{
+		ID:            uuid.New(),
+		PortfolioID:   portfolioID,
+		Symbol:        "AAPL",
+		PurchaseDate:  now.AddDate(0, -3, 0),
+		Quantity:      decimal.NewFromInt(5),
+		CostBasis:     decimal.NewFromInt(1000), // 200 a share
+		TransactionID: uuid.New(),
+	}
+	msft := &models.TaxLot{
+		ID:            uuid.New(),
+		PortfolioID:   portfolioID,
+		Symbol:        "MSFT",
+		PurchaseDate:  now.AddDate(0, -3, 0),
+		Quantity:      decimal.NewFromInt(5),
+		CostBasis:     decimal.NewFromInt(1000),
+		TransactionID: uuid.New(),
+	}
+	unquoted := &models.TaxLot{
+		ID:            uuid.New(),
+		PortfolioID:   portfolioID,
+		Symbol:        "DELISTED",
+		PurchaseDate:  now.AddDate(-1, 0, 0),
+		Quantity:      decimal.NewFromInt(5),
+		CostBasis:     decimal.NewFromInt(1000),
+		TransactionID: uuid.New(),
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
-	holdingRepo.On("FindByPortfolioID", portfolioID.String()).Return(holdings, nil)
+	recent := []*models.Transaction{
+		{ID: newVTI.TransactionID, Symbol: "VTI", Type: models.TransactionTypeBuy, Date: newVTI.PurchaseDate},
+		{ID: uuid.New(), Symbol: "AAPL", Type: models.TransactionTypeSell, Date: now.AddDate(0, 0, -5)},
+	}
+
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
+	taxLotRepo.On("FindByPortfolioID", mock.Anything, portfolioID.String()).
+		Return([]*models.TaxLot{oldVTI, newVTI, aapl, msft, unquoted}, nil)
+	marketData.On("GetQuotes", []string{"VTI", "AAPL", "MSFT", "DELISTED"}).Return(map[string]*Quote{
+		"VTI":  {Symbol: "VTI", Price: decimal.NewFromInt(225)},
+		"AAPL": {Symbol: "AAPL", Price: decimal.NewFromInt(196)},
+		"MSFT": {Symbol: "MSFT", Price: decimal.NewFromInt(210)},
+	}, nil)
+	transactionRepo.On("FindByPortfolioIDWithFilters", mock.Anything, portfolioID.String(), (*string)(nil), mock.Anything, mock.Anything).
+		Return(recent, nil)
 
 	opportunities, err := service.IdentifyTaxLossOpportunities(
+		ctx,
 		portfolioID.String(),
 		userID.String(),
-		decimal.NewFromInt(5), // Min 5% loss
+		decimal.NewFromInt(-2), // Min 2% loss
 	)
+	require.NoError(t, err)
+
+	// MSFT gained and the delisted symbol has no quote
+	require.Len(t, opportunities, 3)
+
+	first := opportunities[0]
+	assert.Equal(t, oldVTI.ID.String(), first.TaxLotID)
+	assert.True(t, first.CurrentValue.Equal(decimal.NewFromInt(2250)))
+	assert.True(t, first.UnrealizedLoss.Equal(decimal.NewFromInt(-250)))
+	assert.True(t, first.LossPercent.Equal(decimal.NewFromInt(-10)))
+	assert.True(t, first.IsLongTerm)
+	assert.True(t, first.WashSaleRisk, "the newer VTI lot was bought within 30 days")
+	require.NotNil(t, first.LastPurchaseDate)
+	assert.Equal(t, newVTI.PurchaseDate, *first.LastPurchaseDate)
+	assert.Equal(t, []string{"ITOT", "SCHB"}, first.Replacements)
+
+	second := opportunities[1]
+	assert.Equal(t, "AAPL", second.Symbol)
+	assert.True(t, second.UnrealizedLoss.Equal(decimal.NewFromInt(-20)))
+	assert.False(t, second.IsLongTerm)
+	assert.False(t, second.WashSaleRisk, "sales don't count as purchases")
+	assert.Equal(t, []string{"MSFT"}, second.Replacements)
+
+	third := opportunities[2]
+	assert.Equal(t, newVTI.ID.String(), third.TaxLotID)
+	assert.False(t, third.WashSaleRisk, "a lot's own purchase doesn't count")
+}
 
-	assert.NoError(t, err)
-	assert.NotNil(t, opportunities)
-	// The service creates a placeholder opportunity with -10% loss
-	assert.Len(t, opportunities, 1)
+func TestTaxLotService_IdentifyTaxLossOpportunities_NoMarketData(t *testing.T) {
+	ctx := context.Background()
 
-	portfolioRepo.AssertExpectations(t)
-	holdingRepo.AssertExpectations(t)
+	taxLotRepo := mocks.NewTaxLotRepository(t)
+	portfolioRepo := mocks.NewPortfolioRepository(t)
+	holdingRepo := mocks.NewHoldingRepository(t)
+	transactionRepo := mocks.NewTransactionRepository(t)
+
+	service := NewTaxLotService(taxLotRepo, portfolioRepo, holdingRepo, transactionRepo)
+
+	userID := uuid.New()
+	portfolioID := uuid.New()
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).
+		Return(&models.Portfolio{ID: portfolioID, UserID: userID}, nil)
+
+	_, err := service.IdentifyTaxLossOpportunities(ctx, portfolioID.String(), userID.String(), decimal.NewFromInt(3))
+	assert.Equal(t, models.ErrMarketDataUnavailable, err)
 }
 
 func TestTaxLotService_IdentifyTaxLossOpportunities_Unauthorized(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -529,9 +697,10 @@ func TestTaxLotService_IdentifyTaxLossOpportunities_Unauthorized(t *testing.T) {
 		Name:   "Test Portfolio",
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
 
 	_, err := service.IdentifyTaxLossOpportunities(
+		ctx,
 		portfolioID.String(),
 		userID.String(),
 		decimal.NewFromInt(5),
@@ -544,6 +713,8 @@ func TestTaxLotService_IdentifyTaxLossOpportunities_Unauthorized(t *testing.T) {
 }
 
 func TestTaxLotService_GenerateTaxReport_Success(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -561,13 +732,14 @@ func TestTaxLotService_GenerateTaxReport_Success(t *testing.T) {
 		CostBasisMethod: models.CostBasisFIFO,
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
 
 	// Mock the transaction repository call
 	var emptyTransactions []*models.Transaction
-	transactionRepo.On("FindByPortfolioIDWithFilters", portfolioID.String(), (*string)(nil), mock.Anything, mock.Anything).Return(emptyTransactions, nil)
+	transactionRepo.On("FindByPortfolioIDWithFilters", mock.Anything, portfolioID.String(), (*string)(nil), mock.Anything, mock.Anything).Return(emptyTransactions, nil)
 
 	report, err := service.GenerateTaxReport(
+		ctx,
 		portfolioID.String(),
 		userID.String(),
 		2024,
@@ -583,7 +755,164 @@ func TestTaxLotService_GenerateTaxReport_Success(t *testing.T) {
 	transactionRepo.AssertExpectations(t)
 }
 
+func TestTaxLotService_GenerateTaxReport_SalesAcrossSymbols(t *testing.T) {
+	ctx := context.Background()
+
+	taxLotRepo := mocks.NewTaxLotRepository(t)
+	portfolioRepo := mocks.NewPortfolioRepository(t)
+	holdingRepo := mocks.NewHoldingRepository(t)
+	transactionRepo := mocks.NewTransactionRepository(t)
+
+	service := NewTaxLotService(taxLotRepo, portfolioRepo, holdingRepo, transactionRepo)
+
+	userID := uuid.New()
+	portfolio := &models.Portfolio{ID: uuid.New(), UserID: userID, CostBasisMethod: models.CostBasisFIFO}
+	portfolioRepo.On("FindByID", mock.Anything, portfolio.ID.String()).Return(portfolio, nil)
+
+	buyPrice, sellPrice := decimal.NewFromInt(10), decimal.NewFromInt(15)
+	trade := func(txType models.TransactionType, symbol string, month time.Month, quantity int64, price *decimal.Decimal) *models.Transaction {
+		return &models.Transaction{
+			ID: uuid.New(), PortfolioID: portfolio.ID, Type: txType, Symbol: symbol,
+			Date: time.Date(2024, month, 1, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(quantity), Price: price,
+		}
+	}
+	transactionRepo.On("FindByPortfolioIDWithFilters", mock.Anything, portfolio.ID.String(), (*string)(nil), (*time.Time)(nil), mock.Anything).
+		Return([]*models.Transaction{
+			trade(models.TransactionTypeSell, "AAPL", 5, 5, &sellPrice),
+			trade(models.TransactionTypeSell, "MSFT", 4, 5, &sellPrice),
+			trade(models.TransactionTypeSell, "AAPL", 3, 5, &sellPrice),
+			trade(models.TransactionTypeBuy, "MSFT", 1, 10, &buyPrice),
+			trade(models.TransactionTypeBuy, "AAPL", 1, 10, &buyPrice),
+		}, nil)
+
+	report, err := service.GenerateTaxReport(ctx, portfolio.ID.String(), userID.String(), 2024)
+	require.NoError(t, err)
+
+	// Each sale of 5 shares at $15 against a $10 basis gains $25
+	require.Len(t, report.ShortTermGains, 3)
+	assert.True(t, report.TotalShortTermGain.Equal(decimal.NewFromInt(75)), report.TotalShortTermGain.String())
+}
+
+func TestTaxLotService_GenerateTaxReport_ReturnOfCapital(t *testing.T) {
+	ctx := context.Background()
+
+	taxLotRepo := mocks.NewTaxLotRepository(t)
+	portfolioRepo := mocks.NewPortfolioRepository(t)
+	holdingRepo := mocks.NewHoldingRepository(t)
+	transactionRepo := mocks.NewTransactionRepository(t)
+
+	service := NewTaxLotService(taxLotRepo, portfolioRepo, holdingRepo, transactionRepo)
+
+	userID := uuid.New()
+	portfolio := &models.Portfolio{ID: uuid.New(), UserID: userID, CostBasisMethod: models.CostBasisFIFO}
+	portfolioRepo.On("FindByID", mock.Anything, portfolio.ID.String()).Return(portfolio, nil)
+
+	price := decimal.NewFromInt(20)
+	buy := &models.Transaction{
+		ID: uuid.New(), PortfolioID: portfolio.ID, Type: models.TransactionTypeBuy, Symbol: "MLP",
+		Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(10), Price: &price,
+	}
+	distribution := &models.Transaction{
+		ID: uuid.New(), PortfolioID: portfolio.ID, Type: models.TransactionTypeReturnOfCapital, Symbol: "MLP",
+		Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(300),
+	}
+
+	transactionRepo.On("FindByPortfolioIDWithFilters", mock.Anything, portfolio.ID.String(), (*string)(nil), (*time.Time)(nil), mock.Anything).
+		Return([]*models.Transaction{distribution, buy}, nil)
+
+	report, err := service.GenerateTaxReport(ctx, portfolio.ID.String(), userID.String(), 2024)
+	require.NoError(t, err)
+
+	// $300 against a $200 basis realizes $100, long-term since the shares were held over a year
+	require.Len(t, report.LongTermGains, 1)
+	assert.Empty(t, report.ShortTermGains)
+	gain := report.LongTermGains[0]
+	assert.Equal(t, "MLP", gain.Symbol)
+	assert.Equal(t, buy.Date, gain.PurchaseDate)
+	assert.True(t, gain.Gain.Equal(decimal.NewFromInt(100)), gain.Gain.String())
+	assert.True(t, report.TotalGain.Equal(decimal.NewFromInt(100)))
+}
+
+func TestTaxLotService_GenerateTaxReport_CanadianRegime(t *testing.T) {
+	ctx := context.Background()
+
+	taxLotRepo := mocks.NewTaxLotRepository(t)
+	portfolioRepo := mocks.NewPortfolioRepository(t)
+	holdingRepo := mocks.NewHoldingRepository(t)
+	transactionRepo := mocks.NewTransactionRepository(t)
+
+	service := NewTaxLotService(taxLotRepo, portfolioRepo, holdingRepo, transactionRepo)
+
+	userID := uuid.New()
+	portfolio := &models.Portfolio{ID: uuid.New(), UserID: userID, CostBasisMethod: models.CostBasisFIFO, TaxRegime: models.TaxRegimeCA}
+	portfolioRepo.On("FindByID", mock.Anything, portfolio.ID.String()).Return(portfolio, nil)
+
+	buyPrice, sellPrice := decimal.NewFromInt(20), decimal.NewFromInt(30)
+	buy := &models.Transaction{
+		ID: uuid.New(), PortfolioID: portfolio.ID, Type: models.TransactionTypeBuy, Symbol: "RY",
+		Date: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(10), Price: &buyPrice,
+	}
+	sell := &models.Transaction{
+		ID: uuid.New(), PortfolioID: portfolio.ID, Type: models.TransactionTypeSell, Symbol: "RY",
+		Date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(10), Price: &sellPrice,
+	}
+	transactionRepo.On("FindByPortfolioIDWithFilters", mock.Anything, portfolio.ID.String(), (*string)(nil), (*time.Time)(nil), mock.Anything).
+		Return([]*models.Transaction{sell, buy}, nil)
+
+	report, err := service.GenerateTaxReport(ctx, portfolio.ID.String(), userID.String(), 2024)
+	require.NoError(t, err)
+
+	// Gains have no term, and half of the $100 gain is taxable
+	assert.Equal(t, models.TaxRegimeCA, report.Regime)
+	assert.Empty(t, report.ShortTermGains)
+	assert.Empty(t, report.LongTermGains)
+	require.Len(t, report.Gains, 1)
+	assert.Equal(t, GainRuleAdjustedCostBase, report.Gains[0].Rule)
+	assert.True(t, report.TotalGain.Equal(decimal.NewFromInt(100)))
+	assert.True(t, report.TaxableGain.Equal(decimal.NewFromInt(50)))
+}
+
+func TestTaxLotService_SetTaxRegime(t *testing.T) {
+	ctx := context.Background()
+
+	userID := uuid.New()
+	newService := func() (TaxLotService, *mocks.PortfolioRepository, *models.Portfolio) {
+		portfolioRepo := mocks.NewPortfolioRepository(t)
+		service := NewTaxLotService(mocks.NewTaxLotRepository(t), portfolioRepo, mocks.NewHoldingRepository(t), mocks.NewTransactionRepository(t))
+		portfolio := &models.Portfolio{ID: uuid.New(), UserID: userID, TaxRegime: models.TaxRegimeUS}
+		portfolioRepo.On("FindByID", mock.Anything, portfolio.ID.String()).Return(portfolio, nil)
+		return service, portfolioRepo, portfolio
+	}
+
+	t.Run("changes the regime", func(t *testing.T) {
+		service, portfolioRepo, portfolio := newService()
+		portfolioRepo.On("Update", mock.Anything, mock.MatchedBy(func(p *models.Portfolio) bool {
+			return p.TaxRegime == models.TaxRegimeUK
+		})).Return(nil)
+
+		updated, err := service.SetTaxRegime(ctx, portfolio.ID.String(), userID.String(), models.TaxRegimeUK)
+		require.NoError(t, err)
+		assert.Equal(t, models.TaxRegimeUK, updated.TaxRegime)
+	})
+
+	t.Run("unknown regime", func(t *testing.T) {
+		service, _, portfolio := newService()
+
+		_, err := service.SetTaxRegime(ctx, portfolio.ID.String(), userID.String(), "FR")
+		assert.ErrorIs(t, err, models.ErrInvalidTaxRegime)
+	})
+
+	t.Run("another user's portfolio", func(t *testing.T) {
+		service, _, portfolio := newService()
+
+		_, err := service.SetTaxRegime(ctx, portfolio.ID.String(), uuid.New().String(), models.TaxRegimeCA)
+		assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+	})
+}
+
 func TestTaxLotService_GenerateTaxReport_PortfolioNotFound(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -594,9 +923,10 @@ func TestTaxLotService_GenerateTaxReport_PortfolioNotFound(t *testing.T) {
 	userID := uuid.New()
 	portfolioID := uuid.New()
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(nil, fmt.Errorf("not found"))
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(nil, fmt.Errorf("not found"))
 
 	_, err := service.GenerateTaxReport(
+		ctx,
 		portfolioID.String(),
 		userID.String(),
 		2024,
@@ -609,6 +939,8 @@ func TestTaxLotService_GenerateTaxReport_PortfolioNotFound(t *testing.T) {
 }
 
 func TestTaxLotService_GenerateTaxReport_Unauthorized(t *testing.T) {
+	ctx := context.Background()
+
 	taxLotRepo := mocks.NewTaxLotRepository(t)
 	portfolioRepo := mocks.NewPortfolioRepository(t)
 	holdingRepo := mocks.NewHoldingRepository(t)
@@ -626,9 +958,10 @@ func TestTaxLotService_GenerateTaxReport_Unauthorized(t *testing.T) {
 		Name:   "Test Portfolio",
 	}
 
-	portfolioRepo.On("FindByID", portfolioID.String()).Return(portfolio, nil)
+	portfolioRepo.On("FindByID", mock.Anything, portfolioID.String()).Return(portfolio, nil)
 
 	_, err := service.GenerateTaxReport(
+		ctx,
 		portfolioID.String(),
 		userID.String(),
 		2024,
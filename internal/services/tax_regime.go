@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// Rules that matched a sale to the shares whose cost it is reported against, for regimes that
+// don't match sales to lots
+const (
+	// GainRuleSameDay matches a UK sale to shares bought the same day
+	GainRuleSameDay = "SAME_DAY"
+	// GainRuleBedAndBreakfast matches a UK sale to shares bought in the 30 days after it
+	GainRuleBedAndBreakfast = "BED_AND_BREAKFAST"
+	// GainRuleSection104 costs a UK sale at the average cost of the Section 104 pool
+	GainRuleSection104 = "SECTION_104"
+	// GainRuleAdjustedCostBase costs a Canadian sale at the average cost of the shares held
+	GainRuleAdjustedCostBase = "ACB"
+)
+
+// GainRuleCapitalGainDistribution marks a gain a fund distributed, which no sale realized
+const GainRuleCapitalGainDistribution = "CAPITAL_GAIN_DISTRIBUTION"
+
+// TaxRegime applies one country's capital gains rules to a portfolio's sales
+type TaxRegime interface {
+	// Code is the regime's code, as stored on portfolios
+	Code() models.TaxRegime
+	// HoldingPeriods reports whether the regime splits gains into short and long term
+	HoldingPeriods() bool
+	// InclusionRate is the share of a net gain that is taxable
+	InclusionRate() decimal.Decimal
+	// AllocateSale allocates selling quantity shares on date to a symbol's open tax lots.
+	// Regimes that match sales to lots take them in the order of method.
+	AllocateSale(lots []*models.TaxLot, quantity decimal.Decimal, date time.Time, method models.CostBasisMethod) ([]*LotAllocation, error)
+	// RealizedGains returns the gains realized by the portfolio's sales and returns of
+	// capital, and distributed by its funds, dated from start through end
+	RealizedGains(ctx context.Context, records TaxRecords, portfolio *models.Portfolio, start, end time.Time) ([]*RealizedGain, error)
+}
+
+// TaxRecords loads the records of one portfolio that a tax regime computes gains from
+type TaxRecords interface {
+	// Transactions returns the portfolio's transactions dated through end, and from start on
+	// when it is set, newest first
+	Transactions(ctx context.Context, start *time.Time, end time.Time) ([]*models.Transaction, error)
+}
+
+// NewTaxRegime returns the tax regime for a code, rounding allocated amounts with rounding
+func NewTaxRegime(code models.TaxRegime, rounding models.RoundingPolicy) (TaxRegime, error) {
+	switch code {
+	case models.TaxRegimeUS, "":
+		return &usTaxRegime{rounding: rounding}, nil
+	case models.TaxRegimeUK:
+		return &ukTaxRegime{rounding: rounding}, nil
+	case models.TaxRegimeCA:
+		return &caTaxRegime{rounding: rounding}, nil
+	default:
+		return nil, models.ErrInvalidTaxRegime
+	}
+}
+
+// usTaxRegime matches sales to tax lots in the order of the portfolio's cost basis method,
+// and gains on lots held for over a year are long term
+type usTaxRegime struct {
+	rounding models.RoundingPolicy
+}
+
+// Code returns models.TaxRegimeUS
+func (r *usTaxRegime) Code() models.TaxRegime {
+	return models.TaxRegimeUS
+}
+
+// HoldingPeriods returns true: gains are short or long term
+func (r *usTaxRegime) HoldingPeriods() bool {
+	return true
+}
+
+// InclusionRate returns one: the whole gain is taxable, at rates that depend on its term
+func (r *usTaxRegime) InclusionRate() decimal.Decimal {
+	return decimal.NewFromInt(1)
+}
+
+// AllocateSale takes the lots in the order of method until the quantity is covered
+func (r *usTaxRegime) AllocateSale(lots []*models.TaxLot, quantity decimal.Decimal, date time.Time, method models.CostBasisMethod) ([]*LotAllocation, error) {
+	sortTaxLots(lots, method)
+
+	allocations := make([]*LotAllocation, 0)
+	remainingQuantity := quantity
+	for _, lot := range lots {
+		if remainingQuantity.IsZero() {
+			break
+		}
+
+		// Determine how much to take from this lot
+		quantityFromLot := decimal.Min(lot.Quantity, remainingQuantity)
+		allocations = append(allocations, &LotAllocation{
+			TaxLot:     lot,
+			Quantity:   quantityFromLot,
+			CostBasis:  lotCostBasis(r.rounding, lot, quantityFromLot),
+			IsLongTerm: lot.IsLongTerm(date),
+		})
+		remainingQuantity = remainingQuantity.Sub(quantityFromLot)
+	}
+
+	// Check if we have enough shares
+	if remainingQuantity.IsPositive() {
+		return nil, models.ErrInsufficientShares
+	}
+	return allocations, nil
+}
+
+// RealizedGains replays the ledger, allocating each sale in the period to the lots it closes,
+// disallowing the losses that are wash sales, and adds the period's capital gain distributions
+// at the term their funds designated. Returns of capital beyond a lot's cost basis are gains
+// too. Whether a loss late in the period is a wash sale depends on the purchases in the 30 days
+// after it, so the ledger is loaded 30 days past the period's end.
+func (r *usTaxRegime) RealizedGains(ctx context.Context, records TaxRecords, portfolio *models.Portfolio, start, end time.Time) ([]*RealizedGain, error) {
+	transactions, err := loadLedger(ctx, records, end.AddDate(0, 0, models.WashSaleWindowDays))
+	if err != nil {
+		return nil, err
+	}
+
+	var gains []*RealizedGain
+	replay := newLedgerReplay(portfolio, r.rounding)
+	washSales := newWashSales(transactions)
+	for _, tx := range transactions {
+		if tx.Date.After(end) {
+			break
+		}
+
+		var saleGains []*RealizedGain
+		if tx.IsSell() && tx.Price != nil {
+			lots, _ := replay.closingLots(tx)
+			saleGains = replay.saleGains(tx)
+			soldLots := make([]uuid.UUID, len(lots))
+			for i, lot := range lots {
+				soldLots[i] = lot.TransactionID
+			}
+			for i, gain := range saleGains {
+				if gain.Gain.IsNegative() {
+					washSales.disallow(replay, tx, gain, lots[i], soldLots)
+				}
+			}
+		}
+		if err := replay.apply(tx); err != nil {
+			return nil, err
+		}
+		washSales.replaced(replay, tx)
+		if !tx.Date.Before(start) {
+			gains = append(gains, saleGains...)
+		}
+	}
+
+	gains = append(gains, gainsFrom(replay.gains, start)...)
+	return append(gains, distributionGains(r.rounding, transactions, start, end)...), nil
+}
+
+// washSaleAdjustment is the part of a disallowed loss that replacement shares carry, along
+// with the holding period of the shares that were sold
+type washSaleAdjustment struct {
+	quantity decimal.Decimal
+	loss     decimal.Decimal
+	heldFor  time.Duration
+}
+
+// washSales matches loss sales to the purchases that replaced their shares, earliest sale
+// first, as a ledger is replayed. Each purchase replaces only as many shares as it bought.
+type washSales struct {
+	transactions []*models.Transaction
+	// unused is how many shares of each purchase haven't replaced shares sold yet
+	unused map[uuid.UUID]decimal.Decimal
+	// pending are the adjustments to purchases that haven't been replayed yet
+	pending map[uuid.UUID][]*washSaleAdjustment
+}
+
+// newWashSales matches loss sales to the purchases among transactions
+func newWashSales(transactions []*models.Transaction) *washSales {
+	return &washSales{
+		transactions: transactions,
+		unused:       make(map[uuid.UUID]decimal.Decimal),
+		pending:      make(map[uuid.UUID][]*washSaleAdjustment),
+	}
+}
+
+// disallow disallows the part of a loss the sale realized on a lot for which shares of the
+// same symbol were bought within 30 days before or after the sale, other than the shares of
+// the lots the sale closed. The disallowed loss is added to the basis of the replacement
+// shares, which also take on the holding period of the shares sold.
+func (w *washSales) disallow(replay *ledgerReplay, sale *models.Transaction, gain *RealizedGain, sold *models.TaxLot, soldLots []uuid.UUID) {
+	saleDay := transactionDay(sale)
+	var purchases []*models.Transaction
+	var quantities []decimal.Decimal
+	replaced := decimal.Zero
+	for _, tx := range w.transactions {
+		if replaced.Equal(gain.Quantity) {
+			break
+		}
+		if !tx.IsBuy() || tx.Symbol != sale.Symbol || slices.Contains(soldLots, tx.ID) {
+			continue
+		}
+		day := transactionDay(tx)
+		if day.Before(saleDay.AddDate(0, 0, -models.WashSaleWindowDays)) || day.After(saleDay.AddDate(0, 0, models.WashSaleWindowDays)) {
+			continue
+		}
+		available, ok := w.unused[tx.ID]
+		if !ok {
+			available = tx.Quantity
+		}
+		quantity := decimal.Min(available, gain.Quantity.Sub(replaced))
+		if !quantity.IsPositive() {
+			continue
+		}
+		w.unused[tx.ID] = available.Sub(quantity)
+		purchases = append(purchases, tx)
+		quantities = append(quantities, quantity)
+		replaced = replaced.Add(quantity)
+	}
+	if replaced.IsZero() {
+		return
+	}
+
+	disallowed := replay.rounding.RoundAmount(gain.Gain.Neg().Mul(replaced).Div(gain.Quantity))
+	gain.DisallowedLoss = &disallowed
+	gain.Gain = gain.Gain.Add(disallowed)
+
+	losses := replay.rounding.AllocateAmount(disallowed, quantities)
+	for i, purchase := range purchases {
+		adjustment := &washSaleAdjustment{quantity: quantities[i], loss: losses[i], heldFor: sale.Date.Sub(sold.PurchaseDate)}
+		if !w.carryOver(replay, purchase, adjustment) {
+			w.pending[purchase.ID] = append(w.pending[purchase.ID], adjustment)
+		}
+	}
+}
+
+// replaced carries the losses disallowed by earlier sales over to the shares a purchase just
+// replayed bought
+func (w *washSales) replaced(replay *ledgerReplay, tx *models.Transaction) {
+	for _, adjustment := range w.pending[tx.ID] {
+		w.carryOver(replay, tx, adjustment)
+	}
+	delete(w.pending, tx.ID)
+}
+
+// carryOver adds a disallowed loss to the basis of the replacement shares in a purchase's lot,
+// splitting them from the rest of the lot, and backdates them by the holding period of the
+// shares sold. Returns false when the purchase has no lot open in the replay.
+func (w *washSales) carryOver(replay *ledgerReplay, purchase *models.Transaction, adjustment *washSaleAdjustment) bool {
+	lots := replay.taxLots[purchase.Symbol]
+	for _, lot := range lots {
+		// Shares already backdated replace earlier sales
+		if lot.TransactionID != purchase.ID || !lot.PurchaseDate.Equal(purchase.Date) {
+			continue
+		}
+
+		replacement := lot
+		if quantity := adjustment.quantity; quantity.LessThan(lot.Quantity) {
+			costBasis := lotCostBasis(replay.rounding, lot, quantity)
+			replacement = &models.TaxLot{
+				PortfolioID:   lot.PortfolioID,
+				Symbol:        lot.Symbol,
+				Quantity:      quantity,
+				CostBasis:     costBasis,
+				TransactionID: lot.TransactionID,
+			}
+			lot.Quantity = lot.Quantity.Sub(quantity)
+			lot.CostBasis = lot.CostBasis.Sub(costBasis)
+			replay.taxLots[purchase.Symbol] = append(lots, replacement)
+		}
+		replacement.CostBasis = replacement.CostBasis.Add(adjustment.loss)
+		replacement.PurchaseDate = purchase.Date.Add(-adjustment.heldFor)
+
+		if holding, ok := replay.holdings[purchase.Symbol]; ok {
+			holding.CostBasis = holding.CostBasis.Add(adjustment.loss)
+			holding.CalculateAvgCostPrice()
+		}
+		return true
+	}
+	return false
+}
+
+// lotCostBasis returns the cost basis of quantity shares taken from a lot at its average
+// cost. Taking the whole lot takes its whole cost basis, so nothing is lost to rounding.
+func lotCostBasis(rounding models.RoundingPolicy, lot *models.TaxLot, quantity decimal.Decimal) decimal.Decimal {
+	if quantity.Equal(lot.Quantity) {
+		return lot.CostBasis
+	}
+	return rounding.RoundAmount(lot.GetCostPerShare().Mul(quantity))
+}
+
+// loadLedger returns a portfolio's transactions dated through end in the order they are
+// replayed
+func loadLedger(ctx context.Context, records TaxRecords, end time.Time) ([]*models.Transaction, error) {
+	transactions, err := records.Transactions(ctx, nil, end)
+	if err != nil {
+		return nil, err
+	}
+	sortTransactionsForReplay(transactions)
+	return transactions, nil
+}
+
+// gainsFrom returns the gains realized on or after start
+func gainsFrom(gains []*RealizedGain, start time.Time) []*RealizedGain {
+	var from []*RealizedGain
+	for _, gain := range gains {
+		if !gain.SaleDate.Before(start) {
+			from = append(from, gain)
+		}
+	}
+	return from
+}
+
+// distributionGains returns the capital gains distributed by funds among transactions dated
+// from start through end. A distribution is all gain, with no shares or cost basis behind it,
+// and its term is the one its fund designated.
+func distributionGains(rounding models.RoundingPolicy, transactions []*models.Transaction, start, end time.Time) []*RealizedGain {
+	var gains []*RealizedGain
+	for _, tx := range transactions {
+		if tx.Type != models.TransactionTypeCapitalGainDistribution || tx.Date.Before(start) || tx.Date.After(end) {
+			continue
+		}
+		amount := rounding.RoundAmount(tx.Quantity)
+		gains = append(gains, &RealizedGain{
+			Symbol:       tx.Symbol,
+			PurchaseDate: tx.Date,
+			SaleDate:     tx.Date,
+			Quantity:     decimal.Zero,
+			CostBasis:    decimal.Zero,
+			Proceeds:     amount,
+			Gain:         amount,
+			IsLongTerm:   tx.IsLongTermDistribution(),
+			Rule:         GainRuleCapitalGainDistribution,
+		})
+	}
+	return gains
+}
+
+// pooledGains marks gains realized by returns of capital on a pool of shares, which have no
+// holding period, with the pool's rule
+func pooledGains(gains []*RealizedGain, rule string) []*RealizedGain {
+	for _, gain := range gains {
+		gain.IsLongTerm = false
+		gain.Rule = rule
+	}
+	return gains
+}
+
+// pooledAllocation allocates a sale to a symbol's lots oldest first, costing the shares taken
+// at the average cost of every share held, as regimes that pool shares do. Taking every share
+// takes the whole cost basis.
+func pooledAllocation(rounding models.RoundingPolicy, lots []*models.TaxLot, quantity decimal.Decimal) ([]*LotAllocation, error) {
+	sortTaxLots(lots, models.CostBasisFIFO)
+
+	held, cost := decimal.Zero, decimal.Zero
+	for _, lot := range lots {
+		held = held.Add(lot.Quantity)
+		cost = cost.Add(lot.CostBasis)
+	}
+	if len(lots) == 0 || held.LessThan(quantity) {
+		return nil, models.ErrInsufficientShares
+	}
+	soldCost := cost
+	if !quantity.Equal(held) {
+		soldCost = rounding.RoundAmount(cost.Mul(quantity).Div(held))
+	}
+
+	var taken []*models.TaxLot
+	var quantities []decimal.Decimal
+	remaining := quantity
+	for _, lot := range lots {
+		if remaining.IsZero() {
+			break
+		}
+		q := decimal.Min(lot.Quantity, remaining)
+		taken = append(taken, lot)
+		quantities = append(quantities, q)
+		remaining = remaining.Sub(q)
+	}
+
+	costBases := rounding.AllocateAmount(soldCost, quantities)
+	allocations := make([]*LotAllocation, len(taken))
+	for i, lot := range taken {
+		allocations[i] = &LotAllocation{TaxLot: lot, Quantity: quantities[i], CostBasis: costBases[i]}
+	}
+	return allocations, nil
+}
+
+// pooledSaleGain returns the gain of selling tx's shares for proceeds out of the replayed
+// holding at its average cost. The purchase date is that of the oldest open lot.
+func pooledSaleGain(replay *ledgerReplay, tx *models.Transaction, proceeds decimal.Decimal, rule string) (*RealizedGain, error) {
+	holding, err := replay.requireHolding(tx, tx.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if holding.Quantity.LessThan(tx.Quantity) {
+		return nil, fmt.Errorf("%w: transaction %s sells %s shares of %s but only %s are held",
+			models.ErrLedgerReplayFailed, tx.ID, tx.Quantity, tx.Symbol, holding.Quantity)
+	}
+
+	costBasis := holding.CostBasis
+	if !tx.Quantity.Equal(holding.Quantity) {
+		costBasis = replay.rounding.RoundAmount(holding.AvgCostPrice.Mul(tx.Quantity))
+	}
+	gain := &RealizedGain{
+		Symbol:    tx.Symbol,
+		SaleDate:  tx.Date,
+		Quantity:  tx.Quantity,
+		CostBasis: costBasis,
+		Proceeds:  proceeds,
+		Gain:      proceeds.Sub(costBasis),
+		Rule:      rule,
+	}
+	for _, lot := range replay.taxLots[tx.Symbol] {
+		if gain.PurchaseDate.IsZero() || lot.PurchaseDate.Before(gain.PurchaseDate) {
+			gain.PurchaseDate = lot.PurchaseDate
+		}
+	}
+	return gain, nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// superficialLossDays is how many days before or after a loss sale a purchase of the same
+// shares makes the loss superficial under Canadian rules
+const superficialLossDays = 30
+
+// caTaxRegime costs sales at the adjusted cost base, the average cost of every share of the
+// symbol held, and half of a net gain is taxable. A loss is superficial, and denied, for the
+// shares bought within 30 days of the sale and still held 30 days after it; the denied loss
+// is added to the cost base of the shares still held.
+type caTaxRegime struct {
+	rounding models.RoundingPolicy
+}
+
+// quantityChange is the quantity of a symbol held after a transaction
+type quantityChange struct {
+	date     time.Time
+	quantity decimal.Decimal
+}
+
+// Code returns models.TaxRegimeCA
+func (r *caTaxRegime) Code() models.TaxRegime {
+	return models.TaxRegimeCA
+}
+
+// HoldingPeriods returns false: gains are taxed alike however long the shares were held
+func (r *caTaxRegime) HoldingPeriods() bool {
+	return false
+}
+
+// InclusionRate returns one half: only half of a net capital gain is taxable
+func (r *caTaxRegime) InclusionRate() decimal.Decimal {
+	return decimal.NewFromFloat(0.5)
+}
+
+// AllocateSale costs the shares at the adjusted cost base, taking them from the oldest lots
+func (r *caTaxRegime) AllocateSale(lots []*models.TaxLot, quantity decimal.Decimal, date time.Time, method models.CostBasisMethod) ([]*LotAllocation, error) {
+	return pooledAllocation(r.rounding, lots, quantity)
+}
+
+// RealizedGains replays the ledger, costing each sale at the adjusted cost base, and adds
+// funds' capital gain distributions. Whether a loss late in the period is superficial depends
+// on the shares held 30 days after it, so the ledger is loaded 30 days past the period's end
+// and replayed once to find them.
+func (r *caTaxRegime) RealizedGains(ctx context.Context, records TaxRecords, portfolio *models.Portfolio, start, end time.Time) ([]*RealizedGain, error) {
+	transactions, err := loadLedger(ctx, records, end.AddDate(0, 0, superficialLossDays))
+	if err != nil {
+		return nil, err
+	}
+
+	held := make(map[string][]quantityChange)
+	replay := newLedgerReplay(portfolio, r.rounding)
+	for _, tx := range transactions {
+		if err := replay.apply(tx); err != nil {
+			return nil, err
+		}
+		quantity := decimal.Zero
+		if holding, ok := replay.holdings[tx.Symbol]; ok {
+			quantity = holding.Quantity
+		}
+		held[tx.Symbol] = append(held[tx.Symbol], quantityChange{date: tx.Date, quantity: quantity})
+	}
+
+	var gains []*RealizedGain
+	denied := make(map[string]decimal.Decimal)
+	replay = newLedgerReplay(portfolio, r.rounding)
+	for _, tx := range transactions {
+		if tx.Date.After(end) {
+			break
+		}
+
+		var gain *RealizedGain
+		if tx.IsSell() && tx.Price != nil {
+			gain, err = pooledSaleGain(replay, tx, r.rounding.RoundAmount(tx.GetProceeds()), GainRuleAdjustedCostBase)
+			if err != nil {
+				return nil, err
+			}
+			if gain.Gain.IsNegative() {
+				if quantity := superficialQuantity(transactions, held[tx.Symbol], tx); quantity.IsPositive() {
+					disallowed := r.rounding.RoundAmount(gain.Gain.Neg().Mul(quantity).Div(tx.Quantity))
+					gain.DisallowedLoss = &disallowed
+					gain.Gain = gain.Gain.Add(disallowed)
+					denied[tx.Symbol] = denied[tx.Symbol].Add(disallowed)
+				}
+			}
+		}
+		if err := replay.apply(tx); err != nil {
+			return nil, err
+		}
+		if gain != nil && !tx.Date.Before(start) {
+			gains = append(gains, gain)
+		}
+
+		// A denied loss is added to the cost base once shares are held again
+		if amount := denied[tx.Symbol]; amount.IsPositive() {
+			if holding, ok := replay.holdings[tx.Symbol]; ok && holding.Quantity.IsPositive() {
+				holding.CostBasis = holding.CostBasis.Add(amount)
+				holding.CalculateAvgCostPrice()
+				if lots := replay.taxLots[tx.Symbol]; len(lots) > 0 {
+					newest := lots[0]
+					for _, lot := range lots {
+						if lot.PurchaseDate.After(newest.PurchaseDate) {
+							newest = lot
+						}
+					}
+					newest.CostBasis = newest.CostBasis.Add(amount)
+				}
+				delete(denied, tx.Symbol)
+			}
+		}
+	}
+
+	gains = append(gains, pooledGains(gainsFrom(replay.gains, start), GainRuleAdjustedCostBase)...)
+	return append(gains, pooledGains(distributionGains(r.rounding, transactions, start, end), GainRuleCapitalGainDistribution)...), nil
+}
+
+// superficialQuantity returns how many of the shares a loss sale sold were substituted: the
+// least of the shares sold, the shares bought within 30 days before or after the sale, and
+// the shares held 30 days after it
+func superficialQuantity(transactions []*models.Transaction, held []quantityChange, sale *models.Transaction) decimal.Decimal {
+	windowStart := transactionDay(sale).AddDate(0, 0, -superficialLossDays)
+	windowEnd := transactionDay(sale).AddDate(0, 0, superficialLossDays+1)
+
+	bought := decimal.Zero
+	for _, tx := range transactions {
+		if tx.IsBuy() && tx.Symbol == sale.Symbol && !tx.Date.Before(windowStart) && tx.Date.Before(windowEnd) {
+			bought = bought.Add(tx.Quantity)
+		}
+	}
+
+	heldAfter := decimal.Zero
+	for _, change := range held {
+		if change.date.Before(windowEnd) {
+			heldAfter = change.quantity
+		}
+	}
+	return decimal.Min(sale.Quantity, bought, heldAfter)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestCATaxRegime_RealizedGains(t *testing.T) {
+	period := func(year int) (time.Time, time.Time) {
+		return time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(year, 12, 31, 23, 59, 59, 0, time.UTC)
+	}
+	regime, err := NewTaxRegime(models.TaxRegimeCA, models.DefaultRoundingPolicy())
+	require.NoError(t, err)
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO, TaxRegime: models.TaxRegimeCA}
+
+	t.Run("superficial loss is denied and added to the cost base", func(t *testing.T) {
+		records := &fakeTaxRecords{}
+		records.add(models.TransactionTypeBuy, "RY", taxRegimeDay(2024, 1, 10), "100", "50")
+		records.add(models.TransactionTypeSell, "RY", taxRegimeDay(2024, 6, 1), "100", "40")
+		records.add(models.TransactionTypeBuy, "RY", taxRegimeDay(2024, 6, 15), "60", "38")
+		records.add(models.TransactionTypeSell, "RY", taxRegimeDay(2024, 10, 1), "60", "50")
+
+		start, end := period(2024)
+		gains, err := regime.RealizedGains(context.Background(), records, portfolio, start, end)
+		require.NoError(t, err)
+		require.Len(t, gains, 2)
+
+		// 60 of the 100 shares sold at a 1,000 loss were bought back and held
+		loss := gains[0]
+		assert.Equal(t, GainRuleAdjustedCostBase, loss.Rule)
+		assert.True(t, decimal.NewFromInt(5000).Equal(loss.CostBasis))
+		require.NotNil(t, loss.DisallowedLoss)
+		assert.True(t, decimal.NewFromInt(600).Equal(*loss.DisallowedLoss))
+		assert.True(t, decimal.NewFromInt(-400).Equal(loss.Gain))
+
+		// The denied loss raised the cost base of the repurchased shares from 2,280
+		gain := gains[1]
+		assert.True(t, decimal.NewFromInt(2880).Equal(gain.CostBasis), "cost %s", gain.CostBasis)
+		assert.True(t, decimal.NewFromInt(120).Equal(gain.Gain))
+		assert.Nil(t, gain.DisallowedLoss)
+	})
+
+	t.Run("loss is allowed when the shares bought back are sold within 30 days", func(t *testing.T) {
+		records := &fakeTaxRecords{}
+		records.add(models.TransactionTypeBuy, "RY", taxRegimeDay(2024, 1, 10), "100", "50")
+		records.add(models.TransactionTypeSell, "RY", taxRegimeDay(2024, 6, 1), "100", "40")
+		records.add(models.TransactionTypeBuy, "RY", taxRegimeDay(2024, 6, 15), "60", "38")
+		records.add(models.TransactionTypeSell, "RY", taxRegimeDay(2024, 6, 20), "60", "39")
+
+		start, end := period(2024)
+		gains, err := regime.RealizedGains(context.Background(), records, portfolio, start, end)
+		require.NoError(t, err)
+		require.Len(t, gains, 2)
+		assert.Nil(t, gains[0].DisallowedLoss)
+		assert.True(t, decimal.NewFromInt(-1000).Equal(gains[0].Gain))
+	})
+
+	t.Run("shares held after the period's end make a loss superficial", func(t *testing.T) {
+		records := &fakeTaxRecords{}
+		records.add(models.TransactionTypeBuy, "RY", taxRegimeDay(2024, 1, 10), "100", "50")
+		records.add(models.TransactionTypeSell, "RY", taxRegimeDay(2024, 12, 20), "100", "40")
+		records.add(models.TransactionTypeBuy, "RY", taxRegimeDay(2025, 1, 3), "100", "41")
+
+		start, end := period(2024)
+		gains, err := regime.RealizedGains(context.Background(), records, portfolio, start, end)
+		require.NoError(t, err)
+		require.Len(t, gains, 1)
+		require.NotNil(t, gains[0].DisallowedLoss)
+		assert.True(t, decimal.NewFromInt(1000).Equal(*gains[0].DisallowedLoss))
+		assert.True(t, gains[0].Gain.IsZero())
+	})
+	t.Run("capital gain distributions are gains without a cost base", func(t *testing.T) {
+		records := &fakeTaxRecords{}
+		records.add(models.TransactionTypeBuy, "XIU", taxRegimeDay(2024, 1, 10), "100", "30")
+		records.add(models.TransactionTypeCapitalGainDistribution, "XIU", taxRegimeDay(2024, 12, 20), "42.5", "0").Price = nil
+
+		start, end := period(2024)
+		gains, err := regime.RealizedGains(context.Background(), records, portfolio, start, end)
+		require.NoError(t, err)
+		require.Len(t, gains, 1)
+		assert.Equal(t, GainRuleCapitalGainDistribution, gains[0].Rule)
+		assert.True(t, gains[0].CostBasis.IsZero())
+		assert.True(t, decimal.RequireFromString("42.5").Equal(gains[0].Gain))
+		assert.False(t, gains[0].IsLongTerm)
+	})
+}
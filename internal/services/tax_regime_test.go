@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// fakeTaxRecords serves a portfolio's transactions from memory
+type fakeTaxRecords struct {
+	transactions []*models.Transaction
+}
+
+func (f *fakeTaxRecords) Transactions(ctx context.Context, start *time.Time, end time.Time) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	for _, tx := range f.transactions {
+		if (start == nil || !tx.Date.Before(*start)) && !tx.Date.After(end) {
+			transactions = append(transactions, tx)
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.After(transactions[j].Date)
+	})
+	return transactions, nil
+}
+
+// add records a transaction after those already added
+func (f *fakeTaxRecords) add(txType models.TransactionType, symbol string, date time.Time, quantity, price string) *models.Transaction {
+	p := decimal.RequireFromString(price)
+	tx := &models.Transaction{
+		ID:         uuid.New(),
+		Type:       txType,
+		Symbol:     symbol,
+		Date:       date,
+		Quantity:   decimal.RequireFromString(quantity),
+		Price:      &p,
+		Multiplier: 1,
+		Commission: decimal.Zero,
+		CreatedAt:  time.Date(2000, 1, 1, 0, 0, len(f.transactions), 0, time.UTC),
+	}
+	f.transactions = append(f.transactions, tx)
+	return tx
+}
+
+func taxRegimeDay(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 15, 0, 0, 0, time.UTC)
+}
+
+func TestNewTaxRegime(t *testing.T) {
+	rounding := models.DefaultRoundingPolicy()
+
+	tests := []struct {
+		code      models.TaxRegime
+		want      models.TaxRegime
+		periods   bool
+		inclusion string
+	}{
+		{code: "", want: models.TaxRegimeUS, periods: true, inclusion: "1"},
+		{code: models.TaxRegimeUS, want: models.TaxRegimeUS, periods: true, inclusion: "1"},
+		{code: models.TaxRegimeUK, want: models.TaxRegimeUK, periods: false, inclusion: "1"},
+		{code: models.TaxRegimeCA, want: models.TaxRegimeCA, periods: false, inclusion: "0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.want), func(t *testing.T) {
+			regime, err := NewTaxRegime(tt.code, rounding)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, regime.Code())
+			assert.Equal(t, tt.periods, regime.HoldingPeriods())
+			assert.True(t, decimal.RequireFromString(tt.inclusion).Equal(regime.InclusionRate()))
+		})
+	}
+
+	_, err := NewTaxRegime("FR", rounding)
+	assert.ErrorIs(t, err, models.ErrInvalidTaxRegime)
+}
+
+func TestPooledAllocation(t *testing.T) {
+	rounding := models.DefaultRoundingPolicy()
+	newLots := func() []*models.TaxLot {
+		return []*models.TaxLot{
+			{Symbol: "VOD", PurchaseDate: taxRegimeDay(2024, 3, 1), Quantity: decimal.NewFromInt(100), CostBasis: decimal.NewFromInt(2000)},
+			{Symbol: "VOD", PurchaseDate: taxRegimeDay(2024, 1, 10), Quantity: decimal.NewFromInt(100), CostBasis: decimal.NewFromInt(1000)},
+		}
+	}
+
+	t.Run("costs shares at the average cost, oldest lots first", func(t *testing.T) {
+		allocations, err := pooledAllocation(rounding, newLots(), decimal.NewFromInt(150))
+		require.NoError(t, err)
+		require.Len(t, allocations, 2)
+		assert.Equal(t, taxRegimeDay(2024, 1, 10), allocations[0].TaxLot.PurchaseDate)
+		assert.True(t, decimal.NewFromInt(100).Equal(allocations[0].Quantity))
+		assert.True(t, decimal.NewFromInt(1500).Equal(allocations[0].CostBasis))
+		assert.True(t, decimal.NewFromInt(50).Equal(allocations[1].Quantity))
+		assert.True(t, decimal.NewFromInt(750).Equal(allocations[1].CostBasis))
+	})
+
+	t.Run("selling every share takes the whole cost", func(t *testing.T) {
+		allocations, err := pooledAllocation(rounding, newLots(), decimal.NewFromInt(200))
+		require.NoError(t, err)
+		total := decimal.Zero
+		for _, allocation := range allocations {
+			total = total.Add(allocation.CostBasis)
+		}
+		assert.True(t, decimal.NewFromInt(3000).Equal(total))
+	})
+
+	t.Run("insufficient shares", func(t *testing.T) {
+		_, err := pooledAllocation(rounding, newLots(), decimal.NewFromInt(201))
+		assert.ErrorIs(t, err, models.ErrInsufficientShares)
+
+		_, err = pooledAllocation(rounding, nil, decimal.NewFromInt(1))
+		assert.ErrorIs(t, err, models.ErrInsufficientShares)
+	})
+}
+
+func TestUSTaxRegime_RealizedGains_DisallowsWashSales(t *testing.T) {
+	records := &fakeTaxRecords{}
+	records.add(models.TransactionTypeBuy, "AAPL", taxRegimeDay(2024, 1, 10), "100", "50")
+	records.add(models.TransactionTypeSell, "AAPL", taxRegimeDay(2024, 6, 1), "100", "40")
+	records.add(models.TransactionTypeBuy, "AAPL", taxRegimeDay(2024, 6, 15), "60", "38")
+
+	regime, err := NewTaxRegime(models.TaxRegimeUS, models.DefaultRoundingPolicy())
+	require.NoError(t, err)
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO}
+
+	gains, err := regime.RealizedGains(context.Background(), records, portfolio,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC))
+	require.NoError(t, err)
+
+	// 60 of the 100 shares sold at a $1,000 loss were bought back 14 days later
+	require.Len(t, gains, 1)
+	require.NotNil(t, gains[0].DisallowedLoss)
+	assert.True(t, decimal.NewFromInt(600).Equal(*gains[0].DisallowedLoss))
+	assert.True(t, decimal.NewFromInt(-400).Equal(gains[0].Gain))
+}
+
+func TestUSTaxRegime_RealizedGains_SellsWashSaleReplacements(t *testing.T) {
+	records := &fakeTaxRecords{}
+	records.add(models.TransactionTypeBuy, "AAPL", taxRegimeDay(2024, 1, 10), "100", "50")
+	records.add(models.TransactionTypeSell, "AAPL", taxRegimeDay(2024, 6, 1), "40", "40")
+	records.add(models.TransactionTypeBuy, "AAPL", taxRegimeDay(2024, 6, 15), "100", "38")
+	records.add(models.TransactionTypeSell, "AAPL", taxRegimeDay(2025, 2, 3), "100", "45")
+
+	regime, err := NewTaxRegime(models.TaxRegimeUS, models.DefaultRoundingPolicy())
+	require.NoError(t, err)
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO}
+
+	gains, err := regime.RealizedGains(context.Background(), records, portfolio,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, gains, 1)
+	require.NotNil(t, gains[0].DisallowedLoss)
+	assert.True(t, decimal.NewFromInt(400).Equal(*gains[0].DisallowedLoss))
+
+	gains, err = regime.RealizedGains(context.Background(), records, portfolio,
+		time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC))
+	require.NoError(t, err)
+
+	// The rest of the first lot goes first, then the 40 replacement shares, which cost $1,520
+	// plus the $400 loss and were held since the first lot's 143 days before they were bought
+	require.Len(t, gains, 2)
+	assert.Equal(t, taxRegimeDay(2024, 1, 10), gains[0].PurchaseDate)
+	assert.True(t, decimal.NewFromInt(60).Equal(gains[0].Quantity))
+	assert.True(t, decimal.NewFromInt(-300).Equal(gains[0].Gain))
+
+	replacement := gains[1]
+	assert.Equal(t, taxRegimeDay(2024, 1, 24), replacement.PurchaseDate)
+	assert.True(t, decimal.NewFromInt(40).Equal(replacement.Quantity))
+	assert.True(t, decimal.NewFromInt(1920).Equal(replacement.CostBasis), replacement.CostBasis.String())
+	assert.True(t, decimal.NewFromInt(-120).Equal(replacement.Gain), replacement.Gain.String())
+	assert.True(t, replacement.IsLongTerm)
+	assert.Nil(t, replacement.DisallowedLoss)
+}
+
+func TestUSTaxRegime_RealizedGains_SharesSoldDontReplaceThemselves(t *testing.T) {
+	records := &fakeTaxRecords{}
+	records.add(models.TransactionTypeBuy, "AAPL", taxRegimeDay(2024, 5, 20), "100", "50")
+	records.add(models.TransactionTypeSell, "AAPL", taxRegimeDay(2024, 6, 1), "100", "40")
+
+	regime, err := NewTaxRegime(models.TaxRegimeUS, models.DefaultRoundingPolicy())
+	require.NoError(t, err)
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO}
+
+	gains, err := regime.RealizedGains(context.Background(), records, portfolio,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, gains, 1)
+	assert.Nil(t, gains[0].DisallowedLoss)
+	assert.True(t, decimal.NewFromInt(-1000).Equal(gains[0].Gain))
+}
+
+func TestUSTaxRegime_RealizedGains_CapitalGainDistributions(t *testing.T) {
+	records := &fakeTaxRecords{}
+	records.add(models.TransactionTypeBuy, "VFIAX", taxRegimeDay(2024, 1, 10), "10", "450")
+	records.add(models.TransactionTypeCapitalGainDistribution, "VFIAX", taxRegimeDay(2023, 12, 20), "30", "0").Price = nil
+	shortTerm := records.add(models.TransactionTypeCapitalGainDistribution, "VFIAX", taxRegimeDay(2024, 6, 20), "4.25", "0")
+	shortTerm.Price = nil
+	shortTerm.Category = models.CapitalGainTermShort
+	records.add(models.TransactionTypeCapitalGainDistribution, "VFIAX", taxRegimeDay(2024, 12, 20), "18.6", "0").Price = nil
+
+	regime, err := NewTaxRegime(models.TaxRegimeUS, models.DefaultRoundingPolicy())
+	require.NoError(t, err)
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO}
+
+	gains, err := regime.RealizedGains(context.Background(), records, portfolio,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC))
+	require.NoError(t, err)
+
+	// The 2023 distribution is outside the period, and the rest keep the term their fund gave
+	require.Len(t, gains, 2)
+	terms := make(map[bool]*RealizedGain)
+	for _, gain := range gains {
+		assert.Equal(t, GainRuleCapitalGainDistribution, gain.Rule)
+		assert.True(t, gain.CostBasis.IsZero())
+		assert.True(t, gain.Proceeds.Equal(gain.Gain))
+		terms[gain.IsLongTerm] = gain
+	}
+	require.Len(t, terms, 2)
+	assert.True(t, decimal.RequireFromString("18.6").Equal(terms[true].Gain))
+	assert.True(t, decimal.RequireFromString("4.25").Equal(terms[false].Gain))
+}
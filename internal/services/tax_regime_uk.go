@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// bedAndBreakfastDays is how many days after a sale a purchase of the same shares is matched
+// to it under the UK bed and breakfast rule
+const bedAndBreakfastDays = 30
+
+// ukTaxRegime applies the UK share matching rules: a sale is matched first to shares bought
+// the same day, then to shares bought in the following 30 days, and whatever remains is
+// costed at the average cost of the Section 104 pool of every other share held
+type ukTaxRegime struct {
+	rounding models.RoundingPolicy
+}
+
+// shareMatch is a quantity of a sale matched to an acquisition by a share matching rule
+type shareMatch struct {
+	acquisition *models.Transaction
+	quantity    decimal.Decimal
+	rule        string
+}
+
+// Code returns models.TaxRegimeUK
+func (r *ukTaxRegime) Code() models.TaxRegime {
+	return models.TaxRegimeUK
+}
+
+// HoldingPeriods returns false: gains are taxed alike however long the shares were held
+func (r *ukTaxRegime) HoldingPeriods() bool {
+	return false
+}
+
+// InclusionRate returns one: the whole gain is taxable
+func (r *ukTaxRegime) InclusionRate() decimal.Decimal {
+	return decimal.NewFromInt(1)
+}
+
+// AllocateSale costs the shares at the pool's average cost, taking them from the oldest lots
+func (r *ukTaxRegime) AllocateSale(lots []*models.TaxLot, quantity decimal.Decimal, date time.Time, method models.CostBasisMethod) ([]*LotAllocation, error) {
+	return pooledAllocation(r.rounding, lots, quantity)
+}
+
+// RealizedGains matches the sales to acquisitions and replays the unmatched rest of the
+// ledger to cost the remaining shares sold from the Section 104 pool, then adds funds' capital
+// gain distributions. Sales late in the period may be matched to purchases after it, so the
+// ledger is loaded 30 days past its end.
+func (r *ukTaxRegime) RealizedGains(ctx context.Context, records TaxRecords, portfolio *models.Portfolio, start, end time.Time) ([]*RealizedGain, error) {
+	transactions, err := loadLedger(ctx, records, end.AddDate(0, 0, bedAndBreakfastDays))
+	if err != nil {
+		return nil, err
+	}
+	matches, unmatched := matchShares(transactions)
+
+	var gains []*RealizedGain
+	replay := newLedgerReplay(portfolio, r.rounding)
+	for _, tx := range transactions {
+		if tx.Date.After(end) {
+			break
+		}
+		reported := tx.IsSell() && tx.Price != nil && !tx.Date.Before(start)
+
+		remaining, tracked := unmatched[tx]
+		if !tracked || remaining.Equal(tx.Quantity) {
+			if reported {
+				gain, err := pooledSaleGain(replay, tx, r.rounding.RoundAmount(tx.GetProceeds()), GainRuleSection104)
+				if err != nil {
+					return nil, err
+				}
+				gains = append(gains, gain)
+			}
+			if err := replay.apply(tx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// Split the sale's proceeds across its matches and its pooled remainder by quantity
+		if reported {
+			weights := make([]decimal.Decimal, 0, len(matches[tx])+1)
+			for _, match := range matches[tx] {
+				weights = append(weights, match.quantity)
+			}
+			weights = append(weights, remaining)
+			proceeds := r.rounding.AllocateAmount(r.rounding.RoundAmount(tx.GetProceeds()), weights)
+
+			for i, match := range matches[tx] {
+				costBasis := r.rounding.RoundAmount(match.acquisition.GetTotalCost().Mul(match.quantity).Div(match.acquisition.Quantity))
+				gains = append(gains, &RealizedGain{
+					Symbol:       tx.Symbol,
+					PurchaseDate: match.acquisition.Date,
+					SaleDate:     tx.Date,
+					Quantity:     match.quantity,
+					CostBasis:    costBasis,
+					Proceeds:     proceeds[i],
+					Gain:         proceeds[i].Sub(costBasis),
+					Rule:         match.rule,
+				})
+			}
+			if remaining.IsPositive() {
+				gain, err := pooledSaleGain(replay, unmatchedPart(tx, remaining), proceeds[len(proceeds)-1], GainRuleSection104)
+				if err != nil {
+					return nil, err
+				}
+				gains = append(gains, gain)
+			}
+		}
+
+		// Matched shares never enter or leave the pool
+		if remaining.IsPositive() {
+			if err := replay.apply(unmatchedPart(tx, remaining)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	gains = append(gains, pooledGains(gainsFrom(replay.gains, start), GainRuleSection104)...)
+	return append(gains, pooledGains(distributionGains(r.rounding, transactions, start, end), GainRuleCapitalGainDistribution)...), nil
+}
+
+// matchShares matches sales to acquisitions of the same symbol, first to those made the same
+// day and then, earliest sale first, to those made in the 30 days after the sale. It returns
+// the matches of each transaction, keyed by sale, and the quantity of each sale and
+// acquisition that was left unmatched.
+func matchShares(transactions []*models.Transaction) (map[*models.Transaction][]*shareMatch, map[*models.Transaction]decimal.Decimal) {
+	matches := make(map[*models.Transaction][]*shareMatch)
+	unmatched := make(map[*models.Transaction]decimal.Decimal)
+	var sales, acquisitions []*models.Transaction
+	for _, tx := range transactions {
+		if tx.IsSell() || tx.IsBuy() {
+			unmatched[tx] = tx.Quantity
+		}
+		if tx.IsSell() {
+			sales = append(sales, tx)
+		} else if tx.IsBuy() {
+			acquisitions = append(acquisitions, tx)
+		}
+	}
+
+	match := func(sale *models.Transaction, rule string, eligible func(acquisition *models.Transaction) bool) {
+		for _, acquisition := range acquisitions {
+			if unmatched[sale].IsZero() {
+				return
+			}
+			if acquisition.Symbol != sale.Symbol || unmatched[acquisition].IsZero() || !eligible(acquisition) {
+				continue
+			}
+			quantity := decimal.Min(unmatched[sale], unmatched[acquisition])
+			matches[sale] = append(matches[sale], &shareMatch{acquisition: acquisition, quantity: quantity, rule: rule})
+			unmatched[sale] = unmatched[sale].Sub(quantity)
+			unmatched[acquisition] = unmatched[acquisition].Sub(quantity)
+		}
+	}
+	for _, sale := range sales {
+		day := transactionDay(sale)
+		match(sale, GainRuleSameDay, func(acquisition *models.Transaction) bool {
+			return transactionDay(acquisition).Equal(day)
+		})
+	}
+	for _, sale := range sales {
+		day := transactionDay(sale)
+		match(sale, GainRuleBedAndBreakfast, func(acquisition *models.Transaction) bool {
+			acquired := transactionDay(acquisition)
+			return acquired.After(day) && !acquired.After(day.AddDate(0, 0, bedAndBreakfastDays))
+		})
+	}
+	return matches, unmatched
+}
+
+// unmatchedPart returns a copy of a transaction reduced to quantity shares, with its
+// commission reduced in proportion
+func unmatchedPart(tx *models.Transaction, quantity decimal.Decimal) *models.Transaction {
+	part := *tx
+	part.Quantity = quantity
+	part.Commission = tx.Commission.Mul(quantity).Div(tx.Quantity)
+	return &part
+}
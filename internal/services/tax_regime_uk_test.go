@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/models"
+)
+
+func TestUKTaxRegime_RealizedGains(t *testing.T) {
+	records := &fakeTaxRecords{}
+	records.add(models.TransactionTypeBuy, "VOD", taxRegimeDay(2024, 1, 10), "100", "10")
+	records.add(models.TransactionTypeBuy, "VOD", taxRegimeDay(2024, 3, 1), "100", "20")
+	// Matched to the same day purchase, then to the purchase 17 days later, then the pool
+	records.add(models.TransactionTypeSell, "VOD", taxRegimeDay(2024, 6, 3), "50", "30")
+	records.add(models.TransactionTypeBuy, "VOD", taxRegimeDay(2024, 6, 3), "20", "25")
+	records.add(models.TransactionTypeBuy, "VOD", taxRegimeDay(2024, 6, 20), "10", "28")
+	// Matched to a purchase in the next tax year
+	records.add(models.TransactionTypeSell, "VOD", taxRegimeDay(2024, 12, 20), "180", "10")
+	records.add(models.TransactionTypeBuy, "VOD", taxRegimeDay(2025, 1, 5), "30", "9")
+
+	regime, err := NewTaxRegime(models.TaxRegimeUK, models.DefaultRoundingPolicy())
+	require.NoError(t, err)
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO, TaxRegime: models.TaxRegimeUK}
+
+	gains, err := regime.RealizedGains(context.Background(), records, portfolio,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC))
+	require.NoError(t, err)
+
+	expected := []struct {
+		rule      string
+		purchased time.Time
+		quantity  int64
+		cost      int64
+		proceeds  int64
+		gain      int64
+	}{
+		{GainRuleSameDay, taxRegimeDay(2024, 6, 3), 20, 500, 600, 100},
+		{GainRuleBedAndBreakfast, taxRegimeDay(2024, 6, 20), 10, 280, 300, 20},
+		{GainRuleSection104, taxRegimeDay(2024, 1, 10), 20, 300, 600, 300},
+		{GainRuleBedAndBreakfast, taxRegimeDay(2025, 1, 5), 30, 270, 300, 30},
+		{GainRuleSection104, taxRegimeDay(2024, 1, 10), 150, 2250, 1500, -750},
+	}
+	require.Len(t, gains, len(expected))
+	for i, want := range expected {
+		gain := gains[i]
+		assert.Equal(t, want.rule, gain.Rule, "gain %d", i)
+		assert.Equal(t, want.purchased, gain.PurchaseDate, "gain %d", i)
+		assert.True(t, decimal.NewFromInt(want.quantity).Equal(gain.Quantity), "gain %d quantity %s", i, gain.Quantity)
+		assert.True(t, decimal.NewFromInt(want.cost).Equal(gain.CostBasis), "gain %d cost %s", i, gain.CostBasis)
+		assert.True(t, decimal.NewFromInt(want.proceeds).Equal(gain.Proceeds), "gain %d proceeds %s", i, gain.Proceeds)
+		assert.True(t, decimal.NewFromInt(want.gain).Equal(gain.Gain), "gain %d gain %s", i, gain.Gain)
+		assert.False(t, gain.IsLongTerm)
+	}
+}
+
+func TestUKTaxRegime_RealizedGains_ReportsOnlyThePeriod(t *testing.T) {
+	records := &fakeTaxRecords{}
+	records.add(models.TransactionTypeBuy, "VOD", taxRegimeDay(2023, 1, 10), "100", "10")
+	records.add(models.TransactionTypeSell, "VOD", taxRegimeDay(2023, 6, 1), "50", "12")
+	records.add(models.TransactionTypeSell, "VOD", taxRegimeDay(2024, 6, 1), "50", "14")
+
+	regime, err := NewTaxRegime(models.TaxRegimeUK, models.DefaultRoundingPolicy())
+	require.NoError(t, err)
+	portfolio := &models.Portfolio{ID: uuid.New(), CostBasisMethod: models.CostBasisFIFO, TaxRegime: models.TaxRegimeUK}
+
+	gains, err := regime.RealizedGains(context.Background(), records, portfolio,
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, gains, 1)
+	assert.True(t, decimal.NewFromInt(500).Equal(gains[0].CostBasis))
+	assert.True(t, decimal.NewFromInt(200).Equal(gains[0].Gain))
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services/tracker_parsers"
+)
+
+// trackerNames are the display names of the supported trackers, used to describe the
+// portfolios an import creates
+var trackerNames = map[dto.ImportFormat]string{
+	dto.ImportFormatGhostfolio:           "Ghostfolio",
+	dto.ImportFormatPortfolioPerformance: "Portfolio Performance",
+}
+
+// TrackerImportService defines the interface for importing other portfolio trackers' exports
+type TrackerImportService interface {
+	// Import creates a portfolio for each account of an export holding securities
+	// transactions and imports the account's history into it
+	Import(ctx context.Context, userID string, req dto.TrackerImportRequest) (*dto.TrackerImportResult, error)
+}
+
+// trackerImportService implements TrackerImportService interface
+type trackerImportService struct {
+	portfolioService     PortfolioService
+	importService        CSVImportService
+	recalculationService PortfolioRecalculationService
+	parsers              map[dto.ImportFormat]tracker_parsers.TrackerParser
+}
+
+// NewTrackerImportService creates a new TrackerImportService instance. Each account's
+// transactions are imported as one import batch, then the portfolio is rebuilt from its
+// ledger to create the tax lots of the imported history.
+func NewTrackerImportService(
+	portfolioService PortfolioService,
+	importService CSVImportService,
+	recalculationService PortfolioRecalculationService,
+) TrackerImportService {
+	parsers := map[dto.ImportFormat]tracker_parsers.TrackerParser{
+		dto.ImportFormatGhostfolio:           tracker_parsers.NewGhostfolioParser(),
+		dto.ImportFormatPortfolioPerformance: tracker_parsers.NewPortfolioPerformanceParser(),
+	}
+
+	return &trackerImportService{
+		portfolioService:     portfolioService,
+		importService:        importService,
+		recalculationService: recalculationService,
+		parsers:              parsers,
+	}
+}
+
+// Import imports a tracker export. Nothing is created if any activity can't be mapped,
+// unless SkipInvalid is set. Portfolios are named after the export's accounts, with a
+// number appended to names the user already has. An error creating a portfolio, such as
+// the organization's quota being reached, stops the import; the portfolios imported
+// before it are kept.
+func (s *trackerImportService) Import(ctx context.Context, userID string, req dto.TrackerImportRequest) (*dto.TrackerImportResult, error) {
+	parser, ok := s.parsers[req.Format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported import format: %s", req.Format)
+	}
+
+	// Decode the export (support both raw text and base64)
+	data := []byte(req.Data)
+	if isBase64(req.Data) {
+		if decoded, err := base64.StdEncoding.DecodeString(req.Data); err == nil {
+			data = decoded
+		}
+	}
+
+	export, err := parser.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidImportFile, err)
+	}
+
+	result := &dto.TrackerImportResult{
+		Success:        true,
+		Format:         req.Format,
+		Portfolios:     []*dto.TrackerPortfolioImport{},
+		IgnoredCount:   export.Ignored,
+		ErrorCount:     len(export.Errors),
+		Errors:         export.Errors,
+		ValidationOnly: req.DryRun,
+	}
+	if len(export.Errors) > 0 && !req.SkipInvalid {
+		result.Success = false
+		return result, nil
+	}
+
+	names, err := s.portfolioNames(ctx, userID, req.Format, export.Accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, account := range export.Accounts {
+		imported := &dto.TrackerPortfolioImport{Account: account.Name}
+		result.Portfolios = append(result.Portfolios, imported)
+
+		if req.DryRun {
+			// Parsers validate every transaction they map
+			imported.Result = &dto.ImportResult{
+				Success:        true,
+				TotalRows:      len(account.Transactions),
+				SuccessCount:   len(account.Transactions),
+				Errors:         []dto.ImportError{},
+				ValidationOnly: true,
+			}
+			continue
+		}
+
+		currency := account.Currency
+		if len(currency) != 3 {
+			currency = ""
+		}
+		portfolio, err := s.portfolioService.Create(ctx, userID, names[i],
+			"Imported from "+trackerNames[req.Format], strings.ToUpper(currency), req.CostBasisMethod)
+		if err != nil {
+			return nil, err
+		}
+		imported.Portfolio = dto.ToPortfolioResponse(portfolio)
+
+		var report *RecalculationReport
+		imported.Result, report, err = importAndReplay(ctx, s.importService, s.recalculationService, portfolio.ID.String(), userID, dto.BulkImportRequest{
+			Format:       req.Format,
+			Transactions: account.Transactions,
+			SkipInvalid:  req.SkipInvalid,
+			Notes:        req.Notes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !imported.Result.Success {
+			result.Success = false
+		}
+		if report != nil {
+			imported.TaxLots = report.TaxLots
+		}
+	}
+
+	return result, nil
+}
+
+// portfolioNames picks a name for each account's portfolio that the user doesn't
+// already have
+func (s *trackerImportService) portfolioNames(
+	ctx context.Context,
+	userID string,
+	format dto.ImportFormat,
+	accounts []*tracker_parsers.Account,
+) ([]string, error) {
+	portfolios, err := s.portfolioService.GetAllByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolios: %w", err)
+	}
+
+	taken := make(map[string]bool, len(portfolios)+len(accounts))
+	for _, portfolio := range portfolios {
+		taken[portfolio.Name] = true
+	}
+
+	names := make([]string, len(accounts))
+	for i, account := range accounts {
+		base := strings.TrimSpace(account.Name)
+		if base == "" {
+			base = trackerNames[format]
+		}
+
+		name := base
+		for n := 2; taken[name]; n++ {
+			name = fmt.Sprintf("%s (%d)", base, n)
+		}
+		taken[name] = true
+		names[i] = name
+	}
+	return names, nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+const trackerImportGhostfolioExport = `{
+  "accounts": [
+    {"id": "acc-1", "name": "Brokerage", "currency": "USD"},
+    {"id": "acc-2", "name": "IRA", "currency": "USD"}
+  ],
+  "activities": [
+    {"accountId": "acc-1", "type": "BUY", "symbol": "AAPL", "date": "2023-01-10T00:00:00.000Z", "quantity": 10, "unitPrice": 100, "fee": 1, "currency": "USD"},
+    {"accountId": "acc-1", "type": "BUY", "symbol": "AAPL", "date": "2023-03-10T00:00:00.000Z", "quantity": 5, "unitPrice": 120, "fee": 1, "currency": "USD"},
+    {"accountId": "acc-1", "type": "SELL", "symbol": "AAPL", "date": "2023-06-10T00:00:00.000Z", "quantity": 12, "unitPrice": 150, "fee": 1, "currency": "USD"},
+    {"accountId": "acc-1", "type": "FEE", "symbol": "Account fee", "date": "2023-06-30T00:00:00.000Z", "quantity": 1, "unitPrice": 5, "fee": 0, "currency": "USD"},
+    {"accountId": "acc-2", "type": "BUY", "symbol": "VTI", "date": "2023-02-01T00:00:00.000Z", "quantity": 3, "unitPrice": 200, "fee": 0, "currency": "USD"},
+    {"accountId": "acc-2", "type": "LIABILITY", "symbol": "Mortgage", "date": "2023-02-01T00:00:00.000Z", "quantity": 1, "unitPrice": 1000, "fee": 0, "currency": "USD"}
+  ]
+}`
+
+func setupTrackerImportTest(t *testing.T) (*gorm.DB, TrackerImportService, PortfolioService, *models.User) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.Transaction{}, &models.Holding{}, &models.TaxLot{},
+	))
+
+	user := &models.User{Email: "migrating@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+
+	userRepo := repository.NewUserRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+
+	portfolioService := NewPortfolioService(portfolioRepo, userRepo)
+	service := NewTrackerImportService(
+		portfolioService,
+		NewCSVImportService(transactionRepo, portfolioRepo, holdingRepo),
+		NewPortfolioRecalculationService(db),
+	)
+	return db, service, portfolioService, user
+}
+
+func TestTrackerImportService_Import(t *testing.T) {
+	db, service, portfolioService, user := setupTrackerImportTest(t)
+	ctx := context.Background()
+
+	// An existing portfolio's name is not reused
+	_, err := portfolioService.Create(ctx, user.ID.String(), "Brokerage", "", "USD", models.CostBasisFIFO)
+	require.NoError(t, err)
+
+	result, err := service.Import(ctx, user.ID.String(), dto.TrackerImportRequest{
+		Format:          dto.ImportFormatGhostfolio,
+		Data:            base64.StdEncoding.EncodeToString([]byte(trackerImportGhostfolioExport)),
+		CostBasisMethod: models.CostBasisLIFO,
+		SkipInvalid:     true,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, result.Success)
+	assert.Equal(t, dto.ImportFormatGhostfolio, result.Format)
+	assert.Equal(t, 1, result.IgnoredCount)
+	assert.Equal(t, 1, result.ErrorCount)
+	require.Len(t, result.Portfolios, 2)
+
+	brokerage := result.Portfolios[0]
+	assert.Equal(t, "Brokerage", brokerage.Account)
+	require.NotNil(t, brokerage.Portfolio)
+	assert.Equal(t, "Brokerage (2)", brokerage.Portfolio.Name)
+	assert.Equal(t, "Imported from Ghostfolio", brokerage.Portfolio.Description)
+	assert.Equal(t, models.CostBasisLIFO, brokerage.Portfolio.CostBasisMethod)
+	assert.Equal(t, 3, brokerage.Result.SuccessCount)
+
+	// LIFO sells the second lot first, leaving 3 shares of the first
+	assert.Equal(t, 1, brokerage.TaxLots)
+	lots, err := repository.NewTaxLotRepository(db).FindByPortfolioID(ctx, brokerage.Portfolio.ID.String())
+	require.NoError(t, err)
+	require.Len(t, lots, 1)
+	assert.True(t, decimal.NewFromInt(3).Equal(lots[0].Quantity), lots[0].Quantity.String())
+
+	holding, err := repository.NewHoldingRepository(db).FindByPortfolioIDAndSymbol(ctx, brokerage.Portfolio.ID.String(), "AAPL")
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(3).Equal(holding.Quantity))
+
+	ira := result.Portfolios[1]
+	assert.Equal(t, "IRA", ira.Portfolio.Name)
+	assert.Equal(t, 1, ira.TaxLots)
+	assert.NotEqual(t, brokerage.Result.BatchID, ira.Result.BatchID)
+}
+
+func TestTrackerImportService_Import_RejectsInvalidActivities(t *testing.T) {
+	_, service, portfolioService, user := setupTrackerImportTest(t)
+	ctx := context.Background()
+
+	result, err := service.Import(ctx, user.ID.String(), dto.TrackerImportRequest{
+		Format: dto.ImportFormatGhostfolio,
+		Data:   trackerImportGhostfolioExport,
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	require.Len(t, result.Errors, 1)
+	assert.Equal(t, 6, result.Errors[0].Line)
+	assert.Empty(t, result.Portfolios)
+
+	portfolios, err := portfolioService.GetAllByUserID(ctx, user.ID.String())
+	require.NoError(t, err)
+	assert.Empty(t, portfolios)
+}
+
+func TestTrackerImportService_Import_DryRun(t *testing.T) {
+	_, service, portfolioService, user := setupTrackerImportTest(t)
+	ctx := context.Background()
+
+	result, err := service.Import(ctx, user.ID.String(), dto.TrackerImportRequest{
+		Format:      dto.ImportFormatGhostfolio,
+		Data:        trackerImportGhostfolioExport,
+		DryRun:      true,
+		SkipInvalid: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.True(t, result.ValidationOnly)
+	require.Len(t, result.Portfolios, 2)
+	assert.Nil(t, result.Portfolios[0].Portfolio)
+	assert.Equal(t, 3, result.Portfolios[0].Result.SuccessCount)
+
+	portfolios, err := portfolioService.GetAllByUserID(ctx, user.ID.String())
+	require.NoError(t, err)
+	assert.Empty(t, portfolios)
+}
+
+func TestTrackerImportService_Import_InvalidFile(t *testing.T) {
+	_, service, _, user := setupTrackerImportTest(t)
+
+	_, err := service.Import(context.Background(), user.ID.String(), dto.TrackerImportRequest{
+		Format: dto.ImportFormatPortfolioPerformance,
+		Data:   trackerImportGhostfolioExport,
+	})
+	assert.True(t, errors.Is(err, models.ErrInvalidImportFile))
+}
@@ -0,0 +1,196 @@
+package tracker_parsers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// ghostfolioDefaultAccount names the account of activities that aren't assigned to one
+const ghostfolioDefaultAccount = "Ghostfolio"
+
+// ghostfolioExport is the subset of a Ghostfolio export (Account > Export Data) that is imported
+type ghostfolioExport struct {
+	Accounts []struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Currency string `json:"currency"`
+	} `json:"accounts"`
+	Activities []ghostfolioActivity `json:"activities"`
+	User       struct {
+		Settings struct {
+			BaseCurrency string `json:"baseCurrency"`
+			Currency     string `json:"currency"`
+		} `json:"settings"`
+	} `json:"user"`
+}
+
+// ghostfolioActivity is an entry of a Ghostfolio export's activities
+type ghostfolioActivity struct {
+	AccountID *string         `json:"accountId"`
+	Comment   *string         `json:"comment"`
+	Currency  string          `json:"currency"`
+	Date      string          `json:"date"`
+	Fee       decimal.Decimal `json:"fee"`
+	Quantity  decimal.Decimal `json:"quantity"`
+	Symbol    string          `json:"symbol"`
+	Type      string          `json:"type"`
+	UnitPrice decimal.Decimal `json:"unitPrice"`
+}
+
+// GhostfolioParser handles Ghostfolio JSON exports. Each Ghostfolio account becomes an
+// account of the export; activities without one are grouped under "Ghostfolio".
+//
+// BUY and SELL activities map to buys and sells with the fee as commission. DIVIDEND
+// activities record quantity × unit price as the amount received. FEE and INTEREST
+// activities only move cash and are ignored; ITEM and LIABILITY activities track manually
+// valued assets, which aren't supported.
+type GhostfolioParser struct {
+	BaseParser
+}
+
+// NewGhostfolioParser creates a new Ghostfolio export parser
+func NewGhostfolioParser() TrackerParser {
+	return &GhostfolioParser{}
+}
+
+// GetFormat returns the format this parser handles
+func (p *GhostfolioParser) GetFormat() dto.ImportFormat {
+	return dto.ImportFormatGhostfolio
+}
+
+// Parse parses a Ghostfolio export
+func (p *GhostfolioParser) Parse(data io.Reader) (*Export, error) {
+	var export ghostfolioExport
+	if err := json.NewDecoder(data).Decode(&export); err != nil {
+		return nil, fmt.Errorf("not a Ghostfolio export: %w", err)
+	}
+	if export.Activities == nil {
+		return nil, fmt.Errorf("not a Ghostfolio export: no activities")
+	}
+
+	baseCurrency := export.User.Settings.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = export.User.Settings.Currency
+	}
+
+	accounts := newAccountSet()
+	for _, account := range export.Accounts {
+		currency := account.Currency
+		if currency == "" {
+			currency = baseCurrency
+		}
+		accounts.get(account.ID, account.Name, currency)
+	}
+
+	result := &Export{}
+	for i, activity := range export.Activities {
+		line := i + 1
+		rawData := p.describe(activity)
+
+		txType, ignored, err := p.mapType(activity.Type)
+		if err != nil {
+			result.Errors = append(result.Errors, p.CreateImportError(line, "type", err.Error(), rawData))
+			continue
+		}
+		if ignored {
+			result.Ignored++
+			continue
+		}
+
+		account := accounts.get("", ghostfolioDefaultAccount, baseCurrency)
+		if activity.AccountID != nil {
+			account = accounts.get(*activity.AccountID, ghostfolioDefaultAccount, baseCurrency)
+		}
+
+		tx, field, err := p.mapActivity(activity, txType, account.Currency)
+		if err != nil {
+			result.Errors = append(result.Errors, p.CreateImportError(line, field, err.Error(), rawData))
+			continue
+		}
+		tx.RawData = rawData
+
+		if validationErrors := p.ValidateTransaction(&tx, line); len(validationErrors) > 0 {
+			result.Errors = append(result.Errors, validationErrors...)
+			continue
+		}
+		account.Transactions = append(account.Transactions, tx)
+	}
+
+	result.Accounts = accounts.withTransactions()
+	return result, nil
+}
+
+// mapType maps a Ghostfolio activity type. ignored is true for cash-only activities.
+func (p *GhostfolioParser) mapType(activityType string) (txType models.TransactionType, ignored bool, err error) {
+	switch strings.ToUpper(activityType) {
+	case "BUY":
+		return models.TransactionTypeBuy, false, nil
+	case "SELL":
+		return models.TransactionTypeSell, false, nil
+	case "DIVIDEND":
+		return models.TransactionTypeDividend, false, nil
+	case "FEE", "INTEREST":
+		return "", true, nil
+	case "ITEM", "LIABILITY":
+		return "", false, fmt.Errorf("%s activities (manually valued assets) are not supported", activityType)
+	default:
+		return "", false, fmt.Errorf("unknown activity type: %s", activityType)
+	}
+}
+
+// mapActivity maps a securities activity to a transaction, returning the field at fault on error
+func (p *GhostfolioParser) mapActivity(
+	activity ghostfolioActivity,
+	txType models.TransactionType,
+	accountCurrency string,
+) (dto.ImportTransactionRequest, string, error) {
+	date, err := p.ParseTimestamp(activity.Date)
+	if err != nil {
+		return dto.ImportTransactionRequest{}, "date", err
+	}
+
+	symbol := strings.ToUpper(strings.TrimSpace(activity.Symbol))
+	if err := p.ValidateSymbol(symbol); err != nil {
+		return dto.ImportTransactionRequest{}, "symbol", err
+	}
+
+	currency := activity.Currency
+	if currency == "" {
+		currency = accountCurrency
+	}
+
+	tx := dto.ImportTransactionRequest{
+		Type:       txType,
+		Symbol:     symbol,
+		Date:       date,
+		Quantity:   activity.Quantity,
+		Commission: activity.Fee,
+		Currency:   currency,
+	}
+	if activity.Comment != nil {
+		tx.Notes = *activity.Comment
+	}
+
+	if txType == models.TransactionTypeDividend {
+		// Dividend transactions record the total amount received
+		tx.Quantity = activity.Quantity.Mul(activity.UnitPrice)
+	} else {
+		price := activity.UnitPrice
+		tx.Price = &price
+	}
+
+	return tx, "", nil
+}
+
+// describe summarizes an activity for error reports
+func (p *GhostfolioParser) describe(activity ghostfolioActivity) string {
+	return fmt.Sprintf("%s %s %s @ %s on %s",
+		activity.Type, activity.Quantity.String(), activity.Symbol, activity.UnitPrice.String(), activity.Date)
+}
@@ -0,0 +1,99 @@
+package tracker_parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+const ghostfolioExportJSON = `{
+  "meta": {"date": "2024-06-01T10:00:00.000Z", "version": "2.80.0"},
+  "accounts": [
+    {"balance": 0, "comment": null, "currency": "USD", "id": "acc-1", "isExcluded": false, "name": "Brokerage", "platformId": null},
+    {"balance": 0, "comment": null, "currency": "CHF", "id": "acc-2", "isExcluded": false, "name": "Savings", "platformId": null}
+  ],
+  "activities": [
+    {"accountId": "acc-1", "comment": "First buy", "fee": 1.5, "quantity": 10, "type": "BUY", "unitPrice": 180.25, "currency": "USD", "dataSource": "YAHOO", "date": "2024-02-01T00:00:00.000Z", "symbol": "aapl"},
+    {"accountId": "acc-1", "comment": null, "fee": 0, "quantity": 10, "type": "DIVIDEND", "unitPrice": 0.24, "currency": "USD", "dataSource": "YAHOO", "date": "2024-02-15T00:00:00.000Z", "symbol": "AAPL"},
+    {"accountId": "acc-1", "comment": null, "fee": 1, "quantity": 4, "type": "SELL", "unitPrice": 190, "currency": "USD", "dataSource": "YAHOO", "date": "2024-01-10T00:00:00.000Z", "symbol": "MSFT"},
+    {"accountId": "acc-2", "comment": null, "fee": 0, "quantity": 1, "type": "INTEREST", "unitPrice": 12.5, "currency": "CHF", "dataSource": "MANUAL", "date": "2024-03-01T00:00:00.000Z", "symbol": "CASH"},
+    {"accountId": "acc-2", "comment": null, "fee": 0, "quantity": 1, "type": "ITEM", "unitPrice": 500000, "currency": "CHF", "dataSource": "MANUAL", "date": "2024-03-01T00:00:00.000Z", "symbol": "House"},
+    {"accountId": null, "comment": null, "fee": 0, "quantity": 2, "type": "BUY", "unitPrice": 95, "currency": "", "dataSource": "YAHOO", "date": "2024-04-01", "symbol": "VTI"},
+    {"accountId": null, "comment": null, "fee": 0, "quantity": 2, "type": "BUY", "unitPrice": 95, "currency": "USD", "dataSource": "YAHOO", "date": "yesterday", "symbol": "VTI"}
+  ],
+  "user": {"settings": {"baseCurrency": "EUR"}}
+}`
+
+func TestGhostfolioParser_GetFormat(t *testing.T) {
+	parser := NewGhostfolioParser()
+	assert.Equal(t, dto.ImportFormatGhostfolio, parser.GetFormat())
+}
+
+func TestGhostfolioParser_Parse(t *testing.T) {
+	parser := NewGhostfolioParser()
+
+	export, err := parser.Parse(strings.NewReader(ghostfolioExportJSON))
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, export.Ignored)
+
+	require.Len(t, export.Errors, 2)
+	assert.Equal(t, 5, export.Errors[0].Line)
+	assert.Equal(t, "type", export.Errors[0].Field)
+	assert.Contains(t, export.Errors[0].Message, "not supported")
+	assert.Equal(t, 7, export.Errors[1].Line)
+	assert.Equal(t, "date", export.Errors[1].Field)
+
+	// Savings only had cash activities
+	require.Len(t, export.Accounts, 2)
+
+	brokerage := export.Accounts[0]
+	assert.Equal(t, "Brokerage", brokerage.Name)
+	assert.Equal(t, "USD", brokerage.Currency)
+	require.Len(t, brokerage.Transactions, 3)
+
+	// Transactions are in date order
+	sell := brokerage.Transactions[0]
+	assert.Equal(t, models.TransactionTypeSell, sell.Type)
+	assert.Equal(t, "MSFT", sell.Symbol)
+	assert.True(t, decimal.NewFromInt(1).Equal(sell.Commission))
+
+	buy := brokerage.Transactions[1]
+	assert.Equal(t, models.TransactionTypeBuy, buy.Type)
+	assert.Equal(t, "AAPL", buy.Symbol)
+	assert.Equal(t, "2024-02-01", buy.Date.Format("2006-01-02"))
+	assert.True(t, decimal.NewFromInt(10).Equal(buy.Quantity))
+	require.NotNil(t, buy.Price)
+	assert.True(t, decimal.RequireFromString("180.25").Equal(*buy.Price))
+	assert.True(t, decimal.RequireFromString("1.5").Equal(buy.Commission))
+	assert.Equal(t, "First buy", buy.Notes)
+
+	dividend := brokerage.Transactions[2]
+	assert.Equal(t, models.TransactionTypeDividend, dividend.Type)
+	assert.True(t, decimal.RequireFromString("2.4").Equal(dividend.Quantity))
+	assert.Nil(t, dividend.Price)
+
+	// Activities without an account use the base currency
+	unassigned := export.Accounts[1]
+	assert.Equal(t, "Ghostfolio", unassigned.Name)
+	assert.Equal(t, "EUR", unassigned.Currency)
+	require.Len(t, unassigned.Transactions, 1)
+	assert.Equal(t, "EUR", unassigned.Transactions[0].Currency)
+}
+
+func TestGhostfolioParser_Parse_InvalidFile(t *testing.T) {
+	parser := NewGhostfolioParser()
+
+	_, err := parser.Parse(strings.NewReader("Date,Symbol\n"))
+	assert.Error(t, err)
+
+	_, err = parser.Parse(strings.NewReader(`{"accounts": []}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no activities")
+}
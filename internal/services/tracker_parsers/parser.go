@@ -0,0 +1,119 @@
+package tracker_parsers
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/services/csv_parsers"
+)
+
+// maxSymbolLength is the length of the transactions.symbol column
+const maxSymbolLength = 20
+
+// TrackerParser defines the interface that parsers of other portfolio trackers' exports
+// must implement
+type TrackerParser interface {
+	// Parse parses an export into its accounts and their transactions
+	Parse(data io.Reader) (*Export, error)
+
+	// GetFormat returns the format this parser handles
+	GetFormat() dto.ImportFormat
+}
+
+// Export is a tracker export mapped onto this app's transactions
+type Export struct {
+	// Accounts lists the accounts holding securities transactions, in the export's order
+	Accounts []*Account
+	// Ignored counts cash-only activities such as deposits, interest and fees, which aren't
+	// tracked here
+	Ignored int
+	// Errors lists the activities that could not be mapped. Line is the activity's position
+	// in the export.
+	Errors []dto.ImportError
+}
+
+// Account is an account of a tracker export with its securities transactions in date order
+type Account struct {
+	Name         string
+	Currency     string
+	Transactions []dto.ImportTransactionRequest
+}
+
+// BaseParser provides common functionality for tracker parsers
+type BaseParser struct {
+	csv_parsers.BaseParser
+}
+
+// ParseTimestamp parses the date of an activity, ignoring its time of day
+func (p *BaseParser) ParseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	formats := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	}
+	for _, format := range formats {
+		if t, err := time.Parse(format, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", value)
+}
+
+// ValidateSymbol checks that a symbol fits in a transaction
+func (p *BaseParser) ValidateSymbol(symbol string) error {
+	if symbol == "" {
+		return fmt.Errorf("symbol is required")
+	}
+	if len(symbol) > maxSymbolLength {
+		return fmt.Errorf("symbol %s is longer than %d characters", symbol, maxSymbolLength)
+	}
+	return nil
+}
+
+// accountSet collects the accounts of an export in order of first appearance
+type accountSet struct {
+	accounts []*Account
+	byKey    map[string]*Account
+}
+
+// newAccountSet creates an empty account set
+func newAccountSet() *accountSet {
+	return &accountSet{byKey: make(map[string]*Account)}
+}
+
+// get returns the account for key, adding it with the given name and currency if needed
+func (s *accountSet) get(key, name, currency string) *Account {
+	if account, ok := s.byKey[key]; ok {
+		return account
+	}
+	account := &Account{Name: name, Currency: currency}
+	s.byKey[key] = account
+	s.accounts = append(s.accounts, account)
+	return account
+}
+
+// withTransactions returns the accounts that have transactions, each sorted by date
+func (s *accountSet) withTransactions() []*Account {
+	accounts := make([]*Account, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		if len(account.Transactions) == 0 {
+			continue
+		}
+		sortByDate(account.Transactions)
+		accounts = append(accounts, account)
+	}
+	return accounts
+}
+
+// sortByDate orders transactions oldest first, keeping the export's order within a day
+func sortByDate(transactions []dto.ImportTransactionRequest) {
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+}
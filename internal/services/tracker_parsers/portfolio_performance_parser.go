@@ -0,0 +1,299 @@
+package tracker_parsers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// Portfolio Performance stores amounts in hundredths and share counts in millionths
+var (
+	ppAmountFactor = decimal.NewFromInt(100)
+	ppSharesFactor = decimal.NewFromInt(1_000_000)
+)
+
+// PortfolioPerformanceParser handles Portfolio Performance files saved as XML (File > Save
+// as > XML). Each securities account ("portfolio") becomes an account of the export.
+//
+// Buys, sells, inbound and outbound deliveries and transfers between securities accounts
+// map to buys and sells priced at the recorded value, with fees, and taxes on purchases, as
+// commission. Dividends are booked on a cash account and go to the securities account that
+// settles to it; they record the amount before taxes. Every other cash account entry, such
+// as deposits, interest and fees, is ignored.
+type PortfolioPerformanceParser struct {
+	BaseParser
+}
+
+// NewPortfolioPerformanceParser creates a new Portfolio Performance XML parser
+func NewPortfolioPerformanceParser() TrackerParser {
+	return &PortfolioPerformanceParser{}
+}
+
+// GetFormat returns the format this parser handles
+func (p *PortfolioPerformanceParser) GetFormat() dto.ImportFormat {
+	return dto.ImportFormatPortfolioPerformance
+}
+
+// Parse parses a Portfolio Performance XML file
+func (p *PortfolioPerformanceParser) Parse(data io.Reader) (*Export, error) {
+	tree, err := parseXMLTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("not a Portfolio Performance XML file: %w", err)
+	}
+	if tree.root.name != "client" {
+		return nil, fmt.Errorf("not a Portfolio Performance XML file; save it with File > Save as > XML")
+	}
+	baseCurrency := tree.childText(tree.root, "baseCurrency")
+
+	accounts := newAccountSet()
+	portfolios := tree.elements(tree.root, "portfolios")
+	// settlements maps each cash account to the first securities account that settles to it
+	settlements := make(map[*xmlNode]*Account)
+	for _, portfolio := range portfolios {
+		cashAccount := tree.child(portfolio, "referenceAccount")
+		currency := tree.childText(cashAccount, "currencyCode")
+		if currency == "" {
+			currency = baseCurrency
+		}
+
+		account := accounts.get(p.key(tree, portfolio), tree.childText(portfolio, "name"), currency)
+		if cashAccount != nil && settlements[cashAccount] == nil {
+			settlements[cashAccount] = account
+		}
+	}
+
+	result := &Export{}
+	line := 0
+	for _, portfolio := range portfolios {
+		account := accounts.get(p.key(tree, portfolio), "", "")
+		for _, entry := range tree.elements(portfolio, "transactions") {
+			line++
+			rawData := p.describe(tree, entry)
+
+			tx, field, err := p.mapPortfolioTransaction(tree, entry, account.Currency)
+			if err != nil {
+				result.Errors = append(result.Errors, p.CreateImportError(line, field, err.Error(), rawData))
+				continue
+			}
+			tx.RawData = rawData
+			p.add(result, account, tx, line)
+		}
+	}
+
+	for _, cashAccount := range tree.elements(tree.root, "accounts") {
+		for _, entry := range tree.elements(cashAccount, "transactions") {
+			line++
+
+			switch tree.childText(entry, "type") {
+			case "BUY", "SELL":
+				// The cash side of a securities account's buy or sell
+				continue
+			case "DIVIDENDS":
+			default:
+				result.Ignored++
+				continue
+			}
+
+			rawData := p.describe(tree, entry)
+			account := settlements[cashAccount]
+			if account == nil && len(portfolios) == 1 {
+				account = accounts.get(p.key(tree, portfolios[0]), "", "")
+			}
+			if account == nil {
+				result.Errors = append(result.Errors, p.CreateImportError(line, "account",
+					fmt.Sprintf("no securities account settles to %s", tree.childText(cashAccount, "name")), rawData))
+				continue
+			}
+
+			tx, field, err := p.mapDividend(tree, entry, account.Currency)
+			if err != nil {
+				result.Errors = append(result.Errors, p.CreateImportError(line, field, err.Error(), rawData))
+				continue
+			}
+			tx.RawData = rawData
+			p.add(result, account, tx, line)
+		}
+	}
+
+	result.Accounts = accounts.withTransactions()
+	return result, nil
+}
+
+// add appends a mapped transaction to account, or reports why it is invalid
+func (p *PortfolioPerformanceParser) add(result *Export, account *Account, tx dto.ImportTransactionRequest, line int) {
+	if validationErrors := p.ValidateTransaction(&tx, line); len(validationErrors) > 0 {
+		result.Errors = append(result.Errors, validationErrors...)
+		return
+	}
+	account.Transactions = append(account.Transactions, tx)
+}
+
+// mapPortfolioTransaction maps a securities account entry to a buy or sell, returning the
+// field at fault on error
+func (p *PortfolioPerformanceParser) mapPortfolioTransaction(
+	tree *xmlTree,
+	entry *xmlNode,
+	accountCurrency string,
+) (dto.ImportTransactionRequest, string, error) {
+	tx, field, err := p.mapCommon(tree, entry, accountCurrency)
+	if err != nil {
+		return tx, field, err
+	}
+
+	shares, err := p.scaled(tree.childText(entry, "shares"), ppSharesFactor)
+	if err != nil {
+		return tx, "shares", err
+	}
+	if !shares.IsPositive() {
+		return tx, "shares", fmt.Errorf("shares must be greater than zero")
+	}
+	amount, err := p.scaled(tree.childText(entry, "amount"), ppAmountFactor)
+	if err != nil {
+		return tx, "amount", err
+	}
+	fees, taxes := p.units(tree, entry)
+
+	// The amount is what was paid including fees and taxes, or received after them
+	var gross decimal.Decimal
+	entryType := tree.childText(entry, "type")
+	switch entryType {
+	case "BUY", "DELIVERY_INBOUND", "TRANSFER_IN":
+		tx.Type = models.TransactionTypeBuy
+		gross = amount.Sub(fees).Sub(taxes)
+		tx.Commission = fees.Add(taxes)
+	case "SELL", "DELIVERY_OUTBOUND", "TRANSFER_OUT":
+		tx.Type = models.TransactionTypeSell
+		gross = amount.Add(fees).Add(taxes)
+		tx.Commission = fees
+	default:
+		return tx, "type", fmt.Errorf("unknown transaction type: %s", entryType)
+	}
+
+	price := gross.Div(shares).Round(8)
+	tx.Quantity = shares
+	tx.Price = &price
+	if entryType != "BUY" && entryType != "SELL" && tx.Notes == "" {
+		tx.Notes = "Portfolio Performance " + strings.ToLower(strings.ReplaceAll(entryType, "_", " "))
+	}
+
+	return tx, "", nil
+}
+
+// mapDividend maps a dividend booked on a cash account, returning the field at fault on error
+func (p *PortfolioPerformanceParser) mapDividend(
+	tree *xmlTree,
+	entry *xmlNode,
+	accountCurrency string,
+) (dto.ImportTransactionRequest, string, error) {
+	tx, field, err := p.mapCommon(tree, entry, accountCurrency)
+	if err != nil {
+		return tx, field, err
+	}
+
+	amount, err := p.scaled(tree.childText(entry, "amount"), ppAmountFactor)
+	if err != nil {
+		return tx, "amount", err
+	}
+	fees, taxes := p.units(tree, entry)
+
+	// Dividend transactions record the total amount received before taxes
+	tx.Type = models.TransactionTypeDividend
+	tx.Quantity = amount.Add(fees).Add(taxes)
+	tx.Commission = fees
+	if taxes.IsPositive() && tx.Notes == "" {
+		tx.Notes = "Taxes withheld: " + taxes.StringFixed(2)
+	}
+
+	return tx, "", nil
+}
+
+// mapCommon maps the date, security, currency and note shared by every entry
+func (p *PortfolioPerformanceParser) mapCommon(
+	tree *xmlTree,
+	entry *xmlNode,
+	accountCurrency string,
+) (dto.ImportTransactionRequest, string, error) {
+	date, err := p.ParseTimestamp(tree.childText(entry, "date"))
+	if err != nil {
+		return dto.ImportTransactionRequest{}, "date", err
+	}
+
+	symbol := p.symbol(tree, tree.child(entry, "security"))
+	if err := p.ValidateSymbol(symbol); err != nil {
+		return dto.ImportTransactionRequest{}, "security", err
+	}
+
+	currency := tree.childText(entry, "currencyCode")
+	if currency == "" {
+		currency = accountCurrency
+	}
+
+	return dto.ImportTransactionRequest{
+		Symbol:   symbol,
+		Date:     date,
+		Currency: currency,
+		Notes:    tree.childText(entry, "note"),
+	}, "", nil
+}
+
+// symbol returns a security's ticker symbol, or its ISIN or WKN if it has none
+func (p *PortfolioPerformanceParser) symbol(tree *xmlTree, security *xmlNode) string {
+	for _, field := range []string{"tickerSymbol", "isin", "wkn"} {
+		if value := tree.childText(security, field); value != "" {
+			return strings.ToUpper(value)
+		}
+	}
+	return ""
+}
+
+// units returns the fees and taxes itemized on an entry
+func (p *PortfolioPerformanceParser) units(tree *xmlTree, entry *xmlNode) (fees, taxes decimal.Decimal) {
+	for _, unit := range tree.elements(entry, "units") {
+		amountNode := tree.child(unit, "amount")
+		if amountNode == nil {
+			continue
+		}
+		amount, err := p.scaled(amountNode.attrs["amount"], ppAmountFactor)
+		if err != nil {
+			continue
+		}
+		switch unit.attrs["type"] {
+		case "FEE":
+			fees = fees.Add(amount)
+		case "TAX":
+			taxes = taxes.Add(amount)
+		}
+	}
+	return fees, taxes
+}
+
+// scaled parses one of Portfolio Performance's fixed-point integers
+func (p *PortfolioPerformanceParser) scaled(value string, factor decimal.Decimal) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	number, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid number: %s", value)
+	}
+	return number.Div(factor), nil
+}
+
+// key identifies a securities account
+func (p *PortfolioPerformanceParser) key(tree *xmlTree, portfolio *xmlNode) string {
+	if uuid := tree.childText(portfolio, "uuid"); uuid != "" {
+		return uuid
+	}
+	return tree.childText(portfolio, "name")
+}
+
+// describe summarizes an entry for error reports
+func (p *PortfolioPerformanceParser) describe(tree *xmlTree, entry *xmlNode) string {
+	return fmt.Sprintf("%s %s on %s",
+		tree.childText(entry, "type"), p.symbol(tree, tree.child(entry, "security")), tree.childText(entry, "date"))
+}
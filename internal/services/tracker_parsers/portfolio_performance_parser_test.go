@@ -0,0 +1,291 @@
+package tracker_parsers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+)
+
+// ppRelativeReferences is a Portfolio Performance file saved with XStream's default relative
+// references. The first securities account is written out inside the cash account's buy,
+// where it is first encountered, and only referenced from the list of securities accounts.
+const ppRelativeReferences = `<?xml version="1.0" encoding="UTF-8"?>
+<client>
+  <version>56</version>
+  <baseCurrency>EUR</baseCurrency>
+  <securities>
+    <security>
+      <uuid>s1</uuid>
+      <name>Apple Inc.</name>
+      <currencyCode>USD</currencyCode>
+      <isin>US0378331005</isin>
+      <tickerSymbol>aapl</tickerSymbol>
+    </security>
+    <security>
+      <uuid>s2</uuid>
+      <name>iShares Core MSCI World</name>
+      <currencyCode>EUR</currencyCode>
+      <isin>IE00B4L5Y983</isin>
+    </security>
+  </securities>
+  <accounts>
+    <account>
+      <uuid>a1</uuid>
+      <name>Cash</name>
+      <currencyCode>EUR</currencyCode>
+      <transactions>
+        <account-transaction>
+          <date>2024-01-02T00:00</date>
+          <currencyCode>EUR</currencyCode>
+          <amount>500000</amount>
+          <shares>0</shares>
+          <type>DEPOSIT</type>
+        </account-transaction>
+        <account-transaction>
+          <date>2024-01-03T10:00</date>
+          <currencyCode>EUR</currencyCode>
+          <amount>150500</amount>
+          <security reference="../../../../../securities/security"/>
+          <crossEntry class="buysell">
+            <portfolio>
+              <uuid>p1</uuid>
+              <name>Broker</name>
+              <referenceAccount reference="../../../../.."/>
+              <transactions>
+                <portfolio-transaction>
+                  <date>2024-01-03T10:00</date>
+                  <currencyCode>EUR</currencyCode>
+                  <amount>150500</amount>
+                  <security reference="../../../../../../../../../securities/security"/>
+                  <crossEntry class="buysell" reference="../../../.."/>
+                  <shares>1000000000</shares>
+                  <units>
+                    <unit type="FEE">
+                      <amount currency="EUR" amount="500"/>
+                    </unit>
+                  </units>
+                  <type>BUY</type>
+                </portfolio-transaction>
+              </transactions>
+            </portfolio>
+          </crossEntry>
+          <shares>0</shares>
+          <type>BUY</type>
+        </account-transaction>
+        <account-transaction>
+          <date>2024-02-15T00:00</date>
+          <currencyCode>EUR</currencyCode>
+          <amount>2550</amount>
+          <security reference="../../../../../securities/security"/>
+          <shares>0</shares>
+          <units>
+            <unit type="TAX">
+              <amount currency="EUR" amount="450"/>
+            </unit>
+          </units>
+          <type>DIVIDENDS</type>
+        </account-transaction>
+      </transactions>
+    </account>
+  </accounts>
+  <portfolios>
+    <portfolio reference="../../accounts/account/transactions/account-transaction[2]/crossEntry/portfolio"/>
+    <portfolio>
+      <uuid>p2</uuid>
+      <name>Broker</name>
+      <transactions>
+        <portfolio-transaction>
+          <date>2023-06-01T00:00</date>
+          <currencyCode>EUR</currencyCode>
+          <amount>40000</amount>
+          <security reference="../../../../../securities/security[2]"/>
+          <shares>5000000</shares>
+          <type>DELIVERY_INBOUND</type>
+        </portfolio-transaction>
+        <portfolio-transaction>
+          <date>2023-07-01T00:00</date>
+          <currencyCode>EUR</currencyCode>
+          <amount>100</amount>
+          <security reference="../../../../../securities/security[2]"/>
+          <shares>0</shares>
+          <type>SELL</type>
+        </portfolio-transaction>
+      </transactions>
+    </portfolio>
+  </portfolios>
+</client>`
+
+// ppIDReferences is a Portfolio Performance file saved with ID references
+const ppIDReferences = `<client id="1">
+  <baseCurrency>USD</baseCurrency>
+  <securities id="2">
+    <security id="3">
+      <name>Microsoft</name>
+      <tickerSymbol>MSFT</tickerSymbol>
+    </security>
+  </securities>
+  <accounts id="4">
+    <account id="5">
+      <name>Checking</name>
+      <currencyCode>USD</currencyCode>
+      <transactions id="6"/>
+    </account>
+  </accounts>
+  <portfolios id="7">
+    <portfolio id="8">
+      <name>Retirement</name>
+      <referenceAccount reference="5"/>
+      <transactions id="9">
+        <portfolio-transaction id="10">
+          <date>2024-03-01T00:00</date>
+          <amount>101000</amount>
+          <security reference="3"/>
+          <shares>2000000</shares>
+          <units id="11">
+            <unit type="FEE">
+              <amount currency="USD" amount="1000"/>
+            </unit>
+            <unit type="TAX">
+              <amount currency="USD" amount="500"/>
+            </unit>
+          </units>
+          <type>SELL</type>
+        </portfolio-transaction>
+      </transactions>
+    </portfolio>
+  </portfolios>
+</client>`
+
+func TestPortfolioPerformanceParser_GetFormat(t *testing.T) {
+	parser := NewPortfolioPerformanceParser()
+	assert.Equal(t, dto.ImportFormatPortfolioPerformance, parser.GetFormat())
+}
+
+func TestPortfolioPerformanceParser_Parse_RelativeReferences(t *testing.T) {
+	parser := NewPortfolioPerformanceParser()
+
+	export, err := parser.Parse(strings.NewReader(ppRelativeReferences))
+	require.NoError(t, err)
+
+	// The deposit is ignored; the cash side of the buy is part of the securities account's buy
+	assert.Equal(t, 1, export.Ignored)
+
+	require.Len(t, export.Errors, 1)
+	assert.Equal(t, 3, export.Errors[0].Line)
+	assert.Equal(t, "shares", export.Errors[0].Field)
+
+	require.Len(t, export.Accounts, 2)
+
+	broker := export.Accounts[0]
+	assert.Equal(t, "Broker", broker.Name)
+	assert.Equal(t, "EUR", broker.Currency)
+	require.Len(t, broker.Transactions, 2)
+
+	buy := broker.Transactions[0]
+	assert.Equal(t, models.TransactionTypeBuy, buy.Type)
+	assert.Equal(t, "AAPL", buy.Symbol)
+	assert.Equal(t, "2024-01-03", buy.Date.Format("2006-01-02"))
+	assert.True(t, decimal.NewFromInt(1000).Equal(buy.Quantity))
+	require.NotNil(t, buy.Price)
+	assert.True(t, decimal.RequireFromString("1.5").Equal(*buy.Price), buy.Price.String())
+	assert.True(t, decimal.NewFromInt(5).Equal(buy.Commission))
+
+	// Dividends record the amount before the taxes withheld
+	dividend := broker.Transactions[1]
+	assert.Equal(t, models.TransactionTypeDividend, dividend.Type)
+	assert.Equal(t, "AAPL", dividend.Symbol)
+	assert.True(t, decimal.NewFromInt(30).Equal(dividend.Quantity), dividend.Quantity.String())
+	assert.Nil(t, dividend.Price)
+	assert.Equal(t, "Taxes withheld: 4.50", dividend.Notes)
+
+	// Securities accounts without a cash account use the base currency
+	delivered := export.Accounts[1]
+	assert.Equal(t, "Broker", delivered.Name)
+	assert.Equal(t, "EUR", delivered.Currency)
+	require.Len(t, delivered.Transactions, 1)
+	assert.Equal(t, models.TransactionTypeBuy, delivered.Transactions[0].Type)
+	assert.Equal(t, "IE00B4L5Y983", delivered.Transactions[0].Symbol)
+	assert.True(t, decimal.NewFromInt(80).Equal(*delivered.Transactions[0].Price))
+	assert.Equal(t, "Portfolio Performance delivery inbound", delivered.Transactions[0].Notes)
+}
+
+func TestPortfolioPerformanceParser_Parse_IDReferences(t *testing.T) {
+	parser := NewPortfolioPerformanceParser()
+
+	export, err := parser.Parse(strings.NewReader(ppIDReferences))
+	require.NoError(t, err)
+	assert.Empty(t, export.Errors)
+
+	require.Len(t, export.Accounts, 1)
+	retirement := export.Accounts[0]
+	assert.Equal(t, "Retirement", retirement.Name)
+	assert.Equal(t, "USD", retirement.Currency)
+	require.Len(t, retirement.Transactions, 1)
+
+	// Sells are priced before fees and taxes; only the fee is a commission
+	sell := retirement.Transactions[0]
+	assert.Equal(t, models.TransactionTypeSell, sell.Type)
+	assert.Equal(t, "MSFT", sell.Symbol)
+	assert.Equal(t, "USD", sell.Currency)
+	assert.True(t, decimal.NewFromInt(2).Equal(sell.Quantity))
+	assert.True(t, decimal.RequireFromString("512.5").Equal(*sell.Price), sell.Price.String())
+	assert.True(t, decimal.NewFromInt(10).Equal(sell.Commission))
+}
+
+func TestPortfolioPerformanceParser_Parse_UnsettledDividend(t *testing.T) {
+	parser := NewPortfolioPerformanceParser()
+	data := `<client>
+  <baseCurrency>EUR</baseCurrency>
+  <securities><security><tickerSymbol>SAP</tickerSymbol></security></securities>
+  <accounts>
+    <account>
+      <name>Savings</name>
+      <transactions>
+        <account-transaction>
+          <date>2024-05-01T00:00</date>
+          <amount>1000</amount>
+          <security reference="../../../../../securities/security"/>
+          <type>DIVIDENDS</type>
+        </account-transaction>
+      </transactions>
+    </account>
+  </accounts>
+  <portfolios>
+    <portfolio><name>One</name></portfolio>
+    <portfolio><name>Two</name></portfolio>
+  </portfolios>
+</client>`
+
+	export, err := parser.Parse(strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Empty(t, export.Accounts)
+	require.Len(t, export.Errors, 1)
+	assert.Equal(t, "account", export.Errors[0].Field)
+	assert.Contains(t, export.Errors[0].Message, "Savings")
+}
+
+func TestPortfolioPerformanceParser_Parse_InvalidFile(t *testing.T) {
+	parser := NewPortfolioPerformanceParser()
+
+	_, err := parser.Parse(strings.NewReader("not xml <"))
+	assert.Error(t, err)
+
+	// Other XML documents are rejected
+	_, err = parser.Parse(strings.NewReader(`<?xml version="1.0"?><portfolio/>`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Save as > XML")
+}
+
+func TestXMLTree_ReferenceCycle(t *testing.T) {
+	tree, err := parseXMLTree(strings.NewReader(`<a><b reference="../c"/><c reference="../b"/></a>`))
+	require.NoError(t, err)
+
+	assert.Nil(t, tree.child(tree.root, "b"))
+	assert.Nil(t, tree.child(tree.root, "missing"))
+}
@@ -0,0 +1,172 @@
+package tracker_parsers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxReferenceHops bounds how many references are followed to reach an element
+const maxReferenceHops = 16
+
+// xmlNode is an element of an XML document kept in memory, so that the XStream references
+// Portfolio Performance writes can be followed
+type xmlNode struct {
+	name     string
+	attrs    map[string]string
+	text     string
+	parent   *xmlNode
+	children []*xmlNode
+}
+
+// xmlTree is a parsed XML document
+type xmlTree struct {
+	root *xmlNode
+	// ids indexes elements by their id attribute, used by files saved with ID references
+	ids map[string]*xmlNode
+}
+
+// parseXMLTree reads a whole XML document
+func parseXMLTree(data io.Reader) (*xmlTree, error) {
+	decoder := xml.NewDecoder(data)
+	tree := &xmlTree{ids: make(map[string]*xmlNode)}
+
+	var current *xmlNode
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: make(map[string]string, len(t.Attr)), parent: current}
+			for _, attr := range t.Attr {
+				node.attrs[attr.Name.Local] = attr.Value
+			}
+			if id, ok := node.attrs["id"]; ok {
+				tree.ids[id] = node
+			}
+			if current == nil {
+				if tree.root != nil {
+					return nil, fmt.Errorf("invalid XML: more than one root element")
+				}
+				tree.root = node
+			} else {
+				current.children = append(current.children, node)
+			}
+			current = node
+		case xml.EndElement:
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.text += string(t)
+			}
+		}
+	}
+
+	if tree.root == nil {
+		return nil, fmt.Errorf("invalid XML: no root element")
+	}
+	return tree, nil
+}
+
+// resolve follows node's reference attribute, if any, to the element it stands for
+func (t *xmlTree) resolve(node *xmlNode) *xmlNode {
+	for hops := 0; node != nil; hops++ {
+		reference, ok := node.attrs["reference"]
+		if !ok {
+			return node
+		}
+		if hops == maxReferenceHops {
+			return nil
+		}
+
+		if target, ok := t.ids[reference]; ok {
+			node = target
+			continue
+		}
+		node = node.follow(reference)
+	}
+	return nil
+}
+
+// follow resolves an XStream relative XPath reference such as
+// ../../../securities/security[2] from node
+func (n *xmlNode) follow(path string) *xmlNode {
+	node := n
+	for _, step := range strings.Split(path, "/") {
+		if node == nil {
+			return nil
+		}
+		switch step {
+		case "", ".":
+			continue
+		case "..":
+			node = node.parent
+			continue
+		}
+
+		name, position := step, 1
+		if open := strings.IndexByte(step, '['); open >= 0 && strings.HasSuffix(step, "]") {
+			index, err := strconv.Atoi(step[open+1 : len(step)-1])
+			if err != nil || index < 1 {
+				return nil
+			}
+			name, position = step[:open], index
+		}
+		node = node.nthChild(name, position)
+	}
+	return node
+}
+
+// nthChild returns the position-th (1-based) child element named name
+func (n *xmlNode) nthChild(name string, position int) *xmlNode {
+	for _, child := range n.children {
+		if child.name != name {
+			continue
+		}
+		position--
+		if position == 0 {
+			return child
+		}
+	}
+	return nil
+}
+
+// child returns the first child element named name, following its reference
+func (t *xmlTree) child(node *xmlNode, name string) *xmlNode {
+	if node == nil {
+		return nil
+	}
+	return t.resolve(node.nthChild(name, 1))
+}
+
+// childText returns the trimmed text of the first child element named name
+func (t *xmlTree) childText(node *xmlNode, name string) string {
+	if child := t.child(node, name); child != nil {
+		return strings.TrimSpace(child.text)
+	}
+	return ""
+}
+
+// elements returns the child elements of the element named name under node, following
+// references
+func (t *xmlTree) elements(node *xmlNode, name string) []*xmlNode {
+	list := t.child(node, name)
+	if list == nil {
+		return nil
+	}
+	elements := make([]*xmlNode, 0, len(list.children))
+	for _, child := range list.children {
+		if resolved := t.resolve(child); resolved != nil {
+			elements = append(elements, resolved)
+		}
+	}
+	return elements
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+// suspendedAfterTradingDays is how many weekdays a symbol's latest session may lag behind
+// today before the symbol counts as suspended. It leaves room for a market holiday and
+// for quotes taken before the open, which still report the previous session.
+const suspendedAfterTradingDays = 3
+
+// suspendedAfterContinuousDays is how many calendar days a price from a market that trades
+// around the clock, such as crypto, may lag behind now before the asset counts as suspended
+const suspendedAfterContinuousDays = 3
+
+// TradingRestrictionLookup reports symbols known to be halted or suspended
+type TradingRestrictionLookup interface {
+	// GetTradingRestrictions returns the cached status of each of symbols that is halted or
+	// suspended, keyed by symbol. It never calls the provider.
+	GetTradingRestrictions(ctx context.Context, symbols []string) map[string]*dto.SymbolTradingStatus
+}
+
+// DetectTradingStatus classifies a symbol from the latest session a provider reports for it
+// and that session's volume. A session several trading days old means the symbol is
+// suspended, and a current session without trades means it is halted. It returns "" when
+// the provider doesn't report a session.
+func DetectTradingStatus(latestTradingDay time.Time, volume int64, now time.Time) dto.TradingStatus {
+	if latestTradingDay.IsZero() {
+		return ""
+	}
+
+	if weekdaysAfter(latestTradingDay, now) >= suspendedAfterTradingDays {
+		return dto.TradingStatusSuspended
+	}
+	if volume == 0 {
+		return dto.TradingStatusHalted
+	}
+	return dto.TradingStatusActive
+}
+
+// DetectContinuousTradingStatus classifies an asset that trades every day of the week, such
+// as a coin pair, from when its price last changed. There are no weekends or sessions to
+// allow for, so a price several calendar days old means trading has stopped.
+func DetectContinuousTradingStatus(lastUpdated, now time.Time) dto.TradingStatus {
+	if lastUpdated.IsZero() {
+		return ""
+	}
+
+	if now.Sub(lastUpdated) >= suspendedAfterContinuousDays*24*time.Hour {
+		return dto.TradingStatusSuspended
+	}
+	return dto.TradingStatusActive
+}
+
+// weekdaysAfter counts the weekdays after day's date up to and including now's date
+func weekdaysAfter(day, now time.Time) int {
+	date := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	count := 0
+	for date = date.AddDate(0, 0, 1); !date.After(today); date = date.AddDate(0, 0, 1) {
+		if date.Weekday() != time.Saturday && date.Weekday() != time.Sunday {
+			count++
+		}
+	}
+	return count
+}
+
+// restrictedSymbols returns the symbols among symbols that restrictions reports as halted
+// or suspended, in their original order. A nil lookup reports none.
+func restrictedSymbols(ctx context.Context, restrictions TradingRestrictionLookup, symbols []string) []string {
+	if restrictions == nil || len(symbols) == 0 {
+		return nil
+	}
+
+	found := restrictions.GetTradingRestrictions(ctx, symbols)
+	var restricted []string
+	for _, symbol := range symbols {
+		if found[symbol] != nil {
+			restricted = append(restricted, symbol)
+		}
+	}
+	return restricted
+}
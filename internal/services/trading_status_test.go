@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lenon/portfolios/internal/dto"
+)
+
+func TestDetectTradingStatus(t *testing.T) {
+	// Wednesday 5 June 2024
+	now := time.Date(2024, 6, 5, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		latestTradingDay time.Time
+		volume           int64
+		expected         dto.TradingStatus
+	}{
+		{"current session with trades", time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), 1000, dto.TradingStatusActive},
+		{"previous session before the open", time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC), 1000, dto.TradingStatusActive},
+		{"current session without trades", time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), 0, dto.TradingStatusHalted},
+		{"session lagging a holiday", time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), 1000, dto.TradingStatusActive},
+		{"no session for three trading days", time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC), 1000, dto.TradingStatusSuspended},
+		{"session not reported", time.Time{}, 1000, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DetectTradingStatus(tt.latestTradingDay, tt.volume, now))
+		})
+	}
+}
+
+func TestDetectTradingStatus_Weekend(t *testing.T) {
+	// Monday morning still reports Friday's session
+	monday := time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC)
+	friday := time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, dto.TradingStatusActive, DetectTradingStatus(friday, 1000, monday))
+}
+
+func TestDetectContinuousTradingStatus(t *testing.T) {
+	// Sunday 9 June 2024: coins trade on weekends, so only calendar days count
+	now := time.Date(2024, 6, 9, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, dto.TradingStatusActive, DetectContinuousTradingStatus(now.Add(-5*time.Minute), now))
+	assert.Equal(t, dto.TradingStatusActive, DetectContinuousTradingStatus(now.AddDate(0, 0, -2), now))
+	assert.Equal(t, dto.TradingStatusSuspended, DetectContinuousTradingStatus(now.AddDate(0, 0, -3), now))
+	assert.Equal(t, dto.TradingStatus(""), DetectContinuousTradingStatus(time.Time{}, now))
+
+	// Friday's price is stale on Monday for a coin, unlike for an equity
+	friday := time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC)
+	monday := time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, dto.TradingStatusSuspended, DetectContinuousTradingStatus(friday, monday))
+}
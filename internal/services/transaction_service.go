@@ -1,24 +1,33 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 
+	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/repository"
 )
 
+// RunningPosition is an alias of the dto type for backward compatibility
+type RunningPosition = dto.RunningPosition
+
 // TransactionService defines the interface for transaction operations
 type TransactionService interface {
-	Create(portfolioID, userID string, transactionType models.TransactionType, symbol string, date time.Time, quantity, price decimal.Decimal, commission decimal.Decimal, currency, notes string) (*models.Transaction, error)
-	GetByID(id, userID string) (*models.Transaction, error)
-	GetByPortfolioID(portfolioID, userID string) ([]*models.Transaction, error)
-	GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) ([]*models.Transaction, error)
-	Update(id, userID string, transactionType models.TransactionType, symbol string, date time.Time, quantity, price decimal.Decimal, commission decimal.Decimal, currency, notes string) (*models.Transaction, error)
-	Delete(id, userID string) error
+	Create(ctx context.Context, portfolioID, userID string, transactionType models.TransactionType, symbol string, date time.Time, quantity, price decimal.Decimal, commission decimal.Decimal, currency, notes, category string) (*models.Transaction, error)
+	GetByID(ctx context.Context, id, userID string) (*models.Transaction, error)
+	GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.Transaction, error)
+	GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) ([]*models.Transaction, error)
+	GetSymbolLedger(ctx context.Context, portfolioID, symbol, userID string) ([]*models.Transaction, map[uuid.UUID]*RunningPosition, error)
+	Update(ctx context.Context, id, userID string, version int, transactionType models.TransactionType, symbol string, date time.Time, quantity, price decimal.Decimal, commission decimal.Decimal, currency, notes, category string) (*models.Transaction, error)
+	Delete(ctx context.Context, id, userID string) error
 }
 
 // transactionService implements TransactionService interface
@@ -26,6 +35,7 @@ type transactionService struct {
 	transactionRepo repository.TransactionRepository
 	portfolioRepo   repository.PortfolioRepository
 	holdingRepo     repository.HoldingRepository
+	securityService SecurityService
 }
 
 // NewTransactionService creates a new TransactionService instance
@@ -33,19 +43,32 @@ func NewTransactionService(
 	transactionRepo repository.TransactionRepository,
 	portfolioRepo repository.PortfolioRepository,
 	holdingRepo repository.HoldingRepository,
+) TransactionService {
+	return NewTransactionServiceWithSymbolValidation(transactionRepo, portfolioRepo, holdingRepo, nil)
+}
+
+// NewTransactionServiceWithSymbolValidation creates a new TransactionService instance that
+// refuses to create equity transactions for symbols securityService can't find, suggesting
+// the closest listed symbols instead. securityService may be nil to accept any symbol.
+func NewTransactionServiceWithSymbolValidation(
+	transactionRepo repository.TransactionRepository,
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	securityService SecurityService,
 ) TransactionService {
 	return &transactionService{
 		transactionRepo: transactionRepo,
 		portfolioRepo:   portfolioRepo,
 		holdingRepo:     holdingRepo,
+		securityService: securityService,
 	}
 }
 
 // recalculateHoldingsForSymbol recalculates holdings for a specific symbol in a portfolio
 // This is used after update/delete operations to ensure holdings are accurate
-func (s *transactionService) recalculateHoldingsForSymbol(portfolioID, symbol string) error {
+func (s *transactionService) recalculateHoldingsForSymbol(ctx context.Context, portfolioID, symbol string) error {
 	// Get all transactions for this symbol, ordered by date
-	transactions, err := s.transactionRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+	transactions, err := s.transactionRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
 	if err != nil {
 		return fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -53,14 +76,21 @@ func (s *transactionService) recalculateHoldingsForSymbol(portfolioID, symbol st
 	// Calculate new holdings based on all transactions
 	var quantity decimal.Decimal
 	var costBasis decimal.Decimal
+	multiplier := 1
 
 	for _, tx := range transactions {
 		switch tx.Type {
-		case models.TransactionTypeBuy:
+		case models.TransactionTypeBuy, models.TransactionTypeRSUVest,
+			models.TransactionTypeESPPPurchase, models.TransactionTypeOptionExercise,
+			models.TransactionTypeBuyToOpen, models.TransactionTypeOpeningBalance:
 			totalCost := tx.GetTotalCost()
 			quantity = quantity.Add(tx.Quantity)
 			costBasis = costBasis.Add(totalCost)
-		case models.TransactionTypeSell:
+			if tx.Multiplier > 1 {
+				multiplier = tx.Multiplier
+			}
+		case models.TransactionTypeSell, models.TransactionTypeSellToClose,
+			models.TransactionTypeOptionExpiration, models.TransactionTypeOptionAssignment:
 			if quantity.IsZero() {
 				return models.ErrInsufficientShares
 			}
@@ -70,15 +100,18 @@ func (s *transactionService) recalculateHoldingsForSymbol(portfolioID, symbol st
 			costBasisForSale := avgCostPrice.Mul(tx.Quantity)
 			quantity = quantity.Sub(tx.Quantity)
 			costBasis = costBasis.Sub(costBasisForSale)
+		case models.TransactionTypeReturnOfCapital:
+			// Returns of capital lower cost basis, which can't go below zero
+			costBasis = decimal.Max(costBasis.Sub(tx.Quantity), decimal.Zero)
 		}
 	}
 
 	// Update or delete holding based on final quantity
 	if quantity.IsZero() {
 		// Delete holding if quantity is zero
-		holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+		holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
 		if err == nil {
-			return s.holdingRepo.Delete(holding.ID.String())
+			return s.holdingRepo.Delete(ctx, holding.ID.String())
 		}
 		if err == models.ErrHoldingNotFound {
 			return nil // Already deleted, that's fine
@@ -87,12 +120,12 @@ func (s *transactionService) recalculateHoldingsForSymbol(portfolioID, symbol st
 	}
 
 	// Update holding with recalculated values
-	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
 	if err == models.ErrHoldingNotFound {
 		// Create new holding
 		pid, err := uuid.Parse(portfolioID)
 		if err != nil {
-			return fmt.Errorf("invalid portfolio ID: %w", err)
+			return fmt.Errorf("%w: %w", models.ErrInvalidPortfolioID, err)
 		}
 		newHolding := &models.Holding{
 			PortfolioID:  pid,
@@ -100,8 +133,9 @@ func (s *transactionService) recalculateHoldingsForSymbol(portfolioID, symbol st
 			Quantity:     quantity,
 			CostBasis:    costBasis,
 			AvgCostPrice: costBasis.Div(quantity),
+			Multiplier:   multiplier,
 		}
-		return s.holdingRepo.Create(newHolding)
+		return s.holdingRepo.Create(ctx, newHolding)
 	}
 	if err != nil {
 		return err
@@ -111,36 +145,46 @@ func (s *transactionService) recalculateHoldingsForSymbol(portfolioID, symbol st
 	holding.Quantity = quantity
 	holding.CostBasis = costBasis
 	holding.AvgCostPrice = costBasis.Div(quantity)
-	return s.holdingRepo.Update(holding)
+	holding.Multiplier = multiplier
+	return s.holdingRepo.Update(ctx, holding)
 }
 
-// Create creates a new transaction
+// Create creates a new transaction. The category only applies to income transactions.
 func (s *transactionService) Create(
+	ctx context.Context,
 	portfolioID, userID string,
 	transactionType models.TransactionType,
 	symbol string,
 	date time.Time,
 	quantity, price, commission decimal.Decimal,
-	currency, notes string,
+	currency, notes, category string,
 ) (*models.Transaction, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
 	// Parse portfolio ID
 	pid, err := uuid.Parse(portfolioID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid portfolio ID: %w", err)
+		return nil, fmt.Errorf("%w: %w", models.ErrInvalidPortfolioID, err)
 	}
 
-	// Set defaults
+	// Set defaults. Coin pairs are priced in their quote currency, whatever the portfolio's,
+	// and cash flows are in the currency they moved.
+	symbol = models.NormalizeTicker(symbol)
+	assetType := models.AssetTypeForSymbol(symbol)
 	if currency == "" {
 		currency = portfolio.BaseCurrency
+		if _, quoteCurrency, ok := models.CryptoPair(symbol); ok {
+			currency = quoteCurrency
+		} else if slices.Contains(models.CashFlowTransactionTypes, transactionType) {
+			currency = symbol
+		}
 	}
 
 	// Create transaction
@@ -153,21 +197,30 @@ func (s *transactionService) Create(
 		PortfolioID: pid,
 		Type:        transactionType,
 		Symbol:      symbol,
+		AssetType:   assetType,
 		Date:        date,
 		Quantity:    quantity,
 		Price:       pricePtr,
 		Commission:  commission,
 		Currency:    currency,
 		Notes:       notes,
+		Category:    strings.TrimSpace(category),
 	}
 
 	// Validate transaction
 	if err := transaction.Validate(); err != nil {
 		return nil, err
 	}
+	if err := s.validateSymbol(ctx, transaction); err != nil {
+		return nil, err
+	}
+
+	// Holdings are kept in step by separate writes, so once the first one starts the rest
+	// (including the rollback below) must run even if the caller gives up
+	ctx = context.WithoutCancel(ctx)
 
 	// Save to database
-	if err := s.transactionRepo.Create(transaction); err != nil {
+	if err := s.transactionRepo.Create(ctx, transaction); err != nil {
 		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
 
@@ -175,9 +228,9 @@ func (s *transactionService) Create(
 	// Note: In a production system, this should be wrapped in a database transaction
 	// to ensure atomicity. For now, we handle errors by attempting to delete the transaction
 	// if holdings update fails.
-	if err := s.updateHoldings(transaction, portfolio); err != nil {
+	if err := s.updateHoldings(ctx, transaction, portfolio); err != nil {
 		// Attempt to rollback by deleting the transaction
-		if deleteErr := s.transactionRepo.Delete(transaction.ID.String()); deleteErr != nil {
+		if deleteErr := s.transactionRepo.Delete(ctx, transaction.ID.String()); deleteErr != nil {
 			return nil, fmt.Errorf("failed to update holdings and rollback transaction: holdings error=%w, rollback error=%v", err, deleteErr)
 		}
 		return nil, fmt.Errorf("failed to update holdings (transaction rolled back): %w", err)
@@ -186,19 +239,51 @@ func (s *transactionService) Create(
 	return transaction, nil
 }
 
+// validateSymbol returns an InvalidSymbolError if the security service can't find the
+// symbol of an equity transaction. Symbols can't be checked while the provider is
+// unreachable, so they are accepted rather than blocking every trade. Cash flows are
+// recorded under a currency rather than a security.
+func (s *transactionService) validateSymbol(ctx context.Context, transaction *models.Transaction) error {
+	if s.securityService == nil || transaction.AssetType != models.AssetTypeEquity || transaction.IsCashFlow() {
+		return nil
+	}
+
+	_, err := s.securityService.Lookup(ctx, transaction.Symbol)
+	if err == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !errors.Is(err, models.ErrSecurityNotFound) {
+		return nil
+	}
+
+	invalid := &models.InvalidSymbolError{Symbol: transaction.Symbol}
+	securities, err := s.securityService.Search(ctx, transaction.Symbol, models.MaxSymbolSuggestions)
+	if err != nil {
+		// The symbol is still unknown, just without suggestions
+		return invalid
+	}
+	for _, security := range securities {
+		invalid.Suggestions = append(invalid.Suggestions, security.Symbol)
+	}
+	return invalid
+}
+
 // GetByID retrieves a transaction by ID, ensuring it belongs to the user
-func (s *transactionService) GetByID(id, userID string) (*models.Transaction, error) {
-	transaction, err := s.transactionRepo.FindByID(id)
+func (s *transactionService) GetByID(ctx context.Context, id, userID string) (*models.Transaction, error) {
+	transaction, err := s.transactionRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Verify the portfolio belongs to the user
-	portfolio, err := s.portfolioRepo.FindByID(transaction.PortfolioID.String())
+	portfolio, err := s.portfolioRepo.FindByID(ctx, transaction.PortfolioID.String())
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
@@ -206,17 +291,17 @@ func (s *transactionService) GetByID(id, userID string) (*models.Transaction, er
 }
 
 // GetByPortfolioID retrieves all transactions for a portfolio
-func (s *transactionService) GetByPortfolioID(portfolioID, userID string) ([]*models.Transaction, error) {
+func (s *transactionService) GetByPortfolioID(ctx context.Context, portfolioID, userID string) ([]*models.Transaction, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	transactions, err := s.transactionRepo.FindByPortfolioID(portfolioID)
+	transactions, err := s.transactionRepo.FindByPortfolioID(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
 	}
@@ -225,17 +310,17 @@ func (s *transactionService) GetByPortfolioID(portfolioID, userID string) ([]*mo
 }
 
 // GetByPortfolioIDAndSymbol retrieves all transactions for a portfolio and symbol
-func (s *transactionService) GetByPortfolioIDAndSymbol(portfolioID, symbol, userID string) ([]*models.Transaction, error) {
+func (s *transactionService) GetByPortfolioIDAndSymbol(ctx context.Context, portfolioID, symbol, userID string) ([]*models.Transaction, error) {
 	// Verify portfolio exists and belongs to user
-	portfolio, err := s.portfolioRepo.FindByID(portfolioID)
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
 	if err != nil {
 		return nil, models.ErrPortfolioNotFound
 	}
-	if portfolio.UserID.String() != userID {
+	if !portfolio.AccessibleBy(ctx, userID) {
 		return nil, models.ErrUnauthorizedAccess
 	}
 
-	transactions, err := s.transactionRepo.FindByPortfolioIDAndSymbol(portfolioID, symbol)
+	transactions, err := s.transactionRepo.FindByPortfolioIDAndSymbol(ctx, portfolioID, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve transactions: %w", err)
 	}
@@ -243,24 +328,74 @@ func (s *transactionService) GetByPortfolioIDAndSymbol(portfolioID, symbol, user
 	return transactions, nil
 }
 
-// Update updates a transaction
+// GetSymbolLedger retrieves a symbol's transactions oldest first, like a brokerage ledger,
+// with the symbol's position right after each one. Positions come from replaying the whole
+// portfolio, so splits, mergers and spinoffs carry over the way recalculation rebuilds them.
+func (s *transactionService) GetSymbolLedger(ctx context.Context, portfolioID, symbol, userID string) ([]*models.Transaction, map[uuid.UUID]*RunningPosition, error) {
+	// Verify portfolio exists and belongs to user
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, nil, models.ErrUnauthorizedAccess
+	}
+
+	transactions, err := s.transactionRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve transactions: %w", err)
+	}
+	sortTransactionsForReplay(transactions)
+
+	replay := newLedgerReplay(portfolio, models.DefaultRoundingPolicy())
+	var ledger []*models.Transaction
+	positions := make(map[uuid.UUID]*RunningPosition)
+	for _, transaction := range transactions {
+		if err := replay.apply(transaction); err != nil {
+			return nil, nil, err
+		}
+		if transaction.Symbol != symbol {
+			continue
+		}
+
+		// A sale of the whole position removes the holding from the replay
+		position := &RunningPosition{Quantity: decimal.Zero, CostBasis: decimal.Zero}
+		if holding, ok := replay.holdings[symbol]; ok {
+			position.Quantity = holding.Quantity
+			position.CostBasis = holding.CostBasis
+		}
+		ledger = append(ledger, transaction)
+		positions[transaction.ID] = position
+	}
+
+	return ledger, positions, nil
+}
+
+// Update updates a transaction. The version is the one the caller read the transaction at;
+// if the transaction has changed since, a VersionConflictError is returned.
 func (s *transactionService) Update(
+	ctx context.Context,
 	id, userID string,
+	version int,
 	transactionType models.TransactionType,
 	symbol string,
 	date time.Time,
 	quantity, price, commission decimal.Decimal,
-	currency, notes string,
+	currency, notes, category string,
 ) (*models.Transaction, error) {
 	// Get existing transaction and verify ownership
-	transaction, err := s.GetByID(id, userID)
+	transaction, err := s.GetByID(ctx, id, userID)
 	if err != nil {
 		return nil, err
 	}
+	if transaction.Version != version {
+		return nil, &models.VersionConflictError{CurrentVersion: transaction.Version}
+	}
 
 	// Update fields
 	transaction.Type = transactionType
 	transaction.Symbol = symbol
+	transaction.AssetType = models.AssetTypeForSymbol(symbol)
 	transaction.Date = date
 	transaction.Quantity = quantity
 	if !price.IsZero() {
@@ -268,20 +403,27 @@ func (s *transactionService) Update(
 	}
 	transaction.Commission = commission
 	transaction.Currency = currency
+	if _, quoteCurrency, ok := models.CryptoPair(symbol); ok && currency == "" {
+		transaction.Currency = quoteCurrency
+	}
 	transaction.Notes = notes
+	transaction.Category = strings.TrimSpace(category)
 
 	// Validate updated transaction
 	if err := transaction.Validate(); err != nil {
 		return nil, err
 	}
 
+	// Don't leave holdings stale if the caller gives up between the two writes
+	ctx = context.WithoutCancel(ctx)
+
 	// Save changes
-	if err := s.transactionRepo.Update(transaction); err != nil {
+	if err := s.transactionRepo.Update(ctx, transaction); err != nil {
 		return nil, fmt.Errorf("failed to update transaction: %w", err)
 	}
 
 	// Recalculate holdings for affected symbol
-	if err := s.recalculateHoldingsForSymbol(transaction.PortfolioID.String(), transaction.Symbol); err != nil {
+	if err := s.recalculateHoldingsForSymbol(ctx, transaction.PortfolioID.String(), transaction.Symbol); err != nil {
 		return nil, fmt.Errorf("failed to recalculate holdings: %w", err)
 	}
 
@@ -289,9 +431,9 @@ func (s *transactionService) Update(
 }
 
 // Delete deletes a transaction, ensuring it belongs to the user
-func (s *transactionService) Delete(id, userID string) error {
+func (s *transactionService) Delete(ctx context.Context, id, userID string) error {
 	// Get transaction and verify ownership
-	transaction, err := s.GetByID(id, userID)
+	transaction, err := s.GetByID(ctx, id, userID)
 	if err != nil {
 		return err
 	}
@@ -300,13 +442,16 @@ func (s *transactionService) Delete(id, userID string) error {
 	symbol := transaction.Symbol
 	portfolioID := transaction.PortfolioID.String()
 
+	// Don't leave holdings stale if the caller gives up between the two writes
+	ctx = context.WithoutCancel(ctx)
+
 	// Delete the transaction
-	if err := s.transactionRepo.Delete(id); err != nil {
+	if err := s.transactionRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete transaction: %w", err)
 	}
 
 	// Recalculate holdings for affected symbol
-	if err := s.recalculateHoldingsForSymbol(portfolioID, symbol); err != nil {
+	if err := s.recalculateHoldingsForSymbol(ctx, portfolioID, symbol); err != nil {
 		return fmt.Errorf("failed to recalculate holdings: %w", err)
 	}
 
@@ -314,14 +459,14 @@ func (s *transactionService) Delete(id, userID string) error {
 }
 
 // updateHoldings updates the holdings table based on a transaction
-func (s *transactionService) updateHoldings(transaction *models.Transaction, portfolio *models.Portfolio) error {
+func (s *transactionService) updateHoldings(ctx context.Context, transaction *models.Transaction, portfolio *models.Portfolio) error {
 	// Only update holdings for BUY and SELL transactions
 	if transaction.Type != models.TransactionTypeBuy && transaction.Type != models.TransactionTypeSell {
 		return nil
 	}
 
 	// Get current holding for this symbol
-	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(
+	holding, err := s.holdingRepo.FindByPortfolioIDAndSymbol(ctx,
 		transaction.PortfolioID.String(),
 		transaction.Symbol,
 	)
@@ -337,7 +482,7 @@ func (s *transactionService) updateHoldings(transaction *models.Transaction, por
 				CostBasis:    totalCost,
 				AvgCostPrice: totalCost.Div(transaction.Quantity),
 			}
-			return s.holdingRepo.Create(newHolding)
+			return s.holdingRepo.Create(ctx, newHolding)
 		}
 		return models.ErrInsufficientShares
 	}
@@ -361,8 +506,8 @@ func (s *transactionService) updateHoldings(transaction *models.Transaction, por
 
 	// If quantity is zero, delete the holding
 	if holding.Quantity.IsZero() {
-		return s.holdingRepo.Delete(holding.ID.String())
+		return s.holdingRepo.Delete(ctx, holding.ID.String())
 	}
 
-	return s.holdingRepo.Update(holding)
+	return s.holdingRepo.Update(ctx, holding)
 }
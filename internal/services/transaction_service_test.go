@@ -1,12 +1,15 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
@@ -26,6 +29,8 @@ func setupTransactionTestDB(t *testing.T) *gorm.DB {
 }
 
 func createTestUserAndPortfolio(t *testing.T, db *gorm.DB) (*models.User, *models.Portfolio) {
+	ctx := context.Background()
+
 	userRepo := repository.NewUserRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
 
@@ -45,13 +50,15 @@ func createTestUserAndPortfolio(t *testing.T, db *gorm.DB) (*models.User, *model
 		BaseCurrency:    "USD",
 		CostBasisMethod: models.CostBasisFIFO,
 	}
-	err = portfolioRepo.Create(portfolio)
+	err = portfolioRepo.Create(ctx, portfolio)
 	assert.NoError(t, err)
 
 	return user, portfolio
 }
 
 func TestTransactionService_CreateBuy(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTransactionTestDB(t)
 	transactionRepo := repository.NewTransactionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
@@ -61,7 +68,7 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 	user, portfolio := createTestUserAndPortfolio(t, db)
 
 	t.Run("successful buy transaction", func(t *testing.T) {
-		transaction, err := service.Create(
+		transaction, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -72,6 +79,7 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 			decimal.NewFromFloat(1.00),
 			"USD",
 			"Initial purchase",
+			"",
 		)
 
 		assert.NoError(t, err)
@@ -81,7 +89,7 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 		assert.Equal(t, decimal.NewFromInt(10), transaction.Quantity)
 
 		// Verify holding was created
-		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 		assert.NoError(t, err)
 		assert.Equal(t, decimal.NewFromInt(10), holding.Quantity)
 		// Total cost = 10 shares * $150.50 + $1.00 commission = $1506.00
@@ -90,7 +98,7 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 	})
 
 	t.Run("second buy transaction updates holding", func(t *testing.T) {
-		transaction, err := service.Create(
+		transaction, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -101,13 +109,14 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 			decimal.NewFromFloat(1.00),
 			"USD",
 			"Additional purchase",
+			"",
 		)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, transaction)
 
 		// Verify holding was updated
-		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 		assert.NoError(t, err)
 		assert.Equal(t, decimal.NewFromInt(15), holding.Quantity) // 10 + 5
 
@@ -118,7 +127,7 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 
 	t.Run("unauthorized access", func(t *testing.T) {
 		otherUserID := uuid.New().String()
-		_, err := service.Create(
+		_, err := service.Create(ctx,
 			portfolio.ID.String(),
 			otherUserID,
 			models.TransactionTypeBuy,
@@ -129,6 +138,7 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 
 		assert.Error(t, err)
@@ -136,7 +146,7 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 	})
 
 	t.Run("invalid quantity", func(t *testing.T) {
-		_, err := service.Create(
+		_, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -147,6 +157,7 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 
 		assert.Error(t, err)
@@ -154,7 +165,82 @@ func TestTransactionService_CreateBuy(t *testing.T) {
 	})
 }
 
+func TestTransactionService_CreateCrypto(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTransactionTestDB(t)
+	transactionRepo := repository.NewTransactionRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	service := NewTransactionService(transactionRepo, portfolioRepo, holdingRepo)
+
+	user, portfolio := createTestUserAndPortfolio(t, db)
+
+	t.Run("coin pair defaults to its quote currency", func(t *testing.T) {
+		transaction, err := service.Create(ctx,
+			portfolio.ID.String(),
+			user.ID.String(),
+			models.TransactionTypeBuy,
+			"BTC-EUR",
+			time.Now(),
+			decimal.RequireFromString("0.12345678"),
+			decimal.NewFromInt(40000),
+			decimal.Zero,
+			"",
+			"",
+			"",
+		)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.AssetTypeCrypto, transaction.AssetType)
+		assert.Equal(t, "EUR", transaction.Currency)
+
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "BTC-EUR")
+		assert.NoError(t, err)
+		assert.Equal(t, models.AssetTypeCrypto, holding.AssetType)
+		assert.True(t, holding.Quantity.Equal(decimal.RequireFromString("0.12345678")))
+	})
+
+	t.Run("rejects a currency other than the quote currency", func(t *testing.T) {
+		_, err := service.Create(ctx,
+			portfolio.ID.String(),
+			user.ID.String(),
+			models.TransactionTypeBuy,
+			"ETH-USD",
+			time.Now(),
+			decimal.NewFromInt(1),
+			decimal.NewFromInt(2500),
+			decimal.Zero,
+			"EUR",
+			"",
+			"",
+		)
+
+		assert.ErrorIs(t, err, models.ErrInvalidCryptoCurrency)
+	})
+
+	t.Run("rejects more than 8 decimal places", func(t *testing.T) {
+		_, err := service.Create(ctx,
+			portfolio.ID.String(),
+			user.ID.String(),
+			models.TransactionTypeBuy,
+			"ETH-USD",
+			time.Now(),
+			decimal.RequireFromString("0.000000001"),
+			decimal.NewFromInt(2500),
+			decimal.Zero,
+			"USD",
+			"",
+			"",
+		)
+
+		assert.ErrorIs(t, err, models.ErrInvalidCryptoQuantity)
+	})
+}
+
 func TestTransactionService_CreateSell(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTransactionTestDB(t)
 	transactionRepo := repository.NewTransactionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
@@ -164,7 +250,7 @@ func TestTransactionService_CreateSell(t *testing.T) {
 	user, portfolio := createTestUserAndPortfolio(t, db)
 
 	// First, buy some shares
-	_, err := service.Create(
+	_, err := service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -175,11 +261,12 @@ func TestTransactionService_CreateSell(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"Initial purchase",
+		"",
 	)
 	assert.NoError(t, err)
 
 	t.Run("successful sell transaction", func(t *testing.T) {
-		transaction, err := service.Create(
+		transaction, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeSell,
@@ -190,6 +277,7 @@ func TestTransactionService_CreateSell(t *testing.T) {
 			decimal.NewFromFloat(1.00),
 			"USD",
 			"Partial sale",
+			"",
 		)
 
 		assert.NoError(t, err)
@@ -197,13 +285,13 @@ func TestTransactionService_CreateSell(t *testing.T) {
 		assert.Equal(t, models.TransactionTypeSell, transaction.Type)
 
 		// Verify holding was updated
-		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 		assert.NoError(t, err)
 		assert.Equal(t, decimal.NewFromInt(5), holding.Quantity) // 10 - 5
 	})
 
 	t.Run("insufficient shares error", func(t *testing.T) {
-		_, err := service.Create(
+		_, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeSell,
@@ -214,6 +302,7 @@ func TestTransactionService_CreateSell(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 
 		assert.Error(t, err)
@@ -221,7 +310,7 @@ func TestTransactionService_CreateSell(t *testing.T) {
 	})
 
 	t.Run("sell without holding", func(t *testing.T) {
-		_, err := service.Create(
+		_, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeSell,
@@ -232,6 +321,7 @@ func TestTransactionService_CreateSell(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 
 		assert.Error(t, err)
@@ -239,7 +329,63 @@ func TestTransactionService_CreateSell(t *testing.T) {
 	})
 }
 
+func TestTransactionService_CreateIncome(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTransactionTestDB(t)
+	transactionRepo := repository.NewTransactionRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	service := NewTransactionService(transactionRepo, portfolioRepo, holdingRepo)
+
+	user, portfolio := createTestUserAndPortfolio(t, db)
+
+	t.Run("income with a category", func(t *testing.T) {
+		transaction, err := service.Create(ctx,
+			portfolio.ID.String(),
+			user.ID.String(),
+			models.TransactionTypeLendingIncome,
+			"GME",
+			time.Now(),
+			decimal.NewFromFloat(3.25),
+			decimal.Zero,
+			decimal.Zero,
+			"USD",
+			"",
+			" FULLY_PAID_LENDING ",
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, "FULLY_PAID_LENDING", transaction.Category)
+		assert.Equal(t, "FULLY_PAID_LENDING", transaction.IncomeCategory())
+
+		// Income leaves positions unchanged
+		_, err = holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "GME")
+		assert.ErrorIs(t, err, models.ErrHoldingNotFound)
+	})
+
+	t.Run("category on a trade", func(t *testing.T) {
+		_, err := service.Create(ctx,
+			portfolio.ID.String(),
+			user.ID.String(),
+			models.TransactionTypeBuy,
+			"AAPL",
+			time.Now(),
+			decimal.NewFromInt(1),
+			decimal.NewFromInt(150),
+			decimal.Zero,
+			"USD",
+			"",
+			"FULLY_PAID_LENDING",
+		)
+
+		assert.ErrorIs(t, err, models.ErrInvalidIncomeCategory)
+	})
+}
+
 func TestTransactionService_GetByID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTransactionTestDB(t)
 	transactionRepo := repository.NewTransactionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
@@ -249,7 +395,7 @@ func TestTransactionService_GetByID(t *testing.T) {
 	user, portfolio := createTestUserAndPortfolio(t, db)
 
 	// Create a transaction
-	transaction, err := service.Create(
+	transaction, err := service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -260,11 +406,12 @@ func TestTransactionService_GetByID(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"Test",
+		"",
 	)
 	assert.NoError(t, err)
 
 	t.Run("successful retrieval", func(t *testing.T) {
-		retrieved, err := service.GetByID(transaction.ID.String(), user.ID.String())
+		retrieved, err := service.GetByID(ctx, transaction.ID.String(), user.ID.String())
 
 		assert.NoError(t, err)
 		assert.NotNil(t, retrieved)
@@ -273,7 +420,7 @@ func TestTransactionService_GetByID(t *testing.T) {
 
 	t.Run("unauthorized access", func(t *testing.T) {
 		otherUserID := uuid.New().String()
-		_, err := service.GetByID(transaction.ID.String(), otherUserID)
+		_, err := service.GetByID(ctx, transaction.ID.String(), otherUserID)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrUnauthorizedAccess, err)
@@ -281,6 +428,8 @@ func TestTransactionService_GetByID(t *testing.T) {
 }
 
 func TestTransactionService_GetByPortfolioID(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTransactionTestDB(t)
 	transactionRepo := repository.NewTransactionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
@@ -290,7 +439,7 @@ func TestTransactionService_GetByPortfolioID(t *testing.T) {
 	user, portfolio := createTestUserAndPortfolio(t, db)
 
 	// Create multiple transactions
-	_, err := service.Create(
+	_, err := service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -301,10 +450,11 @@ func TestTransactionService_GetByPortfolioID(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"",
+		"",
 	)
 	assert.NoError(t, err)
 
-	_, err = service.Create(
+	_, err = service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -315,11 +465,12 @@ func TestTransactionService_GetByPortfolioID(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"",
+		"",
 	)
 	assert.NoError(t, err)
 
 	t.Run("retrieve all transactions", func(t *testing.T) {
-		transactions, err := service.GetByPortfolioID(portfolio.ID.String(), user.ID.String())
+		transactions, err := service.GetByPortfolioID(ctx, portfolio.ID.String(), user.ID.String())
 
 		assert.NoError(t, err)
 		assert.Len(t, transactions, 2)
@@ -327,7 +478,7 @@ func TestTransactionService_GetByPortfolioID(t *testing.T) {
 
 	t.Run("unauthorized access", func(t *testing.T) {
 		otherUserID := uuid.New().String()
-		_, err := service.GetByPortfolioID(portfolio.ID.String(), otherUserID)
+		_, err := service.GetByPortfolioID(ctx, portfolio.ID.String(), otherUserID)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrUnauthorizedAccess, err)
@@ -335,6 +486,8 @@ func TestTransactionService_GetByPortfolioID(t *testing.T) {
 }
 
 func TestTransactionService_GetByPortfolioIDAndSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTransactionTestDB(t)
 	transactionRepo := repository.NewTransactionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
@@ -344,7 +497,7 @@ func TestTransactionService_GetByPortfolioIDAndSymbol(t *testing.T) {
 	user, portfolio := createTestUserAndPortfolio(t, db)
 
 	// Create transactions for different symbols
-	_, err := service.Create(
+	_, err := service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -355,10 +508,11 @@ func TestTransactionService_GetByPortfolioIDAndSymbol(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"",
+		"",
 	)
 	assert.NoError(t, err)
 
-	_, err = service.Create(
+	_, err = service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -369,10 +523,11 @@ func TestTransactionService_GetByPortfolioIDAndSymbol(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"",
+		"",
 	)
 	assert.NoError(t, err)
 
-	_, err = service.Create(
+	_, err = service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -383,11 +538,12 @@ func TestTransactionService_GetByPortfolioIDAndSymbol(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"",
+		"",
 	)
 	assert.NoError(t, err)
 
 	t.Run("filter by symbol", func(t *testing.T) {
-		transactions, err := service.GetByPortfolioIDAndSymbol(
+		transactions, err := service.GetByPortfolioIDAndSymbol(ctx,
 			portfolio.ID.String(),
 			"AAPL",
 			user.ID.String(),
@@ -402,6 +558,8 @@ func TestTransactionService_GetByPortfolioIDAndSymbol(t *testing.T) {
 }
 
 func TestTransactionService_Delete(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTransactionTestDB(t)
 	transactionRepo := repository.NewTransactionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
@@ -411,7 +569,7 @@ func TestTransactionService_Delete(t *testing.T) {
 	user, portfolio := createTestUserAndPortfolio(t, db)
 
 	// Create a transaction
-	transaction, err := service.Create(
+	transaction, err := service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -422,22 +580,23 @@ func TestTransactionService_Delete(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"",
+		"",
 	)
 	assert.NoError(t, err)
 
 	t.Run("successful deletion", func(t *testing.T) {
-		err := service.Delete(transaction.ID.String(), user.ID.String())
+		err := service.Delete(ctx, transaction.ID.String(), user.ID.String())
 
 		assert.NoError(t, err)
 
 		// Verify it's deleted
-		_, err = service.GetByID(transaction.ID.String(), user.ID.String())
+		_, err = service.GetByID(ctx, transaction.ID.String(), user.ID.String())
 		assert.Error(t, err)
 	})
 
 	t.Run("unauthorized deletion", func(t *testing.T) {
 		// Create another transaction
-		transaction2, err := service.Create(
+		transaction2, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -448,11 +607,12 @@ func TestTransactionService_Delete(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 		assert.NoError(t, err)
 
 		otherUserID := uuid.New().String()
-		err = service.Delete(transaction2.ID.String(), otherUserID)
+		err = service.Delete(ctx, transaction2.ID.String(), otherUserID)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrUnauthorizedAccess, err)
@@ -460,6 +620,8 @@ func TestTransactionService_Delete(t *testing.T) {
 }
 
 func TestTransactionService_Update(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTransactionTestDB(t)
 	transactionRepo := repository.NewTransactionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
@@ -469,7 +631,7 @@ func TestTransactionService_Update(t *testing.T) {
 	user, portfolio := createTestUserAndPortfolio(t, db)
 
 	// Create initial transaction
-	transaction, err := service.Create(
+	transaction, err := service.Create(ctx,
 		portfolio.ID.String(),
 		user.ID.String(),
 		models.TransactionTypeBuy,
@@ -480,13 +642,15 @@ func TestTransactionService_Update(t *testing.T) {
 		decimal.Zero,
 		"USD",
 		"Initial purchase",
+		"",
 	)
 	assert.NoError(t, err)
 
 	t.Run("successful update", func(t *testing.T) {
-		updated, err := service.Update(
+		updated, err := service.Update(ctx,
 			transaction.ID.String(),
 			user.ID.String(),
+			1,
 			models.TransactionTypeBuy,
 			"AAPL",
 			time.Now(),
@@ -495,6 +659,7 @@ func TestTransactionService_Update(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"Updated purchase",
+			"",
 		)
 
 		assert.NoError(t, err)
@@ -504,16 +669,17 @@ func TestTransactionService_Update(t *testing.T) {
 		assert.Equal(t, "Updated purchase", updated.Notes)
 
 		// Verify holdings were recalculated
-		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "AAPL")
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "AAPL")
 		assert.NoError(t, err)
 		assert.Equal(t, decimal.NewFromInt(15), holding.Quantity)
 	})
 
 	t.Run("unauthorized update", func(t *testing.T) {
 		otherUserID := uuid.New().String()
-		_, err := service.Update(
+		_, err := service.Update(ctx,
 			transaction.ID.String(),
 			otherUserID,
+			2,
 			models.TransactionTypeBuy,
 			"AAPL",
 			time.Now(),
@@ -522,6 +688,7 @@ func TestTransactionService_Update(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"Unauthorized update",
+			"",
 		)
 
 		assert.Error(t, err)
@@ -530,9 +697,10 @@ func TestTransactionService_Update(t *testing.T) {
 
 	t.Run("validation error", func(t *testing.T) {
 		// Try to update with zero quantity (should fail validation)
-		_, err := service.Update(
+		_, err := service.Update(ctx,
 			transaction.ID.String(),
 			user.ID.String(),
+			2,
 			models.TransactionTypeBuy,
 			"AAPL",
 			time.Now(),
@@ -541,6 +709,7 @@ func TestTransactionService_Update(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"Invalid update",
+			"",
 		)
 
 		assert.Error(t, err)
@@ -548,6 +717,8 @@ func TestTransactionService_Update(t *testing.T) {
 }
 
 func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
+	ctx := context.Background()
+
 	db := setupTransactionTestDB(t)
 	transactionRepo := repository.NewTransactionRepository(db)
 	portfolioRepo := repository.NewPortfolioRepository(db)
@@ -558,7 +729,7 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 
 	t.Run("recalculate after multiple buys", func(t *testing.T) {
 		// Create first buy
-		_, err := service.Create(
+		_, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -569,11 +740,12 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 		assert.NoError(t, err)
 
 		// Create second buy
-		_, err = service.Create(
+		_, err = service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -584,11 +756,12 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 		assert.NoError(t, err)
 
 		// Verify holdings
-		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "TSLA")
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "TSLA")
 		assert.NoError(t, err)
 		assert.Equal(t, decimal.NewFromInt(15), holding.Quantity)
 		// Cost basis = (10 * 100) + (5 * 110) = 1000 + 550 = 1550
@@ -597,7 +770,7 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 
 	t.Run("recalculate after buy and sell", func(t *testing.T) {
 		// Create buy
-		_, err := service.Create(
+		_, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -608,11 +781,12 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 		assert.NoError(t, err)
 
 		// Create sell
-		_, err = service.Create(
+		_, err = service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeSell,
@@ -623,11 +797,12 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 		assert.NoError(t, err)
 
 		// Verify holdings
-		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "MSFT")
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "MSFT")
 		assert.NoError(t, err)
 		assert.Equal(t, decimal.NewFromInt(15), holding.Quantity)
 		// Cost basis = (20 * 200) - (5 * 200) = 4000 - 1000 = 3000
@@ -636,7 +811,7 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 
 	t.Run("delete holding when quantity reaches zero", func(t *testing.T) {
 		// Create buy
-		_, err := service.Create(
+		_, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -647,16 +822,17 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 		assert.NoError(t, err)
 
 		// Verify holding exists
-		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "GOOG")
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "GOOG")
 		assert.NoError(t, err)
 		assert.NotNil(t, holding)
 
 		// Sell all shares
-		_, err = service.Create(
+		_, err = service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeSell,
@@ -667,25 +843,26 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 		assert.NoError(t, err)
 
 		// Verify holding is deleted
-		_, err = holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "GOOG")
+		_, err = holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "GOOG")
 		assert.Error(t, err)
 		assert.Equal(t, models.ErrHoldingNotFound, err)
 	})
 
 	t.Run("create holding if not exists", func(t *testing.T) {
 		// Manually delete a holding to test recreation
-		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "TSLA")
+		holding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "TSLA")
 		assert.NoError(t, err)
-		err = holdingRepo.Delete(holding.ID.String())
+		err = holdingRepo.Delete(ctx, holding.ID.String())
 		assert.NoError(t, err)
 
 		// Trigger recalculation via service (accessing private method through Update)
 		// Create a transaction to trigger recalculation
-		tx, err := service.Create(
+		tx, err := service.Create(ctx,
 			portfolio.ID.String(),
 			user.ID.String(),
 			models.TransactionTypeBuy,
@@ -696,11 +873,12 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 			decimal.Zero,
 			"USD",
 			"",
+			"",
 		)
 		assert.NoError(t, err)
 
 		// Holding should be recreated through recalculation
-		recreatedHolding, err := holdingRepo.FindByPortfolioIDAndSymbol(portfolio.ID.String(), "TSLA")
+		recreatedHolding, err := holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "TSLA")
 		assert.NoError(t, err)
 		assert.NotNil(t, recreatedHolding)
 
@@ -708,3 +886,108 @@ func TestTransactionService_RecalculateHoldingsForSymbol(t *testing.T) {
 		_ = tx
 	})
 }
+
+func TestTransactionService_CreateValidatesSymbol(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTransactionTestDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Security{}))
+	transactionRepo := repository.NewTransactionRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	securityRepo := repository.NewSecurityRepository(db)
+	marketData := new(MockMarketDataService)
+	service := NewTransactionServiceWithSymbolValidation(transactionRepo, portfolioRepo, holdingRepo,
+		NewSecurityService(securityRepo, marketData))
+
+	user, portfolio := createTestUserAndPortfolio(t, db)
+	require.NoError(t, securityRepo.Upsert(ctx, []*models.Security{{Symbol: "AAPL", Name: "Apple Inc"}}))
+
+	create := func(symbol string) (*models.Transaction, error) {
+		return service.Create(ctx, portfolio.ID.String(), user.ID.String(), models.TransactionTypeBuy, symbol,
+			time.Now(), decimal.NewFromInt(1), decimal.NewFromInt(100), decimal.Zero, "USD", "", "")
+	}
+
+	t.Run("stored symbols are normalized without asking the provider", func(t *testing.T) {
+		transaction, err := create("nasdaq:aapl")
+		require.NoError(t, err)
+		assert.Equal(t, "AAPL", transaction.Symbol)
+	})
+
+	t.Run("unknown symbols are refused with suggestions", func(t *testing.T) {
+		marketData.On("SearchSymbols", "APPL").Return([]*models.Security{
+			{Symbol: "AAPL", Name: "Apple Inc"},
+			{Symbol: "APLE", Name: "Apple Hospitality REIT Inc"},
+		}, nil)
+
+		_, err := create("appl")
+		var invalid *models.InvalidSymbolError
+		require.True(t, errors.As(err, &invalid))
+		assert.Equal(t, "APPL", invalid.Symbol)
+		assert.Equal(t, []string{"AAPL", "APLE"}, invalid.Suggestions)
+
+		_, err = holdingRepo.FindByPortfolioIDAndSymbol(ctx, portfolio.ID.String(), "APPL")
+		assert.Error(t, err)
+	})
+
+	t.Run("symbols are accepted while the provider is unreachable", func(t *testing.T) {
+		marketData.On("SearchSymbols", "MSFT").Return(nil, models.ErrQuotaExceeded).Once()
+
+		transaction, err := create("MSFT")
+		require.NoError(t, err)
+		assert.Equal(t, "MSFT", transaction.Symbol)
+	})
+
+	t.Run("crypto symbols aren't looked up", func(t *testing.T) {
+		transaction, err := create("btc-usd")
+		require.NoError(t, err)
+		assert.Equal(t, "BTC-USD", transaction.Symbol)
+	})
+}
+
+func TestTransactionService_GetSymbolLedger(t *testing.T) {
+	ctx := context.Background()
+
+	db := setupTransactionTestDB(t)
+	service := NewTransactionService(repository.NewTransactionRepository(db),
+		repository.NewPortfolioRepository(db), repository.NewHoldingRepository(db))
+	user, portfolio := createTestUserAndPortfolio(t, db)
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	price := func(p int64) *decimal.Decimal { v := decimal.NewFromInt(p); return &v }
+	// Recorded out of order, with another symbol in between
+	for _, tx := range []*models.Transaction{
+		{Type: models.TransactionTypeSell, Symbol: "AAPL", Date: day(20), Quantity: decimal.NewFromInt(5), Price: price(80)},
+		{Type: models.TransactionTypeBuy, Symbol: "AAPL", Date: day(1), Quantity: decimal.NewFromInt(10), Price: price(100), Commission: decimal.NewFromInt(5)},
+		{Type: models.TransactionTypeBuy, Symbol: "MSFT", Date: day(5), Quantity: decimal.NewFromInt(3), Price: price(300)},
+		{Type: models.TransactionTypeSplit, Symbol: "AAPL", Date: day(10), Quantity: decimal.NewFromInt(10)},
+	} {
+		tx.PortfolioID = portfolio.ID
+		tx.Currency = "USD"
+		require.NoError(t, db.Create(tx).Error)
+	}
+
+	ledger, positions, err := service.GetSymbolLedger(ctx, portfolio.ID.String(), "AAPL", user.ID.String())
+	require.NoError(t, err)
+	require.Len(t, ledger, 3)
+
+	want := []struct {
+		txType    models.TransactionType
+		quantity  int64
+		costBasis string
+	}{
+		{models.TransactionTypeBuy, 10, "1005"},
+		{models.TransactionTypeSplit, 20, "1005"},
+		{models.TransactionTypeSell, 15, "753.75"},
+	}
+	for i, w := range want {
+		assert.Equal(t, w.txType, ledger[i].Type)
+		position := positions[ledger[i].ID]
+		require.NotNil(t, position)
+		assert.True(t, decimal.NewFromInt(w.quantity).Equal(position.Quantity), position.Quantity.String())
+		assert.Equal(t, w.costBasis, position.CostBasis.String())
+	}
+
+	_, _, err = service.GetSymbolLedger(ctx, portfolio.ID.String(), "AAPL", uuid.New().String())
+	assert.ErrorIs(t, err, models.ErrUnauthorizedAccess)
+}
@@ -0,0 +1,330 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// UserUsage counts the resources a user owns
+type UserUsage struct {
+	Portfolios           int64
+	Transactions         int64
+	ImportBatches        int64
+	Holdings             int64
+	PerformanceSnapshots int64
+	ActiveAPIKeys        int64
+	// ActiveSessions counts unexpired, unrevoked refresh tokens
+	ActiveSessions int64
+}
+
+// ForcedPasswordReset is the outcome of forcing a user to choose a new password
+type ForcedPasswordReset struct {
+	User *models.User
+	// ExpiresAt is when the reset link sent to the user stops working
+	ExpiresAt time.Time
+	// EmailSent is false if the reset email could not be delivered
+	EmailSent bool
+}
+
+// UserSchemaResolver looks up the tenant schema holding a user's portfolio data in
+// multi-schema mode
+type UserSchemaResolver interface {
+	// FindSchemaNameByUserID returns the schema of the user's organization, or "" for the
+	// public schema
+	FindSchemaNameByUserID(userID string) (string, error)
+}
+
+// UserAdminService defines the interface for managing users on behalf of an administrator.
+// Methods taking adminID refuse to let administrators lock themselves out.
+type UserAdminService interface {
+	ListUsers(filter repository.UserFilter) ([]*models.User, int64, error)
+	GetUser(userID string) (*models.User, error)
+	SetRole(adminID, userID string, role models.UserRole) (*models.User, error)
+	DisableUser(adminID, userID string) (*models.User, error)
+	EnableUser(userID string) (*models.User, error)
+	ForcePasswordReset(userID string) (*ForcedPasswordReset, error)
+	DeleteUser(adminID, userID string) error
+	GetUsage(ctx context.Context, userID string) (*UserUsage, error)
+}
+
+// userAdminService implements UserAdminService interface
+type userAdminService struct {
+	db            *gorm.DB
+	emailService  EmailService
+	resetValidity time.Duration
+	schemas       UserSchemaResolver
+	now           func() time.Time
+}
+
+// NewUserAdminService creates a new UserAdminService instance. Forced password resets send
+// links valid for resetValidity.
+func NewUserAdminService(db *gorm.DB, emailService EmailService, resetValidity time.Duration) UserAdminService {
+	return &userAdminService{
+		db:            db,
+		emailService:  emailService,
+		resetValidity: resetValidity,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// NewUserAdminServiceWithTenantSchemas creates a UserAdminService that counts each user's
+// usage in the schema of their organization
+func NewUserAdminServiceWithTenantSchemas(
+	db *gorm.DB,
+	emailService EmailService,
+	resetValidity time.Duration,
+	schemas UserSchemaResolver,
+) UserAdminService {
+	return &userAdminService{
+		db:            db,
+		emailService:  emailService,
+		resetValidity: resetValidity,
+		schemas:       schemas,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// ListUsers returns the users matching filter and the total number of matches
+func (s *userAdminService) ListUsers(filter repository.UserFilter) ([]*models.User, int64, error) {
+	if filter.Role != "" && !filter.Role.IsValid() {
+		return nil, 0, models.ErrInvalidRole
+	}
+	return repository.NewUserRepository(s.db).List(filter)
+}
+
+// GetUser retrieves a user by ID
+func (s *userAdminService) GetUser(userID string) (*models.User, error) {
+	return findUser(repository.NewUserRepository(s.db), userID)
+}
+
+// SetRole changes a user's role. Administrators cannot demote themselves.
+func (s *userAdminService) SetRole(adminID, userID string, role models.UserRole) (*models.User, error) {
+	if !role.IsValid() {
+		return nil, models.ErrInvalidRole
+	}
+	if adminID == userID && role != models.RoleAdmin {
+		return nil, models.ErrAdminSelfChange
+	}
+
+	userRepo := repository.NewUserRepository(s.db)
+	user, err := findUser(userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	user.Role = role
+	if err := userRepo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// DisableUser disables a user's account and ends their sessions. Access tokens and API keys
+// already issued are rejected from then on. Disabling a disabled user changes nothing.
+func (s *userAdminService) DisableUser(adminID, userID string) (*models.User, error) {
+	if adminID == userID {
+		return nil, models.ErrAdminSelfChange
+	}
+
+	var user *models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := repository.NewUserRepository(tx)
+
+		var err error
+		user, err = findUser(userRepo, userID)
+		if err != nil {
+			return err
+		}
+		if user.IsDisabled() {
+			return nil
+		}
+
+		disabledAt := s.now()
+		user.DisabledAt = &disabledAt
+		if err := userRepo.Save(user); err != nil {
+			return err
+		}
+		return repository.NewRefreshTokenRepository(tx).RevokeByUserID(userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// EnableUser re-enables a disabled account. The user has to log in again.
+func (s *userAdminService) EnableUser(userID string) (*models.User, error) {
+	userRepo := repository.NewUserRepository(s.db)
+	user, err := findUser(userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsDisabled() {
+		return user, nil
+	}
+
+	user.DisabledAt = nil
+	if err := userRepo.Save(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ForcePasswordReset locks a user's account until they choose a new password, ends their
+// sessions, and emails them a reset link. Forcing it again sends a new link.
+func (s *userAdminService) ForcePasswordReset(userID string) (*ForcedPasswordReset, error) {
+	var resetToken string
+	result := &ForcedPasswordReset{}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		userRepo := repository.NewUserRepository(tx)
+
+		user, err := findUser(userRepo, userID)
+		if err != nil {
+			return err
+		}
+
+		user.PasswordResetRequired = true
+		if err := userRepo.Save(user); err != nil {
+			return err
+		}
+		if err := repository.NewRefreshTokenRepository(tx).RevokeByUserID(userID); err != nil {
+			return err
+		}
+
+		resetToken, err = generateSecret(TokenLength)
+		if err != nil {
+			return fmt.Errorf("failed to generate reset token: %w", err)
+		}
+		result.ExpiresAt = s.now().Add(s.resetValidity)
+		token := &models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: hashResetToken(resetToken),
+			ExpiresAt: result.ExpiresAt,
+		}
+		if err := repository.NewPasswordResetRepository(tx).Create(token); err != nil {
+			return err
+		}
+
+		result.User = user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Email the link only once the account is locked. A delivery failure is reported rather
+	// than returned, since the user can still request a link through forgot-password.
+	result.EmailSent = s.emailService.SendPasswordResetEmail(result.User.Email, resetToken) == nil
+
+	return result, nil
+}
+
+// DeleteUser deletes a user along with their portfolios, tokens and API keys.
+// Administrators cannot delete themselves.
+func (s *userAdminService) DeleteUser(adminID, userID string) error {
+	if adminID == userID {
+		return models.ErrAdminSelfChange
+	}
+
+	userRepo := repository.NewUserRepository(s.db)
+	if _, err := findUser(userRepo, userID); err != nil {
+		return err
+	}
+	return userRepo.Delete(userID)
+}
+
+// GetUsage counts the resources a user owns
+func (s *userAdminService) GetUsage(ctx context.Context, userID string) (*UserUsage, error) {
+	user, err := findUser(repository.NewUserRepository(s.db), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Portfolio data lives in the schema of the user's organization, not the caller's
+	if s.schemas != nil {
+		schema, err := s.schemas.FindSchemaNameByUserID(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve organization schema: %w", err)
+		}
+		ctx = database.WithSchema(ctx, schema)
+	}
+	db := s.db.WithContext(ctx)
+
+	// The portfolio IDs are looked up first rather than in subqueries, which tenant tables
+	// aren't qualified in
+	var portfolioIDs []uuid.UUID
+	if err := db.Model(&models.Portfolio{}).Where("user_id = ?", user.ID).Pluck("id", &portfolioIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list portfolios: %w", err)
+	}
+
+	usage := &UserUsage{Portfolios: int64(len(portfolioIDs))}
+	if len(portfolioIDs) > 0 {
+		counts := []struct {
+			model interface{}
+			query string
+			count *int64
+		}{
+			{&models.Transaction{}, "portfolio_id IN ?", &usage.Transactions},
+			{&models.Holding{}, "portfolio_id IN ?", &usage.Holdings},
+			{&models.PerformanceSnapshot{}, "portfolio_id IN ?", &usage.PerformanceSnapshots},
+		}
+		for _, c := range counts {
+			if err := db.Model(c.model).Where(c.query, portfolioIDs).Count(c.count).Error; err != nil {
+				return nil, fmt.Errorf("failed to count usage: %w", err)
+			}
+		}
+
+		err := db.Model(&models.Transaction{}).
+			Where("portfolio_id IN ? AND import_batch_id IS NOT NULL", portfolioIDs).
+			Distinct("import_batch_id").
+			Count(&usage.ImportBatches).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to count import batches: %w", err)
+		}
+	}
+
+	err = db.Model(&models.APIKey{}).
+		Where("user_id = ? AND revoked_at IS NULL", user.ID).
+		Count(&usage.ActiveAPIKeys).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count API keys: %w", err)
+	}
+
+	err = db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, s.now()).
+		Count(&usage.ActiveSessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sessions: %w", err)
+	}
+
+	return usage, nil
+}
+
+// findUser looks up a user by ID, reporting malformed IDs as not found
+func findUser(userRepo repository.UserRepository, userID string) (*models.User, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil, models.ErrUserNotFound
+	}
+
+	user, err := userRepo.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrUserNotFound) {
+			return nil, models.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupUserAdminTest(t *testing.T) (*gorm.DB, UserAdminService, *mockEmailService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.RefreshToken{}, &models.PasswordResetToken{}, &models.APIKey{},
+		&models.Portfolio{}, &models.Transaction{}, &models.Holding{}, &models.PerformanceSnapshot{},
+	))
+
+	emailService := newMockEmailService()
+	return db, NewUserAdminService(db, emailService, time.Hour), emailService
+}
+
+func createUserAdminTestUser(t *testing.T, db *gorm.DB, email string, role models.UserRole) *models.User {
+	user := &models.User{Email: email, Role: role}
+	require.NoError(t, user.SetPassword("password123"))
+	require.NoError(t, repository.NewUserRepository(db).Create(user))
+	return user
+}
+
+func createUserAdminTestSession(t *testing.T, db *gorm.DB, user *models.User) {
+	require.NoError(t, repository.NewRefreshTokenRepository(db).Create(&models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: uuid.NewString(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+}
+
+func TestUserAdminService_ListUsers(t *testing.T) {
+	db, service, _ := setupUserAdminTest(t)
+	admin := createUserAdminTestUser(t, db, "ops@example.com", models.RoleAdmin)
+	createUserAdminTestUser(t, db, "jane@acme.example", models.RoleUser)
+	createUserAdminTestUser(t, db, "john@acme.example", models.RoleUser)
+
+	users, total, err := service.ListUsers(repository.UserFilter{Email: "ACME", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	require.Len(t, users, 1)
+	assert.Equal(t, "jane@acme.example", users[0].Email)
+
+	users, total, err = service.ListUsers(repository.UserFilter{Role: models.RoleAdmin})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, admin.ID, users[0].ID)
+
+	_, _, err = service.ListUsers(repository.UserFilter{Role: "owner"})
+	assert.Equal(t, models.ErrInvalidRole, err)
+}
+
+func TestUserAdminService_SetRole(t *testing.T) {
+	db, service, _ := setupUserAdminTest(t)
+	admin := createUserAdminTestUser(t, db, "ops@example.com", models.RoleAdmin)
+	user := createUserAdminTestUser(t, db, "jane@example.com", models.RoleUser)
+
+	promoted, err := service.SetRole(admin.ID.String(), user.ID.String(), models.RoleAdmin)
+	require.NoError(t, err)
+	assert.True(t, promoted.IsAdmin())
+
+	_, err = service.SetRole(admin.ID.String(), admin.ID.String(), models.RoleUser)
+	assert.Equal(t, models.ErrAdminSelfChange, err)
+
+	_, err = service.SetRole(admin.ID.String(), user.ID.String(), "owner")
+	assert.Equal(t, models.ErrInvalidRole, err)
+
+	_, err = service.SetRole(admin.ID.String(), "not-a-uuid", models.RoleUser)
+	assert.Equal(t, models.ErrUserNotFound, err)
+}
+
+func TestUserAdminService_DisableAndEnableUser(t *testing.T) {
+	db, service, _ := setupUserAdminTest(t)
+	admin := createUserAdminTestUser(t, db, "ops@example.com", models.RoleAdmin)
+	user := createUserAdminTestUser(t, db, "jane@example.com", models.RoleUser)
+	createUserAdminTestSession(t, db, user)
+
+	disabled, err := service.DisableUser(admin.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	assert.True(t, disabled.IsDisabled())
+	assert.Equal(t, models.ErrUserDisabled, disabled.CanAuthenticate())
+
+	// Disabling ends the user's sessions
+	usage, err := service.GetUsage(context.Background(), user.ID.String())
+	require.NoError(t, err)
+	assert.Zero(t, usage.ActiveSessions)
+
+	// Disabling again keeps the original time
+	again, err := service.DisableUser(admin.ID.String(), user.ID.String())
+	require.NoError(t, err)
+	assert.True(t, disabled.DisabledAt.Equal(*again.DisabledAt))
+
+	enabled, err := service.EnableUser(user.ID.String())
+	require.NoError(t, err)
+	assert.False(t, enabled.IsDisabled())
+	assert.NoError(t, enabled.CanAuthenticate())
+
+	_, err = service.DisableUser(admin.ID.String(), admin.ID.String())
+	assert.Equal(t, models.ErrAdminSelfChange, err)
+}
+
+func TestUserAdminService_ForcePasswordReset(t *testing.T) {
+	db, service, emailService := setupUserAdminTest(t)
+	user := createUserAdminTestUser(t, db, "jane@example.com", models.RoleUser)
+	createUserAdminTestSession(t, db, user)
+
+	reset, err := service.ForcePasswordReset(user.ID.String())
+	require.NoError(t, err)
+	assert.True(t, reset.EmailSent)
+	assert.True(t, reset.User.PasswordResetRequired)
+	assert.Equal(t, models.ErrPasswordResetRequired, reset.User.CanAuthenticate())
+
+	require.Len(t, emailService.sentEmails, 1)
+	assert.Equal(t, "jane@example.com", emailService.sentEmails[0].to)
+
+	// The emailed token resets the password and unlocks the account
+	token, err := repository.NewPasswordResetRepository(db).FindByTokenHash(hashResetToken(emailService.sentEmails[0].token))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, token.UserID)
+
+	userRepo := repository.NewUserRepository(db)
+	require.NoError(t, userRepo.UpdatePassword(user.ID.String(), "new-hash"))
+	unlocked, err := userRepo.FindByID(user.ID.String())
+	require.NoError(t, err)
+	assert.False(t, unlocked.PasswordResetRequired)
+
+	// A failed delivery still locks the account
+	emailService.shouldFail = true
+	reset, err = service.ForcePasswordReset(user.ID.String())
+	require.NoError(t, err)
+	assert.False(t, reset.EmailSent)
+	assert.True(t, reset.User.PasswordResetRequired)
+}
+
+func TestUserAdminService_DeleteUser(t *testing.T) {
+	db, service, _ := setupUserAdminTest(t)
+	admin := createUserAdminTestUser(t, db, "ops@example.com", models.RoleAdmin)
+	user := createUserAdminTestUser(t, db, "jane@example.com", models.RoleUser)
+
+	assert.Equal(t, models.ErrAdminSelfChange, service.DeleteUser(admin.ID.String(), admin.ID.String()))
+
+	require.NoError(t, service.DeleteUser(admin.ID.String(), user.ID.String()))
+	_, err := service.GetUser(user.ID.String())
+	assert.Equal(t, models.ErrUserNotFound, err)
+
+	assert.Equal(t, models.ErrUserNotFound, service.DeleteUser(admin.ID.String(), user.ID.String()))
+}
+
+func TestUserAdminService_GetUsage(t *testing.T) {
+	db, service, _ := setupUserAdminTest(t)
+	user := createUserAdminTestUser(t, db, "jane@example.com", models.RoleUser)
+	other := createUserAdminTestUser(t, db, "john@example.com", models.RoleUser)
+	ctx := context.Background()
+
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	batchID := uuid.New()
+	price := decimal.NewFromInt(100)
+	for _, owner := range []*models.User{user, user, other} {
+		portfolio := &models.Portfolio{
+			UserID: owner.ID, Name: "Brokerage", BaseCurrency: "USD", CostBasisMethod: models.CostBasisFIFO,
+		}
+		require.NoError(t, portfolioRepo.Create(ctx, portfolio))
+		for i := 0; i < 2; i++ {
+			require.NoError(t, transactionRepo.Create(ctx, &models.Transaction{
+				PortfolioID:   portfolio.ID,
+				Type:          models.TransactionTypeBuy,
+				Symbol:        "AAPL",
+				Date:          time.Now(),
+				Quantity:      decimal.NewFromInt(1),
+				Price:         &price,
+				Currency:      "USD",
+				ImportBatchID: &batchID,
+			}))
+		}
+	}
+	createUserAdminTestSession(t, db, user)
+
+	usage, err := service.GetUsage(ctx, user.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), usage.Portfolios)
+	assert.Equal(t, int64(4), usage.Transactions)
+	assert.Equal(t, int64(1), usage.ImportBatches)
+	assert.Equal(t, int64(1), usage.ActiveSessions)
+	assert.Zero(t, usage.ActiveAPIKeys)
+
+	_, err = service.GetUsage(ctx, uuid.NewString())
+	assert.Equal(t, models.ErrUserNotFound, err)
+}
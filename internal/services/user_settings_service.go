@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// UserSettingsService defines the interface for user preferences
+type UserSettingsService interface {
+	Get(ctx context.Context, userID string) (*models.UserSettings, error)
+	Update(ctx context.Context, userID string, req *dto.UpdateUserSettingsRequest) (*models.UserSettings, error)
+}
+
+// userSettingsService implements UserSettingsService interface
+type userSettingsService struct {
+	settingsRepo repository.UserSettingsRepository
+}
+
+// NewUserSettingsService creates a new UserSettingsService instance
+func NewUserSettingsService(settingsRepo repository.UserSettingsRepository) UserSettingsService {
+	return &userSettingsService{
+		settingsRepo: settingsRepo,
+	}
+}
+
+// Get returns the user's settings, or the defaults if they never saved any
+func (s *userSettingsService) Get(ctx context.Context, userID string) (*models.UserSettings, error) {
+	uid, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+	return s.settingsRepo.FindByUserID(ctx, uid)
+}
+
+// Update changes the settings set in req, leaving the others as they were
+func (s *userSettingsService) Update(ctx context.Context, userID string, req *dto.UpdateUserSettingsRequest) (*models.UserSettings, error) {
+	settings, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DisplayCurrency != nil {
+		settings.DisplayCurrency = *req.DisplayCurrency
+	}
+	if req.Timezone != nil {
+		settings.Timezone = strings.TrimSpace(*req.Timezone)
+	}
+	if req.Locale != nil {
+		settings.Locale = strings.TrimSpace(*req.Locale)
+	}
+	if req.DefaultCostBasisMethod != nil {
+		settings.DefaultCostBasisMethod = *req.DefaultCostBasisMethod
+	}
+	if req.NotifyCorporateActions != nil {
+		settings.NotifyCorporateActions = *req.NotifyCorporateActions
+	}
+	if req.NotifyImports != nil {
+		settings.NotifyImports = *req.NotifyImports
+	}
+	if req.NotifyReports != nil {
+		settings.NotifyReports = *req.NotifyReports
+	}
+	if req.PushNotifications != nil {
+		settings.PushNotifications = *req.PushNotifications
+	}
+
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.settingsRepo.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+func setupUserSettingsTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{}, &models.Portfolio{}, &models.UserSettings{}, &models.Notification{}, &models.PushDevice{},
+	))
+	return db
+}
+
+func TestUserSettingsService_Update(t *testing.T) {
+	ctx := context.Background()
+	service := NewUserSettingsService(repository.NewUserSettingsRepository(setupUserSettingsTestDB(t)))
+	userID := uuid.New().String()
+
+	timezone, notify := "America/Sao_Paulo", false
+	settings, err := service.Update(ctx, userID, &dto.UpdateUserSettingsRequest{Timezone: &timezone, NotifyReports: &notify})
+	require.NoError(t, err)
+	assert.Equal(t, "America/Sao_Paulo", settings.Timezone)
+	assert.False(t, settings.NotifyReports)
+
+	// Fields that aren't set are left unchanged
+	locale := "pt-BR"
+	settings, err = service.Update(ctx, userID, &dto.UpdateUserSettingsRequest{Locale: &locale})
+	require.NoError(t, err)
+	assert.Equal(t, "America/Sao_Paulo", settings.Timezone)
+	assert.False(t, settings.NotifyReports)
+	assert.Equal(t, "USD", settings.DisplayCurrency)
+
+	// Invalid settings aren't saved
+	locale = "??"
+	_, err = service.Update(ctx, userID, &dto.UpdateUserSettingsRequest{Locale: &locale})
+	assert.Equal(t, models.ErrInvalidLocale, err)
+	settings, err = service.Get(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "pt-BR", settings.Locale)
+}
+
+func TestNotificationService_FollowsSettings(t *testing.T) {
+	ctx := context.Background()
+	db := setupUserSettingsTestDB(t)
+	settings := NewUserSettingsService(repository.NewUserSettingsRepository(db))
+	pushes := NewPushService(repository.NewPushDeviceRepository(db), nil).(*pushService)
+	sender := &recordingSender{}
+	pushes.senders[models.PushPlatformAPNs] = sender
+	service := NewNotificationServiceWithSettings(repository.NewNotificationRepository(db), pushes, settings)
+
+	userID := uuid.New()
+	_, err := pushes.RegisterDevice(ctx, userID.String(), &dto.RegisterPushDeviceRequest{Platform: models.PushPlatformAPNs, Endpoint: "abcd"})
+	require.NoError(t, err)
+	off := false
+	_, err = settings.Update(ctx, userID.String(), &dto.UpdateUserSettingsRequest{NotifyImports: &off, PushNotifications: &off})
+	require.NoError(t, err)
+
+	// Imports are turned off, and corporate actions are shown in the app without being pushed
+	require.NoError(t, service.Notify(ctx, &models.Notification{UserID: userID, Type: models.NotificationTypeImportCompleted, Title: "Import finished"}))
+	require.NoError(t, service.Notify(ctx, &models.Notification{UserID: userID, Type: models.NotificationTypeCorporateAction, Title: "AAPL SPLIT"}))
+
+	notifications, _, err := service.List(ctx, userID.String(), false, 0)
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, models.NotificationTypeCorporateAction, notifications[0].Type)
+	assert.Empty(t, sender.sent)
+}
+
+func TestPortfolioService_CreateWithSettingsDefaults(t *testing.T) {
+	ctx := context.Background()
+	db := setupUserSettingsTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	settings := NewUserSettingsService(repository.NewUserSettingsRepository(db))
+	service := NewPortfolioServiceWithSettings(repository.NewPortfolioRepository(db), userRepo, nil, settings)
+
+	user := &models.User{ID: uuid.New(), Email: "investor@example.com", PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(user))
+	currency, method := "CHF", models.CostBasisLIFO
+	_, err := settings.Update(ctx, user.ID.String(), &dto.UpdateUserSettingsRequest{DisplayCurrency: &currency, DefaultCostBasisMethod: &method})
+	require.NoError(t, err)
+
+	portfolio, err := service.Create(ctx, user.ID.String(), "Swiss", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, "CHF", portfolio.BaseCurrency)
+	assert.Equal(t, models.CostBasisLIFO, portfolio.CostBasisMethod)
+
+	// Values given take precedence
+	portfolio, err = service.Create(ctx, user.ID.String(), "US", "", "USD", models.CostBasisFIFO)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", portfolio.BaseCurrency)
+	assert.Equal(t, models.CostBasisFIFO, portfolio.CostBasisMethod)
+}
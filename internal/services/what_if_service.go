@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+// Type alias for dto type for consistency with the other analytics services
+type WhatIfResult = dto.WhatIfResult
+
+// WhatIfService defines the interface for previewing the effect of hypothetical trades on a
+// portfolio without recording them
+type WhatIfService interface {
+	Analyze(ctx context.Context, portfolioID, userID string, req *dto.WhatIfRequest) (*WhatIfResult, error)
+}
+
+// whatIfService implements WhatIfService interface
+type whatIfService struct {
+	portfolioRepo repository.PortfolioRepository
+	holdingRepo   repository.HoldingRepository
+	taxLotRepo    repository.TaxLotRepository
+	marketData    MarketDataService
+	rounding      models.RoundingPolicy
+	now           func() time.Time
+}
+
+// NewWhatIfService creates a new WhatIfService instance that closes lots and rounds gains the
+// way the tax lot service does. marketData prices the positions that aren't traded and may be
+// nil, in which case they are valued at cost.
+func NewWhatIfService(
+	portfolioRepo repository.PortfolioRepository,
+	holdingRepo repository.HoldingRepository,
+	taxLotRepo repository.TaxLotRepository,
+	marketData MarketDataService,
+	rounding models.RoundingPolicy,
+) WhatIfService {
+	return &whatIfService{
+		portfolioRepo: portfolioRepo,
+		holdingRepo:   holdingRepo,
+		taxLotRepo:    taxLotRepo,
+		marketData:    marketData,
+		rounding:      rounding,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Analyze applies the trades, in order, to copies of the portfolio's holdings and tax lots, and
+// compares the positions, realized gains and cash before and after. Nothing is stored.
+func (s *whatIfService) Analyze(ctx context.Context, portfolioID, userID string, req *dto.WhatIfRequest) (*WhatIfResult, error) {
+	portfolio, err := s.portfolioRepo.FindByID(ctx, portfolioID)
+	if err != nil {
+		return nil, models.ErrPortfolioNotFound
+	}
+	if !portfolio.AccessibleBy(ctx, userID) {
+		return nil, models.ErrUnauthorizedAccess
+	}
+	if err := validateWhatIf(req); err != nil {
+		return nil, err
+	}
+
+	holdings, err := s.holdingRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve holdings: %w", err)
+	}
+	taxLots, err := s.taxLotRepo.FindByPortfolioID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tax lots: %w", err)
+	}
+
+	replay := newLedgerReplay(portfolio, s.rounding)
+	before := make(map[string]*models.Holding, len(holdings))
+	for _, holding := range holdings {
+		before[holding.Symbol] = holding
+		held := *holding
+		replay.holdings[holding.Symbol] = &held
+	}
+	for _, lot := range taxLots {
+		open := *lot
+		replay.taxLots[lot.Symbol] = append(replay.taxLots[lot.Symbol], &open)
+	}
+	for _, lots := range replay.taxLots {
+		sortTaxLots(lots, models.CostBasisFIFO)
+	}
+
+	result := &WhatIfResult{
+		PortfolioID:       portfolioID,
+		CostBasisMethod:   portfolio.CostBasisMethod,
+		RealizedGains:     []*dto.RealizedGainResponse{},
+		CashBalanceBefore: req.CashBalance,
+		CashChange:        decimal.Zero,
+	}
+
+	today := s.now()
+	tradePrices := make(map[string]decimal.Decimal)
+	for _, trade := range req.Trades {
+		price := trade.Price
+		tx := &models.Transaction{
+			PortfolioID: portfolio.ID,
+			Type:        trade.Type,
+			Symbol:      strings.TrimSpace(trade.Symbol),
+			Date:        today,
+			Quantity:    trade.Quantity,
+			Price:       &price,
+			Commission:  trade.Commission,
+			Multiplier:  1,
+		}
+		if trade.Date != nil {
+			tx.Date = *trade.Date
+		}
+		if err := tx.Validate(); err != nil {
+			return nil, err
+		}
+
+		if tx.IsSell() {
+			holding, ok := replay.holdings[tx.Symbol]
+			if !ok || holding.Quantity.LessThan(tx.Quantity) {
+				return nil, models.ErrInsufficientShares
+			}
+			tx.Multiplier = holding.Multiplier
+			for _, gain := range replay.saleGains(tx) {
+				result.RealizedGains = append(result.RealizedGains, toRealizedGainResponse(gain))
+				if gain.IsLongTerm {
+					result.LongTermGain = result.LongTermGain.Add(gain.Gain)
+				} else {
+					result.ShortTermGain = result.ShortTermGain.Add(gain.Gain)
+				}
+			}
+			result.CashChange = result.CashChange.Add(tx.GetProceeds())
+		} else {
+			if holding, ok := replay.holdings[tx.Symbol]; ok && holding.Multiplier > 1 {
+				tx.Multiplier = holding.Multiplier
+			}
+			result.CashChange = result.CashChange.Sub(tx.GetTotalCost())
+		}
+		if err := replay.apply(tx); err != nil {
+			return nil, err
+		}
+		tradePrices[tx.Symbol] = price
+	}
+
+	result.TotalGain = result.ShortTermGain.Add(result.LongTermGain)
+	result.EstimatedTax = s.rounding.RoundAmount(estimatedTax(result.ShortTermGain, result.LongTermGain, req.ShortTermTaxRate, req.LongTermTaxRate))
+	result.CashBalanceAfter = result.CashBalanceBefore.Add(result.CashChange)
+
+	result.Positions = s.positions(ctx, before, replay.holdings, tradePrices, req.TargetWeights)
+	s.weigh(result, req.TargetWeights)
+
+	return result, nil
+}
+
+// positions lines up the positions before and after the trades, by symbol, including the
+// symbols with a target weight that aren't held
+func (s *whatIfService) positions(
+	ctx context.Context,
+	before, after map[string]*models.Holding,
+	tradePrices map[string]decimal.Decimal,
+	targetWeights map[string]decimal.Decimal,
+) []*dto.WhatIfPosition {
+	symbolSet := make(map[string]bool)
+	for symbol := range before {
+		symbolSet[symbol] = true
+	}
+	for symbol := range after {
+		symbolSet[symbol] = true
+	}
+	for symbol := range targetWeights {
+		symbolSet[symbol] = true
+	}
+	symbols := make([]string, 0, len(symbolSet))
+	var unpriced []string
+	for symbol := range symbolSet {
+		symbols = append(symbols, symbol)
+		if _, ok := tradePrices[symbol]; !ok {
+			unpriced = append(unpriced, symbol)
+		}
+	}
+	sort.Strings(symbols)
+	sort.Strings(unpriced)
+	quotes := s.prices(ctx, unpriced)
+
+	positions := make([]*dto.WhatIfPosition, 0, len(symbols))
+	for _, symbol := range symbols {
+		position := &dto.WhatIfPosition{
+			Symbol:         symbol,
+			QuantityBefore: decimal.Zero,
+			QuantityAfter:  decimal.Zero,
+			ValueBefore:    decimal.Zero,
+			ValueAfter:     decimal.Zero,
+		}
+
+		multiplier := decimal.NewFromInt(1)
+		var avgCostPrice decimal.Decimal
+		for _, holding := range []*models.Holding{before[symbol], after[symbol]} {
+			if holding == nil {
+				continue
+			}
+			avgCostPrice = holding.AvgCostPrice
+			if holding.Multiplier > 1 {
+				multiplier = decimal.NewFromInt(int64(holding.Multiplier))
+			}
+		}
+
+		if price, ok := tradePrices[symbol]; ok {
+			position.Price = price
+		} else if price, ok := quotes[symbol]; ok {
+			position.Price = price
+		} else {
+			position.Price = avgCostPrice
+			position.PricedAtCost = true
+		}
+
+		if holding := before[symbol]; holding != nil {
+			position.QuantityBefore = holding.Quantity
+			position.ValueBefore = s.rounding.RoundAmount(holding.Quantity.Mul(position.Price).Mul(multiplier))
+		}
+		if holding := after[symbol]; holding != nil {
+			position.QuantityAfter = holding.Quantity
+			position.ValueAfter = s.rounding.RoundAmount(holding.Quantity.Mul(position.Price).Mul(multiplier))
+		}
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+// weigh adds up the value of the positions and cash before and after the trades and works
+// out their weights and drifts from the target weights
+func (s *whatIfService) weigh(result *WhatIfResult, targetWeights map[string]decimal.Decimal) {
+	result.TotalValueBefore = result.CashBalanceBefore
+	result.TotalValueAfter = result.CashBalanceAfter
+	for _, position := range result.Positions {
+		result.TotalValueBefore = result.TotalValueBefore.Add(position.ValueBefore)
+		result.TotalValueAfter = result.TotalValueAfter.Add(position.ValueAfter)
+	}
+
+	result.CashWeightBefore = percentOf(result.CashBalanceBefore, result.TotalValueBefore).Round(2)
+	result.CashWeightAfter = percentOf(result.CashBalanceAfter, result.TotalValueAfter).Round(2)
+
+	totalDriftBefore, totalDriftAfter := decimal.Zero, decimal.Zero
+	for _, position := range result.Positions {
+		position.WeightBefore = percentOf(position.ValueBefore, result.TotalValueBefore).Round(2)
+		position.WeightAfter = percentOf(position.ValueAfter, result.TotalValueAfter).Round(2)
+		position.WeightChange = position.WeightAfter.Sub(position.WeightBefore)
+
+		target, ok := targetWeights[position.Symbol]
+		if !ok {
+			continue
+		}
+		driftBefore := position.WeightBefore.Sub(target)
+		driftAfter := position.WeightAfter.Sub(target)
+		position.TargetWeight = &target
+		position.DriftBefore = &driftBefore
+		position.DriftAfter = &driftAfter
+		totalDriftBefore = totalDriftBefore.Add(driftBefore.Abs())
+		totalDriftAfter = totalDriftAfter.Add(driftAfter.Abs())
+	}
+
+	if len(targetWeights) > 0 {
+		result.TotalDriftBefore = &totalDriftBefore
+		result.TotalDriftAfter = &totalDriftAfter
+	}
+}
+
+// prices returns the latest prices of the symbols that have a quote
+func (s *whatIfService) prices(ctx context.Context, symbols []string) map[string]decimal.Decimal {
+	prices := make(map[string]decimal.Decimal)
+	if s.marketData == nil || len(symbols) == 0 {
+		return prices
+	}
+
+	quotes, err := s.marketData.GetQuotes(ctx, symbols)
+	if err != nil {
+		return prices
+	}
+	for symbol, quote := range quotes {
+		prices[symbol] = quote.Price
+	}
+	return prices
+}
+
+// estimatedTax applies the tax rates, as percentages, to the net short- and long-term gains
+// after a net loss of one kind offsets gains of the other
+func estimatedTax(shortTermGain, longTermGain, shortTermRate, longTermRate decimal.Decimal) decimal.Decimal {
+	if shortTermGain.IsNegative() && longTermGain.IsPositive() {
+		longTermGain = decimal.Max(decimal.Zero, longTermGain.Add(shortTermGain))
+	}
+	if longTermGain.IsNegative() && shortTermGain.IsPositive() {
+		shortTermGain = decimal.Max(decimal.Zero, shortTermGain.Add(longTermGain))
+	}
+
+	tax := decimal.Zero
+	if shortTermGain.IsPositive() {
+		tax = tax.Add(shortTermGain.Mul(shortTermRate).Div(decimal.NewFromInt(100)))
+	}
+	if longTermGain.IsPositive() {
+		tax = tax.Add(longTermGain.Mul(longTermRate).Div(decimal.NewFromInt(100)))
+	}
+	return tax
+}
+
+// toRealizedGainResponse converts a realized gain to its response
+func toRealizedGainResponse(gain *RealizedGain) *dto.RealizedGainResponse {
+	return &dto.RealizedGainResponse{
+		Symbol:       gain.Symbol,
+		PurchaseDate: gain.PurchaseDate,
+		SaleDate:     gain.SaleDate,
+		Quantity:     gain.Quantity,
+		CostBasis:    gain.CostBasis,
+		Proceeds:     gain.Proceeds,
+		Gain:         gain.Gain,
+		IsLongTerm:   gain.IsLongTerm,
+	}
+}
+
+// validateWhatIf checks the amounts, rates and target weights of a what-if request. Each
+// trade is checked as a transaction when it is applied.
+func validateWhatIf(req *dto.WhatIfRequest) error {
+	hundred := decimal.NewFromInt(100)
+	for _, rate := range []decimal.Decimal{req.ShortTermTaxRate, req.LongTermTaxRate} {
+		if rate.IsNegative() || rate.GreaterThan(hundred) {
+			return models.ErrInvalidWhatIf
+		}
+	}
+
+	total := decimal.Zero
+	for _, target := range req.TargetWeights {
+		if target.IsNegative() || target.GreaterThan(hundred) {
+			return models.ErrInvalidWhatIf
+		}
+		total = total.Add(target)
+	}
+	if total.GreaterThan(hundred) {
+		return models.ErrInvalidWhatIf
+	}
+
+	for _, trade := range req.Trades {
+		if !trade.Quantity.IsPositive() || !trade.Price.IsPositive() || trade.Commission.IsNegative() {
+			return models.ErrInvalidWhatIf
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+)
+
+var whatIfTestNow = time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+// setupWhatIfTest creates a portfolio holding 10 AAPL in a long-term lot of 5 at 80 and a
+// short-term lot of 5 at 120, and 10 MSFT at 50
+func setupWhatIfTest(t *testing.T, method models.CostBasisMethod) (*gorm.DB, *models.Portfolio, *whatIfService) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.Portfolio{}, &models.Holding{}, &models.TaxLot{}))
+
+	user := &models.User{Email: "trader@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(user).Error)
+	portfolio := &models.Portfolio{UserID: user.ID, Name: "Brokerage", BaseCurrency: "USD", CostBasisMethod: method}
+	require.NoError(t, db.Create(portfolio).Error)
+
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID: portfolio.ID, Symbol: "AAPL",
+		Quantity: decimal.NewFromInt(10), CostBasis: decimal.NewFromInt(1000), AvgCostPrice: decimal.NewFromInt(100),
+	}).Error)
+	require.NoError(t, db.Create(&models.Holding{
+		PortfolioID: portfolio.ID, Symbol: "MSFT",
+		Quantity: decimal.NewFromInt(10), CostBasis: decimal.NewFromInt(500), AvgCostPrice: decimal.NewFromInt(50),
+	}).Error)
+	for _, lot := range []*models.TaxLot{
+		{Symbol: "AAPL", PurchaseDate: time.Date(2023, 1, 10, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(5), CostBasis: decimal.NewFromInt(400)},
+		{Symbol: "AAPL", PurchaseDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(5), CostBasis: decimal.NewFromInt(600)},
+		{Symbol: "MSFT", PurchaseDate: time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC), Quantity: decimal.NewFromInt(10), CostBasis: decimal.NewFromInt(500)},
+	} {
+		lot.PortfolioID = portfolio.ID
+		lot.TransactionID = uuid.New()
+		require.NoError(t, db.Create(lot).Error)
+	}
+
+	service := NewWhatIfService(
+		repository.NewPortfolioRepository(db),
+		repository.NewHoldingRepository(db),
+		repository.NewTaxLotRepository(db),
+		nil,
+		models.DefaultRoundingPolicy(),
+	).(*whatIfService)
+	service.now = func() time.Time { return whatIfTestNow }
+
+	return db, portfolio, service
+}
+
+func TestWhatIfService_SaleFIFO(t *testing.T) {
+	db, portfolio, service := setupWhatIfTest(t, models.CostBasisFIFO)
+	ctx := context.Background()
+
+	result, err := service.Analyze(ctx, portfolio.ID.String(), portfolio.UserID.String(), &dto.WhatIfRequest{
+		Trades: []dto.WhatIfTrade{
+			{Type: models.TransactionTypeSell, Symbol: "AAPL", Quantity: decimal.NewFromInt(7), Price: decimal.NewFromInt(150)},
+		},
+		ShortTermTaxRate: decimal.NewFromInt(30),
+		LongTermTaxRate:  decimal.NewFromInt(15),
+		TargetWeights:    map[string]decimal.Decimal{"AAPL": decimal.NewFromInt(40), "MSFT": decimal.NewFromInt(40)},
+	})
+	require.NoError(t, err)
+
+	// The whole long-term lot goes first, then 2 shares of the short-term one
+	require.Len(t, result.RealizedGains, 2)
+	assert.True(t, result.RealizedGains[0].IsLongTerm)
+	assert.True(t, result.RealizedGains[0].Gain.Equal(decimal.NewFromInt(350)))
+	assert.False(t, result.RealizedGains[1].IsLongTerm)
+	assert.True(t, result.RealizedGains[1].CostBasis.Equal(decimal.NewFromInt(240)))
+	assert.True(t, result.LongTermGain.Equal(decimal.NewFromInt(350)))
+	assert.True(t, result.ShortTermGain.Equal(decimal.NewFromInt(60)))
+	assert.True(t, result.EstimatedTax.Equal(decimal.NewFromFloat(70.5)), result.EstimatedTax.String())
+	assert.True(t, result.CashBalanceAfter.Equal(decimal.NewFromInt(1050)))
+
+	// AAPL is valued at the trade price and MSFT, without a quote, at cost
+	require.Len(t, result.Positions, 2)
+	aapl, msft := result.Positions[0], result.Positions[1]
+	assert.True(t, aapl.QuantityAfter.Equal(decimal.NewFromInt(3)))
+	assert.True(t, aapl.WeightBefore.Equal(decimal.NewFromInt(75)), aapl.WeightBefore.String())
+	assert.True(t, aapl.WeightAfter.Equal(decimal.NewFromFloat(22.5)), aapl.WeightAfter.String())
+	assert.True(t, msft.PricedAtCost)
+	assert.True(t, result.CashWeightAfter.Equal(decimal.NewFromFloat(52.5)))
+	require.NotNil(t, result.TotalDriftBefore)
+	assert.True(t, result.TotalDriftBefore.Equal(decimal.NewFromInt(50)), result.TotalDriftBefore.String())
+	assert.True(t, result.TotalDriftAfter.Equal(decimal.NewFromFloat(32.5)), result.TotalDriftAfter.String())
+
+	// Nothing is recorded
+	var holding models.Holding
+	require.NoError(t, db.Where("portfolio_id = ? AND symbol = ?", portfolio.ID, "AAPL").First(&holding).Error)
+	assert.True(t, holding.Quantity.Equal(decimal.NewFromInt(10)))
+	var lots int64
+	require.NoError(t, db.Model(&models.TaxLot{}).Where("portfolio_id = ?", portfolio.ID).Count(&lots).Error)
+	assert.Equal(t, int64(3), lots)
+}
+
+func TestWhatIfService_SaleLIFO(t *testing.T) {
+	_, portfolio, service := setupWhatIfTest(t, models.CostBasisLIFO)
+
+	result, err := service.Analyze(context.Background(), portfolio.ID.String(), portfolio.UserID.String(), &dto.WhatIfRequest{
+		Trades: []dto.WhatIfTrade{
+			{Type: models.TransactionTypeSell, Symbol: "AAPL", Quantity: decimal.NewFromInt(7), Price: decimal.NewFromInt(150)},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, result.ShortTermGain.Equal(decimal.NewFromInt(150)))
+	assert.True(t, result.LongTermGain.Equal(decimal.NewFromInt(140)))
+	assert.True(t, result.EstimatedTax.IsZero())
+}
+
+func TestWhatIfService_BuyThenSell(t *testing.T) {
+	_, portfolio, service := setupWhatIfTest(t, models.CostBasisFIFO)
+
+	result, err := service.Analyze(context.Background(), portfolio.ID.String(), portfolio.UserID.String(), &dto.WhatIfRequest{
+		Trades: []dto.WhatIfTrade{
+			{Type: models.TransactionTypeBuy, Symbol: "NVDA", Quantity: decimal.NewFromInt(5), Price: decimal.NewFromInt(10), Commission: decimal.NewFromInt(1)},
+			{Type: models.TransactionTypeSell, Symbol: "NVDA", Quantity: decimal.NewFromInt(2), Price: decimal.NewFromInt(12)},
+		},
+		CashBalance: decimal.NewFromInt(100),
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.RealizedGains, 1)
+	assert.True(t, result.ShortTermGain.Equal(decimal.NewFromFloat(3.6)), result.ShortTermGain.String())
+	assert.True(t, result.CashChange.Equal(decimal.NewFromInt(-27)), result.CashChange.String())
+	assert.True(t, result.CashBalanceAfter.Equal(decimal.NewFromInt(73)))
+
+	require.Len(t, result.Positions, 3)
+	nvda := result.Positions[2]
+	assert.True(t, nvda.QuantityBefore.IsZero())
+	assert.True(t, nvda.QuantityAfter.Equal(decimal.NewFromInt(3)))
+	assert.True(t, nvda.ValueAfter.Equal(decimal.NewFromInt(36)))
+}
+
+func TestWhatIfService_InvalidRequests(t *testing.T) {
+	db, portfolio, service := setupWhatIfTest(t, models.CostBasisFIFO)
+	ctx := context.Background()
+	portfolioID := portfolio.ID.String()
+	userID := portfolio.UserID.String()
+
+	sell := func(symbol string, quantity int64) []dto.WhatIfTrade {
+		return []dto.WhatIfTrade{{Type: models.TransactionTypeSell, Symbol: symbol, Quantity: decimal.NewFromInt(quantity), Price: decimal.NewFromInt(10)}}
+	}
+
+	tests := []struct {
+		name string
+		req  dto.WhatIfRequest
+		err  error
+	}{
+		{"selling more than held", dto.WhatIfRequest{Trades: sell("AAPL", 11)}, models.ErrInsufficientShares},
+		{"selling what isn't held", dto.WhatIfRequest{Trades: sell("TSLA", 1)}, models.ErrInsufficientShares},
+		{"tax rate above 100", dto.WhatIfRequest{Trades: sell("AAPL", 1), LongTermTaxRate: decimal.NewFromInt(101)}, models.ErrInvalidWhatIf},
+		{"targets above 100", dto.WhatIfRequest{Trades: sell("AAPL", 1), TargetWeights: map[string]decimal.Decimal{
+			"AAPL": decimal.NewFromInt(60), "MSFT": decimal.NewFromInt(50),
+		}}, models.ErrInvalidWhatIf},
+		{"no price", dto.WhatIfRequest{Trades: []dto.WhatIfTrade{{Type: models.TransactionTypeBuy, Symbol: "AAPL", Quantity: decimal.NewFromInt(1)}}}, models.ErrInvalidWhatIf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Analyze(ctx, portfolioID, userID, &tt.req)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+
+	stranger := &models.User{Email: "stranger@example.com", PasswordHash: "hash"}
+	require.NoError(t, db.Create(stranger).Error)
+	_, err := service.Analyze(ctx, portfolioID, stranger.ID.String(), &dto.WhatIfRequest{Trades: sell("AAPL", 1)})
+	assert.Equal(t, models.ErrUnauthorizedAccess, err)
+}
+
+func TestEstimatedTax(t *testing.T) {
+	shortTermRate, longTermRate := decimal.NewFromInt(30), decimal.NewFromInt(15)
+
+	// A short-term loss offsets long-term gains
+	tax := estimatedTax(decimal.NewFromInt(-100), decimal.NewFromInt(300), shortTermRate, longTermRate)
+	assert.True(t, tax.Equal(decimal.NewFromInt(30)), tax.String())
+
+	// A long-term loss offsets short-term gains
+	tax = estimatedTax(decimal.NewFromInt(200), decimal.NewFromInt(-300), shortTermRate, longTermRate)
+	assert.True(t, tax.IsZero(), tax.String())
+}
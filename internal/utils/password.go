@@ -1,9 +1,15 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 	"unicode"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -14,22 +20,109 @@ const (
 	BcryptCost = 12
 )
 
-// HashPassword hashes a password using bcrypt with cost factor 12
-func HashPassword(password string) (string, error) {
+// PasswordHashAlgorithm is the algorithm new password hashes are created with
+type PasswordHashAlgorithm string
+
+const (
+	// PasswordHashArgon2id hashes passwords with Argon2id, encoded in the PHC string format
+	PasswordHashArgon2id PasswordHashAlgorithm = "argon2id"
+	// PasswordHashBcrypt hashes passwords with bcrypt
+	PasswordHashBcrypt PasswordHashAlgorithm = "bcrypt"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	// argon2MinMemory is the least memory, in KiB, an Argon2id hash may use
+	argon2MinMemory = 8 * 1024
+)
+
+// ErrInvalidPasswordHasher is returned for unusable password hashing settings
+var ErrInvalidPasswordHasher = errors.New("invalid password hashing settings")
+
+// PasswordHasher hashes passwords with an algorithm and its parameters. It verifies hashes of
+// either algorithm, whatever it was configured with, so hashes can be migrated as users sign
+// in.
+type PasswordHasher struct {
+	Algorithm PasswordHashAlgorithm
+	// Argon2Memory is the memory an Argon2id hash uses, in KiB
+	Argon2Memory uint32
+	// Argon2Iterations is the number of passes Argon2id makes over the memory
+	Argon2Iterations uint32
+	// Argon2Parallelism is the number of threads an Argon2id hash uses
+	Argon2Parallelism uint8
+	// BcryptCost is the cost factor of bcrypt hashes
+	BcryptCost int
+}
+
+// DefaultPasswordHasher hashes passwords with Argon2id using 64 MiB of memory, 3 iterations
+// and 4 threads, one of the settings recommended by RFC 9106
+func DefaultPasswordHasher() PasswordHasher {
+	return PasswordHasher{
+		Algorithm:         PasswordHashArgon2id,
+		Argon2Memory:      64 * 1024,
+		Argon2Iterations:  3,
+		Argon2Parallelism: 4,
+		BcryptCost:        BcryptCost,
+	}
+}
+
+// Validate checks if the hasher is usable
+func (h PasswordHasher) Validate() error {
+	switch h.Algorithm {
+	case PasswordHashArgon2id:
+		if h.Argon2Memory < argon2MinMemory {
+			return fmt.Errorf("%w: %s needs at least %d KiB of memory", ErrInvalidPasswordHasher, h, argon2MinMemory)
+		}
+		if h.Argon2Iterations < 1 || h.Argon2Parallelism < 1 {
+			return fmt.Errorf("%w: %s needs at least one iteration and one thread", ErrInvalidPasswordHasher, h)
+		}
+	case PasswordHashBcrypt:
+		if h.BcryptCost < bcrypt.MinCost || h.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("%w: %s needs a cost between %d and %d", ErrInvalidPasswordHasher, h, bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("%w: algorithm must be %s or %s, got %q", ErrInvalidPasswordHasher, PasswordHashArgon2id, PasswordHashBcrypt, h.Algorithm)
+	}
+	return nil
+}
+
+// String describes the algorithm and parameters new hashes are created with
+func (h PasswordHasher) String() string {
+	if h.Algorithm == PasswordHashBcrypt {
+		return fmt.Sprintf("bcrypt (cost=%d)", h.BcryptCost)
+	}
+	return fmt.Sprintf("%s (m=%d, t=%d, p=%d)", h.Algorithm, h.Argon2Memory, h.Argon2Iterations, h.Argon2Parallelism)
+}
+
+// Hash hashes a password with the hasher's algorithm and parameters
+func (h PasswordHasher) Hash(password string) (string, error) {
 	if password == "" {
 		return "", fmt.Errorf("password cannot be empty")
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), BcryptCost)
-	if err != nil {
+	if h.Algorithm == PasswordHashBcrypt {
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), h.BcryptCost)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash password: %w", err)
+		}
+		return string(hashedPassword), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
 	}
+	key := argon2.IDKey([]byte(password), salt, h.Argon2Iterations, h.Argon2Memory, h.Argon2Parallelism, argon2KeyLength)
 
-	return string(hashedPassword), nil
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Argon2Memory, h.Argon2Iterations, h.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
-// CheckPassword verifies if the provided password matches the stored hash
-func CheckPassword(password, hash string) error {
+// Check verifies if the password matches a hash of either algorithm
+func (h PasswordHasher) Check(password, hash string) error {
 	if password == "" {
 		return fmt.Errorf("password cannot be empty")
 	}
@@ -37,14 +130,82 @@ func CheckPassword(password, hash string) error {
 		return fmt.Errorf("hash cannot be empty")
 	}
 
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if !strings.HasPrefix(hash, "$argon2id$") {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err != nil {
+			return fmt.Errorf("password does not match: %w", err)
+		}
+		return nil
+	}
+
+	params, salt, key, err := decodeArgon2Hash(hash)
 	if err != nil {
 		return fmt.Errorf("password does not match: %w", err)
 	}
-
+	derived := argon2.IDKey([]byte(password), salt, params.Argon2Iterations, params.Argon2Memory, params.Argon2Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(derived, key) != 1 {
+		return fmt.Errorf("password does not match")
+	}
 	return nil
 }
 
+// NeedsRehash reports whether a hash was created with another algorithm or other parameters
+// than the hasher's, so it should be replaced the next time the password is known
+func (h PasswordHasher) NeedsRehash(hash string) bool {
+	if h.Algorithm == PasswordHashBcrypt {
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != h.BcryptCost
+	}
+
+	params, _, _, err := decodeArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+	return params.Argon2Memory != h.Argon2Memory ||
+		params.Argon2Iterations != h.Argon2Iterations ||
+		params.Argon2Parallelism != h.Argon2Parallelism
+}
+
+// decodeArgon2Hash parses an Argon2id hash in the PHC string format
+func decodeArgon2Hash(hash string) (PasswordHasher, []byte, []byte, error) {
+	params := PasswordHasher{Algorithm: PasswordHashArgon2id}
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Argon2Memory, &params.Argon2Iterations, &params.Argon2Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2 key")
+	}
+
+	return params, salt, key, nil
+}
+
+// HashPassword hashes a password with the default hasher
+func HashPassword(password string) (string, error) {
+	return DefaultPasswordHasher().Hash(password)
+}
+
+// CheckPassword verifies if the provided password matches the stored hash, of either
+// algorithm
+func CheckPassword(password, hash string) error {
+	return DefaultPasswordHasher().Check(password, hash)
+}
+
 // ValidatePassword checks if a password meets the required complexity rules
 // Requirements: minimum 8 characters, at least one uppercase, one lowercase, one number
 func ValidatePassword(password string) error {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -14,11 +15,8 @@ func TestHashPassword(t *testing.T) {
 		hash, err := HashPassword(password)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, hash)
-		assert.True(t, strings.HasPrefix(hash, "$2a$"))
-
-		// Verify it's a valid bcrypt hash
-		err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=4$"))
+		assert.NoError(t, CheckPassword(password, hash))
 	})
 
 	t.Run("empty password error", func(t *testing.T) {
@@ -78,6 +76,53 @@ func TestCheckPassword(t *testing.T) {
 	})
 }
 
+func TestPasswordHasher_Validate(t *testing.T) {
+	assert.NoError(t, DefaultPasswordHasher().Validate())
+	assert.NoError(t, PasswordHasher{Algorithm: PasswordHashBcrypt, BcryptCost: 10}.Validate())
+
+	invalid := []PasswordHasher{
+		{Algorithm: "scrypt"},
+		{Algorithm: PasswordHashArgon2id, Argon2Memory: 1024, Argon2Iterations: 3, Argon2Parallelism: 1},
+		{Algorithm: PasswordHashArgon2id, Argon2Memory: 65536, Argon2Iterations: 0, Argon2Parallelism: 1},
+		{Algorithm: PasswordHashBcrypt, BcryptCost: 40},
+	}
+	for _, hasher := range invalid {
+		assert.ErrorIs(t, hasher.Validate(), ErrInvalidPasswordHasher, hasher.String())
+	}
+
+	// Errors name the settings in use
+	assert.ErrorContains(t, invalid[1].Validate(), "argon2id (m=1024, t=3, p=1)")
+}
+
+func TestPasswordHasher_MigratesBcrypt(t *testing.T) {
+	password := "TestPassword123"
+	bcryptHasher := PasswordHasher{Algorithm: PasswordHashBcrypt, BcryptCost: bcrypt.MinCost}
+	legacyHash, err := bcryptHasher.Hash(password)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(legacyHash, "$2a$"))
+
+	hasher := PasswordHasher{Algorithm: PasswordHashArgon2id, Argon2Memory: 8 * 1024, Argon2Iterations: 1, Argon2Parallelism: 1}
+
+	// Either algorithm's hashes are verified
+	assert.NoError(t, hasher.Check(password, legacyHash))
+	assert.Error(t, hasher.Check("WrongPassword123", legacyHash))
+	assert.True(t, hasher.NeedsRehash(legacyHash))
+
+	hash, err := hasher.Hash(password)
+	require.NoError(t, err)
+	assert.NoError(t, hasher.Check(password, hash))
+	assert.NoError(t, bcryptHasher.Check(password, hash))
+	assert.Error(t, hasher.Check("WrongPassword123", hash))
+	assert.False(t, hasher.NeedsRehash(hash))
+
+	// Changing the parameters asks for a rehash too
+	stronger := hasher
+	stronger.Argon2Iterations = 2
+	assert.True(t, stronger.NeedsRehash(hash))
+	assert.True(t, bcryptHasher.NeedsRehash(hash))
+	assert.False(t, bcryptHasher.NeedsRehash(legacyHash))
+}
+
 func TestValidatePassword(t *testing.T) {
 	t.Run("valid password", func(t *testing.T) {
 		err := ValidatePassword("ValidPass123")
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SecretBoxKeyLength is the length, in bytes, of the keys secret boxes encrypt with
+const SecretBoxKeyLength = 32
+
+// ErrInvalidSecretBoxKey is returned for keys that aren't 32 bytes, hex or base64 encoded
+var ErrInvalidSecretBoxKey = errors.New("encryption key must be 32 bytes, hex or base64 encoded")
+
+// SecretBox encrypts secrets stored in the database, such as the access tokens of broker
+// connections, with AES-256-GCM. Sealed secrets are base64 encoded and start with their
+// random nonce, so sealing the same secret twice gives different results.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox creates a secret box from a 32-byte key given as 64 hex characters or in
+// standard base64
+func NewSecretBox(key string) (*SecretBox, error) {
+	key = strings.TrimSpace(key)
+	raw, err := hex.DecodeString(key)
+	if err != nil || len(raw) != SecretBoxKeyLength {
+		raw, err = base64.StdEncoding.DecodeString(key)
+	}
+	if err != nil || len(raw) != SecretBoxKeyLength {
+		return nil, ErrInvalidSecretBoxKey
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal encrypts a secret
+func (b *SecretBox) Seal(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a secret sealed with the same key. It fails for secrets sealed with another
+// key and for secrets that were changed after sealing.
+func (b *SecretBox) Open(sealed string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return "", fmt.Errorf("failed to decrypt secret: malformed ciphertext")
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecretBoxKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func TestNewSecretBox(t *testing.T) {
+	t.Run("hex key", func(t *testing.T) {
+		_, err := NewSecretBox(testSecretBoxKey)
+		assert.NoError(t, err)
+	})
+
+	t.Run("base64 key", func(t *testing.T) {
+		_, err := NewSecretBox(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32))))
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		for _, key := range []string{"", "not-a-key", testSecretBoxKey[:62], base64.StdEncoding.EncodeToString([]byte("short"))} {
+			_, err := NewSecretBox(key)
+			assert.ErrorIs(t, err, ErrInvalidSecretBoxKey, key)
+		}
+	})
+}
+
+func TestSecretBox_SealAndOpen(t *testing.T) {
+	box, err := NewSecretBox(testSecretBoxKey)
+	require.NoError(t, err)
+
+	sealed, err := box.Seal("access-sandbox-123")
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "access-sandbox")
+
+	opened, err := box.Open(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "access-sandbox-123", opened)
+
+	// Each seal uses a new nonce
+	again, err := box.Seal("access-sandbox-123")
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed, again)
+
+	t.Run("other key", func(t *testing.T) {
+		other, err := NewSecretBox(strings.Repeat("ff", 32))
+		require.NoError(t, err)
+		_, err = other.Open(sealed)
+		assert.Error(t, err)
+	})
+
+	t.Run("tampered ciphertext", func(t *testing.T) {
+		data, err := base64.StdEncoding.DecodeString(sealed)
+		require.NoError(t, err)
+		data[len(data)-1] ^= 1
+		_, err = box.Open(base64.StdEncoding.EncodeToString(data))
+		assert.Error(t, err)
+
+		_, err = box.Open("%%%")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,13 @@
+-- Restore transaction type constraint
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE'
+));
+
+-- Drop stock plan tables
+DROP INDEX IF EXISTS idx_stock_plan_events_portfolio_id;
+DROP INDEX IF EXISTS idx_stock_plan_events_grant_id;
+DROP TABLE IF EXISTS stock_plan_events;
+DROP INDEX IF EXISTS idx_stock_plan_grants_symbol;
+DROP INDEX IF EXISTS idx_stock_plan_grants_portfolio_id;
+DROP TABLE IF EXISTS stock_plan_grants;
@@ -0,0 +1,58 @@
+-- Create stock_plan_grants table
+CREATE TABLE IF NOT EXISTS stock_plan_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    grant_date TIMESTAMP NOT NULL,
+    total_shares NUMERIC(20, 8) NOT NULL,
+    vesting_start_date TIMESTAMP NOT NULL,
+    vesting_months INTEGER NOT NULL DEFAULT 0,
+    cliff_months INTEGER NOT NULL DEFAULT 0,
+    vesting_interval_months INTEGER NOT NULL DEFAULT 1,
+    strike_price NUMERIC(20, 8),
+    expiration_date TIMESTAMP,
+    discount_percent NUMERIC(10, 4),
+    lookback BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_stock_plan_type CHECK (type IN ('RSU', 'ESPP', 'ISO', 'NSO')),
+    CONSTRAINT chk_stock_plan_total_shares CHECK (total_shares > 0),
+    CONSTRAINT chk_stock_plan_vesting CHECK (vesting_months >= 0 AND cliff_months >= 0 AND vesting_interval_months >= 0),
+    CONSTRAINT chk_stock_plan_strike CHECK (strike_price IS NULL OR strike_price >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_plan_grants_portfolio_id ON stock_plan_grants(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_stock_plan_grants_symbol ON stock_plan_grants(symbol);
+
+-- Create stock_plan_events table
+CREATE TABLE IF NOT EXISTS stock_plan_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    grant_id UUID NOT NULL REFERENCES stock_plan_grants(id) ON DELETE CASCADE,
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    shares NUMERIC(20, 8) NOT NULL,
+    shares_withheld NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    fair_market_value NUMERIC(20, 8) NOT NULL,
+    purchase_price NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    cost_basis NUMERIC(20, 8) NOT NULL,
+    ordinary_income NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    bargain_element NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    amt_adjustment NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_stock_plan_event_type CHECK (type IN ('VEST', 'ESPP_PURCHASE', 'EXERCISE')),
+    CONSTRAINT chk_stock_plan_event_shares CHECK (shares > 0 AND shares_withheld >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_plan_events_grant_id ON stock_plan_events(grant_id);
+CREATE INDEX IF NOT EXISTS idx_stock_plan_events_portfolio_id ON stock_plan_events(portfolio_id);
+
+-- Allow stock plan acquisition transaction types
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+    'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE'
+));
@@ -0,0 +1,8 @@
+-- Drop blackout tables
+DROP INDEX IF EXISTS idx_blackout_overrides_window_id;
+DROP INDEX IF EXISTS idx_blackout_overrides_portfolio_id;
+DROP TABLE IF EXISTS blackout_overrides;
+DROP INDEX IF EXISTS idx_blackout_windows_portfolio_id;
+DROP TABLE IF EXISTS blackout_windows;
+DROP INDEX IF EXISTS idx_employer_stock_policies_portfolio_id;
+DROP TABLE IF EXISTS employer_stock_policies;
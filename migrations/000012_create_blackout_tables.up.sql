@@ -0,0 +1,44 @@
+-- Create employer_stock_policies table
+CREATE TABLE IF NOT EXISTS employer_stock_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    enforcement VARCHAR(10) NOT NULL DEFAULT 'WARN',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_blackout_enforcement CHECK (enforcement IN ('WARN', 'BLOCK'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employer_stock_policies_portfolio_id ON employer_stock_policies(portfolio_id);
+
+-- Create blackout_windows table
+CREATE TABLE IF NOT EXISTS blackout_windows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_blackout_window_dates CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blackout_windows_portfolio_id ON blackout_windows(portfolio_id);
+
+-- Create blackout_overrides audit table. Rows intentionally keep no foreign key to the
+-- window or transaction so the audit trail survives their deletion.
+CREATE TABLE IF NOT EXISTS blackout_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    window_id UUID NOT NULL,
+    transaction_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    transaction_type VARCHAR(20) NOT NULL,
+    transaction_date TIMESTAMP NOT NULL,
+    enforcement VARCHAR(10) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_blackout_overrides_portfolio_id ON blackout_overrides(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_blackout_overrides_window_id ON blackout_overrides(window_id);
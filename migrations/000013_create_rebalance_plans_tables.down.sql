@@ -0,0 +1,6 @@
+-- Drop rebalance plan tables
+DROP INDEX IF EXISTS idx_rebalance_plan_trades_plan_id;
+DROP TABLE IF EXISTS rebalance_plan_trades;
+DROP INDEX IF EXISTS idx_rebalance_plans_status;
+DROP INDEX IF EXISTS idx_rebalance_plans_portfolio_id;
+DROP TABLE IF EXISTS rebalance_plans;
@@ -0,0 +1,40 @@
+-- Create rebalance_plans table
+CREATE TABLE IF NOT EXISTS rebalance_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    stale_after_days INTEGER NOT NULL DEFAULT 7,
+    last_reminder_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_rebalance_plan_status CHECK (status IN ('ACTIVE', 'COMPLETED', 'CANCELLED')),
+    CONSTRAINT chk_rebalance_plan_stale_after_days CHECK (stale_after_days >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_plans_portfolio_id ON rebalance_plans(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_rebalance_plans_status ON rebalance_plans(status);
+
+-- Create rebalance_plan_trades table
+CREATE TABLE IF NOT EXISTS rebalance_plan_trades (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    plan_id UUID NOT NULL REFERENCES rebalance_plans(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    side VARCHAR(10) NOT NULL,
+    planned_quantity NUMERIC(20, 8) NOT NULL,
+    planned_price NUMERIC(20, 8) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    executed_quantity NUMERIC(20, 8),
+    executed_price NUMERIC(20, 8),
+    executed_at TIMESTAMP,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_rebalance_trade_side CHECK (side IN ('BUY', 'SELL')),
+    CONSTRAINT chk_rebalance_trade_status CHECK (status IN ('PENDING', 'EXECUTED', 'SKIPPED')),
+    CONSTRAINT chk_rebalance_trade_planned CHECK (planned_quantity > 0 AND planned_price > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_plan_trades_plan_id ON rebalance_plan_trades(plan_id);
@@ -0,0 +1,5 @@
+-- Drop peer benchmarks and opt-in column
+DROP INDEX IF EXISTS idx_peer_benchmarks_bucket;
+DROP TABLE IF EXISTS peer_benchmarks;
+DROP INDEX IF EXISTS idx_portfolios_peer_comparison_opt_in;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS peer_comparison_opt_in;
@@ -0,0 +1,23 @@
+-- Record each portfolio owner's consent to anonymized peer comparison
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS peer_comparison_opt_in BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_portfolios_peer_comparison_opt_in ON portfolios(peer_comparison_opt_in) WHERE peer_comparison_opt_in;
+
+-- Create peer_benchmarks table. Only quantiles of each allocation bucket's distribution are
+-- stored; nothing links a row back to the contributing portfolios.
+CREATE TABLE IF NOT EXISTS peer_benchmarks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bucket VARCHAR(20) NOT NULL,
+    sample_size INTEGER NOT NULL,
+    p10 NUMERIC(12, 6) NOT NULL,
+    p25 NUMERIC(12, 6) NOT NULL,
+    p50 NUMERIC(12, 6) NOT NULL,
+    p75 NUMERIC(12, 6) NOT NULL,
+    p90 NUMERIC(12, 6) NOT NULL,
+    computed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_peer_benchmark_bucket CHECK (bucket IN ('CONCENTRATED', 'FOCUSED', 'DIVERSIFIED')),
+    CONSTRAINT chk_peer_benchmark_sample_size CHECK (sample_size >= 10)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_peer_benchmarks_bucket ON peer_benchmarks(bucket);
@@ -0,0 +1,10 @@
+-- Drop API keys, organization membership columns and organizations
+DROP INDEX IF EXISTS idx_api_keys_user_id;
+DROP INDEX IF EXISTS idx_api_keys_key_hash;
+DROP INDEX IF EXISTS idx_api_keys_organization_external_id;
+DROP TABLE IF EXISTS api_keys;
+DROP INDEX IF EXISTS idx_users_organization_external_id;
+ALTER TABLE users DROP COLUMN IF EXISTS external_id;
+ALTER TABLE users DROP COLUMN IF EXISTS organization_id;
+DROP INDEX IF EXISTS idx_organizations_external_id;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,3 @@
+-- Drop the tenant schema column. Tenant schemas themselves are left in place.
+DROP INDEX IF EXISTS idx_organizations_schema_name;
+ALTER TABLE organizations DROP COLUMN IF EXISTS schema_name;
@@ -0,0 +1,5 @@
+-- Organizations created in multi-schema mode keep their portfolio data in their own schema.
+-- Tenant schemas are created and migrated from migrations/tenant, not by this directory.
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS schema_name VARCHAR(63);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_schema_name ON organizations(schema_name) WHERE schema_name IS NOT NULL;
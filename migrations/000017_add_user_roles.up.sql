@@ -0,0 +1,9 @@
+-- Users are either regular users or administrators of the admin user API. Administrators can
+-- disable accounts and force their owners to choose a new password.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_required BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users ADD CONSTRAINT chk_user_role CHECK (role IN ('user', 'admin'));
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users(role);
@@ -0,0 +1,23 @@
+-- Create report_subscriptions table: emailed performance digests a user has signed up for
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_report_subscription_frequency CHECK (frequency IN ('WEEKLY', 'MONTHLY'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_user_id ON report_subscriptions(user_id);
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_enabled ON report_subscriptions(enabled) WHERE enabled;
+
+-- Portfolios covered by a subscription; a subscription without any covers all of the user's
+CREATE TABLE IF NOT EXISTS report_subscription_portfolios (
+    subscription_id UUID NOT NULL REFERENCES report_subscriptions(id) ON DELETE CASCADE,
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, portfolio_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscription_portfolios_portfolio_id ON report_subscription_portfolios(portfolio_id);
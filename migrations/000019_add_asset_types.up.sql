@@ -0,0 +1,9 @@
+-- Positions are either equities or crypto assets. Crypto symbols are coin pairs such as
+-- BTC-USD, priced 24/7 by the crypto market data provider in the pair's quote currency.
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS asset_type VARCHAR(10) NOT NULL DEFAULT 'EQUITY';
+ALTER TABLE holdings ADD COLUMN IF NOT EXISTS asset_type VARCHAR(10) NOT NULL DEFAULT 'EQUITY';
+
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_asset_type CHECK (asset_type IN ('EQUITY', 'CRYPTO'));
+ALTER TABLE holdings ADD CONSTRAINT chk_holding_asset_type CHECK (asset_type IN ('EQUITY', 'CRYPTO'));
+
+CREATE INDEX IF NOT EXISTS idx_holdings_asset_type ON holdings(asset_type);
@@ -0,0 +1,35 @@
+-- Listed option contracts. Each is identified by its OCC symbol (underlying, expiry, call or
+-- put, strike), which is also the symbol of the transactions and holdings that trade it.
+CREATE TABLE IF NOT EXISTS option_contracts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    symbol VARCHAR(20) NOT NULL UNIQUE,
+    underlying VARCHAR(10) NOT NULL,
+    option_type VARCHAR(4) NOT NULL,
+    strike NUMERIC(20, 8) NOT NULL,
+    expiry DATE NOT NULL,
+    multiplier INTEGER NOT NULL DEFAULT 100,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_option_contract_type CHECK (option_type IN ('CALL', 'PUT')),
+    CONSTRAINT chk_option_contract_strike CHECK (strike > 0),
+    CONSTRAINT chk_option_contract_multiplier CHECK (multiplier > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_option_contracts_underlying ON option_contracts(underlying);
+CREATE INDEX IF NOT EXISTS idx_option_contracts_expiry ON option_contracts(expiry);
+
+-- Option quantities are contracts; the multiplier is how many shares each one covers
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS multiplier INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE holdings ADD COLUMN IF NOT EXISTS multiplier INTEGER NOT NULL DEFAULT 1;
+
+-- Allow option trades and settlements
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+    'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE',
+    'BUY_TO_OPEN', 'SELL_TO_CLOSE', 'OPTION_EXPIRATION', 'OPTION_ASSIGNMENT'
+));
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_asset_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_asset_type CHECK (asset_type IN ('EQUITY', 'CRYPTO', 'OPTION'));
+ALTER TABLE holdings DROP CONSTRAINT IF EXISTS chk_holding_asset_type;
+ALTER TABLE holdings ADD CONSTRAINT chk_holding_asset_type CHECK (asset_type IN ('EQUITY', 'CRYPTO', 'OPTION'));
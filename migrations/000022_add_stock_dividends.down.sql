@@ -0,0 +1,13 @@
+-- Restore transaction type and corporate action type constraints
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+    'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE',
+    'BUY_TO_OPEN', 'SELL_TO_CLOSE', 'OPTION_EXPIRATION', 'OPTION_ASSIGNMENT',
+    'RETURN_OF_CAPITAL'
+));
+
+ALTER TABLE corporate_actions DROP CONSTRAINT IF EXISTS chk_corporate_action_type;
+ALTER TABLE corporate_actions ADD CONSTRAINT chk_corporate_action_type CHECK (type IN (
+    'SPLIT', 'DIVIDEND', 'MERGER', 'SPINOFF', 'TICKER_CHANGE', 'RETURN_OF_CAPITAL'
+));
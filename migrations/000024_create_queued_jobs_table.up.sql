@@ -0,0 +1,25 @@
+-- Create queued_jobs table: heavy operations requested over the API and run by the worker pool.
+-- It lives in the public schema; tenant_schema records which schema a job's data is in.
+CREATE TABLE IF NOT EXISTS queued_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    portfolio_id UUID,
+    tenant_schema VARCHAR(63) NOT NULL DEFAULT '',
+    type VARCHAR(30) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED',
+    payload TEXT NOT NULL DEFAULT '{}',
+    progress TEXT,
+    result TEXT,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    heartbeat_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_queued_job_type CHECK (type IN ('CSV_IMPORT', 'TRACKER_IMPORT', 'RECALCULATION', 'TAX_REPORT')),
+    CONSTRAINT chk_queued_job_status CHECK (status IN ('QUEUED', 'RUNNING', 'SUCCEEDED', 'FAILED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_queued_jobs_user_id ON queued_jobs(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_queued_jobs_queued ON queued_jobs(created_at) WHERE status = 'QUEUED';
+CREATE INDEX IF NOT EXISTS idx_queued_jobs_running ON queued_jobs(heartbeat_at) WHERE status = 'RUNNING';
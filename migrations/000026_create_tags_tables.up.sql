@@ -0,0 +1,26 @@
+-- Create tags table: free-form labels users put on their portfolios and transactions
+CREATE TABLE IF NOT EXISTS tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_tags_user_id_name UNIQUE (user_id, name)
+);
+
+-- Portfolios carrying a tag
+CREATE TABLE IF NOT EXISTS portfolio_tags (
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (portfolio_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_tags_tag_id ON portfolio_tags(tag_id);
+
+-- Transactions carrying a tag
+CREATE TABLE IF NOT EXISTS transaction_tags (
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (transaction_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_tags_tag_id ON transaction_tags(tag_id);
@@ -0,0 +1,5 @@
+-- Drop portfolio group tables
+DROP INDEX IF EXISTS idx_portfolio_group_portfolios_portfolio_id;
+DROP TABLE IF EXISTS portfolio_group_portfolios;
+DROP INDEX IF EXISTS idx_portfolio_groups_parent_id;
+DROP TABLE IF EXISTS portfolio_groups;
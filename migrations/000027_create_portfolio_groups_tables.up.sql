@@ -0,0 +1,22 @@
+-- Create portfolio_groups table: named sets of a user's portfolios, optionally nested
+CREATE TABLE IF NOT EXISTS portfolio_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES portfolio_groups(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_portfolio_groups_user_id_name UNIQUE (user_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_groups_parent_id ON portfolio_groups(parent_id);
+
+-- Portfolios in a group; a portfolio can be in more than one group
+CREATE TABLE IF NOT EXISTS portfolio_group_portfolios (
+    group_id UUID NOT NULL REFERENCES portfolio_groups(id) ON DELETE CASCADE,
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, portfolio_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_group_portfolios_portfolio_id ON portfolio_group_portfolios(portfolio_id);
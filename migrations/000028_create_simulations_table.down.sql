@@ -0,0 +1,9 @@
+-- Drop simulations table and simulation jobs
+DELETE FROM queued_jobs WHERE type = 'SIMULATION';
+ALTER TABLE queued_jobs DROP CONSTRAINT IF EXISTS chk_queued_job_type;
+ALTER TABLE queued_jobs ADD CONSTRAINT chk_queued_job_type CHECK (type IN (
+    'CSV_IMPORT', 'TRACKER_IMPORT', 'RECALCULATION', 'TAX_REPORT'
+));
+
+DROP INDEX IF EXISTS idx_simulations_portfolio_id;
+DROP TABLE IF EXISTS simulations;
@@ -0,0 +1,22 @@
+-- Create simulations table: stored Monte Carlo withdrawal simulations, run as queued jobs.
+-- job_id is not a foreign key since finished jobs are deleted after a week.
+CREATE TABLE IF NOT EXISTS simulations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    job_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED',
+    parameters TEXT NOT NULL,
+    result TEXT,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    CONSTRAINT chk_simulation_status CHECK (status IN ('QUEUED', 'RUNNING', 'SUCCEEDED', 'FAILED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_simulations_portfolio_id ON simulations(portfolio_id, created_at);
+
+-- Allow simulation jobs
+ALTER TABLE queued_jobs DROP CONSTRAINT IF EXISTS chk_queued_job_type;
+ALTER TABLE queued_jobs ADD CONSTRAINT chk_queued_job_type CHECK (type IN (
+    'CSV_IMPORT', 'TRACKER_IMPORT', 'RECALCULATION', 'TAX_REPORT', 'SIMULATION'
+));
@@ -0,0 +1,3 @@
+-- Drop securities table
+DROP INDEX IF EXISTS idx_securities_name;
+DROP TABLE IF EXISTS securities;
@@ -0,0 +1,13 @@
+-- Create securities table: symbols found through the market data provider's symbol search,
+-- shared by all users
+CREATE TABLE IF NOT EXISTS securities (
+    symbol VARCHAR(40) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    exchange VARCHAR(100),
+    type VARCHAR(50),
+    currency VARCHAR(10),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_securities_name ON securities(UPPER(name));
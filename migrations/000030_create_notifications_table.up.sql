@@ -0,0 +1,19 @@
+-- Create notifications table: in-app messages about corporate actions, finished imports and
+-- scheduled reports. It lives in the public schema with the users; portfolio_id is not a
+-- foreign key since portfolios may be kept in a tenant schema.
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    portfolio_id UUID,
+    type VARCHAR(30) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_notification_type CHECK (type IN (
+        'CORPORATE_ACTION', 'IMPORT_COMPLETED', 'IMPORT_FAILED', 'REPORT_GENERATED'
+    ))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
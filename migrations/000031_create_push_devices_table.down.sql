@@ -0,0 +1,4 @@
+-- Drop push_devices table
+DROP INDEX IF EXISTS idx_push_devices_user_id;
+DROP INDEX IF EXISTS idx_push_devices_endpoint;
+DROP TABLE IF EXISTS push_devices;
@@ -0,0 +1,18 @@
+-- Create push_devices table: browsers and phones users registered for push notifications.
+-- It lives in the public schema with the users. endpoint is the Web Push subscription URL or
+-- the APNs device token, which identify a device whoever registers it.
+CREATE TABLE IF NOT EXISTS push_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(20) NOT NULL,
+    endpoint TEXT NOT NULL,
+    p256dh VARCHAR(128),
+    auth VARCHAR(64),
+    name VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP,
+    CONSTRAINT chk_push_device_platform CHECK (platform IN ('WEB_PUSH', 'APNS'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_push_devices_endpoint ON push_devices(endpoint);
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices(user_id);
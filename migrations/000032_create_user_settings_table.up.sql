@@ -0,0 +1,16 @@
+-- Create user_settings table: each user's display currency, time zone, locale, default cost
+-- basis method and notification preferences. It lives in the public schema with the users;
+-- users without a row use the defaults.
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    display_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    locale VARCHAR(35) NOT NULL DEFAULT 'en-US',
+    default_cost_basis_method VARCHAR(20) NOT NULL DEFAULT 'FIFO',
+    notify_corporate_actions BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_imports BOOLEAN NOT NULL DEFAULT TRUE,
+    notify_reports BOOLEAN NOT NULL DEFAULT TRUE,
+    push_notifications BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_user_settings_cost_basis_method CHECK (default_cost_basis_method IN ('FIFO', 'LIFO', 'SPECIFIC_LOT'))
+);
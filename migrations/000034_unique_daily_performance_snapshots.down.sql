@@ -0,0 +1,4 @@
+-- Restore the per-timestamp unique index. Snapshots removed by the up migration are not
+-- restored.
+DROP INDEX IF EXISTS idx_performance_snapshots_portfolio_day;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_performance_snapshots_portfolio_date ON performance_snapshots(portfolio_id, date);
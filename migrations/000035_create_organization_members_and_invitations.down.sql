@@ -0,0 +1,9 @@
+-- Drop organization memberships, invitations and organization-owned portfolios
+ALTER TABLE queued_jobs DROP COLUMN IF EXISTS organization_id;
+DROP INDEX IF EXISTS idx_portfolios_organization_id;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS organization_id;
+DROP INDEX IF EXISTS idx_organization_invitations_organization_id;
+DROP INDEX IF EXISTS idx_organization_invitations_token_hash;
+DROP TABLE IF EXISTS organization_invitations;
+DROP INDEX IF EXISTS idx_organization_members_user_id;
+DROP TABLE IF EXISTS organization_members;
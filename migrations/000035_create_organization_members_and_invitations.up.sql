@@ -0,0 +1,45 @@
+-- Create organization_members table: each user's role in the organizations they belong to.
+-- Owners and admins manage members, invitations and API keys; members and viewers work with
+-- the organization's portfolios, viewers read-only.
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id),
+    CONSTRAINT chk_organization_members_role CHECK (role IN ('owner', 'admin', 'member', 'viewer'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+-- Users provisioned into an organization are its members
+INSERT INTO organization_members (organization_id, user_id, role)
+SELECT organization_id, id, 'member' FROM users WHERE organization_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Create organization_invitations table. Only a SHA-256 hash of each invitation token is
+-- stored; accepting an invitation adds the user with that email as a member.
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    invited_by_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_organization_invitations_role CHECK (role IN ('owner', 'admin', 'member', 'viewer'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_invitations_token_hash ON organization_invitations(token_hash);
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_organization_id ON organization_invitations(organization_id);
+
+-- Portfolios created for an organization belong to it rather than to the member who
+-- created them
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_portfolios_organization_id ON portfolios(organization_id) WHERE organization_id IS NOT NULL;
+
+-- Queued jobs run in the organization they were requested in
+ALTER TABLE queued_jobs ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
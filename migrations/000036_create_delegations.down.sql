@@ -0,0 +1,10 @@
+-- Drop advisor delegations, their portfolios and audit trail
+ALTER TABLE queued_jobs DROP COLUMN IF EXISTS delegation_id;
+DROP INDEX IF EXISTS idx_delegation_audit_events_delegation_id;
+DROP TABLE IF EXISTS delegation_audit_events;
+DROP TABLE IF EXISTS delegation_portfolios;
+DROP INDEX IF EXISTS idx_delegations_client_email;
+DROP INDEX IF EXISTS idx_delegations_client_id;
+DROP INDEX IF EXISTS idx_delegations_advisor_id;
+DROP INDEX IF EXISTS idx_delegations_token_hash;
+DROP TABLE IF EXISTS delegations;
@@ -0,0 +1,54 @@
+-- Create delegations table: an advisor's access to some of a client's portfolios. The
+-- advisor asks for access by the client's email and the client consents with the token
+-- emailed to them; only a SHA-256 hash of the token is stored. client_id is set once the
+-- client consents.
+CREATE TABLE IF NOT EXISTS delegations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    advisor_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_email VARCHAR(255) NOT NULL,
+    client_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    access VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    approved_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_delegations_access CHECK (access IN ('read', 'manage')),
+    CONSTRAINT chk_delegations_status CHECK (status IN ('pending', 'active', 'revoked'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delegations_token_hash ON delegations(token_hash);
+CREATE INDEX IF NOT EXISTS idx_delegations_advisor_id ON delegations(advisor_id);
+CREATE INDEX IF NOT EXISTS idx_delegations_client_id ON delegations(client_id);
+CREATE INDEX IF NOT EXISTS idx_delegations_client_email ON delegations(client_email);
+
+-- Create delegation_portfolios table: the portfolios a client delegated. Portfolios may be
+-- kept in an organization's schema, so there is no foreign key to them.
+CREATE TABLE IF NOT EXISTS delegation_portfolios (
+    delegation_id UUID NOT NULL REFERENCES delegations(id) ON DELETE CASCADE,
+    portfolio_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (delegation_id, portfolio_id)
+);
+
+-- Create delegation_audit_events table: every request an advisor made on behalf of a client
+CREATE TABLE IF NOT EXISTS delegation_audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    delegation_id UUID NOT NULL REFERENCES delegations(id) ON DELETE CASCADE,
+    advisor_id UUID NOT NULL,
+    client_id UUID NOT NULL,
+    portfolio_id UUID,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    status INTEGER NOT NULL,
+    request_id VARCHAR(128),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_delegation_audit_events_delegation_id ON delegation_audit_events(delegation_id, created_at);
+
+-- Queued jobs run under the delegation they were requested under
+ALTER TABLE queued_jobs ADD COLUMN IF NOT EXISTS delegation_id UUID REFERENCES delegations(id) ON DELETE CASCADE;
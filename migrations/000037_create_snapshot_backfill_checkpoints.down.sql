@@ -0,0 +1,8 @@
+-- Drop snapshot_backfill_checkpoints table and snapshot backfill jobs
+DELETE FROM queued_jobs WHERE type = 'SNAPSHOT_BACKFILL';
+ALTER TABLE queued_jobs DROP CONSTRAINT IF EXISTS chk_queued_job_type;
+ALTER TABLE queued_jobs ADD CONSTRAINT chk_queued_job_type CHECK (type IN (
+    'CSV_IMPORT', 'TRACKER_IMPORT', 'RECALCULATION', 'TAX_REPORT', 'SIMULATION'
+));
+
+DROP TABLE IF EXISTS snapshot_backfill_checkpoints;
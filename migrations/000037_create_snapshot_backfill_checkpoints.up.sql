@@ -0,0 +1,14 @@
+-- Create snapshot_backfill_checkpoints table: how far each portfolio's snapshot backfill has
+-- got, so an interrupted backfill resumes from the last completed session
+CREATE TABLE IF NOT EXISTS snapshot_backfill_checkpoints (
+    portfolio_id UUID PRIMARY KEY REFERENCES portfolios(id) ON DELETE CASCADE,
+    start_date TIMESTAMP NOT NULL,
+    last_completed_date TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Allow snapshot backfill jobs
+ALTER TABLE queued_jobs DROP CONSTRAINT IF EXISTS chk_queued_job_type;
+ALTER TABLE queued_jobs ADD CONSTRAINT chk_queued_job_type CHECK (type IN (
+    'CSV_IMPORT', 'TRACKER_IMPORT', 'RECALCULATION', 'TAX_REPORT', 'SIMULATION', 'SNAPSHOT_BACKFILL'
+));
@@ -0,0 +1,14 @@
+-- Create feature_flag_overrides table: features turned on or off for one user, or for
+-- everyone when user_id is NULL, overriding the configuration
+CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One override per feature for everyone, and one per feature and user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flag_overrides_global ON feature_flag_overrides(name) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flag_overrides_user ON feature_flag_overrides(name, user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,6 @@
+-- Break each snapshot's gains down into realized and unrealized gains, dividend income and
+-- fees, cumulative from the portfolio's first transaction. Earlier snapshots leave them null.
+ALTER TABLE performance_snapshots ADD COLUMN IF NOT EXISTS realized_gain NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN IF NOT EXISTS unrealized_gain NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN IF NOT EXISTS dividend_income NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN IF NOT EXISTS fees NUMERIC(20, 8);
@@ -0,0 +1,13 @@
+-- Allow management, ADR and FX fee transactions
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+    'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE',
+    'BUY_TO_OPEN', 'SELL_TO_CLOSE', 'OPTION_EXPIRATION', 'OPTION_ASSIGNMENT',
+    'RETURN_OF_CAPITAL', 'STOCK_DIVIDEND',
+    'MANAGEMENT_FEE', 'ADR_FEE', 'FX_FEE'
+));
+
+-- Distributions and fees are looked up by type over a date range
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_type_date ON transactions(portfolio_id, type, date);
+DROP INDEX IF EXISTS idx_transactions_portfolio_type;
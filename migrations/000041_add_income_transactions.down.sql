@@ -0,0 +1,11 @@
+-- Drop income categories and restore the transaction type constraint
+ALTER TABLE transactions DROP COLUMN IF EXISTS category;
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+    'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE',
+    'BUY_TO_OPEN', 'SELL_TO_CLOSE', 'OPTION_EXPIRATION', 'OPTION_ASSIGNMENT',
+    'RETURN_OF_CAPITAL', 'STOCK_DIVIDEND',
+    'MANAGEMENT_FEE', 'ADR_FEE', 'FX_FEE'
+));
@@ -0,0 +1,3 @@
+-- Drop the tax regime of portfolios
+ALTER TABLE portfolios DROP CONSTRAINT IF EXISTS chk_tax_regime;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS tax_regime;
@@ -0,0 +1,4 @@
+-- Record the country whose capital gains rules each portfolio's tax reports follow
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS tax_regime VARCHAR(2) NOT NULL DEFAULT 'US';
+ALTER TABLE portfolios DROP CONSTRAINT IF EXISTS chk_tax_regime;
+ALTER TABLE portfolios ADD CONSTRAINT chk_tax_regime CHECK (tax_regime IN ('US', 'UK', 'CA'));
@@ -0,0 +1,19 @@
+-- Restore the corporate action and transaction type constraints and column widths
+ALTER TABLE corporate_actions DROP CONSTRAINT IF EXISTS chk_corporate_action_type;
+ALTER TABLE corporate_actions ADD CONSTRAINT chk_corporate_action_type CHECK (type IN (
+    'SPLIT', 'DIVIDEND', 'MERGER', 'SPINOFF', 'TICKER_CHANGE', 'RETURN_OF_CAPITAL', 'STOCK_DIVIDEND'
+));
+ALTER TABLE corporate_actions ALTER COLUMN type TYPE VARCHAR(20);
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+    'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE',
+    'BUY_TO_OPEN', 'SELL_TO_CLOSE', 'OPTION_EXPIRATION', 'OPTION_ASSIGNMENT',
+    'RETURN_OF_CAPITAL', 'STOCK_DIVIDEND',
+    'MANAGEMENT_FEE', 'ADR_FEE', 'FX_FEE',
+    'LENDING_INCOME', 'INTEREST', 'REBATE',
+    'DEPOSIT', 'WITHDRAWAL',
+    'OPENING_BALANCE'
+));
+ALTER TABLE transactions ALTER COLUMN type TYPE VARCHAR(20);
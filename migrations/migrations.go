@@ -0,0 +1,18 @@
+// Package migrations embeds the SQL migrations that are applied from Go code rather than
+// with the migrate CLI.
+package migrations
+
+import "embed"
+
+// Tenant holds the migrations applied to each organization's schema in multi-schema mode.
+// They are numbered independently of the migrations in this directory, which keep managing
+// the public schema.
+//
+//go:embed tenant/*.sql
+var Tenant embed.FS
+
+// SQLite holds the migrations for SQLite deployments, which are applied when the server
+// connects. They create the same tables as the Postgres migrations in this directory.
+//
+//go:embed sqlite/*.sql
+var SQLite embed.FS
@@ -0,0 +1,21 @@
+-- Drop the SQLite schema
+DROP TABLE IF EXISTS peer_benchmarks;
+DROP TABLE IF EXISTS rebalance_plan_trades;
+DROP TABLE IF EXISTS rebalance_plans;
+DROP TABLE IF EXISTS blackout_overrides;
+DROP TABLE IF EXISTS blackout_windows;
+DROP TABLE IF EXISTS employer_stock_policies;
+DROP TABLE IF EXISTS stock_plan_events;
+DROP TABLE IF EXISTS stock_plan_grants;
+DROP TABLE IF EXISTS portfolio_actions;
+DROP TABLE IF EXISTS performance_snapshots;
+DROP TABLE IF EXISTS tax_lots;
+DROP TABLE IF EXISTS holdings;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS portfolios;
+DROP TABLE IF EXISTS corporate_actions;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,432 @@
+-- Create the full schema for SQLite deployments. It matches migration 000016 of the Postgres
+-- migrations: UUIDs are stored as text, and updated_at is maintained by triggers that only
+-- fire when an update leaves it unchanged. Foreign keys are enforced by the connection.
+
+-- Organizations for the admin provisioning API. Quotas of 0 mean unlimited. schema_name is
+-- only used in multi-schema mode, which needs Postgres.
+CREATE TABLE IF NOT EXISTS organizations (
+    id TEXT PRIMARY KEY,
+    external_id VARCHAR(255) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    max_users INTEGER NOT NULL DEFAULT 0,
+    max_portfolios_per_user INTEGER NOT NULL DEFAULT 0,
+    max_api_keys INTEGER NOT NULL DEFAULT 0,
+    schema_name VARCHAR(63),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_organization_quotas CHECK (max_users >= 0 AND max_portfolios_per_user >= 0 AND max_api_keys >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_external_id ON organizations(external_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_schema_name ON organizations(schema_name) WHERE schema_name IS NOT NULL;
+
+-- Users
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    organization_id TEXT REFERENCES organizations(id) ON DELETE RESTRICT,
+    external_id VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_login_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_organization_external_id ON users(organization_id, external_id);
+
+CREATE TRIGGER IF NOT EXISTS update_users_updated_at
+    AFTER UPDATE ON users
+    FOR EACH ROW
+    WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Refresh tokens
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens(token_hash);
+
+-- Password reset tokens
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    token_hash VARCHAR(255) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    used_at TIMESTAMP,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_token_hash ON password_reset_tokens(token_hash);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+
+-- API keys. Only a SHA-256 hash of each key is stored.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    external_id VARCHAR(255) NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_organization_external_id ON api_keys(organization_id, external_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_hash ON api_keys(key_hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+
+-- Corporate actions
+CREATE TABLE IF NOT EXISTS corporate_actions (
+    id TEXT PRIMARY KEY,
+    symbol VARCHAR(20) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    ratio NUMERIC(20, 8),
+    amount NUMERIC(20, 8),
+    new_symbol VARCHAR(20),
+    currency VARCHAR(3),
+    description TEXT,
+    applied BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_corporate_action_type CHECK (type IN ('SPLIT', 'DIVIDEND', 'MERGER', 'SPINOFF', 'TICKER_CHANGE'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_symbol ON corporate_actions(symbol);
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_date ON corporate_actions(date DESC);
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_type ON corporate_actions(type);
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_applied ON corporate_actions(applied);
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_symbol_date ON corporate_actions(symbol, date DESC);
+
+-- Portfolios
+CREATE TABLE IF NOT EXISTS portfolios (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    base_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    cost_basis_method VARCHAR(20) NOT NULL DEFAULT 'FIFO',
+    peer_comparison_opt_in BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_cost_basis_method CHECK (cost_basis_method IN ('FIFO', 'LIFO', 'SPECIFIC_LOT'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolios_user_id ON portfolios(user_id);
+CREATE INDEX IF NOT EXISTS idx_portfolios_created_at ON portfolios(created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolios_user_name ON portfolios(user_id, name);
+CREATE INDEX IF NOT EXISTS idx_portfolios_peer_comparison_opt_in ON portfolios(peer_comparison_opt_in) WHERE peer_comparison_opt_in;
+
+CREATE TRIGGER IF NOT EXISTS update_portfolios_updated_at
+    AFTER UPDATE ON portfolios
+    FOR EACH ROW
+    WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE portfolios SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Transactions
+CREATE TABLE IF NOT EXISTS transactions (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    quantity NUMERIC(20, 8) NOT NULL,
+    price NUMERIC(20, 8),
+    commission NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    notes TEXT,
+    import_batch_id TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_transaction_type CHECK (type IN (
+        'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+        'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE'
+    )),
+    CONSTRAINT chk_quantity_positive CHECK (quantity > 0),
+    CONSTRAINT chk_price_non_negative CHECK (price IS NULL OR price >= 0),
+    CONSTRAINT chk_commission_non_negative CHECK (commission >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_id ON transactions(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_symbol ON transactions(symbol);
+CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_symbol ON transactions(portfolio_id, symbol);
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_date ON transactions(portfolio_id, date DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_import_batch ON transactions(import_batch_id);
+
+CREATE TRIGGER IF NOT EXISTS update_transactions_updated_at
+    AFTER UPDATE ON transactions
+    FOR EACH ROW
+    WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE transactions SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Holdings
+CREATE TABLE IF NOT EXISTS holdings (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    quantity NUMERIC(20, 8) NOT NULL,
+    cost_basis NUMERIC(20, 8) NOT NULL,
+    avg_cost_price NUMERIC(20, 8) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_holding_quantity CHECK (quantity >= 0),
+    CONSTRAINT chk_holding_cost_basis CHECK (cost_basis >= 0),
+    CONSTRAINT chk_holding_avg_cost CHECK (avg_cost_price >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holdings_portfolio_symbol ON holdings(portfolio_id, symbol);
+CREATE INDEX IF NOT EXISTS idx_holdings_portfolio_id ON holdings(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_holdings_symbol ON holdings(symbol);
+CREATE INDEX IF NOT EXISTS idx_holdings_updated_at ON holdings(updated_at DESC);
+
+CREATE TRIGGER IF NOT EXISTS update_holdings_updated_at
+    AFTER UPDATE ON holdings
+    FOR EACH ROW
+    WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE holdings SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Tax lots
+CREATE TABLE IF NOT EXISTS tax_lots (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    purchase_date TIMESTAMP NOT NULL,
+    quantity NUMERIC(20, 8) NOT NULL,
+    cost_basis NUMERIC(20, 8) NOT NULL,
+    transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_tax_lot_quantity CHECK (quantity >= 0),
+    CONSTRAINT chk_tax_lot_cost_basis CHECK (cost_basis >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_lots_portfolio_id ON tax_lots(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_tax_lots_symbol ON tax_lots(symbol);
+CREATE INDEX IF NOT EXISTS idx_tax_lots_portfolio_symbol ON tax_lots(portfolio_id, symbol);
+CREATE INDEX IF NOT EXISTS idx_tax_lots_purchase_date ON tax_lots(purchase_date);
+CREATE INDEX IF NOT EXISTS idx_tax_lots_transaction_id ON tax_lots(transaction_id);
+
+CREATE TRIGGER IF NOT EXISTS update_tax_lots_updated_at
+    AFTER UPDATE ON tax_lots
+    FOR EACH ROW
+    WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE tax_lots SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Performance snapshots
+CREATE TABLE IF NOT EXISTS performance_snapshots (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    date TIMESTAMP NOT NULL,
+    total_value NUMERIC(20, 8) NOT NULL,
+    total_cost_basis NUMERIC(20, 8) NOT NULL,
+    total_return NUMERIC(20, 8) NOT NULL,
+    total_return_pct NUMERIC(10, 4) NOT NULL,
+    day_change NUMERIC(20, 8),
+    day_change_pct NUMERIC(10, 4),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_performance_snapshots_portfolio_date ON performance_snapshots(portfolio_id, date);
+CREATE INDEX IF NOT EXISTS idx_performance_snapshots_portfolio_id ON performance_snapshots(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_performance_snapshots_date ON performance_snapshots(date DESC);
+CREATE INDEX IF NOT EXISTS idx_performance_snapshots_created_at ON performance_snapshots(created_at DESC);
+
+-- Portfolio actions (corporate actions suggested to a portfolio)
+CREATE TABLE IF NOT EXISTS portfolio_actions (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    corporate_action_id TEXT NOT NULL REFERENCES corporate_actions(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    affected_symbol VARCHAR(20) NOT NULL,
+    shares_affected BIGINT NOT NULL,
+    detected_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP,
+    applied_at TIMESTAMP,
+    reviewed_by_user_id TEXT REFERENCES users(id),
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_portfolio_action_status CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED', 'APPLIED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_portfolio_id ON portfolio_actions(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_corporate_action_id ON portfolio_actions(corporate_action_id);
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_status ON portfolio_actions(status);
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_portfolio_status ON portfolio_actions(portfolio_id, status);
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_detected_at ON portfolio_actions(detected_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolio_actions_unique_pending
+    ON portfolio_actions(portfolio_id, corporate_action_id)
+    WHERE status = 'PENDING';
+
+-- Employer stock plans
+CREATE TABLE IF NOT EXISTS stock_plan_grants (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    grant_date TIMESTAMP NOT NULL,
+    total_shares NUMERIC(20, 8) NOT NULL,
+    vesting_start_date TIMESTAMP NOT NULL,
+    vesting_months INTEGER NOT NULL DEFAULT 0,
+    cliff_months INTEGER NOT NULL DEFAULT 0,
+    vesting_interval_months INTEGER NOT NULL DEFAULT 1,
+    strike_price NUMERIC(20, 8),
+    expiration_date TIMESTAMP,
+    discount_percent NUMERIC(10, 4),
+    lookback BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_stock_plan_type CHECK (type IN ('RSU', 'ESPP', 'ISO', 'NSO')),
+    CONSTRAINT chk_stock_plan_total_shares CHECK (total_shares > 0),
+    CONSTRAINT chk_stock_plan_vesting CHECK (vesting_months >= 0 AND cliff_months >= 0 AND vesting_interval_months >= 0),
+    CONSTRAINT chk_stock_plan_strike CHECK (strike_price IS NULL OR strike_price >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_plan_grants_portfolio_id ON stock_plan_grants(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_stock_plan_grants_symbol ON stock_plan_grants(symbol);
+
+CREATE TABLE IF NOT EXISTS stock_plan_events (
+    id TEXT PRIMARY KEY,
+    grant_id TEXT NOT NULL REFERENCES stock_plan_grants(id) ON DELETE CASCADE,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    transaction_id TEXT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    shares NUMERIC(20, 8) NOT NULL,
+    shares_withheld NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    fair_market_value NUMERIC(20, 8) NOT NULL,
+    purchase_price NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    cost_basis NUMERIC(20, 8) NOT NULL,
+    ordinary_income NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    bargain_element NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    amt_adjustment NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_stock_plan_event_type CHECK (type IN ('VEST', 'ESPP_PURCHASE', 'EXERCISE')),
+    CONSTRAINT chk_stock_plan_event_shares CHECK (shares > 0 AND shares_withheld >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_plan_events_grant_id ON stock_plan_events(grant_id);
+CREATE INDEX IF NOT EXISTS idx_stock_plan_events_portfolio_id ON stock_plan_events(portfolio_id);
+
+-- Employer stock blackout windows
+CREATE TABLE IF NOT EXISTS employer_stock_policies (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    enforcement VARCHAR(10) NOT NULL DEFAULT 'WARN',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_blackout_enforcement CHECK (enforcement IN ('WARN', 'BLOCK'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employer_stock_policies_portfolio_id ON employer_stock_policies(portfolio_id);
+
+CREATE TABLE IF NOT EXISTS blackout_windows (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_blackout_window_dates CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blackout_windows_portfolio_id ON blackout_windows(portfolio_id);
+
+CREATE TABLE IF NOT EXISTS blackout_overrides (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    window_id TEXT NOT NULL,
+    transaction_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    transaction_type VARCHAR(20) NOT NULL,
+    transaction_date TIMESTAMP NOT NULL,
+    enforcement VARCHAR(10) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_blackout_overrides_portfolio_id ON blackout_overrides(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_blackout_overrides_window_id ON blackout_overrides(window_id);
+
+-- Rebalancing execution plans
+CREATE TABLE IF NOT EXISTS rebalance_plans (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    stale_after_days INTEGER NOT NULL DEFAULT 7,
+    last_reminder_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_rebalance_plan_status CHECK (status IN ('ACTIVE', 'COMPLETED', 'CANCELLED')),
+    CONSTRAINT chk_rebalance_plan_stale_after_days CHECK (stale_after_days >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_plans_portfolio_id ON rebalance_plans(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_rebalance_plans_status ON rebalance_plans(status);
+
+CREATE TABLE IF NOT EXISTS rebalance_plan_trades (
+    id TEXT PRIMARY KEY,
+    plan_id TEXT NOT NULL REFERENCES rebalance_plans(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    side VARCHAR(10) NOT NULL,
+    planned_quantity NUMERIC(20, 8) NOT NULL,
+    planned_price NUMERIC(20, 8) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    executed_quantity NUMERIC(20, 8),
+    executed_price NUMERIC(20, 8),
+    executed_at TIMESTAMP,
+    transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_rebalance_trade_side CHECK (side IN ('BUY', 'SELL')),
+    CONSTRAINT chk_rebalance_trade_status CHECK (status IN ('PENDING', 'EXECUTED', 'SKIPPED')),
+    CONSTRAINT chk_rebalance_trade_planned CHECK (planned_quantity > 0 AND planned_price > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_plan_trades_plan_id ON rebalance_plan_trades(plan_id);
+
+-- Peer benchmarks, aggregated from opted-in portfolios
+CREATE TABLE IF NOT EXISTS peer_benchmarks (
+    id TEXT PRIMARY KEY,
+    bucket VARCHAR(20) NOT NULL,
+    sample_size INTEGER NOT NULL,
+    p10 NUMERIC(12, 6) NOT NULL,
+    p25 NUMERIC(12, 6) NOT NULL,
+    p50 NUMERIC(12, 6) NOT NULL,
+    p75 NUMERIC(12, 6) NOT NULL,
+    p90 NUMERIC(12, 6) NOT NULL,
+    computed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_peer_benchmark_bucket CHECK (bucket IN ('CONCENTRATED', 'FOCUSED', 'DIVERSIFIED')),
+    CONSTRAINT chk_peer_benchmark_sample_size CHECK (sample_size >= 10)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_peer_benchmarks_bucket ON peer_benchmarks(bucket);
@@ -0,0 +1,5 @@
+-- Drop user roles and account state
+DROP INDEX IF EXISTS idx_users_role;
+ALTER TABLE users DROP COLUMN password_reset_required;
+ALTER TABLE users DROP COLUMN disabled_at;
+ALTER TABLE users DROP COLUMN role;
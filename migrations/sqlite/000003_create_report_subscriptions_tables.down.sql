@@ -0,0 +1,6 @@
+-- Drop report subscription tables
+DROP INDEX IF EXISTS idx_report_subscription_portfolios_portfolio_id;
+DROP TABLE IF EXISTS report_subscription_portfolios;
+DROP INDEX IF EXISTS idx_report_subscriptions_enabled;
+DROP INDEX IF EXISTS idx_report_subscriptions_user_id;
+DROP TABLE IF EXISTS report_subscriptions;
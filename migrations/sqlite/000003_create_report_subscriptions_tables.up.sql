@@ -0,0 +1,22 @@
+-- Create the report subscription tables, matching migration 000018 of the Postgres migrations
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_report_subscription_frequency CHECK (frequency IN ('WEEKLY', 'MONTHLY'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_user_id ON report_subscriptions(user_id);
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_enabled ON report_subscriptions(enabled) WHERE enabled;
+
+CREATE TABLE IF NOT EXISTS report_subscription_portfolios (
+    subscription_id TEXT NOT NULL REFERENCES report_subscriptions(id) ON DELETE CASCADE,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, portfolio_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscription_portfolios_portfolio_id ON report_subscription_portfolios(portfolio_id);
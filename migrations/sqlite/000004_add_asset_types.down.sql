@@ -0,0 +1,4 @@
+-- Drop asset types
+DROP INDEX IF EXISTS idx_holdings_asset_type;
+ALTER TABLE holdings DROP COLUMN asset_type;
+ALTER TABLE transactions DROP COLUMN asset_type;
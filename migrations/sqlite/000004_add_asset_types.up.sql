@@ -0,0 +1,5 @@
+-- Add asset types, matching migration 000019 of the Postgres migrations
+ALTER TABLE transactions ADD COLUMN asset_type VARCHAR(10) NOT NULL DEFAULT 'EQUITY' CHECK (asset_type IN ('EQUITY', 'CRYPTO'));
+ALTER TABLE holdings ADD COLUMN asset_type VARCHAR(10) NOT NULL DEFAULT 'EQUITY' CHECK (asset_type IN ('EQUITY', 'CRYPTO'));
+
+CREATE INDEX IF NOT EXISTS idx_holdings_asset_type ON holdings(asset_type);
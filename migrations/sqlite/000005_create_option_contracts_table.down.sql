@@ -0,0 +1,5 @@
+-- Drop option contracts. The multiplier columns and wider CHECK constraints on transactions
+-- and holdings are left in place; the down migrations are only run by hand.
+DROP INDEX IF EXISTS idx_option_contracts_expiry;
+DROP INDEX IF EXISTS idx_option_contracts_underlying;
+DROP TABLE IF EXISTS option_contracts;
@@ -0,0 +1,42 @@
+-- Create the option contracts table and allow option transactions, matching migration 000020
+-- of the Postgres migrations
+CREATE TABLE IF NOT EXISTS option_contracts (
+    id TEXT PRIMARY KEY,
+    symbol VARCHAR(20) NOT NULL UNIQUE,
+    underlying VARCHAR(10) NOT NULL,
+    option_type VARCHAR(4) NOT NULL,
+    strike NUMERIC(20, 8) NOT NULL,
+    expiry DATE NOT NULL,
+    multiplier INTEGER NOT NULL DEFAULT 100,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_option_contract_type CHECK (option_type IN ('CALL', 'PUT')),
+    CONSTRAINT chk_option_contract_strike CHECK (strike > 0),
+    CONSTRAINT chk_option_contract_multiplier CHECK (multiplier > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_option_contracts_underlying ON option_contracts(underlying);
+CREATE INDEX IF NOT EXISTS idx_option_contracts_expiry ON option_contracts(expiry);
+
+-- SQLite can't alter CHECK constraints, and rebuilding transactions would cascade to the tax
+-- lots and other rows that reference it, since foreign keys can't be turned off inside the
+-- migration transaction. The constraints only get wider, so existing rows still satisfy them
+-- and the stored table definitions are edited in place, as the SQLite documentation
+-- describes for loosening constraints. Resetting writable_schema reloads the definitions on
+-- this connection, and the ALTER TABLE statements after it bump the schema version so other
+-- connections reload them too.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''RSU_VEST'', ''ESPP_PURCHASE'', ''OPTION_EXERCISE''',
+    '''RSU_VEST'', ''ESPP_PURCHASE'', ''OPTION_EXERCISE'',
+        ''BUY_TO_OPEN'', ''SELL_TO_CLOSE'', ''OPTION_EXPIRATION'', ''OPTION_ASSIGNMENT''')
+WHERE type = 'table' AND name = 'transactions';
+
+UPDATE sqlite_master
+SET sql = replace(sql, 'asset_type IN (''EQUITY'', ''CRYPTO'')', 'asset_type IN (''EQUITY'', ''CRYPTO'', ''OPTION'')')
+WHERE type = 'table' AND name IN ('transactions', 'holdings');
+
+PRAGMA writable_schema = RESET;
+
+ALTER TABLE transactions ADD COLUMN multiplier INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE holdings ADD COLUMN multiplier INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+-- Drop the distribution index. The wider CHECK constraints are left in place; the down
+-- migrations are only run by hand.
+DROP INDEX IF EXISTS idx_transactions_portfolio_type;
@@ -0,0 +1,19 @@
+-- Allow return-of-capital distributions, matching migration 000021 of the Postgres migrations.
+-- The CHECK constraints are widened in place, as in migration 000005; creating the index
+-- afterwards bumps the schema version so other connections reload the definitions.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''OPTION_EXPIRATION'', ''OPTION_ASSIGNMENT''',
+    '''OPTION_EXPIRATION'', ''OPTION_ASSIGNMENT'',
+        ''RETURN_OF_CAPITAL''')
+WHERE type = 'table' AND name = 'transactions';
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''SPINOFF'', ''TICKER_CHANGE'')', '''SPINOFF'', ''TICKER_CHANGE'', ''RETURN_OF_CAPITAL'')')
+WHERE type = 'table' AND name = 'corporate_actions';
+
+PRAGMA writable_schema = RESET;
+
+-- The tax report looks up a portfolio's distributions by type
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_type ON transactions(portfolio_id, type);
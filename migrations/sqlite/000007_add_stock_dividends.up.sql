@@ -0,0 +1,12 @@
+-- Allow stock dividends, matching migration 000022 of the Postgres migrations. The CHECK
+-- constraints are widened in place, as in migration 000005.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''RETURN_OF_CAPITAL''', '''RETURN_OF_CAPITAL'', ''STOCK_DIVIDEND''')
+WHERE type = 'table' AND name IN ('transactions', 'corporate_actions');
+
+PRAGMA writable_schema = RESET;
+
+-- Other connections only reload the definitions once the schema version changes
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_type_date ON corporate_actions(type, date DESC);
@@ -0,0 +1,3 @@
+-- Drop record versions
+ALTER TABLE transactions DROP COLUMN version;
+ALTER TABLE portfolios DROP COLUMN version;
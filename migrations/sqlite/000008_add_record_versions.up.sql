@@ -0,0 +1,3 @@
+-- Version portfolios and transactions, matching migration 000023 of the Postgres migrations
+ALTER TABLE portfolios ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE transactions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
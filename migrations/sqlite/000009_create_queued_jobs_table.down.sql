@@ -0,0 +1,5 @@
+-- Drop queued_jobs table
+DROP INDEX IF EXISTS idx_queued_jobs_running;
+DROP INDEX IF EXISTS idx_queued_jobs_queued;
+DROP INDEX IF EXISTS idx_queued_jobs_user_id;
+DROP TABLE IF EXISTS queued_jobs;
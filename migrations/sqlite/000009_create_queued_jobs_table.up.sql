@@ -0,0 +1,24 @@
+-- Create the job queue table, matching migration 000024 of the Postgres migrations
+CREATE TABLE IF NOT EXISTS queued_jobs (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    portfolio_id TEXT,
+    tenant_schema VARCHAR(63) NOT NULL DEFAULT '',
+    type VARCHAR(30) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED',
+    payload TEXT NOT NULL DEFAULT '{}',
+    progress TEXT,
+    result TEXT,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    heartbeat_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_queued_job_type CHECK (type IN ('CSV_IMPORT', 'TRACKER_IMPORT', 'RECALCULATION', 'TAX_REPORT')),
+    CONSTRAINT chk_queued_job_status CHECK (status IN ('QUEUED', 'RUNNING', 'SUCCEEDED', 'FAILED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_queued_jobs_user_id ON queued_jobs(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_queued_jobs_queued ON queued_jobs(created_at) WHERE status = 'QUEUED';
+CREATE INDEX IF NOT EXISTS idx_queued_jobs_running ON queued_jobs(heartbeat_at) WHERE status = 'RUNNING';
@@ -0,0 +1,6 @@
+-- Drop tag tables
+DROP INDEX IF EXISTS idx_transaction_tags_tag_id;
+DROP TABLE IF EXISTS transaction_tags;
+DROP INDEX IF EXISTS idx_portfolio_tags_tag_id;
+DROP TABLE IF EXISTS portfolio_tags;
+DROP TABLE IF EXISTS tags;
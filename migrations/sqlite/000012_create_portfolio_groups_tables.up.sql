@@ -0,0 +1,21 @@
+-- Create the portfolio group tables, matching migration 000027 of the Postgres migrations
+CREATE TABLE IF NOT EXISTS portfolio_groups (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_id TEXT REFERENCES portfolio_groups(id) ON DELETE SET NULL,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_portfolio_groups_user_id_name UNIQUE (user_id, name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_groups_parent_id ON portfolio_groups(parent_id);
+
+CREATE TABLE IF NOT EXISTS portfolio_group_portfolios (
+    group_id TEXT NOT NULL REFERENCES portfolio_groups(id) ON DELETE CASCADE,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, portfolio_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_group_portfolios_portfolio_id ON portfolio_group_portfolios(portfolio_id);
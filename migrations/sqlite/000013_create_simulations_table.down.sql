@@ -0,0 +1,4 @@
+-- Drop the simulations table. The wider job type CHECK constraint is left in place; the down
+-- migrations are only run by hand.
+DROP INDEX IF EXISTS idx_simulations_portfolio_id;
+DROP TABLE IF EXISTS simulations;
@@ -0,0 +1,26 @@
+-- Create the simulations table and allow simulation jobs, matching migration 000028 of the
+-- Postgres migrations. The job type CHECK constraint is widened in place, as in migration
+-- 000007.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''TAX_REPORT''', '''TAX_REPORT'', ''SIMULATION''')
+WHERE type = 'table' AND name = 'queued_jobs';
+
+PRAGMA writable_schema = RESET;
+
+-- Creating the table also makes other connections reload the widened definition
+CREATE TABLE IF NOT EXISTS simulations (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    job_id TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED',
+    parameters TEXT NOT NULL,
+    result TEXT,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    CONSTRAINT chk_simulation_status CHECK (status IN ('QUEUED', 'RUNNING', 'SUCCEEDED', 'FAILED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_simulations_portfolio_id ON simulations(portfolio_id, created_at);
@@ -0,0 +1,12 @@
+-- Create the securities table, matching migration 000029 of the Postgres migrations
+CREATE TABLE IF NOT EXISTS securities (
+    symbol VARCHAR(40) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    exchange VARCHAR(100),
+    type VARCHAR(50),
+    currency VARCHAR(10),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_securities_name ON securities(UPPER(name));
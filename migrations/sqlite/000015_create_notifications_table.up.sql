@@ -0,0 +1,17 @@
+-- Create the notifications table, matching migration 000030 of the Postgres migrations
+CREATE TABLE IF NOT EXISTS notifications (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    portfolio_id TEXT,
+    type VARCHAR(30) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_notification_type CHECK (type IN (
+        'CORPORATE_ACTION', 'IMPORT_COMPLETED', 'IMPORT_FAILED', 'REPORT_GENERATED'
+    ))
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
@@ -0,0 +1,16 @@
+-- Create the push_devices table, matching migration 000031 of the Postgres migrations
+CREATE TABLE IF NOT EXISTS push_devices (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(20) NOT NULL,
+    endpoint TEXT NOT NULL,
+    p256dh VARCHAR(128),
+    auth VARCHAR(64),
+    name VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP,
+    CONSTRAINT chk_push_device_platform CHECK (platform IN ('WEB_PUSH', 'APNS'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_push_devices_endpoint ON push_devices(endpoint);
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices(user_id);
@@ -0,0 +1,2 @@
+-- Drop the user_settings table
+DROP TABLE IF EXISTS user_settings;
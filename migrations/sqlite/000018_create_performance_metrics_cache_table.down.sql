@@ -0,0 +1,3 @@
+-- Drop the performance_metrics_cache table
+DROP INDEX IF EXISTS idx_performance_metrics_cache_portfolio_period;
+DROP TABLE IF EXISTS performance_metrics_cache;
@@ -0,0 +1,13 @@
+-- Allow one performance snapshot per portfolio per day, matching migration 000034 of the
+-- Postgres migrations. Keep the last snapshot of each day and drop the rest first.
+DELETE FROM performance_snapshots
+WHERE EXISTS (
+    SELECT 1 FROM performance_snapshots newer
+    WHERE newer.portfolio_id = performance_snapshots.portfolio_id
+      AND date(newer.date) = date(performance_snapshots.date)
+      AND (newer.date > performance_snapshots.date
+           OR (newer.date = performance_snapshots.date AND newer.id > performance_snapshots.id))
+);
+
+DROP INDEX IF EXISTS idx_performance_snapshots_portfolio_date;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_performance_snapshots_portfolio_day ON performance_snapshots(portfolio_id, date(date));
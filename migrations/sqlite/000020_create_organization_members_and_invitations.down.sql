@@ -0,0 +1,6 @@
+-- Drop organization memberships, invitations and organization-owned portfolios
+ALTER TABLE queued_jobs DROP COLUMN organization_id;
+DROP INDEX IF EXISTS idx_portfolios_organization_id;
+ALTER TABLE portfolios DROP COLUMN organization_id;
+DROP TABLE IF EXISTS organization_invitations;
+DROP TABLE IF EXISTS organization_members;
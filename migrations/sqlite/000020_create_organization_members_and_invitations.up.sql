@@ -0,0 +1,37 @@
+-- Create organization memberships and invitations, and let organizations own portfolios,
+-- matching migration 000035 of the Postgres migrations
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id),
+    CONSTRAINT chk_organization_members_role CHECK (role IN ('owner', 'admin', 'member', 'viewer'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user_id ON organization_members(user_id);
+
+INSERT OR IGNORE INTO organization_members (organization_id, user_id, role)
+SELECT organization_id, id, 'member' FROM users WHERE organization_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS organization_invitations (
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    invited_by_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_organization_invitations_role CHECK (role IN ('owner', 'admin', 'member', 'viewer'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organization_invitations_token_hash ON organization_invitations(token_hash);
+CREATE INDEX IF NOT EXISTS idx_organization_invitations_organization_id ON organization_invitations(organization_id);
+
+ALTER TABLE portfolios ADD COLUMN organization_id TEXT REFERENCES organizations(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_portfolios_organization_id ON portfolios(organization_id) WHERE organization_id IS NOT NULL;
+
+ALTER TABLE queued_jobs ADD COLUMN organization_id TEXT REFERENCES organizations(id) ON DELETE CASCADE;
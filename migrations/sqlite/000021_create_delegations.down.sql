@@ -0,0 +1,5 @@
+-- Drop advisor delegations, their portfolios and audit trail
+ALTER TABLE queued_jobs DROP COLUMN delegation_id;
+DROP TABLE IF EXISTS delegation_audit_events;
+DROP TABLE IF EXISTS delegation_portfolios;
+DROP TABLE IF EXISTS delegations;
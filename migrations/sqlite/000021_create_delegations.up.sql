@@ -0,0 +1,48 @@
+-- Create advisor delegations, their portfolios and audit trail, matching migration 000036
+-- of the Postgres migrations
+CREATE TABLE IF NOT EXISTS delegations (
+    id TEXT PRIMARY KEY,
+    advisor_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    client_email VARCHAR(255) NOT NULL,
+    client_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    access VARCHAR(10) NOT NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending',
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    approved_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_delegations_access CHECK (access IN ('read', 'manage')),
+    CONSTRAINT chk_delegations_status CHECK (status IN ('pending', 'active', 'revoked'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_delegations_token_hash ON delegations(token_hash);
+CREATE INDEX IF NOT EXISTS idx_delegations_advisor_id ON delegations(advisor_id);
+CREATE INDEX IF NOT EXISTS idx_delegations_client_id ON delegations(client_id);
+CREATE INDEX IF NOT EXISTS idx_delegations_client_email ON delegations(client_email);
+
+CREATE TABLE IF NOT EXISTS delegation_portfolios (
+    delegation_id TEXT NOT NULL REFERENCES delegations(id) ON DELETE CASCADE,
+    portfolio_id TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (delegation_id, portfolio_id)
+);
+
+CREATE TABLE IF NOT EXISTS delegation_audit_events (
+    id TEXT PRIMARY KEY,
+    delegation_id TEXT NOT NULL REFERENCES delegations(id) ON DELETE CASCADE,
+    advisor_id TEXT NOT NULL,
+    client_id TEXT NOT NULL,
+    portfolio_id TEXT,
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    path VARCHAR(2048) NOT NULL,
+    status INTEGER NOT NULL,
+    request_id VARCHAR(128),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_delegation_audit_events_delegation_id ON delegation_audit_events(delegation_id, created_at);
+
+ALTER TABLE queued_jobs ADD COLUMN delegation_id TEXT REFERENCES delegations(id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+-- Drop the snapshot_backfill_checkpoints table. The wider job type CHECK constraint is left in
+-- place; the down migrations are only run by hand.
+DROP TABLE IF EXISTS snapshot_backfill_checkpoints;
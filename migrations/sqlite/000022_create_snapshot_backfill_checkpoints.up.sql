@@ -0,0 +1,18 @@
+-- Create the snapshot_backfill_checkpoints table and allow snapshot backfill jobs, matching
+-- migration 000037 of the Postgres migrations. The job type CHECK constraint is widened in
+-- place, as in migration 000013.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''SIMULATION''', '''SIMULATION'', ''SNAPSHOT_BACKFILL''')
+WHERE type = 'table' AND name = 'queued_jobs';
+
+PRAGMA writable_schema = RESET;
+
+-- Creating the table also makes other connections reload the widened definition
+CREATE TABLE IF NOT EXISTS snapshot_backfill_checkpoints (
+    portfolio_id TEXT PRIMARY KEY REFERENCES portfolios(id) ON DELETE CASCADE,
+    start_date TIMESTAMP NOT NULL,
+    last_completed_date TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
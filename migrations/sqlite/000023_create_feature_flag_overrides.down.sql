@@ -0,0 +1,2 @@
+-- Drop feature_flag_overrides table
+DROP TABLE IF EXISTS feature_flag_overrides;
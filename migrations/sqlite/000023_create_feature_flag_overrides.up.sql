@@ -0,0 +1,12 @@
+-- Create feature_flag_overrides table, matching migration 000038 of the Postgres migrations
+CREATE TABLE IF NOT EXISTS feature_flag_overrides (
+    id TEXT PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    user_id TEXT REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flag_overrides_global ON feature_flag_overrides(name) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_feature_flag_overrides_user ON feature_flag_overrides(name, user_id) WHERE user_id IS NOT NULL;
@@ -0,0 +1,5 @@
+-- Drop the gain breakdown of performance snapshots
+ALTER TABLE performance_snapshots DROP COLUMN fees;
+ALTER TABLE performance_snapshots DROP COLUMN dividend_income;
+ALTER TABLE performance_snapshots DROP COLUMN unrealized_gain;
+ALTER TABLE performance_snapshots DROP COLUMN realized_gain;
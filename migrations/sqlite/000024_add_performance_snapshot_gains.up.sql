@@ -0,0 +1,5 @@
+-- Break each snapshot's gains down, matching migration 000039 of the Postgres migrations
+ALTER TABLE performance_snapshots ADD COLUMN realized_gain NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN unrealized_gain NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN dividend_income NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN fees NUMERIC(20, 8);
@@ -0,0 +1,4 @@
+-- Restore the distribution index. The wider CHECK constraint is left in place; the down
+-- migrations are only run by hand.
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_type ON transactions(portfolio_id, type);
+DROP INDEX IF EXISTS idx_transactions_portfolio_type_date;
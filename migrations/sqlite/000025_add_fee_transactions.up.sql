@@ -0,0 +1,14 @@
+-- Allow management, ADR and FX fee transactions, matching migration 000040 of the Postgres
+-- migrations. The CHECK constraint is widened in place, as in migration 000005; creating the
+-- index afterwards bumps the schema version so other connections reload the definition.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''STOCK_DIVIDEND''', '''STOCK_DIVIDEND'', ''MANAGEMENT_FEE'', ''ADR_FEE'', ''FX_FEE''')
+WHERE type = 'table' AND name = 'transactions';
+
+PRAGMA writable_schema = RESET;
+
+-- Distributions and fees are looked up by type over a date range
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_type_date ON transactions(portfolio_id, type, date);
+DROP INDEX IF EXISTS idx_transactions_portfolio_type;
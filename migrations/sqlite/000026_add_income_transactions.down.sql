@@ -0,0 +1,3 @@
+-- Drop income categories. The wider CHECK constraint is left in place; the down migrations are
+-- only run by hand.
+ALTER TABLE transactions DROP COLUMN category;
@@ -0,0 +1,12 @@
+-- Allow income transactions, matching migration 000041 of the Postgres migrations. The CHECK
+-- constraint is widened in place, as in migration 000005; adding the column afterwards bumps
+-- the schema version so other connections reload the definition.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''FX_FEE''', '''FX_FEE'', ''LENDING_INCOME'', ''INTEREST'', ''REBATE''')
+WHERE type = 'table' AND name = 'transactions';
+
+PRAGMA writable_schema = RESET;
+
+ALTER TABLE transactions ADD COLUMN category VARCHAR(50);
@@ -0,0 +1,2 @@
+-- Nothing to undo: the wider CHECK constraint is left in place, and the down migrations are
+-- only run by hand.
@@ -0,0 +1,13 @@
+-- Allow opening balance transactions, matching migration 000043 of the Postgres
+-- migrations. The CHECK constraint is widened in place, as in migration 000005; recreating the
+-- type index afterwards bumps the schema version so other connections reload the definition.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''WITHDRAWAL''', '''WITHDRAWAL'', ''OPENING_BALANCE''')
+WHERE type = 'table' AND name = 'transactions';
+
+PRAGMA writable_schema = RESET;
+
+DROP INDEX IF EXISTS idx_transactions_portfolio_type_date;
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_type_date ON transactions(portfolio_id, type, date);
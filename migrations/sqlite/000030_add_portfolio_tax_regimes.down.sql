@@ -0,0 +1,2 @@
+-- Drop the tax regime of portfolios
+ALTER TABLE portfolios DROP COLUMN tax_regime;
@@ -0,0 +1,2 @@
+-- Record each portfolio's tax regime, matching migration 000045 of the Postgres migrations
+ALTER TABLE portfolios ADD COLUMN tax_regime VARCHAR(2) NOT NULL DEFAULT 'US' CHECK (tax_regime IN ('US', 'UK', 'CA'));
@@ -0,0 +1,2 @@
+-- Nothing to undo: the wider CHECK constraints are left in place, and the down migrations are
+-- only run by hand.
@@ -0,0 +1,18 @@
+-- Allow capital gain distributions, matching migration 000046 of the Postgres migrations.
+-- SQLite doesn't enforce the type columns' lengths, so only the CHECK constraints are widened,
+-- in place as in migration 000005.
+PRAGMA writable_schema = ON;
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''OPENING_BALANCE''', '''OPENING_BALANCE'', ''CAPITAL_GAIN_DISTRIBUTION''')
+WHERE type = 'table' AND name = 'transactions';
+
+UPDATE sqlite_master
+SET sql = replace(sql, '''STOCK_DIVIDEND'')', '''STOCK_DIVIDEND'', ''CAPITAL_GAIN_DISTRIBUTION'')')
+WHERE type = 'table' AND name = 'corporate_actions';
+
+PRAGMA writable_schema = RESET;
+
+-- Other connections only reload the definitions once the schema version changes
+DROP INDEX IF EXISTS idx_corporate_actions_type_date;
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_type_date ON corporate_actions(type, date DESC);
@@ -0,0 +1,15 @@
+-- Drop one organization's portfolio data tables
+DROP TABLE IF EXISTS peer_benchmarks;
+DROP TABLE IF EXISTS rebalance_plan_trades;
+DROP TABLE IF EXISTS rebalance_plans;
+DROP TABLE IF EXISTS blackout_overrides;
+DROP TABLE IF EXISTS blackout_windows;
+DROP TABLE IF EXISTS employer_stock_policies;
+DROP TABLE IF EXISTS stock_plan_events;
+DROP TABLE IF EXISTS stock_plan_grants;
+DROP TABLE IF EXISTS portfolio_actions;
+DROP TABLE IF EXISTS performance_snapshots;
+DROP TABLE IF EXISTS tax_lots;
+DROP TABLE IF EXISTS holdings;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS portfolios;
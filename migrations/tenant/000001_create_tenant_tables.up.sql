@@ -0,0 +1,310 @@
+-- Create the tables holding one organization's portfolio data. This runs with the tenant
+-- schema first on the search path; users and corporate actions stay in the public schema.
+-- It matches the shared tables as of migration 000016 of the main migrations.
+
+-- Portfolios
+CREATE TABLE IF NOT EXISTS portfolios (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    base_currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    cost_basis_method VARCHAR(20) NOT NULL DEFAULT 'FIFO',
+    peer_comparison_opt_in BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_cost_basis_method CHECK (cost_basis_method IN ('FIFO', 'LIFO', 'SPECIFIC_LOT'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolios_user_id ON portfolios(user_id);
+CREATE INDEX IF NOT EXISTS idx_portfolios_created_at ON portfolios(created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolios_user_name ON portfolios(user_id, name);
+CREATE INDEX IF NOT EXISTS idx_portfolios_peer_comparison_opt_in ON portfolios(peer_comparison_opt_in) WHERE peer_comparison_opt_in;
+
+CREATE TRIGGER update_portfolios_updated_at
+    BEFORE UPDATE ON portfolios
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- Transactions
+CREATE TABLE IF NOT EXISTS transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    quantity NUMERIC(20, 8) NOT NULL,
+    price NUMERIC(20, 8),
+    commission NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    notes TEXT,
+    import_batch_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_transaction_type CHECK (type IN (
+        'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+        'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE'
+    )),
+    CONSTRAINT chk_quantity_positive CHECK (quantity > 0),
+    CONSTRAINT chk_price_non_negative CHECK (price IS NULL OR price >= 0),
+    CONSTRAINT chk_commission_non_negative CHECK (commission >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_id ON transactions(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_symbol ON transactions(symbol);
+CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_symbol ON transactions(portfolio_id, symbol);
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_date ON transactions(portfolio_id, date DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_import_batch ON transactions(import_batch_id);
+
+CREATE TRIGGER update_transactions_updated_at
+    BEFORE UPDATE ON transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- Holdings
+CREATE TABLE IF NOT EXISTS holdings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    quantity NUMERIC(20, 8) NOT NULL,
+    cost_basis NUMERIC(20, 8) NOT NULL,
+    avg_cost_price NUMERIC(20, 8) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_holding_quantity CHECK (quantity >= 0),
+    CONSTRAINT chk_holding_cost_basis CHECK (cost_basis >= 0),
+    CONSTRAINT chk_holding_avg_cost CHECK (avg_cost_price >= 0)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_holdings_portfolio_symbol ON holdings(portfolio_id, symbol);
+CREATE INDEX IF NOT EXISTS idx_holdings_portfolio_id ON holdings(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_holdings_symbol ON holdings(symbol);
+CREATE INDEX IF NOT EXISTS idx_holdings_updated_at ON holdings(updated_at DESC);
+
+CREATE TRIGGER update_holdings_updated_at
+    BEFORE UPDATE ON holdings
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- Tax lots
+CREATE TABLE IF NOT EXISTS tax_lots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    purchase_date TIMESTAMP NOT NULL,
+    quantity NUMERIC(20, 8) NOT NULL,
+    cost_basis NUMERIC(20, 8) NOT NULL,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_tax_lot_quantity CHECK (quantity >= 0),
+    CONSTRAINT chk_tax_lot_cost_basis CHECK (cost_basis >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tax_lots_portfolio_id ON tax_lots(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_tax_lots_symbol ON tax_lots(symbol);
+CREATE INDEX IF NOT EXISTS idx_tax_lots_portfolio_symbol ON tax_lots(portfolio_id, symbol);
+CREATE INDEX IF NOT EXISTS idx_tax_lots_purchase_date ON tax_lots(purchase_date);
+CREATE INDEX IF NOT EXISTS idx_tax_lots_transaction_id ON tax_lots(transaction_id);
+
+CREATE TRIGGER update_tax_lots_updated_at
+    BEFORE UPDATE ON tax_lots
+    FOR EACH ROW
+    EXECUTE FUNCTION public.update_updated_at_column();
+
+-- Performance snapshots
+CREATE TABLE IF NOT EXISTS performance_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    date TIMESTAMP NOT NULL,
+    total_value NUMERIC(20, 8) NOT NULL,
+    total_cost_basis NUMERIC(20, 8) NOT NULL,
+    total_return NUMERIC(20, 8) NOT NULL,
+    total_return_pct NUMERIC(10, 4) NOT NULL,
+    day_change NUMERIC(20, 8),
+    day_change_pct NUMERIC(10, 4),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_performance_snapshots_portfolio_date ON performance_snapshots(portfolio_id, date);
+CREATE INDEX IF NOT EXISTS idx_performance_snapshots_portfolio_id ON performance_snapshots(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_performance_snapshots_date ON performance_snapshots(date DESC);
+CREATE INDEX IF NOT EXISTS idx_performance_snapshots_created_at ON performance_snapshots(created_at DESC);
+
+-- Portfolio actions (corporate actions suggested to a portfolio)
+CREATE TABLE IF NOT EXISTS portfolio_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    corporate_action_id UUID NOT NULL REFERENCES public.corporate_actions(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    affected_symbol VARCHAR(20) NOT NULL,
+    shares_affected BIGINT NOT NULL,
+    detected_at TIMESTAMP NOT NULL,
+    reviewed_at TIMESTAMP,
+    applied_at TIMESTAMP,
+    reviewed_by_user_id UUID REFERENCES public.users(id),
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_portfolio_action_status CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED', 'APPLIED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_portfolio_id ON portfolio_actions(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_corporate_action_id ON portfolio_actions(corporate_action_id);
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_status ON portfolio_actions(status);
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_portfolio_status ON portfolio_actions(portfolio_id, status);
+CREATE INDEX IF NOT EXISTS idx_portfolio_actions_detected_at ON portfolio_actions(detected_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_portfolio_actions_unique_pending
+    ON portfolio_actions(portfolio_id, corporate_action_id)
+    WHERE status = 'PENDING';
+
+-- Employer stock plans
+CREATE TABLE IF NOT EXISTS stock_plan_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    grant_date TIMESTAMP NOT NULL,
+    total_shares NUMERIC(20, 8) NOT NULL,
+    vesting_start_date TIMESTAMP NOT NULL,
+    vesting_months INTEGER NOT NULL DEFAULT 0,
+    cliff_months INTEGER NOT NULL DEFAULT 0,
+    vesting_interval_months INTEGER NOT NULL DEFAULT 1,
+    strike_price NUMERIC(20, 8),
+    expiration_date TIMESTAMP,
+    discount_percent NUMERIC(10, 4),
+    lookback BOOLEAN NOT NULL DEFAULT FALSE,
+    notes TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_stock_plan_type CHECK (type IN ('RSU', 'ESPP', 'ISO', 'NSO')),
+    CONSTRAINT chk_stock_plan_total_shares CHECK (total_shares > 0),
+    CONSTRAINT chk_stock_plan_vesting CHECK (vesting_months >= 0 AND cliff_months >= 0 AND vesting_interval_months >= 0),
+    CONSTRAINT chk_stock_plan_strike CHECK (strike_price IS NULL OR strike_price >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_plan_grants_portfolio_id ON stock_plan_grants(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_stock_plan_grants_symbol ON stock_plan_grants(symbol);
+
+CREATE TABLE IF NOT EXISTS stock_plan_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    grant_id UUID NOT NULL REFERENCES stock_plan_grants(id) ON DELETE CASCADE,
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    date TIMESTAMP NOT NULL,
+    shares NUMERIC(20, 8) NOT NULL,
+    shares_withheld NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    fair_market_value NUMERIC(20, 8) NOT NULL,
+    purchase_price NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    cost_basis NUMERIC(20, 8) NOT NULL,
+    ordinary_income NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    bargain_element NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    amt_adjustment NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_stock_plan_event_type CHECK (type IN ('VEST', 'ESPP_PURCHASE', 'EXERCISE')),
+    CONSTRAINT chk_stock_plan_event_shares CHECK (shares > 0 AND shares_withheld >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_plan_events_grant_id ON stock_plan_events(grant_id);
+CREATE INDEX IF NOT EXISTS idx_stock_plan_events_portfolio_id ON stock_plan_events(portfolio_id);
+
+-- Employer stock blackout windows
+CREATE TABLE IF NOT EXISTS employer_stock_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    enforcement VARCHAR(10) NOT NULL DEFAULT 'WARN',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_blackout_enforcement CHECK (enforcement IN ('WARN', 'BLOCK'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_employer_stock_policies_portfolio_id ON employer_stock_policies(portfolio_id);
+
+CREATE TABLE IF NOT EXISTS blackout_windows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_blackout_window_dates CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_blackout_windows_portfolio_id ON blackout_windows(portfolio_id);
+
+CREATE TABLE IF NOT EXISTS blackout_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    window_id UUID NOT NULL,
+    transaction_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    transaction_type VARCHAR(20) NOT NULL,
+    transaction_date TIMESTAMP NOT NULL,
+    enforcement VARCHAR(10) NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_blackout_overrides_portfolio_id ON blackout_overrides(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_blackout_overrides_window_id ON blackout_overrides(window_id);
+
+-- Rebalancing execution plans
+CREATE TABLE IF NOT EXISTS rebalance_plans (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    notes TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    stale_after_days INTEGER NOT NULL DEFAULT 7,
+    last_reminder_at TIMESTAMP,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_rebalance_plan_status CHECK (status IN ('ACTIVE', 'COMPLETED', 'CANCELLED')),
+    CONSTRAINT chk_rebalance_plan_stale_after_days CHECK (stale_after_days >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_plans_portfolio_id ON rebalance_plans(portfolio_id);
+CREATE INDEX IF NOT EXISTS idx_rebalance_plans_status ON rebalance_plans(status);
+
+CREATE TABLE IF NOT EXISTS rebalance_plan_trades (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    plan_id UUID NOT NULL REFERENCES rebalance_plans(id) ON DELETE CASCADE,
+    symbol VARCHAR(20) NOT NULL,
+    side VARCHAR(10) NOT NULL,
+    planned_quantity NUMERIC(20, 8) NOT NULL,
+    planned_price NUMERIC(20, 8) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    executed_quantity NUMERIC(20, 8),
+    executed_price NUMERIC(20, 8),
+    executed_at TIMESTAMP,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_rebalance_trade_side CHECK (side IN ('BUY', 'SELL')),
+    CONSTRAINT chk_rebalance_trade_status CHECK (status IN ('PENDING', 'EXECUTED', 'SKIPPED')),
+    CONSTRAINT chk_rebalance_trade_planned CHECK (planned_quantity > 0 AND planned_price > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rebalance_plan_trades_plan_id ON rebalance_plan_trades(plan_id);
+
+-- Peer benchmarks, aggregated only from this organization's opted-in portfolios
+CREATE TABLE IF NOT EXISTS peer_benchmarks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    bucket VARCHAR(20) NOT NULL,
+    sample_size INTEGER NOT NULL,
+    p10 NUMERIC(12, 6) NOT NULL,
+    p25 NUMERIC(12, 6) NOT NULL,
+    p50 NUMERIC(12, 6) NOT NULL,
+    p75 NUMERIC(12, 6) NOT NULL,
+    p90 NUMERIC(12, 6) NOT NULL,
+    computed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_peer_benchmark_bucket CHECK (bucket IN ('CONCENTRATED', 'FOCUSED', 'DIVERSIFIED')),
+    CONSTRAINT chk_peer_benchmark_sample_size CHECK (sample_size >= 10)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_peer_benchmarks_bucket ON peer_benchmarks(bucket);
@@ -0,0 +1,3 @@
+-- Drop report subscription tables
+DROP TABLE IF EXISTS report_subscription_portfolios;
+DROP TABLE IF EXISTS report_subscriptions;
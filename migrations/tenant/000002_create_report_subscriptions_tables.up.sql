@@ -0,0 +1,23 @@
+-- Create the report subscription tables, matching migration 000018 of the main migrations.
+-- Subscriptions live with the portfolios they cover; their users stay in the public schema.
+CREATE TABLE IF NOT EXISTS report_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    frequency VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_report_subscription_frequency CHECK (frequency IN ('WEEKLY', 'MONTHLY'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_user_id ON report_subscriptions(user_id);
+CREATE INDEX IF NOT EXISTS idx_report_subscriptions_enabled ON report_subscriptions(enabled) WHERE enabled;
+
+CREATE TABLE IF NOT EXISTS report_subscription_portfolios (
+    subscription_id UUID NOT NULL REFERENCES report_subscriptions(id) ON DELETE CASCADE,
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    PRIMARY KEY (subscription_id, portfolio_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_subscription_portfolios_portfolio_id ON report_subscription_portfolios(portfolio_id);
@@ -0,0 +1,6 @@
+-- Drop asset types
+DROP INDEX IF EXISTS idx_holdings_asset_type;
+ALTER TABLE holdings DROP CONSTRAINT IF EXISTS chk_holding_asset_type;
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_asset_type;
+ALTER TABLE holdings DROP COLUMN IF EXISTS asset_type;
+ALTER TABLE transactions DROP COLUMN IF EXISTS asset_type;
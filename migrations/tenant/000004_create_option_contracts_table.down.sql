@@ -0,0 +1,19 @@
+-- Restore asset type and transaction type constraints
+ALTER TABLE holdings DROP CONSTRAINT IF EXISTS chk_holding_asset_type;
+ALTER TABLE holdings ADD CONSTRAINT chk_holding_asset_type CHECK (asset_type IN ('EQUITY', 'CRYPTO'));
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_asset_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_asset_type CHECK (asset_type IN ('EQUITY', 'CRYPTO'));
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+    'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE'
+));
+
+ALTER TABLE holdings DROP COLUMN IF EXISTS multiplier;
+ALTER TABLE transactions DROP COLUMN IF EXISTS multiplier;
+
+-- Drop option contracts
+DROP INDEX IF EXISTS idx_option_contracts_expiry;
+DROP INDEX IF EXISTS idx_option_contracts_underlying;
+DROP TABLE IF EXISTS option_contracts;
@@ -0,0 +1,12 @@
+-- Allow return-of-capital transactions, matching migration 000021 of the main migrations.
+-- Corporate actions are shared and stay in the public schema.
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS chk_transaction_type;
+ALTER TABLE transactions ADD CONSTRAINT chk_transaction_type CHECK (type IN (
+    'BUY', 'SELL', 'DIVIDEND', 'SPLIT', 'MERGER', 'SPINOFF', 'DIVIDEND_REINVEST', 'TICKER_CHANGE',
+    'RSU_VEST', 'ESPP_PURCHASE', 'OPTION_EXERCISE',
+    'BUY_TO_OPEN', 'SELL_TO_CLOSE', 'OPTION_EXPIRATION', 'OPTION_ASSIGNMENT',
+    'RETURN_OF_CAPITAL'
+));
+
+-- The tax report looks up a portfolio's distributions by type
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_type ON transactions(portfolio_id, type);
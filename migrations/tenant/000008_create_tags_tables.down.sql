@@ -0,0 +1,4 @@
+-- Drop tag tables
+DROP TABLE IF EXISTS transaction_tags;
+DROP TABLE IF EXISTS portfolio_tags;
+DROP TABLE IF EXISTS tags;
@@ -0,0 +1,3 @@
+-- Drop simulations table
+DROP INDEX IF EXISTS idx_simulations_portfolio_id;
+DROP TABLE IF EXISTS simulations;
@@ -0,0 +1,16 @@
+-- Create the simulations table, matching migration 000028 of the main migrations. Their jobs
+-- stay in the public schema's queue.
+CREATE TABLE IF NOT EXISTS simulations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    job_id UUID,
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED',
+    parameters TEXT NOT NULL,
+    result TEXT,
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP,
+    CONSTRAINT chk_simulation_status CHECK (status IN ('QUEUED', 'RUNNING', 'SUCCEEDED', 'FAILED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_simulations_portfolio_id ON simulations(portfolio_id, created_at);
@@ -0,0 +1,15 @@
+-- Create the performance_metrics_cache table, matching migration 000033 of the main
+-- migrations
+CREATE TABLE IF NOT EXISTS performance_metrics_cache (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    period VARCHAR(3) NOT NULL,
+    start_date TIMESTAMP NOT NULL,
+    end_date TIMESTAMP NOT NULL,
+    source_stamp VARCHAR(100) NOT NULL,
+    metrics TEXT NOT NULL,
+    computed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_performance_metrics_cache_period CHECK (period IN ('1M', '3M', 'YTD', '1Y', '3Y', 'ALL'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_performance_metrics_cache_portfolio_period ON performance_metrics_cache(portfolio_id, period);
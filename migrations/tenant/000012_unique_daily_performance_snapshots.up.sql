@@ -0,0 +1,10 @@
+-- Allow one performance snapshot per portfolio per day, matching migration 000034 of the
+-- main migrations. Keep the last snapshot of each day and drop the rest first.
+DELETE FROM performance_snapshots s
+USING performance_snapshots newer
+WHERE newer.portfolio_id = s.portfolio_id
+  AND newer.date::date = s.date::date
+  AND (newer.date > s.date OR (newer.date = s.date AND newer.id > s.id));
+
+DROP INDEX IF EXISTS idx_performance_snapshots_portfolio_date;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_performance_snapshots_portfolio_day ON performance_snapshots(portfolio_id, (date::date));
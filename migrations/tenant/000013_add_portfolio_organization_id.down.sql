@@ -0,0 +1,3 @@
+-- Drop the organization that owns each portfolio
+DROP INDEX IF EXISTS idx_portfolios_organization_id;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS organization_id;
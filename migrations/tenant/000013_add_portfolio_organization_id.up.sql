@@ -0,0 +1,4 @@
+-- Portfolios created for an organization belong to it, matching migration 000035 of the main
+-- migrations
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES public.organizations(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS idx_portfolios_organization_id ON portfolios(organization_id) WHERE organization_id IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Drop snapshot_backfill_checkpoints table
+DROP TABLE IF EXISTS snapshot_backfill_checkpoints;
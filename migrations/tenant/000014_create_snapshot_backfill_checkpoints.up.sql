@@ -0,0 +1,8 @@
+-- Create the snapshot_backfill_checkpoints table, matching migration 000037 of the main
+-- migrations. Queued jobs stay in the public schema.
+CREATE TABLE IF NOT EXISTS snapshot_backfill_checkpoints (
+    portfolio_id UUID PRIMARY KEY REFERENCES portfolios(id) ON DELETE CASCADE,
+    start_date TIMESTAMP NOT NULL,
+    last_completed_date TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,5 @@
+-- Drop the gain breakdown of performance snapshots
+ALTER TABLE performance_snapshots DROP COLUMN IF EXISTS fees;
+ALTER TABLE performance_snapshots DROP COLUMN IF EXISTS dividend_income;
+ALTER TABLE performance_snapshots DROP COLUMN IF EXISTS unrealized_gain;
+ALTER TABLE performance_snapshots DROP COLUMN IF EXISTS realized_gain;
@@ -0,0 +1,5 @@
+-- Break each snapshot's gains down, matching migration 000039 of the main migrations
+ALTER TABLE performance_snapshots ADD COLUMN IF NOT EXISTS realized_gain NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN IF NOT EXISTS unrealized_gain NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN IF NOT EXISTS dividend_income NUMERIC(20, 8);
+ALTER TABLE performance_snapshots ADD COLUMN IF NOT EXISTS fees NUMERIC(20, 8);
@@ -0,0 +1,3 @@
+-- Drop broker_connections table
+DROP INDEX IF EXISTS idx_broker_connections_portfolio_id;
+DROP TABLE IF EXISTS broker_connections;
@@ -0,0 +1,22 @@
+-- Create the broker_connections table, matching migration 000044 of the main migrations.
+-- Connections live with the portfolios they sync; their users stay in the public schema.
+CREATE TABLE IF NOT EXISTS broker_connections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    account_id VARCHAR(255) NOT NULL,
+    credentials TEXT NOT NULL,
+    sync_from TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    last_synced_at TIMESTAMP,
+    last_sync_error TEXT,
+    last_sync_report TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_broker_connection_provider CHECK (provider IN ('PLAID')),
+    CONSTRAINT chk_broker_connection_status CHECK (status IN ('ACTIVE', 'FAILED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_broker_connections_portfolio_id ON broker_connections(portfolio_id, created_at);
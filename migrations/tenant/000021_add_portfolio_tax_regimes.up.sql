@@ -0,0 +1,4 @@
+-- Record each portfolio's tax regime, matching migration 000045 of the main migrations
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS tax_regime VARCHAR(2) NOT NULL DEFAULT 'US';
+ALTER TABLE portfolios DROP CONSTRAINT IF EXISTS chk_tax_regime;
+ALTER TABLE portfolios ADD CONSTRAINT chk_tax_regime CHECK (tax_regime IN ('US', 'UK', 'CA'));
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// The admin provisioning methods authenticate with the admin API token rather than a user's
+// access token, so they are called on a client created with WithAccessToken(adminToken).
+// Resources are addressed by the caller's external IDs, and every Put method is an
+// idempotent upsert.
+
+// PutOrganization creates or renames an organization
+// PUT /api/admin/v1/orgs/:org_id
+func (c *Client) PutOrganization(ctx context.Context, orgID string, req UpsertOrganizationRequest) (*OrganizationResponse, error) {
+	var result OrganizationResponse
+	if err := c.do(ctx, http.MethodPut, "/api/admin/v1/orgs/:org_id", pathParams{"org_id": orgID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetOrganization retrieves an organization
+// GET /api/admin/v1/orgs/:org_id
+func (c *Client) GetOrganization(ctx context.Context, orgID string) (*OrganizationResponse, error) {
+	var result OrganizationResponse
+	if err := c.do(ctx, http.MethodGet, "/api/admin/v1/orgs/:org_id", pathParams{"org_id": orgID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PutOrganizationQuotas replaces an organization's quotas
+// PUT /api/admin/v1/orgs/:org_id/quotas
+func (c *Client) PutOrganizationQuotas(ctx context.Context, orgID string, req SetOrganizationQuotasRequest) (*OrganizationResponse, error) {
+	var result OrganizationResponse
+	if err := c.do(ctx, http.MethodPut, "/api/admin/v1/orgs/:org_id/quotas", pathParams{"org_id": orgID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PutOrganizationUser creates an organization member and emails them an invite, or updates
+// an existing member's email
+// PUT /api/admin/v1/orgs/:org_id/users/:user_id
+func (c *Client) PutOrganizationUser(ctx context.Context, orgID, userID string, req UpsertOrganizationUserRequest) (*OrganizationUserResponse, error) {
+	var result OrganizationUserResponse
+	params := pathParams{"org_id": orgID, "user_id": userID}
+	if err := c.do(ctx, http.MethodPut, "/api/admin/v1/orgs/:org_id/users/:user_id", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetOrganizationUser retrieves an organization member
+// GET /api/admin/v1/orgs/:org_id/users/:user_id
+func (c *Client) GetOrganizationUser(ctx context.Context, orgID, userID string) (*OrganizationUserResponse, error) {
+	var result OrganizationUserResponse
+	params := pathParams{"org_id": orgID, "user_id": userID}
+	if err := c.do(ctx, http.MethodGet, "/api/admin/v1/orgs/:org_id/users/:user_id", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PutAPIKey issues or renames an API key. The response's Key is only set when a new key was
+// issued; store it then, as it cannot be retrieved later.
+// PUT /api/admin/v1/orgs/:org_id/api-keys/:key_id
+func (c *Client) PutAPIKey(ctx context.Context, orgID, keyID string, req UpsertAPIKeyRequest) (*APIKeyResponse, error) {
+	var result APIKeyResponse
+	params := pathParams{"org_id": orgID, "key_id": keyID}
+	if err := c.do(ctx, http.MethodPut, "/api/admin/v1/orgs/:org_id/api-keys/:key_id", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAPIKey retrieves an API key's metadata
+// GET /api/admin/v1/orgs/:org_id/api-keys/:key_id
+func (c *Client) GetAPIKey(ctx context.Context, orgID, keyID string) (*APIKeyResponse, error) {
+	var result APIKeyResponse
+	params := pathParams{"org_id": orgID, "key_id": keyID}
+	if err := c.do(ctx, http.MethodGet, "/api/admin/v1/orgs/:org_id/api-keys/:key_id", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeAPIKey revokes an API key. Revoking an unknown or already revoked key succeeds.
+// DELETE /api/admin/v1/orgs/:org_id/api-keys/:key_id
+func (c *Client) RevokeAPIKey(ctx context.Context, orgID, keyID string) error {
+	params := pathParams{"org_id": orgID, "key_id": keyID}
+	return c.do(ctx, http.MethodDelete, "/api/admin/v1/orgs/:org_id/api-keys/:key_id", params, nil, nil, nil)
+}
+
+// GetMaintenance retrieves the maintenance mode in effect
+// GET /api/admin/v1/maintenance
+func (c *Client) GetMaintenance(ctx context.Context) (*MaintenanceResponse, error) {
+	var result MaintenanceResponse
+	if err := c.do(ctx, http.MethodGet, "/api/admin/v1/maintenance", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetMaintenance turns maintenance mode on or off on every instance, overriding the
+// configuration until ClearMaintenance is called
+// PUT /api/admin/v1/maintenance
+func (c *Client) SetMaintenance(ctx context.Context, req SetMaintenanceRequest) (*MaintenanceResponse, error) {
+	var result MaintenanceResponse
+	if err := c.do(ctx, http.MethodPut, "/api/admin/v1/maintenance", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ClearMaintenance returns to the configured maintenance mode, which it returns
+// DELETE /api/admin/v1/maintenance
+func (c *Client) ClearMaintenance(ctx context.Context) (*MaintenanceResponse, error) {
+	var result MaintenanceResponse
+	if err := c.do(ctx, http.MethodDelete, "/api/admin/v1/maintenance", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
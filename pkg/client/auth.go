@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// HealthResponse is the server's health check status
+type HealthResponse struct {
+	Status    string `json:"status"`
+	Timestamp string `json:"timestamp"`
+}
+
+// Health checks whether the server is up. It does not require authentication.
+// GET /health
+func (c *Client) Health(ctx context.Context) (*HealthResponse, error) {
+	var result HealthResponse
+	if err := c.do(ctx, http.MethodGet, "/health", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListErrorCodes lists the codes error responses carry, with their HTTP status and default
+// message. It does not require authentication.
+// GET /api/errors
+func (c *Client) ListErrorCodes(ctx context.Context) (*ErrorCatalogResponse, error) {
+	var result ErrorCatalogResponse
+	if err := c.do(ctx, http.MethodGet, "/api/errors", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Register creates an account and signs the client in with the returned access token
+// POST /api/auth/register
+func (c *Client) Register(ctx context.Context, req RegisterRequest) (*AuthResponse, error) {
+	var result AuthResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/register", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	c.SetAccessToken(result.AccessToken)
+	return &result, nil
+}
+
+// Login signs the client in with the returned access token
+// POST /api/auth/login
+func (c *Client) Login(ctx context.Context, req LoginRequest) (*AuthResponse, error) {
+	var result AuthResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/login", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	c.SetAccessToken(result.AccessToken)
+	return &result, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token, which the client then uses
+// POST /api/auth/refresh
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (*RefreshResponse, error) {
+	var result RefreshResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/refresh", nil, nil, RefreshRequest{RefreshToken: refreshToken}, &result); err != nil {
+		return nil, err
+	}
+	c.SetAccessToken(result.AccessToken)
+	return &result, nil
+}
+
+// ForgotPassword requests a password reset email
+// POST /api/auth/forgot-password
+func (c *Client) ForgotPassword(ctx context.Context, email string) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/forgot-password", nil, nil, ForgotPasswordRequest{Email: email}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ResetPassword sets a new password using a reset token
+// POST /api/auth/reset-password
+func (c *Client) ResetPassword(ctx context.Context, req ResetPasswordRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/reset-password", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ChangePassword replaces the signed-in user's password, given their current one
+// POST /api/auth/change-password
+func (c *Client) ChangePassword(ctx context.Context, req ChangePasswordRequest) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/change-password", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Logout revokes a refresh token and clears the client's access token
+// POST /api/auth/logout
+func (c *Client) Logout(ctx context.Context, refreshToken string) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.do(ctx, http.MethodPost, "/api/auth/logout", nil, nil, LogoutRequest{RefreshToken: refreshToken}, &result); err != nil {
+		return nil, err
+	}
+	c.SetAccessToken("")
+	return &result, nil
+}
+
+// GetCurrentUser retrieves the signed-in user
+// GET /api/auth/me
+func (c *Client) GetCurrentUser(ctx context.Context) (*UserResponse, error) {
+	var result UserResponse
+	if err := c.do(ctx, http.MethodGet, "/api/auth/me", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// CreateBrokerConnection connects a portfolio to a brokerage account
+// POST /api/v1/portfolios/:id/connections
+func (c *Client) CreateBrokerConnection(ctx context.Context, portfolioID string, req CreateBrokerConnectionRequest) (*BrokerConnectionResponse, error) {
+	var result BrokerConnectionResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/connections", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListBrokerConnections lists a portfolio's broker connections
+// GET /api/v1/portfolios/:id/connections
+func (c *Client) ListBrokerConnections(ctx context.Context, portfolioID string) ([]*BrokerConnectionResponse, error) {
+	var result []*BrokerConnectionResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/connections", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetBrokerConnection retrieves a broker connection with the report of its last sync
+// GET /api/v1/portfolios/:id/connections/:connection_id
+func (c *Client) GetBrokerConnection(ctx context.Context, portfolioID, connectionID string) (*BrokerConnectionResponse, error) {
+	var result BrokerConnectionResponse
+	params := pathParams{"id": portfolioID, "connection_id": connectionID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/connections/:connection_id", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteBrokerConnection disconnects a brokerage account, keeping the transactions it synced
+// DELETE /api/v1/portfolios/:id/connections/:connection_id
+func (c *Client) DeleteBrokerConnection(ctx context.Context, portfolioID, connectionID string) error {
+	params := pathParams{"id": portfolioID, "connection_id": connectionID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/portfolios/:id/connections/:connection_id", params, nil, nil, nil)
+}
+
+// SyncBrokerConnection syncs a broker connection now and returns its reconciliation report
+// POST /api/v1/portfolios/:id/connections/:connection_id/sync
+func (c *Client) SyncBrokerConnection(ctx context.Context, portfolioID, connectionID string, req SyncBrokerConnectionRequest) (*BrokerSyncReport, error) {
+	var result BrokerSyncReport
+	params := pathParams{"id": portfolioID, "connection_id": connectionID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/connections/:connection_id/sync", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
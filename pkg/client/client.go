@@ -0,0 +1,405 @@
+// Package client is a typed Go client for the portfolios REST API.
+//
+// Every route the API server registers has a method on Client. Request and response
+// types are the ones the server itself uses, re-exported in types.go, so the client
+// cannot drift from the wire format. The package's tests run every method against the
+// server's router and fail when a route has no client method.
+//
+//	c := client.New("http://localhost:8080")
+//	if _, err := c.Login(ctx, client.LoginRequest{Email: email, Password: password}); err != nil {
+//		return err
+//	}
+//	portfolios, err := c.ListPortfolios(ctx)
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MediaType is the media type the client accepts JSON responses as. It names the API
+// version the client's types match, so the server keeps sending that version's responses
+// after newer versions are released.
+const MediaType = "application/vnd.portfolios.v1+json"
+
+// Client calls the portfolios API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	apiKey string
+
+	jobPollInterval time.Duration
+
+	mu           sync.RWMutex
+	accessToken  string
+	organization string
+	onBehalfOf   string
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to make requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAccessToken sets the bearer token sent with every request
+func WithAccessToken(token string) Option {
+	return func(c *Client) {
+		c.accessToken = token
+	}
+}
+
+// WithAPIKey authenticates requests with an API key issued through the admin API instead
+// of an access token
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithOrganization makes requests act in an organization the user belongs to, giving
+// access to its portfolios
+func WithOrganization(organizationID string) Option {
+	return func(c *Client) {
+		c.organization = organizationID
+	}
+}
+
+// WithOnBehalfOf makes an advisor's requests act on behalf of a client, giving access to
+// the portfolios the client delegated to them
+func WithOnBehalfOf(clientID string) Option {
+	return func(c *Client) {
+		c.onBehalfOf = clientID
+	}
+}
+
+// WithJobPollInterval sets how often methods that queue a job on the server check whether
+// it has finished. It defaults to a second.
+func WithJobPollInterval(interval time.Duration) Option {
+	return func(c *Client) {
+		c.jobPollInterval = interval
+	}
+}
+
+// New creates a client for the API server at baseURL, e.g. "https://portfolios.example.com"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		jobPollInterval: time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// AccessToken returns the bearer token currently sent with requests
+func (c *Client) AccessToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.accessToken
+}
+
+// SetAccessToken replaces the bearer token sent with requests. Register, Login, and
+// RefreshToken call it with the token they receive.
+func (c *Client) SetAccessToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = token
+}
+
+// Organization returns the ID of the organization requests act in, or "" when they act as
+// the user alone
+func (c *Client) Organization() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.organization
+}
+
+// SetOrganization makes later requests act in an organization the user belongs to, or as
+// the user alone when organizationID is ""
+func (c *Client) SetOrganization(organizationID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.organization = organizationID
+}
+
+// OnBehalfOf returns the ID of the client requests act on behalf of, or "" when they act as
+// the user alone
+func (c *Client) OnBehalfOf() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.onBehalfOf
+}
+
+// SetOnBehalfOf makes later requests act on behalf of a client who delegated access to the
+// user, or as the user alone when clientID is ""
+func (c *Client) SetOnBehalfOf(clientID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onBehalfOf = clientID
+}
+
+// APIError is returned when the server responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	// Code is the machine-readable error code, e.g. "PORTFOLIO_NOT_FOUND", when the server sent one
+	Code string
+	// Message is the human-readable error message
+	Message string
+	// FieldErrors lists each invalid field when the request failed validation
+	FieldErrors []FieldError
+	// RequestID identifies the request in the server's logs; quote it when reporting a bug
+	RequestID string
+	// RetryAfter is how long the server asked to wait before retrying, when it was rate
+	// limited or in maintenance mode
+	RetryAfter time.Duration
+	// Body is the raw response body
+	Body []byte
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("API error (status %d, %s): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// pathParams fills the :name placeholders of a route pattern
+type pathParams map[string]string
+
+// expandPath substitutes escaped path parameters into a route pattern such as
+// "/api/v1/portfolios/:id"
+func expandPath(pattern string, params pathParams) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = url.PathEscape(params[segment[1:]])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// newRequest creates an authenticated request for a route pattern. body and query may be nil.
+func (c *Client) newRequest(ctx context.Context, method, pattern string, params pathParams, query url.Values, body interface{}) (*http.Request, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(jsonBody)
+	}
+
+	target := c.baseURL + expandPath(pattern, params)
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", MediaType)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := c.AccessToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	if organization := c.Organization(); organization != "" {
+		req.Header.Set("X-Organization-ID", organization)
+	}
+	if clientID := c.OnBehalfOf(); clientID != "" {
+		req.Header.Set("X-On-Behalf-Of", clientID)
+	}
+	return req, nil
+}
+
+// do sends a request and decodes a JSON response into result, or copies the response body
+// as is when result is a *[]byte. body, query, and result may be nil.
+func (c *Client) do(ctx context.Context, method, pattern string, params pathParams, query url.Values, body, result interface{}) error {
+	req, err := c.newRequest(ctx, method, pattern, params, query, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := newAPIError(resp, respBody)
+		return apiErr
+	}
+
+	if raw, ok := result.(*[]byte); ok {
+		*raw = respBody
+		return nil
+	}
+	if result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// maxEventSize is the largest server-sent event the client reads
+const maxEventSize = 16 << 20
+
+// streamEvents sends a GET request for a server-sent event stream and calls fn with the
+// name and data of each event until the stream ends or fn returns false. The client's
+// timeout does not apply, since streams stay open for as long as what they report on;
+// cancel ctx to stop early.
+func (c *Client) streamEvents(ctx context.Context, pattern string, params pathParams, fn func(event string, data []byte) (bool, error)) error {
+	req, err := c.newRequest(ctx, http.MethodGet, pattern, params, nil, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+	resp, err := streamClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		apiErr := newAPIError(resp, respBody)
+		return apiErr
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line ends the event
+			if len(data) > 0 {
+				more, err := fn(event, []byte(strings.Join(data, "\n")))
+				if err != nil || !more {
+					return err
+				}
+			}
+			event, data = "", nil
+		case strings.HasPrefix(line, ":"):
+			// Comments keep the connection alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimPrefix(strings.TrimPrefix(line, "event:"), " ")
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read event stream: %w", err)
+	}
+	return nil
+}
+
+// download sends a GET request and copies the response body to w as it arrives, so large
+// exports are never held in memory. Like streamEvents, the client's timeout does not apply.
+func (c *Client) download(ctx context.Context, pattern string, params pathParams, query url.Values, w io.Writer) error {
+	req, err := c.newRequest(ctx, http.MethodGet, pattern, params, query, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "*/*")
+
+	downloadClient := *c.httpClient
+	downloadClient.Timeout = 0
+	resp, err := downloadClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		apiErr := newAPIError(resp, respBody)
+		return apiErr
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	return nil
+}
+
+// newAPIError builds an APIError from an error response and its body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	statusCode := resp.StatusCode
+	apiErr := &APIError{StatusCode: statusCode, RequestID: resp.Header.Get("X-Request-ID"), Body: body}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	var errorBody ErrorResponse
+	if err := json.Unmarshal(body, &errorBody); err == nil && errorBody.Error != "" {
+		apiErr.Code = errorBody.Code
+		apiErr.Message = errorBody.Error
+		apiErr.FieldErrors = errorBody.Errors
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+		if apiErr.Message == "" {
+			apiErr.Message = http.StatusText(statusCode)
+		}
+	}
+
+	return apiErr
+}
+
+// dateQuery adds optional YYYY-MM-DD date parameters to a query
+func dateQuery(query url.Values, name string, date time.Time) {
+	if !date.IsZero() {
+		query.Set(name, date.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,1359 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/lenon/portfolios/internal/database"
+	"github.com/lenon/portfolios/internal/handlers"
+	"github.com/lenon/portfolios/internal/jobs"
+	"github.com/lenon/portfolios/internal/middleware"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/repository"
+	"github.com/lenon/portfolios/internal/router"
+	"github.com/lenon/portfolios/internal/services"
+	"github.com/lenon/portfolios/internal/utils"
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+// fakeProvider serves fixed market data so the market data and analytics routes register
+type fakeProvider struct{}
+
+func (fakeProvider) GetQuote(_ context.Context, symbol string) (*services.Quote, error) {
+	return &services.Quote{Symbol: symbol, Price: decimal.NewFromInt(120), LastUpdated: time.Now()}, nil
+}
+
+func (p fakeProvider) GetQuotes(ctx context.Context, symbols []string) (map[string]*services.Quote, error) {
+	quotes := make(map[string]*services.Quote, len(symbols))
+	for _, symbol := range symbols {
+		quotes[symbol], _ = p.GetQuote(ctx, symbol)
+	}
+	return quotes, nil
+}
+
+func (fakeProvider) GetHistoricalPrices(_ context.Context, _ string, startDate, endDate time.Time) ([]*services.HistoricalPrice, error) {
+	var prices []*services.HistoricalPrice
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 7) {
+		price := decimal.NewFromInt(100)
+		prices = append(prices, &services.HistoricalPrice{Date: date, Open: price, High: price, Low: price, Close: price})
+	}
+	return prices, nil
+}
+
+func (fakeProvider) GetExchangeRate(_ context.Context, _, _ string) (decimal.Decimal, error) {
+	return decimal.NewFromFloat(1.1), nil
+}
+
+func (fakeProvider) IsAvailable() bool { return true }
+
+func (fakeProvider) SearchSymbols(_ context.Context, keywords string) ([]*models.Security, error) {
+	return []*models.Security{{Symbol: "AAPL", Name: "Apple Inc", Exchange: "United States", Type: "Equity", Currency: "USD"}}, nil
+}
+
+// fakeBrokerProvider reports one position bought in one trade so the broker connection
+// routes have an account to sync
+type fakeBrokerProvider struct{}
+
+func (fakeBrokerProvider) Name() models.BrokerProvider { return models.BrokerProviderPlaid }
+
+func (fakeBrokerProvider) GetPositions(_ context.Context, _, _ string) ([]services.BrokerPosition, error) {
+	return []services.BrokerPosition{{Symbol: "SCHD", Quantity: decimal.NewFromInt(4), Currency: "USD"}}, nil
+}
+
+func (fakeBrokerProvider) GetTransactions(_ context.Context, _, _ string, start, _ time.Time) ([]services.BrokerTransaction, error) {
+	price := decimal.NewFromInt(80)
+	return []services.BrokerTransaction{{
+		ExternalID: "plaid-tx-1", Type: models.TransactionTypeBuy, Symbol: "SCHD", Date: start,
+		Quantity: decimal.NewFromInt(4), Price: &price, Currency: "USD",
+	}}, nil
+}
+
+// routeRecorder records the route pattern of every request that reached a registered route
+type routeRecorder struct {
+	mu     sync.Mutex
+	routes map[string]bool
+}
+
+func (r *routeRecorder) middleware(c *gin.Context) {
+	if path := c.FullPath(); path != "" {
+		r.mu.Lock()
+		r.routes[c.Request.Method+" "+path] = true
+		r.mu.Unlock()
+	}
+	c.Next()
+}
+
+// testAdminToken is the admin API token the test server accepts
+const testAdminToken = "test-admin-token"
+
+// setupServer starts the API router on a test server backed by an in-memory SQLite database
+// with the same schema and settings a self-hosted deployment gets, wired the same way
+// cmd/api does
+func setupServer(t *testing.T) (*httptest.Server, *gin.Engine, *routeRecorder, *gorm.DB) {
+	db, err := database.Connect("sqlite://:memory:")
+	require.NoError(t, err)
+	db.Logger = db.Logger.LogMode(logger.Silent)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	userRepo := repository.NewUserRepository(db)
+	portfolioRepo := repository.NewPortfolioRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	holdingRepo := repository.NewHoldingRepository(db)
+	taxLotRepo := repository.NewTaxLotRepository(db)
+	portfolioActionRepo := repository.NewPortfolioActionRepository(db)
+	performanceSnapshotRepo := repository.NewPerformanceSnapshotRepository(db)
+
+	organizationRepo := repository.NewOrganizationRepository(db)
+
+	tokenService := services.NewTokenService("test-secret-key-for-jwt-signing-32-chars")
+	authService := services.NewAuthService(
+		userRepo,
+		repository.NewRefreshTokenRepository(db),
+		tokenService,
+		30*time.Minute,
+		7*24*time.Hour,
+		24*time.Hour,
+		30*24*time.Hour,
+	)
+	emailService := services.NewEmailService("", 0, "", "", "")
+	passwordResetService := services.NewPasswordResetService(
+		userRepo,
+		repository.NewPasswordResetRepository(db),
+		emailService,
+		time.Hour,
+	)
+	transactionService := services.NewTransactionService(transactionRepo, portfolioRepo, holdingRepo)
+	blackoutService := services.NewBlackoutService(repository.NewBlackoutRepository(db), portfolioRepo)
+	marketDataService := services.NewMarketDataService(fakeProvider{}, time.Minute)
+	settingsService := services.NewUserSettingsService(repository.NewUserSettingsRepository(db))
+	portfolioService := services.NewPortfolioServiceWithSettings(portfolioRepo, userRepo, organizationRepo, settingsService)
+	queuedJobRepo := repository.NewQueuedJobRepository(db)
+	jobQueueService := services.NewJobQueueService(queuedJobRepo, portfolioRepo)
+	csvImportService := services.NewCSVImportServiceWithQueue(transactionRepo, portfolioRepo, holdingRepo, jobQueueService)
+	recalculationService := services.NewPortfolioRecalculationService(db)
+	openingLotImportService := services.NewOpeningLotImportService(portfolioRepo, csvImportService, recalculationService)
+	brokerBox, err := utils.NewSecretBox("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	require.NoError(t, err)
+	brokerConnectionService := services.NewBrokerConnectionService(
+		repository.NewBrokerConnectionRepository(db), portfolioRepo, transactionRepo, holdingRepo,
+		csvImportService, recalculationService, brokerBox, fakeBrokerProvider{},
+	)
+	taxLotService := services.NewTaxLotServiceWithMarketData(
+		taxLotRepo, portfolioRepo, holdingRepo, transactionRepo, models.DefaultRoundingPolicy(), marketDataService, nil,
+	)
+	reportSubscriptionService := newReportSubscriptionService(db)
+	analyticsService := services.NewPerformanceAnalyticsService(portfolioRepo, transactionRepo, performanceSnapshotRepo, marketDataService)
+	tagService := services.NewTagService(repository.NewTagRepository(db), portfolioRepo, transactionRepo, analyticsService)
+	aggregationService := services.NewAggregationService(portfolioRepo, holdingRepo, marketDataService, analyticsService)
+	groupService := services.NewPortfolioGroupService(repository.NewPortfolioGroupRepository(db), portfolioRepo, aggregationService, analyticsService)
+	pushService := services.NewPushService(repository.NewPushDeviceRepository(db), nil)
+	notificationService := services.NewNotificationServiceWithPush(repository.NewNotificationRepository(db), pushService)
+	organizationService := services.NewOrganizationService(db, emailService, 24*time.Hour)
+	delegationService := services.NewDelegationService(db, emailService, 24*time.Hour)
+	dashboardService := services.NewDashboardService(
+		portfolioRepo, holdingRepo, marketDataService,
+		services.NewCorporateActionCalendarService(repository.NewCorporateActionRepository(db), portfolioRepo, holdingRepo, nil),
+		notificationService, settingsService,
+	)
+	simulationService := services.NewSimulationService(
+		repository.NewSimulationRepository(db), portfolioRepo, transactionRepo, performanceSnapshotRepo, jobQueueService,
+	)
+	backfillService := services.NewSnapshotBackfillService(
+		portfolioRepo, transactionRepo, performanceSnapshotRepo, repository.NewSnapshotBackfillCheckpointRepository(db),
+		marketDataService, jobQueueService, models.DefaultRoundingPolicy(),
+	)
+
+	featureFlagService := services.NewFeatureFlagService(repository.NewFeatureFlagRepository(db), userRepo, nil)
+	h := router.Handlers{
+		Auth:                 handlers.NewAuthHandler(authService, passwordResetService, userRepo, 1800),
+		Password:             handlers.NewPasswordHandler(services.NewPasswordService(userRepo, nil, models.DefaultPasswordPolicy(), utils.DefaultPasswordHasher())),
+		Portfolio:            handlers.NewPortfolioHandlerWithTags(portfolioService, tagService),
+		Transaction:          handlers.NewTransactionHandlerWithTags(transactionService, blackoutService, tagService),
+		Tag:                  handlers.NewTagHandler(tagService),
+		Aggregation:          handlers.NewAggregationHandler(aggregationService),
+		Dashboard:            handlers.NewDashboardHandler(dashboardService),
+		PortfolioGroup:       handlers.NewPortfolioGroupHandler(groupService),
+		Import:               handlers.NewImportHandler(csvImportService),
+		TrackerImport:        handlers.NewTrackerImportHandler(jobQueueService),
+		OpeningLotImport:     handlers.NewOpeningLotImportHandler(openingLotImportService),
+		BrokerConnection:     handlers.NewBrokerConnectionHandler(brokerConnectionService),
+		Reconciliation:       handlers.NewReconciliationHandler(services.NewReconciliationService(portfolioRepo, holdingRepo, csvImportService, recalculationService)),
+		Job:                  handlers.NewJobHandler(jobQueueService),
+		Notification:         handlers.NewNotificationHandler(notificationService),
+		Push:                 handlers.NewPushHandler(pushService),
+		Settings:             handlers.NewUserSettingsHandler(settingsService),
+		Holding:              handlers.NewHoldingHandler(services.NewHoldingServiceWithLedger(holdingRepo, portfolioRepo, transactionRepo, models.DefaultRoundingPolicy())),
+		PerformanceAnalytics: handlers.NewPerformanceAnalyticsHandler(analyticsService),
+		PerformanceSnapshot: handlers.NewPerformanceSnapshotHandler(services.NewPerformanceSnapshotService(
+			performanceSnapshotRepo, portfolioRepo, holdingRepo,
+		)),
+		Certification: handlers.NewPerformanceCertificationHandler(services.NewPerformanceCertificationService(
+			portfolioRepo, transactionRepo, performanceSnapshotRepo, []byte("test-secret"),
+		)),
+		Statement: handlers.NewStatementHandler(services.NewStatementService(
+			portfolioRepo, transactionRepo, performanceSnapshotRepo,
+		)),
+		Export: handlers.NewExportHandler(services.NewExportService(
+			userRepo, repository.NewUserSettingsRepository(db), portfolioRepo, holdingRepo, taxLotRepo,
+			transactionRepo, performanceSnapshotRepo,
+		)),
+		ReportSubscription: handlers.NewReportSubscriptionHandler(reportSubscriptionService),
+		StockPlan:          handlers.NewStockPlanHandler(services.NewStockPlanService(db)),
+		Option: handlers.NewOptionHandler(services.NewOptionService(
+			repository.NewOptionContractRepository(db), portfolioRepo, transactionRepo, holdingRepo,
+			transactionService, marketDataService,
+		)),
+		Blackout: handlers.NewBlackoutHandler(blackoutService),
+		RebalancePlan: handlers.NewRebalancePlanHandler(services.NewRebalancePlanService(
+			repository.NewRebalancePlanRepository(db), portfolioRepo, transactionService,
+		)),
+		PeerComparison: handlers.NewPeerComparisonHandler(services.NewPeerComparisonService(
+			portfolioRepo, holdingRepo, performanceSnapshotRepo, repository.NewPeerBenchmarkRepository(db),
+		)),
+		FeeComparison: handlers.NewFeeComparisonHandler(services.NewFeeComparisonService(portfolioRepo, transactionRepo)),
+		FeeSummary: handlers.NewFeeSummaryHandler(services.NewFeeSummaryService(
+			portfolioRepo, transactionRepo, performanceSnapshotRepo,
+		)),
+		Contribution: handlers.NewContributionHandler(services.NewContributionService(
+			portfolioRepo, transactionRepo, performanceSnapshotRepo,
+		)),
+		Projection: handlers.NewProjectionHandler(services.NewProjectionService(
+			portfolioRepo, transactionRepo, performanceSnapshotRepo,
+		)),
+		Simulation: handlers.NewSimulationHandler(simulationService),
+		WhatIf: handlers.NewWhatIfHandler(services.NewWhatIfService(
+			portfolioRepo, holdingRepo, taxLotRepo, marketDataService, models.DefaultRoundingPolicy(),
+		)),
+		Recalculation:    handlers.NewRecalculationHandler(jobQueueService),
+		SnapshotBackfill: handlers.NewSnapshotBackfillHandler(backfillService),
+		TaxLot:           handlers.NewTaxLotHandler(taxLotService, jobQueueService),
+		PortfolioAction: handlers.NewPortfolioActionHandlerWithCalendar(
+			portfolioActionRepo, portfolioRepo, services.NewPortfolioActionService(db),
+			services.NewCorporateActionCalendarService(repository.NewCorporateActionRepository(db), portfolioRepo, holdingRepo, nil),
+		),
+		MarketData:   handlers.NewMarketDataHandler(marketDataService),
+		Security:     handlers.NewSecurityHandler(services.NewSecurityService(repository.NewSecurityRepository(db), marketDataService)),
+		Admin:        handlers.NewAdminHandler(services.NewAdminProvisioningService(db, emailService, 24*time.Hour)),
+		UserAdmin:    handlers.NewUserAdminHandler(services.NewUserAdminService(db, emailService, time.Hour)),
+		Organization: handlers.NewOrganizationHandler(organizationService),
+		Delegation:   handlers.NewDelegationHandler(delegationService),
+		FeatureFlag:  handlers.NewFeatureFlagHandler(featureFlagService),
+	}
+	maintenance := middleware.NewMaintenance(nil, false, time.Minute)
+	h.Maintenance = handlers.NewMaintenanceHandler(maintenance)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	recorder := &routeRecorder{routes: make(map[string]bool)}
+	engine.Use(recorder.middleware)
+	engine.Use(middleware.RequestID())
+	engine.Use(maintenance.Middleware("/health", "/api/admin/"))
+	router.Register(engine, h, router.Auth{
+		TokenService:      tokenService,
+		APIKeys:           services.NewAPIKeyService(repository.NewAPIKeyRepository(db)),
+		AdminToken:        testAdminToken,
+		Users:             userRepo,
+		Organizations:     organizationService,
+		Delegations:       delegationService,
+		UnsubscribeTokens: reportSubscriptionService,
+		RateLimit:         func(c *gin.Context) { c.Next() },
+		Features:          featureFlagService,
+	})
+
+	// Run queued jobs the way cmd/api does
+	pool := jobs.NewWorkerPool(queuedJobRepo, 2, jobQueueService.Enqueued())
+	pool.Handle(models.QueuedJobTypeCSVImport, services.NotifyingImportJobHandler(
+		services.CSVImportJobHandler(csvImportService), notificationService,
+	))
+	pool.Handle(models.QueuedJobTypeTrackerImport, services.NotifyingImportJobHandler(services.TrackerImportJobHandler(
+		services.NewTrackerImportService(portfolioService, csvImportService, recalculationService),
+	), notificationService))
+	pool.Handle(models.QueuedJobTypeRecalculation, services.RecalculationJobHandler(recalculationService))
+	pool.Handle(models.QueuedJobTypeTaxReport, services.TaxReportJobHandler(taxLotService))
+	pool.Handle(models.QueuedJobTypeSimulation, services.SimulationJobHandler(simulationService))
+	pool.Handle(models.QueuedJobTypeSnapshotBackfill, services.SnapshotBackfillJobHandler(backfillService))
+	pool.Start()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = pool.Stop(ctx)
+	})
+
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+	return server, engine, recorder, db
+}
+
+// newReportSubscriptionService builds the report subscription service the test server uses,
+// so tests can sign unsubscribe tokens the server accepts
+func newReportSubscriptionService(db *gorm.DB) services.ReportSubscriptionService {
+	return services.NewReportSubscriptionService(
+		repository.NewReportSubscriptionRepository(db),
+		repository.NewPortfolioRepository(db),
+		repository.NewTransactionRepository(db),
+		repository.NewPerformanceSnapshotRepository(db),
+		repository.NewHoldingRepository(db),
+		nil,
+		[]byte("test-secret"),
+	)
+}
+
+// requireAPIError asserts that err is an *APIError with the given status
+func requireAPIError(t *testing.T, err error, status int) *client.APIError {
+	t.Helper()
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr), "expected *client.APIError, got %v", err)
+	assert.Equal(t, status, apiErr.StatusCode)
+	return apiErr
+}
+
+// requireAnswered asserts that the server answered, successfully or with a decoded API
+// error, for endpoints whose outcome depends on data the test does not seed
+func requireAnswered(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		var apiErr *client.APIError
+		require.True(t, errors.As(err, &apiErr), "expected *client.APIError, got %v", err)
+	}
+}
+
+func TestClient_CoversEveryRoute(t *testing.T) {
+	server, engine, recorder, db := setupServer(t)
+	ctx := context.Background()
+	c := client.New(server.URL, client.WithJobPollInterval(10*time.Millisecond))
+
+	price := func(v int64) *decimal.Decimal {
+		d := decimal.NewFromInt(v)
+		return &d
+	}
+	day := func(offset int) time.Time {
+		return time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).AddDate(0, 0, offset)
+	}
+	year := client.DateRange{Start: day(0), End: day(364)}
+
+	// Health and authentication
+	health, err := c.Health(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "healthy", health.Status)
+
+	catalog, err := c.ListErrorCodes(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, catalog.Errors)
+
+	_, err = c.GetCurrentUser(ctx)
+	apiErr := requireAPIError(t, err, http.StatusUnauthorized)
+	assert.NotEmpty(t, apiErr.Message)
+	assert.NotEmpty(t, apiErr.RequestID)
+
+	registered, err := c.Register(ctx, client.RegisterRequest{Email: "investor@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	assert.Equal(t, registered.AccessToken, c.AccessToken())
+
+	auth, err := c.Login(ctx, client.LoginRequest{Email: "investor@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	refreshed, err := c.RefreshToken(ctx, auth.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, refreshed.AccessToken, c.AccessToken())
+
+	me, err := c.GetCurrentUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "investor@example.com", me.Email)
+
+	_, err = c.ForgotPassword(ctx, "nobody@example.com")
+	require.NoError(t, err)
+
+	_, err = c.ResetPassword(ctx, client.ResetPasswordRequest{Token: "not-a-token", NewPassword: "AnotherPass123"})
+	requireAPIError(t, err, http.StatusBadRequest)
+
+	_, err = c.ChangePassword(ctx, client.ChangePasswordRequest{CurrentPassword: "WrongPass123", NewPassword: "AnotherPass123"})
+	requireAPIError(t, err, http.StatusBadRequest)
+	_, err = c.ChangePassword(ctx, client.ChangePasswordRequest{CurrentPassword: "SecurePass123", NewPassword: "AnotherPass123"})
+	require.NoError(t, err)
+
+	// Portfolios
+	_, err = c.CreatePortfolio(ctx, client.CreatePortfolioRequest{Name: "Brokerage", BaseCurrency: "US"})
+	apiErr = requireAPIError(t, err, http.StatusBadRequest)
+	assert.Equal(t, []client.FieldError{
+		{Field: "base_currency", Rule: "len", Message: "must be exactly 3 characters long"},
+	}, apiErr.FieldErrors)
+
+	portfolio, err := c.CreatePortfolio(ctx, client.CreatePortfolioRequest{
+		Name:            "Brokerage",
+		BaseCurrency:    "USD",
+		CostBasisMethod: client.CostBasisFIFO,
+		Tags:            []string{"Retirement ", "long-term"},
+	})
+	require.NoError(t, err)
+	portfolioID := portfolio.ID.String()
+	assert.Equal(t, []string{"long-term", "retirement"}, portfolio.Tags)
+
+	list, err := c.ListPortfolios(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, list.Total)
+
+	list, err = c.ListPortfolios(ctx, "retirement", "speculative")
+	require.NoError(t, err)
+	assert.Zero(t, list.Total)
+
+	updated, err := c.UpdatePortfolio(ctx, portfolioID, client.UpdatePortfolioRequest{Name: "Taxable brokerage", Version: &portfolio.Version})
+	require.NoError(t, err)
+	assert.Equal(t, "Taxable brokerage", updated.Name)
+	assert.Equal(t, portfolio.Version+1, updated.Version)
+
+	_, err = c.UpdatePortfolio(ctx, portfolioID, client.UpdatePortfolioRequest{Name: "Lost update", Version: &portfolio.Version})
+	requireAPIError(t, err, http.StatusConflict)
+
+	fetched, err := c.GetPortfolio(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Equal(t, "Taxable brokerage", fetched.Name)
+
+	// Transactions, holdings, and tax lots
+	buy, err := c.CreateTransaction(ctx, portfolioID, client.CreateTransactionRequest{
+		Type:     client.TransactionTypeBuy,
+		Symbol:   "AAPL",
+		Date:     day(0),
+		Quantity: decimal.NewFromInt(10),
+		Price:    price(100),
+	})
+	require.NoError(t, err)
+
+	msft, err := c.CreateTransaction(ctx, portfolioID, client.CreateTransactionRequest{
+		Type:     client.TransactionTypeBuy,
+		Symbol:   "MSFT",
+		Date:     day(1),
+		Quantity: decimal.NewFromInt(5),
+		Price:    price(300),
+		Tags:     []string{"speculative"},
+	})
+	require.NoError(t, err)
+
+	transactions, err := c.ListTransactions(ctx, portfolioID, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, transactions.Transactions, 1)
+
+	ledger, err := c.GetSymbolLedger(ctx, portfolioID, "AAPL")
+	require.NoError(t, err)
+	require.Len(t, ledger.Transactions, 1)
+	require.NotNil(t, ledger.Transactions[0].RunningQuantity)
+	assert.True(t, transactions.Transactions[0].Quantity.Equal(*ledger.Transactions[0].RunningQuantity))
+
+	transactions, err = c.ListTransactions(ctx, portfolioID, "", "speculative")
+	require.NoError(t, err)
+	require.Len(t, transactions.Transactions, 1)
+	assert.Equal(t, msft.ID, transactions.Transactions[0].ID)
+
+	var exported bytes.Buffer
+	require.NoError(t, c.ExportTransactions(ctx, portfolioID, client.ExportFormatCSV, &exported))
+	assert.True(t, strings.HasPrefix(exported.String(), "Date,Type,Symbol,Quantity,Price,Commission,Currency,Notes\n"))
+	assert.Equal(t, 3, strings.Count(exported.String(), "\n"))
+	err = c.ExportTransactions(ctx, "00000000-0000-0000-0000-000000000000", client.ExportFormatJSONLines, &exported)
+	requireAPIError(t, err, http.StatusNotFound)
+
+	tags, err := c.ListTags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*client.TagResponse{
+		{Name: "long-term", Portfolios: 1},
+		{Name: "retirement", Portfolios: 1},
+		{Name: "speculative", Transactions: 1},
+	}, tags.Tags)
+
+	got, err := c.GetTransaction(ctx, buy.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "AAPL", got.Symbol)
+
+	_, err = c.UpdateTransaction(ctx, buy.ID.String(), client.UpdateTransactionRequest{
+		Type:     client.TransactionTypeBuy,
+		Symbol:   "AAPL",
+		Date:     day(0),
+		Quantity: decimal.NewFromInt(10),
+		Price:    price(100),
+		Notes:    "opening position",
+		Version:  &got.Version,
+	})
+	require.NoError(t, err)
+
+	holdings, err := c.ListHoldings(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Len(t, holdings.Holdings, 2)
+
+	holdings, err = c.ListHoldingsAsOf(ctx, portfolioID, day(0))
+	require.NoError(t, err)
+	require.Len(t, holdings.Holdings, 1)
+	assert.Equal(t, "AAPL", holdings.Holdings[0].Symbol)
+	require.NotNil(t, holdings.AsOf)
+
+	holding, err := c.GetHolding(ctx, portfolioID, "AAPL")
+	require.NoError(t, err)
+	assert.True(t, holding.Quantity.Equal(decimal.NewFromInt(10)))
+
+	overview, err := c.GetOverview(ctx, "USD", year)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", overview.Currency)
+	assert.Len(t, overview.Holdings, 2)
+	assert.True(t, overview.TotalValue.IsPositive())
+
+	dashboard, err := c.GetDashboard(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", dashboard.Currency)
+	require.Len(t, dashboard.Portfolios, 1)
+	assert.Equal(t, 2, dashboard.Portfolios[0].Positions)
+	assert.True(t, dashboard.NetWorth.Equal(overview.TotalValue))
+
+	parentGroup, err := c.CreatePortfolioGroup(ctx, client.CreatePortfolioGroupRequest{Name: "Family"})
+	require.NoError(t, err)
+	parentGroupID := parentGroup.ID.String()
+	group, err := c.CreatePortfolioGroup(ctx, client.CreatePortfolioGroupRequest{
+		Name:         "Retirement",
+		ParentID:     &parentGroupID,
+		PortfolioIDs: []string{portfolioID},
+	})
+	require.NoError(t, err)
+	groups, err := c.ListPortfolioGroups(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, groups.Total)
+	group, err = c.GetPortfolioGroup(ctx, group.ID.String())
+	require.NoError(t, err)
+	require.Len(t, group.PortfolioIDs, 1)
+	assert.Equal(t, portfolioID, group.PortfolioIDs[0].String())
+	groupName := "Retirement accounts"
+	group, err = c.UpdatePortfolioGroup(ctx, group.ID.String(), client.UpdatePortfolioGroupRequest{Name: &groupName})
+	require.NoError(t, err)
+	assert.Equal(t, groupName, group.Name)
+	groupOverview, err := c.GetPortfolioGroupOverview(ctx, parentGroupID, "USD", year)
+	require.NoError(t, err)
+	assert.Len(t, groupOverview.Portfolios, 1)
+	_, err = c.GetPortfolioGroupBenchmarkComparison(ctx, parentGroupID, "SPY", year)
+	requireAnswered(t, err)
+	require.NoError(t, c.DeletePortfolioGroup(ctx, parentGroupID))
+
+	report, err := c.Recalculate(ctx, portfolioID, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 2, report.TransactionsReplayed)
+
+	lots, err := c.ListTaxLots(ctx, portfolioID, "")
+	requireAnswered(t, err)
+	if len(lots) > 0 {
+		_, err = c.GetTaxLot(ctx, lots[0].ID)
+		require.NoError(t, err)
+	} else {
+		_, err = c.GetTaxLot(ctx, "00000000-0000-0000-0000-000000000000")
+		requireAPIError(t, err, http.StatusNotFound)
+	}
+
+	_, err = c.AllocateSale(ctx, portfolioID, client.TaxLotAllocationRequest{
+		Symbol: "AAPL", Quantity: decimal.NewFromInt(1), Method: string(client.CostBasisFIFO),
+	})
+	requireAnswered(t, err)
+
+	_, err = c.ListTaxLossOpportunities(ctx, portfolioID, "")
+	requireAnswered(t, err)
+
+	taxReport, err := c.GenerateTaxReport(ctx, portfolioID, client.TaxReportRequest{TaxYear: 2024})
+	require.NoError(t, err)
+	assert.Equal(t, 2024, taxReport.Year)
+	assert.Equal(t, client.TaxRegimeUS, taxReport.Regime)
+
+	regimePortfolio, err := c.SetTaxRegime(ctx, portfolioID, client.TaxRegimeUK)
+	require.NoError(t, err)
+	assert.Equal(t, client.TaxRegimeUK, regimePortfolio.TaxRegime)
+	var taxForm bytes.Buffer
+	err = c.ExportTaxForm(ctx, portfolioID, 2024, client.TaxExportTXF, &taxForm)
+	requireAPIError(t, err, http.StatusUnprocessableEntity)
+	_, err = c.SetTaxRegime(ctx, portfolioID, client.TaxRegimeUS)
+	require.NoError(t, err)
+	require.NoError(t, c.ExportTaxForm(ctx, portfolioID, 2024, client.TaxExportForm8949CSV, &taxForm))
+	assert.True(t, strings.HasPrefix(taxForm.String(), "Description,Date Acquired,Date Sold,"))
+
+	// Imports
+	imported, err := c.ImportBulk(ctx, portfolioID, client.BulkImportRequest{
+		Format: client.ImportFormatGeneric,
+		Transactions: []client.ImportTransactionRequest{{
+			Type: client.TransactionTypeBuy, Symbol: "VTI", Date: day(2), Quantity: decimal.NewFromInt(3), Price: price(200),
+		}},
+	})
+	require.NoError(t, err)
+	assert.True(t, imported.Success)
+
+	job, err := c.ImportCSV(ctx, portfolioID, client.CSVImportRequest{
+		Format:  client.ImportFormatGeneric,
+		CSVData: "not,a,valid\nimport,file,row",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.ImportStatusQueued, job.Status)
+
+	var updates int
+	progress, err := c.WatchImport(ctx, portfolioID, job.BatchID.String(), func(*client.ImportProgress) { updates++ })
+	require.NoError(t, err)
+	assert.Positive(t, updates)
+	assert.True(t, progress.Status.IsFinished())
+
+	opening, err := c.ImportOpeningLots(ctx, portfolioID, client.OpeningLotImportRequest{
+		CSVData: "Symbol,Quantity,Cost Basis,Purchase Date\nVTI,10,1500,2019-03-01\n",
+	})
+	require.NoError(t, err)
+	assert.True(t, opening.Result.Success)
+	assert.Positive(t, opening.TaxLots)
+
+	batches, err := c.ListImportBatches(ctx, portfolioID)
+	require.NoError(t, err)
+	require.NotEmpty(t, batches.Batches)
+	require.NoError(t, c.DeleteImportBatch(ctx, portfolioID, imported.BatchID.String()))
+
+	tracked, err := c.ImportTracker(ctx, client.TrackerImportRequest{
+		Format: client.ImportFormatGhostfolio,
+		Data: `{"accounts":[{"id":"a1","name":"Broker","currency":"USD"}],"activities":[
+			{"accountId":"a1","type":"BUY","symbol":"VTI","date":"2024-01-02T00:00:00.000Z","quantity":2,"unitPrice":200,"fee":1,"currency":"USD"}
+		]}`,
+	})
+	require.NoError(t, err)
+	require.Len(t, tracked.Portfolios, 1)
+
+	// Recalculations, reports and imports ran as queued jobs
+	jobList, err := c.ListJobs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, jobList.Jobs, 4)
+	assert.Equal(t, client.QueuedJobTypeTrackerImport, jobList.Jobs[0].Type)
+	queued, err := c.GetJob(ctx, jobList.Jobs[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, client.QueuedJobStatusSucceeded, queued.Status)
+	assert.Equal(t, 1, tracked.Portfolios[0].TaxLots)
+
+	// Finished imports left notifications
+	notificationList, err := c.ListNotifications(ctx, true, 0)
+	require.NoError(t, err)
+	require.Len(t, notificationList.Notifications, 2)
+	assert.Equal(t, int64(2), notificationList.UnreadCount)
+	assert.Equal(t, client.NotificationTypeImportCompleted, notificationList.Notifications[0].Type)
+	read, err := c.MarkNotificationRead(ctx, notificationList.Notifications[0].ID)
+	require.NoError(t, err)
+	assert.True(t, read.Read)
+	notificationList, err = c.ListNotifications(ctx, false, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), notificationList.UnreadCount)
+
+	// Push devices: without VAPID keys only phones can register
+	_, err = c.GetVAPIDPublicKey(ctx)
+	requireAPIError(t, err, http.StatusServiceUnavailable)
+	device, err := c.RegisterPushDevice(ctx, &client.RegisterPushDeviceRequest{
+		Platform: client.PushPlatformAPNs,
+		Endpoint: "a1b2c3d4e5f60718",
+		Name:     "iPhone",
+	})
+	require.NoError(t, err)
+	devices, err := c.ListPushDevices(ctx)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "iPhone", devices[0].Name)
+	require.NoError(t, c.DeletePushDevice(ctx, device.ID))
+
+	// Performance
+	_, err = c.GetPerformanceMetrics(ctx, portfolioID, year)
+	requireAnswered(t, err)
+	_, err = c.GetPeriodMetrics(ctx, portfolioID, client.PerformancePeriodYTD)
+	requireAnswered(t, err)
+	periods, err := c.ListPeriodMetrics(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.NotNil(t, periods.Periods)
+	_, err = c.GetTWR(ctx, portfolioID, year)
+	requireAnswered(t, err)
+	_, err = c.GetMWR(ctx, portfolioID, year)
+	requireAnswered(t, err)
+	_, err = c.GetAnnualizedReturn(ctx, portfolioID, year)
+	requireAnswered(t, err)
+	_, err = c.GetBenchmarkComparison(ctx, portfolioID, "SPY", year)
+	requireAnswered(t, err)
+	_, err = c.GetContributionAnalysis(ctx, portfolioID, year)
+	requireAnswered(t, err)
+	tagPerformance, err := c.GetTagPerformance(ctx, "retirement", year)
+	require.NoError(t, err)
+	require.Len(t, tagPerformance.Portfolios, 1)
+	_, err = c.GetTagPerformance(ctx, "unused", year)
+	requireAPIError(t, err, http.StatusNotFound)
+	_, err = c.GetPerformanceCertification(ctx, portfolioID, year)
+	requireAPIError(t, err, http.StatusUnprocessableEntity)
+	_, err = c.VerifyPerformanceCertification(ctx, &client.PerformanceCertification{Format: "unknown"})
+	requireAPIError(t, err, http.StatusBadRequest)
+	statement, err := c.GetStatement(ctx, portfolioID, "2024-Q4", client.StatementFormatPDF)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(statement, []byte("%PDF-")))
+	_, err = c.GetStatement(ctx, portfolioID, "2024-13", client.StatementFormatHTML)
+	requireAPIError(t, err, http.StatusBadRequest)
+
+	// Report subscriptions
+	subscription, err := c.CreateReportSubscription(ctx, client.CreateReportSubscriptionRequest{
+		Frequency:    client.ReportFrequencyWeekly,
+		PortfolioIDs: []string{portfolioID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{portfolioID}, subscription.PortfolioIDs)
+	subscriptions, err := c.ListReportSubscriptions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, subscriptions.Subscriptions, 1)
+	monthly := client.ReportFrequencyMonthly
+	subscription, err = c.UpdateReportSubscription(ctx, subscription.ID, client.UpdateReportSubscriptionRequest{Frequency: &monthly})
+	require.NoError(t, err)
+	assert.Equal(t, client.ReportFrequencyMonthly, subscription.Frequency)
+	_, err = c.GetReportSubscription(ctx, subscription.ID)
+	require.NoError(t, err)
+	digest, err := c.PreviewReportSubscription(ctx, subscription.ID)
+	require.NoError(t, err)
+	assert.Len(t, digest.Portfolios, 1)
+	_, err = c.Unsubscribe(ctx, "forged.token.value")
+	requireAPIError(t, err, http.StatusBadRequest)
+	subscriptionModel, err := repository.NewReportSubscriptionRepository(db).FindByID(ctx, subscription.ID)
+	require.NoError(t, err)
+	unsubscribed, err := c.Unsubscribe(ctx, newReportSubscriptionService(db).UnsubscribeToken(subscriptionModel))
+	require.NoError(t, err)
+	assert.False(t, unsubscribed.Enabled)
+	require.NoError(t, c.DeleteReportSubscription(ctx, subscription.ID))
+
+	snapshots, err := c.ListSnapshots(ctx, portfolioID, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, snapshots.Snapshots)
+	_, err = c.ListSnapshotsByDateRange(ctx, portfolioID, year)
+	require.NoError(t, err)
+	_, err = c.GetLatestSnapshot(ctx, portfolioID)
+	requireAPIError(t, err, http.StatusNotFound)
+	backfill, err := c.BackfillSnapshots(ctx, portfolioID, client.DateRange{Start: day(0), End: day(30)}, false)
+	require.NoError(t, err)
+	assert.Positive(t, backfill.Sessions)
+	assert.Equal(t, backfill.Sessions, backfill.Created)
+
+	_, err = c.SetPeerComparisonOptIn(ctx, portfolioID, true)
+	require.NoError(t, err)
+	_, err = c.GetPeerComparison(ctx, portfolioID)
+	requireAnswered(t, err)
+
+	presets, err := c.ListFeeSchedulePresets(ctx)
+	require.NoError(t, err)
+	assert.NotEmpty(t, presets.Presets)
+	fees, err := c.CompareFees(ctx, portfolioID, client.FeeComparisonRequest{Presets: []string{"ZERO_COMMISSION"}})
+	require.NoError(t, err)
+	assert.Len(t, fees.Comparisons, 1)
+	feeSummary, err := c.GetFeeSummary(ctx, portfolioID, 0)
+	require.NoError(t, err)
+	assert.Len(t, feeSummary.Categories, 4) // COMMISSION and the three fee transaction types
+	startingValue, expectedReturn := decimal.NewFromInt(10000), decimal.NewFromInt(7)
+	projection, err := c.ProjectPortfolio(ctx, portfolioID, client.ProjectionRequest{
+		Method:              client.ProjectionMethodExpectedReturn,
+		StartingValue:       &startingValue,
+		MonthlyContribution: decimal.NewFromInt(500),
+		HorizonMonths:       24,
+		ExpectedReturn:      &expectedReturn,
+	})
+	require.NoError(t, err)
+	assert.Len(t, projection.Bands, 24)
+	volatility := decimal.NewFromInt(12)
+	simulation, err := c.RunSimulation(ctx, portfolioID, client.SimulationRequest{
+		AnnualWithdrawal: decimal.NewFromInt(400),
+		Years:            10,
+		InflationRate:    decimal.NewFromInt(2),
+		StartingValue:    &startingValue,
+		ExpectedReturn:   &expectedReturn,
+		Volatility:       &volatility,
+		Paths:            200,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, client.QueuedJobStatusSucceeded, simulation.Status)
+	require.NotNil(t, simulation.Result)
+	assert.Len(t, simulation.Result.Years, 10)
+	simulations, err := c.ListSimulations(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, simulations.Total)
+	_, err = c.GetSimulation(ctx, portfolioID, simulation.ID.String())
+	require.NoError(t, err)
+	require.NoError(t, c.DeleteSimulation(ctx, portfolioID, simulation.ID.String()))
+	whatIf, err := c.AnalyzeWhatIf(ctx, portfolioID, client.WhatIfRequest{
+		Trades:      []client.WhatIfTrade{{Type: client.TransactionTypeBuy, Symbol: "AAPL", Quantity: decimal.NewFromInt(5), Price: decimal.NewFromInt(120)}},
+		CashBalance: decimal.NewFromInt(1000),
+	})
+	require.NoError(t, err)
+	assert.True(t, whatIf.CashBalanceAfter.Equal(decimal.NewFromInt(400)), whatIf.CashBalanceAfter.String())
+
+	// Stock plans and blackout windows
+	grant, err := c.CreateStockPlanGrant(ctx, portfolioID, client.CreateStockPlanGrantRequest{
+		Symbol:                "ACME",
+		Type:                  client.StockPlanTypeRSU,
+		GrantDate:             day(0),
+		TotalShares:           decimal.NewFromInt(400),
+		VestingMonths:         48,
+		CliffMonths:           12,
+		VestingIntervalMonths: 3,
+	})
+	require.NoError(t, err)
+
+	grants, err := c.ListStockPlanGrants(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Len(t, grants, 1)
+
+	_, err = c.GetStockPlanGrant(ctx, portfolioID, grant.ID)
+	require.NoError(t, err)
+
+	_, err = c.RecordVest(ctx, portfolioID, grant.ID, client.RecordRSUVestRequest{
+		Date: day(366), Shares: decimal.NewFromInt(100), FairMarketValue: decimal.NewFromInt(50),
+	})
+	require.NoError(t, err)
+
+	_, err = c.RecordESPPPurchase(ctx, portfolioID, grant.ID, client.RecordESPPPurchaseRequest{
+		Date: day(366), Shares: decimal.NewFromInt(10), PurchaseDateFMV: decimal.NewFromInt(50),
+	})
+	requireAPIError(t, err, http.StatusUnprocessableEntity)
+
+	_, err = c.RecordExercise(ctx, portfolioID, grant.ID, client.RecordOptionExerciseRequest{
+		Date: day(366), Shares: decimal.NewFromInt(10), FairMarketValue: decimal.NewFromInt(50),
+	})
+	requireAPIError(t, err, http.StatusUnprocessableEntity)
+
+	events, err := c.ListStockPlanEvents(ctx, portfolioID, grant.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	_, err = c.GetVestingCalendar(ctx, portfolioID, client.DateRange{Start: day(0), End: day(730)})
+	require.NoError(t, err)
+
+	_, err = c.GetEmployerStockPolicy(ctx, portfolioID)
+	requireAPIError(t, err, http.StatusNotFound)
+
+	policy, err := c.SetEmployerStockPolicy(ctx, portfolioID, client.SetEmployerStockPolicyRequest{
+		Symbol: "ACME", Enforcement: client.BlackoutEnforcementWarn,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ACME", policy.Symbol)
+
+	window, err := c.CreateBlackoutWindow(ctx, portfolioID, client.CreateBlackoutWindowRequest{
+		StartDate: day(80), EndDate: day(95), Reason: "Quarterly earnings",
+	})
+	require.NoError(t, err)
+
+	windows, err := c.ListBlackoutWindows(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Len(t, windows, 1)
+
+	overrides, err := c.ListBlackoutOverrides(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	require.NoError(t, c.DeleteBlackoutWindow(ctx, portfolioID, window.ID))
+	require.NoError(t, c.DeleteEmployerStockPolicy(ctx, portfolioID))
+	require.NoError(t, c.DeleteStockPlanGrant(ctx, portfolioID, grant.ID))
+
+	// Options
+	strike := decimal.NewFromInt(150)
+	expiry := day(30)
+	_, err = c.TradeOption(ctx, portfolioID, client.OptionTradeRequest{
+		Type:       client.TransactionTypeBuyToOpen,
+		Underlying: "AAPL",
+		OptionType: client.OptionTypeCall,
+		Strike:     &strike,
+		Expiry:     &expiry,
+		Date:       day(1),
+		Quantity:   decimal.NewFromInt(1),
+		Price:      decimal.NewFromInt(3),
+	})
+	require.NoError(t, err)
+
+	positions, err := c.ListOptionPositions(ctx, portfolioID)
+	require.NoError(t, err)
+	require.Equal(t, 1, positions.Total)
+	contract := positions.Positions[0].Contract
+	assert.Equal(t, 100, contract.Multiplier)
+
+	early := day(2)
+	_, err = c.SettleOption(ctx, portfolioID, contract.ID.String(), client.SettleOptionRequest{
+		Action: client.OptionSettlementExpire, Date: &early,
+	})
+	requireAPIError(t, err, http.StatusUnprocessableEntity)
+
+	settlement, err := c.SettleOption(ctx, portfolioID, contract.ID.String(), client.SettleOptionRequest{
+		Action: client.OptionSettlementExpire,
+	})
+	require.NoError(t, err)
+	require.Len(t, settlement.Transactions, 1)
+	assert.Equal(t, client.TransactionTypeOptionExpiration, settlement.Transactions[0].Type)
+
+	// Rebalance plans
+	plan, err := c.CreateRebalancePlan(ctx, portfolioID, client.CreateRebalancePlanRequest{
+		Name: "Trim tech",
+		Trades: []client.RebalanceTradeRequest{
+			{Symbol: "AAPL", Side: client.TransactionTypeSell, Quantity: decimal.NewFromInt(2), Price: decimal.NewFromInt(120)},
+			{Symbol: "BND", Side: client.TransactionTypeBuy, Quantity: decimal.NewFromInt(3), Price: decimal.NewFromInt(70)},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Trades, 2)
+
+	plans, err := c.ListRebalancePlans(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Len(t, plans, 1)
+
+	_, err = c.ExecuteRebalanceTrade(ctx, portfolioID, plan.ID, plan.Trades[0].ID, client.ExecuteRebalanceTradeRequest{
+		Date: day(10), Quantity: decimal.NewFromInt(2), Price: decimal.NewFromInt(121),
+	})
+	require.NoError(t, err)
+
+	_, err = c.SkipRebalanceTrade(ctx, portfolioID, plan.ID, plan.Trades[1].ID)
+	require.NoError(t, err)
+
+	_, err = c.GetRebalancePlan(ctx, portfolioID, plan.ID)
+	require.NoError(t, err)
+
+	_, err = c.CancelRebalancePlan(ctx, portfolioID, plan.ID)
+	requireAnswered(t, err)
+	require.NoError(t, c.DeleteRebalancePlan(ctx, portfolioID, plan.ID))
+
+	// Broker connections
+	connection, err := c.CreateBrokerConnection(ctx, portfolioID, client.CreateBrokerConnectionRequest{
+		Provider: models.BrokerProviderPlaid, Name: "Brokerage", AccessToken: "access-sandbox-1", AccountID: "acc-1",
+	})
+	require.NoError(t, err)
+
+	connections, err := c.ListBrokerConnections(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Len(t, connections, 1)
+
+	syncReport, err := c.SyncBrokerConnection(ctx, portfolioID, connection.ID.String(), client.SyncBrokerConnectionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, syncReport.Imported)
+
+	connection, err = c.GetBrokerConnection(ctx, portfolioID, connection.ID.String())
+	require.NoError(t, err)
+	require.NotNil(t, connection.LastSync)
+	require.NoError(t, c.DeleteBrokerConnection(ctx, portfolioID, connection.ID.String()))
+
+	// Reconciliation
+	reconciliation, err := c.Reconcile(ctx, portfolioID, client.ReconcileRequest{CSVData: "Symbol,Quantity\nZZZZ,5\n"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, reconciliation.Discrepancies)
+	assert.Zero(t, reconciliation.Adjusted)
+
+	// Portfolio actions
+	actions, err := c.ListPortfolioActions(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Empty(t, actions)
+
+	_, err = c.ListPendingPortfolioActions(ctx, portfolioID)
+	require.NoError(t, err)
+
+	calendar, err := c.GetCorporateActionCalendar(ctx, portfolioID, client.DateRange{})
+	require.NoError(t, err)
+	assert.Equal(t, portfolioID, calendar.PortfolioID)
+
+	missingAction := "00000000-0000-0000-0000-000000000000"
+	_, err = c.GetPortfolioAction(ctx, portfolioID, missingAction)
+	requireAPIError(t, err, http.StatusNotFound)
+	_, err = c.ApprovePortfolioAction(ctx, portfolioID, missingAction, client.ApproveActionRequest{})
+	requireAPIError(t, err, http.StatusNotFound)
+	_, err = c.RejectPortfolioAction(ctx, portfolioID, missingAction, client.RejectActionRequest{Reason: "not applicable"})
+	requireAPIError(t, err, http.StatusNotFound)
+
+	// Market data
+	quote, err := c.GetQuote(ctx, "AAPL")
+	require.NoError(t, err)
+	assert.True(t, quote.Price.Equal(decimal.NewFromInt(120)))
+
+	quotes, err := c.GetQuotes(ctx, []string{"AAPL", "MSFT"})
+	require.NoError(t, err)
+	assert.Len(t, quotes.Quotes, 2)
+
+	history, err := c.GetHistoricalPrices(ctx, "AAPL", client.DateRange{Start: day(0), End: day(30)})
+	require.NoError(t, err)
+	assert.Equal(t, client.PriceResolution1Day, history.Resolution)
+
+	history, err = c.GetPriceHistory(ctx, "AAPL", client.PriceResolution1Week, client.DateRange{Start: day(0), End: day(30)})
+	require.NoError(t, err)
+	assert.Equal(t, client.PriceResolution1Week, history.Resolution)
+
+	rate, err := c.GetExchangeRate(ctx, "USD", "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", rate.To)
+
+	_, err = c.ClearMarketDataCache(ctx)
+	require.NoError(t, err)
+
+	_, err = c.GetMarketDataQuota(ctx)
+	require.NoError(t, err)
+
+	search, err := c.SearchSecurities(ctx, "apple", 5)
+	require.NoError(t, err)
+	require.Len(t, search.Securities, 1)
+	assert.Equal(t, "AAPL", search.Securities[0].Symbol)
+
+	security, err := c.GetSecurity(ctx, "aapl")
+	require.NoError(t, err)
+	assert.Equal(t, "Apple Inc", security.Name)
+
+	// Account export of everything stored about the user
+	var account bytes.Buffer
+	require.NoError(t, c.ExportAccount(ctx, &account))
+	firstLine, _, _ := strings.Cut(account.String(), "\n")
+	var userRecord client.AccountExportRecord
+	require.NoError(t, json.Unmarshal([]byte(firstLine), &userRecord))
+	assert.Equal(t, "user", string(userRecord.Type))
+
+	// Settings give new portfolios their currency and cost basis method
+	settings, err := c.GetSettings(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", settings.DisplayCurrency)
+	assert.Nil(t, settings.UpdatedAt)
+	timezone, currency, locale, method := "Mars/Olympus_Mons", "eur", "de-de", client.CostBasisLIFO
+	_, err = c.UpdateSettings(ctx, &client.UpdateUserSettingsRequest{Timezone: &timezone})
+	requireAPIError(t, err, http.StatusBadRequest)
+	settings, err = c.UpdateSettings(ctx, &client.UpdateUserSettingsRequest{
+		DisplayCurrency:        &currency,
+		Locale:                 &locale,
+		DefaultCostBasisMethod: &method,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", settings.DisplayCurrency)
+	assert.Equal(t, "de-DE", settings.Locale)
+	defaulted, err := c.CreatePortfolio(ctx, client.CreatePortfolioRequest{Name: "Euro Savings"})
+	require.NoError(t, err)
+	assert.Equal(t, "EUR", defaulted.BaseCurrency)
+	assert.Equal(t, client.CostBasisLIFO, defaulted.CostBasisMethod)
+	require.NoError(t, c.DeletePortfolio(ctx, defaulted.ID.String()))
+
+	// Cleanup and sign out
+	require.NoError(t, c.DeleteTransaction(ctx, msft.ID.String()))
+	require.NoError(t, c.DeletePortfolio(ctx, portfolioID))
+
+	_, err = c.Logout(ctx, auth.RefreshToken)
+	require.NoError(t, err)
+	assert.Empty(t, c.AccessToken())
+
+	// Admin provisioning
+	admin := client.New(server.URL, client.WithAccessToken(testAdminToken))
+
+	_, err = c.GetOrganization(ctx, "acme")
+	requireAPIError(t, err, http.StatusUnauthorized)
+
+	org, err := admin.PutOrganization(ctx, "acme", client.UpsertOrganizationRequest{Name: "Acme"})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", org.ExternalID)
+
+	_, err = admin.GetOrganization(ctx, "acme")
+	require.NoError(t, err)
+
+	zero, one := 0, 1
+	org, err = admin.PutOrganizationQuotas(ctx, "acme", client.SetOrganizationQuotasRequest{
+		MaxUsers: &zero, MaxPortfoliosPerUser: &one, MaxAPIKeys: &zero,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, org.MaxPortfoliosPerUser)
+
+	member, err := admin.PutOrganizationUser(ctx, "acme", "jane", client.UpsertOrganizationUserRequest{Email: "jane@acme.example"})
+	require.NoError(t, err)
+	assert.NotNil(t, member.InviteExpiresAt)
+
+	_, err = admin.GetOrganizationUser(ctx, "acme", "jane")
+	require.NoError(t, err)
+
+	issued, err := admin.PutAPIKey(ctx, "acme", "ci", client.UpsertAPIKeyRequest{UserID: "jane", Name: "CI"})
+	require.NoError(t, err)
+	require.NotEmpty(t, issued.Key)
+
+	key, err := admin.GetAPIKey(ctx, "acme", "ci")
+	require.NoError(t, err)
+	assert.Equal(t, "jane", key.UserID)
+	assert.Empty(t, key.Key)
+
+	// The API key acts as its member, whose portfolio quota applies
+	jane := client.New(server.URL, client.WithAPIKey(issued.Key), client.WithOrganization(org.ID))
+	_, err = jane.CreatePortfolio(ctx, client.CreatePortfolioRequest{
+		Name: "First", BaseCurrency: "USD", CostBasisMethod: client.CostBasisFIFO,
+	})
+	require.NoError(t, err)
+	_, err = jane.CreatePortfolio(ctx, client.CreatePortfolioRequest{
+		Name: "Second", BaseCurrency: "USD", CostBasisMethod: client.CostBasisFIFO,
+	})
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "PORTFOLIO_QUOTA_EXCEEDED", apiErr.Code)
+
+	require.NoError(t, admin.RevokeAPIKey(ctx, "acme", "ci"))
+	_, err = jane.ListPortfolios(ctx)
+	requireAPIError(t, err, http.StatusUnauthorized)
+
+	// Maintenance mode turns away everything but the admin API
+	enabled := true
+	retryAfter := 30
+	state, err := admin.SetMaintenance(ctx, client.SetMaintenanceRequest{Enabled: &enabled, RetryAfterSeconds: &retryAfter})
+	require.NoError(t, err)
+	assert.True(t, state.Enabled)
+	_, err = jane.ListPortfolios(ctx)
+	apiErr = requireAPIError(t, err, http.StatusServiceUnavailable)
+	assert.Equal(t, "MAINTENANCE", apiErr.Code)
+	assert.Equal(t, 30*time.Second, apiErr.RetryAfter)
+	state, err = admin.GetMaintenance(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", state.Source)
+	state, err = admin.ClearMaintenance(ctx)
+	require.NoError(t, err)
+	assert.False(t, state.Enabled)
+	assert.Equal(t, "config", state.Source)
+
+	// User management, after promoting an operator the way set-user-role does
+	ops := client.New(server.URL)
+	opsAuth, err := ops.Register(ctx, client.RegisterRequest{Email: "ops@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	_, err = ops.ListUsers(ctx, client.ListUsersRequest{})
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "FORBIDDEN", apiErr.Code)
+
+	opsUser, err := repository.NewUserRepository(db).FindByEmail("ops@example.com")
+	require.NoError(t, err)
+	opsUser.Role = models.RoleAdmin
+	require.NoError(t, repository.NewUserRepository(db).Save(opsUser))
+
+	users, err := ops.ListUsers(ctx, client.ListUsersRequest{Email: "ACME", Role: client.RoleUser})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), users.Total)
+	janeID := users.Users[0].ID
+	assert.Equal(t, "jane@acme.example", users.Users[0].Email)
+
+	_, err = ops.GetUser(ctx, janeID)
+	require.NoError(t, err)
+
+	usage, err := ops.GetUserUsage(ctx, janeID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), usage.Portfolios)
+
+	_, err = ops.SetUserRole(ctx, opsAuth.User.ID.String(), client.RoleUser)
+	apiErr = requireAPIError(t, err, http.StatusConflict)
+	assert.Equal(t, "ADMIN_SELF_CHANGE", apiErr.Code)
+
+	promoted, err := ops.SetUserRole(ctx, janeID, client.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, client.RoleAdmin, promoted.Role)
+
+	// Feature flags hide experimental routes from the users they are turned off for
+	features, err := ops.GetFeatures(ctx)
+	require.NoError(t, err)
+	assert.True(t, features.Features["what_if"])
+
+	off := false
+	flag, err := ops.SetFeatureFlag(ctx, "what_if", client.SetFeatureFlagRequest{Enabled: &off, UserID: opsAuth.User.ID.String()})
+	require.NoError(t, err)
+	assert.True(t, flag.Enabled)
+	require.Len(t, flag.Overrides, 1)
+	_, err = ops.AnalyzeWhatIf(ctx, "00000000-0000-0000-0000-000000000001", client.WhatIfRequest{})
+	apiErr = requireAPIError(t, err, http.StatusNotFound)
+	assert.Equal(t, "FEATURE_DISABLED", apiErr.Code)
+
+	_, err = ops.SetFeatureFlag(ctx, "graphql", client.SetFeatureFlagRequest{Enabled: &off})
+	apiErr = requireAPIError(t, err, http.StatusNotFound)
+	assert.Equal(t, "FEATURE_NOT_FOUND", apiErr.Code)
+
+	flags, err := ops.ListFeatureFlags(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, flags.Total)
+
+	flag, err = ops.DeleteFeatureFlag(ctx, "what_if", opsAuth.User.ID.String())
+	require.NoError(t, err)
+	assert.Empty(t, flag.Overrides)
+
+	// A disabled member is locked out at once, and back in once enabled
+	bob := client.New(server.URL)
+	_, err = bob.Register(ctx, client.RegisterRequest{Email: "bob@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	bobProfile, err := bob.GetCurrentUser(ctx)
+	require.NoError(t, err)
+
+	disabled, err := ops.DisableUser(ctx, bobProfile.ID.String())
+	require.NoError(t, err)
+	assert.True(t, disabled.Disabled)
+	_, err = bob.ListPortfolios(ctx)
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "ACCOUNT_DISABLED", apiErr.Code)
+
+	_, err = ops.EnableUser(ctx, bobProfile.ID.String())
+	require.NoError(t, err)
+	_, err = bob.ListPortfolios(ctx)
+	require.NoError(t, err)
+
+	reset, err := ops.ForcePasswordReset(ctx, bobProfile.ID.String())
+	require.NoError(t, err)
+	assert.True(t, reset.User.PasswordResetRequired)
+	_, err = bob.Login(ctx, client.LoginRequest{Email: "bob@example.com", Password: "SecurePass123"})
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "PASSWORD_RESET_REQUIRED", apiErr.Code)
+
+	require.NoError(t, ops.DeleteUser(ctx, bobProfile.ID.String()))
+	_, err = ops.GetUser(ctx, bobProfile.ID.String())
+	apiErr = requireAPIError(t, err, http.StatusNotFound)
+	assert.Equal(t, "USER_NOT_FOUND", apiErr.Code)
+
+	// Organizations share portfolios between their members
+	advisor := client.New(server.URL)
+	_, err = advisor.Register(ctx, client.RegisterRequest{Email: "advisor@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	firm, err := advisor.CreateOrganization(ctx, "Advisors")
+	require.NoError(t, err)
+
+	memberships, err := advisor.ListOrganizations(ctx)
+	require.NoError(t, err)
+	require.Len(t, memberships, 1)
+	assert.Equal(t, client.OrgRoleOwner, memberships[0].Role)
+
+	advisor.SetOrganization(firm.ID)
+	_, err = advisor.CreatePortfolio(ctx, client.CreatePortfolioRequest{
+		Name: "Client A", BaseCurrency: "USD", CostBasisMethod: client.CostBasisFIFO,
+	})
+	require.NoError(t, err)
+	advisor.SetOrganization("")
+
+	invitation, err := advisor.InviteToOrganization(ctx, firm.ID, client.CreateInvitationRequest{
+		Email: "analyst@example.com", Role: client.OrgRoleViewer,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, invitation.Token)
+
+	stale, err := advisor.InviteToOrganization(ctx, firm.ID, client.CreateInvitationRequest{
+		Email: "former@example.com", Role: client.OrgRoleMember,
+	})
+	require.NoError(t, err)
+	require.NoError(t, advisor.RevokeInvitation(ctx, firm.ID, stale.ID))
+	invitations, err := advisor.ListInvitations(ctx, firm.ID)
+	require.NoError(t, err)
+	assert.Len(t, invitations, 1)
+
+	analyst := client.New(server.URL)
+	analystAuth, err := analyst.Register(ctx, client.RegisterRequest{Email: "analyst@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	outsider := client.New(server.URL, client.WithAccessToken(analyst.AccessToken()), client.WithOrganization(firm.ID))
+	_, err = outsider.ListPortfolios(ctx)
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "NOT_ORGANIZATION_MEMBER", apiErr.Code)
+	_, err = analyst.AcceptInvitation(ctx, invitation.Token)
+	require.NoError(t, err)
+	analyst.SetOrganization(firm.ID)
+
+	// Viewers can read the organization's portfolios but not change them
+	shared, err := analyst.ListPortfolios(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, shared.Total)
+	_, err = analyst.CreatePortfolio(ctx, client.CreatePortfolioRequest{
+		Name: "Client B", BaseCurrency: "USD", CostBasisMethod: client.CostBasisFIFO,
+	})
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "INSUFFICIENT_ORGANIZATION_ROLE", apiErr.Code)
+
+	members, err := advisor.ListOrganizationMembers(ctx, firm.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+	promotedMember, err := advisor.SetOrganizationMemberRole(ctx, firm.ID, analystAuth.User.ID.String(), client.OrgRoleMember)
+	require.NoError(t, err)
+	assert.Equal(t, client.OrgRoleMember, promotedMember.Role)
+
+	// Organization API keys act as the member who issued them, only in their organization
+	orgKey, err := advisor.CreateOrganizationAPIKey(ctx, firm.ID, "Reporting")
+	require.NoError(t, err)
+	require.NotEmpty(t, orgKey.Key)
+	reporting := client.New(server.URL, client.WithAPIKey(orgKey.Key), client.WithOrganization(firm.ID))
+	shared, err = reporting.ListPortfolios(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, shared.Total)
+
+	orgKeys, err := advisor.ListOrganizationAPIKeys(ctx, firm.ID)
+	require.NoError(t, err)
+	assert.Len(t, orgKeys, 1)
+	require.NoError(t, advisor.RevokeOrganizationAPIKey(ctx, firm.ID, orgKey.ID))
+	_, err = reporting.ListPortfolios(ctx)
+	requireAPIError(t, err, http.StatusUnauthorized)
+
+	require.NoError(t, advisor.RemoveOrganizationMember(ctx, firm.ID, analystAuth.User.ID.String()))
+	_, err = analyst.ListPortfolios(ctx)
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "NOT_ORGANIZATION_MEMBER", apiErr.Code)
+
+	// Advisors work with the portfolios their clients delegate to them
+	planner := client.New(server.URL)
+	plannerAuth, err := planner.Register(ctx, client.RegisterRequest{Email: "planner@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	carol := client.New(server.URL)
+	carolAuth, err := carol.Register(ctx, client.RegisterRequest{Email: "carol@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	retirement, err := carol.CreatePortfolio(ctx, client.CreatePortfolioRequest{
+		Name: "Retirement", BaseCurrency: "USD", CostBasisMethod: client.CostBasisFIFO,
+	})
+	require.NoError(t, err)
+	savings, err := carol.CreatePortfolio(ctx, client.CreatePortfolioRequest{
+		Name: "Savings", BaseCurrency: "USD", CostBasisMethod: client.CostBasisFIFO,
+	})
+	require.NoError(t, err)
+
+	delegation, err := planner.RequestDelegation(ctx, client.CreateDelegationRequest{
+		ClientEmail: "carol@example.com", Access: client.DelegationAccessManage,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, delegation.Token)
+	requests, err := carol.ListDelegations(ctx)
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	assert.Equal(t, client.DelegationStatusPending, requests[0].Status)
+	assert.Equal(t, "planner@example.com", requests[0].AdvisorEmail)
+
+	consented, err := carol.ConsentToDelegation(ctx, delegation.Token, []string{retirement.ID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, client.DelegationStatusActive, consented.Status)
+
+	planner.SetOnBehalfOf(carolAuth.User.ID.String())
+	delegated, err := planner.ListPortfolios(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, delegated.Total)
+	_, err = planner.GetPortfolio(ctx, retirement.ID.String())
+	require.NoError(t, err)
+	_, err = planner.GetPortfolio(ctx, savings.ID.String())
+	requireAPIError(t, err, http.StatusForbidden)
+	err = planner.DeletePortfolio(ctx, retirement.ID.String())
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "DELEGATION_FORBIDDEN", apiErr.Code)
+
+	_, err = carol.SetDelegatedPortfolios(ctx, delegation.ID, []string{retirement.ID.String(), savings.ID.String()})
+	require.NoError(t, err)
+	_, err = planner.GetPortfolio(ctx, savings.ID.String())
+	require.NoError(t, err)
+
+	// The client sees everything done on their behalf, attributed to the advisor
+	audit, err := carol.ListDelegationAuditEvents(ctx, delegation.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, audit, 5)
+	assert.Equal(t, plannerAuth.User.ID.String(), audit[0].AdvisorID)
+	assert.Equal(t, savings.ID.String(), audit[0].PortfolioID)
+	assert.Equal(t, http.StatusOK, audit[0].Status)
+
+	require.NoError(t, carol.RevokeDelegation(ctx, delegation.ID))
+	_, err = planner.ListPortfolios(ctx)
+	apiErr = requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "NOT_DELEGATED", apiErr.Code)
+	planner.SetOnBehalfOf("")
+
+	// Every registered route must have been reached through the client
+	var missing []string
+	for _, route := range engine.Routes() {
+		if key := route.Method + " " + route.Path; !recorder.routes[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	assert.Empty(t, missing, "routes without client coverage")
+}
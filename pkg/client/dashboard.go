@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetDashboard returns everything the home screen shows: the signed-in user's net worth and
+// today's change, the portfolio cards, the top movers, the upcoming corporate actions and the
+// unread notifications. An empty currency uses the user's display currency.
+// GET /api/v1/dashboard
+func (c *Client) GetDashboard(ctx context.Context, currency string) (*Dashboard, error) {
+	var query url.Values
+	if currency != "" {
+		query = url.Values{"currency": {currency}}
+	}
+
+	var result Dashboard
+	if err := c.do(ctx, http.MethodGet, "/api/v1/dashboard", nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
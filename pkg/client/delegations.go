@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListDelegations lists the user's delegations, as advisor or client, and the requests for
+// access sent to their email, newest first
+// GET /api/v1/delegations
+func (c *Client) ListDelegations(ctx context.Context) ([]*DelegationResponse, error) {
+	var result []*DelegationResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/delegations", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RequestDelegation asks a client for access to their portfolios. The consent token is only
+// returned by this request.
+// POST /api/v1/delegations
+func (c *Client) RequestDelegation(ctx context.Context, req CreateDelegationRequest) (*DelegationResponse, error) {
+	var result DelegationResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/delegations", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ConsentToDelegation consents to an advisor's request for access, delegating the chosen
+// portfolios
+// POST /api/v1/delegations/consent
+func (c *Client) ConsentToDelegation(ctx context.Context, token string, portfolioIDs []string) (*DelegationResponse, error) {
+	var result DelegationResponse
+	req := ConsentDelegationRequest{Token: token, PortfolioIDs: portfolioIDs}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/delegations/consent", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetDelegatedPortfolios changes which portfolios the user delegates to an advisor
+// PUT /api/v1/delegations/:delegation_id/portfolios
+func (c *Client) SetDelegatedPortfolios(ctx context.Context, delegationID string, portfolioIDs []string) (*DelegationResponse, error) {
+	var result DelegationResponse
+	params := pathParams{"delegation_id": delegationID}
+	req := SetDelegatedPortfoliosRequest{PortfolioIDs: portfolioIDs}
+	if err := c.do(ctx, http.MethodPut, "/api/v1/delegations/:delegation_id/portfolios", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeDelegation ends a delegation, declines a request for access, or withdraws one
+// DELETE /api/v1/delegations/:delegation_id
+func (c *Client) RevokeDelegation(ctx context.Context, delegationID string) error {
+	params := pathParams{"delegation_id": delegationID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/delegations/:delegation_id", params, nil, nil, nil)
+}
+
+// ListDelegationAuditEvents lists the latest requests an advisor made under a delegation,
+// newest first. A limit of 0 uses the server's default.
+// GET /api/v1/delegations/:delegation_id/audit
+func (c *Client) ListDelegationAuditEvents(ctx context.Context, delegationID string, limit int) ([]*DelegationAuditEventResponse, error) {
+	var query url.Values
+	if limit > 0 {
+		query = url.Values{"limit": {strconv.Itoa(limit)}}
+	}
+	var result []*DelegationAuditEventResponse
+	params := pathParams{"delegation_id": delegationID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/delegations/:delegation_id/audit", params, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strconv"
+)
+
+// ExportTransactions writes a portfolio's transactions to w as CSV in the generic import
+// format, or as JSON Lines, oldest first
+// GET /api/v1/portfolios/:id/transactions/export
+func (c *Client) ExportTransactions(ctx context.Context, portfolioID string, format ExportFormat, w io.Writer) error {
+	query := url.Values{}
+	if format != "" {
+		query.Set("format", string(format))
+	}
+	return c.download(ctx, "/api/v1/portfolios/:id/transactions/export", pathParams{"id": portfolioID}, query, w)
+}
+
+// ExportTaxForm writes a tax year's realized gains to w as Form 8949 CSV, or as a TXF file
+// for tax software. Only portfolios under the US tax regime can be exported.
+// GET /api/v1/portfolios/:id/tax/export
+func (c *Client) ExportTaxForm(ctx context.Context, portfolioID string, year int, format TaxExportFormat, w io.Writer) error {
+	query := url.Values{"year": {strconv.Itoa(year)}}
+	if format != "" {
+		query.Set("format", string(format))
+	}
+	return c.download(ctx, "/api/v1/portfolios/:id/tax/export", pathParams{"id": portfolioID}, query, w)
+}
+
+// ExportAccount writes everything stored about the user to w as JSON Lines: one
+// AccountExportRecord per line
+// GET /api/v1/export
+func (c *Client) ExportAccount(ctx context.Context, w io.Writer) error {
+	return c.download(ctx, "/api/v1/export", nil, nil, w)
+}
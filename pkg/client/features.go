@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetFeatures lists whether each feature is enabled for the authenticated user
+// GET /api/v1/features
+func (c *Client) GetFeatures(ctx context.Context) (*FeaturesResponse, error) {
+	var result FeaturesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/features", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// The feature flag methods below require the authenticated user to have the admin role.
+
+// ListFeatureFlags lists every feature flag with its overrides
+// GET /api/v1/admin/feature-flags
+func (c *Client) ListFeatureFlags(ctx context.Context) (*FeatureFlagListResponse, error) {
+	var result FeatureFlagListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/feature-flags", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetFeatureFlag turns a feature on or off for the user the request names, or for everyone
+// when it names none
+// PUT /api/v1/admin/feature-flags/:name
+func (c *Client) SetFeatureFlag(ctx context.Context, name string, req SetFeatureFlagRequest) (*FeatureFlagResponse, error) {
+	var result FeatureFlagResponse
+	if err := c.do(ctx, http.MethodPut, "/api/v1/admin/feature-flags/:name", pathParams{"name": name}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteFeatureFlag removes the override of a feature for a user, or for everyone when
+// userID is empty
+// DELETE /api/v1/admin/feature-flags/:name
+func (c *Client) DeleteFeatureFlag(ctx context.Context, name, userID string) (*FeatureFlagResponse, error) {
+	query := url.Values{}
+	if userID != "" {
+		query.Set("user_id", userID)
+	}
+
+	var result FeatureFlagResponse
+	if err := c.do(ctx, http.MethodDelete, "/api/v1/admin/feature-flags/:name", pathParams{"name": name}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
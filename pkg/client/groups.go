@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// CreatePortfolioGroup creates a group of the user's portfolios
+// POST /api/v1/groups
+func (c *Client) CreatePortfolioGroup(ctx context.Context, req CreatePortfolioGroupRequest) (*PortfolioGroupResponse, error) {
+	var result PortfolioGroupResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/groups", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPortfolioGroups lists the user's portfolio groups
+// GET /api/v1/groups
+func (c *Client) ListPortfolioGroups(ctx context.Context) (*PortfolioGroupListResponse, error) {
+	var result PortfolioGroupListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/groups", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPortfolioGroup retrieves a portfolio group
+// GET /api/v1/groups/:id
+func (c *Client) GetPortfolioGroup(ctx context.Context, groupID string) (*PortfolioGroupResponse, error) {
+	var result PortfolioGroupResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/groups/:id", pathParams{"id": groupID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdatePortfolioGroup renames, moves or changes the portfolios of a portfolio group
+// PUT /api/v1/groups/:id
+func (c *Client) UpdatePortfolioGroup(ctx context.Context, groupID string, req UpdatePortfolioGroupRequest) (*PortfolioGroupResponse, error) {
+	var result PortfolioGroupResponse
+	if err := c.do(ctx, http.MethodPut, "/api/v1/groups/:id", pathParams{"id": groupID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeletePortfolioGroup deletes a portfolio group, keeping its portfolios
+// DELETE /api/v1/groups/:id
+func (c *Client) DeletePortfolioGroup(ctx context.Context, groupID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/groups/:id", pathParams{"id": groupID}, nil, nil, nil)
+}
+
+// GetPortfolioGroupOverview rolls up the valuation and performance over a period of a group's
+// portfolios, including those of its subgroups. An empty currency uses the server's default.
+// GET /api/v1/groups/:id/overview
+func (c *Client) GetPortfolioGroupOverview(ctx context.Context, groupID, currency string, period DateRange) (*PortfolioGroupOverview, error) {
+	query := period.query()
+	if currency != "" {
+		query.Set("currency", currency)
+	}
+
+	var result PortfolioGroupOverview
+	if err := c.do(ctx, http.MethodGet, "/api/v1/groups/:id/overview", pathParams{"id": groupID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPortfolioGroupBenchmarkComparison compares a group's combined return with a benchmark
+// symbol over a period. An empty benchmarkSymbol uses the server's default benchmark.
+// GET /api/v1/groups/:id/benchmark
+func (c *Client) GetPortfolioGroupBenchmarkComparison(ctx context.Context, groupID, benchmarkSymbol string, period DateRange) (*BenchmarkComparisonResponse, error) {
+	query := period.query()
+	if benchmarkSymbol != "" {
+		query.Set("benchmark_symbol", benchmarkSymbol)
+	}
+	var result BenchmarkComparisonResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/groups/:id/benchmark", pathParams{"id": groupID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ListJobs lists the user's most recent queued imports, recalculations and reports, newest
+// first. A limit of 0 uses the server's default.
+// GET /api/v1/jobs
+func (c *Client) ListJobs(ctx context.Context, limit int) (*QueuedJobListResponse, error) {
+	var query url.Values
+	if limit > 0 {
+		query = url.Values{"limit": {strconv.Itoa(limit)}}
+	}
+	var result QueuedJobListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/jobs", nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetJob retrieves the status, progress and result of a queued job
+// GET /api/v1/jobs/:id
+func (c *Client) GetJob(ctx context.Context, jobID string) (*QueuedJobResponse, error) {
+	var result QueuedJobResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/jobs/:id", pathParams{"id": jobID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WaitForJob polls a queued job until it finishes and returns it. A
+// job that failed is returned along with an error holding the reason.
+func (c *Client) WaitForJob(ctx context.Context, jobID string) (*QueuedJobResponse, error) {
+	ticker := time.NewTicker(c.jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := c.GetJob(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		switch job.Status {
+		case QueuedJobStatusSucceeded:
+			return job, nil
+		case QueuedJobStatusFailed:
+			return job, fmt.Errorf("%s job failed: %s", job.Type, job.Error)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// runJob sends a request that queues a job, waits for the job to finish and decodes its
+// result into result
+func (c *Client) runJob(ctx context.Context, pattern string, params pathParams, query url.Values, body, result interface{}) error {
+	var queued QueuedJobResponse
+	if err := c.do(ctx, http.MethodPost, pattern, params, query, body, &queued); err != nil {
+		return err
+	}
+
+	job, err := c.WaitForJob(ctx, queued.ID)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(job.Result, result); err != nil {
+		return fmt.Errorf("failed to decode job result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// GetQuote retrieves the current quote for a symbol
+// GET /api/v1/market/quote/:symbol
+func (c *Client) GetQuote(ctx context.Context, symbol string) (*Quote, error) {
+	var result Quote
+	if err := c.do(ctx, http.MethodGet, "/api/v1/market/quote/:symbol", pathParams{"symbol": symbol}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetQuotes retrieves current quotes for several symbols
+// POST /api/v1/market/quotes
+func (c *Client) GetQuotes(ctx context.Context, symbols []string) (*QuotesResponse, error) {
+	var result QuotesResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/market/quotes", nil, nil, GetQuotesRequest{Symbols: symbols}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetHistoricalPrices retrieves daily prices for a symbol. A zero period defaults to the
+// last 30 days.
+// GET /api/v1/market/history/:symbol
+func (c *Client) GetHistoricalPrices(ctx context.Context, symbol string, period DateRange) (*HistoricalPricesResponse, error) {
+	return c.GetPriceHistory(ctx, symbol, PriceResolution1Day, period)
+}
+
+// GetPriceHistory retrieves a symbol's price bars at a resolution, oldest first. Intraday
+// resolutions cover at most 31 days; a zero period defaults to a span sized to the
+// resolution, such as the last day of 5m bars.
+// GET /api/v1/market/history/:symbol
+func (c *Client) GetPriceHistory(ctx context.Context, symbol string, resolution PriceResolution, period DateRange) (*HistoricalPricesResponse, error) {
+	query := period.query()
+	if resolution != "" {
+		query.Set("resolution", string(resolution))
+	}
+	var result HistoricalPricesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/market/history/:symbol", pathParams{"symbol": symbol}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetExchangeRate retrieves the exchange rate between two ISO 4217 currency codes
+// GET /api/v1/market/exchange
+func (c *Client) GetExchangeRate(ctx context.Context, from, to string) (*ExchangeRateResponse, error) {
+	query := url.Values{"from": {from}, "to": {to}}
+	var result ExchangeRateResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/market/exchange", nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SearchSecurities finds securities whose symbol or name matches query. A zero limit returns
+// the server's default of 10 matches.
+// GET /api/v1/market/search
+func (c *Client) SearchSecurities(ctx context.Context, query string, limit int) (*SecuritySearchResponse, error) {
+	params := url.Values{"q": {query}}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	var result SecuritySearchResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/market/search", nil, params, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSecurity looks up a security by its symbol
+// GET /api/v1/market/securities/:symbol
+func (c *Client) GetSecurity(ctx context.Context, symbol string) (*SecurityResponse, error) {
+	var result SecurityResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/market/securities/:symbol", pathParams{"symbol": symbol}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ClearMarketDataCache discards the server's cached quotes and prices
+// POST /api/v1/market/cache/clear
+func (c *Client) ClearMarketDataCache(ctx context.Context) (*MessageResponse, error) {
+	var result MessageResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/market/cache/clear", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMarketDataQuota reports the remaining request budget for the market data provider
+// GET /api/v1/market/quota
+func (c *Client) GetMarketDataQuota(ctx context.Context) (*QuotaStatus, error) {
+	var result QuotaStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/market/quota", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// ListNotifications lists the user's most recent notifications, newest first, along with
+// how many are unread. A limit of 0 uses the server's default.
+// GET /api/v1/notifications
+func (c *Client) ListNotifications(ctx context.Context, unreadOnly bool, limit int) (*NotificationListResponse, error) {
+	query := url.Values{}
+	if unreadOnly {
+		query.Set("unread", "true")
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	var result NotificationListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/notifications", nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// MarkNotificationRead marks a notification read
+// POST /api/v1/notifications/:id/read
+func (c *Client) MarkNotificationRead(ctx context.Context, notificationID string) (*NotificationResponse, error) {
+	var result NotificationResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/notifications/:id/read", pathParams{"id": notificationID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// TradeOption buys option contracts to open a position or sells them to close it
+// POST /api/v1/portfolios/:id/options/trades
+func (c *Client) TradeOption(ctx context.Context, portfolioID string, req OptionTradeRequest) (*TransactionResponse, error) {
+	var result TransactionResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/options/trades", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListOptionPositions lists a portfolio's option positions, valued at option quotes where available
+// GET /api/v1/portfolios/:id/options
+func (c *Client) ListOptionPositions(ctx context.Context, portfolioID string) (*OptionPositionListResponse, error) {
+	var result OptionPositionListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/options", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SettleOption closes a portfolio's position in a contract by expiration or assignment
+// POST /api/v1/portfolios/:id/options/:contract_id/settle
+func (c *Client) SettleOption(ctx context.Context, portfolioID, contractID string, req SettleOptionRequest) (*OptionSettlementResponse, error) {
+	var result OptionSettlementResponse
+	params := pathParams{"id": portfolioID, "contract_id": contractID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/options/:contract_id/settle", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
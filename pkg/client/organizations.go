@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListOrganizations lists the organizations the user belongs to and their role in each
+// GET /api/v1/orgs
+func (c *Client) ListOrganizations(ctx context.Context) ([]*MembershipResponse, error) {
+	var result []*MembershipResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/orgs", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateOrganization creates an organization owned by the user
+// POST /api/v1/orgs
+func (c *Client) CreateOrganization(ctx context.Context, name string) (*OrganizationResponse, error) {
+	var result OrganizationResponse
+	req := CreateOrganizationRequest{Name: name}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/orgs", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AcceptInvitation joins the organization an invitation addressed to the user's email was
+// issued for
+// POST /api/v1/orgs/invitations/accept
+func (c *Client) AcceptInvitation(ctx context.Context, token string) (*MembershipResponse, error) {
+	var result MembershipResponse
+	req := AcceptInvitationRequest{Token: token}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/orgs/invitations/accept", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListOrganizationMembers lists an organization's members
+// GET /api/v1/orgs/:org_id/members
+func (c *Client) ListOrganizationMembers(ctx context.Context, organizationID string) ([]*MemberResponse, error) {
+	var result []*MemberResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/orgs/:org_id/members", pathParams{"org_id": organizationID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SetOrganizationMemberRole changes a member's role
+// PUT /api/v1/orgs/:org_id/members/:user_id
+func (c *Client) SetOrganizationMemberRole(ctx context.Context, organizationID, userID string, role OrganizationRole) (*MemberResponse, error) {
+	var result MemberResponse
+	params := pathParams{"org_id": organizationID, "user_id": userID}
+	req := SetMemberRoleRequest{Role: role}
+	if err := c.do(ctx, http.MethodPut, "/api/v1/orgs/:org_id/members/:user_id", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RemoveOrganizationMember removes a member from an organization, or leaves it when userID is
+// the user's own
+// DELETE /api/v1/orgs/:org_id/members/:user_id
+func (c *Client) RemoveOrganizationMember(ctx context.Context, organizationID, userID string) error {
+	params := pathParams{"org_id": organizationID, "user_id": userID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/orgs/:org_id/members/:user_id", params, nil, nil, nil)
+}
+
+// ListInvitations lists an organization's pending invitations
+// GET /api/v1/orgs/:org_id/invitations
+func (c *Client) ListInvitations(ctx context.Context, organizationID string) ([]*InvitationResponse, error) {
+	var result []*InvitationResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/orgs/:org_id/invitations", pathParams{"org_id": organizationID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// InviteToOrganization invites whoever signs in with an email to join an organization. The
+// invitation token is only returned by this request.
+// POST /api/v1/orgs/:org_id/invitations
+func (c *Client) InviteToOrganization(ctx context.Context, organizationID string, req CreateInvitationRequest) (*InvitationResponse, error) {
+	var result InvitationResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/orgs/:org_id/invitations", pathParams{"org_id": organizationID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeInvitation revokes a pending invitation
+// DELETE /api/v1/orgs/:org_id/invitations/:invitation_id
+func (c *Client) RevokeInvitation(ctx context.Context, organizationID, invitationID string) error {
+	params := pathParams{"org_id": organizationID, "invitation_id": invitationID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/orgs/:org_id/invitations/:invitation_id", params, nil, nil, nil)
+}
+
+// ListOrganizationAPIKeys lists an organization's API keys, including revoked ones
+// GET /api/v1/orgs/:org_id/api-keys
+func (c *Client) ListOrganizationAPIKeys(ctx context.Context, organizationID string) ([]*OrganizationAPIKeyResponse, error) {
+	var result []*OrganizationAPIKeyResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/orgs/:org_id/api-keys", pathParams{"org_id": organizationID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateOrganizationAPIKey issues an API key acting as the user in an organization. The key
+// is only returned by this request.
+// POST /api/v1/orgs/:org_id/api-keys
+func (c *Client) CreateOrganizationAPIKey(ctx context.Context, organizationID, name string) (*OrganizationAPIKeyResponse, error) {
+	var result OrganizationAPIKeyResponse
+	req := CreateOrganizationAPIKeyRequest{Name: name}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/orgs/:org_id/api-keys", pathParams{"org_id": organizationID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RevokeOrganizationAPIKey revokes an organization API key
+// DELETE /api/v1/orgs/:org_id/api-keys/:key_id
+func (c *Client) RevokeOrganizationAPIKey(ctx context.Context, organizationID, keyID string) error {
+	params := pathParams{"org_id": organizationID, "key_id": keyID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/orgs/:org_id/api-keys/:key_id", params, nil, nil, nil)
+}
@@ -0,0 +1,22 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetOverview aggregates the value, asset allocation, holdings and performance over a period of
+// all of the signed-in user's portfolios. An empty currency uses the server's default.
+// GET /api/v1/overview
+func (c *Client) GetOverview(ctx context.Context, currency string, period DateRange) (*Overview, error) {
+	query := period.query()
+	if currency != "" {
+		query.Set("currency", currency)
+	}
+
+	var result Overview
+	if err := c.do(ctx, http.MethodGet, "/api/v1/overview", nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DateRange limits a query to dates between Start and End, inclusive. A zero bound lets
+// the server apply its default.
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// query encodes the range as start_date and end_date parameters
+func (r DateRange) query() url.Values {
+	query := url.Values{}
+	dateQuery(query, "start_date", r.Start)
+	dateQuery(query, "end_date", r.End)
+	return query
+}
+
+// GetPerformanceMetrics retrieves a portfolio's performance metrics over a period
+// GET /api/v1/portfolios/:id/performance/metrics
+func (c *Client) GetPerformanceMetrics(ctx context.Context, portfolioID string, period DateRange) (*PerformanceMetricsResponse, error) {
+	var result PerformanceMetricsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/metrics", pathParams{"id": portfolioID}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPeriodMetrics retrieves a portfolio's performance metrics over a standard period ending
+// today, which the server materializes nightly
+// GET /api/v1/portfolios/:id/performance/metrics?period=
+func (c *Client) GetPeriodMetrics(ctx context.Context, portfolioID string, period PerformancePeriod) (*PerformanceMetricsResponse, error) {
+	var result PerformanceMetricsResponse
+	query := url.Values{"period": {string(period)}}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/metrics", pathParams{"id": portfolioID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPeriodMetrics retrieves a portfolio's performance metrics over every standard period
+// its snapshots cover
+// GET /api/v1/portfolios/:id/performance/periods
+func (c *Client) ListPeriodMetrics(ctx context.Context, portfolioID string) (*PeriodMetricsResponse, error) {
+	var result PeriodMetricsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/periods", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTWR retrieves a portfolio's time-weighted return over a period
+// GET /api/v1/portfolios/:id/performance/twr
+func (c *Client) GetTWR(ctx context.Context, portfolioID string, period DateRange) (*TWRResponse, error) {
+	var result TWRResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/twr", pathParams{"id": portfolioID}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetMWR retrieves a portfolio's money-weighted return over a period
+// GET /api/v1/portfolios/:id/performance/mwr
+func (c *Client) GetMWR(ctx context.Context, portfolioID string, period DateRange) (*MWRResponse, error) {
+	var result MWRResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/mwr", pathParams{"id": portfolioID}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetAnnualizedReturn retrieves a portfolio's annualized return over a period
+// GET /api/v1/portfolios/:id/performance/annualized
+func (c *Client) GetAnnualizedReturn(ctx context.Context, portfolioID string, period DateRange) (*AnnualizedReturnResponse, error) {
+	var result AnnualizedReturnResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/annualized", pathParams{"id": portfolioID}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetBenchmarkComparison compares a portfolio's return with a benchmark symbol over a
+// period. An empty benchmarkSymbol uses the server's default benchmark.
+// GET /api/v1/portfolios/:id/performance/benchmark
+func (c *Client) GetBenchmarkComparison(ctx context.Context, portfolioID, benchmarkSymbol string, period DateRange) (*BenchmarkComparisonResponse, error) {
+	query := period.query()
+	if benchmarkSymbol != "" {
+		query.Set("benchmark_symbol", benchmarkSymbol)
+	}
+	var result BenchmarkComparisonResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/benchmark", pathParams{"id": portfolioID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetContributionAnalysis splits a portfolio's growth over a period into its net
+// contributions and the returns of its investments
+// GET /api/v1/portfolios/:id/performance/contributions
+func (c *Client) GetContributionAnalysis(ctx context.Context, portfolioID string, period DateRange) (*ContributionAnalysis, error) {
+	var result ContributionAnalysis
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/contributions", pathParams{"id": portfolioID}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListSnapshots lists a portfolio's daily performance snapshots, newest first
+// GET /api/v1/portfolios/:id/snapshots
+func (c *Client) ListSnapshots(ctx context.Context, portfolioID string, limit, offset int) (*PerformanceSnapshotListResponse, error) {
+	query := url.Values{
+		"limit":  {strconv.Itoa(limit)},
+		"offset": {strconv.Itoa(offset)},
+	}
+	var result PerformanceSnapshotListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/snapshots", pathParams{"id": portfolioID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListSnapshotsByDateRange lists a portfolio's performance snapshots within a period
+// GET /api/v1/portfolios/:id/snapshots/range
+func (c *Client) ListSnapshotsByDateRange(ctx context.Context, portfolioID string, period DateRange) (*PerformanceSnapshotListResponse, error) {
+	var result PerformanceSnapshotListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/snapshots/range", pathParams{"id": portfolioID}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetLatestSnapshot retrieves a portfolio's most recent performance snapshot
+// GET /api/v1/portfolios/:id/snapshots/latest
+func (c *Client) GetLatestSnapshot(ctx context.Context, portfolioID string) (*PerformanceSnapshotResponse, error) {
+	var result PerformanceSnapshotResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/snapshots/latest", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// BackfillSnapshots fills in a portfolio's missing daily performance snapshots over a period
+// from its transaction history, valued at historical closing prices. A zero period.Start
+// starts at the first transaction and a zero period.End at the last closed session. A
+// backfill that was interrupted resumes where it stopped unless restart is set. The backfill
+// is queued on the server, and this waits for it to finish.
+// POST /api/v1/portfolios/:id/snapshots/backfill
+func (c *Client) BackfillSnapshots(ctx context.Context, portfolioID string, period DateRange, restart bool) (*SnapshotBackfillResult, error) {
+	query := period.query()
+	if restart {
+		query.Set("restart", "true")
+	}
+	var result SnapshotBackfillResult
+	if err := c.runJob(ctx, "/api/v1/portfolios/:id/snapshots/backfill", pathParams{"id": portfolioID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPerformanceCertification exports a signed report of a portfolio's time-weighted return
+// over a period, with the sub-periods, cash flows and valuations it was computed from
+// GET /api/v1/portfolios/:id/performance/certification
+func (c *Client) GetPerformanceCertification(ctx context.Context, portfolioID string, period DateRange) (*PerformanceCertification, error) {
+	var result PerformanceCertification
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/performance/certification", pathParams{"id": portfolioID}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// VerifyPerformanceCertification checks that a certification is unchanged since the server
+// signed it
+// POST /api/v1/performance-certifications/verify
+func (c *Client) VerifyPerformanceCertification(ctx context.Context, certification *PerformanceCertification) (*CertificationVerificationResponse, error) {
+	var result CertificationVerificationResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/performance-certifications/verify", nil, nil, certification, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetStatement renders a portfolio's statement for a month (2024-11) or quarter (2024-Q4)
+// and returns the document, a PDF unless format asks for an HTML preview
+// GET /api/v1/portfolios/:id/statements
+func (c *Client) GetStatement(ctx context.Context, portfolioID, period string, format StatementFormat) ([]byte, error) {
+	query := url.Values{"period": {period}}
+	if format != "" {
+		query.Set("format", string(format))
+	}
+	var document []byte
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/statements", pathParams{"id": portfolioID}, query, nil, &document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListPortfolioActions lists every corporate action proposed for a portfolio
+// GET /api/v1/portfolios/:id/actions
+func (c *Client) ListPortfolioActions(ctx context.Context, portfolioID string) ([]*PortfolioActionResponse, error) {
+	var result []*PortfolioActionResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/actions", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListPendingPortfolioActions lists the corporate actions awaiting review
+// GET /api/v1/portfolios/:id/actions/pending
+func (c *Client) ListPendingPortfolioActions(ctx context.Context, portfolioID string) ([]*PortfolioActionResponse, error) {
+	var result []*PortfolioActionResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/actions/pending", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetCorporateActionCalendar lists the known corporate actions of a portfolio's held symbols
+// in a period, which defaults to the next 90 days
+// GET /api/v1/portfolios/:id/actions/calendar
+func (c *Client) GetCorporateActionCalendar(ctx context.Context, portfolioID string, period DateRange) (*CorporateActionCalendarResponse, error) {
+	query := url.Values{}
+	dateQuery(query, "from", period.Start)
+	dateQuery(query, "to", period.End)
+	var result CorporateActionCalendarResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/actions/calendar", params, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPortfolioAction retrieves a proposed corporate action
+// GET /api/v1/portfolios/:id/actions/:action_id
+func (c *Client) GetPortfolioAction(ctx context.Context, portfolioID, actionID string) (*PortfolioActionResponse, error) {
+	var result PortfolioActionResponse
+	params := pathParams{"id": portfolioID, "action_id": actionID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/actions/:action_id", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ApprovePortfolioAction approves a corporate action and applies it to the holdings
+// POST /api/v1/portfolios/:id/actions/:action_id/approve
+func (c *Client) ApprovePortfolioAction(ctx context.Context, portfolioID, actionID string, req ApproveActionRequest) (*PortfolioActionResponse, error) {
+	var result PortfolioActionResponse
+	params := pathParams{"id": portfolioID, "action_id": actionID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/actions/:action_id/approve", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RejectPortfolioAction rejects a corporate action
+// POST /api/v1/portfolios/:id/actions/:action_id/reject
+func (c *Client) RejectPortfolioAction(ctx context.Context, portfolioID, actionID string, req RejectActionRequest) (*PortfolioActionResponse, error) {
+	var result PortfolioActionResponse
+	params := pathParams{"id": portfolioID, "action_id": actionID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/actions/:action_id/reject", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,188 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CreatePortfolio creates a portfolio
+// POST /api/v1/portfolios
+func (c *Client) CreatePortfolio(ctx context.Context, req CreatePortfolioRequest) (*PortfolioResponse, error) {
+	var result PortfolioResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPortfolios lists the signed-in user's portfolios, optionally only those carrying every
+// one of tags
+// GET /api/v1/portfolios
+func (c *Client) ListPortfolios(ctx context.Context, tags ...string) (*PortfolioListResponse, error) {
+	var query url.Values
+	if len(tags) > 0 {
+		query = url.Values{"tag": tags}
+	}
+	var result PortfolioListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios", nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPortfolio retrieves a portfolio
+// GET /api/v1/portfolios/:id
+func (c *Client) GetPortfolio(ctx context.Context, portfolioID string) (*PortfolioResponse, error) {
+	var result PortfolioResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdatePortfolio updates a portfolio's name and description. The request must carry the
+// Version of the portfolio it was made against; a stale version fails with VERSION_CONFLICT.
+// PUT /api/v1/portfolios/:id
+func (c *Client) UpdatePortfolio(ctx context.Context, portfolioID string, req UpdatePortfolioRequest) (*PortfolioResponse, error) {
+	var result PortfolioResponse
+	if err := c.do(ctx, http.MethodPut, "/api/v1/portfolios/:id", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeletePortfolio deletes a portfolio
+// DELETE /api/v1/portfolios/:id
+func (c *Client) DeletePortfolio(ctx context.Context, portfolioID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/portfolios/:id", pathParams{"id": portfolioID}, nil, nil, nil)
+}
+
+// ListHoldings lists a portfolio's current holdings
+// GET /api/v1/portfolios/:id/holdings
+func (c *Client) ListHoldings(ctx context.Context, portfolioID string) (*HoldingListResponse, error) {
+	var result HoldingListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/holdings", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListHoldingsAsOf lists the holdings a portfolio had at the end of a past day, rebuilt from
+// its transactions up to that day
+// GET /api/v1/portfolios/:id/holdings?as_of=
+func (c *Client) ListHoldingsAsOf(ctx context.Context, portfolioID string, asOf time.Time) (*HoldingListResponse, error) {
+	query := url.Values{}
+	dateQuery(query, "as_of", asOf)
+	var result HoldingListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/holdings", pathParams{"id": portfolioID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetHolding retrieves a portfolio's holding of a symbol
+// GET /api/v1/portfolios/:id/holdings/:symbol
+func (c *Client) GetHolding(ctx context.Context, portfolioID, symbol string) (*HoldingResponse, error) {
+	var result HoldingResponse
+	params := pathParams{"id": portfolioID, "symbol": symbol}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/holdings/:symbol", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Recalculate replays a portfolio's transactions to rebuild its holdings and tax lots and
+// reports where the stored state differed. With dryRun set nothing is written. The
+// recalculation is queued on the server, and this waits for it to finish.
+// POST /api/v1/portfolios/:id/recalculate
+func (c *Client) Recalculate(ctx context.Context, portfolioID string, dryRun bool) (*RecalculationReport, error) {
+	var query url.Values
+	if dryRun {
+		query = url.Values{"dry_run": {"true"}}
+	}
+	var result RecalculationReport
+	if err := c.runJob(ctx, "/api/v1/portfolios/:id/recalculate", pathParams{"id": portfolioID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetPeerComparisonOptIn opts a portfolio in or out of anonymized peer comparison
+// PUT /api/v1/portfolios/:id/peer-comparison/opt-in
+func (c *Client) SetPeerComparisonOptIn(ctx context.Context, portfolioID string, optIn bool) (*PeerComparisonOptInResponse, error) {
+	var result PeerComparisonOptInResponse
+	req := PeerComparisonOptInRequest{OptIn: &optIn}
+	if err := c.do(ctx, http.MethodPut, "/api/v1/portfolios/:id/peer-comparison/opt-in", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetPeerComparison reports where a portfolio's risk-adjusted return falls among its peers
+// GET /api/v1/portfolios/:id/peer-comparison
+func (c *Client) GetPeerComparison(ctx context.Context, portfolioID string) (*PeerComparisonResult, error) {
+	var result PeerComparisonResult
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/peer-comparison", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListFeeSchedulePresets lists the built-in broker fee schedules
+// GET /api/v1/fee-schedules
+func (c *Client) ListFeeSchedulePresets(ctx context.Context) (*FeeSchedulePresetsResponse, error) {
+	var result FeeSchedulePresetsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/fee-schedules", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CompareFees recomputes a portfolio's historical trading fees under other fee schedules
+// POST /api/v1/portfolios/:id/fee-comparison
+func (c *Client) CompareFees(ctx context.Context, portfolioID string, req FeeComparisonRequest) (*FeeComparisonResult, error) {
+	var result FeeComparisonResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/fee-comparison", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetFeeSummary totals the fees a portfolio paid in a year by category and measures their
+// drag on its annualized return. A year of 0 summarizes the current one.
+// GET /api/v1/portfolios/:id/fees
+func (c *Client) GetFeeSummary(ctx context.Context, portfolioID string, year int) (*FeeSummary, error) {
+	var query url.Values
+	if year > 0 {
+		query = url.Values{"year": {strconv.Itoa(year)}}
+	}
+	var result FeeSummary
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/fees", pathParams{"id": portfolioID}, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ProjectPortfolio simulates a portfolio's future value under monthly contributions
+// POST /api/v1/portfolios/:id/projection
+func (c *Client) ProjectPortfolio(ctx context.Context, portfolioID string, req ProjectionRequest) (*ProjectionResult, error) {
+	var result ProjectionResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/projection", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AnalyzeWhatIf previews the allocation, realized gains, taxes and cash of a portfolio after
+// hypothetical trades, without recording them
+// POST /api/v1/portfolios/:id/what-if
+func (c *Client) AnalyzeWhatIf(ctx context.Context, portfolioID string, req WhatIfRequest) (*WhatIfResult, error) {
+	var result WhatIfResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/what-if", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetVAPIDPublicKey returns the key browsers subscribe to push notifications with. It fails
+// with 503 when the server has no VAPID keys.
+// GET /api/v1/push/vapid-public-key
+func (c *Client) GetVAPIDPublicKey(ctx context.Context) (*VAPIDPublicKeyResponse, error) {
+	var result VAPIDPublicKeyResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/push/vapid-public-key", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListPushDevices lists the devices registered for the user's push notifications
+// GET /api/v1/push/devices
+func (c *Client) ListPushDevices(ctx context.Context) ([]*PushDeviceResponse, error) {
+	var result []*PushDeviceResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/push/devices", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RegisterPushDevice registers a browser subscription or phone for push notifications.
+// Registering an endpoint again updates its keys.
+// POST /api/v1/push/devices
+func (c *Client) RegisterPushDevice(ctx context.Context, req *RegisterPushDeviceRequest) (*PushDeviceResponse, error) {
+	var result PushDeviceResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/push/devices", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeletePushDevice unregisters a device
+// DELETE /api/v1/push/devices/:id
+func (c *Client) DeletePushDevice(ctx context.Context, deviceID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/push/devices/:id", pathParams{"id": deviceID}, nil, nil, nil)
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// CreateRebalancePlan saves a rebalancing execution plan
+// POST /api/v1/portfolios/:id/rebalance-plans
+func (c *Client) CreateRebalancePlan(ctx context.Context, portfolioID string, req CreateRebalancePlanRequest) (*RebalancePlanResponse, error) {
+	var result RebalancePlanResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/rebalance-plans", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListRebalancePlans lists a portfolio's rebalance plans
+// GET /api/v1/portfolios/:id/rebalance-plans
+func (c *Client) ListRebalancePlans(ctx context.Context, portfolioID string) ([]*RebalancePlanResponse, error) {
+	var result []*RebalancePlanResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/rebalance-plans", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetRebalancePlan retrieves a rebalance plan with its trades and drift
+// GET /api/v1/portfolios/:id/rebalance-plans/:plan_id
+func (c *Client) GetRebalancePlan(ctx context.Context, portfolioID, planID string) (*RebalancePlanResponse, error) {
+	var result RebalancePlanResponse
+	params := pathParams{"id": portfolioID, "plan_id": planID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/rebalance-plans/:plan_id", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteRebalancePlan deletes a rebalance plan
+// DELETE /api/v1/portfolios/:id/rebalance-plans/:plan_id
+func (c *Client) DeleteRebalancePlan(ctx context.Context, portfolioID, planID string) error {
+	params := pathParams{"id": portfolioID, "plan_id": planID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/portfolios/:id/rebalance-plans/:plan_id", params, nil, nil, nil)
+}
+
+// CancelRebalancePlan cancels a rebalance plan's remaining trades
+// POST /api/v1/portfolios/:id/rebalance-plans/:plan_id/cancel
+func (c *Client) CancelRebalancePlan(ctx context.Context, portfolioID, planID string) (*RebalancePlanResponse, error) {
+	var result RebalancePlanResponse
+	params := pathParams{"id": portfolioID, "plan_id": planID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/rebalance-plans/:plan_id/cancel", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExecuteRebalanceTrade records the fill of a planned trade
+// POST /api/v1/portfolios/:id/rebalance-plans/:plan_id/trades/:trade_id/execute
+func (c *Client) ExecuteRebalanceTrade(ctx context.Context, portfolioID, planID, tradeID string, req ExecuteRebalanceTradeRequest) (*RebalancePlanResponse, error) {
+	var result RebalancePlanResponse
+	params := pathParams{"id": portfolioID, "plan_id": planID, "trade_id": tradeID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/rebalance-plans/:plan_id/trades/:trade_id/execute", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SkipRebalanceTrade marks a planned trade as skipped
+// POST /api/v1/portfolios/:id/rebalance-plans/:plan_id/trades/:trade_id/skip
+func (c *Client) SkipRebalanceTrade(ctx context.Context, portfolioID, planID, tradeID string) (*RebalancePlanResponse, error) {
+	var result RebalancePlanResponse
+	params := pathParams{"id": portfolioID, "plan_id": planID, "trade_id": tradeID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/rebalance-plans/:plan_id/trades/:trade_id/skip", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
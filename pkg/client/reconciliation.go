@@ -0,0 +1,17 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Reconcile compares a statement of the positions a portfolio's account holds with its
+// holdings, creating adjustment transactions for the discrepancies if the request asks to
+// POST /api/v1/portfolios/:id/reconcile
+func (c *Client) Reconcile(ctx context.Context, portfolioID string, req ReconcileRequest) (*ReconciliationReport, error) {
+	var result ReconciliationReport
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/reconcile", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
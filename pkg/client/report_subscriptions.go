@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// CreateReportSubscription subscribes to a weekly or monthly performance digest by email
+// POST /api/v1/report-subscriptions
+func (c *Client) CreateReportSubscription(ctx context.Context, req CreateReportSubscriptionRequest) (*ReportSubscriptionResponse, error) {
+	var result ReportSubscriptionResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/report-subscriptions", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListReportSubscriptions lists the user's report subscriptions
+// GET /api/v1/report-subscriptions
+func (c *Client) ListReportSubscriptions(ctx context.Context) (*ReportSubscriptionListResponse, error) {
+	var result ReportSubscriptionListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/report-subscriptions", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetReportSubscription retrieves a report subscription
+// GET /api/v1/report-subscriptions/:id
+func (c *Client) GetReportSubscription(ctx context.Context, subscriptionID string) (*ReportSubscriptionResponse, error) {
+	var result ReportSubscriptionResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/report-subscriptions/:id", pathParams{"id": subscriptionID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateReportSubscription changes a report subscription's frequency, portfolios or enabled state
+// PUT /api/v1/report-subscriptions/:id
+func (c *Client) UpdateReportSubscription(ctx context.Context, subscriptionID string, req UpdateReportSubscriptionRequest) (*ReportSubscriptionResponse, error) {
+	var result ReportSubscriptionResponse
+	if err := c.do(ctx, http.MethodPut, "/api/v1/report-subscriptions/:id", pathParams{"id": subscriptionID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteReportSubscription deletes a report subscription
+// DELETE /api/v1/report-subscriptions/:id
+func (c *Client) DeleteReportSubscription(ctx context.Context, subscriptionID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/report-subscriptions/:id", pathParams{"id": subscriptionID}, nil, nil, nil)
+}
+
+// PreviewReportSubscription builds the digest a subscription would have sent for the last
+// complete period, without emailing it
+// GET /api/v1/report-subscriptions/:id/preview
+func (c *Client) PreviewReportSubscription(ctx context.Context, subscriptionID string) (*PerformanceDigest, error) {
+	var result PerformanceDigest
+	if err := c.do(ctx, http.MethodGet, "/api/v1/report-subscriptions/:id/preview", pathParams{"id": subscriptionID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Unsubscribe disables the report subscription named by the token in a digest's
+// unsubscribe link. It needs no credentials.
+// GET /api/report-subscriptions/unsubscribe
+func (c *Client) Unsubscribe(ctx context.Context, token string) (*ReportSubscriptionResponse, error) {
+	var result ReportSubscriptionResponse
+	query := url.Values{"token": {token}}
+	if err := c.do(ctx, http.MethodGet, "/api/report-subscriptions/unsubscribe", nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
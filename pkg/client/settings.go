@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetSettings returns the user's settings, which are the defaults until first saved
+// GET /api/v1/settings
+func (c *Client) GetSettings(ctx context.Context) (*UserSettingsResponse, error) {
+	var result UserSettingsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/settings", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateSettings changes the settings set in req, leaving the others unchanged
+// PUT /api/v1/settings
+func (c *Client) UpdateSettings(ctx context.Context, req *UpdateUserSettingsRequest) (*UserSettingsResponse, error) {
+	var result UserSettingsResponse
+	if err := c.do(ctx, http.MethodPut, "/api/v1/settings", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
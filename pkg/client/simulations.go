@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// RunSimulation runs a Monte Carlo simulation of withdrawing from a portfolio in retirement.
+// The simulation is queued on the server, and this waits for it to finish. The finished
+// simulation stays stored on the server.
+// POST /api/v1/portfolios/:id/simulations
+func (c *Client) RunSimulation(ctx context.Context, portfolioID string, req SimulationRequest) (*SimulationResponse, error) {
+	var result SimulationResponse
+	if err := c.runJob(ctx, "/api/v1/portfolios/:id/simulations", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListSimulations lists a portfolio's stored simulations, newest first
+// GET /api/v1/portfolios/:id/simulations
+func (c *Client) ListSimulations(ctx context.Context, portfolioID string) (*SimulationListResponse, error) {
+	var result SimulationListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/simulations", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSimulation retrieves a stored simulation with its result
+// GET /api/v1/portfolios/:id/simulations/:simulation_id
+func (c *Client) GetSimulation(ctx context.Context, portfolioID, simulationID string) (*SimulationResponse, error) {
+	var result SimulationResponse
+	params := pathParams{"id": portfolioID, "simulation_id": simulationID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/simulations/:simulation_id", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteSimulation deletes a stored simulation
+// DELETE /api/v1/portfolios/:id/simulations/:simulation_id
+func (c *Client) DeleteSimulation(ctx context.Context, portfolioID, simulationID string) error {
+	params := pathParams{"id": portfolioID, "simulation_id": simulationID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/portfolios/:id/simulations/:simulation_id", params, nil, nil, nil)
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// CreateStockPlanGrant records an employer stock plan grant
+// POST /api/v1/portfolios/:id/stock-plans/grants
+func (c *Client) CreateStockPlanGrant(ctx context.Context, portfolioID string, req CreateStockPlanGrantRequest) (*StockPlanGrantResponse, error) {
+	var result StockPlanGrantResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/stock-plans/grants", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListStockPlanGrants lists a portfolio's stock plan grants
+// GET /api/v1/portfolios/:id/stock-plans/grants
+func (c *Client) ListStockPlanGrants(ctx context.Context, portfolioID string) ([]*StockPlanGrantResponse, error) {
+	var result []*StockPlanGrantResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/stock-plans/grants", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetStockPlanGrant retrieves a grant with its vesting schedule
+// GET /api/v1/portfolios/:id/stock-plans/grants/:grant_id
+func (c *Client) GetStockPlanGrant(ctx context.Context, portfolioID, grantID string) (*StockPlanGrantResponse, error) {
+	var result StockPlanGrantResponse
+	params := pathParams{"id": portfolioID, "grant_id": grantID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/stock-plans/grants/:grant_id", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteStockPlanGrant deletes a grant
+// DELETE /api/v1/portfolios/:id/stock-plans/grants/:grant_id
+func (c *Client) DeleteStockPlanGrant(ctx context.Context, portfolioID, grantID string) error {
+	params := pathParams{"id": portfolioID, "grant_id": grantID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/portfolios/:id/stock-plans/grants/:grant_id", params, nil, nil, nil)
+}
+
+// ListStockPlanEvents lists the vests, purchases, and exercises recorded against a grant
+// GET /api/v1/portfolios/:id/stock-plans/grants/:grant_id/events
+func (c *Client) ListStockPlanEvents(ctx context.Context, portfolioID, grantID string) ([]*StockPlanEventResponse, error) {
+	var result []*StockPlanEventResponse
+	params := pathParams{"id": portfolioID, "grant_id": grantID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/stock-plans/grants/:grant_id/events", params, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RecordVest records an RSU vest
+// POST /api/v1/portfolios/:id/stock-plans/grants/:grant_id/vest
+func (c *Client) RecordVest(ctx context.Context, portfolioID, grantID string, req RecordRSUVestRequest) (*StockPlanEventResponse, error) {
+	var result StockPlanEventResponse
+	params := pathParams{"id": portfolioID, "grant_id": grantID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/stock-plans/grants/:grant_id/vest", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RecordESPPPurchase records an ESPP purchase
+// POST /api/v1/portfolios/:id/stock-plans/grants/:grant_id/espp-purchase
+func (c *Client) RecordESPPPurchase(ctx context.Context, portfolioID, grantID string, req RecordESPPPurchaseRequest) (*StockPlanEventResponse, error) {
+	var result StockPlanEventResponse
+	params := pathParams{"id": portfolioID, "grant_id": grantID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/stock-plans/grants/:grant_id/espp-purchase", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// RecordExercise records a stock option exercise
+// POST /api/v1/portfolios/:id/stock-plans/grants/:grant_id/exercise
+func (c *Client) RecordExercise(ctx context.Context, portfolioID, grantID string, req RecordOptionExerciseRequest) (*StockPlanEventResponse, error) {
+	var result StockPlanEventResponse
+	params := pathParams{"id": portfolioID, "grant_id": grantID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/stock-plans/grants/:grant_id/exercise", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetVestingCalendar lists the vests scheduled across a portfolio's grants within a period
+// GET /api/v1/portfolios/:id/stock-plans/vesting-calendar
+func (c *Client) GetVestingCalendar(ctx context.Context, portfolioID string, period DateRange) (*VestingCalendarResponse, error) {
+	var result VestingCalendarResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/stock-plans/vesting-calendar", pathParams{"id": portfolioID}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetEmployerStockPolicy retrieves a portfolio's employer stock designation
+// GET /api/v1/portfolios/:id/employer-stock
+func (c *Client) GetEmployerStockPolicy(ctx context.Context, portfolioID string) (*EmployerStockPolicyResponse, error) {
+	var result EmployerStockPolicyResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/employer-stock", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetEmployerStockPolicy designates a portfolio's employer stock and how blackouts are enforced
+// PUT /api/v1/portfolios/:id/employer-stock
+func (c *Client) SetEmployerStockPolicy(ctx context.Context, portfolioID string, req SetEmployerStockPolicyRequest) (*EmployerStockPolicyResponse, error) {
+	var result EmployerStockPolicyResponse
+	if err := c.do(ctx, http.MethodPut, "/api/v1/portfolios/:id/employer-stock", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteEmployerStockPolicy removes a portfolio's employer stock designation
+// DELETE /api/v1/portfolios/:id/employer-stock
+func (c *Client) DeleteEmployerStockPolicy(ctx context.Context, portfolioID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/portfolios/:id/employer-stock", pathParams{"id": portfolioID}, nil, nil, nil)
+}
+
+// CreateBlackoutWindow adds a trading blackout window for the employer stock
+// POST /api/v1/portfolios/:id/blackout-windows
+func (c *Client) CreateBlackoutWindow(ctx context.Context, portfolioID string, req CreateBlackoutWindowRequest) (*BlackoutWindowResponse, error) {
+	var result BlackoutWindowResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/blackout-windows", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListBlackoutWindows lists a portfolio's blackout windows
+// GET /api/v1/portfolios/:id/blackout-windows
+func (c *Client) ListBlackoutWindows(ctx context.Context, portfolioID string) ([]*BlackoutWindowResponse, error) {
+	var result []*BlackoutWindowResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/blackout-windows", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteBlackoutWindow deletes a blackout window
+// DELETE /api/v1/portfolios/:id/blackout-windows/:window_id
+func (c *Client) DeleteBlackoutWindow(ctx context.Context, portfolioID, windowID string) error {
+	params := pathParams{"id": portfolioID, "window_id": windowID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/portfolios/:id/blackout-windows/:window_id", params, nil, nil, nil)
+}
+
+// ListBlackoutOverrides lists the audit trail of trades made during blackout windows
+// GET /api/v1/portfolios/:id/blackout-overrides
+func (c *Client) ListBlackoutOverrides(ctx context.Context, portfolioID string) ([]*BlackoutOverrideResponse, error) {
+	var result []*BlackoutOverrideResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/blackout-overrides", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
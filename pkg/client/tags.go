@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListTags lists the tags on the signed-in user's portfolios and transactions
+// GET /api/v1/tags
+func (c *Client) ListTags(ctx context.Context) (*TagListResponse, error) {
+	var result TagListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tags", nil, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTagPerformance retrieves the performance of every portfolio carrying a tag over a period,
+// with totals for each base currency
+// GET /api/v1/tags/:tag/performance
+func (c *Client) GetTagPerformance(ctx context.Context, tag string, period DateRange) (*TagPerformance, error) {
+	var result TagPerformance
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tags/:tag/performance", pathParams{"tag": tag}, period.query(), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
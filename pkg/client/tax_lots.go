@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetTaxLot retrieves a tax lot
+// GET /api/v1/tax-lots/:id
+func (c *Client) GetTaxLot(ctx context.Context, taxLotID string) (*TaxLotResponse, error) {
+	var result TaxLotResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tax-lots/:id", pathParams{"id": taxLotID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTaxLots lists a portfolio's tax lots, optionally only those for symbol
+// GET /api/v1/portfolios/:id/tax-lots
+func (c *Client) ListTaxLots(ctx context.Context, portfolioID, symbol string) ([]*TaxLotResponse, error) {
+	var query url.Values
+	if symbol != "" {
+		query = url.Values{"symbol": {symbol}}
+	}
+	var result []*TaxLotResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/tax-lots", params, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AllocateSale previews which tax lots a sale would close under a cost basis method
+// POST /api/v1/portfolios/:id/tax-lots/allocate
+func (c *Client) AllocateSale(ctx context.Context, portfolioID string, req TaxLotAllocationRequest) ([]*LotAllocationResponse, error) {
+	var result []*LotAllocationResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/tax-lots/allocate", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListTaxLossOpportunities lists lots whose unrealized loss at current prices is at least
+// threshold percent of their cost basis, with wash sale risk and replacement suggestions. An
+// empty threshold uses the server's default of -3.
+// GET /api/v1/portfolios/:id/tax-lots/harvest
+func (c *Client) ListTaxLossOpportunities(ctx context.Context, portfolioID, threshold string) ([]*TaxLossOpportunityResponse, error) {
+	var query url.Values
+	if threshold != "" {
+		query = url.Values{"threshold": {threshold}}
+	}
+	var result []*TaxLossOpportunityResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/tax-lots/harvest", params, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GenerateTaxReport summarizes a portfolio's realized gains for a tax year. The report is
+// queued on the server, and this waits for it to finish.
+// POST /api/v1/portfolios/:id/tax-lots/report
+func (c *Client) GenerateTaxReport(ctx context.Context, portfolioID string, req TaxReportRequest) (*TaxReportResponse, error) {
+	var result TaxReportResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.runJob(ctx, "/api/v1/portfolios/:id/tax-lots/report", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetTaxRegime changes the tax regime a portfolio's sales are allocated and reported under
+// PUT /api/v1/portfolios/:id/tax-regime
+func (c *Client) SetTaxRegime(ctx context.Context, portfolioID string, regime TaxRegime) (*PortfolioResponse, error) {
+	var result PortfolioResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodPut, "/api/v1/portfolios/:id/tax-regime", params, nil, TaxRegimeRequest{TaxRegime: regime}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,194 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CreateTransaction records a transaction in a portfolio
+// POST /api/v1/portfolios/:id/transactions
+func (c *Client) CreateTransaction(ctx context.Context, portfolioID string, req CreateTransactionRequest) (*TransactionResponse, error) {
+	var result TransactionResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/transactions", params, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTransactions lists a portfolio's transactions, optionally only those for symbol and
+// those carrying every one of tags
+// GET /api/v1/portfolios/:id/transactions
+func (c *Client) ListTransactions(ctx context.Context, portfolioID, symbol string, tags ...string) (*TransactionListResponse, error) {
+	query := url.Values{}
+	if symbol != "" {
+		query.Set("symbol", symbol)
+	}
+	if len(tags) > 0 {
+		query["tag"] = tags
+	}
+	var result TransactionListResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/transactions", params, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetSymbolLedger lists a portfolio's transactions in symbol oldest first, each with the
+// symbol's running quantity and cost basis right after it
+// GET /api/v1/portfolios/:id/transactions?symbol=:symbol&running_position=true
+func (c *Client) GetSymbolLedger(ctx context.Context, portfolioID, symbol string) (*TransactionListResponse, error) {
+	query := url.Values{"symbol": {symbol}, "running_position": {"true"}}
+	var result TransactionListResponse
+	params := pathParams{"id": portfolioID}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/transactions", params, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetTransaction retrieves a transaction
+// GET /api/v1/transactions/:id
+func (c *Client) GetTransaction(ctx context.Context, transactionID string) (*TransactionResponse, error) {
+	var result TransactionResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/transactions/:id", pathParams{"id": transactionID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateTransaction replaces a transaction. The request must carry the Version of the
+// transaction it was made against; a stale version fails with VERSION_CONFLICT.
+// PUT /api/v1/transactions/:id
+func (c *Client) UpdateTransaction(ctx context.Context, transactionID string, req UpdateTransactionRequest) (*TransactionResponse, error) {
+	var result TransactionResponse
+	if err := c.do(ctx, http.MethodPut, "/api/v1/transactions/:id", pathParams{"id": transactionID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteTransaction deletes a transaction
+// DELETE /api/v1/transactions/:id
+func (c *Client) DeleteTransaction(ctx context.Context, transactionID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/transactions/:id", pathParams{"id": transactionID}, nil, nil, nil)
+}
+
+// ImportCSV starts importing transactions from broker CSV data. The import runs in the
+// background on the server; pass the returned batch ID to WatchImport for its progress
+// and result.
+// POST /api/v1/portfolios/:id/transactions/import/csv
+func (c *Client) ImportCSV(ctx context.Context, portfolioID string, req CSVImportRequest) (*ImportJobResponse, error) {
+	var result ImportJobResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/transactions/import/csv", pathParams{"id": portfolioID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WatchImport follows the progress of a background CSV import until it finishes, calling
+// onProgress, if set, with every event. It returns the last event, whose Result holds the
+// outcome of a completed import and whose Error says why a failed one stopped.
+// GET /api/v1/portfolios/:id/imports/batches/:batch_id/events
+func (c *Client) WatchImport(ctx context.Context, portfolioID, batchID string, onProgress func(*ImportProgress)) (*ImportProgress, error) {
+	var last *ImportProgress
+	params := pathParams{"id": portfolioID, "batch_id": batchID}
+	err := c.streamEvents(ctx, "/api/v1/portfolios/:id/imports/batches/:batch_id/events", params, func(_ string, data []byte) (bool, error) {
+		var progress ImportProgress
+		if err := json.Unmarshal(data, &progress); err != nil {
+			return false, fmt.Errorf("failed to decode import progress: %w", err)
+		}
+		last = &progress
+		if onProgress != nil {
+			onProgress(last)
+		}
+		return !progress.Status.IsFinished(), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if last == nil || !last.Status.IsFinished() {
+		return last, fmt.Errorf("import event stream ended before the import finished")
+	}
+	return last, nil
+}
+
+// ImportBulk imports pre-parsed transactions. When the import is rejected the server still
+// describes what failed, so the result is returned alongside the *APIError.
+// POST /api/v1/portfolios/:id/transactions/import/bulk
+func (c *Client) ImportBulk(ctx context.Context, portfolioID string, req BulkImportRequest) (*ImportResult, error) {
+	return c.doImport(ctx, "/api/v1/portfolios/:id/transactions/import/bulk", portfolioID, req)
+}
+
+// doImport posts an import and decodes the import result from rejected imports as well
+func (c *Client) doImport(ctx context.Context, pattern, portfolioID string, req interface{}) (*ImportResult, error) {
+	var result ImportResult
+	err := c.do(ctx, http.MethodPost, pattern, pathParams{"id": portfolioID}, nil, req, &result)
+	if err == nil {
+		return &result, nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		var rejected ImportResult
+		if json.Unmarshal(apiErr.Body, &rejected) == nil && rejected.TotalRows > 0 {
+			return &rejected, err
+		}
+	}
+	return nil, err
+}
+
+// ImportOpeningLots imports the lots a portfolio held before its recorded history from a CSV
+// file, recording each as an opening balance transaction. As with ImportBulk, a rejected
+// import's result is returned alongside the *APIError.
+// POST /api/v1/portfolios/:id/transactions/import/lots
+func (c *Client) ImportOpeningLots(ctx context.Context, portfolioID string, req OpeningLotImportRequest) (*OpeningLotImportResult, error) {
+	var result OpeningLotImportResult
+	err := c.do(ctx, http.MethodPost, "/api/v1/portfolios/:id/transactions/import/lots", pathParams{"id": portfolioID}, nil, req, &result)
+	if err == nil {
+		return &result, nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+		var rejected OpeningLotImportResult
+		if json.Unmarshal(apiErr.Body, &rejected) == nil && rejected.Result != nil {
+			return &rejected, err
+		}
+	}
+	return nil, err
+}
+
+// ListImportBatches lists a portfolio's import batches
+// GET /api/v1/portfolios/:id/imports/batches
+func (c *Client) ListImportBatches(ctx context.Context, portfolioID string) (*ImportBatchListResponse, error) {
+	var result ImportBatchListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/portfolios/:id/imports/batches", pathParams{"id": portfolioID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteImportBatch deletes every transaction created by an import batch
+// DELETE /api/v1/portfolios/:id/imports/batches/:batch_id
+func (c *Client) DeleteImportBatch(ctx context.Context, portfolioID, batchID string) error {
+	params := pathParams{"id": portfolioID, "batch_id": batchID}
+	return c.do(ctx, http.MethodDelete, "/api/v1/portfolios/:id/imports/batches/:batch_id", params, nil, nil, nil)
+}
+
+// ImportTracker imports a Ghostfolio or Portfolio Performance export, creating a portfolio
+// for each of its accounts. The import is queued on the server, and this waits for it to
+// finish; a rejected import has Success unset and its errors in the result.
+// POST /api/v1/imports/tracker
+func (c *Client) ImportTracker(ctx context.Context, req TrackerImportRequest) (*TrackerImportResult, error) {
+	var result TrackerImportResult
+	if err := c.runJob(ctx, "/api/v1/imports/tracker", nil, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,550 @@
+package client
+
+import (
+	"github.com/lenon/portfolios/internal/dto"
+	"github.com/lenon/portfolios/internal/models"
+	"github.com/lenon/portfolios/internal/services"
+)
+
+// Enumerations used in requests
+type (
+	TransactionType     = models.TransactionType
+	AssetType           = models.AssetType
+	CostBasisMethod     = models.CostBasisMethod
+	TaxRegime           = models.TaxRegime
+	StockPlanType       = models.StockPlanType
+	OptionType          = models.OptionType
+	OptionSettlement    = dto.OptionSettlementAction
+	SpinoffAllocation   = models.SpinoffAllocationMethod
+	BlackoutEnforcement = models.BlackoutEnforcement
+	ImportFormat        = dto.ImportFormat
+	ImportStatus        = dto.ImportStatus
+	QueuedJobType       = models.QueuedJobType
+	QueuedJobStatus     = models.QueuedJobStatus
+	StatementFormat     = dto.StatementFormat
+	ExportFormat        = dto.ExportFormat
+	TaxExportFormat     = dto.TaxExportFormat
+	ReportFrequency     = models.ReportFrequency
+	UserRole            = models.UserRole
+	OrganizationRole    = models.OrganizationRole
+	DelegationAccess    = models.DelegationAccess
+	DelegationStatus    = models.DelegationStatus
+	PriceResolution     = models.PriceResolution
+	NotificationType    = models.NotificationType
+	PushPlatform        = models.PushPlatform
+	PerformancePeriod   = models.PerformancePeriod
+)
+
+// Transaction types
+const (
+	TransactionTypeBuy              = models.TransactionTypeBuy
+	TransactionTypeSell             = models.TransactionTypeSell
+	TransactionTypeDividend         = models.TransactionTypeDividend
+	TransactionTypeSplit            = models.TransactionTypeSplit
+	TransactionTypeMerger           = models.TransactionTypeMerger
+	TransactionTypeSpinoff          = models.TransactionTypeSpinoff
+	TransactionTypeDividendReinvest = models.TransactionTypeDividendReinvest
+	TransactionTypeBuyToOpen        = models.TransactionTypeBuyToOpen
+	TransactionTypeSellToClose      = models.TransactionTypeSellToClose
+	TransactionTypeOptionExpiration = models.TransactionTypeOptionExpiration
+	TransactionTypeOptionAssignment = models.TransactionTypeOptionAssignment
+	TransactionTypeReturnOfCapital  = models.TransactionTypeReturnOfCapital
+	TransactionTypeStockDividend    = models.TransactionTypeStockDividend
+	TransactionTypeManagementFee    = models.TransactionTypeManagementFee
+	TransactionTypeADRFee           = models.TransactionTypeADRFee
+	TransactionTypeFXFee            = models.TransactionTypeFXFee
+	TransactionTypeLendingIncome    = models.TransactionTypeLendingIncome
+	TransactionTypeInterest         = models.TransactionTypeInterest
+	TransactionTypeRebate           = models.TransactionTypeRebate
+	TransactionTypeDeposit          = models.TransactionTypeDeposit
+	TransactionTypeWithdrawal       = models.TransactionTypeWithdrawal
+	TransactionTypeOpeningBalance   = models.TransactionTypeOpeningBalance
+
+	TransactionTypeCapitalGainDistribution = models.TransactionTypeCapitalGainDistribution
+)
+
+// Capital gain distribution terms, set as a distribution's category
+const (
+	CapitalGainTermShort = models.CapitalGainTermShort
+	CapitalGainTermLong  = models.CapitalGainTermLong
+)
+
+// Asset types, reported on transactions and holdings. Coin pair symbols such as BTC-USD
+// are crypto, OCC symbols such as AAPL240621C00190000 are options, and everything else is
+// an equity.
+const (
+	AssetTypeEquity = models.AssetTypeEquity
+	AssetTypeCrypto = models.AssetTypeCrypto
+	AssetTypeOption = models.AssetTypeOption
+)
+
+// Option contract types and settlements
+const (
+	OptionTypeCall = models.OptionTypeCall
+	OptionTypePut  = models.OptionTypePut
+
+	OptionSettlementExpire = dto.OptionSettlementExpire
+	OptionSettlementAssign = dto.OptionSettlementAssign
+)
+
+// Spinoff cost basis allocation methods, used when approving a spinoff
+const (
+	SpinoffAllocationDefault         = models.SpinoffAllocationDefault
+	SpinoffAllocationExplicit        = models.SpinoffAllocationExplicit
+	SpinoffAllocationFairMarketValue = models.SpinoffAllocationFairMarketValue
+)
+
+// Price history resolutions
+const (
+	PriceResolution1Min  = models.PriceResolution1Min
+	PriceResolution5Min  = models.PriceResolution5Min
+	PriceResolution1Hour = models.PriceResolution1Hour
+	PriceResolution1Day  = models.PriceResolution1Day
+	PriceResolution1Week = models.PriceResolution1Week
+)
+
+// Cost basis methods
+const (
+	CostBasisFIFO        = models.CostBasisFIFO
+	CostBasisLIFO        = models.CostBasisLIFO
+	CostBasisSpecificLot = models.CostBasisSpecificLot
+)
+
+// Tax regimes
+const (
+	TaxRegimeUS = models.TaxRegimeUS
+	TaxRegimeUK = models.TaxRegimeUK
+	TaxRegimeCA = models.TaxRegimeCA
+)
+
+// User roles
+const (
+	RoleUser  = models.RoleUser
+	RoleAdmin = models.RoleAdmin
+)
+
+// Organization roles
+const (
+	OrgRoleOwner  = models.OrgRoleOwner
+	OrgRoleAdmin  = models.OrgRoleAdmin
+	OrgRoleMember = models.OrgRoleMember
+	OrgRoleViewer = models.OrgRoleViewer
+)
+
+// Advisor delegation access levels and statuses
+const (
+	DelegationAccessRead    = models.DelegationAccessRead
+	DelegationAccessManage  = models.DelegationAccessManage
+	DelegationStatusPending = models.DelegationStatusPending
+	DelegationStatusActive  = models.DelegationStatusActive
+	DelegationStatusRevoked = models.DelegationStatusRevoked
+)
+
+// Employer stock plan types
+const (
+	StockPlanTypeRSU  = models.StockPlanTypeRSU
+	StockPlanTypeESPP = models.StockPlanTypeESPP
+	StockPlanTypeISO  = models.StockPlanTypeISO
+	StockPlanTypeNSO  = models.StockPlanTypeNSO
+)
+
+// Blackout enforcement levels
+const (
+	BlackoutEnforcementWarn  = models.BlackoutEnforcementWarn
+	BlackoutEnforcementBlock = models.BlackoutEnforcementBlock
+)
+
+// Projection methods
+const (
+	ProjectionMethodExpectedReturn = models.ProjectionMethodExpectedReturn
+	ProjectionMethodHistorical     = models.ProjectionMethodHistorical
+)
+
+// Import formats
+const (
+	ImportFormatGeneric            = dto.ImportFormatGeneric
+	ImportFormatFidelity           = dto.ImportFormatFidelity
+	ImportFormatSchwab             = dto.ImportFormatSchwab
+	ImportFormatTDAmeritrade       = dto.ImportFormatTDAmeritrade
+	ImportFormatETrade             = dto.ImportFormatETrade
+	ImportFormatInteractiveBrokers = dto.ImportFormatInteractiveBrokers
+	ImportFormatRobinhood          = dto.ImportFormatRobinhood
+
+	ImportFormatGhostfolio           = dto.ImportFormatGhostfolio
+	ImportFormatPortfolioPerformance = dto.ImportFormatPortfolioPerformance
+)
+
+// Background import statuses
+const (
+	ImportStatusQueued    = dto.ImportStatusQueued
+	ImportStatusRunning   = dto.ImportStatusRunning
+	ImportStatusCompleted = dto.ImportStatusCompleted
+	ImportStatusFailed    = dto.ImportStatusFailed
+)
+
+// Queued job types and statuses
+const (
+	QueuedJobTypeCSVImport        = models.QueuedJobTypeCSVImport
+	QueuedJobTypeTrackerImport    = models.QueuedJobTypeTrackerImport
+	QueuedJobTypeRecalculation    = models.QueuedJobTypeRecalculation
+	QueuedJobTypeTaxReport        = models.QueuedJobTypeTaxReport
+	QueuedJobTypeSimulation       = models.QueuedJobTypeSimulation
+	QueuedJobTypeSnapshotBackfill = models.QueuedJobTypeSnapshotBackfill
+
+	QueuedJobStatusQueued    = models.QueuedJobStatusQueued
+	QueuedJobStatusRunning   = models.QueuedJobStatusRunning
+	QueuedJobStatusSucceeded = models.QueuedJobStatusSucceeded
+	QueuedJobStatusFailed    = models.QueuedJobStatusFailed
+)
+
+// Notification types
+const (
+	NotificationTypeCorporateAction = models.NotificationTypeCorporateAction
+	NotificationTypeImportCompleted = models.NotificationTypeImportCompleted
+	NotificationTypeImportFailed    = models.NotificationTypeImportFailed
+	NotificationTypeReportGenerated = models.NotificationTypeReportGenerated
+)
+
+// Push platforms
+const (
+	PushPlatformWeb  = models.PushPlatformWeb
+	PushPlatformAPNs = models.PushPlatformAPNs
+)
+
+// Standard performance periods
+const (
+	PerformancePeriod1M  = models.PerformancePeriod1M
+	PerformancePeriod3M  = models.PerformancePeriod3M
+	PerformancePeriodYTD = models.PerformancePeriodYTD
+	PerformancePeriod1Y  = models.PerformancePeriod1Y
+	PerformancePeriod3Y  = models.PerformancePeriod3Y
+	PerformancePeriodAll = models.PerformancePeriodAll
+)
+
+// Statement formats
+const (
+	StatementFormatPDF  = dto.StatementFormatPDF
+	StatementFormatHTML = dto.StatementFormatHTML
+)
+
+// Export formats
+const (
+	ExportFormatCSV       = dto.ExportFormatCSV
+	ExportFormatJSONLines = dto.ExportFormatJSONLines
+)
+
+// Tax export formats
+const (
+	TaxExportForm8949CSV = dto.TaxExportForm8949CSV
+	TaxExportTXF         = dto.TaxExportTXF
+)
+
+// Report subscription frequencies
+const (
+	ReportFrequencyWeekly  = models.ReportFrequencyWeekly
+	ReportFrequencyMonthly = models.ReportFrequencyMonthly
+)
+
+// Common
+type (
+	ErrorResponse        = dto.ErrorResponse
+	FieldError           = dto.FieldError
+	ErrorCodeResponse    = dto.ErrorCodeResponse
+	ErrorCatalogResponse = dto.ErrorCatalogResponse
+	MessageResponse      = dto.MessageResponse
+)
+
+// Authentication
+type (
+	RegisterRequest       = dto.RegisterRequest
+	LoginRequest          = dto.LoginRequest
+	RefreshRequest        = dto.RefreshRequest
+	LogoutRequest         = dto.LogoutRequest
+	ForgotPasswordRequest = dto.ForgotPasswordRequest
+	ResetPasswordRequest  = dto.ResetPasswordRequest
+	ChangePasswordRequest = dto.ChangePasswordRequest
+	AuthResponse          = dto.AuthResponse
+	RefreshResponse       = dto.RefreshResponse
+	UserResponse          = dto.UserResponse
+)
+
+// Portfolios
+type (
+	CreatePortfolioRequest = dto.CreatePortfolioRequest
+	UpdatePortfolioRequest = dto.UpdatePortfolioRequest
+	PortfolioResponse      = dto.PortfolioResponse
+	PortfolioListResponse  = dto.PortfolioListResponse
+)
+
+// Tags
+type (
+	TagResponse             = dto.TagResponse
+	TagListResponse         = dto.TagListResponse
+	TagPerformance          = dto.TagPerformance
+	TagPortfolioPerformance = dto.TagPortfolioPerformance
+	TagPerformanceTotal     = dto.TagPerformanceTotal
+	PerformanceMetrics      = dto.PerformanceMetrics
+)
+
+// Household overview
+type (
+	Overview            = dto.Overview
+	OverviewPortfolio   = dto.OverviewPortfolio
+	OverviewAllocation  = dto.OverviewAllocation
+	OverviewHolding     = dto.OverviewHolding
+	OverviewPerformance = dto.OverviewPerformance
+)
+
+// Dashboard
+type (
+	Dashboard                = dto.Dashboard
+	DashboardPortfolio       = dto.DashboardPortfolio
+	DashboardMover           = dto.DashboardMover
+	DashboardCorporateAction = dto.DashboardCorporateAction
+)
+
+// Portfolio groups
+type (
+	CreatePortfolioGroupRequest = dto.CreatePortfolioGroupRequest
+	UpdatePortfolioGroupRequest = dto.UpdatePortfolioGroupRequest
+	PortfolioGroupResponse      = dto.PortfolioGroupResponse
+	PortfolioGroupListResponse  = dto.PortfolioGroupListResponse
+	PortfolioGroupOverview      = dto.PortfolioGroupOverview
+)
+
+// Transactions and imports
+type (
+	CreateTransactionRequest = dto.CreateTransactionRequest
+	UpdateTransactionRequest = dto.UpdateTransactionRequest
+	TransactionResponse      = dto.TransactionResponse
+	TransactionListResponse  = dto.TransactionListResponse
+	CSVImportRequest         = dto.CSVImportRequest
+	BulkImportRequest        = dto.BulkImportRequest
+	ImportTransactionRequest = dto.ImportTransactionRequest
+	ImportResult             = dto.ImportResult
+	ImportJobResponse        = dto.ImportJobResponse
+	ImportProgress           = dto.ImportProgress
+	ImportBatchListResponse  = dto.ImportBatchListResponse
+	TrackerImportRequest     = dto.TrackerImportRequest
+	TrackerImportResult      = dto.TrackerImportResult
+	OpeningLotImportRequest  = dto.OpeningLotImportRequest
+	OpeningLotImportResult   = dto.OpeningLotImportResult
+	TrackerPortfolioImport   = dto.TrackerPortfolioImport
+	AccountExportRecord      = dto.AccountExportRecord
+)
+
+// Broker connections
+type (
+	CreateBrokerConnectionRequest = dto.CreateBrokerConnectionRequest
+	SyncBrokerConnectionRequest   = dto.SyncBrokerConnectionRequest
+	BrokerConnectionResponse      = dto.BrokerConnectionResponse
+	BrokerSyncReport              = dto.BrokerSyncReport
+	BrokerTransactionDiff         = dto.BrokerTransactionDiff
+	BrokerPositionDiff            = dto.BrokerPositionDiff
+)
+
+// Reconciliation
+type (
+	ReconcileRequest         = dto.ReconcileRequest
+	StatementPosition        = dto.StatementPosition
+	ReconciliationReport     = dto.ReconciliationReport
+	PositionDiscrepancy      = dto.PositionDiscrepancy
+	ReconciliationAdjustment = dto.ReconciliationAdjustment
+)
+
+// Holdings, tax lots, and recalculation
+type (
+	HoldingResponse            = dto.HoldingResponse
+	HoldingListResponse        = dto.HoldingListResponse
+	TaxLotResponse             = dto.TaxLotResponse
+	TaxLotAllocationRequest    = dto.TaxLotAllocationRequest
+	LotAllocationResponse      = dto.LotAllocationResponse
+	TaxLossOpportunityResponse = dto.TaxLossOpportunityResponse
+	TaxReportRequest           = dto.TaxReportRequest
+	TaxReportResponse          = dto.TaxReportResponse
+	TaxRegimeRequest           = dto.TaxRegimeRequest
+	RecalculationReport        = dto.RecalculationReport
+	RecalculationDiscrepancy   = dto.RecalculationDiscrepancy
+)
+
+// Performance
+type (
+	PerformanceMetricsResponse        = dto.PerformanceMetricsResponse
+	PeriodMetricsResponse             = dto.PeriodMetricsResponse
+	TWRResponse                       = dto.TWRResponse
+	MWRResponse                       = dto.MWRResponse
+	AnnualizedReturnResponse          = dto.AnnualizedReturnResponse
+	BenchmarkComparisonResponse       = dto.BenchmarkComparisonResponse
+	ContributionAnalysis              = dto.ContributionAnalysis
+	PerformanceSnapshotResponse       = dto.PerformanceSnapshotResponse
+	PerformanceSnapshotListResponse   = dto.PerformanceSnapshotListResponse
+	SnapshotBackfillProgress          = dto.SnapshotBackfillProgress
+	SnapshotBackfillResult            = dto.SnapshotBackfillResult
+	PerformanceCertification          = dto.PerformanceCertification
+	CertificationVerificationResponse = dto.CertificationVerificationResponse
+	PeerComparisonOptInRequest        = dto.PeerComparisonOptInRequest
+	PeerComparisonOptInResponse       = dto.PeerComparisonOptInResponse
+	PeerComparisonResult              = dto.PeerComparisonResult
+	FeeSchedulePresetsResponse        = dto.FeeSchedulePresetsResponse
+	FeeComparisonRequest              = dto.FeeComparisonRequest
+	FeeComparisonResult               = dto.FeeComparisonResult
+	FeeSummary                        = dto.FeeSummary
+	FeeCategorySummary                = dto.FeeCategorySummary
+	ProjectionRequest                 = dto.ProjectionRequest
+	ProjectionResult                  = dto.ProjectionResult
+	SimulationRequest                 = dto.SimulationRequest
+	SimulationParameters              = dto.SimulationParameters
+	SimulationResult                  = dto.SimulationResult
+	SimulationResponse                = dto.SimulationResponse
+	SimulationListResponse            = dto.SimulationListResponse
+	SimulationYear                    = models.SimulationYear
+	WhatIfTrade                       = dto.WhatIfTrade
+	WhatIfRequest                     = dto.WhatIfRequest
+	WhatIfPosition                    = dto.WhatIfPosition
+	WhatIfResult                      = dto.WhatIfResult
+)
+
+// Employer stock plans and blackout windows
+type (
+	CreateStockPlanGrantRequest   = dto.CreateStockPlanGrantRequest
+	StockPlanGrantResponse        = dto.StockPlanGrantResponse
+	StockPlanEventResponse        = dto.StockPlanEventResponse
+	RecordRSUVestRequest          = dto.RecordRSUVestRequest
+	RecordESPPPurchaseRequest     = dto.RecordESPPPurchaseRequest
+	RecordOptionExerciseRequest   = dto.RecordOptionExerciseRequest
+	VestingCalendarResponse       = dto.VestingCalendarResponse
+	SetEmployerStockPolicyRequest = dto.SetEmployerStockPolicyRequest
+	EmployerStockPolicyResponse   = dto.EmployerStockPolicyResponse
+	CreateBlackoutWindowRequest   = dto.CreateBlackoutWindowRequest
+	BlackoutWindowResponse        = dto.BlackoutWindowResponse
+	BlackoutOverrideResponse      = dto.BlackoutOverrideResponse
+)
+
+// Options
+type (
+	OptionTradeRequest         = dto.OptionTradeRequest
+	SettleOptionRequest        = dto.SettleOptionRequest
+	OptionContractResponse     = dto.OptionContractResponse
+	OptionPositionResponse     = dto.OptionPositionResponse
+	OptionPositionListResponse = dto.OptionPositionListResponse
+	OptionSettlementResponse   = dto.OptionSettlementResponse
+)
+
+// Rebalancing and corporate actions
+type (
+	CreateRebalancePlanRequest   = dto.CreateRebalancePlanRequest
+	RebalanceTradeRequest        = dto.RebalanceTradeRequest
+	ExecuteRebalanceTradeRequest = dto.ExecuteRebalanceTradeRequest
+	RebalancePlanResponse        = dto.RebalancePlanResponse
+	RebalanceTradeResponse       = dto.RebalanceTradeResponse
+	PortfolioActionResponse      = dto.PortfolioActionResponse
+	ApproveActionRequest         = dto.ApproveActionRequest
+	RejectActionRequest          = dto.RejectActionRequest
+
+	CorporateActionCalendarEvent    = dto.CorporateActionCalendarEvent
+	CorporateActionCalendarResponse = dto.CorporateActionCalendarResponse
+)
+
+// Report subscriptions
+type (
+	CreateReportSubscriptionRequest = dto.CreateReportSubscriptionRequest
+	UpdateReportSubscriptionRequest = dto.UpdateReportSubscriptionRequest
+	ReportSubscriptionResponse      = dto.ReportSubscriptionResponse
+	ReportSubscriptionListResponse  = dto.ReportSubscriptionListResponse
+	PerformanceDigest               = dto.PerformanceDigest
+	DigestPortfolio                 = dto.DigestPortfolio
+	DigestMover                     = dto.DigestMover
+)
+
+// Queued jobs
+type (
+	QueuedJobResponse     = dto.QueuedJobResponse
+	QueuedJobListResponse = dto.QueuedJobListResponse
+)
+
+// Notifications
+type (
+	NotificationResponse     = dto.NotificationResponse
+	NotificationListResponse = dto.NotificationListResponse
+)
+
+// User settings
+type (
+	UpdateUserSettingsRequest = dto.UpdateUserSettingsRequest
+	UserSettingsResponse      = dto.UserSettingsResponse
+)
+
+// Push notifications
+type (
+	RegisterPushDeviceRequest = dto.RegisterPushDeviceRequest
+	PushSubscriptionKeys      = dto.PushSubscriptionKeys
+	PushDeviceResponse        = dto.PushDeviceResponse
+	VAPIDPublicKeyResponse    = dto.VAPIDPublicKeyResponse
+)
+
+// Market data
+type (
+	Quote                    = dto.Quote
+	QuoteResponse            = dto.QuoteResponse
+	GetQuotesRequest         = dto.GetQuotesRequest
+	QuotesResponse           = dto.QuotesResponse
+	HistoricalPricesResponse = dto.HistoricalPricesResponse
+	ExchangeRateResponse     = dto.ExchangeRateResponse
+	QuotaStatus              = services.QuotaStatus
+	SecurityResponse         = dto.SecurityResponse
+	SecuritySearchResponse   = dto.SecuritySearchResponse
+)
+
+// Admin provisioning
+type (
+	UpsertOrganizationRequest     = dto.UpsertOrganizationRequest
+	SetOrganizationQuotasRequest  = dto.SetOrganizationQuotasRequest
+	OrganizationResponse          = dto.OrganizationResponse
+	UpsertOrganizationUserRequest = dto.UpsertOrganizationUserRequest
+	OrganizationUserResponse      = dto.OrganizationUserResponse
+	UpsertAPIKeyRequest           = dto.UpsertAPIKeyRequest
+	APIKeyResponse                = dto.APIKeyResponse
+	SetMaintenanceRequest         = dto.SetMaintenanceRequest
+	MaintenanceResponse           = dto.MaintenanceResponse
+)
+
+// Organizations
+type (
+	CreateOrganizationRequest       = dto.CreateOrganizationRequest
+	SetMemberRoleRequest            = dto.SetMemberRoleRequest
+	CreateInvitationRequest         = dto.CreateInvitationRequest
+	AcceptInvitationRequest         = dto.AcceptInvitationRequest
+	CreateOrganizationAPIKeyRequest = dto.CreateOrganizationAPIKeyRequest
+	MembershipResponse              = dto.MembershipResponse
+	MemberResponse                  = dto.MemberResponse
+	InvitationResponse              = dto.InvitationResponse
+	OrganizationAPIKeyResponse      = dto.OrganizationAPIKeyResponse
+)
+
+// Advisor delegations
+type (
+	CreateDelegationRequest       = dto.CreateDelegationRequest
+	ConsentDelegationRequest      = dto.ConsentDelegationRequest
+	SetDelegatedPortfoliosRequest = dto.SetDelegatedPortfoliosRequest
+	DelegationResponse            = dto.DelegationResponse
+	DelegationAuditEventResponse  = dto.DelegationAuditEventResponse
+)
+
+// Admin user management
+type (
+	ListUsersRequest           = dto.ListUsersRequest
+	SetUserRoleRequest         = dto.SetUserRoleRequest
+	AdminUserResponse          = dto.AdminUserResponse
+	AdminUserListResponse      = dto.AdminUserListResponse
+	ForcePasswordResetResponse = dto.ForcePasswordResetResponse
+	UserUsageResponse          = dto.UserUsageResponse
+)
+
+// Feature flags
+type (
+	FeaturesResponse            = dto.FeaturesResponse
+	SetFeatureFlagRequest       = dto.SetFeatureFlagRequest
+	FeatureFlagOverrideResponse = dto.FeatureFlagOverrideResponse
+	FeatureFlagResponse         = dto.FeatureFlagResponse
+	FeatureFlagListResponse     = dto.FeatureFlagListResponse
+)
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// The user management methods require the authenticated user to have the admin role.
+
+// ListUsers lists users matching the request's optional filters, a page at a time
+// GET /api/v1/admin/users
+func (c *Client) ListUsers(ctx context.Context, req ListUsersRequest) (*AdminUserListResponse, error) {
+	query := url.Values{}
+	if req.Email != "" {
+		query.Set("email", req.Email)
+	}
+	if req.Role != "" {
+		query.Set("role", string(req.Role))
+	}
+	if req.Disabled != nil {
+		query.Set("disabled", strconv.FormatBool(*req.Disabled))
+	}
+	if req.Limit > 0 {
+		query.Set("limit", strconv.Itoa(req.Limit))
+	}
+	if req.Offset > 0 {
+		query.Set("offset", strconv.Itoa(req.Offset))
+	}
+
+	var result AdminUserListResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/users", nil, query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetUser retrieves a user
+// GET /api/v1/admin/users/:id
+func (c *Client) GetUser(ctx context.Context, userID string) (*AdminUserResponse, error) {
+	var result AdminUserResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/users/:id", pathParams{"id": userID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// SetUserRole changes a user's role
+// PUT /api/v1/admin/users/:id/role
+func (c *Client) SetUserRole(ctx context.Context, userID string, role UserRole) (*AdminUserResponse, error) {
+	var result AdminUserResponse
+	req := SetUserRoleRequest{Role: role}
+	if err := c.do(ctx, http.MethodPut, "/api/v1/admin/users/:id/role", pathParams{"id": userID}, nil, req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DisableUser disables a user's account and ends their sessions
+// POST /api/v1/admin/users/:id/disable
+func (c *Client) DisableUser(ctx context.Context, userID string) (*AdminUserResponse, error) {
+	var result AdminUserResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/users/:id/disable", pathParams{"id": userID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// EnableUser re-enables a disabled account
+// POST /api/v1/admin/users/:id/enable
+func (c *Client) EnableUser(ctx context.Context, userID string) (*AdminUserResponse, error) {
+	var result AdminUserResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/users/:id/enable", pathParams{"id": userID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ForcePasswordReset locks a user's account until they choose a new password, and emails
+// them a reset link
+// POST /api/v1/admin/users/:id/reset-password
+func (c *Client) ForcePasswordReset(ctx context.Context, userID string) (*ForcePasswordResetResponse, error) {
+	var result ForcePasswordResetResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/users/:id/reset-password", pathParams{"id": userID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteUser deletes a user along with everything they own
+// DELETE /api/v1/admin/users/:id
+func (c *Client) DeleteUser(ctx context.Context, userID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/admin/users/:id", pathParams{"id": userID}, nil, nil, nil)
+}
+
+// GetUserUsage counts the resources a user owns
+// GET /api/v1/admin/users/:id/usage
+func (c *Client) GetUserUsage(ctx context.Context, userID string) (*UserUsageResponse, error) {
+	var result UserUsageResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/users/:id/usage", pathParams{"id": userID}, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
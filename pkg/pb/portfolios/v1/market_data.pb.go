@@ -0,0 +1,574 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v6.32.1
+// source: portfolios/v1/market_data.proto
+
+package portfoliosv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Quote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Price         string                 `protobuf:"bytes,2,opt,name=price,proto3" json:"price,omitempty"`
+	Open          string                 `protobuf:"bytes,3,opt,name=open,proto3" json:"open,omitempty"`
+	High          string                 `protobuf:"bytes,4,opt,name=high,proto3" json:"high,omitempty"`
+	Low           string                 `protobuf:"bytes,5,opt,name=low,proto3" json:"low,omitempty"`
+	Volume        int64                  `protobuf:"varint,6,opt,name=volume,proto3" json:"volume,omitempty"`
+	PreviousClose string                 `protobuf:"bytes,7,opt,name=previous_close,json=previousClose,proto3" json:"previous_close,omitempty"`
+	Change        string                 `protobuf:"bytes,8,opt,name=change,proto3" json:"change,omitempty"`
+	ChangePercent string                 `protobuf:"bytes,9,opt,name=change_percent,json=changePercent,proto3" json:"change_percent,omitempty"`
+	LastUpdated   *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=last_updated,json=lastUpdated,proto3" json:"last_updated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Quote) Reset() {
+	*x = Quote{}
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Quote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Quote) ProtoMessage() {}
+
+func (x *Quote) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Quote.ProtoReflect.Descriptor instead.
+func (*Quote) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_market_data_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Quote) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Quote) GetPrice() string {
+	if x != nil {
+		return x.Price
+	}
+	return ""
+}
+
+func (x *Quote) GetOpen() string {
+	if x != nil {
+		return x.Open
+	}
+	return ""
+}
+
+func (x *Quote) GetHigh() string {
+	if x != nil {
+		return x.High
+	}
+	return ""
+}
+
+func (x *Quote) GetLow() string {
+	if x != nil {
+		return x.Low
+	}
+	return ""
+}
+
+func (x *Quote) GetVolume() int64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+func (x *Quote) GetPreviousClose() string {
+	if x != nil {
+		return x.PreviousClose
+	}
+	return ""
+}
+
+func (x *Quote) GetChange() string {
+	if x != nil {
+		return x.Change
+	}
+	return ""
+}
+
+func (x *Quote) GetChangePercent() string {
+	if x != nil {
+		return x.ChangePercent
+	}
+	return ""
+}
+
+func (x *Quote) GetLastUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUpdated
+	}
+	return nil
+}
+
+type GetQuoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuoteRequest) Reset() {
+	*x = GetQuoteRequest{}
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuoteRequest) ProtoMessage() {}
+
+func (x *GetQuoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuoteRequest.ProtoReflect.Descriptor instead.
+func (*GetQuoteRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_market_data_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetQuoteRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type GetQuotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbols       []string               `protobuf:"bytes,1,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotesRequest) Reset() {
+	*x = GetQuotesRequest{}
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotesRequest) ProtoMessage() {}
+
+func (x *GetQuotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotesRequest.ProtoReflect.Descriptor instead.
+func (*GetQuotesRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_market_data_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetQuotesRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+type GetQuotesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Symbols the provider had no quote for are left out
+	Quotes        map[string]*Quote `protobuf:"bytes,1,rep,name=quotes,proto3" json:"quotes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetQuotesResponse) Reset() {
+	*x = GetQuotesResponse{}
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetQuotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetQuotesResponse) ProtoMessage() {}
+
+func (x *GetQuotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetQuotesResponse.ProtoReflect.Descriptor instead.
+func (*GetQuotesResponse) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_market_data_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetQuotesResponse) GetQuotes() map[string]*Quote {
+	if x != nil {
+		return x.Quotes
+	}
+	return nil
+}
+
+type HistoricalPrice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Open          string                 `protobuf:"bytes,2,opt,name=open,proto3" json:"open,omitempty"`
+	High          string                 `protobuf:"bytes,3,opt,name=high,proto3" json:"high,omitempty"`
+	Low           string                 `protobuf:"bytes,4,opt,name=low,proto3" json:"low,omitempty"`
+	Close         string                 `protobuf:"bytes,5,opt,name=close,proto3" json:"close,omitempty"`
+	Volume        int64                  `protobuf:"varint,6,opt,name=volume,proto3" json:"volume,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoricalPrice) Reset() {
+	*x = HistoricalPrice{}
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoricalPrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoricalPrice) ProtoMessage() {}
+
+func (x *HistoricalPrice) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoricalPrice.ProtoReflect.Descriptor instead.
+func (*HistoricalPrice) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_market_data_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HistoricalPrice) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *HistoricalPrice) GetOpen() string {
+	if x != nil {
+		return x.Open
+	}
+	return ""
+}
+
+func (x *HistoricalPrice) GetHigh() string {
+	if x != nil {
+		return x.High
+	}
+	return ""
+}
+
+func (x *HistoricalPrice) GetLow() string {
+	if x != nil {
+		return x.Low
+	}
+	return ""
+}
+
+func (x *HistoricalPrice) GetClose() string {
+	if x != nil {
+		return x.Close
+	}
+	return ""
+}
+
+func (x *HistoricalPrice) GetVolume() int64 {
+	if x != nil {
+		return x.Volume
+	}
+	return 0
+}
+
+type GetHistoricalPricesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	StartDate     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoricalPricesRequest) Reset() {
+	*x = GetHistoricalPricesRequest{}
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoricalPricesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoricalPricesRequest) ProtoMessage() {}
+
+func (x *GetHistoricalPricesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoricalPricesRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoricalPricesRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_market_data_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetHistoricalPricesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetHistoricalPricesRequest) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *GetHistoricalPricesRequest) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+type GetHistoricalPricesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prices        []*HistoricalPrice     `protobuf:"bytes,1,rep,name=prices,proto3" json:"prices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoricalPricesResponse) Reset() {
+	*x = GetHistoricalPricesResponse{}
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoricalPricesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoricalPricesResponse) ProtoMessage() {}
+
+func (x *GetHistoricalPricesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_market_data_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoricalPricesResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoricalPricesResponse) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_market_data_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetHistoricalPricesResponse) GetPrices() []*HistoricalPrice {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+var File_portfolios_v1_market_data_proto protoreflect.FileDescriptor
+
+const file_portfolios_v1_market_data_proto_rawDesc = "" +
+	"\n" +
+	"\x1fportfolios/v1/market_data.proto\x12\rportfolios.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xac\x02\n" +
+	"\x05Quote\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\tR\x05price\x12\x12\n" +
+	"\x04open\x18\x03 \x01(\tR\x04open\x12\x12\n" +
+	"\x04high\x18\x04 \x01(\tR\x04high\x12\x10\n" +
+	"\x03low\x18\x05 \x01(\tR\x03low\x12\x16\n" +
+	"\x06volume\x18\x06 \x01(\x03R\x06volume\x12%\n" +
+	"\x0eprevious_close\x18\a \x01(\tR\rpreviousClose\x12\x16\n" +
+	"\x06change\x18\b \x01(\tR\x06change\x12%\n" +
+	"\x0echange_percent\x18\t \x01(\tR\rchangePercent\x12=\n" +
+	"\flast_updated\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\vlastUpdated\")\n" +
+	"\x0fGetQuoteRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\",\n" +
+	"\x10GetQuotesRequest\x12\x18\n" +
+	"\asymbols\x18\x01 \x03(\tR\asymbols\"\xaa\x01\n" +
+	"\x11GetQuotesResponse\x12D\n" +
+	"\x06quotes\x18\x01 \x03(\v2,.portfolios.v1.GetQuotesResponse.QuotesEntryR\x06quotes\x1aO\n" +
+	"\vQuotesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12*\n" +
+	"\x05value\x18\x02 \x01(\v2\x14.portfolios.v1.QuoteR\x05value:\x028\x01\"\xa9\x01\n" +
+	"\x0fHistoricalPrice\x12.\n" +
+	"\x04date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x12\n" +
+	"\x04open\x18\x02 \x01(\tR\x04open\x12\x12\n" +
+	"\x04high\x18\x03 \x01(\tR\x04high\x12\x10\n" +
+	"\x03low\x18\x04 \x01(\tR\x03low\x12\x14\n" +
+	"\x05close\x18\x05 \x01(\tR\x05close\x12\x16\n" +
+	"\x06volume\x18\x06 \x01(\x03R\x06volume\"\xa6\x01\n" +
+	"\x1aGetHistoricalPricesRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x129\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\"U\n" +
+	"\x1bGetHistoricalPricesResponse\x126\n" +
+	"\x06prices\x18\x01 \x03(\v2\x1e.portfolios.v1.HistoricalPriceR\x06prices2\x93\x02\n" +
+	"\x11MarketDataService\x12@\n" +
+	"\bGetQuote\x12\x1e.portfolios.v1.GetQuoteRequest\x1a\x14.portfolios.v1.Quote\x12N\n" +
+	"\tGetQuotes\x12\x1f.portfolios.v1.GetQuotesRequest\x1a .portfolios.v1.GetQuotesResponse\x12l\n" +
+	"\x13GetHistoricalPrices\x12).portfolios.v1.GetHistoricalPricesRequest\x1a*.portfolios.v1.GetHistoricalPricesResponseB?Z=github.com/lenon/portfolios/pkg/pb/portfolios/v1;portfoliosv1b\x06proto3"
+
+var (
+	file_portfolios_v1_market_data_proto_rawDescOnce sync.Once
+	file_portfolios_v1_market_data_proto_rawDescData []byte
+)
+
+func file_portfolios_v1_market_data_proto_rawDescGZIP() []byte {
+	file_portfolios_v1_market_data_proto_rawDescOnce.Do(func() {
+		file_portfolios_v1_market_data_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_portfolios_v1_market_data_proto_rawDesc), len(file_portfolios_v1_market_data_proto_rawDesc)))
+	})
+	return file_portfolios_v1_market_data_proto_rawDescData
+}
+
+var file_portfolios_v1_market_data_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_portfolios_v1_market_data_proto_goTypes = []any{
+	(*Quote)(nil),                       // 0: portfolios.v1.Quote
+	(*GetQuoteRequest)(nil),             // 1: portfolios.v1.GetQuoteRequest
+	(*GetQuotesRequest)(nil),            // 2: portfolios.v1.GetQuotesRequest
+	(*GetQuotesResponse)(nil),           // 3: portfolios.v1.GetQuotesResponse
+	(*HistoricalPrice)(nil),             // 4: portfolios.v1.HistoricalPrice
+	(*GetHistoricalPricesRequest)(nil),  // 5: portfolios.v1.GetHistoricalPricesRequest
+	(*GetHistoricalPricesResponse)(nil), // 6: portfolios.v1.GetHistoricalPricesResponse
+	nil,                                 // 7: portfolios.v1.GetQuotesResponse.QuotesEntry
+	(*timestamppb.Timestamp)(nil),       // 8: google.protobuf.Timestamp
+}
+var file_portfolios_v1_market_data_proto_depIdxs = []int32{
+	8,  // 0: portfolios.v1.Quote.last_updated:type_name -> google.protobuf.Timestamp
+	7,  // 1: portfolios.v1.GetQuotesResponse.quotes:type_name -> portfolios.v1.GetQuotesResponse.QuotesEntry
+	8,  // 2: portfolios.v1.HistoricalPrice.date:type_name -> google.protobuf.Timestamp
+	8,  // 3: portfolios.v1.GetHistoricalPricesRequest.start_date:type_name -> google.protobuf.Timestamp
+	8,  // 4: portfolios.v1.GetHistoricalPricesRequest.end_date:type_name -> google.protobuf.Timestamp
+	4,  // 5: portfolios.v1.GetHistoricalPricesResponse.prices:type_name -> portfolios.v1.HistoricalPrice
+	0,  // 6: portfolios.v1.GetQuotesResponse.QuotesEntry.value:type_name -> portfolios.v1.Quote
+	1,  // 7: portfolios.v1.MarketDataService.GetQuote:input_type -> portfolios.v1.GetQuoteRequest
+	2,  // 8: portfolios.v1.MarketDataService.GetQuotes:input_type -> portfolios.v1.GetQuotesRequest
+	5,  // 9: portfolios.v1.MarketDataService.GetHistoricalPrices:input_type -> portfolios.v1.GetHistoricalPricesRequest
+	0,  // 10: portfolios.v1.MarketDataService.GetQuote:output_type -> portfolios.v1.Quote
+	3,  // 11: portfolios.v1.MarketDataService.GetQuotes:output_type -> portfolios.v1.GetQuotesResponse
+	6,  // 12: portfolios.v1.MarketDataService.GetHistoricalPrices:output_type -> portfolios.v1.GetHistoricalPricesResponse
+	10, // [10:13] is the sub-list for method output_type
+	7,  // [7:10] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_portfolios_v1_market_data_proto_init() }
+func file_portfolios_v1_market_data_proto_init() {
+	if File_portfolios_v1_market_data_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_portfolios_v1_market_data_proto_rawDesc), len(file_portfolios_v1_market_data_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_portfolios_v1_market_data_proto_goTypes,
+		DependencyIndexes: file_portfolios_v1_market_data_proto_depIdxs,
+		MessageInfos:      file_portfolios_v1_market_data_proto_msgTypes,
+	}.Build()
+	File_portfolios_v1_market_data_proto = out.File
+	file_portfolios_v1_market_data_proto_goTypes = nil
+	file_portfolios_v1_market_data_proto_depIdxs = nil
+}
@@ -0,0 +1,310 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v6.32.1
+// source: portfolios/v1/performance.proto
+
+package portfoliosv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetPerformanceMetricsRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	PortfolioId string                 `protobuf:"bytes,1,opt,name=portfolio_id,json=portfolioId,proto3" json:"portfolio_id,omitempty"`
+	// A year ago when unset
+	StartDate *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	// Now when unset
+	EndDate       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPerformanceMetricsRequest) Reset() {
+	*x = GetPerformanceMetricsRequest{}
+	mi := &file_portfolios_v1_performance_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPerformanceMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPerformanceMetricsRequest) ProtoMessage() {}
+
+func (x *GetPerformanceMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_performance_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPerformanceMetricsRequest.ProtoReflect.Descriptor instead.
+func (*GetPerformanceMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_performance_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetPerformanceMetricsRequest) GetPortfolioId() string {
+	if x != nil {
+		return x.PortfolioId
+	}
+	return ""
+}
+
+func (x *GetPerformanceMetricsRequest) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *GetPerformanceMetricsRequest) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+type PerformanceMetrics struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	StartDate           *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	EndDate             *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=end_date,json=endDate,proto3" json:"end_date,omitempty"`
+	StartingValue       string                 `protobuf:"bytes,3,opt,name=starting_value,json=startingValue,proto3" json:"starting_value,omitempty"`
+	EndingValue         string                 `protobuf:"bytes,4,opt,name=ending_value,json=endingValue,proto3" json:"ending_value,omitempty"`
+	TotalReturn         string                 `protobuf:"bytes,5,opt,name=total_return,json=totalReturn,proto3" json:"total_return,omitempty"`
+	TotalReturnPct      string                 `protobuf:"bytes,6,opt,name=total_return_pct,json=totalReturnPct,proto3" json:"total_return_pct,omitempty"`
+	TimeWeightedReturn  string                 `protobuf:"bytes,7,opt,name=time_weighted_return,json=timeWeightedReturn,proto3" json:"time_weighted_return,omitempty"`
+	MoneyWeightedReturn string                 `protobuf:"bytes,8,opt,name=money_weighted_return,json=moneyWeightedReturn,proto3" json:"money_weighted_return,omitempty"`
+	AnnualizedReturn    string                 `protobuf:"bytes,9,opt,name=annualized_return,json=annualizedReturn,proto3" json:"annualized_return,omitempty"`
+	TotalDeposits       string                 `protobuf:"bytes,10,opt,name=total_deposits,json=totalDeposits,proto3" json:"total_deposits,omitempty"`
+	TotalWithdrawals    string                 `protobuf:"bytes,11,opt,name=total_withdrawals,json=totalWithdrawals,proto3" json:"total_withdrawals,omitempty"`
+	NetCashFlow         string                 `protobuf:"bytes,12,opt,name=net_cash_flow,json=netCashFlow,proto3" json:"net_cash_flow,omitempty"`
+	Years               float64                `protobuf:"fixed64,13,opt,name=years,proto3" json:"years,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *PerformanceMetrics) Reset() {
+	*x = PerformanceMetrics{}
+	mi := &file_portfolios_v1_performance_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PerformanceMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PerformanceMetrics) ProtoMessage() {}
+
+func (x *PerformanceMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_performance_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PerformanceMetrics.ProtoReflect.Descriptor instead.
+func (*PerformanceMetrics) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_performance_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PerformanceMetrics) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *PerformanceMetrics) GetEndDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndDate
+	}
+	return nil
+}
+
+func (x *PerformanceMetrics) GetStartingValue() string {
+	if x != nil {
+		return x.StartingValue
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetEndingValue() string {
+	if x != nil {
+		return x.EndingValue
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetTotalReturn() string {
+	if x != nil {
+		return x.TotalReturn
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetTotalReturnPct() string {
+	if x != nil {
+		return x.TotalReturnPct
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetTimeWeightedReturn() string {
+	if x != nil {
+		return x.TimeWeightedReturn
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetMoneyWeightedReturn() string {
+	if x != nil {
+		return x.MoneyWeightedReturn
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetAnnualizedReturn() string {
+	if x != nil {
+		return x.AnnualizedReturn
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetTotalDeposits() string {
+	if x != nil {
+		return x.TotalDeposits
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetTotalWithdrawals() string {
+	if x != nil {
+		return x.TotalWithdrawals
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetNetCashFlow() string {
+	if x != nil {
+		return x.NetCashFlow
+	}
+	return ""
+}
+
+func (x *PerformanceMetrics) GetYears() float64 {
+	if x != nil {
+		return x.Years
+	}
+	return 0
+}
+
+var File_portfolios_v1_performance_proto protoreflect.FileDescriptor
+
+const file_portfolios_v1_performance_proto_rawDesc = "" +
+	"\n" +
+	"\x1fportfolios/v1/performance.proto\x12\rportfolios.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb3\x01\n" +
+	"\x1cGetPerformanceMetricsRequest\x12!\n" +
+	"\fportfolio_id\x18\x01 \x01(\tR\vportfolioId\x129\n" +
+	"\n" +
+	"start_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\"\xbe\x04\n" +
+	"\x12PerformanceMetrics\x129\n" +
+	"\n" +
+	"start_date\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x125\n" +
+	"\bend_date\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\aendDate\x12%\n" +
+	"\x0estarting_value\x18\x03 \x01(\tR\rstartingValue\x12!\n" +
+	"\fending_value\x18\x04 \x01(\tR\vendingValue\x12!\n" +
+	"\ftotal_return\x18\x05 \x01(\tR\vtotalReturn\x12(\n" +
+	"\x10total_return_pct\x18\x06 \x01(\tR\x0etotalReturnPct\x120\n" +
+	"\x14time_weighted_return\x18\a \x01(\tR\x12timeWeightedReturn\x122\n" +
+	"\x15money_weighted_return\x18\b \x01(\tR\x13moneyWeightedReturn\x12+\n" +
+	"\x11annualized_return\x18\t \x01(\tR\x10annualizedReturn\x12%\n" +
+	"\x0etotal_deposits\x18\n" +
+	" \x01(\tR\rtotalDeposits\x12+\n" +
+	"\x11total_withdrawals\x18\v \x01(\tR\x10totalWithdrawals\x12\"\n" +
+	"\rnet_cash_flow\x18\f \x01(\tR\vnetCashFlow\x12\x14\n" +
+	"\x05years\x18\r \x01(\x01R\x05years2}\n" +
+	"\x12PerformanceService\x12g\n" +
+	"\x15GetPerformanceMetrics\x12+.portfolios.v1.GetPerformanceMetricsRequest\x1a!.portfolios.v1.PerformanceMetricsB?Z=github.com/lenon/portfolios/pkg/pb/portfolios/v1;portfoliosv1b\x06proto3"
+
+var (
+	file_portfolios_v1_performance_proto_rawDescOnce sync.Once
+	file_portfolios_v1_performance_proto_rawDescData []byte
+)
+
+func file_portfolios_v1_performance_proto_rawDescGZIP() []byte {
+	file_portfolios_v1_performance_proto_rawDescOnce.Do(func() {
+		file_portfolios_v1_performance_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_portfolios_v1_performance_proto_rawDesc), len(file_portfolios_v1_performance_proto_rawDesc)))
+	})
+	return file_portfolios_v1_performance_proto_rawDescData
+}
+
+var file_portfolios_v1_performance_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_portfolios_v1_performance_proto_goTypes = []any{
+	(*GetPerformanceMetricsRequest)(nil), // 0: portfolios.v1.GetPerformanceMetricsRequest
+	(*PerformanceMetrics)(nil),           // 1: portfolios.v1.PerformanceMetrics
+	(*timestamppb.Timestamp)(nil),        // 2: google.protobuf.Timestamp
+}
+var file_portfolios_v1_performance_proto_depIdxs = []int32{
+	2, // 0: portfolios.v1.GetPerformanceMetricsRequest.start_date:type_name -> google.protobuf.Timestamp
+	2, // 1: portfolios.v1.GetPerformanceMetricsRequest.end_date:type_name -> google.protobuf.Timestamp
+	2, // 2: portfolios.v1.PerformanceMetrics.start_date:type_name -> google.protobuf.Timestamp
+	2, // 3: portfolios.v1.PerformanceMetrics.end_date:type_name -> google.protobuf.Timestamp
+	0, // 4: portfolios.v1.PerformanceService.GetPerformanceMetrics:input_type -> portfolios.v1.GetPerformanceMetricsRequest
+	1, // 5: portfolios.v1.PerformanceService.GetPerformanceMetrics:output_type -> portfolios.v1.PerformanceMetrics
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_portfolios_v1_performance_proto_init() }
+func file_portfolios_v1_performance_proto_init() {
+	if File_portfolios_v1_performance_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_portfolios_v1_performance_proto_rawDesc), len(file_portfolios_v1_performance_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_portfolios_v1_performance_proto_goTypes,
+		DependencyIndexes: file_portfolios_v1_performance_proto_depIdxs,
+		MessageInfos:      file_portfolios_v1_performance_proto_msgTypes,
+	}.Build()
+	File_portfolios_v1_performance_proto = out.File
+	file_portfolios_v1_performance_proto_goTypes = nil
+	file_portfolios_v1_performance_proto_depIdxs = nil
+}
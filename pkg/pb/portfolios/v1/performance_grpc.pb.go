@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v6.32.1
+// source: portfolios/v1/performance.proto
+
+package portfoliosv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	PerformanceService_GetPerformanceMetrics_FullMethodName = "/portfolios.v1.PerformanceService/GetPerformanceMetrics"
+)
+
+// PerformanceServiceClient is the client API for PerformanceService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// PerformanceService calculates the returns of the authenticated user's portfolios. It is
+// only registered when market data is enabled.
+type PerformanceServiceClient interface {
+	GetPerformanceMetrics(ctx context.Context, in *GetPerformanceMetricsRequest, opts ...grpc.CallOption) (*PerformanceMetrics, error)
+}
+
+type performanceServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPerformanceServiceClient(cc grpc.ClientConnInterface) PerformanceServiceClient {
+	return &performanceServiceClient{cc}
+}
+
+func (c *performanceServiceClient) GetPerformanceMetrics(ctx context.Context, in *GetPerformanceMetricsRequest, opts ...grpc.CallOption) (*PerformanceMetrics, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PerformanceMetrics)
+	err := c.cc.Invoke(ctx, PerformanceService_GetPerformanceMetrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PerformanceServiceServer is the server API for PerformanceService service.
+// All implementations must embed UnimplementedPerformanceServiceServer
+// for forward compatibility.
+//
+// PerformanceService calculates the returns of the authenticated user's portfolios. It is
+// only registered when market data is enabled.
+type PerformanceServiceServer interface {
+	GetPerformanceMetrics(context.Context, *GetPerformanceMetricsRequest) (*PerformanceMetrics, error)
+	mustEmbedUnimplementedPerformanceServiceServer()
+}
+
+// UnimplementedPerformanceServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPerformanceServiceServer struct{}
+
+func (UnimplementedPerformanceServiceServer) GetPerformanceMetrics(context.Context, *GetPerformanceMetricsRequest) (*PerformanceMetrics, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPerformanceMetrics not implemented")
+}
+func (UnimplementedPerformanceServiceServer) mustEmbedUnimplementedPerformanceServiceServer() {}
+func (UnimplementedPerformanceServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafePerformanceServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PerformanceServiceServer will
+// result in compilation errors.
+type UnsafePerformanceServiceServer interface {
+	mustEmbedUnimplementedPerformanceServiceServer()
+}
+
+func RegisterPerformanceServiceServer(s grpc.ServiceRegistrar, srv PerformanceServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPerformanceServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PerformanceService_ServiceDesc, srv)
+}
+
+func _PerformanceService_GetPerformanceMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPerformanceMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PerformanceServiceServer).GetPerformanceMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PerformanceService_GetPerformanceMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PerformanceServiceServer).GetPerformanceMetrics(ctx, req.(*GetPerformanceMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PerformanceService_ServiceDesc is the grpc.ServiceDesc for PerformanceService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PerformanceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "portfolios.v1.PerformanceService",
+	HandlerType: (*PerformanceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPerformanceMetrics",
+			Handler:    _PerformanceService_GetPerformanceMetrics_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "portfolios/v1/performance.proto",
+}
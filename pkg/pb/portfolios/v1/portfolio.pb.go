@@ -0,0 +1,798 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        v6.32.1
+// source: portfolios/v1/portfolio.proto
+
+package portfoliosv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Portfolio struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId          string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Name            string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Description     string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	BaseCurrency    string                 `protobuf:"bytes,5,opt,name=base_currency,json=baseCurrency,proto3" json:"base_currency,omitempty"`
+	CostBasisMethod string                 `protobuf:"bytes,6,opt,name=cost_basis_method,json=costBasisMethod,proto3" json:"cost_basis_method,omitempty"`
+	Version         int32                  `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Portfolio) Reset() {
+	*x = Portfolio{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Portfolio) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Portfolio) ProtoMessage() {}
+
+func (x *Portfolio) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Portfolio.ProtoReflect.Descriptor instead.
+func (*Portfolio) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Portfolio) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Portfolio) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Portfolio) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Portfolio) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Portfolio) GetBaseCurrency() string {
+	if x != nil {
+		return x.BaseCurrency
+	}
+	return ""
+}
+
+func (x *Portfolio) GetCostBasisMethod() string {
+	if x != nil {
+		return x.CostBasisMethod
+	}
+	return ""
+}
+
+func (x *Portfolio) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Portfolio) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Portfolio) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListPortfoliosRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPortfoliosRequest) Reset() {
+	*x = ListPortfoliosRequest{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPortfoliosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPortfoliosRequest) ProtoMessage() {}
+
+func (x *ListPortfoliosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPortfoliosRequest.ProtoReflect.Descriptor instead.
+func (*ListPortfoliosRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{1}
+}
+
+type ListPortfoliosResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Portfolios    []*Portfolio           `protobuf:"bytes,1,rep,name=portfolios,proto3" json:"portfolios,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPortfoliosResponse) Reset() {
+	*x = ListPortfoliosResponse{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPortfoliosResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPortfoliosResponse) ProtoMessage() {}
+
+func (x *ListPortfoliosResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPortfoliosResponse.ProtoReflect.Descriptor instead.
+func (*ListPortfoliosResponse) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListPortfoliosResponse) GetPortfolios() []*Portfolio {
+	if x != nil {
+		return x.Portfolios
+	}
+	return nil
+}
+
+type GetPortfolioRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPortfolioRequest) Reset() {
+	*x = GetPortfolioRequest{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPortfolioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPortfolioRequest) ProtoMessage() {}
+
+func (x *GetPortfolioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPortfolioRequest.ProtoReflect.Descriptor instead.
+func (*GetPortfolioRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetPortfolioRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CreatePortfolioRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Name         string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description  string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	BaseCurrency string                 `protobuf:"bytes,3,opt,name=base_currency,json=baseCurrency,proto3" json:"base_currency,omitempty"`
+	// FIFO, LIFO or SPECIFIC_LOT; FIFO when empty
+	CostBasisMethod string `protobuf:"bytes,4,opt,name=cost_basis_method,json=costBasisMethod,proto3" json:"cost_basis_method,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CreatePortfolioRequest) Reset() {
+	*x = CreatePortfolioRequest{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePortfolioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePortfolioRequest) ProtoMessage() {}
+
+func (x *CreatePortfolioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePortfolioRequest.ProtoReflect.Descriptor instead.
+func (*CreatePortfolioRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreatePortfolioRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreatePortfolioRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreatePortfolioRequest) GetBaseCurrency() string {
+	if x != nil {
+		return x.BaseCurrency
+	}
+	return ""
+}
+
+func (x *CreatePortfolioRequest) GetCostBasisMethod() string {
+	if x != nil {
+		return x.CostBasisMethod
+	}
+	return ""
+}
+
+type UpdatePortfolioRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The version the update was made against
+	Version       int32  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Name          string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdatePortfolioRequest) Reset() {
+	*x = UpdatePortfolioRequest{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdatePortfolioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdatePortfolioRequest) ProtoMessage() {}
+
+func (x *UpdatePortfolioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdatePortfolioRequest.ProtoReflect.Descriptor instead.
+func (*UpdatePortfolioRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdatePortfolioRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdatePortfolioRequest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdatePortfolioRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdatePortfolioRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type DeletePortfolioRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePortfolioRequest) Reset() {
+	*x = DeletePortfolioRequest{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePortfolioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePortfolioRequest) ProtoMessage() {}
+
+func (x *DeletePortfolioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePortfolioRequest.ProtoReflect.Descriptor instead.
+func (*DeletePortfolioRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeletePortfolioRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeletePortfolioResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePortfolioResponse) Reset() {
+	*x = DeletePortfolioResponse{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePortfolioResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePortfolioResponse) ProtoMessage() {}
+
+func (x *DeletePortfolioResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePortfolioResponse.ProtoReflect.Descriptor instead.
+func (*DeletePortfolioResponse) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{7}
+}
+
+type Holding struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PortfolioId   string                 `protobuf:"bytes,2,opt,name=portfolio_id,json=portfolioId,proto3" json:"portfolio_id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	AssetType     string                 `protobuf:"bytes,4,opt,name=asset_type,json=assetType,proto3" json:"asset_type,omitempty"`
+	Quantity      string                 `protobuf:"bytes,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	CostBasis     string                 `protobuf:"bytes,6,opt,name=cost_basis,json=costBasis,proto3" json:"cost_basis,omitempty"`
+	AvgCostPrice  string                 `protobuf:"bytes,7,opt,name=avg_cost_price,json=avgCostPrice,proto3" json:"avg_cost_price,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Holding) Reset() {
+	*x = Holding{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Holding) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Holding) ProtoMessage() {}
+
+func (x *Holding) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Holding.ProtoReflect.Descriptor instead.
+func (*Holding) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Holding) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Holding) GetPortfolioId() string {
+	if x != nil {
+		return x.PortfolioId
+	}
+	return ""
+}
+
+func (x *Holding) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Holding) GetAssetType() string {
+	if x != nil {
+		return x.AssetType
+	}
+	return ""
+}
+
+func (x *Holding) GetQuantity() string {
+	if x != nil {
+		return x.Quantity
+	}
+	return ""
+}
+
+func (x *Holding) GetCostBasis() string {
+	if x != nil {
+		return x.CostBasis
+	}
+	return ""
+}
+
+func (x *Holding) GetAvgCostPrice() string {
+	if x != nil {
+		return x.AvgCostPrice
+	}
+	return ""
+}
+
+func (x *Holding) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListHoldingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PortfolioId   string                 `protobuf:"bytes,1,opt,name=portfolio_id,json=portfolioId,proto3" json:"portfolio_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHoldingsRequest) Reset() {
+	*x = ListHoldingsRequest{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHoldingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHoldingsRequest) ProtoMessage() {}
+
+func (x *ListHoldingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHoldingsRequest.ProtoReflect.Descriptor instead.
+func (*ListHoldingsRequest) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListHoldingsRequest) GetPortfolioId() string {
+	if x != nil {
+		return x.PortfolioId
+	}
+	return ""
+}
+
+type ListHoldingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Holdings      []*Holding             `protobuf:"bytes,1,rep,name=holdings,proto3" json:"holdings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListHoldingsResponse) Reset() {
+	*x = ListHoldingsResponse{}
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListHoldingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListHoldingsResponse) ProtoMessage() {}
+
+func (x *ListHoldingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_portfolios_v1_portfolio_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListHoldingsResponse.ProtoReflect.Descriptor instead.
+func (*ListHoldingsResponse) Descriptor() ([]byte, []int) {
+	return file_portfolios_v1_portfolio_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListHoldingsResponse) GetHoldings() []*Holding {
+	if x != nil {
+		return x.Holdings
+	}
+	return nil
+}
+
+var File_portfolios_v1_portfolio_proto protoreflect.FileDescriptor
+
+const file_portfolios_v1_portfolio_proto_rawDesc = "" +
+	"\n" +
+	"\x1dportfolios/v1/portfolio.proto\x12\rportfolios.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xcb\x02\n" +
+	"\tPortfolio\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12#\n" +
+	"\rbase_currency\x18\x05 \x01(\tR\fbaseCurrency\x12*\n" +
+	"\x11cost_basis_method\x18\x06 \x01(\tR\x0fcostBasisMethod\x12\x18\n" +
+	"\aversion\x18\a \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x17\n" +
+	"\x15ListPortfoliosRequest\"R\n" +
+	"\x16ListPortfoliosResponse\x128\n" +
+	"\n" +
+	"portfolios\x18\x01 \x03(\v2\x18.portfolios.v1.PortfolioR\n" +
+	"portfolios\"%\n" +
+	"\x13GetPortfolioRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x9f\x01\n" +
+	"\x16CreatePortfolioRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12#\n" +
+	"\rbase_currency\x18\x03 \x01(\tR\fbaseCurrency\x12*\n" +
+	"\x11cost_basis_method\x18\x04 \x01(\tR\x0fcostBasisMethod\"x\n" +
+	"\x16UpdatePortfolioRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\"(\n" +
+	"\x16DeletePortfolioRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x19\n" +
+	"\x17DeletePortfolioResponse\"\x8f\x02\n" +
+	"\aHolding\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\fportfolio_id\x18\x02 \x01(\tR\vportfolioId\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12\x1d\n" +
+	"\n" +
+	"asset_type\x18\x04 \x01(\tR\tassetType\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\tR\bquantity\x12\x1d\n" +
+	"\n" +
+	"cost_basis\x18\x06 \x01(\tR\tcostBasis\x12$\n" +
+	"\x0eavg_cost_price\x18\a \x01(\tR\favgCostPrice\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"8\n" +
+	"\x13ListHoldingsRequest\x12!\n" +
+	"\fportfolio_id\x18\x01 \x01(\tR\vportfolioId\"J\n" +
+	"\x14ListHoldingsResponse\x122\n" +
+	"\bholdings\x18\x01 \x03(\v2\x16.portfolios.v1.HoldingR\bholdings2\xa2\x04\n" +
+	"\x10PortfolioService\x12]\n" +
+	"\x0eListPortfolios\x12$.portfolios.v1.ListPortfoliosRequest\x1a%.portfolios.v1.ListPortfoliosResponse\x12L\n" +
+	"\fGetPortfolio\x12\".portfolios.v1.GetPortfolioRequest\x1a\x18.portfolios.v1.Portfolio\x12R\n" +
+	"\x0fCreatePortfolio\x12%.portfolios.v1.CreatePortfolioRequest\x1a\x18.portfolios.v1.Portfolio\x12R\n" +
+	"\x0fUpdatePortfolio\x12%.portfolios.v1.UpdatePortfolioRequest\x1a\x18.portfolios.v1.Portfolio\x12`\n" +
+	"\x0fDeletePortfolio\x12%.portfolios.v1.DeletePortfolioRequest\x1a&.portfolios.v1.DeletePortfolioResponse\x12W\n" +
+	"\fListHoldings\x12\".portfolios.v1.ListHoldingsRequest\x1a#.portfolios.v1.ListHoldingsResponseB?Z=github.com/lenon/portfolios/pkg/pb/portfolios/v1;portfoliosv1b\x06proto3"
+
+var (
+	file_portfolios_v1_portfolio_proto_rawDescOnce sync.Once
+	file_portfolios_v1_portfolio_proto_rawDescData []byte
+)
+
+func file_portfolios_v1_portfolio_proto_rawDescGZIP() []byte {
+	file_portfolios_v1_portfolio_proto_rawDescOnce.Do(func() {
+		file_portfolios_v1_portfolio_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_portfolios_v1_portfolio_proto_rawDesc), len(file_portfolios_v1_portfolio_proto_rawDesc)))
+	})
+	return file_portfolios_v1_portfolio_proto_rawDescData
+}
+
+var file_portfolios_v1_portfolio_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_portfolios_v1_portfolio_proto_goTypes = []any{
+	(*Portfolio)(nil),               // 0: portfolios.v1.Portfolio
+	(*ListPortfoliosRequest)(nil),   // 1: portfolios.v1.ListPortfoliosRequest
+	(*ListPortfoliosResponse)(nil),  // 2: portfolios.v1.ListPortfoliosResponse
+	(*GetPortfolioRequest)(nil),     // 3: portfolios.v1.GetPortfolioRequest
+	(*CreatePortfolioRequest)(nil),  // 4: portfolios.v1.CreatePortfolioRequest
+	(*UpdatePortfolioRequest)(nil),  // 5: portfolios.v1.UpdatePortfolioRequest
+	(*DeletePortfolioRequest)(nil),  // 6: portfolios.v1.DeletePortfolioRequest
+	(*DeletePortfolioResponse)(nil), // 7: portfolios.v1.DeletePortfolioResponse
+	(*Holding)(nil),                 // 8: portfolios.v1.Holding
+	(*ListHoldingsRequest)(nil),     // 9: portfolios.v1.ListHoldingsRequest
+	(*ListHoldingsResponse)(nil),    // 10: portfolios.v1.ListHoldingsResponse
+	(*timestamppb.Timestamp)(nil),   // 11: google.protobuf.Timestamp
+}
+var file_portfolios_v1_portfolio_proto_depIdxs = []int32{
+	11, // 0: portfolios.v1.Portfolio.created_at:type_name -> google.protobuf.Timestamp
+	11, // 1: portfolios.v1.Portfolio.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 2: portfolios.v1.ListPortfoliosResponse.portfolios:type_name -> portfolios.v1.Portfolio
+	11, // 3: portfolios.v1.Holding.updated_at:type_name -> google.protobuf.Timestamp
+	8,  // 4: portfolios.v1.ListHoldingsResponse.holdings:type_name -> portfolios.v1.Holding
+	1,  // 5: portfolios.v1.PortfolioService.ListPortfolios:input_type -> portfolios.v1.ListPortfoliosRequest
+	3,  // 6: portfolios.v1.PortfolioService.GetPortfolio:input_type -> portfolios.v1.GetPortfolioRequest
+	4,  // 7: portfolios.v1.PortfolioService.CreatePortfolio:input_type -> portfolios.v1.CreatePortfolioRequest
+	5,  // 8: portfolios.v1.PortfolioService.UpdatePortfolio:input_type -> portfolios.v1.UpdatePortfolioRequest
+	6,  // 9: portfolios.v1.PortfolioService.DeletePortfolio:input_type -> portfolios.v1.DeletePortfolioRequest
+	9,  // 10: portfolios.v1.PortfolioService.ListHoldings:input_type -> portfolios.v1.ListHoldingsRequest
+	2,  // 11: portfolios.v1.PortfolioService.ListPortfolios:output_type -> portfolios.v1.ListPortfoliosResponse
+	0,  // 12: portfolios.v1.PortfolioService.GetPortfolio:output_type -> portfolios.v1.Portfolio
+	0,  // 13: portfolios.v1.PortfolioService.CreatePortfolio:output_type -> portfolios.v1.Portfolio
+	0,  // 14: portfolios.v1.PortfolioService.UpdatePortfolio:output_type -> portfolios.v1.Portfolio
+	7,  // 15: portfolios.v1.PortfolioService.DeletePortfolio:output_type -> portfolios.v1.DeletePortfolioResponse
+	10, // 16: portfolios.v1.PortfolioService.ListHoldings:output_type -> portfolios.v1.ListHoldingsResponse
+	11, // [11:17] is the sub-list for method output_type
+	5,  // [5:11] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_portfolios_v1_portfolio_proto_init() }
+func file_portfolios_v1_portfolio_proto_init() {
+	if File_portfolios_v1_portfolio_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_portfolios_v1_portfolio_proto_rawDesc), len(file_portfolios_v1_portfolio_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_portfolios_v1_portfolio_proto_goTypes,
+		DependencyIndexes: file_portfolios_v1_portfolio_proto_depIdxs,
+		MessageInfos:      file_portfolios_v1_portfolio_proto_msgTypes,
+	}.Build()
+	File_portfolios_v1_portfolio_proto = out.File
+	file_portfolios_v1_portfolio_proto_goTypes = nil
+	file_portfolios_v1_portfolio_proto_depIdxs = nil
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lenon/portfolios/internal/apitest"
+	"github.com/lenon/portfolios/internal/app"
+	"github.com/lenon/portfolios/internal/config"
+	"github.com/lenon/portfolios/internal/loadtest"
+	"github.com/lenon/portfolios/pkg/client"
+)
+
+// requireAPIError asserts that err is an *APIError with the given status
+func requireAPIError(t *testing.T, err error, status int) *client.APIError {
+	t.Helper()
+	var apiErr *client.APIError
+	require.True(t, errors.As(err, &apiErr), "expected *client.APIError, got %v", err)
+	assert.Equal(t, status, apiErr.StatusCode)
+	return apiErr
+}
+
+func TestHealth(t *testing.T) {
+	server := apitest.New(t)
+
+	resp := server.Do(t, http.MethodGet, "/health", nil, "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+	assert.NotEmpty(t, resp.Header.Get("Content-Security-Policy"))
+	assert.NotEmpty(t, resp.Header.Get("X-Request-ID"))
+
+	var health client.HealthResponse
+	resp.Decode(t, &health)
+	assert.Equal(t, "healthy", health.Status)
+}
+
+func TestAuthFlow(t *testing.T) {
+	server := apitest.New(t)
+	ctx := context.Background()
+
+	_, err := server.Client().GetCurrentUser(ctx)
+	apiErr := requireAPIError(t, err, http.StatusUnauthorized)
+	assert.NotEmpty(t, apiErr.RequestID)
+
+	user := server.RegisterUser(t)
+	me, err := user.Client.GetCurrentUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, me.Email)
+
+	c := server.Client()
+	auth, err := c.Login(ctx, client.LoginRequest{Email: user.Email, Password: user.Password})
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, auth.User.ID)
+
+	_, err = server.Client().Login(ctx, client.LoginRequest{Email: user.Email, Password: "WrongPass123"})
+	requireAPIError(t, err, http.StatusUnauthorized)
+
+	refreshed, err := c.RefreshToken(ctx, auth.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshed.AccessToken)
+
+	_, err = c.Logout(ctx, auth.RefreshToken)
+	require.NoError(t, err)
+	_, err = c.RefreshToken(ctx, auth.RefreshToken)
+	requireAPIError(t, err, http.StatusUnauthorized)
+}
+
+func TestPortfolioLifecycle(t *testing.T) {
+	server := apitest.New(t)
+	ctx := context.Background()
+	user := server.RegisterUser(t)
+
+	portfolio, err := user.Client.CreatePortfolio(ctx, client.CreatePortfolioRequest{Name: "Retirement"})
+	require.NoError(t, err)
+	portfolioID := portfolio.ID.String()
+
+	price := decimal.NewFromInt(150)
+	_, err = user.Client.CreateTransaction(ctx, portfolioID, client.CreateTransactionRequest{
+		Type:     client.TransactionTypeBuy,
+		Symbol:   "AAPL",
+		Date:     time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		Quantity: decimal.NewFromInt(10),
+		Price:    &price,
+	})
+	require.NoError(t, err)
+
+	holdings, err := user.Client.ListHoldings(ctx, portfolioID)
+	require.NoError(t, err)
+	require.Len(t, holdings.Holdings, 1)
+	assert.Equal(t, "AAPL", holdings.Holdings[0].Symbol)
+	assert.True(t, holdings.Holdings[0].Quantity.Equal(decimal.NewFromInt(10)))
+
+	// Other users can't see the portfolio
+	other := server.RegisterUser(t)
+	_, err = other.Client.GetPortfolio(ctx, portfolioID)
+	apiErr := requireAPIError(t, err, http.StatusForbidden)
+	assert.Equal(t, "FORBIDDEN", apiErr.Code)
+
+	require.NoError(t, user.Client.DeletePortfolio(ctx, portfolioID))
+	_, err = user.Client.GetPortfolio(ctx, portfolioID)
+	requireAPIError(t, err, http.StatusNotFound)
+}
+
+func TestMalformedRequest(t *testing.T) {
+	server := apitest.New(t)
+	user := server.RegisterUser(t)
+
+	resp := user.Do(t, http.MethodPost, "/api/v1/portfolios", map[string]any{"name": 42})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var body struct {
+		Code string `json:"code"`
+	}
+	resp.Decode(t, &body)
+	assert.NotEmpty(t, body.Code)
+}
+
+func TestCSVImportRunsOnWorkers(t *testing.T) {
+	server := apitest.New(t)
+	ctx := context.Background()
+	user := server.RegisterUser(t)
+
+	portfolio, err := user.Client.CreatePortfolio(ctx, client.CreatePortfolioRequest{Name: "Brokerage"})
+	require.NoError(t, err)
+	portfolioID := portfolio.ID.String()
+
+	job, err := user.Client.ImportCSV(ctx, portfolioID, client.CSVImportRequest{
+		Format: client.ImportFormatGeneric,
+		CSVData: "Date,Symbol,Type,Quantity,Price,Fees,Notes\n" +
+			"2024-01-15,AAPL,buy,10,150.50,5.00,Initial purchase\n" +
+			"2024-02-20,GOOGL,buy,5,120.00,2.50,\n" +
+			"2024-04-05,AAPL,sell,3,160.00,2.00,Partial profit taking\n",
+	})
+	require.NoError(t, err)
+
+	progress, err := user.Client.WatchImport(ctx, portfolioID, job.BatchID.String(), nil)
+	require.NoError(t, err)
+	require.Equal(t, client.ImportStatusCompleted, progress.Status, progress.Error)
+	require.NotNil(t, progress.Result)
+	assert.Equal(t, 3, progress.Result.SuccessCount)
+
+	holdings, err := user.Client.ListHoldings(ctx, portfolioID)
+	require.NoError(t, err)
+	assert.Len(t, holdings.Holdings, 2)
+}
+
+func TestMarketDataRoutes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("not configured", func(t *testing.T) {
+		server := apitest.New(t)
+		if !server.InProcess() {
+			t.Skip("the server's market data configuration is unknown")
+		}
+		user := server.RegisterUser(t)
+
+		_, err := user.Client.GetQuote(ctx, "AAPL")
+		requireAPIError(t, err, http.StatusNotFound)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		market := loadtest.NewMarket(1, 1, time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), 1)
+		server := apitest.New(t, apitest.WithAppOptions(app.WithMarketDataProvider(loadtest.NewProvider(market))))
+		user := server.RegisterUser(t)
+
+		quote, err := user.Client.GetQuote(ctx, market.Symbols[0])
+		require.NoError(t, err)
+		assert.Equal(t, market.Symbols[0], quote.Symbol)
+		assert.True(t, quote.Price.IsPositive())
+	})
+}
+
+func TestAuthRateLimit(t *testing.T) {
+	server := apitest.New(t, apitest.WithConfig(func(cfg *config.Config) {
+		cfg.Security.RateLimitRequests = 2
+	}))
+	ctx := context.Background()
+
+	c := server.Client()
+	for range 2 {
+		_, err := c.Login(ctx, client.LoginRequest{Email: "nobody@example.com", Password: apitest.Password})
+		requireAPIError(t, err, http.StatusUnauthorized)
+	}
+	_, err := c.Login(ctx, client.LoginRequest{Email: "nobody@example.com", Password: apitest.Password})
+	requireAPIError(t, err, http.StatusTooManyRequests)
+}
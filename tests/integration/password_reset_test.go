@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lenon/portfolios/internal/dto"
 	"github.com/lenon/portfolios/internal/models"
 	"github.com/lenon/portfolios/internal/repository"
 	"github.com/lenon/portfolios/internal/services"
@@ -32,6 +33,31 @@ func (m *MockEmailService) SendPasswordResetEmail(to, resetToken string) error {
 	return m.SendError
 }
 
+func (m *MockEmailService) SendRebalancePlanReminderEmail(to, planName string, pendingTrades int, lastActivity time.Time) error {
+	m.LastEmailRecipient = to
+	return m.SendError
+}
+
+func (m *MockEmailService) SendInviteEmail(to, organizationName, inviteToken string, expiresAt time.Time) error {
+	m.LastEmailRecipient = to
+	return m.SendError
+}
+
+func (m *MockEmailService) SendOrganizationInviteEmail(to, organizationName, inviterEmail string, role models.OrganizationRole, inviteToken string, expiresAt time.Time) error {
+	m.LastEmailRecipient = to
+	return m.SendError
+}
+
+func (m *MockEmailService) SendDelegationRequestEmail(to, advisorEmail string, access models.DelegationAccess, consentToken string, expiresAt time.Time) error {
+	m.LastEmailRecipient = to
+	return m.SendError
+}
+
+func (m *MockEmailService) SendPerformanceDigestEmail(to string, digest *dto.PerformanceDigest, unsubscribeToken string) error {
+	m.LastEmailRecipient = to
+	return m.SendError
+}
+
 // setupPasswordResetTest creates services for password reset testing
 func setupPasswordResetTest(t *testing.T) (services.PasswordResetService, services.AuthService, *MockEmailService, *gorm.DB) {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
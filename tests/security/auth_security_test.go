@@ -30,6 +30,26 @@ func (m *mockEmailService) SendPasswordResetEmail(to, resetToken string) error {
 	return nil
 }
 
+func (m *mockEmailService) SendRebalancePlanReminderEmail(to, planName string, pendingTrades int, lastActivity time.Time) error {
+	return nil
+}
+
+func (m *mockEmailService) SendInviteEmail(to, organizationName, inviteToken string, expiresAt time.Time) error {
+	return nil
+}
+
+func (m *mockEmailService) SendOrganizationInviteEmail(to, organizationName, inviterEmail string, role models.OrganizationRole, inviteToken string, expiresAt time.Time) error {
+	return nil
+}
+
+func (m *mockEmailService) SendDelegationRequestEmail(to, advisorEmail string, access models.DelegationAccess, consentToken string, expiresAt time.Time) error {
+	return nil
+}
+
+func (m *mockEmailService) SendPerformanceDigestEmail(to string, digest *dto.PerformanceDigest, unsubscribeToken string) error {
+	return nil
+}
+
 // setupSecurityTestServer creates a test server with rate limiting
 func setupSecurityTestServer(t *testing.T) (*gin.Engine, *gorm.DB, services.AuthService) {
 	gin.SetMode(gin.TestMode)
@@ -253,8 +273,8 @@ func TestSQLInjectionPrevention(t *testing.T) {
 		"Legitimate login should still work after SQL injection attempts")
 }
 
-// Test 3: Verify bcrypt password hashing (password never stored in plain text)
-func TestBcryptPasswordHashing(t *testing.T) {
+// Test 3: Verify Argon2id password hashing (password never stored in plain text)
+func TestArgon2idPasswordHashing(t *testing.T) {
 	_, db, authService := setupSecurityTestServer(t)
 	defer func() {
 		sqlDB, _ := db.DB()
@@ -279,16 +299,9 @@ func TestBcryptPasswordHashing(t *testing.T) {
 	assert.NotEqual(t, plainPassword, user.PasswordHash,
 		"Password should NOT be stored as plain text")
 
-	// Verify hash starts with bcrypt prefix ($2a$, $2b$, or $2y$)
-	assert.True(t,
-		strings.HasPrefix(user.PasswordHash, "$2a$") ||
-			strings.HasPrefix(user.PasswordHash, "$2b$") ||
-			strings.HasPrefix(user.PasswordHash, "$2y$"),
-		"Password hash should use bcrypt format (starts with $2a$, $2b$, or $2y$)")
-
-	// Verify hash length is appropriate for bcrypt (should be 60 characters)
-	assert.Equal(t, 60, len(user.PasswordHash),
-		"Bcrypt hash should be 60 characters long")
+	// Verify hash is in the PHC format with the default Argon2id parameters
+	assert.True(t, strings.HasPrefix(user.PasswordHash, "$argon2id$v=19$m=65536,t=3,p=4$"),
+		"Password hash should use argon2id format (starts with $argon2id$)")
 
 	// Verify password verification works
 	assert.True(t, user.CheckPassword(plainPassword),
@@ -297,7 +310,7 @@ func TestBcryptPasswordHashing(t *testing.T) {
 	assert.False(t, user.CheckPassword("WrongPassword"),
 		"Should reject incorrect password")
 
-	// Verify that same password produces different hashes (bcrypt uses salt)
+	// Verify that same password produces different hashes (argon2id uses salt)
 	user2 := &models.User{
 		ID:    uuid.New(),
 		Email: "another@example.com",